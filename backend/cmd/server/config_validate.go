@@ -0,0 +1,181 @@
+// Package main — config_validate.go implements the `config validate` subcommand
+// and the startup checks serve runs before it touches the database.
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// runConfigValidate dispatches `config validate`: it loads the configuration
+// without failing on the first problem, runs Config.Validate and the startup
+// checks, and prints every problem found. A non-nil error (and so a non-zero
+// exit status) means serve would refuse to start with this configuration.
+func runConfigValidate(configPath string) error {
+	if len(os.Args) < 3 || os.Args[2] != "validate" {
+		return fmt.Errorf("usage: %s config validate [--config <path>]", os.Args[0])
+	}
+	args := os.Args[3:]
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--config":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--config requires a path argument")
+			}
+			configPath = args[i+1]
+			i++
+		default:
+			return fmt.Errorf("unknown flag for 'config validate': %s", args[i])
+		}
+	}
+
+	cfg, err := config.LoadUnvalidated(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var problems config.ValidationErrors
+	if err := cfg.Validate(); err != nil && !errors.As(err, &problems) {
+		return err
+	}
+	checkStartupConfig(cfg, &problems)
+
+	if len(problems) == 0 {
+		fmt.Println("Configuration is valid")
+		return nil
+	}
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "  - %s\n", p.Error())
+	}
+	return fmt.Errorf("configuration is invalid: %d problem(s) found", len(problems))
+}
+
+// checkStartupConfig runs the checks Config.Validate cannot do without looking
+// outside the config: the encryption key from the environment, DSN parsing,
+// TLS files, and a dry construction of the storage backend. Problems are
+// appended to errs. The storage backend is skipped when errs already has a
+// storage problem, since its constructor would only repeat it.
+func checkStartupConfig(cfg *config.Config, errs *config.ValidationErrors) {
+	checkEncryptionKeys(errs,
+		os.Getenv("ENCRYPTION_KEY"),
+		os.Getenv("ENCRYPTION_KEY_PREVIOUS"),
+		os.Getenv("TFR_ALLOW_LOW_ENTROPY_ENCRYPTION_KEY") == "true",
+	)
+
+	checkDSN(errs, "database", &cfg.Database)
+	if cfg.IdentityDatabase.GetDSN() != cfg.Database.GetDSN() {
+		checkDSN(errs, "identity_database", &cfg.IdentityDatabase)
+	}
+
+	if cfg.Security.TLS.Enabled && cfg.Security.TLS.CertFile != "" && cfg.Security.TLS.KeyFile != "" {
+		checkTLSKeyPair(errs, cfg.Security.TLS.CertFile, cfg.Security.TLS.KeyFile)
+	}
+
+	for _, e := range *errs {
+		if strings.HasPrefix(e.Field, "storage.") {
+			return
+		}
+	}
+	checkStorageBackend(errs, cfg)
+}
+
+// checkEncryptionKeys applies the rules NewRouter enforces on ENCRYPTION_KEY
+// and ENCRYPTION_KEY_PREVIOUS: raw 32-byte AES-256 keys, and no low-entropy
+// current key unless the operator opted in with allowLowEntropy.
+func checkEncryptionKeys(errs *config.ValidationErrors, current, previous string, allowLowEntropy bool) {
+	switch {
+	case current == "":
+		errs.Add("ENCRYPTION_KEY", "is not set; generate one with: openssl rand -hex 16")
+	case len(current) != 32:
+		errs.Add("ENCRYPTION_KEY", "must be exactly 32 bytes, got %d; generate one with: openssl rand -hex 16", len(current))
+	case crypto.IsLikelyLowEntropySecret([]byte(current)) && !allowLowEntropy:
+		errs.Add("ENCRYPTION_KEY", "has low estimated entropy; generate one with: openssl rand -hex 16, or set TFR_ALLOW_LOW_ENTROPY_ENCRYPTION_KEY=true while rotating")
+	}
+	if previous != "" && len(previous) != 32 {
+		errs.Add("ENCRYPTION_KEY_PREVIOUS", "must be exactly 32 bytes, got %d", len(previous))
+	}
+}
+
+// checkDSN parses the connection string built from db the way the driver
+// will, without connecting. The password is masked in any parse error.
+func checkDSN(errs *config.ValidationErrors, field string, db *config.DatabaseConfig) {
+	if _, err := pq.NewConfig(db.GetDSN()); err != nil {
+		msg := err.Error()
+		if db.Password != "" {
+			msg = strings.ReplaceAll(msg, db.Password, "****")
+		}
+		errs.Add(field, "connection settings do not form a valid DSN: %s", msg)
+	}
+}
+
+// checkTLSKeyPair checks that the TLS certificate and key files exist and
+// hold a matching pair.
+func checkTLSKeyPair(errs *config.ValidationErrors, certFile, keyFile string) {
+	missing := false
+	for _, f := range []struct{ field, path string }{
+		{"security.tls.cert_file", certFile},
+		{"security.tls.key_file", keyFile},
+	} {
+		if _, err := os.Stat(f.path); err != nil {
+			errs.Add(f.field, "cannot read %q: %v", f.path, err)
+			missing = true
+		}
+	}
+	if missing {
+		return
+	}
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		errs.Add("security.tls", "cert_file and key_file are not a usable pair: %v", err)
+	}
+}
+
+// checkStorageBackend constructs the configured storage backend without
+// using it, which catches bad credentials settings and malformed endpoints.
+// The local backend is checked by path instead, since constructing it
+// creates its base directory.
+func checkStorageBackend(errs *config.ValidationErrors, cfg *config.Config) {
+	if cfg.Storage.DefaultBackend == "local" {
+		checkLocalStoragePath(errs, cfg.Storage.Local.BasePath)
+		return
+	}
+	backend, err := storage.NewStorage(cfg)
+	if err != nil {
+		errs.Add("storage."+cfg.Storage.DefaultBackend, "%v", err)
+		return
+	}
+	if closer, ok := backend.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+// checkLocalStoragePath reports a local storage base path that the server
+// could not create or use as a directory.
+func checkLocalStoragePath(errs *config.ValidationErrors, basePath string) {
+	for dir := filepath.Clean(basePath); ; dir = filepath.Dir(dir) {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				errs.Add("storage.local.base_path", "%q is not a directory", dir)
+			}
+			return
+		}
+		if !os.IsNotExist(err) {
+			errs.Add("storage.local.base_path", "cannot access %q: %v", dir, err)
+			return
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return
+		}
+	}
+}
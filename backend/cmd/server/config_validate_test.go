@@ -0,0 +1,214 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+func problemFields(errs config.ValidationErrors) string {
+	fields := make([]string, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	return strings.Join(fields, ",")
+}
+
+// ---------------------------------------------------------------------------
+// checkEncryptionKeys
+// ---------------------------------------------------------------------------
+
+func TestCheckEncryptionKeys(t *testing.T) {
+	const strongKey = "3f7a9c1e5b2d8046f1a7c3e9b5d2084f"
+	const weakKey = "kkkkkkkkkkkkkkkkkkkkkkkkkkkkkkkk"
+
+	tests := []struct {
+		name            string
+		current         string
+		previous        string
+		allowLowEntropy bool
+		wantFields      string
+	}{
+		{"valid", strongKey, "", false, ""},
+		{"valid with previous", strongKey, weakKey, false, ""},
+		{"missing", "", "", false, "ENCRYPTION_KEY"},
+		{"wrong length", strongKey[:16], "", false, "ENCRYPTION_KEY"},
+		{"low entropy", weakKey, "", false, "ENCRYPTION_KEY"},
+		{"low entropy with override", weakKey, "", true, ""},
+		{"previous wrong length", strongKey, "short", false, "ENCRYPTION_KEY_PREVIOUS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs config.ValidationErrors
+			checkEncryptionKeys(&errs, tt.current, tt.previous, tt.allowLowEntropy)
+			if got := problemFields(errs); got != tt.wantFields {
+				t.Errorf("problems = %v, want fields %q", errs, tt.wantFields)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// checkDSN
+// ---------------------------------------------------------------------------
+
+func TestCheckDSN(t *testing.T) {
+	valid := config.DatabaseConfig{Host: "db", Port: 5432, User: "registry", Password: "s3cret", Name: "registry", SSLMode: "disable"}
+	var errs config.ValidationErrors
+	checkDSN(&errs, "database", &valid)
+	if len(errs) != 0 {
+		t.Fatalf("valid DSN: problems = %v", errs)
+	}
+
+	badSSLMode := valid
+	badSSLMode.SSLMode = "sometimes"
+	checkDSN(&errs, "database", &badSSLMode)
+	if problemFields(errs) != "database" {
+		t.Fatalf("bad sslmode: problems = %v, want one for database", errs)
+	}
+
+	errs = nil
+	spacedPassword := valid
+	spacedPassword.Password = "pass word"
+	checkDSN(&errs, "identity_database", &spacedPassword)
+	if problemFields(errs) != "identity_database" {
+		t.Fatalf("unquoted password: problems = %v, want one for identity_database", errs)
+	}
+	if strings.Contains(errs[0].Message, "pass word") {
+		t.Errorf("message leaks the password: %q", errs[0].Message)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// checkTLSKeyPair
+// ---------------------------------------------------------------------------
+
+// writeKeyPair writes a self-signed certificate and its key to dir.
+func writeKeyPair(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "registry.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestCheckTLSKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	certA, keyA := writeKeyPair(t, dir, "a")
+	_, keyB := writeKeyPair(t, dir, "b")
+
+	tests := []struct {
+		name       string
+		cert, key  string
+		wantFields string
+	}{
+		{"matching pair", certA, keyA, ""},
+		{"key from another pair", certA, keyB, "security.tls"},
+		{"typo in both paths", certA + "x", keyA + "x", "security.tls.cert_file,security.tls.key_file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs config.ValidationErrors
+			checkTLSKeyPair(&errs, tt.cert, tt.key)
+			if got := problemFields(errs); got != tt.wantFields {
+				t.Errorf("problems = %v, want fields %q", errs, tt.wantFields)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// checkStorageBackend
+// ---------------------------------------------------------------------------
+
+func TestCheckStorageBackend_Local(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		basePath string
+		wantErr  bool
+	}{
+		{"existing directory", dir, false},
+		{"creatable directory", filepath.Join(dir, "a", "b"), false},
+		{"path is a file", file, true},
+		{"parent is a file", filepath.Join(file, "storage"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Storage: config.StorageConfig{DefaultBackend: "local", Local: config.LocalStorageConfig{BasePath: tt.basePath}}}
+			var errs config.ValidationErrors
+			checkStorageBackend(&errs, cfg)
+			if (len(errs) != 0) != tt.wantErr {
+				t.Errorf("problems = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a")); !os.IsNotExist(err) {
+		t.Error("checking the local backend created its base directory")
+	}
+}
+
+func TestCheckStorageBackend_DryConstruction(t *testing.T) {
+	cfg := &config.Config{Storage: config.StorageConfig{
+		DefaultBackend: "artifactory",
+		Artifactory:    config.ArtifactoryStorageConfig{BaseURL: "artifactory.example.com", Repository: "tf"},
+	}}
+	var errs config.ValidationErrors
+	checkStorageBackend(&errs, cfg)
+	if problemFields(errs) != "storage.artifactory" {
+		t.Errorf("problems = %v, want one for storage.artifactory", errs)
+	}
+}
+
+func TestCheckStartupConfig_SkipsStorageAlreadyReported(t *testing.T) {
+	t.Setenv("ENCRYPTION_KEY", "3f7a9c1e5b2d8046f1a7c3e9b5d2084f")
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Host: "db", Port: 5432, User: "u", Name: "n", SSLMode: "disable"},
+		Storage:  config.StorageConfig{DefaultBackend: "s3"},
+	}
+	cfg.IdentityDatabase = cfg.Database
+	errs := config.ValidationErrors{{Field: "storage.s3.bucket", Message: "is required when using S3 backend"}}
+	checkStartupConfig(cfg, &errs)
+	if len(errs) != 1 {
+		t.Errorf("problems = %v, want only the existing storage problem", errs)
+	}
+}
@@ -27,18 +27,26 @@
 // @tag.description  Prometheus metrics and profiling are served on a dedicated side-channel port (default: 9090) that is separate from the main API server. This keeps the scrape path off the public ingress and avoids rate-limiting middleware. Configure the port with TFR_TELEMETRY_METRICS_PROMETHEUS_PORT. The endpoint path is always GET /metrics. pprof (if enabled via TFR_TELEMETRY_PROFILING_ENABLED=true) is served on TFR_TELEMETRY_PROFILING_PORT (default: 6060) at the standard /debug/pprof/ paths. Neither endpoint is part of the OpenAPI spec because they are not served by the Gin router.
 
 // Package main is the entry point for the Terraform Registry server binary.
-// It dispatches subcommands — serve, migrate, version, upgrade, and scan-worker —
-// via a simple switch on os.Args so the binary's full CLI surface is readable in
-// one place without requiring a cobra dependency. The serve command runs
-// auto-migration on startup so freshly deployed containers never need a separate
-// migration step. The scan-worker command runs only the module security scanner
-// loop so scanning can scale horizontally on dedicated pods.
+// It dispatches subcommands — serve, migrate, version, upgrade, config,
+// scan-worker, mirror-resign, storage-relocate, and mirror-dedup — via a simple switch on
+// os.Args so the binary's full CLI surface is readable in one place without
+// requiring a cobra dependency.
+// The serve command validates the configuration and runs auto-migration on
+// startup so freshly deployed containers never need a separate migration step;
+// `config validate` runs the same validation alone, for CI pipelines. The
+// scan-worker command runs only the module security scanner loop so scanning can
+// scale horizontally on dedicated pods. The mirror-resign command re-signs a
+// mirror's provider versions with the mirror_signing key, storage-relocate
+// moves artifacts stored before the canonical key scheme to canonical keys, and
+// mirror-dedup moves mirrored provider archives onto shared content-addressed
+// blobs.
 package main
 
 import (
 	"context"
 	"crypto/rand"
 	"crypto/tls"
+	"database/sql"
 	"encoding/base64"
 	"fmt"
 	"log"
@@ -58,6 +66,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sethbacon/terraform-suite-identity/identity"
 	"github.com/terraform-registry/terraform-registry/internal/api"
@@ -68,6 +77,8 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/jobs"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/secrets"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/telemetry"
 	"golang.org/x/crypto/bcrypt"
@@ -93,8 +104,14 @@ func run() error {
 		command = os.Args[1]
 	}
 
-	// Load configuration
+	// config validate loads the configuration itself so it can report every
+	// problem instead of the first one Load fails on.
 	configPath := os.Getenv("CONFIG_PATH")
+	if command == "config" {
+		return runConfigValidate(configPath)
+	}
+
+	// Load configuration
 	cfg, err := config.Load(configPath)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -119,8 +136,14 @@ func run() error {
 		return runUpgrade(configPath)
 	case "scan-worker":
 		return scanWorker(cfg)
+	case "mirror-resign":
+		return mirrorResign(cfg)
+	case "storage-relocate":
+		return storageRelocate(cfg)
+	case "mirror-dedup":
+		return mirrorDedup(cfg)
 	default:
-		return fmt.Errorf("unknown command: %s\nAvailable commands: serve, migrate, version, upgrade, scan-worker", command)
+		return fmt.Errorf("unknown command: %s\nAvailable commands: serve, migrate, version, upgrade, config, scan-worker, mirror-resign, storage-relocate, mirror-dedup", command)
 	}
 }
 
@@ -199,6 +222,13 @@ func scanWorker(cfg *config.Config) error {
 	scanRepo := repositories.NewModuleScanRepository(database)
 	moduleRepo := repositories.NewModuleRepository(database)
 	scannerJob := jobs.NewModuleScannerJob(&cfg.Scanning, scanRepo, moduleRepo, storageBackend)
+	if cfg.Scratch.Dir != "" {
+		scratchSpace, err := scratch.New(cfg.Scratch.Dir, 0)
+		if err != nil {
+			return fmt.Errorf("failed to initialize scratch directory: %w", err)
+		}
+		scannerJob.SetScratch(scratchSpace)
+	}
 
 	// Handle SIGINT/SIGTERM for graceful shutdown (Kubernetes sends SIGTERM on
 	// pod termination). Cancelling ctx stops the scanner loop.
@@ -256,13 +286,21 @@ func serve(cfg *config.Config) error {
 		log.Println("WARNING: DEV_MODE is enabled. Unauthenticated admin-session minting")
 		log.Println("WARNING: endpoints (/api/v1/dev/login, /api/v1/dev/impersonate) are")
 		log.Println("WARNING: ACTIVE. This must never be set in a production deployment.")
+		log.Println("WARNING: Dev responses carry X-Registry-Dev-Mode and GET /version")
+		log.Println("WARNING: reports dev_endpoints_active=true for monitoring to alert on.")
 		log.Println("WARNING: ==========================================================")
+		slog.Warn("dev endpoints are active", "dev_mode", true, "environment", cfg.Server.Environment, "logging_level", cfg.Logging.Level)
 	}
 
 	// Refuse to boot entirely when DEV_MODE is combined with a production
 	// indicator, rather than relying on the warning above being noticed. See
-	// devModeProductionGuard's doc comment for why logging.level is the signal.
-	if err := devModeProductionGuard(devModeEnabled, cfg.Logging.Level, devModeNonProductionConfirmed()); err != nil {
+	// devModeProductionGuard's doc comment for the signals used.
+	if err := devModeProductionGuard(devModeEnabled, devModeSignals{
+		Environment:            cfg.Server.Environment,
+		LoggingLevel:           cfg.Logging.Level,
+		TLSEnabled:             cfg.Security.TLS.Enabled,
+		NonProductionConfirmed: devModeNonProductionConfirmed(),
+	}); err != nil {
 		return err
 	}
 
@@ -280,6 +318,15 @@ func serve(cfg *config.Config) error {
 	if len(cfg.Suite.TrustedIssuers) > 0 {
 		log.Printf("JWT trusted issuers extended for suite coupling: %v", cfg.Suite.TrustedIssuers) // #nosec G706 -- config values from trusted config file/env, not user input
 	}
+	auth.SetClockSkewLeeway(cfg.Auth.ClockSkewLeeway)
+
+	// Catch environment and file problems (encryption key, DSN, TLS files,
+	// storage backend) before connecting, instead of failing deep in NewRouter.
+	var startupProblems config.ValidationErrors
+	checkStartupConfig(cfg, &startupProblems)
+	if err := startupProblems.Err(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
 
 	// Debug: Print database configuration (mask password)
 	maskedPassword := "****"
@@ -296,12 +343,33 @@ func serve(cfg *config.Config) error {
 	// misleading "(masked)" label. The line above already logs every field with the
 	// password properly redacted.
 
+	// With secrets.refresh_interval set, the database password is re-fetched
+	// from the secrets backend; pools opened with it pick up a rotated
+	// password for new connections and drop their idle ones.
+	var dbPassword *secrets.Refresher
+	var rotatingPools []*sql.DB
+	if cfg.Secrets.RefreshInterval > 0 {
+		provider, pErr := cfg.Secrets.Provider(context.Background())
+		if pErr != nil {
+			return fmt.Errorf("secrets backend %q: %w", cfg.Secrets.Backend, pErr)
+		}
+		dbPassword = secrets.NewRefresher(provider, cfg.Secrets.DatabasePassword, cfg.Database.Password, cfg.Secrets.RefreshInterval,
+			func(string) {
+				for _, pool := range rotatingPools {
+					db.CloseIdleConnections(pool, cfg.Database.MinIdleConnections)
+				}
+			})
+	}
+
 	// Connect to database
-	database, err := db.Connect(cfg.Database.GetDSN(), cfg.Database.MaxConnections, cfg.Database.MinIdleConnections)
+	database, err := openDatabase(cfg.Database, "", dbPassword)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer database.Close()
+	if dbPassword != nil {
+		rotatingPools = append(rotatingPools, database)
+	}
 
 	log.Println("Connected to database successfully")
 
@@ -362,7 +430,12 @@ func serve(cfg *config.Config) error {
 		metricsAddr := fmt.Sprintf(":%d", cfg.Telemetry.Metrics.PrometheusPort)
 		go func() {
 			mux := http.NewServeMux()
-			mux.Handle("/metrics", promhttp.Handler())
+			// OpenMetrics is negotiated when the scraper asks for it; it is the
+			// only format that carries the latency histogram's exemplars.
+			mux.Handle("/metrics", promhttp.InstrumentMetricHandler(
+				prometheus.DefaultRegisterer,
+				promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+			))
 			slog.Info("starting Prometheus metrics server", "addr", metricsAddr)
 			// Use http.Server with timeouts (G114: bare http.ListenAndServe has no timeout support).
 			srv := &http.Server{
@@ -403,14 +476,20 @@ func serve(cfg *config.Config) error {
 	identityDB := database
 	if identitySchemaEnabled() {
 		searchPath := identitySchemaName() + ",public"
-		idb, connErr := db.Connect(
-			cfg.IdentityDatabase.GetDSNWithSearchPath(searchPath),
-			cfg.IdentityDatabase.MaxConnections, cfg.IdentityDatabase.MinIdleConnections,
-		)
+		// The identity pool rotates with the app database password only when
+		// it inherited that password.
+		identityRotation := dbPassword
+		if cfg.IdentityDatabase.Password != cfg.Database.Password {
+			identityRotation = nil
+		}
+		idb, connErr := openDatabase(cfg.IdentityDatabase, searchPath, identityRotation)
 		if connErr != nil {
 			return fmt.Errorf("failed to connect to identity schema: %w", connErr)
 		}
 		defer idb.Close()
+		if identityRotation != nil {
+			rotatingPools = append(rotatingPools, idb)
+		}
 		identityDB = idb
 		slog.Info("identity schema cutover enabled", "search_path", searchPath)
 
@@ -435,8 +514,19 @@ func serve(cfg *config.Config) error {
 		}
 	}
 
-	// Create router
-	router, bgServices := api.NewRouter(cfg, database, identityDB)
+	if dbPassword != nil {
+		refreshCtx, stopRefresh := context.WithCancel(context.Background())
+		defer stopRefresh()
+		go dbPassword.Run(refreshCtx)
+		slog.Info("database password rotation enabled", "backend", cfg.Secrets.Backend, "interval", cfg.Secrets.RefreshInterval)
+	}
+
+	// Create router. A failed initializer leaves nothing running; returning
+	// runs the deferred cleanup above (DB connections, password refresh).
+	router, bgServices, err := api.NewRouter(cfg, database, identityDB)
+	if err != nil {
+		return fmt.Errorf("failed to initialize router: %w", err)
+	}
 
 	// Start daily cleanup of expired JWT revocation entries (revoked_tokens is an
 	// identity table, so use the identity connection).
@@ -469,6 +559,16 @@ func serve(cfg *config.Config) error {
 	// Explicit floor instead of relying on crypto/tls defaults.
 	serverTLSConfig := &tls.Config{MinVersion: tls.VersionTLS12}
 
+	// A key pair resolved from the secrets backend (secrets.tls_cert /
+	// secrets.tls_key) is served from memory instead of cert_file/key_file.
+	if len(cfg.Security.TLS.KeyPEM) > 0 {
+		keyPair, kpErr := tls.X509KeyPair(cfg.Security.TLS.CertPEM, cfg.Security.TLS.KeyPEM)
+		if kpErr != nil {
+			return fmt.Errorf("TLS key pair from secrets backend: %w", kpErr)
+		}
+		serverTLSConfig.Certificates = []tls.Certificate{keyPair}
+	}
+
 	// Wire mTLS client-certificate verification into the TLS server (issue #559
 	// finding [3]). Without this, the mtls Provider/AuthMiddleware are dead
 	// code: c.Request.TLS.VerifiedChains is only ever populated by Go's TLS
@@ -514,7 +614,10 @@ func serve(cfg *config.Config) error {
 		log.Println("Server is ready to accept connections")
 
 		var err error
-		if cfg.Security.TLS.Enabled {
+		if cfg.Security.TLS.Enabled && len(serverTLSConfig.Certificates) > 0 {
+			log.Printf("TLS enabled: key pair from the %s secrets backend", cfg.Secrets.Backend) // #nosec G706 -- config values from trusted config file/env, not user input
+			err = server.ListenAndServeTLS("", "")
+		} else if cfg.Security.TLS.Enabled {
 			log.Printf("TLS enabled: cert=%s, key=%s", cfg.Security.TLS.CertFile, cfg.Security.TLS.KeyFile) // #nosec G706 -- config values from trusted config file/env, not user input
 			err = server.ListenAndServeTLS(cfg.Security.TLS.CertFile, cfg.Security.TLS.KeyFile)
 		} else {
@@ -538,6 +641,7 @@ func serve(cfg *config.Config) error {
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
+		bgServices.LogRunningOperations()
 		return fmt.Errorf("server forced to shutdown: %w", err)
 	}
 
@@ -568,7 +672,7 @@ func handleSetupToken(repo *repositories.OIDCConfigRepository) error {
 			return nil // Setup fully done, nothing to do
 		}
 		// Do NOT auto-mint a new setup token here by default (issue #649):
-		// SetSetupCompleted clears setup_token_hash, so on every restart with a
+		// CompleteSetup clears setup_token_hash, so on every restart with a
 		// pending optional feature (e.g. scanning left unconfigured -- a common,
 		// indefinite production state) this branch used to unconditionally
 		// generate and print a fresh token. SetupTokenMiddleware now scopes what
@@ -717,6 +821,23 @@ func devModeNonProductionConfirmed() bool {
 	return os.Getenv("TFR_CONFIRM_NON_PRODUCTION") == "true"
 }
 
+// devModeEnvironments are the server.environment values in which DEV_MODE
+// may run. Any other explicit value (staging, production) refuses to start.
+var devModeEnvironments = map[string]bool{"development": true, "test": true}
+
+// devModeSignals are the deployment properties devModeProductionGuard
+// inspects.
+type devModeSignals struct {
+	// Environment is server.environment; empty when not set.
+	Environment string
+	// LoggingLevel is logging.level.
+	LoggingLevel string
+	// TLSEnabled is security.tls.enabled.
+	TLSEnabled bool
+	// NonProductionConfirmed is TFR_CONFIRM_NON_PRODUCTION=true.
+	NonProductionConfirmed bool
+}
+
 // devModeProductionGuard refuses to start the server when DEV_MODE is enabled
 // outside of an unambiguous dev/test signal (issue #559 findings [5]/[11]).
 // DEV_MODE unconditionally exposes the unauthenticated /api/v1/dev/login
@@ -724,6 +845,20 @@ func devModeNonProductionConfirmed() bool {
 // warning above is easy to miss in aggregated production logs, so this makes
 // the misconfiguration a hard startup failure instead.
 //
+// The checks, in order:
+//
+//   - An explicit server.environment outside devModeEnvironments ("staging",
+//     "production") always refuses; TFR_CONFIRM_NON_PRODUCTION cannot
+//     override a deployment that says what it is.
+//   - A non-debug logging.level together with TLS enabled always refuses:
+//     terminating TLS in-process is what publicly reachable deployments do,
+//     and a staging deployment has already been caught serving the dev
+//     endpoints publicly.
+//   - server.environment "development" or "test" is itself the
+//     non-production assertion, and allows DEV_MODE.
+//   - Otherwise logging.level must be "debug", or the operator must confirm
+//     with TFR_CONFIRM_NON_PRODUCTION=true.
+//
 // logging.level alone cannot distinguish dev from production: this repo's own
 // dev and test compose stacks run at logging.level=="info" (the config
 // default), which is indistinguishable from a plausible, deliberate
@@ -732,29 +867,70 @@ func devModeNonProductionConfirmed() bool {
 // common case (DEV_MODE=true with logging.level left at its "info" default)
 // sailed through unblocked. "debug" is the only logging.level this repo's own
 // manifests treat as unambiguously non-production; any other level requires
-// an explicit TFR_CONFIRM_NON_PRODUCTION=true acknowledgment. Deployments that
-// spin up this backend with DEV_MODE=true at a non-debug logging.level (this
-// repo's own docker-compose.yml/docker-compose.test.yml, and any downstream
-// repo's compose files that do the same) must set that variable.
+// an explicit assertion. Deployments that spin up this backend with
+// DEV_MODE=true at a non-debug logging.level (this repo's own
+// docker-compose.yml/docker-compose.test.yml, and any downstream repo's
+// compose files that do the same) must set server.environment or
+// TFR_CONFIRM_NON_PRODUCTION.
 //
-// devModeEnabled, loggingLevel, and nonProductionConfirmed are passed in
-// rather than read from os.Getenv/cfg directly so this stays a pure function,
-// independently unit-testable against arbitrary inputs.
-func devModeProductionGuard(devModeEnabled bool, loggingLevel string, nonProductionConfirmed bool) error {
-	if !devModeEnabled || loggingLevel == "debug" || nonProductionConfirmed {
+// The signals are passed in rather than read from os.Getenv/cfg directly so
+// this stays a pure function, independently unit-testable against arbitrary
+// inputs.
+func devModeProductionGuard(devModeEnabled bool, sig devModeSignals) error {
+	if !devModeEnabled {
+		return nil
+	}
+	if sig.Environment != "" && !devModeEnvironments[sig.Environment] {
+		return fmt.Errorf(
+			"refusing to start: DEV_MODE is enabled with server.environment=%q. DEV_MODE unconditionally "+
+				"exposes the unauthenticated /api/v1/dev/login admin-session-minting endpoint and may only run "+
+				"with server.environment \"development\" or \"test\". Unset DEV_MODE",
+			sig.Environment,
+		)
+	}
+	if sig.LoggingLevel != "debug" && sig.TLSEnabled {
+		return fmt.Errorf(
+			"refusing to start: DEV_MODE is enabled with TLS enabled and logging.level=%q, which looks like a "+
+				"publicly reachable deployment. DEV_MODE unconditionally exposes the unauthenticated "+
+				"/api/v1/dev/login admin-session-minting endpoint. Unset DEV_MODE, or for local TLS testing set "+
+				"logging.level to \"debug\"",
+			sig.LoggingLevel,
+		)
+	}
+	if devModeEnvironments[sig.Environment] || sig.LoggingLevel == "debug" || sig.NonProductionConfirmed {
 		return nil
 	}
 	return fmt.Errorf(
 		"refusing to start: DEV_MODE is enabled with logging.level=%q, which is not a reliable dev-only signal "+
 			"(this repo's own dev/test compose stacks also run at \"info\", the config default). DEV_MODE "+
 			"unconditionally exposes the unauthenticated /api/v1/dev/login admin-session-minting endpoint and "+
-			"must never run in a production deployment. Unset DEV_MODE, set logging.level to \"debug\", or if "+
-			"this really is a non-production deployment set TFR_CONFIRM_NON_PRODUCTION=true to acknowledge it "+
-			"explicitly",
-		loggingLevel,
+			"must never run in a production deployment. Unset DEV_MODE, set logging.level to \"debug\", set "+
+			"server.environment to \"development\" or \"test\", or if this really is a non-production "+
+			"deployment set TFR_CONFIRM_NON_PRODUCTION=true to acknowledge it explicitly",
+		sig.LoggingLevel,
 	)
 }
 
+// openDatabase connects to dc, optionally with search_path set. With a
+// non-nil rotation, every new connection uses the refresher's current
+// password instead of dc.Password.
+func openDatabase(dc config.DatabaseConfig, searchPath string, rotation *secrets.Refresher) (*sql.DB, error) {
+	dsn := func() string {
+		c := dc
+		if rotation != nil {
+			c.Password = rotation.Value()
+		}
+		if searchPath != "" {
+			return c.GetDSNWithSearchPath(searchPath)
+		}
+		return c.GetDSN()
+	}
+	if rotation == nil {
+		return db.Connect(dsn(), dc.MaxConnections, dc.MinIdleConnections)
+	}
+	return db.ConnectRotating(dsn, dc.MaxConnections, dc.MinIdleConnections)
+}
+
 // identitySchemaEnabled reports whether identity data (users, organizations, API
 // keys, OIDC config, audit logs, role templates, revoked tokens) is read/written
 // from the dedicated shared identity schema instead of the app's public schema.
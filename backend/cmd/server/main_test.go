@@ -64,30 +64,66 @@ func TestIsProductionLoggingLevel(t *testing.T) {
 // through).
 func TestDevModeProductionGuard_RefusesNonDebugWithoutConfirmation(t *testing.T) {
 	for _, level := range []string{"warn", "error", "info", ""} {
-		if err := devModeProductionGuard(true, level, false); err == nil {
-			t.Errorf("devModeProductionGuard(true, %q, false) = nil, want an error", level)
+		if err := devModeProductionGuard(true, devModeSignals{LoggingLevel: level}); err == nil {
+			t.Errorf("devModeProductionGuard(true, level %q) = nil, want an error", level)
 		}
 	}
 }
 
 func TestDevModeProductionGuard_AllowsDebugWithoutConfirmation(t *testing.T) {
-	if err := devModeProductionGuard(true, "debug", false); err != nil {
-		t.Errorf("devModeProductionGuard(true, \"debug\", false) = %v, want nil", err)
+	if err := devModeProductionGuard(true, devModeSignals{LoggingLevel: "debug"}); err != nil {
+		t.Errorf("devModeProductionGuard(true, level \"debug\") = %v, want nil", err)
 	}
 }
 
 func TestDevModeProductionGuard_AllowsNonDebugWithExplicitConfirmation(t *testing.T) {
 	for _, level := range []string{"warn", "error", "info", "", "debug"} {
-		if err := devModeProductionGuard(true, level, true); err != nil {
-			t.Errorf("devModeProductionGuard(true, %q, true) = %v, want nil", level, err)
+		if err := devModeProductionGuard(true, devModeSignals{LoggingLevel: level, NonProductionConfirmed: true}); err != nil {
+			t.Errorf("devModeProductionGuard(true, level %q, confirmed) = %v, want nil", level, err)
 		}
 	}
 }
 
 func TestDevModeProductionGuard_AllowsProductionWithoutDevMode(t *testing.T) {
 	for _, level := range []string{"warn", "error", "info", "debug"} {
-		if err := devModeProductionGuard(false, level, false); err != nil {
-			t.Errorf("devModeProductionGuard(false, %q, false) = %v, want nil", level, err)
+		sig := devModeSignals{Environment: "production", LoggingLevel: level, TLSEnabled: true}
+		if err := devModeProductionGuard(false, sig); err != nil {
+			t.Errorf("devModeProductionGuard(false, %+v) = %v, want nil", sig, err)
+		}
+	}
+}
+
+// An explicit production-like environment wins over every other signal,
+// including the operator confirmation.
+func TestDevModeProductionGuard_RefusesNonDevEnvironment(t *testing.T) {
+	for _, env := range []string{"production", "staging"} {
+		sig := devModeSignals{Environment: env, LoggingLevel: "debug", NonProductionConfirmed: true}
+		if err := devModeProductionGuard(true, sig); err == nil {
+			t.Errorf("devModeProductionGuard(true, %+v) = nil, want an error", sig)
+		}
+	}
+}
+
+func TestDevModeProductionGuard_RefusesTLSWithoutDebug(t *testing.T) {
+	for _, sig := range []devModeSignals{
+		{LoggingLevel: "info", TLSEnabled: true},
+		{LoggingLevel: "info", TLSEnabled: true, NonProductionConfirmed: true},
+		{Environment: "development", LoggingLevel: "warn", TLSEnabled: true},
+	} {
+		if err := devModeProductionGuard(true, sig); err == nil {
+			t.Errorf("devModeProductionGuard(true, %+v) = nil, want an error", sig)
+		}
+	}
+	if err := devModeProductionGuard(true, devModeSignals{LoggingLevel: "debug", TLSEnabled: true}); err != nil {
+		t.Errorf("devModeProductionGuard with TLS at debug = %v, want nil", err)
+	}
+}
+
+func TestDevModeProductionGuard_AllowsDevEnvironment(t *testing.T) {
+	for _, env := range []string{"development", "test"} {
+		sig := devModeSignals{Environment: env, LoggingLevel: "info"}
+		if err := devModeProductionGuard(true, sig); err != nil {
+			t.Errorf("devModeProductionGuard(true, %+v) = %v, want nil", sig, err)
 		}
 	}
 }
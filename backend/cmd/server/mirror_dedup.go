@@ -0,0 +1,77 @@
+// Package main — mirror_dedup.go implements the `mirror-dedup` subcommand,
+// which moves mirrored provider archives stored before content-addressed
+// storage onto shared blobs (see services.MirrorBlobs), deletes the objects
+// they leave behind and reports the bytes reclaimed. It is a dry run unless
+// --apply is given.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+)
+
+// mirrorDedup runs the dedup with the flags in os.Args[2:] and prints the
+// report as JSON. It fails when any archive could not be moved.
+func mirrorDedup(cfg *config.Config) error {
+	var apply bool
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--apply":
+			apply = true
+		default:
+			return fmt.Errorf("usage: %s mirror-dedup [--apply]", os.Args[0])
+		}
+	}
+
+	telemetry.SetupLogger(cfg.Logging.Format, cfg.Logging.Level)
+
+	// Like scan-worker, this command shares the API server's schema and must
+	// not run migrations.
+	database, err := db.Connect(cfg.Database.GetDSN(), cfg.Database.MaxConnections, cfg.Database.MinIdleConnections)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	storageBackend, err := storage.NewStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage backend: %w", err)
+	}
+	// Honor artifact immutability like the API server does.
+	storageBackend = storage.NewImmutableStorage(storageBackend,
+		services.NewArtifactImmutability(&cfg.ImmutableArtifacts, repositories.NewArtifactImmutabilityRepository(database)))
+
+	blobs := services.NewMirrorBlobs(repositories.NewMirrorBlobRepository(database), storageBackend)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("mirror-dedup: starting", "apply", apply)
+	report, err := blobs.Dedup(ctx, apply)
+	if report != nil {
+		out, jsonErr := json.MarshalIndent(report, "", "  ")
+		if jsonErr != nil {
+			return jsonErr
+		}
+		fmt.Println(string(out))
+	}
+	if err != nil {
+		return fmt.Errorf("mirror dedup failed: %w", err)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d mirrored archives could not be deduplicated", report.Failed, report.Platforms)
+	}
+	return nil
+}
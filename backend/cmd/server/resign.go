@@ -0,0 +1,94 @@
+// Package main — resign.go implements the `mirror-resign` subcommand, the CLI
+// counterpart of POST /api/v1/admin/mirrors/:id/resign. It re-signs every
+// GPG-verified provider version synced by a mirror with the mirror_signing key,
+// e.g. from a one-off job after enabling re-signing or rotating the key.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+)
+
+// mirrorResign re-signs the versions of the mirror named by os.Args[2] and
+// prints the summary as JSON. It fails when any version could not be signed.
+func mirrorResign(cfg *config.Config) error {
+	if len(os.Args) < 3 {
+		return fmt.Errorf("usage: %s mirror-resign <mirror-id>", os.Args[0])
+	}
+	mirrorID, err := uuid.Parse(os.Args[2])
+	if err != nil {
+		return fmt.Errorf("invalid mirror ID %q: %w", os.Args[2], err)
+	}
+	if !cfg.MirrorSigning.Enabled {
+		return fmt.Errorf("mirror re-signing is disabled; set mirror_signing.enabled (TFR_MIRROR_SIGNING_ENABLED)")
+	}
+
+	telemetry.SetupLogger(cfg.Logging.Format, cfg.Logging.Level)
+
+	egressGuard, err := httpsafe.NewGuard(cfg.Security.Egress.Allowlist)
+	if err != nil {
+		return fmt.Errorf("invalid security.egress.allowlist: %w", err)
+	}
+	signer, err := mirror.NewSigner(cfg.MirrorSigning, egressGuard)
+	if err != nil {
+		return fmt.Errorf("failed to initialize mirror signing key: %w", err)
+	}
+
+	// Like scan-worker, this command shares the API server's schema and must
+	// not run migrations.
+	database, err := db.Connect(cfg.Database.GetDSN(), cfg.Database.MaxConnections, cfg.Database.MinIdleConnections)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	storageBackend, err := storage.NewStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage backend: %w", err)
+	}
+	// Honor artifact immutability like the API server does.
+	storageBackend = storage.NewImmutableStorage(storageBackend,
+		services.NewArtifactImmutability(&cfg.ImmutableArtifacts, repositories.NewArtifactImmutabilityRepository(database)))
+
+	resigner := services.NewProviderResigner(
+		repositories.NewProviderRepository(database),
+		repositories.NewMirrorRepository(sqlx.NewDb(database, "postgres")),
+		storageBackend, signer,
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("mirror-resign: starting", "mirror_id", mirrorID, "key_id", signer.KeyID())
+	summary, err := resigner.ResignMirror(ctx, mirrorID)
+	if err != nil {
+		return fmt.Errorf("failed to re-sign mirror %s: %w", mirrorID, err)
+	}
+
+	out, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d versions could not be re-signed", summary.Failed, summary.Versions)
+	}
+	return nil
+}
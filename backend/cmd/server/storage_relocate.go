@@ -0,0 +1,82 @@
+// Package main — storage_relocate.go implements the `storage-relocate`
+// subcommand, which moves stored artifacts written before the canonical
+// storage key scheme to their canonical keys (see storage.CanonicalKey) and
+// reports keys that collide on case-insensitive backends. It is a dry run
+// unless --apply is given.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+)
+
+// storageRelocate runs the relocation with the flags in os.Args[2:] and prints
+// the report as JSON. It fails when any artifact could not be relocated.
+func storageRelocate(cfg *config.Config) error {
+	var apply, deleteOld bool
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--apply":
+			apply = true
+		case "--delete-old":
+			deleteOld = true
+		default:
+			return fmt.Errorf("usage: %s storage-relocate [--apply] [--delete-old]", os.Args[0])
+		}
+	}
+	if deleteOld && !apply {
+		return fmt.Errorf("--delete-old requires --apply")
+	}
+
+	telemetry.SetupLogger(cfg.Logging.Format, cfg.Logging.Level)
+
+	// Like scan-worker, this command shares the API server's schema and must
+	// not run migrations.
+	database, err := db.Connect(cfg.Database.GetDSN(), cfg.Database.MaxConnections, cfg.Database.MinIdleConnections)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	storageBackend, err := storage.NewStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage backend: %w", err)
+	}
+	// Honor artifact immutability like the API server does.
+	storageBackend = storage.NewImmutableStorage(storageBackend,
+		services.NewArtifactImmutability(&cfg.ImmutableArtifacts, repositories.NewArtifactImmutabilityRepository(database)))
+
+	relocator := services.NewStorageKeyRelocator(repositories.NewStorageKeyRepository(database), storageBackend)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("storage-relocate: starting", "apply", apply, "delete_old", deleteOld)
+	report, err := relocator.Run(ctx, apply, deleteOld)
+	if report != nil {
+		out, jsonErr := json.MarshalIndent(report, "", "  ")
+		if jsonErr != nil {
+			return jsonErr
+		}
+		fmt.Println(string(out))
+	}
+	if err != nil {
+		return fmt.Errorf("storage relocation failed: %w", err)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d artifacts could not be relocated", report.Failed, report.Artifacts)
+	}
+	return nil
+}
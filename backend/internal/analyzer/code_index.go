@@ -0,0 +1,170 @@
+// code_index.go extracts what code search indexes from a module's .tf files:
+// its resources, data sources, variables and module calls, each with the file
+// and lines it was declared on and the block's source text.
+package analyzer
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Kinds of code symbol, named after the block type they are declared with.
+const (
+	CodeSymbolResource = "resource"
+	CodeSymbolData     = "data"
+	CodeSymbolVariable = "variable"
+	CodeSymbolModule   = "module"
+)
+
+// CodeSymbolKinds lists every kind of code symbol.
+var CodeSymbolKinds = []string{CodeSymbolResource, CodeSymbolData, CodeSymbolVariable, CodeSymbolModule}
+
+// MaxCodeSymbolBodyBytes caps the source text kept for one block. A longer
+// block keeps its first lines up to the cap.
+const MaxCodeSymbolBodyBytes = 8 << 10
+
+// CodeSymbol is one block of a module's configuration.
+type CodeSymbol struct {
+	Kind string
+	// Symbol is the resource or data source type, the variable name or the
+	// module call name.
+	Symbol string
+	// Label is the resource or data source name; empty for other kinds.
+	Label string
+	// Source is a module call's source, when it is a literal string.
+	Source string
+	// FilePath is slash-separated and relative to the indexed directory.
+	FilePath  string
+	StartLine int
+	EndLine   int
+	Body      string
+}
+
+// CodeIndex is what IndexCode extracted from a directory.
+type CodeIndex struct {
+	Symbols []CodeSymbol
+	Files   int
+	Bytes   int64
+	// Truncated is set when the .tf files exceed the byte cap; the files
+	// past it are not indexed.
+	Truncated bool
+}
+
+// IndexCode extracts the code symbols of every .tf file under dir, in lexical
+// path order. Hidden directories such as .terraform are skipped. Indexing
+// stops at the first file that would take the total size over maxBytes (0
+// means no cap). A file that does not parse cleanly contributes the blocks
+// that did parse.
+func IndexCode(dir string, maxBytes int64) (*CodeIndex, error) {
+	idx := &CodeIndex{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || filepath.Ext(path) != ".tf" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if maxBytes > 0 && idx.Bytes+info.Size() > maxBytes {
+			idx.Truncated = true
+			return filepath.SkipAll
+		}
+		src, err := os.ReadFile(path) // #nosec G304 -- path is found by walking the extracted archive
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		idx.Files++
+		idx.Bytes += int64(len(src))
+		idx.Symbols = append(idx.Symbols, codeSymbols(filepath.ToSlash(rel), src)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// codeSymbols returns the indexed blocks of one file.
+func codeSymbols(relPath string, src []byte) []CodeSymbol {
+	file, _ := hclsyntax.ParseConfig(src, relPath, hcl.InitialPos)
+	if file == nil {
+		return nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	var symbols []CodeSymbol
+	for _, b := range body.Blocks {
+		s := CodeSymbol{Kind: b.Type, FilePath: relPath}
+		switch b.Type {
+		case CodeSymbolResource, CodeSymbolData:
+			if len(b.Labels) != 2 {
+				continue
+			}
+			s.Symbol, s.Label = b.Labels[0], b.Labels[1]
+		case CodeSymbolVariable:
+			if len(b.Labels) != 1 {
+				continue
+			}
+			s.Symbol = b.Labels[0]
+		case CodeSymbolModule:
+			if len(b.Labels) != 1 {
+				continue
+			}
+			s.Symbol = b.Labels[0]
+			if attr, ok := b.Body.Attributes["source"]; ok {
+				if tmpl, ok := attr.Expr.(*hclsyntax.TemplateExpr); ok && tmpl.IsStringLiteral() {
+					if v, diags := tmpl.Value(nil); !diags.HasErrors() {
+						s.Source = v.AsString()
+					}
+				}
+			}
+		default:
+			continue
+		}
+
+		rng := b.Range()
+		s.StartLine, s.EndLine = rng.Start.Line, rng.End.Line
+		s.Body = blockBody(src, rng)
+		symbols = append(symbols, s)
+	}
+	return symbols
+}
+
+// blockBody returns the source text of the block at rng, cut after the last
+// whole line within MaxCodeSymbolBodyBytes. NUL bytes and invalid UTF-8 are
+// dropped, since PostgreSQL text columns reject them.
+func blockBody(src []byte, rng hcl.Range) string {
+	start, end := rng.Start.Byte, rng.End.Byte
+	if start < 0 || end > len(src) || start > end {
+		return ""
+	}
+	text := src[start:end]
+	if len(text) > MaxCodeSymbolBodyBytes {
+		text = text[:MaxCodeSymbolBodyBytes]
+		if i := strings.LastIndexByte(string(text), '\n'); i > 0 {
+			text = text[:i]
+		}
+	}
+	return strings.ToValidUTF8(strings.ReplaceAll(string(text), "\x00", ""), "")
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+const codeIndexMainTF = `variable "node_capacity_type" {
+  type    = string
+  default = "SPOT"
+}
+
+resource "aws_eks_cluster" "this" {
+  name = "demo"
+}
+
+data "aws_iam_policy_document" "assume" {}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+}
+
+module "dynamic" {
+  source = "${path.module}/modules/x"
+}
+
+output "id" {
+  value = aws_eks_cluster.this.id
+}
+`
+
+func TestIndexCode(t *testing.T) {
+	dir := t.TempDir()
+	writeTFFiles(t, dir, map[string]string{
+		"main.tf":                       codeIndexMainTF,
+		"modules/nodes/nodes.tf":        "resource \"aws_eks_node_group\" \"spot\" {\n  capacity_type = \"SPOT\"\n}\n",
+		"README.md":                     "resource \"ignored\" \"x\" {}\n",
+		".terraform/modules/x/cache.tf": "resource \"ignored\" \"x\" {}\n",
+	})
+
+	idx, err := IndexCode(dir, 0)
+	if err != nil {
+		t.Fatalf("IndexCode: %v", err)
+	}
+	if idx.Files != 2 || idx.Truncated {
+		t.Errorf("files = %d, truncated = %v; want 2, false", idx.Files, idx.Truncated)
+	}
+
+	var got []string
+	for _, s := range idx.Symbols {
+		got = append(got, s.Kind+":"+s.Symbol+":"+s.Label+":"+s.Source+"@"+s.FilePath)
+	}
+	want := []string{
+		"variable:node_capacity_type::@main.tf",
+		"resource:aws_eks_cluster:this:@main.tf",
+		"data:aws_iam_policy_document:assume:@main.tf",
+		"module:vpc::terraform-aws-modules/vpc/aws@main.tf",
+		"module:dynamic::@main.tf",
+		"resource:aws_eks_node_group:spot:@modules/nodes/nodes.tf",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("symbols:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	cluster := idx.Symbols[1]
+	if cluster.StartLine != 6 || cluster.EndLine != 8 {
+		t.Errorf("aws_eks_cluster lines = %d-%d, want 6-8", cluster.StartLine, cluster.EndLine)
+	}
+	if cluster.Body != "resource \"aws_eks_cluster\" \"this\" {\n  name = \"demo\"\n}" {
+		t.Errorf("aws_eks_cluster body = %q", cluster.Body)
+	}
+}
+
+func TestIndexCode_ByteCap(t *testing.T) {
+	dir := t.TempDir()
+	writeTFFiles(t, dir, map[string]string{
+		"a.tf": "variable \"a\" {}\n",
+		"b.tf": "variable \"b\" {}\n" + strings.Repeat("# padding\n", 20),
+	})
+
+	idx, err := IndexCode(dir, 64)
+	if err != nil {
+		t.Fatalf("IndexCode: %v", err)
+	}
+	if !idx.Truncated || idx.Files != 1 || len(idx.Symbols) != 1 || idx.Symbols[0].Symbol != "a" {
+		t.Errorf("IndexCode = %+v, want only a.tf and truncated", idx)
+	}
+}
+
+func TestIndexCode_InvalidFileKeepsParsedBlocks(t *testing.T) {
+	dir := t.TempDir()
+	writeTFFiles(t, dir, map[string]string{
+		"main.tf": "variable \"ok\" {}\n\nresource \"aws_instance\" {\n",
+	})
+
+	idx, err := IndexCode(dir, 0)
+	if err != nil {
+		t.Fatalf("IndexCode: %v", err)
+	}
+	if len(idx.Symbols) != 1 || idx.Symbols[0].Symbol != "ok" {
+		t.Errorf("symbols = %+v, want the variable only", idx.Symbols)
+	}
+}
+
+func TestBlockBody_Capped(t *testing.T) {
+	src := "resource \"a\" \"b\" {\n" + strings.Repeat("  tags = {}\n", MaxCodeSymbolBodyBytes/8) + "}\n"
+	file := codeSymbols("main.tf", []byte(src))
+	if len(file) != 1 {
+		t.Fatalf("symbols = %d, want 1", len(file))
+	}
+	body := file[0].Body
+	if len(body) > MaxCodeSymbolBodyBytes || strings.HasSuffix(body, "  tags") || !strings.HasSuffix(body, "tags = {}") {
+		t.Errorf("body is %d bytes ending %q; want whole lines within the cap", len(body), body[len(body)-20:])
+	}
+}
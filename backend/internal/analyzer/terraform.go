@@ -7,21 +7,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform-config-inspect/tfconfig"
 	"github.com/terraform-registry/terraform-registry/internal/archiver"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
 
 // ModuleDoc holds structured documentation extracted from a Terraform module.
+// Submodules is only set for a module root and is served by the submodule
+// endpoints rather than with the root's docs.
 type ModuleDoc struct {
-	Inputs       []InputVar    `json:"inputs"`
-	Outputs      []OutputVal   `json:"outputs"`
-	Providers    []ProviderReq `json:"providers"`
-	Requirements *Requirements `json:"requirements,omitempty"`
+	Inputs       []InputVar     `json:"inputs"`
+	Outputs      []OutputVal    `json:"outputs"`
+	Providers    []ProviderReq  `json:"providers"`
+	Requirements *Requirements  `json:"requirements,omitempty"`
+	Submodules   []SubmoduleDoc `json:"-"`
+}
+
+// SubmoduleDoc documents one submodule under the root's modules/ directory.
+// Error is set when the submodule's configuration could not be parsed
+// cleanly; whatever could be parsed is still included.
+type SubmoduleDoc struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Readme string `json:"readme,omitempty"`
+	Error  string `json:"error,omitempty"`
+	ModuleDoc
 }
 
 // InputVar represents a Terraform input variable.
@@ -52,28 +69,59 @@ type Requirements struct {
 	RequiredVersion string `json:"required_version,omitempty"`
 }
 
-// AnalyzeDir parses Terraform files in moduleDir and returns structured metadata.
-// Uses tfconfig.LoadModule which tolerates partial/incomplete modules.
+// RequiredTerraformVersion returns the root module's required_version
+// constraint, or nil when the module declares none or the declaration is not a
+// valid version constraint. Safe to call on a nil doc.
+func (d *ModuleDoc) RequiredTerraformVersion() *string {
+	if d == nil || d.Requirements == nil || d.Requirements.RequiredVersion == "" {
+		return nil
+	}
+	constraint := d.Requirements.RequiredVersion
+	if err := validation.ValidateVersionConstraint(constraint); err != nil {
+		slog.Debug("ignoring unparseable required_version", "constraint", constraint, "error", err)
+		return nil
+	}
+	return &constraint
+}
+
+// AnalyzeDir parses Terraform files in moduleDir and returns structured metadata,
+// including that of each submodule under moduleDir/modules (see
+// analyzeSubmodules). Uses tfconfig.LoadModule which tolerates
+// partial/incomplete modules.
 // Returns (nil, nil) if the directory has no .tf files.
+func AnalyzeDir(moduleDir string) (*ModuleDoc, error) {
+	doc, diags, err := analyzeModule(moduleDir)
+	if doc == nil || err != nil {
+		return nil, err
+	}
+	if diags != "" {
+		// Partial parse is common with missing providers; log and continue.
+		slog.Debug("terraform-config-inspect: parse diagnostics",
+			"dir", moduleDir, "diags", diags)
+	}
+	doc.Submodules = analyzeSubmodules(moduleDir)
+	return doc, nil
+}
+
+// analyzeModule parses the Terraform files directly in dir. diags holds the
+// parse errors of a partial parse, if any.
 //
 // A deferred recover() guards against panics from terraform-config-inspect (e.g.
 // variables whose default values cannot be serialised as JSON, such as Infinity).
 // Those are converted to errors so callers never observe a panic.
-func AnalyzeDir(moduleDir string) (doc *ModuleDoc, err error) {
+func analyzeModule(dir string) (doc *ModuleDoc, diags string, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("terraform-config-inspect panic: %v", r)
 			doc = nil
 		}
 	}()
-	module, diags := tfconfig.LoadModule(moduleDir)
+	module, moduleDiags := tfconfig.LoadModule(dir)
 	if module == nil {
-		return nil, nil
+		return nil, "", nil
 	}
-	if diags.HasErrors() {
-		// Partial parse is common with missing providers; log and continue.
-		slog.Debug("terraform-config-inspect: parse diagnostics",
-			"dir", moduleDir, "diags", diags.Error())
+	if moduleDiags.HasErrors() {
+		diags = moduleDiags.Error()
 	}
 
 	doc = &ModuleDoc{
@@ -117,18 +165,112 @@ func AnalyzeDir(moduleDir string) (doc *ModuleDoc, err error) {
 		}
 	}
 
-	return doc, nil
+	return doc, diags, nil
+}
+
+// submoduleDir is the directory, relative to a module root, whose
+// subdirectories the public registry documents as submodules.
+const submoduleDir = "modules"
+
+// maxSubmoduleReadmeSize caps the README read for each submodule, like the
+// root README (validation.ExtractReadme).
+const maxSubmoduleReadmeSize = 1024 * 1024
+
+// submoduleReadmeNames lists the README file names looked for in a
+// submodule, highest priority first.
+var submoduleReadmeNames = []string{"README.md", "README", "README.txt"}
+
+// analyzeSubmodules documents each directory directly under
+// moduleDir/modules, sorted by name. Only one level is walked (nested
+// modules/x/modules/y are not submodules of the root), symlinks are skipped so
+// nothing outside the module is read, and directories without Terraform files
+// are ignored. A submodule that fails to parse is still listed, with Error
+// set.
+func analyzeSubmodules(moduleDir string) []SubmoduleDoc {
+	entries, err := os.ReadDir(filepath.Join(moduleDir, submoduleDir))
+	if err != nil {
+		return nil
+	}
+	var subs []SubmoduleDoc
+	for _, e := range entries {
+		// ReadDir reports the entry itself, so a symlinked directory is a
+		// symlink here, not a directory.
+		if !e.IsDir() || e.Type()&fs.ModeSymlink != 0 {
+			continue
+		}
+		dir := filepath.Join(moduleDir, submoduleDir, e.Name())
+		if tfs, _ := filepath.Glob(filepath.Join(dir, "*.tf")); len(tfs) == 0 {
+			continue
+		}
+		sub := SubmoduleDoc{
+			Name:   e.Name(),
+			Path:   submoduleDir + "/" + e.Name(),
+			Readme: readSubmoduleReadme(dir),
+		}
+		doc, diags, err := analyzeModule(dir)
+		switch {
+		case err != nil:
+			sub.Error = err.Error()
+		case diags != "":
+			sub.Error = diags
+		}
+		if doc != nil {
+			sub.ModuleDoc = *doc
+		} else {
+			sub.ModuleDoc = ModuleDoc{Inputs: []InputVar{}, Outputs: []OutputVal{}, Providers: []ProviderReq{}}
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// readSubmoduleReadme returns the README in dir, matched case-insensitively in
+// submoduleReadmeNames order, or "" when there is none. Symlinks are ignored.
+func readSubmoduleReadme(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, want := range submoduleReadmeNames {
+		for _, e := range entries {
+			if !e.Type().IsRegular() || !strings.EqualFold(e.Name(), want) {
+				continue
+			}
+			f, err := os.Open(filepath.Join(dir, e.Name())) // #nosec G304 -- regular file inside the extracted module
+			if err != nil {
+				return ""
+			}
+			content, err := io.ReadAll(io.LimitReader(f, maxSubmoduleReadmeSize))
+			f.Close()
+			if err != nil {
+				return ""
+			}
+			return string(content)
+		}
+	}
+	return ""
 }
 
 // AnalyzeArchive extracts a tar.gz archive from reader and calls AnalyzeDir
 // on the module root.  The reader must be seekable (os.File satisfies this).
 // The temporary directory is removed on return.
 func AnalyzeArchive(reader io.ReadSeeker) (*ModuleDoc, error) {
+	return AnalyzeArchiveIn("", reader)
+}
+
+// AnalyzeArchiveIn is AnalyzeArchive with the archive extracted under dir
+// (the OS temporary directory when dir is empty).
+func AnalyzeArchiveIn(dir string, reader io.ReadSeeker) (*ModuleDoc, error) {
 	if _, err := reader.Seek(0, io.SeekStart); err != nil {
 		return nil, fmt.Errorf("seek archive: %w", err)
 	}
+	return AnalyzeArchiveStreamIn(dir, reader)
+}
 
-	tmpDir, err := os.MkdirTemp("", "tfdocs-*")
+// AnalyzeArchiveStreamIn is AnalyzeArchiveIn for a reader positioned at the
+// start of the archive that cannot seek, such as an upload being streamed.
+func AnalyzeArchiveStreamIn(dir string, reader io.Reader) (*ModuleDoc, error) {
+	tmpDir, err := os.MkdirTemp(dir, "tfdocs-*")
 	if err != nil {
 		return nil, fmt.Errorf("mkdirtemp: %w", err)
 	}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -249,6 +250,33 @@ terraform {
 	}
 }
 
+func TestModuleDoc_RequiredTerraformVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  *ModuleDoc
+		want string
+	}{
+		{"nil doc", nil, ""},
+		{"no requirements", &ModuleDoc{}, ""},
+		{"valid constraint", &ModuleDoc{Requirements: &Requirements{RequiredVersion: ">= 1.5.0, < 2.0.0"}}, ">= 1.5.0, < 2.0.0"},
+		{"unparseable constraint", &ModuleDoc{Requirements: &Requirements{RequiredVersion: "latest"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.doc.RequiredTerraformVersion()
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("RequiredTerraformVersion() = %q, want nil", *got)
+				}
+				return
+			}
+			if got == nil || *got != tt.want {
+				t.Errorf("RequiredTerraformVersion() = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAnalyzeDir_SortedAlphabetically(t *testing.T) {
 	dir := t.TempDir()
 	writeTFFiles(t, dir, map[string]string{
@@ -289,6 +317,95 @@ func TestAnalyzeDir_NonExistent(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Submodules
+// ---------------------------------------------------------------------------
+
+func TestAnalyzeDir_Submodules(t *testing.T) {
+	doc, err := AnalyzeDir("testdata/submodules")
+	if err != nil {
+		t.Fatalf("AnalyzeDir: %v", err)
+	}
+	// modules/examples-only has no .tf files; modules/network/modules/subnet
+	// is nested one level too deep.
+	if len(doc.Submodules) != 2 {
+		t.Fatalf("submodules = %+v, want broken and network", doc.Submodules)
+	}
+
+	broken, network := doc.Submodules[0], doc.Submodules[1]
+	if broken.Name != "broken" || broken.Error == "" {
+		t.Errorf("broken = %+v, want an error marker", broken)
+	}
+
+	if network.Name != "network" || network.Path != "modules/network" || network.Error != "" {
+		t.Errorf("network = %+v", network)
+	}
+	if len(network.Inputs) != 2 || network.Inputs[0].Name != "cidr" || network.Inputs[1].Name != "name" {
+		t.Errorf("network inputs = %+v", network.Inputs)
+	}
+	if len(network.Outputs) != 1 || network.Outputs[0].Description != "ID of the VPC." {
+		t.Errorf("network outputs = %+v", network.Outputs)
+	}
+	if len(network.Providers) != 1 || network.Providers[0].Source != "hashicorp/aws" {
+		t.Errorf("network providers = %+v", network.Providers)
+	}
+	if !strings.Contains(network.Readme, "Creates the VPC.") {
+		t.Errorf("network readme = %q", network.Readme)
+	}
+	if network.Submodules != nil {
+		t.Errorf("nested submodules were walked: %+v", network.Submodules)
+	}
+}
+
+func TestAnalyzeDir_SubmoduleSymlinkSkipped(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	writeTFFiles(t, dir, map[string]string{
+		"main.tf":              `variable "root" {}`,
+		"modules/real/main.tf": `variable "real" {}`,
+	})
+	writeTFFiles(t, outside, map[string]string{"main.tf": `variable "secret" {}`})
+	if err := os.Symlink(outside, filepath.Join(dir, "modules", "linked")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	doc, err := AnalyzeDir(dir)
+	if err != nil {
+		t.Fatalf("AnalyzeDir: %v", err)
+	}
+	if len(doc.Submodules) != 1 || doc.Submodules[0].Name != "real" {
+		t.Errorf("submodules = %+v, want only real", doc.Submodules)
+	}
+}
+
+func TestAnalyzeDir_NoSubmodules(t *testing.T) {
+	dir := t.TempDir()
+	writeTFFiles(t, dir, map[string]string{"main.tf": `variable "root" {}`})
+	doc, err := AnalyzeDir(dir)
+	if err != nil {
+		t.Fatalf("AnalyzeDir: %v", err)
+	}
+	if doc.Submodules != nil {
+		t.Errorf("submodules = %+v, want none", doc.Submodules)
+	}
+}
+
+func TestAnalyzeArchive_WrappedArchiveSubmodules(t *testing.T) {
+	data := buildTarGzWrapped(t, "repo-main", map[string]string{
+		"main.tf":                            `variable "root" {}`,
+		"modules/iam/main.tf":                `variable "role" {}`,
+		"modules/iam/readme.md":              "IAM roles",
+		"modules/iam/modules/policy/main.tf": `variable "policy" {}`,
+	})
+	doc, err := AnalyzeArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("AnalyzeArchive: %v", err)
+	}
+	if len(doc.Submodules) != 1 || doc.Submodules[0].Name != "iam" || doc.Submodules[0].Readme != "IAM roles" {
+		t.Errorf("submodules = %+v", doc.Submodules)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // AnalyzeArchive
 // ---------------------------------------------------------------------------
@@ -0,0 +1,13 @@
+variable "name" {
+  type        = string
+  description = "Name prefix for all resources."
+}
+
+module "network" {
+  source = "./modules/network"
+  name   = var.name
+}
+
+output "vpc_id" {
+  value = module.network.vpc_id
+}
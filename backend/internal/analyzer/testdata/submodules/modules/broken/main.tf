@@ -0,0 +1,6 @@
+variable "ok" {
+  type = string
+}
+
+variable "unterminated" {
+  type = string
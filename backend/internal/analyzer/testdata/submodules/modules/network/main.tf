@@ -0,0 +1,27 @@
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0"
+    }
+  }
+}
+
+variable "name" {
+  type        = string
+  description = "Name of the VPC."
+}
+
+variable "cidr" {
+  type    = string
+  default = "10.0.0.0/16"
+}
+
+module "subnet" {
+  source = "./modules/subnet"
+}
+
+output "vpc_id" {
+  description = "ID of the VPC."
+  value       = "vpc-123"
+}
@@ -0,0 +1,248 @@
+// apikey_policies.go implements the per-organization API key policy admin
+// endpoints: read/replace/remove an organization's policy, and a compliance
+// report listing existing keys that violate it. Enforcement on key creation,
+// update, and rotation lives in APIKeyHandlers.enforceKeyPolicy; tightening a
+// policy never revokes existing keys, it only surfaces them here.
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// APIKeyPolicyHandlers serves the organization API key policy endpoints.
+type APIKeyPolicyHandlers struct {
+	orgRepo    *repositories.OrganizationRepository
+	apiKeyRepo *repositories.APIKeyRepository
+	policyRepo *repositories.OrgAPIKeyPolicyRepository
+}
+
+// NewAPIKeyPolicyHandlers constructs an APIKeyPolicyHandlers. identityDB backs
+// organizations, members, and API keys; policyRepo runs on the registry's own
+// connection.
+func NewAPIKeyPolicyHandlers(identityDB *sql.DB, policyRepo *repositories.OrgAPIKeyPolicyRepository) *APIKeyPolicyHandlers {
+	return &APIKeyPolicyHandlers{
+		orgRepo:    repositories.NewOrganizationRepository(identityDB),
+		apiKeyRepo: repositories.NewAPIKeyRepository(identityDB),
+		policyRepo: policyRepo,
+	}
+}
+
+// UpdateAPIKeyPolicyRequest is the body of PUT /organizations/:id/api-key-policy.
+type UpdateAPIKeyPolicyRequest struct {
+	MaxLifetimeDays int `json:"max_lifetime_days"` // 0 = unlimited
+	// AllowedScopes restricts the scopes member keys may carry; omit or null
+	// for no restriction.
+	AllowedScopes  []string `json:"allowed_scopes"`
+	RequireExpiry  bool     `json:"require_expiry"`
+	MaxKeysPerUser int      `json:"max_keys_per_user"` // 0 = unlimited
+}
+
+// APIKeyComplianceEntry is one non-compliant key in a compliance report.
+type APIKeyComplianceEntry struct {
+	KeyID          string                         `json:"key_id"`
+	KeyName        string                         `json:"key_name"`
+	KeyPrefix      string                         `json:"key_prefix"`
+	UserID         string                         `json:"user_id"`
+	OrganizationID string                         `json:"organization_id"`
+	ExpiresAt      *time.Time                     `json:"expires_at"`
+	Violations     []models.APIKeyPolicyViolation `json:"violations"`
+}
+
+// APIKeyComplianceResponse is the compliance report for one organization.
+type APIKeyComplianceResponse struct {
+	OrganizationID string                  `json:"organization_id"`
+	Policy         *models.OrgAPIKeyPolicy `json:"policy"`
+	CheckedKeys    int                     `json:"checked_keys"`
+	Compliant      bool                    `json:"compliant"`
+	Violations     []APIKeyComplianceEntry `json:"violations"`
+}
+
+// @Summary      Get organization API key policy
+// @Description  Returns the organization's API key policy, or `policy: null` when none is set.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  map[string]interface{}  "{\"policy\": OrgAPIKeyPolicy|null}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/api-key-policy [get]
+// GetPolicyHandler returns an organization's API key policy.
+// GET /api/v1/organizations/:id/api-key-policy
+func (h *APIKeyPolicyHandlers) GetPolicyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, err := h.policyRepo.Get(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API key policy"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"policy": policy})
+	}
+}
+
+// @Summary      Set organization API key policy
+// @Description  Creates or replaces the organization's API key policy. The policy applies to keys whose owner is a member of the organization; when a user belongs to several organizations, the strictest value of each rule wins. Existing keys are not revoked — use the compliance endpoint to find them.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                     true  "Organization ID"
+// @Param        body  body  UpdateAPIKeyPolicyRequest  true  "Policy"
+// @Success      200  {object}  map[string]interface{}  "{\"policy\": OrgAPIKeyPolicy}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Organization not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/api-key-policy [put]
+// UpdatePolicyHandler creates or replaces an organization's API key policy.
+// PUT /api/v1/organizations/:id/api-key-policy
+func (h *APIKeyPolicyHandlers) UpdatePolicyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID := c.Param("id")
+
+		var req UpdateAPIKeyPolicyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+		if req.MaxLifetimeDays < 0 || req.MaxKeysPerUser < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_lifetime_days and max_keys_per_user must be >= 0"})
+			return
+		}
+		if req.AllowedScopes != nil {
+			if err := auth.ValidateScopes(req.AllowedScopes); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid allowed_scopes: " + err.Error()})
+				return
+			}
+		}
+
+		org, err := h.orgRepo.GetByID(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		policy := &models.OrgAPIKeyPolicy{
+			OrganizationID:  org.ID,
+			MaxLifetimeDays: req.MaxLifetimeDays,
+			AllowedScopes:   req.AllowedScopes,
+			RequireExpiry:   req.RequireExpiry,
+			MaxKeysPerUser:  req.MaxKeysPerUser,
+		}
+		if err := h.policyRepo.Upsert(c.Request.Context(), policy); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save API key policy"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"policy": policy})
+	}
+}
+
+// @Summary      Remove organization API key policy
+// @Description  Removes the organization's API key policy.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  admin.MessageResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "No policy set for this organization"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/api-key-policy [delete]
+// DeletePolicyHandler removes an organization's API key policy.
+// DELETE /api/v1/organizations/:id/api-key-policy
+func (h *APIKeyPolicyHandlers) DeletePolicyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deleted, err := h.policyRepo.Delete(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete API key policy"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No API key policy set for this organization"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "API key policy removed"})
+	}
+}
+
+// @Summary      API key policy compliance report
+// @Description  Evaluates every API key owned by a member of the organization against the organization's current API key policy and lists the keys that violate it. Keys are never revoked automatically.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  admin.APIKeyComplianceResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/api-key-policy/compliance [get]
+// ComplianceHandler reports existing keys that violate an organization's policy.
+// GET /api/v1/organizations/:id/api-key-policy/compliance
+func (h *APIKeyPolicyHandlers) ComplianceHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		orgID := c.Param("id")
+
+		policy, err := h.policyRepo.Get(ctx, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API key policy"})
+			return
+		}
+		resp := APIKeyComplianceResponse{
+			OrganizationID: orgID,
+			Policy:         policy,
+			Compliant:      true,
+			Violations:     []APIKeyComplianceEntry{},
+		}
+		if policy == nil {
+			c.JSON(http.StatusOK, resp)
+			return
+		}
+		effective := models.MergeAPIKeyPolicies([]*models.OrgAPIKeyPolicy{policy})
+
+		members, err := h.orgRepo.ListMembers(ctx, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization members"})
+			return
+		}
+		for _, m := range members {
+			keys, err := h.apiKeyRepo.ListByUser(ctx, m.UserID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys"})
+				return
+			}
+			for _, k := range keys {
+				resp.CheckedKeys++
+				violations := effective.Violations(k.Scopes, k.CreatedAt, k.ExpiresAt, len(keys))
+				if len(violations) == 0 {
+					continue
+				}
+				resp.Violations = append(resp.Violations, APIKeyComplianceEntry{
+					KeyID:          k.ID,
+					KeyName:        k.Name,
+					KeyPrefix:      k.KeyPrefix,
+					UserID:         m.UserID,
+					OrganizationID: k.OrganizationID,
+					ExpiresAt:      k.ExpiresAt,
+					Violations:     violations,
+				})
+			}
+		}
+		resp.Compliant = len(resp.Violations) == 0
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,366 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var keyPolicyCols = []string{
+	"organization_id", "max_lifetime_days", "allowed_scopes", "require_expiry", "max_keys_per_user", "created_at", "updated_at",
+}
+
+func keyPolicyRow(orgID string, maxDays int, scopes []byte, requireExpiry bool, maxKeys int) *sqlmock.Rows {
+	return sqlmock.NewRows(keyPolicyCols).AddRow(orgID, maxDays, scopes, requireExpiry, maxKeys, time.Now(), time.Now())
+}
+
+func membershipRows(orgIDs ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(membershipSQLCols)
+	for _, id := range orgIDs {
+		rows.AddRow(id, "org-"+id, nil, time.Now(), nil, nil, []byte(`[]`))
+	}
+	return rows
+}
+
+func newKeyPolicyRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewAPIKeyPolicyHandlers(db, repositories.NewOrgAPIKeyPolicyRepository(db))
+	r := gin.New()
+	r.GET("/organizations/:id/api-key-policy", h.GetPolicyHandler())
+	r.PUT("/organizations/:id/api-key-policy", h.UpdatePolicyHandler())
+	r.DELETE("/organizations/:id/api-key-policy", h.DeletePolicyHandler())
+	r.GET("/organizations/:id/api-key-policy/compliance", h.ComplianceHandler())
+	return mock, r
+}
+
+// newPolicyEnforcedAPIKeyRouter is newAPIKeyRouter with key policies enabled.
+func newPolicyEnforcedAPIKeyRouter(t *testing.T, userID string) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewAPIKeyHandlers(&config.Config{}, db).WithKeyPolicies(repositories.NewOrgAPIKeyPolicyRepository(db))
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	r.POST("/apikeys", h.CreateAPIKeyHandler())
+	r.PUT("/apikeys/:id", h.UpdateAPIKeyHandler())
+	r.POST("/apikeys/:id/rotate", h.RotateAPIKeyHandler())
+	return mock, r
+}
+
+// ---------------------------------------------------------------------------
+// Policy CRUD
+// ---------------------------------------------------------------------------
+
+func TestGetAPIKeyPolicy(t *testing.T) {
+	mock, r := newKeyPolicyRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_api_key_policies").
+		WillReturnRows(keyPolicyRow("org-1", 90, nil, true, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1/api-key-policy", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	policy, _ := getJSON(w)["policy"].(map[string]interface{})
+	if policy["max_lifetime_days"] != float64(90) {
+		t.Errorf("policy = %v", policy)
+	}
+}
+
+func TestGetAPIKeyPolicy_None(t *testing.T) {
+	mock, r := newKeyPolicyRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_api_key_policies").WillReturnRows(sqlmock.NewRows(keyPolicyCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1/api-key-policy", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if getJSON(w)["policy"] != nil {
+		t.Errorf("policy should be null, body=%s", w.Body.String())
+	}
+}
+
+func TestUpdateAPIKeyPolicy_Success(t *testing.T) {
+	mock, r := newKeyPolicyRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows(orgSQLCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO org_api_key_policies").
+		WithArgs("org-1", 90, []byte(`["modules:read"]`), true, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/org-1/api-key-policy", jsonBody(map[string]interface{}{
+		"max_lifetime_days": 90,
+		"allowed_scopes":    []string{"modules:read"},
+		"require_expiry":    true,
+		"max_keys_per_user": 3,
+	})))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateAPIKeyPolicy_Validation(t *testing.T) {
+	_, r := newKeyPolicyRouter(t)
+	for name, body := range map[string]map[string]interface{}{
+		"negative lifetime": {"max_lifetime_days": -1},
+		"negative key cap":  {"max_keys_per_user": -5},
+		"unknown scope":     {"allowed_scopes": []string{"not:a:scope"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/org-1/api-key-policy", jsonBody(body)))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateAPIKeyPolicy_OrgNotFound(t *testing.T) {
+	mock, r := newKeyPolicyRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sqlmock.NewRows(orgSQLCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/missing/api-key-policy", jsonBody(map[string]interface{}{})))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestDeleteAPIKeyPolicy(t *testing.T) {
+	mock, r := newKeyPolicyRouter(t)
+	mock.ExpectExec("DELETE FROM org_api_key_policies").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM org_api_key_policies").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/organizations/org-1/api-key-policy", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/organizations/org-1/api-key-policy", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Compliance report
+// ---------------------------------------------------------------------------
+
+func TestAPIKeyPolicyCompliance_ReportsViolations(t *testing.T) {
+	mock, r := newKeyPolicyRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_api_key_policies").
+		WillReturnRows(keyPolicyRow("org-1", 90, nil, false, 0))
+	mock.ExpectQuery("SELECT.*FROM organization_members").
+		WillReturnRows(sqlmock.NewRows(orgMemberCols).AddRow("org-1", "user-1", nil, time.Now()))
+	created := time.Now().Add(-24 * time.Hour)
+	withinPolicy := created.Add(30 * 24 * time.Hour)
+	mock.ExpectQuery("WHERE ak.user_id").
+		WillReturnRows(sqlmock.NewRows(akListCols).
+			AddRow("key-ok", "user-1", "org-1", "ok", nil, "h1", "tfr_ok", testKeyScopes, withinPolicy, nil, nil, created, nil).
+			AddRow("key-forever", "user-1", "org-1", "forever", nil, "h2", "tfr_ff", testKeyScopes, nil, nil, nil, created, nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1/api-key-policy/compliance", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	resp := getJSON(w)
+	if resp["compliant"] != false || resp["checked_keys"] != float64(2) {
+		t.Fatalf("unexpected report: %s", w.Body.String())
+	}
+	violations, _ := resp["violations"].([]interface{})
+	if len(violations) != 1 {
+		t.Fatalf("violations = %d, want 1", len(violations))
+	}
+	entry := violations[0].(map[string]interface{})
+	if entry["key_id"] != "key-forever" {
+		t.Errorf("key_id = %v, want key-forever", entry["key_id"])
+	}
+}
+
+func TestAPIKeyPolicyCompliance_NoPolicy(t *testing.T) {
+	mock, r := newKeyPolicyRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_api_key_policies").WillReturnRows(sqlmock.NewRows(keyPolicyCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1/api-key-policy/compliance", nil))
+	if w.Code != http.StatusOK || getJSON(w)["compliant"] != true {
+		t.Errorf("status = %d body=%s, want compliant", w.Code, w.Body.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Enforcement in CreateAPIKeyHandler / RotateAPIKeyHandler / UpdateAPIKeyHandler
+// ---------------------------------------------------------------------------
+
+func TestCreateAPIKey_PolicyRequiresExpiry(t *testing.T) {
+	mock, r := newPolicyEnforcedAPIKeyRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM organization_members.*WHERE").WillReturnRows(sampleMemberRoleRow())
+	mock.ExpectQuery("FROM organization_members om").WillReturnRows(membershipRows("org-1", "org-2"))
+	mock.ExpectQuery("FROM org_api_key_policies").
+		WillReturnRows(keyPolicyRow("org-2", 0, nil, true, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys", jsonBody(map[string]interface{}{
+		"name": "k", "organization_id": "org-1", "scopes": []string{"modules:read"},
+	})))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", w.Code, w.Body.String())
+	}
+	if rule := getJSON(w)["policy_rule"]; rule != "require_expiry" {
+		t.Errorf("policy_rule = %v, want require_expiry", rule)
+	}
+}
+
+func TestCreateAPIKey_StrictestLifetimeWins(t *testing.T) {
+	mock, r := newPolicyEnforcedAPIKeyRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM organization_members.*WHERE").WillReturnRows(sampleMemberRoleRow())
+	mock.ExpectQuery("FROM organization_members om").WillReturnRows(membershipRows("org-1", "org-2"))
+	mock.ExpectQuery("FROM org_api_key_policies").
+		WillReturnRows(sqlmock.NewRows(keyPolicyCols).
+			AddRow("org-1", 365, nil, false, 0, time.Now(), time.Now()).
+			AddRow("org-2", 30, nil, false, 0, time.Now(), time.Now()))
+
+	expires := time.Now().Add(60 * 24 * time.Hour).Format(time.RFC3339)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys", jsonBody(map[string]interface{}{
+		"name": "k", "organization_id": "org-1", "scopes": []string{"modules:read"}, "expires_at": expires,
+	})))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", w.Code, w.Body.String())
+	}
+	if rule := getJSON(w)["policy_rule"]; rule != "max_lifetime_days" {
+		t.Errorf("policy_rule = %v, want max_lifetime_days", rule)
+	}
+}
+
+func TestCreateAPIKey_PolicyMaxKeysPerUser(t *testing.T) {
+	mock, r := newPolicyEnforcedAPIKeyRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM organization_members.*WHERE").WillReturnRows(sampleMemberRoleRow())
+	mock.ExpectQuery("FROM organization_members om").WillReturnRows(membershipRows("org-1"))
+	mock.ExpectQuery("FROM org_api_key_policies").
+		WillReturnRows(keyPolicyRow("org-1", 0, nil, false, 1))
+	mock.ExpectQuery("WHERE ak.user_id").WillReturnRows(sampleAKListRow())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys", jsonBody(map[string]interface{}{
+		"name": "k", "organization_id": "org-1", "scopes": []string{"modules:read"},
+	})))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", w.Code, w.Body.String())
+	}
+	if rule := getJSON(w)["policy_rule"]; rule != "max_keys_per_user" {
+		t.Errorf("policy_rule = %v, want max_keys_per_user", rule)
+	}
+}
+
+func TestCreateAPIKey_PolicySatisfied(t *testing.T) {
+	mock, r := newPolicyEnforcedAPIKeyRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM organization_members.*WHERE").WillReturnRows(sampleMemberRoleRow())
+	mock.ExpectQuery("FROM organization_members om").WillReturnRows(membershipRows("org-1"))
+	mock.ExpectQuery("FROM org_api_key_policies").
+		WillReturnRows(keyPolicyRow("org-1", 90, []byte(`["modules:read"]`), true, 0))
+	mock.ExpectExec("INSERT INTO api_keys").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	expires := time.Now().Add(30 * 24 * time.Hour).Format(time.RFC3339)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys", jsonBody(map[string]interface{}{
+		"name": "k", "organization_id": "org-1", "scopes": []string{"modules:read"}, "expires_at": expires,
+	})))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreateAPIKey_PolicyLoadError(t *testing.T) {
+	mock, r := newPolicyEnforcedAPIKeyRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM organization_members.*WHERE").WillReturnRows(sampleMemberRoleRow())
+	mock.ExpectQuery("FROM organization_members om").WillReturnRows(membershipRows("org-1"))
+	mock.ExpectQuery("FROM org_api_key_policies").WillReturnError(errDB)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys", jsonBody(map[string]interface{}{
+		"name": "k", "organization_id": "org-1", "scopes": []string{"modules:read"},
+	})))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500 (fail closed)", w.Code)
+	}
+}
+
+func TestRotateAPIKey_PolicyViolation(t *testing.T) {
+	mock, r := newPolicyEnforcedAPIKeyRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM api_keys WHERE id").WillReturnRows(sampleAKRow())
+	mock.ExpectQuery("FROM organization_members om").WillReturnRows(membershipRows("org-1"))
+	mock.ExpectQuery("FROM org_api_key_policies").
+		WillReturnRows(keyPolicyRow("org-1", 90, nil, false, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys/key-1/rotate",
+		jsonBody(map[string]interface{}{"grace_period_hours": 0})))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRotateAPIKey_ReplacedKeyNotCounted(t *testing.T) {
+	mock, r := newPolicyEnforcedAPIKeyRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM api_keys WHERE id").WillReturnRows(sampleAKRow())
+	mock.ExpectQuery("FROM organization_members om").WillReturnRows(membershipRows("org-1"))
+	mock.ExpectQuery("FROM org_api_key_policies").
+		WillReturnRows(keyPolicyRow("org-1", 0, nil, false, 1))
+	mock.ExpectQuery("WHERE ak.user_id").WillReturnRows(sampleAKListRow())
+	mock.ExpectExec("INSERT INTO api_keys").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM api_keys").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys/key-1/rotate",
+		jsonBody(map[string]interface{}{"grace_period_hours": 0})))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateAPIKey_PolicyScopeViolation(t *testing.T) {
+	mock, r := newPolicyEnforcedAPIKeyRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM api_keys WHERE id").WillReturnRows(sampleAKRow())
+	mock.ExpectQuery("SELECT.*FROM organization_members.*WHERE").WillReturnRows(sampleMemberRoleRow())
+	mock.ExpectQuery("FROM organization_members om").WillReturnRows(membershipRows("org-1"))
+	mock.ExpectQuery("FROM org_api_key_policies").
+		WillReturnRows(keyPolicyRow("org-1", 0, []byte(`["modules:read"]`), false, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/apikeys/key-1",
+		jsonBody(map[string]interface{}{"scopes": []string{"modules:read", "modules:write"}})))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", w.Code, w.Body.String())
+	}
+	if rule := getJSON(w)["policy_rule"]; rule != "allowed_scopes" {
+		t.Errorf("policy_rule = %v, want allowed_scopes", rule)
+	}
+}
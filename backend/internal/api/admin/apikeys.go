@@ -5,15 +5,25 @@
 package admin
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
 	"github.com/terraform-registry/terraform-registry/internal/auth"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/events"
 )
 
 // APIKeyHandlers handles API key management endpoints
@@ -23,6 +33,16 @@ type APIKeyHandlers struct {
 	apiKeyRepo *repositories.APIKeyRepository
 	orgRepo    *repositories.OrganizationRepository
 	userRepo   *repositories.UserRepository
+	// keyPolicyRepo holds per-organization key policies (registry connection).
+	// May be nil in tests; policy enforcement is skipped when unset.
+	keyPolicyRepo *repositories.OrgAPIKeyPolicyRepository
+	// claimRepo and cipher hold one-time retrieval links. Both may be nil in
+	// tests; the one_time_link delivery mode is then unavailable.
+	claimRepo *repositories.APIKeyClaimRepository
+	cipher    *crypto.TokenCipher
+	// events receives key lifecycle events for the owning organization's
+	// event webhooks; nil drops them.
+	events *events.Bus
 }
 
 // NewAPIKeyHandlers creates a new APIKeyHandlers instance
@@ -36,6 +56,172 @@ func NewAPIKeyHandlers(cfg *config.Config, db *sql.DB) *APIKeyHandlers {
 	}
 }
 
+// WithKeyPolicies sets the organization API key policy repository used to
+// enforce key policies on create, update, and rotate.
+func (h *APIKeyHandlers) WithKeyPolicies(repo *repositories.OrgAPIKeyPolicyRepository) *APIKeyHandlers {
+	h.keyPolicyRepo = repo
+	return h
+}
+
+// WithKeyClaims enables the one_time_link delivery mode on create and rotate.
+// Raw keys waiting to be claimed are sealed with cipher.
+func (h *APIKeyHandlers) WithKeyClaims(repo *repositories.APIKeyClaimRepository, cipher *crypto.TokenCipher) *APIKeyHandlers {
+	h.claimRepo = repo
+	h.cipher = cipher
+	return h
+}
+
+// WithEvents sets the bus API key created, rotated and revoked events are
+// published on. Returns the handler for chaining.
+func (h *APIKeyHandlers) WithEvents(bus *events.Bus) *APIKeyHandlers {
+	h.events = bus
+	return h
+}
+
+// publishKeyEvent publishes an API key event for key's organization.
+func (h *APIKeyHandlers) publishKeyEvent(c *gin.Context, eventType string, key *models.APIKey, extra map[string]string) {
+	data := map[string]string{
+		"api_key_id": key.ID,
+		"name":       key.Name,
+		"key_prefix": key.KeyPrefix,
+		"scopes":     strings.Join(key.Scopes, ","),
+	}
+	if key.UserID != nil {
+		data["user_id"] = *key.UserID
+	}
+	if key.ExpiresAt != nil {
+		data["expires_at"] = key.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	h.events.Publish(events.Event{
+		Type:           eventType,
+		OrganizationID: key.OrganizationID,
+		ActorID:        eventActor(c),
+		Data:           data,
+	})
+}
+
+// defaultAPIKeyClaimTTL applies when auth.api_keys.claim_ttl is unset.
+const defaultAPIKeyClaimTTL = 15 * time.Minute
+
+// checkDelivery validates a requested delivery mode. On failure it writes a
+// 400 response and returns false.
+func (h *APIKeyHandlers) checkDelivery(c *gin.Context, delivery string) bool {
+	switch delivery {
+	case "", models.APIKeyDeliveryResponse:
+		return true
+	case models.APIKeyDeliveryOneTimeLink:
+		if h.claimRepo == nil || h.cipher == nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "one_time_link delivery is not available on this registry",
+			})
+			return false
+		}
+		return true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "delivery must be response or one_time_link",
+		})
+		return false
+	}
+}
+
+// issueClaim stores fullKey sealed behind a new single-use claim token and
+// returns the URL that retrieves it and when that URL expires.
+func (h *APIKeyHandlers) issueClaim(ctx context.Context, key *models.APIKey, fullKey string) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, fmt.Errorf("generate claim token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	sealed, err := h.cipher.Seal(fullKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("seal api key: %w", err)
+	}
+
+	ttl := h.cfg.Auth.APIKeys.ClaimTTL
+	if ttl <= 0 {
+		ttl = defaultAPIKeyClaimTTL
+	}
+	claim := &models.APIKeyClaim{
+		APIKeyID:     key.ID,
+		UserID:       key.UserID,
+		TokenHash:    hashClaimToken(token),
+		EncryptedKey: sealed,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+	if err := h.claimRepo.Create(ctx, claim); err != nil {
+		return "", time.Time{}, err
+	}
+	return strings.TrimRight(h.cfg.Server.BaseURL, "/") + "/api/v1/apikeys/claims/" + token, claim.ExpiresAt, nil
+}
+
+// hashClaimToken returns the form of a claim token stored in the database.
+func hashClaimToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// enforceKeyPolicy checks a key owned by ownerID against the strictest
+// combination of the API key policies of every organization the owner belongs
+// to. replacingKeyID names an existing key the new one supersedes (rotation,
+// update) so it is not counted twice against max_keys_per_user. On failure it
+// writes the response (422 naming the failed rule, or 500) and returns false.
+func (h *APIKeyHandlers) enforceKeyPolicy(c *gin.Context, ownerID string, scopes []string, expiresAt *time.Time, replacingKeyID string) bool {
+	if h.keyPolicyRepo == nil || ownerID == "" {
+		return true
+	}
+	ctx := c.Request.Context()
+
+	memberships, err := h.orgRepo.GetUserMemberships(ctx, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load organization memberships"})
+		return false
+	}
+	orgIDs := make([]string, 0, len(memberships))
+	for _, m := range memberships {
+		orgIDs = append(orgIDs, m.OrganizationID)
+	}
+	policies, err := h.keyPolicyRepo.ListByOrganizations(ctx, orgIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load API key policies"})
+		return false
+	}
+	effective := models.MergeAPIKeyPolicies(policies)
+	if effective == nil {
+		return true
+	}
+
+	keyCount := 1
+	if effective.MaxKeysPerUser > 0 {
+		existing, err := h.apiKeyRepo.ListByUser(ctx, ownerID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count existing API keys"})
+			return false
+		}
+		for _, k := range existing {
+			if k.ID != replacingKeyID {
+				keyCount++
+			}
+		}
+	}
+
+	violations := effective.Violations(scopes, time.Now(), expiresAt, keyCount)
+	if len(violations) == 0 {
+		return true
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":            violations[0].Message,
+		"policy_rule":      violations[0].Rule,
+		"violations":       violations,
+		"organization_ids": effective.OrganizationIDs,
+	})
+	return false
+}
+
 // CreateAPIKeyRequest represents the request to create a new API key
 type CreateAPIKeyRequest struct {
 	Name           string   `json:"name" binding:"required"`
@@ -43,6 +229,9 @@ type CreateAPIKeyRequest struct {
 	Description    *string  `json:"description"`
 	Scopes         []string `json:"scopes" binding:"required"`
 	ExpiresAt      *string  `json:"expires_at"` // RFC3339 format
+	// Delivery is "response" (default) to return the key in the response, or
+	// "one_time_link" to return a single-use retrieval link instead.
+	Delivery string `json:"delivery"`
 }
 
 // CreateAPIKeyResponse represents the response when creating an API key
@@ -50,13 +239,27 @@ type CreateAPIKeyResponse struct {
 	ID          string     `json:"id"`
 	Name        string     `json:"name"`
 	Description *string    `json:"description"`
-	Key         string     `json:"key"` // Only returned once during creation
+	Key         string     `json:"key,omitempty"` // Only returned once during creation
 	KeyPrefix   string     `json:"key_prefix"`
 	Scopes      []string   `json:"scopes"`
 	ExpiresAt   *time.Time `json:"expires_at"`
 	CreatedAt   time.Time  `json:"created_at"`
+	// ClaimURL and ClaimExpiresAt replace Key with one_time_link delivery.
+	ClaimURL       string     `json:"claim_url,omitempty"`
+	ClaimExpiresAt *time.Time `json:"claim_expires_at,omitempty"`
+}
+
+// ClaimAPIKeyResponse is returned by GET /api/v1/apikeys/claims/{token}.
+type ClaimAPIKeyResponse struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
 }
 
+// apiKeyListLimits are the page size default and cap of the API key list.
+// The repository returns every matching key, so the page is cut in memory;
+// the default is high enough to keep returning all keys to typical callers.
+var apiKeyListLimits = pagination.Limits{Default: 100, Max: 500}
+
 // @Summary      List API keys
 // @Description  List API keys with optional filtering by organization. Users with api_keys:manage scope can view all keys in an organization, otherwise only their own keys are visible.
 // @Tags         API Keys
@@ -64,6 +267,8 @@ type CreateAPIKeyResponse struct {
 // @Accept       json
 // @Produce      json
 // @Param        organization_id  query  string  false  "Filter by organization ID (optional)"
+// @Param        limit            query  int     false  "Items per page, max 500 (default 100); alias per_page"
+// @Param        offset           query  int     false  "Items to skip (default 0); alias page (1-based)"
 // @Success      200  {object}  admin.ListAPIKeysResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized - user not authenticated"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
@@ -96,6 +301,7 @@ func (h *APIKeyHandlers) ListAPIKeysHandler() gin.HandlerFunc {
 
 		// Get organization filter if provided
 		orgID := c.Query("organization_id")
+		page := pagination.Parse(c, apiKeyListLimits)
 
 		// Check if user has api_keys:manage scope (allows viewing all keys in org)
 		scopesVal, _ := c.Get("scopes")
@@ -127,6 +333,8 @@ func (h *APIKeyHandlers) ListAPIKeysHandler() gin.HandlerFunc {
 			})
 			return
 		}
+		total := len(keys)
+		keys = pagination.Window(page, keys)
 
 		// Map keys to a JSON-friendly shape (snake_case) and avoid exposing sensitive data
 		resp := make([]gin.H, 0, len(keys))
@@ -175,21 +383,23 @@ func (h *APIKeyHandlers) ListAPIKeysHandler() gin.HandlerFunc {
 
 		c.JSON(http.StatusOK, gin.H{
 			"keys": resp,
+			"meta": page.Meta(int64(total)),
 		})
 	}
 }
 
 // @Summary      Create API key
-// @Description  Create a new API key with specified scopes. The full API key is only returned once during creation.
+// @Description  Create a new API key with specified scopes. The full API key is only returned once during creation. With delivery one_time_link the response carries claim_url instead of key; the key is revoked if the link is not claimed before claim_expires_at.
 // @Tags         API Keys
 // @Security     Bearer
 // @Accept       json
 // @Produce      json
 // @Param        body  body  CreateAPIKeyRequest  true  "API key creation request"
 // @Success      201  {object}  CreateAPIKeyResponse  "API key created successfully (full key returned once)"
-// @Failure      400  {object}  map[string]interface{}  "Invalid request or scopes"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request, scopes or delivery"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized - user not authenticated"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden - no role or scopes exceed permissions"
+// @Failure      422  {object}  map[string]interface{}  "Key violates an organization API key policy (policy_rule names the failed rule)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/apikeys [post]
 // CreateAPIKeyHandler creates a new API key
@@ -229,6 +439,10 @@ func (h *APIKeyHandlers) CreateAPIKeyHandler() gin.HandlerFunc {
 			return
 		}
 
+		if !h.checkDelivery(c, req.Delivery) {
+			return
+		}
+
 		// Resolve organization ID - if 'default', get the actual default org ID
 		orgID := req.OrganizationID
 		if orgID == "default" || orgID == "" {
@@ -314,6 +528,11 @@ func (h *APIKeyHandlers) CreateAPIKeyHandler() gin.HandlerFunc {
 			expiresAt = &parsed
 		}
 
+		// Enforce the key owner's organization API key policies
+		if !h.enforceKeyPolicy(c, userID, req.Scopes, expiresAt, "") {
+			return
+		}
+
 		// Generate API key
 		keyPrefix := "tfr" // Terraform Registry
 		fullKey, keyHash, displayPrefix, err := auth.GenerateAPIKey(keyPrefix)
@@ -344,16 +563,32 @@ func (h *APIKeyHandlers) CreateAPIKeyHandler() gin.HandlerFunc {
 			return
 		}
 
-		// Return full key (only time it's visible)
-		c.JSON(http.StatusCreated, CreateAPIKeyResponse{
+		resp := CreateAPIKeyResponse{
 			ID:        apiKey.ID,
 			Name:      apiKey.Name,
-			Key:       fullKey, // IMPORTANT: Only returned once
 			KeyPrefix: displayPrefix,
 			Scopes:    apiKey.Scopes,
 			ExpiresAt: apiKey.ExpiresAt,
 			CreatedAt: apiKey.CreatedAt,
-		})
+		}
+		if req.Delivery == models.APIKeyDeliveryOneTimeLink {
+			claimURL, claimExpiresAt, err := h.issueClaim(c.Request.Context(), apiKey, fullKey)
+			if err != nil {
+				// Nobody can retrieve the key, so don't leave it behind.
+				_ = h.apiKeyRepo.Delete(c.Request.Context(), apiKey.ID)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to create API key claim link",
+				})
+				return
+			}
+			resp.ClaimURL = claimURL
+			resp.ClaimExpiresAt = &claimExpiresAt
+		} else {
+			// Return full key (only time it's visible)
+			resp.Key = fullKey
+		}
+		h.publishKeyEvent(c, events.TypeAPIKeyCreated, apiKey, nil)
+		c.JSON(http.StatusCreated, resp)
 	}
 }
 
@@ -472,6 +707,7 @@ func (h *APIKeyHandlers) DeleteAPIKeyHandler() gin.HandlerFunc {
 			})
 			return
 		}
+		h.publishKeyEvent(c, events.TypeAPIKeyRevoked, apiKey, nil)
 
 		c.JSON(http.StatusOK, gin.H{
 			"message": "API key deleted successfully",
@@ -492,6 +728,7 @@ func (h *APIKeyHandlers) DeleteAPIKeyHandler() gin.HandlerFunc {
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized - user not authenticated"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden - access denied or scopes exceed permissions"
 // @Failure      404  {object}  map[string]interface{}  "API key not found"
+// @Failure      422  {object}  map[string]interface{}  "Key violates an organization API key policy (policy_rule names the failed rule)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/apikeys/{id} [put]
 // UpdateAPIKeyHandler updates an API key (name, scopes, expiration)
@@ -609,6 +846,14 @@ func (h *APIKeyHandlers) UpdateAPIKeyHandler() gin.HandlerFunc {
 			apiKey.ExpiresAt = &parsed
 		}
 
+		// Re-check the owner's organization API key policies when the change
+		// touches a policy-governed field.
+		if (req.Scopes != nil || req.ExpiresAt != nil) && apiKey.UserID != nil {
+			if !h.enforceKeyPolicy(c, *apiKey.UserID, apiKey.Scopes, apiKey.ExpiresAt, apiKey.ID) {
+				return
+			}
+		}
+
 		// Update in database
 		if err := h.apiKeyRepo.Update(c.Request.Context(), apiKey); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -627,6 +872,8 @@ func (h *APIKeyHandlers) UpdateAPIKeyHandler() gin.HandlerFunc {
 type RotateAPIKeyRequest struct {
 	// GracePeriodHours is how long the old key should remain valid (0 = immediate revocation)
 	GracePeriodHours int `json:"grace_period_hours"`
+	// Delivery is "response" (default) or "one_time_link", as for create.
+	Delivery string `json:"delivery"`
 }
 
 // RotateAPIKeyResponse represents the response when rotating an API key
@@ -637,7 +884,7 @@ type RotateAPIKeyResponse struct {
 }
 
 // @Summary      Rotate API key
-// @Description  Rotate an API key by creating a new key and optionally scheduling the old key's expiration. Users can only rotate their own keys unless they have admin scope.
+// @Description  Rotate an API key by creating a new key and optionally scheduling the old key's expiration. Users can only rotate their own keys unless they have admin scope. With delivery one_time_link, new_key carries claim_url instead of key.
 // @Tags         API Keys
 // @Security     Bearer
 // @Accept       json
@@ -645,10 +892,11 @@ type RotateAPIKeyResponse struct {
 // @Param        id    path  string                  true  "API key ID"
 // @Param        body  body  RotateAPIKeyRequest     true  "Rotation request with optional grace period (0-72 hours)"
 // @Success      200  {object}  RotateAPIKeyResponse  "New API key and old key status"
-// @Failure      400  {object}  map[string]interface{}  "Invalid grace period (must be 0-72 hours)"
+// @Failure      400  {object}  map[string]interface{}  "Invalid grace period (must be 0-72 hours) or delivery"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized - user not authenticated"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden - access denied to this key"
 // @Failure      404  {object}  map[string]interface{}  "API key not found"
+// @Failure      422  {object}  map[string]interface{}  "Rotated key would violate an organization API key policy"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/apikeys/{id}/rotate [post]
 // RotateAPIKeyHandler rotates an API key - creates a new key and optionally schedules old key expiration
@@ -671,6 +919,10 @@ func (h *APIKeyHandlers) RotateAPIKeyHandler() gin.HandlerFunc {
 			return
 		}
 
+		if !h.checkDelivery(c, req.Delivery) {
+			return
+		}
+
 		// Get the existing API key
 		oldKey, err := h.apiKeyRepo.GetByID(c.Request.Context(), keyID)
 		if err != nil {
@@ -703,6 +955,14 @@ func (h *APIKeyHandlers) RotateAPIKeyHandler() gin.HandlerFunc {
 			}
 		}
 
+		// The new key inherits the old key's scopes and expiry, so it must
+		// satisfy the owner's current organization API key policies.
+		if oldKey.UserID != nil {
+			if !h.enforceKeyPolicy(c, *oldKey.UserID, oldKey.Scopes, oldKey.ExpiresAt, oldKey.ID) {
+				return
+			}
+		}
+
 		// Generate new API key
 		keyPrefix := "tfr"
 		fullKey, keyHash, displayPrefix, err := auth.GenerateAPIKey(keyPrefix)
@@ -733,6 +993,31 @@ func (h *APIKeyHandlers) RotateAPIKeyHandler() gin.HandlerFunc {
 			return
 		}
 
+		newKeyResp := CreateAPIKeyResponse{
+			ID:        newKey.ID,
+			Name:      newKey.Name,
+			KeyPrefix: displayPrefix,
+			Scopes:    newKey.Scopes,
+			ExpiresAt: newKey.ExpiresAt,
+			CreatedAt: newKey.CreatedAt,
+		}
+		if req.Delivery == models.APIKeyDeliveryOneTimeLink {
+			// Issue the link before touching the old key, so a failure here
+			// leaves the old key exactly as it was.
+			claimURL, claimExpiresAt, err := h.issueClaim(c.Request.Context(), newKey, fullKey)
+			if err != nil {
+				_ = h.apiKeyRepo.Delete(c.Request.Context(), newKey.ID)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to create API key claim link",
+				})
+				return
+			}
+			newKeyResp.ClaimURL = claimURL
+			newKeyResp.ClaimExpiresAt = &claimExpiresAt
+		} else {
+			newKeyResp.Key = fullKey // IMPORTANT: Only returned once
+		}
+
 		// Handle old key based on grace period
 		var oldKeyStatus string
 		var oldExpiresAt *time.Time
@@ -758,18 +1043,79 @@ func (h *APIKeyHandlers) RotateAPIKeyHandler() gin.HandlerFunc {
 			}
 		}
 
+		h.publishKeyEvent(c, events.TypeAPIKeyRotated, newKey, map[string]string{
+			"replaced_api_key_id": oldKey.ID,
+			"old_key_status":      oldKeyStatus,
+		})
 		c.JSON(http.StatusOK, RotateAPIKeyResponse{
-			NewKey: CreateAPIKeyResponse{
-				ID:        newKey.ID,
-				Name:      newKey.Name,
-				Key:       fullKey, // IMPORTANT: Only returned once
-				KeyPrefix: displayPrefix,
-				Scopes:    newKey.Scopes,
-				ExpiresAt: newKey.ExpiresAt,
-				CreatedAt: newKey.CreatedAt,
-			},
+			NewKey:       newKeyResp,
 			OldKeyStatus: oldKeyStatus,
 			OldExpiresAt: oldExpiresAt,
 		})
 	}
 }
+
+// @Summary      Claim API key
+// @Description  Return the raw value of an API key created or rotated with delivery one_time_link, then destroy the link. Only the key's owner can claim it; keys without an owner are claimed by admins. A link that is unknown, expired, already claimed or belongs to another user returns 404.
+// @Tags         API Keys
+// @Security     Bearer
+// @Produce      json
+// @Param        token  path  string  true  "Claim token from claim_url"
+// @Success      200  {object}  ClaimAPIKeyResponse  "The API key (returned once)"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized - user not authenticated"
+// @Failure      404  {object}  map[string]interface{}  "Claim link not found, expired or already used"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/apikeys/claims/{token} [get]
+// ClaimAPIKeyHandler returns an API key through its one-time retrieval link
+// GET /api/v1/apikeys/claims/:token
+func (h *APIKeyHandlers) ClaimAPIKeyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDVal, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not authenticated",
+			})
+			return
+		}
+		userID, _ := userIDVal.(string)
+
+		if h.claimRepo == nil || h.cipher == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Claim link not found, expired, or already used",
+			})
+			return
+		}
+
+		scopesVal, _ := c.Get("scopes")
+		scopes, _ := scopesVal.([]string)
+		claim, err := h.claimRepo.Claim(c.Request.Context(), hashClaimToken(c.Param("token")), userID, auth.HasScope(scopes, auth.ScopeAdmin))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to claim API key",
+			})
+			return
+		}
+		if claim == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Claim link not found, expired, or already used",
+			})
+			return
+		}
+
+		fullKey, err := h.cipher.Open(claim.EncryptedKey)
+		if err != nil {
+			// The claim is gone, so the key can never be delivered.
+			_ = h.apiKeyRepo.Delete(c.Request.Context(), claim.APIKeyID)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to decrypt API key; it has been revoked",
+			})
+			return
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, ClaimAPIKeyResponse{
+			ID:  claim.APIKeyID,
+			Key: fullKey,
+		})
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 )
 
 // ---------------------------------------------------------------------------
@@ -777,3 +780,136 @@ func TestUpdateAPIKey_WithInvalidExpiresAt(t *testing.T) {
 		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
 	}
 }
+
+// ---------------------------------------------------------------------------
+// One-time link delivery and ClaimAPIKeyHandler
+// ---------------------------------------------------------------------------
+
+func newAPIKeyClaimRouter(t *testing.T, userID string, scopes []string) (sqlmock.Sqlmock, *gin.Engine, *crypto.TokenCipher) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	cipher, _ := crypto.NewTokenCipher(bytes.Repeat([]byte("k"), 32))
+
+	cfg := &config.Config{}
+	cfg.Server.BaseURL = "https://registry.example.com"
+	h := NewAPIKeyHandlers(cfg, db).WithKeyClaims(repositories.NewAPIKeyClaimRepository(db), cipher)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("scopes", scopes)
+		c.Next()
+	})
+	r.POST("/apikeys", h.CreateAPIKeyHandler())
+	r.GET("/apikeys/claims/:token", h.ClaimAPIKeyHandler())
+	r.POST("/apikeys/:id/rotate", h.RotateAPIKeyHandler())
+	return mock, r, cipher
+}
+
+var claimInsertCols = []string{"id", "created_at"}
+
+func TestCreateAPIKey_OneTimeLink(t *testing.T) {
+	mock, r, _ := newAPIKeyClaimRouter(t, "user-1", nil)
+	mock.ExpectQuery("SELECT.*FROM organization_members.*WHERE").
+		WillReturnRows(sampleMemberRoleRow())
+	mock.ExpectExec("INSERT INTO api_keys").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO api_key_claims").
+		WillReturnRows(sqlmock.NewRows(claimInsertCols).AddRow("claim-1", time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys",
+		jsonBody(map[string]interface{}{
+			"name":            "My Key",
+			"organization_id": "org-1",
+			"scopes":          []string{"modules:read"},
+			"delivery":        "one_time_link",
+		})))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+	resp := getJSON(w)
+	if _, ok := resp["key"]; ok {
+		t.Error("response must not carry the raw key with one_time_link delivery")
+	}
+	claimURL, _ := resp["claim_url"].(string)
+	if !strings.HasPrefix(claimURL, "https://registry.example.com/api/v1/apikeys/claims/") || resp["claim_expires_at"] == nil {
+		t.Errorf("claim_url = %q, claim_expires_at = %v", claimURL, resp["claim_expires_at"])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreateAPIKey_OneTimeLinkUnavailable(t *testing.T) {
+	_, r := newAPIKeyRouter(t, "user-1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys",
+		jsonBody(map[string]interface{}{
+			"name":            "My Key",
+			"organization_id": "org-1",
+			"scopes":          []string{"modules:read"},
+			"delivery":        "one_time_link",
+		})))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRotateAPIKey_OneTimeLinkIssuedBeforeRevoke(t *testing.T) {
+	mock, r, _ := newAPIKeyClaimRouter(t, "user-1", nil)
+	mock.ExpectQuery("SELECT.*FROM api_keys WHERE id").WillReturnRows(sampleAKRow())
+	mock.ExpectExec("INSERT INTO api_keys").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO api_key_claims").
+		WillReturnRows(sqlmock.NewRows(claimInsertCols).AddRow("claim-1", time.Now()))
+	mock.ExpectExec("DELETE FROM api_keys").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/apikeys/key-1/rotate",
+		jsonBody(map[string]interface{}{"delivery": "one_time_link"})))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	newKey, _ := getJSON(w)["new_key"].(map[string]interface{})
+	if newKey["key"] != nil || newKey["claim_url"] == nil {
+		t.Errorf("new_key = %v, want claim_url and no key", newKey)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClaimAPIKey_ReturnsKeyOnce(t *testing.T) {
+	mock, r, cipher := newAPIKeyClaimRouter(t, "user-1", nil)
+	sealed, _ := cipher.Seal("tfr_secret")
+	cols := []string{"id", "api_key_id", "user_id", "token_hash", "encrypted_key", "expires_at", "created_at"}
+	mock.ExpectQuery("DELETE FROM api_key_claims").
+		WithArgs(hashClaimToken("tok"), "user-1", false).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("claim-1", "key-1", "user-1", hashClaimToken("tok"), sealed, time.Now().Add(time.Minute), time.Now()))
+	mock.ExpectQuery("DELETE FROM api_key_claims").
+		WithArgs(hashClaimToken("tok"), "user-1", false).
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/apikeys/claims/tok", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if resp := getJSON(w); resp["key"] != "tfr_secret" || resp["id"] != "key-1" {
+		t.Errorf("response = %v", resp)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/apikeys/claims/tok", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second claim status = %d, want 404", w.Code)
+	}
+}
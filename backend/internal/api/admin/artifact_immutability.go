@@ -0,0 +1,215 @@
+// artifact_immutability.go implements the per-organization artifact
+// immutability endpoints and the publish log integrity check. Enforcement
+// lives in services.ArtifactImmutability: the module and provider delete
+// handlers check it before deleting, and the storage decorator refuses
+// deletes and overwrites of protected archives on every code path.
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// ArtifactImmutabilityHandlers serves the artifact immutability endpoints.
+type ArtifactImmutabilityHandlers struct {
+	cfg          *config.ImmutableArtifactsConfig
+	orgRepo      *repositories.OrganizationRepository
+	repo         *repositories.ArtifactImmutabilityRepository
+	immutability *services.ArtifactImmutability
+}
+
+// NewArtifactImmutabilityHandlers constructs an ArtifactImmutabilityHandlers.
+// identityDB backs organizations; repo runs on the registry's own connection.
+func NewArtifactImmutabilityHandlers(cfg *config.ImmutableArtifactsConfig, identityDB *sql.DB, repo *repositories.ArtifactImmutabilityRepository, immutability *services.ArtifactImmutability) *ArtifactImmutabilityHandlers {
+	return &ArtifactImmutabilityHandlers{
+		cfg:          cfg,
+		orgRepo:      repositories.NewOrganizationRepository(identityDB),
+		repo:         repo,
+		immutability: immutability,
+	}
+}
+
+// UpdateArtifactImmutabilityRequest is the body of
+// PUT /organizations/:id/artifact-immutability.
+type UpdateArtifactImmutabilityRequest struct {
+	Enabled bool `json:"enabled"`
+	// RetentionDays is how old a version must be before it may be deleted;
+	// 0 means never.
+	RetentionDays int `json:"retention_days"`
+}
+
+// ArtifactImmutabilityResponse describes the immutability that applies to an
+// organization's artifacts.
+type ArtifactImmutabilityResponse struct {
+	OrganizationID string `json:"organization_id"`
+	// Global is the registry-wide immutable_artifacts setting.
+	Global models.EffectiveImmutability `json:"global"`
+	// Organization is the organization's own setting, null when it has none.
+	Organization *models.OrgArtifactImmutability `json:"organization"`
+	// Effective combines the two: enabled if either is, with the longest
+	// retention.
+	Effective models.EffectiveImmutability `json:"effective"`
+}
+
+func (h *ArtifactImmutabilityHandlers) response(orgID string, setting *models.OrgArtifactImmutability) ArtifactImmutabilityResponse {
+	global := models.MergeImmutability(h.cfg.Enabled, h.cfg.RetentionDays, nil)
+	return ArtifactImmutabilityResponse{
+		OrganizationID: orgID,
+		Global:         global,
+		Organization:   setting,
+		Effective:      models.MergeImmutability(h.cfg.Enabled, h.cfg.RetentionDays, setting),
+	}
+}
+
+// @Summary      Get organization artifact immutability
+// @Description  Returns the organization's artifact immutability setting together with the registry-wide setting and the effective combination.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  admin.ArtifactImmutabilityResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/artifact-immutability [get]
+// GetSettingHandler returns an organization's artifact immutability.
+// GET /api/v1/organizations/:id/artifact-immutability
+func (h *ArtifactImmutabilityHandlers) GetSettingHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID := c.Param("id")
+		setting, err := h.repo.GetOrgSetting(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve artifact immutability setting"})
+			return
+		}
+		c.JSON(http.StatusOK, h.response(orgID, setting))
+	}
+}
+
+// @Summary      Set organization artifact immutability
+// @Description  Creates or strengthens the organization's artifact immutability setting. While enabled, published module and provider archives cannot be replaced, versions younger than retention_days (0 = never) cannot be deleted, and every publish is appended to the hash-chained publish log. Once enabled the setting can only be strengthened: disabling it or shortening the retention returns 409.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                             true  "Organization ID"
+// @Param        body  body  UpdateArtifactImmutabilityRequest  true  "Setting"
+// @Success      200  {object}  admin.ArtifactImmutabilityResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Organization not found"
+// @Failure      409  {object}  map[string]interface{}  "Change would weaken an enabled setting"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/artifact-immutability [put]
+// UpdateSettingHandler creates or strengthens an organization's artifact
+// immutability setting.
+// PUT /api/v1/organizations/:id/artifact-immutability
+func (h *ArtifactImmutabilityHandlers) UpdateSettingHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID := c.Param("id")
+
+		var req UpdateArtifactImmutabilityRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+		if req.RetentionDays < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "retention_days must be >= 0"})
+			return
+		}
+
+		org, err := h.orgRepo.GetByID(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		current, err := h.repo.GetOrgSetting(c.Request.Context(), org.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve artifact immutability setting"})
+			return
+		}
+		if current != nil && current.Enabled && weakensImmutability(current, req) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Artifact immutability is enabled for this organization and can only be strengthened: it cannot be disabled and its retention cannot be shortened",
+			})
+			return
+		}
+
+		setting := &models.OrgArtifactImmutability{
+			OrganizationID: org.ID,
+			Enabled:        req.Enabled,
+			RetentionDays:  req.RetentionDays,
+		}
+		if err := h.repo.UpsertOrgSetting(c.Request.Context(), setting); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save artifact immutability setting"})
+			return
+		}
+		c.JSON(http.StatusOK, h.response(org.ID, setting))
+	}
+}
+
+// weakensImmutability reports whether req would disable an enabled setting or
+// shorten its retention. A retention of 0 (never deletable) is the longest.
+func weakensImmutability(current *models.OrgArtifactImmutability, req UpdateArtifactImmutabilityRequest) bool {
+	if !req.Enabled {
+		return true
+	}
+	if current.RetentionDays == 0 {
+		return req.RetentionDays != 0
+	}
+	return req.RetentionDays != 0 && req.RetentionDays < current.RetentionDays
+}
+
+// @Summary      Verify publish log integrity
+// @Description  Walks the hash-chained publish log and checks that every entry links to the one before it and that its hash matches its contents. Returns the number of entries checked and the head hash; when the chain is broken, valid is false and first_invalid_id names the first bad entry. Record head_hash externally to also detect removal of the newest entries. Requires audit:read scope.
+// @Tags         Audit
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  models.PublishLogIntegrity
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/publish-log/integrity [get]
+// VerifyPublishLogHandler validates the publish log chain.
+// GET /api/v1/admin/publish-log/integrity
+func (h *ArtifactImmutabilityHandlers) VerifyPublishLogHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := h.immutability.VerifyPublishLog(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify publish log"})
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// immutableDeleteAllowed writes a 403 (or a 500 when the setting cannot be
+// read) and returns false when artifact immutability forbids deleting a
+// version of orgID published at publishedAt.
+func immutableDeleteAllowed(c *gin.Context, immutability *services.ArtifactImmutability, orgID string, publishedAt time.Time) bool {
+	err := immutability.CheckVersionDelete(c.Request.Context(), orgID, publishedAt)
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, storage.ErrArtifactImmutable) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return false
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check artifact immutability"})
+	return false
+}
@@ -0,0 +1,158 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+var immutabilityCols = []string{"organization_id", "enabled", "retention_days", "created_at", "updated_at"}
+
+func newImmutabilityRouter(t *testing.T, cfg config.ImmutableArtifactsConfig) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := repositories.NewArtifactImmutabilityRepository(db)
+	h := NewArtifactImmutabilityHandlers(&cfg, db, repo, services.NewArtifactImmutability(&cfg, repo))
+	r := gin.New()
+	r.GET("/organizations/:id/artifact-immutability", h.GetSettingHandler())
+	r.PUT("/organizations/:id/artifact-immutability", h.UpdateSettingHandler())
+	r.GET("/admin/publish-log/integrity", h.VerifyPublishLogHandler())
+	return mock, r
+}
+
+func putImmutability(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/organizations/org-1/artifact-immutability", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetArtifactImmutability(t *testing.T) {
+	mock, r := newImmutabilityRouter(t, config.ImmutableArtifactsConfig{Enabled: true, RetentionDays: 30})
+	mock.ExpectQuery("SELECT.*FROM org_artifact_immutability").
+		WillReturnRows(sqlmock.NewRows(immutabilityCols).AddRow("org-1", true, 90, time.Now(), time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1/artifact-immutability", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp ArtifactImmutabilityResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Global.RetentionDays != 30 || resp.Organization == nil || !resp.Effective.Enabled || resp.Effective.RetentionDays != 90 {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestUpdateArtifactImmutability_Strengthen(t *testing.T) {
+	mock, r := newImmutabilityRouter(t, config.ImmutableArtifactsConfig{})
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM org_artifact_immutability").
+		WillReturnRows(sqlmock.NewRows(immutabilityCols).AddRow("org-1", true, 30, time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO org_artifact_immutability").
+		WithArgs("org-1", true, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+	w := putImmutability(r, `{"enabled":true,"retention_days":0}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateArtifactImmutability_WeakenRejected(t *testing.T) {
+	mock, r := newImmutabilityRouter(t, config.ImmutableArtifactsConfig{})
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM org_artifact_immutability").
+		WillReturnRows(sqlmock.NewRows(immutabilityCols).AddRow("org-1", true, 30, time.Now(), time.Now()))
+
+	w := putImmutability(r, `{"enabled":false,"retention_days":30}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409; body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateArtifactImmutability_Validation(t *testing.T) {
+	_, r := newImmutabilityRouter(t, config.ImmutableArtifactsConfig{})
+	if w := putImmutability(r, `{"enabled":true,"retention_days":-1}`); w.Code != http.StatusBadRequest {
+		t.Errorf("negative retention: status = %d, want 400", w.Code)
+	}
+
+	mock, r := newImmutabilityRouter(t, config.ImmutableArtifactsConfig{})
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").WillReturnRows(sqlmock.NewRows(orgCols))
+	if w := putImmutability(r, `{"enabled":true}`); w.Code != http.StatusNotFound {
+		t.Errorf("unknown org: status = %d, want 404", w.Code)
+	}
+}
+
+func TestWeakensImmutability(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		req     UpdateArtifactImmutabilityRequest
+		want    bool
+	}{
+		{"disable", 30, UpdateArtifactImmutabilityRequest{Enabled: false, RetentionDays: 30}, true},
+		{"shorten", 30, UpdateArtifactImmutabilityRequest{Enabled: true, RetentionDays: 10}, true},
+		{"never to finite", 0, UpdateArtifactImmutabilityRequest{Enabled: true, RetentionDays: 365}, true},
+		{"same", 30, UpdateArtifactImmutabilityRequest{Enabled: true, RetentionDays: 30}, false},
+		{"lengthen", 30, UpdateArtifactImmutabilityRequest{Enabled: true, RetentionDays: 60}, false},
+		{"finite to never", 30, UpdateArtifactImmutabilityRequest{Enabled: true, RetentionDays: 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := &models.OrgArtifactImmutability{Enabled: true, RetentionDays: tt.current}
+			if got := weakensImmutability(current, tt.req); got != tt.want {
+				t.Errorf("weakensImmutability() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyPublishLog(t *testing.T) {
+	mock, r := newImmutabilityRouter(t, config.ImmutableArtifactsConfig{Enabled: true})
+	e := &models.PublishLogEntry{
+		ID: 1, ArtifactType: models.PublishLogArtifactModule, Namespace: "ns", Name: "n", Target: "aws", Version: "1.0.0",
+		PublishedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), PrevHash: models.PublishLogGenesisHash,
+	}
+	e.EntryHash = e.ComputeHash()
+	mock.ExpectQuery("FROM artifact_publish_log").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "artifact_type", "organization_id", "namespace", "name", "target", "version",
+			"storage_key", "checksum", "published_by", "published_at", "prev_hash", "entry_hash"}).
+			AddRow(e.ID, e.ArtifactType, nil, e.Namespace, e.Name, e.Target, e.Version, "", "", nil, e.PublishedAt, e.PrevHash, e.EntryHash))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/publish-log/integrity", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var res models.PublishLogIntegrity
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.EntriesChecked != 1 || res.HeadHash != e.EntryHash {
+		t.Errorf("integrity = %+v", res)
+	}
+}
@@ -4,10 +4,11 @@ package admin
 import (
 	"database/sql"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 )
 
@@ -15,6 +16,7 @@ import (
 type AuditLogHandlers struct {
 	db        *sql.DB
 	auditRepo *repositories.AuditRepository
+	pageRepo  *repositories.AuditLogPageRepository
 }
 
 // NewAuditLogHandlers creates a new AuditLogHandlers instance
@@ -22,17 +24,19 @@ func NewAuditLogHandlers(db *sql.DB) *AuditLogHandlers {
 	return &AuditLogHandlers{
 		db:        db,
 		auditRepo: repositories.NewAuditRepository(db),
+		pageRepo:  repositories.NewAuditLogPageRepository(db),
 	}
 }
 
 // @Summary      List audit logs
-// @Description  Get a paginated, filterable list of audit log entries. Requires audit:read scope.
+// @Description  Get a paginated, filterable list of audit log entries, newest first. Requires audit:read scope. Pages are requested with limit/offset (or per_page/page), or with the cursor from the previous page's meta.next_cursor, which stays fast however deep the page and is not shifted by new entries. Cursor pages carry no total.
 // @Tags         Audit
 // @Security     Bearer
 // @Accept       json
 // @Produce      json
-// @Param        page           query  int     false  "Page number (default 1)"
-// @Param        per_page       query  int     false  "Items per page, max 200 (default 25)"
+// @Param        limit          query  int     false  "Items per page, max 200 (default 25); alias per_page"
+// @Param        offset         query  int     false  "Items to skip (default 0); alias page (1-based)"
+// @Param        cursor         query  string  false  "meta.next_cursor of the previous page; cannot be combined with offset or page"
 // @Param        action         query  string  false  "Filter by action string (exact match)"
 // @Param        resource_type  query  string  false  "Filter by resource type (module, provider, user, mirror, api_key, organization)"
 // @Param        user_id        query  string  false  "Filter by actor user ID (exact match)"
@@ -40,7 +44,7 @@ func NewAuditLogHandlers(db *sql.DB) *AuditLogHandlers {
 // @Param        start_date     query  string  false  "Filter entries at or after this RFC3339 timestamp"
 // @Param        end_date       query  string  false  "Filter entries at or before this RFC3339 timestamp"
 // @Success      200  {object}  admin.AuditLogListResponse
-// @Failure      400  {object}  map[string]interface{}  "Invalid query parameters"
+// @Failure      400  {object}  map[string]interface{}  "Invalid query parameters or cursor"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden — audit:read scope required"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
@@ -49,16 +53,11 @@ func NewAuditLogHandlers(db *sql.DB) *AuditLogHandlers {
 // GET /api/v1/admin/audit-logs
 func (h *AuditLogHandlers) ListAuditLogsHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Pagination
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "25"))
-		if page < 1 {
-			page = 1
-		}
-		if perPage < 1 || perPage > 200 {
-			perPage = 25
+		page, err := pagination.ParseCursor(c, auditLogLimits)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-		offset := (page - 1) * perPage
 
 		// Build filters
 		filters := repositories.AuditFilters{}
@@ -92,10 +91,25 @@ func (h *AuditLogHandlers) ListAuditLogsHandler() gin.HandlerFunc {
 			filters.EndDate = &t
 		}
 
-		logs, total, err := h.auditRepo.ListAuditLogs(c.Request.Context(), filters, perPage, offset)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit logs"})
-			return
+		var logs []*models.AuditLog
+		var meta pagination.Meta
+		if page.After != nil {
+			logs, err = h.pageRepo.ListAfter(c.Request.Context(), filters, page.After, page.Limit+1)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit logs"})
+				return
+			}
+			var more bool
+			logs, more = pagination.Trim(page, logs)
+			meta = page.CursorMeta(more, auditLogKeyset(logs))
+		} else {
+			var total int
+			logs, total, err = h.auditRepo.ListAuditLogs(c.Request.Context(), filters, page.Limit, page.Offset)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit logs"})
+				return
+			}
+			meta = page.Meta(int64(total)).WithNextCursor(auditLogKeyset(logs))
 		}
 
 		// Map to response structs
@@ -116,15 +130,25 @@ func (h *AuditLogHandlers) ListAuditLogsHandler() gin.HandlerFunc {
 			})
 		}
 
-		c.JSON(http.StatusOK, AuditLogListResponse{
-			Logs: items,
-			Pagination: PaginationMeta{
-				Page:    page,
-				PerPage: perPage,
-				Total:   int64(total),
-			},
-		})
+		resp := AuditLogListResponse{Logs: items, Meta: meta}
+		if page.After == nil {
+			pm := newPaginationMeta(page, *meta.Total)
+			resp.Pagination = &pm
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// auditLogLimits are the audit log listing's page size default and cap.
+var auditLogLimits = pagination.Limits{Default: 25, Max: 200}
+
+// auditLogKeyset returns the position of the last of logs.
+func auditLogKeyset(logs []*models.AuditLog) repositories.Keyset {
+	if len(logs) == 0 {
+		return repositories.Keyset{}
 	}
+	last := logs[len(logs)-1]
+	return repositories.Keyset{CreatedAt: last.CreatedAt, ID: last.ID}
 }
 
 // @Summary      Get audit log entry
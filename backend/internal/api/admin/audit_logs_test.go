@@ -249,7 +249,7 @@ func TestListAuditLogs_PageBelowOne(t *testing.T) {
 }
 
 func TestListAuditLogs_PerPageExceedsMax(t *testing.T) {
-	// per_page=500 (>200) should be capped at 25
+	// per_page=500 (>200) is lowered to the 200 cap
 	mock, r := newAuditLogRouter(t)
 
 	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM audit_logs").
@@ -282,3 +282,56 @@ func TestListAuditLogs_WithDateFilters(t *testing.T) {
 		t.Errorf("status = %d, want 200", w.Code)
 	}
 }
+
+func TestListAuditLogs_CursorPaging(t *testing.T) {
+	mock, r := newAuditLogRouter(t)
+
+	// An offset page carries the cursor that continues after its last row.
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM audit_logs").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT al\\.id").
+		WillReturnRows(sampleAuditLogListRows())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/audit-logs?limit=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	resp := getJSON(w)
+	meta, _ := resp["meta"].(map[string]interface{})
+	cursor, _ := meta["next_cursor"].(string)
+	if meta["total"] != float64(2) || meta["has_more"] != true || cursor == "" || resp["pagination"] == nil {
+		t.Fatalf("offset page = %v, want total 2, has_more and a next_cursor", resp)
+	}
+
+	// The cursor page is read by keyset, one row past the limit, uncounted.
+	mock.ExpectQuery("\\(al\\.created_at, al\\.id\\) < \\(\\$1, \\$2::uuid\\).*LIMIT \\$3").
+		WithArgs(sqlmock.AnyArg(), knownUUID, 2).
+		WillReturnRows(emptyAuditLogListRows())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/audit-logs?limit=1&cursor="+cursor, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	resp = getJSON(w)
+	meta, _ = resp["meta"].(map[string]interface{})
+	if _, counted := meta["total"]; counted || meta["has_more"] != false || meta["next_cursor"] != nil || resp["pagination"] != nil {
+		t.Errorf("cursor page = %v, want no total, no pagination and no next_cursor", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListAuditLogs_BadCursor(t *testing.T) {
+	_, r := newAuditLogRouter(t)
+
+	for _, query := range []string{"cursor=garbage", "cursor=eyJ0IjoiMjAyNi0wMS0wMVQwMDowMDowMFoiLCJpZCI6IngifQ", "cursor=abc&offset=5"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/audit-logs?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/auth/oidc"
 	samlpkg "github.com/terraform-registry/terraform-registry/internal/auth/saml"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
 	"github.com/terraform-registry/terraform-registry/internal/middleware"
@@ -45,6 +46,17 @@ type AuthHandlers struct {
 	// samlEgressGuard widens the SSRF deny-list applied when fetching a SAML
 	// IdP's metadata_url (nil = strict). Set via WithSAMLEgressGuard.
 	samlEgressGuard *httpsafe.Guard
+	// loginRepo records each successful interactive login for the admin user
+	// search (nil = not recorded). Set via WithLoginRecorder.
+	loginRepo *repositories.UserLoginRepository
+	// scopeCeilingRepo holds the organization scope ceilings issued tokens are
+	// clipped to (nil = no ceilings). Set via WithScopeCeilings.
+	scopeCeilingRepo *repositories.OrgScopeCeilingRepository
+	// linkRepo holds the pending identity link requests of logins that must
+	// be linked to an existing account explicitly (nil = such logins are
+	// linked silently). auditRepo records them. Set via WithIdentityLinks.
+	linkRepo  *repositories.IdentityLinkRepository
+	auditRepo *repositories.AuditRepository
 }
 
 // AuthHandlersOption configures optional AuthHandlers construction behavior.
@@ -57,6 +69,26 @@ func WithSAMLEgressGuard(g *httpsafe.Guard) AuthHandlersOption {
 	return func(h *AuthHandlers) { h.samlEgressGuard = g }
 }
 
+// WithLoginRecorder records each successful OIDC, Azure AD, SAML and LDAP
+// login in repo. Token refreshes are not logins.
+func WithLoginRecorder(repo *repositories.UserLoginRepository) AuthHandlersOption {
+	return func(h *AuthHandlers) { h.loginRepo = repo }
+}
+
+// WithScopeCeilings clips the scopes of every issued session token to the
+// scope ceilings of the user's organizations held in repo.
+func WithScopeCeilings(repo *repositories.OrgScopeCeilingRepository) AuthHandlersOption {
+	return func(h *AuthHandlers) { h.scopeCeilingRepo = repo }
+}
+
+// WithIdentityLinks stops logins from silently taking over an existing
+// account without a linked identity whose email they match: such logins get
+// a link token to confirm instead (see identity_links.go). Requests and links
+// are stored in linkRepo and audited in auditRepo.
+func WithIdentityLinks(linkRepo *repositories.IdentityLinkRepository, auditRepo *repositories.AuditRepository) AuthHandlersOption {
+	return func(h *AuthHandlers) { h.linkRepo, h.auditRepo = linkRepo, auditRepo }
+}
+
 // NewAuthHandlers creates a new AuthHandlers instance.
 // stateStore must be non-nil; the caller selects the implementation
 // (MemoryStateStore for single-instance, RedisStateStore for HA).
@@ -359,6 +391,7 @@ func (h *AuthHandlers) CallbackHandler() gin.HandlerFunc {
 		ctx := context.Background()
 
 		var sub, email, name string
+		var issuer string // ID token issuer, recorded with the login
 		var err error
 		var oidcGroups []string // populated for OIDC logins when group_claim_name is configured
 		var emailVerified *bool
@@ -414,6 +447,7 @@ func (h *AuthHandlers) CallbackHandler() gin.HandlerFunc {
 			}
 
 			emailVerified = emailVerifiedClaim(idToken)
+			issuer = idToken.Issuer
 
 			// Extract group claims for role mapping.
 			// DB config group mapping settings take precedence over env/file config.
@@ -461,6 +495,7 @@ func (h *AuthHandlers) CallbackHandler() gin.HandlerFunc {
 			}
 
 			emailVerified = emailVerifiedClaim(idToken)
+			issuer = idToken.Issuer
 
 		default:
 			// Check for SAML provider type ("saml" or "saml:<idp_name>")
@@ -483,6 +518,17 @@ func (h *AuthHandlers) CallbackHandler() gin.HandlerFunc {
 			callbackError("email_bound", err.Error())
 			return
 		}
+		linkToken, linkExpires, err := h.requireIdentityLink(ctx, c.ClientIP(), identityLogin{
+			Provider: sessionState.ProviderType, Issuer: issuer, Sub: sub, Email: email, Name: name, EmailVerified: oidcEmailVerified,
+		})
+		if err != nil {
+			callbackError("user_creation_failed", "Failed to look up or create your account.")
+			return
+		}
+		if linkToken != "" {
+			h.redirectIdentityLinkRequired(c, linkToken, linkExpires)
+			return
+		}
 		user, err := h.userRepo.GetOrCreateUserByOIDC(ctx, sub, email, name, oidcEmailVerified)
 		if err != nil {
 			callbackError("user_creation_failed", "Failed to look up or create your account.")
@@ -501,6 +547,7 @@ func (h *AuthHandlers) CallbackHandler() gin.HandlerFunc {
 		if err != nil {
 			scopes = []string{}
 		}
+		scopes = h.issuedScopes(ctx, user, scopes)
 
 		// Generate JWT token for user
 		jwtToken, err := auth.GenerateJWT(user.ID, user.Email, scopes, 24*time.Hour)
@@ -508,6 +555,7 @@ func (h *AuthHandlers) CallbackHandler() gin.HandlerFunc {
 			callbackError("jwt_failed", "Failed to generate an authentication token.")
 			return
 		}
+		h.recordLogin(ctx, user.ID, sessionState.ProviderType, issuer)
 
 		// Set HttpOnly cookie — prevents JS access, logging, and Referer leakage.
 		// SameSite=Lax allows the cookie to survive the top-level redirect from
@@ -694,6 +742,7 @@ func (h *AuthHandlers) RefreshHandler() gin.HandlerFunc {
 		if err != nil {
 			scopes = []string{}
 		}
+		scopes = h.issuedScopes(c.Request.Context(), user, scopes)
 
 		// Revoke the old JWT so it cannot be replayed after refresh.
 		if claims, exists := c.Get("jwt_claims"); exists {
@@ -737,7 +786,7 @@ func (h *AuthHandlers) RefreshHandler() gin.HandlerFunc {
 }
 
 // @Summary      Get current user
-// @Description  Retrieve information about the currently authenticated user, including organization memberships and role templates
+// @Description  Retrieve information about the currently authenticated user, including organization memberships, role templates, the organization the request acts for, and the catalog of all scopes
 // @Tags         Authentication
 // @Security     Bearer
 // @Accept       json
@@ -817,9 +866,22 @@ func (h *AuthHandlers) MeHandler() gin.HandlerFunc {
 		}
 		response["memberships"] = memberships
 
-		// Calculate combined allowed scopes across all organizations
-		// and provide a "primary" role template (highest privilege) for backward compatibility
-		response["allowed_scopes"] = userWithRoles.GetAllowedScopes() //nolint:staticcheck // SA1019: deliberate suite-wide combined view for this admin display endpoint; narrow legitimate use per the deprecation notice
+		// Calculate combined allowed scopes across all organizations, clipped
+		// to the organizations' scope ceilings as an issued token would be,
+		// and name the scopes a ceiling removed so the UI and support can tell
+		// why an action is unavailable.
+		allowedScopes, removedScopes, err := h.userScopeCeilings(c.Request.Context(), &userWithRoles.User,
+			userWithRoles.GetAllowedScopes()) //nolint:staticcheck // SA1019: deliberate suite-wide combined view for this admin display endpoint; narrow legitimate use per the deprecation notice
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get scope ceilings",
+			})
+			return
+		}
+		response["allowed_scopes"] = allowedScopes
+		response["scopes_removed_by_ceiling"] = removedScopes
+		// The catalog of every scope, so the UI can describe and group them
+		response["scope_catalog"] = auth.ScopeCatalog()
 
 		// Include session expiry from JWT claims so the frontend can schedule the
 		// pre-expiry warning dialog for cookie-based sessions. Absent for API-key auth.
@@ -830,6 +892,16 @@ func (h *AuthHandlers) MeHandler() gin.HandlerFunc {
 			}
 		}
 
+		// The organization the request acts for, as resolved by the auth
+		// middleware: the API key's organization, the token's organization or
+		// the user's only membership. Absent when it is ambiguous.
+		if orgID := c.GetString("organization_id"); orgID != "" {
+			response["organization_id"] = orgID
+			if role := middleware.OrganizationRoleFromContext(c); role != "" {
+				response["organization_role"] = role
+			}
+		}
+
 		// For backward compatibility, provide the first membership's role template as primary
 		// In a multi-org setup, the frontend should use per-org memberships
 		if len(userWithRoles.Memberships) > 0 && userWithRoles.Memberships[0].RoleTemplateID != nil {
@@ -846,6 +918,17 @@ func (h *AuthHandlers) MeHandler() gin.HandlerFunc {
 	}
 }
 
+// recordLogin records a successful login when a recorder is configured. A
+// failure is logged and does not fail the login.
+func (h *AuthHandlers) recordLogin(ctx context.Context, userID, provider, issuer string) {
+	if h.loginRepo == nil {
+		return
+	}
+	if err := h.loginRepo.RecordLogin(ctx, userID, provider, issuer); err != nil {
+		slog.Warn("failed to record user login", "user_id", userID, "provider", provider, "error", err)
+	}
+}
+
 // resolveGroupClaimName returns the effective group claim name to use when
 // extracting IdP group memberships from the OIDC ID token.
 // Priority: DB-stored OIDC config > env/file config.
@@ -1230,6 +1313,17 @@ func (h *AuthHandlers) SAMLACSHandler() gin.HandlerFunc {
 			callbackError("email_bound", err.Error())
 			return
 		}
+		linkToken, linkExpires, err := h.requireIdentityLink(ctx, c.ClientIP(), identityLogin{
+			Provider: models.AuthProviderSAML, Issuer: idpName, Sub: sub, Email: userInfo.Email, Name: userInfo.Name, EmailVerified: true,
+		})
+		if err != nil {
+			callbackError("user_creation_failed", "Failed to look up or create your account.")
+			return
+		}
+		if linkToken != "" {
+			h.redirectIdentityLinkRequired(c, linkToken, linkExpires)
+			return
+		}
 
 		// Get or create user (reuse the OIDC path — sub is unique per IdP).
 		// emailVerified=true: the email comes from a signed SAML assertion the IdP
@@ -1253,6 +1347,7 @@ func (h *AuthHandlers) SAMLACSHandler() gin.HandlerFunc {
 		if scopeErr != nil {
 			scopes = []string{}
 		}
+		scopes = h.issuedScopes(ctx, user, scopes)
 
 		// Generate JWT token
 		jwtToken, err := auth.GenerateJWT(user.ID, user.Email, scopes, 24*time.Hour)
@@ -1260,6 +1355,7 @@ func (h *AuthHandlers) SAMLACSHandler() gin.HandlerFunc {
 			callbackError("jwt_failed", "Failed to generate an authentication token.")
 			return
 		}
+		h.recordLogin(ctx, user.ID, models.AuthProviderSAML, idpName)
 
 		// Set HttpOnly cookie
 		http.SetCookie(c.Writer, &http.Cookie{
@@ -1339,6 +1435,7 @@ func (h *AuthHandlers) ProvidersHandler() gin.HandlerFunc {
 // @Success      200  {object}  map[string]interface{}  "Session established via cookie"
 // @Failure      400  {object}  map[string]interface{}  "Missing credentials or LDAP not configured"
 // @Failure      401  {object}  map[string]interface{}  "Invalid username or password"
+// @Failure      409  {object}  admin.IdentityLinkRequiredResponse  "Email matches an existing account; confirm the link first"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/auth/ldap/login [post]
 // LDAPLoginHandler authenticates a user via LDAP with username/password.
@@ -1376,6 +1473,19 @@ func (h *AuthHandlers) LDAPLoginHandler() gin.HandlerFunc {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		linkToken, linkExpires, err := h.requireIdentityLink(ctx, c.ClientIP(), identityLogin{
+			Provider: models.AuthProviderLDAP, Sub: sub, Email: userInfo.Email, Name: userInfo.Name, EmailVerified: true,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up or create your account"})
+			return
+		}
+		if linkToken != "" {
+			c.JSON(http.StatusConflict, IdentityLinkRequiredResponse{
+				Error: identityLinkRequiredMessage, LinkRequired: true, LinkToken: linkToken, ExpiresAt: linkExpires,
+			})
+			return
+		}
 
 		// emailVerified=true: the email is an attribute read directly off the
 		// directory entry the user just bound-authenticated against (not a
@@ -1398,6 +1508,7 @@ func (h *AuthHandlers) LDAPLoginHandler() gin.HandlerFunc {
 		if err != nil {
 			scopes = []string{}
 		}
+		scopes = h.issuedScopes(ctx, user, scopes)
 
 		// Generate JWT
 		jwtToken, err := auth.GenerateJWT(user.ID, user.Email, scopes, 24*time.Hour)
@@ -1405,6 +1516,7 @@ func (h *AuthHandlers) LDAPLoginHandler() gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate authentication token"})
 			return
 		}
+		h.recordLogin(ctx, user.ID, models.AuthProviderLDAP, "")
 
 		// Set HttpOnly cookie
 		http.SetCookie(c.Writer, &http.Cookie{
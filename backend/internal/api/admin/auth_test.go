@@ -24,6 +24,7 @@ import (
 	samlpkg "github.com/terraform-registry/terraform-registry/internal/auth/saml"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 )
 
 // ---------------------------------------------------------------------------
@@ -445,6 +446,10 @@ func TestMeHandler_Success_NoMemberships(t *testing.T) {
 	if resp["user"] == nil {
 		t.Error("response missing 'user'")
 	}
+	catalog, ok := resp["scope_catalog"].([]interface{})
+	if !ok || len(catalog) != len(auth.AllScopes()) {
+		t.Errorf("scope_catalog = %v, want one entry per scope", resp["scope_catalog"])
+	}
 }
 
 func TestMeHandler_DBError(t *testing.T) {
@@ -773,6 +778,40 @@ func TestMeHandler_SuccessWithMemberships(t *testing.T) {
 	}
 }
 
+func TestMeHandler_IncludesOrganizationContext(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	h, _ := NewAuthHandlers(&config.Config{}, db, nil, nil, auth.NewMemoryStateStore(time.Hour))
+	r := gin.New()
+	// As set by the auth middleware for an API key bound to org-1.
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("organization_id", "org-1")
+		c.Set(middleware.OrganizationRoleContextKey, "publisher")
+		c.Next()
+	})
+	r.GET("/auth/me", h.MeHandler())
+
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").
+		WillReturnRows(sqlmock.NewRows(authUserCols).
+			AddRow("user-1", "member@example.com", "Member User", nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM organization_members").
+		WillReturnRows(sqlmock.NewRows(meOrgMembershipCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/me", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	resp := getJSON(w)
+	if resp["organization_id"] != "org-1" || resp["organization_role"] != "publisher" {
+		t.Errorf("organization_id = %v, organization_role = %v; want org-1, publisher",
+			resp["organization_id"], resp["organization_role"])
+	}
+}
+
 // ---------------------------------------------------------------------------
 // CallbackHandler — unknown provider type in session
 // ---------------------------------------------------------------------------
@@ -0,0 +1,265 @@
+// ci_trust_rules.go implements admin CRUD for the trust rules consulted by the
+// CI OIDC token exchange. A rule binds one repository on a trusted issuer
+// (optionally narrowed by ref pattern and deployment environment) to one
+// namespace and the organization that owns it.
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// CITrustRuleHandlers serves the CI trust rule admin endpoints.
+type CITrustRuleHandlers struct {
+	cfg      *config.TokenExchangeConfig
+	orgRepo  *repositories.OrganizationRepository
+	ruleRepo *repositories.CITrustRuleRepository
+}
+
+// NewCITrustRuleHandlers constructs a CITrustRuleHandlers. identityDB backs
+// organization lookups; ruleRepo runs on the registry's own connection.
+func NewCITrustRuleHandlers(cfg *config.TokenExchangeConfig, identityDB *sql.DB, ruleRepo *repositories.CITrustRuleRepository) *CITrustRuleHandlers {
+	return &CITrustRuleHandlers{
+		cfg:      cfg,
+		orgRepo:  repositories.NewOrganizationRepository(identityDB),
+		ruleRepo: ruleRepo,
+	}
+}
+
+// CITrustRuleRequest is the body of POST and PUT /admin/ci-trust-rules.
+type CITrustRuleRequest struct {
+	OrganizationID string `json:"organization_id" binding:"required"`
+	Namespace      string `json:"namespace" binding:"required"`
+	// Issuer defaults to the only configured trusted issuer when omitted.
+	Issuer     string `json:"issuer"`
+	Repository string `json:"repository" binding:"required"` // owner/repo
+	// RefPattern is a glob such as "refs/tags/v*"; empty matches any ref.
+	RefPattern  string `json:"ref_pattern"`
+	Environment string `json:"environment"`
+	Description string `json:"description"`
+	Enabled     *bool  `json:"enabled"` // default true
+}
+
+// @Summary      List CI trust rules
+// @Description  Lists the trust rules that allow CI OIDC identities to exchange their ID token for a namespace-scoped publish token.
+// @Tags         CI Token Exchange
+// @Security     Bearer
+// @Produce      json
+// @Param        namespace  query  string  false  "Only rules for this namespace"
+// @Success      200  {object}  map[string]interface{}  "{\"rules\": []CITrustRule}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/ci-trust-rules [get]
+// ListRules lists CI trust rules.
+// GET /api/v1/admin/ci-trust-rules
+func (h *CITrustRuleHandlers) ListRules(c *gin.Context) {
+	rules, err := h.ruleRepo.List(c.Request.Context(), c.Query("namespace"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list CI trust rules"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// @Summary      Get CI trust rule
+// @Tags         CI Token Exchange
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Rule ID"
+// @Success      200  {object}  map[string]interface{}  "{\"rule\": CITrustRule}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Rule not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/ci-trust-rules/{id} [get]
+// GetRule returns one CI trust rule.
+// GET /api/v1/admin/ci-trust-rules/:id
+func (h *CITrustRuleHandlers) GetRule(c *gin.Context) {
+	rule, err := h.ruleRepo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve CI trust rule"})
+		return
+	}
+	if rule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "CI trust rule not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rule": rule})
+}
+
+// @Summary      Create CI trust rule
+// @Description  Allows CI jobs from `repository` on `issuer` to publish modules into `namespace`. `ref_pattern` (e.g. `refs/tags/v*`) and `environment` narrow the rule further.
+// @Tags         CI Token Exchange
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  CITrustRuleRequest  true  "Rule"
+// @Success      201  {object}  map[string]interface{}  "{\"rule\": CITrustRule}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/ci-trust-rules [post]
+// CreateRule creates a CI trust rule.
+// POST /api/v1/admin/ci-trust-rules
+func (h *CITrustRuleHandlers) CreateRule(c *gin.Context) {
+	rule, ok := h.bindRule(c)
+	if !ok {
+		return
+	}
+	if uid := c.GetString("user_id"); uid != "" {
+		rule.CreatedBy = &uid
+	}
+	if err := h.ruleRepo.Create(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create CI trust rule"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"rule": rule})
+}
+
+// @Summary      Update CI trust rule
+// @Description  Replaces every field of the rule.
+// @Tags         CI Token Exchange
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string              true  "Rule ID"
+// @Param        body  body  CITrustRuleRequest  true  "Rule"
+// @Success      200  {object}  map[string]interface{}  "{\"rule\": CITrustRule}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Rule not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/ci-trust-rules/{id} [put]
+// UpdateRule replaces a CI trust rule.
+// PUT /api/v1/admin/ci-trust-rules/:id
+func (h *CITrustRuleHandlers) UpdateRule(c *gin.Context) {
+	rule, ok := h.bindRule(c)
+	if !ok {
+		return
+	}
+	rule.ID = c.Param("id")
+	found, err := h.ruleRepo.Update(c.Request.Context(), rule)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update CI trust rule"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "CI trust rule not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rule": rule})
+}
+
+// @Summary      Delete CI trust rule
+// @Description  Deletes the rule. Publish tokens already issued under it stay valid until they expire.
+// @Tags         CI Token Exchange
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Rule ID"
+// @Success      200  {object}  admin.MessageResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Rule not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/ci-trust-rules/{id} [delete]
+// DeleteRule deletes a CI trust rule.
+// DELETE /api/v1/admin/ci-trust-rules/:id
+func (h *CITrustRuleHandlers) DeleteRule(c *gin.Context) {
+	deleted, err := h.ruleRepo.Delete(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete CI trust rule"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "CI trust rule not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "CI trust rule deleted"})
+}
+
+// bindRule binds and validates a CITrustRuleRequest, writing a 4xx/5xx
+// response and returning false when it is unusable.
+func (h *CITrustRuleHandlers) bindRule(c *gin.Context) (*models.CITrustRule, bool) {
+	var req CITrustRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return nil, false
+	}
+
+	if err := validation.ValidateRegistrySegment(req.Namespace); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid namespace: " + err.Error()})
+		return nil, false
+	}
+	issuer := req.Issuer
+	if issuer == "" && len(h.cfg.TrustedIssuers) == 1 {
+		issuer = h.cfg.TrustedIssuers[0]
+	}
+	if !h.isTrustedIssuer(issuer) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "issuer must be one of auth.token_exchange.trusted_issuers"})
+		return nil, false
+	}
+	owner, repo, found := strings.Cut(req.Repository, "/")
+	if !found || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "repository must be in owner/repo form"})
+		return nil, false
+	}
+	if req.RefPattern != "" {
+		if _, err := path.Match(req.RefPattern, ""); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ref_pattern: " + err.Error()})
+			return nil, false
+		}
+	}
+
+	org, err := h.orgRepo.GetByID(c.Request.Context(), req.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+		return nil, false
+	}
+	if org == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Organization not found"})
+		return nil, false
+	}
+
+	rule := &models.CITrustRule{
+		OrganizationID: org.ID,
+		Namespace:      req.Namespace,
+		Issuer:         issuer,
+		Repository:     req.Repository,
+		RefPattern:     optionalString(req.RefPattern),
+		Environment:    optionalString(req.Environment),
+		Description:    optionalString(req.Description),
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+	return rule, true
+}
+
+func (h *CITrustRuleHandlers) isTrustedIssuer(issuer string) bool {
+	if issuer == "" {
+		return false
+	}
+	for _, iss := range h.cfg.TrustedIssuers {
+		if iss == issuer {
+			return true
+		}
+	}
+	return false
+}
+
+// optionalString maps "" to nil for nullable columns.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
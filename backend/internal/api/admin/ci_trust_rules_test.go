@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+func newCITrustRuleRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	cfg := &config.TokenExchangeConfig{TrustedIssuers: []string{testCIIssuer}}
+	h := NewCITrustRuleHandlers(cfg, db, repositories.NewCITrustRuleRepository(db))
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "admin-1") })
+	r.GET("/ci-trust-rules", h.ListRules)
+	r.GET("/ci-trust-rules/:id", h.GetRule)
+	r.POST("/ci-trust-rules", h.CreateRule)
+	r.PUT("/ci-trust-rules/:id", h.UpdateRule)
+	r.DELETE("/ci-trust-rules/:id", h.DeleteRule)
+	return mock, r
+}
+
+func doCITrustRuleReq(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateCITrustRule_DefaultsIssuerAndRecordsCreator(t *testing.T) {
+	mock, r := newCITrustRuleRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO ci_trust_rules").
+		WithArgs("org-1", "acme", testCIIssuer, "acme/terraform-aws-vpc", "refs/tags/v*", nil, nil, true, "admin-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("rule-1", time.Now(), time.Now()))
+
+	w := doCITrustRuleReq(r, http.MethodPost, "/ci-trust-rules",
+		`{"organization_id":"org-1","namespace":"acme","repository":"acme/terraform-aws-vpc","ref_pattern":"refs/tags/v*"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestCreateCITrustRule_Validation(t *testing.T) {
+	cases := map[string]string{
+		"untrusted issuer":  `{"organization_id":"org-1","namespace":"acme","issuer":"https://evil.example.com","repository":"acme/vpc"}`,
+		"bad repository":    `{"organization_id":"org-1","namespace":"acme","repository":"acme"}`,
+		"bad ref pattern":   `{"organization_id":"org-1","namespace":"acme","repository":"acme/vpc","ref_pattern":"refs/["}`,
+		"bad namespace":     `{"organization_id":"org-1","namespace":"../x","repository":"acme/vpc"}`,
+		"missing namespace": `{"organization_id":"org-1","repository":"acme/vpc"}`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, r := newCITrustRuleRouter(t)
+			if w := doCITrustRuleReq(r, http.MethodPost, "/ci-trust-rules", body); w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateCITrustRule_UnknownOrganization(t *testing.T) {
+	mock, r := newCITrustRuleRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols))
+
+	w := doCITrustRuleReq(r, http.MethodPost, "/ci-trust-rules",
+		`{"organization_id":"nope","namespace":"acme","repository":"acme/vpc"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetCITrustRule_NotFound(t *testing.T) {
+	mock, r := newCITrustRuleRouter(t)
+	mock.ExpectQuery("SELECT.*FROM ci_trust_rules WHERE id").
+		WillReturnRows(sqlmock.NewRows(ciRuleCols))
+
+	if w := doCITrustRuleReq(r, http.MethodGet, "/ci-trust-rules/missing", ""); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestListCITrustRules_FiltersByNamespace(t *testing.T) {
+	mock, r := newCITrustRuleRouter(t)
+	mock.ExpectQuery("SELECT.*FROM ci_trust_rules WHERE namespace").
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows(ciRuleCols).
+			AddRow("rule-1", "org-1", "acme", testCIIssuer, "acme/vpc", nil, nil, nil, true, nil, nil, time.Now(), time.Now()))
+
+	w := doCITrustRuleReq(r, http.MethodGet, "/ci-trust-rules?namespace=acme", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"rule-1"`) {
+		t.Errorf("status = %d body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDeleteCITrustRule_NotFound(t *testing.T) {
+	mock, r := newCITrustRuleRouter(t)
+	mock.ExpectExec("DELETE FROM ci_trust_rules").
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if w := doCITrustRuleReq(r, http.MethodDelete, "/ci-trust-rules/missing", ""); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
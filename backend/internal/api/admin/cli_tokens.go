@@ -0,0 +1,185 @@
+// cli_tokens.go implements the self-service endpoints for tokens issued to the
+// Terraform CLI. A CLI token carries the `terraform-cli` audience and only the
+// protocol read scopes its owner holds; the auth middleware accepts it solely
+// on the /v1/modules and /v1/providers routes. Users list and revoke their
+// own tokens here; the token itself is shown only once, at creation.
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// defaultCLITokenTTL applies when auth.cli_tokens.token_ttl is unset.
+const defaultCLITokenTTL = 720 * time.Hour
+
+// CLITokenHandlers serves /api/v1/users/me/cli-tokens.
+type CLITokenHandlers struct {
+	cfg       *config.CLITokensConfig
+	cliTokens *repositories.CLITokenRepository
+	// tokenRepo is the shared JTI denylist the auth middleware checks;
+	// revocation adds the token's JTI to it.
+	tokenRepo *repositories.TokenRepository
+}
+
+// NewCLITokenHandlers constructs a CLITokenHandlers.
+func NewCLITokenHandlers(cfg *config.CLITokensConfig, cliTokens *repositories.CLITokenRepository, tokenRepo *repositories.TokenRepository) *CLITokenHandlers {
+	return &CLITokenHandlers{cfg: cfg, cliTokens: cliTokens, tokenRepo: tokenRepo}
+}
+
+// CreateCLITokenRequest is the body of POST /api/v1/users/me/cli-tokens.
+type CreateCLITokenRequest struct {
+	// Description identifies the token in listings, e.g. the machine it is
+	// used on.
+	Description string `json:"description"`
+}
+
+// CLITokenCreatedResponse is returned once, when a CLI token is created.
+type CLITokenCreatedResponse struct {
+	// Token is the bearer token for credentials.tfrc.json. It is not stored
+	// and cannot be retrieved again.
+	Token    string           `json:"token"`
+	CLIToken *models.CLIToken `json:"cli_token"`
+}
+
+func (h *CLITokenHandlers) ttl() time.Duration {
+	if h.cfg != nil && h.cfg.TokenTTL > 0 {
+		return h.cfg.TokenTTL
+	}
+	return defaultCLITokenTTL
+}
+
+// @Summary      Create CLI token
+// @Description  Issues a token for the Terraform CLI (audience `terraform-cli`) carrying the protocol read scopes (modules:read, providers:read) the caller holds. It is accepted only for module and provider protocol reads under /v1/modules and /v1/providers, and rejected by every other endpoint. The token is returned once.
+// @Tags         Users
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  CreateCLITokenRequest  false  "Token description"
+// @Success      201  {object}  admin.CLITokenCreatedResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/users/me/cli-tokens [post]
+// CreateToken issues a CLI token for the current user.
+// POST /api/v1/users/me/cli-tokens
+func (h *CLITokenHandlers) CreateToken(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req CreateCLITokenRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	var email string
+	if v, ok := c.Get("user"); ok {
+		if u, ok := v.(*models.User); ok && u != nil {
+			email = u.Email
+		}
+	}
+	scopes := auth.CLITokenScopes(c.GetStringSlice("scopes"))
+
+	token, claims, err := auth.GenerateCLIToken(userID, email, scopes, h.ttl())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue CLI token"})
+		return
+	}
+	record := &models.CLIToken{
+		ID:        claims.ID,
+		UserID:    userID,
+		Scopes:    scopes,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if d := strings.TrimSpace(req.Description); d != "" {
+		record.Description = &d
+	}
+	if err := h.cliTokens.Create(c.Request.Context(), record); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue CLI token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CLITokenCreatedResponse{Token: token, CLIToken: record})
+}
+
+// @Summary      List CLI tokens
+// @Description  Lists the current user's Terraform CLI tokens, newest first. Expired tokens are omitted unless include_expired=true.
+// @Tags         Users
+// @Security     Bearer
+// @Produce      json
+// @Param        include_expired  query  bool  false  "Include expired tokens"
+// @Success      200  {object}  map[string]interface{}  "{\"cli_tokens\": []CLIToken}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/users/me/cli-tokens [get]
+// ListTokens lists the current user's CLI tokens.
+// GET /api/v1/users/me/cli-tokens
+func (h *CLITokenHandlers) ListTokens(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	tokens, err := h.cliTokens.ListByUser(c.Request.Context(), userID, c.Query("include_expired") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list CLI tokens"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"cli_tokens": tokens})
+}
+
+// @Summary      Revoke CLI token
+// @Description  Revokes one of the current user's Terraform CLI tokens. It stops working immediately. Revoking an already revoked token succeeds.
+// @Tags         Users
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "CLI token ID"
+// @Success      200  {object}  map[string]interface{}  "Token revoked"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Token not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/users/me/cli-tokens/{id} [delete]
+// RevokeToken revokes one of the current user's CLI tokens.
+// DELETE /api/v1/users/me/cli-tokens/:id
+func (h *CLITokenHandlers) RevokeToken(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	record, err := h.cliTokens.GetForUser(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke CLI token"})
+		return
+	}
+	if record == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "CLI token not found"})
+		return
+	}
+	if record.RevokedAt == nil {
+		// Denylist first: the middleware checks it, so the token stops
+		// working even if marking the record fails.
+		if err := h.tokenRepo.RevokeToken(c.Request.Context(), record.ID, userID, record.ExpiresAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke CLI token"})
+			return
+		}
+		if err := h.cliTokens.MarkRevoked(c.Request.Context(), record.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke CLI token"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "CLI token revoked"})
+}
@@ -0,0 +1,144 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var cliTokenRowCols = []string{"id", "user_id", "description", "scopes", "created_at", "expires_at", "revoked_at"}
+
+// newCLITokenRouter wires the CLI token handlers over one mocked connection
+// standing in for both the registry and identity databases.
+func newCLITokenRouter(t *testing.T, userID string, scopes []string) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewCLITokenHandlers(&config.CLITokensConfig{TokenTTL: time.Hour},
+		repositories.NewCLITokenRepository(db), repositories.NewTokenRepository(db))
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if userID != "" {
+			c.Set("user_id", userID)
+		}
+		c.Set("scopes", scopes)
+	})
+	r.POST("/cli-tokens", h.CreateToken)
+	r.GET("/cli-tokens", h.ListTokens)
+	r.DELETE("/cli-tokens/:id", h.RevokeToken)
+	return mock, r
+}
+
+func doCLITokenReq(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCLITokens_CreateIssuesReadOnlyToken(t *testing.T) {
+	mock, r := newCLITokenRouter(t, "user-1", []string{string(auth.ScopeModulesWrite), string(auth.ScopeAuditRead)})
+	mock.ExpectQuery("INSERT INTO cli_tokens").
+		WithArgs(sqlmock.AnyArg(), "user-1", "laptop", []byte(`["modules:read"]`), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+
+	w := doCLITokenReq(r, http.MethodPost, "/cli-tokens", `{"description":" laptop "}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	var resp CLITokenCreatedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	claims, err := auth.ValidateCLIToken(resp.Token)
+	if err != nil {
+		t.Fatalf("issued token does not validate: %v", err)
+	}
+	if claims.ID != resp.CLIToken.ID || claims.UserID() != "user-1" {
+		t.Errorf("claims = %+v, record = %+v", claims, resp.CLIToken)
+	}
+	if len(claims.Scopes) != 1 || claims.Scopes[0] != string(auth.ScopeModulesRead) {
+		t.Errorf("scopes = %v, want [modules:read]", claims.Scopes)
+	}
+}
+
+func TestCLITokens_CreateUnauthenticated(t *testing.T) {
+	_, r := newCLITokenRouter(t, "", nil)
+	if w := doCLITokenReq(r, http.MethodPost, "/cli-tokens", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+}
+
+func TestCLITokens_List(t *testing.T) {
+	mock, r := newCLITokenRouter(t, "user-1", nil)
+	mock.ExpectQuery("SELECT.*FROM cli_tokens WHERE user_id = \\$1 AND expires_at > NOW\\(\\)").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(cliTokenRowCols).
+			AddRow("jti-1", "user-1", nil, []byte(`["modules:read"]`), time.Now(), time.Now().Add(time.Hour), nil))
+
+	w := doCLITokenReq(r, http.MethodGet, "/cli-tokens", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"jti-1"`) {
+		t.Errorf("status = %d body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCLITokens_RevokeAddsJTIToDenylist(t *testing.T) {
+	mock, r := newCLITokenRouter(t, "user-1", nil)
+	expires := time.Now().Add(time.Hour)
+	mock.ExpectQuery("SELECT.*FROM cli_tokens WHERE id = \\$1 AND user_id = \\$2").
+		WithArgs("jti-1", "user-1").
+		WillReturnRows(sqlmock.NewRows(cliTokenRowCols).
+			AddRow("jti-1", "user-1", nil, []byte(`[]`), time.Now(), expires, nil))
+	mock.ExpectExec("INSERT INTO revoked_tokens").
+		WithArgs("jti-1", "user-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE cli_tokens SET revoked_at").
+		WithArgs("jti-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if w := doCLITokenReq(r, http.MethodDelete, "/cli-tokens/jti-1", ""); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestCLITokens_RevokeOtherUsersToken(t *testing.T) {
+	mock, r := newCLITokenRouter(t, "user-2", nil)
+	mock.ExpectQuery("SELECT.*FROM cli_tokens WHERE id = \\$1 AND user_id = \\$2").
+		WithArgs("jti-1", "user-2").
+		WillReturnRows(sqlmock.NewRows(cliTokenRowCols))
+
+	if w := doCLITokenReq(r, http.MethodDelete, "/cli-tokens/jti-1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestCLITokens_RevokeAlreadyRevoked(t *testing.T) {
+	mock, r := newCLITokenRouter(t, "user-1", nil)
+	mock.ExpectQuery("SELECT.*FROM cli_tokens WHERE id = \\$1 AND user_id = \\$2").
+		WillReturnRows(sqlmock.NewRows(cliTokenRowCols).
+			AddRow("jti-1", "user-1", nil, []byte(`[]`), time.Now(), time.Now().Add(time.Hour), time.Now()))
+
+	if w := doCLITokenReq(r, http.MethodDelete, "/cli-tokens/jti-1", ""); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// code_index.go implements the admin endpoints of the module content index
+// (jobs.CodeIndexJob) behind code search: its progress, and a backfill that
+// queues existing module versions to be indexed, or indexed again.
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// codeIndexErrorsListed caps the failed versions listed in the status.
+const codeIndexErrorsListed = 50
+
+// CodeIndexerInterface queues an indexing run. *jobs.CodeIndexJob satisfies it.
+type CodeIndexerInterface interface {
+	TriggerIndex() bool
+}
+
+// CodeIndexHandlers serves the code index admin endpoints.
+type CodeIndexHandlers struct {
+	cfg     *config.CodeIndexConfig
+	repo    *repositories.ModuleCodeIndexRepository
+	indexer CodeIndexerInterface
+}
+
+// NewCodeIndexHandlers constructs a CodeIndexHandlers.
+func NewCodeIndexHandlers(cfg *config.CodeIndexConfig, repo *repositories.ModuleCodeIndexRepository) *CodeIndexHandlers {
+	return &CodeIndexHandlers{cfg: cfg, repo: repo}
+}
+
+// SetIndexer enables POST /admin/code-index/backfill.
+func (h *CodeIndexHandlers) SetIndexer(indexer CodeIndexerInterface) {
+	h.indexer = indexer
+}
+
+// CodeIndexStatusResponse is returned by GET /api/v1/admin/code-index.
+type CodeIndexStatusResponse struct {
+	Enabled            bool                          `json:"enabled"`
+	MaxBytesPerVersion int64                         `json:"max_bytes_per_version"`
+	Counts             *models.ModuleCodeIndexCounts `json:"counts"`
+	// Errors lists the most recent versions whose indexing failed.
+	Errors []*models.ModuleCodeIndex `json:"errors"`
+}
+
+// BackfillCodeIndexRequest is the body of POST /api/v1/admin/code-index/backfill.
+type BackfillCodeIndexRequest struct {
+	// ModuleID limits the backfill to one module's versions.
+	ModuleID string `json:"module_id" binding:"omitempty,uuid"`
+	// Reindex queues versions already indexed too, e.g. after raising
+	// code_index.max_bytes_per_version.
+	Reindex bool `json:"reindex"`
+}
+
+// BackfillCodeIndexResponse is returned by POST /api/v1/admin/code-index/backfill.
+type BackfillCodeIndexResponse struct {
+	Message string `json:"message"`
+	Queued  int64  `json:"queued"`
+}
+
+// @Summary      Get code index status
+// @Description  Returns the progress of the module content index behind code search: module versions by index status (unqueued versions have not been picked up by the indexer yet) and the most recent versions whose indexing failed.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  admin.CodeIndexStatusResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/code-index [get]
+// GetStatus returns the code index status.
+// GET /api/v1/admin/code-index
+func (h *CodeIndexHandlers) GetStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	counts, err := h.repo.Counts(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count indexed module versions"})
+		return
+	}
+	errs, err := h.repo.ListErrors(ctx, codeIndexErrorsListed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list code index errors"})
+		return
+	}
+	c.JSON(http.StatusOK, CodeIndexStatusResponse{
+		Enabled:            h.cfg.Enabled,
+		MaxBytesPerVersion: h.cfg.MaxBytesPerVersion,
+		Counts:             counts,
+		Errors:             errs,
+	})
+}
+
+// @Summary      Backfill code index
+// @Description  Queues existing module versions for the code index and starts an indexing run. Versions never indexed and versions whose indexing failed are queued; with reindex, versions already indexed are too. module_id limits the backfill to one module. Versions being indexed are left alone.
+// @Tags         System
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  admin.BackfillCodeIndexRequest  false  "Backfill options"
+// @Success      202  {object}  admin.BackfillCodeIndexResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      409  {object}  map[string]interface{}  "Indexer disabled (code_index.enabled=false)"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      503  {object}  map[string]interface{}  "Indexer not configured"
+// @Router       /api/v1/admin/code-index/backfill [post]
+// Backfill queues module versions for indexing.
+// POST /api/v1/admin/code-index/backfill
+func (h *CodeIndexHandlers) Backfill(c *gin.Context) {
+	if h.indexer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Code indexer not configured"})
+		return
+	}
+	if !h.cfg.Enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Code indexer is disabled (code_index.enabled=false)"})
+		return
+	}
+	var req BackfillCodeIndexRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	queued, err := h.repo.Backfill(c.Request.Context(), req.ModuleID, req.Reindex)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue module versions for indexing"})
+		return
+	}
+	h.indexer.TriggerIndex()
+	c.JSON(http.StatusAccepted, BackfillCodeIndexResponse{Message: "Module versions queued for indexing", Queued: queued})
+}
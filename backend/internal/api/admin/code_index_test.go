@@ -0,0 +1,120 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+type fakeCodeIndexer struct{ triggered int }
+
+func (f *fakeCodeIndexer) TriggerIndex() bool {
+	f.triggered++
+	return true
+}
+
+func newCodeIndexRouter(t *testing.T, cfg *config.CodeIndexConfig) (sqlmock.Sqlmock, *gin.Engine, *fakeCodeIndexer) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	indexer := &fakeCodeIndexer{}
+	h := NewCodeIndexHandlers(cfg, repositories.NewModuleCodeIndexRepository(db))
+	h.SetIndexer(indexer)
+	r := gin.New()
+	r.GET("/admin/code-index", h.GetStatus)
+	r.POST("/admin/code-index/backfill", h.Backfill)
+	return mock, r, indexer
+}
+
+func TestCodeIndex_GetStatus(t *testing.T) {
+	mock, r, _ := newCodeIndexRouter(t, &config.CodeIndexConfig{Enabled: true, MaxBytesPerVersion: 1 << 20})
+	mock.ExpectQuery("SELECT status, COUNT").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).AddRow("indexed", 4).AddRow("error", 1))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM module_versions").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	now := time.Now()
+	mock.ExpectQuery("FROM module_version_code_index.*WHERE status = 'error'").
+		WithArgs(codeIndexErrorsListed).
+		WillReturnRows(sqlmock.NewRows([]string{"module_version_id", "status", "files_indexed", "symbols_indexed", "bytes_indexed",
+			"truncated", "error_message", "indexed_at", "created_at", "updated_at"}).
+			AddRow("mv-1", "error", 0, 0, 0, false, "extract: unexpected EOF", nil, now, now))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/code-index", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var got CodeIndexStatusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !got.Enabled || got.Counts.Indexed != 4 || got.Counts.Unqueued != 3 || len(got.Errors) != 1 {
+		t.Errorf("status = %+v", got)
+	}
+}
+
+func TestCodeIndex_Backfill(t *testing.T) {
+	t.Run("queues and triggers a run", func(t *testing.T) {
+		mock, r, indexer := newCodeIndexRouter(t, &config.CodeIndexConfig{Enabled: true})
+		mock.ExpectExec("INSERT INTO module_version_code_index").
+			WithArgs("7d9f0a52-5f5c-4a8e-9d0e-3c1b2a4f6e71", true).
+			WillReturnResult(sqlmock.NewResult(0, 12))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/code-index/backfill",
+			strings.NewReader(`{"module_id": "7d9f0a52-5f5c-4a8e-9d0e-3c1b2a4f6e71", "reindex": true}`)))
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+		}
+		var got BackfillCodeIndexResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Queued != 12 {
+			t.Errorf("response = %s", w.Body.String())
+		}
+		if indexer.triggered != 1 {
+			t.Errorf("triggered = %d, want 1", indexer.triggered)
+		}
+	})
+
+	t.Run("no body queues every unindexed version", func(t *testing.T) {
+		mock, r, _ := newCodeIndexRouter(t, &config.CodeIndexConfig{Enabled: true})
+		mock.ExpectExec("INSERT INTO module_version_code_index").
+			WithArgs("", false).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/code-index/backfill", nil))
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("invalid module id", func(t *testing.T) {
+		_, r, _ := newCodeIndexRouter(t, &config.CodeIndexConfig{Enabled: true})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/code-index/backfill", strings.NewReader(`{"module_id": "nope"}`)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		_, r, _ := newCodeIndexRouter(t, &config.CodeIndexConfig{})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/code-index/backfill", nil))
+		if w.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409", w.Code)
+		}
+	})
+}
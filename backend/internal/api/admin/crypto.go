@@ -0,0 +1,57 @@
+// crypto.go implements the admin task that re-seals stored secrets with the
+// current ENCRYPTION_KEY after a key rotation, so ENCRYPTION_KEY_PREVIOUS can
+// be retired.
+package admin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+// CryptoHandlers serves the encryption key admin endpoints.
+type CryptoHandlers struct {
+	reencryptor *services.SecretReencryptor
+}
+
+// NewCryptoHandlers constructs a CryptoHandlers.
+func NewCryptoHandlers(reencryptor *services.SecretReencryptor) *CryptoHandlers {
+	return &CryptoHandlers{reencryptor: reencryptor}
+}
+
+// @Summary      Re-encrypt stored secrets
+// @Description  Walks every encrypted column (SCM OAuth and provider tokens, SCM provider client secrets and app keys, storage credentials, OIDC client secrets, notification channel targets), decrypts each value with whichever of ENCRYPTION_KEY and ENCRYPTION_KEY_PREVIOUS opens it, and re-seals values still under the previous key with the current one. Values neither key opens are listed under failures and left untouched. Progress is reported in /admin/operations. With dry_run=true nothing is written. Safe to re-run. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Param        dry_run  query  bool  false  "Only report what would be re-sealed"
+// @Success      200  {object}  services.SecretReencryptReport
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      503  {object}  map[string]interface{}  "Cancelled"
+// @Router       /api/v1/admin/crypto/reencrypt [post]
+// Reencrypt re-seals stored secrets with the current encryption key.
+// POST /api/v1/admin/crypto/reencrypt
+func (h *CryptoHandlers) Reencrypt(c *gin.Context) {
+	apply := c.Query("dry_run") != "true"
+	report, err := h.reencryptor.Run(c.Request.Context(), apply)
+	if errors.Is(err, context.Canceled) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Re-encryption cancelled", "report": report})
+		return
+	}
+	if err != nil {
+		slog.Error("re-encryption failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-encrypt secrets"})
+		return
+	}
+	if apply {
+		slog.Info("re-encrypted stored secrets", "user_id", c.GetString("user_id"),
+			"resealed", report.Resealed, "failed", report.Failed, "stale", report.Stale)
+	}
+	c.JSON(http.StatusOK, report)
+}
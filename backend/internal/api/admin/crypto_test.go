@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+func newCryptoRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine, *crypto.TokenCipher) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	oldKey := bytes.Repeat([]byte("o"), 32)
+	dual, _ := crypto.NewTokenCipherWithPrevious(bytes.Repeat([]byte("n"), 32), oldKey)
+	oldCipher, _ := crypto.NewTokenCipher(oldKey)
+
+	h := NewCryptoHandlers(services.NewSecretReencryptor(repositories.NewEncryptedColumnRepository(db), dual))
+	r := gin.New()
+	r.POST("/admin/crypto/reencrypt", h.Reencrypt)
+	return mock, r, oldCipher
+}
+
+// expectEncryptedColumns expects one listing per encrypted column; the first
+// returns rows, the rest are empty.
+func expectEncryptedColumns(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	for i, col := range repositories.EncryptedColumns {
+		q := mock.ExpectQuery("SELECT .* FROM " + regexp.QuoteMeta(col.Table) + " WHERE " + regexp.QuoteMeta(col.Column))
+		if i == 0 {
+			q.WillReturnRows(rows)
+		} else {
+			q.WillReturnRows(sqlmock.NewRows([]string{"id", "v"}))
+		}
+	}
+}
+
+func TestReencrypt_ResealsPreviousKeyValues(t *testing.T) {
+	mock, r, oldCipher := newCryptoRouter(t)
+	sealedOld, _ := oldCipher.Seal("secret")
+	expectEncryptedColumns(mock, sqlmock.NewRows([]string{"id", "v"}).AddRow("p-1", sealedOld))
+	mock.ExpectExec("UPDATE scm_providers").
+		WithArgs(sqlmock.AnyArg(), "p-1", sealedOld).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/crypto/reencrypt", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var report services.SecretReencryptReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.DryRun || report.Resealed != 1 || len(report.Columns) != len(repositories.EncryptedColumns) {
+		t.Errorf("report = %+v", report)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReencrypt_DryRun(t *testing.T) {
+	mock, r, oldCipher := newCryptoRouter(t)
+	sealedOld, _ := oldCipher.Seal("secret")
+	expectEncryptedColumns(mock, sqlmock.NewRows([]string{"id", "v"}).AddRow("p-1", sealedOld))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/crypto/reencrypt?dry_run=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var report services.SecretReencryptReport
+	_ = json.Unmarshal(w.Body.Bytes(), &report)
+	if !report.DryRun || report.Previous != 1 || report.Resealed != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReencrypt_ListError(t *testing.T) {
+	mock, r, _ := newCryptoRouter(t)
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("boom"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/crypto/reencrypt", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
@@ -3,6 +3,7 @@ package admin
 
 import (
 	"database/sql"
+	"log/slog"
 	"net/http"
 	"os"
 	"time"
@@ -10,27 +11,43 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/auth"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 )
 
 // DevHandlers handles development-only endpoints
 type DevHandlers struct {
-	cfg      *config.Config
-	db       *sql.DB
-	userRepo *repositories.UserRepository
-	orgRepo  *repositories.OrganizationRepository
+	cfg       *config.Config
+	db        *sql.DB
+	userRepo  *repositories.UserRepository
+	orgRepo   *repositories.OrganizationRepository
+	loginRepo *repositories.UserLoginRepository
+	auditRepo *repositories.AuditRepository
 }
 
 // NewDevHandlers creates a new DevHandlers instance
 func NewDevHandlers(cfg *config.Config, db *sql.DB) *DevHandlers {
 	return &DevHandlers{
-		cfg:      cfg,
-		db:       db,
-		userRepo: repositories.NewUserRepository(db),
-		orgRepo:  repositories.NewOrganizationRepository(db),
+		cfg:       cfg,
+		db:        db,
+		userRepo:  repositories.NewUserRepository(db),
+		orgRepo:   repositories.NewOrganizationRepository(db),
+		loginRepo: repositories.NewUserLoginRepository(db),
 	}
 }
 
+// WithAuditRepo records every dev login and impersonation in the audit log.
+// The dev group sits outside AuditMiddleware, and the dev login is
+// unauthenticated, so the handlers write the entries themselves.
+func (h *DevHandlers) WithAuditRepo(auditRepo *repositories.AuditRepository) *DevHandlers {
+	h.auditRepo = auditRepo
+	return h
+}
+
+// DevModeHeader is set on every response from the dev endpoints while dev
+// mode is enabled, so proxies and monitoring can spot them.
+const DevModeHeader = "X-Registry-Dev-Mode"
+
 // IsDevMode checks if the application is running in development mode.
 // Requires explicit opt-in via DEV_MODE=true or DEV_MODE=1 environment variable.
 func IsDevMode() bool {
@@ -38,7 +55,8 @@ func IsDevMode() bool {
 	return devMode == "true" || devMode == "1"
 }
 
-// DevModeMiddleware blocks access to dev endpoints in production
+// DevModeMiddleware blocks access to dev endpoints in production. When dev
+// mode is enabled it marks every dev response with DevModeHeader.
 func DevModeMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !IsDevMode() {
@@ -47,6 +65,7 @@ func DevModeMiddleware() gin.HandlerFunc {
 			})
 			return
 		}
+		c.Header(DevModeHeader, "enabled")
 		c.Next()
 	}
 }
@@ -78,6 +97,7 @@ func (h *DevHandlers) ImpersonateUserHandler() gin.HandlerFunc {
 
 		// Only admins can impersonate
 		if !auth.HasScope(scopes, auth.ScopeAdmin) {
+			h.recordAudit(c, "dev.impersonate_denied", targetUserID, nil)
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "Only administrators can impersonate users",
 			})
@@ -115,6 +135,7 @@ func (h *DevHandlers) ImpersonateUserHandler() gin.HandlerFunc {
 		// Deliver the impersonated session via the same httpOnly auth cookie
 		// (plus CSRF cookie) as the interactive login flows.
 		setSessionCookies(c, token)
+		h.recordAudit(c, "dev.impersonate", targetUser.ID, map[string]interface{}{"target_email": targetUser.Email})
 
 		c.JSON(http.StatusOK, gin.H{
 			"user":    targetUser,
@@ -223,9 +244,15 @@ func (h *DevHandlers) DevLoginHandler() gin.HandlerFunc {
 			return
 		}
 
+		// Recorded like the interactive logins (impersonation is not a login).
+		if err := h.loginRepo.RecordLogin(c.Request.Context(), user.ID, models.AuthProviderDev, ""); err != nil {
+			slog.Warn("failed to record user login", "user_id", user.ID, "provider", models.AuthProviderDev, "error", err)
+		}
+
 		// Deliver the session via the same httpOnly auth cookie (plus CSRF
 		// cookie) as the interactive login flows.
 		setSessionCookies(c, token)
+		h.recordAudit(c, "dev.login", user.ID, map[string]interface{}{"email": user.Email})
 
 		c.JSON(http.StatusOK, gin.H{
 			"user":       user,
@@ -244,3 +271,33 @@ func (h *DevHandlers) DevStatusHandler() gin.HandlerFunc {
 		})
 	}
 }
+
+// recordAudit writes a dev login or impersonation to the audit log. The
+// actor is the authenticated caller, if any; targetUserID is the user the
+// minted session belongs to.
+func (h *DevHandlers) recordAudit(c *gin.Context, action, targetUserID string, extra map[string]interface{}) {
+	slog.Warn("dev endpoint used", "action", action, "target_user_id", targetUserID, "ip", c.ClientIP())
+	if h.auditRepo == nil {
+		return
+	}
+
+	metadata := map[string]interface{}{"dev_mode": true}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+	resourceType := "user"
+	ip := c.ClientIP()
+	entry := &models.AuditLog{
+		Action:       action,
+		ResourceType: &resourceType,
+		ResourceID:   &targetUserID,
+		Metadata:     metadata,
+		IPAddress:    &ip,
+	}
+	if userID := c.GetString("user_id"); userID != "" {
+		entry.UserID = &userID
+	}
+	if err := h.auditRepo.CreateAuditLog(c.Request.Context(), entry); err != nil {
+		slog.Error("failed to write audit log for dev endpoint", "error", err, "action", action)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/middleware"
 )
 
@@ -138,6 +139,9 @@ func TestDevModeMiddleware_Allowed(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("status = %d, want 200", w.Code)
 	}
+	if got := w.Header().Get(DevModeHeader); got != "enabled" {
+		t.Errorf("%s = %q, want enabled", DevModeHeader, got)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -205,6 +209,9 @@ func TestDevLogin_Success(t *testing.T) {
 	// GetUserCombinedScopes -> GetUserMemberships (empty)
 	mock.ExpectQuery("SELECT.*FROM organization_members").
 		WillReturnRows(sqlmock.NewRows(membershipSQLCols))
+	mock.ExpectExec("INSERT INTO user_logins").
+		WithArgs("user-1", "dev", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("POST", "/dev/login", nil))
@@ -223,6 +230,46 @@ func TestDevLogin_Success(t *testing.T) {
 		t.Error("response missing 'expires_in' key")
 	}
 	assertSessionCookies(t, w)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("login not recorded: %v", err)
+	}
+}
+
+func TestDevLogin_Audited(t *testing.T) {
+	t.Setenv("TFR_JWT_SECRET", "test-secret-key-that-is-at-least-32-characters-long")
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	auditDB, auditMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { auditDB.Close() })
+
+	h := NewDevHandlers(&config.Config{}, db).WithAuditRepo(repositories.NewAuditRepository(auditDB))
+	r := gin.New()
+	r.POST("/dev/login", h.DevLoginHandler())
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT.*FROM users WHERE email").
+		WillReturnRows(sqlmock.NewRows(devUserCols).
+			AddRow("user-1", "admin@dev.local", "Dev Admin", nil, now, now))
+	mock.ExpectQuery("SELECT.*FROM organization_members").
+		WillReturnRows(sqlmock.NewRows(membershipSQLCols))
+	mock.ExpectExec("INSERT INTO user_logins").WillReturnResult(sqlmock.NewResult(0, 1))
+	auditMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/dev/login", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if err := auditMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("dev login not audited: %v", err)
+	}
 }
 
 // ---------------------------------------------------------------------------
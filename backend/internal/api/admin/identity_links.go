@@ -0,0 +1,419 @@
+// identity_links.go implements account linking between existing accounts and
+// SSO identities. A login whose verified email matches an account without a
+// linked identity is not merged into it silently: it gets a link token, and
+// the identity is linked once the user confirms with the token while
+// authenticated as the existing account, or an admin approves the request.
+//
+//   - POST   /api/v1/auth/link-identity           — confirm a link as the account's user
+//   - GET    /api/v1/admin/users/:id/link-identity — linked identity and pending requests
+//   - POST   /api/v1/admin/users/:id/link-identity — approve a pending request
+//   - DELETE /api/v1/admin/users/:id/link-identity — unlink the linked identity
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// identityLinkTTL is how long a link request waits for confirmation or
+// approval.
+const identityLinkTTL = 24 * time.Hour
+
+// identityLinkRequiredMessage tells a user whose login needs linking what to do.
+const identityLinkRequiredMessage = "An account with this email already exists. Sign in to it and confirm the link, or ask an administrator to approve it."
+
+// IdentityLinkHandlers serves the identity linking endpoints.
+type IdentityLinkHandlers struct {
+	userRepo  *repositories.UserRepository
+	linkRepo  *repositories.IdentityLinkRepository
+	auditRepo *repositories.AuditRepository
+}
+
+// NewIdentityLinkHandlers constructs an IdentityLinkHandlers. db is the
+// identity connection.
+func NewIdentityLinkHandlers(db *sql.DB, linkRepo *repositories.IdentityLinkRepository) *IdentityLinkHandlers {
+	return &IdentityLinkHandlers{
+		userRepo:  repositories.NewUserRepository(db),
+		linkRepo:  linkRepo,
+		auditRepo: repositories.NewAuditRepository(db),
+	}
+}
+
+// IdentityLinkRequiredResponse is returned instead of a session when a login
+// must be linked to an existing account first.
+type IdentityLinkRequiredResponse struct {
+	Error        string `json:"error"`
+	LinkRequired bool   `json:"link_required"`
+	// LinkToken confirms the link through POST /api/v1/auth/link-identity.
+	LinkToken string    `json:"link_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ConfirmIdentityLinkRequest is the body of POST /api/v1/auth/link-identity.
+type ConfirmIdentityLinkRequest struct {
+	LinkToken string `json:"link_token" binding:"required"`
+}
+
+// ApproveIdentityLinkRequest is the body of POST /api/v1/admin/users/:id/link-identity.
+type ApproveIdentityLinkRequest struct {
+	RequestID string `json:"request_id" binding:"required,uuid"`
+}
+
+// IdentityLinkResponse is returned when an identity is linked or unlinked.
+type IdentityLinkResponse struct {
+	Message string `json:"message"`
+	UserID  string `json:"user_id"`
+	OIDCSub string `json:"oidc_sub"`
+}
+
+// IdentityLinkStatusResponse is returned by GET /api/v1/admin/users/:id/link-identity.
+type IdentityLinkStatusResponse struct {
+	UserID string `json:"user_id"`
+	// OIDCSub is the linked identity; null when the account has none.
+	OIDCSub *string `json:"oidc_sub"`
+	// ActiveAPIKeys counts the account's unexpired API keys, which remain
+	// usable when the identity is unlinked.
+	ActiveAPIKeys   int                           `json:"active_api_keys"`
+	PendingRequests []*models.IdentityLinkRequest `json:"pending_requests"`
+}
+
+// @Summary      Confirm identity link
+// @Description  Links the SSO identity of a login that answered link_required to the caller's account. The caller must be authenticated as the existing account (session or API key) that the link token was issued for; the identity is then used for that account's logins, keeping its memberships and API keys. Link tokens are single-use and expire after 24 hours.
+// @Tags         Authentication
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  admin.ConfirmIdentityLinkRequest  true  "Link token"
+// @Success      200  {object}  admin.IdentityLinkResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Link token issued for a different account"
+// @Failure      404  {object}  map[string]interface{}  "Link token not found or expired"
+// @Failure      409  {object}  map[string]interface{}  "Account or identity already linked"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/auth/link-identity [post]
+// ConfirmLink links an identity as the account's own user.
+// POST /api/v1/auth/link-identity
+func (h *IdentityLinkHandlers) ConfirmLink(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	var body ConfirmIdentityLinkRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	req, err := h.linkRepo.GetRequestByTokenHash(c.Request.Context(), hashLinkToken(body.LinkToken))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up link request"})
+		return
+	}
+	if req == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link token not found or expired"})
+		return
+	}
+	if req.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Link token was issued for a different account"})
+		return
+	}
+	h.link(c, req, "user")
+}
+
+// @Summary      Get identity link status
+// @Description  Returns the SSO identity linked to a user, the number of unexpired API keys the user could still authenticate with if it were unlinked, and the pending link requests of logins whose verified email matched the user. Requires admin scope.
+// @Tags         Users
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "User ID"
+// @Success      200  {object}  admin.IdentityLinkStatusResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden — admin scope required"
+// @Failure      404  {object}  map[string]interface{}  "User not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/users/{id}/link-identity [get]
+// GetStatus returns a user's linked identity and pending link requests.
+// GET /api/v1/admin/users/:id/link-identity
+func (h *IdentityLinkHandlers) GetStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	user, ok := h.lookupUser(c)
+	if !ok {
+		return
+	}
+	keys, err := h.linkRepo.CountActiveAPIKeys(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count API keys"})
+		return
+	}
+	reqs, err := h.linkRepo.ListRequests(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list link requests"})
+		return
+	}
+	c.JSON(http.StatusOK, IdentityLinkStatusResponse{
+		UserID:          user.ID,
+		OIDCSub:         user.OIDCSub,
+		ActiveAPIKeys:   keys,
+		PendingRequests: reqs,
+	})
+}
+
+// @Summary      Approve identity link
+// @Description  Links the SSO identity of a pending link request to the user, as if the user had confirmed it. The user's memberships and API keys are kept, and the request's login identity is used for the user's logins from then on. Requires admin scope.
+// @Tags         Users
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                             true  "User ID"
+// @Param        body  body  admin.ApproveIdentityLinkRequest  true  "Pending link request"
+// @Success      200  {object}  admin.IdentityLinkResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden — admin scope required"
+// @Failure      404  {object}  map[string]interface{}  "Link request not found or expired"
+// @Failure      409  {object}  map[string]interface{}  "User or identity already linked"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/users/{id}/link-identity [post]
+// ApproveLink links a pending request's identity on an admin's approval.
+// POST /api/v1/admin/users/:id/link-identity
+func (h *IdentityLinkHandlers) ApproveLink(c *gin.Context) {
+	var body ApproveIdentityLinkRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	req, err := h.linkRepo.GetRequest(c.Request.Context(), c.Param("id"), body.RequestID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up link request"})
+		return
+	}
+	if req == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link request not found or expired"})
+		return
+	}
+	h.link(c, req, "admin")
+}
+
+// @Summary      Unlink identity
+// @Description  Detaches the SSO identity linked to a user. The user's next login with that identity then needs linking again. Refused when the identity was provisioned through SCIM, or when the user would be left without a way to authenticate: at least one unexpired API key must remain. Requires admin scope.
+// @Tags         Users
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "User ID"
+// @Success      200  {object}  admin.IdentityLinkResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden — admin scope required"
+// @Failure      404  {object}  map[string]interface{}  "User not found"
+// @Failure      409  {object}  map[string]interface{}  "No linked identity, SCIM-provisioned identity, or no login method would remain"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/users/{id}/link-identity [delete]
+// Unlink detaches a user's linked identity.
+// DELETE /api/v1/admin/users/:id/link-identity
+func (h *IdentityLinkHandlers) Unlink(c *gin.Context) {
+	ctx := c.Request.Context()
+	user, ok := h.lookupUser(c)
+	if !ok {
+		return
+	}
+	if user.OIDCSub == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User has no linked identity"})
+		return
+	}
+	if strings.HasPrefix(*user.OIDCSub, "scim:") {
+		c.JSON(http.StatusConflict, gin.H{"error": "Identity was provisioned through SCIM; deprovision it there"})
+		return
+	}
+	keys, err := h.linkRepo.CountActiveAPIKeys(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count API keys"})
+		return
+	}
+	if keys == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User would have no login method left; create an API key for the user first"})
+		return
+	}
+
+	sub, err := h.linkRepo.Unlink(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink identity"})
+		return
+	}
+	if sub == "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "User has no linked identity"})
+		return
+	}
+	recordIdentityLinkAudit(ctx, h.auditRepo, c.GetString("user_id"), c.ClientIP(), "user.identity_unlinked", user.ID,
+		map[string]interface{}{"oidc_sub": sub})
+	c.JSON(http.StatusOK, IdentityLinkResponse{Message: "Identity unlinked", UserID: user.ID, OIDCSub: sub})
+}
+
+// link links req's identity and answers the request. approvedBy is "user"
+// or "admin".
+func (h *IdentityLinkHandlers) link(c *gin.Context, req *models.IdentityLinkRequest, approvedBy string) {
+	ctx := c.Request.Context()
+	if err := h.linkRepo.Link(ctx, req); err != nil {
+		if errors.Is(err, repositories.ErrIdentityLinkConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Account already has a linked identity, or the identity is linked to another account"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link identity"})
+		return
+	}
+	recordIdentityLinkAudit(ctx, h.auditRepo, c.GetString("user_id"), c.ClientIP(), "user.identity_linked", req.UserID,
+		map[string]interface{}{
+			"oidc_sub":        req.OIDCSub,
+			"auth_provider":   req.AuthProvider,
+			"auth_issuer":     req.AuthIssuer,
+			"link_request_id": req.ID,
+			"approved_by":     approvedBy,
+		})
+	c.JSON(http.StatusOK, IdentityLinkResponse{Message: "Identity linked", UserID: req.UserID, OIDCSub: req.OIDCSub})
+}
+
+// lookupUser loads the :id user, answering 404 or 500 when it cannot.
+func (h *IdentityLinkHandlers) lookupUser(c *gin.Context) (*models.User, bool) {
+	user, err := h.userRepo.GetUserByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		return nil, false
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+	return user, true
+}
+
+// identityLogin is an SSO login about to be mapped to an account.
+type identityLogin struct {
+	Provider      string // oidc | azuread | saml | ldap
+	Issuer        string
+	Sub           string // in users.oidc_sub form
+	Email         string
+	Name          string
+	EmailVerified bool
+}
+
+// requireIdentityLink decides whether login needs an explicit link: its
+// identity is new, and its verified email belongs to an account without a
+// linked identity. It then stores a link request and returns its token; ""
+// means the login proceeds. The admin named in the setup wizard is linked on
+// first login as before while the account has no API key, since it has no
+// other way in; an account whose identity was unlinked always keeps one. A nil
+// link repository keeps the old behavior of linking such accounts silently.
+func (h *AuthHandlers) requireIdentityLink(ctx context.Context, ip string, login identityLogin) (string, time.Time, error) {
+	if h.linkRepo == nil || login.Email == "" || !login.EmailVerified {
+		return "", time.Time{}, nil
+	}
+	existing, err := h.userRepo.GetUserByOIDCSub(ctx, login.Sub)
+	if err != nil || existing != nil {
+		return "", time.Time{}, err
+	}
+	user, err := h.userRepo.GetUserByEmail(ctx, login.Email)
+	if err != nil || user == nil || user.OIDCSub != nil {
+		return "", time.Time{}, err
+	}
+	if h.oidcConfigRepo != nil {
+		if pending, err := h.oidcConfigRepo.GetPendingAdminEmail(ctx); err == nil && strings.EqualFold(pending, login.Email) {
+			keys, err := h.linkRepo.CountActiveAPIKeys(ctx, user.ID)
+			if err != nil || keys == 0 {
+				return "", time.Time{}, err
+			}
+		}
+	}
+
+	token, err := generateLinkToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req := &models.IdentityLinkRequest{
+		UserID:       user.ID,
+		OIDCSub:      login.Sub,
+		AuthProvider: login.Provider,
+		AuthIssuer:   login.Issuer,
+		Email:        login.Email,
+		Name:         login.Name,
+		TokenHash:    hashLinkToken(token),
+		ExpiresAt:    time.Now().Add(identityLinkTTL),
+	}
+	if err := h.linkRepo.CreateRequest(ctx, req); err != nil {
+		return "", time.Time{}, err
+	}
+	slog.Info("identity link required", "user_id", user.ID, "provider", login.Provider, "link_request_id", req.ID)
+	recordIdentityLinkAudit(ctx, h.auditRepo, "", ip, "user.identity_link_requested", user.ID,
+		map[string]interface{}{
+			"oidc_sub":        login.Sub,
+			"auth_provider":   login.Provider,
+			"auth_issuer":     login.Issuer,
+			"link_request_id": req.ID,
+		})
+	return token, req.ExpiresAt, nil
+}
+
+// redirectIdentityLinkRequired sends the browser of a login that needs
+// linking to the frontend callback page with the link token, or answers 409
+// with it when no frontend URL can be derived.
+func (h *AuthHandlers) redirectIdentityLinkRequired(c *gin.Context, token string, expiresAt time.Time) {
+	frontendBase := deriveFrontendURL(h.cfg)
+	if frontendBase == "" {
+		c.JSON(http.StatusConflict, IdentityLinkRequiredResponse{
+			Error: identityLinkRequiredMessage, LinkRequired: true, LinkToken: token, ExpiresAt: expiresAt,
+		})
+		return
+	}
+	c.Redirect(http.StatusFound, fmt.Sprintf("%s/auth/callback?error=link_required&error_description=%s&link_token=%s",
+		frontendBase, url.QueryEscape(identityLinkRequiredMessage), url.QueryEscape(token)))
+}
+
+// recordIdentityLinkAudit writes a link event of userID to the audit log.
+// actorID is the authenticated caller, if any.
+func recordIdentityLinkAudit(ctx context.Context, auditRepo *repositories.AuditRepository, actorID, ip, action, userID string, metadata map[string]interface{}) {
+	if auditRepo == nil {
+		return
+	}
+	resourceType := "user"
+	entry := &models.AuditLog{
+		Action:       action,
+		ResourceType: &resourceType,
+		ResourceID:   &userID,
+		Metadata:     metadata,
+		IPAddress:    &ip,
+	}
+	if actorID != "" {
+		entry.UserID = &actorID
+	}
+	if err := auditRepo.CreateAuditLog(ctx, entry); err != nil {
+		slog.Error("failed to write audit log for identity link", "error", err, "action", action)
+	}
+}
+
+// generateLinkToken returns a random link token.
+func generateLinkToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashLinkToken returns the form of a link token stored in the database.
+func hashLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
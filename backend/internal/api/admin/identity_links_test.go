@@ -0,0 +1,304 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var identityLinkRequestCols = []string{"id", "user_id", "oidc_sub", "auth_provider", "auth_issuer", "email", "name", "token_hash", "expires_at", "created_at"}
+
+const identityLinkRequestID = "7b0f6c1e-3a52-4d2b-9c1a-2f4e8d6a9b10"
+
+func newIdentityLinkRouter(t *testing.T, callerID string) (*gin.Engine, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	h := NewIdentityLinkHandlers(db, repositories.NewIdentityLinkRepository(db))
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if callerID != "" {
+			c.Set("user_id", callerID)
+		}
+		c.Next()
+	})
+	r.POST("/auth/link-identity", h.ConfirmLink)
+	r.GET("/admin/users/:id/link-identity", h.GetStatus)
+	r.POST("/admin/users/:id/link-identity", h.ApproveLink)
+	r.DELETE("/admin/users/:id/link-identity", h.Unlink)
+	return r, mock
+}
+
+func serveIdentityLink(r *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func linkRequestRow(userID string) *sqlmock.Rows {
+	return sqlmock.NewRows(identityLinkRequestCols).AddRow(identityLinkRequestID, userID, "sub-1", "oidc",
+		"https://idp.example.com", "alice@example.com", "Alice", hashLinkToken("tok"), time.Now().Add(time.Hour), time.Now())
+}
+
+// ---------------------------------------------------------------------------
+// ConfirmLink
+// ---------------------------------------------------------------------------
+
+func TestConfirmLink_Unauthenticated(t *testing.T) {
+	r, _ := newIdentityLinkRouter(t, "")
+	w := serveIdentityLink(r, http.MethodPost, "/auth/link-identity", ConfirmIdentityLinkRequest{LinkToken: "tok"})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+}
+
+func TestConfirmLink_Success(t *testing.T) {
+	r, mock := newIdentityLinkRouter(t, "user-1")
+	mock.ExpectQuery("FROM identity_link_requests.*token_hash").
+		WithArgs(hashLinkToken("tok")).
+		WillReturnRows(linkRequestRow("user-1"))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET oidc_sub").
+		WithArgs("user-1", "sub-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM identity_link_requests").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := serveIdentityLink(r, http.MethodPost, "/auth/link-identity", ConfirmIdentityLinkRequest{LinkToken: "tok"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp IdentityLinkResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.UserID != "user-1" || resp.OIDCSub != "sub-1" {
+		t.Fatalf("response = %+v, %v", resp, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConfirmLink_DifferentAccount(t *testing.T) {
+	r, mock := newIdentityLinkRouter(t, "user-2")
+	mock.ExpectQuery("FROM identity_link_requests").WillReturnRows(linkRequestRow("user-1"))
+
+	w := serveIdentityLink(r, http.MethodPost, "/auth/link-identity", ConfirmIdentityLinkRequest{LinkToken: "tok"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", w.Code)
+	}
+}
+
+func TestConfirmLink_UnknownToken(t *testing.T) {
+	r, mock := newIdentityLinkRouter(t, "user-1")
+	mock.ExpectQuery("FROM identity_link_requests").WillReturnRows(sqlmock.NewRows(identityLinkRequestCols))
+
+	w := serveIdentityLink(r, http.MethodPost, "/auth/link-identity", ConfirmIdentityLinkRequest{LinkToken: "tok"})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestConfirmLink_AlreadyLinked(t *testing.T) {
+	r, mock := newIdentityLinkRouter(t, "user-1")
+	mock.ExpectQuery("FROM identity_link_requests").WillReturnRows(linkRequestRow("user-1"))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET oidc_sub").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	w := serveIdentityLink(r, http.MethodPost, "/auth/link-identity", ConfirmIdentityLinkRequest{LinkToken: "tok"})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", w.Code)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ApproveLink
+// ---------------------------------------------------------------------------
+
+func TestApproveLink_Success(t *testing.T) {
+	r, mock := newIdentityLinkRouter(t, "admin-1")
+	mock.ExpectQuery("FROM identity_link_requests.*id = \\$1 AND user_id = \\$2").
+		WithArgs(identityLinkRequestID, "user-1").
+		WillReturnRows(linkRequestRow("user-1"))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET oidc_sub").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM identity_link_requests").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := serveIdentityLink(r, http.MethodPost, "/admin/users/user-1/link-identity",
+		ApproveIdentityLinkRequest{RequestID: identityLinkRequestID})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApproveLink_InvalidRequestID(t *testing.T) {
+	r, _ := newIdentityLinkRouter(t, "admin-1")
+	w := serveIdentityLink(r, http.MethodPost, "/admin/users/user-1/link-identity",
+		ApproveIdentityLinkRequest{RequestID: "not-a-uuid"})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Unlink
+// ---------------------------------------------------------------------------
+
+func TestUnlink_NoLoginMethodLeft(t *testing.T) {
+	r, mock := newIdentityLinkRouter(t, "admin-1")
+	sub := "sub-1"
+	mock.ExpectQuery("SELECT.*FROM users.*WHERE id").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(authUserCols).AddRow("user-1", "alice@example.com", "Alice", &sub, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM api_keys").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	w := serveIdentityLink(r, http.MethodDelete, "/admin/users/user-1/link-identity", nil)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", w.Code)
+	}
+}
+
+func TestUnlink_SCIMIdentity(t *testing.T) {
+	r, mock := newIdentityLinkRouter(t, "admin-1")
+	sub := "scim:ext-1"
+	mock.ExpectQuery("SELECT.*FROM users.*WHERE id").
+		WillReturnRows(sqlmock.NewRows(authUserCols).AddRow("user-1", "alice@example.com", "Alice", &sub, time.Now(), time.Now()))
+
+	w := serveIdentityLink(r, http.MethodDelete, "/admin/users/user-1/link-identity", nil)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", w.Code)
+	}
+}
+
+func TestUnlink_Success(t *testing.T) {
+	r, mock := newIdentityLinkRouter(t, "admin-1")
+	sub := "sub-1"
+	mock.ExpectQuery("SELECT.*FROM users.*WHERE id").
+		WillReturnRows(sqlmock.NewRows(authUserCols).AddRow("user-1", "alice@example.com", "Alice", &sub, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM api_keys").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("UPDATE users u SET oidc_sub = NULL").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"oidc_sub"}).AddRow("sub-1"))
+	mock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := serveIdentityLink(r, http.MethodDelete, "/admin/users/user-1/link-identity", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// requireIdentityLink
+// ---------------------------------------------------------------------------
+
+func newLinkingAuthHandlers(t *testing.T) (*AuthHandlers, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	h, err := NewAuthHandlers(&config.Config{}, db,
+		repositories.NewOIDCConfigRepository(sqlx.NewDb(db, "sqlmock")), nil, auth.NewMemoryStateStore(time.Hour),
+		WithIdentityLinks(repositories.NewIdentityLinkRepository(db), repositories.NewAuditRepository(db)))
+	if err != nil {
+		t.Fatalf("NewAuthHandlers: %v", err)
+	}
+	return h, mock
+}
+
+var aliceLogin = identityLogin{Provider: "oidc", Sub: "sub-1", Email: "alice@example.com", Name: "Alice", EmailVerified: true}
+
+func TestRequireIdentityLink_ExistingAccount(t *testing.T) {
+	h, mock := newLinkingAuthHandlers(t)
+	mock.ExpectQuery("SELECT.*FROM users.*WHERE oidc_sub").
+		WithArgs("sub-1").
+		WillReturnRows(sqlmock.NewRows(authUserCols))
+	mock.ExpectQuery("SELECT.*FROM users.*WHERE email").
+		WithArgs("alice@example.com").
+		WillReturnRows(sqlmock.NewRows(authUserCols).AddRow("user-1", "alice@example.com", "Alice", nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT pending_admin_email").
+		WillReturnRows(sqlmock.NewRows([]string{"pending_admin_email"}).AddRow(nil))
+	mock.ExpectExec("DELETE FROM identity_link_requests WHERE expires_at").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO identity_link_requests").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(identityLinkRequestID, time.Now()))
+	mock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	token, expiresAt, err := h.requireIdentityLink(t.Context(), "10.0.0.1", aliceLogin)
+	if err != nil || token == "" || expiresAt.IsZero() {
+		t.Fatalf("requireIdentityLink = %q, %v, %v; want a link token", token, expiresAt, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRequireIdentityLink_KnownIdentity(t *testing.T) {
+	h, mock := newLinkingAuthHandlers(t)
+	mock.ExpectQuery("SELECT.*FROM users.*WHERE oidc_sub").
+		WillReturnRows(sqlmock.NewRows(authUserCols).AddRow("user-1", "alice@example.com", "Alice", "sub-1", time.Now(), time.Now()))
+
+	if token, _, err := h.requireIdentityLink(t.Context(), "10.0.0.1", aliceLogin); err != nil || token != "" {
+		t.Fatalf("requireIdentityLink = %q, %v; want no link required", token, err)
+	}
+}
+
+func TestRequireIdentityLink_UnverifiedEmail(t *testing.T) {
+	h, _ := newLinkingAuthHandlers(t)
+	login := aliceLogin
+	login.EmailVerified = false
+	// No query expected: unverified emails never match an account.
+	if token, _, err := h.requireIdentityLink(t.Context(), "10.0.0.1", login); err != nil || token != "" {
+		t.Fatalf("requireIdentityLink = %q, %v; want no link required", token, err)
+	}
+}
+
+func TestRequireIdentityLink_PendingSetupAdmin(t *testing.T) {
+	h, mock := newLinkingAuthHandlers(t)
+	mock.ExpectQuery("SELECT.*FROM users.*WHERE oidc_sub").WillReturnRows(sqlmock.NewRows(authUserCols))
+	mock.ExpectQuery("SELECT.*FROM users.*WHERE email").
+		WillReturnRows(sqlmock.NewRows(authUserCols).AddRow("user-1", "alice@example.com", "Alice", nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT pending_admin_email").
+		WillReturnRows(sqlmock.NewRows([]string{"pending_admin_email"}).AddRow("Alice@example.com"))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM api_keys").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	if token, _, err := h.requireIdentityLink(t.Context(), "10.0.0.1", aliceLogin); err != nil || token != "" {
+		t.Fatalf("requireIdentityLink = %q, %v; want the setup admin linked on first login", token, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
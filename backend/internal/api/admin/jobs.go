@@ -0,0 +1,49 @@
+// jobs.go implements the admin read-only endpoint that reports the state of
+// the background jobs running on the shared scheduler: whether each loop is
+// active, whether a run is in flight, when it last ran and how long it took,
+// the last panic (if any), and when it runs next.
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/jobs"
+)
+
+// jobStatusSource is the subset of jobs.Registry used by this handler.
+type jobStatusSource interface {
+	Status() []jobs.TaskStatus
+}
+
+// JobsStatusResponse is returned by GET /api/v1/admin/jobs.
+type JobsStatusResponse struct {
+	Jobs []jobs.TaskStatus `json:"jobs"`
+}
+
+// JobsHandler exposes the GET /api/v1/admin/jobs endpoint.
+type JobsHandler struct {
+	source jobStatusSource
+}
+
+// NewJobsHandler constructs a JobsHandler.
+func NewJobsHandler(source jobStatusSource) *JobsHandler {
+	return &JobsHandler{source: source}
+}
+
+// @Summary      List background job status
+// @Description  Returns scheduling state for every background job running on the shared scheduler: whether its loop is active, whether a run is in progress, run and panic counts, the last run's start, finish, duration and error, and the next scheduled run. Jobs that are disabled by configuration never start a loop and are omitted.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  admin.JobsStatusResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Missing required scope"
+// @Router       /api/v1/admin/jobs [get]
+func (h *JobsHandler) ListJobs(c *gin.Context) {
+	statuses := h.source.Status()
+	if statuses == nil {
+		statuses = []jobs.TaskStatus{}
+	}
+	c.JSON(http.StatusOK, JobsStatusResponse{Jobs: statuses})
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/jobs"
+)
+
+type stubJobStatusSource struct {
+	statuses []jobs.TaskStatus
+}
+
+func (s *stubJobStatusSource) Status() []jobs.TaskStatus { return s.statuses }
+
+func doListJobs(t *testing.T, source jobStatusSource) (*httptest.ResponseRecorder, JobsStatusResponse) {
+	t.Helper()
+	r := gin.New()
+	r.GET("/api/v1/admin/jobs", NewJobsHandler(source).ListJobs)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/jobs", nil))
+	var resp JobsStatusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v (body %s)", err, w.Body.String())
+	}
+	return w, resp
+}
+
+func TestListJobs_ReturnsStatuses(t *testing.T) {
+	source := &stubJobStatusSource{statuses: []jobs.TaskStatus{
+		{Name: "audit-cleanup", Interval: "24h0m0s", Active: true, RunCount: 3},
+		{Name: "mirror-sync", Interval: "10m0s", Active: true, Running: true, PanicCount: 1, LastError: "panic: boom"},
+	}}
+	w, resp := doListJobs(t, source)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if len(resp.Jobs) != 2 || resp.Jobs[1].Name != "mirror-sync" || resp.Jobs[1].LastError != "panic: boom" {
+		t.Errorf("jobs = %+v", resp.Jobs)
+	}
+}
+
+func TestListJobs_EmptyIsArray(t *testing.T) {
+	w, _ := doListJobs(t, &stubJobStatusSource{})
+	if w.Body.String() != `{"jobs":[]}` {
+		t.Errorf("body = %s, want {\"jobs\":[]}", w.Body.String())
+	}
+}
@@ -0,0 +1,113 @@
+// maintenance.go implements the admin toggle for read-only maintenance mode.
+// While it is on the API refuses mutating requests with 503 and the
+// operator's message (see middleware.MaintenanceMiddleware); reads and the
+// Terraform protocol endpoints keep working. The mode is stored in
+// system_settings, so it survives restarts.
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// maxMaintenanceMessageLength caps the message echoed on every refused write.
+const maxMaintenanceMessageLength = 1024
+
+// MaintenanceHandlers serves the maintenance mode admin endpoints.
+type MaintenanceHandlers struct {
+	repo  *repositories.MaintenanceRepository
+	state *middleware.MaintenanceState
+}
+
+// NewMaintenanceHandlers constructs a MaintenanceHandlers. state is the cache
+// consulted by the maintenance middleware; it is invalidated on every write
+// and may be nil.
+func NewMaintenanceHandlers(repo *repositories.MaintenanceRepository, state *middleware.MaintenanceState) *MaintenanceHandlers {
+	return &MaintenanceHandlers{repo: repo, state: state}
+}
+
+// MaintenanceRequest is the body of POST /admin/maintenance.
+type MaintenanceRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+	// Message is returned to refused clients; a default is used when empty.
+	Message string `json:"message"`
+	// ETA is when writes are expected to be accepted again (RFC 3339). It
+	// sets the Retry-After of refused requests and must be in the future.
+	ETA *time.Time `json:"eta"`
+}
+
+// @Summary      Get maintenance mode
+// @Description  Returns whether the registry is in read-only maintenance mode, with its message and ETA.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  models.MaintenanceMode
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/maintenance [get]
+// GetMaintenance returns the maintenance mode state.
+// GET /api/v1/admin/maintenance
+func (h *MaintenanceHandlers) GetMaintenance(c *gin.Context) {
+	mode, err := h.repo.Get(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get maintenance mode"})
+		return
+	}
+	c.JSON(http.StatusOK, mode)
+}
+
+// @Summary      Set maintenance mode
+// @Description  Turns read-only maintenance mode on or off. While it is on, mutating requests outside authentication, this endpoint and storage migrations are refused with 503, a Retry-After header derived from the ETA, and the message; reads and Terraform protocol downloads keep working. The mode survives restarts and reaches every replica within a few seconds.
+// @Tags         System
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  MaintenanceRequest  true  "Maintenance mode"
+// @Success      200  {object}  models.MaintenanceMode
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/maintenance [post]
+// SetMaintenance turns maintenance mode on or off.
+// POST /api/v1/admin/maintenance
+func (h *MaintenanceHandlers) SetMaintenance(c *gin.Context) {
+	var req MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	mode := &models.MaintenanceMode{Enabled: *req.Enabled}
+	if mode.Enabled {
+		mode.Message = strings.TrimSpace(req.Message)
+		if len(mode.Message) > maxMaintenanceMessageLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message: must be at most 1024 characters"})
+			return
+		}
+		now := time.Now()
+		if req.ETA != nil && !req.ETA.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid eta: must be in the future"})
+			return
+		}
+		mode.ETA = req.ETA
+		mode.EnabledAt = &now
+		if uid := c.GetString("user_id"); uid != "" {
+			mode.EnabledBy = &uid
+		}
+	}
+	if err := h.repo.Set(c.Request.Context(), mode); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set maintenance mode"})
+		return
+	}
+	if h.state != nil {
+		h.state.Invalidate()
+	}
+	c.JSON(http.StatusOK, mode)
+}
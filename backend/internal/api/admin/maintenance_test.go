@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+var maintenanceCols = []string{
+	"maintenance_enabled", "maintenance_message", "maintenance_eta",
+	"maintenance_enabled_by", "maintenance_enabled_at",
+}
+
+func newMaintenanceRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine, *middleware.MaintenanceState) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := repositories.NewMaintenanceRepository(db)
+	state := middleware.NewMaintenanceState(repo, time.Hour)
+	h := NewMaintenanceHandlers(repo, state)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "admin-1") })
+	r.GET("/admin/maintenance", h.GetMaintenance)
+	r.POST("/admin/maintenance", h.SetMaintenance)
+	return mock, r, state
+}
+
+func doMaintenanceReq(r *gin.Engine, method, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/admin/maintenance", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSetMaintenance_EnableInvalidatesCache(t *testing.T) {
+	mock, r, state := newMaintenanceRouter(t)
+
+	// Prime the cache with maintenance off.
+	mock.ExpectQuery("SELECT.*FROM system_settings").
+		WillReturnRows(sqlmock.NewRows(maintenanceCols).AddRow(false, "", nil, nil, nil))
+	if state.Current(context.Background()).Enabled {
+		t.Fatal("maintenance should start off")
+	}
+
+	eta := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	mock.ExpectExec("UPDATE system_settings SET").
+		WithArgs(true, "migrating storage", sqlmock.AnyArg(), "admin-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	w := doMaintenanceReq(r, http.MethodPost, `{"enabled":true,"message":"  migrating storage ","eta":"`+eta.Format(time.RFC3339)+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var got models.MaintenanceMode
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !got.Enabled || got.Message != "migrating storage" || got.ETA == nil || !got.ETA.Equal(eta) || got.EnabledBy == nil {
+		t.Errorf("response = %+v", got)
+	}
+
+	// The write invalidated the cache, so the next check reloads.
+	mock.ExpectQuery("SELECT.*FROM system_settings").
+		WillReturnRows(sqlmock.NewRows(maintenanceCols).AddRow(true, "migrating storage", eta, "admin-1", time.Now()))
+	if !state.Current(context.Background()).Enabled {
+		t.Error("cache was not invalidated")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSetMaintenance_DisableClearsDetails(t *testing.T) {
+	mock, r, _ := newMaintenanceRouter(t)
+	mock.ExpectExec("UPDATE system_settings SET").
+		WithArgs(false, "", nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doMaintenanceReq(r, http.MethodPost, `{"enabled":false,"message":"ignored"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSetMaintenance_Validation(t *testing.T) {
+	_, r, _ := newMaintenanceRouter(t)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	for name, body := range map[string]string{
+		"missing enabled": `{"message":"x"}`,
+		"past eta":        `{"enabled":true,"eta":"` + past + `"}`,
+		"long message":    `{"enabled":true,"message":"` + strings.Repeat("x", 1025) + `"}`,
+	} {
+		if w := doMaintenanceReq(r, http.MethodPost, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+}
+
+func TestGetMaintenance(t *testing.T) {
+	mock, r, _ := newMaintenanceRouter(t)
+	mock.ExpectQuery("SELECT.*FROM system_settings").
+		WillReturnRows(sqlmock.NewRows(maintenanceCols).AddRow(true, "migrating storage", nil, "admin-1", time.Now()))
+
+	w := doMaintenanceReq(r, http.MethodGet, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"enabled":true`) {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
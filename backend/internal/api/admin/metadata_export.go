@@ -0,0 +1,89 @@
+// metadata_export.go implements the NDJSON export of module, provider and
+// Terraform binary version metadata that external portals index the registry
+// with.
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
+)
+
+// metadataExportSettle holds back the most recent changes from an export.
+// updated_at is stamped when a transaction starts, so a change still being
+// committed when the export runs can carry a time before its cursor; leaving
+// the last minute to the next export keeps it from being skipped.
+const metadataExportSettle = time.Minute
+
+// metadataExportError is the last line of an export that failed part way.
+// The cursor header has already been sent, so it must not be used.
+type metadataExportError struct {
+	SchemaVersion int    `json:"schema_version"`
+	Kind          string `json:"kind"`
+	Error         string `json:"error"`
+}
+
+// @Summary      Export version metadata
+// @Description  Streams the metadata of every module, provider and Terraform binary version as newline-delimited JSON (NDJSON), one record per version, ordered by updated_at. Each record carries schema_version, kind (module_version, provider_version or terraform_version), identifiers, description, publish info, checksums and deprecation state. since limits the export to versions changed at or after that time; the X-Export-Cursor response header is the since to pass next time to receive only later changes. Changes from the last minute are left to the next export. An export that fails part way ends with a record of kind error, and its cursor must not be used. Every version is exported regardless of visibility or approval state. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Produce      application/x-ndjson
+// @Param        since  query  string  false  "RFC3339 timestamp; export only versions changed at or after it (default: all)"
+// @Success      200  {string}  string  "NDJSON stream of version metadata records"
+// @Header       200  {string}  X-Export-Cursor  "RFC3339 timestamp to pass as since on the next export"
+// @Failure      400  {object}  map[string]interface{}  "Invalid since parameter"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden - requires admin scope"
+// @Router       /api/v1/admin/export/metadata [get]
+// ExportVersionMetadata streams version metadata for external indexing
+// GET /api/v1/admin/export/metadata
+func ExportVersionMetadata(repo *repositories.MetadataExportRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var since time.Time
+		if v := c.Query("since"); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC3339 timestamp (e.g. 2006-01-02T15:04:05Z)"})
+				return
+			}
+			since = t.UTC()
+		}
+
+		now := time.Now().UTC()
+		until := now.Add(-metadataExportSettle).Truncate(time.Microsecond)
+		if until.Before(since) {
+			until = since
+		}
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", "attachment; filename=version-metadata-"+now.Format("2006-01-02")+".ndjson")
+		c.Header("X-Export-Cursor", until.Format(time.RFC3339Nano))
+		c.Status(http.StatusOK)
+
+		op := operations.FromContext(c.Request.Context())
+		enc := json.NewEncoder(c.Writer)
+		err := repo.EachVersion(c.Request.Context(), since, until, func(rec *models.MetadataExportRecord) error {
+			if err := enc.Encode(rec); err != nil { // writes JSON + "\n"
+				return err
+			}
+			c.Writer.Flush()
+			op.AddDone(1)
+			return nil
+		})
+		if err != nil {
+			// Headers are already sent, so the failure is reported in the stream.
+			slog.Error("version metadata export failed", "error", err)
+			_ = enc.Encode(metadataExportError{
+				SchemaVersion: models.MetadataExportSchemaVersion,
+				Kind:          "error",
+				Error:         "export interrupted; retry with the same since",
+			})
+		}
+	}
+}
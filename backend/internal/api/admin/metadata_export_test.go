@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var metadataExportCols = []string{
+	"kind", "id", "parent_id", "namespace", "name", "system", "version", "description",
+	"published_at", "published_by", "published_by_name", "checksum", "platforms",
+	"deprecated", "deprecated_at", "deprecation_message", "updated_at",
+}
+
+func newMetadataExportRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.GET("/export/metadata", ExportVersionMetadata(repositories.NewMetadataExportRepository(db)))
+	return mock, r
+}
+
+// ndjsonLines decodes every line of an NDJSON body.
+func ndjsonLines(t *testing.T, body string) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		var m map[string]interface{}
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", sc.Text(), err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestExportVersionMetadata_InvalidSince(t *testing.T) {
+	_, r := newMetadataExportRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/export/metadata?since=yesterday", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestExportVersionMetadata_StreamsRecordsWithCursor(t *testing.T) {
+	mock, r := newMetadataExportRouter(t)
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+	mock.ExpectQuery("UNION ALL").
+		WithArgs(since, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(metadataExportCols).
+			AddRow("module_version", "mv-1", "mod-1", "acme", "vpc", "aws", "1.0.0", nil,
+				now, nil, nil, "abc123", nil, false, nil, nil, now))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/export/metadata?since=2026-01-01T00:00:00Z", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	cursor, err := time.Parse(time.RFC3339Nano, w.Header().Get("X-Export-Cursor"))
+	if err != nil || !cursor.After(since) || cursor.After(time.Now().Add(-metadataExportSettle)) {
+		t.Errorf("X-Export-Cursor = %q (%v)", w.Header().Get("X-Export-Cursor"), err)
+	}
+	lines := ndjsonLines(t, w.Body.String())
+	if len(lines) != 1 || lines[0]["kind"] != "module_version" || lines[0]["schema_version"] != float64(1) || lines[0]["checksum"] != "abc123" {
+		t.Errorf("records = %v", lines)
+	}
+}
+
+func TestExportVersionMetadata_FailureEndsWithErrorRecord(t *testing.T) {
+	mock, r := newMetadataExportRouter(t)
+	mock.ExpectQuery("UNION ALL").WillReturnError(errDB)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/export/metadata", nil))
+	lines := ndjsonLines(t, w.Body.String())
+	if len(lines) != 1 || lines[0]["kind"] != "error" {
+		t.Errorf("records = %v, want one error record", lines)
+	}
+}
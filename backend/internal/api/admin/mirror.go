@@ -1,18 +1,23 @@
-// mirror.go implements handlers for provider mirror CRUD operations, manual sync triggering, and sync history retrieval.
+// mirror.go implements handlers for provider mirror CRUD operations, manual sync triggering, re-signing, and sync history retrieval.
 package admin
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
-	"strconv"
 	"time"
 
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
 	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -23,16 +28,39 @@ type MirrorSyncJobInterface interface {
 	TriggerManualSync(ctx context.Context, mirrorID uuid.UUID) error
 }
 
+// MirrorResignerInterface re-signs a mirror's synced provider versions with the
+// registry's signing key. *services.ProviderResigner satisfies it.
+type MirrorResignerInterface interface {
+	ResignMirror(ctx context.Context, mirrorID uuid.UUID) (*services.ResignSummary, error)
+}
+
+// MirrorProviderRemoverInterface deletes a provider a mirror created, with its
+// archives. *ProviderAdminHandlers satisfies it.
+type MirrorProviderRemoverInterface interface {
+	RemoveMirroredProvider(ctx context.Context, providerID string) error
+}
+
+// errPrivateMirrorWithoutOrg rejects a private mirror with no organization,
+// whose providers no one could be served.
+const errPrivateMirrorWithoutOrg = "A private mirror must belong to an organization"
+
 // MirrorHandler handles mirror configuration endpoints
 type MirrorHandler struct {
 	mirrorRepo   *repositories.MirrorRepository
 	orgRepo      *repositories.OrganizationRepository
 	providerRepo *repositories.ProviderRepository
 	syncJob      MirrorSyncJobInterface
+	resigner     MirrorResignerInterface // nil unless mirror_signing is enabled
+	// providerRemover serves DeleteMirrorConfig's delete_providers option;
+	// nil rejects it.
+	providerRemover MirrorProviderRemoverInterface
 	// egress is consulted (via mirror.ValidateRegistryURL) on every create/update
 	// so a non-admin "devops"-scoped caller cannot point a mirror at a private
 	// or cloud-metadata address; nil enforces the strict default deny-list.
 	egress *httpsafe.Guard
+	// leases backs the sync_lease status field and DELETE .../lock; nil
+	// leaves the field out and answers the endpoint with 503.
+	leases *repositories.MirrorSyncLeaseRepository
 }
 
 // NewMirrorHandler creates a new mirror handler
@@ -49,6 +77,24 @@ func (h *MirrorHandler) SetSyncJob(syncJob MirrorSyncJobInterface) {
 	h.syncJob = syncJob
 }
 
+// SetResigner enables POST /admin/mirrors/:id/resign. Without it the endpoint
+// answers 503.
+func (h *MirrorHandler) SetResigner(r MirrorResignerInterface) {
+	h.resigner = r
+}
+
+// SetProviderRemover sets how providers are deleted with their mirror
+// configuration when delete_providers=true is passed.
+func (h *MirrorHandler) SetProviderRemover(r MirrorProviderRemoverInterface) {
+	h.providerRemover = r
+}
+
+// SetSyncLeases enables sync lease reporting in GetMirrorStatus and
+// DELETE /admin/mirrors/:id/lock.
+func (h *MirrorHandler) SetSyncLeases(leases *repositories.MirrorSyncLeaseRepository) {
+	h.leases = leases
+}
+
 // SetEgressGuard installs the operator-configured egress guard
 // (security.egress.allowlist) consulted when validating upstream_registry_url
 // on create/update. Returns the handler for chaining.
@@ -134,6 +180,12 @@ func (h *MirrorHandler) CreateMirrorConfig(c *gin.Context) {
 		}
 	}
 
+	private := req.Private != nil && *req.Private
+	if private && orgID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errPrivateMirrorWithoutOrg})
+		return
+	}
+
 	// Convert filter arrays to JSON strings
 	var namespaceFilter, providerFilter, platformFilter *string
 	if len(req.NamespaceFilter) > 0 {
@@ -174,17 +226,35 @@ func (h *MirrorHandler) CreateMirrorConfig(c *gin.Context) {
 		pullThroughTTL = *req.PullThroughCacheTTLHours
 	}
 
+	docsPassthrough := req.DocsPassthroughEnabled != nil && *req.DocsPassthroughEnabled
+
+	docsTTL := 24
+	if req.DocsCacheTTLHours != nil {
+		docsTTL = *req.DocsCacheTTLHours
+	}
+
 	requiresApproval := false
 	if req.RequiresApproval != nil {
 		requiresApproval = *req.RequiresApproval
 	}
 
+	historyCount := models.DefaultSyncHistoryRetentionCount
+	if req.HistoryRetentionCount != nil {
+		historyCount = *req.HistoryRetentionCount
+	}
+
+	historyDays := models.DefaultSyncHistoryRetentionDays
+	if req.HistoryRetentionDays != nil {
+		historyDays = *req.HistoryRetentionDays
+	}
+
 	config := &models.MirrorConfiguration{
 		ID:                       uuid.New(),
 		Name:                     req.Name,
 		Description:              req.Description,
 		UpstreamRegistryURL:      req.UpstreamRegistryURL,
 		OrganizationID:           orgID,
+		Private:                  private,
 		NamespaceFilter:          namespaceFilter,
 		ProviderFilter:           providerFilter,
 		VersionFilter:            req.VersionFilter,
@@ -195,6 +265,11 @@ func (h *MirrorHandler) CreateMirrorConfig(c *gin.Context) {
 		AutoApproveRules:         req.AutoApproveRules,
 		PullThroughEnabled:       pullThroughEnabled,
 		PullThroughCacheTTLHours: pullThroughTTL,
+		DocsPassthroughEnabled:   docsPassthrough,
+		DocsCacheTTLHours:        docsTTL,
+		HistoryRetentionCount:    historyCount,
+		HistoryRetentionDays:     historyDays,
+		MaxStorageBytes:          storageLimit(req.MaxStorageBytes),
 		CreatedAt:                time.Now(),
 		UpdatedAt:                time.Now(),
 		CreatedBy:                createdBy,
@@ -208,12 +283,28 @@ func (h *MirrorHandler) CreateMirrorConfig(c *gin.Context) {
 	c.JSON(http.StatusCreated, config)
 }
 
+// storageLimit maps a requested max_storage_bytes onto the stored limit: 0
+// removes the limit.
+func storageLimit(req *int64) *int64 {
+	if req == nil || *req == 0 {
+		return nil
+	}
+	return req
+}
+
+// mirrorConfigLimits are the page size default and cap of the mirror
+// configuration list, which is paged in memory: organizations have few
+// mirror configurations.
+var mirrorConfigLimits = pagination.Limits{Default: 100, Max: 500}
+
 // @Summary      List mirror configurations
 // @Description  List all provider mirror configurations, optionally filtered to enabled only. Requires admin scope.
 // @Tags         Mirror
 // @Security     Bearer
 // @Produce      json
 // @Param        enabled  query  bool  false  "Filter to enabled mirrors only"
+// @Param        limit    query  int   false  "Items per page, max 500 (default 100); alias per_page"
+// @Param        offset   query  int   false  "Items to skip (default 0); alias page (1-based)"
 // @Success      200  {object}  admin.ListMirrorConfigsResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
@@ -223,13 +314,18 @@ func (h *MirrorHandler) CreateMirrorConfig(c *gin.Context) {
 func (h *MirrorHandler) ListMirrorConfigs(c *gin.Context) {
 	enabledOnly := c.Query("enabled") == "true"
 
+	page := pagination.Parse(c, mirrorConfigLimits)
+
 	configs, err := h.mirrorRepo.List(c.Request.Context(), enabledOnly)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list mirror configurations: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"mirrors": configs})
+	c.JSON(http.StatusOK, gin.H{
+		"mirrors": pagination.Window(page, configs),
+		"meta":    page.Meta(int64(len(configs))),
+	})
 }
 
 // @Summary      Get mirror configuration
@@ -402,6 +498,14 @@ func (h *MirrorHandler) UpdateMirrorConfig(c *gin.Context) {
 		}
 	}
 
+	if req.Private != nil {
+		config.Private = *req.Private
+	}
+	if config.Private && config.OrganizationID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errPrivateMirrorWithoutOrg})
+		return
+	}
+
 	if req.Enabled != nil {
 		config.Enabled = *req.Enabled
 	}
@@ -418,6 +522,26 @@ func (h *MirrorHandler) UpdateMirrorConfig(c *gin.Context) {
 		config.PullThroughCacheTTLHours = *req.PullThroughCacheTTLHours
 	}
 
+	if req.DocsPassthroughEnabled != nil {
+		config.DocsPassthroughEnabled = *req.DocsPassthroughEnabled
+	}
+
+	if req.DocsCacheTTLHours != nil {
+		config.DocsCacheTTLHours = *req.DocsCacheTTLHours
+	}
+
+	if req.HistoryRetentionCount != nil {
+		config.HistoryRetentionCount = *req.HistoryRetentionCount
+	}
+
+	if req.HistoryRetentionDays != nil {
+		config.HistoryRetentionDays = *req.HistoryRetentionDays
+	}
+
+	if req.MaxStorageBytes != nil {
+		config.MaxStorageBytes = storageLimit(req.MaxStorageBytes)
+	}
+
 	if req.RequiresApproval != nil {
 		config.RequiresApproval = *req.RequiresApproval
 	}
@@ -435,15 +559,18 @@ func (h *MirrorHandler) UpdateMirrorConfig(c *gin.Context) {
 }
 
 // @Summary      Delete mirror configuration
-// @Description  Delete a provider mirror configuration and its sync history. Requires admin scope.
+// @Description  Delete a provider mirror configuration and its sync history. The providers it synced are kept as ordinary providers and listed in providers_kept; with delete_providers=true those it exclusively created (every version synced by this mirror) are deleted with their archives and listed in providers_deleted. Requires admin scope.
 // @Tags         Mirror
 // @Security     Bearer
 // @Produce      json
-// @Param        id  path  string  true  "Mirror configuration ID (UUID)"
+// @Param        id                path   string  true   "Mirror configuration ID (UUID)"
+// @Param        delete_providers  query  bool    false  "Also delete the providers this mirror exclusively created"
 // @Success      200  {object}  admin.MessageResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid mirror ID"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "A provider version is retained by artifact immutability"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      503  {object}  map[string]interface{}  "Provider deletion not configured"
 // @Router       /api/v1/admin/mirrors/{id} [delete]
 // DeleteMirrorConfig deletes a mirror configuration
 // DELETE /api/v1/admin/mirrors/:id
@@ -455,12 +582,54 @@ func (h *MirrorHandler) DeleteMirrorConfig(c *gin.Context) {
 		return
 	}
 
+	deleteProviders := c.Query("delete_providers") == "true"
+	if deleteProviders && h.providerRemover == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Provider deletion not configured"})
+		return
+	}
+
+	providers, err := h.mirrorRepo.ListProvidersForMirror(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list mirrored providers"})
+		return
+	}
+
+	// Providers the mirror created and nothing else published to go with it
+	// on request; the rest are kept as ordinary providers.
+	var deleted, kept []string
+	for _, p := range providers {
+		name := p.Namespace + "/" + p.Type
+		if !deleteProviders || !p.Exclusive {
+			kept = append(kept, name)
+			continue
+		}
+		if err := h.providerRemover.RemoveMirroredProvider(c.Request.Context(), p.ProviderID.String()); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, storage.ErrArtifactImmutable) {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, gin.H{
+				"error":             "Failed to delete provider " + name + ": " + err.Error(),
+				"providers_deleted": deleted,
+			})
+			return
+		}
+		deleted = append(deleted, name)
+	}
+
 	if err := h.mirrorRepo.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete mirror configuration: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Mirror configuration deleted successfully"})
+	resp := gin.H{"message": "Mirror configuration deleted successfully"}
+	if len(deleted) > 0 {
+		resp["providers_deleted"] = deleted
+	}
+	if len(kept) > 0 {
+		resp["providers_kept"] = kept
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // @Summary      Trigger mirror sync
@@ -509,7 +678,7 @@ func (h *MirrorHandler) TriggerSync(c *gin.Context) {
 	// The job will handle creating the sync history record and checking for active syncs
 	if h.syncJob != nil {
 		log.Printf("API: Triggering manual sync for mirror %s (ID: %s)", config.Name, id) // #nosec G706 -- logged value is application-internal (config string, integer, or application-constructed path); not raw user-controlled request input
-		if err := h.syncJob.TriggerManualSync(c.Request.Context(), id); err != nil {
+		if err := h.syncJob.TriggerManualSync(operations.WithActor(c.Request.Context(), c.GetString("user_id")), id); err != nil {
 			if err.Error() == "sync already in progress for this mirror" {
 				c.JSON(http.StatusAccepted, gin.H{"message": "Sync already in progress"})
 				return
@@ -527,8 +696,47 @@ func (h *MirrorHandler) TriggerSync(c *gin.Context) {
 	})
 }
 
+// @Summary      Re-sign mirrored provider versions
+// @Description  Re-signs every GPG-verified provider version synced by the mirror with the registry's signing key (mirror_signing), replacing any earlier registry signature. Use it to backfill versions synced before re-signing was enabled or after rotating the key. Versions whose upstream signature was never verified are skipped. Runs synchronously. Requires admin scope.
+// @Tags         Mirror
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Mirror configuration ID (UUID)"
+// @Success      200  {object}  services.ResignSummary
+// @Failure      400  {object}  map[string]interface{}  "Invalid mirror ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Mirror configuration not found"
+// @Failure      503  {object}  map[string]interface{}  "Mirror re-signing not configured"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirrors/{id}/resign [post]
+// ResignMirror re-signs a mirror's synced provider versions with the registry key.
+// POST /api/v1/admin/mirrors/:id/resign
+func (h *MirrorHandler) ResignMirror(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mirror ID"})
+		return
+	}
+	if h.resigner == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Mirror re-signing not configured"})
+		return
+	}
+
+	summary, err := h.resigner.ResignMirror(c.Request.Context(), id)
+	if errors.Is(err, services.ErrResignMirrorNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mirror configuration not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-sign mirror: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
 // @Summary      Get mirror sync status
-// @Description  Get the current sync status, active sync, and recent sync history for a mirror. Requires admin scope.
+// @Description  Get the current sync status, active sync, recent sync history and aggregate sync stats (success rate over the retained history) for a mirror, plus every platform the mirror holds with its downloads over the last 90 days (unused_90d marks platforms with none). While a sync lease is held, sync_lease shows its holder and heartbeat age. storage_usage compares the bytes of the mirror's stored archives with its max_storage_bytes. Requires admin scope.
 // @Tags         Mirror
 // @Security     Bearer
 // @Produce      json
@@ -574,6 +782,13 @@ func (h *MirrorHandler) GetMirrorStatus(c *gin.Context) {
 		return
 	}
 
+	// Aggregate over the retained history so trimming old runs keeps the signal
+	syncStats, err := h.mirrorRepo.GetSyncHistoryStats(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sync stats: " + err.Error()})
+		return
+	}
+
 	// Calculate next scheduled sync
 	var nextScheduled *time.Time
 	if config.Enabled && config.LastSyncAt != nil {
@@ -585,12 +800,150 @@ func (h *MirrorHandler) GetMirrorStatus(c *gin.Context) {
 		MirrorConfig:  *config,
 		CurrentSync:   activeSync,
 		RecentSyncs:   recentSyncs,
+		SyncStats:     syncStats,
 		NextScheduled: nextScheduled,
 	}
 
+	// Platform usage only informs platform_filter tuning; a failed lookup
+	// leaves it out rather than failing the status.
+	since := time.Now().AddDate(0, 0, -models.UnusedPlatformWindowDays)
+	if usage, err := h.mirrorRepo.GetPlatformUsage(c.Request.Context(), id, since); err != nil {
+		slog.Warn("failed to load mirror platform usage", "mirror_id", id, "error", err)
+	} else {
+		status.Platforms = usage
+	}
+
+	// Storage usage is informational; a failed lookup leaves it out.
+	if used, err := h.mirrorRepo.GetStorageUsage(c.Request.Context(), id); err != nil {
+		slog.Warn("failed to load mirror storage usage", "mirror_id", id, "error", err)
+	} else {
+		status.StorageUsage = models.NewMirrorStorageUsage(used, config.MaxStorageBytes)
+	}
+
+	if h.leases != nil {
+		if lease, err := h.leases.Get(c.Request.Context(), id, models.MirrorSyncLeaseTTL); err != nil {
+			slog.Warn("failed to load mirror sync lease", "mirror_id", id, "error", err)
+		} else {
+			status.SyncLease = lease
+		}
+	}
+
 	c.JSON(http.StatusOK, status)
 }
 
+// @Summary      Clear mirror sync lock
+// @Description  Force-releases the sync lease of a mirror so it can sync again, for a lease left behind by a replica that died mid-sync. Leases also expire on their own once their heartbeat is older than two minutes. If the holder is still running, its next heartbeat fails and the sync stops. Requires mirrors:manage scope.
+// @Tags         Mirror
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Mirror configuration ID (UUID)"
+// @Success      200  {object}  admin.MessageResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid mirror ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "No sync lease held for this mirror"
+// @Failure      503  {object}  map[string]interface{}  "Sync leases not configured"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirrors/{id}/lock [delete]
+// ClearSyncLock force-releases a mirror's sync lease.
+// DELETE /api/v1/admin/mirrors/:id/lock
+func (h *MirrorHandler) ClearSyncLock(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mirror ID"})
+		return
+	}
+	if h.leases == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Sync leases not configured"})
+		return
+	}
+
+	lease, err := h.leases.Get(c.Request.Context(), id, models.MirrorSyncLeaseTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sync lease: " + err.Error()})
+		return
+	}
+	if lease == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No sync lease held for this mirror"})
+		return
+	}
+
+	cleared, err := h.leases.ForceRelease(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear sync lease: " + err.Error()})
+		return
+	}
+	if !cleared {
+		// Released by its holder between the lookup and the delete.
+		c.JSON(http.StatusNotFound, gin.H{"error": "No sync lease held for this mirror"})
+		return
+	}
+
+	slog.Info("mirror sync lease cleared",
+		"mirror_id", id, "holder", lease.Holder, "heartbeat_age_seconds", lease.HeartbeatAgeSeconds,
+		"stale", lease.Stale, "cleared_by", c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Sync lock cleared"})
+}
+
+// @Summary      List mirror sync history
+// @Description  Returns a page of sync runs for a mirror configuration, newest first. History is bounded by the configuration's retention settings. Requires mirrors:read scope.
+// @Tags         Mirror
+// @Security     Bearer
+// @Produce      json
+// @Param        id      path   string  true   "Mirror configuration ID (UUID)"
+// @Param        limit   query  int     false  "Maximum results (default 50, max 200)"
+// @Param        offset  query  int     false  "Offset for pagination (default 0)"
+// @Success      200  {object}  admin.MirrorSyncHistoryPage
+// @Failure      400  {object}  map[string]interface{}  "Invalid mirror ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Mirror configuration not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirrors/{id}/history [get]
+// GetMirrorSyncHistory returns paginated sync history for a mirror configuration
+// GET /api/v1/admin/mirrors/:id/history
+func (h *MirrorHandler) GetMirrorSyncHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mirror ID"})
+		return
+	}
+
+	config, err := h.mirrorRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get mirror configuration: " + err.Error()})
+		return
+	}
+	if config == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mirror configuration not found"})
+		return
+	}
+
+	page := pagination.Parse(c, syncHistoryLimits)
+
+	history, total, err := h.mirrorRepo.ListSyncHistoryPaginated(c.Request.Context(), id, page.Limit, page.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sync history: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, MirrorSyncHistoryPage{
+		MirrorSyncHistoryListResponse: models.MirrorSyncHistoryListResponse{
+			History:    history,
+			TotalCount: total,
+			Limit:      page.Limit,
+			Offset:     page.Offset,
+		},
+		Meta: page.Meta(int64(total)),
+	})
+}
+
+// syncHistoryLimits are the page size default and cap of the sync history
+// endpoints.
+var syncHistoryLimits = pagination.Limits{Default: 50, Max: models.MaxSyncHistoryPageSize}
+
+// mirroredProviderLimits are the page size default and cap of the mirrored
+// provider list.
+var mirroredProviderLimits = pagination.Limits{Default: 100, Max: 1000}
+
 // @Summary      List mirrored providers
 // @Description  List all providers that have been synced for a mirror configuration, including their synced versions. Requires admin scope.
 // @Tags         Mirror
@@ -615,17 +968,7 @@ func (h *MirrorHandler) ListMirroredProviders(c *gin.Context) {
 		return
 	}
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if limit > 1000 {
-		limit = 1000
-	}
-	if limit < 1 {
-		limit = 1
-	}
-	if offset < 0 {
-		offset = 0
-	}
+	page := pagination.Parse(c, mirroredProviderLimits)
 
 	config, err := h.mirrorRepo.GetByID(c.Request.Context(), id)
 	if err != nil {
@@ -637,7 +980,7 @@ func (h *MirrorHandler) ListMirroredProviders(c *gin.Context) {
 		return
 	}
 
-	providers, total, err := h.mirrorRepo.ListMirroredProvidersPaginated(c.Request.Context(), id, limit, offset)
+	providers, total, err := h.mirrorRepo.ListMirroredProvidersPaginated(c.Request.Context(), id, page.Limit, page.Offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list mirrored providers: " + err.Error()})
 		return
@@ -652,6 +995,7 @@ func (h *MirrorHandler) ListMirroredProviders(c *gin.Context) {
 		Arch              string `json:"arch"`
 		Filename          string `json:"filename"`
 		Shasum            string `json:"shasum"`
+		models.UpstreamProvenance
 	}
 
 	type versionWithPlatforms struct {
@@ -680,15 +1024,20 @@ func (h *MirrorHandler) ListMirroredProviders(c *gin.Context) {
 					rawPlatforms = nil
 				}
 			}
+			var provenance map[string]models.UpstreamProvenance
+			if len(rawPlatforms) > 0 {
+				provenance, _ = h.providerRepo.ListPlatformProvenance(c.Request.Context(), v.ProviderVersionID.String())
+			}
 			platforms := make([]platformJSON, 0, len(rawPlatforms))
 			for _, pl := range rawPlatforms {
 				platforms = append(platforms, platformJSON{
-					ID:                pl.ID,
-					ProviderVersionID: pl.ProviderVersionID,
-					OS:                pl.OS,
-					Arch:              pl.Arch,
-					Filename:          pl.Filename,
-					Shasum:            pl.Shasum,
+					ID:                 pl.ID,
+					ProviderVersionID:  pl.ProviderVersionID,
+					OS:                 pl.OS,
+					Arch:               pl.Arch,
+					Filename:           pl.Filename,
+					Shasum:             pl.Shasum,
+					UpstreamProvenance: provenance[pl.ID],
 				})
 			}
 			versionList = append(versionList, versionWithPlatforms{
@@ -702,7 +1051,13 @@ func (h *MirrorHandler) ListMirroredProviders(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{"providers": result, "total": total, "limit": limit, "offset": offset})
+	c.JSON(http.StatusOK, gin.H{
+		"providers": result,
+		"total":     total,
+		"limit":     page.Limit,
+		"offset":    page.Offset,
+		"meta":      page.Meta(int64(total)),
+	})
 }
 
 // RegisterRoutes registers all mirror management routes
@@ -715,6 +1070,7 @@ func (h *MirrorHandler) RegisterRoutes(router *gin.RouterGroup) {
 		mirrors.PUT("/:id", h.UpdateMirrorConfig)
 		mirrors.DELETE("/:id", h.DeleteMirrorConfig)
 		mirrors.POST("/:id/sync", h.TriggerSync)
+		mirrors.POST("/:id/resign", h.ResignMirror)
 		mirrors.GET("/:id/status", h.GetMirrorStatus)
 		mirrors.GET("/:id/providers", h.ListMirroredProviders)
 	}
@@ -0,0 +1,223 @@
+// mirror_allowlist.go implements admin CRUD for the network mirror allowlist.
+// While the allowlist is empty the mirror routes (/terraform/providers/...)
+// serve every stored provider; once it has entries, only providers matching
+// one of them are served. Writes take effect without a restart.
+package admin
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// MirrorAllowlistHandlers serves the mirror allowlist admin endpoints.
+type MirrorAllowlistHandlers struct {
+	repo      *repositories.MirrorAllowlistRepository
+	allowlist *middleware.MirrorAllowlist
+}
+
+// NewMirrorAllowlistHandlers constructs a MirrorAllowlistHandlers. allowlist is
+// the cache consulted by the mirror routes; it is invalidated on every write
+// and may be nil.
+func NewMirrorAllowlistHandlers(repo *repositories.MirrorAllowlistRepository, allowlist *middleware.MirrorAllowlist) *MirrorAllowlistHandlers {
+	return &MirrorAllowlistHandlers{repo: repo, allowlist: allowlist}
+}
+
+// MirrorAllowlistEntryRequest is the body of POST and PUT /admin/mirror-allowlist.
+// Omitted patterns default to "*".
+type MirrorAllowlistEntryRequest struct {
+	HostnamePattern  string `json:"hostname_pattern"`  // e.g. "registry.terraform.io"
+	NamespacePattern string `json:"namespace_pattern"` // e.g. "hashicorp"
+	TypePattern      string `json:"type_pattern"`      // e.g. "aws" or "azure*"
+	Description      string `json:"description"`
+}
+
+// @Summary      List mirror allowlist entries
+// @Description  Lists the hostname/namespace/type patterns the network mirror routes may serve. An empty list means every stored provider is served.
+// @Tags         Mirror
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "{\"entries\": []MirrorAllowlistEntry}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirror-allowlist [get]
+// ListEntries lists mirror allowlist entries.
+// GET /api/v1/admin/mirror-allowlist
+func (h *MirrorAllowlistHandlers) ListEntries(c *gin.Context) {
+	entries, err := h.repo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list mirror allowlist entries"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+// @Summary      Get mirror allowlist entry
+// @Tags         Mirror
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Entry ID"
+// @Success      200  {object}  map[string]interface{}  "{\"entry\": MirrorAllowlistEntry}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Entry not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirror-allowlist/{id} [get]
+// GetEntry returns one mirror allowlist entry.
+// GET /api/v1/admin/mirror-allowlist/:id
+func (h *MirrorAllowlistHandlers) GetEntry(c *gin.Context) {
+	entry, err := h.repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mirror allowlist entry"})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mirror allowlist entry not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entry": entry})
+}
+
+// @Summary      Create mirror allowlist entry
+// @Description  Allows the network mirror to serve providers matching the hostname/namespace/type glob patterns (`*` matches any value). Creating the first entry restricts the mirror to the allowlist.
+// @Tags         Mirror
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  MirrorAllowlistEntryRequest  true  "Entry"
+// @Success      201  {object}  map[string]interface{}  "{\"entry\": MirrorAllowlistEntry}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirror-allowlist [post]
+// CreateEntry creates a mirror allowlist entry.
+// POST /api/v1/admin/mirror-allowlist
+func (h *MirrorAllowlistHandlers) CreateEntry(c *gin.Context) {
+	entry, ok := bindMirrorAllowlistEntry(c)
+	if !ok {
+		return
+	}
+	if uid := c.GetString("user_id"); uid != "" {
+		entry.CreatedBy = &uid
+	}
+	if err := h.repo.Create(c.Request.Context(), entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create mirror allowlist entry"})
+		return
+	}
+	h.invalidate()
+	c.JSON(http.StatusCreated, gin.H{"entry": entry})
+}
+
+// @Summary      Update mirror allowlist entry
+// @Description  Replaces the entry's patterns and description.
+// @Tags         Mirror
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                       true  "Entry ID"
+// @Param        body  body  MirrorAllowlistEntryRequest  true  "Entry"
+// @Success      200  {object}  map[string]interface{}  "{\"entry\": MirrorAllowlistEntry}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Entry not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirror-allowlist/{id} [put]
+// UpdateEntry replaces a mirror allowlist entry.
+// PUT /api/v1/admin/mirror-allowlist/:id
+func (h *MirrorAllowlistHandlers) UpdateEntry(c *gin.Context) {
+	entry, ok := bindMirrorAllowlistEntry(c)
+	if !ok {
+		return
+	}
+	entry.ID = c.Param("id")
+	found, err := h.repo.Update(c.Request.Context(), entry)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update mirror allowlist entry"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mirror allowlist entry not found"})
+		return
+	}
+	h.invalidate()
+	c.JSON(http.StatusOK, gin.H{"entry": entry})
+}
+
+// @Summary      Delete mirror allowlist entry
+// @Description  Deletes the entry. Deleting the last entry lets the mirror serve every stored provider again.
+// @Tags         Mirror
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Entry ID"
+// @Success      200  {object}  admin.MessageResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Entry not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirror-allowlist/{id} [delete]
+// DeleteEntry deletes a mirror allowlist entry.
+// DELETE /api/v1/admin/mirror-allowlist/:id
+func (h *MirrorAllowlistHandlers) DeleteEntry(c *gin.Context) {
+	deleted, err := h.repo.Delete(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete mirror allowlist entry"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mirror allowlist entry not found"})
+		return
+	}
+	h.invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Mirror allowlist entry deleted"})
+}
+
+func (h *MirrorAllowlistHandlers) invalidate() {
+	if h.allowlist != nil {
+		h.allowlist.Invalidate()
+	}
+}
+
+// bindMirrorAllowlistEntry binds and validates a MirrorAllowlistEntryRequest,
+// writing a 400 response and returning false when it is unusable.
+func bindMirrorAllowlistEntry(c *gin.Context) (*models.MirrorAllowlistEntry, bool) {
+	var req MirrorAllowlistEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return nil, false
+	}
+
+	patterns := map[string]*string{
+		"hostname_pattern":  &req.HostnamePattern,
+		"namespace_pattern": &req.NamespacePattern,
+		"type_pattern":      &req.TypePattern,
+	}
+	for field, p := range patterns {
+		*p = strings.TrimSpace(*p)
+		if *p == "" {
+			*p = "*"
+		}
+		if strings.Contains(*p, "/") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + field + ": must not contain '/'"})
+			return nil, false
+		}
+		if _, err := path.Match(*p, ""); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + field + ": " + err.Error()})
+			return nil, false
+		}
+	}
+
+	return &models.MirrorAllowlistEntry{
+		HostnamePattern:  strings.ToLower(req.HostnamePattern),
+		NamespacePattern: req.NamespacePattern,
+		TypePattern:      req.TypePattern,
+		Description:      optionalString(req.Description),
+	}, true
+}
@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+var mirrorAllowlistCols = []string{
+	"id", "hostname_pattern", "namespace_pattern", "type_pattern", "description",
+	"created_by", "created_at", "updated_at",
+}
+
+func newMirrorAllowlistRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine, *middleware.MirrorAllowlist) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := repositories.NewMirrorAllowlistRepository(db)
+	allowlist := middleware.NewMirrorAllowlist(repo, time.Hour)
+	h := NewMirrorAllowlistHandlers(repo, allowlist)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "admin-1") })
+	r.GET("/mirror-allowlist", h.ListEntries)
+	r.GET("/mirror-allowlist/:id", h.GetEntry)
+	r.POST("/mirror-allowlist", h.CreateEntry)
+	r.PUT("/mirror-allowlist/:id", h.UpdateEntry)
+	r.DELETE("/mirror-allowlist/:id", h.DeleteEntry)
+	return mock, r, allowlist
+}
+
+func doMirrorAllowlistReq(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateMirrorAllowlistEntry_DefaultsAndInvalidates(t *testing.T) {
+	mock, r, allowlist := newMirrorAllowlistRouter(t)
+	ctx := context.Background()
+
+	// Prime the cache with an empty allowlist.
+	mock.ExpectQuery("SELECT.*FROM mirror_allowlist_entries").WillReturnRows(sqlmock.NewRows(mirrorAllowlistCols))
+	if ok, _ := allowlist.Permits(ctx, "registry.example.com", "internal", "secret"); !ok {
+		t.Fatal("empty allowlist should permit everything")
+	}
+
+	mock.ExpectQuery("INSERT INTO mirror_allowlist_entries").
+		WithArgs("registry.terraform.io", "hashicorp", "*", nil, "admin-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("e-1", time.Now(), time.Now()))
+	w := doMirrorAllowlistReq(r, http.MethodPost, "/mirror-allowlist",
+		`{"hostname_pattern":"Registry.Terraform.io","namespace_pattern":"hashicorp"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+
+	// The write invalidated the cache, so the next check reloads.
+	mock.ExpectQuery("SELECT.*FROM mirror_allowlist_entries").
+		WillReturnRows(sqlmock.NewRows(mirrorAllowlistCols).
+			AddRow("e-1", "registry.terraform.io", "hashicorp", "*", nil, "admin-1", time.Now(), time.Now()))
+	if ok, _ := allowlist.Permits(ctx, "registry.example.com", "internal", "secret"); ok {
+		t.Error("provider still permitted after the allowlist was created")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestCreateMirrorAllowlistEntry_InvalidPattern(t *testing.T) {
+	_, r, _ := newMirrorAllowlistRouter(t)
+	for _, body := range []string{
+		`{"namespace_pattern":"["}`,
+		`{"type_pattern":"aws/extra"}`,
+	} {
+		if w := doMirrorAllowlistReq(r, http.MethodPost, "/mirror-allowlist", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestUpdateMirrorAllowlistEntry_NotFound(t *testing.T) {
+	mock, r, _ := newMirrorAllowlistRouter(t)
+	mock.ExpectQuery("UPDATE mirror_allowlist_entries").
+		WillReturnRows(sqlmock.NewRows([]string{"created_by", "created_at", "updated_at"}))
+
+	if w := doMirrorAllowlistReq(r, http.MethodPut, "/mirror-allowlist/missing", `{}`); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestDeleteMirrorAllowlistEntry(t *testing.T) {
+	mock, r, _ := newMirrorAllowlistRouter(t)
+	mock.ExpectExec("DELETE FROM mirror_allowlist_entries WHERE id").
+		WithArgs("e-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if w := doMirrorAllowlistReq(r, http.MethodDelete, "/mirror-allowlist/e-1", ""); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,265 @@
+// mirror_hostname_aliases.go implements admin endpoints for network mirror
+// hostname aliases, which serve a mirror's providers under a second origin
+// hostname (e.g. registry.opentofu.org for a registry.terraform.io mirror),
+// and for the per-provider opt-in an alias requires. Writes take effect
+// without a restart.
+package admin
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// mirrorAliasChecksumWarning is returned whenever a provider opts in to its
+// mirror's hostname aliases.
+const mirrorAliasChecksumWarning = "The alias registry may publish different checksums for this provider than the mirror's upstream. " +
+	"Clients whose lock files were written against the alias registry will fail checksum verification for any such version."
+
+// MirrorHostnameAliasHandlers serves the mirror hostname alias admin endpoints.
+type MirrorHostnameAliasHandlers struct {
+	repo       *repositories.MirrorHostnameAliasRepository
+	mirrorRepo *repositories.MirrorRepository
+	aliases    *middleware.MirrorHostnameAliases
+}
+
+// NewMirrorHostnameAliasHandlers constructs a MirrorHostnameAliasHandlers.
+// aliases is the cache consulted by the mirror routes; it is invalidated on
+// every alias write and may be nil.
+func NewMirrorHostnameAliasHandlers(repo *repositories.MirrorHostnameAliasRepository, mirrorRepo *repositories.MirrorRepository, aliases *middleware.MirrorHostnameAliases) *MirrorHostnameAliasHandlers {
+	return &MirrorHostnameAliasHandlers{repo: repo, mirrorRepo: mirrorRepo, aliases: aliases}
+}
+
+// MirrorHostnameAliasRequest is the body of POST /admin/mirrors/:id/hostname-aliases.
+type MirrorHostnameAliasRequest struct {
+	AliasHostname string `json:"alias_hostname" binding:"required"` // e.g. "registry.opentofu.org"
+	Namespace     string `json:"namespace"`                         // e.g. "hashicorp"; empty for every namespace
+}
+
+// MirrorAliasOptInRequest is the body of PUT /admin/mirrors/:id/providers/:providerId/hostname-aliases.
+type MirrorAliasOptInRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// @Summary      List mirror hostname aliases
+// @Description  Lists the hostnames the mirror's providers are also served under on the network mirror routes.
+// @Tags         Mirror
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Mirror configuration ID"
+// @Success      200  {object}  map[string]interface{}  "{\"aliases\": []MirrorHostnameAlias}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid mirror ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Mirror configuration not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirrors/{id}/hostname-aliases [get]
+// ListAliases lists a mirror configuration's hostname aliases.
+// GET /api/v1/admin/mirrors/:id/hostname-aliases
+func (h *MirrorHostnameAliasHandlers) ListAliases(c *gin.Context) {
+	mirror, ok := h.loadMirror(c)
+	if !ok {
+		return
+	}
+	aliases, err := h.repo.ListByMirror(c.Request.Context(), mirror.ID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list mirror hostname aliases"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"aliases": aliases})
+}
+
+// @Summary      Create mirror hostname alias
+// @Description  Serves the mirror's providers under alias_hostname as well as the upstream's hostname, for one namespace or (namespace omitted) all of them. A hostname/namespace pair can alias only one mirror. Each provider must also opt in with PUT /admin/mirrors/{id}/providers/{providerId}/hostname-aliases, and only versions the mirror synced are served under the alias.
+// @Tags         Mirror
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                      true  "Mirror configuration ID"
+// @Param        body  body  MirrorHostnameAliasRequest  true  "Alias"
+// @Success      201  {object}  map[string]interface{}  "{\"alias\": MirrorHostnameAlias}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Mirror configuration not found"
+// @Failure      409  {object}  map[string]interface{}  "Hostname and namespace already aliased"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirrors/{id}/hostname-aliases [post]
+// CreateAlias creates a hostname alias for a mirror configuration.
+// POST /api/v1/admin/mirrors/:id/hostname-aliases
+func (h *MirrorHostnameAliasHandlers) CreateAlias(c *gin.Context) {
+	mirror, ok := h.loadMirror(c)
+	if !ok {
+		return
+	}
+
+	var req MirrorHostnameAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	hostname := strings.ToLower(strings.TrimSpace(req.AliasHostname))
+	namespace := strings.TrimSpace(req.Namespace)
+	if hostname == "" || strings.ContainsAny(hostname, "/:*? ") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alias_hostname: must be a bare hostname such as registry.opentofu.org"})
+		return
+	}
+	if strings.ContainsAny(namespace, "/*? ") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid namespace: must be a single namespace or empty for every namespace"})
+		return
+	}
+	if hostname == models.UpstreamHostname(mirror.UpstreamRegistryURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alias_hostname is the mirror's own upstream hostname"})
+		return
+	}
+
+	alias := &models.MirrorHostnameAlias{
+		MirrorConfigID: mirror.ID.String(),
+		AliasHostname:  hostname,
+		Namespace:      optionalString(namespace),
+		TargetHostname: models.UpstreamHostname(mirror.UpstreamRegistryURL),
+	}
+	existing, err := h.repo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create mirror hostname alias"})
+		return
+	}
+	for _, e := range existing {
+		if e.AliasHostname == hostname && ((e.Namespace == nil && alias.Namespace == nil) ||
+			(e.Namespace != nil && alias.Namespace != nil && *e.Namespace == namespace)) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This hostname and namespace already alias a mirror"})
+			return
+		}
+	}
+
+	if uid := c.GetString("user_id"); uid != "" {
+		alias.CreatedBy = &uid
+	}
+	if err := h.repo.Create(c.Request.Context(), alias); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create mirror hostname alias"})
+		return
+	}
+	h.invalidate()
+	c.JSON(http.StatusCreated, gin.H{"alias": alias})
+}
+
+// @Summary      Delete mirror hostname alias
+// @Description  Stops serving the mirror's providers under the alias.
+// @Tags         Mirror
+// @Security     Bearer
+// @Produce      json
+// @Param        id       path  string  true  "Mirror configuration ID"
+// @Param        aliasId  path  string  true  "Alias ID"
+// @Success      200  {object}  admin.MessageResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid mirror ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Alias not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirrors/{id}/hostname-aliases/{aliasId} [delete]
+// DeleteAlias deletes one of a mirror configuration's hostname aliases.
+// DELETE /api/v1/admin/mirrors/:id/hostname-aliases/:aliasId
+func (h *MirrorHostnameAliasHandlers) DeleteAlias(c *gin.Context) {
+	mirrorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mirror ID"})
+		return
+	}
+	deleted, err := h.repo.Delete(c.Request.Context(), mirrorID.String(), c.Param("aliasId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete mirror hostname alias"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mirror hostname alias not found"})
+		return
+	}
+	h.invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Mirror hostname alias deleted"})
+}
+
+// @Summary      Opt a mirrored provider in to hostname aliases
+// @Description  Lets (enabled true) or stops (enabled false) the mirrored provider being served under the mirror's hostname aliases. Enabling returns a warning: the alias registry may publish different checksums for the provider than the mirror's upstream.
+// @Tags         Mirror
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id          path  string                   true  "Mirror configuration ID"
+// @Param        providerId  path  string                   true  "Mirrored provider ID"
+// @Param        body        body  MirrorAliasOptInRequest  true  "Opt-in"
+// @Success      200  {object}  map[string]interface{}  "{\"mirrored_provider_id\", \"serve_under_aliases\", \"warning\"}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Mirrored provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/mirrors/{id}/providers/{providerId}/hostname-aliases [put]
+// SetProviderOptIn opts a mirrored provider in to or out of hostname aliases.
+// PUT /api/v1/admin/mirrors/:id/providers/:providerId/hostname-aliases
+func (h *MirrorHostnameAliasHandlers) SetProviderOptIn(c *gin.Context) {
+	mirrorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mirror ID"})
+		return
+	}
+	providerID, err := uuid.Parse(c.Param("providerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mirrored provider ID"})
+		return
+	}
+	var req MirrorAliasOptInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	found, err := h.repo.SetServeUnderAliases(c.Request.Context(), mirrorID.String(), providerID.String(), req.Enabled)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update mirrored provider"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mirrored provider not found"})
+		return
+	}
+
+	resp := gin.H{"mirrored_provider_id": providerID.String(), "serve_under_aliases": req.Enabled}
+	if req.Enabled {
+		slog.Warn("mirrored provider opted in to hostname aliases; alias registry checksums may differ",
+			"mirror_id", mirrorID.String(), "mirrored_provider_id", providerID.String())
+		resp["warning"] = mirrorAliasChecksumWarning
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// loadMirror resolves the :id mirror configuration, writing an error response
+// and returning false when it is invalid or missing.
+func (h *MirrorHostnameAliasHandlers) loadMirror(c *gin.Context) (*models.MirrorConfiguration, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mirror ID"})
+		return nil, false
+	}
+	mirror, err := h.mirrorRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get mirror configuration"})
+		return nil, false
+	}
+	if mirror == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mirror configuration not found"})
+		return nil, false
+	}
+	return mirror, true
+}
+
+func (h *MirrorHostnameAliasHandlers) invalidate() {
+	if h.aliases != nil {
+		h.aliases.Invalidate()
+	}
+}
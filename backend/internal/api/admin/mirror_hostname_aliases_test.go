@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+var mirrorHostnameAliasCols = []string{
+	"id", "mirror_config_id", "alias_hostname", "namespace", "created_by", "created_at",
+	"upstream_registry_url",
+}
+
+func newMirrorHostnameAliasRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine, *middleware.MirrorHostnameAliases) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := repositories.NewMirrorHostnameAliasRepository(db)
+	aliases := middleware.NewMirrorHostnameAliases(repo, time.Hour)
+	h := NewMirrorHostnameAliasHandlers(repo, repositories.NewMirrorRepository(sqlx.NewDb(db, "sqlmock")), aliases)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "admin-1") })
+	r.GET("/mirrors/:id/hostname-aliases", h.ListAliases)
+	r.POST("/mirrors/:id/hostname-aliases", h.CreateAlias)
+	r.DELETE("/mirrors/:id/hostname-aliases/:aliasId", h.DeleteAlias)
+	r.PUT("/mirrors/:id/providers/:providerId/hostname-aliases", h.SetProviderOptIn)
+	return mock, r, aliases
+}
+
+func TestCreateMirrorHostnameAlias_InvalidatesCache(t *testing.T) {
+	mock, r, aliases := newMirrorHostnameAliasRouter(t)
+	ctx := context.Background()
+
+	// Prime the cache with no aliases.
+	mock.ExpectQuery("SELECT.*FROM mirror_hostname_aliases").WillReturnRows(sqlmock.NewRows(mirrorHostnameAliasCols))
+	if a, _ := aliases.Lookup(ctx, "registry.opentofu.org", "hashicorp"); a != nil {
+		t.Fatalf("alias = %+v before any were created", a)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE id").WillReturnRows(sampleMirrorCfgRow())
+	mock.ExpectQuery("SELECT.*FROM mirror_hostname_aliases").WillReturnRows(sqlmock.NewRows(mirrorHostnameAliasCols))
+	mock.ExpectQuery("INSERT INTO mirror_hostname_aliases").
+		WithArgs(knownUUID, "registry.opentofu.org", "hashicorp", "admin-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("a-1", time.Now()))
+	w := doMirrorAllowlistReq(r, http.MethodPost, "/mirrors/"+knownUUID+"/hostname-aliases",
+		`{"alias_hostname":"Registry.OpenTofu.org","namespace":"hashicorp"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"target_hostname":"registry.terraform.io"`) {
+		t.Errorf("body = %s, want the target hostname", w.Body.String())
+	}
+
+	// The write invalidated the cache, so the next lookup reloads.
+	ns := "hashicorp"
+	mock.ExpectQuery("SELECT.*FROM mirror_hostname_aliases").
+		WillReturnRows(sqlmock.NewRows(mirrorHostnameAliasCols).
+			AddRow("a-1", knownUUID, "registry.opentofu.org", &ns, "admin-1", time.Now(), "https://registry.terraform.io"))
+	if a, _ := aliases.Lookup(ctx, "registry.opentofu.org", "hashicorp"); a == nil || a.TargetHostname != "registry.terraform.io" {
+		t.Errorf("alias = %+v after create, want one targeting registry.terraform.io", a)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestCreateMirrorHostnameAlias_Rejects(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body     string
+		existing bool
+		want     int
+	}{
+		"url not hostname":  {body: `{"alias_hostname":"https://registry.opentofu.org"}`, want: http.StatusBadRequest},
+		"own upstream host": {body: `{"alias_hostname":"registry.terraform.io"}`, want: http.StatusBadRequest},
+		"duplicate":         {body: `{"alias_hostname":"registry.opentofu.org"}`, existing: true, want: http.StatusConflict},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock, r, _ := newMirrorHostnameAliasRouter(t)
+			mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE id").WillReturnRows(sampleMirrorCfgRow())
+			if tc.existing {
+				mock.ExpectQuery("SELECT.*FROM mirror_hostname_aliases").
+					WillReturnRows(sqlmock.NewRows(mirrorHostnameAliasCols).
+						AddRow("a-1", "other-mirror", "registry.opentofu.org", nil, nil, time.Now(), "https://registry.terraform.io"))
+			}
+			w := doMirrorAllowlistReq(r, http.MethodPost, "/mirrors/"+knownUUID+"/hostname-aliases", tc.body)
+			if w.Code != tc.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetMirrorProviderAliasOptIn_WarnsOnEnable(t *testing.T) {
+	mock, r, _ := newMirrorHostnameAliasRouter(t)
+	path := "/mirrors/" + knownUUID + "/providers/" + knownUUID + "/hostname-aliases"
+
+	mock.ExpectExec("UPDATE mirrored_providers SET serve_under_aliases").
+		WithArgs(knownUUID, knownUUID, true).WillReturnResult(sqlmock.NewResult(0, 1))
+	w := doMirrorAllowlistReq(r, http.MethodPut, path, `{"enabled":true}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"warning"`) {
+		t.Errorf("enable: status = %d, body = %s; want 200 with a warning", w.Code, w.Body.String())
+	}
+
+	mock.ExpectExec("UPDATE mirrored_providers SET serve_under_aliases").
+		WithArgs(knownUUID, knownUUID, false).WillReturnResult(sqlmock.NewResult(0, 1))
+	w = doMirrorAllowlistReq(r, http.MethodPut, path, `{"enabled":false}`)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), `"warning"`) {
+		t.Errorf("disable: status = %d, body = %s; want 200 without a warning", w.Code, w.Body.String())
+	}
+
+	mock.ExpectExec("UPDATE mirrored_providers SET serve_under_aliases").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if w := doMirrorAllowlistReq(r, http.MethodPut, path, `{"enabled":true}`); w.Code != http.StatusNotFound {
+		t.Errorf("unknown provider: status = %d, want 404", w.Code)
+	}
+}
+
+func TestDeleteMirrorHostnameAlias_NotFound(t *testing.T) {
+	mock, r, _ := newMirrorHostnameAliasRouter(t)
+	mock.ExpectExec("DELETE FROM mirror_hostname_aliases").
+		WithArgs("a-1", knownUUID).WillReturnResult(sqlmock.NewResult(0, 0))
+	if w := doMirrorAllowlistReq(r, http.MethodDelete, "/mirrors/"+knownUUID+"/hostname-aliases/a-1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
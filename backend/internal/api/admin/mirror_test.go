@@ -2,9 +2,11 @@ package admin
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -13,8 +15,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
 // ---------------------------------------------------------------------------
@@ -95,6 +100,7 @@ func newMirrorRouterWithJob(t *testing.T, syncJob MirrorSyncJobInterface) (sqlmo
 	r.DELETE("/mirrors/:id", h.DeleteMirrorConfig)
 	r.POST("/mirrors/:id/sync", h.TriggerSync)
 	r.GET("/mirrors/:id/status", h.GetMirrorStatus)
+	r.GET("/mirrors/:id/history", h.GetMirrorSyncHistory)
 	return mock, r
 }
 
@@ -219,6 +225,51 @@ func TestMirrorCreate_InsertDBError(t *testing.T) {
 	}
 }
 
+func TestMirrorCreate_Private(t *testing.T) {
+	mock, r := newMirrorRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE name").
+		WillReturnRows(sqlmock.NewRows(mirrorCfgCols))
+	mock.ExpectExec("INSERT INTO mirror_configurations").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/mirrors",
+		jsonBody(map[string]interface{}{
+			"name":                  "team-mirror",
+			"upstream_registry_url": "https://registry.terraform.io",
+			"organization_id":       knownUUID,
+			"private":               true,
+		})))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+	if got := getJSON(w)["private"]; got != true {
+		t.Errorf("private = %v, want true", got)
+	}
+}
+
+func TestMirrorCreate_PrivateWithoutOrganization(t *testing.T) {
+	mock, r := newMirrorRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE name").
+		WillReturnRows(sqlmock.NewRows(mirrorCfgCols))
+	// No default organization either, so the mirror would have no owner.
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name", "idp_type", "idp_name", "created_at", "updated_at"}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/mirrors",
+		jsonBody(map[string]interface{}{
+			"name":                  "team-mirror",
+			"upstream_registry_url": "https://registry.terraform.io",
+			"private":               true,
+		})))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ListMirrorConfigs
 // ---------------------------------------------------------------------------
@@ -374,6 +425,21 @@ func TestMirrorUpdate_RequiresApprovalPersisted(t *testing.T) {
 	}
 }
 
+func TestMirrorUpdate_PrivateWithoutOrganization(t *testing.T) {
+	mock, r := newMirrorRouter(t)
+	// The sample config has no organization.
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE id").
+		WillReturnRows(sampleMirrorCfgRow())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/mirrors/"+knownUUID,
+		jsonBody(map[string]interface{}{"private": true})))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
 // ---------------------------------------------------------------------------
 // DeleteMirrorConfig
 // ---------------------------------------------------------------------------
@@ -391,6 +457,7 @@ func TestMirrorDelete_InvalidID(t *testing.T) {
 func TestMirrorDelete_NotFound(t *testing.T) {
 	mock, r := newMirrorRouter(t)
 	// 0 rows affected → repo returns error → handler returns 500
+	expectMirrorProviders(mock)
 	mock.ExpectExec("DELETE FROM mirror_configurations WHERE id").
 		WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -404,6 +471,7 @@ func TestMirrorDelete_NotFound(t *testing.T) {
 
 func TestMirrorDelete_DBError(t *testing.T) {
 	mock, r := newMirrorRouter(t)
+	expectMirrorProviders(mock)
 	mock.ExpectExec("DELETE FROM mirror_configurations WHERE id").
 		WillReturnError(errDB)
 
@@ -417,6 +485,7 @@ func TestMirrorDelete_DBError(t *testing.T) {
 
 func TestMirrorDelete_Success(t *testing.T) {
 	mock, r := newMirrorRouter(t)
+	expectMirrorProviders(mock)
 	mock.ExpectExec("DELETE FROM mirror_configurations WHERE id").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -428,6 +497,117 @@ func TestMirrorDelete_Success(t *testing.T) {
 	}
 }
 
+// expectMirrorProviders expects DeleteMirrorConfig's lookup of the providers
+// the mirror tracks, returning rows as (namespace, type, exclusive) triples.
+func expectMirrorProviders(mock sqlmock.Sqlmock, rows ...[]interface{}) {
+	result := sqlmock.NewRows([]string{"provider_id", "namespace", "type", "exclusive"})
+	for _, row := range rows {
+		result.AddRow(uuid.New(), row[0], row[1], row[2])
+	}
+	mock.ExpectQuery("SELECT p.id AS provider_id.*FROM mirrored_providers").WillReturnRows(result)
+}
+
+// fakeProviderRemover records the providers DeleteMirrorConfig removes.
+type fakeProviderRemover struct {
+	removed []string
+	err     error
+}
+
+func (f *fakeProviderRemover) RemoveMirroredProvider(_ context.Context, providerID string) error {
+	f.removed = append(f.removed, providerID)
+	return f.err
+}
+
+func newMirrorDeleteRouter(t *testing.T, remover MirrorProviderRemoverInterface) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewMirrorHandler(repositories.NewMirrorRepository(sqlx.NewDb(db, "sqlmock")),
+		repositories.NewOrganizationRepository(db), repositories.NewProviderRepository(db))
+	if remover != nil {
+		h.SetProviderRemover(remover)
+	}
+	r := gin.New()
+	r.DELETE("/mirrors/:id", h.DeleteMirrorConfig)
+	return mock, r
+}
+
+func TestMirrorDelete_KeepsProvidersByDefault(t *testing.T) {
+	remover := &fakeProviderRemover{}
+	mock, r := newMirrorDeleteRouter(t, remover)
+	expectMirrorProviders(mock, []interface{}{"hashicorp", "aws", true})
+	mock.ExpectExec("DELETE FROM mirror_configurations WHERE id").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/mirrors/"+knownUUID, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if len(remover.removed) != 0 {
+		t.Errorf("removed = %v, want none", remover.removed)
+	}
+	if !strings.Contains(w.Body.String(), `"providers_kept":["hashicorp/aws"]`) {
+		t.Errorf("body = %s, want hashicorp/aws kept", w.Body.String())
+	}
+}
+
+func TestMirrorDelete_DeleteProviders(t *testing.T) {
+	remover := &fakeProviderRemover{}
+	mock, r := newMirrorDeleteRouter(t, remover)
+	expectMirrorProviders(mock,
+		[]interface{}{"hashicorp", "aws", true},
+		[]interface{}{"hashicorp", "google", false})
+	mock.ExpectExec("DELETE FROM mirror_configurations WHERE id").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/mirrors/"+knownUUID+"?delete_providers=true", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if len(remover.removed) != 1 {
+		t.Errorf("removed = %v, want only the exclusive provider", remover.removed)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"providers_deleted":["hashicorp/aws"]`) || !strings.Contains(body, `"providers_kept":["hashicorp/google"]`) {
+		t.Errorf("body = %s, want aws deleted and google kept", body)
+	}
+}
+
+func TestMirrorDelete_DeleteProvidersImmutable(t *testing.T) {
+	remover := &fakeProviderRemover{err: storage.ErrArtifactImmutable}
+	mock, r := newMirrorDeleteRouter(t, remover)
+	expectMirrorProviders(mock, []interface{}{"hashicorp", "aws", true})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/mirrors/"+knownUUID+"?delete_providers=true", nil))
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403: body=%s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestMirrorDelete_DeleteProvidersNotConfigured(t *testing.T) {
+	_, r := newMirrorDeleteRouter(t, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/mirrors/"+knownUUID+"?delete_providers=true", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // TriggerSync
 // ---------------------------------------------------------------------------
@@ -497,6 +677,55 @@ func TestMirrorTriggerSync_AlreadyInProgress(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ResignMirror
+// ---------------------------------------------------------------------------
+
+type mockResigner struct {
+	summary *services.ResignSummary
+	err     error
+}
+
+func (m *mockResigner) ResignMirror(_ context.Context, _ uuid.UUID) (*services.ResignSummary, error) {
+	return m.summary, m.err
+}
+
+func newResignRouter(resigner MirrorResignerInterface) *gin.Engine {
+	h := NewMirrorHandler(nil, nil, nil)
+	if resigner != nil {
+		h.SetResigner(resigner)
+	}
+	r := gin.New()
+	r.POST("/mirrors/:id/resign", h.ResignMirror)
+	return r
+}
+
+func TestMirrorResign(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		resigner MirrorResignerInterface
+		wantCode int
+		wantBody string
+	}{
+		{"invalid id", "not-a-uuid", &mockResigner{}, http.StatusBadRequest, "Invalid mirror ID"},
+		{"not configured", knownUUID, nil, http.StatusServiceUnavailable, "not configured"},
+		{"not found", knownUUID, &mockResigner{err: services.ErrResignMirrorNotFound}, http.StatusNotFound, "not found"},
+		{"error", knownUUID, &mockResigner{err: fmt.Errorf("db down")}, http.StatusInternalServerError, "db down"},
+		{"success", knownUUID, &mockResigner{summary: &services.ResignSummary{KeyID: "0123456789ABCDEF", Versions: 3, Resigned: 2, Skipped: 1}},
+			http.StatusOK, `"resigned":2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newResignRouter(tt.resigner).ServeHTTP(w, httptest.NewRequest("POST", "/mirrors/"+tt.id+"/resign", nil))
+			if w.Code != tt.wantCode || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("got %d %s, want %d containing %q", w.Code, w.Body.String(), tt.wantCode, tt.wantBody)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // GetMirrorStatus
 // ---------------------------------------------------------------------------
@@ -535,6 +764,9 @@ func TestMirrorGetStatus_Success(t *testing.T) {
 	// GetSyncHistory (uses SelectContext)
 	mock.ExpectQuery("SELECT.*FROM mirror_sync_history WHERE mirror_config_id").
 		WillReturnRows(emptySyncHistRows())
+	// GetSyncHistoryStats
+	mock.ExpectQuery("SELECT COUNT.*FROM mirror_sync_history").
+		WillReturnRows(syncStatsRow(4, 3, 1))
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("GET", "/mirrors/"+knownUUID+"/status", nil))
@@ -546,6 +778,112 @@ func TestMirrorGetStatus_Success(t *testing.T) {
 	if resp["mirror_config"] == nil {
 		t.Error("response missing 'mirror_config' key")
 	}
+	stats, _ := resp["sync_stats"].(map[string]interface{})
+	if stats["success_rate"] != 0.75 || stats["total_runs"] != float64(4) {
+		t.Errorf("sync_stats = %v, want 4 runs at 0.75", stats)
+	}
+}
+
+func TestMirrorGetStatus_PlatformUsage(t *testing.T) {
+	mock, r := newMirrorRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE id").
+		WillReturnRows(sampleMirrorCfgRow())
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_history WHERE mirror_config_id.*AND status").
+		WillReturnRows(emptySyncHistRows())
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_history WHERE mirror_config_id").
+		WillReturnRows(emptySyncHistRows())
+	mock.ExpectQuery("SELECT COUNT.*FROM mirror_sync_history").
+		WillReturnRows(syncStatsRow(0, 0, 0))
+	mock.ExpectQuery("FROM platforms p.*LEFT JOIN provider_download_stats").
+		WillReturnRows(sqlmock.NewRows([]string{"os", "arch", "downloads"}).
+			AddRow("linux", "amd64", 42).
+			AddRow("windows", "386", 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/mirrors/"+knownUUID+"/status", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Platforms []models.MirrorPlatformUsage `json:"platforms"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	want := []models.MirrorPlatformUsage{
+		{OS: "linux", Arch: "amd64", Downloads: 42},
+		{OS: "windows", Arch: "386", Downloads: 0, Unused: true},
+	}
+	if !reflect.DeepEqual(resp.Platforms, want) {
+		t.Errorf("platforms = %+v, want %+v", resp.Platforms, want)
+	}
+}
+
+func TestMirrorGetStatus_StatsDBError(t *testing.T) {
+	mock, r := newMirrorRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE id").
+		WillReturnRows(sampleMirrorCfgRow())
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_history WHERE mirror_config_id.*AND status").
+		WillReturnRows(emptySyncHistRows())
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_history WHERE mirror_config_id").
+		WillReturnRows(emptySyncHistRows())
+	mock.ExpectQuery("SELECT COUNT.*FROM mirror_sync_history").
+		WillReturnError(errDB)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/mirrors/"+knownUUID+"/status", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500: body=%s", w.Code, w.Body.String())
+	}
+}
+
+// syncStatsRow returns a GetSyncHistoryStats aggregate row.
+func syncStatsRow(total, success, failed int) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{
+		"total_runs", "successful_runs", "failed_runs", "cancelled_runs",
+		"window_start", "last_success_at", "last_failure_at",
+	}).AddRow(total, success, failed, 0, nil, nil, nil)
+}
+
+// ---------------------------------------------------------------------------
+// GetMirrorSyncHistory
+// ---------------------------------------------------------------------------
+
+func TestMirrorGetSyncHistory_Paginated(t *testing.T) {
+	mock, r := newMirrorRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE id").
+		WillReturnRows(sampleMirrorCfgRow())
+	mock.ExpectQuery("SELECT COUNT.*FROM mirror_sync_history").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(40000))
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_history WHERE mirror_config_id.*LIMIT").
+		WithArgs(sqlmock.AnyArg(), 200, 400).
+		WillReturnRows(emptySyncHistRows())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/mirrors/"+knownUUID+"/history?limit=5000&offset=400", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	resp := getJSON(w)
+	if resp["total_count"] != float64(40000) || resp["limit"] != float64(200) {
+		t.Errorf("page = %v, want total_count 40000 and limit capped at 200", resp)
+	}
+}
+
+func TestMirrorGetSyncHistory_NotFound(t *testing.T) {
+	mock, r := newMirrorRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE id").
+		WillReturnRows(sqlmock.NewRows(mirrorCfgCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/mirrors/"+knownUUID+"/history", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -1066,12 +1404,21 @@ func TestListMirroredProviders_WithProviderAndVersions(t *testing.T) {
 			"providers/hashicorp/aws/1.0.0/linux_amd64.zip",
 			"local", int64(1024), "abc123", nil, int64(0),
 		))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*provenance IS NOT NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "provenance", "upstream_registry", "upstream_download_url", "upstream_shasum_url", "fetched_at"}).AddRow(
+			knownUUID, models.ProvenanceRecorded, "registry.terraform.io",
+			"https://releases.hashicorp.com/terraform-provider-aws/1.0.0/terraform-provider-aws_1.0.0_linux_amd64.zip",
+			"https://releases.hashicorp.com/terraform-provider-aws/1.0.0/terraform-provider-aws_1.0.0_SHA256SUMS", now,
+		))
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("GET", "/mirrors/"+knownUUID+"/providers", nil))
 	if w.Code != http.StatusOK {
 		t.Errorf("status = %d, want 200: body=%s", w.Code, w.Body.String())
 	}
+	if !strings.Contains(w.Body.String(), `"upstream_registry":"registry.terraform.io"`) {
+		t.Errorf("body missing platform provenance: %s", w.Body.String())
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -1173,6 +1520,8 @@ func TestMirrorGetStatus_WithNextScheduled(t *testing.T) {
 		WillReturnRows(emptySyncHistRows())
 	mock.ExpectQuery("SELECT.*FROM mirror_sync_history WHERE mirror_config_id").
 		WillReturnRows(emptySyncHistRows())
+	mock.ExpectQuery("SELECT COUNT.*FROM mirror_sync_history").
+		WillReturnRows(syncStatsRow(0, 0, 0))
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("GET", "/mirrors/"+knownUUID+"/status", nil))
 	if w.Code != http.StatusOK {
@@ -1209,3 +1558,103 @@ func TestMirrorTriggerSync_JobError(t *testing.T) {
 		t.Errorf("status = %d, want 500", w.Code)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Sync leases — GetMirrorStatus sync_lease and ClearSyncLock
+// ---------------------------------------------------------------------------
+
+var syncLeaseCols = []string{"mirror_config_id", "holder", "acquired_at", "heartbeat_at", "age", "stale"}
+
+func newMirrorLeaseRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewMirrorHandler(repositories.NewMirrorRepository(sqlx.NewDb(db, "sqlmock")),
+		repositories.NewOrganizationRepository(db), repositories.NewProviderRepository(db))
+	h.SetSyncLeases(repositories.NewMirrorSyncLeaseRepository(db))
+
+	r := gin.New()
+	r.GET("/mirrors/:id/status", h.GetMirrorStatus)
+	r.DELETE("/mirrors/:id/lock", h.ClearSyncLock)
+	return mock, r
+}
+
+func TestMirrorGetStatus_SyncLease(t *testing.T) {
+	mock, r := newMirrorLeaseRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations WHERE id").
+		WillReturnRows(sampleMirrorCfgRow())
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_history WHERE mirror_config_id.*AND status").
+		WillReturnRows(emptySyncHistRows())
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_history WHERE mirror_config_id").
+		WillReturnRows(emptySyncHistRows())
+	mock.ExpectQuery("SELECT COUNT.*FROM mirror_sync_history").
+		WillReturnRows(syncStatsRow(0, 0, 0))
+	mock.ExpectQuery("FROM platforms p.*LEFT JOIN provider_download_stats").
+		WillReturnRows(sqlmock.NewRows([]string{"os", "arch", "downloads"}))
+	old := time.Now().Add(-10 * time.Minute)
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_leases").
+		WillReturnRows(sqlmock.NewRows(syncLeaseCols).AddRow(knownUUID, "api-0/1a2b3c4d", old, old, int64(600), true))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/mirrors/"+knownUUID+"/status", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	lease, _ := getJSON(w)["sync_lease"].(map[string]interface{})
+	if lease["holder"] != "api-0/1a2b3c4d" || lease["stale"] != true || lease["heartbeat_age_seconds"] != float64(600) {
+		t.Errorf("sync_lease = %v", lease)
+	}
+}
+
+func TestMirrorClearSyncLock_Success(t *testing.T) {
+	mock, r := newMirrorLeaseRouter(t)
+	old := time.Now().Add(-10 * time.Minute)
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_leases").
+		WillReturnRows(sqlmock.NewRows(syncLeaseCols).AddRow(knownUUID, "api-0/1a2b3c4d", old, old, int64(600), true))
+	mock.ExpectExec("DELETE FROM mirror_sync_leases WHERE mirror_config_id = \\$1$").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/mirrors/"+knownUUID+"/lock", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestMirrorClearSyncLock_NoLease(t *testing.T) {
+	mock, r := newMirrorLeaseRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_leases").WillReturnRows(sqlmock.NewRows(syncLeaseCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/mirrors/"+knownUUID+"/lock", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestMirrorClearSyncLock_InvalidID(t *testing.T) {
+	_, r := newMirrorLeaseRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/mirrors/not-a-uuid/lock", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestMirrorClearSyncLock_NotConfigured(t *testing.T) {
+	_, r := newMirrorRouter(t)
+	h := NewMirrorHandler(nil, nil, nil)
+	r.DELETE("/mirrors/:id/lock", h.ClearSyncLock)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/mirrors/"+knownUUID+"/lock", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+}
@@ -0,0 +1,302 @@
+// module_approvals.go implements the per-organization module approval admin
+// endpoints: read/replace an organization's module policy (including the
+// default module version cap), list its approved module versions, and approve
+// or revoke a single version. Enforcement of the approved_only policy lives in
+// the protocol handlers (modules package); the version cap is enforced by
+// services.VersionCap on publish.
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// ModuleApprovalHandlers serves the organization module approval endpoints.
+type ModuleApprovalHandlers struct {
+	moduleRepo      *repositories.ModuleRepository
+	registryOrgRepo *repositories.OrganizationRepository // default-org lookup for module paths
+	orgRepo         *repositories.OrganizationRepository // identity organizations
+	approvalRepo    *repositories.ModuleApprovalRepository
+}
+
+// NewModuleApprovalHandlers constructs a ModuleApprovalHandlers. db is the
+// registry connection holding modules; identityDB backs organizations.
+func NewModuleApprovalHandlers(db, identityDB *sql.DB, approvalRepo *repositories.ModuleApprovalRepository) *ModuleApprovalHandlers {
+	return &ModuleApprovalHandlers{
+		moduleRepo:      repositories.NewModuleRepository(db),
+		registryOrgRepo: repositories.NewOrganizationRepository(db),
+		orgRepo:         repositories.NewOrganizationRepository(identityDB),
+		approvalRepo:    approvalRepo,
+	}
+}
+
+// UpdateModulePolicyRequest is the body of PUT /organizations/:id/module-policy.
+type UpdateModulePolicyRequest struct {
+	// ApprovedOnly restricts the organization's members and org-scoped API
+	// keys to approved module versions.
+	ApprovedOnly bool `json:"approved_only"`
+	// DefaultMaxVersions caps the listed versions of every module in the
+	// organization that has no cap of its own. Omit for no cap.
+	DefaultMaxVersions *int `json:"default_max_versions" binding:"omitempty,min=1"`
+	// DefaultVersionCapMode is what a publish past the cap does: strict
+	// rejects it, rolling archives the oldest unapproved versions. Omit for
+	// strict.
+	DefaultVersionCapMode *string `json:"default_version_cap_mode" binding:"omitempty,oneof=strict rolling"`
+	// AliasDuplicateVersions stores a publish whose archive is byte-identical
+	// to an existing version of the module as an alias of that version.
+	AliasDuplicateVersions bool `json:"alias_duplicate_versions"`
+}
+
+// ApproveModuleVersionRequest is the optional body of
+// PUT /organizations/:id/module-approvals/:namespace/:name/:system/:version.
+type ApproveModuleVersionRequest struct {
+	Notes string `json:"notes"`
+}
+
+// @Summary      Get organization module policy
+// @Description  Returns the organization's module consumption policy. Organizations without a stored policy report `approved_only: false`, no version cap and `alias_duplicate_versions: false`.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  map[string]interface{}  "{\"policy\": OrgModulePolicy}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/module-policy [get]
+// GetPolicyHandler returns an organization's module policy.
+// GET /api/v1/organizations/:id/module-policy
+func (h *ModuleApprovalHandlers) GetPolicyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID := c.Param("id")
+		policy, err := h.approvalRepo.GetPolicy(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve module policy"})
+			return
+		}
+		if policy == nil {
+			policy = &models.OrgModulePolicy{OrganizationID: orgID}
+		}
+		c.JSON(http.StatusOK, gin.H{"policy": policy})
+	}
+}
+
+// @Summary      Set organization module policy
+// @Description  Creates or replaces the organization's module consumption policy. With `approved_only` set, authenticated members and org-scoped API keys only see approved versions in the protocol version list and receive 403 when downloading an unapproved version. Anonymous protocol access is unaffected. `default_max_versions` caps the listed versions of every module without a cap of its own; `default_version_cap_mode` (strict or rolling, default strict) decides whether a publish past the cap is rejected or archives the oldest unapproved versions. With `alias_duplicate_versions` set, a publish whose archive is byte-identical to an existing version of the module is created as an alias of that version and stores no copy.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                     true  "Organization ID"
+// @Param        body  body  UpdateModulePolicyRequest  true  "Policy"
+// @Success      200  {object}  map[string]interface{}  "{\"policy\": OrgModulePolicy}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Organization not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/module-policy [put]
+// UpdatePolicyHandler creates or replaces an organization's module policy.
+// PUT /api/v1/organizations/:id/module-policy
+func (h *ModuleApprovalHandlers) UpdatePolicyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateModulePolicyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		org, err := h.orgRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		policy := &models.OrgModulePolicy{
+			OrganizationID:         org.ID,
+			ApprovedOnly:           req.ApprovedOnly,
+			DefaultMaxVersions:     req.DefaultMaxVersions,
+			DefaultVersionCapMode:  req.DefaultVersionCapMode,
+			AliasDuplicateVersions: req.AliasDuplicateVersions,
+		}
+		if err := h.approvalRepo.UpsertPolicy(c.Request.Context(), policy); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save module policy"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"policy": policy})
+	}
+}
+
+// @Summary      List organization module approvals
+// @Description  Lists the module versions the organization has approved, newest approval first. Optionally narrowed to one module.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id         path   string  true   "Organization ID"
+// @Param        namespace  query  string  false  "Module namespace"
+// @Param        name       query  string  false  "Module name"
+// @Param        system     query  string  false  "Module system"
+// @Success      200  {object}  map[string]interface{}  "{\"approvals\": []ModuleVersionApproval}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/module-approvals [get]
+// ListApprovalsHandler lists an organization's module version approvals.
+// GET /api/v1/organizations/:id/module-approvals
+func (h *ModuleApprovalHandlers) ListApprovalsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		approvals, err := h.approvalRepo.ListForOrganization(c.Request.Context(), c.Param("id"),
+			c.Query("namespace"), c.Query("name"), c.Query("system"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list module approvals"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"approvals": approvals})
+	}
+}
+
+// @Summary      Approve module version for organization
+// @Description  Marks a module version as approved for the organization. Re-approving refreshes the approver, timestamp, and notes.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id         path  string                       true   "Organization ID"
+// @Param        namespace  path  string                       true   "Module namespace"
+// @Param        name       path  string                       true   "Module name"
+// @Param        system     path  string                       true   "Module system"
+// @Param        version    path  string                       true   "Module version"
+// @Param        body       body  ApproveModuleVersionRequest  false  "Approval notes"
+// @Success      200  {object}  models.ModuleVersionApproval
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Organization, module, or version not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/module-approvals/{namespace}/{name}/{system}/{version} [put]
+// ApproveVersionHandler approves a module version for an organization.
+// PUT /api/v1/organizations/:id/module-approvals/:namespace/:name/:system/:version
+func (h *ModuleApprovalHandlers) ApproveVersionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ApproveModuleVersionRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+				return
+			}
+		}
+
+		org, err := h.orgRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		module, version, ok := h.lookupVersion(c)
+		if !ok {
+			return
+		}
+
+		approval := &models.ModuleVersionApproval{
+			ModuleVersionID: version.ID,
+			OrganizationID:  org.ID,
+			Namespace:       module.Namespace,
+			Name:            module.Name,
+			System:          module.System,
+			Version:         version.Version,
+		}
+		if uid := c.GetString("user_id"); uid != "" {
+			approval.ApprovedBy = &uid
+		}
+		if notes := strings.TrimSpace(req.Notes); notes != "" {
+			approval.Notes = &notes
+		}
+		if err := h.approvalRepo.Approve(c.Request.Context(), approval); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve module version"})
+			return
+		}
+		c.JSON(http.StatusOK, approval)
+	}
+}
+
+// @Summary      Revoke module version approval
+// @Description  Removes the organization's approval of a module version.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id         path  string  true  "Organization ID"
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Module system"
+// @Param        version    path  string  true  "Module version"
+// @Success      200  {object}  map[string]interface{}  "{\"message\": \"...\"}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Module, version, or approval not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/module-approvals/{namespace}/{name}/{system}/{version} [delete]
+// RevokeVersionHandler removes an organization's approval of a module version.
+// DELETE /api/v1/organizations/:id/module-approvals/:namespace/:name/:system/:version
+func (h *ModuleApprovalHandlers) RevokeVersionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, version, ok := h.lookupVersion(c)
+		if !ok {
+			return
+		}
+
+		removed, err := h.approvalRepo.Revoke(c.Request.Context(), version.ID, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke module version approval"})
+			return
+		}
+		if !removed {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Approval not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Module version approval revoked"})
+	}
+}
+
+// lookupVersion resolves the :namespace/:name/:system/:version path to a
+// module version, writing the error response and returning ok=false when it
+// cannot.
+func (h *ModuleApprovalHandlers) lookupVersion(c *gin.Context) (*models.Module, *models.ModuleVersion, bool) {
+	ctx := c.Request.Context()
+	org, err := h.registryOrgRepo.GetDefaultOrganization(ctx)
+	if err != nil || org == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return nil, nil, false
+	}
+	module, err := h.moduleRepo.GetModule(ctx, org.ID, c.Param("namespace"), c.Param("name"), c.Param("system"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query module"})
+		return nil, nil, false
+	}
+	if module == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return nil, nil, false
+	}
+	version, err := h.moduleRepo.GetVersion(ctx, module.ID, c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query module version"})
+		return nil, nil, false
+	}
+	if version == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module version not found"})
+		return nil, nil, false
+	}
+	return module, version, true
+}
@@ -0,0 +1,175 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var modulePolicyCols = []string{"organization_id", "approved_only", "default_max_versions", "default_version_cap_mode", "alias_duplicate_versions", "created_at", "updated_at"}
+
+func newModuleApprovalRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewModuleApprovalHandlers(db, db, repositories.NewModuleApprovalRepository(db))
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Next()
+	})
+	r.GET("/organizations/:id/module-policy", h.GetPolicyHandler())
+	r.PUT("/organizations/:id/module-policy", h.UpdatePolicyHandler())
+	r.GET("/organizations/:id/module-approvals", h.ListApprovalsHandler())
+	r.PUT("/organizations/:id/module-approvals/:namespace/:name/:system/:version", h.ApproveVersionHandler())
+	r.DELETE("/organizations/:id/module-approvals/:namespace/:name/:system/:version", h.RevokeVersionHandler())
+	return mock, r
+}
+
+func TestGetModulePolicy_DefaultsWhenUnset(t *testing.T) {
+	mock, r := newModuleApprovalRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_module_policies").WillReturnRows(sqlmock.NewRows(modulePolicyCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1/module-policy", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	policy, _ := getJSON(w)["policy"].(map[string]interface{})
+	if policy == nil || policy["approved_only"] != false || policy["organization_id"] != "org-1" {
+		t.Errorf("policy = %v", policy)
+	}
+}
+
+func TestUpdateModulePolicy_Success(t *testing.T) {
+	mock, r := newModuleApprovalRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows(orgSQLCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO org_module_policies").
+		WithArgs("org-1", true, nil, nil, false).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/org-1/module-policy",
+		jsonBody(map[string]interface{}{"approved_only": true})))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdateModulePolicy_InvalidVersionCap(t *testing.T) {
+	for _, body := range []map[string]interface{}{
+		{"approved_only": false, "default_version_cap_mode": "fifo"},
+		{"approved_only": false, "default_max_versions": 0},
+	} {
+		_, r := newModuleApprovalRouter(t)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/org-1/module-policy", jsonBody(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %v: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestUpdateModulePolicy_OrgNotFound(t *testing.T) {
+	mock, r := newModuleApprovalRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sqlmock.NewRows(orgSQLCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/missing/module-policy",
+		jsonBody(map[string]interface{}{"approved_only": true})))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestListModuleApprovals_PassesFilters(t *testing.T) {
+	mock, r := newModuleApprovalRouter(t)
+	mock.ExpectQuery("SELECT.*FROM module_version_approvals a").
+		WithArgs("org-1", "hashicorp", "vpc", "aws").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "module_version_id", "organization_id", "approved_by",
+			"approved_at", "notes", "namespace", "name", "system", "version"}).
+			AddRow("appr-1", "ver-1", "org-1", "user-1", time.Now(), nil, "hashicorp", "vpc", "aws", "1.0.0"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1/module-approvals?namespace=hashicorp&name=vpc&system=aws", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	if approvals, _ := getJSON(w)["approvals"].([]interface{}); len(approvals) != 1 {
+		t.Errorf("approvals = %v, want 1 entry", getJSON(w)["approvals"])
+	}
+}
+
+func TestApproveModuleVersion_Success(t *testing.T) {
+	mock, r := newModuleApprovalRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows(orgSQLCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows(orgSQLCols).AddRow("org-default", "default", "Default", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(sampleModuleRow())
+	mock.ExpectQuery("SELECT.*FROM module_versions").WillReturnRows(sampleModVersionGetRow())
+	mock.ExpectQuery("INSERT INTO module_version_approvals").
+		WithArgs("ver-1", "org-1", "user-1", "pinned for prod").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "approved_at"}).AddRow("appr-1", time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/org-1/module-approvals/hashicorp/vpc/aws/1.0.0",
+		jsonBody(map[string]interface{}{"notes": " pinned for prod "})))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	body := getJSON(w)
+	if body["id"] != "appr-1" || body["version"] != "1.0.0" || body["approved_by"] != "user-1" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestApproveModuleVersion_VersionNotFound(t *testing.T) {
+	mock, r := newModuleApprovalRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows(orgSQLCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows(orgSQLCols).AddRow("org-default", "default", "Default", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(sampleModuleRow())
+	mock.ExpectQuery("SELECT.*FROM module_versions").WillReturnRows(emptyModVersionGetRow())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/org-1/module-approvals/hashicorp/vpc/aws/9.9.9", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404; body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRevokeModuleVersionApproval(t *testing.T) {
+	for _, tc := range []struct {
+		affected int64
+		want     int
+	}{{1, http.StatusOK}, {0, http.StatusNotFound}} {
+		mock, r := newModuleApprovalRouter(t)
+		mock.ExpectQuery("SELECT.*FROM organizations").
+			WillReturnRows(sqlmock.NewRows(orgSQLCols).AddRow("org-default", "default", "Default", nil, nil, time.Now(), time.Now()))
+		mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(sampleModuleRow())
+		mock.ExpectQuery("SELECT.*FROM module_versions").WillReturnRows(sampleModVersionGetRow())
+		mock.ExpectExec("DELETE FROM module_version_approvals").
+			WithArgs("ver-1", "org-1").WillReturnResult(sqlmock.NewResult(0, tc.affected))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("DELETE", "/organizations/org-1/module-approvals/hashicorp/vpc/aws/1.0.0", nil))
+		if w.Code != tc.want {
+			t.Errorf("affected=%d: status = %d, want %d", tc.affected, w.Code, tc.want)
+		}
+	}
+}
@@ -3,16 +3,21 @@ package admin
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/analyzer"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/events"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
@@ -25,6 +30,12 @@ type ModuleAdminHandlers struct {
 	cfg            *config.Config
 	moduleDocsRepo *repositories.ModuleDocsRepository
 	scanRepo       *repositories.ModuleScanRepository
+	scmRepo        *repositories.SCMRepository    // optional; overview scm section
+	dependents     ModuleDependentsFunc           // optional; overview dependents section
+	immutability   *services.ArtifactImmutability // optional; retention check on delete
+	events         *events.Bus                    // optional; tier change events
+
+	overviewTimeout time.Duration // per-section overview budget; 0 selects defaultOverviewSectionTimeout
 }
 
 // NewModuleAdminHandlers creates a new module admin handlers instance
@@ -49,8 +60,36 @@ func (h *ModuleAdminHandlers) WithScanQueue(repo *repositories.ModuleScanReposit
 	return h
 }
 
+// WithSCM sets the SCM repository the module overview reads repository links
+// and webhook events from.
+func (h *ModuleAdminHandlers) WithSCM(repo *repositories.SCMRepository) *ModuleAdminHandlers {
+	h.scmRepo = repo
+	return h
+}
+
+// WithDependents sets the lookup behind the module overview's dependents
+// section.
+func (h *ModuleAdminHandlers) WithDependents(fn ModuleDependentsFunc) *ModuleAdminHandlers {
+	h.dependents = fn
+	return h
+}
+
+// WithImmutability sets the artifact immutability policy that delete
+// requests are checked against.
+func (h *ModuleAdminHandlers) WithImmutability(a *services.ArtifactImmutability) *ModuleAdminHandlers {
+	h.immutability = a
+	return h
+}
+
+// WithEvents sets the bus module tier changes are published on. Returns the
+// handler for chaining.
+func (h *ModuleAdminHandlers) WithEvents(bus *events.Bus) *ModuleAdminHandlers {
+	h.events = bus
+	return h
+}
+
 // @Summary      Create module record
-// @Description  Create a module record without a version file. Used by the SCM publishing flow. Requires modules:publish scope.
+// @Description  Create a module record without a version file. Used by the SCM publishing flow. Modules that are not provider-specific use the reserved "generic" system; placeholder systems such as "all" or "none" are rejected. Requires modules:publish scope.
 // @Tags         Modules
 // @Security     Bearer
 // @Accept       json
@@ -77,12 +116,16 @@ func (h *ModuleAdminHandlers) CreateModuleRecord(c *gin.Context) {
 		return
 	}
 
-	for field, val := range map[string]string{"namespace": req.Namespace, "name": req.Name, "system": req.System} {
+	for field, val := range map[string]string{"namespace": req.Namespace, "name": req.Name} {
 		if err := validation.ValidateRegistrySegment(val); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s: %v", field, err)})
 			return
 		}
 	}
+	if err := validation.ValidateModuleSystem(req.System); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid system: %v", err)})
+		return
+	}
 
 	org, err := h.orgRepo.GetDefaultOrganization(c.Request.Context())
 	if err != nil || org == nil {
@@ -125,13 +168,14 @@ func (h *ModuleAdminHandlers) CreateModuleRecord(c *gin.Context) {
 }
 
 // @Summary      Get module
-// @Description  Retrieve a module with all its versions, download counts, and metadata. No authentication required; authentication is optional and provides user context.
+// @Description  Retrieve a module with all its versions, download counts, and metadata, including its registry-assigned tier. No authentication required; authentication is optional and provides user context.
 // @Tags         Modules
 // @Produce      json
 // @Param        namespace  path  string  true  "Module namespace"
 // @Param        name       path  string  true  "Module name"
 // @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
 // @Success      200  {object}  admin.ModuleDetailResponse
+// @Success      301  "Module moved; Location is the same request at its current address"
 // @Failure      404  {object}  map[string]interface{}  "Module not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/modules/{namespace}/{name}/{system} [get]
@@ -162,43 +206,84 @@ func (h *ModuleAdminHandlers) GetModule(c *gin.Context) {
 	}
 
 	if module == nil {
+		if h.redirectMovedModule(c, orgID, namespace, name, system) {
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
 		return
 	}
 
-	// Get versions for the module
-	versions, err := h.moduleRepo.ListVersions(c.Request.Context(), module.ID)
+	versionsList, totalDownloads, err := h.moduleVersionsList(c.Request.Context(), module)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list module versions"})
 		return
 	}
 
+	resp := h.moduleSummary(c.Request.Context(), module)
+	resp["download_count"] = totalDownloads
+	resp["versions"] = versionsList
+	c.JSON(http.StatusOK, resp)
+}
+
+// moduleVersionsList returns a module's versions formatted for the detail
+// response, and their total download count.
+func (h *ModuleAdminHandlers) moduleVersionsList(ctx context.Context, module *models.Module) ([]gin.H, int64, error) {
+	versions, err := h.moduleRepo.ListVersions(ctx, module.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Archived versions stay in the admin view, flagged with when they were
+	// archived. A lookup failure just omits the flag.
+	archived, err := h.moduleRepo.ListArchivedVersions(ctx, module.ID)
+	if err != nil {
+		slog.Warn("failed to list archived module versions", "module_id", module.ID, "error", err)
+	}
+
 	// Format versions and calculate total downloads
 	versionsList := make([]gin.H, 0, len(versions))
 	var totalDownloads int64
 	for _, v := range versions {
 		totalDownloads += v.DownloadCount
-		versionData := gin.H{
-			"id":                v.ID,
-			"version":           v.Version,
-			"size_bytes":        v.SizeBytes,
-			"checksum":          v.Checksum,
-			"download_count":    v.DownloadCount,
-			"deprecated":        v.Deprecated,
-			"published_by":      v.PublishedBy,
-			"published_by_name": v.PublishedByName,
-			"created_at":        v.CreatedAt,
-		}
-		if v.DeprecatedAt != nil {
-			versionData["deprecated_at"] = v.DeprecatedAt
-		}
-		if v.DeprecationMessage != nil {
-			versionData["deprecation_message"] = v.DeprecationMessage
-		}
-		versionsList = append(versionsList, versionData)
+		versionsList = append(versionsList, moduleVersionData(v, archived))
 	}
+	return versionsList, totalDownloads, nil
+}
 
-	c.JSON(http.StatusOK, gin.H{
+// moduleVersionData formats one module version for the detail response and
+// the version listing. archived maps archived version IDs to when they were
+// archived.
+func moduleVersionData(v *models.ModuleVersion, archived map[string]time.Time) gin.H {
+	versionData := gin.H{
+		"id":                v.ID,
+		"version":           v.Version,
+		"size_bytes":        v.SizeBytes,
+		"checksum":          v.Checksum,
+		"download_count":    v.DownloadCount,
+		"deprecated":        v.Deprecated,
+		"prerelease":        validation.IsPrerelease(v.Version),
+		"published_by":      v.PublishedBy,
+		"published_by_name": v.PublishedByName,
+		"created_at":        v.CreatedAt,
+	}
+	if v.DeprecatedAt != nil {
+		versionData["deprecated_at"] = v.DeprecatedAt
+	}
+	if v.DeprecationMessage != nil {
+		versionData["deprecation_message"] = v.DeprecationMessage
+	}
+	if v.RequiredTerraformVersion != nil {
+		versionData["required_terraform_version"] = v.RequiredTerraformVersion
+	}
+	if at, ok := archived[v.ID]; ok {
+		versionData["archived_at"] = at
+	}
+	return versionData
+}
+
+// moduleSummary returns the module-level fields of the detail response.
+func (h *ModuleAdminHandlers) moduleSummary(ctx context.Context, module *models.Module) gin.H {
+	resp := gin.H{
 		"id":                  module.ID,
 		"organization_id":     module.OrganizationID,
 		"namespace":           module.Namespace,
@@ -208,19 +293,32 @@ func (h *ModuleAdminHandlers) GetModule(c *gin.Context) {
 		"source":              module.Source,
 		"created_by":          module.CreatedBy,
 		"created_by_name":     module.CreatedByName,
-		"download_count":      totalDownloads,
 		"deprecated":          module.Deprecated,
 		"deprecated_at":       module.DeprecatedAt,
 		"deprecation_message": module.DeprecationMessage,
 		"successor_module_id": module.SuccessorModuleID,
-		"versions":            versionsList,
 		"created_at":          module.CreatedAt,
 		"updated_at":          module.UpdatedAt,
-	})
+	}
+	if include := h.loadIncludePrerelease(ctx, module.ID); include != nil {
+		resp["include_prerelease"] = *include
+	}
+	h.loadVersionCapSettings(ctx, module)
+	if module.MaxVersions != nil {
+		resp["max_versions"] = *module.MaxVersions
+	}
+	if module.VersionCapMode != nil {
+		resp["version_cap_mode"] = *module.VersionCapMode
+	}
+	h.loadTier(ctx, module)
+	if module.Tier != "" {
+		resp["tier"] = module.Tier
+	}
+	return resp
 }
 
 // @Summary      Get module version
-// @Description  Retrieve a single module version's metadata, including deprecation fields. No authentication required; authentication is optional and provides user context.
+// @Description  Retrieve a single module version's metadata, including deprecation fields and the changelog excerpt. No authentication required; authentication is optional and provides user context.
 // @Tags         Modules
 // @Produce      json
 // @Param        namespace  path  string  true  "Module namespace"
@@ -228,6 +326,7 @@ func (h *ModuleAdminHandlers) GetModule(c *gin.Context) {
 // @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
 // @Param        version    path  string  true  "Semantic version (e.g. 1.2.3)"
 // @Success      200  {object}  models.ModuleVersion
+// @Success      301  "Module moved; Location is the same request at its current address"
 // @Failure      404  {object}  map[string]interface{}  "Module or version not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/modules/{namespace}/{name}/{system}/{version} [get]
@@ -262,6 +361,9 @@ func (h *ModuleAdminHandlers) GetModuleVersion(c *gin.Context) {
 		return
 	}
 	if module == nil {
+		if h.redirectMovedModule(c, orgID, namespace, name, system) {
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
 		return
 	}
@@ -276,6 +378,14 @@ func (h *ModuleAdminHandlers) GetModuleVersion(c *gin.Context) {
 		return
 	}
 
+	// The changelog lives outside the core version columns; a lookup failure
+	// degrades to omitting it rather than failing the whole response.
+	if changelog, err := h.moduleRepo.GetVersionChangelog(c.Request.Context(), mv.ID); err != nil {
+		slog.Warn("failed to load module version changelog", "version_id", mv.ID, "error", err)
+	} else {
+		mv.Changelog = changelog
+	}
+
 	c.JSON(http.StatusOK, mv)
 }
 
@@ -289,6 +399,7 @@ func (h *ModuleAdminHandlers) GetModuleVersion(c *gin.Context) {
 // @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
 // @Success      200  {object}  admin.MessageResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "A version is retained by artifact immutability"
 // @Failure      404  {object}  map[string]interface{}  "Module not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/modules/{namespace}/{name}/{system} [delete]
@@ -330,11 +441,21 @@ func (h *ModuleAdminHandlers) DeleteModule(c *gin.Context) {
 		return
 	}
 
+	// Refuse before deleting anything if any version is still retained
+	for _, v := range versions {
+		if !immutableDeleteAllowed(c, h.immutability, module.OrganizationID, v.CreatedAt) {
+			return
+		}
+	}
+
 	// Delete files from storage for each version
 	for _, v := range versions {
 		if v.StoragePath != "" {
 			// Try to delete from storage (ignore errors - file might not exist)
-			_ = h.storageBackend.Delete(c.Request.Context(), v.StoragePath)
+			if err := h.storageBackend.Delete(c.Request.Context(), v.StoragePath); errors.Is(err, storage.ErrArtifactImmutable) {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
 		}
 	}
 
@@ -353,7 +474,7 @@ func (h *ModuleAdminHandlers) DeleteModule(c *gin.Context) {
 }
 
 // @Summary      Delete module version
-// @Description  Delete a specific version of a module, including its file in storage. Requires modules:delete scope.
+// @Description  Delete a specific version of a module, including its file in storage. A version created as an alias of an identical earlier version shares that version's file, which is kept. A version that other versions alias cannot be deleted until they are (409). Requires modules:delete scope.
 // @Tags         Modules
 // @Security     Bearer
 // @Produce      json
@@ -363,7 +484,9 @@ func (h *ModuleAdminHandlers) DeleteModule(c *gin.Context) {
 // @Param        version    path  string  true  "Semantic version (e.g. 1.2.3)"
 // @Success      200  {object}  admin.MessageResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Version is retained by artifact immutability"
 // @Failure      404  {object}  map[string]interface{}  "Module or version not found"
+// @Failure      409  {object}  map[string]interface{}  "Other versions are aliases of this version (aliases lists them)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/modules/{namespace}/{name}/{system}/versions/{version} [delete]
 // DeleteVersion deletes a specific version of a module
@@ -410,9 +533,31 @@ func (h *ModuleAdminHandlers) DeleteVersion(c *gin.Context) {
 		return
 	}
 
-	// Delete file from storage
-	if versionRecord.StoragePath != "" {
-		_ = h.storageBackend.Delete(c.Request.Context(), versionRecord.StoragePath)
+	if !immutableDeleteAllowed(c, h.immutability, module.OrganizationID, versionRecord.CreatedAt) {
+		return
+	}
+
+	// Aliases share this version's archive, so it stays until they are gone.
+	aliasOf, aliases, err := h.moduleRepo.GetVersionAliasing(c.Request.Context(), versionRecord.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check version aliases"})
+		return
+	}
+	if len(aliases) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   fmt.Sprintf("Version %s is aliased by other versions; delete them first", version),
+			"aliases": aliases,
+		})
+		return
+	}
+
+	// Delete file from storage; an alias's file belongs to the version it
+	// points at.
+	if versionRecord.StoragePath != "" && aliasOf == "" {
+		if err := h.storageBackend.Delete(c.Request.Context(), versionRecord.StoragePath); errors.Is(err, storage.ErrArtifactImmutable) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 	}
 
 	// Delete version from database
@@ -598,7 +743,7 @@ func (h *ModuleAdminHandlers) UndeprecateVersion(c *gin.Context) {
 
 // UpdateModuleRecord handler
 // @Summary      Update module record
-// @Description  Update a module record's namespace, description, or source URL. Requires modules:write scope.
+// @Description  Update a module record's namespace, description, source URL, include_prerelease setting (list pre-release versions in the protocol versions response by default), or version cap (max_versions, 0 to clear; version_cap_mode strict or rolling, "" to clear). Requires modules:write scope.
 // @Tags         Modules
 // @Security     Bearer
 // @Accept       json
@@ -621,11 +766,31 @@ func (h *ModuleAdminHandlers) UpdateModuleRecord(c *gin.Context) {
 		Description *string `json:"description"`
 		Source      *string `json:"source"`
 		Namespace   *string `json:"namespace"`
+		// IncludePrerelease opts the module back into listing pre-release
+		// versions by default.
+		IncludePrerelease *bool `json:"include_prerelease"`
+		// MaxVersions caps how many versions the module keeps listed; 0
+		// clears the module's own cap so the organization default applies.
+		MaxVersions *int `json:"max_versions"`
+		// VersionCapMode is "strict" or "rolling"; "" clears it.
+		VersionCapMode *string `json:"version_cap_mode"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.MaxVersions != nil && *req.MaxVersions < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_versions must not be negative"})
+		return
+	}
+	if req.VersionCapMode != nil {
+		switch *req.VersionCapMode {
+		case "", models.VersionCapModeStrict, models.VersionCapModeRolling:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "version_cap_mode must be strict or rolling"})
+			return
+		}
+	}
 
 	module, err := h.moduleRepo.GetModuleByID(c.Request.Context(), id)
 	if err != nil {
@@ -668,6 +833,34 @@ func (h *ModuleAdminHandlers) UpdateModuleRecord(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update module"})
 		return
 	}
+	if req.IncludePrerelease != nil {
+		if err := h.moduleRepo.SetIncludePrerelease(c.Request.Context(), module.ID, *req.IncludePrerelease); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update module"})
+			return
+		}
+		module.IncludePrerelease = req.IncludePrerelease
+	} else {
+		module.IncludePrerelease = h.loadIncludePrerelease(c.Request.Context(), module.ID)
+	}
+	h.loadVersionCapSettings(c.Request.Context(), module)
+	if req.MaxVersions != nil || req.VersionCapMode != nil {
+		if req.MaxVersions != nil {
+			module.MaxVersions = req.MaxVersions
+			if *req.MaxVersions == 0 {
+				module.MaxVersions = nil
+			}
+		}
+		if req.VersionCapMode != nil {
+			module.VersionCapMode = req.VersionCapMode
+			if *req.VersionCapMode == "" {
+				module.VersionCapMode = nil
+			}
+		}
+		if err := h.moduleRepo.SetVersionCapSettings(c.Request.Context(), module.ID, module.MaxVersions, module.VersionCapMode); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update module"})
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, module)
 }
@@ -697,9 +890,105 @@ func (h *ModuleAdminHandlers) GetModuleByIDRecord(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "module not found"})
 		return
 	}
+	module.IncludePrerelease = h.loadIncludePrerelease(c.Request.Context(), module.ID)
+	h.loadVersionCapSettings(c.Request.Context(), module)
+	h.loadTier(c.Request.Context(), module)
 	c.JSON(http.StatusOK, module)
 }
 
+// @Summary      List module versions
+// @Description  List a module's versions, archived ones included, with server-side filtering, sorting and pagination. pagination.total is the number of versions matching the filters. Requires modules:read scope.
+// @Tags         Modules
+// @Security     Bearer
+// @Produce      json
+// @Param        id               path   string  true   "Module record UUID"
+// @Param        deprecated       query  bool    false  "Only deprecated (true) or non-deprecated (false) versions"
+// @Param        archived         query  bool    false  "Only archived (true) or non-archived (false) versions"
+// @Param        published_after  query  string  false  "Only versions published after this RFC 3339 timestamp or YYYY-MM-DD date"
+// @Param        published_by     query  string  false  "Only versions published by this user ID"
+// @Param        constraint       query  string  false  "Only versions matching this version constraint (e.g. ~> 1.4)"
+// @Param        sort             query  string  false  "semver (default) or published"
+// @Param        order            query  string  false  "desc (default) or asc"
+// @Param        page             query  int     false  "Page number (default 1)"
+// @Param        per_page         query  int     false  "Items per page, max 100 (default 20)"
+// @Success      200  {object}  admin.ModuleVersionListResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid filter"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Module not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/modules/{id}/versions [get]
+// ListModuleVersions lists a module's versions matching the query filters
+// GET /api/v1/admin/modules/:id/versions
+func (h *ModuleAdminHandlers) ListModuleVersions(c *gin.Context) {
+	filter, pagination, err := parseVersionListQuery(c, true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	module, err := h.moduleRepo.GetModuleByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get module"})
+		return
+	}
+	if module == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module not found"})
+		return
+	}
+
+	versions, total, err := h.moduleRepo.ListVersionsFiltered(c.Request.Context(), module.ID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list module versions"})
+		return
+	}
+	archived, err := h.moduleRepo.ListArchivedVersions(c.Request.Context(), module.ID)
+	if err != nil {
+		slog.Warn("failed to list archived module versions", "module_id", module.ID, "error", err)
+	}
+
+	versionsList := make([]gin.H, 0, len(versions))
+	for _, v := range versions {
+		versionsList = append(versionsList, moduleVersionData(v, archived))
+	}
+	pagination.Total = int64(total)
+	c.JSON(http.StatusOK, gin.H{"versions": versionsList, "pagination": pagination})
+}
+
+// loadIncludePrerelease returns the module's include_prerelease setting. The
+// setting lives outside the core module columns; a lookup failure degrades to
+// omitting it (nil) rather than failing the whole response.
+func (h *ModuleAdminHandlers) loadIncludePrerelease(ctx context.Context, moduleID string) *bool {
+	include, err := h.moduleRepo.GetIncludePrerelease(ctx, moduleID)
+	if err != nil {
+		slog.Warn("failed to load module pre-release setting", "module_id", moduleID, "error", err)
+		return nil
+	}
+	return &include
+}
+
+// loadVersionCapSettings fills in the module's own version cap settings. Like
+// loadIncludePrerelease, a lookup failure leaves them unset.
+func (h *ModuleAdminHandlers) loadVersionCapSettings(ctx context.Context, module *models.Module) {
+	maxVersions, mode, err := h.moduleRepo.GetVersionCapSettings(ctx, module.ID)
+	if err != nil {
+		slog.Warn("failed to load module version cap settings", "module_id", module.ID, "error", err)
+		return
+	}
+	module.MaxVersions = maxVersions
+	module.VersionCapMode = mode
+}
+
+// loadTier fills in the module's tier. Like loadIncludePrerelease, a lookup
+// failure leaves it unset.
+func (h *ModuleAdminHandlers) loadTier(ctx context.Context, module *models.Module) {
+	tiers, err := h.moduleRepo.ListTiers(ctx, []string{module.ID})
+	if err != nil {
+		slog.Warn("failed to load module tier", "module_id", module.ID, "error", err)
+		return
+	}
+	module.Tier = tiers[module.ID]
+}
+
 // DeprecateModuleRequest represents a request to deprecate an entire module.
 // Message is optional; SuccessorModuleID optionally points to a replacement module.
 type DeprecateModuleRequest struct {
@@ -836,6 +1125,113 @@ func (h *ModuleAdminHandlers) UndeprecateModule(c *gin.Context) {
 	})
 }
 
+// redirectMovedModule redirects a detail request for the old address of a
+// moved module to its current address and reports whether it did. A failed
+// lookup is logged and treated as "not moved".
+func (h *ModuleAdminHandlers) redirectMovedModule(c *gin.Context, orgID, namespace, name, system string) bool {
+	rd, err := h.moduleRepo.GetModuleRedirect(c.Request.Context(), orgID, namespace, name, system)
+	if err != nil {
+		slog.Warn("failed to look up module redirect", "namespace", namespace, "name", name, "system", system, "error", err)
+		return false
+	}
+	if rd == nil {
+		return false
+	}
+	target := rd.MovedPath(c.Request.URL.Path)
+	if target == "" {
+		return false
+	}
+	if q := c.Request.URL.RawQuery; q != "" {
+		target += "?" + q
+	}
+	c.Redirect(http.StatusMovedPermanently, target)
+	return true
+}
+
+// ChangeModuleSystemRequest is the body of POST .../change-system.
+type ChangeModuleSystemRequest struct {
+	System string `json:"system" binding:"required"`
+}
+
+// @Summary      Change module system
+// @Description  Move a module to another system, e.g. from an ad-hoc placeholder such as "all" or "none" to the reserved "generic" system for tool-agnostic modules. Versions, settings and SCM links move with the module; the old address redirects (301) to the new one on the protocol and detail endpoints. Requires modules:write scope.
+// @Tags         Modules
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        namespace  path  string                     true  "Module namespace"
+// @Param        name       path  string                     true  "Module name"
+// @Param        system     path  string                     true  "Current target system"
+// @Param        body       body  ChangeModuleSystemRequest  true  "New system"
+// @Success      200  {object}  models.Module
+// @Failure      400  {object}  map[string]interface{}  "Invalid or unchanged system"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Module not found"
+// @Failure      409  {object}  map[string]interface{}  "A module already exists at the new address"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/modules/{namespace}/{name}/{system}/change-system [post]
+// ChangeModuleSystem moves a module to another system, leaving a redirect at
+// its old address.
+// POST /api/v1/modules/:namespace/:name/:system/change-system
+func (h *ModuleAdminHandlers) ChangeModuleSystem(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	system := c.Param("system")
+
+	var req ChangeModuleSystemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.ValidateModuleSystem(req.System); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid system: %v", err)})
+		return
+	}
+	if req.System == system {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "module already uses system " + system})
+		return
+	}
+
+	org, err := h.orgRepo.GetDefaultOrganization(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return
+	}
+
+	var orgID string
+	if org != nil {
+		orgID = org.ID
+	}
+
+	module, err := h.moduleRepo.GetModule(c.Request.Context(), orgID, namespace, name, system)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get module"})
+		return
+	}
+	if module == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+
+	// Check for conflict with an existing module at the new address.
+	existing, err := h.moduleRepo.GetModule(c.Request.Context(), module.OrganizationID, namespace, name, req.System)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check system availability"})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "a module with the same namespace and name already exists for the target system"})
+		return
+	}
+
+	if err := h.moduleRepo.MoveModule(c.Request.Context(), module, namespace, req.System); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change module system"})
+		return
+	}
+
+	c.JSON(http.StatusOK, module)
+}
+
 // @Summary      Re-analyze module version
 // @Description  Re-download the module archive from storage and re-run the HCL analyzer to refresh terraform-docs metadata. Optionally re-queues a security scan. Requires modules:publish scope.
 // @Tags         Modules
@@ -935,6 +1331,7 @@ func (h *ModuleAdminHandlers) ReanalyzeVersion(c *gin.Context) {
 				result["docs"] = "updated"
 				result["inputs"] = len(doc.Inputs)
 				result["outputs"] = len(doc.Outputs)
+				result["submodules"] = len(doc.Submodules)
 			}
 		} else {
 			result["docs"] = "no_terraform_files"
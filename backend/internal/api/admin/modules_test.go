@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,14 +27,14 @@ var modVersionListCols = []string{
 	"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes",
 	"checksum", "readme", "published_by", "published_by_name", "download_count",
 	"deprecated", "deprecated_at", "deprecation_message", "replacement_source", "created_at",
-	"commit_sha", "tag_name", "scm_repo_id", "has_docs",
+	"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version", "has_docs",
 }
 
 var modVersionGetCols = []string{
 	"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes",
 	"checksum", "readme", "published_by", "download_count",
 	"deprecated", "deprecated_at", "deprecation_message", "replacement_source", "created_at",
-	"commit_sha", "tag_name", "scm_repo_id",
+	"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version",
 }
 
 var modCreateCols = []string{"id", "created_at", "updated_at"}
@@ -55,7 +56,7 @@ func sampleModVersionListRow() *sqlmock.Rows {
 	return sqlmock.NewRows(modVersionListCols).
 		AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/vpc/aws/vpc-1.0.0.tar.gz", "default",
 			int64(1024), "abc123", nil, nil, nil, int64(5), false, nil, nil, nil, time.Now(),
-			nil, nil, nil, false)
+			nil, nil, nil, nil, false)
 }
 
 func emptyModVersionListRows() *sqlmock.Rows {
@@ -66,7 +67,7 @@ func sampleModVersionGetRow() *sqlmock.Rows {
 	return sqlmock.NewRows(modVersionGetCols).
 		AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/vpc/aws/vpc-1.0.0.tar.gz", "default",
 			int64(1024), "abc123", nil, nil, int64(5), false, nil, nil, nil, time.Now(),
-			nil, nil, nil)
+			nil, nil, nil, nil)
 }
 
 func emptyModVersionGetRow() *sqlmock.Rows {
@@ -97,8 +98,10 @@ func newModuleRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
 	r.DELETE("/modules/:namespace/:name/:system/versions/:version/deprecate", h.UndeprecateVersion)
 	r.GET("/modules/id/:id", h.GetModuleByIDRecord)
 	r.PUT("/modules/id/:id", h.UpdateModuleRecord)
+	r.GET("/modules/id/:id/versions", h.ListModuleVersions)
 	r.POST("/modules/:namespace/:name/:system/deprecate", h.DeprecateModule)
 	r.DELETE("/modules/:namespace/:name/:system/deprecate", h.UndeprecateModule)
+	r.POST("/modules/:namespace/:name/:system/change-system", h.ChangeModuleSystem)
 
 	return mock, r
 }
@@ -600,6 +603,7 @@ func TestDeleteModuleVersion_DeleteDBError(t *testing.T) {
 		WillReturnRows(sampleModuleRow())
 	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id").
 		WillReturnRows(sampleModVersionGetRow())
+	expectVersionAliasing(mock, "")
 	mock.ExpectExec("DELETE FROM module_versions").
 		WillReturnError(errDB)
 
@@ -651,6 +655,7 @@ func TestDeleteModuleVersion_Success(t *testing.T) {
 		WillReturnRows(sampleModuleRow())
 	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id").
 		WillReturnRows(sampleModVersionGetRow())
+	expectVersionAliasing(mock, "")
 	mock.ExpectExec("DELETE FROM module_versions").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -662,6 +667,38 @@ func TestDeleteModuleVersion_Success(t *testing.T) {
 	}
 }
 
+func TestDeleteModuleVersion_AliasedConflict(t *testing.T) {
+	mock, r := newModuleRouter(t)
+
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").
+		WillReturnRows(sampleModuleRow())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id").
+		WillReturnRows(sampleModVersionGetRow())
+	expectVersionAliasing(mock, "", "1.0.1", "1.0.2")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/modules/hashicorp/vpc/aws/versions/1.0.0", nil))
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"aliases":["1.0.1","1.0.2"]`) {
+		t.Errorf("body = %s, want the aliasing versions listed", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// expectVersionAliasing queues the alias lookup DeleteVersion runs before
+// removing a version.
+func expectVersionAliasing(mock sqlmock.Sqlmock, aliasOf string, aliases ...string) {
+	mock.ExpectQuery("SELECT COALESCE\\(v.alias_of").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_of", "aliases"}).
+			AddRow(aliasOf, "{"+strings.Join(aliases, ",")+"}"))
+}
+
 // ---------------------------------------------------------------------------
 // DeprecateVersion (module) tests
 // ---------------------------------------------------------------------------
@@ -966,6 +1003,21 @@ func TestGetModuleByIDRecord_Success(t *testing.T) {
 	}
 }
 
+func TestGetModuleByIDRecord_IncludePrerelease(t *testing.T) {
+	mock, r := newModuleRouter(t)
+	mock.ExpectQuery("SELECT.*FROM modules").WithArgs("mod-1").WillReturnRows(sampleModuleRow())
+	mock.ExpectQuery("SELECT include_prerelease FROM modules").WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"include_prerelease"}).AddRow(true))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/id/mod-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"include_prerelease":true`) {
+		t.Errorf("body missing include_prerelease: %s", w.Body.String())
+	}
+}
+
 // ---------------------------------------------------------------------------
 // UpdateModuleRecord tests
 // ---------------------------------------------------------------------------
@@ -1025,6 +1077,26 @@ func TestUpdateModuleRecord_Success(t *testing.T) {
 	}
 }
 
+func TestUpdateModuleRecord_IncludePrerelease(t *testing.T) {
+	mock, r := newModuleRouter(t)
+	mock.ExpectQuery("SELECT.*FROM modules").WithArgs("mod-1").WillReturnRows(sampleModuleRow())
+	mock.ExpectQuery("UPDATE modules").WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+	mock.ExpectExec("UPDATE modules SET include_prerelease").WithArgs("mod-1", true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/modules/id/mod-1",
+		jsonBody(map[string]bool{"include_prerelease": true})))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"include_prerelease":true`) {
+		t.Errorf("body missing include_prerelease: %s", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
 func TestUpdateModuleRecord_NamespaceChange_Success(t *testing.T) {
 	mock, r := newModuleRouter(t)
 	mock.ExpectQuery("SELECT.*FROM modules").WithArgs("mod-1").WillReturnRows(sampleModuleRow())
@@ -1210,3 +1282,185 @@ func TestUndeprecateModule_DBError(t *testing.T) {
 		t.Errorf("status = %d, want 500", w.Code)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// ListModuleVersions tests
+// ---------------------------------------------------------------------------
+
+func TestListModuleVersions_InvalidFilters(t *testing.T) {
+	_, r := newModuleRouter(t)
+	for _, query := range []string{
+		"constraint=not-a-constraint",
+		"deprecated=maybe",
+		"published_after=yesterday",
+		"sort=downloads",
+		"order=up",
+		"yanked=true",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/id/mod-1/versions?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestListModuleVersions_NotFound(t *testing.T) {
+	mock, r := newModuleRouter(t)
+	mock.ExpectQuery("SELECT.*FROM modules").WithArgs("mod-999").WillReturnRows(emptyModuleRow())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/id/mod-999/versions", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestListModuleVersions_ConstraintAndPagination(t *testing.T) {
+	mock, r := newModuleRouter(t)
+	mock.ExpectQuery("SELECT.*FROM modules").WithArgs("mod-1").WillReturnRows(sampleModuleRow())
+	rows := sqlmock.NewRows(modVersionListCols)
+	for _, v := range []string{"1.3.0", "1.4.0", "1.5.0", "2.0.0"} {
+		rows.AddRow("ver-"+v, "mod-1", v, "modules/hashicorp/vpc/aws/vpc-"+v+".tar.gz", "default",
+			int64(1024), "abc123", nil, nil, nil, int64(5), true, nil, nil, nil, time.Now(),
+			nil, nil, nil, nil, false)
+	}
+	mock.ExpectQuery("SELECT.*FROM module_versions").WithArgs("mod-1", true).WillReturnRows(rows)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/id/mod-1/versions?deprecated=true&constraint=~>1.4&per_page=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"version":"1.5.0"`) || strings.Contains(body, `"version":"1.4.0"`) {
+		t.Errorf("expected only 1.5.0 on the first page, got %s", body)
+	}
+	if !strings.Contains(body, `"total":2`) {
+		t.Errorf("expected total 2 for the filter, got %s", body)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ChangeModuleSystem tests
+// ---------------------------------------------------------------------------
+
+func TestCreateModuleRecord_PlaceholderSystemRejected(t *testing.T) {
+	_, r := newModuleRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/create",
+		jsonBody(map[string]string{"namespace": "acme", "name": "policies", "system": "none"})))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "generic") {
+		t.Errorf("body = %s, want a hint to use generic", w.Body.String())
+	}
+}
+
+func TestChangeModuleSystem_Success(t *testing.T) {
+	mock, r := newModuleRouter(t)
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").
+		WithArgs("", "hashicorp", "vpc", "aws").
+		WillReturnRows(sampleModuleRow())
+	mock.ExpectQuery("SELECT.*FROM modules").
+		WithArgs("org-1", "hashicorp", "vpc", "generic").
+		WillReturnRows(emptyModuleRow())
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE modules").
+		WithArgs("mod-1", "hashicorp", "generic").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+	mock.ExpectExec("DELETE FROM module_address_redirects").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO module_address_redirects").
+		WithArgs("org-1", "hashicorp", "vpc", "aws", "mod-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE module_scm_repos").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/hashicorp/vpc/aws/change-system",
+		jsonBody(map[string]string{"system": "generic"})))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"system":"generic"`) {
+		t.Errorf("body = %s, want the module under its new system", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestChangeModuleSystem_InvalidSystem(t *testing.T) {
+	_, r := newModuleRouter(t)
+
+	for _, system := range []string{"all", "AWS", "aws"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/hashicorp/vpc/aws/change-system",
+			jsonBody(map[string]string{"system": system})))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("system %q: status = %d, want 400", system, w.Code)
+		}
+	}
+}
+
+func TestChangeModuleSystem_ModuleNotFound(t *testing.T) {
+	mock, r := newModuleRouter(t)
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").
+		WillReturnRows(emptyModuleRow())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/hashicorp/vpc/aws/change-system",
+		jsonBody(map[string]string{"system": "generic"})))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestChangeModuleSystem_Conflict(t *testing.T) {
+	mock, r := newModuleRouter(t)
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").
+		WillReturnRows(sampleModuleRow())
+	mock.ExpectQuery("SELECT.*FROM modules").
+		WillReturnRows(sampleModuleRow())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/hashicorp/vpc/aws/change-system",
+		jsonBody(map[string]string{"system": "generic"})))
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", w.Code)
+	}
+}
+
+func TestGetModule_MovedModuleRedirects(t *testing.T) {
+	mock, r := newModuleRouter(t)
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").
+		WillReturnRows(emptyModuleRow())
+	mock.ExpectQuery("SELECT.*FROM module_address_redirects").
+		WithArgs("", "hashicorp", "vpc", "all").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "organization_id", "namespace", "name", "system", "module_id", "created_at",
+			"namespace", "name", "system",
+		}).AddRow("rd-1", "org-1", "hashicorp", "vpc", "all", "mod-1", time.Now(), "hashicorp", "vpc", "generic"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/hashicorp/vpc/all", nil))
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want 301: body=%s", w.Code, w.Body.String())
+	}
+	if loc := w.Header().Get("Location"); loc != "/modules/hashicorp/vpc/generic" {
+		t.Errorf("Location = %q", loc)
+	}
+}
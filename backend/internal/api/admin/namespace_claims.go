@@ -0,0 +1,520 @@
+// namespace_claims.go implements self-service namespace claim requests: an
+// organization member asks for a namespace, and an admin approves or rejects
+// the request. services.NamespaceClaims applies the automatic rules (reserved
+// namespaces, verified-domain auto-approval) and writes the ownership row on
+// approval. Admins are notified of new pending requests; requesters are
+// emailed when their request is decided.
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/notify"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// domainPattern matches a lowercase DNS name with at least two labels.
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// NamespaceClaimHandlers serves the namespace claim request endpoints and the
+// organization verified-domain endpoints.
+type NamespaceClaimHandlers struct {
+	orgRepo  *repositories.OrganizationRepository
+	userRepo *repositories.UserRepository
+	requests *repositories.NamespaceClaimRequestRepository
+	claims   *services.NamespaceClaims
+
+	// notifCfg, cveCfg, and mailer back the admin approval_pending email and
+	// the decision email sent to requesters. Set via WithNotifications; nil
+	// until then, in which case no email is sent.
+	notifCfg *config.NotificationsConfig
+	cveCfg   *config.CVEConfig
+	mailer   *notify.Mailer
+	// notifier fans approval_pending out to admin-configured notification
+	// channels. Set via WithNotifier; nil is a no-op.
+	notifier *notify.Notifier
+}
+
+// NewNamespaceClaimHandlers constructs a NamespaceClaimHandlers. identityDB
+// backs organizations and users; requests runs on the registry's own
+// connection.
+func NewNamespaceClaimHandlers(identityDB *sql.DB, requests *repositories.NamespaceClaimRequestRepository, claims *services.NamespaceClaims) *NamespaceClaimHandlers {
+	return &NamespaceClaimHandlers{
+		orgRepo:  repositories.NewOrganizationRepository(identityDB),
+		userRepo: repositories.NewUserRepository(identityDB),
+		requests: requests,
+		claims:   claims,
+	}
+}
+
+// WithNotifications wires in the shared notifications/CVE config so new
+// pending requests email the admin recipients and decisions email the
+// requester. Returns the handler for chaining.
+func (h *NamespaceClaimHandlers) WithNotifications(notifCfg *config.NotificationsConfig, cveCfg *config.CVEConfig) *NamespaceClaimHandlers {
+	h.notifCfg = notifCfg
+	h.cveCfg = cveCfg
+	h.mailer = notify.New(&notifCfg.SMTP)
+	return h
+}
+
+// WithNotifier wires in the channel notifier for approval_pending. Returns
+// the handler for chaining.
+func (h *NamespaceClaimHandlers) WithNotifier(n *notify.Notifier) *NamespaceClaimHandlers {
+	h.notifier = n
+	return h
+}
+
+// SubmitNamespaceClaimRequest is the body of POST /namespaces/:namespace/claim.
+type SubmitNamespaceClaimRequest struct {
+	OrganizationID string `json:"organization_id" binding:"required"`
+	Reason         string `json:"reason"`
+}
+
+// DecideNamespaceClaimRequest is the body of the approve and reject
+// endpoints.
+type DecideNamespaceClaimRequest struct {
+	Notes string `json:"notes"`
+}
+
+// NamespaceClaimDecisionResponse is a request after an admin decision.
+type NamespaceClaimDecisionResponse struct {
+	*models.NamespaceClaimRequest
+	// Superseded lists the other organizations' pending requests for the
+	// namespace that an approval rejected.
+	Superseded []*models.NamespaceClaimRequest `json:"superseded,omitempty"`
+}
+
+// SetVerifiedDomainRequest is the body of PUT /organizations/:id/verified-domain.
+type SetVerifiedDomainRequest struct {
+	Domain string `json:"domain" binding:"required"`
+}
+
+// contextUserID returns the authenticated user's ID, or nil for principals
+// without one.
+func contextUserID(c *gin.Context) *string {
+	if id := c.GetString("user_id"); id != "" {
+		return &id
+	}
+	return nil
+}
+
+func optionalNotes(notes string) *string {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return nil
+	}
+	return &notes
+}
+
+// @Summary      Request a namespace
+// @Description  Submits a claim for an unowned namespace on behalf of an organization the caller belongs to (admins may claim for any organization). The request is rejected immediately when the namespace is reserved (hashicorp, terraform, or a configured pattern), approved immediately when auto-approval is enabled and the namespace matches the organization's verified domain, and otherwise left pending for an admin. The status field of the response reports the outcome.
+// @Tags         Namespaces
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        namespace  path  string                       true  "Namespace"
+// @Param        body       body  SubmitNamespaceClaimRequest  true  "Claim"
+// @Success      201  {object}  models.NamespaceClaimRequest
+// @Failure      400  {object}  map[string]interface{}  "Invalid namespace or request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Caller is not a member of the organization"
+// @Failure      404  {object}  map[string]interface{}  "Organization not found"
+// @Failure      409  {object}  map[string]interface{}  "Namespace already claimed or a request is already pending"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/namespaces/{namespace}/claim [post]
+// SubmitClaimHandler submits a namespace claim request.
+// POST /api/v1/namespaces/:namespace/claim
+func (h *NamespaceClaimHandlers) SubmitClaimHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		namespace := c.Param("namespace")
+		if err := validation.ValidateRegistrySegment(namespace); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid namespace: %v", err)})
+			return
+		}
+		var req SubmitNamespaceClaimRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "organization_id is required"})
+			return
+		}
+		if _, err := uuid.Parse(req.OrganizationID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization_id"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		org, err := h.orgRepo.GetByID(ctx, req.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		userID := contextUserID(c)
+		scopes, _ := c.Get("scopes")
+		scopeList, _ := scopes.([]string)
+		if !auth.HasScope(scopeList, auth.ScopeAdmin) {
+			if userID == nil {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Namespace claims must be made by an organization member"})
+				return
+			}
+			isMember, _, err := h.orgRepo.CheckMembership(ctx, org.ID, *userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check organization membership"})
+				return
+			}
+			if !isMember {
+				c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this organization"})
+				return
+			}
+		}
+
+		claim, err := h.claims.Submit(ctx, namespace, org.ID, userID, strings.TrimSpace(req.Reason))
+		switch {
+		case errors.Is(err, services.ErrNamespaceAlreadyClaimed):
+			c.JSON(http.StatusConflict, gin.H{"error": "Namespace is already claimed"})
+			return
+		case errors.Is(err, repositories.ErrClaimRequestPending):
+			c.JSON(http.StatusConflict, gin.H{"error": "A claim request for this namespace is already pending"})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit namespace claim"})
+			return
+		}
+
+		if claim.Status == models.NamespaceClaimPending {
+			h.notifyClaimPending(claim, org.Name)
+		}
+		c.JSON(http.StatusCreated, claim)
+	}
+}
+
+// @Summary      List namespace claim requests
+// @Description  Lists namespace claim requests, newest first. Requires admin scope.
+// @Tags         Namespaces
+// @Security     Bearer
+// @Produce      json
+// @Param        status           query  string  false  "Filter by status (pending, approved, rejected)"
+// @Param        organization_id  query  string  false  "Filter by organization"
+// @Success      200  {array}   models.NamespaceClaimRequest
+// @Failure      400  {object}  map[string]interface{}  "Invalid filter"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/namespace-claims [get]
+// ListClaimRequestsHandler lists namespace claim requests.
+// GET /api/v1/admin/namespace-claims
+func (h *NamespaceClaimHandlers) ListClaimRequestsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := models.NamespaceClaimStatus(c.Query("status"))
+		switch status {
+		case "", models.NamespaceClaimPending, models.NamespaceClaimApproved, models.NamespaceClaimRejected:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved, or rejected"})
+			return
+		}
+		orgID := c.Query("organization_id")
+		if orgID != "" {
+			if _, err := uuid.Parse(orgID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization_id"})
+				return
+			}
+		}
+
+		requests, err := h.requests.ListRequests(c.Request.Context(), status, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list namespace claim requests"})
+			return
+		}
+		c.JSON(http.StatusOK, requests)
+	}
+}
+
+// @Summary      Approve namespace claim request
+// @Description  Approves a pending namespace claim request: the namespace is assigned to the requesting organization, other organizations' pending requests for it are rejected, and the requesters are emailed. Requires admin scope.
+// @Tags         Namespaces
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                       true   "Claim request ID (UUID)"
+// @Param        body  body  DecideNamespaceClaimRequest  false  "Decision notes"
+// @Success      200  {object}  admin.NamespaceClaimDecisionResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Claim request not found"
+// @Failure      409  {object}  map[string]interface{}  "Request already decided or namespace owned by another organization"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/namespace-claims/{id}/approve [post]
+// ApproveClaimRequestHandler approves a namespace claim request.
+// POST /api/v1/admin/namespace-claims/:id/approve
+func (h *NamespaceClaimHandlers) ApproveClaimRequestHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, notes, ok := bindClaimDecision(c)
+		if !ok {
+			return
+		}
+		decision, err := h.claims.Approve(c.Request.Context(), id, contextUserID(c), notes)
+		if err != nil {
+			writeClaimDecisionError(c, err)
+			return
+		}
+		h.notifyRequester(decision.Request)
+		for _, r := range decision.Superseded {
+			h.notifyRequester(r)
+		}
+		c.JSON(http.StatusOK, NamespaceClaimDecisionResponse{NamespaceClaimRequest: decision.Request, Superseded: decision.Superseded})
+	}
+}
+
+// @Summary      Reject namespace claim request
+// @Description  Rejects a pending namespace claim request and emails the requester. Requires admin scope.
+// @Tags         Namespaces
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                       true   "Claim request ID (UUID)"
+// @Param        body  body  DecideNamespaceClaimRequest  false  "Decision notes"
+// @Success      200  {object}  admin.NamespaceClaimDecisionResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Claim request not found"
+// @Failure      409  {object}  map[string]interface{}  "Request already decided"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/namespace-claims/{id}/reject [post]
+// RejectClaimRequestHandler rejects a namespace claim request.
+// POST /api/v1/admin/namespace-claims/:id/reject
+func (h *NamespaceClaimHandlers) RejectClaimRequestHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, notes, ok := bindClaimDecision(c)
+		if !ok {
+			return
+		}
+		rejected, err := h.claims.Reject(c.Request.Context(), id, contextUserID(c), notes)
+		if err != nil {
+			writeClaimDecisionError(c, err)
+			return
+		}
+		h.notifyRequester(rejected)
+		c.JSON(http.StatusOK, NamespaceClaimDecisionResponse{NamespaceClaimRequest: rejected})
+	}
+}
+
+// bindClaimDecision parses the request ID and the optional notes body,
+// writing a 400 and returning false when either is invalid.
+func bindClaimDecision(c *gin.Context) (string, *string, bool) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid claim request ID"})
+		return "", nil, false
+	}
+	var req DecideNamespaceClaimRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return "", nil, false
+		}
+	}
+	return id, optionalNotes(req.Notes), true
+}
+
+func writeClaimDecisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrClaimRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Claim request not found"})
+	case errors.Is(err, repositories.ErrClaimRequestNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": "Claim request has already been decided"})
+	case errors.Is(err, repositories.ErrNamespaceOwnedByOther):
+		c.JSON(http.StatusConflict, gin.H{"error": "Namespace is already owned by another organization"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decide namespace claim request"})
+	}
+}
+
+// @Summary      Get organization verified domain
+// @Description  Returns the domain an admin has verified the organization controls. With namespace_claims.auto_approve_verified_domain enabled, claims for namespaces matching the domain's first label (acme, acme-*, acme_* for acme.com) are approved automatically.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  models.OrgVerifiedDomain
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "No verified domain"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/verified-domain [get]
+// GetVerifiedDomainHandler returns an organization's verified domain.
+// GET /api/v1/organizations/:id/verified-domain
+func (h *NamespaceClaimHandlers) GetVerifiedDomainHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		domain, err := h.requests.GetVerifiedDomain(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve verified domain"})
+			return
+		}
+		if domain == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization has no verified domain"})
+			return
+		}
+		c.JSON(http.StatusOK, domain)
+	}
+}
+
+// @Summary      Set organization verified domain
+// @Description  Records that the organization controls a domain, replacing any previous one. The registry does not verify ownership itself: the admin attests to it. Requires admin scope.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                    true  "Organization ID"
+// @Param        body  body  SetVerifiedDomainRequest  true  "Domain"
+// @Success      200  {object}  models.OrgVerifiedDomain
+// @Failure      400  {object}  map[string]interface{}  "Invalid domain"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Organization not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/verified-domain [put]
+// SetVerifiedDomainHandler sets an organization's verified domain.
+// PUT /api/v1/organizations/:id/verified-domain
+func (h *NamespaceClaimHandlers) SetVerifiedDomainHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SetVerifiedDomainRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "domain is required"})
+			return
+		}
+		domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(req.Domain)), ".")
+		if len(domain) > 253 || !domainPattern.MatchString(domain) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		org, err := h.orgRepo.GetByID(ctx, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		verified := &models.OrgVerifiedDomain{OrganizationID: org.ID, Domain: domain, VerifiedBy: contextUserID(c)}
+		if err := h.requests.SetVerifiedDomain(ctx, verified); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set verified domain"})
+			return
+		}
+		c.JSON(http.StatusOK, verified)
+	}
+}
+
+// @Summary      Remove organization verified domain
+// @Description  Removes the organization's verified domain. Requires admin scope.
+// @Tags         Organizations
+// @Security     Bearer
+// @Param        id  path  string  true  "Organization ID"
+// @Success      204  "No Content"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "No verified domain"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/verified-domain [delete]
+// DeleteVerifiedDomainHandler removes an organization's verified domain.
+// DELETE /api/v1/organizations/:id/verified-domain
+func (h *NamespaceClaimHandlers) DeleteVerifiedDomainHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deleted, err := h.requests.DeleteVerifiedDomain(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove verified domain"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization has no verified domain"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// notifyClaimPending tells the admins a claim request awaits review, through
+// the approval_pending notification channels and the admin recipients email.
+// Runs detached; failures are logged only.
+func (h *NamespaceClaimHandlers) notifyClaimPending(claim *models.NamespaceClaimRequest, orgName string) {
+	subject := fmt.Sprintf("New approval pending: namespace claim for %s", claim.Namespace)
+	body := fmt.Sprintf(
+		"A namespace claim request requires review.\n\nNamespace: %s\nOrganization: %s\nReason: %s\n\nLog in to the Terraform Registry admin UI to review and approve or reject it.\n\n— Terraform Registry",
+		claim.Namespace, orgName, claim.Reason,
+	)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		h.notifier.Notify(ctx, notify.Event{Type: notify.EventApprovalPending, Title: subject, Message: body})
+
+		if h.notifCfg == nil || !h.notifCfg.Enabled || h.notifCfg.SMTP.Host == "" || !h.notifCfg.Events.ApprovalPending {
+			return
+		}
+		recipients := h.notifCfg.Recipients
+		if len(recipients) == 0 && h.cveCfg != nil {
+			recipients = h.cveCfg.EmailRecipients
+		}
+		if len(recipients) == 0 {
+			return
+		}
+		if err := h.mailer.Send(recipients, subject, body); err != nil {
+			slog.Warn("failed to send namespace claim notification email", "error", err)
+		}
+	}()
+}
+
+// notifyRequester emails the user who submitted claim that it was decided.
+// Skipped when email notifications are not configured or the requester is
+// unknown. Runs detached; failures are logged only.
+func (h *NamespaceClaimHandlers) notifyRequester(claim *models.NamespaceClaimRequest) {
+	if h.notifCfg == nil || !h.notifCfg.Enabled || h.notifCfg.SMTP.Host == "" || claim.RequestedBy == nil {
+		return
+	}
+	subject := fmt.Sprintf("Namespace claim %s: %s", claim.Status, claim.Namespace)
+	body := fmt.Sprintf("Your request to claim the namespace %s has been %s.\n", claim.Namespace, claim.Status)
+	if claim.DecisionNotes != nil {
+		body += fmt.Sprintf("\nNotes: %s\n", *claim.DecisionNotes)
+	}
+	body += "\n— Terraform Registry"
+	userID := *claim.RequestedBy
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		user, err := h.userRepo.GetUserByID(ctx, userID)
+		if err != nil || user == nil || user.Email == "" {
+			slog.Warn("failed to look up namespace claim requester", "user_id", userID, "error", err)
+			return
+		}
+		if err := h.mailer.Send([]string{user.Email}, subject, body); err != nil {
+			slog.Warn("failed to send namespace claim decision email", "user_id", userID, "error", err)
+		}
+	}()
+}
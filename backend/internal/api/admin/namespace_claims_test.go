@@ -0,0 +1,199 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+const claimOrgID = "11111111-1111-1111-1111-111111111111"
+
+var claimRequestCols = []string{"id", "namespace", "organization_id", "requested_by", "reason", "status",
+	"auto_decided", "decided_by", "decided_at", "decision_notes", "created_at"}
+
+func newNamespaceClaimRouter(t *testing.T, scopes []string) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	requests := repositories.NewNamespaceClaimRequestRepository(db)
+	claims := services.NewNamespaceClaims(&config.NamespaceClaimsConfig{}, requests, repositories.NewNamespaceClaimRepository(db), nil)
+	h := NewNamespaceClaimHandlers(db, requests, claims)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("scopes", scopes)
+		c.Next()
+	})
+	r.POST("/namespaces/:namespace/claim", h.SubmitClaimHandler())
+	r.GET("/admin/namespace-claims", h.ListClaimRequestsHandler())
+	r.POST("/admin/namespace-claims/:id/approve", h.ApproveClaimRequestHandler())
+	r.PUT("/organizations/:id/verified-domain", h.SetVerifiedDomainHandler())
+	return mock, r
+}
+
+func postClaim(r *gin.Engine, namespace, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/namespaces/"+namespace+"/claim", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func expectClaimOrg(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols).AddRow(claimOrgID, "acme", "Acme", nil, nil, time.Now(), time.Now()))
+}
+
+func TestSubmitNamespaceClaim_NonMemberForbidden(t *testing.T) {
+	mock, r := newNamespaceClaimRouter(t, []string{"modules:read"})
+	expectClaimOrg(mock)
+	mock.ExpectQuery("FROM organization_members").WillReturnError(sql.ErrNoRows)
+
+	w := postClaim(r, "acme", `{"organization_id":"`+claimOrgID+`"}`)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403; body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSubmitNamespaceClaim_Reserved(t *testing.T) {
+	mock, r := newNamespaceClaimRouter(t, []string{"admin"})
+	expectClaimOrg(mock)
+	mock.ExpectQuery("FROM namespace_claims").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("INSERT INTO namespace_claim_requests").
+		WithArgs("hashicorp", claimOrgID, "user-1", "", "rejected", true, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "decided_at", "created_at"}).AddRow("req-1", time.Now(), time.Now()))
+
+	w := postClaim(r, "hashicorp", `{"organization_id":"`+claimOrgID+`"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body = %s", w.Code, w.Body.String())
+	}
+	var got models.NamespaceClaimRequest
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.NamespaceClaimRejected || !got.AutoDecided {
+		t.Errorf("claim = %+v, want auto-rejected", got)
+	}
+}
+
+func TestSubmitNamespaceClaim_AlreadyClaimed(t *testing.T) {
+	mock, r := newNamespaceClaimRouter(t, []string{"admin"})
+	expectClaimOrg(mock)
+	mock.ExpectQuery("FROM namespace_claims").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace", "organization_id", "claimed_by", "created_at"}).
+			AddRow("acme", "org-2", nil, time.Now()))
+
+	if w := postClaim(r, "acme", `{"organization_id":"`+claimOrgID+`"}`); w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409; body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSubmitNamespaceClaim_Validation(t *testing.T) {
+	_, r := newNamespaceClaimRouter(t, []string{"admin"})
+	if w := postClaim(r, "acme", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing organization_id: status = %d, want 400", w.Code)
+	}
+	if w := postClaim(r, "acme", `{"organization_id":"not-a-uuid"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid organization_id: status = %d, want 400", w.Code)
+	}
+	if w := postClaim(r, "bad%20name", `{"organization_id":"`+claimOrgID+`"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid namespace: status = %d, want 400", w.Code)
+	}
+}
+
+func TestListNamespaceClaimRequests(t *testing.T) {
+	mock, r := newNamespaceClaimRouter(t, []string{"admin"})
+	mock.ExpectQuery("FROM namespace_claim_requests WHERE status").
+		WithArgs("pending").
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow("req-1", "acme", claimOrgID, "user-1", "", "pending", false, nil, nil, nil, time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/namespace-claims?status=pending", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var got []models.NamespaceClaimRequest
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || len(got) != 1 {
+		t.Fatalf("response = %s, %v", w.Body.String(), err)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/namespace-claims?status=bogus", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid status filter: status = %d, want 400", w.Code)
+	}
+}
+
+func TestApproveNamespaceClaimRequest_Errors(t *testing.T) {
+	mock, r := newNamespaceClaimRouter(t, []string{"admin"})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/admin/namespace-claims/not-a-uuid/approve", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: status = %d, want 400", w.Code)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/admin/namespace-claims/"+claimOrgID+"/approve", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown request: status = %d, want 404; body = %s", w.Code, w.Body.String())
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow(claimOrgID, "acme", claimOrgID, nil, "", "approved", false, nil, time.Now(), nil, time.Now()))
+	mock.ExpectRollback()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/admin/namespace-claims/"+claimOrgID+"/approve", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("decided request: status = %d, want 409; body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSetVerifiedDomain(t *testing.T) {
+	mock, r := newNamespaceClaimRouter(t, []string{"admin"})
+	put := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/organizations/"+claimOrgID+"/verified-domain", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, bad := range []string{`{"domain":"localhost"}`, `{"domain":"-acme.com"}`, `{"domain":"acme .com"}`} {
+		if w := put(bad); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", bad, w.Code)
+		}
+	}
+
+	expectClaimOrg(mock)
+	mock.ExpectQuery("INSERT INTO org_verified_domains").
+		WithArgs(claimOrgID, "acme.com", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"verified_at"}).AddRow(time.Now()))
+	w := put(`{"domain":" ACME.com. "}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,203 @@
+// namespace_metadata.go serves namespace landing pages: the public namespace
+// view and the endpoint the owning organization uses to edit its description,
+// links and avatar. Descriptions go through the same markdown sanitizer as
+// module changelogs before they are stored.
+package admin
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// NamespaceMetadataHandlers serves the namespace landing-page endpoints.
+type NamespaceMetadataHandlers struct {
+	repo *repositories.NamespaceMetadataRepository
+}
+
+// NewNamespaceMetadataHandlers constructs a NamespaceMetadataHandlers.
+func NewNamespaceMetadataHandlers(repo *repositories.NamespaceMetadataRepository) *NamespaceMetadataHandlers {
+	return &NamespaceMetadataHandlers{repo: repo}
+}
+
+// UpdateNamespaceMetadataRequest is the body of
+// PUT /api/v1/namespaces/:namespace/metadata. It replaces the stored content;
+// omitted fields are cleared.
+type UpdateNamespaceMetadataRequest struct {
+	// Description is markdown. Raw HTML, scripts and javascript: links are
+	// removed before it is stored.
+	Description string                 `json:"description"`
+	Links       []models.NamespaceLink `json:"links"`
+	AvatarURL   *string                `json:"avatar_url"`
+}
+
+// NamespaceResponse is returned by GET /api/v1/namespaces/:namespace.
+type NamespaceResponse struct {
+	Namespace        string                 `json:"namespace"`
+	Description      string                 `json:"description"`
+	ShortDescription string                 `json:"short_description"`
+	Links            []models.NamespaceLink `json:"links"`
+	AvatarURL        *string                `json:"avatar_url,omitempty"`
+	ModuleCount      int                    `json:"module_count"`
+	ProviderCount    int                    `json:"provider_count"`
+	UpdatedAt        *time.Time             `json:"updated_at,omitempty"`
+}
+
+// @Summary      Get a namespace
+// @Description  Returns the landing page of a namespace: the description, links and avatar published by its owning organization, and how many modules and providers it holds. A namespace without published content still returns its counts; one with neither content nor artifacts is not found.
+// @Tags         Namespaces
+// @Produce      json
+// @Param        namespace  path  string  true  "Namespace"
+// @Success      200  {object}  admin.NamespaceResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid namespace"
+// @Failure      404  {object}  map[string]interface{}  "Namespace not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/namespaces/{namespace} [get]
+// GetNamespaceHandler handles GET /api/v1/namespaces/:namespace.
+func (h *NamespaceMetadataHandlers) GetNamespaceHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		namespace := c.Param("namespace")
+		if err := validation.ValidateRegistrySegment(namespace); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid namespace: %v", err)})
+			return
+		}
+
+		meta, err := h.repo.GetMetadata(c.Request.Context(), namespace)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get namespace metadata"})
+			return
+		}
+		moduleCount, providerCount, err := h.repo.CountArtifacts(c.Request.Context(), namespace)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count namespace artifacts"})
+			return
+		}
+		if meta == nil && moduleCount == 0 && providerCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Namespace not found"})
+			return
+		}
+
+		resp := NamespaceResponse{
+			Namespace:     namespace,
+			Links:         []models.NamespaceLink{},
+			ModuleCount:   moduleCount,
+			ProviderCount: providerCount,
+		}
+		if meta != nil {
+			resp.Description = meta.Description
+			resp.ShortDescription = meta.ShortDescription
+			resp.Links = meta.Links
+			resp.AvatarURL = meta.AvatarURL
+			resp.UpdatedAt = &meta.UpdatedAt
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// @Summary      Update namespace metadata
+// @Description  Replaces the landing-page content of a namespace. Only principals with modules:write in the namespace's owning organization (or admins) may edit it. The description is markdown of at most 16 KiB after raw HTML, scripts and javascript: links are removed; at most 10 links are accepted, each with a label of at most 100 characters; link and avatar URLs must be http or https and at most 2048 bytes. The short description shown in search results is derived from the first paragraph of the description.
+// @Tags         Namespaces
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        namespace  path  string                          true  "Namespace"
+// @Param        body       body  UpdateNamespaceMetadataRequest  true  "Namespace metadata"
+// @Success      200  {object}  models.NamespaceMetadata
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Caller does not belong to the owning organization"
+// @Failure      404  {object}  map[string]interface{}  "Namespace not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/namespaces/{namespace}/metadata [put]
+// UpdateMetadataHandler handles PUT /api/v1/namespaces/:namespace/metadata.
+// It runs after the namespace authorizer, which sets owner_org_id for owned
+// namespaces; an unowned namespace has nothing to describe.
+func (h *NamespaceMetadataHandlers) UpdateMetadataHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		namespace := c.Param("namespace")
+		if c.GetString("owner_org_id") == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Namespace not found"})
+			return
+		}
+
+		var req UpdateNamespaceMetadataRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		meta, err := buildNamespaceMetadata(namespace, &req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		meta.UpdatedBy = contextUserID(c)
+
+		if err := h.repo.UpsertMetadata(c.Request.Context(), meta); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save namespace metadata"})
+			return
+		}
+
+		var updatedBy string
+		if meta.UpdatedBy != nil {
+			updatedBy = *meta.UpdatedBy
+		}
+		slog.Info("namespace metadata updated",
+			"namespace", namespace,
+			"organization_id", c.GetString("owner_org_id"),
+			"updated_by", updatedBy,
+			"links", len(meta.Links),
+		)
+		c.JSON(http.StatusOK, meta)
+	}
+}
+
+// buildNamespaceMetadata validates and sanitizes an update request.
+func buildNamespaceMetadata(namespace string, req *UpdateNamespaceMetadataRequest) (*models.NamespaceMetadata, error) {
+	description, err := validation.SanitizeNamespaceDescription(req.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Links) > validation.MaxNamespaceLinks {
+		return nil, fmt.Errorf("at most %d links are allowed", validation.MaxNamespaceLinks)
+	}
+	links := make([]models.NamespaceLink, 0, len(req.Links))
+	for i, link := range req.Links {
+		label := strings.TrimSpace(link.Label)
+		if err := validation.ValidateNamespaceLinkLabel(label); err != nil {
+			return nil, fmt.Errorf("links[%d]: %w", i, err)
+		}
+		url := strings.TrimSpace(link.URL)
+		if err := validation.ValidateNamespaceURL(url); err != nil {
+			return nil, fmt.Errorf("links[%d]: %w", i, err)
+		}
+		links = append(links, models.NamespaceLink{Label: label, URL: url})
+	}
+
+	var avatarURL *string
+	if req.AvatarURL != nil {
+		if avatar := strings.TrimSpace(*req.AvatarURL); avatar != "" {
+			if err := validation.ValidateNamespaceURL(avatar); err != nil {
+				return nil, fmt.Errorf("avatar_url: %w", err)
+			}
+			avatarURL = &avatar
+		}
+	}
+
+	return &models.NamespaceMetadata{
+		Namespace:        namespace,
+		Description:      description,
+		ShortDescription: validation.NamespaceShortDescription(description),
+		Links:            links,
+		AvatarURL:        avatarURL,
+	}, nil
+}
@@ -0,0 +1,157 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var namespaceMetadataCols = []string{
+	"namespace", "description", "short_description", "links", "avatar_url",
+	"updated_by", "created_at", "updated_at",
+}
+
+// newNamespaceMetadataRouter mounts the namespace landing-page handlers.
+// ownerOrgID stands in for the namespace authorizer: "" is an unowned
+// namespace.
+func newNamespaceMetadataRouter(t *testing.T, ownerOrgID string) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewNamespaceMetadataHandlers(repositories.NewNamespaceMetadataRepository(db))
+	r := gin.New()
+	r.GET("/namespaces/:namespace", h.GetNamespaceHandler())
+	r.PUT("/namespaces/:namespace/metadata", func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		if ownerOrgID != "" {
+			c.Set("owner_org_id", ownerOrgID)
+		}
+		c.Next()
+	}, h.UpdateMetadataHandler())
+	return mock, r
+}
+
+func putNamespaceMetadata(r *gin.Engine, namespace, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/namespaces/"+namespace+"/metadata", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetNamespace_WithMetadata(t *testing.T) {
+	mock, r := newNamespaceMetadataRouter(t, "")
+	now := time.Now()
+	mock.ExpectQuery("SELECT.*FROM namespace_metadata").
+		WillReturnRows(sqlmock.NewRows(namespaceMetadataCols).AddRow(
+			"acme", "# Acme\n\nNetworking modules", "Networking modules",
+			[]byte(`[{"label":"Runbook","url":"https://acme.example/runbook"}]`), nil, "user-1", now, now))
+	mock.ExpectQuery("SELECT.*FROM modules.*FROM providers").
+		WillReturnRows(sqlmock.NewRows([]string{"modules", "providers"}).AddRow(4, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/namespaces/acme", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	var resp NamespaceResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.ShortDescription != "Networking modules" || resp.ModuleCount != 4 || len(resp.Links) != 1 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestGetNamespace_ArtifactsWithoutMetadata(t *testing.T) {
+	mock, r := newNamespaceMetadataRouter(t, "")
+	mock.ExpectQuery("SELECT.*FROM namespace_metadata").WillReturnRows(sqlmock.NewRows(namespaceMetadataCols))
+	mock.ExpectQuery("SELECT.*FROM modules.*FROM providers").
+		WillReturnRows(sqlmock.NewRows([]string{"modules", "providers"}).AddRow(0, 2))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/namespaces/acme", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"links":[]`) || !strings.Contains(w.Body.String(), `"provider_count":2`) {
+		t.Errorf("body = %s", w.Body.String())
+	}
+}
+
+func TestGetNamespace_NotFound(t *testing.T) {
+	mock, r := newNamespaceMetadataRouter(t, "")
+	mock.ExpectQuery("SELECT.*FROM namespace_metadata").WillReturnRows(sqlmock.NewRows(namespaceMetadataCols))
+	mock.ExpectQuery("SELECT.*FROM modules.*FROM providers").
+		WillReturnRows(sqlmock.NewRows([]string{"modules", "providers"}).AddRow(0, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/namespaces/acme", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestUpdateNamespaceMetadata_SanitizesAndSaves(t *testing.T) {
+	mock, r := newNamespaceMetadataRouter(t, claimOrgID)
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO namespace_metadata").
+		WithArgs("acme", "# Acme\nNetworking modules", "Networking modules",
+			[]byte(`[{"label":"Runbook","url":"https://acme.example/runbook"}]`), "https://acme.example/logo.png", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+
+	body := `{
+		"description": "# Acme\n<script>alert(1)</script>Networking modules",
+		"links": [{"label": " Runbook ", "url": "https://acme.example/runbook"}],
+		"avatar_url": "https://acme.example/logo.png"
+	}`
+	w := putNamespaceMetadata(r, "acme", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "alert(1)") {
+		t.Errorf("body = %s, want the script removed", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdateNamespaceMetadata_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"javascript avatar", `{"avatar_url": "javascript:alert(1)"}`},
+		{"relative link", `{"links": [{"label": "Docs", "url": "/docs"}]}`},
+		{"empty link label", `{"links": [{"label": "", "url": "https://acme.example"}]}`},
+		{"too many links", `{"links": [` + strings.TrimSuffix(strings.Repeat(`{"label":"x","url":"https://acme.example"},`, 11), ",") + `]}`},
+		{"oversized description", `{"description": "` + strings.Repeat("x", 16*1024+1) + `"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, r := newNamespaceMetadataRouter(t, claimOrgID)
+			if w := putNamespaceMetadata(r, "acme", tt.body); w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400; body = %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateNamespaceMetadata_UnownedNamespace(t *testing.T) {
+	_, r := newNamespaceMetadataRouter(t, "")
+	if w := putNamespaceMetadata(r, "acme", `{"description": "hi"}`); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
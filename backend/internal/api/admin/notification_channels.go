@@ -1,9 +1,9 @@
 // notification_channels.go implements admin CRUD + a test action for
 // notification channels — additional delivery destinations (webhook, Slack,
 // Microsoft Teams, or an ad-hoc email recipient list) for the
-// module_published, approval_pending, cve_detected, and
-// scanner_update_available events, alongside the shared SMTP recipients
-// list. Target values are capability-bearing secrets, so they are encrypted
+// module_published, approval_pending, cve_detected,
+// scanner_update_available, and mirror_storage_threshold events, alongside
+// the shared SMTP recipients list. Target values are capability-bearing secrets, so they are encrypted
 // at rest (via the shared token cipher) and never returned by the API.
 package admin
 
@@ -30,6 +30,7 @@ var validNotificationChannelEvents = map[string]bool{
 	notify.EventApprovalPending:        true,
 	notify.EventCVEDetected:            true,
 	notify.EventScannerUpdateAvailable: true,
+	notify.EventMirrorStorageThreshold: true,
 }
 
 // NotificationChannelHandlers serves the notification-channel endpoints.
@@ -73,7 +74,7 @@ func (req *notificationChannelRequest) validate(guard *identityhttpsafe.Guard) e
 	}
 	for _, e := range req.Events {
 		if !validNotificationChannelEvents[e] {
-			return fmt.Errorf("unknown event %q (allowed: module_published, approval_pending, cve_detected, scanner_update_available)", e)
+			return fmt.Errorf("unknown event %q (allowed: module_published, approval_pending, cve_detected, scanner_update_available, mirror_storage_threshold)", e)
 		}
 	}
 	if req.Target != "" {
@@ -215,6 +216,7 @@ func (h *NotificationChannelHandlers) UpdateChannel(c *gin.Context) {
 // @Summary      Delete notification channel
 // @Tags         Notifications
 // @Security     Bearer
+// @Param        id  path  string  true  "Channel ID"
 // @Success      204
 // @Router       /api/v1/admin/notifications/channels/{id} [delete]
 func (h *NotificationChannelHandlers) DeleteChannel(c *gin.Context) {
@@ -235,6 +237,7 @@ func (h *NotificationChannelHandlers) DeleteChannel(c *gin.Context) {
 // @Tags         Notifications
 // @Security     Bearer
 // @Produce      json
+// @Param        id  path  string  true  "Channel ID"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      404  {object}  map[string]interface{}  "Channel not found"
 // @Failure      502  {object}  map[string]interface{}  "Delivery failed"
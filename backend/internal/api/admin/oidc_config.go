@@ -4,6 +4,8 @@
 package admin
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +16,9 @@ import (
 // OIDCConfigAdminHandlers handles admin OIDC configuration endpoints
 type OIDCConfigAdminHandlers struct {
 	oidcConfigRepo *repositories.OIDCConfigRepository
+	// staged routes group mapping changes through the two-person rule; nil
+	// applies them directly.
+	staged *StagedChanges
 }
 
 // NewOIDCConfigAdminHandlers creates a new OIDCConfigAdminHandlers instance
@@ -21,6 +26,14 @@ func NewOIDCConfigAdminHandlers(oidcConfigRepo *repositories.OIDCConfigRepositor
 	return &OIDCConfigAdminHandlers{oidcConfigRepo: oidcConfigRepo}
 }
 
+// WithStagedChanges routes group mapping changes through the two-person rule
+// and registers their applier. Returns the handler for chaining.
+func (h *OIDCConfigAdminHandlers) WithStagedChanges(s *StagedChanges) *OIDCConfigAdminHandlers {
+	h.staged = s
+	s.Register(models.StagedResourceOIDCGroupMapping, h.applyStagedGroupMapping)
+	return h
+}
+
 // @Summary      Get active OIDC configuration
 // @Description  Returns the currently active OIDC configuration including group mapping settings. Client secret is never returned. Requires admin scope.
 // @Tags         OIDC
@@ -48,53 +61,85 @@ func (h *OIDCConfigAdminHandlers) GetActiveOIDCConfig(c *gin.Context) {
 }
 
 // @Summary      Update OIDC group mapping settings
-// @Description  Updates the group claim name, group-to-role mappings, and default role for the active OIDC configuration. Takes effect on the next login. Requires admin scope.
+// @Description  Updates the group claim name, group-to-role mappings, and default role for the active OIDC configuration. Takes effect on the next login. Requires admin scope. While the two-person rule is on, the change is staged and 202 is returned with the staged change for another admin to approve.
 // @Tags         OIDC
 // @Security     Bearer
 // @Accept       json
 // @Produce      json
 // @Param        body  body  models.OIDCGroupMappingInput  true  "Group mapping configuration"
 // @Success      200  {object}  models.OIDCConfigResponse
+// @Success      202  {object}  models.StagedChange
 // @Failure      400  {object}  map[string]interface{}  "Invalid request body"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      404  {object}  map[string]interface{}  "No active OIDC group configuration"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/admin/oidc/group-mapping [put]
 func (h *OIDCConfigAdminHandlers) UpdateGroupMapping(c *gin.Context) {
-	ctx := c.Request.Context()
-
 	var input models.OIDCGroupMappingInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	cfg, err := h.oidcConfigRepo.GetActiveOIDCConfig(ctx)
+	cfg, err := h.getActiveConfig(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve OIDC configuration"})
+		writeStagedApplyError(c, err)
 		return
 	}
+
+	h.staged.Submit(c, StagedChangeInput{
+		ResourceType: models.StagedResourceOIDCGroupMapping,
+		ResourceID:   cfg.ID.String(),
+		Action:       models.StagedActionUpdate,
+		Before:       models.OIDCConfigToResponse(cfg),
+		After:        input,
+	}, http.StatusOK, func(ctx context.Context) (interface{}, error) {
+		return h.updateGroupMapping(ctx, cfg, input)
+	})
+}
+
+func (h *OIDCConfigAdminHandlers) getActiveConfig(ctx context.Context) (*models.OIDCConfig, error) {
+	cfg, err := h.oidcConfigRepo.GetActiveOIDCConfig(ctx)
+	if err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to retrieve OIDC configuration")
+	}
 	if cfg == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No active OIDC group configuration"})
-		return
+		return nil, newStagedApplyError(http.StatusNotFound, "No active OIDC group configuration")
 	}
+	return cfg, nil
+}
 
+func (h *OIDCConfigAdminHandlers) updateGroupMapping(ctx context.Context, cfg *models.OIDCConfig, input models.OIDCGroupMappingInput) (*models.OIDCConfigResponse, error) {
 	if err := cfg.SetGroupMappingConfig(input.GroupClaimName, models.ToIdentityGroupMappings(input.GroupMappings), input.DefaultRole); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode group mapping"})
-		return
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to encode group mapping")
 	}
 
 	if err := h.oidcConfigRepo.UpdateOIDCConfigExtraConfig(ctx, cfg.ID, cfg.ExtraConfig); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save group mapping"})
-		return
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to save group mapping")
 	}
 
 	// Return the updated config
 	updated, err := h.oidcConfigRepo.GetActiveOIDCConfig(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve updated configuration"})
-		return
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to retrieve updated configuration")
 	}
+	return models.OIDCConfigToResponse(updated), nil
+}
 
-	c.JSON(http.StatusOK, models.OIDCConfigToResponse(updated))
+// applyStagedGroupMapping applies an approved group mapping change. It is
+// refused if a different OIDC configuration has become active since the
+// change was requested.
+func (h *OIDCConfigAdminHandlers) applyStagedGroupMapping(ctx context.Context, change *models.StagedChange) (interface{}, error) {
+	var input models.OIDCGroupMappingInput
+	if err := json.Unmarshal(change.After, &input); err != nil {
+		return nil, newStagedApplyError(http.StatusBadRequest, "Staged group mapping change is malformed")
+	}
+	cfg, err := h.getActiveConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if change.ResourceID != nil && *change.ResourceID != cfg.ID.String() {
+		return nil, newStagedApplyError(http.StatusConflict, "The active OIDC configuration has changed since this change was requested")
+	}
+	return h.updateGroupMapping(ctx, cfg, input)
 }
@@ -0,0 +1,63 @@
+// operations.go exposes the in-process operations registry: the uploads,
+// mirror syncs, storage migrations and exports running on this replica, and
+// cooperative cancellation of them.
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
+)
+
+// OperationsHandlers serves the operations admin endpoints.
+type OperationsHandlers struct {
+	registry *operations.Registry
+}
+
+// NewOperationsHandlers constructs an OperationsHandlers.
+func NewOperationsHandlers(registry *operations.Registry) *OperationsHandlers {
+	return &OperationsHandlers{registry: registry}
+}
+
+// @Summary      List running operations
+// @Description  Lists the long-running operations (uploads, mirror syncs, storage migrations, exports) in flight on the replica that serves the request, oldest first. Progress is reported as done/total in operation-specific units (bytes for uploads, items otherwise); total is omitted when unknown. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "{\"operations\": []operations.Info}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Router       /api/v1/admin/operations [get]
+// ListOperations lists the operations running in this process.
+// GET /api/v1/admin/operations
+func (h *OperationsHandlers) ListOperations(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"operations": h.registry.List()})
+}
+
+// @Summary      Cancel operation
+// @Description  Requests cooperative cancellation of a running operation: its context is cancelled and it stops at its next checkpoint. The operation stays listed with cancel_requested=true until it has stopped. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Operation ID"
+// @Success      202  {object}  map[string]interface{}  "{\"message\": string, \"operation\": operations.Info}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Operation not found"
+// @Router       /api/v1/admin/operations/{id} [delete]
+// CancelOperation requests cancellation of an operation.
+// DELETE /api/v1/admin/operations/:id
+func (h *OperationsHandlers) CancelOperation(c *gin.Context) {
+	id := c.Param("id")
+	if !h.registry.Cancel(id) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Operation not found"})
+		return
+	}
+	resp := gin.H{"message": "Cancellation requested"}
+	// The owner may already have finished by now; report it only if still listed.
+	if info, ok := h.registry.Get(id); ok {
+		resp["operation"] = info
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
+)
+
+func newOperationsRouter(reg *operations.Registry) *gin.Engine {
+	h := NewOperationsHandlers(reg)
+	r := gin.New()
+	r.GET("/operations", h.ListOperations)
+	r.DELETE("/operations/:id", h.CancelOperation)
+	return r
+}
+
+func TestListOperations(t *testing.T) {
+	reg := operations.NewRegistry()
+	_, op := reg.Start(context.Background(), operations.TypeMirrorSync, operations.ActorSystem, "mirror/hashicorp")
+	defer op.Finish()
+	op.SetTotal(3)
+	op.AddDone(1)
+
+	w := httptest.NewRecorder()
+	newOperationsRouter(reg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/operations", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Operations []operations.Info `json:"operations"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Operations) != 1 {
+		t.Fatalf("operations = %+v", resp.Operations)
+	}
+	got := resp.Operations[0]
+	if got.ID != op.ID() || got.Type != operations.TypeMirrorSync || got.Done != 1 || got.Total != 3 {
+		t.Errorf("operation = %+v", got)
+	}
+}
+
+func TestListOperations_Empty(t *testing.T) {
+	w := httptest.NewRecorder()
+	newOperationsRouter(operations.NewRegistry()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/operations", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"operations":[]}` {
+		t.Errorf("got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCancelOperation(t *testing.T) {
+	reg := operations.NewRegistry()
+	ctx, op := reg.Start(context.Background(), operations.TypeStorageMigration, "user-1", "storage_migration/m1")
+	defer op.Finish()
+
+	w := httptest.NewRecorder()
+	newOperationsRouter(reg).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/operations/"+op.ID(), nil))
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if ctx.Err() == nil {
+		t.Error("operation context was not cancelled")
+	}
+	var resp struct {
+		Operation operations.Info `json:"operation"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Operation.ID != op.ID() || !resp.Operation.CancelRequested {
+		t.Errorf("operation = %+v, want cancel_requested", resp.Operation)
+	}
+}
+
+func TestCancelOperation_NotFound(t *testing.T) {
+	w := httptest.NewRecorder()
+	newOperationsRouter(operations.NewRegistry()).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/operations/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
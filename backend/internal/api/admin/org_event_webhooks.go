@@ -0,0 +1,590 @@
+// org_event_webhooks.go implements the per-organization event webhook
+// endpoints: subscribing HTTPS endpoints (typically a SIEM collector) to the
+// organization's security-relevant events, testing them before they are
+// enabled, and reading and replaying their delivery log. Events are published
+// on the events.Bus by the organization, API key and SCM OAuth handlers and
+// delivered by services.OrgEventWebhooks.
+package admin
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/events"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+const (
+	defaultOrgEventWebhookTimeoutSeconds = 10
+	minOrgEventWebhookSecretLength       = 16
+	defaultOrgEventWebhookOverlapHours   = 24
+)
+
+// orgEventDeliveryLimits are the page size default and cap of the delivery
+// log, which is paged by cursor only.
+var orgEventDeliveryLimits = pagination.Limits{Default: 50, Max: 500}
+
+// OrgEventWebhookHandlers serves the organization event webhook endpoints.
+type OrgEventWebhookHandlers struct {
+	orgRepo     *repositories.OrganizationRepository
+	repo        *repositories.OrgEventWebhookRepository
+	webhooks    *services.OrgEventWebhooks
+	tokenCipher *crypto.TokenCipher
+	// egress is consulted when validating webhook URLs; nil enforces the
+	// strict default deny-list.
+	egress *httpsafe.Guard
+}
+
+// NewOrgEventWebhookHandlers constructs an OrgEventWebhookHandlers.
+// identityDB backs organizations; repo runs on the registry's own connection.
+func NewOrgEventWebhookHandlers(identityDB *sql.DB, repo *repositories.OrgEventWebhookRepository, webhooks *services.OrgEventWebhooks, tokenCipher *crypto.TokenCipher) *OrgEventWebhookHandlers {
+	return &OrgEventWebhookHandlers{
+		orgRepo:     repositories.NewOrganizationRepository(identityDB),
+		repo:        repo,
+		webhooks:    webhooks,
+		tokenCipher: tokenCipher,
+	}
+}
+
+// WithEgressGuard installs the operator-configured egress guard
+// (security.egress.allowlist) consulted when validating webhook URLs. Returns
+// the handler for chaining.
+func (h *OrgEventWebhookHandlers) WithEgressGuard(g *httpsafe.Guard) *OrgEventWebhookHandlers {
+	h.egress = g
+	return h
+}
+
+// RotateOrgEventWebhookSecretRequest is the optional body of
+// POST /organizations/:id/event-webhooks/:webhook_id/rotate-secret.
+type RotateOrgEventWebhookSecretRequest struct {
+	// Secret is the next secret; a random one is generated when omitted.
+	Secret string `json:"secret"`
+	// OverlapHours is how long deliveries are signed with both secrets.
+	// Defaults to 24.
+	OverlapHours *int `json:"overlap_hours" binding:"omitempty,min=1,max=168"`
+}
+
+// RotateOrgEventWebhookSecretResponse is returned when a secret rotation
+// starts.
+type RotateOrgEventWebhookSecretResponse struct {
+	Webhook *models.OrgEventWebhook `json:"webhook"`
+	// Secret is the next secret. It is not returned again.
+	Secret string `json:"secret"`
+}
+
+// OrgEventWebhookRequest is the body of POST /organizations/:id/event-webhooks
+// and PUT /organizations/:id/event-webhooks/:webhook_id.
+type OrgEventWebhookRequest struct {
+	// URL is the HTTPS endpoint events are POSTed to.
+	URL string `json:"url"`
+	// Secret keys the HMAC-SHA256 request signature. Write-only: required
+	// when creating the webhook, omit it to keep the current one.
+	Secret string `json:"secret"`
+	// EventTypes filters the events delivered; empty delivers every type.
+	EventTypes []string `json:"event_types"`
+	// TimeoutSeconds is how long each request may take (1-60, default 10).
+	TimeoutSeconds int `json:"timeout_seconds"`
+	// Enabled turns on delivery. Leave it off until a test succeeds.
+	Enabled bool `json:"enabled"`
+}
+
+func (req *OrgEventWebhookRequest) validate(guard *httpsafe.Guard) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("url must be a valid https URL")
+	}
+	if err := guard.ValidateURL(req.URL); err != nil {
+		return err
+	}
+	if req.Secret != "" && len(req.Secret) < minOrgEventWebhookSecretLength {
+		return fmt.Errorf("secret must be at least %d characters", minOrgEventWebhookSecretLength)
+	}
+	if req.TimeoutSeconds == 0 {
+		req.TimeoutSeconds = defaultOrgEventWebhookTimeoutSeconds
+	}
+	if req.TimeoutSeconds < 1 || req.TimeoutSeconds > int(services.MaxPublishHookTimeout.Seconds()) {
+		return fmt.Errorf("timeout_seconds must be between 1 and %d", int(services.MaxPublishHookTimeout.Seconds()))
+	}
+	eventTypes := []string{}
+	seen := map[string]bool{}
+	for _, t := range req.EventTypes {
+		if !events.IsType(t) {
+			return fmt.Errorf("unknown event type %q; valid types are %s", t, strings.Join(events.Types, ", "))
+		}
+		if !seen[t] {
+			seen[t] = true
+			eventTypes = append(eventTypes, t)
+		}
+	}
+	req.EventTypes = eventTypes
+	return nil
+}
+
+// sealSecret encrypts secret, writing the error response and returning
+// ok == false on failure.
+func (h *OrgEventWebhookHandlers) sealSecret(c *gin.Context, secret string) (string, bool) {
+	if h.tokenCipher == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No encryption key is configured to store the secret"})
+		return "", false
+	}
+	sealed, err := h.tokenCipher.Seal(secret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encrypt secret"})
+		return "", false
+	}
+	return sealed, true
+}
+
+// loadWebhook fetches the path's webhook, writing the error response and
+// returning nil when it cannot.
+func (h *OrgEventWebhookHandlers) loadWebhook(c *gin.Context) *models.OrgEventWebhook {
+	w, err := h.repo.Get(c.Request.Context(), c.Param("id"), c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event webhook"})
+		return nil
+	}
+	if w == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event webhook not found"})
+		return nil
+	}
+	return w
+}
+
+// @Summary      List organization event webhooks
+// @Description  Returns the organization's event webhooks. Secrets are never returned; has_secret reports that one is set.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  map[string]interface{}  "webhooks: []models.OrgEventWebhook"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks [get]
+// ListWebhooksHandler lists an organization's event webhooks.
+// GET /api/v1/organizations/:id/event-webhooks
+func (h *OrgEventWebhookHandlers) ListWebhooksHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhooks, err := h.repo.List(c.Request.Context(), c.Param("id"), false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list event webhooks"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+	}
+}
+
+// @Summary      Create organization event webhook
+// @Description  Subscribes an HTTPS endpoint to the organization's security events: organization.member_added, organization.member_removed, organization.member_role_changed, api_key.created, api_key.rotated, api_key.revoked, scm.token_connected, provider.tier_changed and module.tier_changed. event_types filters them; empty subscribes to all. While enabled, each event is POSTed as JSON carrying schema_version, signed with HMAC-SHA256 in X-Registry-Signature-256 (and in X-Registry-Signatures, with the secret's key ID in X-Registry-Signature-Key-Id; see GET /api/v1/webhooks/verification-info), with the event type in X-Registry-Event, the delivery ID in X-Registry-Delivery and the schema version in X-Registry-Schema-Version. Any 2xx answer counts as delivered; a 5xx answer is retried once. The secret is write-only and required. The URL must be https and pass the egress policy.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                  true  "Organization ID"
+// @Param        body  body  OrgEventWebhookRequest  true  "Webhook"
+// @Success      201  {object}  models.OrgEventWebhook
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Organization not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks [post]
+// CreateWebhookHandler subscribes an endpoint to an organization's events.
+// POST /api/v1/organizations/:id/event-webhooks
+func (h *OrgEventWebhookHandlers) CreateWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req OrgEventWebhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+		if err := req.validate(h.egress); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Secret == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "secret is required when creating an event webhook"})
+			return
+		}
+
+		org, err := h.orgRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		sealed, ok := h.sealSecret(c, req.Secret)
+		if !ok {
+			return
+		}
+		w := &models.OrgEventWebhook{
+			OrganizationID:  org.ID,
+			URL:             req.URL,
+			EncryptedSecret: sealed,
+			EventTypes:      req.EventTypes,
+			TimeoutSeconds:  req.TimeoutSeconds,
+			Enabled:         req.Enabled,
+		}
+		if err := h.repo.Create(c.Request.Context(), w); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save event webhook"})
+			return
+		}
+		c.JSON(http.StatusCreated, w)
+	}
+}
+
+// @Summary      Get organization event webhook
+// @Description  Returns one of the organization's event webhooks. The secret is never returned.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id          path  string  true  "Organization ID"
+// @Param        webhook_id  path  string  true  "Webhook ID"
+// @Success      200  {object}  models.OrgEventWebhook
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Event webhook not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks/{webhook_id} [get]
+// GetWebhookHandler returns one of an organization's event webhooks.
+// GET /api/v1/organizations/:id/event-webhooks/:webhook_id
+func (h *OrgEventWebhookHandlers) GetWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if w := h.loadWebhook(c); w != nil {
+			c.JSON(http.StatusOK, w)
+		}
+	}
+}
+
+// @Summary      Update organization event webhook
+// @Description  Replaces an event webhook's settings. Omit secret to keep the current one; a new secret gets a new key ID at once and abandons any secret rotation in progress, so prefer rotate-secret for a live receiver.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id          path  string                  true  "Organization ID"
+// @Param        webhook_id  path  string                  true  "Webhook ID"
+// @Param        body        body  OrgEventWebhookRequest  true  "Webhook"
+// @Success      200  {object}  models.OrgEventWebhook
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Event webhook not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks/{webhook_id} [put]
+// UpdateWebhookHandler replaces an event webhook's settings.
+// PUT /api/v1/organizations/:id/event-webhooks/:webhook_id
+func (h *OrgEventWebhookHandlers) UpdateWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req OrgEventWebhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+		if err := req.validate(h.egress); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		current := h.loadWebhook(c)
+		if current == nil {
+			return
+		}
+		w := &models.OrgEventWebhook{
+			ID:              current.ID,
+			OrganizationID:  current.OrganizationID,
+			URL:             req.URL,
+			EncryptedSecret: current.EncryptedSecret,
+			EventTypes:      req.EventTypes,
+			TimeoutSeconds:  req.TimeoutSeconds,
+			Enabled:         req.Enabled,
+		}
+		if req.Secret != "" {
+			sealed, ok := h.sealSecret(c, req.Secret)
+			if !ok {
+				return
+			}
+			w.EncryptedSecret = sealed
+		}
+
+		found, err := h.repo.Update(c.Request.Context(), w)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save event webhook"})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event webhook not found"})
+			return
+		}
+		c.JSON(http.StatusOK, w)
+	}
+}
+
+// @Summary      Delete organization event webhook
+// @Description  Removes an event webhook and its delivery log.
+// @Tags         Organizations
+// @Security     Bearer
+// @Param        id          path  string  true  "Organization ID"
+// @Param        webhook_id  path  string  true  "Webhook ID"
+// @Success      204  "Deleted"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Event webhook not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks/{webhook_id} [delete]
+// DeleteWebhookHandler removes an event webhook.
+// DELETE /api/v1/organizations/:id/event-webhooks/:webhook_id
+func (h *OrgEventWebhookHandlers) DeleteWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deleted, err := h.repo.Delete(c.Request.Context(), c.Param("id"), c.Param("webhook_id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event webhook"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event webhook not found"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// @Summary      Test organization event webhook
+// @Description  Sends the webhook a signed sample event of type event_webhook.test, then a copy signed with the wrong key, whether or not the webhook is enabled. ready is true when the endpoint answered the signed event with a 2xx status and rejected the badly signed copy with a 4xx status. The signed event is recorded in the delivery log.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id          path  string  true  "Organization ID"
+// @Param        webhook_id  path  string  true  "Webhook ID"
+// @Success      200  {object}  services.OrgEventWebhookTestResult
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Event webhook not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks/{webhook_id}/test [post]
+// TestWebhookHandler sends an event webhook a sample event.
+// POST /api/v1/organizations/:id/event-webhooks/:webhook_id/test
+func (h *OrgEventWebhookHandlers) TestWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if w := h.loadWebhook(c); w != nil {
+			c.JSON(http.StatusOK, h.webhooks.Test(c.Request.Context(), w))
+		}
+	}
+}
+
+// @Summary      List organization event webhook deliveries
+// @Description  Returns the webhook's deliveries, newest first, with the payload sent, the outcome (delivered, failed), the last status code, the number of attempts, and why a failed delivery failed. Replays carry replay_of. Pages hold limit deliveries; pass meta.next_cursor as cursor to read the next page. The log does not accept offset or page.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id          path   string   true   "Organization ID"
+// @Param        webhook_id  path   string   true   "Webhook ID"
+// @Param        limit       query  integer  false  "Deliveries per page (default 50, max 500)"
+// @Param        cursor      query  string   false  "meta.next_cursor of the previous page"
+// @Success      200  {object}  map[string]interface{}  "deliveries: []models.OrgEventDelivery, meta: PaginationMetadata"
+// @Failure      400  {object}  map[string]interface{}  "Invalid cursor, or offset given"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Event webhook not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks/{webhook_id}/deliveries [get]
+// ListDeliveriesHandler lists an event webhook's deliveries.
+// GET /api/v1/organizations/:id/event-webhooks/:webhook_id/deliveries
+func (h *OrgEventWebhookHandlers) ListDeliveriesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := pagination.ParseCursor(c, orgEventDeliveryLimits)
+		if err == nil && page.Offset > 0 {
+			err = errors.New("deliveries are paged with cursor, not offset or page")
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		w := h.loadWebhook(c)
+		if w == nil {
+			return
+		}
+		deliveries, err := h.repo.ListDeliveriesAfter(c.Request.Context(), w.ID, page.After, page.Limit+1)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list event deliveries"})
+			return
+		}
+		deliveries, hasMore := pagination.Trim(page, deliveries)
+		var last repositories.Keyset
+		if len(deliveries) > 0 {
+			d := deliveries[len(deliveries)-1]
+			last = repositories.Keyset{CreatedAt: d.CreatedAt, ID: d.ID}
+		}
+		c.JSON(http.StatusOK, gin.H{"deliveries": deliveries, "meta": page.CursorMeta(hasMore, last)})
+	}
+}
+
+// @Summary      Replay organization event webhook delivery
+// @Description  Re-sends a logged delivery's payload unchanged, signed with the webhook's current secrets and under a new delivery ID, whether or not the webhook is enabled. The event id in the payload is unchanged, so receivers can deduplicate on it. The new delivery is recorded with replay_of set.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id           path  string  true  "Organization ID"
+// @Param        webhook_id   path  string  true  "Webhook ID"
+// @Param        delivery_id  path  string  true  "Delivery ID"
+// @Success      200  {object}  models.OrgEventDelivery
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Event webhook or delivery not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks/{webhook_id}/deliveries/{delivery_id}/replay [post]
+// ReplayDeliveryHandler re-sends a logged delivery.
+// POST /api/v1/organizations/:id/event-webhooks/:webhook_id/deliveries/:delivery_id/replay
+func (h *OrgEventWebhookHandlers) ReplayDeliveryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := h.loadWebhook(c)
+		if w == nil {
+			return
+		}
+		d, err := h.repo.GetDelivery(c.Request.Context(), w.ID, c.Param("delivery_id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event delivery"})
+			return
+		}
+		if d == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event delivery not found"})
+			return
+		}
+		c.JSON(http.StatusOK, h.webhooks.Replay(c.Request.Context(), w, d))
+	}
+}
+
+// @Summary      Start organization event webhook secret rotation
+// @Description  Adds a next secret to the webhook, under a new key ID, so receivers can switch secrets without missing a delivery. Until overlap_until (overlap_hours from now: default 24, 1-168) every delivery is signed with both secrets: X-Registry-Signature-256 and X-Registry-Signature-Key-Id keep using the current secret, and X-Registry-Signatures carries a "<key_id>:sha256=<hex>" signature for each. After overlap_until only the next secret signs. Complete the rotation to promote the next secret. secret is generated when omitted; it is only returned by this call.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id          path  string                              true   "Organization ID"
+// @Param        webhook_id  path  string                              true   "Webhook ID"
+// @Param        body        body  RotateOrgEventWebhookSecretRequest  false  "Next secret and overlap"
+// @Success      200  {object}  admin.RotateOrgEventWebhookSecretResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Event webhook not found"
+// @Failure      409  {object}  map[string]interface{}  "A secret rotation is already in progress"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks/{webhook_id}/rotate-secret [post]
+// StartSecretRotationHandler adds a next secret to an event webhook.
+// POST /api/v1/organizations/:id/event-webhooks/:webhook_id/rotate-secret
+func (h *OrgEventWebhookHandlers) StartSecretRotationHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RotateOrgEventWebhookSecretRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+				return
+			}
+		}
+		if req.Secret != "" && len(req.Secret) < minOrgEventWebhookSecretLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("secret must be at least %d characters", minOrgEventWebhookSecretLength)})
+			return
+		}
+		overlapHours := defaultOrgEventWebhookOverlapHours
+		if req.OverlapHours != nil {
+			overlapHours = *req.OverlapHours
+		}
+
+		current := h.loadWebhook(c)
+		if current == nil {
+			return
+		}
+		if current.SecretRotation != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "A secret rotation is already in progress; complete it first"})
+			return
+		}
+
+		secret := req.Secret
+		if secret == "" {
+			b := make([]byte, 32)
+			if _, err := rand.Read(b); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+				return
+			}
+			secret = hex.EncodeToString(b)
+		}
+		sealed, ok := h.sealSecret(c, secret)
+		if !ok {
+			return
+		}
+		overlapUntil := time.Now().Add(time.Duration(overlapHours) * time.Hour)
+		w, err := h.repo.StartSecretRotation(c.Request.Context(), current.OrganizationID, current.ID, sealed, overlapUntil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start secret rotation"})
+			return
+		}
+		if w == nil {
+			// Deleted, or another rotation started, since it was looked up.
+			c.JSON(http.StatusConflict, gin.H{"error": "A secret rotation is already in progress; complete it first"})
+			return
+		}
+		c.JSON(http.StatusOK, RotateOrgEventWebhookSecretResponse{Webhook: w, Secret: secret})
+	}
+}
+
+// @Summary      Complete organization event webhook secret rotation
+// @Description  Promotes the webhook's next secret, with its key ID, and retires the current one; from then on deliveries are signed with the promoted secret only. It may be called before overlap_until once every receiver has the next secret.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id          path  string  true  "Organization ID"
+// @Param        webhook_id  path  string  true  "Webhook ID"
+// @Success      200  {object}  models.OrgEventWebhook
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Event webhook not found"
+// @Failure      409  {object}  map[string]interface{}  "No secret rotation is in progress"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/event-webhooks/{webhook_id}/rotate-secret/complete [post]
+// CompleteSecretRotationHandler promotes an event webhook's next secret.
+// POST /api/v1/organizations/:id/event-webhooks/:webhook_id/rotate-secret/complete
+func (h *OrgEventWebhookHandlers) CompleteSecretRotationHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current := h.loadWebhook(c)
+		if current == nil {
+			return
+		}
+		w, err := h.repo.CompleteSecretRotation(c.Request.Context(), current.OrganizationID, current.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete secret rotation"})
+			return
+		}
+		if w == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "No secret rotation is in progress"})
+			return
+		}
+		c.JSON(http.StatusOK, w)
+	}
+}
+
+// eventActor returns the calling user's ID for an event's actor_id, or ""
+// when the request is not tied to a user.
+func eventActor(c *gin.Context) string {
+	if userID, ok := getUserIDFromContext(c); ok {
+		return userID.String()
+	}
+	return ""
+}
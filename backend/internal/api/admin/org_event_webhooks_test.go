@@ -0,0 +1,345 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/events"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+)
+
+var orgEventWebhookCols = []string{"id", "organization_id", "url", "encrypted_secret", "event_types", "timeout_seconds", "enabled", "created_at", "updated_at",
+	"secret_key_id", "next_encrypted_secret", "next_secret_key_id", "rotation_started_at", "rotation_overlap_until"}
+
+func newOrgEventWebhookRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	cipher, _ := crypto.NewTokenCipher(bytes.Repeat([]byte("k"), 32))
+
+	// Webhook URLs are IP literals on the allowlist so validation needs no DNS.
+	h := NewOrgEventWebhookHandlers(db, repositories.NewOrgEventWebhookRepository(db), nil, cipher).
+		WithEgressGuard(httpsafe.MustGuard("203.0.113.10"))
+	r := gin.New()
+	r.POST("/organizations/:id/event-webhooks", h.CreateWebhookHandler())
+	r.PUT("/organizations/:id/event-webhooks/:webhook_id", h.UpdateWebhookHandler())
+	r.POST("/organizations/:id/event-webhooks/:webhook_id/rotate-secret", h.StartSecretRotationHandler())
+	r.POST("/organizations/:id/event-webhooks/:webhook_id/rotate-secret/complete", h.CompleteSecretRotationHandler())
+	r.GET("/organizations/:id/event-webhooks/:webhook_id/deliveries", h.ListDeliveriesHandler())
+	r.POST("/organizations/:id/event-webhooks/:webhook_id/deliveries/:delivery_id/replay", h.ReplayDeliveryHandler())
+	return mock, r
+}
+
+func sendOrgEventWebhook(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateOrgEventWebhook_Validation(t *testing.T) {
+	_, r := newOrgEventWebhookRouter(t)
+	for name, body := range map[string]string{
+		"http url":       `{"url":"http://203.0.113.10/siem","secret":"0123456789abcdef"}`,
+		"private url":    `{"url":"https://10.0.0.1/siem","secret":"0123456789abcdef"}`,
+		"short secret":   `{"url":"https://203.0.113.10/siem","secret":"short"}`,
+		"timeout":        `{"url":"https://203.0.113.10/siem","secret":"0123456789abcdef","timeout_seconds":61}`,
+		"unknown type":   `{"url":"https://203.0.113.10/siem","secret":"0123456789abcdef","event_types":["module.published"]}`,
+		"missing secret": `{"url":"https://203.0.113.10/siem"}`,
+		"invalid json":   `{`,
+	} {
+		if w := sendOrgEventWebhook(r, "POST", "/organizations/org-1/event-webhooks", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400; body = %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestCreateOrgEventWebhook_DedupesEventTypes(t *testing.T) {
+	mock, r := newOrgEventWebhookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO org_event_webhooks").
+		WithArgs("org-1", "https://203.0.113.10/siem", sqlmock.AnyArg(), pq.Array([]string{"api_key.created", "api_key.revoked"}), 10, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "secret_key_id", "created_at", "updated_at"}).AddRow("wh-1", "k1", time.Now(), time.Now()))
+
+	w := sendOrgEventWebhook(r, "POST", "/organizations/org-1/event-webhooks",
+		`{"url":"https://203.0.113.10/siem","secret":"0123456789abcdef","event_types":["api_key.created","api_key.revoked","api_key.created"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var webhook map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &webhook)
+	if _, leaked := webhook["encrypted_secret"]; leaked || webhook["has_secret"] != true || webhook["id"] != "wh-1" {
+		t.Errorf("response = %v, want has_secret and no secret", webhook)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateOrgEventWebhook_KeepsSecret(t *testing.T) {
+	mock, r := newOrgEventWebhookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_event_webhooks WHERE organization_id").
+		WithArgs("org-1", "wh-1").
+		WillReturnRows(sqlmock.NewRows(orgEventWebhookCols).AddRow("wh-1", "org-1", "https://203.0.113.10/old", "sealed-secret",
+			"{}", 10, false, time.Now(), time.Now(), "k1", nil, nil, nil, nil))
+	mock.ExpectQuery("UPDATE org_event_webhooks").
+		WithArgs("org-1", "wh-1", "https://203.0.113.10/siem", "sealed-secret", pq.Array([]string{}), 10, true).
+		WillReturnRows(sqlmock.NewRows(orgEventWebhookCols).AddRow("wh-1", "org-1", "https://203.0.113.10/siem", "sealed-secret",
+			"{}", 10, true, time.Now(), time.Now(), "k1", nil, nil, nil, nil))
+
+	w := sendOrgEventWebhook(r, "PUT", "/organizations/org-1/event-webhooks/wh-1", `{"url":"https://203.0.113.10/siem","enabled":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// webhookRow returns a webhook row for wh-1, with a rotation in progress to
+// next when next is set.
+func webhookRow(next string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(orgEventWebhookCols)
+	if next == "" {
+		return rows.AddRow("wh-1", "org-1", "https://203.0.113.10/siem", "sealed-secret",
+			"{}", 10, true, time.Now(), time.Now(), "k1", nil, nil, nil, nil)
+	}
+	return rows.AddRow("wh-1", "org-1", "https://203.0.113.10/siem", "sealed-secret",
+		"{}", 10, true, time.Now(), time.Now(), "k1", next, "k2", time.Now(), time.Now().Add(24*time.Hour))
+}
+
+func TestStartOrgEventWebhookSecretRotation(t *testing.T) {
+	mock, r := newOrgEventWebhookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_event_webhooks WHERE organization_id").WillReturnRows(webhookRow(""))
+	mock.ExpectQuery("UPDATE org_event_webhooks SET.*next_encrypted_secret IS NULL").
+		WithArgs("org-1", "wh-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(webhookRow("sealed-next"))
+
+	w := sendOrgEventWebhook(r, "POST", "/organizations/org-1/event-webhooks/wh-1/rotate-secret", `{"overlap_hours":48}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp RotateOrgEventWebhookSecretResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Secret) != 64 || resp.Webhook == nil || resp.Webhook.SecretRotation == nil || resp.Webhook.SecretRotation.NextSecretKeyID != "k2" {
+		t.Errorf("response = %s", w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "sealed-next") {
+		t.Error("response leaks the sealed next secret")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStartOrgEventWebhookSecretRotation_Validation(t *testing.T) {
+	_, r := newOrgEventWebhookRouter(t)
+	for name, body := range map[string]string{
+		"short secret":  `{"secret":"short"}`,
+		"zero overlap":  `{"overlap_hours":0}`,
+		"long overlap":  `{"overlap_hours":169}`,
+		"invalid json":  `{`,
+		"wrong type":    `{"overlap_hours":"a day"}`,
+		"secret object": `{"secret":{}}`,
+	} {
+		if w := sendOrgEventWebhook(r, "POST", "/organizations/org-1/event-webhooks/wh-1/rotate-secret", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400; body = %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestStartOrgEventWebhookSecretRotation_InProgress(t *testing.T) {
+	mock, r := newOrgEventWebhookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_event_webhooks WHERE organization_id").WillReturnRows(webhookRow("sealed-next"))
+
+	w := sendOrgEventWebhook(r, "POST", "/organizations/org-1/event-webhooks/wh-1/rotate-secret", "")
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409; body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCompleteOrgEventWebhookSecretRotation(t *testing.T) {
+	mock, r := newOrgEventWebhookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_event_webhooks WHERE organization_id").WillReturnRows(webhookRow("sealed-next"))
+	mock.ExpectQuery("encrypted_secret += next_encrypted_secret").
+		WithArgs("org-1", "wh-1").
+		WillReturnRows(sqlmock.NewRows(orgEventWebhookCols).AddRow("wh-1", "org-1", "https://203.0.113.10/siem", "sealed-next",
+			"{}", 10, true, time.Now(), time.Now(), "k2", nil, nil, nil, nil))
+
+	w := sendOrgEventWebhook(r, "POST", "/organizations/org-1/event-webhooks/wh-1/rotate-secret/complete", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var webhook models.OrgEventWebhook
+	if err := json.Unmarshal(w.Body.Bytes(), &webhook); err != nil || webhook.SecretKeyID != "k2" || webhook.SecretRotation != nil {
+		t.Errorf("webhook = %+v, %v", webhook, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCompleteOrgEventWebhookSecretRotation_NoneInProgress(t *testing.T) {
+	mock, r := newOrgEventWebhookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_event_webhooks WHERE organization_id").WillReturnRows(webhookRow(""))
+	mock.ExpectQuery("encrypted_secret += next_encrypted_secret").WillReturnRows(sqlmock.NewRows(orgEventWebhookCols))
+
+	w := sendOrgEventWebhook(r, "POST", "/organizations/org-1/event-webhooks/wh-1/rotate-secret/complete", "")
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409; body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestReplayOrgEventDelivery_NotFound(t *testing.T) {
+	mock, r := newOrgEventWebhookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_event_webhooks WHERE organization_id").
+		WillReturnRows(sqlmock.NewRows(orgEventWebhookCols).AddRow("wh-1", "org-1", "https://203.0.113.10/siem", "sealed-secret",
+			"{}", 10, true, time.Now(), time.Now(), "k1", nil, nil, nil, nil))
+	mock.ExpectQuery("FROM org_event_deliveries WHERE webhook_id").
+		WithArgs("wh-1", "d-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	w := sendOrgEventWebhook(r, "POST", "/organizations/org-1/event-webhooks/wh-1/deliveries/d-1/replay", "")
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "delivery not found") {
+		t.Errorf("status = %d, body = %s; want 404 delivery not found", w.Code, w.Body.String())
+	}
+}
+
+func TestListOrgEventDeliveries_Paging(t *testing.T) {
+	mock, r := newOrgEventWebhookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_event_webhooks WHERE organization_id").
+		WillReturnRows(sqlmock.NewRows(orgEventWebhookCols).AddRow("wh-1", "org-1", "https://203.0.113.10/siem", "sealed-secret",
+			"{}", 10, true, time.Now(), time.Now(), "k1", nil, nil, nil, nil))
+	cols := []string{"id", "webhook_id", "event_id", "event_type", "schema_version", "payload",
+		"outcome", "status_code", "attempts", "error", "duration_ms", "replay_of", "created_at"}
+	rows := sqlmock.NewRows(cols)
+	for _, id := range []string{"3f2a8c3e-6c1d-4d59-9a55-1d5e7a8d4b21", "8d0c2b1e-5a4f-4e3d-9c2b-1a0f9e8d7c6b", "b6a5c4d3-e2f1-4a0b-9c8d-7e6f5a4b3c2d"} {
+		rows.AddRow(id, "wh-1", "ev-1", "api_key.created", 1, []byte(`{}`), "delivered", 200, 1, nil, 5, nil, time.Now())
+	}
+	// limit=2 reads three rows: the third only tells that a next page exists.
+	mock.ExpectQuery("FROM org_event_deliveries.*LIMIT").
+		WithArgs("wh-1", 3).
+		WillReturnRows(rows)
+
+	w := sendOrgEventWebhook(r, "GET", "/organizations/org-1/event-webhooks/wh-1/deliveries?limit=2", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Deliveries []models.OrgEventDelivery `json:"deliveries"`
+		Meta       struct {
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Deliveries) != 2 || !resp.Meta.HasMore || resp.Meta.NextCursor == "" {
+		t.Errorf("response = %+v, %v; want 2 deliveries and a next cursor", resp, err)
+	}
+
+	for _, query := range []string{"offset=2", "page=2", "cursor=garbage"} {
+		if w := sendOrgEventWebhook(r, "GET", "/organizations/org-1/event-webhooks/wh-1/deliveries?"+query, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+// collectEvents starts bus and returns a function that stops it and returns
+// every event it delivered.
+func collectEvents(t *testing.T, bus *events.Bus) func() []events.Event {
+	t.Helper()
+	var (
+		mu  sync.Mutex
+		got []events.Event
+	)
+	bus.Subscribe(func(_ context.Context, e events.Event) {
+		mu.Lock()
+		got = append(got, e)
+		mu.Unlock()
+	})
+	done := make(chan struct{})
+	go func() {
+		_ = bus.Start(context.Background())
+		close(done)
+	}()
+	return func() []events.Event {
+		_ = bus.Stop()
+		<-done
+		mu.Lock()
+		defer mu.Unlock()
+		return got
+	}
+}
+
+func newOrgRouterWithEvents(t *testing.T, bus *events.Bus) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	h := NewOrganizationHandlers(&config.Config{}, db, repositories.NewNamespaceClaimRepository(db), nil).WithEvents(bus)
+	r := gin.New()
+	r.DELETE("/organizations/:id/members/:user_id", h.RemoveMemberHandler())
+	return mock, r
+}
+
+func TestRemoveMember_PublishesMemberRemoved(t *testing.T) {
+	bus := events.NewBus(0)
+	stop := collectEvents(t, bus)
+	mock, r := newOrgRouterWithEvents(t, bus)
+	mock.ExpectQuery("SELECT.*FROM organization_members.*LEFT JOIN").
+		WillReturnRows(sampleMemberWithRoleRow())
+	mock.ExpectExec("DELETE FROM organization_members").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/organizations/org-1/members/user-1", nil))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "revocation_incomplete") {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	got := stop()
+	if len(got) != 1 || got[0].Type != events.TypeMemberRemoved || got[0].OrganizationID != "org-1" || got[0].Data["user_id"] != "user-1" {
+		t.Errorf("events = %+v", got)
+	}
+}
+
+func TestRemoveMember_NonMemberPublishesNothing(t *testing.T) {
+	bus := events.NewBus(0)
+	stop := collectEvents(t, bus)
+	mock, r := newOrgRouterWithEvents(t, bus)
+	mock.ExpectQuery("SELECT.*FROM organization_members.*LEFT JOIN").
+		WillReturnRows(sqlmock.NewRows(orgMemberWithRoleCols))
+	mock.ExpectExec("DELETE FROM organization_members").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/organizations/org-1/members/user-9", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got := stop(); len(got) != 0 {
+		t.Errorf("events = %+v, want none", got)
+	}
+}
@@ -2,16 +2,20 @@
 package admin
 
 import (
+	"cmp"
 	"database/sql"
 	"log/slog"
 	"net/http"
-	"strconv"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/events"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
 
@@ -27,6 +31,11 @@ type OrganizationHandlers struct {
 	// carrying the old privileges until expiry (issue #559 finding [9]).
 	// May be nil in tests; revocation is skipped when unset.
 	userRevocations *repositories.UserTokenRevocationRepository
+	// statsRepo serves ?include=stats; stats are omitted when nil.
+	statsRepo *repositories.OrganizationStatsRepository
+	// events receives membership and role change events for the
+	// organization's event webhooks; nil drops them.
+	events *events.Bus
 }
 
 // NewOrganizationHandlers creates a new OrganizationHandlers instance. db
@@ -53,6 +62,20 @@ func NewOrganizationHandlers(cfg *config.Config, db *sql.DB, claimRepo *reposito
 	}
 }
 
+// WithStats sets the repository used to aggregate per-organization counts for
+// ?include=stats on the list and detail endpoints.
+func (h *OrganizationHandlers) WithStats(repo *repositories.OrganizationStatsRepository) *OrganizationHandlers {
+	h.statsRepo = repo
+	return h
+}
+
+// WithEvents sets the bus membership and role change events are published
+// on. Returns the handler for chaining.
+func (h *OrganizationHandlers) WithEvents(bus *events.Bus) *OrganizationHandlers {
+	h.events = bus
+	return h
+}
+
 // revokeUserTokens moves a user's revoke-all watermark after a privilege
 // change. Best-effort by design: the privilege change itself has already been
 // committed, so a failed revocation is logged loudly rather than turned into a
@@ -178,35 +201,63 @@ func (h *OrganizationHandlers) GetNamespaceOwnershipHandler() gin.HandlerFunc {
 }
 
 // @Summary      List organizations
-// @Description  Get a paginated list of all organizations.
+// @Description  Get a paginated list of all organizations. With `include=stats` each organization carries module, provider, member and mirror configuration counts plus its artifact storage footprint. Sorting by a stats column implies `include=stats`.
 // @Tags         Organizations
 // @Security     Bearer
 // @Produce      json
-// @Param        page      query  int  false  "Page number (default 1)"
-// @Param        per_page  query  int  false  "Items per page, max 100 (default 20)"
+// @Param        limit     query  int     false  "Items per page, max 100 (default 20); alias per_page"
+// @Param        offset    query  int     false  "Items to skip (default 0); alias page (1-based)"
+// @Param        include   query  string  false  "Set to 'stats' to include per-organization counts"
+// @Param        sort      query  string  false  "created_at (default), name, module_count, provider_count, member_count, mirror_config_count, storage_bytes"
+// @Param        order     query  string  false  "asc or desc (default desc; asc when sorting by name)"
 // @Success      200  {object}  admin.ListOrganizationsResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid sort or order"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/organizations [get]
 // ListOrganizationsHandler lists all organizations with pagination
-// GET /api/v1/organizations?page=1&per_page=20
+// GET /api/v1/organizations?page=1&per_page=20&include=stats&sort=storage_bytes
 func (h *OrganizationHandlers) ListOrganizationsHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Parse pagination parameters
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+		ctx := c.Request.Context()
+
+		page := pagination.Parse(c, defaultListLimits)
 
-		if page < 1 {
-			page = 1
+		sortBy := c.DefaultQuery("sort", "created_at")
+		statsKey, statSort := orgStatSortKeys[sortBy]
+		if !statSort && sortBy != "created_at" && sortBy != "name" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort column"})
+			return
+		}
+		order := c.DefaultQuery("order", "desc")
+		if sortBy == "name" && c.Query("order") == "" {
+			order = "asc"
 		}
-		if perPage < 1 || perPage > 100 {
-			perPage = 20
+		if order != "asc" && order != "desc" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+			return
 		}
+		includeStats := (c.Query("include") == "stats" || statSort) && h.statsRepo != nil
 
-		offset := (page - 1) * perPage
+		// The repository pages in created_at DESC order; any other order is
+		// applied over the full (small) organization set in memory, with the
+		// stats aggregated for all organizations in one grouped pass.
+		pageInDB := sortBy == "created_at" && order == "desc"
 
-		// Get organizations from repository
-		orgs, err := h.orgRepo.List(c.Request.Context(), perPage, offset)
+		var orgs []*models.Organization
+		var total int
+		var err error
+		if pageInDB {
+			orgs, err = h.orgRepo.List(ctx, page.Limit, page.Offset)
+			if err == nil {
+				total, err = h.orgRepo.Count(ctx)
+			}
+		} else {
+			total, err = h.orgRepo.Count(ctx)
+			if err == nil {
+				orgs, err = h.orgRepo.List(ctx, total, 0)
+			}
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to list organizations",
@@ -214,32 +265,97 @@ func (h *OrganizationHandlers) ListOrganizationsHandler() gin.HandlerFunc {
 			return
 		}
 
-		// Get total count
-		total, err := h.orgRepo.Count(c.Request.Context())
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to count organizations",
-			})
-			return
+		var stats map[string]*models.OrganizationStats
+		if includeStats {
+			var ids []string
+			if pageInDB {
+				ids = make([]string, 0, len(orgs))
+				for _, org := range orgs {
+					ids = append(ids, org.ID)
+				}
+			}
+			stats, err = h.statsRepo.ListStats(ctx, ids)
+			if err != nil {
+				slog.Error("failed to aggregate organization stats", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to aggregate organization stats",
+				})
+				return
+			}
+		}
+
+		if !pageInDB {
+			sortOrganizations(orgs, sortBy, statsKey, order == "desc", stats)
+			orgs = pagination.Window(page, orgs)
+		}
+
+		var items interface{} = orgs
+		if includeStats {
+			withStats := make([]OrganizationWithStats, 0, len(orgs))
+			for _, org := range orgs {
+				withStats = append(withStats, OrganizationWithStats{Organization: org, Stats: statsFor(stats, org.ID)})
+			}
+			items = withStats
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"organizations": orgs,
-			"pagination": gin.H{
-				"page":     page,
-				"per_page": perPage,
-				"total":    total,
-			},
+			"organizations": items,
+			"meta":          page.Meta(int64(total)),
+			"pagination":    newPaginationMeta(page, int64(total)),
 		})
 	}
 }
 
+// orgStatSortKeys maps the stats sort columns accepted by the list endpoint
+// to the value they order by.
+var orgStatSortKeys = map[string]func(*models.OrganizationStats) int64{
+	"module_count":        func(s *models.OrganizationStats) int64 { return s.ModuleCount },
+	"provider_count":      func(s *models.OrganizationStats) int64 { return s.ProviderCount },
+	"member_count":        func(s *models.OrganizationStats) int64 { return s.MemberCount },
+	"mirror_config_count": func(s *models.OrganizationStats) int64 { return s.MirrorConfigCount },
+	"storage_bytes":       func(s *models.OrganizationStats) int64 { return s.StorageBytes },
+}
+
+// sortOrganizations orders orgs by name, created_at, or a stats column. Ties
+// fall back to name so pages are stable.
+func sortOrganizations(orgs []*models.Organization, sortBy string, statKey func(*models.OrganizationStats) int64, desc bool, stats map[string]*models.OrganizationStats) {
+	less := func(a, b *models.Organization) int {
+		switch {
+		case statKey != nil:
+			return cmp.Compare(statKey(statsFor(stats, a.ID)), statKey(statsFor(stats, b.ID)))
+		case sortBy == "name":
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	}
+	slices.SortStableFunc(orgs, func(a, b *models.Organization) int {
+		c := less(a, b)
+		if desc {
+			c = -c
+		}
+		if c == 0 {
+			c = strings.Compare(a.Name, b.Name)
+		}
+		return c
+	})
+}
+
+// statsFor returns the stats of an organization, zeroed when it owns nothing.
+func statsFor(stats map[string]*models.OrganizationStats, orgID string) *models.OrganizationStats {
+	if s, ok := stats[orgID]; ok {
+		return s
+	}
+	return &models.OrganizationStats{}
+}
+
 // @Summary      Get organization
 // @Description  Retrieve a specific organization by its ID, including member list.
 // @Tags         Organizations
 // @Security     Bearer
 // @Produce      json
-// @Param        id  path  string  true  "Organization ID"
+// @Param        id       path   string  true   "Organization ID"
+// @Param        include  query  string  false  "Set to 'stats' to include usage counts"
 // @Success      200  {object}  admin.OrganizationWithMembersResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      404  {object}  map[string]interface{}  "Organization not found"
@@ -275,10 +391,23 @@ func (h *OrganizationHandlers) GetOrganizationHandler() gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		resp := gin.H{
 			"organization": org,
 			"members":      members,
-		})
+		}
+		if c.Query("include") == "stats" && h.statsRepo != nil {
+			stats, err := h.statsRepo.ListStats(c.Request.Context(), []string{org.ID})
+			if err != nil {
+				slog.Error("failed to aggregate organization stats", "org_id", org.ID, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to aggregate organization stats",
+				})
+				return
+			}
+			resp["stats"] = statsFor(stats, org.ID)
+		}
+
+		c.JSON(http.StatusOK, resp)
 	}
 }
 
@@ -725,6 +854,12 @@ func (h *OrganizationHandlers) AddMemberHandler() gin.HandlerFunc {
 			})
 			return
 		}
+		h.events.Publish(events.Event{
+			Type:           events.TypeMemberAdded,
+			OrganizationID: orgID,
+			ActorID:        eventActor(c),
+			Data:           memberEventData(req.UserID, req.RoleTemplateID),
+		})
 
 		// Get member with role template info for response
 		memberWithRole, err := h.orgRepo.GetMemberWithRole(c.Request.Context(), orgID, req.UserID)
@@ -817,6 +952,17 @@ func (h *OrganizationHandlers) UpdateMemberHandler() gin.HandlerFunc {
 		// finding [9]).
 		if !stringPtrEqual(oldRoleTemplateID, req.RoleTemplateID) {
 			h.revokeUserTokens(c, userID, "organization member role template changed")
+
+			data := memberEventData(userID, req.RoleTemplateID)
+			if oldRoleTemplateID != nil {
+				data["previous_role_template_id"] = *oldRoleTemplateID
+			}
+			h.events.Publish(events.Event{
+				Type:           events.TypeMemberRoleChanged,
+				OrganizationID: orgID,
+				ActorID:        eventActor(c),
+				Data:           data,
+			})
 		}
 
 		// Get member with role template info for response
@@ -860,11 +1006,12 @@ func (h *OrganizationHandlers) RemoveMemberHandler() gin.HandlerFunc {
 		// with no relationship to the target) would still revoke that user's
 		// tokens org-wide below -- letting any org admin log out an arbitrary
 		// user by targeting a removal that never actually changes anything.
-		// Skipped entirely when userRevocations is nil (as in most tests and
-		// any deployment that hasn't wired it up): the lookup's only purpose
-		// is deciding whether to call revokeUserTokens, which itself no-ops
-		// in that case, so running it unconditionally would add a hard
-		// dependency on an unrelated read query for no behavioral benefit.
+		// Skipped entirely when neither userRevocations nor events is wired
+		// up (as in most tests): the lookup's only purposes are deciding
+		// whether to call revokeUserTokens, which itself no-ops in that case,
+		// and whether to publish member_removed, so running it
+		// unconditionally would add a hard dependency on an unrelated read
+		// query for no behavioral benefit.
 		//
 		// A lookup failure is logged and treated as "membership unconfirmed"
 		// rather than blocking the removal: this query only feeds the
@@ -875,14 +1022,14 @@ func (h *OrganizationHandlers) RemoveMemberHandler() gin.HandlerFunc {
 		// caller below), never an unwarranted one.
 		var wasMember *models.OrganizationMemberWithUser
 		revocationCheckFailed := false
-		if h.userRevocations != nil {
+		if h.userRevocations != nil || h.events != nil {
 			var err error
 			wasMember, err = h.orgRepo.GetMemberWithRole(c.Request.Context(), orgID, userID)
 			if err != nil {
 				slog.Error("failed to check organization membership before removal; token revocation will be skipped",
 					"user_id", userID, "organization_id", orgID, "error", err)
 				wasMember = nil
-				revocationCheckFailed = true
+				revocationCheckFailed = h.userRevocations != nil
 			}
 		}
 
@@ -899,6 +1046,12 @@ func (h *OrganizationHandlers) RemoveMemberHandler() gin.HandlerFunc {
 		// [9]) -- but only when membership actually existed and was removed.
 		if wasMember != nil {
 			h.revokeUserTokens(c, userID, "removed from organization")
+			h.events.Publish(events.Event{
+				Type:           events.TypeMemberRemoved,
+				OrganizationID: orgID,
+				ActorID:        eventActor(c),
+				Data:           memberEventData(userID, wasMember.RoleTemplateID),
+			})
 		}
 
 		response := gin.H{"message": "Member removed successfully"}
@@ -912,6 +1065,16 @@ func (h *OrganizationHandlers) RemoveMemberHandler() gin.HandlerFunc {
 	}
 }
 
+// memberEventData is the data of a membership event: the member and, when
+// set, their role template.
+func memberEventData(userID string, roleTemplateID *string) map[string]string {
+	data := map[string]string{"user_id": userID}
+	if roleTemplateID != nil {
+		data["role_template_id"] = *roleTemplateID
+	}
+	return data
+}
+
 // stringPtrEqual reports whether two optional strings (role template IDs) are
 // equal, treating nil as distinct from any non-nil value including "".
 func stringPtrEqual(a, b *string) bool {
@@ -927,8 +1090,8 @@ func stringPtrEqual(a, b *string) bool {
 // @Security     Bearer
 // @Produce      json
 // @Param        q         query  string  true   "Search query"
-// @Param        page      query  int     false  "Page number (default 1)"
-// @Param        per_page  query  int     false  "Items per page, max 100 (default 20)"
+// @Param        limit     query  int     false  "Items per page, max 100 (default 20); alias per_page"
+// @Param        offset    query  int     false  "Items to skip (default 0); alias page (1-based)"
 // @Success      200  {object}  admin.ListOrganizationsResponse
 // @Failure      400  {object}  map[string]interface{}  "Search query is required"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
@@ -946,34 +1109,23 @@ func (h *OrganizationHandlers) SearchOrganizationsHandler() gin.HandlerFunc {
 			return
 		}
 
-		// Parse pagination
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
-
-		if page < 1 {
-			page = 1
-		}
-		if perPage < 1 || perPage > 100 {
-			perPage = 20
-		}
-
-		offset := (page - 1) * perPage
+		page := pagination.Parse(c, defaultListLimits)
 
-		// Search organizations
-		orgs, err := h.orgRepo.Search(c.Request.Context(), query, perPage, offset)
+		// Search organizations. The search is not counted; one extra row
+		// tells whether another page follows.
+		orgs, err := h.orgRepo.Search(c.Request.Context(), query, page.Limit+1, page.Offset)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to search organizations",
 			})
 			return
 		}
+		orgs, hasMore := pagination.Trim(page, orgs)
 
 		c.JSON(http.StatusOK, gin.H{
 			"organizations": orgs,
-			"pagination": gin.H{
-				"page":     page,
-				"per_page": perPage,
-			},
+			"meta":          pagination.Meta{Limit: page.Limit, Offset: page.Offset, HasMore: hasMore},
+			"pagination":    newPaginationMeta(page, 0),
 		})
 	}
 }
@@ -14,6 +14,7 @@ import (
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 )
 
@@ -248,10 +249,160 @@ func TestListOrganizations_DBError(t *testing.T) {
 	}
 }
 
+// newOrgStatsRouter wires the organization handlers with a stats repository
+// whose registry and identity sides share one mock connection.
+func newOrgStatsRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewOrganizationHandlers(&config.Config{}, db, repositories.NewNamespaceClaimRepository(db), nil).
+		WithStats(repositories.NewOrganizationStatsRepository(db, db))
+	r := gin.New()
+	r.GET("/organizations", h.ListOrganizationsHandler())
+	r.GET("/organizations/:id", h.GetOrganizationHandler())
+	return mock, r
+}
+
+var orgStatsCols = []string{"organization_id", "modules", "providers", "mirrors", "bytes"}
+var orgMemberCountCols = []string{"organization_id", "count"}
+
+func TestListOrganizations_IncludeStats(t *testing.T) {
+	mock, r := newOrgStatsRouter(t)
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*ORDER BY").
+		WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT COUNT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("WITH module_stats AS").
+		WillReturnRows(sqlmock.NewRows(orgStatsCols).AddRow("org-1", 4, 2, 1, 2048))
+	mock.ExpectQuery("FROM organization_members\\s+WHERE").
+		WillReturnRows(sqlmock.NewRows(orgMemberCountCols).AddRow("org-1", 3))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations?include=stats", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Organizations []struct {
+			ID    string                   `json:"id"`
+			Stats models.OrganizationStats `json:"stats"`
+		} `json:"organizations"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := models.OrganizationStats{ModuleCount: 4, ProviderCount: 2, MemberCount: 3, MirrorConfigCount: 1, StorageBytes: 2048}
+	if len(resp.Organizations) != 1 || resp.Organizations[0].ID != "org-1" || resp.Organizations[0].Stats != want {
+		t.Errorf("organizations = %+v", resp.Organizations)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestListOrganizations_WithoutIncludeSkipsStats(t *testing.T) {
+	mock, r := newOrgStatsRouter(t)
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*ORDER BY").
+		WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT COUNT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations", nil))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), `"stats"`) {
+		t.Errorf("status = %d body = %s; want no stats", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestListOrganizations_SortByStatColumn(t *testing.T) {
+	mock, r := newOrgStatsRouter(t)
+
+	mock.ExpectQuery("SELECT COUNT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT.*FROM organizations.*ORDER BY").
+		WithArgs(3, 0).
+		WillReturnRows(sqlmock.NewRows(orgCols).
+			AddRow("org-a", "alpha", "Alpha", nil, nil, time.Now(), time.Now()).
+			AddRow("org-b", "bravo", "Bravo", nil, nil, time.Now(), time.Now()).
+			AddRow("org-c", "charlie", "Charlie", nil, nil, time.Now(), time.Now()))
+	// Aggregated once for every organization (no per-page ID filter).
+	mock.ExpectQuery("WITH module_stats AS").
+		WithArgs(nil).
+		WillReturnRows(sqlmock.NewRows(orgStatsCols).
+			AddRow("org-a", 1, 0, 0, 100).
+			AddRow("org-c", 1, 0, 0, 900))
+	mock.ExpectQuery("FROM organization_members\\s+WHERE").
+		WillReturnRows(sqlmock.NewRows(orgMemberCountCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations?sort=storage_bytes&per_page=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Organizations []struct {
+			ID string `json:"id"`
+		} `json:"organizations"`
+		Pagination PaginationMeta `json:"pagination"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Organizations) != 2 || resp.Organizations[0].ID != "org-c" || resp.Organizations[1].ID != "org-a" {
+		t.Errorf("organizations = %+v, want [org-c org-a]", resp.Organizations)
+	}
+	if resp.Pagination.Total != 3 {
+		t.Errorf("total = %d, want 3", resp.Pagination.Total)
+	}
+}
+
+func TestListOrganizations_InvalidSort(t *testing.T) {
+	_, r := newOrgStatsRouter(t)
+
+	for _, q := range []string{"sort=password", "sort=name&order=sideways"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations?"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, w.Code)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // GetOrganizationHandler tests
 // ---------------------------------------------------------------------------
 
+func TestGetOrganization_IncludeStats(t *testing.T) {
+	mock, r := newOrgStatsRouter(t)
+
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM organization_members.*JOIN users").
+		WillReturnRows(emptyMembersWithUsersRows())
+	mock.ExpectQuery("WITH module_stats AS").
+		WillReturnRows(sqlmock.NewRows(orgStatsCols))
+	mock.ExpectQuery("FROM organization_members\\s+WHERE").
+		WillReturnRows(sqlmock.NewRows(orgMemberCountCols).AddRow("org-1", 2))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1?include=stats", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"member_count":2`) {
+		t.Errorf("body = %s, want stats with member_count 2", w.Body.String())
+	}
+}
+
 func TestGetOrganization_NotFound(t *testing.T) {
 	mock, r := newOrgRouter(t)
 
@@ -1269,7 +1420,7 @@ func TestListOrganizations_PaginationDefaults(t *testing.T) {
 	mock.ExpectQuery("SELECT COUNT.*FROM organizations").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-	// page < 1 and per_page > 100 should be clamped to defaults
+	// page < 1 and per_page > 100 fall back to page 1 and the 100 cap
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations?page=0&per_page=200", nil))
 
@@ -1423,7 +1574,7 @@ func TestSearchOrganizations_PaginationDefaults(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM organizations").
 		WillReturnRows(sampleOrgRow())
 
-	// page < 1 and per_page > 100 should be clamped to defaults
+	// page < 1 and per_page > 100 fall back to page 1 and the 100 cap
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/search?q=test&page=0&per_page=200", nil))
 
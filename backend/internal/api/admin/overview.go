@@ -0,0 +1,446 @@
+// overview.go implements the module and provider overview endpoints, which
+// assemble what a detail page needs (versions, SCM link, stats, dependents,
+// maintainers) in one call. Requested sections load concurrently, each within
+// its own time budget; a section that fails or runs out of time is reported
+// under "unavailable" instead of failing the whole response.
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// defaultOverviewSectionTimeout bounds how long an overview waits for any one
+// section.
+const defaultOverviewSectionTimeout = 5 * time.Second
+
+// overviewSCMEventLimit is how many recent webhook events the scm section
+// carries; the full log stays at GET /admin/modules/:id/scm/events.
+const overviewSCMEventLimit = 10
+
+// moduleOverviewSections and providerOverviewSections list the include names
+// each overview accepts, in the order they are loaded by default.
+var (
+	moduleOverviewSections   = []string{"versions", "scm", "stats", "dependents", "maintainers"}
+	providerOverviewSections = []string{"versions", "stats", "maintainers"}
+)
+
+// errOverviewNotConfigured marks a section whose backing feature is not set up
+// on this registry.
+var errOverviewNotConfigured = errors.New("not configured")
+
+// ModuleDependentsFunc looks up what consumes a module, for the module
+// overview's dependents section.
+type ModuleDependentsFunc func(ctx context.Context, namespace, name, system string) (any, error)
+
+// overviewSection loads one section of an overview.
+type overviewSection func(ctx context.Context) (any, error)
+
+// OverviewMaintainer is a user who created or published to a module or provider.
+type OverviewMaintainer struct {
+	UserID            string     `json:"user_id"`
+	Name              *string    `json:"name,omitempty"`
+	Creator           bool       `json:"creator"`
+	VersionsPublished int        `json:"versions_published"`
+	LastPublishedAt   *time.Time `json:"last_published_at,omitempty"`
+}
+
+// parseOverviewIncludes validates the include query parameter against the
+// supported section names. An empty value selects every section.
+func parseOverviewIncludes(raw string, supported []string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return supported, nil
+	}
+	var includes []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		known := false
+		for _, s := range supported {
+			if s == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown include %q (expected any of: %s)", name, strings.Join(supported, ", "))
+		}
+		seen[name] = true
+		includes = append(includes, name)
+	}
+	return includes, nil
+}
+
+// runOverviewSections loads the sections concurrently and returns the data of
+// those that finished within timeout, and why each of the others is missing.
+// It returns once every section has finished or the timeout has passed,
+// whichever comes first; a section still running then sees its context
+// cancelled, and its result is discarded.
+func runOverviewSections(ctx context.Context, timeout time.Duration, sections map[string]overviewSection) (map[string]any, map[string]string) {
+	if timeout <= 0 {
+		timeout = defaultOverviewSectionTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		data any
+		err  error
+	}
+	done := make(chan result, len(sections)) // buffered so a late section never blocks
+	for name, load := range sections {
+		go func(name string, load overviewSection) {
+			data, err := load(ctx)
+			done <- result{name: name, data: data, err: err}
+		}(name, load)
+	}
+
+	data := make(map[string]any, len(sections))
+	unavailable := map[string]string{}
+	for pending := len(sections); pending > 0; pending-- {
+		select {
+		case r := <-done:
+			switch {
+			case r.err == nil:
+				data[r.name] = r.data
+			case errors.Is(r.err, errOverviewNotConfigured):
+				unavailable[r.name] = r.err.Error()
+			case errors.Is(r.err, context.DeadlineExceeded):
+				unavailable[r.name] = "timed out"
+			default:
+				slog.Warn("overview section failed", "section", r.name, "error", r.err)
+				unavailable[r.name] = "failed to load"
+			}
+		case <-ctx.Done():
+			reason := "timed out"
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				reason = "request cancelled"
+			}
+			for name := range sections {
+				if _, ok := data[name]; !ok {
+					if _, ok := unavailable[name]; !ok {
+						unavailable[name] = reason
+					}
+				}
+			}
+			return data, unavailable
+		}
+	}
+	return data, unavailable
+}
+
+// overviewResponse merges the loaded sections into resp under their include
+// names. Sections that did not load are null and listed under "unavailable".
+func overviewResponse(resp gin.H, includes []string, data map[string]any, unavailable map[string]string) gin.H {
+	for _, name := range includes {
+		resp[name] = data[name]
+	}
+	resp["partial"] = len(unavailable) > 0
+	resp["unavailable"] = unavailable
+	return resp
+}
+
+// addMaintainer records a creation or publish by userID in byUser.
+func addMaintainer(byUser map[string]*OverviewMaintainer, userID, name *string, creator bool, publishedAt *time.Time) {
+	if userID == nil || *userID == "" {
+		return
+	}
+	m, ok := byUser[*userID]
+	if !ok {
+		m = &OverviewMaintainer{UserID: *userID}
+		byUser[*userID] = m
+	}
+	if m.Name == nil {
+		m.Name = name
+	}
+	if creator {
+		m.Creator = true
+	}
+	if publishedAt != nil {
+		m.VersionsPublished++
+		if m.LastPublishedAt == nil || publishedAt.After(*m.LastPublishedAt) {
+			at := *publishedAt
+			m.LastPublishedAt = &at
+		}
+	}
+}
+
+// sortedMaintainers orders maintainers by versions published, then by most
+// recent publish, with the creator first among equals.
+func sortedMaintainers(byUser map[string]*OverviewMaintainer) []*OverviewMaintainer {
+	out := make([]*OverviewMaintainer, 0, len(byUser))
+	for _, m := range byUser {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.VersionsPublished != b.VersionsPublished {
+			return a.VersionsPublished > b.VersionsPublished
+		}
+		if a.Creator != b.Creator {
+			return a.Creator
+		}
+		if (a.LastPublishedAt == nil) != (b.LastPublishedAt == nil) {
+			return a.LastPublishedAt != nil
+		}
+		if a.LastPublishedAt != nil && !a.LastPublishedAt.Equal(*b.LastPublishedAt) {
+			return a.LastPublishedAt.After(*b.LastPublishedAt)
+		}
+		return a.UserID < b.UserID
+	})
+	return out
+}
+
+// @Summary      Get module overview
+// @Description  Returns the module's detail fields under "module" plus the sections named in include (default: all), loaded concurrently: versions (as in the module detail), scm (repository link and recent webhook events, or linked false), stats (download totals per version), dependents (states consuming the module, from the sibling Suite app) and maintainers (the creator and every user who published a version). Each section has its own time budget; a section that fails, times out or is not configured is null, listed under "unavailable" with the reason, and sets "partial". Requires modules:read scope.
+// @Tags         Modules
+// @Security     Bearer
+// @Produce      json
+// @Param        namespace  path   string  true   "Module namespace"
+// @Param        name       path   string  true   "Module name"
+// @Param        system     path   string  true   "Target system (e.g. aws, azurerm)"
+// @Param        include    query  string  false  "Comma-separated sections: versions, scm, stats, dependents, maintainers (default: all)"
+// @Success      200  {object}  map[string]interface{}  "module, the requested sections, partial, unavailable"
+// @Failure      400  {object}  map[string]interface{}  "Unknown include"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Module not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/modules/{namespace}/{name}/{system}/overview [get]
+// GetModuleOverview returns a module's detail with the requested sections.
+// GET /api/v1/admin/modules/:namespace/:name/:system/overview
+func (h *ModuleAdminHandlers) GetModuleOverview(c *gin.Context) {
+	// The route shares its first wildcard with GET /admin/modules/:id, so gin
+	// names the namespace segment "id".
+	namespace := c.Param("id")
+	name := c.Param("name")
+	system := c.Param("system")
+
+	includes, err := parseOverviewIncludes(c.Query("include"), moduleOverviewSections)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	org, err := h.orgRepo.GetDefaultOrganization(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return
+	}
+	var orgID string
+	if org != nil {
+		orgID = org.ID
+	}
+
+	module, err := h.moduleRepo.GetModule(ctx, orgID, namespace, name, system)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get module"})
+		return
+	}
+	if module == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+
+	sections := make(map[string]overviewSection, len(includes))
+	for _, include := range includes {
+		switch include {
+		case "versions":
+			sections[include] = func(ctx context.Context) (any, error) {
+				versions, _, err := h.moduleVersionsList(ctx, module)
+				return versions, err
+			}
+		case "scm":
+			sections[include] = func(ctx context.Context) (any, error) { return h.moduleSCMSection(ctx, module) }
+		case "stats":
+			sections[include] = func(ctx context.Context) (any, error) { return h.moduleStatsSection(ctx, module) }
+		case "dependents":
+			sections[include] = func(ctx context.Context) (any, error) {
+				if h.dependents == nil {
+					return nil, errOverviewNotConfigured
+				}
+				return h.dependents(ctx, module.Namespace, module.Name, module.System)
+			}
+		case "maintainers":
+			sections[include] = func(ctx context.Context) (any, error) { return h.moduleMaintainersSection(ctx, module) }
+		}
+	}
+
+	// The module summary loads alongside the sections rather than before them.
+	summary := make(chan gin.H, 1)
+	go func() { summary <- h.moduleSummary(ctx, module) }()
+	data, unavailable := runOverviewSections(ctx, h.overviewTimeout, sections)
+	c.JSON(http.StatusOK, overviewResponse(gin.H{"module": <-summary}, includes, data, unavailable))
+}
+
+// moduleSCMSection returns the module's repository link and its most recent
+// webhook events.
+func (h *ModuleAdminHandlers) moduleSCMSection(ctx context.Context, module *models.Module) (any, error) {
+	if h.scmRepo == nil {
+		return nil, errOverviewNotConfigured
+	}
+	moduleID, err := uuid.Parse(module.ID)
+	if err != nil {
+		return nil, err
+	}
+	link, err := h.scmRepo.GetModuleSourceRepo(ctx, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return gin.H{"linked": false}, nil
+	}
+	events, err := h.scmRepo.ListWebhookLogs(ctx, link.ID, overviewSCMEventLimit)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{"linked": true, "link": link, "recent_events": events}, nil
+}
+
+// moduleStatsSection returns the module's download totals, per version by
+// downloads descending.
+func (h *ModuleAdminHandlers) moduleStatsSection(ctx context.Context, module *models.Module) (any, error) {
+	versions, err := h.moduleRepo.ListVersions(ctx, module.ID)
+	if err != nil {
+		return nil, err
+	}
+	perVersion := make([]gin.H, 0, len(versions))
+	var total int64
+	for _, v := range versions {
+		total += v.DownloadCount
+		perVersion = append(perVersion, gin.H{"version": v.Version, "downloads": v.DownloadCount})
+	}
+	sort.SliceStable(perVersion, func(i, j int) bool {
+		return perVersion[i]["downloads"].(int64) > perVersion[j]["downloads"].(int64)
+	})
+	return gin.H{"total_downloads": total, "version_count": len(versions), "versions": perVersion}, nil
+}
+
+// moduleMaintainersSection returns the module's creator and publishers.
+func (h *ModuleAdminHandlers) moduleMaintainersSection(ctx context.Context, module *models.Module) (any, error) {
+	versions, err := h.moduleRepo.ListVersions(ctx, module.ID)
+	if err != nil {
+		return nil, err
+	}
+	byUser := map[string]*OverviewMaintainer{}
+	addMaintainer(byUser, module.CreatedBy, module.CreatedByName, true, nil)
+	for _, v := range versions {
+		createdAt := v.CreatedAt
+		addMaintainer(byUser, v.PublishedBy, v.PublishedByName, false, &createdAt)
+	}
+	return sortedMaintainers(byUser), nil
+}
+
+// @Summary      Get provider overview
+// @Description  Returns the provider's detail fields under "provider" plus the sections named in include (default: all), loaded concurrently: versions (as in the provider detail), stats (download counts over the last 30 days, as GET /admin/providers/{namespace}/{type}/stats) and maintainers (the creator and every user who published a version). Each section has its own time budget; a section that fails or times out is null, listed under "unavailable" with the reason, and sets "partial". Requires providers:read scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Produce      json
+// @Param        namespace  path   string  true   "Provider namespace"
+// @Param        type       path   string  true   "Provider type (e.g. aws, azurerm)"
+// @Param        include    query  string  false  "Comma-separated sections: versions, stats, maintainers (default: all)"
+// @Success      200  {object}  map[string]interface{}  "provider, the requested sections, partial, unavailable"
+// @Failure      400  {object}  map[string]interface{}  "Unknown include"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{namespace}/{type}/overview [get]
+// GetProviderOverview returns a provider's detail with the requested sections.
+// GET /api/v1/admin/providers/:namespace/:type/overview
+func (h *ProviderAdminHandlers) GetProviderOverview(c *gin.Context) {
+	// The route shares its first wildcard with GET /admin/providers/:id, so
+	// gin names the namespace segment "id".
+	namespace := c.Param("id")
+	providerType := c.Param("type")
+
+	includes, err := parseOverviewIncludes(c.Query("include"), providerOverviewSections)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	org, err := h.orgRepo.GetDefaultOrganization(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return
+	}
+	var orgID string
+	if org != nil {
+		orgID = org.ID
+	}
+
+	provider, err := h.providerRepo.GetProvider(ctx, orgID, namespace, providerType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider"})
+		return
+	}
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+		return
+	}
+
+	sections := make(map[string]overviewSection, len(includes))
+	for _, include := range includes {
+		switch include {
+		case "versions":
+			sections[include] = func(ctx context.Context) (any, error) { return h.providerVersionsList(ctx, provider) }
+		case "stats":
+			sections[include] = func(ctx context.Context) (any, error) { return h.providerStatsSection(ctx, provider) }
+		case "maintainers":
+			sections[include] = func(ctx context.Context) (any, error) { return h.providerMaintainersSection(ctx, provider) }
+		}
+	}
+
+	summary := make(chan gin.H, 1)
+	go func() { summary <- h.providerSummary(ctx, provider) }()
+	data, unavailable := runOverviewSections(ctx, h.overviewTimeout, sections)
+	c.JSON(http.StatusOK, overviewResponse(gin.H{"provider": <-summary}, includes, data, unavailable))
+}
+
+// providerStatsSection returns the provider's download stats over the default
+// 30 day window.
+func (h *ProviderAdminHandlers) providerStatsSection(ctx context.Context, provider *models.Provider) (any, error) {
+	const window = "30d"
+	y, m, d := time.Now().UTC().Date()
+	since := time.Date(y, m, d-(providerStatsWindows[window]-1), 0, 0, 0, 0, time.UTC)
+	counts, err := h.providerRepo.GetDownloadStats(ctx, provider.ID, &since)
+	if err != nil {
+		return nil, err
+	}
+	resp := buildProviderStats(counts)
+	resp.Namespace = provider.Namespace
+	resp.Type = provider.Type
+	resp.Window = window
+	resp.Since = &since
+	return resp, nil
+}
+
+// providerMaintainersSection returns the provider's creator and publishers.
+func (h *ProviderAdminHandlers) providerMaintainersSection(ctx context.Context, provider *models.Provider) (any, error) {
+	versions, err := h.providerRepo.ListVersions(ctx, provider.ID)
+	if err != nil {
+		return nil, err
+	}
+	byUser := map[string]*OverviewMaintainer{}
+	addMaintainer(byUser, provider.CreatedBy, provider.CreatedByName, true, nil)
+	for _, v := range versions {
+		createdAt := v.CreatedAt
+		addMaintainer(byUser, v.PublishedBy, v.PublishedByName, false, &createdAt)
+	}
+	return sortedMaintainers(byUser), nil
+}
@@ -0,0 +1,166 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+func TestParseOverviewIncludes(t *testing.T) {
+	got, err := parseOverviewIncludes("", moduleOverviewSections)
+	if err != nil || len(got) != len(moduleOverviewSections) {
+		t.Errorf("empty include = %v, %v; want every section", got, err)
+	}
+	got, err = parseOverviewIncludes(" stats,versions,stats ", moduleOverviewSections)
+	if err != nil || len(got) != 2 || got[0] != "stats" || got[1] != "versions" {
+		t.Errorf("include = %v, %v; want [stats versions]", got, err)
+	}
+	if _, err := parseOverviewIncludes("versions,dependents", providerOverviewSections); err == nil {
+		t.Error("provider overview accepted the dependents include")
+	}
+}
+
+func TestRunOverviewSections_PartialResults(t *testing.T) {
+	sections := map[string]overviewSection{
+		"fast": func(context.Context) (any, error) { return "ok", nil },
+		"slow": func(ctx context.Context) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		// Ignores its context entirely; the overview must not wait for it.
+		"stuck":   func(context.Context) (any, error) { time.Sleep(time.Second); return "late", nil },
+		"broken":  func(context.Context) (any, error) { return nil, errors.New("boom") },
+		"missing": func(context.Context) (any, error) { return nil, errOverviewNotConfigured },
+	}
+
+	start := time.Now()
+	data, unavailable := runOverviewSections(context.Background(), 50*time.Millisecond, sections)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("overview took %s, want it bounded by the section timeout", elapsed)
+	}
+
+	if data["fast"] != "ok" || len(data) != 1 {
+		t.Errorf("data = %v, want only the fast section", data)
+	}
+	want := map[string]string{
+		"slow":    "timed out",
+		"stuck":   "timed out",
+		"broken":  "failed to load",
+		"missing": "not configured",
+	}
+	for name, reason := range want {
+		if unavailable[name] != reason {
+			t.Errorf("unavailable[%s] = %q, want %q", name, unavailable[name], reason)
+		}
+	}
+}
+
+func newModuleOverviewRouter(t *testing.T, dependents ModuleDependentsFunc) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewModuleAdminHandlers(db, &mockStorage{}, &config.Config{})
+	if dependents != nil {
+		h.WithDependents(dependents)
+	}
+	h.overviewTimeout = 50 * time.Millisecond
+
+	r := gin.New()
+	r.GET("/admin/modules/:id/:name/:system/overview", h.GetModuleOverview)
+	return mock, r
+}
+
+func TestGetModuleOverview_SectionsAndPartialMarker(t *testing.T) {
+	mock, r := newModuleOverviewRouter(t, func(_ context.Context, namespace, name, system string) (any, error) {
+		return gin.H{"module": namespace + "/" + name + "/" + system}, nil
+	})
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(sampleModuleRow())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/modules/hashicorp/vpc/aws/overview?include=dependents,scm", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Module      map[string]any    `json:"module"`
+		Dependents  map[string]any    `json:"dependents"`
+		SCM         any               `json:"scm"`
+		Versions    any               `json:"versions"`
+		Partial     bool              `json:"partial"`
+		Unavailable map[string]string `json:"unavailable"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Module["name"] != "vpc" || resp.Dependents["module"] != "hashicorp/vpc/aws" {
+		t.Errorf("module = %v, dependents = %v", resp.Module, resp.Dependents)
+	}
+	if resp.SCM != nil || !resp.Partial || resp.Unavailable["scm"] != "not configured" {
+		t.Errorf("scm = %v, partial = %v, unavailable = %v; want scm reported unavailable", resp.SCM, resp.Partial, resp.Unavailable)
+	}
+	if _, ok := resp.Unavailable["versions"]; ok {
+		t.Error("versions was not requested but is reported")
+	}
+}
+
+func TestGetModuleOverview_SlowSectionDoesNotBlock(t *testing.T) {
+	mock, r := newModuleOverviewRouter(t, func(ctx context.Context, _, _, _ string) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(sampleModuleRow())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/modules/hashicorp/vpc/aws/overview?include=dependents", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["partial"] != true || resp["unavailable"].(map[string]any)["dependents"] != "timed out" {
+		t.Errorf("response = %v, want dependents timed out", resp)
+	}
+}
+
+func TestGetModuleOverview_UnknownInclude(t *testing.T) {
+	_, r := newModuleOverviewRouter(t, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/modules/hashicorp/vpc/aws/overview?include=readme", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestSortedMaintainers(t *testing.T) {
+	alice, bob, carol := "alice", "bob", "carol"
+	older, newer := time.Now().Add(-time.Hour), time.Now()
+	byUser := map[string]*OverviewMaintainer{}
+	addMaintainer(byUser, &carol, nil, true, nil)
+	addMaintainer(byUser, &alice, nil, false, &older)
+	addMaintainer(byUser, &bob, nil, false, &older)
+	addMaintainer(byUser, &bob, nil, false, &newer)
+	addMaintainer(byUser, nil, nil, false, &newer) // unknown publisher is skipped
+
+	got := sortedMaintainers(byUser)
+	if len(got) != 3 || got[0].UserID != "bob" || got[1].UserID != "alice" || got[2].UserID != "carol" {
+		t.Fatalf("order = %+v, want bob, alice, carol", got)
+	}
+	if got[0].VersionsPublished != 2 || !got[0].LastPublishedAt.Equal(newer) || !got[2].Creator {
+		t.Errorf("maintainers = %+v", got)
+	}
+}
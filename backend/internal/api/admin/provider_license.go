@@ -0,0 +1,202 @@
+// provider_license.go implements admin handlers for provider license gating:
+// attaching a license to a provider, and listing and exporting the
+// acceptances users have recorded for audit.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// licenseProvider loads the provider named by the :id path parameter, writing
+// a 404 or 500 and returning nil when it cannot.
+func (h *ProviderAdminHandlers) licenseProvider(c *gin.Context) *models.Provider {
+	provider, err := h.providerRepo.GetProviderByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider"})
+		return nil
+	}
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+		return nil
+	}
+	return provider
+}
+
+// @Summary      Get provider license gate
+// @Description  Returns the license attached to a provider and whether its downloads are gated on accepting it. Requires providers:read scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Provider record UUID"
+// @Success      200  {object}  models.ProviderLicenseGate
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found or no license attached"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{id}/license [get]
+// GetProviderLicense returns the license attached to a provider
+// GET /api/v1/admin/providers/:id/license
+func (h *ProviderAdminHandlers) GetProviderLicense(c *gin.Context) {
+	provider := h.licenseProvider(c)
+	if provider == nil {
+		return
+	}
+
+	gate, err := h.licenseRepo.GetGate(c.Request.Context(), provider.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider license"})
+		return
+	}
+	if gate == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No license attached to this provider"})
+		return
+	}
+	c.JSON(http.StatusOK, gate)
+}
+
+// @Summary      Set provider license gate
+// @Description  Attaches license text to a provider, replacing any attached before. While enabled (the default), the provider's download and network mirror endpoints are served only to users who accepted license_version through POST /api/v1/providers/{namespace}/{type}/accept-license; anonymous requests get 403 and users who have not accepted get 451. Changing license_version requires every user to accept again. Requires providers:write scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                             true  "Provider record UUID"
+// @Param        body  body  models.ProviderLicenseGateRequest  true  "License"
+// @Success      200  {object}  models.ProviderLicenseGate
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{id}/license [put]
+// SetProviderLicense attaches a license to a provider
+// PUT /api/v1/admin/providers/:id/license
+func (h *ProviderAdminHandlers) SetProviderLicense(c *gin.Context) {
+	var req models.ProviderLicenseGateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.LicenseText) == "" || strings.TrimSpace(req.LicenseVersion) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "license_text and license_version must not be blank"})
+		return
+	}
+
+	provider := h.licenseProvider(c)
+	if provider == nil {
+		return
+	}
+
+	gate := &models.ProviderLicenseGate{
+		ProviderID:     provider.ID,
+		LicenseText:    req.LicenseText,
+		LicenseVersion: strings.TrimSpace(req.LicenseVersion),
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+	if userID := c.GetString("user_id"); userID != "" {
+		gate.UpdatedBy = &userID
+	}
+	if err := h.licenseRepo.UpsertGate(c.Request.Context(), gate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save provider license: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gate)
+}
+
+// @Summary      Remove provider license gate
+// @Description  Detaches the license from a provider, so its downloads are no longer gated. Recorded acceptances are kept for audit. Requires providers:write scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Provider record UUID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found or no license attached"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{id}/license [delete]
+// DeleteProviderLicense detaches the license from a provider
+// DELETE /api/v1/admin/providers/:id/license
+func (h *ProviderAdminHandlers) DeleteProviderLicense(c *gin.Context) {
+	provider := h.licenseProvider(c)
+	if provider == nil {
+		return
+	}
+
+	deleted, err := h.licenseRepo.DeleteGate(c.Request.Context(), provider.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete provider license"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No license attached to this provider"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Provider license removed"})
+}
+
+// @Summary      List provider license acceptances
+// @Description  Lists every license acceptance recorded for a provider, of any license version, oldest first. Requires audit:read scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Provider record UUID"
+// @Success      200  {object}  models.ProviderLicenseAcceptanceListResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{id}/license/acceptances [get]
+// ListProviderLicenseAcceptances lists the acceptances recorded for a provider
+// GET /api/v1/admin/providers/:id/license/acceptances
+func (h *ProviderAdminHandlers) ListProviderLicenseAcceptances(c *gin.Context) {
+	provider := h.licenseProvider(c)
+	if provider == nil {
+		return
+	}
+
+	acceptances, err := h.licenseRepo.ListAcceptances(c.Request.Context(), provider.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list license acceptances"})
+		return
+	}
+	c.JSON(http.StatusOK, models.ProviderLicenseAcceptanceListResponse{Acceptances: acceptances, TotalCount: len(acceptances)})
+}
+
+// @Summary      Export provider license acceptances
+// @Description  Downloads every license acceptance recorded for a provider as newline-delimited JSON (NDJSON), one record per line, for audit. Requires audit:read scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Produce      application/x-ndjson
+// @Param        id  path  string  true  "Provider record UUID"
+// @Success      200  {string}  string  "NDJSON stream of license acceptances"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{id}/license/acceptances/export [get]
+// ExportProviderLicenseAcceptances exports the acceptances recorded for a provider
+// GET /api/v1/admin/providers/:id/license/acceptances/export
+func (h *ProviderAdminHandlers) ExportProviderLicenseAcceptances(c *gin.Context) {
+	provider := h.licenseProvider(c)
+	if provider == nil {
+		return
+	}
+
+	acceptances, err := h.licenseRepo.ListAcceptances(c.Request.Context(), provider.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list license acceptances"})
+		return
+	}
+
+	filename := "license-acceptances-" + provider.Namespace + "-" + provider.Type + "-" + time.Now().UTC().Format("2006-01-02") + ".ndjson"
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+	for i := range acceptances {
+		_ = enc.Encode(acceptances[i]) // writes JSON + "\n"
+	}
+}
@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var licenseAcceptanceListCols = []string{
+	"id", "provider_id", "user_id", "license_version", "ip_address", "accepted_at", "email", "name",
+}
+
+func TestSetProviderLicense_BlankVersion(t *testing.T) {
+	_, r := newProviderRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/providers/id/prov-1/license",
+		bytes.NewBufferString(`{"license_text":"terms","license_version":"  "}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestSetProviderLicense_EnabledByDefault(t *testing.T) {
+	mock, r := newProviderRouter(t)
+	mock.ExpectQuery("SELECT.*FROM providers").WithArgs("prov-1").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("INSERT INTO provider_license_gates").
+		WithArgs("prov-1", "terms", "2026-01", true, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/providers/id/prov-1/license",
+		bytes.NewBufferString(`{"license_text":"terms","license_version":"2026-01"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var gate models.ProviderLicenseGate
+	if err := json.Unmarshal(w.Body.Bytes(), &gate); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !gate.Enabled || gate.ProviderID != "prov-1" {
+		t.Errorf("gate = %+v", gate)
+	}
+}
+
+func TestGetProviderLicense_NotAttached(t *testing.T) {
+	mock, r := newProviderRouter(t)
+	mock.ExpectQuery("SELECT.*FROM providers").WithArgs("prov-1").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_license_gates").WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows([]string{"provider_id", "license_text", "license_version", "enabled", "updated_by", "created_at", "updated_at"}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/id/prov-1/license", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestDeleteProviderLicense_NotAttached(t *testing.T) {
+	mock, r := newProviderRouter(t)
+	mock.ExpectQuery("SELECT.*FROM providers").WithArgs("prov-1").WillReturnRows(sampleProviderRow())
+	mock.ExpectExec("DELETE FROM provider_license_gates").WithArgs("prov-1").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/providers/id/prov-1/license", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestExportProviderLicenseAcceptances(t *testing.T) {
+	mock, r := newProviderRouter(t)
+	email := "dev@example.com"
+	mock.ExpectQuery("SELECT.*FROM providers").WithArgs("prov-1").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_license_acceptances").WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows(licenseAcceptanceListCols).
+			AddRow("acc-1", "prov-1", "user-1", "2026-01", "192.0.2.1", time.Now(), &email, nil).
+			AddRow("acc-2", "prov-1", "user-2", "2026-01", nil, time.Now(), nil, nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/id/prov-1/license/acceptances/export", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "license-acceptances-hashicorp-aws-") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	var lines []models.ProviderLicenseAcceptance
+	sc := bufio.NewScanner(w.Body)
+	for sc.Scan() {
+		var a models.ProviderLicenseAcceptance
+		if err := json.Unmarshal(sc.Bytes(), &a); err != nil {
+			t.Fatalf("decode line %q: %v", sc.Text(), err)
+		}
+		lines = append(lines, a)
+	}
+	if len(lines) != 2 || lines[0].UserEmail == nil || *lines[0].UserEmail != email || lines[1].UserID != "user-2" {
+		t.Errorf("exported = %+v", lines)
+	}
+}
@@ -0,0 +1,139 @@
+// provider_stats.go implements the admin provider download stats endpoint.
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// providerStatsWindows maps the stats window query parameter to the number of
+// days counted, today included. 0 counts every recorded day.
+var providerStatsWindows = map[string]int{
+	"7d":  7,
+	"30d": 30,
+	"90d": 90,
+	"all": 0,
+}
+
+// @Summary      Get provider download stats
+// @Description  Download counts for a provider over a window, broken down per version (and platform within each version) and per platform. Counts come from the provider download endpoint and the network mirror, recorded per UTC day since download stats were introduced; provider_platforms download_count keeps the lifetime totals. Requires providers:read scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Produce      json
+// @Param        namespace  path   string  true   "Provider namespace"
+// @Param        type       path   string  true   "Provider type (e.g. aws, azurerm)"
+// @Param        window     query  string  false  "Window to count over: 7d, 30d (default), 90d or all"
+// @Success      200  {object}  admin.ProviderStatsResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid window"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{namespace}/{type}/stats [get]
+// GetProviderStats returns download counts for a provider
+// GET /api/v1/admin/providers/:namespace/:type/stats
+func (h *ProviderAdminHandlers) GetProviderStats(c *gin.Context) {
+	// The route shares its first wildcard with GET /admin/providers/:id, so
+	// gin names the namespace segment "id".
+	namespace := c.Param("id")
+	providerType := c.Param("type")
+
+	window := c.DefaultQuery("window", "30d")
+	days, ok := providerStatsWindows[window]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid window (expected one of: 7d, 30d, 90d, all)"})
+		return
+	}
+
+	org, err := h.orgRepo.GetDefaultOrganization(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return
+	}
+	var orgID string
+	if org != nil {
+		orgID = org.ID
+	}
+
+	provider, err := h.providerRepo.GetProvider(c.Request.Context(), orgID, namespace, providerType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider"})
+		return
+	}
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+		return
+	}
+
+	var since *time.Time
+	if days > 0 {
+		y, m, d := time.Now().UTC().Date()
+		first := time.Date(y, m, d-(days-1), 0, 0, 0, 0, time.UTC)
+		since = &first
+	}
+
+	counts, err := h.providerRepo.GetDownloadStats(c.Request.Context(), provider.ID, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider download stats"})
+		return
+	}
+
+	resp := buildProviderStats(counts)
+	resp.Namespace = provider.Namespace
+	resp.Type = provider.Type
+	resp.Window = window
+	resp.Since = since
+	c.JSON(http.StatusOK, resp)
+}
+
+// buildProviderStats folds per version/platform counts into the per-version
+// and per-platform breakdowns, each ordered by downloads descending.
+func buildProviderStats(counts []models.ProviderDownloadCount) ProviderStatsResponse {
+	resp := ProviderStatsResponse{
+		Versions:  []ProviderVersionDownloads{},
+		Platforms: []ProviderPlatformDownloads{},
+	}
+
+	versionIdx := map[string]int{}
+	platformIdx := map[string]int{}
+	for _, c := range counts {
+		resp.TotalDownloads += c.Downloads
+
+		i, ok := versionIdx[c.Version]
+		if !ok {
+			i = len(resp.Versions)
+			versionIdx[c.Version] = i
+			resp.Versions = append(resp.Versions, ProviderVersionDownloads{Version: c.Version})
+		}
+		v := &resp.Versions[i]
+		v.Downloads += c.Downloads
+		v.Platforms = append(v.Platforms, ProviderPlatformDownloads{OS: c.OS, Arch: c.Arch, Downloads: c.Downloads})
+
+		key := c.OS + "/" + c.Arch
+		j, ok := platformIdx[key]
+		if !ok {
+			j = len(resp.Platforms)
+			platformIdx[key] = j
+			resp.Platforms = append(resp.Platforms, ProviderPlatformDownloads{OS: c.OS, Arch: c.Arch})
+		}
+		resp.Platforms[j].Downloads += c.Downloads
+	}
+
+	sort.SliceStable(resp.Versions, func(a, b int) bool {
+		return resp.Versions[a].Downloads > resp.Versions[b].Downloads
+	})
+	for i := range resp.Versions {
+		sortPlatformDownloads(resp.Versions[i].Platforms)
+	}
+	sortPlatformDownloads(resp.Platforms)
+	return resp
+}
+
+func sortPlatformDownloads(p []ProviderPlatformDownloads) {
+	sort.SliceStable(p, func(a, b int) bool {
+		return p[a].Downloads > p[b].Downloads
+	})
+}
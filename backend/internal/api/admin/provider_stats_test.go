@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+func newProviderStatsRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewProviderAdminHandlers(db, &mockStorage{}, &config.Config{})
+	r := gin.New()
+	// Same shape as the real route, which shares :id with GET /admin/providers/:id.
+	r.GET("/admin/providers/:id/:type/stats", h.GetProviderStats)
+	return mock, r
+}
+
+func TestGetProviderStats(t *testing.T) {
+	mock, r := newProviderStatsRouter(t)
+	expectOrgFound(mock)
+	mock.ExpectQuery("SELECT.*FROM providers").
+		WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT version, os, arch, SUM\\(downloads\\).*FROM provider_download_stats").
+		WithArgs("prov-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "os", "arch", "sum"}).
+			AddRow("5.0.0", "darwin", "arm64", 3).
+			AddRow("5.0.0", "linux", "amd64", 10).
+			AddRow("5.1.0", "linux", "amd64", 20))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/providers/hashicorp/aws/stats?window=7d", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp ProviderStatsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Namespace != "hashicorp" || resp.Type != "aws" || resp.Window != "7d" || resp.Since == nil {
+		t.Errorf("header fields = %+v", resp)
+	}
+	if resp.TotalDownloads != 33 {
+		t.Errorf("total_downloads = %d, want 33", resp.TotalDownloads)
+	}
+	wantVersions := []ProviderVersionDownloads{
+		{Version: "5.1.0", Downloads: 20, Platforms: []ProviderPlatformDownloads{{OS: "linux", Arch: "amd64", Downloads: 20}}},
+		{Version: "5.0.0", Downloads: 13, Platforms: []ProviderPlatformDownloads{
+			{OS: "linux", Arch: "amd64", Downloads: 10},
+			{OS: "darwin", Arch: "arm64", Downloads: 3},
+		}},
+	}
+	if !reflect.DeepEqual(resp.Versions, wantVersions) {
+		t.Errorf("versions = %+v, want %+v", resp.Versions, wantVersions)
+	}
+	wantPlatforms := []ProviderPlatformDownloads{
+		{OS: "linux", Arch: "amd64", Downloads: 30},
+		{OS: "darwin", Arch: "arm64", Downloads: 3},
+	}
+	if !reflect.DeepEqual(resp.Platforms, wantPlatforms) {
+		t.Errorf("platforms = %+v, want %+v", resp.Platforms, wantPlatforms)
+	}
+}
+
+func TestGetProviderStats_AllWindowHasNoSince(t *testing.T) {
+	mock, r := newProviderStatsRouter(t)
+	expectOrgFound(mock)
+	mock.ExpectQuery("SELECT.*FROM providers").
+		WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("FROM provider_download_stats").
+		WithArgs("prov-1", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "os", "arch", "sum"}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/providers/hashicorp/aws/stats?window=all", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	resp := getJSON(w)
+	if _, ok := resp["since"]; ok {
+		t.Errorf("since present for window=all: %v", resp["since"])
+	}
+	if v, _ := resp["versions"].([]interface{}); v == nil || len(v) != 0 {
+		t.Errorf("versions = %v, want empty list", resp["versions"])
+	}
+}
+
+func TestGetProviderStats_InvalidWindow(t *testing.T) {
+	_, r := newProviderStatsRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/providers/hashicorp/aws/stats?window=1y", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestGetProviderStats_ProviderNotFound(t *testing.T) {
+	mock, r := newProviderStatsRouter(t)
+	expectOrgFound(mock)
+	mock.ExpectQuery("SELECT.*FROM providers").
+		WillReturnRows(emptyProviderRow())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/providers/hashicorp/aws/stats", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
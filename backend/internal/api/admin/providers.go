@@ -2,13 +2,20 @@
 package admin
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/events"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
@@ -18,6 +25,13 @@ type ProviderAdminHandlers struct {
 	orgRepo        *repositories.OrganizationRepository
 	storageBackend storage.Storage
 	cfg            *config.Config
+	immutability   *services.ArtifactImmutability // optional; retention check on delete
+	mirrorBlobs    *services.MirrorBlobs          // optional; shared mirror archives
+	mirrorRepo     *repositories.MirrorRepository // optional; mirror reference check on delete
+	licenseRepo    *repositories.ProviderLicenseRepository
+	events         *events.Bus // optional; tier change events
+
+	overviewTimeout time.Duration // per-section overview budget; 0 selects defaultOverviewSectionTimeout
 }
 
 // NewProviderAdminHandlers creates a new provider admin handlers instance
@@ -25,13 +39,58 @@ func NewProviderAdminHandlers(db *sql.DB, storageBackend storage.Storage, cfg *c
 	return &ProviderAdminHandlers{
 		providerRepo:   repositories.NewProviderRepository(db),
 		orgRepo:        repositories.NewOrganizationRepository(db),
+		licenseRepo:    repositories.NewProviderLicenseRepository(db),
 		storageBackend: storageBackend,
 		cfg:            cfg,
 	}
 }
 
+// WithImmutability sets the artifact immutability policy that delete
+// requests are checked against.
+func (h *ProviderAdminHandlers) WithImmutability(a *services.ArtifactImmutability) *ProviderAdminHandlers {
+	h.immutability = a
+	return h
+}
+
+// WithMirrorBlobs releases the blob references of deleted mirrored platforms,
+// so a shared archive is only deleted with its last reference.
+func (h *ProviderAdminHandlers) WithMirrorBlobs(b *services.MirrorBlobs) *ProviderAdminHandlers {
+	h.mirrorBlobs = b
+	return h
+}
+
+// WithMirrorRepo enables the mirror reference check on provider deletion: a
+// provider a mirror configuration syncs is only deleted once detached from it.
+func (h *ProviderAdminHandlers) WithMirrorRepo(r *repositories.MirrorRepository) *ProviderAdminHandlers {
+	h.mirrorRepo = r
+	return h
+}
+
+// WithEvents sets the bus provider tier changes are published on. Returns the
+// handler for chaining.
+func (h *ProviderAdminHandlers) WithEvents(bus *events.Bus) *ProviderAdminHandlers {
+	h.events = bus
+	return h
+}
+
+// deletePlatformArchive deletes p's archive from storage. A mirrored archive
+// stored as a blob is released instead, and deleted only with its last
+// reference.
+func (h *ProviderAdminHandlers) deletePlatformArchive(ctx context.Context, p *models.ProviderPlatform) error {
+	if h.mirrorBlobs != nil {
+		if released, err := h.mirrorBlobs.Release(ctx, p.ID); released || err != nil {
+			return err
+		}
+	}
+	if storage.IsBlobKey(p.StoragePath) {
+		// Other platforms may share it; leave it for mirror-dedup to prune.
+		return nil
+	}
+	return h.storageBackend.Delete(ctx, p.StoragePath)
+}
+
 // @Summary      Get provider
-// @Description  Retrieve a provider with all its versions and platforms. No authentication required; authentication is optional and provides user context.
+// @Description  Retrieve a provider with all its versions and platforms, and its registry-assigned tier. Mirrored providers also include the upstream source_url, upstream_tier, and the license detected in the provider archive (license, license_file, license_text). No authentication required; authentication is optional and provides user context.
 // @Tags         Providers
 // @Produce      json
 // @Param        namespace  path  string  true  "Provider namespace"
@@ -70,67 +129,158 @@ func (h *ProviderAdminHandlers) GetProvider(c *gin.Context) {
 		return
 	}
 
-	// Get versions for the provider
-	versions, err := h.providerRepo.ListVersions(c.Request.Context(), provider.ID)
+	versionsList, err := h.providerVersionsList(c.Request.Context(), provider)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list provider versions"})
 		return
 	}
 
-	// Format versions
+	resp := h.providerSummary(c.Request.Context(), provider)
+	resp["versions"] = versionsList
+	c.JSON(http.StatusOK, resp)
+}
+
+// providerVersionsList returns a provider's versions, with their platforms,
+// formatted for the detail response.
+func (h *ProviderAdminHandlers) providerVersionsList(ctx context.Context, provider *models.Provider) ([]gin.H, error) {
+	versions, err := h.providerRepo.ListVersions(ctx, provider.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	versionsList := make([]gin.H, 0, len(versions))
 	for _, v := range versions {
-		platforms, _ := h.providerRepo.ListPlatforms(c.Request.Context(), v.ID)
-		platformsList := make([]gin.H, 0, len(platforms))
-		for _, p := range platforms {
-			platformsList = append(platformsList, gin.H{
-				"os":             p.OS,
-				"arch":           p.Arch,
-				"filename":       p.Filename,
-				"shasum":         p.Shasum,
-				"download_count": p.DownloadCount,
-			})
-		}
+		versionsList = append(versionsList, h.providerVersionData(ctx, v))
+	}
+	return versionsList, nil
+}
 
-		versionData := gin.H{
-			"id":         v.ID,
-			"version":    v.Version,
-			"protocols":  v.Protocols,
-			"platforms":  platformsList,
-			"deprecated": v.Deprecated,
-			"created_at": v.CreatedAt,
-		}
-		if v.DeprecatedAt != nil {
-			versionData["deprecated_at"] = v.DeprecatedAt
+// providerVersionData formats one provider version, with its platforms, for
+// the detail response and the version listing.
+func (h *ProviderAdminHandlers) providerVersionData(ctx context.Context, v *models.ProviderVersion) gin.H {
+	platforms, _ := h.providerRepo.ListPlatforms(ctx, v.ID)
+	provenance, _ := h.providerRepo.ListPlatformProvenance(ctx, v.ID)
+	platformsList := make([]gin.H, 0, len(platforms))
+	for _, p := range platforms {
+		platformData := gin.H{
+			"os":             p.OS,
+			"arch":           p.Arch,
+			"filename":       p.Filename,
+			"shasum":         p.Shasum,
+			"download_count": p.DownloadCount,
 		}
-		if v.DeprecationMessage != nil {
-			versionData["deprecation_message"] = v.DeprecationMessage
+		if up, ok := provenance[p.ID]; ok {
+			addUpstreamProvenance(platformData, up)
 		}
-		versionsList = append(versionsList, versionData)
+		platformsList = append(platformsList, platformData)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	versionData := gin.H{
+		"id":         v.ID,
+		"version":    v.Version,
+		"protocols":  v.Protocols,
+		"platforms":  platformsList,
+		"deprecated": v.Deprecated,
+		"created_at": v.CreatedAt,
+	}
+	if v.DeprecatedAt != nil {
+		versionData["deprecated_at"] = v.DeprecatedAt
+	}
+	if v.DeprecationMessage != nil {
+		versionData["deprecation_message"] = v.DeprecationMessage
+	}
+	return versionData
+}
+
+// addUpstreamProvenance adds the recorded fields of a mirrored platform's
+// upstream provenance to its detail entry.
+func addUpstreamProvenance(platform gin.H, up models.UpstreamProvenance) {
+	platform["provenance"] = up.Provenance
+	if up.UpstreamRegistry != nil {
+		platform["upstream_registry"] = *up.UpstreamRegistry
+	}
+	if up.UpstreamDownloadURL != nil {
+		platform["upstream_download_url"] = *up.UpstreamDownloadURL
+	}
+	if up.UpstreamSHASumURL != nil {
+		platform["upstream_shasum_url"] = *up.UpstreamSHASumURL
+	}
+	if up.FetchedAt != nil {
+		platform["fetched_at"] = *up.FetchedAt
+	}
+}
+
+// providerSummary returns the provider-level fields of the detail response.
+func (h *ProviderAdminHandlers) providerSummary(ctx context.Context, provider *models.Provider) gin.H {
+	resp := gin.H{
 		"id":          provider.ID,
 		"namespace":   provider.Namespace,
 		"type":        provider.Type,
 		"description": provider.Description,
 		"source":      provider.Source,
-		"versions":    versionsList,
 		"created_at":  provider.CreatedAt,
 		"updated_at":  provider.UpdatedAt,
-	})
+	}
+
+	// Upstream metadata only exists for mirrored providers; failing to load it
+	// leaves the fields out rather than failing the request.
+	meta, err := h.providerRepo.GetUpstreamMetadata(ctx, provider.ID)
+	if err != nil {
+		slog.Warn("failed to load provider upstream metadata", "provider_id", provider.ID, "error", err)
+	}
+	if meta != nil {
+		addUpstreamMetadata(resp, meta)
+	}
+	h.loadTier(ctx, provider)
+	if provider.Tier != "" {
+		resp["tier"] = provider.Tier
+	}
+	return resp
+}
+
+// loadTier fills in the provider's tier. A lookup failure leaves it unset
+// rather than failing the request.
+func (h *ProviderAdminHandlers) loadTier(ctx context.Context, provider *models.Provider) {
+	tiers, err := h.providerRepo.ListTiers(ctx, []string{provider.ID})
+	if err != nil {
+		slog.Warn("failed to load provider tier", "provider_id", provider.ID, "error", err)
+		return
+	}
+	provider.Tier = tiers[provider.ID]
+}
+
+// addUpstreamMetadata adds the non-null mirror-sync metadata fields to a
+// provider detail response.
+func addUpstreamMetadata(resp gin.H, meta *models.ProviderUpstreamMetadata) {
+	for key, value := range map[string]*string{
+		"source_url":    meta.SourceURL,
+		"upstream_tier": meta.UpstreamTier,
+		"license":       meta.License,
+		"license_file":  meta.LicenseFile,
+		"license_text":  meta.LicenseText,
+	} {
+		if value != nil {
+			resp[key] = *value
+		}
+	}
+	if meta.SyncedAt != nil {
+		resp["metadata_synced_at"] = meta.SyncedAt
+	}
 }
 
 // @Summary      Delete provider
-// @Description  Delete a provider and all its versions and platform binaries from storage. Requires providers:delete scope.
+// @Description  Delete a provider and all its versions and platform binaries from storage. A provider a mirror configuration syncs is refused with 409 and the names of those mirrors, unless detach=true is passed: the mirrors' tracking rows are then removed and their filters changed so the next sync does not recreate the provider (the type or namespace is dropped from the filter when that is exact, otherwise a "!namespace/type" exclusion is added to provider_filter). Requires providers:delete scope.
 // @Tags         Providers
 // @Security     Bearer
 // @Produce      json
-// @Param        namespace  path  string  true  "Provider namespace"
-// @Param        type       path  string  true  "Provider type (e.g. aws, azurerm)"
+// @Param        namespace  path   string  true   "Provider namespace"
+// @Param        type       path   string  true   "Provider type (e.g. aws, azurerm)"
+// @Param        detach     query  bool    false  "Detach the provider from the mirror configurations syncing it"
 // @Success      200  {object}  admin.MessageResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "A version is retained by artifact immutability"
 // @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      409  {object}  map[string]interface{}  "Provider is synced by mirror configurations (error, mirrors)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/providers/{namespace}/{type} [delete]
 // DeleteProvider deletes a provider and all its versions/platforms
@@ -138,6 +288,7 @@ func (h *ProviderAdminHandlers) GetProvider(c *gin.Context) {
 func (h *ProviderAdminHandlers) DeleteProvider(c *gin.Context) {
 	namespace := c.Param("namespace")
 	providerType := c.Param("type")
+	detach := c.Query("detach") == "true"
 
 	// Get organization context
 	org, err := h.orgRepo.GetDefaultOrganization(c.Request.Context())
@@ -163,35 +314,105 @@ func (h *ProviderAdminHandlers) DeleteProvider(c *gin.Context) {
 		return
 	}
 
+	// A mirror still syncing the provider would recreate it under a new ID
+	// on its next run, so refuse unless asked to detach it first.
+	var mirrorNames []string
+	if h.mirrorRepo != nil {
+		refs, err := h.mirrorRepo.ListMirrorsForProvider(c.Request.Context(), provider.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check mirror configurations"})
+			return
+		}
+		for _, ref := range refs {
+			mirrorNames = append(mirrorNames, ref.Name)
+		}
+		if len(mirrorNames) > 0 && !detach {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Provider is synced by mirror configurations; pass detach=true to detach and delete it",
+				"mirrors": mirrorNames,
+			})
+			return
+		}
+	}
+
+	if err := h.removeProvider(c.Request.Context(), provider, func(ctx context.Context) error {
+		if len(mirrorNames) == 0 {
+			return nil
+		}
+		_, err := h.mirrorRepo.DetachProvider(ctx, provider.ID, provider.Namespace, provider.Type)
+		return err
+	}); err != nil {
+		if errors.Is(err, storage.ErrArtifactImmutable) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete provider: " + err.Error()})
+		return
+	}
+
+	resp := gin.H{
+		"message":   "Provider deleted successfully",
+		"namespace": namespace,
+		"type":      providerType,
+	}
+	if len(mirrorNames) > 0 {
+		resp["detached_mirrors"] = mirrorNames
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// removeProvider deletes provider with its versions and platform archives.
+// When a version is still retained it fails with an error wrapping
+// storage.ErrArtifactImmutable before deleting anything. beforeDelete, when
+// non-nil, runs after the archives are deleted and before the provider row.
+func (h *ProviderAdminHandlers) removeProvider(ctx context.Context, provider *models.Provider, beforeDelete func(context.Context) error) error {
 	// Get all versions to delete their files from storage
-	versions, err := h.providerRepo.ListVersions(c.Request.Context(), provider.ID)
+	versions, err := h.providerRepo.ListVersions(ctx, provider.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list provider versions"})
-		return
+		return fmt.Errorf("failed to list provider versions: %w", err)
+	}
+
+	// Refuse before deleting anything if any version is still retained
+	for _, v := range versions {
+		if err := h.immutability.CheckVersionDelete(ctx, provider.OrganizationID, v.CreatedAt); err != nil {
+			return err
+		}
 	}
 
 	// Delete files from storage for each version
 	for _, v := range versions {
-		platforms, _ := h.providerRepo.ListPlatforms(c.Request.Context(), v.ID)
+		platforms, _ := h.providerRepo.ListPlatforms(ctx, v.ID)
 		for _, p := range platforms {
 			if p.StoragePath != "" {
 				// Try to delete from storage (ignore errors - file might not exist)
-				_ = h.storageBackend.Delete(c.Request.Context(), p.StoragePath)
+				if err := h.deletePlatformArchive(ctx, p); errors.Is(err, storage.ErrArtifactImmutable) {
+					return err
+				}
 			}
 		}
 	}
 
-	// Delete provider from database (cascades to versions and platforms)
-	if err := h.providerRepo.DeleteProvider(c.Request.Context(), provider.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete provider: " + err.Error()})
-		return
+	if beforeDelete != nil {
+		if err := beforeDelete(ctx); err != nil {
+			return err
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Provider deleted successfully",
-		"namespace": namespace,
-		"type":      providerType,
-	})
+	// Delete provider from database (cascades to versions and platforms)
+	return h.providerRepo.DeleteProvider(ctx, provider.ID)
+}
+
+// RemoveMirroredProvider deletes a provider a mirror configuration created,
+// with its archives, for mirror deletion with delete_providers=true.
+func (h *ProviderAdminHandlers) RemoveMirroredProvider(ctx context.Context, providerID string) error {
+	provider, err := h.providerRepo.GetProviderByID(ctx, providerID)
+	if err != nil {
+		return err
+	}
+	if provider == nil {
+		return nil
+	}
+	return h.removeProvider(ctx, provider, nil)
 }
 
 // @Summary      Delete provider version
@@ -204,6 +425,7 @@ func (h *ProviderAdminHandlers) DeleteProvider(c *gin.Context) {
 // @Param        version    path  string  true  "Semantic version (e.g. 1.2.3)"
 // @Success      200  {object}  admin.MessageResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Version is retained by artifact immutability"
 // @Failure      404  {object}  map[string]interface{}  "Provider or version not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/providers/{namespace}/{type}/versions/{version} [delete]
@@ -250,11 +472,18 @@ func (h *ProviderAdminHandlers) DeleteVersion(c *gin.Context) {
 		return
 	}
 
+	if !immutableDeleteAllowed(c, h.immutability, provider.OrganizationID, versionRecord.CreatedAt) {
+		return
+	}
+
 	// Delete files from storage
 	platforms, _ := h.providerRepo.ListPlatforms(c.Request.Context(), versionRecord.ID)
 	for _, p := range platforms {
 		if p.StoragePath != "" {
-			_ = h.storageBackend.Delete(c.Request.Context(), p.StoragePath)
+			if err := h.deletePlatformArchive(c.Request.Context(), p); errors.Is(err, storage.ErrArtifactImmutable) {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
 		}
 	}
 
@@ -594,5 +823,59 @@ func (h *ProviderAdminHandlers) GetProviderByID(c *gin.Context) {
 		return
 	}
 
+	h.loadTier(c.Request.Context(), provider)
 	c.JSON(http.StatusOK, provider)
 }
+
+// @Summary      List provider versions
+// @Description  List a provider's versions, with their platforms, with server-side filtering, sorting and pagination. Versions pending or rejected under mirror approval are included. pagination.total is the number of versions matching the filters. Requires providers:read scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Produce      json
+// @Param        id               path   string  true   "Provider record UUID"
+// @Param        deprecated       query  bool    false  "Only deprecated (true) or non-deprecated (false) versions"
+// @Param        published_after  query  string  false  "Only versions published after this RFC 3339 timestamp or YYYY-MM-DD date"
+// @Param        published_by     query  string  false  "Only versions published by this user ID"
+// @Param        constraint       query  string  false  "Only versions matching this version constraint (e.g. ~> 1.4)"
+// @Param        sort             query  string  false  "semver (default) or published"
+// @Param        order            query  string  false  "desc (default) or asc"
+// @Param        page             query  int     false  "Page number (default 1)"
+// @Param        per_page         query  int     false  "Items per page, max 100 (default 20)"
+// @Success      200  {object}  admin.ProviderVersionListResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid filter"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{id}/versions [get]
+// ListProviderVersions lists a provider's versions matching the query filters
+// GET /api/v1/admin/providers/:id/versions
+func (h *ProviderAdminHandlers) ListProviderVersions(c *gin.Context) {
+	filter, pagination, err := parseVersionListQuery(c, false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	provider, err := h.providerRepo.GetProviderByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider"})
+		return
+	}
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+		return
+	}
+
+	versions, total, err := h.providerRepo.ListVersionsFiltered(c.Request.Context(), provider.ID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list provider versions"})
+		return
+	}
+
+	versionsList := make([]gin.H, 0, len(versions))
+	for _, v := range versions {
+		versionsList = append(versionsList, h.providerVersionData(c.Request.Context(), v))
+	}
+	pagination.Total = int64(total)
+	c.JSON(http.StatusOK, gin.H{"versions": versionsList, "pagination": pagination})
+}
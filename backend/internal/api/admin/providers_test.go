@@ -6,12 +6,17 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
@@ -132,6 +137,12 @@ func newProviderRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
 	r.POST("/providers/record", h.CreateProviderRecord)
 	r.GET("/providers/id/:id", h.GetProviderByID)
 	r.PUT("/providers/id/:id", h.UpdateProviderRecord)
+	r.GET("/providers/id/:id/versions", h.ListProviderVersions)
+	r.GET("/providers/id/:id/license", h.GetProviderLicense)
+	r.PUT("/providers/id/:id/license", h.SetProviderLicense)
+	r.DELETE("/providers/id/:id/license", h.DeleteProviderLicense)
+	r.GET("/providers/id/:id/license/acceptances", h.ListProviderLicenseAcceptances)
+	r.GET("/providers/id/:id/license/acceptances/export", h.ExportProviderLicenseAcceptances)
 
 	return mock, r
 }
@@ -206,6 +217,33 @@ func TestGetProvider_Success_NoVersions(t *testing.T) {
 	}
 }
 
+func TestGetProvider_IncludesUpstreamMetadata(t *testing.T) {
+	mock, r := newProviderRouter(t)
+
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM providers").
+		WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_versions").
+		WillReturnRows(emptyVersionRows())
+	mock.ExpectQuery("SELECT source_url, upstream_tier, license.*FROM providers").
+		WillReturnRows(sqlmock.NewRows([]string{"source_url", "upstream_tier", "license", "license_file", "license_text", "upstream_metadata_synced_at", "license_checked_at"}).
+			AddRow("https://github.com/hashicorp/terraform-provider-aws", "official", "MPL-2.0", "LICENSE", "Mozilla Public License", time.Now(), time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/hashicorp/aws", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	resp := getJSON(w)
+	if resp["license"] != "MPL-2.0" || resp["upstream_tier"] != "official" || resp["license_text"] != "Mozilla Public License" {
+		t.Errorf("response missing upstream metadata: %v", resp)
+	}
+	if resp["metadata_synced_at"] == nil {
+		t.Error("response missing 'metadata_synced_at'")
+	}
+}
+
 func TestGetProvider_ProviderDBError(t *testing.T) {
 	mock, r := newProviderRouter(t)
 
@@ -376,6 +414,86 @@ func TestDeleteProvider_Success_WithVersionsAndPlatforms(t *testing.T) {
 	}
 }
 
+// newProviderRouterWithMirrors is newProviderRouter with the mirror reference
+// check enabled on provider deletion.
+func newProviderRouterWithMirrors(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewProviderAdminHandlers(db, &mockStorage{}, &config.Config{}).
+		WithMirrorRepo(repositories.NewMirrorRepository(sqlx.NewDb(db, "sqlmock")))
+	r := gin.New()
+	r.DELETE("/providers/:namespace/:type", h.DeleteProvider)
+	return mock, r
+}
+
+func TestDeleteProvider_MirrorConflict(t *testing.T) {
+	mock, r := newProviderRouterWithMirrors(t)
+
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM providers").
+		WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT mp.mirror_config_id, m.name.*FROM mirrored_providers").
+		WillReturnRows(sqlmock.NewRows([]string{"mirror_config_id", "name"}).AddRow(uuid.New(), "hashicorp-mirror"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/providers/hashicorp/aws", nil))
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"mirrors":["hashicorp-mirror"]`) {
+		t.Errorf("body = %s, want the mirror named", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestDeleteProvider_Detach(t *testing.T) {
+	mock, r := newProviderRouterWithMirrors(t)
+	cfgID := uuid.New()
+
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM providers").
+		WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT mp.mirror_config_id, m.name.*FROM mirrored_providers").
+		WillReturnRows(sqlmock.NewRows([]string{"mirror_config_id", "name"}).AddRow(cfgID, "hashicorp-mirror"))
+	mock.ExpectQuery("SELECT.*FROM provider_versions").
+		WillReturnRows(emptyVersionRows())
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mp.mirror_config_id, m.name.*FROM mirrored_providers").
+		WillReturnRows(sqlmock.NewRows([]string{"mirror_config_id", "name"}).AddRow(cfgID, "hashicorp-mirror"))
+	mock.ExpectQuery("SELECT namespace_filter, provider_filter.*FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace_filter", "provider_filter"}).
+			AddRow(`["hashicorp"]`, `["aws","google"]`))
+	mock.ExpectExec("UPDATE mirror_configurations").
+		WithArgs(cfgID, `["hashicorp"]`, `["google"]`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM mirrored_providers").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("DELETE FROM providers").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/providers/hashicorp/aws?detach=true", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"detached_mirrors":["hashicorp-mirror"]`) {
+		t.Errorf("body = %s, want the detached mirror named", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // DeleteVersion tests
 // ---------------------------------------------------------------------------
@@ -436,6 +554,62 @@ func TestDeleteVersion_Success(t *testing.T) {
 	}
 }
 
+func TestDeleteVersion_ReleasesMirrorBlobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	store := &mockStorage{}
+	h := NewProviderAdminHandlers(db, store, &config.Config{}).
+		WithMirrorBlobs(services.NewMirrorBlobs(repositories.NewMirrorBlobRepository(db), store))
+	r := gin.New()
+	r.DELETE("/providers/:namespace/:type/versions/:version", h.DeleteVersion)
+
+	shared := storage.BlobKey("aa")
+	unreferenced := storage.BlobKey("bb")
+	expectNoDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM providers").
+		WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE provider_id").
+		WillReturnRows(sampleVersionRow())
+	mock.ExpectQuery("SELECT.*FROM provider_platforms").
+		WillReturnRows(sqlmock.NewRows(platformCols).
+			AddRow("plat-1", "ver-1", "linux", "amd64", "p.zip", shared, "local", 1024, "aa", nil, 0).
+			AddRow("plat-2", "ver-1", "linux", "arm64", "p.zip", unreferenced, "local", 1024, "bb", nil, 0).
+			AddRow("plat-3", "ver-1", "darwin", "arm64", "p.zip", "providers/hashicorp/aws/5.0.0/darwin_arm64.zip", "local", 1024, "cc", nil, 0))
+	// plat-1's blob is still referenced by another organization's mirror.
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM mirror_blob_refs").WithArgs("plat-1").
+		WillReturnRows(sqlmock.NewRows([]string{"digest"}).AddRow("aa"))
+	mock.ExpectQuery("FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"storage_path"}).AddRow(shared))
+	mock.ExpectQuery("SELECT COUNT").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectCommit()
+	for _, id := range []string{"plat-2", "plat-3"} {
+		mock.ExpectBegin()
+		mock.ExpectQuery("DELETE FROM mirror_blob_refs").WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"digest"}))
+		mock.ExpectRollback()
+	}
+	mock.ExpectExec("DELETE FROM provider_versions").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/providers/hashicorp/aws/versions/5.0.0", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != "providers/hashicorp/aws/5.0.0/darwin_arm64.zip" {
+		t.Errorf("deleted = %v, want only the unshared archive", store.deleted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // DeprecateVersion tests
 // ---------------------------------------------------------------------------
@@ -1054,3 +1228,61 @@ func TestCreateProviderRecord_Success(t *testing.T) {
 		t.Errorf("status = %d, want 201: body=%s", w.Code, w.Body.String())
 	}
 }
+
+// ---------------------------------------------------------------------------
+// ListProviderVersions tests
+// ---------------------------------------------------------------------------
+
+func TestListProviderVersions_ArchivedIgnored(t *testing.T) {
+	mock, r := newProviderRouter(t)
+	mock.ExpectQuery("SELECT.*FROM providers").WithArgs("prov-1").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_versions").WithArgs("prov-1").WillReturnRows(emptyVersionRows())
+
+	// archived only applies to modules; providers ignore it.
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/id/prov-1/versions?archived=true", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestListProviderVersions_InvalidConstraint(t *testing.T) {
+	_, r := newProviderRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/id/prov-1/versions?constraint=%3E%3E1", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestListProviderVersions_NotFound(t *testing.T) {
+	mock, r := newProviderRouter(t)
+	mock.ExpectQuery("SELECT.*FROM providers").WithArgs("prov-999").WillReturnRows(emptyProviderRow())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/id/prov-999/versions", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestListProviderVersions_PublishedSortWithPlatforms(t *testing.T) {
+	mock, r := newProviderRouter(t)
+	mock.ExpectQuery("SELECT.*FROM providers").WithArgs("prov-1").WillReturnRows(sampleProviderRow())
+	now := time.Now()
+	protocols := []byte(`["6.0"]`)
+	mock.ExpectQuery("SELECT.*FROM provider_versions").WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows(versionCols).
+			AddRow("ver-2", "prov-1", "2.0.0", protocols, "", "", "", nil, nil, nil, nil, false, nil, nil, now.Add(-time.Hour)).
+			AddRow("ver-1", "prov-1", "1.0.0", protocols, "", "", "", nil, nil, nil, nil, false, nil, nil, now))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms").WithArgs("ver-1").WillReturnRows(emptyPlatformRows())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/id/prov-1/versions?sort=published&per_page=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"version":"1.0.0"`) || !strings.Contains(body, `"total":2`) {
+		t.Errorf("expected the most recently published version and total 2, got %s", body)
+	}
+}
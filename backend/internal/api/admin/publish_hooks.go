@@ -0,0 +1,297 @@
+// publish_hooks.go implements the per-organization pre-publish hook endpoints:
+// configuring the hook, testing it before enforcement is enabled, and reading
+// its delivery log. The hook itself is called by services.PublishHooks from
+// the upload handler and the SCM publisher.
+package admin
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+const (
+	defaultPublishHookTimeoutSeconds = 10
+	minPublishHookSecretLength       = 16
+	defaultPublishHookDeliveries     = 50
+	maxPublishHookDeliveries         = 500
+)
+
+// PublishHookHandlers serves the pre-publish hook endpoints.
+type PublishHookHandlers struct {
+	orgRepo     *repositories.OrganizationRepository
+	repo        *repositories.PublishHookRepository
+	hooks       *services.PublishHooks
+	tokenCipher *crypto.TokenCipher
+	// egress is consulted when validating the hook URL; nil enforces the
+	// strict default deny-list.
+	egress *httpsafe.Guard
+}
+
+// NewPublishHookHandlers constructs a PublishHookHandlers. identityDB backs
+// organizations; repo runs on the registry's own connection.
+func NewPublishHookHandlers(identityDB *sql.DB, repo *repositories.PublishHookRepository, hooks *services.PublishHooks, tokenCipher *crypto.TokenCipher) *PublishHookHandlers {
+	return &PublishHookHandlers{
+		orgRepo:     repositories.NewOrganizationRepository(identityDB),
+		repo:        repo,
+		hooks:       hooks,
+		tokenCipher: tokenCipher,
+	}
+}
+
+// WithEgressGuard installs the operator-configured egress guard
+// (security.egress.allowlist) consulted when validating the hook URL. Returns
+// the handler for chaining.
+func (h *PublishHookHandlers) WithEgressGuard(g *httpsafe.Guard) *PublishHookHandlers {
+	h.egress = g
+	return h
+}
+
+// UpdatePublishHookRequest is the body of PUT /organizations/:id/publish-hook.
+type UpdatePublishHookRequest struct {
+	// URL is the HTTPS endpoint that receives each module version publish.
+	URL string `json:"url"`
+	// Secret keys the HMAC-SHA256 request signature. Write-only: required
+	// when creating the hook, omit it to keep the current one.
+	Secret string `json:"secret"`
+	// TimeoutSeconds is how long each request may take (1-60, default 10).
+	TimeoutSeconds int `json:"timeout_seconds"`
+	// FailureMode is fail_closed (default: block the publish) or fail_open
+	// (allow it) when the endpoint gives no valid answer.
+	FailureMode string `json:"failure_mode"`
+	// Enabled turns on enforcement. Leave it off until a test succeeds.
+	Enabled bool `json:"enabled"`
+}
+
+func (req *UpdatePublishHookRequest) validate(guard *httpsafe.Guard) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("url must be a valid https URL")
+	}
+	if err := guard.ValidateURL(req.URL); err != nil {
+		return err
+	}
+	if req.Secret != "" && len(req.Secret) < minPublishHookSecretLength {
+		return fmt.Errorf("secret must be at least %d characters", minPublishHookSecretLength)
+	}
+	if req.TimeoutSeconds == 0 {
+		req.TimeoutSeconds = defaultPublishHookTimeoutSeconds
+	}
+	if req.TimeoutSeconds < 1 || req.TimeoutSeconds > int(services.MaxPublishHookTimeout.Seconds()) {
+		return fmt.Errorf("timeout_seconds must be between 1 and %d", int(services.MaxPublishHookTimeout.Seconds()))
+	}
+	switch req.FailureMode {
+	case "":
+		req.FailureMode = models.PublishHookFailClosed
+	case models.PublishHookFailClosed, models.PublishHookFailOpen:
+	default:
+		return fmt.Errorf(`failure_mode must be "fail_closed" or "fail_open"`)
+	}
+	return nil
+}
+
+// @Summary      Get organization publish hook
+// @Description  Returns the organization's pre-publish hook. The secret is never returned; has_secret reports that one is set.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  models.PublishHook
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "No publish hook configured"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/publish-hook [get]
+// GetHookHandler returns an organization's publish hook.
+// GET /api/v1/organizations/:id/publish-hook
+func (h *PublishHookHandlers) GetHookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hook, err := h.repo.Get(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve publish hook"})
+			return
+		}
+		if hook == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No publish hook configured"})
+			return
+		}
+		c.JSON(http.StatusOK, hook)
+	}
+}
+
+// @Summary      Set organization publish hook
+// @Description  Creates or replaces the organization's pre-publish hook. While enabled, every module version published into the organization (uploads, publishes from a URL, and SCM tag publishes; not mirror sync) is POSTed to the URL with its metadata and archive SHA-256, signed with HMAC-SHA256 in X-Registry-Signature-256, and must be answered with {"allow": bool, "reason": string} within timeout_seconds. A 5xx answer is retried once; when no valid answer arrives, failure_mode decides. The secret is write-only and required when creating the hook. The URL must be https and pass the egress policy.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                    true  "Organization ID"
+// @Param        body  body  UpdatePublishHookRequest  true  "Hook"
+// @Success      200  {object}  models.PublishHook
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Organization not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/publish-hook [put]
+// UpdateHookHandler creates or replaces an organization's publish hook.
+// PUT /api/v1/organizations/:id/publish-hook
+func (h *PublishHookHandlers) UpdateHookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdatePublishHookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+		if err := req.validate(h.egress); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		org, err := h.orgRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		current, err := h.repo.Get(c.Request.Context(), org.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve publish hook"})
+			return
+		}
+		hook := &models.PublishHook{
+			OrganizationID: org.ID,
+			URL:            req.URL,
+			TimeoutSeconds: req.TimeoutSeconds,
+			FailureMode:    req.FailureMode,
+			Enabled:        req.Enabled,
+		}
+		switch {
+		case req.Secret != "":
+			if h.tokenCipher == nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "No encryption key is configured to store the secret"})
+				return
+			}
+			if hook.EncryptedSecret, err = h.tokenCipher.Seal(req.Secret); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encrypt secret"})
+				return
+			}
+		case current != nil:
+			hook.EncryptedSecret = current.EncryptedSecret
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "secret is required when creating a publish hook"})
+			return
+		}
+
+		if err := h.repo.Upsert(c.Request.Context(), hook); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save publish hook"})
+			return
+		}
+		c.JSON(http.StatusOK, hook)
+	}
+}
+
+// @Summary      Delete organization publish hook
+// @Description  Removes the organization's pre-publish hook and its delivery log. Publishes are no longer checked.
+// @Tags         Organizations
+// @Security     Bearer
+// @Param        id  path  string  true  "Organization ID"
+// @Success      204  "Deleted"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "No publish hook configured"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/publish-hook [delete]
+// DeleteHookHandler removes an organization's publish hook.
+// DELETE /api/v1/organizations/:id/publish-hook
+func (h *PublishHookHandlers) DeleteHookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deleted, err := h.repo.Delete(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete publish hook"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No publish hook configured"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// @Summary      Test organization publish hook
+// @Description  Sends the configured hook a signed publish_hook.test event, then a copy signed with the wrong key, whether or not enforcement is enabled. ready is true when the endpoint answered the signed request with a valid decision and rejected the badly signed copy with a 4xx status. The signed request is recorded in the delivery log with source test.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  services.PublishHookTestResult
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "No publish hook configured"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/publish-hook/test [post]
+// TestHookHandler tests an organization's publish hook.
+// POST /api/v1/organizations/:id/publish-hook/test
+func (h *PublishHookHandlers) TestHookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hook, err := h.repo.Get(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve publish hook"})
+			return
+		}
+		if hook == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No publish hook configured"})
+			return
+		}
+		c.JSON(http.StatusOK, h.hooks.Test(c.Request.Context(), hook))
+	}
+}
+
+// @Summary      List organization publish hook deliveries
+// @Description  Returns the most recent calls to the organization's pre-publish hook, newest first, with their outcome (allowed, denied, failed_open, failed_closed), the endpoint's reason, the last status code, the number of attempts, and why no valid answer arrived when one did not.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id     path   string   true   "Organization ID"
+// @Param        limit  query  integer  false  "Maximum deliveries to return (default 50, max 500)"
+// @Success      200  {object}  map[string]interface{}  "deliveries: []models.PublishHookDelivery"
+// @Failure      400  {object}  map[string]interface{}  "Invalid limit"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/publish-hook/deliveries [get]
+// ListDeliveriesHandler lists an organization's publish hook deliveries.
+// GET /api/v1/organizations/:id/publish-hook/deliveries
+func (h *PublishHookHandlers) ListDeliveriesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := defaultPublishHookDeliveries
+		if raw := c.Query("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > maxPublishHookDeliveries {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxPublishHookDeliveries)})
+				return
+			}
+			limit = n
+		}
+		deliveries, err := h.repo.ListDeliveries(c.Request.Context(), c.Param("id"), limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list publish hook deliveries"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+	}
+}
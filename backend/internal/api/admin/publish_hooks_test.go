@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+)
+
+var publishHookCols = []string{"organization_id", "url", "encrypted_secret", "timeout_seconds", "failure_mode", "enabled", "created_at", "updated_at"}
+
+func newPublishHookRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	cipher, _ := crypto.NewTokenCipher(bytes.Repeat([]byte("k"), 32))
+
+	// Hook URLs are IP literals on the allowlist so validation needs no DNS.
+	h := NewPublishHookHandlers(db, repositories.NewPublishHookRepository(db), nil, cipher).
+		WithEgressGuard(httpsafe.MustGuard("203.0.113.10"))
+	r := gin.New()
+	r.GET("/organizations/:id/publish-hook", h.GetHookHandler())
+	r.PUT("/organizations/:id/publish-hook", h.UpdateHookHandler())
+	return mock, r
+}
+
+func putPublishHook(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/organizations/org-1/publish-hook", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestUpdatePublishHook_Validation(t *testing.T) {
+	_, r := newPublishHookRouter(t)
+	for name, body := range map[string]string{
+		"http url":     `{"url":"http://203.0.113.10/hook","secret":"0123456789abcdef"}`,
+		"private url":  `{"url":"https://10.0.0.1/hook","secret":"0123456789abcdef"}`,
+		"short secret": `{"url":"https://203.0.113.10/hook","secret":"short"}`,
+		"timeout":      `{"url":"https://203.0.113.10/hook","secret":"0123456789abcdef","timeout_seconds":61}`,
+		"failure mode": `{"url":"https://203.0.113.10/hook","secret":"0123456789abcdef","failure_mode":"retry"}`,
+		"invalid json": `{`,
+	} {
+		if w := putPublishHook(r, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400; body = %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestUpdatePublishHook_SecretRequiredOnCreate(t *testing.T) {
+	mock, r := newPublishHookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM publish_hooks").WillReturnRows(sqlmock.NewRows(publishHookCols))
+
+	w := putPublishHook(r, `{"url":"https://203.0.113.10/hook"}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "secret is required") {
+		t.Errorf("status = %d, body = %s; want 400 secret is required", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdatePublishHook_KeepsSecret(t *testing.T) {
+	mock, r := newPublishHookRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM publish_hooks").
+		WillReturnRows(sqlmock.NewRows(publishHookCols).AddRow("org-1", "https://203.0.113.10/old", "sealed-secret", 10, "fail_closed", false, time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO publish_hooks").
+		WithArgs("org-1", "https://203.0.113.10/hook", "sealed-secret", 10, models.PublishHookFailOpen, true).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+	w := putPublishHook(r, `{"url":"https://203.0.113.10/hook","failure_mode":"fail_open","enabled":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var hook map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &hook)
+	if _, leaked := hook["encrypted_secret"]; leaked || hook["has_secret"] != true {
+		t.Errorf("response = %v, want has_secret and no secret", hook)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,10 +15,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/notify"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 )
 
 // RBACHandlers handles RBAC-related API endpoints
@@ -39,6 +44,14 @@ type RBACHandlers struct {
 	// channels (webhook/Slack/Teams/email), in addition to the direct
 	// recipients email above. Set via WithNotifier; nil is a no-op.
 	notifier *notify.Notifier
+	// approvals applies auto_apply approvals to the mirror's filters and
+	// backs the revoke endpoint. Set via WithApprovalProvisioner; when nil,
+	// ReviewApproval only changes the request's status and revocation is
+	// unavailable.
+	approvals *services.MirrorApprovalProvisioner
+	// staged routes role template and mirror policy changes through the
+	// two-person rule. Set via WithStagedChanges; nil applies them directly.
+	staged *StagedChanges
 }
 
 // NewRBACHandlers creates a new RBAC handlers instance
@@ -64,22 +77,40 @@ func (h *RBACHandlers) WithNotifier(n *notify.Notifier) *RBACHandlers {
 	return h
 }
 
+// WithApprovalProvisioner wires in the provisioner that applies auto_apply
+// approvals to mirror filters and handles revocation. Returns the handler for
+// chaining.
+func (h *RBACHandlers) WithApprovalProvisioner(p *services.MirrorApprovalProvisioner) *RBACHandlers {
+	h.approvals = p
+	return h
+}
+
+// WithStagedChanges routes role template and mirror policy changes through
+// the two-person rule and registers their appliers. Returns the handler for
+// chaining.
+func (h *RBACHandlers) WithStagedChanges(s *StagedChanges) *RBACHandlers {
+	h.staged = s
+	s.Register(models.StagedResourceRoleTemplate, h.applyStagedRoleTemplate)
+	s.Register(models.StagedResourceMirrorPolicy, h.applyStagedMirrorPolicy)
+	return h
+}
+
 // revokeRoleTemplateMemberTokens revokes the outstanding tokens of every member
 // currently assigned roleTemplateID. Best-effort: the scope edit has already
 // been committed, so a lookup or revocation failure is logged rather than
 // turned into a misleading error response for an otherwise-successful edit.
-func (h *RBACHandlers) revokeRoleTemplateMemberTokens(c *gin.Context, roleTemplateID uuid.UUID, reason string) {
+func (h *RBACHandlers) revokeRoleTemplateMemberTokens(ctx context.Context, roleTemplateID uuid.UUID, reason string) {
 	if h.userRevocations == nil {
 		return
 	}
-	userIDs, err := h.rbacRepo.ListRoleTemplateMemberUserIDs(c.Request.Context(), roleTemplateID)
+	userIDs, err := h.rbacRepo.ListRoleTemplateMemberUserIDs(ctx, roleTemplateID)
 	if err != nil {
 		slog.Error("failed to list role template members for token revocation",
 			"role_template_id", roleTemplateID, "reason", reason, "error", err)
 		return
 	}
 	for _, userID := range userIDs {
-		if err := h.userRevocations.RevokeAllUserTokens(c.Request.Context(), userID); err != nil {
+		if err := h.userRevocations.RevokeAllUserTokens(ctx, userID); err != nil {
 			slog.Error("failed to revoke user tokens after role template change",
 				"user_id", userID, "role_template_id", roleTemplateID, "reason", reason, "error", err)
 		}
@@ -189,6 +220,26 @@ func (h *RBACHandlers) GetRoleTemplate(c *gin.Context) {
 	c.JSON(http.StatusOK, template)
 }
 
+// ScopeCatalogResponse is returned by GET /api/v1/admin/role-templates/scopes.
+type ScopeCatalogResponse struct {
+	Scopes []auth.ScopeInfo `json:"scopes"`
+}
+
+// @Summary      List assignable scopes
+// @Description  Returns the scope catalog: every scope a role template or API key can carry, with a description and the other scopes that satisfy it besides admin (its write/manage pair, and for binaries:read / binaries:manage the mirrors scopes they were split out of). Requires admin scope.
+// @Tags         RBAC
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  ScopeCatalogResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden - requires admin scope"
+// @Router       /api/v1/admin/role-templates/scopes [get]
+// ListScopes returns the scope catalog
+// GET /api/v1/admin/role-templates/scopes
+func (h *RBACHandlers) ListScopes(c *gin.Context) {
+	c.JSON(http.StatusOK, ScopeCatalogResponse{Scopes: auth.ScopeCatalog()})
+}
+
 // CreateRoleTemplateRequest represents the request to create a role template
 type CreateRoleTemplateRequest struct {
 	Name        string   `json:"name" binding:"required"`
@@ -198,14 +249,15 @@ type CreateRoleTemplateRequest struct {
 }
 
 // @Summary      Create role template
-// @Description  Create a new custom RBAC role template with specified scopes. Requires admin scope.
+// @Description  Create a new custom RBAC role template with specified scopes. Requires admin scope. While the two-person rule is on, the change is staged and 202 is returned with the staged change for another admin to approve.
 // @Tags         RBAC
 // @Security     Bearer
 // @Accept       json
 // @Produce      json
 // @Param        body  body  CreateRoleTemplateRequest  true  "Role template"
 // @Success      201  {object}  models.RoleTemplateView
-// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Success      202  {object}  models.StagedChange
+// @Failure      400  {object}  map[string]interface{}  "Invalid request or scopes"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      409  {object}  map[string]interface{}  "Role template with this name already exists"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
@@ -218,16 +270,28 @@ func (h *RBACHandlers) CreateRoleTemplate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := auth.ValidateScopes(req.Scopes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scopes: " + err.Error()})
+		return
+	}
 
+	h.staged.Submit(c, StagedChangeInput{
+		ResourceType: models.StagedResourceRoleTemplate,
+		Action:       models.StagedActionCreate,
+		After:        req,
+	}, http.StatusCreated, func(ctx context.Context) (interface{}, error) {
+		return h.createRoleTemplate(ctx, req)
+	})
+}
+
+func (h *RBACHandlers) createRoleTemplate(ctx context.Context, req CreateRoleTemplateRequest) (*models.RoleTemplate, error) {
 	// Check if name already exists
-	existing, err := h.rbacRepo.GetRoleTemplateByName(c.Request.Context(), req.Name)
+	existing, err := h.rbacRepo.GetRoleTemplateByName(ctx, req.Name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing template"})
-		return
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to check existing template")
 	}
 	if existing != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Role template with this name already exists"})
-		return
+		return nil, newStagedApplyError(http.StatusConflict, "Role template with this name already exists")
 	}
 
 	template := &models.RoleTemplate{
@@ -241,16 +305,14 @@ func (h *RBACHandlers) CreateRoleTemplate(c *gin.Context) {
 		UpdatedAt:   time.Now(),
 	}
 
-	if err := h.rbacRepo.CreateRoleTemplate(c.Request.Context(), template); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role template"})
-		return
+	if err := h.rbacRepo.CreateRoleTemplate(ctx, template); err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to create role template")
 	}
-
-	c.JSON(http.StatusCreated, template)
+	return template, nil
 }
 
 // @Summary      Update role template
-// @Description  Update an existing custom role template. Cannot modify system role templates. Requires admin scope.
+// @Description  Update an existing custom role template. Cannot modify system role templates. Requires admin scope. While the two-person rule is on, the change is staged and 202 is returned with the staged change for another admin to approve.
 // @Tags         RBAC
 // @Security     Bearer
 // @Accept       json
@@ -258,7 +320,8 @@ func (h *RBACHandlers) CreateRoleTemplate(c *gin.Context) {
 // @Param        id    path  string                    true  "Role template ID (UUID)"
 // @Param        body  body  CreateRoleTemplateRequest  true  "Updated role template"
 // @Success      200  {object}  models.RoleTemplateView
-// @Failure      400  {object}  map[string]interface{}  "Invalid request or ID"
+// @Success      202  {object}  models.StagedChange
+// @Failure      400  {object}  map[string]interface{}  "Invalid request, scopes, or ID"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Cannot modify system role templates"
 // @Failure      404  {object}  map[string]interface{}  "Role template not found"
@@ -274,17 +337,9 @@ func (h *RBACHandlers) UpdateRoleTemplate(c *gin.Context) {
 		return
 	}
 
-	existing, err := h.rbacRepo.GetRoleTemplate(c.Request.Context(), id)
+	existing, err := h.getCustomRoleTemplate(c.Request.Context(), id, "modify")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get role template"})
-		return
-	}
-	if existing == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Role template not found"})
-		return
-	}
-	if existing.IsSystem {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot modify system role templates"})
+		writeStagedApplyError(c, err)
 		return
 	}
 
@@ -293,7 +348,39 @@ func (h *RBACHandlers) UpdateRoleTemplate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := auth.ValidateScopes(req.Scopes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scopes: " + err.Error()})
+		return
+	}
 
+	h.staged.Submit(c, StagedChangeInput{
+		ResourceType: models.StagedResourceRoleTemplate,
+		ResourceID:   id.String(),
+		Action:       models.StagedActionUpdate,
+		Before:       existing,
+		After:        req,
+	}, http.StatusOK, func(ctx context.Context) (interface{}, error) {
+		return h.updateRoleTemplate(ctx, existing, req)
+	})
+}
+
+// getCustomRoleTemplate loads a role template that verb ("modify" or
+// "delete") may be applied to: it must exist and not be a system template.
+func (h *RBACHandlers) getCustomRoleTemplate(ctx context.Context, id uuid.UUID, verb string) (*models.RoleTemplate, error) {
+	existing, err := h.rbacRepo.GetRoleTemplate(ctx, id)
+	if err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to get role template")
+	}
+	if existing == nil {
+		return nil, newStagedApplyError(http.StatusNotFound, "Role template not found")
+	}
+	if existing.IsSystem {
+		return nil, newStagedApplyError(http.StatusForbidden, "Cannot "+verb+" system role templates")
+	}
+	return existing, nil
+}
+
+func (h *RBACHandlers) updateRoleTemplate(ctx context.Context, existing *models.RoleTemplate, req CreateRoleTemplateRequest) (*models.RoleTemplate, error) {
 	scopesChanged := !stringSlicesEqual(existing.Scopes, req.Scopes)
 
 	existing.DisplayName = req.DisplayName
@@ -301,9 +388,8 @@ func (h *RBACHandlers) UpdateRoleTemplate(c *gin.Context) {
 	existing.Scopes = req.Scopes
 	existing.UpdatedAt = time.Now()
 
-	if err := h.rbacRepo.UpdateRoleTemplate(c.Request.Context(), existing); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role template"})
-		return
+	if err := h.rbacRepo.UpdateRoleTemplate(ctx, existing); err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to update role template")
 	}
 
 	// A scope edit changes what a fresh JWT would embed for every member
@@ -312,10 +398,9 @@ func (h *RBACHandlers) UpdateRoleTemplate(c *gin.Context) {
 	// (issue #559 finding [9]). Display-name/description-only edits don't
 	// affect scopes, so skip the revocation sweep for those.
 	if scopesChanged {
-		h.revokeRoleTemplateMemberTokens(c, id, "role template scopes edited")
+		h.revokeRoleTemplateMemberTokens(ctx, existing.ID, "role template scopes edited")
 	}
-
-	c.JSON(http.StatusOK, existing)
+	return existing, nil
 }
 
 // stringSlicesEqual reports whether two scope slices contain the same elements
@@ -335,12 +420,13 @@ func stringSlicesEqual(a, b []string) bool {
 }
 
 // @Summary      Delete role template
-// @Description  Delete a custom role template. Cannot delete system role templates. Requires admin scope.
+// @Description  Delete a custom role template. Cannot delete system role templates. Requires admin scope. While the two-person rule is on, the change is staged and 202 is returned with the staged change for another admin to approve.
 // @Tags         RBAC
 // @Security     Bearer
 // @Produce      json
 // @Param        id  path  string  true  "Role template ID (UUID)"
 // @Success      200  {object}  admin.MessageResponse
+// @Success      202  {object}  models.StagedChange
 // @Failure      400  {object}  map[string]interface{}  "Invalid role template ID"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Cannot delete system role templates"
@@ -357,20 +443,23 @@ func (h *RBACHandlers) DeleteRoleTemplate(c *gin.Context) {
 		return
 	}
 
-	existing, err := h.rbacRepo.GetRoleTemplate(c.Request.Context(), id)
+	existing, err := h.getCustomRoleTemplate(c.Request.Context(), id, "delete")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get role template"})
-		return
-	}
-	if existing == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Role template not found"})
-		return
-	}
-	if existing.IsSystem {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot delete system role templates"})
+		writeStagedApplyError(c, err)
 		return
 	}
 
+	h.staged.Submit(c, StagedChangeInput{
+		ResourceType: models.StagedResourceRoleTemplate,
+		ResourceID:   id.String(),
+		Action:       models.StagedActionDelete,
+		Before:       existing,
+	}, http.StatusOK, func(ctx context.Context) (interface{}, error) {
+		return h.deleteRoleTemplate(ctx, id)
+	})
+}
+
+func (h *RBACHandlers) deleteRoleTemplate(ctx context.Context, id uuid.UUID) (gin.H, error) {
 	// Snapshot the affected members before the delete: the FK is ON DELETE SET
 	// NULL, so a member's role_template_id is gone the instant the delete
 	// commits, and ListRoleTemplateMemberUserIDs would find nobody afterward.
@@ -387,7 +476,7 @@ func (h *RBACHandlers) DeleteRoleTemplate(c *gin.Context) {
 	revocationLookupFailed := false
 	if h.userRevocations != nil {
 		var lookupErr error
-		memberUserIDs, lookupErr = h.rbacRepo.ListRoleTemplateMemberUserIDs(c.Request.Context(), id)
+		memberUserIDs, lookupErr = h.rbacRepo.ListRoleTemplateMemberUserIDs(ctx, id)
 		if lookupErr != nil {
 			slog.Error("failed to look up role template members before deletion; affected members' tokens will not be revoked",
 				"role_template_id", id, "error", lookupErr)
@@ -396,9 +485,8 @@ func (h *RBACHandlers) DeleteRoleTemplate(c *gin.Context) {
 		}
 	}
 
-	if err := h.rbacRepo.DeleteRoleTemplate(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role template"})
-		return
+	if err := h.rbacRepo.DeleteRoleTemplate(ctx, id); err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to delete role template")
 	}
 
 	// Deleting the template unconditionally removes its scopes from every
@@ -410,7 +498,7 @@ func (h *RBACHandlers) DeleteRoleTemplate(c *gin.Context) {
 	// response for an otherwise-successful deletion, matching
 	// revokeRoleTemplateMemberTokens' own best-effort posture.
 	for _, userID := range memberUserIDs {
-		if err := h.userRevocations.RevokeAllUserTokens(c.Request.Context(), userID); err != nil {
+		if err := h.userRevocations.RevokeAllUserTokens(ctx, userID); err != nil {
 			slog.Error("failed to revoke user tokens after role template deletion",
 				"user_id", userID, "role_template_id", id, "error", err)
 		}
@@ -423,7 +511,40 @@ func (h *RBACHandlers) DeleteRoleTemplate(c *gin.Context) {
 		// affected members' tokens were actually invalidated.
 		response["revocation_incomplete"] = true
 	}
-	c.JSON(http.StatusOK, response)
+	return response, nil
+}
+
+// applyStagedRoleTemplate applies an approved role template change,
+// rechecking the template as the direct endpoints do.
+func (h *RBACHandlers) applyStagedRoleTemplate(ctx context.Context, change *models.StagedChange) (interface{}, error) {
+	var req CreateRoleTemplateRequest
+	if change.Action != models.StagedActionDelete {
+		if err := json.Unmarshal(change.After, &req); err != nil {
+			return nil, newStagedApplyError(http.StatusBadRequest, "Staged role template change is malformed")
+		}
+	}
+	if change.Action == models.StagedActionCreate {
+		return h.createRoleTemplate(ctx, req)
+	}
+
+	var id uuid.UUID
+	if change.ResourceID != nil {
+		id, _ = uuid.Parse(*change.ResourceID)
+	}
+	switch change.Action {
+	case models.StagedActionUpdate:
+		existing, err := h.getCustomRoleTemplate(ctx, id, "modify")
+		if err != nil {
+			return nil, err
+		}
+		return h.updateRoleTemplate(ctx, existing, req)
+	case models.StagedActionDelete:
+		if _, err := h.getCustomRoleTemplate(ctx, id, "delete"); err != nil {
+			return nil, err
+		}
+		return h.deleteRoleTemplate(ctx, id)
+	}
+	return nil, newStagedApplyError(http.StatusBadRequest, "Unknown staged change action "+change.Action)
 }
 
 // ============================================================================
@@ -512,6 +633,9 @@ type CreateApprovalRequestRequest struct {
 	ProviderNamespace string  `json:"provider_namespace" binding:"required"`
 	ProviderName      *string `json:"provider_name"`
 	Reason            string  `json:"reason"`
+	// AutoApply adds the namespace/provider to the mirror's filters and starts
+	// a sync when the request is approved.
+	AutoApply bool `json:"auto_apply"`
 }
 
 // @Summary      Create approval request
@@ -571,6 +695,7 @@ func (h *RBACHandlers) CreateApprovalRequest(c *gin.Context) {
 		Reason:            req.Reason,
 		Status:            models.ApprovalStatusPending,
 		AutoApproved:      false,
+		AutoApply:         req.AutoApply,
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
 	}
@@ -591,17 +716,34 @@ type ReviewApprovalRequest struct {
 	Notes  string `json:"notes"`
 }
 
+// ApprovalReviewResponse is the approval request after a review or
+// revocation, with the mirror filter entries that were changed.
+type ApprovalReviewResponse struct {
+	*models.MirrorApprovalRequest
+	// FiltersAdded lists the entries an auto_apply approval added to the
+	// mirror's namespace/provider filters.
+	FiltersAdded *repositories.ApprovalFilterChange `json:"filters_added,omitempty"`
+	// FiltersRemoved lists the entries a revocation removed.
+	FiltersRemoved *repositories.ApprovalFilterChange `json:"filters_removed,omitempty"`
+	// SyncTriggered reports whether a sync of the mirror was started.
+	SyncTriggered bool `json:"sync_triggered,omitempty"`
+	// SyncError is set when the sync could not be started (for example,
+	// because one is already running); the approval itself succeeded.
+	SyncError string `json:"sync_error,omitempty"`
+}
+
 // @Summary      Review approval request
-// @Description  Approve or reject a mirror provider approval request. Requires admin scope.
+// @Description  Approve or reject a mirror provider approval request. Approving a request created with auto_apply also adds its namespace/provider to the mirror's filters and starts a sync of the mirror. Requires admin scope.
 // @Tags         RBAC
 // @Security     Bearer
 // @Accept       json
 // @Produce      json
 // @Param        id    path  string                 true  "Approval request ID (UUID)"
 // @Param        body  body  ReviewApprovalRequest  true  "Review decision (status: approved or rejected)"
-// @Success      200  {object}  models.MirrorApprovalRequest
+// @Success      200  {object}  admin.ApprovalReviewResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid ID or status value"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Approval request not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/admin/approvals/{id}/review [put]
 // ReviewApproval approves or rejects an approval request
@@ -626,14 +768,26 @@ func (h *RBACHandlers) ReviewApproval(c *gin.Context) {
 		return
 	}
 
-	// Get reviewer ID from context
-	var reviewerID uuid.UUID
-	if userIDStr, exists := c.Get("user_id"); exists {
-		if idStr, ok := userIDStr.(string); ok {
-			if id, err := uuid.Parse(idStr); err == nil {
-				reviewerID = id
+	reviewerID := contextUserUUID(c)
+
+	if h.approvals != nil {
+		ctx := operations.WithActor(c.Request.Context(), c.GetString("user_id"))
+		result, err := h.approvals.Review(ctx, id, status, reviewerID, req.Notes)
+		if err != nil {
+			if errors.Is(err, services.ErrApprovalNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Approval request not found"})
+				return
 			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update approval status"})
+			return
 		}
+		c.JSON(http.StatusOK, ApprovalReviewResponse{
+			MirrorApprovalRequest: result.Approval,
+			FiltersAdded:          result.Change,
+			SyncTriggered:         result.SyncTriggered,
+			SyncError:             result.SyncError,
+		})
+		return
 	}
 
 	if err := h.rbacRepo.UpdateApprovalStatus(c.Request.Context(), id, status, reviewerID, req.Notes); err != nil {
@@ -651,6 +805,81 @@ func (h *RBACHandlers) ReviewApproval(c *gin.Context) {
 	c.JSON(http.StatusOK, approval)
 }
 
+// RevokeApprovalRequest is the optional body of a revocation.
+type RevokeApprovalRequest struct {
+	Notes string `json:"notes"`
+}
+
+// @Summary      Revoke approval request
+// @Description  Revoke an approved mirror provider approval request. The namespace/provider filter entries its auto_apply approval added are removed from the mirror, unless another approved request for the same mirror still needs them. Providers already mirrored are not deleted. Requires admin scope.
+// @Tags         RBAC
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                 true   "Approval request ID (UUID)"
+// @Param        body  body  RevokeApprovalRequest  false  "Revocation notes"
+// @Success      200  {object}  admin.ApprovalReviewResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Approval request not found"
+// @Failure      409  {object}  map[string]interface{}  "Approval request is not approved"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      501  {object}  map[string]interface{}  "Revocation not configured"
+// @Router       /api/v1/admin/approvals/{id}/revoke [put]
+// RevokeApproval revokes an approved approval request
+// PUT /api/v1/admin/approvals/:id/revoke
+func (h *RBACHandlers) RevokeApproval(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid approval request ID"})
+		return
+	}
+
+	var req RevokeApprovalRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if h.approvals == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Approval revocation is not configured"})
+		return
+	}
+
+	result, err := h.approvals.Revoke(c.Request.Context(), id, contextUserUUID(c), req.Notes)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrApprovalNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Approval request not found"})
+		case errors.Is(err, repositories.ErrApprovalNotApproved):
+			c.JSON(http.StatusConflict, gin.H{"error": "Only approved requests can be revoked"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke approval request"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ApprovalReviewResponse{
+		MirrorApprovalRequest: result.Approval,
+		FiltersRemoved:        result.Change,
+	})
+}
+
+// contextUserUUID returns the authenticated user's ID, or uuid.Nil when
+// absent or malformed.
+func contextUserUUID(c *gin.Context) uuid.UUID {
+	if userIDStr, exists := c.Get("user_id"); exists {
+		if idStr, ok := userIDStr.(string); ok {
+			if id, err := uuid.Parse(idStr); err == nil {
+				return id
+			}
+		}
+	}
+	return uuid.Nil
+}
+
 // ============================================================================
 // Mirror Policies
 // ============================================================================
@@ -739,13 +968,14 @@ type CreateMirrorPolicyRequest struct {
 }
 
 // @Summary      Create mirror policy
-// @Description  Create a new mirror access policy (allow or deny). Requires admin scope.
+// @Description  Create a new mirror access policy (allow or deny). Requires admin scope. While the two-person rule is on, the change is staged and 202 is returned with the staged change for another admin to approve.
 // @Tags         RBAC
 // @Security     Bearer
 // @Accept       json
 // @Produce      json
 // @Param        body  body  CreateMirrorPolicyRequest  true  "Mirror policy"
 // @Success      201  {object}  models.MirrorPolicy
+// @Success      202  {object}  models.StagedChange
 // @Failure      400  {object}  map[string]interface{}  "Invalid request or policy type"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
@@ -758,31 +988,58 @@ func (h *RBACHandlers) CreateMirrorPolicy(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	policyType := models.PolicyType(req.PolicyType)
-	if policyType != models.PolicyTypeAllow && policyType != models.PolicyTypeDeny {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Policy type must be 'allow' or 'deny'"})
+	if _, _, err := parseMirrorPolicyRequest(req); err != nil {
+		writeStagedApplyError(c, err)
 		return
 	}
 
+	// Get creator ID from context
+	var createdBy *uuid.UUID
+	if id := contextUserUUID(c); id != uuid.Nil {
+		createdBy = &id
+	}
+
+	h.staged.Submit(c, StagedChangeInput{
+		ResourceType: models.StagedResourceMirrorPolicy,
+		Action:       models.StagedActionCreate,
+		After:        req,
+	}, http.StatusCreated, func(ctx context.Context) (interface{}, error) {
+		return h.createMirrorPolicy(ctx, req, createdBy)
+	})
+}
+
+// parsePolicyType validates the policy type of a create or update request.
+func parsePolicyType(policyType string) (models.PolicyType, error) {
+	pt := models.PolicyType(policyType)
+	if pt != models.PolicyTypeAllow && pt != models.PolicyTypeDeny {
+		return "", newStagedApplyError(http.StatusBadRequest, "Policy type must be 'allow' or 'deny'")
+	}
+	return pt, nil
+}
+
+// parseMirrorPolicyRequest validates the policy type and organization ID of
+// a create request.
+func parseMirrorPolicyRequest(req CreateMirrorPolicyRequest) (models.PolicyType, *uuid.UUID, error) {
+	policyType, err := parsePolicyType(req.PolicyType)
+	if err != nil {
+		return "", nil, err
+	}
+
 	var orgID *uuid.UUID
 	if req.OrganizationID != nil {
 		id, err := uuid.Parse(*req.OrganizationID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
-			return
+			return "", nil, newStagedApplyError(http.StatusBadRequest, "Invalid organization ID")
 		}
 		orgID = &id
 	}
+	return policyType, orgID, nil
+}
 
-	// Get creator ID from context
-	var createdBy *uuid.UUID
-	if userIDStr, exists := c.Get("user_id"); exists {
-		if idStr, ok := userIDStr.(string); ok {
-			if id, err := uuid.Parse(idStr); err == nil {
-				createdBy = &id
-			}
-		}
+func (h *RBACHandlers) createMirrorPolicy(ctx context.Context, req CreateMirrorPolicyRequest, createdBy *uuid.UUID) (*models.MirrorPolicy, error) {
+	policyType, orgID, err := parseMirrorPolicyRequest(req)
+	if err != nil {
+		return nil, err
 	}
 
 	policy := &models.MirrorPolicy{
@@ -802,16 +1059,14 @@ func (h *RBACHandlers) CreateMirrorPolicy(c *gin.Context) {
 		CreatedBy:        createdBy,
 	}
 
-	if err := h.rbacRepo.CreateMirrorPolicy(c.Request.Context(), policy); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create policy"})
-		return
+	if err := h.rbacRepo.CreateMirrorPolicy(ctx, policy); err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to create policy")
 	}
-
-	c.JSON(http.StatusCreated, policy)
+	return policy, nil
 }
 
 // @Summary      Update mirror policy
-// @Description  Update an existing mirror access policy. Requires admin scope.
+// @Description  Update an existing mirror access policy. Requires admin scope. While the two-person rule is on, the change is staged and 202 is returned with the staged change for another admin to approve.
 // @Tags         RBAC
 // @Security     Bearer
 // @Accept       json
@@ -819,6 +1074,7 @@ func (h *RBACHandlers) CreateMirrorPolicy(c *gin.Context) {
 // @Param        id    path  string                    true  "Policy ID (UUID)"
 // @Param        body  body  CreateMirrorPolicyRequest  true  "Updated mirror policy"
 // @Success      200  {object}  models.MirrorPolicy
+// @Success      202  {object}  models.StagedChange
 // @Failure      400  {object}  map[string]interface{}  "Invalid request, ID, or policy type"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      404  {object}  map[string]interface{}  "Policy not found"
@@ -834,13 +1090,9 @@ func (h *RBACHandlers) UpdateMirrorPolicy(c *gin.Context) {
 		return
 	}
 
-	existing, err := h.rbacRepo.GetMirrorPolicy(c.Request.Context(), id)
+	existing, err := h.getMirrorPolicy(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get policy"})
-		return
-	}
-	if existing == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
+		writeStagedApplyError(c, err)
 		return
 	}
 
@@ -849,16 +1101,39 @@ func (h *RBACHandlers) UpdateMirrorPolicy(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	policyType := models.PolicyType(req.PolicyType)
-	if policyType != models.PolicyTypeAllow && policyType != models.PolicyTypeDeny {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Policy type must be 'allow' or 'deny'"})
+	if _, err := parsePolicyType(req.PolicyType); err != nil {
+		writeStagedApplyError(c, err)
 		return
 	}
 
+	h.staged.Submit(c, StagedChangeInput{
+		ResourceType: models.StagedResourceMirrorPolicy,
+		ResourceID:   id.String(),
+		Action:       models.StagedActionUpdate,
+		Before:       existing,
+		After:        req,
+	}, http.StatusOK, func(ctx context.Context) (interface{}, error) {
+		return h.updateMirrorPolicy(ctx, existing, req)
+	})
+}
+
+func (h *RBACHandlers) getMirrorPolicy(ctx context.Context, id uuid.UUID) (*models.MirrorPolicy, error) {
+	policy, err := h.rbacRepo.GetMirrorPolicy(ctx, id)
+	if err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to get policy")
+	}
+	if policy == nil {
+		return nil, newStagedApplyError(http.StatusNotFound, "Policy not found")
+	}
+	return policy, nil
+}
+
+// updateMirrorPolicy applies req to existing. The organization of a policy
+// is fixed at creation, so req.OrganizationID is ignored.
+func (h *RBACHandlers) updateMirrorPolicy(ctx context.Context, existing *models.MirrorPolicy, req CreateMirrorPolicyRequest) (*models.MirrorPolicy, error) {
 	existing.Name = req.Name
 	existing.Description = &req.Description
-	existing.PolicyType = policyType
+	existing.PolicyType = models.PolicyType(req.PolicyType)
 	existing.UpstreamRegistry = req.UpstreamRegistry
 	existing.NamespacePattern = req.NamespacePattern
 	existing.ProviderPattern = req.ProviderPattern
@@ -867,23 +1142,23 @@ func (h *RBACHandlers) UpdateMirrorPolicy(c *gin.Context) {
 	existing.RequiresApproval = req.RequiresApproval
 	existing.UpdatedAt = time.Now()
 
-	if err := h.rbacRepo.UpdateMirrorPolicy(c.Request.Context(), existing); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update policy"})
-		return
+	if err := h.rbacRepo.UpdateMirrorPolicy(ctx, existing); err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to update policy")
 	}
-
-	c.JSON(http.StatusOK, existing)
+	return existing, nil
 }
 
 // @Summary      Delete mirror policy
-// @Description  Delete a mirror access policy. Requires admin scope.
+// @Description  Delete a mirror access policy. Requires admin scope. While the two-person rule is on, the change is staged and 202 is returned with the staged change for another admin to approve.
 // @Tags         RBAC
 // @Security     Bearer
 // @Produce      json
 // @Param        id  path  string  true  "Policy ID (UUID)"
 // @Success      200  {object}  admin.MessageResponse
+// @Success      202  {object}  models.StagedChange
 // @Failure      400  {object}  map[string]interface{}  "Invalid policy ID"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Policy not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/admin/policies/{id} [delete]
 // DeleteMirrorPolicy deletes a mirror policy
@@ -896,12 +1171,66 @@ func (h *RBACHandlers) DeleteMirrorPolicy(c *gin.Context) {
 		return
 	}
 
-	if err := h.rbacRepo.DeleteMirrorPolicy(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete policy"})
+	existing, err := h.getMirrorPolicy(c.Request.Context(), id)
+	if err != nil {
+		writeStagedApplyError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Policy deleted"})
+	h.staged.Submit(c, StagedChangeInput{
+		ResourceType: models.StagedResourceMirrorPolicy,
+		ResourceID:   id.String(),
+		Action:       models.StagedActionDelete,
+		Before:       existing,
+	}, http.StatusOK, func(ctx context.Context) (interface{}, error) {
+		return h.deleteMirrorPolicy(ctx, id)
+	})
+}
+
+func (h *RBACHandlers) deleteMirrorPolicy(ctx context.Context, id uuid.UUID) (gin.H, error) {
+	if err := h.rbacRepo.DeleteMirrorPolicy(ctx, id); err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to delete policy")
+	}
+	return gin.H{"message": "Policy deleted"}, nil
+}
+
+// applyStagedMirrorPolicy applies an approved mirror policy change. A created
+// policy is attributed to the admin who requested it.
+func (h *RBACHandlers) applyStagedMirrorPolicy(ctx context.Context, change *models.StagedChange) (interface{}, error) {
+	var req CreateMirrorPolicyRequest
+	if change.Action != models.StagedActionDelete {
+		if err := json.Unmarshal(change.After, &req); err != nil {
+			return nil, newStagedApplyError(http.StatusBadRequest, "Staged mirror policy change is malformed")
+		}
+	}
+	if change.Action == models.StagedActionCreate {
+		var createdBy *uuid.UUID
+		if change.RequestedBy != nil {
+			if id, err := uuid.Parse(*change.RequestedBy); err == nil {
+				createdBy = &id
+			}
+		}
+		return h.createMirrorPolicy(ctx, req, createdBy)
+	}
+
+	var id uuid.UUID
+	if change.ResourceID != nil {
+		id, _ = uuid.Parse(*change.ResourceID)
+	}
+	existing, err := h.getMirrorPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch change.Action {
+	case models.StagedActionUpdate:
+		if _, err := parsePolicyType(req.PolicyType); err != nil {
+			return nil, err
+		}
+		return h.updateMirrorPolicy(ctx, existing, req)
+	case models.StagedActionDelete:
+		return h.deleteMirrorPolicy(ctx, id)
+	}
+	return nil, newStagedApplyError(http.StatusBadRequest, "Unknown staged change action "+change.Action)
 }
 
 // EvaluatePolicyRequest represents a request to evaluate policies for a provider
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 )
 
 // ---------------------------------------------------------------------------
@@ -27,6 +29,7 @@ var approvalCols = []string{
 	"provider_namespace", "provider_name", "reason", "status",
 	"reviewed_by", "reviewed_at", "review_notes", "auto_approved",
 	"created_at", "updated_at", "expires_at",
+	"auto_apply", "applied_namespace", "applied_provider", "applied_at", "revoked_by", "revoked_at",
 }
 
 var approvalListCols = []string{
@@ -34,6 +37,7 @@ var approvalListCols = []string{
 	"provider_namespace", "provider_name", "reason", "status",
 	"reviewed_by", "reviewed_at", "review_notes", "auto_approved",
 	"created_at", "updated_at", "expires_at",
+	"auto_apply", "applied_namespace", "applied_provider", "applied_at", "revoked_by", "revoked_at",
 	"requested_by_name", "reviewed_by_name", "mirror_name",
 }
 
@@ -81,6 +85,7 @@ func sampleApprovalRow() *sqlmock.Rows {
 			"hashicorp", nil, "need it", "pending",
 			nil, nil, nil, false,
 			time.Now(), time.Now(), nil,
+			false, nil, nil, nil, nil, nil,
 		)
 }
 
@@ -136,11 +141,13 @@ func newRBACRouterWithRevocation(t *testing.T, withRevocation bool) (sqlmock.Sql
 	r.POST("/role-templates", h.CreateRoleTemplate)
 	r.PUT("/role-templates/:id", h.UpdateRoleTemplate)
 	r.DELETE("/role-templates/:id", h.DeleteRoleTemplate)
+	r.GET("/role-templates/scopes", h.ListScopes)
 
 	r.GET("/approvals", h.ListApprovalRequests)
 	r.GET("/approvals/:id", h.GetApprovalRequest)
 	r.POST("/approvals", h.CreateApprovalRequest)
 	r.PUT("/approvals/:id/review", h.ReviewApproval)
+	r.PUT("/approvals/:id/revoke", h.RevokeApproval)
 
 	r.GET("/policies", h.ListMirrorPolicies)
 	r.GET("/policies/:id", h.GetMirrorPolicy)
@@ -221,6 +228,33 @@ func TestRBACGetRoleTemplate_Found(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ListScopes
+// ---------------------------------------------------------------------------
+
+func TestRBACListScopes(t *testing.T) {
+	_, r := newRBACRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/role-templates/scopes", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp ScopeCatalogResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	for _, info := range resp.Scopes {
+		if info.Scope == "binaries:manage" {
+			if len(info.ImpliedBy) != 1 || info.ImpliedBy[0] != "mirrors:manage" {
+				t.Errorf("binaries:manage implied_by = %v, want [mirrors:manage]", info.ImpliedBy)
+			}
+			return
+		}
+	}
+	t.Error("scope catalog missing binaries:manage")
+}
+
 // ---------------------------------------------------------------------------
 // CreateRoleTemplate
 // ---------------------------------------------------------------------------
@@ -236,6 +270,41 @@ func TestRBACCreateRoleTemplate_MissingFields(t *testing.T) {
 	}
 }
 
+func TestRBACCreateRoleTemplate_InvalidScope(t *testing.T) {
+	_, r := newRBACRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/role-templates",
+		jsonBody(map[string]interface{}{
+			"name":         "new-role",
+			"display_name": "New Role",
+			"scopes":       []string{"binaries:write"},
+		})))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRBACCreateRoleTemplate_BinariesScopes(t *testing.T) {
+	mock, r := newRBACRouter(t)
+	mock.ExpectQuery("SELECT.*FROM role_templates WHERE name").
+		WillReturnRows(emptyRTRows())
+	mock.ExpectExec("INSERT INTO role_templates").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/role-templates",
+		jsonBody(map[string]interface{}{
+			"name":         "binary-mirror-owner",
+			"display_name": "Binary Mirror Owner",
+			"scopes":       []string{"binaries:read", "binaries:manage"},
+		})))
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+}
+
 func TestRBACCreateRoleTemplate_Conflict(t *testing.T) {
 	mock, r := newRBACRouter(t)
 	// GetRoleTemplateByName finds existing
@@ -279,6 +348,23 @@ func TestRBACCreateRoleTemplate_Success(t *testing.T) {
 // UpdateRoleTemplate
 // ---------------------------------------------------------------------------
 
+func TestRBACUpdateRoleTemplate_InvalidScope(t *testing.T) {
+	mock, r := newRBACRouter(t)
+	mock.ExpectQuery("SELECT.*FROM role_templates WHERE id").
+		WillReturnRows(sampleRTRow())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/role-templates/"+knownUUID,
+		jsonBody(map[string]interface{}{
+			"name":         "reader",
+			"display_name": "Reader",
+			"scopes":       []string{"modules:read", "not-a-scope"},
+		})))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
 func TestRBACUpdateRoleTemplate_NotFound(t *testing.T) {
 	mock, r := newRBACRouter(t)
 	mock.ExpectQuery("SELECT.*FROM role_templates WHERE id").
@@ -739,6 +825,8 @@ func TestRBACDeleteMirrorPolicy_InvalidID(t *testing.T) {
 
 func TestRBACDeleteMirrorPolicy_Success(t *testing.T) {
 	mock, r := newRBACRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_policies WHERE id").
+		WillReturnRows(sampleMPRow())
 	mock.ExpectExec("DELETE FROM mirror_policies").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -750,6 +838,19 @@ func TestRBACDeleteMirrorPolicy_Success(t *testing.T) {
 	}
 }
 
+func TestRBACDeleteMirrorPolicy_NotFound(t *testing.T) {
+	mock, r := newRBACRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_policies WHERE id").
+		WillReturnError(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/policies/"+knownUUID, nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
 // ---------------------------------------------------------------------------
 // EvaluatePolicy
 // ---------------------------------------------------------------------------
@@ -1376,6 +1477,115 @@ func TestRBACReviewApproval_MissingBody(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ReviewApproval / RevokeApproval — with the approval provisioner
+// ---------------------------------------------------------------------------
+
+// newRBACApprovalRouter builds an approvals-only router whose handlers have
+// a MirrorApprovalProvisioner wired in.
+func newRBACApprovalRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	rbacRepo := repositories.NewRBACRepository(sqlx.NewDb(db, "sqlmock"))
+	h := NewRBACHandlers(rbacRepo, nil).
+		WithApprovalProvisioner(services.NewMirrorApprovalProvisioner(rbacRepo, nil))
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", knownUserUUID)
+		c.Next()
+	})
+	r.PUT("/approvals/:id/review", h.ReviewApproval)
+	r.PUT("/approvals/:id/revoke", h.RevokeApproval)
+	return mock, r
+}
+
+func TestRBACReviewApproval_ProvisionerNotFound(t *testing.T) {
+	mock, r := newRBACApprovalRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_approval_requests WHERE id").
+		WillReturnRows(emptyApprovalRows())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/approvals/"+knownUUID+"/review",
+		jsonBody(map[string]interface{}{"status": "approved"})))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRBACReviewApproval_ProvisionerWithoutAutoApply(t *testing.T) {
+	mock, r := newRBACApprovalRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_approval_requests WHERE id").
+		WillReturnRows(sampleApprovalRow())
+	mock.ExpectExec("UPDATE mirror_approval_requests.*SET status").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT.*FROM mirror_approval_requests WHERE id").
+		WillReturnRows(sampleApprovalRow())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/approvals/"+knownUUID+"/review",
+		jsonBody(map[string]interface{}{"status": "approved"})))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "filters_added") {
+		t.Errorf("body = %s, want no filters_added for a request without auto_apply", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRBACRevokeApproval_NotConfigured(t *testing.T) {
+	_, r := newRBACRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/approvals/"+knownUUID+"/revoke", nil))
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("status = %d, want 501: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRBACRevokeApproval_InvalidID(t *testing.T) {
+	_, r := newRBACApprovalRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/approvals/not-a-uuid/revoke", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestRBACRevokeApproval_NotApproved(t *testing.T) {
+	mock, r := newRBACApprovalRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_approval_requests WHERE id").
+		WillReturnRows(sampleApprovalRow())
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mirror_config_id.*FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"mirror_config_id", "provider_namespace", "provider_name", "status",
+			"applied_namespace", "applied_provider", "applied_at",
+		}).AddRow(knownUUID, "hashicorp", nil, "pending", nil, nil, nil))
+	mock.ExpectQuery("SELECT namespace_filter, provider_filter").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace_filter", "provider_filter"}).AddRow(nil, nil))
+	mock.ExpectRollback()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/approvals/"+knownUUID+"/revoke",
+		jsonBody(map[string]interface{}{"notes": "no longer needed"})))
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409: body=%s", w.Code, w.Body.String())
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ListMirrorPolicies — with valid org ID
 // ---------------------------------------------------------------------------
@@ -1466,6 +1676,8 @@ func TestRBACUpdateMirrorPolicy_BindJSONError(t *testing.T) {
 
 func TestRBACDeleteMirrorPolicy_DBError(t *testing.T) {
 	mock, r := newRBACRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_policies WHERE id").
+		WillReturnRows(sampleMPRow())
 	mock.ExpectExec("DELETE FROM mirror_policies").
 		WillReturnError(errDB)
 
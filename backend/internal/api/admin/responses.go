@@ -1,6 +1,15 @@
 package admin
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
 
 // MessageResponse is returned by action endpoints that confirm success with a plain message.
 // Used by delete, unlink, revoke, and similar operations.
@@ -36,11 +45,19 @@ type MeMembershipEntry struct {
 
 // MeResponse is returned by GET /api/v1/auth/me.
 type MeResponse struct {
-	User             MeUserInfo          `json:"user"`
-	Memberships      []MeMembershipEntry `json:"memberships"`
-	AllowedScopes    []string            `json:"allowed_scopes"`
-	RoleTemplate     interface{}         `json:"role_template"`
-	SessionExpiresAt *time.Time          `json:"session_expires_at,omitempty"`
+	User          MeUserInfo          `json:"user"`
+	Memberships   []MeMembershipEntry `json:"memberships"`
+	AllowedScopes []string            `json:"allowed_scopes"`
+	// ScopesRemovedByCeiling lists the role-template scopes the scope
+	// ceilings of the user's organizations keep out of their tokens.
+	ScopesRemovedByCeiling []string         `json:"scopes_removed_by_ceiling"`
+	ScopeCatalog           []auth.ScopeInfo `json:"scope_catalog"`
+	RoleTemplate           interface{}      `json:"role_template"`
+	SessionExpiresAt       *time.Time       `json:"session_expires_at,omitempty"`
+	// OrganizationID is the organization the request acts for; absent when
+	// the caller belongs to several and none was selected.
+	OrganizationID   string `json:"organization_id,omitempty"`
+	OrganizationRole string `json:"organization_role,omitempty"`
 }
 
 // APIKeyItem represents a single API key in list/get responses.
@@ -60,7 +77,8 @@ type APIKeyItem struct {
 
 // ListAPIKeysResponse is returned by GET /api/v1/apikeys.
 type ListAPIKeysResponse struct {
-	Keys []APIKeyItem `json:"keys"`
+	Keys []APIKeyItem    `json:"keys"`
+	Meta pagination.Meta `json:"meta"`
 }
 
 // APIKeyResponse wraps a single API key for get/update responses.
@@ -70,7 +88,22 @@ type APIKeyResponse struct {
 
 // ListMirrorConfigsResponse is returned by GET /api/v1/admin/mirrors.
 type ListMirrorConfigsResponse struct {
-	Mirrors interface{} `json:"mirrors"`
+	Mirrors interface{}     `json:"mirrors"`
+	Meta    pagination.Meta `json:"meta"`
+}
+
+// MirrorSyncHistoryPage is returned by GET /api/v1/admin/mirrors/{id}/history.
+// It keeps the total_count/limit/offset fields and adds the standard meta.
+type MirrorSyncHistoryPage struct {
+	models.MirrorSyncHistoryListResponse
+	Meta pagination.Meta `json:"meta"`
+}
+
+// TerraformSyncHistoryPage is returned by
+// GET /api/v1/admin/terraform-mirrors/{id}/history.
+type TerraformSyncHistoryPage struct {
+	models.TerraformSyncHistoryListResponse
+	Meta pagination.Meta `json:"meta"`
 }
 
 // TokenRefreshResponse is returned by POST /api/v1/scm-providers/{id}/oauth/refresh.
@@ -115,13 +148,24 @@ type ListBranchesResponse struct {
 	Branches interface{} `json:"branches"`
 }
 
-// PaginationMeta carries page / per_page / total counts used in paginated list responses.
+// PaginationMeta carries page / per_page / total counts used in paginated
+// list responses. It predates the standard pagination.Meta block ("meta"),
+// which list responses carry alongside it.
 type PaginationMeta struct {
 	Page    int   `json:"page"`
 	PerPage int   `json:"per_page"`
 	Total   int64 `json:"total,omitempty"`
 }
 
+// newPaginationMeta returns the PaginationMeta of an offset page.
+func newPaginationMeta(p pagination.Page, total int64) PaginationMeta {
+	return PaginationMeta{Page: p.Number(), PerPage: p.Limit, Total: total}
+}
+
+// defaultListLimits are the page size default and cap of the admin lists
+// that do not set their own.
+var defaultListLimits = pagination.Limits{Default: 20, Max: 100}
+
 // UserItem is the shape of a user in list/get/create/update responses.
 type UserItem struct {
 	ID        string    `json:"id"`
@@ -131,10 +175,28 @@ type UserItem struct {
 	UpdatedAt time.Time `json:"updated_at"`
 } // @name User
 
-// ListUsersResponse is returned by GET /api/v1/users and GET /api/v1/users/search.
+// ListUsersResponse is returned by GET /api/v1/users.
 type ListUsersResponse struct {
-	Users      []UserItem     `json:"users"`
-	Pagination PaginationMeta `json:"pagination"`
+	Users      []UserItem      `json:"users"`
+	Meta       pagination.Meta `json:"meta"`
+	Pagination PaginationMeta  `json:"pagination"`
+}
+
+// UserSearchItem is the shape of a user in GET /api/v1/users/search results.
+// LastLoginAt and AuthProvider are null for users who never logged in.
+type UserSearchItem struct {
+	UserItem
+	Memberships  interface{} `json:"memberships"`
+	LastLoginAt  *time.Time  `json:"last_login_at"`
+	AuthProvider *string     `json:"auth_provider"`
+	AuthIssuer   *string     `json:"auth_issuer,omitempty"`
+}
+
+// SearchUsersResponse is returned by GET /api/v1/users/search.
+type SearchUsersResponse struct {
+	Users      []UserSearchItem `json:"users"`
+	Meta       pagination.Meta  `json:"meta"`
+	Pagination PaginationMeta   `json:"pagination"`
 }
 
 // UserWithOrgsResponse is returned by GET /api/v1/users/{id}.
@@ -155,14 +217,23 @@ type UserMembershipsResponse struct {
 
 // ListOrganizationsResponse is returned by GET /api/v1/organizations and GET /api/v1/organizations/search.
 type ListOrganizationsResponse struct {
-	Organizations interface{}    `json:"organizations"`
-	Pagination    PaginationMeta `json:"pagination"`
+	Organizations interface{}     `json:"organizations"`
+	Meta          pagination.Meta `json:"meta"`
+	Pagination    PaginationMeta  `json:"pagination"`
+}
+
+// OrganizationWithStats is an organization list item when ?include=stats is set.
+type OrganizationWithStats struct {
+	*models.Organization
+	Stats *models.OrganizationStats `json:"stats"`
 }
 
 // OrganizationWithMembersResponse is returned by GET /api/v1/organizations/{id}.
+// Stats is present only with ?include=stats.
 type OrganizationWithMembersResponse struct {
-	Organization interface{} `json:"organization"`
-	Members      interface{} `json:"members"`
+	Organization interface{}               `json:"organization"`
+	Members      interface{}               `json:"members"`
+	Stats        *models.OrganizationStats `json:"stats,omitempty"`
 }
 
 // OrganizationMembersResponse is returned by GET /api/v1/organizations/{id}/members.
@@ -182,9 +253,21 @@ type MemberResponse struct {
 
 // DeleteTerraformMirrorResponse is returned by DELETE /api/v1/admin/terraform-mirrors/{id}.
 type DeleteTerraformMirrorResponse struct {
-	Message string `json:"message"`
-	ID      string `json:"id"`
-	Name    string `json:"name"`
+	Message       string                            `json:"message"`
+	ID            string                            `json:"id"`
+	Name          string                            `json:"name"`
+	Artifacts     *TerraformArtifactDeletionSummary `json:"artifacts,omitempty"`
+	ArtifactsKept bool                              `json:"artifacts_kept,omitempty"`
+}
+
+// TerraformArtifactDeletionSummary reports what deleting a Terraform mirror
+// config or version removed from storage. Missing objects were already gone;
+// failed ones are recorded for garbage collection.
+type TerraformArtifactDeletionSummary struct {
+	Deleted    int   `json:"deleted"`
+	Missing    int   `json:"missing"`
+	Failed     int   `json:"failed"`
+	BytesFreed int64 `json:"bytes_freed"`
 }
 
 // TerraformMirrorSyncResponse is returned by POST /api/v1/admin/terraform-mirrors/{id}/sync.
@@ -196,13 +279,47 @@ type TerraformMirrorSyncResponse struct {
 
 // DeleteTerraformVersionResponse is returned by DELETE /api/v1/admin/terraform-mirrors/{id}/versions/{version}.
 type DeleteTerraformVersionResponse struct {
-	Message string `json:"message"`
-	Version string `json:"version"`
+	Message       string                            `json:"message"`
+	Version       string                            `json:"version"`
+	Artifacts     *TerraformArtifactDeletionSummary `json:"artifacts,omitempty"`
+	ArtifactsKept bool                              `json:"artifacts_kept,omitempty"`
+}
+
+// SCMSyncResponse is returned by POST /api/v1/admin/modules/{id}/scm/sync.
+// Published holds the publish metadata of each version the sync created.
+type SCMSyncResponse struct {
+	Message   string                 `json:"message"`
+	Published []*scm.PublishMetadata `json:"published"`
 }
 
 // WebhookEventsResponse is returned by GET /api/v1/admin/modules/{id}/scm/events.
 type WebhookEventsResponse struct {
-	Events interface{} `json:"events"`
+	Events interface{}     `json:"events"`
+	Meta   pagination.Meta `json:"meta"`
+}
+
+// SCMPublishTaskResponse is returned by GET /api/v1/admin/modules/{id}/scm/publishes/{publish_id}.
+type SCMPublishTaskResponse struct {
+	Publish *scm.PublishTask `json:"publish"`
+}
+
+// SCMBackfillResponse is returned by POST /api/v1/admin/modules/{id}/scm/backfill.
+// Tags lists the tags backfilled, oldest version first; Publishes holds the
+// tasks queued for them, empty on a dry run.
+type SCMBackfillResponse struct {
+	BackfillID *uuid.UUID             `json:"backfill_id,omitempty"`
+	DryRun     bool                   `json:"dry_run"`
+	Tags       []services.BackfillTag `json:"tags"`
+	Publishes  []*scm.PublishTask     `json:"publishes"`
+}
+
+// SCMBackfillStatusResponse is returned by GET /api/v1/admin/modules/{id}/scm/backfills/{backfill_id}.
+type SCMBackfillStatusResponse struct {
+	BackfillID uuid.UUID                     `json:"backfill_id"`
+	Status     string                        `json:"status"` // running or completed
+	Total      int                           `json:"total"`
+	Counts     map[scm.PublishTaskStatus]int `json:"counts"`
+	Publishes  []*scm.PublishTask            `json:"publishes"`
 }
 
 // ActivateStorageConfigResponse is returned by POST /api/v1/storage/configs/{id}/activate.
@@ -240,6 +357,14 @@ type ModuleDetailResponse struct {
 	Versions      []ModuleVersionItem `json:"versions"`
 	CreatedAt     time.Time           `json:"created_at"`
 	UpdatedAt     time.Time           `json:"updated_at"`
+	// Tier is the registry-assigned tier.
+	Tier string `json:"tier,omitempty"`
+}
+
+// ModuleVersionListResponse is returned by GET /api/v1/admin/modules/{id}/versions.
+type ModuleVersionListResponse struct {
+	Versions   []ModuleVersionItem `json:"versions"`
+	Pagination PaginationMeta      `json:"pagination"`
 }
 
 // ProviderPlatformItem represents a platform entry inside a provider version.
@@ -264,6 +389,12 @@ type ProviderVersionItem struct {
 	CreatedAt          time.Time              `json:"created_at"`
 }
 
+// ProviderVersionListResponse is returned by GET /api/v1/admin/providers/{id}/versions.
+type ProviderVersionListResponse struct {
+	Versions   []ProviderVersionItem `json:"versions"`
+	Pagination PaginationMeta        `json:"pagination"`
+}
+
 // ProviderDetailResponse is returned by GET /api/v1/providers/{namespace}/{type}.
 type ProviderDetailResponse struct {
 	ID          string                `json:"id"`
@@ -274,6 +405,15 @@ type ProviderDetailResponse struct {
 	Versions    []ProviderVersionItem `json:"versions"`
 	CreatedAt   time.Time             `json:"created_at"`
 	UpdatedAt   time.Time             `json:"updated_at"`
+	// Tier is the registry-assigned tier.
+	Tier string `json:"tier,omitempty"`
+	// Upstream metadata recorded by mirror sync; absent for uploaded providers.
+	SourceURL        string     `json:"source_url,omitempty"`
+	UpstreamTier     string     `json:"upstream_tier,omitempty"`
+	License          string     `json:"license,omitempty"`
+	LicenseFile      string     `json:"license_file,omitempty"`
+	LicenseText      string     `json:"license_text,omitempty"`
+	MetadataSyncedAt *time.Time `json:"metadata_synced_at,omitempty"`
 }
 
 // MirroredPlatformSummary describes a single platform entry in the ListMirroredProviders response.
@@ -312,6 +452,10 @@ type MirroredProviderSummary struct {
 // ListMirroredProvidersResponse is returned by GET /api/v1/admin/mirrors/{id}/providers.
 type ListMirroredProvidersResponse struct {
 	Providers []MirroredProviderSummary `json:"providers"`
+	Total     int                       `json:"total"`
+	Limit     int                       `json:"limit"`
+	Offset    int                       `json:"offset"`
+	Meta      pagination.Meta           `json:"meta"`
 }
 
 // AuditLogResponse represents a single audit log entry in list or get responses.
@@ -330,7 +474,37 @@ type AuditLogResponse struct {
 }
 
 // AuditLogListResponse is returned by GET /api/v1/admin/audit-logs.
+// Pagination is the pre-meta page block, omitted on cursor pages.
 type AuditLogListResponse struct {
 	Logs       []AuditLogResponse `json:"logs"`
-	Pagination PaginationMeta     `json:"pagination"`
+	Meta       pagination.Meta    `json:"meta"`
+	Pagination *PaginationMeta    `json:"pagination,omitempty"`
+}
+
+// ProviderPlatformDownloads is a platform's download count inside a provider
+// stats response.
+type ProviderPlatformDownloads struct {
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	Downloads int64  `json:"downloads"`
+}
+
+// ProviderVersionDownloads is a version's download count, broken down by
+// platform, inside a provider stats response.
+type ProviderVersionDownloads struct {
+	Version   string                      `json:"version"`
+	Downloads int64                       `json:"downloads"`
+	Platforms []ProviderPlatformDownloads `json:"platforms"`
+}
+
+// ProviderStatsResponse is returned by GET /api/v1/admin/providers/{namespace}/{type}/stats.
+// Versions and platforms are ordered by downloads, most downloaded first.
+type ProviderStatsResponse struct {
+	Namespace      string                      `json:"namespace"`
+	Type           string                      `json:"type"`
+	Window         string                      `json:"window"`
+	Since          *time.Time                  `json:"since,omitempty"` // first day counted; omitted for window=all
+	TotalDownloads int64                       `json:"total_downloads"`
+	Versions       []ProviderVersionDownloads  `json:"versions"`
+	Platforms      []ProviderPlatformDownloads `json:"platforms"`
 }
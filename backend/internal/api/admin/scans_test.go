@@ -28,7 +28,7 @@ var modVersionGetColsScan = []string{
 	"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes",
 	"checksum", "readme", "published_by", "download_count",
 	"deprecated", "deprecated_at", "deprecation_message", "replacement_source", "created_at",
-	"commit_sha", "tag_name", "scm_repo_id",
+	"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version",
 }
 
 func newScanAdminRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
@@ -66,7 +66,7 @@ func sampleVersionRowScan() *sqlmock.Rows {
 	return sqlmock.NewRows(modVersionGetColsScan).
 		AddRow("ver-1", "mod-1", "1.0.0", "path/file.tgz", "local",
 			int64(1024), "abc123", nil, nil, int64(0), false, nil, nil, nil, time.Now(),
-			nil, nil, nil)
+			nil, nil, nil, nil)
 }
 
 func sampleScanResultRow() *sqlmock.Rows {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,6 +16,8 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/events"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/scm"
 	"github.com/terraform-registry/terraform-registry/internal/scm/appcreds"
 )
@@ -26,6 +29,9 @@ type SCMOAuthHandlers struct {
 	userRepo    *repositories.UserRepository
 	tokenCipher *crypto.TokenCipher
 	minter      appcreds.SharedMinter
+	// events receives scm.token_connected for the provider's organization's
+	// event webhooks; nil drops them.
+	events *events.Bus
 }
 
 // NewSCMOAuthHandlers creates a new SCM OAuth handlers instance
@@ -45,6 +51,31 @@ func (h *SCMOAuthHandlers) WithMinter(minter appcreds.SharedMinter) *SCMOAuthHan
 	return h
 }
 
+// WithEvents sets the bus SCM token connection events are published on.
+// Returns the handler for chaining.
+func (h *SCMOAuthHandlers) WithEvents(bus *events.Bus) *SCMOAuthHandlers {
+	h.events = bus
+	return h
+}
+
+// publishTokenConnected publishes scm.token_connected for a user's newly
+// stored token. method is "oauth" or "pat"; replaced reports that it
+// replaced an earlier token.
+func (h *SCMOAuthHandlers) publishTokenConnected(provider *scm.SCMProviderRecord, userID uuid.UUID, method string, replaced bool) {
+	h.events.Publish(events.Event{
+		Type:           events.TypeSCMTokenConnected,
+		OrganizationID: provider.OrganizationID.String(),
+		ActorID:        userID.String(),
+		Data: map[string]string{
+			"user_id":         userID.String(),
+			"scm_provider_id": provider.ID.String(),
+			"provider_type":   string(provider.ProviderType),
+			"method":          method,
+			"replaced":        strconv.FormatBool(replaced),
+		},
+	})
+}
+
 // @Summary      Initiate SCM OAuth
 // @Description  Start the OAuth authorization flow for an SCM provider. Returns the authorization URL to redirect the user to. For PAT-based providers, returns guidance on using POST /token instead.
 // @Tags         SCM OAuth
@@ -114,7 +145,7 @@ func (h *SCMOAuthHandlers) InitiateOAuth(c *gin.Context) {
 		InstanceBaseURL: baseURL,
 		ClientID:        provider.ClientID,
 		ClientSecret:    clientSecret,
-		CallbackURL:     fmt.Sprintf("%s/api/v1/scm-providers/%s/oauth/callback", h.cfg.Server.GetPublicURL(), providerID),
+		CallbackURL:     fmt.Sprintf("%s/api/v1/scm-providers/%s/oauth/callback", h.publicURL(c), providerID),
 		TenantID:        tenantID,
 	})
 	if err != nil {
@@ -198,7 +229,7 @@ func (h *SCMOAuthHandlers) HandleOAuthCallback(c *gin.Context) {
 		Kind:         provider.ProviderType,
 		ClientID:     provider.ClientID,
 		ClientSecret: clientSecret,
-		CallbackURL:  fmt.Sprintf("%s/api/v1/scm-providers/%s/oauth/callback", h.cfg.Server.GetPublicURL(), providerID),
+		CallbackURL:  fmt.Sprintf("%s/api/v1/scm-providers/%s/oauth/callback", h.publicURL(c), providerID),
 		TenantID:     callbackTenantID,
 	})
 	if err != nil {
@@ -284,9 +315,10 @@ func (h *SCMOAuthHandlers) HandleOAuthCallback(c *gin.Context) {
 			return
 		}
 	}
+	h.publishTokenConnected(provider, userID, "oauth", existingToken != nil)
 
 	// Redirect to frontend success page
-	redirectURL := fmt.Sprintf("%s/admin/scm-providers/%s/connected", h.cfg.Server.GetPublicURL(), providerID)
+	redirectURL := fmt.Sprintf("%s/admin/scm-providers/%s/connected", h.publicURL(c), providerID)
 	c.Redirect(http.StatusFound, redirectURL)
 }
 
@@ -396,7 +428,7 @@ func (h *SCMOAuthHandlers) RefreshToken(c *gin.Context) {
 		Kind:         provider.ProviderType,
 		ClientID:     provider.ClientID,
 		ClientSecret: clientSecret,
-		CallbackURL:  fmt.Sprintf("%s/api/v1/scm-providers/%s/oauth/callback", h.cfg.Server.GetPublicURL(), providerID),
+		CallbackURL:  fmt.Sprintf("%s/api/v1/scm-providers/%s/oauth/callback", h.publicURL(c), providerID),
 		TenantID:     refreshTenantID,
 	})
 	if err != nil {
@@ -533,6 +565,7 @@ func (h *SCMOAuthHandlers) SavePATToken(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save token"})
 		return
 	}
+	h.publishTokenConnected(provider, userID, "pat", existingToken != nil)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Personal Access Token saved successfully"})
 }
@@ -683,7 +716,7 @@ func (h *SCMOAuthHandlers) ListRepositories(c *gin.Context) {
 		InstanceBaseURL: baseURL,
 		ClientID:        provider.ClientID,
 		ClientSecret:    clientSecret,
-		CallbackURL:     fmt.Sprintf("%s/api/v1/scm-providers/%s/oauth/callback", h.cfg.Server.GetPublicURL(), providerID),
+		CallbackURL:     fmt.Sprintf("%s/api/v1/scm-providers/%s/oauth/callback", h.publicURL(c), providerID),
 		TenantID:        tenantID,
 	})
 	if err != nil {
@@ -1000,6 +1033,12 @@ func (h *SCMOAuthHandlers) refreshAndPersistToken(ctx context.Context, connector
 	return newToken, nil
 }
 
+// publicURL returns the public URL OAuth callback and redirect URLs are built
+// on: the tenant domain the request was made on, or server.public_url.
+func (h *SCMOAuthHandlers) publicURL(c *gin.Context) string {
+	return middleware.TenantPublicURL(c, h.cfg.Server.GetPublicURL())
+}
+
 // buildConnectorWithToken is a helper to build an SCM connector with a user's OAuth token.
 // If the stored token is already expired it will attempt a proactive refresh before returning.
 func (h *SCMOAuthHandlers) buildConnectorWithToken(ctx context.Context, providerID, userID uuid.UUID) (scm.Connector, *scm.OAuthToken, *scm.SCMUserTokenRecord, error) {
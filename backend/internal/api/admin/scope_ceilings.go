@@ -0,0 +1,234 @@
+// scope_ceilings.go implements the per-organization scope ceiling admin
+// endpoints and the clipping AuthHandlers applies when it issues a session
+// token. A ceiling only takes effect at the next login or token refresh:
+// tokens already issued keep their scopes until they expire.
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// ScopeCeilingHandlers serves the organization scope ceiling endpoints.
+type ScopeCeilingHandlers struct {
+	orgRepo     *repositories.OrganizationRepository
+	ceilingRepo *repositories.OrgScopeCeilingRepository
+}
+
+// NewScopeCeilingHandlers constructs a ScopeCeilingHandlers. identityDB backs
+// organizations; ceilingRepo runs on the registry's own connection.
+func NewScopeCeilingHandlers(identityDB *sql.DB, ceilingRepo *repositories.OrgScopeCeilingRepository) *ScopeCeilingHandlers {
+	return &ScopeCeilingHandlers{
+		orgRepo:     repositories.NewOrganizationRepository(identityDB),
+		ceilingRepo: ceilingRepo,
+	}
+}
+
+// UpdateScopeCeilingRequest is the body of PUT /organizations/:id/scope-ceiling.
+type UpdateScopeCeilingRequest struct {
+	// Scopes lists the scopes session tokens may carry on behalf of the
+	// organization. An empty list allows none.
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+// @Summary      Get organization scope ceiling
+// @Description  Returns the organization's scope ceiling, or `ceiling: null` when none is set.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  map[string]interface{}  "{\"ceiling\": OrgScopeCeiling|null}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/scope-ceiling [get]
+// GetCeilingHandler returns an organization's scope ceiling.
+// GET /api/v1/organizations/:id/scope-ceiling
+func (h *ScopeCeilingHandlers) GetCeilingHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ceiling, err := h.ceilingRepo.Get(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve scope ceiling"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ceiling": ceiling})
+	}
+}
+
+// @Summary      Set organization scope ceiling
+// @Description  Creates or replaces the organization's scope ceiling. When a session token is issued (login or refresh), the user's role-template scopes are intersected with the union of the ceilings of their organizations; an organization without a ceiling does not restrict its members, so a user is only clipped when every organization they belong to has one. The admin scope passes a ceiling only for users listed in auth.global_admins. Tokens already issued are unaffected until they are refreshed.
+// @Tags         Organizations
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                     true  "Organization ID"
+// @Param        body  body  UpdateScopeCeilingRequest  true  "Ceiling"
+// @Success      200  {object}  map[string]interface{}  "{\"ceiling\": OrgScopeCeiling}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Organization not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/scope-ceiling [put]
+// UpdateCeilingHandler creates or replaces an organization's scope ceiling.
+// PUT /api/v1/organizations/:id/scope-ceiling
+func (h *ScopeCeilingHandlers) UpdateCeilingHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateScopeCeilingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+		if err := auth.ValidateScopes(req.Scopes); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scopes: " + err.Error()})
+			return
+		}
+
+		org, err := h.orgRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		ceiling := &models.OrgScopeCeiling{OrganizationID: org.ID, Scopes: req.Scopes}
+		if err := h.ceilingRepo.Upsert(c.Request.Context(), ceiling); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save scope ceiling"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ceiling": ceiling})
+	}
+}
+
+// @Summary      Remove organization scope ceiling
+// @Description  Removes the organization's scope ceiling. Members get their full role-template scopes at their next login or token refresh.
+// @Tags         Organizations
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Organization ID"
+// @Success      200  {object}  admin.MessageResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "No scope ceiling set for this organization"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/organizations/{id}/scope-ceiling [delete]
+// DeleteCeilingHandler removes an organization's scope ceiling.
+// DELETE /api/v1/organizations/:id/scope-ceiling
+func (h *ScopeCeilingHandlers) DeleteCeilingHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deleted, err := h.ceilingRepo.Delete(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete scope ceiling"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No scope ceiling set for this organization"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Scope ceiling removed"})
+	}
+}
+
+// applyScopeCeilings clips scopes, a user's combined role-template scopes, to
+// the ceilings of the organizations in orgIDs (the user's memberships). The
+// ceilings are unioned: a scope survives when any of the organizations allows
+// it, and an organization without a ceiling allows every scope, so nothing is
+// clipped unless each organization has a ceiling. The admin scope survives
+// regardless when globalAdmin is set. Returns the scopes kept, in their
+// original order, and the scopes removed, sorted.
+func applyScopeCeilings(scopes, orgIDs []string, ceilings []*models.OrgScopeCeiling, globalAdmin bool) (kept, removed []string) {
+	if len(orgIDs) == 0 {
+		return scopes, []string{}
+	}
+	byOrg := make(map[string]*models.OrgScopeCeiling, len(ceilings))
+	for _, ceiling := range ceilings {
+		byOrg[ceiling.OrganizationID] = ceiling
+	}
+	allowed := map[string]bool{}
+	for _, orgID := range orgIDs {
+		ceiling, ok := byOrg[orgID]
+		if !ok {
+			return scopes, []string{}
+		}
+		for _, s := range ceiling.Scopes {
+			allowed[s] = true
+		}
+	}
+
+	kept = make([]string, 0, len(scopes))
+	removed = []string{}
+	for _, s := range scopes {
+		if allowed[s] || (s == string(auth.ScopeAdmin) && globalAdmin) {
+			kept = append(kept, s)
+		} else {
+			removed = append(removed, s)
+		}
+	}
+	sort.Strings(removed)
+	return kept, removed
+}
+
+// isGlobalAdmin reports whether auth.global_admins lists user, by ID or by
+// email address (case-insensitively).
+func isGlobalAdmin(globalAdmins []string, user *models.User) bool {
+	for _, entry := range globalAdmins {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if entry == user.ID || (user.Email != "" && strings.EqualFold(entry, user.Email)) {
+			return true
+		}
+	}
+	return false
+}
+
+// userScopeCeilings clips scopes, the user's combined role-template scopes,
+// to the ceilings of the user's organizations; see applyScopeCeilings.
+func (h *AuthHandlers) userScopeCeilings(ctx context.Context, user *models.User, scopes []string) (kept, removed []string, err error) {
+	if h.scopeCeilingRepo == nil {
+		return scopes, []string{}, nil
+	}
+	memberships, err := h.orgRepo.GetUserMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load organization memberships: %w", err)
+	}
+	orgIDs := make([]string, 0, len(memberships))
+	for _, m := range memberships {
+		orgIDs = append(orgIDs, m.OrganizationID)
+	}
+	ceilings, err := h.scopeCeilingRepo.ListByOrganizations(ctx, orgIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	kept, removed = applyScopeCeilings(scopes, orgIDs, ceilings, isGlobalAdmin(h.cfg.Auth.GlobalAdmins, user))
+	return kept, removed, nil
+}
+
+// issuedScopes returns the scopes a session token issued to user carries:
+// scopes clipped to the user's scope ceilings. When the ceilings cannot be
+// loaded the token carries no scopes, as when the scopes themselves cannot be.
+func (h *AuthHandlers) issuedScopes(ctx context.Context, user *models.User, scopes []string) []string {
+	kept, removed, err := h.userScopeCeilings(ctx, user, scopes)
+	if err != nil {
+		slog.Error("failed to apply scope ceilings", "user_id", user.ID, "error", err)
+		return []string{}
+	}
+	if len(removed) > 0 {
+		slog.Info("scope ceiling removed scopes from issued token", "user_id", user.ID, "removed", removed)
+	}
+	return kept
+}
@@ -0,0 +1,399 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var scopeCeilingCols = []string{"organization_id", "scopes", "created_at", "updated_at"}
+
+func newScopeCeilingRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewScopeCeilingHandlers(db, repositories.NewOrgScopeCeilingRepository(db))
+	r := gin.New()
+	r.GET("/organizations/:id/scope-ceiling", h.GetCeilingHandler())
+	r.PUT("/organizations/:id/scope-ceiling", h.UpdateCeilingHandler())
+	r.DELETE("/organizations/:id/scope-ceiling", h.DeleteCeilingHandler())
+	return mock, r
+}
+
+// newCeilingAuthRouter serves the refresh and me endpoints for user-1 with
+// scope ceilings enabled.
+func newCeilingAuthRouter(t *testing.T, globalAdmins ...string) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	cfg := &config.Config{}
+	cfg.Auth.GlobalAdmins = globalAdmins
+	h, err := NewAuthHandlers(cfg, db, nil, nil, auth.NewMemoryStateStore(time.Hour),
+		WithScopeCeilings(repositories.NewOrgScopeCeilingRepository(db)))
+	if err != nil {
+		t.Fatalf("NewAuthHandlers: %v", err)
+	}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Next()
+	})
+	r.GET("/auth/refresh", h.RefreshHandler())
+	r.GET("/auth/me", h.MeHandler())
+	return mock, r
+}
+
+// twoOrgMemberships returns user-1's memberships: a publisher in org-a and
+// an administrator in org-b.
+func twoOrgMemberships() *sqlmock.Rows {
+	return sqlmock.NewRows(meOrgMembershipCols).
+		AddRow("org-a", "alpha", "role-1", time.Now(), "publisher", "Publisher", `["modules:read","modules:write"]`).
+		AddRow("org-b", "beta", "role-2", time.Now(), "admin", "Administrator", `["admin","providers:write"]`)
+}
+
+// twoOrgCeilings returns conflicting ceilings for org-a and org-b.
+func twoOrgCeilings() *sqlmock.Rows {
+	return sqlmock.NewRows(scopeCeilingCols).
+		AddRow("org-a", []byte(`["modules:read"]`), time.Now(), time.Now()).
+		AddRow("org-b", []byte(`["modules:write","providers:read"]`), time.Now(), time.Now())
+}
+
+func ceilings(byOrg map[string][]string) []*models.OrgScopeCeiling {
+	var out []*models.OrgScopeCeiling
+	for orgID, scopes := range byOrg {
+		out = append(out, &models.OrgScopeCeiling{OrganizationID: orgID, Scopes: scopes})
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Ceiling arithmetic
+// ---------------------------------------------------------------------------
+
+func TestApplyScopeCeilings(t *testing.T) {
+	scopes := []string{"modules:read", "modules:write", "providers:write", "admin"}
+	tests := []struct {
+		name        string
+		orgIDs      []string
+		ceilings    []*models.OrgScopeCeiling
+		globalAdmin bool
+		wantKept    []string
+		wantRemoved []string
+	}{
+		{
+			name:     "no memberships",
+			wantKept: scopes, wantRemoved: []string{},
+		},
+		{
+			name:     "no ceilings",
+			orgIDs:   []string{"org-a", "org-b"},
+			wantKept: scopes, wantRemoved: []string{},
+		},
+		{
+			name:     "one organization without a ceiling",
+			orgIDs:   []string{"org-a", "org-b"},
+			ceilings: ceilings(map[string][]string{"org-a": {"modules:read"}}),
+			wantKept: scopes, wantRemoved: []string{},
+		},
+		{
+			name:        "conflicting ceilings are unioned",
+			orgIDs:      []string{"org-a", "org-b"},
+			ceilings:    ceilings(map[string][]string{"org-a": {"modules:read"}, "org-b": {"modules:write", "providers:read"}}),
+			wantKept:    []string{"modules:read", "modules:write"},
+			wantRemoved: []string{"admin", "providers:write"},
+		},
+		{
+			name:        "global admin keeps admin",
+			orgIDs:      []string{"org-a", "org-b"},
+			ceilings:    ceilings(map[string][]string{"org-a": {"modules:read"}, "org-b": {"modules:write"}}),
+			globalAdmin: true,
+			wantKept:    []string{"modules:read", "modules:write", "admin"},
+			wantRemoved: []string{"providers:write"},
+		},
+		{
+			name:        "ceiling listing admin",
+			orgIDs:      []string{"org-a"},
+			ceilings:    ceilings(map[string][]string{"org-a": {"admin"}}),
+			wantKept:    []string{"admin"},
+			wantRemoved: []string{"modules:read", "modules:write", "providers:write"},
+		},
+		{
+			name:        "empty ceilings",
+			orgIDs:      []string{"org-a", "org-b"},
+			ceilings:    ceilings(map[string][]string{"org-a": {}, "org-b": {}}),
+			wantKept:    []string{},
+			wantRemoved: []string{"admin", "modules:read", "modules:write", "providers:write"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, removed := applyScopeCeilings(scopes, tt.orgIDs, tt.ceilings, tt.globalAdmin)
+			if !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Errorf("kept = %v, want %v", kept, tt.wantKept)
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestIsGlobalAdmin(t *testing.T) {
+	user := &models.User{ID: "user-1", Email: "Ops@Example.com"}
+	tests := []struct {
+		admins []string
+		want   bool
+	}{
+		{nil, false},
+		{[]string{"user-2", "dev@example.com"}, false},
+		{[]string{"user-1"}, true},
+		{[]string{" ops@example.com "}, true},
+		{[]string{""}, false},
+	}
+	for _, tt := range tests {
+		if got := isGlobalAdmin(tt.admins, user); got != tt.want {
+			t.Errorf("isGlobalAdmin(%v) = %v, want %v", tt.admins, got, tt.want)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Issuance and /auth/me
+// ---------------------------------------------------------------------------
+
+func TestRefreshHandler_ScopeCeilings(t *testing.T) {
+	tests := []struct {
+		name         string
+		globalAdmins []string
+		want         []string
+	}{
+		{name: "clipped", want: []string{"modules:read", "modules:write"}},
+		{name: "global admin", globalAdmins: []string{"ops@example.com"}, want: []string{"admin", "modules:read", "modules:write"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, r := newCeilingAuthRouter(t, tt.globalAdmins...)
+			mock.ExpectQuery("SELECT.*FROM users WHERE id").
+				WillReturnRows(sqlmock.NewRows(authUserCols).
+					AddRow("user-1", "ops@example.com", "Ops", nil, time.Now(), time.Now()))
+			mock.ExpectQuery("SELECT.*FROM organization_members").WillReturnRows(twoOrgMemberships())
+			mock.ExpectQuery("SELECT.*FROM organization_members").WillReturnRows(twoOrgMemberships())
+			mock.ExpectQuery("SELECT.*FROM org_scope_ceilings").WillReturnRows(twoOrgCeilings())
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/refresh", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+
+			var token string
+			for _, c := range w.Result().Cookies() {
+				if c.Name == "tfr_auth_token" {
+					token = c.Value
+				}
+			}
+			claims, err := auth.ValidateJWT(token)
+			if err != nil {
+				t.Fatalf("ValidateJWT: %v", err)
+			}
+			got := append([]string(nil), claims.Scopes...)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("token scopes = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRefreshHandler_ScopeCeilingsUnavailable(t *testing.T) {
+	mock, r := newCeilingAuthRouter(t)
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").
+		WillReturnRows(sqlmock.NewRows(authUserCols).
+			AddRow("user-1", "ops@example.com", "Ops", nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM organization_members").WillReturnRows(twoOrgMemberships())
+	mock.ExpectQuery("SELECT.*FROM organization_members").WillReturnRows(twoOrgMemberships())
+	mock.ExpectQuery("SELECT.*FROM org_scope_ceilings").WillReturnError(errDB)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/refresh", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.Name != "tfr_auth_token" {
+			continue
+		}
+		claims, err := auth.ValidateJWT(c.Value)
+		if err != nil {
+			t.Fatalf("ValidateJWT: %v", err)
+		}
+		if len(claims.Scopes) != 0 {
+			t.Errorf("token scopes = %v, want none when ceilings cannot be loaded", claims.Scopes)
+		}
+	}
+}
+
+func TestMeHandler_ScopesRemovedByCeiling(t *testing.T) {
+	mock, r := newCeilingAuthRouter(t)
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").
+		WillReturnRows(sqlmock.NewRows(authUserCols).
+			AddRow("user-1", "ops@example.com", "Ops", nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM organization_members").WillReturnRows(twoOrgMemberships())
+	mock.ExpectQuery("SELECT.*FROM organization_members").WillReturnRows(twoOrgMemberships())
+	mock.ExpectQuery("SELECT.*FROM org_scope_ceilings").WillReturnRows(twoOrgCeilings())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/me", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp MeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	sort.Strings(resp.AllowedScopes)
+	if !reflect.DeepEqual(resp.AllowedScopes, []string{"modules:read", "modules:write"}) {
+		t.Errorf("allowed_scopes = %v", resp.AllowedScopes)
+	}
+	if !reflect.DeepEqual(resp.ScopesRemovedByCeiling, []string{"admin", "providers:write"}) {
+		t.Errorf("scopes_removed_by_ceiling = %v", resp.ScopesRemovedByCeiling)
+	}
+}
+
+func TestMeHandler_NoScopeCeilings(t *testing.T) {
+	mock, r := newCeilingAuthRouter(t)
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").
+		WillReturnRows(sqlmock.NewRows(authUserCols).
+			AddRow("user-1", "ops@example.com", "Ops", nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM organization_members").WillReturnRows(twoOrgMemberships())
+	mock.ExpectQuery("SELECT.*FROM organization_members").WillReturnRows(twoOrgMemberships())
+	mock.ExpectQuery("SELECT.*FROM org_scope_ceilings").WillReturnRows(sqlmock.NewRows(scopeCeilingCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/me", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp MeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.AllowedScopes) != 4 || resp.ScopesRemovedByCeiling == nil || len(resp.ScopesRemovedByCeiling) != 0 {
+		t.Errorf("allowed_scopes = %v, scopes_removed_by_ceiling = %v", resp.AllowedScopes, resp.ScopesRemovedByCeiling)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Ceiling CRUD
+// ---------------------------------------------------------------------------
+
+func TestGetScopeCeiling(t *testing.T) {
+	mock, r := newScopeCeilingRouter(t)
+	mock.ExpectQuery("SELECT.*FROM org_scope_ceilings").
+		WillReturnRows(sqlmock.NewRows(scopeCeilingCols).AddRow("org-1", []byte(`["modules:read"]`), time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM org_scope_ceilings").WillReturnRows(sqlmock.NewRows(scopeCeilingCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1/scope-ceiling", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	ceiling, _ := getJSON(w)["ceiling"].(map[string]interface{})
+	if scopes, _ := ceiling["scopes"].([]interface{}); len(scopes) != 1 || scopes[0] != "modules:read" {
+		t.Errorf("ceiling = %v", ceiling)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org-1/scope-ceiling", nil))
+	if w.Code != http.StatusOK || getJSON(w)["ceiling"] != nil {
+		t.Errorf("status = %d, body = %s, want 200 with null ceiling", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateScopeCeiling(t *testing.T) {
+	mock, r := newScopeCeilingRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows(orgSQLCols).AddRow("org-1", "acme", "Acme", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO org_scope_ceilings").
+		WithArgs("org-1", []byte(`["modules:read","providers:read"]`)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/org-1/scope-ceiling", jsonBody(map[string]interface{}{
+		"scopes": []string{"modules:read", "providers:read"},
+	})))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateScopeCeiling_Invalid(t *testing.T) {
+	for name, body := range map[string]interface{}{
+		"missing scopes": map[string]interface{}{},
+		"unknown scope":  map[string]interface{}{"scopes": []string{"modules:destroy"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, r := newScopeCeilingRouter(t)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/org-1/scope-ceiling", jsonBody(body)))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateScopeCeiling_OrgNotFound(t *testing.T) {
+	mock, r := newScopeCeilingRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sqlmock.NewRows(orgSQLCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/organizations/org-1/scope-ceiling", jsonBody(map[string]interface{}{
+		"scopes": []string{"modules:read"},
+	})))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestDeleteScopeCeiling(t *testing.T) {
+	mock, r := newScopeCeilingRouter(t)
+	mock.ExpectExec("DELETE FROM org_scope_ceilings").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM org_scope_ceilings").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/organizations/org-1/scope-ceiling", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/organizations/org-1/scope-ceiling", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 when no ceiling is set", w.Code)
+	}
+}
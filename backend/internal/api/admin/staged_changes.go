@@ -0,0 +1,515 @@
+// staged_changes.go implements the two-person rule. While it is on, changes
+// to sensitive resources (role templates, mirror policies, the OIDC group
+// mapping, and the rule itself) are stored as pending staged changes instead
+// of being applied, and a second admin approves or rejects them. Handlers opt
+// a mutation in by routing it through StagedChanges.Submit and registering an
+// applier that replays a stored change, so further resources can be added
+// without touching the review endpoints.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// stagedApplyError is a failure of a change that maps to an HTTP status,
+// returned by apply functions shared between the direct and staged paths.
+type stagedApplyError struct {
+	status  int
+	message string
+}
+
+func (e *stagedApplyError) Error() string { return e.message }
+
+func newStagedApplyError(status int, message string) error {
+	return &stagedApplyError{status: status, message: message}
+}
+
+// writeStagedApplyError writes err as a JSON error response, using its status
+// when it is a stagedApplyError and 500 otherwise.
+func writeStagedApplyError(c *gin.Context, err error) {
+	var applyErr *stagedApplyError
+	if errors.As(err, &applyErr) {
+		c.JSON(applyErr.status, gin.H{"error": applyErr.message})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply change"})
+}
+
+// StagedChangeApplier applies an approved staged change of one resource type
+// and returns the response body of the equivalent direct change. It must
+// revalidate the change, since the resource may have moved on since it was
+// requested.
+type StagedChangeApplier func(ctx context.Context, change *models.StagedChange) (interface{}, error)
+
+// StagedChangeInput describes a change being submitted.
+type StagedChangeInput struct {
+	ResourceType string
+	// ResourceID is empty for creates.
+	ResourceID string
+	Action     string
+	// Before is the current resource; nil for creates.
+	Before interface{}
+	// After is the request body the change was made with; nil for deletes.
+	// Appliers decode it back into the same type.
+	After interface{}
+}
+
+// StagedChanges routes sensitive changes through the two-person rule and
+// serves the endpoints reviewing them.
+type StagedChanges struct {
+	repo      *repositories.StagedChangeRepository
+	auditRepo *repositories.AuditRepository
+	appliers  map[string]StagedChangeApplier
+}
+
+// NewStagedChanges constructs a StagedChanges. auditRepo may be nil, in which
+// case reviews are not audited.
+func NewStagedChanges(repo *repositories.StagedChangeRepository, auditRepo *repositories.AuditRepository) *StagedChanges {
+	s := &StagedChanges{
+		repo:      repo,
+		auditRepo: auditRepo,
+		appliers:  map[string]StagedChangeApplier{},
+	}
+	s.Register(models.StagedResourceTwoPersonRule, s.applyTwoPersonRule)
+	return s
+}
+
+// Register sets the applier for staged changes of resourceType.
+func (s *StagedChanges) Register(resourceType string, applier StagedChangeApplier) {
+	s.appliers[resourceType] = applier
+}
+
+// Submit applies a change directly and responds with successStatus and
+// apply's result, or, while the two-person rule is on, stores it as a pending
+// staged change and responds 202 with it. A nil StagedChanges always applies
+// directly. If the rule setting cannot be read the change is refused rather
+// than applied unreviewed.
+func (s *StagedChanges) Submit(c *gin.Context, in StagedChangeInput, successStatus int, apply func(ctx context.Context) (interface{}, error)) {
+	ctx := c.Request.Context()
+	if s != nil {
+		enabled, err := s.repo.TwoPersonRuleEnabled(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get two-person rule setting"})
+			return
+		}
+		if enabled {
+			s.stage(c, in)
+			return
+		}
+	}
+
+	result, err := apply(ctx)
+	if err != nil {
+		writeStagedApplyError(c, err)
+		return
+	}
+	c.JSON(successStatus, result)
+}
+
+func (s *StagedChanges) stage(c *gin.Context, in StagedChangeInput) {
+	requester := contextUserUUID(c)
+	if requester == uuid.Nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "The two-person rule requires changes to be made by a user"})
+		return
+	}
+	requesterID := requester.String()
+
+	change := &models.StagedChange{
+		ResourceType: in.ResourceType,
+		Action:       in.Action,
+		RequestedBy:  &requesterID,
+	}
+	if in.ResourceID != "" {
+		change.ResourceID = &in.ResourceID
+	}
+	var err error
+	if in.Before != nil {
+		if change.Before, err = json.Marshal(in.Before); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode staged change"})
+			return
+		}
+	}
+	if in.After != nil {
+		if change.After, err = json.Marshal(in.After); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode staged change"})
+			return
+		}
+	}
+
+	if err := s.repo.Create(c.Request.Context(), change); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stage change"})
+		return
+	}
+	s.audit(c, "staged_change.requested", change)
+	c.JSON(http.StatusAccepted, change)
+}
+
+// audit records a staged change event with both actors. Best-effort: the
+// event has already happened, so a write failure is only logged.
+func (s *StagedChanges) audit(c *gin.Context, action string, change *models.StagedChange) {
+	if s.auditRepo == nil {
+		return
+	}
+	resourceType := "staged_change"
+	ip := c.ClientIP()
+	metadata := map[string]interface{}{
+		"resource_type": change.ResourceType,
+		"change_action": change.Action,
+	}
+	if change.ResourceID != nil {
+		metadata["resource_id"] = *change.ResourceID
+	}
+	if change.RequestedBy != nil {
+		metadata["requested_by"] = *change.RequestedBy
+	}
+	if change.ReviewedBy != nil {
+		metadata["reviewed_by"] = *change.ReviewedBy
+	}
+	if change.ApplyError != nil {
+		metadata["apply_error"] = *change.ApplyError
+	}
+	entry := &models.AuditLog{
+		Action:       action,
+		ResourceType: &resourceType,
+		ResourceID:   &change.ID,
+		Metadata:     metadata,
+		IPAddress:    &ip,
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(string); ok && id != "" {
+			entry.UserID = &id
+		}
+	}
+	if err := s.auditRepo.CreateAuditLog(c.Request.Context(), entry); err != nil {
+		slog.Error("failed to write audit log for staged change", "error", err, "action", action, "staged_change_id", change.ID)
+	}
+}
+
+// @Summary      List staged changes
+// @Description  Lists the changes submitted while the two-person rule was on, newest first, with the resource before the change and the requested change. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Param        status  query  string  false  "Filter by status (pending, approved, rejected, failed)"
+// @Success      200  {array}   models.StagedChange
+// @Failure      400  {object}  map[string]interface{}  "Invalid status"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/staged-changes [get]
+// ListStagedChanges lists staged changes.
+// GET /api/v1/admin/staged-changes
+func (s *StagedChanges) ListStagedChanges(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.StagedChangeStatusPending, models.StagedChangeStatusApproved,
+		models.StagedChangeStatusRejected, models.StagedChangeStatusFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of pending, approved, rejected, failed"})
+		return
+	}
+
+	changes, err := s.repo.List(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list staged changes"})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// @Summary      Get staged change
+// @Description  Returns a staged change with the resource before the change and the requested change. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Staged change ID (UUID)"
+// @Success      200  {object}  models.StagedChange
+// @Failure      400  {object}  map[string]interface{}  "Invalid staged change ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Staged change not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/staged-changes/{id} [get]
+// GetStagedChange returns a staged change.
+// GET /api/v1/admin/staged-changes/:id
+func (s *StagedChanges) GetStagedChange(c *gin.Context) {
+	change, ok := s.loadChange(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, change)
+}
+
+func (s *StagedChanges) loadChange(c *gin.Context) (*models.StagedChange, bool) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid staged change ID"})
+		return nil, false
+	}
+	change, err := s.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get staged change"})
+		return nil, false
+	}
+	if change == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staged change not found"})
+		return nil, false
+	}
+	return change, true
+}
+
+// ReviewStagedChangeRequest is the optional body of the approve and reject
+// endpoints.
+type ReviewStagedChangeRequest struct {
+	Comment string `json:"comment"`
+}
+
+// StagedChangeApprovalResponse is returned when a staged change is approved
+// and applied.
+type StagedChangeApprovalResponse struct {
+	Change *models.StagedChange `json:"change"`
+	// Result is the response body the change would have returned had it been
+	// applied directly.
+	Result interface{} `json:"result"`
+}
+
+// @Summary      Approve staged change
+// @Description  Approves a pending staged change and applies it. The approver must be a different admin from the one who requested it. The change is revalidated when applied; if that fails the change is marked failed and the error is returned. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                     true   "Staged change ID (UUID)"
+// @Param        body  body  ReviewStagedChangeRequest  false  "Review comment"
+// @Success      200  {object}  admin.StagedChangeApprovalResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid request or ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Requester cannot approve their own change"
+// @Failure      404  {object}  map[string]interface{}  "Staged change not found"
+// @Failure      409  {object}  map[string]interface{}  "Staged change is not pending, or no longer applies"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/staged-changes/{id}/approve [post]
+// ApproveStagedChange approves and applies a staged change.
+// POST /api/v1/admin/staged-changes/:id/approve
+func (s *StagedChanges) ApproveStagedChange(c *gin.Context) {
+	req, ok := bindReviewStagedChange(c)
+	if !ok {
+		return
+	}
+	change, ok := s.loadChange(c)
+	if !ok {
+		return
+	}
+	reviewer := contextUserUUID(c)
+	if reviewer == uuid.Nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Staged changes must be reviewed by a user"})
+		return
+	}
+	if change.RequestedBy != nil && *change.RequestedBy == reviewer.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "A staged change must be approved by a different admin than the one who requested it"})
+		return
+	}
+	applier, ok := s.appliers[change.ResourceType]
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No applier for staged change resource type " + change.ResourceType})
+		return
+	}
+
+	change, ok = s.review(c, change.ID, models.StagedChangeStatusApproved, reviewer, req.Comment)
+	if !ok {
+		return
+	}
+
+	result, err := applier(c.Request.Context(), change)
+	if err != nil {
+		message := err.Error()
+		var applyErr *stagedApplyError
+		status := http.StatusInternalServerError
+		if errors.As(err, &applyErr) {
+			status = applyErr.status
+		}
+		if markErr := s.repo.MarkFailed(c.Request.Context(), change.ID, message); markErr != nil {
+			slog.Error("failed to mark staged change failed", "error", markErr, "staged_change_id", change.ID)
+		}
+		change.Status = models.StagedChangeStatusFailed
+		change.ApplyError = &message
+		s.audit(c, "staged_change.failed", change)
+		c.JSON(status, gin.H{"error": "Failed to apply staged change: " + message, "change": change})
+		return
+	}
+
+	s.audit(c, "staged_change.approved", change)
+	c.JSON(http.StatusOK, StagedChangeApprovalResponse{Change: change, Result: result})
+}
+
+// @Summary      Reject staged change
+// @Description  Rejects a pending staged change without applying it. Any other admin can reject a change, and its requester can withdraw it. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                     true   "Staged change ID (UUID)"
+// @Param        body  body  ReviewStagedChangeRequest  false  "Review comment"
+// @Success      200  {object}  models.StagedChange
+// @Failure      400  {object}  map[string]interface{}  "Invalid request or ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Staged change not found"
+// @Failure      409  {object}  map[string]interface{}  "Staged change is not pending"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/staged-changes/{id}/reject [post]
+// RejectStagedChange rejects a staged change.
+// POST /api/v1/admin/staged-changes/:id/reject
+func (s *StagedChanges) RejectStagedChange(c *gin.Context) {
+	req, ok := bindReviewStagedChange(c)
+	if !ok {
+		return
+	}
+	change, ok := s.loadChange(c)
+	if !ok {
+		return
+	}
+	reviewer := contextUserUUID(c)
+	if reviewer == uuid.Nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Staged changes must be reviewed by a user"})
+		return
+	}
+
+	change, ok = s.review(c, change.ID, models.StagedChangeStatusRejected, reviewer, req.Comment)
+	if !ok {
+		return
+	}
+	s.audit(c, "staged_change.rejected", change)
+	c.JSON(http.StatusOK, change)
+}
+
+// bindReviewStagedChange binds the optional review body; an empty body is
+// allowed.
+func bindReviewStagedChange(c *gin.Context) (ReviewStagedChangeRequest, bool) {
+	var req ReviewStagedChangeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return req, false
+		}
+	}
+	return req, true
+}
+
+func (s *StagedChanges) review(c *gin.Context, id, status string, reviewer uuid.UUID, comment string) (*models.StagedChange, bool) {
+	var commentPtr *string
+	if comment != "" {
+		commentPtr = &comment
+	}
+	change, err := s.repo.Review(c.Request.Context(), id, status, reviewer.String(), commentPtr)
+	if errors.Is(err, repositories.ErrStagedChangeNotPending) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Staged change is not pending"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review staged change"})
+		return nil, false
+	}
+	return change, true
+}
+
+// TwoPersonRuleResponse is the body of GET and PUT /admin/two-person-rule.
+type TwoPersonRuleResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// TwoPersonRuleRequest is the body of PUT /admin/two-person-rule.
+type TwoPersonRuleRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// @Summary      Get two-person rule
+// @Description  Returns whether changes to role templates, mirror policies and the OIDC group mapping must be approved by a second admin. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  admin.TwoPersonRuleResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/two-person-rule [get]
+// GetTwoPersonRule returns the two-person rule setting.
+// GET /api/v1/admin/two-person-rule
+func (s *StagedChanges) GetTwoPersonRule(c *gin.Context) {
+	enabled, err := s.repo.TwoPersonRuleEnabled(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get two-person rule setting"})
+		return
+	}
+	c.JSON(http.StatusOK, TwoPersonRuleResponse{Enabled: enabled})
+}
+
+// @Summary      Set two-person rule
+// @Description  Turns the two-person rule on or off. While it is on, changes to role templates, mirror policies and the OIDC group mapping are staged and answered with 202 until a different admin approves them. Turning the rule on applies immediately; turning it off while it is on is itself a staged change. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  TwoPersonRuleRequest  true  "Two-person rule setting"
+// @Success      200  {object}  admin.TwoPersonRuleResponse
+// @Success      202  {object}  models.StagedChange
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/two-person-rule [put]
+// SetTwoPersonRule turns the two-person rule on or off.
+// PUT /api/v1/admin/two-person-rule
+func (s *StagedChanges) SetTwoPersonRule(c *gin.Context) {
+	var req TwoPersonRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	apply := func(ctx context.Context) (interface{}, error) {
+		return s.setTwoPersonRule(ctx, *req.Enabled)
+	}
+
+	// Turning the rule on only makes changes stricter, so it never needs a
+	// second admin.
+	if *req.Enabled {
+		result, err := apply(c.Request.Context())
+		if err != nil {
+			writeStagedApplyError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	s.Submit(c, StagedChangeInput{
+		ResourceType: models.StagedResourceTwoPersonRule,
+		Action:       models.StagedActionUpdate,
+		Before:       TwoPersonRuleResponse{Enabled: true},
+		After:        req,
+	}, http.StatusOK, apply)
+}
+
+func (s *StagedChanges) setTwoPersonRule(ctx context.Context, enabled bool) (*TwoPersonRuleResponse, error) {
+	if err := s.repo.SetTwoPersonRule(ctx, enabled); err != nil {
+		return nil, newStagedApplyError(http.StatusInternalServerError, "Failed to set two-person rule setting")
+	}
+	return &TwoPersonRuleResponse{Enabled: enabled}, nil
+}
+
+func (s *StagedChanges) applyTwoPersonRule(ctx context.Context, change *models.StagedChange) (interface{}, error) {
+	var req TwoPersonRuleRequest
+	if err := json.Unmarshal(change.After, &req); err != nil || req.Enabled == nil {
+		return nil, newStagedApplyError(http.StatusBadRequest, "Staged two-person rule change is malformed")
+	}
+	return s.setTwoPersonRule(ctx, *req.Enabled)
+}
@@ -0,0 +1,269 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+const otherAdminUUID = "33333333-3333-3333-3333-333333333333"
+
+var stagedChangeCols = []string{
+	"id", "resource_type", "resource_id", "action", "before_state", "after_state", "status",
+	"requested_by", "reviewed_by", "review_comment", "apply_error", "created_at", "reviewed_at",
+}
+
+// newStagedChangesRouter wires RBAC handlers through a StagedChanges over one
+// mocked connection, and audit logging over a second. The acting user is
+// knownUserUUID unless the X-Test-User header names another.
+func newStagedChangesRouter(t *testing.T) (sqlmock.Sqlmock, sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	auditDB, auditMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { auditDB.Close() })
+
+	staged := NewStagedChanges(repositories.NewStagedChangeRepository(db), repositories.NewAuditRepository(auditDB))
+	h := NewRBACHandlers(repositories.NewRBACRepository(sqlx.NewDb(db, "sqlmock")), nil).WithStagedChanges(staged)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		user := c.GetHeader("X-Test-User")
+		if user == "" {
+			user = knownUserUUID
+		}
+		c.Set("user_id", user)
+	})
+	r.POST("/role-templates", h.CreateRoleTemplate)
+	r.DELETE("/policies/:id", h.DeleteMirrorPolicy)
+	r.GET("/staged-changes", staged.ListStagedChanges)
+	r.POST("/staged-changes/:id/approve", staged.ApproveStagedChange)
+	r.POST("/staged-changes/:id/reject", staged.RejectStagedChange)
+	r.PUT("/two-person-rule", staged.SetTwoPersonRule)
+	return mock, auditMock, r
+}
+
+func doStagedReq(r *gin.Engine, method, path, user, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func expectTwoPersonRule(mock sqlmock.Sqlmock, enabled bool) {
+	mock.ExpectQuery("SELECT two_person_rule_enabled FROM system_settings").
+		WillReturnRows(sqlmock.NewRows([]string{"two_person_rule_enabled"}).AddRow(enabled))
+}
+
+// pendingPolicyDeleteRow is a pending staged deletion of mirror policy
+// knownUUID requested by knownUserUUID.
+func pendingPolicyDeleteRow(status string, reviewer interface{}) *sqlmock.Rows {
+	return sqlmock.NewRows(stagedChangeCols).AddRow(
+		knownUUID, models.StagedResourceMirrorPolicy, knownUUID, models.StagedActionDelete,
+		[]byte(`{"name":"p"}`), nil, status, knownUserUUID, reviewer, nil, nil, time.Now(), nil)
+}
+
+func TestSubmit_RuleOffAppliesDirectly(t *testing.T) {
+	mock, _, r := newStagedChangesRouter(t)
+	expectTwoPersonRule(mock, false)
+	mock.ExpectQuery("SELECT.*FROM role_templates WHERE name").WillReturnRows(sqlmock.NewRows(rtCols))
+	mock.ExpectExec("INSERT INTO role_templates").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := doStagedReq(r, http.MethodPost, "/role-templates", "",
+		`{"name":"ops","display_name":"Ops","scopes":["modules:read"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSubmit_RuleOnStagesChange(t *testing.T) {
+	mock, auditMock, r := newStagedChangesRouter(t)
+	expectTwoPersonRule(mock, true)
+	mock.ExpectQuery("INSERT INTO staged_changes").
+		WithArgs(models.StagedResourceRoleTemplate, nil, models.StagedActionCreate, nil, sqlmock.AnyArg(), knownUserUUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow(knownUUID, "pending", time.Now()))
+	auditMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doStagedReq(r, http.MethodPost, "/role-templates", "",
+		`{"name":"ops","display_name":"Ops","scopes":["modules:read"]}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", w.Code, w.Body.String())
+	}
+	var change models.StagedChange
+	if err := json.Unmarshal(w.Body.Bytes(), &change); err != nil {
+		t.Fatal(err)
+	}
+	if change.Status != models.StagedChangeStatusPending || !strings.Contains(string(change.After), `"name":"ops"`) {
+		t.Errorf("change = %+v", change)
+	}
+	// The role template itself must not have been written.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSubmit_SettingErrorFailsClosed(t *testing.T) {
+	mock, _, r := newStagedChangesRouter(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_policies WHERE id").WillReturnRows(sampleMPRow())
+	mock.ExpectQuery("SELECT two_person_rule_enabled").WillReturnError(errDB)
+
+	w := doStagedReq(r, http.MethodDelete, "/policies/"+knownUUID, "", "")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApproveStagedChange_RequesterCannotApprove(t *testing.T) {
+	mock, _, r := newStagedChangesRouter(t)
+	mock.ExpectQuery("SELECT .* FROM staged_changes").WillReturnRows(pendingPolicyDeleteRow("pending", nil))
+
+	w := doStagedReq(r, http.MethodPost, "/staged-changes/"+knownUUID+"/approve", knownUserUUID, "")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestApproveStagedChange_AppliesAndAuditsBothActors(t *testing.T) {
+	mock, auditMock, r := newStagedChangesRouter(t)
+	mock.ExpectQuery("SELECT .* FROM staged_changes").WillReturnRows(pendingPolicyDeleteRow("pending", nil))
+	mock.ExpectQuery("UPDATE staged_changes").
+		WithArgs(knownUUID, models.StagedChangeStatusApproved, otherAdminUUID, "looks right").
+		WillReturnRows(pendingPolicyDeleteRow("approved", otherAdminUUID))
+	mock.ExpectQuery("SELECT.*FROM mirror_policies WHERE id").WillReturnRows(sampleMPRow())
+	mock.ExpectExec("DELETE FROM mirror_policies").WillReturnResult(sqlmock.NewResult(1, 1))
+	auditMock.ExpectExec("INSERT INTO audit_logs").
+		WithArgs(sqlmock.AnyArg(), otherAdminUUID, nil, "staged_change.approved", "staged_change", knownUUID,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doStagedReq(r, http.MethodPost, "/staged-changes/"+knownUUID+"/approve", otherAdminUUID, `{"comment":"looks right"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp StagedChangeApprovalResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Change.Status != models.StagedChangeStatusApproved || *resp.Change.RequestedBy != knownUserUUID ||
+		*resp.Change.ReviewedBy != otherAdminUUID {
+		t.Errorf("change = %+v", resp.Change)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if err := auditMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApproveStagedChange_ApplyFailureMarksFailed(t *testing.T) {
+	mock, auditMock, r := newStagedChangesRouter(t)
+	mock.ExpectQuery("SELECT .* FROM staged_changes").WillReturnRows(pendingPolicyDeleteRow("pending", nil))
+	mock.ExpectQuery("UPDATE staged_changes").WillReturnRows(pendingPolicyDeleteRow("approved", otherAdminUUID))
+	// The policy was deleted by someone else in the meantime.
+	mock.ExpectQuery("SELECT.*FROM mirror_policies WHERE id").WillReturnRows(sqlmock.NewRows(mpCols))
+	mock.ExpectExec("UPDATE staged_changes SET status = 'failed'").
+		WithArgs(knownUUID, "Policy not found").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	auditMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doStagedReq(r, http.MethodPost, "/staged-changes/"+knownUUID+"/approve", otherAdminUUID, "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApproveStagedChange_AlreadyReviewed(t *testing.T) {
+	mock, _, r := newStagedChangesRouter(t)
+	mock.ExpectQuery("SELECT .* FROM staged_changes").WillReturnRows(pendingPolicyDeleteRow("rejected", otherAdminUUID))
+	mock.ExpectQuery("UPDATE staged_changes").WillReturnRows(sqlmock.NewRows(stagedChangeCols))
+
+	w := doStagedReq(r, http.MethodPost, "/staged-changes/"+knownUUID+"/approve", otherAdminUUID, "")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRejectStagedChange_RequesterCanWithdraw(t *testing.T) {
+	mock, auditMock, r := newStagedChangesRouter(t)
+	mock.ExpectQuery("SELECT .* FROM staged_changes").WillReturnRows(pendingPolicyDeleteRow("pending", nil))
+	mock.ExpectQuery("UPDATE staged_changes").
+		WithArgs(knownUUID, models.StagedChangeStatusRejected, knownUserUUID, nil).
+		WillReturnRows(pendingPolicyDeleteRow("rejected", knownUserUUID))
+	auditMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doStagedReq(r, http.MethodPost, "/staged-changes/"+knownUUID+"/reject", knownUserUUID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListStagedChanges_InvalidStatus(t *testing.T) {
+	_, _, r := newStagedChangesRouter(t)
+	w := doStagedReq(r, http.MethodGet, "/staged-changes?status=bogus", "", "")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestSetTwoPersonRule_EnableAppliesImmediately(t *testing.T) {
+	mock, _, r := newStagedChangesRouter(t)
+	mock.ExpectExec("UPDATE system_settings SET two_person_rule_enabled").
+		WithArgs(true).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doStagedReq(r, http.MethodPut, "/two-person-rule", "", `{"enabled":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSetTwoPersonRule_DisableIsStaged(t *testing.T) {
+	mock, auditMock, r := newStagedChangesRouter(t)
+	expectTwoPersonRule(mock, true)
+	mock.ExpectQuery("INSERT INTO staged_changes").
+		WithArgs(models.StagedResourceTwoPersonRule, nil, models.StagedActionUpdate, `{"enabled":true}`, `{"enabled":false}`, knownUserUUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow(knownUUID, "pending", time.Now()))
+	auditMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doStagedReq(r, http.MethodPut, "/two-person-rule", "", `{"enabled":false}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
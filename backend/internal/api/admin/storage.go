@@ -158,7 +158,7 @@ func (h *StorageHandlers) GetStorageConfig(c *gin.Context) {
 }
 
 // @Summary      Create storage configuration
-// @Description  Create a new storage configuration. Supported backends: local, azure, s3, gcs. Requires admin scope.
+// @Description  Create a new storage configuration. Supported backends: local, azure, s3, gcs, artifactory. Requires admin scope.
 // @Tags         Storage
 // @Security     Bearer
 // @Accept       json
@@ -181,8 +181,8 @@ func (h *StorageHandlers) CreateStorageConfig(c *gin.Context) {
 	}
 
 	// Validate backend type
-	if input.BackendType != "local" && input.BackendType != "azure" && input.BackendType != "s3" && input.BackendType != "gcs" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid backend_type: must be local, azure, s3, or gcs"})
+	if input.BackendType != "local" && input.BackendType != "azure" && input.BackendType != "s3" && input.BackendType != "gcs" && input.BackendType != "artifactory" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid backend_type: must be local, azure, s3, gcs, or artifactory"})
 		return
 	}
 
@@ -441,7 +441,7 @@ func (h *StorageHandlers) ActivateStorageConfig(c *gin.Context) {
 // @Description  Validates a storage configuration and performs a live connectivity probe against the target backend
 // @Description  without saving anything to the database. The backend is instantiated from the provided input, then
 // @Description  an Exists probe (10-second timeout) is executed to confirm reachability and correct credentials.
-// @Description  Supported backends: local, azure, s3, gcs. Requires admin scope.
+// @Description  Supported backends: local, azure, s3, gcs, artifactory. Requires admin scope.
 // @Tags         Storage
 // @Security     Bearer
 // @Accept       json
@@ -505,6 +505,12 @@ func (h *StorageHandlers) TestStorageConfig(c *gin.Context) {
 			CredentialsJSON: input.GCSCredentialsJSON,
 			Endpoint:        input.GCSEndpoint,
 		}
+	case "artifactory":
+		testCfg.Storage.Artifactory = config.ArtifactoryStorageConfig{
+			BaseURL:     input.ArtifactoryBaseURL,
+			Repository:  input.ArtifactoryRepository,
+			AccessToken: input.ArtifactoryAccessToken,
+		}
 	}
 
 	// Instantiate the backend
@@ -582,6 +588,10 @@ func (h *StorageHandlers) validateStorageConfig(input *models.StorageConfigInput
 				return &ValidationError{Field: "gcs_credentials", Message: "credentials_file or credentials_json required for service_account auth"}
 			}
 		}
+	case "artifactory":
+		if input.ArtifactoryBaseURL == "" {
+			return &ValidationError{Field: "artifactory_base_url", Message: "required for Artifactory storage"}
+		}
 	}
 	return nil
 }
@@ -658,6 +668,17 @@ func (h *StorageHandlers) buildStorageConfig(input *models.StorageConfigInput, u
 			}
 			config.GCSCredentialsJSONEncrypted = sql.NullString{String: encrypted, Valid: true}
 		}
+
+	case "artifactory":
+		config.ArtifactoryBaseURL = sql.NullString{String: input.ArtifactoryBaseURL, Valid: input.ArtifactoryBaseURL != ""}
+		config.ArtifactoryRepository = sql.NullString{String: input.ArtifactoryRepository, Valid: input.ArtifactoryRepository != ""}
+		if input.ArtifactoryAccessToken != "" {
+			encrypted, err := h.tokenCipher.Seal(input.ArtifactoryAccessToken)
+			if err != nil {
+				return nil, err
+			}
+			config.ArtifactoryAccessTokenEncrypted = sql.NullString{String: encrypted, Valid: true}
+		}
 	}
 
 	return config, nil
@@ -724,6 +745,17 @@ func (h *StorageHandlers) updateStorageConfigFromInput(config *models.StorageCon
 			}
 			config.GCSCredentialsJSONEncrypted = sql.NullString{String: encrypted, Valid: true}
 		}
+
+	case "artifactory":
+		config.ArtifactoryBaseURL = sql.NullString{String: input.ArtifactoryBaseURL, Valid: input.ArtifactoryBaseURL != ""}
+		config.ArtifactoryRepository = sql.NullString{String: input.ArtifactoryRepository, Valid: input.ArtifactoryRepository != ""}
+		if input.ArtifactoryAccessToken != "" {
+			encrypted, err := h.tokenCipher.Seal(input.ArtifactoryAccessToken)
+			if err != nil {
+				return err
+			}
+			config.ArtifactoryAccessTokenEncrypted = sql.NullString{String: encrypted, Valid: true}
+		}
 	}
 
 	return nil
@@ -0,0 +1,285 @@
+// storage_consistency.go implements the admin endpoints of the storage
+// consistency checker (jobs.StorageConsistencyJob): its report of artifacts
+// whose storage object is missing, an on-demand run, marking an artifact
+// broken so downloads answer 410 Gone, and repairing a mirrored artifact by
+// having its mirror download it again.
+package admin
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// StorageConsistencyCheckerInterface queues an on-demand consistency check.
+// *jobs.StorageConsistencyJob satisfies it.
+type StorageConsistencyCheckerInterface interface {
+	TriggerCheck() bool
+}
+
+// StorageConsistencyHandlers serves the storage consistency admin endpoints.
+type StorageConsistencyHandlers struct {
+	cfg     *config.StorageConsistencyConfig
+	repo    *repositories.StorageConsistencyRepository
+	checker StorageConsistencyCheckerInterface
+	// Repairing a mirrored provider platform deletes its row and re-syncs its
+	// mirror; nil rejects the repair with 503.
+	providerRepo *repositories.ProviderRepository
+	mirrorSync   MirrorSyncJobInterface
+	// Repairing a Terraform binary platform marks it pending and re-syncs its
+	// mirror config; nil rejects the repair with 503.
+	tfMirrorRepo *repositories.TerraformMirrorRepository
+	tfMirrorSync TerraformMirrorSyncJobInterface
+}
+
+// NewStorageConsistencyHandlers constructs a StorageConsistencyHandlers.
+func NewStorageConsistencyHandlers(cfg *config.StorageConsistencyConfig, repo *repositories.StorageConsistencyRepository) *StorageConsistencyHandlers {
+	return &StorageConsistencyHandlers{cfg: cfg, repo: repo}
+}
+
+// SetChecker enables POST /admin/consistency/run.
+func (h *StorageConsistencyHandlers) SetChecker(checker StorageConsistencyCheckerInterface) {
+	h.checker = checker
+}
+
+// SetProviderRepair enables repairing findings of mirrored provider platforms.
+func (h *StorageConsistencyHandlers) SetProviderRepair(providerRepo *repositories.ProviderRepository, syncJob MirrorSyncJobInterface) {
+	h.providerRepo = providerRepo
+	h.mirrorSync = syncJob
+}
+
+// SetTerraformRepair enables repairing findings of Terraform binary platforms.
+func (h *StorageConsistencyHandlers) SetTerraformRepair(repo *repositories.TerraformMirrorRepository, syncJob TerraformMirrorSyncJobInterface) {
+	h.tfMirrorRepo = repo
+	h.tfMirrorSync = syncJob
+}
+
+// StorageConsistencyCounts summarises the open findings.
+type StorageConsistencyCounts struct {
+	Critical int `json:"critical"`
+	Warning  int `json:"warning"`
+	Broken   int `json:"broken"`
+}
+
+// StorageConsistencyReportResponse is returned by GET /api/v1/admin/consistency/report.
+type StorageConsistencyReportResponse struct {
+	Enabled    bool                                `json:"enabled"`
+	MarkBroken bool                                `json:"mark_broken"`
+	LatestRun  *models.StorageConsistencyRun       `json:"latest_run"`
+	Counts     StorageConsistencyCounts            `json:"counts"`
+	Findings   []*models.StorageConsistencyFinding `json:"findings"`
+}
+
+// @Summary      Get storage consistency report
+// @Description  Returns the latest storage consistency run and the open findings: module versions, provider platforms and Terraform binary platforms whose storage object is missing. Findings of mirrored artifacts are warnings, since the mirror can download them again; findings of uploaded artifacts are critical and need the artifact re-published. Critical findings are listed first.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  admin.StorageConsistencyReportResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/consistency/report [get]
+// GetReport returns the storage consistency report.
+// GET /api/v1/admin/consistency/report
+func (h *StorageConsistencyHandlers) GetReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	run, err := h.repo.LatestRun(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get storage consistency run"})
+		return
+	}
+	findings, err := h.repo.ListFindings(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list storage consistency findings"})
+		return
+	}
+
+	resp := StorageConsistencyReportResponse{
+		Enabled:    h.cfg.Enabled,
+		MarkBroken: h.cfg.MarkBroken,
+		LatestRun:  run,
+		Findings:   findings,
+	}
+	for _, f := range findings {
+		if f.Severity == models.ConsistencySeverityCritical {
+			resp.Counts.Critical++
+		} else {
+			resp.Counts.Warning++
+		}
+		if f.BrokenAt != nil {
+			resp.Counts.Broken++
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Run storage consistency check
+// @Description  Queues a storage consistency check to run now instead of at the next interval. The result appears in the report once the run completes.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Success      202  {object}  admin.MessageResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      409  {object}  map[string]interface{}  "Checker disabled (storage_consistency.enabled=false)"
+// @Failure      503  {object}  map[string]interface{}  "Checker not configured"
+// @Router       /api/v1/admin/consistency/run [post]
+// RunCheck queues an on-demand check.
+// POST /api/v1/admin/consistency/run
+func (h *StorageConsistencyHandlers) RunCheck(c *gin.Context) {
+	if h.checker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Storage consistency checker not configured"})
+		return
+	}
+	if !h.checker.TriggerCheck() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Storage consistency checker is disabled (storage_consistency.enabled=false)"})
+		return
+	}
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "Storage consistency check queued"})
+}
+
+// @Summary      Mark artifact broken
+// @Description  Marks the artifact of a storage consistency finding broken: its downloads answer 410 Gone instead of a URL to the missing object. Findings are marked broken automatically with storage_consistency.mark_broken. The mark is removed with the finding, once the object is back or the artifact is repaired.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Finding ID"
+// @Success      200  {object}  models.StorageConsistencyFinding
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Finding not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/consistency/findings/{id}/broken [post]
+// MarkBroken marks a finding's artifact broken.
+// POST /api/v1/admin/consistency/findings/:id/broken
+func (h *StorageConsistencyHandlers) MarkBroken(c *gin.Context) {
+	h.setBroken(c, true)
+}
+
+// @Summary      Unmark artifact broken
+// @Description  Makes the artifact of a storage consistency finding downloadable again while the finding stays open, for example while the object is being restored from a backup.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Finding ID"
+// @Success      200  {object}  models.StorageConsistencyFinding
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Finding not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/consistency/findings/{id}/broken [delete]
+// UnmarkBroken clears a finding's broken mark.
+// DELETE /api/v1/admin/consistency/findings/:id/broken
+func (h *StorageConsistencyHandlers) UnmarkBroken(c *gin.Context) {
+	h.setBroken(c, false)
+}
+
+func (h *StorageConsistencyHandlers) setBroken(c *gin.Context, broken bool) {
+	if _, err := uuid.Parse(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Finding not found"})
+		return
+	}
+	f, err := h.repo.SetBroken(c.Request.Context(), c.Param("id"), broken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update finding"})
+		return
+	}
+	if f == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Finding not found"})
+		return
+	}
+	c.JSON(http.StatusOK, f)
+}
+
+// @Summary      Repair mirrored artifact
+// @Description  Repairs the artifact of a finding by having its mirror download it again: a provider platform's row is deleted and its provider mirror re-synced, a Terraform binary platform is marked pending and its mirror config re-synced. The finding is removed; the next check records it again if the object is still missing. Uploaded artifacts cannot be repaired this way and must be re-published.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Finding ID"
+// @Success      202  {object}  admin.MessageResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Finding not found"
+// @Failure      422  {object}  map[string]interface{}  "Artifact was uploaded, not mirrored"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      503  {object}  map[string]interface{}  "Mirror sync not configured"
+// @Router       /api/v1/admin/consistency/findings/{id}/repair [post]
+// RepairFinding re-syncs a mirrored artifact whose object is missing.
+// POST /api/v1/admin/consistency/findings/:id/repair
+func (h *StorageConsistencyHandlers) RepairFinding(c *gin.Context) {
+	ctx := c.Request.Context()
+	if _, err := uuid.Parse(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Finding not found"})
+		return
+	}
+	f, err := h.repo.GetFinding(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get finding"})
+		return
+	}
+	if f == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Finding not found"})
+		return
+	}
+	if f.MirrorConfigID == nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Artifact was uploaded, not mirrored; re-publish it to repair it"})
+		return
+	}
+	mirrorID, err := uuid.Parse(*f.MirrorConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Finding has an invalid mirror configuration ID"})
+		return
+	}
+
+	var resync func(context.Context) error
+	switch f.ArtifactType {
+	case models.ArtifactTypeProviderPlatform:
+		if h.providerRepo == nil || h.mirrorSync == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Provider mirror sync not configured"})
+			return
+		}
+		if err := h.providerRepo.DeletePlatform(ctx, f.ArtifactID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove provider platform"})
+			return
+		}
+		resync = func(ctx context.Context) error { return h.mirrorSync.TriggerManualSync(ctx, mirrorID) }
+	case models.ArtifactTypeTerraformVersionPlatform:
+		if h.tfMirrorRepo == nil || h.tfMirrorSync == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Terraform mirror sync not configured"})
+			return
+		}
+		platformID, err := uuid.Parse(f.ArtifactID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Finding has an invalid platform ID"})
+			return
+		}
+		if err := h.tfMirrorRepo.ResetPlatformSync(ctx, platformID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset platform sync status"})
+			return
+		}
+		resync = func(ctx context.Context) error { return h.tfMirrorSync.TriggerSync(ctx, mirrorID) }
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Artifact was uploaded, not mirrored; re-publish it to repair it"})
+		return
+	}
+
+	if err := h.repo.DeleteFinding(ctx, f.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove finding"})
+		return
+	}
+	// A sync already running or queued picks the artifact up on its next pass,
+	// so a refused trigger is logged rather than failed.
+	if err := resync(ctx); err != nil {
+		slog.Warn("storage consistency: repair sync not started; the next scheduled sync repairs the artifact",
+			"artifact_type", f.ArtifactType, "artifact", f.Description, "mirror_config_id", mirrorID, "error", err)
+	}
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "Repair queued: " + f.Description + " will be downloaded again by its mirror"})
+}
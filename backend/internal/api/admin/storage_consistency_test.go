@@ -0,0 +1,184 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var consistencyFindingCols = []string{"id", "artifact_type", "artifact_id", "storage_key", "severity", "description",
+	"mirror_config_id", "first_detected_at", "last_detected_at", "broken_at"}
+
+// consistencyReportCols are the columns of ListFindings, which adds each
+// platform's upstream provenance.
+var consistencyReportCols = append(append([]string{}, consistencyFindingCols...),
+	"provenance", "upstream_registry", "upstream_download_url", "upstream_shasum_url", "fetched_at")
+
+type fakeConsistencyChecker struct{ enabled bool }
+
+func (f fakeConsistencyChecker) TriggerCheck() bool { return f.enabled }
+
+func newConsistencyRouter(t *testing.T, cfg *config.StorageConsistencyConfig, tfSync TerraformMirrorSyncJobInterface) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewStorageConsistencyHandlers(cfg, repositories.NewStorageConsistencyRepository(db))
+	h.SetChecker(fakeConsistencyChecker{enabled: cfg.Enabled})
+	h.SetProviderRepair(repositories.NewProviderRepository(db), &mockSyncJob{})
+	h.SetTerraformRepair(repositories.NewTerraformMirrorRepository(sqlx.NewDb(db, "sqlmock")), tfSync)
+	r := gin.New()
+	r.GET("/admin/consistency/report", h.GetReport)
+	r.POST("/admin/consistency/run", h.RunCheck)
+	r.POST("/admin/consistency/findings/:id/broken", h.MarkBroken)
+	r.POST("/admin/consistency/findings/:id/repair", h.RepairFinding)
+	return mock, r
+}
+
+func doConsistencyReq(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestStorageConsistency_GetReport(t *testing.T) {
+	mock, r := newConsistencyRouter(t, &config.StorageConsistencyConfig{Enabled: true, MarkBroken: true}, &mockTMSyncJob{})
+	now := time.Now()
+	mock.ExpectQuery("FROM storage_consistency_runs").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "full_scan", "started_at", "completed_at", "checked", "missing", "errors", "error"}).
+			AddRow("run-1", false, now, now, 500, 2, 0, nil))
+	mock.ExpectQuery("FROM storage_consistency_findings").
+		WillReturnRows(sqlmock.NewRows(consistencyReportCols).
+			AddRow(uuid.NewString(), models.ArtifactTypeModuleVersion, uuid.NewString(), "modules/a.tar.gz",
+				models.ConsistencySeverityCritical, "acme/vpc/aws 1.0.0", nil, now, now, now, nil, nil, nil, nil, nil).
+			AddRow(uuid.NewString(), models.ArtifactTypeProviderPlatform, uuid.NewString(), "blobs/sha256/aa",
+				models.ConsistencySeverityWarning, "hashicorp/aws 5.0.0 linux_amd64", uuid.NewString(), now, now, nil,
+				models.ProvenanceRecorded, "registry.terraform.io",
+				"https://releases.hashicorp.com/terraform-provider-aws/5.0.0/terraform-provider-aws_5.0.0_linux_amd64.zip",
+				"https://releases.hashicorp.com/terraform-provider-aws/5.0.0/terraform-provider-aws_5.0.0_SHA256SUMS", now))
+
+	w := doConsistencyReq(r, http.MethodGet, "/admin/consistency/report")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var got StorageConsistencyReportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !got.Enabled || got.LatestRun == nil || got.LatestRun.Missing != 2 || len(got.Findings) != 2 {
+		t.Errorf("report = %+v", got)
+	}
+	if got.Counts != (StorageConsistencyCounts{Critical: 1, Warning: 1, Broken: 1}) {
+		t.Errorf("counts = %+v, want 1 critical, 1 warning, 1 broken", got.Counts)
+	}
+	if got.Findings[0].Provenance != nil {
+		t.Errorf("module finding provenance = %v, want none", *got.Findings[0].Provenance)
+	}
+	if r := got.Findings[1].UpstreamRegistry; r == nil || *r != "registry.terraform.io" {
+		t.Errorf("provider finding upstream registry = %v, want registry.terraform.io", r)
+	}
+}
+
+func TestStorageConsistency_RunCheck(t *testing.T) {
+	_, r := newConsistencyRouter(t, &config.StorageConsistencyConfig{Enabled: true}, &mockTMSyncJob{})
+	if w := doConsistencyReq(r, http.MethodPost, "/admin/consistency/run"); w.Code != http.StatusAccepted {
+		t.Errorf("enabled: status = %d, want 202", w.Code)
+	}
+
+	_, r = newConsistencyRouter(t, &config.StorageConsistencyConfig{}, &mockTMSyncJob{})
+	if w := doConsistencyReq(r, http.MethodPost, "/admin/consistency/run"); w.Code != http.StatusConflict {
+		t.Errorf("disabled: status = %d, want 409", w.Code)
+	}
+}
+
+func TestStorageConsistency_MarkBrokenNotFound(t *testing.T) {
+	mock, r := newConsistencyRouter(t, &config.StorageConsistencyConfig{Enabled: true}, &mockTMSyncJob{})
+	if w := doConsistencyReq(r, http.MethodPost, "/admin/consistency/findings/not-a-uuid/broken"); w.Code != http.StatusNotFound {
+		t.Errorf("invalid ID: status = %d, want 404", w.Code)
+	}
+
+	id := uuid.NewString()
+	mock.ExpectQuery("UPDATE storage_consistency_findings SET broken_at").
+		WithArgs(id, true).
+		WillReturnRows(sqlmock.NewRows(consistencyFindingCols))
+	if w := doConsistencyReq(r, http.MethodPost, "/admin/consistency/findings/"+id+"/broken"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown finding: status = %d, want 404", w.Code)
+	}
+}
+
+func TestStorageConsistency_RepairFinding(t *testing.T) {
+	now := time.Now()
+
+	t.Run("uploaded artifact is not repairable", func(t *testing.T) {
+		mock, r := newConsistencyRouter(t, &config.StorageConsistencyConfig{Enabled: true}, &mockTMSyncJob{})
+		id := uuid.NewString()
+		mock.ExpectQuery("FROM storage_consistency_findings").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(consistencyFindingCols).AddRow(id, models.ArtifactTypeModuleVersion, uuid.NewString(),
+				"modules/a.tar.gz", models.ConsistencySeverityCritical, "acme/vpc/aws 1.0.0", nil, now, now, nil))
+
+		if w := doConsistencyReq(r, http.MethodPost, "/admin/consistency/findings/"+id+"/repair"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want 422", w.Code)
+		}
+	})
+
+	t.Run("terraform platform is reset and re-synced", func(t *testing.T) {
+		// A refused trigger still repairs the platform on the next sync.
+		mock, r := newConsistencyRouter(t, &config.StorageConsistencyConfig{Enabled: true}, &mockTMSyncJob{err: errors.New("sync queue is full")})
+		id, platformID, configID := uuid.NewString(), uuid.New(), uuid.NewString()
+		mock.ExpectQuery("FROM storage_consistency_findings").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(consistencyFindingCols).AddRow(id, models.ArtifactTypeTerraformVersionPlatform, platformID.String(),
+				"terraform/1.9.0/linux_amd64.zip", models.ConsistencySeverityWarning, "terraform 1.9.0 linux_amd64", configID, now, now, now))
+		mock.ExpectExec("UPDATE terraform_version_platforms\\s+SET sync_status = 'pending'").
+			WithArgs(platformID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM storage_consistency_findings").
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		w := doConsistencyReq(r, http.MethodPost, "/admin/consistency/findings/"+id+"/repair")
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("mirrored provider platform row is removed", func(t *testing.T) {
+		mock, r := newConsistencyRouter(t, &config.StorageConsistencyConfig{Enabled: true}, &mockTMSyncJob{})
+		id, platformID := uuid.NewString(), uuid.NewString()
+		mock.ExpectQuery("FROM storage_consistency_findings").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(consistencyFindingCols).AddRow(id, models.ArtifactTypeProviderPlatform, platformID,
+				"blobs/sha256/aa", models.ConsistencySeverityWarning, "hashicorp/aws 5.0.0 linux_amd64", uuid.NewString(), now, now, nil))
+		mock.ExpectExec("DELETE FROM provider_platforms").
+			WithArgs(platformID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM storage_consistency_findings").
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		if w := doConsistencyReq(r, http.MethodPost, "/admin/consistency/findings/"+id+"/repair"); w.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+	})
+}
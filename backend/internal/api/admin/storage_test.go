@@ -17,6 +17,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 
 	// Register storage backends so storage.NewStorage works in tests
+	_ "github.com/terraform-registry/terraform-registry/internal/storage/artifactory"
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/azure"
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/gcs"
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/local"
@@ -682,6 +683,48 @@ func TestTestStorageConfig_GCSServiceAccountMissingCreds(t *testing.T) {
 	}
 }
 
+func TestTestStorageConfig_ArtifactoryMissingBaseURL(t *testing.T) {
+	_, r := newStorageRouter(t)
+	body := `{"backend_type":"artifactory","artifactory_repository":"terraform-local"}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/storage/configs/test",
+		bytes.NewBufferString(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestTestStorageConfig_ArtifactoryReachable(t *testing.T) {
+	var gotAuth, gotPath string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotAuth, gotPath = req.Header.Get("Authorization"), req.URL.Path
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer upstream.Close()
+
+	_, r := newStorageRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/storage/configs/test",
+		jsonBody(map[string]interface{}{
+			"backend_type":             "artifactory",
+			"artifactory_base_url":     upstream.URL + "/artifactory",
+			"artifactory_repository":   "terraform-local",
+			"artifactory_access_token": "tok",
+		})))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["success"] != true {
+		t.Errorf("success = %v, want true: %s", resp["success"], w.Body.String())
+	}
+	if gotAuth != "Bearer tok" || gotPath != "/artifactory/terraform-local/.connectivity-test" {
+		t.Errorf("probe sent Authorization=%q path=%q", gotAuth, gotPath)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // CreateStorageConfig — additional backend types
 // ---------------------------------------------------------------------------
@@ -753,6 +796,39 @@ func TestStorageCreateConfig_AzureSuccess(t *testing.T) {
 	}
 }
 
+func TestStorageCreateConfig_ArtifactorySuccess(t *testing.T) {
+	cipher, err := crypto.NewTokenCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("NewTokenCipher: %v", err)
+	}
+	mock, r := newStorageRouterWithCipher(t, cipher)
+	mock.ExpectQuery("SELECT storage_configured FROM system_settings").
+		WillReturnRows(sqlmock.NewRows([]string{"storage_configured"}))
+	mock.ExpectExec("INSERT INTO storage_config").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/storage/configs",
+		jsonBody(map[string]interface{}{
+			"backend_type":             "artifactory",
+			"artifactory_base_url":     "https://example.jfrog.io/artifactory",
+			"artifactory_repository":   "terraform-local",
+			"artifactory_access_token": "secret",
+		})))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["artifactory_access_token_set"] != true {
+		t.Errorf("artifactory_access_token_set = %v, want true", resp["artifactory_access_token_set"])
+	}
+	if _, leaked := resp["artifactory_access_token"]; leaked {
+		t.Error("response must not include the access token")
+	}
+}
+
 func TestStorageCreateConfig_AlreadyConfiguredDeactivates(t *testing.T) {
 	mock, r := newStorageRouter(t)
 	// IsStorageConfigured returns true
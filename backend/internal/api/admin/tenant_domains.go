@@ -0,0 +1,254 @@
+// tenant_domains.go implements admin CRUD for custom tenant domains: hostnames
+// that serve one organization's view of the registry, with optional branding
+// over the global UI theme. Writes take effect without a restart.
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/uitheme"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// TenantDomainHandlers serves the tenant domain admin endpoints.
+type TenantDomainHandlers struct {
+	server  *config.ServerConfig
+	orgRepo *repositories.OrganizationRepository
+	repo    *repositories.TenantDomainRepository
+	domains *middleware.TenantDomains
+}
+
+// NewTenantDomainHandlers constructs a TenantDomainHandlers. identityDB backs
+// organization lookups; domains is the cache consulted by TenantMiddleware,
+// invalidated on every write, and may be nil.
+func NewTenantDomainHandlers(server *config.ServerConfig, identityDB *sql.DB, repo *repositories.TenantDomainRepository, domains *middleware.TenantDomains) *TenantDomainHandlers {
+	return &TenantDomainHandlers{
+		server:  server,
+		orgRepo: repositories.NewOrganizationRepository(identityDB),
+		repo:    repo,
+		domains: domains,
+	}
+}
+
+// TenantDomainRequest is the body of POST and PUT /admin/tenant-domains.
+type TenantDomainRequest struct {
+	// Hostname is set on create only, e.g. "modules.acme-blue.example.com".
+	Hostname       string `json:"hostname"`
+	OrganizationID string `json:"organization_id" binding:"required"`
+	// Branding laid over the global UI theme on this hostname; empty keeps
+	// the global value.
+	ProductName  string `json:"product_name"`
+	PrimaryColor string `json:"primary_color"` // e.g. "#0055FF"
+	LogoURL      string `json:"logo_url"`
+	FaviconURL   string `json:"favicon_url"`
+}
+
+// @Summary      List tenant domains
+// @Description  Lists the custom hostnames that serve one organization's view of the registry.
+// @Tags         Tenant Domains
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "{\"domains\": []TenantDomain}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/tenant-domains [get]
+// ListDomains lists the tenant domains.
+// GET /api/v1/admin/tenant-domains
+func (h *TenantDomainHandlers) ListDomains(c *gin.Context) {
+	domains, err := h.repo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tenant domains"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"domains": domains})
+}
+
+// @Summary      Create tenant domain
+// @Description  Serves the registry for `organization_id` on `hostname`. Requests on the hostname get service discovery, download, webhook and OAuth callback URLs on that hostname, search results limited to the organization's claimed namespaces, and the domain's branding over the global UI theme. The hostname must already resolve to this deployment.
+// @Tags         Tenant Domains
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  TenantDomainRequest  true  "Tenant domain"
+// @Success      201  {object}  map[string]interface{}  "{\"domain\": TenantDomain}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      409  {object}  map[string]interface{}  "Hostname already configured"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/tenant-domains [post]
+// CreateDomain creates a tenant domain.
+// POST /api/v1/admin/tenant-domains
+func (h *TenantDomainHandlers) CreateDomain(c *gin.Context) {
+	var req TenantDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	hostname := strings.ToLower(strings.TrimSpace(req.Hostname))
+	if hostname == "" || strings.ContainsAny(hostname, "/:*? ") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hostname: must be a bare hostname such as modules.example.com"})
+		return
+	}
+	for _, own := range []string{h.server.GetPublicURL(), h.server.BaseURL} {
+		if hostname == models.UpstreamHostname(own) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hostname is the registry's own hostname"})
+			return
+		}
+	}
+	domain, ok := h.bindDomain(c, &req)
+	if !ok {
+		return
+	}
+	domain.Hostname = hostname
+
+	existing, err := h.repo.GetByHostname(c.Request.Context(), hostname)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tenant domain"})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "This hostname is already a tenant domain"})
+		return
+	}
+
+	if uid := c.GetString("user_id"); uid != "" {
+		domain.CreatedBy = &uid
+	}
+	if err := h.repo.Create(c.Request.Context(), domain); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tenant domain"})
+		return
+	}
+	h.invalidate()
+	c.JSON(http.StatusCreated, gin.H{"domain": domain})
+}
+
+// @Summary      Update tenant domain
+// @Description  Replaces the domain's organization and branding. The hostname cannot be changed; delete the domain and create a new one instead.
+// @Tags         Tenant Domains
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string               true  "Tenant domain ID"
+// @Param        body  body  TenantDomainRequest  true  "Tenant domain"
+// @Success      200  {object}  map[string]interface{}  "{\"domain\": TenantDomain}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Tenant domain not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/tenant-domains/{id} [put]
+// UpdateDomain replaces a tenant domain's organization and branding.
+// PUT /api/v1/admin/tenant-domains/:id
+func (h *TenantDomainHandlers) UpdateDomain(c *gin.Context) {
+	current, err := h.repo.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tenant domain"})
+		return
+	}
+	if current == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tenant domain not found"})
+		return
+	}
+
+	var req TenantDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if req.Hostname != "" && strings.ToLower(strings.TrimSpace(req.Hostname)) != current.Hostname {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "hostname cannot be changed"})
+		return
+	}
+	domain, ok := h.bindDomain(c, &req)
+	if !ok {
+		return
+	}
+	domain.ID, domain.Hostname = current.ID, current.Hostname
+	domain.CreatedBy, domain.CreatedAt = current.CreatedBy, current.CreatedAt
+
+	found, err := h.repo.Update(c.Request.Context(), domain)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tenant domain"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tenant domain not found"})
+		return
+	}
+	h.invalidate()
+	c.JSON(http.StatusOK, gin.H{"domain": domain})
+}
+
+// @Summary      Delete tenant domain
+// @Description  Stops serving the organization's view on the hostname. Requests on it fall back to the single-tenant behavior.
+// @Tags         Tenant Domains
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Tenant domain ID"
+// @Success      200  {object}  admin.MessageResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "Tenant domain not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/tenant-domains/{id} [delete]
+// DeleteDomain deletes a tenant domain.
+// DELETE /api/v1/admin/tenant-domains/:id
+func (h *TenantDomainHandlers) DeleteDomain(c *gin.Context) {
+	deleted, err := h.repo.Delete(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tenant domain"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tenant domain not found"})
+		return
+	}
+	h.invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Tenant domain deleted"})
+}
+
+// bindDomain validates the organization and branding of a request, writing a
+// 4xx/5xx response and returning false when they are unusable.
+func (h *TenantDomainHandlers) bindDomain(c *gin.Context, req *TenantDomainRequest) (*models.TenantDomain, bool) {
+	domain := &models.TenantDomain{
+		ProductName:  optionalString(strings.TrimSpace(req.ProductName)),
+		PrimaryColor: optionalString(strings.TrimSpace(req.PrimaryColor)),
+		LogoURL:      optionalString(strings.TrimSpace(req.LogoURL)),
+		FaviconURL:   optionalString(strings.TrimSpace(req.FaviconURL)),
+	}
+	if err := uitheme.ValidateTheme(&models.UIThemeConfig{
+		ProductName:  domain.ProductName,
+		PrimaryColor: domain.PrimaryColor,
+		LogoURL:      domain.LogoURL,
+		FaviconURL:   domain.FaviconURL,
+	}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	org, err := h.orgRepo.GetByID(c.Request.Context(), req.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+		return nil, false
+	}
+	if org == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Organization not found"})
+		return nil, false
+	}
+	domain.OrganizationID = org.ID
+	return domain, true
+}
+
+func (h *TenantDomainHandlers) invalidate() {
+	if h.domains != nil {
+		h.domains.Invalidate()
+	}
+}
@@ -0,0 +1,134 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+var tenantDomainCols = []string{
+	"id", "hostname", "organization_id", "product_name", "primary_color", "logo_url", "favicon_url",
+	"created_by", "created_at", "updated_at",
+}
+
+func newTenantDomainRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine, *middleware.TenantDomains) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := repositories.NewTenantDomainRepository(db)
+	domains := middleware.NewTenantDomains(repo, time.Hour)
+	server := &config.ServerConfig{BaseURL: "http://localhost:8080", PublicURL: "https://registry.example.com"}
+	h := NewTenantDomainHandlers(server, db, repo, domains)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "admin-1") })
+	r.GET("/tenant-domains", h.ListDomains)
+	r.POST("/tenant-domains", h.CreateDomain)
+	r.PUT("/tenant-domains/:id", h.UpdateDomain)
+	r.DELETE("/tenant-domains/:id", h.DeleteDomain)
+	return mock, r, domains
+}
+
+func TestCreateTenantDomain_InvalidatesCache(t *testing.T) {
+	mock, r, domains := newTenantDomainRouter(t)
+	ctx := context.Background()
+
+	// Prime the cache with no domains.
+	mock.ExpectQuery("SELECT.*FROM tenant_domains ORDER BY hostname").WillReturnRows(sqlmock.NewRows(tenantDomainCols))
+	if d, _ := domains.Resolve(ctx, "modules.acme-blue.example.com"); d != nil {
+		t.Fatalf("domain = %+v before any were created", d)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols).AddRow("org-blue", "acme-blue", "Acme Blue", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM tenant_domains WHERE hostname").
+		WithArgs("modules.acme-blue.example.com").
+		WillReturnRows(sqlmock.NewRows(tenantDomainCols))
+	mock.ExpectQuery("INSERT INTO tenant_domains").
+		WithArgs("modules.acme-blue.example.com", "org-blue", "Acme Blue Modules", "#0055ff", nil, nil, "admin-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("d-1", time.Now(), time.Now()))
+	w := doMirrorAllowlistReq(r, http.MethodPost, "/tenant-domains",
+		`{"hostname":"Modules.Acme-Blue.example.com","organization_id":"org-blue","product_name":"Acme Blue Modules","primary_color":"#0055ff"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+
+	// The write invalidated the cache, so the next lookup reloads.
+	mock.ExpectQuery("SELECT.*FROM tenant_domains ORDER BY hostname").
+		WillReturnRows(sqlmock.NewRows(tenantDomainCols).
+			AddRow("d-1", "modules.acme-blue.example.com", "org-blue", nil, nil, nil, nil, "admin-1", time.Now(), time.Now()))
+	if d, _ := domains.Resolve(ctx, "modules.acme-blue.example.com"); d == nil || d.OrganizationID != "org-blue" {
+		t.Errorf("domain = %+v after create, want org-blue", d)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestCreateTenantDomain_Rejects(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body string
+		want int
+	}{
+		"hostname with a port":      {`{"hostname":"modules.example.com:8443","organization_id":"org-1"}`, http.StatusBadRequest},
+		"registry's own hostname":   {`{"hostname":"registry.example.com","organization_id":"org-1"}`, http.StatusBadRequest},
+		"missing organization":      {`{"hostname":"modules.example.com"}`, http.StatusBadRequest},
+		"bad color":                 {`{"hostname":"modules.example.com","organization_id":"org-1","primary_color":"blue"}`, http.StatusBadRequest},
+		"javascript logo":           {`{"hostname":"modules.example.com","organization_id":"org-1","logo_url":"javascript:alert(1)"}`, http.StatusBadRequest},
+		"protocol-relative favicon": {`{"hostname":"modules.example.com","organization_id":"org-1","favicon_url":"//evil.example/x.ico"}`, http.StatusBadRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, r, _ := newTenantDomainRouter(t)
+			if w := doMirrorAllowlistReq(r, http.MethodPost, "/tenant-domains", tc.body); w.Code != tc.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateTenantDomain_Conflict(t *testing.T) {
+	mock, r, _ := newTenantDomainRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE id").
+		WillReturnRows(sqlmock.NewRows(orgCols).AddRow("org-red", "acme-red", "Acme Red", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM tenant_domains WHERE hostname").
+		WillReturnRows(sqlmock.NewRows(tenantDomainCols).
+			AddRow("d-1", "modules.acme-red.example.com", "org-blue", nil, nil, nil, nil, nil, time.Now(), time.Now()))
+
+	w := doMirrorAllowlistReq(r, http.MethodPost, "/tenant-domains", `{"hostname":"modules.acme-red.example.com","organization_id":"org-red"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateTenantDomain_HostnameIsImmutable(t *testing.T) {
+	mock, r, _ := newTenantDomainRouter(t)
+	mock.ExpectQuery("SELECT.*FROM tenant_domains WHERE id").
+		WithArgs("d-1").
+		WillReturnRows(sqlmock.NewRows(tenantDomainCols).
+			AddRow("d-1", "modules.acme-red.example.com", "org-red", nil, nil, nil, nil, nil, time.Now(), time.Now()))
+
+	w := doMirrorAllowlistReq(r, http.MethodPut, "/tenant-domains/d-1", `{"hostname":"modules.acme-blue.example.com","organization_id":"org-red"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDeleteTenantDomain_NotFound(t *testing.T) {
+	mock, r, _ := newTenantDomainRouter(t)
+	mock.ExpectExec("DELETE FROM tenant_domains").
+		WithArgs("d-9").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if w := doMirrorAllowlistReq(r, http.MethodDelete, "/tenant-domains/d-9", ""); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
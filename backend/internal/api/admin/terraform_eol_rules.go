@@ -0,0 +1,206 @@
+// terraform_eol_rules.go implements admin HTTP handlers for the Terraform
+// binary mirror's end-of-life rules. Upstream release indexes carry no
+// support status, so operators list the EOL version series per tool here; the
+// EOL refresh job marks matching mirrored versions, which the public versions
+// endpoints then flag.
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// eolVersionPrefixPattern accepts a major, major.minor or full version.
+var eolVersionPrefixPattern = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+
+// eolRuleFromRequest validates req and copies it onto rule. It writes a 400
+// and returns false when the request is invalid.
+func eolRuleFromRequest(c *gin.Context, req *models.TerraformEOLRuleRequest, rule *models.TerraformEOLRule) bool {
+	if !eolVersionPrefixPattern.MatchString(req.VersionPrefix) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version_prefix must be a major, major.minor or major.minor.patch version, e.g. 1.3"})
+		return false
+	}
+	rule.EOLDate = nil
+	if req.EOLDate != nil && *req.EOLDate != "" {
+		d, err := time.Parse("2006-01-02", *req.EOLDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "eol_date must be a YYYY-MM-DD date"})
+			return false
+		}
+		rule.EOLDate = &d
+	}
+	rule.Tool = req.Tool
+	rule.VersionPrefix = req.VersionPrefix
+	rule.Message = req.Message
+	return true
+}
+
+// parseEOLRuleID parses the :ruleId path parameter, writing a 400 on failure.
+func parseEOLRuleID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("ruleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid EOL rule ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// ---- GET /api/v1/admin/terraform-mirrors/eol-rules -------------------------
+
+// @Summary      List Terraform EOL rules
+// @Description  Returns the end-of-life rules applied to mirrored Terraform/OpenTofu versions. Requires binaries:read scope (mirrors:read is also accepted).
+// @Tags         Terraform Mirror
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  models.TerraformEOLRuleListResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/terraform-mirrors/eol-rules [get]
+func (h *TerraformMirrorHandler) ListEOLRules(c *gin.Context) {
+	rules, err := h.repo.ListEOLRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list EOL rules: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, models.TerraformEOLRuleListResponse{Rules: rules, TotalCount: len(rules)})
+}
+
+// ---- POST /api/v1/admin/terraform-mirrors/eol-rules ------------------------
+
+// @Summary      Create Terraform EOL rule
+// @Description  Marks the versions of a tool whose version starts with version_prefix ("1.3" covers every 1.3.x release) as end-of-life from eol_date, or at once when eol_date is omitted. Matching versions are flagged is_eol with the rule's message in the public versions endpoints, and are never chosen as latest by mirrors that set exclude_eol_from_latest. Requires binaries:manage scope (mirrors:manage is also accepted).
+// @Tags         Terraform Mirror
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.TerraformEOLRuleRequest  true  "EOL rule"
+// @Success      201  {object}  models.TerraformEOLRule
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      409  {object}  map[string]interface{}  "A rule for this tool and version prefix already exists"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/terraform-mirrors/eol-rules [post]
+func (h *TerraformMirrorHandler) CreateEOLRule(c *gin.Context) {
+	var req models.TerraformEOLRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule := &models.TerraformEOLRule{}
+	if !eolRuleFromRequest(c, &req, rule) {
+		return
+	}
+
+	if err := h.repo.CreateEOLRule(c.Request.Context(), rule); err != nil {
+		if errors.Is(err, repositories.ErrTerraformEOLRuleExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create EOL rule: " + err.Error()})
+		return
+	}
+	h.refreshEOL(c.Request.Context())
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ---- PUT /api/v1/admin/terraform-mirrors/eol-rules/:ruleId -----------------
+
+// @Summary      Update Terraform EOL rule
+// @Description  Replaces an end-of-life rule. Requires binaries:manage scope (mirrors:manage is also accepted).
+// @Tags         Terraform Mirror
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        ruleId  path  string                          true  "EOL rule UUID"
+// @Param        body    body  models.TerraformEOLRuleRequest  true  "EOL rule"
+// @Success      200  {object}  models.TerraformEOLRule
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Not found"
+// @Failure      409  {object}  map[string]interface{}  "A rule for this tool and version prefix already exists"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/terraform-mirrors/eol-rules/{ruleId} [put]
+func (h *TerraformMirrorHandler) UpdateEOLRule(c *gin.Context) {
+	id, ok := parseEOLRuleID(c)
+	if !ok {
+		return
+	}
+	var req models.TerraformEOLRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.repo.GetEOLRule(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get EOL rule: " + err.Error()})
+		return
+	}
+	if rule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "EOL rule not found"})
+		return
+	}
+	if !eolRuleFromRequest(c, &req, rule) {
+		return
+	}
+
+	if err := h.repo.UpdateEOLRule(c.Request.Context(), rule); err != nil {
+		if errors.Is(err, repositories.ErrTerraformEOLRuleExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update EOL rule: " + err.Error()})
+		return
+	}
+	h.refreshEOL(c.Request.Context())
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// ---- DELETE /api/v1/admin/terraform-mirrors/eol-rules/:ruleId --------------
+
+// @Summary      Delete Terraform EOL rule
+// @Description  Deletes an end-of-life rule; the versions it marked are unmarked unless another rule covers them. Requires binaries:manage scope (mirrors:manage is also accepted).
+// @Tags         Terraform Mirror
+// @Security     Bearer
+// @Produce      json
+// @Param        ruleId  path  string  true  "EOL rule UUID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}  "Invalid rule ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/terraform-mirrors/eol-rules/{ruleId} [delete]
+func (h *TerraformMirrorHandler) DeleteEOLRule(c *gin.Context) {
+	id, ok := parseEOLRuleID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.repo.GetEOLRule(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get EOL rule: " + err.Error()})
+		return
+	}
+	if rule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "EOL rule not found"})
+		return
+	}
+
+	if err := h.repo.DeleteEOLRule(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete EOL rule: " + err.Error()})
+		return
+	}
+	h.refreshEOL(c.Request.Context())
+
+	c.JSON(http.StatusOK, gin.H{"message": "EOL rule deleted", "id": id})
+}
@@ -0,0 +1,190 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var eolRuleCols = []string{"id", "tool", "version_prefix", "eol_date", "message", "created_at", "updated_at"}
+
+type mockEOLRefresher struct{ calls int }
+
+func (m *mockEOLRefresher) Refresh(_ context.Context) error {
+	m.calls++
+	return nil
+}
+
+func newEOLRulesRouter(t *testing.T) (sqlmock.Sqlmock, *mockEOLRefresher, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewTerraformMirrorHandler(repositories.NewTerraformMirrorRepository(sqlx.NewDb(db, "sqlmock")))
+	refresher := &mockEOLRefresher{}
+	h.SetEOLRefresher(refresher)
+
+	r := gin.New()
+	r.GET("/eol-rules", h.ListEOLRules)
+	r.POST("/eol-rules", h.CreateEOLRule)
+	r.PUT("/eol-rules/:ruleId", h.UpdateEOLRule)
+	r.DELETE("/eol-rules/:ruleId", h.DeleteEOLRule)
+	return mock, refresher, r
+}
+
+func TestTMListEOLRules_Success(t *testing.T) {
+	mock, _, r := newEOLRulesRouter(t)
+	mock.ExpectQuery("SELECT.*FROM terraform_eol_rules").
+		WillReturnRows(sqlmock.NewRows(eolRuleCols).
+			AddRow(knownUUID, "terraform", "1.3", nil, nil, time.Now(), time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/eol-rules", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestTMCreateEOLRule_Success(t *testing.T) {
+	mock, refresher, r := newEOLRulesRouter(t)
+	mock.ExpectQuery("INSERT INTO terraform_eol_rules").
+		WithArgs(sqlmock.AnyArg(), "terraform", "1.3", sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/eol-rules", jsonBody(map[string]interface{}{
+		"tool": "terraform", "version_prefix": "1.3", "eol_date": "2024-01-01",
+	})))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+	if refresher.calls != 1 {
+		t.Errorf("refresh calls = %d, want 1", refresher.calls)
+	}
+}
+
+func TestTMCreateEOLRule_InvalidPrefix(t *testing.T) {
+	_, refresher, r := newEOLRulesRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/eol-rules", jsonBody(map[string]interface{}{
+		"tool": "terraform", "version_prefix": "1.x",
+	})))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+	if refresher.calls != 0 {
+		t.Errorf("refresh calls = %d, want 0", refresher.calls)
+	}
+}
+
+func TestTMCreateEOLRule_InvalidDate(t *testing.T) {
+	_, _, r := newEOLRulesRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/eol-rules", jsonBody(map[string]interface{}{
+		"tool": "terraform", "version_prefix": "1.3", "eol_date": "01/01/2024",
+	})))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestTMCreateEOLRule_Conflict(t *testing.T) {
+	mock, _, r := newEOLRulesRouter(t)
+	mock.ExpectQuery("INSERT INTO terraform_eol_rules").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "terraform_eol_rules_unique"})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/eol-rules", jsonBody(map[string]interface{}{
+		"tool": "terraform", "version_prefix": "1.3",
+	})))
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestTMUpdateEOLRule_Success(t *testing.T) {
+	mock, refresher, r := newEOLRulesRouter(t)
+	mock.ExpectQuery("SELECT.*FROM terraform_eol_rules").
+		WillReturnRows(sqlmock.NewRows(eolRuleCols).
+			AddRow(knownUUID, "terraform", "1.3", nil, nil, time.Now(), time.Now()))
+	mock.ExpectQuery("UPDATE terraform_eol_rules").
+		WithArgs(sqlmock.AnyArg(), "terraform", "1.4", nil, "Upgrade to 1.5").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/eol-rules/"+knownUUID, jsonBody(map[string]interface{}{
+		"tool": "terraform", "version_prefix": "1.4", "message": "Upgrade to 1.5",
+	})))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if refresher.calls != 1 {
+		t.Errorf("refresh calls = %d, want 1", refresher.calls)
+	}
+}
+
+func TestTMDeleteEOLRule_InvalidID(t *testing.T) {
+	_, _, r := newEOLRulesRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/eol-rules/not-a-uuid", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestTMDeleteEOLRule_NotFound(t *testing.T) {
+	mock, refresher, r := newEOLRulesRouter(t)
+	mock.ExpectQuery("SELECT.*FROM terraform_eol_rules").
+		WillReturnRows(sqlmock.NewRows(eolRuleCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/eol-rules/"+knownUUID, nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+	if refresher.calls != 0 {
+		t.Errorf("refresh calls = %d, want 0", refresher.calls)
+	}
+}
+
+func TestTMDeleteEOLRule_Success(t *testing.T) {
+	mock, refresher, r := newEOLRulesRouter(t)
+	mock.ExpectQuery("SELECT.*FROM terraform_eol_rules").
+		WillReturnRows(sqlmock.NewRows(eolRuleCols).
+			AddRow(knownUUID, "terraform", "1.3", nil, nil, time.Now(), time.Now()))
+	mock.ExpectExec("DELETE FROM terraform_eol_rules").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/eol-rules/"+knownUUID, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if refresher.calls != 1 {
+		t.Errorf("refresh calls = %d, want 1", refresher.calls)
+	}
+}
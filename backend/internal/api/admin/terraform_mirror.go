@@ -2,7 +2,8 @@
 // The multi-config design mirrors the provider mirror feature: full CRUD for configs,
 // per-config sync triggering, and per-config version/platform/history inspection.
 //
-// All endpoints are secured with mirrors:read / mirrors:manage scopes.
+// All endpoints are secured with binaries:read / binaries:manage scopes; the
+// mirrors:read / mirrors:manage scopes they were split out of are also accepted.
 package admin
 
 import (
@@ -12,6 +13,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
@@ -26,16 +28,38 @@ type TerraformMirrorSyncJobInterface interface {
 	TriggerSync(ctx context.Context, configID uuid.UUID) error
 }
 
+// TerraformEOLRefresher re-applies the EOL rules and re-resolves each mirror's
+// latest version. *jobs.TerraformEOLRefreshJob satisfies it.
+type TerraformEOLRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// StorageDeletionFailureRecorder records stored objects a delete could not
+// remove, for garbage collection to retry.
+type StorageDeletionFailureRecorder interface {
+	Record(ctx context.Context, f *models.StorageDeletionFailure) error
+}
+
+// Sources recorded with a failed artifact delete.
+const (
+	deletionSourceTerraformVersion = "terraform_mirror_version"
+	deletionSourceTerraformConfig  = "terraform_mirror_config"
+)
+
 // TerraformMirrorHandler handles admin endpoints for the Terraform binary mirror.
 type TerraformMirrorHandler struct {
 	repo           *repositories.TerraformMirrorRepository
 	syncJob        TerraformMirrorSyncJobInterface
 	storageBackend storage.Storage
+	failures       StorageDeletionFailureRecorder
 	// egress is consulted when validating upstream_url on create/update so a
 	// non-admin "devops"-scoped caller cannot point the binary mirror at a
 	// private or cloud-metadata address; nil enforces the strict default
 	// deny-list.
 	egress *httpsafe.Guard
+	// eolRefresher applies EOL rule and exclude_eol_from_latest changes at
+	// once; nil leaves them to the next scheduled refresh.
+	eolRefresher TerraformEOLRefresher
 }
 
 // NewTerraformMirrorHandler creates a new TerraformMirrorHandler.
@@ -55,40 +79,79 @@ func (h *TerraformMirrorHandler) SetEgressGuard(g *httpsafe.Guard) {
 	h.egress = g
 }
 
+// SetEOLRefresher attaches the EOL refresh job so EOL rule changes are applied
+// to versions immediately.
+func (h *TerraformMirrorHandler) SetEOLRefresher(r TerraformEOLRefresher) {
+	h.eolRefresher = r
+}
+
+// refreshEOL applies EOL changes now when a refresher is attached. A failure
+// is logged and left to the scheduled refresh; the change itself is saved.
+func (h *TerraformMirrorHandler) refreshEOL(ctx context.Context) {
+	if h.eolRefresher == nil {
+		return
+	}
+	if err := h.eolRefresher.Refresh(ctx); err != nil {
+		log.Printf("[terraform-mirror] failed to refresh EOL markings: %v", err)
+	}
+}
+
 // SetStorageBackend attaches the object-storage backend so deleting a version
 // also removes its stored binaries. When unset, deletes only touch the database.
 func (h *TerraformMirrorHandler) SetStorageBackend(s storage.Storage) {
 	h.storageBackend = s
 }
 
-// deleteVersionArtifacts best-effort removes a version's stored binaries from
-// object storage: every platform package plus the version's SHA256SUMS and
-// detached signature. Storage errors are intentionally swallowed — a dangling
-// metadata row is worse than an orphaned blob (which can be swept later), so a
-// storage failure must never block the database delete. No-op when no storage
-// backend is configured.
-func (h *TerraformMirrorHandler) deleteVersionArtifacts(ctx context.Context, v *models.TerraformVersion) {
-	if h.storageBackend == nil || v == nil {
-		return
-	}
-	if platforms, listErr := h.repo.ListPlatformsForVersion(ctx, v.ID); listErr == nil {
-		for _, p := range platforms {
-			if p.StorageKey != nil && *p.StorageKey != "" {
-				_ = h.storageBackend.Delete(ctx, *p.StorageKey)
+// SetDeletionFailureRecorder attaches the store that records stored binaries a
+// delete could not remove. When unset, such failures are only logged.
+func (h *TerraformMirrorHandler) SetDeletionFailureRecorder(r StorageDeletionFailureRecorder) {
+	h.failures = r
+}
+
+// deleteArtifacts removes stored binaries whose database rows have already
+// been deleted. An object that no longer exists counts as missing, not as a
+// failure. A failed delete never fails the request — the rows are gone — but
+// is recorded (source says which delete left it) for garbage collection.
+func (h *TerraformMirrorHandler) deleteArtifacts(ctx context.Context, artifacts []models.TerraformStoredArtifact, source string) *TerraformArtifactDeletionSummary {
+	summary := &TerraformArtifactDeletionSummary{}
+	for _, a := range artifacts {
+		var size int64
+		meta, err := h.storageBackend.GetMetadata(ctx, a.StorageKey)
+		if err != nil {
+			if exists, existsErr := h.storageBackend.Exists(ctx, a.StorageKey); existsErr == nil && !exists {
+				summary.Missing++
+				continue
 			}
+		} else if meta != nil {
+			size = meta.Size
 		}
-	}
-	for _, key := range []*string{v.SumsStorageKey, v.SigStorageKey} {
-		if key != nil && *key != "" {
-			_ = h.storageBackend.Delete(ctx, *key)
+
+		if err := h.storageBackend.Delete(ctx, a.StorageKey); err != nil {
+			summary.Failed++
+			log.Printf("terraform mirror: failed to delete stored artifact %s: %v", a.StorageKey, err)
+			if h.failures != nil {
+				f := &models.StorageDeletionFailure{
+					StorageKey:     a.StorageKey,
+					StorageBackend: a.StorageBackend,
+					Source:         source,
+					LastError:      err.Error(),
+				}
+				if recErr := h.failures.Record(ctx, f); recErr != nil {
+					log.Printf("terraform mirror: failed to record deletion failure for %s: %v", a.StorageKey, recErr)
+				}
+			}
+			continue
 		}
+		summary.Deleted++
+		summary.BytesFreed += size
 	}
+	return summary
 }
 
 // ---- POST /api/v1/admin/terraform-mirrors ----------------------------------
 
 // @Summary      Create Terraform mirror configuration
-// @Description  Creates a new named Terraform binary mirror configuration. Requires mirrors:manage scope.
+// @Description  Creates a new named Terraform binary mirror configuration. Requires binaries:manage scope (mirrors:manage is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Accept       json
@@ -162,6 +225,19 @@ func (h *TerraformMirrorHandler) CreateConfig(c *gin.Context) {
 		verifyGitHubAttestation = *req.VerifyGitHubAttestation
 	}
 
+	historyCount := models.DefaultSyncHistoryRetentionCount
+	if req.HistoryRetentionCount != nil {
+		historyCount = *req.HistoryRetentionCount
+	}
+	historyDays := models.DefaultSyncHistoryRetentionDays
+	if req.HistoryRetentionDays != nil {
+		historyDays = *req.HistoryRetentionDays
+	}
+	excludeEOL := false
+	if req.ExcludeEOLFromLatest != nil {
+		excludeEOL = *req.ExcludeEOLFromLatest
+	}
+
 	cfg := &models.TerraformMirrorConfig{
 		Name:                    req.Name,
 		Description:             req.Description,
@@ -176,6 +252,10 @@ func (h *TerraformMirrorHandler) CreateConfig(c *gin.Context) {
 		RequiresApproval:        requiresApproval,
 		AutoApproveRules:        req.AutoApproveRules,
 		VerifyGitHubAttestation: verifyGitHubAttestation,
+		HistoryRetentionCount:   historyCount,
+		HistoryRetentionDays:    historyDays,
+		ExcludeEOLFromLatest:    excludeEOL,
+		MaxStorageBytes:         storageLimit(req.MaxStorageBytes),
 	}
 
 	if createErr := h.repo.Create(c.Request.Context(), cfg); createErr != nil {
@@ -189,7 +269,7 @@ func (h *TerraformMirrorHandler) CreateConfig(c *gin.Context) {
 // ---- GET /api/v1/admin/terraform-mirrors -----------------------------------
 
 // @Summary      List Terraform mirror configurations
-// @Description  Returns all Terraform binary mirror configurations. Requires mirrors:read scope.
+// @Description  Returns all Terraform binary mirror configurations. Requires binaries:read scope (mirrors:read is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
@@ -213,7 +293,7 @@ func (h *TerraformMirrorHandler) ListConfigs(c *gin.Context) {
 // ---- GET /api/v1/admin/terraform-mirrors/:id --------------------------------
 
 // @Summary      Get Terraform mirror configuration
-// @Description  Returns a specific Terraform binary mirror configuration. Requires mirrors:read scope.
+// @Description  Returns a specific Terraform binary mirror configuration. Requires binaries:read scope (mirrors:read is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
@@ -245,7 +325,7 @@ func (h *TerraformMirrorHandler) GetConfig(c *gin.Context) {
 // ---- GET /api/v1/admin/terraform-mirrors/:id/status ------------------------
 
 // @Summary      Get Terraform mirror status
-// @Description  Returns the status and summary stats for a specific mirror config. Requires mirrors:read scope.
+// @Description  Returns the status and summary stats for a specific mirror config, including eol_count, the number of its versions marked end-of-life by the EOL rules, and storage_usage, the bytes of its stored binaries against max_storage_bytes. Requires binaries:read scope (mirrors:read is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
@@ -282,19 +362,39 @@ func (h *TerraformMirrorHandler) GetStatus(c *gin.Context) {
 		latestStr = &latest.Version
 	}
 
+	syncStats, syncStatsErr := h.repo.GetSyncHistoryStats(c.Request.Context(), id)
+	if syncStatsErr != nil {
+		log.Printf("[terraform-mirror] failed to aggregate sync history for %s: %v", id, syncStatsErr)
+	}
+
+	eolCount, eolErr := h.repo.CountEOLVersions(c.Request.Context(), id)
+	if eolErr != nil {
+		log.Printf("[terraform-mirror] failed to count EOL versions for %s: %v", id, eolErr)
+	}
+
+	var storageUsage *models.MirrorStorageUsage
+	if used, usageErr := h.repo.GetStorageUsage(c.Request.Context(), id); usageErr != nil {
+		log.Printf("[terraform-mirror] failed to read storage usage for %s: %v", id, usageErr)
+	} else {
+		storageUsage = models.NewMirrorStorageUsage(used, cfg.MaxStorageBytes)
+	}
+
 	c.JSON(http.StatusOK, models.TerraformMirrorStatusResponse{
 		Config:        cfg,
 		VersionCount:  versionCount,
 		PlatformCount: platformCount,
 		PendingCount:  pendingCount,
+		EOLCount:      eolCount,
 		LatestVersion: latestStr,
+		SyncStats:     syncStats,
+		StorageUsage:  storageUsage,
 	})
 }
 
 // ---- PUT /api/v1/admin/terraform-mirrors/:id --------------------------------
 
 // @Summary      Update Terraform mirror configuration
-// @Description  Updates a Terraform binary mirror configuration. Requires mirrors:manage scope.
+// @Description  Updates a Terraform binary mirror configuration. Requires binaries:manage scope (mirrors:manage is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Accept       json
@@ -389,11 +489,27 @@ func (h *TerraformMirrorHandler) UpdateConfig(c *gin.Context) {
 	if req.VerifyGitHubAttestation != nil {
 		cfg.VerifyGitHubAttestation = *req.VerifyGitHubAttestation
 	}
+	if req.HistoryRetentionCount != nil {
+		cfg.HistoryRetentionCount = *req.HistoryRetentionCount
+	}
+	if req.HistoryRetentionDays != nil {
+		cfg.HistoryRetentionDays = *req.HistoryRetentionDays
+	}
+	if req.MaxStorageBytes != nil {
+		cfg.MaxStorageBytes = storageLimit(req.MaxStorageBytes)
+	}
+	eolChanged := req.ExcludeEOLFromLatest != nil && *req.ExcludeEOLFromLatest != cfg.ExcludeEOLFromLatest
+	if req.ExcludeEOLFromLatest != nil {
+		cfg.ExcludeEOLFromLatest = *req.ExcludeEOLFromLatest
+	}
 
 	if updateErr := h.repo.Update(c.Request.Context(), cfg); updateErr != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update config: " + updateErr.Error()})
 		return
 	}
+	if eolChanged {
+		h.refreshEOL(c.Request.Context())
+	}
 
 	c.JSON(http.StatusOK, cfg)
 }
@@ -401,11 +517,12 @@ func (h *TerraformMirrorHandler) UpdateConfig(c *gin.Context) {
 // ---- DELETE /api/v1/admin/terraform-mirrors/:id -----------------------------
 
 // @Summary      Delete Terraform mirror configuration
-// @Description  Deletes a Terraform binary mirror config, all its associated versions/history, and the stored binaries for every version (each platform package plus per-version SHA256SUMS and detached signatures) from object storage. Requires mirrors:manage scope.
+// @Description  Deletes a Terraform binary mirror config, all its associated versions/history, and the stored binaries for every version (each platform package plus per-version SHA256SUMS and detached signatures) from object storage. Missing objects are tolerated; objects that fail to delete are recorded for garbage collection. Pass keep_artifacts=true to leave the stored binaries in place. Requires binaries:manage scope (mirrors:manage is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
-// @Param        id  path  string  true  "Mirror config UUID"
+// @Param        id              path   string  true   "Mirror config UUID"
+// @Param        keep_artifacts  query  bool    false  "Leave the stored binaries in storage"
 // @Success      200  {object}  admin.DeleteTerraformMirrorResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      404  {object}  map[string]interface{}  "Not found"
@@ -427,15 +544,16 @@ func (h *TerraformMirrorHandler) DeleteConfig(c *gin.Context) {
 		return
 	}
 
-	// Remove every version's stored binaries before deleting the config. The DB
-	// delete cascades the version/platform rows, but object-storage blobs are not
-	// FK-managed, so without this they would be orphaned. Best-effort.
-	if h.storageBackend != nil {
-		ctx := c.Request.Context()
-		if versions, listErr := h.repo.ListVersions(ctx, id, false); listErr == nil {
-			for i := range versions {
-				h.deleteVersionArtifacts(ctx, &versions[i])
-			}
+	// The DB delete cascades the version/platform rows, but object-storage
+	// blobs are not FK-managed, so enumerate them first and remove them once
+	// the rows are gone.
+	keep := c.Query("keep_artifacts") == "true"
+	var artifacts []models.TerraformStoredArtifact
+	if h.storageBackend != nil && !keep {
+		artifacts, err = h.repo.ListConfigArtifacts(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list stored artifacts: " + err.Error()})
+			return
 		}
 	}
 
@@ -444,13 +562,27 @@ func (h *TerraformMirrorHandler) DeleteConfig(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Mirror config deleted", "id": id, "name": cfg.Name})
+	resp := gin.H{"message": "Mirror config deleted", "id": id, "name": cfg.Name}
+	h.addArtifactResult(c, resp, keep, artifacts, deletionSourceTerraformConfig)
+	c.JSON(http.StatusOK, resp)
+}
+
+// addArtifactResult deletes the artifacts of a removed config or version and
+// adds the outcome to resp: artifacts_kept when keep_artifacts was passed,
+// otherwise an artifacts summary (absent when no storage backend is attached).
+func (h *TerraformMirrorHandler) addArtifactResult(c *gin.Context, resp gin.H, keep bool, artifacts []models.TerraformStoredArtifact, source string) {
+	switch {
+	case keep:
+		resp["artifacts_kept"] = true
+	case h.storageBackend != nil:
+		resp["artifacts"] = h.deleteArtifacts(c.Request.Context(), artifacts, source)
+	}
 }
 
 // ---- POST /api/v1/admin/terraform-mirrors/:id/sync -------------------------
 
 // @Summary      Trigger Terraform mirror sync
-// @Description  Enqueues a manual sync for the specified mirror config. Requires mirrors:manage scope.
+// @Description  Enqueues a manual sync for the specified mirror config. Requires binaries:manage scope (mirrors:manage is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
@@ -497,7 +629,7 @@ func (h *TerraformMirrorHandler) TriggerSync(c *gin.Context) {
 // ---- GET /api/v1/admin/terraform-mirrors/:id/versions ----------------------
 
 // @Summary      List mirrored Terraform versions
-// @Description  Returns all Terraform versions known to the specified mirror config. Requires mirrors:read scope.
+// @Description  Returns all Terraform versions known to the specified mirror config. Requires binaries:read scope (mirrors:read is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
@@ -564,7 +696,7 @@ func (h *TerraformMirrorHandler) ListVersions(c *gin.Context) {
 // ---- GET /api/v1/admin/terraform-mirrors/:id/versions/:version -------------
 
 // @Summary      Get a specific mirrored Terraform version
-// @Description  Returns metadata and per-platform sync status for a single version. Requires mirrors:read scope.
+// @Description  Returns metadata and per-platform sync status for a single version. Requires binaries:read scope (mirrors:read is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
@@ -606,12 +738,13 @@ func (h *TerraformMirrorHandler) GetVersion(c *gin.Context) {
 // ---- DELETE /api/v1/admin/terraform-mirrors/:id/versions/:version ----------
 
 // @Summary      Delete a mirrored Terraform version
-// @Description  Removes a version, its platform records, and the stored binaries (each platform package plus the version's SHA256SUMS and detached signature) from object storage. Requires mirrors:manage scope.
+// @Description  Removes a version, its platform records, and the stored binaries (each platform package plus the version's SHA256SUMS and detached signature) from object storage. Missing objects are tolerated; objects that fail to delete are recorded for garbage collection. Pass keep_artifacts=true to leave the stored binaries in place. Requires binaries:manage scope (mirrors:manage is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
-// @Param        id       path  string  true  "Mirror config UUID"
-// @Param        version  path  string  true  "Terraform version (e.g. 1.7.0)"
+// @Param        id              path   string  true   "Mirror config UUID"
+// @Param        version         path   string  true   "Terraform version (e.g. 1.7.0)"
+// @Param        keep_artifacts  query  bool    false  "Leave the stored binaries in storage"
 // @Success      200  {object}  admin.DeleteTerraformVersionResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      404  {object}  map[string]interface{}  "Not found"
@@ -635,21 +768,30 @@ func (h *TerraformMirrorHandler) DeleteVersion(c *gin.Context) {
 		return
 	}
 
-	// Remove the stored artifacts before the database rows (best-effort).
-	h.deleteVersionArtifacts(c.Request.Context(), v)
+	keep := c.Query("keep_artifacts") == "true"
+	var artifacts []models.TerraformStoredArtifact
+	if h.storageBackend != nil && !keep {
+		artifacts, err = h.repo.ListVersionArtifacts(c.Request.Context(), v.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list stored artifacts: " + err.Error()})
+			return
+		}
+	}
 
 	if delErr := h.repo.DeleteVersion(c.Request.Context(), v.ID); delErr != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete version: " + delErr.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Version deleted", "version": versionStr})
+	resp := gin.H{"message": "Version deleted", "version": versionStr}
+	h.addArtifactResult(c, resp, keep, artifacts, deletionSourceTerraformVersion)
+	c.JSON(http.StatusOK, resp)
 }
 
 // ---- POST /api/v1/admin/terraform-mirrors/:id/versions/:version/deprecate --
 
 // @Summary      Deprecate a mirrored Terraform version
-// @Description  Mark a mirrored Terraform/OpenTofu version as deprecated. Deprecated versions are skipped by the sync job (no further binary downloads), but already-mirrored artifacts remain available so existing pulls keep working. Requires mirrors:manage scope.
+// @Description  Mark a mirrored Terraform/OpenTofu version as deprecated. Deprecated versions are skipped by the sync job (no further binary downloads), but already-mirrored artifacts remain available so existing pulls keep working. Requires binaries:manage scope (mirrors:manage is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
@@ -689,7 +831,7 @@ func (h *TerraformMirrorHandler) DeprecateVersion(c *gin.Context) {
 // ---- DELETE /api/v1/admin/terraform-mirrors/:id/versions/:version/deprecate
 
 // @Summary      Undeprecate a mirrored Terraform version
-// @Description  Clear the deprecated flag on a mirrored Terraform/OpenTofu version, restoring normal sync behavior on subsequent runs. Requires mirrors:manage scope.
+// @Description  Clear the deprecated flag on a mirrored Terraform/OpenTofu version, restoring normal sync behavior on subsequent runs. Requires binaries:manage scope (mirrors:manage is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
@@ -729,13 +871,14 @@ func (h *TerraformMirrorHandler) UndeprecateVersion(c *gin.Context) {
 // ---- GET /api/v1/admin/terraform-mirrors/:id/history ----------------------
 
 // @Summary      Get Terraform mirror sync history
-// @Description  Returns the most recent sync run records for the specified config. Requires mirrors:read scope.
+// @Description  Returns a page of sync run records for the specified config, newest first. History is bounded by the config's retention settings. Requires binaries:read scope (mirrors:read is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
-// @Param        id     path   string  true   "Mirror config UUID"
-// @Param        limit  query  int     false  "Maximum number of history rows to return (default: 50)"
-// @Success      200  {object}  models.TerraformSyncHistoryListResponse
+// @Param        id      path   string  true   "Mirror config UUID"
+// @Param        limit   query  int     false  "Maximum number of history rows to return (default 50, max 200)"
+// @Param        offset  query  int     false  "Offset for pagination (default 0)"
+// @Success      200  {object}  admin.TerraformSyncHistoryPage
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      404  {object}  map[string]interface{}  "Not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
@@ -750,29 +893,29 @@ func (h *TerraformMirrorHandler) GetSyncHistory(c *gin.Context) {
 		return
 	}
 
-	limit := 50
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
-			limit = n
-		}
-	}
+	page := pagination.Parse(c, syncHistoryLimits)
 
-	history, err := h.repo.ListSyncHistory(c.Request.Context(), id, limit)
+	history, total, err := h.repo.ListSyncHistory(c.Request.Context(), id, page.Limit, page.Offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load history: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.TerraformSyncHistoryListResponse{
-		History:    history,
-		TotalCount: len(history),
+	c.JSON(http.StatusOK, TerraformSyncHistoryPage{
+		TerraformSyncHistoryListResponse: models.TerraformSyncHistoryListResponse{
+			History:    history,
+			TotalCount: total,
+			Limit:      page.Limit,
+			Offset:     page.Offset,
+		},
+		Meta: page.Meta(int64(total)),
 	})
 }
 
 // ---- GET /api/v1/admin/terraform-mirrors/:id/versions/:version/platforms ---
 
 // @Summary      List platforms for a Terraform version
-// @Description  Returns per-platform sync details for a specific version. Requires mirrors:read scope.
+// @Description  Returns per-platform sync details for a specific version. Requires binaries:read scope (mirrors:read is also accepted).
 // @Tags         Terraform Mirror
 // @Security     Bearer
 // @Produce      json
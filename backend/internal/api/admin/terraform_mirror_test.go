@@ -2,6 +2,8 @@ package admin
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,8 +13,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
 // ---------------------------------------------------------------------------
@@ -23,6 +27,8 @@ var tmcCols = []string{
 	"id", "name", "description", "tool", "enabled", "upstream_url",
 	"platform_filter", "version_filter", "gpg_verify", "stable_only", "sync_interval_hours",
 	"requires_approval", "auto_approve_rules", "verify_github_attestation",
+	"history_retention_count", "history_retention_days", "exclude_eol_from_latest",
+	"max_storage_bytes", "storage_alert_level",
 	"last_sync_at", "last_sync_status", "last_sync_error",
 	"created_at", "updated_at",
 }
@@ -60,6 +66,8 @@ func sampleTMCRow() *sqlmock.Rows {
 			knownUUID, "my-mirror", nil, "terraform", false,
 			"https://releases.hashicorp.com", nil, nil, true, false, 24,
 			false, nil, false,
+			500, 90, false,
+			nil, 0,
 			nil, nil, nil,
 			time.Now(), time.Now(),
 		)
@@ -310,6 +318,10 @@ func TestTMCreateConfig_DefaultsStableOnlyAndApproval(t *testing.T) {
 			true,             // requires_approval -> default true
 			sqlmock.AnyArg(), // auto_approve_rules
 			false,            // verify_github_attestation -> default false
+			500,              // history_retention_count -> default 500
+			90,               // history_retention_days -> default 90
+			false,            // exclude_eol_from_latest -> default false
+			nil,              // max_storage_bytes -> no limit
 			sqlmock.AnyArg(), // created_at
 			sqlmock.AnyArg(), // updated_at
 		).
@@ -353,6 +365,10 @@ func TestTMCreateConfig_ExplicitFalseOverridesDefaults(t *testing.T) {
 			false,            // requires_approval -> explicit false honored
 			sqlmock.AnyArg(), // auto_approve_rules
 			false,            // verify_github_attestation -> default false
+			500,              // history_retention_count -> default 500
+			90,               // history_retention_days -> default 90
+			false,            // exclude_eol_from_latest -> default false
+			nil,              // max_storage_bytes -> no limit
 			sqlmock.AnyArg(), // created_at
 			sqlmock.AnyArg(), // updated_at
 		).
@@ -390,7 +406,8 @@ func TestTMCreateConfig_VerifyGitHubAttestationDefaultsFalse(t *testing.T) {
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
 			false, // verify_github_attestation -> default false
-			sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 		).
 		WillReturnRows(sampleTMCRow())
 
@@ -422,7 +439,8 @@ func TestTMCreateConfig_VerifyGitHubAttestationExplicitTrue(t *testing.T) {
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
 			true, // verify_github_attestation -> explicit true honored
-			sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 		).
 		WillReturnRows(sampleTMCRow())
 
@@ -583,48 +601,25 @@ func TestTMDeleteConfig_Success(t *testing.T) {
 
 // When a storage backend is attached, deleting a config removes the stored
 // binaries for every version it owns (each platform package plus per-version
-// SHA256SUMS and detached signature) before the cascading database delete.
+// SHA256SUMS and detached signature) after the cascading database delete.
 func TestTMDeleteConfig_DeletesBinaries(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("sqlmock.New: %v", err)
-	}
-	t.Cleanup(func() { db.Close() })
-	repo := repositories.NewTerraformMirrorRepository(sqlx.NewDb(db, "sqlmock"))
-	store := &mockStorage{}
-	h := NewTerraformMirrorHandler(repo)
-	h.SetStorageBackend(store)
+	mock, h, store := newTMArtifactHandler(t)
 	r := gin.New()
 	r.DELETE("/terraform-mirrors/:id", h.DeleteConfig)
 
 	// GetByID -> the config being deleted.
 	mock.ExpectQuery("SELECT.*FROM terraform_mirror_configs WHERE id").
 		WillReturnRows(sampleTMCRow())
-	// ListVersions -> two synced versions, each with stored SHA256SUMS + signature.
-	versionCols := []string{
-		"id", "config_id", "version", "is_latest", "is_deprecated", "release_date",
-		"sync_status", "sync_error", "synced_at", "created_at", "updated_at",
-		"sums_storage_key", "sig_storage_key", "approval_status",
-	}
-	mock.ExpectQuery("SELECT.*FROM terraform_versions WHERE config_id").
-		WillReturnRows(sqlmock.NewRows(versionCols).
-			AddRow(knownUUID, knownUUID, "1.7.0", true, false, nil,
-				"synced", nil, nil, time.Now(), time.Now(),
-				"tf/1.7.0/SHA256SUMS", "tf/1.7.0/SHA256SUMS.sig", "approved").
-			AddRow(knownUUID, knownUUID, "1.6.0", false, false, nil,
-				"synced", nil, nil, time.Now(), time.Now(),
-				"tf/1.6.0/SHA256SUMS", "tf/1.6.0/SHA256SUMS.sig", "approved"))
-	// ListPlatformsForVersion -> one stored binary per version (queried in list order).
-	mock.ExpectQuery("SELECT.*FROM terraform_version_platforms WHERE version_id").
-		WillReturnRows(sqlmock.NewRows(tmPlatformCols).
-			AddRow(knownUUID, knownUUID, "linux", "amd64", "u", "f1", "h1",
-				"tf/1.7.0/linux/amd64/terraform_1.7.0_linux_amd64.zip", "s3", true, true,
-				"synced", nil, nil, time.Now(), time.Now()))
-	mock.ExpectQuery("SELECT.*FROM terraform_version_platforms WHERE version_id").
-		WillReturnRows(sqlmock.NewRows(tmPlatformCols).
-			AddRow(knownUUID, knownUUID, "linux", "amd64", "u", "f2", "h2",
-				"tf/1.6.0/linux/amd64/terraform_1.6.0_linux_amd64.zip", "s3", true, true,
-				"synced", nil, nil, time.Now(), time.Now()))
+	// ListConfigArtifacts -> two versions, each with a package, SHA256SUMS and signature.
+	mock.ExpectQuery("SELECT p.storage_key.*FROM terraform_version_platforms.*WHERE v.config_id").
+		WillReturnRows(tmArtifactRows(
+			"tf/1.7.0/linux/amd64/terraform_1.7.0_linux_amd64.zip",
+			"tf/1.6.0/linux/amd64/terraform_1.6.0_linux_amd64.zip",
+			"tf/1.7.0/SHA256SUMS",
+			"tf/1.6.0/SHA256SUMS",
+			"tf/1.7.0/SHA256SUMS.sig",
+			"tf/1.6.0/SHA256SUMS.sig",
+		))
 	mock.ExpectExec("DELETE FROM terraform_mirror_configs WHERE id").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -654,6 +649,14 @@ func TestTMDeleteConfig_DeletesBinaries(t *testing.T) {
 			t.Errorf("expected storage Delete(%q); got %v", key, store.deleted)
 		}
 	}
+	var resp DeleteTerraformMirrorResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Artifacts == nil || resp.Artifacts.Deleted != 6 || resp.Artifacts.BytesFreed != 6*tmArtifactSize {
+		t.Errorf("artifacts = %+v, want 6 deleted, %d bytes freed", resp.Artifacts, 6*tmArtifactSize)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -932,6 +935,11 @@ func TestTMGetStatus_Success(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"version_count", "platform_count", "pending_count"}).AddRow(10, 8, 2))
 	mock.ExpectQuery("SELECT.*FROM terraform_versions WHERE config_id.*is_latest").
 		WillReturnRows(sqlmock.NewRows(tfvCols))
+	mock.ExpectQuery("SELECT COUNT.*FROM terraform_sync_history").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"total_runs", "successful_runs", "failed_runs", "cancelled_runs",
+			"window_start", "last_success_at", "last_failure_at",
+		}).AddRow(10, 9, 1, 0, time.Now(), time.Now(), time.Now()))
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("GET", "/terraform-mirrors/"+knownUUID+"/status", nil))
@@ -939,6 +947,10 @@ func TestTMGetStatus_Success(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("status = %d, want 200: body=%s", w.Code, w.Body.String())
 	}
+	stats, _ := getJSON(w)["sync_stats"].(map[string]interface{})
+	if stats["success_rate"] != 0.9 {
+		t.Errorf("sync_stats = %v, want success_rate 0.9", stats)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -996,6 +1008,37 @@ func TestTMGetVersion_Success(t *testing.T) {
 	}
 }
 
+func TestTMGetVersion_SignatureMethod(t *testing.T) {
+	mock, r := newTerraformMirrorRouter(t)
+	cols := append(append([]string{}, tmPlatformCols...), "signature_method")
+	mock.ExpectQuery("SELECT.*FROM terraform_versions WHERE config_id.*AND version").
+		WillReturnRows(sampleTFVRow())
+	mock.ExpectQuery("SELECT.*FROM terraform_version_platforms WHERE version_id").
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(knownUUID, knownUUID, "linux", "amd64", "u", "f1", "h1",
+				"tofu/1.8.0/linux/amd64/tofu_1.8.0_linux_amd64.zip", "s3", true, false,
+				"synced", nil, nil, time.Now(), time.Now(), "cosign"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/terraform-mirrors/"+knownUUID+"/versions/1.8.0", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Platforms []struct {
+			GPGVerified     bool    `json:"gpg_verified"`
+			SignatureMethod *string `json:"signature_method"`
+		} `json:"platforms"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Platforms) != 1 || resp.Platforms[0].SignatureMethod == nil || *resp.Platforms[0].SignatureMethod != "cosign" {
+		t.Errorf("platforms = %+v, want one platform with signature_method=cosign", resp.Platforms)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // DeleteVersion (mirror handler) tests
 // ---------------------------------------------------------------------------
@@ -1054,38 +1097,19 @@ func TestTMMirrorDeleteVersion_Success(t *testing.T) {
 // When a storage backend is attached, deleting a version also removes the stored
 // platform packages plus the version's SHA256SUMS and detached signature.
 func TestTMMirrorDeleteVersion_DeletesBinaries(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("sqlmock.New: %v", err)
-	}
-	t.Cleanup(func() { db.Close() })
-	repo := repositories.NewTerraformMirrorRepository(sqlx.NewDb(db, "sqlmock"))
-	store := &mockStorage{}
-	h := NewTerraformMirrorHandler(repo)
-	h.SetStorageBackend(store)
+	mock, h, store := newTMArtifactHandler(t)
 	r := gin.New()
 	r.DELETE("/terraform-mirrors/:id/versions/:version", h.DeleteVersion)
 
-	// GetVersionByString -> a synced version that has stored SHA256SUMS + signature.
-	mock.ExpectQuery("SELECT.*FROM terraform_versions WHERE config_id.*AND version").
-		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "config_id", "version", "is_latest", "is_deprecated", "release_date",
-			"sync_status", "sync_error", "synced_at", "created_at", "updated_at",
-			"sums_storage_key", "sig_storage_key", "approval_status",
-		}).AddRow(
-			knownUUID, knownUUID, "1.7.0", true, false, nil,
-			"synced", nil, nil, time.Now(), time.Now(),
-			"terraform-binaries/1.7.0/SHA256SUMS", "terraform-binaries/1.7.0/SHA256SUMS.sig", "approved",
+	expectTMVersionLookup(mock)
+	// ListVersionArtifacts -> two stored platform binaries plus the signature files.
+	mock.ExpectQuery("SELECT p.storage_key.*FROM terraform_version_platforms.*WHERE v.id").
+		WillReturnRows(tmArtifactRows(
+			"terraform-binaries/1.7.0/linux/amd64/terraform_1.7.0_linux_amd64.zip",
+			"terraform-binaries/1.7.0/windows/amd64/terraform_1.7.0_windows_amd64.zip",
+			"terraform-binaries/1.7.0/SHA256SUMS",
+			"terraform-binaries/1.7.0/SHA256SUMS.sig",
 		))
-	// ListPlatformsForVersion -> two stored platform binaries.
-	mock.ExpectQuery("SELECT.*FROM terraform_version_platforms WHERE version_id").
-		WillReturnRows(sqlmock.NewRows(tmPlatformCols).
-			AddRow(knownUUID, knownUUID, "linux", "amd64", "u", "f1", "h1",
-				"terraform-binaries/1.7.0/linux/amd64/terraform_1.7.0_linux_amd64.zip", "s3", true, true,
-				"synced", nil, nil, time.Now(), time.Now()).
-			AddRow(knownUUID, knownUUID, "windows", "amd64", "u", "f2", "h2",
-				"terraform-binaries/1.7.0/windows/amd64/terraform_1.7.0_windows_amd64.zip", "s3", true, true,
-				"synced", nil, nil, time.Now(), time.Now()))
 	mock.ExpectExec("DELETE FROM terraform_versions WHERE id").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -1113,6 +1137,146 @@ func TestTMMirrorDeleteVersion_DeletesBinaries(t *testing.T) {
 			t.Errorf("expected storage Delete(%q); got %v", key, store.deleted)
 		}
 	}
+	var resp DeleteTerraformVersionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Artifacts == nil || resp.Artifacts.Deleted != 4 || resp.Artifacts.BytesFreed != 4*tmArtifactSize {
+		t.Errorf("artifacts = %+v, want 4 deleted, %d bytes freed", resp.Artifacts, 4*tmArtifactSize)
+	}
+}
+
+// Missing objects are tolerated and failed deletes are recorded for garbage
+// collection; neither fails the request once the rows are gone.
+func TestTMMirrorDeleteVersion_MissingAndFailedArtifacts(t *testing.T) {
+	mock, h, store := newTMArtifactHandler(t)
+	store.missing = map[string]bool{"tf/1.7.0/SHA256SUMS.sig": true}
+	store.failing = map[string]bool{"tf/1.7.0/SHA256SUMS": true}
+	recorder := &fakeDeletionFailureRecorder{}
+	h.SetDeletionFailureRecorder(recorder)
+	r := gin.New()
+	r.DELETE("/terraform-mirrors/:id/versions/:version", h.DeleteVersion)
+
+	expectTMVersionLookup(mock)
+	mock.ExpectQuery("SELECT p.storage_key.*FROM terraform_version_platforms.*WHERE v.id").
+		WillReturnRows(tmArtifactRows("tf/1.7.0/linux/amd64/terraform.zip", "tf/1.7.0/SHA256SUMS", "tf/1.7.0/SHA256SUMS.sig"))
+	mock.ExpectExec("DELETE FROM terraform_versions WHERE id").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/terraform-mirrors/"+knownUUID+"/versions/1.7.0", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+
+	var resp DeleteTerraformVersionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	want := TerraformArtifactDeletionSummary{Deleted: 1, Missing: 1, Failed: 1, BytesFreed: tmArtifactSize}
+	if resp.Artifacts == nil || *resp.Artifacts != want {
+		t.Errorf("artifacts = %+v, want %+v", resp.Artifacts, want)
+	}
+	if len(recorder.recorded) != 1 || recorder.recorded[0].StorageKey != "tf/1.7.0/SHA256SUMS" ||
+		recorder.recorded[0].Source != deletionSourceTerraformVersion {
+		t.Errorf("recorded = %+v, want the failed SHA256SUMS delete", recorder.recorded)
+	}
+}
+
+// keep_artifacts=true deletes only the rows and leaves storage untouched.
+func TestTMMirrorDeleteVersion_KeepArtifacts(t *testing.T) {
+	mock, h, store := newTMArtifactHandler(t)
+	r := gin.New()
+	r.DELETE("/terraform-mirrors/:id/versions/:version", h.DeleteVersion)
+
+	expectTMVersionLookup(mock)
+	mock.ExpectExec("DELETE FROM terraform_versions WHERE id").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/terraform-mirrors/"+knownUUID+"/versions/1.7.0?keep_artifacts=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if len(store.deleted) != 0 {
+		t.Errorf("deleted = %v, want nothing", store.deleted)
+	}
+	var resp DeleteTerraformVersionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if !resp.ArtifactsKept || resp.Artifacts != nil {
+		t.Errorf("response = %+v, want artifacts_kept and no summary", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+const tmArtifactSize = 100
+
+// tmArtifactStorage is mockStorage reporting every object as tmArtifactSize
+// bytes, except missing keys (absent) and failing keys (Delete errors).
+type tmArtifactStorage struct {
+	mockStorage
+	missing map[string]bool
+	failing map[string]bool
+}
+
+func (s *tmArtifactStorage) GetMetadata(_ context.Context, path string) (*storage.FileMetadata, error) {
+	if s.missing[path] {
+		return nil, errors.New("file not found: " + path)
+	}
+	return &storage.FileMetadata{Path: path, Size: tmArtifactSize}, nil
+}
+
+func (s *tmArtifactStorage) Exists(_ context.Context, path string) (bool, error) {
+	return !s.missing[path], nil
+}
+
+func (s *tmArtifactStorage) Delete(ctx context.Context, path string) error {
+	if s.failing[path] {
+		return errors.New("access denied")
+	}
+	return s.mockStorage.Delete(ctx, path)
+}
+
+type fakeDeletionFailureRecorder struct {
+	recorded []models.StorageDeletionFailure
+}
+
+func (f *fakeDeletionFailureRecorder) Record(_ context.Context, failure *models.StorageDeletionFailure) error {
+	f.recorded = append(f.recorded, *failure)
+	return nil
+}
+
+func newTMArtifactHandler(t *testing.T) (sqlmock.Sqlmock, *TerraformMirrorHandler, *tmArtifactStorage) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store := &tmArtifactStorage{}
+	h := NewTerraformMirrorHandler(repositories.NewTerraformMirrorRepository(sqlx.NewDb(db, "sqlmock")))
+	h.SetStorageBackend(store)
+	return mock, h, store
+}
+
+// expectTMVersionLookup expects GetVersionByString to return version 1.7.0.
+func expectTMVersionLookup(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT.*FROM terraform_versions WHERE config_id.*AND version").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "config_id", "version", "is_latest", "is_deprecated", "release_date",
+			"sync_status", "sync_error", "synced_at", "created_at", "updated_at",
+			"sums_storage_key", "sig_storage_key", "approval_status",
+		}).AddRow(
+			knownUUID, knownUUID, "1.7.0", true, false, nil,
+			"synced", nil, nil, time.Now(), time.Now(),
+			"tf/1.7.0/SHA256SUMS", "tf/1.7.0/SHA256SUMS.sig", "approved",
+		))
+}
+
+func tmArtifactRows(keys ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"storage_key", "storage_backend"})
+	for _, k := range keys {
+		rows.AddRow(k, "s3")
+	}
+	return rows
 }
 
 // ---------------------------------------------------------------------------
@@ -1261,7 +1425,10 @@ func TestTMGetSyncHistory_Success(t *testing.T) {
 	mock, r := newTerraformMirrorRouter(t)
 	mock.ExpectQuery("SELECT.*FROM terraform_mirror_configs WHERE id").
 		WillReturnRows(sampleTMCRow())
+	mock.ExpectQuery("SELECT COUNT.*FROM terraform_sync_history WHERE config_id").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mock.ExpectQuery("SELECT.*FROM terraform_sync_history WHERE config_id").
+		WithArgs(sqlmock.AnyArg(), 50, 0).
 		WillReturnRows(sqlmock.NewRows(syncHistoryCols))
 
 	w := httptest.NewRecorder()
@@ -1588,15 +1755,43 @@ func TestTMGetSyncHistory_WithLimit(t *testing.T) {
 	mock, r := newTerraformMirrorRouter(t)
 	mock.ExpectQuery("SELECT.*FROM terraform_mirror_configs WHERE id").
 		WillReturnRows(sampleTMCRow())
+	mock.ExpectQuery("SELECT COUNT.*FROM terraform_sync_history WHERE config_id").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
 	mock.ExpectQuery("SELECT.*FROM terraform_sync_history WHERE config_id").
+		WithArgs(sqlmock.AnyArg(), 10, 20).
 		WillReturnRows(sqlmock.NewRows(syncHistoryCols))
 
 	w := httptest.NewRecorder()
-	r.ServeHTTP(w, httptest.NewRequest("GET", "/terraform-mirrors/"+knownUUID+"/history?limit=10", nil))
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/terraform-mirrors/"+knownUUID+"/history?limit=10&offset=20", nil))
 
 	if w.Code != http.StatusOK {
 		t.Errorf("status = %d, want 200: body=%s", w.Code, w.Body.String())
 	}
+	body := getJSON(w)
+	if body["total_count"] != float64(42) || body["limit"] != float64(10) || body["offset"] != float64(20) {
+		t.Errorf("page = %v, want total_count 42, limit 10, offset 20", body)
+	}
+}
+
+func TestTMGetSyncHistory_LimitCapped(t *testing.T) {
+	mock, r := newTerraformMirrorRouter(t)
+	mock.ExpectQuery("SELECT.*FROM terraform_mirror_configs WHERE id").
+		WillReturnRows(sampleTMCRow())
+	mock.ExpectQuery("SELECT COUNT.*FROM terraform_sync_history WHERE config_id").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(40000))
+	mock.ExpectQuery("SELECT.*FROM terraform_sync_history WHERE config_id").
+		WithArgs(sqlmock.AnyArg(), 200, 0).
+		WillReturnRows(sqlmock.NewRows(syncHistoryCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/terraform-mirrors/"+knownUUID+"/history?limit=100000", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("limit not capped: %v", err)
+	}
 }
 
 // ---------------------------------------------------------------------------
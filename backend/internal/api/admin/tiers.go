@@ -0,0 +1,165 @@
+// tiers.go implements the admin endpoints that set a provider's or module's
+// registry-assigned tier (community, approved, official, mirrored, or a
+// deployment's own), the badge search and detail responses carry.
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/events"
+)
+
+// TierRequest is the body of the tier endpoints.
+type TierRequest struct {
+	// Tier is community, approved, official, mirrored, or any other
+	// lowercase slug; verified and partner are accepted as aliases of approved.
+	Tier string `json:"tier" binding:"required"`
+}
+
+// TierResponse is returned by the tier endpoints.
+type TierResponse struct {
+	ID           string `json:"id"`
+	Tier         string `json:"tier"`
+	PreviousTier string `json:"previous_tier"`
+}
+
+// bindTier parses and normalises a TierRequest, writing a 400 and returning
+// false when it is invalid.
+func bindTier(c *gin.Context) (string, bool) {
+	var req TierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return "", false
+	}
+	tier, err := models.NormalizeTier(req.Tier)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return tier, true
+}
+
+// @Summary      Set provider tier
+// @Description  Sets the registry-assigned tier search and detail responses badge a provider with: community (the default for uploaded providers), approved, official, mirrored (the default for providers created by mirror sync or the pull-through cache), or any other lowercase slug of up to 32 letters, digits and hyphens. verified and partner are accepted as aliases of approved. A change publishes a provider.tier_changed organization event. Requires admin scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string             true  "Provider record UUID"
+// @Param        body  body  admin.TierRequest  true  "Tier"
+// @Success      200  {object}  admin.TierResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid tier"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden - requires admin scope"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{id}/tier [patch]
+// SetProviderTier sets a provider's tier
+// PATCH /api/v1/admin/providers/:id/tier
+func (h *ProviderAdminHandlers) SetProviderTier(c *gin.Context) {
+	tier, ok := bindTier(c)
+	if !ok {
+		return
+	}
+
+	provider, err := h.providerRepo.GetProviderByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider"})
+		return
+	}
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+		return
+	}
+
+	previous, err := h.providerRepo.SetTier(c.Request.Context(), provider.ID, tier)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set provider tier"})
+		return
+	}
+	if previous == "" {
+		// Deleted since it was looked up.
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+		return
+	}
+
+	if previous != tier {
+		h.events.Publish(events.Event{
+			Type:           events.TypeProviderTierChanged,
+			OrganizationID: provider.OrganizationID,
+			ActorID:        eventActor(c),
+			Data: map[string]string{
+				"provider_id":   provider.ID,
+				"namespace":     provider.Namespace,
+				"type":          provider.Type,
+				"tier":          tier,
+				"previous_tier": previous,
+			},
+		})
+	}
+	c.JSON(http.StatusOK, TierResponse{ID: provider.ID, Tier: tier, PreviousTier: previous})
+}
+
+// @Summary      Set module tier
+// @Description  Sets the registry-assigned tier search and detail responses badge a module with: community (the default), approved, official, or any other lowercase slug of up to 32 letters, digits and hyphens. verified and partner are accepted as aliases of approved. A change publishes a module.tier_changed organization event. Requires admin scope.
+// @Tags         Modules
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string             true  "Module record UUID"
+// @Param        body  body  admin.TierRequest  true  "Tier"
+// @Success      200  {object}  admin.TierResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid tier"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden - requires admin scope"
+// @Failure      404  {object}  map[string]interface{}  "Module not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/modules/{id}/tier [patch]
+// SetModuleTier sets a module's tier
+// PATCH /api/v1/admin/modules/:id/tier
+func (h *ModuleAdminHandlers) SetModuleTier(c *gin.Context) {
+	tier, ok := bindTier(c)
+	if !ok {
+		return
+	}
+
+	module, err := h.moduleRepo.GetModuleByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get module"})
+		return
+	}
+	if module == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module not found"})
+		return
+	}
+
+	previous, err := h.moduleRepo.SetTier(c.Request.Context(), module.ID, tier)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set module tier"})
+		return
+	}
+	if previous == "" {
+		// Deleted since it was looked up.
+		c.JSON(http.StatusNotFound, gin.H{"error": "module not found"})
+		return
+	}
+
+	if previous != tier {
+		h.events.Publish(events.Event{
+			Type:           events.TypeModuleTierChanged,
+			OrganizationID: module.OrganizationID,
+			ActorID:        eventActor(c),
+			Data: map[string]string{
+				"module_id":     module.ID,
+				"namespace":     module.Namespace,
+				"name":          module.Name,
+				"system":        module.System,
+				"tier":          tier,
+				"previous_tier": previous,
+			},
+		})
+	}
+	c.JSON(http.StatusOK, TierResponse{ID: module.ID, Tier: tier, PreviousTier: previous})
+}
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/events"
+)
+
+func newTierRouter(t *testing.T, bus *events.Bus) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	providers := NewProviderAdminHandlers(db, &mockStorage{}, &config.Config{}).WithEvents(bus)
+	modules := NewModuleAdminHandlers(db, &mockStorage{}, &config.Config{}).WithEvents(bus)
+	r := gin.New()
+	r.PATCH("/providers/:id/tier", providers.SetProviderTier)
+	r.PATCH("/modules/:id/tier", modules.SetModuleTier)
+	return mock, r
+}
+
+func patchTier(r *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PATCH", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSetProviderTier_InvalidTier(t *testing.T) {
+	_, r := newTierRouter(t, nil)
+	for _, body := range []string{`{}`, `{"tier":"first_party"}`} {
+		if w := patchTier(r, "/providers/prov-1/tier", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestSetProviderTier_NotFound(t *testing.T) {
+	mock, r := newTierRouter(t, nil)
+	mock.ExpectQuery("SELECT.*FROM providers").WithArgs("prov-9").WillReturnRows(emptyProviderRow())
+
+	if w := patchTier(r, "/providers/prov-9/tier", `{"tier":"approved"}`); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestSetProviderTier_PublishesChange(t *testing.T) {
+	bus := events.NewBus(0)
+	stop := collectEvents(t, bus)
+	mock, r := newTierRouter(t, bus)
+	mock.ExpectQuery("SELECT.*FROM providers").WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows(providerCols).
+			AddRow("prov-1", "org-1", "hashicorp", "aws", nil, nil, nil, time.Now(), time.Now(), nil))
+	mock.ExpectQuery("UPDATE providers.*RETURNING old.tier").WithArgs("prov-1", "approved").
+		WillReturnRows(sqlmock.NewRows([]string{"tier"}).AddRow("community"))
+
+	w := patchTier(r, "/providers/prov-1/tier", `{"tier":"Verified"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	resp := getJSON(w)
+	if resp["tier"] != "approved" || resp["previous_tier"] != "community" {
+		t.Errorf("response = %v", resp)
+	}
+
+	got := stop()
+	if len(got) != 1 || got[0].Type != events.TypeProviderTierChanged || got[0].Data["tier"] != "approved" || got[0].Data["previous_tier"] != "community" {
+		t.Errorf("events = %+v", got)
+	}
+}
+
+func TestSetModuleTier_UnchangedPublishesNothing(t *testing.T) {
+	bus := events.NewBus(0)
+	stop := collectEvents(t, bus)
+	mock, r := newTierRouter(t, bus)
+	mock.ExpectQuery("SELECT.*FROM modules").WithArgs("mod-1").WillReturnRows(sampleModuleRow())
+	mock.ExpectQuery("UPDATE modules.*RETURNING old.tier").WithArgs("mod-1", "official").
+		WillReturnRows(sqlmock.NewRows([]string{"tier"}).AddRow("official"))
+
+	w := patchTier(r, "/modules/mod-1/tier", `{"tier":"official"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got := stop(); len(got) != 0 {
+		t.Errorf("events = %+v, want none", got)
+	}
+}
+
+func TestSetModuleTier_DeletedConcurrently(t *testing.T) {
+	mock, r := newTierRouter(t, nil)
+	mock.ExpectQuery("SELECT.*FROM modules").WithArgs("mod-1").WillReturnRows(sampleModuleRow())
+	mock.ExpectQuery("UPDATE modules.*RETURNING old.tier").
+		WillReturnRows(sqlmock.NewRows([]string{"tier"}))
+
+	if w := patchTier(r, "/modules/mod-1/tier", `{"tier":"official"}`); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,208 @@
+// token_exchange.go implements POST /api/v1/auth/token-exchange: a CI job
+// presents the OIDC ID token its platform issued (e.g. GitHub Actions) and the
+// namespace it wants to publish to, and receives a short-lived registry token
+// with modules:write for that namespace only.
+//
+// The exchange succeeds only when the ID token verifies against a configured
+// trusted issuer and audience and an enabled trust rule for the namespace
+// matches its repository, ref and environment claims. Every attempt that gets
+// as far as a verified identity is written to the audit log with the claims
+// that were used, whether it succeeded or not.
+package admin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/auth/tokenexchange"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// CIIdentityVerifier verifies a CI OIDC ID token. *tokenexchange.Verifier is
+// the production implementation.
+type CIIdentityVerifier interface {
+	Verify(ctx context.Context, rawToken string) (*models.CIIdentity, error)
+}
+
+// TokenExchangeHandlers serves the CI OIDC token exchange.
+type TokenExchangeHandlers struct {
+	cfg       *config.TokenExchangeConfig
+	verifier  CIIdentityVerifier
+	ruleRepo  *repositories.CITrustRuleRepository
+	auditRepo *repositories.AuditRepository
+}
+
+// NewTokenExchangeHandlers constructs a TokenExchangeHandlers. auditRepo may
+// be nil, in which case exchanges are not audited.
+func NewTokenExchangeHandlers(cfg *config.TokenExchangeConfig, verifier CIIdentityVerifier, ruleRepo *repositories.CITrustRuleRepository, auditRepo *repositories.AuditRepository) *TokenExchangeHandlers {
+	return &TokenExchangeHandlers{
+		cfg:       cfg,
+		verifier:  verifier,
+		ruleRepo:  ruleRepo,
+		auditRepo: auditRepo,
+	}
+}
+
+// TokenExchangeRequest is the body of POST /auth/token-exchange.
+type TokenExchangeRequest struct {
+	Token     string `json:"token" binding:"required"`     // CI OIDC ID token
+	Namespace string `json:"namespace" binding:"required"` // namespace to publish to
+}
+
+// TokenExchangeResponse is the publish token returned by a successful exchange.
+type TokenExchangeResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int       `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Namespace   string    `json:"namespace"`
+	Scopes      []string  `json:"scopes"`
+}
+
+// @Summary      Exchange a CI OIDC token for a publish token
+// @Description  Verifies an OIDC ID token from a trusted CI issuer (audience and signature) and matches its repository/ref/environment claims against the namespace's CI trust rules. On success returns a short-lived bearer token with modules:write for that namespace only.
+// @Tags         CI Token Exchange
+// @Accept       json
+// @Produce      json
+// @Param        body  body  TokenExchangeRequest  true  "ID token and target namespace"
+// @Success      200  {object}  admin.TokenExchangeResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid request"
+// @Failure      401  {object}  map[string]interface{}  "ID token invalid or issuer not trusted"
+// @Failure      403  {object}  map[string]interface{}  "No trust rule matches the identity"
+// @Failure      404  {object}  map[string]interface{}  "Token exchange is disabled"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/auth/token-exchange [post]
+// ExchangeHandler mints a namespace-scoped publish token for a CI identity.
+// POST /api/v1/auth/token-exchange
+func (h *TokenExchangeHandlers) ExchangeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.cfg == nil || !h.cfg.Enabled {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Token exchange is not enabled"})
+			return
+		}
+
+		var req TokenExchangeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: token and namespace are required"})
+			return
+		}
+		if err := validation.ValidateRegistrySegment(req.Namespace); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid namespace: " + err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+		identity, err := h.verifier.Verify(ctx, req.Token)
+		if err != nil {
+			// Unverified claims are never trusted, so nothing is audited here.
+			slog.Warn("token exchange: identity token rejected", "namespace", req.Namespace, "error", err)
+			msg := "Invalid identity token"
+			if errors.Is(err, tokenexchange.ErrUntrustedIssuer) {
+				msg = "Identity token issuer is not trusted"
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+			return
+		}
+
+		rules, err := h.ruleRepo.ListEnabledForNamespace(ctx, req.Namespace, identity.Issuer)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load trust rules"})
+			return
+		}
+		var rule *models.CITrustRule
+		for _, r := range rules {
+			if r.Matches(identity) {
+				rule = r
+				break
+			}
+		}
+		if rule == nil {
+			h.audit(c, "auth.token_exchange_denied", nil, identity, req.Namespace, map[string]interface{}{
+				"reason": "no trust rule matches the identity",
+			})
+			c.JSON(http.StatusForbidden, gin.H{"error": "No trust rule allows this identity to publish to namespace " + req.Namespace})
+			return
+		}
+
+		scopes := []string{string(auth.ScopeModulesWrite)}
+		claims := &auth.CITokenClaims{
+			OrganizationID: rule.OrganizationID,
+			Namespace:      rule.Namespace,
+			Scopes:         scopes,
+			TrustRuleID:    rule.ID,
+			CIIssuer:       identity.Issuer,
+			CISubject:      identity.Subject,
+			CIRepository:   identity.Repository,
+			CIRef:          identity.Ref,
+		}
+		token, err := auth.GenerateCIToken(claims, h.cfg.TokenTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token"})
+			return
+		}
+		if err := h.ruleRepo.TouchLastUsed(ctx, rule.ID); err != nil {
+			slog.Warn("token exchange: failed to record trust rule use", "rule_id", rule.ID, "error", err)
+		}
+		h.audit(c, "auth.token_exchange", rule, identity, req.Namespace, map[string]interface{}{
+			"jti":        claims.ID,
+			"expires_at": claims.ExpiresAt.Time,
+		})
+
+		c.JSON(http.StatusOK, TokenExchangeResponse{
+			AccessToken: token,
+			TokenType:   "Bearer",
+			ExpiresIn:   int(h.cfg.TokenTTL.Seconds()),
+			ExpiresAt:   claims.ExpiresAt.Time,
+			Namespace:   rule.Namespace,
+			Scopes:      scopes,
+		})
+	}
+}
+
+// audit records an exchange attempt with the verified identity claims. It is
+// written synchronously: the exchange is the only record tying a publish
+// token back to the CI run that obtained it.
+func (h *TokenExchangeHandlers) audit(c *gin.Context, action string, rule *models.CITrustRule, identity *models.CIIdentity, namespace string, extra map[string]interface{}) {
+	if h.auditRepo == nil {
+		return
+	}
+	metadata := map[string]interface{}{
+		"namespace":    namespace,
+		"iss":          identity.Issuer,
+		"sub":          identity.Subject,
+		"repository":   identity.Repository,
+		"ref":          identity.Ref,
+		"environment":  identity.Environment,
+		"workflow_ref": identity.Workflow,
+		"actor":        identity.Actor,
+		"sha":          identity.SHA,
+		"run_id":       identity.RunID,
+	}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+
+	resourceType := "ci_trust_rule"
+	ip := c.ClientIP()
+	entry := &models.AuditLog{
+		Action:       action,
+		ResourceType: &resourceType,
+		Metadata:     metadata,
+		IPAddress:    &ip,
+	}
+	if rule != nil {
+		entry.ResourceID = &rule.ID
+		entry.OrganizationID = &rule.OrganizationID
+	}
+	if err := h.auditRepo.CreateAuditLog(c.Request.Context(), entry); err != nil {
+		slog.Error("failed to write audit log for token exchange", "error", err, "action", action)
+	}
+}
@@ -0,0 +1,191 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/auth/tokenexchange"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+const testCIIssuer = "https://token.actions.githubusercontent.com"
+
+var ciRuleCols = []string{
+	"id", "organization_id", "namespace", "issuer", "repository", "ref_pattern", "environment",
+	"description", "enabled", "created_by", "last_used_at", "created_at", "updated_at",
+}
+
+// stubCIVerifier returns a fixed identity or error, standing in for issuer
+// discovery and signature checks (covered in package tokenexchange).
+type stubCIVerifier struct {
+	identity *models.CIIdentity
+	err      error
+}
+
+func (s stubCIVerifier) Verify(context.Context, string) (*models.CIIdentity, error) {
+	return s.identity, s.err
+}
+
+func ciIdentity(repository, ref string) *models.CIIdentity {
+	return &models.CIIdentity{
+		Issuer:     testCIIssuer,
+		Subject:    "repo:" + repository + ":ref:" + ref,
+		Repository: repository,
+		Ref:        ref,
+		Actor:      "octocat",
+		RunID:      "42",
+	}
+}
+
+func newTokenExchangeRouter(t *testing.T, enabled bool, verifier CIIdentityVerifier) (sqlmock.Sqlmock, sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	auditDB, auditMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New (audit): %v", err)
+	}
+	t.Cleanup(func() { auditDB.Close() })
+
+	cfg := &config.TokenExchangeConfig{
+		Enabled:        enabled,
+		TrustedIssuers: []string{testCIIssuer},
+		Audience:       "terraform-registry",
+		TokenTTL:       15 * time.Minute,
+	}
+	h := NewTokenExchangeHandlers(cfg, verifier, repositories.NewCITrustRuleRepository(db), repositories.NewAuditRepository(auditDB))
+	r := gin.New()
+	r.POST("/auth/token-exchange", h.ExchangeHandler())
+	return mock, auditMock, r
+}
+
+func doTokenExchange(r *gin.Engine, namespace string) *httptest.ResponseRecorder {
+	body := fmt.Sprintf(`{"token":"id-token","namespace":%q}`, namespace)
+	req := httptest.NewRequest(http.MethodPost, "/auth/token-exchange", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func expectTrustRules(mock sqlmock.Sqlmock, namespace string) {
+	mock.ExpectQuery("SELECT.*FROM ci_trust_rules.*namespace = \\$1 AND issuer = \\$2").
+		WithArgs(namespace, testCIIssuer).
+		WillReturnRows(sqlmock.NewRows(ciRuleCols).
+			AddRow("rule-1", "org-1", namespace, testCIIssuer, "acme/terraform-aws-vpc", "refs/tags/v*", nil, nil, true, nil, nil, time.Now(), time.Now()))
+}
+
+func TestTokenExchange_Success(t *testing.T) {
+	mock, auditMock, r := newTokenExchangeRouter(t, true, stubCIVerifier{identity: ciIdentity("acme/terraform-aws-vpc", "refs/tags/v1.2.0")})
+	expectTrustRules(mock, "acme")
+	mock.ExpectExec("UPDATE ci_trust_rules SET last_used_at").
+		WithArgs("rule-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	auditMock.ExpectExec("INSERT INTO audit_logs").
+		WithArgs(sqlmock.AnyArg(), nil, "org-1", "auth.token_exchange", "ci_trust_rule", "rule-1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doTokenExchange(r, "acme")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp TokenExchangeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.TokenType != "Bearer" || resp.ExpiresIn != 900 || resp.Namespace != "acme" {
+		t.Errorf("resp = %+v", resp)
+	}
+
+	claims, err := auth.ValidateCIToken(resp.AccessToken)
+	if err != nil {
+		t.Fatalf("issued token does not validate: %v", err)
+	}
+	if claims.Namespace != "acme" || claims.OrganizationID != "org-1" || claims.CIRepository != "acme/terraform-aws-vpc" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if len(claims.Scopes) != 1 || claims.Scopes[0] != string(auth.ScopeModulesWrite) {
+		t.Errorf("scopes = %v, want [modules:write]", claims.Scopes)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+	if err := auditMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet audit expectations: %v", err)
+	}
+}
+
+func TestTokenExchange_WrongRepository_Forbidden(t *testing.T) {
+	mock, auditMock, r := newTokenExchangeRouter(t, true, stubCIVerifier{identity: ciIdentity("evil/terraform-aws-vpc", "refs/tags/v1.2.0")})
+	expectTrustRules(mock, "acme")
+	auditMock.ExpectExec("INSERT INTO audit_logs").
+		WithArgs(sqlmock.AnyArg(), nil, nil, "auth.token_exchange_denied", "ci_trust_rule", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doTokenExchange(r, "acme")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "access_token") {
+		t.Error("a rejected exchange must not return a token")
+	}
+	if err := auditMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("denied exchange was not audited: %v", err)
+	}
+}
+
+func TestTokenExchange_RefNotAllowed_Forbidden(t *testing.T) {
+	mock, auditMock, r := newTokenExchangeRouter(t, true, stubCIVerifier{identity: ciIdentity("acme/terraform-aws-vpc", "refs/heads/feature")})
+	expectTrustRules(mock, "acme")
+	auditMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if w := doTokenExchange(r, "acme"); w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTokenExchange_WrongAudience_Unauthorized(t *testing.T) {
+	// The verifier rejects a token minted for another audience; no rule
+	// lookup or audit entry may follow.
+	mock, auditMock, r := newTokenExchangeRouter(t, true, stubCIVerifier{
+		err: fmt.Errorf("%w: oidc: expected audience %q", tokenexchange.ErrInvalidToken, "terraform-registry"),
+	})
+
+	if w := doTokenExchange(r, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected DB access: %v", err)
+	}
+	if err := auditMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected audit: %v", err)
+	}
+}
+
+func TestTokenExchange_Disabled(t *testing.T) {
+	_, _, r := newTokenExchangeRouter(t, false, stubCIVerifier{identity: ciIdentity("acme/terraform-aws-vpc", "refs/tags/v1.2.0")})
+	if w := doTokenExchange(r, "acme"); w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestTokenExchange_InvalidNamespace(t *testing.T) {
+	_, _, r := newTokenExchangeRouter(t, true, stubCIVerifier{identity: ciIdentity("acme/terraform-aws-vpc", "refs/tags/v1.2.0")})
+	if w := doTokenExchange(r, "../etc"); w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+}
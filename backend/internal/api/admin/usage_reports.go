@@ -0,0 +1,295 @@
+// usage_reports.go implements usage reporting: consumers (a repository or a
+// workspace) POST the module and provider versions they use, typically from
+// CI with scripts/report-usage.py, and module and provider owners list who
+// consumes what. Usage that stops being reported is flagged stale, never
+// deleted.
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+const (
+	maxUsageConsumerLength = 512
+	maxUsageVersionLength  = 128
+	maxUsageReportEntries  = 1000
+)
+
+// UsageHandlers serves the usage report and consumer listing endpoints.
+type UsageHandlers struct {
+	repo       *repositories.UsageRepository
+	staleAfter time.Duration
+	now        func() time.Time
+}
+
+// NewUsageHandlers constructs a UsageHandlers. Usage last reported more than
+// staleAfterDays ago is flagged stale in the listings; 0 never flags it.
+func NewUsageHandlers(repo *repositories.UsageRepository, staleAfterDays int) *UsageHandlers {
+	return &UsageHandlers{
+		repo:       repo,
+		staleAfter: time.Duration(staleAfterDays) * 24 * time.Hour,
+		now:        time.Now,
+	}
+}
+
+// UsageReportRequest is the body of POST /api/v1/usage/report.
+type UsageReportRequest struct {
+	// Consumer identifies what uses the artifacts: a repository URL or a
+	// workspace name.
+	Consumer string `json:"consumer" binding:"required"`
+	// Modules are module addresses ([host/]namespace/name/system, optionally
+	// with a //subdirectory) and the versions in use.
+	Modules []UsageReportEntry `json:"modules"`
+	// Providers are provider addresses ([host/]namespace/type), as in
+	// .terraform.lock.hcl, and the versions in use.
+	Providers []UsageReportEntry `json:"providers"`
+}
+
+// UsageReportEntry is one artifact version in a usage report.
+type UsageReportEntry struct {
+	Source  string `json:"source"`
+	Version string `json:"version"`
+}
+
+// records validates the report and converts it to usage records, dropping
+// duplicates.
+func (r *UsageReportRequest) records() ([]models.UsageRecord, string) {
+	r.Consumer = strings.TrimSpace(r.Consumer)
+	if r.Consumer == "" || len(r.Consumer) > maxUsageConsumerLength {
+		return nil, "consumer is required and must be at most 512 characters"
+	}
+	if len(r.Modules)+len(r.Providers) == 0 {
+		return nil, "report at least one module or provider"
+	}
+	if len(r.Modules)+len(r.Providers) > maxUsageReportEntries {
+		return nil, "a report may list at most 1000 modules and providers"
+	}
+
+	seen := make(map[models.UsageRecord]bool)
+	records := make([]models.UsageRecord, 0, len(r.Modules)+len(r.Providers))
+	add := func(kind string, e UsageReportEntry, segments int) string {
+		rec, ok := parseUsageSource(kind, e.Source, segments)
+		if !ok {
+			return "invalid " + kind + " source " + e.Source
+		}
+		rec.Version = strings.TrimPrefix(strings.TrimSpace(e.Version), "v")
+		if rec.Version == "" || len(rec.Version) > maxUsageVersionLength || strings.ContainsAny(rec.Version, " \t/") {
+			return "invalid version for " + kind + " " + e.Source
+		}
+		if !seen[rec] {
+			seen[rec] = true
+			records = append(records, rec)
+		}
+		return ""
+	}
+	for _, m := range r.Modules {
+		if msg := add(models.UsageKindModule, m, 3); msg != "" {
+			return nil, msg
+		}
+	}
+	for _, p := range r.Providers {
+		if msg := add(models.UsageKindProvider, p, 2); msg != "" {
+			return nil, msg
+		}
+	}
+	return records, ""
+}
+
+// parseUsageSource parses a module (segments 3) or provider (segments 2)
+// address, with or without a registry hostname, which is dropped. A module's
+// //subdirectory is dropped too. Provider addresses are case-insensitive and
+// are lowercased.
+func parseUsageSource(kind, source string, segments int) (models.UsageRecord, bool) {
+	source = strings.TrimSpace(source)
+	if i := strings.Index(source, "//"); i >= 0 && kind == models.UsageKindModule {
+		source = source[:i]
+	}
+	if kind == models.UsageKindProvider {
+		source = strings.ToLower(source)
+	}
+	parts := strings.Split(source, "/")
+	if len(parts) == segments+1 {
+		parts = parts[1:]
+	}
+	if len(parts) != segments {
+		return models.UsageRecord{}, false
+	}
+	for _, p := range parts {
+		if p == "" || strings.ContainsAny(p, " \t?#:") {
+			return models.UsageRecord{}, false
+		}
+	}
+	rec := models.UsageRecord{Kind: kind, Namespace: parts[0], Name: parts[1]}
+	if kind == models.UsageKindModule {
+		rec.System = parts[2]
+	}
+	return rec, true
+}
+
+// ReportUsageHandler records a consumer's usage report.
+// @Summary      Report module and provider usage
+// @Description  Records the module and provider versions a consumer (a repository URL or workspace name) uses. Must be sent with an API key; usage is recorded under the key's organization. Addresses may include a registry hostname, which is dropped. Each consumer, address and version is stored once: repeating it refreshes its last-seen time. Versions missing from a report are kept and later flagged stale. scripts/report-usage.py builds a report from .terraform.lock.hcl and .terraform/modules/modules.json. Requires modules:read or providers:read scope.
+// @Tags         Usage
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        body  body  UsageReportRequest  true  "Usage report"
+// @Success      200  {object}  map[string]interface{}  "consumer, recorded"
+// @Failure      400  {object}  map[string]interface{}  "Invalid report"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Not authenticated with an API key"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/usage/report [post]
+func (h *UsageHandlers) ReportUsageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID := c.GetString("organization_id")
+		if c.GetString("auth_method") != "api_key" || orgID == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "usage reports must be sent with an API key"})
+			return
+		}
+
+		var req UsageReportRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		records, msg := req.records()
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		var apiKeyID *string
+		if id := c.GetString("api_key_id"); id != "" {
+			apiKeyID = &id
+		}
+		if err := h.repo.Record(c.Request.Context(), orgID, req.Consumer, apiKeyID, records); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record usage report"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"consumer": req.Consumer, "recorded": len(records)})
+	}
+}
+
+// UsageConsumersResponse lists the consumers of a module or provider.
+type UsageConsumersResponse struct {
+	Source string `json:"source"`
+	// StaleAfterDays is the usage_reports.stale_after_days setting the
+	// stale flags were computed with; 0 never flags usage stale.
+	StaleAfterDays int                  `json:"stale_after_days"`
+	Consumers      []UsageConsumerEntry `json:"consumers"`
+}
+
+// UsageConsumerEntry is one consumer and the versions it has reported.
+type UsageConsumerEntry struct {
+	OrganizationID string `json:"organization_id"`
+	Consumer       string `json:"consumer"`
+	// LastSeenAt is the consumer's most recent report of any version.
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Stale is set when every version the consumer reported is stale.
+	Stale    bool                   `json:"stale"`
+	Versions []UsageConsumerVersion `json:"versions"`
+}
+
+// UsageConsumerVersion is a version a consumer reported, most recently seen
+// first.
+type UsageConsumerVersion struct {
+	Version     string    `json:"version"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	// Stale is set when the consumer has not reported the version for longer
+	// than stale_after_days.
+	Stale bool `json:"stale"`
+}
+
+// ModuleConsumersHandler lists the consumers of a module.
+// @Summary      List module consumers
+// @Description  Returns the consumers that reported using the module through POST /api/v1/usage/report, with the versions each has pinned and when each was first and last seen. Usage not reported for longer than usage_reports.stale_after_days is flagged stale; a consumer is stale when all its versions are. Consumers are listed across organizations. Requires modules:read scope.
+// @Tags         Modules
+// @Security     Bearer
+// @Produce      json
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
+// @Success      200  {object}  UsageConsumersResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/modules/{namespace}/{name}/{system}/consumers [get]
+func (h *UsageHandlers) ModuleConsumersHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// The route shares its first wildcard with GET /admin/modules/:id,
+		// so gin names the namespace segment "id".
+		h.listConsumers(c, models.UsageKindModule, c.Param("id"), c.Param("name"), c.Param("system"))
+	}
+}
+
+// ProviderConsumersHandler lists the consumers of a provider.
+// @Summary      List provider consumers
+// @Description  Returns the consumers that reported using the provider through POST /api/v1/usage/report, with the versions each has locked and when each was first and last seen. Usage not reported for longer than usage_reports.stale_after_days is flagged stale; a consumer is stale when all its versions are. Consumers are listed across organizations. Requires providers:read scope.
+// @Tags         Providers
+// @Security     Bearer
+// @Produce      json
+// @Param        namespace  path  string  true  "Provider namespace"
+// @Param        type       path  string  true  "Provider type (e.g. aws, azurerm)"
+// @Success      200  {object}  UsageConsumersResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/providers/{namespace}/{type}/consumers [get]
+func (h *UsageHandlers) ProviderConsumersHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// The route shares its first wildcard with GET /admin/providers/:id.
+		h.listConsumers(c, models.UsageKindProvider, strings.ToLower(c.Param("id")), strings.ToLower(c.Param("type")), "")
+	}
+}
+
+func (h *UsageHandlers) listConsumers(c *gin.Context, kind, namespace, name, system string) {
+	rows, err := h.repo.ListConsumers(c.Request.Context(), kind, namespace, name, system)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list consumers"})
+		return
+	}
+
+	source := namespace + "/" + name
+	if system != "" {
+		source += "/" + system
+	}
+	resp := UsageConsumersResponse{
+		Source:         source,
+		StaleAfterDays: int(h.staleAfter / (24 * time.Hour)),
+		Consumers:      groupUsageConsumers(rows, h.now(), h.staleAfter),
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// groupUsageConsumers folds per-version rows, ordered by consumer, into one
+// entry per consumer, ordered by most recently seen.
+func groupUsageConsumers(rows []*models.UsageConsumer, now time.Time, staleAfter time.Duration) []UsageConsumerEntry {
+	entries := []UsageConsumerEntry{}
+	for _, row := range rows {
+		n := len(entries)
+		if n == 0 || entries[n-1].Consumer != row.Consumer || entries[n-1].OrganizationID != row.OrganizationID {
+			entries = append(entries, UsageConsumerEntry{OrganizationID: row.OrganizationID, Consumer: row.Consumer, Stale: true})
+			n++
+		}
+		e := &entries[n-1]
+		stale := staleAfter > 0 && now.Sub(row.LastSeenAt) > staleAfter
+		e.Versions = append(e.Versions, UsageConsumerVersion{
+			Version: row.Version, FirstSeenAt: row.FirstSeenAt, LastSeenAt: row.LastSeenAt, Stale: stale,
+		})
+		e.Stale = e.Stale && stale
+		if row.LastSeenAt.After(e.LastSeenAt) {
+			e.LastSeenAt = row.LastSeenAt
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastSeenAt.After(entries[j].LastSeenAt)
+	})
+	return entries
+}
@@ -0,0 +1,134 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+func newUsageRouter(t *testing.T, authMethod string) (sqlmock.Sqlmock, *gin.Engine, *UsageHandlers) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	h := NewUsageHandlers(repositories.NewUsageRepository(db), 30)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("auth_method", authMethod)
+		c.Set("organization_id", "org-1")
+		c.Set("api_key_id", "key-1")
+	})
+	r.POST("/usage/report", h.ReportUsageHandler())
+	r.GET("/admin/modules/:id/:name/:system/consumers", h.ModuleConsumersHandler())
+	r.GET("/admin/providers/:id/:type/consumers", h.ProviderConsumersHandler())
+	return mock, r, h
+}
+
+func postUsageReport(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/usage/report", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestReportUsage_RequiresAPIKey(t *testing.T) {
+	_, r, _ := newUsageRouter(t, "jwt")
+	w := postUsageReport(r, `{"consumer":"ws","providers":[{"source":"hashicorp/aws","version":"5.0.0"}]}`)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
+
+func TestReportUsage_Validation(t *testing.T) {
+	_, r, _ := newUsageRouter(t, "api_key")
+	for name, body := range map[string]string{
+		"no consumer":     `{"providers":[{"source":"hashicorp/aws","version":"5.0.0"}]}`,
+		"nothing":         `{"consumer":"ws"}`,
+		"git module":      `{"consumer":"ws","modules":[{"source":"git::https://example.com/vpc.git?ref=v1","version":"1"}]}`,
+		"short module":    `{"consumer":"ws","modules":[{"source":"acme/vpc","version":"1.0.0"}]}`,
+		"missing version": `{"consumer":"ws","providers":[{"source":"hashicorp/aws"}]}`,
+	} {
+		if w := postUsageReport(r, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400; body = %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestReportUsage_RecordsNormalizedAddresses(t *testing.T) {
+	mock, r, _ := newUsageRouter(t, "api_key")
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("INSERT INTO usage_consumers")
+	prep.ExpectExec().WithArgs("org-1", "github.com/acme/infra", "module", "acme", "vpc", "aws", "1.2.0", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs("org-1", "github.com/acme/infra", "provider", "hashicorp", "aws", "", "5.0.0", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// The module is reported twice (once through a subdirectory) and the
+	// provider with a hostname and mixed case; each is recorded once.
+	w := postUsageReport(r, `{"consumer":" github.com/acme/infra ",
+		"modules":[{"source":"registry.example.com/acme/vpc/aws","version":"1.2.0"},
+		           {"source":"acme/vpc/aws//modules/subnets","version":"1.2.0"}],
+		"providers":[{"source":"registry.terraform.io/HashiCorp/aws","version":"5.0.0"}]}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"recorded":2`) {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestModuleConsumers_GroupsAndFlagsStale(t *testing.T) {
+	mock, r, h := newUsageRouter(t, "jwt")
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	old := now.Add(-45 * 24 * time.Hour)
+	recent := now.Add(-24 * time.Hour)
+	mock.ExpectQuery("FROM usage_consumers").
+		WithArgs("module", "acme", "vpc", "aws").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "consumer", "version", "first_seen_at", "last_seen_at"}).
+			AddRow("org-1", "repo-a", "1.2.0", old, recent).
+			AddRow("org-1", "repo-a", "1.1.0", old, old).
+			AddRow("org-1", "repo-b", "1.0.0", old, old))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/modules/acme/vpc/aws/consumers", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp UsageConsumersResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Source != "acme/vpc/aws" || resp.StaleAfterDays != 30 || len(resp.Consumers) != 2 {
+		t.Fatalf("response = %+v", resp)
+	}
+	a, b := resp.Consumers[0], resp.Consumers[1]
+	if a.Consumer != "repo-a" || a.Stale || len(a.Versions) != 2 || a.Versions[0].Stale || !a.Versions[1].Stale || !a.LastSeenAt.Equal(recent) {
+		t.Errorf("repo-a = %+v", a)
+	}
+	if b.Consumer != "repo-b" || !b.Stale {
+		t.Errorf("repo-b = %+v", b)
+	}
+}
+
+func TestGroupUsageConsumers_NeverStaleWhenDisabled(t *testing.T) {
+	now := time.Now()
+	got := groupUsageConsumers([]*models.UsageConsumer{
+		{OrganizationID: "org-1", Consumer: "ws", Version: "1.0.0", LastSeenAt: now.Add(-365 * 24 * time.Hour)},
+	}, now, 0)
+	if len(got) != 1 || got[0].Stale || got[0].Versions[0].Stale {
+		t.Errorf("consumers = %+v, want none stale", got)
+	}
+}
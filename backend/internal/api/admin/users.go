@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
@@ -14,19 +17,21 @@ import (
 
 // UserHandlers handles user management endpoints
 type UserHandlers struct {
-	cfg      *config.Config
-	db       *sql.DB
-	userRepo *repositories.UserRepository
-	orgRepo  *repositories.OrganizationRepository
+	cfg       *config.Config
+	db        *sql.DB
+	userRepo  *repositories.UserRepository
+	orgRepo   *repositories.OrganizationRepository
+	loginRepo *repositories.UserLoginRepository
 }
 
 // NewUserHandlers creates a new UserHandlers instance
 func NewUserHandlers(cfg *config.Config, db *sql.DB) *UserHandlers {
 	return &UserHandlers{
-		cfg:      cfg,
-		db:       db,
-		userRepo: repositories.NewUserRepository(db),
-		orgRepo:  repositories.NewOrganizationRepository(db),
+		cfg:       cfg,
+		db:        db,
+		userRepo:  repositories.NewUserRepository(db),
+		orgRepo:   repositories.NewOrganizationRepository(db),
+		loginRepo: repositories.NewUserLoginRepository(db),
 	}
 }
 
@@ -36,8 +41,8 @@ func NewUserHandlers(cfg *config.Config, db *sql.DB) *UserHandlers {
 // @Security     Bearer
 // @Accept       json
 // @Produce      json
-// @Param        page      query  int  false  "Page number (default 1)"
-// @Param        per_page  query  int  false  "Items per page, max 100 (default 20)"
+// @Param        limit     query  int  false  "Items per page, max 100 (default 20); alias per_page"
+// @Param        offset    query  int  false  "Items to skip (default 0); alias page (1-based)"
 // @Success      200  {object}  admin.ListUsersResponse
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
@@ -46,21 +51,10 @@ func NewUserHandlers(cfg *config.Config, db *sql.DB) *UserHandlers {
 // GET /api/v1/users?page=1&per_page=20
 func (h *UserHandlers) ListUsersHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Parse pagination parameters
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
-
-		if page < 1 {
-			page = 1
-		}
-		if perPage < 1 || perPage > 100 {
-			perPage = 20
-		}
-
-		offset := (page - 1) * perPage
+		page := pagination.Parse(c, defaultListLimits)
 
 		// Get users with memberships (2 queries total, not N+1)
-		users, total, err := h.userRepo.ListUsersWithMemberships(c.Request.Context(), perPage, offset)
+		users, total, err := h.userRepo.ListUsersWithMemberships(c.Request.Context(), page.Limit, page.Offset)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to list users",
@@ -69,12 +63,9 @@ func (h *UserHandlers) ListUsersHandler() gin.HandlerFunc {
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"users": users,
-			"pagination": gin.H{
-				"page":     page,
-				"per_page": perPage,
-				"total":    total,
-			},
+			"users":      users,
+			"meta":       page.Meta(int64(total)),
+			"pagination": newPaginationMeta(page, int64(total)),
 		})
 	}
 }
@@ -334,45 +325,96 @@ func (h *UserHandlers) DeleteUserHandler() gin.HandlerFunc {
 }
 
 // @Summary      Search users
-// @Description  Search users by email or name. Requires users:read scope.
+// @Description  Search and filter users, with their organization memberships and last login. At least one of q or the filters is required. Requires users:read scope.
 // @Tags         Users
 // @Security     Bearer
 // @Produce      json
-// @Param        q         query  string  true   "Search query"
-// @Param        page      query  int     false  "Page number (default 1)"
-// @Param        per_page  query  int     false  "Items per page, max 100 (default 20)"
-// @Success      200  {object}  admin.ListUsersResponse
-// @Failure      400  {object}  map[string]interface{}  "Search query is required"
+// @Param        q                  query  string  false  "Substring of email or name"
+// @Param        organization_id    query  string  false  "Only members of this organization (UUID)"
+// @Param        role               query  string  false  "Only users holding this role template (in organization_id, when given)"
+// @Param        auth_provider      query  string  false  "Provider of the last login (oidc, azuread, saml, ldap, dev) or its issuer"
+// @Param        last_login_before  query  string  false  "Last login before this RFC3339 time; includes users who never logged in"
+// @Param        last_login_after   query  string  false  "Last login after this RFC3339 time"
+// @Param        active             query  bool    false  "true: users with at least one organization membership; false: users with none"
+// @Param        sort               query  string  false  "created_at (default) or last_login"
+// @Param        order              query  string  false  "asc or desc (default desc)"
+// @Param        limit              query  int     false  "Items per page, max 100 (default 20); alias per_page"
+// @Param        offset             query  int     false  "Items to skip (default 0); alias page (1-based)"
+// @Success      200  {object}  admin.SearchUsersResponse
+// @Failure      400  {object}  map[string]interface{}  "Missing search query or invalid filter"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/users/search [get]
-// SearchUsersHandler searches users by email or name
-// GET /api/v1/users/search?q=query&page=1&per_page=20
+// SearchUsersHandler searches users by email or name and filters them by
+// membership, role and last login
+// GET /api/v1/users/search?q=query&organization_id=...&sort=last_login&page=1&per_page=20
 func (h *UserHandlers) SearchUsersHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		query := c.Query("q")
-		if query == "" {
+		filter := repositories.UserSearchFilter{
+			Query:        c.Query("q"),
+			Role:         c.Query("role"),
+			AuthProvider: c.Query("auth_provider"),
+		}
+		if v := c.Query("organization_id"); v != "" {
+			if _, err := uuid.Parse(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "organization_id must be a UUID"})
+				return
+			}
+			filter.OrganizationID = v
+		}
+		if v := c.Query("last_login_before"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "last_login_before must be an RFC3339 timestamp (e.g. 2006-01-02T15:04:05Z)"})
+				return
+			}
+			filter.LastLoginBefore = &t
+		}
+		if v := c.Query("last_login_after"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "last_login_after must be an RFC3339 timestamp (e.g. 2006-01-02T15:04:05Z)"})
+				return
+			}
+			filter.LastLoginAfter = &t
+		}
+		if v := c.Query("active"); v != "" {
+			active, err := strconv.ParseBool(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "active must be true or false"})
+				return
+			}
+			filter.Active = &active
+		}
+		if filter == (repositories.UserSearchFilter{}) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Search query is required",
 			})
 			return
 		}
 
-		// Parse pagination
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
-
-		if page < 1 {
-			page = 1
+		switch sort := c.DefaultQuery("sort", "created_at"); sort {
+		case "created_at", "last_login":
+			filter.Sort = sort
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be created_at or last_login"})
+			return
 		}
-		if perPage < 1 || perPage > 100 {
-			perPage = 20
+		switch c.DefaultQuery("order", "desc") {
+		case "desc":
+			filter.Desc = true
+		case "asc":
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+			return
 		}
 
-		offset := (page - 1) * perPage
+		page := pagination.Parse(c, defaultListLimits)
+		filter.Limit = page.Limit
+		filter.Offset = page.Offset
 
-		// Search users with memberships
-		users, err := h.userRepo.SearchWithMemberships(c.Request.Context(), query, perPage, offset)
+		// Search users with memberships and last logins
+		users, total, err := h.loginRepo.SearchUsers(c.Request.Context(), filter)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to search users",
@@ -381,11 +423,9 @@ func (h *UserHandlers) SearchUsersHandler() gin.HandlerFunc {
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"users": users,
-			"pagination": gin.H{
-				"page":     page,
-				"per_page": perPage,
-			},
+			"users":      users,
+			"meta":       page.Meta(int64(total)),
+			"pagination": newPaginationMeta(page, int64(total)),
 		})
 	}
 }
@@ -48,6 +48,14 @@ func sampleUserRow() *sqlmock.Rows {
 		AddRow("user-1", "alice@example.com", "Alice", nil, time.Now(), time.Now())
 }
 
+// userSearchSQLCols are the columns returned by UserLoginRepository.SearchUsers.
+var userSearchSQLCols = append(append([]string{}, userSQLCols...), "last_login_at", "auth_provider", "auth_issuer")
+
+func sampleUserSearchRow() *sqlmock.Rows {
+	return sqlmock.NewRows(userSearchSQLCols).
+		AddRow("user-1", "alice@example.com", "Alice", nil, time.Now(), time.Now(), time.Now(), "oidc", "https://idp.example.com")
+}
+
 func emptyUserRows() *sqlmock.Rows {
 	return sqlmock.NewRows(userSQLCols)
 }
@@ -436,9 +444,11 @@ func TestSearchUsersHandler_MissingQuery(t *testing.T) {
 func TestSearchUsersHandler_Success(t *testing.T) {
 	mock, r := newUserRouter(t)
 
-	mock.ExpectQuery("SELECT").
-		WillReturnRows(sampleUserRow())
-	// loadMembershipsForUsers bulk query
+	mock.ExpectQuery("SELECT COUNT").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("LEFT JOIN user_logins").
+		WillReturnRows(sampleUserSearchRow())
+	// bulk memberships query
 	mock.ExpectQuery("ANY").
 		WillReturnRows(emptyBulkMembershipRows())
 
@@ -467,6 +477,60 @@ func TestSearchUsersHandler_DBError(t *testing.T) {
 	}
 }
 
+func TestSearchUsersHandler_FiltersWithoutQuery(t *testing.T) {
+	mock, r := newUserRouter(t)
+	orgID := "00000000-0000-0000-0000-000000000001"
+
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs(orgID, "admin", "saml", false).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`ORDER BY ul.last_login_at ASC NULLS FIRST`).
+		WithArgs(orgID, "admin", "saml", false, 20, 0).
+		WillReturnRows(sampleUserSearchRow())
+	mock.ExpectQuery("ANY").
+		WillReturnRows(emptyBulkMembershipRows())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET",
+		"/users/search?organization_id="+orgID+"&role=admin&auth_provider=saml&active=false&sort=last_login&order=asc", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	resp := getJSON(w)
+	users, _ := resp["users"].([]interface{})
+	if len(users) != 1 {
+		t.Fatalf("users = %v, want 1", resp["users"])
+	}
+	if u := users[0].(map[string]interface{}); u["auth_provider"] != "oidc" || u["last_login_at"] == nil || u["memberships"] == nil {
+		t.Errorf("user = %v, want last login and memberships", u)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSearchUsersHandler_InvalidFilters(t *testing.T) {
+	for _, query := range []string{
+		"organization_id=not-a-uuid",
+		"last_login_before=yesterday",
+		"last_login_after=2026-01-01",
+		"active=maybe",
+		"q=alice&sort=email",
+		"q=alice&order=sideways",
+		"sort=last_login",
+	} {
+		t.Run(query, func(t *testing.T) {
+			_, r := newUserRouter(t)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/users/search?"+query, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", w.Code)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // GetCurrentUserMembershipsHandler
 // ---------------------------------------------------------------------------
@@ -787,13 +851,16 @@ func TestGetUserMembershipsHandler_DBErrorOnMemberships(t *testing.T) {
 func TestSearchUsersHandler_PaginationDefaults(t *testing.T) {
 	mock, r := newUserRouter(t)
 
-	mock.ExpectQuery("SELECT").
-		WillReturnRows(sampleUserRow())
-	// loadMembershipsForUsers bulk query
+	mock.ExpectQuery("SELECT COUNT").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("LEFT JOIN user_logins").
+		WithArgs("%alice%", 100, 0).
+		WillReturnRows(sampleUserSearchRow())
+	// bulk memberships query
 	mock.ExpectQuery("ANY").
 		WillReturnRows(emptyBulkMembershipRows())
 
-	// page < 1 and perPage > 100 → clamped to defaults
+	// page < 1 → first page; perPage > 100 → clamped to the cap
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("GET", "/users/search?q=alice&page=-5&per_page=999", nil))
 
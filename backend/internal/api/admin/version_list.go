@@ -0,0 +1,91 @@
+// version_list.go parses the query string of the filtered admin module and
+// provider version listings.
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hashicorp/go-version"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// parseVersionListQuery reads the filter, sort and pagination parameters of
+// an admin version listing. allowArchived enables the module-only archived
+// filter. Errors are suitable for a 400 response.
+func parseVersionListQuery(c *gin.Context, allowArchived bool) (repositories.VersionListFilter, PaginationMeta, error) {
+	var f repositories.VersionListFilter
+
+	if c.Query("yanked") != "" {
+		return f, PaginationMeta{}, errors.New("yanked is not supported: versions are deprecated or archived, never yanked")
+	}
+
+	var err error
+	if f.Deprecated, err = optionalBoolQuery(c, "deprecated"); err != nil {
+		return f, PaginationMeta{}, err
+	}
+	if allowArchived {
+		if f.Archived, err = optionalBoolQuery(c, "archived"); err != nil {
+			return f, PaginationMeta{}, err
+		}
+	}
+
+	if s := c.Query("published_after"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, s); err != nil {
+				return f, PaginationMeta{}, errors.New("published_after must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			}
+		}
+		f.PublishedAfter = &t
+	}
+	f.PublishedBy = c.Query("published_by")
+
+	if s := c.Query("constraint"); s != "" {
+		if f.Constraint, err = version.NewConstraint(s); err != nil {
+			return f, PaginationMeta{}, fmt.Errorf("invalid version constraint: %w", err)
+		}
+	}
+
+	switch f.Sort = c.DefaultQuery("sort", repositories.VersionSortSemver); f.Sort {
+	case repositories.VersionSortSemver, repositories.VersionSortPublished:
+	default:
+		return f, PaginationMeta{}, fmt.Errorf("sort must be %q or %q", repositories.VersionSortSemver, repositories.VersionSortPublished)
+	}
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		f.Ascending = true
+	case "desc":
+	default:
+		return f, PaginationMeta{}, errors.New(`order must be "asc" or "desc"`)
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+	f.Limit = perPage
+	f.Offset = (page - 1) * perPage
+
+	return f, PaginationMeta{Page: page, PerPage: perPage}, nil
+}
+
+// optionalBoolQuery parses an optional true/false query parameter.
+func optionalBoolQuery(c *gin.Context, name string) (*bool, error) {
+	s := c.Query(name)
+	if s == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false", name)
+	}
+	return &b, nil
+}
@@ -0,0 +1,76 @@
+// Package artifacthead answers HEAD requests on artifact download endpoints
+// from storage metadata.
+//
+// A HEAD on a download endpoint runs the same lookups and access checks as
+// the GET, then describes the archive the GET would lead to — its size,
+// checksum and modification time — without reading the object, handing out a
+// download URL, or counting a download. Clients and caches use it to check
+// an artifact before fetching it.
+package artifacthead
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// IsHead reports whether the request is a HEAD request.
+func IsHead(c *gin.Context) bool {
+	return c.Request.Method == http.MethodHead
+}
+
+// Mode returns the configured HEAD mode, defaulting to metadata when cfg is
+// nil or the mode is unset.
+func Mode(cfg *config.Config) string {
+	if cfg == nil || cfg.DownloadHead.Mode == "" {
+		return config.DownloadHeadMetadata
+	}
+	return cfg.DownloadHead.Mode
+}
+
+// Respond answers a HEAD request for the object at key with a zero-byte
+// 200. shasum is the artifact's hex SHA-256 as recorded in the database; when
+// empty the checksum from storage metadata is used for the ETag instead.
+//
+// In metadata mode the response carries Content-Length, ETag, Last-Modified
+// and Accept-Ranges. In existence mode only the object's presence is checked,
+// so Content-Length and Last-Modified are omitted and the ETag comes from
+// shasum alone. A missing object answers 404 and a storage failure 500, both
+// without a body.
+func Respond(c *gin.Context, store storage.Storage, mode, key, shasum string) {
+	ctx := c.Request.Context()
+	exists, err := store.Exists(ctx, key)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	h := c.Writer.Header()
+	if mode != config.DownloadHeadExistence {
+		meta, err := store.GetMetadata(ctx, key)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		h.Set("Content-Length", strconv.FormatInt(meta.Size, 10))
+		if !meta.LastModified.IsZero() {
+			h.Set("Last-Modified", meta.LastModified.UTC().Format(http.TimeFormat))
+		}
+		if shasum == "" {
+			shasum = meta.Checksum
+		}
+	}
+	if shasum != "" {
+		h.Set("ETag", strconv.Quote(shasum))
+	}
+	h.Set("Accept-Ranges", "bytes")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+}
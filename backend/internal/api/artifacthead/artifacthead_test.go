@@ -0,0 +1,92 @@
+package artifacthead
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+type fakeStore struct {
+	exists    bool
+	existsErr error
+	meta      *storage.FileMetadata
+	metaErr   error
+}
+
+func (f *fakeStore) Upload(context.Context, string, io.Reader, int64) (*storage.UploadResult, error) {
+	return nil, errors.New("unexpected Upload")
+}
+func (f *fakeStore) Download(context.Context, string) (io.ReadCloser, error) {
+	return nil, errors.New("unexpected Download")
+}
+func (f *fakeStore) Delete(context.Context, string) error { return errors.New("unexpected Delete") }
+func (f *fakeStore) GetURL(context.Context, string, time.Duration) (string, error) {
+	return "", errors.New("unexpected GetURL")
+}
+func (f *fakeStore) Exists(context.Context, string) (bool, error) { return f.exists, f.existsErr }
+func (f *fakeStore) GetMetadata(context.Context, string) (*storage.FileMetadata, error) {
+	return f.meta, f.metaErr
+}
+
+func head(store storage.Storage, mode, shasum string) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.HEAD("/a", func(c *gin.Context) { Respond(c, store, mode, "a.zip", shasum) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/a", nil))
+	return w
+}
+
+func TestRespond(t *testing.T) {
+	meta := &storage.FileMetadata{Size: 42, Checksum: "fromstorage", LastModified: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		name       string
+		store      *fakeStore
+		mode       string
+		shasum     string
+		wantStatus int
+		wantLength string
+		wantETag   string
+	}{
+		{"database shasum wins", &fakeStore{exists: true, meta: meta}, config.DownloadHeadMetadata, "fromdb", http.StatusOK, "42", `"fromdb"`},
+		{"storage checksum fallback", &fakeStore{exists: true, meta: meta}, config.DownloadHeadMetadata, "", http.StatusOK, "42", `"fromstorage"`},
+		{"existence skips metadata", &fakeStore{exists: true, metaErr: errors.New("unexpected")}, config.DownloadHeadExistence, "fromdb", http.StatusOK, "", `"fromdb"`},
+		{"missing object", &fakeStore{}, config.DownloadHeadMetadata, "fromdb", http.StatusNotFound, "", ""},
+		{"exists error", &fakeStore{existsErr: errors.New("boom")}, config.DownloadHeadMetadata, "", http.StatusInternalServerError, "", ""},
+		{"metadata error", &fakeStore{exists: true, metaErr: errors.New("boom")}, config.DownloadHeadMetadata, "", http.StatusInternalServerError, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := head(tt.store, tt.mode, tt.shasum)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Body.Len() != 0 {
+				t.Errorf("body = %q, want empty", w.Body.String())
+			}
+			if got := w.Header().Get("Content-Length"); got != tt.wantLength {
+				t.Errorf("Content-Length = %q, want %q", got, tt.wantLength)
+			}
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestMode(t *testing.T) {
+	if got := Mode(nil); got != config.DownloadHeadMetadata {
+		t.Errorf("Mode(nil) = %q, want metadata", got)
+	}
+	cfg := &config.Config{DownloadHead: config.DownloadHeadConfig{Mode: config.DownloadHeadExistence}}
+	if got := Mode(cfg); got != config.DownloadHeadExistence {
+		t.Errorf("Mode() = %q, want existence", got)
+	}
+}
@@ -0,0 +1,182 @@
+// Package badges serves SVG shields for module READMEs: the latest release
+// and the download count of a module, rendered in-process (no external badge
+// service is called) and cached by clients and proxies through ETag.
+//
+// A badge shows only what the caller could see through the module registry
+// protocol: callers whose organization enforces approved_only see their
+// latest approved release. A module the caller cannot see, including one that
+// does not exist, gets a generic "private" badge with the same status and
+// headers as any other, so badge URLs cannot be used to probe for modules.
+package badges
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// Cache lifetimes. Anonymous badges, which is what README image proxies
+// fetch, may be cached by shared caches; badges computed for an
+// authenticated caller only by the caller.
+const (
+	publicMaxAge  = time.Hour
+	privateMaxAge = 5 * time.Minute
+)
+
+// The subsets of the organization, module and module approval repositories
+// the handlers need.
+type (
+	organizationSource interface {
+		GetDefaultOrganization(ctx context.Context) (*models.Organization, error)
+	}
+	moduleSource interface {
+		GetModule(ctx context.Context, orgID, namespace, name, system string) (*models.Module, error)
+		GetBadgeStats(ctx context.Context, moduleID string, approvedBy []string) (*string, int64, error)
+	}
+	approvalSource interface {
+		ApprovedOnlyOrganizations(ctx context.Context, orgIDs []string) ([]string, error)
+	}
+)
+
+// Handlers serves the badge endpoints.
+type Handlers struct {
+	orgs      organizationSource
+	modules   moduleSource
+	approvals approvalSource
+}
+
+// NewHandlers creates the badge handlers.
+func NewHandlers(db *sql.DB) *Handlers {
+	return &Handlers{
+		orgs:      repositories.NewOrganizationRepository(db),
+		modules:   repositories.NewModuleRepository(db),
+		approvals: repositories.NewModuleApprovalRepository(db),
+	}
+}
+
+// @Summary      Module version badge
+// @Description  SVG badge showing the module's latest release: the highest version that is neither archived nor a pre-release. Callers whose organization enforces approved_only see their latest approved release. A module the caller cannot see, or that does not exist, renders a generic "private" badge with status 200. Badges carry an ETag and support If-None-Match; anonymous badges are publicly cacheable for an hour.
+// @Tags         Modules
+// @Produce      image/svg+xml
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
+// @Success      200  {string}  string  "SVG badge"
+// @Success      304  "Not modified"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/badges/modules/{namespace}/{name}/{system}/version.svg [get]
+// ModuleVersion serves GET /api/v1/badges/modules/:namespace/:name/:system/version.svg.
+func (h *Handlers) ModuleVersion() gin.HandlerFunc {
+	return h.moduleBadge("version", func(latest *string, _ int64) badge {
+		if latest == nil {
+			return badge{label: "version", message: "no release", color: colorInactive}
+		}
+		return badge{label: "version", message: "v" + strings.TrimPrefix(*latest, "v"), color: colorVersion}
+	})
+}
+
+// @Summary      Module downloads badge
+// @Description  SVG badge showing the module's total downloads across all versions, abbreviated (e.g. 1.2k). Visibility, the "private" badge and caching are as for the version badge.
+// @Tags         Modules
+// @Produce      image/svg+xml
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
+// @Success      200  {string}  string  "SVG badge"
+// @Success      304  "Not modified"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/badges/modules/{namespace}/{name}/{system}/downloads.svg [get]
+// ModuleDownloads serves GET /api/v1/badges/modules/:namespace/:name/:system/downloads.svg.
+func (h *Handlers) ModuleDownloads() gin.HandlerFunc {
+	return h.moduleBadge("downloads", func(_ *string, downloads int64) badge {
+		return badge{label: "downloads", message: formatCount(downloads), color: colorDownload}
+	})
+}
+
+// moduleBadge looks up the module named by the path and serves the badge
+// build makes from its stats, or the private badge labelled label.
+func (h *Handlers) moduleBadge(label string, build func(latest *string, downloads int64) badge) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		org, err := h.orgs.GetDefaultOrganization(ctx)
+		if err != nil || org == nil {
+			slog.Error("failed to resolve the default organization for a badge", "path", c.Request.URL.Path, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+			return
+		}
+		module, err := h.modules.GetModule(ctx, org.ID, c.Param("namespace"), c.Param("name"), c.Param("system"))
+		if err != nil {
+			slog.Error("failed to get module for a badge", "path", c.Request.URL.Path, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render badge"})
+			return
+		}
+		if module == nil {
+			serve(c, badge{label: label, message: "private", color: colorInactive})
+			return
+		}
+
+		// Callers whose organization enforces approved_only see only the
+		// versions one of those organizations has approved, as on
+		// GET /v1/modules/.../versions.
+		var enforcing []string
+		if orgIDs, _ := middleware.OrganizationIDsFromContext(c); len(orgIDs) > 0 {
+			if enforcing, err = h.approvals.ApprovedOnlyOrganizations(ctx, orgIDs); err != nil {
+				slog.Error("failed to resolve organization module policy for a badge", "path", c.Request.URL.Path, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render badge"})
+				return
+			}
+		}
+		latest, downloads, err := h.modules.GetBadgeStats(ctx, module.ID, enforcing)
+		if err != nil {
+			slog.Error("failed to get module badge stats", "path", c.Request.URL.Path, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render badge"})
+			return
+		}
+		serve(c, build(latest, downloads))
+	}
+}
+
+// serve renders b and answers the request with it, or with a 304 when the
+// caller already has it.
+func serve(c *gin.Context, b badge) {
+	body := b.render()
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	// The badge depends on the caller's organizations, so a badge computed
+	// for credentials must not be served to anyone else.
+	if _, authenticated := middleware.OrganizationIDsFromContext(c); authenticated {
+		c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(privateMaxAge.Seconds())))
+	} else {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(publicMaxAge.Seconds())))
+	}
+	c.Header("Vary", "Authorization, Cookie")
+	c.Header("ETag", etag)
+	if etagMatches(c.Request.Header.Get("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, ContentType, body)
+}
+
+// etagMatches reports whether an If-None-Match header value lists etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package badges
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+type fakeOrgs struct{}
+
+func (fakeOrgs) GetDefaultOrganization(context.Context) (*models.Organization, error) {
+	return &models.Organization{ID: "org-default"}, nil
+}
+
+// fakeModules knows acme/vpc/aws only. Its latest release is 2.1.0, or 2.0.0
+// for callers restricted to approved versions.
+type fakeModules struct {
+	err        error
+	approvedBy [][]string
+}
+
+func (f *fakeModules) GetModule(_ context.Context, _, namespace, name, system string) (*models.Module, error) {
+	if namespace == "acme" && name == "vpc" && system == "aws" {
+		return &models.Module{ID: "mod-1"}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeModules) GetBadgeStats(_ context.Context, _ string, approvedBy []string) (*string, int64, error) {
+	f.approvedBy = append(f.approvedBy, approvedBy)
+	latest := "2.1.0"
+	if len(approvedBy) > 0 {
+		latest = "2.0.0"
+	}
+	return &latest, 1234, f.err
+}
+
+type fakeApprovals struct{ enforcing []string }
+
+func (f fakeApprovals) ApprovedOnlyOrganizations(context.Context, []string) ([]string, error) {
+	return f.enforcing, nil
+}
+
+func newTestRouter(mods *fakeModules, orgIDs []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &Handlers{orgs: fakeOrgs{}, modules: mods, approvals: fakeApprovals{enforcing: []string{"org-strict"}}}
+	r := gin.New()
+	if orgIDs != nil {
+		r.Use(func(c *gin.Context) { c.Set(middleware.OrganizationIDsContextKey, orgIDs) })
+	}
+	g := r.Group("/api/v1/badges/modules/:namespace/:name/:system")
+	g.GET("/version.svg", h.ModuleVersion())
+	g.GET("/downloads.svg", h.ModuleDownloads())
+	return r
+}
+
+func get(r http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestModuleVersion(t *testing.T) {
+	r := newTestRouter(&fakeModules{}, nil)
+	w := get(r, "/api/v1/badges/modules/acme/vpc/aws/version.svg")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("status = %d, content type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if text := parseSVG(t, w.Body.Bytes()); !strings.Contains(text, "v2.1.0") {
+		t.Errorf("badge text = %q, want v2.1.0", text)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=3600" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if w := get(r, "/api/v1/badges/modules/acme/vpc/aws/version.svg", "If-None-Match", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("conditional GET: status = %d, body %d bytes; want 304 and no body", w.Code, w.Body.Len())
+	}
+	if w := get(r, "/api/v1/badges/modules/acme/vpc/aws/version.svg", "If-None-Match", `"stale"`); w.Code != http.StatusOK {
+		t.Errorf("stale ETag: status = %d, want 200", w.Code)
+	}
+}
+
+func TestModuleDownloads(t *testing.T) {
+	w := get(newTestRouter(&fakeModules{}, nil), "/api/v1/badges/modules/acme/vpc/aws/downloads.svg")
+	if text := parseSVG(t, w.Body.Bytes()); !strings.Contains(text, "1.2k") {
+		t.Errorf("badge text = %q, want 1.2k", text)
+	}
+}
+
+func TestPrivateBadgeLeaksNothing(t *testing.T) {
+	r := newTestRouter(&fakeModules{}, nil)
+	hidden := get(r, "/api/v1/badges/modules/acme/secret/aws/version.svg")
+	if hidden.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 like any badge", hidden.Code)
+	}
+	text := parseSVG(t, hidden.Body.Bytes())
+	if !strings.Contains(text, "private") || strings.Contains(text, "v2") {
+		t.Errorf("badge text = %q, want the generic private badge", text)
+	}
+	other := get(r, "/api/v1/badges/modules/other/thing/gcp/version.svg")
+	if other.Body.String() != hidden.Body.String() || other.Header().Get("ETag") != hidden.Header().Get("ETag") {
+		t.Error("private badges differ between modules")
+	}
+}
+
+func TestAuthenticatedCallerSeesApprovedRelease(t *testing.T) {
+	mods := &fakeModules{}
+	w := get(newTestRouter(mods, []string{"org-strict"}), "/api/v1/badges/modules/acme/vpc/aws/version.svg")
+	if text := parseSVG(t, w.Body.Bytes()); !strings.Contains(text, "v2.0.0") {
+		t.Errorf("badge text = %q, want the approved v2.0.0", text)
+	}
+	if len(mods.approvedBy) != 1 || len(mods.approvedBy[0]) != 1 || mods.approvedBy[0][0] != "org-strict" {
+		t.Errorf("approvedBy = %v, want [org-strict]", mods.approvedBy)
+	}
+	if cc := w.Header().Get("Cache-Control"); !strings.HasPrefix(cc, "private") {
+		t.Errorf("Cache-Control = %q, want private for an authenticated caller", cc)
+	}
+}
+
+func TestModuleBadge_QueryError(t *testing.T) {
+	w := get(newTestRouter(&fakeModules{err: errors.New("db down")}, nil), "/api/v1/badges/modules/acme/vpc/aws/version.svg")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
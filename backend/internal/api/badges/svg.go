@@ -0,0 +1,126 @@
+// svg.go renders the flat two-part shields the badge endpoints serve. Label
+// and message text is XML-escaped and length-capped, and colors come from a
+// fixed palette, so nothing a module author controls can add markup.
+package badges
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ContentType is the media type of a rendered badge.
+const ContentType = "image/svg+xml; charset=utf-8"
+
+// maxTextRunes caps the label and the message; longer text is cut short and
+// ends in an ellipsis.
+const maxTextRunes = 48
+
+// color is a badge message background from the fixed palette below.
+type color string
+
+const (
+	colorLabel    color = "#555"
+	colorVersion  color = "#007ec6"
+	colorDownload color = "#4c1"
+	colorInactive color = "#9f9f9f"
+)
+
+// badge is one shield: a grey label on the left and a colored message on the
+// right.
+type badge struct {
+	label   string
+	message string
+	color   color
+}
+
+// textPadding is the horizontal space around each half's text.
+const textPadding = 10
+
+// render returns the badge as an SVG document.
+func (b badge) render() []byte {
+	label, message := truncate(b.label), truncate(b.message)
+	lw := textWidth(label) + textPadding
+	mw := textWidth(message) + textPadding
+	w := lw + mw
+	lx, mx := strconv.FormatFloat(float64(lw)/2, 'f', 1, 64), strconv.FormatFloat(float64(lw)+float64(mw)/2, 'f', 1, 64)
+	l, m := escape(label), escape(message)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, w, l, m)
+	fmt.Fprintf(&buf, `<title>%s: %s</title>`, l, m)
+	buf.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&buf, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, w)
+	fmt.Fprintf(&buf, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="%s"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		lw, colorLabel, lw, mw, b.color, w)
+	buf.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	for _, t := range []struct{ x, text string }{{lx, l}, {mx, m}} {
+		fmt.Fprintf(&buf, `<text x="%s" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%s" y="14">%s</text>`, t.x, t.text, t.x, t.text)
+	}
+	buf.WriteString(`</g></svg>`)
+	return buf.Bytes()
+}
+
+// escape XML-escapes s for use in text and attribute values. Characters XML
+// does not allow are replaced with U+FFFD.
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s)) // writes to a bytes.Buffer never fail
+	return buf.String()
+}
+
+// truncate caps s at maxTextRunes.
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= maxTextRunes {
+		return s
+	}
+	return string(r[:maxTextRunes-1]) + "…"
+}
+
+// textWidth approximates the rendered width of s in 11px Verdana, in pixels.
+// Badge readers only need the halves to fit their text, not exact metrics.
+func textWidth(s string) int {
+	var w float64
+	for _, r := range s {
+		switch {
+		case r == 'i' || r == 'j' || r == 'l' || r == '.' || r == ',' || r == ':' || r == ';' || r == '\'' || r == '|' || r == '!':
+			w += 3.5
+		case r == 'f' || r == 'r' || r == 't' || r == 'I' || r == '(' || r == ')' || r == '[' || r == ']' || r == ' ' || r == '-':
+			w += 5
+		case r == 'm' || r == 'w' || r == 'M' || r == 'W' || r == '%':
+			w += 10
+		case r >= 'A' && r <= 'Z':
+			w += 7.5
+		default:
+			w += 7
+		}
+	}
+	return int(math.Ceil(w))
+}
+
+// formatCount abbreviates a download count the way shields do: 950, 1.2k,
+// 12k, 3.4M.
+func formatCount(n int64) string {
+	if n < 1000 {
+		return strconv.FormatInt(n, 10)
+	}
+	f := float64(n)
+	units := []string{"k", "M", "G", "T"}
+	for i, unit := range units {
+		f /= 1000
+		if f < 999.5 || i == len(units)-1 {
+			if f < 9.95 {
+				s := strconv.FormatFloat(f, 'f', 1, 64)
+				if s[len(s)-2:] == ".0" {
+					s = s[:len(s)-2]
+				}
+				return s + unit
+			}
+			return strconv.FormatFloat(math.Round(f), 'f', 0, 64) + unit
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
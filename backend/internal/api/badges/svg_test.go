@@ -0,0 +1,106 @@
+package badges
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// parseSVG checks that body is well-formed XML and returns its text content.
+func parseSVG(t *testing.T, body []byte) string {
+	t.Helper()
+	text, _ := parseSVGElements(t, body)
+	return text
+}
+
+// parseSVGElements is parseSVG that also counts the elements.
+func parseSVGElements(t *testing.T, body []byte) (string, int) {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader(string(body)))
+	var text strings.Builder
+	elements := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatalf("invalid SVG: %v\n%s", err, body)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			elements++
+		case xml.CharData:
+			text.Write(tok)
+		}
+	}
+	if elements == 0 {
+		t.Fatalf("no elements in %s", body)
+	}
+	return text.String(), elements
+}
+
+func TestRender_EscapesMarkup(t *testing.T) {
+	_, want := parseSVGElements(t, badge{label: "version", message: "v1.0.0", color: colorVersion}.render())
+	for _, hostile := range []string{
+		`<script>alert(1)</script>`,
+		`"><image href="x" onerror="alert(1)"/>`,
+		`a & b ' c`,
+		"\x00\x1b]bell\x07",
+	} {
+		body := badge{label: hostile, message: hostile, color: colorVersion}.render()
+		// Well-formed XML with the same elements as a benign badge, whose
+		// text is the (sanitized) input, proves nothing escaped into markup.
+		text, elements := parseSVGElements(t, body)
+		if elements != want {
+			t.Errorf("%q: %d elements, want %d: %s", hostile, elements, want, body)
+		}
+		if !strings.Contains(text, strings.Map(func(r rune) rune {
+			if r < 0x20 {
+				return '�'
+			}
+			return r
+		}, hostile)) {
+			t.Errorf("%q: text content = %q", hostile, text)
+		}
+	}
+}
+
+func TestRender_TruncatesLongText(t *testing.T) {
+	long := strings.Repeat("x", 500)
+	body := badge{label: "version", message: long, color: colorVersion}.render()
+	text := parseSVG(t, body)
+	if strings.Contains(text, long[:maxTextRunes]) || !strings.Contains(text, "…") {
+		t.Errorf("message not truncated: %q", text)
+	}
+}
+
+func TestRender_WidthFitsText(t *testing.T) {
+	short := badge{label: "version", message: "v1.0.0", color: colorVersion}.render()
+	wide := badge{label: "version", message: "v1.0.0-WWWWWWWW", color: colorVersion}.render()
+	if len(short) == 0 || string(short) == string(wide) {
+		t.Fatal("badges should differ")
+	}
+	if textWidth("WWWW") <= textWidth("iiii") {
+		t.Errorf("textWidth(WWWW) = %d, want wider than textWidth(iiii) = %d", textWidth("WWWW"), textWidth("iiii"))
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:             "0",
+		999:           "999",
+		1000:          "1k",
+		1234:          "1.2k",
+		12345:         "12k",
+		999_499:       "999k",
+		999_999:       "1M",
+		3_400_000:     "3.4M",
+		7_000_000_000: "7G",
+	} {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
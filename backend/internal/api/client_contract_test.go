@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/pkg/client"
+)
+
+// Contract tests for pkg/client: the client is run against the real router,
+// with sqlmock standing in for the database, so a change to a route or a
+// response shape that the client depends on fails plain `go test ./...`.
+// internal/integration covers the same ground against Postgres and object
+// storage but needs Docker and the integration build tag.
+
+// newClientContract serves the real router over HTTP and returns a client for
+// it together with the mock behind the router's repositories. The router's
+// background jobs share the database, so expect registers every expectation
+// before they start, and expectations are unordered; the patterns are
+// specific enough that the jobs' queries never match them.
+func newClientContract(t *testing.T, expect func(sqlmock.Sqlmock)) (*client.Client, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	mock.MatchExpectationsInOrder(false)
+	expect(mock)
+	t.Setenv("ENCRYPTION_KEY", "9f86d081884c7d659a2feaa0c55ad015")
+
+	cfg := &config.Config{}
+	cfg.Storage.DefaultBackend = "local"
+	cfg.Storage.Local.BasePath = t.TempDir()
+	router, bg, err := NewRouter(cfg, db, db)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	t.Cleanup(bg.Shutdown)
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	c, err := client.New(srv.URL)
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+	return c, mock
+}
+
+var contractTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func expectDefaultOrganization(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name", "idp_type", "idp_name", "created_at", "updated_at"}).
+			AddRow("org-1", "default", "Default", nil, nil, contractTime, contractTime))
+}
+
+var contractModuleCols = []string{
+	"id", "organization_id", "namespace", "name", "system", "description", "source",
+	"created_by", "created_at", "updated_at", "created_by_name",
+	"deprecated", "deprecated_at", "deprecation_message", "successor_module_id",
+}
+
+func TestClientContract_ModuleVersions(t *testing.T) {
+	c, mock := newClientContract(t, func(mock sqlmock.Sqlmock) {
+		expectDefaultOrganization(mock)
+		mock.ExpectQuery("SELECT.*FROM modules m.*WHERE m.organization_id = \\$1 AND m.namespace").
+			WillReturnRows(sqlmock.NewRows(contractModuleCols).
+				AddRow("mod-1", "org-1", "hashicorp", "consul", "aws", nil, nil, nil, contractTime, contractTime, nil,
+					false, nil, nil, nil))
+		mock.ExpectQuery("SELECT COUNT.*FROM module_versions WHERE module_id").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE mv.module_id").
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes", "checksum",
+				"readme", "published_by", "published_by_name", "download_count", "deprecated",
+				"deprecated_at", "deprecation_message", "replacement_source", "created_at",
+				"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version", "has_docs",
+			}).
+				AddRow("ver-2", "mod-1", "2.0.0", "modules/hashicorp/consul/aws/2.0.0.tgz", "local", 2048, "def456",
+					nil, nil, nil, int64(7), false, nil, nil, nil, contractTime, nil, nil, nil, nil, true).
+				AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tgz", "local", 1024, "abc123",
+					nil, nil, nil, int64(5), true, contractTime, "use 2.x", nil, contractTime, nil, nil, nil, nil, false))
+	})
+
+	versions, err := c.ModuleVersions(context.Background(), "hashicorp", "consul", "aws")
+	if err != nil {
+		t.Fatalf("ModuleVersions: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("versions = %+v, want 2", versions)
+	}
+	v := versions[0]
+	if v.Version != "2.0.0" || v.DownloadCount != 7 || !v.HasDocs || v.Deprecated {
+		t.Errorf("versions[0] = %+v", v)
+	}
+	if v := versions[1]; v.Version != "1.0.0" || !v.Deprecated {
+		t.Errorf("versions[1] = %+v, want deprecated 1.0.0", v)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientContract_ModuleVersionsNotFound(t *testing.T) {
+	c, mock := newClientContract(t, func(mock sqlmock.Sqlmock) {
+		expectDefaultOrganization(mock)
+		mock.ExpectQuery("SELECT.*FROM modules m.*WHERE m.organization_id = \\$1 AND m.namespace").
+			WillReturnRows(sqlmock.NewRows(contractModuleCols))
+		mock.ExpectQuery("SELECT.*FROM module_address_redirects").
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "organization_id", "namespace", "name", "system", "module_id", "created_at",
+				"namespace", "name", "system",
+			}))
+	})
+
+	_, err := c.ModuleVersions(context.Background(), "hashicorp", "missing", "aws")
+	if !client.IsNotFound(err) {
+		t.Fatalf("ModuleVersions = %v, want a not found error", err)
+	}
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || len(apiErr.Errors) == 0 {
+		t.Errorf("error = %#v, want the protocol's errors list decoded", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientContract_ProviderVersions(t *testing.T) {
+	c, mock := newClientContract(t, func(mock sqlmock.Sqlmock) {
+		expectDefaultOrganization(mock)
+		mock.ExpectQuery("SELECT.*FROM providers.*WHERE").
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "organization_id", "namespace", "type", "description", "source",
+				"created_by", "created_at", "updated_at", "created_by_name",
+			}).AddRow("prov-1", "org-1", "hashicorp", "aws", nil, nil, nil, contractTime, contractTime, nil))
+		mock.ExpectQuery("SELECT COUNT.*FROM provider_versions").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE pv.provider_id").
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "provider_id", "version", "protocols", "gpg_public_key",
+				"shasums_url", "shasums_signature_url",
+				"shasum_storage_key", "shasum_signature_storage_key",
+				"published_by", "published_by_name",
+				"deprecated", "deprecated_at", "deprecation_message", "created_at",
+			}).AddRow("ver-1", "prov-1", "4.0.0", []byte(`["5.0","6.0"]`), "", "", "",
+				nil, nil, nil, nil, false, nil, nil, contractTime))
+		mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "provider_version_id", "os", "arch", "filename",
+				"storage_path", "storage_backend", "size_bytes", "shasum", "h1_hash", "download_count",
+			}).AddRow("plat-1", "ver-1", "linux", "amd64", "terraform-provider-aws_4.0.0_linux_amd64.zip",
+				"providers/hashicorp/aws/4.0.0/terraform-provider-aws_4.0.0_linux_amd64.zip",
+				"local", int64(1024), "sha256abc", nil, int64(3)))
+	})
+
+	versions, err := c.ProviderVersions(context.Background(), "hashicorp", "aws")
+	if err != nil {
+		t.Fatalf("ProviderVersions: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Version != "4.0.0" || len(versions[0].Protocols) != 2 {
+		t.Fatalf("versions = %+v, want 4.0.0 with two protocols", versions)
+	}
+	if p := versions[0].Platforms; len(p) != 1 || p[0].OS != "linux" || p[0].Arch != "amd64" {
+		t.Errorf("platforms = %+v, want linux/amd64", p)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientContract_SearchModules(t *testing.T) {
+	c, mock := newClientContract(t, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM modules m").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery("SELECT.*FROM modules m.*ORDER BY.*LIMIT").
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "organization_id", "namespace", "name", "system", "description", "source",
+				"created_by", "created_by_name", "created_at", "updated_at",
+				"deprecated", "deprecated_at", "deprecation_message", "successor_module_id",
+				"latest_version", "total_downloads",
+			}).AddRow("mod-1", "org-1", "hashicorp", "consul", "aws", nil, nil, nil, nil, contractTime, contractTime,
+				false, nil, nil, nil, "2.0.0", int64(12)))
+		mock.ExpectQuery("SELECT namespace, short_description FROM namespace_metadata").
+			WillReturnRows(sqlmock.NewRows([]string{"namespace", "short_description"}).AddRow("hashicorp", "HashiCorp modules"))
+	})
+
+	page, err := c.SearchModules(context.Background(), client.ModuleSearchOptions{Namespace: "hashicorp", Limit: 1})
+	if err != nil {
+		t.Fatalf("SearchModules: %v", err)
+	}
+	if len(page.Modules) != 1 {
+		t.Fatalf("modules = %+v, want 1", page.Modules)
+	}
+	m := page.Modules[0]
+	if m.Name != "consul" || m.LatestVersion != "2.0.0" || m.DownloadCount != 12 {
+		t.Errorf("module = %+v", m)
+	}
+	if m.NamespaceDescription == nil || *m.NamespaceDescription != "HashiCorp modules" {
+		t.Errorf("namespace_description = %v, want HashiCorp modules", m.NamespaceDescription)
+	}
+	if page.Meta.Total != 3 || page.Meta.Limit != 1 {
+		t.Errorf("meta = %+v, want total 3 limit 1", page.Meta)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
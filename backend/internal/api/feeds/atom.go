@@ -0,0 +1,169 @@
+// atom.go renders lists of published versions as Atom 1.0 documents (RFC 4287).
+package feeds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ContentType is the media type feeds are served with.
+const ContentType = "application/atom+xml; charset=utf-8"
+
+// changelogExcerptRunes caps the changelog excerpt in an entry's summary.
+const changelogExcerptRunes = 500
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Links     []atomLink `xml:"link"`
+	Summary   atomText   `xml:"summary"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// feedSpec describes one feed: everything but its entries.
+type feedSpec struct {
+	Title string
+	// Path is the feed's own path (its rel="self" link and ID), e.g.
+	// /feeds/namespaces/hashicorp.atom.
+	Path string
+	// UIPath is the registry UI page the feed follows (its rel="alternate"
+	// link), e.g. /modules/hashicorp/consul/aws.
+	UIPath string
+}
+
+// builder renders feeds whose links are absolute URLs on baseURL. Feeds of
+// any scope — a namespace, a module, a provider or the whole registry — are
+// a feedSpec and the versions to list, newest first.
+type builder struct {
+	baseURL string
+	now     func() time.Time
+}
+
+// build renders the feed and returns it with its updated time: the newest
+// version's publish time, or now when the feed is empty.
+func (b builder) build(spec feedSpec, versions []models.FeedVersion) ([]byte, time.Time, error) {
+	updated := b.now().UTC().Truncate(time.Second)
+	if len(versions) > 0 {
+		updated = versions[0].PublishedAt.UTC().Truncate(time.Second)
+	}
+	feed := atomFeed{
+		ID:      b.baseURL + spec.Path,
+		Title:   spec.Title,
+		Updated: updated.Format(time.RFC3339),
+		Author:  atomPerson{Name: "Terraform Registry"},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: b.baseURL + spec.Path},
+			{Rel: "alternate", Type: "text/html", Href: b.baseURL + spec.UIPath},
+		},
+		Entries: make([]atomEntry, 0, len(versions)),
+	}
+	for _, v := range versions {
+		feed.Entries = append(feed.Entries, b.entry(v))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return nil, time.Time{}, fmt.Errorf("encoding feed: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), updated, nil
+}
+
+func (b builder) entry(v models.FeedVersion) atomEntry {
+	published := v.PublishedAt.UTC().Format(time.RFC3339)
+	address := versionAddress(v)
+	summary := fmt.Sprintf("%s %s was published.", address, v.Version)
+	if v.Changelog != nil && strings.TrimSpace(*v.Changelog) != "" {
+		summary = excerpt(strings.TrimSpace(*v.Changelog), changelogExcerptRunes)
+	}
+	return atomEntry{
+		ID:        b.entryID(v),
+		Title:     fmt.Sprintf("%s %s %s", v.Kind, address, v.Version),
+		Updated:   published,
+		Published: published,
+		Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: b.baseURL + versionUIPath(v)}},
+		Summary:   atomText{Type: "text", Body: summary},
+	}
+}
+
+// entryID is a tag URI (RFC 4151) for the version, dated by its publish day
+// so it stays the same however often the feed is rendered.
+func (b builder) entryID(v models.FeedVersion) string {
+	authority := "terraform-registry"
+	if u, err := url.Parse(b.baseURL); err == nil && u.Hostname() != "" {
+		authority = u.Hostname()
+	}
+	return fmt.Sprintf("tag:%s,%s:%s/%s/%s", authority, v.PublishedAt.UTC().Format("2006-01-02"),
+		v.Kind, versionAddress(v), v.Version)
+}
+
+// versionAddress is the module or provider address without a hostname:
+// namespace/name/system or namespace/type.
+func versionAddress(v models.FeedVersion) string {
+	if v.Kind == models.FeedKindModule {
+		return v.Namespace + "/" + v.Name + "/" + v.System
+	}
+	return v.Namespace + "/" + v.Name
+}
+
+// versionUIPath is the registry UI page of the version.
+func versionUIPath(v models.FeedVersion) string {
+	if v.Kind == models.FeedKindModule {
+		return uiPath("modules", v.Namespace, v.Name, v.System, v.Version)
+	}
+	return uiPath("providers", v.Namespace, v.Name, v.Version)
+}
+
+func uiPath(segments ...string) string {
+	var sb strings.Builder
+	for _, s := range segments {
+		sb.WriteByte('/')
+		sb.WriteString(url.PathEscape(s))
+	}
+	return sb.String()
+}
+
+// excerpt returns s cut to at most n runes, with an ellipsis when cut.
+func excerpt(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
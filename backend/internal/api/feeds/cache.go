@@ -0,0 +1,101 @@
+// cache.go holds rendered feeds for a few minutes and answers conditional
+// GETs against them.
+package feeds
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheTTL is how long a rendered feed is served before it is rebuilt, and
+// the max-age sent to feed readers.
+const cacheTTL = 5 * time.Minute
+
+// maxCachedFeeds bounds the cache; feed URLs are caller-chosen, so every
+// unknown namespace requested would otherwise add an entry.
+const maxCachedFeeds = 1000
+
+// renderedFeed is a rendered feed document and its validators.
+type renderedFeed struct {
+	body    []byte
+	etag    string
+	updated time.Time
+	expires time.Time
+}
+
+func newRenderedFeed(body []byte, updated, expires time.Time) *renderedFeed {
+	sum := sha256.Sum256(body)
+	return &renderedFeed{
+		body:    body,
+		etag:    `"` + hex.EncodeToString(sum[:16]) + `"`,
+		updated: updated,
+		expires: expires,
+	}
+}
+
+// notModified reports whether the request's validators match the feed.
+// If-None-Match takes precedence over If-Modified-Since (RFC 9110 13.2.2).
+func (f *renderedFeed) notModified(r *http.Request) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == f.etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !f.updated.After(t)
+		}
+	}
+	return false
+}
+
+// feedCache maps feed URLs to their rendered documents.
+type feedCache struct {
+	now func() time.Time
+
+	mu    sync.Mutex
+	feeds map[string]*renderedFeed
+}
+
+func newFeedCache(now func() time.Time) *feedCache {
+	return &feedCache{now: now, feeds: make(map[string]*renderedFeed)}
+}
+
+// get returns the cached feed for key, or nil when there is none or it has
+// expired.
+func (c *feedCache) get(key string) *renderedFeed {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f := c.feeds[key]
+	if f == nil || !c.now().Before(f.expires) {
+		return nil
+	}
+	return f
+}
+
+// put caches f under key. When the cache is full, expired feeds are dropped
+// first; if none have expired f is not cached.
+func (c *feedCache) put(key string, f *renderedFeed) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.feeds[key]; !ok && len(c.feeds) >= maxCachedFeeds {
+		now := c.now()
+		for k, cached := range c.feeds {
+			if !now.Before(cached.expires) {
+				delete(c.feeds, k)
+			}
+		}
+		if len(c.feeds) >= maxCachedFeeds {
+			return
+		}
+	}
+	c.feeds[key] = f
+}
@@ -0,0 +1,278 @@
+// Package feeds serves Atom feeds of newly published module and provider
+// versions so teams can subscribe to a namespace, module or provider instead
+// of polling the registry. Feeds are public and list only what an anonymous
+// Terraform client could install (see repositories.FeedRepository).
+package feeds
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// maxEntries is the number of versions a feed lists.
+const maxEntries = 50
+
+// atomSuffix ends every feed URL.
+const atomSuffix = ".atom"
+
+// versionSource lists recently published versions.
+// *repositories.FeedRepository satisfies it.
+type versionSource interface {
+	RecentModuleVersions(ctx context.Context, f repositories.FeedFilter) ([]models.FeedVersion, error)
+	RecentProviderVersions(ctx context.Context, f repositories.FeedFilter) ([]models.FeedVersion, error)
+}
+
+// The subsets of the organization, module and provider repositories the
+// handlers need.
+type (
+	organizationSource interface {
+		GetDefaultOrganization(ctx context.Context) (*models.Organization, error)
+	}
+	moduleLookup interface {
+		GetModule(ctx context.Context, orgID, namespace, name, system string) (*models.Module, error)
+	}
+	providerLookup interface {
+		GetProvider(ctx context.Context, orgID, namespace, providerType string) (*models.Provider, error)
+	}
+)
+
+// Handlers serves the feed endpoints.
+type Handlers struct {
+	cfg       *config.Config
+	versions  versionSource
+	orgs      organizationSource
+	modules   moduleLookup
+	providers providerLookup
+	cache     *feedCache
+	now       func() time.Time
+}
+
+// NewHandlers creates the feed handlers.
+func NewHandlers(db *sql.DB, cfg *config.Config) *Handlers {
+	return &Handlers{
+		cfg:       cfg,
+		versions:  repositories.NewFeedRepository(db),
+		orgs:      repositories.NewOrganizationRepository(db),
+		modules:   repositories.NewModuleRepository(db),
+		providers: repositories.NewProviderRepository(db),
+		cache:     newFeedCache(time.Now),
+		now:       time.Now,
+	}
+}
+
+// @Summary      Namespace feed
+// @Description  Atom feed of the 50 most recently published module and provider versions in a namespace, newest first. Each entry carries the version, its publish time, a changelog excerpt when one was recorded, and a link to the version in the registry UI. Archived module versions, mirrored provider versions pending approval or rejected, and provider versions synced only by private mirrors are not listed. Feeds are cached for 5 minutes and support conditional GET through ETag / If-None-Match and Last-Modified / If-Modified-Since. An unknown namespace returns an empty feed.
+// @Tags         Feeds
+// @Produce      application/atom+xml
+// @Param        namespace  path  string  true  "Namespace"
+// @Success      200  {string}  string  "Atom feed"
+// @Success      304  "Not modified"
+// @Failure      404  {object}  map[string]interface{}  "URL does not end in .atom"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /feeds/namespaces/{namespace}.atom [get]
+// NamespaceFeed serves GET /feeds/namespaces/:namespace.atom.
+func (h *Handlers) NamespaceFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		namespace, ok := atomParam(c, "namespace")
+		if !ok {
+			return
+		}
+		h.serve(c, func(ctx context.Context, orgID string) (feedSpec, []models.FeedVersion, error) {
+			filter := repositories.FeedFilter{OrganizationID: orgID, Namespace: namespace, Limit: maxEntries}
+			mods, err := h.versions.RecentModuleVersions(ctx, filter)
+			if err != nil {
+				return feedSpec{}, nil, err
+			}
+			provs, err := h.versions.RecentProviderVersions(ctx, filter)
+			if err != nil {
+				return feedSpec{}, nil, err
+			}
+			spec := feedSpec{
+				Title:  fmt.Sprintf("New versions in %s", namespace),
+				Path:   uiPath("feeds", "namespaces", namespace) + atomSuffix,
+				UIPath: uiPath("namespaces", namespace),
+			}
+			return spec, newest(maxEntries, mods, provs), nil
+		})
+	}
+}
+
+// @Summary      Module feed
+// @Description  Atom feed of the 50 most recently published versions of a module, newest first, with the same entries, visibility rules, caching and conditional GET support as the namespace feed.
+// @Tags         Feeds
+// @Produce      application/atom+xml
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
+// @Success      200  {string}  string  "Atom feed"
+// @Success      304  "Not modified"
+// @Failure      404  {object}  map[string]interface{}  "Module not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /feeds/modules/{namespace}/{name}/{system}.atom [get]
+// ModuleFeed serves GET /feeds/modules/:namespace/:name/:system.atom.
+func (h *Handlers) ModuleFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		system, ok := atomParam(c, "system")
+		if !ok {
+			return
+		}
+		namespace, name := c.Param("namespace"), c.Param("name")
+		h.serve(c, func(ctx context.Context, orgID string) (feedSpec, []models.FeedVersion, error) {
+			module, err := h.modules.GetModule(ctx, orgID, namespace, name, system)
+			if err != nil {
+				return feedSpec{}, nil, err
+			}
+			if module == nil {
+				return feedSpec{}, nil, notFoundError("Module not found")
+			}
+			versions, err := h.versions.RecentModuleVersions(ctx, repositories.FeedFilter{
+				OrganizationID: orgID, Namespace: namespace, Name: name, System: system, Limit: maxEntries,
+			})
+			if err != nil {
+				return feedSpec{}, nil, err
+			}
+			spec := feedSpec{
+				Title:  fmt.Sprintf("New versions of module %s/%s/%s", namespace, name, system),
+				Path:   uiPath("feeds", "modules", namespace, name, system) + atomSuffix,
+				UIPath: uiPath("modules", namespace, name, system),
+			}
+			return spec, versions, nil
+		})
+	}
+}
+
+// @Summary      Provider feed
+// @Description  Atom feed of the 50 most recently published versions of a provider, newest first, with the same entries, visibility rules, caching and conditional GET support as the namespace feed.
+// @Tags         Feeds
+// @Produce      application/atom+xml
+// @Param        namespace  path  string  true  "Provider namespace"
+// @Param        type       path  string  true  "Provider type"
+// @Success      200  {string}  string  "Atom feed"
+// @Success      304  "Not modified"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /feeds/providers/{namespace}/{type}.atom [get]
+// ProviderFeed serves GET /feeds/providers/:namespace/:type.atom.
+func (h *Handlers) ProviderFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		providerType, ok := atomParam(c, "type")
+		if !ok {
+			return
+		}
+		namespace := c.Param("namespace")
+		h.serve(c, func(ctx context.Context, orgID string) (feedSpec, []models.FeedVersion, error) {
+			provider, err := h.providers.GetProvider(ctx, orgID, namespace, providerType)
+			if err != nil {
+				return feedSpec{}, nil, err
+			}
+			if provider == nil {
+				return feedSpec{}, nil, notFoundError("Provider not found")
+			}
+			versions, err := h.versions.RecentProviderVersions(ctx, repositories.FeedFilter{
+				OrganizationID: orgID, Namespace: namespace, Name: providerType, Limit: maxEntries,
+			})
+			if err != nil {
+				return feedSpec{}, nil, err
+			}
+			spec := feedSpec{
+				Title:  fmt.Sprintf("New versions of provider %s/%s", namespace, providerType),
+				Path:   uiPath("feeds", "providers", namespace, providerType) + atomSuffix,
+				UIPath: uiPath("providers", namespace, providerType),
+			}
+			return spec, versions, nil
+		})
+	}
+}
+
+// loadFunc loads a feed for the default organization. It returns a
+// notFoundError when the module or provider the feed follows does not exist.
+type loadFunc func(ctx context.Context, orgID string) (feedSpec, []models.FeedVersion, error)
+
+// notFoundError is answered with a 404 carrying its text.
+type notFoundError string
+
+func (e notFoundError) Error() string { return string(e) }
+
+// serve answers a feed request from the cache, or loads, renders and caches
+// the feed. Feeds link to the tenant domain the request was made on, so they
+// are cached per public URL.
+func (h *Handlers) serve(c *gin.Context, load loadFunc) {
+	baseURL := strings.TrimRight(middleware.TenantPublicURL(c, h.cfg.Server.GetPublicURL()), "/")
+	key := baseURL + c.Request.URL.Path
+
+	feed := h.cache.get(key)
+	if feed == nil {
+		ctx := c.Request.Context()
+		org, err := h.orgs.GetDefaultOrganization(ctx)
+		if err != nil || org == nil {
+			slog.Error("failed to resolve the default organization for a feed", "path", c.Request.URL.Path, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+			return
+		}
+		spec, versions, err := load(ctx, org.ID)
+		var notFound notFoundError
+		if errors.As(err, &notFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": notFound.Error()})
+			return
+		}
+		if err != nil {
+			slog.Error("failed to load feed", "path", c.Request.URL.Path, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load feed"})
+			return
+		}
+		body, updated, err := builder{baseURL: baseURL, now: h.now}.build(spec, versions)
+		if err != nil {
+			slog.Error("failed to render feed", "path", c.Request.URL.Path, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render feed"})
+			return
+		}
+		feed = newRenderedFeed(body, updated, h.now().Add(cacheTTL))
+		h.cache.put(key, feed)
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(cacheTTL.Seconds())))
+	c.Header("ETag", feed.etag)
+	c.Header("Last-Modified", feed.updated.Format(http.TimeFormat))
+	if feed.notModified(c.Request) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, ContentType, feed.body)
+}
+
+// atomParam returns the named path parameter without its .atom suffix. A
+// parameter without the suffix is answered with a 404.
+func atomParam(c *gin.Context, name string) (string, bool) {
+	v, ok := strings.CutSuffix(c.Param(name), atomSuffix)
+	if !ok || v == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return "", false
+	}
+	return v, true
+}
+
+// newest merges version lists into one, newest first, keeping at most n.
+func newest(n int, lists ...[]models.FeedVersion) []models.FeedVersion {
+	var all []models.FeedVersion
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].PublishedAt.After(all[j].PublishedAt) })
+	if len(all) > n {
+		all = all[:n]
+	}
+	return all
+}
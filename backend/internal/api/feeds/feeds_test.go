@@ -0,0 +1,213 @@
+package feeds
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var published = time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+type fakeSource struct {
+	modules, providers []models.FeedVersion
+	err                error
+	calls              int
+	filters            []repositories.FeedFilter
+}
+
+func (f *fakeSource) RecentModuleVersions(_ context.Context, filter repositories.FeedFilter) ([]models.FeedVersion, error) {
+	f.calls++
+	f.filters = append(f.filters, filter)
+	return f.modules, f.err
+}
+
+func (f *fakeSource) RecentProviderVersions(_ context.Context, filter repositories.FeedFilter) ([]models.FeedVersion, error) {
+	f.calls++
+	f.filters = append(f.filters, filter)
+	return f.providers, f.err
+}
+
+type fakeCatalog struct{ module *models.Module }
+
+func (fakeCatalog) GetDefaultOrganization(context.Context) (*models.Organization, error) {
+	return &models.Organization{ID: "org-1"}, nil
+}
+
+func (f fakeCatalog) GetModule(context.Context, string, string, string, string) (*models.Module, error) {
+	return f.module, nil
+}
+
+func (fakeCatalog) GetProvider(context.Context, string, string, string) (*models.Provider, error) {
+	return nil, nil
+}
+
+func newTestRouter(src *fakeSource, catalog fakeCatalog) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Server.PublicURL = "https://registry.example.com/"
+	now := func() time.Time { return published.Add(time.Hour) }
+	h := &Handlers{
+		cfg: cfg, versions: src, orgs: catalog, modules: catalog, providers: catalog,
+		cache: newFeedCache(now), now: now,
+	}
+	r := gin.New()
+	r.GET("/feeds/namespaces/:namespace", h.NamespaceFeed())
+	r.GET("/feeds/modules/:namespace/:name/:system", h.ModuleFeed())
+	r.GET("/feeds/providers/:namespace/:type", h.ProviderFeed())
+	return r
+}
+
+func get(r http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestNamespaceFeed(t *testing.T) {
+	changelog := "## Fixed\n\n- Subnet tagging"
+	src := &fakeSource{
+		modules: []models.FeedVersion{{
+			Kind: models.FeedKindModule, Namespace: "acme", Name: "vpc", System: "aws",
+			Version: "1.2.0", PublishedAt: published.Add(-time.Hour), Changelog: &changelog,
+		}},
+		providers: []models.FeedVersion{{
+			Kind: models.FeedKindProvider, Namespace: "acme", Name: "cloud", Version: "0.4.0", PublishedAt: published,
+		}},
+	}
+	r := newTestRouter(src, fakeCatalog{})
+
+	w := get(r, "/feeds/namespaces/acme.atom", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if lm := w.Header().Get("Last-Modified"); lm != published.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q, want the newest version's publish time", lm)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=300" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	if src.filters[0].OrganizationID != "org-1" || src.filters[0].Namespace != "acme" {
+		t.Errorf("filter = %+v", src.filters[0])
+	}
+
+	var feed atomFeed
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("feed is not valid XML: %v", err)
+	}
+	if feed.ID != "https://registry.example.com/feeds/namespaces/acme.atom" {
+		t.Errorf("feed id = %q", feed.ID)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(feed.Entries))
+	}
+	first, second := feed.Entries[0], feed.Entries[1]
+	if first.Title != "provider acme/cloud 0.4.0" || first.Links[0].Href != "https://registry.example.com/providers/acme/cloud/0.4.0" {
+		t.Errorf("first entry = %+v, want the newer provider version", first)
+	}
+	if first.ID != "tag:registry.example.com,2026-03-14:provider/acme/cloud/0.4.0" {
+		t.Errorf("first entry id = %q", first.ID)
+	}
+	if second.Summary.Body != changelog || second.Links[0].Href != "https://registry.example.com/modules/acme/vpc/aws/1.2.0" {
+		t.Errorf("second entry = %+v, want the module version with its changelog", second)
+	}
+}
+
+func TestFeed_ConditionalGetAndCache(t *testing.T) {
+	src := &fakeSource{modules: []models.FeedVersion{{
+		Kind: models.FeedKindModule, Namespace: "acme", Name: "vpc", System: "aws", Version: "1.2.0", PublishedAt: published,
+	}}}
+	r := newTestRouter(src, fakeCatalog{module: &models.Module{ID: "m1"}})
+	const path = "/feeds/modules/acme/vpc/aws.atom"
+
+	w := get(r, path, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+
+	for name, h := range map[string]http.Header{
+		"matching etag":        {"If-None-Match": {`"other", ` + etag}},
+		"weak etag":            {"If-None-Match": {"W/" + etag}},
+		"not modified since":   {"If-Modified-Since": {published.Format(http.TimeFormat)}},
+		"etag wins over dates": {"If-None-Match": {etag}, "If-Modified-Since": {published.Add(-time.Hour).Format(http.TimeFormat)}},
+	} {
+		if w := get(r, path, h); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("%s: status = %d, want 304 with no body", name, w.Code)
+		}
+	}
+	for name, h := range map[string]http.Header{
+		"stale etag":     {"If-None-Match": {`"other"`}},
+		"modified since": {"If-Modified-Since": {published.Add(-time.Minute).Format(http.TimeFormat)}},
+	} {
+		if w := get(r, path, h); w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", name, w.Code)
+		}
+	}
+	if src.calls != 1 {
+		t.Errorf("version queries = %d, want 1 (later requests served from the cache)", src.calls)
+	}
+}
+
+func TestFeed_Errors(t *testing.T) {
+	r := newTestRouter(&fakeSource{}, fakeCatalog{})
+	for path, want := range map[string]int{
+		"/feeds/modules/acme/vpc/aws.atom":     http.StatusNotFound,
+		"/feeds/providers/acme/cloud.atom":     http.StatusNotFound,
+		"/feeds/namespaces/acme":               http.StatusNotFound,
+		"/feeds/namespaces/.atom":              http.StatusNotFound,
+		"/feeds/namespaces/nobody-here.atom":   http.StatusOK,
+		"/feeds/modules/acme/vpc/aws.atom.xml": http.StatusNotFound,
+	} {
+		if w := get(r, path, nil); w.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, w.Code, want)
+		}
+	}
+
+	r = newTestRouter(&fakeSource{err: errors.New("db down")}, fakeCatalog{})
+	if w := get(r, "/feeds/namespaces/acme.atom", nil); w.Code != http.StatusInternalServerError {
+		t.Errorf("query failure: status = %d, want 500", w.Code)
+	}
+}
+
+func TestNewest(t *testing.T) {
+	at := func(h int) models.FeedVersion {
+		return models.FeedVersion{Version: "v", PublishedAt: published.Add(time.Duration(h) * time.Hour)}
+	}
+	got := newest(3, []models.FeedVersion{at(5), at(1)}, []models.FeedVersion{at(4), at(2)})
+	if len(got) != 3 || !got[0].PublishedAt.Equal(at(5).PublishedAt) || !got[2].PublishedAt.Equal(at(2).PublishedAt) {
+		t.Errorf("newest = %v", got)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := excerpt("short", 10); got != "short" {
+		t.Errorf("excerpt = %q", got)
+	}
+	if got := excerpt("héllo wörld", 6); got != "héllo…" {
+		t.Errorf("excerpt = %q, want héllo…", got)
+	}
+	if got := excerpt(strings.Repeat("a", 600), changelogExcerptRunes); len([]rune(got)) != changelogExcerptRunes+1 {
+		t.Errorf("excerpt length = %d", len([]rune(got)))
+	}
+}
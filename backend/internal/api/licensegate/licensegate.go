@@ -0,0 +1,102 @@
+// Package licensegate enforces per-provider license acceptance on the
+// provider download endpoints.
+//
+// An admin may attach a license to a provider and enable gating. The
+// provider's downloads — the Provider Registry download endpoint and the
+// network mirror platform index — are then served only to authenticated
+// users who have accepted the license's current version through
+// POST /api/v1/providers/:namespace/:type/accept-license. Anonymous requests
+// get 403 and users who have not accepted get 451; both responses point at
+// the license and the acceptance endpoint.
+package licensegate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// store is the subset of ProviderLicenseRepository the gate needs.
+type store interface {
+	GetGate(ctx context.Context, providerID string) (*models.ProviderLicenseGate, error)
+	GetAcceptance(ctx context.Context, providerID, userID, licenseVersion string) (*models.ProviderLicenseAcceptance, error)
+}
+
+// Gate checks download requests against the providers' license gates. A nil
+// *Gate lets every request through.
+type Gate struct {
+	store store
+}
+
+// New creates the gate backed by the provider license repository.
+func New(repo *repositories.ProviderLicenseRepository) *Gate {
+	return &Gate{store: repo}
+}
+
+// LicenseURL is the public endpoint returning a provider's license.
+func LicenseURL(namespace, providerType string) string {
+	return fmt.Sprintf("/api/v1/providers/%s/%s/license", namespace, providerType)
+}
+
+// AcceptURL is the endpoint through which a user accepts a provider's license.
+func AcceptURL(namespace, providerType string) string {
+	return fmt.Sprintf("/api/v1/providers/%s/%s/accept-license", namespace, providerType)
+}
+
+// Allow reports whether the request may download provider. When it may not,
+// the response has been written: 403 for an anonymous request, 451 for a user
+// who has not accepted the license's current version, 500 when the check
+// itself failed.
+func (g *Gate) Allow(c *gin.Context, provider *models.Provider) bool {
+	if g == nil || provider == nil {
+		return true
+	}
+
+	gate, err := g.store.GetGate(c.Request.Context(), provider.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check provider license",
+		})
+		return false
+	}
+	if gate == nil || !gate.Enabled {
+		return true
+	}
+
+	name := provider.Namespace + "/" + provider.Type
+	userID := c.GetString("user_id")
+	if userID == "" {
+		deny(c, http.StatusForbidden, gate, provider,
+			fmt.Sprintf("Provider %s requires accepting its license before download; authenticate as a user and accept it with POST %s", name, AcceptURL(provider.Namespace, provider.Type)))
+		return false
+	}
+
+	accepted, err := g.store.GetAcceptance(c.Request.Context(), provider.ID, userID, gate.LicenseVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check provider license",
+		})
+		return false
+	}
+	if accepted == nil {
+		deny(c, http.StatusUnavailableForLegalReasons, gate, provider,
+			fmt.Sprintf("Provider %s requires accepting version %s of its license before download; accept it with POST %s", name, gate.LicenseVersion, AcceptURL(provider.Namespace, provider.Type)))
+		return false
+	}
+	return true
+}
+
+// deny writes a gated response in the registry protocol's errors format,
+// with the links a client needs to read and accept the license.
+func deny(c *gin.Context, status int, gate *models.ProviderLicenseGate, provider *models.Provider, message string) {
+	c.JSON(status, gin.H{
+		"errors":             []string{message},
+		"license_version":    gate.LicenseVersion,
+		"license_url":        LicenseURL(provider.Namespace, provider.Type),
+		"accept_license_url": AcceptURL(provider.Namespace, provider.Type),
+	})
+}
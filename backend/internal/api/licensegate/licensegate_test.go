@@ -0,0 +1,112 @@
+package licensegate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type fakeStore struct {
+	gate      *models.ProviderLicenseGate
+	gateErr   error
+	accepted  map[string]bool // "userID/version"
+	acceptErr error
+}
+
+func (f *fakeStore) GetGate(context.Context, string) (*models.ProviderLicenseGate, error) {
+	return f.gate, f.gateErr
+}
+
+func (f *fakeStore) GetAcceptance(_ context.Context, providerID, userID, version string) (*models.ProviderLicenseAcceptance, error) {
+	if f.acceptErr != nil {
+		return nil, f.acceptErr
+	}
+	if f.accepted[userID+"/"+version] {
+		return &models.ProviderLicenseAcceptance{ProviderID: providerID, UserID: userID, LicenseVersion: version}, nil
+	}
+	return nil, nil
+}
+
+var testProvider = &models.Provider{ID: "prov-1", Namespace: "acme", Type: "widgets"}
+
+// allow runs g.Allow for a request authenticated as userID ("" for anonymous).
+func allow(g *Gate, userID string) (bool, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if userID != "" {
+		c.Set("user_id", userID)
+	}
+	return g.Allow(c, testProvider), w
+}
+
+func TestAllow(t *testing.T) {
+	enabled := &models.ProviderLicenseGate{ProviderID: "prov-1", LicenseVersion: "v2", Enabled: true}
+	disabled := &models.ProviderLicenseGate{ProviderID: "prov-1", LicenseVersion: "v2", Enabled: false}
+	accepted := map[string]bool{"user-1/v2": true, "user-2/v1": true}
+
+	tests := []struct {
+		name       string
+		store      *fakeStore
+		userID     string
+		wantAllow  bool
+		wantStatus int
+	}{
+		{"no gate", &fakeStore{}, "", true, 0},
+		{"gate disabled", &fakeStore{gate: disabled}, "", true, 0},
+		{"anonymous", &fakeStore{gate: enabled, accepted: accepted}, "", false, http.StatusForbidden},
+		{"accepted current version", &fakeStore{gate: enabled, accepted: accepted}, "user-1", true, 0},
+		{"accepted an older version only", &fakeStore{gate: enabled, accepted: accepted}, "user-2", false, http.StatusUnavailableForLegalReasons},
+		{"never accepted", &fakeStore{gate: enabled, accepted: accepted}, "user-3", false, http.StatusUnavailableForLegalReasons},
+		{"gate lookup fails", &fakeStore{gateErr: errors.New("db down")}, "user-1", false, http.StatusInternalServerError},
+		{"acceptance lookup fails", &fakeStore{gate: enabled, acceptErr: errors.New("db down")}, "user-1", false, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, w := allow(&Gate{store: tt.store}, tt.userID)
+			if ok != tt.wantAllow {
+				t.Fatalf("Allow = %v, want %v", ok, tt.wantAllow)
+			}
+			if !ok && w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAllow_DeniedResponsePointsAtAcceptance(t *testing.T) {
+	g := &Gate{store: &fakeStore{gate: &models.ProviderLicenseGate{LicenseVersion: "v2", Enabled: true}}}
+	_, w := allow(g, "")
+
+	var body struct {
+		Errors           []string `json:"errors"`
+		LicenseVersion   string   `json:"license_version"`
+		LicenseURL       string   `json:"license_url"`
+		AcceptLicenseURL string   `json:"accept_license_url"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Errors) != 1 || body.LicenseVersion != "v2" ||
+		body.LicenseURL != "/api/v1/providers/acme/widgets/license" ||
+		body.AcceptLicenseURL != "/api/v1/providers/acme/widgets/accept-license" {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestAllow_NilGate(t *testing.T) {
+	var g *Gate
+	if ok, _ := allow(g, ""); !ok {
+		t.Error("a nil gate should let every request through")
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+)
+
+// fillNilPointers points every nil pointer field of the struct v points to at
+// a zero value, so the route registration functions can run without real
+// dependencies. The fields are unexported, hence the unsafe access.
+func fillNilPointers(v interface{}) {
+	rv := reflect.ValueOf(v).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		if f.Kind() == reflect.Ptr && f.IsNil() {
+			reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem().Set(reflect.New(f.Type().Elem()))
+		}
+	}
+}
+
+// registeredRoutes returns the registry's route table.
+func registeredRoutes(t *testing.T) gin.RoutesInfo {
+	t.Helper()
+	r := gin.New()
+	routes := newRouteGroup(r)
+	public := &publicRouteDeps{cfg: &config.Config{}}
+	fillNilPointers(public)
+	registerPublicRoutes(routes, public)
+	v1 := &apiV1RouteDeps{cfg: &config.Config{}}
+	fillNilPointers(v1)
+	registerAPIV1Routes(routes, v1)
+	return r.Routes()
+}
+
+var routeParamPattern = regexp.MustCompile(`[:*][^/]+`)
+
+// TestHTTPMetricsCardinalityBounded serves every registered route for many
+// organizations and checks that http_requests_total gains at most one series
+// per route and org bucket: the path label is the route template and the
+// organization is bucketed.
+func TestHTTPMetricsCardinalityBounded(t *testing.T) {
+	routes := registeredRoutes(t)
+	if len(routes) < 100 {
+		t.Fatalf("only %d routes registered; the route table did not load", len(routes))
+	}
+
+	// Count only the series this test produces; other tests in the package
+	// serve real requests through the same global counter.
+	telemetry.HTTPRequestsTotal.Reset()
+
+	const orgs = 40
+	r := gin.New()
+	r.Use(middleware.MetricsMiddleware(nil))
+	templates := map[string]bool{}
+	for _, route := range routes {
+		templates[route.Path] = true
+		r.Handle(route.Method, route.Path, func(c *gin.Context) {
+			if org := c.GetHeader("X-Test-Org"); org != "" {
+				c.Set("organization_id", org)
+			}
+			c.Status(http.StatusOK)
+		})
+	}
+	for _, route := range routes {
+		path := routeParamPattern.ReplaceAllString(route.Path, "x")
+		for i := 0; i <= orgs; i++ {
+			req := httptest.NewRequest(route.Method, path, nil)
+			if i > 0 {
+				req.Header.Set("X-Test-Org", fmt.Sprintf("org-%d", i))
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+		}
+	}
+
+	ch := make(chan prometheus.Metric, 4096)
+	go func() {
+		telemetry.HTTPRequestsTotal.Collect(ch)
+		close(ch)
+	}()
+	series := 0
+	buckets := map[string]bool{}
+	groups := map[string]bool{}
+	for m := range ch {
+		var dm dto.Metric
+		if err := m.Write(&dm); err != nil {
+			t.Fatal(err)
+		}
+		labels := map[string]string{}
+		for _, lp := range dm.GetLabel() {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		if !templates[labels["path"]] {
+			continue
+		}
+		series++
+		buckets[labels["org_bucket"]] = true
+		groups[labels["group"]] = true
+	}
+
+	if series == 0 {
+		t.Fatal("no http_requests_total series recorded for the route table")
+	}
+	if limit := len(routes) * (telemetry.HTTPOrgBuckets + 1); series > limit {
+		t.Errorf("%d routes x %d organizations produced %d series, want at most %d", len(routes), orgs, series, limit)
+	}
+	if len(buckets) > telemetry.HTTPOrgBuckets+1 {
+		t.Errorf("org_bucket has %d values, want at most %d", len(buckets), telemetry.HTTPOrgBuckets+1)
+	}
+	if len(groups) > 4 {
+		t.Errorf("group has %d values (%v), want at most 4", len(groups), groups)
+	}
+}
@@ -0,0 +1,49 @@
+// hostname_alias.go decides what the mirror handlers may serve for a request
+// under a hostname alias (see models.MirrorHostnameAlias).
+package mirror
+
+import (
+	"context"
+	"strings"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// providerSourceStore is the subset of MirrorHostnameAliasRepository the
+// mirror handlers need.
+type providerSourceStore interface {
+	GetProviderSource(ctx context.Context, providerID string) (*models.MirroredProviderSource, error)
+	ListSyncedVersionIDs(ctx context.Context, mirroredProviderID string) (map[string]bool, error)
+}
+
+// aliasedVersionIDs returns the IDs of the provider versions that may be
+// served under alias for a request made with hostname. ok is false when the
+// provider must not be served under the alias at all: it was not synced by the
+// alias's mirror, or has not opted in. A nil map with ok true means no
+// restriction, which is the case when the provider's mirror syncs from
+// hostname itself.
+//
+// Only versions the alias's mirror synced are served: versions that reached
+// the provider another way (an upload or a pull-through fetch) were never
+// checked against the alias's upstream, and the two registries do not always
+// agree on checksums.
+func aliasedVersionIDs(ctx context.Context, store providerSourceStore, alias *models.MirrorHostnameAlias, hostname, providerID string) (ids map[string]bool, ok bool, err error) {
+	src, err := store.GetProviderSource(ctx, providerID)
+	if err != nil {
+		return nil, false, err
+	}
+	if src == nil {
+		return nil, false, nil
+	}
+	if src.UpstreamHostname == strings.ToLower(hostname) {
+		return nil, true, nil
+	}
+	if src.MirrorConfigID != alias.MirrorConfigID || !src.ServeUnderAliases {
+		return nil, false, nil
+	}
+	ids, err = store.ListSyncedVersionIDs(ctx, src.MirroredProviderID)
+	if err != nil {
+		return nil, false, err
+	}
+	return ids, true, nil
+}
@@ -0,0 +1,55 @@
+package mirror
+
+import (
+	"context"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+type fakeProviderSourceStore struct {
+	src *models.MirroredProviderSource
+	ids map[string]bool
+}
+
+func (f *fakeProviderSourceStore) GetProviderSource(context.Context, string) (*models.MirroredProviderSource, error) {
+	return f.src, nil
+}
+
+func (f *fakeProviderSourceStore) ListSyncedVersionIDs(context.Context, string) (map[string]bool, error) {
+	return f.ids, nil
+}
+
+func TestAliasedVersionIDs(t *testing.T) {
+	alias := &models.MirrorHostnameAlias{MirrorConfigID: "m-1", AliasHostname: "registry.opentofu.org", TargetHostname: "registry.terraform.io"}
+	synced := map[string]bool{"v-1": true}
+	source := func(mirrorID, upstream string, optIn bool) *models.MirroredProviderSource {
+		return &models.MirroredProviderSource{MirroredProviderID: "mp-1", MirrorConfigID: mirrorID, UpstreamHostname: upstream, ServeUnderAliases: optIn}
+	}
+
+	tests := []struct {
+		name     string
+		src      *models.MirroredProviderSource
+		wantOK   bool
+		wantIDs  bool
+		hostname string
+	}{
+		{"not mirrored", nil, false, false, "registry.opentofu.org"},
+		{"not opted in", source("m-1", "registry.terraform.io", false), false, false, "registry.opentofu.org"},
+		{"other mirror", source("m-2", "registry.terraform.io", true), false, false, "registry.opentofu.org"},
+		{"opted in serves synced versions", source("m-1", "registry.terraform.io", true), true, true, "registry.opentofu.org"},
+		{"synced from the request hostname", source("m-2", "registry.opentofu.org", false), true, false, "Registry.OpenTofu.org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeProviderSourceStore{src: tt.src, ids: synced}
+			ids, ok, err := aliasedVersionIDs(context.Background(), store, alias, tt.hostname, "prov-1")
+			if err != nil {
+				t.Fatalf("aliasedVersionIDs: %v", err)
+			}
+			if ok != tt.wantOK || (ids != nil) != tt.wantIDs {
+				t.Errorf("aliasedVersionIDs = %v, %v; want ok %v, restricted %v", ids, ok, tt.wantOK, tt.wantIDs)
+			}
+		})
+	}
+}
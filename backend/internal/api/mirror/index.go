@@ -2,7 +2,9 @@
 // (https://developer.hashicorp.com/terraform/internals/provider-network-mirror-protocol).
 // The mirror protocol allows Terraform to use the registry as a local cache of provider binaries,
 // enabling air-gapped deployments and reducing upstream registry dependencies.
-// These endpoints are unauthenticated by protocol design.
+// These endpoints need no authentication by protocol design; optional
+// credentials only identify the requester's organizations for private mirrors
+// (see Visibility).
 package mirror
 
 import (
@@ -14,11 +16,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/services"
 )
 
 // @Summary      Network mirror provider version index
-// @Description  Returns all available versions for a provider in the Terraform Network Mirror Protocol format.
+// @Description  Returns all available versions for a provider in the Terraform Network Mirror Protocol format. A provider only private mirrors synced is served only to members of their organization (authenticate with a JWT, API key, or Terraform CLI token); others get 404.
 // @Tags         Mirror Protocol
 // @Produce      json
 // @Param        hostname   path  string  true  "Origin registry hostname (e.g. registry.terraform.io)"
@@ -31,20 +34,30 @@ import (
 // IndexHandler handles network mirror index requests
 // Implements: GET /terraform/providers/:hostname/:namespace/:type/index.json
 // Returns a simple JSON object with all available versions
-func IndexHandler(db *sql.DB, _ *config.Config, pullThrough *services.PullThroughService) gin.HandlerFunc {
+// Under a hostname alias (aliases, nil: none) only the versions the alias's
+// mirror synced are listed; see aliasedVersionIDs. Private mirrors' providers
+// are listed only to members of their organization (visibility, nil: no
+// restriction); see Visibility.
+func IndexHandler(db *sql.DB, _ *config.Config, pullThrough *services.PullThroughService, aliases *middleware.MirrorHostnameAliases, visibility *Visibility) gin.HandlerFunc {
 	providerRepo := repositories.NewProviderRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
+	aliasRepo := repositories.NewMirrorHostnameAliasRepository(db)
 
 	return func(c *gin.Context) {
 		// Note: hostname is in the path for compatibility with Network Mirror Protocol
 		// It represents the origin registry hostname (e.g., registry.terraform.io)
-		// We don't use it for routing, but it's part of the spec
+		// Providers are not keyed by it; it only matters for hostname aliases.
 		hostname := c.Param("hostname")
 		namespace := c.Param("namespace")
 		providerType := c.Param("type")
 
-		// Log hostname for debugging (not used in single-tenant mode)
-		_ = hostname
+		alias, err := aliases.Lookup(c.Request.Context(), hostname, namespace)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load mirror hostname aliases",
+			})
+			return
+		}
 
 		// Get organization context (default org for single-tenant mode)
 		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
@@ -70,10 +83,17 @@ func IndexHandler(db *sql.DB, _ *config.Config, pullThrough *services.PullThroug
 			return
 		}
 
+		provider, hiddenIDs, ok := visibility.servedProvider(c, providerRepo, provider, namespace, providerType)
+		if !ok {
+			return
+		}
+
 		if provider == nil {
-			// Cache miss — attempt pull-through if configured
-			if pullThrough != nil {
+			// Cache miss — attempt pull-through if configured. Nothing fetched
+			// that way could be served under an alias, so aliases skip it.
+			if pullThrough != nil && alias == nil {
 				configs, err := pullThrough.GetConfigsForProvider(c.Request.Context(), org.ID, namespace, providerType)
+				configs = visibility.pullThroughConfigs(c, configs)
 				if err != nil || len(configs) == 0 {
 					c.Data(http.StatusNotFound, "application/json", []byte(`{"errors":["provider not found"]}`))
 					return
@@ -96,6 +116,22 @@ func IndexHandler(db *sql.DB, _ *config.Config, pullThrough *services.PullThroug
 			}
 		}
 
+		var aliasedIDs map[string]bool
+		if alias != nil {
+			ids, ok, err := aliasedVersionIDs(c.Request.Context(), aliasRepo, alias, hostname, provider.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to query provider",
+				})
+				return
+			}
+			if !ok {
+				c.Data(http.StatusNotFound, "application/json", []byte(`{"errors":["provider not found"]}`))
+				return
+			}
+			aliasedIDs = ids
+		}
+
 		// Get versions visible to clients (hides versions pending/rejected approval)
 		versions, err := providerRepo.ListVisibleVersions(c.Request.Context(), provider.ID)
 		if err != nil {
@@ -118,6 +154,12 @@ func IndexHandler(db *sql.DB, _ *config.Config, pullThrough *services.PullThroug
 		// }
 		versionsMap := make(map[string]interface{})
 		for _, v := range versions {
+			if aliasedIDs != nil && !aliasedIDs[v.ID] {
+				continue
+			}
+			if hiddenIDs[v.ID] {
+				continue
+			}
 			// Each version is an empty object per the spec
 			versionsMap[v.Version] = gin.H{}
 		}
@@ -1,6 +1,7 @@
 package mirror
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"net/http"
@@ -14,6 +15,8 @@ import (
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/downloadstats"
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/local"
 )
 
@@ -71,8 +74,8 @@ func newMirrorAPIRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
 
 	cfg := &config.Config{}
 	r := gin.New()
-	r.GET("/providers/:hostname/:namespace/:type/index.json", IndexHandler(db, cfg, nil))
-	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, cfg, nil, nil))
+	r.GET("/providers/:hostname/:namespace/:type/index.json", IndexHandler(db, cfg, nil, nil, nil))
+	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, cfg, nil, nil, nil, nil, nil, nil))
 	return mock, r
 }
 
@@ -374,7 +377,7 @@ func TestPlatformIndex_StorageInitError(t *testing.T) {
 	cfg.Storage.DefaultBackend = "nonexistent-backend"
 
 	r := gin.New()
-	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, cfg, nil, nil))
+	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, cfg, nil, nil, nil, nil, nil, nil))
 
 	mock.ExpectQuery("SELECT.*FROM organizations WHERE name").
 		WillReturnRows(sampleMirrorAPIOrg())
@@ -409,7 +412,7 @@ func TestPlatformIndex_Success_EmptyPlatforms(t *testing.T) {
 	cfg.Server.BaseURL = "http://localhost:8080"
 
 	r := gin.New()
-	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, cfg, nil, nil))
+	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, cfg, nil, nil, nil, nil, nil, nil))
 
 	mock.ExpectQuery("SELECT.*FROM organizations WHERE name").
 		WillReturnRows(sampleMirrorAPIOrg())
@@ -462,7 +465,7 @@ func TestPlatformIndex_Success_WithPlatforms(t *testing.T) {
 	}
 
 	r := gin.New()
-	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, cfg, nil, nil))
+	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, cfg, nil, nil, nil, nil, nil, nil))
 
 	mock.ExpectQuery("SELECT.*FROM organizations WHERE name").
 		WillReturnRows(sampleMirrorAPIOrg())
@@ -507,6 +510,76 @@ func TestPlatformIndex_Success_WithPlatforms(t *testing.T) {
 	}
 }
 
+type statsStore struct {
+	counts []models.ProviderDownloadCount
+}
+
+func (s *statsStore) AddDownloadStats(_ context.Context, counts []models.ProviderDownloadCount) error {
+	s.counts = append(s.counts, counts...)
+	return nil
+}
+
+func TestPlatformIndex_RecordsDownloadStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Storage.DefaultBackend = "local"
+	cfg.Storage.Local.BasePath = tmpDir
+	cfg.Storage.Local.ServeDirectly = true
+	cfg.Server.BaseURL = "http://localhost:8080"
+	archive := filepath.Join(tmpDir, "providers", "hashicorp", "aws", "1.2.3", "linux_amd64.zip")
+	if err := os.MkdirAll(filepath.Dir(archive), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archive, []byte("fake-zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &statsStore{}
+	recorder := downloadstats.NewRecorder(store, 0)
+	r := gin.New()
+	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, cfg, nil, nil, recorder, nil, nil, nil))
+
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE name").
+		WillReturnRows(sampleMirrorAPIOrg())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE.*organization_id").
+		WillReturnRows(sampleMirrorAPIProvider())
+	mock.ExpectQuery("SELECT.*FROM provider_versions WHERE provider_id").
+		WillReturnRows(sampleMirrorVersionGetRow())
+	mock.ExpectQuery("SELECT.*approval_status.*FROM mirrored_provider_versions").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").
+		WillReturnRows(sqlmock.NewRows(mirrorPlatformCols).
+			AddRow("plat-1", "ver-1", "linux", "amd64",
+				"terraform-provider-aws_1.2.3_linux_amd64.zip",
+				"providers/hashicorp/aws/1.2.3/linux_amd64.zip",
+				"local", 1024, "abc123def", nil, 0))
+
+	req := httptest.NewRequest("GET", "/providers/registry.terraform.io/hashicorp/aws/1.2.3.json", nil)
+	req.Header.Set("User-Agent", "Terraform/1.9.0 (+https://www.terraform.io) linux_amd64")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if err := recorder.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.counts) != 1 {
+		t.Fatalf("recorded %+v, want one count", store.counts)
+	}
+	got := store.counts[0]
+	if got.ProviderID != "prov-1" || got.Version != "1.2.3" || got.OS != "linux" || got.Arch != "amd64" || got.Downloads != 1 {
+		t.Errorf("recorded %+v", got)
+	}
+}
+
 func TestPlatformIndex_VersionWithoutJsonSuffix(t *testing.T) {
 	// Short version string (< 5 chars) should not strip .json
 	_, r := newMirrorAPIRouter(t)
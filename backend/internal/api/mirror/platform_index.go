@@ -14,9 +14,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/licensegate"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/downloadstats"
 	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
@@ -25,7 +27,7 @@ import (
 )
 
 // @Summary      Network mirror provider platform index
-// @Description  Returns download URLs and hashes for all platforms of a specific provider version, per the Terraform Network Mirror Protocol.
+// @Description  Returns download URLs and hashes for all platforms of a specific provider version, per the Terraform Network Mirror Protocol. A provider only private mirrors synced is served only to members of their organization (authenticate with a JWT, API key, or Terraform CLI token); others get 404.
 // @Tags         Mirror Protocol
 // @Produce      json
 // @Param        hostname     path  string  true  "Origin registry hostname (e.g. registry.terraform.io)"
@@ -34,15 +36,24 @@ import (
 // @Param        versionfile  path  string  true  "Version with .json suffix (e.g. 1.2.3.json)"
 // @Success      200  {object}  mirror.MirrorPlatformIndexResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid version format"
+// @Failure      403  {object}  map[string]interface{}  "Provider is license-gated and the request is anonymous"
 // @Failure      404  {object}  map[string]interface{}  "Provider or version not found"
+// @Failure      451  {object}  map[string]interface{}  "Provider is license-gated and the user has not accepted its current license version"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /terraform/providers/{hostname}/{namespace}/{type}/{versionfile} [get]
 // PlatformIndexHandler handles network mirror platform index requests
 // Implements: GET /terraform/providers/:hostname/:namespace/:type/:version.json
 // Returns download URLs and hashes for all platforms of a specific version
-func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositories.AuditRepository, pullThrough *services.PullThroughService) gin.HandlerFunc {
+// The requesting client's platform (from its User-Agent) is counted in stats
+// (nil: not recorded) for the admin provider stats. Under a hostname alias
+// (aliases, nil: none) only versions the alias's mirror synced are served,
+// and private mirrors' providers only to members of their organization
+// (visibility, nil: no restriction). A license-gated provider is only served
+// to users who accepted its license (licenses, nil: no gating).
+func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositories.AuditRepository, pullThrough *services.PullThroughService, stats *downloadstats.Recorder, aliases *middleware.MirrorHostnameAliases, visibility *Visibility, licenses *licensegate.Gate) gin.HandlerFunc {
 	providerRepo := repositories.NewProviderRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
+	aliasRepo := repositories.NewMirrorHostnameAliasRepository(db)
 
 	// storageBackend is initialized exactly once on the first request that reaches
 	// the download-URL generation step.  Using sync.Once avoids both re-initialising
@@ -55,7 +66,8 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 	)
 
 	return func(c *gin.Context) {
-		// Note: hostname is in the path for compatibility with Network Mirror Protocol
+		// Note: hostname is in the path for compatibility with Network Mirror Protocol.
+		// Providers are not keyed by it; it only matters for hostname aliases.
 		hostname := c.Param("hostname")
 		namespace := c.Param("namespace")
 		providerType := c.Param("type")
@@ -69,9 +81,6 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 			version = version[:len(version)-5]
 		}
 
-		// Log hostname for debugging (not used in single-tenant mode)
-		_ = hostname
-
 		// Validate semantic versioning
 		if err := validation.ValidateSemver(version); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -80,6 +89,14 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 			return
 		}
 
+		alias, err := aliases.Lookup(c.Request.Context(), hostname, namespace)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load mirror hostname aliases",
+			})
+			return
+		}
+
 		// Get organization context (default org for single-tenant mode)
 		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
 		if err != nil {
@@ -104,10 +121,17 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 			return
 		}
 
+		provider, hiddenIDs, ok := visibility.servedProvider(c, providerRepo, provider, namespace, providerType)
+		if !ok {
+			return
+		}
+
 		if provider == nil {
-			// Cache miss — attempt pull-through if configured
-			if pullThrough != nil {
+			// Cache miss — attempt pull-through if configured (never under an
+			// alias, which could not serve what it fetches).
+			if pullThrough != nil && alias == nil {
 				configs, err := pullThrough.GetConfigsForProvider(c.Request.Context(), org.ID, namespace, providerType)
+				configs = visibility.pullThroughConfigs(c, configs)
 				if err != nil || len(configs) == 0 {
 					c.Data(http.StatusNotFound, "application/json", []byte(`{"errors":["provider not found"]}`))
 					return
@@ -128,6 +152,10 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 			}
 		}
 
+		if !licenses.Allow(c, provider) {
+			return
+		}
+
 		// Get provider version
 		providerVersion, err := providerRepo.GetVersion(c.Request.Context(), provider.ID, version)
 		if err != nil {
@@ -137,10 +165,19 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 			return
 		}
 
+		if providerVersion != nil && hiddenIDs[providerVersion.ID] {
+			c.Data(http.StatusNotFound, "application/json", []byte(`{"errors":["provider version not found"]}`))
+			return
+		}
+
 		if providerVersion == nil {
-			// Version not in local DB — attempt pull-through if not already tried
-			if pullThrough != nil {
+			// Version not in local DB — attempt pull-through if not already
+			// tried. Pull-through fills the default organization's copy, so
+			// it is skipped when another organization's copy is being served.
+			defaultCopy := provider.OrganizationID == "" || provider.OrganizationID == org.ID
+			if pullThrough != nil && alias == nil && defaultCopy {
 				configs, err := pullThrough.GetConfigsForProvider(c.Request.Context(), org.ID, namespace, providerType)
+				configs = visibility.pullThroughConfigs(c, configs)
 				if err != nil || len(configs) == 0 {
 					c.Data(http.StatusNotFound, "application/json", []byte(`{"errors":["provider version not found"]}`))
 					return
@@ -160,6 +197,22 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 			}
 		}
 
+		// Under an alias, serve only what the alias's mirror synced, with the
+		// same 404 as a missing version.
+		if alias != nil {
+			ids, ok, err := aliasedVersionIDs(c.Request.Context(), aliasRepo, alias, hostname, provider.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to query provider version",
+				})
+				return
+			}
+			if !ok || (ids != nil && !ids[providerVersion.ID]) {
+				c.Data(http.StatusNotFound, "application/json", []byte(`{"errors":["provider version not found"]}`))
+				return
+			}
+		}
+
 		// Enforce the version approval gate: a mirrored version still pending approval
 		// or rejected must not be resolvable here even by direct version reference — it
 		// is already hidden from IndexHandler's version listing and gated on the
@@ -241,7 +294,7 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 
 			// Add to archives
 			archives[platformKey] = gin.H{
-				"url":    downloadURL,
+				"url":    middleware.TenantURL(c, downloadURL),
 				"hashes": hashes,
 			}
 		}
@@ -312,10 +365,13 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 		// counter there.  For every other configuration (S3, Azure, GCS, or local
 		// without ServeDirectly) the binary is delivered from a signed/external URL
 		// and ServeFileHandler is never called, so this is the only place to count it.
-		if cfg.Storage.DefaultBackend != "local" || !cfg.Storage.Local.ServeDirectly {
-			if clientOS, clientArch := parseTerraformPlatform(c.GetHeader("User-Agent")); clientOS != "" {
-				for _, platform := range platforms {
-					if platform.OS == clientOS && platform.Arch == clientArch {
+		// The per-day stats are recorded here in every configuration: the files
+		// route cannot tell a mirror fetch from a registry-protocol one.
+		if clientOS, clientArch := parseTerraformPlatform(c.GetHeader("User-Agent")); clientOS != "" {
+			for _, platform := range platforms {
+				if platform.OS == clientOS && platform.Arch == clientArch {
+					stats.Record(provider.ID, providerVersion.Version, clientOS, clientArch)
+					if cfg.Storage.DefaultBackend != "local" || !cfg.Storage.Local.ServeDirectly {
 						platformID := platform.ID
 						go func() {
 							if err := providerRepo.IncrementDownloadCount(context.Background(), platformID); err != nil {
@@ -323,8 +379,8 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config, auditRepo *repositorie
 							}
 						}()
 						telemetry.ProviderDownloadsTotal.WithLabelValues(namespace, providerType, clientOS, clientArch).Inc()
-						break
 					}
+					break
 				}
 			}
 		}
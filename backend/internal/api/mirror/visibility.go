@@ -0,0 +1,218 @@
+// visibility.go enforces org-scoped mirror visibility on the network mirror
+// handlers: the providers of a private mirror (models.MirrorConfiguration.Private)
+// are served only to members of the mirror's organization.
+package mirror
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// mirrorVisibilityStore is the subset of MirrorRepository the visibility
+// check needs.
+type mirrorVisibilityStore interface {
+	ListProviderCopySources(ctx context.Context, namespace, providerType string) ([]models.ProviderCopySource, error)
+	ListVersionMirrorConfigs(ctx context.Context, providerID string) (map[string][]string, error)
+}
+
+// Visibility decides which stored copy of a provider, and which of its
+// versions, a network mirror request may be served.
+//
+// Mirrors store their providers in their own organization, so the same
+// provider can exist as several copies. A copy is public when a public mirror
+// synced it, or, for the default organization's copy, when no mirror did (it
+// was uploaded). A copy only private mirrors synced is served to members of
+// those mirrors' organizations and is a 404 for everyone else. The public copy
+// wins when both exist, and versions of a public copy that only a private
+// mirror synced are withheld from non-members.
+//
+// A nil *Visibility serves the default organization's copy to everyone.
+type Visibility struct {
+	store mirrorVisibilityStore
+}
+
+// NewVisibility creates the visibility check backed by the mirror repository.
+func NewVisibility(repo *repositories.MirrorRepository) *Visibility {
+	return &Visibility{store: repo}
+}
+
+// visibleCopy is the outcome of Visibility.resolve.
+type visibleCopy struct {
+	// providerID is the copy to serve; "" when none may be.
+	providerID string
+	// hidden holds the IDs of the copy's versions withheld from the requester.
+	hidden map[string]bool
+	// withheld is true when a copy exists that the requester may not see, so
+	// the provider must be a 404 rather than fetched through pull-through.
+	withheld bool
+}
+
+// copyVisibility summarises the mirrors one copy was synced from.
+type copyVisibility struct {
+	mirrored bool // at least one mirror synced it
+	public   bool // a public mirror synced it
+	member   bool // a private mirror of one of the requester's organizations synced it
+	hidden   bool // a private mirror of another organization synced it
+}
+
+// resolve picks the copy of namespace/providerType to serve to a requester
+// belonging to orgIDs. defaultID is the default organization's copy ("" when
+// there is none).
+func (v *Visibility) resolve(ctx context.Context, defaultID, namespace, providerType string, orgIDs []string) (visibleCopy, error) {
+	sources, err := v.store.ListProviderCopySources(ctx, namespace, providerType)
+	if err != nil {
+		return visibleCopy{}, err
+	}
+
+	member := make(map[string]bool, len(orgIDs))
+	for _, id := range orgIDs {
+		member[id] = true
+	}
+	hiddenConfigs := make(map[string]bool)
+
+	var order []string
+	copies := make(map[string]*copyVisibility)
+	for _, s := range sources {
+		cv, ok := copies[s.ProviderID]
+		if !ok {
+			cv = &copyVisibility{}
+			copies[s.ProviderID] = cv
+			order = append(order, s.ProviderID)
+		}
+		if s.MirrorConfigID == nil {
+			continue
+		}
+		cv.mirrored = true
+		switch {
+		case !s.Private || s.OrganizationID == nil:
+			cv.public = true
+		case member[*s.OrganizationID]:
+			cv.member = true
+		default:
+			cv.hidden = true
+			hiddenConfigs[*s.MirrorConfigID] = true
+		}
+	}
+
+	pick := ""
+	if defaultID != "" {
+		if cv, ok := copies[defaultID]; !ok || !cv.mirrored || cv.public {
+			pick = defaultID
+		}
+	}
+	for _, id := range order {
+		if pick != "" {
+			break
+		}
+		if copies[id].public {
+			pick = id
+		}
+	}
+	if pick == "" && defaultID != "" && copies[defaultID].member {
+		pick = defaultID
+	}
+	for _, id := range order {
+		if pick != "" {
+			break
+		}
+		if copies[id].member {
+			pick = id
+		}
+	}
+
+	if pick == "" {
+		for _, cv := range copies {
+			if cv.hidden {
+				return visibleCopy{withheld: true}, nil
+			}
+		}
+		return visibleCopy{}, nil
+	}
+
+	result := visibleCopy{providerID: pick}
+	if cv, ok := copies[pick]; ok && cv.hidden {
+		versionConfigs, err := v.store.ListVersionMirrorConfigs(ctx, pick)
+		if err != nil {
+			return visibleCopy{}, err
+		}
+		result.hidden = make(map[string]bool)
+		for versionID, configIDs := range versionConfigs {
+			withhold := true
+			for _, id := range configIDs {
+				if !hiddenConfigs[id] {
+					withhold = false
+					break
+				}
+			}
+			if withhold {
+				result.hidden[versionID] = true
+			}
+		}
+	}
+	return result, nil
+}
+
+// servedProvider applies the visibility rules to provider, the default
+// organization's copy (nil: none). It returns the provider to serve (nil when
+// nothing is stored that the requester may see, so pull-through may run) and
+// the version IDs to withhold. When ok is false the response has been written.
+func (v *Visibility) servedProvider(c *gin.Context, providerRepo *repositories.ProviderRepository, provider *models.Provider, namespace, providerType string) (served *models.Provider, hidden map[string]bool, ok bool) {
+	if v == nil {
+		return provider, nil, true
+	}
+
+	defaultID := ""
+	if provider != nil {
+		defaultID = provider.ID
+	}
+	orgIDs, _ := middleware.OrganizationIDsFromContext(c)
+	vc, err := v.resolve(c.Request.Context(), defaultID, namespace, providerType, orgIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to query provider",
+		})
+		return nil, nil, false
+	}
+	if vc.withheld {
+		c.Data(http.StatusNotFound, "application/json", []byte(`{"errors":["provider not found"]}`))
+		return nil, nil, false
+	}
+	if vc.providerID == "" || vc.providerID == defaultID {
+		return provider, vc.hidden, true
+	}
+
+	served, err = providerRepo.GetProviderByID(c.Request.Context(), vc.providerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to query provider",
+		})
+		return nil, nil, false
+	}
+	return served, vc.hidden, true
+}
+
+// pullThroughConfigs drops the private mirrors of organizations the
+// requester does not belong to, so pull-through cannot fetch through them.
+func (v *Visibility) pullThroughConfigs(c *gin.Context, configs []*models.MirrorConfiguration) []*models.MirrorConfiguration {
+	if v == nil {
+		return configs
+	}
+	orgIDs, _ := middleware.OrganizationIDsFromContext(c)
+	member := make(map[string]bool, len(orgIDs))
+	for _, id := range orgIDs {
+		member[id] = true
+	}
+	var visible []*models.MirrorConfiguration
+	for _, cfg := range configs {
+		if cfg.Private && cfg.OrganizationID != nil && !member[cfg.OrganizationID.String()] {
+			continue
+		}
+		visible = append(visible, cfg)
+	}
+	return visible
+}
@@ -0,0 +1,288 @@
+package mirror
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// fakeVisibilityStore serves fixed copy sources and per-version mirror IDs.
+type fakeVisibilityStore struct {
+	sources        []models.ProviderCopySource
+	versionConfigs map[string][]string
+}
+
+func (f *fakeVisibilityStore) ListProviderCopySources(_ context.Context, _, _ string) ([]models.ProviderCopySource, error) {
+	return f.sources, nil
+}
+
+func (f *fakeVisibilityStore) ListVersionMirrorConfigs(_ context.Context, _ string) (map[string][]string, error) {
+	return f.versionConfigs, nil
+}
+
+func strPtr(s string) *string { return &s }
+
+func copySource(providerID, configID, orgID string, private bool) models.ProviderCopySource {
+	s := models.ProviderCopySource{ProviderID: providerID, Private: private}
+	if configID != "" {
+		s.MirrorConfigID = strPtr(configID)
+	}
+	if orgID != "" {
+		s.OrganizationID = strPtr(orgID)
+	}
+	return s
+}
+
+func TestVisibilityResolve(t *testing.T) {
+	tests := []struct {
+		name         string
+		sources      []models.ProviderCopySource
+		defaultID    string
+		orgIDs       []string
+		wantID       string
+		wantWithheld bool
+	}{
+		{
+			name:      "uploaded default copy",
+			sources:   []models.ProviderCopySource{copySource("p-default", "", "", false)},
+			defaultID: "p-default",
+			wantID:    "p-default",
+		},
+		{
+			name:      "public mirror",
+			sources:   []models.ProviderCopySource{copySource("p-default", "mc-1", "org-a", false)},
+			defaultID: "p-default",
+			wantID:    "p-default",
+		},
+		{
+			name:         "private mirror, anonymous",
+			sources:      []models.ProviderCopySource{copySource("p-default", "mc-1", "org-a", true)},
+			defaultID:    "p-default",
+			wantWithheld: true,
+		},
+		{
+			name:         "private mirror, other organization",
+			sources:      []models.ProviderCopySource{copySource("p-default", "mc-1", "org-a", true)},
+			defaultID:    "p-default",
+			orgIDs:       []string{"org-b"},
+			wantWithheld: true,
+		},
+		{
+			name:      "private mirror, member",
+			sources:   []models.ProviderCopySource{copySource("p-default", "mc-1", "org-a", true)},
+			defaultID: "p-default",
+			orgIDs:    []string{"org-b", "org-a"},
+			wantID:    "p-default",
+		},
+		{
+			name:    "private copy in another organization, member",
+			sources: []models.ProviderCopySource{copySource("p-org-a", "mc-1", "org-a", true)},
+			orgIDs:  []string{"org-a"},
+			wantID:  "p-org-a",
+		},
+		{
+			name: "public copy preferred over the member's private copy",
+			sources: []models.ProviderCopySource{
+				copySource("p-org-a", "mc-1", "org-a", true),
+				copySource("p-org-b", "mc-2", "org-b", false),
+			},
+			orgIDs: []string{"org-a"},
+			wantID: "p-org-b",
+		},
+		{
+			name: "hidden private default copy falls back to a public copy",
+			sources: []models.ProviderCopySource{
+				copySource("p-default", "mc-1", "org-a", true),
+				copySource("p-org-b", "mc-2", "org-b", false),
+			},
+			defaultID: "p-default",
+			wantID:    "p-org-b",
+		},
+		{
+			name:    "nothing stored",
+			sources: nil,
+			wantID:  "",
+		},
+		{
+			name:    "private mirror without organization is public",
+			sources: []models.ProviderCopySource{copySource("p-default", "mc-1", "", true)},
+			wantID:  "p-default",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Visibility{store: &fakeVisibilityStore{sources: tt.sources}}
+			got, err := v.resolve(context.Background(), tt.defaultID, "hashicorp", "aws", tt.orgIDs)
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			if got.providerID != tt.wantID || got.withheld != tt.wantWithheld {
+				t.Errorf("resolve = (%q, withheld=%v), want (%q, withheld=%v)", got.providerID, got.withheld, tt.wantID, tt.wantWithheld)
+			}
+		})
+	}
+}
+
+func TestVisibilityResolve_HidesPrivateOnlyVersions(t *testing.T) {
+	store := &fakeVisibilityStore{
+		sources: []models.ProviderCopySource{
+			copySource("p-default", "mc-public", "org-a", false),
+			copySource("p-default", "mc-private", "org-a", true),
+		},
+		versionConfigs: map[string][]string{
+			"v-both":    {"mc-public", "mc-private"},
+			"v-public":  {"mc-public"},
+			"v-private": {"mc-private"},
+		},
+	}
+	v := &Visibility{store: store}
+
+	got, err := v.resolve(context.Background(), "p-default", "hashicorp", "aws", nil)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got.providerID != "p-default" {
+		t.Fatalf("providerID = %q, want p-default", got.providerID)
+	}
+	if !got.hidden["v-private"] || got.hidden["v-both"] || got.hidden["v-public"] {
+		t.Errorf("hidden = %v, want only v-private", got.hidden)
+	}
+
+	got, err = v.resolve(context.Background(), "p-default", "hashicorp", "aws", []string{"org-a"})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(got.hidden) != 0 {
+		t.Errorf("member: hidden = %v, want none", got.hidden)
+	}
+}
+
+func TestVisibilityPullThroughConfigs(t *testing.T) {
+	orgA := uuid.New()
+	public := &models.MirrorConfiguration{Name: "public", OrganizationID: &orgA}
+	private := &models.MirrorConfiguration{Name: "private", OrganizationID: &orgA, Private: true}
+	configs := []*models.MirrorConfiguration{private, public}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	v := &Visibility{store: &fakeVisibilityStore{}}
+	if got := v.pullThroughConfigs(c, configs); len(got) != 1 || got[0] != public {
+		t.Errorf("anonymous: got %d configs, want only the public one", len(got))
+	}
+
+	c.Set(middleware.OrganizationIDsContextKey, []string{orgA.String()})
+	if got := v.pullThroughConfigs(c, configs); len(got) != 2 {
+		t.Errorf("member: got %d configs, want 2", len(got))
+	}
+
+	var nilVisibility *Visibility
+	if got := nilVisibility.pullThroughConfigs(c, configs); len(got) != 2 {
+		t.Errorf("nil visibility: got %d configs, want 2", len(got))
+	}
+}
+
+// newVisibilityMirrorRouter is newMirrorAPIRouter with the visibility check
+// backed by store and the requester acting for orgIDs (nil: anonymous).
+func newVisibilityMirrorRouter(t *testing.T, store mirrorVisibilityStore, orgIDs []string) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	v := &Visibility{store: store}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if orgIDs != nil {
+			c.Set(middleware.OrganizationIDsContextKey, orgIDs)
+		}
+		c.Next()
+	})
+	r.GET("/providers/:hostname/:namespace/:type/index.json", IndexHandler(db, &config.Config{}, nil, nil, v))
+	r.GET("/providers/:hostname/:namespace/:type/:versionfile", PlatformIndexHandler(db, &config.Config{}, nil, nil, nil, nil, v, nil))
+	return mock, r
+}
+
+func TestIndex_PrivateMirrorWithheld(t *testing.T) {
+	store := &fakeVisibilityStore{sources: []models.ProviderCopySource{copySource("prov-1", "mc-1", "org-a", true)}}
+	mock, r := newVisibilityMirrorRouter(t, store, []string{"org-b"})
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE name").
+		WillReturnRows(sampleMirrorAPIOrg())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE.*organization_id").
+		WillReturnRows(sampleMirrorAPIProvider())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/registry.terraform.io/hashicorp/aws/index.json", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestIndex_PrivateOnlyVersionsHidden(t *testing.T) {
+	store := &fakeVisibilityStore{
+		sources: []models.ProviderCopySource{
+			copySource("prov-1", "mc-public", "org-a", false),
+			copySource("prov-1", "mc-private", "org-a", true),
+		},
+		versionConfigs: map[string][]string{"ver-2": {"mc-private"}},
+	}
+	mock, r := newVisibilityMirrorRouter(t, store, nil)
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE name").
+		WillReturnRows(sampleMirrorAPIOrg())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE.*organization_id").
+		WillReturnRows(sampleMirrorAPIProvider())
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE pv.provider_id").
+		WillReturnRows(sqlmock.NewRows(mirrorVersionCols).
+			AddRow("ver-1", "prov-1", "1.0.0", []byte(`["6.0"]`), "", "", "", nil, nil, nil, nil, false, nil, nil, time.Now()).
+			AddRow("ver-2", "prov-1", "2.0.0", []byte(`["6.0"]`), "", "", "", nil, nil, nil, nil, false, nil, nil, time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/registry.terraform.io/hashicorp/aws/index.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"1.0.0"`) || strings.Contains(body, `"2.0.0"`) {
+		t.Errorf("body = %s, want 1.0.0 listed and 2.0.0 withheld", body)
+	}
+}
+
+func TestPlatformIndex_PrivateOnlyVersionNotFound(t *testing.T) {
+	store := &fakeVisibilityStore{
+		sources: []models.ProviderCopySource{
+			copySource("prov-1", "mc-public", "org-a", false),
+			copySource("prov-1", "mc-private", "org-a", true),
+		},
+		versionConfigs: map[string][]string{"ver-2": {"mc-private"}},
+	}
+	mock, r := newVisibilityMirrorRouter(t, store, nil)
+	mock.ExpectQuery("SELECT.*FROM organizations WHERE name").
+		WillReturnRows(sampleMirrorAPIOrg())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE.*organization_id").
+		WillReturnRows(sampleMirrorAPIProvider())
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE.*provider_id").
+		WillReturnRows(sqlmock.NewRows(mirrorVersionGetCols).
+			AddRow("ver-2", "prov-1", "2.0.0", []byte(`["6.0"]`), "", "", "", nil, nil, nil, false, nil, nil, time.Now()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/providers/registry.terraform.io/hashicorp/aws/2.0.0.json", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,24 @@
+// approval.go resolves which organizations' approved_only module policies
+// apply to the caller of a protocol endpoint.
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// enforcingOrganizationIDs returns the caller's organizations that restrict
+// module consumption to approved versions. An empty result means no
+// restriction applies; anonymous callers never trigger a policy lookup.
+//
+// The caller's organizations are the ones OptionalAuthMiddleware resolved: the
+// key's organization for org-scoped API keys, otherwise every organization the
+// user belongs to.
+func enforcingOrganizationIDs(c *gin.Context, approvalRepo *repositories.ModuleApprovalRepository) ([]string, error) {
+	orgIDs, _ := middleware.OrganizationIDsFromContext(c)
+	if len(orgIDs) == 0 {
+		return nil, nil
+	}
+	return approvalRepo.ApprovedOnlyOrganizations(c.Request.Context(), orgIDs)
+}
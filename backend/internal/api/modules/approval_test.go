@@ -0,0 +1,143 @@
+package modules
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// withCaller injects an org-scoped API key and the organization context the
+// auth middleware resolves for it.
+func withCaller(orgID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("api_key", &models.APIKey{ID: "key-1", OrganizationID: orgID})
+		c.Set("organization_id", orgID)
+		c.Set(middleware.OrganizationIDsContextKey, []string{orgID})
+		c.Next()
+	}
+}
+
+func newApprovalRouter(t *testing.T, caller gin.HandlerFunc) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.Use(caller)
+	r.GET("/v1/modules/:namespace/:name/:system/versions", ListVersionsHandler(db, &config.Config{}))
+	r.GET("/v1/modules/:namespace/:name/:system/:version/download",
+		DownloadHandler(db, &mockStore{getURLResult: "https://example.com/module.tgz"}, &config.Config{}, nil))
+	return mock, r
+}
+
+func TestListVersionsHandler_ApprovedOnlyFiltersVersions(t *testing.T) {
+	mock, r := newApprovalRouter(t, withCaller("org-a"))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT organization_id FROM org_module_policies").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow("org-a"))
+	mock.ExpectQuery("SELECT COUNT.*FROM module_versions mv.*module_version_approvals").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM module_versions mv.*module_version_approvals").
+		WillReturnRows(sampleModuleVersionsRows())
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/versions")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestListVersionsHandler_NoPolicyListsAll(t *testing.T) {
+	mock, r := newApprovalRouter(t, withCaller("org-a"))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT organization_id FROM org_module_policies").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id"}))
+	mock.ExpectQuery("SELECT COUNT.*FROM module_versions WHERE module_id").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM module_versions mv.*ORDER BY").WillReturnRows(sampleModuleVersionsRows())
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/versions")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListVersionsHandler_PolicyError(t *testing.T) {
+	mock, r := newApprovalRouter(t, withCaller("org-a"))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT organization_id FROM org_module_policies").WillReturnError(errDB2)
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/versions")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
+
+func TestDownloadHandler_UnapprovedVersionForbidden(t *testing.T) {
+	mock, r := newApprovalRouter(t, withCaller("org-a"))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").WillReturnRows(sampleModuleVersionGetRow())
+	mock.ExpectQuery("SELECT organization_id FROM org_module_policies").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow("org-a"))
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/1.0.0/download")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403; body: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Terraform-Get") != "" {
+		t.Error("X-Terraform-Get must not be set for an unapproved version")
+	}
+}
+
+func TestDownloadHandler_ApprovedVersionAllowed(t *testing.T) {
+	// A user in one organization; the handler reads the memberships the auth
+	// middleware resolved rather than querying them.
+	mock, r := newApprovalRouter(t, func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set(middleware.OrganizationIDsContextKey, []string{"org-a"})
+		c.Next()
+	})
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").WillReturnRows(sampleModuleVersionGetRow())
+	mock.ExpectQuery("SELECT organization_id FROM org_module_policies").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow("org-a"))
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec("UPDATE module_versions").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/1.0.0/download")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204; body: %s", w.Code, w.Body.String())
+	}
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestDownloadHandler_AnonymousSkipsPolicy(t *testing.T) {
+	mock, r := newApprovalRouter(t, func(c *gin.Context) { c.Next() })
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").WillReturnRows(sampleModuleVersionGetRow())
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/1.0.0/download")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204; body: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,72 @@
+// changelog.go implements the per-version module changelog endpoint for the modules package.
+package modules
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// @Summary      Get module version changelog
+// @Description  Returns the release notes recorded for a module version: the matching CHANGELOG.md section or annotated tag message for SCM-published versions, or the changelog supplied at upload time. The content is sanitized markdown.
+// @Tags         Modules
+// @Produce      json
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
+// @Param        version    path  string  true  "Module version"
+// @Success      200  {object}  modules.ModuleChangelogResponse
+// @Failure      404  {object}  map[string]interface{}  "Module, version, or changelog not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/modules/{namespace}/{name}/{system}/versions/{version}/changelog [get]
+func GetModuleChangelogHandler(db *sql.DB) gin.HandlerFunc {
+	moduleRepo := repositories.NewModuleRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+
+	return func(c *gin.Context) {
+		namespace := c.Param("namespace")
+		name := c.Param("name")
+		system := c.Param("system")
+		version := c.Param("version")
+
+		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+		if err != nil || org == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization context"})
+			return
+		}
+
+		module, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module"})
+			return
+		}
+		if module == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "module not found"})
+			return
+		}
+
+		mv, err := moduleRepo.GetVersion(c.Request.Context(), module.ID, version)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module version"})
+			return
+		}
+		if mv == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "module version not found"})
+			return
+		}
+
+		changelog, err := moduleRepo.GetVersionChangelog(c.Request.Context(), mv.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module changelog"})
+			return
+		}
+		if changelog == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no changelog found for this module version"})
+			return
+		}
+
+		c.JSON(http.StatusOK, ModuleChangelogResponse{Version: mv.Version, Changelog: *changelog})
+	}
+}
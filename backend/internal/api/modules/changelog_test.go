@@ -0,0 +1,101 @@
+package modules
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func newChangelogAPIRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.GET("/api/v1/modules/:namespace/:name/:system/versions/:version/changelog",
+		GetModuleChangelogHandler(db))
+	return mock, r
+}
+
+func expectChangelogVersionLookup(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id").
+		WithArgs("mod-1", "1.0.0").
+		WillReturnRows(sampleVersionGetRowForDocs())
+}
+
+func TestGetModuleChangelog_Success(t *testing.T) {
+	mock, r := newChangelogAPIRouter(t)
+	expectChangelogVersionLookup(mock)
+	mock.ExpectQuery("SELECT changelog FROM module_versions").
+		WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"changelog"}).AddRow("### Added\n- things"))
+
+	w := doGET(r, "/api/v1/modules/hashicorp/consul/aws/versions/1.0.0/changelog")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var resp ModuleChangelogResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Version != "1.0.0" || resp.Changelog != "### Added\n- things" {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestGetModuleChangelog_NoChangelog(t *testing.T) {
+	mock, r := newChangelogAPIRouter(t)
+	expectChangelogVersionLookup(mock)
+	mock.ExpectQuery("SELECT changelog FROM module_versions").
+		WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"changelog"}).AddRow(nil))
+
+	w := doGET(r, "/api/v1/modules/hashicorp/consul/aws/versions/1.0.0/changelog")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestGetModuleChangelog_ChangelogDBError(t *testing.T) {
+	mock, r := newChangelogAPIRouter(t)
+	expectChangelogVersionLookup(mock)
+	mock.ExpectQuery("SELECT changelog FROM module_versions").WillReturnError(errDB2)
+
+	w := doGET(r, "/api/v1/modules/hashicorp/consul/aws/versions/1.0.0/changelog")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
+
+func TestGetModuleChangelog_VersionNotFound(t *testing.T) {
+	mock, r := newChangelogAPIRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id").
+		WithArgs("mod-1", "9.9.9").
+		WillReturnRows(sqlmock.NewRows(moduleVersionGetColsDoc))
+
+	w := doGET(r, "/api/v1/modules/hashicorp/consul/aws/versions/9.9.9/changelog")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestGetModuleChangelog_ModuleNotFound(t *testing.T) {
+	mock, r := newChangelogAPIRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").
+		WillReturnRows(sqlmock.NewRows(moduleCols2))
+
+	w := doGET(r, "/api/v1/modules/hashicorp/consul/aws/versions/1.0.0/changelog")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,148 @@
+// code_search.go implements code search: finding the module versions whose
+// .tf files declare a given resource type, data source, variable or module
+// call, or whose blocks mention a word. It reads the content index built by
+// jobs.CodeIndexJob.
+package modules
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/analyzer"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// codeSearchLimits are the page size default and cap of code search, in
+// module versions.
+var codeSearchLimits = pagination.Limits{Default: 20, Max: 50}
+
+// Caps on what code search returns per module version.
+const (
+	codeSearchMatchesPerVersion = 10
+	codeSearchSnippetLines      = 5
+)
+
+// CodeSearchResponse is returned by GET /api/v1/search/code.
+type CodeSearchResponse struct {
+	Results []*models.ModuleCodeSearchResult `json:"results"`
+	Meta    pagination.Meta                  `json:"meta"`
+}
+
+// @Summary      Search module code
+// @Description  Finds the module versions whose .tf files declare a resource type (resource), data source type (data), variable (variable) or module call (module, matching the call name or its source), and/or whose declaring blocks contain the words of q. At least one of them is required, and at most one of resource, data, variable and module. Each result is a module version, newest first within a module, with up to 10 matching blocks: their file path, line range and a snippet of the block's first line and the lines containing a word of q. match_count is the total number of matching blocks. Archived versions are not searched. Versions are indexed in the background shortly after they are published; only the first code_index.max_bytes_per_version bytes of each version's .tf files are indexed. Answers 404 when code search is disabled (code_index.enabled=false).
+// @Tags         Modules
+// @Produce      json
+// @Param        resource   query  string  false  "Resource type, e.g. aws_eks_cluster"
+// @Param        data       query  string  false  "Data source type, e.g. aws_iam_policy_document"
+// @Param        variable   query  string  false  "Variable name"
+// @Param        module     query  string  false  "Module call name or source"
+// @Param        q          query  string  false  "Words the matching blocks must contain"
+// @Param        namespace  query  string  false  "Filter by namespace"
+// @Param        system     query  string  false  "Filter by target system"
+// @Param        scope      query  string  false  "all to search every namespace on a custom tenant domain; by default results there are limited to the tenant organization's namespaces"
+// @Param        limit      query  int     false  "Maximum module versions to return (default 20, max 50); alias per_page"
+// @Param        offset     query  int     false  "Offset for pagination (default 0); alias page (1-based)"
+// @Success      200  {object}  modules.CodeSearchResponse
+// @Failure      400  {object}  map[string]interface{}  "No search criteria, or more than one of resource, data, variable and module"
+// @Failure      404  {object}  map[string]interface{}  "Code search disabled"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/search/code [get]
+// CodeSearchHandler handles code search requests.
+// Implements: GET /api/v1/search/code?resource=<type>&q=<words>
+func CodeSearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
+	codeIndexRepo := repositories.NewModuleCodeIndexRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+
+	return func(c *gin.Context) {
+		if !cfg.CodeIndex.Enabled {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Code search is not enabled"})
+			return
+		}
+
+		filter := models.CodeSearchFilter{
+			Query:     strings.TrimSpace(c.Query("q")),
+			Namespace: c.Query("namespace"),
+			System:    c.Query("system"),
+		}
+		for _, kind := range analyzer.CodeSymbolKinds {
+			symbol := strings.TrimSpace(c.Query(kind))
+			if symbol == "" {
+				continue
+			}
+			if filter.Kind != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Only one of resource, data, variable and module may be given"})
+				return
+			}
+			filter.Kind, filter.Symbol = kind, symbol
+		}
+		if filter.Kind == "" && filter.Query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "One of resource, data, variable, module or q is required"})
+			return
+		}
+
+		page := pagination.Parse(c, codeSearchLimits)
+
+		var orgID string
+		if cfg.MultiTenancy.Enabled {
+			org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+				return
+			}
+			if org == nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Default organization not found"})
+				return
+			}
+			orgID = org.ID
+		}
+		var ownerOrgID string
+		if c.Query("scope") != "all" {
+			ownerOrgID = middleware.TenantOrganizationID(c)
+		}
+
+		results, total, err := codeIndexRepo.SearchCode(c.Request.Context(), orgID, ownerOrgID, filter,
+			page.Limit, page.Offset, codeSearchMatchesPerVersion)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search module code"})
+			return
+		}
+		for _, res := range results {
+			for i := range res.Matches {
+				res.Matches[i].Lines = codeSnippet(&res.Matches[i], filter.Query)
+			}
+		}
+
+		c.JSON(http.StatusOK, CodeSearchResponse{Results: results, Meta: page.Meta(int64(total))})
+	}
+}
+
+// codeSnippet returns a match's snippet: the block's first line, then the
+// lines containing a word of query, up to codeSearchSnippetLines in all.
+func codeSnippet(m *models.ModuleCodeMatch, query string) []models.CodeLine {
+	lines := strings.Split(m.Body, "\n")
+	snippet := []models.CodeLine{{Line: m.StartLine, Text: lines[0]}}
+
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return snippet
+	}
+	for i := 1; i < len(lines) && len(snippet) < codeSearchSnippetLines; i++ {
+		lower := strings.ToLower(lines[i])
+		for _, w := range words {
+			if strings.Contains(lower, w) {
+				snippet = append(snippet, models.CodeLine{Line: m.StartLine + i, Text: lines[i]})
+				break
+			}
+		}
+	}
+	return snippet
+}
@@ -0,0 +1,98 @@
+package modules
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newCodeSearchRouter(t *testing.T, enabled bool) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	cfg := &config.Config{}
+	cfg.CodeIndex.Enabled = enabled
+	r := gin.New()
+	r.GET("/v1/search/code", CodeSearchHandler(db, cfg))
+	return mock, r
+}
+
+const eksClusterBody = `resource "aws_eks_cluster" "this" {
+  name = var.name
+  capacity_type = "SPOT"
+}`
+
+func TestCodeSearchHandler_Success(t *testing.T) {
+	mock, r := newCodeSearchRouter(t, true)
+	mock.ExpectQuery("SELECT COUNT\\(DISTINCT mv.id\\)").
+		WithArgs("resource", "aws_eks_cluster", "spot").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT mv.id, m.id").
+		WithArgs("resource", "aws_eks_cluster", "spot", 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "module_id", "namespace", "name", "system", "version"}).
+			AddRow("mv-1", "mod-1", "acme", "eks", "aws", "2.0.0"))
+	mock.ExpectQuery("ROW_NUMBER").
+		WithArgs(sqlmock.AnyArg(), "resource", "aws_eks_cluster", "spot", codeSearchMatchesPerVersion).
+		WillReturnRows(sqlmock.NewRows([]string{"module_version_id", "kind", "symbol", "label", "source", "file_path", "start_line", "end_line", "body", "total"}).
+			AddRow("mv-1", "resource", "aws_eks_cluster", "this", nil, "main.tf", 10, 13, eksClusterBody, 1))
+
+	w := doGET(r, "/v1/search/code?resource=aws_eks_cluster&q=spot")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp CodeSearchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 1 || resp.Meta.Total == nil || *resp.Meta.Total != 1 {
+		t.Fatalf("response = %s", w.Body.String())
+	}
+	m := resp.Results[0].Matches[0]
+	want := []models.CodeLine{
+		{Line: 10, Text: `resource "aws_eks_cluster" "this" {`},
+		{Line: 12, Text: `  capacity_type = "SPOT"`},
+	}
+	if m.FilePath != "main.tf" || len(m.Lines) != 2 || m.Lines[0] != want[0] || m.Lines[1] != want[1] {
+		t.Errorf("match = %+v, want lines %+v", m, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestCodeSearchHandler_BadRequest(t *testing.T) {
+	_, r := newCodeSearchRouter(t, true)
+	for _, path := range []string{
+		"/v1/search/code",
+		"/v1/search/code?namespace=acme",
+		"/v1/search/code?resource=aws_instance&variable=name",
+	} {
+		if w := doGET(r, path); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", path, w.Code)
+		}
+	}
+}
+
+func TestCodeSearchHandler_Disabled(t *testing.T) {
+	_, r := newCodeSearchRouter(t, false)
+	if w := doGET(r, "/v1/search/code?resource=aws_instance"); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestCodeSnippet(t *testing.T) {
+	m := &models.ModuleCodeMatch{StartLine: 1, Body: "variable \"x\" {}"}
+	if got := codeSnippet(m, ""); len(got) != 1 || got[0].Text != `variable "x" {}` {
+		t.Errorf("codeSnippet without query = %+v", got)
+	}
+
+	m = &models.ModuleCodeMatch{StartLine: 1, Body: "a {\nspot 1\nspot 2\nspot 3\nspot 4\nspot 5\n}"}
+	if got := codeSnippet(m, "Spot"); len(got) != codeSearchSnippetLines || got[4].Line != 5 {
+		t.Errorf("codeSnippet = %+v, want %d lines", got, codeSearchSnippetLines)
+	}
+}
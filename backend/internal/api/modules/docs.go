@@ -1,4 +1,6 @@
-// docs.go implements the module documentation endpoint for the modules package.
+// docs.go implements the module documentation endpoints for the modules
+// package: the root module's docs and those of its submodules (the
+// directories under modules/, as documented by the public registry).
 package modules
 
 import (
@@ -6,6 +8,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 )
 
@@ -27,47 +30,156 @@ func GetModuleDocsHandler(db *sql.DB) gin.HandlerFunc {
 	docsRepo := repositories.NewModuleDocsRepository(db)
 
 	return func(c *gin.Context) {
-		namespace := c.Param("namespace")
-		name := c.Param("name")
-		system := c.Param("system")
-		version := c.Param("version")
-
-		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
-		if err != nil || org == nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization context"})
+		mv, ok := lookupDocsModuleVersion(c, orgRepo, moduleRepo)
+		if !ok {
 			return
 		}
 
-		module, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
+		doc, err := docsRepo.GetModuleDocs(c.Request.Context(), mv.ID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module docs"})
 			return
 		}
-		if module == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "module not found"})
+		if doc == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no documentation found for this module version"})
 			return
 		}
 
-		mv, err := moduleRepo.GetVersion(c.Request.Context(), module.ID, version)
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// lookupDocsModuleVersion resolves the module version named by the request
+// path, writing the error response and returning false when it cannot.
+func lookupDocsModuleVersion(c *gin.Context, orgRepo *repositories.OrganizationRepository, moduleRepo *repositories.ModuleRepository) (*models.ModuleVersion, bool) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	system := c.Param("system")
+	version := c.Param("version")
+
+	org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+	if err != nil || org == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization context"})
+		return nil, false
+	}
+
+	module, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module"})
+		return nil, false
+	}
+	if module == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module not found"})
+		return nil, false
+	}
+
+	mv, err := moduleRepo.GetVersion(c.Request.Context(), module.ID, version)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module version"})
+		return nil, false
+	}
+	if mv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module version not found"})
+		return nil, false
+	}
+	return mv, true
+}
+
+// noSubmoduleDocsError is returned when a version has no submodule docs: it
+// has no docs at all, or was analyzed before submodules were documented.
+const noSubmoduleDocsError = "no submodule documentation found for this module version; re-analyze it to generate it"
+
+// @Summary      List module submodules
+// @Description  Lists the submodules (directories directly under modules/) of a module version, as found at upload time. Submodules whose configuration could not be parsed are listed with an error.
+// @Tags         Modules
+// @Produce      json
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
+// @Param        version    path  string  true  "Module version"
+// @Success      200  {object}  map[string]interface{}  "submodules: [{name, path, inputs, outputs, error}] with input and output counts"
+// @Failure      404  {object}  map[string]interface{}  "Module, version, or submodule docs not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/modules/{namespace}/{name}/{system}/versions/{version}/submodules [get]
+func ListModuleSubmodulesHandler(db *sql.DB) gin.HandlerFunc {
+	moduleRepo := repositories.NewModuleRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+	docsRepo := repositories.NewModuleDocsRepository(db)
+
+	return func(c *gin.Context) {
+		mv, ok := lookupDocsModuleVersion(c, orgRepo, moduleRepo)
+		if !ok {
+			return
+		}
+
+		subs, analyzed, err := docsRepo.GetSubmoduleDocs(c.Request.Context(), mv.ID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module version"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module docs"})
 			return
 		}
-		if mv == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "module version not found"})
+		if !analyzed {
+			c.JSON(http.StatusNotFound, gin.H{"error": noSubmoduleDocsError})
 			return
 		}
 
-		doc, err := docsRepo.GetModuleDocs(c.Request.Context(), mv.ID)
+		out := make([]gin.H, 0, len(subs))
+		for _, sub := range subs {
+			entry := gin.H{
+				"name":    sub.Name,
+				"path":    sub.Path,
+				"inputs":  len(sub.Inputs),
+				"outputs": len(sub.Outputs),
+			}
+			if sub.Error != "" {
+				entry["error"] = sub.Error
+			}
+			out = append(out, entry)
+		}
+		c.JSON(http.StatusOK, gin.H{"submodules": out})
+	}
+}
+
+// @Summary      Get submodule documentation
+// @Description  Returns the README, inputs, outputs and providers of one submodule of a module version. error is set when its configuration could not be parsed cleanly.
+// @Tags         Modules
+// @Produce      json
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
+// @Param        version    path  string  true  "Module version"
+// @Param        submodule  path  string  true  "Submodule name (its directory under modules/)"
+// @Success      200  {object}  analyzer.SubmoduleDoc
+// @Failure      404  {object}  map[string]interface{}  "Module, version, or submodule not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/modules/{namespace}/{name}/{system}/versions/{version}/submodules/{submodule} [get]
+func GetModuleSubmoduleHandler(db *sql.DB) gin.HandlerFunc {
+	moduleRepo := repositories.NewModuleRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+	docsRepo := repositories.NewModuleDocsRepository(db)
+
+	return func(c *gin.Context) {
+		mv, ok := lookupDocsModuleVersion(c, orgRepo, moduleRepo)
+		if !ok {
+			return
+		}
+
+		subs, analyzed, err := docsRepo.GetSubmoduleDocs(c.Request.Context(), mv.ID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query module docs"})
 			return
 		}
-		if doc == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no documentation found for this module version"})
+		if !analyzed {
+			c.JSON(http.StatusNotFound, gin.H{"error": noSubmoduleDocsError})
 			return
 		}
 
-		c.JSON(http.StatusOK, doc)
+		name := c.Param("submodule")
+		for _, sub := range subs {
+			if sub.Name == name {
+				c.JSON(http.StatusOK, sub)
+				return
+			}
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "submodule not found"})
 	}
 }
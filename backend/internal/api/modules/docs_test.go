@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
@@ -14,7 +15,7 @@ var moduleVersionGetColsDoc = []string{
 	"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes",
 	"checksum", "readme", "published_by", "download_count",
 	"deprecated", "deprecated_at", "deprecation_message", "replacement_source", "created_at",
-	"commit_sha", "tag_name", "scm_repo_id",
+	"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version",
 }
 
 var docResultCols = []string{"inputs", "outputs", "providers", "requirements"}
@@ -23,7 +24,7 @@ func sampleVersionGetRowForDocs() *sqlmock.Rows {
 	return sqlmock.NewRows(moduleVersionGetColsDoc).
 		AddRow("ver-1", "mod-1", "1.0.0", "path/to/file.tgz", "local",
 			int64(1024), "abc123", nil, nil, int64(0), false, nil, nil, nil, time.Now(),
-			nil, nil, nil)
+			nil, nil, nil, nil)
 }
 
 func sampleDocsResultRow() *sqlmock.Rows {
@@ -173,3 +174,100 @@ func TestGetModuleDocs_DocsDBError(t *testing.T) {
 		t.Errorf("status = %d, want 500", w.Code)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Submodule handlers
+// ---------------------------------------------------------------------------
+
+const sampleSubmodulesJSON = `[
+	{"name":"broken","path":"modules/broken","error":"bad HCL","inputs":[],"outputs":[],"providers":[]},
+	{"name":"network","path":"modules/network","readme":"# Network","inputs":[{"name":"cidr","required":true}],"outputs":[],"providers":[]}
+]`
+
+func newSubmodulesAPIRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.GET("/api/v1/modules/:namespace/:name/:system/versions/:version/submodules",
+		ListModuleSubmodulesHandler(db))
+	r.GET("/api/v1/modules/:namespace/:name/:system/versions/:version/submodules/:submodule",
+		GetModuleSubmoduleHandler(db))
+	return mock, r
+}
+
+func expectSubmodules(mock sqlmock.Sqlmock, submodules interface{}) {
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id").
+		WithArgs("mod-1", "1.0.0").
+		WillReturnRows(sampleVersionGetRowForDocs())
+	mock.ExpectQuery("SELECT submodules FROM module_version_docs").
+		WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"submodules"}).AddRow(submodules))
+}
+
+func TestListModuleSubmodules_Success(t *testing.T) {
+	mock, r := newSubmodulesAPIRouter(t)
+	expectSubmodules(mock, sampleSubmodulesJSON)
+
+	w := doGET(r, "/api/v1/modules/hashicorp/consul/aws/versions/1.0.0/submodules")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Submodules []map[string]interface{} `json:"submodules"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Submodules) != 2 {
+		t.Fatalf("submodules = %v", resp.Submodules)
+	}
+	if resp.Submodules[0]["error"] != "bad HCL" {
+		t.Errorf("broken submodule not marked: %v", resp.Submodules[0])
+	}
+	if resp.Submodules[1]["inputs"] != float64(1) || resp.Submodules[1]["readme"] != nil {
+		t.Errorf("network summary = %v", resp.Submodules[1])
+	}
+}
+
+func TestListModuleSubmodules_NotAnalyzed(t *testing.T) {
+	mock, r := newSubmodulesAPIRouter(t)
+	expectSubmodules(mock, nil)
+
+	w := doGET(r, "/api/v1/modules/hashicorp/consul/aws/versions/1.0.0/submodules")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestGetModuleSubmodule(t *testing.T) {
+	mock, r := newSubmodulesAPIRouter(t)
+	expectSubmodules(mock, sampleSubmodulesJSON)
+
+	w := doGET(r, "/api/v1/modules/hashicorp/consul/aws/versions/1.0.0/submodules/network")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var sub map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &sub); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if sub["readme"] != "# Network" || sub["path"] != "modules/network" {
+		t.Errorf("submodule = %v", sub)
+	}
+}
+
+func TestGetModuleSubmodule_NotFound(t *testing.T) {
+	mock, r := newSubmodulesAPIRouter(t)
+	expectSubmodules(mock, sampleSubmodulesJSON)
+
+	w := doGET(r, "/api/v1/modules/hashicorp/consul/aws/versions/1.0.0/submodules/missing")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
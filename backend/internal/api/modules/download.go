@@ -13,9 +13,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/artifacthead"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/db/transient"
 	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/telemetry"
@@ -23,7 +25,7 @@ import (
 )
 
 // @Summary      Download module version
-// @Description  Returns a 204 response with an X-Terraform-Get header containing the download URL. Implements the Terraform Module Registry Protocol.
+// @Description  Returns a 204 response with an X-Terraform-Get header containing the download URL. Implements the Terraform Module Registry Protocol. A HEAD request answers 200 with no body and the archive's Content-Length, ETag (its SHA-256), Last-Modified and Accept-Ranges headers, read from storage metadata.
 // @Tags         Modules
 // @Produce      json
 // @Param        namespace  path  string  true  "Module namespace"
@@ -31,16 +33,26 @@ import (
 // @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
 // @Param        version    path  string  true  "Semantic version (e.g. 1.2.3)"
 // @Success      204  "No Content — X-Terraform-Get header contains the download URL"
+// @Success      301  "Module moved; Location is the same request at its current address"
 // @Failure      400  {object}  map[string]interface{}  "Invalid version format"
+// @Failure      403  {object}  map[string]interface{}  "Version not approved for the caller's organization"
 // @Failure      404  {object}  map[string]interface{}  "Module or version not found"
+// @Failure      410  {object}  map[string]interface{}  "Version archive is missing from storage and marked broken"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      503  {object}  map[string]interface{}  "Database temporarily unavailable (Retry-After set)"
 // @Router       /v1/modules/{namespace}/{name}/{system}/{version}/download [get]
+// @Router       /v1/modules/{namespace}/{name}/{system}/{version}/download [head]
 // DownloadHandler handles module download requests
 // Implements: GET /v1/modules/:namespace/:name/:system/:version/download
 // Returns 204 No Content with X-Terraform-Get header pointing to download URL
 func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config, auditRepo *repositories.AuditRepository) gin.HandlerFunc {
 	moduleRepo := repositories.NewModuleRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
+	approvalRepo := repositories.NewModuleApprovalRepository(db)
+	var consistencyRepo *repositories.StorageConsistencyRepository
+	if cfg.StorageConsistency.Enabled {
+		consistencyRepo = repositories.NewStorageConsistencyRepository(db)
+	}
 
 	return func(c *gin.Context) {
 		namespace := c.Param("namespace")
@@ -57,11 +69,9 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 		}
 
 		// Get organization context
-		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+		org, err := transient.Value(c.Request.Context(), orgRepo.GetDefaultOrganization)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to get organization context",
-			})
+			respondQueryError(c, err, "Failed to get organization context")
 			return
 		}
 		if org == nil {
@@ -72,14 +82,17 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 		}
 
 		// Get module
-		module, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
+		module, err := transient.Value(c.Request.Context(), func(ctx context.Context) (*models.Module, error) {
+			return moduleRepo.GetModule(ctx, org.ID, namespace, name, system)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to query module",
-			})
+			respondQueryError(c, err, "Failed to query module")
 			return
 		}
 		if module == nil {
+			if redirectMovedModule(c, moduleRepo, org.ID, namespace, name, system) {
+				return
+			}
 			c.JSON(http.StatusNotFound, gin.H{
 				"errors": []string{"Module not found"},
 			})
@@ -87,11 +100,11 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 		}
 
 		// Get specific version
-		moduleVersion, err := moduleRepo.GetVersion(c.Request.Context(), module.ID, version)
+		moduleVersion, err := transient.Value(c.Request.Context(), func(ctx context.Context) (*models.ModuleVersion, error) {
+			return moduleRepo.GetVersion(ctx, module.ID, version)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to query module version",
-			})
+			respondQueryError(c, err, "Failed to query module version")
 			return
 		}
 		if moduleVersion == nil {
@@ -101,6 +114,55 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 			return
 		}
 
+		// Enforce approved_only policies of the caller's organizations.
+		enforcing, err := transient.Value(c.Request.Context(), func(context.Context) ([]string, error) {
+			return enforcingOrganizationIDs(c, approvalRepo)
+		})
+		if err != nil {
+			respondQueryError(c, err, "Failed to resolve organization module policy")
+			return
+		}
+		if len(enforcing) > 0 {
+			approved, err := transient.Value(c.Request.Context(), func(ctx context.Context) (bool, error) {
+				return approvalRepo.IsApproved(ctx, moduleVersion.ID, enforcing)
+			})
+			if err != nil {
+				respondQueryError(c, err, "Failed to check module version approval")
+				return
+			}
+			if !approved {
+				c.JSON(http.StatusForbidden, gin.H{
+					"errors": []string{"Module version is not approved for your organization"},
+				})
+				return
+			}
+		}
+
+		// Refuse a version the consistency checker found without its archive,
+		// rather than hand out a URL that 404s at the storage backend.
+		if consistencyRepo != nil {
+			broken, err := transient.Value(c.Request.Context(), func(ctx context.Context) (bool, error) {
+				return consistencyRepo.IsBroken(ctx, models.ArtifactTypeModuleVersion, moduleVersion.ID)
+			})
+			if err != nil {
+				respondQueryError(c, err, "Failed to check module version state")
+				return
+			}
+			if broken {
+				c.JSON(http.StatusGone, gin.H{
+					"errors": []string{"Module version archive is missing from storage; it must be re-published"},
+				})
+				return
+			}
+		}
+
+		// A HEAD describes the archive instead of handing out its URL, and is
+		// not counted as a download.
+		if artifacthead.IsHead(c) {
+			artifacthead.Respond(c, storageBackend, artifacthead.Mode(cfg), moduleVersion.StoragePath, moduleVersion.Checksum)
+			return
+		}
+
 		// Get download URL from storage backend
 		// TTL of 15 minutes for signed URLs
 		downloadURL, err := storageBackend.GetURL(c.Request.Context(), moduleVersion.StoragePath, 15*time.Minute)
@@ -161,7 +223,25 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 
 		// Return 204 No Content with X-Terraform-Get header
 		// This is the Terraform Module Registry Protocol standard response
-		c.Header("X-Terraform-Get", downloadURL)
+		c.Header("X-Terraform-Get", middleware.TenantURL(c, downloadURL))
 		c.Status(http.StatusNoContent)
 	}
 }
+
+// respondQueryError writes the error response for a failed protocol read. A
+// connection-level failure that survived transient.Do's retry becomes a 503
+// with Retry-After so Terraform (and any caching proxy in front of us) backs
+// off and retries instead of treating the registry as broken; anything else
+// is a plain 500.
+func respondQueryError(c *gin.Context, err error, msg string) {
+	if transient.IsTransient(err) {
+		c.Header("Retry-After", transient.RetryAfterSeconds)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Database temporarily unavailable",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": msg,
+	})
+}
@@ -5,20 +5,32 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
 
 func init() {
@@ -58,9 +70,11 @@ func (m *mockStore) GetMetadata(_ context.Context, _ string) (*storage.FileMetad
 	if m.metadataErr != nil {
 		return nil, m.metadataErr
 	}
-	return &storage.FileMetadata{Path: "test.tgz", Size: 1234, Checksum: "abc"}, nil
+	return &storage.FileMetadata{Path: "test.tgz", Size: 1234, Checksum: "abc", LastModified: mockModTime}, nil
 }
 
+var mockModTime = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
 var errDB2 = errors.New("db error")
 
 // ---------------------------------------------------------------------------
@@ -78,7 +92,7 @@ var moduleVersionListCols2 = []string{
 	"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes", "checksum",
 	"readme", "published_by", "published_by_name", "download_count", "deprecated",
 	"deprecated_at", "deprecation_message", "replacement_source", "created_at",
-	"commit_sha", "tag_name", "scm_repo_id", "has_docs",
+	"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version", "has_docs",
 }
 
 // GetVersion: 18 cols (no published_by_name, includes replacement_source, commit_sha, tag_name, scm_repo_id)
@@ -86,7 +100,7 @@ var moduleVersionGetCols2 = []string{
 	"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes", "checksum",
 	"readme", "published_by", "download_count", "deprecated",
 	"deprecated_at", "deprecation_message", "replacement_source", "created_at",
-	"commit_sha", "tag_name", "scm_repo_id",
+	"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version",
 }
 
 // SearchModulesWithStats result: id, org_id, namespace, name, system, description, source,
@@ -126,14 +140,14 @@ func sampleModuleVersionsRows() *sqlmock.Rows {
 	return sqlmock.NewRows(moduleVersionListCols2).
 		AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tgz", "local",
 			1024, "abc123", nil, nil, nil, int64(5), false, nil, nil, nil, time.Now(),
-			nil, nil, nil, false)
+			nil, nil, nil, nil, false)
 }
 
 func sampleModuleVersionGetRow() *sqlmock.Rows {
 	return sqlmock.NewRows(moduleVersionGetCols2).
 		AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tgz", "local",
 			1024, "abc123", nil, nil, int64(5), false, nil, nil, nil, time.Now(),
-			nil, nil, nil)
+			nil, nil, nil, nil)
 }
 
 func sampleModuleSearchRowFTS() *sqlmock.Rows {
@@ -182,6 +196,35 @@ func newServeRouter(t *testing.T, store *mockStore) *gin.Engine {
 	return r
 }
 
+func doHEAD(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodHead, path, nil)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// assertArtifactHead checks a HEAD answer carries the artifact headers from
+// storage metadata and no body.
+func assertArtifactHead(t *testing.T, w *httptest.ResponseRecorder, etag string) {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", w.Body.String())
+	}
+	for header, want := range map[string]string{
+		"Content-Length": "1234",
+		"ETag":           etag,
+		"Last-Modified":  "Sun, 01 Mar 2026 12:00:00 GMT",
+		"Accept-Ranges":  "bytes",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
 func doGET(r *gin.Engine, path string) *httptest.ResponseRecorder {
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodGet, path, nil)
@@ -253,6 +296,30 @@ func TestListVersionsHandler_ModuleNotFound(t *testing.T) {
 	}
 }
 
+var moduleRedirectCols = []string{
+	"id", "organization_id", "namespace", "name", "system", "module_id", "created_at",
+	"namespace", "name", "system",
+}
+
+func TestListVersionsHandler_MovedModuleRedirects(t *testing.T) {
+	mock, r := newVersionsRouter(t)
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sqlmock.NewRows(moduleCols2))
+	mock.ExpectQuery("SELECT.*FROM module_address_redirects").
+		WithArgs("org-1", "hashicorp", "consul", "all").
+		WillReturnRows(sqlmock.NewRows(moduleRedirectCols).
+			AddRow("rd-1", "org-1", "hashicorp", "consul", "all", "mod-1", time.Now(), "hashicorp", "consul", "generic"))
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/all/versions?include_prerelease=true")
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want 301; body: %s", w.Code, w.Body.String())
+	}
+	if loc := w.Header().Get("Location"); loc != "/v1/modules/hashicorp/consul/generic/versions?include_prerelease=true" {
+		t.Errorf("Location = %q", loc)
+	}
+}
+
 func TestListVersionsHandler_VersionsError(t *testing.T) {
 	mock, r := newVersionsRouter(t)
 
@@ -275,7 +342,7 @@ func TestListVersionsHandler_DeprecationBlock(t *testing.T) {
 	deprecatedVersionRow := sqlmock.NewRows(moduleVersionListCols2).
 		AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tgz", "local",
 			1024, "abc123", nil, nil, nil, int64(5), true, &depTime, &depMsg, &replacement, time.Now(),
-			nil, nil, nil, false)
+			nil, nil, nil, nil, false)
 
 	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
 	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
@@ -315,7 +382,7 @@ func TestListVersionsHandler_DeprecationBlock_NoReplacement(t *testing.T) {
 	deprecatedVersionRow := sqlmock.NewRows(moduleVersionListCols2).
 		AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tgz", "local",
 			1024, "abc123", nil, nil, nil, int64(5), true, &depTime, &depMsg, nil, time.Now(),
-			nil, nil, nil, false)
+			nil, nil, nil, nil, false)
 
 	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
 	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
@@ -371,6 +438,110 @@ func TestSearchHandler_Success_SingleTenant(t *testing.T) {
 	// "consul" is >= 3 chars so FTS mode adds rank column
 	mock.ExpectQuery("SELECT COUNT.*FROM modules").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 	mock.ExpectQuery("SELECT.*FROM modules.*ORDER BY").WillReturnRows(sampleModuleSearchRowFTS())
+	mock.ExpectQuery("SELECT namespace, short_description FROM namespace_metadata").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace", "short_description"}))
+
+	w := doGET(r, "/v1/modules/search?q=consul")
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "namespace_description") {
+		t.Errorf("body = %s, want no namespace_description without metadata", w.Body.String())
+	}
+}
+
+// tenantDomainList is a middleware.TenantDomainLoader over a fixed list.
+type tenantDomainList []*models.TenantDomain
+
+func (l tenantDomainList) List(context.Context) ([]*models.TenantDomain, error) { return l, nil }
+
+func TestSearchHandler_TenantDomainScope(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	domains := middleware.NewTenantDomains(tenantDomainList{
+		{Hostname: "modules.acme-blue.example.com", OrganizationID: "org-blue"},
+	}, time.Minute)
+	r := gin.New()
+	r.Use(middleware.TenantMiddleware(domains, &config.ServerConfig{BaseURL: "https://registry.example.com"}))
+	r.GET("/v1/modules/search", SearchHandler(db, &config.Config{}))
+
+	search := func(path string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "modules.acme-blue.example.com"
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	mock.ExpectQuery("SELECT COUNT.*FROM modules m WHERE m.namespace IN \\(SELECT namespace FROM namespace_claims").
+		WithArgs("org-blue").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT.*FROM modules.*ORDER BY").WillReturnRows(sqlmock.NewRows(nil))
+	search("/v1/modules/search")
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM modules m\\s*$").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT.*FROM modules.*ORDER BY").WillReturnRows(sqlmock.NewRows(nil))
+	search("/v1/modules/search?scope=all")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearchHandler_IncludesNamespaceDescription(t *testing.T) {
+	mock, r := newSearchRouter(t, &config.Config{})
+
+	mock.ExpectQuery("SELECT COUNT.*FROM modules").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM modules.*ORDER BY").WillReturnRows(sampleModuleSearchRowFTS())
+	mock.ExpectQuery("SELECT namespace, short_description FROM namespace_metadata").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace", "short_description"}).AddRow("hashicorp", "Official modules"))
+
+	w := doGET(r, "/v1/modules/search?q=consul")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"namespace_description":"Official modules"`) {
+		t.Errorf("body = %s, want the namespace short description", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearchHandler_SystemFacets(t *testing.T) {
+	mock, r := newSearchRouter(t, &config.Config{})
+
+	mock.ExpectQuery("SELECT COUNT.*FROM modules").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM modules.*ORDER BY").WillReturnRows(sampleModuleSearchRowFTS())
+	mock.ExpectQuery("SELECT namespace, short_description FROM namespace_metadata").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace", "short_description"}))
+	mock.ExpectQuery("SELECT m.system, COUNT.*GROUP BY m.system").
+		WillReturnRows(sqlmock.NewRows([]string{"system", "count"}).
+			AddRow("aws", int64(1)).
+			AddRow("generic", int64(3)))
+
+	w := doGET(r, "/v1/modules/search?q=consul&system=aws")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"facets":{"systems":[{"system":"aws","count":1},{"system":"generic","count":3}]}`) {
+		t.Errorf("body = %s, want system facets", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearchHandler_NamespaceDescriptionErrorIgnored(t *testing.T) {
+	mock, r := newSearchRouter(t, &config.Config{})
+
+	mock.ExpectQuery("SELECT COUNT.*FROM modules").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM modules.*ORDER BY").WillReturnRows(sampleModuleSearchRowFTS())
+	mock.ExpectQuery("SELECT namespace, short_description FROM namespace_metadata").WillReturnError(errDB2)
 
 	w := doGET(r, "/v1/modules/search?q=consul")
 	if w.Code != http.StatusOK {
@@ -404,6 +575,15 @@ func TestSearchHandler_SearchError(t *testing.T) {
 	}
 }
 
+func TestSearchHandler_InvalidTier(t *testing.T) {
+	_, r := newSearchRouter(t, &config.Config{})
+
+	w := doGET(r, "/v1/modules/search?tier=first_party")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
 func TestSearchHandler_MultiTenant_OrgError(t *testing.T) {
 	cfg := &config.Config{}
 	cfg.MultiTenancy.Enabled = true
@@ -453,6 +633,24 @@ func TestDownloadHandler_ModuleNotFound(t *testing.T) {
 	}
 }
 
+func TestDownloadHandler_MovedModuleRedirects(t *testing.T) {
+	mock, r := newDownloadRouter(t, &mockStore{})
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sqlmock.NewRows(moduleCols2))
+	mock.ExpectQuery("SELECT.*FROM module_address_redirects").
+		WillReturnRows(sqlmock.NewRows(moduleRedirectCols).
+			AddRow("rd-1", "org-1", "hashicorp", "consul", "none", "mod-1", time.Now(), "hashicorp", "consul", "generic"))
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/none/1.0.0/download")
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want 301; body: %s", w.Code, w.Body.String())
+	}
+	if loc := w.Header().Get("Location"); loc != "/v1/modules/hashicorp/consul/generic/1.0.0/download" {
+		t.Errorf("Location = %q", loc)
+	}
+}
+
 func TestDownloadHandler_VersionNotFound(t *testing.T) {
 	mock, r := newDownloadRouter(t, &mockStore{})
 
@@ -483,6 +681,53 @@ func TestDownloadHandler_Success(t *testing.T) {
 	}
 }
 
+// A HEAD describes the archive from storage metadata, with the version's
+// checksum as the ETag, instead of handing out a URL.
+func TestDownloadHandler_Head(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.HEAD("/v1/modules/:namespace/:name/:system/:version/download", DownloadHandler(db, &mockStore{existsResult: true, getURLErr: errors.New("no URL for HEAD")}, &config.Config{}, nil))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").WillReturnRows(sampleModuleVersionGetRow())
+
+	w := doHEAD(r, "/v1/modules/hashicorp/consul/aws/1.0.0/download")
+	assertArtifactHead(t, w, `"abc123"`)
+	if w.Header().Get("X-Terraform-Get") != "" {
+		t.Error("HEAD must not hand out a download URL")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations (download counted?): %v", err)
+	}
+}
+
+// With the storage consistency checker enabled, a version marked broken is
+// refused with 410 instead of a URL to its missing archive.
+func TestDownloadHandler_MarkedBroken(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	cfg := &config.Config{StorageConsistency: config.StorageConsistencyConfig{Enabled: true}}
+	r.GET("/v1/modules/:namespace/:name/:system/:version/download", DownloadHandler(db, &mockStore{getURLResult: "https://example.com/module.tgz"}, cfg, nil))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").WillReturnRows(sampleModuleVersionGetRow())
+	mock.ExpectQuery("SELECT EXISTS.*FROM storage_consistency_findings").
+		WithArgs("module_version", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/1.0.0/download")
+	if w.Code != http.StatusGone {
+		t.Errorf("status = %d, want 410; body: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Terraform-Get") != "" {
+		t.Error("a broken version must not be given a download URL")
+	}
+}
+
 func TestDownloadHandler_StorageError(t *testing.T) {
 	store := &mockStore{getURLErr: errors.New("storage error")}
 	mock, r := newDownloadRouter(t, store)
@@ -552,6 +797,51 @@ func TestServeFileHandler_Success(t *testing.T) {
 	}
 }
 
+func TestServeFileHandler_Head(t *testing.T) {
+	// Download fails, so a 200 proves HEAD never opened the file.
+	store := &mockStore{existsResult: true, downloadErr: errors.New("must not be read")}
+	r := gin.New()
+	r.HEAD("/v1/files/*filepath", ServeFileHandler(store, &config.Config{}, nil, nil))
+
+	w := doHEAD(r, "/v1/files/path/to/file.tgz")
+	assertArtifactHead(t, w, `"abc"`)
+}
+
+func TestServeFileHandler_HeadNotFound(t *testing.T) {
+	r := gin.New()
+	r.HEAD("/v1/files/*filepath", ServeFileHandler(&mockStore{existsResult: false}, &config.Config{}, nil, nil))
+
+	w := doHEAD(r, "/v1/files/path/to/file.tgz")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", w.Body.String())
+	}
+}
+
+// In existence mode only the object's presence is checked: no size or
+// modification time, and the ETag only when the database has a checksum.
+func TestServeFileHandler_HeadExistenceMode(t *testing.T) {
+	store := &mockStore{existsResult: true, metadataErr: errors.New("metadata must not be read")}
+	r := gin.New()
+	cfg := &config.Config{DownloadHead: config.DownloadHeadConfig{Mode: config.DownloadHeadExistence}}
+	r.HEAD("/v1/files/*filepath", ServeFileHandler(store, cfg, nil, nil))
+
+	w := doHEAD(r, "/v1/files/path/to/file.tgz")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if w.Header().Get("Accept-Ranges") != "bytes" {
+		t.Errorf("Accept-Ranges = %q, want bytes", w.Header().Get("Accept-Ranges"))
+	}
+	for _, h := range []string{"Content-Length", "Last-Modified", "ETag"} {
+		if got := w.Header().Get(h); got != "" {
+			t.Errorf("%s = %q, want unset in existence mode", h, got)
+		}
+	}
+}
+
 // Issue #566 finding [47]: the path-traversal guard on this unauthenticated,
 // unrated endpoint had zero attack-payload test coverage.
 func TestServeFileHandler_PathTraversal(t *testing.T) {
@@ -656,7 +946,7 @@ func newModuleUploadRouter(t *testing.T, store storage.Storage) (sqlmock.Sqlmock
 	db, mock, _ := sqlmock.New()
 	t.Cleanup(func() { db.Close() })
 	r := gin.New()
-	r.POST("/api/v1/modules", UploadHandler(db, store, &config.Config{}, nil, nil, nil, nil))
+	r.POST("/api/v1/modules", UploadHandler(db, store, &config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil))
 	return mock, r
 }
 
@@ -805,6 +1095,127 @@ func TestUploadHandler_VersionConflict(t *testing.T) {
 	}
 }
 
+func TestUploadHandler_VersionCapReached(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	versionCap := services.NewVersionCap(repositories.NewModuleRepository(db), nil)
+	r := gin.New()
+	r.POST("/api/v1/modules", UploadHandler(db, &mockStore{}, &config.Config{}, nil, nil, nil, nil, versionCap, nil, nil, nil))
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("INSERT INTO modules").WillReturnRows(
+		sqlmock.NewRows(moduleInsertCols2).AddRow("mod-1", time.Now(), time.Now()),
+	)
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
+	// GetVersionCap: strict mode, already at its cap of 3
+	mock.ExpectQuery("SELECT COALESCE").WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"max_versions", "mode", "active"}).AddRow(3, "strict", 3))
+
+	req := buildModuleUploadRequest(t, "/api/v1/modules", map[string]string{
+		"namespace": "hashicorp",
+		"name":      "consul",
+		"system":    "aws",
+		"version":   "4.0.0",
+	}, makeValidModuleTarGz(t))
+	w := doPOSTReq(r, req)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422; body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+func TestUploadHandler_ScratchBudgetExceeded(t *testing.T) {
+	space, err := scratch.New(t.TempDir(), validation.MaxArchiveSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, _, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.POST("/api/v1/modules", UploadHandler(db, &mockStore{}, &config.Config{}, nil, nil, nil, nil, nil, nil, nil, space))
+
+	req := buildModuleUploadRequest(t, "/api/v1/modules", map[string]string{
+		"namespace": "hashicorp",
+		"name":      "consul",
+		"system":    "aws",
+		"version":   "1.0.0",
+	}, makeValidModuleTarGz(t))
+	w := doPOSTReq(r, req)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("status = %d, Retry-After = %q; want 503 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+func TestUploadHandler_PublishHookDenies(t *testing.T) {
+	var checksum string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			Checksum string `json:"checksum"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		checksum = event.Checksum
+		_, _ = w.Write([]byte(`{"allow": false, "reason": "unsigned release"}`))
+	}))
+	t.Cleanup(hook.Close)
+	cipher, _ := crypto.NewTokenCipher(bytes.Repeat([]byte("k"), 32))
+	sealed, _ := cipher.Seal("hook-secret-0123456789")
+
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	hooks := services.NewPublishHooks(repositories.NewPublishHookRepository(db), cipher, httpsafe.MustGuard("127.0.0.1"))
+	r := gin.New()
+	r.POST("/api/v1/modules", UploadHandler(db, &mockStore{}, &config.Config{}, nil, nil, nil, nil, nil, nil, hooks, nil))
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("INSERT INTO modules").WillReturnRows(
+		sqlmock.NewRows(moduleInsertCols2).AddRow("mod-1", time.Now(), time.Now()),
+	)
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
+	mock.ExpectQuery("SELECT.*FROM publish_hooks").WithArgs("org-1").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "url", "encrypted_secret", "timeout_seconds", "failure_mode", "enabled", "created_at", "updated_at"}).
+			AddRow("org-1", hook.URL, sealed, 5, "fail_closed", true, time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO publish_hook_deliveries").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("d-1", time.Now()))
+
+	archive := makeValidModuleTarGz(t)
+	req := buildModuleUploadRequest(t, "/api/v1/modules", map[string]string{
+		"namespace": "hashicorp",
+		"name":      "consul",
+		"system":    "aws",
+		"version":   "1.0.0",
+	}, archive)
+	w := doPOSTReq(r, req)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "unsigned release") {
+		t.Errorf("status = %d, want 422 with the hook's reason; body: %s", w.Code, w.Body.String())
+	}
+	if sum := sha256.Sum256(archive); checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("hook saw checksum %q, want the archive's SHA-256", checksum)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+func TestUploadHandler_IgnoreVersionCapRequiresAdmin(t *testing.T) {
+	_, r := newModuleUploadRouter(t, &mockStore{})
+
+	req := buildModuleUploadRequest(t, "/api/v1/modules", map[string]string{
+		"namespace":          "hashicorp",
+		"name":               "consul",
+		"system":             "aws",
+		"version":            "4.0.0",
+		"ignore_version_cap": "true",
+	}, makeValidModuleTarGz(t))
+	w := doPOSTReq(r, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403; body: %s", w.Code, w.Body.String())
+	}
+}
+
 func TestUploadHandler_Success_NewModule(t *testing.T) {
 	mock, r := newModuleUploadRouter(t, &mockStore{})
 
@@ -821,6 +1232,7 @@ func TestUploadHandler_Success_NewModule(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
 		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
 	// CreateVersion INSERT RETURNING id, created_at
+	expectNoDuplicateContent(mock)
 	mock.ExpectQuery("INSERT INTO module_versions").WillReturnRows(
 		sqlmock.NewRows(moduleVersionInsertCols2).AddRow("ver-new", time.Now()),
 	)
@@ -852,6 +1264,7 @@ func TestUploadHandler_Success_ExistingModule(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
 		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
 	// CreateVersion
+	expectNoDuplicateContent(mock)
 	mock.ExpectQuery("INSERT INTO module_versions").WillReturnRows(
 		sqlmock.NewRows(moduleVersionInsertCols2).AddRow("ver-new2", time.Now()),
 	)
@@ -868,6 +1281,251 @@ func TestUploadHandler_Success_ExistingModule(t *testing.T) {
 	}
 }
 
+// expectNoDuplicateContent queues the duplicate-content lookup that runs
+// before a version row is written, finding no earlier identical archive.
+func expectNoDuplicateContent(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT.*FROM module_versions.*checksum").
+		WillReturnRows(sqlmock.NewRows(moduleVersionChecksumCols2))
+}
+
+var moduleVersionChecksumCols2 = []string{
+	"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes", "checksum", "created_at",
+}
+
+var modulePolicyCols2 = []string{
+	"organization_id", "approved_only", "default_max_versions", "default_version_cap_mode",
+	"alias_duplicate_versions", "created_at", "updated_at",
+}
+
+// expectDuplicateContent queues the lookups for an archive identical to
+// version 1.0.0, under an org policy that does or does not alias duplicates.
+func expectDuplicateContent(mock sqlmock.Sqlmock, archive []byte, aliasDuplicates bool) {
+	sum := sha256.Sum256(archive)
+	mock.ExpectQuery("SELECT.*FROM module_versions.*checksum").
+		WithArgs("mod-1", hex.EncodeToString(sum[:])).
+		WillReturnRows(sqlmock.NewRows(moduleVersionChecksumCols2).AddRow(
+			"ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tgz", "s3",
+			int64(len(archive)), hex.EncodeToString(sum[:]), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM org_module_policies").
+		WillReturnRows(sqlmock.NewRows(modulePolicyCols2).
+			AddRow("org-1", false, nil, "warn", aliasDuplicates, time.Now(), time.Now()))
+}
+
+func TestUploadHandler_DuplicateContent_Alias(t *testing.T) {
+	store := &consumingStore{}
+	mock, r := newModuleUploadRouter(t, store)
+	archive := makeValidModuleTarGz(t)
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("INSERT INTO modules").WillReturnRows(
+		sqlmock.NewRows(moduleInsertCols2).AddRow("mod-1", time.Now(), time.Now()),
+	)
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
+	expectDuplicateContent(mock, archive, true)
+	// The alias row reuses the original's archive and records what it points at.
+	mock.ExpectQuery("INSERT INTO module_versions").
+		WithArgs("mod-1", "1.0.1", "modules/hashicorp/consul/aws/1.0.0.tgz", "s3",
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "ver-1").
+		WillReturnRows(sqlmock.NewRows(moduleVersionInsertCols2).AddRow("ver-2", time.Now()))
+
+	req := buildModuleUploadRequest(t, "/api/v1/modules", map[string]string{
+		"namespace": "hashicorp",
+		"name":      "consul",
+		"system":    "aws",
+		"version":   "1.0.1",
+	}, archive)
+	w := doPOSTReq(r, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"duplicate_of":"1.0.0"`) || !strings.Contains(w.Body.String(), `"alias":true`) {
+		t.Errorf("body does not report the alias: %s", w.Body.String())
+	}
+	if store.uploads != 0 {
+		t.Errorf("storage uploads = %d, want none for an alias", store.uploads)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+func TestUploadHandler_DuplicateContent_ReportedOnly(t *testing.T) {
+	store := &consumingStore{}
+	mock, r := newModuleUploadRouter(t, store)
+	archive := makeValidModuleTarGz(t)
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("INSERT INTO modules").WillReturnRows(
+		sqlmock.NewRows(moduleInsertCols2).AddRow("mod-1", time.Now(), time.Now()),
+	)
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
+	expectDuplicateContent(mock, archive, false)
+	mock.ExpectQuery("INSERT INTO module_versions").
+		WithArgs("mod-1", "1.0.1", sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows(moduleVersionInsertCols2).AddRow("ver-2", time.Now()))
+
+	req := buildModuleUploadRequest(t, "/api/v1/modules", map[string]string{
+		"namespace": "hashicorp",
+		"name":      "consul",
+		"system":    "aws",
+		"version":   "1.0.1",
+	}, archive)
+	w := doPOSTReq(r, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"duplicate_of":"1.0.0"`) || !strings.Contains(w.Body.String(), `"alias":false`) {
+		t.Errorf("body = %s, want duplicate_of with alias false", w.Body.String())
+	}
+	if store.uploads != 1 {
+		t.Errorf("storage uploads = %d, want 1", store.uploads)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+func TestUploadHandler_DryRun_NewModule(t *testing.T) {
+	// A streaming backend: a dry run must still not write the archive.
+	store := &consumingStore{streams: true}
+	mock, r := newModuleUploadRouter(t, store)
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	// GetModule → not found; nothing is inserted
+	mock.ExpectQuery("SELECT.*FROM modules m").WillReturnRows(sqlmock.NewRows(moduleCols2))
+
+	archive := makeValidModuleTarGz(t)
+	req := buildModuleUploadRequest(t, "/api/v1/modules?dry_run=true", map[string]string{
+		"namespace":   "hashicorp",
+		"name":        "consul",
+		"system":      "aws",
+		"version":     "1.0.0",
+		"description": "A test module",
+	}, archive)
+	w := doPOSTReq(r, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var resp ModuleUploadDryRunResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if sum := sha256.Sum256(archive); !resp.DryRun || resp.ModuleExists || resp.Version != "1.0.0" || resp.Checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("response = %+v", resp)
+	}
+	if store.uploads != 0 {
+		t.Errorf("storage uploads = %d, want none", store.uploads)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+func TestUploadHandler_DryRun_VersionConflict(t *testing.T) {
+	mock, r := newModuleUploadRouter(t, &mockStore{})
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules m").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WithArgs("mod-1", "1.0.0").
+		WillReturnRows(sampleModuleVersionGetRow())
+
+	req := buildModuleUploadRequest(t, "/api/v1/modules?dry_run=true", map[string]string{
+		"namespace": "hashicorp",
+		"name":      "consul",
+		"system":    "aws",
+		"version":   "1.0.0",
+	}, makeValidModuleTarGz(t))
+	w := doPOSTReq(r, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409 as a real publish; body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// UploadHandler — publish from URL (JSON body)
+// ---------------------------------------------------------------------------
+
+func newRemoteModuleUploadRouter(t *testing.T, enabled bool) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	cfg := &config.Config{}
+	cfg.RemoteUpload = config.RemoteUploadConfig{Enabled: enabled, AllowedSchemes: []string{"http"}}
+	cfg.Security.Egress.Allowlist = []string{"127.0.0.1"} // the httptest server
+	r := gin.New()
+	r.POST("/api/v1/modules", UploadHandler(db, &mockStore{}, cfg, nil, nil, nil, nil, nil, nil, nil, nil))
+	return mock, r
+}
+
+func remoteModuleUploadRequest(sourceURL string) *http.Request {
+	body := `{"namespace":"hashicorp","name":"consul","system":"aws","version":"1.0.0","source_url":"` + sourceURL + `"}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/modules", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestUploadHandler_Remote_Disabled(t *testing.T) {
+	_, r := newRemoteModuleUploadRouter(t, false)
+
+	w := doPOSTReq(r, remoteModuleUploadRequest("http://127.0.0.1/consul.tar.gz"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400; body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUploadHandler_Remote_UpstreamStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	_, r := newRemoteModuleUploadRouter(t, true)
+
+	w := doPOSTReq(r, remoteModuleUploadRequest(srv.URL+"/consul.tar.gz"))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502; body: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"upstream_status":403`) {
+		t.Errorf("body does not report the upstream status: %s", w.Body.String())
+	}
+}
+
+func TestUploadHandler_Remote_Success(t *testing.T) {
+	archive := makeValidModuleTarGz(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	}))
+	defer srv.Close()
+	mock, r := newRemoteModuleUploadRouter(t, true)
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("INSERT INTO modules").WillReturnRows(
+		sqlmock.NewRows(moduleInsertCols2).AddRow("mod-1", time.Now(), time.Now()),
+	)
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
+	expectNoDuplicateContent(mock)
+	mock.ExpectQuery("INSERT INTO module_versions").WillReturnRows(
+		sqlmock.NewRows(moduleVersionInsertCols2).AddRow("ver-1", time.Now()),
+	)
+
+	w := doPOSTReq(r, remoteModuleUploadRequest(srv.URL+"/builds/consul.tar.gz"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"filename":"consul.tar.gz"`) {
+		t.Errorf("filename not taken from source_url: %s", w.Body.String())
+	}
+}
+
 // ---------------------------------------------------------------------------
 // DownloadHandler — additional uncovered branches
 // ---------------------------------------------------------------------------
@@ -1001,3 +1659,79 @@ func TestParseProviderFilePath_Empty(t *testing.T) {
 		t.Error("expected ok=false for empty path")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Transient database errors
+// ---------------------------------------------------------------------------
+
+// errConnDropped simulates the database closing the connection mid-request
+// (failover, restart).
+var errConnDropped = &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+func TestDownloadHandler_RetriesDroppedConnection(t *testing.T) {
+	store := &mockStore{getURLResult: "https://example.com/module.tgz"}
+	mock, r := newDownloadRouter(t, store)
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnError(errConnDropped)
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").WillReturnRows(sampleModuleVersionGetRow())
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/1.0.0/download")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204; body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestDownloadHandler_PersistentDroppedConnection(t *testing.T) {
+	mock, r := newDownloadRouter(t, &mockStore{})
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").WillReturnError(errConnDropped)
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").WillReturnError(errConnDropped)
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/1.0.0/download")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+}
+
+func TestListVersionsHandler_RetriesDroppedConnection(t *testing.T) {
+	mock, r := newVersionsRouter(t)
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnError(errConnDropped)
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT COUNT.*FROM module_versions WHERE module_id").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE mv.module_id").WillReturnRows(sampleModuleVersionsRows())
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/versions")
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListVersionsHandler_NonTransientErrorNotRetried(t *testing.T) {
+	mock, r := newVersionsRouter(t)
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnError(errDB2)
+
+	w := doGET(r, "/v1/modules/hashicorp/consul/aws/versions")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "" {
+		t.Error("Retry-After must not be set for non-transient errors")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
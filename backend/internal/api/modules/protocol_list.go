@@ -0,0 +1,176 @@
+// protocol_list.go implements the protocol-shaped module listing and search
+// endpoints (GET /v1/modules and GET /v1/modules/search) consumed by
+// ecosystem tooling that speaks the public registry's discovery API.
+package modules
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+const (
+	protocolListDefaultLimit = 15
+	protocolListMaxLimit     = 100
+)
+
+// @Summary      List modules (protocol)
+// @Description  Lists modules in the public registry's discovery format, for tooling such as IDE plugins. Each module is reported at its latest version; modules without a listable version are skipped. No module on this registry is verified, so verified=true returns an empty list.
+// @Tags         Modules
+// @Produce      json
+// @Param        namespace  query  string  false  "Filter by namespace"
+// @Param        provider   query  string  false  "Filter by provider (target system)"
+// @Param        verified   query  bool    false  "Only verified modules"
+// @Param        scope      query  string  false  "all to include every namespace on a custom tenant domain"
+// @Param        limit      query  int     false  "Maximum results to return (default 15, max 100)"
+// @Param        offset     query  int     false  "Offset for pagination (default 0)"
+// @Success      200  {object}  modules.ProtocolModuleList
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /v1/modules [get]
+// ProtocolListHandler lists modules in the protocol discovery format
+// Implements: GET /v1/modules?namespace=<namespace>&provider=<provider>&verified=<bool>&limit=<limit>&offset=<offset>
+func ProtocolListHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
+	return protocolModuleListHandler(db, cfg, false)
+}
+
+// @Summary      Search modules (protocol)
+// @Description  Searches modules and returns them in the public registry's discovery format, for tooling such as IDE plugins. Each module is reported at its latest version; modules without a listable version are skipped. No module on this registry is verified, so verified=true returns an empty list.
+// @Tags         Modules
+// @Produce      json
+// @Param        q          query  string  true   "Search query"
+// @Param        namespace  query  string  false  "Filter by namespace"
+// @Param        provider   query  string  false  "Filter by provider (target system)"
+// @Param        verified   query  bool    false  "Only verified modules"
+// @Param        scope      query  string  false  "all to include every namespace on a custom tenant domain"
+// @Param        limit      query  int     false  "Maximum results to return (default 15, max 100)"
+// @Param        offset     query  int     false  "Offset for pagination (default 0)"
+// @Success      200  {object}  modules.ProtocolModuleList
+// @Failure      400  {object}  map[string]interface{}  "Missing search query"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /v1/modules/search [get]
+// ProtocolSearchHandler searches modules in the protocol discovery format
+// Implements: GET /v1/modules/search?q=<query>&namespace=<namespace>&provider=<provider>&verified=<bool>&limit=<limit>&offset=<offset>
+func ProtocolSearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
+	return protocolModuleListHandler(db, cfg, true)
+}
+
+// protocolModuleListHandler serves both discovery endpoints; they differ only
+// in whether a search query is required. Scoping matches SearchHandler: the
+// default organization under multi-tenancy, and the tenant organization's
+// namespaces on a custom tenant domain.
+func protocolModuleListHandler(db *sql.DB, cfg *config.Config, search bool) gin.HandlerFunc {
+	moduleRepo := repositories.NewModuleRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+
+	return func(c *gin.Context) {
+		query := c.Query("q")
+		if search && query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"errors": []string{"q is required"}})
+			return
+		}
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(protocolListDefaultLimit)))
+		if err != nil || limit < 1 || limit > protocolListMaxLimit {
+			limit = protocolListDefaultLimit
+		}
+		offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if err != nil || offset < 0 {
+			offset = 0
+		}
+
+		resp := ProtocolModuleList{
+			Meta:    ProtocolListMeta{Limit: limit, CurrentOffset: offset},
+			Modules: []ProtocolModule{},
+		}
+		if offset > 0 {
+			prev := max(offset-limit, 0)
+			resp.Meta.PrevOffset = &prev
+			resp.Meta.PrevURL = protocolPageURL(c, limit, prev)
+		}
+
+		// Verification is a public-registry concept with no equivalent here.
+		if verified, _ := strconv.ParseBool(c.Query("verified")); verified {
+			c.JSON(http.StatusOK, resp)
+			return
+		}
+
+		var orgID string
+		if cfg.MultiTenancy.Enabled {
+			org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+			if err != nil || org == nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"errors": []string{"Failed to get organization context"}})
+				return
+			}
+			orgID = org.ID
+		}
+
+		var ownerOrgID string
+		if c.Query("scope") != "all" {
+			ownerOrgID = middleware.TenantOrganizationID(c)
+		}
+
+		modules, total, err := moduleRepo.SearchModulesWithStats(
+			c.Request.Context(),
+			orgID,
+			ownerOrgID,
+			query,
+			c.Query("namespace"),
+			c.Query("provider"),
+			"",
+			limit,
+			offset,
+			"",
+			"",
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"errors": []string{"Failed to list modules"}})
+			return
+		}
+
+		for _, m := range modules {
+			if m.LatestVersion == nil {
+				continue
+			}
+			item := ProtocolModule{
+				ID:          m.Namespace + "/" + m.Name + "/" + m.System + "/" + *m.LatestVersion,
+				Namespace:   m.Namespace,
+				Name:        m.Name,
+				Version:     *m.LatestVersion,
+				Provider:    m.System,
+				PublishedAt: m.UpdatedAt,
+				Downloads:   m.TotalDownloads,
+			}
+			if m.CreatedByName != nil {
+				item.Owner = *m.CreatedByName
+			}
+			if m.Description != nil {
+				item.Description = *m.Description
+			}
+			if m.Source != nil {
+				item.Source = *m.Source
+			}
+			resp.Modules = append(resp.Modules, item)
+		}
+
+		if next := offset + limit; next < total {
+			resp.Meta.NextOffset = &next
+			resp.Meta.NextURL = protocolPageURL(c, limit, next)
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// protocolPageURL returns the request's path and query with the page moved to
+// offset.
+func protocolPageURL(c *gin.Context, limit, offset int) string {
+	q := c.Request.URL.Query()
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("offset", strconv.Itoa(offset))
+	return c.Request.URL.Path + "?" + q.Encode()
+}
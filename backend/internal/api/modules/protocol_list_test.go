@@ -0,0 +1,135 @@
+package modules
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+// Contract tests for GET /v1/modules and GET /v1/modules/search.
+//
+// IDE plugins and docs tooling parse these documents with the public
+// registry's schema, so the snapshots pin field names and pagination shape.
+
+func newProtocolListRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.GET("/v1/modules", ProtocolListHandler(db, &config.Config{}))
+	r.GET("/v1/modules/search", ProtocolSearchHandler(db, &config.Config{}))
+	return mock, r
+}
+
+func protocolSearchRows(cols []string) *sqlmock.Rows {
+	extra := func(vals ...driver.Value) []driver.Value {
+		if len(cols) == len(moduleSearchColsFTS) {
+			vals = append(vals, float64(0.5))
+		}
+		return vals
+	}
+	return sqlmock.NewRows(cols).
+		AddRow(extra("mod-1", "org-1", "hashicorp", "consul", "aws",
+			"Consul cluster on AWS", "https://github.com/hashicorp/terraform-aws-consul", "user-1", "Alice",
+			contractPublishedAt, contractPublishedAt, false, nil, nil, nil, "2.0.0", int64(12))...).
+		AddRow(extra("mod-2", "org-1", "hashicorp", "empty", "aws",
+			nil, nil, nil, nil, contractPublishedAt, contractPublishedAt, false, nil, nil, nil, nil, int64(0))...)
+}
+
+const protocolModuleListSnapshot = `{
+  "meta": {
+    "limit": 2,
+    "current_offset": 2,
+    "next_offset": 4,
+    "prev_offset": 0,
+    "next_url": "/v1/modules?limit=2\u0026offset=4",
+    "prev_url": "/v1/modules?limit=2\u0026offset=0"
+  },
+  "modules": [
+    {
+      "id": "hashicorp/consul/aws/2.0.0",
+      "owner": "Alice",
+      "namespace": "hashicorp",
+      "name": "consul",
+      "version": "2.0.0",
+      "provider": "aws",
+      "description": "Consul cluster on AWS",
+      "source": "https://github.com/hashicorp/terraform-aws-consul",
+      "published_at": "2024-05-01T12:00:00Z",
+      "downloads": 12,
+      "verified": false
+    }
+  ]
+}`
+
+func TestProtocolListContract_Snapshot(t *testing.T) {
+	mock, r := newProtocolListRouter(t)
+	mock.ExpectQuery("SELECT COUNT.*FROM modules").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery("SELECT.*FROM modules.*ORDER BY").WithArgs(2, 2).WillReturnRows(protocolSearchRows(moduleSearchCols))
+
+	w := doGET(r, "/v1/modules?limit=2&offset=2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, w.Body.Bytes(), "", "  "); err != nil {
+		t.Fatalf("indent: %v", err)
+	}
+	if pretty.String() != protocolModuleListSnapshot {
+		t.Errorf("protocol document changed.\ngot:\n%s\nwant:\n%s", pretty.String(), protocolModuleListSnapshot)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProtocolSearchContract(t *testing.T) {
+	mock, r := newProtocolListRouter(t)
+	mock.ExpectQuery("SELECT COUNT.*FROM modules.*plainto_tsquery").
+		WithArgs("consul", "aws").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT.*FROM modules.*ORDER BY").WillReturnRows(protocolSearchRows(moduleSearchColsFTS))
+
+	w := doGET(r, "/v1/modules/search?q=consul&provider=aws")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	var meta map[string]interface{}
+	_ = json.Unmarshal(doc["meta"], &meta)
+	if len(meta) != 2 || meta["limit"] != float64(15) || meta["current_offset"] != float64(0) {
+		t.Errorf("meta = %v, want only limit 15 and current_offset 0 on a single page", meta)
+	}
+	var list ProtocolModuleList
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Modules) != 1 || list.Modules[0].ID != "hashicorp/consul/aws/2.0.0" {
+		t.Errorf("modules = %+v, want only the versioned module", list.Modules)
+	}
+}
+
+func TestProtocolSearch_RequiresQuery(t *testing.T) {
+	_, r := newProtocolListRouter(t)
+	w := doGET(r, "/v1/modules/search")
+	if w.Code != http.StatusBadRequest || !bytes.Contains(w.Body.Bytes(), []byte(`"errors"`)) {
+		t.Errorf("status = %d, body: %s; want 400 with errors", w.Code, w.Body.String())
+	}
+}
+
+func TestProtocolList_VerifiedIsEmpty(t *testing.T) {
+	mock, r := newProtocolListRouter(t)
+	w := doGET(r, "/v1/modules?verified=true")
+	if w.Code != http.StatusOK || w.Body.String() != `{"meta":{"limit":15,"current_offset":0},"modules":[]}` {
+		t.Errorf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
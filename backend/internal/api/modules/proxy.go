@@ -0,0 +1,181 @@
+// proxy.go implements the module pull-through proxy endpoints. They mirror the
+// Module Registry Protocol versions/download pair with the upstream registry
+// hostname as an extra leading path segment, and are only registered when
+// module_proxy.enabled is set.
+package modules
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// proxyCoordinates reads and validates the hostname/namespace/name/system path
+// parameters. Public registry addresses are case-insensitive, so the segments
+// are lowercased to match this registry's naming rules.
+func proxyCoordinates(c *gin.Context) (hostname, namespace, name, system string, ok bool) {
+	hostname = strings.ToLower(c.Param("hostname"))
+	namespace = strings.ToLower(c.Param("namespace"))
+	name = strings.ToLower(c.Param("name"))
+	system = strings.ToLower(c.Param("system"))
+
+	if hostname == "" || strings.ContainsAny(hostname, "/:@ ") {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": []string{"Invalid registry hostname"}})
+		return "", "", "", "", false
+	}
+	for _, seg := range []string{namespace, name, system} {
+		if err := validation.ValidateRegistrySegment(seg); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+			return "", "", "", "", false
+		}
+	}
+	return hostname, namespace, name, system, true
+}
+
+// respondProxyError maps module proxy failures to responses. Errors reported
+// by the upstream registry are relayed with the upstream's status and message;
+// an unreachable upstream or an archive the proxy cannot handle is a 502.
+func respondProxyError(c *gin.Context, err error) {
+	var statusErr *mirror.UpstreamStatusError
+	var denied *services.ModuleProxyDeniedError
+	switch {
+	case errors.Is(err, services.ErrModuleProxyHostNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"errors": []string{err.Error()}})
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{"errors": []string{denied.Error()}})
+	case errors.Is(err, services.ErrModuleProxyConflict):
+		c.JSON(http.StatusConflict, gin.H{"errors": []string{err.Error()}})
+	case errors.As(err, &statusErr):
+		msg := statusErr.Message
+		if msg == "" {
+			msg = http.StatusText(statusErr.StatusCode)
+		}
+		c.JSON(statusErr.StatusCode, gin.H{"errors": []string{"upstream registry: " + msg}})
+	case errors.Is(err, mirror.ErrUnsupportedModuleSource):
+		c.JSON(http.StatusBadGateway, gin.H{"errors": []string{err.Error()}})
+	case errors.Is(err, scratch.ErrBudgetExceeded):
+		c.Header("Retry-After", "60")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"errors": []string{err.Error()}})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"errors": []string{"upstream registry timed out"}})
+	default:
+		slog.Error("module proxy request failed", "path", c.Request.URL.Path, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"errors": []string{"failed to fetch module from upstream registry"}})
+	}
+}
+
+// @Summary      List proxied module versions
+// @Description  Lists the versions an upstream registry offers for a module, via the module pull-through proxy. Falls back to locally cached versions when the upstream is unreachable. Only available when module_proxy.enabled is set; the hostname must be in module_proxy.allowed_hosts and allowed by the mirror policies.
+// @Tags         Modules
+// @Produce      json
+// @Param        hostname   path  string  true  "Upstream registry hostname (e.g. registry.terraform.io)"
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
+// @Success      200  {object}  modules.ModuleVersionsResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid path segment"
+// @Failure      403  {object}  map[string]interface{}  "Host or module not allowed for proxying"
+// @Failure      404  {object}  map[string]interface{}  "Module not found upstream"
+// @Failure      502  {object}  map[string]interface{}  "Upstream registry unreachable"
+// @Router       /v1/modules/proxy/{hostname}/{namespace}/{name}/{system}/versions [get]
+// ProxyListVersionsHandler handles listing upstream module versions
+// Implements: GET /v1/modules/proxy/:hostname/:namespace/:name/:system/versions
+func ProxyListVersionsHandler(svc *services.ModuleProxyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hostname, namespace, name, system, ok := proxyCoordinates(c)
+		if !ok {
+			return
+		}
+
+		versions, err := svc.ListVersions(c.Request.Context(), hostname, namespace, name, system)
+		if err != nil {
+			respondProxyError(c, err)
+			return
+		}
+
+		versionsList := make([]gin.H, 0, len(versions))
+		for _, v := range versions {
+			versionsList = append(versionsList, gin.H{"version": v})
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"modules": []gin.H{
+				{
+					"source":   hostname + "/" + namespace + "/" + name + "/" + system,
+					"versions": versionsList,
+				},
+			},
+		})
+	}
+}
+
+// @Summary      Download proxied module version
+// @Description  Returns a 204 response with an X-Terraform-Get header for a module version served through the module pull-through proxy. A version not yet cached is fetched from the upstream registry, stored locally, and then served; later requests use the local copy. Upstream errors are relayed with the upstream status.
+// @Tags         Modules
+// @Produce      json
+// @Param        hostname   path  string  true  "Upstream registry hostname (e.g. registry.terraform.io)"
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
+// @Param        version    path  string  true  "Semantic version (e.g. 1.2.3)"
+// @Success      204  "No Content — X-Terraform-Get header contains the download URL"
+// @Failure      400  {object}  map[string]interface{}  "Invalid path segment or version"
+// @Failure      403  {object}  map[string]interface{}  "Host or module not allowed for proxying"
+// @Failure      404  {object}  map[string]interface{}  "Module version not found upstream"
+// @Failure      409  {object}  map[string]interface{}  "A local module already uses these coordinates"
+// @Failure      502  {object}  map[string]interface{}  "Upstream registry unreachable or unsupported module source"
+// @Failure      503  {object}  map[string]interface{}  "Scratch space budget exceeded; retry later"
+// @Router       /v1/modules/proxy/{hostname}/{namespace}/{name}/{system}/{version}/download [get]
+// ProxyDownloadHandler handles proxied module download requests
+// Implements: GET /v1/modules/proxy/:hostname/:namespace/:name/:system/:version/download
+func ProxyDownloadHandler(svc *services.ModuleProxyService, moduleRepo *repositories.ModuleRepository, storageBackend storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hostname, namespace, name, system, ok := proxyCoordinates(c)
+		if !ok {
+			return
+		}
+		version := c.Param("version")
+		if err := validation.ValidateSemver(version); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{"Invalid version format - must be valid semantic versioning"},
+			})
+			return
+		}
+
+		moduleVersion, err := svc.Fetch(c.Request.Context(), hostname, namespace, name, system, version)
+		if err != nil {
+			respondProxyError(c, err)
+			return
+		}
+
+		downloadURL, err := storageBackend.GetURL(c.Request.Context(), moduleVersion.StoragePath, 15*time.Minute)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to generate download URL",
+			})
+			return
+		}
+
+		versionID := moduleVersion.ID
+		go func() {
+			if err := moduleRepo.IncrementDownloadCount(context.Background(), versionID); err != nil {
+				slog.Warn("failed to increment module download count", "version_id", versionID, "error", err)
+			}
+		}()
+		telemetry.ModuleDownloadsTotal.WithLabelValues(namespace, system).Inc()
+
+		c.Header("X-Terraform-Get", downloadURL)
+		c.Status(http.StatusNoContent)
+	}
+}
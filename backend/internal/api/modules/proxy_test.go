@@ -0,0 +1,81 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+func newProxyRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	svc := services.NewModuleProxyService(
+		config.ModuleProxyConfig{Enabled: true, AllowedHosts: []string{"registry.terraform.io"}},
+		nil, nil, nil, nil, &mockStore{}, "local",
+	)
+	r := gin.New()
+	r.GET("/v1/modules/proxy/:hostname/:namespace/:name/:system/versions", ProxyListVersionsHandler(svc))
+	r.GET("/v1/modules/proxy/:hostname/:namespace/:name/:system/:version/download", ProxyDownloadHandler(svc, nil, &mockStore{}))
+	return r
+}
+
+func TestProxyListVersionsHandler_InvalidSegment(t *testing.T) {
+	w := doGET(newProxyRouter(t), "/v1/modules/proxy/registry.terraform.io/hashi..corp/consul/aws/versions")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestProxyListVersionsHandler_HostNotAllowed(t *testing.T) {
+	w := doGET(newProxyRouter(t), "/v1/modules/proxy/evil.example.com/hashicorp/consul/aws/versions")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestProxyDownloadHandler_InvalidVersion(t *testing.T) {
+	w := doGET(newProxyRouter(t), "/v1/modules/proxy/registry.terraform.io/hashicorp/consul/aws/latest/download")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestRespondProxyError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"host not allowed", services.ErrModuleProxyHostNotAllowed, http.StatusForbidden, "not allowed"},
+		{"policy denied", &services.ModuleProxyDeniedError{Reason: "blocked by policy"}, http.StatusForbidden, "blocked by policy"},
+		{"conflict", services.ErrModuleProxyConflict, http.StatusConflict, "exists locally"},
+		{"upstream 404 relayed", fmt.Errorf("wrap: %w", &mirror.UpstreamStatusError{StatusCode: http.StatusNotFound, Message: "Not Found"}), http.StatusNotFound, "upstream registry: Not Found"},
+		{"upstream 429 relayed", &mirror.UpstreamStatusError{StatusCode: http.StatusTooManyRequests}, http.StatusTooManyRequests, "Too Many Requests"},
+		{"unsupported source", fmt.Errorf("%w: s3::bucket", mirror.ErrUnsupportedModuleSource), http.StatusBadGateway, "unsupported module source"},
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout, "timed out"},
+		{"network error", errors.New("dial tcp: connection refused"), http.StatusBadGateway, "failed to fetch module"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/modules/proxy/x/y/z/w/versions", nil)
+			respondProxyError(c, tt.err)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// redirect.go answers requests for the old address of a module that was moved
+// to another namespace or system (see POST .../change-system) with a redirect
+// to its current address, so pinned source addresses keep resolving.
+package modules
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// redirectMovedModule redirects the request when namespace/name/system is the
+// old address of a moved module and reports whether it did. A failed lookup
+// is logged and treated as "not moved" so the caller answers 404 as before.
+func redirectMovedModule(c *gin.Context, moduleRepo *repositories.ModuleRepository, orgID, namespace, name, system string) bool {
+	rd, err := moduleRepo.GetModuleRedirect(c.Request.Context(), orgID, namespace, name, system)
+	if err != nil {
+		slog.Warn("failed to look up module redirect", "namespace", namespace, "name", name, "system", system, "error", err)
+		return false
+	}
+	if rd == nil {
+		return false
+	}
+	target := rd.MovedPath(c.Request.URL.Path)
+	if target == "" {
+		return false
+	}
+	if q := c.Request.URL.RawQuery; q != "" {
+		target += "?" + q
+	}
+	c.Redirect(http.StatusMovedPermanently, target)
+	return true
+}
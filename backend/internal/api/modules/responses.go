@@ -1,6 +1,11 @@
 package modules
 
-import "time"
+import (
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
+	"github.com/terraform-registry/terraform-registry/internal/policy"
+)
 
 // ModuleUploadResponse is returned by POST /api/v1/modules.
 type ModuleUploadResponse struct {
@@ -13,6 +18,39 @@ type ModuleUploadResponse struct {
 	SizeBytes int64     `json:"size_bytes"`
 	Filename  string    `json:"filename"`
 	CreatedAt time.Time `json:"created_at"`
+	// DuplicateOf is the existing version whose archive is byte-identical;
+	// Alias reports that the new version shares that archive instead of
+	// storing a copy. Both are omitted when the archive is new.
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+	Alias       bool   `json:"alias,omitempty"`
+}
+
+// ModuleUploadDryRunResponse is returned by POST /api/v1/modules?dry_run=true
+// when the upload passes every check: what would have been published.
+type ModuleUploadDryRunResponse struct {
+	DryRun    bool   `json:"dry_run"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	System    string `json:"system"`
+	Version   string `json:"version"`
+	// Checksum is the SHA-256 (hex) of the archive.
+	Checksum  string `json:"checksum"`
+	SizeBytes int64  `json:"size_bytes"`
+	Filename  string `json:"filename"`
+	// ModuleExists is false when the publish would create the module.
+	ModuleExists             bool    `json:"module_exists"`
+	HasReadme                bool    `json:"has_readme"`
+	RequiredTerraformVersion *string `json:"required_terraform_version,omitempty"`
+	// DuplicateOf is the existing version whose archive is byte-identical;
+	// Alias reports that the publish would share that archive instead of
+	// storing a copy.
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+	Alias       bool   `json:"alias,omitempty"`
+	// Warnings lists problems that would not stop the publish.
+	Warnings []string `json:"warnings"`
+	// Violations lists the policy violations allowed in warn mode; in block
+	// mode they fail the request with 422 as a real publish does.
+	Violations []policy.Violation `json:"violations"`
 }
 
 // ModuleVersionEntry represents a single version in the module versions list response.
@@ -36,6 +74,9 @@ type ModuleVersionsModuleItem struct {
 // ModuleVersionsResponse is returned by GET /v1/modules/{namespace}/{name}/{system}/versions.
 type ModuleVersionsResponse struct {
 	Modules []ModuleVersionsModuleItem `json:"modules"`
+	// Warnings lists deprecated versions; sent only when
+	// protocol.deprecation_warnings is enabled.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // LinkModuleSCMResponse is returned by POST /api/v1/admin/modules/{id}/scm.
@@ -45,32 +86,163 @@ type LinkModuleSCMResponse struct {
 	WebhookCallbackURL string `json:"webhook_callback_url"`
 	WebhookRegistered  bool   `json:"webhook_registered"`
 	Note               string `json:"note"`
+	// ModuleAddress is the canonical hostname/namespace/name/system address
+	// of the module, stored on the link and stamped into publish metadata.
+	ModuleAddress string `json:"module_address"`
+	// RepositoryNameCheck is omitted when scm.repository_name_check is off.
+	RepositoryNameCheck *RepositoryNameCheck `json:"repository_name_check,omitempty"`
 }
 
-// SearchMetadata carries pagination info for search responses.
-type SearchMetadata struct {
-	Limit  int   `json:"limit"`
-	Offset int   `json:"offset"`
-	Total  int64 `json:"total"`
-} // @name PaginationMetadata
+// RepositoryNameCheck reports how a linked repository's name compares with the
+// terraform-<PROVIDER>-<NAME> naming convention for its module.
+type RepositoryNameCheck struct {
+	// Status is "match", "mismatch", "overridden" (a mismatch accepted with
+	// allow_name_mismatch) or "skipped" (the repository is not named by the
+	// convention, or the module lives in a subdirectory of it).
+	Status                 string `json:"status"`
+	RepositoryName         string `json:"repository_name"`
+	ExpectedRepositoryName string `json:"expected_repository_name"`
+	// Message explains a mismatch for display next to the link.
+	Message string `json:"message,omitempty"`
+}
+
+// RotateWebhookSecretResponse is the body of
+// POST /admin/modules/:id/scm/rotate-webhook-secret.
+type RotateWebhookSecretResponse struct {
+	Message            string `json:"message"`
+	WebhookCallbackURL string `json:"webhook_callback_url"`
+	// WebhookSecret is only returned here; it is omitted for providers that
+	// do not sign deliveries, whose callback URL embeds the secret instead.
+	WebhookSecret            string     `json:"webhook_secret,omitempty"`
+	WebhookRegistered        bool       `json:"webhook_registered"`
+	PreviousSecretValidUntil *time.Time `json:"previous_secret_valid_until,omitempty"`
+	Note                     string     `json:"note"`
+}
 
 // ModuleSearchItem represents a single module result in search responses.
 type ModuleSearchItem struct {
-	ID                 string     `json:"id"`
-	Namespace          string     `json:"namespace"`
-	Name               string     `json:"name"`
-	System             string     `json:"system"`
-	Description        string     `json:"description,omitempty"`
-	DownloadCount      int64      `json:"download_count"`
-	Deprecated         bool       `json:"deprecated"`
-	DeprecatedAt       *time.Time `json:"deprecated_at,omitempty"`
-	DeprecationMessage *string    `json:"deprecation_message,omitempty"`
-	SuccessorModuleID  *string    `json:"successor_module_id,omitempty"`
-	CreatedAt          time.Time  `json:"created_at"`
+	ID          string `json:"id"`
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	System      string `json:"system"`
+	Description string `json:"description,omitempty"`
+	// NamespaceDescription is the short description from the namespace's
+	// landing page, when one has been published.
+	NamespaceDescription string     `json:"namespace_description,omitempty"`
+	DownloadCount        int64      `json:"download_count"`
+	Deprecated           bool       `json:"deprecated"`
+	DeprecatedAt         *time.Time `json:"deprecated_at,omitempty"`
+	DeprecationMessage   *string    `json:"deprecation_message,omitempty"`
+	SuccessorModuleID    *string    `json:"successor_module_id,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	// Tier is the registry-assigned tier; omitted when it could not be loaded.
+	Tier string `json:"tier,omitempty"`
+}
+
+// ModuleSystemFacetItem is the number of matching modules for one system.
+type ModuleSystemFacetItem struct {
+	System string `json:"system"`
+	Count  int64  `json:"count"`
+}
+
+// ModuleSearchFacets groups the matching modules of a search.
+type ModuleSearchFacets struct {
+	Systems []ModuleSystemFacetItem `json:"systems"`
 }
 
 // ModuleSearchResponse is returned by GET /api/v1/modules/search.
 type ModuleSearchResponse struct {
 	Modules []ModuleSearchItem `json:"modules"`
-	Meta    SearchMetadata     `json:"meta"`
+	Meta    pagination.Meta    `json:"meta"`
+	// Facets is omitted when the counts could not be loaded.
+	Facets *ModuleSearchFacets `json:"facets,omitempty"`
+}
+
+// ProtocolListMeta carries pagination for the protocol discovery endpoints.
+// The next and previous fields are omitted at either end of the list.
+type ProtocolListMeta struct {
+	Limit         int    `json:"limit"`
+	CurrentOffset int    `json:"current_offset"`
+	NextOffset    *int   `json:"next_offset,omitempty"`
+	PrevOffset    *int   `json:"prev_offset,omitempty"`
+	NextURL       string `json:"next_url,omitempty"`
+	PrevURL       string `json:"prev_url,omitempty"`
+}
+
+// ProtocolModule is one module, at its latest version, in the protocol
+// discovery endpoints.
+type ProtocolModule struct {
+	ID          string    `json:"id"`
+	Owner       string    `json:"owner"`
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Provider    string    `json:"provider"`
+	Description string    `json:"description"`
+	Source      string    `json:"source"`
+	PublishedAt time.Time `json:"published_at"`
+	Downloads   int64     `json:"downloads"`
+	Verified    bool      `json:"verified"`
+}
+
+// ProtocolModuleList is returned by GET /v1/modules and GET /v1/modules/search.
+type ProtocolModuleList struct {
+	Meta    ProtocolListMeta `json:"meta"`
+	Modules []ProtocolModule `json:"modules"`
+}
+
+// ModuleChangelogResponse is returned by GET /api/v1/modules/{namespace}/{name}/{system}/versions/{version}/changelog.
+type ModuleChangelogResponse struct {
+	Version   string `json:"version"`
+	Changelog string `json:"changelog"`
+}
+
+// VersionPublisher identifies who published a version.
+type VersionPublisher struct {
+	ID   string  `json:"id"`
+	Name *string `json:"name,omitempty"`
+}
+
+// VersionDeprecation describes a deprecated version in extended documents.
+type VersionDeprecation struct {
+	DeprecatedAt      *time.Time `json:"deprecated_at,omitempty"`
+	Message           *string    `json:"message,omitempty"`
+	ReplacementSource *string    `json:"replacement_source,omitempty"`
+}
+
+// ModuleVersionExtended is one version in the extended version listing.
+type ModuleVersionExtended struct {
+	ID            string              `json:"id"`
+	Version       string              `json:"version"`
+	PublishedAt   time.Time           `json:"published_at"`
+	PublishedBy   *VersionPublisher   `json:"published_by,omitempty"`
+	DownloadCount int64               `json:"download_count"`
+	SizeBytes     int64               `json:"size_bytes"`
+	Checksum      string              `json:"checksum"`
+	CommitSHA     *string             `json:"commit_sha,omitempty"`
+	TagName       *string             `json:"tag_name,omitempty"`
+	HasDocs       bool                `json:"has_docs"`
+	Deprecated    bool                `json:"deprecated"`
+	Deprecation   *VersionDeprecation `json:"deprecation,omitempty"`
+	// Prerelease marks versions with a semver pre-release suffix, which the
+	// protocol document hides by default.
+	Prerelease bool `json:"prerelease"`
+	// RequiredTerraformVersion is the module's terraform required_version
+	// constraint, when it declares one.
+	RequiredTerraformVersion *string `json:"required_terraform_version,omitempty"`
+}
+
+// ModuleVersionsExtendedResponse is returned by
+// GET /v1/modules/{namespace}/{name}/{system}/versions when the request sends
+// `Accept: application/vnd.tfr.v1+json`.
+type ModuleVersionsExtendedResponse struct {
+	Namespace string                  `json:"namespace"`
+	Name      string                  `json:"name"`
+	System    string                  `json:"system"`
+	Source    *string                 `json:"source"`
+	Tier      string                  `json:"tier,omitempty"`
+	Versions  []ModuleVersionExtended `json:"versions"`
+	Total     int                     `json:"total"`
+	Limit     int                     `json:"limit"`
+	Offset    int                     `json:"offset"`
 }
@@ -0,0 +1,168 @@
+// scm_backfill.go implements tag backfills: publishing every historical tag of
+// a module's linked repository, typically right after the module is linked.
+package modules
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+)
+
+// Backfill statuses reported by GetBackfill.
+const (
+	backfillRunning   = "running"
+	backfillCompleted = "completed"
+)
+
+// @Summary      Backfill historical tags
+// @Description  Lists every tag of the linked repository and queues a publish for each tag that matches the link's tag
+// @Description  pattern (default: v*) and pre-release setting and whose version the module does not have yet. Tags
+// @Description  with a queued or running publish are left out. Publishes are queued oldest version first, so the newest
+// @Description  version is published last, and come due scm.backfill_interval apart so the SCM is not asked for every
+// @Description  archive at once. They are ordinary queued publishes: the scm-publish-queue job runs them, retries them
+// @Description  up to webhooks.max_retries times, and picks up where it stopped after a restart. Track progress with
+// @Description  GET .../scm/backfills/{backfill_id} or each publish with GET .../scm/publishes/{publish_id}. With
+// @Description  dry_run=true the tags are listed and nothing is queued. Answers 200 when there is nothing to backfill.
+// @Tags         SCM Linking
+// @Security     Bearer
+// @Produce      json
+// @Param        id       path   string  true   "Module ID (UUID)"
+// @Param        dry_run  query  bool    false  "Only list the tags that would be published"
+// @Success      200  {object}  admin.SCMBackfillResponse  "Dry run, or nothing to backfill"
+// @Success      202  {object}  admin.SCMBackfillResponse  "Publishes queued"
+// @Failure      400  {object}  map[string]interface{}  "Invalid module ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized or no OAuth token for this SCM provider"
+// @Failure      404  {object}  map[string]interface{}  "Module is not linked to a repository"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error (connector build, tag listing, queueing, etc.)"
+// @Router       /api/v1/admin/modules/{id}/scm/backfill [post]
+// BackfillTags queues publishes for a linked repository's historical tags
+// POST /api/v1/admin/modules/:id/scm/backfill
+func (h *SCMLinkingHandler) BackfillTags(c *gin.Context) {
+	moduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid module ID"})
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	link, err := h.scmRepo.GetModuleSourceRepo(c.Request.Context(), moduleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get repository link"})
+		return
+	}
+	if link == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module is not linked to a repository"})
+		return
+	}
+
+	connector, token, ok := h.syncCredentials(c, link)
+	if !ok {
+		return
+	}
+
+	plan, err := h.publisher.PlanBackfill(c.Request.Context(), link, connector, token)
+	if err != nil {
+		slog.Warn("backfill planning failed", "module_id", moduleID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "backfill failed: " + err.Error()})
+		return
+	}
+	if dryRun || len(plan) == 0 {
+		c.JSON(http.StatusOK, gin.H{"dry_run": dryRun, "tags": plan, "publishes": []*scm.PublishTask{}})
+		return
+	}
+
+	backfillID, tasks, err := h.publisher.QueueBackfill(c.Request.Context(), link, plan, h.maxPublishAttempts, h.backfillInterval)
+	if err != nil {
+		slog.Warn("backfill queueing failed", "module_id", moduleID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "backfill failed: " + err.Error()})
+		return
+	}
+	slog.Info("tag backfill queued", "module_id", moduleID, "backfill_id", backfillID, "tags", len(tasks))
+	c.JSON(http.StatusAccepted, gin.H{"backfill_id": backfillID, "dry_run": false, "tags": plan, "publishes": tasks})
+}
+
+// @Summary      Get tag backfill progress
+// @Description  Returns the publishes a tag backfill queued, in the order it queued them, with a count per status
+// @Description  (queued, running, succeeded, skipped, failed). The backfill is running while any publish is queued or
+// @Description  running and completed after that. Succeeded publishes carry the publish metadata of their version.
+// @Tags         SCM Linking
+// @Security     Bearer
+// @Produce      json
+// @Param        id           path  string  true  "Module ID (UUID)"
+// @Param        backfill_id  path  string  true  "Backfill ID (UUID)"
+// @Success      200  {object}  admin.SCMBackfillStatusResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid module or backfill ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Module is not linked to a repository, or backfill not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/modules/{id}/scm/backfills/{backfill_id} [get]
+// GetBackfill reports the progress of a tag backfill
+// GET /api/v1/admin/modules/:id/scm/backfills/:backfill_id
+func (h *SCMLinkingHandler) GetBackfill(c *gin.Context) {
+	moduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid module ID"})
+		return
+	}
+	backfillID, err := uuid.Parse(c.Param("backfill_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid backfill ID"})
+		return
+	}
+
+	link, err := h.scmRepo.GetModuleSourceRepo(c.Request.Context(), moduleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get repository link"})
+		return
+	}
+	if link == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module is not linked to a repository"})
+		return
+	}
+
+	tasks, err := h.scmRepo.ListBackfillTasks(c.Request.Context(), link.ID, backfillID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get backfill"})
+		return
+	}
+	if len(tasks) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "backfill not found"})
+		return
+	}
+
+	status := backfillCompleted
+	counts := map[scm.PublishTaskStatus]int{}
+	var versionIDs []uuid.UUID
+	for _, task := range tasks {
+		counts[task.Status]++
+		if task.Status == scm.PublishTaskQueued || task.Status == scm.PublishTaskRunning {
+			status = backfillRunning
+		}
+		if task.ResultVersionID != nil {
+			versionIDs = append(versionIDs, *task.ResultVersionID)
+		}
+	}
+	if len(versionIDs) > 0 {
+		metadata, err := h.scmRepo.ListPublishMetadata(c.Request.Context(), versionIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get publish metadata"})
+			return
+		}
+		for _, task := range tasks {
+			if task.ResultVersionID != nil {
+				task.PublishMetadata = metadata[*task.ResultVersionID]
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"backfill_id": backfillID,
+		"status":      status,
+		"total":       len(tasks),
+		"counts":      counts,
+		"publishes":   tasks,
+	})
+}
@@ -0,0 +1,129 @@
+package modules
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// ---------------------------------------------------------------------------
+// BackfillTags
+// ---------------------------------------------------------------------------
+
+func TestBackfillTags_InvalidModuleID(t *testing.T) {
+	_, _, r := newSCMLinkingRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/not-a-uuid/scm/backfill", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestBackfillTags_NotLinked(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sqlmock.NewRows(moduleSourceRepoColsLink))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm/backfill?dry_run=true", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestBackfillTags_ProviderNotFound(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+		WillReturnRows(sqlmock.NewRows(scmProviderColsLink))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm/backfill", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500: body=%s", w.Code, w.Body.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// GetBackfill
+// ---------------------------------------------------------------------------
+
+var backfillTaskCols = append(append([]string{}, publishTaskCols...), "backfill_id", "backfill_seq")
+
+func TestGetBackfill_Running(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	backfillID, versionID := uuid.New(), uuid.New()
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM scm_publish_tasks.*backfill_id").
+		WithArgs(sqlmock.AnyArg(), backfillID).
+		WillReturnRows(sqlmock.NewRows(backfillTaskCols).
+			AddRow(uuid.New(), uuid.New(), nil, "v1.0.0", "abc123",
+				"succeeded", 1, 1, time.Now(), nil,
+				nil, versionID, time.Now(), time.Now(), time.Now(), time.Now(), backfillID, 0).
+			AddRow(uuid.New(), uuid.New(), nil, "v1.1.0", "def456",
+				"queued", 0, 1, time.Now().Add(10*time.Second), nil,
+				nil, nil, nil, nil, time.Now(), time.Now(), backfillID, 1))
+	scmMock.ExpectQuery("SELECT.*FROM module_version_publish_metadata").
+		WillReturnRows(sqlmock.NewRows(publishMetadataCols).
+			AddRow(versionID, "1.0.0", "v1.0.0", "abc123", int64(2048), "deadbeef", int64(150), []byte(`[]`), time.Now(), ""))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/backfills/"+backfillID.String(), nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Status    string         `json:"status"`
+		Total     int            `json:"total"`
+		Counts    map[string]int `json:"counts"`
+		Publishes []struct {
+			TagName         string                 `json:"tag_name"`
+			PublishMetadata map[string]interface{} `json:"publish_metadata"`
+		} `json:"publishes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Status != backfillRunning || resp.Total != 2 || resp.Counts["succeeded"] != 1 || resp.Counts["queued"] != 1 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(resp.Publishes) != 2 || resp.Publishes[0].TagName != "v1.0.0" || resp.Publishes[0].PublishMetadata == nil {
+		t.Errorf("publishes = %+v", resp.Publishes)
+	}
+}
+
+func TestGetBackfill_NotFound(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM scm_publish_tasks.*backfill_id").
+		WillReturnRows(sqlmock.NewRows(backfillTaskCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/backfills/"+uuid.New().String(), nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetBackfill_InvalidID(t *testing.T) {
+	_, _, r := newSCMLinkingRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/backfills/not-a-uuid", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,345 @@
+// scm_link_health.go implements the SCM link health check and publishing
+// identity transfer for SCM-linked modules.
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+// scmStaleEventAge is how long a link may go without receiving a webhook event
+// before the health check warns about it.
+const scmStaleEventAge = 30 * 24 * time.Hour
+
+// SCM link health check and overall statuses.
+const (
+	SCMCheckOK      = "ok"
+	SCMCheckWarning = "warning"
+	SCMCheckError   = "error"
+
+	SCMLinkHealthy   = "healthy"
+	SCMLinkDegraded  = "degraded"
+	SCMLinkUnhealthy = "unhealthy"
+)
+
+// SCMLinkHealthCheck is one verified aspect of an SCM link.
+type SCMLinkHealthCheck struct {
+	Name    string `json:"name"` // token, repository, webhook, last_event
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Action tells an operator how to fix a non-ok check.
+	Action string `json:"action,omitempty"`
+}
+
+// SCMLinkHealthResponse is the body of GET /admin/modules/:id/scm/health.
+type SCMLinkHealthResponse struct {
+	Status           string               `json:"status"`
+	AuthMode         string               `json:"auth_mode"`
+	PublishingUserID *string              `json:"publishing_user_id,omitempty"`
+	TokenInvalidAt   *time.Time           `json:"token_invalid_at,omitempty"`
+	LastEventAt      *time.Time           `json:"last_event_at,omitempty"`
+	Checks           []SCMLinkHealthCheck `json:"checks"`
+}
+
+// scmHealthInput is everything evaluateSCMLinkHealth needs, gathered by the
+// handler so the evaluation itself stays free of I/O.
+type scmHealthInput struct {
+	link        *scm.ModuleSourceRepoRecord
+	authMode    string
+	appMode     bool
+	publisher   *string
+	token       *scm.OAuthToken // nil when no usable token could be resolved
+	tokenErr    error           // failure resolving the token (app-mode minting)
+	fetchErr    error           // result of fetching the repository with token
+	lastEventAt *time.Time
+	now         time.Time
+}
+
+// evaluateSCMLinkHealth turns the gathered link state into actionable checks.
+func evaluateSCMLinkHealth(in scmHealthInput) SCMLinkHealthResponse {
+	resp := SCMLinkHealthResponse{
+		AuthMode:         in.authMode,
+		PublishingUserID: in.publisher,
+		TokenInvalidAt:   in.link.TokenInvalidAt,
+		LastEventAt:      in.lastEventAt,
+	}
+	if in.appMode {
+		// Shared app credentials: there is no per-user publishing identity.
+		resp.PublishingUserID = nil
+	}
+
+	tokenUsable := false
+	switch {
+	case in.tokenErr != nil:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "token", Status: SCMCheckError,
+			Message: "Shared app credential could not be minted: " + in.tokenErr.Error(),
+			Action:  "Check the SCM provider's app credentials",
+		})
+	case in.token == nil:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "token", Status: SCMCheckError,
+			Message: "The publishing user has no stored token for this SCM provider",
+			Action:  "Have a user connected to this provider take over with POST .../scm/transfer-ownership",
+		})
+	case in.token.IsExpired() && in.token.RefreshToken == "":
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "token", Status: SCMCheckError,
+			Message: "The publishing token has expired and cannot be refreshed",
+			Action:  "Reconnect the publishing user to the SCM provider or transfer ownership",
+		})
+	case scm.IsAuthError(in.fetchErr):
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "token", Status: SCMCheckError,
+			Message: "The SCM provider rejected the publishing token",
+			Action:  "Reconnect the publishing user to the SCM provider or transfer ownership",
+		})
+	case in.token.IsExpired():
+		tokenUsable = true
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "token", Status: SCMCheckWarning,
+			Message: "The publishing token has expired; webhook publishes fail until it is refreshed",
+			Action:  "Trigger a manual sync as the publishing user to refresh the token",
+		})
+	default:
+		tokenUsable = true
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{Name: "token", Status: SCMCheckOK, Message: "Token is valid"})
+	}
+
+	repoName := in.link.RepositoryOwner + "/" + in.link.RepositoryName
+	switch {
+	case !tokenUsable:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "repository", Status: SCMCheckWarning,
+			Message: "Repository access not verified without a valid token",
+		})
+	case in.fetchErr == nil:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "repository", Status: SCMCheckOK, Message: repoName + " is accessible",
+		})
+	case errors.Is(in.fetchErr, scm.ErrRepoNotFound), errors.Is(in.fetchErr, scm.ErrRepoAccessDenied), isForbidden(in.fetchErr):
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "repository", Status: SCMCheckError,
+			Message: repoName + " was not found or is not accessible with the publishing token",
+			Action:  "Update the link if the repository moved, or grant the publishing user access",
+		})
+	default:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "repository", Status: SCMCheckWarning,
+			Message: "Could not reach the SCM provider: " + in.fetchErr.Error(),
+			Action:  "Retry later; check provider availability and registry egress",
+		})
+	}
+
+	switch {
+	case in.link.WebhookEnabled && in.link.WebhookID != nil:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{Name: "webhook", Status: SCMCheckOK, Message: "Webhook is registered"})
+	case in.lastEventAt != nil:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{Name: "webhook", Status: SCMCheckOK, Message: "Webhook was registered manually and is delivering events"})
+	default:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "webhook", Status: SCMCheckWarning,
+			Message: "No webhook is registered and no events have been received",
+			Action:  "Register the link's webhook URL in the repository settings",
+		})
+	}
+
+	switch {
+	case in.lastEventAt == nil:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "last_event", Status: SCMCheckWarning, Message: "No webhook events received yet",
+		})
+	case in.now.Sub(*in.lastEventAt) > scmStaleEventAge:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "last_event", Status: SCMCheckWarning,
+			Message: fmt.Sprintf("Last webhook event was received %d days ago", int(in.now.Sub(*in.lastEventAt).Hours()/24)),
+			Action:  "Check the webhook's recent deliveries in the repository settings",
+		})
+	default:
+		resp.Checks = append(resp.Checks, SCMLinkHealthCheck{
+			Name: "last_event", Status: SCMCheckOK,
+			Message: "Last webhook event was received " + in.lastEventAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	resp.Status = SCMLinkHealthy
+	for _, check := range resp.Checks {
+		if check.Status == SCMCheckError {
+			resp.Status = SCMLinkUnhealthy
+			break
+		}
+		if check.Status == SCMCheckWarning {
+			resp.Status = SCMLinkDegraded
+		}
+	}
+	return resp
+}
+
+func isForbidden(err error) bool {
+	var apiErr *scm.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden
+}
+
+// @Summary      Check SCM link health
+// @Description  Verifies a module's SCM link end-to-end: the publishing token is present, unexpired, and accepted by the provider; the repository is accessible with it; a webhook is registered; and how long ago the last webhook event arrived. Each check carries a status (ok/warning/error) and, when not ok, a suggested action. The overall status is unhealthy when any check errors and degraded when any warns.
+// @Tags         SCM Linking
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Module ID (UUID)"
+// @Success      200  {object}  modules.SCMLinkHealthResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid module ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Module is not linked to a repository"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/modules/{id}/scm/health [get]
+// GetSCMLinkHealth checks a module's SCM link end-to-end
+// GET /api/v1/admin/modules/:id/scm/health
+func (h *SCMLinkingHandler) GetSCMLinkHealth(c *gin.Context) {
+	moduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid module ID"})
+		return
+	}
+	ctx := c.Request.Context()
+
+	link, err := h.scmRepo.GetModuleSourceRepo(ctx, moduleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get repository link"})
+		return
+	}
+	if link == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module is not linked to a repository"})
+		return
+	}
+	provider, err := h.scmRepo.GetProvider(ctx, link.SCMProviderID)
+	if err != nil || provider == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "provider not found"})
+		return
+	}
+	module, err := h.moduleRepo.GetModuleByID(ctx, moduleID.String())
+	if err != nil || module == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get module"})
+		return
+	}
+	lastEventAt, err := h.scmRepo.GetLatestWebhookLogTime(ctx, link.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get webhook events"})
+		return
+	}
+
+	in := scmHealthInput{
+		link:        link,
+		authMode:    provider.AuthMode,
+		appMode:     provider.AuthMode == scm.AuthModeEntraApp || provider.AuthMode == scm.AuthModeGitHubApp,
+		publisher:   services.PublishingUserID(link, module.CreatedBy),
+		lastEventAt: lastEventAt,
+		now:         time.Now(),
+	}
+	publisherID := uuid.Nil
+	if in.publisher != nil {
+		publisherID, _ = uuid.Parse(*in.publisher)
+	}
+	if !in.appMode && publisherID == uuid.Nil {
+		// No resolvable publishing identity: nothing to resolve a token for.
+		c.JSON(http.StatusOK, evaluateSCMLinkHealth(in))
+		return
+	}
+	connector, token, connErr := h.connectorAndToken(ctx, provider, publisherID)
+	if connErr != nil {
+		if !in.appMode {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": connErr.Error()})
+			return
+		}
+		in.tokenErr = connErr
+	}
+	in.token = token
+	if token != nil && !(token.IsExpired() && token.RefreshToken == "") {
+		_, in.fetchErr = connector.FetchRepository(ctx, token, link.RepositoryOwner, link.RepositoryName)
+	}
+
+	c.JSON(http.StatusOK, evaluateSCMLinkHealth(in))
+}
+
+// @Summary      Transfer SCM link publishing identity
+// @Description  Makes the calling user the publishing identity for a module's SCM link, so webhook publishes use the caller's stored token for the link's provider instead of the previous owner's. The link, its webhook, and its event history are kept. The caller's token must be able to read the linked repository. Not applicable to providers using shared app credentials.
+// @Tags         SCM Linking
+// @Security     Bearer
+// @Produce      json
+// @Param        id  path  string  true  "Module ID (UUID)"
+// @Success      200  {object}  map[string]interface{}  "{\"message\", \"publishing_user_id\", \"previous_publishing_user_id\"}"
+// @Failure      400  {object}  map[string]interface{}  "Invalid module ID, app-credential provider, or caller has no usable token"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Module is not linked to a repository"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/modules/{id}/scm/transfer-ownership [post]
+// TransferSCMOwnership makes the caller the link's publishing identity
+// POST /api/v1/admin/modules/:id/scm/transfer-ownership
+func (h *SCMLinkingHandler) TransferSCMOwnership(c *gin.Context) {
+	moduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid module ID"})
+		return
+	}
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	ctx := c.Request.Context()
+
+	link, err := h.scmRepo.GetModuleSourceRepo(ctx, moduleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get repository link"})
+		return
+	}
+	if link == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module is not linked to a repository"})
+		return
+	}
+	provider, err := h.scmRepo.GetProvider(ctx, link.SCMProviderID)
+	if err != nil || provider == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "provider not found"})
+		return
+	}
+	if provider.AuthMode == scm.AuthModeEntraApp || provider.AuthMode == scm.AuthModeGitHubApp {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider uses shared app credentials; links have no per-user publishing identity"})
+		return
+	}
+
+	connector, token, err := h.connectorAndToken(ctx, provider, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if token == nil || (token.IsExpired() && token.RefreshToken == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "you have no valid token for this SCM provider; connect to it first"})
+		return
+	}
+	if _, err := connector.FetchRepository(ctx, token, link.RepositoryOwner, link.RepositoryName); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "your token cannot access the linked repository: " + err.Error()})
+		return
+	}
+
+	var previous *string
+	if link.PublishingUserID != nil {
+		p := link.PublishingUserID.String()
+		previous = &p
+	}
+	if err := h.scmRepo.SetModuleSourceRepoPublisher(ctx, link.ID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to transfer publishing identity"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":                     "publishing identity transferred",
+		"publishing_user_id":          userID.String(),
+		"previous_publishing_user_id": previous,
+	})
+}
@@ -0,0 +1,274 @@
+package modules
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+)
+
+// ---------------------------------------------------------------------------
+// evaluateSCMLinkHealth
+// ---------------------------------------------------------------------------
+
+func healthCheckStatus(resp SCMLinkHealthResponse, name string) string {
+	for _, c := range resp.Checks {
+		if c.Name == name {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func healthyInput() scmHealthInput {
+	now := time.Now()
+	webhookID := "hook-1"
+	recent := now.Add(-time.Hour)
+	publisher := uuid.New().String()
+	return scmHealthInput{
+		link: &scm.ModuleSourceRepoRecord{
+			RepositoryOwner: "owner",
+			RepositoryName:  "repo",
+			WebhookEnabled:  true,
+			WebhookID:       &webhookID,
+		},
+		authMode:    scm.AuthModeOAuthUser,
+		publisher:   &publisher,
+		token:       &scm.OAuthToken{AccessToken: "tok"},
+		lastEventAt: &recent,
+		now:         now,
+	}
+}
+
+func TestEvaluateSCMLinkHealth_Healthy(t *testing.T) {
+	resp := evaluateSCMLinkHealth(healthyInput())
+	if resp.Status != SCMLinkHealthy {
+		t.Errorf("status = %q, want %q: %+v", resp.Status, SCMLinkHealthy, resp.Checks)
+	}
+	if len(resp.Checks) != 4 {
+		t.Errorf("checks = %d, want 4", len(resp.Checks))
+	}
+}
+
+func TestEvaluateSCMLinkHealth_TokenProblems(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name       string
+		mutate     func(in *scmHealthInput)
+		wantToken  string
+		wantRepo   string
+		wantStatus string
+	}{
+		{"missing token", func(in *scmHealthInput) { in.token = nil }, SCMCheckError, SCMCheckWarning, SCMLinkUnhealthy},
+		{"expired without refresh", func(in *scmHealthInput) {
+			in.token = &scm.OAuthToken{AccessToken: "tok", ExpiresAt: &past}
+		}, SCMCheckError, SCMCheckWarning, SCMLinkUnhealthy},
+		{"expired with refresh", func(in *scmHealthInput) {
+			in.token = &scm.OAuthToken{AccessToken: "tok", RefreshToken: "r", ExpiresAt: &past}
+		}, SCMCheckWarning, SCMCheckOK, SCMLinkDegraded},
+		{"rejected by provider", func(in *scmHealthInput) {
+			in.fetchErr = scm.WrapRemoteError(http.StatusUnauthorized, "bad credentials", nil)
+		}, SCMCheckError, SCMCheckWarning, SCMLinkUnhealthy},
+		{"app credential mint failure", func(in *scmHealthInput) {
+			in.appMode, in.token, in.tokenErr = true, nil, errors.New("mint failed")
+		}, SCMCheckError, SCMCheckWarning, SCMLinkUnhealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := healthyInput()
+			tt.mutate(&in)
+			resp := evaluateSCMLinkHealth(in)
+			if got := healthCheckStatus(resp, "token"); got != tt.wantToken {
+				t.Errorf("token check = %q, want %q", got, tt.wantToken)
+			}
+			if got := healthCheckStatus(resp, "repository"); got != tt.wantRepo {
+				t.Errorf("repository check = %q, want %q", got, tt.wantRepo)
+			}
+			if resp.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", resp.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestEvaluateSCMLinkHealth_RepositoryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"not found", scm.ErrRepoNotFound, SCMCheckError},
+		{"forbidden", scm.WrapRemoteError(http.StatusForbidden, "forbidden", nil), SCMCheckError},
+		{"provider unavailable", scm.WrapRemoteError(http.StatusBadGateway, "bad gateway", nil), SCMCheckWarning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := healthyInput()
+			in.fetchErr = tt.err
+			resp := evaluateSCMLinkHealth(in)
+			if got := healthCheckStatus(resp, "repository"); got != tt.want {
+				t.Errorf("repository check = %q, want %q", got, tt.want)
+			}
+			if got := healthCheckStatus(resp, "token"); got != SCMCheckOK {
+				t.Errorf("token check = %q, want ok", got)
+			}
+		})
+	}
+}
+
+func TestEvaluateSCMLinkHealth_WebhookAndEvents(t *testing.T) {
+	in := healthyInput()
+	in.link.WebhookEnabled = false
+	in.link.WebhookID = nil
+	stale := in.now.Add(-45 * 24 * time.Hour)
+	in.lastEventAt = &stale
+
+	resp := evaluateSCMLinkHealth(in)
+	if got := healthCheckStatus(resp, "webhook"); got != SCMCheckOK {
+		t.Errorf("webhook check = %q, want ok for a manually registered webhook", got)
+	}
+	if got := healthCheckStatus(resp, "last_event"); got != SCMCheckWarning {
+		t.Errorf("last_event check = %q, want warning for a stale event", got)
+	}
+
+	in.lastEventAt = nil
+	resp = evaluateSCMLinkHealth(in)
+	if got := healthCheckStatus(resp, "webhook"); got != SCMCheckWarning {
+		t.Errorf("webhook check = %q, want warning with no webhook and no events", got)
+	}
+	if resp.Status != SCMLinkDegraded {
+		t.Errorf("status = %q, want %q", resp.Status, SCMLinkDegraded)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// GetSCMLinkHealth
+// ---------------------------------------------------------------------------
+
+func TestGetSCMLinkHealth_InvalidModuleID(t *testing.T) {
+	_, _, r := newSCMLinkingRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/not-a-uuid/scm/health", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestGetSCMLinkHealth_NotLinked(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sqlmock.NewRows(moduleSourceRepoColsLink))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/health", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetSCMLinkHealth_NoPublishingUser(t *testing.T) {
+	scmMock, modMock, r := newSCMLinkingRouter(t)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+		WillReturnRows(sampleSCMProviderRowLink())
+	modMock.ExpectQuery("SELECT.*FROM modules").
+		WillReturnRows(sampleModuleForSCMRow(scmLinkModuleUUID))
+	scmMock.ExpectQuery("SELECT MAX\\(created_at\\) FROM scm_webhook_events").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/health", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp SCMLinkHealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Status != SCMLinkUnhealthy {
+		t.Errorf("status = %q, want %q", resp.Status, SCMLinkUnhealthy)
+	}
+	if got := healthCheckStatus(resp, "token"); got != SCMCheckError {
+		t.Errorf("token check = %q, want error", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TransferSCMOwnership
+// ---------------------------------------------------------------------------
+
+func TestTransferSCMOwnership_InvalidModuleID(t *testing.T) {
+	_, _, r := newSCMLinkingRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/not-a-uuid/scm/transfer-ownership", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestTransferSCMOwnership_Unauthenticated(t *testing.T) {
+	_, _, r := newSCMLinkingRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm/transfer-ownership", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+}
+
+func TestTransferSCMOwnership_NotLinked(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouterWithUserID(t, uuid.New())
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sqlmock.NewRows(moduleSourceRepoColsLink))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm/transfer-ownership", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestTransferSCMOwnership_AppCredentialProvider(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouterWithUserID(t, uuid.New())
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+		WillReturnRows(sqlmock.NewRows(append(scmProviderColsLink, "auth_mode")).AddRow(
+			scmLinkProviderUUID, uuid.Nil.String(), "github", "github-provider",
+			nil, nil, "client-id",
+			"encrypted-secret", "webhook-secret",
+			true, time.Now(), time.Now(), scm.AuthModeGitHubApp,
+		))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm/transfer-ownership", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestTransferSCMOwnership_GetLinkDBError(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouterWithUserID(t, uuid.New())
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnError(errSCMLinkDB)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm/transfer-ownership", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500: body=%s", w.Code, w.Body.String())
+	}
+}
@@ -3,18 +3,26 @@ package modules
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
+	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/scm"
 	"github.com/terraform-registry/terraform-registry/internal/scm/appcreds"
 	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
 
 // SCMLinkingHandler handles module-SCM repository linking
@@ -25,16 +33,24 @@ type SCMLinkingHandler struct {
 	publicURL   string
 	publisher   *services.SCMPublisher
 	minter      appcreds.SharedMinter
+	// nameCheck is scm.repository_name_check; empty means warn.
+	nameCheck string
+	// backfillInterval spaces a tag backfill's publishes (scm.backfill_interval).
+	backfillInterval time.Duration
+	// maxPublishAttempts is how often each backfilled publish may be
+	// attempted; 1 unless WithBackfill sets retries.
+	maxPublishAttempts int
 }
 
 // NewSCMLinkingHandler creates a new SCM linking handler
 func NewSCMLinkingHandler(scmRepo *repositories.SCMRepository, moduleRepo *repositories.ModuleRepository, tokenCipher *crypto.TokenCipher, publicURL string, publisher *services.SCMPublisher) *SCMLinkingHandler {
 	return &SCMLinkingHandler{
-		scmRepo:     scmRepo,
-		moduleRepo:  moduleRepo,
-		tokenCipher: tokenCipher,
-		publicURL:   publicURL,
-		publisher:   publisher,
+		scmRepo:            scmRepo,
+		moduleRepo:         moduleRepo,
+		tokenCipher:        tokenCipher,
+		publicURL:          publicURL,
+		publisher:          publisher,
+		maxPublishAttempts: 1,
 	}
 }
 
@@ -45,6 +61,84 @@ func (h *SCMLinkingHandler) WithMinter(minter appcreds.SharedMinter) *SCMLinking
 	return h
 }
 
+// WithRepositoryNameCheck sets what linking a repository whose name names a
+// different module does (scm.repository_name_check). Returns the handler for
+// chaining.
+func (h *SCMLinkingHandler) WithRepositoryNameCheck(mode string) *SCMLinkingHandler {
+	h.nameCheck = mode
+	return h
+}
+
+// WithBackfill sets how far apart a tag backfill's publishes come due
+// (scm.backfill_interval) and lets each be retried maxRetries times after its
+// first attempt fails (webhooks.max_retries). Returns the handler for chaining.
+func (h *SCMLinkingHandler) WithBackfill(interval time.Duration, maxRetries int) *SCMLinkingHandler {
+	h.backfillInterval = interval
+	if maxRetries > 0 {
+		h.maxPublishAttempts = maxRetries + 1
+	}
+	return h
+}
+
+// Repository name check statuses reported in RepositoryNameCheck.Status.
+const (
+	repositoryNameMatch      = "match"
+	repositoryNameMismatch   = "mismatch"
+	repositoryNameOverridden = "overridden"
+	repositoryNameSkipped    = "skipped"
+)
+
+// checkRepositoryName compares repoName with the conventional repository name
+// of module. A repository not named terraform-<PROVIDER>-<NAME>, or holding
+// the module in a subdirectory (a monorepo), is skipped since its name says
+// nothing about the module. Returns nil when the check is off.
+func (h *SCMLinkingHandler) checkRepositoryName(repoName, modulePath string, module *models.Module, allowMismatch bool) *RepositoryNameCheck {
+	if h.nameCheck == config.RepositoryNameCheckOff {
+		return nil
+	}
+	check := &RepositoryNameCheck{
+		RepositoryName:         repoName,
+		ExpectedRepositoryName: validation.ModuleRepositoryName(module.Name, module.System),
+	}
+	switch {
+	case !validation.FollowsModuleRepositoryConvention(repoName) || !isRootModulePath(modulePath):
+		check.Status = repositoryNameSkipped
+	case validation.ModuleRepositoryNameMatches(repoName, module.Name, module.System):
+		check.Status = repositoryNameMatch
+	default:
+		check.Status = repositoryNameMismatch
+		if allowMismatch {
+			check.Status = repositoryNameOverridden
+		}
+		check.Message = fmt.Sprintf("repository %q is named for a different module than %s/%s/%s (expected %q)",
+			repoName, module.Namespace, module.Name, module.System, check.ExpectedRepositoryName)
+	}
+	return check
+}
+
+// blocksLink reports whether check must stop the link from being saved.
+func (h *SCMLinkingHandler) blocksLink(check *RepositoryNameCheck) bool {
+	return check != nil && check.Status == repositoryNameMismatch && h.nameCheck == config.RepositoryNameCheckBlock
+}
+
+// isRootModulePath reports whether a link's repository_path points at the
+// repository root.
+func isRootModulePath(p string) bool {
+	p = strings.Trim(p, "/")
+	return p == "" || p == "."
+}
+
+// moduleAddress returns the canonical source address of module on this
+// registry: hostname/namespace/name/system, with the hostname taken from the
+// public URL. Without a usable public URL the hostname is left off.
+func moduleAddress(publicURL string, module *models.Module) string {
+	address := module.Namespace + "/" + module.Name + "/" + module.System
+	if u, err := url.Parse(publicURL); err == nil && u.Host != "" {
+		return strings.ToLower(u.Host) + "/" + address
+	}
+	return address
+}
+
 // connectorAndToken builds an SCM connector for a provider and resolves an access
 // token for it. Providers in an app auth mode (entra_app/github_app) mint the
 // shared, admin-managed credential; legacy oauth_user providers use the requesting
@@ -118,12 +212,24 @@ type LinkSCMRequest struct {
 	ModulePath      string `json:"repository_path"`
 	TagPattern      string `json:"tag_pattern"`
 	AutoPublish     bool   `json:"auto_publish_enabled"`
+	// SkipPrerelease stops tags such as v1.4.0-rc.1 from being published.
+	SkipPrerelease bool `json:"skip_prerelease"`
+	// AllowNameMismatch links a repository whose terraform-<PROVIDER>-<NAME>
+	// name names a different module even when scm.repository_name_check is
+	// block.
+	AllowNameMismatch bool `json:"allow_name_mismatch"`
 }
 
 // @Summary      Link module to SCM repository
 // @Description  Link a module to a source repository in an SCM provider. Generates a unique webhook callback URL
-// @Description  (containing an embedded URL secret) that must be registered in the repository's webhook settings.
+// @Description  that must be registered in the repository's webhook settings with the SCM provider's webhook secret.
+// @Description  Deliveries are authenticated by their signature; for providers that cannot sign deliveries (Azure DevOps)
+// @Description  or have no webhook secret, the URL embeds a secret instead.
 // @Description  The module must not already be linked. Validates that both the module and the SCM provider exist.
+// @Description  A repository named terraform-<PROVIDER>-<NAME> is checked against the module's name and system; the result is
+// @Description  returned in repository_name_check. With scm.repository_name_check set to block, a mismatch is rejected with 422
+// @Description  unless allow_name_mismatch is set. The module's canonical address is stored on the link and recorded in the
+// @Description  publish metadata of the versions it publishes.
 // @Tags         SCM Linking
 // @Security     Bearer
 // @Accept       json
@@ -135,6 +241,7 @@ type LinkSCMRequest struct {
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      404  {object}  map[string]interface{}  "Module not found or SCM provider not found"
 // @Failure      409  {object}  map[string]interface{}  "Module is already linked to a repository"
+// @Failure      422  {object}  map[string]interface{}  "Repository name names a different module"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/admin/modules/{id}/scm [post]
 // LinkModuleToSCM links a module to an SCM repository
@@ -191,6 +298,16 @@ func (h *SCMLinkingHandler) LinkModuleToSCM(c *gin.Context) {
 		return
 	}
 
+	nameCheck := h.checkRepositoryName(req.RepositoryName, req.ModulePath, existingModule, req.AllowNameMismatch)
+	if h.blocksLink(nameCheck) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":                 nameCheck.Message + "; set allow_name_mismatch to link it anyway",
+			"repository_name_check": nameCheck,
+		})
+		return
+	}
+	address := moduleAddress(middleware.TenantPublicURL(c, h.publicURL), existingModule)
+
 	// Set defaults
 	if req.DefaultBranch == "" {
 		req.DefaultBranch = h.detectDefaultBranch(c.Request.Context(), c, provider, req.RepositoryOwner, req.RepositoryName)
@@ -202,7 +319,8 @@ func (h *SCMLinkingHandler) LinkModuleToSCM(c *gin.Context) {
 		req.TagPattern = "v*"
 	}
 
-	// Create the webhook secret
+	// Only embedded in the callback URL for providers that cannot sign
+	// deliveries with the provider's webhook secret.
 	webhookSecret := generateWebhookSecret()
 
 	// Create module source repo link
@@ -232,7 +350,7 @@ func (h *SCMLinkingHandler) LinkModuleToSCM(c *gin.Context) {
 		u := fmt.Sprintf("%s/%s/%s", repoBaseURL, req.RepositoryOwner, req.RepositoryName)
 		repoFullURL = &u
 	}
-	webhookCallbackURL := fmt.Sprintf("%s/webhooks/scm/%s/%s", h.publicURL, linkID, webhookSecret)
+	webhookCallbackURL := h.webhookCallbackURL(c, linkID, provider.ProviderType, provider.WebhookSecret, webhookSecret)
 
 	link := &scm.ModuleSourceRepoRecord{
 		ID:              linkID,
@@ -245,8 +363,10 @@ func (h *SCMLinkingHandler) LinkModuleToSCM(c *gin.Context) {
 		ModulePath:      req.ModulePath,
 		TagPattern:      req.TagPattern,
 		AutoPublish:     req.AutoPublish,
+		SkipPrerelease:  req.SkipPrerelease,
 		WebhookURL:      &webhookCallbackURL,
 		WebhookEnabled:  false, // Will be activated after webhook registration
+		ModuleAddress:   &address,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
@@ -285,17 +405,27 @@ func (h *SCMLinkingHandler) LinkModuleToSCM(c *gin.Context) {
 		webhookNote = "Auto-registration unavailable; register the webhook URL manually in your repository settings"
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message":              "module linked to repository",
-		"link_id":              linkID,
-		"webhook_callback_url": webhookCallbackURL,
-		"webhook_registered":   webhookRegistered,
-		"note":                 webhookNote,
+	if nameCheck != nil && nameCheck.Status != repositoryNameMatch && nameCheck.Status != repositoryNameSkipped {
+		slog.Warn("module linked to a repository named for a different module",
+			"module_id", moduleID, "module_address", address, "repository", req.RepositoryOwner+"/"+req.RepositoryName,
+			"status", nameCheck.Status)
+	}
+
+	c.JSON(http.StatusCreated, LinkModuleSCMResponse{
+		Message:             "module linked to repository",
+		LinkID:              linkID.String(),
+		WebhookCallbackURL:  webhookCallbackURL,
+		WebhookRegistered:   webhookRegistered,
+		Note:                webhookNote,
+		ModuleAddress:       address,
+		RepositoryNameCheck: nameCheck,
 	})
 }
 
 // @Summary      Update SCM repository link
 // @Description  Update the configuration of an existing SCM repository link for a module.
+// @Description  Renaming the repository or changing its path repeats the repository name check of the link endpoint,
+// @Description  including the 422 under scm.repository_name_check block unless allow_name_mismatch is set.
 // @Tags         SCM Linking
 // @Security     Bearer
 // @Accept       json
@@ -306,6 +436,7 @@ func (h *SCMLinkingHandler) LinkModuleToSCM(c *gin.Context) {
 // @Failure      400  {object}  map[string]interface{}  "Invalid module ID or request body"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      404  {object}  map[string]interface{}  "Module is not linked to a repository"
+// @Failure      422  {object}  map[string]interface{}  "Repository name names a different module"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/admin/modules/{id}/scm [put]
 // UpdateSCMLink updates the SCM link configuration
@@ -352,15 +483,43 @@ func (h *SCMLinkingHandler) UpdateSCMLink(c *gin.Context) {
 	if req.TagPattern != "" {
 		link.TagPattern = req.TagPattern
 	}
-	// AutoPublish is boolean: always update because false is a valid intentional value.
+	// AutoPublish and SkipPrerelease are boolean: always update because false
+	// is a valid intentional value.
 	link.AutoPublish = req.AutoPublish
+	link.SkipPrerelease = req.SkipPrerelease
+
+	// Re-check the name only when the change could alter the outcome; the
+	// module is looked up just for a conventionally named root repository.
+	var nameCheck *RepositoryNameCheck
+	if (req.RepositoryName != "" || req.ModulePath != "") && h.nameCheck != config.RepositoryNameCheckOff &&
+		validation.FollowsModuleRepositoryConvention(link.RepositoryName) && isRootModulePath(link.ModulePath) {
+		module, err := h.moduleRepo.GetModuleByID(c.Request.Context(), moduleID.String())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get module"})
+			return
+		}
+		if module != nil {
+			nameCheck = h.checkRepositoryName(link.RepositoryName, link.ModulePath, module, req.AllowNameMismatch)
+		}
+		if h.blocksLink(nameCheck) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":                 nameCheck.Message + "; set allow_name_mismatch to link it anyway",
+				"repository_name_check": nameCheck,
+			})
+			return
+		}
+	}
 
 	if err := h.scmRepo.UpdateModuleSourceRepo(c.Request.Context(), link); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update repository link"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "repository link updated"})
+	resp := gin.H{"message": "repository link updated"}
+	if nameCheck != nil {
+		resp["repository_name_check"] = nameCheck
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // @Summary      Unlink module from SCM repository
@@ -454,12 +613,19 @@ func (h *SCMLinkingHandler) GetModuleSCMInfo(c *gin.Context) {
 		return
 	}
 
+	link.LastPublish, err = h.scmRepo.GetLatestPublishMetadata(c.Request.Context(), link.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get publish metadata"})
+		return
+	}
+
 	c.JSON(http.StatusOK, link)
 }
 
 // @Summary      Trigger manual SCM sync
-// @Description  Manually trigger an asynchronous repository scan that imports matching tag-based versions from the
-// @Description  linked SCM repository. The endpoint returns 202 immediately; the sync runs in the background.
+// @Description  Manually trigger a repository scan that imports matching tag-based versions from the linked SCM
+// @Description  repository. The sync runs before the endpoint returns, and the response carries the publish metadata
+// @Description  (commit SHA, source archive size and digest, packaging duration, connector calls) of each new version.
 // @Description  Tags are matched against the configured pattern (default: v*) and the semantic version is extracted.
 // @Description  Versions that already exist in the registry are silently skipped. The caller's OAuth token is used
 // @Description  for SCM API access and is proactively refreshed if it is expired or within 5 minutes of expiry.
@@ -467,11 +633,11 @@ func (h *SCMLinkingHandler) GetModuleSCMInfo(c *gin.Context) {
 // @Security     Bearer
 // @Produce      json
 // @Param        id  path  string  true  "Module ID (UUID)"
-// @Success      202  {object}  admin.MessageResponse
+// @Success      200  {object}  admin.SCMSyncResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid module ID"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized or no OAuth token for this SCM provider"
 // @Failure      404  {object}  map[string]interface{}  "Module is not linked to a repository"
-// @Failure      500  {object}  map[string]interface{}  "Internal server error (connector build, token decryption, etc.)"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error (connector build, token decryption, sync failure, etc.)"
 // @Router       /api/v1/admin/modules/{id}/scm/sync [post]
 // TriggerManualSync manually triggers a repository sync
 // POST /api/v1/admin/modules/:id/scm/sync
@@ -496,56 +662,64 @@ func (h *SCMLinkingHandler) TriggerManualSync(c *gin.Context) {
 		return
 	}
 
+	connector, token, ok := h.syncCredentials(c, link)
+	if !ok {
+		return
+	}
+
+	slog.Debug("starting sync", "module_id", moduleID, "owner", link.RepositoryOwner, "repo", link.RepositoryName)
+	h.runManualSync(c, moduleID, link, connector, token)
+}
+
+// syncCredentials builds the connector and resolves the token a sync or
+// backfill of link lists tags with. App-mode providers use the shared,
+// admin-managed credential; oauth_user providers use the caller's token,
+// refreshed if it is expired or expires within 5 minutes. On failure it
+// writes the error response and returns false.
+func (h *SCMLinkingHandler) syncCredentials(c *gin.Context, link *scm.ModuleSourceRepoRecord) (scm.Connector, *scm.OAuthToken, bool) {
 	// Get the SCM provider
 	provider, err := h.scmRepo.GetProvider(c.Request.Context(), link.SCMProviderID)
 	if err != nil || provider == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "provider not found"})
-		return
+		return nil, nil, false
 	}
 
-	// App-mode providers use the shared, admin-managed credential — no per-user
-	// connection is required to trigger a sync.
+	// App-mode providers need no per-user connection.
 	if provider.AuthMode == scm.AuthModeEntraApp || provider.AuthMode == scm.AuthModeGitHubApp {
 		connector, token, connErr := h.connectorAndToken(c.Request.Context(), provider, uuid.Nil)
 		if connErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": connErr.Error()})
-			return
+			return nil, nil, false
 		}
-		go func() {
-			if syncErr := h.publisher.TriggerManualSync(context.Background(), link, connector, token); syncErr != nil {
-				slog.Warn("manual sync failed", "module_id", moduleID, "error", syncErr)
-			}
-		}()
-		c.JSON(http.StatusAccepted, gin.H{"message": "sync triggered"})
-		return
+		return connector, token, true
 	}
 
 	// Get user ID from context
 	userID, err := getUserIDFromContext(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
-		return
+		return nil, nil, false
 	}
 
 	// Get user's OAuth token for this provider
 	tokenRecord, err := h.scmRepo.GetUserToken(c.Request.Context(), userID, link.SCMProviderID)
 	if err != nil || tokenRecord == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "not connected to this SCM provider"})
-		return
+		return nil, nil, false
 	}
 
 	// Decrypt the access token
 	accessToken, err := h.tokenCipher.Open(tokenRecord.AccessTokenEncrypted)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decrypt access token"})
-		return
+		return nil, nil, false
 	}
 
 	// Decrypt client secret
 	clientSecret, err := h.tokenCipher.Open(provider.ClientSecretEncrypted)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decrypt client secret"})
-		return
+		return nil, nil, false
 	}
 
 	// Build connector
@@ -569,7 +743,7 @@ func (h *SCMLinkingHandler) TriggerManualSync(c *gin.Context) {
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create connector"})
-		return
+		return nil, nil, false
 	}
 
 	// Create OAuth token
@@ -613,30 +787,43 @@ func (h *SCMLinkingHandler) TriggerManualSync(c *gin.Context) {
 		}
 	}
 
-	// Trigger async sync in background using a new context
-	// (c.Request.Context() would be canceled when the HTTP response is sent)
-	slog.Debug("starting async sync", "module_id", moduleID, "owner", link.RepositoryOwner, "repo", link.RepositoryName)
-	go func() {
-		slog.Debug("running sync in goroutine", "module_id", moduleID)
-		if err := h.publisher.TriggerManualSync(context.Background(), link, connector, token); err != nil {
-			// Log error but don't fail the request
-			slog.Warn("manual sync failed", "module_id", moduleID, "error", err)
-		} else {
-			slog.Debug("manual sync completed successfully", "module_id", moduleID)
-		}
-	}()
+	return connector, token, true
+}
 
-	c.JSON(http.StatusAccepted, gin.H{"message": "sync triggered"})
+// runManualSync syncs link and responds with the metadata of the versions it
+// published. The sync is detached from the request's cancellation so a client
+// disconnect does not leave a version half-published.
+func (h *SCMLinkingHandler) runManualSync(c *gin.Context, moduleID uuid.UUID, link *scm.ModuleSourceRepoRecord, connector scm.Connector, token *scm.OAuthToken) {
+	published, err := h.publisher.TriggerManualSync(context.WithoutCancel(c.Request.Context()), link, connector, token)
+	if err != nil {
+		slog.Warn("manual sync failed", "module_id", moduleID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "sync failed: " + err.Error()})
+		return
+	}
+	slog.Debug("manual sync completed successfully", "module_id", moduleID, "published", len(published))
+	c.JSON(http.StatusOK, gin.H{"message": "sync completed", "published": published})
 }
 
+// webhookEventLimits are the page size default and cap of the webhook event
+// history.
+var webhookEventLimits = pagination.Limits{Default: 50, Max: 200}
+
+// errWebhookEventOffset rejects offset paging of the webhook event history,
+// which is paged by cursor only.
+var errWebhookEventOffset = errors.New("webhook events are paged with cursor, not offset or page")
+
 // @Summary      Get webhook event history
-// @Description  Retrieve the recent webhook event log for a module's SCM repository link (last 50 events).
+// @Description  Retrieve the webhook event log for a module's SCM repository link, newest first. Events that
+// @Description  queued a publish carry it as publish_task. Pages hold limit events (default 50, max 200); pass
+// @Description  meta.next_cursor as cursor to read the next page. The history does not accept offset or page.
 // @Tags         SCM Linking
 // @Security     Bearer
 // @Produce      json
-// @Param        id  path  string  true  "Module ID (UUID)"
+// @Param        id      path   string  true   "Module ID (UUID)"
+// @Param        limit   query  int     false  "Events per page, max 200 (default 50)"
+// @Param        cursor  query  string  false  "meta.next_cursor of the previous page"
 // @Success      200  {object}  admin.WebhookEventsResponse
-// @Failure      400  {object}  map[string]interface{}  "Invalid module ID"
+// @Failure      400  {object}  map[string]interface{}  "Invalid module ID, invalid cursor, or offset given"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      404  {object}  map[string]interface{}  "Module is not linked to a repository"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
@@ -650,6 +837,14 @@ func (h *SCMLinkingHandler) GetWebhookEvents(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid module ID"})
 		return
 	}
+	page, err := pagination.ParseCursor(c, webhookEventLimits)
+	if err == nil && page.Offset > 0 {
+		err = errWebhookEventOffset
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	link, err := h.scmRepo.GetModuleSourceRepo(c.Request.Context(), moduleID)
 	if err != nil {
@@ -661,14 +856,113 @@ func (h *SCMLinkingHandler) GetWebhookEvents(c *gin.Context) {
 		return
 	}
 
-	limit := 50 // Default limit
-	events, err := h.scmRepo.ListWebhookLogs(c.Request.Context(), link.ID, limit)
+	// One row past the limit tells whether another page follows.
+	events, err := h.scmRepo.ListWebhookLogsAfter(c.Request.Context(), link.ID, page.After, page.Limit+1)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get webhook events"})
 		return
 	}
+	events, hasMore := pagination.Trim(page, events)
+
+	var versionIDs []uuid.UUID
+	for _, e := range events {
+		if e.ResultVersionID != nil {
+			versionIDs = append(versionIDs, *e.ResultVersionID)
+		}
+	}
+	if len(versionIDs) > 0 {
+		metadata, err := h.scmRepo.ListPublishMetadata(c.Request.Context(), versionIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get publish metadata"})
+			return
+		}
+		for _, e := range events {
+			if e.ResultVersionID != nil {
+				e.PublishMetadata = metadata[*e.ResultVersionID]
+			}
+		}
+	}
+
+	eventIDs := make([]uuid.UUID, len(events))
+	for i, e := range events {
+		eventIDs[i] = e.ID
+	}
+	tasks, err := h.scmRepo.ListPublishTasksForEvents(c.Request.Context(), eventIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get publish tasks"})
+		return
+	}
+	for _, e := range events {
+		e.PublishTask = tasks[e.ID]
+	}
+
+	var last repositories.Keyset
+	if len(events) > 0 {
+		e := events[len(events)-1]
+		last = repositories.Keyset{CreatedAt: e.CreatedAt, ID: e.ID.String()}
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events, "meta": page.CursorMeta(hasMore, last)})
+}
+
+// @Summary      Get queued publish status
+// @Description  Returns a tag publish queued by a webhook delivery (the publish_id of the delivery response): its status
+// @Description  (queued, running, succeeded, skipped or failed), attempts, last error and, once it succeeded, the publish
+// @Description  metadata of the new version. A failed attempt is retried with backoff until max_attempts
+// @Description  (webhooks.max_retries + 1); failed is terminal.
+// @Tags         SCM Linking
+// @Security     Bearer
+// @Produce      json
+// @Param        id          path  string  true  "Module ID (UUID)"
+// @Param        publish_id  path  string  true  "Publish task ID (UUID)"
+// @Success      200  {object}  admin.SCMPublishTaskResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid module or publish ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Module is not linked to a repository, or publish not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/admin/modules/{id}/scm/publishes/{publish_id} [get]
+// GetPublishTask retrieves the status of a queued tag publish
+// GET /api/v1/admin/modules/:id/scm/publishes/:publish_id
+func (h *SCMLinkingHandler) GetPublishTask(c *gin.Context) {
+	moduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid module ID"})
+		return
+	}
+	taskID, err := uuid.Parse(c.Param("publish_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid publish ID"})
+		return
+	}
+
+	link, err := h.scmRepo.GetModuleSourceRepo(c.Request.Context(), moduleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get repository link"})
+		return
+	}
+	if link == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module is not linked to a repository"})
+		return
+	}
+
+	task, err := h.scmRepo.GetPublishTask(c.Request.Context(), link.ID, taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get publish"})
+		return
+	}
+	if task == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "publish not found"})
+		return
+	}
+	if task.ResultVersionID != nil {
+		metadata, err := h.scmRepo.ListPublishMetadata(c.Request.Context(), []uuid.UUID{*task.ResultVersionID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get publish metadata"})
+			return
+		}
+		task.PublishMetadata = metadata[*task.ResultVersionID]
+	}
 
-	c.JSON(http.StatusOK, gin.H{"events": events})
+	c.JSON(http.StatusOK, gin.H{"publish": task})
 }
 
 func generateWebhookSecret() string {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,7 +14,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/services"
 )
@@ -86,6 +89,13 @@ func linkBody(fields map[string]interface{}) *bytes.Buffer {
 
 func newSCMLinkingRouter(t *testing.T) (sqlmock.Sqlmock, sqlmock.Sqlmock, *gin.Engine) {
 	t.Helper()
+	return newSCMLinkingRouterWithNameCheck(t, "")
+}
+
+// newSCMLinkingRouterWithNameCheck is newSCMLinkingRouter with
+// scm.repository_name_check set to mode.
+func newSCMLinkingRouterWithNameCheck(t *testing.T, mode string) (sqlmock.Sqlmock, sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
 
 	// SCM repo uses sqlx
 	scmDB, scmMock, err := sqlmock.New()
@@ -105,7 +115,8 @@ func newSCMLinkingRouter(t *testing.T) (sqlmock.Sqlmock, sqlmock.Sqlmock, *gin.E
 	moduleRepo := repositories.NewModuleRepository(modDB)
 	tokenCipher := &crypto.TokenCipher{}
 	scmPublisher := &services.SCMPublisher{}
-	h := NewSCMLinkingHandler(scmRepo, moduleRepo, tokenCipher, "https://registry.example.com", scmPublisher)
+	h := NewSCMLinkingHandler(scmRepo, moduleRepo, tokenCipher, "https://registry.example.com", scmPublisher).
+		WithRepositoryNameCheck(mode)
 
 	r := gin.New()
 	r.POST("/modules/:id/scm", h.LinkModuleToSCM)
@@ -114,6 +125,12 @@ func newSCMLinkingRouter(t *testing.T) (sqlmock.Sqlmock, sqlmock.Sqlmock, *gin.E
 	r.GET("/modules/:id/scm", h.GetModuleSCMInfo)
 	r.POST("/modules/:id/scm/sync", h.TriggerManualSync)
 	r.GET("/modules/:id/scm/events", h.GetWebhookEvents)
+	r.GET("/modules/:id/scm/publishes/:publish_id", h.GetPublishTask)
+	r.POST("/modules/:id/scm/backfill", h.BackfillTags)
+	r.GET("/modules/:id/scm/backfills/:backfill_id", h.GetBackfill)
+	r.GET("/modules/:id/scm/health", h.GetSCMLinkHealth)
+	r.POST("/modules/:id/scm/transfer-ownership", h.TransferSCMOwnership)
+	r.POST("/modules/:id/scm/rotate-webhook-secret", h.RotateWebhookSecret)
 
 	return scmMock, modMock, r
 }
@@ -494,6 +511,9 @@ func TestGetSCMInfo_Success(t *testing.T) {
 	// GetModuleSourceRepo returns a link row
 	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
 		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM module_version_publish_metadata").
+		WillReturnRows(sqlmock.NewRows(publishMetadataCols).
+			AddRow(uuid.New(), "1.2.0", "v1.2.0", "abc123", int64(2048), "deadbeef", int64(150), []byte(`["FetchTagByName","DownloadSourceArchive"]`), time.Now(), "registry.example.com/hashicorp/vpc/aws"))
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm", nil))
@@ -511,6 +531,32 @@ func TestGetSCMInfo_Success(t *testing.T) {
 	if resp["repository_name"] != "repo" {
 		t.Errorf("unexpected repository_name: %v", resp["repository_name"])
 	}
+	last, _ := resp["last_publish"].(map[string]interface{})
+	if last["commit_sha"] != "abc123" || last["source_archive_size_bytes"] != float64(2048) {
+		t.Errorf("unexpected last_publish: %v", resp["last_publish"])
+	}
+}
+
+func TestGetSCMInfo_PublishMetadataDBError(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM module_version_publish_metadata").
+		WillReturnError(errSCMLinkDB)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500: body=%s", w.Code, w.Body.String())
+	}
+}
+
+// publishMetadataCols matches the columns read by the publish metadata queries.
+var publishMetadataCols = []string{
+	"module_version_id", "version", "tag_name", "commit_sha",
+	"source_archive_size_bytes", "source_archive_sha256", "package_duration_ms",
+	"connector_api_calls", "created_at", "module_address",
 }
 
 // ---------------------------------------------------------------------------
@@ -778,6 +824,108 @@ func TestGetWebhookEvents_Success(t *testing.T) {
 	}
 }
 
+func TestGetWebhookEvents_ReadsOnePastLimit(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM scm_webhook_events WHERE module_scm_repo_id.*LIMIT").
+		WithArgs(sqlmock.AnyArg(), 11).
+		WillReturnRows(sqlmock.NewRows(webhookEventCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/events?limit=10", nil))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"has_more":false`) {
+		t.Errorf("status = %d, want 200 with meta: body=%s", w.Code, w.Body.String())
+	}
+	if err := scmMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetWebhookEvents_RejectsOffset(t *testing.T) {
+	_, _, r := newSCMLinkingRouter(t)
+
+	for _, query := range []string{"offset=50", "page=2", "cursor=garbage"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/events?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// GetPublishTask
+// ---------------------------------------------------------------------------
+
+var publishTaskCols = []string{
+	"id", "module_scm_repo_id", "webhook_event_id", "tag_name", "commit_sha",
+	"status", "attempts", "max_attempts", "next_attempt_at", "locked_until",
+	"last_error", "result_version_id", "started_at", "completed_at", "created_at", "updated_at",
+}
+
+func TestGetPublishTask_Succeeded(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	taskID, versionID := uuid.New(), uuid.New()
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM scm_publish_tasks WHERE id").
+		WithArgs(taskID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(publishTaskCols).AddRow(
+			taskID, uuid.New(), uuid.New(), "v1.2.0", "abc123",
+			"succeeded", 2, 4, time.Now(), nil,
+			"failed to publish version: download failed", versionID, time.Now(), time.Now(), time.Now(), time.Now()))
+	scmMock.ExpectQuery("SELECT.*FROM module_version_publish_metadata").
+		WillReturnRows(sqlmock.NewRows(publishMetadataCols).
+			AddRow(versionID, "1.2.0", "v1.2.0", "abc123", int64(2048), "deadbeef", int64(150), []byte(`[]`), time.Now(), ""))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/publishes/"+taskID.String(), nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Publish struct {
+			Status          string                 `json:"status"`
+			Attempts        int                    `json:"attempts"`
+			PublishMetadata map[string]interface{} `json:"publish_metadata"`
+		} `json:"publish"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Publish.Status != "succeeded" || resp.Publish.Attempts != 2 || resp.Publish.PublishMetadata["version"] != "1.2.0" {
+		t.Errorf("publish = %+v", resp.Publish)
+	}
+}
+
+func TestGetPublishTask_NotFound(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM scm_publish_tasks WHERE id").
+		WillReturnRows(sqlmock.NewRows(publishTaskCols))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/publishes/"+uuid.New().String(), nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetPublishTask_InvalidID(t *testing.T) {
+	_, _, r := newSCMLinkingRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/modules/"+scmLinkModuleUUID+"/scm/publishes/not-a-uuid", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Router helper (with user_id injected into gin context)
 // ---------------------------------------------------------------------------
@@ -823,6 +971,7 @@ func newSCMLinkingRouterWithUserID(t *testing.T, userID uuid.UUID) (sqlmock.Sqlm
 	r.GET("/modules/:id/scm", h.GetModuleSCMInfo)
 	r.POST("/modules/:id/scm/sync", setUser, h.TriggerManualSync)
 	r.GET("/modules/:id/scm/events", h.GetWebhookEvents)
+	r.POST("/modules/:id/scm/transfer-ownership", setUser, h.TransferSCMOwnership)
 
 	return scmMock, modMock, r
 }
@@ -1013,3 +1162,149 @@ func TestGetUserIDFromContext_UnexpectedType(t *testing.T) {
 		t.Error("want error for unexpected type")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Repository name check
+// ---------------------------------------------------------------------------
+
+// expectLinkLookups queues the module, provider and existing-link lookups
+// LinkModuleToSCM makes before it checks the repository name.
+func expectLinkLookups(scmMock, modMock sqlmock.Sqlmock) {
+	modMock.ExpectQuery("SELECT.*FROM modules m.*WHERE m.id").
+		WillReturnRows(sampleModuleForSCMRow(scmLinkModuleUUID))
+	scmMock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+		WillReturnRows(sampleSCMProviderRowLink())
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sqlmock.NewRows(moduleSourceRepoColsLink))
+}
+
+func postLink(r *gin.Engine, fields map[string]interface{}) *httptest.ResponseRecorder {
+	fields["provider_id"] = scmLinkProviderUUID
+	fields["repository_owner"] = "owner"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm", linkBody(fields)))
+	return w
+}
+
+func TestLinkModule_RepositoryNameMatches(t *testing.T) {
+	scmMock, modMock, r := newSCMLinkingRouter(t)
+	expectLinkLookups(scmMock, modMock)
+	scmMock.ExpectExec("INSERT INTO module_scm_repos").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := postLink(r, map[string]interface{}{"repository_name": "terraform-aws-vpc", "default_branch": "main"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+	var resp LinkModuleSCMResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.ModuleAddress != "registry.example.com/hashicorp/vpc/aws" {
+		t.Errorf("module_address = %q", resp.ModuleAddress)
+	}
+	if resp.RepositoryNameCheck == nil || resp.RepositoryNameCheck.Status != repositoryNameMatch {
+		t.Errorf("repository_name_check = %+v, want match", resp.RepositoryNameCheck)
+	}
+}
+
+func TestLinkModule_RepositoryNameMismatchWarns(t *testing.T) {
+	scmMock, modMock, r := newSCMLinkingRouter(t)
+	expectLinkLookups(scmMock, modMock)
+	scmMock.ExpectExec("INSERT INTO module_scm_repos").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := postLink(r, map[string]interface{}{"repository_name": "terraform-google-network", "default_branch": "main"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+	var resp LinkModuleSCMResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	check := resp.RepositoryNameCheck
+	if check == nil || check.Status != repositoryNameMismatch || check.ExpectedRepositoryName != "terraform-aws-vpc" || check.Message == "" {
+		t.Errorf("repository_name_check = %+v, want a mismatch expecting terraform-aws-vpc", check)
+	}
+}
+
+func TestLinkModule_RepositoryNameMismatchBlocked(t *testing.T) {
+	scmMock, modMock, r := newSCMLinkingRouterWithNameCheck(t, config.RepositoryNameCheckBlock)
+	expectLinkLookups(scmMock, modMock)
+
+	w := postLink(r, map[string]interface{}{"repository_name": "terraform-google-network"})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422: body=%s", w.Code, w.Body.String())
+	}
+	if err := scmMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestLinkModule_RepositoryNameMismatchOverridden(t *testing.T) {
+	scmMock, modMock, r := newSCMLinkingRouterWithNameCheck(t, config.RepositoryNameCheckBlock)
+	expectLinkLookups(scmMock, modMock)
+	scmMock.ExpectExec("INSERT INTO module_scm_repos").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := postLink(r, map[string]interface{}{
+		"repository_name": "terraform-google-network", "default_branch": "main", "allow_name_mismatch": true,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+	var resp LinkModuleSCMResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.RepositoryNameCheck == nil || resp.RepositoryNameCheck.Status != repositoryNameOverridden {
+		t.Errorf("repository_name_check = %+v, want overridden", resp.RepositoryNameCheck)
+	}
+}
+
+func TestLinkModule_RepositoryNameCheckOff(t *testing.T) {
+	scmMock, modMock, r := newSCMLinkingRouterWithNameCheck(t, config.RepositoryNameCheckOff)
+	expectLinkLookups(scmMock, modMock)
+	scmMock.ExpectExec("INSERT INTO module_scm_repos").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := postLink(r, map[string]interface{}{"repository_name": "terraform-google-network", "default_branch": "main"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("repository_name_check")) {
+		t.Errorf("body = %s, want no repository_name_check", w.Body.String())
+	}
+}
+
+func TestCheckRepositoryName_Skipped(t *testing.T) {
+	h := &SCMLinkingHandler{}
+	module := &models.Module{Namespace: "hashicorp", Name: "vpc", System: "aws"}
+	tests := []struct{ repo, path string }{
+		{"infra-modules", "/"},
+		{"terraform-aws-modules", "/modules/vpc"},
+	}
+	for _, tt := range tests {
+		if got := h.checkRepositoryName(tt.repo, tt.path, module, false); got.Status != repositoryNameSkipped {
+			t.Errorf("checkRepositoryName(%q, %q) status = %q, want skipped", tt.repo, tt.path, got.Status)
+		}
+	}
+}
+
+func TestUpdateSCMLink_RenameBlocked(t *testing.T) {
+	scmMock, modMock, r := newSCMLinkingRouterWithNameCheck(t, config.RepositoryNameCheckBlock)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sampleModuleSourceRepoRowLink())
+	modMock.ExpectQuery("SELECT.*FROM modules m.*WHERE m.id").
+		WillReturnRows(sampleModuleForSCMRow(scmLinkModuleUUID))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/modules/"+scmLinkModuleUUID+"/scm",
+		linkBody(map[string]interface{}{
+			"provider_id":      scmLinkProviderUUID,
+			"repository_owner": "owner",
+			"repository_name":  "terraform-google-network",
+		})))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422: body=%s", w.Code, w.Body.String())
+	}
+	if err := scmMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
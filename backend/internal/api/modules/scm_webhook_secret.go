@@ -0,0 +1,231 @@
+// scm_webhook_secret.go implements webhook secret rotation for SCM-linked
+// modules.
+package modules
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+)
+
+// Grace period bounds for webhook secret rotation, in hours.
+const (
+	defaultWebhookSecretGraceHours = 24
+	maxWebhookSecretGraceHours     = 7 * 24
+)
+
+// RotateWebhookSecretRequest is the optional body of
+// POST /admin/modules/:id/scm/rotate-webhook-secret.
+type RotateWebhookSecretRequest struct {
+	// GracePeriodHours is how long the replaced secret and callback URL keep
+	// being accepted. Defaults to 24; 0 stops accepting them immediately.
+	GracePeriodHours *int `json:"grace_period_hours" binding:"omitempty,min=0,max=168"`
+}
+
+// webhookCallbackURL builds a link's callback URL. Providers that sign
+// deliveries with a secret get the secretless form; for the rest pathSecret is
+// embedded as the last segment, since it is the only thing authenticating a
+// delivery. A link made on a tenant domain gets a callback URL on that domain.
+func (h *SCMLinkingHandler) webhookCallbackURL(c *gin.Context, linkID uuid.UUID, providerType scm.ProviderType, signingSecret, pathSecret string) string {
+	publicURL := middleware.TenantPublicURL(c, h.publicURL)
+	if providerType.SignsDeliveries() && signingSecret != "" {
+		return fmt.Sprintf("%s/webhooks/scm/%s", publicURL, linkID)
+	}
+	return fmt.Sprintf("%s/webhooks/scm/%s/%s", publicURL, linkID, pathSecret)
+}
+
+// generateSigningSecret returns a random secret for signing webhook deliveries.
+func generateSigningSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// @Summary      Rotate SCM webhook secret
+// @Description  Generates a new webhook secret for a module's SCM link and moves the link to a callback URL without an embedded secret (providers that do not sign deliveries, such as Azure DevOps, get a new embedded secret instead). When the webhook was registered automatically it is re-registered with the new URL and secret; otherwise the repository's webhook must be updated by hand with the returned values. The replaced secret and callback URL keep being accepted for grace_period_hours (default 24, at most 168). The new secret is only returned by this call.
+// @Tags         SCM Linking
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                      true   "Module ID (UUID)"
+// @Param        body  body  RotateWebhookSecretRequest  false  "Grace period for the replaced secret"
+// @Success      200  {object}  modules.RotateWebhookSecretResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid module ID or request body, or caller has no usable token to update the registered webhook"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Module is not linked to a repository"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      502  {object}  map[string]interface{}  "The SCM provider rejected the webhook update"
+// @Router       /api/v1/admin/modules/{id}/scm/rotate-webhook-secret [post]
+// RotateWebhookSecret replaces the webhook secret of a module's SCM link
+// POST /api/v1/admin/modules/:id/scm/rotate-webhook-secret
+func (h *SCMLinkingHandler) RotateWebhookSecret(c *gin.Context) {
+	moduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid module ID"})
+		return
+	}
+	var req RotateWebhookSecretRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	graceHours := defaultWebhookSecretGraceHours
+	if req.GracePeriodHours != nil {
+		graceHours = *req.GracePeriodHours
+	}
+	ctx := c.Request.Context()
+
+	link, err := h.scmRepo.GetModuleSourceRepo(ctx, moduleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get repository link"})
+		return
+	}
+	if link == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "module is not linked to a repository"})
+		return
+	}
+	provider, err := h.scmRepo.GetProvider(ctx, link.SCMProviderID)
+	if err != nil || provider == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "provider not found"})
+		return
+	}
+
+	secret, err := generateSigningSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate webhook secret"})
+		return
+	}
+	callbackURL := h.webhookCallbackURL(c, link.ID, provider.ProviderType, secret, generateWebhookSecret())
+
+	oldSecret := provider.WebhookSecret
+	if link.WebhookSecret != nil {
+		oldSecret = *link.WebhookSecret
+	}
+	oldURL := ""
+	if link.WebhookURL != nil {
+		oldURL = *link.WebhookURL
+	}
+
+	if link.WebhookID != nil {
+		userID, uidErr := getUserIDFromContext(c)
+		if uidErr != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			return
+		}
+		connector, token, connErr := h.connectorAndToken(ctx, provider, userID)
+		if connErr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": connErr.Error()})
+			return
+		}
+		if token == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "you have no valid token for this SCM provider to update the registered webhook; connect to it first"})
+			return
+		}
+
+		hookID, restoredID, replaceErr := replaceRemoteWebhook(ctx, connector, token, link, *link.WebhookID,
+			scm.WebhookSetup{CallbackURL: callbackURL, SharedSecret: secret, EventTypes: []string{"push"}, ActiveOnSetup: true},
+			scm.WebhookSetup{CallbackURL: oldURL, SharedSecret: oldSecret, EventTypes: []string{"push"}, ActiveOnSetup: true},
+		)
+		if replaceErr != nil {
+			msg := "failed to update the registered webhook: " + replaceErr.Error()
+			if restoredID != *link.WebhookID {
+				// The old hook is gone; record what is registered now.
+				link.WebhookID, link.WebhookEnabled = nil, false
+				if restoredID != "" {
+					link.WebhookID, link.WebhookEnabled = &restoredID, true
+				} else {
+					msg += "; the previous webhook was removed and could not be restored, register the callback URL manually"
+				}
+				if updErr := h.scmRepo.UpdateModuleSourceRepo(ctx, link); updErr != nil {
+					slog.Warn("failed to persist webhook state after failed rotation", "link_id", link.ID, "error", updErr)
+				}
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": msg})
+			return
+		}
+		link.WebhookID = &hookID
+		link.WebhookEnabled = true
+	}
+
+	link.PreviousWebhookSecret = link.WebhookSecret
+	link.PreviousWebhookURL = link.WebhookURL
+	link.WebhookSecret = &secret
+	link.WebhookURL = &callbackURL
+	link.WebhookSecretGraceUntil = nil
+	if graceHours > 0 {
+		until := time.Now().Add(time.Duration(graceHours) * time.Hour)
+		link.WebhookSecretGraceUntil = &until
+	}
+	if err := h.scmRepo.RotateModuleSourceRepoWebhookSecret(ctx, link); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save rotated webhook secret"})
+		return
+	}
+
+	resp := RotateWebhookSecretResponse{
+		Message:                  "webhook secret rotated",
+		WebhookCallbackURL:       callbackURL,
+		WebhookRegistered:        link.WebhookID != nil,
+		PreviousSecretValidUntil: link.WebhookSecretGraceUntil,
+		Note:                     "Webhook updated automatically",
+	}
+	if provider.ProviderType.SignsDeliveries() {
+		resp.WebhookSecret = secret
+	}
+	if link.WebhookID == nil {
+		resp.Note = "Update the repository's webhook with the new callback URL and secret"
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// replaceRemoteWebhook swaps the registered webhook hookID for one set up as
+// next and returns its ID. Some providers refuse a second hook with the same
+// callback URL, so the old hook is removed first. If the new hook cannot be
+// created the old one is registered again from previous; restoredID is then
+// the ID of the hook now registered: hookID when the old hook was never
+// removed, the re-registered hook's ID, or "" when nothing is registered.
+func replaceRemoteWebhook(ctx context.Context, connector scm.Connector, token *scm.OAuthToken, link *scm.ModuleSourceRepoRecord, hookID string, next, previous scm.WebhookSetup) (newID, restoredID string, err error) {
+	owner, repo := link.RepositoryOwner, link.RepositoryName
+	removed := true
+	if rmErr := connector.RemoveWebhook(ctx, token, owner, repo, hookID); rmErr != nil {
+		removed = false
+		slog.Warn("failed to remove webhook before rotation", "webhook_id", hookID, "owner", owner, "repo", repo, "error", rmErr)
+	}
+
+	hook, regErr := connector.RegisterWebhook(ctx, token, owner, repo, next)
+	if regErr == nil && hook != nil {
+		if !removed {
+			// The old hook would keep delivering with the old secret until
+			// the grace period ends; try once more to clean it up.
+			if rmErr := connector.RemoveWebhook(ctx, token, owner, repo, hookID); rmErr != nil {
+				slog.Warn("failed to remove replaced webhook", "webhook_id", hookID, "owner", owner, "repo", repo, "error", rmErr)
+			}
+		}
+		return hook.ExternalID, "", nil
+	}
+	if regErr == nil {
+		regErr = fmt.Errorf("provider returned no webhook")
+	}
+	if !removed {
+		return "", hookID, regErr
+	}
+
+	restored, restoreErr := connector.RegisterWebhook(ctx, token, owner, repo, previous)
+	if restoreErr != nil || restored == nil {
+		slog.Warn("failed to restore webhook after failed rotation", "owner", owner, "repo", repo, "error", restoreErr)
+		return "", "", regErr
+	}
+	return "", restored.ExternalID, regErr
+}
@@ -0,0 +1,167 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+)
+
+// ---------------------------------------------------------------------------
+// replaceRemoteWebhook
+// ---------------------------------------------------------------------------
+
+// webhookConnector records webhook registrations; the embedded nil Connector
+// panics on anything else.
+type webhookConnector struct {
+	scm.Connector
+	registerErrs []error // consumed one per RegisterWebhook call
+	removeErr    error
+	registered   []scm.WebhookSetup
+	removed      []string
+}
+
+func (w *webhookConnector) RegisterWebhook(_ context.Context, _ *scm.AccessToken, _, _ string, setup scm.WebhookSetup) (*scm.WebhookInfo, error) {
+	if len(w.registerErrs) > 0 {
+		err := w.registerErrs[0]
+		w.registerErrs = w.registerErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	w.registered = append(w.registered, setup)
+	return &scm.WebhookInfo{ExternalID: setup.CallbackURL}, nil
+}
+
+func (w *webhookConnector) RemoveWebhook(_ context.Context, _ *scm.AccessToken, _, _, hookID string) error {
+	if w.removeErr != nil {
+		return w.removeErr
+	}
+	w.removed = append(w.removed, hookID)
+	return nil
+}
+
+func TestReplaceRemoteWebhook(t *testing.T) {
+	link := &scm.ModuleSourceRepoRecord{RepositoryOwner: "owner", RepositoryName: "repo"}
+	next := scm.WebhookSetup{CallbackURL: "new", SharedSecret: "s2"}
+	previous := scm.WebhookSetup{CallbackURL: "old", SharedSecret: "s1"}
+	errRejected := errors.New("rejected")
+
+	tests := []struct {
+		name         string
+		conn         *webhookConnector
+		wantNew      string
+		wantRestored string
+		wantErr      bool
+	}{
+		{"replaced", &webhookConnector{}, "new", "", false},
+		{"registration fails, old hook restored", &webhookConnector{registerErrs: []error{errRejected}}, "", "old", true},
+		{"registration and restore fail", &webhookConnector{registerErrs: []error{errRejected, errRejected}}, "", "", true},
+		{"old hook could not be removed", &webhookConnector{registerErrs: []error{errRejected}, removeErr: errRejected}, "", "hook-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newID, restoredID, err := replaceRemoteWebhook(context.Background(), tt.conn, &scm.OAuthToken{}, link, "hook-1", next, previous)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if newID != tt.wantNew || restoredID != tt.wantRestored {
+				t.Errorf("ids = (%q, %q), want (%q, %q)", newID, restoredID, tt.wantNew, tt.wantRestored)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// RotateWebhookSecret
+// ---------------------------------------------------------------------------
+
+func TestRotateWebhookSecret_InvalidModuleID(t *testing.T) {
+	_, _, r := newSCMLinkingRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/not-a-uuid/scm/rotate-webhook-secret", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestRotateWebhookSecret_GracePeriodTooLong(t *testing.T) {
+	_, _, r := newSCMLinkingRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm/rotate-webhook-secret",
+		linkBody(map[string]interface{}{"grace_period_hours": 500})))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRotateWebhookSecret_NotLinked(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sqlmock.NewRows(moduleSourceRepoColsLink))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm/rotate-webhook-secret", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRotateWebhookSecret_ManualWebhook(t *testing.T) {
+	scmMock, _, r := newSCMLinkingRouter(t)
+	linkID := uuid.New()
+	legacyURL := "https://registry.example.com/webhooks/scm/" + linkID.String() + "/old-secret"
+	scmMock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE module_id").
+		WillReturnRows(sqlmock.NewRows(moduleSourceRepoColsLink).AddRow(
+			linkID, scmLinkModuleUUID, scmLinkProviderUUID,
+			"owner", "repo", nil,
+			"main", "", "v*",
+			false, nil, legacyURL,
+			false, nil, nil,
+			time.Now(), time.Now(),
+		))
+	scmMock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+		WillReturnRows(sampleSCMProviderRowLink())
+	scmMock.ExpectExec("UPDATE module_scm_repos SET.*webhook_secret = \\$2").
+		WithArgs(linkID, sqlmock.AnyArg(), "https://registry.example.com/webhooks/scm/"+linkID.String(), nil, false,
+			nil, legacyURL, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/modules/"+scmLinkModuleUUID+"/scm/rotate-webhook-secret",
+		linkBody(map[string]interface{}{"grace_period_hours": 2})))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp RotateWebhookSecretResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if strings.HasSuffix(resp.WebhookCallbackURL, "old-secret") || !strings.HasSuffix(resp.WebhookCallbackURL, linkID.String()) {
+		t.Errorf("webhook_callback_url = %q, want the secretless URL", resp.WebhookCallbackURL)
+	}
+	if len(resp.WebhookSecret) != 64 {
+		t.Errorf("webhook_secret = %q, want 64 hex characters", resp.WebhookSecret)
+	}
+	if resp.WebhookRegistered {
+		t.Error("webhook_registered = true for a manually registered webhook")
+	}
+	if resp.PreviousSecretValidUntil == nil || time.Until(*resp.PreviousSecretValidUntil) > 2*time.Hour {
+		t.Errorf("previous_secret_valid_until = %v, want about 2h from now", resp.PreviousSecretValidUntil)
+	}
+	if err := scmMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
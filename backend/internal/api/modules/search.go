@@ -3,14 +3,20 @@ package modules
 
 import (
 	"database/sql"
+	"log/slog"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 )
 
+// searchLimits are the page size default and cap of the search endpoint.
+var searchLimits = pagination.Limits{Default: 20, Max: 100}
+
 // validModuleSortFields defines the allowed values for the sort query parameter.
 var validModuleSortFields = map[string]bool{
 	"":          true,
@@ -22,25 +28,28 @@ var validModuleSortFields = map[string]bool{
 }
 
 // @Summary      Search modules
-// @Description  Search for modules by name, namespace, or provider system with pagination and sorting.
+// @Description  Search for modules by name, namespace, or provider system with pagination and sorting. Each result carries its registry-assigned tier (community, approved, official, mirrored, or a custom tier); tier filters on it, accepting verified and partner as aliases of approved. Results from a namespace with a published landing page carry its short description in namespace_description. facets.systems counts the matching modules per system (ignoring the system filter); tool-agnostic modules use the reserved generic system.
 // @Tags         Modules
 // @Produce      json
 // @Param        q          query  string  false  "Search query"
 // @Param        namespace  query  string  false  "Filter by namespace"
 // @Param        system     query  string  false  "Filter by target system"
+// @Param        tier       query  string  false  "Filter by tier (e.g. approved)"
 // @Param        sort       query  string  false  "Sort field: relevance, name, downloads, created, updated"
 // @Param        order      query  string  false  "Sort order: asc or desc (default desc)"
-// @Param        limit      query  int     false  "Maximum results to return (default 20, max 100)"
-// @Param        offset     query  int     false  "Offset for pagination (default 0)"
+// @Param        scope      query  string  false  "all to search every namespace on a custom tenant domain; by default results there are limited to the tenant organization's namespaces"
+// @Param        limit      query  int     false  "Maximum results to return (default 20, max 100); alias per_page"
+// @Param        offset     query  int     false  "Offset for pagination (default 0); alias page (1-based)"
 // @Success      200  {object}  modules.ModuleSearchResponse
-// @Failure      400  {object}  map[string]interface{}  "Invalid sort parameter"
+// @Failure      400  {object}  map[string]interface{}  "Invalid sort or tier parameter"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/modules/search [get]
 // SearchHandler handles module search requests
-// Implements: GET /api/v1/modules/search?q=<query>&namespace=<namespace>&system=<system>&sort=<sort>&order=<order>&limit=<limit>&offset=<offset>
+// Implements: GET /api/v1/modules/search?q=<query>&namespace=<namespace>&system=<system>&tier=<tier>&sort=<sort>&order=<order>&limit=<limit>&offset=<offset>
 func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 	moduleRepo := repositories.NewModuleRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
+	namespaceMetaRepo := repositories.NewNamespaceMetadataRepository(db)
 
 	return func(c *gin.Context) {
 		// Get query parameters
@@ -59,19 +68,19 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		// Pagination parameters
-		limitStr := c.DefaultQuery("limit", "20")
-		offsetStr := c.DefaultQuery("offset", "0")
-
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil || limit < 1 || limit > 100 {
-			limit = 20 // Default to 20, max 100
+		var tier string
+		if t := c.Query("tier"); t != "" {
+			normalized, err := models.NormalizeTier(t)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err.Error(),
+				})
+				return
+			}
+			tier = normalized
 		}
 
-		offset, err := strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			offset = 0
-		}
+		page := pagination.Parse(c, searchLimits)
 
 		// Get organization context
 		var orgID string
@@ -92,15 +101,24 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			orgID = org.ID
 		}
 
+		// On a custom tenant domain, search only the tenant organization's
+		// namespaces unless every namespace is asked for.
+		var ownerOrgID string
+		if c.Query("scope") != "all" {
+			ownerOrgID = middleware.TenantOrganizationID(c)
+		}
+
 		// Search modules with aggregated version stats in a single query
 		modules, total, err := moduleRepo.SearchModulesWithStats(
 			c.Request.Context(),
 			orgID,
+			ownerOrgID,
 			query,
 			namespace,
 			system,
-			limit,
-			offset,
+			tier,
+			page.Limit,
+			page.Offset,
 			sortField,
 			sortOrder,
 		)
@@ -111,6 +129,31 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Attach each namespace's short description. It is decoration only,
+		// so a failed lookup leaves it out rather than failing the search.
+		namespaces := make([]string, 0, len(modules))
+		seen := make(map[string]bool, len(modules))
+		for _, m := range modules {
+			if !seen[m.Namespace] {
+				seen[m.Namespace] = true
+				namespaces = append(namespaces, m.Namespace)
+			}
+		}
+		namespaceDescriptions, err := namespaceMetaRepo.ShortDescriptions(c.Request.Context(), namespaces)
+		if err != nil {
+			slog.Warn("failed to load namespace descriptions for search results", "error", err)
+		}
+
+		// Tiers are loaded the same way: a failed lookup leaves them out.
+		ids := make([]string, len(modules))
+		for i, m := range modules {
+			ids[i] = m.ID
+		}
+		tiers, err := moduleRepo.ListTiers(c.Request.Context(), ids)
+		if err != nil {
+			slog.Warn("failed to load module tiers for search results", "error", err)
+		}
+
 		// Format results
 		results := make([]gin.H, len(modules))
 		for i, m := range modules {
@@ -137,15 +180,29 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 				"created_at":          m.CreatedAt,
 				"updated_at":          m.UpdatedAt,
 			}
+			if desc, ok := namespaceDescriptions[m.Namespace]; ok {
+				results[i]["namespace_description"] = desc
+			}
+			if t, ok := tiers[m.ID]; ok {
+				results[i]["tier"] = t
+			}
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		resp := gin.H{
 			"modules": results,
-			"meta": gin.H{
-				"limit":  limit,
-				"offset": offset,
-				"total":  total,
-			},
-		})
+			"meta":    page.Meta(int64(total)),
+		}
+
+		// Module counts per system, ignoring the system filter so every
+		// system the search could be narrowed to is listed. Like the
+		// namespace descriptions, a failed lookup leaves them out.
+		systems, err := moduleRepo.SearchModuleSystemFacets(c.Request.Context(), orgID, ownerOrgID, query, namespace, tier)
+		if err != nil {
+			slog.Warn("failed to load system facets for search results", "error", err)
+		} else {
+			resp["facets"] = gin.H{"systems": systems}
+		}
+
+		c.JSON(http.StatusOK, resp)
 	}
 }
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/artifacthead"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
@@ -20,7 +21,7 @@ import (
 
 // ServeFileHandler serves a module or provider archive file directly from local storage.
 // @Summary      Serve archive file from local storage
-// @Description  Streams a stored archive file. Only registered when the local storage backend has ServeDirectly enabled. Path traversal sequences are rejected.
+// @Description  Streams a stored archive file. Only registered when the local storage backend has ServeDirectly enabled. Path traversal sequences are rejected. A HEAD request answers 200 with no body and the archive's Content-Length, ETag (its SHA-256), Last-Modified and Accept-Ranges headers, read from storage metadata.
 // @Tags         Files
 // @Param        filepath   path  string  true  "Storage-relative file path"
 // @Produce      application/octet-stream
@@ -29,6 +30,7 @@ import (
 // @Failure      404  {object}  map[string]interface{}  "File not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /v1/files/{filepath} [get]
+// @Router       /v1/files/{filepath} [head]
 // ServeFileHandler handles direct file serving for local storage
 // Implements: GET /v1/files/*filepath
 // Only used when local storage has ServeDirectly: true
@@ -92,6 +94,14 @@ func ServeFileHandler(storageBackend storage.Storage, cfg *config.Config, db *sq
 			}
 		}
 
+		// A HEAD is answered from metadata without opening the file, and is
+		// not counted as a download.
+		if artifacthead.IsHead(c) {
+			c.Header("Content-Type", "application/gzip")
+			artifacthead.Respond(c, storageBackend, artifacthead.Mode(cfg), filePath, "")
+			return
+		}
+
 		// Check if file exists
 		exists, err := storageBackend.Exists(c.Request.Context(), filePath)
 		if err != nil {
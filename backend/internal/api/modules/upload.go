@@ -4,30 +4,58 @@ package modules
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/analyzer"
+	"github.com/terraform-registry/terraform-registry/internal/api/remoteupload"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/notify"
 	"github.com/terraform-registry/terraform-registry/internal/policy"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/telemetry"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
 
+// moduleUploadRequest is the upload's metadata; the JSON form also names the
+// archive to download in place of a multipart file.
+type moduleUploadRequest struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	System      string `json:"system"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Source      string `json:"source"`
+	Changelog   string `json:"changelog"`
+
+	// IgnoreVersionCap publishes past the module's version cap without
+	// archiving anything. Admin scope only.
+	IgnoreVersionCap bool `json:"ignore_version_cap"`
+
+	SourceURL       string `json:"source_url"`
+	Checksum        string `json:"checksum"`
+	AuthHeaderName  string `json:"auth_header_name"`
+	AuthHeaderValue string `json:"auth_header_value"`
+}
+
 // @Summary      Upload module version
-// @Description  Uploads a new module version archive. Module identity (namespace, name, system, version) is supplied as multipart form fields, not path params. Requires modules:write scope.
+// @Description  Uploads a new module version archive. Module identity (namespace, name, system, version) is supplied as multipart form fields, not path params. Alternatively send an application/json body with the same fields plus source_url (and optional checksum, auth_header_name, auth_header_value) to have the registry download the archive; this requires remote_upload.enabled. On storage backends that stream uploads (local), the archive goes straight to storage when namespace, name, system and version are sent before the file part; it is deleted again if a later check fails. With dry_run=true the upload goes through every check a publish does (naming, archive, policy, duplicate version, version cap, pre-publish hook) without writing to the database or storage, and answers 200 with what would be published; a failed check answers as a real publish would. Requires modules:write scope.
 // @Tags         Modules
 // @Security     Bearer
 // @Accept       multipart/form-data
+// @Accept       json
 // @Produce      json
 // @Param        namespace    formData  string  true   "Module namespace"
 // @Param        name         formData  string  true   "Module name"
@@ -35,38 +63,145 @@ import (
 // @Param        version      formData  string  true   "Semantic version (e.g. 1.2.3)"
 // @Param        description  formData  string  false  "Module description"
 // @Param        source       formData  string  false  "Source URL"
+// @Param        changelog    formData  string  false  "Release notes for this version (markdown; sanitized and truncated)"
+// @Param        ignore_version_cap  formData  bool  false  "Publish past the module's version cap without archiving older versions (admin scope only)"
 // @Param        file         formData  file    true   "Module archive (tar.gz)"
+// @Param        dry_run      query     bool    false  "Validate without publishing"
+// @Success      200  {object}  ModuleUploadDryRunResponse  "dry_run=true: the upload passed every check"
 // @Success      201
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}  "ignore_version_cap requires admin scope"
 // @Failure      409  {object}  map[string]interface{}
-// @Failure      422  {object}  map[string]interface{}  "Policy violation (block mode)"
+// @Failure      413  {object}  map[string]interface{}  "Remote archive exceeds the size limit"
+// @Failure      422  {object}  map[string]interface{}  "Policy violation (block mode), checksum mismatch, version cap reached (strict mode), or rejected by the organization's pre-publish hook (reason carries the hook's reason)"
 // @Failure      500  {object}  map[string]interface{}
+// @Failure      502  {object}  map[string]interface{}  "source_url download failed (upstream_status carries the remote status), or the pre-publish hook did not answer and fails closed"
+// @Failure      503  {object}  map[string]interface{}  "Scratch space budget exceeded; retry later"
+// @Failure      504  {object}  map[string]interface{}
 // @Router       /api/v1/modules [post]
 // UploadHandler handles module upload requests
 // Implements: POST /api/v1/modules
-// Accepts multipart form with: namespace, name, system, version, description (optional), file
-func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config, scanRepo *repositories.ModuleScanRepository, moduleDocsRepo *repositories.ModuleDocsRepository, policyEngine *policy.PolicyEngine, notifier *notify.Notifier) gin.HandlerFunc {
+// Accepts multipart form with: namespace, name, system, version, description (optional), changelog (optional), file
+// or a JSON moduleUploadRequest naming a source_url to download.
+func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config, scanRepo *repositories.ModuleScanRepository, moduleDocsRepo *repositories.ModuleDocsRepository, policyEngine *policy.PolicyEngine, notifier *notify.Notifier, versionCap *services.VersionCap, immutability *services.ArtifactImmutability, publishHooks *services.PublishHooks, scratchSpace *scratch.Space) gin.HandlerFunc {
 	moduleRepo := repositories.NewModuleRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
+	approvalRepo := repositories.NewModuleApprovalRepository(db)
 	mailer := notify.New(&cfg.Notifications.SMTP)
 
+	fetcher := remoteupload.NewFetcher(cfg).WithScratch(scratchSpace)
+
 	return func(c *gin.Context) {
-		// Parse multipart form (max 100MB)
-		if err := c.Request.ParseMultipartForm(100 << 20); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Failed to parse multipart form",
+		// A JSON body publishes from a URL instead of a multipart upload.
+		var req moduleUploadRequest
+		remote := remoteupload.IsJSONRequest(c)
+		// A dry run goes through every check a publish does but writes
+		// nothing, and reports what it would have published.
+		dryRun := c.Query("dry_run") == "true"
+		// Backends that stream uploads take the archive straight from the
+		// request body instead of through a temp file. A dry run never
+		// streams, since that writes the archive before the checks.
+		streaming := !remote && !dryRun && storage.SupportsStreamingUpload(storageBackend)
+
+		var (
+			tmpFile  *os.File
+			size     int64
+			filename string
+			digest   string // SHA-256 (hex) of the archive
+
+			formReader *multipart.Reader // rest of a streamed form, read after the archive
+			formFields map[string]string
+			filePart   *multipart.Part // archive to stream once the request checks pass
+		)
+		if remote {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid request body",
+				})
+				return
+			}
+			if req.SourceURL == "" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Missing required field: source_url",
+				})
+				return
+			}
+		} else if streaming {
+			mr, err := c.Request.MultipartReader()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Failed to parse multipart form",
+				})
+				return
+			}
+			formFields = map[string]string{}
+			part, err := readUploadFields(mr, formFields, true)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Failed to parse multipart form",
+				})
+				return
+			}
+			if part != nil {
+				filename = part.FileName()
+				if formFields["namespace"] != "" && formFields["name"] != "" && formFields["system"] != "" && formFields["version"] != "" {
+					formReader, filePart = mr, part
+				} else {
+					// The archive came before the fields naming it, so it has
+					// to be held in a temp file while the rest is read.
+					var ok bool
+					if tmpFile, size, digest, ok = spoolModuleArchive(c, scratchSpace, part); !ok {
+						return
+					}
+					defer os.Remove(tmpFile.Name())
+					defer tmpFile.Close()
+					if _, err := readUploadFields(mr, formFields, false); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{
+							"error": "Failed to parse multipart form",
+						})
+						return
+					}
+				}
+			}
+			req = moduleUploadRequestFromFields(formFields)
+		} else {
+			// Parse multipart form (max 100MB)
+			if err := c.Request.ParseMultipartForm(100 << 20); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Failed to parse multipart form",
+				})
+				return
+			}
+
+			// Get form values
+			req = moduleUploadRequest{
+				Namespace:   c.PostForm("namespace"),
+				Name:        c.PostForm("name"),
+				System:      c.PostForm("system"),
+				Version:     c.PostForm("version"),
+				Description: c.PostForm("description"),
+				Source:      c.PostForm("source"),
+				Changelog:   c.PostForm("changelog"),
+
+				IgnoreVersionCap: c.PostForm("ignore_version_cap") == "true",
+			}
+		}
+
+		if req.IgnoreVersionCap && !hasAdminScope(c) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "ignore_version_cap requires admin scope",
 			})
 			return
 		}
 
-		// Get form values
-		namespace := c.PostForm("namespace")
-		name := c.PostForm("name")
-		system := c.PostForm("system")
-		version := c.PostForm("version")
-		description := c.PostForm("description")
-		source := c.PostForm("source")
+		namespace := req.Namespace
+		name := req.Name
+		system := req.System
+		version := req.Version
+		description := req.Description
+		source := req.Source
+		changelog := validation.SanitizeChangelog(req.Changelog)
 
 		// Validate required fields
 		if namespace == "" || name == "" || system == "" || version == "" {
@@ -96,49 +231,161 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
-		// Get uploaded file
-		file, header, err := c.Request.FormFile("file")
+		// The archive and its extraction for terraform-docs are each held to
+		// the archive size limit.
+		release, err := scratchSpace.Reserve(2 * validation.MaxArchiveSize)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Missing or invalid file upload",
+			c.Header("Retry-After", "60")
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": err.Error(),
 			})
 			return
 		}
-		defer file.Close()
+		defer release()
 
-		// Write uploaded file to a temp file to avoid holding up to 100MB in memory
-		tmpFile, err := os.CreateTemp("", "module-upload-*.tar.gz")
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to create temporary file",
-			})
-			return
-		}
-		defer os.Remove(tmpFile.Name())
-		defer tmpFile.Close()
+		var (
+			org          *models.Organization
+			streamed     *streamedArchive
+			keepStreamed bool // set once the streamed archive must stay in storage
+		)
+		switch {
+		case remote:
+			src := &remoteupload.Source{
+				SourceURL:       req.SourceURL,
+				Checksum:        req.Checksum,
+				AuthHeaderName:  req.AuthHeaderName,
+				AuthHeaderValue: req.AuthHeaderValue,
+			}
+			req.AuthHeaderValue = ""
+			fetched, err := fetcher.Fetch(c.Request.Context(), src, validation.MaxArchiveSize, "module-upload-*.tar.gz")
+			if err != nil {
+				remoteupload.Respond(c, err)
+				return
+			}
+			defer fetched.Cleanup()
+			tmpFile, size, filename, digest = fetched.File, fetched.Size, fetched.Filename, fetched.SHA256
+		case filePart != nil:
+			// Streaming writes the archive before the version row exists, so
+			// refuse up front rather than overwrite a published version.
+			var ok bool
+			if org, ok = loadUploadOrganization(c, orgRepo); !ok {
+				return
+			}
+			existingModule, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
+			if err == nil && existingModule != nil {
+				var existingVersion *models.ModuleVersion
+				if existingVersion, err = moduleRepo.GetVersion(c.Request.Context(), existingModule.ID, version); err == nil && existingVersion != nil {
+					c.JSON(http.StatusConflict, gin.H{
+						"error": fmt.Sprintf("Version %s already exists for this module", version),
+					})
+					return
+				}
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to check for existing version",
+				})
+				return
+			}
 
-		size, err := io.Copy(tmpFile, file)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to read uploaded file",
-			})
-			return
-		}
+			streamed, err = streamModuleArchive(c.Request.Context(), storageBackend,
+				storage.ModuleArchiveKey(namespace, name, system, version), filePart, scratchSpace.Dir())
+			if err != nil {
+				var invalid *invalidArchiveError
+				switch {
+				case errors.As(err, &invalid):
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error": fmt.Sprintf("Invalid archive: %v", invalid),
+					})
+				case errors.Is(err, storage.ErrArtifactImmutable):
+					c.JSON(http.StatusConflict, gin.H{
+						"error": err.Error(),
+					})
+				default:
+					c.JSON(http.StatusInternalServerError, gin.H{
+						"error": fmt.Sprintf("Failed to upload file: %v", err),
+					})
+				}
+				return
+			}
+			// Until the version row is committed, any failure removes the
+			// streamed archive again.
+			defer func() {
+				if keepStreamed {
+					return
+				}
+				if err := storageBackend.Delete(context.WithoutCancel(c.Request.Context()), streamed.Result.Path); err != nil {
+					slog.Error("failed to clean up streamed module archive", // #nosec G706 -- logged value is application-internal (config string, integer, or application-constructed path); not raw user-controlled request input
+						"path", streamed.Result.Path, "error", err)
+				}
+			}()
+			size, digest = streamed.Result.Size, streamed.Digest
 
-		// Validate archive format (seek back to start for reading)
-		if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to process uploaded file",
-			})
-			return
-		}
-		if err := validation.ValidateArchive(tmpFile, validation.MaxArchiveSize); err != nil {
+			// Fields after the archive can only add optional metadata; the
+			// identity fields already read keep their values.
+			if _, err := readUploadFields(formReader, formFields, false); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Failed to parse multipart form",
+				})
+				return
+			}
+			req = moduleUploadRequestFromFields(formFields)
+			if req.IgnoreVersionCap && !hasAdminScope(c) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "ignore_version_cap requires admin scope",
+				})
+				return
+			}
+			description, source, changelog = req.Description, req.Source, validation.SanitizeChangelog(req.Changelog)
+		case tmpFile != nil:
+			// Spooled while reading a streamed form.
+		case streaming:
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": fmt.Sprintf("Invalid archive: %v", err),
+				"error": "Missing or invalid file upload",
 			})
 			return
+		default:
+			// Get uploaded file
+			file, header, err := c.Request.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Missing or invalid file upload",
+				})
+				return
+			}
+			defer file.Close()
+			filename = header.Filename
+
+			var ok bool
+			if tmpFile, size, digest, ok = spoolModuleArchive(c, scratchSpace, file); !ok {
+				return
+			}
+			defer os.Remove(tmpFile.Name())
+			defer tmpFile.Close()
 		}
 
+		// Validate archive format (seek back to start for reading); a
+		// streamed archive was validated on its way to storage.
+		if tmpFile != nil {
+			if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to process uploaded file",
+				})
+				return
+			}
+			if err := validation.ValidateArchive(tmpFile, validation.MaxArchiveSize); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("Invalid archive: %v", err),
+				})
+				return
+			}
+		}
+
+		var (
+			warnings   []string
+			violations []policy.Violation // warn-mode policy violations
+		)
+
 		// Evaluate policy (after archive validation, before any DB or storage write).
 		if policyEngine != nil && policyEngine.IsEnabled() {
 			policyInput := map[string]interface{}{
@@ -152,6 +399,7 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			if err != nil {
 				slog.Warn("policy evaluation error", "error", err)
 				// Non-fatal: proceed on evaluation error to avoid breaking uploads.
+				warnings = append(warnings, fmt.Sprintf("Policy evaluation failed and was skipped: %v", err))
 			} else {
 				mode := policyEngine.Mode()
 				if !result.Allowed {
@@ -167,6 +415,8 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 					slog.Warn("policy violation (warn mode)",
 						"namespace", namespace, "name", name, "system", system, "version", version,
 						"violations", result.Violations)
+					violations = result.Violations
+					warnings = append(warnings, fmt.Sprintf("%d policy violation(s) allowed in warn mode", len(result.Violations)))
 				} else {
 					telemetry.PolicyEvaluationsTotal.WithLabelValues("allowed").Inc()
 				}
@@ -174,18 +424,11 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 		}
 
 		// Get organization context
-		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to get organization context",
-			})
-			return
-		}
 		if org == nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Default organization not found",
-			})
-			return
+			var ok bool
+			if org, ok = loadUploadOrganization(c, orgRepo); !ok {
+				return
+			}
 		}
 
 		// Atomically create-or-get the module to avoid race conditions when two
@@ -208,72 +451,245 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			}
 		}
 
-		if err := moduleRepo.UpsertModule(c.Request.Context(), module); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": fmt.Sprintf("Failed to create/get module: %v", err),
-			})
-			return
+		if dryRun {
+			// Look the module up instead of creating it; a module that does
+			// not exist yet has no versions and no cap to check.
+			existing, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to look up module",
+				})
+				return
+			}
+			if existing != nil {
+				module.ID = existing.ID
+			}
+		} else {
+			if err := moduleRepo.UpsertModule(c.Request.Context(), module); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": fmt.Sprintf("Failed to create/get module: %v", err),
+				})
+				return
+			}
+
+			// Update description/source on existing module if provided
+			if description != "" || source != "" {
+				if err := moduleRepo.UpdateModule(c.Request.Context(), module); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{
+						"error": "Failed to update module",
+					})
+					return
+				}
+			}
 		}
 
-		// Update description/source on existing module if provided
-		if description != "" || source != "" {
-			if err := moduleRepo.UpdateModule(c.Request.Context(), module); err != nil {
+		if module.ID != "" {
+			// Check for duplicate version
+			existingVersion, err := moduleRepo.GetVersion(c.Request.Context(), module.ID, version)
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Failed to update module",
+					"error": "Failed to check for existing version",
+				})
+				return
+			}
+			if existingVersion != nil {
+				// A concurrent upload of this version got there first; the
+				// archive key is now its archive.
+				keepStreamed = true
+				c.JSON(http.StatusConflict, gin.H{
+					"error": fmt.Sprintf("Version %s already exists for this module", version),
+				})
+				return
+			}
+
+			// Strict-mode modules refuse new versions once they reach their cap.
+			if err := versionCap.CheckPublish(c.Request.Context(), module.ID, req.IgnoreVersionCap); err != nil {
+				if errors.Is(err, services.ErrVersionCapExceeded) {
+					c.JSON(http.StatusUnprocessableEntity, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to check module version cap",
 				})
 				return
 			}
 		}
 
-		// Check for duplicate version
-		existingVersion, err := moduleRepo.GetVersion(c.Request.Context(), module.ID, version)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to check for existing version",
-			})
-			return
+		// The organization's pre-publish hook must approve the version before
+		// anything is stored.
+		hookSource := models.PublishHookSourceUpload
+		if remote {
+			hookSource = models.PublishHookSourceURL
 		}
-		if existingVersion != nil {
-			c.JSON(http.StatusConflict, gin.H{
-				"error": fmt.Sprintf("Version %s already exists for this module", version),
-			})
-			return
+		var publishedBy *string
+		if userID, exists := c.Get("user_id"); exists {
+			if uid, ok := userID.(string); ok {
+				publishedBy = &uid
+			}
 		}
-
-		// Generate storage path: modules/{namespace}/{name}/{system}/{version}.tar.gz
-		storagePath := fmt.Sprintf("modules/%s/%s/%s/%s.tar.gz", namespace, name, system, version)
-
-		// Seek back to start for storage upload
-		if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to process uploaded file",
-			})
+		if err := publishHooks.Check(c.Request.Context(), &services.ModuleVersionPublish{
+			OrganizationID: module.OrganizationID,
+			Source:         hookSource,
+			Namespace:      namespace,
+			Name:           name,
+			System:         system,
+			Version:        version,
+			Checksum:       digest,
+			SizeBytes:      size,
+			PublishedBy:    publishedBy,
+			DryRun:         dryRun,
+		}); err != nil {
+			var denied *services.PublishHookDeniedError
+			switch {
+			case errors.As(err, &denied):
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error":  "Module version rejected by the organization's pre-publish hook",
+					"reason": denied.Reason,
+				})
+			case errors.Is(err, services.ErrPublishHookUnavailable):
+				c.JSON(http.StatusBadGateway, gin.H{
+					"error": err.Error(),
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to check the pre-publish hook",
+				})
+			}
 			return
 		}
 
-		// Upload to storage backend
-		uploadResult, err := storageBackend.Upload(
-			c.Request.Context(),
-			storagePath,
-			tmpFile,
-			size,
+		// An archive byte-identical to an existing version of the module is
+		// reported, and stored as an alias of that version when the
+		// organization's module policy asks for it.
+		var (
+			duplicate *models.ModuleVersion
+			alias     bool
 		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": fmt.Sprintf("Failed to upload file: %v", err),
-			})
-			return
+		if module.ID != "" {
+			if duplicate, err = moduleRepo.FindVersionByChecksum(c.Request.Context(), module.ID, digest); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to check for duplicate content",
+				})
+				return
+			}
+		}
+		if duplicate != nil {
+			modulePolicy, err := approvalRepo.GetPolicy(c.Request.Context(), module.OrganizationID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to get module policy",
+				})
+				return
+			}
+			alias = modulePolicy != nil && modulePolicy.AliasDuplicateVersions
+			warnings = append(warnings, fmt.Sprintf("The archive is identical to version %s", duplicate.Version))
 		}
 
-		// Seek back to start for README extraction
-		if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
-			slog.Warn("failed to seek temp file for README extraction", "error", err)
+		var (
+			uploadResult *storage.UploadResult
+			readme       string
+			doc          *analyzer.ModuleDoc
+		)
+		if streamed != nil {
+			uploadResult, readme, doc = streamed.Result, streamed.Readme, streamed.Doc
+			if streamed.DocErr != nil {
+				slog.Warn("terraform-docs: failed to analyze archive",
+					"namespace", namespace, "name", name, "version", version, "error", streamed.DocErr)
+			}
+		} else {
+			if !dryRun && !alias {
+				storagePath := storage.ModuleArchiveKey(namespace, name, system, version)
+
+				// Seek back to start for storage upload
+				if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{
+						"error": "Failed to process uploaded file",
+					})
+					return
+				}
+
+				// Upload to storage backend
+				var err error
+				uploadResult, err = storageBackend.Upload(
+					c.Request.Context(),
+					storagePath,
+					tmpFile,
+					size,
+				)
+				if err != nil {
+					if errors.Is(err, storage.ErrArtifactImmutable) {
+						c.JSON(http.StatusConflict, gin.H{
+							"error": err.Error(),
+						})
+						return
+					}
+					c.JSON(http.StatusInternalServerError, gin.H{
+						"error": fmt.Sprintf("Failed to upload file: %v", err),
+					})
+					return
+				}
+			}
+
+			// Seek back to start for README extraction
+			if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+				slog.Warn("failed to seek temp file for README extraction", "error", err)
+			}
+
+			// Extract README from tarball
+			var err error
+			readme, err = validation.ExtractReadme(tmpFile)
+			if err != nil {
+				slog.Warn("failed to extract README from archive", "error", err)
+				warnings = append(warnings, fmt.Sprintf("Failed to extract README: %v", err))
+			} else if readme == "" {
+				warnings = append(warnings, "The archive has no README")
+			}
+
+			// Parse the module's Terraform configuration (non-fatal — a module
+			// without variables is perfectly valid). The required_version
+			// constraint is recorded on the version row; the rest is stored as
+			// terraform-docs metadata once the version exists.
+			if _, err := tmpFile.Seek(0, io.SeekStart); err == nil {
+				if doc, err = analyzer.AnalyzeArchiveIn(scratchSpace.Dir(), tmpFile); err != nil {
+					slog.Warn("terraform-docs: failed to analyze archive",
+						"namespace", namespace, "name", name, "version", version, "error", err)
+					warnings = append(warnings, fmt.Sprintf("Failed to parse the module's Terraform configuration: %v", err))
+				}
+			}
+		}
+		if alias {
+			// The version shares the existing archive; a streamed copy is
+			// deleted again on the way out.
+			uploadResult = &storage.UploadResult{Path: duplicate.StoragePath, Size: duplicate.SizeBytes, Checksum: duplicate.Checksum}
 		}
 
-		// Extract README from tarball
-		readme, err := validation.ExtractReadme(tmpFile)
-		if err != nil {
-			slog.Warn("failed to extract README from archive", "error", err)
+		if dryRun {
+			if warnings == nil {
+				warnings = []string{}
+			}
+			if violations == nil {
+				violations = []policy.Violation{}
+			}
+			c.JSON(http.StatusOK, ModuleUploadDryRunResponse{
+				DryRun:                   true,
+				Namespace:                namespace,
+				Name:                     name,
+				System:                   system,
+				Version:                  version,
+				Checksum:                 digest,
+				SizeBytes:                size,
+				Filename:                 filename,
+				ModuleExists:             module.ID != "",
+				HasReadme:                readme != "",
+				RequiredTerraformVersion: doc.RequiredTerraformVersion(),
+				DuplicateOf:              duplicateVersion(duplicate),
+				Alias:                    alias,
+				Warnings:                 warnings,
+				Violations:               violations,
+			})
+			return
 		}
 
 		// Create version record
@@ -285,6 +701,10 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			SizeBytes:      uploadResult.Size,
 			Checksum:       uploadResult.Checksum,
 		}
+		if alias {
+			moduleVersion.StorageBackend = duplicate.StorageBackend
+			moduleVersion.AliasOf = &duplicate.ID
+		}
 		// Set published_by for audit tracking
 		if userID, exists := c.Get("user_id"); exists {
 			if uid, ok := userID.(string); ok {
@@ -296,12 +716,17 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 		if readme != "" {
 			moduleVersion.Readme = &readme
 		}
+		moduleVersion.RequiredTerraformVersion = doc.RequiredTerraformVersion()
 
 		if err := moduleRepo.CreateVersion(c.Request.Context(), moduleVersion); err != nil {
-			// Try to clean up the orphaned storage artifact
-			if delErr := storageBackend.Delete(c.Request.Context(), uploadResult.Path); delErr != nil {
-				slog.Error("failed to clean up orphaned storage artifact", // #nosec G706 -- logged value is application-internal (config string, integer, or application-constructed path); not raw user-controlled request input
-					"path", uploadResult.Path, "error", delErr)
+			// Try to clean up the orphaned storage artifact; an alias's
+			// archive belongs to the version it points at.
+			if !alias {
+				keepStreamed = true
+				if delErr := storageBackend.Delete(c.Request.Context(), uploadResult.Path); delErr != nil {
+					slog.Error("failed to clean up orphaned storage artifact", // #nosec G706 -- logged value is application-internal (config string, integer, or application-constructed path); not raw user-controlled request input
+						"path", uploadResult.Path, "error", delErr)
+				}
 			}
 
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -309,6 +734,30 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			})
 			return
 		}
+		keepStreamed = !alias
+
+		// Rolling-mode modules archive their oldest versions (non-fatal).
+		versionCap.AfterPublish(c.Request.Context(), moduleVersion, moduleVersion.PublishedBy, req.IgnoreVersionCap)
+
+		// Append to the publish log when the module's organization keeps one.
+		immutability.RecordPublish(c.Request.Context(), &models.PublishLogEntry{
+			ArtifactType:   models.PublishLogArtifactModule,
+			OrganizationID: &module.OrganizationID,
+			Namespace:      namespace,
+			Name:           name,
+			Target:         system,
+			Version:        version,
+			StorageKey:     moduleVersion.StoragePath,
+			Checksum:       moduleVersion.Checksum,
+			PublishedBy:    moduleVersion.PublishedBy,
+		})
+
+		// Store caller-supplied release notes (non-fatal).
+		if changelog != "" {
+			if err := moduleRepo.SetVersionChangelog(c.Request.Context(), moduleVersion.ID, changelog); err != nil {
+				slog.Warn("failed to store changelog", "version_id", moduleVersion.ID, "error", err)
+			}
+		}
 
 		notifyModulePublished(mailer, notifier, cfg, namespace, name, system, version)
 
@@ -320,24 +769,15 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			}
 		}
 
-		// Extract terraform-docs metadata from the archive (non-fatal — a module
-		// without variables is perfectly valid).
-		if moduleDocsRepo != nil {
-			if _, err := tmpFile.Seek(0, io.SeekStart); err == nil {
-				doc, err := analyzer.AnalyzeArchive(tmpFile)
-				if err != nil {
-					slog.Warn("terraform-docs: failed to analyze archive",
-						"namespace", namespace, "name", name, "version", version, "error", err)
-				} else if doc != nil {
-					if err := moduleDocsRepo.UpsertModuleDocs(c.Request.Context(), moduleVersion.ID, doc); err != nil {
-						slog.Warn("terraform-docs: failed to store docs",
-							"version_id", moduleVersion.ID, "error", err)
-					} else {
-						slog.Debug("terraform-docs: stored",
-							"version_id", moduleVersion.ID,
-							"inputs", len(doc.Inputs), "outputs", len(doc.Outputs))
-					}
-				}
+		// Store the terraform-docs metadata extracted above (non-fatal).
+		if moduleDocsRepo != nil && doc != nil {
+			if err := moduleDocsRepo.UpsertModuleDocs(c.Request.Context(), moduleVersion.ID, doc); err != nil {
+				slog.Warn("terraform-docs: failed to store docs",
+					"version_id", moduleVersion.ID, "error", err)
+			} else {
+				slog.Debug("terraform-docs: stored",
+					"version_id", moduleVersion.ID,
+					"inputs", len(doc.Inputs), "outputs", len(doc.Outputs))
 			}
 		}
 
@@ -345,7 +785,7 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 		telemetry.ModulePublishesTotal.WithLabelValues(namespace, system).Inc()
 
 		// Return success response with module metadata
-		c.JSON(http.StatusCreated, gin.H{
+		resp := gin.H{
 			"id":         module.ID,
 			"namespace":  module.Namespace,
 			"name":       module.Name,
@@ -353,10 +793,49 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			"version":    moduleVersion.Version,
 			"checksum":   moduleVersion.Checksum,
 			"size_bytes": moduleVersion.SizeBytes,
-			"filename":   header.Filename,
+			"filename":   filename,
 			"created_at": moduleVersion.CreatedAt,
+		}
+		if duplicate != nil {
+			resp["duplicate_of"] = duplicate.Version
+			resp["alias"] = alias
+		}
+		c.JSON(http.StatusCreated, resp)
+	}
+}
+
+// duplicateVersion is the version string of v, or "" when v is nil.
+func duplicateVersion(v *models.ModuleVersion) string {
+	if v == nil {
+		return ""
+	}
+	return v.Version
+}
+
+// hasAdminScope reports whether the caller holds the admin scope.
+func hasAdminScope(c *gin.Context) bool {
+	scopesVal, _ := c.Get("scopes")
+	scopes, _ := scopesVal.([]string)
+	return auth.HasScope(scopes, auth.ScopeAdmin)
+}
+
+// loadUploadOrganization returns the default organization uploads publish
+// into, writing the error response and returning ok == false when it cannot.
+func loadUploadOrganization(c *gin.Context, orgRepo *repositories.OrganizationRepository) (*models.Organization, bool) {
+	org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get organization context",
+		})
+		return nil, false
+	}
+	if org == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Default organization not found",
 		})
+		return nil, false
 	}
+	return org, true
 }
 
 // notifyModulePublished emails the configured admin recipients and fans out to
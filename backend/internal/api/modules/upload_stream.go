@@ -0,0 +1,218 @@
+// upload_stream.go streams multipart module uploads straight to storage backends that support it.
+package modules
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/analyzer"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// maxUploadFieldSize caps each form field read from a streamed upload. The
+// largest field, the changelog, is truncated well below this anyway.
+const maxUploadFieldSize = 1 << 20
+
+var (
+	errUploadFieldTooLarge = fmt.Errorf("form field exceeds %d bytes", maxUploadFieldSize)
+	errArchiveTooLarge     = fmt.Errorf("archive exceeds maximum size of %d bytes", validation.MaxArchiveSize)
+
+	// errArchiveRejected aborts a streaming upload once archive validation
+	// has failed; the validation error itself is reported to the caller.
+	errArchiveRejected = errors.New("archive rejected")
+)
+
+// readUploadFields reads form fields from mr into fields, keeping the first
+// value of each, until the end of the form. With wantFile it stops at the
+// "file" part and returns it; otherwise file parts are skipped. A nil part
+// means the form had no file left to read.
+func readUploadFields(mr *multipart.Reader, fields map[string]string, wantFile bool) (*multipart.Part, error) {
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FileName() != "" {
+			if wantFile && part.FormName() == "file" {
+				return part, nil
+			}
+			continue
+		}
+		value, err := io.ReadAll(io.LimitReader(part, maxUploadFieldSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(value) > maxUploadFieldSize {
+			return nil, errUploadFieldTooLarge
+		}
+		if _, seen := fields[part.FormName()]; !seen {
+			fields[part.FormName()] = string(value)
+		}
+	}
+}
+
+// moduleUploadRequestFromFields builds the upload metadata from streamed form fields.
+func moduleUploadRequestFromFields(fields map[string]string) moduleUploadRequest {
+	return moduleUploadRequest{
+		Namespace:   fields["namespace"],
+		Name:        fields["name"],
+		System:      fields["system"],
+		Version:     fields["version"],
+		Description: fields["description"],
+		Source:      fields["source"],
+		Changelog:   fields["changelog"],
+
+		IgnoreVersionCap: fields["ignore_version_cap"] == "true",
+	}
+}
+
+// spoolModuleArchive copies an uploaded archive into a scratch temp file,
+// hashing it on the way. On failure it writes the error response and
+// returns ok == false; on success the caller owns the file.
+func spoolModuleArchive(c *gin.Context, scratchSpace *scratch.Space, file io.Reader) (tmpFile *os.File, size int64, digest string, ok bool) {
+	// Write uploaded file to a temp file to avoid holding up to 100MB in memory
+	tmpFile, err := scratchSpace.CreateTemp("module-upload-*.tar.gz")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create temporary file",
+		})
+		return nil, 0, "", false
+	}
+	discard := func() {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+	}
+
+	hasher := sha256.New()
+	size, err = io.Copy(io.MultiWriter(tmpFile, hasher), io.LimitReader(file, validation.MaxArchiveSize+1))
+	if err != nil {
+		discard()
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to read uploaded file",
+		})
+		return nil, 0, "", false
+	}
+	if size > validation.MaxArchiveSize {
+		discard()
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Invalid archive: %v", errArchiveTooLarge),
+		})
+		return nil, 0, "", false
+	}
+	return tmpFile, size, hex.EncodeToString(hasher.Sum(nil)), true
+}
+
+// streamedArchive is a module archive uploaded straight from the request
+// body, with everything the temp-file path would read back from disk.
+type streamedArchive struct {
+	Result *storage.UploadResult
+	Digest string // SHA-256 (hex) of the archive
+	Readme string
+	Doc    *analyzer.ModuleDoc
+	DocErr error // terraform-docs analysis failure; non-fatal
+}
+
+// invalidArchiveError reports a streamed archive that failed validation.
+type invalidArchiveError struct {
+	err error
+}
+
+func (e *invalidArchiveError) Error() string { return e.err.Error() }
+func (e *invalidArchiveError) Unwrap() error { return e.err }
+
+// streamModuleArchive uploads archive to key on backend, which must support
+// streaming uploads, while hashing it, validating it (including the
+// decompression limits), extracting its README and analyzing its Terraform
+// configuration in the same pass. Validation failures abort the upload and
+// are returned as *invalidArchiveError. Nothing is left in storage when an
+// error is returned.
+func streamModuleArchive(ctx context.Context, backend storage.Storage, key string, archive io.Reader, docsDir string) (*streamedArchive, error) {
+	inspectR, inspectW := io.Pipe()
+	docsR, docsW := io.Pipe()
+
+	var (
+		wg         sync.WaitGroup
+		out        streamedArchive
+		inspectErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		out.Readme, inspectErr = validation.InspectArchive(inspectR, validation.MaxArchiveSize)
+		if inspectErr != nil {
+			inspectR.CloseWithError(errArchiveRejected)
+			return
+		}
+		// Drain the gzip trailer and tar padding.
+		_, _ = io.Copy(io.Discard, inspectR)
+	}()
+	go func() {
+		defer wg.Done()
+		out.Doc, out.DocErr = analyzer.AnalyzeArchiveStreamIn(docsDir, docsR)
+		// Analysis is best effort; keep the upload moving whatever happened.
+		_, _ = io.Copy(io.Discard, docsR)
+	}()
+
+	hasher := sha256.New()
+	body := io.TeeReader(&cappedArchiveReader{r: archive, n: validation.MaxArchiveSize}, io.MultiWriter(hasher, inspectW, docsW))
+	result, err := backend.Upload(ctx, key, body, -1)
+	inspectW.CloseWithError(err)
+	docsW.CloseWithError(err)
+	wg.Wait()
+
+	switch {
+	case errors.Is(err, errArchiveTooLarge):
+		return nil, &invalidArchiveError{err: errArchiveTooLarge}
+	case errors.Is(err, errArchiveRejected):
+		return nil, &invalidArchiveError{err: inspectErr}
+	case err != nil:
+		return nil, err
+	case inspectErr != nil:
+		// The archive was fully stored before validation finished with it.
+		if delErr := backend.Delete(context.WithoutCancel(ctx), result.Path); delErr != nil {
+			slog.Error("failed to delete rejected module archive", "path", result.Path, "error", delErr)
+		}
+		return nil, &invalidArchiveError{err: inspectErr}
+	}
+
+	out.Result = result
+	out.Digest = hex.EncodeToString(hasher.Sum(nil))
+	return &out, nil
+}
+
+// cappedArchiveReader fails with errArchiveTooLarge once more than n bytes
+// have been read from r.
+type cappedArchiveReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *cappedArchiveReader) Read(p []byte) (int, error) {
+	if r.n < 0 {
+		return 0, errArchiveTooLarge
+	}
+	if int64(len(p)) > r.n+1 {
+		p = p[:r.n+1]
+	}
+	n, err := r.r.Read(p)
+	r.n -= int64(n)
+	if r.n < 0 {
+		return n, errArchiveTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,314 @@
+package modules
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// consumingStore is a mockStore whose Upload reads the whole archive, as a
+// real backend would, and tracks which objects are left in storage. With
+// streams set it advertises streaming uploads.
+type consumingStore struct {
+	mockStore
+	streams bool
+
+	mu      sync.Mutex
+	uploads int
+	live    map[string]bool
+}
+
+func (s *consumingStore) StreamsUploads() bool { return s.streams }
+
+func (s *consumingStore) Upload(_ context.Context, path string, reader io.Reader, _ int64) (*storage.UploadResult, error) {
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, reader)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads++
+	if s.live == nil {
+		s.live = map[string]bool{}
+	}
+	s.live[path] = true
+	return &storage.UploadResult{Path: path, Size: n, Checksum: hex.EncodeToString(hasher.Sum(nil))}, nil
+}
+
+func (s *consumingStore) Delete(_ context.Context, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.live, path)
+	return nil
+}
+
+func (s *consumingStore) stored() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.live)
+}
+
+// uploadPart is one part of a hand-ordered multipart upload; a nil value
+// marks the archive.
+type uploadPart struct {
+	name  string
+	value *string
+}
+
+func field(name, value string) uploadPart { return uploadPart{name: name, value: &value} }
+
+// buildOrderedModuleUploadRequest is buildModuleUploadRequest with the parts
+// written in the given order.
+func buildOrderedModuleUploadRequest(t testing.TB, parts []uploadPart, fileData []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, p := range parts {
+		if p.value == nil {
+			fw, _ := mw.CreateFormFile("file", "module.tar.gz")
+			_, _ = fw.Write(fileData)
+			continue
+		}
+		_ = mw.WriteField(p.name, *p.value)
+	}
+	mw.Close()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/modules", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+var streamedIdentity = []uploadPart{
+	field("namespace", "hashicorp"),
+	field("name", "consul"),
+	field("system", "aws"),
+	field("version", "1.0.0"),
+}
+
+func TestUploadHandler_Streaming_Success(t *testing.T) {
+	store := &consumingStore{streams: true}
+	mock, r := newModuleUploadRouter(t, store)
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	// Pre-check before streaming: module not yet registered
+	mock.ExpectQuery("SELECT.*FROM modules m").WillReturnRows(sqlmock.NewRows(moduleCols2))
+	mock.ExpectQuery("INSERT INTO modules").WillReturnRows(
+		sqlmock.NewRows(moduleInsertCols2).AddRow("mod-new", time.Now(), time.Now()),
+	)
+	// The description arrives after the archive and still updates the module
+	mock.ExpectQuery("UPDATE modules").WillReturnRows(sqlmock.NewRows(moduleUpdateCols2).AddRow(time.Now()))
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
+	expectNoDuplicateContent(mock)
+	mock.ExpectQuery("INSERT INTO module_versions").WillReturnRows(
+		sqlmock.NewRows(moduleVersionInsertCols2).AddRow("ver-new", time.Now()),
+	)
+
+	archive := makeValidModuleTarGz(t)
+	parts := append(append([]uploadPart{}, streamedIdentity...), uploadPart{name: "file"}, field("description", "A test module"))
+	w := doPOSTReq(r, buildOrderedModuleUploadRequest(t, parts, archive))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Checksum string `json:"checksum"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if sum := sha256.Sum256(archive); resp.Checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("checksum = %q, want the archive's SHA-256", resp.Checksum)
+	}
+	if store.stored() != 1 {
+		t.Errorf("stored objects = %d, want 1", store.stored())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+func TestUploadHandler_Streaming_InvalidArchiveLeavesNothing(t *testing.T) {
+	var traversal bytes.Buffer
+	gzw := gzip.NewWriter(&traversal)
+	tw := tar.NewWriter(gzw)
+	_ = tw.WriteHeader(&tar.Header{Name: "../escape.tf", Size: 1, Mode: 0644, Typeflag: tar.TypeReg})
+	_, _ = tw.Write([]byte("x"))
+	tw.Close()
+	gzw.Close()
+
+	for name, archive := range map[string][]byte{
+		"not gzip":       []byte("not-a-tar-gz"),
+		"path traversal": traversal.Bytes(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := &consumingStore{streams: true}
+			mock, r := newModuleUploadRouter(t, store)
+			mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+			mock.ExpectQuery("SELECT.*FROM modules m").WillReturnRows(sqlmock.NewRows(moduleCols2))
+
+			parts := append(append([]uploadPart{}, streamedIdentity...), uploadPart{name: "file"})
+			w := doPOSTReq(r, buildOrderedModuleUploadRequest(t, parts, archive))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400; body: %s", w.Code, w.Body.String())
+			}
+			if store.stored() != 0 {
+				t.Errorf("stored objects = %d, want 0", store.stored())
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUploadHandler_Streaming_ExistingVersionNotOverwritten(t *testing.T) {
+	store := &consumingStore{streams: true}
+	mock, r := newModuleUploadRouter(t, store)
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules m").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WillReturnRows(sampleModuleVersionGetRow())
+
+	parts := append(append([]uploadPart{}, streamedIdentity...), uploadPart{name: "file"})
+	w := doPOSTReq(r, buildOrderedModuleUploadRequest(t, parts, makeValidModuleTarGz(t)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409; body: %s", w.Code, w.Body.String())
+	}
+	if store.uploads != 0 {
+		t.Errorf("uploads = %d, want 0", store.uploads)
+	}
+}
+
+func TestUploadHandler_Streaming_RejectedAfterUploadIsDeleted(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	store := &consumingStore{streams: true}
+	versionCap := services.NewVersionCap(repositories.NewModuleRepository(db), nil)
+	r := gin.New()
+	r.POST("/api/v1/modules", UploadHandler(db, store, &config.Config{}, nil, nil, nil, nil, versionCap, nil, nil, nil))
+
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules m").WillReturnRows(sqlmock.NewRows(moduleCols2))
+	mock.ExpectQuery("INSERT INTO modules").WillReturnRows(
+		sqlmock.NewRows(moduleInsertCols2).AddRow("mod-1", time.Now(), time.Now()),
+	)
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
+	mock.ExpectQuery("SELECT COALESCE").WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"max_versions", "mode", "active"}).AddRow(3, "strict", 3))
+
+	parts := append(append([]uploadPart{}, streamedIdentity...), uploadPart{name: "file"})
+	w := doPOSTReq(r, buildOrderedModuleUploadRequest(t, parts, makeValidModuleTarGz(t)))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422; body: %s", w.Code, w.Body.String())
+	}
+	if store.uploads != 1 || store.stored() != 0 {
+		t.Errorf("uploads = %d, stored = %d; want the streamed archive deleted again", store.uploads, store.stored())
+	}
+}
+
+func TestUploadHandler_Streaming_FileBeforeFieldsFallsBack(t *testing.T) {
+	store := &consumingStore{streams: true}
+	mock, r := newModuleUploadRouter(t, store)
+
+	// The temp-file path: nothing is checked before the archive is validated
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("INSERT INTO modules").WillReturnRows(
+		sqlmock.NewRows(moduleInsertCols2).AddRow("mod-1", time.Now(), time.Now()),
+	)
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+		WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
+	expectNoDuplicateContent(mock)
+	mock.ExpectQuery("INSERT INTO module_versions").WillReturnRows(
+		sqlmock.NewRows(moduleVersionInsertCols2).AddRow("ver-new", time.Now()),
+	)
+
+	parts := append([]uploadPart{{name: "file"}}, streamedIdentity...)
+	w := doPOSTReq(r, buildOrderedModuleUploadRequest(t, parts, makeValidModuleTarGz(t)))
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+// BenchmarkModuleUpload compares the temp-file and streaming upload paths on
+// a ~100 MB archive of incompressible data.
+func BenchmarkModuleUpload(b *testing.B) {
+	payload := make([]byte, 95<<20)
+	_, _ = rand.Read(payload)
+	var archive bytes.Buffer
+	gzw, _ := gzip.NewWriterLevel(&archive, gzip.NoCompression)
+	tw := tar.NewWriter(gzw)
+	mainTF := []byte(`variable "name" {}`)
+	_ = tw.WriteHeader(&tar.Header{Name: "main.tf", Size: int64(len(mainTF)), Mode: 0644, Typeflag: tar.TypeReg})
+	_, _ = tw.Write(mainTF)
+	_ = tw.WriteHeader(&tar.Header{Name: "files/blob.bin", Size: int64(len(payload)), Mode: 0644, Typeflag: tar.TypeReg})
+	_, _ = tw.Write(payload)
+	tw.Close()
+	gzw.Close()
+
+	parts := append(append([]uploadPart{}, streamedIdentity...), uploadPart{name: "file"})
+	body := buildOrderedModuleUploadRequest(b, parts, archive.Bytes())
+	contentType := body.Header.Get("Content-Type")
+	form, _ := io.ReadAll(body.Body)
+
+	for _, bc := range []struct {
+		name    string
+		streams bool
+	}{{"temp_file", false}, {"streaming", true}} {
+		streams := bc.streams
+		b.Run(bc.name, func(b *testing.B) {
+			db, mock, _ := sqlmock.New()
+			b.Cleanup(func() { db.Close() })
+			r := gin.New()
+			r.POST("/api/v1/modules", UploadHandler(db, &consumingStore{streams: streams}, &config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil))
+
+			b.SetBytes(int64(archive.Len()))
+			b.ReportAllocs()
+			// The default organization is cached after the first upload.
+			mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow2())
+			for b.Loop() {
+				if streams {
+					mock.ExpectQuery("SELECT.*FROM modules m").WillReturnRows(sqlmock.NewRows(moduleCols2))
+				}
+				mock.ExpectQuery("INSERT INTO modules").WillReturnRows(
+					sqlmock.NewRows(moduleInsertCols2).AddRow("mod-1", time.Now(), time.Now()),
+				)
+				mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE module_id.*AND version").
+					WillReturnRows(sqlmock.NewRows(moduleVersionGetCols2))
+				expectNoDuplicateContent(mock)
+				mock.ExpectQuery("INSERT INTO module_versions").WillReturnRows(
+					sqlmock.NewRows(moduleVersionInsertCols2).AddRow("ver-1", time.Now()),
+				)
+
+				req, _ := http.NewRequest(http.MethodPost, "/api/v1/modules", bytes.NewReader(form))
+				req.Header.Set("Content-Type", contentType)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code != http.StatusCreated {
+					b.Fatalf("status = %d; body: %s", w.Code, w.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -2,39 +2,53 @@
 package modules
 
 import (
+	"context"
 	"database/sql"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/negotiate"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/db/transient"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
 
 // @Summary      List module versions
-// @Description  List all available versions for a specific module. Implements the Terraform Module Registry Protocol.
+// @Description  List all available versions for a specific module. Authenticated callers whose organization enforces an approved_only module policy see only versions approved by that organization. Pre-release versions (e.g. 1.4.0-rc.1) are omitted from the protocol document unless include_prerelease=true is passed or the module has include_prerelease enabled; the extended document always lists them, flagged with prerelease=true. Implements the Terraform Module Registry Protocol. The protocol document is returned by default (and for `Accept: application/json`); sending `Accept: application/vnd.tfr.v1+json` returns the extended document (modules.ModuleVersionsExtendedResponse) with the module's tier, publisher, size, checksum, SCM, required Terraform version, and deprecation metadata instead. When protocol.filter_by_terraform_version is enabled, the protocol document omits versions whose required_version excludes the X-Terraform-Version request header.
 // @Tags         Modules
 // @Produce      json
+// @Produce      application/vnd.tfr.v1+json
 // @Param        namespace  path  string  true  "Module namespace"
 // @Param        name       path  string  true  "Module name"
 // @Param        system     path  string  true  "Target system (e.g. aws, azurerm)"
 // @Param        limit      query int     false "Maximum results (default 100, max 1000)"
 // @Param        offset     query int     false "Offset for pagination (default 0)"
+// @Param        include_prerelease  query  bool  false  "Include pre-release versions in the protocol document (default false)"
+// @Param        Accept     header string  false "application/json (default) or application/vnd.tfr.v1+json for the extended document"
+// @Param        X-Terraform-Version  header  string  false  "Terraform CLI version (sent by terraform); used to hide incompatible versions when filtering is enabled"
 // @Success      200  {object}  modules.ModuleVersionsResponse
+// @Success      301  "Module moved; Location is the same request at its current address"
 // @Failure      404  {object}  map[string]interface{}  "Module not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      503  {object}  map[string]interface{}  "Database temporarily unavailable (Retry-After set)"
 // @Router       /v1/modules/{namespace}/{name}/{system}/versions [get]
 // ListVersionsHandler handles listing all versions of a module
 // Implements: GET /v1/modules/:namespace/:name/:system/versions
 func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 	moduleRepo := repositories.NewModuleRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
+	approvalRepo := repositories.NewModuleApprovalRepository(db)
 
 	return func(c *gin.Context) {
 		namespace := c.Param("namespace")
 		name := c.Param("name")
 		system := c.Param("system")
+		negotiate.SetVary(c)
 
 		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
 		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
@@ -49,11 +63,9 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Get organization context (default org for single-tenant mode)
-		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+		org, err := transient.Value(c.Request.Context(), orgRepo.GetDefaultOrganization)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to get organization context",
-			})
+			respondQueryError(c, err, "Failed to get organization context")
 			return
 		}
 		if org == nil {
@@ -64,26 +76,98 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Get module
-		module, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
+		module, err := transient.Value(c.Request.Context(), func(ctx context.Context) (*models.Module, error) {
+			return moduleRepo.GetModule(ctx, org.ID, namespace, name, system)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to query module",
-			})
+			respondQueryError(c, err, "Failed to query module")
 			return
 		}
 
 		if module == nil {
+			if redirectMovedModule(c, moduleRepo, org.ID, namespace, name, system) {
+				return
+			}
 			c.JSON(http.StatusNotFound, gin.H{
 				"errors": []string{"Module not found"},
 			})
 			return
 		}
 
+		// Callers whose organization enforces approved_only see only the
+		// versions one of those organizations has approved.
+		enforcing, err := transient.Value(c.Request.Context(), func(context.Context) ([]string, error) {
+			return enforcingOrganizationIDs(c, approvalRepo)
+		})
+		if err != nil {
+			respondQueryError(c, err, "Failed to resolve organization module policy")
+			return
+		}
+
+		// Pre-releases are opt-in: a loose constraint such as "~> 1.4" would
+		// otherwise resolve to 1.4.0-rc.1 before 1.4.0 ships. The repository
+		// hides them, unless the module opted in, before paginating. The
+		// extended document lists them all, flagged.
+		extended := negotiate.WantsExtended(c)
+		hidePrereleases := false
+		if !extended {
+			includePre, _ := strconv.ParseBool(c.Query("include_prerelease"))
+			hidePrereleases = !includePre
+		}
+
+		// Don't offer versions the calling terraform cannot run. Constraints
+		// are evaluated in Go, so every version is loaded and the page is cut
+		// from the filtered list.
+		var tfVersion string
+		if cfg.Protocol.FilterByTerraformVersion && !extended {
+			if v := c.GetHeader(terraformVersionHeader); v != "" && validation.ValidateSemver(v) == nil {
+				tfVersion = v
+			}
+		}
+		queryLimit, queryOffset := limit, offset
+		if tfVersion != "" {
+			queryLimit, queryOffset = 0, 0
+		}
+
 		// Get all versions for the module with pagination
-		versions, total, err := moduleRepo.ListVersionsPaginated(c.Request.Context(), module.ID, limit, offset)
+		var versions []*models.ModuleVersion
+		var total int
+		err = transient.Do(c.Request.Context(), func(ctx context.Context) error {
+			var err error
+			if len(enforcing) > 0 {
+				versions, total, err = moduleRepo.ListApprovedVersionsPaginated(ctx, module.ID, enforcing, hidePrereleases, queryLimit, queryOffset)
+			} else {
+				versions, total, err = moduleRepo.ListVersionsPaginated(ctx, module.ID, hidePrereleases, queryLimit, queryOffset)
+			}
+			return err
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to list module versions",
+			respondQueryError(c, err, "Failed to list module versions")
+			return
+		}
+		if tfVersion != "" {
+			versions = filterByTerraformVersion(versions, tfVersion)
+			total = len(versions)
+			versions = versions[min(offset, total):min(offset+limit, total)]
+		}
+
+		if extended {
+			// The tier is decoration; a failed lookup leaves it out.
+			tiers, err := moduleRepo.ListTiers(c.Request.Context(), []string{module.ID})
+			if err != nil {
+				slog.Warn("failed to load module tier", "module_id", module.ID, "error", err)
+			}
+			c.Header("Content-Type", negotiate.ExtendedMediaType)
+			c.JSON(http.StatusOK, ModuleVersionsExtendedResponse{
+				Namespace: module.Namespace,
+				Name:      module.Name,
+				System:    module.System,
+				Source:    module.Source,
+				Tier:      tiers[module.ID],
+				Versions:  extendedModuleVersions(versions),
+				Total:     total,
+				Limit:     limit,
+				Offset:    offset,
 			})
 			return
 		}
@@ -91,7 +175,11 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 		// Format response per Terraform Module Registry Protocol spec
 		// https://www.terraform.io/docs/internals/module-registry-protocol.html
 		versionsList := make([]map[string]interface{}, len(versions))
+		var warnings []string
 		for i, v := range versions {
+			if w := v.DeprecationWarning(); w != "" {
+				warnings = append(warnings, w)
+			}
 			versionData := map[string]interface{}{
 				"id":             v.ID,
 				"version":        v.Version,
@@ -155,7 +243,64 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			"limit":  limit,
 			"offset": offset,
 		}
+		if cfg.Protocol.DeprecationWarnings && len(warnings) > 0 {
+			response["warnings"] = warnings
+		}
 
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+// extendedModuleVersions maps version rows to the extended document entries.
+func extendedModuleVersions(versions []*models.ModuleVersion) []ModuleVersionExtended {
+	out := make([]ModuleVersionExtended, 0, len(versions))
+	for _, v := range versions {
+		entry := ModuleVersionExtended{
+			ID:                       v.ID,
+			Version:                  v.Version,
+			PublishedAt:              v.CreatedAt.UTC(),
+			DownloadCount:            v.DownloadCount,
+			SizeBytes:                v.SizeBytes,
+			Checksum:                 v.Checksum,
+			CommitSHA:                v.CommitSHA,
+			TagName:                  v.TagName,
+			HasDocs:                  v.HasDocs,
+			Deprecated:               v.Deprecated,
+			Prerelease:               validation.IsPrerelease(v.Version),
+			RequiredTerraformVersion: v.RequiredTerraformVersion,
+		}
+		if v.PublishedBy != nil {
+			entry.PublishedBy = &VersionPublisher{ID: *v.PublishedBy, Name: v.PublishedByName}
+		}
+		if v.Deprecated {
+			entry.Deprecation = &VersionDeprecation{
+				DeprecatedAt:      v.DeprecatedAt,
+				Message:           v.DeprecationMessage,
+				ReplacementSource: v.ReplacementSource,
+			}
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// terraformVersionHeader carries the terraform CLI version on registry
+// protocol requests.
+const terraformVersionHeader = "X-Terraform-Version"
+
+// filterByTerraformVersion drops versions whose required_version constraint
+// excludes tfVersion, a valid semver. Versions without a recorded constraint
+// are kept.
+func filterByTerraformVersion(versions []*models.ModuleVersion, tfVersion string) []*models.ModuleVersion {
+	kept := make([]*models.ModuleVersion, 0, len(versions))
+	for _, v := range versions {
+		if v.RequiredTerraformVersion != nil {
+			ok, err := validation.SatisfiesConstraint(tfVersion, *v.RequiredTerraformVersion)
+			if err == nil && !ok {
+				continue
+			}
+		}
+		kept = append(kept, v)
+	}
+	return kept
+}
@@ -0,0 +1,473 @@
+package modules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+// Contract tests for GET /v1/modules/:namespace/:name/:system/versions.
+//
+// The protocol document is what `terraform init` consumes; these snapshots pin
+// it byte-for-byte so changes made for the extended
+// (application/vnd.tfr.v1+json) document can never leak into it.
+
+var contractPublishedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func expectContractVersionQueries(mock sqlmock.Sqlmock) {
+	deprecatedAt := contractPublishedAt.Add(24 * time.Hour)
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+	mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+	mock.ExpectQuery("SELECT COUNT.*FROM module_versions WHERE module_id").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE mv.module_id").
+		WillReturnRows(sqlmock.NewRows(moduleVersionListCols2).
+			AddRow("ver-2", "mod-1", "2.0.0", "modules/hashicorp/consul/aws/2.0.0.tgz", "local",
+				2048, "def456", nil, "user-1", "Alice", int64(7), false, nil, nil, nil, contractPublishedAt,
+				"0123abcd", "v2.0.0", nil, nil, true).
+			AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tgz", "local",
+				1024, "abc123", nil, nil, nil, int64(5), true, deprecatedAt, "use 2.x", "hashicorp/consul/aws", contractPublishedAt,
+				nil, nil, nil, nil, false))
+}
+
+func doVersionsGET(r *gin.Engine, accept string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/modules/hashicorp/consul/aws/versions", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+const moduleVersionsProtocolSnapshot = `{
+  "limit": 100,
+  "modules": [
+    {
+      "source": "hashicorp/consul/aws",
+      "versions": [
+        {
+          "deprecated": false,
+          "download_count": 7,
+          "has_docs": true,
+          "id": "ver-2",
+          "published_at": "2024-05-01T12:00:00Z",
+          "published_by": "user-1",
+          "published_by_name": "Alice",
+          "version": "2.0.0"
+        },
+        {
+          "deprecated": true,
+          "deprecated_at": "2024-05-02T12:00:00Z",
+          "deprecation": {
+            "link": "hashicorp/consul/aws",
+            "reason": "use 2.x"
+          },
+          "deprecation_message": "use 2.x",
+          "download_count": 5,
+          "has_docs": false,
+          "id": "ver-1",
+          "published_at": "2024-05-01T12:00:00Z",
+          "replacement_source": "hashicorp/consul/aws",
+          "version": "1.0.0"
+        }
+      ]
+    }
+  ],
+  "offset": 0,
+  "total": 2
+}`
+
+func TestListVersionsContract_ProtocolSnapshot(t *testing.T) {
+	for _, accept := range []string{"", "application/json", "*/*", "application/json, application/vnd.tfr.v1+json;q=0.1"} {
+		t.Run("accept="+accept, func(t *testing.T) {
+			mock, r := newVersionsRouter(t)
+			expectContractVersionQueries(mock)
+
+			w := doVersionsGET(r, accept)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			var pretty bytes.Buffer
+			if err := json.Indent(&pretty, w.Body.Bytes(), "", "  "); err != nil {
+				t.Fatalf("indent: %v", err)
+			}
+			if pretty.String() != moduleVersionsProtocolSnapshot {
+				t.Errorf("protocol document changed.\ngot:\n%s\nwant:\n%s", pretty.String(), moduleVersionsProtocolSnapshot)
+			}
+		})
+	}
+}
+
+func TestListVersionsContract_Extended(t *testing.T) {
+	mock, r := newVersionsRouter(t)
+	expectContractVersionQueries(mock)
+
+	w := doVersionsGET(r, "application/vnd.tfr.v1+json")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/vnd.tfr.v1+json" {
+		t.Errorf("Content-Type = %q, want application/vnd.tfr.v1+json", ct)
+	}
+	if vary := w.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("Vary = %q, want Accept", vary)
+	}
+
+	var resp ModuleVersionsExtendedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Namespace != "hashicorp" || resp.Total != 2 || len(resp.Versions) != 2 {
+		t.Fatalf("unexpected document: %+v", resp)
+	}
+	v2, v1 := resp.Versions[0], resp.Versions[1]
+	if v2.PublishedBy == nil || v2.PublishedBy.ID != "user-1" || v2.PublishedBy.Name == nil || *v2.PublishedBy.Name != "Alice" {
+		t.Errorf("published_by = %+v", v2.PublishedBy)
+	}
+	if v2.SizeBytes != 2048 || v2.Checksum != "def456" || v2.TagName == nil || *v2.TagName != "v2.0.0" {
+		t.Errorf("v2 metadata = %+v", v2)
+	}
+	if v2.Deprecation != nil {
+		t.Errorf("non-deprecated version has deprecation: %+v", v2.Deprecation)
+	}
+	if !v1.Deprecated || v1.Deprecation == nil || v1.Deprecation.Message == nil || *v1.Deprecation.Message != "use 2.x" {
+		t.Errorf("deprecation = %+v", v1.Deprecation)
+	}
+	if !v1.PublishedAt.Equal(contractPublishedAt) {
+		t.Errorf("published_at = %v", v1.PublishedAt)
+	}
+}
+
+// Deprecation warnings (protocol.deprecation_warnings): off keeps the
+// protocol document byte-identical; on adds only a top-level "warnings" array.
+func TestListVersionsContract_DeprecationWarnings(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			t.Cleanup(func() { db.Close() })
+			cfg := &config.Config{Protocol: config.ProtocolConfig{DeprecationWarnings: enabled}}
+			r := gin.New()
+			r.GET("/v1/modules/:namespace/:name/:system/versions", ListVersionsHandler(db, cfg))
+			expectContractVersionQueries(mock)
+
+			w := doVersionsGET(r, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+			var doc map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			raw, present := doc["warnings"]
+			if !enabled {
+				if present {
+					t.Fatalf("warnings sent with the flag off: %s", raw)
+				}
+				return
+			}
+			var warnings []string
+			if err := json.Unmarshal(raw, &warnings); err != nil {
+				t.Fatalf("warnings: %v", err)
+			}
+			want := "1.0.0 is deprecated: use 2.x (replacement: hashicorp/consul/aws)"
+			if len(warnings) != 1 || warnings[0] != want {
+				t.Errorf("warnings = %q, want [%q]", warnings, want)
+			}
+
+			delete(doc, "warnings")
+			rest, _ := json.Marshal(doc)
+			var pretty bytes.Buffer
+			_ = json.Indent(&pretty, rest, "", "  ")
+			if pretty.String() != moduleVersionsProtocolSnapshot {
+				t.Errorf("protocol document changed beyond warnings.\ngot:\n%s", pretty.String())
+			}
+		})
+	}
+}
+
+// Terraform version filtering (protocol.filter_by_terraform_version): versions
+// whose required_version excludes X-Terraform-Version are hidden only when the
+// flag is on and the header is present.
+func TestListVersionsContract_FilterByTerraformVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		tfVersion string
+		want      []string
+	}{
+		{"flag off", false, "1.5.7", []string{"2.0.0", "1.0.0"}},
+		{"no header", true, "", []string{"2.0.0", "1.0.0"}},
+		{"incompatible", true, "1.5.7", []string{"1.0.0"}},
+		{"compatible", true, "1.9.0", []string{"2.0.0", "1.0.0"}},
+		{"prerelease cli", true, "1.6.0-beta1", []string{"2.0.0", "1.0.0"}},
+		{"unparseable header", true, "dev", []string{"2.0.0", "1.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			t.Cleanup(func() { db.Close() })
+			cfg := &config.Config{Protocol: config.ProtocolConfig{FilterByTerraformVersion: tt.enabled}}
+			r := gin.New()
+			r.GET("/v1/modules/:namespace/:name/:system/versions", ListVersionsHandler(db, cfg))
+
+			mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+			mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+			mock.ExpectQuery("SELECT COUNT.*FROM module_versions WHERE module_id").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+			mock.ExpectQuery("SELECT.*FROM module_versions.*WHERE mv.module_id").
+				WillReturnRows(sqlmock.NewRows(moduleVersionListCols2).
+					AddRow("ver-2", "mod-1", "2.0.0", "modules/hashicorp/consul/aws/2.0.0.tgz", "local",
+						2048, "def456", nil, nil, nil, int64(7), false, nil, nil, nil, contractPublishedAt,
+						nil, nil, nil, ">= 1.6.0", false).
+					AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tgz", "local",
+						1024, "abc123", nil, nil, nil, int64(5), false, nil, nil, nil, contractPublishedAt,
+						nil, nil, nil, nil, false))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/v1/modules/hashicorp/consul/aws/versions", nil)
+			if tt.tfVersion != "" {
+				req.Header.Set("X-Terraform-Version", tt.tfVersion)
+			}
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+
+			var doc struct {
+				Modules []struct {
+					Versions []struct {
+						Version string `json:"version"`
+					} `json:"versions"`
+				} `json:"modules"`
+				Total int `json:"total"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			var got []string
+			for _, v := range doc.Modules[0].Versions {
+				got = append(got, v.Version)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) || doc.Total != len(tt.want) {
+				t.Errorf("versions = %v (total %d), want %v", got, doc.Total, tt.want)
+			}
+		})
+	}
+}
+
+// Pre-release versions are hidden from the protocol document unless the
+// request passes include_prerelease=true or the module opted in; the extended
+// document always lists them, flagged. Hiding happens in SQL, which also
+// evaluates the module's opt-in, so the mocked rows are the database's answer.
+func TestListVersionsContract_Prereleases(t *testing.T) {
+	const (
+		hiddenCount = `SELECT COUNT.*FROM module_versions WHERE module_id = \$1 AND archived_at IS NULL AND \(module_versions.version !~.*include_prerelease`
+		hiddenPage  = `SELECT.*FROM module_versions.*WHERE mv.module_id = \$1 AND mv.archived_at IS NULL AND \(mv.version !~.*include_prerelease.*LIMIT`
+		allCount    = `SELECT COUNT.*FROM module_versions WHERE module_id = \$1 AND archived_at IS NULL$`
+		allPage     = `SELECT.*FROM module_versions.*WHERE mv.module_id = \$1 AND mv.archived_at IS NULL\s+ORDER BY`
+	)
+	tests := []struct {
+		name   string
+		query  string
+		accept string
+		hidden bool     // the queries must hide pre-releases
+		rows   []string // versions the database returns
+		want   []string
+	}{
+		{"hidden by default", "", "", true, []string{"1.0.0"}, []string{"1.0.0"}},
+		{"query opt-in", "?include_prerelease=true", "", false, []string{"1.1.0-rc.1", "1.0.0"}, []string{"1.1.0-rc.1", "1.0.0"}},
+		{"module opt-in", "", "", true, []string{"1.1.0-rc.1", "1.0.0"}, []string{"1.1.0-rc.1", "1.0.0"}},
+		{"extended lists all", "", "application/vnd.tfr.v1+json", false, []string{"1.1.0-rc.1", "1.0.0"}, []string{"1.1.0-rc.1", "1.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, r := newVersionsRouter(t)
+			countQuery, pageQuery := allCount, allPage
+			if tt.hidden {
+				countQuery, pageQuery = hiddenCount, hiddenPage
+			}
+			mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+			mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+			mock.ExpectQuery(countQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(tt.rows)))
+			mock.ExpectQuery(pageQuery).WillReturnRows(contractVersionRows(tt.rows...))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/v1/modules/hashicorp/consul/aws/versions"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("expectations: %v", err)
+			}
+
+			var got []string
+			var total int
+			if tt.accept != "" {
+				var doc ModuleVersionsExtendedResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+					t.Fatalf("unmarshal: %v", err)
+				}
+				for _, v := range doc.Versions {
+					got = append(got, v.Version)
+					if v.Prerelease != (v.Version == "1.1.0-rc.1") {
+						t.Errorf("%s: prerelease = %v", v.Version, v.Prerelease)
+					}
+				}
+				total = doc.Total
+			} else {
+				got, total = protocolVersions(t, w)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) || total != len(tt.want) {
+				t.Errorf("versions = %v (total %d), want %v", got, total, tt.want)
+			}
+		})
+	}
+}
+
+// Hidden pre-releases spread across pages must not shorten pages or make the
+// total vary between them: the filter runs before LIMIT/OFFSET and the count
+// uses the same filter.
+func TestListVersionsContract_PrereleasesAcrossPages(t *testing.T) {
+	// All versions, newest first: 2.0.0-rc.1, 1.2.0, 1.2.0-beta.1, 1.1.0,
+	// 1.0.0. The database returns the stable ones.
+	stable := []string{"1.2.0", "1.1.0", "1.0.0"}
+	const limit = 2
+
+	for offset := 0; offset < len(stable); offset += limit {
+		t.Run(fmt.Sprintf("offset=%d", offset), func(t *testing.T) {
+			mock, r := newVersionsRouter(t)
+			page := stable[offset:min(offset+limit, len(stable))]
+			mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+			mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+			mock.ExpectQuery(`SELECT COUNT.*FROM module_versions WHERE module_id.*version !~`).
+				WithArgs("mod-1").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(stable)))
+			mock.ExpectQuery(`SELECT.*FROM module_versions.*mv.version !~.*LIMIT \$2 OFFSET \$3`).
+				WithArgs("mod-1", limit, offset).
+				WillReturnRows(contractVersionRows(page...))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet,
+				fmt.Sprintf("/v1/modules/hashicorp/consul/aws/versions?limit=%d&offset=%d", limit, offset), nil)
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("expectations: %v", err)
+			}
+			got, total := protocolVersions(t, w)
+			if fmt.Sprint(got) != fmt.Sprint(page) || total != len(stable) {
+				t.Errorf("versions = %v (total %d), want %v (total %d)", got, total, page, len(stable))
+			}
+		})
+	}
+}
+
+// contractVersionRows returns module version rows for versions, in order.
+func contractVersionRows(versions ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(moduleVersionListCols2)
+	for i, v := range versions {
+		rows.AddRow(fmt.Sprintf("ver-%d", i), "mod-1", v, "modules/hashicorp/consul/aws/"+v+".tgz", "local",
+			1024, "abc123", nil, nil, nil, int64(0), false, nil, nil, nil, contractPublishedAt,
+			nil, nil, nil, nil, false)
+	}
+	return rows
+}
+
+// protocolVersions returns the versions and total of a protocol document.
+func protocolVersions(t *testing.T, w *httptest.ResponseRecorder) ([]string, int) {
+	t.Helper()
+	var doc struct {
+		Modules []struct {
+			Versions []struct {
+				Version string `json:"version"`
+			} `json:"versions"`
+		} `json:"modules"`
+		Total int `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	var got []string
+	for _, v := range doc.Modules[0].Versions {
+		got = append(got, v.Version)
+	}
+	return got, doc.Total
+}
+
+// With protocol.filter_by_terraform_version on, versions the calling
+// terraform cannot run are dropped before the page is cut, so pages stay full
+// and total counts the compatible versions only.
+func TestListVersionsContract_TerraformVersionAcrossPages(t *testing.T) {
+	// Newest first; 3.0.0 and 2.1.0 need terraform >= 1.9.
+	all := []struct{ version, required string }{
+		{"3.0.0", ">= 1.9.0"}, {"2.1.0", ">= 1.9.0"}, {"2.0.0", ""}, {"1.1.0", ">= 1.0.0"}, {"1.0.0", ""},
+	}
+	compatible := []string{"2.0.0", "1.1.0", "1.0.0"}
+	const limit = 2
+
+	for offset := 0; offset < len(compatible); offset += limit {
+		t.Run(fmt.Sprintf("offset=%d", offset), func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			t.Cleanup(func() { db.Close() })
+			cfg := &config.Config{Protocol: config.ProtocolConfig{FilterByTerraformVersion: true}}
+			r := gin.New()
+			r.GET("/v1/modules/:namespace/:name/:system/versions", ListVersionsHandler(db, cfg))
+
+			rows := sqlmock.NewRows(moduleVersionListCols2)
+			for i, v := range all {
+				var required interface{}
+				if v.required != "" {
+					required = v.required
+				}
+				rows.AddRow(fmt.Sprintf("ver-%d", i), "mod-1", v.version, "modules/hashicorp/consul/aws/"+v.version+".tgz", "local",
+					1024, "abc123", nil, nil, nil, int64(0), false, nil, nil, nil, contractPublishedAt,
+					nil, nil, nil, required, false)
+			}
+			mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow2())
+			mock.ExpectQuery("SELECT.*FROM modules.*WHERE").WillReturnRows(sampleModuleRow2())
+			mock.ExpectQuery("SELECT COUNT.*FROM module_versions WHERE module_id").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(all)))
+			// Every version is loaded: no limit, no offset.
+			mock.ExpectQuery("SELECT.*FROM module_versions.*LIMIT \\$2 OFFSET \\$3").
+				WithArgs("mod-1", nil, 0).
+				WillReturnRows(rows)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet,
+				fmt.Sprintf("/v1/modules/hashicorp/consul/aws/versions?limit=%d&offset=%d", limit, offset), nil)
+			req.Header.Set("X-Terraform-Version", "1.5.7")
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("expectations: %v", err)
+			}
+			want := compatible[offset:min(offset+limit, len(compatible))]
+			got, total := protocolVersions(t, w)
+			if fmt.Sprint(got) != fmt.Sprint(want) || total != len(compatible) {
+				t.Errorf("versions = %v (total %d), want %v (total %d)", got, total, want, len(compatible))
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package negotiate implements Accept-header content negotiation for protocol
+// endpoints that also offer the registry's extended document format.
+//
+// Protocol endpoints (module and provider version listings) must keep serving
+// the exact document Terraform expects. Tooling that wants richer metadata
+// asks for it explicitly with `Accept: application/vnd.tfr.v1+json`; any
+// other Accept value — including none, `application/json`, and `*/*` — gets
+// the protocol document.
+package negotiate
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExtendedMediaType is the media type of the registry's extended documents.
+const ExtendedMediaType = "application/vnd.tfr.v1+json"
+
+// WantsExtended reports whether the request's Accept header prefers the
+// extended document over plain JSON. The extended type must be listed
+// explicitly with a non-zero quality that is at least as high as the best
+// competing JSON range (application/json, application/*, */*); wildcards
+// alone never select it.
+func WantsExtended(c *gin.Context) bool {
+	return prefersExtended(c.GetHeader("Accept"))
+}
+
+// SetVary marks the response as varying on Accept so shared caches keep the
+// protocol and extended documents apart.
+func SetVary(c *gin.Context) {
+	c.Writer.Header().Add("Vary", "Accept")
+}
+
+func prefersExtended(accept string) bool {
+	if accept == "" {
+		return false
+	}
+	extQ, jsonQ := -1.0, -1.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, q := parseMediaRange(part)
+		switch mediaType {
+		case ExtendedMediaType:
+			extQ = maxFloat(extQ, q)
+		case "application/json", "application/*", "*/*":
+			jsonQ = maxFloat(jsonQ, q)
+		}
+	}
+	return extQ > 0 && extQ >= jsonQ
+}
+
+// parseMediaRange splits one Accept entry into its lower-cased media type and
+// quality value (default 1; malformed q values count as 0).
+func parseMediaRange(part string) (string, float64) {
+	fields := strings.Split(part, ";")
+	mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+	q := 1.0
+	for _, param := range fields[1:] {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.ToLower(strings.TrimSpace(key)) != "q" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			parsed = 0
+		}
+		q = parsed
+	}
+	return mediaType, q
+}
+
+func maxFloat(a, b float64) float64 {
+	if b > a {
+		return b
+	}
+	return a
+}
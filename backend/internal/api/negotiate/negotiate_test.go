@@ -0,0 +1,29 @@
+package negotiate
+
+import "testing"
+
+func TestPrefersExtended(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/vnd.tfr.v1+json", true},
+		{"Application/VND.TFR.V1+JSON", true},
+		{"application/vnd.tfr.v1+json, application/json;q=0.9", true},
+		{"application/json, application/vnd.tfr.v1+json;q=0.5", false},
+		{"application/json;q=0.5, application/vnd.tfr.v1+json", true},
+		{"application/vnd.tfr.v1+json;q=0", false},
+		{"application/vnd.tfr.v1+json;q=bogus", false},
+		{"application/vnd.tfr.v1+json; charset=utf-8", true},
+		{"application/vnd.tfr.v2+json", false},
+		{"text/html, */*;q=0.8", false},
+	}
+	for _, tt := range tests {
+		if got := prefersExtended(tt.accept); got != tt.want {
+			t.Errorf("prefersExtended(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
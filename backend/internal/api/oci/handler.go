@@ -183,6 +183,10 @@ func (h *Handler) GetBlob(c *gin.Context) {
 // @Tags         Modules
 // @Security     Bearer
 // @Produce      json
+// @Param        namespace  path  string  true  "Module namespace"
+// @Param        name       path  string  true  "Module name"
+// @Param        system     path  string  true  "Provider/system"
+// @Param        reference  path  string  true  "Version tag or digest"
 // @Failure      405  {object}  map[string]interface{}
 // @Router       /v2/{namespace}/{name}/{system}/manifests/{reference} [put]
 func (h *Handler) PutManifest(c *gin.Context) {
@@ -46,11 +46,11 @@ func versionRow(id, moduleID, version, storagePath, checksum string, sizeBytes i
 		"id", "module_id", "version", "storage_path", "storage_backend",
 		"size_bytes", "checksum", "readme", "published_by",
 		"download_count", "deprecated", "deprecated_at", "deprecation_message",
-		"replacement_source", "created_at", "commit_sha", "tag_name", "scm_repo_id",
+		"replacement_source", "created_at", "commit_sha", "tag_name", "scm_repo_id", "required_terraform_version",
 	}).AddRow(id, moduleID, version, storagePath, "local",
 		sizeBytes, checksum, "", "",
 		0, false, nil, nil,
-		nil, now, nil, nil, nil)
+		nil, now, nil, nil, nil, nil)
 }
 
 // ─── Ping ────────────────────────────────────────────────────────────────────
@@ -0,0 +1,176 @@
+// Package pagination parses the paging parameters of list endpoints and
+// builds the standard meta block their responses carry, so every list caps
+// its page size and reports where it is the same way.
+//
+// A page is requested with limit and offset, or with their aliases per_page
+// and page (1-based). Missing or malformed values fall back to the endpoint's
+// default, and a limit above the endpoint's cap is lowered to the cap; a list
+// never returns an unbounded result set. Endpoints over large, append-mostly
+// tables (audit logs, webhook events) also accept an opaque cursor, which
+// continues after the last row of the previous page at constant cost however
+// deep the page (see repositories.Keyset).
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// maxOffset keeps offsets within an int32, which is what PostgreSQL accepts.
+const maxOffset = 1<<31 - 1
+
+// Limits are an endpoint's default page size and its cap.
+type Limits struct {
+	Default int
+	Max     int
+}
+
+// Page is a parsed page request.
+type Page struct {
+	Limit  int
+	Offset int
+	// After is set when the request carried a cursor: the page starts after
+	// that position, and Offset is 0.
+	After *repositories.Keyset
+}
+
+// Number returns the 1-based page number of an offset page, for responses
+// that still report page and per_page.
+func (p Page) Number() int {
+	return p.Offset/p.Limit + 1
+}
+
+// Parse reads limit and offset, or per_page and page, from the query string.
+func Parse(c *gin.Context, l Limits) Page {
+	p := Page{Limit: l.Default}
+	raw := c.Query("limit")
+	if raw == "" {
+		raw = c.Query("per_page")
+	}
+	if n, err := strconv.Atoi(raw); err == nil && n >= 1 {
+		p.Limit = min(n, l.Max)
+	}
+
+	if raw := c.Query("offset"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+			p.Offset = min(n, maxOffset)
+		}
+	} else if n, err := strconv.Atoi(c.Query("page")); err == nil && n > 1 {
+		p.Offset = int(min(int64(n-1)*int64(p.Limit), maxOffset))
+	}
+	return p
+}
+
+// ErrCursorWithOffset is returned by ParseCursor when a request combines a
+// cursor with offset or page.
+var ErrCursorWithOffset = errors.New("cursor cannot be combined with offset or page")
+
+// ErrInvalidCursor is returned by ParseCursor for a cursor this package did
+// not issue.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ParseCursor is Parse for endpoints that also accept ?cursor=.
+func ParseCursor(c *gin.Context, l Limits) (Page, error) {
+	p := Parse(c, l)
+	raw := c.Query("cursor")
+	if raw == "" {
+		return p, nil
+	}
+	if c.Query("offset") != "" || c.Query("page") != "" {
+		return Page{}, ErrCursorWithOffset
+	}
+	after, err := decodeCursor(raw)
+	if err != nil {
+		return Page{}, ErrInvalidCursor
+	}
+	p.Offset, p.After = 0, after
+	return p, nil
+}
+
+// cursor is the JSON inside an encoded cursor.
+type cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// EncodeCursor returns the cursor that continues after k.
+func EncodeCursor(k repositories.Keyset) string {
+	b, _ := json.Marshal(cursor{CreatedAt: k.CreatedAt, ID: k.ID}) // a struct of a time and a string always marshals
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*repositories.Keyset, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var cur cursor
+	if err := json.Unmarshal(b, &cur); err != nil {
+		return nil, err
+	}
+	if cur.CreatedAt.IsZero() {
+		return nil, errors.New("cursor has no position")
+	}
+	if _, err := uuid.Parse(cur.ID); err != nil {
+		return nil, err
+	}
+	return &repositories.Keyset{CreatedAt: cur.CreatedAt, ID: cur.ID}, nil
+}
+
+// Meta is the standard pagination block list responses carry as "meta".
+// Total is omitted by cursor pages, which do not count the whole list.
+// NextCursor is set on endpoints that accept cursors when there is a next
+// page.
+type Meta struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Total      *int64 `json:"total,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+} // @name PaginationMetadata
+
+// Meta returns the meta block of an offset page out of total results.
+func (p Page) Meta(total int64) Meta {
+	return Meta{Limit: p.Limit, Offset: p.Offset, Total: &total, HasMore: int64(p.Offset)+int64(p.Limit) < total}
+}
+
+// CursorMeta returns the meta block of a page read without a count: hasMore
+// reports whether rows follow it, and last is the position of its last row.
+func (p Page) CursorMeta(hasMore bool, last repositories.Keyset) Meta {
+	m := Meta{Limit: p.Limit, Offset: p.Offset, HasMore: hasMore}
+	return m.WithNextCursor(last)
+}
+
+// WithNextCursor sets the cursor that continues after last when there is a
+// next page.
+func (m Meta) WithNextCursor(last repositories.Keyset) Meta {
+	if m.HasMore {
+		m.NextCursor = EncodeCursor(last)
+	}
+	return m
+}
+
+// Trim cuts rows read with a limit of p.Limit+1 down to p.Limit, and reports
+// whether the extra row was there.
+func Trim[T any](p Page, rows []T) ([]T, bool) {
+	if len(rows) > p.Limit {
+		return rows[:p.Limit], true
+	}
+	return rows, false
+}
+
+// Window returns the page of items held in memory, for lists whose
+// repository cannot page them itself.
+func Window[T any](p Page, items []T) []T {
+	if p.Offset >= len(items) {
+		return items[:0]
+	}
+	return items[p.Offset:min(p.Offset+p.Limit, len(items))]
+}
@@ -0,0 +1,135 @@
+package pagination
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var testLimits = Limits{Default: 20, Max: 100}
+
+func contextFor(query string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+	return c
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query         string
+		limit, offset int
+	}{
+		{"", 20, 0},
+		{"limit=50&offset=10", 50, 10},
+		{"per_page=50&page=3", 50, 100},
+		{"limit=10&per_page=50", 10, 0},
+		{"offset=5&page=3", 20, 5},
+		{"limit=5000", 100, 0},
+		{"limit=0", 20, 0},
+		{"limit=-3&offset=-1", 20, 0},
+		{"limit=abc&page=abc", 20, 0},
+		{"page=0", 20, 0},
+		{"offset=99999999999", 20, maxOffset},
+		{"per_page=100&page=99999999999", 100, maxOffset},
+	}
+	for _, tt := range tests {
+		p := Parse(contextFor(tt.query), testLimits)
+		if p.Limit != tt.limit || p.Offset != tt.offset || p.After != nil {
+			t.Errorf("Parse(%q) = %+v, want limit %d offset %d", tt.query, p, tt.limit, tt.offset)
+		}
+	}
+}
+
+func TestPageNumber(t *testing.T) {
+	if n := (Page{Limit: 20, Offset: 40}).Number(); n != 3 {
+		t.Errorf("Number() = %d, want 3", n)
+	}
+}
+
+func TestParseCursor_RoundTrip(t *testing.T) {
+	k := repositories.Keyset{
+		CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 890123000, time.UTC),
+		ID:        "3f2a8c3e-6c1d-4d59-9a55-1d5e7a8d4b21",
+	}
+	p, err := ParseCursor(contextFor("limit=10&cursor="+EncodeCursor(k)), testLimits)
+	if err != nil {
+		t.Fatalf("ParseCursor: %v", err)
+	}
+	if p.Limit != 10 || p.Offset != 0 || p.After == nil || !p.After.CreatedAt.Equal(k.CreatedAt) || p.After.ID != k.ID {
+		t.Errorf("ParseCursor = %+v (after %+v), want limit 10 after %+v", p, p.After, k)
+	}
+}
+
+func TestParseCursor_Rejects(t *testing.T) {
+	valid := EncodeCursor(repositories.Keyset{CreatedAt: time.Now(), ID: "3f2a8c3e-6c1d-4d59-9a55-1d5e7a8d4b21"})
+	tests := []struct {
+		query string
+		want  error
+	}{
+		{"cursor=" + valid + "&offset=10", ErrCursorWithOffset},
+		{"cursor=" + valid + "&page=2", ErrCursorWithOffset},
+		{"cursor=!!!", ErrInvalidCursor},
+		{"cursor=" + EncodeCursor(repositories.Keyset{CreatedAt: time.Now(), ID: "1; DROP TABLE audit_logs"}), ErrInvalidCursor},
+		{"cursor=" + EncodeCursor(repositories.Keyset{ID: "3f2a8c3e-6c1d-4d59-9a55-1d5e7a8d4b21"}), ErrInvalidCursor},
+		{"cursor=bm90IGpzb24", ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		if _, err := ParseCursor(contextFor(tt.query), testLimits); !errors.Is(err, tt.want) {
+			t.Errorf("ParseCursor(%q) error = %v, want %v", tt.query, err, tt.want)
+		}
+	}
+}
+
+func TestParseCursor_WithoutCursor(t *testing.T) {
+	p, err := ParseCursor(contextFor("offset=40"), testLimits)
+	if err != nil || p.Offset != 40 || p.After != nil {
+		t.Errorf("ParseCursor = %+v, %v; want an offset page", p, err)
+	}
+}
+
+func TestMeta(t *testing.T) {
+	m := Page{Limit: 20, Offset: 20}.Meta(45)
+	if m.Total == nil || *m.Total != 45 || !m.HasMore || m.NextCursor != "" {
+		t.Errorf("Meta(45) = %+v, want total 45 and has_more", m)
+	}
+	if m := (Page{Limit: 20, Offset: 40}).Meta(45); m.HasMore {
+		t.Errorf("last page has_more = true")
+	}
+
+	last := repositories.Keyset{CreatedAt: time.Now(), ID: "3f2a8c3e-6c1d-4d59-9a55-1d5e7a8d4b21"}
+	if m := (Page{Limit: 20}).CursorMeta(true, last); m.Total != nil || m.NextCursor != EncodeCursor(last) {
+		t.Errorf("CursorMeta(true) = %+v, want a next cursor and no total", m)
+	}
+	if m := (Page{Limit: 20}).CursorMeta(false, last); m.NextCursor != "" {
+		t.Errorf("CursorMeta(false) next_cursor = %q, want none", m.NextCursor)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	p := Page{Limit: 2}
+	if rows, more := Trim(p, []int{1, 2, 3}); len(rows) != 2 || !more {
+		t.Errorf("Trim(3 rows) = %v, %v", rows, more)
+	}
+	if rows, more := Trim(p, []int{1, 2}); len(rows) != 2 || more {
+		t.Errorf("Trim(2 rows) = %v, %v", rows, more)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	items := []int{0, 1, 2, 3, 4}
+	if got := Window(Page{Limit: 2, Offset: 2}, items); len(got) != 2 || got[0] != 2 {
+		t.Errorf("Window = %v, want [2 3]", got)
+	}
+	if got := Window(Page{Limit: 2, Offset: 4}, items); len(got) != 1 || got[0] != 4 {
+		t.Errorf("Window = %v, want [4]", got)
+	}
+	if got := Window(Page{Limit: 2, Offset: 10}, items); got == nil || len(got) != 0 {
+		t.Errorf("Window past the end = %#v, want an empty slice", got)
+	}
+}
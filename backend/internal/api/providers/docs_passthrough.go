@@ -0,0 +1,155 @@
+// docs_passthrough.go implements the documentation passthrough endpoints for
+// mirrored providers. They sit beside the protocol routes under
+// /v1/providers/:namespace/:type/:version and serve the upstream registry's
+// doc pages from the registry's own cache; see services.ProviderDocsPassthrough.
+package providers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+// ProviderDocsSourceResponse attributes passthrough documentation to the
+// upstream registry.
+type ProviderDocsSourceResponse struct {
+	Registry    string `json:"registry"`
+	URL         string `json:"url"`
+	Attribution string `json:"attribution"`
+}
+
+// ProviderDocsPassthroughResponse is returned by the passthrough doc index
+// endpoint.
+type ProviderDocsPassthroughResponse struct {
+	Docs      []ProviderDocEntryResponse `json:"docs"`
+	Source    ProviderDocsSourceResponse `json:"source"`
+	FetchedAt time.Time                  `json:"fetched_at"`
+}
+
+// ProviderDocPagePassthroughResponse is returned by the passthrough doc page
+// endpoint.
+type ProviderDocPagePassthroughResponse struct {
+	Title       string                     `json:"title"`
+	Category    string                     `json:"category"`
+	Subcategory *string                    `json:"subcategory,omitempty"`
+	Slug        string                     `json:"slug"`
+	Content     string                     `json:"content"`
+	Source      ProviderDocsSourceResponse `json:"source"`
+	FetchedAt   time.Time                  `json:"fetched_at"`
+	// Stale is true when the cached copy is past the mirror's
+	// docs_cache_ttl_hours and could not be refreshed from upstream.
+	Stale bool `json:"stale"`
+}
+
+func sourceResponse(s services.ProviderDocsSource) ProviderDocsSourceResponse {
+	return ProviderDocsSourceResponse{Registry: s.Registry, URL: s.URL, Attribution: s.Attribution}
+}
+
+// respondDocsPassthroughError maps passthrough failures to protocol-style
+// error responses.
+func respondDocsPassthroughError(c *gin.Context, err error) {
+	var denied *services.ProviderDocsDeniedError
+	switch {
+	case errors.Is(err, services.ErrProviderDocsNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"errors": []string{"Documentation not found"}})
+	case errors.Is(err, services.ErrProviderDocsDisabled):
+		c.JSON(http.StatusNotFound, gin.H{"errors": []string{err.Error()}})
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{"errors": []string{denied.Error()}})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"errors": []string{"upstream registry timed out"}})
+	case errors.Is(err, services.ErrProviderDocsUpstream):
+		slog.Warn("provider docs passthrough: upstream fetch failed", "path", c.Request.URL.Path, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"errors": []string{services.ErrProviderDocsUpstream.Error()}})
+	default:
+		slog.Error("provider docs passthrough failed", "path", c.Request.URL.Path, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": []string{"Failed to load provider documentation"}})
+	}
+}
+
+// @Summary      List passthrough provider documentation
+// @Description  Lists the documentation pages of a mirrored provider version, fetched from the upstream registry's v2 provider-docs API on the first request and cached. Only available for providers whose mirror sets docs_passthrough_enabled, and only when the mirror policies allow the upstream provider.
+// @Tags         Providers
+// @Produce      json
+// @Param        namespace  path   string  true   "Provider namespace"
+// @Param        type       path   string  true   "Provider type"
+// @Param        version    path   string  true   "Provider version"
+// @Param        category   query  string  false  "Filter by doc category (overview, resources, data-sources, etc.)"
+// @Success      200  {object}  ProviderDocsPassthroughResponse
+// @Failure      403  {object}  map[string]interface{}  "Denied by the mirror policies"
+// @Failure      404  {object}  map[string]interface{}  "Provider or version not found, or passthrough not enabled"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      502  {object}  map[string]interface{}  "Failed to fetch from upstream"
+// @Router       /v1/providers/{namespace}/{type}/{version}/docs [get]
+// ProviderDocsPassthroughHandler handles listing passthrough doc pages
+// Implements: GET /v1/providers/:namespace/:type/:version/docs
+func ProviderDocsPassthroughHandler(svc *services.ProviderDocsPassthrough) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		index, err := svc.Index(c.Request.Context(), c.Param("namespace"), c.Param("type"), c.Param("version"), c.Query("category"))
+		if err != nil {
+			respondDocsPassthroughError(c, err)
+			return
+		}
+
+		docs := make([]ProviderDocEntryResponse, 0, len(index.Docs))
+		for _, d := range index.Docs {
+			docs = append(docs, ProviderDocEntryResponse{
+				ID:          d.UpstreamDocID,
+				Title:       d.Title,
+				Slug:        d.Slug,
+				Category:    d.Category,
+				Subcategory: d.Subcategory,
+				Language:    d.Language,
+			})
+		}
+		c.JSON(http.StatusOK, ProviderDocsPassthroughResponse{
+			Docs:      docs,
+			Source:    sourceResponse(index.Source),
+			FetchedAt: index.FetchedAt,
+		})
+	}
+}
+
+// @Summary      Get passthrough provider documentation page
+// @Description  Returns one documentation page of a mirrored provider version from the registry's cache, fetching it from the upstream registry when it is missing or older than the mirror's docs_cache_ttl_hours. When the refresh fails an expired copy is served with stale set. Only available for providers whose mirror sets docs_passthrough_enabled.
+// @Tags         Providers
+// @Produce      json
+// @Param        namespace  path  string  true  "Provider namespace"
+// @Param        type       path  string  true  "Provider type"
+// @Param        version    path  string  true  "Provider version"
+// @Param        category   path  string  true  "Documentation category (overview, resources, data-sources, etc.)"
+// @Param        slug       path  string  true  "Documentation slug"
+// @Success      200  {object}  ProviderDocPagePassthroughResponse
+// @Failure      403  {object}  map[string]interface{}  "Denied by the mirror policies"
+// @Failure      404  {object}  map[string]interface{}  "Page not found, or passthrough not enabled"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      502  {object}  map[string]interface{}  "Failed to fetch from upstream"
+// @Router       /v1/providers/{namespace}/{type}/{version}/docs/{category}/{slug} [get]
+// ProviderDocPagePassthroughHandler handles serving one passthrough doc page
+// Implements: GET /v1/providers/:namespace/:type/:version/docs/:category/:slug
+func ProviderDocPagePassthroughHandler(svc *services.ProviderDocsPassthrough) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := svc.Page(c.Request.Context(), c.Param("namespace"), c.Param("type"), c.Param("version"),
+			c.Param("category"), c.Param("slug"))
+		if err != nil {
+			respondDocsPassthroughError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, ProviderDocPagePassthroughResponse{
+			Title:       page.Doc.Title,
+			Category:    page.Doc.Category,
+			Subcategory: page.Doc.Subcategory,
+			Slug:        page.Doc.Slug,
+			Content:     page.Content,
+			Source:      sourceResponse(page.Source),
+			FetchedAt:   page.FetchedAt,
+			Stale:       page.Stale,
+		})
+	}
+}
@@ -13,9 +13,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/artifacthead"
+	"github.com/terraform-registry/terraform-registry/internal/api/licensegate"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/db/transient"
+	"github.com/terraform-registry/terraform-registry/internal/downloadstats"
 	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/telemetry"
@@ -23,7 +27,7 @@ import (
 )
 
 // @Summary      Download provider platform binary
-// @Description  Returns download URL, checksums, and signing key info for a specific provider platform. Implements the Terraform Provider Registry Protocol.
+// @Description  Returns download URL, checksums, and signing key info for a specific provider platform. Implements the Terraform Provider Registry Protocol. A HEAD request answers 200 with no body and the archive's Content-Length, ETag (its SHA-256), Last-Modified and Accept-Ranges headers, read from storage metadata.
 // @Tags         Providers
 // @Produce      json
 // @Param        namespace  path  string  true  "Provider namespace"
@@ -33,15 +37,27 @@ import (
 // @Param        arch       path  string  true  "Target architecture (e.g. amd64, arm64)"
 // @Success      200  {object}  providers.ProviderDownloadResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid version or platform"
+// @Failure      403  {object}  map[string]interface{}  "Provider is license-gated and the request is anonymous"
 // @Failure      404  {object}  map[string]interface{}  "Provider, version, or platform not found"
+// @Failure      410  {object}  map[string]interface{}  "Platform archive is missing from storage and marked broken"
+// @Failure      451  {object}  map[string]interface{}  "Provider is license-gated and the user has not accepted its current license version"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      503  {object}  map[string]interface{}  "Database temporarily unavailable (Retry-After set)"
 // @Router       /v1/providers/{namespace}/{type}/{version}/download/{os}/{arch} [get]
+// @Router       /v1/providers/{namespace}/{type}/{version}/download/{os}/{arch} [head]
 // DownloadHandler handles provider download requests
 // Implements: GET /v1/providers/:namespace/:type/:version/download/:os/:arch
 // Returns JSON with download URL, checksums, and signing keys
-func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config, auditRepo *repositories.AuditRepository) gin.HandlerFunc {
+// Each download is also counted per version and platform in stats (nil: not
+// recorded) for the admin provider stats. A license-gated provider is only
+// served to users who accepted its license (licenses, nil: no gating).
+func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config, auditRepo *repositories.AuditRepository, stats *downloadstats.Recorder, licenses *licensegate.Gate) gin.HandlerFunc {
 	providerRepo := repositories.NewProviderRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
+	var consistencyRepo *repositories.StorageConsistencyRepository
+	if cfg.StorageConsistency.Enabled {
+		consistencyRepo = repositories.NewStorageConsistencyRepository(db)
+	}
 
 	return func(c *gin.Context) {
 		namespace := c.Param("namespace")
@@ -67,11 +83,9 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 		}
 
 		// Get organization context
-		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+		org, err := transient.Value(c.Request.Context(), orgRepo.GetDefaultOrganization)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to get organization context",
-			})
+			respondQueryError(c, err, "Failed to get organization context")
 			return
 		}
 		if org == nil {
@@ -82,11 +96,11 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 		}
 
 		// Get provider
-		provider, err := providerRepo.GetProvider(c.Request.Context(), org.ID, namespace, providerType)
+		provider, err := transient.Value(c.Request.Context(), func(ctx context.Context) (*models.Provider, error) {
+			return providerRepo.GetProvider(ctx, org.ID, namespace, providerType)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to query provider",
-			})
+			respondQueryError(c, err, "Failed to query provider")
 			return
 		}
 		if provider == nil {
@@ -96,12 +110,16 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 			return
 		}
 
+		if !licenses.Allow(c, provider) {
+			return
+		}
+
 		// Get provider version
-		providerVersion, err := providerRepo.GetVersion(c.Request.Context(), provider.ID, version)
+		providerVersion, err := transient.Value(c.Request.Context(), func(ctx context.Context) (*models.ProviderVersion, error) {
+			return providerRepo.GetVersion(ctx, provider.ID, version)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to query provider version",
-			})
+			respondQueryError(c, err, "Failed to query provider version")
 			return
 		}
 		if providerVersion == nil {
@@ -117,11 +135,11 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 		// uploaded versions have no mirrored row and are always visible. We
 		// return the same 404 as a missing version so the gate does not reveal
 		// that a hidden version exists.
-		approvalStatus, err := providerRepo.GetVersionApprovalStatus(c.Request.Context(), providerVersion.ID)
+		approvalStatus, err := transient.Value(c.Request.Context(), func(ctx context.Context) (*string, error) {
+			return providerRepo.GetVersionApprovalStatus(ctx, providerVersion.ID)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to query provider version",
-			})
+			respondQueryError(c, err, "Failed to query provider version")
 			return
 		}
 		if approvalStatus != nil &&
@@ -133,11 +151,11 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 		}
 
 		// Get platform binary
-		platform, err := providerRepo.GetPlatform(c.Request.Context(), providerVersion.ID, os, arch)
+		platform, err := transient.Value(c.Request.Context(), func(ctx context.Context) (*models.ProviderPlatform, error) {
+			return providerRepo.GetPlatform(ctx, providerVersion.ID, os, arch)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to query provider platform",
-			})
+			respondQueryError(c, err, "Failed to query provider platform")
 			return
 		}
 		if platform == nil {
@@ -147,6 +165,31 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 			return
 		}
 
+		// Refuse a platform the consistency checker found without its archive,
+		// rather than hand out a URL that 404s at the storage backend.
+		if consistencyRepo != nil {
+			broken, err := transient.Value(c.Request.Context(), func(ctx context.Context) (bool, error) {
+				return consistencyRepo.IsBroken(ctx, models.ArtifactTypeProviderPlatform, platform.ID)
+			})
+			if err != nil {
+				respondQueryError(c, err, "Failed to check provider platform state")
+				return
+			}
+			if broken {
+				c.JSON(http.StatusGone, gin.H{
+					"errors": []string{"Provider platform archive is missing from storage"},
+				})
+				return
+			}
+		}
+
+		// A HEAD describes the archive instead of returning the download
+		// document, and is not counted as a download.
+		if artifacthead.IsHead(c) {
+			artifacthead.Respond(c, storageBackend, artifacthead.Mode(cfg), platform.StoragePath, platform.Shasum)
+			return
+		}
+
 		// Get download URL from storage backend
 		// TTL of 15 minutes for signed URLs
 		downloadURL, err := storageBackend.GetURL(c.Request.Context(), platform.StoragePath, 15*time.Minute)
@@ -157,13 +200,15 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 			return
 		}
 
-		// Resolve SHA256SUMS and signature URLs. For uploaded providers the
-		// SUMS/sig files live in our own storage backend (storage-key columns),
+		// Resolve SHA256SUMS and signature URLs. Uploaded providers, and
+		// mirrored versions since mirror sync started storing the files, keep
+		// the SUMS/sig files in our own storage backend (storage-key columns),
 		// in which case we generate a pre-signed URL on demand with the same
-		// 15-minute TTL as the binary download. For mirrored providers the
-		// columns hold external URLs published by HashiCorp and we pass them
-		// through verbatim. Both paths conform to the Provider Registry
-		// Protocol which permits relative, absolute, or pre-signed URLs.
+		// 15-minute TTL as the binary download. Mirrored versions synced before
+		// that, until a sync backfills them, only have the external URLs
+		// published upstream and we pass them through verbatim. Both paths
+		// conform to the Provider Registry Protocol which permits relative,
+		// absolute, or pre-signed URLs.
 		shasumsURL := ""
 		if providerVersion.ShasumStorageKey != nil && *providerVersion.ShasumStorageKey != "" {
 			if url, sumsErr := storageBackend.GetURL(c.Request.Context(), *providerVersion.ShasumStorageKey, 15*time.Minute); sumsErr == nil {
@@ -186,6 +231,26 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 			shasumsSignatureURL = providerVersion.ShasumSignatureURL
 		}
 
+		// With mirror_signing enabled, a version the registry has re-signed is
+		// served with the registry's SHA256SUMS, signature and key instead of
+		// the upstream ones (which stay on the version row for audit). Any
+		// failure here falls back to the upstream signature.
+		var resignature *models.ProviderVersionResignature
+		if cfg != nil && cfg.MirrorSigning.Enabled {
+			rs, rsErr := providerRepo.GetVersionResignature(c.Request.Context(), providerVersion.ID)
+			if rsErr != nil {
+				slog.Warn("failed to look up provider version re-signature", "version", providerVersion.Version, "error", rsErr)
+			} else if rs != nil {
+				sumsURL, sumsErr := storageBackend.GetURL(c.Request.Context(), rs.ShasumStorageKey, 15*time.Minute)
+				sigURL, sigErr := storageBackend.GetURL(c.Request.Context(), rs.ShasumSignatureStorageKey, 15*time.Minute)
+				if sumsErr == nil && sigErr == nil {
+					shasumsURL, shasumsSignatureURL, resignature = sumsURL, sigURL, rs
+				} else {
+					slog.Warn("failed to generate re-signed SHA256SUMS URLs", "version", providerVersion.Version, "sums_error", sumsErr, "sig_error", sigErr)
+				}
+			}
+		}
+
 		// Increment download counter asynchronously (don't block the response)
 		platformID := platform.ID
 		go func() {
@@ -197,6 +262,7 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 
 		// Increment Prometheus download counter
 		telemetry.ProviderDownloadsTotal.WithLabelValues(namespace, providerType, os, arch).Inc()
+		stats.Record(provider.ID, providerVersion.Version, os, arch)
 
 		// Audit log the download event asynchronously
 		if auditRepo != nil {
@@ -238,7 +304,14 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 		// https://www.terraform.io/docs/internals/provider-registry-protocol.html
 		// signing_keys must always be present; gpg_public_keys is empty when no key is configured.
 		gpgPublicKeys := []gin.H{}
-		if providerVersion.GPGPublicKey != "" {
+		if resignature != nil {
+			gpgPublicKeys = []gin.H{
+				{
+					"key_id":      resignature.KeyID,
+					"ascii_armor": resignature.GPGPublicKey,
+				},
+			}
+		} else if providerVersion.GPGPublicKey != "" {
 			gpgKey := resolveProviderGPGKey(providerVersion.GPGPublicKey)
 			keyID, err := validation.ExtractKeyID(gpgKey)
 			if err != nil {
@@ -257,15 +330,34 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 			"os":                    platform.OS,
 			"arch":                  platform.Arch,
 			"filename":              platform.Filename,
-			"download_url":          downloadURL,
-			"shasums_url":           shasumsURL,
-			"shasums_signature_url": shasumsSignatureURL,
+			"download_url":          middleware.TenantURL(c, downloadURL),
+			"shasums_url":           middleware.TenantURL(c, shasumsURL),
+			"shasums_signature_url": middleware.TenantURL(c, shasumsSignatureURL),
 			"shasum":                platform.Shasum,
 			"signing_keys": gin.H{
 				"gpg_public_keys": gpgPublicKeys,
 			},
 		}
+		if w := providerVersion.DeprecationWarning(); w != "" && cfg.Protocol.DeprecationWarnings {
+			response["warnings"] = []string{w}
+		}
 
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+// respondQueryError answers a failed protocol read: 503 with Retry-After when
+// the database connection is still failing after transient.Do's retry,
+// otherwise 500 with msg.
+func respondQueryError(c *gin.Context, err error, msg string) {
+	if transient.IsTransient(err) {
+		c.Header("Retry-After", transient.RetryAfterSeconds)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Database temporarily unavailable",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": msg,
+	})
+}
@@ -0,0 +1,137 @@
+// license.go implements the public endpoints of provider license gating:
+// reading a gated provider's license and recording the caller's acceptance,
+// which the download endpoints then check (see package licensegate).
+package providers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// gatedProvider looks up the provider named by the :namespace and :type path
+// parameters in the default organization, with its enabled license gate.
+// When ok is false the response has been written: 404 when the provider does
+// not exist or is not license-gated.
+func gatedProvider(c *gin.Context, orgRepo *repositories.OrganizationRepository, providerRepo *repositories.ProviderRepository, licenseRepo *repositories.ProviderLicenseRepository) (provider *models.Provider, gate *models.ProviderLicenseGate, ok bool) {
+	org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+	if err != nil || org == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return nil, nil, false
+	}
+
+	provider, err = providerRepo.GetProvider(c.Request.Context(), org.ID, c.Param("namespace"), c.Param("type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query provider"})
+		return nil, nil, false
+	}
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+		return nil, nil, false
+	}
+
+	gate, err = licenseRepo.GetGate(c.Request.Context(), provider.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider license"})
+		return nil, nil, false
+	}
+	if gate == nil || !gate.Enabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider does not require license acceptance"})
+		return nil, nil, false
+	}
+	return provider, gate, true
+}
+
+// @Summary      Get provider license
+// @Description  Returns the license a provider's downloads are gated on, with whether the caller (when authenticated) has accepted its current version. Providers without an enabled license gate return 404.
+// @Tags         Providers
+// @Produce      json
+// @Param        namespace  path  string  true  "Provider namespace"
+// @Param        type       path  string  true  "Provider type"
+// @Success      200  {object}  models.ProviderLicenseResponse
+// @Failure      404  {object}  map[string]interface{}  "Provider not found or not license-gated"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/providers/{namespace}/{type}/license [get]
+// LicenseHandler returns a gated provider's license
+// GET /api/v1/providers/:namespace/:type/license
+func LicenseHandler(db *sql.DB) gin.HandlerFunc {
+	orgRepo := repositories.NewOrganizationRepository(db)
+	providerRepo := repositories.NewProviderRepository(db)
+	licenseRepo := repositories.NewProviderLicenseRepository(db)
+
+	return func(c *gin.Context) {
+		provider, gate, ok := gatedProvider(c, orgRepo, providerRepo, licenseRepo)
+		if !ok {
+			return
+		}
+
+		resp := models.ProviderLicenseResponse{
+			Namespace:      provider.Namespace,
+			Type:           provider.Type,
+			LicenseText:    gate.LicenseText,
+			LicenseVersion: gate.LicenseVersion,
+		}
+		if userID := c.GetString("user_id"); userID != "" {
+			acceptance, err := licenseRepo.GetAcceptance(c.Request.Context(), provider.ID, userID, gate.LicenseVersion)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check license acceptance"})
+				return
+			}
+			if acceptance != nil {
+				resp.Accepted = true
+				resp.AcceptedAt = &acceptance.AcceptedAt
+			}
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// @Summary      Accept provider license
+// @Description  Records that the authenticated user accepts the current version of a provider's license, after which the provider's download and network mirror endpoints serve them. Accepting a version already accepted returns the original record. API keys not bound to a user cannot accept a license.
+// @Tags         Providers
+// @Security     Bearer
+// @Produce      json
+// @Param        namespace  path  string  true  "Provider namespace"
+// @Param        type       path  string  true  "Provider type"
+// @Success      200  {object}  models.ProviderLicenseAcceptance
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Credential is not bound to a user"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found or not license-gated"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/providers/{namespace}/{type}/accept-license [post]
+// AcceptLicenseHandler records the caller's acceptance of a provider's license
+// POST /api/v1/providers/:namespace/:type/accept-license
+func AcceptLicenseHandler(db *sql.DB) gin.HandlerFunc {
+	orgRepo := repositories.NewOrganizationRepository(db)
+	providerRepo := repositories.NewProviderRepository(db)
+	licenseRepo := repositories.NewProviderLicenseRepository(db)
+
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		if userID == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "License acceptance must be recorded against a user; authenticate as a user or with an API key bound to one"})
+			return
+		}
+
+		provider, gate, ok := gatedProvider(c, orgRepo, providerRepo, licenseRepo)
+		if !ok {
+			return
+		}
+
+		ip := c.ClientIP()
+		acceptance := &models.ProviderLicenseAcceptance{
+			ProviderID:     provider.ID,
+			UserID:         userID,
+			LicenseVersion: gate.LicenseVersion,
+			IPAddress:      &ip,
+		}
+		if err := licenseRepo.Accept(c.Request.Context(), acceptance); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record license acceptance"})
+			return
+		}
+		c.JSON(http.StatusOK, acceptance)
+	}
+}
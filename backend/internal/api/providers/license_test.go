@@ -0,0 +1,139 @@
+package providers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/licensegate"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var licenseGateCols = []string{"provider_id", "license_text", "license_version", "enabled", "updated_by", "created_at", "updated_at"}
+
+var licenseAcceptanceCols = []string{"id", "provider_id", "user_id", "license_version", "ip_address", "accepted_at"}
+
+func sampleLicenseGateRow(enabled bool) *sqlmock.Rows {
+	return sqlmock.NewRows(licenseGateCols).
+		AddRow("prov-1", "Internal redistribution terms", "2026-01", enabled, nil, time.Now(), time.Now())
+}
+
+// newLicenseRouter serves the license endpoints, authenticating requests as
+// userID when it is not empty.
+func newLicenseRouter(t *testing.T, userID string) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if userID != "" {
+			c.Set("user_id", userID)
+		}
+	})
+	r.GET("/api/v1/providers/:namespace/:type/license", LicenseHandler(db))
+	r.POST("/api/v1/providers/:namespace/:type/accept-license", AcceptLicenseHandler(db))
+	return mock, r
+}
+
+func TestLicenseHandler_NotGated(t *testing.T) {
+	mock, r := newLicenseRouter(t, "")
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_license_gates").WillReturnRows(sampleLicenseGateRow(false))
+
+	w := doGET(r, "/api/v1/providers/hashicorp/aws/license")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404; body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLicenseHandler_ShowsAcceptance(t *testing.T) {
+	mock, r := newLicenseRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_license_gates").WillReturnRows(sampleLicenseGateRow(true))
+	mock.ExpectQuery("SELECT.*FROM provider_license_acceptances").
+		WithArgs("prov-1", "user-1", "2026-01").
+		WillReturnRows(sqlmock.NewRows(licenseAcceptanceCols).AddRow("acc-1", "prov-1", "user-1", "2026-01", nil, time.Now()))
+
+	w := doGET(r, "/api/v1/providers/hashicorp/aws/license")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var resp models.ProviderLicenseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Accepted || resp.AcceptedAt == nil || resp.LicenseVersion != "2026-01" || resp.LicenseText == "" {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestAcceptLicenseHandler_RequiresUser(t *testing.T) {
+	_, r := newLicenseRouter(t, "")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/providers/hashicorp/aws/accept-license", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403; body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAcceptLicenseHandler_RecordsCurrentVersion(t *testing.T) {
+	mock, r := newLicenseRouter(t, "user-1")
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_license_gates").WillReturnRows(sampleLicenseGateRow(true))
+	mock.ExpectQuery("INSERT INTO provider_license_acceptances").
+		WithArgs("prov-1", "user-1", "2026-01", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ip_address", "accepted_at"}).AddRow("acc-1", "192.0.2.1", time.Now()))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/providers/hashicorp/aws/accept-license", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var resp models.ProviderLicenseAcceptance
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.ID != "acc-1" || resp.LicenseVersion != "2026-01" || resp.UserID != "user-1" {
+		t.Errorf("response = %+v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDownloadHandler_LicenseGateBlocksUnaccepted(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "user-1") })
+	r.GET("/v1/providers/:namespace/:type/:version/download/:os/:arch",
+		DownloadHandler(db, &mockStore{getURLResult: "https://example.com/p.zip"}, &config.Config{}, nil, nil,
+			licensegate.New(repositories.NewProviderLicenseRepository(db))))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_license_gates").WillReturnRows(sampleLicenseGateRow(true))
+	mock.ExpectQuery("SELECT.*FROM provider_license_acceptances").WillReturnRows(sqlmock.NewRows(licenseAcceptanceCols))
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/4.0.0/download/linux/amd64")
+	if w.Code != http.StatusUnavailableForLegalReasons {
+		t.Fatalf("status = %d, want 451; body: %s", w.Code, w.Body.String())
+	}
+	var body map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if body["accept_license_url"] != "/api/v1/providers/hashicorp/aws/accept-license" {
+		t.Errorf("accept_license_url = %v", body["accept_license_url"])
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -56,7 +61,7 @@ func (m *mockStore) GetURL(_ context.Context, _ string, _ time.Duration) (string
 }
 func (m *mockStore) Exists(_ context.Context, _ string) (bool, error) { return true, nil }
 func (m *mockStore) GetMetadata(_ context.Context, _ string) (*storage.FileMetadata, error) {
-	return &storage.FileMetadata{}, nil
+	return &storage.FileMetadata{Size: 1024000, Checksum: "stored", LastModified: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}, nil
 }
 
 var errDB2 = errors.New("db error")
@@ -194,7 +199,7 @@ func newDownloadRouter(t *testing.T, store *mockStore) (sqlmock.Sqlmock, *gin.En
 	db, mock, _ := sqlmock.New()
 	t.Cleanup(func() { db.Close() })
 	r := gin.New()
-	r.GET("/v1/providers/:namespace/:type/:version/download/:os/:arch", DownloadHandler(db, store, &config.Config{}, nil))
+	r.GET("/v1/providers/:namespace/:type/:version/download/:os/:arch", DownloadHandler(db, store, &config.Config{}, nil, nil, nil))
 	return mock, r
 }
 
@@ -432,6 +437,42 @@ func TestDownloadHandler_Success(t *testing.T) {
 	}
 }
 
+// A HEAD describes the platform archive from storage metadata, with the
+// platform's shasum as the ETag, instead of returning the download document.
+func TestDownloadHandler_Head(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.HEAD("/v1/providers/:namespace/:type/:version/download/:os/:arch", DownloadHandler(db, &mockStore{getURLErr: errors.New("no URL for HEAD")}, &config.Config{}, nil, nil, nil))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE provider_id.*AND version").WillReturnRows(sampleProviderVersionGetRow())
+	mock.ExpectQuery("SELECT approval_status FROM mirrored_provider_versions").WillReturnRows(sqlmock.NewRows([]string{"approval_status"}).AddRow(nil))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").WillReturnRows(samplePlatformRow())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodHead, "/v1/providers/hashicorp/aws/4.0.0/download/linux/amd64", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", w.Body.String())
+	}
+	for header, want := range map[string]string{
+		"Content-Length": "1024000",
+		"ETag":           `"sha256abc"`,
+		"Last-Modified":  "Sun, 01 Mar 2026 12:00:00 GMT",
+		"Accept-Ranges":  "bytes",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
 // TestDownloadHandler_PendingApprovalHidden verifies the approval gate: a
 // mirrored version still pending approval is not downloadable by direct version
 // reference and returns 404 (same as a missing version), before any platform
@@ -493,7 +534,7 @@ func makeValidZIP(t *testing.T) []byte {
 	t.Helper()
 	var buf bytes.Buffer
 	zw := zip.NewWriter(&buf)
-	w, err := zw.Create("terraform-provider-test_v1.0.0_linux_amd64")
+	w, err := zw.Create("terraform-provider-aws_v1.0.0")
 	if err != nil {
 		t.Fatalf("zip.Create: %v", err)
 	}
@@ -521,6 +562,20 @@ func buildUploadRequestWithFiles(
 	fields map[string]string,
 	fileData []byte,
 	extraFiles map[string][]byte,
+) *http.Request {
+	t.Helper()
+	return buildNamedUploadRequest(t, path, fields, "provider.zip", fileData, extraFiles)
+}
+
+// buildNamedUploadRequest is buildUploadRequestWithFiles with the binary
+// uploaded under filename.
+func buildNamedUploadRequest(
+	t *testing.T,
+	path string,
+	fields map[string]string,
+	filename string,
+	fileData []byte,
+	extraFiles map[string][]byte,
 ) *http.Request {
 	t.Helper()
 	var body bytes.Buffer
@@ -531,7 +586,7 @@ func buildUploadRequestWithFiles(
 		}
 	}
 	if fileData != nil {
-		fw, err := mw.CreateFormFile("file", "provider.zip")
+		fw, err := mw.CreateFormFile("file", filename)
 		if err != nil {
 			t.Fatalf("CreateFormFile: %v", err)
 		}
@@ -558,7 +613,7 @@ func newUploadRouter(t *testing.T, store *mockStore) (sqlmock.Sqlmock, *gin.Engi
 	db, mock, _ := sqlmock.New()
 	t.Cleanup(func() { db.Close() })
 	r := gin.New()
-	r.POST("/v1/providers", UploadHandler(db, store, &config.Config{}))
+	r.POST("/v1/providers", UploadHandler(db, store, &config.Config{}, nil, nil))
 	return mock, r
 }
 
@@ -806,6 +861,164 @@ func TestUploadHandler_PlatformConflict(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// UploadHandler — version and platform from the archive name
+// ---------------------------------------------------------------------------
+
+func TestUploadHandler_PlatformFromFilename(t *testing.T) {
+	for _, tt := range []struct {
+		filename, version, os, arch string
+	}{
+		{"terraform-provider-aws_4.0.0_darwin_arm64.zip", "4.0.0", "darwin", "arm64"},
+		{"terraform-provider-aws_4.1.0_windows_386.zip", "4.1.0", "windows", "386"},
+	} {
+		t.Run(tt.filename, func(t *testing.T) {
+			mock, r := newUploadRouter(t, &mockStore{})
+			uploadHappyPathExpectations(mock)
+			mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").
+				WithArgs("ver-new", tt.os, tt.arch).
+				WillReturnRows(sqlmock.NewRows(platformCols))
+			mock.ExpectQuery("INSERT INTO provider_platforms").
+				WillReturnRows(sqlmock.NewRows(platformInsertCols).AddRow("plat-new"))
+
+			req := buildNamedUploadRequest(t, "/v1/providers", map[string]string{
+				"namespace": "hashicorp",
+				"type":      "aws",
+			}, tt.filename, makeValidZIP(t), nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+			}
+			var resp map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if resp["version"] != tt.version || resp["os"] != tt.os || resp["arch"] != tt.arch {
+				t.Errorf("response = %v, want version %s on %s_%s", resp, tt.version, tt.os, tt.arch)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUploadHandler_FilenameMismatch(t *testing.T) {
+	_, r := newUploadRouter(t, &mockStore{})
+
+	req := buildNamedUploadRequest(t, "/v1/providers", map[string]string{
+		"namespace": "hashicorp",
+		"type":      "aws",
+		"os":        "linux",
+	}, "terraform-provider-aws_4.0.0_darwin_arm64.zip", makeValidZIP(t), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409 (os disagrees with filename): body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestUploadHandler_FilenameTypeMismatch(t *testing.T) {
+	_, r := newUploadRouter(t, &mockStore{})
+
+	req := buildNamedUploadRequest(t, "/v1/providers", map[string]string{
+		"namespace": "hashicorp",
+		"type":      "aws",
+	}, "terraform-provider-google_1.2.3_linux_amd64.zip", makeValidZIP(t), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409 (type disagrees with filename): body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestUploadHandler_MalformedFilenameWithoutPlatform(t *testing.T) {
+	for _, filename := range []string{
+		"provider.zip",
+		"terraform-provider-aws_4.0.0_darwin.zip",
+		"terraform-provider-aws_4.0.0_darwin_arm64.tar.gz",
+	} {
+		t.Run(filename, func(t *testing.T) {
+			_, r := newUploadRouter(t, &mockStore{})
+
+			req := buildNamedUploadRequest(t, "/v1/providers", map[string]string{
+				"namespace": "hashicorp",
+				"type":      "aws",
+			}, filename, makeValidZIP(t), nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400 (no version or platform): body=%s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// UploadHandler — publish from URL (JSON body)
+// ---------------------------------------------------------------------------
+
+func newRemoteUploadRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	cfg := &config.Config{}
+	cfg.RemoteUpload = config.RemoteUploadConfig{Enabled: true, AllowedSchemes: []string{"http"}}
+	cfg.Security.Egress.Allowlist = []string{"127.0.0.1"} // the httptest server
+	r := gin.New()
+	r.POST("/v1/providers", UploadHandler(db, &mockStore{}, cfg, nil, nil))
+	return mock, r
+}
+
+func remoteUploadRequest(sourceURL, checksum string) *http.Request {
+	body := `{"namespace":"hashicorp","type":"aws","version":"4.0.0","os":"linux","arch":"amd64",` +
+		`"protocols":["5.0","6.0"],"source_url":"` + sourceURL + `","checksum":"` + checksum + `"}`
+	req, _ := http.NewRequest(http.MethodPost, "/v1/providers", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestUploadHandler_Remote_ChecksumMismatch(t *testing.T) {
+	zip := makeValidZIP(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(zip)
+	}))
+	defer srv.Close()
+	_, r := newRemoteUploadRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, remoteUploadRequest(srv.URL+"/provider.zip", strings.Repeat("0", 64)))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422 (checksum mismatch): body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestUploadHandler_Remote_Success(t *testing.T) {
+	zip := makeValidZIP(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(zip)
+	}))
+	defer srv.Close()
+	mock, r := newRemoteUploadRouter(t)
+
+	uploadHappyPathExpectations(mock)
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").
+		WillReturnRows(sqlmock.NewRows(platformCols))
+	mock.ExpectQuery("INSERT INTO provider_platforms").
+		WillReturnRows(sqlmock.NewRows(platformInsertCols).AddRow("plat-new"))
+
+	sum := sha256.Sum256(zip)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, remoteUploadRequest(srv.URL+"/terraform-provider-aws_4.0.0_linux_amd64.zip", hex.EncodeToString(sum[:])))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: body=%s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // UploadHandler — SHA256SUMS / signature handling (issue #404)
 // ---------------------------------------------------------------------------
@@ -828,9 +1041,8 @@ func uploadHappyPathExpectations(mock sqlmock.Sqlmock) {
 func TestUploadHandler_RejectsSignatureWithoutSums(t *testing.T) {
 	store := &mockStore{}
 	mock, r := newUploadRouter(t, store)
-	uploadHappyPathExpectations(mock)
-	// Note: no platform-query / insert-platform expectations — the request
-	// must be rejected before that point.
+	// The signature files are checked before anything is written.
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow())
 
 	// No gpg_public_key supplied — the validator skips key parsing entirely
 	// so the request reaches the signature-file pairing check.
@@ -851,12 +1063,15 @@ func TestUploadHandler_RejectsSignatureWithoutSums(t *testing.T) {
 	if !strings.Contains(w.Body.String(), "shasums_signature_file requires shasums_file") {
 		t.Errorf("body should explain the missing companion file; got: %s", w.Body.String())
 	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
 }
 
 func TestUploadHandler_RejectsSignatureWithoutGPGKey(t *testing.T) {
 	store := &mockStore{}
 	mock, r := newUploadRouter(t, store)
-	uploadHappyPathExpectations(mock)
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow())
 
 	req := buildUploadRequestWithFiles(t, "/v1/providers", map[string]string{
 		"namespace": "hashicorp",
@@ -877,6 +1092,143 @@ func TestUploadHandler_RejectsSignatureWithoutGPGKey(t *testing.T) {
 	if !strings.Contains(w.Body.String(), "requires gpg_public_key") {
 		t.Errorf("body should explain the missing gpg key; got: %s", w.Body.String())
 	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUploadHandler_DryRun_NewProvider(t *testing.T) {
+	store := &mockStore{uploadErr: errors.New("dry run must not upload")}
+	mock, r := newUploadRouter(t, store)
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow())
+	// GetProvider → not found; nothing is inserted
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sqlmock.NewRows(providerCols))
+
+	req := buildUploadRequestWithFiles(t, "/v1/providers?dry_run=true", map[string]string{
+		"namespace": "hashicorp",
+		"type":      "aws",
+		"version":   "4.0.0",
+		"os":        "linux",
+		"arch":      "amd64",
+	}, makeValidZIP(t), map[string][]byte{
+		"shasums_file": []byte("abc123def  terraform-provider-aws_4.0.0_linux_amd64.zip\n"),
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp ProviderUploadDryRunResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.DryRun || resp.ProviderExists || resp.VersionExists || !resp.ShasumsFile || resp.Checksum == "" || resp.H1Hash == "" {
+		t.Errorf("response = %+v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUploadHandler_DryRun_PlatformConflict(t *testing.T) {
+	mock, r := newUploadRouter(t, &mockStore{})
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE provider_id.*AND version").
+		WillReturnRows(sampleProviderVersionGetRow())
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").
+		WillReturnRows(samplePlatformRow())
+
+	req := buildUploadRequest(t, "/v1/providers?dry_run=true", map[string]string{
+		"namespace": "hashicorp",
+		"type":      "aws",
+		"version":   "4.0.0",
+		"os":        "linux",
+		"arch":      "amd64",
+	}, makeValidZIP(t))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409 as a real publish: body=%s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// makeProviderZIP creates a ZIP file in memory holding the named entries.
+func makeProviderZIP(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip.Create: %v", err)
+		}
+		w.Write([]byte("provider binary content"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip.Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadHandler_AddressMismatch(t *testing.T) {
+	mock, r := newUploadRouter(t, &mockStore{})
+
+	req := buildUploadRequest(t, "/v1/providers", map[string]string{
+		"namespace": "acme",
+		"type":      "aws",
+		"version":   "4.0.0",
+		"os":        "linux",
+		"arch":      "amd64",
+	}, makeProviderZIP(t, "terraform-provider-google_v4.0.0_x5"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Discrepancies []string `json:"discrepancies"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Discrepancies) != 1 || !strings.Contains(resp.Discrepancies[0], `"google"`) {
+		t.Errorf("discrepancies = %v", resp.Discrepancies)
+	}
+	// Nothing was looked up or written.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUploadHandler_AddressMismatchAllowed(t *testing.T) {
+	mock, r := newUploadRouter(t, &mockStore{})
+	mock.ExpectQuery("SELECT.*FROM organizations").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sqlmock.NewRows(providerCols))
+
+	req := buildUploadRequest(t, "/v1/providers?dry_run=true", map[string]string{
+		"namespace":              "acme",
+		"type":                   "aws",
+		"version":                "4.0.0",
+		"os":                     "linux",
+		"arch":                   "amd64",
+		"allow_address_mismatch": "true",
+	}, makeProviderZIP(t, "terraform-provider-awsfork_v4.0.0"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	var resp ProviderUploadDryRunResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "allow_address_mismatch") {
+		t.Errorf("warnings = %v, want the allowed mismatch", resp.Warnings)
+	}
 }
 
 // NOTE: GPG verification-failure path is covered exhaustively in
@@ -1355,6 +1707,83 @@ func TestDownloadHandler_SuccessWithStorageKeys(t *testing.T) {
 	}
 }
 
+// With the storage consistency checker enabled, a platform marked broken is
+// refused with 410 instead of a URL to its missing archive.
+func TestDownloadHandler_MarkedBroken(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	cfg := &config.Config{StorageConsistency: config.StorageConsistencyConfig{Enabled: true}}
+	r.GET("/v1/providers/:namespace/:type/:version/download/:os/:arch", DownloadHandler(db, &mockStore{getURLResult: "https://example.com/p.zip"}, cfg, nil, nil, nil))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE provider_id.*AND version").WillReturnRows(sampleProviderVersionGetRow())
+	mock.ExpectQuery("SELECT approval_status FROM mirrored_provider_versions").WillReturnRows(sqlmock.NewRows([]string{"approval_status"}).AddRow(nil))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").WillReturnRows(samplePlatformRow())
+	mock.ExpectQuery("SELECT EXISTS.*FROM storage_consistency_findings").
+		WithArgs("provider_platform", "plat-1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/4.0.0/download/linux/amd64")
+	if w.Code != http.StatusGone {
+		t.Errorf("status = %d, want 410; body: %s", w.Code, w.Body.String())
+	}
+}
+
+// With mirror_signing enabled, a re-signed mirrored version is served with the
+// registry's SHA256SUMS, signature and key instead of the upstream ones.
+func TestDownloadHandler_ServesResignature(t *testing.T) {
+	const presignedURL = "https://storage.example.com/resigned"
+	store := &mockStore{getURLResult: presignedURL}
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	cfg := &config.Config{MirrorSigning: config.MirrorSigningConfig{Enabled: true}}
+	r.GET("/v1/providers/:namespace/:type/:version/download/:os/:arch", DownloadHandler(db, store, cfg, nil, nil, nil))
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE provider_id.*AND version").WillReturnRows(
+		sqlmock.NewRows(providerVersionGetCols).
+			AddRow("ver-1", "prov-1", "4.0.0", sampleProtocolsJSON, "UPSTREAM KEY",
+				"https://example.com/shasums", "https://example.com/shasums.sig",
+				nil, nil,
+				nil, false, nil, nil, time.Now()),
+	)
+	mock.ExpectQuery("SELECT approval_status FROM mirrored_provider_versions").WillReturnRows(sqlmock.NewRows([]string{"approval_status"}).AddRow(nil))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").
+		WillReturnRows(samplePlatformRow())
+	mock.ExpectQuery("SELECT.*FROM provider_version_resignatures").WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"provider_version_id", "key_id", "gpg_public_key", "shasum_storage_key", "shasum_signature_storage_key", "signed_at"}).
+			AddRow("ver-1", "0123456789ABCDEF", "REGISTRY KEY", "sums", "sums.sig", time.Now()))
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/4.0.0/download/linux/amd64")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		ShasumsURL          string `json:"shasums_url"`
+		ShasumsSignatureURL string `json:"shasums_signature_url"`
+		SigningKeys         struct {
+			GPGPublicKeys []struct {
+				KeyID      string `json:"key_id"`
+				ASCIIArmor string `json:"ascii_armor"`
+			} `json:"gpg_public_keys"`
+		} `json:"signing_keys"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.ShasumsURL != presignedURL || resp.ShasumsSignatureURL != presignedURL {
+		t.Errorf("shasums URLs = %q / %q, want registry storage URLs", resp.ShasumsURL, resp.ShasumsSignatureURL)
+	}
+	if len(resp.SigningKeys.GPGPublicKeys) != 1 || resp.SigningKeys.GPGPublicKeys[0].KeyID != "0123456789ABCDEF" ||
+		resp.SigningKeys.GPGPublicKeys[0].ASCIIArmor != "REGISTRY KEY" {
+		t.Errorf("signing_keys = %+v, want the registry key", resp.SigningKeys)
+	}
+}
+
 func TestDownloadHandler_SuccessWithAuditContext(t *testing.T) {
 	store := &mockStore{getURLResult: "https://example.com/provider.zip"}
 	db, mock, _ := sqlmock.New()
@@ -1370,7 +1799,7 @@ func TestDownloadHandler_SuccessWithAuditContext(t *testing.T) {
 		c.Next()
 	})
 	r.GET("/v1/providers/:namespace/:type/:version/download/:os/:arch",
-		DownloadHandler(db, store, &config.Config{}, auditRepo))
+		DownloadHandler(db, store, &config.Config{}, auditRepo, nil, nil))
 
 	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
 	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
@@ -1387,3 +1816,46 @@ func TestDownloadHandler_SuccessWithAuditContext(t *testing.T) {
 	// Give async goroutines a moment to fire (best-effort)
 	time.Sleep(50 * time.Millisecond)
 }
+
+// ---------------------------------------------------------------------------
+// Transient database errors
+// ---------------------------------------------------------------------------
+
+// errConnDropped simulates the database closing the connection mid-request.
+var errConnDropped = &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+func TestDownloadHandler_RetriesDroppedConnection(t *testing.T) {
+	store := &mockStore{getURLResult: "https://example.com/provider.zip"}
+	mock, r := newDownloadRouter(t, store)
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE provider_id.*AND version").WillReturnRows(sampleProviderVersionGetRow())
+	mock.ExpectQuery("SELECT approval_status FROM mirrored_provider_versions").WillReturnRows(sqlmock.NewRows([]string{"approval_status"}).AddRow(nil))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").WillReturnError(errConnDropped)
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").WillReturnRows(samplePlatformRow())
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/4.0.0/download/linux/amd64")
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestListVersionsHandler_PersistentDroppedConnection(t *testing.T) {
+	mock, r := newVersionsRouter(t)
+
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnError(errConnDropped)
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnError(errConnDropped)
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/versions")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+}
@@ -0,0 +1,203 @@
+// resolve.go implements server-side version constraint resolution for
+// providers: the best version matching a constraint, with its platforms, in
+// one response, for tooling that would otherwise fetch the whole versions
+// list and resolve the constraint itself.
+package providers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/db/transient"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// closestVersionsLimit is how many alternatives a failed resolution suggests.
+const closestVersionsLimit = 5
+
+// @Summary      Resolve provider version constraint
+// @Description  Returns the newest version of a provider matching constraint (any version when omitted), with its protocols and platforms, each with the Provider Registry download path that returns its download URL, checksums and signing keys. With os and arch, only versions that ship that platform are considered and only it is returned. Versions hidden from the versions listing (mirrored versions pending or rejected under approval) are never resolved, deprecated versions only with include_deprecated=true, and pre-releases only when the constraint names one exactly (= 2.0.0-beta1) or include_prerelease=true. When nothing matches, 404 lists the closest available versions, and the matching versions that lack the requested platform.
+// @Tags         Providers
+// @Produce      json
+// @Param        namespace           path   string  true   "Provider namespace"
+// @Param        type                path   string  true   "Provider type (e.g. aws, azurerm)"
+// @Param        constraint          query  string  false  "Version constraint (e.g. ~> 5.0, >= 1.2, < 2.0)"
+// @Param        os                  query  string  false  "Target OS (e.g. linux); requires arch"
+// @Param        arch                query  string  false  "Target architecture (e.g. amd64); requires os"
+// @Param        include_prerelease  query  bool    false  "Let pre-release versions match by their release version (default false)"
+// @Param        include_deprecated  query  bool    false  "Let deprecated versions match (default false)"
+// @Success      200  {object}  providers.ProviderResolveResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid constraint or platform"
+// @Failure      404  {object}  providers.ProviderResolveNotFoundResponse  "Provider not found, or no version matches"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      503  {object}  map[string]interface{}  "Database temporarily unavailable (Retry-After set)"
+// @Router       /v1/providers/{namespace}/{type}/resolve [get]
+// ResolveHandler resolves a version constraint to the best matching version
+// Implements: GET /v1/providers/:namespace/:type/resolve
+func ResolveHandler(db *sql.DB) gin.HandlerFunc {
+	providerRepo := repositories.NewProviderRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+
+	return func(c *gin.Context) {
+		namespace := c.Param("namespace")
+		providerType := c.Param("type")
+		constraint := c.Query("constraint")
+		os, arch := c.Query("os"), c.Query("arch")
+		includePre, _ := strconv.ParseBool(c.Query("include_prerelease"))
+		includeDeprecated, _ := strconv.ParseBool(c.Query("include_deprecated"))
+
+		if constraint != "" {
+			if err := validation.ValidateVersionConstraint(constraint); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"errors": []string{err.Error()},
+				})
+				return
+			}
+		}
+		if (os == "") != (arch == "") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{"os and arch must be given together"},
+			})
+			return
+		}
+		if os != "" {
+			if err := validation.ValidatePlatform(os, arch); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"errors": []string{err.Error()},
+				})
+				return
+			}
+		}
+
+		org, err := transient.Value(c.Request.Context(), orgRepo.GetDefaultOrganization)
+		if err != nil {
+			respondQueryError(c, err, "Failed to get organization context")
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Default organization not found - please run migrations",
+			})
+			return
+		}
+
+		provider, err := transient.Value(c.Request.Context(), func(ctx context.Context) (*models.Provider, error) {
+			return providerRepo.GetProvider(ctx, org.ID, namespace, providerType)
+		})
+		if err != nil {
+			respondQueryError(c, err, "Failed to query provider")
+			return
+		}
+		if provider == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"errors": []string{"Provider not found"},
+			})
+			return
+		}
+
+		// The same versions the protocol listing shows: mirrored versions
+		// pending or rejected under approval are hidden.
+		versions, err := transient.Value(c.Request.Context(), func(ctx context.Context) ([]*models.ProviderVersion, error) {
+			return providerRepo.ListVisibleVersions(ctx, provider.ID)
+		})
+		if err != nil {
+			respondQueryError(c, err, "Failed to list provider versions")
+			return
+		}
+
+		byVersion := make(map[string]*models.ProviderVersion, len(versions))
+		var eligible []string
+		for _, v := range versions {
+			if v.Deprecated && !includeDeprecated {
+				continue
+			}
+			byVersion[v.Version] = v
+			eligible = append(eligible, v.Version)
+		}
+
+		matches, err := validation.MatchingVersions(eligible, constraint, includePre)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		// Newest match first; with a platform requested, the first that ships it.
+		var missingPlatform []string
+		for _, match := range matches {
+			v := byVersion[match]
+			platforms, err := transient.Value(c.Request.Context(), func(ctx context.Context) ([]*models.ProviderPlatform, error) {
+				return providerRepo.ListPlatforms(ctx, v.ID)
+			})
+			if err != nil {
+				respondQueryError(c, err, "Failed to list provider platforms")
+				return
+			}
+
+			resolved := make([]ProviderResolvedPlatform, 0, len(platforms))
+			for _, p := range platforms {
+				if os != "" && (p.OS != os || p.Arch != arch) {
+					continue
+				}
+				resolved = append(resolved, ProviderResolvedPlatform{
+					OS:           p.OS,
+					Arch:         p.Arch,
+					Filename:     p.Filename,
+					Shasum:       p.Shasum,
+					H1Hash:       p.H1Hash,
+					SizeBytes:    p.SizeBytes,
+					DownloadPath: fmt.Sprintf("/v1/providers/%s/%s/%s/download/%s/%s", provider.Namespace, provider.Type, v.Version, p.OS, p.Arch),
+				})
+			}
+			if os != "" && len(resolved) == 0 {
+				missingPlatform = append(missingPlatform, v.Version)
+				continue
+			}
+
+			resp := ProviderResolveResponse{
+				Namespace:   provider.Namespace,
+				Type:        provider.Type,
+				Constraint:  constraint,
+				Version:     v.Version,
+				Protocols:   v.Protocols,
+				PublishedAt: v.CreatedAt.UTC(),
+				Prerelease:  validation.IsPrerelease(v.Version),
+				Deprecated:  v.Deprecated,
+				Platforms:   resolved,
+			}
+			if v.Deprecated {
+				resp.Deprecation = &VersionDeprecation{DeprecatedAt: v.DeprecatedAt, Message: v.DeprecationMessage}
+			}
+			c.JSON(http.StatusOK, resp)
+			return
+		}
+
+		// Suggest the versions the caller could have matched, under the same
+		// pre-release rule.
+		var candidates []string
+		for _, s := range eligible {
+			if includePre || !validation.IsPrerelease(s) {
+				candidates = append(candidates, s)
+			}
+		}
+		message := fmt.Sprintf("No version of %s/%s matches constraint %q", provider.Namespace, provider.Type, constraint)
+		if constraint == "" {
+			message = fmt.Sprintf("No version of %s/%s is available", provider.Namespace, provider.Type)
+		}
+		if len(missingPlatform) > 0 {
+			message = fmt.Sprintf("No version of %s/%s matching constraint %q has a %s_%s platform", provider.Namespace, provider.Type, constraint, os, arch)
+		}
+		c.JSON(http.StatusNotFound, ProviderResolveNotFoundResponse{
+			Errors:          []string{message},
+			ClosestVersions: validation.ClosestVersions(candidates, constraint, closestVersionsLimit),
+			MissingPlatform: missingPlatform,
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package providers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func newResolveRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.GET("/v1/providers/:namespace/:type/resolve", ResolveHandler(db))
+	return mock, r
+}
+
+// resolveVersionRows lists versions in the order given; deprecated names the
+// ones to mark deprecated.
+func resolveVersionRows(versions []string, deprecated ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(providerVersionListCols)
+	for _, v := range versions {
+		dep := false
+		for _, d := range deprecated {
+			dep = dep || d == v
+		}
+		rows.AddRow("ver-"+v, "prov-1", v, sampleProtocolsJSON, "", "", "", nil, nil, nil, nil, dep, nil, nil, time.Now())
+	}
+	return rows
+}
+
+func resolvePlatformRows(versionID string, platforms ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(platformCols)
+	for _, p := range platforms {
+		os, arch, _ := strings.Cut(p, "/")
+		rows.AddRow("plat-"+p, versionID, os, arch, "terraform-provider-aws_"+os+"_"+arch+".zip",
+			"providers/x.zip", "local", int64(1024), "abc123", nil, int64(0))
+	}
+	return rows
+}
+
+func expectResolveProvider(mock sqlmock.Sqlmock, versions *sqlmock.Rows) {
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT.*FROM provider_versions").WillReturnRows(versions)
+}
+
+func TestResolveHandler_NewestMatchWithPlatform(t *testing.T) {
+	mock, r := newResolveRouter(t)
+	expectResolveProvider(mock, resolveVersionRows([]string{"6.0.0", "5.2.0", "5.1.0", "5.3.0"}, "5.3.0"))
+	// 5.3.0 is deprecated and 6.0.0 out of range; 5.2.0 lacks linux/amd64.
+	mock.ExpectQuery("SELECT.*FROM provider_platforms").WithArgs("ver-5.2.0").
+		WillReturnRows(resolvePlatformRows("ver-5.2.0", "darwin/arm64"))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms").WithArgs("ver-5.1.0").
+		WillReturnRows(resolvePlatformRows("ver-5.1.0", "darwin/arm64", "linux/amd64"))
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/resolve?constraint=~>5.0&os=linux&arch=amd64")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var resp ProviderResolveResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Version != "5.1.0" || len(resp.Platforms) != 1 || resp.Platforms[0].OS != "linux" {
+		t.Fatalf("response = %+v", resp)
+	}
+	if want := "/v1/providers/hashicorp/aws/5.1.0/download/linux/amd64"; resp.Platforms[0].DownloadPath != want {
+		t.Errorf("download_path = %q, want %q", resp.Platforms[0].DownloadPath, want)
+	}
+	if len(resp.Protocols) != 1 || resp.Protocols[0] != "6.0" {
+		t.Errorf("protocols = %v", resp.Protocols)
+	}
+}
+
+func TestResolveHandler_IncludeDeprecated(t *testing.T) {
+	mock, r := newResolveRouter(t)
+	expectResolveProvider(mock, resolveVersionRows([]string{"5.1.0", "5.3.0"}, "5.3.0"))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms").WithArgs("ver-5.3.0").
+		WillReturnRows(resolvePlatformRows("ver-5.3.0", "linux/amd64", "darwin/arm64"))
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/resolve?constraint=~>5.0&include_deprecated=true")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var resp ProviderResolveResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Version != "5.3.0" || !resp.Deprecated || resp.Deprecation == nil || len(resp.Platforms) != 2 {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestResolveHandler_PrereleaseOnlyWhenNamed(t *testing.T) {
+	mock, r := newResolveRouter(t)
+	expectResolveProvider(mock, resolveVersionRows([]string{"6.0.0-beta1", "5.1.0"}))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms").WithArgs("ver-5.1.0").
+		WillReturnRows(resolvePlatformRows("ver-5.1.0", "linux/amd64"))
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/resolve")
+	var resp ProviderResolveResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Version != "5.1.0" {
+		t.Fatalf("status = %d, version = %q; want 200 with 5.1.0", w.Code, resp.Version)
+	}
+
+	mock, r = newResolveRouter(t)
+	expectResolveProvider(mock, resolveVersionRows([]string{"6.0.0-beta1", "5.1.0"}))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms").WithArgs("ver-6.0.0-beta1").
+		WillReturnRows(resolvePlatformRows("ver-6.0.0-beta1", "linux/amd64"))
+
+	w = doGET(r, "/v1/providers/hashicorp/aws/resolve?constraint=%3D6.0.0-beta1")
+	resp = ProviderResolveResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Version != "6.0.0-beta1" || !resp.Prerelease {
+		t.Fatalf("status = %d, response = %+v; want the named pre-release", w.Code, resp)
+	}
+}
+
+func TestResolveHandler_NoMatchListsClosest(t *testing.T) {
+	mock, r := newResolveRouter(t)
+	expectResolveProvider(mock, resolveVersionRows([]string{"6.1.0", "6.0.0", "4.67.0", "4.66.0"}))
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/resolve?constraint=~>5.0")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404; body: %s", w.Code, w.Body.String())
+	}
+	var resp ProviderResolveNotFoundResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if strings.Join(resp.ClosestVersions, ",") != "6.1.0,6.0.0,4.67.0,4.66.0" || len(resp.MissingPlatform) != 0 {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestResolveHandler_NoMatchForPlatform(t *testing.T) {
+	mock, r := newResolveRouter(t)
+	expectResolveProvider(mock, resolveVersionRows([]string{"5.1.0"}))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms").WithArgs("ver-5.1.0").
+		WillReturnRows(resolvePlatformRows("ver-5.1.0", "darwin/arm64"))
+
+	w := doGET(r, "/v1/providers/hashicorp/aws/resolve?constraint=~>5.0&os=linux&arch=amd64")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404; body: %s", w.Code, w.Body.String())
+	}
+	var resp ProviderResolveNotFoundResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.MissingPlatform) != 1 || resp.MissingPlatform[0] != "5.1.0" || !strings.Contains(resp.Errors[0], "linux_amd64") {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestResolveHandler_BadRequests(t *testing.T) {
+	_, r := newResolveRouter(t)
+	for _, q := range []string{"constraint=newest", "os=linux", "os=plan9&arch=amd64"} {
+		if w := doGET(r, "/v1/providers/hashicorp/aws/resolve?"+q); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, w.Code)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package providers
 
+import (
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
+)
+
 // ProviderUploadResponse is returned by POST /api/v1/providers.
 type ProviderUploadResponse struct {
 	ID        string   `json:"id"`
@@ -14,6 +20,34 @@ type ProviderUploadResponse struct {
 	Filename  string   `json:"filename"`
 }
 
+// ProviderUploadDryRunResponse is returned by POST
+// /api/v1/providers?dry_run=true when the upload passes every check: what
+// would have been published.
+type ProviderUploadDryRunResponse struct {
+	DryRun    bool     `json:"dry_run"`
+	Namespace string   `json:"namespace"`
+	Type      string   `json:"type"`
+	Version   string   `json:"version"`
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+	Protocols []string `json:"protocols"`
+	// Checksum is the SHA-256 (hex) of the binary.
+	Checksum  string `json:"checksum"`
+	SizeBytes int64  `json:"size_bytes"`
+	Filename  string `json:"filename"`
+	H1Hash    string `json:"h1_hash,omitempty"`
+	// ProviderExists and VersionExists are false when the publish would
+	// create the provider or the version.
+	ProviderExists bool `json:"provider_exists"`
+	VersionExists  bool `json:"version_exists"`
+	// ShasumsFile and ShasumsSignatureFile report the SHA256SUMS files that
+	// would be stored; a signature has already been verified.
+	ShasumsFile          bool `json:"shasums_file"`
+	ShasumsSignatureFile bool `json:"shasums_signature_file"`
+	// Warnings lists problems that would not stop the publish.
+	Warnings []string `json:"warnings"`
+}
+
 // ProviderPlatformEntry represents a single platform in the provider versions list response.
 type ProviderPlatformEntry struct {
 	ID            string `json:"id"`
@@ -40,6 +74,9 @@ type ProviderVersionEntry struct {
 // ProviderVersionsResponse is returned by GET /v1/providers/{namespace}/{type}/versions.
 type ProviderVersionsResponse struct {
 	Versions []ProviderVersionEntry `json:"versions"`
+	// Warnings lists deprecated versions; sent only when
+	// protocol.deprecation_warnings is enabled.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // ProviderSearchItem represents a single provider result in search responses.
@@ -51,19 +88,19 @@ type ProviderSearchItem struct {
 	Source        string `json:"source,omitempty"`
 	LatestVersion string `json:"latest_version,omitempty"`
 	DownloadCount int64  `json:"download_count"`
+	// Tier is the registry-assigned tier; omitted when it could not be loaded.
+	Tier string `json:"tier,omitempty"`
+	// SourceURL, UpstreamTier and License are recorded by mirror sync from the
+	// upstream registry; they are absent for uploaded providers.
+	SourceURL    string `json:"source_url,omitempty"`
+	UpstreamTier string `json:"upstream_tier,omitempty"`
+	License      string `json:"license,omitempty"`
 }
 
-// ProviderSearchMeta carries pagination info for provider search responses.
-type ProviderSearchMeta struct {
-	Limit  int   `json:"limit"`
-	Offset int   `json:"offset"`
-	Total  int64 `json:"total"`
-} // @name PaginationMetadata
-
 // ProviderSearchResponse is returned by GET /api/v1/providers/search.
 type ProviderSearchResponse struct {
 	Providers []ProviderSearchItem `json:"providers"`
-	Meta      ProviderSearchMeta   `json:"meta"`
+	Meta      pagination.Meta      `json:"meta"`
 }
 
 // ProviderSigningKeys holds GPG public key info for provider download responses.
@@ -88,4 +125,95 @@ type ProviderDownloadResponse struct {
 	ShasumsSignatureURL string               `json:"shasums_signature_url"`
 	Shasum              string               `json:"shasum"`
 	SigningKeys         *ProviderSigningKeys `json:"signing_keys,omitempty"`
+	// Warnings carries the version's deprecation notice; sent only when
+	// protocol.deprecation_warnings is enabled.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// VersionPublisher identifies who published a version.
+type VersionPublisher struct {
+	ID   string  `json:"id"`
+	Name *string `json:"name,omitempty"`
+}
+
+// VersionDeprecation describes a deprecated version in extended documents.
+type VersionDeprecation struct {
+	DeprecatedAt *time.Time `json:"deprecated_at,omitempty"`
+	Message      *string    `json:"message,omitempty"`
+}
+
+// ProviderPlatformExtended is one platform binary in the extended version listing.
+type ProviderPlatformExtended struct {
+	OS            string  `json:"os"`
+	Arch          string  `json:"arch"`
+	Filename      string  `json:"filename"`
+	Shasum        string  `json:"shasum"`
+	H1Hash        *string `json:"h1_hash,omitempty"`
+	SizeBytes     int64   `json:"size_bytes"`
+	DownloadCount int64   `json:"download_count"`
+}
+
+// ProviderVersionExtended is one version in the extended version listing.
+type ProviderVersionExtended struct {
+	ID            string                     `json:"id"`
+	Version       string                     `json:"version"`
+	Protocols     []string                   `json:"protocols"`
+	Platforms     []ProviderPlatformExtended `json:"platforms"`
+	PublishedAt   time.Time                  `json:"published_at"`
+	PublishedBy   *VersionPublisher          `json:"published_by,omitempty"`
+	DownloadCount int64                      `json:"download_count"`
+	Mirrored      bool                       `json:"mirrored"`
+	Deprecated    bool                       `json:"deprecated"`
+	Deprecation   *VersionDeprecation        `json:"deprecation,omitempty"`
+}
+
+// ProviderVersionsExtendedResponse is returned by
+// GET /v1/providers/{namespace}/{type}/versions when the request sends
+// `Accept: application/vnd.tfr.v1+json`.
+type ProviderVersionsExtendedResponse struct {
+	Namespace string                    `json:"namespace"`
+	Type      string                    `json:"type"`
+	Tier      string                    `json:"tier,omitempty"`
+	Versions  []ProviderVersionExtended `json:"versions"`
+	Total     int                       `json:"total"`
+	Limit     int                       `json:"limit"`
+	Offset    int                       `json:"offset"`
+}
+
+// ProviderResolvedPlatform is one platform of a resolved version. DownloadPath
+// is the Provider Registry download endpoint that returns its download URL,
+// checksums and signing keys.
+type ProviderResolvedPlatform struct {
+	OS           string  `json:"os"`
+	Arch         string  `json:"arch"`
+	Filename     string  `json:"filename"`
+	Shasum       string  `json:"shasum"`
+	H1Hash       *string `json:"h1_hash,omitempty"`
+	SizeBytes    int64   `json:"size_bytes"`
+	DownloadPath string  `json:"download_path"`
+}
+
+// ProviderResolveResponse is returned by GET /v1/providers/{namespace}/{type}/resolve.
+// Platforms holds only the requested platform when os and arch are given.
+type ProviderResolveResponse struct {
+	Namespace   string                     `json:"namespace"`
+	Type        string                     `json:"type"`
+	Constraint  string                     `json:"constraint"`
+	Version     string                     `json:"version"`
+	Protocols   []string                   `json:"protocols"`
+	PublishedAt time.Time                  `json:"published_at"`
+	Prerelease  bool                       `json:"prerelease"`
+	Deprecated  bool                       `json:"deprecated"`
+	Deprecation *VersionDeprecation        `json:"deprecation,omitempty"`
+	Platforms   []ProviderResolvedPlatform `json:"platforms"`
+}
+
+// ProviderResolveNotFoundResponse is the 404 body of the resolve endpoint
+// when no version matches. ClosestVersions lists the available versions
+// nearest the constraint; MissingPlatform lists the versions that match the
+// constraint but lack the requested platform.
+type ProviderResolveNotFoundResponse struct {
+	Errors          []string `json:"errors"`
+	ClosestVersions []string `json:"closest_versions"`
+	MissingPlatform []string `json:"missing_platform,omitempty"`
 }
@@ -3,14 +3,20 @@ package providers
 
 import (
 	"database/sql"
+	"log/slog"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/pagination"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 )
 
+// searchLimits are the page size default and cap of the search endpoint.
+var searchLimits = pagination.Limits{Default: 20, Max: 100}
+
 // validProviderSortFields defines the allowed values for the sort query parameter.
 var validProviderSortFields = map[string]bool{
 	"":          true,
@@ -22,21 +28,23 @@ var validProviderSortFields = map[string]bool{
 }
 
 // @Summary      Search providers
-// @Description  Search for providers by name or namespace with pagination and sorting.
+// @Description  Search for providers by name or namespace with pagination and sorting. Each result carries its registry-assigned tier (community, approved, official, mirrored, or a custom tier); tier filters on it, accepting verified and partner as aliases of approved. Mirrored providers also carry the upstream source_url, upstream_tier and detected license.
 // @Tags         Providers
 // @Produce      json
 // @Param        q          query  string  false  "Search query"
 // @Param        namespace  query  string  false  "Filter by namespace"
+// @Param        tier       query  string  false  "Filter by tier (e.g. approved)"
 // @Param        sort       query  string  false  "Sort field: relevance, name, downloads, created, updated"
 // @Param        order      query  string  false  "Sort order: asc or desc (default desc)"
-// @Param        limit      query  int     false  "Maximum results to return (default 20, max 100)"
-// @Param        offset     query  int     false  "Offset for pagination (default 0)"
+// @Param        scope      query  string  false  "all to search every namespace on a custom tenant domain; by default results there are limited to the tenant organization's namespaces"
+// @Param        limit      query  int     false  "Maximum results to return (default 20, max 100); alias per_page"
+// @Param        offset     query  int     false  "Offset for pagination (default 0); alias page (1-based)"
 // @Success      200  {object}  providers.ProviderSearchResponse
-// @Failure      400  {object}  map[string]interface{}  "Invalid sort parameter"
+// @Failure      400  {object}  map[string]interface{}  "Invalid sort or tier parameter"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/providers/search [get]
 // SearchHandler handles provider search requests
-// Implements: GET /api/v1/providers/search?q=<query>&namespace=<namespace>&sort=<sort>&order=<order>&limit=<limit>&offset=<offset>
+// Implements: GET /api/v1/providers/search?q=<query>&namespace=<namespace>&tier=<tier>&sort=<sort>&order=<order>&limit=<limit>&offset=<offset>
 func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 	providerRepo := repositories.NewProviderRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
@@ -57,19 +65,19 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		// Pagination parameters
-		limitStr := c.DefaultQuery("limit", "20")
-		offsetStr := c.DefaultQuery("offset", "0")
-
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil || limit < 1 || limit > 100 {
-			limit = 20 // Default to 20, max 100
+		var tier string
+		if t := c.Query("tier"); t != "" {
+			normalized, err := models.NormalizeTier(t)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err.Error(),
+				})
+				return
+			}
+			tier = normalized
 		}
 
-		offset, err := strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			offset = 0
-		}
+		page := pagination.Parse(c, searchLimits)
 
 		// Get organization context
 		var orgID string
@@ -91,14 +99,23 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 		}
 		// In single-tenant mode, orgID will be empty string which the repository will handle
 
+		// On a custom tenant domain, search only the tenant organization's
+		// namespaces unless every namespace is asked for.
+		var ownerOrgID string
+		if c.Query("scope") != "all" {
+			ownerOrgID = middleware.TenantOrganizationID(c)
+		}
+
 		// Search providers with aggregated version stats in a single query
 		providers, total, err := providerRepo.SearchProvidersWithStats(
 			c.Request.Context(),
 			orgID,
+			ownerOrgID,
 			query,
 			namespace,
-			limit,
-			offset,
+			tier,
+			page.Limit,
+			page.Offset,
 			sortField,
 			sortOrder,
 		)
@@ -109,6 +126,21 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Upstream metadata only exists for mirrored providers; failing to load
+		// it, or the tiers, leaves those fields out rather than failing the search.
+		ids := make([]string, len(providers))
+		for i, p := range providers {
+			ids[i] = p.ID
+		}
+		upstream, err := providerRepo.ListUpstreamMetadata(c.Request.Context(), ids)
+		if err != nil {
+			slog.Warn("failed to load provider upstream metadata for search", "error", err)
+		}
+		tiers, err := providerRepo.ListTiers(c.Request.Context(), ids)
+		if err != nil {
+			slog.Warn("failed to load provider tiers for search", "error", err)
+		}
+
 		// Format results
 		results := make([]gin.H, len(providers))
 		for i, p := range providers {
@@ -130,15 +162,25 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 				"created_at":      p.CreatedAt,
 				"updated_at":      p.UpdatedAt,
 			}
+			if meta := upstream[p.ID]; meta != nil {
+				if meta.SourceURL != nil {
+					results[i]["source_url"] = *meta.SourceURL
+				}
+				if meta.UpstreamTier != nil {
+					results[i]["upstream_tier"] = *meta.UpstreamTier
+				}
+				if meta.License != nil {
+					results[i]["license"] = *meta.License
+				}
+			}
+			if t, ok := tiers[p.ID]; ok {
+				results[i]["tier"] = t
+			}
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"providers": results,
-			"meta": gin.H{
-				"limit":  limit,
-				"offset": offset,
-				"total":  total,
-			},
+			"meta":      page.Meta(int64(total)),
 		})
 	}
 }
@@ -5,16 +5,23 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/remoteupload"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/telemetry"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
@@ -30,17 +37,40 @@ const (
 	MaxSignatureFileSize = 64 << 10 // 64KB
 )
 
+// providerUploadRequest is the upload's metadata; the JSON form also names the
+// binary to download in place of a multipart file.
+type providerUploadRequest struct {
+	Namespace    string   `json:"namespace"`
+	Type         string   `json:"type"`
+	Version      string   `json:"version"`
+	OS           string   `json:"os"`
+	Arch         string   `json:"arch"`
+	Protocols    []string `json:"protocols"`
+	GPGPublicKey string   `json:"gpg_public_key"`
+	Description  string   `json:"description"`
+	Source       string   `json:"source"`
+	// AllowAddressMismatch publishes an archive whose binary or manifest names
+	// a different provider, for intentionally re-namespaced forks.
+	AllowAddressMismatch bool `json:"allow_address_mismatch"`
+
+	SourceURL       string `json:"source_url"`
+	Checksum        string `json:"checksum"`
+	AuthHeaderName  string `json:"auth_header_name"`
+	AuthHeaderValue string `json:"auth_header_value"`
+}
+
 // @Summary      Upload provider version
-// @Description  Uploads a new provider version binary and associated files. Provider identity (namespace, type, version, os, arch) is supplied as multipart form fields, not path params. Requires providers:write scope.
+// @Description  Uploads a new provider version binary and associated files. Provider identity (namespace, type, version, os, arch) is supplied as multipart form fields, not path params. type, version, os and arch may be omitted when the file is named terraform-provider-<TYPE>_<VERSION>_<OS>_<ARCH>.zip; they are then read from the name, and values that are supplied must match it (409 otherwise). Alternatively send an application/json body with the same fields (protocols as an array) plus source_url (and optional checksum, auth_header_name, auth_header_value) to have the registry download the binary; this requires remote_upload.enabled and does not accept SHA256SUMS files. The archive must hold a terraform-provider-<TYPE> binary for type, and a terraform-registry-manifest.json that declares metadata.address must declare namespace/type; otherwise the upload is rejected with 422 listing the discrepancies, unless allow_address_mismatch=true (for intentionally re-namespaced forks). With dry_run=true the upload goes through every check a publish does (naming, binary, provider address, SHA256SUMS signature, duplicate platform) without writing to the database or storage, and answers 200 with what would be published; a failed check answers as a real publish would. Requires providers:write scope.
 // @Tags         Providers
 // @Security     Bearer
 // @Accept       multipart/form-data
+// @Accept       json
 // @Produce      json
 // @Param        namespace      formData  string  true   "Provider namespace"
 // @Param        type           formData  string  true   "Provider type (e.g. aws, azurerm)"
-// @Param        version        formData  string  true   "Semantic version (e.g. 1.2.3)"
-// @Param        os             formData  string  true   "Target OS (e.g. linux, darwin, windows)"
-// @Param        arch           formData  string  true   "Target architecture (e.g. amd64, arm64)"
+// @Param        version        formData  string  false  "Semantic version (e.g. 1.2.3); defaults to the one in a canonical file name"
+// @Param        os             formData  string  false  "Target OS (e.g. linux, darwin, windows); defaults to the one in a canonical file name"
+// @Param        arch           formData  string  false  "Target architecture (e.g. amd64, arm64); defaults to the one in a canonical file name"
 // @Param        protocols      formData  string  false  "JSON array of supported protocols (default [\"5.0\"])"
 // @Param        gpg_public_key formData  string  false  "ASCII-armored GPG public key for signing verification"
 // @Param        description    formData  string  false  "Provider description"
@@ -48,43 +78,110 @@ const (
 // @Param        file           formData  file    true   "Provider binary (.zip, max 500MB)"
 // @Param        shasums_file           formData  file    false  "SHA256SUMS file (max 64KB). Required if shasums_signature_file is provided."
 // @Param        shasums_signature_file formData  file    false  "Detached GPG signature of SHA256SUMS (max 64KB). Requires shasums_file AND gpg_public_key; verified before persistence."
+// @Param        allow_address_mismatch formData  bool    false  "Publish even if the archive names a different provider (re-namespaced forks)"
+// @Param        dry_run                query     bool    false  "Validate without publishing"
+// @Success      200  {object}  ProviderUploadDryRunResponse  "dry_run=true: the upload passed every check"
 // @Success      201
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
-// @Failure      409  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}  "Conflict, including version/os/arch that disagree with the file name"
+// @Failure      413  {object}  map[string]interface{}  "Remote binary exceeds the size limit"
+// @Failure      422  {object}  map[string]interface{}  "Remote binary checksum mismatch, or the archive holds a different provider (discrepancies lists how)"
 // @Failure      500  {object}  map[string]interface{}
+// @Failure      502  {object}  map[string]interface{}  "source_url download failed; upstream_status carries the remote status"
+// @Failure      503  {object}  map[string]interface{}  "Scratch space budget exceeded; retry later"
+// @Failure      504  {object}  map[string]interface{}
 // @Router       /api/v1/providers [post]
 // UploadHandler handles provider upload requests
 // Implements: POST /api/v1/providers
 // Accepts multipart form with: namespace, type, version, os, arch, protocols, gpg_public_key, file
-func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config) gin.HandlerFunc {
+// or a JSON providerUploadRequest naming a source_url to download.
+func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config, immutability *services.ArtifactImmutability, scratchSpace *scratch.Space) gin.HandlerFunc {
 	providerRepo := repositories.NewProviderRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
 
+	fetcher := remoteupload.NewFetcher(cfg).WithScratch(scratchSpace)
+
 	return func(c *gin.Context) {
-		// Parse multipart form (max 500MB for provider binaries)
-		if err := c.Request.ParseMultipartForm(MaxProviderBinarySize); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Failed to parse multipart form",
+		// A JSON body publishes from a URL instead of a multipart upload.
+		var req providerUploadRequest
+		remote := remoteupload.IsJSONRequest(c)
+		// A dry run goes through every check a publish does but writes
+		// nothing, and reports what it would have published.
+		dryRun := c.Query("dry_run") == "true"
+		if remote {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid request body",
+				})
+				return
+			}
+			if req.SourceURL == "" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Missing required field: source_url",
+				})
+				return
+			}
+		} else {
+			// Parse multipart form (max 500MB for provider binaries)
+			if err := c.Request.ParseMultipartForm(MaxProviderBinarySize); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Failed to parse multipart form",
+				})
+				return
+			}
+
+			// Get form values
+			req = providerUploadRequest{
+				Namespace:    c.PostForm("namespace"),
+				Type:         c.PostForm("type"),
+				Version:      c.PostForm("version"),
+				OS:           c.PostForm("os"),
+				Arch:         c.PostForm("arch"),
+				GPGPublicKey: c.PostForm("gpg_public_key"),
+				Description:  c.PostForm("description"),
+				Source:       c.PostForm("source"),
+
+				AllowAddressMismatch: c.PostForm("allow_address_mismatch") == "true",
+			}
+			if protocolsStr := c.PostForm("protocols"); protocolsStr != "" {
+				if err := json.Unmarshal([]byte(protocolsStr), &req.Protocols); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error": fmt.Sprintf("Invalid protocols format (must be JSON array): %v", err),
+					})
+					return
+				}
+			}
+		}
+
+		// Type, version and platform may be left to the canonical archive name.
+		if err := applyProviderFilename(&req, uploadFilename(c, remote, req.SourceURL)); err != nil {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
 			})
 			return
 		}
 
-		// Get form values
-		namespace := c.PostForm("namespace")
-		providerType := c.PostForm("type")
-		version := c.PostForm("version")
-		targetOS := c.PostForm("os")
-		arch := c.PostForm("arch")
-		protocolsStr := c.PostForm("protocols")
-		gpgPublicKey := c.PostForm("gpg_public_key")
-		description := c.PostForm("description")
-		source := c.PostForm("source")
+		namespace := req.Namespace
+		providerType := req.Type
+		version := req.Version
+		targetOS := req.OS
+		arch := req.Arch
+		gpgPublicKey := req.GPGPublicKey
+		description := req.Description
+		source := req.Source
 
 		// Validate required fields
-		if namespace == "" || providerType == "" || version == "" || targetOS == "" || arch == "" {
+		if namespace == "" || providerType == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Missing required fields: namespace, type",
+			})
+			return
+		}
+		if version == "" || targetOS == "" || arch == "" {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Missing required fields: namespace, type, version, os, arch",
+				"error": "Missing required fields: version, os, arch (they may be omitted only when the file is named " +
+					providerFilenameLayout + ")",
 			})
 			return
 		}
@@ -117,16 +214,8 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
-		// Parse protocols JSON array
-		var protocols []string
-		if protocolsStr != "" {
-			if err := json.Unmarshal([]byte(protocolsStr), &protocols); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error": fmt.Sprintf("Invalid protocols format (must be JSON array): %v", err),
-				})
-				return
-			}
-		} else {
+		protocols := req.Protocols
+		if len(protocols) == 0 {
 			// Default to protocol 5.0 if not specified
 			protocols = []string{"5.0"}
 		}
@@ -143,33 +232,66 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			gpgPublicKey = validation.NormalizeGPGKey(gpgPublicKey)
 		}
 
-		// Get uploaded file
-		file, header, err := c.Request.FormFile("file")
+		release, err := scratchSpace.Reserve(MaxProviderBinarySize)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Missing or invalid file upload",
+			c.Header("Retry-After", "60")
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": err.Error(),
 			})
 			return
 		}
-		defer file.Close()
+		defer release()
 
-		// Write uploaded file to a temp file to avoid holding up to 500MB in memory
-		tmpFile, err := os.CreateTemp("", "provider-upload-*.zip")
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to create temporary file",
-			})
-			return
-		}
-		defer os.Remove(tmpFile.Name())
-		defer tmpFile.Close()
+		var (
+			tmpFile  *os.File
+			size     int64
+			filename string
+		)
+		if remote {
+			src := &remoteupload.Source{
+				SourceURL:       req.SourceURL,
+				Checksum:        req.Checksum,
+				AuthHeaderName:  req.AuthHeaderName,
+				AuthHeaderValue: req.AuthHeaderValue,
+			}
+			req.AuthHeaderValue = ""
+			fetched, err := fetcher.Fetch(c.Request.Context(), src, MaxProviderBinarySize, "provider-upload-*.zip")
+			if err != nil {
+				remoteupload.Respond(c, err)
+				return
+			}
+			defer fetched.Cleanup()
+			tmpFile, size, filename = fetched.File, fetched.Size, fetched.Filename
+		} else {
+			// Get uploaded file
+			file, header, err := c.Request.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Missing or invalid file upload",
+				})
+				return
+			}
+			defer file.Close()
+			filename = header.Filename
 
-		size, err := io.Copy(tmpFile, file)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to read uploaded file",
-			})
-			return
+			// Write uploaded file to a temp file to avoid holding up to 500MB in memory
+			tmpFile, err = scratchSpace.CreateTemp("provider-upload-*.zip")
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to create temporary file",
+				})
+				return
+			}
+			defer os.Remove(tmpFile.Name())
+			defer tmpFile.Close()
+
+			size, err = io.Copy(tmpFile, io.LimitReader(file, MaxProviderBinarySize+1))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to read uploaded file",
+				})
+				return
+			}
 		}
 
 		// Validate provider binary: check size and read ZIP magic bytes from temp file
@@ -208,6 +330,31 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
+		// The archive must hold the provider it is published as, unless the
+		// uploader vouches for an intentionally re-namespaced fork.
+		var addressWarning string
+		if err := validation.CheckProviderArchiveAddress(tmpFile, size, namespace, providerType); err != nil {
+			var mismatch *validation.ProviderAddressMismatch
+			switch {
+			case !errors.As(err, &mismatch):
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("Invalid provider binary: %v", err),
+				})
+				return
+			case !req.AllowAddressMismatch:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error":         fmt.Sprintf("Provider archive does not match %s/%s", namespace, providerType),
+					"discrepancies": mismatch.Discrepancies,
+				})
+				return
+			default:
+				addressWarning = "Provider archive does not match its address (allowed by allow_address_mismatch): " +
+					strings.Join(mismatch.Discrepancies, "; ")
+				slog.Warn("publishing provider archive that does not match its address",
+					"namespace", namespace, "type", providerType, "version", version, "discrepancies", mismatch.Discrepancies)
+			}
+		}
+
 		// Calculate SHA256 checksum (seek back to start)
 		if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -238,6 +385,20 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
+		// Optional: accept shasums_file and shasums_signature_file. These are
+		// per-version files, so we only need to store them once. Subsequent
+		// platform uploads against the same version can omit them; if provided,
+		// we'll re-validate and overwrite (the operator may be re-uploading the
+		// signed files after a key rotation). A JSON upload carries no files.
+		// They are checked here, before anything is written.
+		var sigFiles uploadedSignatureFiles
+		if !remote {
+			if sigFiles, err = readUploadedSignatureFiles(c, gpgPublicKey); err != nil {
+				// readUploadedSignatureFiles has already written the HTTP error.
+				return
+			}
+		}
+
 		// Check if provider already exists, create if not
 		provider, err := providerRepo.GetProvider(c.Request.Context(), org.ID, namespace, providerType)
 		if err != nil {
@@ -246,8 +407,16 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			})
 			return
 		}
+		providerExists := provider != nil
 
-		if provider == nil {
+		switch {
+		case dryRun:
+			// Nothing is created; a provider that does not exist yet has no
+			// versions to check against.
+			if provider == nil {
+				provider = &models.Provider{OrganizationID: org.ID, Namespace: namespace, Type: providerType}
+			}
+		case provider == nil:
 			// Create new provider
 			provider = &models.Provider{
 				OrganizationID: org.ID,
@@ -273,7 +442,7 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 				})
 				return
 			}
-		} else {
+		default:
 			// Update existing provider metadata if provided
 			if description != "" {
 				provider.Description = &description
@@ -290,13 +459,17 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 		}
 
 		// Check if version already exists, create if not
-		providerVersion, err := providerRepo.GetVersion(c.Request.Context(), provider.ID, version)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to query provider version",
-			})
-			return
+		var providerVersion *models.ProviderVersion
+		if provider.ID != "" {
+			providerVersion, err = providerRepo.GetVersion(c.Request.Context(), provider.ID, version)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to query provider version",
+				})
+				return
+			}
 		}
+		versionExists := providerVersion != nil
 
 		if providerVersion == nil {
 			// Create new version. ShasumURL/ShasumSignatureURL stay empty here —
@@ -317,41 +490,72 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 				}
 			}
 
-			if err := providerRepo.CreateVersion(c.Request.Context(), providerVersion); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": fmt.Sprintf("Failed to create provider version: %v", err),
-				})
-				return
+			if !dryRun {
+				if err := providerRepo.CreateVersion(c.Request.Context(), providerVersion); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{
+						"error": fmt.Sprintf("Failed to create provider version: %v", err),
+					})
+					return
+				}
 			}
 		}
 
-		// Optional: accept shasums_file and shasums_signature_file. These are
-		// per-version files, so we only need to store them once. Subsequent
-		// platform uploads against the same version can omit them; if provided,
-		// we'll re-validate and overwrite (the operator may be re-uploading the
-		// signed files after a key rotation).
-		if storeErr := storeUploadedSignatureFiles(c, storageBackend, providerRepo, providerVersion, namespace, providerType, version, gpgPublicKey); storeErr != nil {
-			// storeUploadedSignatureFiles has already written the HTTP error.
-			return
+		if !dryRun {
+			if storeErr := storeUploadedSignatureFiles(c, storageBackend, providerRepo, providerVersion, sigFiles, namespace, providerType, version); storeErr != nil {
+				// storeUploadedSignatureFiles has already written the HTTP error.
+				return
+			}
 		}
 
-		// Check for duplicate platform
-		existingPlatform, err := providerRepo.GetPlatform(c.Request.Context(), providerVersion.ID, targetOS, arch)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to check for existing platform",
-			})
-			return
+		// Check for duplicate platform; a version a dry run did not create
+		// has none.
+		if providerVersion.ID != "" {
+			existingPlatform, err := providerRepo.GetPlatform(c.Request.Context(), providerVersion.ID, targetOS, arch)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to check for existing platform",
+				})
+				return
+			}
+			if existingPlatform != nil {
+				c.JSON(http.StatusConflict, gin.H{
+					"error": fmt.Sprintf("Platform %s/%s already exists for version %s", targetOS, arch, version),
+				})
+				return
+			}
 		}
-		if existingPlatform != nil {
-			c.JSON(http.StatusConflict, gin.H{
-				"error": fmt.Sprintf("Platform %s/%s already exists for version %s", targetOS, arch, version),
-			})
+
+		if dryRun {
+			resp := ProviderUploadDryRunResponse{
+				DryRun:               true,
+				Namespace:            namespace,
+				Type:                 providerType,
+				Version:              version,
+				OS:                   targetOS,
+				Arch:                 arch,
+				Protocols:            protocols,
+				Checksum:             sha256sum,
+				SizeBytes:            size,
+				Filename:             filename,
+				ProviderExists:       providerExists,
+				VersionExists:        versionExists,
+				ShasumsFile:          sigFiles.sumsProvided,
+				ShasumsSignatureFile: sigFiles.sigProvided,
+				Warnings:             []string{},
+			}
+			if addressWarning != "" {
+				resp.Warnings = append(resp.Warnings, addressWarning)
+			}
+			if h1, err := checksum.HashZipFile(tmpFile, size); err != nil {
+				resp.Warnings = append(resp.Warnings, fmt.Sprintf("Failed to compute the h1 hash; the zh hash would be used instead: %v", err))
+			} else {
+				resp.H1Hash = h1
+			}
+			c.JSON(http.StatusOK, resp)
 			return
 		}
 
-		// Generate storage path: providers/{namespace}/{type}/{version}/{os}_{arch}.zip
-		storagePath := fmt.Sprintf("providers/%s/%s/%s/%s_%s.zip", namespace, providerType, version, targetOS, arch)
+		storagePath := storage.ProviderArchiveKey(namespace, providerType, version, targetOS, arch)
 
 		// Seek back to start for storage upload
 		if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
@@ -369,6 +573,12 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			size,
 		)
 		if err != nil {
+			if errors.Is(err, storage.ErrArtifactImmutable) {
+				c.JSON(http.StatusConflict, gin.H{
+					"error": err.Error(),
+				})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": fmt.Sprintf("Failed to upload file: %v", err),
 			})
@@ -380,7 +590,7 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			ProviderVersionID: providerVersion.ID,
 			OS:                targetOS,
 			Arch:              arch,
-			Filename:          header.Filename,
+			Filename:          filename,
 			StoragePath:       uploadResult.Path,
 			StorageBackend:    cfg.Storage.DefaultBackend,
 			SizeBytes:         uploadResult.Size,
@@ -411,6 +621,22 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
+		// Append to the publish log when the provider's organization keeps one.
+		logEntry := &models.PublishLogEntry{
+			ArtifactType:   models.PublishLogArtifactProvider,
+			OrganizationID: &provider.OrganizationID,
+			Namespace:      namespace,
+			Name:           providerType,
+			Target:         targetOS + "_" + arch,
+			Version:        version,
+			StorageKey:     platform.StoragePath,
+			Checksum:       platform.Shasum,
+		}
+		if uid := c.GetString("user_id"); uid != "" {
+			logEntry.PublishedBy = &uid
+		}
+		immutability.RecordPublish(c.Request.Context(), logEntry)
+
 		// Emit publish metric
 		telemetry.ProviderPublishesTotal.WithLabelValues(provider.Namespace, provider.Type).Inc()
 
@@ -425,74 +651,154 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			"protocols":  providerVersion.Protocols,
 			"checksum":   platform.Shasum,
 			"size_bytes": platform.SizeBytes,
-			"filename":   header.Filename,
+			"filename":   filename,
 		})
 	}
 }
 
-// storeUploadedSignatureFiles handles the optional shasums_file and
+// providerFilenameLayout is the archive name the upload handler reads
+// version and platform from.
+const providerFilenameLayout = "terraform-provider-<TYPE>_<VERSION>_<OS>_<ARCH>.zip"
+
+// uploadFilename is the name of the binary being published: the multipart
+// file's name, or the last path segment of source_url (the name the remote
+// fetch records). It is empty when neither is available yet.
+func uploadFilename(c *gin.Context, remote bool, sourceURL string) string {
+	if remote {
+		u, err := url.Parse(sourceURL)
+		if err != nil {
+			return ""
+		}
+		name := path.Base(u.Path)
+		if name == "." || name == "/" {
+			return ""
+		}
+		return name
+	}
+	if c.Request.MultipartForm == nil {
+		return ""
+	}
+	if files := c.Request.MultipartForm.File["file"]; len(files) > 0 {
+		return files[0].Filename
+	}
+	return ""
+}
+
+// applyProviderFilename fills the type, version, os and arch the request left empty
+// from a canonical archive name, and returns an error when a value the
+// request did supply disagrees with the name. Names in any other layout are
+// ignored, leaving the request to supply every field itself.
+func applyProviderFilename(req *providerUploadRequest, filename string) error {
+	parsed, ok := validation.ParseProviderFilename(filename)
+	if !ok {
+		return nil
+	}
+	for _, f := range []struct {
+		field string
+		value *string
+		name  string
+	}{
+		{"type", &req.Type, parsed.Type},
+		{"version", &req.Version, parsed.Version},
+		{"os", &req.OS, parsed.OS},
+		{"arch", &req.Arch, parsed.Arch},
+	} {
+		switch {
+		case *f.value == "":
+			*f.value = f.name
+		case *f.value != f.name:
+			return fmt.Errorf("%s %q does not match %q from filename %s", f.field, *f.value, f.name, filename)
+		}
+	}
+	return nil
+}
+
+// uploadedSignatureFiles are an upload's optional shasums_file and
+// shasums_signature_file.
+type uploadedSignatureFiles struct {
+	sums, sig                 []byte
+	sumsProvided, sigProvided bool
+}
+
+// readUploadedSignatureFiles reads and checks the optional shasums_file and
 // shasums_signature_file multipart inputs:
-// coverage:skip:integration-only — performs storage backend uploads and DB writes that require a live storage service; parameter validation and error paths are exercised by unit tests (TestUploadHandler_Rejects* and TestUploadHandler_StoresShasumsFileWithoutSignature).
 //
-//   - If neither file is provided, no-op.
+//   - If neither file is provided, it returns an empty set.
 //   - If shasums_signature_file is provided, shasums_file AND a non-empty
 //     gpg_public_key form value are required; the signature is verified
-//     against the SUMS before persistence (rejected with 400 on failure).
-//   - If only shasums_file is provided (no signature), it is stored as-is.
+//     against the SUMS (rejected with 400 on failure).
+//   - If only shasums_file is provided (no signature), it is accepted as-is.
 //
-// On success the version row's storage-key columns are updated and the
-// download handler will start returning pre-signed URLs for these files.
 // On any error this function writes the HTTP response and returns a
 // non-nil error so the caller can abort the upload flow.
-func storeUploadedSignatureFiles(
-	c *gin.Context,
-	storageBackend storage.Storage,
-	providerRepo *repositories.ProviderRepository,
-	providerVersion *models.ProviderVersion,
-	namespace, providerType, version, gpgPublicKey string,
-) error {
-	sumsBytes, sumsProvided, err := readOptionalMultipartFile(c, "shasums_file")
-	if err != nil {
+func readUploadedSignatureFiles(c *gin.Context, gpgPublicKey string) (uploadedSignatureFiles, error) {
+	var files uploadedSignatureFiles
+	var err error
+	if files.sums, files.sumsProvided, err = readOptionalMultipartFile(c, "shasums_file"); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return err
+		return files, err
 	}
-	sigBytes, sigProvided, err := readOptionalMultipartFile(c, "shasums_signature_file")
-	if err != nil {
+	if files.sig, files.sigProvided, err = readOptionalMultipartFile(c, "shasums_signature_file"); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return err
-	}
-
-	if !sumsProvided && !sigProvided {
-		return nil
+		return files, err
 	}
 
-	if sigProvided {
-		if !sumsProvided {
+	if files.sigProvided {
+		if !files.sumsProvided {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "shasums_signature_file requires shasums_file in the same upload",
 			})
-			return fmt.Errorf("sig without sums")
+			return files, fmt.Errorf("sig without sums")
 		}
 		if gpgPublicKey == "" {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "shasums_signature_file requires gpg_public_key to verify the signature",
 			})
-			return fmt.Errorf("sig without gpg key")
+			return files, fmt.Errorf("sig without gpg key")
 		}
-		if verifyErr := validation.VerifySignature(gpgPublicKey, sumsBytes, sigBytes); verifyErr != nil {
+		if verifyErr := validation.VerifySignature(gpgPublicKey, files.sums, files.sig); verifyErr != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": fmt.Sprintf("shasums signature failed GPG verification: %v", verifyErr),
 			})
-			return verifyErr
+			return files, verifyErr
 		}
 	}
+	return files, nil
+}
+
+// storeUploadedSignatureFiles stores the files readUploadedSignatureFiles
+// accepted, if any:
+// coverage:skip:integration-only — performs storage backend uploads and DB writes that require a live storage service; the happy path is exercised by TestUploadHandler_StoresShasumsFileWithoutSignature.
+//
+// On success the version row's storage-key columns are updated and the
+// download handler will start returning pre-signed URLs for these files.
+// On any error this function writes the HTTP response and returns a
+// non-nil error so the caller can abort the upload flow.
+func storeUploadedSignatureFiles(
+	c *gin.Context,
+	storageBackend storage.Storage,
+	providerRepo *repositories.ProviderRepository,
+	providerVersion *models.ProviderVersion,
+	files uploadedSignatureFiles,
+	namespace, providerType, version string,
+) error {
+	if !files.sumsProvided && !files.sigProvided {
+		return nil
+	}
+	sumsBytes, sigBytes := files.sums, files.sig
 
 	var sumsKey, sigKey *string
 
-	if sumsProvided {
-		path := fmt.Sprintf("providers/%s/%s/%s/SHA256SUMS", namespace, providerType, version)
+	if files.sumsProvided {
+		path := storage.ProviderFileKey(namespace, providerType, version, "SHA256SUMS")
 		if _, upErr := storageBackend.Upload(c.Request.Context(), path, bytes.NewReader(sumsBytes), int64(len(sumsBytes))); upErr != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
+			status := http.StatusInternalServerError
+			if errors.Is(upErr, storage.ErrArtifactImmutable) {
+				// The version's files were stored by an earlier upload and
+				// immutability forbids replacing them.
+				status = http.StatusConflict
+			}
+			c.JSON(status, gin.H{
 				"error": fmt.Sprintf("Failed to upload SHA256SUMS: %v", upErr),
 			})
 			return upErr
@@ -500,10 +806,16 @@ func storeUploadedSignatureFiles(
 		sumsKey = &path
 	}
 
-	if sigProvided {
-		path := fmt.Sprintf("providers/%s/%s/%s/SHA256SUMS.sig", namespace, providerType, version)
+	if files.sigProvided {
+		path := storage.ProviderFileKey(namespace, providerType, version, "SHA256SUMS.sig")
 		if _, upErr := storageBackend.Upload(c.Request.Context(), path, bytes.NewReader(sigBytes), int64(len(sigBytes))); upErr != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
+			status := http.StatusInternalServerError
+			if errors.Is(upErr, storage.ErrArtifactImmutable) {
+				// The version's files were stored by an earlier upload and
+				// immutability forbids replacing them.
+				status = http.StatusConflict
+			}
+			c.JSON(status, gin.H{
 				"error": fmt.Sprintf("Failed to upload SHA256SUMS signature: %v", upErr),
 			})
 			return upErr
@@ -2,27 +2,35 @@
 package providers
 
 import (
+	"context"
 	"database/sql"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/negotiate"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/db/transient"
 )
 
 // @Summary      List provider versions
-// @Description  List all available versions and platforms for a specific provider. Implements the Terraform Provider Registry Protocol.
+// @Description  List all available versions and platforms for a specific provider. Implements the Terraform Provider Registry Protocol. The protocol document is returned by default (and for `Accept: application/json`); sending `Accept: application/vnd.tfr.v1+json` returns the extended document (providers.ProviderVersionsExtendedResponse) with the provider's tier and publisher, per-platform size/hash, and deprecation metadata instead.
 // @Tags         Providers
 // @Produce      json
+// @Produce      application/vnd.tfr.v1+json
 // @Param        namespace  path  string  true  "Provider namespace"
 // @Param        type       path  string  true  "Provider type (e.g. aws, azurerm)"
 // @Param        limit      query int     false "Maximum results (default 100, max 1000)"
 // @Param        offset     query int     false "Offset for pagination (default 0)"
+// @Param        Accept     header string  false "application/json (default) or application/vnd.tfr.v1+json for the extended document"
 // @Success      200  {object}  providers.ProviderVersionsResponse
 // @Failure      404  {object}  map[string]interface{}  "Provider not found"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Failure      503  {object}  map[string]interface{}  "Database temporarily unavailable (Retry-After set)"
 // @Router       /v1/providers/{namespace}/{type}/versions [get]
 // ListVersionsHandler handles listing all versions of a provider
 // Implements: GET /v1/providers/:namespace/:type/versions
@@ -33,6 +41,8 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		namespace := c.Param("namespace")
 		providerType := c.Param("type")
+		negotiate.SetVary(c)
+		extended := negotiate.WantsExtended(c)
 
 		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
 		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
@@ -47,11 +57,9 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Get organization context (default org for single-tenant mode)
-		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+		org, err := transient.Value(c.Request.Context(), orgRepo.GetDefaultOrganization)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to get organization context",
-			})
+			respondQueryError(c, err, "Failed to get organization context")
 			return
 		}
 		if org == nil {
@@ -62,11 +70,11 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Get provider
-		provider, err := providerRepo.GetProvider(c.Request.Context(), org.ID, namespace, providerType)
+		provider, err := transient.Value(c.Request.Context(), func(ctx context.Context) (*models.Provider, error) {
+			return providerRepo.GetProvider(ctx, org.ID, namespace, providerType)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to query provider",
-			})
+			respondQueryError(c, err, "Failed to query provider")
 			return
 		}
 
@@ -78,27 +86,38 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Get all versions for the provider with pagination
-		versions, total, err := providerRepo.ListVersionsPaginated(c.Request.Context(), provider.ID, limit, offset)
+		var versions []*models.ProviderVersion
+		var total int
+		err = transient.Do(c.Request.Context(), func(ctx context.Context) error {
+			var err error
+			versions, total, err = providerRepo.ListVersionsPaginated(ctx, provider.ID, limit, offset)
+			return err
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to list provider versions",
-			})
+			respondQueryError(c, err, "Failed to list provider versions")
 			return
 		}
 
 		// Format response per Terraform Provider Registry Protocol spec
 		// https://www.terraform.io/docs/internals/provider-registry-protocol.html
 		versionsList := make([]gin.H, 0, len(versions))
+		var extendedList []ProviderVersionExtended
+		var warnings []string
 		for _, v := range versions {
 			// Get platforms for this version
-			platforms, err := providerRepo.ListPlatforms(c.Request.Context(), v.ID)
+			platforms, err := transient.Value(c.Request.Context(), func(ctx context.Context) ([]*models.ProviderPlatform, error) {
+				return providerRepo.ListPlatforms(ctx, v.ID)
+			})
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Failed to list provider platforms",
-				})
+				respondQueryError(c, err, "Failed to list provider platforms")
 				return
 			}
 
+			if extended {
+				extendedList = append(extendedList, extendedProviderVersion(v, platforms))
+				continue
+			}
+
 			// Format platforms and sum downloads
 			platformsList := make([]gin.H, 0, len(platforms))
 			var versionDownloadCount int64
@@ -114,6 +133,10 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 				})
 			}
 
+			if w := v.DeprecationWarning(); w != "" {
+				warnings = append(warnings, w)
+			}
+
 			versionData := gin.H{
 				"id":             v.ID,
 				"version":        v.Version,
@@ -139,13 +162,73 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			versionsList = append(versionsList, versionData)
 		}
 
+		if extended {
+			if extendedList == nil {
+				extendedList = []ProviderVersionExtended{}
+			}
+			// The tier is decoration; a failed lookup leaves it out.
+			tiers, err := providerRepo.ListTiers(c.Request.Context(), []string{provider.ID})
+			if err != nil {
+				slog.Warn("failed to load provider tier", "provider_id", provider.ID, "error", err)
+			}
+			c.Header("Content-Type", negotiate.ExtendedMediaType)
+			c.JSON(http.StatusOK, ProviderVersionsExtendedResponse{
+				Namespace: provider.Namespace,
+				Type:      provider.Type,
+				Tier:      tiers[provider.ID],
+				Versions:  extendedList,
+				Total:     total,
+				Limit:     limit,
+				Offset:    offset,
+			})
+			return
+		}
+
 		response := gin.H{
 			"versions": versionsList,
 			"total":    total,
 			"limit":    limit,
 			"offset":   offset,
 		}
+		// Terraform prints a versions document's "warnings" during init.
+		if cfg.Protocol.DeprecationWarnings && len(warnings) > 0 {
+			response["warnings"] = warnings
+		}
 
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+// extendedProviderVersion maps a version row and its platforms to an extended
+// document entry. A version is reported as mirrored when its SHA256SUMS come
+// from an upstream URL rather than an uploaded file.
+func extendedProviderVersion(v *models.ProviderVersion, platforms []*models.ProviderPlatform) ProviderVersionExtended {
+	entry := ProviderVersionExtended{
+		ID:          v.ID,
+		Version:     v.Version,
+		Protocols:   v.Protocols,
+		Platforms:   make([]ProviderPlatformExtended, 0, len(platforms)),
+		PublishedAt: v.CreatedAt.UTC(),
+		Mirrored:    v.ShasumURL != "",
+		Deprecated:  v.Deprecated,
+	}
+	for _, p := range platforms {
+		entry.DownloadCount += p.DownloadCount
+		entry.Platforms = append(entry.Platforms, ProviderPlatformExtended{
+			OS:            p.OS,
+			Arch:          p.Arch,
+			Filename:      p.Filename,
+			Shasum:        p.Shasum,
+			H1Hash:        p.H1Hash,
+			SizeBytes:     p.SizeBytes,
+			DownloadCount: p.DownloadCount,
+		})
+	}
+	if v.PublishedBy != nil {
+		entry.PublishedBy = &VersionPublisher{ID: *v.PublishedBy, Name: v.PublishedByName}
+	}
+	if v.Deprecated {
+		entry.Deprecation = &VersionDeprecation{DeprecatedAt: v.DeprecatedAt, Message: v.DeprecationMessage}
+	}
+	return entry
+}
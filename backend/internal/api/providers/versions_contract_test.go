@@ -0,0 +1,256 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+// Contract tests for GET /v1/providers/:namespace/:type/versions.
+//
+// The protocol document is what `terraform init` consumes; these snapshots pin
+// it byte-for-byte so changes made for the extended
+// (application/vnd.tfr.v1+json) document can never leak into it.
+
+var contractPublishedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func expectContractVersionQueries(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT COUNT.*FROM provider_versions").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE pv.provider_id").
+		WillReturnRows(sqlmock.NewRows(providerVersionListCols).
+			AddRow("ver-1", "prov-1", "4.0.0", sampleProtocolsJSON, "",
+				"https://releases.example.com/SHA256SUMS", "",
+				nil, nil,
+				"user-1", "Alice",
+				true, contractPublishedAt, "upgrade to 5.x", contractPublishedAt))
+	h1 := "h1:abc="
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").
+		WillReturnRows(sqlmock.NewRows(platformCols).
+			AddRow("plat-1", "ver-1", "linux", "amd64",
+				"terraform-provider-aws_4.0.0_linux_amd64.zip",
+				"providers/hashicorp/aws/4.0.0/terraform-provider-aws_linux_amd64.zip",
+				"local", int64(1024000), "sha256abc", &h1, int64(3)))
+}
+
+func doVersionsGET(r *gin.Engine, accept string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/providers/hashicorp/aws/versions", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+const providerVersionsProtocolSnapshot = `{
+  "limit": 100,
+  "offset": 0,
+  "total": 1,
+  "versions": [
+    {
+      "deprecated": true,
+      "deprecated_at": "2024-05-01T12:00:00Z",
+      "deprecation_message": "upgrade to 5.x",
+      "download_count": 3,
+      "id": "ver-1",
+      "platforms": [
+        {
+          "arch": "amd64",
+          "download_count": 3,
+          "filename": "terraform-provider-aws_4.0.0_linux_amd64.zip",
+          "id": "plat-1",
+          "os": "linux",
+          "shasum": "sha256abc"
+        }
+      ],
+      "protocols": [
+        "6.0"
+      ],
+      "published_at": "2024-05-01T12:00:00Z",
+      "published_by": "user-1",
+      "published_by_name": "Alice",
+      "version": "4.0.0"
+    }
+  ]
+}`
+
+func TestListVersionsContract_ProtocolSnapshot(t *testing.T) {
+	for _, accept := range []string{"", "application/json", "*/*"} {
+		t.Run("accept="+accept, func(t *testing.T) {
+			mock, r := newVersionsRouter(t)
+			expectContractVersionQueries(mock)
+
+			w := doVersionsGET(r, accept)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			var pretty bytes.Buffer
+			if err := json.Indent(&pretty, w.Body.Bytes(), "", "  "); err != nil {
+				t.Fatalf("indent: %v", err)
+			}
+			if pretty.String() != providerVersionsProtocolSnapshot {
+				t.Errorf("protocol document changed.\ngot:\n%s\nwant:\n%s", pretty.String(), providerVersionsProtocolSnapshot)
+			}
+		})
+	}
+}
+
+func TestListVersionsContract_Extended(t *testing.T) {
+	mock, r := newVersionsRouter(t)
+	expectContractVersionQueries(mock)
+
+	w := doVersionsGET(r, "application/vnd.tfr.v1+json")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/vnd.tfr.v1+json" {
+		t.Errorf("Content-Type = %q, want application/vnd.tfr.v1+json", ct)
+	}
+	if vary := w.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("Vary = %q, want Accept", vary)
+	}
+
+	var resp ProviderVersionsExtendedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Namespace != "hashicorp" || resp.Type != "aws" || len(resp.Versions) != 1 {
+		t.Fatalf("unexpected document: %+v", resp)
+	}
+	v := resp.Versions[0]
+	if !v.Mirrored || v.DownloadCount != 3 || v.PublishedBy == nil || v.PublishedBy.ID != "user-1" {
+		t.Errorf("version = %+v", v)
+	}
+	if v.Deprecation == nil || v.Deprecation.Message == nil || *v.Deprecation.Message != "upgrade to 5.x" {
+		t.Errorf("deprecation = %+v", v.Deprecation)
+	}
+	if len(v.Platforms) != 1 || v.Platforms[0].SizeBytes != 1024000 || v.Platforms[0].H1Hash == nil {
+		t.Errorf("platforms = %+v", v.Platforms)
+	}
+}
+
+func TestListVersionsContract_ExtendedEmpty(t *testing.T) {
+	mock, r := newVersionsRouter(t)
+	mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+	mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+	mock.ExpectQuery("SELECT COUNT.*FROM provider_versions").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE pv.provider_id").
+		WillReturnRows(sqlmock.NewRows(providerVersionListCols))
+
+	w := doVersionsGET(r, "application/vnd.tfr.v1+json")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"versions":[]`)) {
+		t.Errorf("empty listing should serialise versions as [], got %s", w.Body.String())
+	}
+}
+
+// Deprecation warnings (protocol.deprecation_warnings). With the flag off the
+// protocol document stays byte-identical to the snapshot above; with it on the
+// only difference is a top-level "warnings" array.
+
+func newVersionsRouterWithConfig(t *testing.T, cfg *config.Config) (sqlmock.Sqlmock, *gin.Engine) {
+	t.Helper()
+	db, mock, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	r := gin.New()
+	r.GET("/v1/providers/:namespace/:type/versions", ListVersionsHandler(db, cfg))
+	r.GET("/v1/providers/:namespace/:type/:version/download/:os/:arch",
+		DownloadHandler(db, &mockStore{getURLResult: "https://example.com/provider.zip"}, cfg, nil, nil, nil))
+	return mock, r
+}
+
+func TestListVersionsContract_DeprecationWarnings(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			cfg := &config.Config{Protocol: config.ProtocolConfig{DeprecationWarnings: enabled}}
+			mock, r := newVersionsRouterWithConfig(t, cfg)
+			expectContractVersionQueries(mock)
+
+			w := doVersionsGET(r, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+			var doc map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			raw, present := doc["warnings"]
+			if !enabled {
+				if present {
+					t.Fatalf("warnings sent with the flag off: %s", raw)
+				}
+				return
+			}
+			var warnings []string
+			if err := json.Unmarshal(raw, &warnings); err != nil {
+				t.Fatalf("warnings: %v", err)
+			}
+			if len(warnings) != 1 || warnings[0] != "4.0.0 is deprecated: upgrade to 5.x" {
+				t.Errorf("warnings = %q", warnings)
+			}
+
+			// Everything else is still the protocol document.
+			delete(doc, "warnings")
+			rest, _ := json.Marshal(doc)
+			var pretty bytes.Buffer
+			_ = json.Indent(&pretty, rest, "", "  ")
+			if pretty.String() != providerVersionsProtocolSnapshot {
+				t.Errorf("protocol document changed beyond warnings.\ngot:\n%s", pretty.String())
+			}
+		})
+	}
+}
+
+func TestDownloadContract_DeprecationWarnings(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			cfg := &config.Config{Protocol: config.ProtocolConfig{DeprecationWarnings: enabled}}
+			mock, r := newVersionsRouterWithConfig(t, cfg)
+			mock.ExpectQuery("SELECT.*FROM organizations.*WHERE name").WillReturnRows(sampleOrgRow())
+			mock.ExpectQuery("SELECT.*FROM providers.*WHERE").WillReturnRows(sampleProviderRow())
+			mock.ExpectQuery("SELECT.*FROM provider_versions.*WHERE provider_id.*AND version").
+				WillReturnRows(sqlmock.NewRows(providerVersionGetCols).
+					AddRow("ver-1", "prov-1", "4.0.0", sampleProtocolsJSON, "", "", "", nil, nil, nil,
+						true, contractPublishedAt, "CVE-2024-0001, upgrade to 4.0.1", contractPublishedAt))
+			mock.ExpectQuery("SELECT approval_status FROM mirrored_provider_versions").
+				WillReturnRows(sqlmock.NewRows([]string{"approval_status"}).AddRow(nil))
+			mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id").WillReturnRows(samplePlatformRow())
+
+			w := doGET(r, "/v1/providers/hashicorp/aws/4.0.0/download/linux/amd64")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+			var resp ProviderDownloadResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if resp.Filename == "" || resp.DownloadURL == "" {
+				t.Errorf("download document incomplete: %+v", resp)
+			}
+			switch {
+			case !enabled && resp.Warnings != nil:
+				t.Errorf("warnings sent with the flag off: %q", resp.Warnings)
+			case enabled && (len(resp.Warnings) != 1 || resp.Warnings[0] != "4.0.0 is deprecated: CVE-2024-0001, upgrade to 4.0.1"):
+				t.Errorf("warnings = %q", resp.Warnings)
+			}
+		})
+	}
+}
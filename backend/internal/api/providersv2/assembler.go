@@ -0,0 +1,215 @@
+// assembler.go builds the JSON:API-style documents the v2 endpoints return
+// from repository rows.
+package providersv2
+
+import (
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// Resource type names.
+const (
+	typeProvider         = "providers"
+	typeProviderVersion  = "provider-versions"
+	typeProviderPlatform = "provider-platforms"
+	typeGPGKey           = "gpg-keys"
+)
+
+// ResourceIdentifier names a resource in a relationship.
+type ResourceIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Relationship links a resource to a single related resource or a list of
+// them. Data is a *ResourceIdentifier (null when there is none) or a
+// []ResourceIdentifier.
+type Relationship struct {
+	Data interface{} `json:"data"`
+}
+
+// Resource is one provider, provider version, platform or GPG key.
+type Resource struct {
+	Type          string                  `json:"type"`
+	ID            string                  `json:"id"`
+	Attributes    map[string]interface{}  `json:"attributes"`
+	Relationships map[string]Relationship `json:"relationships,omitempty"`
+}
+
+// Pagination describes the page a list document holds.
+type Pagination struct {
+	PageSize    int  `json:"page_size"`
+	CurrentPage int  `json:"current_page"`
+	NextPage    *int `json:"next_page"`
+	PrevPage    *int `json:"prev_page"`
+	TotalPages  int  `json:"total_pages"`
+	TotalCount  int  `json:"total_count"`
+}
+
+// ListMeta is the meta member of a list document.
+type ListMeta struct {
+	Pagination Pagination `json:"pagination"`
+}
+
+// ListDocument is returned by GET /api/v2/providers.
+type ListDocument struct {
+	Data     []Resource `json:"data"`
+	Included []Resource `json:"included,omitempty"`
+	Meta     ListMeta   `json:"meta"`
+}
+
+// Document is returned by GET /api/v2/providers/{namespace}/{type}.
+type Document struct {
+	Data     Resource   `json:"data"`
+	Included []Resource `json:"included,omitempty"`
+}
+
+// gpgKey is a public key that verifies a provider version's SHA256SUMS.
+type gpgKey struct {
+	KeyID      string
+	ASCIIArmor string
+}
+
+// providerData is everything loaded for one provider. Only what the request's
+// selection needs is set.
+type providerData struct {
+	provider  *models.ProviderSearchResult
+	upstream  *models.ProviderUpstreamMetadata
+	latest    *models.ProviderVersion
+	platforms []*models.ProviderPlatform
+	keys      []gpgKey
+}
+
+// assembler turns providerData into resources, collecting the related
+// resources the selection includes. Included resources are listed once even
+// when several providers relate to them (a GPG key shared by a namespace).
+type assembler struct {
+	sel      selection
+	included []Resource
+	seen     map[ResourceIdentifier]bool
+}
+
+func newAssembler(sel selection) *assembler {
+	return &assembler{sel: sel, seen: map[ResourceIdentifier]bool{}}
+}
+
+// provider returns the provider's resource with the requested attributes and
+// a relationship for each include.
+func (a *assembler) provider(d providerData) Resource {
+	p := d.provider
+	attrs := map[string]interface{}{}
+	set := func(field string, v interface{}) {
+		if a.sel.wants(field) {
+			attrs[field] = v
+		}
+	}
+	set("namespace", p.Namespace)
+	set("type", p.Type)
+	set("description", p.Description)
+	set("source", p.Source)
+	var tier *string
+	if p.Tier != "" {
+		tier = &p.Tier
+	}
+	set("tier", tier)
+	var sourceURL, upstreamTier, license *string
+	if d.upstream != nil {
+		sourceURL, upstreamTier, license = d.upstream.SourceURL, d.upstream.UpstreamTier, d.upstream.License
+	}
+	set("source_url", sourceURL)
+	set("upstream_tier", upstreamTier)
+	set("license", license)
+	set("downloads", p.TotalDownloads)
+	var latestVersion *string
+	if d.latest != nil {
+		latestVersion = &d.latest.Version
+	}
+	set("latest_version", latestVersion)
+	set("created_at", p.CreatedAt)
+	set("updated_at", p.UpdatedAt)
+
+	res := Resource{Type: typeProvider, ID: p.ID, Attributes: attrs}
+	if len(a.sel.includes) == 0 {
+		return res
+	}
+	res.Relationships = map[string]Relationship{}
+	if a.sel.includes[includeLatestVersion] {
+		var id *ResourceIdentifier
+		if d.latest != nil {
+			id = a.include(versionResource(d.latest))
+		}
+		res.Relationships[includeLatestVersion] = Relationship{Data: id}
+	}
+	if a.sel.includes[includePlatforms] {
+		ids := []ResourceIdentifier{}
+		for _, pl := range d.platforms {
+			ids = append(ids, *a.include(platformResource(pl)))
+		}
+		res.Relationships[includePlatforms] = Relationship{Data: ids}
+	}
+	if a.sel.includes[includeGPGKeys] {
+		ids := []ResourceIdentifier{}
+		for _, k := range d.keys {
+			ids = append(ids, *a.include(gpgKeyResource(k)))
+		}
+		res.Relationships[includeGPGKeys] = Relationship{Data: ids}
+	}
+	return res
+}
+
+// include adds r to the included resources unless it is already there, and
+// returns its identifier.
+func (a *assembler) include(r Resource) *ResourceIdentifier {
+	id := ResourceIdentifier{Type: r.Type, ID: r.ID}
+	if !a.seen[id] {
+		a.seen[id] = true
+		a.included = append(a.included, r)
+	}
+	return &id
+}
+
+func versionResource(v *models.ProviderVersion) Resource {
+	attrs := map[string]interface{}{
+		"version":      v.Version,
+		"protocols":    v.Protocols,
+		"published_at": v.CreatedAt.UTC().Format(time.RFC3339),
+		"deprecated":   v.Deprecated,
+	}
+	if v.DeprecationMessage != nil {
+		attrs["deprecation_message"] = *v.DeprecationMessage
+	}
+	return Resource{Type: typeProviderVersion, ID: v.ID, Attributes: attrs}
+}
+
+func platformResource(p *models.ProviderPlatform) Resource {
+	return Resource{Type: typeProviderPlatform, ID: p.ID, Attributes: map[string]interface{}{
+		"os":        p.OS,
+		"arch":      p.Arch,
+		"filename":  p.Filename,
+		"shasum":    p.Shasum,
+		"downloads": p.DownloadCount,
+	}}
+}
+
+func gpgKeyResource(k gpgKey) Resource {
+	return Resource{Type: typeGPGKey, ID: k.KeyID, Attributes: map[string]interface{}{
+		"key_id":      k.KeyID,
+		"ascii_armor": k.ASCIIArmor,
+	}}
+}
+
+// pagination describes page q of a list of total results.
+func pagination(q listQuery, total int) Pagination {
+	pages := (total + q.pageSize - 1) / q.pageSize
+	p := Pagination{PageSize: q.pageSize, CurrentPage: q.pageNumber, TotalPages: pages, TotalCount: total}
+	if q.pageNumber < pages {
+		next := q.pageNumber + 1
+		p.NextPage = &next
+	}
+	if q.pageNumber > 1 {
+		prev := q.pageNumber - 1
+		p.PrevPage = &prev
+	}
+	return p
+}
@@ -0,0 +1,287 @@
+// Package providersv2 serves the read-only /api/v2/providers surface for the
+// web UI. Unlike the fixed-shape /api/v1 endpoints, a request picks the
+// provider attributes it needs (?fields=) and pulls the latest version, its
+// platforms and its GPG keys into the same response (?include=), in a
+// JSON:API-style document loosely modeled on the public registry's v2 API.
+// It is a thin layer over ProviderRepository: every include is one batched
+// query for the whole page, never one per provider.
+package providersv2
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// providerSource is the subset of *repositories.ProviderRepository the
+// handlers read from.
+type providerSource interface {
+	SearchProvidersWithStats(ctx context.Context, orgID, ownerOrgID, searchQuery, namespace, tier string, limit, offset int, sortField, sortOrder string) ([]*models.ProviderSearchResult, int, error)
+	GetProvider(ctx context.Context, orgID, namespace, providerType string) (*models.Provider, error)
+	GetTotalDownloadCount(ctx context.Context, providerID string) (int64, error)
+	ListUpstreamMetadata(ctx context.Context, providerIDs []string) (map[string]*models.ProviderUpstreamMetadata, error)
+	ListTiers(ctx context.Context, providerIDs []string) (map[string]string, error)
+	ListLatestVisibleVersions(ctx context.Context, providerIDs []string) (map[string]*models.ProviderVersion, error)
+	ListPlatformsByVersions(ctx context.Context, versionIDs []string) (map[string][]*models.ProviderPlatform, error)
+	ListVersionResignatures(ctx context.Context, versionIDs []string) (map[string]*models.ProviderVersionResignature, error)
+}
+
+// organizationSource resolves the default organization.
+type organizationSource interface {
+	GetDefaultOrganization(ctx context.Context) (*models.Organization, error)
+}
+
+// Handlers serves the v2 provider endpoints.
+type Handlers struct {
+	cfg       *config.Config
+	providers providerSource
+	orgs      organizationSource
+}
+
+// NewHandlers creates the v2 provider handlers.
+func NewHandlers(db *sql.DB, cfg *config.Config) *Handlers {
+	return &Handlers{
+		cfg:       cfg,
+		providers: repositories.NewProviderRepository(db),
+		orgs:      repositories.NewOrganizationRepository(db),
+	}
+}
+
+// @Summary      List providers (v2)
+// @Description  Lists providers as a JSON:API-style document. `fields` limits the provider attributes returned (namespace, type, description, source, tier, source_url, upstream_tier, license, downloads, latest_version, created_at, updated_at; default all). tier is the registry-assigned tier; upstream_tier the tier the upstream registry reports for a mirrored provider. `filter[tier]` lists only providers of one tier, accepting verified and partner as aliases of approved. `include` adds the latest version, its platforms and its GPG signing keys as related resources in `included` (latest_version, platforms, gpg_keys). The latest version is the highest version visible to Terraform clients; mirrored versions pending approval or rejected are skipped. On a custom tenant domain only the tenant organization's namespaces are listed unless scope=all.
+// @Tags         Providers
+// @Produce      json
+// @Param        fields             query  string  false  "Comma-separated provider attributes to return"
+// @Param        include            query  string  false  "Comma-separated related resources: latest_version, platforms, gpg_keys"
+// @Param        q                  query  string  false  "Search query"
+// @Param        filter[namespace]  query  string  false  "Filter by namespace"
+// @Param        filter[tier]       query  string  false  "Filter by tier (e.g. approved)"
+// @Param        sort               query  string  false  "relevance, name, downloads, created or updated; prefix with - for descending order (default: relevance for searches, else newest first)"
+// @Param        scope              query  string  false  "all to list every namespace on a custom tenant domain"
+// @Param        page[size]         query  int     false  "Results per page (default 20, max 100)"
+// @Param        page[number]       query  int     false  "Page number (default 1)"
+// @Success      200  {object}  providersv2.ListDocument
+// @Failure      400  {object}  map[string]interface{}  "Unknown field or include, or invalid sort or tier"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v2/providers [get]
+// List serves GET /api/v2/providers.
+func (h *Handlers) List() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q, err := parseListQuery(c.Request.URL.Query())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx := c.Request.Context()
+
+		orgID, ok := h.organizationID(c)
+		if !ok {
+			return
+		}
+		// On a custom tenant domain, list only the tenant organization's
+		// namespaces unless every namespace is asked for.
+		var ownerOrgID string
+		if !q.scopeAll {
+			ownerOrgID = middleware.TenantOrganizationID(c)
+		}
+
+		found, total, err := h.providers.SearchProvidersWithStats(ctx, orgID, ownerOrgID, q.search, q.namespace, q.tier,
+			q.pageSize, q.offset(), q.sortField, q.sortOrder)
+		if err != nil {
+			slog.Error("v2: failed to list providers", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list providers"})
+			return
+		}
+
+		data, err := h.load(ctx, q.selection, found)
+		if err != nil {
+			slog.Error("v2: failed to load provider relations", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list providers"})
+			return
+		}
+		a := newAssembler(q.selection)
+		doc := ListDocument{Data: make([]Resource, 0, len(data)), Meta: ListMeta{Pagination: pagination(q, total)}}
+		for _, d := range data {
+			doc.Data = append(doc.Data, a.provider(d))
+		}
+		doc.Included = a.included
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// @Summary      Get provider (v2)
+// @Description  Returns one provider as a JSON:API-style document, with the same `fields` and `include` parameters as the v2 provider list.
+// @Tags         Providers
+// @Produce      json
+// @Param        namespace  path   string  true   "Provider namespace"
+// @Param        type       path   string  true   "Provider type"
+// @Param        fields     query  string  false  "Comma-separated provider attributes to return"
+// @Param        include    query  string  false  "Comma-separated related resources: latest_version, platforms, gpg_keys"
+// @Success      200  {object}  providersv2.Document
+// @Failure      400  {object}  map[string]interface{}  "Unknown field or include"
+// @Failure      404  {object}  map[string]interface{}  "Provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v2/providers/{namespace}/{type} [get]
+// Get serves GET /api/v2/providers/:namespace/:type.
+func (h *Handlers) Get() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sel, err := parseSelection(c.Request.URL.Query())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx := c.Request.Context()
+
+		orgID, ok := h.organizationID(c)
+		if !ok {
+			return
+		}
+		provider, err := h.providers.GetProvider(ctx, orgID, c.Param("namespace"), c.Param("type"))
+		if err != nil {
+			slog.Error("v2: failed to get provider", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider"})
+			return
+		}
+		if provider == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+			return
+		}
+		result := &models.ProviderSearchResult{Provider: *provider}
+		if sel.wants("downloads") {
+			if result.TotalDownloads, err = h.providers.GetTotalDownloadCount(ctx, provider.ID); err != nil {
+				slog.Error("v2: failed to get provider downloads", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider"})
+				return
+			}
+		}
+
+		data, err := h.load(ctx, sel, []*models.ProviderSearchResult{result})
+		if err != nil {
+			slog.Error("v2: failed to load provider relations", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get provider"})
+			return
+		}
+		a := newAssembler(sel)
+		doc := Document{Data: a.provider(data[0])}
+		doc.Included = a.included
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// organizationID returns the organization providers are looked up in: the
+// default organization in multi-tenant mode, and "" (every organization)
+// otherwise. On failure it answers the request and returns false.
+func (h *Handlers) organizationID(c *gin.Context) (string, bool) {
+	if !h.cfg.MultiTenancy.Enabled {
+		return "", true
+	}
+	org, err := h.orgs.GetDefaultOrganization(c.Request.Context())
+	if err != nil || org == nil {
+		slog.Error("v2: failed to resolve the default organization", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return "", false
+	}
+	return org.ID, true
+}
+
+// load fetches what sel needs for the providers, one batched query per kind
+// of data.
+func (h *Handlers) load(ctx context.Context, sel selection, found []*models.ProviderSearchResult) ([]providerData, error) {
+	data := make([]providerData, len(found))
+	ids := make([]string, len(found))
+	for i, p := range found {
+		data[i].provider = p
+		ids[i] = p.ID
+	}
+
+	if sel.needsUpstream() {
+		// Upstream metadata only exists for mirrored providers; failing to
+		// load it leaves those attributes null rather than failing the
+		// request, as on /api/v1/providers/search.
+		upstream, err := h.providers.ListUpstreamMetadata(ctx, ids)
+		if err != nil {
+			slog.Warn("v2: failed to load provider upstream metadata", "error", err)
+		}
+		for i := range data {
+			data[i].upstream = upstream[data[i].provider.ID]
+		}
+	}
+	if sel.wants("tier") {
+		// Tiers degrade the same way.
+		tiers, err := h.providers.ListTiers(ctx, ids)
+		if err != nil {
+			slog.Warn("v2: failed to load provider tiers", "error", err)
+		}
+		for i := range data {
+			data[i].provider.Tier = tiers[data[i].provider.ID]
+		}
+	}
+	if !sel.needsLatestVersion() {
+		return data, nil
+	}
+
+	latest, err := h.providers.ListLatestVisibleVersions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	var versionIDs []string
+	for i := range data {
+		if v := latest[data[i].provider.ID]; v != nil {
+			data[i].latest = v
+			versionIDs = append(versionIDs, v.ID)
+		}
+	}
+
+	if sel.includes[includePlatforms] {
+		platforms, err := h.providers.ListPlatformsByVersions(ctx, versionIDs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range data {
+			if data[i].latest != nil {
+				data[i].platforms = platforms[data[i].latest.ID]
+			}
+		}
+	}
+	if sel.includes[includeGPGKeys] {
+		resignatures, err := h.providers.ListVersionResignatures(ctx, versionIDs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range data {
+			if v := data[i].latest; v != nil {
+				data[i].keys = signingKeys(v, resignatures[v.ID])
+			}
+		}
+	}
+	return data, nil
+}
+
+// signingKeys returns the key Terraform is given to verify the version's
+// SHA256SUMS, chosen as GET /v1/providers/.../download does: the registry's
+// own key when the version was re-signed, else the version's key with an
+// expired upstream key swapped for its refreshed copy.
+func signingKeys(v *models.ProviderVersion, rs *models.ProviderVersionResignature) []gpgKey {
+	if rs != nil {
+		return []gpgKey{{KeyID: rs.KeyID, ASCIIArmor: rs.GPGPublicKey}}
+	}
+	if v.GPGPublicKey == "" {
+		return nil
+	}
+	armored := mirror.ResolveExpiredGPGKey(v.GPGPublicKey)
+	keyID, err := validation.ExtractKeyID(armored)
+	if err != nil || keyID == "" {
+		slog.Warn("v2: failed to extract GPG key_id for provider signing key", "version_id", v.ID, "error", err)
+		return nil
+	}
+	return []gpgKey{{KeyID: keyID, ASCIIArmor: armored}}
+}
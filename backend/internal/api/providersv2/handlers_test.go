@@ -0,0 +1,249 @@
+package providersv2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var created = time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+type searchCall struct {
+	namespace, tier, query, sortField, sortOrder string
+	limit, offset                                int
+}
+
+// fakeProviders serves two providers: acme/cloud with a re-signed latest
+// version on two platforms, and acme/empty with no visible version.
+type fakeProviders struct {
+	err      error
+	searches []searchCall
+	// calls counts every batched lookup by method name.
+	calls map[string]int
+}
+
+func newFakeProviders() *fakeProviders { return &fakeProviders{calls: map[string]int{}} }
+
+func str(s string) *string { return &s }
+
+func (f *fakeProviders) results() []*models.ProviderSearchResult {
+	return []*models.ProviderSearchResult{
+		{Provider: models.Provider{ID: "p1", Namespace: "acme", Type: "cloud", Description: str("Cloud"), CreatedAt: created, UpdatedAt: created}, TotalDownloads: 42},
+		{Provider: models.Provider{ID: "p2", Namespace: "acme", Type: "empty", CreatedAt: created, UpdatedAt: created}},
+	}
+}
+
+func (f *fakeProviders) SearchProvidersWithStats(_ context.Context, _, _, q, namespace, tier string, limit, offset int, sortField, sortOrder string) ([]*models.ProviderSearchResult, int, error) {
+	f.searches = append(f.searches, searchCall{namespace, tier, q, sortField, sortOrder, limit, offset})
+	return f.results(), 45, f.err
+}
+
+func (f *fakeProviders) GetProvider(_ context.Context, _, namespace, providerType string) (*models.Provider, error) {
+	for _, p := range f.results() {
+		if p.Namespace == namespace && p.Type == providerType {
+			return &p.Provider, nil
+		}
+	}
+	return nil, f.err
+}
+
+func (f *fakeProviders) GetTotalDownloadCount(context.Context, string) (int64, error) {
+	f.calls["downloads"]++
+	return 42, nil
+}
+
+func (f *fakeProviders) ListUpstreamMetadata(context.Context, []string) (map[string]*models.ProviderUpstreamMetadata, error) {
+	f.calls["upstream"]++
+	return map[string]*models.ProviderUpstreamMetadata{"p1": {UpstreamTier: str("partner")}}, nil
+}
+
+func (f *fakeProviders) ListTiers(context.Context, []string) (map[string]string, error) {
+	f.calls["tiers"]++
+	return map[string]string{"p1": models.TierApproved, "p2": models.TierCommunity}, nil
+}
+
+func (f *fakeProviders) ListLatestVisibleVersions(_ context.Context, ids []string) (map[string]*models.ProviderVersion, error) {
+	f.calls["versions"]++
+	return map[string]*models.ProviderVersion{
+		"p1": {ID: "v1", ProviderID: "p1", Version: "1.2.0", Protocols: []string{"6.0"}, CreatedAt: created},
+	}, f.err
+}
+
+func (f *fakeProviders) ListPlatformsByVersions(_ context.Context, ids []string) (map[string][]*models.ProviderPlatform, error) {
+	f.calls["platforms"]++
+	return map[string][]*models.ProviderPlatform{"v1": {
+		{ID: "pl1", OS: "darwin", Arch: "arm64", Filename: "a.zip", Shasum: "aa"},
+		{ID: "pl2", OS: "linux", Arch: "amd64", Filename: "b.zip", Shasum: "bb", DownloadCount: 7},
+	}}, nil
+}
+
+func (f *fakeProviders) ListVersionResignatures(context.Context, []string) (map[string]*models.ProviderVersionResignature, error) {
+	f.calls["keys"]++
+	return map[string]*models.ProviderVersionResignature{"v1": {ProviderVersionID: "v1", KeyID: "ABCDEF0123456789", GPGPublicKey: "armored"}}, nil
+}
+
+func newTestRouter(src *fakeProviders) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &Handlers{cfg: &config.Config{}, providers: src}
+	r := gin.New()
+	r.GET("/api/v2/providers", h.List())
+	r.GET("/api/v2/providers/:namespace/:type", h.Get())
+	return r
+}
+
+func get(t *testing.T, r http.Handler, path string) (int, map[string]interface{}) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET %s: invalid JSON %q", path, w.Body)
+	}
+	return w.Code, body
+}
+
+func TestList_SparseFieldsetSkipsUnneededQueries(t *testing.T) {
+	src := newFakeProviders()
+	code, body := get(t, newTestRouter(src), "/api/v2/providers?fields=namespace,type")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body %v", code, body)
+	}
+	first := body["data"].([]interface{})[0].(map[string]interface{})
+	attrs := first["attributes"].(map[string]interface{})
+	if len(attrs) != 2 || attrs["namespace"] != "acme" || attrs["type"] != "cloud" {
+		t.Errorf("attributes = %v, want only namespace and type", attrs)
+	}
+	if first["id"] != "p1" || first["type"] != "providers" || first["relationships"] != nil {
+		t.Errorf("resource = %v", first)
+	}
+	if _, ok := body["included"]; ok {
+		t.Error("included present without ?include")
+	}
+	if len(src.calls) != 0 {
+		t.Errorf("lookups = %v, want none for namespace and type only", src.calls)
+	}
+}
+
+func TestList_Includes(t *testing.T) {
+	src := newFakeProviders()
+	code, body := get(t, newTestRouter(src),
+		"/api/v2/providers?fields=latest_version,tier,upstream_tier&include=latest_version,platforms,gpg_keys")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body %v", code, body)
+	}
+	data := body["data"].([]interface{})
+	cloud, empty := data[0].(map[string]interface{}), data[1].(map[string]interface{})
+
+	if attrs := cloud["attributes"].(map[string]interface{}); attrs["latest_version"] != "1.2.0" || attrs["tier"] != "approved" || attrs["upstream_tier"] != "partner" {
+		t.Errorf("acme/cloud attributes = %v", attrs)
+	}
+	rel := cloud["relationships"].(map[string]interface{})
+	latest := rel["latest_version"].(map[string]interface{})["data"].(map[string]interface{})
+	if latest["type"] != "provider-versions" || latest["id"] != "v1" {
+		t.Errorf("latest_version relationship = %v", latest)
+	}
+	if n := len(rel["platforms"].(map[string]interface{})["data"].([]interface{})); n != 2 {
+		t.Errorf("platforms = %d, want 2", n)
+	}
+	if keys := rel["gpg_keys"].(map[string]interface{})["data"].([]interface{}); len(keys) != 1 || keys[0].(map[string]interface{})["id"] != "ABCDEF0123456789" {
+		t.Errorf("gpg_keys = %v, want the re-signing key", keys)
+	}
+
+	// A provider without a visible version relates to nothing.
+	emptyRel := empty["relationships"].(map[string]interface{})
+	if emptyRel["latest_version"].(map[string]interface{})["data"] != nil || len(emptyRel["platforms"].(map[string]interface{})["data"].([]interface{})) != 0 {
+		t.Errorf("acme/empty relationships = %v", emptyRel)
+	}
+	if attrs := empty["attributes"].(map[string]interface{}); attrs["latest_version"] != nil {
+		t.Errorf("acme/empty latest_version = %v, want null", attrs["latest_version"])
+	}
+
+	if n := len(body["included"].([]interface{})); n != 4 {
+		t.Errorf("included = %d resources, want version + 2 platforms + key", n)
+	}
+	for _, kind := range []string{"upstream", "tiers", "versions", "platforms", "keys"} {
+		if src.calls[kind] != 1 {
+			t.Errorf("%s lookups = %d, want one batched query", kind, src.calls[kind])
+		}
+	}
+}
+
+func TestList_FilterSortAndPagination(t *testing.T) {
+	src := newFakeProviders()
+	code, body := get(t, newTestRouter(src), "/api/v2/providers?fields=type&q=clo&filter[namespace]=acme&filter[tier]=verified&sort=-downloads&page[size]=10&page[number]=3")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body %v", code, body)
+	}
+	want := searchCall{namespace: "acme", tier: models.TierApproved, query: "clo", sortField: "downloads", sortOrder: "desc", limit: 10, offset: 20}
+	if src.searches[0] != want {
+		t.Errorf("search = %+v, want %+v", src.searches[0], want)
+	}
+	p := body["meta"].(map[string]interface{})["pagination"].(map[string]interface{})
+	if p["total_pages"] != 5.0 || p["current_page"] != 3.0 || p["next_page"] != 4.0 || p["prev_page"] != 2.0 || p["total_count"] != 45.0 {
+		t.Errorf("pagination = %v", p)
+	}
+
+	get(t, newTestRouter(src), "/api/v2/providers?sort=name&page[size]=500&page[number]=-1")
+	want = searchCall{sortField: "name", sortOrder: "asc", limit: defaultPageSize}
+	if src.searches[1] != want {
+		t.Errorf("search = %+v, want %+v (out-of-range pages fall back to defaults)", src.searches[1], want)
+	}
+}
+
+func TestList_Errors(t *testing.T) {
+	for _, query := range []string{"fields=name", "include=versions", "sort=stars", "sort=-", "filter[tier]=first_party"} {
+		if code, _ := get(t, newTestRouter(newFakeProviders()), "/api/v2/providers?"+query); code != http.StatusBadRequest {
+			t.Errorf("?%s: status = %d, want 400", query, code)
+		}
+	}
+
+	src := newFakeProviders()
+	src.err = errors.New("db down")
+	if code, _ := get(t, newTestRouter(src), "/api/v2/providers"); code != http.StatusInternalServerError {
+		t.Errorf("query failure: status = %d, want 500", code)
+	}
+}
+
+func TestGet(t *testing.T) {
+	src := newFakeProviders()
+	r := newTestRouter(src)
+	code, body := get(t, r, "/api/v2/providers/acme/cloud?fields=downloads,latest_version&include=latest_version")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body %v", code, body)
+	}
+	data := body["data"].(map[string]interface{})
+	if attrs := data["attributes"].(map[string]interface{}); attrs["downloads"] != 42.0 || attrs["latest_version"] != "1.2.0" {
+		t.Errorf("attributes = %v", attrs)
+	}
+	included := body["included"].([]interface{})
+	if len(included) != 1 || included[0].(map[string]interface{})["attributes"].(map[string]interface{})["published_at"] != "2026-03-14T09:30:00Z" {
+		t.Errorf("included = %v", included)
+	}
+
+	if code, _ := get(t, r, "/api/v2/providers/acme/missing"); code != http.StatusNotFound {
+		t.Errorf("unknown provider: status = %d, want 404", code)
+	}
+}
+
+func TestParseListQuery_Defaults(t *testing.T) {
+	q, err := parseListQuery(url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.fields != nil || len(q.includes) != 0 || q.sortField != "" || q.sortOrder != "desc" || q.pageSize != defaultPageSize || q.offset() != 0 {
+		t.Errorf("defaults = %+v", q)
+	}
+	if !q.wants("license") || !q.needsLatestVersion() || !q.needsUpstream() {
+		t.Error("every attribute should be selected by default")
+	}
+}
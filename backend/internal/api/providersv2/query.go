@@ -0,0 +1,175 @@
+// query.go parses the query parameters of the /api/v2/providers endpoints:
+// sparse fieldsets, includes, filters, sort and pagination.
+package providersv2
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// Pagination defaults and bounds, the same as /api/v1/providers/search.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	// maxPageNumber keeps the row offset within an int32.
+	maxPageNumber = math.MaxInt32 / maxPageSize
+)
+
+// providerFields lists the provider attributes, in response order.
+var providerFields = []string{
+	"namespace", "type", "description", "source", "tier", "source_url", "upstream_tier",
+	"license", "downloads", "latest_version", "created_at", "updated_at",
+}
+
+// Include names. Platforms and GPG keys are those of the latest version.
+const (
+	includeLatestVersion = "latest_version"
+	includePlatforms     = "platforms"
+	includeGPGKeys       = "gpg_keys"
+)
+
+var supportedIncludes = []string{includeLatestVersion, includePlatforms, includeGPGKeys}
+
+// sortFields maps the v2 sort names to the sort fields of
+// ProviderRepository.SearchProvidersWithStats.
+var sortFields = map[string]string{
+	"relevance": "relevance",
+	"name":      "name",
+	"downloads": "downloads",
+	"created":   "created",
+	"updated":   "updated",
+}
+
+// selection is the part of a request shared by the list and show endpoints:
+// which provider attributes to return and which related resources to include.
+type selection struct {
+	// fields is nil when every attribute is requested.
+	fields   map[string]bool
+	includes map[string]bool
+}
+
+// wants reports whether the provider attribute is requested.
+func (s selection) wants(field string) bool {
+	return s.fields == nil || s.fields[field]
+}
+
+// needsLatestVersion reports whether the latest version has to be loaded.
+func (s selection) needsLatestVersion() bool {
+	return s.wants("latest_version") || len(s.includes) > 0
+}
+
+// needsUpstream reports whether mirror-sync metadata has to be loaded.
+func (s selection) needsUpstream() bool {
+	return s.wants("source_url") || s.wants("upstream_tier") || s.wants("license")
+}
+
+// listQuery is a parsed GET /api/v2/providers request.
+type listQuery struct {
+	selection
+	namespace  string
+	tier       string
+	search     string
+	sortField  string
+	sortOrder  string
+	scopeAll   bool
+	pageSize   int
+	pageNumber int
+}
+
+func (q listQuery) offset() int {
+	return (q.pageNumber - 1) * q.pageSize
+}
+
+// parseSelection parses ?fields= and ?include=. Unknown names are an error.
+func parseSelection(v url.Values) (selection, error) {
+	var s selection
+	if raw := v.Get("fields"); strings.TrimSpace(raw) != "" {
+		fields, err := parseList("field", raw, providerFields)
+		if err != nil {
+			return s, err
+		}
+		s.fields = fields
+	}
+	includes, err := parseList("include", v.Get("include"), supportedIncludes)
+	if err != nil {
+		return s, err
+	}
+	s.includes = includes
+	return s, nil
+}
+
+// parseListQuery parses the list endpoint's parameters. Malformed page
+// parameters fall back to their defaults, as on /api/v1/providers/search.
+func parseListQuery(v url.Values) (listQuery, error) {
+	sel, err := parseSelection(v)
+	if err != nil {
+		return listQuery{}, err
+	}
+	q := listQuery{
+		selection:  sel,
+		namespace:  v.Get("filter[namespace]"),
+		search:     v.Get("q"),
+		scopeAll:   v.Get("scope") == "all",
+		sortOrder:  "desc",
+		pageSize:   defaultPageSize,
+		pageNumber: 1,
+	}
+
+	if raw := v.Get("filter[tier]"); raw != "" {
+		tier, err := models.NormalizeTier(raw)
+		if err != nil {
+			return listQuery{}, err
+		}
+		q.tier = tier
+	}
+
+	if raw := v.Get("sort"); raw != "" {
+		name := strings.TrimPrefix(raw, "-")
+		field, ok := sortFields[name]
+		if !ok {
+			return listQuery{}, fmt.Errorf("invalid sort %q (expected one of: relevance, name, downloads, created, updated, optionally prefixed with - for descending order)", raw)
+		}
+		q.sortField = field
+		q.sortOrder = "asc"
+		if strings.HasPrefix(raw, "-") {
+			q.sortOrder = "desc"
+		}
+	}
+
+	if n, err := strconv.Atoi(v.Get("page[size]")); err == nil && n >= 1 && n <= maxPageSize {
+		q.pageSize = n
+	}
+	if n, err := strconv.Atoi(v.Get("page[number]")); err == nil && n >= 1 && n <= maxPageNumber {
+		q.pageNumber = n
+	}
+	return q, nil
+}
+
+// parseList splits a comma-separated list of names, each of which must be in
+// supported.
+func parseList(kind, raw string, supported []string) (map[string]bool, error) {
+	names := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, s := range supported {
+			if s == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown %s %q (expected any of: %s)", kind, name, strings.Join(supported, ", "))
+		}
+		names[name] = true
+	}
+	return names, nil
+}
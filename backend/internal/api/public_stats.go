@@ -0,0 +1,99 @@
+// public_stats.go serves GET /api/v1/stats/public: unauthenticated,
+// registry-wide counts for status pages, recomputed at most once per refresh
+// interval per process.
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// defaultPublicStatsRefresh applies when public_stats.refresh_interval is 0.
+const defaultPublicStatsRefresh = 5 * time.Minute
+
+// supportedProtocols are the registry protocol versions this build serves.
+var supportedProtocols = ProtocolVersions{Modules: "v1", Providers: "v1", Mirror: "v1"}
+
+// publicStatsSource computes public statistics.
+// *repositories.PublicStatsRepository satisfies it.
+type publicStatsSource interface {
+	Get(ctx context.Context) (*models.PublicStats, error)
+}
+
+// publicStatsCache holds the last computed statistics for refresh. Requests
+// that find them stale recompute them one at a time; the rest wait for that
+// result rather than querying too.
+type publicStatsCache struct {
+	source  publicStatsSource
+	refresh time.Duration
+	now     func() time.Time
+
+	mu         sync.Mutex
+	stats      *models.PublicStats
+	computedAt time.Time
+}
+
+func newPublicStatsCache(source publicStatsSource, refresh time.Duration) *publicStatsCache {
+	if refresh <= 0 {
+		refresh = defaultPublicStatsRefresh
+	}
+	return &publicStatsCache{source: source, refresh: refresh, now: time.Now}
+}
+
+// get returns the cached statistics, recomputing them when they are older
+// than the refresh interval. When recomputing fails, the previous statistics
+// are served (and retried on the next request) if there are any.
+func (c *publicStatsCache) get(ctx context.Context) (*models.PublicStats, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats != nil && c.now().Sub(c.computedAt) < c.refresh {
+		return c.stats, c.computedAt, nil
+	}
+	stats, err := c.source.Get(ctx)
+	if err != nil {
+		if c.stats != nil {
+			slog.Warn("failed to refresh public stats; serving the previous result",
+				"computed_at", c.computedAt, "error", err)
+			return c.stats, c.computedAt, nil
+		}
+		return nil, time.Time{}, err
+	}
+	c.stats, c.computedAt = stats, c.now()
+	return c.stats, c.computedAt, nil
+}
+
+// @Summary      Public registry statistics
+// @Description  Returns registry-wide counts for status pages: modules, providers and their versions, mirrored providers, synced binary mirror versions, and when the last successful provider and binary mirror syncs finished, with the serving version and protocol versions. No organization or namespace breakdown is included. Results are computed at most once per public_stats.refresh_interval (5 minutes by default) and sent with a matching Cache-Control max-age. Not registered when public_stats.enabled is false.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object}  api.PublicStatsResponse
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/stats/public [get]
+// publicStatsHandler serves GET /api/v1/stats/public.
+func publicStatsHandler(cache *publicStatsCache) gin.HandlerFunc {
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(cache.refresh.Seconds()))
+	return func(c *gin.Context) {
+		stats, computedAt, err := cache.get(c.Request.Context())
+		if err != nil {
+			slog.Error("failed to compute public stats", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load statistics"})
+			return
+		}
+		c.Header("Cache-Control", cacheControl)
+		c.JSON(http.StatusOK, PublicStatsResponse{
+			PublicStats: *stats,
+			Version:     AppVersion,
+			APIVersion:  "v1",
+			Protocols:   supportedProtocols,
+			GeneratedAt: computedAt.UTC(),
+		})
+	}
+}
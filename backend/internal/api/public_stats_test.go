@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+type fakeStatsSource struct {
+	calls int
+	err   error
+}
+
+func (f *fakeStatsSource) Get(context.Context) (*models.PublicStats, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.PublicStats{Modules: int64(f.calls), Providers: 3}, nil
+}
+
+func getPublicStats(t *testing.T, r *gin.Engine) (*httptest.ResponseRecorder, PublicStatsResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/stats/public", nil))
+	var resp PublicStatsResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+	}
+	return w, resp
+}
+
+func TestPublicStatsHandler_CachesUntilRefresh(t *testing.T) {
+	src := &fakeStatsSource{}
+	cache := newPublicStatsCache(src, time.Minute)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	r := gin.New()
+	r.GET("/api/v1/stats/public", publicStatsHandler(cache))
+
+	w, resp := getPublicStats(t, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+	if resp.Modules != 1 || resp.Providers != 3 || resp.Protocols.Modules != "v1" || resp.APIVersion != "v1" {
+		t.Errorf("response = %+v", resp)
+	}
+
+	now = now.Add(30 * time.Second)
+	if _, resp := getPublicStats(t, r); resp.Modules != 1 || src.calls != 1 {
+		t.Errorf("within refresh interval: modules = %d, calls = %d; want cached result", resp.Modules, src.calls)
+	}
+
+	now = now.Add(time.Minute)
+	if _, resp := getPublicStats(t, r); resp.Modules != 2 || !resp.GeneratedAt.Equal(now) {
+		t.Errorf("after refresh interval: modules = %d, generated_at = %v; want recomputed", resp.Modules, resp.GeneratedAt)
+	}
+}
+
+func TestPublicStatsHandler_RefreshFailure(t *testing.T) {
+	src := &fakeStatsSource{err: errors.New("db down")}
+	cache := newPublicStatsCache(src, 0)
+	if cache.refresh != defaultPublicStatsRefresh {
+		t.Errorf("refresh = %v, want default %v", cache.refresh, defaultPublicStatsRefresh)
+	}
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	r := gin.New()
+	r.GET("/api/v1/stats/public", publicStatsHandler(cache))
+
+	if w, _ := getPublicStats(t, r); w.Code != http.StatusInternalServerError {
+		t.Fatalf("status without previous stats = %d, want 500", w.Code)
+	}
+
+	src.err = nil
+	if w, _ := getPublicStats(t, r); w.Code != http.StatusOK {
+		t.Fatalf("status after recovery = %d, want 200", w.Code)
+	}
+
+	src.err = errors.New("db down")
+	now = now.Add(2 * defaultPublicStatsRefresh)
+	w, resp := getPublicStats(t, r)
+	if w.Code != http.StatusOK || resp.Modules != 2 {
+		t.Errorf("failed refresh = (%d, modules %d), want previous stats served", w.Code, resp.Modules)
+	}
+}
@@ -0,0 +1,354 @@
+// Package remoteupload implements publish-from-URL for the module and provider
+// upload endpoints. Instead of streaming the archive through the client, a
+// caller sends a JSON body naming a source_url and the server downloads it.
+//
+// Downloads go through the SSRF-safe httpsafe client (resolve-and-pin, private
+// and link-local/metadata ranges denied unless allow-listed in
+// security.egress), restricted further by remote_upload.allowed_schemes and
+// remote_upload.allowed_hosts, and capped at the caller's size limit. An
+// optional auth header is never persisted or logged; from the moment the
+// request is bound until the outbound request is built it is held sealed under
+// a per-process key, and it is dropped on any redirect to a different host.
+package remoteupload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+)
+
+// defaultTimeout applies when remote_upload.timeout is unset.
+const defaultTimeout = 10 * time.Minute
+
+var (
+	// ErrDisabled is returned when remote_upload.enabled is false.
+	ErrDisabled = errors.New("publishing from a URL is not enabled on this registry")
+	// ErrTooLarge is returned when the remote archive exceeds the size limit.
+	ErrTooLarge = errors.New("remote archive exceeds the maximum upload size")
+	// ErrChecksumMismatch is returned when the downloaded archive does not
+	// match the caller-supplied checksum.
+	ErrChecksumMismatch = errors.New("remote archive checksum does not match")
+)
+
+// InvalidSourceError reports a source_url or auth header the policy rejects.
+type InvalidSourceError struct {
+	Reason string
+}
+
+func (e *InvalidSourceError) Error() string {
+	return "invalid source: " + e.Reason
+}
+
+// StatusError reports a non-2xx response from the remote server.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("remote server responded %s", e.Status)
+}
+
+// Source is the publish-from-URL part of a JSON upload body.
+type Source struct {
+	// SourceURL is the archive to download.
+	SourceURL string `json:"source_url"`
+	// Checksum is the expected SHA-256 of the archive (hex, optionally
+	// prefixed with "sha256:").
+	Checksum string `json:"checksum,omitempty"`
+	// AuthHeaderName and AuthHeaderValue are sent with the download request
+	// only, e.g. "Authorization" / "Bearer ...".
+	AuthHeaderName  string `json:"auth_header_name,omitempty"`
+	AuthHeaderValue string `json:"auth_header_value,omitempty"`
+}
+
+// Result describes a completed download.
+type Result struct {
+	// File is the downloaded archive, positioned at the start. The caller must
+	// Close it and remove it (Cleanup does both).
+	File *os.File
+	// Size is the archive size in bytes.
+	Size int64
+	// SHA256 is the hex SHA-256 of the archive.
+	SHA256 string
+	// Filename is the last path segment of the source URL.
+	Filename string
+}
+
+// Cleanup closes and removes the downloaded file.
+func (r *Result) Cleanup() {
+	if r == nil || r.File == nil {
+		return
+	}
+	_ = r.File.Close()
+	_ = os.Remove(r.File.Name())
+}
+
+// IsJSONRequest reports whether the request body is JSON, i.e. a
+// publish-from-URL request rather than a multipart upload.
+func IsJSONRequest(c *gin.Context) bool {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// headerNameRe matches an RFC 7230 header field name.
+var headerNameRe = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// reservedHeaders may not be set by the caller: they control framing or
+// routing of the request rather than authenticating it.
+var reservedHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Te":                true,
+	"Upgrade":           true,
+}
+
+// Fetcher downloads archives named in publish-from-URL requests.
+type Fetcher struct {
+	cfg    config.RemoteUploadConfig
+	guard  *httpsafe.Guard
+	cipher *crypto.TokenCipher
+	client *http.Client
+	dir    string // where downloads are written; "" is the OS temp dir
+}
+
+// NewFetcher builds a Fetcher from the registry configuration. The egress
+// allow-list was validated at config load, so a parse failure here falls back
+// to the strict default guard.
+func NewFetcher(cfg *config.Config) *Fetcher {
+	guard, err := httpsafe.NewGuard(cfg.Security.Egress.Allowlist)
+	if err != nil {
+		guard = nil
+	}
+	return newFetcher(cfg.RemoteUpload, guard)
+}
+
+func newFetcher(cfg config.RemoteUploadConfig, guard *httpsafe.Guard) *Fetcher {
+	f := &Fetcher{cfg: cfg, guard: guard}
+
+	// The auth header is sealed under a key that only ever lives in this
+	// process's memory; a failure to create it only disables header support.
+	if key, err := crypto.GenerateKey(); err == nil {
+		f.cipher, _ = crypto.NewTokenCipher(key)
+	}
+
+	timeout := f.cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	f.client = httpsafe.NewClient(timeout, guard)
+	f.client.CheckRedirect = f.checkRedirect
+	return f
+}
+
+// WithScratch writes downloads to the registry's scratch directory. The
+// caller reserves the download's size from the scratch budget.
+func (f *Fetcher) WithScratch(space *scratch.Space) *Fetcher {
+	f.dir = space.Dir()
+	return f
+}
+
+// Enabled reports whether publish-from-URL is enabled.
+func (f *Fetcher) Enabled() bool {
+	return f != nil && f.cfg.Enabled
+}
+
+// sealedSource is a validated Source with the auth header value sealed.
+type sealedSource struct {
+	url        *url.URL
+	checksum   string
+	headerName string
+	headerSeal string
+}
+
+// prepare validates src against the policy and seals its auth header. The
+// plaintext header value is cleared from src.
+func (f *Fetcher) prepare(src *Source) (*sealedSource, error) {
+	if !f.Enabled() {
+		return nil, ErrDisabled
+	}
+	defer func() { src.AuthHeaderValue = "" }()
+
+	u, err := url.Parse(strings.TrimSpace(src.SourceURL))
+	if err != nil || u.Host == "" {
+		return nil, &InvalidSourceError{Reason: "source_url must be an absolute URL"}
+	}
+	if u.User != nil {
+		return nil, &InvalidSourceError{Reason: "source_url must not embed credentials; use auth_header_name/auth_header_value"}
+	}
+	if !f.cfg.IsAllowedScheme(u.Scheme) {
+		return nil, &InvalidSourceError{Reason: fmt.Sprintf("scheme %q is not allowed", u.Scheme)}
+	}
+	if !f.cfg.IsAllowedHost(u.Hostname()) {
+		return nil, &InvalidSourceError{Reason: fmt.Sprintf("host %q is not allowed", u.Hostname())}
+	}
+	if err := f.guard.ValidateURL(u.String()); err != nil {
+		return nil, &InvalidSourceError{Reason: err.Error()}
+	}
+
+	s := &sealedSource{url: u}
+	if src.Checksum != "" {
+		sum := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(src.Checksum), "sha256:"))
+		if len(sum) != sha256.Size*2 {
+			return nil, &InvalidSourceError{Reason: "checksum must be a hex SHA-256 digest"}
+		}
+		if _, err := hex.DecodeString(sum); err != nil {
+			return nil, &InvalidSourceError{Reason: "checksum must be a hex SHA-256 digest"}
+		}
+		s.checksum = sum
+	}
+
+	if src.AuthHeaderName != "" || src.AuthHeaderValue != "" {
+		name := http.CanonicalHeaderKey(strings.TrimSpace(src.AuthHeaderName))
+		if name == "" || !headerNameRe.MatchString(name) || reservedHeaders[name] {
+			return nil, &InvalidSourceError{Reason: "auth_header_name is not a valid request header"}
+		}
+		if strings.ContainsAny(src.AuthHeaderValue, "\r\n") {
+			return nil, &InvalidSourceError{Reason: "auth_header_value must not contain line breaks"}
+		}
+		if f.cipher == nil {
+			return nil, errors.New("auth header encryption is unavailable")
+		}
+		sealed, err := f.cipher.Seal(src.AuthHeaderValue)
+		if err != nil {
+			return nil, fmt.Errorf("seal auth header: %w", err)
+		}
+		s.headerName = name
+		s.headerSeal = sealed
+	}
+	return s, nil
+}
+
+// Fetch validates src, downloads it to a temp file named by pattern, and
+// verifies the size limit and optional checksum. On success the caller owns
+// the returned Result and must call Cleanup.
+func (f *Fetcher) Fetch(ctx context.Context, src *Source, maxBytes int64, pattern string) (*Result, error) {
+	s, err := f.prepare(src)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url.String(), nil)
+	if err != nil {
+		return nil, &InvalidSourceError{Reason: err.Error()}
+	}
+	if s.headerName != "" {
+		value, err := f.cipher.Open(s.headerSeal)
+		if err != nil {
+			return nil, fmt.Errorf("open auth header: %w", err)
+		}
+		req.Header.Set(s.headerName, value)
+		ctx = context.WithValue(ctx, authHeaderKey{}, s.headerName)
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", s.url.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	tmp, err := os.CreateTemp(f.dir, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	result := &Result{File: tmp, Filename: path.Base(s.url.Path)}
+	if result.Filename == "." || result.Filename == "/" {
+		result.Filename = ""
+	}
+
+	hasher := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hasher), io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		result.Cleanup()
+		return nil, fmt.Errorf("download %s: %w", s.url.Redacted(), err)
+	}
+	if n > maxBytes {
+		result.Cleanup()
+		return nil, ErrTooLarge
+	}
+	result.Size = n
+	result.SHA256 = hex.EncodeToString(hasher.Sum(nil))
+	if s.checksum != "" && s.checksum != result.SHA256 {
+		result.Cleanup()
+		return nil, ErrChecksumMismatch
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		result.Cleanup()
+		return nil, fmt.Errorf("rewind temp file: %w", err)
+	}
+	return result, nil
+}
+
+// authHeaderKey carries the caller's auth header name to checkRedirect.
+type authHeaderKey struct{}
+
+// checkRedirect applies the egress guard's redirect checks, re-applies the
+// scheme/host policy to every hop, and drops the caller's auth header when a
+// hop leaves the original host.
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if err := f.guard.CheckRedirect(req, via); err != nil {
+		return err
+	}
+	if !f.cfg.IsAllowedScheme(req.URL.Scheme) || !f.cfg.IsAllowedHost(req.URL.Hostname()) {
+		return fmt.Errorf("redirect to %s is not allowed by remote_upload policy", req.URL.Host)
+	}
+	if name, ok := req.Context().Value(authHeaderKey{}).(string); ok && len(via) > 0 &&
+		!strings.EqualFold(via[0].URL.Host, req.URL.Host) {
+		req.Header.Del(name)
+	}
+	return nil
+}
+
+// Respond writes the error response for a failed Fetch. Remote server errors
+// are reported as 502 with the upstream status so callers can tell a missing
+// artifact from an expired credential.
+func Respond(c *gin.Context, err error) {
+	var invalid *InvalidSourceError
+	var status *StatusError
+	switch {
+	case errors.Is(err, ErrDisabled):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.As(err, &invalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Error()})
+	case errors.Is(err, ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrChecksumMismatch):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.As(err, &status):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":           "Failed to download source_url: " + status.Error(),
+			"upstream_status": status.StatusCode,
+		})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Timed out downloading source_url"})
+	default:
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to download source_url: %v", err)})
+	}
+}
@@ -0,0 +1,200 @@
+package remoteupload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+)
+
+// testFetcher allows loopback (the httptest server) through the egress guard.
+func testFetcher(hosts ...string) *Fetcher {
+	return newFetcher(config.RemoteUploadConfig{
+		Enabled:        true,
+		AllowedSchemes: []string{"http", "https"},
+		AllowedHosts:   hosts,
+	}, httpsafe.MustGuard("127.0.0.1", "localhost"))
+}
+
+func sum(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func TestFetch_Success(t *testing.T) {
+	body := []byte("archive-bytes")
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("X-Api-Token")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	src := &Source{
+		SourceURL:       srv.URL + "/builds/vpc-1.0.0.tar.gz",
+		Checksum:        "sha256:" + sum(body),
+		AuthHeaderName:  "x-api-token",
+		AuthHeaderValue: "s3cret",
+	}
+	res, err := testFetcher().Fetch(context.Background(), src, 1024, "remote-*")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	defer res.Cleanup()
+
+	if gotAuth != "s3cret" {
+		t.Errorf("auth header = %q, want s3cret", gotAuth)
+	}
+	if src.AuthHeaderValue != "" {
+		t.Error("plaintext auth header value was not cleared from the request")
+	}
+	if res.Size != int64(len(body)) || res.SHA256 != sum(body) || res.Filename != "vpc-1.0.0.tar.gz" {
+		t.Errorf("result = size %d sha %s name %q", res.Size, res.SHA256, res.Filename)
+	}
+	data, _ := os.ReadFile(res.File.Name())
+	if string(data) != string(body) {
+		t.Errorf("file contents = %q", data)
+	}
+}
+
+func TestFetch_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/big":
+			_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		fetcher *Fetcher
+		src     Source
+		check   func(error) bool
+	}{
+		{"disabled", newFetcher(config.RemoteUploadConfig{}, nil), Source{SourceURL: srv.URL + "/a"},
+			func(err error) bool { return errors.Is(err, ErrDisabled) }},
+		{"upstream 404", testFetcher(), Source{SourceURL: srv.URL + "/missing"},
+			func(err error) bool {
+				var se *StatusError
+				return errors.As(err, &se) && se.StatusCode == http.StatusNotFound
+			}},
+		{"too large", testFetcher(), Source{SourceURL: srv.URL + "/big"},
+			func(err error) bool { return errors.Is(err, ErrTooLarge) }},
+		{"checksum mismatch", testFetcher(), Source{SourceURL: srv.URL + "/a", Checksum: sum([]byte("other"))},
+			func(err error) bool { return errors.Is(err, ErrChecksumMismatch) }},
+		{"host not allowed", testFetcher("releases.example.com"), Source{SourceURL: srv.URL + "/a"},
+			isInvalid},
+		{"scheme not allowed", testFetcher(), Source{SourceURL: "ftp://127.0.0.1/a"},
+			isInvalid},
+		{"embedded credentials", testFetcher(), Source{SourceURL: "http://user:pw@127.0.0.1/a"},
+			isInvalid},
+		{"reserved header", testFetcher(), Source{SourceURL: srv.URL + "/a", AuthHeaderName: "Host", AuthHeaderValue: "x"},
+			isInvalid},
+		{"malformed checksum", testFetcher(), Source{SourceURL: srv.URL + "/a", Checksum: "abc"},
+			isInvalid},
+		{"metadata address", newFetcher(config.RemoteUploadConfig{Enabled: true, AllowedSchemes: []string{"http"}}, nil),
+			Source{SourceURL: "http://169.254.169.254/latest/meta-data"}, isInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := tt.src
+			res, err := tt.fetcher.Fetch(context.Background(), &src, 16, "remote-*")
+			if err == nil {
+				res.Cleanup()
+				t.Fatal("expected an error")
+			}
+			if !tt.check(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func isInvalid(err error) bool {
+	var ie *InvalidSourceError
+	return errors.As(err, &ie)
+}
+
+func TestFetch_DropsAuthHeaderOnCrossHostRedirect(t *testing.T) {
+	var gotAuth string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("X-Api-Token")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	// Redirect from 127.0.0.1 to localhost so the hop changes host.
+	targetURL, _ := url.Parse(target.URL)
+	targetURL.Host = "localhost:" + targetURL.Port()
+	origin := httptest.NewServer(http.RedirectHandler(targetURL.String()+"/a.zip", http.StatusFound))
+	defer origin.Close()
+
+	src := &Source{SourceURL: origin.URL + "/a.zip", AuthHeaderName: "X-Api-Token", AuthHeaderValue: "s3cret"}
+	res, err := testFetcher().Fetch(context.Background(), src, 1024, "remote-*")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	defer res.Cleanup()
+	if gotAuth != "" {
+		t.Errorf("auth header forwarded across hosts: %q", gotAuth)
+	}
+}
+
+func TestFetch_RedirectToDisallowedHost(t *testing.T) {
+	origin := httptest.NewServer(http.RedirectHandler("http://elsewhere.example.com/a.zip", http.StatusFound))
+	defer origin.Close()
+
+	_, err := testFetcher("127.0.0.1").Fetch(context.Background(), &Source{SourceURL: origin.URL + "/a.zip"}, 1024, "remote-*")
+	if err == nil || !strings.Contains(err.Error(), "remote_upload policy") {
+		t.Errorf("err = %v, want redirect policy error", err)
+	}
+}
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err        error
+		wantStatus int
+	}{
+		{ErrDisabled, http.StatusBadRequest},
+		{&InvalidSourceError{Reason: "x"}, http.StatusBadRequest},
+		{ErrTooLarge, http.StatusRequestEntityTooLarge},
+		{ErrChecksumMismatch, http.StatusUnprocessableEntity},
+		{&StatusError{StatusCode: 403, Status: "403 Forbidden"}, http.StatusBadGateway},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{errors.New("boom"), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		Respond(c, tt.err)
+		if w.Code != tt.wantStatus {
+			t.Errorf("Respond(%v) = %d, want %d", tt.err, w.Code, tt.wantStatus)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	Respond(c, &StatusError{StatusCode: 401, Status: "401 Unauthorized"})
+	var body map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if body["upstream_status"] != float64(401) {
+		t.Errorf("upstream_status = %v, want 401", body["upstream_status"])
+	}
+}
@@ -1,5 +1,11 @@
 package api
 
+import (
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
 // HealthResponse is returned by GET /health.
 type HealthResponse struct {
 	Status    string `json:"status"`
@@ -21,6 +27,8 @@ type ReadinessResponse struct {
 	Checks ReadinessChecks `json:"checks"`
 	Time   string          `json:"time,omitempty"`
 	Error  string          `json:"error,omitempty"`
+	// Maintenance is present while read-only maintenance mode is on.
+	Maintenance *models.MaintenanceMode `json:"maintenance,omitempty"`
 }
 
 // ServiceDiscoveryResponse is returned by GET /.well-known/terraform.json.
@@ -36,6 +44,19 @@ type ProtocolVersions struct {
 	Mirror    string `json:"mirror"`
 }
 
+// PublicStatsResponse is returned by GET /api/v1/stats/public.
+type PublicStatsResponse struct {
+	models.PublicStats
+	// Version is the serving build, so a status page can spot version drift
+	// across registry environments.
+	Version    string           `json:"version"`
+	APIVersion string           `json:"api_version"`
+	Protocols  ProtocolVersions `json:"protocols"`
+	// GeneratedAt is when the counts were computed; they are reused until
+	// the refresh interval passes.
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
 // VersionResponse is returned by GET /version.
 type VersionResponse struct {
 	Version         string           `json:"version"`
@@ -43,4 +64,30 @@ type VersionResponse struct {
 	APIVersion      string           `json:"api_version"`
 	DefaultLanguage string           `json:"default_language"`
 	Protocols       ProtocolVersions `json:"protocols"`
+	// DevEndpointsActive is true while DEV_MODE serves the dev login and
+	// impersonation endpoints.
+	DevEndpointsActive bool `json:"dev_endpoints_active"`
+}
+
+// RouteManifestEntry describes one registered route.
+type RouteManifestEntry struct {
+	Method string `json:"method"`
+	// Path is the gin path template, e.g. /v1/providers/:namespace/:type/versions.
+	Path string `json:"path"`
+	// Auth is none, optional, required, setup_token, signature, url_token,
+	// ip_allowlist or mtls.
+	Auth string `json:"auth"`
+	// Scopes must all be held by the caller.
+	Scopes []string `json:"scopes,omitempty"`
+	// AnyScopes are alternatives, one of which must be held.
+	AnyScopes []string `json:"any_scopes,omitempty"`
+	// RateLimit is the class of the most specific limiter applied: none,
+	// general, auth, upload, principal or dev.
+	RateLimit string `json:"rate_limit"`
+}
+
+// RouteManifestResponse is returned by GET /api/v1/admin/routes.
+type RouteManifestResponse struct {
+	Routes     []RouteManifestEntry `json:"routes"`
+	TotalCount int                  `json:"total_count"`
 }
@@ -0,0 +1,279 @@
+// route_manifest.go records every route as it is registered, with the
+// authentication, scopes and rate-limit class its middleware chain applies,
+// and serves the result at GET /api/v1/admin/routes so administrators can see
+// what a deployment exposes.
+//
+// gin does not expose a route's middleware chain, so registerPublicRoutes and
+// registerAPIV1Routes add routes through routeGroup, which attaches each
+// access-control middleware together with its annotation (UseAuth,
+// UseRateLimit, UseScope, WithScope) so the two cannot drift apart.
+package api
+
+import (
+	"net/http"
+	"path"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// Route authentication modes recorded in the manifest.
+const (
+	routeAuthNone     = "none"
+	routeAuthOptional = "optional"
+	routeAuthRequired = "required"
+	// routeAuthSetupToken routes take the one-time setup token.
+	routeAuthSetupToken = "setup_token"
+	// routeAuthSignature routes verify a request signature in the handler.
+	routeAuthSignature = "signature"
+	// routeAuthURLToken routes take a single-use token in the path.
+	routeAuthURLToken = "url_token"
+	// routeAuthIPAllowlist and routeAuthMTLS are the binary mirror's
+	// binary_mirror.auth modes.
+	routeAuthIPAllowlist = "ip_allowlist"
+	routeAuthMTLS        = "mtls"
+)
+
+// Rate-limit classes recorded in the manifest, one per limiter NewRouter builds.
+const (
+	routeRateLimitNone    = "none"
+	routeRateLimitGeneral = "general"
+	routeRateLimitAuth    = "auth"
+	routeRateLimitUpload  = "upload"
+	// routeRateLimitPrincipal is the general limit keyed by user, API key or
+	// organization, with per-principal overrides.
+	routeRateLimitPrincipal = "principal"
+	routeRateLimitDev       = "dev"
+)
+
+// routeManifest collects the entries routeGroups record. Routes are only added
+// while NewRouter runs, before the server accepts requests, so reads need no
+// locking.
+type routeManifest struct {
+	entries []RouteManifestEntry
+}
+
+// sorted returns the entries ordered by path, then method, so the manifest is
+// stable across runs regardless of registration order.
+func (m *routeManifest) sorted() []RouteManifestEntry {
+	out := make([]RouteManifestEntry, len(m.entries))
+	copy(out, m.entries)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// routeAccess is what a group's middleware so far requires of a request.
+type routeAccess struct {
+	auth      string
+	scopes    []string
+	anyScopes []string
+	rateLimit string
+}
+
+func (a routeAccess) clone() routeAccess {
+	a.scopes = append([]string(nil), a.scopes...)
+	a.anyScopes = append([]string(nil), a.anyScopes...)
+	return a
+}
+
+// routeGroup wraps a gin.RouterGroup and records every route added through it
+// into the shared manifest.
+type routeGroup struct {
+	group    *gin.RouterGroup
+	manifest *routeManifest
+	access   routeAccess
+	// prefix is middleware prepended to each route added through this group,
+	// set by the With* views.
+	prefix []gin.HandlerFunc
+}
+
+// newRouteGroup wraps engine's root group, recording into a fresh manifest.
+func newRouteGroup(engine *gin.Engine) *routeGroup {
+	return &routeGroup{
+		group:    &engine.RouterGroup,
+		manifest: &routeManifest{},
+		access:   routeAccess{auth: routeAuthNone, rateLimit: routeRateLimitNone},
+	}
+}
+
+// view returns a copy of g that shares its gin group, for the With* helpers.
+func (g *routeGroup) view() *routeGroup {
+	return &routeGroup{
+		group:    g.group,
+		manifest: g.manifest,
+		access:   g.access.clone(),
+		prefix:   append([]gin.HandlerFunc(nil), g.prefix...),
+	}
+}
+
+// Group creates a child group that inherits g's middleware and annotations.
+func (g *routeGroup) Group(relativePath string, handlers ...gin.HandlerFunc) *routeGroup {
+	child := g.view()
+	child.group = g.group.Group(relativePath, append(child.prefix, handlers...)...)
+	child.prefix = nil
+	return child
+}
+
+// Use adds middleware that does not change a route's access requirements.
+func (g *routeGroup) Use(middleware ...gin.HandlerFunc) {
+	g.group.Use(middleware...)
+}
+
+// UseAuth adds authentication middleware and records its mode.
+func (g *routeGroup) UseAuth(mode string, handler gin.HandlerFunc) {
+	g.group.Use(handler)
+	g.access.auth = mode
+}
+
+// UseRateLimit adds rate-limiting middleware and records its class.
+func (g *routeGroup) UseRateLimit(class string, handlers ...gin.HandlerFunc) {
+	g.group.Use(handlers...)
+	g.access.rateLimit = class
+}
+
+// UseScope requires scope on every route added to g from now on.
+func (g *routeGroup) UseScope(scope auth.Scope) {
+	g.group.Use(middleware.RequireScope(scope))
+	g.access.scopes = append(g.access.scopes, string(scope))
+}
+
+// WithScope returns a view of g whose routes also require scope.
+func (g *routeGroup) WithScope(scope auth.Scope) *routeGroup {
+	v := g.view()
+	v.prefix = append(v.prefix, middleware.RequireScope(scope))
+	v.access.scopes = append(v.access.scopes, string(scope))
+	return v
+}
+
+// WithAnyScope returns a view of g whose routes require one of scopes.
+func (g *routeGroup) WithAnyScope(scopes ...auth.Scope) *routeGroup {
+	v := g.view()
+	v.prefix = append(v.prefix, middleware.RequireAnyScope(scopes...))
+	for _, s := range scopes {
+		v.access.anyScopes = append(v.access.anyScopes, string(s))
+	}
+	return v
+}
+
+// WithRateLimit returns a view of g whose routes are also rate limited by
+// handler, recorded as class.
+func (g *routeGroup) WithRateLimit(class string, handler gin.HandlerFunc) *routeGroup {
+	v := g.view()
+	v.prefix = append(v.prefix, handler)
+	v.access.rateLimit = class
+	return v
+}
+
+// WithAuth returns a view of g whose routes authenticate in the handler
+// itself (a webhook signature or a token in the path), recorded as mode.
+func (g *routeGroup) WithAuth(mode string) *routeGroup {
+	v := g.view()
+	v.access.auth = mode
+	return v
+}
+
+func (g *routeGroup) handle(method, relativePath string, handlers []gin.HandlerFunc) {
+	chain := append(append([]gin.HandlerFunc(nil), g.prefix...), handlers...)
+	g.group.Handle(method, relativePath, chain...)
+	a := g.access.clone()
+	g.manifest.entries = append(g.manifest.entries, RouteManifestEntry{
+		Method:    method,
+		Path:      joinRoutePath(g.group.BasePath(), relativePath),
+		Auth:      a.auth,
+		Scopes:    a.scopes,
+		AnyScopes: a.anyScopes,
+		RateLimit: a.rateLimit,
+	})
+}
+
+func (g *routeGroup) GET(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodGet, relativePath, handlers)
+}
+
+func (g *routeGroup) HEAD(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodHead, relativePath, handlers)
+}
+
+func (g *routeGroup) POST(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPost, relativePath, handlers)
+}
+
+func (g *routeGroup) PUT(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPut, relativePath, handlers)
+}
+
+func (g *routeGroup) PATCH(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPatch, relativePath, handlers)
+}
+
+func (g *routeGroup) DELETE(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodDelete, relativePath, handlers)
+}
+
+// StaticFS serves fs under relativePath, recording the GET and HEAD routes
+// gin registers for it.
+func (g *routeGroup) StaticFS(relativePath string, fs http.FileSystem) {
+	g.group.StaticFS(relativePath, fs)
+	pattern := path.Join(relativePath, "/*filepath")
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		a := g.access.clone()
+		g.manifest.entries = append(g.manifest.entries, RouteManifestEntry{
+			Method:    method,
+			Path:      joinRoutePath(g.group.BasePath(), pattern),
+			Auth:      a.auth,
+			Scopes:    a.scopes,
+			AnyScopes: a.anyScopes,
+			RateLimit: a.rateLimit,
+		})
+	}
+}
+
+// joinRoutePath joins a group's base path and a route's relative path the way
+// gin does, keeping a trailing slash on the relative path.
+func joinRoutePath(base, relative string) string {
+	if relative == "" {
+		return base
+	}
+	joined := path.Join(base, relative)
+	if relative[len(relative)-1] == '/' && joined[len(joined)-1] != '/' {
+		return joined + "/"
+	}
+	return joined
+}
+
+// binaryMirrorRouteAuth maps binary_mirror.auth to its manifest mode, treating
+// unrecognised values as none like middleware.BinaryMirrorAuthMiddleware.
+func binaryMirrorRouteAuth(mode string) string {
+	switch mode {
+	case "allowlist":
+		return routeAuthIPAllowlist
+	case "mtls":
+		return routeAuthMTLS
+	default:
+		return routeAuthNone
+	}
+}
+
+// @Summary      List registered routes
+// @Description  Returns every route this server registered, with its method, gin path template, authentication (none, optional, required, setup_token, signature, url_token, ip_allowlist or mtls), the scopes it requires (all of scopes, or one of any_scopes) and the class of the most specific rate limiter applied (none, general, auth, upload, principal or dev). Routes are sorted by path, then method. The manifest reflects this server's configuration: routes that are disabled by config (e.g. download HEAD, public stats, the module proxy) are absent. Requires admin scope.
+// @Tags         System
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  api.RouteManifestResponse
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden - requires admin scope"
+// @Router       /api/v1/admin/routes [get]
+// routeManifestHandler serves GET /api/v1/admin/routes.
+func routeManifestHandler(m *routeManifest) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		routes := m.sorted()
+		c.JSON(http.StatusOK, RouteManifestResponse{Routes: routes, TotalCount: len(routes)})
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+// fetchRouteManifest builds the real router and calls the route manifest
+// handler registered on it directly, past its authentication middleware.
+func fetchRouteManifest(t *testing.T) (gin.RoutesInfo, RouteManifestResponse) {
+	t.Helper()
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	t.Setenv("ENCRYPTION_KEY", "9f86d081884c7d659a2feaa0c55ad015")
+
+	cfg := &config.Config{}
+	cfg.Storage.DefaultBackend = "local"
+	cfg.Storage.Local.BasePath = t.TempDir()
+	router, bg, err := NewRouter(cfg, db, db)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	t.Cleanup(bg.Shutdown)
+
+	routes := router.Routes()
+	for _, r := range routes {
+		if r.Method != http.MethodGet || r.Path != "/api/v1/admin/routes" {
+			continue
+		}
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, r.Path, nil)
+		r.HandlerFunc(c)
+		var resp RouteManifestResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode manifest: %v", err)
+		}
+		return routes, resp
+	}
+	t.Fatal("GET /api/v1/admin/routes is not registered")
+	return nil, RouteManifestResponse{}
+}
+
+func TestRouteManifest_MatchesRouter(t *testing.T) {
+	routes, manifest := fetchRouteManifest(t)
+
+	sort.Slice(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		return a.Path < b.Path || (a.Path == b.Path && a.Method < b.Method)
+	})
+	var registered, listed []string
+	for _, r := range routes {
+		registered = append(registered, r.Method+" "+r.Path)
+	}
+	for _, e := range manifest.Routes {
+		listed = append(listed, e.Method+" "+e.Path)
+	}
+	// Same routes, in path-then-method order.
+	if strings.Join(listed, "\n") != strings.Join(registered, "\n") {
+		t.Errorf("manifest routes differ from the router's:\nmanifest: %v\nrouter:   %v", listed, registered)
+	}
+	if manifest.TotalCount != len(routes) {
+		t.Errorf("total_count = %d, want %d", manifest.TotalCount, len(routes))
+	}
+}
+
+func TestRouteManifest_Annotations(t *testing.T) {
+	_, manifest := fetchRouteManifest(t)
+	byRoute := map[string]RouteManifestEntry{}
+	for _, e := range manifest.Routes {
+		byRoute[e.Method+" "+e.Path] = e
+	}
+
+	tests := []struct {
+		route     string
+		auth      string
+		scopes    []auth.Scope
+		rateLimit string
+	}{
+		{"GET /health", routeAuthNone, nil, routeRateLimitNone},
+		{"GET /v1/providers/:namespace/:type/versions", routeAuthOptional, nil, routeRateLimitNone},
+		{"GET /terraform/binaries/:name/versions", routeAuthNone, nil, routeRateLimitNone},
+		{"POST /api/v1/auth/ldap/login", routeAuthNone, nil, routeRateLimitAuth},
+		{"POST /api/v1/setup/complete", routeAuthSetupToken, nil, routeRateLimitNone},
+		{"GET /api/v1/modules/search", routeAuthNone, nil, routeRateLimitGeneral},
+		{"GET /api/v1/providers/:namespace/:type", routeAuthOptional, nil, routeRateLimitGeneral},
+		{"GET /api/v1/auth/me", routeAuthRequired, nil, routeRateLimitPrincipal},
+		{"POST /api/v1/modules", routeAuthRequired, []auth.Scope{auth.ScopeModulesWrite}, routeRateLimitUpload},
+		{"GET /api/v1/users/:id", routeAuthRequired, []auth.Scope{auth.ScopeUsersRead}, routeRateLimitPrincipal},
+		{"GET /api/v1/admin/routes", routeAuthRequired, []auth.Scope{auth.ScopeAdmin}, routeRateLimitPrincipal},
+		{"GET /scim/v2/Users", routeAuthRequired, []auth.Scope{auth.ScopeSCIMProvision}, routeRateLimitNone},
+		{"GET /api/v1/dev/status", routeAuthNone, nil, routeRateLimitDev},
+		{"GET /api/v1/dev/users", routeAuthRequired, nil, routeRateLimitDev},
+		{"POST /webhooks/scm/:module_source_repo_id", routeAuthSignature, nil, routeRateLimitNone},
+		{"POST /webhooks/approvals/:token", routeAuthURLToken, nil, routeRateLimitNone},
+	}
+	for _, tt := range tests {
+		e, ok := byRoute[tt.route]
+		if !ok {
+			t.Errorf("%s: not in the manifest", tt.route)
+			continue
+		}
+		var scopes []string
+		for _, s := range tt.scopes {
+			scopes = append(scopes, string(s))
+		}
+		if e.Auth != tt.auth || e.RateLimit != tt.rateLimit || strings.Join(e.Scopes, ",") != strings.Join(scopes, ",") {
+			t.Errorf("%s: got auth=%s scopes=%v rate_limit=%s; want auth=%s scopes=%v rate_limit=%s",
+				tt.route, e.Auth, e.Scopes, e.RateLimit, tt.auth, scopes, tt.rateLimit)
+		}
+	}
+
+	want := string(auth.ScopeModulesRead) + "," + string(auth.ScopeProvidersRead)
+	if e := byRoute["POST /api/v1/usage/report"]; strings.Join(e.AnyScopes, ",") != want || len(e.Scopes) != 0 {
+		t.Errorf("POST /api/v1/usage/report = %+v, want any_scopes %s", e, want)
+	}
+}
+
+func TestRouteGroup_WithScopeDoesNotLeak(t *testing.T) {
+	g := newRouteGroup(gin.New())
+	admin := g.Group("/admin")
+	admin.WithScope(auth.ScopeAdmin).GET("/a", func(*gin.Context) {})
+	admin.GET("/b", func(*gin.Context) {})
+
+	got := g.manifest.sorted()
+	if len(got) != 2 || len(got[0].Scopes) != 1 || got[0].Path != "/admin/a" || len(got[1].Scopes) != 0 {
+		t.Errorf("manifest = %+v; the scope should apply to /admin/a only", got)
+	}
+}
+
+func TestJoinRoutePath(t *testing.T) {
+	tests := []struct{ base, relative, want string }{
+		{"/api/v1", "", "/api/v1"},
+		{"/api/v1", "/modules", "/api/v1/modules"},
+		{"/", "/api-docs/", "/api-docs/"},
+		{"/v2", "/", "/v2/"},
+	}
+	for _, tt := range tests {
+		if got := joinRoutePath(tt.base, tt.relative); got != tt.want {
+			t.Errorf("joinRoutePath(%q, %q) = %q, want %q", tt.base, tt.relative, got, tt.want)
+		}
+	}
+}
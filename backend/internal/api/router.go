@@ -16,11 +16,13 @@ package api
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -38,20 +40,27 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/api/webhooks"
 	"github.com/terraform-registry/terraform-registry/internal/auth"
 	"github.com/terraform-registry/terraform-registry/internal/auth/mtls"
+	"github.com/terraform-registry/terraform-registry/internal/auth/tokenexchange"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/downloadstats"
+	"github.com/terraform-registry/terraform-registry/internal/events"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
 	"github.com/terraform-registry/terraform-registry/internal/jobs"
 	"github.com/terraform-registry/terraform-registry/internal/middleware"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
 	"github.com/terraform-registry/terraform-registry/internal/notify"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
 	"github.com/terraform-registry/terraform-registry/internal/policy"
 	"github.com/terraform-registry/terraform-registry/internal/scm"
 	"github.com/terraform-registry/terraform-registry/internal/scm/appcreds"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
 	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 
 	// Import storage backends to register them
+	_ "github.com/terraform-registry/terraform-registry/internal/storage/artifactory"
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/azure"
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/gcs"
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/local"
@@ -74,6 +83,8 @@ type BackgroundServices struct {
 	jobs               *jobs.Registry
 	rateLimiters       []middleware.RateLimiterBackend
 	principalOverrides *middleware.PrincipalOverrideLimiters
+	operations         *operations.Registry
+	stateStore         auth.StateStore
 }
 
 // Shutdown stops all background goroutines. It should be called after the HTTP
@@ -92,9 +103,27 @@ func (bg *BackgroundServices) Shutdown() {
 	if bg.principalOverrides != nil {
 		_ = bg.principalOverrides.Close()
 	}
+	if bg.stateStore != nil {
+		_ = bg.stateStore.Close()
+	}
 	slog.Info("all background services stopped")
 }
 
+// LogRunningOperations logs every operation still registered. cmd/server calls
+// it when the HTTP drain timeout expires, so the operations cut off by the
+// exit are on record for the operator to retry.
+func (bg *BackgroundServices) LogRunningOperations() {
+	running := bg.operations.List()
+	for _, op := range running {
+		slog.Warn("operation still running at shutdown",
+			"id", op.ID, "type", op.Type, "actor", op.Actor, "resource", op.Resource,
+			"started_at", op.StartedAt, "done", op.Done, "total", op.Total)
+	}
+	if len(running) > 0 {
+		slog.Warn("shutdown drain timeout expired with operations in flight", "count", len(running))
+	}
+}
+
 // collectRateLimiterBackends returns a slice of non-nil rate limiter backends for shutdown tracking.
 func collectRateLimiterBackends(backends ...middleware.RateLimiterBackend) []middleware.RateLimiterBackend {
 	var out []middleware.RateLimiterBackend
@@ -118,10 +147,15 @@ var AppCryptoMode = "standard"
 // config, audit logs, role templates, revoked tokens). It equals db unless the
 // identity-schema cutover is enabled, in which case it targets the shared
 // identity schema (feature tables fall back to public via search_path).
-func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *BackgroundServices) {
+//
+// An initializer failure (bad storage config, unusable ENCRYPTION_KEY, ...) is
+// returned as an error with nothing left running: background jobs start only
+// once every fallible step has succeeded, and what was started before the
+// failure is stopped.
+func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (_ *gin.Engine, _ *BackgroundServices, err error) {
 	router := gin.New()
 	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
-		log.Fatalf("invalid trusted_proxies config: %v", err)
+		return nil, nil, fmt.Errorf("invalid trusted_proxies config: %w", err)
 	}
 
 	// egressGuard widens the SSRF deny-list enforced by every outbound client
@@ -131,19 +165,44 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	// mean cfg was constructed without going through config.Load.
 	egressGuard, err := httpsafe.NewGuard(cfg.Security.Egress.Allowlist)
 	if err != nil {
-		log.Fatalf("invalid security.egress.allowlist: %v", err)
+		return nil, nil, fmt.Errorf("invalid security.egress.allowlist: %w", err)
 	}
 	if err := scm.ConfigureEgress(cfg.Security.Egress.Allowlist); err != nil {
-		log.Fatalf("failed to configure SCM connector egress policy: %v", err)
+		return nil, nil, fmt.Errorf("failed to configure SCM connector egress policy: %w", err)
 	}
+	scm.ConfigureClient(scm.ClientOptions{
+		Timeout:       cfg.SCM.Timeout,
+		MaxRetries:    cfg.SCM.MaxRetries,
+		MaxRetryWait:  cfg.SCM.MaxRetryWait,
+		MaxConcurrent: cfg.SCM.MaxConcurrent,
+	})
 
 	// Initialize storage backend
 	storageBackend, err := storage.NewStorage(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize storage backend: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize storage backend: %w", err)
 	}
 	log.Printf("Initialized storage backend: %s", cfg.Storage.DefaultBackend)
 
+	// Scratch directory: uploads, SCM publishes, the module proxy and mirror
+	// syncs stage archives here, and uploads and publishes reserve their
+	// worst case from scratch.max_size_mb. Left nil (OS temp dir, no budget)
+	// when scratch.dir is unset, as in configs not built by config.Load.
+	var scratchSpace *scratch.Space
+	if cfg.Scratch.Dir != "" {
+		scratchSpace, err = scratch.New(cfg.Scratch.Dir, cfg.Scratch.MaxSizeMB<<20)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize scratch directory: %w", err)
+		}
+	}
+
+	// Artifact immutability (immutable_artifacts and per-organization
+	// settings) is enforced at the storage layer, below every handler and
+	// job: deletes and overwrites of protected archives are refused there.
+	artifactImmutabilityRepo := repositories.NewArtifactImmutabilityRepository(db)
+	artifactImmutability := services.NewArtifactImmutability(&cfg.ImmutableArtifacts, artifactImmutabilityRepo)
+	storageBackend = storage.NewImmutableStorage(storageBackend, artifactImmutability)
+
 	// Identity repositories use identityDB so they follow the configured identity
 	// schema; feature repositories below stay on db (public schema).
 	userRepo := repositories.NewUserRepository(identityDB)
@@ -186,24 +245,114 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	pullThroughSvc := services.NewPullThroughService(providerRepo, mirrorRepo, orgRepo)
 	pullThroughSvc.SetEgressGuard(egressGuard)
 
+	// Network mirror allowlist: a feature table on db, cached by the mirror
+	// route middleware and invalidated by the admin handlers.
+	mirrorAllowlistRepo := repositories.NewMirrorAllowlistRepository(db)
+	mirrorHostnameAliasRepo := repositories.NewMirrorHostnameAliasRepository(db)
+	mirrorHostnameAliases := middleware.NewMirrorHostnameAliases(mirrorHostnameAliasRepo, middleware.DefaultMirrorAllowlistTTL)
+	mirrorAllowlist := middleware.NewMirrorAllowlist(mirrorAllowlistRepo, middleware.DefaultMirrorAllowlistTTL).
+		WithAliases(mirrorHostnameAliases)
+
+	// Custom tenant domains: a feature table on db, cached by TenantMiddleware
+	// and invalidated by the admin handlers.
+	tenantDomainRepo := repositories.NewTenantDomainRepository(db)
+	tenantDomains := middleware.NewTenantDomains(tenantDomainRepo, middleware.DefaultMirrorAllowlistTTL)
+
+	// Read-only maintenance mode: stored in system_settings, cached by the
+	// global maintenance middleware and invalidated by the admin toggle.
+	maintenanceRepo := repositories.NewMaintenanceRepository(db)
+	maintenanceState := middleware.NewMaintenanceState(maintenanceRepo, middleware.DefaultMaintenanceTTL)
+
+	// Module pull-through proxy (opt-in). Mirror policies gate which
+	// namespaces may be proxied.
+	var moduleProxySvc *services.ModuleProxyService
+	if cfg.ModuleProxy.Enabled {
+		moduleProxySvc = services.NewModuleProxyService(cfg.ModuleProxy, moduleRepo,
+			repositories.NewModuleProxyRepository(db), orgRepo,
+			repositories.NewRBACRepository(sqlxDB), storageBackend, cfg.Storage.DefaultBackend)
+		moduleProxySvc.SetEgressGuard(egressGuard)
+		moduleProxySvc.SetScratch(scratchSpace)
+	}
+
+	// Documentation passthrough for mirrored providers; each mirror opts in
+	// with docs_passthrough_enabled.
+	providerDocsSvc := services.NewProviderDocsPassthrough(providerRepo, providerDocsRepo, mirrorRepo, orgRepo,
+		repositories.NewRBACRepository(sqlxDB))
+	providerDocsSvc.SetEgressGuard(egressGuard)
+
 	// jobRegistry collects every background job; they are all started together
 	// via StartAll near the end of NewRouter (after full wiring) and stopped
 	// together by BackgroundServices.Shutdown (issue #565 finding [40]).
 	jobRegistry := jobs.NewRegistry()
 
+	// operationsRegistry tracks in-flight uploads, syncs, migrations and
+	// exports for /admin/operations and the shutdown drain log.
+	operationsRegistry := operations.NewRegistry()
+
+	// downloadStats buffers provider downloads per version and platform and
+	// flushes them in batches for the admin provider stats and the mirror
+	// status platform usage. Registered as a job so shutdown flushes it.
+	downloadStats := downloadstats.NewRecorder(providerRepo, downloadstats.DefaultFlushInterval)
+	jobRegistry.Register(downloadStats)
+
+	// eventBus carries organization security events (membership, role, API
+	// key, SCM token and tier changes) from the handlers to the organization event
+	// webhooks, off the request path. Registered as a job so shutdown
+	// delivers what is still queued.
+	eventBus := events.NewBus(events.DefaultBufferSize)
+	jobRegistry.Register(eventBus)
+
 	// Initialize mirror sync job - checks every 10 minutes for mirrors needing sync.
 	mirrorSyncJob := jobs.NewMirrorSyncJob(mirrorRepo, providerRepo, providerDocsRepo, orgRepo, storageBackend, cfg.Storage.DefaultBackend)
 	mirrorSyncJob.SetApprovalRepo(repositories.NewVersionApprovalRepository(sqlxDB))
 	mirrorSyncJob.SetEgressGuard(egressGuard)
+	mirrorSyncJob.SetScratch(scratchSpace)
 	mirrorSyncJob.SetInterval(10)
+	mirrorSyncJob.SetOperations(operationsRegistry)
+	// Mirrored archives are stored once per content across mirrors and
+	// organizations; deleting a provider releases its references.
+	mirrorBlobs := services.NewMirrorBlobs(repositories.NewMirrorBlobRepository(db), storageBackend)
+	mirrorSyncJob.SetMirrorBlobs(mirrorBlobs)
+	// Sync leases live in the database so replicas never sync the same mirror
+	// at once and a lease left by a crashed replica expires.
+	mirrorSyncLeaseRepo := repositories.NewMirrorSyncLeaseRepository(db)
+	mirrorSyncJob.SetSyncLeases(mirrorSyncLeaseRepo)
 	jobRegistry.Register(mirrorSyncJob)
 
+	// Optional re-signing of mirrored providers with the registry's own key
+	// (mirror_signing); nil when disabled.
+	mirrorSigner, signerErr := mirror.NewSigner(cfg.MirrorSigning, egressGuard)
+	if signerErr != nil {
+		return nil, nil, fmt.Errorf("failed to initialize mirror signing key: %w", signerErr)
+	}
+	var providerResigner *services.ProviderResigner
+	if mirrorSigner != nil {
+		providerResigner = services.NewProviderResigner(providerRepo, mirrorRepo, storageBackend, mirrorSigner)
+		mirrorSyncJob.SetResigner(providerResigner)
+		slog.Info("mirror re-signing enabled", "key_id", mirrorSigner.KeyID())
+	}
+
 	// Initialize Terraform binary mirror repository and sync job
 	tfMirrorRepo := repositories.NewTerraformMirrorRepository(sqlxDB)
 	tfMirrorSyncJob := jobs.NewTerraformMirrorSyncJob(tfMirrorRepo, storageBackend, cfg.Storage.DefaultBackend)
 	tfMirrorSyncJob.SetEgressGuard(egressGuard)
+	tfMirrorSyncJob.SetScratch(scratchSpace)
 	tfMirrorSyncJob.SetInterval(10)
 	jobRegistry.Register(tfMirrorSyncJob)
+	tfEOLRefreshJob := jobs.NewTerraformEOLRefreshJob(tfMirrorRepo)
+	jobRegistry.Register(tfEOLRefreshJob)
+
+	// Storage consistency checker: finds artifact rows whose storage object is
+	// missing; its findings can mark downloads 410 Gone.
+	storageConsistencyRepo := repositories.NewStorageConsistencyRepository(db)
+	storageConsistencyJob := jobs.NewStorageConsistencyJob(&cfg.StorageConsistency, storageConsistencyRepo, storageBackend)
+	jobRegistry.Register(storageConsistencyJob)
+
+	// Scratch cleanup: removes what failed or interrupted work left in the
+	// scratch directory, at startup and then periodically.
+	if scratchSpace != nil {
+		jobRegistry.Register(jobs.NewScratchCleanupJob(&cfg.Scratch, scratchSpace))
+	}
 
 	// Initialize and start the upstream release-signing GPG key refresh job.
 	// On success it installs itself as the in-process resolver consulted by
@@ -225,6 +374,10 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 
 	// Public handler is created here (before route registration)
 	tfBinariesHandler := terraform_binaries.NewHandler(tfMirrorRepo, storageBackend, auditRepo)
+	tfBinariesHandler.SetHeadMode(cfg.DownloadHead.Mode)
+	if cfg.StorageConsistency.Enabled {
+		tfBinariesHandler.SetBrokenChecker(storageConsistencyRepo)
+	}
 
 	// OCI distribution handler (public read, backed by existing module storage)
 	ociHandler := oci.NewHandler(db, storageBackend)
@@ -259,8 +412,16 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	reloadScanningConfigFromDB(cfg, oidcConfigRepo)
 
 	moduleScannerJob := jobs.NewModuleScannerJob(&cfg.Scanning, scanRepo, moduleRepo, storageBackend)
+	moduleScannerJob.SetScratch(scratchSpace)
 	jobRegistry.Register(moduleScannerJob)
 
+	// Module content index behind code search: indexes the .tf files of
+	// module versions in the background after they are published.
+	codeIndexRepo := repositories.NewModuleCodeIndexRepository(db)
+	codeIndexJob := jobs.NewCodeIndexJob(&cfg.CodeIndex, codeIndexRepo, moduleRepo, storageBackend)
+	codeIndexJob.SetScratch(scratchSpace)
+	jobRegistry.Register(codeIndexJob)
+
 	// Initialize and start the scheduled scanner update-check job (no-op when
 	// scanning.auto_update.enabled=false). Discovers newer upstream scanner
 	// releases, files them into the version-approval workflow, and reconciles
@@ -278,7 +439,7 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	// Get encryption key from environment for OAuth token encryption
 	encryptionKey := os.Getenv("ENCRYPTION_KEY")
 	if encryptionKey == "" {
-		log.Fatal("ENCRYPTION_KEY environment variable must be set for SCM integration")
+		return nil, nil, errors.New("ENCRYPTION_KEY environment variable must be set for SCM integration")
 	}
 	// ENCRYPTION_KEY is used directly as raw AES-256 key bytes (no KDF/hashing), so its
 	// real-world entropy determines the actual strength of the cipher. Fail closed by
@@ -289,7 +450,7 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	// existing deployment can restart once to rotate its key instead of being unable
 	// to start at all.
 	if shouldRejectLowEntropyEncryptionKey([]byte(encryptionKey), allowLowEntropyEncryptionKey()) {
-		log.Fatal("ENCRYPTION_KEY has low estimated entropy and may not have been generated with a CSPRNG. Refusing to start (issue #560). Generate one with: openssl rand -hex 16 (see docs/secrets-rotation.md). To roll out this check on an existing deployment while you rotate to a stronger key, set TFR_ALLOW_LOW_ENTROPY_ENCRYPTION_KEY=true temporarily.")
+		return nil, nil, errors.New("ENCRYPTION_KEY has low estimated entropy and may not have been generated with a CSPRNG. Refusing to start (issue #560). Generate one with: openssl rand -hex 16 (see docs/secrets-rotation.md). To roll out this check on an existing deployment while you rotate to a stronger key, set TFR_ALLOW_LOW_ENTROPY_ENCRYPTION_KEY=true temporarily.")
 	}
 	if crypto.IsLikelyLowEntropySecret([]byte(encryptionKey)) {
 		log.Printf("WARNING: ENCRYPTION_KEY has low estimated entropy and may not have been generated with a CSPRNG. Generate one with: openssl rand -hex 16 (TFR_ALLOW_LOW_ENTROPY_ENCRYPTION_KEY override in use -- rotate this key soon)")
@@ -303,13 +464,13 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	if encryptionKeyPrevious != "" {
 		tokenCipher, err = crypto.NewTokenCipherWithPrevious([]byte(encryptionKey), []byte(encryptionKeyPrevious))
 		if err != nil {
-			log.Fatalf("Failed to initialize dual-key token cipher: %v", err)
+			return nil, nil, fmt.Errorf("failed to initialize dual-key token cipher: %w", err)
 		}
 		slog.Info("token cipher initialized with previous key for rotation support")
 	} else {
 		tokenCipher, err = crypto.NewTokenCipher([]byte(encryptionKey))
 		if err != nil {
-			log.Fatalf("Failed to initialize token cipher: %v", err)
+			return nil, nil, fmt.Errorf("failed to initialize token cipher: %w", err)
 		}
 	}
 
@@ -319,6 +480,13 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	// reloadNotificationsConfigFromDB.
 	reloadNotificationsConfigFromDB(cfg, oidcConfigRepo, tokenCipher)
 
+	// Re-seals stored secrets with the current key after a rotation; see
+	// POST /admin/crypto/reencrypt.
+	secretReencryptor := services.NewSecretReencryptor(repositories.NewEncryptedColumnRepository(db), tokenCipher)
+	if tokenCipher.HasPreviousKey() {
+		warnUnusedPreviousEncryptionKey(secretReencryptor)
+	}
+
 	// Add middleware
 	// middleware.RecoveryMiddleware replaces gin.Recovery(): gin's stock
 	// Recovery() only redacts the Authorization header in its panic-recovery
@@ -326,7 +494,7 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	// (issue #663).
 	router.Use(middleware.RecoveryMiddleware())
 	router.Use(middleware.RequestIDMiddleware())
-	router.Use(middleware.MetricsMiddleware())
+	router.Use(middleware.MetricsMiddleware(&cfg.Telemetry))
 	router.Use(LoggerMiddleware(cfg))
 	router.Use(CORSMiddleware(cfg))
 	router.Use(middleware.SecurityHeadersMiddleware(middleware.APISecurityHeadersConfig()))
@@ -343,13 +511,25 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	if cfg.Security.MTLS.Enabled {
 		mtlsProvider, mtlsErr := mtls.NewProvider(cfg.Security.MTLS)
 		if mtlsErr != nil {
-			log.Fatalf("failed to initialize mTLS provider: %v", mtlsErr)
+			return nil, nil, fmt.Errorf("failed to initialize mTLS provider: %w", mtlsErr)
 		}
 		router.Use(mtls.AuthMiddleware(mtlsProvider))
 	}
 
+	// Refuse mutating requests while maintenance mode is on. Registered
+	// globally so no route group can forget it; reads always pass.
+	router.Use(middleware.MaintenanceMiddleware(maintenanceState))
+
+	// Resolve custom tenant domains before any route builds an absolute URL
+	// or runs a search; requests on other hosts pass through unchanged.
+	router.Use(middleware.TenantMiddleware(tenantDomains, &cfg.Server))
+
+	// Routes are added through routeGroup so GET /api/v1/admin/routes can list
+	// each with its authentication, scopes and rate-limit class.
+	routes := newRouteGroup(router)
+
 	// Public + Terraform-protocol routes (issue #565 finding [39]). See registerPublicRoutes.
-	registerPublicRoutes(router, &publicRouteDeps{
+	registerPublicRoutes(routes, &publicRouteDeps{
 		cfg:                     cfg,
 		db:                      db,
 		storageBackend:          storageBackend,
@@ -361,6 +541,13 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 		userTokenRevocationRepo: userTokenRevocationRepo,
 		auditRepo:               auditRepo,
 		pullThroughSvc:          pullThroughSvc,
+		mirrorAllowlist:         mirrorAllowlist,
+		mirrorHostnameAliases:   mirrorHostnameAliases,
+		mirrorRepo:              mirrorRepo,
+		maintenanceState:        maintenanceState,
+		moduleProxySvc:          moduleProxySvc,
+		providerDocsSvc:         providerDocsSvc,
+		downloadStats:           downloadStats,
 		tfBinariesHandler:       tfBinariesHandler,
 	})
 
@@ -378,11 +565,27 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	} else {
 		oidcStateStore = auth.NewMemoryStateStore(5 * time.Minute)
 	}
-
+	// The state store runs a cleanup goroutine (or holds a Redis connection);
+	// release it if a later initializer fails. On success
+	// BackgroundServices.Shutdown closes it.
+	defer func() {
+		if err != nil {
+			_ = oidcStateStore.Close()
+		}
+	}()
+
+	// Organization scope ceilings are a feature table on db; the memberships
+	// they are resolved through stay on identityDB.
+	scopeCeilingRepo := repositories.NewOrgScopeCeilingRepository(db)
+	// Identity link requests are linked into the identity users table in one
+	// transaction, so they are kept through identityDB.
+	identityLinkRepo := repositories.NewIdentityLinkRepository(identityDB)
 	var authHandlers *admin.AuthHandlers
-	authHandlers, err = admin.NewAuthHandlers(cfg, identityDB, oidcConfigRepo, tokenRepo, oidcStateStore, admin.WithSAMLEgressGuard(egressGuard))
+	authHandlers, err = admin.NewAuthHandlers(cfg, identityDB, oidcConfigRepo, tokenRepo, oidcStateStore,
+		admin.WithSAMLEgressGuard(egressGuard), admin.WithLoginRecorder(repositories.NewUserLoginRepository(db)),
+		admin.WithScopeCeilings(scopeCeilingRepo), admin.WithIdentityLinks(identityLinkRepo, auditRepo))
 	if err != nil {
-		log.Fatalf("Failed to initialize auth handlers: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize auth handlers: %w", err)
 	}
 
 	// Load OIDC configuration persisted by the setup wizard from the database
@@ -394,25 +597,89 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	// identity repos / raw identity SQL then follow the identity schema). The org
 	// handler's namespace cascade and the stats handler's feature-table counts
 	// fall back to public via the identity connection's search_path.
-	apiKeyHandlers := admin.NewAPIKeyHandlers(cfg, identityDB)
+	// Per-organization API key policies are a feature table on db; the
+	// key/membership data they constrain stays on identityDB.
+	apiKeyPolicyRepo := repositories.NewOrgAPIKeyPolicyRepository(db)
+	// One-time API key retrieval links are a feature table on db; the key
+	// each one delivers stays on identityDB.
+	apiKeyClaimRepo := repositories.NewAPIKeyClaimRepository(db)
+	apiKeyHandlers := admin.NewAPIKeyHandlers(cfg, identityDB).WithKeyPolicies(apiKeyPolicyRepo).WithKeyClaims(apiKeyClaimRepo, tokenCipher).WithEvents(eventBus)
+	jobRegistry.Register(jobs.NewAPIKeyClaimExpiryJob(apiKeyClaimRepo, apiKeyRepo))
+	apiKeyPolicyHandlers := admin.NewAPIKeyPolicyHandlers(identityDB, apiKeyPolicyRepo)
+	scopeCeilingHandlers := admin.NewScopeCeilingHandlers(identityDB, scopeCeilingRepo)
+	// Module version approvals and the approved_only policy are feature
+	// tables on db, keyed by identity organization ID.
+	moduleApprovalHandlers := admin.NewModuleApprovalHandlers(db, identityDB, repositories.NewModuleApprovalRepository(db))
+	// CI token exchange: trust rules are a feature table on db; issuer
+	// discovery and JWKS fetches go through the egress-guarded client.
+	ciTrustRuleRepo := repositories.NewCITrustRuleRepository(db)
+	ciTrustRuleHandlers := admin.NewCITrustRuleHandlers(&cfg.Auth.TokenExchange, identityDB, ciTrustRuleRepo)
+	ciVerifier := tokenexchange.NewVerifier(cfg.Auth.TokenExchange.TrustedIssuers, cfg.Auth.TokenExchange.Audience,
+		httpsafe.NewClient(10*time.Second, egressGuard))
+	tokenExchangeHandlers := admin.NewTokenExchangeHandlers(&cfg.Auth.TokenExchange, ciVerifier, ciTrustRuleRepo, auditRepo)
+	tenantDomainHandlers := admin.NewTenantDomainHandlers(&cfg.Server, identityDB, tenantDomainRepo, tenantDomains)
+	// Terraform CLI tokens: the issued-token records are a feature table on
+	// db; revocation goes through the shared JTI denylist on identityDB.
+	cliTokenHandlers := admin.NewCLITokenHandlers(&cfg.Auth.CLITokens, repositories.NewCLITokenRepository(db), tokenRepo)
 	userHandlers := admin.NewUserHandlers(cfg, identityDB)
-	orgHandlers := admin.NewOrganizationHandlers(cfg, identityDB, nsClaimRepo, userTokenRevocationRepo)
+	orgHandlers := admin.NewOrganizationHandlers(cfg, identityDB, nsClaimRepo, userTokenRevocationRepo).
+		WithStats(repositories.NewOrganizationStatsRepository(db, identityDB)).
+		WithEvents(eventBus)
 	statsHandlers := admin.NewStatsHandler(identitySqlxDB, &cfg.Scanning)
 	mirrorHandlers := admin.NewMirrorHandler(mirrorRepo, orgRepo, providerRepo)
 	mirrorHandlers.SetSyncJob(mirrorSyncJob) // Connect sync job for manual triggers
 	mirrorHandlers.SetEgressGuard(egressGuard)
+	mirrorHandlers.SetSyncLeases(mirrorSyncLeaseRepo)
+	if providerResigner != nil {
+		mirrorHandlers.SetResigner(providerResigner)
+	}
+	mirrorAllowlistHandlers := admin.NewMirrorAllowlistHandlers(mirrorAllowlistRepo, mirrorAllowlist)
+	mirrorHostnameAliasHandlers := admin.NewMirrorHostnameAliasHandlers(mirrorHostnameAliasRepo, mirrorRepo, mirrorHostnameAliases)
+	operationsHandlers := admin.NewOperationsHandlers(operationsRegistry)
+	maintenanceHandlers := admin.NewMaintenanceHandlers(maintenanceRepo, maintenanceState)
+	cryptoHandlers := admin.NewCryptoHandlers(secretReencryptor)
+	storageConsistencyHandlers := admin.NewStorageConsistencyHandlers(&cfg.StorageConsistency, storageConsistencyRepo)
+	storageConsistencyHandlers.SetChecker(storageConsistencyJob)
+	storageConsistencyHandlers.SetProviderRepair(providerRepo, mirrorSyncJob)
+	storageConsistencyHandlers.SetTerraformRepair(tfMirrorRepo, tfMirrorSyncJob)
+	codeIndexHandlers := admin.NewCodeIndexHandlers(&cfg.CodeIndex, codeIndexRepo)
+	codeIndexHandlers.SetIndexer(codeIndexJob)
+	identityLinkHandlers := admin.NewIdentityLinkHandlers(identityDB, identityLinkRepo)
 
 	// Initialize Terraform binary mirror admin handler
 	tfMirrorAdminHandler := admin.NewTerraformMirrorHandler(tfMirrorRepo)
 	tfMirrorAdminHandler.SetSyncJob(tfMirrorSyncJob)
 	tfMirrorAdminHandler.SetStorageBackend(storageBackend) // delete stored binaries when a version is removed
+	tfMirrorAdminHandler.SetDeletionFailureRecorder(repositories.NewStorageDeletionFailureRepository(db))
 	tfMirrorAdminHandler.SetEgressGuard(egressGuard)
+	tfMirrorAdminHandler.SetEOLRefresher(tfEOLRefreshJob)
 	releasesGPGKeysAdminHandler := admin.NewReleasesGPGKeysHandler(releasesKeyRepo, tfMirrorRepo, cfg.ReleasesGPGKeys)
 	versionApprovalHandler := admin.NewVersionApprovalHandler(repositories.NewVersionApprovalRepository(sqlxDB))
-	providerAdminHandlers := admin.NewProviderAdminHandlers(db, storageBackend, cfg)
+	providerAdminHandlers := admin.NewProviderAdminHandlers(db, storageBackend, cfg).
+		WithImmutability(artifactImmutability).
+		WithMirrorBlobs(mirrorBlobs).
+		WithMirrorRepo(mirrorRepo).
+		WithEvents(eventBus)
+	mirrorHandlers.SetProviderRemover(providerAdminHandlers)
 	moduleAdminHandlers := admin.NewModuleAdminHandlers(db, storageBackend, cfg).
 		WithModuleDocs(moduleDocsRepo).
-		WithScanQueue(scanRepo)
+		WithScanQueue(scanRepo).
+		WithSCM(scmRepo).
+		WithImmutability(artifactImmutability).
+		WithEvents(eventBus)
+	artifactImmutabilityHandlers := admin.NewArtifactImmutabilityHandlers(&cfg.ImmutableArtifacts, identityDB, artifactImmutabilityRepo, artifactImmutability)
+
+	// Self-service namespace claims: organization members request a
+	// namespace, admins approve it, and approval writes the namespace_claims
+	// row the namespace authorizer enforces.
+	nsClaimRequestRepo := repositories.NewNamespaceClaimRequestRepository(db)
+	namespaceClaims := services.NewNamespaceClaims(&cfg.NamespaceClaims, nsClaimRequestRepo, nsClaimRepo, auditRepo)
+	namespaceClaimHandlers := admin.NewNamespaceClaimHandlers(identityDB, nsClaimRequestRepo, namespaceClaims).
+		WithNotifications(&cfg.Notifications, &cfg.CVE)
+
+	// Namespace landing pages: public description/links/avatar per namespace,
+	// edited by the owning organization.
+	namespaceMetadataHandlers := admin.NewNamespaceMetadataHandlers(repositories.NewNamespaceMetadataRepository(db))
 
 	// GDPR data-subject handlers (Article 15/17/20). Registered under
 	// /api/v1/admin/users/:id/{export,erase} below.
@@ -421,7 +688,16 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 
 	// Role-template CRUD follows the identity schema; mirror methods stay public.
 	rbacRepo := repositories.NewRBACRepositoryWithIdentity(sqlxDB, identitySqlxDB)
-	rbacHandlers := admin.NewRBACHandlers(rbacRepo, userTokenRevocationRepo).WithNotifications(&cfg.Notifications, &cfg.CVE)
+	// Approving an auto_apply mirror request updates the mirror's filters and
+	// starts a sync; shared by the admin review and webhook token paths.
+	approvalProvisioner := services.NewMirrorApprovalProvisioner(rbacRepo, auditRepo).WithSync(mirrorSyncJob)
+	// Role template, mirror policy and OIDC group mapping changes go through
+	// the two-person rule when an admin has turned it on.
+	stagedChanges := admin.NewStagedChanges(repositories.NewStagedChangeRepository(db), auditRepo)
+	rbacHandlers := admin.NewRBACHandlers(rbacRepo, userTokenRevocationRepo).
+		WithNotifications(&cfg.Notifications, &cfg.CVE).
+		WithApprovalProvisioner(approvalProvisioner).
+		WithStagedChanges(stagedChanges)
 
 	// Initialize audit log handlers
 	auditLogHandlers := admin.NewAuditLogHandlers(identityDB)
@@ -431,16 +707,42 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	// shared egress guard for parity with the other SCM outbound paths (#676).
 	sharedMinter := appcreds.NewMinterWithGuard(tokenCipher, scmRepo, egressGuard)
 
+	// Per-module version cap, applied by uploads and SCM publishes alike.
+	versionCap := services.NewVersionCap(moduleRepo, auditRepo)
+
+	// Per-organization pre-publish hooks, called by uploads and SCM publishes.
+	publishHookRepo := repositories.NewPublishHookRepository(db)
+	publishHooks := services.NewPublishHooks(publishHookRepo, tokenCipher, egressGuard)
+	publishHookHandlers := admin.NewPublishHookHandlers(identityDB, publishHookRepo, publishHooks, tokenCipher).WithEgressGuard(egressGuard)
+
+	// Per-organization event webhooks, fed from the event bus.
+	orgEventWebhookRepo := repositories.NewOrgEventWebhookRepository(db)
+	orgEventWebhooks := services.NewOrgEventWebhooks(orgEventWebhookRepo, tokenCipher, egressGuard)
+	eventBus.Subscribe(orgEventWebhooks.Handle)
+	orgEventWebhookHandlers := admin.NewOrgEventWebhookHandlers(identityDB, orgEventWebhookRepo, orgEventWebhooks, tokenCipher).WithEgressGuard(egressGuard)
+
+	// Module and provider usage reported by consumers.
+	usageHandlers := admin.NewUsageHandlers(repositories.NewUsageRepository(db), cfg.UsageReports.StaleAfterDays)
+
 	// Initialize SCM publisher service (needed by scmLinkingHandler)
 	scmPublisher := services.NewSCMPublisher(scmRepo, moduleRepo, storageBackend, tokenCipher).
 		WithScanQueue(scanRepo, &cfg.Scanning).
 		WithModuleDocs(moduleDocsRepo).
-		WithSharedMinter(sharedMinter)
+		WithSharedMinter(sharedMinter).
+		WithVersionCap(versionCap).
+		WithImmutability(artifactImmutability).
+		WithPublishHooks(publishHooks).
+		WithArchiveSizeLimit(cfg.SCMArchiveSizeLimit()).
+		WithScratch(scratchSpace)
 
 	// Initialize the webhook retry job (no-op when max_retries=0)
 	webhookRetryJob := jobs.NewWebhookRetryJob(&cfg.Webhooks, scmRepo, moduleRepo, scmPublisher, tokenCipher)
 	jobRegistry.Register(webhookRetryJob)
 
+	// Initialize the SCM publish queue job, which publishes the tag pushes that
+	// webhook deliveries enqueue.
+	jobRegistry.Register(jobs.NewSCMPublishQueueJob(scmRepo, scmPublisher, tokenCipher))
+
 	// Initialize the CVE polling job (no-op when cve.enabled=false)
 	cveRepo := repositories.NewCVERepository(db)
 	cvePollJob := jobs.NewCVEPollJob(cveRepo, auditRepo, &cfg.Scanning, &cfg.CVE, &cfg.Notifications)
@@ -449,8 +751,11 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 
 	// Initialize SCM handlers with the already-created repositories and token cipher
 	scmProviderHandlers := admin.NewSCMProviderHandlers(cfg, scmRepo, orgRepo, tokenCipher).WithMinter(sharedMinter).WithEgressGuard(egressGuard)
-	scmOAuthHandlers := admin.NewSCMOAuthHandlers(cfg, scmRepo, userRepo, tokenCipher).WithMinter(sharedMinter)
-	scmLinkingHandler := modules.NewSCMLinkingHandler(scmRepo, moduleRepo, tokenCipher, cfg.Server.BaseURL, scmPublisher).WithMinter(sharedMinter)
+	scmOAuthHandlers := admin.NewSCMOAuthHandlers(cfg, scmRepo, userRepo, tokenCipher).WithMinter(sharedMinter).WithEvents(eventBus)
+	scmLinkingHandler := modules.NewSCMLinkingHandler(scmRepo, moduleRepo, tokenCipher, cfg.Server.BaseURL, scmPublisher).
+		WithMinter(sharedMinter).
+		WithRepositoryNameCheck(cfg.SCM.RepositoryNameCheck).
+		WithBackfill(cfg.SCM.BackfillInterval, cfg.Webhooks.MaxRetries)
 
 	// Initialize storage configuration handlers
 	storageHandlers := admin.NewStorageHandlers(cfg, storageConfigRepo, tokenCipher)
@@ -476,11 +781,11 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	// every other existing use (SCM tokens, storage keys, mirror sync, ...).
 	identityTokenCipher, err := buildIdentityTokenCipher(encryptionKey, encryptionKeyPrevious)
 	if err != nil {
-		log.Fatalf("Failed to initialize shared token cipher: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize shared token cipher: %w", err)
 	}
 	identityGuard, err := identityhttpsafe.NewGuard(cfg.Security.Egress.Allowlist)
 	if err != nil {
-		log.Fatalf("invalid security.egress.allowlist: %v", err)
+		return nil, nil, fmt.Errorf("invalid security.egress.allowlist: %w", err)
 	}
 	notificationsSMTPConfig := func() identitymailer.Config {
 		return identitymailer.Config{
@@ -498,10 +803,13 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	notificationChannelHandlers := admin.NewNotificationChannelHandlers(notificationChannelRepo, notifier, identityTokenCipher, identityGuard)
 	cvePollJob.SetNotifier(notifier)
 	scannerUpdateJob.SetNotifier(notifier)
+	mirrorSyncJob.SetNotifier(notifier)
+	tfMirrorSyncJob.SetNotifier(notifier)
 	rbacHandlers.WithNotifier(notifier)
+	namespaceClaimHandlers.WithNotifier(notifier)
 
 	// Initialize OIDC admin configuration handlers
-	oidcAdminHandlers := admin.NewOIDCConfigAdminHandlers(oidcConfigRepo)
+	oidcAdminHandlers := admin.NewOIDCConfigAdminHandlers(oidcConfigRepo).WithStagedChanges(stagedChanges)
 
 	// Initialize setup wizard handlers
 	setupHandlers := setup.NewHandlers(
@@ -518,13 +826,13 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 	}
 	policyEngine, err := policy.NewPolicyEngineWithGuard(policyEngineCfg, egressGuard)
 	if err != nil {
-		log.Fatalf("failed to initialize policy engine: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize policy engine: %w", err)
 	}
 	policyAdminHandler := admin.NewPolicyHandler(policyEngine, cfg.Policy)
 
 	// Initialize SCM webhook handler
-	scmWebhookHandler := webhooks.NewSCMWebhookHandler(scmRepo, scmPublisher, tokenCipher)
-	approvalWebhookHandler := webhooks.NewApprovalHandler(rbacRepo)
+	scmWebhookHandler := webhooks.NewSCMWebhookHandler(scmRepo, tokenCipher).WithPublishRetries(cfg.Webhooks.MaxRetries)
+	approvalWebhookHandler := webhooks.NewApprovalHandler(rbacRepo).WithApprovalProvisioner(approvalProvisioner)
 
 	// Initialize rate limiters (conditionally, based on config)
 	var authRateLimiter, generalRateLimiter, uploadRateLimiter middleware.RateLimiterBackend
@@ -562,6 +870,7 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 			// Per-organization rate limiter (only when configured)
 			if cfg.Security.RateLimiting.OrgRequestsPerMinute > 0 {
 				orgCfg := middleware.RateLimitConfig{
+					Name:              "organization",
 					RequestsPerMinute: cfg.Security.RateLimiting.OrgRequestsPerMinute,
 					BurstSize:         cfg.Security.RateLimiting.OrgBurst,
 					CleanupInterval:   5 * time.Minute,
@@ -585,6 +894,7 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 			// Per-organization rate limiter
 			if cfg.Security.RateLimiting.OrgRequestsPerMinute > 0 {
 				orgCfg := middleware.RateLimitConfig{
+					Name:              "organization",
 					RequestsPerMinute: cfg.Security.RateLimiting.OrgRequestsPerMinute,
 					BurstSize:         cfg.Security.RateLimiting.OrgBurst,
 					CleanupInterval:   5 * time.Minute,
@@ -604,63 +914,100 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 		slog.Info("per-principal rate limit overrides configured", "count", len(cfg.Security.RateLimiting.PrincipalOverrides))
 	}
 
+	// The dev endpoints are always rate limited while dev mode is on,
+	// independent of security.rate_limiting (in-memory: dev mode is not an HA
+	// deployment).
+	var devRateLimiter middleware.RateLimiterBackend
+	if admin.IsDevMode() {
+		devRateLimiter = middleware.NewRateLimiter(middleware.DevRateLimitConfig())
+	}
+
 	// Public + admin API routes (issue #565 finding [39]). See registerAPIV1Routes.
-	registerAPIV1Routes(router, &apiV1RouteDeps{
-		cfg:                         cfg,
-		db:                          db,
-		storageBackend:              storageBackend,
-		sqlxDB:                      sqlxDB,
-		oidcConfigRepo:              oidcConfigRepo,
-		setupHandlers:               setupHandlers,
-		authRateLimiter:             authRateLimiter,
-		generalRateLimiter:          generalRateLimiter,
-		uploadRateLimiter:           uploadRateLimiter,
-		orgRateLimiter:              orgRateLimiter,
-		principalOverrides:          principalOverrides,
-		authHandlers:                authHandlers,
-		userRepo:                    userRepo,
-		apiKeyRepo:                  apiKeyRepo,
-		orgRepo:                     orgRepo,
-		tokenRepo:                   tokenRepo,
-		userTokenRevocationRepo:     userTokenRevocationRepo,
-		moduleAdminHandlers:         moduleAdminHandlers,
-		providerAdminHandlers:       providerAdminHandlers,
-		auditRepo:                   auditRepo,
-		nsAuthz:                     nsAuthz,
-		scanRepo:                    scanRepo,
-		moduleDocsRepo:              moduleDocsRepo,
-		policyEngine:                policyEngine,
-		sbvRepo:                     sbvRepo,
-		scannerApprovalRepo:         scannerApprovalRepo,
-		scannerUpdateJob:            scannerUpdateJob,
-		notificationsHandler:        notificationsHandler,
-		notificationChannelHandlers: notificationChannelHandlers,
-		notifier:                    notifier,
-		apiKeyHandlers:              apiKeyHandlers,
-		userHandlers:                userHandlers,
-		gdprHandlers:                gdprHandlers,
-		orgHandlers:                 orgHandlers,
-		scmProviderHandlers:         scmProviderHandlers,
-		scmOAuthHandlers:            scmOAuthHandlers,
-		scmLinkingHandler:           scmLinkingHandler,
-		mirrorHandlers:              mirrorHandlers,
-		tfMirrorAdminHandler:        tfMirrorAdminHandler,
-		releasesGPGKeysAdminHandler: releasesGPGKeysAdminHandler,
-		rbacHandlers:                rbacHandlers,
-		versionApprovalHandler:      versionApprovalHandler,
-		storageHandlers:             storageHandlers,
-		storageConfigRepo:           storageConfigRepo,
-		moduleRepo:                  moduleRepo,
-		providerRepo:                providerRepo,
-		tokenCipher:                 tokenCipher,
-		oidcAdminHandlers:           oidcAdminHandlers,
-		auditLogHandlers:            auditLogHandlers,
-		policyAdminHandler:          policyAdminHandler,
-		cvePollJob:                  cvePollJob,
-		statsHandlers:               statsHandlers,
-		scmWebhookHandler:           scmWebhookHandler,
-		approvalWebhookHandler:      approvalWebhookHandler,
-		egressGuard:                 egressGuard,
+	registerAPIV1Routes(routes, &apiV1RouteDeps{
+		cfg:                          cfg,
+		db:                           db,
+		storageBackend:               storageBackend,
+		sqlxDB:                       sqlxDB,
+		oidcConfigRepo:               oidcConfigRepo,
+		setupHandlers:                setupHandlers,
+		authRateLimiter:              authRateLimiter,
+		generalRateLimiter:           generalRateLimiter,
+		uploadRateLimiter:            uploadRateLimiter,
+		orgRateLimiter:               orgRateLimiter,
+		devRateLimiter:               devRateLimiter,
+		principalOverrides:           principalOverrides,
+		authHandlers:                 authHandlers,
+		userRepo:                     userRepo,
+		apiKeyRepo:                   apiKeyRepo,
+		orgRepo:                      orgRepo,
+		tokenRepo:                    tokenRepo,
+		userTokenRevocationRepo:      userTokenRevocationRepo,
+		moduleAdminHandlers:          moduleAdminHandlers,
+		providerAdminHandlers:        providerAdminHandlers,
+		auditRepo:                    auditRepo,
+		nsAuthz:                      nsAuthz,
+		scanRepo:                     scanRepo,
+		moduleDocsRepo:               moduleDocsRepo,
+		policyEngine:                 policyEngine,
+		sbvRepo:                      sbvRepo,
+		scannerApprovalRepo:          scannerApprovalRepo,
+		scannerUpdateJob:             scannerUpdateJob,
+		notificationsHandler:         notificationsHandler,
+		notificationChannelHandlers:  notificationChannelHandlers,
+		notifier:                     notifier,
+		versionCap:                   versionCap,
+		artifactImmutability:         artifactImmutability,
+		artifactImmutabilityHandlers: artifactImmutabilityHandlers,
+		publishHooks:                 publishHooks,
+		scratchSpace:                 scratchSpace,
+		publishHookHandlers:          publishHookHandlers,
+		orgEventWebhookHandlers:      orgEventWebhookHandlers,
+		usageHandlers:                usageHandlers,
+		namespaceClaimHandlers:       namespaceClaimHandlers,
+		namespaceMetadataHandlers:    namespaceMetadataHandlers,
+		apiKeyHandlers:               apiKeyHandlers,
+		apiKeyPolicyHandlers:         apiKeyPolicyHandlers,
+		scopeCeilingHandlers:         scopeCeilingHandlers,
+		moduleApprovalHandlers:       moduleApprovalHandlers,
+		ciTrustRuleHandlers:          ciTrustRuleHandlers,
+		tenantDomainHandlers:         tenantDomainHandlers,
+		tokenExchangeHandlers:        tokenExchangeHandlers,
+		cliTokenHandlers:             cliTokenHandlers,
+		userHandlers:                 userHandlers,
+		gdprHandlers:                 gdprHandlers,
+		orgHandlers:                  orgHandlers,
+		scmProviderHandlers:          scmProviderHandlers,
+		scmOAuthHandlers:             scmOAuthHandlers,
+		scmLinkingHandler:            scmLinkingHandler,
+		mirrorHandlers:               mirrorHandlers,
+		mirrorAllowlistHandlers:      mirrorAllowlistHandlers,
+		mirrorHostnameAliasHandlers:  mirrorHostnameAliasHandlers,
+		operationsRegistry:           operationsRegistry,
+		operationsHandlers:           operationsHandlers,
+		maintenanceHandlers:          maintenanceHandlers,
+		stagedChanges:                stagedChanges,
+		cryptoHandlers:               cryptoHandlers,
+		storageConsistencyHandlers:   storageConsistencyHandlers,
+		codeIndexHandlers:            codeIndexHandlers,
+		identityLinkHandlers:         identityLinkHandlers,
+		tfMirrorAdminHandler:         tfMirrorAdminHandler,
+		releasesGPGKeysAdminHandler:  releasesGPGKeysAdminHandler,
+		rbacHandlers:                 rbacHandlers,
+		versionApprovalHandler:       versionApprovalHandler,
+		storageHandlers:              storageHandlers,
+		storageConfigRepo:            storageConfigRepo,
+		moduleRepo:                   moduleRepo,
+		providerRepo:                 providerRepo,
+		tokenCipher:                  tokenCipher,
+		oidcAdminHandlers:            oidcAdminHandlers,
+		auditLogHandlers:             auditLogHandlers,
+		policyAdminHandler:           policyAdminHandler,
+		cvePollJob:                   cvePollJob,
+		jobsHandler:                  admin.NewJobsHandler(jobRegistry),
+		statsHandlers:                statsHandlers,
+		scmWebhookHandler:            scmWebhookHandler,
+		approvalWebhookHandler:       approvalWebhookHandler,
+		egressGuard:                  egressGuard,
 	})
 
 	// Start every registered background job now that all wiring is complete.
@@ -671,17 +1018,19 @@ func NewRouter(cfg *config.Config, db, identityDB *sql.DB) (*gin.Engine, *Backgr
 
 	bg := &BackgroundServices{
 		jobs:               jobRegistry,
-		rateLimiters:       collectRateLimiterBackends(authRateLimiter, generalRateLimiter, uploadRateLimiter, orgRateLimiter),
+		rateLimiters:       collectRateLimiterBackends(authRateLimiter, generalRateLimiter, uploadRateLimiter, orgRateLimiter, devRateLimiter),
 		principalOverrides: principalOverrides,
+		operations:         operationsRegistry,
+		stateStore:         oidcStateStore,
 	}
 
-	return router, bg
+	return router, bg, nil
 }
 
 // shouldRejectLowEntropyEncryptionKey reports whether NewRouter should refuse
 // to start given ENCRYPTION_KEY's estimated entropy. Extracted from the
 // entropy check inline in NewRouter so the fail-closed decision (issue #560)
-// can be unit tested without exercising log.Fatal.
+// can be unit tested without constructing a router.
 func shouldRejectLowEntropyEncryptionKey(encryptionKey []byte, overrideAllowed bool) bool {
 	return crypto.IsLikelyLowEntropySecret(encryptionKey) && !overrideAllowed
 }
@@ -696,24 +1045,16 @@ func allowLowEntropyEncryptionKey() bool {
 }
 
 // @Summary      Health check
-// @Description  Returns the health status of the service, including database connectivity.
+// @Description  Process liveness probe. Does not touch the database or storage backend so a brief database outage does not cause the orchestrator to restart healthy replicas; use /ready for dependency checks.
 // @Tags         System
 // @Produce      json
 // @Success      200  {object}  api.HealthResponse
-// @Failure      503  {object}  api.HealthResponse
 // @Router       /health [get]
-// healthCheckHandler returns the health status of the service
-func healthCheckHandler(db *sql.DB) gin.HandlerFunc {
+// healthCheckHandler returns the liveness status of the service. It
+// deliberately performs no dependency checks: if the process can serve this
+// request it is alive.
+func healthCheckHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Check database connection
-		if err := db.Ping(); err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"status": "unhealthy",
-				"error":  "database connection failed",
-			})
-			return
-		}
-
 		c.JSON(http.StatusOK, gin.H{
 			"status":     "healthy",
 			"time":       time.Now().UTC().Format(time.RFC3339),
@@ -723,51 +1064,118 @@ func healthCheckHandler(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
+// readinessCacheTTL bounds how often /ready actually probes the database and
+// storage backend. Probe storms (many replicas, aggressive probe periods, load
+// balancer health checks) are served from the cached result instead of adding
+// load to a dependency that may already be struggling.
+const readinessCacheTTL = 2 * time.Second
+
+// readinessProbeTimeout caps a single dependency check so a hung connection
+// cannot stall the probe past the orchestrator's own timeout.
+const readinessProbeTimeout = 3 * time.Second
+
+// readinessResult is a cached readiness evaluation.
+type readinessResult struct {
+	status int
+	body   gin.H
+	at     time.Time
+}
+
+// readinessChecker evaluates and caches dependency readiness.
+type readinessChecker struct {
+	db      *sql.DB
+	storage storage.Storage
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu   sync.Mutex
+	last *readinessResult
+}
+
+// check returns the cached result when it is younger than ttl, otherwise
+// probes the dependencies. The mutex is held across the probe so concurrent
+// callers share one evaluation rather than each hitting the database.
+func (r *readinessChecker) check(ctx context.Context) readinessResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.last != nil && r.now().Sub(r.last.at) < r.ttl {
+		return *r.last
+	}
+	res := r.probe(ctx)
+	res.at = r.now()
+	r.last = &res
+	return res
+}
+
+func (r *readinessChecker) probe(ctx context.Context) readinessResult {
+	ctx, cancel := context.WithTimeout(ctx, readinessProbeTimeout)
+	defer cancel()
+
+	checks := gin.H{}
+
+	// Check database connection
+	if err := r.db.PingContext(ctx); err != nil {
+		checks["database"] = "unhealthy"
+		return readinessResult{status: http.StatusServiceUnavailable, body: gin.H{
+			"ready":  false,
+			"checks": checks,
+			"error":  "database not ready",
+		}}
+	}
+	checks["database"] = "healthy"
+
+	// Check storage backend — probe with a known-absent sentinel path.
+	// Exists() exercises authentication and network connectivity without
+	// creating any state.
+	if _, err := r.storage.Exists(ctx, ".readiness-probe"); err != nil {
+		checks["storage"] = "unhealthy"
+		return readinessResult{status: http.StatusServiceUnavailable, body: gin.H{
+			"ready":  false,
+			"checks": checks,
+			"error":  "storage backend not ready",
+		}}
+	}
+	checks["storage"] = "healthy"
+
+	return readinessResult{status: http.StatusOK, body: gin.H{
+		"ready":  true,
+		"checks": checks,
+		"time":   time.Now().UTC().Format(time.RFC3339),
+	}}
+}
+
 // @Summary      Readiness check
-// @Description  Returns whether the service is ready to accept traffic. Checks database connectivity.
+// @Description  Returns whether the service is ready to accept traffic. Checks database connectivity and the storage backend. Results are cached for a couple of seconds. Read-only maintenance mode is reported under maintenance and does not fail readiness, since reads are still served.
 // @Tags         System
 // @Produce      json
 // @Success      200  {object}  api.ReadinessResponse
 // @Failure      503  {object}  api.ReadinessResponse
 // @Router       /ready [get]
 // readinessHandler returns the readiness status of the service.
-// Unlike the liveness probe (/health), this also checks the storage backend so
-// that a Kubernetes readiness gate fails when uploads/downloads would error.
-func readinessHandler(db *sql.DB, storageBackend storage.Storage) gin.HandlerFunc {
+// Unlike the liveness probe (/health), this checks the database and storage
+// backend so that a Kubernetes readiness gate fails when uploads/downloads
+// would error. Results are cached for readinessCacheTTL. Maintenance mode,
+// when maintenance is non-nil and the mode is on, is added to the body but
+// leaves the status alone: a replica in maintenance still serves reads.
+func readinessHandler(db *sql.DB, storageBackend storage.Storage, maintenance *middleware.MaintenanceState) gin.HandlerFunc {
+	checker := &readinessChecker{db: db, storage: storageBackend, ttl: readinessCacheTTL, now: time.Now}
 	return func(c *gin.Context) {
-		checks := gin.H{}
-
-		// Check database connection
-		if err := db.Ping(); err != nil {
-			checks["database"] = "unhealthy"
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"ready":  false,
-				"checks": checks,
-				"error":  "database not ready",
-			})
-			return
+		res := checker.check(c.Request.Context())
+		if res.status != http.StatusOK {
+			c.Header("Retry-After", "5")
 		}
-		checks["database"] = "healthy"
-
-		// Check storage backend — probe with a known-absent sentinel path.
-		// Exists() exercises authentication and network connectivity without
-		// creating any state.
-		if _, err := storageBackend.Exists(c.Request.Context(), ".readiness-probe"); err != nil {
-			checks["storage"] = "unhealthy"
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"ready":  false,
-				"checks": checks,
-				"error":  "storage backend not ready",
-			})
-			return
+		body := res.body
+		if maintenance != nil {
+			if mode := maintenance.Current(c.Request.Context()); mode.Enabled {
+				// Copy so the cached result is not mutated.
+				body = gin.H{"maintenance": mode}
+				for k, v := range res.body {
+					body[k] = v
+				}
+			}
 		}
-		checks["storage"] = "healthy"
-
-		c.JSON(http.StatusOK, gin.H{
-			"ready":  true,
-			"checks": checks,
-			"time":   time.Now().UTC().Format(time.RFC3339),
-		})
+		c.JSON(res.status, body)
 	}
 }
 
@@ -784,10 +1192,11 @@ func readinessHandler(db *sql.DB, storageBackend storage.Storage) gin.HandlerFun
 // "source = HOST/ns/name/system" against and that the State Manager captures for
 // the suite "Consumed by" join, so it must match the join key the suite proxy
 // emits (also GetPublicURL-derived). In the default deploy public_url is empty
-// and this is byte-for-byte identical to the previous base_url output.
+// and this is byte-for-byte identical to the previous base_url output. On a
+// custom tenant domain the URLs point at that domain instead.
 func serviceDiscoveryHandler(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		publicURL := cfg.Server.GetPublicURL()
+		publicURL := middleware.TenantPublicURL(c, cfg.Server.GetPublicURL())
 		c.JSON(http.StatusOK, gin.H{
 			"modules.v1":   publicURL + "/v1/modules/",
 			"providers.v1": publicURL + "/v1/providers/",
@@ -811,14 +1220,13 @@ func versionHandler(cfg *config.Config) gin.HandlerFunc {
 			"api_version":      "v1",
 			"crypto_mode":      AppCryptoMode,
 			"default_language": cfg.Server.DefaultLanguage,
-			"protocols": gin.H{
-				"modules":   "v1",
-				"providers": "v1",
-				"mirror":    "v1",
-			},
+			"protocols":        supportedProtocols,
 			"capabilities": gin.H{
 				"oci": true,
 			},
+			// Lets monitoring alert on a deployment serving the dev login
+			// and impersonation endpoints.
+			"dev_endpoints_active": admin.IsDevMode(),
 		})
 	}
 }
@@ -5,18 +5,21 @@
 // Each takes a small dependency struct built from NewRouter's locals; the
 // route-registration bodies themselves were moved verbatim (not rewritten),
 // with each dependency struct field re-bound to a local of the same name at
-// the top of the function so the body needed no further edits.
+// the top of the function so the body needed no further edits. Routes are
+// added through routeGroup (route_manifest.go), which records each one for
+// GET /api/v1/admin/routes.
 package api
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -24,10 +27,14 @@ import (
 	"github.com/terraform-registry/terraform-registry/docs"
 	"github.com/terraform-registry/terraform-registry/internal/api/admin"
 	"github.com/terraform-registry/terraform-registry/internal/api/advisories"
+	"github.com/terraform-registry/terraform-registry/internal/api/badges"
+	"github.com/terraform-registry/terraform-registry/internal/api/feeds"
+	"github.com/terraform-registry/terraform-registry/internal/api/licensegate"
 	"github.com/terraform-registry/terraform-registry/internal/api/mirror"
 	"github.com/terraform-registry/terraform-registry/internal/api/modules"
 	"github.com/terraform-registry/terraform-registry/internal/api/oci"
 	"github.com/terraform-registry/terraform-registry/internal/api/providers"
+	"github.com/terraform-registry/terraform-registry/internal/api/providersv2"
 	"github.com/terraform-registry/terraform-registry/internal/api/scim"
 	"github.com/terraform-registry/terraform-registry/internal/api/setup"
 	terraform_binaries "github.com/terraform-registry/terraform-registry/internal/api/terraform_binaries"
@@ -37,11 +44,14 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/downloadstats"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
 	"github.com/terraform-registry/terraform-registry/internal/jobs"
 	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/notify"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
 	"github.com/terraform-registry/terraform-registry/internal/policy"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
 	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
@@ -59,13 +69,20 @@ type publicRouteDeps struct {
 	userTokenRevocationRepo *repositories.UserTokenRevocationRepository
 	auditRepo               *repositories.AuditRepository
 	pullThroughSvc          *services.PullThroughService
+	mirrorAllowlist         *middleware.MirrorAllowlist
+	mirrorHostnameAliases   *middleware.MirrorHostnameAliases
+	mirrorRepo              *repositories.MirrorRepository
+	maintenanceState        *middleware.MaintenanceState
+	moduleProxySvc          *services.ModuleProxyService
+	providerDocsSvc         *services.ProviderDocsPassthrough
+	downloadStats           *downloadstats.Recorder
 	tfBinariesHandler       *terraform_binaries.Handler
 }
 
 // registerPublicRoutes wires the unauthenticated Terraform-protocol/OCI/Swagger
 // route table onto router.
 // coverage:skip:integration-only — registers the Terraform-protocol/OCI/Swagger route table; tested via E2E
-func registerPublicRoutes(router *gin.Engine, d *publicRouteDeps) {
+func registerPublicRoutes(router *routeGroup, d *publicRouteDeps) {
 	cfg := d.cfg
 	db := d.db
 	storageBackend := d.storageBackend
@@ -80,10 +97,10 @@ func registerPublicRoutes(router *gin.Engine, d *publicRouteDeps) {
 	tfBinariesHandler := d.tfBinariesHandler
 
 	// Health check endpoint
-	router.GET("/health", healthCheckHandler(db))
+	router.GET("/health", healthCheckHandler())
 
 	// Readiness check endpoint (includes storage backend probe)
-	router.GET("/ready", readinessHandler(db, storageBackend))
+	router.GET("/ready", readinessHandler(db, storageBackend, d.maintenanceState))
 
 	// Service discovery endpoint (Terraform protocol)
 	router.GET("/.well-known/terraform.json", serviceDiscoveryHandler(cfg))
@@ -189,60 +206,33 @@ func registerPublicRoutes(router *gin.Engine, d *publicRouteDeps) {
 		c.Redirect(http.StatusMovedPermanently, "/api-docs/")
 	})
 
-	// Raw Swagger JSON endpoint - serve embedded spec with runtime metadata
+	// Raw Swagger JSON endpoint - serve embedded spec with runtime metadata.
+	// ?group=admin|protocol|public narrows the spec to one API surface; the
+	// variants are built once at startup.
+	swaggerSpecs, err := buildSwaggerSpecs(docs.SwaggerJSON, cfg.ApiDocs)
+	if err != nil {
+		log.Printf("failed to build swagger.json variants: %v", err)
+	}
 	router.GET("/swagger.json", func(c *gin.Context) {
-		c.Header("Content-Type", "application/json")
 		c.Header("Access-Control-Allow-Origin", "*")
 
-		data := docs.SwaggerJSON
-
-		// Unmarshal to a generic map so we can override the info fields
-		var doc map[string]interface{}
-		if err := json.Unmarshal(data, &doc); err != nil {
-			log.Printf("failed to unmarshal swagger.json: %v", err)
-			c.Data(http.StatusOK, "application/json", data)
+		group := c.Query("group")
+		if swaggerSpecs == nil {
+			if group != "" {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Grouped API specs are unavailable"})
+				return
+			}
+			c.Data(http.StatusOK, "application/json", docs.SwaggerJSON)
 			return
 		}
-
-		// Ensure info object exists
-		info, _ := doc["info"].(map[string]interface{})
-		if info == nil {
-			info = map[string]interface{}{}
-			doc["info"] = info
-		}
-
-		// Inject configured metadata if provided
-		if cfg.ApiDocs.TermsOfService != "" {
-			info["termsOfService"] = cfg.ApiDocs.TermsOfService
-		}
-		// Contact
-		contact, _ := info["contact"].(map[string]interface{})
-		if contact == nil {
-			contact = map[string]interface{}{}
-			info["contact"] = contact
-		}
-		if cfg.ApiDocs.ContactName != "" {
-			contact["name"] = cfg.ApiDocs.ContactName
-		}
-		if cfg.ApiDocs.ContactEmail != "" {
-			contact["email"] = cfg.ApiDocs.ContactEmail
-		}
-
-		// License
-		if cfg.ApiDocs.License != "" {
-			license := map[string]interface{}{"name": cfg.ApiDocs.License}
-			info["license"] = license
-		}
-
-		// Marshal back to JSON and return
-		out, err := json.Marshal(doc)
-		if err != nil {
-			log.Printf("failed to marshal modified swagger.json: %v", err)
-			c.Data(http.StatusOK, "application/json", data)
+		spec, ok := swaggerSpecs[group]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Unknown spec group %q (expected one of: %s)", group, strings.Join(specGroups, ", ")),
+			})
 			return
 		}
-
-		c.Data(http.StatusOK, "application/json", out)
+		c.Data(http.StatusOK, "application/json", spec)
 	})
 
 	// Raw OpenAPI 3 JSON — same spec as /swagger.json converted to OpenAPI 3
@@ -256,113 +246,188 @@ func registerPublicRoutes(router *gin.Engine, d *publicRouteDeps) {
 		c.Data(http.StatusOK, "application/json", docs.OpenAPI3JSON)
 	})
 
+	// HEAD on the artifact download endpoints describes the archive from
+	// storage metadata; download_head.mode "disabled" leaves them unregistered.
+	downloadHead := cfg.DownloadHead.Mode != config.DownloadHeadDisabled
+
 	// Module Registry endpoints (v1) - Terraform Protocol
-	// These are public endpoints that support optional authentication
+	// These are public endpoints that support optional authentication, and
+	// the only ones (with /v1/providers) that accept Terraform CLI tokens
 	v1Modules := router.Group("/v1/modules")
-	v1Modules.Use(middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
+	v1Modules.Use(middleware.AllowCLITokens())
+	v1Modules.UseAuth(routeAuthOptional, middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
 	{
+		// Discovery endpoints in the public registry's format, for IDE plugins and docs tooling
+		v1Modules.GET("", modules.ProtocolListHandler(db, cfg))
+		v1Modules.GET("/search", modules.ProtocolSearchHandler(db, cfg))
 		v1Modules.GET("/:namespace/:name/:system/versions", modules.ListVersionsHandler(db, cfg))
-		v1Modules.GET("/:namespace/:name/:system/:version/download", modules.DownloadHandler(db, storageBackend, cfg, auditRepo))
+		moduleDownload := modules.DownloadHandler(db, storageBackend, cfg, auditRepo)
+		v1Modules.GET("/:namespace/:name/:system/:version/download", moduleDownload)
+		if downloadHead {
+			v1Modules.HEAD("/:namespace/:name/:system/:version/download", moduleDownload)
+		}
+
+		// Module pull-through proxy: fetch-and-cache from an upstream registry
+		if d.moduleProxySvc != nil {
+			v1Modules.GET("/proxy/:hostname/:namespace/:name/:system/versions", modules.ProxyListVersionsHandler(d.moduleProxySvc))
+			v1Modules.GET("/proxy/:hostname/:namespace/:name/:system/:version/download", modules.ProxyDownloadHandler(d.moduleProxySvc, repositories.NewModuleRepository(db), storageBackend))
+		}
 	}
 
 	// File serving endpoint for local storage with ServeDirectly enabled
-	router.GET("/v1/files/*filepath", modules.ServeFileHandler(storageBackend, cfg, db, auditRepo))
+	serveFile := modules.ServeFileHandler(storageBackend, cfg, db, auditRepo)
+	router.GET("/v1/files/*filepath", serveFile)
+	if downloadHead {
+		router.HEAD("/v1/files/*filepath", serveFile)
+	}
 
 	// Provider Registry endpoints (v1)
 	// These are for the standard Provider Registry Protocol
+	// License-gated providers are only downloaded by users who accepted them,
+	// through either the registry protocol or the network mirror.
+	providerLicenses := licensegate.New(repositories.NewProviderLicenseRepository(db))
+
 	v1Providers := router.Group("/v1/providers")
-	v1Providers.Use(middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
+	v1Providers.Use(middleware.AllowCLITokens())
+	v1Providers.UseAuth(routeAuthOptional, middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
 	{
 		v1Providers.GET("/:namespace/:type/versions", providers.ListVersionsHandler(db, cfg))
-		v1Providers.GET("/:namespace/:type/:version/download/:os/:arch", providers.DownloadHandler(db, storageBackend, cfg, auditRepo))
+		v1Providers.GET("/:namespace/:type/resolve", providers.ResolveHandler(db))
+		providerDownload := providers.DownloadHandler(db, storageBackend, cfg, auditRepo, d.downloadStats, providerLicenses)
+		v1Providers.GET("/:namespace/:type/:version/download/:os/:arch", providerDownload)
+		if downloadHead {
+			v1Providers.HEAD("/:namespace/:type/:version/download/:os/:arch", providerDownload)
+		}
+		// Upstream documentation for mirrored providers; each mirror opts in.
+		v1Providers.GET("/:namespace/:type/:version/docs", providers.ProviderDocsPassthroughHandler(d.providerDocsSvc))
+		v1Providers.GET("/:namespace/:type/:version/docs/:category/:slug", providers.ProviderDocPagePassthroughHandler(d.providerDocsSvc))
 	}
 
 	// Network Mirror endpoints (separate from Provider Registry to avoid routing conflicts)
 	// These endpoints include the hostname of the origin registry as per the Network Mirror Protocol
 	// They use a different path structure: /terraform/providers/:hostname/:namespace/:type/...
 	v1Mirror := router.Group("/terraform/providers")
+	// Only providers on the admin-managed allowlist are served (empty = all).
+	// Authentication is optional; it identifies the requester's organizations
+	// so private mirrors' providers can be served to their members.
+	v1Mirror.Use(middleware.MirrorAllowlistMiddleware(d.mirrorAllowlist))
+	v1Mirror.Use(middleware.AllowCLITokens())
+	v1Mirror.UseAuth(routeAuthOptional, middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
+	mirrorVisibility := mirror.NewVisibility(d.mirrorRepo)
 	{
-		v1Mirror.GET("/:hostname/:namespace/:type/index.json", mirror.IndexHandler(db, cfg, pullThroughSvc))
-		v1Mirror.GET("/:hostname/:namespace/:type/:versionfile", mirror.PlatformIndexHandler(db, cfg, auditRepo, pullThroughSvc))
+		v1Mirror.GET("/:hostname/:namespace/:type/index.json", mirror.IndexHandler(db, cfg, pullThroughSvc, d.mirrorHostnameAliases, mirrorVisibility))
+		v1Mirror.GET("/:hostname/:namespace/:type/:versionfile", mirror.PlatformIndexHandler(db, cfg, auditRepo, pullThroughSvc, d.downloadStats, d.mirrorHostnameAliases, mirrorVisibility, providerLicenses))
 	}
 
 	// Terraform Binary Mirror endpoints (public by default, protected when auth mode is configured)
 	// Allows clients to discover and download official Terraform/OpenTofu binaries synced by
 	// any named mirror config.  The :name segment identifies the mirror configuration.
 	tfBinaries := router.Group("/terraform/binaries")
-	tfBinaries.Use(middleware.BinaryMirrorAuthMiddleware(cfg.BinaryMirror))
+	tfBinaries.UseAuth(binaryMirrorRouteAuth(cfg.BinaryMirror.Auth), middleware.BinaryMirrorAuthMiddleware(cfg.BinaryMirror))
 	{
 		tfBinaries.GET("", tfBinariesHandler.ListConfigs)
 		tfBinaries.GET("/:name/versions", tfBinariesHandler.ListVersions)
 		tfBinaries.GET("/:name/versions/latest", tfBinariesHandler.GetLatestVersion)
 		tfBinaries.GET("/:name/versions/:version", tfBinariesHandler.GetVersion)
 		tfBinaries.GET("/:name/versions/:version/:os/:arch", tfBinariesHandler.DownloadBinary)
+		if downloadHead {
+			tfBinaries.HEAD("/:name/versions/:version/:os/:arch", tfBinariesHandler.DownloadBinary)
+		}
 	}
 }
 
 // apiV1RouteDeps holds every dependency registerAPIV1Routes needs.
 type apiV1RouteDeps struct {
-	cfg                         *config.Config
-	db                          *sql.DB
-	storageBackend              storage.Storage
-	sqlxDB                      *sqlx.DB
-	oidcConfigRepo              *repositories.OIDCConfigRepository
-	setupHandlers               *setup.Handlers
-	authRateLimiter             middleware.RateLimiterBackend
-	generalRateLimiter          middleware.RateLimiterBackend
-	uploadRateLimiter           middleware.RateLimiterBackend
-	orgRateLimiter              middleware.RateLimiterBackend
-	principalOverrides          *middleware.PrincipalOverrideLimiters
-	authHandlers                *admin.AuthHandlers
-	userRepo                    *repositories.UserRepository
-	apiKeyRepo                  *repositories.APIKeyRepository
-	orgRepo                     *repositories.OrganizationRepository
-	tokenRepo                   *repositories.TokenRepository
-	userTokenRevocationRepo     *repositories.UserTokenRevocationRepository
-	moduleAdminHandlers         *admin.ModuleAdminHandlers
-	providerAdminHandlers       *admin.ProviderAdminHandlers
-	auditRepo                   *repositories.AuditRepository
-	nsAuthz                     *middleware.NamespaceAuthorizer
-	scanRepo                    *repositories.ModuleScanRepository
-	moduleDocsRepo              *repositories.ModuleDocsRepository
-	policyEngine                *policy.PolicyEngine
-	sbvRepo                     *repositories.ScannerBinaryVersionRepository
-	scannerApprovalRepo         *repositories.VersionApprovalRepository
-	scannerUpdateJob            *jobs.ScannerUpdateJob
-	notificationsHandler        *admin.NotificationsHandler
-	notificationChannelHandlers *admin.NotificationChannelHandlers
-	notifier                    *notify.Notifier
-	apiKeyHandlers              *admin.APIKeyHandlers
-	userHandlers                *admin.UserHandlers
-	gdprHandlers                *admin.GDPRHandlers
-	orgHandlers                 *admin.OrganizationHandlers
-	scmProviderHandlers         *admin.SCMProviderHandlers
-	scmOAuthHandlers            *admin.SCMOAuthHandlers
-	scmLinkingHandler           *modules.SCMLinkingHandler
-	mirrorHandlers              *admin.MirrorHandler
-	tfMirrorAdminHandler        *admin.TerraformMirrorHandler
-	releasesGPGKeysAdminHandler *admin.ReleasesGPGKeysHandler
-	rbacHandlers                *admin.RBACHandlers
-	versionApprovalHandler      *admin.VersionApprovalHandler
-	storageHandlers             *admin.StorageHandlers
-	storageConfigRepo           *repositories.StorageConfigRepository
-	moduleRepo                  *repositories.ModuleRepository
-	providerRepo                *repositories.ProviderRepository
-	tokenCipher                 *crypto.TokenCipher
-	oidcAdminHandlers           *admin.OIDCConfigAdminHandlers
-	auditLogHandlers            *admin.AuditLogHandlers
-	policyAdminHandler          *admin.PolicyHandler
-	cvePollJob                  *jobs.CVEPollJob
-	statsHandlers               *admin.StatsHandler
-	scmWebhookHandler           *webhooks.SCMWebhookHandler
-	approvalWebhookHandler      *webhooks.ApprovalHandler
-	egressGuard                 *httpsafe.Guard
+	cfg                          *config.Config
+	db                           *sql.DB
+	storageBackend               storage.Storage
+	sqlxDB                       *sqlx.DB
+	oidcConfigRepo               *repositories.OIDCConfigRepository
+	setupHandlers                *setup.Handlers
+	authRateLimiter              middleware.RateLimiterBackend
+	generalRateLimiter           middleware.RateLimiterBackend
+	uploadRateLimiter            middleware.RateLimiterBackend
+	orgRateLimiter               middleware.RateLimiterBackend
+	devRateLimiter               middleware.RateLimiterBackend
+	principalOverrides           *middleware.PrincipalOverrideLimiters
+	authHandlers                 *admin.AuthHandlers
+	userRepo                     *repositories.UserRepository
+	apiKeyRepo                   *repositories.APIKeyRepository
+	orgRepo                      *repositories.OrganizationRepository
+	tokenRepo                    *repositories.TokenRepository
+	userTokenRevocationRepo      *repositories.UserTokenRevocationRepository
+	moduleAdminHandlers          *admin.ModuleAdminHandlers
+	providerAdminHandlers        *admin.ProviderAdminHandlers
+	auditRepo                    *repositories.AuditRepository
+	nsAuthz                      *middleware.NamespaceAuthorizer
+	scanRepo                     *repositories.ModuleScanRepository
+	moduleDocsRepo               *repositories.ModuleDocsRepository
+	policyEngine                 *policy.PolicyEngine
+	sbvRepo                      *repositories.ScannerBinaryVersionRepository
+	scannerApprovalRepo          *repositories.VersionApprovalRepository
+	scannerUpdateJob             *jobs.ScannerUpdateJob
+	notificationsHandler         *admin.NotificationsHandler
+	notificationChannelHandlers  *admin.NotificationChannelHandlers
+	notifier                     *notify.Notifier
+	versionCap                   *services.VersionCap
+	artifactImmutability         *services.ArtifactImmutability
+	artifactImmutabilityHandlers *admin.ArtifactImmutabilityHandlers
+	publishHooks                 *services.PublishHooks
+	scratchSpace                 *scratch.Space
+	publishHookHandlers          *admin.PublishHookHandlers
+	orgEventWebhookHandlers      *admin.OrgEventWebhookHandlers
+	usageHandlers                *admin.UsageHandlers
+	namespaceClaimHandlers       *admin.NamespaceClaimHandlers
+	namespaceMetadataHandlers    *admin.NamespaceMetadataHandlers
+	apiKeyHandlers               *admin.APIKeyHandlers
+	apiKeyPolicyHandlers         *admin.APIKeyPolicyHandlers
+	scopeCeilingHandlers         *admin.ScopeCeilingHandlers
+	moduleApprovalHandlers       *admin.ModuleApprovalHandlers
+	ciTrustRuleHandlers          *admin.CITrustRuleHandlers
+	tenantDomainHandlers         *admin.TenantDomainHandlers
+	tokenExchangeHandlers        *admin.TokenExchangeHandlers
+	cliTokenHandlers             *admin.CLITokenHandlers
+	userHandlers                 *admin.UserHandlers
+	gdprHandlers                 *admin.GDPRHandlers
+	orgHandlers                  *admin.OrganizationHandlers
+	scmProviderHandlers          *admin.SCMProviderHandlers
+	scmOAuthHandlers             *admin.SCMOAuthHandlers
+	scmLinkingHandler            *modules.SCMLinkingHandler
+	mirrorHandlers               *admin.MirrorHandler
+	mirrorAllowlistHandlers      *admin.MirrorAllowlistHandlers
+	mirrorHostnameAliasHandlers  *admin.MirrorHostnameAliasHandlers
+	operationsRegistry           *operations.Registry
+	operationsHandlers           *admin.OperationsHandlers
+	maintenanceHandlers          *admin.MaintenanceHandlers
+	stagedChanges                *admin.StagedChanges
+	cryptoHandlers               *admin.CryptoHandlers
+	storageConsistencyHandlers   *admin.StorageConsistencyHandlers
+	codeIndexHandlers            *admin.CodeIndexHandlers
+	identityLinkHandlers         *admin.IdentityLinkHandlers
+	tfMirrorAdminHandler         *admin.TerraformMirrorHandler
+	releasesGPGKeysAdminHandler  *admin.ReleasesGPGKeysHandler
+	rbacHandlers                 *admin.RBACHandlers
+	versionApprovalHandler       *admin.VersionApprovalHandler
+	storageHandlers              *admin.StorageHandlers
+	storageConfigRepo            *repositories.StorageConfigRepository
+	moduleRepo                   *repositories.ModuleRepository
+	providerRepo                 *repositories.ProviderRepository
+	tokenCipher                  *crypto.TokenCipher
+	oidcAdminHandlers            *admin.OIDCConfigAdminHandlers
+	auditLogHandlers             *admin.AuditLogHandlers
+	policyAdminHandler           *admin.PolicyHandler
+	cvePollJob                   *jobs.CVEPollJob
+	jobsHandler                  *admin.JobsHandler
+	statsHandlers                *admin.StatsHandler
+	scmWebhookHandler            *webhooks.SCMWebhookHandler
+	approvalWebhookHandler       *webhooks.ApprovalHandler
+	egressGuard                  *httpsafe.Guard
 }
 
 // registerAPIV1Routes wires the /api/v1, /scim/v2, and webhook route table
 // onto router.
 // coverage:skip:integration-only — registers the /api/v1, /scim/v2, and webhook route table; tested via E2E
-func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
+func registerAPIV1Routes(router *routeGroup, d *apiV1RouteDeps) {
 	cfg := d.cfg
 	db := d.db
 	storageBackend := d.storageBackend
@@ -394,6 +459,22 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 	notificationChannelHandlers := d.notificationChannelHandlers
 	notifier := d.notifier
 	apiKeyHandlers := d.apiKeyHandlers
+	apiKeyPolicyHandlers := d.apiKeyPolicyHandlers
+	moduleApprovalHandlers := d.moduleApprovalHandlers
+	ciTrustRuleHandlers := d.ciTrustRuleHandlers
+	tenantDomainHandlers := d.tenantDomainHandlers
+	cliTokenHandlers := d.cliTokenHandlers
+	mirrorAllowlistHandlers := d.mirrorAllowlistHandlers
+	mirrorHostnameAliasHandlers := d.mirrorHostnameAliasHandlers
+	operationsRegistry := d.operationsRegistry
+	operationsHandlers := d.operationsHandlers
+	maintenanceHandlers := d.maintenanceHandlers
+	stagedChanges := d.stagedChanges
+	cryptoHandlers := d.cryptoHandlers
+	storageConsistencyHandlers := d.storageConsistencyHandlers
+	codeIndexHandlers := d.codeIndexHandlers
+	identityLinkHandlers := d.identityLinkHandlers
+	tokenExchangeHandlers := d.tokenExchangeHandlers
 	userHandlers := d.userHandlers
 	gdprHandlers := d.gdprHandlers
 	orgHandlers := d.orgHandlers
@@ -414,11 +495,45 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 	auditLogHandlers := d.auditLogHandlers
 	policyAdminHandler := d.policyAdminHandler
 	cvePollJob := d.cvePollJob
+	jobsHandler := d.jobsHandler
 	statsHandlers := d.statsHandlers
 	scmWebhookHandler := d.scmWebhookHandler
 	approvalWebhookHandler := d.approvalWebhookHandler
 	egressGuard := d.egressGuard
 
+	// Atom feeds of newly published versions (public, rate limited like the
+	// other unauthenticated discovery endpoints)
+	feedHandlers := feeds.NewHandlers(db, cfg)
+	feedGroup := router.Group("/feeds")
+	feedGroup.UseRateLimit(routeRateLimitGeneral, middleware.RateLimitMiddleware(generalRateLimiter))
+	{
+		feedGroup.GET("/namespaces/:namespace", feedHandlers.NamespaceFeed())
+		feedGroup.GET("/modules/:namespace/:name/:system", feedHandlers.ModuleFeed())
+		feedGroup.GET("/providers/:namespace/:type", feedHandlers.ProviderFeed())
+	}
+
+	// README badges for modules (public with optional auth, so callers bound
+	// by an approved_only policy see their approved release; rate limited like
+	// the other unauthenticated discovery endpoints)
+	badgeHandlers := badges.NewHandlers(db)
+	badgeGroup := router.Group("/api/v1/badges/modules/:namespace/:name/:system")
+	badgeGroup.UseRateLimit(routeRateLimitGeneral, middleware.RateLimitMiddleware(generalRateLimiter))
+	badgeGroup.UseAuth(routeAuthOptional, middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
+	{
+		badgeGroup.GET("/version.svg", badgeHandlers.ModuleVersion())
+		badgeGroup.GET("/downloads.svg", badgeHandlers.ModuleDownloads())
+	}
+
+	// Read-only v2 provider API for the web UI: sparse fieldsets and includes
+	// (public, rate limited like /api/v1/providers/search)
+	providersV2Handlers := providersv2.NewHandlers(db, cfg)
+	apiV2 := router.Group("/api/v2")
+	apiV2.UseRateLimit(routeRateLimitGeneral, middleware.RateLimitMiddleware(generalRateLimiter))
+	{
+		apiV2.GET("/providers", providersV2Handlers.List())
+		apiV2.GET("/providers/:namespace/:type", providersV2Handlers.Get())
+	}
+
 	// Admin API endpoints
 	apiV1 := router.Group("/api/v1")
 	{
@@ -430,7 +545,7 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 		// These endpoints are available only during initial setup and are permanently
 		// disabled once setup is completed.
 		setupGroup := apiV1.Group("/setup")
-		setupGroup.Use(middleware.SetupTokenMiddleware(oidcConfigRepo))
+		setupGroup.UseAuth(routeAuthSetupToken, middleware.SetupTokenMiddleware(oidcConfigRepo))
 		{
 			setupGroup.POST("/validate-token", setupHandlers.ValidateToken)
 			setupGroup.POST("/oidc/test", setupHandlers.TestOIDCConfig)
@@ -453,7 +568,7 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 
 		// Public authentication endpoints (no auth required, but rate limited)
 		authGroup := apiV1.Group("/auth")
-		authGroup.Use(middleware.RateLimitMiddleware(authRateLimiter))
+		authGroup.UseRateLimit(routeRateLimitAuth, middleware.RateLimitMiddleware(authRateLimiter))
 		{
 			authGroup.GET("/login", authHandlers.LoginHandler())
 			authGroup.GET("/callback", authHandlers.CallbackHandler())
@@ -466,16 +581,20 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 
 			// LDAP endpoint
 			authGroup.POST("/ldap/login", authHandlers.LDAPLoginHandler())
+
+			// CI OIDC token exchange (namespace-scoped publish tokens)
+			authGroup.POST("/token-exchange", tokenExchangeHandlers.ExchangeHandler())
 		}
 
 		// Public search endpoints (no auth required, but rate limited)
 		// These allow public discovery of modules and providers without authentication
 		var suiteClient *suite.DiscoveryClient
 		publicGroup := apiV1.Group("")
-		publicGroup.Use(middleware.RateLimitMiddleware(generalRateLimiter))
+		publicGroup.UseRateLimit(routeRateLimitGeneral, middleware.RateLimitMiddleware(generalRateLimiter))
 		{
 			publicGroup.GET("/modules/search", modules.SearchHandler(db, cfg))
 			publicGroup.GET("/providers/search", providers.SearchHandler(db, cfg))
+			publicGroup.GET("/search/code", modules.CodeSearchHandler(db, cfg))
 			// CVE advisory banner endpoint — consumed by the frontend to show active advisories
 			advisoryHandlers := advisories.NewHandlers(db)
 			publicGroup.GET("/advisories/active", advisoryHandlers.ListActive())
@@ -485,37 +604,54 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			uiThemeHandlers := uitheme.NewHandlers(sqlxDB)
 			publicGroup.GET("/ui/theme", uiThemeHandlers.GetTheme())
 
+			// How receivers verify outbound webhook signatures
+			publicGroup.GET("/webhooks/verification-info", webhookVerificationInfoHandler())
+
 			// Suite runtime discovery (Phase 0)
 			publicGroup.GET("/suite/manifest", suiteManifestHandler(cfg))
 			publicGroup.GET("/ui/config", uiConfigHandler(cfg, func() *suite.DiscoveryClient { return suiteClient }))
+
+			// Aggregate registry statistics for status pages
+			if cfg.PublicStats.Enabled {
+				statsCache := newPublicStatsCache(repositories.NewPublicStatsRepository(db), cfg.PublicStats.RefreshInterval)
+				publicGroup.GET("/stats/public", publicStatsHandler(statsCache))
+			}
 		}
 		suiteClient = startSuiteDiscovery(cfg)
 
 		// Public detail endpoints — no auth required; optional auth populates user context if a
 		// token is present (used by the frontend to conditionally show management actions).
 		publicDetailGroup := apiV1.Group("")
-		publicDetailGroup.Use(middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
-		publicDetailGroup.Use(middleware.RateLimitMiddleware(generalRateLimiter))
+		publicDetailGroup.UseAuth(routeAuthOptional, middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
+		publicDetailGroup.UseRateLimit(routeRateLimitGeneral, middleware.RateLimitMiddleware(generalRateLimiter))
 		{
 			publicDetailGroup.GET("/modules/:namespace/:name/:system", moduleAdminHandlers.GetModule)
 			publicDetailGroup.GET("/modules/:namespace/:name/:system/:version", moduleAdminHandlers.GetModuleVersion)
 			publicDetailGroup.GET("/modules/:namespace/:name/:system/versions/:version/docs", modules.GetModuleDocsHandler(db))
+			publicDetailGroup.GET("/modules/:namespace/:name/:system/versions/:version/submodules", modules.ListModuleSubmodulesHandler(db))
+			publicDetailGroup.GET("/modules/:namespace/:name/:system/versions/:version/submodules/:submodule", modules.GetModuleSubmoduleHandler(db))
+			publicDetailGroup.GET("/modules/:namespace/:name/:system/versions/:version/changelog", modules.GetModuleChangelogHandler(db))
 			publicDetailGroup.GET("/providers/:namespace/:type", providerAdminHandlers.GetProvider)
+			publicDetailGroup.GET("/providers/:namespace/:type/license", providers.LicenseHandler(db))
 			publicDetailGroup.GET("/providers/:namespace/:type/versions/:version/docs", providers.ListProviderDocsHandler(db))
 			publicDetailGroup.GET("/providers/:namespace/:type/versions/:version/docs/:category/:slug", providers.GetProviderDocContentHandler(db, cfg))
+			publicDetailGroup.GET("/namespaces/:namespace", d.namespaceMetadataHandlers.GetNamespaceHandler())
 		}
 
 		// Authenticated-only endpoints
 		authenticatedGroup := apiV1.Group("")
-		authenticatedGroup.Use(middleware.AuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
+		authenticatedGroup.UseAuth(routeAuthRequired, middleware.AuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
 		authenticatedGroup.Use(middleware.CSRFMiddleware(cfg)) // double-submit cookie CSRF protection + browser-origin Bearer allowlist
-		authenticatedGroup.Use(middleware.PrincipalRateLimitMiddleware(generalRateLimiter, principalOverrides))
-		authenticatedGroup.Use(middleware.OrgRateLimitMiddleware(generalRateLimiter, orgRateLimiter))
+		authenticatedGroup.UseRateLimit(routeRateLimitPrincipal,
+			middleware.PrincipalRateLimitMiddleware(generalRateLimiter, principalOverrides),
+			middleware.OrgRateLimitMiddleware(generalRateLimiter, orgRateLimiter))
 		authenticatedGroup.Use(middleware.AuditMiddleware(auditRepo)) // Audit all authenticated actions
 		{
 			// Auth endpoints (require auth)
 			authenticatedGroup.POST("/auth/refresh", authHandlers.RefreshHandler())
 			authenticatedGroup.GET("/auth/me", authHandlers.MeHandler())
+			// Confirming an identity link authenticates as the existing account
+			authenticatedGroup.POST("/auth/link-identity", identityLinkHandlers.ConfirmLink)
 
 			// Suite coupling: "Consumed by" — which sibling-app states use this
 			// module. Server-proxied to the sibling (2s timeout, [] on any failure),
@@ -523,157 +659,176 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			// Namespaced under /suite to avoid the /modules/:version wildcard.
 			authenticatedGroup.GET("/suite/modules/:namespace/:name/:system/consumers",
 				moduleConsumersHandler(func() *suite.DiscoveryClient { return suiteClient }, cfg, egressGuard))
+			// The module overview's dependents section is the same lookup.
+			moduleConsumers := moduleConsumersLookup(func() *suite.DiscoveryClient { return suiteClient }, cfg, egressGuard)
+			moduleAdminHandlers.WithDependents(func(ctx context.Context, namespace, name, system string) (any, error) {
+				return moduleConsumers(ctx, namespace+"/"+name+"/"+system), nil
+			})
 
 			// Stats endpoints (require auth)
 			authenticatedGroup.GET("/admin/stats/dashboard", statsHandlers.GetDashboardStats)
 
 			// Modules admin endpoints - require write permissions plus
 			// namespace-org authorization (issue #555)
-			authenticatedGroup.POST("/admin/modules/create",
-				middleware.RequireScope(auth.ScopeModulesWrite),
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).POST("/admin/modules/create",
 				nsAuthz.RequirePublishAccessFromJSON(auth.ScopeModulesWrite),
 				moduleAdminHandlers.CreateModuleRecord)
-			authenticatedGroup.GET("/admin/modules/:id",
-				middleware.RequireScope(auth.ScopeModulesRead),
+			authenticatedGroup.WithScope(auth.ScopeModulesRead).GET("/admin/modules/:id",
 				moduleAdminHandlers.GetModuleByIDRecord)
-			authenticatedGroup.PUT("/admin/modules/:id",
-				middleware.RequireScope(auth.ScopeModulesWrite),
+			authenticatedGroup.WithScope(auth.ScopeModulesRead).GET("/admin/modules/:id/versions",
+				moduleAdminHandlers.ListModuleVersions)
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).PUT("/admin/modules/:id",
 				nsAuthz.RequireModuleUpdateAccess(auth.ScopeModulesWrite),
 				moduleAdminHandlers.UpdateModuleRecord)
-			authenticatedGroup.POST("/modules",
-				middleware.RateLimitMiddleware(uploadRateLimiter), // Stricter rate limit for uploads
-				middleware.RequireScope(auth.ScopeModulesWrite),
-				nsAuthz.RequirePublishAccessFromForm(auth.ScopeModulesWrite, 100<<20), // matches the handler's ParseMultipartForm limit
-				modules.UploadHandler(db, storageBackend, cfg, scanRepo, moduleDocsRepo, policyEngine, notifier))
+			// Tiers are a registry-wide badge, so only admins set them
+			authenticatedGroup.WithScope(auth.ScopeAdmin).PATCH("/admin/modules/:id/tier",
+				moduleAdminHandlers.SetModuleTier)
+			// Combined detail for the module page. gin requires the first
+			// segment to reuse the :id wildcard above; the handler reads it as
+			// the namespace.
+			authenticatedGroup.WithScope(auth.ScopeModulesRead).GET("/admin/modules/:id/:name/:system/overview",
+				moduleAdminHandlers.GetModuleOverview)
+			authenticatedGroup.WithScope(auth.ScopeModulesRead).GET("/admin/modules/:id/:name/:system/consumers",
+				d.usageHandlers.ModuleConsumersHandler())
+			uploads := authenticatedGroup.WithRateLimit(routeRateLimitUpload, middleware.RateLimitMiddleware(uploadRateLimiter)) // Stricter rate limit for uploads
+			uploads.WithScope(auth.ScopeModulesWrite).POST("/modules",
+				middleware.TrackOperation(operationsRegistry, operations.TypeModuleUpload),
+				nsAuthz.RequirePublishAccessFromBody(auth.ScopeModulesWrite, 100<<20), // matches the handler's ParseMultipartForm limit
+				modules.UploadHandler(db, storageBackend, cfg, scanRepo, moduleDocsRepo, policyEngine, notifier, d.versionCap, d.artifactImmutability, d.publishHooks, d.scratchSpace))
 
 			// Providers admin endpoints - require write permissions plus
 			// namespace-org authorization (issue #555)
-			authenticatedGroup.POST("/providers",
-				middleware.RateLimitMiddleware(uploadRateLimiter), // Stricter rate limit for uploads
-				middleware.RequireScope(auth.ScopeProvidersWrite),
-				nsAuthz.RequirePublishAccessFromForm(auth.ScopeProvidersWrite, 32<<20), // gin's default multipart memory limit
-				providers.UploadHandler(db, storageBackend, cfg))
-			authenticatedGroup.DELETE("/providers/:namespace/:type",
-				middleware.RequireScope(auth.ScopeProvidersWrite),
+			uploads.WithScope(auth.ScopeProvidersWrite).POST("/providers",
+				middleware.TrackOperation(operationsRegistry, operations.TypeProviderUpload),
+				nsAuthz.RequirePublishAccessFromBody(auth.ScopeProvidersWrite, 32<<20), // gin's default multipart memory limit
+				providers.UploadHandler(db, storageBackend, cfg, d.artifactImmutability, d.scratchSpace))
+			// Any authenticated user may accept a gated provider's license
+			authenticatedGroup.POST("/providers/:namespace/:type/accept-license",
+				providers.AcceptLicenseHandler(db))
+			authenticatedGroup.WithScope(auth.ScopeProvidersWrite).DELETE("/providers/:namespace/:type",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeProvidersWrite),
 				providerAdminHandlers.DeleteProvider)
-			authenticatedGroup.DELETE("/providers/:namespace/:type/versions/:version",
-				middleware.RequireScope(auth.ScopeProvidersWrite),
+			authenticatedGroup.WithScope(auth.ScopeProvidersWrite).DELETE("/providers/:namespace/:type/versions/:version",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeProvidersWrite),
 				providerAdminHandlers.DeleteVersion)
-			authenticatedGroup.POST("/providers/:namespace/:type/versions/:version/deprecate",
-				middleware.RequireScope(auth.ScopeProvidersWrite),
+			authenticatedGroup.WithScope(auth.ScopeProvidersWrite).POST("/providers/:namespace/:type/versions/:version/deprecate",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeProvidersWrite),
 				providerAdminHandlers.DeprecateVersion)
-			authenticatedGroup.DELETE("/providers/:namespace/:type/versions/:version/deprecate",
-				middleware.RequireScope(auth.ScopeProvidersWrite),
+			authenticatedGroup.WithScope(auth.ScopeProvidersWrite).DELETE("/providers/:namespace/:type/versions/:version/deprecate",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeProvidersWrite),
 				providerAdminHandlers.UndeprecateVersion)
 
 			// Provider record admin endpoints (create + get by UUID)
-			authenticatedGroup.POST("/admin/providers",
-				middleware.RequireScope(auth.ScopeProvidersWrite),
+			authenticatedGroup.WithScope(auth.ScopeProvidersWrite).POST("/admin/providers",
 				nsAuthz.RequirePublishAccessFromJSON(auth.ScopeProvidersWrite),
 				providerAdminHandlers.CreateProviderRecord)
-			authenticatedGroup.GET("/admin/providers/:id",
-				middleware.RequireScope(auth.ScopeProvidersRead),
+			authenticatedGroup.WithScope(auth.ScopeProvidersRead).GET("/admin/providers/:id",
 				providerAdminHandlers.GetProviderByID)
-			authenticatedGroup.PUT("/admin/providers/:id",
-				middleware.RequireScope(auth.ScopeProvidersWrite),
+			authenticatedGroup.WithScope(auth.ScopeProvidersRead).GET("/admin/providers/:id/versions",
+				providerAdminHandlers.ListProviderVersions)
+			authenticatedGroup.WithScope(auth.ScopeProvidersWrite).PUT("/admin/providers/:id",
 				nsAuthz.RequireProviderAccessByID(auth.ScopeProvidersWrite),
 				providerAdminHandlers.UpdateProviderRecord)
+			authenticatedGroup.WithScope(auth.ScopeAdmin).PATCH("/admin/providers/:id/tier",
+				providerAdminHandlers.SetProviderTier)
+			// License gating; acceptance records are audit data
+			authenticatedGroup.WithScope(auth.ScopeProvidersRead).GET("/admin/providers/:id/license",
+				providerAdminHandlers.GetProviderLicense)
+			authenticatedGroup.WithScope(auth.ScopeProvidersWrite).PUT("/admin/providers/:id/license",
+				nsAuthz.RequireProviderAccessByID(auth.ScopeProvidersWrite),
+				providerAdminHandlers.SetProviderLicense)
+			authenticatedGroup.WithScope(auth.ScopeProvidersWrite).DELETE("/admin/providers/:id/license",
+				nsAuthz.RequireProviderAccessByID(auth.ScopeProvidersWrite),
+				providerAdminHandlers.DeleteProviderLicense)
+			authenticatedGroup.WithScope(auth.ScopeAuditRead).GET("/admin/providers/:id/license/acceptances",
+				providerAdminHandlers.ListProviderLicenseAcceptances)
+			authenticatedGroup.WithScope(auth.ScopeAuditRead).GET("/admin/providers/:id/license/acceptances/export",
+				providerAdminHandlers.ExportProviderLicenseAcceptances)
+			// Download stats by namespace/type. gin requires the first segment to
+			// reuse the :id wildcard above; the handler reads it as the namespace.
+			authenticatedGroup.WithScope(auth.ScopeProvidersRead).GET("/admin/providers/:id/:type/stats",
+				providerAdminHandlers.GetProviderStats)
+			authenticatedGroup.WithScope(auth.ScopeProvidersRead).GET("/admin/providers/:id/:type/overview",
+				providerAdminHandlers.GetProviderOverview)
+			authenticatedGroup.WithScope(auth.ScopeProvidersRead).GET("/admin/providers/:id/:type/consumers",
+				d.usageHandlers.ProviderConsumersHandler())
+
+			// Usage reports from consumers (CI jobs reporting what they use).
+			// The handler only accepts API keys.
+			authenticatedGroup.WithAnyScope(auth.ScopeModulesRead, auth.ScopeProvidersRead).POST("/usage/report",
+				d.usageHandlers.ReportUsageHandler())
 
 			// Modules admin endpoints - delete, deprecate (GET moved to publicDetailGroup above)
-			authenticatedGroup.DELETE("/modules/:namespace/:name/:system",
-				middleware.RequireScope(auth.ScopeModulesWrite),
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).DELETE("/modules/:namespace/:name/:system",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
 				moduleAdminHandlers.DeleteModule)
-			authenticatedGroup.DELETE("/modules/:namespace/:name/:system/versions/:version",
-				middleware.RequireScope(auth.ScopeModulesWrite),
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).DELETE("/modules/:namespace/:name/:system/versions/:version",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
 				moduleAdminHandlers.DeleteVersion)
-			authenticatedGroup.POST("/modules/:namespace/:name/:system/versions/:version/deprecate",
-				middleware.RequireScope(auth.ScopeModulesWrite),
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).POST("/modules/:namespace/:name/:system/versions/:version/deprecate",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
 				moduleAdminHandlers.DeprecateVersion)
-			authenticatedGroup.DELETE("/modules/:namespace/:name/:system/versions/:version/deprecate",
-				middleware.RequireScope(auth.ScopeModulesWrite),
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).DELETE("/modules/:namespace/:name/:system/versions/:version/deprecate",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
 				moduleAdminHandlers.UndeprecateVersion)
-			authenticatedGroup.POST("/modules/:namespace/:name/:system/versions/:version/reanalyze",
-				middleware.RequireScope(auth.ScopeModulesWrite),
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).POST("/modules/:namespace/:name/:system/versions/:version/reanalyze",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
 				moduleAdminHandlers.ReanalyzeVersion)
 
 			// Module-level deprecation
-			authenticatedGroup.POST("/modules/:namespace/:name/:system/deprecate",
-				middleware.RequireScope(auth.ScopeModulesWrite),
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).POST("/modules/:namespace/:name/:system/deprecate",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
 				moduleAdminHandlers.DeprecateModule)
-			authenticatedGroup.DELETE("/modules/:namespace/:name/:system/deprecate",
-				middleware.RequireScope(auth.ScopeModulesWrite),
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).DELETE("/modules/:namespace/:name/:system/deprecate",
 				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
 				moduleAdminHandlers.UndeprecateModule)
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).POST("/modules/:namespace/:name/:system/change-system",
+				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
+				moduleAdminHandlers.ChangeModuleSystem)
 
-			authenticatedGroup.GET("/modules/:namespace/:name/:system/versions/:version/scan",
-				middleware.RequireScope(auth.ScopeScanningRead),
+			authenticatedGroup.WithScope(auth.ScopeScanningRead).GET("/modules/:namespace/:name/:system/versions/:version/scan",
 				admin.GetModuleScanHandler(db))
 
 			// Security scanning admin endpoints
-			authenticatedGroup.GET("/admin/scanning/config",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/scanning/config",
 				admin.GetScanningConfigHandler(&cfg.Scanning))
-			authenticatedGroup.GET("/admin/scanning/stats",
-				middleware.RequireScope(auth.ScopeScanningRead),
+			authenticatedGroup.WithScope(auth.ScopeScanningRead).GET("/admin/scanning/stats",
 				admin.GetScanningStatsHandler(sqlxDB))
-			authenticatedGroup.GET("/admin/scanning/scans/:id",
-				middleware.RequireScope(auth.ScopeScanningRead),
+			authenticatedGroup.WithScope(auth.ScopeScanningRead).GET("/admin/scanning/scans/:id",
 				admin.GetScanByIDHandler(db))
 			installHandler := admin.NewScanningInstallHandler(&cfg.Scanning, nil, scannerUpdateJob, sbvRepo, scannerApprovalRepo)
 			installHandler.SetEgressGuard(egressGuard)
-			authenticatedGroup.POST("/admin/scanning/install",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).POST("/admin/scanning/install",
 				installHandler.Install())
-			authenticatedGroup.POST("/admin/scanning/check",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).POST("/admin/scanning/check",
 				admin.TriggerScannerCheckHandler(scannerUpdateJob))
-			authenticatedGroup.GET("/admin/scanning/latest",
-				middleware.RequireScope(auth.ScopeScanningRead),
+			authenticatedGroup.WithScope(auth.ScopeScanningRead).GET("/admin/scanning/latest",
 				admin.GetScannerLatestHandler(&cfg.Scanning, egressGuard))
 			scanningAutoUpdateHandler := admin.NewScanningAutoUpdateHandler(&cfg.Scanning, oidcConfigRepo, scannerUpdateJob)
-			authenticatedGroup.PUT("/admin/scanning/auto-update",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).PUT("/admin/scanning/auto-update",
 				scanningAutoUpdateHandler.Put)
 
 			// Notifications (SMTP) admin endpoints
-			authenticatedGroup.GET("/admin/notifications/config",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/notifications/config",
 				notificationsHandler.GetConfig)
-			authenticatedGroup.PUT("/admin/notifications/config",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).PUT("/admin/notifications/config",
 				notificationsHandler.PutConfig)
-			authenticatedGroup.POST("/admin/notifications/test",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).POST("/admin/notifications/test",
 				notificationsHandler.TestEmail)
 
 			// Notification channels: additional delivery destinations (webhook,
 			// Slack, Microsoft Teams, or an ad-hoc email recipient list) for the
 			// module_published, approval_pending, cve_detected, and
 			// scanner_update_available events.
-			authenticatedGroup.GET("/admin/notifications/channels",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/notifications/channels",
 				notificationChannelHandlers.ListChannels)
-			authenticatedGroup.POST("/admin/notifications/channels",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).POST("/admin/notifications/channels",
 				notificationChannelHandlers.CreateChannel)
-			authenticatedGroup.PUT("/admin/notifications/channels/:id",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).PUT("/admin/notifications/channels/:id",
 				notificationChannelHandlers.UpdateChannel)
-			authenticatedGroup.DELETE("/admin/notifications/channels/:id",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).DELETE("/admin/notifications/channels/:id",
 				notificationChannelHandlers.DeleteChannel)
-			authenticatedGroup.POST("/admin/notifications/channels/:id/test",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).POST("/admin/notifications/channels/:id/test",
 				notificationChannelHandlers.TestChannel)
 
 			// API Keys management - self-service for own keys
@@ -683,6 +838,7 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			{
 				apiKeysGroup.GET("", apiKeyHandlers.ListAPIKeysHandler())
 				apiKeysGroup.POST("", apiKeyHandlers.CreateAPIKeyHandler())
+				apiKeysGroup.GET("/claims/:token", apiKeyHandlers.ClaimAPIKeyHandler())
 				apiKeysGroup.GET("/:id", apiKeyHandlers.GetAPIKeyHandler())
 				apiKeysGroup.PUT("/:id", apiKeyHandlers.UpdateAPIKeyHandler())
 				apiKeysGroup.DELETE("/:id", apiKeyHandlers.DeleteAPIKeyHandler())
@@ -692,10 +848,14 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			// Self-service user endpoints (any authenticated user)
 			// These endpoints allow users to access their own data without special scopes
 			authenticatedGroup.GET("/users/me/memberships", userHandlers.GetCurrentUserMembershipsHandler())
+			// Terraform CLI tokens (read-only, accepted only on the protocol routes)
+			authenticatedGroup.GET("/users/me/cli-tokens", cliTokenHandlers.ListTokens)
+			authenticatedGroup.POST("/users/me/cli-tokens", cliTokenHandlers.CreateToken)
+			authenticatedGroup.DELETE("/users/me/cli-tokens/:id", cliTokenHandlers.RevokeToken)
 
 			// Users management (requires users:read scope for viewing others)
 			usersGroup := authenticatedGroup.Group("/users")
-			usersGroup.Use(middleware.RequireScope(auth.ScopeUsersRead))
+			usersGroup.UseScope(auth.ScopeUsersRead)
 			{
 				usersGroup.GET("", userHandlers.ListUsersHandler())
 				usersGroup.GET("/search", userHandlers.SearchUsersHandler())
@@ -704,7 +864,7 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			}
 
 			usersWriteGroup := authenticatedGroup.Group("/users")
-			usersWriteGroup.Use(middleware.RequireScope(auth.ScopeUsersWrite))
+			usersWriteGroup.UseScope(auth.ScopeUsersWrite)
 			{
 				usersWriteGroup.POST("", userHandlers.CreateUserHandler())
 				usersWriteGroup.PUT("/:id", userHandlers.UpdateUserHandler())
@@ -715,25 +875,28 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			// these reveal or destroy PII for any user, so the gate is stricter than
 			// users:write.
 			adminUsersGroup := authenticatedGroup.Group("/admin/users")
-			adminUsersGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
+			adminUsersGroup.UseScope(auth.ScopeAdmin)
 			{
-				adminUsersGroup.GET("/:id/export", gdprHandlers.ExportUserDataHandler())
+				adminUsersGroup.GET("/:id/export",
+					middleware.TrackOperation(operationsRegistry, operations.TypeUserDataExport),
+					gdprHandlers.ExportUserDataHandler())
 				adminUsersGroup.POST("/:id/erase", gdprHandlers.EraseUserHandler())
+				adminUsersGroup.GET("/:id/link-identity", identityLinkHandlers.GetStatus)
+				adminUsersGroup.POST("/:id/link-identity", identityLinkHandlers.ApproveLink)
+				adminUsersGroup.DELETE("/:id/link-identity", identityLinkHandlers.Unlink)
 			}
 
 			// White-label theme writes for admins (post-setup edits).
 			// Setup-wizard writes use PUT /api/v1/setup/ui-theme above.
 			adminUIThemeHandlers := uitheme.NewHandlers(sqlxDB)
-			authenticatedGroup.PUT("/admin/ui-theme",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).PUT("/admin/ui-theme",
 				adminUIThemeHandlers.PutTheme())
 
 			// Per-org quota status — feeds the frontend QuotaUsageChart dashboard.
 			// READ-ONLY in this PR; enforcement middleware (429 / X-Quota-Reset)
 			// and admin writes for setting per-org limits are tracked separately.
 			quotaHandlers := admin.NewQuotaHandlers(sqlxDB)
-			authenticatedGroup.GET("/admin/quotas",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/quotas",
 				quotaHandlers.ListQuotas())
 
 			// Organizations management.
@@ -751,14 +914,12 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			orgsGroup := authenticatedGroup.Group("/organizations")
 			{
 				// Read operations require organizations:read
-				orgsGroup.GET("", middleware.RequireScope(auth.ScopeOrganizationsRead), orgHandlers.ListOrganizationsHandler())
-				orgsGroup.GET("/search", middleware.RequireScope(auth.ScopeOrganizationsRead), orgHandlers.SearchOrganizationsHandler())
-				orgsGroup.GET("/:id",
-					middleware.RequireScope(auth.ScopeOrganizationsRead),
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("", orgHandlers.ListOrganizationsHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/search", orgHandlers.SearchOrganizationsHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id",
 					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
 					orgHandlers.GetOrganizationHandler())
-				orgsGroup.GET("/:id/members",
-					middleware.RequireScope(auth.ScopeOrganizationsRead),
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/members",
 					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
 					orgHandlers.ListMembersHandler())
 
@@ -767,72 +928,205 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 				// scope instead of organizations:write (issue #648): holding
 				// organizations:write via membership in one org must not by
 				// itself grant the ability to provision brand new orgs.
-				orgsGroup.POST("", middleware.RequireScope(auth.ScopeOrganizationsCreate), orgHandlers.CreateOrganizationHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsCreate).POST("", orgHandlers.CreateOrganizationHandler())
 
 				// Update/delete require organizations:write
-				orgsGroup.PUT("/:id",
-					middleware.RequireScope(auth.ScopeOrganizationsWrite),
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).PUT("/:id",
 					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
 					orgHandlers.UpdateOrganizationHandler())
-				orgsGroup.DELETE("/:id",
-					middleware.RequireScope(auth.ScopeOrganizationsWrite),
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).DELETE("/:id",
 					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
 					orgHandlers.DeleteOrganizationHandler())
 
 				// Member management requires organizations:write
-				orgsGroup.POST("/:id/members",
-					middleware.RequireScope(auth.ScopeOrganizationsWrite),
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).POST("/:id/members",
 					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
 					orgHandlers.AddMemberHandler())
-				orgsGroup.PUT("/:id/members/:user_id",
-					middleware.RequireScope(auth.ScopeOrganizationsWrite),
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).PUT("/:id/members/:user_id",
 					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
 					orgHandlers.UpdateMemberHandler())
-				orgsGroup.DELETE("/:id/members/:user_id",
-					middleware.RequireScope(auth.ScopeOrganizationsWrite),
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).DELETE("/:id/members/:user_id",
 					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
 					orgHandlers.RemoveMemberHandler())
+
+				// Per-organization API key policy. Reading the policy and its
+				// compliance report needs organizations:read; changing it
+				// needs organizations:write in that organization.
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/api-key-policy",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					apiKeyPolicyHandlers.GetPolicyHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).PUT("/:id/api-key-policy",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					apiKeyPolicyHandlers.UpdatePolicyHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).DELETE("/:id/api-key-policy",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					apiKeyPolicyHandlers.DeletePolicyHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/api-key-policy/compliance",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					apiKeyPolicyHandlers.ComplianceHandler())
+
+				// Per-organization scope ceiling for issued session tokens.
+				// Reading it needs organizations:read; changing it needs
+				// organizations:write in that organization.
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/scope-ceiling",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					d.scopeCeilingHandlers.GetCeilingHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).PUT("/:id/scope-ceiling",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.scopeCeilingHandlers.UpdateCeilingHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).DELETE("/:id/scope-ceiling",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.scopeCeilingHandlers.DeleteCeilingHandler())
+
+				// Per-organization artifact immutability. Reading needs
+				// organizations:read; enabling or strengthening it needs
+				// organizations:write in that organization.
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/artifact-immutability",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					d.artifactImmutabilityHandlers.GetSettingHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).PUT("/:id/artifact-immutability",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.artifactImmutabilityHandlers.UpdateSettingHandler())
+
+				// Per-organization pre-publish hook. Reading the hook and its
+				// delivery log needs organizations:read; configuring or
+				// testing it needs organizations:write in that organization.
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/publish-hook",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					d.publishHookHandlers.GetHookHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).PUT("/:id/publish-hook",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.publishHookHandlers.UpdateHookHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).DELETE("/:id/publish-hook",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.publishHookHandlers.DeleteHookHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).POST("/:id/publish-hook/test",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.publishHookHandlers.TestHookHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/publish-hook/deliveries",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					d.publishHookHandlers.ListDeliveriesHandler())
+
+				// Per-organization event webhooks for security events.
+				// Reading webhooks and their delivery log needs
+				// organizations:read; configuring, testing or replaying
+				// needs organizations:write in that organization.
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/event-webhooks",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					d.orgEventWebhookHandlers.ListWebhooksHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).POST("/:id/event-webhooks",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.orgEventWebhookHandlers.CreateWebhookHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/event-webhooks/:webhook_id",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					d.orgEventWebhookHandlers.GetWebhookHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).PUT("/:id/event-webhooks/:webhook_id",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.orgEventWebhookHandlers.UpdateWebhookHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).DELETE("/:id/event-webhooks/:webhook_id",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.orgEventWebhookHandlers.DeleteWebhookHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).POST("/:id/event-webhooks/:webhook_id/test",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.orgEventWebhookHandlers.TestWebhookHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).POST("/:id/event-webhooks/:webhook_id/rotate-secret",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.orgEventWebhookHandlers.StartSecretRotationHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).POST("/:id/event-webhooks/:webhook_id/rotate-secret/complete",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.orgEventWebhookHandlers.CompleteSecretRotationHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/event-webhooks/:webhook_id/deliveries",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					d.orgEventWebhookHandlers.ListDeliveriesHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).POST("/:id/event-webhooks/:webhook_id/deliveries/:delivery_id/replay",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					d.orgEventWebhookHandlers.ReplayDeliveryHandler())
+
+				// Verified domain for namespace claim auto-approval. Reading
+				// needs organizations:read in that organization; setting it is
+				// an admin attestation.
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/verified-domain",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					d.namespaceClaimHandlers.GetVerifiedDomainHandler())
+				orgsGroup.WithScope(auth.ScopeAdmin).PUT("/:id/verified-domain",
+					d.namespaceClaimHandlers.SetVerifiedDomainHandler())
+				orgsGroup.WithScope(auth.ScopeAdmin).DELETE("/:id/verified-domain",
+					d.namespaceClaimHandlers.DeleteVerifiedDomainHandler())
+
+				// Per-organization module version approvals. Listing needs
+				// organizations:read; approving, revoking, and changing the
+				// approved_only policy need organizations:write.
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/module-policy",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					moduleApprovalHandlers.GetPolicyHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).PUT("/:id/module-policy",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					moduleApprovalHandlers.UpdatePolicyHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsRead).GET("/:id/module-approvals",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsRead, orgRepo),
+					moduleApprovalHandlers.ListApprovalsHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).PUT("/:id/module-approvals/:namespace/:name/:system/:version",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					moduleApprovalHandlers.ApproveVersionHandler())
+				orgsGroup.WithScope(auth.ScopeOrganizationsWrite).DELETE("/:id/module-approvals/:namespace/:name/:system/:version",
+					middleware.RequireOrgScopeForPathOrg(auth.ScopeOrganizationsWrite, orgRepo),
+					moduleApprovalHandlers.RevokeVersionHandler())
 			}
 
 			// Namespace ownership (read-only): audit which organization owns each
 			// module/provider namespace. Ownership itself is enforced by
 			// namespace_claims on every mutation (issue #555); these endpoints only
 			// expose it for verification.
-			authenticatedGroup.GET("/admin/namespaces",
-				middleware.RequireScope(auth.ScopeOrganizationsRead),
+			authenticatedGroup.WithScope(auth.ScopeOrganizationsRead).GET("/admin/namespaces",
 				orgHandlers.ListNamespaceClaimsHandler())
-			authenticatedGroup.GET("/admin/namespaces/:namespace",
-				middleware.RequireScope(auth.ScopeOrganizationsRead),
+			authenticatedGroup.WithScope(auth.ScopeOrganizationsRead).GET("/admin/namespaces/:namespace",
 				orgHandlers.GetNamespaceOwnershipHandler())
 
+			// Self-service namespace claims. Any authenticated member may
+			// request a namespace for their organization (membership is
+			// checked in the handler); admins review the requests.
+			authenticatedGroup.POST("/namespaces/:namespace/claim",
+				d.namespaceClaimHandlers.SubmitClaimHandler())
+			// Namespace landing page: the owning organization edits its
+			// description, links and avatar.
+			authenticatedGroup.WithScope(auth.ScopeModulesWrite).PUT("/namespaces/:namespace/metadata",
+				nsAuthz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
+				d.namespaceMetadataHandlers.UpdateMetadataHandler())
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/namespace-claims",
+				d.namespaceClaimHandlers.ListClaimRequestsHandler())
+			authenticatedGroup.WithScope(auth.ScopeAdmin).POST("/admin/namespace-claims/:id/approve",
+				d.namespaceClaimHandlers.ApproveClaimRequestHandler())
+			authenticatedGroup.WithScope(auth.ScopeAdmin).POST("/admin/namespace-claims/:id/reject",
+				d.namespaceClaimHandlers.RejectClaimRequestHandler())
+
 			// SCM Provider management
 			scmProvidersGroup := authenticatedGroup.Group("/scm-providers")
 			{
 				// Read operations require scm:read
-				scmProvidersGroup.GET("", middleware.RequireScope(auth.ScopeSCMRead), scmProviderHandlers.ListProviders)
-				scmProvidersGroup.GET("/:id", middleware.RequireScope(auth.ScopeSCMRead), scmProviderHandlers.GetProvider)
+				scmProvidersGroup.WithScope(auth.ScopeSCMRead).GET("", scmProviderHandlers.ListProviders)
+				scmProvidersGroup.WithScope(auth.ScopeSCMRead).GET("/:id", scmProviderHandlers.GetProvider)
 
 				// Management operations require scm:manage
-				scmProvidersGroup.POST("", middleware.RequireScope(auth.ScopeSCMManage), scmProviderHandlers.CreateProvider)
-				scmProvidersGroup.PUT("/:id", middleware.RequireScope(auth.ScopeSCMManage), scmProviderHandlers.UpdateProvider)
-				scmProvidersGroup.DELETE("/:id", middleware.RequireScope(auth.ScopeSCMManage), scmProviderHandlers.DeleteProvider)
+				scmProvidersGroup.WithScope(auth.ScopeSCMManage).POST("", scmProviderHandlers.CreateProvider)
+				scmProvidersGroup.WithScope(auth.ScopeSCMManage).PUT("/:id", scmProviderHandlers.UpdateProvider)
+				scmProvidersGroup.WithScope(auth.ScopeSCMManage).DELETE("/:id", scmProviderHandlers.DeleteProvider)
 
 				// Verify shared app credentials by minting a token (app auth modes only)
-				scmProvidersGroup.POST("/:id/verify", middleware.RequireScope(auth.ScopeSCMManage), scmProviderHandlers.VerifyProvider)
+				scmProvidersGroup.WithScope(auth.ScopeSCMManage).POST("/:id/verify", scmProviderHandlers.VerifyProvider)
 
 				// OAuth flow endpoints require scm:manage
-				scmProvidersGroup.GET("/:id/oauth/authorize", middleware.RequireScope(auth.ScopeSCMManage), scmOAuthHandlers.InitiateOAuth)
-				scmProvidersGroup.GET("/:id/oauth/token", middleware.RequireScope(auth.ScopeSCMRead), scmOAuthHandlers.GetTokenStatus)
-				scmProvidersGroup.DELETE("/:id/oauth/token", middleware.RequireScope(auth.ScopeSCMManage), scmOAuthHandlers.RevokeOAuth)
-				scmProvidersGroup.POST("/:id/oauth/refresh", middleware.RequireScope(auth.ScopeSCMManage), scmOAuthHandlers.RefreshToken)
+				scmProvidersGroup.WithScope(auth.ScopeSCMManage).GET("/:id/oauth/authorize", scmOAuthHandlers.InitiateOAuth)
+				scmProvidersGroup.WithScope(auth.ScopeSCMRead).GET("/:id/oauth/token", scmOAuthHandlers.GetTokenStatus)
+				scmProvidersGroup.WithScope(auth.ScopeSCMManage).DELETE("/:id/oauth/token", scmOAuthHandlers.RevokeOAuth)
+				scmProvidersGroup.WithScope(auth.ScopeSCMManage).POST("/:id/oauth/refresh", scmOAuthHandlers.RefreshToken)
 
 				// PAT-based auth (e.g., Bitbucket Data Center)
-				scmProvidersGroup.POST("/:id/token", middleware.RequireScope(auth.ScopeSCMManage), scmOAuthHandlers.SavePATToken)
+				scmProvidersGroup.WithScope(auth.ScopeSCMManage).POST("/:id/token", scmOAuthHandlers.SavePATToken)
 
 				// Repository listing - requires scm:read
-				scmProvidersGroup.GET("/:id/repositories", middleware.RequireScope(auth.ScopeSCMRead), scmOAuthHandlers.ListRepositories)
-				scmProvidersGroup.GET("/:id/repositories/:owner/:repo/tags", middleware.RequireScope(auth.ScopeSCMRead), scmOAuthHandlers.ListRepositoryTags)
-				scmProvidersGroup.GET("/:id/repositories/:owner/:repo/branches", middleware.RequireScope(auth.ScopeSCMRead), scmOAuthHandlers.ListRepositoryBranches)
+				scmProvidersGroup.WithScope(auth.ScopeSCMRead).GET("/:id/repositories", scmOAuthHandlers.ListRepositories)
+				scmProvidersGroup.WithScope(auth.ScopeSCMRead).GET("/:id/repositories/:owner/:repo/tags", scmOAuthHandlers.ListRepositoryTags)
+				scmProvidersGroup.WithScope(auth.ScopeSCMRead).GET("/:id/repositories/:owner/:repo/branches", scmOAuthHandlers.ListRepositoryBranches)
 			}
 
 			// SCM OAuth callback (public endpoint, no auth required)
@@ -841,7 +1135,7 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			// Module SCM linking endpoints. Mutations additionally require
 			// namespace-org authorization for the target module (issue #555).
 			moduleSCMGroup := authenticatedGroup.Group("/admin/modules/:id/scm")
-			moduleSCMGroup.Use(middleware.RequireScope(auth.ScopeModulesWrite))
+			moduleSCMGroup.UseScope(auth.ScopeModulesWrite)
 			{
 				moduleSCMGroup.POST("", nsAuthz.RequireModuleAccessByID(auth.ScopeModulesWrite), scmLinkingHandler.LinkModuleToSCM)
 				moduleSCMGroup.GET("", scmLinkingHandler.GetModuleSCMInfo)
@@ -849,6 +1143,12 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 				moduleSCMGroup.DELETE("", nsAuthz.RequireModuleAccessByID(auth.ScopeModulesWrite), scmLinkingHandler.UnlinkModuleFromSCM)
 				moduleSCMGroup.POST("/sync", nsAuthz.RequireModuleAccessByID(auth.ScopeModulesWrite), scmLinkingHandler.TriggerManualSync)
 				moduleSCMGroup.GET("/events", scmLinkingHandler.GetWebhookEvents)
+				moduleSCMGroup.GET("/publishes/:publish_id", scmLinkingHandler.GetPublishTask)
+				moduleSCMGroup.POST("/backfill", nsAuthz.RequireModuleAccessByID(auth.ScopeModulesWrite), scmLinkingHandler.BackfillTags)
+				moduleSCMGroup.GET("/backfills/:backfill_id", scmLinkingHandler.GetBackfill)
+				moduleSCMGroup.GET("/health", scmLinkingHandler.GetSCMLinkHealth)
+				moduleSCMGroup.POST("/transfer-ownership", nsAuthz.RequireModuleAccessByID(auth.ScopeModulesWrite), scmLinkingHandler.TransferSCMOwnership)
+				moduleSCMGroup.POST("/rotate-webhook-secret", nsAuthz.RequireModuleAccessByID(auth.ScopeModulesWrite), scmLinkingHandler.RotateWebhookSecret)
 			}
 
 			// Mirror management endpoints with granular RBAC
@@ -857,92 +1157,188 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			mirrorsGroup := authenticatedGroup.Group("/admin/mirrors")
 			{
 				// Read operations - require mirrors:read (or mirrors:manage or admin)
-				mirrorsGroup.GET("", middleware.RequireScope(auth.ScopeMirrorsRead), mirrorHandlers.ListMirrorConfigs)
-				mirrorsGroup.GET("/:id", middleware.RequireScope(auth.ScopeMirrorsRead), mirrorHandlers.GetMirrorConfig)
-				mirrorsGroup.GET("/:id/status", middleware.RequireScope(auth.ScopeMirrorsRead), mirrorHandlers.GetMirrorStatus)
-				mirrorsGroup.GET("/:id/providers", middleware.RequireScope(auth.ScopeMirrorsRead), mirrorHandlers.ListMirroredProviders)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsRead).GET("", mirrorHandlers.ListMirrorConfigs)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsRead).GET("/:id", mirrorHandlers.GetMirrorConfig)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsRead).GET("/:id/status", mirrorHandlers.GetMirrorStatus)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsRead).GET("/:id/history", mirrorHandlers.GetMirrorSyncHistory)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsRead).GET("/:id/providers", mirrorHandlers.ListMirroredProviders)
 
 				// Management operations - require mirrors:manage (or admin)
-				mirrorsGroup.POST("", middleware.RequireScope(auth.ScopeMirrorsManage), mirrorHandlers.CreateMirrorConfig)
-				mirrorsGroup.PUT("/:id", middleware.RequireScope(auth.ScopeMirrorsManage), mirrorHandlers.UpdateMirrorConfig)
-				mirrorsGroup.DELETE("/:id", middleware.RequireScope(auth.ScopeMirrorsManage), mirrorHandlers.DeleteMirrorConfig)
-				mirrorsGroup.POST("/:id/sync", middleware.RequireScope(auth.ScopeMirrorsManage), mirrorHandlers.TriggerSync)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsManage).POST("", mirrorHandlers.CreateMirrorConfig)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsManage).PUT("/:id", mirrorHandlers.UpdateMirrorConfig)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsManage).DELETE("/:id", mirrorHandlers.DeleteMirrorConfig)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsManage).POST("/:id/sync", mirrorHandlers.TriggerSync)
+				mirrorsGroup.WithScope(auth.ScopeMirrorsManage).DELETE("/:id/lock", mirrorHandlers.ClearSyncLock)
+				// Re-signing vouches for artifacts with the registry's key - admin only
+				mirrorsGroup.WithScope(auth.ScopeAdmin).POST("/:id/resign", mirrorHandlers.ResignMirror)
+
+				// Hostname aliases (e.g. serving a registry.terraform.io mirror to
+				// OpenTofu clients) - admin only, since they change what the
+				// network mirror serves under another registry's name
+				mirrorsGroup.WithScope(auth.ScopeMirrorsRead).GET("/:id/hostname-aliases", mirrorHostnameAliasHandlers.ListAliases)
+				mirrorsGroup.WithScope(auth.ScopeAdmin).POST("/:id/hostname-aliases", mirrorHostnameAliasHandlers.CreateAlias)
+				mirrorsGroup.WithScope(auth.ScopeAdmin).DELETE("/:id/hostname-aliases/:aliasId", mirrorHostnameAliasHandlers.DeleteAlias)
+				mirrorsGroup.WithScope(auth.ScopeAdmin).PUT("/:id/providers/:providerId/hostname-aliases", mirrorHostnameAliasHandlers.SetProviderOptIn)
 			}
 
 			// Terraform Binary Mirror admin endpoints (multi-config)
-			// Read operations require mirrors:read scope; management requires mirrors:manage
+			// Read operations require binaries:read scope; management requires
+			// binaries:manage. mirrors:read / mirrors:manage, which covered these
+			// endpoints before the binaries scopes existed, are still accepted.
 			tfMirrorGroup := authenticatedGroup.Group("/admin/terraform-mirrors")
+			binariesRead := tfMirrorGroup.WithAnyScope(auth.AcceptedScopes(auth.ScopeBinariesRead)...)
+			binariesManage := tfMirrorGroup.WithAnyScope(auth.AcceptedScopes(auth.ScopeBinariesManage)...)
 			{
 				// Release-signing GPG key cache + expiry state (read-only).
 				// Registered before /:id routes so the static path takes priority.
-				tfMirrorGroup.GET("/releases-gpg-keys", middleware.RequireScope(auth.ScopeMirrorsRead), releasesGPGKeysAdminHandler.GetReleasesGPGKeys)
+				binariesRead.GET("/releases-gpg-keys", releasesGPGKeysAdminHandler.GetReleasesGPGKeys)
+				// End-of-life rules, shared by every mirror config.
+				binariesRead.GET("/eol-rules", tfMirrorAdminHandler.ListEOLRules)
+				binariesManage.POST("/eol-rules", tfMirrorAdminHandler.CreateEOLRule)
+				binariesManage.PUT("/eol-rules/:ruleId", tfMirrorAdminHandler.UpdateEOLRule)
+				binariesManage.DELETE("/eol-rules/:ruleId", tfMirrorAdminHandler.DeleteEOLRule)
 				// Config CRUD
-				tfMirrorGroup.GET("", middleware.RequireScope(auth.ScopeMirrorsRead), tfMirrorAdminHandler.ListConfigs)
-				tfMirrorGroup.POST("", middleware.RequireScope(auth.ScopeMirrorsManage), tfMirrorAdminHandler.CreateConfig)
-				tfMirrorGroup.GET("/:id", middleware.RequireScope(auth.ScopeMirrorsRead), tfMirrorAdminHandler.GetConfig)
-				tfMirrorGroup.GET("/:id/status", middleware.RequireScope(auth.ScopeMirrorsRead), tfMirrorAdminHandler.GetStatus)
-				tfMirrorGroup.PUT("/:id", middleware.RequireScope(auth.ScopeMirrorsManage), tfMirrorAdminHandler.UpdateConfig)
-				tfMirrorGroup.DELETE("/:id", middleware.RequireScope(auth.ScopeMirrorsManage), tfMirrorAdminHandler.DeleteConfig)
+				binariesRead.GET("", tfMirrorAdminHandler.ListConfigs)
+				binariesManage.POST("", tfMirrorAdminHandler.CreateConfig)
+				binariesRead.GET("/:id", tfMirrorAdminHandler.GetConfig)
+				binariesRead.GET("/:id/status", tfMirrorAdminHandler.GetStatus)
+				binariesManage.PUT("/:id", tfMirrorAdminHandler.UpdateConfig)
+				binariesManage.DELETE("/:id", tfMirrorAdminHandler.DeleteConfig)
 				// Sync trigger
-				tfMirrorGroup.POST("/:id/sync", middleware.RequireScope(auth.ScopeMirrorsManage), tfMirrorAdminHandler.TriggerSync)
+				binariesManage.POST("/:id/sync", tfMirrorAdminHandler.TriggerSync)
 				// Versions
-				tfMirrorGroup.GET("/:id/versions", middleware.RequireScope(auth.ScopeMirrorsRead), tfMirrorAdminHandler.ListVersions)
-				tfMirrorGroup.GET("/:id/versions/:version", middleware.RequireScope(auth.ScopeMirrorsRead), tfMirrorAdminHandler.GetVersion)
-				tfMirrorGroup.DELETE("/:id/versions/:version", middleware.RequireScope(auth.ScopeMirrorsManage), tfMirrorAdminHandler.DeleteVersion)
-				tfMirrorGroup.POST("/:id/versions/:version/deprecate", middleware.RequireScope(auth.ScopeMirrorsManage), tfMirrorAdminHandler.DeprecateVersion)
-				tfMirrorGroup.DELETE("/:id/versions/:version/deprecate", middleware.RequireScope(auth.ScopeMirrorsManage), tfMirrorAdminHandler.UndeprecateVersion)
-				tfMirrorGroup.GET("/:id/versions/:version/platforms", middleware.RequireScope(auth.ScopeMirrorsRead), tfMirrorAdminHandler.ListPlatforms)
+				binariesRead.GET("/:id/versions", tfMirrorAdminHandler.ListVersions)
+				binariesRead.GET("/:id/versions/:version", tfMirrorAdminHandler.GetVersion)
+				binariesManage.DELETE("/:id/versions/:version", tfMirrorAdminHandler.DeleteVersion)
+				binariesManage.POST("/:id/versions/:version/deprecate", tfMirrorAdminHandler.DeprecateVersion)
+				binariesManage.DELETE("/:id/versions/:version/deprecate", tfMirrorAdminHandler.UndeprecateVersion)
+				binariesRead.GET("/:id/versions/:version/platforms", tfMirrorAdminHandler.ListPlatforms)
 				// Sync history
-				tfMirrorGroup.GET("/:id/history", middleware.RequireScope(auth.ScopeMirrorsRead), tfMirrorAdminHandler.GetSyncHistory)
+				binariesRead.GET("/:id/history", tfMirrorAdminHandler.GetSyncHistory)
 			}
 
 			// Role Templates management
 			roleTemplatesGroup := authenticatedGroup.Group("/admin/role-templates")
 			{
-				roleTemplatesGroup.GET("", middleware.RequireScope(auth.ScopeAdmin), rbacHandlers.ListRoleTemplates)
-				roleTemplatesGroup.GET("/:id", middleware.RequireScope(auth.ScopeAdmin), rbacHandlers.GetRoleTemplate)
-				roleTemplatesGroup.POST("", middleware.RequireScope(auth.ScopeAdmin), rbacHandlers.CreateRoleTemplate)
-				roleTemplatesGroup.PUT("/:id", middleware.RequireScope(auth.ScopeAdmin), rbacHandlers.UpdateRoleTemplate)
-				roleTemplatesGroup.DELETE("/:id", middleware.RequireScope(auth.ScopeAdmin), rbacHandlers.DeleteRoleTemplate)
+				roleTemplatesGroup.WithScope(auth.ScopeAdmin).GET("", rbacHandlers.ListRoleTemplates)
+				roleTemplatesGroup.WithScope(auth.ScopeAdmin).GET("/scopes", rbacHandlers.ListScopes)
+				roleTemplatesGroup.WithScope(auth.ScopeAdmin).GET("/:id", rbacHandlers.GetRoleTemplate)
+				roleTemplatesGroup.WithScope(auth.ScopeAdmin).POST("", rbacHandlers.CreateRoleTemplate)
+				roleTemplatesGroup.WithScope(auth.ScopeAdmin).PUT("/:id", rbacHandlers.UpdateRoleTemplate)
+				roleTemplatesGroup.WithScope(auth.ScopeAdmin).DELETE("/:id", rbacHandlers.DeleteRoleTemplate)
 			}
 
 			// Mirror Approval Requests
 			approvalsGroup := authenticatedGroup.Group("/admin/approvals")
 			{
-				approvalsGroup.GET("", middleware.RequireScope(auth.ScopeMirrorsRead), rbacHandlers.ListApprovalRequests)
-				approvalsGroup.GET("/:id", middleware.RequireScope(auth.ScopeMirrorsRead), rbacHandlers.GetApprovalRequest)
-				approvalsGroup.POST("", middleware.RequireScope(auth.ScopeMirrorsManage), rbacHandlers.CreateApprovalRequest)
-				approvalsGroup.PUT("/:id/review", middleware.RequireScope(auth.ScopeAdmin), rbacHandlers.ReviewApproval)
+				approvalsGroup.WithScope(auth.ScopeMirrorsRead).GET("", rbacHandlers.ListApprovalRequests)
+				approvalsGroup.WithScope(auth.ScopeMirrorsRead).GET("/:id", rbacHandlers.GetApprovalRequest)
+				approvalsGroup.WithScope(auth.ScopeMirrorsManage).POST("", rbacHandlers.CreateApprovalRequest)
+				approvalsGroup.WithScope(auth.ScopeAdmin).PUT("/:id/review", rbacHandlers.ReviewApproval)
+				approvalsGroup.WithScope(auth.ScopeAdmin).PUT("/:id/revoke", rbacHandlers.RevokeApproval)
 				// Generate a single-use token that allows out-of-band (email/Slack) approval.
-				approvalsGroup.POST("/:id/token", middleware.RequireScope(auth.ScopeMirrorsManage), rbacHandlers.GenerateApprovalToken)
+				approvalsGroup.WithScope(auth.ScopeMirrorsManage).POST("/:id/token", rbacHandlers.GenerateApprovalToken)
 			}
 
 			// Version Approvals (provider + terraform mirror version gate)
 			versionApprovalsGroup := authenticatedGroup.Group("/admin/version-approvals")
 			{
-				versionApprovalsGroup.GET("", middleware.RequireScope(auth.ScopeMirrorsRead), versionApprovalHandler.List)
-				versionApprovalsGroup.GET("/pending-count", middleware.RequireScope(auth.ScopeMirrorsRead), versionApprovalHandler.PendingCount)
-				versionApprovalsGroup.GET("/:id/events", middleware.RequireScope(auth.ScopeMirrorsRead), versionApprovalHandler.Events)
-				versionApprovalsGroup.PUT("/:id/approve", middleware.RequireScope(auth.ScopeAdmin), versionApprovalHandler.Approve)
-				versionApprovalsGroup.PUT("/:id/reject", middleware.RequireScope(auth.ScopeAdmin), versionApprovalHandler.Reject)
-				versionApprovalsGroup.POST("/bulk-approve", middleware.RequireScope(auth.ScopeAdmin), versionApprovalHandler.BulkApprove)
-				versionApprovalsGroup.POST("/bulk-reject", middleware.RequireScope(auth.ScopeAdmin), versionApprovalHandler.BulkReject)
+				versionApprovalsGroup.WithScope(auth.ScopeMirrorsRead).GET("", versionApprovalHandler.List)
+				versionApprovalsGroup.WithScope(auth.ScopeMirrorsRead).GET("/pending-count", versionApprovalHandler.PendingCount)
+				versionApprovalsGroup.WithScope(auth.ScopeMirrorsRead).GET("/:id/events", versionApprovalHandler.Events)
+				versionApprovalsGroup.WithScope(auth.ScopeAdmin).PUT("/:id/approve", versionApprovalHandler.Approve)
+				versionApprovalsGroup.WithScope(auth.ScopeAdmin).PUT("/:id/reject", versionApprovalHandler.Reject)
+				versionApprovalsGroup.WithScope(auth.ScopeAdmin).POST("/bulk-approve", versionApprovalHandler.BulkApprove)
+				versionApprovalsGroup.WithScope(auth.ScopeAdmin).POST("/bulk-reject", versionApprovalHandler.BulkReject)
 			}
 
 			// Mirror Policies
 			policiesGroup := authenticatedGroup.Group("/admin/policies")
 			{
-				policiesGroup.GET("", middleware.RequireScope(auth.ScopeMirrorsRead), rbacHandlers.ListMirrorPolicies)
-				policiesGroup.GET("/:id", middleware.RequireScope(auth.ScopeMirrorsRead), rbacHandlers.GetMirrorPolicy)
-				policiesGroup.POST("", middleware.RequireScope(auth.ScopeAdmin), rbacHandlers.CreateMirrorPolicy)
-				policiesGroup.PUT("/:id", middleware.RequireScope(auth.ScopeAdmin), rbacHandlers.UpdateMirrorPolicy)
-				policiesGroup.DELETE("/:id", middleware.RequireScope(auth.ScopeAdmin), rbacHandlers.DeleteMirrorPolicy)
-				policiesGroup.POST("/evaluate", middleware.RequireScope(auth.ScopeMirrorsRead), rbacHandlers.EvaluatePolicy)
+				policiesGroup.WithScope(auth.ScopeMirrorsRead).GET("", rbacHandlers.ListMirrorPolicies)
+				policiesGroup.WithScope(auth.ScopeMirrorsRead).GET("/:id", rbacHandlers.GetMirrorPolicy)
+				policiesGroup.WithScope(auth.ScopeAdmin).POST("", rbacHandlers.CreateMirrorPolicy)
+				policiesGroup.WithScope(auth.ScopeAdmin).PUT("/:id", rbacHandlers.UpdateMirrorPolicy)
+				policiesGroup.WithScope(auth.ScopeAdmin).DELETE("/:id", rbacHandlers.DeleteMirrorPolicy)
+				policiesGroup.WithScope(auth.ScopeMirrorsRead).POST("/evaluate", rbacHandlers.EvaluatePolicy)
+			}
+
+			// Network mirror allowlist (which providers /terraform/providers serves)
+			mirrorAllowlistGroup := authenticatedGroup.Group("/admin/mirror-allowlist")
+			{
+				mirrorAllowlistGroup.WithScope(auth.ScopeMirrorsRead).GET("", mirrorAllowlistHandlers.ListEntries)
+				mirrorAllowlistGroup.WithScope(auth.ScopeMirrorsRead).GET("/:id", mirrorAllowlistHandlers.GetEntry)
+				mirrorAllowlistGroup.WithScope(auth.ScopeAdmin).POST("", mirrorAllowlistHandlers.CreateEntry)
+				mirrorAllowlistGroup.WithScope(auth.ScopeAdmin).PUT("/:id", mirrorAllowlistHandlers.UpdateEntry)
+				mirrorAllowlistGroup.WithScope(auth.ScopeAdmin).DELETE("/:id", mirrorAllowlistHandlers.DeleteEntry)
+			}
+
+			// In-flight uploads, mirror syncs, storage migrations and exports on
+			// this replica, with cooperative cancellation (requires admin scope)
+			operationsGroup := authenticatedGroup.Group("/admin/operations")
+			operationsGroup.UseScope(auth.ScopeAdmin)
+			{
+				operationsGroup.GET("", operationsHandlers.ListOperations)
+				operationsGroup.DELETE("/:id", operationsHandlers.CancelOperation)
+			}
+
+			// Read-only maintenance mode toggle (requires admin scope)
+			maintenanceGroup := authenticatedGroup.Group("/admin/maintenance")
+			maintenanceGroup.UseScope(auth.ScopeAdmin)
+			{
+				maintenanceGroup.GET("", maintenanceHandlers.GetMaintenance)
+				maintenanceGroup.POST("", maintenanceHandlers.SetMaintenance)
+			}
+
+			// Two-person rule setting and review of the changes it stages
+			// (requires admin scope)
+			stagedChangesGroup := authenticatedGroup.Group("/admin/staged-changes")
+			stagedChangesGroup.UseScope(auth.ScopeAdmin)
+			{
+				stagedChangesGroup.GET("", stagedChanges.ListStagedChanges)
+				stagedChangesGroup.GET("/:id", stagedChanges.GetStagedChange)
+				stagedChangesGroup.POST("/:id/approve", stagedChanges.ApproveStagedChange)
+				stagedChangesGroup.POST("/:id/reject", stagedChanges.RejectStagedChange)
+			}
+			twoPersonRuleGroup := authenticatedGroup.Group("/admin/two-person-rule")
+			twoPersonRuleGroup.UseScope(auth.ScopeAdmin)
+			{
+				twoPersonRuleGroup.GET("", stagedChanges.GetTwoPersonRule)
+				twoPersonRuleGroup.PUT("", stagedChanges.SetTwoPersonRule)
+			}
+
+			// Storage consistency report, on-demand check, broken marks and
+			// mirror repair of artifacts whose object is missing (requires admin scope)
+			consistencyGroup := authenticatedGroup.Group("/admin/consistency")
+			consistencyGroup.UseScope(auth.ScopeAdmin)
+			{
+				consistencyGroup.GET("/report", storageConsistencyHandlers.GetReport)
+				consistencyGroup.POST("/run", storageConsistencyHandlers.RunCheck)
+				consistencyGroup.POST("/findings/:id/broken", storageConsistencyHandlers.MarkBroken)
+				consistencyGroup.DELETE("/findings/:id/broken", storageConsistencyHandlers.UnmarkBroken)
+				consistencyGroup.POST("/findings/:id/repair", storageConsistencyHandlers.RepairFinding)
+			}
+
+			// Module content index behind code search (requires admin scope)
+			codeIndexGroup := authenticatedGroup.Group("/admin/code-index")
+			codeIndexGroup.UseScope(auth.ScopeAdmin)
+			{
+				codeIndexGroup.GET("", codeIndexHandlers.GetStatus)
+				codeIndexGroup.POST("/backfill", codeIndexHandlers.Backfill)
+			}
+
+			// Re-encryption of stored secrets after an ENCRYPTION_KEY rotation (requires admin scope)
+			cryptoGroup := authenticatedGroup.Group("/admin/crypto")
+			cryptoGroup.UseScope(auth.ScopeAdmin)
+			{
+				cryptoGroup.POST("/reencrypt",
+					middleware.TrackOperation(operationsRegistry, operations.TypeSecretReencrypt),
+					cryptoHandlers.Reencrypt)
 			}
 
 			// Storage Configuration management (requires admin scope)
 			storageGroup := authenticatedGroup.Group("/storage")
-			storageGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
+			storageGroup.UseScope(auth.ScopeAdmin)
 			{
 				storageGroup.GET("/config", storageHandlers.GetActiveStorageConfig)
 				storageGroup.GET("/configs", storageHandlers.ListStorageConfigs)
@@ -959,10 +1355,11 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			storageMigrationService := services.NewStorageMigrationService(
 				storageMigrationRepo, storageConfigRepo, moduleRepo, providerRepo, tokenCipher, cfg,
 			)
+			storageMigrationService.SetOperations(operationsRegistry)
 			storageMigrationHandler := admin.NewStorageMigrationHandler(storageMigrationService)
 
 			migrationGroup := authenticatedGroup.Group("/admin/storage/migrations")
-			migrationGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
+			migrationGroup.UseScope(auth.ScopeAdmin)
 			{
 				migrationGroup.POST("/plan", storageMigrationHandler.PlanMigration)
 				migrationGroup.POST("", storageMigrationHandler.StartMigration)
@@ -973,33 +1370,70 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 
 			// OIDC admin configuration management (requires admin scope)
 			oidcAdminGroup := authenticatedGroup.Group("/admin/oidc")
-			oidcAdminGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
+			oidcAdminGroup.UseScope(auth.ScopeAdmin)
 			{
 				oidcAdminGroup.GET("/config", oidcAdminHandlers.GetActiveOIDCConfig)
 				oidcAdminGroup.PUT("/group-mapping", oidcAdminHandlers.UpdateGroupMapping)
 			}
 
+			// CI OIDC token exchange trust rules (requires admin scope)
+			ciTrustRulesGroup := authenticatedGroup.Group("/admin/ci-trust-rules")
+			ciTrustRulesGroup.UseScope(auth.ScopeAdmin)
+			{
+				ciTrustRulesGroup.GET("", ciTrustRuleHandlers.ListRules)
+				ciTrustRulesGroup.POST("", ciTrustRuleHandlers.CreateRule)
+				ciTrustRulesGroup.GET("/:id", ciTrustRuleHandlers.GetRule)
+				ciTrustRulesGroup.PUT("/:id", ciTrustRuleHandlers.UpdateRule)
+				ciTrustRulesGroup.DELETE("/:id", ciTrustRuleHandlers.DeleteRule)
+			}
+
+			// Custom tenant domains (requires admin scope)
+			tenantDomainsGroup := authenticatedGroup.Group("/admin/tenant-domains")
+			tenantDomainsGroup.UseScope(auth.ScopeAdmin)
+			{
+				tenantDomainsGroup.GET("", tenantDomainHandlers.ListDomains)
+				tenantDomainsGroup.POST("", tenantDomainHandlers.CreateDomain)
+				tenantDomainsGroup.PUT("/:id", tenantDomainHandlers.UpdateDomain)
+				tenantDomainsGroup.DELETE("/:id", tenantDomainHandlers.DeleteDomain)
+			}
+
+			// Background job scheduling state (read-only)
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/jobs",
+				jobsHandler.ListJobs)
+
+			// Route manifest: every registered route with its auth and rate limit
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/routes",
+				routeManifestHandler(router.manifest))
+
+			// Version metadata export for external indexing
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/export/metadata",
+				middleware.TrackOperation(operationsRegistry, operations.TypeMetadataExport),
+				admin.ExportVersionMetadata(repositories.NewMetadataExportRepository(db)))
+
 			// Identity group mappings (SAML + LDAP, read-only from config)
-			authenticatedGroup.GET("/admin/identity/group-mappings",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/identity/group-mappings",
 				authHandlers.IdentityGroupMappingsHandler())
 
 			// mTLS config (read-only from server config)
-			authenticatedGroup.GET("/admin/mtls/config",
-				middleware.RequireScope(auth.ScopeAdmin),
+			authenticatedGroup.WithScope(auth.ScopeAdmin).GET("/admin/mtls/config",
 				authHandlers.MTLSConfigHandler())
 
 			// Audit log read access (requires audit:read scope; admins implicitly have it)
 			auditLogsGroup := authenticatedGroup.Group("/admin/audit-logs")
 			{
-				auditLogsGroup.GET("", middleware.RequireScope(auth.ScopeAuditRead), auditLogHandlers.ListAuditLogsHandler())
-				auditLogsGroup.GET("/export", middleware.RequireScope(auth.ScopeAuditRead), admin.ExportAuditLogs(auditRepo, AppVersion))
-				auditLogsGroup.GET("/:id", middleware.RequireScope(auth.ScopeAuditRead), auditLogHandlers.GetAuditLogHandler())
+				auditLogsGroup.WithScope(auth.ScopeAuditRead).GET("", auditLogHandlers.ListAuditLogsHandler())
+				auditLogsGroup.WithScope(auth.ScopeAuditRead).GET("/export", middleware.TrackOperation(operationsRegistry, operations.TypeAuditExport),
+					admin.ExportAuditLogs(auditRepo, AppVersion))
+				auditLogsGroup.WithScope(auth.ScopeAuditRead).GET("/:id", auditLogHandlers.GetAuditLogHandler())
 			}
 
+			// Publish log integrity (artifact immutability); same scope as the audit log
+			authenticatedGroup.WithScope(auth.ScopeAuditRead).GET("/admin/publish-log/integrity",
+				d.artifactImmutabilityHandlers.VerifyPublishLogHandler())
+
 			// Policy engine admin endpoints (requires admin scope)
 			policyGroup := authenticatedGroup.Group("/admin/policy")
-			policyGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
+			policyGroup.UseScope(auth.ScopeAdmin)
 			{
 				policyGroup.GET("/config", policyAdminHandler.GetPolicyConfig)
 				policyGroup.POST("/reload", policyAdminHandler.ReloadBundle)
@@ -1009,7 +1443,7 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 			// CVE advisory admin endpoints (requires admin scope)
 			advisoryAdminHandlers := admin.NewAdvisoryHandlers(db, cvePollJob)
 			advisoryAdminGroup := authenticatedGroup.Group("/admin/advisories")
-			advisoryAdminGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
+			advisoryAdminGroup.UseScope(auth.ScopeAdmin)
 			{
 				advisoryAdminGroup.GET("", advisoryAdminHandlers.ListAdvisories())
 				advisoryAdminGroup.POST("/poll", advisoryAdminHandlers.TriggerPoll())
@@ -1019,8 +1453,8 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 		// SCIM 2.0 provisioning endpoints — bearer token auth only (no CSRF, no cookie auth).
 		// Require admin or scim:provision scope.
 		scimGroup := router.Group("/scim/v2")
-		scimGroup.Use(middleware.AuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
-		scimGroup.Use(middleware.RequireScope(auth.ScopeSCIMProvision))
+		scimGroup.UseAuth(routeAuthRequired, middleware.AuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
+		scimGroup.UseScope(auth.ScopeSCIMProvision)
 		{
 			scimHandlers := scim.NewHandlers(cfg, db)
 			scimGroup.GET("/Users", scimHandlers.ListUsers())
@@ -1036,21 +1470,24 @@ func registerAPIV1Routes(router *gin.Engine, d *apiV1RouteDeps) {
 		// Development-only endpoints (guarded by DevModeMiddleware)
 		devGroup := apiV1.Group("/dev")
 		devGroup.Use(admin.DevModeMiddleware())
+		devGroup.UseRateLimit(routeRateLimitDev, middleware.RateLimitMiddleware(d.devRateLimiter))
 		{
-			devHandlers := admin.NewDevHandlers(cfg, db)
+			devHandlers := admin.NewDevHandlers(cfg, db).WithAuditRepo(auditRepo)
 			// Unauthenticated dev endpoints (dev-mode-gated only)
 			devGroup.GET("/status", devHandlers.DevStatusHandler())
 			devGroup.POST("/login", devHandlers.DevLoginHandler())
 
 			// Impersonation endpoints (require auth + admin scope)
-			devGroup.Use(middleware.AuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
+			devGroup.UseAuth(routeAuthRequired, middleware.AuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo, tokenRepo, userTokenRevocationRepo))
 			devGroup.GET("/users", devHandlers.ListUsersForImpersonationHandler())
 			devGroup.POST("/impersonate/:user_id", devHandlers.ImpersonateUserHandler())
 		}
 	}
 
 	// Webhook endpoints (public, authentication via signature validation)
-	router.POST("/webhooks/scm/:module_source_repo_id/:secret", scmWebhookHandler.HandleWebhook)
+	router.WithAuth(routeAuthSignature).POST("/webhooks/scm/:module_source_repo_id", scmWebhookHandler.HandleWebhook)
+	// Deprecated: callback URLs embedding a secret, kept until the link's secret is rotated.
+	router.WithAuth(routeAuthURLToken).POST("/webhooks/scm/:module_source_repo_id/:secret", scmWebhookHandler.HandleLegacyWebhook)
 	// Single-use approval token redemption — no auth, token possession is the credential.
-	router.POST("/webhooks/approvals/:token", approvalWebhookHandler.RedeemApprovalToken)
+	router.WithAuth(routeAuthURLToken).POST("/webhooks/approvals/:token", approvalWebhookHandler.RedeemApprovalToken)
 }
@@ -20,8 +20,36 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 )
 
+// previousKeyCheckTimeout bounds the startup scan for secrets still sealed
+// with ENCRYPTION_KEY_PREVIOUS.
+const previousKeyCheckTimeout = 30 * time.Second
+
+// warnUnusedPreviousEncryptionKey scans the stored secrets when
+// ENCRYPTION_KEY_PREVIOUS is set and warns when none of them still needs it,
+// so operators know the rotation is finished and the old key can be removed.
+// It also reports secrets that neither key opens. A failed scan is logged and
+// otherwise ignored; it never blocks startup.
+func warnUnusedPreviousEncryptionKey(reencryptor *services.SecretReencryptor) {
+	ctx, cancel := context.WithTimeout(context.Background(), previousKeyCheckTimeout)
+	defer cancel()
+	report, err := reencryptor.Run(ctx, false)
+	if err != nil {
+		slog.Warn("could not check whether ENCRYPTION_KEY_PREVIOUS is still in use", "error", err)
+		return
+	}
+	if report.Failed > 0 {
+		slog.Warn("some stored secrets cannot be decrypted with ENCRYPTION_KEY or ENCRYPTION_KEY_PREVIOUS", "count", report.Failed)
+	}
+	if report.Previous == 0 {
+		slog.Warn("ENCRYPTION_KEY_PREVIOUS is set but no stored secret is encrypted with it any more; remove it to finish the key rotation")
+		return
+	}
+	slog.Info("stored secrets still encrypted with ENCRYPTION_KEY_PREVIOUS; re-seal them with POST /api/v1/admin/crypto/reencrypt", "count", report.Previous)
+}
+
 // buildIdentityTokenCipher constructs the shared identity/crypto.TokenCipher
 // instance used by the notification-channel Notifier and its admin handlers.
 // It mirrors this repo's own tokenCipher construction (same ENCRYPTION_KEY /
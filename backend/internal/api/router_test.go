@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -16,6 +17,7 @@ import (
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
@@ -67,10 +69,8 @@ func newHealthDB(t *testing.T, pingOK bool) *sql.DB {
 }
 
 func TestHealthCheckHandler_Healthy(t *testing.T) {
-	db := newHealthDB(t, true)
-
 	r := gin.New()
-	r.GET("/health", healthCheckHandler(db))
+	r.GET("/health", healthCheckHandler())
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
@@ -93,24 +93,25 @@ func TestHealthCheckHandler_Healthy(t *testing.T) {
 	}
 }
 
-func TestHealthCheckHandler_Unhealthy(t *testing.T) {
+// TestHealthCheckHandler_DatabaseDown verifies liveness is independent of the
+// database: /health stays 200 while /ready on the same router reports 503.
+func TestHealthCheckHandler_DatabaseDown(t *testing.T) {
 	db := newHealthDB(t, false)
 
 	r := gin.New()
-	r.GET("/health", healthCheckHandler(db))
+	r.GET("/health", healthCheckHandler())
+	r.GET("/ready", readinessHandler(db, &readinessMockStorage{}, nil))
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("/health status = %d, want 200", w.Code)
+	}
 
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
 	if w.Code != http.StatusServiceUnavailable {
-		t.Errorf("status = %d, want 503", w.Code)
-	}
-	var body map[string]interface{}
-	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
-		t.Fatalf("unmarshal: %v", err)
-	}
-	if body["status"] != "unhealthy" {
-		t.Errorf("status = %v, want unhealthy", body["status"])
+		t.Errorf("/ready status = %d, want 503", w.Code)
 	}
 }
 
@@ -122,7 +123,7 @@ func TestReadinessHandler_Ready(t *testing.T) {
 	db := newHealthDB(t, true)
 
 	r := gin.New()
-	r.GET("/ready", readinessHandler(db, &readinessMockStorage{}))
+	r.GET("/ready", readinessHandler(db, &readinessMockStorage{}, nil))
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
@@ -143,7 +144,7 @@ func TestReadinessHandler_NotReady(t *testing.T) {
 	db := newHealthDB(t, false)
 
 	r := gin.New()
-	r.GET("/ready", readinessHandler(db, &readinessMockStorage{}))
+	r.GET("/ready", readinessHandler(db, &readinessMockStorage{}, nil))
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
@@ -164,7 +165,7 @@ func TestReadinessHandler_StorageNotReady(t *testing.T) {
 	db := newHealthDB(t, true)
 
 	r := gin.New()
-	r.GET("/ready", readinessHandler(db, &readinessMockStorage{existsErr: errors.New("storage offline")}))
+	r.GET("/ready", readinessHandler(db, &readinessMockStorage{existsErr: errors.New("storage offline")}, nil))
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
@@ -181,6 +182,91 @@ func TestReadinessHandler_StorageNotReady(t *testing.T) {
 	}
 }
 
+func TestReadinessHandler_NotReadySetsRetryAfter(t *testing.T) {
+	db := newHealthDB(t, false)
+
+	r := gin.New()
+	r.GET("/ready", readinessHandler(db, &readinessMockStorage{}, nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header on 503 readiness response")
+	}
+}
+
+type readinessMaintenanceLoader struct{ mode *models.MaintenanceMode }
+
+func (l readinessMaintenanceLoader) Get(context.Context) (*models.MaintenanceMode, error) {
+	return l.mode, nil
+}
+
+// TestReadinessHandler_MaintenanceStaysReady verifies maintenance mode is
+// reported without failing readiness, since reads are still served.
+func TestReadinessHandler_MaintenanceStaysReady(t *testing.T) {
+	db := newHealthDB(t, true)
+	state := middleware.NewMaintenanceState(readinessMaintenanceLoader{
+		mode: &models.MaintenanceMode{Enabled: true, Message: "migrating storage"},
+	}, 0)
+
+	r := gin.New()
+	r.GET("/ready", readinessHandler(db, &readinessMockStorage{}, state))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var body ReadinessResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !body.Ready || body.Maintenance == nil || body.Maintenance.Message != "migrating storage" {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+// TestReadinessChecker_CachesWithinTTL verifies that a burst of probes inside
+// the TTL costs a single database ping, and that the cache expires.
+func TestReadinessChecker_CachesWithinTTL(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectPing()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	checker := &readinessChecker{
+		db:      db,
+		storage: &readinessMockStorage{},
+		ttl:     2 * time.Second,
+		now:     func() time.Time { return now },
+	}
+
+	for i := 0; i < 5; i++ {
+		if res := checker.check(context.Background()); res.status != http.StatusOK {
+			t.Fatalf("call %d: status = %d, want 200", i, res.status)
+		}
+		now = now.Add(300 * time.Millisecond)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected exactly one ping: %v", err)
+	}
+
+	// Past the TTL the next call probes again and sees the new state.
+	now = now.Add(2 * time.Second)
+	mock.ExpectPing().WillReturnError(sql.ErrConnDone)
+	if res := checker.check(context.Background()); res.status != http.StatusServiceUnavailable {
+		t.Errorf("after TTL: status = %d, want 503", res.status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected a second ping after TTL: %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // serviceDiscoveryHandler
 // ---------------------------------------------------------------------------
@@ -271,6 +357,26 @@ func TestVersionHandler(t *testing.T) {
 	if body["default_language"] != "en" {
 		t.Errorf("default_language = %v, want \"en\"", body["default_language"])
 	}
+	if body["dev_endpoints_active"] != false {
+		t.Errorf("dev_endpoints_active = %v, want false without DEV_MODE", body["dev_endpoints_active"])
+	}
+}
+
+func TestVersionHandler_DevEndpointsActive(t *testing.T) {
+	t.Setenv("DEV_MODE", "true")
+	r := gin.New()
+	r.GET("/version", versionHandler(&config.Config{}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if body["dev_endpoints_active"] != true {
+		t.Errorf("dev_endpoints_active = %v, want true with DEV_MODE=true", body["dev_endpoints_active"])
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -524,3 +630,78 @@ func TestAllowLowEntropyEncryptionKey(t *testing.T) {
 		})
 	}
 }
+
+// ---------------------------------------------------------------------------
+// NewRouter initializer failures
+// ---------------------------------------------------------------------------
+
+// settledGoroutines returns the goroutine count once it stops falling, so
+// goroutines still exiting after a failed NewRouter are not counted as leaks.
+func settledGoroutines() int {
+	n := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		time.Sleep(10 * time.Millisecond)
+		m := runtime.NumGoroutine()
+		if m >= n {
+			return m
+		}
+		n = m
+	}
+	return n
+}
+
+func TestNewRouter_BadStorageConfigReturnsError(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{}
+	cfg.Storage.DefaultBackend = "floppy"
+
+	before := settledGoroutines()
+	router, bg, err := NewRouter(cfg, db, db)
+	if err == nil {
+		t.Fatal("expected an error for an unknown storage backend")
+	}
+	if router != nil || bg != nil {
+		t.Error("expected no router or background services on failure")
+	}
+	if !strings.Contains(err.Error(), "storage backend") {
+		t.Errorf("error = %v, want a storage backend error", err)
+	}
+	if after := settledGoroutines(); after > before {
+		t.Errorf("goroutines: %d before, %d after a failed NewRouter", before, after)
+	}
+}
+
+// A failure late in NewRouter, after the OIDC state store has started its
+// cleanup goroutine, must stop it and must not start any background job.
+func TestNewRouter_LateFailureLeaksNoGoroutines(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	t.Setenv("ENCRYPTION_KEY", "9f86d081884c7d659a2feaa0c55ad015")
+
+	cfg := &config.Config{}
+	cfg.Storage.DefaultBackend = "local"
+	cfg.Storage.Local.BasePath = t.TempDir()
+	cfg.Policy.Enabled = true
+	cfg.Policy.BundleURL = "http://policy.invalid/bundle.tar.gz"
+
+	before := settledGoroutines()
+	_, bg, err := NewRouter(cfg, db, db)
+	if err == nil {
+		bg.Shutdown()
+		t.Fatal("expected an error for an unusable policy bundle URL")
+	}
+	if !strings.Contains(err.Error(), "policy engine") {
+		t.Errorf("error = %v, want a policy engine error", err)
+	}
+	if after := settledGoroutines(); after > before {
+		t.Errorf("goroutines: %d before, %d after a failed NewRouter", before, after)
+	}
+}
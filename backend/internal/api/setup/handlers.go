@@ -27,6 +27,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
 	"github.com/terraform-registry/terraform-registry/internal/jobs"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/scanner"
 	"github.com/terraform-registry/terraform-registry/internal/scanner/installer"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
@@ -127,7 +128,8 @@ func (h *Handlers) GetSetupStatus(c *gin.Context) {
 // @Produce      json
 // @Success      200  {object}  setup.ValidateTokenResponse
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Router       /api/v1/setup/validate-token [post]
 func (h *Handlers) ValidateToken(c *gin.Context) {
 	// If we reach this handler, the SetupTokenMiddleware has already validated the token
@@ -147,7 +149,8 @@ func (h *Handlers) ValidateToken(c *gin.Context) {
 // @Success      200  {object}  setup.TestOIDCConfigResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid configuration"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Router       /api/v1/setup/oidc/test [post]
 func (h *Handlers) TestOIDCConfig(c *gin.Context) {
 	var input models.OIDCConfigInput
@@ -208,7 +211,8 @@ func (h *Handlers) TestOIDCConfig(c *gin.Context) {
 // @Success      200  {object}  models.OIDCConfigResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid configuration"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/setup/oidc [post]
 func (h *Handlers) SaveOIDCConfig(c *gin.Context) {
@@ -323,7 +327,8 @@ func (h *Handlers) SaveOIDCConfig(c *gin.Context) {
 // @Success      200  {object}  setup.TestStorageConfigResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid configuration"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Router       /api/v1/setup/storage/test [post]
 func (h *Handlers) TestStorageConfig(c *gin.Context) {
 	var input models.StorageConfigInput
@@ -374,7 +379,8 @@ func (h *Handlers) TestStorageConfig(c *gin.Context) {
 // @Success      200  {object}  setup.SaveStorageConfigResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid configuration"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/setup/storage [post]
 func (h *Handlers) SaveStorageConfig(c *gin.Context) {
@@ -432,7 +438,8 @@ type ConfigureAdminInput struct {
 // @Success      200  {object}  setup.ConfigureAdminResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid email"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/setup/admin [post]
 func (h *Handlers) ConfigureAdmin(c *gin.Context) {
@@ -494,19 +501,29 @@ func (h *Handlers) ConfigureAdmin(c *gin.Context) {
 }
 
 // @Summary      Complete setup
-// @Description  Finalizes the initial setup. Verifies that authentication (OIDC or LDAP), storage, and admin user are configured, then permanently disables setup endpoints by clearing the setup token.
+// @Description  Finalizes the initial setup. Verifies that authentication (OIDC or LDAP), storage, and admin user are configured, then permanently disables setup endpoints by clearing the setup token and records when and from which client address setup was completed, in one transaction. Concurrent completions are serialized: exactly one succeeds and the others get 410.
 // @Tags         Setup
 // @Security     SetupToken
 // @Produce      json
 // @Success      200  {object}  setup.CompleteSetupResponse
 // @Failure      400  {object}  map[string]interface{}  "Setup is incomplete — missing required configuration"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/setup/complete [post]
 func (h *Handlers) CompleteSetup(c *gin.Context) {
 	ctx := c.Request.Context()
 
+	// Completion commits through the transaction SetupTokenMiddleware holds
+	// the exclusive setup state lock in.
+	tx := middleware.SetupTx(c)
+	if tx == nil {
+		slog.Error("setup: complete called without a setup transaction")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete setup"})
+		return
+	}
+
 	// Verify all required components are configured
 	status, err := h.oidcConfigRepo.GetEnhancedSetupStatus(ctx)
 	if err != nil {
@@ -528,13 +545,13 @@ func (h *Handlers) CompleteSetup(c *gin.Context) {
 		}
 
 		// Clear the setup token hash to re-disable setup endpoints
-		if err := h.oidcConfigRepo.SetSetupCompleted(ctx); err != nil {
+		if err := h.oidcConfigRepo.CompleteSetup(ctx, tx, c.ClientIP()); err != nil {
 			slog.Error("setup: failed to complete feature setup", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete feature setup"})
 			return
 		}
 
-		slog.Info("setup: pending feature setup completed successfully")
+		slog.Info("setup: pending feature setup completed successfully", "ip", c.ClientIP())
 		c.JSON(http.StatusOK, gin.H{
 			"message":         "Feature setup completed successfully.",
 			"setup_completed": true,
@@ -564,13 +581,13 @@ func (h *Handlers) CompleteSetup(c *gin.Context) {
 
 	// Mark setup as completed — this also NULLs the setup_token_hash,
 	// permanently disabling all setup endpoints.
-	if err := h.oidcConfigRepo.SetSetupCompleted(ctx); err != nil {
+	if err := h.oidcConfigRepo.CompleteSetup(ctx, tx, c.ClientIP()); err != nil {
 		slog.Error("setup: failed to complete setup", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete setup"})
 		return
 	}
 
-	slog.Info("setup: initial setup completed successfully")
+	slog.Info("setup: initial setup completed successfully", "ip", c.ClientIP())
 
 	authMethod := "OIDC"
 	if status.LDAPConfigured {
@@ -624,7 +641,8 @@ type TestScanningConfigInput struct {
 // @Success      200  {object}  setup.TestScanningConfigResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid configuration"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Router       /api/v1/setup/scanning/test [post]
 func (h *Handlers) TestScanningConfig(c *gin.Context) {
 	var input TestScanningConfigInput
@@ -727,7 +745,8 @@ type SaveScanningConfigInput struct {
 // @Success      200  {object}  setup.SaveScanningConfigResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid configuration"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/setup/scanning [post]
 func (h *Handlers) SaveScanningConfig(c *gin.Context) {
@@ -826,7 +845,8 @@ func (h *Handlers) SaveScanningConfig(c *gin.Context) {
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}  "Invalid configuration"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Router       /api/v1/setup/ldap/test [post]
 func (h *Handlers) TestLDAPConfig(c *gin.Context) {
 	var input models.LDAPConfigInput
@@ -870,7 +890,8 @@ func (h *Handlers) TestLDAPConfig(c *gin.Context) {
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}  "Invalid configuration"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/setup/ldap [post]
 func (h *Handlers) SaveLDAPConfig(c *gin.Context) {
@@ -1083,6 +1104,12 @@ func buildTestStorageConfig(input *models.StorageConfigInput) *config.Config {
 			CredentialsJSON: input.GCSCredentialsJSON,
 			Endpoint:        input.GCSEndpoint,
 		}
+	case "artifactory":
+		testCfg.Storage.Artifactory = config.ArtifactoryStorageConfig{
+			BaseURL:     input.ArtifactoryBaseURL,
+			Repository:  input.ArtifactoryRepository,
+			AccessToken: input.ArtifactoryAccessToken,
+		}
 	}
 	return testCfg
 }
@@ -1151,6 +1178,16 @@ func (h *Handlers) buildEncryptedStorageConfig(input *models.StorageConfigInput)
 			}
 			cfg.GCSCredentialsJSONEncrypted = toNullString(encrypted)
 		}
+	case "artifactory":
+		cfg.ArtifactoryBaseURL = toNullString(input.ArtifactoryBaseURL)
+		cfg.ArtifactoryRepository = toNullString(input.ArtifactoryRepository)
+		if input.ArtifactoryAccessToken != "" {
+			encrypted, err := h.tokenCipher.Seal(input.ArtifactoryAccessToken)
+			if err != nil {
+				return nil, err
+			}
+			cfg.ArtifactoryAccessTokenEncrypted = toNullString(encrypted)
+		}
 	}
 
 	return cfg, nil
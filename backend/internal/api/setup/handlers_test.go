@@ -18,6 +18,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/local"
 )
 
@@ -36,6 +37,7 @@ func TestMain(m *testing.M) {
 // sqlmock for userRepo, and sqlmock for orgRepo.
 type testEnv struct {
 	h           *Handlers
+	oidcDB      *sqlx.DB
 	oidcMock    sqlmock.Sqlmock
 	storageMock sqlmock.Sqlmock
 	userMock    sqlmock.Sqlmock
@@ -51,7 +53,8 @@ func newTestEnv(t *testing.T) *testEnv {
 		t.Fatalf("sqlmock: %v", err)
 	}
 	t.Cleanup(func() { oidcDB.Close() })
-	oidcRepo := repositories.NewOIDCConfigRepository(sqlx.NewDb(oidcDB, "sqlmock"))
+	oidcSqlx := sqlx.NewDb(oidcDB, "sqlmock")
+	oidcRepo := repositories.NewOIDCConfigRepository(oidcSqlx)
 
 	// Storage config repo (sqlx)
 	storageDB, storageMock, err := sqlmock.New()
@@ -95,6 +98,7 @@ func newTestEnv(t *testing.T) *testEnv {
 
 	return &testEnv{
 		h:           h,
+		oidcDB:      oidcSqlx,
 		oidcMock:    oidcMock,
 		storageMock: storageMock,
 		userMock:    userMock,
@@ -102,6 +106,25 @@ func newTestEnv(t *testing.T) *testEnv {
 	}
 }
 
+// newCompleteRouter mounts CompleteSetup behind a stand-in for
+// SetupTokenMiddleware that opens the setup transaction the handler commits
+// through. The transaction's begin is expected first.
+func newCompleteRouter(env *testEnv) *gin.Engine {
+	env.oidcMock.ExpectBegin()
+	r := gin.New()
+	r.POST("/complete", func(c *gin.Context) {
+		tx, err := env.oidcDB.Beginx()
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = tx.Rollback() }()
+		c.Set(middleware.SetupTxContextKey, tx)
+		c.Next()
+	}, env.h.CompleteSetup)
+	return r
+}
+
 func jsonBody(v interface{}) *bytes.Buffer {
 	b, _ := json.Marshal(v)
 	return bytes.NewBuffer(b)
@@ -954,12 +977,25 @@ func TestConfigureAdmin_PendingAdminEmailFails(t *testing.T) {
 // CompleteSetup
 // ---------------------------------------------------------------------------
 
-func TestCompleteSetup_StatusError(t *testing.T) {
+func TestCompleteSetup_NoSetupTx(t *testing.T) {
 	env := newTestEnv(t)
 
 	r := gin.New()
 	r.POST("/complete", env.h.CompleteSetup)
 
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/complete", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
+
+func TestCompleteSetup_StatusError(t *testing.T) {
+	env := newTestEnv(t)
+
+	r := newCompleteRouter(env)
+
 	env.oidcMock.ExpectQuery("SELECT.*FROM system_settings").
 		WillReturnError(errDB)
 
@@ -974,8 +1010,7 @@ func TestCompleteSetup_StatusError(t *testing.T) {
 func TestCompleteSetup_Incomplete(t *testing.T) {
 	env := newTestEnv(t)
 
-	r := gin.New()
-	r.POST("/complete", env.h.CompleteSetup)
+	r := newCompleteRouter(env)
 
 	// OIDC not configured, storage not configured, no admin
 	env.oidcMock.ExpectQuery("SELECT.*FROM system_settings").
@@ -1000,8 +1035,7 @@ func TestCompleteSetup_Incomplete(t *testing.T) {
 func TestCompleteSetup_Success(t *testing.T) {
 	env := newTestEnv(t)
 
-	r := gin.New()
-	r.POST("/complete", env.h.CompleteSetup)
+	r := newCompleteRouter(env)
 
 	// All configured
 	env.oidcMock.ExpectQuery("SELECT.*FROM system_settings").
@@ -1012,9 +1046,10 @@ func TestCompleteSetup_Success(t *testing.T) {
 		}))
 	env.oidcMock.ExpectQuery("SELECT scanning_configured FROM system_settings").
 		WillReturnRows(sqlmock.NewRows([]string{"scanning_configured"}).AddRow(false))
-	// SetSetupCompleted
+	// CompleteSetup
 	env.oidcMock.ExpectExec("UPDATE system_settings SET").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	env.oidcMock.ExpectCommit()
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("POST", "/complete", nil))
@@ -1032,8 +1067,7 @@ func TestCompleteSetup_Success(t *testing.T) {
 func TestCompleteSetup_SetCompletedError(t *testing.T) {
 	env := newTestEnv(t)
 
-	r := gin.New()
-	r.POST("/complete", env.h.CompleteSetup)
+	r := newCompleteRouter(env)
 
 	env.oidcMock.ExpectQuery("SELECT.*FROM system_settings").
 		WillReturnRows(settingsRow(settingsOpts{
@@ -1043,9 +1077,10 @@ func TestCompleteSetup_SetCompletedError(t *testing.T) {
 		}))
 	env.oidcMock.ExpectQuery("SELECT scanning_configured FROM system_settings").
 		WillReturnRows(sqlmock.NewRows([]string{"scanning_configured"}).AddRow(false))
-	// SetSetupCompleted fails
+	// CompleteSetup fails; the transaction is rolled back
 	env.oidcMock.ExpectExec("UPDATE system_settings SET").
 		WillReturnError(errDB)
+	env.oidcMock.ExpectRollback()
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("POST", "/complete", nil))
@@ -1582,8 +1617,7 @@ func TestSaveLDAPConfig_DBError(t *testing.T) {
 func TestCompleteSetup_LDAPSuccess(t *testing.T) {
 	env := newTestEnv(t)
 
-	r := gin.New()
-	r.POST("/complete", env.h.CompleteSetup)
+	r := newCompleteRouter(env)
 
 	// LDAP configured instead of OIDC
 	env.oidcMock.ExpectQuery("SELECT.*FROM system_settings").
@@ -1595,9 +1629,10 @@ func TestCompleteSetup_LDAPSuccess(t *testing.T) {
 		}))
 	env.oidcMock.ExpectQuery("SELECT scanning_configured FROM system_settings").
 		WillReturnRows(sqlmock.NewRows([]string{"scanning_configured"}).AddRow(false))
-	// SetSetupCompleted
+	// CompleteSetup
 	env.oidcMock.ExpectExec("UPDATE system_settings SET").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	env.oidcMock.ExpectCommit()
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest("POST", "/complete", nil))
@@ -1620,8 +1655,7 @@ func TestCompleteSetup_LDAPSuccess(t *testing.T) {
 func TestCompleteSetup_NoAuth(t *testing.T) {
 	env := newTestEnv(t)
 
-	r := gin.New()
-	r.POST("/complete", env.h.CompleteSetup)
+	r := newCompleteRouter(env)
 
 	// Neither OIDC nor LDAP configured, but storage + admin are
 	env.oidcMock.ExpectQuery("SELECT.*FROM system_settings").
@@ -26,7 +26,8 @@ type InstallScannerInput struct {
 // @Success      200  {object}  InstallScannerResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid request"
 // @Failure      401  {object}  map[string]interface{}  "Invalid setup token"
-// @Failure      403  {object}  map[string]interface{}  "Setup already completed"
+// @Failure      403  {object}  map[string]interface{}  "No setup token has been generated"
+// @Failure      410  {object}  map[string]interface{}  "Setup already completed (code setup_already_completed)"
 // @Failure      500  {object}  map[string]interface{}  "Install directory not configured"
 // @Router       /api/v1/setup/scanning/install [post]
 func (h *Handlers) InstallScanner(c *gin.Context) {
@@ -115,6 +115,18 @@ func startSuiteDiscovery(cfg *config.Config) *suite.DiscoveryClient {
 // @Failure      401  {object}  map[string]interface{}  "Authentication required"
 // @Router       /api/v1/suite/modules/{namespace}/{name}/{system}/consumers [get]
 func moduleConsumersHandler(getClient func() *suite.DiscoveryClient, cfg *config.Config, egressGuard *httpsafe.Guard) gin.HandlerFunc {
+	lookup := moduleConsumersLookup(getClient, cfg, egressGuard)
+	return func(c *gin.Context) {
+		moduleAddr := c.Param("namespace") + "/" + c.Param("name") + "/" + c.Param("system")
+		c.JSON(http.StatusOK, lookup(c.Request.Context(), moduleAddr))
+	}
+}
+
+// moduleConsumersLookup returns the "Consumed by" lookup behind
+// moduleConsumersHandler, for a namespace/name/system module address. It is
+// shared with the admin module overview's dependents section and, like the
+// handler, returns an empty list on any failure.
+func moduleConsumersLookup(getClient func() *suite.DiscoveryClient, cfg *config.Config, egressGuard *httpsafe.Guard) func(ctx context.Context, moduleAddr string) gin.H {
 	// hosts is THIS registry's set of canonical host identities the sibling
 	// matches "consumed by" on: its public host, its base/discovery host, and any
 	// operator-configured aliases (TFR_SERVER_HOST_ALIASES) for vanity-CNAME or
@@ -128,25 +140,22 @@ func moduleConsumersHandler(getClient func() *suite.DiscoveryClient, cfg *config
 	// same as any other operator/upstream-influenced target (issue #653).
 	httpClient := httpsafe.NewClient(2*time.Second, egressGuard)
 
-	return func(c *gin.Context) {
+	return func(ctx context.Context, moduleAddr string) gin.H {
 		empty := gin.H{"consumers": []any{}, "total": 0}
 		dc := getClient()
 		if dc == nil || len(hosts) == 0 || cfg.Suite.SiblingToken == "" {
-			c.JSON(http.StatusOK, empty)
-			return
+			return empty
 		}
 		state, m := dc.Snapshot()
 		if state != suite.StateActive || m == nil || m.PublicURL == "" {
-			c.JSON(http.StatusOK, empty)
-			return
+			return empty
 		}
 
 		// siblingURL is the trusted origin advertised via discovery — the single
 		// host the outbound request below is permitted to reach.
 		siblingURL, err := url.Parse(m.PublicURL)
 		if err != nil || siblingURL.Host == "" {
-			c.JSON(http.StatusOK, empty)
-			return
+			return empty
 		}
 		// m.PublicURL is the sibling's self-advertised manifest field, not the
 		// operator-pinned SiblingURL, so its scheme and target range are
@@ -155,12 +164,9 @@ func moduleConsumersHandler(getClient func() *suite.DiscoveryClient, cfg *config
 		// a clear reason instead of an opaque "sibling unreachable" empty
 		// result on the happy-path shape).
 		if err := egressGuard.ValidateURL(m.PublicURL); err != nil {
-			c.JSON(http.StatusOK, empty)
-			return
+			return empty
 		}
 
-		moduleAddr := c.Param("namespace") + "/" + c.Param("name") + "/" + c.Param("system")
-
 		// Build the outbound URL structurally from the trusted sibling origin
 		// (siblingURL, parsed from the discovery-advertised PublicURL). The
 		// user-provided module address and the registry's own host set are
@@ -181,22 +187,19 @@ func moduleConsumersHandler(getClient func() *suite.DiscoveryClient, cfg *config
 		}
 		target.RawQuery = q.Encode()
 
-		req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, target.String(), nil)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
 		if err != nil {
-			c.JSON(http.StatusOK, empty)
-			return
+			return empty
 		}
 		req.Header.Set(suiteServiceTokenHeader, cfg.Suite.SiblingToken)
 
 		resp, err := httpClient.Do(req)
 		if err != nil {
-			c.JSON(http.StatusOK, empty)
-			return
+			return empty
 		}
 		defer func() { _ = resp.Body.Close() }()
 		if resp.StatusCode != http.StatusOK {
-			c.JSON(http.StatusOK, empty)
-			return
+			return empty
 		}
 
 		// Forward the sibling's rows opaquely (RawMessage) — the registry does not
@@ -206,12 +209,11 @@ func moduleConsumersHandler(getClient func() *suite.DiscoveryClient, cfg *config
 			Total     int               `json:"total"`
 		}
 		if err := json.NewDecoder(io.LimitReader(resp.Body, maxConsumersResponseBytes)).Decode(&body); err != nil {
-			c.JSON(http.StatusOK, empty)
-			return
+			return empty
 		}
 		if body.Consumers == nil {
 			body.Consumers = []json.RawMessage{}
 		}
-		c.JSON(http.StatusOK, gin.H{"consumers": body.Consumers, "total": body.Total})
+		return gin.H{"consumers": body.Consumers, "total": body.Total}
 	}
 }
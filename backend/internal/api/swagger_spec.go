@@ -0,0 +1,273 @@
+// swagger_spec.go builds the variants of the embedded Swagger 2.0 spec served
+// at /swagger.json. The combined spec is the default; ?group=admin|protocol|public
+// narrows it to one API surface so consumers generating clients only get the
+// operations they call. Every variant gets the configured api_docs metadata and
+// the ErrorResponse envelope definition.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+// Spec groups accepted by GET /swagger.json?group=.
+const (
+	specGroupAdmin    = "admin"
+	specGroupProtocol = "protocol"
+	specGroupPublic   = "public"
+)
+
+var specGroups = []string{specGroupAdmin, specGroupProtocol, specGroupPublic}
+
+// errorResponseDefinition is the shared error envelope. API handlers answer
+// {"error": "..."}; the Terraform protocol handlers answer {"errors": [...]}.
+const errorResponseDefinition = "ErrorResponse"
+
+// protocolPathPrefixes are the surfaces consumed by Terraform/OpenTofu and OCI
+// clients rather than by API callers.
+var protocolPathPrefixes = []string{"/v1/", "/v2/", "/terraform/", "/.well-known/"}
+
+// adminPathPrefixes are always grouped as admin, even where an operation is
+// reachable without credentials (first-run setup, SCIM discovery).
+var adminPathPrefixes = []string{"/api/v1/admin/", "/api/v1/setup/", "/scim/"}
+
+// operationSpecGroup assigns one operation to a group. Tags are the first
+// signal: operations tagged only for the Terraform protocols belong there.
+// Several tags (Modules, Providers, System) span the protocol, public and
+// admin surfaces, so the path and the operation's security requirement decide
+// the rest.
+func operationSpecGroup(path string, op map[string]interface{}) string {
+	tags, _ := op["tags"].([]interface{})
+	for _, t := range tags {
+		switch t {
+		case "Mirror Protocol", "Terraform Binaries", "Files":
+			return specGroupProtocol
+		case "Setup", "SCIM", "RBAC", "Audit":
+			return specGroupAdmin
+		}
+	}
+	for _, p := range protocolPathPrefixes {
+		if strings.HasPrefix(path, p) {
+			return specGroupProtocol
+		}
+	}
+	for _, p := range adminPathPrefixes {
+		if strings.HasPrefix(path, p) {
+			return specGroupAdmin
+		}
+	}
+	if sec, ok := op["security"].([]interface{}); ok && len(sec) > 0 {
+		return specGroupAdmin
+	}
+	return specGroupPublic
+}
+
+// buildSwaggerSpecs returns the combined spec (key "") and one spec per group.
+func buildSwaggerSpecs(raw []byte, docsCfg config.ApiDocsConfig) (map[string][]byte, error) {
+	out := make(map[string][]byte, len(specGroups)+1)
+	for _, group := range append([]string{""}, specGroups...) {
+		spec, err := buildSwaggerSpec(raw, docsCfg, group)
+		if err != nil {
+			return nil, err
+		}
+		out[group] = spec
+	}
+	return out, nil
+}
+
+// buildSwaggerSpec returns the spec for one group ("" for the combined spec).
+func buildSwaggerSpec(raw []byte, docsCfg config.ApiDocsConfig, group string) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal swagger spec: %w", err)
+	}
+
+	injectSpecMetadata(doc, docsCfg)
+	addErrorEnvelope(doc)
+
+	if group != "" {
+		filterSpecPaths(doc, group)
+		pruneSpecDefinitions(doc)
+		if info, ok := doc["info"].(map[string]interface{}); ok {
+			if title, ok := info["title"].(string); ok {
+				info["title"] = title + " (" + group + ")"
+			}
+		}
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal swagger spec: %w", err)
+	}
+	return out, nil
+}
+
+// injectSpecMetadata applies the configured terms-of-service, contact, and
+// license fields to the info object.
+func injectSpecMetadata(doc map[string]interface{}, docsCfg config.ApiDocsConfig) {
+	info, _ := doc["info"].(map[string]interface{})
+	if info == nil {
+		info = map[string]interface{}{}
+		doc["info"] = info
+	}
+
+	if docsCfg.TermsOfService != "" {
+		info["termsOfService"] = docsCfg.TermsOfService
+	}
+	contact, _ := info["contact"].(map[string]interface{})
+	if contact == nil {
+		contact = map[string]interface{}{}
+		info["contact"] = contact
+	}
+	if docsCfg.ContactName != "" {
+		contact["name"] = docsCfg.ContactName
+	}
+	if docsCfg.ContactEmail != "" {
+		contact["email"] = docsCfg.ContactEmail
+	}
+	if docsCfg.License != "" {
+		info["license"] = map[string]interface{}{"name": docsCfg.License}
+	}
+}
+
+// addErrorEnvelope adds the ErrorResponse definition and points every 4xx/5xx
+// response that is documented as a free-form object at it, so generated
+// clients get a typed error.
+func addErrorEnvelope(doc map[string]interface{}) {
+	defs, _ := doc["definitions"].(map[string]interface{})
+	if defs == nil {
+		defs = map[string]interface{}{}
+		doc["definitions"] = defs
+	}
+	defs[errorResponseDefinition] = map[string]interface{}{
+		"type":        "object",
+		"description": "Error envelope. Registry API endpoints set error; Terraform protocol endpoints set errors.",
+		"properties": map[string]interface{}{
+			"error": map[string]interface{}{
+				"type":        "string",
+				"description": "Human-readable error message",
+			},
+			"errors": map[string]interface{}{
+				"type":        "array",
+				"description": "Error messages (Terraform registry protocol format)",
+				"items":       map[string]interface{}{"type": "string"},
+			},
+		},
+		"additionalProperties": true,
+	}
+
+	ref := map[string]interface{}{"$ref": "#/definitions/" + errorResponseDefinition}
+	paths, _ := doc["paths"].(map[string]interface{})
+	for _, item := range paths {
+		ops, _ := item.(map[string]interface{})
+		for method, o := range ops {
+			op, ok := o.(map[string]interface{})
+			if !ok || method == "parameters" {
+				continue
+			}
+			responses, _ := op["responses"].(map[string]interface{})
+			for code, r := range responses {
+				resp, ok := r.(map[string]interface{})
+				if !ok || !(strings.HasPrefix(code, "4") || strings.HasPrefix(code, "5")) {
+					continue
+				}
+				if isFreeFormObjectSchema(resp["schema"]) {
+					resp["schema"] = ref
+				}
+			}
+		}
+	}
+}
+
+// isFreeFormObjectSchema reports whether s is the schema swag emits for
+// map[string]interface{}.
+func isFreeFormObjectSchema(s interface{}) bool {
+	schema, ok := s.(map[string]interface{})
+	if !ok || schema["type"] != "object" {
+		return false
+	}
+	_, hasProps := schema["properties"]
+	_, hasRef := schema["$ref"]
+	return !hasProps && !hasRef
+}
+
+// filterSpecPaths drops every operation outside group, and paths left empty.
+func filterSpecPaths(doc map[string]interface{}, group string) {
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path, item := range paths {
+		ops, _ := item.(map[string]interface{})
+		kept := 0
+		for method, o := range ops {
+			if method == "parameters" {
+				continue
+			}
+			op, ok := o.(map[string]interface{})
+			if !ok || operationSpecGroup(path, op) != group {
+				delete(ops, method)
+				continue
+			}
+			kept++
+		}
+		if kept == 0 {
+			delete(paths, path)
+		}
+	}
+}
+
+// pruneSpecDefinitions drops definitions no remaining operation references,
+// directly or through another definition. ErrorResponse is always kept.
+func pruneSpecDefinitions(doc map[string]interface{}) {
+	defs, _ := doc["definitions"].(map[string]interface{})
+	if defs == nil {
+		return
+	}
+
+	used := map[string]bool{errorResponseDefinition: true}
+	var queue []string
+	collectSpecRefs(doc["paths"], func(name string) {
+		if !used[name] {
+			used[name] = true
+			queue = append(queue, name)
+		}
+	})
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		collectSpecRefs(defs[name], func(ref string) {
+			if !used[ref] {
+				used[ref] = true
+				queue = append(queue, ref)
+			}
+		})
+	}
+
+	for name := range defs {
+		if !used[name] {
+			delete(defs, name)
+		}
+	}
+}
+
+// collectSpecRefs calls visit with the definition name of every
+// "#/definitions/..." $ref found in v.
+func collectSpecRefs(v interface{}, visit func(string)) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if s, ok := child.(string); ok && k == "$ref" {
+				if name, found := strings.CutPrefix(s, "#/definitions/"); found {
+					visit(name)
+				}
+				continue
+			}
+			collectSpecRefs(child, visit)
+		}
+	case []interface{}:
+		for _, child := range t {
+			collectSpecRefs(child, visit)
+		}
+	}
+}
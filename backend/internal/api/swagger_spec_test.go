@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+	"github.com/terraform-registry/terraform-registry/docs"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+func decodeSpec(t *testing.T, raw []byte) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("spec is not JSON: %v", err)
+	}
+	return doc
+}
+
+func specOperations(doc map[string]interface{}) map[string]map[string]interface{} {
+	ops := map[string]map[string]interface{}{}
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path, item := range paths {
+		for method, o := range item.(map[string]interface{}) {
+			if op, ok := o.(map[string]interface{}); ok && method != "parameters" {
+				ops[method+" "+path] = op
+			}
+		}
+	}
+	return ops
+}
+
+func TestBuildSwaggerSpecs_GroupsValidateAndPartition(t *testing.T) {
+	specs, err := buildSwaggerSpecs(docs.SwaggerJSON, config.ApiDocsConfig{})
+	if err != nil {
+		t.Fatalf("buildSwaggerSpecs: %v", err)
+	}
+
+	combined := specOperations(decodeSpec(t, specs[""]))
+	seen := map[string]string{}
+	for _, group := range specGroups {
+		raw := specs[group]
+
+		analyzed, err := loads.Analyzed(raw, "")
+		if err != nil {
+			t.Fatalf("%s: load spec: %v", group, err)
+		}
+		if err := validate.Spec(analyzed, strfmt.Default); err != nil {
+			t.Errorf("%s: spec does not validate: %v", group, err)
+		}
+
+		ops := specOperations(decodeSpec(t, raw))
+		if len(ops) == 0 {
+			t.Errorf("%s: no operations", group)
+		}
+		for key, op := range ops {
+			if other, dup := seen[key]; dup {
+				t.Errorf("%s appears in both %s and %s", key, other, group)
+			}
+			seen[key] = group
+			path := strings.SplitN(key, " ", 2)[1]
+			if got := operationSpecGroup(path, op); got != group {
+				t.Errorf("%s: %s belongs to group %s", group, key, got)
+			}
+		}
+	}
+	if len(seen) != len(combined) {
+		t.Errorf("groups cover %d operations, combined spec has %d", len(seen), len(combined))
+	}
+}
+
+func TestBuildSwaggerSpecs_ExpectedGroupMembership(t *testing.T) {
+	specs, err := buildSwaggerSpecs(docs.SwaggerJSON, config.ApiDocsConfig{})
+	if err != nil {
+		t.Fatalf("buildSwaggerSpecs: %v", err)
+	}
+	tests := []struct {
+		group, op string
+	}{
+		{specGroupProtocol, "get /v1/modules/{namespace}/{name}/{system}/versions"},
+		{specGroupProtocol, "get /terraform/providers/{hostname}/{namespace}/{type}/index.json"},
+		{specGroupProtocol, "get /.well-known/terraform.json"},
+		{specGroupPublic, "get /health"},
+		{specGroupAdmin, "get /api/v1/users"},
+		{specGroupAdmin, "post /api/v1/admin/users/{id}/erase"},
+	}
+	for _, tt := range tests {
+		ops := specOperations(decodeSpec(t, specs[tt.group]))
+		if _, ok := ops[tt.op]; !ok {
+			t.Errorf("%s missing from %s spec", tt.op, tt.group)
+		}
+	}
+}
+
+func TestBuildSwaggerSpec_MetadataAndErrorEnvelopeOnEveryVariant(t *testing.T) {
+	docsCfg := config.ApiDocsConfig{
+		TermsOfService: "https://example.com/tos",
+		ContactName:    "Registry Team",
+		ContactEmail:   "registry@example.com",
+		License:        "Apache-2.0",
+	}
+	specs, err := buildSwaggerSpecs(docs.SwaggerJSON, docsCfg)
+	if err != nil {
+		t.Fatalf("buildSwaggerSpecs: %v", err)
+	}
+	for group, raw := range specs {
+		doc := decodeSpec(t, raw)
+		info := doc["info"].(map[string]interface{})
+		if info["termsOfService"] != docsCfg.TermsOfService {
+			t.Errorf("group %q: termsOfService = %v", group, info["termsOfService"])
+		}
+		contact := info["contact"].(map[string]interface{})
+		if contact["name"] != docsCfg.ContactName || contact["email"] != docsCfg.ContactEmail {
+			t.Errorf("group %q: contact = %v", group, contact)
+		}
+		if license, _ := info["license"].(map[string]interface{}); license["name"] != docsCfg.License {
+			t.Errorf("group %q: license = %v", group, info["license"])
+		}
+
+		defs := doc["definitions"].(map[string]interface{})
+		if _, ok := defs[errorResponseDefinition]; !ok {
+			t.Errorf("group %q: %s definition missing", group, errorResponseDefinition)
+		}
+		for key, op := range specOperations(doc) {
+			responses, _ := op["responses"].(map[string]interface{})
+			for code, r := range responses {
+				if code[0] != '4' && code[0] != '5' {
+					continue
+				}
+				if isFreeFormObjectSchema(r.(map[string]interface{})["schema"]) {
+					t.Errorf("group %q: %s %s still has an untyped error schema", group, key, code)
+				}
+			}
+		}
+	}
+}
+
+func TestSwaggerJSONRoute_Group(t *testing.T) {
+	specs, err := buildSwaggerSpecs(docs.SwaggerJSON, config.ApiDocsConfig{})
+	if err != nil {
+		t.Fatalf("buildSwaggerSpecs: %v", err)
+	}
+	r := gin.New()
+	registerPublicRoutes(newRouteGroup(r), &publicRouteDeps{cfg: &config.Config{}})
+
+	for _, tc := range []struct {
+		query string
+		want  int
+	}{
+		{"", http.StatusOK},
+		{"?group=protocol", http.StatusOK},
+		{"?group=dev", http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger.json"+tc.query, nil))
+		if w.Code != tc.want {
+			t.Errorf("GET /swagger.json%s = %d, want %d", tc.query, w.Code, tc.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger.json?group=protocol", nil))
+	if w.Body.String() != string(specs[specGroupProtocol]) {
+		t.Error("?group=protocol did not serve the protocol spec")
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/api/artifacthead"
+	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/middleware"
@@ -32,11 +34,37 @@ type Handler struct {
 	repo           *repositories.TerraformMirrorRepository
 	storageBackend storage.Storage
 	auditRepo      *repositories.AuditRepository
+	// broken answers DownloadBinary with 410 for platforms the storage
+	// consistency checker marked broken; nil skips the check.
+	broken BrokenArtifactChecker
+	// headMode is the download_head.mode HEAD requests on DownloadBinary
+	// are answered with.
+	headMode string
+}
+
+// BrokenArtifactChecker reports whether an artifact is marked broken.
+// *repositories.StorageConsistencyRepository satisfies it.
+type BrokenArtifactChecker interface {
+	IsBroken(ctx context.Context, artifactType, artifactID string) (bool, error)
 }
 
 // NewHandler creates a new Handler.
 func NewHandler(repo *repositories.TerraformMirrorRepository, storageBackend storage.Storage, auditRepo *repositories.AuditRepository) *Handler {
-	return &Handler{repo: repo, storageBackend: storageBackend, auditRepo: auditRepo}
+	return &Handler{repo: repo, storageBackend: storageBackend, auditRepo: auditRepo, headMode: config.DownloadHeadMetadata}
+}
+
+// SetHeadMode sets how HEAD requests on DownloadBinary are answered; see
+// config.DownloadHeadConfig.
+func (h *Handler) SetHeadMode(mode string) {
+	if mode != "" {
+		h.headMode = mode
+	}
+}
+
+// SetBrokenChecker enables the 410 response for platforms whose binary the
+// storage consistency checker found missing and marked broken.
+func (h *Handler) SetBrokenChecker(broken BrokenArtifactChecker) {
+	h.broken = broken
 }
 
 // approvalVisible reports whether a version's approval_status permits exposing
@@ -236,7 +264,7 @@ func (h *Handler) GetVersion(c *gin.Context) {
 // ---- GET /terraform/binaries/:name/versions/:version/:os/:arch ---------------------
 
 // @Summary      Download Terraform binary
-// @Description  Returns a signed download URL for the requested Terraform binary. The URL is valid for 15 minutes. Increments the terraform_binary_downloads_total Prometheus counter.
+// @Description  Returns a signed download URL for the requested Terraform binary. The URL is valid for 15 minutes. Increments the terraform_binary_downloads_total Prometheus counter. A HEAD request answers 200 with no body and the archive's Content-Length, ETag (its SHA-256), Last-Modified and Accept-Ranges headers, read from storage metadata.
 // @Tags         Terraform Binaries
 // @Produce      json
 // @Param        name     path  string  true  "Mirror configuration name"
@@ -246,9 +274,11 @@ func (h *Handler) GetVersion(c *gin.Context) {
 // @Success      200  {object}  models.TerraformBinaryDownloadResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid version or platform"
 // @Failure      404  {object}  map[string]interface{}  "Mirror, version, or platform not found"
+// @Failure      410  {object}  map[string]interface{}  "Binary is missing from storage and marked broken"
 // @Failure      503  {object}  map[string]interface{}  "Binary not yet available (sync pending)"
 // @Failure      500  {object}  map[string]interface{}  "Internal server error"
 // @Router       /terraform/binaries/{name}/versions/{version}/{os}/{arch} [get]
+// @Router       /terraform/binaries/{name}/versions/{version}/{os}/{arch} [head]
 func (h *Handler) DownloadBinary(c *gin.Context) {
 	versionStr := c.Param("version")
 	osStr := c.Param("os")
@@ -300,6 +330,25 @@ func (h *Handler) DownloadBinary(c *gin.Context) {
 		return
 	}
 
+	if h.broken != nil {
+		broken, err := h.broken.IsBroken(c.Request.Context(), models.ArtifactTypeTerraformVersionPlatform, platform.ID.String())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check platform state"})
+			return
+		}
+		if broken {
+			c.JSON(http.StatusGone, gin.H{"errors": []string{"Binary is missing from storage; it will be available again after the mirror re-syncs it"}})
+			return
+		}
+	}
+
+	// A HEAD describes the binary instead of returning the download
+	// document, and is not counted as a download.
+	if artifacthead.IsHead(c) {
+		artifacthead.Respond(c, h.storageBackend, h.headMode, *platform.StorageKey, platform.SHA256)
+		return
+	}
+
 	// Generate pre-signed download URL (15-minute TTL)
 	downloadURL, err := h.storageBackend.GetURL(c.Request.Context(), *platform.StorageKey, 15*time.Minute)
 	if err != nil {
@@ -378,8 +427,8 @@ func (h *Handler) DownloadBinary(c *gin.Context) {
 		Version:             version.Version,
 		Filename:            platform.Filename,
 		SHA256:              platform.SHA256,
-		DownloadURL:         downloadURL,
-		ShasumsURL:          shasumsURL,
-		ShasumsSignatureURL: shasumsSignatureURL,
+		DownloadURL:         middleware.TenantURL(c, downloadURL),
+		ShasumsURL:          middleware.TenantURL(c, shasumsURL),
+		ShasumsSignatureURL: middleware.TenantURL(c, shasumsSignatureURL),
 	})
 }
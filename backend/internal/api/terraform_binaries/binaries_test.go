@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +17,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
@@ -93,8 +95,9 @@ func samplePlatformRow(storageKey string) *sqlmock.Rows {
 // ---- mock storage -----------------------------------------------------------
 
 type mockStorage struct {
-	url string
-	err error
+	url  string
+	err  error
+	meta *storage.FileMetadata
 }
 
 func (m *mockStorage) Upload(_ context.Context, _ string, _ io.Reader, _ int64) (*storage.UploadResult, error) {
@@ -104,6 +107,9 @@ func (m *mockStorage) Download(_ context.Context, _ string) (io.ReadCloser, erro
 func (m *mockStorage) Delete(_ context.Context, _ string) error                    { return nil }
 func (m *mockStorage) Exists(_ context.Context, _ string) (bool, error)            { return true, nil }
 func (m *mockStorage) GetMetadata(_ context.Context, _ string) (*storage.FileMetadata, error) {
+	if m.meta != nil {
+		return m.meta, nil
+	}
 	return &storage.FileMetadata{}, nil
 }
 func (m *mockStorage) GetURL(_ context.Context, _ string, _ time.Duration) (string, error) {
@@ -442,6 +448,75 @@ func TestDownloadBinary_Success(t *testing.T) {
 	assert.Equal(t, "", resp["shasums_signature_url"])
 }
 
+// A HEAD describes the binary from storage metadata, with the platform's
+// SHA-256 as the ETag, instead of returning the download document.
+func TestDownloadBinary_Head(t *testing.T) {
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &mockStorage{err: errors.New("no URL for HEAD"), meta: &storage.FileMetadata{Size: 27000000, LastModified: modTime}}
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	h := NewHandler(repositories.NewTerraformMirrorRepository(sqlx.NewDb(db, "postgres")), store, nil)
+	r := gin.New()
+	r.HEAD("/:name/versions/:version/:os/:arch", h.DownloadBinary)
+
+	mock.ExpectQuery(`SELECT.*FROM terraform_mirror_configs.*WHERE name`).
+		WithArgs(sampleConfigName).
+		WillReturnRows(sampleConfigRow())
+	mock.ExpectQuery(`SELECT.*FROM terraform_versions.*WHERE config_id.*version`).
+		WithArgs(sampleConfigID, "1.9.0").
+		WillReturnRows(sampleVersionRow("1.9.0", true))
+	mock.ExpectQuery(`SELECT.*FROM terraform_version_platforms.*WHERE version_id.*os.*arch`).
+		WithArgs(sampleVersionID, "linux", "amd64").
+		WillReturnRows(samplePlatformRow("tf/1.9.0/linux_amd64.zip"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/"+sampleConfigName+"/versions/1.9.0/linux/amd64", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Zero(t, w.Body.Len())
+	assert.Equal(t, "27000000", w.Header().Get("Content-Length"))
+	assert.Equal(t, `"abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"`, w.Header().Get("ETag"))
+	assert.Equal(t, modTime.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+	assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+}
+
+type fakeBrokenChecker struct{ artifactType, artifactID string }
+
+func (f *fakeBrokenChecker) IsBroken(_ context.Context, artifactType, artifactID string) (bool, error) {
+	f.artifactType, f.artifactID = artifactType, artifactID
+	return true, nil
+}
+
+func TestDownloadBinary_MarkedBroken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	checker := &fakeBrokenChecker{}
+	h := NewHandler(repositories.NewTerraformMirrorRepository(sqlx.NewDb(db, "postgres")), &mockStorage{url: "https://example.com/download"}, nil)
+	h.SetBrokenChecker(checker)
+	r := gin.New()
+	r.GET("/:name/versions/:version/:os/:arch", h.DownloadBinary)
+
+	mock.ExpectQuery(`SELECT.*FROM terraform_mirror_configs.*WHERE name`).
+		WithArgs(sampleConfigName).
+		WillReturnRows(sampleConfigRow())
+	mock.ExpectQuery(`SELECT.*FROM terraform_versions.*WHERE config_id.*version`).
+		WithArgs(sampleConfigID, "1.9.0").
+		WillReturnRows(sampleVersionRow("1.9.0", true))
+	mock.ExpectQuery(`SELECT.*FROM terraform_version_platforms.*WHERE version_id.*os.*arch`).
+		WithArgs(sampleVersionID, "linux", "amd64").
+		WillReturnRows(samplePlatformRow("tf/1.9.0/linux_amd64.zip"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+sampleConfigName+"/versions/1.9.0/linux/amd64", nil))
+
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Equal(t, models.ArtifactTypeTerraformVersionPlatform, checker.artifactType)
+	assert.NotEmpty(t, checker.artifactID)
+}
+
 func TestDownloadBinary_ReturnsSignatureURLsWhenStored(t *testing.T) {
 	// Verifies the fix for #402: when the sync job has stored both the
 	// SHA256SUMS file and its detached GPG signature for a version, the
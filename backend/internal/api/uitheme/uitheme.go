@@ -15,6 +15,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 )
 
 // Handlers holds the UI theme endpoints.
@@ -28,7 +29,7 @@ func NewHandlers(db *sqlx.DB) *Handlers {
 }
 
 // @Summary      Get UI theme configuration
-// @Description  Returns the white-label theme configuration consumed by the frontend ThemeContext. Public — no authentication required so the login page can brand itself before sign-in. Returns 404 when nothing has been configured; the frontend then falls back to its built-in defaults.
+// @Description  Returns the white-label theme configuration consumed by the frontend ThemeContext. Public — no authentication required so the login page can brand itself before sign-in. On a custom tenant domain the domain's branding (product name, primary color, logo and favicon) is laid over the global theme. Returns 404 when nothing has been configured; the frontend then falls back to its built-in defaults.
 // @Tags         UI Theme
 // @Produce      json
 // @Success      200  {object}  models.UIThemeConfig
@@ -42,6 +43,12 @@ func (h *Handlers) GetTheme() gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load ui theme"})
 			return
 		}
+		// The theme depends on the tenant domain, so shared caches must key
+		// it by host.
+		c.Header("Vary", "Host")
+		if t := middleware.TenantFromContext(c); t != nil && t.Domain.HasBranding() {
+			cfg = t.Domain.Brand(cfg)
+		}
 		if cfg == nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "ui theme not configured"})
 			return
@@ -77,7 +84,7 @@ func (h *Handlers) PutTheme() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		if err := validateTheme(&in); err != nil {
+		if err := ValidateTheme(&in); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -101,7 +108,9 @@ var (
 	errBadURL = errors.New("must be an https:// URL or a relative path starting with /")
 )
 
-func validateTheme(in *models.UIThemeConfig) error {
+// ValidateTheme checks a theme's colors, URLs and product name. It is shared
+// with the tenant domain branding, which takes the same values.
+func ValidateTheme(in *models.UIThemeConfig) error {
 	colorFields := []struct {
 		name string
 		val  *string
@@ -2,6 +2,7 @@ package uitheme
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
@@ -12,7 +13,9 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 )
 
 func newTestRouter(t *testing.T) (*Handlers, *gin.Engine, sqlmock.Sqlmock) {
@@ -73,6 +76,53 @@ func TestGetTheme_Success(t *testing.T) {
 	}
 }
 
+type tenantDomainList []*models.TenantDomain
+
+func (l tenantDomainList) List(context.Context) ([]*models.TenantDomain, error) { return l, nil }
+
+func TestGetTheme_TenantBranding(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	name, color := "Acme Red Modules", "#cc0000"
+	domains := middleware.NewTenantDomains(tenantDomainList{
+		{Hostname: "modules.acme-red.example.com", OrganizationID: "org-red", ProductName: &name, PrimaryColor: &color},
+	}, time.Minute)
+	r := gin.New()
+	r.Use(middleware.TenantMiddleware(domains, &config.ServerConfig{BaseURL: "https://registry.example.com"}))
+	r.GET("/ui/theme", NewHandlers(sqlx.NewDb(db, "postgres")).GetTheme())
+
+	// No global theme: the tenant branding alone is served instead of a 404.
+	mock.ExpectQuery(`SELECT.*FROM ui_theme_config`).WillReturnError(sqlNoRows())
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/ui/theme", nil)
+	req.Host = "modules.acme-red.example.com"
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp models.UIThemeConfig
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.ProductName == nil || *resp.ProductName != name || resp.PrimaryColor == nil || *resp.PrimaryColor != color {
+		t.Errorf("theme = %+v, want the tenant's name and color", resp)
+	}
+	if w.Header().Get("Vary") != "Host" {
+		t.Errorf("Vary = %q, want Host", w.Header().Get("Vary"))
+	}
+
+	// Any other host still gets the plain 404.
+	mock.ExpectQuery(`SELECT.*FROM ui_theme_config`).WillReturnError(sqlNoRows())
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/ui/theme", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("other host: status = %d, want 404", w.Code)
+	}
+}
+
 func TestGetTheme_DBError(t *testing.T) {
 	_, r, mock := newTestRouter(t)
 	mock.ExpectQuery(`SELECT.*FROM ui_theme_config`).
@@ -197,9 +247,9 @@ func TestValidateTheme(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validateTheme(&tc.in)
+			err := ValidateTheme(&tc.in)
 			if (err != nil) != tc.wantErr {
-				t.Errorf("validateTheme err = %v, wantErr %v", err, tc.wantErr)
+				t.Errorf("ValidateTheme err = %v, wantErr %v", err, tc.wantErr)
 			}
 		})
 	}
@@ -0,0 +1,26 @@
+// webhook_verification.go serves GET /api/v1/webhooks/verification-info: the
+// unauthenticated recipe receivers follow to verify outbound webhook
+// signatures and payload schema versions.
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+// @Summary      Webhook verification recipe
+// @Description  Describes how outbound webhooks (organization event webhooks and pre-publish hooks) are signed and versioned: the signature algorithm, every header sent, the verification steps, how a secret rotation shows in the headers, and the current schema version and event types of each payload. Needs no authentication and holds nothing organization-specific.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object}  services.WebhookVerificationInfo
+// @Router       /api/v1/webhooks/verification-info [get]
+// webhookVerificationInfoHandler serves GET /api/v1/webhooks/verification-info.
+func webhookVerificationInfoHandler() gin.HandlerFunc {
+	info := services.WebhookVerification()
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, info)
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+func TestWebhookVerificationInfoHandler(t *testing.T) {
+	r := gin.New()
+	r.GET("/api/v1/webhooks/verification-info", webhookVerificationInfoHandler())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/verification-info", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var info services.WebhookVerificationInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if info.Algorithm != "HMAC-SHA256" || len(info.Steps) == 0 || len(info.Payloads) != 2 {
+		t.Errorf("info = %+v", info)
+	}
+	headers := map[string]bool{}
+	for _, h := range info.Headers {
+		headers[h.Name] = true
+	}
+	for _, name := range []string{services.PublishHookSignatureHeader, services.OrgEventSignatureKeyIDHeader, services.OrgEventSignaturesHeader} {
+		if !headers[name] {
+			t.Errorf("headers do not describe %s", name)
+		}
+	}
+}
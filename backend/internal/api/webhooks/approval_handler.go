@@ -14,11 +14,15 @@ import (
 	"github.com/google/uuid"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 )
 
 // ApprovalHandler handles webhook-based approval token redemption.
 type ApprovalHandler struct {
 	rbacRepo *repositories.RBACRepository
+	// approvals applies auto_apply approvals to the mirror's filters. When
+	// nil, redemption only changes the request's status.
+	approvals *services.MirrorApprovalProvisioner
 }
 
 // NewApprovalHandler creates a new ApprovalHandler.
@@ -26,6 +30,13 @@ func NewApprovalHandler(rbacRepo *repositories.RBACRepository) *ApprovalHandler
 	return &ApprovalHandler{rbacRepo: rbacRepo}
 }
 
+// WithApprovalProvisioner wires in the provisioner that applies auto_apply
+// approvals. Returns the handler for chaining.
+func (h *ApprovalHandler) WithApprovalProvisioner(p *services.MirrorApprovalProvisioner) *ApprovalHandler {
+	h.approvals = p
+	return h
+}
+
 // @Summary      Redeem approval token
 // @Description  Redeems a single-use approval token generated via the admin API. When valid,
 //
@@ -64,8 +75,15 @@ func (h *ApprovalHandler) RedeemApprovalToken(c *gin.Context) {
 	}
 
 	// Approve the associated request with a system/zero reviewer UUID.
-	if err := h.rbacRepo.UpdateApprovalStatus(c.Request.Context(), approvalID,
-		models.ApprovalStatusApproved, uuid.Nil, "Approved via single-use webhook token"); err != nil {
+	const notes = "Approved via single-use webhook token"
+	if h.approvals != nil {
+		if _, err := h.approvals.Review(c.Request.Context(), approvalID,
+			models.ApprovalStatusApproved, uuid.Nil, notes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update approval status"})
+			return
+		}
+	} else if err := h.rbacRepo.UpdateApprovalStatus(c.Request.Context(), approvalID,
+		models.ApprovalStatusApproved, uuid.Nil, notes); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update approval status"})
 		return
 	}
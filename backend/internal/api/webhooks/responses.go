@@ -4,4 +4,9 @@ package webhooks
 type WebhookReceivedResponse struct {
 	Message string `json:"message"`
 	LogID   string `json:"log_id"`
+	// PublishID is the publish queued by a tag push, if any.
+	PublishID string `json:"publish_id,omitempty"`
+	// Duplicate is set when the delivery was already received; LogID and
+	// PublishID then identify the original event and publish.
+	Duplicate bool `json:"duplicate,omitempty"`
 }
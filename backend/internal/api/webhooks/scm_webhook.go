@@ -5,60 +5,71 @@
 package webhooks
 
 import (
-	"context"
-	"crypto/subtle"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"path"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
-	"github.com/terraform-registry/terraform-registry/internal/safego"
 	"github.com/terraform-registry/terraform-registry/internal/scm"
-	"github.com/terraform-registry/terraform-registry/internal/services"
 )
 
 // SCMWebhookHandler handles incoming SCM webhooks
 type SCMWebhookHandler struct {
-	scmRepo     *repositories.SCMRepository
-	publisher   *services.SCMPublisher
-	connectors  map[scm.ProviderType]scm.Connector
-	tokenCipher *crypto.TokenCipher
+	scmRepo            *repositories.SCMRepository
+	connectors         map[scm.ProviderType]scm.Connector
+	tokenCipher        *crypto.TokenCipher
+	maxPublishAttempts int
 }
 
-// NewSCMWebhookHandler creates a new webhook handler
-func NewSCMWebhookHandler(scmRepo *repositories.SCMRepository, publisher *services.SCMPublisher, tokenCipher *crypto.TokenCipher) *SCMWebhookHandler {
+// NewSCMWebhookHandler creates a new webhook handler. Tag publishes are
+// queued for the scm-publish-queue job and attempted once unless
+// WithPublishRetries is set.
+func NewSCMWebhookHandler(scmRepo *repositories.SCMRepository, tokenCipher *crypto.TokenCipher) *SCMWebhookHandler {
 	return &SCMWebhookHandler{
-		scmRepo:     scmRepo,
-		publisher:   publisher,
-		connectors:  make(map[scm.ProviderType]scm.Connector),
-		tokenCipher: tokenCipher,
+		scmRepo:            scmRepo,
+		connectors:         make(map[scm.ProviderType]scm.Connector),
+		tokenCipher:        tokenCipher,
+		maxPublishAttempts: 1,
 	}
 }
 
+// WithPublishRetries lets each queued publish be retried maxRetries times
+// after its first attempt fails (webhooks.max_retries).
+func (h *SCMWebhookHandler) WithPublishRetries(maxRetries int) *SCMWebhookHandler {
+	if maxRetries > 0 {
+		h.maxPublishAttempts = maxRetries + 1
+	}
+	return h
+}
+
 // @Summary      Receive SCM webhook
 // @Description  Receives and processes incoming webhook events from SCM providers (GitHub, GitLab, Azure DevOps, Bitbucket).
-// @Description  Two-layer security is applied: the URL-embedded secret (last path segment of the registered callback URL)
-// @Description  is verified first with a constant-time comparison, and then the provider's HMAC payload signature is
-// @Description  validated against the stored webhook secret. Both checks must pass before the payload is processed.
-// @Description  Accepted events are logged. Tag-push events trigger asynchronous auto-publish when AutoPublish is enabled.
+// @Description  Deliveries are authenticated by the provider's payload signature (HMAC or token header), verified against
+// @Description  the link's webhook secret, or the SCM provider's secret for links whose secret was never rotated. During
+// @Description  the grace period after POST /api/v1/admin/modules/{id}/scm/rotate-webhook-secret the replaced secret is
+// @Description  accepted too. This secretless URL is only accepted for providers that sign deliveries and have a secret
+// @Description  configured. Accepted events are logged. When AutoPublish is enabled a tag-push event queues a publish, whose ID is returned
+// @Description  as publish_id, and the delivery is acknowledged without waiting for it; poll GET /api/v1/admin/modules/{id}/scm/publishes/{publish_id}
+// @Description  or the event log for the outcome. A redelivery of a delivery already received (same X-GitHub-Delivery,
+// @Description  X-Gitlab-Event-UUID or Bitbucket X-Request-Id) is not logged or published again: the response repeats the
+// @Description  original log_id and publish_id with duplicate=true.
 // @Tags         Webhooks
 // @Accept       json
 // @Produce      json
 // @Param        module_source_repo_id  path  string  true  "Module source repository link ID (UUID) — uniquely identifies the SCM-to-module mapping"
-// @Param        secret                 path  string  true  "URL-embedded webhook secret generated at link time; used as a first-line constant-time guard before HMAC validation"
 // @Success      200  {object}  webhooks.WebhookReceivedResponse
 // @Failure      400  {object}  map[string]interface{}  "Invalid repository ID or malformed/unreadable payload"
-// @Failure      401  {object}  map[string]interface{}  "URL secret mismatch or HMAC payload signature invalid"
+// @Failure      401  {object}  map[string]interface{}  "Payload signature missing or invalid"
 // @Failure      404  {object}  map[string]interface{}  "Repository link or SCM provider not found"
-// @Failure      500  {object}  map[string]interface{}  "Internal server error (connector build, log write, etc.)"
-// @Router       /webhooks/scm/{module_source_repo_id}/{secret} [post]
+// @Failure      500  {object}  map[string]interface{}  "Internal server error (connector build, log write, queue write, etc.)"
+// @Router       /webhooks/scm/{module_source_repo_id} [post]
 // HandleWebhook processes incoming webhooks from SCM providers
-// POST /webhooks/scm/:module_source_repo_id/:secret
+// POST /webhooks/scm/:module_source_repo_id
 func (h *SCMWebhookHandler) HandleWebhook(c *gin.Context) {
 	repoIDStr := c.Param("module_source_repo_id")
 	requestSecret := c.Param("secret")
@@ -90,14 +101,16 @@ func (h *SCMWebhookHandler) HandleWebhook(c *gin.Context) {
 		return
 	}
 
-	// Verify the URL-embedded secret to ensure the request came from the correct webhook endpoint.
-	// The full webhook URL is stored in WebhookURL; its last path segment is the secret.
+	// Every link is given a callback URL when it is created.
 	if moduleSourceRepo.WebhookURL == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "webhook URL not configured"})
 		return
 	}
-	storedSecret := path.Base(*moduleSourceRepo.WebhookURL)
-	if subtle.ConstantTimeCompare([]byte(storedSecret), []byte(requestSecret)) != 1 {
+
+	// Legacy callback URLs embed a secret as their last path segment. It is no
+	// longer required, but when present it must still be one the link accepts.
+	now := time.Now()
+	if requestSecret != "" && !moduleSourceRepo.AcceptsPathSecret(requestSecret, now) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook secret"})
 		return
 	}
@@ -113,6 +126,14 @@ func (h *SCMWebhookHandler) HandleWebhook(c *gin.Context) {
 		return
 	}
 
+	// Without the path secret the payload signature is the only credential, so
+	// the provider must sign deliveries and the link must have a secret.
+	secrets := moduleSourceRepo.DeliverySecrets(provider.WebhookSecret, now)
+	if requestSecret == "" && (!provider.ProviderType.SignsDeliveries() || len(secrets) == 0) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "webhook secret required: this link has no signing secret, use its full callback URL"})
+		return
+	}
+
 	// Build connector for this provider
 	baseURL := ""
 	if provider.BaseURL != nil {
@@ -143,9 +164,14 @@ func (h *SCMWebhookHandler) HandleWebhook(c *gin.Context) {
 		}
 	}
 
-	// Verify webhook signature
+	// Verify webhook signature against every secret the link currently accepts.
 	signatureHeader := h.getSignatureHeader(c.Request, provider.ProviderType)
-	if !connector.VerifyDeliverySignature(payloadBytes, signatureHeader, provider.WebhookSecret) {
+	if len(secrets) == 0 {
+		// Legacy link on a provider without a secret: the connector decides
+		// whether unsigned deliveries are acceptable.
+		secrets = []string{""}
+	}
+	if !verifyDelivery(connector, payloadBytes, signatureHeader, secrets) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook signature"})
 		return
 	}
@@ -157,46 +183,126 @@ func (h *SCMWebhookHandler) HandleWebhook(c *gin.Context) {
 		return
 	}
 
-	// Log the webhook event
+	// Log the webhook event. GitLab's token header is the secret itself.
+	loggedSignature := signatureHeader
+	if provider.ProviderType == scm.ProviderGitLab && loggedSignature != "" {
+		loggedSignature = redactedHeaderValue
+	}
 	logID := uuid.New()
 	validSig := true
+	var deliveryID *string
+	if id := h.getDeliveryID(c.Request, provider.ProviderType); id != "" {
+		deliveryID = &id
+	}
 	webhookLog := &scm.SCMWebhookLogRecord{
 		ID:              logID,
 		ModuleSCMRepoID: repoID,
 		EventID:         &hook.ID,
+		DeliveryID:      deliveryID,
 		EventType:       hook.Type,
 		Ref:             &hook.Ref,
 		CommitSHA:       &hook.CommitSHA,
 		TagName:         &hook.TagName,
 		Payload:         hook.Payload,
 		Headers:         convertHeaders(headers),
-		Signature:       &signatureHeader,
+		Signature:       &loggedSignature,
 		SignatureValid:  &validSig,
 		Processed:       false,
 		CreatedAt:       time.Now(),
 	}
 
 	if err := h.scmRepo.CreateWebhookLog(c.Request.Context(), webhookLog); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateWebhookDelivery) {
+			h.respondDuplicateDelivery(c, repoID, *deliveryID)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log webhook"})
 		return
 	}
 
-	// Process the webhook asynchronously if it's a tag push.
-	// Use a detached context because the request context is cancelled as soon as
-	// the HTTP response is sent, but the publishing work (SCM download, storage
-	// upload, DB writes) can take minutes.
+	// Queue the publish of a tag push rather than running it here: packaging
+	// a large module can outlast the provider's delivery timeout, and the
+	// queue survives a restart.
+	resp := WebhookReceivedResponse{Message: "webhook received", LogID: logID.String()}
 	if hook.IsTagEvent() && moduleSourceRepo.AutoPublish {
-		asyncCtx, asyncCancel := context.WithTimeout(context.Background(), 10*time.Minute) // #nosec G118 -- asyncCancel is called via defer inside the goroutine closure below
-		msr := moduleSourceRepo
-		h2 := hook
-		conn := connector
-		safego.Go(func() {
-			defer asyncCancel()
-			h.publisher.ProcessTagPush(asyncCtx, logID, msr, h2, conn)
-		})
+		task := &scm.PublishTask{
+			ModuleSCMRepoID: repoID,
+			WebhookEventID:  &logID,
+			DeliveryID:      deliveryID,
+			TagName:         hook.TagName,
+			MaxAttempts:     h.maxPublishAttempts,
+		}
+		if hook.CommitSHA != "" {
+			task.CommitSHA = &hook.CommitSHA
+		}
+		if err := h.scmRepo.EnqueuePublishTask(c.Request.Context(), task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue publish"})
+			return
+		}
+		resp.PublishID = task.ID.String()
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "webhook received", "log_id": logID})
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Receive SCM webhook (deprecated URL-embedded secret)
+// @Description  Legacy form of POST /webhooks/scm/{module_source_repo_id} for callback URLs that embed a secret in the
+// @Description  path. The path secret must match the link's webhook URL (or, during a rotation grace period, the URL it
+// @Description  replaced) and the payload signature is then verified as on the secretless route. Kept so existing links
+// @Description  keep working until their secret is rotated; the path secret is redacted from request logs.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        module_source_repo_id  path  string  true  "Module source repository link ID (UUID) — uniquely identifies the SCM-to-module mapping"
+// @Param        secret                 path  string  true  "URL-embedded webhook secret from the link's callback URL"
+// @Success      200  {object}  webhooks.WebhookReceivedResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid repository ID or malformed/unreadable payload"
+// @Failure      401  {object}  map[string]interface{}  "URL secret mismatch or HMAC payload signature invalid"
+// @Failure      404  {object}  map[string]interface{}  "Repository link or SCM provider not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error (connector build, log write, etc.)"
+// @Deprecated
+// @Router       /webhooks/scm/{module_source_repo_id}/{secret} [post]
+// HandleLegacyWebhook processes webhooks sent to a callback URL that embeds a
+// secret
+// POST /webhooks/scm/:module_source_repo_id/:secret
+func (h *SCMWebhookHandler) HandleLegacyWebhook(c *gin.Context) {
+	h.HandleWebhook(c)
+}
+
+// respondDuplicateDelivery answers a redelivery of a delivery the link already
+// logged with the original outcome: its event log entry and the publish it
+// queued, if any. Nothing is logged or queued again.
+func (h *SCMWebhookHandler) respondDuplicateDelivery(c *gin.Context, repoID uuid.UUID, deliveryID string) {
+	original, err := h.scmRepo.GetWebhookLogByDeliveryID(c.Request.Context(), repoID, deliveryID)
+	if err != nil || original == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get original delivery"})
+		return
+	}
+	resp := WebhookReceivedResponse{
+		Message:   "duplicate delivery: already received",
+		LogID:     original.ID.String(),
+		Duplicate: true,
+	}
+	tasks, err := h.scmRepo.ListPublishTasksForEvents(c.Request.Context(), []uuid.UUID{original.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get original publish"})
+		return
+	}
+	if task, ok := tasks[original.ID]; ok {
+		resp.PublishID = task.ID.String()
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// verifyDelivery reports whether the delivery's signature verifies against any
+// of secrets.
+func verifyDelivery(connector scm.Connector, payload []byte, signatureHeader string, secrets []string) bool {
+	for _, secret := range secrets {
+		if connector.VerifyDeliverySignature(payload, signatureHeader, secret) {
+			return true
+		}
+	}
+	return false
 }
 
 func (h *SCMWebhookHandler) getSignatureHeader(req *http.Request, providerType scm.ProviderType) string {
@@ -214,6 +320,22 @@ func (h *SCMWebhookHandler) getSignatureHeader(req *http.Request, providerType s
 	}
 }
 
+// getDeliveryID returns the provider's ID for the delivery, which its webhook
+// delivery log shows and a redelivery repeats, or "" when the provider sends
+// none (Azure DevOps).
+func (h *SCMWebhookHandler) getDeliveryID(req *http.Request, providerType scm.ProviderType) string {
+	switch providerType {
+	case scm.ProviderGitHub:
+		return req.Header.Get("X-GitHub-Delivery")
+	case scm.ProviderGitLab:
+		return req.Header.Get("X-Gitlab-Event-UUID")
+	case scm.ProviderBitbucketDC:
+		return req.Header.Get("X-Request-Id")
+	default:
+		return ""
+	}
+}
+
 func formatHeaders(headers map[string]string) string {
 	// Convert headers map to JSON string for storage
 	result := ""
@@ -226,9 +348,20 @@ func formatHeaders(headers map[string]string) string {
 	return result
 }
 
+// secretWebhookHeaders are delivery headers that carry the webhook secret
+// verbatim rather than a signature of the payload.
+var secretWebhookHeaders = map[string]bool{
+	"X-Gitlab-Token": true,
+}
+
+const redactedHeaderValue = "[REDACTED]"
+
 func convertHeaders(headers map[string]string) map[string]interface{} {
 	result := make(map[string]interface{})
 	for key, value := range headers {
+		if secretWebhookHeaders[http.CanonicalHeaderKey(key)] {
+			value = redactedHeaderValue
+		}
 		result[key] = value
 	}
 	return result
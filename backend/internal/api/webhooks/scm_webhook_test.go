@@ -108,10 +108,11 @@ func newWebhookRouter(t *testing.T) (sqlmock.Sqlmock, *gin.Engine) {
 
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	scmRepo := repositories.NewSCMRepository(sqlxDB)
-	h := NewSCMWebhookHandler(scmRepo, nil, testTokenCipher(t)) // nil publisher OK for early-exit tests
+	h := NewSCMWebhookHandler(scmRepo, testTokenCipher(t))
 
 	r := gin.New()
-	r.POST("/webhooks/scm/:module_source_repo_id/:secret", h.HandleWebhook)
+	r.POST("/webhooks/scm/:module_source_repo_id", h.HandleWebhook)
+	r.POST("/webhooks/scm/:module_source_repo_id/:secret", h.HandleLegacyWebhook)
 	return mock, r
 }
 
@@ -429,6 +430,16 @@ func TestConvertHeaders_WithEntries(t *testing.T) {
 	}
 }
 
+func TestConvertHeaders_RedactsSecretHeaders(t *testing.T) {
+	result := convertHeaders(map[string]string{"X-Gitlab-Token": "gl-secret", "X-Gitlab-Event": "Tag Push Hook"})
+	if v := result["X-Gitlab-Token"]; v != "[REDACTED]" {
+		t.Errorf("result[X-Gitlab-Token] = %v, want [REDACTED]", v)
+	}
+	if v := result["X-Gitlab-Event"]; v != "Tag Push Hook" {
+		t.Errorf("result[X-Gitlab-Event] = %v, want Tag Push Hook", v)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // HandleWebhook — post-signature paths (ParseDelivery, CreateWebhookLog, success)
 // ---------------------------------------------------------------------------
@@ -534,3 +545,223 @@ func TestWebhook_Success(t *testing.T) {
 		t.Errorf("status = %d, want 200: body=%s", w.Code, w.Body.String())
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Secretless callback URL and secret rotation
+// ---------------------------------------------------------------------------
+
+// sampleRotatedModuleSourceRepoRow returns a link whose webhook secret was
+// rotated, replacing the provider's secret and previousURL until graceUntil.
+func sampleRotatedModuleSourceRepoRow(scmProviderID uuid.UUID, webhookURL, secret, previousURL string, graceUntil time.Time) *sqlmock.Rows {
+	cols := append(append([]string{}, moduleSourceRepoCols...),
+		"webhook_secret", "previous_webhook_secret", "previous_webhook_url", "webhook_secret_grace_until")
+	return sqlmock.NewRows(cols).AddRow(
+		uuid.MustParse(webhookTestUUID), uuid.New(), scmProviderID,
+		"my-org", "my-repo", nil,
+		"main", "", "v*",
+		false, nil, webhookURL,
+		false, nil, nil,
+		time.Now(), time.Now(),
+		secret, nil, previousURL, graceUntil,
+	)
+}
+
+func TestWebhook_Secretless_Success(t *testing.T) {
+	mock, r := newWebhookRouter(t)
+	providerID := uuid.New()
+	payload := []byte(`{}`)
+
+	mock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE id").
+		WillReturnRows(sampleModuleSourceRepoRowWithURL(providerID,
+			"https://registry.example.com/webhooks/scm/"+webhookTestUUID))
+	mock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+		WillReturnRows(sampleProviderRowWithSecret(t, providerID, "bitbucket_dc", testWebhookSecret))
+	mock.ExpectExec("INSERT INTO scm_webhook_events").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req := httptest.NewRequest("POST", "/webhooks/scm/"+webhookTestUUID, bytes.NewReader(payload))
+	req.Header.Set("X-Hub-Signature", bbHMAC(payload, testWebhookSecret))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+}
+
+// TestWebhook_TagPushQueuesPublish — a tag push on an auto-publish link is
+// queued for the publish queue job and acknowledged with the task ID.
+func TestWebhook_TagPushQueuesPublish(t *testing.T) {
+	mock, r := newWebhookRouter(t)
+	providerID, taskID := uuid.New(), uuid.New()
+	payload := []byte(`{"eventKey":"repo:refs_changed","changes":[{"ref":{"id":"refs/tags/v1.2.0","displayId":"v1.2.0","type":"TAG"},"toHash":"abc123"}]}`)
+
+	mock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE id").
+		WillReturnRows(sqlmock.NewRows(moduleSourceRepoCols).AddRow(
+			uuid.MustParse(webhookTestUUID), uuid.New(), providerID,
+			"my-org", "my-repo", nil,
+			"main", "", "v*",
+			true, nil, "https://registry.example.com/webhooks/scm/"+webhookTestUUID,
+			true, nil, nil,
+			time.Now(), time.Now(),
+		))
+	mock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+		WillReturnRows(sampleProviderRowWithSecret(t, providerID, "bitbucket_dc", testWebhookSecret))
+	mock.ExpectExec("INSERT INTO scm_webhook_events").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO scm_publish_tasks").
+		WithArgs(uuid.MustParse(webhookTestUUID), sqlmock.AnyArg(), "v1.2.0", sqlmock.AnyArg(), 1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "next_attempt_at", "created_at", "updated_at"}).
+			AddRow(taskID, "queued", time.Now(), time.Now(), time.Now()))
+
+	req := httptest.NewRequest("POST", "/webhooks/scm/"+webhookTestUUID, bytes.NewReader(payload))
+	req.Header.Set("X-Hub-Signature", bbHMAC(payload, testWebhookSecret))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"publish_id":"`+taskID.String()+`"`)) {
+		t.Errorf("body = %s, want publish_id %s", w.Body.String(), taskID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestWebhook_RedeliveryReturnsOriginalOutcome — a redelivery (same
+// X-Request-Id) is neither logged nor queued again; the response repeats the
+// original event and publish.
+func TestWebhook_RedeliveryReturnsOriginalOutcome(t *testing.T) {
+	mock, r := newWebhookRouter(t)
+	providerID, originalID, taskID := uuid.New(), uuid.New(), uuid.New()
+	delivery := "d0e6b8a4-5d7c-4b1e-9d57-1a2b3c4d5e6f"
+	payload := []byte(`{"eventKey":"repo:refs_changed","changes":[{"ref":{"id":"refs/tags/v1.2.0","displayId":"v1.2.0","type":"TAG"},"toHash":"abc123"}]}`)
+
+	mock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE id").
+		WillReturnRows(sqlmock.NewRows(moduleSourceRepoCols).AddRow(
+			uuid.MustParse(webhookTestUUID), uuid.New(), providerID,
+			"my-org", "my-repo", nil,
+			"main", "", "v*",
+			true, nil, "https://registry.example.com/webhooks/scm/"+webhookTestUUID,
+			true, nil, nil,
+			time.Now(), time.Now(),
+		))
+	mock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+		WillReturnRows(sampleProviderRowWithSecret(t, providerID, "bitbucket_dc", testWebhookSecret))
+	mock.ExpectExec("INSERT INTO scm_webhook_events.*ON CONFLICT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT.*FROM scm_webhook_events WHERE module_scm_repo_id = \\$1 AND delivery_id = \\$2").
+		WithArgs(uuid.MustParse(webhookTestUUID), delivery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "module_scm_repo_id", "delivery_id", "event_type", "processed", "created_at"}).
+			AddRow(originalID, uuid.MustParse(webhookTestUUID), delivery, "push", false, time.Now()))
+	mock.ExpectQuery("SELECT.*FROM scm_publish_tasks WHERE webhook_event_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "module_scm_repo_id", "webhook_event_id", "tag_name", "status"}).
+			AddRow(taskID, uuid.MustParse(webhookTestUUID), originalID, "v1.2.0", "running"))
+
+	req := httptest.NewRequest("POST", "/webhooks/scm/"+webhookTestUUID, bytes.NewReader(payload))
+	req.Header.Set("X-Hub-Signature", bbHMAC(payload, testWebhookSecret))
+	req.Header.Set("X-Request-Id", delivery)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+	for _, want := range []string{`"log_id":"` + originalID.String() + `"`, `"publish_id":"` + taskID.String() + `"`, `"duplicate":true`} {
+		if !bytes.Contains(w.Body.Bytes(), []byte(want)) {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestWebhook_Secretless_RequiresSigningSecret(t *testing.T) {
+	tests := []struct {
+		name         string
+		providerType string
+		secret       string
+	}{
+		{"provider without secret", "github", ""},
+		{"provider that does not sign", "azuredevops", testWebhookSecret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, r := newWebhookRouter(t)
+			providerID := uuid.New()
+			mock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE id").
+				WillReturnRows(sampleModuleSourceRepoRowWithURL(providerID,
+					"https://registry.example.com/webhooks/scm/"+webhookTestUUID+"/secret123"))
+			mock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+				WillReturnRows(sampleProviderRowWithSecret(t, providerID, tt.providerType, tt.secret))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks/scm/"+webhookTestUUID, bytes.NewReader([]byte(`{}`))))
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401: body=%s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhook_RotationGracePeriod(t *testing.T) {
+	const rotated = "rotated-link-secret"
+	secretless := "https://registry.example.com/webhooks/scm/" + webhookTestUUID
+	legacy := secretless + "/secret123"
+	payload := []byte(`{}`)
+
+	tests := []struct {
+		name       string
+		path       string
+		signWith   string
+		graceUntil time.Time
+		want       int
+	}{
+		{"new secret", "/webhooks/scm/" + webhookTestUUID, rotated, time.Now().Add(time.Hour), http.StatusOK},
+		{"replaced secret during grace", "/webhooks/scm/" + webhookTestUUID, testWebhookSecret, time.Now().Add(time.Hour), http.StatusOK},
+		{"replaced secret after grace", "/webhooks/scm/" + webhookTestUUID, testWebhookSecret, time.Now().Add(-time.Hour), http.StatusUnauthorized},
+		{"replaced URL during grace", "/webhooks/scm/" + webhookTestUUID + "/secret123", testWebhookSecret, time.Now().Add(time.Hour), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, r := newWebhookRouter(t)
+			providerID := uuid.New()
+			mock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE id").
+				WillReturnRows(sampleRotatedModuleSourceRepoRow(providerID, secretless, rotated, legacy, tt.graceUntil))
+			mock.ExpectQuery("SELECT.*FROM scm_providers WHERE id").
+				WillReturnRows(sampleProviderRowWithSecret(t, providerID, "bitbucket_dc", testWebhookSecret))
+			if tt.want == http.StatusOK {
+				mock.ExpectExec("INSERT INTO scm_webhook_events").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			req := httptest.NewRequest("POST", tt.path, bytes.NewReader(payload))
+			req.Header.Set("X-Hub-Signature", bbHMAC(payload, tt.signWith))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: body=%s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhook_ReplacedURLAfterGrace(t *testing.T) {
+	mock, r := newWebhookRouter(t)
+	providerID := uuid.New()
+	secretless := "https://registry.example.com/webhooks/scm/" + webhookTestUUID
+	mock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE id").
+		WillReturnRows(sampleRotatedModuleSourceRepoRow(providerID, secretless, "rotated", secretless+"/secret123", time.Now().Add(-time.Hour)))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks/scm/"+webhookTestUUID+"/secret123", bytes.NewReader([]byte(`{}`))))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401: body=%s", w.Code, w.Body.String())
+	}
+}
@@ -34,10 +34,20 @@ const maxCompressedInputBytes = 100 << 20 // 100 MB
 // maximum entry count, and a compressed-input size cap. Returns an error on
 // invalid archives.
 func ExtractTarGz(reader io.Reader, destDir string) error {
+	return extractTarGz(reader, destDir, maxCompressedInputBytes, maxExtractBytes)
+}
+
+// ExtractTarGzLimit is ExtractTarGz with maxBytes in place of both 100 MB caps,
+// the compressed input and the cumulative extracted bytes.
+func ExtractTarGzLimit(reader io.Reader, destDir string, maxBytes int64) error {
+	return extractTarGz(reader, destDir, maxBytes, maxBytes)
+}
+
+func extractTarGz(reader io.Reader, destDir string, maxCompressed, maxExtract int64) error {
 	if !filepath.IsAbs(destDir) {
 		return fmt.Errorf("destDir must be an absolute path, got: %s", destDir)
 	}
-	gzr, err := gzip.NewReader(io.LimitReader(reader, maxCompressedInputBytes+1))
+	gzr, err := gzip.NewReader(io.LimitReader(reader, maxCompressed+1))
 	if err != nil {
 		return fmt.Errorf("open gzip: %w", err)
 	}
@@ -83,15 +93,15 @@ func ExtractTarGz(reader io.Reader, destDir string) error {
 			if err != nil {
 				return fmt.Errorf("create file %s: %w", target, err)
 			}
-			remaining := maxExtractBytes - totalWritten
+			remaining := maxExtract - totalWritten
 			n, copyErr := io.Copy(f, io.LimitReader(tr, remaining+1))
 			_ = f.Close()
 			if copyErr != nil {
 				return fmt.Errorf("write file %s: %w", target, copyErr)
 			}
 			totalWritten += n
-			if totalWritten > maxExtractBytes {
-				return fmt.Errorf("archive exceeds extraction size limit of %d bytes", maxExtractBytes)
+			if totalWritten > maxExtract {
+				return fmt.Errorf("archive exceeds extraction size limit of %d bytes", maxExtract)
 			}
 		}
 	}
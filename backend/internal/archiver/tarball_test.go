@@ -192,6 +192,30 @@ func TestExtractTarGz_ExceedsExtractionCap(t *testing.T) {
 	}
 }
 
+func TestExtractTarGzLimit_RejectsArchiveAboveLimit(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	content := bytes.Repeat([]byte("A"), 4096)
+	if err := tw.WriteHeader(&tar.Header{Name: "main.tf", Mode: 0644, Size: int64(len(content))}); err != nil {
+		t.Fatalf("tar WriteHeader: %v", err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatalf("tar Write: %v", err)
+	}
+	tw.Close()
+	gw.Close()
+	data := buf.Bytes()
+
+	if err := ExtractTarGzLimit(bytes.NewReader(data), t.TempDir(), 8192); err != nil {
+		t.Fatalf("archive within the limit: %v", err)
+	}
+	err := ExtractTarGzLimit(bytes.NewReader(data), t.TempDir(), 1024)
+	if err == nil || !strings.Contains(err.Error(), "extraction size limit") {
+		t.Errorf("err = %v, want the extraction size limit", err)
+	}
+}
+
 // Issue #566 finding [46]: TestExtractTarGz_ExceedsExtractionCap (above) only proves
 // a single oversized entry is rejected — that alone would also pass a naive per-entry
 // size check (e.g. "if header.Size > maxExtractBytes"). This test proves the cap is
@@ -0,0 +1,85 @@
+// Package auth - ci_token.go issues and validates the short-lived publish
+// tokens minted by the CI OIDC token exchange.
+//
+// A CI token is not a user session: it names no user, carries the single
+// namespace and organization its trust rule grants, and is signed with a key
+// derived from the JWT secret rather than the secret itself. The derived key
+// keeps the two token kinds disjoint — a CI token never validates as a user
+// JWT (which would fail the user lookup) and a user JWT never validates as a
+// CI token. Rotating the JWT secret invalidates outstanding CI tokens
+// immediately; they are short-lived and CI simply exchanges again.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ciTokenIssuer is stamped as `iss` on CI publish tokens.
+const ciTokenIssuer = "terraform-registry/token-exchange"
+
+// ciTokenKeyLabel is mixed into the JWT secret to derive the CI signing key.
+const ciTokenKeyLabel = "terraform-registry ci publish token v1"
+
+// CITokenClaims are the claims of a CI publish token.
+type CITokenClaims struct {
+	OrganizationID string   `json:"org_id"`
+	Namespace      string   `json:"namespace"`
+	Scopes         []string `json:"scopes"`
+	TrustRuleID    string   `json:"trust_rule_id"`
+	// Identity the token was exchanged for, kept for the audit trail.
+	CIIssuer     string `json:"ci_iss"`
+	CISubject    string `json:"ci_sub"`
+	CIRepository string `json:"ci_repository,omitempty"`
+	CIRef        string `json:"ci_ref,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func ciTokenKey() []byte {
+	mac := hmac.New(sha256.New, []byte(GetJWTSecret()))
+	mac.Write([]byte(ciTokenKeyLabel))
+	return mac.Sum(nil)
+}
+
+// GenerateCIToken signs a CI publish token valid for ttl, stamping the issuer,
+// subject, timestamps and a fresh JTI onto claims.
+func GenerateCIToken(claims *CITokenClaims, ttl time.Duration) (string, error) {
+	if claims.Namespace == "" || claims.OrganizationID == "" {
+		return "", errors.New("ci token requires a namespace and organization")
+	}
+	now := time.Now()
+	claims.Issuer = ciTokenIssuer
+	claims.Subject = "ci:" + claims.TrustRuleID
+	claims.ID = uuid.NewString()
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ciTokenKey())
+}
+
+// ValidateCIToken parses and verifies a CI publish token. Time-claim failures
+// beyond the clock skew leeway are *TokenTimeError.
+func ValidateCIToken(tokenString string) (*CITokenClaims, error) {
+	claims := &CITokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return ciTokenKey(), nil
+	}, jwt.WithIssuer(ciTokenIssuer), jwt.WithExpirationRequired(), jwt.WithLeeway(ClockSkewLeeway()))
+	if err != nil {
+		return nil, asTokenTimeError(err)
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.Namespace == "" || claims.OrganizationID == "" {
+		return nil, errors.New("ci token is missing its namespace binding")
+	}
+	return claims, nil
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCIToken_RoundTrip(t *testing.T) {
+	resetJWTSecret()
+
+	token, err := GenerateCIToken(&CITokenClaims{
+		OrganizationID: "org-1",
+		Namespace:      "acme",
+		Scopes:         []string{string(ScopeModulesWrite)},
+		TrustRuleID:    "rule-1",
+		CIRepository:   "acme/terraform-aws-vpc",
+	}, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateCIToken: %v", err)
+	}
+
+	claims, err := ValidateCIToken(token)
+	if err != nil {
+		t.Fatalf("ValidateCIToken: %v", err)
+	}
+	if claims.Namespace != "acme" || claims.OrganizationID != "org-1" || claims.ID == "" || claims.Subject != "ci:rule-1" {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestCIToken_DisjointFromUserJWT(t *testing.T) {
+	resetJWTSecret()
+
+	ciToken, err := GenerateCIToken(&CITokenClaims{OrganizationID: "org-1", Namespace: "acme"}, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateCIToken: %v", err)
+	}
+	if _, err := ValidateJWT(ciToken); err == nil {
+		t.Error("a CI token must not validate as a user JWT")
+	}
+
+	userToken, err := GenerateJWT("user-1", "u@example.com", []string{"admin"}, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	if _, err := ValidateCIToken(userToken); err == nil {
+		t.Error("a user JWT must not validate as a CI token")
+	}
+}
+
+func TestCIToken_Expired(t *testing.T) {
+	resetJWTSecret()
+
+	token, err := GenerateCIToken(&CITokenClaims{OrganizationID: "org-1", Namespace: "acme"}, -time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateCIToken: %v", err)
+	}
+	if _, err := ValidateCIToken(token); err == nil {
+		t.Error("expected an expired CI token to be rejected")
+	}
+}
+
+func TestGenerateCIToken_RequiresNamespace(t *testing.T) {
+	if _, err := GenerateCIToken(&CITokenClaims{OrganizationID: "org-1"}, time.Minute); err == nil {
+		t.Error("expected error without a namespace")
+	}
+}
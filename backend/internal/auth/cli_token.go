@@ -0,0 +1,117 @@
+// Package auth - cli_token.go issues and validates the tokens handed to the
+// Terraform CLI (`terraform login`, credentials.tfrc.json).
+//
+// A CLI token belongs to a user but is not a session: it carries the
+// `terraform-cli` audience, only the read scopes the user held when it was
+// minted, and is signed with a key derived from the JWT secret, so it never
+// validates as a user JWT (or a CI token) and vice versa. The auth middleware
+// accepts it only on the registry protocol routes. Each token's JTI is
+// recorded in cli_tokens so the owner can list and revoke it; revocation
+// goes through the shared JTI denylist like any other token.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// CLITokenAudience is the `aud` claim of every CLI token.
+const CLITokenAudience = "terraform-cli"
+
+// cliTokenIssuer is stamped as `iss` on CLI tokens.
+const cliTokenIssuer = "terraform-registry/cli"
+
+// cliTokenKeyLabel is mixed into the JWT secret to derive the CLI signing key.
+const cliTokenKeyLabel = "terraform-registry cli token v1"
+
+// cliTokenScopes are the scopes a CLI token may carry: the registry protocol
+// reads. A token gets those of them its user holds.
+var cliTokenScopes = []Scope{ScopeModulesRead, ScopeProvidersRead}
+
+// CLITokenClaims are the claims of a CLI token. Subject is the user ID.
+type CLITokenClaims struct {
+	Email  string   `json:"email,omitempty"`
+	Scopes []string `json:"scopes"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the ID of the user the token was issued to.
+func (c *CLITokenClaims) UserID() string {
+	return c.Subject
+}
+
+// CLITokenScopes returns the protocol read scopes granted by userScopes,
+// which is what a CLI token minted for that user carries.
+func CLITokenScopes(userScopes []string) []string {
+	scopes := []string{}
+	for _, s := range cliTokenScopes {
+		if HasScope(userScopes, s) {
+			scopes = append(scopes, string(s))
+		}
+	}
+	return scopes
+}
+
+func cliTokenKey() []byte {
+	mac := hmac.New(sha256.New, []byte(GetJWTSecret()))
+	mac.Write([]byte(cliTokenKeyLabel))
+	return mac.Sum(nil)
+}
+
+// GenerateCLIToken signs a CLI token for userID valid for ttl. It returns the
+// token and its claims, whose ID (the JTI) identifies it for revocation.
+func GenerateCLIToken(userID, email string, scopes []string, ttl time.Duration) (string, *CLITokenClaims, error) {
+	if userID == "" {
+		return "", nil, errors.New("cli token requires a user")
+	}
+	if scopes == nil {
+		scopes = []string{}
+	}
+	now := time.Now()
+	claims := &CLITokenClaims{
+		Email:  email,
+		Scopes: scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cliTokenIssuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{CLITokenAudience},
+			ID:        uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(cliTokenKey())
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// ValidateCLIToken parses and verifies a CLI token, including its audience.
+// Time-claim failures beyond the clock skew leeway are *TokenTimeError.
+func ValidateCLIToken(tokenString string) (*CLITokenClaims, error) {
+	claims := &CLITokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return cliTokenKey(), nil
+	}, jwt.WithIssuer(cliTokenIssuer), jwt.WithAudience(CLITokenAudience), jwt.WithExpirationRequired(),
+		jwt.WithLeeway(ClockSkewLeeway()))
+	if err != nil {
+		return nil, asTokenTimeError(err)
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.Subject == "" || claims.ID == "" {
+		return nil, errors.New("cli token is missing its user or token ID")
+	}
+	return claims, nil
+}
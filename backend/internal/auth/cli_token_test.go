@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCLIToken_RoundTrip(t *testing.T) {
+	resetJWTSecret()
+
+	token, issued, err := GenerateCLIToken("user-1", "u@example.com", []string{string(ScopeModulesRead)}, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateCLIToken: %v", err)
+	}
+
+	claims, err := ValidateCLIToken(token)
+	if err != nil {
+		t.Fatalf("ValidateCLIToken: %v", err)
+	}
+	if claims.UserID() != "user-1" || claims.ID != issued.ID || claims.ID == "" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != CLITokenAudience {
+		t.Errorf("audience = %v, want [%s]", claims.Audience, CLITokenAudience)
+	}
+}
+
+func TestCLIToken_DisjointFromOtherTokens(t *testing.T) {
+	resetJWTSecret()
+
+	cliToken, _, err := GenerateCLIToken("user-1", "", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateCLIToken: %v", err)
+	}
+	if _, err := ValidateJWT(cliToken); err == nil {
+		t.Error("a CLI token must not validate as a user JWT")
+	}
+	if _, err := ValidateCIToken(cliToken); err == nil {
+		t.Error("a CLI token must not validate as a CI token")
+	}
+
+	userToken, err := GenerateJWT("user-1", "u@example.com", []string{"admin"}, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	if _, err := ValidateCLIToken(userToken); err == nil {
+		t.Error("a user JWT must not validate as a CLI token")
+	}
+}
+
+func TestCLIToken_Expired(t *testing.T) {
+	resetJWTSecret()
+
+	token, _, err := GenerateCLIToken("user-1", "", nil, -time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateCLIToken: %v", err)
+	}
+	if _, err := ValidateCLIToken(token); err == nil {
+		t.Error("expected an expired CLI token to be rejected")
+	}
+}
+
+func TestGenerateCLIToken_RequiresUser(t *testing.T) {
+	if _, _, err := GenerateCLIToken("", "", nil, time.Minute); err == nil {
+		t.Error("expected error without a user")
+	}
+}
+
+func TestCLITokenScopes(t *testing.T) {
+	tests := []struct {
+		name string
+		user []string
+		want []string
+	}{
+		{"admin gets all protocol reads", []string{string(ScopeAdmin)}, []string{"modules:read", "providers:read"}},
+		{"write implies read", []string{string(ScopeModulesWrite)}, []string{"modules:read"}},
+		{"unrelated scopes grant nothing", []string{string(ScopeMirrorsManage), string(ScopeAuditRead)}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CLITokenScopes(tt.user); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CLITokenScopes(%v) = %v, want %v", tt.user, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Package auth - clock_skew.go applies a clock skew leeway to the time claims
+// (exp, nbf) of the tokens this service validates, and reports a token
+// rejected for its time claims as a TokenTimeError so the auth middleware can
+// tell the caller whether the token expired or is not valid yet, and include
+// the server's clock in the response.
+package auth
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultClockSkewLeeway is the leeway applied until SetClockSkewLeeway is
+// called (auth.clock_skew_leeway).
+const DefaultClockSkewLeeway = 30 * time.Second
+
+// Time-claim rejection reasons, as returned in TokenTimeError.Reason.
+const (
+	TokenExpired     = "token_expired"
+	TokenNotYetValid = "token_not_yet_valid"
+)
+
+var clockSkewLeeway atomic.Int64
+
+func init() { clockSkewLeeway.Store(int64(DefaultClockSkewLeeway)) }
+
+// SetClockSkewLeeway sets how far exp and nbf may be off before a token is
+// rejected. Negative values are treated as zero.
+func SetClockSkewLeeway(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	clockSkewLeeway.Store(int64(d))
+}
+
+// ClockSkewLeeway returns the leeway currently applied to token time claims.
+func ClockSkewLeeway() time.Duration {
+	return time.Duration(clockSkewLeeway.Load())
+}
+
+// TokenTimeError reports a token whose signature verified but whose exp or
+// nbf claim is outside the accepted window even with the leeway applied. It
+// unwraps to jwt.ErrTokenExpired or jwt.ErrTokenNotValidYet.
+type TokenTimeError struct {
+	Reason string // TokenExpired or TokenNotYetValid
+	err    error
+}
+
+func (e *TokenTimeError) Error() string { return e.err.Error() }
+
+func (e *TokenTimeError) Unwrap() error { return e.err }
+
+// asTokenTimeError wraps err in a TokenTimeError when it is only a time-claim
+// failure and returns it unchanged otherwise. An expiry takes precedence when
+// a token fails both checks.
+func asTokenTimeError(err error) error {
+	switch {
+	case !isOnlyTimeClaimError(err):
+		return err
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return &TokenTimeError{Reason: TokenExpired, err: err}
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return &TokenTimeError{Reason: TokenNotYetValid, err: err}
+	}
+	return err
+}
+
+// isOnlyTimeClaimError reports whether err is a time-claim failure and
+// nothing else. jwt/v5 verifies the signature before the claims and joins
+// every failed claim check into one error, so such a token is genuine and
+// addressed to us; only its timing is off.
+func isOnlyTimeClaimError(err error) bool {
+	if !errors.Is(err, jwt.ErrTokenExpired) && !errors.Is(err, jwt.ErrTokenNotValidYet) {
+		return false
+	}
+	for _, other := range []error{
+		jwt.ErrTokenMalformed, jwt.ErrTokenUnverifiable, jwt.ErrTokenSignatureInvalid,
+		jwt.ErrTokenInvalidAudience, jwt.ErrTokenInvalidIssuer, jwt.ErrTokenInvalidId,
+		jwt.ErrTokenRequiredClaimMissing, jwt.ErrTokenUsedBeforeIssued,
+	} {
+		if errors.Is(err, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateJWTWithLeeway re-checks a session JWT that the TokenManager rejected
+// only for its time claims, this time allowing the clock skew leeway. The
+// TokenManager offers no leeway of its own, so this repeats its checks: HS256
+// with the current secret, this app's audience and a trusted issuer. Tokens
+// signed with the previous secret during a rotation overlap get no leeway.
+func validateJWTWithLeeway(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(loadSecret()), nil
+	}, jwt.WithAudience(jwtIssuer), jwt.WithLeeway(ClockSkewLeeway()))
+	if err != nil {
+		return nil, err
+	}
+	if !issuerTrusted(claims.Issuer) {
+		return nil, errors.New("token issuer not allowed")
+	}
+	return claims, nil
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// skewedJWT signs a session JWT as ValidateJWTSecret's TokenManager would,
+// but with the given issued-at, not-before and expiry times.
+func skewedJWT(t *testing.T, iat, nbf, exp time.Time) string {
+	t.Helper()
+	claims := &Claims{
+		UserID: "user-1",
+		JTI:    "jti-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    jwtIssuer,
+			Audience:  jwt.ClaimStrings{jwtIssuer},
+			Subject:   "user-1",
+			IssuedAt:  jwt.NewNumericDate(iat),
+			NotBefore: jwt.NewNumericDate(nbf),
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(GetJWTSecret()))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return token
+}
+
+func TestValidateJWT_ClockSkew(t *testing.T) {
+	resetJWTSecret()
+	t.Setenv("TFR_JWT_SECRET", "test-jwt-secret-that-is-32-chars-!")
+	SetClockSkewLeeway(DefaultClockSkewLeeway)
+	t.Cleanup(func() { SetClockSkewLeeway(DefaultClockSkewLeeway) })
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		iat, nbf   time.Time
+		exp        time.Time
+		wantReason string // empty: accepted
+	}{
+		{"issuer clock ahead within leeway", now.Add(10 * time.Second), now.Add(10 * time.Second), now.Add(time.Hour), ""},
+		{"expired within leeway", now.Add(-time.Hour), now.Add(-time.Hour), now.Add(-10 * time.Second), ""},
+		{"issuer clock ahead beyond leeway", now.Add(2 * time.Minute), now.Add(2 * time.Minute), now.Add(time.Hour), TokenNotYetValid},
+		{"expired beyond leeway", now.Add(-time.Hour), now.Add(-time.Hour), now.Add(-2 * time.Minute), TokenExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ValidateJWT(skewedJWT(t, tt.iat, tt.nbf, tt.exp))
+			if tt.wantReason == "" {
+				if err != nil || claims.UserID != "user-1" {
+					t.Fatalf("ValidateJWT = %v, %v; want the claims", claims, err)
+				}
+				return
+			}
+			var timeErr *TokenTimeError
+			if !errors.As(err, &timeErr) || timeErr.Reason != tt.wantReason {
+				t.Fatalf("err = %v, want a TokenTimeError with reason %s", err, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestValidateJWT_ZeroLeeway(t *testing.T) {
+	resetJWTSecret()
+	t.Setenv("TFR_JWT_SECRET", "test-jwt-secret-that-is-32-chars-!")
+	SetClockSkewLeeway(0)
+	t.Cleanup(func() { SetClockSkewLeeway(DefaultClockSkewLeeway) })
+	now := time.Now()
+
+	_, err := ValidateJWT(skewedJWT(t, now.Add(10*time.Second), now.Add(10*time.Second), now.Add(time.Hour)))
+	var timeErr *TokenTimeError
+	if !errors.As(err, &timeErr) || timeErr.Reason != TokenNotYetValid {
+		t.Errorf("err = %v, want token_not_yet_valid", err)
+	}
+}
+
+func TestValidateJWT_LeewayKeepsOtherChecks(t *testing.T) {
+	resetJWTSecret()
+	t.Setenv("TFR_JWT_SECRET", "test-jwt-secret-that-is-32-chars-!")
+	now := time.Now()
+	claims := &Claims{
+		UserID: "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "terraform-state-manager", // not trusted
+			Audience:  jwt.ClaimStrings{jwtIssuer},
+			ExpiresAt: jwt.NewNumericDate(now.Add(-10 * time.Second)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(GetJWTSecret()))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+
+	_, err = ValidateJWT(token)
+	var timeErr *TokenTimeError
+	if err == nil || errors.As(err, &timeErr) {
+		t.Errorf("err = %v, want an issuer rejection", err)
+	}
+}
+
+func TestValidateCLIToken_ClockSkew(t *testing.T) {
+	SetClockSkewLeeway(DefaultClockSkewLeeway)
+	token, _, err := GenerateCLIToken("user-1", "u@example.com", nil, -10*time.Second)
+	if err != nil {
+		t.Fatalf("GenerateCLIToken: %v", err)
+	}
+	if _, err := ValidateCLIToken(token); err != nil {
+		t.Errorf("CLI token expired within the leeway: %v", err)
+	}
+
+	token, _, err = GenerateCLIToken("user-1", "u@example.com", nil, -2*time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateCLIToken: %v", err)
+	}
+	var timeErr *TokenTimeError
+	if _, err := ValidateCLIToken(token); !errors.As(err, &timeErr) || timeErr.Reason != TokenExpired {
+		t.Errorf("err = %v, want token_expired", err)
+	}
+	if !errors.Is(timeErr, jwt.ErrTokenExpired) {
+		t.Error("TokenTimeError does not unwrap to jwt.ErrTokenExpired")
+	}
+}
@@ -43,6 +43,10 @@ var (
 	// tokenManager performs the actual signing/validation. Constructed once the
 	// secret is resolved; the file watch swaps its secret via RotateSecret.
 	tokenManager *identityauth.TokenManager
+
+	// trustedIssuers mirrors the TokenManager's allowed issuers for the
+	// leeway re-check in validateJWTWithLeeway, which cannot ask it.
+	trustedIssuers atomic.Pointer[[]string]
 )
 
 func storeSecret(s string) { currentSecret.Store(&s) }
@@ -132,7 +136,7 @@ func ValidateJWTSecret() error {
 		// suite deployment extends it via SetTrustedIssuers (suite.trusted_issuers
 		// / TFR_SUITE_TRUSTED_ISSUERS), called once at startup after this
 		// function; see cmd/server/main.go.
-		tokenManager.SetAllowedIssuers([]string{jwtIssuer})
+		setAllowedIssuers([]string{jwtIssuer})
 		// Stamp/require this app's own identity as the audience (issue #559
 		// finding [0], completed via #608). An issuer pin alone still lets a
 		// trusted sibling's token through unchanged; SetAudience closes that gap
@@ -176,7 +180,25 @@ func SetTrustedIssuers(extra []string) {
 		}
 		issuers = append(issuers, iss)
 	}
+	setAllowedIssuers(issuers)
+}
+
+func setAllowedIssuers(issuers []string) {
 	tokenManager.SetAllowedIssuers(issuers)
+	trustedIssuers.Store(&issuers)
+}
+
+func issuerTrusted(iss string) bool {
+	p := trustedIssuers.Load()
+	if p == nil {
+		return false
+	}
+	for _, allowed := range *p {
+		if iss == allowed {
+			return true
+		}
+	}
+	return false
 }
 
 // GetJWTSecret retrieves the current effective JWT secret, validating lazily if
@@ -200,9 +222,21 @@ func GenerateJWT(userID, email string, scopes []string, expiresIn time.Duration)
 
 // ValidateJWT parses and validates a JWT via the shared identity TokenManager.
 // During a key rotation overlap the TokenManager also tries the previous secret.
+// A token rejected only for its exp or nbf claim is accepted when it is within
+// the clock skew leeway, and reported as a *TokenTimeError otherwise.
 func ValidateJWT(tokenString string) (*Claims, error) {
 	_ = GetJWTSecret()
-	return tokenManager.Validate(tokenString)
+	claims, err := tokenManager.Validate(tokenString)
+	if err == nil || !isOnlyTimeClaimError(err) {
+		return claims, err
+	}
+	if ClockSkewLeeway() > 0 {
+		claims, err = validateJWTWithLeeway(tokenString)
+		if err == nil {
+			return claims, nil
+		}
+	}
+	return nil, asTokenTimeError(err)
 }
 
 // StartJWTSecretFileWatch begins watching the file at secretFilePath for changes.
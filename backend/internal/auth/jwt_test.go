@@ -113,7 +113,8 @@ func TestGenerateAndValidateJWT(t *testing.T) {
 	})
 
 	t.Run("expired token is rejected", func(t *testing.T) {
-		token, err := GenerateJWT("uid", "u@example.com", nil, -time.Second)
+		// Expired by more than DefaultClockSkewLeeway.
+		token, err := GenerateJWT("uid", "u@example.com", nil, -time.Minute)
 		if err != nil {
 			t.Fatalf("GenerateJWT() error: %v", err)
 		}
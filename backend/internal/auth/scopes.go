@@ -28,6 +28,11 @@ const (
 	ScopeMirrorsRead   Scope = "mirrors:read"   // View mirror configurations and sync status
 	ScopeMirrorsManage Scope = "mirrors:manage" // Create, update, delete mirrors and trigger syncs
 
+	// Terraform binary mirror scopes. Split out of the mirror scopes, which
+	// the binary mirror endpoints still accept (see AcceptedScopes).
+	ScopeBinariesRead   Scope = "binaries:read"   // View binary mirror configurations, versions and sync status
+	ScopeBinariesManage Scope = "binaries:manage" // Create, update, delete binary mirrors and trigger syncs
+
 	// SCM provider management scopes
 	ScopeSCMRead   Scope = "scm:read"   // View SCM provider configurations
 	ScopeSCMManage Scope = "scm:manage" // Create, update, delete SCM providers and manage OAuth
@@ -63,6 +68,7 @@ var readWritePairs = identityauth.ReadWritePairs{
 	string(ScopeProvidersRead):     string(ScopeProvidersWrite),
 	string(ScopeUsersRead):         string(ScopeUsersWrite),
 	string(ScopeMirrorsRead):       string(ScopeMirrorsManage),
+	string(ScopeBinariesRead):      string(ScopeBinariesManage),
 	string(ScopeOrganizationsRead): string(ScopeOrganizationsWrite),
 	string(ScopeSCMRead):           string(ScopeSCMManage),
 }
@@ -76,6 +82,8 @@ func AllScopes() []Scope {
 		ScopeProvidersWrite,
 		ScopeMirrorsRead,
 		ScopeMirrorsManage,
+		ScopeBinariesRead,
+		ScopeBinariesManage,
 		ScopeUsersRead,
 		ScopeUsersWrite,
 		ScopeOrganizationsRead,
@@ -91,6 +99,77 @@ func AllScopes() []Scope {
 	}
 }
 
+// splitScopes maps a scope split out of a broader one to the scope that
+// covered it before. Routes requiring the narrower scope keep accepting the
+// broader one, so existing API keys and role templates do not break.
+var splitScopes = map[Scope]Scope{
+	ScopeBinariesRead:   ScopeMirrorsRead,
+	ScopeBinariesManage: ScopeMirrorsManage,
+}
+
+// AcceptedScopes returns the scopes a route requiring scope accepts: scope
+// itself, and the scope it was split out of, if any. Pass the result to
+// RequireAnyScope. Admin and write-implies-read apply as usual.
+func AcceptedScopes(scope Scope) []Scope {
+	if broader, ok := splitScopes[scope]; ok {
+		return []Scope{scope, broader}
+	}
+	return []Scope{scope}
+}
+
+// scopeDescriptions describes each scope for the scope catalog.
+var scopeDescriptions = map[Scope]string{
+	ScopeModulesRead:         "View modules and module versions",
+	ScopeModulesWrite:        "Publish, update and delete modules",
+	ScopeProvidersRead:       "View providers and provider versions",
+	ScopeProvidersWrite:      "Publish, update and delete providers",
+	ScopeMirrorsRead:         "View provider mirror configurations and sync status",
+	ScopeMirrorsManage:       "Create, update, delete provider mirrors and trigger syncs",
+	ScopeBinariesRead:        "View Terraform binary mirror configurations, versions and sync status",
+	ScopeBinariesManage:      "Create, update, delete Terraform binary mirrors and trigger syncs",
+	ScopeUsersRead:           "View users",
+	ScopeUsersWrite:          "Create, update and delete users",
+	ScopeOrganizationsRead:   "View organizations and their members",
+	ScopeOrganizationsWrite:  "Update organizations and manage their members",
+	ScopeOrganizationsCreate: "Create new top-level organizations",
+	ScopeSCMRead:             "View SCM provider configurations",
+	ScopeSCMManage:           "Create, update, delete SCM providers and manage OAuth",
+	ScopeAPIKeysManage:       "Create, rotate and revoke API keys",
+	ScopeAuditRead:           "View and export the audit log",
+	ScopeScanningRead:        "View scan results, config, and stats",
+	ScopeSCIMProvision:       "SCIM 2.0 user and group provisioning",
+	ScopeAdmin:               "Full access to every registry feature",
+}
+
+// ScopeInfo is one entry of the scope catalog.
+type ScopeInfo struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+	// ImpliedBy lists the other scopes that satisfy this one, besides admin:
+	// its write/manage pair, and the scopes it was split out of.
+	ImpliedBy []string `json:"implied_by,omitempty"`
+}
+
+// ScopeCatalog describes every valid scope, in AllScopes order.
+func ScopeCatalog() []ScopeInfo {
+	scopes := AllScopes()
+	catalog := make([]ScopeInfo, 0, len(scopes))
+	for _, scope := range scopes {
+		info := ScopeInfo{Scope: string(scope), Description: scopeDescriptions[scope]}
+		if write, ok := readWritePairs[string(scope)]; ok {
+			info.ImpliedBy = append(info.ImpliedBy, write)
+		}
+		if broader, ok := splitScopes[scope]; ok {
+			info.ImpliedBy = append(info.ImpliedBy, string(broader))
+			if write, ok := readWritePairs[string(broader)]; ok {
+				info.ImpliedBy = append(info.ImpliedBy, write)
+			}
+		}
+		catalog = append(catalog, info)
+	}
+	return catalog
+}
+
 // ValidScopes returns a map of valid scope strings
 func ValidScopes() map[string]bool {
 	validScopes := make(map[string]bool)
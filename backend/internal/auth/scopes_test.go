@@ -208,3 +208,55 @@ func TestAllScopesUnique(t *testing.T) {
 		seen[sc] = true
 	}
 }
+
+func TestAcceptedScopes_BinariesAcceptMirrors(t *testing.T) {
+	tests := []struct {
+		required Scope
+		held     []string
+		want     bool
+	}{
+		{ScopeBinariesRead, []string{"binaries:read"}, true},
+		{ScopeBinariesRead, []string{"binaries:manage"}, true},
+		{ScopeBinariesRead, []string{"mirrors:read"}, true},
+		{ScopeBinariesRead, []string{"mirrors:manage"}, true},
+		{ScopeBinariesManage, []string{"mirrors:manage"}, true},
+		{ScopeBinariesManage, []string{"admin"}, true},
+		{ScopeBinariesManage, []string{"binaries:read", "mirrors:read"}, false},
+		{ScopeBinariesRead, []string{"providers:read"}, false},
+	}
+	for _, tt := range tests {
+		if got := HasAnyScope(tt.held, AcceptedScopes(tt.required)); got != tt.want {
+			t.Errorf("%v holding %v: got %v, want %v", tt.required, tt.held, got, tt.want)
+		}
+	}
+	// Binary scopes do not grant the provider mirror endpoints.
+	if HasScope([]string{"binaries:manage"}, ScopeMirrorsRead) {
+		t.Error("binaries:manage must not satisfy mirrors:read")
+	}
+	if got := AcceptedScopes(ScopeModulesRead); len(got) != 1 || got[0] != ScopeModulesRead {
+		t.Errorf("AcceptedScopes(modules:read) = %v", got)
+	}
+}
+
+func TestScopeCatalog(t *testing.T) {
+	catalog := ScopeCatalog()
+	if len(catalog) != len(AllScopes()) {
+		t.Fatalf("catalog has %d entries, want %d", len(catalog), len(AllScopes()))
+	}
+	for _, info := range catalog {
+		if info.Description == "" {
+			t.Errorf("scope %q has no description", info.Scope)
+		}
+		if info.Scope == string(ScopeBinariesRead) {
+			want := []string{"binaries:manage", "mirrors:read", "mirrors:manage"}
+			if len(info.ImpliedBy) != len(want) {
+				t.Fatalf("binaries:read implied_by = %v, want %v", info.ImpliedBy, want)
+			}
+			for i := range want {
+				if info.ImpliedBy[i] != want[i] {
+					t.Errorf("binaries:read implied_by = %v, want %v", info.ImpliedBy, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package tokenexchange verifies OIDC ID tokens presented by CI systems (e.g.
+// GitHub Actions) to the token exchange endpoint. Only issuers configured in
+// auth.token_exchange.trusted_issuers are contacted: the issuer claim is read
+// from the unverified token solely to pick the issuer, and the token is then
+// verified against that issuer's published signing keys, audience and expiry.
+package tokenexchange
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ErrUntrustedIssuer is returned for tokens whose issuer is not configured.
+var ErrUntrustedIssuer = errors.New("token issuer is not trusted")
+
+// ErrInvalidToken wraps every other verification failure (malformed token,
+// bad signature, wrong audience, expired).
+var ErrInvalidToken = errors.New("invalid identity token")
+
+// discoveryTimeout bounds the issuer discovery request.
+const discoveryTimeout = 10 * time.Second
+
+// Verifier verifies CI OIDC ID tokens against a fixed set of trusted issuers.
+// Issuer discovery and signing keys are cached per issuer.
+type Verifier struct {
+	audience string
+	trusted  map[string]bool
+	client   *http.Client
+
+	mu        sync.Mutex
+	verifiers map[string]*oidc.IDTokenVerifier
+}
+
+// NewVerifier creates a Verifier accepting tokens from trustedIssuers with the
+// given audience. client performs discovery and key fetches; pass the SSRF-safe
+// egress client in production.
+func NewVerifier(trustedIssuers []string, audience string, client *http.Client) *Verifier {
+	trusted := make(map[string]bool, len(trustedIssuers))
+	for _, iss := range trustedIssuers {
+		if iss != "" {
+			trusted[iss] = true
+		}
+	}
+	return &Verifier{
+		audience:  audience,
+		trusted:   trusted,
+		client:    client,
+		verifiers: make(map[string]*oidc.IDTokenVerifier),
+	}
+}
+
+// IsTrusted reports whether issuer is one of the configured issuers.
+func (v *Verifier) IsTrusted(issuer string) bool {
+	return v.trusted[issuer]
+}
+
+// ciClaims are the claims read from a verified token. Field names follow
+// GitHub Actions; other issuers populate whichever they share.
+type ciClaims struct {
+	Repository  string `json:"repository"`
+	Ref         string `json:"ref"`
+	Environment string `json:"environment"`
+	WorkflowRef string `json:"workflow_ref"`
+	Actor       string `json:"actor"`
+	SHA         string `json:"sha"`
+	RunID       string `json:"run_id"`
+}
+
+// Verify checks rawToken and returns the CI identity it asserts.
+func (v *Verifier) Verify(ctx context.Context, rawToken string) (*models.CIIdentity, error) {
+	issuer, err := unverifiedIssuer(rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !v.trusted[issuer] {
+		return nil, fmt.Errorf("%w: %s", ErrUntrustedIssuer, issuer)
+	}
+
+	verifier, err := v.verifierFor(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := verifier.Verify(oidc.ClientContext(ctx, v.client), rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims ciClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return &models.CIIdentity{
+		Issuer:      idToken.Issuer,
+		Subject:     idToken.Subject,
+		Repository:  claims.Repository,
+		Ref:         claims.Ref,
+		Environment: claims.Environment,
+		Workflow:    claims.WorkflowRef,
+		Actor:       claims.Actor,
+		SHA:         claims.SHA,
+		RunID:       claims.RunID,
+	}, nil
+}
+
+// verifierFor returns the cached verifier for issuer, running discovery on
+// first use. A failed discovery is not cached so a transient outage recovers.
+func (v *Verifier) verifierFor(ctx context.Context, issuer string) (*oidc.IDTokenVerifier, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if verifier, ok := v.verifiers[issuer]; ok {
+		return verifier, nil
+	}
+
+	// The provider keeps this context (without its deadline) for later key
+	// refreshes, so it carries the egress client rather than the request.
+	discoverCtx, cancel := context.WithTimeout(oidc.ClientContext(context.WithoutCancel(ctx), v.client), discoveryTimeout)
+	defer cancel()
+	provider, err := oidc.NewProvider(discoverCtx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover issuer %s: %w", issuer, err)
+	}
+	verifier := provider.Verifier(&oidc.Config{ClientID: v.audience})
+	v.verifiers[issuer] = verifier
+	return verifier, nil
+}
+
+// unverifiedIssuer reads the iss claim without verifying the signature. It is
+// used only to select which trusted issuer verifies the token.
+func unverifiedIssuer(rawToken string) (string, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return "", errors.New("token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed token payload: %w", err)
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("malformed token payload: %w", err)
+	}
+	if claims.Issuer == "" {
+		return "", errors.New("token has no issuer")
+	}
+	return claims.Issuer, nil
+}
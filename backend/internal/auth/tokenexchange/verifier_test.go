@@ -0,0 +1,142 @@
+package tokenexchange
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeIssuer is a minimal OIDC issuer: discovery document, JWKS, and a helper
+// that signs ID tokens with its key.
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                f.server.URL,
+			"jwks_uri":                              f.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": "test",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeIssuer) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(f.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func (f *fakeIssuer) claims(aud string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":        f.server.URL,
+		"sub":        "repo:acme/terraform-aws-vpc:ref:refs/tags/v1.0.0",
+		"aud":        aud,
+		"exp":        time.Now().Add(5 * time.Minute).Unix(),
+		"iat":        time.Now().Unix(),
+		"repository": "acme/terraform-aws-vpc",
+		"ref":        "refs/tags/v1.0.0",
+		"actor":      "octocat",
+	}
+}
+
+func TestVerify_Valid(t *testing.T) {
+	iss := newFakeIssuer(t)
+	v := NewVerifier([]string{iss.server.URL}, "terraform-registry", iss.server.Client())
+
+	id, err := v.Verify(context.Background(), iss.sign(t, iss.claims("terraform-registry")))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if id.Repository != "acme/terraform-aws-vpc" || id.Ref != "refs/tags/v1.0.0" || id.Actor != "octocat" || id.Issuer != iss.server.URL {
+		t.Errorf("identity = %+v", id)
+	}
+}
+
+func TestVerify_WrongAudience(t *testing.T) {
+	iss := newFakeIssuer(t)
+	v := NewVerifier([]string{iss.server.URL}, "terraform-registry", iss.server.Client())
+
+	_, err := v.Verify(context.Background(), iss.sign(t, iss.claims("some-other-service")))
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerify_Expired(t *testing.T) {
+	iss := newFakeIssuer(t)
+	v := NewVerifier([]string{iss.server.URL}, "terraform-registry", iss.server.Client())
+
+	claims := iss.claims("terraform-registry")
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	if _, err := v.Verify(context.Background(), iss.sign(t, claims)); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerify_UntrustedIssuer(t *testing.T) {
+	iss := newFakeIssuer(t)
+	v := NewVerifier([]string{"https://token.actions.githubusercontent.com"}, "terraform-registry", iss.server.Client())
+
+	_, err := v.Verify(context.Background(), iss.sign(t, iss.claims("terraform-registry")))
+	if !errors.Is(err, ErrUntrustedIssuer) {
+		t.Fatalf("err = %v, want ErrUntrustedIssuer", err)
+	}
+}
+
+func TestVerify_ForgedSignature(t *testing.T) {
+	iss := newFakeIssuer(t)
+	forger := newFakeIssuer(t)
+	v := NewVerifier([]string{iss.server.URL}, "terraform-registry", iss.server.Client())
+
+	// Signed by another key but claiming the trusted issuer.
+	if _, err := v.Verify(context.Background(), forger.sign(t, iss.claims("terraform-registry"))); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerify_Malformed(t *testing.T) {
+	v := NewVerifier([]string{"https://token.actions.githubusercontent.com"}, "terraform-registry", http.DefaultClient)
+	for _, raw := range []string{"", "not-a-jwt", "a.!!!.c"} {
+		if _, err := v.Verify(context.Background(), raw); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify(%q) err = %v, want ErrInvalidToken", raw, err)
+		}
+	}
+}
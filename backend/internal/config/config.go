@@ -12,9 +12,12 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -47,10 +50,208 @@ type Config struct {
 	AuditRetention  AuditRetentionConfig  `mapstructure:"audit_retention"`
 	Webhooks        WebhooksConfig        `mapstructure:"webhooks"`
 	BinaryMirror    BinaryMirrorConfig    `mapstructure:"binary_mirror"`
+	Protocol        ProtocolConfig        `mapstructure:"protocol"`
+	ModuleProxy     ModuleProxyConfig     `mapstructure:"module_proxy"`
+	RemoteUpload    RemoteUploadConfig    `mapstructure:"remote_upload"`
+	SCM             SCMConfig             `mapstructure:"scm"`
+	MirrorSigning   MirrorSigningConfig   `mapstructure:"mirror_signing"`
 	Policy          PolicyConfig          `mapstructure:"policy"`
 	CVE             CVEConfig             `mapstructure:"cve"`
 	ReleasesGPGKeys ReleasesGPGKeysConfig `mapstructure:"releases_gpg_keys"`
 	Suite           SuiteConfig           `mapstructure:"suite"`
+	// ImmutableArtifacts is the registry-wide artifact immutability setting;
+	// organizations can also opt in individually.
+	ImmutableArtifacts ImmutableArtifactsConfig `mapstructure:"immutable_artifacts"`
+	// NamespaceClaims controls which self-service namespace claims are
+	// decided automatically.
+	NamespaceClaims NamespaceClaimsConfig `mapstructure:"namespace_claims"`
+	// PublicStats controls the unauthenticated GET /api/v1/stats/public.
+	PublicStats PublicStatsConfig `mapstructure:"public_stats"`
+	// StorageConsistency controls the job that looks for artifact rows whose
+	// storage object is missing.
+	StorageConsistency StorageConsistencyConfig `mapstructure:"storage_consistency"`
+	// CodeIndex controls the module content index behind code search.
+	CodeIndex CodeIndexConfig `mapstructure:"code_index"`
+	// DownloadHead controls how HEAD requests on artifact download endpoints
+	// are answered.
+	DownloadHead DownloadHeadConfig `mapstructure:"download_head"`
+	// Scratch is the local directory where uploads, SCM publishes, the
+	// module proxy and mirror syncs stage archives.
+	Scratch ScratchConfig `mapstructure:"scratch"`
+	// UsageReports controls how module and provider usage reported to
+	// POST /api/v1/usage/report is presented.
+	UsageReports UsageReportsConfig `mapstructure:"usage_reports"`
+	// Secrets selects where ENCRYPTION_KEY, the database password and the TLS
+	// key pair are read from; resolved before Load returns.
+	Secrets SecretsConfig `mapstructure:"secrets"`
+}
+
+// SecretsConfig selects the backend startup secrets are read from. With the
+// default env backend and no references set, secrets come from the
+// environment and config file as they always have. Each reference's format
+// depends on the backend; see internal/secrets.
+type SecretsConfig struct {
+	// Backend is env (default), file, vault or aws_secrets_manager.
+	Backend string `mapstructure:"backend"`
+	// EncryptionKey and EncryptionKeyPrevious resolve ENCRYPTION_KEY and
+	// ENCRYPTION_KEY_PREVIOUS; empty leaves the environment variable as is.
+	EncryptionKey         string `mapstructure:"encryption_key"`
+	EncryptionKeyPrevious string `mapstructure:"encryption_key_previous"`
+	// DatabasePassword resolves database.password.
+	DatabasePassword string `mapstructure:"database_password"`
+	// TLSCert and TLSKey resolve the PEM certificate chain and private key
+	// served when security.tls.enabled, in place of cert_file and key_file.
+	TLSCert string `mapstructure:"tls_cert"`
+	TLSKey  string `mapstructure:"tls_key"`
+	// RefreshInterval re-fetches DatabasePassword this often and reconnects
+	// the database pool when it changes. 0 (default) fetches it once.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	Vault           VaultSecrets  `mapstructure:"vault"`
+	AWS             AWSSecrets    `mapstructure:"aws"`
+}
+
+// VaultSecrets configures the vault secrets backend (KV v2).
+type VaultSecrets struct {
+	Address   string `mapstructure:"address"`
+	Namespace string `mapstructure:"namespace"`
+	// Mount is the KV v2 mount path; default "secret".
+	Mount string `mapstructure:"mount"`
+	// AuthMethod is token (default) or kubernetes.
+	AuthMethod string `mapstructure:"auth_method"`
+	Token      string `mapstructure:"token"`
+	TokenFile  string `mapstructure:"token_file"`
+	// Role, KubernetesMount and KubernetesTokenFile configure kubernetes auth.
+	Role                string `mapstructure:"role"`
+	KubernetesMount     string `mapstructure:"kubernetes_mount"`
+	KubernetesTokenFile string `mapstructure:"kubernetes_token_file"`
+}
+
+// AWSSecrets configures the aws_secrets_manager secrets backend. Credentials
+// come from the default AWS credential chain.
+type AWSSecrets struct {
+	Region   string `mapstructure:"region"`
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// UsageReportsConfig controls the consumer listings built from usage reports.
+type UsageReportsConfig struct {
+	// StaleAfterDays is how long a consumer can go without reporting a
+	// version before the listings flag it stale. Stale usage is kept, not
+	// deleted. Defaults to 30.
+	StaleAfterDays int `mapstructure:"stale_after_days"`
+}
+
+// ScratchConfig controls the scratch directory. Every temporary file and
+// directory the registry creates goes under Dir, so it should be a directory
+// of its own: entries older than OrphanMaxAge are deleted by the scratch
+// cleanup job.
+type ScratchConfig struct {
+	// Dir is the scratch directory, created at startup. Defaults to
+	// "terraform-registry" under the OS temporary directory.
+	Dir string `mapstructure:"dir"`
+	// MaxSizeMB is the budget shared by uploads and SCM publishes in flight.
+	// Work that would take it over is turned away (503 for uploads, a retry
+	// for queued SCM publishes). 0 disables the budget. Defaults to 10240.
+	MaxSizeMB int64 `mapstructure:"max_size_mb"`
+	// OrphanMaxAge is how old an entry in Dir must be before the cleanup job
+	// treats it as left behind and deletes it. Defaults to 1h.
+	OrphanMaxAge time.Duration `mapstructure:"orphan_max_age"`
+	// CleanupInterval is the time between cleanup runs; each run also
+	// refreshes the scratch usage metric. Defaults to 5m.
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// StorageConsistencyConfig controls the storage consistency checker, which
+// calls storage Exists for the archives referenced by module versions,
+// provider platforms and Terraform binary mirror platforms and records those
+// whose object is gone.
+type StorageConsistencyConfig struct {
+	// Enabled runs the checker and makes downloads of artifacts marked broken
+	// answer 410. Off by default.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the time between checks. Defaults to 24h.
+	Interval time.Duration `mapstructure:"interval"`
+	// FullScan checks every artifact on each run instead of a random sample.
+	FullScan bool `mapstructure:"full_scan"`
+	// SampleSize is how many artifacts of each kind a sampled run checks.
+	// Defaults to 500.
+	SampleSize int `mapstructure:"sample_size"`
+	// MarkBroken marks artifacts broken as soon as their object is found
+	// missing, instead of leaving that to an admin.
+	MarkBroken bool `mapstructure:"mark_broken"`
+}
+
+// CodeIndexConfig controls the module content index, which extracts the
+// resources, data sources, variables and module calls of each module
+// version's .tf files for GET /api/v1/search/code. Versions are indexed in
+// the background after they are published.
+type CodeIndexConfig struct {
+	// Enabled runs the indexing job and serves code search. Off by default.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the time between indexing runs, each of which picks up the
+	// versions published since the last. Defaults to 1m.
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxBytesPerVersion caps how much .tf source is indexed per version;
+	// files past the cap are skipped and the version is marked truncated.
+	// Defaults to 1 MiB.
+	MaxBytesPerVersion int64 `mapstructure:"max_bytes_per_version"`
+	// BatchSize is how many versions a replica claims at a time. Defaults
+	// to 20.
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+// DownloadHeadConfig controls HEAD requests on the module, provider, Terraform
+// binary and local file download endpoints.
+type DownloadHeadConfig struct {
+	// Mode is "metadata" (the default) to answer with the artifact's
+	// Content-Length, ETag, Last-Modified and Accept-Ranges from storage
+	// metadata, "existence" to only check the object exists (for backends
+	// where a metadata lookup is expensive), or "disabled" to not register
+	// the HEAD routes at all.
+	Mode string `mapstructure:"mode"`
+}
+
+// Download HEAD modes for DownloadHeadConfig.Mode.
+const (
+	DownloadHeadMetadata  = "metadata"
+	DownloadHeadExistence = "existence"
+	DownloadHeadDisabled  = "disabled"
+)
+
+// PublicStatsConfig controls the public registry statistics endpoint used by
+// status pages.
+type PublicStatsConfig struct {
+	// Enabled registers GET /api/v1/stats/public. On by default; turn it off
+	// where even aggregate counts are considered sensitive.
+	Enabled bool `mapstructure:"enabled"`
+	// RefreshInterval is how long computed statistics are served from memory
+	// before they are recomputed. Defaults to 5m.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+// NamespaceClaimsConfig controls the self-service namespace claim workflow
+// (POST /api/v1/namespaces/:namespace/claim).
+type NamespaceClaimsConfig struct {
+	// ReservedPatterns are unanchored regular expressions, matched
+	// case-insensitively against the namespace, whose claims are rejected
+	// automatically. They add to the built-in hashicorp and terraform
+	// reservations.
+	ReservedPatterns []string `mapstructure:"reserved_patterns"`
+	// AutoApproveVerifiedDomain approves a claim immediately when the
+	// namespace matches the claiming organization's verified domain prefix.
+	AutoApproveVerifiedDomain bool `mapstructure:"auto_approve_verified_domain"`
+}
+
+// ImmutableArtifactsConfig makes published module and provider archives
+// write-once. While enabled, a published archive can never be replaced, a
+// version cannot be deleted until it is RetentionDays old, and every publish
+// is appended to a hash-chained publish log. Admins are not exempt.
+type ImmutableArtifactsConfig struct {
+	// Enabled applies immutability to every organization. Off by default.
+	Enabled bool `mapstructure:"enabled"`
+	// RetentionDays is how old a version must be before it may be deleted.
+	// 0 (the default) means versions can never be deleted.
+	RetentionDays int `mapstructure:"retention_days"`
 }
 
 // AuditRetentionConfig controls the background audit log cleanup job.
@@ -111,6 +312,187 @@ type BinaryMirrorConfig struct {
 	Allowlist []string `mapstructure:"allowlist"`
 }
 
+// ProtocolConfig tunes the registry protocol documents served to the
+// terraform CLI.
+type ProtocolConfig struct {
+	// DeprecationWarnings adds a "warnings" array naming each deprecated
+	// version and its deprecation reason to module and provider version
+	// listings and to provider download responses, so terraform init prints
+	// them. Off by default: some older terraform releases reject unexpected
+	// fields in these documents.
+	DeprecationWarnings bool `mapstructure:"deprecation_warnings"`
+
+	// FilterByTerraformVersion drops module versions whose recorded
+	// required_version constraint excludes the caller's Terraform version
+	// (the X-Terraform-Version request header) from the protocol version
+	// listing, so terraform init never selects a version it cannot run.
+	// Requests without the header are not filtered.
+	FilterByTerraformVersion bool `mapstructure:"filter_by_terraform_version"`
+}
+
+// ModuleProxyConfig controls the module pull-through proxy. When Enabled, the
+// /v1/modules/proxy/:hostname/... routes fetch module versions that are not
+// present locally from the named upstream registry, cache them in storage and
+// the database, and serve the local copy on every later request. Which
+// namespaces may be proxied is governed by the mirror policies (registry =
+// hostname, namespace pattern, provider pattern matched against the module
+// name); with no matching allow policy nothing is proxied.
+type ModuleProxyConfig struct {
+	// Enabled registers the proxy routes. Off by default.
+	Enabled bool `mapstructure:"enabled"`
+	// AllowedHosts lists the upstream registry hostnames the proxy may
+	// contact. Defaults to registry.terraform.io.
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+}
+
+// IsAllowedHost reports whether hostname is in AllowedHosts (case-insensitive).
+func (c ModuleProxyConfig) IsAllowedHost(hostname string) bool {
+	for _, h := range c.AllowedHosts {
+		if strings.EqualFold(strings.TrimSpace(h), hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteUploadConfig controls publishing from a URL: POST /api/v1/modules and
+// POST /api/v1/providers accept a JSON body naming a source_url, and the
+// server downloads the archive itself. Outbound requests go through the
+// egress guard (security.egress), so private and link-local/metadata targets
+// are refused unless explicitly allow-listed there.
+type RemoteUploadConfig struct {
+	// Enabled accepts JSON (source_url) publish requests. Off by default.
+	Enabled bool `mapstructure:"enabled"`
+	// AllowedSchemes lists the URL schemes that may be fetched. Defaults to
+	// ["https"]; only http and https are valid.
+	AllowedSchemes []string `mapstructure:"allowed_schemes"`
+	// AllowedHosts restricts source_url to these hosts. An entry may be an
+	// exact hostname or "*.example.com" for any subdomain. Empty allows any
+	// host the egress policy permits.
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+	// Timeout bounds one download. Defaults to 10m.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// SCMConfig tunes the shared HTTP client the SCM connectors (GitHub,
+// GitLab, Bitbucket Data Center, Azure DevOps) use, and so the publisher and
+// background jobs that call them.
+type SCMConfig struct {
+	// Timeout bounds one request attempt, including reading the response.
+	// Defaults to 30s.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxRetries is how many times a rate-limited (429, or 403 with the rate
+	// limit exhausted) or, for idempotent methods, 5xx request is retried.
+	// Defaults to 3; 0 disables retries.
+	MaxRetries int `mapstructure:"max_retries"`
+	// MaxRetryWait caps a single wait before a retry, including the wait
+	// Retry-After or the rate limit's reset time asks for; a longer one is not
+	// waited out. Defaults to 60s.
+	MaxRetryWait time.Duration `mapstructure:"max_retry_wait"`
+	// MaxConcurrent limits in-flight requests per SCM provider type. Defaults
+	// to 8; 0 is unlimited.
+	MaxConcurrent int `mapstructure:"max_concurrent"`
+	// RepositoryNameCheck is what linking a module to a repository whose
+	// terraform-<PROVIDER>-<NAME> name names a different module does: "warn"
+	// (the default) links it and reports the mismatch, "block" rejects it
+	// unless the request sets allow_name_mismatch, and "off" skips the check.
+	RepositoryNameCheck string `mapstructure:"repository_name_check"`
+	// MaxArchiveSizeMB caps the repository archive an SCM publish downloads.
+	// policy.max_archive_size_mb takes precedence when set. Defaults to 100.
+	MaxArchiveSizeMB int `mapstructure:"max_archive_size_mb"`
+	// BackfillInterval spaces out the publishes a tag backfill queues, so its
+	// archive downloads do not hammer the SCM. Defaults to 10s; 0 queues them
+	// all at once.
+	BackfillInterval time.Duration `mapstructure:"backfill_interval"`
+}
+
+// defaultSCMArchiveSizeMB is the archive cap when neither setting is made; it
+// matches the upload handler's 100 MB archive limit.
+const defaultSCMArchiveSizeMB = 100
+
+// SCMArchiveSizeLimit returns the largest repository archive, in bytes, an
+// SCM publish downloads: the publish policy's max_archive_size_mb when set,
+// otherwise scm.max_archive_size_mb, otherwise 100 MB.
+func (c *Config) SCMArchiveSizeLimit() int64 {
+	mb := c.SCM.MaxArchiveSizeMB
+	if c.Policy.MaxArchiveSizeMB > 0 {
+		mb = c.Policy.MaxArchiveSizeMB
+	}
+	if mb <= 0 {
+		mb = defaultSCMArchiveSizeMB
+	}
+	return int64(mb) << 20
+}
+
+// Repository name check modes for SCMConfig.RepositoryNameCheck.
+const (
+	RepositoryNameCheckWarn  = "warn"
+	RepositoryNameCheckBlock = "block"
+	RepositoryNameCheckOff   = "off"
+)
+
+// IsAllowedScheme reports whether scheme is in AllowedSchemes (case-insensitive).
+func (c RemoteUploadConfig) IsAllowedScheme(scheme string) bool {
+	for _, s := range c.AllowedSchemes {
+		if strings.EqualFold(strings.TrimSpace(s), scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAllowedHost reports whether host matches AllowedHosts. An empty list
+// allows every host.
+func (c RemoteUploadConfig) IsAllowedHost(host string) bool {
+	if len(c.AllowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, h := range c.AllowedHosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if suffix, ok := strings.CutPrefix(h, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// MirrorSigningConfig re-signs mirrored providers with the registry's own
+// OpenPGP key. When Enabled, each mirrored provider version gets a SHA256SUMS
+// file and detached signature produced by this key, and the provider download
+// response advertises them (and the key) instead of the upstream originals,
+// which are kept for audit. Exactly one key source must be configured:
+// PrivateKey / PrivateKeyFile for in-process signing, or SignerURL for an
+// external signer.
+type MirrorSigningConfig struct {
+	// Enabled turns re-signing on. Off by default.
+	Enabled bool `mapstructure:"enabled"`
+	// PrivateKey is an ASCII-armored OpenPGP private key. It should be
+	// passphrase-protected at rest; Passphrase decrypts it at startup.
+	PrivateKey string `mapstructure:"private_key"`
+	// PrivateKeyFile reads the armored private key from a file (e.g. a
+	// mounted secret) instead of PrivateKey.
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	// Passphrase decrypts an encrypted PrivateKey.
+	Passphrase string `mapstructure:"passphrase"`
+	// SignerURL delegates signing to an external service (e.g. an HSM front
+	// end). The registry POSTs the SHA256SUMS content as
+	// application/octet-stream and expects the detached signature (binary or
+	// armored) in a 200 response.
+	SignerURL string `mapstructure:"signer_url"`
+	// SignerToken is sent as a Bearer token to SignerURL when set.
+	SignerToken string `mapstructure:"signer_token"`
+	// PublicKey is the ASCII-armored public key of the SignerURL key. Required
+	// with SignerURL; signatures it returns are verified against it.
+	PublicKey string `mapstructure:"public_key"`
+}
+
 // PolicyConfig controls the OPA/Rego policy engine.
 // When Enabled is false (the default) the engine is a no-op and all actions are allowed.
 type PolicyConfig struct {
@@ -128,6 +510,10 @@ type PolicyConfig struct {
 	// BundleRefreshInterval is how often (in seconds) the bundle is re-fetched in the background.
 	// 0 means no background refresh.
 	BundleRefreshInterval int `mapstructure:"bundle_refresh_interval"`
+	// MaxArchiveSizeMB is the largest repository archive an SCM publish may
+	// download. It overrides scm.max_archive_size_mb when set and applies
+	// whether or not the Rego engine is enabled; 0 leaves the SCM default.
+	MaxArchiveSizeMB int `mapstructure:"max_archive_size_mb"`
 }
 
 // CVEConfig controls the scheduled OSV.dev vulnerability polling feature.
@@ -286,6 +672,11 @@ type ServerConfig struct {
 	// Empty (default) = just public_url + base_url. TFR_SERVER_HOST_ALIASES,
 	// comma-separated.
 	HostAliases []string `mapstructure:"host_aliases"`
+	// Environment names the kind of deployment: "development", "test",
+	// "staging" or "production". Empty (default) = unspecified. DEV_MODE is
+	// refused at startup in any environment other than development or test.
+	// TFR_SERVER_ENVIRONMENT.
+	Environment string `mapstructure:"environment"`
 }
 
 // SuiteConfig configures optional runtime coupling to the sibling Suite app.
@@ -351,11 +742,12 @@ type DatabaseConfig struct {
 
 // StorageConfig holds storage backend configuration
 type StorageConfig struct {
-	DefaultBackend string             `mapstructure:"default_backend"`
-	Azure          AzureStorageConfig `mapstructure:"azure"`
-	S3             S3StorageConfig    `mapstructure:"s3"`
-	GCS            GCSStorageConfig   `mapstructure:"gcs"`
-	Local          LocalStorageConfig `mapstructure:"local"`
+	DefaultBackend string                   `mapstructure:"default_backend"`
+	Azure          AzureStorageConfig       `mapstructure:"azure"`
+	S3             S3StorageConfig          `mapstructure:"s3"`
+	GCS            GCSStorageConfig         `mapstructure:"gcs"`
+	Artifactory    ArtifactoryStorageConfig `mapstructure:"artifactory"`
+	Local          LocalStorageConfig       `mapstructure:"local"`
 }
 
 // AzureStorageConfig holds Azure Blob Storage configuration
@@ -422,6 +814,22 @@ type GCSStorageConfig struct {
 	Endpoint string `mapstructure:"endpoint"`
 }
 
+// ArtifactoryStorageConfig holds JFrog Artifactory (or generic WebDAV)
+// storage configuration
+type ArtifactoryStorageConfig struct {
+	// BaseURL is the Artifactory root URL, e.g. https://example.jfrog.io/artifactory.
+	// For a generic WebDAV server, the collection URL that artifacts are stored under.
+	BaseURL string `mapstructure:"base_url"`
+
+	// Repository is the Artifactory repository key artifacts are stored in
+	// (optional for generic WebDAV servers, where BaseURL already names the collection)
+	Repository string `mapstructure:"repository"`
+
+	// AccessToken is sent as a bearer token on every request (optional for
+	// servers that allow anonymous writes)
+	AccessToken string `mapstructure:"access_token"`
+}
+
 // LocalStorageConfig holds local filesystem storage configuration
 type LocalStorageConfig struct {
 	BasePath      string `mapstructure:"base_path"`
@@ -435,12 +843,54 @@ type AuthConfig struct {
 	AzureAD AzureADConfig `mapstructure:"azure_ad"`
 	SAML    SAMLConfig    `mapstructure:"saml"`
 	LDAP    LDAPConfig    `mapstructure:"ldap"`
+	// TokenExchange lets CI systems trade an OIDC ID token for a short-lived
+	// publish token (POST /api/v1/auth/token-exchange).
+	TokenExchange TokenExchangeConfig `mapstructure:"token_exchange"`
+	// CLITokens controls the read-only tokens issued to the Terraform CLI
+	// (/api/v1/users/me/cli-tokens).
+	CLITokens CLITokensConfig `mapstructure:"cli_tokens"`
+	// ClockSkewLeeway is how far a token's exp or nbf claim may be off from
+	// the server's clock before it is rejected (default 30s, max 5m).
+	ClockSkewLeeway time.Duration `mapstructure:"clock_skew_leeway"`
+	// GlobalAdmins lists the users, by ID or email address, whose session
+	// tokens keep the admin scope when an organization scope ceiling would
+	// remove it. Comma-separated in TFR_AUTH_GLOBAL_ADMINS.
+	GlobalAdmins []string `mapstructure:"global_admins"`
+}
+
+// CLITokensConfig controls tokens issued to the Terraform CLI. They carry
+// the `terraform-cli` audience and are accepted only on the registry
+// protocol routes.
+type CLITokensConfig struct {
+	// TokenTTL is the lifetime of an issued CLI token (default 720h, max
+	// 8760h; zero means the default).
+	TokenTTL time.Duration `mapstructure:"token_ttl"`
+}
+
+// TokenExchangeConfig controls the CI OIDC token exchange. Which repositories
+// may publish to which namespace is decided by the per-namespace trust rules
+// managed through the admin API; this block only names the identity providers
+// whose tokens are accepted at all.
+type TokenExchangeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// TrustedIssuers lists the OIDC issuers whose ID tokens may be exchanged
+	// (default: GitHub Actions). Trust rules may only reference these issuers.
+	TrustedIssuers []string `mapstructure:"trusted_issuers"`
+	// Audience is the `aud` claim CI jobs must request for their ID token,
+	// e.g. `core.getIDToken('terraform-registry')` in GitHub Actions.
+	Audience string `mapstructure:"audience"`
+	// TokenTTL is the lifetime of the issued publish token (default 15m, max 1h).
+	TokenTTL time.Duration `mapstructure:"token_ttl"`
 }
 
 // APIKeyConfig holds API key authentication configuration
 type APIKeyConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
 	Prefix  string `mapstructure:"prefix"`
+	// ClaimTTL is how long a one-time retrieval link for a new or rotated key
+	// stays claimable. The key is revoked if it is not claimed in time
+	// (default 15m, max 24h).
+	ClaimTTL time.Duration `mapstructure:"claim_ttl"`
 }
 
 // OIDCGroupMapping maps a single IdP group to an organization and role template.
@@ -634,6 +1084,10 @@ type TLSConfig struct {
 	Enabled  bool   `mapstructure:"enabled"`
 	CertFile string `mapstructure:"cert_file"`
 	KeyFile  string `mapstructure:"key_file"`
+	// CertPEM and KeyPEM hold the key pair resolved from secrets.tls_cert and
+	// secrets.tls_key; when set they are served instead of the files.
+	CertPEM []byte `mapstructure:"-"`
+	KeyPEM  []byte `mapstructure:"-"`
 }
 
 // LoggingConfig holds logging configuration
@@ -827,6 +1281,7 @@ func bindEnvVars(v *viper.Viper) error {
 		"server.default_language",
 		"server.trusted_proxies",
 		"server.host_aliases",
+		"server.environment",
 
 		// Storage
 		"storage.default_backend",
@@ -850,12 +1305,16 @@ func bindEnvVars(v *viper.Viper) error {
 		"storage.gcs.credentials_file",
 		"storage.gcs.credentials_json",
 		"storage.gcs.endpoint",
+		"storage.artifactory.base_url",
+		"storage.artifactory.repository",
+		"storage.artifactory.access_token",
 		"storage.local.base_path",
 		"storage.local.serve_directly",
 
 		// Auth
 		"auth.api_keys.enabled",
 		"auth.api_keys.prefix",
+		"auth.api_keys.claim_ttl",
 		"auth.oidc.enabled",
 		"auth.oidc.issuer_url",
 		"auth.oidc.client_id",
@@ -871,6 +1330,13 @@ func bindEnvVars(v *viper.Viper) error {
 		"auth.azure_ad.client_id",
 		"auth.azure_ad.client_secret",
 		"auth.azure_ad.redirect_url",
+		"auth.token_exchange.enabled",
+		"auth.token_exchange.trusted_issuers",
+		"auth.token_exchange.audience",
+		"auth.token_exchange.token_ttl",
+		"auth.cli_tokens.token_ttl",
+		"auth.clock_skew_leeway",
+		"auth.global_admins",
 
 		// Multi-tenancy
 		"multi_tenancy.enabled",
@@ -952,6 +1418,93 @@ func bindEnvVars(v *viper.Viper) error {
 		"webhooks.max_retries",
 		"webhooks.retry_interval_mins",
 
+		// Registry protocol
+		"protocol.deprecation_warnings",
+		"protocol.filter_by_terraform_version",
+
+		// Module pull-through proxy
+		"module_proxy.enabled",
+		"module_proxy.allowed_hosts",
+		"remote_upload.enabled",
+		"remote_upload.allowed_schemes",
+		"remote_upload.allowed_hosts",
+		"remote_upload.timeout",
+
+		// SCM connector HTTP client
+		"scm.timeout",
+		"scm.max_retries",
+		"scm.max_retry_wait",
+		"scm.max_concurrent",
+		"scm.repository_name_check",
+		"scm.max_archive_size_mb",
+		"scm.backfill_interval",
+
+		// Artifact immutability
+		"immutable_artifacts.enabled",
+		"immutable_artifacts.retention_days",
+
+		// Namespace claims
+		"namespace_claims.reserved_patterns",
+		"namespace_claims.auto_approve_verified_domain",
+
+		// Public statistics
+		"public_stats.enabled",
+		"public_stats.refresh_interval",
+
+		// Storage consistency checker
+		"storage_consistency.enabled",
+		"storage_consistency.interval",
+		"storage_consistency.full_scan",
+		"storage_consistency.sample_size",
+		"storage_consistency.mark_broken",
+
+		// Module content index
+		"code_index.enabled",
+		"code_index.interval",
+		"code_index.max_bytes_per_version",
+		"code_index.batch_size",
+
+		// Download HEAD requests
+		"download_head.mode",
+
+		// Usage reports
+		"usage_reports.stale_after_days",
+
+		// Secrets backend
+		"secrets.backend",
+		"secrets.encryption_key",
+		"secrets.encryption_key_previous",
+		"secrets.database_password",
+		"secrets.tls_cert",
+		"secrets.tls_key",
+		"secrets.refresh_interval",
+		"secrets.vault.address",
+		"secrets.vault.namespace",
+		"secrets.vault.mount",
+		"secrets.vault.auth_method",
+		"secrets.vault.token",
+		"secrets.vault.token_file",
+		"secrets.vault.role",
+		"secrets.vault.kubernetes_mount",
+		"secrets.vault.kubernetes_token_file",
+		"secrets.aws.region",
+		"secrets.aws.endpoint",
+
+		// Scratch directory
+		"scratch.dir",
+		"scratch.max_size_mb",
+		"scratch.orphan_max_age",
+		"scratch.cleanup_interval",
+
+		// Mirror re-signing
+		"mirror_signing.enabled",
+		"mirror_signing.private_key",
+		"mirror_signing.private_key_file",
+		"mirror_signing.passphrase",
+		"mirror_signing.signer_url",
+		"mirror_signing.signer_token",
+		"mirror_signing.public_key",
+
 		// Suite
 		"suite.sibling_url",
 		"suite.poll_interval",
@@ -970,6 +1523,22 @@ func bindEnvVars(v *viper.Viper) error {
 
 // Load loads configuration from file and environment variables
 func Load(configPath string) (*Config, error) {
+	cfg, err := LoadUnvalidated(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadUnvalidated loads configuration like Load but skips Validate, so a
+// caller such as `config validate` can report every problem itself.
+func LoadUnvalidated(configPath string) (*Config, error) {
 	v := viper.New()
 
 	// Set default values
@@ -1022,16 +1591,18 @@ func Load(configPath string) (*Config, error) {
 	cfg.Auth.OIDC.ClientSecret = expandEnv(cfg.Auth.OIDC.ClientSecret)
 	cfg.Auth.AzureAD.ClientSecret = expandEnv(cfg.Auth.AzureAD.ClientSecret)
 	cfg.Notifications.SMTP.Password = expandEnv(cfg.Notifications.SMTP.Password)
+	cfg.Secrets.Vault.Token = expandEnv(cfg.Secrets.Vault.Token)
+
+	// Resolve secrets from the configured backend before the identity
+	// database inherits the app database's password.
+	if err := cfg.resolveSecrets(context.Background()); err != nil {
+		return nil, err
+	}
 
 	// Identity database inherits any unset field from the app database. Runs after
 	// expandEnv so an inherited password is the expanded value.
 	cfg.resolveIdentityDatabase()
 
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
-	}
-
 	return &cfg, nil
 }
 
@@ -1047,6 +1618,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.default_language", "en")
 	v.SetDefault("server.trusted_proxies", []string{})
 	v.SetDefault("server.host_aliases", []string{})
+	v.SetDefault("server.environment", "")
 
 	// Redis defaults (empty host = disabled, in-memory fallback used)
 	v.SetDefault("redis.host", "")
@@ -1085,10 +1657,18 @@ func setDefaults(v *viper.Viper) {
 	// Auth defaults
 	v.SetDefault("auth.api_keys.enabled", true)
 	v.SetDefault("auth.api_keys.prefix", "tfr_")
+	v.SetDefault("auth.api_keys.claim_ttl", "15m")
 	v.SetDefault("auth.oidc.enabled", false)
 	v.SetDefault("auth.oidc.scopes", []string{"openid", "email", "profile"})
 	v.SetDefault("auth.oidc.require_verified_email", true)
 	v.SetDefault("auth.azure_ad.enabled", false)
+	v.SetDefault("auth.token_exchange.enabled", false)
+	v.SetDefault("auth.token_exchange.trusted_issuers", []string{"https://token.actions.githubusercontent.com"})
+	v.SetDefault("auth.token_exchange.audience", "terraform-registry")
+	v.SetDefault("auth.token_exchange.token_ttl", "15m")
+	v.SetDefault("auth.cli_tokens.token_ttl", "720h")
+	v.SetDefault("auth.clock_skew_leeway", "30s")
+	v.SetDefault("auth.global_admins", []string{})
 
 	// Multi-tenancy defaults
 	v.SetDefault("multi_tenancy.enabled", false)
@@ -1164,6 +1744,75 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("webhooks.max_retries", 3)
 	v.SetDefault("webhooks.retry_interval_mins", 2)
 
+	// Registry protocol defaults
+	v.SetDefault("protocol.deprecation_warnings", false)
+	v.SetDefault("protocol.filter_by_terraform_version", false)
+
+	// Module pull-through proxy defaults
+	v.SetDefault("module_proxy.enabled", false)
+	v.SetDefault("module_proxy.allowed_hosts", []string{"registry.terraform.io"})
+
+	// Remote upload (publish from URL) defaults
+	v.SetDefault("remote_upload.enabled", false)
+	v.SetDefault("remote_upload.allowed_schemes", []string{"https"})
+	v.SetDefault("remote_upload.allowed_hosts", []string{})
+	v.SetDefault("remote_upload.timeout", "10m")
+
+	// SCM connector HTTP client defaults
+	v.SetDefault("scm.timeout", "30s")
+	v.SetDefault("scm.max_retries", 3)
+	v.SetDefault("scm.max_retry_wait", "60s")
+	v.SetDefault("scm.max_concurrent", 8)
+	v.SetDefault("scm.repository_name_check", RepositoryNameCheckWarn)
+	v.SetDefault("scm.max_archive_size_mb", defaultSCMArchiveSizeMB)
+	v.SetDefault("scm.backfill_interval", "10s")
+
+	// Artifact immutability defaults
+	v.SetDefault("immutable_artifacts.enabled", false)
+	v.SetDefault("immutable_artifacts.retention_days", 0)
+
+	// Namespace claim defaults
+	v.SetDefault("namespace_claims.reserved_patterns", []string{})
+	v.SetDefault("namespace_claims.auto_approve_verified_domain", false)
+
+	// Public statistics defaults
+	v.SetDefault("public_stats.enabled", true)
+	v.SetDefault("public_stats.refresh_interval", "5m")
+
+	// Storage consistency checker defaults
+	v.SetDefault("storage_consistency.enabled", false)
+	v.SetDefault("storage_consistency.interval", "24h")
+	v.SetDefault("storage_consistency.full_scan", false)
+	v.SetDefault("storage_consistency.sample_size", 500)
+	v.SetDefault("storage_consistency.mark_broken", false)
+
+	v.SetDefault("code_index.enabled", false)
+	v.SetDefault("code_index.interval", "1m")
+	v.SetDefault("code_index.max_bytes_per_version", 1<<20)
+	v.SetDefault("code_index.batch_size", 20)
+
+	// Download HEAD defaults
+	v.SetDefault("download_head.mode", DownloadHeadMetadata)
+
+	// Usage report defaults
+	v.SetDefault("usage_reports.stale_after_days", 30)
+
+	// Secrets backend defaults
+	v.SetDefault("secrets.backend", "env")
+	v.SetDefault("secrets.refresh_interval", "0s")
+	v.SetDefault("secrets.vault.mount", "secret")
+	v.SetDefault("secrets.vault.auth_method", "token")
+	v.SetDefault("secrets.vault.kubernetes_mount", "kubernetes")
+
+	// Scratch directory defaults
+	v.SetDefault("scratch.dir", filepath.Join(os.TempDir(), "terraform-registry"))
+	v.SetDefault("scratch.max_size_mb", 10240)
+	v.SetDefault("scratch.orphan_max_age", "1h")
+	v.SetDefault("scratch.cleanup_interval", "5m")
+
+	// Mirror re-signing defaults
+	v.SetDefault("mirror_signing.enabled", false)
+
 	// CVE polling defaults
 	v.SetDefault("cve.enabled", false)
 	v.SetDefault("cve.interval_hours", 24)
@@ -1197,119 +1846,169 @@ func expandEnv(s string) string {
 	return os.ExpandEnv(s)
 }
 
-// Validate validates the configuration
+// Validate validates the configuration. Every problem found is reported, as
+// a ValidationErrors keyed by field path, rather than only the first.
 func (c *Config) Validate() error {
-	// Validate server
-	if c.Server.Port < 1 || c.Server.Port > 65535 {
-		return fmt.Errorf("invalid server port: %d", c.Server.Port)
-	}
+	var errs ValidationErrors
+
+	// Validate server; the port is checked with the other listeners below
 	if c.Server.BaseURL == "" {
-		return fmt.Errorf("server.base_url is required")
+		errs.Add("server.base_url", "is required")
+	} else {
+		validateHTTPURL(&errs, "server.base_url", c.Server.BaseURL)
+	}
+	if c.Server.PublicURL != "" {
+		validateHTTPURL(&errs, "server.public_url", c.Server.PublicURL)
 	}
 	validLangs := map[string]bool{"en": true, "es": true, "fr": true, "de": true, "ja": true, "pt": true, "nl": true, "nb": true, "zh": true, "it": true}
 	if !validLangs[c.Server.DefaultLanguage] {
-		return fmt.Errorf("invalid server.default_language: %q (must be one of en, es, fr, de, ja, pt, nl, nb, zh, it)", c.Server.DefaultLanguage)
+		errs.Add("server.default_language", "invalid language %q (must be one of en, es, fr, de, ja, pt, nl, nb, zh, it)", c.Server.DefaultLanguage)
 	}
+	switch c.Server.Environment {
+	case "", "development", "test", "staging", "production":
+	default:
+		errs.Add("server.environment", "invalid environment %q (must be one of development, test, staging, production)", c.Server.Environment)
+	}
+	c.validateListeners(&errs)
 
 	// Validate database
 	if c.Database.Host == "" {
-		return fmt.Errorf("database.host is required")
+		errs.Add("database.host", "is required")
 	}
 	if c.Database.Name == "" {
-		return fmt.Errorf("database.name is required")
+		errs.Add("database.name", "is required")
 	}
 	if c.Database.User == "" {
-		return fmt.Errorf("database.user is required")
+		errs.Add("database.user", "is required")
 	}
 
 	// Validate storage backend
-	validBackends := map[string]bool{"azure": true, "s3": true, "gcs": true, "local": true}
+	validBackends := map[string]bool{"azure": true, "s3": true, "gcs": true, "artifactory": true, "local": true}
 	if !validBackends[c.Storage.DefaultBackend] {
-		return fmt.Errorf("invalid storage backend: %s (must be azure, s3, gcs, or local)", c.Storage.DefaultBackend)
+		errs.Add("storage.default_backend", "invalid storage backend %q (must be azure, s3, gcs, artifactory, or local)", c.Storage.DefaultBackend)
 	}
 
 	// Validate Azure storage if enabled
 	if c.Storage.DefaultBackend == "azure" {
 		if c.Storage.Azure.AccountName == "" {
-			return fmt.Errorf("storage.azure.account_name is required when using Azure backend")
+			errs.Add("storage.azure.account_name", "is required when using Azure backend")
 		}
 		if c.Storage.Azure.AccountKey == "" {
-			return fmt.Errorf("storage.azure.account_key is required when using Azure backend")
+			errs.Add("storage.azure.account_key", "is required when using Azure backend")
 		}
 		if c.Storage.Azure.ContainerName == "" {
-			return fmt.Errorf("storage.azure.container_name is required when using Azure backend")
+			errs.Add("storage.azure.container_name", "is required when using Azure backend")
 		}
 	}
 
 	// Validate S3 storage if enabled
 	if c.Storage.DefaultBackend == "s3" {
 		if c.Storage.S3.Bucket == "" {
-			return fmt.Errorf("storage.s3.bucket is required when using S3 backend")
+			errs.Add("storage.s3.bucket", "is required when using S3 backend")
 		}
 		if c.Storage.S3.Region == "" {
-			return fmt.Errorf("storage.s3.region is required when using S3 backend")
+			errs.Add("storage.s3.region", "is required when using S3 backend")
 		}
 	}
 
 	// Validate GCS storage if enabled
 	if c.Storage.DefaultBackend == "gcs" {
 		if c.Storage.GCS.Bucket == "" {
-			return fmt.Errorf("storage.gcs.bucket is required when using GCS backend")
+			errs.Add("storage.gcs.bucket", "is required when using GCS backend")
+		}
+	}
+
+	// Validate Artifactory storage if enabled
+	if c.Storage.DefaultBackend == "artifactory" {
+		if c.Storage.Artifactory.BaseURL == "" {
+			errs.Add("storage.artifactory.base_url", "is required when using Artifactory backend")
 		}
 	}
 
 	// Validate local storage if enabled
 	if c.Storage.DefaultBackend == "local" {
 		if c.Storage.Local.BasePath == "" {
-			return fmt.Errorf("storage.local.base_path is required when using local backend")
+			errs.Add("storage.local.base_path", "is required when using local backend")
 		}
 	}
 
+	if c.Auth.APIKeys.ClaimTTL < 0 || c.Auth.APIKeys.ClaimTTL > 24*time.Hour {
+		errs.Add("auth.api_keys.claim_ttl", "must be between 0 and 24h")
+	}
+
 	// Validate OIDC if enabled
 	if c.Auth.OIDC.Enabled {
 		if c.Auth.OIDC.IssuerURL == "" {
-			return fmt.Errorf("auth.oidc.issuer_url is required when OIDC is enabled")
+			errs.Add("auth.oidc.issuer_url", "is required when OIDC is enabled")
 		}
 		if c.Auth.OIDC.ClientID == "" {
-			return fmt.Errorf("auth.oidc.client_id is required when OIDC is enabled")
+			errs.Add("auth.oidc.client_id", "is required when OIDC is enabled")
 		}
 		if c.Auth.OIDC.ClientSecret == "" {
-			return fmt.Errorf("auth.oidc.client_secret is required when OIDC is enabled")
+			errs.Add("auth.oidc.client_secret", "is required when OIDC is enabled")
 		}
 	}
 
+	// Validate CI token exchange if enabled
+	if c.Auth.TokenExchange.Enabled {
+		if len(c.Auth.TokenExchange.TrustedIssuers) == 0 {
+			errs.Add("auth.token_exchange.trusted_issuers", "must not be empty when token exchange is enabled")
+		}
+		for _, iss := range c.Auth.TokenExchange.TrustedIssuers {
+			if !strings.HasPrefix(iss, "https://") {
+				errs.Add("auth.token_exchange.trusted_issuers", "entry %q must be an https:// URL", iss)
+			}
+		}
+		if c.Auth.TokenExchange.Audience == "" {
+			errs.Add("auth.token_exchange.audience", "is required when token exchange is enabled")
+		}
+		if c.Auth.TokenExchange.TokenTTL <= 0 || c.Auth.TokenExchange.TokenTTL > time.Hour {
+			errs.Add("auth.token_exchange.token_ttl", "must be between 1s and 1h")
+		}
+	}
+
+	// CLI token TTL (zero falls back to the default)
+	if ttl := c.Auth.CLITokens.TokenTTL; ttl != 0 && (ttl < time.Minute || ttl > 8760*time.Hour) {
+		errs.Add("auth.cli_tokens.token_ttl", "must be between 1m and 8760h")
+	}
+
+	// Token clock skew leeway
+	if leeway := c.Auth.ClockSkewLeeway; leeway < 0 || leeway > 5*time.Minute {
+		errs.Add("auth.clock_skew_leeway", "must be between 0s and 5m")
+	}
+
 	// Validate Azure AD if enabled
 	if c.Auth.AzureAD.Enabled {
 		if c.Auth.AzureAD.TenantID == "" {
-			return fmt.Errorf("auth.azure_ad.tenant_id is required when Azure AD is enabled")
+			errs.Add("auth.azure_ad.tenant_id", "is required when Azure AD is enabled")
 		}
 		if c.Auth.AzureAD.ClientID == "" {
-			return fmt.Errorf("auth.azure_ad.client_id is required when Azure AD is enabled")
+			errs.Add("auth.azure_ad.client_id", "is required when Azure AD is enabled")
 		}
 		if c.Auth.AzureAD.ClientSecret == "" {
-			return fmt.Errorf("auth.azure_ad.client_secret is required when Azure AD is enabled")
+			errs.Add("auth.azure_ad.client_secret", "is required when Azure AD is enabled")
 		}
 	}
 
 	// Validate TLS if enabled
 	if c.Security.TLS.Enabled {
-		if c.Security.TLS.CertFile == "" {
-			return fmt.Errorf("security.tls.cert_file is required when TLS is enabled")
+		if c.Security.TLS.CertFile == "" && len(c.Security.TLS.CertPEM) == 0 {
+			errs.Add("security.tls.cert_file", "is required when TLS is enabled")
 		}
-		if c.Security.TLS.KeyFile == "" {
-			return fmt.Errorf("security.tls.key_file is required when TLS is enabled")
+		if c.Security.TLS.KeyFile == "" && len(c.Security.TLS.KeyPEM) == 0 {
+			errs.Add("security.tls.key_file", "is required when TLS is enabled")
 		}
 	}
 
 	// Validate logging level
 	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	if !validLevels[c.Logging.Level] {
-		return fmt.Errorf("invalid logging level: %s (must be debug, info, warn, or error)", c.Logging.Level)
+		errs.Add("logging.level", "invalid logging level %q (must be debug, info, warn, or error)", c.Logging.Level)
 	}
 
 	if c.Scanning.Enabled {
 		if c.Scanning.BinaryPath == "" {
-			return fmt.Errorf("scanning.binary_path is required when scanning.enabled=true")
+			errs.Add("scanning.binary_path", "is required when scanning.enabled=true")
 		}
 		validTools := []string{"trivy", "terrascan", "snyk", "checkov", "custom"}
 		toolValid := false
@@ -1320,7 +2019,145 @@ func (c *Config) Validate() error {
 			}
 		}
 		if !toolValid {
-			return fmt.Errorf("scanning.tool must be one of: %s", strings.Join(validTools, ", "))
+			errs.Add("scanning.tool", "must be one of: %s", strings.Join(validTools, ", "))
+		}
+	}
+
+	if c.ModuleProxy.Enabled {
+		if len(c.ModuleProxy.AllowedHosts) == 0 {
+			errs.Add("module_proxy.allowed_hosts", "must list at least one registry hostname when module_proxy.enabled=true")
+		}
+		for _, h := range c.ModuleProxy.AllowedHosts {
+			h = strings.TrimSpace(h)
+			if h == "" || strings.ContainsAny(h, "/:@ ") {
+				errs.Add("module_proxy.allowed_hosts", "%q is not a bare hostname", h)
+			}
+		}
+	}
+
+	if c.RemoteUpload.Enabled {
+		if len(c.RemoteUpload.AllowedSchemes) == 0 {
+			errs.Add("remote_upload.allowed_schemes", "must list at least one scheme when remote_upload.enabled=true")
+		}
+		for _, s := range c.RemoteUpload.AllowedSchemes {
+			if s = strings.ToLower(strings.TrimSpace(s)); s != "http" && s != "https" {
+				errs.Add("remote_upload.allowed_schemes", "%q is not supported (must be http or https)", s)
+			}
+		}
+		for _, h := range c.RemoteUpload.AllowedHosts {
+			h = strings.TrimSpace(h)
+			if h == "" || strings.ContainsAny(h, "/:@ ") {
+				errs.Add("remote_upload.allowed_hosts", "%q is not a bare hostname", h)
+			}
+		}
+		if c.RemoteUpload.Timeout < 0 {
+			errs.Add("remote_upload.timeout", "must not be negative")
+		}
+	}
+
+	if c.SCM.Timeout < 0 {
+		errs.Add("scm.timeout", "must not be negative")
+	}
+	if c.SCM.MaxRetries < 0 {
+		errs.Add("scm.max_retries", "must not be negative")
+	}
+	if c.SCM.MaxRetryWait < 0 {
+		errs.Add("scm.max_retry_wait", "must not be negative")
+	}
+	if c.SCM.MaxConcurrent < 0 {
+		errs.Add("scm.max_concurrent", "must not be negative")
+	}
+	if c.SCM.MaxArchiveSizeMB < 0 {
+		errs.Add("scm.max_archive_size_mb", "must not be negative")
+	}
+	if c.SCM.BackfillInterval < 0 {
+		errs.Add("scm.backfill_interval", "must not be negative")
+	}
+	if c.Policy.MaxArchiveSizeMB < 0 {
+		errs.Add("policy.max_archive_size_mb", "must not be negative")
+	}
+	switch c.SCM.RepositoryNameCheck {
+	case "", RepositoryNameCheckWarn, RepositoryNameCheckBlock, RepositoryNameCheckOff:
+	default:
+		errs.Add("scm.repository_name_check", "must be one of: warn, block, off")
+	}
+
+	if c.ImmutableArtifacts.RetentionDays < 0 {
+		errs.Add("immutable_artifacts.retention_days", "must not be negative")
+	}
+
+	for _, p := range c.NamespaceClaims.ReservedPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			errs.Add("namespace_claims.reserved_patterns", "invalid regular expression %q: %v", p, err)
+		}
+	}
+
+	if c.PublicStats.RefreshInterval < 0 {
+		errs.Add("public_stats.refresh_interval", "must not be negative")
+	}
+
+	if c.StorageConsistency.Enabled {
+		if c.StorageConsistency.Interval <= 0 {
+			errs.Add("storage_consistency.interval", "must be positive")
+		}
+		if !c.StorageConsistency.FullScan && c.StorageConsistency.SampleSize <= 0 {
+			errs.Add("storage_consistency.sample_size", "must be positive unless full_scan is set")
+		}
+	}
+
+	if c.CodeIndex.Enabled {
+		if c.CodeIndex.Interval <= 0 {
+			errs.Add("code_index.interval", "must be positive")
+		}
+		if c.CodeIndex.MaxBytesPerVersion <= 0 {
+			errs.Add("code_index.max_bytes_per_version", "must be positive")
+		}
+		if c.CodeIndex.BatchSize <= 0 {
+			errs.Add("code_index.batch_size", "must be positive")
+		}
+	}
+
+	switch c.DownloadHead.Mode {
+	case "", DownloadHeadMetadata, DownloadHeadExistence, DownloadHeadDisabled:
+	default:
+		errs.Add("download_head.mode", "must be one of: metadata, existence, disabled")
+	}
+
+	if c.UsageReports.StaleAfterDays < 0 {
+		errs.Add("usage_reports.stale_after_days", "must not be negative")
+	}
+
+	c.validateSecrets(&errs)
+
+	if dir := c.Scratch.Dir; dir != "" {
+		clean := filepath.Clean(dir)
+		if clean == filepath.Dir(clean) || clean == filepath.Clean(os.TempDir()) {
+			errs.Add("scratch.dir", "must be a directory of its own, not %q: old entries in it are deleted", dir)
+		}
+	}
+	if c.Scratch.MaxSizeMB < 0 {
+		errs.Add("scratch.max_size_mb", "must not be negative")
+	}
+	if c.Scratch.OrphanMaxAge < 0 {
+		errs.Add("scratch.orphan_max_age", "must not be negative")
+	}
+	if c.Scratch.CleanupInterval < 0 {
+		errs.Add("scratch.cleanup_interval", "must not be negative")
+	}
+
+	if c.MirrorSigning.Enabled {
+		ms := c.MirrorSigning
+		hasKey := ms.PrivateKey != "" || ms.PrivateKeyFile != ""
+		switch {
+		case ms.PrivateKey != "" && ms.PrivateKeyFile != "":
+			errs.Add("mirror_signing", "set only one of private_key and private_key_file")
+		case hasKey && ms.SignerURL != "":
+			errs.Add("mirror_signing", "set either a private key or signer_url, not both")
+		case !hasKey && ms.SignerURL == "":
+			errs.Add("mirror_signing", "enabled=true requires private_key, private_key_file, or signer_url")
+		}
+		if ms.SignerURL != "" && ms.PublicKey == "" {
+			errs.Add("mirror_signing.public_key", "is required with mirror_signing.signer_url")
 		}
 	}
 
@@ -1328,7 +2165,19 @@ func (c *Config) Validate() error {
 	// or CIDR) before using it to validate the URLs below.
 	egressGuard, err := httpsafe.NewGuard(c.Security.Egress.Allowlist)
 	if err != nil {
-		return fmt.Errorf("security.egress.allowlist: %w", err)
+		errs.Add("security.egress.allowlist", "%v", err)
+		return errs.Err()
+	}
+
+	if c.MirrorSigning.Enabled && c.MirrorSigning.SignerURL != "" {
+		signerURL := c.MirrorSigning.SignerURL
+		if parsed, err := url.Parse(signerURL); err != nil {
+			errs.Add("mirror_signing.signer_url", "invalid URL: %v", err)
+		} else if parsed.Scheme != "https" && !egressGuard.HostExempt(parsed.Hostname()) {
+			errs.Add("mirror_signing.signer_url", "must use https (got %q); add the host to security.egress.allowlist if plain HTTP to an internal signer is intentional", parsed.Scheme)
+		} else if err := egressGuard.ValidateURL(signerURL); err != nil {
+			errs.Add("mirror_signing.signer_url", "%v", err)
+		}
 	}
 
 	// Validate the policy bundle URL at config-load time: bundle_url is not
@@ -1337,19 +2186,16 @@ func (c *Config) Validate() error {
 	// cloud-metadata address, and must use HTTPS unless the host is
 	// allow-listed (see internal/policy/bundle_loader.go).
 	if c.Policy.Enabled && c.Policy.BundleURL != "" {
-		parsed, err := url.Parse(c.Policy.BundleURL)
-		if err != nil {
-			return fmt.Errorf("policy.bundle_url: invalid URL: %w", err)
-		}
-		if parsed.Scheme != "https" && !egressGuard.HostExempt(parsed.Hostname()) {
-			return fmt.Errorf("policy.bundle_url must use https (got %q); add the host to security.egress.allowlist if plain HTTP to an internal mirror is intentional", parsed.Scheme)
-		}
-		if err := egressGuard.ValidateURL(c.Policy.BundleURL); err != nil {
-			return fmt.Errorf("policy.bundle_url: %w", err)
+		if parsed, err := url.Parse(c.Policy.BundleURL); err != nil {
+			errs.Add("policy.bundle_url", "invalid URL: %v", err)
+		} else if parsed.Scheme != "https" && !egressGuard.HostExempt(parsed.Hostname()) {
+			errs.Add("policy.bundle_url", "must use https (got %q); add the host to security.egress.allowlist if plain HTTP to an internal mirror is intentional", parsed.Scheme)
+		} else if err := egressGuard.ValidateURL(c.Policy.BundleURL); err != nil {
+			errs.Add("policy.bundle_url", "%v", err)
 		}
 	}
 
-	return nil
+	return errs.Err()
 }
 
 // GetDSN returns the PostgreSQL connection string
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // ---------------------------------------------------------------------------
@@ -256,6 +259,95 @@ func TestValidate(t *testing.T) {
 		}
 	})
 
+	t.Run("artifactory backend missing base_url", func(t *testing.T) {
+		cfg := minimalValidConfig()
+		cfg.Storage.DefaultBackend = "artifactory"
+		cfg.Storage.Artifactory = ArtifactoryStorageConfig{Repository: "terraform-local"}
+		if err := cfg.Validate(); err == nil {
+			t.Error("Validate() expected error for missing artifactory base_url, got nil")
+		}
+	})
+
+	t.Run("module proxy enabled without hosts", func(t *testing.T) {
+		cfg := minimalValidConfig()
+		cfg.ModuleProxy = ModuleProxyConfig{Enabled: true}
+		if err := cfg.Validate(); err == nil {
+			t.Error("Validate() expected error for empty module_proxy.allowed_hosts, got nil")
+		}
+	})
+
+	t.Run("module proxy host with scheme", func(t *testing.T) {
+		cfg := minimalValidConfig()
+		cfg.ModuleProxy = ModuleProxyConfig{Enabled: true, AllowedHosts: []string{"https://registry.terraform.io"}}
+		if err := cfg.Validate(); err == nil {
+			t.Error("Validate() expected error for module_proxy host with scheme, got nil")
+		}
+	})
+
+	t.Run("module proxy valid", func(t *testing.T) {
+		cfg := minimalValidConfig()
+		cfg.ModuleProxy = ModuleProxyConfig{Enabled: true, AllowedHosts: []string{"registry.terraform.io"}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() unexpected error: %v", err)
+		}
+		if !cfg.ModuleProxy.IsAllowedHost("Registry.Terraform.io") {
+			t.Error("IsAllowedHost should match case-insensitively")
+		}
+		if cfg.ModuleProxy.IsAllowedHost("example.com") {
+			t.Error("IsAllowedHost matched an unlisted host")
+		}
+	})
+
+	t.Run("remote upload unsupported scheme", func(t *testing.T) {
+		cfg := minimalValidConfig()
+		cfg.RemoteUpload = RemoteUploadConfig{Enabled: true, AllowedSchemes: []string{"ftp"}}
+		if err := cfg.Validate(); err == nil {
+			t.Error("Validate() expected error for remote_upload scheme ftp, got nil")
+		}
+	})
+
+	t.Run("remote upload valid", func(t *testing.T) {
+		cfg := minimalValidConfig()
+		cfg.RemoteUpload = RemoteUploadConfig{Enabled: true, AllowedSchemes: []string{"https"}, AllowedHosts: []string{"artifacts.example.com", "*.blob.example.net"}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() unexpected error: %v", err)
+		}
+		for host, want := range map[string]bool{
+			"artifacts.example.com":      true,
+			"ci.blob.example.net":        true,
+			"blob.example.net":           false,
+			"artifacts.example.com.evil": false,
+		} {
+			if got := cfg.RemoteUpload.IsAllowedHost(host); got != want {
+				t.Errorf("IsAllowedHost(%q) = %v, want %v", host, got, want)
+			}
+		}
+	})
+
+	t.Run("mirror signing", func(t *testing.T) {
+		for _, tt := range []struct {
+			name    string
+			ms      MirrorSigningConfig
+			wantErr bool
+		}{
+			{"no key source", MirrorSigningConfig{Enabled: true}, true},
+			{"key and signer", MirrorSigningConfig{Enabled: true, PrivateKey: "k", SignerURL: "https://signer.example.com/sign", PublicKey: "p"}, true},
+			{"key and key file", MirrorSigningConfig{Enabled: true, PrivateKey: "k", PrivateKeyFile: "/etc/key.asc"}, true},
+			{"signer without public key", MirrorSigningConfig{Enabled: true, SignerURL: "https://signer.example.com/sign"}, true},
+			{"plain http signer", MirrorSigningConfig{Enabled: true, SignerURL: "http://signer.example.com/sign", PublicKey: "p"}, true},
+			{"metadata signer", MirrorSigningConfig{Enabled: true, SignerURL: "https://169.254.169.254/sign", PublicKey: "p"}, true},
+			{"private key", MirrorSigningConfig{Enabled: true, PrivateKeyFile: "/etc/key.asc", Passphrase: "x"}, false},
+			{"signer", MirrorSigningConfig{Enabled: true, SignerURL: "https://signer.example.com/sign", PublicKey: "p"}, false},
+			{"disabled ignores fields", MirrorSigningConfig{SignerURL: "http://signer"}, false},
+		} {
+			cfg := minimalValidConfig()
+			cfg.MirrorSigning = tt.ms
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		}
+	})
+
 	t.Run("local backend missing base_path", func(t *testing.T) {
 		cfg := minimalValidConfig()
 		cfg.Storage.DefaultBackend = "local"
@@ -428,6 +520,148 @@ func TestValidate(t *testing.T) {
 		}
 	})
 }
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	cfg := minimalValidConfig()
+	cfg.Server.BaseURL = "registry.example.com"
+	cfg.Database.Host = ""
+	cfg.Storage.Local.BasePath = ""
+	cfg.Logging.Level = "verbose"
+
+	err := cfg.Validate()
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+	}
+	var fields []string
+	for _, p := range problems {
+		fields = append(fields, p.Field)
+	}
+	want := []string{"server.base_url", "database.host", "storage.local.base_path", "logging.level"}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+	if !strings.HasPrefix(err.Error(), "4 configuration problems:") || !strings.Contains(err.Error(), "\n  - database.host: is required") {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestValidate_BaseURLFormat(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		wantErr bool
+	}{
+		{"https://registry.example.com", false},
+		{"http://localhost:8080/registry", false},
+		{"registry.example.com", true},
+		{"ftp://registry.example.com", true},
+		{"https://", true},
+		{"https://registry.example.com?x=1", true},
+		{"https://registry example.com", true},
+	}
+	for _, tt := range tests {
+		cfg := minimalValidConfig()
+		cfg.Server.BaseURL = tt.baseURL
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("base_url %q: Validate() error = %v, wantErr %v", tt.baseURL, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate_ListenerPorts(t *testing.T) {
+	tests := []struct {
+		name      string
+		telemetry TelemetryConfig
+		wantField string
+	}{
+		{"defaults", TelemetryConfig{Metrics: MetricsConfig{Enabled: true, PrometheusPort: 9090}, Profiling: ProfilingConfig{Enabled: true, Port: 6060}}, ""},
+		{"metrics on API port", TelemetryConfig{Metrics: MetricsConfig{Enabled: true, PrometheusPort: 8080}}, "telemetry.metrics.prometheus_port"},
+		{"pprof on metrics port", TelemetryConfig{Metrics: MetricsConfig{Enabled: true, PrometheusPort: 9090}, Profiling: ProfilingConfig{Enabled: true, Port: 9090}}, "telemetry.profiling.port"},
+		{"disabled listener ignored", TelemetryConfig{Profiling: ProfilingConfig{Port: 8080}}, ""},
+		{"out of range", TelemetryConfig{Profiling: ProfilingConfig{Enabled: true, Port: 70000}}, "telemetry.profiling.port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := minimalValidConfig()
+			cfg.Telemetry = tt.telemetry
+			err := cfg.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			var problems ValidationErrors
+			if !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want one problem with %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestValidate_NamespaceClaimReservedPatterns(t *testing.T) {
+	cfg := minimalValidConfig()
+	cfg.NamespaceClaims.ReservedPatterns = []string{`^internal-`, `[`}
+	var problems ValidationErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "namespace_claims.reserved_patterns" {
+		t.Errorf("Validate() error = %v, want one problem with namespace_claims.reserved_patterns", err)
+	}
+
+	cfg.NamespaceClaims.ReservedPatterns = []string{`^internal-`}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidate_CLITokenTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{"unset uses default", 0, false},
+		{"thirty days", 720 * time.Hour, false},
+		{"too short", 30 * time.Second, true},
+		{"too long", 9000 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := minimalValidConfig()
+			cfg.Auth.CLITokens.TokenTTL = tt.ttl
+			err := cfg.Validate()
+			if tt.wantErr {
+				var problems ValidationErrors
+				if !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "auth.cli_tokens.token_ttl" {
+					t.Errorf("Validate() error = %v, want one problem with auth.cli_tokens.token_ttl", err)
+				}
+			} else if err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_ClockSkewLeeway(t *testing.T) {
+	for _, tt := range []struct {
+		leeway  time.Duration
+		wantErr bool
+	}{
+		{0, false},
+		{30 * time.Second, false},
+		{5 * time.Minute, false},
+		{-time.Second, true},
+		{10 * time.Minute, true},
+	} {
+		cfg := minimalValidConfig()
+		cfg.Auth.ClockSkewLeeway = tt.leeway
+		err := cfg.Validate()
+		var problems ValidationErrors
+		if tt.wantErr && (!errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "auth.clock_skew_leeway") {
+			t.Errorf("leeway %v: Validate() error = %v, want one problem with auth.clock_skew_leeway", tt.leeway, err)
+		} else if !tt.wantErr && err != nil {
+			t.Errorf("leeway %v: Validate() unexpected error: %v", tt.leeway, err)
+		}
+	}
+}
 
 // ---------------------------------------------------------------------------
 // Load – defaults and env var expansion
@@ -803,6 +1037,128 @@ func TestLoad_TrustedIssuersEnvOverride(t *testing.T) {
 	}
 }
 
+func TestLoad_PublicStats(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.PublicStats.Enabled || cfg.PublicStats.RefreshInterval != 5*time.Minute {
+		t.Errorf("default PublicStats = %+v, want enabled with a 5m refresh", cfg.PublicStats)
+	}
+
+	t.Setenv("TFR_PUBLIC_STATS_ENABLED", "false")
+	if cfg, err = Load(""); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.PublicStats.Enabled {
+		t.Error("TFR_PUBLIC_STATS_ENABLED=false did not disable public stats")
+	}
+}
+
+func TestLoad_UsageReports(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.UsageReports.StaleAfterDays != 30 {
+		t.Errorf("default StaleAfterDays = %d, want 30", cfg.UsageReports.StaleAfterDays)
+	}
+
+	t.Setenv("TFR_USAGE_REPORTS_STALE_AFTER_DAYS", "7")
+	if cfg, err = Load(""); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.UsageReports.StaleAfterDays != 7 {
+		t.Errorf("StaleAfterDays = %d, want 7 from TFR_USAGE_REPORTS_STALE_AFTER_DAYS", cfg.UsageReports.StaleAfterDays)
+	}
+}
+
+func TestLoad_ServerEnvironment(t *testing.T) {
+	t.Setenv("TFR_SERVER_ENVIRONMENT", "production")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Environment != "production" {
+		t.Errorf("Environment = %q, want production from TFR_SERVER_ENVIRONMENT", cfg.Server.Environment)
+	}
+
+	t.Setenv("TFR_SERVER_ENVIRONMENT", "prod")
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "server.environment") {
+		t.Errorf("Load with environment %q: err = %v, want a server.environment error", "prod", err)
+	}
+}
+
+func TestLoad_SecretsFileBackend(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	t.Setenv("ENCRYPTION_KEY", "")
+	t.Setenv("TFR_DATABASE_PASSWORD", "from-env")
+	t.Setenv("TFR_SECRETS_BACKEND", "file")
+	t.Setenv("TFR_SECRETS_ENCRYPTION_KEY", write("encryption-key", "0123456789abcdef0123456789abcdef\n"))
+	t.Setenv("TFR_SECRETS_DATABASE_PASSWORD", write("db-password", "from-file\n"))
+	t.Setenv("TFR_SECRETS_TLS_CERT", write("tls.crt", "CERT"))
+	t.Setenv("TFR_SECRETS_TLS_KEY", write("tls.key", "KEY"))
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Database.Password != "from-file" {
+		t.Errorf("Database.Password = %q, want from-file", cfg.Database.Password)
+	}
+	if cfg.IdentityDatabase.Password != "from-file" {
+		t.Errorf("IdentityDatabase.Password = %q, want the inherited from-file", cfg.IdentityDatabase.Password)
+	}
+	if got := os.Getenv("ENCRYPTION_KEY"); got != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("ENCRYPTION_KEY = %q, want the file contents", got)
+	}
+	if string(cfg.Security.TLS.CertPEM) != "CERT" || string(cfg.Security.TLS.KeyPEM) != "KEY" {
+		t.Errorf("TLS PEM = %q, %q; want CERT, KEY", cfg.Security.TLS.CertPEM, cfg.Security.TLS.KeyPEM)
+	}
+}
+
+func TestLoad_SecretsMissingFails(t *testing.T) {
+	t.Setenv("TFR_SECRETS_BACKEND", "file")
+	t.Setenv("TFR_SECRETS_DATABASE_PASSWORD", filepath.Join(t.TempDir(), "missing"))
+	_, err := Load("")
+	if err == nil || !strings.Contains(err.Error(), "secrets.database_password") {
+		t.Errorf("Load = %v, want an error naming secrets.database_password", err)
+	}
+}
+
+func TestValidate_Secrets(t *testing.T) {
+	cases := map[string]struct {
+		secrets SecretsConfig
+		field   string
+	}{
+		"unknown backend":         {SecretsConfig{Backend: "keychain"}, "secrets.backend"},
+		"vault without address":   {SecretsConfig{Backend: "vault", Vault: VaultSecrets{Token: "t"}}, "secrets.vault.address"},
+		"vault without token":     {SecretsConfig{Backend: "vault", Vault: VaultSecrets{Address: "https://vault:8200"}}, "secrets.vault.token"},
+		"kubernetes without role": {SecretsConfig{Backend: "vault", Vault: VaultSecrets{Address: "https://vault:8200", AuthMethod: "kubernetes"}}, "secrets.vault.role"},
+		"tls cert without key":    {SecretsConfig{Backend: "file", TLSCert: "/tls.crt"}, "secrets.tls_key"},
+		"refresh with env":        {SecretsConfig{Backend: "env", DatabasePassword: "DB_PASSWORD", RefreshInterval: time.Minute}, "secrets.refresh_interval"},
+		"refresh without ref":     {SecretsConfig{Backend: "file", RefreshInterval: time.Minute}, "secrets.refresh_interval"},
+	}
+	for name, tc := range cases {
+		cfg, err := Load("")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		cfg.Secrets = tc.secrets
+		err = cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), tc.field) {
+			t.Errorf("%s: Validate = %v, want an error on %s", name, err, tc.field)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // IdentityDatabase fallback
 // ---------------------------------------------------------------------------
@@ -860,3 +1216,134 @@ func TestLoad_IdentityDatabasePasswordFallbackExpanded(t *testing.T) {
 		t.Errorf("IdentityDatabase.Password = %q, want inherited+expanded s3cr3t-pw", cfg.IdentityDatabase.Password)
 	}
 }
+
+func TestValidate_SCMClient(t *testing.T) {
+	cfg := minimalValidConfig()
+	cfg.SCM.MaxRetries = -1
+	var problems ValidationErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "scm.max_retries" {
+		t.Errorf("Validate() error = %v, want one problem with scm.max_retries", err)
+	}
+
+	cfg.SCM.MaxRetries = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidate_SCMBackfillInterval(t *testing.T) {
+	cfg := minimalValidConfig()
+	cfg.SCM.BackfillInterval = -time.Second
+	var problems ValidationErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "scm.backfill_interval" {
+		t.Errorf("Validate() error = %v, want one problem with scm.backfill_interval", err)
+	}
+}
+
+func TestValidate_SCMRepositoryNameCheck(t *testing.T) {
+	cfg := minimalValidConfig()
+	cfg.SCM.RepositoryNameCheck = "strict"
+	var problems ValidationErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "scm.repository_name_check" {
+		t.Errorf("Validate() error = %v, want one problem with scm.repository_name_check", err)
+	}
+
+	for _, mode := range []string{"", RepositoryNameCheckWarn, RepositoryNameCheckBlock, RepositoryNameCheckOff} {
+		cfg.SCM.RepositoryNameCheck = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with %q: unexpected error: %v", mode, err)
+		}
+	}
+}
+
+func TestSCMArchiveSizeLimit(t *testing.T) {
+	cfg := minimalValidConfig()
+	if got := cfg.SCMArchiveSizeLimit(); got != 100<<20 {
+		t.Errorf("default = %d, want 100 MB", got)
+	}
+	cfg.SCM.MaxArchiveSizeMB = 20
+	if got := cfg.SCMArchiveSizeLimit(); got != 20<<20 {
+		t.Errorf("scm setting = %d, want 20 MB", got)
+	}
+	cfg.Policy.MaxArchiveSizeMB = 5
+	if got := cfg.SCMArchiveSizeLimit(); got != 5<<20 {
+		t.Errorf("policy setting = %d, want 5 MB", got)
+	}
+
+	cfg.Policy.MaxArchiveSizeMB = -1
+	var problems ValidationErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "policy.max_archive_size_mb" {
+		t.Errorf("Validate() error = %v, want one problem with policy.max_archive_size_mb", err)
+	}
+}
+
+func TestValidate_StorageConsistency(t *testing.T) {
+	cfg := minimalValidConfig()
+	cfg.StorageConsistency = StorageConsistencyConfig{Enabled: true, Interval: time.Hour}
+	var problems ValidationErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "storage_consistency.sample_size" {
+		t.Errorf("Validate() error = %v, want one problem with storage_consistency.sample_size", err)
+	}
+
+	cfg.StorageConsistency.FullScan = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with full_scan: unexpected error: %v", err)
+	}
+
+	cfg.StorageConsistency.Interval = 0
+	if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "storage_consistency.interval" {
+		t.Errorf("Validate() error = %v, want one problem with storage_consistency.interval", err)
+	}
+}
+
+func TestValidate_CodeIndex(t *testing.T) {
+	cfg := minimalValidConfig()
+	cfg.CodeIndex = CodeIndexConfig{Enabled: true, Interval: time.Minute, MaxBytesPerVersion: 1 << 20}
+	var problems ValidationErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "code_index.batch_size" {
+		t.Errorf("Validate() error = %v, want one problem with code_index.batch_size", err)
+	}
+
+	cfg.CodeIndex.BatchSize = 20
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	cfg.CodeIndex.Enabled = false
+	cfg.CodeIndex.MaxBytesPerVersion = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with code_index disabled: unexpected error: %v", err)
+	}
+}
+
+func TestValidate_DownloadHeadMode(t *testing.T) {
+	cfg := minimalValidConfig()
+	for _, mode := range []string{"", DownloadHeadMetadata, DownloadHeadExistence, DownloadHeadDisabled} {
+		cfg.DownloadHead.Mode = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with mode %q: unexpected error: %v", mode, err)
+		}
+	}
+
+	cfg.DownloadHead.Mode = "full"
+	var problems ValidationErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "download_head.mode" {
+		t.Errorf("Validate() error = %v, want one problem with download_head.mode", err)
+	}
+}
+
+func TestValidate_ScratchDir(t *testing.T) {
+	cfg := minimalValidConfig()
+	for _, dir := range []string{os.TempDir(), "/"} {
+		cfg.Scratch.Dir = dir
+		var problems ValidationErrors
+		if err := cfg.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Field != "scratch.dir" {
+			t.Errorf("Validate() with scratch.dir=%q: error = %v, want one problem with scratch.dir", dir, err)
+		}
+	}
+
+	cfg.Scratch.Dir = filepath.Join(os.TempDir(), "terraform-registry")
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with a dedicated scratch.dir: unexpected error: %v", err)
+	}
+}
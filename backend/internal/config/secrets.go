@@ -0,0 +1,152 @@
+// secrets.go resolves startup secrets from the configured secrets backend
+// (internal/secrets) and validates the secrets settings.
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/secrets"
+)
+
+// secretsResolveTimeout bounds how long Load waits on a remote secrets
+// backend before failing startup.
+const secretsResolveTimeout = 30 * time.Second
+
+// Provider returns the secrets.Provider for the configured backend.
+func (s *SecretsConfig) Provider(ctx context.Context) (secrets.Provider, error) {
+	return secrets.New(ctx, secrets.Options{
+		Backend: s.Backend,
+		Vault: secrets.VaultOptions{
+			Address:             s.Vault.Address,
+			Namespace:           s.Vault.Namespace,
+			Mount:               s.Vault.Mount,
+			AuthMethod:          s.Vault.AuthMethod,
+			Token:               s.Vault.Token,
+			TokenFile:           s.Vault.TokenFile,
+			Role:                s.Vault.Role,
+			KubernetesMount:     s.Vault.KubernetesMount,
+			KubernetesTokenFile: s.Vault.KubernetesTokenFile,
+		},
+		AWS: secrets.AWSOptions{
+			Region:   s.AWS.Region,
+			Endpoint: s.AWS.Endpoint,
+		},
+	})
+}
+
+// hasRefs reports whether any secret is resolved from the backend.
+func (s *SecretsConfig) hasRefs() bool {
+	return s.EncryptionKey != "" || s.EncryptionKeyPrevious != "" || s.DatabasePassword != "" ||
+		s.TLSCert != "" || s.TLSKey != ""
+}
+
+// resolveSecrets replaces the configured secrets with their values from the
+// secrets backend. ENCRYPTION_KEY and ENCRYPTION_KEY_PREVIOUS are set in the
+// process environment, where the rest of the registry reads them; the other
+// secrets go into their Config fields. An unreachable backend or missing
+// secret fails Load.
+func (c *Config) resolveSecrets(ctx context.Context) error {
+	s := &c.Secrets
+	if !s.hasRefs() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, secretsResolveTimeout)
+	defer cancel()
+
+	p, err := s.Provider(ctx)
+	if err != nil {
+		return fmt.Errorf("secrets backend %q: %w", s.Backend, err)
+	}
+	get := func(field, ref string) (string, error) {
+		v, err := p.Get(ctx, ref)
+		if err != nil {
+			return "", fmt.Errorf("resolving secrets.%s (%s) from the %s backend: %w", field, ref, s.Backend, err)
+		}
+		return v, nil
+	}
+
+	for _, env := range []struct{ field, ref, name string }{
+		{"encryption_key", s.EncryptionKey, "ENCRYPTION_KEY"},
+		{"encryption_key_previous", s.EncryptionKeyPrevious, "ENCRYPTION_KEY_PREVIOUS"},
+	} {
+		if env.ref == "" {
+			continue
+		}
+		v, err := get(env.field, env.ref)
+		if err != nil {
+			return err
+		}
+		if err := os.Setenv(env.name, v); err != nil {
+			return fmt.Errorf("setting %s: %w", env.name, err)
+		}
+	}
+	if s.DatabasePassword != "" {
+		if c.Database.Password, err = get("database_password", s.DatabasePassword); err != nil {
+			return err
+		}
+	}
+	if s.TLSCert != "" {
+		v, err := get("tls_cert", s.TLSCert)
+		if err != nil {
+			return err
+		}
+		c.Security.TLS.CertPEM = []byte(v)
+	}
+	if s.TLSKey != "" {
+		v, err := get("tls_key", s.TLSKey)
+		if err != nil {
+			return err
+		}
+		c.Security.TLS.KeyPEM = []byte(v)
+	}
+	return nil
+}
+
+// validateSecrets checks the secrets backend settings.
+func (c *Config) validateSecrets(errs *ValidationErrors) {
+	s := c.Secrets
+	switch s.Backend {
+	case "", secrets.BackendEnv, secrets.BackendFile:
+	case secrets.BackendVault:
+		if s.Vault.Address == "" {
+			errs.Add("secrets.vault.address", "is required when secrets.backend is vault")
+		} else {
+			validateHTTPURL(errs, "secrets.vault.address", s.Vault.Address)
+		}
+		switch s.Vault.AuthMethod {
+		case "", "token":
+			if s.Vault.Token == "" && s.Vault.TokenFile == "" {
+				errs.Add("secrets.vault.token", "token or token_file is required for token auth")
+			}
+		case "kubernetes":
+			if s.Vault.Role == "" {
+				errs.Add("secrets.vault.role", "is required for kubernetes auth")
+			}
+		default:
+			errs.Add("secrets.vault.auth_method", "must be token or kubernetes")
+		}
+	case secrets.BackendAWSSecretsManager:
+		if s.AWS.Endpoint != "" {
+			validateHTTPURL(errs, "secrets.aws.endpoint", s.AWS.Endpoint)
+		}
+	default:
+		errs.Add("secrets.backend", "must be one of: env, file, vault, aws_secrets_manager")
+	}
+
+	if (s.TLSCert == "") != (s.TLSKey == "") {
+		errs.Add("secrets.tls_key", "tls_cert and tls_key must be set together")
+	}
+	if s.RefreshInterval < 0 {
+		errs.Add("secrets.refresh_interval", "must not be negative")
+	} else if s.RefreshInterval > 0 {
+		if s.DatabasePassword == "" {
+			errs.Add("secrets.refresh_interval", "requires secrets.database_password")
+		}
+		if s.Backend == "" || s.Backend == secrets.BackendEnv {
+			errs.Add("secrets.refresh_interval", "has no effect with the env backend")
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// validation.go defines the error type Validate reports configuration problems
+// with, plus the listener and URL checks that need more than a field lookup.
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// FieldError is a single configuration problem. Field is the YAML path of the
+// offending setting (e.g. "storage.s3.bucket"), or the variable name for
+// settings only read from the environment (e.g. "ENCRYPTION_KEY").
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors collects every problem found in a configuration so they
+// can be fixed in one pass instead of one restart at a time.
+type ValidationErrors []FieldError
+
+// Add records a problem with field.
+func (v *ValidationErrors) Add(field, format string, args ...interface{}) {
+	*v = append(*v, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns v as an error, or nil when no problems were recorded.
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+func (v ValidationErrors) Error() string {
+	if len(v) == 1 {
+		return v[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d configuration problems:", len(v))
+	for _, e := range v {
+		b.WriteString("\n  - ")
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+// validateHTTPURL records a problem unless raw is an absolute http(s) URL with
+// a host. The registry builds download and callback links by appending paths,
+// so a query or fragment is rejected too.
+func validateHTTPURL(errs *ValidationErrors, field, raw string) {
+	u, err := url.Parse(raw)
+	switch {
+	case err != nil:
+		errs.Add(field, "invalid URL %q: %v", raw, err)
+	case u.Scheme != "http" && u.Scheme != "https":
+		errs.Add(field, "%q must start with http:// or https://", raw)
+	case u.Host == "":
+		errs.Add(field, "%q has no host", raw)
+	case u.RawQuery != "" || u.Fragment != "":
+		errs.Add(field, "%q must not contain a query or fragment", raw)
+	}
+}
+
+// validateListeners checks the ports of the API listener and the optional
+// metrics and pprof listeners, which all bind on the same host.
+func (c *Config) validateListeners(errs *ValidationErrors) {
+	type listener struct {
+		field string
+		port  int
+	}
+	listeners := []listener{{"server.port", c.Server.Port}}
+	if c.Telemetry.Metrics.Enabled {
+		listeners = append(listeners, listener{"telemetry.metrics.prometheus_port", c.Telemetry.Metrics.PrometheusPort})
+	}
+	if c.Telemetry.Profiling.Enabled {
+		listeners = append(listeners, listener{"telemetry.profiling.port", c.Telemetry.Profiling.Port})
+	}
+
+	seen := make(map[int]string, len(listeners))
+	for _, l := range listeners {
+		if l.port < 1 || l.port > 65535 {
+			errs.Add(l.field, "invalid port %d (must be 1-65535)", l.port)
+			continue
+		}
+		if other, ok := seen[l.port]; ok {
+			errs.Add(l.field, "port %d is already used by %s", l.port, other)
+			continue
+		}
+		seen[l.port] = l.field
+	}
+}
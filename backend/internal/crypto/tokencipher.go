@@ -143,6 +143,36 @@ func (tc *TokenCipher) Open(encodedCiphertext string) (string, error) {
 	return "", err
 }
 
+// HasPreviousKey reports whether a previous key is configured for decryption
+// fallback.
+func (tc *TokenCipher) HasPreviousKey() bool {
+	return tc.previousKey != nil
+}
+
+// NeedsReseal reports whether encodedCiphertext can only be opened with the
+// previous key, i.e. whether it has to be re-sealed before the previous key
+// can be retired. An empty value never does. It returns the error Open would
+// when neither key opens the value.
+func (tc *TokenCipher) NeedsReseal(encodedCiphertext string) (bool, error) {
+	if encodedCiphertext == "" {
+		return false, nil
+	}
+	ciphertext, err := base64.URLEncoding.DecodeString(encodedCiphertext)
+	if err != nil {
+		return false, ErrCiphertextCorrupted
+	}
+	_, err = tc.decryptWithKey(tc.masterKey, ciphertext)
+	if err == nil {
+		return false, nil
+	}
+	if tc.previousKey != nil && errors.Is(err, ErrDecryptionFailed) {
+		if _, prevErr := tc.decryptWithKey(tc.previousKey, ciphertext); prevErr == nil {
+			return true, nil
+		}
+	}
+	return false, err
+}
+
 // decryptWithKey performs AES-256-GCM decryption with the given key.
 func (tc *TokenCipher) decryptWithKey(key, ciphertext []byte) (string, error) {
 	blockCipher, err := aes.NewCipher(key)
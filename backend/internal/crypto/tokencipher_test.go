@@ -400,3 +400,48 @@ func TestDualKeyDecryption_NoPreviousKeyFallback(t *testing.T) {
 		t.Errorf("Open() without previous key error = %v, want %v", err, ErrDecryptionFailed)
 	}
 }
+
+func TestNeedsReseal(t *testing.T) {
+	oldKey := bytes.Repeat([]byte("o"), 32)
+	newKey := bytes.Repeat([]byte("n"), 32)
+	otherKey := bytes.Repeat([]byte("x"), 32)
+
+	oldCipher, _ := NewTokenCipher(oldKey)
+	newCipher, _ := NewTokenCipher(newKey)
+	otherCipher, _ := NewTokenCipher(otherKey)
+	sealedOld, _ := oldCipher.Seal("old")
+	sealedNew, _ := newCipher.Seal("new")
+	sealedOther, _ := otherCipher.Seal("other")
+
+	dual, _ := NewTokenCipherWithPrevious(newKey, oldKey)
+	if !dual.HasPreviousKey() {
+		t.Error("HasPreviousKey() = false, want true")
+	}
+	if newCipher.HasPreviousKey() {
+		t.Error("HasPreviousKey() = true for single-key cipher")
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    bool
+		wantErr error
+	}{
+		{"empty", "", false, nil},
+		{"current key", sealedNew, false, nil},
+		{"previous key", sealedOld, true, nil},
+		{"unknown key", sealedOther, false, ErrDecryptionFailed},
+		{"not base64", "!!!", false, ErrCiphertextCorrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dual.NeedsReseal(tt.value)
+			if err != tt.wantErr {
+				t.Fatalf("NeedsReseal() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NeedsReseal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"embed"
 	"fmt"
 	"time"
@@ -12,7 +14,7 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 //go:embed migrations/*.sql
@@ -24,7 +26,46 @@ func Connect(dsn string, maxConnections, minIdleConnections int) (*sql.DB, error
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	return configurePool(db, maxConnections, minIdleConnections)
+}
+
+// ConnectRotating is Connect for credentials that rotate: every new
+// connection is opened with the DSN dsn returns at that moment, so a new
+// password takes effect without reopening the pool. Call
+// CloseIdleConnections after a rotation to retire connections opened with
+// the old password sooner than ConnMaxLifetime would.
+func ConnectRotating(dsn func() string, maxConnections, minIdleConnections int) (*sql.DB, error) {
+	return configurePool(sql.OpenDB(rotatingConnector{dsn: dsn}), maxConnections, minIdleConnections)
+}
+
+// CloseIdleConnections closes the pool's idle connections; connections in
+// use are closed when they are returned.
+func CloseIdleConnections(db *sql.DB, minIdleConnections int) {
+	db.SetMaxIdleConns(0)
+	db.SetMaxIdleConns(minIdleConnections)
+}
+
+// rotatingConnector opens each connection with the current DSN.
+type rotatingConnector struct {
+	dsn func() string
+}
+
+// Connect implements driver.Connector.
+func (c rotatingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	connector, err := pq.NewConnector(c.dsn())
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+// Driver implements driver.Connector.
+func (rotatingConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
 
+// configurePool applies the registry's pool settings and pings the database.
+func configurePool(db *sql.DB, maxConnections, minIdleConnections int) (*sql.DB, error) {
 	// Configure connection pool
 	db.SetMaxOpenConns(maxConnections)
 	db.SetMaxIdleConns(minIdleConnections)
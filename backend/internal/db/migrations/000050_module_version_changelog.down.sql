@@ -0,0 +1,3 @@
+-- 000050_module_version_changelog.down.sql
+-- Drops the per-version changelog column; stored changelog excerpts are lost.
+ALTER TABLE module_versions DROP COLUMN IF EXISTS changelog;
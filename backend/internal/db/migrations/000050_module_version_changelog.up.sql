@@ -0,0 +1,7 @@
+-- Per-version changelog excerpt. Populated by the SCM publisher from the
+-- section of the module's CHANGELOG.md matching the published tag (falling
+-- back to the annotated tag message), or from the optional `changelog`
+-- multipart field on direct uploads. Stored already sanitized and truncated
+-- (see validation.SanitizeChangelog); NULL when no changelog was found.
+ALTER TABLE module_versions
+    ADD COLUMN IF NOT EXISTS changelog TEXT;
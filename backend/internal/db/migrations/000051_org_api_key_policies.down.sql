@@ -0,0 +1,3 @@
+-- 000051_org_api_key_policies.down.sql
+-- Drops per-organization API key policies. Existing keys are unaffected.
+DROP TABLE IF EXISTS org_api_key_policies;
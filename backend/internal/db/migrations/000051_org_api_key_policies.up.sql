@@ -0,0 +1,33 @@
+-- 000051_org_api_key_policies.up.sql
+-- Per-organization API key creation policy.
+--
+-- Lets an organization cap how long its members' API keys may live, which
+-- scopes they may carry, whether an expiry is mandatory, and how many keys a
+-- single user may hold. Enforced at key creation / rotation / update for any
+-- key whose owner is a member of the organization; when a user belongs to
+-- several organizations with policies, the strictest value of each rule wins.
+-- Existing keys are never revoked by a policy change -- they are reported by
+-- the compliance endpoint instead.
+CREATE TABLE IF NOT EXISTS org_api_key_policies (
+    organization_id    UUID        PRIMARY KEY,
+    max_lifetime_days  INT         NOT NULL DEFAULT 0,     -- 0 = unlimited
+    allowed_scopes     JSONB,                              -- NULL = any scope
+    require_expiry     BOOLEAN     NOT NULL DEFAULT false,
+    max_keys_per_user  INT         NOT NULL DEFAULT 0,     -- 0 = unlimited
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT org_api_key_policies_max_lifetime_check CHECK (max_lifetime_days >= 0),
+    CONSTRAINT org_api_key_policies_max_keys_check CHECK (max_keys_per_user >= 0)
+);
+
+-- Foreign key follows the 000045 pattern: point at the identity schema when
+-- the identity-schema cutover has happened, otherwise at public. A policy has
+-- no meaning without its organization, so it is dropped with it.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.org_api_key_policies ADD CONSTRAINT org_api_key_policies_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.org_api_key_policies ADD CONSTRAINT org_api_key_policies_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+  END IF;
+END $$;
@@ -0,0 +1,11 @@
+-- 000052_provider_upstream_metadata.down.sql
+-- Drops the mirrored-provider upstream metadata columns; captured license and
+-- source data is lost and repopulated by the next sync after re-applying.
+ALTER TABLE providers
+    DROP COLUMN IF EXISTS license_checked_at,
+    DROP COLUMN IF EXISTS upstream_metadata_synced_at,
+    DROP COLUMN IF EXISTS license_text,
+    DROP COLUMN IF EXISTS license_file,
+    DROP COLUMN IF EXISTS license,
+    DROP COLUMN IF EXISTS tier,
+    DROP COLUMN IF EXISTS source_url;
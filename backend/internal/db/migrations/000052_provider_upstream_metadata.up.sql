@@ -0,0 +1,21 @@
+-- Upstream metadata for mirrored providers, captured by the mirror sync so
+-- compliance can answer "what license is this provider under":
+--   source_url   source repository URL from the upstream v2 provider API
+--   tier         upstream tier (official / partner / community)
+--   license      SPDX identifier detected from the license file, when recognised
+--   license_file name of the license file found in the provider archive
+--   license_text the license file contents (capped at 256 KiB by the sync)
+-- All columns are NULL for uploaded providers and for mirrored providers whose
+-- upstream did not supply the data. upstream_metadata_synced_at records the
+-- last successful v2 metadata fetch; license_checked_at records when an
+-- archive was last examined for a license file (set even when none was found).
+-- A NULL license_checked_at makes the next sync read the newest stored archive,
+-- which backfills providers mirrored before this migration.
+ALTER TABLE providers
+    ADD COLUMN IF NOT EXISTS source_url TEXT,
+    ADD COLUMN IF NOT EXISTS tier VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS license VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS license_file VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS license_text TEXT,
+    ADD COLUMN IF NOT EXISTS upstream_metadata_synced_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS license_checked_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+-- 000053_ci_trust_rules.down.sql
+-- Drops the CI OIDC token exchange trust rules. Publish tokens already issued
+-- stay valid until they expire.
+DROP TABLE IF EXISTS ci_trust_rules;
@@ -0,0 +1,41 @@
+-- 000053_ci_trust_rules.up.sql
+-- Per-namespace trust rules for the CI OIDC token exchange.
+--
+-- A rule lets CI jobs from one repository on a trusted OIDC issuer (e.g.
+-- GitHub Actions) exchange their ID token for a short-lived publish token
+-- limited to modules:write in the rule's namespace. ref_pattern and
+-- environment optionally narrow the rule further; NULL matches any value.
+-- organization_id is the organization the issued token acts for, and must
+-- own the namespace for a publish to succeed.
+CREATE TABLE IF NOT EXISTS ci_trust_rules (
+    id               UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id  UUID         NOT NULL,
+    namespace        VARCHAR(255) NOT NULL,
+    issuer           TEXT         NOT NULL,
+    repository       VARCHAR(255) NOT NULL,   -- "owner/repo", matched case-insensitively
+    ref_pattern      VARCHAR(255),            -- glob, e.g. "refs/tags/v*"; NULL = any ref
+    environment      VARCHAR(255),            -- NULL = any (or no) environment
+    description      TEXT,
+    enabled          BOOLEAN      NOT NULL DEFAULT true,
+    created_by       UUID,
+    last_used_at     TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ci_trust_rules_unique
+    ON ci_trust_rules (namespace, issuer, LOWER(repository), COALESCE(ref_pattern, ''), COALESCE(environment, ''));
+
+CREATE INDEX IF NOT EXISTS idx_ci_trust_rules_namespace ON ci_trust_rules (namespace);
+
+-- Foreign key follows the 000045 pattern: point at the identity schema when
+-- the identity-schema cutover has happened, otherwise at public. Rules are
+-- dropped with their organization.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.ci_trust_rules ADD CONSTRAINT ci_trust_rules_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.ci_trust_rules ADD CONSTRAINT ci_trust_rules_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+  END IF;
+END $$;
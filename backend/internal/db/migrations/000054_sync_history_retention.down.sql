@@ -0,0 +1,12 @@
+-- 000054_sync_history_retention.down.sql
+-- Drops the per-config sync history retention settings. History already
+-- pruned is not restored.
+ALTER TABLE terraform_mirror_configs
+    DROP CONSTRAINT IF EXISTS terraform_mirror_configs_history_retention_check,
+    DROP COLUMN IF EXISTS history_retention_days,
+    DROP COLUMN IF EXISTS history_retention_count;
+
+ALTER TABLE mirror_configurations
+    DROP CONSTRAINT IF EXISTS mirror_configurations_history_retention_check,
+    DROP COLUMN IF EXISTS history_retention_days,
+    DROP COLUMN IF EXISTS history_retention_count;
@@ -0,0 +1,22 @@
+-- Per-config retention for sync history. mirror_sync_history and
+-- terraform_sync_history previously grew without bound; the sync jobs now
+-- prune finished runs at the end of every sync:
+--   history_retention_count keep at most this many most-recent runs (0 = no count limit)
+--   history_retention_days  drop runs that started more than this many days ago (0 = no age limit)
+-- A run is deleted when it falls outside either limit. Running rows are never
+-- pruned. The defaults keep the last 500 runs from the last 90 days.
+ALTER TABLE mirror_configurations
+    ADD COLUMN IF NOT EXISTS history_retention_count INTEGER NOT NULL DEFAULT 500,
+    ADD COLUMN IF NOT EXISTS history_retention_days  INTEGER NOT NULL DEFAULT 90;
+
+ALTER TABLE mirror_configurations
+    ADD CONSTRAINT mirror_configurations_history_retention_check
+    CHECK (history_retention_count >= 0 AND history_retention_days >= 0);
+
+ALTER TABLE terraform_mirror_configs
+    ADD COLUMN IF NOT EXISTS history_retention_count INTEGER NOT NULL DEFAULT 500,
+    ADD COLUMN IF NOT EXISTS history_retention_days  INTEGER NOT NULL DEFAULT 90;
+
+ALTER TABLE terraform_mirror_configs
+    ADD CONSTRAINT terraform_mirror_configs_history_retention_check
+    CHECK (history_retention_count >= 0 AND history_retention_days >= 0);
@@ -0,0 +1,4 @@
+-- 000055_module_version_approvals.down.sql
+-- Drops module version approvals and the approved_only organization policy.
+DROP TABLE IF EXISTS org_module_policies;
+DROP TABLE IF EXISTS module_version_approvals;
@@ -0,0 +1,43 @@
+-- 000055_module_version_approvals.up.sql
+-- Per-organization approval of module versions, and an opt-in policy that
+-- restricts an organization's members to approved versions.
+--
+-- module_version_approvals records that an organization has approved one
+-- module version (approved_by is the approving user; NULL when the approval
+-- was made with a credential that has no user). Deleting the version removes
+-- its approvals. org_module_policies holds the approved_only flag: when set,
+-- authenticated members of the organization only see, and can only download,
+-- versions that organization has approved. Anonymous protocol access is not
+-- affected.
+CREATE TABLE IF NOT EXISTS module_version_approvals (
+    id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    module_version_id UUID        NOT NULL REFERENCES module_versions(id) ON DELETE CASCADE,
+    organization_id   UUID        NOT NULL,
+    approved_by       UUID,
+    approved_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    notes             TEXT,
+    CONSTRAINT module_version_approvals_version_org_key UNIQUE (module_version_id, organization_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_module_version_approvals_org
+    ON module_version_approvals (organization_id);
+
+CREATE TABLE IF NOT EXISTS org_module_policies (
+    organization_id UUID        PRIMARY KEY,
+    approved_only   BOOLEAN     NOT NULL DEFAULT false,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Foreign keys follow the 000045 pattern: point at the identity schema when
+-- the identity-schema cutover has happened, otherwise at public.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.module_version_approvals ADD CONSTRAINT module_version_approvals_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+    ALTER TABLE public.org_module_policies ADD CONSTRAINT org_module_policies_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.module_version_approvals ADD CONSTRAINT module_version_approvals_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+    ALTER TABLE public.org_module_policies ADD CONSTRAINT org_module_policies_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+  END IF;
+END $$;
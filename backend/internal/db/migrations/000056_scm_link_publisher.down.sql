@@ -0,0 +1,5 @@
+-- 000056_scm_link_publisher.down.sql
+-- Drops the SCM link publishing identity and token health columns.
+ALTER TABLE module_scm_repos
+    DROP COLUMN IF EXISTS token_invalid_at,
+    DROP COLUMN IF EXISTS publishing_user_id;
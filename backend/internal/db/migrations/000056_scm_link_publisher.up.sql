@@ -0,0 +1,11 @@
+-- 000056_scm_link_publisher.up.sql
+-- Publishing identity and token health for module SCM links.
+--   publishing_user_id  user whose OAuth token is used for webhook publishes
+--                       on oauth_user providers; NULL falls back to the
+--                       module's creator (the previous behaviour)
+--   token_invalid_at    set by the tag verifier when the publishing token is
+--                       missing, expired, or rejected by the provider; cleared
+--                       once a valid token is seen again or ownership moves
+ALTER TABLE module_scm_repos
+    ADD COLUMN IF NOT EXISTS publishing_user_id UUID,
+    ADD COLUMN IF NOT EXISTS token_invalid_at   TIMESTAMPTZ;
@@ -0,0 +1,13 @@
+-- 000057_artifactory_storage.down.sql
+-- Removes the Artifactory storage settings. Artifactory configurations are
+-- deleted first so the original backend_type constraint can be restored.
+DELETE FROM storage_config WHERE backend_type = 'artifactory';
+
+ALTER TABLE storage_config DROP CONSTRAINT IF EXISTS valid_backend_type;
+ALTER TABLE storage_config
+    ADD CONSTRAINT valid_backend_type CHECK (backend_type IN ('local', 'azure', 's3', 'gcs'));
+
+ALTER TABLE storage_config
+    DROP COLUMN IF EXISTS artifactory_access_token_encrypted,
+    DROP COLUMN IF EXISTS artifactory_repository,
+    DROP COLUMN IF EXISTS artifactory_base_url;
@@ -0,0 +1,13 @@
+-- 000057_artifactory_storage.up.sql
+-- JFrog Artifactory / generic WebDAV storage backend settings.
+--   artifactory_base_url                 Artifactory root URL (or WebDAV collection URL)
+--   artifactory_repository               repository key artifacts are stored in
+--   artifactory_access_token_encrypted   bearer token, encrypted at rest
+ALTER TABLE storage_config
+    ADD COLUMN IF NOT EXISTS artifactory_base_url               VARCHAR(1024),
+    ADD COLUMN IF NOT EXISTS artifactory_repository             VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS artifactory_access_token_encrypted TEXT;
+
+ALTER TABLE storage_config DROP CONSTRAINT IF EXISTS valid_backend_type;
+ALTER TABLE storage_config
+    ADD CONSTRAINT valid_backend_type CHECK (backend_type IN ('local', 'azure', 's3', 'gcs', 'artifactory'));
@@ -0,0 +1,4 @@
+-- 000058_module_proxy.down.sql
+-- Drops the module pull-through proxy bookkeeping table. Cached module rows
+-- and archives are left in place and become ordinary local modules.
+DROP TABLE IF EXISTS proxied_modules;
@@ -0,0 +1,16 @@
+-- 000058_module_proxy.up.sql
+-- Flags modules that were populated by the module pull-through proxy rather
+-- than published locally. A module row plus a proxied_modules row means every
+-- version under it was fetched from upstream_host on demand; local uploads
+-- into such a module are still possible but the proxy will never write into a
+-- module that has no proxied_modules row.
+CREATE TABLE IF NOT EXISTS proxied_modules (
+    module_id       UUID         PRIMARY KEY REFERENCES modules(id) ON DELETE CASCADE,
+    upstream_host   VARCHAR(255) NOT NULL,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_fetched_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE proxied_modules IS 'Modules cached on demand by the module pull-through proxy, keyed to the upstream registry host';
+
+CREATE INDEX IF NOT EXISTS idx_proxied_modules_host ON proxied_modules(upstream_host);
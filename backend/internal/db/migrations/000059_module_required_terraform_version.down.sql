@@ -0,0 +1,2 @@
+-- 000059_module_required_terraform_version.down.sql
+ALTER TABLE module_versions DROP COLUMN IF EXISTS required_terraform_version;
@@ -0,0 +1,6 @@
+-- 000059_module_required_terraform_version.up.sql
+-- Records the Terraform core version constraint (the root module's
+-- terraform { required_version = ... }) for each module version, so listings
+-- can show it and the protocol can hide versions a client cannot run. NULL
+-- means the module declares no constraint or it could not be parsed.
+ALTER TABLE module_versions ADD COLUMN IF NOT EXISTS required_terraform_version TEXT;
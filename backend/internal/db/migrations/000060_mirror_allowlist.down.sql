@@ -0,0 +1,4 @@
+-- 000060_mirror_allowlist.down.sql
+-- Drops the network mirror allowlist; the mirror serves every stored provider
+-- again.
+DROP TABLE IF EXISTS mirror_allowlist_entries;
@@ -0,0 +1,21 @@
+-- 000060_mirror_allowlist.up.sql
+-- Allowlist for the network mirror protocol routes (/terraform/providers/...).
+--
+-- Each entry is a hostname/namespace/type triple of path.Match globs ("*"
+-- matches any segment). While the table is empty the mirror serves every
+-- provider it stores; once it holds at least one entry, mirror requests that
+-- match no entry are answered 404 before any provider lookup. The registry
+-- protocol (/v1/providers/...) is not affected.
+CREATE TABLE IF NOT EXISTS mirror_allowlist_entries (
+    id                 UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    hostname_pattern   VARCHAR(255) NOT NULL DEFAULT '*',   -- origin registry, matched case-insensitively
+    namespace_pattern  VARCHAR(255) NOT NULL DEFAULT '*',
+    type_pattern       VARCHAR(255) NOT NULL DEFAULT '*',
+    description        TEXT,
+    created_by         UUID,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mirror_allowlist_entries_unique
+    ON mirror_allowlist_entries (LOWER(hostname_pattern), namespace_pattern, type_pattern);
@@ -0,0 +1,4 @@
+-- 000061_provider_version_resignatures.down.sql
+-- Drops registry-issued signatures; mirrored versions are served with their
+-- upstream signatures again.
+DROP TABLE IF EXISTS provider_version_resignatures;
@@ -0,0 +1,19 @@
+-- 000061_provider_version_resignatures.up.sql
+-- Registry-issued signatures for mirrored provider versions (mirror_signing).
+--
+-- When re-signing is enabled the registry rebuilds SHA256SUMS from the
+-- verified upstream checksums, signs it with its own key and serves that
+-- signature and key instead of the upstream ones. The upstream URLs and key
+-- on provider_versions are left untouched so the original chain of trust can
+-- still be audited. One row per version; re-signing replaces it.
+CREATE TABLE IF NOT EXISTS provider_version_resignatures (
+    provider_version_id           UUID         PRIMARY KEY REFERENCES provider_versions(id) ON DELETE CASCADE,
+    key_id                        VARCHAR(16)  NOT NULL,
+    gpg_public_key                TEXT         NOT NULL,
+    shasum_storage_key            VARCHAR(1024) NOT NULL,
+    shasum_signature_storage_key  VARCHAR(1024) NOT NULL,
+    signed_at                     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_version_resignatures_key_id
+    ON provider_version_resignatures (key_id);
@@ -0,0 +1,5 @@
+-- 000062_module_prerelease_settings.down.sql
+-- Drops the pre-release channel settings; per-module and per-link choices are
+-- lost.
+ALTER TABLE module_scm_repos DROP COLUMN IF EXISTS skip_prerelease;
+ALTER TABLE modules DROP COLUMN IF EXISTS include_prerelease;
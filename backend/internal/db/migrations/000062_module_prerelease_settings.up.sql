@@ -0,0 +1,11 @@
+-- 000062_module_prerelease_settings.up.sql
+-- Pre-release channel settings. Versions with a semver pre-release suffix
+-- (1.4.0-rc.1) are hidden from the protocol versions listing and never chosen
+-- as a module's latest version unless the module opts back in with
+-- include_prerelease. skip_prerelease makes an SCM link ignore pre-release
+-- tags instead of publishing them.
+ALTER TABLE modules
+    ADD COLUMN IF NOT EXISTS include_prerelease BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE module_scm_repos
+    ADD COLUMN IF NOT EXISTS skip_prerelease BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+-- 000063_provider_download_stats.down.sql
+-- Drops the daily provider download counts; lifetime totals on
+-- provider_platforms.download_count are unaffected.
+DROP TABLE IF EXISTS provider_download_stats;
@@ -0,0 +1,20 @@
+-- 000063_provider_download_stats.up.sql
+-- Daily provider download counts per version and platform.
+--
+-- provider_platforms.download_count only keeps a lifetime total, which cannot
+-- answer "which platforms were used recently". Downloads are buffered in the
+-- server and upserted here in batches, one row per provider, version,
+-- platform and UTC day, feeding the admin provider stats endpoint and the
+-- unused-platform markers on the mirror status.
+CREATE TABLE IF NOT EXISTS provider_download_stats (
+    provider_id UUID        NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+    version     VARCHAR(50) NOT NULL,
+    os          VARCHAR(50) NOT NULL,
+    arch        VARCHAR(50) NOT NULL,
+    day         DATE        NOT NULL,
+    downloads   BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (provider_id, version, os, arch, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_download_stats_provider_day
+    ON provider_download_stats (provider_id, day);
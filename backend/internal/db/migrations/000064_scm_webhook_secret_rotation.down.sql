@@ -0,0 +1,8 @@
+-- 000064_scm_webhook_secret_rotation.down.sql
+-- Drops per-link webhook secrets. Links whose secret was rotated fall back to
+-- the provider's secret, so their registered webhooks must be updated to match.
+ALTER TABLE module_scm_repos
+    DROP COLUMN IF EXISTS webhook_secret_grace_until,
+    DROP COLUMN IF EXISTS previous_webhook_url,
+    DROP COLUMN IF EXISTS previous_webhook_secret,
+    DROP COLUMN IF EXISTS webhook_secret;
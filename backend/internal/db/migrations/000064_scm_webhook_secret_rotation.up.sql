@@ -0,0 +1,15 @@
+-- 000064_scm_webhook_secret_rotation.up.sql
+-- Per-link webhook secrets and rotation.
+--
+-- Links were created with the secret embedded in the callback URL path
+-- (/webhooks/scm/<link id>/<secret>), which leaks into proxy logs and SCM
+-- delivery UIs, and deliveries were signed with the provider-wide secret.
+-- webhook_secret is the link's own signing secret, set when the secret is
+-- rotated; NULL keeps signing with the provider's secret. During a rotation's
+-- grace period the replaced secret and callback URL keep being accepted so
+-- in-flight and redelivered events are not rejected.
+ALTER TABLE module_scm_repos
+    ADD COLUMN IF NOT EXISTS webhook_secret TEXT,
+    ADD COLUMN IF NOT EXISTS previous_webhook_secret TEXT,
+    ADD COLUMN IF NOT EXISTS previous_webhook_url TEXT,
+    ADD COLUMN IF NOT EXISTS webhook_secret_grace_until TIMESTAMPTZ;
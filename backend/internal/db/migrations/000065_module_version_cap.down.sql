@@ -0,0 +1,10 @@
+-- 000065_module_version_cap.down.sql
+-- Drops the version cap settings. Archived versions become listed again.
+DROP INDEX IF EXISTS idx_module_versions_active;
+ALTER TABLE module_versions DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE org_module_policies
+    DROP COLUMN IF EXISTS default_version_cap_mode,
+    DROP COLUMN IF EXISTS default_max_versions;
+ALTER TABLE modules
+    DROP COLUMN IF EXISTS version_cap_mode,
+    DROP COLUMN IF EXISTS max_versions;
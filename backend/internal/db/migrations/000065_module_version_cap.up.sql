@@ -0,0 +1,31 @@
+-- 000065_module_version_cap.up.sql
+-- Soft cap on the number of versions a module keeps listed.
+--
+-- modules.max_versions caps one module; org_module_policies.default_max_versions
+-- is the default for every module of the organization that sets no cap of its
+-- own. NULL means no cap. The mode decides what a publish past the cap does:
+-- 'strict' rejects it, 'rolling' archives the oldest versions no organization
+-- has approved. A module with no mode of its own uses its organization's,
+-- and 'strict' when neither is set.
+--
+-- Archived versions (archived_at set) are hidden from version listings and
+-- never picked as latest, but stay downloadable so pinned configurations keep
+-- working.
+ALTER TABLE modules
+    ADD COLUMN IF NOT EXISTS max_versions INTEGER
+        CHECK (max_versions IS NULL OR max_versions > 0),
+    ADD COLUMN IF NOT EXISTS version_cap_mode TEXT
+        CHECK (version_cap_mode IS NULL OR version_cap_mode IN ('strict', 'rolling'));
+
+ALTER TABLE org_module_policies
+    ADD COLUMN IF NOT EXISTS default_max_versions INTEGER
+        CHECK (default_max_versions IS NULL OR default_max_versions > 0),
+    ADD COLUMN IF NOT EXISTS default_version_cap_mode TEXT
+        CHECK (default_version_cap_mode IS NULL OR default_version_cap_mode IN ('strict', 'rolling'));
+
+ALTER TABLE module_versions
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_module_versions_active
+    ON module_versions (module_id, created_at)
+    WHERE archived_at IS NULL;
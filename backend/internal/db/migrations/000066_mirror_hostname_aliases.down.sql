@@ -0,0 +1,5 @@
+-- 000066_mirror_hostname_aliases.down.sql
+-- Drops mirror hostname aliases and the per-provider opt-in.
+ALTER TABLE mirrored_providers DROP COLUMN IF EXISTS serve_under_aliases;
+
+DROP TABLE IF EXISTS mirror_hostname_aliases;
@@ -0,0 +1,31 @@
+-- 000066_mirror_hostname_aliases.up.sql
+-- Hostname aliases for the network mirror routes (/terraform/providers/...).
+--
+-- OpenTofu requests providers as registry.opentofu.org/<namespace>/<type>
+-- while a mirror synced from registry.terraform.io stores them under that
+-- origin. An alias lets the providers one mirror configuration synced be served
+-- under another origin hostname, for one namespace or (namespace NULL) every
+-- namespace. Each hostname/namespace pair maps to at most one mirror, so an
+-- alias never mixes providers from different upstreams.
+--
+-- The two registries do not publish identical checksums for every provider,
+-- so an alias only applies to mirrored providers that opted in with
+-- mirrored_providers.serve_under_aliases, and only the versions that mirror
+-- synced are listed under it.
+CREATE TABLE IF NOT EXISTS mirror_hostname_aliases (
+    id                UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    mirror_config_id  UUID         NOT NULL REFERENCES mirror_configurations(id) ON DELETE CASCADE,
+    alias_hostname    VARCHAR(255) NOT NULL,   -- stored lowercase
+    namespace         VARCHAR(255),            -- NULL: every namespace
+    created_by        UUID,
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mirror_hostname_aliases_unique
+    ON mirror_hostname_aliases (alias_hostname, COALESCE(namespace, ''));
+
+CREATE INDEX IF NOT EXISTS idx_mirror_hostname_aliases_mirror
+    ON mirror_hostname_aliases (mirror_config_id);
+
+ALTER TABLE mirrored_providers
+    ADD COLUMN IF NOT EXISTS serve_under_aliases BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+-- 000067_module_version_docs_submodules.down.sql
+ALTER TABLE module_version_docs
+    DROP COLUMN IF EXISTS submodules;
@@ -0,0 +1,9 @@
+-- 000067_module_version_docs_submodules.up.sql
+-- Documentation of each submodule under a module's modules/ directory:
+-- name, path, README, inputs, outputs, providers and any parse error.
+--
+-- NULL means the version was analyzed before submodules were documented;
+-- re-analyzing it fills the column in. A module without submodules stores an
+-- empty array.
+ALTER TABLE module_version_docs
+    ADD COLUMN IF NOT EXISTS submodules JSONB DEFAULT NULL;
@@ -0,0 +1,16 @@
+-- 000068_mirror_approval_auto_apply.down.sql
+UPDATE mirror_approval_requests SET status = 'rejected' WHERE status = 'revoked';
+
+ALTER TABLE mirror_approval_requests
+    DROP CONSTRAINT IF EXISTS mirror_approval_requests_status_check;
+ALTER TABLE mirror_approval_requests
+    ADD CONSTRAINT mirror_approval_requests_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected'));
+
+ALTER TABLE mirror_approval_requests
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS revoked_by,
+    DROP COLUMN IF EXISTS applied_at,
+    DROP COLUMN IF EXISTS applied_provider,
+    DROP COLUMN IF EXISTS applied_namespace,
+    DROP COLUMN IF EXISTS auto_apply;
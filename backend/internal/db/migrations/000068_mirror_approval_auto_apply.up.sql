@@ -0,0 +1,21 @@
+-- 000068_mirror_approval_auto_apply.up.sql
+-- Auto-provisioning for mirror approval requests.
+--
+-- Approving a request with auto_apply set adds its namespace and provider to
+-- the target mirror's namespace_filter / provider_filter and starts a sync.
+-- applied_namespace / applied_provider record the entries the approval
+-- actually added (NULL when the entry was already in the filter), so revoking
+-- the approval removes those entries and nothing else.
+ALTER TABLE mirror_approval_requests
+    ADD COLUMN IF NOT EXISTS auto_apply        BOOLEAN      NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS applied_namespace VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS applied_provider  VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS applied_at        TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS revoked_by        UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS revoked_at        TIMESTAMP;
+
+ALTER TABLE mirror_approval_requests
+    DROP CONSTRAINT IF EXISTS mirror_approval_requests_status_check;
+ALTER TABLE mirror_approval_requests
+    ADD CONSTRAINT mirror_approval_requests_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'revoked'));
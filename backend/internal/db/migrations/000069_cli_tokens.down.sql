@@ -0,0 +1,4 @@
+-- 000069_cli_tokens.down.sql
+-- Drops the CLI token records. Tokens already issued stay valid until they
+-- expire; those already revoked stay in revoked_tokens.
+DROP TABLE IF EXISTS cli_tokens;
@@ -0,0 +1,20 @@
+-- 000069_cli_tokens.up.sql
+-- Tokens issued to the Terraform CLI (audience "terraform-cli").
+--
+-- The token itself is a signed JWT and is never stored; this table records
+-- each issued token's JTI so its owner can list and revoke it. Revocation
+-- also adds the JTI to revoked_tokens, which is what the auth middleware
+-- checks. No FK to users: identity data may live in the shared identity
+-- schema (or a separate identity database), while this table always lives
+-- on the registry's own connection.
+CREATE TABLE IF NOT EXISTS cli_tokens (
+    id          UUID        PRIMARY KEY,   -- the token's JTI
+    user_id     UUID        NOT NULL,
+    description TEXT,
+    scopes      JSONB       NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at  TIMESTAMPTZ NOT NULL,
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_cli_tokens_user ON cli_tokens (user_id, created_at DESC);
@@ -0,0 +1,6 @@
+-- 000070_artifact_immutability.down.sql
+-- Drops artifact immutability settings and the publish log.
+DROP INDEX IF EXISTS idx_provider_platforms_storage_path;
+DROP INDEX IF EXISTS idx_module_versions_storage_path;
+DROP TABLE IF EXISTS artifact_publish_log;
+DROP TABLE IF EXISTS org_artifact_immutability;
@@ -0,0 +1,50 @@
+-- 000070_artifact_immutability.up.sql
+-- Write-once (WORM) module and provider archives.
+--
+-- Immutability is enabled registry-wide by immutable_artifacts.enabled or per
+-- organization by a row here. While it applies, a published archive can never
+-- be replaced, a version cannot be deleted until it is retention_days old
+-- (0 = never), and every publish is appended to artifact_publish_log.
+CREATE TABLE IF NOT EXISTS org_artifact_immutability (
+    organization_id UUID        PRIMARY KEY,
+    enabled         BOOLEAN     NOT NULL DEFAULT true,
+    retention_days  INT         NOT NULL DEFAULT 0,     -- 0 = versions are never deletable
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT org_artifact_immutability_retention_check CHECK (retention_days >= 0)
+);
+
+-- Foreign key follows the 000045 pattern (see 000051).
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.org_artifact_immutability ADD CONSTRAINT org_artifact_immutability_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.org_artifact_immutability ADD CONSTRAINT org_artifact_immutability_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+-- Append-only publish log. Each entry's entry_hash is the SHA-256 of its own
+-- fields and prev_hash, the entry_hash of the entry before it, so changing or
+-- removing any entry breaks the chain from that point on. The log has no
+-- foreign keys: it must outlive the versions and organizations it records.
+CREATE TABLE IF NOT EXISTS artifact_publish_log (
+    id              BIGSERIAL    PRIMARY KEY,
+    artifact_type   VARCHAR(16)  NOT NULL CHECK (artifact_type IN ('module', 'provider')),
+    organization_id UUID,
+    namespace       VARCHAR(255) NOT NULL,
+    name            VARCHAR(255) NOT NULL,   -- module name or provider type
+    target          VARCHAR(255) NOT NULL,   -- module system or provider os_arch
+    version         VARCHAR(50)  NOT NULL,
+    storage_key     TEXT         NOT NULL,
+    checksum        VARCHAR(64)  NOT NULL,
+    published_by    UUID,
+    published_at    TIMESTAMPTZ  NOT NULL,
+    prev_hash       CHAR(64)     NOT NULL,
+    entry_hash      CHAR(64)     NOT NULL UNIQUE
+);
+
+-- The immutability guard resolves storage keys to their versions on every
+-- delete and on uploads to existing keys.
+CREATE INDEX IF NOT EXISTS idx_module_versions_storage_path     ON module_versions (storage_path);
+CREATE INDEX IF NOT EXISTS idx_provider_platforms_storage_path  ON provider_platforms (storage_path);
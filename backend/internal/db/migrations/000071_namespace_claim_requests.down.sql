@@ -0,0 +1,4 @@
+-- 000071_namespace_claim_requests.down.sql
+-- Drops namespace claim requests and verified organization domains.
+DROP TABLE IF EXISTS org_verified_domains;
+DROP TABLE IF EXISTS namespace_claim_requests;
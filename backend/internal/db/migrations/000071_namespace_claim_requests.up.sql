@@ -0,0 +1,53 @@
+-- 000071_namespace_claim_requests.up.sql
+-- Self-service namespace claims.
+--
+-- An organization member asks for a namespace with a claim request; an admin
+-- approves or rejects it, and approval writes the namespace_claims ownership
+-- row (000045). Requests for reserved namespaces are stored already rejected.
+-- Only one request per namespace and organization can be pending at a time.
+CREATE TABLE IF NOT EXISTS namespace_claim_requests (
+    id              UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    namespace       VARCHAR(255) NOT NULL,
+    organization_id UUID         NOT NULL,
+    requested_by    UUID,
+    reason          TEXT         NOT NULL DEFAULT '',
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    auto_decided    BOOLEAN      NOT NULL DEFAULT false,
+    decided_by      UUID,
+    decided_at      TIMESTAMPTZ,
+    decision_notes  TEXT,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CONSTRAINT namespace_claim_requests_status_check CHECK (status IN ('pending', 'approved', 'rejected'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_namespace_claim_requests_pending
+    ON namespace_claim_requests(namespace, organization_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_namespace_claim_requests_status
+    ON namespace_claim_requests(status, created_at);
+
+-- The domain an admin has verified an organization controls. Its first label
+-- is the prefix namespace_claims.auto_approve_verified_domain matches.
+CREATE TABLE IF NOT EXISTS org_verified_domains (
+    organization_id UUID         PRIMARY KEY,
+    domain          VARCHAR(253) NOT NULL,
+    verified_by     UUID,
+    verified_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- Foreign keys follow the 000045 pattern.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.namespace_claim_requests ADD CONSTRAINT namespace_claim_requests_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+    ALTER TABLE public.namespace_claim_requests ADD CONSTRAINT namespace_claim_requests_requested_by_fkey FOREIGN KEY (requested_by) REFERENCES identity.users(id) ON DELETE SET NULL;
+    ALTER TABLE public.namespace_claim_requests ADD CONSTRAINT namespace_claim_requests_decided_by_fkey FOREIGN KEY (decided_by) REFERENCES identity.users(id) ON DELETE SET NULL;
+    ALTER TABLE public.org_verified_domains ADD CONSTRAINT org_verified_domains_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+    ALTER TABLE public.org_verified_domains ADD CONSTRAINT org_verified_domains_verified_by_fkey FOREIGN KEY (verified_by) REFERENCES identity.users(id) ON DELETE SET NULL;
+  ELSE
+    ALTER TABLE public.namespace_claim_requests ADD CONSTRAINT namespace_claim_requests_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+    ALTER TABLE public.namespace_claim_requests ADD CONSTRAINT namespace_claim_requests_requested_by_fkey FOREIGN KEY (requested_by) REFERENCES public.users(id) ON DELETE SET NULL;
+    ALTER TABLE public.namespace_claim_requests ADD CONSTRAINT namespace_claim_requests_decided_by_fkey FOREIGN KEY (decided_by) REFERENCES public.users(id) ON DELETE SET NULL;
+    ALTER TABLE public.org_verified_domains ADD CONSTRAINT org_verified_domains_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+    ALTER TABLE public.org_verified_domains ADD CONSTRAINT org_verified_domains_verified_by_fkey FOREIGN KEY (verified_by) REFERENCES public.users(id) ON DELETE SET NULL;
+  END IF;
+END $$;
@@ -0,0 +1,5 @@
+-- 000072_mirror_blobs.down.sql
+-- Platform rows keep their blobs/sha256/ storage paths, which stay
+-- downloadable; only the reference counting is dropped.
+DROP TABLE IF EXISTS mirror_blob_refs;
+DROP TABLE IF EXISTS mirror_blobs;
@@ -0,0 +1,26 @@
+-- 000072_mirror_blobs.up.sql
+-- Content-addressed storage for mirrored provider platform archives.
+--
+-- Mirror syncs store each archive once under blobs/sha256/<digest>, however
+-- many mirror configurations (and organizations) mirror the same upstream
+-- provider. mirror_blob_refs maps each platform row backed by a blob to it:
+-- a blob's reference count is its number of rows there. Deleting a platform
+-- through the API releases its reference and deletes the blob with the last
+-- one.
+CREATE TABLE mirror_blobs (
+    digest       VARCHAR(64)   PRIMARY KEY,
+    storage_path VARCHAR(1024) NOT NULL,
+    size_bytes   BIGINT        NOT NULL,
+    created_at   TIMESTAMP     NOT NULL DEFAULT NOW()
+);
+
+-- A platform row deleted without releasing its reference (a cascade from its
+-- provider or version) takes the reference with it; blobs left unreferenced
+-- are pruned by `mirror-dedup --apply`.
+CREATE TABLE mirror_blob_refs (
+    provider_platform_id UUID        PRIMARY KEY REFERENCES provider_platforms(id) ON DELETE CASCADE,
+    digest               VARCHAR(64) NOT NULL REFERENCES mirror_blobs(digest),
+    created_at           TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_mirror_blob_refs_digest ON mirror_blob_refs(digest);
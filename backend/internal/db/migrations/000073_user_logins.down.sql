@@ -0,0 +1,3 @@
+-- 000073_user_logins.down.sql
+-- Drops recorded user logins.
+DROP TABLE IF EXISTS user_logins;
@@ -0,0 +1,25 @@
+-- 000073_user_logins.up.sql
+-- The most recent successful interactive login of each user: when, and
+-- through which provider. Written when a session token is issued (OIDC, Azure
+-- AD, SAML, LDAP or dev login) and read by the admin user search filters.
+-- Token refreshes and impersonation are not logins.
+CREATE TABLE IF NOT EXISTS user_logins (
+    user_id       UUID          PRIMARY KEY,
+    last_login_at TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    -- oidc | azuread | saml | ldap | dev
+    auth_provider VARCHAR(20)   NOT NULL,
+    -- The OIDC/Azure AD token issuer or SAML IdP name; empty for LDAP and dev.
+    auth_issuer   VARCHAR(1024) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_logins_last_login_at ON user_logins(last_login_at);
+
+-- Foreign keys follow the 000045 pattern.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.user_logins ADD CONSTRAINT user_logins_user_id_fkey FOREIGN KEY (user_id) REFERENCES identity.users(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.user_logins ADD CONSTRAINT user_logins_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+  END IF;
+END $$;
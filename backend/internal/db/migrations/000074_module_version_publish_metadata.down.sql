@@ -0,0 +1,3 @@
+-- 000074_module_version_publish_metadata.down.sql
+-- Drops recorded SCM publish metadata.
+DROP TABLE IF EXISTS module_version_publish_metadata;
@@ -0,0 +1,21 @@
+-- 000074_module_version_publish_metadata.up.sql
+-- How each SCM-published module version was built: the commit its tag
+-- resolved to, the upstream source archive it was repackaged from, how long
+-- packaging took and which connector API calls it made. Written by the SCM
+-- publisher; read by the module SCM info, webhook events and manual sync
+-- endpoints. Versions uploaded directly have no row.
+CREATE TABLE module_version_publish_metadata (
+    module_version_id         UUID         PRIMARY KEY REFERENCES module_versions(id) ON DELETE CASCADE,
+    module_scm_repo_id        UUID         REFERENCES module_scm_repos(id) ON DELETE SET NULL,
+    tag_name                  VARCHAR(255) NOT NULL,
+    commit_sha                VARCHAR(64)  NOT NULL,
+    source_archive_size_bytes BIGINT       NOT NULL,
+    source_archive_sha256     VARCHAR(64)  NOT NULL,
+    package_duration_ms       BIGINT       NOT NULL,
+    -- Connector methods called, in order, e.g. ["FetchTagByName", "DownloadSourceArchive"].
+    connector_api_calls       JSONB        NOT NULL DEFAULT '[]',
+    created_at                TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_module_version_publish_metadata_repo
+    ON module_version_publish_metadata(module_scm_repo_id, created_at DESC);
@@ -0,0 +1,8 @@
+-- 000075_maintenance_mode.down.sql
+-- Removes the maintenance mode columns from system_settings.
+ALTER TABLE system_settings
+  DROP COLUMN IF EXISTS maintenance_enabled_at,
+  DROP COLUMN IF EXISTS maintenance_enabled_by,
+  DROP COLUMN IF EXISTS maintenance_eta,
+  DROP COLUMN IF EXISTS maintenance_message,
+  DROP COLUMN IF EXISTS maintenance_enabled;
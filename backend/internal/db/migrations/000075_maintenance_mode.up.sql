@@ -0,0 +1,12 @@
+-- 000075_maintenance_mode.up.sql
+-- Read-only maintenance mode. While maintenance_enabled is true the API
+-- rejects mutating requests with 503 and maintenance_message, and keeps
+-- serving reads. Kept in system_settings so the mode survives restarts and
+-- applies to every replica.
+ALTER TABLE system_settings
+  ADD COLUMN IF NOT EXISTS maintenance_enabled    BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS maintenance_message    TEXT    NOT NULL DEFAULT '',
+  -- When the operator expects writes to be accepted again; drives Retry-After.
+  ADD COLUMN IF NOT EXISTS maintenance_eta        TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN IF NOT EXISTS maintenance_enabled_by UUID,
+  ADD COLUMN IF NOT EXISTS maintenance_enabled_at TIMESTAMP WITH TIME ZONE;
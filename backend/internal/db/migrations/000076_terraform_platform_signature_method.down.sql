@@ -0,0 +1,3 @@
+-- 000076_terraform_platform_signature_method.down.sql
+-- Removes the signature method column from terraform_version_platforms.
+ALTER TABLE terraform_version_platforms DROP COLUMN IF EXISTS signature_method;
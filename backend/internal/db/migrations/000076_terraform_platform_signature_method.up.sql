@@ -0,0 +1,8 @@
+-- 000076_terraform_platform_signature_method.up.sql
+-- Records how the SHA256SUMS file a mirrored binary was checked against was
+-- authenticated: 'gpg' (detached GPG signature) or 'cosign' (keyless cosign
+-- signature, OpenTofu). NULL when the file was not authenticated.
+ALTER TABLE terraform_version_platforms
+    ADD COLUMN IF NOT EXISTS signature_method VARCHAR(16);
+
+UPDATE terraform_version_platforms SET signature_method = 'gpg' WHERE gpg_verified = true;
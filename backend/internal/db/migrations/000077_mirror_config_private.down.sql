@@ -0,0 +1,3 @@
+-- 000077_mirror_config_private.down.sql
+-- Removes the private flag from mirror_configurations.
+ALTER TABLE mirror_configurations DROP COLUMN IF EXISTS private;
@@ -0,0 +1,6 @@
+-- 000077_mirror_config_private.up.sql
+-- Lets an organization-owned provider mirror keep its providers to itself:
+-- the network mirror endpoints serve a private mirror's providers only to
+-- members of the mirror's organization.
+ALTER TABLE mirror_configurations
+    ADD COLUMN IF NOT EXISTS private BOOLEAN NOT NULL DEFAULT false;
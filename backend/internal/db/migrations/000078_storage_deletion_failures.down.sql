@@ -0,0 +1,3 @@
+-- 000078_storage_deletion_failures.down.sql
+-- Drops the record of storage objects awaiting garbage collection.
+DROP TABLE IF EXISTS storage_deletion_failures;
@@ -0,0 +1,17 @@
+-- 000078_storage_deletion_failures.up.sql
+-- Storage objects whose delete failed after the rows that referenced them
+-- were removed (e.g. a Terraform binary mirror version or config delete).
+-- Each key is recorded once; a repeated failure bumps attempts and replaces
+-- last_error. A garbage-collection pass retries the deletes and removes the
+-- rows it clears.
+CREATE TABLE storage_deletion_failures (
+    id              UUID          PRIMARY KEY DEFAULT gen_random_uuid(),
+    storage_key     VARCHAR(1024) NOT NULL UNIQUE,
+    storage_backend VARCHAR(50),
+    -- What tried to delete the object, e.g. "terraform_mirror_version".
+    source          VARCHAR(100)  NOT NULL,
+    last_error      TEXT          NOT NULL,
+    attempts        INTEGER       NOT NULL DEFAULT 1,
+    created_at      TIMESTAMP     NOT NULL DEFAULT NOW(),
+    last_attempt_at TIMESTAMP     NOT NULL DEFAULT NOW()
+);
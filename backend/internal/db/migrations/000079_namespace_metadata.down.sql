@@ -0,0 +1,3 @@
+-- 000079_namespace_metadata.down.sql
+-- Drops namespace landing-page content.
+DROP TABLE IF EXISTS namespace_metadata;
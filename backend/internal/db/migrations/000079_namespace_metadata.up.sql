@@ -0,0 +1,14 @@
+-- 000079_namespace_metadata.up.sql
+-- Landing-page content for a namespace, edited by its owning organization:
+-- a sanitized markdown description, the one-line summary derived from it
+-- for search results, a list of {label, url} links, and an avatar URL.
+CREATE TABLE namespace_metadata (
+    namespace         VARCHAR(255) PRIMARY KEY,
+    description       TEXT         NOT NULL DEFAULT '',
+    short_description VARCHAR(255) NOT NULL DEFAULT '',
+    links             JSONB        NOT NULL DEFAULT '[]'::jsonb,
+    avatar_url        VARCHAR(2048),
+    updated_by        UUID,
+    created_at        TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMP    NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- 000080_mirror_sync_leases.down.sql
+-- Drops provider mirror sync leases.
+DROP TABLE IF EXISTS mirror_sync_leases;
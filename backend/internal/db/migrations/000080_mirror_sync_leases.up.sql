@@ -0,0 +1,13 @@
+-- 000080_mirror_sync_leases.up.sql
+-- Sync leases for provider mirrors. A replica inserts a row before syncing a
+-- mirror and refreshes heartbeat_at while the sync runs; another replica may
+-- take over the lease only once the heartbeat is older than the lease TTL, so
+-- a crashed or killed sync stops blocking the mirror without a restart. The
+-- row is deleted when the sync finishes.
+CREATE TABLE mirror_sync_leases (
+    mirror_config_id UUID         PRIMARY KEY REFERENCES mirror_configurations(id) ON DELETE CASCADE,
+    -- hostname/process identifier of the replica running the sync.
+    holder           VARCHAR(255) NOT NULL,
+    acquired_at      TIMESTAMP    NOT NULL DEFAULT NOW(),
+    heartbeat_at     TIMESTAMP    NOT NULL DEFAULT NOW()
+);
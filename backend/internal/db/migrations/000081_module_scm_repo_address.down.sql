@@ -0,0 +1,5 @@
+-- 000081_module_scm_repo_address.down.sql
+-- Drops the module address of SCM links and publish metadata.
+ALTER TABLE module_version_publish_metadata DROP COLUMN IF EXISTS module_address;
+
+ALTER TABLE module_scm_repos DROP COLUMN IF EXISTS module_address;
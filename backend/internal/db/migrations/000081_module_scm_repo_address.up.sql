@@ -0,0 +1,8 @@
+-- 000081_module_scm_repo_address.up.sql
+-- The canonical registry address (hostname/namespace/name/system) of the
+-- module a repository is linked to, recorded when the link is created so the
+-- SCM publisher can stamp it into the publish metadata of every version it
+-- publishes. Links created before this migration have none.
+ALTER TABLE module_scm_repos ADD COLUMN module_address VARCHAR(512);
+
+ALTER TABLE module_version_publish_metadata ADD COLUMN module_address VARCHAR(512);
@@ -0,0 +1,4 @@
+-- 000082_storage_consistency.down.sql
+-- Drops the storage consistency checker's runs and findings.
+DROP TABLE IF EXISTS storage_consistency_findings;
+DROP TABLE IF EXISTS storage_consistency_runs;
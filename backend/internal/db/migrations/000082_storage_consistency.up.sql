@@ -0,0 +1,48 @@
+-- 000082_storage_consistency.up.sql
+-- Storage consistency checks: artifact rows whose storage object is gone
+-- (for example after a misfired bucket lifecycle rule). Each run of the
+-- checker is recorded in storage_consistency_runs; storage_consistency_findings
+-- holds one row per artifact currently missing its object, removed when a
+-- later run finds the object again or the artifact row is deleted.
+CREATE TABLE storage_consistency_runs (
+    id           UUID      PRIMARY KEY DEFAULT gen_random_uuid(),
+    full_scan    BOOLEAN   NOT NULL,
+    started_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP,
+    checked      INTEGER   NOT NULL DEFAULT 0,
+    missing      INTEGER   NOT NULL DEFAULT 0,
+    -- Exists calls that failed; those artifacts are neither flagged nor cleared.
+    errors       INTEGER   NOT NULL DEFAULT 0,
+    -- Why the run stopped early, if it did.
+    error        TEXT
+);
+
+CREATE INDEX idx_storage_consistency_runs_started ON storage_consistency_runs (started_at DESC);
+
+CREATE TABLE storage_consistency_findings (
+    id                UUID          PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- module_version | provider_platform | terraform_version_platform; the
+    -- artifact is the row with id artifact_id in that table.
+    artifact_type     VARCHAR(40)   NOT NULL,
+    artifact_id       UUID          NOT NULL,
+    storage_key       VARCHAR(1024) NOT NULL,
+    -- critical: the registry holds the only copy (uploads); warning: a mirror
+    -- sync can download it again.
+    severity          VARCHAR(20)   NOT NULL,
+    -- Human-readable artifact name, e.g. "hashicorp/aws 5.0.0 linux_amd64".
+    description       TEXT          NOT NULL,
+    -- Provider mirror or Terraform binary mirror config that can restore a
+    -- mirrored artifact.
+    mirror_config_id  UUID,
+    first_detected_at TIMESTAMP     NOT NULL DEFAULT NOW(),
+    last_detected_at  TIMESTAMP     NOT NULL DEFAULT NOW(),
+    -- Set while downloads of the artifact answer 410 Gone.
+    broken_at         TIMESTAMP,
+    UNIQUE (artifact_type, artifact_id),
+    CONSTRAINT storage_consistency_findings_type_check CHECK (
+        artifact_type IN ('module_version', 'provider_platform', 'terraform_version_platform')
+    ),
+    CONSTRAINT storage_consistency_findings_severity_check CHECK (
+        severity IN ('critical', 'warning')
+    )
+);
@@ -0,0 +1,3 @@
+-- 000083_tenant_domains.down.sql
+-- Drops the custom tenant domains.
+DROP TABLE IF EXISTS tenant_domains;
@@ -0,0 +1,33 @@
+-- 000083_tenant_domains.up.sql
+-- Custom tenant domains: hostnames that serve one organization's view of the
+-- registry from a shared deployment (for example modules.acme-blue.example.com).
+-- A request whose Host matches a row gets absolute URLs built on that host and
+-- search results scoped to the organization's claimed namespaces
+-- (namespace_claims, 000045). The optional branding columns override the
+-- global UI theme on that host. Hostnames are stored lowercase.
+CREATE TABLE IF NOT EXISTS tenant_domains (
+    id              UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    hostname        VARCHAR(253) NOT NULL UNIQUE,
+    organization_id UUID         NOT NULL,
+    product_name    VARCHAR(200),
+    primary_color   VARCHAR(7),
+    logo_url        TEXT,
+    favicon_url     TEXT,
+    created_by      UUID,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_domains_org ON tenant_domains(organization_id);
+
+-- Foreign keys follow the 000045 pattern. A domain goes with its organization.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.tenant_domains ADD CONSTRAINT tenant_domains_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+    ALTER TABLE public.tenant_domains ADD CONSTRAINT tenant_domains_created_by_fkey FOREIGN KEY (created_by) REFERENCES identity.users(id) ON DELETE SET NULL;
+  ELSE
+    ALTER TABLE public.tenant_domains ADD CONSTRAINT tenant_domains_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+    ALTER TABLE public.tenant_domains ADD CONSTRAINT tenant_domains_created_by_fkey FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL;
+  END IF;
+END $$;
@@ -0,0 +1,2 @@
+-- 000084_scm_publish_tasks.down.sql
+DROP TABLE IF EXISTS scm_publish_tasks;
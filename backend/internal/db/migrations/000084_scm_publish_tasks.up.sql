@@ -0,0 +1,30 @@
+-- 000084_scm_publish_tasks.up.sql
+-- Queue of SCM tag publishes. A webhook delivery enqueues a task and is
+-- acknowledged at once; the scm-publish-queue job claims due tasks, publishes
+-- the tag and retries failures with backoff until max_attempts, after which
+-- the task is failed for good. A task still running when its lease expires
+-- (its replica died mid-publish) is claimed again.
+CREATE TABLE scm_publish_tasks (
+    id                 UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    module_scm_repo_id UUID         NOT NULL REFERENCES module_scm_repos(id) ON DELETE CASCADE,
+    -- The webhook delivery that queued the task, if any.
+    webhook_event_id   UUID         REFERENCES scm_webhook_events(id) ON DELETE SET NULL,
+    tag_name           VARCHAR(255) NOT NULL,
+    commit_sha         VARCHAR(255),
+    -- queued | running | succeeded | skipped | failed
+    status             VARCHAR(20)  NOT NULL DEFAULT 'queued',
+    attempts           INTEGER      NOT NULL DEFAULT 0,
+    max_attempts       INTEGER      NOT NULL DEFAULT 1,
+    next_attempt_at    TIMESTAMP    NOT NULL DEFAULT NOW(),
+    locked_until       TIMESTAMP,
+    last_error         TEXT,
+    result_version_id  UUID         REFERENCES module_versions(id) ON DELETE SET NULL,
+    started_at         TIMESTAMP,
+    completed_at       TIMESTAMP,
+    created_at         TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scm_publish_tasks_due   ON scm_publish_tasks (next_attempt_at) WHERE status IN ('queued', 'running');
+CREATE INDEX idx_scm_publish_tasks_repo  ON scm_publish_tasks (module_scm_repo_id, created_at DESC);
+CREATE INDEX idx_scm_publish_tasks_event ON scm_publish_tasks (webhook_event_id);
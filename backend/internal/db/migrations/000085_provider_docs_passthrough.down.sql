@@ -0,0 +1,8 @@
+-- 000085_provider_docs_passthrough.down.sql
+ALTER TABLE provider_version_docs
+    DROP COLUMN IF EXISTS content_fetched_at,
+    DROP COLUMN IF EXISTS content;
+
+ALTER TABLE mirror_configurations
+    DROP COLUMN IF EXISTS docs_cache_ttl_hours,
+    DROP COLUMN IF EXISTS docs_passthrough_enabled;
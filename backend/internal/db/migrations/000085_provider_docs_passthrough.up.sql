@@ -0,0 +1,14 @@
+-- 000085_provider_docs_passthrough.up.sql
+-- Opt-in documentation passthrough for mirrored providers. A mirror with
+-- docs_passthrough_enabled serves its providers' upstream doc pages from
+-- /v1/providers/:namespace/:type/:version/docs; each page is fetched on first
+-- request, stored on its provider_version_docs row, and refetched once older
+-- than docs_cache_ttl_hours.
+
+ALTER TABLE mirror_configurations
+    ADD COLUMN docs_passthrough_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN docs_cache_ttl_hours     INTEGER NOT NULL DEFAULT 24;
+
+ALTER TABLE provider_version_docs
+    ADD COLUMN content            TEXT,
+    ADD COLUMN content_fetched_at TIMESTAMPTZ;
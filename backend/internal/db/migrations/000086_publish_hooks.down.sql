@@ -0,0 +1,4 @@
+-- 000086_publish_hooks.down.sql
+-- Drops pre-publish hooks and their delivery log.
+DROP TABLE IF EXISTS publish_hook_deliveries;
+DROP TABLE IF EXISTS publish_hooks;
@@ -0,0 +1,53 @@
+-- 000086_publish_hooks.up.sql
+-- Pre-publish hooks: an organization can name an HTTPS endpoint that must
+-- approve every module version before it is stored. The registry POSTs the
+-- version metadata and archive digest, signed with HMAC-SHA256 over the body
+-- using the hook's secret, and blocks the publish when the endpoint denies it.
+-- failure_mode decides what happens when the endpoint does not answer.
+-- The secret is sealed with the token cipher like other stored secrets.
+CREATE TABLE IF NOT EXISTS publish_hooks (
+    organization_id  UUID        PRIMARY KEY,
+    url              TEXT        NOT NULL,
+    encrypted_secret TEXT        NOT NULL,
+    timeout_seconds  INT         NOT NULL DEFAULT 10,
+    failure_mode     VARCHAR(16) NOT NULL DEFAULT 'fail_closed',   -- fail_closed | fail_open
+    -- Publishes consult the hook only while enabled, so it can be created
+    -- and tested first.
+    enabled          BOOLEAN     NOT NULL DEFAULT false,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT publish_hooks_timeout_check CHECK (timeout_seconds BETWEEN 1 AND 60),
+    CONSTRAINT publish_hooks_failure_mode_check CHECK (failure_mode IN ('fail_closed', 'fail_open'))
+);
+
+-- Foreign key follows the 000045 pattern (see 000051).
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.publish_hooks ADD CONSTRAINT publish_hooks_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.publish_hooks ADD CONSTRAINT publish_hooks_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+-- One row per hook call, including test calls. outcome is allowed, denied,
+-- failed_open or failed_closed.
+CREATE TABLE IF NOT EXISTS publish_hook_deliveries (
+    id              UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID         NOT NULL REFERENCES publish_hooks(organization_id) ON DELETE CASCADE,
+    source          VARCHAR(16)  NOT NULL,   -- upload | url | scm | test
+    namespace       VARCHAR(255) NOT NULL,
+    name            VARCHAR(255) NOT NULL,
+    system          VARCHAR(255) NOT NULL,
+    version         VARCHAR(50)  NOT NULL,
+    checksum        VARCHAR(64)  NOT NULL,
+    outcome         VARCHAR(16)  NOT NULL,
+    reason          TEXT,
+    status_code     INT,
+    attempts        INT          NOT NULL,
+    error           TEXT,
+    duration_ms     BIGINT       NOT NULL,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_publish_hook_deliveries_org ON publish_hook_deliveries (organization_id, created_at DESC);
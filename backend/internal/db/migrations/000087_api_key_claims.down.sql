@@ -0,0 +1,3 @@
+-- 000087_api_key_claims.down.sql
+-- Drops one-time API key retrieval links.
+DROP TABLE IF EXISTS api_key_claims;
@@ -0,0 +1,29 @@
+-- 000087_api_key_claims.up.sql
+-- One-time retrieval links for API keys. When a key is created or rotated with
+-- delivery "one_time_link", the raw key is sealed with the token cipher and
+-- held here until it is claimed once or the claim expires. Only the SHA-256 of
+-- the claim token is stored. Keys whose claim expires unclaimed are revoked by
+-- the claim expiry job.
+CREATE TABLE IF NOT EXISTS api_key_claims (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    api_key_id    UUID        NOT NULL,
+    -- Owner of the key; only they may claim it. NULL for keys without an
+    -- owning user, which admins claim.
+    user_id       UUID,
+    token_hash    VARCHAR(64) NOT NULL UNIQUE,
+    encrypted_key TEXT        NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_claims_expires_at ON api_key_claims (expires_at);
+
+-- Foreign key follows the 000045 pattern (see 000051).
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.api_key_claims ADD CONSTRAINT api_key_claims_api_key_id_fkey FOREIGN KEY (api_key_id) REFERENCES identity.api_keys(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.api_key_claims ADD CONSTRAINT api_key_claims_api_key_id_fkey FOREIGN KEY (api_key_id) REFERENCES public.api_keys(id) ON DELETE CASCADE;
+  END IF;
+END $$;
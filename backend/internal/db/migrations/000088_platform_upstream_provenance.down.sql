@@ -0,0 +1,15 @@
+-- 000088_platform_upstream_provenance.down.sql
+-- Drops upstream provenance from mirrored platforms.
+ALTER TABLE terraform_version_platforms
+    DROP COLUMN IF EXISTS fetched_at,
+    DROP COLUMN IF EXISTS upstream_shasum_url,
+    DROP COLUMN IF EXISTS upstream_download_url,
+    DROP COLUMN IF EXISTS upstream_registry,
+    DROP COLUMN IF EXISTS provenance;
+
+ALTER TABLE provider_platforms
+    DROP COLUMN IF EXISTS fetched_at,
+    DROP COLUMN IF EXISTS upstream_shasum_url,
+    DROP COLUMN IF EXISTS upstream_download_url,
+    DROP COLUMN IF EXISTS upstream_registry,
+    DROP COLUMN IF EXISTS provenance;
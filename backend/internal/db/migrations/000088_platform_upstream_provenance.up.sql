@@ -0,0 +1,33 @@
+-- 000088_platform_upstream_provenance.up.sql
+-- Upstream provenance of mirrored platform binaries: the registry host, the
+-- original download and SHA256SUMS URLs, and when the binary was fetched.
+-- provenance is 'recorded' once a sync has filled these in and 'unknown' for
+-- platforms mirrored before this migration. Uploaded provider platforms keep
+-- NULL throughout.
+ALTER TABLE provider_platforms
+    ADD COLUMN IF NOT EXISTS provenance            VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS upstream_registry     VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS upstream_download_url TEXT,
+    ADD COLUMN IF NOT EXISTS upstream_shasum_url   TEXT,
+    ADD COLUMN IF NOT EXISTS fetched_at            TIMESTAMPTZ;
+
+ALTER TABLE terraform_version_platforms
+    ADD COLUMN IF NOT EXISTS provenance            VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS upstream_registry     VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS upstream_download_url TEXT,
+    ADD COLUMN IF NOT EXISTS upstream_shasum_url   TEXT,
+    ADD COLUMN IF NOT EXISTS fetched_at            TIMESTAMPTZ;
+
+UPDATE provider_platforms
+SET provenance = 'unknown'
+WHERE provenance IS NULL
+  AND provider_version_id IN (
+      SELECT pv.id
+      FROM provider_versions pv
+      JOIN mirrored_providers mp ON mp.provider_id = pv.provider_id
+  );
+
+UPDATE terraform_version_platforms
+SET provenance = 'unknown'
+WHERE provenance IS NULL
+  AND sync_status = 'synced';
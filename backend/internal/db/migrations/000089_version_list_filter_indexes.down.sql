@@ -0,0 +1,6 @@
+-- 000089_version_list_filter_indexes.down.sql
+-- Drops the indexes behind the filtered admin version listings.
+DROP INDEX IF EXISTS idx_provider_versions_provider_published_by;
+DROP INDEX IF EXISTS idx_provider_versions_provider_created_at;
+DROP INDEX IF EXISTS idx_module_versions_module_published_by;
+DROP INDEX IF EXISTS idx_module_versions_module_created_at;
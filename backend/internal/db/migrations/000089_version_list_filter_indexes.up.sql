@@ -0,0 +1,13 @@
+-- 000089_version_list_filter_indexes.up.sql
+-- Indexes behind the filtered admin version listings
+-- (GET /api/v1/admin/modules/:id/versions and /admin/providers/:id/versions):
+-- candidates are selected per module/provider by publish date and publisher.
+CREATE INDEX IF NOT EXISTS idx_module_versions_module_created_at
+    ON module_versions (module_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_module_versions_module_published_by
+    ON module_versions (module_id, published_by);
+
+CREATE INDEX IF NOT EXISTS idx_provider_versions_provider_created_at
+    ON provider_versions (provider_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_provider_versions_provider_published_by
+    ON provider_versions (provider_id, published_by);
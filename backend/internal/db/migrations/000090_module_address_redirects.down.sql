@@ -0,0 +1,3 @@
+-- 000090_module_address_redirects.down.sql
+-- Drops the module address redirects.
+DROP TABLE IF EXISTS module_address_redirects;
@@ -0,0 +1,19 @@
+-- 000090_module_address_redirects.up.sql
+-- Old addresses of modules that were moved to another namespace or system.
+-- The protocol and detail endpoints answer requests for an old address with a
+-- redirect to the module's current one, so existing source addresses keep
+-- resolving after a module is re-systemed (e.g. from a placeholder system to
+-- the reserved "generic" system) or moved.
+CREATE TABLE IF NOT EXISTS module_address_redirects (
+    id              UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID         NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    namespace       VARCHAR(255) NOT NULL,
+    name            VARCHAR(255) NOT NULL,
+    system          VARCHAR(255) NOT NULL,
+    module_id       UUID         NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, namespace, name, system)
+);
+
+CREATE INDEX IF NOT EXISTS idx_module_address_redirects_module
+    ON module_address_redirects (module_id);
@@ -0,0 +1,6 @@
+-- 000091_webhook_delivery_ids.down.sql
+-- Drops the SCM delivery IDs of webhook events and publish tasks.
+DROP INDEX IF EXISTS idx_scm_publish_tasks_delivery;
+DROP INDEX IF EXISTS idx_scm_webhook_events_delivery;
+ALTER TABLE scm_publish_tasks  DROP COLUMN IF EXISTS delivery_id;
+ALTER TABLE scm_webhook_events DROP COLUMN IF EXISTS delivery_id;
@@ -0,0 +1,13 @@
+-- 000091_webhook_delivery_ids.up.sql
+-- The SCM provider's delivery ID (X-GitHub-Delivery, X-Gitlab-Event-UUID,
+-- X-Request-Id) of each webhook event, and of the publish it queued. A
+-- delivery redelivered from the provider's webhook log carries the same ID,
+-- so the unique indexes make a redelivery a no-op rather than a second event
+-- and a second publish. Deliveries without an ID are not deduplicated.
+ALTER TABLE scm_webhook_events ADD COLUMN IF NOT EXISTS delivery_id VARCHAR(255);
+ALTER TABLE scm_publish_tasks  ADD COLUMN IF NOT EXISTS delivery_id VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scm_webhook_events_delivery
+    ON scm_webhook_events (module_scm_repo_id, delivery_id) WHERE delivery_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scm_publish_tasks_delivery
+    ON scm_publish_tasks (module_scm_repo_id, delivery_id) WHERE delivery_id IS NOT NULL;
@@ -0,0 +1,6 @@
+-- 000092_staged_changes.down.sql
+-- Removes staged changes and the two-person rule setting.
+DROP TABLE IF EXISTS staged_changes;
+
+ALTER TABLE system_settings
+  DROP COLUMN IF EXISTS two_person_rule_enabled;
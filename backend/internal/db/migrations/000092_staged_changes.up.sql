@@ -0,0 +1,36 @@
+-- 000092_staged_changes.up.sql
+-- Two-person rule for sensitive admin changes. While
+-- system_settings.two_person_rule_enabled is true, edits to role templates,
+-- mirror policies and the OIDC group mapping are not applied directly: each
+-- is stored in staged_changes and applied only once a different admin
+-- approves it.
+ALTER TABLE system_settings
+  ADD COLUMN IF NOT EXISTS two_person_rule_enabled BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE staged_changes (
+    id             UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- role_template | mirror_policy | oidc_group_mapping | two_person_rule
+    resource_type  VARCHAR(40)  NOT NULL,
+    -- The changed row; NULL for creates and singleton settings.
+    resource_id    VARCHAR(255),
+    -- create | update | delete
+    action         VARCHAR(20)  NOT NULL,
+    -- The resource as it was when the change was requested, and the
+    -- requested change; together they are the diff reviewers see.
+    before_state   JSONB,
+    after_state    JSONB,
+    status         VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    requested_by   UUID         REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_by    UUID         REFERENCES users(id) ON DELETE SET NULL,
+    review_comment TEXT,
+    -- Why applying an approved change failed.
+    apply_error    TEXT,
+    created_at     TIMESTAMP    NOT NULL DEFAULT NOW(),
+    reviewed_at    TIMESTAMP,
+    CONSTRAINT staged_changes_action_check CHECK (action IN ('create', 'update', 'delete')),
+    CONSTRAINT staged_changes_status_check CHECK (
+        status IN ('pending', 'approved', 'rejected', 'failed')
+    )
+);
+
+CREATE INDEX idx_staged_changes_status ON staged_changes (status, created_at);
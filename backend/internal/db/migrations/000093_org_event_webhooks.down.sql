@@ -0,0 +1,4 @@
+-- 000093_org_event_webhooks.down.sql
+-- Drops organization event webhooks and their delivery log.
+DROP TABLE IF EXISTS org_event_deliveries;
+DROP TABLE IF EXISTS org_event_webhooks;
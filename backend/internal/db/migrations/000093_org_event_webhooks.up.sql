@@ -0,0 +1,55 @@
+-- 000093_org_event_webhooks.up.sql
+-- Organization event webhooks: HTTPS endpoints (typically a SIEM collector)
+-- that receive the organization's security-relevant events — membership and
+-- role changes, API key lifecycle, SCM token connections. Each delivery is
+-- signed with HMAC-SHA256 over the body using the webhook's secret, like
+-- publish hooks. The secret is sealed with the token cipher like other stored
+-- secrets. An empty event_types array subscribes to every event type.
+CREATE TABLE IF NOT EXISTS org_event_webhooks (
+    id               UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id  UUID        NOT NULL,
+    url              TEXT        NOT NULL,
+    encrypted_secret TEXT        NOT NULL,
+    event_types      TEXT[]      NOT NULL DEFAULT '{}',
+    timeout_seconds  INT         NOT NULL DEFAULT 10,
+    -- Events are delivered only while enabled, so the webhook can be created
+    -- and tested first.
+    enabled          BOOLEAN     NOT NULL DEFAULT false,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT org_event_webhooks_timeout_check CHECK (timeout_seconds BETWEEN 1 AND 60)
+);
+
+-- Foreign key follows the 000045 pattern (see 000051).
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.org_event_webhooks ADD CONSTRAINT org_event_webhooks_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.org_event_webhooks ADD CONSTRAINT org_event_webhooks_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_org_event_webhooks_org ON org_event_webhooks (organization_id);
+
+-- One row per delivery, including tests and replays. payload is the exact
+-- event body sent (JSON, not JSONB, so the text is kept as signed), so a
+-- delivery can be replayed byte for byte. outcome is delivered or failed;
+-- replay_of names the delivery a replay re-sent.
+CREATE TABLE IF NOT EXISTS org_event_deliveries (
+    id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id      UUID        NOT NULL REFERENCES org_event_webhooks(id) ON DELETE CASCADE,
+    event_id        UUID        NOT NULL,
+    event_type      VARCHAR(64) NOT NULL,
+    schema_version  INT         NOT NULL,
+    payload         JSON        NOT NULL,
+    outcome         VARCHAR(16) NOT NULL,
+    status_code     INT,
+    attempts        INT         NOT NULL,
+    error           TEXT,
+    duration_ms     BIGINT      NOT NULL,
+    replay_of       UUID,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_event_deliveries_webhook ON org_event_deliveries (webhook_id, created_at DESC);
@@ -0,0 +1,39 @@
+-- 000094_usage_consumers.up.sql
+-- Usage reports: which consumers (a repository URL or workspace name) use
+-- which module and provider versions, as reported to POST /api/v1/usage/report.
+-- There is one row per consumer, artifact and version, refreshed by every
+-- report that repeats it. Rows are never deleted when a consumer stops
+-- reporting them; the consumer listings flag them stale by last_seen_at.
+-- Addresses are stored as reported, without a registry hostname, and are not
+-- tied to module or provider rows, so usage of an artifact published later
+-- or served from a mirror is kept too.
+CREATE TABLE IF NOT EXISTS usage_consumers (
+    id                  UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id     UUID        NOT NULL,
+    consumer            TEXT        NOT NULL,
+    kind                TEXT        NOT NULL,
+    namespace           TEXT        NOT NULL,
+    name                TEXT        NOT NULL,
+    -- The module's target system; empty for providers.
+    system              TEXT        NOT NULL DEFAULT '',
+    version             TEXT        NOT NULL,
+    -- The API key of the most recent report.
+    api_key_id          UUID,
+    first_seen_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT usage_consumers_kind_check CHECK (kind IN ('module', 'provider')),
+    CONSTRAINT usage_consumers_unique UNIQUE (organization_id, consumer, kind, namespace, name, system, version)
+);
+
+-- Foreign key follows the 000045 pattern (see 000051).
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.usage_consumers ADD CONSTRAINT usage_consumers_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.usage_consumers ADD CONSTRAINT usage_consumers_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_usage_consumers_artifact
+    ON usage_consumers (kind, namespace, name, system, last_seen_at DESC);
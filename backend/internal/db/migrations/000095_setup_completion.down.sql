@@ -0,0 +1,5 @@
+-- 000095_setup_completion.down.sql
+-- Removes the setup completion record.
+ALTER TABLE system_settings
+  DROP COLUMN IF EXISTS setup_completed_at,
+  DROP COLUMN IF EXISTS setup_completed_by;
@@ -0,0 +1,8 @@
+-- 000095_setup_completion.up.sql
+-- Records when first-run setup was completed and by whom. Setup-token
+-- requests carry no user, so setup_completed_by is the client address of the
+-- POST /api/v1/setup/complete request. Both stay NULL for deployments that
+-- completed setup before this migration.
+ALTER TABLE system_settings
+  ADD COLUMN IF NOT EXISTS setup_completed_at TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN IF NOT EXISTS setup_completed_by TEXT;
@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS idx_org_event_deliveries_webhook
+    ON org_event_deliveries (webhook_id, created_at DESC);
+DROP INDEX IF EXISTS idx_org_event_deliveries_webhook_created_id;
+
+DROP INDEX IF EXISTS idx_scm_webhook_events_repo_created_id;
+
+DROP INDEX IF EXISTS idx_audit_logs_created_id;
@@ -0,0 +1,15 @@
+-- 000096_keyset_pagination_indexes.up.sql
+-- The audit log, SCM webhook event and organization event delivery listings
+-- page by keyset: ORDER BY created_at DESC, id DESC with
+-- WHERE (created_at, id) < (last page's row). These indexes serve that order
+-- directly, so a page deep in the history costs the same as the first.
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_id
+    ON audit_logs (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_scm_webhook_events_repo_created_id
+    ON scm_webhook_events (module_scm_repo_id, created_at DESC, id DESC);
+
+-- Supersedes idx_org_event_deliveries_webhook (webhook_id, created_at DESC).
+CREATE INDEX IF NOT EXISTS idx_org_event_deliveries_webhook_created_id
+    ON org_event_deliveries (webhook_id, created_at DESC, id DESC);
+DROP INDEX IF EXISTS idx_org_event_deliveries_webhook;
@@ -0,0 +1,7 @@
+-- 000097_module_version_aliases.down.sql
+-- Drops version aliasing. Aliases stay as versions that share their target's
+-- archive key, so deleting either one deletes the archive of both.
+ALTER TABLE org_module_policies DROP COLUMN IF EXISTS alias_duplicate_versions;
+DROP INDEX IF EXISTS idx_module_versions_alias_of;
+DROP INDEX IF EXISTS idx_module_versions_module_checksum;
+ALTER TABLE module_versions DROP COLUMN IF EXISTS alias_of;
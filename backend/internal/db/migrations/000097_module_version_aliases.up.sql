@@ -0,0 +1,22 @@
+-- 000097_module_version_aliases.up.sql
+-- Module versions that republish a byte-identical archive.
+--
+-- module_versions.checksum already holds the SHA-256 of each version's
+-- archive; the index lets a publish find an earlier version of the same module
+-- with the same content. When the organization's module policy sets
+-- alias_duplicate_versions, such a publish creates the version as an alias:
+-- alias_of points at the version that holds the archive, and the alias shares
+-- its storage_path instead of storing a copy. A version with aliases cannot be
+-- deleted until they are; deleting the whole module removes both together.
+ALTER TABLE module_versions
+    ADD COLUMN IF NOT EXISTS alias_of UUID REFERENCES module_versions(id);
+
+CREATE INDEX IF NOT EXISTS idx_module_versions_module_checksum
+    ON module_versions (module_id, checksum);
+
+CREATE INDEX IF NOT EXISTS idx_module_versions_alias_of
+    ON module_versions (alias_of)
+    WHERE alias_of IS NOT NULL;
+
+ALTER TABLE org_module_policies
+    ADD COLUMN IF NOT EXISTS alias_duplicate_versions BOOLEAN NOT NULL DEFAULT false;
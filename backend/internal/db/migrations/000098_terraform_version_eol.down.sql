@@ -0,0 +1,8 @@
+ALTER TABLE terraform_mirror_configs
+    DROP COLUMN IF EXISTS exclude_eol_from_latest;
+
+ALTER TABLE terraform_versions
+    DROP COLUMN IF EXISTS eol_message,
+    DROP COLUMN IF EXISTS is_eol;
+
+DROP TABLE IF EXISTS terraform_eol_rules;
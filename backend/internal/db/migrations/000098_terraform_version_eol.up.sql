@@ -0,0 +1,28 @@
+-- 000098_terraform_version_eol.up.sql
+-- End-of-life tracking for the Terraform binary mirror.
+--
+-- The upstream release indexes carry no support status, so operators keep an
+-- EOL table: each rule marks the versions of a tool whose version starts with
+-- version_prefix (a whole series such as "1.3" or a single "1.3.9") as
+-- end-of-life from eol_date, or at once when eol_date is NULL. A scheduled job
+-- copies the rules onto terraform_versions.is_eol / eol_message, which the
+-- public versions endpoints return. With exclude_eol_from_latest set on a
+-- mirror config, EOL versions are never chosen as its latest version; they
+-- can still be downloaded by explicit version.
+CREATE TABLE IF NOT EXISTS terraform_eol_rules (
+    id              UUID          PRIMARY KEY DEFAULT gen_random_uuid(),
+    tool            VARCHAR(50)   NOT NULL,
+    version_prefix  VARCHAR(50)   NOT NULL,
+    eol_date        DATE          DEFAULT NULL,
+    message         TEXT          DEFAULT NULL,
+    created_at      TIMESTAMP     NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP     NOT NULL DEFAULT NOW(),
+    CONSTRAINT terraform_eol_rules_unique UNIQUE (tool, version_prefix)
+);
+
+ALTER TABLE terraform_versions
+    ADD COLUMN IF NOT EXISTS is_eol      BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS eol_message TEXT    DEFAULT NULL;
+
+ALTER TABLE terraform_mirror_configs
+    ADD COLUMN IF NOT EXISTS exclude_eol_from_latest BOOLEAN NOT NULL DEFAULT false;
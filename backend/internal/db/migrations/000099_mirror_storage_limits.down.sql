@@ -0,0 +1,12 @@
+ALTER TABLE terraform_version_platforms
+    DROP COLUMN IF EXISTS size_bytes;
+
+ALTER TABLE terraform_mirror_configs
+    DROP CONSTRAINT IF EXISTS terraform_mirror_configs_max_storage_bytes_check,
+    DROP COLUMN IF EXISTS storage_alert_level,
+    DROP COLUMN IF EXISTS max_storage_bytes;
+
+ALTER TABLE mirror_configurations
+    DROP CONSTRAINT IF EXISTS mirror_configurations_max_storage_bytes_check,
+    DROP COLUMN IF EXISTS storage_alert_level,
+    DROP COLUMN IF EXISTS max_storage_bytes;
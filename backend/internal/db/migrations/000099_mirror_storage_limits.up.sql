@@ -0,0 +1,29 @@
+-- 000099_mirror_storage_limits.up.sql
+-- Optional storage soft limits for provider and Terraform binary mirrors.
+--
+-- max_storage_bytes caps the bytes a mirror config may hold in storage (NULL
+-- = no limit). Once its stored archives reach the limit, a sync stops
+-- downloading new versions and records last_sync_status = 'limit_reached';
+-- raising the limit lets the next sync carry on. storage_alert_level is the
+-- highest usage threshold (0, 80 or 100 percent) already notified, so each
+-- threshold is announced once until usage falls back below it.
+--
+-- Terraform binary platforms did not record their size; size_bytes is
+-- filled in as binaries are synced, and back-filled from storage metadata for
+-- binaries synced earlier.
+ALTER TABLE mirror_configurations
+    ADD COLUMN IF NOT EXISTS max_storage_bytes   BIGINT   DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS storage_alert_level SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE mirror_configurations
+    ADD CONSTRAINT mirror_configurations_max_storage_bytes_check CHECK (max_storage_bytes IS NULL OR max_storage_bytes > 0);
+
+ALTER TABLE terraform_mirror_configs
+    ADD COLUMN IF NOT EXISTS max_storage_bytes   BIGINT   DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS storage_alert_level SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE terraform_mirror_configs
+    ADD CONSTRAINT terraform_mirror_configs_max_storage_bytes_check CHECK (max_storage_bytes IS NULL OR max_storage_bytes > 0);
+
+ALTER TABLE terraform_version_platforms
+    ADD COLUMN IF NOT EXISTS size_bytes BIGINT DEFAULT NULL;
@@ -0,0 +1,4 @@
+-- 000100_provider_license_gating.down.sql
+-- Drops provider license gating and its acceptance records.
+DROP TABLE IF EXISTS provider_license_acceptances;
+DROP TABLE IF EXISTS provider_license_gates;
@@ -0,0 +1,43 @@
+-- 000100_provider_license_gating.up.sql
+-- Optional license acceptance gating per provider.
+--
+-- An admin attaches license text to a provider and enables gating; its
+-- downloads (the Provider Registry download endpoint and the network mirror
+-- platform index) are then served only to users who have accepted the
+-- current license_version. Acceptances are recorded per version and never
+-- rewritten, so changing license_version requires everyone to accept again
+-- and the table stays the audit record of who accepted what, and when.
+CREATE TABLE IF NOT EXISTS provider_license_gates (
+    provider_id     UUID         PRIMARY KEY REFERENCES providers(id) ON DELETE CASCADE,
+    license_text    TEXT         NOT NULL,
+    license_version VARCHAR(100) NOT NULL,
+    enabled         BOOLEAN      NOT NULL DEFAULT true,
+    updated_by      UUID,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS provider_license_acceptances (
+    id              UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider_id     UUID         NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+    user_id         UUID         NOT NULL,
+    license_version VARCHAR(100) NOT NULL,
+    ip_address      VARCHAR(45),
+    accepted_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (provider_id, user_id, license_version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_license_acceptances_provider
+    ON provider_license_acceptances (provider_id, accepted_at);
+
+-- Foreign keys follow the 000045 pattern. An acceptance goes with its user.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.provider_license_gates ADD CONSTRAINT provider_license_gates_updated_by_fkey FOREIGN KEY (updated_by) REFERENCES identity.users(id) ON DELETE SET NULL;
+    ALTER TABLE public.provider_license_acceptances ADD CONSTRAINT provider_license_acceptances_user_id_fkey FOREIGN KEY (user_id) REFERENCES identity.users(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.provider_license_gates ADD CONSTRAINT provider_license_gates_updated_by_fkey FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL;
+    ALTER TABLE public.provider_license_acceptances ADD CONSTRAINT provider_license_acceptances_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+  END IF;
+END $$;
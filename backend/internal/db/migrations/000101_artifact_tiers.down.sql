@@ -0,0 +1,9 @@
+-- 000101_artifact_tiers.down.sql
+-- Drops registry-assigned tiers and restores the upstream tier column name.
+DROP INDEX IF EXISTS idx_modules_tier;
+DROP INDEX IF EXISTS idx_providers_tier;
+
+ALTER TABLE modules   DROP COLUMN IF EXISTS tier;
+ALTER TABLE providers DROP COLUMN IF EXISTS tier;
+
+ALTER TABLE providers RENAME COLUMN upstream_tier TO tier;
@@ -0,0 +1,21 @@
+-- 000101_artifact_tiers.up.sql
+-- Registry-assigned tiers for providers and modules.
+--
+-- An admin badges a provider or module as community (the default), approved
+-- or official; other lowercase slugs are accepted so deployments can add
+-- their own. Mirrored providers default to 'mirrored', so pass-through
+-- artifacts are distinguishable from first-party ones.
+--
+-- providers.tier (000052) held the tier the upstream registry reports for a
+-- mirrored provider; it is renamed upstream_tier so tier can carry the
+-- registry's own.
+ALTER TABLE providers RENAME COLUMN tier TO upstream_tier;
+
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS tier VARCHAR(32) NOT NULL DEFAULT 'community';
+ALTER TABLE modules   ADD COLUMN IF NOT EXISTS tier VARCHAR(32) NOT NULL DEFAULT 'community';
+
+UPDATE providers SET tier = 'mirrored'
+WHERE id IN (SELECT provider_id FROM mirrored_providers);
+
+CREATE INDEX IF NOT EXISTS idx_providers_tier ON providers(tier);
+CREATE INDEX IF NOT EXISTS idx_modules_tier   ON modules(tier);
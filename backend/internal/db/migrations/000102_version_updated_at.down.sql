@@ -0,0 +1,14 @@
+-- 000102_version_updated_at.down.sql
+-- Drops the version updated_at columns and the triggers maintaining them.
+DROP TRIGGER IF EXISTS trg_provider_platforms_touch_version ON provider_platforms;
+DROP TRIGGER IF EXISTS trg_provider_versions_updated_at ON provider_versions;
+DROP TRIGGER IF EXISTS trg_module_versions_updated_at ON module_versions;
+DROP FUNCTION IF EXISTS provider_platforms_touch_version();
+DROP FUNCTION IF EXISTS version_updated_at_touch();
+
+DROP INDEX IF EXISTS idx_terraform_versions_updated_at;
+DROP INDEX IF EXISTS idx_provider_versions_updated_at;
+DROP INDEX IF EXISTS idx_module_versions_updated_at;
+
+ALTER TABLE provider_versions DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE module_versions   DROP COLUMN IF EXISTS updated_at;
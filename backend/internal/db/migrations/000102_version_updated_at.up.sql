@@ -0,0 +1,48 @@
+-- 000102_version_updated_at.up.sql
+-- updated_at for module and provider versions, so the metadata export
+-- (GET /api/v1/admin/export/metadata) can be consumed incrementally.
+--
+-- Triggers keep it current: only changes to exported metadata bump it, so
+-- download counters and storage migrations do not re-export a version.
+-- Changes to a provider version's platforms bump the version too.
+-- terraform_versions already has updated_at, maintained by its repository.
+ALTER TABLE module_versions   ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+ALTER TABLE provider_versions ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+
+UPDATE module_versions   SET updated_at = GREATEST(created_at, COALESCE(deprecated_at, created_at));
+UPDATE provider_versions SET updated_at = GREATEST(created_at, COALESCE(deprecated_at, created_at));
+
+ALTER TABLE module_versions   ALTER COLUMN updated_at SET NOT NULL, ALTER COLUMN updated_at SET DEFAULT NOW();
+ALTER TABLE provider_versions ALTER COLUMN updated_at SET NOT NULL, ALTER COLUMN updated_at SET DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_module_versions_updated_at    ON module_versions (updated_at);
+CREATE INDEX IF NOT EXISTS idx_provider_versions_updated_at  ON provider_versions (updated_at);
+CREATE INDEX IF NOT EXISTS idx_terraform_versions_updated_at ON terraform_versions (updated_at);
+
+CREATE OR REPLACE FUNCTION version_updated_at_touch() RETURNS trigger AS $$
+BEGIN
+  NEW.updated_at := NOW();
+  RETURN NEW;
+END $$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_module_versions_updated_at
+  BEFORE UPDATE OF version, checksum, published_by, deprecated, deprecated_at,
+    deprecation_message, replacement_source, archived_at, changelog,
+    required_terraform_version, alias_of ON module_versions
+  FOR EACH ROW EXECUTE FUNCTION version_updated_at_touch();
+
+CREATE TRIGGER trg_provider_versions_updated_at
+  BEFORE UPDATE OF version, protocols, gpg_public_key, published_by, deprecated,
+    deprecated_at, deprecation_message ON provider_versions
+  FOR EACH ROW EXECUTE FUNCTION version_updated_at_touch();
+
+CREATE OR REPLACE FUNCTION provider_platforms_touch_version() RETURNS trigger AS $$
+BEGIN
+  UPDATE provider_versions SET updated_at = NOW()
+  WHERE id = COALESCE(NEW.provider_version_id, OLD.provider_version_id);
+  RETURN NULL;
+END $$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_provider_platforms_touch_version
+  AFTER INSERT OR DELETE OR UPDATE OF os, arch, filename, shasum ON provider_platforms
+  FOR EACH ROW EXECUTE FUNCTION provider_platforms_touch_version();
@@ -0,0 +1,6 @@
+-- 000103_binaries_scopes.down.sql
+-- Remove the binaries scopes from system role templates.
+
+UPDATE role_templates
+SET scopes = scopes - 'binaries:read' - 'binaries:manage'
+WHERE is_system = true;
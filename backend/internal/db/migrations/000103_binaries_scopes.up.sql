@@ -0,0 +1,12 @@
+-- 000103_binaries_scopes.up.sql
+-- binaries:read and binaries:manage split the Terraform binary mirror out of
+-- mirrors:read and mirrors:manage. Give the new scopes to every system role
+-- template that held the mirror scope they were split from.
+
+UPDATE role_templates
+SET scopes = scopes || '["binaries:read"]'::jsonb
+WHERE is_system = true AND scopes ? 'mirrors:read' AND NOT scopes ? 'binaries:read';
+
+UPDATE role_templates
+SET scopes = scopes || '["binaries:manage"]'::jsonb
+WHERE is_system = true AND scopes ? 'mirrors:manage' AND NOT scopes ? 'binaries:manage';
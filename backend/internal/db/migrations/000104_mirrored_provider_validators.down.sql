@@ -0,0 +1,4 @@
+-- 000104_mirrored_provider_validators.down.sql
+ALTER TABLE mirrored_providers
+    DROP COLUMN IF EXISTS upstream_last_modified,
+    DROP COLUMN IF EXISTS upstream_etag;
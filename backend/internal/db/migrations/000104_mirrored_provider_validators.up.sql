@@ -0,0 +1,8 @@
+-- 000104_mirrored_provider_validators.up.sql
+-- Cache validators (ETag, Last-Modified) of the upstream version listing as of
+-- a mirror's last complete sync of the provider. A sync sends them back, and
+-- skips the provider when the upstream answers 304 Not Modified. NULL when
+-- the upstream sent none or the last sync was incomplete.
+ALTER TABLE mirrored_providers
+    ADD COLUMN IF NOT EXISTS upstream_etag          TEXT,
+    ADD COLUMN IF NOT EXISTS upstream_last_modified TEXT;
@@ -0,0 +1,6 @@
+-- 000105_scm_publish_backfills.down.sql
+DROP INDEX IF EXISTS idx_scm_publish_tasks_backfill;
+
+ALTER TABLE scm_publish_tasks
+    DROP COLUMN IF EXISTS backfill_seq,
+    DROP COLUMN IF EXISTS backfill_id;
@@ -0,0 +1,12 @@
+-- 000105_scm_publish_backfills.up.sql
+-- Tag backfills queue one publish task per historical tag of a newly linked
+-- repository. backfill_id groups the tasks of one backfill for its progress
+-- endpoint, and backfill_seq is each task's place in it, oldest version first.
+-- The tasks are ordinary queue entries, so a backfill interrupted by a
+-- restart carries on where it stopped.
+ALTER TABLE scm_publish_tasks
+    ADD COLUMN IF NOT EXISTS backfill_id  UUID,
+    ADD COLUMN IF NOT EXISTS backfill_seq INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_scm_publish_tasks_backfill
+    ON scm_publish_tasks (backfill_id, backfill_seq) WHERE backfill_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- 000106_org_scope_ceilings.down.sql
+-- Drops per-organization scope ceilings. Tokens issued from then on carry the
+-- user's full role-template scopes.
+DROP TABLE IF EXISTS org_scope_ceilings;
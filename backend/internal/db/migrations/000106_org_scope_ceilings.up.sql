@@ -0,0 +1,27 @@
+-- 000106_org_scope_ceilings.up.sql
+-- Per-organization scope ceiling for issued JWTs.
+--
+-- A ceiling lists the scopes a session token may carry on behalf of the
+-- organization. At token issuance (login and refresh) the user's combined
+-- role-template scopes are intersected with the union of the ceilings of the
+-- organizations they belong to; an organization without a row sets no
+-- ceiling. The admin scope is kept past a ceiling only for the users listed
+-- in auth.global_admins.
+CREATE TABLE IF NOT EXISTS org_scope_ceilings (
+    organization_id  UUID        PRIMARY KEY,
+    scopes           JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Foreign key follows the 000045 pattern: point at the identity schema when
+-- the identity-schema cutover has happened, otherwise at public. A ceiling has
+-- no meaning without its organization, so it is dropped with it.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.org_scope_ceilings ADD CONSTRAINT org_scope_ceilings_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES identity.organizations(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.org_scope_ceilings ADD CONSTRAINT org_scope_ceilings_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE;
+  END IF;
+END $$;
@@ -0,0 +1,9 @@
+-- 000107_org_event_webhook_secret_rotation.down.sql
+-- Drops event webhook signing key IDs and any rotation in progress; the
+-- current secret is kept.
+ALTER TABLE org_event_webhooks
+    DROP COLUMN IF EXISTS rotation_overlap_until,
+    DROP COLUMN IF EXISTS rotation_started_at,
+    DROP COLUMN IF EXISTS next_secret_key_id,
+    DROP COLUMN IF EXISTS next_encrypted_secret,
+    DROP COLUMN IF EXISTS secret_key_id;
@@ -0,0 +1,13 @@
+-- 000107_org_event_webhook_secret_rotation.up.sql
+-- Signing key IDs and secret rotation for organization event webhooks. Each
+-- secret gets a short random key ID, sent with every signature so receivers
+-- can tell which secret signed a delivery. A rotation stores the next secret
+-- alongside the current one: until rotation_overlap_until, deliveries are
+-- signed with both; after it, with the next secret only. Completing the
+-- rotation promotes the next secret and clears the next_* columns.
+ALTER TABLE org_event_webhooks
+    ADD COLUMN IF NOT EXISTS secret_key_id          TEXT NOT NULL DEFAULT substr(md5(random()::text), 1, 16),
+    ADD COLUMN IF NOT EXISTS next_encrypted_secret  TEXT,
+    ADD COLUMN IF NOT EXISTS next_secret_key_id     TEXT,
+    ADD COLUMN IF NOT EXISTS rotation_started_at    TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS rotation_overlap_until TIMESTAMPTZ;
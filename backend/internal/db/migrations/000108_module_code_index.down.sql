@@ -0,0 +1,4 @@
+-- 000108_module_code_index.down.sql
+-- Drops the module content index.
+DROP TABLE IF EXISTS module_code_symbols;
+DROP TABLE IF EXISTS module_version_code_index;
@@ -0,0 +1,52 @@
+-- 000108_module_code_index.up.sql
+-- Content index behind GET /api/v1/search/code. Every module version gets a
+-- module_version_code_index row, queued as pending by the code index job and
+-- replaced when it is indexed again; module_code_symbols holds the blocks the
+-- job extracted from the version's .tf files: resources, data sources,
+-- variables and module calls, each with its file, lines and source text.
+CREATE TABLE module_version_code_index (
+    module_version_id UUID        PRIMARY KEY REFERENCES module_versions(id) ON DELETE CASCADE,
+    status            VARCHAR(20) NOT NULL DEFAULT 'pending',
+    files_indexed     INTEGER     NOT NULL DEFAULT 0,
+    symbols_indexed   INTEGER     NOT NULL DEFAULT 0,
+    bytes_indexed     BIGINT      NOT NULL DEFAULT 0,
+    -- Set when the version's .tf files exceed code_index.max_bytes_per_version;
+    -- files past the cap are not indexed.
+    truncated         BOOLEAN     NOT NULL DEFAULT FALSE,
+    error_message     TEXT,
+    indexed_at        TIMESTAMP,
+    created_at        TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMP   NOT NULL DEFAULT NOW(),
+    CONSTRAINT module_version_code_index_status_check CHECK (
+        status IN ('pending', 'indexing', 'indexed', 'error')
+    )
+);
+
+CREATE INDEX idx_module_version_code_index_pending
+    ON module_version_code_index (created_at) WHERE status = 'pending';
+
+CREATE TABLE module_code_symbols (
+    id                BIGSERIAL     PRIMARY KEY,
+    module_version_id UUID          NOT NULL REFERENCES module_versions(id) ON DELETE CASCADE,
+    -- resource | data | variable | module
+    kind              VARCHAR(20)   NOT NULL,
+    -- Resource or data source type, variable name, or module call name.
+    symbol            VARCHAR(255)  NOT NULL,
+    -- Resource or data source name; unset for variables and module calls.
+    label             VARCHAR(255),
+    -- A module call's source, when it is a literal string.
+    source            TEXT,
+    file_path         VARCHAR(1024) NOT NULL,
+    start_line        INTEGER       NOT NULL,
+    end_line          INTEGER       NOT NULL,
+    -- The block's source text, capped.
+    body              TEXT          NOT NULL,
+    search_vector     tsvector      GENERATED ALWAYS AS (to_tsvector('simple', body)) STORED,
+    CONSTRAINT module_code_symbols_kind_check CHECK (
+        kind IN ('resource', 'data', 'variable', 'module')
+    )
+);
+
+CREATE INDEX idx_module_code_symbols_version ON module_code_symbols (module_version_id);
+CREATE INDEX idx_module_code_symbols_symbol  ON module_code_symbols (kind, symbol);
+CREATE INDEX idx_module_code_symbols_search  ON module_code_symbols USING GIN (search_vector);
@@ -0,0 +1 @@
+-- no-op: the validators are stored again by the next complete sync
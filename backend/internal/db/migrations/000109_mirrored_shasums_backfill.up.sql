@@ -0,0 +1,8 @@
+-- 000109_mirrored_shasums_backfill.up.sql
+-- Mirror sync now stores each version's SHA256SUMS file and signature, and
+-- backfills versions synced before it did when it lists their provider.
+-- Drop the listing validators so the next sync lists every mirrored provider
+-- in full instead of skipping it on 304 Not Modified.
+UPDATE mirrored_providers
+SET upstream_etag = NULL, upstream_last_modified = NULL
+WHERE upstream_etag IS NOT NULL OR upstream_last_modified IS NOT NULL;
@@ -0,0 +1,3 @@
+-- 000110_identity_link_requests.down.sql
+-- Drops pending identity link requests. Unconfirmed links are lost.
+DROP TABLE IF EXISTS identity_link_requests;
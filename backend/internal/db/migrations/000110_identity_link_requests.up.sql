@@ -0,0 +1,40 @@
+-- 000110_identity_link_requests.up.sql
+-- Pending requests to link an SSO identity to an existing account.
+--
+-- An OIDC, Azure AD, SAML or LDAP login whose verified email matches an
+-- account without a linked identity no longer links it silently. The login
+-- stores a request here and hands the user a single-use link token instead;
+-- the identity is attached once the user confirms the link by authenticating
+-- as the existing account, or an admin approves it. Linking deletes the
+-- account's requests, and requests expire unconfirmed.
+CREATE TABLE IF NOT EXISTS identity_link_requests (
+    id            UUID          PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID          NOT NULL,
+    -- The subject to link, in users.oidc_sub form (saml:/ldap: prefixed for
+    -- those providers).
+    oidc_sub      VARCHAR(255)  NOT NULL,
+    -- oidc | azuread | saml | ldap
+    auth_provider VARCHAR(20)   NOT NULL,
+    -- The OIDC/Azure AD token issuer or SAML IdP name; empty for LDAP.
+    auth_issuer   VARCHAR(1024) NOT NULL DEFAULT '',
+    email         VARCHAR(255)  NOT NULL,
+    name          VARCHAR(255)  NOT NULL DEFAULT '',
+    -- hex SHA-256 of the link token; the token itself is never stored.
+    token_hash    VARCHAR(64)   NOT NULL UNIQUE,
+    expires_at    TIMESTAMPTZ   NOT NULL,
+    created_at    TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, oidc_sub)
+);
+
+CREATE INDEX IF NOT EXISTS idx_identity_link_requests_expires_at ON identity_link_requests(expires_at);
+
+-- Foreign key follows the 000045 pattern. A request has no meaning without
+-- its account, so it is dropped with it.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = 'identity') THEN
+    ALTER TABLE public.identity_link_requests ADD CONSTRAINT identity_link_requests_user_id_fkey FOREIGN KEY (user_id) REFERENCES identity.users(id) ON DELETE CASCADE;
+  ELSE
+    ALTER TABLE public.identity_link_requests ADD CONSTRAINT identity_link_requests_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+  END IF;
+END $$;
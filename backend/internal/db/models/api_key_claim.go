@@ -0,0 +1,23 @@
+// Package models — api_key_claim.go defines a pending one-time retrieval link
+// for an API key whose raw value was not returned when it was created.
+package models
+
+import "time"
+
+// API key delivery modes for create and rotate.
+const (
+	APIKeyDeliveryResponse    = "response"      // raw key in the response body (default)
+	APIKeyDeliveryOneTimeLink = "one_time_link" // single-use claim link
+)
+
+// APIKeyClaim holds an API key's raw value, sealed with the token cipher,
+// until it is claimed once or expires.
+type APIKeyClaim struct {
+	ID           string
+	APIKeyID     string
+	UserID       *string // owner allowed to claim; nil means admins only
+	TokenHash    string  // hex SHA-256 of the claim token
+	EncryptedKey string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
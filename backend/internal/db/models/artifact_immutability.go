@@ -0,0 +1,175 @@
+// Package models — artifact_immutability.go defines the write-once artifact
+// settings, how the registry-wide and per-organization settings combine, and
+// the hash-chained publish log that records every immutable publish.
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Publish log artifact types.
+const (
+	PublishLogArtifactModule   = "module"
+	PublishLogArtifactProvider = "provider"
+)
+
+// PublishLogGenesisHash is the prev_hash of the first publish log entry.
+const PublishLogGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// OrgArtifactImmutability is an organization's artifact immutability setting.
+type OrgArtifactImmutability struct {
+	OrganizationID string    `json:"organization_id"`
+	Enabled        bool      `json:"enabled"`
+	RetentionDays  int       `json:"retention_days"` // 0 = versions are never deletable
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// EffectiveImmutability is the immutability that applies to one
+// organization's artifacts.
+type EffectiveImmutability struct {
+	Enabled bool `json:"enabled"`
+	// RetentionDays is how old a version must be before it may be deleted;
+	// 0 means never.
+	RetentionDays int `json:"retention_days"`
+}
+
+// MergeImmutability combines the registry-wide setting with an
+// organization's (nil when it has none). Immutability applies if either
+// enables it, and the longest retention of the enabled settings wins, where
+// 0 (never deletable) is the longest.
+func MergeImmutability(globalEnabled bool, globalRetentionDays int, org *OrgArtifactImmutability) EffectiveImmutability {
+	eff := EffectiveImmutability{}
+	apply := func(retentionDays int) {
+		if !eff.Enabled {
+			eff.Enabled = true
+			eff.RetentionDays = retentionDays
+			return
+		}
+		if retentionDays == 0 || eff.RetentionDays == 0 {
+			eff.RetentionDays = 0
+		} else if retentionDays > eff.RetentionDays {
+			eff.RetentionDays = retentionDays
+		}
+	}
+	if globalEnabled {
+		apply(globalRetentionDays)
+	}
+	if org != nil && org.Enabled {
+		apply(org.RetentionDays)
+	}
+	return eff
+}
+
+// DeletableAt returns when a version published at publishedAt may be deleted,
+// and false when it never may. It is publishedAt itself when immutability
+// does not apply.
+func (e EffectiveImmutability) DeletableAt(publishedAt time.Time) (time.Time, bool) {
+	if !e.Enabled {
+		return publishedAt, true
+	}
+	if e.RetentionDays == 0 {
+		return time.Time{}, false
+	}
+	return publishedAt.AddDate(0, 0, e.RetentionDays), true
+}
+
+// PublishLogEntry is one entry of the publish log.
+type PublishLogEntry struct {
+	ID             int64     `json:"id"`
+	ArtifactType   string    `json:"artifact_type"`
+	OrganizationID *string   `json:"organization_id,omitempty"`
+	Namespace      string    `json:"namespace"`
+	Name           string    `json:"name"`   // module name or provider type
+	Target         string    `json:"target"` // module system or provider os_arch
+	Version        string    `json:"version"`
+	StorageKey     string    `json:"storage_key"`
+	Checksum       string    `json:"checksum"`
+	PublishedBy    *string   `json:"published_by,omitempty"`
+	PublishedAt    time.Time `json:"published_at"`
+	PrevHash       string    `json:"prev_hash"`
+	EntryHash      string    `json:"entry_hash"`
+}
+
+// ComputeHash returns the SHA-256 (hex) of the entry's fields and PrevHash.
+// ID and EntryHash are not covered. PublishedAt is hashed at microsecond
+// precision in UTC, which is what the database stores.
+func (e *PublishLogEntry) ComputeHash() string {
+	var org, by string
+	if e.OrganizationID != nil {
+		org = *e.OrganizationID
+	}
+	if e.PublishedBy != nil {
+		by = *e.PublishedBy
+	}
+	fields := []string{
+		e.PrevHash,
+		e.ArtifactType,
+		org,
+		e.Namespace,
+		e.Name,
+		e.Target,
+		e.Version,
+		e.StorageKey,
+		e.Checksum,
+		by,
+		e.PublishedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// PublishLogIntegrity is the result of validating the publish log's chain.
+type PublishLogIntegrity struct {
+	Valid          bool   `json:"valid"`
+	EntriesChecked int    `json:"entries_checked"`
+	HeadHash       string `json:"head_hash"`
+	// FirstInvalidID and Problem describe the first entry that breaks the
+	// chain; they are unset when the chain is valid.
+	FirstInvalidID *int64 `json:"first_invalid_id,omitempty"`
+	Problem        string `json:"problem,omitempty"`
+}
+
+// NewPublishLogIntegrity returns the state of an empty, valid chain, ready to
+// Extend with the log's entries from the first one on.
+func NewPublishLogIntegrity() *PublishLogIntegrity {
+	return &PublishLogIntegrity{Valid: true, HeadHash: PublishLogGenesisHash}
+}
+
+// Extend checks the next entries of the log, in ID order: each must link to
+// the one before it and its hash must match its contents. It stops at the
+// first broken entry and reports whether the chain is still valid.
+func (r *PublishLogIntegrity) Extend(entries []*PublishLogEntry) bool {
+	if !r.Valid {
+		return false
+	}
+	for _, e := range entries {
+		problem := ""
+		switch {
+		case e.PrevHash != r.HeadHash:
+			problem = "prev_hash does not match the previous entry's hash"
+		case e.ComputeHash() != e.EntryHash:
+			problem = "entry_hash does not match the entry's contents"
+		}
+		if problem != "" {
+			id := e.ID
+			r.Valid = false
+			r.FirstInvalidID = &id
+			r.Problem = problem
+			return false
+		}
+		r.EntriesChecked++
+		r.HeadHash = e.EntryHash
+	}
+	return true
+}
+
+// VerifyPublishLog checks a complete log, in ID order.
+func VerifyPublishLog(entries []*PublishLogEntry) *PublishLogIntegrity {
+	res := NewPublishLogIntegrity()
+	res.Extend(entries)
+	return res
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeImmutability(t *testing.T) {
+	tests := []struct {
+		name          string
+		global        bool
+		globalDays    int
+		org           *OrgArtifactImmutability
+		wantEnabled   bool
+		wantRetention int
+	}{
+		{"nothing enabled", false, 30, nil, false, 0},
+		{"global only", true, 30, nil, true, 30},
+		{"org only", false, 0, &OrgArtifactImmutability{Enabled: true, RetentionDays: 90}, true, 90},
+		{"disabled org ignored", true, 30, &OrgArtifactImmutability{Enabled: false, RetentionDays: 0}, true, 30},
+		{"longest retention wins", true, 30, &OrgArtifactImmutability{Enabled: true, RetentionDays: 90}, true, 90},
+		{"never deletable wins", true, 0, &OrgArtifactImmutability{Enabled: true, RetentionDays: 90}, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeImmutability(tt.global, tt.globalDays, tt.org)
+			if got.Enabled != tt.wantEnabled || got.RetentionDays != tt.wantRetention {
+				t.Errorf("MergeImmutability() = %+v, want enabled=%v retention=%d", got, tt.wantEnabled, tt.wantRetention)
+			}
+		})
+	}
+}
+
+func TestEffectiveImmutability_DeletableAt(t *testing.T) {
+	published := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if at, ok := (EffectiveImmutability{}).DeletableAt(published); !ok || !at.Equal(published) {
+		t.Errorf("disabled: DeletableAt = %v, %v", at, ok)
+	}
+	if at, ok := (EffectiveImmutability{Enabled: true, RetentionDays: 30}).DeletableAt(published); !ok || !at.Equal(published.AddDate(0, 0, 30)) {
+		t.Errorf("30 days: DeletableAt = %v, %v", at, ok)
+	}
+	if _, ok := (EffectiveImmutability{Enabled: true}).DeletableAt(published); ok {
+		t.Error("retention 0 should never be deletable")
+	}
+}
+
+func chainEntries(n int) []*PublishLogEntry {
+	prev := PublishLogGenesisHash
+	var out []*PublishLogEntry
+	for i := 0; i < n; i++ {
+		e := &PublishLogEntry{
+			ID:           int64(i + 1),
+			ArtifactType: PublishLogArtifactModule,
+			Namespace:    "acme",
+			Name:         "vpc",
+			Target:       "aws",
+			Version:      "1.0." + string(rune('0'+i)),
+			StorageKey:   "modules/acme/vpc/aws/1.0.tar.gz",
+			Checksum:     "abc",
+			PublishedAt:  time.Date(2026, 1, 1, 0, 0, i, 123456789, time.UTC),
+			PrevHash:     prev,
+		}
+		e.EntryHash = e.ComputeHash()
+		prev = e.EntryHash
+		out = append(out, e)
+	}
+	return out
+}
+
+func TestVerifyPublishLog(t *testing.T) {
+	res := VerifyPublishLog(nil)
+	if !res.Valid || res.EntriesChecked != 0 || res.HeadHash != PublishLogGenesisHash {
+		t.Errorf("empty log: %+v", res)
+	}
+
+	entries := chainEntries(3)
+	res = VerifyPublishLog(entries)
+	if !res.Valid || res.EntriesChecked != 3 || res.HeadHash != entries[2].EntryHash {
+		t.Errorf("intact log: %+v", res)
+	}
+
+	// Rewriting an entry's contents breaks its hash.
+	entries[1].Checksum = "def"
+	res = VerifyPublishLog(entries)
+	if res.Valid || res.FirstInvalidID == nil || *res.FirstInvalidID != 2 || res.EntriesChecked != 1 {
+		t.Errorf("tampered entry: %+v", res)
+	}
+
+	// Removing an entry breaks the link of the one after it.
+	entries = chainEntries(3)
+	res = VerifyPublishLog([]*PublishLogEntry{entries[0], entries[2]})
+	if res.Valid || res.FirstInvalidID == nil || *res.FirstInvalidID != 3 {
+		t.Errorf("removed entry: %+v", res)
+	}
+}
+
+func TestPublishLogEntry_ComputeHashIgnoresStoragePrecision(t *testing.T) {
+	e := chainEntries(1)[0]
+	stored := *e
+	stored.PublishedAt = e.PublishedAt.Truncate(time.Microsecond).In(time.FixedZone("X", 3600))
+	if stored.ComputeHash() != e.EntryHash {
+		t.Error("hash should not depend on sub-microsecond precision or time zone")
+	}
+}
@@ -0,0 +1,66 @@
+// Package models — ci_trust_rule.go defines the per-namespace trust rules that
+// decide which CI OIDC identities may exchange their ID token for a
+// short-lived publish token, and the matching logic applied at exchange time.
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// CITrustRule lets CI jobs from one repository on a trusted OIDC issuer
+// publish modules into a namespace. RefPattern and Environment narrow the rule
+// further; nil matches any value.
+type CITrustRule struct {
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"organization_id"`
+	Namespace      string     `json:"namespace"`
+	Issuer         string     `json:"issuer"`
+	Repository     string     `json:"repository"`
+	RefPattern     *string    `json:"ref_pattern,omitempty"`
+	Environment    *string    `json:"environment,omitempty"`
+	Description    *string    `json:"description,omitempty"`
+	Enabled        bool       `json:"enabled"`
+	CreatedBy      *string    `json:"created_by,omitempty"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// CIIdentity is the subset of a verified CI OIDC ID token that trust rules
+// are matched against. Field names follow the GitHub Actions claims.
+type CIIdentity struct {
+	Issuer      string `json:"iss"`
+	Subject     string `json:"sub"`
+	Repository  string `json:"repository,omitempty"`
+	Ref         string `json:"ref,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Workflow    string `json:"workflow_ref,omitempty"`
+	Actor       string `json:"actor,omitempty"`
+	SHA         string `json:"sha,omitempty"`
+	RunID       string `json:"run_id,omitempty"`
+}
+
+// Matches reports whether the identity satisfies the rule. Disabled rules
+// never match. The repository is compared case-insensitively (GitHub
+// repository names are case-insensitive); the ref pattern is a path.Match
+// glob, so "refs/tags/v*" matches "refs/tags/v1.2.0" but not a branch.
+func (r *CITrustRule) Matches(id *CIIdentity) bool {
+	if !r.Enabled || id == nil {
+		return false
+	}
+	if r.Issuer != id.Issuer || id.Repository == "" || !strings.EqualFold(r.Repository, id.Repository) {
+		return false
+	}
+	if r.RefPattern != nil && *r.RefPattern != "" {
+		ok, err := path.Match(*r.RefPattern, id.Ref)
+		if err != nil || !ok {
+			return false
+		}
+	}
+	if r.Environment != nil && *r.Environment != "" && *r.Environment != id.Environment {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package models
+
+import "testing"
+
+func TestCITrustRule_Matches(t *testing.T) {
+	const gha = "https://token.actions.githubusercontent.com"
+	str := func(s string) *string { return &s }
+
+	base := func() *CIIdentity {
+		return &CIIdentity{Issuer: gha, Repository: "Acme/terraform-aws-vpc", Ref: "refs/tags/v1.2.0", Environment: "release"}
+	}
+
+	tests := []struct {
+		name string
+		rule CITrustRule
+		id   func() *CIIdentity
+		want bool
+	}{
+		{"repository only", CITrustRule{Enabled: true, Issuer: gha, Repository: "acme/terraform-aws-vpc"}, base, true},
+		{"disabled", CITrustRule{Enabled: false, Issuer: gha, Repository: "acme/terraform-aws-vpc"}, base, false},
+		{"wrong issuer", CITrustRule{Enabled: true, Issuer: "https://gitlab.example.com", Repository: "acme/terraform-aws-vpc"}, base, false},
+		{"wrong repository", CITrustRule{Enabled: true, Issuer: gha, Repository: "acme/other"}, base, false},
+		{"tag pattern", CITrustRule{Enabled: true, Issuer: gha, Repository: "acme/terraform-aws-vpc", RefPattern: str("refs/tags/v*")}, base, true},
+		{"branch does not match tag pattern", CITrustRule{Enabled: true, Issuer: gha, Repository: "acme/terraform-aws-vpc", RefPattern: str("refs/tags/v*")}, func() *CIIdentity {
+			id := base()
+			id.Ref = "refs/heads/main"
+			return id
+		}, false},
+		{"environment", CITrustRule{Enabled: true, Issuer: gha, Repository: "acme/terraform-aws-vpc", Environment: str("release")}, base, true},
+		{"wrong environment", CITrustRule{Enabled: true, Issuer: gha, Repository: "acme/terraform-aws-vpc", Environment: str("prod")}, base, false},
+		{"missing repository claim", CITrustRule{Enabled: true, Issuer: gha, Repository: "acme/terraform-aws-vpc"}, func() *CIIdentity {
+			return &CIIdentity{Issuer: gha}
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.id()); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
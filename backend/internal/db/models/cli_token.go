@@ -0,0 +1,21 @@
+// Package models — cli_token.go defines the record kept for each token issued
+// to the Terraform CLI. The token itself is never stored.
+package models
+
+import "time"
+
+// CLIToken is an issued Terraform CLI token, identified by its JTI.
+type CLIToken struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Description *string    `json:"description,omitempty"`
+	Scopes      []string   `json:"scopes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the token is neither revoked nor expired at now.
+func (t *CLIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
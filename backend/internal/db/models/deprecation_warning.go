@@ -0,0 +1,41 @@
+// Package models - deprecation_warning.go renders the human-readable warning
+// lines the registry protocol documents carry for deprecated versions.
+package models
+
+import "strings"
+
+// DeprecationWarning returns the warning shown to terraform users for a
+// deprecated module version, or "" when the version is not deprecated.
+func (v *ModuleVersion) DeprecationWarning() string {
+	if !v.Deprecated {
+		return ""
+	}
+	return deprecationWarning(v.Version, v.DeprecationMessage, v.ReplacementSource)
+}
+
+// DeprecationWarning returns the warning shown to terraform users for a
+// deprecated provider version, or "" when the version is not deprecated.
+func (v *ProviderVersion) DeprecationWarning() string {
+	if !v.Deprecated {
+		return ""
+	}
+	return deprecationWarning(v.Version, v.DeprecationMessage, nil)
+}
+
+// deprecationWarning formats "1.2.0 is deprecated: <reason> (replacement: <source>)",
+// dropping whichever optional parts are unset.
+func deprecationWarning(version string, message, replacement *string) string {
+	var b strings.Builder
+	b.WriteString(version)
+	b.WriteString(" is deprecated")
+	if message != nil && strings.TrimSpace(*message) != "" {
+		b.WriteString(": ")
+		b.WriteString(strings.TrimSpace(*message))
+	}
+	if replacement != nil && *replacement != "" {
+		b.WriteString(" (replacement: ")
+		b.WriteString(*replacement)
+		b.WriteString(")")
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestDeprecationWarning(t *testing.T) {
+	reason := "CVE-2024-1234, upgrade to 1.2.1"
+	replacement := "acme/vpc/aws"
+	blank := "  "
+
+	cases := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"not deprecated", (&ProviderVersion{Version: "1.2.0"}).DeprecationWarning(), ""},
+		{"provider with reason", (&ProviderVersion{Version: "1.2.0", Deprecated: true, DeprecationMessage: &reason}).DeprecationWarning(),
+			"1.2.0 is deprecated: CVE-2024-1234, upgrade to 1.2.1"},
+		{"provider without reason", (&ProviderVersion{Version: "1.2.0", Deprecated: true, DeprecationMessage: &blank}).DeprecationWarning(),
+			"1.2.0 is deprecated"},
+		{"module with replacement", (&ModuleVersion{Version: "0.9.0", Deprecated: true, DeprecationMessage: &reason, ReplacementSource: &replacement}).DeprecationWarning(),
+			"0.9.0 is deprecated: CVE-2024-1234, upgrade to 1.2.1 (replacement: acme/vpc/aws)"},
+	}
+	for _, tc := range cases {
+		if tc.got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, tc.got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Package models - feed.go defines the published versions listed in the Atom
+// feeds of new module and provider versions.
+package models
+
+import "time"
+
+// Feed entry kinds.
+const (
+	FeedKindModule   = "module"
+	FeedKindProvider = "provider"
+)
+
+// FeedVersion is one published module or provider version in an Atom feed.
+type FeedVersion struct {
+	Kind      string // FeedKindModule or FeedKindProvider
+	Namespace string
+	// Name is the module name, or the provider type.
+	Name string
+	// System is the module's target system; empty for providers.
+	System      string
+	Version     string
+	PublishedAt time.Time
+	// Changelog is the module version's stored release-notes excerpt; nil
+	// when none was recorded and always nil for providers.
+	Changelog *string
+}
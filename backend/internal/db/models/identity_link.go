@@ -0,0 +1,21 @@
+// Package models — identity_link.go defines a pending request to link an SSO
+// identity to an existing account.
+package models
+
+import "time"
+
+// IdentityLinkRequest is an SSO identity waiting to be linked to the existing
+// account whose email it matched. It is linked when the account's user
+// confirms it with the link token, or when an admin approves it.
+type IdentityLinkRequest struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	OIDCSub      string    `json:"oidc_sub"`
+	AuthProvider string    `json:"auth_provider"` // oidc | azuread | saml | ldap
+	AuthIssuer   string    `json:"auth_issuer,omitempty"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	TokenHash    string    `json:"-"` // hex SHA-256 of the link token
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
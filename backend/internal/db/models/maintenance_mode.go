@@ -0,0 +1,28 @@
+// Package models — maintenance_mode.go defines the registry-wide read-only
+// maintenance mode state stored in system_settings.
+package models
+
+import "time"
+
+// MaintenanceMode is the registry's read-only maintenance state. While Enabled
+// is true mutating API requests are refused with 503 and Message, and reads
+// (including the Terraform protocol endpoints) keep being served.
+type MaintenanceMode struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"`
+	// ETA is when the operator expects writes to be accepted again.
+	ETA       *time.Time `json:"eta,omitempty"`
+	EnabledBy *string    `json:"enabled_by,omitempty"`
+	EnabledAt *time.Time `json:"enabled_at,omitempty"`
+}
+
+// RetryAfter returns how long a refused client should wait before retrying:
+// the time left until ETA, or fallback when there is no ETA in the future.
+func (m *MaintenanceMode) RetryAfter(now time.Time, fallback time.Duration) time.Duration {
+	if m.ETA != nil {
+		if d := m.ETA.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,57 @@
+// Package models - metadata_export.go defines the records of the version
+// metadata export external portals index the registry with.
+package models
+
+import "time"
+
+// MetadataExportSchemaVersion is the schema_version of every export record.
+// It changes only when a field is removed or changes meaning.
+const MetadataExportSchemaVersion = 1
+
+// Metadata export record kinds.
+const (
+	MetadataKindModuleVersion    = "module_version"
+	MetadataKindProviderVersion  = "provider_version"
+	MetadataKindTerraformVersion = "terraform_version"
+)
+
+// MetadataExportPlatform is one platform package of a provider or Terraform
+// binary version.
+type MetadataExportPlatform struct {
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Filename string `json:"filename"`
+	SHA256   string `json:"sha256"`
+}
+
+// MetadataExportRecord is one module, provider or Terraform binary version in
+// the metadata export.
+type MetadataExportRecord struct {
+	SchemaVersion int    `json:"schema_version"`
+	Kind          string `json:"kind"`
+	ID            string `json:"id"`
+	// ParentID is the module, provider or binary mirror the version belongs to.
+	ParentID string `json:"parent_id"`
+	// Namespace is the module or provider namespace, or the binary mirror's name.
+	Namespace string `json:"namespace"`
+	// Name is the module name, the provider type, or the binary mirror's tool.
+	Name string `json:"name"`
+	// System is the module's target system; empty for other kinds.
+	System  string `json:"system,omitempty"`
+	Version string `json:"version"`
+	// Description is the module's, provider's or binary mirror's current one.
+	Description *string `json:"description,omitempty"`
+	// PublishedAt is when the version was published or mirrored; for binaries,
+	// the upstream release date when known.
+	PublishedAt     time.Time `json:"published_at"`
+	PublishedBy     *string   `json:"published_by,omitempty"`
+	PublishedByName *string   `json:"published_by_name,omitempty"`
+	// Checksum is the SHA-256 (hex) of a module version's archive.
+	Checksum string `json:"checksum,omitempty"`
+	// Platforms lists a provider or binary version's packages.
+	Platforms          []MetadataExportPlatform `json:"platforms,omitempty"`
+	Deprecated         bool                     `json:"deprecated"`
+	DeprecatedAt       *time.Time               `json:"deprecated_at,omitempty"`
+	DeprecationMessage *string                  `json:"deprecation_message,omitempty"`
+	UpdatedAt          time.Time                `json:"updated_at"`
+}
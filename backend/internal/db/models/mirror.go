@@ -3,6 +3,8 @@
 package models
 
 import (
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +17,7 @@ type MirrorConfiguration struct {
 	Description              *string    `json:"description,omitempty" db:"description"`
 	UpstreamRegistryURL      string     `json:"upstream_registry_url" db:"upstream_registry_url"`
 	OrganizationID           *uuid.UUID `json:"organization_id,omitempty" db:"organization_id"`   // Organization for mirrored providers
+	Private                  bool       `json:"private" db:"private"`                             // Serve mirrored providers only to members of OrganizationID
 	NamespaceFilter          *string    `json:"namespace_filter,omitempty" db:"namespace_filter"` // JSON array
 	ProviderFilter           *string    `json:"provider_filter,omitempty" db:"provider_filter"`   // JSON array
 	VersionFilter            *string    `json:"version_filter,omitempty" db:"version_filter"`     // Version filter: "3.", "latest:5", ">=3.0.0", or comma-separated
@@ -25,8 +28,14 @@ type MirrorConfiguration struct {
 	AutoApproveRules         *string    `json:"auto_approve_rules,omitempty" db:"auto_approve_rules"` // JSONB: AutoApproveRules; NULL = manual approval only
 	PullThroughEnabled       bool       `json:"pull_through_enabled" db:"pull_through_enabled"`
 	PullThroughCacheTTLHours int        `json:"pull_through_cache_ttl_hours" db:"pull_through_cache_ttl_hours"`
+	DocsPassthroughEnabled   bool       `json:"docs_passthrough_enabled" db:"docs_passthrough_enabled"` // Serve upstream provider docs through /v1/providers/.../docs
+	DocsCacheTTLHours        int        `json:"docs_cache_ttl_hours" db:"docs_cache_ttl_hours"`         // Refetch a cached doc page after this long
+	HistoryRetentionCount    int        `json:"history_retention_count" db:"history_retention_count"`   // Keep the last N sync runs; 0 = no count limit
+	HistoryRetentionDays     int        `json:"history_retention_days" db:"history_retention_days"`     // Prune sync runs older than N days; 0 = no age limit
+	MaxStorageBytes          *int64     `json:"max_storage_bytes,omitempty" db:"max_storage_bytes"`     // Stop syncing new versions once stored archives reach this size; nil = no limit
+	StorageAlertLevel        int        `json:"-" db:"storage_alert_level"`                             // Highest storage usage threshold (percent) already notified
 	LastSyncAt               *time.Time `json:"last_sync_at,omitempty" db:"last_sync_at"`
-	LastSyncStatus           *string    `json:"last_sync_status,omitempty" db:"last_sync_status"` // success, failed, in_progress
+	LastSyncStatus           *string    `json:"last_sync_status,omitempty" db:"last_sync_status"` // success, failed, in_progress, limit_reached
 	LastSyncError            *string    `json:"last_sync_error,omitempty" db:"last_sync_error"`
 	CreatedAt                time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt                time.Time  `json:"updated_at" db:"updated_at"`
@@ -44,6 +53,64 @@ type MirroredProvider struct {
 	LastSyncVersion   *string   `json:"last_sync_version,omitempty" db:"last_sync_version"`
 	SyncEnabled       bool      `json:"sync_enabled" db:"sync_enabled"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	// UpstreamETag and UpstreamLastModified are the cache validators of the
+	// upstream version listing as of the last complete sync; nil when the
+	// upstream sent none or the last sync was incomplete.
+	UpstreamETag         *string `json:"-" db:"upstream_etag"`
+	UpstreamLastModified *string `json:"-" db:"upstream_last_modified"`
+}
+
+// ProviderCopySource links a stored copy of a provider to a mirror it was
+// synced from, for org-scoped mirror visibility. A copy no mirror synced has
+// one row with a nil MirrorConfigID.
+type ProviderCopySource struct {
+	ProviderID     string  `db:"provider_id"`
+	MirrorConfigID *string `db:"mirror_config_id"`
+	// OrganizationID is the owning organization of the mirror.
+	OrganizationID *string `db:"organization_id"`
+	Private        bool    `db:"private"`
+}
+
+// ProviderFilterExclusionPrefix marks a provider_filter entry that excludes
+// one namespace/type pair instead of naming a provider type to sync.
+const ProviderFilterExclusionPrefix = "!"
+
+// ProviderFilterExclusion returns the provider_filter entry that stops a
+// mirror syncing namespace/providerType while the rest of its filters still
+// cover it.
+func ProviderFilterExclusion(namespace, providerType string) string {
+	return ProviderFilterExclusionPrefix + namespace + "/" + providerType
+}
+
+// SplitProviderFilter separates decoded provider_filter entries into the
+// provider types to sync and the excluded "namespace/type" pairs.
+func SplitProviderFilter(entries []string) (providerTypes []string, excluded map[string]bool) {
+	excluded = make(map[string]bool)
+	for _, e := range entries {
+		if pair, ok := strings.CutPrefix(e, ProviderFilterExclusionPrefix); ok {
+			excluded[pair] = true
+			continue
+		}
+		providerTypes = append(providerTypes, e)
+	}
+	return providerTypes, excluded
+}
+
+// ProviderMirrorRef names a mirror configuration that tracks a provider in
+// mirrored_providers.
+type ProviderMirrorRef struct {
+	MirrorConfigID uuid.UUID `json:"id" db:"mirror_config_id"`
+	Name           string    `json:"name" db:"name"`
+}
+
+// MirrorProviderRef is a provider tracked by a mirror configuration.
+// Exclusive is true when every version of the provider was synced by that
+// mirror, i.e. the mirror created it and nothing else published to it.
+type MirrorProviderRef struct {
+	ProviderID uuid.UUID `json:"provider_id" db:"provider_id"`
+	Namespace  string    `json:"namespace" db:"namespace"`
+	Type       string    `json:"type" db:"type"`
+	Exclusive  bool      `json:"exclusive" db:"exclusive"`
 }
 
 // MirroredProviderVersion tracks individual version sync status
@@ -77,6 +144,7 @@ type CreateMirrorConfigRequest struct {
 	Description              *string  `json:"description,omitempty"`
 	UpstreamRegistryURL      string   `json:"upstream_registry_url" binding:"required,url"`
 	OrganizationID           *string  `json:"organization_id,omitempty"`                                        // Organization for mirrored providers
+	Private                  *bool    `json:"private,omitempty"`                                                // Default: false; requires an organization
 	NamespaceFilter          []string `json:"namespace_filter,omitempty"`                                       // List of namespaces to mirror
 	ProviderFilter           []string `json:"provider_filter,omitempty"`                                        // List of provider names to mirror
 	VersionFilter            *string  `json:"version_filter,omitempty"`                                         // Version filter: "3.", "latest:5", ">=3.0.0", or comma-separated
@@ -87,6 +155,11 @@ type CreateMirrorConfigRequest struct {
 	AutoApproveRules         *string  `json:"auto_approve_rules,omitempty"`                                     // JSON: AutoApproveRules
 	PullThroughEnabled       *bool    `json:"pull_through_enabled,omitempty"`                                   // Default: false
 	PullThroughCacheTTLHours *int     `json:"pull_through_cache_ttl_hours,omitempty" binding:"omitempty,min=1"` // Default: 24
+	DocsPassthroughEnabled   *bool    `json:"docs_passthrough_enabled,omitempty"`                               // Default: false
+	DocsCacheTTLHours        *int     `json:"docs_cache_ttl_hours,omitempty" binding:"omitempty,min=1"`         // Default: 24
+	HistoryRetentionCount    *int     `json:"history_retention_count,omitempty" binding:"omitempty,min=0"`      // Default: 500; 0 = no count limit
+	HistoryRetentionDays     *int     `json:"history_retention_days,omitempty" binding:"omitempty,min=0"`       // Default: 90; 0 = no age limit
+	MaxStorageBytes          *int64   `json:"max_storage_bytes,omitempty" binding:"omitempty,min=0"`            // Default: no limit; 0 = no limit
 }
 
 // UpdateMirrorConfigRequest represents the request to update a mirror configuration
@@ -95,6 +168,7 @@ type UpdateMirrorConfigRequest struct {
 	Description              *string  `json:"description,omitempty"`
 	UpstreamRegistryURL      *string  `json:"upstream_registry_url,omitempty" binding:"omitempty,url"`
 	OrganizationID           *string  `json:"organization_id,omitempty"` // Organization for mirrored providers
+	Private                  *bool    `json:"private,omitempty"`         // Serve mirrored providers only to members of the organization
 	NamespaceFilter          []string `json:"namespace_filter,omitempty"`
 	ProviderFilter           []string `json:"provider_filter,omitempty"`
 	VersionFilter            *string  `json:"version_filter,omitempty"`  // Version filter: "3.", "latest:5", ">=3.0.0", or comma-separated
@@ -105,6 +179,11 @@ type UpdateMirrorConfigRequest struct {
 	AutoApproveRules         *string  `json:"auto_approve_rules,omitempty"` // JSON: AutoApproveRules
 	PullThroughEnabled       *bool    `json:"pull_through_enabled,omitempty"`
 	PullThroughCacheTTLHours *int     `json:"pull_through_cache_ttl_hours,omitempty" binding:"omitempty,min=1"`
+	DocsPassthroughEnabled   *bool    `json:"docs_passthrough_enabled,omitempty"`
+	DocsCacheTTLHours        *int     `json:"docs_cache_ttl_hours,omitempty" binding:"omitempty,min=1"`
+	HistoryRetentionCount    *int     `json:"history_retention_count,omitempty" binding:"omitempty,min=0"`
+	HistoryRetentionDays     *int     `json:"history_retention_days,omitempty" binding:"omitempty,min=0"`
+	MaxStorageBytes          *int64   `json:"max_storage_bytes,omitempty" binding:"omitempty,min=0"` // 0 removes the limit
 }
 
 // TriggerSyncRequest represents the request to trigger a manual sync
@@ -118,5 +197,83 @@ type MirrorSyncStatus struct {
 	MirrorConfig  MirrorConfiguration `json:"mirror_config"`
 	CurrentSync   *MirrorSyncHistory  `json:"current_sync,omitempty"`
 	RecentSyncs   []MirrorSyncHistory `json:"recent_syncs"`
+	SyncStats     *SyncHistoryStats   `json:"sync_stats,omitempty"` // aggregate over the retained history
 	NextScheduled *time.Time          `json:"next_scheduled,omitempty"`
+	// Platforms lists every os/arch the mirror holds archives for with its
+	// recent download count, so unused platforms can be dropped from the
+	// platform filter. Omitted when the usage lookup fails.
+	Platforms []MirrorPlatformUsage `json:"platforms,omitempty"`
+	// SyncLease is the lease held by the replica syncing the mirror, if any.
+	// A stale lease was left by a sync that stopped heartbeating.
+	SyncLease *MirrorSyncLease `json:"sync_lease,omitempty"`
+	// StorageUsage is the storage the mirror's archives take up against its
+	// max_storage_bytes. Omitted when the usage lookup fails.
+	StorageUsage *MirrorStorageUsage `json:"storage_usage,omitempty"`
+}
+
+// MirrorSyncStatusLimitReached is the last_sync_status of a provider or
+// Terraform binary mirror whose sync skipped new versions because the
+// mirror's stored archives reached its max_storage_bytes.
+const MirrorSyncStatusLimitReached = "limit_reached"
+
+// MirrorStorageUsage is the storage a mirror's archives take up. LimitBytes
+// and PercentUsed are omitted when the mirror has no storage limit.
+type MirrorStorageUsage struct {
+	UsedBytes    int64    `json:"used_bytes"`
+	LimitBytes   *int64   `json:"limit_bytes,omitempty"`
+	PercentUsed  *float64 `json:"percent_used,omitempty"`
+	LimitReached bool     `json:"limit_reached"`
+}
+
+// NewMirrorStorageUsage reports used bytes against limit (nil = no limit).
+func NewMirrorStorageUsage(used int64, limit *int64) *MirrorStorageUsage {
+	u := &MirrorStorageUsage{UsedBytes: used}
+	if limit != nil && *limit > 0 {
+		l := *limit
+		pct := math.Round(float64(used)/float64(l)*1000) / 10
+		u.LimitBytes = &l
+		u.PercentUsed = &pct
+		u.LimitReached = used >= l
+	}
+	return u
+}
+
+// MirrorSyncLeaseTTL is how long a sync lease stays valid without a
+// heartbeat. The running sync heartbeats every MirrorSyncLeaseHeartbeat.
+const (
+	MirrorSyncLeaseTTL       = 2 * time.Minute
+	MirrorSyncLeaseHeartbeat = 30 * time.Second
+)
+
+// MirrorSyncLease records which replica is syncing a mirror. Heartbeat age is
+// measured by the database clock, so replicas with skewed clocks agree on it.
+type MirrorSyncLease struct {
+	MirrorConfigID      uuid.UUID `json:"mirror_config_id"`
+	Holder              string    `json:"holder"`
+	AcquiredAt          time.Time `json:"acquired_at"`
+	HeartbeatAt         time.Time `json:"heartbeat_at"`
+	HeartbeatAgeSeconds int64     `json:"heartbeat_age_seconds"`
+	// Stale is set once the heartbeat is older than MirrorSyncLeaseTTL; the
+	// next sync attempt takes the lease over.
+	Stale bool `json:"stale"`
+}
+
+// MirrorPlatformUsage is the download count of one platform across a mirror's
+// providers over the last UnusedPlatformWindowDays days.
+type MirrorPlatformUsage struct {
+	OS        string `json:"os" db:"os"`
+	Arch      string `json:"arch" db:"arch"`
+	Downloads int64  `json:"downloads_90d" db:"downloads"`
+	Unused    bool   `json:"unused_90d"` // 0 downloads in the window
+}
+
+// UnusedPlatformWindowDays is the window MirrorPlatformUsage counts over.
+const UnusedPlatformWindowDays = 90
+
+// MirrorSyncHistoryListResponse is a page of sync history for a mirror configuration.
+type MirrorSyncHistoryListResponse struct {
+	History    []MirrorSyncHistory `json:"history"`
+	TotalCount int                 `json:"total_count"`
+	Limit      int                 `json:"limit"`
+	Offset     int                 `json:"offset"`
 }
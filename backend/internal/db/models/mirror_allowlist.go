@@ -0,0 +1,51 @@
+// Package models — mirror_allowlist.go defines the entries that restrict which
+// providers the network mirror routes (/terraform/providers/...) will serve.
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// MirrorAllowlistEntry permits one hostname/namespace/type pattern on the
+// network mirror routes. Each pattern is a path.Match glob; "*" matches any
+// value. The hostname is the origin registry segment of the mirror path and is
+// compared case-insensitively.
+type MirrorAllowlistEntry struct {
+	ID               string    `json:"id"`
+	HostnamePattern  string    `json:"hostname_pattern"`
+	NamespacePattern string    `json:"namespace_pattern"`
+	TypePattern      string    `json:"type_pattern"`
+	Description      *string   `json:"description,omitempty"`
+	CreatedBy        *string   `json:"created_by,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Matches reports whether the entry permits the given mirror address.
+func (e *MirrorAllowlistEntry) Matches(hostname, namespace, providerType string) bool {
+	return globMatch(strings.ToLower(e.HostnamePattern), strings.ToLower(hostname)) &&
+		globMatch(e.NamespacePattern, namespace) &&
+		globMatch(e.TypePattern, providerType)
+}
+
+// MirrorAllowlistPermits reports whether a mirror address may be served under
+// entries. An empty allowlist permits everything.
+func MirrorAllowlistPermits(entries []*MirrorAllowlistEntry, hostname, namespace, providerType string) bool {
+	if len(entries) == 0 {
+		return true
+	}
+	for _, e := range entries {
+		if e.Matches(hostname, namespace, providerType) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch is path.Match with malformed patterns treated as non-matching.
+func globMatch(pattern, value string) bool {
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
@@ -0,0 +1,34 @@
+package models
+
+import "testing"
+
+func TestMirrorAllowlistPermits(t *testing.T) {
+	entries := []*MirrorAllowlistEntry{
+		{HostnamePattern: "registry.terraform.io", NamespacePattern: "hashicorp", TypePattern: "*"},
+		{HostnamePattern: "*", NamespacePattern: "acme", TypePattern: "azure*"},
+	}
+
+	tests := []struct {
+		name                      string
+		entries                   []*MirrorAllowlistEntry
+		hostname, namespace, kind string
+		want                      bool
+	}{
+		{"empty allowlist permits all", nil, "registry.example.com", "internal", "secret", true},
+		{"exact hostname and namespace", entries, "registry.terraform.io", "hashicorp", "aws", true},
+		{"hostname is case-insensitive", entries, "Registry.Terraform.IO", "hashicorp", "aws", true},
+		{"other hostname", entries, "registry.example.com", "hashicorp", "aws", false},
+		{"type glob", entries, "registry.example.com", "acme", "azurerm", true},
+		{"type glob miss", entries, "registry.example.com", "acme", "aws", false},
+		{"namespace is case-sensitive", entries, "registry.terraform.io", "HashiCorp", "aws", false},
+		{"malformed pattern never matches", []*MirrorAllowlistEntry{{HostnamePattern: "[", NamespacePattern: "*", TypePattern: "*"}},
+			"registry.terraform.io", "hashicorp", "aws", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MirrorAllowlistPermits(tt.entries, tt.hostname, tt.namespace, tt.kind); got != tt.want {
+				t.Errorf("MirrorAllowlistPermits(%s/%s/%s) = %v, want %v", tt.hostname, tt.namespace, tt.kind, got, tt.want)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ const (
 	ApprovalStatusPending  ApprovalStatus = "pending"
 	ApprovalStatusApproved ApprovalStatus = "approved"
 	ApprovalStatusRejected ApprovalStatus = "rejected"
+	ApprovalStatusRevoked  ApprovalStatus = "revoked"
 )
 
 // MirrorApprovalRequest represents a request to mirror a specific provider or namespace
@@ -40,6 +41,17 @@ type MirrorApprovalRequest struct {
 	// Auto-approval
 	AutoApproved bool `db:"auto_approved" json:"auto_approved"`
 
+	// Auto-provisioning: when AutoApply is set, approving the request adds
+	// ProviderNamespace and ProviderName to the mirror's filters and starts a
+	// sync. AppliedNamespace / AppliedProvider are the filter entries the
+	// approval added (nil when already present); revoking removes only those.
+	AutoApply        bool       `db:"auto_apply" json:"auto_apply"`
+	AppliedNamespace *string    `db:"applied_namespace" json:"applied_namespace,omitempty"`
+	AppliedProvider  *string    `db:"applied_provider" json:"applied_provider,omitempty"`
+	AppliedAt        *time.Time `db:"applied_at" json:"applied_at,omitempty"`
+	RevokedBy        *uuid.UUID `db:"revoked_by" json:"revoked_by,omitempty"`
+	RevokedAt        *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+
 	CreatedAt time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
 	ExpiresAt *time.Time `db:"expires_at" json:"expires_at,omitempty"`
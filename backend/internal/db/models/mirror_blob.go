@@ -0,0 +1,24 @@
+// Package models - mirror_blob.go defines content-addressed blobs backing
+// mirrored provider platform archives.
+package models
+
+import "time"
+
+// MirrorBlob is one stored object, keyed by the SHA-256 of its content, that
+// backs the platform archives of every mirror that synced the same content.
+type MirrorBlob struct {
+	Digest      string    `json:"digest"`
+	StoragePath string    `json:"storage_path"`
+	SizeBytes   int64     `json:"size_bytes"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MirrorBlobCandidate is a mirrored platform archive stored under its own key
+// rather than a blob, which mirror-dedup can move onto one.
+type MirrorBlobCandidate struct {
+	PlatformID  string `json:"platform_id"`
+	StoragePath string `json:"storage_path"`
+	SizeBytes   int64  `json:"size_bytes"`
+	// Shasum is the SHA-256 recorded when the archive was synced.
+	Shasum string `json:"shasum"`
+}
@@ -0,0 +1,68 @@
+// Package models — mirror_hostname_alias.go defines the hostname aliases that
+// let the network mirror routes serve a mirror's providers under a second
+// origin hostname (e.g. registry.opentofu.org for a registry.terraform.io
+// mirror).
+package models
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MirrorHostnameAlias maps AliasHostname to the upstream hostname of one
+// mirror configuration, for one namespace or (Namespace nil) all of them.
+type MirrorHostnameAlias struct {
+	ID             string  `json:"id"`
+	MirrorConfigID string  `json:"mirror_config_id"`
+	AliasHostname  string  `json:"alias_hostname"`
+	Namespace      *string `json:"namespace,omitempty"`
+	// TargetHostname is the hostname of the mirror's upstream_registry_url
+	// (joined, not stored).
+	TargetHostname string    `json:"target_hostname"`
+	CreatedBy      *string   `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MirrorHostnameAliasFor returns the alias that applies to a mirror request
+// for hostname/namespace, or nil. An alias for the namespace wins over one
+// for every namespace.
+func MirrorHostnameAliasFor(aliases []*MirrorHostnameAlias, hostname, namespace string) *MirrorHostnameAlias {
+	hostname = strings.ToLower(hostname)
+	var global *MirrorHostnameAlias
+	for _, a := range aliases {
+		if a.AliasHostname != hostname {
+			continue
+		}
+		if a.Namespace == nil {
+			global = a
+		} else if *a.Namespace == namespace {
+			return a
+		}
+	}
+	return global
+}
+
+// UpstreamHostname returns the lowercase hostname of a mirror's
+// upstream_registry_url, which may be given with or without a scheme.
+func UpstreamHostname(upstreamRegistryURL string) string {
+	raw := strings.TrimSpace(upstreamRegistryURL)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// MirroredProviderSource is the mirror a stored provider was synced from, as
+// far as hostname aliasing is concerned.
+type MirroredProviderSource struct {
+	MirroredProviderID string
+	MirrorConfigID     string
+	// UpstreamHostname is the hostname of the mirror's upstream_registry_url.
+	UpstreamHostname  string
+	ServeUnderAliases bool
+}
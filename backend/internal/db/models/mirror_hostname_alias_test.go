@@ -0,0 +1,41 @@
+package models
+
+import "testing"
+
+func TestMirrorHostnameAliasFor(t *testing.T) {
+	ns := "hashicorp"
+	global := &MirrorHostnameAlias{ID: "global", AliasHostname: "registry.opentofu.org"}
+	scoped := &MirrorHostnameAlias{ID: "scoped", AliasHostname: "registry.opentofu.org", Namespace: &ns}
+	aliases := []*MirrorHostnameAlias{global, scoped}
+
+	tests := []struct {
+		name                string
+		hostname, namespace string
+		want                *MirrorHostnameAlias
+	}{
+		{"namespace alias wins", "registry.opentofu.org", "hashicorp", scoped},
+		{"global alias for other namespaces", "registry.opentofu.org", "acme", global},
+		{"hostname is case-insensitive", "Registry.OpenTofu.ORG", "hashicorp", scoped},
+		{"other hostname", "registry.terraform.io", "hashicorp", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MirrorHostnameAliasFor(aliases, tt.hostname, tt.namespace); got != tt.want {
+				t.Errorf("MirrorHostnameAliasFor(%s, %s) = %+v, want %+v", tt.hostname, tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpstreamHostname(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://registry.terraform.io":      "registry.terraform.io",
+		"https://Registry.Example.com:8443/": "registry.example.com",
+		"registry.opentofu.org":              "registry.opentofu.org",
+		"":                                   "",
+	} {
+		if got := UpstreamHostname(raw); got != want {
+			t.Errorf("UpstreamHostname(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -344,3 +344,17 @@ func TestStorageConfig_ToResponse_GCSFields(t *testing.T) {
 		t.Error("GCSCredentialsJSONSet should be true when credentials are set")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// SplitProviderFilter
+// ---------------------------------------------------------------------------
+
+func TestSplitProviderFilter(t *testing.T) {
+	types, excluded := SplitProviderFilter([]string{"aws", ProviderFilterExclusion("hashicorp", "google"), "google"})
+	if len(types) != 2 || types[0] != "aws" || types[1] != "google" {
+		t.Errorf("types = %v, want [aws google]", types)
+	}
+	if len(excluded) != 1 || !excluded["hashicorp/google"] {
+		t.Errorf("excluded = %v, want hashicorp/google", excluded)
+	}
+}
@@ -20,6 +20,18 @@ type Module struct {
 	DeprecatedAt       *time.Time `json:"deprecated_at,omitempty" db:"deprecated_at"`
 	DeprecationMessage *string    `json:"deprecation_message,omitempty" db:"deprecation_message"`
 	SuccessorModuleID  *string    `json:"successor_module_id,omitempty" db:"successor_module_id"`
+	// IncludePrerelease opts the module back into listing pre-release versions
+	// by default. Only populated by handlers that explicitly load it (see
+	// ModuleRepository.GetIncludePrerelease).
+	IncludePrerelease *bool `json:"include_prerelease,omitempty"`
+	// MaxVersions and VersionCapMode are the module's own version cap
+	// settings; nil falls back to the organization default. Only populated by
+	// handlers that explicitly load them (see ModuleRepository.GetVersionCapSettings).
+	MaxVersions    *int    `json:"max_versions,omitempty"`
+	VersionCapMode *string `json:"version_cap_mode,omitempty"`
+	// Tier is the registry-assigned tier (see tier.go). Only populated by
+	// handlers that explicitly load it (see ModuleRepository.ListTiers).
+	Tier string `json:"tier,omitempty"`
 	// Joined fields (not stored in modules table)
 	CreatedByName *string `json:"created_by_name,omitempty"` // User name who created this module (joined from users table)
 }
@@ -33,6 +45,13 @@ type ModuleSearchResult struct {
 	TotalDownloads int64   `json:"total_downloads"`
 }
 
+// ModuleSystemFacet is the number of modules matching a search that target
+// one system.
+type ModuleSystemFacet struct {
+	System string `json:"system"`
+	Count  int64  `json:"count"`
+}
+
 // ModuleVersion represents a specific version of a module
 type ModuleVersion struct {
 	ID                 string     `json:"id"`
@@ -54,7 +73,43 @@ type ModuleVersion struct {
 	CommitSHA *string `json:"commit_sha,omitempty"`  // Git commit SHA at time of publish
 	TagName   *string `json:"tag_name,omitempty"`    // Git tag name that triggered publish
 	SCMRepoID *string `json:"scm_repo_id,omitempty"` // FK to module_scm_repos.id
+	// RequiredTerraformVersion is the root module's terraform required_version
+	// constraint recorded at publish (e.g. ">= 1.5.0, < 2.0.0"); nil when the
+	// module declares none.
+	RequiredTerraformVersion *string `json:"required_terraform_version,omitempty"`
+	// Changelog is the sanitized release-notes excerpt for this version. Only
+	// populated by handlers that explicitly load it (see GetVersionChangelog).
+	Changelog *string `json:"changelog,omitempty"`
+	// ArchivedAt is set when the version was archived to keep the module under
+	// its version cap. Only populated by handlers that explicitly load it (see
+	// ModuleRepository.ListArchivedVersions).
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// AliasOf is the ID of the version whose byte-identical archive this
+	// version shares instead of storing its own. Written by CreateVersion;
+	// reads that need it use ModuleRepository.GetVersionAliasing.
+	AliasOf *string `json:"alias_of,omitempty"`
 	// Joined fields (not stored in module_versions table)
 	PublishedByName *string `json:"published_by_name,omitempty"` // User name who published this version (joined from users table)
 	HasDocs         bool    `json:"has_docs"`                    // Whether terraform-docs metadata exists (joined from module_version_docs)
 }
+
+// Version cap modes: what a publish does once a module holds its maximum
+// number of listed versions.
+const (
+	// VersionCapModeStrict rejects the publish.
+	VersionCapModeStrict = "strict"
+	// VersionCapModeRolling archives the oldest unapproved versions.
+	VersionCapModeRolling = "rolling"
+)
+
+// ModuleVersionCap is a module's effective version cap: its own settings,
+// falling back to its organization's defaults.
+type ModuleVersionCap struct {
+	// MaxVersions is the number of listed versions the module may keep; nil
+	// means the module is not capped.
+	MaxVersions *int
+	// Mode is VersionCapModeStrict or VersionCapModeRolling.
+	Mode string
+	// ActiveVersions is the number of versions not archived.
+	ActiveVersions int
+}
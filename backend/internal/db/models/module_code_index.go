@@ -0,0 +1,88 @@
+// Package models - module_code_index.go defines the module content index
+// behind code search: the indexing state of each module version and the
+// search results built from its code symbols.
+package models
+
+import "time"
+
+// Code index statuses of a module version.
+const (
+	CodeIndexStatusPending  = "pending"
+	CodeIndexStatusIndexing = "indexing"
+	CodeIndexStatusIndexed  = "indexed"
+	CodeIndexStatusError    = "error"
+)
+
+// ModuleCodeIndex is the indexing state of one module version.
+type ModuleCodeIndex struct {
+	ModuleVersionID string     `json:"module_version_id"`
+	Status          string     `json:"status"`
+	FilesIndexed    int        `json:"files_indexed"`
+	SymbolsIndexed  int        `json:"symbols_indexed"`
+	BytesIndexed    int64      `json:"bytes_indexed"`
+	Truncated       bool       `json:"truncated"`
+	ErrorMessage    *string    `json:"error_message,omitempty"`
+	IndexedAt       *time.Time `json:"indexed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// ModuleCodeIndexCounts counts module versions by code index status.
+// Unqueued is the number of versions the job has not picked up yet.
+type ModuleCodeIndexCounts struct {
+	Pending  int `json:"pending"`
+	Indexing int `json:"indexing"`
+	Indexed  int `json:"indexed"`
+	Error    int `json:"error"`
+	Unqueued int `json:"unqueued"`
+}
+
+// CodeSearchFilter selects the code symbols a code search matches. Empty
+// fields do not filter.
+type CodeSearchFilter struct {
+	// Kind and Symbol match a symbol exactly, e.g. resource aws_eks_cluster.
+	// Symbol of a module call also matches its source.
+	Kind   string
+	Symbol string
+	// Query is matched against the words of the symbol's source text.
+	Query string
+	// Namespace and System narrow the modules searched.
+	Namespace string
+	System    string
+}
+
+// ModuleCodeSearchResult is one module version with code matching a search.
+type ModuleCodeSearchResult struct {
+	ModuleVersionID string `json:"module_version_id"`
+	ModuleID        string `json:"module_id"`
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	System          string `json:"system"`
+	Version         string `json:"version"`
+	// MatchCount is the number of matching blocks in the version; Matches
+	// holds the first of them, by file and line.
+	MatchCount int               `json:"match_count"`
+	Matches    []ModuleCodeMatch `json:"matches"`
+}
+
+// ModuleCodeMatch is one matching block of a module version.
+type ModuleCodeMatch struct {
+	Kind      string  `json:"kind"`
+	Symbol    string  `json:"symbol"`
+	Label     *string `json:"label,omitempty"`
+	Source    *string `json:"source,omitempty"`
+	FilePath  string  `json:"file_path"`
+	StartLine int     `json:"start_line"`
+	EndLine   int     `json:"end_line"`
+	// Lines are the snippet shown for the match: the block's first line and
+	// the lines containing a word of the query.
+	Lines []CodeLine `json:"lines"`
+	// Body is the block's source text the snippet is cut from.
+	Body string `json:"-"`
+}
+
+// CodeLine is one numbered line of a snippet.
+type CodeLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
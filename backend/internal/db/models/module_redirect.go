@@ -0,0 +1,44 @@
+// Package models — module_redirect.go defines the redirects left behind when a
+// module moves to another namespace or system, so that source addresses
+// pinned to the old location keep resolving.
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ModuleRedirect maps an old namespace/name/system address to the module that
+// now lives elsewhere. Namespace, Name and System are the old address;
+// the module's current address is joined in Target*.
+type ModuleRedirect struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Namespace      string    `json:"namespace"`
+	Name           string    `json:"name"`
+	System         string    `json:"system"`
+	ModuleID       string    `json:"module_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	// Current address of the module (joined from modules, not stored).
+	TargetNamespace string `json:"target_namespace"`
+	TargetName      string `json:"target_name"`
+	TargetSystem    string `json:"target_system"`
+}
+
+// MovedPath rewrites a request path for the old address to the module's
+// current address. The first occurrence of the /namespace/name/system
+// segments is replaced; the rest of the path (version, download suffix) is
+// kept. It returns "" when path does not contain the old address.
+func (r *ModuleRedirect) MovedPath(path string) string {
+	from := "/" + r.Namespace + "/" + r.Name + "/" + r.System
+	to := "/" + r.TargetNamespace + "/" + r.TargetName + "/" + r.TargetSystem
+	i := strings.Index(path, from)
+	if i < 0 {
+		return ""
+	}
+	rest := path[i+len(from):]
+	if rest != "" && rest[0] != '/' {
+		return ""
+	}
+	return path[:i] + to + rest
+}
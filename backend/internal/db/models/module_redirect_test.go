@@ -0,0 +1,24 @@
+package models
+
+import "testing"
+
+func TestModuleRedirect_MovedPath(t *testing.T) {
+	r := &ModuleRedirect{
+		Namespace: "acme", Name: "policies", System: "all",
+		TargetNamespace: "acme", TargetName: "policies", TargetSystem: "generic",
+	}
+	tests := []struct {
+		path, want string
+	}{
+		{"/v1/modules/acme/policies/all/versions", "/v1/modules/acme/policies/generic/versions"},
+		{"/v1/modules/acme/policies/all/1.2.0/download", "/v1/modules/acme/policies/generic/1.2.0/download"},
+		{"/api/v1/modules/acme/policies/all", "/api/v1/modules/acme/policies/generic"},
+		{"/v1/modules/acme/policies/allow/versions", ""},
+		{"/v1/modules/other/policies/all/versions", ""},
+	}
+	for _, tt := range tests {
+		if got := r.MovedPath(tt.path); got != tt.want {
+			t.Errorf("MovedPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
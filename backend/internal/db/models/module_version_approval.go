@@ -0,0 +1,42 @@
+// Package models — module_version_approval.go defines per-organization module
+// version approvals and the organization policy that enforces them.
+package models
+
+import "time"
+
+// ModuleVersionApproval records that an organization has approved a module
+// version for use by its members.
+type ModuleVersionApproval struct {
+	ID              string    `json:"id"`
+	ModuleVersionID string    `json:"module_version_id"`
+	OrganizationID  string    `json:"organization_id"`
+	ApprovedBy      *string   `json:"approved_by,omitempty"` // user ID; nil for non-user credentials
+	ApprovedAt      time.Time `json:"approved_at"`
+	Notes           *string   `json:"notes,omitempty"`
+
+	// Populated by list queries — not stored in module_version_approvals.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	System    string `json:"system,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
+
+// OrgModulePolicy controls how an organization's members consume modules, how
+// many versions its modules keep listed, and how duplicate publishes are stored.
+type OrgModulePolicy struct {
+	OrganizationID string `json:"organization_id"`
+	// ApprovedOnly restricts authenticated members to module versions the
+	// organization has approved, in version listings and downloads.
+	ApprovedOnly bool `json:"approved_only"`
+	// DefaultMaxVersions and DefaultVersionCapMode cap the listed versions of
+	// every module in the organization that has no cap of its own. nil means
+	// no cap, and strict mode, respectively.
+	DefaultMaxVersions    *int    `json:"default_max_versions,omitempty"`
+	DefaultVersionCapMode *string `json:"default_version_cap_mode,omitempty"`
+	// AliasDuplicateVersions makes a publish whose archive is byte-identical
+	// to an existing version of the module an alias of that version instead
+	// of a second stored copy.
+	AliasDuplicateVersions bool      `json:"alias_duplicate_versions"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
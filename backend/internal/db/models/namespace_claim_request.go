@@ -0,0 +1,67 @@
+// Package models - namespace_claim_request.go defines self-service namespace
+// claim requests and the verified organization domains that can approve them
+// automatically.
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// NamespaceClaimStatus is the state of a namespace claim request.
+type NamespaceClaimStatus string
+
+const (
+	NamespaceClaimPending  NamespaceClaimStatus = "pending"
+	NamespaceClaimApproved NamespaceClaimStatus = "approved"
+	NamespaceClaimRejected NamespaceClaimStatus = "rejected"
+)
+
+// NamespaceClaimRequest is an organization's request to own a namespace. An
+// approved request has written the namespace's NamespaceClaim.
+type NamespaceClaimRequest struct {
+	ID             string               `json:"id"`
+	Namespace      string               `json:"namespace"`
+	OrganizationID string               `json:"organization_id"`
+	RequestedBy    *string              `json:"requested_by,omitempty"`
+	Reason         string               `json:"reason"`
+	Status         NamespaceClaimStatus `json:"status"`
+	// AutoDecided is set when the request was approved or rejected by the
+	// registry's rules rather than by an admin.
+	AutoDecided   bool       `json:"auto_decided"`
+	DecidedBy     *string    `json:"decided_by,omitempty"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+	DecisionNotes *string    `json:"decision_notes,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// OrgVerifiedDomain is a domain an admin has verified an organization
+// controls.
+type OrgVerifiedDomain struct {
+	OrganizationID string    `json:"organization_id"`
+	Domain         string    `json:"domain"`
+	VerifiedBy     *string   `json:"verified_by,omitempty"`
+	VerifiedAt     time.Time `json:"verified_at"`
+}
+
+// NamespacePrefix returns the domain's first label, lowercased: "acme" for
+// acme.com.
+func (d *OrgVerifiedDomain) NamespacePrefix() string {
+	label, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(d.Domain)), ".")
+	return label
+}
+
+// MatchesNamespace reports whether namespace is the domain's prefix or starts
+// with the prefix followed by "-" or "_" (acme, acme-platform, acme_infra).
+func (d *OrgVerifiedDomain) MatchesNamespace(namespace string) bool {
+	prefix := d.NamespacePrefix()
+	if prefix == "" {
+		return false
+	}
+	namespace = strings.ToLower(namespace)
+	if namespace == prefix {
+		return true
+	}
+	rest, ok := strings.CutPrefix(namespace, prefix)
+	return ok && (rest[0] == '-' || rest[0] == '_')
+}
@@ -0,0 +1,25 @@
+// Package models - namespace_metadata.go defines the landing-page content an
+// owning organization publishes for a namespace.
+package models
+
+import "time"
+
+// NamespaceLink is one labelled link on a namespace landing page.
+type NamespaceLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// NamespaceMetadata is the landing-page content of a namespace. Description
+// is sanitized markdown; ShortDescription is the plain-text summary derived
+// from it and shown in search results.
+type NamespaceMetadata struct {
+	Namespace        string          `json:"namespace"`
+	Description      string          `json:"description"`
+	ShortDescription string          `json:"short_description"`
+	Links            []NamespaceLink `json:"links"`
+	AvatarURL        *string         `json:"avatar_url,omitempty"`
+	UpdatedBy        *string         `json:"updated_by,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
@@ -144,6 +144,17 @@ func OIDCConfigToResponse(c *OIDCConfig) *OIDCConfigResponse {
 	return resp
 }
 
+// SetupState is the setup wizard state the setup token middleware checks,
+// read under a row lock (see OIDCConfigRepository.LockSetupState).
+type SetupState struct {
+	Completed bool
+	// PendingFeature is set when setup is completed but a feature added in a
+	// later release has not been configured yet.
+	PendingFeature bool
+	// TokenHash is the bcrypt hash of the setup token; empty when none is set.
+	TokenHash string
+}
+
 // SetupStatus represents the enhanced setup status response
 type SetupStatus struct {
 	SetupCompleted      bool           `json:"setup_completed"`
@@ -0,0 +1,158 @@
+// Package models — org_api_key_policy.go defines the per-organization API key
+// creation policy and the logic that combines several policies into the
+// effective (strictest) one and checks a key against it.
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// API key policy rule identifiers, returned as `policy_rule` on 422 responses
+// and in compliance reports.
+const (
+	APIKeyPolicyRuleRequireExpiry  = "require_expiry"
+	APIKeyPolicyRuleMaxLifetime    = "max_lifetime_days"
+	APIKeyPolicyRuleAllowedScopes  = "allowed_scopes"
+	APIKeyPolicyRuleMaxKeysPerUser = "max_keys_per_user"
+)
+
+// OrgAPIKeyPolicy restricts the API keys that members of an organization may
+// hold.
+type OrgAPIKeyPolicy struct {
+	OrganizationID  string    `json:"organization_id"`
+	MaxLifetimeDays int       `json:"max_lifetime_days"` // 0 = unlimited
+	AllowedScopes   []string  `json:"allowed_scopes"`    // nil = any scope
+	RequireExpiry   bool      `json:"require_expiry"`
+	MaxKeysPerUser  int       `json:"max_keys_per_user"` // 0 = unlimited
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// EffectiveAPIKeyPolicy is the strictest combination of every policy that
+// applies to a key owner (one per organization they belong to).
+type EffectiveAPIKeyPolicy struct {
+	MaxLifetimeDays int
+	// AllowedScopes is only meaningful when ScopesRestricted is set; an empty
+	// list then means no scope is allowed at all (disjoint allowlists).
+	AllowedScopes    []string
+	ScopesRestricted bool
+	RequireExpiry    bool
+	MaxKeysPerUser   int
+	// OrganizationIDs lists the organizations whose policies were merged.
+	OrganizationIDs []string
+}
+
+// APIKeyPolicyViolation describes one failed policy rule.
+type APIKeyPolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// MergeAPIKeyPolicies combines policies so the strictest value of each rule
+// wins: the shortest non-zero lifetime and key cap, the intersection of the
+// scope allowlists, and require_expiry if any policy sets it. Returns nil when
+// there are no policies.
+func MergeAPIKeyPolicies(policies []*OrgAPIKeyPolicy) *EffectiveAPIKeyPolicy {
+	if len(policies) == 0 {
+		return nil
+	}
+	eff := &EffectiveAPIKeyPolicy{}
+	var allowed map[string]bool
+	for _, p := range policies {
+		if p == nil {
+			continue
+		}
+		eff.OrganizationIDs = append(eff.OrganizationIDs, p.OrganizationID)
+		eff.MaxLifetimeDays = minPositive(eff.MaxLifetimeDays, p.MaxLifetimeDays)
+		eff.MaxKeysPerUser = minPositive(eff.MaxKeysPerUser, p.MaxKeysPerUser)
+		eff.RequireExpiry = eff.RequireExpiry || p.RequireExpiry
+		if p.AllowedScopes == nil {
+			continue
+		}
+		next := make(map[string]bool, len(p.AllowedScopes))
+		for _, s := range p.AllowedScopes {
+			if allowed == nil || allowed[s] {
+				next[s] = true
+			}
+		}
+		allowed = next
+		eff.ScopesRestricted = true
+	}
+	if eff.ScopesRestricted {
+		eff.AllowedScopes = make([]string, 0, len(allowed))
+		for s := range allowed {
+			eff.AllowedScopes = append(eff.AllowedScopes, s)
+		}
+		sort.Strings(eff.AllowedScopes)
+	}
+	return eff
+}
+
+// Violations checks a key against the policy. createdAt is the key's creation
+// time (now for a key being created), expiresAt its expiry (nil = never), and
+// keyCount the number of keys the owner holds including this one. All failing
+// rules are returned, in a stable order.
+func (p *EffectiveAPIKeyPolicy) Violations(scopes []string, createdAt time.Time, expiresAt *time.Time, keyCount int) []APIKeyPolicyViolation {
+	if p == nil {
+		return nil
+	}
+	var out []APIKeyPolicyViolation
+
+	if expiresAt == nil && (p.RequireExpiry || p.MaxLifetimeDays > 0) {
+		out = append(out, APIKeyPolicyViolation{
+			Rule:    APIKeyPolicyRuleRequireExpiry,
+			Message: "organization policy requires API keys to have an expiration date",
+		})
+	}
+	if expiresAt != nil && p.MaxLifetimeDays > 0 {
+		maxLifetime := time.Duration(p.MaxLifetimeDays) * 24 * time.Hour
+		if expiresAt.Sub(createdAt) > maxLifetime {
+			out = append(out, APIKeyPolicyViolation{
+				Rule:    APIKeyPolicyRuleMaxLifetime,
+				Message: fmt.Sprintf("organization policy limits API key lifetime to %d days", p.MaxLifetimeDays),
+			})
+		}
+	}
+	if p.ScopesRestricted {
+		allowed := make(map[string]bool, len(p.AllowedScopes))
+		for _, s := range p.AllowedScopes {
+			allowed[s] = true
+		}
+		var denied []string
+		for _, s := range scopes {
+			if !allowed[s] {
+				denied = append(denied, s)
+			}
+		}
+		if len(denied) > 0 {
+			out = append(out, APIKeyPolicyViolation{
+				Rule:    APIKeyPolicyRuleAllowedScopes,
+				Message: "organization policy does not allow scope(s): " + strings.Join(denied, ", "),
+			})
+		}
+	}
+	if p.MaxKeysPerUser > 0 && keyCount > p.MaxKeysPerUser {
+		out = append(out, APIKeyPolicyViolation{
+			Rule:    APIKeyPolicyRuleMaxKeysPerUser,
+			Message: fmt.Sprintf("organization policy limits each user to %d API keys", p.MaxKeysPerUser),
+		})
+	}
+	return out
+}
+
+// minPositive returns the smaller of a and b, treating 0 as "unlimited".
+func minPositive(a, b int) int {
+	switch {
+	case a <= 0:
+		return b
+	case b <= 0:
+		return a
+	case b < a:
+		return b
+	default:
+		return a
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeAPIKeyPolicies(t *testing.T) {
+	if MergeAPIKeyPolicies(nil) != nil {
+		t.Fatal("MergeAPIKeyPolicies(nil) should be nil")
+	}
+
+	eff := MergeAPIKeyPolicies([]*OrgAPIKeyPolicy{
+		{OrganizationID: "a", MaxLifetimeDays: 90, AllowedScopes: []string{"modules:read", "modules:write", "providers:read"}},
+		{OrganizationID: "b", MaxLifetimeDays: 30, MaxKeysPerUser: 5},
+		{OrganizationID: "c", RequireExpiry: true, MaxKeysPerUser: 3, AllowedScopes: []string{"providers:read", "modules:read"}},
+	})
+	if eff.MaxLifetimeDays != 30 {
+		t.Errorf("MaxLifetimeDays = %d, want 30", eff.MaxLifetimeDays)
+	}
+	if eff.MaxKeysPerUser != 3 {
+		t.Errorf("MaxKeysPerUser = %d, want 3", eff.MaxKeysPerUser)
+	}
+	if !eff.RequireExpiry {
+		t.Error("RequireExpiry = false, want true")
+	}
+	if !eff.ScopesRestricted || !reflect.DeepEqual(eff.AllowedScopes, []string{"modules:read", "providers:read"}) {
+		t.Errorf("AllowedScopes = %v (restricted=%v)", eff.AllowedScopes, eff.ScopesRestricted)
+	}
+	if !reflect.DeepEqual(eff.OrganizationIDs, []string{"a", "b", "c"}) {
+		t.Errorf("OrganizationIDs = %v", eff.OrganizationIDs)
+	}
+
+	disjoint := MergeAPIKeyPolicies([]*OrgAPIKeyPolicy{
+		{AllowedScopes: []string{"modules:read"}},
+		{AllowedScopes: []string{"providers:read"}},
+	})
+	if !disjoint.ScopesRestricted || len(disjoint.AllowedScopes) != 0 {
+		t.Errorf("disjoint allowlists should allow nothing, got %v", disjoint.AllowedScopes)
+	}
+}
+
+func TestEffectiveAPIKeyPolicy_Violations(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	in := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+	day := 24 * time.Hour
+
+	p := &EffectiveAPIKeyPolicy{
+		MaxLifetimeDays:  90,
+		ScopesRestricted: true,
+		AllowedScopes:    []string{"modules:read"},
+		MaxKeysPerUser:   2,
+	}
+
+	tests := []struct {
+		name      string
+		scopes    []string
+		expiresAt *time.Time
+		keyCount  int
+		want      []string
+	}{
+		{"compliant", []string{"modules:read"}, in(90 * day), 2, nil},
+		{"no expiry with lifetime cap", []string{"modules:read"}, nil, 1, []string{APIKeyPolicyRuleRequireExpiry}},
+		{"lifetime too long", []string{"modules:read"}, in(91 * day), 1, []string{APIKeyPolicyRuleMaxLifetime}},
+		{"scope not allowed", []string{"modules:read", "admin"}, in(day), 1, []string{APIKeyPolicyRuleAllowedScopes}},
+		{"too many keys", []string{"modules:read"}, in(day), 3, []string{APIKeyPolicyRuleMaxKeysPerUser}},
+		{"all at once", []string{"admin"}, nil, 3, []string{APIKeyPolicyRuleRequireExpiry, APIKeyPolicyRuleAllowedScopes, APIKeyPolicyRuleMaxKeysPerUser}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range p.Violations(tt.scopes, now, tt.expiresAt, tt.keyCount) {
+				got = append(got, v.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Violations() rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if v := (*EffectiveAPIKeyPolicy)(nil).Violations([]string{"admin"}, now, nil, 100); v != nil {
+		t.Errorf("nil policy should have no violations, got %v", v)
+	}
+	if v := (&EffectiveAPIKeyPolicy{RequireExpiry: true}).Violations(nil, now, nil, 1); len(v) != 1 {
+		t.Errorf("require_expiry alone should fail a key without expiry, got %v", v)
+	}
+}
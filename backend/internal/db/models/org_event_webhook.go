@@ -0,0 +1,75 @@
+// Package models — org_event_webhook.go defines an organization's event
+// webhooks, the endpoints its security-relevant events are pushed to, and the
+// log of deliveries made to them.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Organization event delivery outcomes.
+const (
+	OrgEventOutcomeDelivered = "delivered" // the endpoint answered 2xx
+	OrgEventOutcomeFailed    = "failed"
+)
+
+// OrgEventWebhook is an endpoint subscribed to an organization's events. The
+// secret is held encrypted and never serialized; HasSecret reports that one
+// is set and SecretKeyID identifies it in signature headers.
+type OrgEventWebhook struct {
+	ID              string   `json:"id"`
+	OrganizationID  string   `json:"organization_id"`
+	URL             string   `json:"url"`
+	EncryptedSecret string   `json:"-"`
+	HasSecret       bool     `json:"has_secret"`
+	SecretKeyID     string   `json:"secret_key_id"`
+	EventTypes      []string `json:"event_types"` // empty subscribes to every event type
+	TimeoutSeconds  int      `json:"timeout_seconds"`
+	Enabled         bool     `json:"enabled"` // receives events; a disabled webhook can still be tested
+	// NextEncryptedSecret is the secret a rotation in progress will promote;
+	// empty when none is.
+	NextEncryptedSecret string                         `json:"-"`
+	SecretRotation      *OrgEventWebhookSecretRotation `json:"secret_rotation,omitempty"`
+	CreatedAt           time.Time                      `json:"created_at"`
+	UpdatedAt           time.Time                      `json:"updated_at"`
+}
+
+// OrgEventWebhookSecretRotation describes a secret rotation in progress.
+// Deliveries are signed with both secrets until OverlapUntil and with the
+// next secret only after it, until the rotation is completed.
+type OrgEventWebhookSecretRotation struct {
+	NextSecretKeyID string    `json:"next_secret_key_id"`
+	StartedAt       time.Time `json:"started_at"`
+	OverlapUntil    time.Time `json:"overlap_until"`
+}
+
+// Wants reports whether the webhook is subscribed to eventType.
+func (w *OrgEventWebhook) Wants(eventType string) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// OrgEventDelivery is one delivery of an event to a webhook.
+type OrgEventDelivery struct {
+	ID            string          `json:"id"`
+	WebhookID     string          `json:"webhook_id"`
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	SchemaVersion int             `json:"schema_version"`
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"` // the body sent
+	Outcome       string          `json:"outcome"`
+	StatusCode    *int            `json:"status_code,omitempty"` // of the last attempt; unset when no response arrived
+	Attempts      int             `json:"attempts"`
+	Error         *string         `json:"error,omitempty"` // why the delivery failed
+	DurationMs    int64           `json:"duration_ms"`
+	ReplayOf      *string         `json:"replay_of,omitempty"` // the delivery this one re-sent
+	CreatedAt     time.Time       `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+// Package models — org_scope_ceiling.go defines the per-organization ceiling
+// on the scopes of issued session tokens.
+package models
+
+import "time"
+
+// OrgScopeCeiling lists the scopes a session token may carry on behalf of an
+// organization. A user's token keeps only the scopes some ceiling of their
+// organizations allows; see auth.global_admins for the admin scope exemption.
+type OrgScopeCeiling struct {
+	OrganizationID string    `json:"organization_id"`
+	Scopes         []string  `json:"scopes"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
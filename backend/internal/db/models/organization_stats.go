@@ -0,0 +1,13 @@
+// Package models - organization_stats.go defines the per-organization usage
+// counts shown alongside organizations in the admin listing.
+package models
+
+// OrganizationStats aggregates what an organization owns. StorageBytes is the
+// sum of stored module archives and provider platform binaries.
+type OrganizationStats struct {
+	ModuleCount       int64 `json:"module_count"`
+	ProviderCount     int64 `json:"provider_count"`
+	MemberCount       int64 `json:"member_count"`
+	MirrorConfigCount int64 `json:"mirror_config_count"`
+	StorageBytes      int64 `json:"storage_bytes"`
+}
@@ -15,6 +15,10 @@ type Provider struct {
 	CreatedBy      *string   `json:"created_by,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	// Tier is the registry-assigned tier (see tier.go). CreateProvider stores
+	// it, defaulting to community when empty; it is otherwise only populated
+	// by handlers that explicitly load it (see ProviderRepository.ListTiers).
+	Tier string `json:"tier,omitempty"`
 	// Joined fields (not stored in providers table)
 	CreatedByName *string `json:"created_by_name,omitempty"`
 }
@@ -27,6 +31,25 @@ type ProviderSearchResult struct {
 	TotalDownloads int64   `json:"total_downloads"`
 }
 
+// ProviderUpstreamMetadata is the upstream-sourced metadata the mirror sync
+// stores on a mirrored provider's row: source repository, upstream tier, and the license
+// found in the provider archive. Every field is nil for uploaded providers and
+// for mirrored providers whose upstream did not supply it. LicenseText is only
+// loaded for single-provider lookups.
+type ProviderUpstreamMetadata struct {
+	SourceURL *string `json:"source_url,omitempty"`
+	// UpstreamTier is the tier the upstream registry reports (official,
+	// partner, community), distinct from the provider's own Tier.
+	UpstreamTier *string `json:"upstream_tier,omitempty"`
+	License      *string `json:"license,omitempty"`
+	LicenseFile  *string `json:"license_file,omitempty"`
+	LicenseText  *string `json:"license_text,omitempty"`
+	// SyncedAt is the last successful upstream metadata fetch; LicenseCheckedAt
+	// is when an archive was last examined for a license file.
+	SyncedAt         *time.Time `json:"synced_at,omitempty"`
+	LicenseCheckedAt *time.Time `json:"license_checked_at,omitempty"`
+}
+
 // ProviderVersion represents a specific version of a provider
 type ProviderVersion struct {
 	ID                 string
@@ -36,10 +59,11 @@ type ProviderVersion struct {
 	GPGPublicKey       string   // PEM-encoded GPG public key for signature verification
 	ShasumURL          string   // External URL to SHA256SUMS file (populated by mirror sync from upstream)
 	ShasumSignatureURL string   // External URL to SHA256SUMS.sig file (populated by mirror sync from upstream)
-	// ShasumStorageKey / ShasumSignatureStorageKey are populated for *uploaded*
-	// (non-mirrored) providers where the SUMS + sig files were attached to the
-	// upload form. The download handler generates pre-signed URLs from these on
-	// demand. Mirrored providers leave these NULL and use ShasumURL instead.
+	// ShasumStorageKey / ShasumSignatureStorageKey locate the SUMS + sig files
+	// in the registry's storage: attached to the upload form for uploaded
+	// providers, stored by mirror sync for mirrored ones. The download handler
+	// generates pre-signed URLs from these on demand, and falls back to
+	// ShasumURL / ShasumSignatureURL when they are NULL.
 	ShasumStorageKey          *string
 	ShasumSignatureStorageKey *string
 	PublishedBy               *string    // User ID who published this version
@@ -61,6 +85,18 @@ type ProviderVersionShasum struct {
 	SHA256Hex         string // lowercase hex SHA256 of the zip archive
 }
 
+// ProviderVersionResignature is the registry's own signature over a mirrored
+// provider version's SHA256SUMS (mirror_signing). When present it is served in
+// place of the upstream signature and key recorded on the ProviderVersion.
+type ProviderVersionResignature struct {
+	ProviderVersionID         string    // FK → provider_versions.id
+	KeyID                     string    // long key ID of the signing key
+	GPGPublicKey              string    // ASCII-armored public key that verifies the signature
+	ShasumStorageKey          string    // storage path of the rebuilt SHA256SUMS
+	ShasumSignatureStorageKey string    // storage path of the detached SHA256SUMS.sig
+	SignedAt                  time.Time // when the version was last re-signed
+}
+
 // ProviderVersionDoc holds documentation metadata for a provider version, sourced
 // from the upstream registry's v1 provider API.  Only the index entry is stored;
 // the full markdown content is fetched on demand from the v2 API.
@@ -89,4 +125,19 @@ type ProviderPlatform struct {
 	Shasum            string  // SHA256 checksum of the binary
 	H1Hash            *string // Terraform h1: dirhash of the zip archive; nil for legacy rows
 	DownloadCount     int64   // Number of times this platform binary has been downloaded
+	// Upstream is where a mirrored binary was fetched from. Only CreatePlatform
+	// and ListPlatformProvenance read or write it.
+	Upstream UpstreamProvenance
+}
+
+// ProviderDownloadCount is a number of downloads of one provider version's
+// os/arch archive on one UTC day, the granularity provider_download_stats is
+// kept at.
+type ProviderDownloadCount struct {
+	ProviderID string
+	Version    string
+	OS         string
+	Arch       string
+	Day        time.Time // UTC midnight
+	Downloads  int64
 }
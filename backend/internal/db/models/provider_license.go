@@ -0,0 +1,59 @@
+// Package models — provider_license.go defines per-provider license gating:
+// the license an admin attaches to a provider and the acceptances users
+// record before its downloads are served to them.
+package models
+
+import "time"
+
+// ProviderLicenseGate is the license attached to a provider. While Enabled,
+// the provider's downloads are served only to users who accepted
+// LicenseVersion.
+type ProviderLicenseGate struct {
+	ProviderID     string    `json:"provider_id"`
+	LicenseText    string    `json:"license_text"`
+	LicenseVersion string    `json:"license_version"`
+	Enabled        bool      `json:"enabled"`
+	UpdatedBy      *string   `json:"updated_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ProviderLicenseGateRequest is the body of PUT /api/v1/admin/providers/:id/license.
+// Enabled defaults to true.
+type ProviderLicenseGateRequest struct {
+	LicenseText    string `json:"license_text" binding:"required"`
+	LicenseVersion string `json:"license_version" binding:"required,max=100"`
+	Enabled        *bool  `json:"enabled,omitempty"`
+}
+
+// ProviderLicenseAcceptance records that a user accepted one version of a
+// provider's license.
+type ProviderLicenseAcceptance struct {
+	ID             string    `json:"id"`
+	ProviderID     string    `json:"provider_id"`
+	UserID         string    `json:"user_id"`
+	LicenseVersion string    `json:"license_version"`
+	IPAddress      *string   `json:"ip_address,omitempty"`
+	AcceptedAt     time.Time `json:"accepted_at"`
+	// Joined fields (not stored in provider_license_acceptances)
+	UserEmail *string `json:"user_email,omitempty"`
+	UserName  *string `json:"user_name,omitempty"`
+}
+
+// ProviderLicenseAcceptanceListResponse is returned by the admin acceptance
+// listing.
+type ProviderLicenseAcceptanceListResponse struct {
+	Acceptances []ProviderLicenseAcceptance `json:"acceptances"`
+	TotalCount  int                         `json:"total_count"`
+}
+
+// ProviderLicenseResponse is the public view of a gated provider's license,
+// with whether the caller has accepted its current version.
+type ProviderLicenseResponse struct {
+	Namespace      string     `json:"namespace"`
+	Type           string     `json:"type"`
+	LicenseText    string     `json:"license_text"`
+	LicenseVersion string     `json:"license_version"`
+	Accepted       bool       `json:"accepted"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+}
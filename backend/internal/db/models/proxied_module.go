@@ -0,0 +1,14 @@
+// Package models - proxied_module.go defines the ProxiedModule model that flags
+// modules populated on demand by the module pull-through proxy.
+package models
+
+import "time"
+
+// ProxiedModule records that a module was fetched from an upstream registry by
+// the pull-through proxy rather than published locally.
+type ProxiedModule struct {
+	ModuleID      string    `json:"module_id"`
+	UpstreamHost  string    `json:"upstream_host"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastFetchedAt time.Time `json:"last_fetched_at"`
+}
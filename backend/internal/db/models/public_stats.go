@@ -0,0 +1,23 @@
+// Package models - public_stats.go defines the registry-wide aggregates
+// published for status pages.
+package models
+
+import "time"
+
+// PublicStats are the registry-wide aggregates published unauthenticated for
+// status pages. They carry no per-organization or per-namespace breakdown.
+type PublicStats struct {
+	Modules           int64 `json:"modules"`
+	ModuleVersions    int64 `json:"module_versions"`
+	Providers         int64 `json:"providers"`
+	ProviderVersions  int64 `json:"provider_versions"`
+	MirroredProviders int64 `json:"mirrored_providers"`
+	// BinaryVersions counts fully synced Terraform/OpenTofu (and other tool)
+	// binary mirror versions.
+	BinaryVersions int64 `json:"binary_versions"`
+	// LastProviderMirrorSyncAt and LastBinaryMirrorSyncAt are when the most
+	// recent successful sync of any mirror of that kind finished; nil when
+	// none has.
+	LastProviderMirrorSyncAt *time.Time `json:"last_provider_mirror_sync_at"`
+	LastBinaryMirrorSyncAt   *time.Time `json:"last_binary_mirror_sync_at"`
+}
@@ -0,0 +1,62 @@
+// Package models — publish_hook.go defines an organization's pre-publish hook,
+// the external endpoint that must approve every module version before it is
+// stored, and the log of calls made to it.
+package models
+
+import "time"
+
+// Publish hook failure modes: what a publish does when the hook endpoint
+// gives no valid answer in time.
+const (
+	PublishHookFailClosed = "fail_closed"
+	PublishHookFailOpen   = "fail_open"
+)
+
+// Publish hook delivery sources.
+const (
+	PublishHookSourceUpload = "upload" // multipart upload, including registry-import
+	PublishHookSourceURL    = "url"    // upload from source_url
+	PublishHookSourceSCM    = "scm"    // SCM tag publish
+	PublishHookSourceTest   = "test"   // connectivity test
+)
+
+// Publish hook delivery outcomes.
+const (
+	PublishHookOutcomeAllowed      = "allowed"
+	PublishHookOutcomeDenied       = "denied"
+	PublishHookOutcomeFailedOpen   = "failed_open"
+	PublishHookOutcomeFailedClosed = "failed_closed"
+)
+
+// PublishHook is an organization's pre-publish hook. The secret is held
+// encrypted and never serialized; HasSecret reports that one is set.
+type PublishHook struct {
+	OrganizationID  string    `json:"organization_id"`
+	URL             string    `json:"url"`
+	EncryptedSecret string    `json:"-"`
+	HasSecret       bool      `json:"has_secret"`
+	TimeoutSeconds  int       `json:"timeout_seconds"`
+	FailureMode     string    `json:"failure_mode"`
+	Enabled         bool      `json:"enabled"` // enforced on publishes; a disabled hook can still be tested
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// PublishHookDelivery is one call to a publish hook.
+type PublishHookDelivery struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Source         string    `json:"source"`
+	Namespace      string    `json:"namespace"`
+	Name           string    `json:"name"`
+	System         string    `json:"system"`
+	Version        string    `json:"version"`
+	Checksum       string    `json:"checksum"`
+	Outcome        string    `json:"outcome"`
+	Reason         *string   `json:"reason,omitempty"`      // the endpoint's reason for its decision
+	StatusCode     *int      `json:"status_code,omitempty"` // of the last attempt; unset when no response arrived
+	Attempts       int       `json:"attempts"`
+	Error          *string   `json:"error,omitempty"` // why no valid answer was received
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
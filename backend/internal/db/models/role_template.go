@@ -25,7 +25,7 @@ func PredefinedRoleTemplates() []RoleTemplate {
 			Name:        "viewer",
 			DisplayName: "Viewer",
 			Description: &viewerDesc,
-			Scopes:      []string{"modules:read", "providers:read", "mirrors:read", "organizations:read", "scm:read"},
+			Scopes:      []string{"modules:read", "providers:read", "mirrors:read", "binaries:read", "organizations:read", "scm:read"},
 			IsSystem:    true,
 		},
 		{
@@ -39,7 +39,7 @@ func PredefinedRoleTemplates() []RoleTemplate {
 			Name:        "devops",
 			DisplayName: "DevOps",
 			Description: &devOpsDesc,
-			Scopes:      []string{"modules:read", "modules:write", "providers:read", "providers:write", "mirrors:read", "mirrors:manage", "organizations:read", "scm:read", "scm:manage"},
+			Scopes:      []string{"modules:read", "modules:write", "providers:read", "providers:write", "mirrors:read", "mirrors:manage", "binaries:read", "binaries:manage", "organizations:read", "scm:read", "scm:manage"},
 			IsSystem:    true,
 		},
 		{
@@ -60,14 +60,14 @@ func PredefinedRoleTemplates() []RoleTemplate {
 			Name:        "auditor",
 			DisplayName: "Auditor",
 			Description: &auditorDesc,
-			Scopes:      []string{"modules:read", "providers:read", "mirrors:read", "organizations:read", "scm:read", "audit:read"},
+			Scopes:      []string{"modules:read", "providers:read", "mirrors:read", "binaries:read", "organizations:read", "scm:read", "audit:read"},
 			IsSystem:    true,
 		},
 		{
 			Name:        "org_owner",
 			DisplayName: "Organization Owner",
 			Description: &orgOwnerDesc,
-			Scopes:      []string{"organizations:write", "users:read", "api_keys:manage", "modules:read", "modules:write", "providers:read", "providers:write", "mirrors:read", "mirrors:manage", "scm:read", "scm:manage"},
+			Scopes:      []string{"organizations:write", "users:read", "api_keys:manage", "modules:read", "modules:write", "providers:read", "providers:write", "mirrors:read", "mirrors:manage", "binaries:read", "binaries:manage", "scm:read", "scm:manage"},
 			IsSystem:    true,
 		},
 		{
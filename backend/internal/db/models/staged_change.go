@@ -0,0 +1,55 @@
+// Package models - staged_change.go defines the pending changes of the
+// two-person rule: sensitive admin edits stored for a second admin to approve
+// before they are applied.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Resource types that go through the two-person rule.
+const (
+	StagedResourceRoleTemplate     = "role_template"
+	StagedResourceMirrorPolicy     = "mirror_policy"
+	StagedResourceOIDCGroupMapping = "oidc_group_mapping"
+	StagedResourceTwoPersonRule    = "two_person_rule"
+)
+
+// Staged change actions.
+const (
+	StagedActionCreate = "create"
+	StagedActionUpdate = "update"
+	StagedActionDelete = "delete"
+)
+
+// Staged change statuses. A change is approved only once it has been
+// applied; failed is an approved change whose application failed.
+const (
+	StagedChangeStatusPending  = "pending"
+	StagedChangeStatusApproved = "approved"
+	StagedChangeStatusRejected = "rejected"
+	StagedChangeStatusFailed   = "failed"
+)
+
+// StagedChange is a sensitive admin change awaiting, or decided by, a second
+// admin's review.
+type StagedChange struct {
+	ID           string  `json:"id"`
+	ResourceType string  `json:"resource_type"`
+	ResourceID   *string `json:"resource_id,omitempty"`
+	Action       string  `json:"action"`
+	// Before is the resource when the change was requested; nil for creates.
+	Before json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	// After is the requested change, in the body format of the endpoint it
+	// was made through; nil for deletes.
+	After         json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	Status        string          `json:"status"`
+	RequestedBy   *string         `json:"requested_by,omitempty"`
+	ReviewedBy    *string         `json:"reviewed_by,omitempty"`
+	ReviewComment *string         `json:"review_comment,omitempty"`
+	// ApplyError is why applying the approved change failed.
+	ApplyError *string    `json:"apply_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
@@ -35,9 +35,21 @@ type SystemSettings struct {
 	NotificationsConfiguredAt sql.NullTime `db:"notifications_configured_at" json:"notifications_configured_at,omitempty"`
 	NotificationsConfig       []byte       `db:"notifications_config" json:"notifications_config,omitempty"`
 	// Audit retention (migration 000023)
-	AuditRetentionDays int       `db:"audit_retention_days" json:"audit_retention_days"`
-	CreatedAt          time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
+	AuditRetentionDays int `db:"audit_retention_days" json:"audit_retention_days"`
+	// Maintenance mode (migration 000075); see MaintenanceMode.
+	MaintenanceEnabled   bool          `db:"maintenance_enabled" json:"maintenance_enabled"`
+	MaintenanceMessage   string        `db:"maintenance_message" json:"maintenance_message"`
+	MaintenanceETA       sql.NullTime  `db:"maintenance_eta" json:"maintenance_eta,omitempty"`
+	MaintenanceEnabledBy uuid.NullUUID `db:"maintenance_enabled_by" json:"maintenance_enabled_by,omitempty"`
+	MaintenanceEnabledAt sql.NullTime  `db:"maintenance_enabled_at" json:"maintenance_enabled_at,omitempty"`
+	// Two-person rule (migration 000092); see StagedChangeRepository.
+	TwoPersonRuleEnabled bool `db:"two_person_rule_enabled" json:"two_person_rule_enabled"`
+	// Setup completion record (migration 000095). SetupCompletedBy is the
+	// client address of the request that completed setup.
+	SetupCompletedAt sql.NullTime   `db:"setup_completed_at" json:"setup_completed_at,omitempty"`
+	SetupCompletedBy sql.NullString `db:"setup_completed_by" json:"-"`
+	CreatedAt        time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time      `db:"updated_at" json:"updated_at"`
 }
 
 // StorageConfig holds storage backend configuration
@@ -76,6 +88,11 @@ type StorageConfig struct {
 	GCSCredentialsJSONEncrypted sql.NullString `db:"gcs_credentials_json_encrypted" json:"-"` // Never expose
 	GCSEndpoint                 sql.NullString `db:"gcs_endpoint" json:"gcs_endpoint,omitempty"`
 
+	// Artifactory / WebDAV settings (migration 000057)
+	ArtifactoryBaseURL              sql.NullString `db:"artifactory_base_url" json:"artifactory_base_url,omitempty"`
+	ArtifactoryRepository           sql.NullString `db:"artifactory_repository" json:"artifactory_repository,omitempty"`
+	ArtifactoryAccessTokenEncrypted sql.NullString `db:"artifactory_access_token_encrypted" json:"-"` // Never expose
+
 	// Metadata
 	CreatedAt time.Time     `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time     `db:"updated_at" json:"updated_at"`
@@ -85,7 +102,7 @@ type StorageConfig struct {
 
 // StorageConfigInput is used for creating/updating storage configuration
 type StorageConfigInput struct {
-	BackendType string `json:"backend_type" binding:"required,oneof=local azure s3 gcs"`
+	BackendType string `json:"backend_type" binding:"required,oneof=local azure s3 gcs artifactory"`
 	Activate    *bool  `json:"activate,omitempty"` // When true, make this config active on creation; defaults to false
 
 	// Local storage settings
@@ -117,6 +134,11 @@ type StorageConfigInput struct {
 	GCSCredentialsFile string `json:"gcs_credentials_file,omitempty"`
 	GCSCredentialsJSON string `json:"gcs_credentials_json,omitempty"` // Plain text input
 	GCSEndpoint        string `json:"gcs_endpoint,omitempty"`
+
+	// Artifactory / WebDAV settings
+	ArtifactoryBaseURL     string `json:"artifactory_base_url,omitempty"`
+	ArtifactoryRepository  string `json:"artifactory_repository,omitempty"`
+	ArtifactoryAccessToken string `json:"artifactory_access_token,omitempty"` // Plain text input
 }
 
 // StorageConfigResponse is the API response for storage configuration
@@ -156,6 +178,11 @@ type StorageConfigResponse struct {
 	GCSCredentialsJSONSet bool   `json:"gcs_credentials_json_set"`
 	GCSEndpoint           string `json:"gcs_endpoint,omitempty"`
 
+	// Artifactory / WebDAV settings
+	ArtifactoryBaseURL        string `json:"artifactory_base_url,omitempty"`
+	ArtifactoryRepository     string `json:"artifactory_repository,omitempty"`
+	ArtifactoryAccessTokenSet bool   `json:"artifactory_access_token_set"`
+
 	// Metadata
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -238,5 +265,14 @@ func (s *StorageConfig) ToResponse() StorageConfigResponse {
 		resp.GCSEndpoint = s.GCSEndpoint.String
 	}
 
+	// Artifactory
+	if s.ArtifactoryBaseURL.Valid {
+		resp.ArtifactoryBaseURL = s.ArtifactoryBaseURL.String
+	}
+	if s.ArtifactoryRepository.Valid {
+		resp.ArtifactoryRepository = s.ArtifactoryRepository.String
+	}
+	resp.ArtifactoryAccessTokenSet = s.ArtifactoryAccessTokenEncrypted.Valid && s.ArtifactoryAccessTokenEncrypted.String != ""
+
 	return resp
 }
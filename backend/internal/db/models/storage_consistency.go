@@ -0,0 +1,80 @@
+// Package models - storage_consistency.go defines the storage consistency
+// checker's runs and its findings of artifacts whose storage object is gone.
+package models
+
+import "time"
+
+// Artifact types checked for a missing storage object. Each names the table
+// holding the artifact row.
+const (
+	ArtifactTypeModuleVersion            = "module_version"
+	ArtifactTypeProviderPlatform         = "provider_platform"
+	ArtifactTypeTerraformVersionPlatform = "terraform_version_platform"
+)
+
+// ConsistencyArtifactTypes lists every artifact type, in checking order.
+var ConsistencyArtifactTypes = []string{
+	ArtifactTypeModuleVersion,
+	ArtifactTypeProviderPlatform,
+	ArtifactTypeTerraformVersionPlatform,
+}
+
+// Finding severities. A critical finding is an uploaded artifact the registry
+// held the only copy of; a warning is a mirrored one a sync can restore.
+const (
+	ConsistencySeverityCritical = "critical"
+	ConsistencySeverityWarning  = "warning"
+)
+
+// StorageArtifact is an artifact row and the storage object it references.
+type StorageArtifact struct {
+	Type        string
+	ID          string
+	StorageKey  string
+	Description string
+	// MirrorConfigID is the provider mirror or Terraform binary mirror the
+	// artifact was synced by; nil for uploads.
+	MirrorConfigID *string
+}
+
+// Severity is how serious a missing object is for the artifact.
+func (a StorageArtifact) Severity() string {
+	if a.MirrorConfigID != nil {
+		return ConsistencySeverityWarning
+	}
+	return ConsistencySeverityCritical
+}
+
+// StorageConsistencyRun is one pass of the storage consistency checker.
+type StorageConsistencyRun struct {
+	ID          string     `json:"id"`
+	FullScan    bool       `json:"full_scan"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Checked     int        `json:"checked"`
+	Missing     int        `json:"missing"`
+	// Errors counts Exists calls that failed; those artifacts were left as
+	// they were.
+	Errors int `json:"errors"`
+	// Error is why the run stopped early, if it did.
+	Error *string `json:"error,omitempty"`
+}
+
+// StorageConsistencyFinding is an artifact whose storage object was missing
+// when last checked.
+type StorageConsistencyFinding struct {
+	ID              string    `json:"id"`
+	ArtifactType    string    `json:"artifact_type"`
+	ArtifactID      string    `json:"artifact_id"`
+	StorageKey      string    `json:"storage_key"`
+	Severity        string    `json:"severity"`
+	Description     string    `json:"description"`
+	MirrorConfigID  *string   `json:"mirror_config_id,omitempty"`
+	FirstDetectedAt time.Time `json:"first_detected_at"`
+	LastDetectedAt  time.Time `json:"last_detected_at"`
+	// BrokenAt is when downloads of the artifact started answering 410.
+	BrokenAt *time.Time `json:"broken_at,omitempty"`
+	// UpstreamProvenance is where a mirrored platform's binary came from,
+	// filled in by ListFindings only.
+	UpstreamProvenance
+}
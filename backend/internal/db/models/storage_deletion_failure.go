@@ -0,0 +1,18 @@
+// Package models - storage_deletion_failure.go defines the record of a storage
+// object whose delete failed after the rows referencing it were removed.
+package models
+
+import "time"
+
+// StorageDeletionFailure is a storage object left behind by a failed delete,
+// awaiting a garbage-collection retry.
+type StorageDeletionFailure struct {
+	ID             string    `json:"id"`
+	StorageKey     string    `json:"storage_key"`
+	StorageBackend *string   `json:"storage_backend,omitempty"`
+	Source         string    `json:"source"`
+	LastError      string    `json:"last_error"`
+	Attempts       int       `json:"attempts"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastAttemptAt  time.Time `json:"last_attempt_at"`
+}
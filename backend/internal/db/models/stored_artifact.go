@@ -0,0 +1,33 @@
+// Package models — stored_artifact.go defines StoredArtifact, one storage
+// object referenced from a database path column, as listed by the storage key
+// relocation tool.
+package models
+
+// Kinds of StoredArtifact, one per database column that holds a storage key.
+const (
+	StoredArtifactModuleArchive            = "module_archive"             // module_versions.storage_path
+	StoredArtifactProviderArchive          = "provider_archive"           // provider_platforms.storage_path
+	StoredArtifactProviderShasums          = "provider_shasums"           // provider_versions.shasum_storage_key
+	StoredArtifactProviderShasumsSignature = "provider_shasums_signature" // provider_versions.shasum_signature_storage_key
+	StoredArtifactResignedShasums          = "resigned_shasums"           // provider_version_resignatures.shasum_storage_key
+	StoredArtifactResignedShasumsSignature = "resigned_shasums_signature" // provider_version_resignatures.shasum_signature_storage_key
+)
+
+// StoredArtifact is a storage object together with the identity its canonical
+// key is derived from. Name is the module name for module archives and the
+// provider type otherwise; System, OS, Arch and KeyID are only set for the
+// kinds that use them. Checksum is the hex SHA-256 recorded for the object, if
+// the registry records one.
+type StoredArtifact struct {
+	Kind      string `json:"kind"`
+	ID        string `json:"id"`
+	Key       string `json:"key"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	System    string `json:"system,omitempty"`
+	Version   string `json:"version"`
+	OS        string `json:"os,omitempty"`
+	Arch      string `json:"arch,omitempty"`
+	KeyID     string `json:"key_id,omitempty"`
+	Checksum  string `json:"-"`
+}
@@ -0,0 +1,61 @@
+// Package models - sync_history.go holds the retention defaults and aggregate
+// stats shared by provider mirror and Terraform binary mirror sync history.
+package models
+
+import "time"
+
+const (
+	// DefaultSyncHistoryRetentionCount is the number of most-recent sync runs
+	// kept per mirror config when the config does not override it.
+	DefaultSyncHistoryRetentionCount = 500
+	// DefaultSyncHistoryRetentionDays is the age, in days, after which sync
+	// runs are pruned when the config does not override it.
+	DefaultSyncHistoryRetentionDays = 90
+	// MaxSyncHistoryPageSize caps the limit accepted by the history endpoints.
+	MaxSyncHistoryPageSize = 200
+)
+
+// SyncHistoryStats summarizes the finished sync runs still retained for a
+// mirror config. Running syncs are not counted.
+type SyncHistoryStats struct {
+	TotalRuns      int `json:"total_runs"`
+	SuccessfulRuns int `json:"successful_runs"`
+	FailedRuns     int `json:"failed_runs"`
+	CancelledRuns  int `json:"cancelled_runs"`
+	// SuccessRate is SuccessfulRuns / TotalRuns in the range 0..1; nil when no
+	// run has finished yet.
+	SuccessRate   *float64   `json:"success_rate"`
+	WindowStart   *time.Time `json:"window_start,omitempty"` // start of the oldest retained run
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+}
+
+// SyncHistoryStatsRow is the raw aggregate row the repositories scan into
+// before deriving SuccessRate.
+type SyncHistoryStatsRow struct {
+	TotalRuns      int        `db:"total_runs"`
+	SuccessfulRuns int        `db:"successful_runs"`
+	FailedRuns     int        `db:"failed_runs"`
+	CancelledRuns  int        `db:"cancelled_runs"`
+	WindowStart    *time.Time `db:"window_start"`
+	LastSuccessAt  *time.Time `db:"last_success_at"`
+	LastFailureAt  *time.Time `db:"last_failure_at"`
+}
+
+// Stats converts the raw aggregate row into a SyncHistoryStats.
+func (r SyncHistoryStatsRow) Stats() *SyncHistoryStats {
+	s := &SyncHistoryStats{
+		TotalRuns:      r.TotalRuns,
+		SuccessfulRuns: r.SuccessfulRuns,
+		FailedRuns:     r.FailedRuns,
+		CancelledRuns:  r.CancelledRuns,
+		WindowStart:    r.WindowStart,
+		LastSuccessAt:  r.LastSuccessAt,
+		LastFailureAt:  r.LastFailureAt,
+	}
+	if r.TotalRuns > 0 {
+		rate := float64(r.SuccessfulRuns) / float64(r.TotalRuns)
+		s.SuccessRate = &rate
+	}
+	return s
+}
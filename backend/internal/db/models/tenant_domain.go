@@ -0,0 +1,65 @@
+// Package models — tenant_domain.go defines the custom hostnames that serve one
+// organization's view of a shared registry deployment, with optional
+// per-domain branding.
+package models
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// TenantDomain maps Hostname to an organization. The branding fields override
+// the global UI theme on that hostname; nil keeps the global value.
+type TenantDomain struct {
+	ID             string    `json:"id"`
+	Hostname       string    `json:"hostname"`
+	OrganizationID string    `json:"organization_id"`
+	ProductName    *string   `json:"product_name,omitempty"`
+	PrimaryColor   *string   `json:"primary_color,omitempty"`
+	LogoURL        *string   `json:"logo_url,omitempty"`
+	FaviconURL     *string   `json:"favicon_url,omitempty"`
+	CreatedBy      *string   `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// HasBranding reports whether the domain overrides any theme field.
+func (d *TenantDomain) HasBranding() bool {
+	return d.ProductName != nil || d.PrimaryColor != nil || d.LogoURL != nil || d.FaviconURL != nil
+}
+
+// Brand returns theme with the domain's branding laid over it. theme may be
+// nil (no global theme configured); it is not modified.
+func (d *TenantDomain) Brand(theme *UIThemeConfig) *UIThemeConfig {
+	out := &UIThemeConfig{UpdatedAt: d.UpdatedAt}
+	if theme != nil {
+		*out = *theme
+		if d.UpdatedAt.After(theme.UpdatedAt) {
+			out.UpdatedAt = d.UpdatedAt
+		}
+	}
+	if d.ProductName != nil {
+		out.ProductName = d.ProductName
+	}
+	if d.PrimaryColor != nil {
+		out.PrimaryColor = d.PrimaryColor
+	}
+	if d.LogoURL != nil {
+		out.LogoURL = d.LogoURL
+	}
+	if d.FaviconURL != nil {
+		out.FaviconURL = d.FaviconURL
+	}
+	return out
+}
+
+// TenantHostname normalizes a request Host for tenant domain matching: it is
+// lowercased and loses any port and trailing dot.
+func TenantHostname(host string) string {
+	host = strings.TrimSpace(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTenantHostname(t *testing.T) {
+	for host, want := range map[string]string{
+		"modules.acme-blue.example.com":      "modules.acme-blue.example.com",
+		"Modules.ACME-Blue.example.com:8443": "modules.acme-blue.example.com",
+		"modules.acme-blue.example.com.":     "modules.acme-blue.example.com",
+		"[::1]:8080":                         "::1",
+		" modules.acme-red.example.com:443 ": "modules.acme-red.example.com",
+	} {
+		if got := TenantHostname(host); got != want {
+			t.Errorf("TenantHostname(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestTenantDomainBrand(t *testing.T) {
+	global, blue, logo := "Registry", "#0055ff", "/logo.svg"
+	older, newer := time.Unix(100, 0), time.Unix(200, 0)
+	theme := &UIThemeConfig{ProductName: &global, LogoURL: &logo, UpdatedAt: older}
+
+	name := "Acme Blue Modules"
+	d := &TenantDomain{ProductName: &name, PrimaryColor: &blue, UpdatedAt: newer}
+	got := d.Brand(theme)
+	if *got.ProductName != name || *got.PrimaryColor != blue || *got.LogoURL != logo {
+		t.Errorf("Brand = %+v, want the domain's name and color over the global logo", got)
+	}
+	if !got.UpdatedAt.Equal(newer) {
+		t.Errorf("UpdatedAt = %v, want the later of the two", got.UpdatedAt)
+	}
+	if *theme.ProductName != global {
+		t.Error("Brand modified the global theme")
+	}
+
+	if got := d.Brand(nil); got.LogoURL != nil || *got.ProductName != name {
+		t.Errorf("Brand(nil) = %+v, want only the domain's branding", got)
+	}
+}
@@ -40,6 +40,21 @@ type TerraformMirrorConfig struct {
 	// from a GitHub-hosted upstream. Default off; only meaningful for
 	// unsigned-upstream tools such as OPA where no GPG signature exists.
 	VerifyGitHubAttestation bool `json:"verify_github_attestation" db:"verify_github_attestation"`
+	// HistoryRetentionCount and HistoryRetentionDays bound terraform_sync_history
+	// for this config; the sync job prunes runs outside either limit. 0 disables
+	// the corresponding limit.
+	HistoryRetentionCount int `json:"history_retention_count" db:"history_retention_count"`
+	HistoryRetentionDays  int `json:"history_retention_days" db:"history_retention_days"`
+	// ExcludeEOLFromLatest keeps end-of-life versions (see TerraformEOLRule)
+	// from being chosen as the latest version; they stay downloadable by
+	// explicit version.
+	ExcludeEOLFromLatest bool `json:"exclude_eol_from_latest" db:"exclude_eol_from_latest"`
+	// MaxStorageBytes stops the sync downloading new binaries once the
+	// config's stored binaries reach it; nil means no limit.
+	// StorageAlertLevel is the highest usage threshold (percent) already
+	// notified.
+	MaxStorageBytes   *int64 `json:"max_storage_bytes,omitempty" db:"max_storage_bytes"`
+	StorageAlertLevel int    `json:"-" db:"storage_alert_level"`
 }
 
 // TerraformVersion represents a single Terraform/OpenTofu release version within a mirror config.
@@ -57,6 +72,11 @@ type TerraformVersion struct {
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
 
+	// IsEOL and EOLMessage are copied from the matching TerraformEOLRule by
+	// the EOL refresh job.
+	IsEOL      bool    `json:"is_eol" db:"is_eol"`
+	EOLMessage *string `json:"eol_message,omitempty" db:"eol_message"`
+
 	// Storage keys for the per-version GPG-verified SHA256SUMS file and its
 	// detached signature. NULL until the sync job has uploaded them. Used by
 	// the public download endpoint to hand clients both files for offline
@@ -85,13 +105,44 @@ type TerraformVersionPlatform struct {
 	// to a release attestation verified against a pinned signer identity
 	// (GitHub Artifact Attestation today). Independent of GPGVerified and
 	// SHA256Verified.
-	AttestationVerified bool       `json:"attestation_verified" db:"attestation_verified"`
-	SyncStatus          string     `json:"sync_status" db:"sync_status"` // pending|syncing|synced|failed
-	SyncError           *string    `json:"sync_error,omitempty" db:"sync_error"`
-	SyncedAt            *time.Time `json:"synced_at,omitempty" db:"synced_at"`
-	DownloadCount       int64      `json:"download_count" db:"download_count"`
-	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+	AttestationVerified bool `json:"attestation_verified" db:"attestation_verified"`
+	// SignatureMethod is how the version's SHA256SUMS file, against which
+	// this binary was checked, was authenticated: "gpg" or "cosign". Nil
+	// when it was not authenticated.
+	SignatureMethod *string    `json:"signature_method,omitempty" db:"signature_method"`
+	SyncStatus      string     `json:"sync_status" db:"sync_status"` // pending|syncing|synced|failed
+	SyncError       *string    `json:"sync_error,omitempty" db:"sync_error"`
+	SyncedAt        *time.Time `json:"synced_at,omitempty" db:"synced_at"`
+	DownloadCount   int64      `json:"download_count" db:"download_count"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	// SizeBytes is the stored binary's size; nil until it has been synced.
+	SizeBytes *int64 `json:"size_bytes,omitempty" db:"size_bytes"`
+	// UpstreamProvenance records where the binary was fetched from; it is
+	// read by GetPlatform and ListPlatformsForVersion.
+	UpstreamProvenance
+}
+
+// TerraformStoredArtifact is one storage object a mirrored version keeps: a
+// platform package, or the version's SHA256SUMS or its detached signature
+// (StorageBackend is only recorded for packages).
+type TerraformStoredArtifact struct {
+	StorageKey     string  `json:"storage_key" db:"storage_key"`
+	StorageBackend *string `json:"storage_backend,omitempty" db:"storage_backend"`
+}
+
+// TerraformEOLRule marks the versions of a tool whose version starts with
+// VersionPrefix ("1.3" covers 1.3.0 through 1.3.x and their pre-releases) as
+// end-of-life from EOLDate, or at once when EOLDate is nil. The rules are
+// operator-maintained: upstream release indexes carry no support status.
+type TerraformEOLRule struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	Tool          string     `json:"tool" db:"tool"`
+	VersionPrefix string     `json:"version_prefix" db:"version_prefix"`
+	EOLDate       *time.Time `json:"eol_date,omitempty" db:"eol_date"`
+	Message       *string    `json:"message,omitempty" db:"message"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // TerraformSyncHistory records each sync run (scheduled or manual) for a specific mirror config.
@@ -128,6 +179,14 @@ type CreateTerraformMirrorConfigRequest struct {
 	// VerifyGitHubAttestation opts into GitHub Artifact Attestation verification
 	// (default false). Only meaningful for GitHub-hosted unsigned-upstream tools.
 	VerifyGitHubAttestation *bool `json:"verify_github_attestation,omitempty"`
+	// HistoryRetentionCount keeps the last N sync runs (default 500, 0 = no limit).
+	HistoryRetentionCount *int `json:"history_retention_count,omitempty" binding:"omitempty,min=0"`
+	// HistoryRetentionDays prunes sync runs older than N days (default 90, 0 = no limit).
+	HistoryRetentionDays *int `json:"history_retention_days,omitempty" binding:"omitempty,min=0"`
+	// ExcludeEOLFromLatest keeps end-of-life versions out of latest (default false).
+	ExcludeEOLFromLatest *bool `json:"exclude_eol_from_latest,omitempty"`
+	// MaxStorageBytes caps the stored binaries (default no limit, 0 = no limit).
+	MaxStorageBytes *int64 `json:"max_storage_bytes,omitempty" binding:"omitempty,min=0"`
 }
 
 // UpdateTerraformMirrorConfigRequest is the request body for PUT /api/v1/admin/terraform-mirrors/:id.
@@ -146,6 +205,29 @@ type UpdateTerraformMirrorConfigRequest struct {
 	AutoApproveRules  *string  `json:"auto_approve_rules,omitempty"` // JSON: AutoApproveRules
 	// VerifyGitHubAttestation toggles GitHub Artifact Attestation verification.
 	VerifyGitHubAttestation *bool `json:"verify_github_attestation,omitempty"`
+	// HistoryRetentionCount keeps the last N sync runs (default 500, 0 = no limit).
+	HistoryRetentionCount *int `json:"history_retention_count,omitempty" binding:"omitempty,min=0"`
+	// HistoryRetentionDays prunes sync runs older than N days (default 90, 0 = no limit).
+	HistoryRetentionDays *int `json:"history_retention_days,omitempty" binding:"omitempty,min=0"`
+	// ExcludeEOLFromLatest toggles keeping end-of-life versions out of latest.
+	ExcludeEOLFromLatest *bool `json:"exclude_eol_from_latest,omitempty"`
+	// MaxStorageBytes sets the storage limit; 0 removes it.
+	MaxStorageBytes *int64 `json:"max_storage_bytes,omitempty" binding:"omitempty,min=0"`
+}
+
+// TerraformEOLRuleRequest is the request body for POST and PUT on
+// /api/v1/admin/terraform-mirrors/eol-rules. EOLDate is a YYYY-MM-DD date.
+type TerraformEOLRuleRequest struct {
+	Tool          string  `json:"tool" binding:"required,oneof=terraform opentofu packer sentinel opa terraform-docs custom"`
+	VersionPrefix string  `json:"version_prefix" binding:"required,max=50"`
+	EOLDate       *string `json:"eol_date,omitempty"`
+	Message       *string `json:"message,omitempty"`
+}
+
+// TerraformEOLRuleListResponse wraps the EOL rules.
+type TerraformEOLRuleListResponse struct {
+	Rules      []TerraformEOLRule `json:"rules"`
+	TotalCount int                `json:"total_count"`
 }
 
 // TerraformMirrorConfigListResponse wraps a list of mirror configs.
@@ -160,7 +242,12 @@ type TerraformMirrorStatusResponse struct {
 	VersionCount  int                    `json:"version_count"`
 	PlatformCount int                    `json:"platform_count"`
 	PendingCount  int                    `json:"pending_count"`
+	EOLCount      int                    `json:"eol_count"` // versions marked end-of-life
 	LatestVersion *string                `json:"latest_version,omitempty"`
+	SyncStats     *SyncHistoryStats      `json:"sync_stats,omitempty"` // aggregate over the retained history
+	// StorageUsage is the storage the config's binaries take up against its
+	// max_storage_bytes. Omitted when the usage lookup fails.
+	StorageUsage *MirrorStorageUsage `json:"storage_usage,omitempty"`
 }
 
 // TerraformVersionListResponse wraps the list of versions with pagination info.
@@ -173,6 +260,8 @@ type TerraformVersionListResponse struct {
 type TerraformSyncHistoryListResponse struct {
 	History    []TerraformSyncHistory `json:"history"`
 	TotalCount int                    `json:"total_count"`
+	Limit      int                    `json:"limit"`
+	Offset     int                    `json:"offset"`
 }
 
 // TerraformBinaryDownloadResponse is returned by the public download endpoint.
@@ -0,0 +1,48 @@
+// Package models — tier.go defines the registry-assigned tiers admins badge
+// providers and modules with, and the normalisation applied to tier names
+// given in requests.
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Built-in tiers. Any other name matching the tier pattern is accepted too,
+// so a deployment can add its own.
+const (
+	// TierCommunity is the default tier of uploaded providers and modules.
+	TierCommunity = "community"
+	// TierApproved marks artifacts certified for internal use.
+	TierApproved = "approved"
+	// TierOfficial marks artifacts maintained by the platform team.
+	TierOfficial = "official"
+	// TierMirrored is the default tier of providers created by mirror sync
+	// and the pull-through cache.
+	TierMirrored = "mirrored"
+)
+
+// tierAliases maps the public registry's badge names to the built-in tier
+// they correspond to, so callers can use either.
+var tierAliases = map[string]string{
+	"verified": TierApproved,
+	"partner":  TierApproved,
+}
+
+// reTier matches tier names: lowercase letters, digits and hyphens, starting
+// with a letter, at most 32 characters.
+var reTier = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+// NormalizeTier lower-cases and trims name, resolves the verified and partner
+// aliases, and returns an error if the result is not a valid tier name.
+func NormalizeTier(name string) (string, error) {
+	tier := strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := tierAliases[tier]; ok {
+		tier = alias
+	}
+	if !reTier.MatchString(tier) {
+		return "", fmt.Errorf("%q is not a valid tier: must be 1-32 lowercase letters, digits or hyphens, starting with a letter", name)
+	}
+	return tier, nil
+}
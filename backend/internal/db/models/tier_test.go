@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestNormalizeTier(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"approved", TierApproved, false},
+		{" Official ", TierOfficial, false},
+		{"verified", TierApproved, false},
+		{"Partner", TierApproved, false},
+		{"platform-gold", "platform-gold", false},
+		{"", "", true},
+		{"1st-party", "", true},
+		{"under_score", "", true},
+		{"a-very-long-tier-name-that-goes-past-the-limit", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeTier(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeTier(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Package models - upstream_provenance.go defines where a mirrored platform
+// binary was fetched from, kept for traceability of mirrored artifacts.
+package models
+
+import "time"
+
+// Upstream provenance states of a mirrored platform.
+const (
+	// ProvenanceRecorded marks a platform whose upstream source was recorded
+	// when it was synced.
+	ProvenanceRecorded = "recorded"
+	// ProvenanceUnknown marks a platform mirrored before provenance was
+	// recorded; its upstream source is not known.
+	ProvenanceUnknown = "unknown"
+)
+
+// UpstreamProvenance is the upstream source of a mirrored platform binary.
+// Provenance is nil for artifacts that were uploaded rather than mirrored.
+type UpstreamProvenance struct {
+	Provenance *string `json:"provenance,omitempty" db:"provenance"`
+	// UpstreamRegistry is the hostname of the registry or release server the
+	// binary was mirrored from.
+	UpstreamRegistry    *string    `json:"upstream_registry,omitempty" db:"upstream_registry"`
+	UpstreamDownloadURL *string    `json:"upstream_download_url,omitempty" db:"upstream_download_url"`
+	UpstreamSHASumURL   *string    `json:"upstream_shasum_url,omitempty" db:"upstream_shasum_url"`
+	FetchedAt           *time.Time `json:"fetched_at,omitempty" db:"fetched_at"`
+}
+
+// NewUpstreamProvenance returns the recorded provenance of a binary fetched
+// from downloadURL at fetchedAt. Empty strings are stored as NULL.
+func NewUpstreamProvenance(registry, downloadURL, shasumURL string, fetchedAt time.Time) UpstreamProvenance {
+	recorded := ProvenanceRecorded
+	return UpstreamProvenance{
+		Provenance:          &recorded,
+		UpstreamRegistry:    nonEmpty(registry),
+		UpstreamDownloadURL: nonEmpty(downloadURL),
+		UpstreamSHASumURL:   nonEmpty(shasumURL),
+		FetchedAt:           &fetchedAt,
+	}
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,30 @@
+// Package models — usage_consumer.go defines the module and provider versions
+// consumers (repositories or workspaces) report using.
+package models
+
+import "time"
+
+// Usage kinds: what a usage record refers to.
+const (
+	UsageKindModule   = "module"
+	UsageKindProvider = "provider"
+)
+
+// UsageRecord is one module or provider version a consumer reported using.
+// System is empty for providers.
+type UsageRecord struct {
+	Kind      string
+	Namespace string
+	Name      string
+	System    string
+	Version   string
+}
+
+// UsageConsumer is a consumer's use of one version of a module or provider.
+type UsageConsumer struct {
+	OrganizationID string    `json:"organization_id"`
+	Consumer       string    `json:"consumer"`
+	Version        string    `json:"version"`
+	FirstSeenAt    time.Time `json:"first_seen_at"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+}
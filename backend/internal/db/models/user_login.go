@@ -0,0 +1,33 @@
+// Package models - user_login.go defines recorded user logins and the admin
+// user search result that carries them.
+package models
+
+import "time"
+
+// Authentication providers recorded on a UserLogin.
+const (
+	AuthProviderOIDC    = "oidc"
+	AuthProviderAzureAD = "azuread"
+	AuthProviderSAML    = "saml"
+	AuthProviderLDAP    = "ldap"
+	AuthProviderDev     = "dev"
+)
+
+// UserLogin is a user's most recent successful interactive login.
+type UserLogin struct {
+	UserID       string    `json:"user_id"`
+	LastLoginAt  time.Time `json:"last_login_at"`
+	AuthProvider string    `json:"auth_provider"`
+	// AuthIssuer is the OIDC/Azure AD token issuer or SAML IdP name; empty
+	// for LDAP and dev logins.
+	AuthIssuer string `json:"auth_issuer"`
+}
+
+// UserSearchResult is a user returned by the admin user search, with their
+// organization memberships and last login (nil if they never logged in).
+type UserSearchResult struct {
+	UserWithOrgRoles
+	LastLoginAt  *time.Time `json:"last_login_at"`
+	AuthProvider *string    `json:"auth_provider"`
+	AuthIssuer   *string    `json:"auth_issuer,omitempty"`
+}
@@ -0,0 +1,88 @@
+// Package repositories - api_key_claim_repository.go persists one-time API key
+// retrieval links. The table lives on the registry's own connection, like
+// user_token_revocations, so it works whichever connection holds identity data.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// APIKeyClaimRepository handles API key claim database operations.
+type APIKeyClaimRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyClaimRepository creates a new API key claim repository.
+func NewAPIKeyClaimRepository(db *sql.DB) *APIKeyClaimRepository {
+	return &APIKeyClaimRepository{db: db}
+}
+
+// Create stores a claim and fills in its ID and CreatedAt.
+func (r *APIKeyClaimRepository) Create(ctx context.Context, claim *models.APIKeyClaim) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO api_key_claims (api_key_id, user_id, token_hash, encrypted_key, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		claim.APIKeyID, claim.UserID, claim.TokenHash, claim.EncryptedKey, claim.ExpiresAt,
+	).Scan(&claim.ID, &claim.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create api key claim: %w", err)
+	}
+	return nil
+}
+
+// Claim deletes and returns the unexpired claim with tokenHash, provided
+// userID owns the key, or the key has no owner and asAdmin is set. Deleting
+// in the same statement makes the claim single-use under concurrent requests.
+// It returns nil when no such claim exists.
+func (r *APIKeyClaimRepository) Claim(ctx context.Context, tokenHash, userID string, asAdmin bool) (*models.APIKeyClaim, error) {
+	c := &models.APIKeyClaim{}
+	err := r.db.QueryRowContext(ctx, `
+		DELETE FROM api_key_claims
+		WHERE token_hash = $1 AND expires_at > NOW()
+		  AND (user_id = $2 OR (user_id IS NULL AND $3))
+		RETURNING id, api_key_id, user_id, token_hash, encrypted_key, expires_at, created_at`,
+		tokenHash, userID, asAdmin,
+	).Scan(&c.ID, &c.APIKeyID, &c.UserID, &c.TokenHash, &c.EncryptedKey, &c.ExpiresAt, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim api key: %w", err)
+	}
+	return c, nil
+}
+
+// ListExpired returns the claims past their expiry, oldest first.
+func (r *APIKeyClaimRepository) ListExpired(ctx context.Context) ([]models.APIKeyClaim, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, api_key_id, user_id, expires_at, created_at
+		FROM api_key_claims WHERE expires_at <= NOW()
+		ORDER BY expires_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired api key claims: %w", err)
+	}
+	defer rows.Close()
+
+	var claims []models.APIKeyClaim
+	for rows.Next() {
+		var c models.APIKeyClaim
+		if err := rows.Scan(&c.ID, &c.APIKeyID, &c.UserID, &c.ExpiresAt, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan api key claim: %w", err)
+		}
+		claims = append(claims, c)
+	}
+	return claims, rows.Err()
+}
+
+// Delete removes a claim. Deleting a missing claim is not an error.
+func (r *APIKeyClaimRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM api_key_claims WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete api key claim: %w", err)
+	}
+	return nil
+}
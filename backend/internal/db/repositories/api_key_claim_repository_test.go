@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func newAPIKeyClaimRepo(t *testing.T) (*APIKeyClaimRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewAPIKeyClaimRepository(db), mock
+}
+
+func TestAPIKeyClaimRepository_Claim(t *testing.T) {
+	repo, mock := newAPIKeyClaimRepo(t)
+	cols := []string{"id", "api_key_id", "user_id", "token_hash", "encrypted_key", "expires_at", "created_at"}
+	mock.ExpectQuery("DELETE FROM api_key_claims.*expires_at > NOW.*RETURNING").
+		WithArgs("hash-1", "user-1", false).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("claim-1", "key-1", "user-1", "hash-1", "sealed", time.Now().Add(time.Minute), time.Now()))
+	mock.ExpectQuery("DELETE FROM api_key_claims").
+		WithArgs("hash-1", "user-1", false).
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	c, err := repo.Claim(context.Background(), "hash-1", "user-1", false)
+	if err != nil || c == nil || c.APIKeyID != "key-1" || c.EncryptedKey != "sealed" {
+		t.Fatalf("Claim = %+v, %v", c, err)
+	}
+	c, err = repo.Claim(context.Background(), "hash-1", "user-1", false)
+	if err != nil || c != nil {
+		t.Fatalf("second Claim = %+v, %v; want nil, nil", c, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAPIKeyClaimRepository_ListExpired(t *testing.T) {
+	repo, mock := newAPIKeyClaimRepo(t)
+	cols := []string{"id", "api_key_id", "user_id", "expires_at", "created_at"}
+	mock.ExpectQuery("FROM api_key_claims WHERE expires_at <= NOW").
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("claim-1", "key-1", "user-1", time.Now(), time.Now()).
+			AddRow("claim-2", "key-2", nil, time.Now(), time.Now()))
+
+	claims, err := repo.ListExpired(context.Background())
+	if err != nil || len(claims) != 2 || claims[1].APIKeyID != "key-2" || claims[1].UserID != nil {
+		t.Fatalf("ListExpired = %+v, %v", claims, err)
+	}
+}
@@ -0,0 +1,194 @@
+// Package repositories - artifact_immutability_repository.go persists the
+// per-organization artifact immutability settings and the hash-chained
+// publish log, and resolves storage keys to the versions that own them.
+//
+// Both tables are feature tables on the registry's own connection, like the
+// module and provider tables the key lookups join.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ArtifactImmutabilityRepository handles artifact immutability database
+// operations.
+type ArtifactImmutabilityRepository struct {
+	db *sql.DB
+}
+
+// NewArtifactImmutabilityRepository creates a new artifact immutability
+// repository.
+func NewArtifactImmutabilityRepository(db *sql.DB) *ArtifactImmutabilityRepository {
+	return &ArtifactImmutabilityRepository{db: db}
+}
+
+// StoredArtifact identifies the published version that owns a storage key.
+type StoredArtifact struct {
+	OrganizationID string
+	PublishedAt    time.Time
+}
+
+// GetOrgSetting returns an organization's setting, or nil when it has none.
+func (r *ArtifactImmutabilityRepository) GetOrgSetting(ctx context.Context, orgID string) (*models.OrgArtifactImmutability, error) {
+	s := &models.OrgArtifactImmutability{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT organization_id, enabled, retention_days, created_at, updated_at
+		FROM org_artifact_immutability WHERE organization_id = $1`, orgID,
+	).Scan(&s.OrganizationID, &s.Enabled, &s.RetentionDays, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get artifact immutability setting: %w", err)
+	}
+	return s, nil
+}
+
+// UpsertOrgSetting creates or replaces s.OrganizationID's setting and fills in
+// its timestamps.
+func (r *ArtifactImmutabilityRepository) UpsertOrgSetting(ctx context.Context, s *models.OrgArtifactImmutability) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO org_artifact_immutability (organization_id, enabled, retention_days)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			enabled        = EXCLUDED.enabled,
+			retention_days = EXCLUDED.retention_days,
+			updated_at     = NOW()
+		RETURNING created_at, updated_at`,
+		s.OrganizationID, s.Enabled, s.RetentionDays,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert artifact immutability setting: %w", err)
+	}
+	return nil
+}
+
+// AnyOrgEnabled reports whether any organization has immutability enabled.
+func (r *ArtifactImmutabilityRepository) AnyOrgEnabled(ctx context.Context) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM org_artifact_immutability WHERE enabled)`,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check artifact immutability settings: %w", err)
+	}
+	return exists, nil
+}
+
+// FindArtifactByStorageKey returns the version that owns key: a module
+// version's archive, a provider platform's archive, or a provider version's
+// SHA256SUMS file or signature. It returns nil when no version owns key.
+func (r *ArtifactImmutabilityRepository) FindArtifactByStorageKey(ctx context.Context, key string) (*StoredArtifact, error) {
+	query := `
+		SELECT COALESCE(m.organization_id::text, ''), mv.created_at
+		FROM module_versions mv
+		JOIN modules m ON m.id = mv.module_id
+		WHERE mv.storage_path = $1
+		UNION ALL
+		SELECT COALESCE(p.organization_id::text, ''), pv.created_at
+		FROM provider_platforms pp
+		JOIN provider_versions pv ON pv.id = pp.provider_version_id
+		JOIN providers p ON p.id = pv.provider_id
+		WHERE pp.storage_path = $1
+		UNION ALL
+		SELECT COALESCE(p.organization_id::text, ''), pv.created_at
+		FROM provider_versions pv
+		JOIN providers p ON p.id = pv.provider_id
+		WHERE pv.shasum_storage_key = $1 OR pv.shasum_signature_storage_key = $1
+		LIMIT 1`
+
+	a := &StoredArtifact{}
+	if err := r.db.QueryRowContext(ctx, query, key).Scan(&a.OrganizationID, &a.PublishedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to look up storage key: %w", err)
+	}
+	return a, nil
+}
+
+// AppendPublishLog appends e to the publish log. It sets e.PrevHash to the
+// hash of the latest entry, computes e.EntryHash, and fills in e.ID.
+// Appends are serialized so concurrent publishes cannot fork the chain.
+func (r *ArtifactImmutabilityRepository) AppendPublishLog(ctx context.Context, e *models.PublishLogEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	// EXCLUSIVE blocks other appends until commit but not readers, so each
+	// entry chains to the one committed before it.
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE artifact_publish_log IN EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock publish log: %w", err)
+	}
+
+	e.PrevHash = models.PublishLogGenesisHash
+	err = tx.QueryRowContext(ctx, `SELECT entry_hash FROM artifact_publish_log ORDER BY id DESC LIMIT 1`).Scan(&e.PrevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read publish log head: %w", err)
+	}
+	e.PublishedAt = e.PublishedAt.UTC().Truncate(time.Microsecond)
+	e.EntryHash = e.ComputeHash()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO artifact_publish_log
+			(artifact_type, organization_id, namespace, name, target, version,
+			 storage_key, checksum, published_by, published_at, prev_hash, entry_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id`,
+		e.ArtifactType, e.OrganizationID, e.Namespace, e.Name, e.Target, e.Version,
+		e.StorageKey, e.Checksum, e.PublishedBy, e.PublishedAt, e.PrevHash, e.EntryHash,
+	).Scan(&e.ID)
+	if err != nil {
+		return fmt.Errorf("failed to append publish log entry: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit publish log entry: %w", err)
+	}
+	return nil
+}
+
+// ListPublishLog returns up to limit publish log entries with IDs greater
+// than afterID, in ID order.
+func (r *ArtifactImmutabilityRepository) ListPublishLog(ctx context.Context, afterID int64, limit int) ([]*models.PublishLogEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, artifact_type, organization_id, namespace, name, target, version,
+		       storage_key, checksum, published_by, published_at, prev_hash, entry_hash
+		FROM artifact_publish_log
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list publish log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.PublishLogEntry
+	for rows.Next() {
+		e := &models.PublishLogEntry{}
+		var orgID, publishedBy sql.NullString
+		if err := rows.Scan(
+			&e.ID, &e.ArtifactType, &orgID, &e.Namespace, &e.Name, &e.Target, &e.Version,
+			&e.StorageKey, &e.Checksum, &publishedBy, &e.PublishedAt, &e.PrevHash, &e.EntryHash,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan publish log entry: %w", err)
+		}
+		if orgID.Valid {
+			e.OrganizationID = &orgID.String
+		}
+		if publishedBy.Valid {
+			e.PublishedBy = &publishedBy.String
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate publish log: %w", err)
+	}
+	return entries, nil
+}
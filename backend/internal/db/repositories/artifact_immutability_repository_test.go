@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newArtifactImmutabilityRepo(t *testing.T) (*ArtifactImmutabilityRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewArtifactImmutabilityRepository(db), mock
+}
+
+func TestArtifactImmutability_GetOrgSetting(t *testing.T) {
+	repo, mock := newArtifactImmutabilityRepo(t)
+	cols := []string{"organization_id", "enabled", "retention_days", "created_at", "updated_at"}
+	mock.ExpectQuery("SELECT.*FROM org_artifact_immutability WHERE organization_id").
+		WithArgs("org-1").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("org-1", true, 30, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM org_artifact_immutability").
+		WillReturnError(sql.ErrNoRows)
+
+	s, err := repo.GetOrgSetting(context.Background(), "org-1")
+	if err != nil || s == nil || !s.Enabled || s.RetentionDays != 30 {
+		t.Fatalf("GetOrgSetting = %+v, %v", s, err)
+	}
+	s, err = repo.GetOrgSetting(context.Background(), "org-2")
+	if err != nil || s != nil {
+		t.Fatalf("GetOrgSetting(missing) = %+v, %v; want nil, nil", s, err)
+	}
+}
+
+func TestArtifactImmutability_FindArtifactByStorageKey(t *testing.T) {
+	repo, mock := newArtifactImmutabilityRepo(t)
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectQuery("FROM module_versions.*UNION ALL.*FROM provider_platforms.*UNION ALL.*shasum_storage_key").
+		WithArgs("modules/a.tar.gz").
+		WillReturnRows(sqlmock.NewRows([]string{"org", "created_at"}).AddRow("org-1", published))
+	mock.ExpectQuery("FROM module_versions").
+		WithArgs("tmp/upload").
+		WillReturnRows(sqlmock.NewRows([]string{"org", "created_at"}))
+	mock.ExpectQuery("FROM module_versions").WillReturnError(errDB)
+
+	a, err := repo.FindArtifactByStorageKey(context.Background(), "modules/a.tar.gz")
+	if err != nil || a == nil || a.OrganizationID != "org-1" || !a.PublishedAt.Equal(published) {
+		t.Fatalf("FindArtifactByStorageKey = %+v, %v", a, err)
+	}
+	a, err = repo.FindArtifactByStorageKey(context.Background(), "tmp/upload")
+	if err != nil || a != nil {
+		t.Fatalf("FindArtifactByStorageKey(unowned) = %+v, %v; want nil, nil", a, err)
+	}
+	if _, err := repo.FindArtifactByStorageKey(context.Background(), "x"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestArtifactImmutability_AppendPublishLog(t *testing.T) {
+	repo, mock := newArtifactImmutabilityRepo(t)
+	head := "ab" + models.PublishLogGenesisHash[2:]
+
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE artifact_publish_log").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT entry_hash FROM artifact_publish_log").
+		WillReturnRows(sqlmock.NewRows([]string{"entry_hash"}).AddRow(head))
+	mock.ExpectQuery("INSERT INTO artifact_publish_log").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+	mock.ExpectCommit()
+
+	e := &models.PublishLogEntry{
+		ArtifactType: models.PublishLogArtifactModule,
+		Namespace:    "hashicorp", Name: "vpc", Target: "aws", Version: "1.0.0",
+		PublishedAt: time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC),
+	}
+	if err := repo.AppendPublishLog(context.Background(), e); err != nil {
+		t.Fatalf("AppendPublishLog: %v", err)
+	}
+	if e.ID != 7 || e.PrevHash != head {
+		t.Errorf("entry = %+v, want id 7 chained to the head", e)
+	}
+	if e.EntryHash != e.ComputeHash() || e.PublishedAt.Nanosecond()%1000 != 0 {
+		t.Errorf("entry hash or timestamp precision not as stored: %+v", e)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestArtifactImmutability_AppendPublishLog_Genesis(t *testing.T) {
+	repo, mock := newArtifactImmutabilityRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT entry_hash").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("INSERT INTO artifact_publish_log").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectCommit()
+
+	e := &models.PublishLogEntry{ArtifactType: models.PublishLogArtifactProvider, PublishedAt: time.Now()}
+	if err := repo.AppendPublishLog(context.Background(), e); err != nil {
+		t.Fatalf("AppendPublishLog: %v", err)
+	}
+	if e.PrevHash != models.PublishLogGenesisHash {
+		t.Errorf("PrevHash = %q, want the genesis hash", e.PrevHash)
+	}
+}
+
+func TestArtifactImmutability_ListPublishLog(t *testing.T) {
+	repo, mock := newArtifactImmutabilityRepo(t)
+	cols := []string{"id", "artifact_type", "organization_id", "namespace", "name", "target", "version",
+		"storage_key", "checksum", "published_by", "published_at", "prev_hash", "entry_hash"}
+	mock.ExpectQuery("FROM artifact_publish_log.*WHERE id > ").
+		WithArgs(int64(5), 2).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(int64(6), "module", "org-1", "ns", "n", "aws", "1.0.0", "k", "c", nil, time.Now(), "p", "h").
+			AddRow(int64(7), "provider", nil, "ns", "n", "linux_amd64", "2.0.0", "k2", "c2", "user-1", time.Now(), "h", "h2"))
+
+	entries, err := repo.ListPublishLog(context.Background(), 5, 2)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ListPublishLog = %v, %v", entries, err)
+	}
+	if entries[0].OrganizationID == nil || *entries[0].OrganizationID != "org-1" || entries[0].PublishedBy != nil {
+		t.Errorf("entries[0] = %+v", entries[0])
+	}
+	if entries[1].OrganizationID != nil || entries[1].PublishedBy == nil || *entries[1].PublishedBy != "user-1" {
+		t.Errorf("entries[1] = %+v", entries[1])
+	}
+}
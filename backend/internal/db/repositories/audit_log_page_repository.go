@@ -0,0 +1,98 @@
+// audit_log_page_repository.go pages through the audit log by keyset, which
+// the shared identity store's AuditRepository (offset pages with a full
+// count) does not offer. The filters are the same.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// AuditLogPageRepository lists audit log entries by keyset.
+type AuditLogPageRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogPageRepository creates a new AuditLogPageRepository.
+func NewAuditLogPageRepository(db *sql.DB) *AuditLogPageRepository {
+	return &AuditLogPageRepository{db: db}
+}
+
+// likeEscaper escapes LIKE wildcards in a user-supplied substring.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListAfter returns up to limit audit log entries matching filters, newest
+// first, starting after the entry at after (from the newest when after is
+// nil). Entries are enriched with the actor's email and name, as by
+// AuditRepository.ListAuditLogs. No total is counted.
+func (r *AuditLogPageRepository) ListAfter(ctx context.Context, filters AuditFilters, after *Keyset, limit int) ([]*models.AuditLog, error) {
+	var wb whereBuilder
+	if filters.UserID != nil {
+		wb.add("al.user_id = $%d", *filters.UserID)
+	}
+	if filters.UserEmail != nil {
+		wb.add("u.email ILIKE $%d", "%"+likeEscaper.Replace(*filters.UserEmail)+"%")
+	}
+	if filters.OrganizationID != nil {
+		wb.add("al.organization_id = $%d", *filters.OrganizationID)
+	}
+	if filters.Action != nil {
+		wb.add("al.action = $%d", *filters.Action)
+	}
+	if filters.ResourceType != nil {
+		wb.add("al.resource_type = $%d", *filters.ResourceType)
+	}
+	if filters.StartDate != nil {
+		wb.add("al.created_at >= $%d", *filters.StartDate)
+	}
+	if filters.EndDate != nil {
+		wb.add("al.created_at <= $%d", *filters.EndDate)
+	}
+	if after != nil {
+		wb.addKeyset("al.created_at", "al.id", *after)
+	}
+	where, args := wb.clause()
+
+	// #nosec G201 -- where holds only fixed conditions; values are bound args
+	query := fmt.Sprintf(`
+		SELECT al.id, al.user_id, al.organization_id, al.action, al.resource_type, al.resource_id,
+		       al.metadata, al.ip_address, al.created_at,
+		       u.email AS user_email, u.name AS user_name
+		FROM audit_logs al
+		LEFT JOIN users u ON al.user_id = u.id
+		%s
+		ORDER BY al.created_at DESC, al.id DESC
+		LIMIT $%d
+	`, where, wb.nextPlaceholder())
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := make([]*models.AuditLog, 0)
+	for rows.Next() {
+		log := &models.AuditLog{}
+		var metadataJSON []byte
+		if err := rows.Scan(&log.ID, &log.UserID, &log.OrganizationID, &log.Action, &log.ResourceType, &log.ResourceID,
+			&metadataJSON, &log.IPAddress, &log.CreatedAt, &log.UserEmail, &log.UserName); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if metadataJSON != nil {
+			if err := json.Unmarshal(metadataJSON, &log.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal audit log metadata: %w", err)
+			}
+		}
+		logs = append(logs, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit logs: %w", err)
+	}
+	return logs, nil
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+var auditLogPageCols = []string{
+	"id", "user_id", "organization_id", "action", "resource_type", "resource_id",
+	"metadata", "ip_address", "created_at", "user_email", "user_name",
+}
+
+func newAuditLogPageRepo(t *testing.T) (*AuditLogPageRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewAuditLogPageRepository(db), mock
+}
+
+func TestAuditLogPageRepository_ListAfter(t *testing.T) {
+	repo, mock := newAuditLogPageRepo(t)
+	after := Keyset{CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), ID: "3f2a8c3e-6c1d-4d59-9a55-1d5e7a8d4b21"}
+	action := "user.login"
+	email := "50%_off"
+
+	mock.ExpectQuery(`WHERE u\.email ILIKE \$1 AND al\.action = \$2 AND \(al\.created_at, al\.id\) < \(\$3, \$4::uuid\)\s+ORDER BY al\.created_at DESC, al\.id DESC\s+LIMIT \$5`).
+		WithArgs(`%50\%\_off%`, action, after.CreatedAt, after.ID, 26).
+		WillReturnRows(sqlmock.NewRows(auditLogPageCols).
+			AddRow("a-1", nil, nil, action, nil, nil, []byte(`{"k":"v"}`), nil, time.Now(), nil, nil))
+
+	logs, err := repo.ListAfter(context.Background(), AuditFilters{UserEmail: &email, Action: &action}, &after, 26)
+	if err != nil || len(logs) != 1 || logs[0].Metadata["k"] != "v" {
+		t.Fatalf("ListAfter = %+v, %v", logs, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAuditLogPageRepository_ListAfterFirstPage(t *testing.T) {
+	repo, mock := newAuditLogPageRepo(t)
+	mock.ExpectQuery(`FROM audit_logs al\s+LEFT JOIN users u ON al\.user_id = u\.id\s+ORDER BY al\.created_at DESC, al\.id DESC\s+LIMIT \$1`).
+		WithArgs(11).
+		WillReturnError(errDB)
+
+	if _, err := repo.ListAfter(context.Background(), AuditFilters{}, nil, 11); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,151 @@
+// Package repositories - ci_trust_rule_repository.go persists the trust rules
+// for the CI OIDC token exchange.
+//
+// ci_trust_rules is a feature table on the registry's own connection; the
+// organizations it refers to live on the identity connection, so callers
+// validate organization IDs through the identity repositories.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// CITrustRuleRepository handles CI trust rule database operations.
+type CITrustRuleRepository struct {
+	db *sql.DB
+}
+
+// NewCITrustRuleRepository creates a new CI trust rule repository.
+func NewCITrustRuleRepository(db *sql.DB) *CITrustRuleRepository {
+	return &CITrustRuleRepository{db: db}
+}
+
+const ciTrustRuleColumns = `id, organization_id, namespace, issuer, repository, ref_pattern, environment,
+	description, enabled, created_by, last_used_at, created_at, updated_at`
+
+func scanCITrustRule(row interface{ Scan(...any) error }) (*models.CITrustRule, error) {
+	r := &models.CITrustRule{}
+	if err := row.Scan(
+		&r.ID, &r.OrganizationID, &r.Namespace, &r.Issuer, &r.Repository, &r.RefPattern, &r.Environment,
+		&r.Description, &r.Enabled, &r.CreatedBy, &r.LastUsedAt, &r.CreatedAt, &r.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *CITrustRuleRepository) list(ctx context.Context, query string, args ...any) ([]*models.CITrustRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ci trust rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*models.CITrustRule{}
+	for rows.Next() {
+		rule, err := scanCITrustRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ci trust rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ci trust rules: %w", err)
+	}
+	return rules, nil
+}
+
+// List returns every rule, optionally filtered to one namespace ("" = all).
+func (r *CITrustRuleRepository) List(ctx context.Context, namespace string) ([]*models.CITrustRule, error) {
+	if namespace == "" {
+		return r.list(ctx, `SELECT `+ciTrustRuleColumns+` FROM ci_trust_rules ORDER BY namespace, repository, created_at`)
+	}
+	return r.list(ctx, `SELECT `+ciTrustRuleColumns+` FROM ci_trust_rules WHERE namespace = $1 ORDER BY repository, created_at`, namespace)
+}
+
+// ListEnabledForNamespace returns the enabled rules of a namespace for a
+// given issuer, the candidate set matched at token exchange.
+func (r *CITrustRuleRepository) ListEnabledForNamespace(ctx context.Context, namespace, issuer string) ([]*models.CITrustRule, error) {
+	return r.list(ctx, `SELECT `+ciTrustRuleColumns+` FROM ci_trust_rules
+		WHERE namespace = $1 AND issuer = $2 AND enabled = true
+		ORDER BY created_at`, namespace, issuer)
+}
+
+// GetByID returns a rule, or nil when it does not exist.
+func (r *CITrustRuleRepository) GetByID(ctx context.Context, id string) (*models.CITrustRule, error) {
+	rule, err := scanCITrustRule(r.db.QueryRowContext(ctx,
+		`SELECT `+ciTrustRuleColumns+` FROM ci_trust_rules WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ci trust rule: %w", err)
+	}
+	return rule, nil
+}
+
+// Create inserts a rule and fills in its ID and timestamps.
+func (r *CITrustRuleRepository) Create(ctx context.Context, rule *models.CITrustRule) error {
+	query := `
+		INSERT INTO ci_trust_rules
+			(organization_id, namespace, issuer, repository, ref_pattern, environment, description, enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		rule.OrganizationID, rule.Namespace, rule.Issuer, rule.Repository, rule.RefPattern,
+		rule.Environment, rule.Description, rule.Enabled, rule.CreatedBy,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create ci trust rule: %w", err)
+	}
+	return nil
+}
+
+// Update replaces a rule's matching fields and refreshes updated_at, filling
+// in the server-maintained columns. It reports whether the rule existed.
+func (r *CITrustRuleRepository) Update(ctx context.Context, rule *models.CITrustRule) (bool, error) {
+	query := `
+		UPDATE ci_trust_rules
+		SET organization_id = $2, namespace = $3, issuer = $4, repository = $5, ref_pattern = $6,
+		    environment = $7, description = $8, enabled = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_by, last_used_at, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		rule.ID, rule.OrganizationID, rule.Namespace, rule.Issuer, rule.Repository, rule.RefPattern,
+		rule.Environment, rule.Description, rule.Enabled,
+	).Scan(&rule.CreatedBy, &rule.LastUsedAt, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to update ci trust rule: %w", err)
+	}
+	return true, nil
+}
+
+// Delete removes a rule. It reports whether the rule existed.
+func (r *CITrustRuleRepository) Delete(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM ci_trust_rules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete ci trust rule: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete ci trust rule: %w", err)
+	}
+	return n > 0, nil
+}
+
+// TouchLastUsed records that a rule authorized a token exchange.
+func (r *CITrustRuleRepository) TouchLastUsed(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE ci_trust_rules SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update ci trust rule last use: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var ciTrustRuleCols = []string{
+	"id", "organization_id", "namespace", "issuer", "repository", "ref_pattern", "environment",
+	"description", "enabled", "created_by", "last_used_at", "created_at", "updated_at",
+}
+
+const testGHAIssuer = "https://token.actions.githubusercontent.com"
+
+func newCITrustRuleRepo(t *testing.T) (*CITrustRuleRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewCITrustRuleRepository(db), mock
+}
+
+func TestCITrustRule_ListEnabledForNamespace(t *testing.T) {
+	repo, mock := newCITrustRuleRepo(t)
+	mock.ExpectQuery("SELECT.*FROM ci_trust_rules.*namespace = \\$1 AND issuer = \\$2 AND enabled = true").
+		WithArgs("acme", testGHAIssuer).
+		WillReturnRows(sqlmock.NewRows(ciTrustRuleCols).
+			AddRow("rule-1", "org-1", "acme", testGHAIssuer, "acme/vpc", "refs/tags/v*", nil, nil, true, nil, nil, time.Now(), time.Now()))
+
+	rules, err := repo.ListEnabledForNamespace(context.Background(), "acme", testGHAIssuer)
+	if err != nil {
+		t.Fatalf("ListEnabledForNamespace: %v", err)
+	}
+	if len(rules) != 1 || rules[0].RefPattern == nil || *rules[0].RefPattern != "refs/tags/v*" || rules[0].Environment != nil {
+		t.Errorf("rules = %+v", rules)
+	}
+}
+
+func TestCITrustRule_List_EmptyIsSlice(t *testing.T) {
+	repo, mock := newCITrustRuleRepo(t)
+	mock.ExpectQuery("SELECT.*FROM ci_trust_rules ORDER BY").
+		WillReturnRows(sqlmock.NewRows(ciTrustRuleCols))
+
+	rules, err := repo.List(context.Background(), "")
+	if err != nil || rules == nil || len(rules) != 0 {
+		t.Fatalf("List = %v, %v; want empty slice", rules, err)
+	}
+}
+
+func TestCITrustRule_GetByID_NotFound(t *testing.T) {
+	repo, mock := newCITrustRuleRepo(t)
+	mock.ExpectQuery("SELECT.*FROM ci_trust_rules WHERE id").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	rule, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || rule != nil {
+		t.Fatalf("GetByID = %+v, %v; want nil, nil", rule, err)
+	}
+}
+
+func TestCITrustRule_CreateUpdateDelete(t *testing.T) {
+	repo, mock := newCITrustRuleRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO ci_trust_rules").
+		WithArgs("org-1", "acme", testGHAIssuer, "acme/vpc", nil, nil, nil, true, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("rule-1", now, now))
+	mock.ExpectQuery("UPDATE ci_trust_rules").
+		WillReturnRows(sqlmock.NewRows([]string{"created_by", "last_used_at", "created_at", "updated_at"}).AddRow(nil, nil, now, now))
+	mock.ExpectQuery("UPDATE ci_trust_rules").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("DELETE FROM ci_trust_rules").
+		WithArgs("rule-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rule := &models.CITrustRule{OrganizationID: "org-1", Namespace: "acme", Issuer: testGHAIssuer, Repository: "acme/vpc", Enabled: true}
+	if err := repo.Create(context.Background(), rule); err != nil || rule.ID != "rule-1" {
+		t.Fatalf("Create: %v (id %q)", err, rule.ID)
+	}
+	if found, err := repo.Update(context.Background(), rule); err != nil || !found {
+		t.Fatalf("Update = %v, %v", found, err)
+	}
+	if found, err := repo.Update(context.Background(), &models.CITrustRule{ID: "gone"}); err != nil || found {
+		t.Fatalf("Update(missing) = %v, %v; want false, nil", found, err)
+	}
+	if deleted, err := repo.Delete(context.Background(), "rule-1"); err != nil || !deleted {
+		t.Fatalf("Delete = %v, %v", deleted, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Package repositories - cli_token_repository.go records the tokens issued to
+// the Terraform CLI so their owners can list and revoke them.
+//
+// cli_tokens is a feature table on the registry's own connection. Revoking a
+// token here only marks the record; callers also add its JTI to the shared
+// denylist (TokenRepository), which is what the auth middleware checks.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// CLITokenRepository handles CLI token database operations.
+type CLITokenRepository struct {
+	db *sql.DB
+}
+
+// NewCLITokenRepository creates a new CLI token repository.
+func NewCLITokenRepository(db *sql.DB) *CLITokenRepository {
+	return &CLITokenRepository{db: db}
+}
+
+const cliTokenColumns = `id, user_id, description, scopes, created_at, expires_at, revoked_at`
+
+func scanCLIToken(row interface{ Scan(...any) error }) (*models.CLIToken, error) {
+	t := &models.CLIToken{}
+	var scopes []byte
+	if err := row.Scan(&t.ID, &t.UserID, &t.Description, &scopes, &t.CreatedAt, &t.ExpiresAt, &t.RevokedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopes, &t.Scopes); err != nil {
+		return nil, fmt.Errorf("invalid cli token scopes: %w", err)
+	}
+	if t.Scopes == nil {
+		t.Scopes = []string{}
+	}
+	return t, nil
+}
+
+// Create records an issued token; ID, UserID, Scopes and ExpiresAt must be
+// set. CreatedAt is filled in.
+func (r *CLITokenRepository) Create(ctx context.Context, t *models.CLIToken) error {
+	scopes, err := json.Marshal(t.Scopes)
+	if err != nil {
+		return fmt.Errorf("failed to encode cli token scopes: %w", err)
+	}
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO cli_tokens (id, user_id, description, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at`,
+		t.ID, t.UserID, t.Description, scopes, t.ExpiresAt,
+	).Scan(&t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create cli token: %w", err)
+	}
+	return nil
+}
+
+// ListByUser returns a user's tokens, newest first. Expired tokens are kept
+// out unless includeExpired is set.
+func (r *CLITokenRepository) ListByUser(ctx context.Context, userID string, includeExpired bool) ([]*models.CLIToken, error) {
+	query := `SELECT ` + cliTokenColumns + ` FROM cli_tokens WHERE user_id = $1`
+	if !includeExpired {
+		query += ` AND expires_at > NOW()`
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cli tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*models.CLIToken{}
+	for rows.Next() {
+		t, err := scanCLIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cli token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate cli tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// GetForUser returns one of a user's tokens, or nil when the user has no
+// token with that ID.
+func (r *CLITokenRepository) GetForUser(ctx context.Context, userID, id string) (*models.CLIToken, error) {
+	t, err := scanCLIToken(r.db.QueryRowContext(ctx,
+		`SELECT `+cliTokenColumns+` FROM cli_tokens WHERE id = $1 AND user_id = $2`, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cli token: %w", err)
+	}
+	return t, nil
+}
+
+// MarkRevoked stamps revoked_at on a token that is not already revoked.
+func (r *CLITokenRepository) MarkRevoked(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE cli_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id); err != nil {
+		return fmt.Errorf("failed to revoke cli token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var cliTokenCols = []string{"id", "user_id", "description", "scopes", "created_at", "expires_at", "revoked_at"}
+
+func newCLITokenRepo(t *testing.T) (*CLITokenRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewCLITokenRepository(db), mock
+}
+
+func TestCLIToken_Create(t *testing.T) {
+	repo, mock := newCLITokenRepo(t)
+	expires := time.Now().Add(time.Hour)
+	mock.ExpectQuery("INSERT INTO cli_tokens").
+		WithArgs("jti-1", "user-1", nil, []byte(`["modules:read"]`), expires).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+
+	tok := &models.CLIToken{ID: "jti-1", UserID: "user-1", Scopes: []string{"modules:read"}, ExpiresAt: expires}
+	if err := repo.Create(context.Background(), tok); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if tok.CreatedAt.IsZero() {
+		t.Error("CreatedAt not filled in")
+	}
+}
+
+func TestCLIToken_ListByUser(t *testing.T) {
+	repo, mock := newCLITokenRepo(t)
+	mock.ExpectQuery("SELECT.*FROM cli_tokens WHERE user_id = \\$1 AND expires_at > NOW\\(\\) ORDER BY created_at DESC").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(cliTokenCols).
+			AddRow("jti-1", "user-1", "laptop", []byte(`["modules:read","providers:read"]`), time.Now(), time.Now().Add(time.Hour), nil))
+
+	tokens, err := repo.ListByUser(context.Background(), "user-1", false)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(tokens) != 1 || len(tokens[0].Scopes) != 2 || *tokens[0].Description != "laptop" {
+		t.Errorf("tokens = %+v", tokens)
+	}
+}
+
+func TestCLIToken_ListByUserIncludeExpired(t *testing.T) {
+	repo, mock := newCLITokenRepo(t)
+	mock.ExpectQuery("SELECT.*FROM cli_tokens WHERE user_id = \\$1 ORDER BY created_at DESC").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(cliTokenCols))
+
+	tokens, err := repo.ListByUser(context.Background(), "user-1", true)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if tokens == nil || len(tokens) != 0 {
+		t.Errorf("tokens = %v, want empty non-nil slice", tokens)
+	}
+}
+
+func TestCLIToken_GetForUserNotFound(t *testing.T) {
+	repo, mock := newCLITokenRepo(t)
+	mock.ExpectQuery("SELECT.*FROM cli_tokens WHERE id = \\$1 AND user_id = \\$2").
+		WithArgs("jti-1", "user-2").
+		WillReturnRows(sqlmock.NewRows(cliTokenCols))
+
+	tok, err := repo.GetForUser(context.Background(), "user-2", "jti-1")
+	if err != nil || tok != nil {
+		t.Fatalf("GetForUser = (%v, %v), want (nil, nil)", tok, err)
+	}
+}
+
+func TestCLIToken_MarkRevoked(t *testing.T) {
+	repo, mock := newCLITokenRepo(t)
+	mock.ExpectExec("UPDATE cli_tokens SET revoked_at = NOW\\(\\) WHERE id = \\$1 AND revoked_at IS NULL").
+		WithArgs("jti-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.MarkRevoked(context.Background(), "jti-1"); err != nil {
+		t.Fatalf("MarkRevoked: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
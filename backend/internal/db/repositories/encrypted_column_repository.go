@@ -0,0 +1,100 @@
+// encrypted_column_repository.go implements EncryptedColumnRepository, which
+// reads and rewrites the columns holding values sealed with the ENCRYPTION_KEY
+// token cipher, for re-encryption after a key rotation.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// EncryptedColumn names a column holding TokenCipher ciphertext and the
+// column identifying its rows.
+type EncryptedColumn struct {
+	Table    string
+	IDColumn string
+	Column   string
+}
+
+// String returns "table.column".
+func (c EncryptedColumn) String() string {
+	return c.Table + "." + c.Column
+}
+
+// EncryptedColumns lists every column sealed with the token cipher. Secrets
+// stored inside JSON settings (the LDAP bind password, the SMTP password) are
+// not listed; they are re-sealed whenever those settings are saved.
+var EncryptedColumns = []EncryptedColumn{
+	{"scm_providers", "id", "client_secret_encrypted"},
+	{"scm_providers", "id", "encrypted_app_private_key"},
+	{"scm_oauth_tokens", "id", "access_token_encrypted"},
+	{"scm_oauth_tokens", "id", "refresh_token_encrypted"},
+	{"scm_provider_tokens", "scm_provider_id", "access_token_encrypted"},
+	{"storage_config", "id", "azure_account_key_encrypted"},
+	{"storage_config", "id", "s3_access_key_id_encrypted"},
+	{"storage_config", "id", "s3_secret_access_key_encrypted"},
+	{"storage_config", "id", "gcs_credentials_json_encrypted"},
+	{"storage_config", "id", "artifactory_access_token_encrypted"},
+	{"oidc_config", "id", "client_secret_encrypted"},
+	{"notification_channels", "id", "encrypted_target"},
+	{"publish_hooks", "organization_id", "encrypted_secret"},
+	{"org_event_webhooks", "id", "encrypted_secret"},
+	{"api_key_claims", "id", "encrypted_key"},
+}
+
+// EncryptedValue is one stored ciphertext and the ID of its row.
+type EncryptedValue struct {
+	ID         string
+	Ciphertext string
+}
+
+// EncryptedColumnRepository reads and updates the columns in EncryptedColumns.
+type EncryptedColumnRepository struct {
+	db *sql.DB
+}
+
+// NewEncryptedColumnRepository creates a new encrypted column repository
+func NewEncryptedColumnRepository(db *sql.DB) *EncryptedColumnRepository {
+	return &EncryptedColumnRepository{db: db}
+}
+
+// ListValues returns every non-empty value of col, ordered by row ID.
+func (r *EncryptedColumnRepository) ListValues(ctx context.Context, col EncryptedColumn) ([]EncryptedValue, error) {
+	query := fmt.Sprintf(`SELECT %s::text, %s FROM %s WHERE %s IS NOT NULL AND %s <> '' ORDER BY %s`,
+		col.IDColumn, col.Column, col.Table, col.Column, col.Column, col.IDColumn)
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", col, err)
+	}
+	var values []EncryptedValue
+	for rows.Next() {
+		var v EncryptedValue
+		if err := rows.Scan(&v.ID, &v.Ciphertext); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan %s: %w", col, err)
+		}
+		values = append(values, v)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", col, err)
+	}
+	return values, nil
+}
+
+// UpdateValue replaces v's ciphertext with sealed. The update only applies
+// while the column still holds v.Ciphertext, so a secret changed since it was
+// listed is not overwritten; it reports whether the row was updated.
+func (r *EncryptedColumnRepository) UpdateValue(ctx context.Context, col EncryptedColumn, v EncryptedValue, sealed string) (bool, error) {
+	query := fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE %s::text = $2 AND %s = $3`,
+		col.Table, col.Column, col.IDColumn, col.Column)
+	res, err := r.db.ExecContext(ctx, query, sealed, v.ID, v.Ciphertext)
+	if err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", col, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
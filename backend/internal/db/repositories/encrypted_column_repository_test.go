@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func newEncryptedColumnRepo(t *testing.T) (*EncryptedColumnRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewEncryptedColumnRepository(db), mock
+}
+
+var testEncryptedColumn = EncryptedColumn{"scm_provider_tokens", "scm_provider_id", "access_token_encrypted"}
+
+func TestEncryptedColumn_ListValues(t *testing.T) {
+	repo, mock := newEncryptedColumnRepo(t)
+	mock.ExpectQuery(`SELECT scm_provider_id::text, access_token_encrypted FROM scm_provider_tokens WHERE access_token_encrypted IS NOT NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "value"}).AddRow("p-1", "c1").AddRow("p-2", "c2"))
+
+	values, err := repo.ListValues(context.Background(), testEncryptedColumn)
+	if err != nil {
+		t.Fatalf("ListValues: %v", err)
+	}
+	if len(values) != 2 || values[0] != (EncryptedValue{"p-1", "c1"}) || values[1].ID != "p-2" {
+		t.Errorf("values = %+v", values)
+	}
+}
+
+func TestEncryptedColumn_ListValuesError(t *testing.T) {
+	repo, mock := newEncryptedColumnRepo(t)
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("boom"))
+
+	if _, err := repo.ListValues(context.Background(), testEncryptedColumn); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestEncryptedColumn_UpdateValue(t *testing.T) {
+	repo, mock := newEncryptedColumnRepo(t)
+	v := EncryptedValue{ID: "p-1", Ciphertext: "old"}
+	mock.ExpectExec(`UPDATE scm_provider_tokens SET access_token_encrypted = \$1 WHERE scm_provider_id::text = \$2 AND access_token_encrypted = \$3`).
+		WithArgs("new", "p-1", "old").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE scm_provider_tokens").
+		WithArgs("new", "p-1", "old").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	updated, err := repo.UpdateValue(context.Background(), testEncryptedColumn, v, "new")
+	if err != nil || !updated {
+		t.Fatalf("UpdateValue = %v, %v; want true, nil", updated, err)
+	}
+	updated, err = repo.UpdateValue(context.Background(), testEncryptedColumn, v, "new")
+	if err != nil || updated {
+		t.Fatalf("UpdateValue on changed row = %v, %v; want false, nil", updated, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
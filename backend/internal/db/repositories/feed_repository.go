@@ -0,0 +1,140 @@
+// Package repositories - feed_repository.go lists recently published module
+// and provider versions for the Atom feeds under /feeds.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// FeedFilter narrows a feed query. Empty fields match everything, so a filter
+// with only OrganizationID and Limit lists the organization's recent versions
+// across all namespaces.
+type FeedFilter struct {
+	OrganizationID string
+	Namespace      string
+	// Name is the module name, or the provider type.
+	Name string
+	// System is the module target system; ignored for providers.
+	System string
+	Limit  int
+}
+
+// FeedRepository reads recently published versions for the Atom feeds. Only
+// versions anonymous Terraform clients can install are returned: archived
+// module versions, mirrored provider versions pending approval or rejected,
+// and provider versions synced only by private mirrors are left out.
+type FeedRepository struct {
+	db *sql.DB
+}
+
+// NewFeedRepository creates a new feed repository.
+func NewFeedRepository(db *sql.DB) *FeedRepository {
+	return &FeedRepository{db: db}
+}
+
+// privateMirrorExclusionClause hides provider versions that only private
+// mirrors synced; they are served only to members of the mirrors'
+// organizations (see mirror.Visibility).
+const privateMirrorExclusionClause = `
+		AND (NOT EXISTS (
+		  SELECT 1 FROM mirrored_provider_versions mpv
+		  JOIN mirrored_providers mp ON mp.id = mpv.mirrored_provider_id
+		  JOIN mirror_configurations mc ON mc.id = mp.mirror_config_id
+		  WHERE mpv.provider_version_id = pv.id AND mc.private
+		) OR EXISTS (
+		  SELECT 1 FROM mirrored_provider_versions mpv
+		  JOIN mirrored_providers mp ON mp.id = mpv.mirrored_provider_id
+		  JOIN mirror_configurations mc ON mc.id = mp.mirror_config_id
+		  WHERE mpv.provider_version_id = pv.id AND NOT mc.private
+		))`
+
+// RecentModuleVersions returns up to f.Limit of the most recently published
+// module versions matching f, newest first.
+func (r *FeedRepository) RecentModuleVersions(ctx context.Context, f FeedFilter) ([]models.FeedVersion, error) {
+	var wb whereBuilder
+	wb.add("m.organization_id = $%d", f.OrganizationID)
+	if f.Namespace != "" {
+		wb.add("m.namespace = $%d", f.Namespace)
+	}
+	if f.Name != "" {
+		wb.add("m.name = $%d", f.Name)
+	}
+	if f.System != "" {
+		wb.add("m.system = $%d", f.System)
+	}
+	where, args := wb.clause()
+	query := fmt.Sprintf(`
+		SELECT m.namespace, m.name, m.system, mv.version, mv.created_at, mv.changelog
+		FROM module_versions mv
+		JOIN modules m ON m.id = mv.module_id
+		%s AND mv.archived_at IS NULL
+		ORDER BY mv.created_at DESC
+		LIMIT $%d
+	`, where, wb.nextPlaceholder())
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, f.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent module versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.FeedVersion
+	for rows.Next() {
+		v := models.FeedVersion{Kind: models.FeedKindModule}
+		if err := rows.Scan(&v.Namespace, &v.Name, &v.System, &v.Version, &v.PublishedAt, &v.Changelog); err != nil {
+			return nil, fmt.Errorf("failed to scan recent module version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recent module versions: %w", err)
+	}
+	return versions, nil
+}
+
+// RecentProviderVersions returns up to f.Limit of the most recently published
+// provider versions matching f, newest first. Providers stored without an
+// organization (single-tenant mirrors) belong to every organization, as in
+// ProviderRepository.GetProvider.
+func (r *FeedRepository) RecentProviderVersions(ctx context.Context, f FeedFilter) ([]models.FeedVersion, error) {
+	var wb whereBuilder
+	wb.add("(p.organization_id = $%d OR p.organization_id IS NULL)", f.OrganizationID)
+	if f.Namespace != "" {
+		wb.add("p.namespace = $%d", f.Namespace)
+	}
+	if f.Name != "" {
+		wb.add("p.type = $%d", f.Name)
+	}
+	where, args := wb.clause()
+	query := fmt.Sprintf(`
+		SELECT p.namespace, p.type, pv.version, pv.created_at
+		FROM provider_versions pv
+		JOIN providers p ON p.id = pv.provider_id
+		%s`+approvalExclusionClause+privateMirrorExclusionClause+`
+		ORDER BY pv.created_at DESC
+		LIMIT $%d
+	`, where, wb.nextPlaceholder())
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, f.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent provider versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.FeedVersion
+	for rows.Next() {
+		v := models.FeedVersion{Kind: models.FeedKindProvider}
+		if err := rows.Scan(&v.Namespace, &v.Name, &v.Version, &v.PublishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recent provider version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recent provider versions: %w", err)
+	}
+	return versions, nil
+}
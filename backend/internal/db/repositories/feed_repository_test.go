@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func TestFeedRepository_RecentModuleVersions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewFeedRepository(db)
+
+	at := time.Now()
+	mock.ExpectQuery(`FROM module_versions mv.*WHERE m.organization_id = \$1 AND m.namespace = \$2 AND m.name = \$3 AND m.system = \$4 AND mv.archived_at IS NULL.*LIMIT \$5`).
+		WithArgs("org-1", "acme", "vpc", "aws", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"namespace", "name", "system", "version", "created_at", "changelog"}).
+			AddRow("acme", "vpc", "aws", "1.2.0", at, "Fixed things").
+			AddRow("acme", "vpc", "aws", "1.1.0", at.Add(-time.Hour), nil))
+
+	got, err := repo.RecentModuleVersions(context.Background(), FeedFilter{
+		OrganizationID: "org-1", Namespace: "acme", Name: "vpc", System: "aws", Limit: 50,
+	})
+	if err != nil {
+		t.Fatalf("RecentModuleVersions: %v", err)
+	}
+	if len(got) != 2 || got[0].Kind != models.FeedKindModule || got[0].Changelog == nil || got[1].Changelog != nil {
+		t.Errorf("versions = %+v", got)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("db down"))
+	if _, err := repo.RecentModuleVersions(context.Background(), FeedFilter{OrganizationID: "org-1"}); err == nil {
+		t.Error("want error when the query fails")
+	}
+}
+
+func TestFeedRepository_RecentProviderVersions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewFeedRepository(db)
+
+	// Hidden: pending/rejected mirrored versions and private-mirror-only versions.
+	mock.ExpectQuery(`FROM provider_versions pv.*organization_id IS NULL\) AND p.namespace = \$2.*approval_status IN.*mc.private.*NOT mc.private.*LIMIT \$3`).
+		WithArgs("org-1", "acme", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"namespace", "type", "version", "created_at"}).
+			AddRow("acme", "cloud", "0.4.0", time.Now()))
+
+	got, err := repo.RecentProviderVersions(context.Background(), FeedFilter{OrganizationID: "org-1", Namespace: "acme", Limit: 50})
+	if err != nil {
+		t.Fatalf("RecentProviderVersions: %v", err)
+	}
+	if len(got) != 1 || got[0].Kind != models.FeedKindProvider || got[0].Name != "cloud" {
+		t.Errorf("versions = %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,190 @@
+// Package repositories - identity_link_repository.go persists pending requests
+// to link an SSO identity to an existing account, and links and unlinks
+// identities. Linking updates the identity users table and the registry's
+// identity_link_requests table in one transaction, so it runs on the identity
+// connection, where feature tables resolve through the search_path.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ErrIdentityLinkConflict is returned by Link when the account already has a
+// linked identity, or the identity is already linked to another account.
+var ErrIdentityLinkConflict = errors.New("identity link conflict")
+
+// IdentityLinkRepository handles identity link database operations.
+type IdentityLinkRepository struct {
+	db *sql.DB
+}
+
+// NewIdentityLinkRepository creates a new identity link repository.
+func NewIdentityLinkRepository(db *sql.DB) *IdentityLinkRepository {
+	return &IdentityLinkRepository{db: db}
+}
+
+const identityLinkRequestColumns = `id, user_id, oidc_sub, auth_provider, auth_issuer, email, name, token_hash, expires_at, created_at`
+
+func scanIdentityLinkRequest(row interface{ Scan(...any) error }) (*models.IdentityLinkRequest, error) {
+	req := &models.IdentityLinkRequest{}
+	if err := row.Scan(&req.ID, &req.UserID, &req.OIDCSub, &req.AuthProvider, &req.AuthIssuer,
+		&req.Email, &req.Name, &req.TokenHash, &req.ExpiresAt, &req.CreatedAt); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// CreateRequest stores a link request and fills in its ID and CreatedAt. A
+// pending request for the same account and identity is replaced, so only the
+// newest link token works. Expired requests are deleted first.
+func (r *IdentityLinkRepository) CreateRequest(ctx context.Context, req *models.IdentityLinkRequest) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM identity_link_requests WHERE expires_at <= NOW()`); err != nil {
+		return fmt.Errorf("failed to delete expired identity link requests: %w", err)
+	}
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO identity_link_requests
+			(user_id, oidc_sub, auth_provider, auth_issuer, email, name, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, oidc_sub) DO UPDATE
+		SET auth_provider = EXCLUDED.auth_provider,
+		    auth_issuer   = EXCLUDED.auth_issuer,
+		    email         = EXCLUDED.email,
+		    name          = EXCLUDED.name,
+		    token_hash    = EXCLUDED.token_hash,
+		    expires_at    = EXCLUDED.expires_at,
+		    created_at    = NOW()
+		RETURNING id, created_at`,
+		req.UserID, req.OIDCSub, req.AuthProvider, req.AuthIssuer, req.Email, req.Name, req.TokenHash, req.ExpiresAt,
+	).Scan(&req.ID, &req.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create identity link request: %w", err)
+	}
+	return nil
+}
+
+// GetRequestByTokenHash returns the unexpired request with tokenHash, or nil.
+func (r *IdentityLinkRepository) GetRequestByTokenHash(ctx context.Context, tokenHash string) (*models.IdentityLinkRequest, error) {
+	req, err := scanIdentityLinkRequest(r.db.QueryRowContext(ctx,
+		`SELECT `+identityLinkRequestColumns+` FROM identity_link_requests
+		WHERE token_hash = $1 AND expires_at > NOW()`, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get identity link request: %w", err)
+	}
+	return req, nil
+}
+
+// GetRequest returns the unexpired request id of userID, or nil.
+func (r *IdentityLinkRepository) GetRequest(ctx context.Context, userID, id string) (*models.IdentityLinkRequest, error) {
+	req, err := scanIdentityLinkRequest(r.db.QueryRowContext(ctx,
+		`SELECT `+identityLinkRequestColumns+` FROM identity_link_requests
+		WHERE id = $1 AND user_id = $2 AND expires_at > NOW()`, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get identity link request: %w", err)
+	}
+	return req, nil
+}
+
+// ListRequests returns the unexpired requests of userID, newest first.
+func (r *IdentityLinkRepository) ListRequests(ctx context.Context, userID string) ([]*models.IdentityLinkRequest, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+identityLinkRequestColumns+` FROM identity_link_requests
+		WHERE user_id = $1 AND expires_at > NOW()
+		ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identity link requests: %w", err)
+	}
+	defer rows.Close()
+
+	reqs := []*models.IdentityLinkRequest{}
+	for rows.Next() {
+		req, err := scanIdentityLinkRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan identity link request: %w", err)
+		}
+		reqs = append(reqs, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate identity link requests: %w", err)
+	}
+	return reqs, nil
+}
+
+// Link attaches the request's identity to its account and deletes the
+// account's requests. It is a compare-and-set: it returns
+// ErrIdentityLinkConflict, and links nothing, when the account already has a
+// linked identity or another account holds this one.
+func (r *IdentityLinkRepository) Link(ctx context.Context, req *models.IdentityLinkRequest) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin identity link: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users SET oidc_sub = $2, updated_at = NOW()
+		WHERE id = $1 AND oidc_sub IS NULL`, req.UserID, req.OIDCSub)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrIdentityLinkConflict
+		}
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	} else if n == 0 {
+		return ErrIdentityLinkConflict
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM identity_link_requests WHERE user_id = $1`, req.UserID); err != nil {
+		return fmt.Errorf("failed to delete identity link requests: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit identity link: %w", err)
+	}
+	return nil
+}
+
+// Unlink detaches the identity linked to userID and returns it, or "" when
+// the account has none.
+func (r *IdentityLinkRepository) Unlink(ctx context.Context, userID string) (string, error) {
+	var sub string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE users u SET oidc_sub = NULL, updated_at = NOW()
+		FROM (SELECT id, oidc_sub FROM users WHERE id = $1 FOR UPDATE) old
+		WHERE u.id = old.id AND old.oidc_sub IS NOT NULL
+		RETURNING old.oidc_sub`, userID).Scan(&sub)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to unlink identity: %w", err)
+	}
+	return sub, nil
+}
+
+// CountActiveAPIKeys returns the number of unexpired API keys of userID: the
+// ways left to authenticate as the account without a linked identity.
+func (r *IdentityLinkRepository) CountActiveAPIKeys(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM api_keys
+		WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW())`, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newIdentityLinkRepo(t *testing.T) (*IdentityLinkRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewIdentityLinkRepository(db), mock
+}
+
+var identityLinkRequestCols = []string{"id", "user_id", "oidc_sub", "auth_provider", "auth_issuer", "email", "name", "token_hash", "expires_at", "created_at"}
+
+func TestIdentityLinkRepository_CreateRequest(t *testing.T) {
+	repo, mock := newIdentityLinkRepo(t)
+	expires := time.Now().Add(time.Hour)
+	mock.ExpectExec("DELETE FROM identity_link_requests WHERE expires_at <= NOW").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO identity_link_requests.*ON CONFLICT \\(user_id, oidc_sub\\) DO UPDATE").
+		WithArgs("user-1", "sub-1", "oidc", "https://idp", "a@example.com", "A", "hash-1", expires).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("req-1", time.Now()))
+
+	req := &models.IdentityLinkRequest{
+		UserID: "user-1", OIDCSub: "sub-1", AuthProvider: "oidc", AuthIssuer: "https://idp",
+		Email: "a@example.com", Name: "A", TokenHash: "hash-1", ExpiresAt: expires,
+	}
+	if err := repo.CreateRequest(context.Background(), req); err != nil || req.ID != "req-1" {
+		t.Fatalf("CreateRequest = %v, ID %q", err, req.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIdentityLinkRepository_GetRequestByTokenHash(t *testing.T) {
+	repo, mock := newIdentityLinkRepo(t)
+	mock.ExpectQuery("FROM identity_link_requests.*token_hash = \\$1 AND expires_at > NOW").
+		WithArgs("hash-1").
+		WillReturnRows(sqlmock.NewRows(identityLinkRequestCols).
+			AddRow("req-1", "user-1", "sub-1", "oidc", "", "a@example.com", "A", "hash-1", time.Now(), time.Now()))
+	mock.ExpectQuery("FROM identity_link_requests").
+		WithArgs("hash-2").
+		WillReturnRows(sqlmock.NewRows(identityLinkRequestCols))
+
+	req, err := repo.GetRequestByTokenHash(context.Background(), "hash-1")
+	if err != nil || req == nil || req.UserID != "user-1" || req.OIDCSub != "sub-1" {
+		t.Fatalf("GetRequestByTokenHash = %+v, %v", req, err)
+	}
+	if req, err = repo.GetRequestByTokenHash(context.Background(), "hash-2"); err != nil || req != nil {
+		t.Fatalf("unknown token = %+v, %v; want nil, nil", req, err)
+	}
+}
+
+func TestIdentityLinkRepository_Link(t *testing.T) {
+	req := &models.IdentityLinkRequest{ID: "req-1", UserID: "user-1", OIDCSub: "sub-1"}
+
+	repo, mock := newIdentityLinkRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET oidc_sub = \\$2.*oidc_sub IS NULL").
+		WithArgs("user-1", "sub-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM identity_link_requests WHERE user_id = \\$1").
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := repo.Link(context.Background(), req); err != nil {
+		t.Fatalf("Link: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// The account was linked meanwhile.
+	repo, mock = newIdentityLinkRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if err := repo.Link(context.Background(), req); !errors.Is(err, ErrIdentityLinkConflict) {
+		t.Errorf("Link of a linked account = %v, want ErrIdentityLinkConflict", err)
+	}
+
+	// Another account holds the identity.
+	repo, mock = newIdentityLinkRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+	if err := repo.Link(context.Background(), req); !errors.Is(err, ErrIdentityLinkConflict) {
+		t.Errorf("Link of a taken identity = %v, want ErrIdentityLinkConflict", err)
+	}
+}
+
+func TestIdentityLinkRepository_Unlink(t *testing.T) {
+	repo, mock := newIdentityLinkRepo(t)
+	mock.ExpectQuery("UPDATE users u SET oidc_sub = NULL.*RETURNING old.oidc_sub").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"oidc_sub"}).AddRow("sub-1"))
+	mock.ExpectQuery("UPDATE users u SET oidc_sub = NULL").
+		WithArgs("user-2").
+		WillReturnRows(sqlmock.NewRows([]string{"oidc_sub"}))
+
+	if sub, err := repo.Unlink(context.Background(), "user-1"); err != nil || sub != "sub-1" {
+		t.Fatalf("Unlink = %q, %v", sub, err)
+	}
+	if sub, err := repo.Unlink(context.Background(), "user-2"); err != nil || sub != "" {
+		t.Fatalf("Unlink of an unlinked account = %q, %v; want \"\", nil", sub, err)
+	}
+}
@@ -0,0 +1,24 @@
+// keyset.go defines the position keyset-paginated listings continue from.
+package repositories
+
+import (
+	"fmt"
+	"time"
+)
+
+// Keyset is a position in a listing ordered newest first by (created_at, id):
+// the created_at and id of the last row of the previous page. Unlike OFFSET,
+// continuing from a keyset costs the same however deep the page, and rows
+// inserted meanwhile do not shift the next page.
+type Keyset struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// addKeyset appends the condition that selects the rows after k in a listing
+// ordered by createdCol DESC, idCol DESC. The id column must be a UUID.
+func (b *whereBuilder) addKeyset(createdCol, idCol string, k Keyset) {
+	n := len(b.args) + 1
+	b.conditions = append(b.conditions, fmt.Sprintf("(%s, %s) < ($%d, $%d::uuid)", createdCol, idCol, n, n+1))
+	b.args = append(b.args, k.CreatedAt, k.ID)
+}
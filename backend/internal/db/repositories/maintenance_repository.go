@@ -0,0 +1,61 @@
+// Package repositories - maintenance_repository.go persists the read-only
+// maintenance mode state in the system_settings singleton.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// MaintenanceRepository reads and writes the maintenance mode state.
+type MaintenanceRepository struct {
+	db *sql.DB
+}
+
+// NewMaintenanceRepository creates a new maintenance mode repository.
+func NewMaintenanceRepository(db *sql.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// Get returns the current maintenance mode state. A missing system_settings
+// row reads as maintenance disabled.
+func (r *MaintenanceRepository) Get(ctx context.Context) (*models.MaintenanceMode, error) {
+	m := &models.MaintenanceMode{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT maintenance_enabled, maintenance_message, maintenance_eta,
+		       maintenance_enabled_by::text, maintenance_enabled_at
+		FROM system_settings WHERE id = 1`).
+		Scan(&m.Enabled, &m.Message, &m.ETA, &m.EnabledBy, &m.EnabledAt)
+	if err == sql.ErrNoRows {
+		return &models.MaintenanceMode{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance mode: %w", err)
+	}
+	return m, nil
+}
+
+// Set stores m as the maintenance mode state. Disabling clears the message,
+// ETA and who enabled it.
+func (r *MaintenanceRepository) Set(ctx context.Context, m *models.MaintenanceMode) error {
+	if !m.Enabled {
+		m.Message, m.ETA, m.EnabledBy, m.EnabledAt = "", nil, nil, nil
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE system_settings SET
+			maintenance_enabled = $1,
+			maintenance_message = $2,
+			maintenance_eta = $3,
+			maintenance_enabled_by = $4,
+			maintenance_enabled_at = $5,
+			updated_at = NOW()
+		WHERE id = 1`,
+		m.Enabled, m.Message, m.ETA, m.EnabledBy, m.EnabledAt)
+	if err != nil {
+		return fmt.Errorf("failed to set maintenance mode: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newMaintenanceRepo(t *testing.T) (*MaintenanceRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewMaintenanceRepository(db), mock
+}
+
+func TestMaintenanceRepository_Get(t *testing.T) {
+	repo, mock := newMaintenanceRepo(t)
+	eta := time.Now().Add(time.Hour)
+	mock.ExpectQuery("SELECT maintenance_enabled.*FROM system_settings").
+		WillReturnRows(sqlmock.NewRows([]string{"maintenance_enabled", "maintenance_message", "maintenance_eta", "maintenance_enabled_by", "maintenance_enabled_at"}).
+			AddRow(true, "migrating storage", eta, "user-1", time.Now()))
+
+	m, err := repo.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !m.Enabled || m.Message != "migrating storage" || m.ETA == nil || m.EnabledBy == nil || *m.EnabledBy != "user-1" {
+		t.Errorf("mode = %+v", m)
+	}
+}
+
+func TestMaintenanceRepository_GetNoSettingsRow(t *testing.T) {
+	repo, mock := newMaintenanceRepo(t)
+	mock.ExpectQuery("SELECT maintenance_enabled").WillReturnError(sql.ErrNoRows)
+
+	m, err := repo.Get(context.Background())
+	if err != nil || m == nil || m.Enabled {
+		t.Errorf("Get = %+v, %v; want disabled", m, err)
+	}
+}
+
+func TestMaintenanceRepository_SetDisabledClearsDetails(t *testing.T) {
+	repo, mock := newMaintenanceRepo(t)
+	by := "user-1"
+	m := &models.MaintenanceMode{Message: "stale", EnabledBy: &by}
+	mock.ExpectExec("UPDATE system_settings SET").
+		WithArgs(false, "", nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.Set(context.Background(), m); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if m.Message != "" || m.EnabledBy != nil {
+		t.Errorf("mode = %+v, want details cleared", m)
+	}
+}
@@ -0,0 +1,110 @@
+// Package repositories - metadata_export_repository.go streams module, provider
+// and Terraform binary version metadata for the admin metadata export.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// MetadataExportRepository reads version metadata for the admin export. It
+// reads every version regardless of visibility or approval state; the export
+// is admin-only.
+type MetadataExportRepository struct {
+	db *sql.DB
+}
+
+// NewMetadataExportRepository creates a new metadata export repository.
+func NewMetadataExportRepository(db *sql.DB) *MetadataExportRepository {
+	return &MetadataExportRepository{db: db}
+}
+
+// metadataExportQuery unions the three version kinds into one record shape.
+// platforms is a JSON array aggregated per version, so a row is one record.
+const metadataExportQuery = `
+	SELECT kind, id, parent_id, namespace, name, system, version, description,
+	       published_at, published_by, published_by_name, checksum, platforms,
+	       deprecated, deprecated_at, deprecation_message, updated_at
+	FROM (
+		SELECT 'module_version' AS kind, mv.id::text AS id, m.id::text AS parent_id,
+		       m.namespace, m.name, m.system, mv.version, m.description,
+		       mv.created_at AS published_at, mv.published_by::text AS published_by,
+		       u.name AS published_by_name, mv.checksum, NULL::json AS platforms,
+		       mv.deprecated, mv.deprecated_at, mv.deprecation_message, mv.updated_at
+		FROM module_versions mv
+		JOIN modules m ON m.id = mv.module_id
+		LEFT JOIN users u ON u.id = mv.published_by
+
+		UNION ALL
+
+		SELECT 'provider_version', pv.id::text, p.id::text,
+		       p.namespace, p.type, '', pv.version, p.description,
+		       pv.created_at, pv.published_by::text, u.name, '',
+		       (SELECT json_agg(json_build_object('os', pp.os, 'arch', pp.arch,
+		                 'filename', pp.filename, 'sha256', pp.shasum) ORDER BY pp.os, pp.arch)
+		        FROM provider_platforms pp WHERE pp.provider_version_id = pv.id),
+		       pv.deprecated, pv.deprecated_at, pv.deprecation_message, pv.updated_at
+		FROM provider_versions pv
+		JOIN providers p ON p.id = pv.provider_id
+		LEFT JOIN users u ON u.id = pv.published_by
+
+		UNION ALL
+
+		SELECT 'terraform_version', tv.id::text, c.id::text,
+		       c.name, c.tool, '', tv.version, c.description,
+		       COALESCE(tv.release_date, tv.created_at), NULL, NULL, '',
+		       (SELECT json_agg(json_build_object('os', tp.os, 'arch', tp.arch,
+		                 'filename', tp.filename, 'sha256', tp.sha256) ORDER BY tp.os, tp.arch)
+		        FROM terraform_version_platforms tp WHERE tp.version_id = tv.id),
+		       tv.is_deprecated, NULL, NULL, tv.updated_at
+		FROM terraform_versions tv
+		JOIN terraform_mirror_configs c ON c.id = tv.config_id
+	) r
+	WHERE r.updated_at >= $1 AND r.updated_at < $2
+	ORDER BY r.updated_at, r.kind, r.id
+`
+
+// EachVersion calls fn with every version whose metadata changed in
+// [since, until), oldest change first, reading rows as fn consumes them
+// rather than loading the whole result. It stops at the first error fn
+// returns, and returns it.
+func (r *MetadataExportRepository) EachVersion(ctx context.Context, since, until time.Time, fn func(*models.MetadataExportRecord) error) error {
+	rows, err := r.db.QueryContext(ctx, metadataExportQuery, since, until)
+	if err != nil {
+		return fmt.Errorf("failed to query version metadata: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rec := &models.MetadataExportRecord{SchemaVersion: models.MetadataExportSchemaVersion}
+		var system, checksum sql.NullString
+		var platforms []byte
+		if err := rows.Scan(
+			&rec.Kind, &rec.ID, &rec.ParentID, &rec.Namespace, &rec.Name, &system,
+			&rec.Version, &rec.Description, &rec.PublishedAt, &rec.PublishedBy,
+			&rec.PublishedByName, &checksum, &platforms, &rec.Deprecated,
+			&rec.DeprecatedAt, &rec.DeprecationMessage, &rec.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to scan version metadata: %w", err)
+		}
+		rec.System = system.String
+		rec.Checksum = checksum.String
+		if len(platforms) > 0 {
+			if err := json.Unmarshal(platforms, &rec.Platforms); err != nil {
+				return fmt.Errorf("failed to decode platforms of %s %s: %w", rec.Kind, rec.ID, err)
+			}
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate version metadata: %w", err)
+	}
+	return nil
+}
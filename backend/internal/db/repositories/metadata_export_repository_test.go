@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var metadataExportCols = []string{
+	"kind", "id", "parent_id", "namespace", "name", "system", "version", "description",
+	"published_at", "published_by", "published_by_name", "checksum", "platforms",
+	"deprecated", "deprecated_at", "deprecation_message", "updated_at",
+}
+
+func TestMetadataExportEachVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewMetadataExportRepository(db)
+
+	since, until := time.Unix(1000, 0), time.Unix(2000, 0)
+	now := time.Now()
+	mock.ExpectQuery("UNION ALL.*WHERE r.updated_at >= \\$1 AND r.updated_at < \\$2.*ORDER BY r.updated_at").
+		WithArgs(since, until).
+		WillReturnRows(sqlmock.NewRows(metadataExportCols).
+			AddRow("module_version", "mv-1", "mod-1", "acme", "vpc", "aws", "1.0.0", "VPC",
+				now, "user-1", "Alice", "abc123", nil, false, nil, nil, now).
+			AddRow("provider_version", "pv-1", "prov-1", "acme", "cloud", "", "2.0.0", nil,
+				now, nil, nil, "", []byte(`[{"os":"linux","arch":"amd64","filename":"p.zip","sha256":"def"}]`),
+				true, now, "use 3.x", now))
+
+	var got []*models.MetadataExportRecord
+	err = repo.EachVersion(context.Background(), since, until, func(rec *models.MetadataExportRecord) error {
+		got = append(got, rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachVersion: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d records, want 2", len(got))
+	}
+	if m := got[0]; m.SchemaVersion != models.MetadataExportSchemaVersion || m.Kind != models.MetadataKindModuleVersion ||
+		m.System != "aws" || m.Checksum != "abc123" || m.PublishedByName == nil || m.Platforms != nil {
+		t.Errorf("module record = %+v", m)
+	}
+	if p := got[1]; len(p.Platforms) != 1 || p.Platforms[0].SHA256 != "def" || !p.Deprecated || p.DeprecationMessage == nil {
+		t.Errorf("provider record = %+v", p)
+	}
+}
+
+func TestMetadataExportEachVersion_StopsOnCallbackError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewMetadataExportRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("UNION ALL").
+		WillReturnRows(sqlmock.NewRows(metadataExportCols).
+			AddRow("terraform_version", "tv-1", "cfg-1", "hashicorp", "terraform", "", "1.9.0", nil,
+				now, nil, nil, "", nil, false, nil, nil, now).
+			AddRow("terraform_version", "tv-2", "cfg-1", "hashicorp", "terraform", "", "1.9.1", nil,
+				now, nil, nil, "", nil, false, nil, nil, now))
+
+	stop := errors.New("client gone")
+	calls := 0
+	err = repo.EachVersion(context.Background(), time.Time{}, now, func(*models.MetadataExportRecord) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("err = %v after %d calls, want %v after 1", err, calls, stop)
+	}
+}
@@ -0,0 +1,123 @@
+// Package repositories - mirror_allowlist_repository.go persists the network
+// mirror allowlist (mirror_allowlist_entries).
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// MirrorAllowlistRepository handles mirror allowlist database operations.
+type MirrorAllowlistRepository struct {
+	db *sql.DB
+}
+
+// NewMirrorAllowlistRepository creates a new mirror allowlist repository.
+func NewMirrorAllowlistRepository(db *sql.DB) *MirrorAllowlistRepository {
+	return &MirrorAllowlistRepository{db: db}
+}
+
+const mirrorAllowlistColumns = `id, hostname_pattern, namespace_pattern, type_pattern, description,
+	created_by, created_at, updated_at`
+
+func scanMirrorAllowlistEntry(row interface{ Scan(...any) error }) (*models.MirrorAllowlistEntry, error) {
+	e := &models.MirrorAllowlistEntry{}
+	if err := row.Scan(
+		&e.ID, &e.HostnamePattern, &e.NamespacePattern, &e.TypePattern, &e.Description,
+		&e.CreatedBy, &e.CreatedAt, &e.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// List returns every allowlist entry.
+func (r *MirrorAllowlistRepository) List(ctx context.Context) ([]*models.MirrorAllowlistEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+mirrorAllowlistColumns+` FROM mirror_allowlist_entries
+		ORDER BY hostname_pattern, namespace_pattern, type_pattern`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mirror allowlist entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.MirrorAllowlistEntry{}
+	for rows.Next() {
+		e, err := scanMirrorAllowlistEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan mirror allowlist entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate mirror allowlist entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetByID returns an entry, or nil when it does not exist.
+func (r *MirrorAllowlistRepository) GetByID(ctx context.Context, id string) (*models.MirrorAllowlistEntry, error) {
+	e, err := scanMirrorAllowlistEntry(r.db.QueryRowContext(ctx,
+		`SELECT `+mirrorAllowlistColumns+` FROM mirror_allowlist_entries WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get mirror allowlist entry: %w", err)
+	}
+	return e, nil
+}
+
+// Create inserts an entry and fills in its ID and timestamps.
+func (r *MirrorAllowlistRepository) Create(ctx context.Context, e *models.MirrorAllowlistEntry) error {
+	query := `
+		INSERT INTO mirror_allowlist_entries
+			(hostname_pattern, namespace_pattern, type_pattern, description, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		e.HostnamePattern, e.NamespacePattern, e.TypePattern, e.Description, e.CreatedBy,
+	).Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create mirror allowlist entry: %w", err)
+	}
+	return nil
+}
+
+// Update replaces an entry's patterns and description and refreshes
+// updated_at, filling in the server-maintained columns. It reports whether the
+// entry existed.
+func (r *MirrorAllowlistRepository) Update(ctx context.Context, e *models.MirrorAllowlistEntry) (bool, error) {
+	query := `
+		UPDATE mirror_allowlist_entries
+		SET hostname_pattern = $2, namespace_pattern = $3, type_pattern = $4, description = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_by, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		e.ID, e.HostnamePattern, e.NamespacePattern, e.TypePattern, e.Description,
+	).Scan(&e.CreatedBy, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to update mirror allowlist entry: %w", err)
+	}
+	return true, nil
+}
+
+// Delete removes an entry. It reports whether the entry existed.
+func (r *MirrorAllowlistRepository) Delete(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM mirror_allowlist_entries WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete mirror allowlist entry: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete mirror allowlist entry: %w", err)
+	}
+	return n > 0, nil
+}
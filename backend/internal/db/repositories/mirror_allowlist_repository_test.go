@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var mirrorAllowlistCols = []string{
+	"id", "hostname_pattern", "namespace_pattern", "type_pattern", "description",
+	"created_by", "created_at", "updated_at",
+}
+
+func newMirrorAllowlistRepo(t *testing.T) (*MirrorAllowlistRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewMirrorAllowlistRepository(db), mock
+}
+
+func TestMirrorAllowlist_List(t *testing.T) {
+	repo, mock := newMirrorAllowlistRepo(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_allowlist_entries").
+		WillReturnRows(sqlmock.NewRows(mirrorAllowlistCols).
+			AddRow("e-1", "registry.terraform.io", "hashicorp", "*", "upstream HashiCorp", nil, time.Now(), time.Now()))
+
+	entries, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 1 || entries[0].NamespacePattern != "hashicorp" || entries[0].Description == nil {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestMirrorAllowlist_List_EmptyIsSlice(t *testing.T) {
+	repo, mock := newMirrorAllowlistRepo(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_allowlist_entries").
+		WillReturnRows(sqlmock.NewRows(mirrorAllowlistCols))
+
+	entries, err := repo.List(context.Background())
+	if err != nil || entries == nil || len(entries) != 0 {
+		t.Fatalf("List = %v, %v; want empty slice", entries, err)
+	}
+}
+
+func TestMirrorAllowlist_GetByID_NotFound(t *testing.T) {
+	repo, mock := newMirrorAllowlistRepo(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_allowlist_entries WHERE id").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(mirrorAllowlistCols))
+
+	entry, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || entry != nil {
+		t.Fatalf("GetByID = %v, %v; want nil, nil", entry, err)
+	}
+}
+
+func TestMirrorAllowlist_Create(t *testing.T) {
+	repo, mock := newMirrorAllowlistRepo(t)
+	creator := "user-1"
+	mock.ExpectQuery("INSERT INTO mirror_allowlist_entries").
+		WithArgs("registry.terraform.io", "hashicorp", "*", nil, &creator).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("e-1", time.Now(), time.Now()))
+
+	e := &models.MirrorAllowlistEntry{HostnamePattern: "registry.terraform.io", NamespacePattern: "hashicorp", TypePattern: "*", CreatedBy: &creator}
+	if err := repo.Create(context.Background(), e); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if e.ID != "e-1" {
+		t.Errorf("ID = %q, want e-1", e.ID)
+	}
+}
+
+func TestMirrorAllowlist_Update_NotFound(t *testing.T) {
+	repo, mock := newMirrorAllowlistRepo(t)
+	mock.ExpectQuery("UPDATE mirror_allowlist_entries").
+		WillReturnRows(sqlmock.NewRows([]string{"created_by", "created_at", "updated_at"}))
+
+	found, err := repo.Update(context.Background(), &models.MirrorAllowlistEntry{ID: "missing", HostnamePattern: "*", NamespacePattern: "*", TypePattern: "*"})
+	if err != nil || found {
+		t.Fatalf("Update = %v, %v; want false, nil", found, err)
+	}
+}
+
+func TestMirrorAllowlist_Delete(t *testing.T) {
+	repo, mock := newMirrorAllowlistRepo(t)
+	mock.ExpectExec("DELETE FROM mirror_allowlist_entries WHERE id").
+		WithArgs("e-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deleted, err := repo.Delete(context.Background(), "e-1")
+	if err != nil || !deleted {
+		t.Fatalf("Delete = %v, %v; want true, nil", deleted, err)
+	}
+}
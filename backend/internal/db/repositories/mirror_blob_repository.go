@@ -0,0 +1,258 @@
+// Package repositories - mirror_blob_repository.go tracks the content-addressed
+// blobs behind mirrored provider platform archives and the platform rows that
+// reference them. A blob's reference count is its number of mirror_blob_refs
+// rows; the storage object is deleted, under a lock on the blob row, only when
+// that count reaches zero.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ErrMirrorBlobNotFound is returned when referencing a blob whose row does not
+// exist, for example because it was released and deleted concurrently.
+var ErrMirrorBlobNotFound = errors.New("mirror blob not found")
+
+// MirrorBlobRepository handles mirror blob database operations.
+type MirrorBlobRepository struct {
+	db *sql.DB
+}
+
+// NewMirrorBlobRepository creates a new mirror blob repository.
+func NewMirrorBlobRepository(db *sql.DB) *MirrorBlobRepository {
+	return &MirrorBlobRepository{db: db}
+}
+
+// EnsureBlob records blob unless a blob with its digest exists. It reports
+// whether the row was created, in which case the caller must store the
+// object.
+func (r *MirrorBlobRepository) EnsureBlob(ctx context.Context, blob *models.MirrorBlob) (bool, error) {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO mirror_blobs (digest, storage_path, size_bytes)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (digest) DO NOTHING
+		RETURNING created_at`,
+		blob.Digest, blob.StoragePath, blob.SizeBytes,
+	).Scan(&blob.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record mirror blob: %w", err)
+	}
+	return true, nil
+}
+
+// GetBlob returns a blob by digest, or nil when it does not exist.
+func (r *MirrorBlobRepository) GetBlob(ctx context.Context, digest string) (*models.MirrorBlob, error) {
+	b := &models.MirrorBlob{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT digest, storage_path, size_bytes, created_at FROM mirror_blobs WHERE digest = $1`, digest,
+	).Scan(&b.Digest, &b.StoragePath, &b.SizeBytes, &b.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mirror blob: %w", err)
+	}
+	return b, nil
+}
+
+// AddRef records that platformID's archive is the blob digest. It returns
+// ErrMirrorBlobNotFound when the blob row does not exist.
+func (r *MirrorBlobRepository) AddRef(ctx context.Context, platformID, digest string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO mirror_blob_refs (provider_platform_id, digest)
+		VALUES ($1, $2)
+		ON CONFLICT (provider_platform_id) DO UPDATE SET digest = EXCLUDED.digest`,
+		platformID, digest)
+	return refError(err)
+}
+
+// RebindPlatform references the blob digest from platformID and points the
+// platform's storage_path at the blob, in one transaction. It returns
+// ErrMirrorBlobNotFound when the blob row does not exist.
+func (r *MirrorBlobRepository) RebindPlatform(ctx context.Context, platformID, digest string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO mirror_blob_refs (provider_platform_id, digest)
+		VALUES ($1, $2)
+		ON CONFLICT (provider_platform_id) DO UPDATE SET digest = EXCLUDED.digest`,
+		platformID, digest); err != nil {
+		return refError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE provider_platforms
+		SET storage_path = (SELECT storage_path FROM mirror_blobs WHERE digest = $2)
+		WHERE id = $1`,
+		platformID, digest); err != nil {
+		return fmt.Errorf("failed to update platform storage path: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit blob reference: %w", err)
+	}
+	return nil
+}
+
+func refError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" && pqErr.Constraint == "mirror_blob_refs_digest_fkey" {
+		return ErrMirrorBlobNotFound
+	}
+	return fmt.Errorf("failed to reference mirror blob: %w", err)
+}
+
+// ReleaseRef drops platformID's blob reference. When it was the blob's last
+// reference, onLast is called with the blob's storage path while the blob row
+// is locked, and the row is deleted; if onLast fails, nothing changes. It
+// reports whether platformID referenced a blob at all.
+func (r *MirrorBlobRepository) ReleaseRef(ctx context.Context, platformID string, onLast func(storagePath string) error) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var digest string
+	err = tx.QueryRowContext(ctx,
+		`DELETE FROM mirror_blob_refs WHERE provider_platform_id = $1 RETURNING digest`, platformID).Scan(&digest)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to release mirror blob reference: %w", err)
+	}
+	if _, err := deleteUnreferencedBlob(ctx, tx, digest, onLast); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit blob release: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteIfUnreferenced deletes the blob digest if nothing references it,
+// calling onDelete with its storage path under the row lock first. It reports
+// whether the blob was deleted.
+func (r *MirrorBlobRepository) DeleteIfUnreferenced(ctx context.Context, digest string, onDelete func(storagePath string) error) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	deleted, err := deleteUnreferencedBlob(ctx, tx, digest, onDelete)
+	if err != nil || !deleted {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit blob deletion: %w", err)
+	}
+	return true, nil
+}
+
+// deleteUnreferencedBlob locks the blob row, and when no reference remains
+// calls onDelete and deletes the row. Locking the row makes a concurrent
+// AddRef wait, and fail with ErrMirrorBlobNotFound once the row is gone, so an
+// object is never deleted while a reference to it is being added.
+func deleteUnreferencedBlob(ctx context.Context, tx *sql.Tx, digest string, onDelete func(storagePath string) error) (bool, error) {
+	var storagePath string
+	err := tx.QueryRowContext(ctx,
+		`SELECT storage_path FROM mirror_blobs WHERE digest = $1 FOR UPDATE`, digest).Scan(&storagePath)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock mirror blob: %w", err)
+	}
+	var refs int
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM mirror_blob_refs WHERE digest = $1`, digest).Scan(&refs); err != nil {
+		return false, fmt.Errorf("failed to count mirror blob references: %w", err)
+	}
+	if refs > 0 {
+		return false, nil
+	}
+	if err := onDelete(storagePath); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mirror_blobs WHERE digest = $1`, digest); err != nil {
+		return false, fmt.Errorf("failed to delete mirror blob: %w", err)
+	}
+	return true, nil
+}
+
+// ListUnreferencedBlobs returns the blobs no platform references.
+func (r *MirrorBlobRepository) ListUnreferencedBlobs(ctx context.Context) ([]*models.MirrorBlob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT b.digest, b.storage_path, b.size_bytes, b.created_at
+		FROM mirror_blobs b
+		WHERE NOT EXISTS (SELECT 1 FROM mirror_blob_refs r WHERE r.digest = b.digest)
+		ORDER BY b.digest`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unreferenced mirror blobs: %w", err)
+	}
+	defer rows.Close()
+
+	var blobs []*models.MirrorBlob
+	for rows.Next() {
+		b := &models.MirrorBlob{}
+		if err := rows.Scan(&b.Digest, &b.StoragePath, &b.SizeBytes, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mirror blob: %w", err)
+		}
+		blobs = append(blobs, b)
+	}
+	return blobs, rows.Err()
+}
+
+// ListDedupCandidates returns the platform archives of mirrored provider
+// versions that are not backed by a blob.
+func (r *MirrorBlobRepository) ListDedupCandidates(ctx context.Context) ([]models.MirrorBlobCandidate, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pp.id, pp.storage_path, pp.size_bytes, pp.shasum
+		FROM provider_platforms pp
+		WHERE EXISTS (SELECT 1 FROM mirrored_provider_versions mpv WHERE mpv.provider_version_id = pp.provider_version_id)
+		  AND NOT EXISTS (SELECT 1 FROM mirror_blob_refs r WHERE r.provider_platform_id = pp.id)
+		  AND pp.storage_path <> ''
+		ORDER BY pp.storage_path, pp.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mirror dedup candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []models.MirrorBlobCandidate
+	for rows.Next() {
+		var c models.MirrorBlobCandidate
+		if err := rows.Scan(&c.PlatformID, &c.StoragePath, &c.SizeBytes, &c.Shasum); err != nil {
+			return nil, fmt.Errorf("failed to scan mirror dedup candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// CountPlatformsByStoragePath returns how many platform rows store their
+// archive at storagePath.
+func (r *MirrorBlobRepository) CountPlatformsByStoragePath(ctx context.Context, storagePath string) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM provider_platforms WHERE storage_path = $1`, storagePath).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count platforms by storage path: %w", err)
+	}
+	return n, nil
+}
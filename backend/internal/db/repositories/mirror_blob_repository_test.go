@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newMirrorBlobRepo(t *testing.T) (*MirrorBlobRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewMirrorBlobRepository(db), mock
+}
+
+func TestMirrorBlob_EnsureBlob(t *testing.T) {
+	repo, mock := newMirrorBlobRepo(t)
+	mock.ExpectQuery("INSERT INTO mirror_blobs.*ON CONFLICT \\(digest\\) DO NOTHING").
+		WithArgs("abc", "blobs/sha256/abc", int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectQuery("INSERT INTO mirror_blobs").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}))
+
+	blob := &models.MirrorBlob{Digest: "abc", StoragePath: "blobs/sha256/abc", SizeBytes: 10}
+	if created, err := repo.EnsureBlob(context.Background(), blob); err != nil || !created {
+		t.Fatalf("EnsureBlob = %v, %v; want created", created, err)
+	}
+	if created, err := repo.EnsureBlob(context.Background(), blob); err != nil || created {
+		t.Fatalf("EnsureBlob(existing) = %v, %v; want not created", created, err)
+	}
+}
+
+func TestMirrorBlob_AddRefMissingBlob(t *testing.T) {
+	repo, mock := newMirrorBlobRepo(t)
+	mock.ExpectExec("INSERT INTO mirror_blob_refs").
+		WithArgs("plat-1", "abc").
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "mirror_blob_refs_digest_fkey"})
+
+	if err := repo.AddRef(context.Background(), "plat-1", "abc"); !errors.Is(err, ErrMirrorBlobNotFound) {
+		t.Fatalf("AddRef = %v, want ErrMirrorBlobNotFound", err)
+	}
+}
+
+func TestMirrorBlob_ReleaseRef(t *testing.T) {
+	tests := []struct {
+		name       string
+		refs       int
+		wantDelete bool
+	}{
+		{"last reference", 0, true},
+		{"still referenced", 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newMirrorBlobRepo(t)
+			mock.ExpectBegin()
+			mock.ExpectQuery("DELETE FROM mirror_blob_refs WHERE provider_platform_id = \\$1 RETURNING digest").
+				WithArgs("plat-1").
+				WillReturnRows(sqlmock.NewRows([]string{"digest"}).AddRow("abc"))
+			mock.ExpectQuery("SELECT storage_path FROM mirror_blobs WHERE digest = \\$1 FOR UPDATE").
+				WithArgs("abc").
+				WillReturnRows(sqlmock.NewRows([]string{"storage_path"}).AddRow("blobs/sha256/abc"))
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM mirror_blob_refs").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.refs))
+			if tt.wantDelete {
+				mock.ExpectExec("DELETE FROM mirror_blobs").WithArgs("abc").WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			var deleted string
+			released, err := repo.ReleaseRef(context.Background(), "plat-1", func(path string) error {
+				deleted = path
+				return nil
+			})
+			if err != nil || !released {
+				t.Fatalf("ReleaseRef = %v, %v", released, err)
+			}
+			if (deleted != "") != tt.wantDelete {
+				t.Errorf("deleted object = %q, wantDelete %v", deleted, tt.wantDelete)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMirrorBlob_ReleaseRef_DeleteFailsRollsBack(t *testing.T) {
+	repo, mock := newMirrorBlobRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM mirror_blob_refs").
+		WillReturnRows(sqlmock.NewRows([]string{"digest"}).AddRow("abc"))
+	mock.ExpectQuery("FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"storage_path"}).AddRow("blobs/sha256/abc"))
+	mock.ExpectQuery("SELECT COUNT").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+
+	boom := errors.New("immutable")
+	if _, err := repo.ReleaseRef(context.Background(), "plat-1", func(string) error { return boom }); !errors.Is(err, boom) {
+		t.Fatalf("ReleaseRef = %v, want callback error", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMirrorBlob_ReleaseRef_NotReferenced(t *testing.T) {
+	repo, mock := newMirrorBlobRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM mirror_blob_refs").
+		WillReturnRows(sqlmock.NewRows([]string{"digest"}))
+	mock.ExpectRollback()
+
+	released, err := repo.ReleaseRef(context.Background(), "plat-1", func(string) error {
+		t.Fatal("onLast called for unreferenced platform")
+		return nil
+	})
+	if err != nil || released {
+		t.Fatalf("ReleaseRef = %v, %v; want false, nil", released, err)
+	}
+}
+
+func TestMirrorBlob_RebindPlatform(t *testing.T) {
+	repo, mock := newMirrorBlobRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO mirror_blob_refs").
+		WithArgs("plat-1", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE provider_platforms.*SELECT storage_path FROM mirror_blobs").
+		WithArgs("plat-1", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.RebindPlatform(context.Background(), "plat-1", "abc"); err != nil {
+		t.Fatalf("RebindPlatform: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,161 @@
+// Package repositories - mirror_hostname_alias_repository.go persists network
+// mirror hostname aliases (mirror_hostname_aliases) and the per-provider
+// opt-in that lets a mirrored provider be served under them.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// MirrorHostnameAliasRepository handles mirror hostname alias database operations.
+type MirrorHostnameAliasRepository struct {
+	db *sql.DB
+}
+
+// NewMirrorHostnameAliasRepository creates a new mirror hostname alias repository.
+func NewMirrorHostnameAliasRepository(db *sql.DB) *MirrorHostnameAliasRepository {
+	return &MirrorHostnameAliasRepository{db: db}
+}
+
+const mirrorHostnameAliasSelect = `
+	SELECT a.id, a.mirror_config_id, a.alias_hostname, a.namespace, a.created_by, a.created_at,
+	       m.upstream_registry_url
+	FROM mirror_hostname_aliases a
+	JOIN mirror_configurations m ON m.id = a.mirror_config_id`
+
+func (r *MirrorHostnameAliasRepository) queryAliases(ctx context.Context, query string, args ...interface{}) ([]*models.MirrorHostnameAlias, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mirror hostname aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []*models.MirrorHostnameAlias{}
+	for rows.Next() {
+		a := &models.MirrorHostnameAlias{}
+		var upstreamURL string
+		if err := rows.Scan(&a.ID, &a.MirrorConfigID, &a.AliasHostname, &a.Namespace,
+			&a.CreatedBy, &a.CreatedAt, &upstreamURL); err != nil {
+			return nil, fmt.Errorf("failed to scan mirror hostname alias: %w", err)
+		}
+		a.TargetHostname = models.UpstreamHostname(upstreamURL)
+		aliases = append(aliases, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate mirror hostname aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// List returns every alias, with TargetHostname filled in.
+func (r *MirrorHostnameAliasRepository) List(ctx context.Context) ([]*models.MirrorHostnameAlias, error) {
+	return r.queryAliases(ctx, mirrorHostnameAliasSelect+`
+		ORDER BY a.alias_hostname, a.namespace NULLS FIRST`)
+}
+
+// ListByMirror returns the aliases of one mirror configuration.
+func (r *MirrorHostnameAliasRepository) ListByMirror(ctx context.Context, mirrorConfigID string) ([]*models.MirrorHostnameAlias, error) {
+	return r.queryAliases(ctx, mirrorHostnameAliasSelect+`
+		WHERE a.mirror_config_id = $1
+		ORDER BY a.alias_hostname, a.namespace NULLS FIRST`, mirrorConfigID)
+}
+
+// Create inserts an alias and fills in its ID and created_at.
+func (r *MirrorHostnameAliasRepository) Create(ctx context.Context, a *models.MirrorHostnameAlias) error {
+	query := `
+		INSERT INTO mirror_hostname_aliases (mirror_config_id, alias_hostname, namespace, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		a.MirrorConfigID, a.AliasHostname, a.Namespace, a.CreatedBy,
+	).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create mirror hostname alias: %w", err)
+	}
+	return nil
+}
+
+// Delete removes one of a mirror configuration's aliases. It reports whether
+// the alias existed.
+func (r *MirrorHostnameAliasRepository) Delete(ctx context.Context, mirrorConfigID, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM mirror_hostname_aliases WHERE id = $1 AND mirror_config_id = $2`, id, mirrorConfigID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete mirror hostname alias: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete mirror hostname alias: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SetServeUnderAliases opts a mirrored provider of a mirror configuration in
+// to (or out of) its mirror's aliases. It reports whether the mirrored
+// provider exists.
+func (r *MirrorHostnameAliasRepository) SetServeUnderAliases(ctx context.Context, mirrorConfigID, mirroredProviderID string, enabled bool) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE mirrored_providers SET serve_under_aliases = $3 WHERE id = $1 AND mirror_config_id = $2`,
+		mirroredProviderID, mirrorConfigID, enabled)
+	if err != nil {
+		return false, fmt.Errorf("failed to update mirrored provider alias opt-in: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update mirrored provider alias opt-in: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetProviderSource returns the mirror a stored provider was synced from, or
+// nil for a provider no mirror tracks.
+func (r *MirrorHostnameAliasRepository) GetProviderSource(ctx context.Context, providerID string) (*models.MirroredProviderSource, error) {
+	query := `
+		SELECT p.id, p.mirror_config_id, m.upstream_registry_url, p.serve_under_aliases
+		FROM mirrored_providers p
+		JOIN mirror_configurations m ON m.id = p.mirror_config_id
+		WHERE p.provider_id = $1
+	`
+	s := &models.MirroredProviderSource{}
+	var upstreamURL string
+	err := r.db.QueryRowContext(ctx, query, providerID).
+		Scan(&s.MirroredProviderID, &s.MirrorConfigID, &upstreamURL, &s.ServeUnderAliases)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mirrored provider source: %w", err)
+	}
+	s.UpstreamHostname = models.UpstreamHostname(upstreamURL)
+	return s, nil
+}
+
+// ListSyncedVersionIDs returns the IDs of the provider versions the mirror
+// synced for a mirrored provider.
+func (r *MirrorHostnameAliasRepository) ListSyncedVersionIDs(ctx context.Context, mirroredProviderID string) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT provider_version_id FROM mirrored_provider_versions WHERE mirrored_provider_id = $1`,
+		mirroredProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list synced provider versions: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan synced provider version: %w", err)
+		}
+		ids[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate synced provider versions: %w", err)
+	}
+	return ids, nil
+}
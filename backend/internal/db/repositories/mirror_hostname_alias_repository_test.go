@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+var mirrorHostnameAliasCols = []string{
+	"id", "mirror_config_id", "alias_hostname", "namespace", "created_by", "created_at",
+	"upstream_registry_url",
+}
+
+func newMirrorHostnameAliasRepo(t *testing.T) (*MirrorHostnameAliasRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewMirrorHostnameAliasRepository(db), mock
+}
+
+func TestMirrorHostnameAlias_List_FillsTarget(t *testing.T) {
+	repo, mock := newMirrorHostnameAliasRepo(t)
+	mock.ExpectQuery("SELECT.*FROM mirror_hostname_aliases a.*JOIN mirror_configurations").
+		WillReturnRows(sqlmock.NewRows(mirrorHostnameAliasCols).
+			AddRow("a-1", "m-1", "registry.opentofu.org", nil, nil, time.Now(), "https://Registry.Terraform.io/"))
+
+	aliases, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0].TargetHostname != "registry.terraform.io" || aliases[0].Namespace != nil {
+		t.Errorf("aliases = %+v", aliases)
+	}
+}
+
+func TestMirrorHostnameAlias_GetProviderSource(t *testing.T) {
+	repo, mock := newMirrorHostnameAliasRepo(t)
+	mock.ExpectQuery("SELECT.*FROM mirrored_providers p").
+		WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "mirror_config_id", "upstream_registry_url", "serve_under_aliases"}).
+			AddRow("mp-1", "m-1", "https://registry.terraform.io", true))
+
+	src, err := repo.GetProviderSource(context.Background(), "prov-1")
+	if err != nil {
+		t.Fatalf("GetProviderSource: %v", err)
+	}
+	if src == nil || src.UpstreamHostname != "registry.terraform.io" || !src.ServeUnderAliases {
+		t.Errorf("source = %+v", src)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM mirrored_providers p").
+		WithArgs("prov-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "mirror_config_id", "upstream_registry_url", "serve_under_aliases"}))
+	if src, err := repo.GetProviderSource(context.Background(), "prov-2"); src != nil || err != nil {
+		t.Errorf("untracked provider: source = %+v, err = %v; want nil, nil", src, err)
+	}
+}
+
+func TestMirrorHostnameAlias_ListSyncedVersionIDs(t *testing.T) {
+	repo, mock := newMirrorHostnameAliasRepo(t)
+	mock.ExpectQuery("SELECT provider_version_id FROM mirrored_provider_versions").
+		WithArgs("mp-1").
+		WillReturnRows(sqlmock.NewRows([]string{"provider_version_id"}).AddRow("v-1").AddRow("v-2"))
+
+	ids, err := repo.ListSyncedVersionIDs(context.Background(), "mp-1")
+	if err != nil {
+		t.Fatalf("ListSyncedVersionIDs: %v", err)
+	}
+	if len(ids) != 2 || !ids["v-1"] || !ids["v-2"] {
+		t.Errorf("ids = %v", ids)
+	}
+}
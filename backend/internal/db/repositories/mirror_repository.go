@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -32,8 +33,10 @@ func (r *MirrorRepository) Create(ctx context.Context, config *models.MirrorConf
 		INSERT INTO mirror_configurations (
 			id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
 			version_filter, platform_filter, enabled, sync_interval_hours, requires_approval, auto_approve_rules,
-			pull_through_enabled, pull_through_cache_ttl_hours, created_at, updated_at, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+			pull_through_enabled, pull_through_cache_ttl_hours, history_retention_count, history_retention_days,
+			created_at, updated_at, created_by, private, docs_passthrough_enabled, docs_cache_ttl_hours,
+			max_storage_bytes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -52,9 +55,15 @@ func (r *MirrorRepository) Create(ctx context.Context, config *models.MirrorConf
 		config.AutoApproveRules,
 		config.PullThroughEnabled,
 		config.PullThroughCacheTTLHours,
+		config.HistoryRetentionCount,
+		config.HistoryRetentionDays,
 		config.CreatedAt,
 		config.UpdatedAt,
 		config.CreatedBy,
+		config.Private,
+		config.DocsPassthroughEnabled,
+		config.DocsCacheTTLHours,
+		config.MaxStorageBytes,
 	)
 
 	if err != nil {
@@ -67,9 +76,11 @@ func (r *MirrorRepository) Create(ctx context.Context, config *models.MirrorConf
 // GetByID retrieves a mirror configuration by ID
 func (r *MirrorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MirrorConfiguration, error) {
 	query := `
-		SELECT id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
+		SELECT id, name, description, upstream_registry_url, organization_id, private, namespace_filter, provider_filter,
 		       version_filter, platform_filter, enabled, sync_interval_hours, requires_approval, auto_approve_rules, pull_through_enabled,
-		       pull_through_cache_ttl_hours, last_sync_at, last_sync_status, last_sync_error,
+		       pull_through_cache_ttl_hours, docs_passthrough_enabled, docs_cache_ttl_hours,
+		       history_retention_count, history_retention_days, max_storage_bytes, storage_alert_level,
+		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at, created_by
 		FROM mirror_configurations
 		WHERE id = $1
@@ -90,9 +101,11 @@ func (r *MirrorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.M
 // GetByName retrieves a mirror configuration by name
 func (r *MirrorRepository) GetByName(ctx context.Context, name string) (*models.MirrorConfiguration, error) {
 	query := `
-		SELECT id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
+		SELECT id, name, description, upstream_registry_url, organization_id, private, namespace_filter, provider_filter,
 		       version_filter, platform_filter, enabled, sync_interval_hours, requires_approval, auto_approve_rules, pull_through_enabled,
-		       pull_through_cache_ttl_hours, last_sync_at, last_sync_status, last_sync_error,
+		       pull_through_cache_ttl_hours, docs_passthrough_enabled, docs_cache_ttl_hours,
+		       history_retention_count, history_retention_days, max_storage_bytes, storage_alert_level,
+		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at, created_by
 		FROM mirror_configurations
 		WHERE name = $1
@@ -113,9 +126,11 @@ func (r *MirrorRepository) GetByName(ctx context.Context, name string) (*models.
 // List retrieves all mirror configurations
 func (r *MirrorRepository) List(ctx context.Context, enabledOnly bool) ([]models.MirrorConfiguration, error) {
 	query := `
-		SELECT id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
+		SELECT id, name, description, upstream_registry_url, organization_id, private, namespace_filter, provider_filter,
 		       version_filter, platform_filter, enabled, sync_interval_hours, requires_approval, auto_approve_rules, pull_through_enabled,
-		       pull_through_cache_ttl_hours, last_sync_at, last_sync_status, last_sync_error,
+		       pull_through_cache_ttl_hours, docs_passthrough_enabled, docs_cache_ttl_hours,
+		       history_retention_count, history_retention_days, max_storage_bytes, storage_alert_level,
+		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at, created_by
 		FROM mirror_configurations
 	`
@@ -139,12 +154,23 @@ func (r *MirrorRepository) List(ctx context.Context, enabledOnly bool) ([]models
 func (r *MirrorRepository) Update(ctx context.Context, config *models.MirrorConfiguration) error {
 	config.UpdatedAt = time.Now()
 
+	// A changed configuration can select versions the last sync skipped, so
+	// the validators its providers were last listed with are dropped and the
+	// next sync lists every provider in full.
 	query := `
+		WITH cleared AS (
+			UPDATE mirrored_providers
+			SET upstream_etag = NULL, upstream_last_modified = NULL
+			WHERE mirror_config_id = $1
+		)
 		UPDATE mirror_configurations
 		SET name = $2, description = $3, upstream_registry_url = $4, organization_id = $5,
 		    namespace_filter = $6, provider_filter = $7, version_filter = $8, platform_filter = $9,
 		    enabled = $10, sync_interval_hours = $11, requires_approval = $12, auto_approve_rules = $13,
-		    pull_through_enabled = $14, pull_through_cache_ttl_hours = $15, updated_at = $16
+		    pull_through_enabled = $14, pull_through_cache_ttl_hours = $15,
+		    history_retention_count = $16, history_retention_days = $17, updated_at = $18,
+		    private = $19, docs_passthrough_enabled = $20, docs_cache_ttl_hours = $21,
+		    max_storage_bytes = $22
 		WHERE id = $1
 	`
 
@@ -164,7 +190,13 @@ func (r *MirrorRepository) Update(ctx context.Context, config *models.MirrorConf
 		config.AutoApproveRules,
 		config.PullThroughEnabled,
 		config.PullThroughCacheTTLHours,
+		config.HistoryRetentionCount,
+		config.HistoryRetentionDays,
 		config.UpdatedAt,
+		config.Private,
+		config.DocsPassthroughEnabled,
+		config.DocsCacheTTLHours,
+		config.MaxStorageBytes,
 	)
 
 	if err != nil {
@@ -256,9 +288,11 @@ func (r *MirrorRepository) ResetStaleSyncs(ctx context.Context) (int64, error) {
 // GetMirrorsNeedingSync retrieves mirror configurations that need to be synced
 func (r *MirrorRepository) GetMirrorsNeedingSync(ctx context.Context) ([]models.MirrorConfiguration, error) {
 	query := `
-		SELECT id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
+		SELECT id, name, description, upstream_registry_url, organization_id, private, namespace_filter, provider_filter,
 		       version_filter, platform_filter, enabled, sync_interval_hours, requires_approval, auto_approve_rules, pull_through_enabled,
-		       pull_through_cache_ttl_hours, last_sync_at, last_sync_status, last_sync_error,
+		       pull_through_cache_ttl_hours, docs_passthrough_enabled, docs_cache_ttl_hours,
+		       history_retention_count, history_retention_days, max_storage_bytes, storage_alert_level,
+		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at, created_by
 		FROM mirror_configurations
 		WHERE enabled = true
@@ -350,6 +384,149 @@ func (r *MirrorRepository) GetSyncHistory(ctx context.Context, mirrorConfigID uu
 	return history, nil
 }
 
+// ListSyncHistoryPaginated returns a page of sync history for a mirror configuration,
+// newest first, together with the total number of retained rows.
+func (r *MirrorRepository) ListSyncHistoryPaginated(ctx context.Context, mirrorConfigID uuid.UUID, limit, offset int) ([]models.MirrorSyncHistory, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM mirror_sync_history WHERE mirror_config_id = $1`, mirrorConfigID,
+	).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count sync history: %w", err)
+	}
+
+	query := `
+		SELECT id, mirror_config_id, started_at, completed_at, status,
+		       providers_synced, providers_failed, error_message, sync_details
+		FROM mirror_sync_history
+		WHERE mirror_config_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	history := []models.MirrorSyncHistory{}
+	if err := r.db.SelectContext(ctx, &history, query, mirrorConfigID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list sync history: %w", err)
+	}
+
+	return history, total, nil
+}
+
+// PruneSyncHistory deletes finished sync history rows for a mirror configuration
+// that fall outside its retention window: beyond the keepCount most recent runs,
+// or started more than keepDays days ago. A zero limit is not applied. Running
+// syncs are never deleted. It returns the number of rows removed.
+func (r *MirrorRepository) PruneSyncHistory(ctx context.Context, mirrorConfigID uuid.UUID, keepCount, keepDays int) (int64, error) {
+	if keepCount <= 0 && keepDays <= 0 {
+		return 0, nil
+	}
+
+	query := `
+		DELETE FROM mirror_sync_history
+		WHERE mirror_config_id = $1
+		  AND status <> 'running'
+		  AND (
+		        ($2::int > 0 AND id NOT IN (
+		            SELECT id FROM mirror_sync_history
+		            WHERE mirror_config_id = $1
+		            ORDER BY started_at DESC
+		            LIMIT $2::int
+		        ))
+		        OR ($3::int > 0 AND started_at < NOW() - ($3::int * INTERVAL '1 day'))
+		      )
+	`
+
+	result, err := r.db.ExecContext(ctx, query, mirrorConfigID, keepCount, keepDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune sync history: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// GetSyncHistoryStats aggregates the finished sync runs retained for a mirror
+// configuration.
+func (r *MirrorRepository) GetSyncHistoryStats(ctx context.Context, mirrorConfigID uuid.UUID) (*models.SyncHistoryStats, error) {
+	query := `
+		SELECT COUNT(*)                                         AS total_runs,
+		       COUNT(*) FILTER (WHERE status = 'success')       AS successful_runs,
+		       COUNT(*) FILTER (WHERE status = 'failed')        AS failed_runs,
+		       COUNT(*) FILTER (WHERE status = 'cancelled')     AS cancelled_runs,
+		       MIN(started_at)                                  AS window_start,
+		       MAX(started_at) FILTER (WHERE status = 'success') AS last_success_at,
+		       MAX(started_at) FILTER (WHERE status = 'failed')  AS last_failure_at
+		FROM mirror_sync_history
+		WHERE mirror_config_id = $1 AND status <> 'running'
+	`
+
+	var row models.SyncHistoryStatsRow
+	if err := r.db.GetContext(ctx, &row, query, mirrorConfigID); err != nil {
+		return nil, fmt.Errorf("failed to get sync history stats: %w", err)
+	}
+
+	return row.Stats(), nil
+}
+
+// GetPlatformUsage returns every os/arch stored for the mirror's providers
+// with the number of downloads recorded for it since the given time, ordered
+// by os and arch.
+func (r *MirrorRepository) GetPlatformUsage(ctx context.Context, mirrorConfigID uuid.UUID, since time.Time) ([]models.MirrorPlatformUsage, error) {
+	query := `
+		WITH mirror_providers AS (
+			SELECT provider_id FROM mirrored_providers WHERE mirror_config_id = $1
+		), platforms AS (
+			SELECT DISTINCT pp.os, pp.arch
+			FROM provider_platforms pp
+			JOIN provider_versions pv ON pv.id = pp.provider_version_id
+			WHERE pv.provider_id IN (SELECT provider_id FROM mirror_providers)
+		)
+		SELECT p.os, p.arch, COALESCE(SUM(s.downloads), 0) AS downloads
+		FROM platforms p
+		LEFT JOIN provider_download_stats s
+			ON s.os = p.os AND s.arch = p.arch AND s.day >= $2::date
+			AND s.provider_id IN (SELECT provider_id FROM mirror_providers)
+		GROUP BY p.os, p.arch
+		ORDER BY p.os, p.arch
+	`
+
+	var usage []models.MirrorPlatformUsage
+	if err := r.db.SelectContext(ctx, &usage, query, mirrorConfigID, since); err != nil {
+		return nil, fmt.Errorf("failed to get mirror platform usage: %w", err)
+	}
+	for i := range usage {
+		usage[i].Unused = usage[i].Downloads == 0
+	}
+	return usage, nil
+}
+
+// GetStorageUsage returns the bytes stored for the platform archives of every
+// provider the mirror tracks. Archives shared with other mirrors through a
+// content-addressed blob count towards each of them.
+func (r *MirrorRepository) GetStorageUsage(ctx context.Context, mirrorConfigID uuid.UUID) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(pp.size_bytes), 0)
+		FROM provider_platforms pp
+		JOIN provider_versions pv ON pv.id = pp.provider_version_id
+		WHERE pv.provider_id IN (SELECT provider_id FROM mirrored_providers WHERE mirror_config_id = $1)
+	`
+
+	var used int64
+	if err := r.db.QueryRowContext(ctx, query, mirrorConfigID).Scan(&used); err != nil {
+		return 0, fmt.Errorf("failed to get mirror storage usage: %w", err)
+	}
+	return used, nil
+}
+
+// SetStorageAlertLevel records the highest storage usage threshold notified
+// for the mirror.
+func (r *MirrorRepository) SetStorageAlertLevel(ctx context.Context, id uuid.UUID, level int) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE mirror_configurations SET storage_alert_level = $2 WHERE id = $1`, id, level,
+	); err != nil {
+		return fmt.Errorf("failed to update mirror storage alert level: %w", err)
+	}
+	return nil
+}
+
 // GetActiveSyncHistory retrieves the currently running sync for a mirror configuration
 func (r *MirrorRepository) GetActiveSyncHistory(ctx context.Context, mirrorConfigID uuid.UUID) (*models.MirrorSyncHistory, error) {
 	query := `
@@ -419,7 +596,8 @@ func (r *MirrorRepository) CreateMirroredProvider(ctx context.Context, mp *model
 func (r *MirrorRepository) GetMirroredProvider(ctx context.Context, mirrorConfigID uuid.UUID, upstreamNamespace, upstreamType string) (*models.MirroredProvider, error) {
 	query := `
 		SELECT id, mirror_config_id, provider_id, upstream_namespace, upstream_type,
-		       last_synced_at, last_sync_version, sync_enabled, created_at
+		       last_synced_at, last_sync_version, sync_enabled, created_at,
+		       upstream_etag, upstream_last_modified
 		FROM mirrored_providers
 		WHERE mirror_config_id = $1 AND upstream_namespace = $2 AND upstream_type = $3
 	`
@@ -457,6 +635,177 @@ func (r *MirrorRepository) GetMirroredProviderByProviderID(ctx context.Context,
 	return &mp, nil
 }
 
+// ListProviderCopySources returns the mirrors every stored copy of
+// namespace/providerType was synced from, oldest copy first. Copies in
+// different organizations are separate providers; a copy no mirror synced
+// appears once with a nil MirrorConfigID.
+func (r *MirrorRepository) ListProviderCopySources(ctx context.Context, namespace, providerType string) ([]models.ProviderCopySource, error) {
+	query := `
+		SELECT p.id AS provider_id, m.id AS mirror_config_id, m.organization_id,
+		       COALESCE(m.private, false) AS private
+		FROM providers p
+		LEFT JOIN mirrored_providers mp ON mp.provider_id = p.id
+		LEFT JOIN mirror_configurations m ON m.id = mp.mirror_config_id
+		WHERE p.namespace = $1 AND p.type = $2
+		ORDER BY p.created_at, p.id
+	`
+
+	var sources []models.ProviderCopySource
+	if err := r.db.SelectContext(ctx, &sources, query, namespace, providerType); err != nil {
+		return nil, fmt.Errorf("failed to list provider copy sources: %w", err)
+	}
+	return sources, nil
+}
+
+// ListVersionMirrorConfigs maps each version of a provider that a mirror
+// synced to the IDs of the mirrors that synced it. Versions that reached the
+// provider another way are absent.
+func (r *MirrorRepository) ListVersionMirrorConfigs(ctx context.Context, providerID string) (map[string][]string, error) {
+	query := `
+		SELECT mpv.provider_version_id, mp.mirror_config_id
+		FROM mirrored_provider_versions mpv
+		JOIN mirrored_providers mp ON mp.id = mpv.mirrored_provider_id
+		WHERE mp.provider_id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list version mirror configs: %w", err)
+	}
+	defer rows.Close()
+
+	configs := make(map[string][]string)
+	for rows.Next() {
+		var versionID, configID string
+		if err := rows.Scan(&versionID, &configID); err != nil {
+			return nil, fmt.Errorf("failed to scan version mirror config: %w", err)
+		}
+		configs[versionID] = append(configs[versionID], configID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list version mirror configs: %w", err)
+	}
+	return configs, nil
+}
+
+// ListMirrorsForProvider returns the mirror configurations tracking
+// providerID in mirrored_providers, by name.
+func (r *MirrorRepository) ListMirrorsForProvider(ctx context.Context, providerID string) ([]models.ProviderMirrorRef, error) {
+	query := `
+		SELECT mp.mirror_config_id, m.name
+		FROM mirrored_providers mp
+		JOIN mirror_configurations m ON m.id = mp.mirror_config_id
+		WHERE mp.provider_id = $1
+		ORDER BY m.name
+	`
+
+	var refs []models.ProviderMirrorRef
+	if err := r.db.SelectContext(ctx, &refs, query, providerID); err != nil {
+		return nil, fmt.Errorf("failed to list mirrors for provider: %w", err)
+	}
+	return refs, nil
+}
+
+// ListProvidersForMirror returns the providers mirror configuration
+// mirrorConfigID tracks, flagging those it exclusively created.
+func (r *MirrorRepository) ListProvidersForMirror(ctx context.Context, mirrorConfigID uuid.UUID) ([]models.MirrorProviderRef, error) {
+	query := `
+		SELECT p.id AS provider_id, p.namespace, p.type,
+		       NOT EXISTS (
+		           SELECT 1 FROM provider_versions pv
+		           WHERE pv.provider_id = p.id
+		             AND NOT EXISTS (
+		                 SELECT 1 FROM mirrored_provider_versions mpv
+		                 WHERE mpv.provider_version_id = pv.id AND mpv.mirrored_provider_id = mp.id
+		             )
+		       ) AS exclusive
+		FROM mirrored_providers mp
+		JOIN providers p ON p.id = mp.provider_id
+		WHERE mp.mirror_config_id = $1
+		ORDER BY p.namespace, p.type
+	`
+
+	var refs []models.MirrorProviderRef
+	if err := r.db.SelectContext(ctx, &refs, query, mirrorConfigID); err != nil {
+		return nil, fmt.Errorf("failed to list providers for mirror: %w", err)
+	}
+	return refs, nil
+}
+
+// DetachProvider removes the mirrored_providers rows tracking providerID and,
+// in the same transaction, changes the filters of each mirror that tracked it
+// so its next sync does not recreate namespace/providerType. It returns the
+// mirrors detached.
+func (r *MirrorRepository) DetachProvider(ctx context.Context, providerID, namespace, providerType string) ([]models.ProviderMirrorRef, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var refs []models.ProviderMirrorRef
+	err = tx.SelectContext(ctx, &refs, `SELECT mp.mirror_config_id, m.name
+			  FROM mirrored_providers mp
+			  JOIN mirror_configurations m ON m.id = mp.mirror_config_id
+			  WHERE mp.provider_id = $1
+			  ORDER BY m.name`, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mirrors for provider: %w", err)
+	}
+
+	for _, ref := range refs {
+		f, err := lockMirrorFilters(ctx, tx, ref.MirrorConfigID)
+		if err != nil {
+			return nil, err
+		}
+		if !detachProviderFilters(f, namespace, providerType) {
+			continue
+		}
+		if err := updateMirrorFilters(ctx, tx, ref.MirrorConfigID, f); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mirrored_providers WHERE provider_id = $1`, providerID); err != nil {
+		return nil, fmt.Errorf("failed to delete mirrored provider: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return refs, nil
+}
+
+// detachProviderFilters changes f so the mirror stops syncing
+// namespace/providerType, reporting whether it changed anything. The type is
+// removed from provider_filter when namespace is the only namespace synced and
+// other types remain, and the namespace is removed from namespace_filter when
+// providerType is the only type and other namespaces remain. Otherwise an
+// exclusion entry (models.ProviderFilterExclusion) is added, which leaves the
+// rest of the namespace x provider cross product untouched.
+func detachProviderFilters(f *lockedFilters, namespace, providerType string) bool {
+	types, excluded := models.SplitProviderFilter(f.providers)
+	if excluded[namespace+"/"+providerType] {
+		return false
+	}
+	namespaces := f.namespaces
+	if len(namespaces) == 0 && len(types) > 0 {
+		namespaces = []string{"hashicorp"} // the sync job's default
+	}
+	if !slices.Contains(types, providerType) || !slices.Contains(namespaces, namespace) {
+		return false // the filters no longer cover it
+	}
+
+	switch {
+	case len(namespaces) == 1 && len(types) > 1:
+		f.providers = slices.DeleteFunc(slices.Clone(f.providers), func(e string) bool { return e == providerType })
+	case len(types) == 1 && len(f.namespaces) > 1:
+		f.namespaces = slices.DeleteFunc(slices.Clone(f.namespaces), func(e string) bool { return e == namespace })
+	default:
+		f.providers = append(f.providers, models.ProviderFilterExclusion(namespace, providerType))
+	}
+	return true
+}
+
 // UpdateMirroredProvider updates a mirrored provider's sync information
 func (r *MirrorRepository) UpdateMirroredProvider(ctx context.Context, mp *models.MirroredProvider) error {
 	query := `
@@ -479,6 +828,23 @@ func (r *MirrorRepository) UpdateMirroredProvider(ctx context.Context, mp *model
 	return nil
 }
 
+// SetMirroredProviderValidators stores the cache validators of the upstream
+// version listing a sync of the mirrored provider completed with. Nil clears
+// them, so the next sync lists the versions unconditionally.
+func (r *MirrorRepository) SetMirroredProviderValidators(ctx context.Context, id uuid.UUID, etag, lastModified *string) error {
+	query := `
+		UPDATE mirrored_providers
+		SET upstream_etag = $2, upstream_last_modified = $3
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, etag, lastModified); err != nil {
+		return fmt.Errorf("failed to update mirrored provider validators: %w", err)
+	}
+
+	return nil
+}
+
 // ListMirroredProviders retrieves all mirrored providers for a mirror configuration
 func (r *MirrorRepository) ListMirroredProviders(ctx context.Context, mirrorConfigID uuid.UUID) ([]models.MirroredProvider, error) {
 	query := `
@@ -641,9 +1007,11 @@ func (r *MirrorRepository) GetPullThroughConfigsForProvider(
 	ctx context.Context, orgID, namespace, providerType string,
 ) ([]*models.MirrorConfiguration, error) {
 	const q = `
-		SELECT id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
+		SELECT id, name, description, upstream_registry_url, organization_id, private, namespace_filter, provider_filter,
 		       version_filter, platform_filter, enabled, sync_interval_hours, requires_approval, auto_approve_rules, pull_through_enabled,
-		       pull_through_cache_ttl_hours, last_sync_at, last_sync_status, last_sync_error,
+		       pull_through_cache_ttl_hours, docs_passthrough_enabled, docs_cache_ttl_hours,
+		       history_retention_count, history_retention_days, max_storage_bytes, storage_alert_level,
+		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at, created_by
 		FROM mirror_configurations
 		WHERE organization_id = $1
@@ -659,7 +1027,8 @@ func (r *MirrorRepository) GetPullThroughConfigsForProvider(
 	// Filter by namespace and provider in Go — the DB column stores a JSON array.
 	var matched []*models.MirrorConfiguration
 	for _, cfg := range all {
-		if matchesJSONFilter(cfg.NamespaceFilter, namespace) && matchesJSONFilter(cfg.ProviderFilter, providerType) {
+		if matchesJSONFilter(cfg.NamespaceFilter, namespace) && matchesJSONFilter(cfg.ProviderFilter, providerType) &&
+			!excludedByJSONFilter(cfg.ProviderFilter, namespace, providerType) {
 			matched = append(matched, cfg)
 		}
 	}
@@ -704,3 +1073,17 @@ func matchesJSONFilter(filter *string, value string) bool {
 	}
 	return false
 }
+
+// excludedByJSONFilter reports whether the provider_filter JSON array holds
+// an exclusion entry for namespace/providerType.
+func excludedByJSONFilter(filter *string, namespace, providerType string) bool {
+	exclusion := models.ProviderFilterExclusion(namespace, providerType)
+	if filter == nil || !strings.Contains(*filter, exclusion) {
+		return false
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(*filter), &values); err != nil {
+		return false
+	}
+	return slices.Contains(values, exclusion)
+}
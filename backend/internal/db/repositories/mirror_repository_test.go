@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"slices"
 	"testing"
 	"time"
 
@@ -221,7 +222,8 @@ func TestMirrorList_EnabledOnly(t *testing.T) {
 
 func TestMirrorUpdate_Success(t *testing.T) {
 	repo, mock := newMirrorRepo(t)
-	mock.ExpectExec("UPDATE mirror_configurations").
+	// Editing a mirror drops its providers' upstream validators.
+	mock.ExpectExec("(?s)UPDATE mirrored_providers.*SET upstream_etag = NULL.*WHERE mirror_config_id = \\$1.*UPDATE mirror_configurations").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	cfg := &models.MirrorConfiguration{
@@ -364,6 +366,107 @@ func TestGetSyncHistory_Success(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ListSyncHistoryPaginated / PruneSyncHistory / GetSyncHistoryStats
+// ---------------------------------------------------------------------------
+
+func TestListSyncHistoryPaginated_Success(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	mirrorID := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	mock.ExpectQuery("SELECT COUNT.*FROM mirror_sync_history").
+		WithArgs(mirrorID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	mock.ExpectQuery("SELECT id.*FROM mirror_sync_history.*LIMIT").
+		WithArgs(mirrorID, 5, 10).
+		WillReturnRows(sampleSyncHistoryRow())
+
+	hist, total, err := repo.ListSyncHistoryPaginated(context.Background(), mirrorID, 5, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hist) != 1 || total != 12 {
+		t.Errorf("len = %d, total = %d, want 1 and 12", len(hist), total)
+	}
+}
+
+func TestListSyncHistoryPaginated_CountError(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	mock.ExpectQuery("SELECT COUNT.*FROM mirror_sync_history").
+		WillReturnError(errDB)
+
+	if _, _, err := repo.ListSyncHistoryPaginated(context.Background(), uuid.New(), 5, 0); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestPruneSyncHistory_Success(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	mirrorID := uuid.New()
+	mock.ExpectExec("DELETE FROM mirror_sync_history.*status <> 'running'").
+		WithArgs(mirrorID, 500, 90).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := repo.PruneSyncHistory(context.Background(), mirrorID, 500, 90)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("pruned = %d, want 3", n)
+	}
+}
+
+func TestPruneSyncHistory_NoLimits(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	if n, err := repo.PruneSyncHistory(context.Background(), uuid.New(), 0, 0); err != nil || n != 0 {
+		t.Fatalf("PruneSyncHistory = (%d, %v), want (0, nil)", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected query: %v", err)
+	}
+}
+
+func TestGetPlatformUsage(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	id := uuid.New()
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM platforms p.*LEFT JOIN provider_download_stats").
+		WithArgs(id, since).
+		WillReturnRows(sqlmock.NewRows([]string{"os", "arch", "downloads"}).
+			AddRow("linux", "amd64", 5).
+			AddRow("freebsd", "arm", 0))
+
+	usage, err := repo.GetPlatformUsage(context.Background(), id, since)
+	if err != nil {
+		t.Fatalf("GetPlatformUsage: %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("usage = %+v", usage)
+	}
+	if usage[0].Downloads != 5 || usage[0].Unused {
+		t.Errorf("linux/amd64 = %+v, want 5 downloads and used", usage[0])
+	}
+	if usage[1].Downloads != 0 || !usage[1].Unused {
+		t.Errorf("freebsd/arm = %+v, want unused", usage[1])
+	}
+}
+
+func TestGetSyncHistoryStats_NoRuns(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	mock.ExpectQuery("SELECT COUNT.*FROM mirror_sync_history").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"total_runs", "successful_runs", "failed_runs", "cancelled_runs",
+			"window_start", "last_success_at", "last_failure_at",
+		}).AddRow(0, 0, 0, 0, nil, nil, nil))
+
+	stats, err := repo.GetSyncHistoryStats(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.TotalRuns != 0 || stats.SuccessRate != nil {
+		t.Errorf("stats = %+v, want no runs and nil success rate", stats)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // GetActiveSyncHistory
 // ---------------------------------------------------------------------------
@@ -471,6 +574,192 @@ func TestGetMirroredProviderByProviderID_Found(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ListProviderCopySources / ListVersionMirrorConfigs
+// ---------------------------------------------------------------------------
+
+func TestListProviderCopySources(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	mock.ExpectQuery("SELECT p.id AS provider_id.*FROM providers p.*LEFT JOIN mirrored_providers.*WHERE p.namespace = \\$1 AND p.type = \\$2").
+		WithArgs("hashicorp", "aws").
+		WillReturnRows(sqlmock.NewRows([]string{"provider_id", "mirror_config_id", "organization_id", "private"}).
+			AddRow("prov-1", nil, nil, false).
+			AddRow("prov-2", "mc-1", "org-1", true))
+
+	sources, err := repo.ListProviderCopySources(context.Background(), "hashicorp", "aws")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("len = %d, want 2", len(sources))
+	}
+	if sources[0].MirrorConfigID != nil {
+		t.Errorf("unmirrored copy has MirrorConfigID %v", *sources[0].MirrorConfigID)
+	}
+	if !sources[1].Private || sources[1].OrganizationID == nil || *sources[1].OrganizationID != "org-1" {
+		t.Errorf("sources[1] = %+v, want private mirror of org-1", sources[1])
+	}
+}
+
+func TestListVersionMirrorConfigs(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	mock.ExpectQuery("SELECT mpv.provider_version_id, mp.mirror_config_id.*WHERE mp.provider_id = \\$1").
+		WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows([]string{"provider_version_id", "mirror_config_id"}).
+			AddRow("v1", "mc-1").
+			AddRow("v1", "mc-2").
+			AddRow("v2", "mc-2"))
+
+	configs, err := repo.ListVersionMirrorConfigs(context.Background(), "prov-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(configs["v1"]) != 2 || len(configs["v2"]) != 1 {
+		t.Errorf("configs = %v, want v1 from two mirrors and v2 from one", configs)
+	}
+}
+
+func TestListMirrorsForProvider(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	cfgID := uuid.New()
+	mock.ExpectQuery("SELECT mp.mirror_config_id, m.name.*WHERE mp.provider_id = \\$1").
+		WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows([]string{"mirror_config_id", "name"}).AddRow(cfgID, "hashicorp-mirror"))
+
+	refs, err := repo.ListMirrorsForProvider(context.Background(), "prov-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(refs) != 1 || refs[0].MirrorConfigID != cfgID || refs[0].Name != "hashicorp-mirror" {
+		t.Errorf("refs = %+v, want hashicorp-mirror", refs)
+	}
+}
+
+func TestListProvidersForMirror(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	cfgID := uuid.New()
+	mock.ExpectQuery("SELECT p.id AS provider_id.*AS exclusive.*WHERE mp.mirror_config_id = \\$1").
+		WithArgs(cfgID).
+		WillReturnRows(sqlmock.NewRows([]string{"provider_id", "namespace", "type", "exclusive"}).
+			AddRow(uuid.New(), "hashicorp", "aws", true).
+			AddRow(uuid.New(), "hashicorp", "google", false))
+
+	refs, err := repo.ListProvidersForMirror(context.Background(), cfgID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(refs) != 2 || !refs[0].Exclusive || refs[1].Exclusive {
+		t.Errorf("refs = %+v, want aws exclusive and google shared", refs)
+	}
+}
+
+func TestDetachProvider(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	cfgID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mp.mirror_config_id, m.name.*WHERE mp.provider_id = \\$1").
+		WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows([]string{"mirror_config_id", "name"}).AddRow(cfgID, "hashicorp-mirror"))
+	mock.ExpectQuery("SELECT namespace_filter, provider_filter.*FOR UPDATE").
+		WithArgs(cfgID).
+		WillReturnRows(sqlmock.NewRows([]string{"namespace_filter", "provider_filter"}).
+			AddRow(`["hashicorp"]`, `["aws","google"]`))
+	mock.ExpectExec("UPDATE mirror_configurations").
+		WithArgs(cfgID, `["hashicorp"]`, `["google"]`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM mirrored_providers WHERE provider_id = \\$1").
+		WithArgs("prov-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	refs, err := repo.DetachProvider(context.Background(), "prov-1", "hashicorp", "aws")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Name != "hashicorp-mirror" {
+		t.Errorf("refs = %+v, want hashicorp-mirror", refs)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestDetachProviderFilters(t *testing.T) {
+	tests := []struct {
+		name           string
+		namespaces     []string
+		providers      []string
+		wantChanged    bool
+		wantNamespaces []string
+		wantProviders  []string
+	}{
+		{
+			name:           "type removed from single namespace",
+			namespaces:     []string{"hashicorp"},
+			providers:      []string{"aws", "google"},
+			wantChanged:    true,
+			wantNamespaces: []string{"hashicorp"},
+			wantProviders:  []string{"google"},
+		},
+		{
+			name:          "type removed under default namespace",
+			providers:     []string{"aws", "google"},
+			wantChanged:   true,
+			wantProviders: []string{"google"},
+		},
+		{
+			name:           "namespace removed for single type",
+			namespaces:     []string{"hashicorp", "acme"},
+			providers:      []string{"aws"},
+			wantChanged:    true,
+			wantNamespaces: []string{"acme"},
+			wantProviders:  []string{"aws"},
+		},
+		{
+			name:           "exclusion added to a cross product",
+			namespaces:     []string{"hashicorp", "acme"},
+			providers:      []string{"aws", "google"},
+			wantChanged:    true,
+			wantNamespaces: []string{"hashicorp", "acme"},
+			wantProviders:  []string{"aws", "google", "!hashicorp/aws"},
+		},
+		{
+			name:           "exclusion added to the only pair",
+			namespaces:     []string{"hashicorp"},
+			providers:      []string{"aws"},
+			wantChanged:    true,
+			wantNamespaces: []string{"hashicorp"},
+			wantProviders:  []string{"aws", "!hashicorp/aws"},
+		},
+		{
+			name:           "already excluded",
+			namespaces:     []string{"hashicorp"},
+			providers:      []string{"aws", "!hashicorp/aws"},
+			wantNamespaces: []string{"hashicorp"},
+			wantProviders:  []string{"aws", "!hashicorp/aws"},
+		},
+		{
+			name:           "not covered by the filters",
+			namespaces:     []string{"acme"},
+			providers:      []string{"aws"},
+			wantNamespaces: []string{"acme"},
+			wantProviders:  []string{"aws"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &lockedFilters{namespaces: tt.namespaces, providers: tt.providers}
+			changed := detachProviderFilters(f, "hashicorp", "aws")
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !slices.Equal(f.namespaces, tt.wantNamespaces) || !slices.Equal(f.providers, tt.wantProviders) {
+				t.Errorf("filters = (%v, %v), want (%v, %v)", f.namespaces, f.providers, tt.wantNamespaces, tt.wantProviders)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // UpdateMirroredProvider
 // ---------------------------------------------------------------------------
@@ -486,6 +775,26 @@ func TestUpdateMirroredProvider_Success(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// SetMirroredProviderValidators
+// ---------------------------------------------------------------------------
+
+func TestSetMirroredProviderValidators(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	id := uuid.New()
+	etag := `"abc"`
+	mock.ExpectExec("UPDATE mirrored_providers.*SET upstream_etag = \\$2, upstream_last_modified = \\$3").
+		WithArgs(id, &etag, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetMirroredProviderValidators(context.Background(), id, &etag, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ListMirroredProviders
 // ---------------------------------------------------------------------------
@@ -943,3 +1252,47 @@ func TestGetPullThroughConfigsForProvider_SpecificitySort(t *testing.T) {
 		t.Errorf("result[2] = %v, want id1 (%v)", result[2].ID, id1)
 	}
 }
+
+func TestExcludedByJSONFilter(t *testing.T) {
+	f := `["aws","!hashicorp/aws"]`
+	if !excludedByJSONFilter(&f, "hashicorp", "aws") {
+		t.Error("expected hashicorp/aws to be excluded")
+	}
+	if excludedByJSONFilter(&f, "acme", "aws") {
+		t.Error("expected acme/aws not to be excluded")
+	}
+	if excludedByJSONFilter(nil, "hashicorp", "aws") {
+		t.Error("nil filter should exclude nothing")
+	}
+}
+
+func TestMirrorGetStorageUsage(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	id := uuid.New()
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(pp.size_bytes\), 0\)`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(int64(4096)))
+
+	used, err := repo.GetStorageUsage(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if used != 4096 {
+		t.Errorf("used = %d, want 4096", used)
+	}
+}
+
+func TestMirrorSetStorageAlertLevel(t *testing.T) {
+	repo, mock := newMirrorRepo(t)
+	id := uuid.New()
+	mock.ExpectExec(`UPDATE mirror_configurations SET storage_alert_level`).
+		WithArgs(id, 80).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetStorageAlertLevel(context.Background(), id, 80); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+// Package repositories - mirror_sync_lease_repository.go persists the leases
+// that keep two replicas (or a scheduled and a manual trigger) from syncing
+// the same provider mirror at once. A lease whose heartbeat is older than the
+// TTL is treated as abandoned and can be taken over.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// MirrorSyncLeaseRepository handles mirror_sync_leases database operations.
+type MirrorSyncLeaseRepository struct {
+	db *sql.DB
+}
+
+// NewMirrorSyncLeaseRepository creates a new mirror sync lease repository.
+func NewMirrorSyncLeaseRepository(db *sql.DB) *MirrorSyncLeaseRepository {
+	return &MirrorSyncLeaseRepository{db: db}
+}
+
+// TryAcquire takes the sync lease of a mirror for holder. It succeeds when no
+// lease exists or the existing lease's heartbeat is older than ttl, and
+// reports false while another live lease is held.
+func (r *MirrorSyncLeaseRepository) TryAcquire(ctx context.Context, mirrorID uuid.UUID, holder string, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO mirror_sync_leases (mirror_config_id, holder)
+		VALUES ($1, $2)
+		ON CONFLICT (mirror_config_id) DO UPDATE
+			SET holder       = EXCLUDED.holder,
+			    acquired_at  = NOW(),
+			    heartbeat_at = NOW()
+			WHERE mirror_sync_leases.heartbeat_at < NOW() - make_interval(secs => $3)
+		RETURNING holder
+	`
+	var got string
+	err := r.db.QueryRowContext(ctx, query, mirrorID, holder, ttl.Seconds()).Scan(&got)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire mirror sync lease: %w", err)
+	}
+	return true, nil
+}
+
+// Heartbeat refreshes holder's lease on a mirror. It reports false when the
+// lease is no longer held by holder (it was cleared or taken over).
+func (r *MirrorSyncLeaseRepository) Heartbeat(ctx context.Context, mirrorID uuid.UUID, holder string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE mirror_sync_leases
+		SET heartbeat_at = NOW()
+		WHERE mirror_config_id = $1 AND holder = $2
+	`, mirrorID, holder)
+	if err != nil {
+		return false, fmt.Errorf("failed to heartbeat mirror sync lease: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to heartbeat mirror sync lease: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Release deletes holder's lease on a mirror. A lease held by someone else is
+// left alone.
+func (r *MirrorSyncLeaseRepository) Release(ctx context.Context, mirrorID uuid.UUID, holder string) error {
+	if _, err := r.db.ExecContext(ctx, `
+		DELETE FROM mirror_sync_leases WHERE mirror_config_id = $1 AND holder = $2
+	`, mirrorID, holder); err != nil {
+		return fmt.Errorf("failed to release mirror sync lease: %w", err)
+	}
+	return nil
+}
+
+// Get returns the sync lease of a mirror, or nil when none is held. Heartbeat
+// age and staleness are computed against ttl by the database clock.
+func (r *MirrorSyncLeaseRepository) Get(ctx context.Context, mirrorID uuid.UUID, ttl time.Duration) (*models.MirrorSyncLease, error) {
+	query := `
+		SELECT mirror_config_id, holder, acquired_at, heartbeat_at,
+		       EXTRACT(EPOCH FROM NOW() - heartbeat_at)::BIGINT,
+		       heartbeat_at < NOW() - make_interval(secs => $2)
+		FROM mirror_sync_leases
+		WHERE mirror_config_id = $1
+	`
+	lease := &models.MirrorSyncLease{}
+	err := r.db.QueryRowContext(ctx, query, mirrorID, ttl.Seconds()).Scan(
+		&lease.MirrorConfigID,
+		&lease.Holder,
+		&lease.AcquiredAt,
+		&lease.HeartbeatAt,
+		&lease.HeartbeatAgeSeconds,
+		&lease.Stale,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mirror sync lease: %w", err)
+	}
+	return lease, nil
+}
+
+// ForceRelease deletes the sync lease of a mirror whoever holds it, and
+// reports whether one existed. The holder's next heartbeat fails, which
+// stops its sync if it is still running.
+func (r *MirrorSyncLeaseRepository) ForceRelease(ctx context.Context, mirrorID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM mirror_sync_leases WHERE mirror_config_id = $1`, mirrorID)
+	if err != nil {
+		return false, fmt.Errorf("failed to clear mirror sync lease: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to clear mirror sync lease: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func newMirrorSyncLeaseRepo(t *testing.T) (*MirrorSyncLeaseRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewMirrorSyncLeaseRepository(db), mock
+}
+
+func TestMirrorSyncLease_TryAcquire(t *testing.T) {
+	mirrorID := uuid.New()
+
+	t.Run("free or stale lease is taken", func(t *testing.T) {
+		repo, mock := newMirrorSyncLeaseRepo(t)
+		mock.ExpectQuery("INSERT INTO mirror_sync_leases.*ON CONFLICT.*WHERE mirror_sync_leases.heartbeat_at").
+			WithArgs(mirrorID, "host-a", float64(120)).
+			WillReturnRows(sqlmock.NewRows([]string{"holder"}).AddRow("host-a"))
+
+		ok, err := repo.TryAcquire(context.Background(), mirrorID, "host-a", 2*time.Minute)
+		if err != nil || !ok {
+			t.Errorf("TryAcquire = (%v, %v), want (true, nil)", ok, err)
+		}
+	})
+
+	t.Run("live lease held elsewhere", func(t *testing.T) {
+		repo, mock := newMirrorSyncLeaseRepo(t)
+		mock.ExpectQuery("INSERT INTO mirror_sync_leases").
+			WillReturnRows(sqlmock.NewRows([]string{"holder"}))
+
+		ok, err := repo.TryAcquire(context.Background(), mirrorID, "host-b", 2*time.Minute)
+		if err != nil || ok {
+			t.Errorf("TryAcquire = (%v, %v), want (false, nil)", ok, err)
+		}
+	})
+}
+
+func TestMirrorSyncLease_Heartbeat(t *testing.T) {
+	repo, mock := newMirrorSyncLeaseRepo(t)
+	mirrorID := uuid.New()
+	mock.ExpectExec("UPDATE mirror_sync_leases SET heartbeat_at").
+		WithArgs(mirrorID, "host-a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE mirror_sync_leases SET heartbeat_at").
+		WithArgs(mirrorID, "host-a").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if held, err := repo.Heartbeat(context.Background(), mirrorID, "host-a"); err != nil || !held {
+		t.Errorf("Heartbeat = (%v, %v), want (true, nil)", held, err)
+	}
+	if held, err := repo.Heartbeat(context.Background(), mirrorID, "host-a"); err != nil || held {
+		t.Errorf("Heartbeat after clear = (%v, %v), want (false, nil)", held, err)
+	}
+}
+
+func TestMirrorSyncLease_Get(t *testing.T) {
+	repo, mock := newMirrorSyncLeaseRepo(t)
+	mirrorID := uuid.New()
+	now := time.Now()
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_leases").
+		WithArgs(mirrorID, float64(120)).
+		WillReturnRows(sqlmock.NewRows([]string{"mirror_config_id", "holder", "acquired_at", "heartbeat_at", "age", "stale"}).
+			AddRow(mirrorID, "host-a", now.Add(-time.Hour), now.Add(-time.Hour), int64(3600), true))
+
+	lease, err := repo.Get(context.Background(), mirrorID, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if lease == nil || lease.Holder != "host-a" || !lease.Stale || lease.HeartbeatAgeSeconds != 3600 {
+		t.Errorf("Get = %+v", lease)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM mirror_sync_leases").
+		WillReturnRows(sqlmock.NewRows([]string{"mirror_config_id", "holder", "acquired_at", "heartbeat_at", "age", "stale"}))
+	if lease, err := repo.Get(context.Background(), mirrorID, 2*time.Minute); err != nil || lease != nil {
+		t.Errorf("Get without lease = (%v, %v), want (nil, nil)", lease, err)
+	}
+}
+
+func TestMirrorSyncLease_ReleaseAndForceRelease(t *testing.T) {
+	repo, mock := newMirrorSyncLeaseRepo(t)
+	mirrorID := uuid.New()
+	mock.ExpectExec("DELETE FROM mirror_sync_leases WHERE mirror_config_id = \\$1 AND holder = \\$2").
+		WithArgs(mirrorID, "host-a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM mirror_sync_leases WHERE mirror_config_id = \\$1$").
+		WithArgs(mirrorID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.Release(context.Background(), mirrorID, "host-a"); err != nil {
+		t.Errorf("Release: %v", err)
+	}
+	if cleared, err := repo.ForceRelease(context.Background(), mirrorID); err != nil || cleared {
+		t.Errorf("ForceRelease = (%v, %v), want (false, nil)", cleared, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+// Package repositories - module_approval_repository.go persists per-organization
+// module version approvals and the approved_only organization policy.
+//
+// Both tables live on the registry's own connection. Organization IDs come
+// from the identity repositories; callers pass them in rather than this
+// repository joining against organizations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ModuleApprovalRepository handles module version approval database operations.
+type ModuleApprovalRepository struct {
+	db *sql.DB
+}
+
+// NewModuleApprovalRepository creates a new module approval repository.
+func NewModuleApprovalRepository(db *sql.DB) *ModuleApprovalRepository {
+	return &ModuleApprovalRepository{db: db}
+}
+
+// Approve records (or refreshes) an organization's approval of a module
+// version and fills in its ID and timestamp.
+func (r *ModuleApprovalRepository) Approve(ctx context.Context, a *models.ModuleVersionApproval) error {
+	query := `
+		INSERT INTO module_version_approvals (module_version_id, organization_id, approved_by, notes)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (module_version_id, organization_id) DO UPDATE SET
+			approved_by = EXCLUDED.approved_by,
+			notes       = EXCLUDED.notes,
+			approved_at = NOW()
+		RETURNING id, approved_at
+	`
+	err := r.db.QueryRowContext(ctx, query, a.ModuleVersionID, a.OrganizationID, a.ApprovedBy, a.Notes).
+		Scan(&a.ID, &a.ApprovedAt)
+	if err != nil {
+		return fmt.Errorf("failed to approve module version: %w", err)
+	}
+	return nil
+}
+
+// Revoke removes an organization's approval of a module version. It reports
+// whether an approval existed.
+func (r *ModuleApprovalRepository) Revoke(ctx context.Context, moduleVersionID, orgID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM module_version_approvals WHERE module_version_id = $1 AND organization_id = $2`,
+		moduleVersionID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke module version approval: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke module version approval: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ListForOrganization returns an organization's approvals, newest first,
+// optionally narrowed to one module. Empty filter values are ignored.
+func (r *ModuleApprovalRepository) ListForOrganization(ctx context.Context, orgID, namespace, name, system string) ([]*models.ModuleVersionApproval, error) {
+	query := `
+		SELECT a.id, a.module_version_id, a.organization_id, a.approved_by, a.approved_at, a.notes,
+		       m.namespace, m.name, m.system, mv.version
+		FROM module_version_approvals a
+		JOIN module_versions mv ON mv.id = a.module_version_id
+		JOIN modules m ON m.id = mv.module_id
+		WHERE a.organization_id = $1
+		  AND ($2 = '' OR m.namespace = $2)
+		  AND ($3 = '' OR m.name = $3)
+		  AND ($4 = '' OR m.system = $4)
+		ORDER BY a.approved_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID, namespace, name, system)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list module version approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := []*models.ModuleVersionApproval{}
+	for rows.Next() {
+		a := &models.ModuleVersionApproval{}
+		if err := rows.Scan(&a.ID, &a.ModuleVersionID, &a.OrganizationID, &a.ApprovedBy, &a.ApprovedAt, &a.Notes,
+			&a.Namespace, &a.Name, &a.System, &a.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan module version approval: %w", err)
+		}
+		approvals = append(approvals, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate module version approvals: %w", err)
+	}
+	return approvals, nil
+}
+
+// IsApproved reports whether any of the given organizations has approved the
+// module version.
+func (r *ModuleApprovalRepository) IsApproved(ctx context.Context, moduleVersionID string, orgIDs []string) (bool, error) {
+	if len(orgIDs) == 0 {
+		return false, nil
+	}
+	var approved bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM module_version_approvals
+			WHERE module_version_id = $1 AND organization_id::text = ANY($2)
+		)`, moduleVersionID, pq.Array(orgIDs)).Scan(&approved)
+	if err != nil {
+		return false, fmt.Errorf("failed to check module version approval: %w", err)
+	}
+	return approved, nil
+}
+
+// GetPolicy returns an organization's module policy, or nil when it has none.
+func (r *ModuleApprovalRepository) GetPolicy(ctx context.Context, orgID string) (*models.OrgModulePolicy, error) {
+	p := &models.OrgModulePolicy{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT organization_id, approved_only, default_max_versions, default_version_cap_mode, alias_duplicate_versions, created_at, updated_at
+		 FROM org_module_policies WHERE organization_id = $1`,
+		orgID).Scan(&p.OrganizationID, &p.ApprovedOnly, &p.DefaultMaxVersions, &p.DefaultVersionCapMode, &p.AliasDuplicateVersions, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module policy: %w", err)
+	}
+	return p, nil
+}
+
+// UpsertPolicy creates or replaces the module policy for p.OrganizationID and
+// fills in its timestamps.
+func (r *ModuleApprovalRepository) UpsertPolicy(ctx context.Context, p *models.OrgModulePolicy) error {
+	query := `
+		INSERT INTO org_module_policies (organization_id, approved_only, default_max_versions, default_version_cap_mode, alias_duplicate_versions)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			approved_only            = EXCLUDED.approved_only,
+			default_max_versions     = EXCLUDED.default_max_versions,
+			default_version_cap_mode = EXCLUDED.default_version_cap_mode,
+			alias_duplicate_versions = EXCLUDED.alias_duplicate_versions,
+			updated_at               = NOW()
+		RETURNING created_at, updated_at
+	`
+	if err := r.db.QueryRowContext(ctx, query, p.OrganizationID, p.ApprovedOnly, p.DefaultMaxVersions, p.DefaultVersionCapMode, p.AliasDuplicateVersions).Scan(&p.CreatedAt, &p.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to upsert module policy: %w", err)
+	}
+	return nil
+}
+
+// ApprovedOnlyOrganizations returns the subset of orgIDs whose module policy
+// has approved_only set.
+func (r *ModuleApprovalRepository) ApprovedOnlyOrganizations(ctx context.Context, orgIDs []string) ([]string, error) {
+	if len(orgIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT organization_id FROM org_module_policies
+		WHERE approved_only AND organization_id::text = ANY($1)
+		ORDER BY organization_id`, pq.Array(orgIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approved-only organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var enforcing []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan organization id: %w", err)
+		}
+		enforcing = append(enforcing, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate approved-only organizations: %w", err)
+	}
+	return enforcing, nil
+}
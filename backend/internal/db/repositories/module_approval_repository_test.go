@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newModuleApprovalRepo(t *testing.T) (*ModuleApprovalRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewModuleApprovalRepository(db), mock
+}
+
+func TestModuleApproval_Approve(t *testing.T) {
+	repo, mock := newModuleApprovalRepo(t)
+	notes := "reviewed"
+	uid := "user-1"
+	mock.ExpectQuery("INSERT INTO module_version_approvals.*ON CONFLICT").
+		WithArgs("ver-1", "org-1", &uid, &notes).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "approved_at"}).AddRow("appr-1", time.Now()))
+
+	a := &models.ModuleVersionApproval{ModuleVersionID: "ver-1", OrganizationID: "org-1", ApprovedBy: &uid, Notes: &notes}
+	if err := repo.Approve(context.Background(), a); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if a.ID != "appr-1" || a.ApprovedAt.IsZero() {
+		t.Errorf("Approve did not populate id/approved_at: %+v", a)
+	}
+}
+
+func TestModuleApproval_Revoke(t *testing.T) {
+	repo, mock := newModuleApprovalRepo(t)
+	mock.ExpectExec("DELETE FROM module_version_approvals").
+		WithArgs("ver-1", "org-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM module_version_approvals").
+		WithArgs("ver-2", "org-1").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if ok, err := repo.Revoke(context.Background(), "ver-1", "org-1"); err != nil || !ok {
+		t.Errorf("Revoke existing = %v, %v; want true, nil", ok, err)
+	}
+	if ok, err := repo.Revoke(context.Background(), "ver-2", "org-1"); err != nil || ok {
+		t.Errorf("Revoke missing = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestModuleApproval_ListForOrganization(t *testing.T) {
+	repo, mock := newModuleApprovalRepo(t)
+	cols := []string{"id", "module_version_id", "organization_id", "approved_by", "approved_at", "notes",
+		"namespace", "name", "system", "version"}
+	mock.ExpectQuery("SELECT.*FROM module_version_approvals a.*JOIN module_versions").
+		WithArgs("org-1", "hashicorp", "", "").
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("appr-1", "ver-1", "org-1", "user-1", time.Now(), nil, "hashicorp", "consul", "aws", "1.0.0"))
+
+	list, err := repo.ListForOrganization(context.Background(), "org-1", "hashicorp", "", "")
+	if err != nil {
+		t.Fatalf("ListForOrganization: %v", err)
+	}
+	if len(list) != 1 || list[0].Version != "1.0.0" || list[0].Namespace != "hashicorp" {
+		t.Errorf("ListForOrganization = %+v", list)
+	}
+}
+
+func TestModuleApproval_IsApproved(t *testing.T) {
+	repo, mock := newModuleApprovalRepo(t)
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("ver-1", pq.Array([]string{"org-1", "org-2"})).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	ok, err := repo.IsApproved(context.Background(), "ver-1", []string{"org-1", "org-2"})
+	if err != nil || !ok {
+		t.Errorf("IsApproved = %v, %v; want true, nil", ok, err)
+	}
+	// No organizations: nothing can approve, and no query is issued.
+	if ok, err := repo.IsApproved(context.Background(), "ver-1", nil); err != nil || ok {
+		t.Errorf("IsApproved(nil orgs) = %v, %v; want false, nil", ok, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestModuleApproval_Policy(t *testing.T) {
+	repo, mock := newModuleApprovalRepo(t)
+	policyCols := []string{"organization_id", "approved_only", "default_max_versions", "default_version_cap_mode", "alias_duplicate_versions", "created_at", "updated_at"}
+	mock.ExpectQuery("SELECT.*FROM org_module_policies WHERE organization_id").
+		WithArgs("org-1").WillReturnRows(sqlmock.NewRows(policyCols))
+	mock.ExpectQuery("INSERT INTO org_module_policies").
+		WithArgs("org-1", true, 50, "rolling", true).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM org_module_policies WHERE organization_id").
+		WithArgs("org-1").WillReturnRows(sqlmock.NewRows(policyCols).AddRow("org-1", true, 50, "rolling", true, time.Now(), time.Now()))
+
+	p, err := repo.GetPolicy(context.Background(), "org-1")
+	if err != nil || p != nil {
+		t.Fatalf("GetPolicy(missing) = %+v, %v; want nil, nil", p, err)
+	}
+	maxVersions, mode := 50, models.VersionCapModeRolling
+	if err := repo.UpsertPolicy(context.Background(), &models.OrgModulePolicy{
+		OrganizationID: "org-1", ApprovedOnly: true, DefaultMaxVersions: &maxVersions, DefaultVersionCapMode: &mode,
+		AliasDuplicateVersions: true,
+	}); err != nil {
+		t.Fatalf("UpsertPolicy: %v", err)
+	}
+	p, err = repo.GetPolicy(context.Background(), "org-1")
+	if err != nil || p == nil || !p.ApprovedOnly || !p.AliasDuplicateVersions {
+		t.Errorf("GetPolicy = %+v, %v", p, err)
+	}
+	if p != nil && (p.DefaultMaxVersions == nil || *p.DefaultMaxVersions != 50 || p.DefaultVersionCapMode == nil || *p.DefaultVersionCapMode != "rolling") {
+		t.Errorf("version cap defaults = %v, %v", p.DefaultMaxVersions, p.DefaultVersionCapMode)
+	}
+}
+
+func TestModuleApproval_ApprovedOnlyOrganizations(t *testing.T) {
+	repo, mock := newModuleApprovalRepo(t)
+	mock.ExpectQuery("SELECT organization_id FROM org_module_policies.*approved_only").
+		WithArgs(pq.Array([]string{"org-1", "org-2"})).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow("org-2"))
+	mock.ExpectQuery("SELECT organization_id FROM org_module_policies").
+		WillReturnError(errors.New("boom"))
+
+	ids, err := repo.ApprovedOnlyOrganizations(context.Background(), []string{"org-1", "org-2"})
+	if err != nil || len(ids) != 1 || ids[0] != "org-2" {
+		t.Errorf("ApprovedOnlyOrganizations = %v, %v; want [org-2]", ids, err)
+	}
+	if _, err := repo.ApprovedOnlyOrganizations(context.Background(), []string{"org-1"}); err == nil {
+		t.Error("expected error to propagate")
+	}
+	if ids, err := repo.ApprovedOnlyOrganizations(context.Background(), nil); err != nil || ids != nil {
+		t.Errorf("ApprovedOnlyOrganizations(nil) = %v, %v", ids, err)
+	}
+}
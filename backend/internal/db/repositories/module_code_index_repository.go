@@ -0,0 +1,350 @@
+// module_code_index_repository.go implements database operations for the
+// module content index: the indexing queue in module_version_code_index, the
+// extracted blocks in module_code_symbols, and code search over them.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/terraform-registry/terraform-registry/internal/analyzer"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ModuleCodeIndexRepository handles database operations for the module
+// content index.
+type ModuleCodeIndexRepository struct {
+	db *sql.DB
+}
+
+// NewModuleCodeIndexRepository constructs a ModuleCodeIndexRepository.
+func NewModuleCodeIndexRepository(db *sql.DB) *ModuleCodeIndexRepository {
+	return &ModuleCodeIndexRepository{db: db}
+}
+
+const moduleCodeIndexColumns = `module_version_id, status, files_indexed, symbols_indexed, bytes_indexed,
+	truncated, error_message, indexed_at, created_at, updated_at`
+
+// QueueUnindexed queues every module version with no code index record, so
+// a newly published version is picked up by the next indexing run. It
+// returns the number of versions queued.
+func (r *ModuleCodeIndexRepository) QueueUnindexed(ctx context.Context) (int64, error) {
+	const q = `
+		INSERT INTO module_version_code_index (module_version_id)
+		SELECT mv.id FROM module_versions mv
+		WHERE NOT EXISTS (SELECT 1 FROM module_version_code_index ci WHERE ci.module_version_id = mv.id)
+		ON CONFLICT (module_version_id) DO NOTHING
+	`
+	res, err := r.db.ExecContext(ctx, q)
+	if err != nil {
+		return 0, fmt.Errorf("queue unindexed module versions: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Backfill queues module versions for indexing: those never indexed and those
+// whose indexing failed, and with reindex those already indexed too. moduleID
+// limits it to one module's versions; empty means every module. Versions
+// pending or being indexed are left alone. It returns the number queued.
+func (r *ModuleCodeIndexRepository) Backfill(ctx context.Context, moduleID string, reindex bool) (int64, error) {
+	const q = `
+		INSERT INTO module_version_code_index (module_version_id)
+		SELECT mv.id FROM module_versions mv
+		WHERE $1 = '' OR mv.module_id = NULLIF($1, '')::uuid
+		ON CONFLICT (module_version_id) DO UPDATE
+			SET status        = 'pending',
+			    error_message = NULL,
+			    updated_at    = NOW()
+			WHERE module_version_code_index.status = 'error'
+			   OR ($2 AND module_version_code_index.status = 'indexed')
+	`
+	res, err := r.db.ExecContext(ctx, q, moduleID, reindex)
+	if err != nil {
+		return 0, fmt.Errorf("backfill code index: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ResetStale returns versions left 'indexing' for longer than olderThan, by
+// a worker that stopped mid-run, to 'pending'.
+func (r *ModuleCodeIndexRepository) ResetStale(ctx context.Context, olderThan time.Duration) error {
+	const q = `
+		UPDATE module_version_code_index
+		SET status = 'pending', updated_at = NOW()
+		WHERE status = 'indexing' AND updated_at < $1
+	`
+	if _, err := r.db.ExecContext(ctx, q, time.Now().Add(-olderThan)); err != nil {
+		return fmt.Errorf("reset stale code index records: %w", err)
+	}
+	return nil
+}
+
+// ClaimPending atomically marks up to limit pending versions 'indexing' and
+// returns their module version IDs, oldest first. FOR UPDATE SKIP LOCKED
+// keeps concurrent replicas from claiming the same versions.
+func (r *ModuleCodeIndexRepository) ClaimPending(ctx context.Context, limit int) ([]string, error) {
+	const q = `
+		UPDATE module_version_code_index
+		SET status = 'indexing', updated_at = NOW()
+		WHERE module_version_id IN (
+			SELECT module_version_id FROM module_version_code_index
+			WHERE status = 'pending'
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING module_version_id
+	`
+	rows, err := r.db.QueryContext(ctx, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim pending code index records: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan claimed code index record: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SaveIndex replaces a version's code symbols with idx's and marks the
+// version indexed.
+func (r *ModuleCodeIndexRepository) SaveIndex(ctx context.Context, moduleVersionID string, idx *analyzer.CodeIndex) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM module_code_symbols WHERE module_version_id = $1`, moduleVersionID); err != nil {
+		return fmt.Errorf("delete code symbols: %w", err)
+	}
+	for _, s := range idx.Symbols {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO module_code_symbols
+				(module_version_id, kind, symbol, label, source, file_path, start_line, end_line, body)
+			VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9)
+		`,
+			moduleVersionID, s.Kind, s.Symbol, s.Label, s.Source,
+			s.FilePath, s.StartLine, s.EndLine, s.Body,
+		); err != nil {
+			return fmt.Errorf("insert code symbol: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE module_version_code_index
+		SET status = 'indexed', files_indexed = $2, symbols_indexed = $3, bytes_indexed = $4,
+		    truncated = $5, error_message = NULL, indexed_at = NOW(), updated_at = NOW()
+		WHERE module_version_id = $1
+	`, moduleVersionID, idx.Files, len(idx.Symbols), idx.Bytes, idx.Truncated); err != nil {
+		return fmt.Errorf("mark code index complete: %w", err)
+	}
+	return tx.Commit()
+}
+
+// MarkError records why a version could not be indexed.
+func (r *ModuleCodeIndexRepository) MarkError(ctx context.Context, moduleVersionID, msg string) error {
+	const q = `
+		UPDATE module_version_code_index
+		SET status = 'error', error_message = $2, updated_at = NOW()
+		WHERE module_version_id = $1
+	`
+	if _, err := r.db.ExecContext(ctx, q, moduleVersionID, msg); err != nil {
+		return fmt.Errorf("mark code index error: %w", err)
+	}
+	return nil
+}
+
+// Counts counts module versions by code index status.
+func (r *ModuleCodeIndexRepository) Counts(ctx context.Context) (*models.ModuleCodeIndexCounts, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM module_version_code_index GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("count code index records: %w", err)
+	}
+	defer rows.Close()
+
+	counts := &models.ModuleCodeIndexCounts{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("scan code index count: %w", err)
+		}
+		switch status {
+		case models.CodeIndexStatusPending:
+			counts.Pending = n
+		case models.CodeIndexStatusIndexing:
+			counts.Indexing = n
+		case models.CodeIndexStatusIndexed:
+			counts.Indexed = n
+		case models.CodeIndexStatusError:
+			counts.Error = n
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM module_versions mv
+		WHERE NOT EXISTS (SELECT 1 FROM module_version_code_index ci WHERE ci.module_version_id = mv.id)
+	`).Scan(&counts.Unqueued); err != nil {
+		return nil, fmt.Errorf("count unindexed module versions: %w", err)
+	}
+	return counts, nil
+}
+
+// ListErrors returns up to limit versions whose indexing failed, most
+// recent first.
+func (r *ModuleCodeIndexRepository) ListErrors(ctx context.Context, limit int) ([]*models.ModuleCodeIndex, error) {
+	q := `SELECT ` + moduleCodeIndexColumns + `
+		FROM module_version_code_index
+		WHERE status = 'error'
+		ORDER BY updated_at DESC
+		LIMIT $1`
+	rows, err := r.db.QueryContext(ctx, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list code index errors: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*models.ModuleCodeIndex{}
+	for rows.Next() {
+		ci := &models.ModuleCodeIndex{}
+		if err := rows.Scan(&ci.ModuleVersionID, &ci.Status, &ci.FilesIndexed, &ci.SymbolsIndexed, &ci.BytesIndexed,
+			&ci.Truncated, &ci.ErrorMessage, &ci.IndexedAt, &ci.CreatedAt, &ci.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan code index record: %w", err)
+		}
+		records = append(records, ci)
+	}
+	return records, rows.Err()
+}
+
+// addCodeSymbolFilter adds f's symbol conditions on module_code_symbols s.
+func addCodeSymbolFilter(wb *whereBuilder, f models.CodeSearchFilter) {
+	if f.Kind != "" {
+		wb.add("s.kind = $%d", f.Kind)
+	}
+	if f.Symbol != "" {
+		if f.Kind == analyzer.CodeSymbolModule {
+			wb.add("(s.symbol = $%d OR s.source = $%d)", f.Symbol)
+		} else {
+			wb.add("s.symbol = $%d", f.Symbol)
+		}
+	}
+	if f.Query != "" {
+		wb.add("s.search_vector @@ plainto_tsquery('simple', $%d)", f.Query)
+	}
+}
+
+// SearchCode returns the module versions with code symbols matching f, a
+// page at a time, ordered by module then newest version first, and the total
+// number of matching versions. Archived versions are not searched. orgID and
+// ownerOrgID scope the modules as in module search. Each result carries at
+// most matchesPerVersion matches.
+func (r *ModuleCodeIndexRepository) SearchCode(ctx context.Context, orgID, ownerOrgID string, f models.CodeSearchFilter, limit, offset, matchesPerVersion int) ([]*models.ModuleCodeSearchResult, int, error) {
+	wb, _ := moduleSearchWhere(orgID, ownerOrgID, "", f.Namespace, f.System, "")
+	addCodeSymbolFilter(&wb, f)
+	whereClause, args := wb.clause()
+	if whereClause == "" {
+		whereClause = "WHERE mv.archived_at IS NULL"
+	} else {
+		whereClause += " AND mv.archived_at IS NULL"
+	}
+	from := `
+		FROM module_code_symbols s
+		JOIN module_versions mv ON mv.id = s.module_version_id
+		JOIN modules m ON m.id = mv.module_id
+		` + whereClause
+
+	var total int
+	// #nosec G202 -- whereClause contains only parameterized SQL structural conditions; user values are passed via args
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(DISTINCT mv.id)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count code search results: %w", err)
+	}
+	if total == 0 {
+		return []*models.ModuleCodeSearchResult{}, 0, nil
+	}
+
+	n := wb.nextPlaceholder()
+	// #nosec G201 -- whereClause contains only parameterized SQL structural conditions; user values are passed via args
+	versionsSQL := fmt.Sprintf(`
+		SELECT mv.id, m.id, m.namespace, m.name, m.system, mv.version
+		%s
+		GROUP BY mv.id, m.id
+		ORDER BY m.namespace, m.name, m.system, mv.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, from, n, n+1)
+	rows, err := r.db.QueryContext(ctx, versionsSQL, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search code: %w", err)
+	}
+	results := []*models.ModuleCodeSearchResult{}
+	byVersion := map[string]*models.ModuleCodeSearchResult{}
+	for rows.Next() {
+		res := &models.ModuleCodeSearchResult{Matches: []models.ModuleCodeMatch{}}
+		if err := rows.Scan(&res.ModuleVersionID, &res.ModuleID, &res.Namespace, &res.Name, &res.System, &res.Version); err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("scan code search result: %w", err)
+		}
+		results = append(results, res)
+		byVersion[res.ModuleVersionID] = res
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if len(results) == 0 {
+		return results, total, nil
+	}
+
+	ids := make([]string, len(results))
+	for i, res := range results {
+		ids[i] = res.ModuleVersionID
+	}
+	var mb whereBuilder
+	mb.add("s.module_version_id = ANY($%d)", pq.Array(ids))
+	addCodeSymbolFilter(&mb, f)
+	matchWhere, matchArgs := mb.clause()
+	// #nosec G201 -- matchWhere contains only parameterized SQL structural conditions; user values are passed via args
+	matchesSQL := fmt.Sprintf(`
+		SELECT module_version_id, kind, symbol, label, source, file_path, start_line, end_line, body, total
+		FROM (
+			SELECT s.module_version_id, s.kind, s.symbol, s.label, s.source, s.file_path, s.start_line, s.end_line, s.body,
+			       ROW_NUMBER() OVER (PARTITION BY s.module_version_id ORDER BY s.file_path, s.start_line) AS rn,
+			       COUNT(*) OVER (PARTITION BY s.module_version_id) AS total
+			FROM module_code_symbols s
+			%s
+		) matched
+		WHERE rn <= $%d
+		ORDER BY module_version_id, file_path, start_line
+	`, matchWhere, mb.nextPlaceholder())
+	matchRows, err := r.db.QueryContext(ctx, matchesSQL, append(matchArgs, matchesPerVersion)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list code search matches: %w", err)
+	}
+	defer matchRows.Close()
+	for matchRows.Next() {
+		var versionID string
+		var m models.ModuleCodeMatch
+		var count int
+		if err := matchRows.Scan(&versionID, &m.Kind, &m.Symbol, &m.Label, &m.Source, &m.FilePath,
+			&m.StartLine, &m.EndLine, &m.Body, &count); err != nil {
+			return nil, 0, fmt.Errorf("scan code search match: %w", err)
+		}
+		if res, ok := byVersion[versionID]; ok {
+			res.MatchCount = count
+			res.Matches = append(res.Matches, m)
+		}
+	}
+	return results, total, matchRows.Err()
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/terraform-registry/terraform-registry/internal/analyzer"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newModuleCodeIndexRepo(t *testing.T) (*ModuleCodeIndexRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewModuleCodeIndexRepository(db), mock
+}
+
+func TestModuleCodeIndex_Backfill(t *testing.T) {
+	repo, mock := newModuleCodeIndexRepo(t)
+	mock.ExpectExec("INSERT INTO module_version_code_index.*NULLIF\\(\\$1, ''\\)::uuid.*ON CONFLICT.*status = 'error'.*\\$2 AND").
+		WithArgs("mod-1", true).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := repo.Backfill(context.Background(), "mod-1", true)
+	if err != nil || n != 3 {
+		t.Fatalf("Backfill = %d, %v; want 3", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestModuleCodeIndex_ClaimPending(t *testing.T) {
+	repo, mock := newModuleCodeIndexRepo(t)
+	mock.ExpectQuery("UPDATE module_version_code_index.*SET status = 'indexing'.*FOR UPDATE SKIP LOCKED.*RETURNING module_version_id").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"module_version_id"}).AddRow("mv-1").AddRow("mv-2"))
+
+	ids, err := repo.ClaimPending(context.Background(), 10)
+	if err != nil || len(ids) != 2 || ids[0] != "mv-1" {
+		t.Fatalf("ClaimPending = %v, %v", ids, err)
+	}
+}
+
+func TestModuleCodeIndex_SaveIndex(t *testing.T) {
+	repo, mock := newModuleCodeIndexRepo(t)
+	idx := &analyzer.CodeIndex{
+		Files: 1, Bytes: 120, Truncated: true,
+		Symbols: []analyzer.CodeSymbol{
+			{Kind: "resource", Symbol: "aws_eks_cluster", Label: "this", FilePath: "main.tf", StartLine: 1, EndLine: 3, Body: "resource {}"},
+		},
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM module_code_symbols WHERE module_version_id = \\$1").
+		WithArgs("mv-1").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO module_code_symbols").
+		WithArgs("mv-1", "resource", "aws_eks_cluster", "this", "", "main.tf", 1, 3, "resource {}").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE module_version_code_index.*status = 'indexed'").
+		WithArgs("mv-1", 1, 1, int64(120), true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.SaveIndex(context.Background(), "mv-1", idx); err != nil {
+		t.Fatalf("SaveIndex: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestModuleCodeIndex_Counts(t *testing.T) {
+	repo, mock := newModuleCodeIndexRepo(t)
+	mock.ExpectQuery("SELECT status, COUNT\\(\\*\\) FROM module_version_code_index GROUP BY status").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).AddRow("indexed", 7).AddRow("error", 1))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM module_versions mv.*NOT EXISTS").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	counts, err := repo.Counts(context.Background())
+	if err != nil {
+		t.Fatalf("Counts: %v", err)
+	}
+	if *counts != (models.ModuleCodeIndexCounts{Indexed: 7, Error: 1, Unqueued: 2}) {
+		t.Errorf("Counts = %+v", counts)
+	}
+}
+
+func TestModuleCodeIndex_SearchCode(t *testing.T) {
+	t.Run("versions and their matches", func(t *testing.T) {
+		repo, mock := newModuleCodeIndexRepo(t)
+		f := models.CodeSearchFilter{Kind: "resource", Symbol: "aws_eks_cluster", Query: "spot", Namespace: "acme"}
+		mock.ExpectQuery("SELECT COUNT\\(DISTINCT mv.id\\).*m.namespace = \\$1 AND s.kind = \\$2 AND s.symbol = \\$3 AND s.search_vector @@ plainto_tsquery\\('simple', \\$4\\) AND mv.archived_at IS NULL").
+			WithArgs("acme", "resource", "aws_eks_cluster", "spot").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("SELECT mv.id, m.id, m.namespace.*GROUP BY mv.id, m.id.*LIMIT \\$5 OFFSET \\$6").
+			WithArgs("acme", "resource", "aws_eks_cluster", "spot", 20, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "module_id", "namespace", "name", "system", "version"}).
+				AddRow("mv-1", "mod-1", "acme", "eks", "aws", "1.0.0"))
+		mock.ExpectQuery("ROW_NUMBER\\(\\) OVER.*s.module_version_id = ANY\\(\\$1\\) AND s.kind = \\$2.*WHERE rn <= \\$5").
+			WithArgs(sqlmock.AnyArg(), "resource", "aws_eks_cluster", "spot", 5).
+			WillReturnRows(sqlmock.NewRows([]string{"module_version_id", "kind", "symbol", "label", "source", "file_path", "start_line", "end_line", "body", "total"}).
+				AddRow("mv-1", "resource", "aws_eks_cluster", "this", nil, "main.tf", 1, 4, "resource ...", 2))
+
+		results, total, err := repo.SearchCode(context.Background(), "", "", f, 20, 0, 5)
+		if err != nil {
+			t.Fatalf("SearchCode: %v", err)
+		}
+		if total != 1 || len(results) != 1 || results[0].MatchCount != 2 || len(results[0].Matches) != 1 ||
+			results[0].Matches[0].FilePath != "main.tf" || *results[0].Matches[0].Label != "this" {
+			t.Errorf("SearchCode = %+v, %d", results, total)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("module symbol also matches the source", func(t *testing.T) {
+		repo, mock := newModuleCodeIndexRepo(t)
+		mock.ExpectQuery("SELECT COUNT\\(DISTINCT mv.id\\).*\\(s.symbol = \\$2 OR s.source = \\$2\\)").
+			WithArgs("module", "terraform-aws-modules/vpc/aws").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+		results, total, err := repo.SearchCode(context.Background(), "", "",
+			models.CodeSearchFilter{Kind: "module", Symbol: "terraform-aws-modules/vpc/aws"}, 20, 0, 5)
+		if err != nil || total != 0 || len(results) != 0 {
+			t.Errorf("SearchCode = %v, %d, %v", results, total, err)
+		}
+	})
+}
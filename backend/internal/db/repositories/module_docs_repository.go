@@ -42,6 +42,15 @@ func (r *ModuleDocsRepository) UpsertModuleDocs(
 		return fmt.Errorf("marshal providers: %w", err)
 	}
 
+	submodules := doc.Submodules
+	if submodules == nil {
+		submodules = []analyzer.SubmoduleDoc{}
+	}
+	submodulesJSON, err := json.Marshal(submodules)
+	if err != nil {
+		return fmt.Errorf("marshal submodules: %w", err)
+	}
+
 	var reqJSON interface{}
 	if doc.Requirements != nil {
 		b, err := json.Marshal(doc.Requirements)
@@ -52,16 +61,17 @@ func (r *ModuleDocsRepository) UpsertModuleDocs(
 	}
 
 	const q = `
-		INSERT INTO module_version_docs (module_version_id, inputs, outputs, providers, requirements)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO module_version_docs (module_version_id, inputs, outputs, providers, requirements, submodules)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (module_version_id) DO UPDATE SET
 			inputs       = EXCLUDED.inputs,
 			outputs      = EXCLUDED.outputs,
 			providers    = EXCLUDED.providers,
 			requirements = EXCLUDED.requirements,
+			submodules   = EXCLUDED.submodules,
 			generated_at = NOW()
 	`
-	_, err = r.db.ExecContext(ctx, q, moduleVersionID, inputsJSON, outputsJSON, providersJSON, reqJSON)
+	_, err = r.db.ExecContext(ctx, q, moduleVersionID, inputsJSON, outputsJSON, providersJSON, reqJSON, submodulesJSON)
 	if err != nil {
 		return fmt.Errorf("upsert module docs: %w", err)
 	}
@@ -116,6 +126,33 @@ func (r *ModuleDocsRepository) GetModuleDocs(
 	return doc, nil
 }
 
+// GetSubmoduleDocs returns the stored submodule docs for a module version.
+// analyzed is false when the version has no docs, or was analyzed before
+// submodules were documented.
+func (r *ModuleDocsRepository) GetSubmoduleDocs(
+	ctx context.Context, moduleVersionID string,
+) (subs []analyzer.SubmoduleDoc, analyzed bool, err error) {
+	const q = `SELECT submodules FROM module_version_docs WHERE module_version_id = $1`
+	var submodulesJSON []byte
+	err = r.db.QueryRowContext(ctx, q, moduleVersionID).Scan(&submodulesJSON)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get submodule docs: %w", err)
+	}
+	if submodulesJSON == nil {
+		return nil, false, nil
+	}
+	if err := json.Unmarshal(submodulesJSON, &subs); err != nil {
+		return nil, false, fmt.Errorf("unmarshal submodules: %w", err)
+	}
+	if subs == nil {
+		subs = []analyzer.SubmoduleDoc{}
+	}
+	return subs, true, nil
+}
+
 // HasDocs returns true if docs exist for the given module version ID.
 func (r *ModuleDocsRepository) HasDocs(ctx context.Context, moduleVersionID string) (bool, error) {
 	const q = `SELECT EXISTS(SELECT 1 FROM module_version_docs WHERE module_version_id = $1)`
@@ -93,6 +93,72 @@ func TestUpsertModuleDocs_DBError(t *testing.T) {
 	}
 }
 
+func TestUpsertModuleDocs_StoresSubmodules(t *testing.T) {
+	repo, mock := newDocsRepo(t)
+	doc := &analyzer.ModuleDoc{
+		Submodules: []analyzer.SubmoduleDoc{{Name: "network", Path: "modules/network"}},
+	}
+	mock.ExpectExec("INSERT INTO module_version_docs").
+		WithArgs("ver-1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil,
+			[]byte(`[{"name":"network","path":"modules/network","inputs":null,"outputs":null,"providers":null}]`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	if err := repo.UpsertModuleDocs(context.Background(), "ver-1", doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// No submodules is stored as an empty list, not NULL (never analyzed).
+	mock.ExpectExec("INSERT INTO module_version_docs").
+		WithArgs("ver-2", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, []byte(`[]`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	if err := repo.UpsertModuleDocs(context.Background(), "ver-2", &analyzer.ModuleDoc{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// GetSubmoduleDocs
+// ---------------------------------------------------------------------------
+
+func TestGetSubmoduleDocs(t *testing.T) {
+	repo, mock := newDocsRepo(t)
+	mock.ExpectQuery("SELECT submodules FROM module_version_docs").
+		WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"submodules"}).
+			AddRow(`[{"name":"broken","path":"modules/broken","error":"bad HCL","inputs":[],"outputs":[],"providers":[]}]`))
+	subs, analyzed, err := repo.GetSubmoduleDocs(context.Background(), "ver-1")
+	if err != nil || !analyzed {
+		t.Fatalf("GetSubmoduleDocs = %v, %v", analyzed, err)
+	}
+	if len(subs) != 1 || subs[0].Name != "broken" || subs[0].Error != "bad HCL" {
+		t.Errorf("subs = %+v", subs)
+	}
+
+	// Analyzed before submodules were documented.
+	mock.ExpectQuery("SELECT submodules FROM module_version_docs").
+		WithArgs("ver-2").
+		WillReturnRows(sqlmock.NewRows([]string{"submodules"}).AddRow(nil))
+	if subs, analyzed, err := repo.GetSubmoduleDocs(context.Background(), "ver-2"); subs != nil || analyzed || err != nil {
+		t.Errorf("NULL submodules = %v, %v, %v; want nil, false, nil", subs, analyzed, err)
+	}
+
+	mock.ExpectQuery("SELECT submodules FROM module_version_docs").
+		WithArgs("ver-3").
+		WillReturnRows(sqlmock.NewRows([]string{"submodules"}))
+	if _, analyzed, err := repo.GetSubmoduleDocs(context.Background(), "ver-3"); analyzed || err != nil {
+		t.Errorf("no docs row = %v, %v; want false, nil", analyzed, err)
+	}
+
+	mock.ExpectQuery("SELECT submodules FROM module_version_docs").
+		WithArgs("ver-4").
+		WillReturnError(errors.New("db error"))
+	if _, _, err := repo.GetSubmoduleDocs(context.Background(), "ver-4"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // GetModuleDocs
 // ---------------------------------------------------------------------------
@@ -0,0 +1,71 @@
+// Package repositories - module_proxy_repository.go tracks which modules were
+// populated by the module pull-through proxy and from which upstream host.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ModuleProxyRepository handles proxied module bookkeeping.
+type ModuleProxyRepository struct {
+	db *sql.DB
+}
+
+// NewModuleProxyRepository creates a new module proxy repository.
+func NewModuleProxyRepository(db *sql.DB) *ModuleProxyRepository {
+	return &ModuleProxyRepository{db: db}
+}
+
+// Get returns the proxy record for a module, or nil when the module was not
+// populated by the proxy.
+func (r *ModuleProxyRepository) Get(ctx context.Context, moduleID string) (*models.ProxiedModule, error) {
+	query := `
+		SELECT module_id, upstream_host, created_at, last_fetched_at
+		FROM proxied_modules
+		WHERE module_id = $1
+	`
+	pm := &models.ProxiedModule{}
+	err := r.db.QueryRowContext(ctx, query, moduleID).
+		Scan(&pm.ModuleID, &pm.UpstreamHost, &pm.CreatedAt, &pm.LastFetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxied module: %w", err)
+	}
+	return pm, nil
+}
+
+// MarkProxied flags a module as proxied from upstreamHost. The first host to
+// claim a module wins: a later call with a different host leaves the record
+// unchanged, and the returned record lets the caller detect the mismatch.
+func (r *ModuleProxyRepository) MarkProxied(ctx context.Context, moduleID, upstreamHost string) (*models.ProxiedModule, error) {
+	query := `
+		INSERT INTO proxied_modules (module_id, upstream_host)
+		VALUES ($1, $2)
+		ON CONFLICT (module_id) DO UPDATE SET module_id = proxied_modules.module_id
+		RETURNING module_id, upstream_host, created_at, last_fetched_at
+	`
+	pm := &models.ProxiedModule{}
+	err := r.db.QueryRowContext(ctx, query, moduleID, upstreamHost).
+		Scan(&pm.ModuleID, &pm.UpstreamHost, &pm.CreatedAt, &pm.LastFetchedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark module as proxied: %w", err)
+	}
+	return pm, nil
+}
+
+// TouchFetched records that a version of the module was just fetched from
+// upstream.
+func (r *ModuleProxyRepository) TouchFetched(ctx context.Context, moduleID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE proxied_modules SET last_fetched_at = NOW() WHERE module_id = $1`, moduleID)
+	if err != nil {
+		return fmt.Errorf("failed to update proxied module: %w", err)
+	}
+	return nil
+}
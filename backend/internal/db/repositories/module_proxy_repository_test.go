@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+var proxiedModuleCols = []string{"module_id", "upstream_host", "created_at", "last_fetched_at"}
+
+func newModuleProxyRepo(t *testing.T) (*ModuleProxyRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewModuleProxyRepository(db), mock
+}
+
+func TestModuleProxy_Get(t *testing.T) {
+	repo, mock := newModuleProxyRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("SELECT.*FROM proxied_modules").WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows(proxiedModuleCols).AddRow("mod-1", "registry.terraform.io", now, now))
+
+	pm, err := repo.Get(context.Background(), "mod-1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if pm == nil || pm.UpstreamHost != "registry.terraform.io" {
+		t.Errorf("Get = %+v, want registry.terraform.io record", pm)
+	}
+}
+
+func TestModuleProxy_Get_NotProxied(t *testing.T) {
+	repo, mock := newModuleProxyRepo(t)
+	mock.ExpectQuery("SELECT.*FROM proxied_modules").WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows(proxiedModuleCols))
+
+	pm, err := repo.Get(context.Background(), "mod-1")
+	if err != nil || pm != nil {
+		t.Errorf("Get = %+v, %v; want nil, nil", pm, err)
+	}
+}
+
+func TestModuleProxy_Get_Error(t *testing.T) {
+	repo, mock := newModuleProxyRepo(t)
+	mock.ExpectQuery("SELECT.*FROM proxied_modules").WillReturnError(errors.New("db down"))
+
+	if _, err := repo.Get(context.Background(), "mod-1"); err == nil {
+		t.Error("Get: expected error")
+	}
+}
+
+func TestModuleProxy_MarkProxied(t *testing.T) {
+	repo, mock := newModuleProxyRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO proxied_modules.*ON CONFLICT").WithArgs("mod-1", "registry.terraform.io").
+		WillReturnRows(sqlmock.NewRows(proxiedModuleCols).AddRow("mod-1", "registry.terraform.io", now, now))
+
+	pm, err := repo.MarkProxied(context.Background(), "mod-1", "registry.terraform.io")
+	if err != nil {
+		t.Fatalf("MarkProxied: %v", err)
+	}
+	if pm.ModuleID != "mod-1" {
+		t.Errorf("ModuleID = %q, want mod-1", pm.ModuleID)
+	}
+}
+
+func TestModuleProxy_TouchFetched(t *testing.T) {
+	repo, mock := newModuleProxyRepo(t)
+	mock.ExpectExec("UPDATE proxied_modules SET last_fetched_at").WithArgs("mod-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.TouchFetched(context.Background(), "mod-1"); err != nil {
+		t.Errorf("TouchFetched: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
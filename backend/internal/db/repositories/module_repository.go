@@ -9,7 +9,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 )
 
@@ -181,13 +183,98 @@ func (r *ModuleRepository) UpdateModule(ctx context.Context, module *models.Modu
 	return nil
 }
 
+// MoveModule moves a module to another namespace and/or system in one
+// transaction. The old address is recorded as a redirect to the module, any
+// redirect previously held by the new address is dropped, and the address
+// recorded on the module's SCM links is rewritten. Redirects left by earlier
+// moves keep pointing at the module and so follow it. On success module
+// carries the new address.
+func (r *ModuleRepository) MoveModule(ctx context.Context, module *models.Module, namespace, system string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		UPDATE modules
+		SET namespace = $2, system = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		module.ID, namespace, system,
+	).Scan(&updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to move module: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM module_address_redirects
+		WHERE organization_id = $1 AND namespace = $2 AND name = $3 AND system = $4`,
+		module.OrganizationID, namespace, module.Name, system,
+	); err != nil {
+		return fmt.Errorf("failed to clear redirect at new module address: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO module_address_redirects (organization_id, namespace, name, system, module_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id, namespace, name, system)
+		DO UPDATE SET module_id = EXCLUDED.module_id, created_at = NOW()`,
+		module.OrganizationID, module.Namespace, module.Name, module.System, module.ID,
+	); err != nil {
+		return fmt.Errorf("failed to record module redirect: %w", err)
+	}
+
+	oldAddr := module.Namespace + "/" + module.Name + "/" + module.System
+	newAddr := namespace + "/" + module.Name + "/" + system
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE module_scm_repos
+		SET module_address = LEFT(module_address, LENGTH(module_address) - LENGTH($2)) || $3
+		WHERE module_id = $1 AND RIGHT(module_address, LENGTH($2)) = $2`,
+		module.ID, oldAddr, newAddr,
+	); err != nil {
+		return fmt.Errorf("failed to update linked module address: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit module move: %w", err)
+	}
+	module.Namespace = namespace
+	module.System = system
+	module.UpdatedAt = updatedAt
+	return nil
+}
+
+// GetModuleRedirect returns the redirect left at an old module address, with
+// the module's current address, or nil when the address never moved.
+func (r *ModuleRepository) GetModuleRedirect(ctx context.Context, orgID, namespace, name, system string) (*models.ModuleRedirect, error) {
+	rd := &models.ModuleRedirect{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT d.id, d.organization_id, d.namespace, d.name, d.system, d.module_id, d.created_at,
+		       m.namespace, m.name, m.system
+		FROM module_address_redirects d
+		JOIN modules m ON m.id = d.module_id
+		WHERE d.organization_id = $1 AND d.namespace = $2 AND d.name = $3 AND d.system = $4`,
+		orgID, namespace, name, system,
+	).Scan(&rd.ID, &rd.OrganizationID, &rd.Namespace, &rd.Name, &rd.System, &rd.ModuleID, &rd.CreatedAt,
+		&rd.TargetNamespace, &rd.TargetName, &rd.TargetSystem)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get module redirect: %w", err)
+	}
+	return rd, nil
+}
+
 // CreateVersion inserts a new module version
 func (r *ModuleRepository) CreateVersion(ctx context.Context, version *models.ModuleVersion) error {
 	query := `
 		INSERT INTO module_versions
 		  (module_id, version, storage_path, storage_backend, size_bytes, checksum, readme, published_by,
-		   commit_sha, tag_name, scm_repo_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		   commit_sha, tag_name, scm_repo_id, required_terraform_version, alias_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, created_at
 	`
 
@@ -203,6 +290,8 @@ func (r *ModuleRepository) CreateVersion(ctx context.Context, version *models.Mo
 		version.CommitSHA,
 		version.TagName,
 		version.SCMRepoID,
+		version.RequiredTerraformVersion,
+		version.AliasOf,
 	).Scan(&version.ID, &version.CreatedAt)
 
 	if err != nil {
@@ -212,12 +301,55 @@ func (r *ModuleRepository) CreateVersion(ctx context.Context, version *models.Mo
 	return nil
 }
 
+// FindVersionByChecksum returns the oldest version of a module that holds its
+// own archive with the given SHA-256, or nil when there is none. Aliases are
+// skipped: they share the archive of the version they point at.
+func (r *ModuleRepository) FindVersionByChecksum(ctx context.Context, moduleID, checksum string) (*models.ModuleVersion, error) {
+	v := &models.ModuleVersion{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, module_id, version, storage_path, storage_backend, size_bytes, checksum, created_at
+		FROM module_versions
+		WHERE module_id = $1 AND checksum = $2 AND alias_of IS NULL
+		ORDER BY created_at
+		LIMIT 1`, moduleID, checksum).Scan(
+		&v.ID, &v.ModuleID, &v.Version, &v.StoragePath, &v.StorageBackend, &v.SizeBytes, &v.Checksum, &v.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find module version by checksum: %w", err)
+	}
+	return v, nil
+}
+
+// GetVersionAliasing returns the ID of the version versionID is an alias of
+// (empty when it holds its own archive) and the versions that are aliases of
+// it.
+func (r *ModuleRepository) GetVersionAliasing(ctx context.Context, versionID string) (string, []string, error) {
+	var aliasOf string
+	var aliases []string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(v.alias_of::text, ''),
+		       COALESCE(array_agg(a.version ORDER BY a.created_at) FILTER (WHERE a.id IS NOT NULL), '{}')
+		FROM module_versions v
+		LEFT JOIN module_versions a ON a.alias_of = v.id
+		WHERE v.id = $1
+		GROUP BY v.alias_of`, versionID).Scan(&aliasOf, pq.Array(&aliases))
+	if err == sql.ErrNoRows {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get module version aliases: %w", err)
+	}
+	return aliasOf, aliases, nil
+}
+
 // GetVersion retrieves a specific module version
 func (r *ModuleRepository) GetVersion(ctx context.Context, moduleID, version string) (*models.ModuleVersion, error) {
 	query := `
 		SELECT id, module_id, version, storage_path, storage_backend, size_bytes, checksum, readme, published_by, download_count,
 		       COALESCE(deprecated, false), deprecated_at, deprecation_message, replacement_source, created_at,
-		       commit_sha, tag_name, scm_repo_id::text
+		       commit_sha, tag_name, scm_repo_id::text, required_terraform_version
 		FROM module_versions
 		WHERE module_id = $1 AND version = $2
 	`
@@ -242,6 +374,7 @@ func (r *ModuleRepository) GetVersion(ctx context.Context, moduleID, version str
 		&v.CommitSHA,
 		&v.TagName,
 		&v.SCMRepoID,
+		&v.RequiredTerraformVersion,
 	)
 
 	if err != nil {
@@ -260,7 +393,7 @@ func (r *ModuleRepository) ListVersions(ctx context.Context, moduleID string) ([
 		SELECT mv.id, mv.module_id, mv.version, mv.storage_path, mv.storage_backend, mv.size_bytes, mv.checksum, mv.readme,
 		       mv.published_by, u.name as published_by_name, mv.download_count,
 		       COALESCE(mv.deprecated, false), mv.deprecated_at, mv.deprecation_message, mv.replacement_source, mv.created_at,
-		       mv.commit_sha, mv.tag_name, mv.scm_repo_id::text,
+		       mv.commit_sha, mv.tag_name, mv.scm_repo_id::text, mv.required_terraform_version,
 		       (mvd.module_version_id IS NOT NULL) AS has_docs
 		FROM module_versions mv
 		LEFT JOIN users u ON mv.published_by = u.id
@@ -297,6 +430,7 @@ func (r *ModuleRepository) ListVersions(ctx context.Context, moduleID string) ([
 			&v.CommitSHA,
 			&v.TagName,
 			&v.SCMRepoID,
+			&v.RequiredTerraformVersion,
 			&v.HasDocs,
 		)
 		if err != nil {
@@ -317,32 +451,146 @@ func (r *ModuleRepository) ListVersions(ctx context.Context, moduleID string) ([
 	return versions, nil
 }
 
+// hiddenPrereleaseCondition is the " AND ..." condition hiding pre-release
+// versions on the version table aliased alias, unless the module opted in to
+// listing them (modules.include_prerelease). It uses the same pre-release
+// pattern as the latest_version lookups. $1 is the module ID.
+func hiddenPrereleaseCondition(alias string) string {
+	return ` AND (` + alias + `.version !~ '^v?[0-9]+(\.[0-9]+)*[^0-9.+]'
+		  OR (SELECT m.include_prerelease FROM modules m WHERE m.id = $1))`
+}
+
 // ListVersionsPaginated retrieves versions for a module with limit/offset pagination and total count.
-func (r *ModuleRepository) ListVersionsPaginated(ctx context.Context, moduleID string, limit, offset int) ([]*models.ModuleVersion, int, error) {
+// Archived versions are left out, and so are pre-releases when hidePrereleases is
+// set and the module has not opted in to listing them. A limit of 0 or less
+// returns every version from offset on.
+func (r *ModuleRepository) ListVersionsPaginated(ctx context.Context, moduleID string, hidePrereleases bool, limit, offset int) ([]*models.ModuleVersion, int, error) {
+	var conds string
+	if hidePrereleases {
+		conds = hiddenPrereleaseCondition("module_versions")
+	}
+
 	// Get total count
-	countQuery := `SELECT COUNT(*) FROM module_versions WHERE module_id = $1`
+	countQuery := `SELECT COUNT(*) FROM module_versions WHERE module_id = $1 AND archived_at IS NULL` + conds
 	var total int
 	if err := r.db.QueryRowContext(ctx, countQuery, moduleID).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("failed to count module versions: %w", err)
 	}
 
+	if hidePrereleases {
+		conds = hiddenPrereleaseCondition("mv")
+	}
 	query := `
 		SELECT mv.id, mv.module_id, mv.version, mv.storage_path, mv.storage_backend, mv.size_bytes, mv.checksum, mv.readme,
 		       mv.published_by, u.name as published_by_name, mv.download_count,
 		       COALESCE(mv.deprecated, false), mv.deprecated_at, mv.deprecation_message, mv.replacement_source, mv.created_at,
-		       mv.commit_sha, mv.tag_name, mv.scm_repo_id::text,
+		       mv.commit_sha, mv.tag_name, mv.scm_repo_id::text, mv.required_terraform_version,
 		       (mvd.module_version_id IS NOT NULL) AS has_docs
 		FROM module_versions mv
 		LEFT JOIN users u ON mv.published_by = u.id
 		LEFT JOIN module_version_docs mvd ON mvd.module_version_id = mv.id
-		WHERE mv.module_id = $1
+		WHERE mv.module_id = $1 AND mv.archived_at IS NULL` + conds + `
 		ORDER BY mv.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, moduleID, limit, offset)
+	versions, err := r.queryPaginatedVersions(ctx, query, moduleID, limitArg(limit), offset)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list module versions: %w", err)
+		return nil, 0, err
+	}
+	return versions, total, nil
+}
+
+// limitArg is the LIMIT argument for limit: NULL, meaning no limit, when
+// limit is 0 or less.
+func limitArg(limit int) interface{} {
+	if limit <= 0 {
+		return nil
+	}
+	return limit
+}
+
+// ListApprovedVersionsPaginated is ListVersionsPaginated restricted to
+// versions approved by at least one of orgIDs (see ModuleApprovalRepository).
+func (r *ModuleRepository) ListApprovedVersionsPaginated(ctx context.Context, moduleID string, orgIDs []string, hidePrereleases bool, limit, offset int) ([]*models.ModuleVersion, int, error) {
+	var conds string
+	if hidePrereleases {
+		conds = hiddenPrereleaseCondition("mv")
+	}
+
+	countQuery := `
+		SELECT COUNT(*) FROM module_versions mv
+		WHERE mv.module_id = $1 AND mv.archived_at IS NULL
+		  AND EXISTS (SELECT 1 FROM module_version_approvals a
+		              WHERE a.module_version_id = mv.id AND a.organization_id::text = ANY($2))` + conds
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, moduleID, pq.Array(orgIDs)).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count approved module versions: %w", err)
+	}
+
+	query := `
+		SELECT mv.id, mv.module_id, mv.version, mv.storage_path, mv.storage_backend, mv.size_bytes, mv.checksum, mv.readme,
+		       mv.published_by, u.name as published_by_name, mv.download_count,
+		       COALESCE(mv.deprecated, false), mv.deprecated_at, mv.deprecation_message, mv.replacement_source, mv.created_at,
+		       mv.commit_sha, mv.tag_name, mv.scm_repo_id::text, mv.required_terraform_version,
+		       (mvd.module_version_id IS NOT NULL) AS has_docs
+		FROM module_versions mv
+		LEFT JOIN users u ON mv.published_by = u.id
+		LEFT JOIN module_version_docs mvd ON mvd.module_version_id = mv.id
+		WHERE mv.module_id = $1 AND mv.archived_at IS NULL
+		  AND EXISTS (SELECT 1 FROM module_version_approvals a
+		              WHERE a.module_version_id = mv.id AND a.organization_id::text = ANY($4))` + conds + `
+		ORDER BY mv.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	versions, err := r.queryPaginatedVersions(ctx, query, moduleID, limitArg(limit), offset, pq.Array(orgIDs))
+	if err != nil {
+		return nil, 0, err
+	}
+	return versions, total, nil
+}
+
+// ListVersionsFiltered returns one page of a module's versions matching f,
+// archived versions included unless f excludes them, plus the number of
+// versions matching f. Readmes are not loaded.
+func (r *ModuleRepository) ListVersionsFiltered(ctx context.Context, moduleID string, f VersionListFilter) ([]*models.ModuleVersion, int, error) {
+	conds, args := f.sqlConditions("mv", []interface{}{moduleID})
+	if f.Archived != nil {
+		if *f.Archived {
+			conds += " AND mv.archived_at IS NOT NULL"
+		} else {
+			conds += " AND mv.archived_at IS NULL"
+		}
+	}
+
+	query := `
+		SELECT mv.id, mv.module_id, mv.version, mv.storage_path, mv.storage_backend, mv.size_bytes, mv.checksum, NULL AS readme,
+		       mv.published_by, u.name as published_by_name, mv.download_count,
+		       COALESCE(mv.deprecated, false), mv.deprecated_at, mv.deprecation_message, mv.replacement_source, mv.created_at,
+		       mv.commit_sha, mv.tag_name, mv.scm_repo_id::text, mv.required_terraform_version,
+		       (mvd.module_version_id IS NOT NULL) AS has_docs
+		FROM module_versions mv
+		LEFT JOIN users u ON mv.published_by = u.id
+		LEFT JOIN module_version_docs mvd ON mvd.module_version_id = mv.id
+		WHERE mv.module_id = $1` + conds
+
+	candidates, err := r.queryPaginatedVersions(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	versions, total := pageVersions(candidates, f,
+		func(v *models.ModuleVersion) string { return v.Version },
+		func(v *models.ModuleVersion) time.Time { return v.CreatedAt })
+	return versions, total, nil
+}
+
+// queryPaginatedVersions runs a paginated version query whose select list
+// matches ListVersionsPaginated and scans the rows.
+func (r *ModuleRepository) queryPaginatedVersions(ctx context.Context, query string, args ...interface{}) ([]*models.ModuleVersion, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list module versions: %w", err)
 	}
 	defer rows.Close()
 
@@ -369,19 +617,20 @@ func (r *ModuleRepository) ListVersionsPaginated(ctx context.Context, moduleID s
 			&v.CommitSHA,
 			&v.TagName,
 			&v.SCMRepoID,
+			&v.RequiredTerraformVersion,
 			&v.HasDocs,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan module version: %w", err)
+			return nil, fmt.Errorf("failed to scan module version: %w", err)
 		}
 		versions = append(versions, v)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating module versions: %w", err)
+		return nil, fmt.Errorf("error iterating module versions: %w", err)
 	}
 
-	return versions, total, nil
+	return versions, nil
 }
 
 // GetAllWithSourceCommit returns all module versions that have a commit SHA recorded,
@@ -391,7 +640,7 @@ func (r *ModuleRepository) GetAllWithSourceCommit(ctx context.Context) ([]*model
 		SELECT id, module_id, version, storage_path, storage_backend, size_bytes, checksum, readme,
 		       published_by, download_count,
 		       COALESCE(deprecated, false), deprecated_at, deprecation_message, replacement_source, created_at,
-		       commit_sha, tag_name, scm_repo_id::text
+		       commit_sha, tag_name, scm_repo_id::text, required_terraform_version
 		FROM module_versions
 		WHERE commit_sha IS NOT NULL AND scm_repo_id IS NOT NULL
 		ORDER BY created_at DESC
@@ -425,6 +674,7 @@ func (r *ModuleRepository) GetAllWithSourceCommit(ctx context.Context) ([]*model
 			&v.CommitSHA,
 			&v.TagName,
 			&v.SCMRepoID,
+			&v.RequiredTerraformVersion,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan SCM-sourced version: %w", err)
@@ -481,6 +731,236 @@ func (r *ModuleRepository) IncrementDownloadCount(ctx context.Context, versionID
 	return nil
 }
 
+// SetVersionChangelog stores the (already sanitized) changelog excerpt for a
+// module version. An empty changelog clears the stored value.
+func (r *ModuleRepository) SetVersionChangelog(ctx context.Context, versionID, changelog string) error {
+	var value *string
+	if changelog != "" {
+		value = &changelog
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE module_versions SET changelog = $2 WHERE id = $1`, versionID, value)
+	if err != nil {
+		return fmt.Errorf("failed to set version changelog: %w", err)
+	}
+	return nil
+}
+
+// GetVersionChangelog returns the stored changelog excerpt for a module
+// version, or nil when none was recorded.
+func (r *ModuleRepository) GetVersionChangelog(ctx context.Context, versionID string) (*string, error) {
+	var changelog *string
+	err := r.db.QueryRowContext(ctx, `SELECT changelog FROM module_versions WHERE id = $1`, versionID).Scan(&changelog)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version changelog: %w", err)
+	}
+	return changelog, nil
+}
+
+// GetIncludePrerelease reports whether a module lists pre-release versions by
+// default (modules.include_prerelease). A missing module reads as false.
+func (r *ModuleRepository) GetIncludePrerelease(ctx context.Context, moduleID string) (bool, error) {
+	var include bool
+	err := r.db.QueryRowContext(ctx, `SELECT include_prerelease FROM modules WHERE id = $1`, moduleID).Scan(&include)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get module pre-release setting: %w", err)
+	}
+	return include, nil
+}
+
+// SetIncludePrerelease sets whether a module lists pre-release versions by
+// default.
+func (r *ModuleRepository) SetIncludePrerelease(ctx context.Context, moduleID string, include bool) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE modules SET include_prerelease = $2, updated_at = NOW() WHERE id = $1`, moduleID, include)
+	if err != nil {
+		return fmt.Errorf("failed to set module pre-release setting: %w", err)
+	}
+	return nil
+}
+
+// ListTiers returns the registry-assigned tier of the given modules keyed by
+// module ID. Modules that do not exist are absent from the map.
+func (r *ModuleRepository) ListTiers(ctx context.Context, moduleIDs []string) (map[string]string, error) {
+	result := make(map[string]string)
+	if len(moduleIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, tier FROM modules WHERE id::text = ANY($1)`, pq.Array(moduleIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list module tiers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, tier string
+		if err := rows.Scan(&id, &tier); err != nil {
+			return nil, fmt.Errorf("failed to scan module tier: %w", err)
+		}
+		result[id] = tier
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate module tiers: %w", err)
+	}
+	return result, nil
+}
+
+// SetTier sets a module's registry-assigned tier and returns the tier it
+// replaced. It returns an empty previous tier when the module does not exist.
+func (r *ModuleRepository) SetTier(ctx context.Context, moduleID, tier string) (string, error) {
+	query := `
+		UPDATE modules m
+		SET tier = $2, updated_at = NOW()
+		FROM (SELECT id, tier FROM modules WHERE id = $1 FOR UPDATE) old
+		WHERE m.id = old.id
+		RETURNING old.tier
+	`
+
+	var previous string
+	err := r.db.QueryRowContext(ctx, query, moduleID, tier).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to set module tier: %w", err)
+	}
+	return previous, nil
+}
+
+// GetVersionCap returns a module's effective version cap: its own
+// max_versions and version_cap_mode, falling back to its organization's
+// defaults, and strict mode when neither sets one. Returns nil when the module
+// does not exist.
+func (r *ModuleRepository) GetVersionCap(ctx context.Context, moduleID string) (*models.ModuleVersionCap, error) {
+	query := `
+		SELECT COALESCE(m.max_versions, p.default_max_versions),
+		       COALESCE(m.version_cap_mode, p.default_version_cap_mode, 'strict'),
+		       (SELECT COUNT(*) FROM module_versions mv WHERE mv.module_id = m.id AND mv.archived_at IS NULL)
+		FROM modules m
+		LEFT JOIN org_module_policies p ON p.organization_id = m.organization_id
+		WHERE m.id = $1
+	`
+	vc := &models.ModuleVersionCap{}
+	var maxVersions sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, moduleID).Scan(&maxVersions, &vc.Mode, &vc.ActiveVersions)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module version cap: %w", err)
+	}
+	if maxVersions.Valid {
+		n := int(maxVersions.Int64)
+		vc.MaxVersions = &n
+	}
+	return vc, nil
+}
+
+// GetVersionCapSettings returns the module's own max_versions and
+// version_cap_mode; nil means the organization default applies.
+func (r *ModuleRepository) GetVersionCapSettings(ctx context.Context, moduleID string) (*int, *string, error) {
+	var maxVersions sql.NullInt64
+	var mode sql.NullString
+	err := r.db.QueryRowContext(ctx,
+		`SELECT max_versions, version_cap_mode FROM modules WHERE id = $1`, moduleID).Scan(&maxVersions, &mode)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get module version cap settings: %w", err)
+	}
+	var maxPtr *int
+	if maxVersions.Valid {
+		n := int(maxVersions.Int64)
+		maxPtr = &n
+	}
+	var modePtr *string
+	if mode.Valid {
+		modePtr = &mode.String
+	}
+	return maxPtr, modePtr, nil
+}
+
+// SetVersionCapSettings sets the module's own max_versions and
+// version_cap_mode; nil clears a setting back to the organization default.
+func (r *ModuleRepository) SetVersionCapSettings(ctx context.Context, moduleID string, maxVersions *int, mode *string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE modules SET max_versions = $2, version_cap_mode = $3, updated_at = NOW() WHERE id = $1`,
+		moduleID, maxVersions, mode)
+	if err != nil {
+		return fmt.Errorf("failed to set module version cap settings: %w", err)
+	}
+	return nil
+}
+
+// ArchiveOldestVersions archives the module's oldest listed versions until at
+// most maxVersions remain listed, and returns the archived version strings.
+// Versions approved by any organization, and keepVersionID (the version just
+// published), are never archived, so fewer may be archived than needed.
+func (r *ModuleRepository) ArchiveOldestVersions(ctx context.Context, moduleID, keepVersionID string, maxVersions int) ([]string, error) {
+	query := `
+		UPDATE module_versions SET archived_at = NOW()
+		WHERE id IN (
+			SELECT mv.id FROM module_versions mv
+			WHERE mv.module_id = $1 AND mv.archived_at IS NULL AND mv.id <> $2
+			  AND NOT EXISTS (SELECT 1 FROM module_version_approvals a WHERE a.module_version_id = mv.id)
+			ORDER BY mv.created_at ASC
+			LIMIT GREATEST(
+				(SELECT COUNT(*) FROM module_versions c WHERE c.module_id = $1 AND c.archived_at IS NULL) - $3, 0)
+		)
+		RETURNING version
+	`
+	rows, err := r.db.QueryContext(ctx, query, moduleID, keepVersionID, maxVersions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive module versions: %w", err)
+	}
+	defer rows.Close()
+
+	var archived []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("failed to scan archived version: %w", err)
+		}
+		archived = append(archived, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archived versions: %w", err)
+	}
+	sort.Slice(archived, func(i, j int) bool {
+		return moduleCompareSemver(archived[i], archived[j]) < 0
+	})
+	return archived, nil
+}
+
+// ListArchivedVersions returns when each archived version of the module was
+// archived, keyed by version ID.
+func (r *ModuleRepository) ListArchivedVersions(ctx context.Context, moduleID string) (map[string]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, archived_at FROM module_versions WHERE module_id = $1 AND archived_at IS NOT NULL`, moduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived module versions: %w", err)
+	}
+	defer rows.Close()
+
+	archived := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var at time.Time
+		if err := rows.Scan(&id, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan archived module version: %w", err)
+		}
+		archived[id] = at
+	}
+	return archived, rows.Err()
+}
+
 // SearchModules searches for modules matching the query
 func (r *ModuleRepository) SearchModules(ctx context.Context, orgID, query, namespace, system string, limit, offset int) ([]*models.Module, int, error) {
 	// Build WHERE clause. Only filter by organization when orgID is provided
@@ -573,37 +1053,23 @@ var allowedModuleSortFields = map[string]bool{
 	"updated":   true,
 }
 
-// SearchModulesWithStats returns modules matching the search criteria along with
-// their latest version and total download count in a single query, eliminating
-// the N+1 query pattern from the original SearchModules + per-module ListVersions.
-// sortField controls result ordering: "relevance" (FTS rank), "name", "downloads",
-// "created", "updated", or "" (default: relevance when FTS is used, else created_at).
-// sortOrder is "asc" or "desc" (default "desc").
-func (r *ModuleRepository) SearchModulesWithStats(ctx context.Context, orgID, searchQuery, namespace, system string, limit, offset int, sortField, sortOrder string) ([]*models.ModuleSearchResult, int, error) {
-	// Validate and normalise sort parameters.
-	if !allowedModuleSortFields[sortField] {
-		sortField = ""
-	}
-	if sortOrder != "asc" && sortOrder != "desc" {
-		sortOrder = "desc"
-	}
-
-	// useFTS is true when the query is long enough for PostgreSQL full-text search.
-	useFTS := len(searchQuery) >= 3
-
-	// Build the WHERE clause via the shared whereBuilder (issue #565 finding
-	// [42]); capture the search term's placeholder index explicitly so the
-	// ts_rank expression below can reuse it without scanning the args slice
-	// for a value-equal string (which would pick the wrong index if, e.g.,
-	// orgID happened to equal searchQuery).
+// moduleSearchWhere builds the WHERE clause of a module search via the shared
+// whereBuilder (issue #565 finding [42]). It returns the search term's
+// placeholder index explicitly so the ts_rank expression can reuse it without
+// scanning the args slice for a value-equal string (which would pick the
+// wrong index if, e.g., orgID happened to equal searchQuery); 0 means no term.
+func moduleSearchWhere(orgID, ownerOrgID, searchQuery, namespace, system, tier string) (whereBuilder, int) {
 	var wb whereBuilder
 	searchArgIdx := 0
 	if orgID != "" {
 		wb.add("m.organization_id = $%d", orgID)
 	}
+	if ownerOrgID != "" {
+		wb.add("m.namespace IN (SELECT namespace FROM namespace_claims WHERE organization_id = $%d)", ownerOrgID)
+	}
 	if searchQuery != "" {
 		searchArgIdx = wb.nextPlaceholder()
-		if useFTS {
+		if len(searchQuery) >= 3 {
 			wb.add("m.search_vector @@ plainto_tsquery('english', $%d)", searchQuery)
 		} else {
 			wb.add("(m.namespace ILIKE $%d OR m.name ILIKE $%d OR m.description ILIKE $%d)", searchQuery+"%")
@@ -615,6 +1081,66 @@ func (r *ModuleRepository) SearchModulesWithStats(ctx context.Context, orgID, se
 	if system != "" {
 		wb.add("m.system = $%d", system)
 	}
+	if tier != "" {
+		wb.add("m.tier = $%d", tier)
+	}
+	return wb, searchArgIdx
+}
+
+// SearchModuleSystemFacets counts the modules matching a search per system.
+// The system filter itself is not applied, so the facet lists every system a
+// caller could narrow the search to. Systems are ordered by count, then name.
+func (r *ModuleRepository) SearchModuleSystemFacets(ctx context.Context, orgID, ownerOrgID, searchQuery, namespace, tier string) ([]models.ModuleSystemFacet, error) {
+	wb, _ := moduleSearchWhere(orgID, ownerOrgID, searchQuery, namespace, "", tier)
+	whereClause, args := wb.clause()
+
+	// #nosec G201 -- whereClause contains only parameterized SQL structural conditions; user values are passed via args
+	query := fmt.Sprintf(`
+		SELECT m.system, COUNT(*)
+		FROM modules m
+		%s
+		GROUP BY m.system
+		ORDER BY COUNT(*) DESC, m.system ASC
+	`, whereClause)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count modules by system: %w", err)
+	}
+	defer rows.Close()
+
+	facets := []models.ModuleSystemFacet{}
+	for rows.Next() {
+		var f models.ModuleSystemFacet
+		if err := rows.Scan(&f.System, &f.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan system facet: %w", err)
+		}
+		facets = append(facets, f)
+	}
+	return facets, rows.Err()
+}
+
+// SearchModulesWithStats returns modules matching the search criteria along with
+// their latest version and total download count in a single query, eliminating
+// the N+1 query pattern from the original SearchModules + per-module ListVersions.
+// sortField controls result ordering: "relevance" (FTS rank), "name", "downloads",
+// "created", "updated", or "" (default: relevance when FTS is used, else created_at).
+// sortOrder is "asc" or "desc" (default "desc"). A non-empty ownerOrgID limits
+// the results to the namespaces that organization has claimed; a non-empty
+// tier to modules of that registry-assigned tier.
+func (r *ModuleRepository) SearchModulesWithStats(ctx context.Context, orgID, ownerOrgID, searchQuery, namespace, system, tier string, limit, offset int, sortField, sortOrder string) ([]*models.ModuleSearchResult, int, error) {
+	// Validate and normalise sort parameters.
+	if !allowedModuleSortFields[sortField] {
+		sortField = ""
+	}
+	if sortOrder != "asc" && sortOrder != "desc" {
+		sortOrder = "desc"
+	}
+
+	// useFTS is true when the query is long enough for PostgreSQL full-text search.
+	useFTS := len(searchQuery) >= 3
+
+	wb, searchArgIdx := moduleSearchWhere(orgID, ownerOrgID, searchQuery, namespace, system, tier)
 	whereClause, args := wb.clause()
 
 	// Count total results
@@ -658,8 +1184,10 @@ func (r *ModuleRepository) SearchModulesWithStats(ctx context.Context, orgID, se
 	}
 
 	// Single query: modules + latest version + total downloads via lateral join.
-	// The lateral subquery fetches the latest version (by created_at) and sums
-	// download counts across ALL versions — replacing the per-module ListVersions loop.
+	// The lateral subquery fetches the latest version (highest semver core) and
+	// sums download counts across ALL versions — replacing the per-module
+	// ListVersions loop. Archived versions are never picked as latest, nor are
+	// pre-release versions unless the module opted in with include_prerelease.
 	// #nosec G201 -- whereClause contains only parameterized SQL structural conditions; user values are passed via args
 	searchSQL := fmt.Sprintf(`
 		SELECT m.id, m.organization_id, m.namespace, m.name, m.system, m.description, m.source,
@@ -671,11 +1199,13 @@ func (r *ModuleRepository) SearchModulesWithStats(ctx context.Context, orgID, se
 		LEFT JOIN users u ON m.created_by = u.id
 		LEFT JOIN LATERAL (
 			SELECT
-				(SELECT mv2.version FROM module_versions mv2 WHERE mv2.module_id = m.id
+				(SELECT mv2.version FROM module_versions mv2 WHERE mv2.module_id = m.id AND mv2.archived_at IS NULL
+			   AND (m.include_prerelease OR mv2.version !~ '^v?[0-9]+(\.[0-9]+)*[^0-9.+]')
 			 ORDER BY
 			   COALESCE(CAST(NULLIF(SPLIT_PART(REGEXP_REPLACE(REGEXP_REPLACE(mv2.version, '^v', ''), '[-+].*$', ''), '.', 1), '') AS INTEGER), 0) DESC,
 			   COALESCE(CAST(NULLIF(SPLIT_PART(REGEXP_REPLACE(REGEXP_REPLACE(mv2.version, '^v', ''), '[-+].*$', ''), '.', 2), '') AS INTEGER), 0) DESC,
-			   COALESCE(CAST(NULLIF(SPLIT_PART(REGEXP_REPLACE(REGEXP_REPLACE(mv2.version, '^v', ''), '[-+].*$', ''), '.', 3), '') AS INTEGER), 0) DESC
+			   COALESCE(CAST(NULLIF(SPLIT_PART(REGEXP_REPLACE(REGEXP_REPLACE(mv2.version, '^v', ''), '[-+].*$', ''), '.', 3), '') AS INTEGER), 0) DESC,
+			   mv2.version ~ '^v?[0-9]+(\.[0-9]+)*[^0-9.+]' ASC
 			 LIMIT 1) AS latest_version,
 				SUM(mv.download_count) AS total_downloads
 			FROM module_versions mv
@@ -729,6 +1259,42 @@ func (r *ModuleRepository) SearchModulesWithStats(ctx context.Context, orgID, se
 	return results, total, nil
 }
 
+// GetBadgeStats returns what a module's README badges show: its latest
+// release, the highest version that is neither archived nor a pre-release
+// (nil when there is none), and its total downloads across all versions.
+// When approvedBy is non-empty, only versions one of those organizations has
+// approved count as releases (see ModuleApprovalRepository).
+func (r *ModuleRepository) GetBadgeStats(ctx context.Context, moduleID string, approvedBy []string) (*string, int64, error) {
+	args := []interface{}{moduleID}
+	approval := ""
+	if len(approvedBy) > 0 {
+		approval = `AND EXISTS (SELECT 1 FROM module_version_approvals a
+		                        WHERE a.module_version_id = module_versions.id AND a.organization_id::text = ANY($2))`
+		args = append(args, pq.Array(approvedBy))
+	}
+	// #nosec G201 -- only the fixed approval clause and ordering are formatted in; values are passed as args
+	query := fmt.Sprintf(`
+		SELECT
+			(SELECT version FROM module_versions
+			 WHERE module_id = $1 AND archived_at IS NULL
+			   AND version !~ '^v?[0-9]+(\.[0-9]+)*[^0-9.+]'
+			   %s
+			 ORDER BY %s
+			 LIMIT 1),
+			(SELECT COALESCE(SUM(download_count), 0) FROM module_versions WHERE module_id = $1)
+	`, approval, semverOrder)
+
+	var latest sql.NullString
+	var downloads int64
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&latest, &downloads); err != nil {
+		return nil, 0, fmt.Errorf("failed to get module badge stats: %w", err)
+	}
+	if !latest.Valid {
+		return nil, downloads, nil
+	}
+	return &latest.String, downloads, nil
+}
+
 // DeleteModule deletes a module and all its versions (cascade)
 func (r *ModuleRepository) DeleteModule(ctx context.Context, moduleID string) error {
 	query := `DELETE FROM modules WHERE id = $1`
@@ -826,7 +1392,7 @@ func (r *ModuleRepository) GetVersionByID(ctx context.Context, id string) (*mode
 	query := `
 		SELECT id, module_id, version, storage_path, storage_backend, size_bytes, checksum, readme, published_by,
 		       download_count, COALESCE(deprecated, false), deprecated_at, deprecation_message, replacement_source, created_at,
-		       commit_sha, tag_name, scm_repo_id::text
+		       commit_sha, tag_name, scm_repo_id::text, required_terraform_version
 		FROM module_versions
 		WHERE id = $1
 	`
@@ -834,7 +1400,7 @@ func (r *ModuleRepository) GetVersionByID(ctx context.Context, id string) (*mode
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&v.ID, &v.ModuleID, &v.Version, &v.StoragePath, &v.StorageBackend, &v.SizeBytes, &v.Checksum,
 		&v.Readme, &v.PublishedBy, &v.DownloadCount, &v.Deprecated, &v.DeprecatedAt, &v.DeprecationMessage,
-		&v.ReplacementSource, &v.CreatedAt, &v.CommitSHA, &v.TagName, &v.SCMRepoID,
+		&v.ReplacementSource, &v.CreatedAt, &v.CommitSHA, &v.TagName, &v.SCMRepoID, &v.RequiredTerraformVersion,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -850,7 +1416,7 @@ func (r *ModuleRepository) GetVersionByChecksum(ctx context.Context, moduleID, c
 	query := `
 		SELECT id, module_id, version, storage_path, storage_backend, size_bytes, checksum, readme, published_by,
 		       download_count, COALESCE(deprecated, false), deprecated_at, deprecation_message, replacement_source, created_at,
-		       commit_sha, tag_name, scm_repo_id::text
+		       commit_sha, tag_name, scm_repo_id::text, required_terraform_version
 		FROM module_versions
 		WHERE module_id = $1 AND checksum = $2
 		LIMIT 1
@@ -859,7 +1425,7 @@ func (r *ModuleRepository) GetVersionByChecksum(ctx context.Context, moduleID, c
 	err := r.db.QueryRowContext(ctx, query, moduleID, checksum).Scan(
 		&v.ID, &v.ModuleID, &v.Version, &v.StoragePath, &v.StorageBackend, &v.SizeBytes, &v.Checksum,
 		&v.Readme, &v.PublishedBy, &v.DownloadCount, &v.Deprecated, &v.DeprecatedAt, &v.DeprecationMessage,
-		&v.ReplacementSource, &v.CreatedAt, &v.CommitSHA, &v.TagName, &v.SCMRepoID,
+		&v.ReplacementSource, &v.CreatedAt, &v.CommitSHA, &v.TagName, &v.SCMRepoID, &v.RequiredTerraformVersion,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -2,10 +2,12 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 )
 
@@ -23,14 +25,14 @@ var modVersionListCols = []string{
 	"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes",
 	"checksum", "readme", "published_by", "published_by_name", "download_count",
 	"deprecated", "deprecated_at", "deprecation_message", "replacement_source", "created_at",
-	"commit_sha", "tag_name", "scm_repo_id", "has_docs",
+	"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version", "has_docs",
 }
 
 var modVersionGetCols = []string{
 	"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes",
 	"checksum", "readme", "published_by", "download_count",
 	"deprecated", "deprecated_at", "deprecation_message", "replacement_source", "created_at",
-	"commit_sha", "tag_name", "scm_repo_id",
+	"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version",
 }
 
 var modCreateCols = []string{"id", "created_at", "updated_at"}
@@ -53,14 +55,14 @@ func sampleModVersionRow() *sqlmock.Rows {
 	return sqlmock.NewRows(modVersionGetCols).
 		AddRow("ver-1", "mod-1", "1.0.0", "path/file.tar.gz", "default",
 			int64(1024), "checksum", nil, nil, int64(5), false, nil, nil, nil, time.Now(),
-			nil, nil, nil)
+			nil, nil, nil, nil)
 }
 
 func sampleModVersionListRowsData() *sqlmock.Rows {
 	return sqlmock.NewRows(modVersionListCols).
 		AddRow("ver-1", "mod-1", "1.0.0", "path/file.tar.gz", "default",
 			int64(1024), "checksum", nil, nil, nil, int64(5), false, nil, nil, nil, time.Now(),
-			nil, nil, nil, false)
+			nil, nil, nil, nil, false)
 }
 
 func emptyModVersionRow() *sqlmock.Rows {
@@ -252,6 +254,41 @@ func TestCreateVersion_Success(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// FindVersionByChecksum / GetVersionAliasing
+// ---------------------------------------------------------------------------
+
+func TestFindVersionByChecksum(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	cols := []string{"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes", "checksum", "created_at"}
+	mock.ExpectQuery("SELECT.*FROM module_versions.*checksum = \\$2 AND alias_of IS NULL").
+		WithArgs("mod-1", "abc").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("ver-1", "mod-1", "1.0.0", "p/1.0.0.tgz", "local", int64(10), "abc", time.Now()))
+	mock.ExpectQuery("SELECT.*FROM module_versions.*checksum").
+		WithArgs("mod-1", "def").
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	v, err := repo.FindVersionByChecksum(context.Background(), "mod-1", "abc")
+	if err != nil || v == nil || v.Version != "1.0.0" || v.StoragePath != "p/1.0.0.tgz" {
+		t.Fatalf("FindVersionByChecksum = %+v, %v", v, err)
+	}
+	if v, err := repo.FindVersionByChecksum(context.Background(), "mod-1", "def"); err != nil || v != nil {
+		t.Errorf("FindVersionByChecksum(no match) = %+v, %v; want nil, nil", v, err)
+	}
+}
+
+func TestGetVersionAliasing(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectQuery("SELECT COALESCE\\(v.alias_of").
+		WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_of", "aliases"}).AddRow("", "{1.0.1,1.0.2}"))
+
+	aliasOf, aliases, err := repo.GetVersionAliasing(context.Background(), "ver-1")
+	if err != nil || aliasOf != "" || len(aliases) != 2 || aliases[1] != "1.0.2" {
+		t.Errorf("GetVersionAliasing = %q, %v, %v", aliasOf, aliases, err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // DeleteModule
 // ---------------------------------------------------------------------------
@@ -324,6 +361,202 @@ func TestIncrementDownloadCount_Success(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// SetVersionChangelog / GetVersionChangelog
+// ---------------------------------------------------------------------------
+
+func TestSetVersionChangelog_Success(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectExec("UPDATE module_versions SET changelog").
+		WithArgs("ver-1", "- added things").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.SetVersionChangelog(context.Background(), "ver-1", "- added things"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+func TestSetVersionChangelog_EmptyClears(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectExec("UPDATE module_versions SET changelog").
+		WithArgs("ver-1", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.SetVersionChangelog(context.Background(), "ver-1", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetVersionChangelog(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectQuery("SELECT changelog FROM module_versions").
+		WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"changelog"}).AddRow("- fixed"))
+
+	got, err := repo.GetVersionChangelog(context.Background(), "ver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || *got != "- fixed" {
+		t.Errorf("changelog = %v, want %q", got, "- fixed")
+	}
+
+	mock.ExpectQuery("SELECT changelog FROM module_versions").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+	got, err = repo.GetVersionChangelog(context.Background(), "missing")
+	if err != nil || got != nil {
+		t.Errorf("missing version: got %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestGetIncludePrerelease(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectQuery("SELECT include_prerelease FROM modules").
+		WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"include_prerelease"}).AddRow(true))
+
+	got, err := repo.GetIncludePrerelease(context.Background(), "mod-1")
+	if err != nil || !got {
+		t.Errorf("got %v, %v; want true, nil", got, err)
+	}
+
+	mock.ExpectQuery("SELECT include_prerelease FROM modules").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+	got, err = repo.GetIncludePrerelease(context.Background(), "missing")
+	if err != nil || got {
+		t.Errorf("missing module: got %v, %v; want false, nil", got, err)
+	}
+
+	mock.ExpectQuery("SELECT include_prerelease FROM modules").
+		WithArgs("mod-2").
+		WillReturnError(errDB)
+	if _, err := repo.GetIncludePrerelease(context.Background(), "mod-2"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSetIncludePrerelease(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectExec("UPDATE modules SET include_prerelease").
+		WithArgs("mod-1", true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetIncludePrerelease(context.Background(), "mod-1", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+func TestGetVersionCap(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectQuery("SELECT COALESCE\\(m.max_versions, p.default_max_versions\\)").
+		WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"max_versions", "mode", "active"}).AddRow(5, "rolling", 7))
+
+	got, err := repo.GetVersionCap(context.Background(), "mod-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.MaxVersions == nil || *got.MaxVersions != 5 || got.Mode != "rolling" || got.ActiveVersions != 7 {
+		t.Errorf("got %+v; want max 5, rolling, 7 active", got)
+	}
+
+	mock.ExpectQuery("SELECT COALESCE").
+		WithArgs("mod-2").
+		WillReturnRows(sqlmock.NewRows([]string{"max_versions", "mode", "active"}).AddRow(nil, "strict", 3))
+	got, err = repo.GetVersionCap(context.Background(), "mod-2")
+	if err != nil || got.MaxVersions != nil {
+		t.Errorf("uncapped module: got %+v, %v; want no max", got, err)
+	}
+
+	mock.ExpectQuery("SELECT COALESCE").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+	got, err = repo.GetVersionCap(context.Background(), "missing")
+	if err != nil || got != nil {
+		t.Errorf("missing module: got %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestGetVersionCapSettings(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectQuery("SELECT max_versions, version_cap_mode FROM modules").
+		WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"max_versions", "version_cap_mode"}).AddRow(10, "strict"))
+
+	maxVersions, mode, err := repo.GetVersionCapSettings(context.Background(), "mod-1")
+	if err != nil || maxVersions == nil || *maxVersions != 10 || mode == nil || *mode != "strict" {
+		t.Errorf("got %v, %v, %v; want 10, strict, nil", maxVersions, mode, err)
+	}
+
+	mock.ExpectQuery("SELECT max_versions, version_cap_mode FROM modules").
+		WithArgs("mod-2").
+		WillReturnRows(sqlmock.NewRows([]string{"max_versions", "version_cap_mode"}).AddRow(nil, nil))
+	maxVersions, mode, err = repo.GetVersionCapSettings(context.Background(), "mod-2")
+	if err != nil || maxVersions != nil || mode != nil {
+		t.Errorf("unset: got %v, %v, %v; want nil, nil, nil", maxVersions, mode, err)
+	}
+}
+
+func TestSetVersionCapSettings(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	maxVersions, mode := 20, "rolling"
+	mock.ExpectExec("UPDATE modules SET max_versions").
+		WithArgs("mod-1", &maxVersions, &mode).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetVersionCapSettings(context.Background(), "mod-1", &maxVersions, &mode); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations: %v", err)
+	}
+}
+
+func TestArchiveOldestVersions(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectQuery("UPDATE module_versions SET archived_at = NOW\\(\\)").
+		WithArgs("mod-1", "ver-new", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("1.10.0").AddRow("1.2.0"))
+
+	got, err := repo.ArchiveOldestVersions(context.Background(), "mod-1", "ver-new", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != "1.2.0" || got[1] != "1.10.0" {
+		t.Errorf("got %v; want [1.2.0 1.10.0]", got)
+	}
+
+	mock.ExpectQuery("UPDATE module_versions SET archived_at").
+		WillReturnError(errDB)
+	if _, err := repo.ArchiveOldestVersions(context.Background(), "mod-1", "ver-new", 3); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestListArchivedVersions(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	at := time.Now()
+	mock.ExpectQuery("SELECT id, archived_at FROM module_versions").
+		WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "archived_at"}).AddRow("ver-1", at))
+
+	got, err := repo.ListArchivedVersions(context.Background(), "mod-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || !got["ver-1"].Equal(at) {
+		t.Errorf("got %v; want ver-1 archived at %v", got, at)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // UpdateModule
 // ---------------------------------------------------------------------------
@@ -644,10 +877,10 @@ func sampleModVersionSourceCommitRows() *sqlmock.Rows {
 	return sqlmock.NewRows(modVersionSourceCommitCols).
 		AddRow("ver-1", "mod-1", "1.0.0", "path/file.tar.gz", "default",
 			int64(1024), "checksum", nil, nil, int64(5), false, nil, nil, nil, time.Now(),
-			"abc123", "v1.0.0", "scm-1").
+			"abc123", "v1.0.0", "scm-1", nil).
 		AddRow("ver-2", "mod-2", "2.0.0", "path/file2.tar.gz", "default",
 			int64(2048), "checksum2", nil, nil, int64(10), false, nil, nil, nil, time.Now(),
-			"def456", "v2.0.0", "scm-2")
+			"def456", "v2.0.0", "scm-2", nil)
 }
 
 func TestGetAllWithSourceCommit_Success(t *testing.T) {
@@ -744,7 +977,7 @@ func TestSearchModulesWithStats_Success(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM modules.*LEFT JOIN LATERAL").
 		WillReturnRows(sampleModuleSearchWithStatsRowFTS())
 
-	results, total, err := repo.SearchModulesWithStats(context.Background(), "org-1", "vpc", "", "", 10, 0, "", "")
+	results, total, err := repo.SearchModulesWithStats(context.Background(), "org-1", "", "vpc", "", "", "", 10, 0, "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -769,7 +1002,7 @@ func TestSearchModulesWithStats_Empty(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM modules.*LEFT JOIN LATERAL").
 		WillReturnRows(sqlmock.NewRows(moduleSearchWithStatsCols))
 
-	results, total, err := repo.SearchModulesWithStats(context.Background(), "", "", "", "", 10, 0, "", "")
+	results, total, err := repo.SearchModulesWithStats(context.Background(), "", "", "", "", "", "", 10, 0, "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -786,7 +1019,7 @@ func TestSearchModulesWithStats_CountError(t *testing.T) {
 	mock.ExpectQuery("SELECT COUNT").
 		WillReturnError(errDB)
 
-	_, _, err := repo.SearchModulesWithStats(context.Background(), "org-1", "vpc", "", "", 10, 0, "", "")
+	_, _, err := repo.SearchModulesWithStats(context.Background(), "org-1", "", "vpc", "", "", "", 10, 0, "", "")
 	if err == nil {
 		t.Error("expected error on count query failure")
 	}
@@ -799,7 +1032,7 @@ func TestSearchModulesWithStats_QueryError(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM modules.*LEFT JOIN LATERAL").
 		WillReturnError(errDB)
 
-	_, _, err := repo.SearchModulesWithStats(context.Background(), "org-1", "vpc", "", "", 10, 0, "", "")
+	_, _, err := repo.SearchModulesWithStats(context.Background(), "org-1", "", "vpc", "", "", "", 10, 0, "", "")
 	if err == nil {
 		t.Error("expected error on search query failure")
 	}
@@ -812,7 +1045,7 @@ func TestSearchModulesWithStats_WithAllFilters(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM modules.*LEFT JOIN LATERAL").
 		WillReturnRows(sampleModuleSearchWithStatsRowFTS())
 
-	results, total, err := repo.SearchModulesWithStats(context.Background(), "org-1", "vpc", "hashicorp", "aws", 10, 0, "", "")
+	results, total, err := repo.SearchModulesWithStats(context.Background(), "org-1", "", "vpc", "hashicorp", "aws", "", 10, 0, "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -833,12 +1066,28 @@ func TestSearchModulesWithStats_ScanError(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM modules.*LEFT JOIN LATERAL").
 		WillReturnRows(badRows)
 
-	_, _, err := repo.SearchModulesWithStats(context.Background(), "org-1", "", "", "", 10, 0, "", "")
+	_, _, err := repo.SearchModulesWithStats(context.Background(), "org-1", "", "", "", "", "", 10, 0, "", "")
 	if err == nil {
 		t.Error("expected scan error, got nil")
 	}
 }
 
+func TestSearchModulesWithStats_OwnerOrgScope(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM modules m WHERE m.namespace IN \(SELECT namespace FROM namespace_claims WHERE organization_id = \$1\) AND m.search_vector`).
+		WithArgs("org-blue", "vpc").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM modules.*LEFT JOIN LATERAL").
+		WillReturnRows(sampleModuleSearchWithStatsRowFTS())
+
+	if _, total, err := repo.SearchModulesWithStats(context.Background(), "", "org-blue", "vpc", "", "", "", 10, 0, "", ""); err != nil || total != 1 {
+		t.Fatalf("total = %d, err = %v", total, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Missing DBError tests for partially-covered methods
 // ---------------------------------------------------------------------------
@@ -926,7 +1175,7 @@ func TestModuleListVersionsPaginated_Success(t *testing.T) {
 		WithArgs("mod-1", 10, 0).
 		WillReturnRows(sampleModVersionListRowsData())
 
-	versions, total, err := repo.ListVersionsPaginated(context.Background(), "mod-1", 10, 0)
+	versions, total, err := repo.ListVersionsPaginated(context.Background(), "mod-1", false, 10, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -945,7 +1194,7 @@ func TestModuleListVersionsPaginated_CountError(t *testing.T) {
 		WithArgs("mod-1").
 		WillReturnError(errDB)
 
-	_, _, err := repo.ListVersionsPaginated(context.Background(), "mod-1", 10, 0)
+	_, _, err := repo.ListVersionsPaginated(context.Background(), "mod-1", false, 10, 0)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -962,7 +1211,7 @@ func TestModuleListVersionsPaginated_QueryError(t *testing.T) {
 		WithArgs("mod-1", 10, 0).
 		WillReturnError(errDB)
 
-	_, _, err := repo.ListVersionsPaginated(context.Background(), "mod-1", 10, 0)
+	_, _, err := repo.ListVersionsPaginated(context.Background(), "mod-1", false, 10, 0)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -979,7 +1228,7 @@ func TestModuleListVersionsPaginated_Empty(t *testing.T) {
 		WithArgs("mod-1", 10, 0).
 		WillReturnRows(emptyModVersionListRows())
 
-	versions, total, err := repo.ListVersionsPaginated(context.Background(), "mod-1", 10, 0)
+	versions, total, err := repo.ListVersionsPaginated(context.Background(), "mod-1", false, 10, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -991,6 +1240,80 @@ func TestModuleListVersionsPaginated_Empty(t *testing.T) {
 	}
 }
 
+func TestModuleListVersionsPaginated_HidePrereleases(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	// Both the count and the page hide pre-releases, before LIMIT/OFFSET,
+	// unless the module opted in.
+	mock.ExpectQuery("SELECT COUNT.*module_versions.version !~ '\\^v\\?.*include_prerelease").
+		WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM module_versions mv.*mv.version !~.*include_prerelease.*LIMIT \\$2 OFFSET \\$3").
+		WithArgs("mod-1", 10, 20).
+		WillReturnRows(sampleModVersionListRowsData())
+
+	versions, total, err := repo.ListVersionsPaginated(context.Background(), "mod-1", true, 10, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(versions) != 1 {
+		t.Errorf("total = %d, len = %d; want 1, 1", total, len(versions))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestModuleListVersionsPaginated_NoLimit(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	// LIMIT NULL returns every row.
+	mock.ExpectQuery("SELECT.*FROM module_versions").
+		WithArgs("mod-1", nil, 0).
+		WillReturnRows(sampleModVersionListRowsData())
+
+	if _, _, err := repo.ListVersionsPaginated(context.Background(), "mod-1", false, 0, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestModuleListApprovedVersionsPaginated_Success(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	orgs := pq.Array([]string{"org-1"})
+
+	mock.ExpectQuery("SELECT COUNT.*module_version_approvals").
+		WithArgs("mod-1", orgs).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery("SELECT.*FROM module_versions mv.*module_version_approvals").
+		WithArgs("mod-1", 10, 0, orgs).
+		WillReturnRows(sampleModVersionListRowsData())
+
+	versions, total, err := repo.ListApprovedVersionsPaginated(context.Background(), "mod-1", []string{"org-1"}, false, 10, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(versions) != 1 {
+		t.Errorf("total = %d, len = %d; want 1, 1", total, len(versions))
+	}
+}
+
+func TestModuleListApprovedVersionsPaginated_CountError(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectQuery("SELECT COUNT.*module_version_approvals").WillReturnError(errDB)
+
+	if _, _, err := repo.ListApprovedVersionsPaginated(context.Background(), "mod-1", []string{"org-1"}, false, 10, 0); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // GetVersionByID
 // ---------------------------------------------------------------------------
@@ -1039,3 +1362,215 @@ func TestGetVersionByID_DBError(t *testing.T) {
 		t.Error("expected error, got nil")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// ListVersionsFiltered
+// ---------------------------------------------------------------------------
+
+func TestModuleListVersionsFiltered_AppliesSQLFiltersAndPages(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(modVersionListCols)
+	for _, v := range []string{"1.0.0", "1.2.0", "1.1.0"} {
+		rows.AddRow("ver-"+v, "mod-1", v, "path/file.tar.gz", "default",
+			int64(1024), "checksum", nil, nil, nil, int64(5), false, nil, nil, nil, now,
+			nil, nil, nil, nil, false)
+	}
+	mock.ExpectQuery(`WHERE mv.module_id = \$1 AND COALESCE\(mv.deprecated, false\) = \$2 AND mv.archived_at IS NULL`).
+		WithArgs("mod-1", false).
+		WillReturnRows(rows)
+
+	notDeprecated, notArchived := false, false
+	versions, total, err := repo.ListVersionsFiltered(context.Background(), "mod-1", VersionListFilter{
+		Deprecated: &notDeprecated,
+		Archived:   &notArchived,
+		Limit:      2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("total = %d, want 3", total)
+	}
+	if len(versions) != 2 || versions[0].Version != "1.2.0" || versions[1].Version != "1.1.0" {
+		t.Errorf("versions = %v, want 1.2.0 then 1.1.0", versions)
+	}
+}
+
+func TestModuleListVersionsFiltered_QueryError(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectQuery("SELECT.*FROM module_versions").
+		WithArgs("mod-1").
+		WillReturnError(errDB)
+
+	if _, _, err := repo.ListVersionsFiltered(context.Background(), "mod-1", VersionListFilter{}); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// MoveModule / GetModuleRedirect / SearchModuleSystemFacets
+// ---------------------------------------------------------------------------
+
+func TestMoveModule_RecordsRedirect(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE modules").
+		WithArgs("mod-1", "acme", "generic").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+	mock.ExpectExec("DELETE FROM module_address_redirects").
+		WithArgs("org-1", "acme", "policies", "generic").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO module_address_redirects").
+		WithArgs("org-1", "acme", "policies", "all", "mod-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE module_scm_repos").
+		WithArgs("mod-1", "acme/policies/all", "acme/policies/generic").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	m := &models.Module{ID: "mod-1", OrganizationID: "org-1", Namespace: "acme", Name: "policies", System: "all"}
+	if err := repo.MoveModule(context.Background(), m, "acme", "generic"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.System != "generic" {
+		t.Errorf("System = %q, want generic", m.System)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestMoveModule_RollsBackOnError(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE modules").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+	mock.ExpectExec("DELETE FROM module_address_redirects").
+		WillReturnError(errDB)
+	mock.ExpectRollback()
+
+	m := &models.Module{ID: "mod-1", OrganizationID: "org-1", Namespace: "acme", Name: "policies", System: "all"}
+	if err := repo.MoveModule(context.Background(), m, "acme", "generic"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if m.System != "all" {
+		t.Errorf("System = %q, want all to be kept on failure", m.System)
+	}
+}
+
+func TestGetModuleRedirect_Found(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectQuery("SELECT.*FROM module_address_redirects").
+		WithArgs("org-1", "acme", "policies", "all").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "organization_id", "namespace", "name", "system", "module_id", "created_at",
+			"namespace", "name", "system",
+		}).AddRow("rd-1", "org-1", "acme", "policies", "all", "mod-1", time.Now(), "acme", "policies", "generic"))
+
+	rd, err := repo.GetModuleRedirect(context.Background(), "org-1", "acme", "policies", "all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rd == nil || rd.TargetSystem != "generic" {
+		t.Errorf("redirect = %+v, want target system generic", rd)
+	}
+}
+
+func TestGetModuleRedirect_NotFound(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectQuery("SELECT.*FROM module_address_redirects").
+		WillReturnError(sql.ErrNoRows)
+
+	rd, err := repo.GetModuleRedirect(context.Background(), "org-1", "acme", "policies", "all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rd != nil {
+		t.Errorf("redirect = %+v, want nil", rd)
+	}
+}
+
+func TestSearchModuleSystemFacets_IgnoresSystemFilter(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectQuery(`SELECT m.system, COUNT\(\*\)\s+FROM modules m\s+WHERE m.namespace = \$1\s+GROUP BY m.system`).
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"system", "count"}).
+			AddRow("aws", int64(4)).
+			AddRow("generic", int64(2)))
+
+	facets, err := repo.SearchModuleSystemFacets(context.Background(), "", "", "", "acme", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(facets) != 2 || facets[1].System != "generic" || facets[1].Count != 2 {
+		t.Errorf("facets = %+v", facets)
+	}
+}
+
+func TestSearchModuleSystemFacets_FiltersByTier(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectQuery(`SELECT m.system, COUNT\(\*\)\s+FROM modules m\s+WHERE m.namespace = \$1 AND m.tier = \$2\s+GROUP BY m.system`).
+		WithArgs("acme", "approved").
+		WillReturnRows(sqlmock.NewRows([]string{"system", "count"}).AddRow("aws", int64(1)))
+
+	facets, err := repo.SearchModuleSystemFacets(context.Background(), "", "", "", "acme", "approved")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(facets) != 1 || facets[0].Count != 1 {
+		t.Errorf("facets = %+v", facets)
+	}
+}
+
+func TestModuleSetTier_ReturnsPrevious(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+	mock.ExpectQuery("UPDATE modules.*FOR UPDATE.*RETURNING old.tier").
+		WithArgs("mod-1", "official").
+		WillReturnRows(sqlmock.NewRows([]string{"tier"}).AddRow("community"))
+
+	previous, err := repo.SetTier(context.Background(), "mod-1", "official")
+	if err != nil || previous != "community" {
+		t.Fatalf("got (%q, %v), want (community, nil)", previous, err)
+	}
+}
+
+func TestGetBadgeStats(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectQuery(`SELECT version FROM module_versions\s+WHERE module_id = \$1 AND archived_at IS NULL`).
+		WithArgs("mod-1").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "downloads"}).AddRow("1.4.0", int64(1200)))
+
+	latest, downloads, err := repo.GetBadgeStats(context.Background(), "mod-1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest == nil || *latest != "1.4.0" || downloads != 1200 {
+		t.Errorf("stats = %v, %d; want 1.4.0, 1200", latest, downloads)
+	}
+}
+
+func TestGetBadgeStats_ApprovedOnlyWithoutRelease(t *testing.T) {
+	repo, mock := newModuleRepo(t)
+
+	mock.ExpectQuery(`module_version_approvals a.*organization_id::text = ANY\(\$2\)`).
+		WithArgs("mod-1", pq.Array([]string{"org-1"})).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "downloads"}).AddRow(nil, int64(3)))
+
+	latest, downloads, err := repo.GetBadgeStats(context.Background(), "mod-1", []string{"org-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest != nil || downloads != 3 {
+		t.Errorf("stats = %v, %d; want no release, 3", latest, downloads)
+	}
+}
@@ -0,0 +1,284 @@
+// Package repositories - namespace_claim_request_repository.go persists
+// self-service namespace claim requests and verified organization domains.
+// Approving a request writes the namespace_claims ownership row in the same
+// transaction, so both live on the registry's own connection.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var (
+	// ErrClaimRequestPending is returned when the organization already has a
+	// pending request for the namespace.
+	ErrClaimRequestPending = errors.New("a claim request for this namespace is already pending")
+	// ErrClaimRequestNotPending is returned when deciding a request that has
+	// already been approved or rejected.
+	ErrClaimRequestNotPending = errors.New("claim request is not pending")
+	// ErrNamespaceOwnedByOther is returned when approving a request for a
+	// namespace another organization already owns.
+	ErrNamespaceOwnedByOther = errors.New("namespace is already owned by another organization")
+)
+
+// NamespaceClaimRequestRepository handles namespace claim request database
+// operations.
+type NamespaceClaimRequestRepository struct {
+	db *sql.DB
+}
+
+// NewNamespaceClaimRequestRepository creates a new namespace claim request
+// repository.
+func NewNamespaceClaimRequestRepository(db *sql.DB) *NamespaceClaimRequestRepository {
+	return &NamespaceClaimRequestRepository{db: db}
+}
+
+const claimRequestColumns = `id, namespace, organization_id, requested_by, reason, status,
+	auto_decided, decided_by, decided_at, decision_notes, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanClaimRequest(row rowScanner) (*models.NamespaceClaimRequest, error) {
+	r := &models.NamespaceClaimRequest{}
+	err := row.Scan(&r.ID, &r.Namespace, &r.OrganizationID, &r.RequestedBy, &r.Reason, &r.Status,
+		&r.AutoDecided, &r.DecidedBy, &r.DecidedAt, &r.DecisionNotes, &r.CreatedAt)
+	return r, err
+}
+
+// CreateRequest stores r with r.Status (pending, or rejected for a request
+// refused on submission) and fills in its ID and CreatedAt. It returns
+// ErrClaimRequestPending when a pending request for the same namespace and
+// organization exists.
+func (r *NamespaceClaimRequestRepository) CreateRequest(ctx context.Context, req *models.NamespaceClaimRequest) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO namespace_claim_requests
+			(namespace, organization_id, requested_by, reason, status, auto_decided, decision_notes, decided_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CASE WHEN $5 = 'pending' THEN NULL ELSE NOW() END)
+		ON CONFLICT (namespace, organization_id) WHERE status = 'pending' DO NOTHING
+		RETURNING id, decided_at, created_at`,
+		req.Namespace, req.OrganizationID, req.RequestedBy, req.Reason, string(req.Status), req.AutoDecided, req.DecisionNotes,
+	).Scan(&req.ID, &req.DecidedAt, &req.CreatedAt)
+	if err == sql.ErrNoRows {
+		return ErrClaimRequestPending
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create namespace claim request: %w", err)
+	}
+	return nil
+}
+
+// GetRequest returns a request by ID, or nil when it does not exist.
+func (r *NamespaceClaimRequestRepository) GetRequest(ctx context.Context, id string) (*models.NamespaceClaimRequest, error) {
+	req, err := scanClaimRequest(r.db.QueryRowContext(ctx,
+		`SELECT `+claimRequestColumns+` FROM namespace_claim_requests WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace claim request: %w", err)
+	}
+	return req, nil
+}
+
+// ListRequests returns requests, newest first, optionally filtered by status
+// and organization ("" for any).
+func (r *NamespaceClaimRequestRepository) ListRequests(ctx context.Context, status models.NamespaceClaimStatus, organizationID string) ([]*models.NamespaceClaimRequest, error) {
+	var where []string
+	var args []interface{}
+	if status != "" {
+		args = append(args, string(status))
+		where = append(where, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if organizationID != "" {
+		args = append(args, organizationID)
+		where = append(where, fmt.Sprintf("organization_id = $%d", len(args)))
+	}
+	query := `SELECT ` + claimRequestColumns + ` FROM namespace_claim_requests`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespace claim requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []*models.NamespaceClaimRequest{}
+	for rows.Next() {
+		req, err := scanClaimRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan namespace claim request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate namespace claim requests: %w", err)
+	}
+	return requests, nil
+}
+
+// ApproveRequest approves pending request id: it claims the namespace for the
+// request's organization and rejects the other organizations' pending
+// requests for the namespace, all in one transaction. decidedBy is nil for an
+// automatic approval. It returns the approved request and the requests it
+// rejected, ErrClaimRequestNotPending when id has already been decided, and
+// ErrNamespaceOwnedByOther when another organization owns the namespace.
+// It returns nil, nil, nil when id does not exist.
+func (r *NamespaceClaimRequestRepository) ApproveRequest(ctx context.Context, id string, decidedBy *string, notes *string) (*models.NamespaceClaimRequest, []*models.NamespaceClaimRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	req, err := scanClaimRequest(tx.QueryRowContext(ctx,
+		`SELECT `+claimRequestColumns+` FROM namespace_claim_requests WHERE id = $1 FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get namespace claim request: %w", err)
+	}
+	if req.Status != models.NamespaceClaimPending {
+		return nil, nil, ErrClaimRequestNotPending
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO namespace_claims (namespace, organization_id, claimed_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (namespace) DO NOTHING`,
+		req.Namespace, req.OrganizationID, req.RequestedBy); err != nil {
+		return nil, nil, fmt.Errorf("failed to claim namespace: %w", err)
+	}
+	var owner string
+	if err := tx.QueryRowContext(ctx,
+		`SELECT organization_id FROM namespace_claims WHERE namespace = $1`, req.Namespace).Scan(&owner); err != nil {
+		return nil, nil, fmt.Errorf("failed to read namespace claim: %w", err)
+	}
+	if owner != req.OrganizationID {
+		return nil, nil, ErrNamespaceOwnedByOther
+	}
+
+	approved, err := scanClaimRequest(tx.QueryRowContext(ctx, `
+		UPDATE namespace_claim_requests
+		SET status = 'approved', auto_decided = $2, decided_by = $3, decided_at = NOW(), decision_notes = $4
+		WHERE id = $1
+		RETURNING `+claimRequestColumns,
+		id, decidedBy == nil, decidedBy, notes))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to approve namespace claim request: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE namespace_claim_requests
+		SET status = 'rejected', auto_decided = true, decided_at = NOW(),
+		    decision_notes = 'The namespace was claimed by another organization.'
+		WHERE namespace = $1 AND status = 'pending' AND id <> $2
+		RETURNING `+claimRequestColumns,
+		req.Namespace, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reject competing namespace claim requests: %w", err)
+	}
+	var superseded []*models.NamespaceClaimRequest
+	for rows.Next() {
+		s, err := scanClaimRequest(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan namespace claim request: %w", err)
+		}
+		superseded = append(superseded, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to reject competing namespace claim requests: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit namespace claim approval: %w", err)
+	}
+	return approved, superseded, nil
+}
+
+// RejectRequest rejects pending request id. It returns
+// ErrClaimRequestNotPending when id has already been decided, and nil, nil
+// when it does not exist.
+func (r *NamespaceClaimRequestRepository) RejectRequest(ctx context.Context, id string, decidedBy *string, notes *string) (*models.NamespaceClaimRequest, error) {
+	req, err := scanClaimRequest(r.db.QueryRowContext(ctx, `
+		UPDATE namespace_claim_requests
+		SET status = 'rejected', auto_decided = false, decided_by = $2, decided_at = NOW(), decision_notes = $3
+		WHERE id = $1 AND status = 'pending'
+		RETURNING `+claimRequestColumns,
+		id, decidedBy, notes))
+	if err == sql.ErrNoRows {
+		existing, getErr := r.GetRequest(ctx, id)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if existing == nil {
+			return nil, nil
+		}
+		return nil, ErrClaimRequestNotPending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject namespace claim request: %w", err)
+	}
+	return req, nil
+}
+
+// GetVerifiedDomain returns an organization's verified domain, or nil.
+func (r *NamespaceClaimRequestRepository) GetVerifiedDomain(ctx context.Context, organizationID string) (*models.OrgVerifiedDomain, error) {
+	d := &models.OrgVerifiedDomain{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT organization_id, domain, verified_by, verified_at
+		FROM org_verified_domains WHERE organization_id = $1`, organizationID,
+	).Scan(&d.OrganizationID, &d.Domain, &d.VerifiedBy, &d.VerifiedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verified domain: %w", err)
+	}
+	return d, nil
+}
+
+// SetVerifiedDomain records d as its organization's verified domain,
+// replacing any previous one, and fills in VerifiedAt.
+func (r *NamespaceClaimRequestRepository) SetVerifiedDomain(ctx context.Context, d *models.OrgVerifiedDomain) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO org_verified_domains (organization_id, domain, verified_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			domain      = EXCLUDED.domain,
+			verified_by = EXCLUDED.verified_by,
+			verified_at = NOW()
+		RETURNING verified_at`,
+		d.OrganizationID, d.Domain, d.VerifiedBy,
+	).Scan(&d.VerifiedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set verified domain: %w", err)
+	}
+	return nil
+}
+
+// DeleteVerifiedDomain removes an organization's verified domain. It reports
+// whether one existed.
+func (r *NamespaceClaimRequestRepository) DeleteVerifiedDomain(ctx context.Context, organizationID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM org_verified_domains WHERE organization_id = $1`, organizationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete verified domain: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete verified domain: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var claimRequestCols = []string{"id", "namespace", "organization_id", "requested_by", "reason", "status",
+	"auto_decided", "decided_by", "decided_at", "decision_notes", "created_at"}
+
+func newNamespaceClaimRequestRepo(t *testing.T) (*NamespaceClaimRequestRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewNamespaceClaimRequestRepository(db), mock
+}
+
+func TestNamespaceClaimRequest_CreateRequest(t *testing.T) {
+	repo, mock := newNamespaceClaimRequestRepo(t)
+	mock.ExpectQuery("INSERT INTO namespace_claim_requests.*ON CONFLICT").
+		WithArgs("acme", "org-1", nil, "ours", "pending", false, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "decided_at", "created_at"}).AddRow("req-1", nil, time.Now()))
+	mock.ExpectQuery("INSERT INTO namespace_claim_requests").
+		WillReturnError(sql.ErrNoRows)
+
+	req := &models.NamespaceClaimRequest{Namespace: "acme", OrganizationID: "org-1", Reason: "ours", Status: models.NamespaceClaimPending}
+	if err := repo.CreateRequest(context.Background(), req); err != nil || req.ID != "req-1" {
+		t.Fatalf("CreateRequest = %v, id %q", err, req.ID)
+	}
+	if err := repo.CreateRequest(context.Background(), req); !errors.Is(err, ErrClaimRequestPending) {
+		t.Fatalf("CreateRequest(duplicate) = %v, want ErrClaimRequestPending", err)
+	}
+}
+
+func TestNamespaceClaimRequest_ListRequestsFilters(t *testing.T) {
+	repo, mock := newNamespaceClaimRequestRepo(t)
+	mock.ExpectQuery(`FROM namespace_claim_requests WHERE status = \$1 AND organization_id = \$2 ORDER BY created_at DESC`).
+		WithArgs("pending", "org-1").
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow("req-1", "acme", "org-1", "user-1", "", "pending", false, nil, nil, nil, time.Now()))
+	mock.ExpectQuery(`FROM namespace_claim_requests ORDER BY`).
+		WillReturnRows(sqlmock.NewRows(claimRequestCols))
+
+	reqs, err := repo.ListRequests(context.Background(), models.NamespaceClaimPending, "org-1")
+	if err != nil || len(reqs) != 1 || reqs[0].RequestedBy == nil || *reqs[0].RequestedBy != "user-1" {
+		t.Fatalf("ListRequests = %v, %v", reqs, err)
+	}
+	reqs, err = repo.ListRequests(context.Background(), "", "")
+	if err != nil || reqs == nil || len(reqs) != 0 {
+		t.Fatalf("ListRequests(all) = %v, %v; want empty, non-nil", reqs, err)
+	}
+}
+
+func TestNamespaceClaimRequest_ApproveRequest(t *testing.T) {
+	repo, mock := newNamespaceClaimRequestRepo(t)
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM namespace_claim_requests WHERE id = \\$1 FOR UPDATE").
+		WithArgs("req-1").
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow("req-1", "acme", "org-1", "user-1", "", "pending", false, nil, nil, nil, now))
+	mock.ExpectExec("INSERT INTO namespace_claims.*ON CONFLICT \\(namespace\\) DO NOTHING").
+		WithArgs("acme", "org-1", "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT organization_id FROM namespace_claims").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow("org-1"))
+	mock.ExpectQuery("UPDATE namespace_claim_requests.*SET status = 'approved'").
+		WithArgs("req-1", false, "admin-1", nil).
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow("req-1", "acme", "org-1", "user-1", "", "approved", false, "admin-1", now, nil, now))
+	mock.ExpectQuery("UPDATE namespace_claim_requests.*SET status = 'rejected'.*id <> \\$2").
+		WithArgs("acme", "req-1").
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow("req-2", "acme", "org-2", "user-2", "", "rejected", true, nil, now, "claimed", now))
+	mock.ExpectCommit()
+
+	admin := "admin-1"
+	approved, superseded, err := repo.ApproveRequest(context.Background(), "req-1", &admin, nil)
+	if err != nil {
+		t.Fatalf("ApproveRequest: %v", err)
+	}
+	if approved.Status != models.NamespaceClaimApproved || len(superseded) != 1 || superseded[0].OrganizationID != "org-2" {
+		t.Errorf("approved = %+v, superseded = %+v", approved, superseded)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNamespaceClaimRequest_ApproveRequest_OwnedByOther(t *testing.T) {
+	repo, mock := newNamespaceClaimRequestRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow("req-1", "acme", "org-1", nil, "", "pending", false, nil, nil, nil, time.Now()))
+	mock.ExpectExec("INSERT INTO namespace_claims").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT organization_id FROM namespace_claims").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow("org-9"))
+	mock.ExpectRollback()
+
+	if _, _, err := repo.ApproveRequest(context.Background(), "req-1", nil, nil); !errors.Is(err, ErrNamespaceOwnedByOther) {
+		t.Fatalf("ApproveRequest = %v, want ErrNamespaceOwnedByOther", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNamespaceClaimRequest_ApproveRequest_NotPending(t *testing.T) {
+	repo, mock := newNamespaceClaimRequestRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow("req-1", "acme", "org-1", nil, "", "rejected", true, nil, time.Now(), nil, time.Now()))
+	mock.ExpectRollback()
+
+	if _, _, err := repo.ApproveRequest(context.Background(), "req-1", nil, nil); !errors.Is(err, ErrClaimRequestNotPending) {
+		t.Fatalf("ApproveRequest = %v, want ErrClaimRequestNotPending", err)
+	}
+}
+
+func TestNamespaceClaimRequest_RejectRequest(t *testing.T) {
+	repo, mock := newNamespaceClaimRequestRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("UPDATE namespace_claim_requests.*WHERE id = \\$1 AND status = 'pending'").
+		WithArgs("req-1", nil, "no").
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow("req-1", "acme", "org-1", nil, "", "rejected", false, nil, now, "no", now))
+	mock.ExpectQuery("UPDATE namespace_claim_requests").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT .* FROM namespace_claim_requests WHERE id").
+		WillReturnRows(sqlmock.NewRows(claimRequestCols).
+			AddRow("req-1", "acme", "org-1", nil, "", "rejected", false, nil, now, "no", now))
+	mock.ExpectQuery("UPDATE namespace_claim_requests").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT .* FROM namespace_claim_requests WHERE id").WillReturnError(sql.ErrNoRows)
+
+	notes := "no"
+	r, err := repo.RejectRequest(context.Background(), "req-1", nil, &notes)
+	if err != nil || r.Status != models.NamespaceClaimRejected {
+		t.Fatalf("RejectRequest = %+v, %v", r, err)
+	}
+	if _, err := repo.RejectRequest(context.Background(), "req-1", nil, &notes); !errors.Is(err, ErrClaimRequestNotPending) {
+		t.Fatalf("RejectRequest(decided) = %v, want ErrClaimRequestNotPending", err)
+	}
+	if r, err := repo.RejectRequest(context.Background(), "req-x", nil, &notes); err != nil || r != nil {
+		t.Fatalf("RejectRequest(missing) = %+v, %v; want nil, nil", r, err)
+	}
+}
+
+func TestNamespaceClaimRequest_VerifiedDomain(t *testing.T) {
+	repo, mock := newNamespaceClaimRequestRepo(t)
+	mock.ExpectQuery("INSERT INTO org_verified_domains.*ON CONFLICT \\(organization_id\\) DO UPDATE").
+		WithArgs("org-1", "acme.com", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"verified_at"}).AddRow(time.Now()))
+	mock.ExpectQuery("FROM org_verified_domains").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("DELETE FROM org_verified_domains").
+		WithArgs("org-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetVerifiedDomain(context.Background(), &models.OrgVerifiedDomain{OrganizationID: "org-1", Domain: "acme.com"}); err != nil {
+		t.Fatalf("SetVerifiedDomain: %v", err)
+	}
+	if d, err := repo.GetVerifiedDomain(context.Background(), "org-2"); err != nil || d != nil {
+		t.Fatalf("GetVerifiedDomain(missing) = %+v, %v; want nil, nil", d, err)
+	}
+	if ok, err := repo.DeleteVerifiedDomain(context.Background(), "org-1"); err != nil || !ok {
+		t.Fatalf("DeleteVerifiedDomain = %v, %v", ok, err)
+	}
+}
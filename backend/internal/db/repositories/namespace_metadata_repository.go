@@ -0,0 +1,142 @@
+// Package repositories - namespace_metadata_repository.go persists namespace
+// landing-page content (description, links, avatar) and the counts shown
+// alongside it.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// NamespaceMetadataRepository handles namespace_metadata database operations.
+type NamespaceMetadataRepository struct {
+	db *sql.DB
+}
+
+// NewNamespaceMetadataRepository creates a new namespace metadata repository.
+func NewNamespaceMetadataRepository(db *sql.DB) *NamespaceMetadataRepository {
+	return &NamespaceMetadataRepository{db: db}
+}
+
+// GetMetadata returns the landing-page content of a namespace, or nil when
+// none has been published.
+func (r *NamespaceMetadataRepository) GetMetadata(ctx context.Context, namespace string) (*models.NamespaceMetadata, error) {
+	query := `
+		SELECT namespace, description, short_description, links, avatar_url,
+		       updated_by, created_at, updated_at
+		FROM namespace_metadata
+		WHERE namespace = $1
+	`
+
+	m := &models.NamespaceMetadata{}
+	var links []byte
+	err := r.db.QueryRowContext(ctx, query, namespace).Scan(
+		&m.Namespace,
+		&m.Description,
+		&m.ShortDescription,
+		&links,
+		&m.AvatarURL,
+		&m.UpdatedBy,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get namespace metadata: %w", err)
+	}
+	if len(links) > 0 {
+		if err := json.Unmarshal(links, &m.Links); err != nil {
+			return nil, fmt.Errorf("failed to decode namespace links: %w", err)
+		}
+	}
+	if m.Links == nil {
+		m.Links = []models.NamespaceLink{}
+	}
+	return m, nil
+}
+
+// UpsertMetadata creates or replaces the landing-page content of a namespace
+// and fills in the stored timestamps.
+func (r *NamespaceMetadataRepository) UpsertMetadata(ctx context.Context, m *models.NamespaceMetadata) error {
+	links := m.Links
+	if links == nil {
+		links = []models.NamespaceLink{}
+	}
+	linksJSON, err := json.Marshal(links)
+	if err != nil {
+		return fmt.Errorf("failed to encode namespace links: %w", err)
+	}
+
+	query := `
+		INSERT INTO namespace_metadata
+			(namespace, description, short_description, links, avatar_url, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (namespace) DO UPDATE
+			SET description       = EXCLUDED.description,
+			    short_description = EXCLUDED.short_description,
+			    links             = EXCLUDED.links,
+			    avatar_url        = EXCLUDED.avatar_url,
+			    updated_by        = EXCLUDED.updated_by,
+			    updated_at        = NOW()
+		RETURNING created_at, updated_at
+	`
+	if err := r.db.QueryRowContext(ctx, query,
+		m.Namespace, m.Description, m.ShortDescription, linksJSON, m.AvatarURL, m.UpdatedBy,
+	).Scan(&m.CreatedAt, &m.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save namespace metadata: %w", err)
+	}
+	m.Links = links
+	return nil
+}
+
+// ShortDescriptions returns the non-empty short descriptions of the given
+// namespaces, keyed by namespace. Namespaces without metadata are absent.
+func (r *NamespaceMetadataRepository) ShortDescriptions(ctx context.Context, namespaces []string) (map[string]string, error) {
+	result := make(map[string]string)
+	if len(namespaces) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT namespace, short_description
+		FROM namespace_metadata
+		WHERE namespace = ANY($1) AND short_description <> ''
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(namespaces))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespace short descriptions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var namespace, short string
+		if err := rows.Scan(&namespace, &short); err != nil {
+			return nil, fmt.Errorf("failed to scan namespace short description: %w", err)
+		}
+		result[namespace] = short
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate namespace short descriptions: %w", err)
+	}
+	return result, nil
+}
+
+// CountArtifacts returns how many modules and providers exist in a namespace.
+func (r *NamespaceMetadataRepository) CountArtifacts(ctx context.Context, namespace string) (modules, providers int, err error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM modules   WHERE namespace = $1),
+			(SELECT COUNT(*) FROM providers WHERE namespace = $1)
+	`
+	if err := r.db.QueryRowContext(ctx, query, namespace).Scan(&modules, &providers); err != nil {
+		return 0, 0, fmt.Errorf("failed to count namespace artifacts: %w", err)
+	}
+	return modules, providers, nil
+}
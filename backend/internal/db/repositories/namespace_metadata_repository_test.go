@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var namespaceMetadataCols = []string{
+	"namespace", "description", "short_description", "links", "avatar_url",
+	"updated_by", "created_at", "updated_at",
+}
+
+func newNamespaceMetadataRepo(t *testing.T) (*NamespaceMetadataRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewNamespaceMetadataRepository(db), mock
+}
+
+func TestNamespaceMetadata_GetMetadata(t *testing.T) {
+	repo, mock := newNamespaceMetadataRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("SELECT.*FROM namespace_metadata").
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows(namespaceMetadataCols).AddRow(
+			"acme", "# Acme\n\nNetworking modules", "Networking modules",
+			[]byte(`[{"label":"Runbook","url":"https://acme.example/runbook"}]`),
+			"https://acme.example/logo.png", "user-1", now, now,
+		))
+
+	m, err := repo.GetMetadata(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("GetMetadata: %v", err)
+	}
+	if m == nil || m.ShortDescription != "Networking modules" {
+		t.Fatalf("GetMetadata = %+v", m)
+	}
+	if len(m.Links) != 1 || m.Links[0].URL != "https://acme.example/runbook" {
+		t.Errorf("Links = %+v", m.Links)
+	}
+	if m.AvatarURL == nil || *m.AvatarURL != "https://acme.example/logo.png" {
+		t.Errorf("AvatarURL = %v", m.AvatarURL)
+	}
+}
+
+func TestNamespaceMetadata_GetMetadataNotFound(t *testing.T) {
+	repo, mock := newNamespaceMetadataRepo(t)
+	mock.ExpectQuery("SELECT.*FROM namespace_metadata").
+		WillReturnRows(sqlmock.NewRows(namespaceMetadataCols))
+
+	m, err := repo.GetMetadata(context.Background(), "acme")
+	if err != nil || m != nil {
+		t.Errorf("GetMetadata = (%v, %v), want (nil, nil)", m, err)
+	}
+}
+
+func TestNamespaceMetadata_UpsertMetadata(t *testing.T) {
+	repo, mock := newNamespaceMetadataRepo(t)
+	now := time.Now()
+	user := "user-1"
+	mock.ExpectQuery("INSERT INTO namespace_metadata.*ON CONFLICT \\(namespace\\) DO UPDATE").
+		WithArgs("acme", "Networking modules", "Networking modules", []byte(`[]`), nil, &user).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+
+	m := &models.NamespaceMetadata{
+		Namespace:        "acme",
+		Description:      "Networking modules",
+		ShortDescription: "Networking modules",
+		UpdatedBy:        &user,
+	}
+	if err := repo.UpsertMetadata(context.Background(), m); err != nil {
+		t.Fatalf("UpsertMetadata: %v", err)
+	}
+	if m.Links == nil || !m.UpdatedAt.Equal(now) {
+		t.Errorf("UpsertMetadata did not fill defaults: %+v", m)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestNamespaceMetadata_ShortDescriptions(t *testing.T) {
+	repo, mock := newNamespaceMetadataRepo(t)
+	mock.ExpectQuery("SELECT namespace, short_description FROM namespace_metadata").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace", "short_description"}).
+			AddRow("acme", "Networking modules"))
+
+	got, err := repo.ShortDescriptions(context.Background(), []string{"acme", "other"})
+	if err != nil {
+		t.Fatalf("ShortDescriptions: %v", err)
+	}
+	if len(got) != 1 || got["acme"] != "Networking modules" {
+		t.Errorf("ShortDescriptions = %v", got)
+	}
+
+	if got, err := repo.ShortDescriptions(context.Background(), nil); err != nil || len(got) != 0 {
+		t.Errorf("ShortDescriptions(nil) = (%v, %v), want an empty map and no query", got, err)
+	}
+}
+
+func TestNamespaceMetadata_CountArtifacts(t *testing.T) {
+	repo, mock := newNamespaceMetadataRepo(t)
+	mock.ExpectQuery("SELECT.*FROM modules.*FROM providers").
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"modules", "providers"}).AddRow(3, 1))
+
+	modules, providers, err := repo.CountArtifacts(context.Background(), "acme")
+	if err != nil || modules != 3 || providers != 1 {
+		t.Errorf("CountArtifacts = (%d, %d, %v), want (3, 1, nil)", modules, providers, err)
+	}
+}
@@ -6,6 +6,7 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -55,16 +56,55 @@ func (r *OIDCConfigRepository) IsSetupCompleted(ctx context.Context) (bool, erro
 	return completed, err
 }
 
-// SetSetupCompleted marks initial setup as completed and clears the setup token
-func (r *OIDCConfigRepository) SetSetupCompleted(ctx context.Context) error {
+// LockSetupState begins a transaction and reads the setup wizard state under
+// a lock on the system_settings row, held until the transaction ends. A
+// shared lock (FOR KEY SHARE) lets setup requests run side by side and still
+// update system_settings on other connections. An exclusive lock (FOR UPDATE)
+// waits for every shared holder to finish and holds off new ones, which then
+// read the state the exclusive holder committed. The caller must commit or
+// roll back the returned transaction.
+func (r *OIDCConfigRepository) LockSetupState(ctx context.Context, exclusive bool) (*sqlx.Tx, *models.SetupState, error) {
+	lock := "FOR KEY SHARE"
+	if exclusive {
+		lock = "FOR UPDATE"
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin setup transaction: %w", err)
+	}
+	state := &models.SetupState{}
+	query := `
+		SELECT setup_completed, setup_completed AND (NOT scanning_configured), COALESCE(setup_token_hash, '')
+		FROM system_settings WHERE id = 1 ` + lock
+	err = tx.QueryRowxContext(ctx, query).Scan(&state.Completed, &state.PendingFeature, &state.TokenHash)
+	if err != nil && err != sql.ErrNoRows {
+		_ = tx.Rollback()
+		return nil, nil, fmt.Errorf("failed to read setup state: %w", err)
+	}
+	return tx, state, nil
+}
+
+// CompleteSetup marks initial setup as completed, clears the setup token hash
+// and records when and from which client address it happened, then commits
+// tx. tx should hold the exclusive lock from LockSetupState, so no other
+// setup request is in flight when setup completes and none can start until
+// the token is gone.
+func (r *OIDCConfigRepository) CompleteSetup(ctx context.Context, tx *sqlx.Tx, completedBy string) error {
 	query := `
 		UPDATE system_settings SET
 			setup_completed = true,
 			setup_token_hash = NULL,
+			setup_completed_at = $1,
+			setup_completed_by = $2,
 			updated_at = $1
 		WHERE id = 1`
-	_, err := r.db.ExecContext(ctx, query, time.Now())
-	return err
+	if _, err := tx.ExecContext(ctx, query, time.Now(), completedBy); err != nil {
+		return fmt.Errorf("failed to mark setup completed: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit setup completion: %w", err)
+	}
+	return nil
 }
 
 // GetSetupTokenHash retrieves the bcrypt hash of the setup token
@@ -87,27 +87,98 @@ func TestIsSetupCompleted_Error(t *testing.T) {
 }
 
 // ---------------------------------------------------------------------------
-// SetSetupCompleted
+// LockSetupState / CompleteSetup
 // ---------------------------------------------------------------------------
 
-func TestSetSetupCompleted_Success(t *testing.T) {
+func TestLockSetupState(t *testing.T) {
+	for _, tc := range []struct {
+		exclusive bool
+		lock      string
+	}{{false, "FOR KEY SHARE"}, {true, "FOR UPDATE"}} {
+		repo, mock := newOIDCConfigRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT setup_completed, .*FROM system_settings WHERE id = 1 " + tc.lock).
+			WillReturnRows(sqlmock.NewRows([]string{"completed", "pending", "hash"}).AddRow(false, false, "$2a$hash"))
+
+		tx, state, err := repo.LockSetupState(context.Background(), tc.exclusive)
+		if err != nil {
+			t.Fatalf("LockSetupState(%v): %v", tc.exclusive, err)
+		}
+		if state.Completed || state.TokenHash != "$2a$hash" {
+			t.Errorf("state = %+v", state)
+		}
+		mock.ExpectRollback()
+		_ = tx.Rollback()
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestLockSetupState_NoRow(t *testing.T) {
 	repo, mock := newOIDCConfigRepo(t)
-	mock.ExpectExec("UPDATE system_settings SET").
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT setup_completed").WillReturnError(sql.ErrNoRows)
+
+	tx, state, err := repo.LockSetupState(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *state != (models.SetupState{}) {
+		t.Errorf("state = %+v, want zero", state)
+	}
+	mock.ExpectRollback()
+	_ = tx.Rollback()
+}
+
+func TestLockSetupState_Error(t *testing.T) {
+	repo, mock := newOIDCConfigRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT setup_completed").WillReturnError(errOIDCDB)
+	mock.ExpectRollback()
+
+	if _, _, err := repo.LockSetupState(context.Background(), true); err == nil {
+		t.Error("expected error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCompleteSetup_Success(t *testing.T) {
+	repo, mock := newOIDCConfigRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT setup_completed").
+		WillReturnRows(sqlmock.NewRows([]string{"completed", "pending", "hash"}).AddRow(false, false, "$2a$hash"))
+	mock.ExpectExec("UPDATE system_settings SET.*setup_token_hash = NULL.*setup_completed_by = \\$2").
+		WithArgs(sqlmock.AnyArg(), "203.0.113.7").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
-	err := repo.SetSetupCompleted(context.Background())
+	tx, _, err := repo.LockSetupState(context.Background(), true)
 	if err != nil {
+		t.Fatalf("LockSetupState: %v", err)
+	}
+	if err := repo.CompleteSetup(context.Background(), tx, "203.0.113.7"); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
 }
 
-func TestSetSetupCompleted_Error(t *testing.T) {
+func TestCompleteSetup_Error(t *testing.T) {
 	repo, mock := newOIDCConfigRepo(t)
-	mock.ExpectExec("UPDATE system_settings SET").
-		WillReturnError(errOIDCDB)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT setup_completed").
+		WillReturnRows(sqlmock.NewRows([]string{"completed", "pending", "hash"}).AddRow(false, false, ""))
+	mock.ExpectExec("UPDATE system_settings SET").WillReturnError(errOIDCDB)
 
-	err := repo.SetSetupCompleted(context.Background())
-	if err == nil {
+	tx, _, err := repo.LockSetupState(context.Background(), true)
+	if err != nil {
+		t.Fatalf("LockSetupState: %v", err)
+	}
+	if err := repo.CompleteSetup(context.Background(), tx, "203.0.113.7"); err == nil {
 		t.Error("expected error")
 	}
 }
@@ -0,0 +1,146 @@
+// Package repositories - org_api_key_policy_repository.go persists the
+// per-organization API key creation policies.
+//
+// org_api_key_policies is a feature table on the registry's own connection;
+// the organizations and api_keys it refers to live on the identity
+// connection, so callers resolve memberships and keys through the identity
+// repositories and only pass organization IDs in here.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// OrgAPIKeyPolicyRepository handles API key policy database operations.
+type OrgAPIKeyPolicyRepository struct {
+	db *sql.DB
+}
+
+// NewOrgAPIKeyPolicyRepository creates a new API key policy repository.
+func NewOrgAPIKeyPolicyRepository(db *sql.DB) *OrgAPIKeyPolicyRepository {
+	return &OrgAPIKeyPolicyRepository{db: db}
+}
+
+const orgAPIKeyPolicyColumns = `organization_id, max_lifetime_days, allowed_scopes, require_expiry, max_keys_per_user, created_at, updated_at`
+
+// Get returns the policy for an organization, or nil when it has none.
+func (r *OrgAPIKeyPolicyRepository) Get(ctx context.Context, orgID string) (*models.OrgAPIKeyPolicy, error) {
+	query := `SELECT ` + orgAPIKeyPolicyColumns + ` FROM org_api_key_policies WHERE organization_id = $1`
+
+	p, err := scanOrgAPIKeyPolicy(r.db.QueryRowContext(ctx, query, orgID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get api key policy: %w", err)
+	}
+	return p, nil
+}
+
+// ListByOrganizations returns the policies of the given organizations.
+// Organizations without a policy are simply absent from the result.
+func (r *OrgAPIKeyPolicyRepository) ListByOrganizations(ctx context.Context, orgIDs []string) ([]*models.OrgAPIKeyPolicy, error) {
+	if len(orgIDs) == 0 {
+		return nil, nil
+	}
+	query := `SELECT ` + orgAPIKeyPolicyColumns + ` FROM org_api_key_policies
+		WHERE organization_id::text = ANY($1)
+		ORDER BY organization_id`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(orgIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api key policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*models.OrgAPIKeyPolicy
+	for rows.Next() {
+		p, err := scanOrgAPIKeyPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate api key policies: %w", err)
+	}
+	return policies, nil
+}
+
+// Upsert creates or replaces the policy for p.OrganizationID and fills in its
+// timestamps.
+func (r *OrgAPIKeyPolicyRepository) Upsert(ctx context.Context, p *models.OrgAPIKeyPolicy) error {
+	var scopesJSON []byte
+	if p.AllowedScopes != nil {
+		var err error
+		scopesJSON, err = json.Marshal(p.AllowedScopes)
+		if err != nil {
+			return fmt.Errorf("failed to marshal allowed scopes: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO org_api_key_policies
+			(organization_id, max_lifetime_days, allowed_scopes, require_expiry, max_keys_per_user)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			max_lifetime_days = EXCLUDED.max_lifetime_days,
+			allowed_scopes    = EXCLUDED.allowed_scopes,
+			require_expiry    = EXCLUDED.require_expiry,
+			max_keys_per_user = EXCLUDED.max_keys_per_user,
+			updated_at        = NOW()
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		p.OrganizationID, p.MaxLifetimeDays, scopesJSON, p.RequireExpiry, p.MaxKeysPerUser,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert api key policy: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an organization's policy. It reports whether a policy existed.
+func (r *OrgAPIKeyPolicyRepository) Delete(ctx context.Context, orgID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM org_api_key_policies WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete api key policy: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete api key policy: %w", err)
+	}
+	return n > 0, nil
+}
+
+// scanOrgAPIKeyPolicy scans one orgAPIKeyPolicyColumns row.
+func scanOrgAPIKeyPolicy(row interface{ Scan(...any) error }) (*models.OrgAPIKeyPolicy, error) {
+	p := &models.OrgAPIKeyPolicy{}
+	var scopesJSON []byte
+	if err := row.Scan(
+		&p.OrganizationID,
+		&p.MaxLifetimeDays,
+		&scopesJSON,
+		&p.RequireExpiry,
+		&p.MaxKeysPerUser,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if scopesJSON != nil {
+		if err := json.Unmarshal(scopesJSON, &p.AllowedScopes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal allowed scopes: %w", err)
+		}
+		if p.AllowedScopes == nil {
+			p.AllowedScopes = []string{}
+		}
+	}
+	return p, nil
+}
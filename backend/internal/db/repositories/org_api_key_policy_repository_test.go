@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var orgAPIKeyPolicyCols = []string{
+	"organization_id", "max_lifetime_days", "allowed_scopes", "require_expiry", "max_keys_per_user", "created_at", "updated_at",
+}
+
+func newOrgAPIKeyPolicyRepo(t *testing.T) (*OrgAPIKeyPolicyRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewOrgAPIKeyPolicyRepository(db), mock
+}
+
+func TestOrgAPIKeyPolicy_Get_Found(t *testing.T) {
+	repo, mock := newOrgAPIKeyPolicyRepo(t)
+	mock.ExpectQuery("SELECT.*FROM org_api_key_policies WHERE organization_id").
+		WithArgs("org-1").
+		WillReturnRows(sqlmock.NewRows(orgAPIKeyPolicyCols).
+			AddRow("org-1", 90, []byte(`["modules:read"]`), true, 5, time.Now(), time.Now()))
+
+	p, err := repo.Get(context.Background(), "org-1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if p == nil || p.MaxLifetimeDays != 90 || !p.RequireExpiry || p.MaxKeysPerUser != 5 {
+		t.Fatalf("Get = %+v", p)
+	}
+	if len(p.AllowedScopes) != 1 || p.AllowedScopes[0] != "modules:read" {
+		t.Errorf("AllowedScopes = %v", p.AllowedScopes)
+	}
+}
+
+func TestOrgAPIKeyPolicy_Get_NullScopesAndNotFound(t *testing.T) {
+	repo, mock := newOrgAPIKeyPolicyRepo(t)
+	mock.ExpectQuery("SELECT.*FROM org_api_key_policies").
+		WillReturnRows(sqlmock.NewRows(orgAPIKeyPolicyCols).
+			AddRow("org-1", 0, nil, false, 0, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM org_api_key_policies").
+		WillReturnRows(sqlmock.NewRows(orgAPIKeyPolicyCols))
+
+	p, err := repo.Get(context.Background(), "org-1")
+	if err != nil || p == nil {
+		t.Fatalf("Get: %+v, %v", p, err)
+	}
+	if p.AllowedScopes != nil {
+		t.Errorf("NULL allowed_scopes should stay nil (any scope), got %v", p.AllowedScopes)
+	}
+
+	p, err = repo.Get(context.Background(), "org-2")
+	if err != nil || p != nil {
+		t.Fatalf("Get(missing) = %+v, %v; want nil, nil", p, err)
+	}
+}
+
+func TestOrgAPIKeyPolicy_Get_DBError(t *testing.T) {
+	repo, mock := newOrgAPIKeyPolicyRepo(t)
+	mock.ExpectQuery("SELECT.*FROM org_api_key_policies").WillReturnError(errDB)
+
+	if _, err := repo.Get(context.Background(), "org-1"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestOrgAPIKeyPolicy_ListByOrganizations(t *testing.T) {
+	repo, mock := newOrgAPIKeyPolicyRepo(t)
+
+	got, err := repo.ListByOrganizations(context.Background(), nil)
+	if err != nil || got != nil {
+		t.Fatalf("empty org list should not query: %v, %v", got, err)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM org_api_key_policies.*ANY").
+		WillReturnRows(sqlmock.NewRows(orgAPIKeyPolicyCols).
+			AddRow("org-1", 90, nil, false, 0, time.Now(), time.Now()).
+			AddRow("org-2", 30, []byte(`[]`), true, 2, time.Now(), time.Now()))
+
+	got, err = repo.ListByOrganizations(context.Background(), []string{"org-1", "org-2", "org-3"})
+	if err != nil {
+		t.Fatalf("ListByOrganizations: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2", len(got))
+	}
+	if got[1].AllowedScopes == nil || len(got[1].AllowedScopes) != 0 {
+		t.Errorf("empty allowlist should be non-nil and empty, got %#v", got[1].AllowedScopes)
+	}
+}
+
+func TestOrgAPIKeyPolicy_Upsert(t *testing.T) {
+	repo, mock := newOrgAPIKeyPolicyRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO org_api_key_policies.*ON CONFLICT").
+		WithArgs("org-1", 90, []byte(`["modules:read"]`), true, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+
+	p := &models.OrgAPIKeyPolicy{OrganizationID: "org-1", MaxLifetimeDays: 90, AllowedScopes: []string{"modules:read"}, RequireExpiry: true}
+	if err := repo.Upsert(context.Background(), p); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if !p.UpdatedAt.Equal(now) {
+		t.Errorf("UpdatedAt not populated")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOrgAPIKeyPolicy_Delete(t *testing.T) {
+	repo, mock := newOrgAPIKeyPolicyRepo(t)
+	mock.ExpectExec("DELETE FROM org_api_key_policies").WithArgs("org-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM org_api_key_policies").WithArgs("org-2").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if ok, err := repo.Delete(context.Background(), "org-1"); err != nil || !ok {
+		t.Errorf("Delete(existing) = %v, %v", ok, err)
+	}
+	if ok, err := repo.Delete(context.Background(), "org-2"); err != nil || ok {
+		t.Errorf("Delete(missing) = %v, %v", ok, err)
+	}
+}
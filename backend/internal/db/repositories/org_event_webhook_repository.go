@@ -0,0 +1,306 @@
+// Package repositories - org_event_webhook_repository.go persists
+// organizations' event webhooks and the log of deliveries made to them.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// OrgEventWebhookRepository handles organization event webhook database
+// operations.
+type OrgEventWebhookRepository struct {
+	db *sql.DB
+}
+
+// NewOrgEventWebhookRepository creates a new organization event webhook
+// repository.
+func NewOrgEventWebhookRepository(db *sql.DB) *OrgEventWebhookRepository {
+	return &OrgEventWebhookRepository{db: db}
+}
+
+const orgEventWebhookColumns = `id, organization_id, url, encrypted_secret, event_types, timeout_seconds,
+	       enabled, created_at, updated_at, secret_key_id, next_encrypted_secret, next_secret_key_id,
+	       rotation_started_at, rotation_overlap_until`
+
+// newSigningKeyIDSQL generates a secret's key ID, as the secret_key_id
+// column default does.
+const newSigningKeyIDSQL = `substr(md5(random()::text), 1, 16)`
+
+func scanOrgEventWebhook(row interface{ Scan(...interface{}) error }) (*models.OrgEventWebhook, error) {
+	w := &models.OrgEventWebhook{}
+	var eventTypes pq.StringArray
+	var nextSecret, nextKeyID sql.NullString
+	var startedAt, overlapUntil sql.NullTime
+	if err := row.Scan(&w.ID, &w.OrganizationID, &w.URL, &w.EncryptedSecret, &eventTypes, &w.TimeoutSeconds,
+		&w.Enabled, &w.CreatedAt, &w.UpdatedAt, &w.SecretKeyID, &nextSecret, &nextKeyID,
+		&startedAt, &overlapUntil); err != nil {
+		return nil, err
+	}
+	w.EventTypes = []string(eventTypes)
+	if w.EventTypes == nil {
+		w.EventTypes = []string{}
+	}
+	w.HasSecret = w.EncryptedSecret != ""
+	if nextSecret.Valid {
+		w.NextEncryptedSecret = nextSecret.String
+		w.SecretRotation = &models.OrgEventWebhookSecretRotation{
+			NextSecretKeyID: nextKeyID.String,
+			StartedAt:       startedAt.Time,
+			OverlapUntil:    overlapUntil.Time,
+		}
+	}
+	return w, nil
+}
+
+// List returns an organization's event webhooks, oldest first. With
+// enabledOnly, disabled webhooks are left out.
+func (r *OrgEventWebhookRepository) List(ctx context.Context, orgID string, enabledOnly bool) ([]*models.OrgEventWebhook, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+orgEventWebhookColumns+`
+		FROM org_event_webhooks
+		WHERE organization_id = $1 AND (enabled OR NOT $2)
+		ORDER BY created_at`, orgID, enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*models.OrgEventWebhook{}
+	for rows.Next() {
+		w, err := scanOrgEventWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate event webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// Get returns one of an organization's event webhooks, or nil when it has no
+// webhook with that ID.
+func (r *OrgEventWebhookRepository) Get(ctx context.Context, orgID, id string) (*models.OrgEventWebhook, error) {
+	w, err := scanOrgEventWebhook(r.db.QueryRowContext(ctx, `
+		SELECT `+orgEventWebhookColumns+`
+		FROM org_event_webhooks WHERE organization_id = $1 AND id = $2`, orgID, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get event webhook: %w", err)
+	}
+	return w, nil
+}
+
+// Create stores w and fills in its ID and timestamps.
+func (r *OrgEventWebhookRepository) Create(ctx context.Context, w *models.OrgEventWebhook) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO org_event_webhooks (organization_id, url, encrypted_secret, event_types, timeout_seconds, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, secret_key_id, created_at, updated_at`,
+		w.OrganizationID, w.URL, w.EncryptedSecret, pq.Array(w.EventTypes), w.TimeoutSeconds, w.Enabled,
+	).Scan(&w.ID, &w.SecretKeyID, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create event webhook: %w", err)
+	}
+	w.HasSecret = w.EncryptedSecret != ""
+	return nil
+}
+
+// Update replaces w's settings and fills in its key ID, rotation and
+// timestamps. Replacing the secret gives it a new key ID and abandons any
+// rotation in progress. It reports whether the webhook exists.
+func (r *OrgEventWebhookRepository) Update(ctx context.Context, w *models.OrgEventWebhook) (bool, error) {
+	updated, err := scanOrgEventWebhook(r.db.QueryRowContext(ctx, `
+		UPDATE org_event_webhooks SET
+			url                    = $3,
+			encrypted_secret       = $4,
+			event_types            = $5,
+			timeout_seconds        = $6,
+			enabled                = $7,
+			secret_key_id          = CASE WHEN encrypted_secret = $4 THEN secret_key_id ELSE `+newSigningKeyIDSQL+` END,
+			next_encrypted_secret  = CASE WHEN encrypted_secret = $4 THEN next_encrypted_secret END,
+			next_secret_key_id     = CASE WHEN encrypted_secret = $4 THEN next_secret_key_id END,
+			rotation_started_at    = CASE WHEN encrypted_secret = $4 THEN rotation_started_at END,
+			rotation_overlap_until = CASE WHEN encrypted_secret = $4 THEN rotation_overlap_until END,
+			updated_at             = NOW()
+		WHERE organization_id = $1 AND id = $2
+		RETURNING `+orgEventWebhookColumns,
+		w.OrganizationID, w.ID, w.URL, w.EncryptedSecret, pq.Array(w.EventTypes), w.TimeoutSeconds, w.Enabled,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to update event webhook: %w", err)
+	}
+	*w = *updated
+	return true, nil
+}
+
+// StartSecretRotation stores nextEncryptedSecret, under a new key ID, as the
+// secret an organization's event webhook rotates to, signing with both
+// secrets until overlapUntil. It returns the updated webhook, or nil when the
+// webhook does not exist or already has a rotation in progress.
+func (r *OrgEventWebhookRepository) StartSecretRotation(ctx context.Context, orgID, id, nextEncryptedSecret string, overlapUntil time.Time) (*models.OrgEventWebhook, error) {
+	w, err := scanOrgEventWebhook(r.db.QueryRowContext(ctx, `
+		UPDATE org_event_webhooks SET
+			next_encrypted_secret  = $3,
+			next_secret_key_id     = `+newSigningKeyIDSQL+`,
+			rotation_started_at    = NOW(),
+			rotation_overlap_until = $4,
+			updated_at             = NOW()
+		WHERE organization_id = $1 AND id = $2 AND next_encrypted_secret IS NULL
+		RETURNING `+orgEventWebhookColumns,
+		orgID, id, nextEncryptedSecret, overlapUntil))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to start event webhook secret rotation: %w", err)
+	}
+	return w, nil
+}
+
+// CompleteSecretRotation promotes the next secret of an organization's event
+// webhook, with its key ID, and retires the current one. It returns the
+// updated webhook, or nil when the webhook does not exist or has no rotation
+// in progress.
+func (r *OrgEventWebhookRepository) CompleteSecretRotation(ctx context.Context, orgID, id string) (*models.OrgEventWebhook, error) {
+	w, err := scanOrgEventWebhook(r.db.QueryRowContext(ctx, `
+		UPDATE org_event_webhooks SET
+			encrypted_secret       = next_encrypted_secret,
+			secret_key_id          = next_secret_key_id,
+			next_encrypted_secret  = NULL,
+			next_secret_key_id     = NULL,
+			rotation_started_at    = NULL,
+			rotation_overlap_until = NULL,
+			updated_at             = NOW()
+		WHERE organization_id = $1 AND id = $2 AND next_encrypted_secret IS NOT NULL
+		RETURNING `+orgEventWebhookColumns,
+		orgID, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to complete event webhook secret rotation: %w", err)
+	}
+	return w, nil
+}
+
+// Delete removes an organization's event webhook and its delivery log. It
+// reports whether there was a webhook to remove.
+func (r *OrgEventWebhookRepository) Delete(ctx context.Context, orgID, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM org_event_webhooks WHERE organization_id = $1 AND id = $2`, orgID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete event webhook: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete event webhook: %w", err)
+	}
+	return n > 0, nil
+}
+
+// CreateDelivery records a delivery to an event webhook and fills in its
+// creation time. d.ID must be set: it is the ID the receiver was sent.
+func (r *OrgEventWebhookRepository) CreateDelivery(ctx context.Context, d *models.OrgEventDelivery) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO org_event_deliveries
+			(id, webhook_id, event_id, event_type, schema_version, payload,
+			 outcome, status_code, attempts, error, duration_ms, replay_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at`,
+		d.ID, d.WebhookID, d.EventID, d.EventType, d.SchemaVersion, string(d.Payload),
+		d.Outcome, d.StatusCode, d.Attempts, d.Error, d.DurationMs, d.ReplayOf,
+	).Scan(&d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record event delivery: %w", err)
+	}
+	return nil
+}
+
+const orgEventDeliveryColumns = `id, webhook_id, event_id, event_type, schema_version, payload,
+	       outcome, status_code, attempts, error, duration_ms, replay_of, created_at`
+
+func scanOrgEventDelivery(row interface{ Scan(...interface{}) error }) (*models.OrgEventDelivery, error) {
+	d := &models.OrgEventDelivery{}
+	var payload []byte
+	var errMsg, replayOf sql.NullString
+	var statusCode sql.NullInt64
+	if err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.SchemaVersion, &payload,
+		&d.Outcome, &statusCode, &d.Attempts, &errMsg, &d.DurationMs, &replayOf, &d.CreatedAt); err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	if statusCode.Valid {
+		code := int(statusCode.Int64)
+		d.StatusCode = &code
+	}
+	if errMsg.Valid {
+		d.Error = &errMsg.String
+	}
+	if replayOf.Valid {
+		d.ReplayOf = &replayOf.String
+	}
+	return d, nil
+}
+
+// GetDelivery returns one of a webhook's deliveries, or nil when it has no
+// delivery with that ID.
+func (r *OrgEventWebhookRepository) GetDelivery(ctx context.Context, webhookID, id string) (*models.OrgEventDelivery, error) {
+	d, err := scanOrgEventDelivery(r.db.QueryRowContext(ctx, `
+		SELECT `+orgEventDeliveryColumns+`
+		FROM org_event_deliveries WHERE webhook_id = $1 AND id = $2`, webhookID, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get event delivery: %w", err)
+	}
+	return d, nil
+}
+
+// ListDeliveriesAfter returns up to limit of a webhook's deliveries, newest
+// first, starting after the delivery at after (from the newest when after is
+// nil).
+func (r *OrgEventWebhookRepository) ListDeliveriesAfter(ctx context.Context, webhookID string, after *Keyset, limit int) ([]*models.OrgEventDelivery, error) {
+	var wb whereBuilder
+	wb.add("webhook_id = $%d", webhookID)
+	if after != nil {
+		wb.addKeyset("created_at", "id", *after)
+	}
+	where, args := wb.clause()
+	// #nosec G201 -- where holds only fixed conditions; values are bound args
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT `+orgEventDeliveryColumns+`
+		FROM org_event_deliveries
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`, where, wb.nextPlaceholder()), append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.OrgEventDelivery{}
+	for rows.Next() {
+		d, err := scanOrgEventDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate event deliveries: %w", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var orgEventWebhookCols = []string{"id", "organization_id", "url", "encrypted_secret", "event_types", "timeout_seconds", "enabled", "created_at", "updated_at",
+	"secret_key_id", "next_encrypted_secret", "next_secret_key_id", "rotation_started_at", "rotation_overlap_until"}
+
+func newOrgEventWebhookRepo(t *testing.T) (*OrgEventWebhookRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewOrgEventWebhookRepository(db), mock
+}
+
+func TestOrgEventWebhookRepository_Get(t *testing.T) {
+	repo, mock := newOrgEventWebhookRepo(t)
+	mock.ExpectQuery("SELECT.*FROM org_event_webhooks WHERE organization_id").
+		WithArgs("org-1", "wh-1").
+		WillReturnRows(sqlmock.NewRows(orgEventWebhookCols).AddRow("wh-1", "org-1", "https://siem.example.com", "sealed",
+			"{api_key.created,api_key.revoked}", 10, true, time.Now(), time.Now(), "k1", nil, nil, nil, nil))
+	mock.ExpectQuery("SELECT.*FROM org_event_webhooks").WillReturnError(sql.ErrNoRows)
+
+	w, err := repo.Get(context.Background(), "org-1", "wh-1")
+	if err != nil || w == nil || !w.HasSecret || len(w.EventTypes) != 2 || !w.Wants("api_key.revoked") || w.Wants("scm.token_connected") {
+		t.Fatalf("Get = %+v, %v", w, err)
+	}
+	w, err = repo.Get(context.Background(), "org-1", "wh-2")
+	if err != nil || w != nil {
+		t.Fatalf("Get(missing) = %+v, %v; want nil, nil", w, err)
+	}
+}
+
+func TestOrgEventWebhookRepository_ListEnabledOnly(t *testing.T) {
+	repo, mock := newOrgEventWebhookRepo(t)
+	mock.ExpectQuery("FROM org_event_webhooks.*enabled OR NOT").
+		WithArgs("org-1", true).
+		WillReturnRows(sqlmock.NewRows(orgEventWebhookCols).AddRow("wh-1", "org-1", "https://siem.example.com", "sealed",
+			"{}", 10, true, time.Now(), time.Now(), "k1", nil, nil, nil, nil))
+
+	webhooks, err := repo.List(context.Background(), "org-1", true)
+	if err != nil || len(webhooks) != 1 || webhooks[0].EventTypes == nil || !webhooks[0].Wants("anything") {
+		t.Fatalf("List = %+v, %v", webhooks, err)
+	}
+}
+
+func TestOrgEventWebhookRepository_UpdateMissing(t *testing.T) {
+	repo, mock := newOrgEventWebhookRepo(t)
+	mock.ExpectQuery("UPDATE org_event_webhooks").
+		WithArgs("org-1", "wh-1", "https://siem.example.com", "sealed", pq.Array([]string{"api_key.created"}), 10, false).
+		WillReturnError(sql.ErrNoRows)
+
+	ok, err := repo.Update(context.Background(), &models.OrgEventWebhook{
+		ID: "wh-1", OrganizationID: "org-1", URL: "https://siem.example.com", EncryptedSecret: "sealed",
+		EventTypes: []string{"api_key.created"}, TimeoutSeconds: 10,
+	})
+	if err != nil || ok {
+		t.Fatalf("Update(missing) = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestOrgEventWebhookRepository_StartSecretRotation(t *testing.T) {
+	repo, mock := newOrgEventWebhookRepo(t)
+	overlapUntil := time.Now().Add(24 * time.Hour)
+	mock.ExpectQuery("UPDATE org_event_webhooks SET.*next_encrypted_secret IS NULL").
+		WithArgs("org-1", "wh-1", "sealed-next", overlapUntil).
+		WillReturnRows(sqlmock.NewRows(orgEventWebhookCols).AddRow("wh-1", "org-1", "https://siem.example.com", "sealed",
+			"{}", 10, true, time.Now(), time.Now(), "k1", "sealed-next", "k2", time.Now(), overlapUntil))
+	mock.ExpectQuery("UPDATE org_event_webhooks SET.*next_encrypted_secret IS NULL").WillReturnError(sql.ErrNoRows)
+
+	w, err := repo.StartSecretRotation(context.Background(), "org-1", "wh-1", "sealed-next", overlapUntil)
+	if err != nil || w == nil || w.SecretRotation == nil {
+		t.Fatalf("StartSecretRotation = %+v, %v", w, err)
+	}
+	if w.NextEncryptedSecret != "sealed-next" || w.SecretRotation.NextSecretKeyID != "k2" || !w.SecretRotation.OverlapUntil.Equal(overlapUntil) {
+		t.Errorf("rotation = %+v, next secret %q", w.SecretRotation, w.NextEncryptedSecret)
+	}
+	w, err = repo.StartSecretRotation(context.Background(), "org-1", "wh-1", "sealed-other", overlapUntil)
+	if err != nil || w != nil {
+		t.Fatalf("StartSecretRotation(in progress) = %+v, %v; want nil, nil", w, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOrgEventWebhookRepository_CompleteSecretRotation(t *testing.T) {
+	repo, mock := newOrgEventWebhookRepo(t)
+	mock.ExpectQuery("encrypted_secret += next_encrypted_secret.*next_encrypted_secret IS NOT NULL").
+		WithArgs("org-1", "wh-1").
+		WillReturnRows(sqlmock.NewRows(orgEventWebhookCols).AddRow("wh-1", "org-1", "https://siem.example.com", "sealed-next",
+			"{}", 10, true, time.Now(), time.Now(), "k2", nil, nil, nil, nil))
+
+	w, err := repo.CompleteSecretRotation(context.Background(), "org-1", "wh-1")
+	if err != nil || w == nil || w.SecretKeyID != "k2" || w.SecretRotation != nil || w.NextEncryptedSecret != "" {
+		t.Fatalf("CompleteSecretRotation = %+v, %v", w, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOrgEventWebhookRepository_GetDelivery(t *testing.T) {
+	repo, mock := newOrgEventWebhookRepo(t)
+	cols := []string{"id", "webhook_id", "event_id", "event_type", "schema_version", "payload",
+		"outcome", "status_code", "attempts", "error", "duration_ms", "replay_of", "created_at"}
+	mock.ExpectQuery("FROM org_event_deliveries WHERE webhook_id").
+		WithArgs("wh-1", "d-2").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("d-2", "wh-1", "ev-1", "api_key.created", 1, []byte(`{"id":"ev-1"}`),
+			models.OrgEventOutcomeFailed, nil, 1, "connection refused", 5, "d-1", time.Now()))
+
+	d, err := repo.GetDelivery(context.Background(), "wh-1", "d-2")
+	if err != nil || d == nil {
+		t.Fatalf("GetDelivery = %+v, %v", d, err)
+	}
+	if string(d.Payload) != `{"id":"ev-1"}` || d.StatusCode != nil || d.Error == nil || d.ReplayOf == nil || *d.ReplayOf != "d-1" {
+		t.Errorf("delivery = %+v", d)
+	}
+}
+
+func TestOrgEventWebhookRepository_ListDeliveriesAfter(t *testing.T) {
+	repo, mock := newOrgEventWebhookRepo(t)
+	cols := []string{"id", "webhook_id", "event_id", "event_type", "schema_version", "payload",
+		"outcome", "status_code", "attempts", "error", "duration_ms", "replay_of", "created_at"}
+	after := Keyset{CreatedAt: time.Now().UTC(), ID: "3f2a8c3e-6c1d-4d59-9a55-1d5e7a8d4b21"}
+	mock.ExpectQuery(`WHERE webhook_id = \$1 AND \(created_at, id\) < \(\$2, \$3::uuid\)\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$4`).
+		WithArgs("wh-1", after.CreatedAt, after.ID, 51).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("d-1", "wh-1", "ev-1", "api_key.created", 1, []byte(`{}`),
+			models.OrgEventOutcomeDelivered, 200, 1, nil, 5, nil, time.Now()))
+
+	deliveries, err := repo.ListDeliveriesAfter(context.Background(), "wh-1", &after, 51)
+	if err != nil || len(deliveries) != 1 || deliveries[0].ID != "d-1" {
+		t.Fatalf("ListDeliveriesAfter = %+v, %v", deliveries, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
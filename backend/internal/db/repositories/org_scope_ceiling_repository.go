@@ -0,0 +1,129 @@
+// Package repositories - org_scope_ceiling_repository.go persists the
+// per-organization scope ceilings applied to issued session tokens.
+//
+// org_scope_ceilings is a feature table on the registry's own connection;
+// the organizations and memberships it refers to live on the identity
+// connection, so callers resolve memberships through the identity
+// repositories and only pass organization IDs in here.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// OrgScopeCeilingRepository handles scope ceiling database operations.
+type OrgScopeCeilingRepository struct {
+	db *sql.DB
+}
+
+// NewOrgScopeCeilingRepository creates a new scope ceiling repository.
+func NewOrgScopeCeilingRepository(db *sql.DB) *OrgScopeCeilingRepository {
+	return &OrgScopeCeilingRepository{db: db}
+}
+
+const orgScopeCeilingColumns = `organization_id, scopes, created_at, updated_at`
+
+// Get returns the ceiling of an organization, or nil when it has none.
+func (r *OrgScopeCeilingRepository) Get(ctx context.Context, orgID string) (*models.OrgScopeCeiling, error) {
+	query := `SELECT ` + orgScopeCeilingColumns + ` FROM org_scope_ceilings WHERE organization_id = $1`
+
+	ceiling, err := scanOrgScopeCeiling(r.db.QueryRowContext(ctx, query, orgID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get scope ceiling: %w", err)
+	}
+	return ceiling, nil
+}
+
+// ListByOrganizations returns the ceilings of the given organizations.
+// Organizations without a ceiling are simply absent from the result.
+func (r *OrgScopeCeilingRepository) ListByOrganizations(ctx context.Context, orgIDs []string) ([]*models.OrgScopeCeiling, error) {
+	if len(orgIDs) == 0 {
+		return nil, nil
+	}
+	query := `SELECT ` + orgScopeCeilingColumns + ` FROM org_scope_ceilings
+		WHERE organization_id::text = ANY($1)
+		ORDER BY organization_id`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(orgIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scope ceilings: %w", err)
+	}
+	defer rows.Close()
+
+	var ceilings []*models.OrgScopeCeiling
+	for rows.Next() {
+		ceiling, err := scanOrgScopeCeiling(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scope ceiling: %w", err)
+		}
+		ceilings = append(ceilings, ceiling)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate scope ceilings: %w", err)
+	}
+	return ceilings, nil
+}
+
+// Upsert creates or replaces the ceiling for c.OrganizationID and fills in
+// its timestamps.
+func (r *OrgScopeCeilingRepository) Upsert(ctx context.Context, c *models.OrgScopeCeiling) error {
+	if c.Scopes == nil {
+		c.Scopes = []string{}
+	}
+	scopesJSON, err := json.Marshal(c.Scopes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ceiling scopes: %w", err)
+	}
+
+	query := `
+		INSERT INTO org_scope_ceilings (organization_id, scopes)
+		VALUES ($1, $2)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			scopes     = EXCLUDED.scopes,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	if err := r.db.QueryRowContext(ctx, query, c.OrganizationID, scopesJSON).Scan(&c.CreatedAt, &c.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to upsert scope ceiling: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an organization's ceiling. It reports whether a ceiling
+// existed.
+func (r *OrgScopeCeilingRepository) Delete(ctx context.Context, orgID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM org_scope_ceilings WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete scope ceiling: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete scope ceiling: %w", err)
+	}
+	return n > 0, nil
+}
+
+// scanOrgScopeCeiling scans one orgScopeCeilingColumns row.
+func scanOrgScopeCeiling(row interface{ Scan(...any) error }) (*models.OrgScopeCeiling, error) {
+	c := &models.OrgScopeCeiling{}
+	var scopesJSON []byte
+	if err := row.Scan(&c.OrganizationID, &scopesJSON, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopesJSON, &c.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ceiling scopes: %w", err)
+	}
+	if c.Scopes == nil {
+		c.Scopes = []string{}
+	}
+	return c, nil
+}
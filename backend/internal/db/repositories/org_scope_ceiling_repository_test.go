@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var orgScopeCeilingCols = []string{"organization_id", "scopes", "created_at", "updated_at"}
+
+func newOrgScopeCeilingRepo(t *testing.T) (*OrgScopeCeilingRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewOrgScopeCeilingRepository(db), mock
+}
+
+func TestOrgScopeCeiling_Get(t *testing.T) {
+	repo, mock := newOrgScopeCeilingRepo(t)
+	mock.ExpectQuery("SELECT.*FROM org_scope_ceilings WHERE organization_id").
+		WithArgs("org-1").
+		WillReturnRows(sqlmock.NewRows(orgScopeCeilingCols).AddRow("org-1", []byte(`["modules:read"]`), time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM org_scope_ceilings").
+		WillReturnRows(sqlmock.NewRows(orgScopeCeilingCols))
+
+	c, err := repo.Get(context.Background(), "org-1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if c == nil || len(c.Scopes) != 1 || c.Scopes[0] != "modules:read" {
+		t.Fatalf("Get = %+v", c)
+	}
+	c, err = repo.Get(context.Background(), "org-2")
+	if err != nil || c != nil {
+		t.Errorf("Get without a ceiling = %+v, %v; want nil, nil", c, err)
+	}
+}
+
+func TestOrgScopeCeiling_ListByOrganizations(t *testing.T) {
+	repo, mock := newOrgScopeCeilingRepo(t)
+
+	if got, err := repo.ListByOrganizations(context.Background(), nil); err != nil || got != nil {
+		t.Fatalf("ListByOrganizations(nil) = %v, %v", got, err)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM org_scope_ceilings.*ANY").
+		WillReturnRows(sqlmock.NewRows(orgScopeCeilingCols).
+			AddRow("org-1", []byte(`["modules:read"]`), time.Now(), time.Now()).
+			AddRow("org-2", []byte(`[]`), time.Now(), time.Now()))
+	got, err := repo.ListByOrganizations(context.Background(), []string{"org-1", "org-2", "org-3"})
+	if err != nil {
+		t.Fatalf("ListByOrganizations: %v", err)
+	}
+	if len(got) != 2 || got[1].Scopes == nil || len(got[1].Scopes) != 0 {
+		t.Errorf("ListByOrganizations = %+v", got)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM org_scope_ceilings").WillReturnError(errors.New("boom"))
+	if _, err := repo.ListByOrganizations(context.Background(), []string{"org-1"}); err == nil {
+		t.Error("ListByOrganizations: want error")
+	}
+}
+
+func TestOrgScopeCeiling_Upsert(t *testing.T) {
+	repo, mock := newOrgScopeCeilingRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO org_scope_ceilings").
+		WithArgs("org-1", []byte(`[]`)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+
+	c := &models.OrgScopeCeiling{OrganizationID: "org-1"}
+	if err := repo.Upsert(context.Background(), c); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if !c.CreatedAt.Equal(now) || c.Scopes == nil {
+		t.Errorf("Upsert left %+v", c)
+	}
+}
+
+func TestOrgScopeCeiling_Delete(t *testing.T) {
+	repo, mock := newOrgScopeCeilingRepo(t)
+	mock.ExpectExec("DELETE FROM org_scope_ceilings").WithArgs("org-1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deleted, err := repo.Delete(context.Background(), "org-1")
+	if err != nil || !deleted {
+		t.Errorf("Delete = %v, %v", deleted, err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package repositories - organization_stats_repository.go aggregates per-
+// organization counts for the admin organization listing.
+//
+// Modules, providers and mirror configurations live on the registry's own
+// connection while memberships live on the identity connection, so the two
+// sides are aggregated separately (one grouped query each) and merged in Go —
+// never joined across connections and never computed with per-row subqueries.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// OrganizationStatsRepository computes organization usage statistics.
+type OrganizationStatsRepository struct {
+	db         *sql.DB
+	identityDB *sql.DB
+}
+
+// NewOrganizationStatsRepository creates a new organization stats repository.
+// db is the registry's domain connection; identityDB backs memberships.
+func NewOrganizationStatsRepository(db, identityDB *sql.DB) *OrganizationStatsRepository {
+	return &OrganizationStatsRepository{db: db, identityDB: identityDB}
+}
+
+// registryStatsQuery aggregates each feature table once with GROUP BY and
+// merges the groups with full outer joins. $1 is an optional uuid[] filter.
+const registryStatsQuery = `
+	WITH module_stats AS (
+		SELECT m.organization_id, COUNT(DISTINCT m.id) AS modules, COALESCE(SUM(mv.size_bytes), 0) AS bytes
+		FROM modules m
+		LEFT JOIN module_versions mv ON mv.module_id = m.id
+		WHERE m.organization_id IS NOT NULL AND ($1::uuid[] IS NULL OR m.organization_id = ANY($1::uuid[]))
+		GROUP BY m.organization_id
+	), provider_stats AS (
+		SELECT p.organization_id, COUNT(DISTINCT p.id) AS providers, COALESCE(SUM(pp.size_bytes), 0) AS bytes
+		FROM providers p
+		LEFT JOIN provider_versions pv ON pv.provider_id = p.id
+		LEFT JOIN provider_platforms pp ON pp.provider_version_id = pv.id
+		WHERE p.organization_id IS NOT NULL AND ($1::uuid[] IS NULL OR p.organization_id = ANY($1::uuid[]))
+		GROUP BY p.organization_id
+	), mirror_stats AS (
+		SELECT organization_id, COUNT(*) AS mirrors
+		FROM mirror_configurations
+		WHERE organization_id IS NOT NULL AND ($1::uuid[] IS NULL OR organization_id = ANY($1::uuid[]))
+		GROUP BY organization_id
+	)
+	SELECT COALESCE(ms.organization_id, ps.organization_id, mi.organization_id)::text,
+	       COALESCE(ms.modules, 0), COALESCE(ps.providers, 0), COALESCE(mi.mirrors, 0),
+	       COALESCE(ms.bytes, 0) + COALESCE(ps.bytes, 0)
+	FROM module_stats ms
+	FULL OUTER JOIN provider_stats ps ON ps.organization_id = ms.organization_id
+	FULL OUTER JOIN mirror_stats mi ON mi.organization_id = COALESCE(ms.organization_id, ps.organization_id)
+`
+
+const memberStatsQuery = `
+	SELECT organization_id::text, COUNT(*)
+	FROM organization_members
+	WHERE $1::uuid[] IS NULL OR organization_id = ANY($1::uuid[])
+	GROUP BY organization_id
+`
+
+// ListStats returns statistics keyed by organization ID. When orgIDs is nil
+// every organization is aggregated; otherwise only the listed ones, each of
+// which is present in the result (zeroed when it owns nothing).
+func (r *OrganizationStatsRepository) ListStats(ctx context.Context, orgIDs []string) (map[string]*models.OrganizationStats, error) {
+	stats := make(map[string]*models.OrganizationStats, len(orgIDs))
+	for _, id := range orgIDs {
+		stats[id] = &models.OrganizationStats{}
+	}
+	get := func(id string) *models.OrganizationStats {
+		s, ok := stats[id]
+		if !ok {
+			s = &models.OrganizationStats{}
+			stats[id] = s
+		}
+		return s
+	}
+	if orgIDs != nil && len(orgIDs) == 0 {
+		return stats, nil
+	}
+	filter := pq.Array(orgIDs)
+
+	rows, err := r.db.QueryContext(ctx, registryStatsQuery, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate organization stats: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var modules, providers, mirrors, bytes int64
+		if err := rows.Scan(&id, &modules, &providers, &mirrors, &bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan organization stats: %w", err)
+		}
+		s := get(id)
+		s.ModuleCount, s.ProviderCount, s.MirrorConfigCount, s.StorageBytes = modules, providers, mirrors, bytes
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate organization stats: %w", err)
+	}
+
+	memberRows, err := r.identityDB.QueryContext(ctx, memberStatsQuery, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count organization members: %w", err)
+	}
+	defer memberRows.Close()
+	for memberRows.Next() {
+		var id string
+		var members int64
+		if err := memberRows.Scan(&id, &members); err != nil {
+			return nil, fmt.Errorf("failed to scan organization member count: %w", err)
+		}
+		get(id).MemberCount = members
+	}
+	if err := memberRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate organization member counts: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newOrgStatsRepo(t *testing.T) (*OrganizationStatsRepository, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	identityDB, identityMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New (identity): %v", err)
+	}
+	t.Cleanup(func() { identityDB.Close() })
+	return NewOrganizationStatsRepository(db, identityDB), mock, identityMock
+}
+
+func TestOrganizationStats_ListStats_MergesBothConnections(t *testing.T) {
+	repo, mock, identityMock := newOrgStatsRepo(t)
+	mock.ExpectQuery("WITH module_stats AS .*GROUP BY m.organization_id.*FULL OUTER JOIN").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "modules", "providers", "mirrors", "bytes"}).
+			AddRow("org-1", 3, 1, 2, 4096))
+	identityMock.ExpectQuery("SELECT organization_id::text, COUNT\\(\\*\\)\\s+FROM organization_members").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "count"}).
+			AddRow("org-1", 5).
+			AddRow("org-2", 1))
+
+	stats, err := repo.ListStats(context.Background(), []string{"org-1", "org-2", "org-3"})
+	if err != nil {
+		t.Fatalf("ListStats: %v", err)
+	}
+	if s := stats["org-1"]; s.ModuleCount != 3 || s.ProviderCount != 1 || s.MirrorConfigCount != 2 || s.StorageBytes != 4096 || s.MemberCount != 5 {
+		t.Errorf("org-1 = %+v", s)
+	}
+	if s := stats["org-2"]; s.MemberCount != 1 || s.ModuleCount != 0 {
+		t.Errorf("org-2 = %+v", s)
+	}
+	if s, ok := stats["org-3"]; !ok || *s != (models.OrganizationStats{}) {
+		t.Errorf("org-3 should be present and zeroed, got %+v", s)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+	if err := identityMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet identity expectations: %v", err)
+	}
+}
+
+func TestOrganizationStats_ListStats_EmptyFilterSkipsQueries(t *testing.T) {
+	repo, mock, identityMock := newOrgStatsRepo(t)
+
+	stats, err := repo.ListStats(context.Background(), []string{})
+	if err != nil || len(stats) != 0 {
+		t.Fatalf("ListStats = %v, %v; want empty", stats, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if err := identityMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
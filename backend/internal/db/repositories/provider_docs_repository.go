@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 )
@@ -214,3 +215,53 @@ func (r *ProviderDocsRepository) CountProviderVersionDocs(ctx context.Context, v
 	}
 	return count, nil
 }
+
+// DocIndexFetchedAt returns when the doc index of a provider version was
+// stored (its oldest entry), or nil when it has no entries.
+func (r *ProviderDocsRepository) DocIndexFetchedAt(ctx context.Context, versionID string) (*time.Time, error) {
+	var fetchedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT MIN(created_at) FROM provider_version_docs WHERE provider_version_id = $1`,
+		versionID,
+	).Scan(&fetchedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider doc index fetch time: %w", err)
+	}
+	if !fetchedAt.Valid {
+		return nil, nil
+	}
+	return &fetchedAt.Time, nil
+}
+
+// GetProviderVersionDocContent returns the cached markdown of a doc entry and
+// when it was fetched. Both are nil when the page has not been fetched yet.
+func (r *ProviderDocsRepository) GetProviderVersionDocContent(ctx context.Context, docID string) (*string, *time.Time, error) {
+	var content sql.NullString
+	var fetchedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT content, content_fetched_at FROM provider_version_docs WHERE id = $1`,
+		docID,
+	).Scan(&content, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get provider version doc content: %w", err)
+	}
+	if !content.Valid || !fetchedAt.Valid {
+		return nil, nil, nil
+	}
+	return &content.String, &fetchedAt.Time, nil
+}
+
+// SetProviderVersionDocContent caches the markdown of a doc entry.
+func (r *ProviderDocsRepository) SetProviderVersionDocContent(ctx context.Context, docID, content string, fetchedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE provider_version_docs SET content = $2, content_fetched_at = $3 WHERE id = $1`,
+		docID, content, fetchedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store provider version doc content: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"testing"
+	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
@@ -412,3 +413,86 @@ func TestCountProviderVersionDocs_DBError(t *testing.T) {
 		t.Error("expected error, got nil")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// DocIndexFetchedAt / doc content cache
+// ---------------------------------------------------------------------------
+
+func TestDocIndexFetchedAt_NoEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewProviderDocsRepository(db)
+
+	mock.ExpectQuery("SELECT MIN\\(created_at\\)").
+		WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(nil))
+
+	fetchedAt, err := repo.DocIndexFetchedAt(context.Background(), "ver-1")
+	if err != nil || fetchedAt != nil {
+		t.Errorf("DocIndexFetchedAt = %v, %v; want nil, nil", fetchedAt, err)
+	}
+}
+
+func TestGetProviderVersionDocContent_Cached(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewProviderDocsRepository(db)
+
+	fetched := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectQuery("SELECT content, content_fetched_at FROM provider_version_docs").
+		WithArgs("doc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"content", "content_fetched_at"}).AddRow("# aws_instance", fetched))
+
+	content, fetchedAt, err := repo.GetProviderVersionDocContent(context.Background(), "doc-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content == nil || *content != "# aws_instance" || fetchedAt == nil || !fetchedAt.Equal(fetched) {
+		t.Errorf("got %v, %v", content, fetchedAt)
+	}
+}
+
+func TestGetProviderVersionDocContent_NotFetched(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewProviderDocsRepository(db)
+
+	mock.ExpectQuery("SELECT content, content_fetched_at FROM provider_version_docs").
+		WithArgs("doc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"content", "content_fetched_at"}).AddRow(nil, nil))
+
+	content, fetchedAt, err := repo.GetProviderVersionDocContent(context.Background(), "doc-1")
+	if err != nil || content != nil || fetchedAt != nil {
+		t.Errorf("got %v, %v, %v; want nil, nil, nil", content, fetchedAt, err)
+	}
+}
+
+func TestSetProviderVersionDocContent_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewProviderDocsRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE provider_version_docs SET content").
+		WithArgs("doc-1", "# page", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetProviderVersionDocContent(context.Background(), "doc-1", "# page", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,173 @@
+// Package repositories - provider_license_repository.go persists the license
+// gates attached to providers and the acceptances users record against them.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ProviderLicenseRepository handles provider_license_gates and
+// provider_license_acceptances database operations.
+type ProviderLicenseRepository struct {
+	db *sql.DB
+}
+
+// NewProviderLicenseRepository creates a new provider license repository.
+func NewProviderLicenseRepository(db *sql.DB) *ProviderLicenseRepository {
+	return &ProviderLicenseRepository{db: db}
+}
+
+// GetGate returns the license attached to a provider, or nil when none is.
+func (r *ProviderLicenseRepository) GetGate(ctx context.Context, providerID string) (*models.ProviderLicenseGate, error) {
+	query := `
+		SELECT provider_id, license_text, license_version, enabled, updated_by, created_at, updated_at
+		FROM provider_license_gates
+		WHERE provider_id = $1
+	`
+
+	g := &models.ProviderLicenseGate{}
+	err := r.db.QueryRowContext(ctx, query, providerID).Scan(
+		&g.ProviderID,
+		&g.LicenseText,
+		&g.LicenseVersion,
+		&g.Enabled,
+		&g.UpdatedBy,
+		&g.CreatedAt,
+		&g.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get provider license: %w", err)
+	}
+	return g, nil
+}
+
+// UpsertGate attaches g to its provider, replacing any license already
+// attached, and fills in the stored timestamps.
+func (r *ProviderLicenseRepository) UpsertGate(ctx context.Context, g *models.ProviderLicenseGate) error {
+	query := `
+		INSERT INTO provider_license_gates
+			(provider_id, license_text, license_version, enabled, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (provider_id) DO UPDATE
+			SET license_text    = EXCLUDED.license_text,
+			    license_version = EXCLUDED.license_version,
+			    enabled         = EXCLUDED.enabled,
+			    updated_by      = EXCLUDED.updated_by,
+			    updated_at      = NOW()
+		RETURNING created_at, updated_at
+	`
+	if err := r.db.QueryRowContext(ctx, query,
+		g.ProviderID, g.LicenseText, g.LicenseVersion, g.Enabled, g.UpdatedBy,
+	).Scan(&g.CreatedAt, &g.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save provider license: %w", err)
+	}
+	return nil
+}
+
+// DeleteGate removes the license attached to a provider, reporting whether
+// there was one. Recorded acceptances are kept for audit.
+func (r *ProviderLicenseRepository) DeleteGate(ctx context.Context, providerID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM provider_license_gates WHERE provider_id = $1`, providerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete provider license: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete provider license: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Accept records a's acceptance and fills in its ID and timestamp. Accepting
+// a license version again is a no-op that returns the original record, so
+// the first acceptance time is the one kept.
+func (r *ProviderLicenseRepository) Accept(ctx context.Context, a *models.ProviderLicenseAcceptance) error {
+	query := `
+		INSERT INTO provider_license_acceptances
+			(provider_id, user_id, license_version, ip_address)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider_id, user_id, license_version) DO UPDATE
+			SET license_version = EXCLUDED.license_version
+		RETURNING id, ip_address, accepted_at
+	`
+	if err := r.db.QueryRowContext(ctx, query,
+		a.ProviderID, a.UserID, a.LicenseVersion, a.IPAddress,
+	).Scan(&a.ID, &a.IPAddress, &a.AcceptedAt); err != nil {
+		return fmt.Errorf("failed to record license acceptance: %w", err)
+	}
+	return nil
+}
+
+// GetAcceptance returns a user's acceptance of one version of a provider's
+// license, or nil when they have not accepted it.
+func (r *ProviderLicenseRepository) GetAcceptance(ctx context.Context, providerID, userID, licenseVersion string) (*models.ProviderLicenseAcceptance, error) {
+	query := `
+		SELECT id, provider_id, user_id, license_version, ip_address, accepted_at
+		FROM provider_license_acceptances
+		WHERE provider_id = $1 AND user_id = $2 AND license_version = $3
+	`
+
+	a := &models.ProviderLicenseAcceptance{}
+	err := r.db.QueryRowContext(ctx, query, providerID, userID, licenseVersion).Scan(
+		&a.ID,
+		&a.ProviderID,
+		&a.UserID,
+		&a.LicenseVersion,
+		&a.IPAddress,
+		&a.AcceptedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get license acceptance: %w", err)
+	}
+	return a, nil
+}
+
+// ListAcceptances returns every acceptance recorded for a provider, of any
+// license version, oldest first, with the accepting user's email and name.
+func (r *ProviderLicenseRepository) ListAcceptances(ctx context.Context, providerID string) ([]models.ProviderLicenseAcceptance, error) {
+	query := `
+		SELECT a.id, a.provider_id, a.user_id, a.license_version, a.ip_address, a.accepted_at,
+		       u.email, u.name
+		FROM provider_license_acceptances a
+		LEFT JOIN users u ON a.user_id = u.id
+		WHERE a.provider_id = $1
+		ORDER BY a.accepted_at, a.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list license acceptances: %w", err)
+	}
+	defer rows.Close()
+
+	acceptances := []models.ProviderLicenseAcceptance{}
+	for rows.Next() {
+		var a models.ProviderLicenseAcceptance
+		if err := rows.Scan(
+			&a.ID,
+			&a.ProviderID,
+			&a.UserID,
+			&a.LicenseVersion,
+			&a.IPAddress,
+			&a.AcceptedAt,
+			&a.UserEmail,
+			&a.UserName,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan license acceptance: %w", err)
+		}
+		acceptances = append(acceptances, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list license acceptances: %w", err)
+	}
+	return acceptances, nil
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var providerLicenseGateCols = []string{
+	"provider_id", "license_text", "license_version", "enabled", "updated_by", "created_at", "updated_at",
+}
+
+func newProviderLicenseRepo(t *testing.T) (*ProviderLicenseRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewProviderLicenseRepository(db), mock
+}
+
+func TestProviderLicense_GetGate(t *testing.T) {
+	repo, mock := newProviderLicenseRepo(t)
+	mock.ExpectQuery("SELECT.*FROM provider_license_gates").
+		WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows(providerLicenseGateCols).
+			AddRow("prov-1", "terms", "2026-01", true, nil, time.Now(), time.Now()))
+
+	g, err := repo.GetGate(context.Background(), "prov-1")
+	if err != nil {
+		t.Fatalf("GetGate: %v", err)
+	}
+	if g == nil || !g.Enabled || g.LicenseVersion != "2026-01" {
+		t.Errorf("gate = %+v", g)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM provider_license_gates").
+		WithArgs("prov-2").
+		WillReturnRows(sqlmock.NewRows(providerLicenseGateCols))
+	if g, err := repo.GetGate(context.Background(), "prov-2"); g != nil || err != nil {
+		t.Errorf("GetGate = %+v, %v; want nil, nil", g, err)
+	}
+}
+
+func TestProviderLicense_UpsertGate(t *testing.T) {
+	repo, mock := newProviderLicenseRepo(t)
+	uid := "admin-1"
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO provider_license_gates.*ON CONFLICT \\(provider_id\\) DO UPDATE").
+		WithArgs("prov-1", "terms", "2026-02", true, &uid).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+
+	g := &models.ProviderLicenseGate{ProviderID: "prov-1", LicenseText: "terms", LicenseVersion: "2026-02", Enabled: true, UpdatedBy: &uid}
+	if err := repo.UpsertGate(context.Background(), g); err != nil {
+		t.Fatalf("UpsertGate: %v", err)
+	}
+	if !g.UpdatedAt.Equal(now) {
+		t.Errorf("UpdatedAt = %v, want %v", g.UpdatedAt, now)
+	}
+}
+
+func TestProviderLicense_DeleteGate(t *testing.T) {
+	repo, mock := newProviderLicenseRepo(t)
+	mock.ExpectExec("DELETE FROM provider_license_gates").
+		WithArgs("prov-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM provider_license_gates").
+		WithArgs("prov-2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if deleted, err := repo.DeleteGate(context.Background(), "prov-1"); !deleted || err != nil {
+		t.Errorf("DeleteGate(prov-1) = %v, %v; want true, nil", deleted, err)
+	}
+	if deleted, err := repo.DeleteGate(context.Background(), "prov-2"); deleted || err != nil {
+		t.Errorf("DeleteGate(prov-2) = %v, %v; want false, nil", deleted, err)
+	}
+}
+
+func TestProviderLicense_Accept(t *testing.T) {
+	repo, mock := newProviderLicenseRepo(t)
+	ip := "192.0.2.1"
+	firstAccepted := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	// A repeated acceptance returns the original row.
+	mock.ExpectQuery("INSERT INTO provider_license_acceptances.*ON CONFLICT \\(provider_id, user_id, license_version\\)").
+		WithArgs("prov-1", "user-1", "2026-01", &ip).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ip_address", "accepted_at"}).AddRow("acc-1", "198.51.100.7", firstAccepted))
+
+	a := &models.ProviderLicenseAcceptance{ProviderID: "prov-1", UserID: "user-1", LicenseVersion: "2026-01", IPAddress: &ip}
+	if err := repo.Accept(context.Background(), a); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if a.ID != "acc-1" || !a.AcceptedAt.Equal(firstAccepted) || *a.IPAddress != "198.51.100.7" {
+		t.Errorf("acceptance = %+v, want the original record", a)
+	}
+}
+
+func TestProviderLicense_GetAcceptanceNotFound(t *testing.T) {
+	repo, mock := newProviderLicenseRepo(t)
+	mock.ExpectQuery("SELECT.*FROM provider_license_acceptances").
+		WithArgs("prov-1", "user-1", "2026-02").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "provider_id", "user_id", "license_version", "ip_address", "accepted_at"}))
+
+	if a, err := repo.GetAcceptance(context.Background(), "prov-1", "user-1", "2026-02"); a != nil || err != nil {
+		t.Errorf("GetAcceptance = %+v, %v; want nil, nil", a, err)
+	}
+}
+
+func TestProviderLicense_ListAcceptances(t *testing.T) {
+	repo, mock := newProviderLicenseRepo(t)
+	email := "dev@example.com"
+	mock.ExpectQuery("SELECT.*FROM provider_license_acceptances a\\s+LEFT JOIN users u").
+		WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "provider_id", "user_id", "license_version", "ip_address", "accepted_at", "email", "name"}).
+			AddRow("acc-1", "prov-1", "user-1", "2026-01", nil, time.Now(), &email, nil).
+			AddRow("acc-2", "prov-1", "user-1", "2026-02", nil, time.Now(), &email, nil))
+
+	list, err := repo.ListAcceptances(context.Background(), "prov-1")
+	if err != nil {
+		t.Fatalf("ListAcceptances: %v", err)
+	}
+	if len(list) != 2 || list[1].LicenseVersion != "2026-02" || *list[0].UserEmail != email {
+		t.Errorf("acceptances = %+v", list)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM provider_license_acceptances").
+		WillReturnError(errors.New("connection reset"))
+	if _, err := repo.ListAcceptances(context.Background(), "prov-1"); err == nil {
+		t.Error("expected the query error")
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 )
@@ -24,11 +27,12 @@ func NewProviderRepository(db *sql.DB) *ProviderRepository {
 	return &ProviderRepository{db: db}
 }
 
-// CreateProvider inserts a new provider record
+// CreateProvider inserts a new provider record. An empty Tier is stored as
+// community.
 func (r *ProviderRepository) CreateProvider(ctx context.Context, provider *models.Provider) error {
 	query := `
-		INSERT INTO providers (organization_id, namespace, type, description, source, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO providers (organization_id, namespace, type, description, source, created_by, tier)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), 'community'))
 		RETURNING id, created_at, updated_at
 	`
 
@@ -47,6 +51,7 @@ func (r *ProviderRepository) CreateProvider(ctx context.Context, provider *model
 		provider.Description,
 		provider.Source,
 		provider.CreatedBy,
+		provider.Tier,
 	).Scan(&provider.ID, &provider.CreatedAt, &provider.UpdatedAt)
 
 	if err != nil {
@@ -211,6 +216,158 @@ func (r *ProviderRepository) UpdateProvider(ctx context.Context, provider *model
 	return nil
 }
 
+// GetUpstreamMetadata returns the mirror-sync metadata stored on a provider's
+// row, including the license text. It returns nil when the provider does not
+// exist or has never had metadata recorded.
+func (r *ProviderRepository) GetUpstreamMetadata(ctx context.Context, providerID string) (*models.ProviderUpstreamMetadata, error) {
+	query := `
+		SELECT source_url, upstream_tier, license, license_file, license_text,
+		       upstream_metadata_synced_at, license_checked_at
+		FROM providers
+		WHERE id = $1
+		  AND (upstream_metadata_synced_at IS NOT NULL OR license_checked_at IS NOT NULL)
+	`
+
+	meta := &models.ProviderUpstreamMetadata{}
+	err := r.db.QueryRowContext(ctx, query, providerID).Scan(
+		&meta.SourceURL, &meta.UpstreamTier, &meta.License, &meta.LicenseFile, &meta.LicenseText,
+		&meta.SyncedAt, &meta.LicenseCheckedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider upstream metadata: %w", err)
+	}
+	return meta, nil
+}
+
+// ListUpstreamMetadata returns the mirror-sync metadata for the given
+// providers keyed by provider ID, without the license text. Providers that
+// never had metadata recorded are absent from the map.
+func (r *ProviderRepository) ListUpstreamMetadata(ctx context.Context, providerIDs []string) (map[string]*models.ProviderUpstreamMetadata, error) {
+	result := make(map[string]*models.ProviderUpstreamMetadata)
+	if len(providerIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT id, source_url, upstream_tier, license, license_file,
+		       upstream_metadata_synced_at, license_checked_at
+		FROM providers
+		WHERE id::text = ANY($1)
+		  AND (upstream_metadata_synced_at IS NOT NULL OR license_checked_at IS NOT NULL)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(providerIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider upstream metadata: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		meta := &models.ProviderUpstreamMetadata{}
+		if err := rows.Scan(&id, &meta.SourceURL, &meta.UpstreamTier, &meta.License, &meta.LicenseFile, &meta.SyncedAt, &meta.LicenseCheckedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan provider upstream metadata: %w", err)
+		}
+		result[id] = meta
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate provider upstream metadata: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateUpstreamMetadata records the upstream description, source repository
+// URL and upstream tier for a mirrored provider. A nil description keeps the
+// existing one; nil sourceURL/upstreamTier are stored as NULL.
+func (r *ProviderRepository) UpdateUpstreamMetadata(ctx context.Context, providerID string, description, sourceURL, upstreamTier *string) error {
+	query := `
+		UPDATE providers
+		SET description = COALESCE($2, description),
+		    source_url = $3,
+		    upstream_tier = $4,
+		    upstream_metadata_synced_at = NOW(),
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, providerID, description, sourceURL, upstreamTier); err != nil {
+		return fmt.Errorf("failed to update provider upstream metadata: %w", err)
+	}
+	return nil
+}
+
+// ListTiers returns the registry-assigned tier of the given providers keyed by
+// provider ID. Providers that do not exist are absent from the map.
+func (r *ProviderRepository) ListTiers(ctx context.Context, providerIDs []string) (map[string]string, error) {
+	result := make(map[string]string)
+	if len(providerIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, tier FROM providers WHERE id::text = ANY($1)`, pq.Array(providerIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider tiers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, tier string
+		if err := rows.Scan(&id, &tier); err != nil {
+			return nil, fmt.Errorf("failed to scan provider tier: %w", err)
+		}
+		result[id] = tier
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate provider tiers: %w", err)
+	}
+	return result, nil
+}
+
+// SetTier sets a provider's registry-assigned tier and returns the tier it
+// replaced. It returns an empty previous tier when the provider does not exist.
+func (r *ProviderRepository) SetTier(ctx context.Context, providerID, tier string) (string, error) {
+	query := `
+		UPDATE providers p
+		SET tier = $2, updated_at = NOW()
+		FROM (SELECT id, tier FROM providers WHERE id = $1 FOR UPDATE) old
+		WHERE p.id = old.id
+		RETURNING old.tier
+	`
+
+	var previous string
+	err := r.db.QueryRowContext(ctx, query, providerID, tier).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to set provider tier: %w", err)
+	}
+	return previous, nil
+}
+
+// UpdateLicense records the result of examining a mirrored provider's archive
+// for a license file. Nil values (no license file found) are stored as NULL;
+// license_checked_at is set either way so the archive is not re-read.
+func (r *ProviderRepository) UpdateLicense(ctx context.Context, providerID string, license, licenseFile, licenseText *string) error {
+	query := `
+		UPDATE providers
+		SET license = $2,
+		    license_file = $3,
+		    license_text = $4,
+		    license_checked_at = NOW(),
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, providerID, license, licenseFile, licenseText); err != nil {
+		return fmt.Errorf("failed to update provider license: %w", err)
+	}
+	return nil
+}
+
 // DeleteProvider deletes a provider and all its versions/platforms (cascade)
 func (r *ProviderRepository) DeleteProvider(ctx context.Context, providerID string) error {
 	query := `DELETE FROM providers WHERE id = $1`
@@ -426,6 +583,51 @@ func (r *ProviderRepository) listVersions(ctx context.Context, providerID string
 	return versions, nil
 }
 
+// ListLatestVisibleVersions returns the highest visible version of each of
+// the given providers keyed by provider ID, in one query. Visibility is that
+// of ListVisibleVersions; providers without a visible version are absent from
+// the map. Publisher and checksum fields are not loaded.
+func (r *ProviderRepository) ListLatestVisibleVersions(ctx context.Context, providerIDs []string) (map[string]*models.ProviderVersion, error) {
+	result := make(map[string]*models.ProviderVersion)
+	if len(providerIDs) == 0 {
+		return result, nil
+	}
+
+	// #nosec G201 -- only the constant ordering and exclusion clauses are interpolated
+	query := fmt.Sprintf(`
+		SELECT DISTINCT ON (pv.provider_id)
+		       pv.id, pv.provider_id, pv.version, pv.protocols, pv.gpg_public_key,
+		       COALESCE(pv.deprecated, false), pv.deprecated_at, pv.deprecation_message, pv.created_at
+		FROM provider_versions pv
+		WHERE pv.provider_id::text = ANY($1) %s
+		ORDER BY pv.provider_id, %s,
+		         (CASE WHEN REGEXP_REPLACE(version, '^v', '') !~ '-' THEN 1 ELSE 0 END) DESC
+	`, approvalExclusionClause, semverOrder)
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(providerIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list latest provider versions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v := &models.ProviderVersion{}
+		var protocolsJSON []byte
+		if err := rows.Scan(&v.ID, &v.ProviderID, &v.Version, &protocolsJSON, &v.GPGPublicKey,
+			&v.Deprecated, &v.DeprecatedAt, &v.DeprecationMessage, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan provider version: %w", err)
+		}
+		if err := json.Unmarshal(protocolsJSON, &v.Protocols); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal protocols: %w", err)
+		}
+		result[v.ProviderID] = v
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating latest provider versions: %w", err)
+	}
+	return result, nil
+}
+
 // ListVersionsPaginated retrieves versions for a provider with limit/offset pagination and total count.
 func (r *ProviderRepository) ListVersionsPaginated(ctx context.Context, providerID string, limit, offset int) ([]*models.ProviderVersion, int, error) {
 	// The public protocol view hides mirrored versions pending or rejected
@@ -498,6 +700,66 @@ func (r *ProviderRepository) ListVersionsPaginated(ctx context.Context, provider
 	return versions, total, nil
 }
 
+// ListVersionsFiltered returns one page of a provider's versions matching f,
+// regardless of approval status, plus the number of versions matching f.
+func (r *ProviderRepository) ListVersionsFiltered(ctx context.Context, providerID string, f VersionListFilter) ([]*models.ProviderVersion, int, error) {
+	conds, args := f.sqlConditions("pv", []interface{}{providerID})
+
+	query := `
+		SELECT pv.id, pv.provider_id, pv.version, pv.protocols, pv.gpg_public_key,
+		       pv.shasums_url, pv.shasums_signature_url,
+		       pv.shasum_storage_key, pv.shasum_signature_storage_key,
+		       pv.published_by, u.name as published_by_name,
+		       COALESCE(pv.deprecated, false), pv.deprecated_at, pv.deprecation_message, pv.created_at
+		FROM provider_versions pv
+		LEFT JOIN users u ON pv.published_by = u.id
+		WHERE pv.provider_id = $1` + conds
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list provider versions: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*models.ProviderVersion
+	for rows.Next() {
+		v := &models.ProviderVersion{}
+		var protocolsJSON []byte
+		err := rows.Scan(
+			&v.ID,
+			&v.ProviderID,
+			&v.Version,
+			&protocolsJSON,
+			&v.GPGPublicKey,
+			&v.ShasumURL,
+			&v.ShasumSignatureURL,
+			&v.ShasumStorageKey,
+			&v.ShasumSignatureStorageKey,
+			&v.PublishedBy,
+			&v.PublishedByName,
+			&v.Deprecated,
+			&v.DeprecatedAt,
+			&v.DeprecationMessage,
+			&v.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan provider version: %w", err)
+		}
+		if err := json.Unmarshal(protocolsJSON, &v.Protocols); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal protocols: %w", err)
+		}
+		candidates = append(candidates, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating provider versions: %w", err)
+	}
+
+	versions, total := pageVersions(candidates, f,
+		func(v *models.ProviderVersion) string { return v.Version },
+		func(v *models.ProviderVersion) time.Time { return v.CreatedAt })
+	return versions, total, nil
+}
+
 // DeleteVersion deletes a specific provider version and all its platforms (cascade)
 func (r *ProviderRepository) DeleteVersion(ctx context.Context, versionID string) error {
 	query := `DELETE FROM provider_versions WHERE id = $1`
@@ -601,8 +863,9 @@ func (r *ProviderRepository) UndeprecateVersion(ctx context.Context, versionID s
 // CreatePlatform inserts a new platform binary record
 func (r *ProviderRepository) CreatePlatform(ctx context.Context, platform *models.ProviderPlatform) error {
 	query := `
-		INSERT INTO provider_platforms (provider_version_id, os, arch, filename, storage_path, storage_backend, size_bytes, shasum, h1_hash)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO provider_platforms (provider_version_id, os, arch, filename, storage_path, storage_backend, size_bytes, shasum, h1_hash,
+			provenance, upstream_registry, upstream_download_url, upstream_shasum_url, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`
 
@@ -616,6 +879,11 @@ func (r *ProviderRepository) CreatePlatform(ctx context.Context, platform *model
 		platform.SizeBytes,
 		platform.Shasum,
 		platform.H1Hash,
+		platform.Upstream.Provenance,
+		platform.Upstream.UpstreamRegistry,
+		platform.Upstream.UpstreamDownloadURL,
+		platform.Upstream.UpstreamSHASumURL,
+		platform.Upstream.FetchedAt,
 	).Scan(&platform.ID)
 
 	if err != nil {
@@ -702,6 +970,68 @@ func (r *ProviderRepository) ListPlatforms(ctx context.Context, versionID string
 	return platforms, nil
 }
 
+// ListPlatformsByVersions returns the platform binaries of the given
+// provider versions keyed by version ID, each list ordered by os and arch, in
+// one query.
+func (r *ProviderRepository) ListPlatformsByVersions(ctx context.Context, versionIDs []string) (map[string][]*models.ProviderPlatform, error) {
+	result := make(map[string][]*models.ProviderPlatform)
+	if len(versionIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT id, provider_version_id, os, arch, filename, storage_path, storage_backend, size_bytes, shasum, h1_hash, download_count
+		FROM provider_platforms
+		WHERE provider_version_id::text = ANY($1)
+		ORDER BY provider_version_id, os, arch
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(versionIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider platforms: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &models.ProviderPlatform{}
+		if err := rows.Scan(&p.ID, &p.ProviderVersionID, &p.OS, &p.Arch, &p.Filename, &p.StoragePath,
+			&p.StorageBackend, &p.SizeBytes, &p.Shasum, &p.H1Hash, &p.DownloadCount); err != nil {
+			return nil, fmt.Errorf("failed to scan provider platform: %w", err)
+		}
+		result[p.ProviderVersionID] = append(result[p.ProviderVersionID], p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating provider platforms: %w", err)
+	}
+	return result, nil
+}
+
+// ListPlatformProvenance returns the upstream provenance of a version's
+// platforms keyed by platform ID. Platforms that were uploaded rather than
+// mirrored are left out.
+func (r *ProviderRepository) ListPlatformProvenance(ctx context.Context, versionID string) (map[string]models.UpstreamProvenance, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, provenance, upstream_registry, upstream_download_url, upstream_shasum_url, fetched_at
+		FROM provider_platforms
+		WHERE provider_version_id = $1 AND provenance IS NOT NULL
+	`, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list platform provenance: %w", err)
+	}
+	defer rows.Close()
+
+	provenance := make(map[string]models.UpstreamProvenance)
+	for rows.Next() {
+		var id string
+		var p models.UpstreamProvenance
+		if err := rows.Scan(&id, &p.Provenance, &p.UpstreamRegistry, &p.UpstreamDownloadURL, &p.UpstreamSHASumURL, &p.FetchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan platform provenance: %w", err)
+		}
+		provenance[id] = p
+	}
+	return provenance, rows.Err()
+}
+
 // IncrementDownloadCount increments the download counter for a platform
 func (r *ProviderRepository) IncrementDownloadCount(ctx context.Context, platformID string) error {
 	query := `
@@ -849,8 +1179,10 @@ var allowedProviderSortFields = map[string]bool{
 // N+1 query pattern from SearchProviders + per-provider ListVersions/GetTotalDownloadCount.
 // sortField controls result ordering: "relevance" (FTS rank), "name", "downloads",
 // "created", "updated", or "" (default: relevance when FTS is used, else created_at).
-// sortOrder is "asc" or "desc" (default "desc").
-func (r *ProviderRepository) SearchProvidersWithStats(ctx context.Context, orgID, searchQuery, namespace string, limit, offset int, sortField, sortOrder string) ([]*models.ProviderSearchResult, int, error) {
+// sortOrder is "asc" or "desc" (default "desc"). A non-empty ownerOrgID limits
+// the results to the namespaces that organization has claimed; a non-empty
+// tier to providers of that registry-assigned tier.
+func (r *ProviderRepository) SearchProvidersWithStats(ctx context.Context, orgID, ownerOrgID, searchQuery, namespace, tier string, limit, offset int, sortField, sortOrder string) ([]*models.ProviderSearchResult, int, error) {
 	// Validate and normalise sort parameters.
 	if !allowedProviderSortFields[sortField] {
 		sortField = ""
@@ -872,6 +1204,9 @@ func (r *ProviderRepository) SearchProvidersWithStats(ctx context.Context, orgID
 	if orgID != "" {
 		wb.add("p.organization_id = $%d", orgID)
 	}
+	if ownerOrgID != "" {
+		wb.add("p.namespace IN (SELECT namespace FROM namespace_claims WHERE organization_id = $%d)", ownerOrgID)
+	}
 	if searchQuery != "" {
 		searchArgIdx = wb.nextPlaceholder()
 		if useFTS {
@@ -883,6 +1218,9 @@ func (r *ProviderRepository) SearchProvidersWithStats(ctx context.Context, orgID
 	if namespace != "" {
 		wb.add("p.namespace = $%d", namespace)
 	}
+	if tier != "" {
+		wb.add("p.tier = $%d", tier)
+	}
 	whereClause, args := wb.clause()
 
 	// Count total results
@@ -1066,6 +1404,154 @@ func (r *ProviderRepository) ListProviderVersionShasums(ctx context.Context, ver
 	return result, nil
 }
 
+// UpsertVersionResignature records (or replaces) the registry's signature for
+// a provider version.
+func (r *ProviderRepository) UpsertVersionResignature(ctx context.Context, rs *models.ProviderVersionResignature) error {
+	query := `
+		INSERT INTO provider_version_resignatures
+			(provider_version_id, key_id, gpg_public_key, shasum_storage_key, shasum_signature_storage_key, signed_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (provider_version_id) DO UPDATE SET
+			key_id                       = EXCLUDED.key_id,
+			gpg_public_key               = EXCLUDED.gpg_public_key,
+			shasum_storage_key           = EXCLUDED.shasum_storage_key,
+			shasum_signature_storage_key = EXCLUDED.shasum_signature_storage_key,
+			signed_at                    = EXCLUDED.signed_at
+		RETURNING signed_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		rs.ProviderVersionID, rs.KeyID, rs.GPGPublicKey, rs.ShasumStorageKey, rs.ShasumSignatureStorageKey,
+	).Scan(&rs.SignedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert provider version resignature: %w", err)
+	}
+	return nil
+}
+
+// GetVersionResignature returns the registry's signature for a provider
+// version, or nil when the version has not been re-signed.
+func (r *ProviderRepository) GetVersionResignature(ctx context.Context, versionID string) (*models.ProviderVersionResignature, error) {
+	query := `
+		SELECT provider_version_id, key_id, gpg_public_key, shasum_storage_key, shasum_signature_storage_key, signed_at
+		FROM provider_version_resignatures
+		WHERE provider_version_id = $1
+	`
+	rs := &models.ProviderVersionResignature{}
+	err := r.db.QueryRowContext(ctx, query, versionID).Scan(
+		&rs.ProviderVersionID, &rs.KeyID, &rs.GPGPublicKey,
+		&rs.ShasumStorageKey, &rs.ShasumSignatureStorageKey, &rs.SignedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider version resignature: %w", err)
+	}
+	return rs, nil
+}
+
+// ListVersionResignatures returns the registry's signatures of the given
+// provider versions keyed by version ID. Versions that have not been re-signed
+// are absent from the map.
+func (r *ProviderRepository) ListVersionResignatures(ctx context.Context, versionIDs []string) (map[string]*models.ProviderVersionResignature, error) {
+	result := make(map[string]*models.ProviderVersionResignature)
+	if len(versionIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT provider_version_id, key_id, gpg_public_key, shasum_storage_key, shasum_signature_storage_key, signed_at
+		FROM provider_version_resignatures
+		WHERE provider_version_id::text = ANY($1)
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(versionIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider version resignatures: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rs := &models.ProviderVersionResignature{}
+		if err := rows.Scan(&rs.ProviderVersionID, &rs.KeyID, &rs.GPGPublicKey,
+			&rs.ShasumStorageKey, &rs.ShasumSignatureStorageKey, &rs.SignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan provider version resignature: %w", err)
+		}
+		result[rs.ProviderVersionID] = rs
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating provider version resignatures: %w", err)
+	}
+	return result, nil
+}
+
+// AddDownloadStats adds a batch of daily download counts to
+// provider_download_stats, creating rows on first use. It implements
+// downloadstats.Store.
+func (r *ProviderRepository) AddDownloadStats(ctx context.Context, counts []models.ProviderDownloadCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO provider_download_stats (provider_id, version, os, arch, day, downloads)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (provider_id, version, os, arch, day)
+		DO UPDATE SET downloads = provider_download_stats.downloads + EXCLUDED.downloads
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare download stats statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, c := range counts {
+		if _, err := stmt.ExecContext(ctx, c.ProviderID, c.Version, c.OS, c.Arch, c.Day, c.Downloads); err != nil {
+			return fmt.Errorf("failed to add download stats for %s %s_%s: %w", c.Version, c.OS, c.Arch, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit download stats transaction: %w", err)
+	}
+	return nil
+}
+
+// GetDownloadStats returns a provider's download counts summed per version and
+// platform over the days on or after since (all recorded days when since is
+// nil). Day is left zero on the returned rows.
+func (r *ProviderRepository) GetDownloadStats(ctx context.Context, providerID string, since *time.Time) ([]models.ProviderDownloadCount, error) {
+	query := `
+		SELECT version, os, arch, SUM(downloads)
+		FROM provider_download_stats
+		WHERE provider_id = $1 AND ($2::date IS NULL OR day >= $2::date)
+		GROUP BY version, os, arch
+		ORDER BY version, os, arch
+	`
+	rows, err := r.db.QueryContext(ctx, query, providerID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider download stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []models.ProviderDownloadCount
+	for rows.Next() {
+		s := models.ProviderDownloadCount{ProviderID: providerID}
+		if err := rows.Scan(&s.Version, &s.OS, &s.Arch, &s.Downloads); err != nil {
+			return nil, fmt.Errorf("failed to scan provider download stats: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating provider download stats: %w", err)
+	}
+	return stats, nil
+}
+
 // compareSemver compares two semver strings
 // Returns: -1 if a < b, 0 if a == b, 1 if a > b
 func compareSemver(a, b string) int {
@@ -1103,7 +1589,8 @@ func parseSemverParts(version string) [3]int {
 }
 
 // UpsertProvider creates or returns an existing provider record for pull-through caching.
-// If the provider already exists (matched by org, namespace, type) it is returned as-is.
+// If the provider already exists (matched by org, namespace, type) it is returned as-is;
+// a new one is created in the mirrored tier.
 func (r *ProviderRepository) UpsertProvider(ctx context.Context, orgID, namespace, providerType string) (*models.Provider, error) {
 	existing, err := r.GetProvider(ctx, orgID, namespace, providerType)
 	if err != nil {
@@ -1119,6 +1606,7 @@ func (r *ProviderRepository) UpsertProvider(ctx context.Context, orgID, namespac
 		Namespace:      namespace,
 		Type:           providerType,
 		Description:    &description,
+		Tier:           models.TierMirrored,
 	}
 	if err := r.CreateProvider(ctx, p); err != nil {
 		return nil, fmt.Errorf("upsert provider: create: %w", err)
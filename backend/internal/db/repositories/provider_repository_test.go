@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
@@ -423,6 +425,34 @@ func TestGetPlatform_NotFound(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ListPlatformProvenance
+// ---------------------------------------------------------------------------
+
+func TestListPlatformProvenance(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	fetched := time.Now()
+	mock.ExpectQuery("SELECT.*FROM provider_platforms.*WHERE provider_version_id.*AND provenance IS NOT NULL").
+		WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "provenance", "upstream_registry", "upstream_download_url", "upstream_shasum_url", "fetched_at"}).
+			AddRow("plat-1", "recorded", "registry.terraform.io", "https://releases.example.com/p.zip", "https://releases.example.com/SHA256SUMS", fetched).
+			AddRow("plat-2", "unknown", nil, nil, nil, nil))
+
+	got, err := repo.ListPlatformProvenance(context.Background(), "ver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2", len(got))
+	}
+	if p := got["plat-1"]; p.UpstreamRegistry == nil || *p.UpstreamRegistry != "registry.terraform.io" {
+		t.Errorf("plat-1 registry = %v, want registry.terraform.io", p.UpstreamRegistry)
+	}
+	if p := got["plat-2"]; p.Provenance == nil || *p.Provenance != models.ProvenanceUnknown || p.UpstreamDownloadURL != nil {
+		t.Errorf("plat-2 = %+v, want unknown provenance without URLs", p)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // IncrementDownloadCount / GetTotalDownloadCount / DeletePlatform
 // ---------------------------------------------------------------------------
@@ -722,7 +752,7 @@ func TestSearchProvidersWithStats_Success(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM providers.*LEFT JOIN LATERAL").
 		WillReturnRows(sampleProviderSearchWithStatsRowFTS())
 
-	results, total, err := repo.SearchProvidersWithStats(context.Background(), "org-1", "aws", "hashicorp", 10, 0, "", "")
+	results, total, err := repo.SearchProvidersWithStats(context.Background(), "org-1", "", "aws", "hashicorp", "", 10, 0, "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -753,7 +783,7 @@ func TestSearchProvidersWithStats_Empty(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM providers.*LEFT JOIN LATERAL").
 		WillReturnRows(sqlmock.NewRows(providerSearchWithStatsCols))
 
-	results, total, err := repo.SearchProvidersWithStats(context.Background(), "", "", "", 10, 0, "", "")
+	results, total, err := repo.SearchProvidersWithStats(context.Background(), "", "", "", "", "", 10, 0, "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -767,7 +797,7 @@ func TestSearchProvidersWithStats_CountError(t *testing.T) {
 	mock.ExpectQuery("SELECT COUNT").
 		WillReturnError(errDB)
 
-	_, _, err := repo.SearchProvidersWithStats(context.Background(), "", "aws", "", 10, 0, "", "")
+	_, _, err := repo.SearchProvidersWithStats(context.Background(), "", "", "aws", "", "", 10, 0, "", "")
 	if err == nil {
 		t.Error("expected error on count query failure")
 	}
@@ -780,7 +810,7 @@ func TestSearchProvidersWithStats_QueryError(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM providers.*LEFT JOIN LATERAL").
 		WillReturnError(errDB)
 
-	_, _, err := repo.SearchProvidersWithStats(context.Background(), "", "aws", "", 10, 0, "", "")
+	_, _, err := repo.SearchProvidersWithStats(context.Background(), "", "", "aws", "", "", 10, 0, "", "")
 	if err == nil {
 		t.Error("expected error on search query failure")
 	}
@@ -793,7 +823,7 @@ func TestSearchProvidersWithStats_NoOrgFilter(t *testing.T) {
 	mock.ExpectQuery("SELECT.*FROM providers.*LEFT JOIN LATERAL").
 		WillReturnRows(sampleProviderSearchWithStatsRowFTS())
 
-	results, total, err := repo.SearchProvidersWithStats(context.Background(), "", "aws", "", 10, 0, "", "")
+	results, total, err := repo.SearchProvidersWithStats(context.Background(), "", "", "aws", "", "", 10, 0, "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -802,6 +832,23 @@ func TestSearchProvidersWithStats_NoOrgFilter(t *testing.T) {
 	}
 }
 
+func TestSearchProvidersWithStats_OwnerOrgScope(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM providers p WHERE p.namespace IN \(SELECT namespace FROM namespace_claims WHERE organization_id = \$1\)`).
+		WithArgs("org-blue").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT.*FROM providers.*LEFT JOIN LATERAL").
+		WillReturnRows(sqlmock.NewRows(providerSearchWithStatsCols).
+			AddRow("prov-1", "org-1", "acme", "widget", nil, nil, nil, nil, time.Now(), time.Now(), "1.0.0", int64(3)))
+
+	if _, total, err := repo.SearchProvidersWithStats(context.Background(), "", "org-blue", "", "", "", 10, 0, "", ""); err != nil || total != 1 {
+		t.Fatalf("total = %d, err = %v", total, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 func TestSearchProvidersWithStats_NullLatestVersion(t *testing.T) {
 	repo, mock := newProviderRepo(t)
 	mock.ExpectQuery("SELECT COUNT").
@@ -810,7 +857,7 @@ func TestSearchProvidersWithStats_NullLatestVersion(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows(providerSearchWithStatsCols).
 			AddRow("prov-2", nil, "hashicorp", "gcp", nil, nil, nil, nil, time.Now(), time.Now(), nil, int64(0)))
 
-	results, total, err := repo.SearchProvidersWithStats(context.Background(), "", "", "", 10, 0, "", "")
+	results, total, err := repo.SearchProvidersWithStats(context.Background(), "", "", "", "", "", 10, 0, "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1380,6 +1427,116 @@ func TestListProviderVersionShasums_DBError(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Version resignatures
+// ---------------------------------------------------------------------------
+
+var resignatureCols = []string{"provider_version_id", "key_id", "gpg_public_key", "shasum_storage_key", "shasum_signature_storage_key", "signed_at"}
+
+func TestUpsertVersionResignature(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	signedAt := time.Now()
+	mock.ExpectQuery("INSERT INTO provider_version_resignatures.*ON CONFLICT \\(provider_version_id\\) DO UPDATE").
+		WithArgs("ver-1", "ABCDEF0123456789", "KEY", "sums", "sums.sig").
+		WillReturnRows(sqlmock.NewRows([]string{"signed_at"}).AddRow(signedAt))
+
+	rs := &models.ProviderVersionResignature{
+		ProviderVersionID: "ver-1", KeyID: "ABCDEF0123456789", GPGPublicKey: "KEY",
+		ShasumStorageKey: "sums", ShasumSignatureStorageKey: "sums.sig",
+	}
+	if err := repo.UpsertVersionResignature(context.Background(), rs); err != nil {
+		t.Fatalf("UpsertVersionResignature: %v", err)
+	}
+	if !rs.SignedAt.Equal(signedAt) {
+		t.Errorf("SignedAt = %v, want %v", rs.SignedAt, signedAt)
+	}
+}
+
+func TestGetVersionResignature_Found(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery("SELECT.*FROM provider_version_resignatures").
+		WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows(resignatureCols).
+			AddRow("ver-1", "ABCDEF0123456789", "KEY", "sums", "sums.sig", time.Now()))
+
+	rs, err := repo.GetVersionResignature(context.Background(), "ver-1")
+	if err != nil {
+		t.Fatalf("GetVersionResignature: %v", err)
+	}
+	if rs == nil || rs.KeyID != "ABCDEF0123456789" || rs.ShasumSignatureStorageKey != "sums.sig" {
+		t.Errorf("rs = %+v", rs)
+	}
+}
+
+func TestGetVersionResignature_NotSigned(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery("SELECT.*FROM provider_version_resignatures").WillReturnError(sql.ErrNoRows)
+
+	rs, err := repo.GetVersionResignature(context.Background(), "ver-1")
+	if err != nil || rs != nil {
+		t.Fatalf("got (%+v, %v), want (nil, nil)", rs, err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// AddDownloadStats / GetDownloadStats
+// ---------------------------------------------------------------------------
+
+func TestAddDownloadStats(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO provider_download_stats")
+	mock.ExpectExec("INSERT INTO provider_download_stats").
+		WithArgs("prov-1", "5.0.0", "linux", "amd64", day, int64(3)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.AddDownloadStats(context.Background(), []models.ProviderDownloadCount{
+		{ProviderID: "prov-1", Version: "5.0.0", OS: "linux", Arch: "amd64", Day: day, Downloads: 3},
+	})
+	if err != nil {
+		t.Fatalf("AddDownloadStats: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddDownloadStats_ExecError(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO provider_download_stats")
+	mock.ExpectExec("INSERT INTO provider_download_stats").WillReturnError(errDB)
+	mock.ExpectRollback()
+
+	err := repo.AddDownloadStats(context.Background(), []models.ProviderDownloadCount{
+		{ProviderID: "prov-1", Version: "5.0.0", OS: "linux", Arch: "amd64", Downloads: 1},
+	})
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestGetDownloadStats(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT version, os, arch, SUM\\(downloads\\).*FROM provider_download_stats").
+		WithArgs("prov-1", &since).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "os", "arch", "sum"}).
+			AddRow("5.0.0", "linux", "amd64", 7))
+
+	stats, err := repo.GetDownloadStats(context.Background(), "prov-1", &since)
+	if err != nil {
+		t.Fatalf("GetDownloadStats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].ProviderID != "prov-1" || stats[0].Version != "5.0.0" || stats[0].Downloads != 7 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // UpsertProvider
 // ---------------------------------------------------------------------------
@@ -1476,3 +1633,227 @@ func TestUpsertVersion_LookupError(t *testing.T) {
 		t.Error("expected error, got nil")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Upstream metadata
+// ---------------------------------------------------------------------------
+
+func TestGetUpstreamMetadata_Found(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	syncedAt := time.Now()
+	mock.ExpectQuery("SELECT source_url, upstream_tier, license, license_file, license_text.*FROM providers.*upstream_metadata_synced_at IS NOT NULL OR license_checked_at IS NOT NULL").
+		WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows([]string{"source_url", "upstream_tier", "license", "license_file", "license_text", "upstream_metadata_synced_at", "license_checked_at"}).
+			AddRow("https://github.com/hashicorp/terraform-provider-aws", "official", "MPL-2.0", "LICENSE.txt", "Mozilla Public License", syncedAt, syncedAt))
+
+	meta, err := repo.GetUpstreamMetadata(context.Background(), "prov-1")
+	if err != nil {
+		t.Fatalf("GetUpstreamMetadata: %v", err)
+	}
+	if meta == nil || meta.License == nil || *meta.License != "MPL-2.0" || meta.LicenseText == nil || meta.UpstreamTier == nil || meta.LicenseCheckedAt == nil {
+		t.Errorf("meta = %+v", meta)
+	}
+}
+
+func TestGetUpstreamMetadata_NotRecorded(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery("SELECT source_url.*FROM providers").WillReturnError(sql.ErrNoRows)
+
+	meta, err := repo.GetUpstreamMetadata(context.Background(), "prov-1")
+	if err != nil || meta != nil {
+		t.Fatalf("got (%+v, %v), want (nil, nil)", meta, err)
+	}
+}
+
+func TestListUpstreamMetadata(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery("SELECT id, source_url, upstream_tier, license, license_file,.*FROM providers.*ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "source_url", "upstream_tier", "license", "license_file", "upstream_metadata_synced_at", "license_checked_at"}).
+			AddRow("prov-1", nil, "partner", nil, nil, time.Now(), nil))
+
+	got, err := repo.ListUpstreamMetadata(context.Background(), []string{"prov-1", "prov-2"})
+	if err != nil {
+		t.Fatalf("ListUpstreamMetadata: %v", err)
+	}
+	if len(got) != 1 || got["prov-1"] == nil || *got["prov-1"].UpstreamTier != "partner" || got["prov-1"].License != nil {
+		t.Errorf("got = %+v", got)
+	}
+}
+
+func TestListUpstreamMetadata_EmptyInputSkipsQuery(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	got, err := repo.ListUpstreamMetadata(context.Background(), nil)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("got (%v, %v)", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateUpstreamMetadata(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	desc, src := "The AWS provider", "https://github.com/hashicorp/terraform-provider-aws"
+	mock.ExpectExec("UPDATE providers.*COALESCE\\(\\$2, description\\).*source_url = \\$3.*upstream_tier = \\$4").
+		WithArgs("prov-1", &desc, &src, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateUpstreamMetadata(context.Background(), "prov-1", &desc, &src, nil); err != nil {
+		t.Fatalf("UpdateUpstreamMetadata: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tiers
+// ---------------------------------------------------------------------------
+
+func TestProviderListTiers(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery("SELECT id, tier FROM providers.*ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tier"}).AddRow("prov-1", "official"))
+
+	got, err := repo.ListTiers(context.Background(), []string{"prov-1", "prov-2"})
+	if err != nil {
+		t.Fatalf("ListTiers: %v", err)
+	}
+	if len(got) != 1 || got["prov-1"] != "official" {
+		t.Errorf("got = %v", got)
+	}
+}
+
+func TestProviderSetTier_ReturnsPrevious(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery("UPDATE providers.*FOR UPDATE.*RETURNING old.tier").
+		WithArgs("prov-1", "approved").
+		WillReturnRows(sqlmock.NewRows([]string{"tier"}).AddRow("community"))
+
+	previous, err := repo.SetTier(context.Background(), "prov-1", "approved")
+	if err != nil || previous != "community" {
+		t.Fatalf("got (%q, %v), want (community, nil)", previous, err)
+	}
+}
+
+func TestProviderSetTier_NotFound(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery("UPDATE providers").WillReturnError(sql.ErrNoRows)
+
+	previous, err := repo.SetTier(context.Background(), "prov-9", "approved")
+	if err != nil || previous != "" {
+		t.Fatalf("got (%q, %v), want (\"\", nil)", previous, err)
+	}
+}
+
+func TestUpdateLicense_DBError(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectExec("UPDATE providers.*license = \\$2.*license_checked_at = NOW\\(\\)").WillReturnError(errors.New("db down"))
+
+	if err := repo.UpdateLicense(context.Background(), "prov-1", nil, nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ListVersionsFiltered
+// ---------------------------------------------------------------------------
+
+func TestProviderListVersionsFiltered_PublishedBy(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+
+	mock.ExpectQuery(`WHERE pv.provider_id = \$1 AND pv.published_by::text = \$2`).
+		WithArgs("prov-1", "user-1").
+		WillReturnRows(sampleProvVersionListRows())
+
+	versions, total, err := repo.ListVersionsFiltered(context.Background(), "prov-1", VersionListFilter{PublishedBy: "user-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(versions) != 1 {
+		t.Fatalf("total = %d, len = %d; want 1, 1", total, len(versions))
+	}
+	if len(versions[0].Protocols) != 1 || versions[0].Protocols[0] != "6.0" {
+		t.Errorf("protocols = %v, want [6.0]", versions[0].Protocols)
+	}
+}
+
+func TestProviderListVersionsFiltered_QueryError(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+
+	mock.ExpectQuery("SELECT.*FROM provider_versions").
+		WithArgs("prov-1").
+		WillReturnError(errDB)
+
+	if _, _, err := repo.ListVersionsFiltered(context.Background(), "prov-1", VersionListFilter{}); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Batched lookups (ListLatestVisibleVersions, ListPlatformsByVersions,
+// ListVersionResignatures)
+// ---------------------------------------------------------------------------
+
+func TestListLatestVisibleVersions(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery(`SELECT DISTINCT ON \(pv.provider_id\).*WHERE pv.provider_id::text = ANY\(\$1\).*approval_status IN.*ORDER BY pv.provider_id`).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "provider_id", "version", "protocols", "gpg_public_key",
+			"deprecated", "deprecated_at", "deprecation_message", "created_at",
+		}).AddRow("ver-2", "prov-1", "2.0.0", []byte(`["6.0"]`), "", false, nil, nil, time.Now()))
+
+	latest, err := repo.ListLatestVisibleVersions(context.Background(), []string{"prov-1", "prov-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(latest) != 1 || latest["prov-1"].Version != "2.0.0" || latest["prov-1"].Protocols[0] != "6.0" {
+		t.Errorf("latest = %+v", latest)
+	}
+
+	// No IDs, no query.
+	if latest, err := repo.ListLatestVisibleVersions(context.Background(), nil); err != nil || len(latest) != 0 {
+		t.Errorf("empty input: %v, %v", latest, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListPlatformsByVersions(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery(`FROM provider_platforms.*WHERE provider_version_id::text = ANY\(\$1\)`).
+		WillReturnRows(sqlmock.NewRows(platformCols).
+			AddRow("plat-1", "ver-1", "linux", "amd64", "a.zip", "p/a.zip", "local", 10, "aa", nil, 1).
+			AddRow("plat-2", "ver-1", "linux", "arm64", "b.zip", "p/b.zip", "local", 10, "bb", nil, 2).
+			AddRow("plat-3", "ver-2", "darwin", "arm64", "c.zip", "p/c.zip", "local", 10, "cc", nil, 3))
+
+	platforms, err := repo.ListPlatformsByVersions(context.Background(), []string{"ver-1", "ver-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(platforms["ver-1"]) != 2 || len(platforms["ver-2"]) != 1 {
+		t.Errorf("platforms = %v", platforms)
+	}
+
+	mock.ExpectQuery("FROM provider_platforms").WillReturnError(errDB)
+	if _, err := repo.ListPlatformsByVersions(context.Background(), []string{"ver-1"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestListVersionResignatures(t *testing.T) {
+	repo, mock := newProviderRepo(t)
+	mock.ExpectQuery(`FROM provider_version_resignatures.*ANY\(\$1\)`).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"provider_version_id", "key_id", "gpg_public_key", "shasum_storage_key", "shasum_signature_storage_key", "signed_at",
+		}).AddRow("ver-1", "ABCDEF0123456789", "armored", "k", "s", time.Now()))
+
+	rs, err := repo.ListVersionResignatures(context.Background(), []string{"ver-1", "ver-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rs) != 1 || rs["ver-1"].KeyID != "ABCDEF0123456789" {
+		t.Errorf("resignatures = %+v", rs)
+	}
+}
@@ -0,0 +1,57 @@
+// Package repositories - public_stats_repository.go computes the registry-wide
+// aggregates served by GET /api/v1/stats/public.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// PublicStatsRepository reads registry-wide aggregates for status pages.
+type PublicStatsRepository struct {
+	db *sql.DB
+}
+
+// NewPublicStatsRepository creates a new public stats repository.
+func NewPublicStatsRepository(db *sql.DB) *PublicStatsRepository {
+	return &PublicStatsRepository{db: db}
+}
+
+// Get computes the public statistics in one round trip.
+func (r *PublicStatsRepository) Get(ctx context.Context) (*models.PublicStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM modules),
+			(SELECT COUNT(*) FROM module_versions),
+			(SELECT COUNT(*) FROM providers),
+			(SELECT COUNT(*) FROM provider_versions),
+			(SELECT COUNT(DISTINCT provider_id) FROM mirrored_providers),
+			(SELECT COUNT(*) FROM terraform_versions WHERE sync_status = 'synced'),
+			(SELECT MAX(completed_at) FROM mirror_sync_history WHERE status = 'success'),
+			(SELECT MAX(completed_at) FROM terraform_sync_history WHERE status = 'success')
+	`
+	stats := &models.PublicStats{}
+	var lastProviderSync, lastBinarySync sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query).Scan(
+		&stats.Modules,
+		&stats.ModuleVersions,
+		&stats.Providers,
+		&stats.ProviderVersions,
+		&stats.MirroredProviders,
+		&stats.BinaryVersions,
+		&lastProviderSync,
+		&lastBinarySync,
+	); err != nil {
+		return nil, fmt.Errorf("failed to compute public stats: %w", err)
+	}
+	if lastProviderSync.Valid {
+		stats.LastProviderMirrorSyncAt = &lastProviderSync.Time
+	}
+	if lastBinarySync.Valid {
+		stats.LastBinaryMirrorSyncAt = &lastBinarySync.Time
+	}
+	return stats, nil
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestPublicStatsGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewPublicStatsRepository(db)
+
+	synced := time.Now().Add(-time.Hour)
+	mock.ExpectQuery("SELECT.*FROM modules.*FROM mirror_sync_history WHERE status = 'success'.*FROM terraform_sync_history").
+		WillReturnRows(sqlmock.NewRows([]string{"m", "mv", "p", "pv", "mp", "bv", "lp", "lb"}).
+			AddRow(12, 40, 5, 30, 3, 8, synced, nil))
+
+	stats, err := repo.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stats.Modules != 12 || stats.MirroredProviders != 3 || stats.BinaryVersions != 8 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.LastProviderMirrorSyncAt == nil || !stats.LastProviderMirrorSyncAt.Equal(synced) {
+		t.Errorf("LastProviderMirrorSyncAt = %v, want %v", stats.LastProviderMirrorSyncAt, synced)
+	}
+	if stats.LastBinaryMirrorSyncAt != nil {
+		t.Errorf("LastBinaryMirrorSyncAt = %v, want nil", stats.LastBinaryMirrorSyncAt)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("db down"))
+	if _, err := repo.Get(context.Background()); err == nil {
+		t.Error("Get: want error when the query fails")
+	}
+}
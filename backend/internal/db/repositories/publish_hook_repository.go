@@ -0,0 +1,139 @@
+// Package repositories - publish_hook_repository.go persists per-organization
+// pre-publish hooks and the log of calls made to them.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// PublishHookRepository handles publish hook database operations.
+type PublishHookRepository struct {
+	db *sql.DB
+}
+
+// NewPublishHookRepository creates a new publish hook repository.
+func NewPublishHookRepository(db *sql.DB) *PublishHookRepository {
+	return &PublishHookRepository{db: db}
+}
+
+// Get returns an organization's publish hook, or nil when it has none.
+func (r *PublishHookRepository) Get(ctx context.Context, orgID string) (*models.PublishHook, error) {
+	h := &models.PublishHook{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT organization_id, url, encrypted_secret, timeout_seconds, failure_mode,
+		       enabled, created_at, updated_at
+		FROM publish_hooks WHERE organization_id = $1`, orgID,
+	).Scan(&h.OrganizationID, &h.URL, &h.EncryptedSecret, &h.TimeoutSeconds, &h.FailureMode,
+		&h.Enabled, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get publish hook: %w", err)
+	}
+	h.HasSecret = h.EncryptedSecret != ""
+	return h, nil
+}
+
+// Upsert creates or replaces h.OrganizationID's hook and fills in its
+// timestamps.
+func (r *PublishHookRepository) Upsert(ctx context.Context, h *models.PublishHook) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO publish_hooks (organization_id, url, encrypted_secret, timeout_seconds, failure_mode, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			url              = EXCLUDED.url,
+			encrypted_secret = EXCLUDED.encrypted_secret,
+			timeout_seconds  = EXCLUDED.timeout_seconds,
+			failure_mode     = EXCLUDED.failure_mode,
+			enabled          = EXCLUDED.enabled,
+			updated_at       = NOW()
+		RETURNING created_at, updated_at`,
+		h.OrganizationID, h.URL, h.EncryptedSecret, h.TimeoutSeconds, h.FailureMode, h.Enabled,
+	).Scan(&h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert publish hook: %w", err)
+	}
+	h.HasSecret = h.EncryptedSecret != ""
+	return nil
+}
+
+// Delete removes an organization's hook and its delivery log. It reports
+// whether there was a hook to remove.
+func (r *PublishHookRepository) Delete(ctx context.Context, orgID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM publish_hooks WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete publish hook: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete publish hook: %w", err)
+	}
+	return n > 0, nil
+}
+
+// CreateDelivery records a call to a publish hook and fills in its ID and
+// creation time.
+func (r *PublishHookRepository) CreateDelivery(ctx context.Context, d *models.PublishHookDelivery) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO publish_hook_deliveries
+			(organization_id, source, namespace, name, system, version, checksum,
+			 outcome, reason, status_code, attempts, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at`,
+		d.OrganizationID, d.Source, d.Namespace, d.Name, d.System, d.Version, d.Checksum,
+		d.Outcome, d.Reason, d.StatusCode, d.Attempts, d.Error, d.DurationMs,
+	).Scan(&d.ID, &d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record publish hook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns an organization's most recent hook deliveries,
+// newest first.
+func (r *PublishHookRepository) ListDeliveries(ctx context.Context, orgID string, limit int) ([]*models.PublishHookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, organization_id, source, namespace, name, system, version, checksum,
+		       outcome, reason, status_code, attempts, error, duration_ms, created_at
+		FROM publish_hook_deliveries
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, orgID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list publish hook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.PublishHookDelivery{}
+	for rows.Next() {
+		d := &models.PublishHookDelivery{}
+		var reason, errMsg sql.NullString
+		var statusCode sql.NullInt64
+		if err := rows.Scan(
+			&d.ID, &d.OrganizationID, &d.Source, &d.Namespace, &d.Name, &d.System, &d.Version, &d.Checksum,
+			&d.Outcome, &reason, &statusCode, &d.Attempts, &errMsg, &d.DurationMs, &d.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan publish hook delivery: %w", err)
+		}
+		if reason.Valid {
+			d.Reason = &reason.String
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			d.StatusCode = &code
+		}
+		if errMsg.Valid {
+			d.Error = &errMsg.String
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate publish hook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newPublishHookRepo(t *testing.T) (*PublishHookRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewPublishHookRepository(db), mock
+}
+
+func TestPublishHookRepository_Get(t *testing.T) {
+	repo, mock := newPublishHookRepo(t)
+	cols := []string{"organization_id", "url", "encrypted_secret", "timeout_seconds", "failure_mode", "enabled", "created_at", "updated_at"}
+	mock.ExpectQuery("SELECT.*FROM publish_hooks WHERE organization_id").
+		WithArgs("org-1").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("org-1", "https://hooks.example.com", "sealed", 10, "fail_open", true, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT.*FROM publish_hooks").
+		WillReturnError(sql.ErrNoRows)
+
+	h, err := repo.Get(context.Background(), "org-1")
+	if err != nil || h == nil || !h.HasSecret || h.FailureMode != models.PublishHookFailOpen || !h.Enabled {
+		t.Fatalf("Get = %+v, %v", h, err)
+	}
+	h, err = repo.Get(context.Background(), "org-2")
+	if err != nil || h != nil {
+		t.Fatalf("Get(missing) = %+v, %v; want nil, nil", h, err)
+	}
+}
+
+func TestPublishHookRepository_Delete(t *testing.T) {
+	repo, mock := newPublishHookRepo(t)
+	mock.ExpectExec("DELETE FROM publish_hooks").WithArgs("org-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM publish_hooks").WithArgs("org-2").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if ok, err := repo.Delete(context.Background(), "org-1"); err != nil || !ok {
+		t.Fatalf("Delete = %v, %v; want true", ok, err)
+	}
+	if ok, err := repo.Delete(context.Background(), "org-2"); err != nil || ok {
+		t.Fatalf("Delete(missing) = %v, %v; want false", ok, err)
+	}
+}
+
+func TestPublishHookRepository_ListDeliveries(t *testing.T) {
+	repo, mock := newPublishHookRepo(t)
+	cols := []string{"id", "organization_id", "source", "namespace", "name", "system", "version", "checksum",
+		"outcome", "reason", "status_code", "attempts", "error", "duration_ms", "created_at"}
+	mock.ExpectQuery("FROM publish_hook_deliveries.*ORDER BY created_at DESC").
+		WithArgs("org-1", 50).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("d-2", "org-1", "scm", "acme", "vpc", "aws", "1.1.0", "abc", "failed_closed", nil, nil, 2, "timeout", 10000, time.Now()).
+			AddRow("d-1", "org-1", "upload", "acme", "vpc", "aws", "1.0.0", "def", "denied", "unsigned", 200, 1, nil, 12, time.Now()))
+
+	got, err := repo.ListDeliveries(context.Background(), "org-1", 50)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("ListDeliveries = %v, %v", got, err)
+	}
+	if got[0].StatusCode != nil || got[0].Error == nil || *got[0].Error != "timeout" {
+		t.Errorf("failed delivery = %+v", got[0])
+	}
+	if got[1].StatusCode == nil || *got[1].StatusCode != 200 || got[1].Reason == nil || *got[1].Reason != "unsigned" {
+		t.Errorf("denied delivery = %+v", got[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -5,6 +5,9 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -105,20 +108,21 @@ func (r *RBACRepository) DeleteRoleTemplate(ctx context.Context, id uuid.UUID) e
 // CreateApprovalRequest creates a new approval request
 func (r *RBACRepository) CreateApprovalRequest(ctx context.Context, req *models.MirrorApprovalRequest) error {
 	query := `INSERT INTO mirror_approval_requests
-			  (id, mirror_config_id, organization_id, requested_by, provider_namespace, provider_name, reason, status, auto_approved, created_at, updated_at, expires_at)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+			  (id, mirror_config_id, organization_id, requested_by, provider_namespace, provider_name, reason, status, auto_approved, created_at, updated_at, expires_at, auto_apply)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		req.ID, req.MirrorConfigID, req.OrganizationID, req.RequestedBy,
 		req.ProviderNamespace, req.ProviderName, req.Reason, req.Status,
-		req.AutoApproved, req.CreatedAt, req.UpdatedAt, req.ExpiresAt)
+		req.AutoApproved, req.CreatedAt, req.UpdatedAt, req.ExpiresAt, req.AutoApply)
 	return err
 }
 
 // GetApprovalRequest retrieves an approval request by ID
 func (r *RBACRepository) GetApprovalRequest(ctx context.Context, id uuid.UUID) (*models.MirrorApprovalRequest, error) {
 	query := `SELECT id, mirror_config_id, organization_id, requested_by, provider_namespace, provider_name,
-			  reason, status, reviewed_by, reviewed_at, review_notes, auto_approved, created_at, updated_at, expires_at
+			  reason, status, reviewed_by, reviewed_at, review_notes, auto_approved, created_at, updated_at, expires_at,
+			  auto_apply, applied_namespace, applied_provider, applied_at, revoked_by, revoked_at
 			  FROM mirror_approval_requests WHERE id = $1`
 
 	var req models.MirrorApprovalRequest
@@ -126,7 +130,8 @@ func (r *RBACRepository) GetApprovalRequest(ctx context.Context, id uuid.UUID) (
 		&req.ID, &req.MirrorConfigID, &req.OrganizationID, &req.RequestedBy,
 		&req.ProviderNamespace, &req.ProviderName, &req.Reason, &req.Status,
 		&req.ReviewedBy, &req.ReviewedAt, &req.ReviewNotes, &req.AutoApproved,
-		&req.CreatedAt, &req.UpdatedAt, &req.ExpiresAt)
+		&req.CreatedAt, &req.UpdatedAt, &req.ExpiresAt,
+		&req.AutoApply, &req.AppliedNamespace, &req.AppliedProvider, &req.AppliedAt, &req.RevokedBy, &req.RevokedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -138,6 +143,7 @@ func (r *RBACRepository) ListApprovalRequests(ctx context.Context, orgID *uuid.U
 	query := `SELECT mar.id, mar.mirror_config_id, mar.organization_id, mar.requested_by, mar.provider_namespace, mar.provider_name,
 			  mar.reason, mar.status, mar.reviewed_by, mar.reviewed_at, mar.review_notes, mar.auto_approved,
 			  mar.created_at, mar.updated_at, mar.expires_at,
+			  mar.auto_apply, mar.applied_namespace, mar.applied_provider, mar.applied_at, mar.revoked_by, mar.revoked_at,
 			  COALESCE(u1.name, '') as requested_by_name,
 			  COALESCE(u2.name, '') as reviewed_by_name,
 			  COALESCE(mc.name, '') as mirror_name
@@ -177,6 +183,7 @@ func (r *RBACRepository) ListApprovalRequests(ctx context.Context, orgID *uuid.U
 			&req.ProviderNamespace, &req.ProviderName, &req.Reason, &req.Status,
 			&req.ReviewedBy, &req.ReviewedAt, &req.ReviewNotes, &req.AutoApproved,
 			&req.CreatedAt, &req.UpdatedAt, &req.ExpiresAt,
+			&req.AutoApply, &req.AppliedNamespace, &req.AppliedProvider, &req.AppliedAt, &req.RevokedBy, &req.RevokedAt,
 			&req.RequestedByName, &req.ReviewedByName, &req.MirrorName); err != nil {
 			return nil, err
 		}
@@ -203,10 +210,277 @@ func (r *RBACRepository) UpdateApprovalStatus(ctx context.Context, id uuid.UUID,
 	return err
 }
 
+// ErrApprovalNotApproved is returned by RevokeApproval for a request that is
+// not currently approved.
+var ErrApprovalNotApproved = errors.New("approval request is not approved")
+
+// ApprovalFilterChange lists the mirror filter entries an approval added or a
+// revocation removed; nil fields mean the filter was left unchanged.
+type ApprovalFilterChange struct {
+	Namespace *string `json:"namespace,omitempty"`
+	Provider  *string `json:"provider,omitempty"`
+}
+
+// lockedApproval is the part of an approval request row the filter
+// transactions need.
+type lockedApproval struct {
+	MirrorConfigID    uuid.UUID  `db:"mirror_config_id"`
+	ProviderNamespace string     `db:"provider_namespace"`
+	ProviderName      *string    `db:"provider_name"`
+	Status            string     `db:"status"`
+	AppliedNamespace  *string    `db:"applied_namespace"`
+	AppliedProvider   *string    `db:"applied_provider"`
+	AppliedAt         *time.Time `db:"applied_at"`
+}
+
+// lockedFilters is a mirror configuration's namespace and provider filters,
+// decoded from their JSON arrays.
+type lockedFilters struct {
+	namespaces []string
+	providers  []string
+}
+
+// lockApprovalAndMirror locks approval request id and its mirror
+// configuration for the rest of tx. It returns nil when the request does not
+// exist.
+func lockApprovalAndMirror(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) (*lockedApproval, *lockedFilters, error) {
+	var a lockedApproval
+	err := tx.GetContext(ctx, &a, `SELECT mirror_config_id, provider_namespace, provider_name, status,
+			  applied_namespace, applied_provider, applied_at
+			  FROM mirror_approval_requests WHERE id = $1 FOR UPDATE`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock approval request: %w", err)
+	}
+
+	f, err := lockMirrorFilters(ctx, tx, a.MirrorConfigID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &a, f, nil
+}
+
+// lockMirrorFilters locks mirror configuration mirrorID for the rest of tx
+// and returns its decoded filters.
+func lockMirrorFilters(ctx context.Context, tx *sqlx.Tx, mirrorID uuid.UUID) (*lockedFilters, error) {
+	var nsJSON, providerJSON *string
+	err := tx.QueryRowxContext(ctx, `SELECT namespace_filter, provider_filter
+			  FROM mirror_configurations WHERE id = $1 FOR UPDATE`, mirrorID).Scan(&nsJSON, &providerJSON)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("mirror configuration %s not found", mirrorID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock mirror configuration: %w", err)
+	}
+	f := &lockedFilters{}
+	if f.namespaces, err = decodeFilter(nsJSON); err != nil {
+		return nil, fmt.Errorf("invalid namespace filter: %w", err)
+	}
+	if f.providers, err = decodeFilter(providerJSON); err != nil {
+		return nil, fmt.Errorf("invalid provider filter: %w", err)
+	}
+	return f, nil
+}
+
+// decodeFilter decodes a mirror filter column; NULL and "" are empty.
+func decodeFilter(raw *string) ([]string, error) {
+	var out []string
+	if raw == nil || *raw == "" {
+		return out, nil
+	}
+	err := json.Unmarshal([]byte(*raw), &out)
+	return out, err
+}
+
+// encodeFilter encodes a mirror filter column, storing NULL for no entries.
+func encodeFilter(entries []string) (*string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	s := string(b)
+	return &s, nil
+}
+
+// updateMirrorFilters writes f back to mirror configuration mirrorID.
+func updateMirrorFilters(ctx context.Context, tx *sqlx.Tx, mirrorID uuid.UUID, f *lockedFilters) error {
+	nsJSON, err := encodeFilter(f.namespaces)
+	if err != nil {
+		return err
+	}
+	providerJSON, err := encodeFilter(f.providers)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE mirror_configurations
+			  SET namespace_filter = $2, provider_filter = $3, updated_at = NOW()
+			  WHERE id = $1`, mirrorID, nsJSON, providerJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update mirror filters: %w", err)
+	}
+	return nil
+}
+
+// ApproveAndApplyFilters approves request id and, in the same transaction,
+// adds its namespace and provider to the target mirror's namespace_filter and
+// provider_filter. Entries already present are left alone and not recorded as
+// applied, so a later revocation never removes a filter entry it did not add.
+// Approving a request whose filters were already applied changes only the
+// review fields. It returns the change made, or nil when the request does not
+// exist.
+func (r *RBACRepository) ApproveAndApplyFilters(ctx context.Context, id uuid.UUID, reviewedBy uuid.UUID, notes string) (*ApprovalFilterChange, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	a, f, err := lockApprovalAndMirror(ctx, tx, id)
+	if err != nil || a == nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	change := &ApprovalFilterChange{}
+	appliedNamespace, appliedProvider, appliedAt := a.AppliedNamespace, a.AppliedProvider, a.AppliedAt
+	if appliedAt == nil {
+		if !containsString(f.namespaces, a.ProviderNamespace) {
+			f.namespaces = append(f.namespaces, a.ProviderNamespace)
+			change.Namespace = &a.ProviderNamespace
+		}
+		if a.ProviderName != nil && !containsString(f.providers, *a.ProviderName) {
+			f.providers = append(f.providers, *a.ProviderName)
+			change.Provider = a.ProviderName
+		}
+		if change.Namespace != nil || change.Provider != nil {
+			if err := updateMirrorFilters(ctx, tx, a.MirrorConfigID, f); err != nil {
+				return nil, err
+			}
+		}
+		appliedNamespace, appliedProvider, appliedAt = change.Namespace, change.Provider, &now
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE mirror_approval_requests
+			  SET status = $2, reviewed_by = $3, reviewed_at = $4, review_notes = $5, updated_at = $4,
+			      applied_namespace = $6, applied_provider = $7, applied_at = $8,
+			      revoked_by = NULL, revoked_at = NULL
+			  WHERE id = $1`,
+		id, models.ApprovalStatusApproved, reviewedBy, now, notes, appliedNamespace, appliedProvider, appliedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve request: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// RevokeApproval revokes approved request id and, in the same transaction,
+// removes the filter entries its approval added from the mirror, unless
+// another approved request for the same mirror still asks for them. Mirrored
+// providers are not deleted; they are just no longer synced. The applied
+// entries are cleared so approving the request again re-applies them. It
+// returns the change made, nil when the request does not exist, or
+// ErrApprovalNotApproved.
+func (r *RBACRepository) RevokeApproval(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID, notes string) (*ApprovalFilterChange, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	a, f, err := lockApprovalAndMirror(ctx, tx, id)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	if a.Status != string(models.ApprovalStatusApproved) {
+		return nil, ErrApprovalNotApproved
+	}
+
+	change := &ApprovalFilterChange{}
+	if a.AppliedNamespace != nil {
+		needed, err := otherApprovalNeeds(ctx, tx, a.MirrorConfigID, id, "provider_namespace", *a.AppliedNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if !needed {
+			f.namespaces = removeString(f.namespaces, *a.AppliedNamespace)
+			change.Namespace = a.AppliedNamespace
+		}
+	}
+	if a.AppliedProvider != nil {
+		needed, err := otherApprovalNeeds(ctx, tx, a.MirrorConfigID, id, "provider_name", *a.AppliedProvider)
+		if err != nil {
+			return nil, err
+		}
+		if !needed {
+			f.providers = removeString(f.providers, *a.AppliedProvider)
+			change.Provider = a.AppliedProvider
+		}
+	}
+	if change.Namespace != nil || change.Provider != nil {
+		if err := updateMirrorFilters(ctx, tx, a.MirrorConfigID, f); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `UPDATE mirror_approval_requests
+			  SET status = $2, revoked_by = $3, revoked_at = $4, updated_at = $4,
+			      review_notes = COALESCE(NULLIF($5::text, ''), review_notes),
+			      applied_namespace = NULL, applied_provider = NULL, applied_at = NULL
+			  WHERE id = $1`,
+		id, models.ApprovalStatusRevoked, revokedBy, now, notes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke request: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// otherApprovalNeeds reports whether an approved request for mirrorID other
+// than exceptID asks for value in column (provider_namespace or provider_name).
+func otherApprovalNeeds(ctx context.Context, tx *sqlx.Tx, mirrorID, exceptID uuid.UUID, column, value string) (bool, error) {
+	var needed bool
+	query := `SELECT EXISTS(SELECT 1 FROM mirror_approval_requests
+			  WHERE mirror_config_id = $1 AND id <> $2 AND status = 'approved' AND ` + column + ` = $3)`
+	if err := tx.QueryRowxContext(ctx, query, mirrorID, exceptID, value).Scan(&needed); err != nil {
+		return false, fmt.Errorf("failed to check other approvals: %w", err)
+	}
+	return needed, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, s string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // CheckApproval checks if a provider is approved for mirroring
 func (r *RBACRepository) CheckApproval(ctx context.Context, mirrorConfigID uuid.UUID, namespace, provider string) (*models.MirrorApprovalRequest, error) {
 	query := `SELECT id, mirror_config_id, organization_id, requested_by, provider_namespace, provider_name,
-			  reason, status, reviewed_by, reviewed_at, review_notes, auto_approved, created_at, updated_at, expires_at
+			  reason, status, reviewed_by, reviewed_at, review_notes, auto_approved, created_at, updated_at, expires_at,
+			  auto_apply, applied_namespace, applied_provider, applied_at, revoked_by, revoked_at
 			  FROM mirror_approval_requests
 			  WHERE mirror_config_id = $1
 			    AND provider_namespace = $2
@@ -221,7 +495,8 @@ func (r *RBACRepository) CheckApproval(ctx context.Context, mirrorConfigID uuid.
 		&req.ID, &req.MirrorConfigID, &req.OrganizationID, &req.RequestedBy,
 		&req.ProviderNamespace, &req.ProviderName, &req.Reason, &req.Status,
 		&req.ReviewedBy, &req.ReviewedAt, &req.ReviewNotes, &req.AutoApproved,
-		&req.CreatedAt, &req.UpdatedAt, &req.ExpiresAt)
+		&req.CreatedAt, &req.UpdatedAt, &req.ExpiresAt,
+		&req.AutoApply, &req.AppliedNamespace, &req.AppliedProvider, &req.AppliedAt, &req.RevokedBy, &req.RevokedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
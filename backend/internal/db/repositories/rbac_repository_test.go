@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -24,6 +25,7 @@ var approvalReqCols = []string{
 	"provider_namespace", "provider_name", "reason", "status",
 	"reviewed_by", "reviewed_at", "review_notes", "auto_approved",
 	"created_at", "updated_at", "expires_at",
+	"auto_apply", "applied_namespace", "applied_provider", "applied_at", "revoked_by", "revoked_at",
 }
 
 var approvalReqListCols = []string{
@@ -31,6 +33,7 @@ var approvalReqListCols = []string{
 	"provider_namespace", "provider_name", "reason", "status",
 	"reviewed_by", "reviewed_at", "review_notes", "auto_approved",
 	"created_at", "updated_at", "expires_at",
+	"auto_apply", "applied_namespace", "applied_provider", "applied_at", "revoked_by", "revoked_at",
 	"requested_by_name", "reviewed_by_name", "mirror_name",
 }
 
@@ -76,7 +79,8 @@ func sampleApprovalRow() *sqlmock.Rows {
 	cfgID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
 	return sqlmock.NewRows(approvalReqCols).
 		AddRow(id, cfgID, nil, nil, "hashicorp", nil, "need it", "pending",
-			nil, nil, nil, false, time.Now(), time.Now(), nil)
+			nil, nil, nil, false, time.Now(), time.Now(), nil,
+			false, nil, nil, nil, nil, nil)
 }
 
 func sampleApprovalListRow() *sqlmock.Rows {
@@ -85,6 +89,7 @@ func sampleApprovalListRow() *sqlmock.Rows {
 	return sqlmock.NewRows(approvalReqListCols).
 		AddRow(id, cfgID, nil, nil, "hashicorp", nil, "need it", "pending",
 			nil, nil, nil, false, time.Now(), time.Now(), nil,
+			false, nil, nil, nil, nil, nil,
 			"Alice", "", "my-mirror")
 }
 
@@ -388,6 +393,111 @@ func TestUpdateApprovalStatus_Success(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ApproveAndApplyFilters / RevokeApproval
+// ---------------------------------------------------------------------------
+
+var lockedApprovalCols = []string{
+	"mirror_config_id", "provider_namespace", "provider_name", "status",
+	"applied_namespace", "applied_provider", "applied_at",
+}
+
+func TestApproveAndApplyFilters_AddsMissingEntries(t *testing.T) {
+	repo, mock := newRBACRepo(t)
+	cfgID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mirror_config_id.*FROM mirror_approval_requests WHERE id = \\$1 FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows(lockedApprovalCols).
+			AddRow(cfgID, "hashicorp", "aws", "pending", nil, nil, nil))
+	mock.ExpectQuery("SELECT namespace_filter, provider_filter.*FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace_filter", "provider_filter"}).
+			AddRow(`["hashicorp"]`, `["random"]`))
+	mock.ExpectExec("UPDATE mirror_configurations").
+		WithArgs(cfgID, `["hashicorp"]`, `["random","aws"]`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE mirror_approval_requests").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	change, err := repo.ApproveAndApplyFilters(context.Background(), uuid.New(), uuid.New(), "ok")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if change.Namespace != nil {
+		t.Errorf("namespace = %q, want nil (already present)", *change.Namespace)
+	}
+	if change.Provider == nil || *change.Provider != "aws" {
+		t.Errorf("provider = %v, want aws", change.Provider)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestApproveAndApplyFilters_NotFound(t *testing.T) {
+	repo, mock := newRBACRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mirror_config_id.*FROM mirror_approval_requests").
+		WillReturnRows(sqlmock.NewRows(lockedApprovalCols))
+	mock.ExpectRollback()
+
+	change, err := repo.ApproveAndApplyFilters(context.Background(), uuid.New(), uuid.New(), "")
+	if err != nil || change != nil {
+		t.Fatalf("got (%v, %v), want (nil, nil)", change, err)
+	}
+}
+
+func TestRevokeApproval_NotApproved(t *testing.T) {
+	repo, mock := newRBACRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mirror_config_id.*FROM mirror_approval_requests").
+		WillReturnRows(sqlmock.NewRows(lockedApprovalCols).
+			AddRow(uuid.New(), "hashicorp", nil, "rejected", nil, nil, nil))
+	mock.ExpectQuery("SELECT namespace_filter, provider_filter").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace_filter", "provider_filter"}).AddRow(nil, nil))
+	mock.ExpectRollback()
+
+	if _, err := repo.RevokeApproval(context.Background(), uuid.New(), uuid.New(), ""); !errors.Is(err, ErrApprovalNotApproved) {
+		t.Fatalf("err = %v, want ErrApprovalNotApproved", err)
+	}
+}
+
+func TestRevokeApproval_KeepsEntriesOtherApprovalsNeed(t *testing.T) {
+	repo, mock := newRBACRepo(t)
+	cfgID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mirror_config_id.*FROM mirror_approval_requests").
+		WillReturnRows(sqlmock.NewRows(lockedApprovalCols).
+			AddRow(cfgID, "hashicorp", "aws", "approved", "hashicorp", "aws", time.Now()))
+	mock.ExpectQuery("SELECT namespace_filter, provider_filter").
+		WillReturnRows(sqlmock.NewRows([]string{"namespace_filter", "provider_filter"}).
+			AddRow(`["hashicorp"]`, `["aws"]`))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(cfgID, sqlmock.AnyArg(), "hashicorp").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(cfgID, sqlmock.AnyArg(), "aws").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec("UPDATE mirror_configurations").
+		WithArgs(cfgID, `["hashicorp"]`, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE mirror_approval_requests").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	change, err := repo.RevokeApproval(context.Background(), uuid.New(), uuid.New(), "no longer needed")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if change.Namespace != nil {
+		t.Errorf("namespace = %q, want nil (still needed)", *change.Namespace)
+	}
+	if change.Provider == nil || *change.Provider != "aws" {
+		t.Errorf("provider = %v, want aws", change.Provider)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // CheckApproval
 // ---------------------------------------------------------------------------
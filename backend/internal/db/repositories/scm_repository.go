@@ -6,10 +6,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/terraform-registry/terraform-registry/internal/scm"
 )
 
@@ -208,9 +211,10 @@ func (r *SCMRepository) CreateModuleSourceRepo(ctx context.Context, link *scm.Mo
 			id, module_id, scm_provider_id, repository_owner, repository_name, repository_url,
 			default_branch, module_path, tag_pattern, auto_publish,
 			webhook_id, webhook_url, webhook_enabled,
-			last_sync_at, last_sync_commit, created_at, updated_at
+			last_sync_at, last_sync_commit, created_at, updated_at, skip_prerelease,
+			module_address
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
 		)`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -218,7 +222,8 @@ func (r *SCMRepository) CreateModuleSourceRepo(ctx context.Context, link *scm.Mo
 		link.RepositoryURL, link.DefaultBranch, link.ModulePath, link.TagPattern,
 		link.AutoPublish, link.WebhookID, link.WebhookURL,
 		link.WebhookEnabled, link.LastSyncAt, link.LastSyncCommit,
-		link.CreatedAt, link.UpdatedAt,
+		link.CreatedAt, link.UpdatedAt, link.SkipPrerelease,
+		link.ModuleAddress,
 	)
 	return err
 }
@@ -242,7 +247,7 @@ func (r *SCMRepository) UpdateModuleSourceRepo(ctx context.Context, link *scm.Mo
 			default_branch = $5, module_path = $6, tag_pattern = $7,
 			auto_publish = $8, webhook_id = $9, webhook_url = $10,
 			webhook_enabled = $11, last_sync_at = $12, last_sync_commit = $13,
-			updated_at = $14
+			updated_at = $14, skip_prerelease = $15
 		WHERE id = $1`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -250,6 +255,7 @@ func (r *SCMRepository) UpdateModuleSourceRepo(ctx context.Context, link *scm.Mo
 		link.DefaultBranch, link.ModulePath, link.TagPattern,
 		link.AutoPublish, link.WebhookID, link.WebhookURL,
 		link.WebhookEnabled, link.LastSyncAt, link.LastSyncCommit, time.Now(),
+		link.SkipPrerelease,
 	)
 	return err
 }
@@ -261,9 +267,53 @@ func (r *SCMRepository) DeleteModuleSourceRepo(ctx context.Context, moduleID uui
 	return err
 }
 
+// SetModuleSourceRepoPublisher makes userID the publishing identity of a link
+// and clears any token-invalid flag, since the new identity's token was just
+// verified by the caller.
+func (r *SCMRepository) SetModuleSourceRepoPublisher(ctx context.Context, linkID, userID uuid.UUID) error {
+	query := `
+		UPDATE module_scm_repos SET
+			publishing_user_id = $2, token_invalid_at = NULL, updated_at = $3
+		WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, linkID, userID, time.Now())
+	return err
+}
+
+// SetModuleSourceRepoTokenInvalid records (invalidAt non-nil) or clears
+// (invalidAt nil) the token-invalid flag on a link.
+func (r *SCMRepository) SetModuleSourceRepoTokenInvalid(ctx context.Context, linkID uuid.UUID, invalidAt *time.Time) error {
+	query := `UPDATE module_scm_repos SET token_invalid_at = $2 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, linkID, invalidAt)
+	return err
+}
+
+// RotateModuleSourceRepoWebhookSecret persists a rotated webhook secret: the
+// link's new secret, callback URL and remote hook, plus the replaced secret and
+// URL with the time they stop being accepted.
+func (r *SCMRepository) RotateModuleSourceRepoWebhookSecret(ctx context.Context, link *scm.ModuleSourceRepoRecord) error {
+	query := `
+		UPDATE module_scm_repos SET
+			webhook_secret = $2, webhook_url = $3, webhook_id = $4, webhook_enabled = $5,
+			previous_webhook_secret = $6, previous_webhook_url = $7,
+			webhook_secret_grace_until = $8, updated_at = $9
+		WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query,
+		link.ID, link.WebhookSecret, link.WebhookURL, link.WebhookID, link.WebhookEnabled,
+		link.PreviousWebhookSecret, link.PreviousWebhookURL,
+		link.WebhookSecretGraceUntil, time.Now(),
+	)
+	return err
+}
+
 // Webhook Event Logging
 
-// CreateWebhookLog creates a webhook event log entry
+// ErrDuplicateWebhookDelivery is returned by CreateWebhookLog when the link
+// already logged a delivery with the same provider delivery ID.
+var ErrDuplicateWebhookDelivery = errors.New("webhook delivery already received")
+
+// CreateWebhookLog creates a webhook event log entry. A redelivery of a
+// delivery the link already logged (same DeliveryID) is not logged again and
+// returns ErrDuplicateWebhookDelivery.
 func (r *SCMRepository) CreateWebhookLog(ctx context.Context, log *scm.SCMWebhookLogRecord) error {
 	payloadJSON, err := json.Marshal(log.Payload)
 	if err != nil {
@@ -280,18 +330,40 @@ func (r *SCMRepository) CreateWebhookLog(ctx context.Context, log *scm.SCMWebhoo
 			id, module_scm_repo_id, event_id, event_type, ref, commit_sha,
 			tag_name, payload, headers, signature, signature_valid,
 			processed, processing_started_at, processed_at,
-			result_version_id, error, created_at
+			result_version_id, error, created_at, delivery_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
-		)`
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+		)
+		ON CONFLICT (module_scm_repo_id, delivery_id) WHERE delivery_id IS NOT NULL DO NOTHING`
 
-	_, err = r.db.ExecContext(ctx, query,
+	res, err := r.db.ExecContext(ctx, query,
 		log.ID, log.ModuleSCMRepoID, log.EventID, log.EventType, log.Ref,
 		log.CommitSHA, log.TagName, payloadJSON, headersJSON, log.Signature,
 		log.SignatureValid, false, log.ProcessingStartedAt,
-		log.ProcessedAt, log.ResultVersionID, log.Error, log.CreatedAt,
+		log.ProcessedAt, log.ResultVersionID, log.Error, log.CreatedAt, log.DeliveryID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrDuplicateWebhookDelivery
+	}
+	return nil
+}
+
+// GetWebhookLogByDeliveryID retrieves the event the link logged for a
+// provider delivery ID, or nil when it has none.
+func (r *SCMRepository) GetWebhookLogByDeliveryID(ctx context.Context, repoID uuid.UUID, deliveryID string) (*scm.SCMWebhookLogRecord, error) {
+	var log scm.SCMWebhookLogRecord
+	query := `SELECT * FROM scm_webhook_events WHERE module_scm_repo_id = $1 AND delivery_id = $2`
+	err := r.db.GetContext(ctx, &log, query, repoID, deliveryID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
 }
 
 // GetWebhookLog retrieves a webhook log entry
@@ -307,12 +379,40 @@ func (r *SCMRepository) GetWebhookLog(ctx context.Context, id uuid.UUID) (*scm.S
 
 // ListWebhookLogs lists webhook logs for a module source repository
 func (r *SCMRepository) ListWebhookLogs(ctx context.Context, repoID uuid.UUID, limit int) ([]*scm.SCMWebhookLogRecord, error) {
+	return r.ListWebhookLogsAfter(ctx, repoID, nil, limit)
+}
+
+// ListWebhookLogsAfter lists up to limit webhook logs for a module source
+// repository, newest first, starting after the log at after (from the newest
+// when after is nil).
+func (r *SCMRepository) ListWebhookLogsAfter(ctx context.Context, repoID uuid.UUID, after *Keyset, limit int) ([]*scm.SCMWebhookLogRecord, error) {
+	var wb whereBuilder
+	wb.add("module_scm_repo_id = $%d", repoID)
+	if after != nil {
+		wb.addKeyset("created_at", "id", *after)
+	}
+	where, args := wb.clause()
 	var logs []*scm.SCMWebhookLogRecord
-	query := `SELECT * FROM scm_webhook_events WHERE module_scm_repo_id = $1 ORDER BY created_at DESC LIMIT $2`
-	err := r.db.SelectContext(ctx, &logs, query, repoID, limit)
+	// #nosec G201 -- where holds only fixed conditions; values are bound args
+	query := fmt.Sprintf(`SELECT * FROM scm_webhook_events %s ORDER BY created_at DESC, id DESC LIMIT $%d`, where, wb.nextPlaceholder())
+	err := r.db.SelectContext(ctx, &logs, query, append(args, limit)...)
 	return logs, err
 }
 
+// GetLatestWebhookLogTime returns when the most recent webhook event for a
+// link was received, or nil when none has been.
+func (r *SCMRepository) GetLatestWebhookLogTime(ctx context.Context, repoID uuid.UUID) (*time.Time, error) {
+	var latest sql.NullTime
+	query := `SELECT MAX(created_at) FROM scm_webhook_events WHERE module_scm_repo_id = $1`
+	if err := r.db.QueryRowContext(ctx, query, repoID).Scan(&latest); err != nil {
+		return nil, err
+	}
+	if !latest.Valid {
+		return nil, nil
+	}
+	return &latest.Time, nil
+}
+
 // UpdateWebhookLogState updates the processing state of a webhook log.
 //
 // The table has no state column — the state string maps onto the processing
@@ -347,6 +447,101 @@ func (r *SCMRepository) UpdateWebhookLogState(ctx context.Context, id uuid.UUID,
 	return err
 }
 
+// SCM Publish Metadata
+
+// CreatePublishMetadata records how a module version was published from the
+// link linkID.
+func (r *SCMRepository) CreatePublishMetadata(ctx context.Context, linkID uuid.UUID, m *scm.PublishMetadata) error {
+	calls, err := json.Marshal(m.ConnectorAPICalls)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO module_version_publish_metadata (
+			module_version_id, module_scm_repo_id, tag_name, commit_sha,
+			source_archive_size_bytes, source_archive_sha256, package_duration_ms,
+			connector_api_calls, created_at, module_address
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''))`
+	_, err = r.db.ExecContext(ctx, query,
+		m.ModuleVersionID, linkID, m.TagName, m.CommitSHA,
+		m.SourceArchiveSizeBytes, m.SourceArchiveSHA256, m.PackageDurationMs,
+		calls, m.CreatedAt, m.ModuleAddress,
+	)
+	return err
+}
+
+const publishMetadataSelect = `
+	SELECT pm.module_version_id, mv.version, pm.tag_name, pm.commit_sha,
+	       pm.source_archive_size_bytes, pm.source_archive_sha256, pm.package_duration_ms,
+	       pm.connector_api_calls, pm.created_at, COALESCE(pm.module_address, '')
+	FROM module_version_publish_metadata pm
+	JOIN module_versions mv ON mv.id = pm.module_version_id`
+
+// GetLatestPublishMetadata returns the metadata of the most recent version
+// published from the link, or nil when none has been.
+func (r *SCMRepository) GetLatestPublishMetadata(ctx context.Context, linkID uuid.UUID) (*scm.PublishMetadata, error) {
+	rows, err := r.db.QueryContext(ctx, publishMetadataSelect+`
+		WHERE pm.module_scm_repo_id = $1
+		ORDER BY pm.created_at DESC
+		LIMIT 1`, linkID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := scanPublishMetadata(rows)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// ListPublishMetadata returns the publish metadata of the given versions,
+// keyed by version ID. Versions without any are absent.
+func (r *SCMRepository) ListPublishMetadata(ctx context.Context, versionIDs []uuid.UUID) (map[uuid.UUID]*scm.PublishMetadata, error) {
+	byVersion := make(map[uuid.UUID]*scm.PublishMetadata, len(versionIDs))
+	if len(versionIDs) == 0 {
+		return byVersion, nil
+	}
+	ids := make([]string, len(versionIDs))
+	for i, id := range versionIDs {
+		ids[i] = id.String()
+	}
+	rows, err := r.db.QueryContext(ctx, publishMetadataSelect+`
+		WHERE pm.module_version_id = ANY($1::uuid[])`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	list, err := scanPublishMetadata(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range list {
+		byVersion[m.ModuleVersionID] = m
+	}
+	return byVersion, nil
+}
+
+func scanPublishMetadata(rows *sql.Rows) ([]*scm.PublishMetadata, error) {
+	defer rows.Close()
+	var list []*scm.PublishMetadata
+	for rows.Next() {
+		m := &scm.PublishMetadata{}
+		var calls []byte
+		if err := rows.Scan(&m.ModuleVersionID, &m.Version, &m.TagName, &m.CommitSHA,
+			&m.SourceArchiveSizeBytes, &m.SourceArchiveSHA256, &m.PackageDurationMs,
+			&calls, &m.CreatedAt, &m.ModuleAddress); err != nil {
+			return nil, err
+		}
+		m.ConnectorAPICalls = []string{}
+		if len(calls) > 0 {
+			if err := json.Unmarshal(calls, &m.ConnectorAPICalls); err != nil {
+				return nil, err
+			}
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
 // Tag Immutability Alerts
 
 // CreateImmutabilityAlert creates a tag immutability violation alert
@@ -438,3 +633,174 @@ func (r *SCMRepository) MarkWebhookForRetry(ctx context.Context, id uuid.UUID, n
 	_, err := r.db.ExecContext(ctx, query, id, nextRetryAt)
 	return err
 }
+
+// SCM Publish Tasks
+
+// EnqueuePublishTask queues task for the scm-publish-queue job, filling in its
+// ID, status and timestamps. A task with a DeliveryID the link already queued
+// is not queued again: task is filled in from the existing one instead, so a
+// redelivery during an in-flight publish does not run it twice.
+func (r *SCMRepository) EnqueuePublishTask(ctx context.Context, task *scm.PublishTask) error {
+	query := `
+		INSERT INTO scm_publish_tasks (module_scm_repo_id, webhook_event_id, tag_name, commit_sha, max_attempts, delivery_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (module_scm_repo_id, delivery_id) WHERE delivery_id IS NOT NULL DO NOTHING
+		RETURNING id, status, next_attempt_at, created_at, updated_at`
+	err := r.db.QueryRowContext(ctx, query, task.ModuleSCMRepoID, task.WebhookEventID, task.TagName,
+		task.CommitSHA, task.MaxAttempts, task.DeliveryID).
+		Scan(&task.ID, &task.Status, &task.NextAttemptAt, &task.CreatedAt, &task.UpdatedAt)
+	if err != sql.ErrNoRows || task.DeliveryID == nil {
+		return err
+	}
+	return r.db.GetContext(ctx, task,
+		`SELECT * FROM scm_publish_tasks WHERE module_scm_repo_id = $1 AND delivery_id = $2`,
+		task.ModuleSCMRepoID, *task.DeliveryID)
+}
+
+// EnqueueBackfill queues the tasks of a tag backfill in one transaction, so
+// the backfill is queued whole or not at all, filling in each task's ID,
+// status and timestamps. Each task carries its BackfillID, BackfillSeq and
+// NextAttemptAt.
+func (r *SCMRepository) EnqueueBackfill(ctx context.Context, tasks []*scm.PublishTask) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	query := `
+		INSERT INTO scm_publish_tasks (module_scm_repo_id, tag_name, commit_sha, max_attempts, next_attempt_at, backfill_id, backfill_seq)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, next_attempt_at, created_at, updated_at`
+	for _, task := range tasks {
+		err := tx.QueryRowContext(ctx, query, task.ModuleSCMRepoID, task.TagName, task.CommitSHA,
+			task.MaxAttempts, task.NextAttemptAt, task.BackfillID, task.BackfillSeq).
+			Scan(&task.ID, &task.Status, &task.NextAttemptAt, &task.CreatedAt, &task.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListPendingPublishTags returns the tags of the link linkID that have a
+// queued or running publish task.
+func (r *SCMRepository) ListPendingPublishTags(ctx context.Context, linkID uuid.UUID) (map[string]bool, error) {
+	var tags []string
+	query := `
+		SELECT DISTINCT tag_name FROM scm_publish_tasks
+		WHERE module_scm_repo_id = $1 AND status IN ('queued', 'running')`
+	if err := r.db.SelectContext(ctx, &tags, query, linkID); err != nil {
+		return nil, err
+	}
+	pending := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		pending[t] = true
+	}
+	return pending, nil
+}
+
+// ListBackfillTasks returns the tasks of a tag backfill of the link linkID in
+// the order it queued them; empty when the link has no such backfill.
+func (r *SCMRepository) ListBackfillTasks(ctx context.Context, linkID, backfillID uuid.UUID) ([]*scm.PublishTask, error) {
+	tasks := []*scm.PublishTask{}
+	query := `
+		SELECT * FROM scm_publish_tasks
+		WHERE module_scm_repo_id = $1 AND backfill_id = $2
+		ORDER BY backfill_seq`
+	if err := r.db.SelectContext(ctx, &tasks, query, linkID, backfillID); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ClaimPublishTask marks the next due task running for lease and returns it,
+// or nil when none is due. A due task is a queued one whose next attempt has
+// come, or a running one whose lease expired because the replica running it
+// stopped. Each claim counts as an attempt; SKIP LOCKED lets replicas claim
+// concurrently without taking the same task.
+func (r *SCMRepository) ClaimPublishTask(ctx context.Context, lease time.Duration) (*scm.PublishTask, error) {
+	var task scm.PublishTask
+	query := `
+		UPDATE scm_publish_tasks SET
+			status = 'running', attempts = attempts + 1,
+			started_at = NOW(), locked_until = NOW() + $1 * INTERVAL '1 second', updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM scm_publish_tasks
+			WHERE (status = 'queued' AND next_attempt_at <= NOW())
+			   OR (status = 'running' AND locked_until < NOW())
+			ORDER BY next_attempt_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+	err := r.db.GetContext(ctx, &task, query, int64(lease.Seconds()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FinishPublishTask records a terminal status (succeeded, skipped or failed)
+// for a task.
+func (r *SCMRepository) FinishPublishTask(ctx context.Context, id uuid.UUID, status scm.PublishTaskStatus, message *string, versionID *uuid.UUID) error {
+	query := `
+		UPDATE scm_publish_tasks SET
+			status = $2, last_error = $3, result_version_id = $4,
+			locked_until = NULL, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, status, message, versionID)
+	return err
+}
+
+// RequeuePublishTask puts a task whose attempt failed back in the queue for
+// another attempt at nextAttemptAt.
+func (r *SCMRepository) RequeuePublishTask(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE scm_publish_tasks SET
+			status = 'queued', last_error = $2, next_attempt_at = $3,
+			locked_until = NULL, updated_at = NOW()
+		WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, lastError, nextAttemptAt)
+	return err
+}
+
+// GetPublishTask retrieves a task of the link linkID, or nil when it has no
+// task with that ID.
+func (r *SCMRepository) GetPublishTask(ctx context.Context, linkID, id uuid.UUID) (*scm.PublishTask, error) {
+	var task scm.PublishTask
+	query := `SELECT * FROM scm_publish_tasks WHERE id = $1 AND module_scm_repo_id = $2`
+	err := r.db.GetContext(ctx, &task, query, id, linkID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ListPublishTasksForEvents returns the tasks queued by the given webhook
+// events, keyed by event ID.
+func (r *SCMRepository) ListPublishTasksForEvents(ctx context.Context, eventIDs []uuid.UUID) (map[uuid.UUID]*scm.PublishTask, error) {
+	byEvent := make(map[uuid.UUID]*scm.PublishTask, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return byEvent, nil
+	}
+	ids := make([]string, len(eventIDs))
+	for i, id := range eventIDs {
+		ids[i] = id.String()
+	}
+	var tasks []*scm.PublishTask
+	query := `SELECT * FROM scm_publish_tasks WHERE webhook_event_id = ANY($1::uuid[])`
+	if err := r.db.SelectContext(ctx, &tasks, query, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		byEvent[*t.WebhookEventID] = t
+	}
+	return byEvent, nil
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -501,6 +502,76 @@ func TestSCMUpdateModuleSourceRepo_Success(t *testing.T) {
 	}
 }
 
+func TestSCMSetModuleSourceRepoPublisher_ClearsTokenFlag(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	linkID, userID := uuid.New(), uuid.New()
+	mock.ExpectExec("UPDATE module_scm_repos SET.*publishing_user_id = \\$2, token_invalid_at = NULL").
+		WithArgs(linkID, userID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetModuleSourceRepoPublisher(context.Background(), linkID, userID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSCMSetModuleSourceRepoTokenInvalid(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	linkID := uuid.New()
+	now := time.Now()
+	mock.ExpectExec("UPDATE module_scm_repos SET token_invalid_at").
+		WithArgs(linkID, &now).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE module_scm_repos SET token_invalid_at").
+		WithArgs(linkID, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetModuleSourceRepoTokenInvalid(context.Background(), linkID, &now); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := repo.SetModuleSourceRepoTokenInvalid(context.Background(), linkID, nil); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+}
+
+func TestSCMRotateModuleSourceRepoWebhookSecret(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	secret, url, oldURL, hookID := "new-secret", "https://r.example.com/webhooks/scm/x", "https://r.example.com/webhooks/scm/x/old", "hook-2"
+	grace := time.Now().Add(24 * time.Hour)
+	link := &scm.ModuleSourceRepoRecord{
+		ID:                      uuid.New(),
+		WebhookSecret:           &secret,
+		WebhookURL:              &url,
+		WebhookID:               &hookID,
+		WebhookEnabled:          true,
+		PreviousWebhookURL:      &oldURL,
+		WebhookSecretGraceUntil: &grace,
+	}
+	mock.ExpectExec("UPDATE module_scm_repos SET.*webhook_secret = \\$2.*webhook_secret_grace_until = \\$8").
+		WithArgs(link.ID, &secret, &url, &hookID, true, nil, &oldURL, &grace, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RotateModuleSourceRepoWebhookSecret(context.Background(), link); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSCMGetLatestWebhookLogTime(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	linkID := uuid.New()
+	at := time.Now().Add(-time.Hour)
+	mock.ExpectQuery("SELECT MAX\\(created_at\\) FROM scm_webhook_events").
+		WithArgs(linkID).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(at))
+	mock.ExpectQuery("SELECT MAX\\(created_at\\) FROM scm_webhook_events").
+		WithArgs(linkID).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+
+	got, err := repo.GetLatestWebhookLogTime(context.Background(), linkID)
+	if err != nil || got == nil || !got.Equal(at) {
+		t.Fatalf("GetLatestWebhookLogTime = %v, %v; want %v", got, err, at)
+	}
+	got, err = repo.GetLatestWebhookLogTime(context.Background(), linkID)
+	if err != nil || got != nil {
+		t.Errorf("no events: got %v, %v; want nil, nil", got, err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // DeleteModuleSourceRepo
 // ---------------------------------------------------------------------------
@@ -538,6 +609,23 @@ func TestSCMCreateWebhookLog_Success(t *testing.T) {
 	}
 }
 
+func TestSCMCreateWebhookLog_DuplicateDelivery(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	mock.ExpectExec("INSERT INTO scm_webhook_events.*ON CONFLICT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	delivery := "72d3162e-cc78-11e3-81ab-4c9367dc0958"
+	log := &scm.SCMWebhookLogRecord{
+		ID:         uuid.New(),
+		DeliveryID: &delivery,
+		Payload:    map[string]interface{}{},
+		Headers:    map[string]interface{}{},
+	}
+	if err := repo.CreateWebhookLog(context.Background(), log); !errors.Is(err, ErrDuplicateWebhookDelivery) {
+		t.Errorf("err = %v, want ErrDuplicateWebhookDelivery", err)
+	}
+}
+
 func TestSCMCreateWebhookLog_Error(t *testing.T) {
 	repo, mock := newSCMRepo(t)
 	mock.ExpectExec("INSERT INTO scm_webhook_events").
@@ -636,6 +724,22 @@ func TestSCMListWebhookLogs_Empty(t *testing.T) {
 	}
 }
 
+func TestSCMListWebhookLogsAfter(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	repoID := uuid.New()
+	after := Keyset{CreatedAt: time.Now().UTC(), ID: uuid.NewString()}
+	mock.ExpectQuery(`WHERE module_scm_repo_id = \$1 AND \(created_at, id\) < \(\$2, \$3::uuid\) ORDER BY created_at DESC, id DESC LIMIT \$4`).
+		WithArgs(repoID, after.CreatedAt, after.ID, 51).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, err := repo.ListWebhookLogsAfter(context.Background(), repoID, &after, 51); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // UpdateWebhookLogState
 // ---------------------------------------------------------------------------
@@ -907,3 +1011,297 @@ func TestSCMMarkWebhookForRetry_DBError(t *testing.T) {
 		t.Error("expected error, got nil")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Publish metadata
+// ---------------------------------------------------------------------------
+
+var publishMetadataCols = []string{
+	"module_version_id", "version", "tag_name", "commit_sha",
+	"source_archive_size_bytes", "source_archive_sha256", "package_duration_ms",
+	"connector_api_calls", "created_at", "module_address",
+}
+
+func TestSCMCreatePublishMetadata_Success(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	meta := &scm.PublishMetadata{
+		ModuleVersionID:   uuid.New(),
+		TagName:           "v1.0.0",
+		CommitSHA:         "abc123",
+		ConnectorAPICalls: []string{"FetchTagByName", "DownloadSourceArchive"},
+		CreatedAt:         time.Now(),
+	}
+	mock.ExpectExec("INSERT INTO module_version_publish_metadata").
+		WithArgs(meta.ModuleVersionID, sqlmock.AnyArg(), "v1.0.0", "abc123", int64(0), "", int64(0),
+			[]byte(`["FetchTagByName","DownloadSourceArchive"]`), sqlmock.AnyArg(), "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.CreatePublishMetadata(context.Background(), uuid.New(), meta); err != nil {
+		t.Fatalf("CreatePublishMetadata: %v", err)
+	}
+}
+
+func TestSCMGetLatestPublishMetadata_Found(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	versionID := uuid.New()
+	mock.ExpectQuery("SELECT.*FROM module_version_publish_metadata.*ORDER BY pm.created_at DESC").
+		WillReturnRows(sqlmock.NewRows(publishMetadataCols).
+			AddRow(versionID, "1.0.0", "v1.0.0", "abc123", int64(2048), "deadbeef", int64(150), []byte(`["DownloadSourceArchive"]`), time.Now(), "registry.example.com/hashicorp/vpc/aws"))
+
+	got, err := repo.GetLatestPublishMetadata(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("GetLatestPublishMetadata: %v", err)
+	}
+	if got == nil || got.ModuleVersionID != versionID || got.Version != "1.0.0" || got.SourceArchiveSizeBytes != 2048 {
+		t.Fatalf("got %+v", got)
+	}
+	if len(got.ConnectorAPICalls) != 1 || got.ConnectorAPICalls[0] != "DownloadSourceArchive" {
+		t.Errorf("ConnectorAPICalls = %v", got.ConnectorAPICalls)
+	}
+	if got.ModuleAddress != "registry.example.com/hashicorp/vpc/aws" {
+		t.Errorf("ModuleAddress = %q", got.ModuleAddress)
+	}
+}
+
+func TestSCMGetLatestPublishMetadata_None(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	mock.ExpectQuery("SELECT.*FROM module_version_publish_metadata").
+		WillReturnRows(sqlmock.NewRows(publishMetadataCols))
+
+	got, err := repo.GetLatestPublishMetadata(context.Background(), uuid.New())
+	if err != nil || got != nil {
+		t.Errorf("GetLatestPublishMetadata = %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestSCMListPublishMetadata_KeyedByVersion(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	withMeta, without := uuid.New(), uuid.New()
+	mock.ExpectQuery("SELECT.*FROM module_version_publish_metadata.*ANY").
+		WillReturnRows(sqlmock.NewRows(publishMetadataCols).
+			AddRow(withMeta, "1.0.0", "v1.0.0", "abc123", int64(1), "", int64(1), []byte(`[]`), time.Now(), ""))
+
+	got, err := repo.ListPublishMetadata(context.Background(), []uuid.UUID{withMeta, without})
+	if err != nil {
+		t.Fatalf("ListPublishMetadata: %v", err)
+	}
+	if got[withMeta] == nil || got[without] != nil {
+		t.Errorf("ListPublishMetadata = %v", got)
+	}
+}
+
+func TestSCMListPublishMetadata_EmptySkipsQuery(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	got, err := repo.ListPublishMetadata(context.Background(), nil)
+	if err != nil || len(got) != 0 {
+		t.Errorf("ListPublishMetadata = %v, %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// SCM publish tasks
+// ---------------------------------------------------------------------------
+
+var publishTaskCols = []string{
+	"id", "module_scm_repo_id", "webhook_event_id", "tag_name", "commit_sha",
+	"status", "attempts", "max_attempts", "next_attempt_at", "locked_until",
+	"last_error", "result_version_id", "started_at", "completed_at", "created_at", "updated_at",
+}
+
+func TestSCMEnqueuePublishTask(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	linkID, eventID, taskID := uuid.New(), uuid.New(), uuid.New()
+	sha := "abc123"
+	mock.ExpectQuery("INSERT INTO scm_publish_tasks.*RETURNING id, status").
+		WithArgs(linkID, &eventID, "v1.2.0", &sha, 4, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "next_attempt_at", "created_at", "updated_at"}).
+			AddRow(taskID, "queued", time.Now(), time.Now(), time.Now()))
+
+	task := &scm.PublishTask{ModuleSCMRepoID: linkID, WebhookEventID: &eventID, TagName: "v1.2.0", CommitSHA: &sha, MaxAttempts: 4}
+	if err := repo.EnqueuePublishTask(context.Background(), task); err != nil {
+		t.Fatalf("EnqueuePublishTask: %v", err)
+	}
+	if task.ID != taskID || task.Status != scm.PublishTaskQueued {
+		t.Errorf("task = %+v, want the returned id and queued status", task)
+	}
+}
+
+func TestSCMEnqueuePublishTask_DuplicateDelivery(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	linkID, existingID := uuid.New(), uuid.New()
+	delivery := "72d3162e-cc78-11e3-81ab-4c9367dc0958"
+	mock.ExpectQuery("INSERT INTO scm_publish_tasks.*ON CONFLICT.*DO NOTHING").
+		WithArgs(linkID, nil, "v1.2.0", nil, 1, &delivery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "next_attempt_at", "created_at", "updated_at"}))
+	mock.ExpectQuery("SELECT \\* FROM scm_publish_tasks WHERE module_scm_repo_id = \\$1 AND delivery_id = \\$2").
+		WithArgs(linkID, delivery).
+		WillReturnRows(sqlmock.NewRows(append(append([]string{}, publishTaskCols...), "delivery_id")).AddRow(
+			existingID, linkID, nil, "v1.2.0", nil,
+			"running", 1, 1, time.Now(), time.Now().Add(15*time.Minute),
+			nil, nil, time.Now(), nil, time.Now(), time.Now(), delivery))
+
+	task := &scm.PublishTask{ModuleSCMRepoID: linkID, TagName: "v1.2.0", MaxAttempts: 1, DeliveryID: &delivery}
+	if err := repo.EnqueuePublishTask(context.Background(), task); err != nil {
+		t.Fatalf("EnqueuePublishTask: %v", err)
+	}
+	if task.ID != existingID || task.Status != scm.PublishTaskRunning {
+		t.Errorf("task = %+v, want the in-flight task for the delivery", task)
+	}
+}
+
+func TestSCMClaimPublishTask(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	taskID := uuid.New()
+	mock.ExpectQuery(`UPDATE scm_publish_tasks SET.*attempts = attempts \+ 1.*locked_until < NOW\(\).*FOR UPDATE SKIP LOCKED`).
+		WithArgs(int64(900)).
+		WillReturnRows(sqlmock.NewRows(publishTaskCols).AddRow(
+			taskID, uuid.New(), nil, "v1.2.0", nil,
+			"running", 1, 4, time.Now(), time.Now().Add(15*time.Minute),
+			nil, nil, time.Now(), nil, time.Now(), time.Now()))
+
+	task, err := repo.ClaimPublishTask(context.Background(), 15*time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimPublishTask: %v", err)
+	}
+	if task == nil || task.ID != taskID || task.Status != scm.PublishTaskRunning || task.Attempts != 1 {
+		t.Errorf("task = %+v", task)
+	}
+
+	mock.ExpectQuery("UPDATE scm_publish_tasks SET").WillReturnRows(sqlmock.NewRows(publishTaskCols))
+	if task, err := repo.ClaimPublishTask(context.Background(), 15*time.Minute); task != nil || err != nil {
+		t.Errorf("empty queue: %+v, %v; want nil, nil", task, err)
+	}
+}
+
+func TestSCMFinishPublishTask(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	taskID, versionID := uuid.New(), uuid.New()
+	mock.ExpectExec("UPDATE scm_publish_tasks SET.*completed_at = NOW").
+		WithArgs(taskID, scm.PublishTaskSucceeded, nil, &versionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.FinishPublishTask(context.Background(), taskID, scm.PublishTaskSucceeded, nil, &versionID); err != nil {
+		t.Fatalf("FinishPublishTask: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSCMListPublishTasksForEvents_KeyedByEvent(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	queued, plain := uuid.New(), uuid.New()
+	mock.ExpectQuery("SELECT.*FROM scm_publish_tasks WHERE webhook_event_id = ANY").
+		WillReturnRows(sqlmock.NewRows(publishTaskCols).AddRow(
+			uuid.New(), uuid.New(), queued, "v1.2.0", nil,
+			"failed", 4, 4, time.Now(), nil,
+			"failed to publish version: download failed", nil, time.Now(), time.Now(), time.Now(), time.Now()))
+
+	got, err := repo.ListPublishTasksForEvents(context.Background(), []uuid.UUID{queued, plain})
+	if err != nil {
+		t.Fatalf("ListPublishTasksForEvents: %v", err)
+	}
+	if got[queued] == nil || got[queued].Status != scm.PublishTaskFailed || got[plain] != nil {
+		t.Errorf("ListPublishTasksForEvents = %v", got)
+	}
+
+	if got, err := repo.ListPublishTasksForEvents(context.Background(), nil); err != nil || len(got) != 0 {
+		t.Errorf("no events: %v, %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSCMEnqueueBackfill(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	linkID, backfillID := uuid.New(), uuid.New()
+	first, second := uuid.New(), uuid.New()
+	start := time.Now()
+	seq0, seq1 := 0, 1
+	tasks := []*scm.PublishTask{
+		{ModuleSCMRepoID: linkID, BackfillID: &backfillID, BackfillSeq: &seq0, TagName: "v1.0.0", MaxAttempts: 2, NextAttemptAt: start},
+		{ModuleSCMRepoID: linkID, BackfillID: &backfillID, BackfillSeq: &seq1, TagName: "v1.1.0", MaxAttempts: 2, NextAttemptAt: start.Add(10 * time.Second)},
+	}
+	retCols := []string{"id", "status", "next_attempt_at", "created_at", "updated_at"}
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO scm_publish_tasks.*backfill_id, backfill_seq").
+		WithArgs(linkID, "v1.0.0", nil, 2, start, &backfillID, &seq0).
+		WillReturnRows(sqlmock.NewRows(retCols).AddRow(first, "queued", start, start, start))
+	mock.ExpectQuery("INSERT INTO scm_publish_tasks.*backfill_id, backfill_seq").
+		WithArgs(linkID, "v1.1.0", nil, 2, start.Add(10*time.Second), &backfillID, &seq1).
+		WillReturnRows(sqlmock.NewRows(retCols).AddRow(second, "queued", start.Add(10*time.Second), start, start))
+	mock.ExpectCommit()
+
+	if err := repo.EnqueueBackfill(context.Background(), tasks); err != nil {
+		t.Fatalf("EnqueueBackfill: %v", err)
+	}
+	if tasks[0].ID != first || tasks[1].ID != second || tasks[1].Status != scm.PublishTaskQueued {
+		t.Errorf("tasks = %+v, %+v; want the returned ids and queued status", tasks[0], tasks[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSCMEnqueueBackfill_RollsBackOnError(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO scm_publish_tasks").WillReturnError(errors.New("insert failed"))
+	mock.ExpectRollback()
+
+	tasks := []*scm.PublishTask{{ModuleSCMRepoID: uuid.New(), TagName: "v1.0.0", MaxAttempts: 1}}
+	if err := repo.EnqueueBackfill(context.Background(), tasks); err == nil {
+		t.Fatal("EnqueueBackfill: want error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSCMListPendingPublishTags(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	linkID := uuid.New()
+	mock.ExpectQuery("SELECT DISTINCT tag_name FROM scm_publish_tasks.*status IN \\('queued', 'running'\\)").
+		WithArgs(linkID).
+		WillReturnRows(sqlmock.NewRows([]string{"tag_name"}).AddRow("v1.0.0").AddRow("v1.1.0"))
+
+	got, err := repo.ListPendingPublishTags(context.Background(), linkID)
+	if err != nil {
+		t.Fatalf("ListPendingPublishTags: %v", err)
+	}
+	if len(got) != 2 || !got["v1.0.0"] || !got["v1.1.0"] {
+		t.Errorf("ListPendingPublishTags = %v", got)
+	}
+}
+
+func TestSCMListBackfillTasks(t *testing.T) {
+	repo, mock := newSCMRepo(t)
+	linkID, backfillID := uuid.New(), uuid.New()
+	cols := append(append([]string{}, publishTaskCols...), "backfill_id", "backfill_seq")
+	mock.ExpectQuery("SELECT \\* FROM scm_publish_tasks.*backfill_id = \\$2.*ORDER BY backfill_seq").
+		WithArgs(linkID, backfillID).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(uuid.New(), linkID, nil, "v1.0.0", nil,
+				"succeeded", 1, 1, time.Now(), nil,
+				nil, uuid.New(), time.Now(), time.Now(), time.Now(), time.Now(), backfillID, 0).
+			AddRow(uuid.New(), linkID, nil, "v1.1.0", nil,
+				"queued", 0, 1, time.Now(), nil,
+				nil, nil, nil, nil, time.Now(), time.Now(), backfillID, 1))
+
+	got, err := repo.ListBackfillTasks(context.Background(), linkID, backfillID)
+	if err != nil {
+		t.Fatalf("ListBackfillTasks: %v", err)
+	}
+	if len(got) != 2 || got[0].TagName != "v1.0.0" || got[1].Status != scm.PublishTaskQueued ||
+		got[1].BackfillID == nil || *got[1].BackfillID != backfillID {
+		t.Errorf("ListBackfillTasks = %+v", got)
+	}
+
+	mock.ExpectQuery("SELECT \\* FROM scm_publish_tasks").WillReturnRows(sqlmock.NewRows(cols))
+	if got, err := repo.ListBackfillTasks(context.Background(), linkID, uuid.New()); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("unknown backfill: %v, %v; want an empty slice", got, err)
+	}
+}
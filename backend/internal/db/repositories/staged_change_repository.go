@@ -0,0 +1,157 @@
+// Package repositories - staged_change_repository.go persists the two-person
+// rule setting and the staged changes awaiting a second admin's review.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// ErrStagedChangeNotPending is returned by Review when the change has already
+// been reviewed.
+var ErrStagedChangeNotPending = errors.New("staged change is not pending")
+
+// StagedChangeRepository handles staged_changes and the two-person rule
+// setting in system_settings.
+type StagedChangeRepository struct {
+	db *sql.DB
+}
+
+// NewStagedChangeRepository creates a new staged change repository.
+func NewStagedChangeRepository(db *sql.DB) *StagedChangeRepository {
+	return &StagedChangeRepository{db: db}
+}
+
+// TwoPersonRuleEnabled reports whether sensitive admin changes must be
+// approved by a second admin. A missing system_settings row reads as off.
+func (r *StagedChangeRepository) TwoPersonRuleEnabled(ctx context.Context) (bool, error) {
+	var enabled bool
+	err := r.db.QueryRowContext(ctx, `SELECT two_person_rule_enabled FROM system_settings WHERE id = 1`).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get two-person rule setting: %w", err)
+	}
+	return enabled, nil
+}
+
+// SetTwoPersonRule turns the two-person rule on or off.
+func (r *StagedChangeRepository) SetTwoPersonRule(ctx context.Context, enabled bool) error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE system_settings SET two_person_rule_enabled = $1, updated_at = NOW() WHERE id = 1`,
+		enabled); err != nil {
+		return fmt.Errorf("failed to set two-person rule setting: %w", err)
+	}
+	return nil
+}
+
+const stagedChangeColumns = `id, resource_type, resource_id, action, before_state, after_state, status,
+	requested_by::text, reviewed_by::text, review_comment, apply_error, created_at, reviewed_at`
+
+func scanStagedChange(row interface{ Scan(...interface{}) error }) (*models.StagedChange, error) {
+	c := &models.StagedChange{}
+	var before, after []byte
+	err := row.Scan(&c.ID, &c.ResourceType, &c.ResourceID, &c.Action, &before, &after, &c.Status,
+		&c.RequestedBy, &c.ReviewedBy, &c.ReviewComment, &c.ApplyError, &c.CreatedAt, &c.ReviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	c.Before, c.After = before, after
+	return c, nil
+}
+
+// nullableJSON stores an empty document as SQL NULL.
+func nullableJSON(doc []byte) interface{} {
+	if len(doc) == 0 {
+		return nil
+	}
+	return string(doc)
+}
+
+// Create stores a pending change and fills in its ID, status and creation
+// time.
+func (r *StagedChangeRepository) Create(ctx context.Context, change *models.StagedChange) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO staged_changes (resource_type, resource_id, action, before_state, after_state, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, status, created_at`,
+		change.ResourceType, change.ResourceID, change.Action,
+		nullableJSON(change.Before), nullableJSON(change.After), change.RequestedBy,
+	).Scan(&change.ID, &change.Status, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create staged change: %w", err)
+	}
+	return nil
+}
+
+// Get returns a staged change by ID, or nil when there is none.
+func (r *StagedChangeRepository) Get(ctx context.Context, id string) (*models.StagedChange, error) {
+	c, err := scanStagedChange(r.db.QueryRowContext(ctx, `
+		SELECT `+stagedChangeColumns+`
+		FROM staged_changes
+		WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged change: %w", err)
+	}
+	return c, nil
+}
+
+// List returns staged changes newest first, only those with status when it
+// is non-empty.
+func (r *StagedChangeRepository) List(ctx context.Context, status string) ([]*models.StagedChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+stagedChangeColumns+`
+		FROM staged_changes
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC`, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []*models.StagedChange{}
+	for rows.Next() {
+		c, err := scanStagedChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan staged change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// Review records reviewerID's decision on a pending change and returns the
+// updated change. Only one review of a change can succeed; later ones get
+// ErrStagedChangeNotPending.
+func (r *StagedChangeRepository) Review(ctx context.Context, id, status, reviewerID string, comment *string) (*models.StagedChange, error) {
+	c, err := scanStagedChange(r.db.QueryRowContext(ctx, `
+		UPDATE staged_changes
+		SET status = $2, reviewed_by = $3, review_comment = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING `+stagedChangeColumns, id, status, reviewerID, comment))
+	if err == sql.ErrNoRows {
+		return nil, ErrStagedChangeNotPending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review staged change: %w", err)
+	}
+	return c, nil
+}
+
+// MarkFailed records that applying an approved change failed.
+func (r *StagedChangeRepository) MarkFailed(ctx context.Context, id, applyErr string) error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE staged_changes SET status = 'failed', apply_error = $2 WHERE id = $1`,
+		id, applyErr); err != nil {
+		return fmt.Errorf("failed to mark staged change failed: %w", err)
+	}
+	return nil
+}
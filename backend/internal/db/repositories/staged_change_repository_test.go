@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var stagedChangeCols = []string{
+	"id", "resource_type", "resource_id", "action", "before_state", "after_state", "status",
+	"requested_by", "reviewed_by", "review_comment", "apply_error", "created_at", "reviewed_at",
+}
+
+func newStagedChangeRepo(t *testing.T) (*StagedChangeRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewStagedChangeRepository(db), mock
+}
+
+func TestStagedChangeRepository_TwoPersonRuleNoSettingsRow(t *testing.T) {
+	repo, mock := newStagedChangeRepo(t)
+	mock.ExpectQuery("SELECT two_person_rule_enabled FROM system_settings").WillReturnError(sql.ErrNoRows)
+
+	enabled, err := repo.TwoPersonRuleEnabled(context.Background())
+	if err != nil || enabled {
+		t.Errorf("TwoPersonRuleEnabled = %v, %v; want false, nil", enabled, err)
+	}
+}
+
+func TestStagedChangeRepository_CreateStoresDeleteWithoutAfter(t *testing.T) {
+	repo, mock := newStagedChangeRepo(t)
+	created := time.Now()
+	mock.ExpectQuery("INSERT INTO staged_changes").
+		WithArgs(models.StagedResourceMirrorPolicy, "policy-1", models.StagedActionDelete, `{"name":"p"}`, nil, "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow("change-1", "pending", created))
+
+	resourceID, requester := "policy-1", "user-1"
+	change := &models.StagedChange{
+		ResourceType: models.StagedResourceMirrorPolicy,
+		ResourceID:   &resourceID,
+		Action:       models.StagedActionDelete,
+		Before:       []byte(`{"name":"p"}`),
+		RequestedBy:  &requester,
+	}
+	if err := repo.Create(context.Background(), change); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if change.ID != "change-1" || change.Status != models.StagedChangeStatusPending {
+		t.Errorf("change = %+v", change)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStagedChangeRepository_GetNotFound(t *testing.T) {
+	repo, mock := newStagedChangeRepo(t)
+	mock.ExpectQuery("SELECT .* FROM staged_changes").WillReturnError(sql.ErrNoRows)
+
+	change, err := repo.Get(context.Background(), "change-1")
+	if err != nil || change != nil {
+		t.Errorf("Get = %+v, %v; want nil, nil", change, err)
+	}
+}
+
+func TestStagedChangeRepository_ReviewAlreadyReviewed(t *testing.T) {
+	repo, mock := newStagedChangeRepo(t)
+	mock.ExpectQuery("UPDATE staged_changes").WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.Review(context.Background(), "change-1", models.StagedChangeStatusApproved, "user-2", nil)
+	if !errors.Is(err, ErrStagedChangeNotPending) {
+		t.Errorf("Review error = %v, want ErrStagedChangeNotPending", err)
+	}
+}
+
+func TestStagedChangeRepository_ReviewReturnsChange(t *testing.T) {
+	repo, mock := newStagedChangeRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("UPDATE staged_changes").
+		WithArgs("change-1", models.StagedChangeStatusRejected, "user-2", nil).
+		WillReturnRows(sqlmock.NewRows(stagedChangeCols).AddRow(
+			"change-1", models.StagedResourceRoleTemplate, nil, models.StagedActionCreate, nil, []byte(`{"name":"ops"}`),
+			models.StagedChangeStatusRejected, "user-1", "user-2", nil, nil, now, now))
+
+	change, err := repo.Review(context.Background(), "change-1", models.StagedChangeStatusRejected, "user-2", nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if change.Status != models.StagedChangeStatusRejected || change.ReviewedBy == nil || *change.ReviewedBy != "user-2" ||
+		string(change.After) != `{"name":"ops"}` || change.Before != nil {
+		t.Errorf("change = %+v", change)
+	}
+}
@@ -77,6 +77,7 @@ func (r *StorageConfigRepository) CreateStorageConfig(ctx context.Context, confi
 			s3_role_arn, s3_role_session_name, s3_external_id, s3_web_identity_token_file,
 			gcs_bucket, gcs_project_id, gcs_auth_method, gcs_credentials_file,
 			gcs_credentials_json_encrypted, gcs_endpoint,
+			artifactory_base_url, artifactory_repository, artifactory_access_token_encrypted,
 			created_at, updated_at, created_by, updated_by
 		) VALUES (
 			$1, $2, $3,
@@ -87,7 +88,8 @@ func (r *StorageConfigRepository) CreateStorageConfig(ctx context.Context, confi
 			$16, $17, $18, $19,
 			$20, $21, $22, $23,
 			$24, $25,
-			$26, $27, $28, $29
+			$26, $27, $28,
+			$29, $30, $31, $32
 		)`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -99,6 +101,7 @@ func (r *StorageConfigRepository) CreateStorageConfig(ctx context.Context, confi
 		config.S3RoleARN, config.S3RoleSessionName, config.S3ExternalID, config.S3WebIdentityTokenFile,
 		config.GCSBucket, config.GCSProjectID, config.GCSAuthMethod, config.GCSCredentialsFile,
 		config.GCSCredentialsJSONEncrypted, config.GCSEndpoint,
+		config.ArtifactoryBaseURL, config.ArtifactoryRepository, config.ArtifactoryAccessTokenEncrypted,
 		config.CreatedAt, config.UpdatedAt, config.CreatedBy, config.UpdatedBy,
 	)
 	return err
@@ -148,7 +151,9 @@ func (r *StorageConfigRepository) UpdateStorageConfig(ctx context.Context, confi
 			s3_web_identity_token_file = $19,
 			gcs_bucket = $20, gcs_project_id = $21, gcs_auth_method = $22,
 			gcs_credentials_file = $23, gcs_credentials_json_encrypted = $24, gcs_endpoint = $25,
-			updated_at = $26, updated_by = $27
+			artifactory_base_url = $26, artifactory_repository = $27,
+			artifactory_access_token_encrypted = $28,
+			updated_at = $29, updated_by = $30
 		WHERE id = $1`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -163,6 +168,8 @@ func (r *StorageConfigRepository) UpdateStorageConfig(ctx context.Context, confi
 		config.S3WebIdentityTokenFile,
 		config.GCSBucket, config.GCSProjectID, config.GCSAuthMethod,
 		config.GCSCredentialsFile, config.GCSCredentialsJSONEncrypted, config.GCSEndpoint,
+		config.ArtifactoryBaseURL, config.ArtifactoryRepository,
+		config.ArtifactoryAccessTokenEncrypted,
 		time.Now(), config.UpdatedBy,
 	)
 	return err
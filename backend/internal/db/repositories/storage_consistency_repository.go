@@ -0,0 +1,283 @@
+// Package repositories - storage_consistency_repository.go lists the artifacts
+// whose storage objects the consistency checker verifies, and persists its
+// runs and its findings of artifacts whose object is missing.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// storageArtifactQueries select (id, storage key, description, mirror config)
+// for each artifact type; $1 limits the rows to a random sample, and NULL
+// selects every row.
+var storageArtifactQueries = map[string]string{
+	models.ArtifactTypeModuleVersion: `
+		SELECT mv.id, mv.storage_path,
+		       m.namespace || '/' || m.name || '/' || m.system || ' ' || mv.version,
+		       NULL::uuid
+		FROM module_versions mv
+		JOIN modules m ON m.id = mv.module_id
+		ORDER BY random()
+		LIMIT $1`,
+	models.ArtifactTypeProviderPlatform: `
+		SELECT pp.id, pp.storage_path,
+		       p.namespace || '/' || p.type || ' ' || pv.version || ' ' || pp.os || '_' || pp.arch,
+		       mp.mirror_config_id
+		FROM provider_platforms pp
+		JOIN provider_versions pv ON pv.id = pp.provider_version_id
+		JOIN providers p ON p.id = pv.provider_id
+		LEFT JOIN mirrored_providers mp ON mp.provider_id = p.id
+		ORDER BY random()
+		LIMIT $1`,
+	models.ArtifactTypeTerraformVersionPlatform: `
+		SELECT tp.id, tp.storage_key,
+		       c.name || ' ' || tv.version || ' ' || tp.os || '_' || tp.arch,
+		       tv.config_id
+		FROM terraform_version_platforms tp
+		JOIN terraform_versions tv ON tv.id = tp.version_id
+		JOIN terraform_mirror_configs c ON c.id = tv.config_id
+		WHERE tp.sync_status = 'synced' AND tp.storage_key IS NOT NULL
+		ORDER BY random()
+		LIMIT $1`,
+}
+
+// StorageConsistencyRepository handles storage_consistency_runs and
+// storage_consistency_findings.
+type StorageConsistencyRepository struct {
+	db *sql.DB
+}
+
+// NewStorageConsistencyRepository creates a new storage consistency repository.
+func NewStorageConsistencyRepository(db *sql.DB) *StorageConsistencyRepository {
+	return &StorageConsistencyRepository{db: db}
+}
+
+// ListArtifacts returns the artifacts of artifactType that reference a storage
+// object: a random sample of limit rows, or every row when limit is 0.
+func (r *StorageConsistencyRepository) ListArtifacts(ctx context.Context, artifactType string, limit int) ([]models.StorageArtifact, error) {
+	query, ok := storageArtifactQueries[artifactType]
+	if !ok {
+		return nil, fmt.Errorf("unknown artifact type %q", artifactType)
+	}
+	var limitArg interface{}
+	if limit > 0 {
+		limitArg = limit
+	}
+	rows, err := r.db.QueryContext(ctx, query, limitArg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s artifacts: %w", artifactType, err)
+	}
+	defer rows.Close()
+
+	var artifacts []models.StorageArtifact
+	for rows.Next() {
+		a := models.StorageArtifact{Type: artifactType}
+		var mirrorConfigID sql.NullString
+		if err := rows.Scan(&a.ID, &a.StorageKey, &a.Description, &mirrorConfigID); err != nil {
+			return nil, fmt.Errorf("failed to scan %s artifact: %w", artifactType, err)
+		}
+		if mirrorConfigID.Valid {
+			a.MirrorConfigID = &mirrorConfigID.String
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, rows.Err()
+}
+
+// CreateRun records the start of a checker run.
+func (r *StorageConsistencyRepository) CreateRun(ctx context.Context, fullScan bool) (*models.StorageConsistencyRun, error) {
+	run := &models.StorageConsistencyRun{FullScan: fullScan}
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO storage_consistency_runs (full_scan) VALUES ($1)
+		RETURNING id, started_at`, fullScan,
+	).Scan(&run.ID, &run.StartedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage consistency run: %w", err)
+	}
+	return run, nil
+}
+
+// CompleteRun records the counts and error of a finished run and stamps its
+// completion time.
+func (r *StorageConsistencyRepository) CompleteRun(ctx context.Context, run *models.StorageConsistencyRun) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE storage_consistency_runs
+		SET completed_at = NOW(), checked = $2, missing = $3, errors = $4, error = $5
+		WHERE id = $1
+		RETURNING completed_at`,
+		run.ID, run.Checked, run.Missing, run.Errors, run.Error,
+	).Scan(&run.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete storage consistency run: %w", err)
+	}
+	return nil
+}
+
+// LatestRun returns the most recently started run, or nil when the checker has
+// never run.
+func (r *StorageConsistencyRepository) LatestRun(ctx context.Context) (*models.StorageConsistencyRun, error) {
+	run := &models.StorageConsistencyRun{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, full_scan, started_at, completed_at, checked, missing, errors, error
+		FROM storage_consistency_runs
+		ORDER BY started_at DESC
+		LIMIT 1`,
+	).Scan(&run.ID, &run.FullScan, &run.StartedAt, &run.CompletedAt, &run.Checked, &run.Missing, &run.Errors, &run.Error)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest storage consistency run: %w", err)
+	}
+	return run, nil
+}
+
+// RecordMissing records that artifact's storage object is missing. A known
+// finding has its detection time refreshed; with markBroken it is also marked
+// broken if it is not already.
+func (r *StorageConsistencyRepository) RecordMissing(ctx context.Context, artifact models.StorageArtifact, markBroken bool) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO storage_consistency_findings
+			(artifact_type, artifact_id, storage_key, severity, description, mirror_config_id, broken_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $7 THEN NOW() END)
+		ON CONFLICT (artifact_type, artifact_id) DO UPDATE SET
+			storage_key      = EXCLUDED.storage_key,
+			severity         = EXCLUDED.severity,
+			description      = EXCLUDED.description,
+			mirror_config_id = EXCLUDED.mirror_config_id,
+			last_detected_at = NOW(),
+			broken_at        = COALESCE(storage_consistency_findings.broken_at, EXCLUDED.broken_at)`,
+		artifact.Type, artifact.ID, artifact.StorageKey, artifact.Severity(), artifact.Description,
+		artifact.MirrorConfigID, markBroken,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record storage consistency finding: %w", err)
+	}
+	return nil
+}
+
+const findingColumns = `id, artifact_type, artifact_id, storage_key, severity, description,
+	mirror_config_id, first_detected_at, last_detected_at, broken_at`
+
+func scanFinding(row interface{ Scan(...interface{}) error }) (*models.StorageConsistencyFinding, error) {
+	f := &models.StorageConsistencyFinding{}
+	err := row.Scan(&f.ID, &f.ArtifactType, &f.ArtifactID, &f.StorageKey, &f.Severity, &f.Description,
+		&f.MirrorConfigID, &f.FirstDetectedAt, &f.LastDetectedAt, &f.BrokenAt)
+	return f, err
+}
+
+// ListFindings returns every open finding, critical ones first, oldest first
+// within a severity. Findings on mirrored platforms carry the upstream
+// provenance of the platform.
+func (r *StorageConsistencyRepository) ListFindings(ctx context.Context) ([]*models.StorageConsistencyFinding, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT f.id, f.artifact_type, f.artifact_id, f.storage_key, f.severity, f.description,
+		       f.mirror_config_id, f.first_detected_at, f.last_detected_at, f.broken_at,
+		       COALESCE(pp.provenance, tp.provenance),
+		       COALESCE(pp.upstream_registry, tp.upstream_registry),
+		       COALESCE(pp.upstream_download_url, tp.upstream_download_url),
+		       COALESCE(pp.upstream_shasum_url, tp.upstream_shasum_url),
+		       COALESCE(pp.fetched_at, tp.fetched_at)
+		FROM storage_consistency_findings f
+		LEFT JOIN provider_platforms pp
+		       ON f.artifact_type = 'provider_platform' AND pp.id = f.artifact_id
+		LEFT JOIN terraform_version_platforms tp
+		       ON f.artifact_type = 'terraform_version_platform' AND tp.id = f.artifact_id
+		ORDER BY f.severity = 'critical' DESC, f.first_detected_at, f.description`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage consistency findings: %w", err)
+	}
+	defer rows.Close()
+
+	findings := []*models.StorageConsistencyFinding{}
+	for rows.Next() {
+		f := &models.StorageConsistencyFinding{}
+		err := rows.Scan(&f.ID, &f.ArtifactType, &f.ArtifactID, &f.StorageKey, &f.Severity, &f.Description,
+			&f.MirrorConfigID, &f.FirstDetectedAt, &f.LastDetectedAt, &f.BrokenAt,
+			&f.Provenance, &f.UpstreamRegistry, &f.UpstreamDownloadURL, &f.UpstreamSHASumURL, &f.FetchedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan storage consistency finding: %w", err)
+		}
+		findings = append(findings, f)
+	}
+	return findings, rows.Err()
+}
+
+// GetFinding returns a finding by ID, or nil when there is none.
+func (r *StorageConsistencyRepository) GetFinding(ctx context.Context, id string) (*models.StorageConsistencyFinding, error) {
+	f, err := scanFinding(r.db.QueryRowContext(ctx, `
+		SELECT `+findingColumns+`
+		FROM storage_consistency_findings
+		WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage consistency finding: %w", err)
+	}
+	return f, nil
+}
+
+// DeleteFinding removes a finding, once its object is back or its artifact
+// has been repaired.
+func (r *StorageConsistencyRepository) DeleteFinding(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM storage_consistency_findings WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete storage consistency finding: %w", err)
+	}
+	return nil
+}
+
+// PruneFindings removes findings whose artifact row no longer exists and
+// reports how many it removed.
+func (r *StorageConsistencyRepository) PruneFindings(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM storage_consistency_findings f
+		WHERE (f.artifact_type = 'module_version'
+		       AND NOT EXISTS (SELECT 1 FROM module_versions WHERE id = f.artifact_id))
+		   OR (f.artifact_type = 'provider_platform'
+		       AND NOT EXISTS (SELECT 1 FROM provider_platforms WHERE id = f.artifact_id))
+		   OR (f.artifact_type = 'terraform_version_platform'
+		       AND NOT EXISTS (SELECT 1 FROM terraform_version_platforms
+		                       WHERE id = f.artifact_id AND sync_status = 'synced'))`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune storage consistency findings: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// SetBroken marks a finding's artifact broken, or with broken false makes it
+// downloadable again. It returns the updated finding, or nil when there is
+// no finding with that ID.
+func (r *StorageConsistencyRepository) SetBroken(ctx context.Context, id string, broken bool) (*models.StorageConsistencyFinding, error) {
+	f, err := scanFinding(r.db.QueryRowContext(ctx, `
+		UPDATE storage_consistency_findings
+		SET broken_at = CASE WHEN $2 THEN COALESCE(broken_at, NOW()) END
+		WHERE id = $1
+		RETURNING `+findingColumns, id, broken))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update storage consistency finding: %w", err)
+	}
+	return f, nil
+}
+
+// IsBroken reports whether the artifact is marked broken.
+func (r *StorageConsistencyRepository) IsBroken(ctx context.Context, artifactType, artifactID string) (bool, error) {
+	var broken bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM storage_consistency_findings
+			WHERE artifact_type = $1 AND artifact_id = $2 AND broken_at IS NOT NULL
+		)`, artifactType, artifactID,
+	).Scan(&broken)
+	if err != nil {
+		return false, fmt.Errorf("failed to check artifact state: %w", err)
+	}
+	return broken, nil
+}
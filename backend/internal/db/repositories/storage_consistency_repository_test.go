@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newStorageConsistencyRepo(t *testing.T) (*StorageConsistencyRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewStorageConsistencyRepository(db), mock
+}
+
+var findingCols = []string{"id", "artifact_type", "artifact_id", "storage_key", "severity", "description",
+	"mirror_config_id", "first_detected_at", "last_detected_at", "broken_at"}
+
+func TestStorageConsistency_ListArtifacts(t *testing.T) {
+	t.Run("sample of mirrored provider platforms", func(t *testing.T) {
+		repo, mock := newStorageConsistencyRepo(t)
+		mock.ExpectQuery("SELECT pp.id.*FROM provider_platforms pp.*LEFT JOIN mirrored_providers.*LIMIT \\$1").
+			WithArgs(50).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "storage_path", "description", "mirror_config_id"}).
+				AddRow("pp-1", "providers/hashicorp/aws/5.0.0/linux_amd64.zip", "hashicorp/aws 5.0.0 linux_amd64", "mc-1").
+				AddRow("pp-2", "providers/acme/foo/1.0.0/linux_amd64.zip", "acme/foo 1.0.0 linux_amd64", nil))
+
+		got, err := repo.ListArtifacts(context.Background(), models.ArtifactTypeProviderPlatform, 50)
+		if err != nil {
+			t.Fatalf("ListArtifacts: %v", err)
+		}
+		if len(got) != 2 || got[0].Severity() != models.ConsistencySeverityWarning || got[1].Severity() != models.ConsistencySeverityCritical {
+			t.Errorf("ListArtifacts = %+v", got)
+		}
+	})
+
+	t.Run("full scan passes no limit", func(t *testing.T) {
+		repo, mock := newStorageConsistencyRepo(t)
+		mock.ExpectQuery("FROM module_versions mv").
+			WithArgs(nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "storage_path", "description", "mirror_config_id"}))
+
+		if _, err := repo.ListArtifacts(context.Background(), models.ArtifactTypeModuleVersion, 0); err != nil {
+			t.Fatalf("ListArtifacts: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		repo, _ := newStorageConsistencyRepo(t)
+		if _, err := repo.ListArtifacts(context.Background(), "blob", 0); err == nil {
+			t.Error("ListArtifacts accepted an unknown artifact type")
+		}
+	})
+}
+
+func TestStorageConsistency_RecordMissing(t *testing.T) {
+	repo, mock := newStorageConsistencyRepo(t)
+	mirrorID := "mc-1"
+	artifact := models.StorageArtifact{
+		Type: models.ArtifactTypeTerraformVersionPlatform, ID: "tp-1", StorageKey: "terraform/1.9.0/linux_amd64.zip",
+		Description: "terraform 1.9.0 linux_amd64", MirrorConfigID: &mirrorID,
+	}
+	mock.ExpectExec("INSERT INTO storage_consistency_findings.*ON CONFLICT.*COALESCE\\(storage_consistency_findings.broken_at").
+		WithArgs(artifact.Type, artifact.ID, artifact.StorageKey, models.ConsistencySeverityWarning, artifact.Description, &mirrorID, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RecordMissing(context.Background(), artifact, true); err != nil {
+		t.Fatalf("RecordMissing: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestStorageConsistency_Runs(t *testing.T) {
+	repo, mock := newStorageConsistencyRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO storage_consistency_runs").
+		WithArgs(false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "started_at"}).AddRow("run-1", now))
+	mock.ExpectQuery("UPDATE storage_consistency_runs").
+		WithArgs("run-1", 10, 2, 1, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"completed_at"}).AddRow(now))
+	mock.ExpectQuery("SELECT .* FROM storage_consistency_runs").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "full_scan", "started_at", "completed_at", "checked", "missing", "errors", "error"}))
+
+	run, err := repo.CreateRun(context.Background(), false)
+	if err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	run.Checked, run.Missing, run.Errors = 10, 2, 1
+	if err := repo.CompleteRun(context.Background(), run); err != nil || run.CompletedAt == nil {
+		t.Fatalf("CompleteRun = %v, completed_at %v", err, run.CompletedAt)
+	}
+	if latest, err := repo.LatestRun(context.Background()); err != nil || latest != nil {
+		t.Errorf("LatestRun without runs = (%v, %v), want (nil, nil)", latest, err)
+	}
+}
+
+func TestStorageConsistency_SetBrokenAndIsBroken(t *testing.T) {
+	repo, mock := newStorageConsistencyRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("UPDATE storage_consistency_findings SET broken_at").
+		WithArgs("f-1", true).
+		WillReturnRows(sqlmock.NewRows(findingCols).AddRow("f-1", models.ArtifactTypeModuleVersion, "mv-1",
+			"modules/acme/vpc/aws/1.0.0.tar.gz", models.ConsistencySeverityCritical, "acme/vpc/aws 1.0.0", nil, now, now, now))
+	mock.ExpectQuery("UPDATE storage_consistency_findings SET broken_at").
+		WithArgs("missing", false).
+		WillReturnRows(sqlmock.NewRows(findingCols))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(models.ArtifactTypeModuleVersion, "mv-1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	f, err := repo.SetBroken(context.Background(), "f-1", true)
+	if err != nil || f == nil || f.BrokenAt == nil {
+		t.Fatalf("SetBroken = (%+v, %v), want a broken finding", f, err)
+	}
+	if f, err := repo.SetBroken(context.Background(), "missing", false); err != nil || f != nil {
+		t.Errorf("SetBroken of unknown finding = (%+v, %v), want (nil, nil)", f, err)
+	}
+	if broken, err := repo.IsBroken(context.Background(), models.ArtifactTypeModuleVersion, "mv-1"); err != nil || !broken {
+		t.Errorf("IsBroken = (%v, %v), want (true, nil)", broken, err)
+	}
+}
+
+func TestStorageConsistency_PruneFindings(t *testing.T) {
+	repo, mock := newStorageConsistencyRepo(t)
+	mock.ExpectExec("DELETE FROM storage_consistency_findings f.*NOT EXISTS").
+		WillReturnResult(driver.RowsAffected(3))
+
+	if n, err := repo.PruneFindings(context.Background()); err != nil || n != 3 {
+		t.Errorf("PruneFindings = (%d, %v), want (3, nil)", n, err)
+	}
+}
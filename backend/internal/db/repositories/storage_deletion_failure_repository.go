@@ -0,0 +1,45 @@
+// Package repositories - storage_deletion_failure_repository.go records storage
+// objects whose delete failed after the rows referencing them were removed, so
+// a garbage-collection pass can retry them.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// StorageDeletionFailureRepository handles storage_deletion_failures rows.
+type StorageDeletionFailureRepository struct {
+	db *sql.DB
+}
+
+// NewStorageDeletionFailureRepository creates a new storage deletion failure
+// repository.
+func NewStorageDeletionFailureRepository(db *sql.DB) *StorageDeletionFailureRepository {
+	return &StorageDeletionFailureRepository{db: db}
+}
+
+// Record stores f, or when its key is already recorded bumps the attempt count
+// and replaces the source and error. f's ID, Attempts and timestamps are set
+// from the stored row.
+func (r *StorageDeletionFailureRepository) Record(ctx context.Context, f *models.StorageDeletionFailure) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO storage_deletion_failures (storage_key, storage_backend, source, last_error)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (storage_key) DO UPDATE SET
+			storage_backend = COALESCE(EXCLUDED.storage_backend, storage_deletion_failures.storage_backend),
+			source          = EXCLUDED.source,
+			last_error      = EXCLUDED.last_error,
+			attempts        = storage_deletion_failures.attempts + 1,
+			last_attempt_at = NOW()
+		RETURNING id, attempts, created_at, last_attempt_at`,
+		f.StorageKey, f.StorageBackend, f.Source, f.LastError,
+	).Scan(&f.ID, &f.Attempts, &f.CreatedAt, &f.LastAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to record storage deletion failure: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func TestStorageDeletionFailure_Record(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := NewStorageDeletionFailureRepository(db)
+
+	backend := "s3"
+	mock.ExpectQuery("INSERT INTO storage_deletion_failures.*ON CONFLICT \\(storage_key\\) DO UPDATE.*attempts \\+ 1").
+		WithArgs("tf/1.7.0/SHA256SUMS", &backend, "terraform_mirror_version", "access denied").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "attempts", "created_at", "last_attempt_at"}).
+			AddRow("f-1", 2, time.Now(), time.Now()))
+
+	f := &models.StorageDeletionFailure{
+		StorageKey:     "tf/1.7.0/SHA256SUMS",
+		StorageBackend: &backend,
+		Source:         "terraform_mirror_version",
+		LastError:      "access denied",
+	}
+	if err := repo.Record(context.Background(), f); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if f.ID != "f-1" || f.Attempts != 2 {
+		t.Errorf("record = %+v, want id f-1 and 2 attempts", f)
+	}
+
+	mock.ExpectQuery("INSERT INTO storage_deletion_failures").WillReturnError(errors.New("db down"))
+	if err := repo.Record(context.Background(), f); err == nil {
+		t.Error("Record should fail when the insert fails")
+	}
+}
@@ -0,0 +1,179 @@
+// storage_key_repository.go implements StorageKeyRepository, which lists every
+// storage key recorded in the database together with the identity of the
+// object it names, and rewrites those keys when objects are relocated.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// StorageKeyRepository reads and updates the storage key columns of module
+// versions, provider platforms, provider versions and provider re-signatures.
+type StorageKeyRepository struct {
+	db *sql.DB
+}
+
+// NewStorageKeyRepository creates a new storage key repository
+func NewStorageKeyRepository(db *sql.DB) *StorageKeyRepository {
+	return &StorageKeyRepository{db: db}
+}
+
+// storageKeyColumns maps each StoredArtifact kind to the table, ID column and
+// key column it is stored in.
+var storageKeyColumns = map[string]struct{ table, idColumn, keyColumn string }{
+	models.StoredArtifactModuleArchive:            {"module_versions", "id", "storage_path"},
+	models.StoredArtifactProviderArchive:          {"provider_platforms", "id", "storage_path"},
+	models.StoredArtifactProviderShasums:          {"provider_versions", "id", "shasum_storage_key"},
+	models.StoredArtifactProviderShasumsSignature: {"provider_versions", "id", "shasum_signature_storage_key"},
+	models.StoredArtifactResignedShasums:          {"provider_version_resignatures", "provider_version_id", "shasum_storage_key"},
+	models.StoredArtifactResignedShasumsSignature: {"provider_version_resignatures", "provider_version_id", "shasum_signature_storage_key"},
+}
+
+// ListStoredArtifacts returns every object referenced by a storage key column.
+// Empty and NULL keys (files never stored locally) are left out.
+func (r *StorageKeyRepository) ListStoredArtifacts(ctx context.Context) ([]models.StoredArtifact, error) {
+	var artifacts []models.StoredArtifact
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT mv.id, mv.storage_path, m.namespace, m.name, m.system, mv.version, mv.checksum
+		FROM module_versions mv
+		JOIN modules m ON m.id = mv.module_id
+		WHERE mv.storage_path <> ''
+		ORDER BY mv.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list module archives: %w", err)
+	}
+	for rows.Next() {
+		a := models.StoredArtifact{Kind: models.StoredArtifactModuleArchive}
+		if err := rows.Scan(&a.ID, &a.Key, &a.Namespace, &a.Name, &a.System, &a.Version, &a.Checksum); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan module archive: %w", err)
+		}
+		artifacts = append(artifacts, a)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, fmt.Errorf("failed to list module archives: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT pp.id, pp.storage_path, p.namespace, p.type, pv.version, pp.os, pp.arch, pp.shasum
+		FROM provider_platforms pp
+		JOIN provider_versions pv ON pv.id = pp.provider_version_id
+		JOIN providers p ON p.id = pv.provider_id
+		WHERE pp.storage_path <> ''
+		ORDER BY pp.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider archives: %w", err)
+	}
+	for rows.Next() {
+		a := models.StoredArtifact{Kind: models.StoredArtifactProviderArchive}
+		if err := rows.Scan(&a.ID, &a.Key, &a.Namespace, &a.Name, &a.Version, &a.OS, &a.Arch, &a.Checksum); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan provider archive: %w", err)
+		}
+		artifacts = append(artifacts, a)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, fmt.Errorf("failed to list provider archives: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT pv.id, p.namespace, p.type, pv.version,
+		       COALESCE(pv.shasum_storage_key, ''), COALESCE(pv.shasum_signature_storage_key, '')
+		FROM provider_versions pv
+		JOIN providers p ON p.id = pv.provider_id
+		WHERE COALESCE(pv.shasum_storage_key, '') <> '' OR COALESCE(pv.shasum_signature_storage_key, '') <> ''
+		ORDER BY pv.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider checksum files: %w", err)
+	}
+	for rows.Next() {
+		var id, namespace, providerType, version, sumsKey, sigKey string
+		if err := rows.Scan(&id, &namespace, &providerType, &version, &sumsKey, &sigKey); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan provider checksum files: %w", err)
+		}
+		artifacts = appendKeyPair(artifacts, models.StoredArtifact{ID: id, Namespace: namespace, Name: providerType, Version: version},
+			models.StoredArtifactProviderShasums, sumsKey, models.StoredArtifactProviderShasumsSignature, sigKey)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, fmt.Errorf("failed to list provider checksum files: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT r.provider_version_id, p.namespace, p.type, pv.version, r.key_id,
+		       r.shasum_storage_key, r.shasum_signature_storage_key
+		FROM provider_version_resignatures r
+		JOIN provider_versions pv ON pv.id = r.provider_version_id
+		JOIN providers p ON p.id = pv.provider_id
+		ORDER BY r.provider_version_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list re-signed checksum files: %w", err)
+	}
+	for rows.Next() {
+		var base models.StoredArtifact
+		var sumsKey, sigKey string
+		if err := rows.Scan(&base.ID, &base.Namespace, &base.Name, &base.Version, &base.KeyID, &sumsKey, &sigKey); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan re-signed checksum files: %w", err)
+		}
+		artifacts = appendKeyPair(artifacts, base,
+			models.StoredArtifactResignedShasums, sumsKey, models.StoredArtifactResignedShasumsSignature, sigKey)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, fmt.Errorf("failed to list re-signed checksum files: %w", err)
+	}
+
+	return artifacts, nil
+}
+
+// appendKeyPair appends the SHA256SUMS and signature artifacts of one provider
+// version row, skipping whichever key is empty.
+func appendKeyPair(artifacts []models.StoredArtifact, base models.StoredArtifact, sumsKind, sumsKey, sigKind, sigKey string) []models.StoredArtifact {
+	if sumsKey != "" {
+		a := base
+		a.Kind, a.Key = sumsKind, sumsKey
+		artifacts = append(artifacts, a)
+	}
+	if sigKey != "" {
+		a := base
+		a.Kind, a.Key = sigKind, sigKey
+		artifacts = append(artifacts, a)
+	}
+	return artifacts
+}
+
+// closeRows closes rows and returns the first iteration or close error.
+func closeRows(rows *sql.Rows) error {
+	iterErr := rows.Err()
+	closeErr := rows.Close()
+	if iterErr != nil {
+		return iterErr
+	}
+	return closeErr
+}
+
+// UpdateStoredArtifactKey points a's key column at newKey. The update only
+// applies while the column still holds a.Key, so a row re-published since it
+// was listed is left alone; the result reports whether a row was updated.
+func (r *StorageKeyRepository) UpdateStoredArtifactKey(ctx context.Context, a models.StoredArtifact, newKey string) (bool, error) {
+	cols, ok := storageKeyColumns[a.Kind]
+	if !ok {
+		return false, fmt.Errorf("unknown stored artifact kind %q", a.Kind)
+	}
+	query := fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE %s = $2 AND %s = $3`,
+		cols.table, cols.keyColumn, cols.idColumn, cols.keyColumn)
+	res, err := r.db.ExecContext(ctx, query, newKey, a.ID, a.Key)
+	if err != nil {
+		return false, fmt.Errorf("failed to update %s key: %w", a.Kind, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newStorageKeyRepo(t *testing.T) (*StorageKeyRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewStorageKeyRepository(db), mock
+}
+
+func TestStorageKey_ListStoredArtifacts(t *testing.T) {
+	repo, mock := newStorageKeyRepo(t)
+	mock.ExpectQuery("SELECT.*FROM module_versions mv").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "storage_path", "namespace", "name", "system", "version", "checksum"}).
+			AddRow("mv-1", "modules/Acme/vpc/aws/1.0.0.tar.gz", "Acme", "vpc", "aws", "1.0.0", "abc"))
+	mock.ExpectQuery("SELECT.*FROM provider_platforms pp").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "storage_path", "namespace", "type", "version", "os", "arch", "shasum"}).
+			AddRow("pp-1", "providers/acme/cloud/1.0.0/linux/amd64/p.zip", "acme", "cloud", "1.0.0", "linux", "amd64", "def"))
+	mock.ExpectQuery("SELECT.*FROM provider_versions pv").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "namespace", "type", "version", "sums", "sig"}).
+			AddRow("pv-1", "acme", "cloud", "1.0.0", "providers/acme/cloud/1.0.0/SHA256SUMS", ""))
+	mock.ExpectQuery("SELECT.*FROM provider_version_resignatures r").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "namespace", "type", "version", "key_id", "sums", "sig"}).
+			AddRow("pv-1", "acme", "cloud", "1.0.0", "KEY", "r/SHA256SUMS", "r/SHA256SUMS.sig"))
+
+	artifacts, err := repo.ListStoredArtifacts(context.Background())
+	if err != nil {
+		t.Fatalf("ListStoredArtifacts: %v", err)
+	}
+	wantKinds := []string{
+		models.StoredArtifactModuleArchive,
+		models.StoredArtifactProviderArchive,
+		models.StoredArtifactProviderShasums, // empty signature key is skipped
+		models.StoredArtifactResignedShasums,
+		models.StoredArtifactResignedShasumsSignature,
+	}
+	if len(artifacts) != len(wantKinds) {
+		t.Fatalf("artifacts = %+v", artifacts)
+	}
+	for i, kind := range wantKinds {
+		if artifacts[i].Kind != kind {
+			t.Errorf("artifacts[%d].Kind = %q, want %q", i, artifacts[i].Kind, kind)
+		}
+	}
+	if a := artifacts[0]; a.Namespace != "Acme" || a.System != "aws" || a.Checksum != "abc" {
+		t.Errorf("module archive = %+v", a)
+	}
+	if a := artifacts[4]; a.KeyID != "KEY" || a.Key != "r/SHA256SUMS.sig" || a.ID != "pv-1" {
+		t.Errorf("re-signed signature = %+v", a)
+	}
+}
+
+func TestStorageKey_ListStoredArtifacts_QueryError(t *testing.T) {
+	repo, mock := newStorageKeyRepo(t)
+	mock.ExpectQuery("SELECT.*FROM module_versions mv").WillReturnError(errors.New("boom"))
+	if _, err := repo.ListStoredArtifacts(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestStorageKey_UpdateStoredArtifactKey(t *testing.T) {
+	repo, mock := newStorageKeyRepo(t)
+	a := models.StoredArtifact{Kind: models.StoredArtifactResignedShasums, ID: "pv-1", Key: "old"}
+
+	mock.ExpectExec("UPDATE provider_version_resignatures SET shasum_storage_key = \\$1 WHERE provider_version_id = \\$2 AND shasum_storage_key = \\$3").
+		WithArgs("new", "pv-1", "old").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if ok, err := repo.UpdateStoredArtifactKey(context.Background(), a, "new"); !ok || err != nil {
+		t.Errorf("UpdateStoredArtifactKey = %v, %v; want true, nil", ok, err)
+	}
+
+	// The key changed since it was listed: nothing is updated.
+	mock.ExpectExec("UPDATE provider_version_resignatures").
+		WithArgs("new", "pv-1", "old").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if ok, err := repo.UpdateStoredArtifactKey(context.Background(), a, "new"); ok || err != nil {
+		t.Errorf("UpdateStoredArtifactKey = %v, %v; want false, nil", ok, err)
+	}
+
+	if _, err := repo.UpdateStoredArtifactKey(context.Background(), models.StoredArtifact{Kind: "bogus"}, "new"); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package repositories - tenant_domain_repository.go persists the custom
+// tenant domains (tenant_domains) that map a hostname to an organization.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// TenantDomainRepository handles tenant domain database operations.
+type TenantDomainRepository struct {
+	db *sql.DB
+}
+
+// NewTenantDomainRepository creates a new tenant domain repository.
+func NewTenantDomainRepository(db *sql.DB) *TenantDomainRepository {
+	return &TenantDomainRepository{db: db}
+}
+
+const tenantDomainSelect = `
+	SELECT id, hostname, organization_id, product_name, primary_color, logo_url, favicon_url,
+	       created_by, created_at, updated_at
+	FROM tenant_domains`
+
+func scanTenantDomain(row interface{ Scan(...interface{}) error }) (*models.TenantDomain, error) {
+	d := &models.TenantDomain{}
+	err := row.Scan(&d.ID, &d.Hostname, &d.OrganizationID, &d.ProductName, &d.PrimaryColor,
+		&d.LogoURL, &d.FaviconURL, &d.CreatedBy, &d.CreatedAt, &d.UpdatedAt)
+	return d, err
+}
+
+// List returns every tenant domain, ordered by hostname.
+func (r *TenantDomainRepository) List(ctx context.Context) ([]*models.TenantDomain, error) {
+	rows, err := r.db.QueryContext(ctx, tenantDomainSelect+` ORDER BY hostname`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := []*models.TenantDomain{}
+	for rows.Next() {
+		d, err := scanTenantDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tenant domain: %w", err)
+		}
+		domains = append(domains, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tenant domains: %w", err)
+	}
+	return domains, nil
+}
+
+// Get returns a tenant domain by ID, or nil if it does not exist.
+func (r *TenantDomainRepository) Get(ctx context.Context, id string) (*models.TenantDomain, error) {
+	d, err := scanTenantDomain(r.db.QueryRowContext(ctx, tenantDomainSelect+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant domain: %w", err)
+	}
+	return d, nil
+}
+
+// GetByHostname returns the tenant domain for a lowercase hostname, or nil.
+func (r *TenantDomainRepository) GetByHostname(ctx context.Context, hostname string) (*models.TenantDomain, error) {
+	d, err := scanTenantDomain(r.db.QueryRowContext(ctx, tenantDomainSelect+` WHERE hostname = $1`, hostname))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant domain: %w", err)
+	}
+	return d, nil
+}
+
+// Create inserts a tenant domain and fills in its ID and timestamps.
+func (r *TenantDomainRepository) Create(ctx context.Context, d *models.TenantDomain) error {
+	query := `
+		INSERT INTO tenant_domains (hostname, organization_id, product_name, primary_color, logo_url, favicon_url, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		d.Hostname, d.OrganizationID, d.ProductName, d.PrimaryColor, d.LogoURL, d.FaviconURL, d.CreatedBy,
+	).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant domain: %w", err)
+	}
+	return nil
+}
+
+// Update replaces a tenant domain's organization and branding. It reports
+// whether the domain exists.
+func (r *TenantDomainRepository) Update(ctx context.Context, d *models.TenantDomain) (bool, error) {
+	query := `
+		UPDATE tenant_domains
+		SET organization_id = $2, product_name = $3, primary_color = $4, logo_url = $5, favicon_url = $6,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		d.ID, d.OrganizationID, d.ProductName, d.PrimaryColor, d.LogoURL, d.FaviconURL,
+	).Scan(&d.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update tenant domain: %w", err)
+	}
+	return true, nil
+}
+
+// Delete removes a tenant domain. It reports whether the domain existed.
+func (r *TenantDomainRepository) Delete(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM tenant_domains WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete tenant domain: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete tenant domain: %w", err)
+	}
+	return n > 0, nil
+}
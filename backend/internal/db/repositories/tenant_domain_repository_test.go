@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+var tenantDomainCols = []string{
+	"id", "hostname", "organization_id", "product_name", "primary_color", "logo_url", "favicon_url",
+	"created_by", "created_at", "updated_at",
+}
+
+func newTenantDomainRepo(t *testing.T) (*TenantDomainRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewTenantDomainRepository(db), mock
+}
+
+func TestTenantDomain_List(t *testing.T) {
+	repo, mock := newTenantDomainRepo(t)
+	name := "Acme Blue"
+	mock.ExpectQuery("SELECT.*FROM tenant_domains ORDER BY hostname").
+		WillReturnRows(sqlmock.NewRows(tenantDomainCols).
+			AddRow("d-1", "modules.acme-blue.example.com", "org-1", &name, nil, nil, nil, nil, time.Now(), time.Now()))
+
+	domains, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(domains) != 1 || domains[0].OrganizationID != "org-1" || *domains[0].ProductName != name || domains[0].LogoURL != nil {
+		t.Errorf("domains = %+v", domains)
+	}
+}
+
+func TestTenantDomain_GetNotFound(t *testing.T) {
+	repo, mock := newTenantDomainRepo(t)
+	mock.ExpectQuery("SELECT.*FROM tenant_domains WHERE id").
+		WithArgs("d-1").
+		WillReturnRows(sqlmock.NewRows(tenantDomainCols))
+	if d, err := repo.Get(context.Background(), "d-1"); d != nil || err != nil {
+		t.Errorf("Get = %+v, %v; want nil, nil", d, err)
+	}
+
+	mock.ExpectQuery("SELECT.*FROM tenant_domains WHERE hostname").
+		WithArgs("modules.acme-red.example.com").
+		WillReturnError(errors.New("connection reset"))
+	if _, err := repo.GetByHostname(context.Background(), "modules.acme-red.example.com"); err == nil {
+		t.Error("GetByHostname: expected the query error")
+	}
+}
+
+func TestTenantDomain_Create(t *testing.T) {
+	repo, mock := newTenantDomainRepo(t)
+	color, uid := "#cc0000", "admin-1"
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO tenant_domains").
+		WithArgs("modules.acme-red.example.com", "org-2", nil, &color, nil, nil, &uid).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("d-2", now, now))
+
+	d := &models.TenantDomain{Hostname: "modules.acme-red.example.com", OrganizationID: "org-2", PrimaryColor: &color, CreatedBy: &uid}
+	if err := repo.Create(context.Background(), d); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if d.ID != "d-2" || !d.UpdatedAt.Equal(now) {
+		t.Errorf("domain = %+v, want the returned ID and timestamps", d)
+	}
+}
+
+func TestTenantDomain_UpdateAndDelete(t *testing.T) {
+	repo, mock := newTenantDomainRepo(t)
+	mock.ExpectQuery("UPDATE tenant_domains").
+		WithArgs("d-9", "org-1", nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}))
+	if found, err := repo.Update(context.Background(), &models.TenantDomain{ID: "d-9", OrganizationID: "org-1"}); found || err != nil {
+		t.Errorf("Update missing = %v, %v; want false, nil", found, err)
+	}
+
+	mock.ExpectExec("DELETE FROM tenant_domains").
+		WithArgs("d-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if deleted, err := repo.Delete(context.Background(), "d-1"); !deleted || err != nil {
+		t.Errorf("Delete = %v, %v; want true, nil", deleted, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
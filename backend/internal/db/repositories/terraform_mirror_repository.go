@@ -6,6 +6,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // TerraformMirrorRepository handles database operations for the Terraform binary mirror.
@@ -41,11 +43,14 @@ func (r *TerraformMirrorRepository) Create(ctx context.Context, cfg *models.Terr
 			id, name, description, tool, enabled, upstream_url,
 			platform_filter, version_filter, gpg_verify, stable_only, sync_interval_hours,
 			requires_approval, auto_approve_rules, verify_github_attestation,
-			created_at, updated_at
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+			history_retention_count, history_retention_days, exclude_eol_from_latest,
+			max_storage_bytes, created_at, updated_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20)
 		RETURNING id, name, description, tool, enabled, upstream_url,
 		          platform_filter, version_filter, gpg_verify, stable_only, sync_interval_hours,
 		          requires_approval, auto_approve_rules, verify_github_attestation,
+		          history_retention_count, history_retention_days, exclude_eol_from_latest,
+		          max_storage_bytes, storage_alert_level,
 		          last_sync_at, last_sync_status, last_sync_error,
 		          created_at, updated_at
 	`
@@ -65,6 +70,10 @@ func (r *TerraformMirrorRepository) Create(ctx context.Context, cfg *models.Terr
 		cfg.RequiresApproval,
 		cfg.AutoApproveRules,
 		cfg.VerifyGitHubAttestation,
+		cfg.HistoryRetentionCount,
+		cfg.HistoryRetentionDays,
+		cfg.ExcludeEOLFromLatest,
+		cfg.MaxStorageBytes,
 		cfg.CreatedAt,
 		cfg.UpdatedAt,
 	).Scan(
@@ -82,6 +91,11 @@ func (r *TerraformMirrorRepository) Create(ctx context.Context, cfg *models.Terr
 		&cfg.RequiresApproval,
 		&cfg.AutoApproveRules,
 		&cfg.VerifyGitHubAttestation,
+		&cfg.HistoryRetentionCount,
+		&cfg.HistoryRetentionDays,
+		&cfg.ExcludeEOLFromLatest,
+		&cfg.MaxStorageBytes,
+		&cfg.StorageAlertLevel,
 		&cfg.LastSyncAt,
 		&cfg.LastSyncStatus,
 		&cfg.LastSyncError,
@@ -96,6 +110,8 @@ func (r *TerraformMirrorRepository) GetByID(ctx context.Context, id uuid.UUID) (
 		SELECT id, name, description, tool, enabled, upstream_url,
 		       platform_filter, version_filter, gpg_verify, stable_only, sync_interval_hours,
 		       requires_approval, auto_approve_rules, verify_github_attestation,
+		       history_retention_count, history_retention_days, exclude_eol_from_latest,
+		       max_storage_bytes, storage_alert_level,
 		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at
 		FROM terraform_mirror_configs
@@ -120,6 +136,8 @@ func (r *TerraformMirrorRepository) GetByName(ctx context.Context, name string)
 		SELECT id, name, description, tool, enabled, upstream_url,
 		       platform_filter, version_filter, gpg_verify, stable_only, sync_interval_hours,
 		       requires_approval, auto_approve_rules, verify_github_attestation,
+		       history_retention_count, history_retention_days, exclude_eol_from_latest,
+		       max_storage_bytes, storage_alert_level,
 		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at
 		FROM terraform_mirror_configs
@@ -144,6 +162,8 @@ func (r *TerraformMirrorRepository) ListAll(ctx context.Context) ([]models.Terra
 		SELECT id, name, description, tool, enabled, upstream_url,
 		       platform_filter, version_filter, gpg_verify, stable_only, sync_interval_hours,
 		       requires_approval, auto_approve_rules, verify_github_attestation,
+		       history_retention_count, history_retention_days, exclude_eol_from_latest,
+		       max_storage_bytes, storage_alert_level,
 		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at
 		FROM terraform_mirror_configs
@@ -164,6 +184,8 @@ func (r *TerraformMirrorRepository) ListEnabled(ctx context.Context) ([]models.T
 		SELECT id, name, description, tool, enabled, upstream_url,
 		       platform_filter, version_filter, gpg_verify, stable_only, sync_interval_hours,
 		       requires_approval, auto_approve_rules, verify_github_attestation,
+		       history_retention_count, history_retention_days, exclude_eol_from_latest,
+		       max_storage_bytes, storage_alert_level,
 		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at
 		FROM terraform_mirror_configs
@@ -185,6 +207,8 @@ func (r *TerraformMirrorRepository) GetConfigsNeedingSync(ctx context.Context) (
 		SELECT id, name, description, tool, enabled, upstream_url,
 		       platform_filter, version_filter, gpg_verify, stable_only, sync_interval_hours,
 		       requires_approval, auto_approve_rules, verify_github_attestation,
+		       history_retention_count, history_retention_days, exclude_eol_from_latest,
+		       max_storage_bytes, storage_alert_level,
 		       last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at
 		FROM terraform_mirror_configs
@@ -223,7 +247,11 @@ func (r *TerraformMirrorRepository) Update(ctx context.Context, cfg *models.Terr
 		    requires_approval         = $12,
 		    auto_approve_rules        = $13,
 		    verify_github_attestation = $14,
-		    updated_at                = $15
+		    history_retention_count   = $15,
+		    history_retention_days    = $16,
+		    exclude_eol_from_latest   = $17,
+		    max_storage_bytes         = $18,
+		    updated_at                = $19
 		WHERE id = $1
 	`
 
@@ -242,6 +270,10 @@ func (r *TerraformMirrorRepository) Update(ctx context.Context, cfg *models.Terr
 		cfg.RequiresApproval,
 		cfg.AutoApproveRules,
 		cfg.VerifyGitHubAttestation,
+		cfg.HistoryRetentionCount,
+		cfg.HistoryRetentionDays,
+		cfg.ExcludeEOLFromLatest,
+		cfg.MaxStorageBytes,
 		cfg.UpdatedAt,
 	)
 	if err != nil {
@@ -252,6 +284,8 @@ func (r *TerraformMirrorRepository) Update(ctx context.Context, cfg *models.Terr
 }
 
 // Delete removes a mirror config (and cascades to versions/platforms/history).
+// The cascade leaves the config's stored objects behind; list them first with
+// ListConfigArtifacts.
 func (r *TerraformMirrorRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, `DELETE FROM terraform_mirror_configs WHERE id = $1`, id)
 	if err != nil {
@@ -337,7 +371,7 @@ func (r *TerraformMirrorRepository) UpsertVersion(ctx context.Context, v *models
 // GetVersionByString looks up a version row by its semver string within a config.
 func (r *TerraformMirrorRepository) GetVersionByString(ctx context.Context, configID uuid.UUID, version string) (*models.TerraformVersion, error) {
 	query := `
-		SELECT id, config_id, version, is_latest, is_deprecated, release_date,
+		SELECT id, config_id, version, is_latest, is_deprecated, is_eol, eol_message, release_date,
 		       sync_status, sync_error, synced_at, created_at, updated_at,
 		       sums_storage_key, sig_storage_key, approval_status
 		FROM terraform_versions
@@ -359,7 +393,7 @@ func (r *TerraformMirrorRepository) GetVersionByString(ctx context.Context, conf
 // GetLatestVersion returns the version marked is_latest = true for a given config.
 func (r *TerraformMirrorRepository) GetLatestVersion(ctx context.Context, configID uuid.UUID) (*models.TerraformVersion, error) {
 	query := `
-		SELECT id, config_id, version, is_latest, is_deprecated, release_date,
+		SELECT id, config_id, version, is_latest, is_deprecated, is_eol, eol_message, release_date,
 		       sync_status, sync_error, synced_at, created_at, updated_at,
 		       sums_storage_key, sig_storage_key, approval_status
 		FROM terraform_versions
@@ -383,7 +417,7 @@ func (r *TerraformMirrorRepository) GetLatestVersion(ctx context.Context, config
 // When syncedOnly is true only versions with sync_status = 'synced' are returned.
 func (r *TerraformMirrorRepository) ListVersions(ctx context.Context, configID uuid.UUID, syncedOnly bool) ([]models.TerraformVersion, error) {
 	query := `
-		SELECT id, config_id, version, is_latest, is_deprecated, release_date,
+		SELECT id, config_id, version, is_latest, is_deprecated, is_eol, eol_message, release_date,
 		       sync_status, sync_error, synced_at, created_at, updated_at,
 		       sums_storage_key, sig_storage_key, approval_status
 		FROM terraform_versions
@@ -418,7 +452,7 @@ func (r *TerraformMirrorRepository) ListVersionsPaginated(ctx context.Context, c
 	}
 
 	query := `
-		SELECT id, config_id, version, is_latest, is_deprecated, release_date,
+		SELECT id, config_id, version, is_latest, is_deprecated, is_eol, eol_message, release_date,
 		       sync_status, sync_error, synced_at, created_at, updated_at,
 		       sums_storage_key, sig_storage_key, approval_status
 		FROM terraform_versions
@@ -509,6 +543,17 @@ func (r *TerraformMirrorRepository) SetLatestVersion(ctx context.Context, config
 	return tx.Commit()
 }
 
+// ClearLatestVersion unmarks a config's latest version, leaving it with none.
+func (r *TerraformMirrorRepository) ClearLatestVersion(ctx context.Context, configID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE terraform_versions SET is_latest = false WHERE config_id = $1 AND is_latest = true`,
+		configID,
+	); err != nil {
+		return fmt.Errorf("failed to clear is_latest: %w", err)
+	}
+	return nil
+}
+
 // DeleteVersion deletes a version and its platforms (cascade).
 func (r *TerraformMirrorRepository) DeleteVersion(ctx context.Context, versionID uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, `DELETE FROM terraform_versions WHERE id = $1`, versionID)
@@ -534,6 +579,137 @@ func (r *TerraformMirrorRepository) SetVersionDeprecated(ctx context.Context, ve
 	return nil
 }
 
+// ---- EOL rules -------------------------------------------------------------
+
+// ErrTerraformEOLRuleExists is returned by CreateEOLRule and UpdateEOLRule
+// when another rule already covers the same tool and version prefix.
+var ErrTerraformEOLRuleExists = errors.New("an EOL rule for this tool and version prefix already exists")
+
+func eolRuleError(err error, action string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "terraform_eol_rules_unique" {
+		return ErrTerraformEOLRuleExists
+	}
+	return fmt.Errorf("failed to %s terraform EOL rule: %w", action, err)
+}
+
+// ListEOLRules returns every EOL rule ordered by tool and version prefix.
+func (r *TerraformMirrorRepository) ListEOLRules(ctx context.Context) ([]models.TerraformEOLRule, error) {
+	rules := []models.TerraformEOLRule{}
+	err := r.db.SelectContext(ctx, &rules, `
+		SELECT id, tool, version_prefix, eol_date, message, created_at, updated_at
+		FROM terraform_eol_rules
+		ORDER BY tool, version_prefix`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list terraform EOL rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetEOLRule returns an EOL rule by ID, or nil if not found.
+func (r *TerraformMirrorRepository) GetEOLRule(ctx context.Context, id uuid.UUID) (*models.TerraformEOLRule, error) {
+	var rule models.TerraformEOLRule
+	err := r.db.GetContext(ctx, &rule, `
+		SELECT id, tool, version_prefix, eol_date, message, created_at, updated_at
+		FROM terraform_eol_rules
+		WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get terraform EOL rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// CreateEOLRule inserts an EOL rule. The rule only takes effect on versions
+// once ApplyEOLRules runs.
+func (r *TerraformMirrorRepository) CreateEOLRule(ctx context.Context, rule *models.TerraformEOLRule) error {
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO terraform_eol_rules (id, tool, version_prefix, eol_date, message)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at`,
+		rule.ID, rule.Tool, rule.VersionPrefix, rule.EOLDate, rule.Message,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return eolRuleError(err, "create")
+	}
+	return nil
+}
+
+// UpdateEOLRule replaces an EOL rule's fields.
+func (r *TerraformMirrorRepository) UpdateEOLRule(ctx context.Context, rule *models.TerraformEOLRule) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE terraform_eol_rules
+		SET tool = $2, version_prefix = $3, eol_date = $4, message = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		rule.ID, rule.Tool, rule.VersionPrefix, rule.EOLDate, rule.Message,
+	).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return eolRuleError(err, "update")
+	}
+	return nil
+}
+
+// DeleteEOLRule removes an EOL rule. Versions it marked stay marked until
+// ApplyEOLRules runs.
+func (r *TerraformMirrorRepository) DeleteEOLRule(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM terraform_eol_rules WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete terraform EOL rule: %w", err)
+	}
+	return nil
+}
+
+// ApplyEOLRules brings every version's is_eol and eol_message in line with
+// the EOL rules in effect today. A version matches a rule for its config's
+// tool when its version (without a leading "v") equals the rule's prefix or
+// continues it with "." or "-"; the longest matching prefix wins. It returns
+// the number of versions whose marking changed.
+func (r *TerraformMirrorRepository) ApplyEOLRules(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE terraform_versions tv
+		SET is_eol = m.rule_id IS NOT NULL, eol_message = m.message, updated_at = NOW()
+		FROM (
+			SELECT v.id, rule.id AS rule_id,
+			       COALESCE(rule.message, 'Version ' || rule.version_prefix || ' is end-of-life') AS message
+			FROM terraform_versions v
+			JOIN terraform_mirror_configs c ON c.id = v.config_id
+			LEFT JOIN LATERAL (
+				SELECT e.id, e.version_prefix, e.message
+				FROM terraform_eol_rules e
+				WHERE e.tool = c.tool
+				  AND (e.eol_date IS NULL OR e.eol_date <= CURRENT_DATE)
+				  AND (LTRIM(v.version, 'v') = e.version_prefix
+				       OR LEFT(LTRIM(v.version, 'v'), LENGTH(e.version_prefix) + 1) IN (e.version_prefix || '.', e.version_prefix || '-'))
+				ORDER BY LENGTH(e.version_prefix) DESC
+				LIMIT 1
+			) rule ON true
+		) m
+		WHERE tv.id = m.id
+		  AND (tv.is_eol <> (m.rule_id IS NOT NULL)
+		       OR tv.eol_message IS DISTINCT FROM m.message)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply terraform EOL rules: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// CountEOLVersions returns how many of a config's versions are marked
+// end-of-life.
+func (r *TerraformMirrorRepository) CountEOLVersions(ctx context.Context, configID uuid.UUID) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM terraform_versions WHERE config_id = $1 AND is_eol`, configID,
+	).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count end-of-life terraform versions: %w", err)
+	}
+	return n, nil
+}
+
 // ---- Platforms -------------------------------------------------------------
 
 // UpsertPlatform inserts or updates a platform row.
@@ -584,8 +760,9 @@ func (r *TerraformMirrorRepository) UpsertPlatform(ctx context.Context, p *model
 func (r *TerraformMirrorRepository) GetPlatform(ctx context.Context, versionID uuid.UUID, os, arch string) (*models.TerraformVersionPlatform, error) {
 	query := `
 		SELECT id, version_id, os, arch, upstream_url, filename, sha256,
-		       storage_key, storage_backend, sha256_verified, gpg_verified, attestation_verified,
-		       sync_status, sync_error, synced_at, download_count, created_at, updated_at
+		       storage_key, storage_backend, sha256_verified, gpg_verified, attestation_verified, signature_method,
+		       sync_status, sync_error, synced_at, download_count, created_at, updated_at, size_bytes,
+		       provenance, upstream_registry, upstream_download_url, upstream_shasum_url, fetched_at
 		FROM terraform_version_platforms
 		WHERE version_id = $1 AND os = $2 AND arch = $3
 	`
@@ -618,8 +795,9 @@ func (r *TerraformMirrorRepository) IncrementDownloadCount(ctx context.Context,
 func (r *TerraformMirrorRepository) ListPlatformsForVersion(ctx context.Context, versionID uuid.UUID) ([]models.TerraformVersionPlatform, error) {
 	query := `
 		SELECT id, version_id, os, arch, upstream_url, filename, sha256,
-		       storage_key, storage_backend, sha256_verified, gpg_verified, attestation_verified,
-		       sync_status, sync_error, synced_at, download_count, created_at, updated_at
+		       storage_key, storage_backend, sha256_verified, gpg_verified, attestation_verified, signature_method,
+		       sync_status, sync_error, synced_at, download_count, created_at, updated_at, size_bytes,
+		       provenance, upstream_registry, upstream_download_url, upstream_shasum_url, fetched_at
 		FROM terraform_version_platforms
 		WHERE version_id = $1
 		ORDER BY os, arch
@@ -634,12 +812,51 @@ func (r *TerraformMirrorRepository) ListPlatformsForVersion(ctx context.Context,
 	return platforms, nil
 }
 
+// terraformArtifactsQuery lists the stored objects of the versions matched by
+// versionFilter, a condition on terraform_versions v bound to $1.
+func terraformArtifactsQuery(versionFilter string) string {
+	return `
+		SELECT p.storage_key, p.storage_backend
+		FROM terraform_version_platforms p
+		JOIN terraform_versions v ON v.id = p.version_id
+		WHERE ` + versionFilter + ` AND COALESCE(p.storage_key, '') <> ''
+		UNION ALL
+		SELECT v.sums_storage_key, NULL
+		FROM terraform_versions v
+		WHERE ` + versionFilter + ` AND COALESCE(v.sums_storage_key, '') <> ''
+		UNION ALL
+		SELECT v.sig_storage_key, NULL
+		FROM terraform_versions v
+		WHERE ` + versionFilter + ` AND COALESCE(v.sig_storage_key, '') <> ''
+	`
+}
+
+// ListVersionArtifacts returns every storage object a version keeps: its
+// platform packages plus its SHA256SUMS and detached signature.
+func (r *TerraformMirrorRepository) ListVersionArtifacts(ctx context.Context, versionID uuid.UUID) ([]models.TerraformStoredArtifact, error) {
+	var artifacts []models.TerraformStoredArtifact
+	if err := r.db.SelectContext(ctx, &artifacts, terraformArtifactsQuery("v.id = $1"), versionID); err != nil {
+		return nil, fmt.Errorf("failed to list terraform version artifacts: %w", err)
+	}
+	return artifacts, nil
+}
+
+// ListConfigArtifacts returns every storage object kept by the versions of a
+// mirror config, which Delete's cascade would otherwise orphan.
+func (r *TerraformMirrorRepository) ListConfigArtifacts(ctx context.Context, configID uuid.UUID) ([]models.TerraformStoredArtifact, error) {
+	var artifacts []models.TerraformStoredArtifact
+	if err := r.db.SelectContext(ctx, &artifacts, terraformArtifactsQuery("v.config_id = $1"), configID); err != nil {
+		return nil, fmt.Errorf("failed to list terraform mirror artifacts: %w", err)
+	}
+	return artifacts, nil
+}
+
 // ListPendingPlatforms returns all platform rows for a config that have not yet been synced.
 func (r *TerraformMirrorRepository) ListPendingPlatforms(ctx context.Context, configID uuid.UUID) ([]models.TerraformVersionPlatform, error) {
 	query := `
 		SELECT p.id, p.version_id, p.os, p.arch, p.upstream_url, p.filename, p.sha256,
-		       p.storage_key, p.storage_backend, p.sha256_verified, p.gpg_verified, p.attestation_verified,
-		       p.sync_status, p.sync_error, p.synced_at, p.download_count, p.created_at, p.updated_at
+		       p.storage_key, p.storage_backend, p.sha256_verified, p.gpg_verified, p.attestation_verified, p.signature_method,
+		       p.sync_status, p.sync_error, p.synced_at, p.download_count, p.size_bytes, p.created_at, p.updated_at
 		FROM terraform_version_platforms p
 		JOIN terraform_versions v ON v.id = p.version_id
 		WHERE v.config_id = $1
@@ -697,6 +914,40 @@ func (r *TerraformMirrorRepository) UpdatePlatformSyncStatus(
 	return nil
 }
 
+// RecordPlatformProvenance records where a platform's binary was fetched
+// from. It is written just before the platform is marked synced.
+func (r *TerraformMirrorRepository) RecordPlatformProvenance(ctx context.Context, id uuid.UUID, p models.UpstreamProvenance) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE terraform_version_platforms
+		SET provenance            = $2,
+		    upstream_registry     = $3,
+		    upstream_download_url = $4,
+		    upstream_shasum_url   = $5,
+		    fetched_at            = $6,
+		    updated_at            = NOW()
+		WHERE id = $1
+	`, id, p.Provenance, p.UpstreamRegistry, p.UpstreamDownloadURL, p.UpstreamSHASumURL, p.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record provenance for platform %s: %w", id, err)
+	}
+	return nil
+}
+
+// ResetPlatformSync marks a synced platform pending again, so the next sync of
+// its config downloads and stores the binary anew. It is used to repair a
+// platform whose stored binary has gone missing.
+func (r *TerraformMirrorRepository) ResetPlatformSync(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE terraform_version_platforms
+		SET sync_status = 'pending', sync_error = NULL, synced_at = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to reset terraform platform sync status: %w", err)
+	}
+	return nil
+}
+
 // UpdatePlatformSHA256 writes the hex SHA256 of a platform's zip to its row.
 // Called once the upstream SHA256SUMS hash for the platform's filename is known
 // (either after fresh download/verify or during back-fill from the SUMS file).
@@ -712,6 +963,38 @@ func (r *TerraformMirrorRepository) UpdatePlatformSHA256(ctx context.Context, id
 	return nil
 }
 
+// UpdatePlatformSize records the stored size of a platform's binary.
+func (r *TerraformMirrorRepository) UpdatePlatformSize(ctx context.Context, id uuid.UUID, sizeBytes int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE terraform_version_platforms SET size_bytes = $2 WHERE id = $1`,
+		id, sizeBytes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update size for platform %s: %w", id, err)
+	}
+	return nil
+}
+
+// ListPlatformsMissingSize returns a config's stored platforms whose size
+// was not recorded, i.e. binaries synced before sizes were kept.
+func (r *TerraformMirrorRepository) ListPlatformsMissingSize(ctx context.Context, configID uuid.UUID) ([]models.TerraformVersionPlatform, error) {
+	query := `
+		SELECT p.id, p.version_id, p.os, p.arch, p.filename, p.storage_key, p.sync_status
+		FROM terraform_version_platforms p
+		JOIN terraform_versions v ON v.id = p.version_id
+		WHERE v.config_id = $1
+		  AND p.sync_status = 'synced'
+		  AND COALESCE(p.storage_key, '') <> ''
+		  AND p.size_bytes IS NULL
+	`
+
+	var platforms []models.TerraformVersionPlatform
+	if err := r.db.SelectContext(ctx, &platforms, query, configID); err != nil {
+		return nil, fmt.Errorf("failed to list terraform platforms missing a size: %w", err)
+	}
+	return platforms, nil
+}
+
 // UpdatePlatformAttestationVerified sets attestation_verified on a single
 // platform row. Unlike GPG (one signature covers the whole version's
 // SHA256SUMS file), a GitHub Artifact Attestation is per-binary-digest, so
@@ -770,6 +1053,21 @@ func (r *TerraformMirrorRepository) UpdateGPGVerifiedForVersion(ctx context.Cont
 	return nil
 }
 
+// UpdateSignatureMethodForVersion records how the version's SHA256SUMS file
+// was authenticated ("gpg" or "cosign") on all of its synced platforms.
+func (r *TerraformMirrorRepository) UpdateSignatureMethodForVersion(ctx context.Context, versionID uuid.UUID, method string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE terraform_version_platforms
+		SET signature_method = $2, updated_at = NOW()
+		WHERE version_id = $1
+		  AND sync_status = 'synced'
+	`, versionID, method)
+	if err != nil {
+		return fmt.Errorf("failed to update signature_method for version %s: %w", versionID, err)
+	}
+	return nil
+}
+
 // CountVersionStats returns distinct version count, total platform count, and pending platform count for a config.
 func (r *TerraformMirrorRepository) CountVersionStats(ctx context.Context, configID uuid.UUID) (versionCount, platformCount, pendingCount int, err error) {
 	row := r.db.QueryRowContext(ctx, `
@@ -789,6 +1087,33 @@ func (r *TerraformMirrorRepository) CountVersionStats(ctx context.Context, confi
 	return versionCount, platformCount, pendingCount, nil
 }
 
+// GetStorageUsage returns the bytes stored for a config's platform binaries.
+// Binaries whose size is not recorded yet count as zero.
+func (r *TerraformMirrorRepository) GetStorageUsage(ctx context.Context, configID uuid.UUID) (int64, error) {
+	var used int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(p.size_bytes), 0)
+		FROM terraform_version_platforms p
+		JOIN terraform_versions v ON v.id = p.version_id
+		WHERE v.config_id = $1 AND COALESCE(p.storage_key, '') <> ''
+	`, configID).Scan(&used)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get terraform mirror storage usage: %w", err)
+	}
+	return used, nil
+}
+
+// SetStorageAlertLevel records the highest storage usage threshold notified
+// for the config.
+func (r *TerraformMirrorRepository) SetStorageAlertLevel(ctx context.Context, id uuid.UUID, level int) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE terraform_mirror_configs SET storage_alert_level = $2 WHERE id = $1`, id, level,
+	); err != nil {
+		return fmt.Errorf("failed to update terraform mirror storage alert level: %w", err)
+	}
+	return nil
+}
+
 // ---- Sync History ----------------------------------------------------------
 
 // CreateSyncHistory inserts a new sync history record.
@@ -856,11 +1181,22 @@ func (r *TerraformMirrorRepository) CompleteSyncHistory(
 	return nil
 }
 
-// ListSyncHistory returns the most recent sync history rows for a config.
-func (r *TerraformMirrorRepository) ListSyncHistory(ctx context.Context, configID uuid.UUID, limit int) ([]models.TerraformSyncHistory, error) {
+// ListSyncHistory returns a page of sync history rows for a config, newest
+// first, together with the total number of retained rows.
+func (r *TerraformMirrorRepository) ListSyncHistory(ctx context.Context, configID uuid.UUID, limit, offset int) ([]models.TerraformSyncHistory, int, error) {
 	if limit <= 0 {
 		limit = 50
 	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM terraform_sync_history WHERE config_id = $1`, configID,
+	).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count terraform sync history: %w", err)
+	}
 
 	query := `
 		SELECT id, config_id, triggered_by, started_at, completed_at, status,
@@ -868,16 +1204,70 @@ func (r *TerraformMirrorRepository) ListSyncHistory(ctx context.Context, configI
 		FROM terraform_sync_history
 		WHERE config_id = $1
 		ORDER BY started_at DESC
-		LIMIT $2
+		LIMIT $2 OFFSET $3
+	`
+
+	history := []models.TerraformSyncHistory{}
+	err := r.db.SelectContext(ctx, &history, query, configID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list terraform sync history: %w", err)
+	}
+
+	return history, total, nil
+}
+
+// PruneSyncHistory deletes finished sync history rows for a config that fall
+// outside its retention window: beyond the keepCount most recent runs, or
+// started more than keepDays days ago. A zero limit is not applied. Running
+// syncs are never deleted. It returns the number of rows removed.
+func (r *TerraformMirrorRepository) PruneSyncHistory(ctx context.Context, configID uuid.UUID, keepCount, keepDays int) (int64, error) {
+	if keepCount <= 0 && keepDays <= 0 {
+		return 0, nil
+	}
+
+	query := `
+		DELETE FROM terraform_sync_history
+		WHERE config_id = $1
+		  AND status <> 'running'
+		  AND (
+		        ($2::int > 0 AND id NOT IN (
+		            SELECT id FROM terraform_sync_history
+		            WHERE config_id = $1
+		            ORDER BY started_at DESC
+		            LIMIT $2::int
+		        ))
+		        OR ($3::int > 0 AND started_at < NOW() - ($3::int * INTERVAL '1 day'))
+		      )
 	`
 
-	var history []models.TerraformSyncHistory
-	err := r.db.SelectContext(ctx, &history, query, configID, limit)
+	result, err := r.db.ExecContext(ctx, query, configID, keepCount, keepDays)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list terraform sync history: %w", err)
+		return 0, fmt.Errorf("failed to prune terraform sync history: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// GetSyncHistoryStats aggregates the finished sync runs retained for a config.
+func (r *TerraformMirrorRepository) GetSyncHistoryStats(ctx context.Context, configID uuid.UUID) (*models.SyncHistoryStats, error) {
+	query := `
+		SELECT COUNT(*)                                         AS total_runs,
+		       COUNT(*) FILTER (WHERE status = 'success')       AS successful_runs,
+		       COUNT(*) FILTER (WHERE status = 'failed')        AS failed_runs,
+		       COUNT(*) FILTER (WHERE status = 'cancelled')     AS cancelled_runs,
+		       MIN(started_at)                                  AS window_start,
+		       MAX(started_at) FILTER (WHERE status = 'success') AS last_success_at,
+		       MAX(started_at) FILTER (WHERE status = 'failed')  AS last_failure_at
+		FROM terraform_sync_history
+		WHERE config_id = $1 AND status <> 'running'
+	`
+
+	var row models.SyncHistoryStatsRow
+	if err := r.db.GetContext(ctx, &row, query, configID); err != nil {
+		return nil, fmt.Errorf("failed to get terraform sync history stats: %w", err)
 	}
 
-	return history, nil
+	return row.Stats(), nil
 }
 
 // ---- Platform filter helpers -----------------------------------------------
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -9,6 +10,7 @@ import (
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 )
@@ -18,6 +20,8 @@ var tfMirrorConfigCols = []string{
 	"id", "name", "description", "tool", "enabled", "upstream_url",
 	"platform_filter", "version_filter", "gpg_verify", "stable_only", "sync_interval_hours",
 	"requires_approval", "auto_approve_rules", "verify_github_attestation",
+	"history_retention_count", "history_retention_days", "exclude_eol_from_latest",
+	"max_storage_bytes", "storage_alert_level",
 	"last_sync_at", "last_sync_status", "last_sync_error",
 	"created_at", "updated_at",
 }
@@ -49,6 +53,11 @@ func newTfMirrorConfigRow(mock sqlmock.Sqlmock, cfg *models.TerraformMirrorConfi
 		cfg.RequiresApproval,
 		cfg.AutoApproveRules,
 		cfg.VerifyGitHubAttestation,
+		cfg.HistoryRetentionCount,
+		cfg.HistoryRetentionDays,
+		cfg.ExcludeEOLFromLatest,
+		cfg.MaxStorageBytes,
+		cfg.StorageAlertLevel,
 		cfg.LastSyncAt,
 		cfg.LastSyncStatus,
 		cfg.LastSyncError,
@@ -323,6 +332,8 @@ func TestTerraformMirrorListAll_Success(t *testing.T) {
 			c.ID, c.Name, c.Description, c.Tool, c.Enabled, c.UpstreamURL,
 			c.PlatformFilter, c.VersionFilter, c.GPGVerify, c.StableOnly, c.SyncIntervalHours,
 			c.RequiresApproval, c.AutoApproveRules, c.VerifyGitHubAttestation,
+			c.HistoryRetentionCount, c.HistoryRetentionDays, c.ExcludeEOLFromLatest,
+			c.MaxStorageBytes, c.StorageAlertLevel,
 			c.LastSyncAt, c.LastSyncStatus, c.LastSyncError, c.CreatedAt, c.UpdatedAt,
 		)
 	}
@@ -679,6 +690,40 @@ func TestTerraformMirrorDeleteVersion_DBError(t *testing.T) {
 	}
 }
 
+// --- ListVersionArtifacts / ListConfigArtifacts ---
+
+func TestTerraformMirrorListVersionArtifacts(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	id := uuid.New()
+
+	mock.ExpectQuery(`FROM terraform_version_platforms p.*WHERE v.id = \$1.*sums_storage_key.*sig_storage_key`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"storage_key", "storage_backend"}).
+			AddRow("tf/1.7.0/linux/amd64/terraform.zip", "s3").
+			AddRow("tf/1.7.0/SHA256SUMS", nil))
+
+	artifacts, err := repo.ListVersionArtifacts(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(artifacts) != 2 || artifacts[0].StorageBackend == nil || artifacts[1].StorageBackend != nil {
+		t.Errorf("artifacts = %+v, want a package with its backend and a SHA256SUMS without", artifacts)
+	}
+}
+
+func TestTerraformMirrorListConfigArtifacts_DBError(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	id := uuid.New()
+
+	mock.ExpectQuery(`WHERE v.config_id = \$1`).
+		WithArgs(id).
+		WillReturnError(fmt.Errorf("db error"))
+
+	if _, err := repo.ListConfigArtifacts(context.Background(), id); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 // --- SetVersionDeprecated ---
 
 func TestSetVersionDeprecated_True(t *testing.T) {
@@ -1081,6 +1126,42 @@ func TestTerraformMirrorUpdatePlatformSyncStatus_Failed(t *testing.T) {
 	}
 }
 
+func TestTerraformMirrorResetPlatformSync(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	id := uuid.New()
+
+	mock.ExpectExec(`UPDATE terraform_version_platforms\s+SET sync_status = 'pending'`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.ResetPlatformSync(context.Background(), id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestTerraformMirrorRecordPlatformProvenance(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	id := uuid.New()
+	fetched := time.Now()
+	p := models.NewUpstreamProvenance("releases.hashicorp.com",
+		"https://releases.hashicorp.com/terraform/1.9.0/terraform_1.9.0_linux_amd64.zip",
+		"https://releases.hashicorp.com/terraform/1.9.0/terraform_1.9.0_SHA256SUMS", fetched)
+
+	mock.ExpectExec(`UPDATE terraform_version_platforms\s+SET provenance`).
+		WithArgs(id, p.Provenance, p.UpstreamRegistry, p.UpstreamDownloadURL, p.UpstreamSHASumURL, p.FetchedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RecordPlatformProvenance(context.Background(), id, p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 func TestTerraformMirrorUpdatePlatformSyncStatus_DBError(t *testing.T) {
 	repo, mock := newTerraformMirrorRepo(t)
 	id := uuid.New()
@@ -1312,28 +1393,37 @@ func TestTerraformMirrorListSyncHistory_Success(t *testing.T) {
 	configID := uuid.New()
 	h := testTFSyncHistory(configID)
 
-	mock.ExpectQuery(`SELECT.*FROM terraform_sync_history`).
-		WithArgs(configID, 10).
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM terraform_sync_history`).
+		WithArgs(configID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(31))
+	mock.ExpectQuery(`SELECT.*FROM terraform_sync_history.*LIMIT \$2 OFFSET \$3`).
+		WithArgs(configID, 10, 20).
 		WillReturnRows(newTFSyncHistoryRow(mock, h))
 
-	history, err := repo.ListSyncHistory(context.Background(), configID, 10)
+	history, total, err := repo.ListSyncHistory(context.Background(), configID, 10, 20)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if len(history) != 1 {
 		t.Fatalf("expected 1 history entry, got %d", len(history))
 	}
+	if total != 31 {
+		t.Errorf("total = %d, want 31", total)
+	}
 }
 
 func TestTerraformMirrorListSyncHistory_DefaultLimit(t *testing.T) {
 	repo, mock := newTerraformMirrorRepo(t)
 	configID := uuid.New()
 
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM terraform_sync_history`).
+		WithArgs(configID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mock.ExpectQuery(`SELECT.*FROM terraform_sync_history`).
-		WithArgs(configID, 50).
+		WithArgs(configID, 50, 0).
 		WillReturnRows(mock.NewRows(tfSyncHistoryCols))
 
-	history, err := repo.ListSyncHistory(context.Background(), configID, 0)
+	history, _, err := repo.ListSyncHistory(context.Background(), configID, 0, -5)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1346,16 +1436,73 @@ func TestTerraformMirrorListSyncHistory_DBError(t *testing.T) {
 	repo, mock := newTerraformMirrorRepo(t)
 	configID := uuid.New()
 
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM terraform_sync_history`).
+		WithArgs(configID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 	mock.ExpectQuery(`SELECT.*FROM terraform_sync_history`).
-		WithArgs(configID, 10).
+		WithArgs(configID, 10, 0).
 		WillReturnError(fmt.Errorf("db error"))
 
-	_, err := repo.ListSyncHistory(context.Background(), configID, 10)
+	_, _, err := repo.ListSyncHistory(context.Background(), configID, 10, 0)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
 }
 
+// --- PruneSyncHistory ---
+
+func TestTerraformMirrorPruneSyncHistory_Success(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	configID := uuid.New()
+
+	mock.ExpectExec(`DELETE FROM terraform_sync_history.*status <> 'running'`).
+		WithArgs(configID, 100, 30).
+		WillReturnResult(sqlmock.NewResult(0, 7))
+
+	n, err := repo.PruneSyncHistory(context.Background(), configID, 100, 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 7 {
+		t.Errorf("pruned = %d, want 7", n)
+	}
+}
+
+func TestTerraformMirrorPruneSyncHistory_Unlimited(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+
+	n, err := repo.PruneSyncHistory(context.Background(), uuid.New(), 0, 0)
+	if err != nil || n != 0 {
+		t.Fatalf("PruneSyncHistory = (%d, %v), want (0, nil)", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected query: %v", err)
+	}
+}
+
+// --- GetSyncHistoryStats ---
+
+func TestTerraformMirrorGetSyncHistoryStats_Success(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	configID := uuid.New()
+	start := time.Now().Add(-48 * time.Hour).UTC()
+
+	mock.ExpectQuery(`SELECT COUNT.*FROM terraform_sync_history`).
+		WithArgs(configID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"total_runs", "successful_runs", "failed_runs", "cancelled_runs",
+			"window_start", "last_success_at", "last_failure_at",
+		}).AddRow(4, 3, 1, 0, start, start, start))
+
+	stats, err := repo.GetSyncHistoryStats(context.Background(), configID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.TotalRuns != 4 || stats.SuccessRate == nil || *stats.SuccessRate != 0.75 {
+		t.Errorf("stats = %+v, want 4 runs at 0.75", stats)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ListVersionsPaginated
 // ---------------------------------------------------------------------------
@@ -1441,3 +1588,120 @@ func TestTerraformMirrorListVersionsPaginated_QueryError(t *testing.T) {
 		t.Error("expected error, got nil")
 	}
 }
+
+// --- EOL rules ---
+
+func TestTerraformMirrorCreateEOLRule_Conflict(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+
+	mock.ExpectQuery("INSERT INTO terraform_eol_rules").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "terraform_eol_rules_unique"})
+
+	err := repo.CreateEOLRule(context.Background(), &models.TerraformEOLRule{Tool: "terraform", VersionPrefix: "1.3"})
+	if !errors.Is(err, ErrTerraformEOLRuleExists) {
+		t.Errorf("err = %v, want ErrTerraformEOLRuleExists", err)
+	}
+}
+
+func TestTerraformMirrorCreateEOLRule_AssignsID(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO terraform_eol_rules").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+
+	rule := &models.TerraformEOLRule{Tool: "terraform", VersionPrefix: "1.3"}
+	if err := repo.CreateEOLRule(context.Background(), rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.ID == uuid.Nil {
+		t.Error("expected an ID to be assigned")
+	}
+}
+
+func TestTerraformMirrorApplyEOLRules(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+
+	mock.ExpectExec("UPDATE terraform_versions tv SET is_eol").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := repo.ApplyEOLRules(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("changed = %d, want 3", n)
+	}
+}
+
+func TestTerraformMirrorCountEOLVersions(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	configID := uuid.New()
+
+	mock.ExpectQuery("SELECT COUNT.*is_eol").
+		WithArgs(configID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	n, err := repo.CountEOLVersions(context.Background(), configID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("count = %d, want 2", n)
+	}
+}
+
+func TestTerraformMirrorGetStorageUsage(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	id := uuid.New()
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(p.size_bytes\), 0\)`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(int64(1 << 20)))
+
+	used, err := repo.GetStorageUsage(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if used != 1<<20 {
+		t.Errorf("used = %d, want %d", used, 1<<20)
+	}
+}
+
+func TestTerraformMirrorListPlatformsMissingSize(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	id := uuid.New()
+	key := "terraform-binaries/1.7.0/linux/amd64/terraform_1.7.0_linux_amd64.zip"
+	mock.ExpectQuery(`FROM terraform_version_platforms p.*p.size_bytes IS NULL`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "storage_key", "sync_status"}).
+			AddRow(uuid.New(), key, "synced"))
+
+	platforms, err := repo.ListPlatformsMissingSize(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(platforms) != 1 || platforms[0].StorageKey == nil || *platforms[0].StorageKey != key {
+		t.Errorf("platforms = %+v, want one with storage key %s", platforms, key)
+	}
+}
+
+func TestTerraformMirrorUpdatePlatformSizeAndAlertLevel(t *testing.T) {
+	repo, mock := newTerraformMirrorRepo(t)
+	platformID, cfgID := uuid.New(), uuid.New()
+	mock.ExpectExec(`UPDATE terraform_version_platforms SET size_bytes`).
+		WithArgs(platformID, int64(2048)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE terraform_mirror_configs SET storage_alert_level`).
+		WithArgs(cfgID, 100).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdatePlatformSize(context.Background(), platformID, 2048); err != nil {
+		t.Fatalf("UpdatePlatformSize: %v", err)
+	}
+	if err := repo.SetStorageAlertLevel(context.Background(), cfgID, 100); err != nil {
+		t.Fatalf("SetStorageAlertLevel: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
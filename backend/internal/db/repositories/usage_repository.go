@@ -0,0 +1,83 @@
+// Package repositories - usage_repository.go persists the module and provider
+// versions consumers report using, and lists the consumers of each.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// UsageRepository handles usage report database operations.
+type UsageRepository struct {
+	db *sql.DB
+}
+
+// NewUsageRepository creates a new usage repository.
+func NewUsageRepository(db *sql.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// Record stores a consumer's report of the versions it uses. A version the
+// consumer already reported has its last-seen time and API key refreshed;
+// versions missing from the report are left as they are, to age out.
+func (r *UsageRepository) Record(ctx context.Context, orgID, consumer string, apiKeyID *string, records []models.UsageRecord) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO usage_consumers (organization_id, consumer, kind, namespace, name, system, version, api_key_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (organization_id, consumer, kind, namespace, name, system, version)
+		DO UPDATE SET last_seen_at = NOW(), api_key_id = EXCLUDED.api_key_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare usage upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, rec := range records {
+		if _, err := stmt.ExecContext(ctx, orgID, consumer, rec.Kind, rec.Namespace, rec.Name, rec.System, rec.Version, apiKeyID); err != nil {
+			return fmt.Errorf("failed to record usage of %s/%s: %w", rec.Namespace, rec.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit usage report: %w", err)
+	}
+	return nil
+}
+
+// ListConsumers returns the consumers of a module or provider (system empty),
+// one entry per consumer and version, ordered by consumer and then most
+// recently seen first.
+func (r *UsageRepository) ListConsumers(ctx context.Context, kind, namespace, name, system string) ([]*models.UsageConsumer, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT organization_id, consumer, version, first_seen_at, last_seen_at
+		FROM usage_consumers
+		WHERE kind = $1 AND namespace = $2 AND name = $3 AND system = $4
+		ORDER BY consumer, organization_id, last_seen_at DESC, version`,
+		kind, namespace, name, system)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage consumers: %w", err)
+	}
+	defer rows.Close()
+
+	consumers := []*models.UsageConsumer{}
+	for rows.Next() {
+		u := &models.UsageConsumer{}
+		if err := rows.Scan(&u.OrganizationID, &u.Consumer, &u.Version, &u.FirstSeenAt, &u.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan usage consumer: %w", err)
+		}
+		consumers = append(consumers, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate usage consumers: %w", err)
+	}
+	return consumers, nil
+}
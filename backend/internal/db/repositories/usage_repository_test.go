@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func newUsageRepo(t *testing.T) (*UsageRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewUsageRepository(db), mock
+}
+
+func TestUsageRepository_Record(t *testing.T) {
+	repo, mock := newUsageRepo(t)
+	keyID := "key-1"
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("INSERT INTO usage_consumers")
+	prep.ExpectExec().WithArgs("org-1", "github.com/acme/infra", "module", "acme", "vpc", "aws", "1.2.0", &keyID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs("org-1", "github.com/acme/infra", "provider", "hashicorp", "aws", "", "5.0.0", &keyID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.Record(context.Background(), "org-1", "github.com/acme/infra", &keyID, []models.UsageRecord{
+		{Kind: models.UsageKindModule, Namespace: "acme", Name: "vpc", System: "aws", Version: "1.2.0"},
+		{Kind: models.UsageKindProvider, Namespace: "hashicorp", Name: "aws", Version: "5.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUsageRepository_ListConsumers(t *testing.T) {
+	repo, mock := newUsageRepo(t)
+	now := time.Now()
+	mock.ExpectQuery("SELECT .* FROM usage_consumers").
+		WithArgs("provider", "hashicorp", "aws", "").
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "consumer", "version", "first_seen_at", "last_seen_at"}).
+			AddRow("org-1", "ws-prod", "5.0.0", now, now))
+
+	got, err := repo.ListConsumers(context.Background(), models.UsageKindProvider, "hashicorp", "aws", "")
+	if err != nil || len(got) != 1 || got[0].Consumer != "ws-prod" || got[0].Version != "5.0.0" {
+		t.Fatalf("ListConsumers = %+v, %v", got, err)
+	}
+}
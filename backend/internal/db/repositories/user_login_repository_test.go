@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func newUserLoginRepo(t *testing.T) (*UserLoginRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewUserLoginRepository(db), mock
+}
+
+var userSearchCols = []string{"id", "email", "name", "oidc_sub", "created_at", "updated_at", "last_login_at", "auth_provider", "auth_issuer"}
+
+func TestUserLogin_RecordLogin(t *testing.T) {
+	repo, mock := newUserLoginRepo(t)
+	mock.ExpectExec("INSERT INTO user_logins.*ON CONFLICT \\(user_id\\) DO UPDATE").
+		WithArgs("user-1", "oidc", "https://idp.example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RecordLogin(context.Background(), "user-1", "oidc", "https://idp.example.com"); err != nil {
+		t.Fatalf("RecordLogin: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUserLogin_SearchUsersFilters(t *testing.T) {
+	repo, mock := newUserLoginRepo(t)
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	after := before.AddDate(-1, 0, 0)
+	active := true
+
+	// The role condition reuses the organization placeholder so both match
+	// the same membership.
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users u LEFT JOIN user_logins ul .*u.email ILIKE \$1 OR u.name ILIKE \$1.*om.organization_id = \$2.*rt.name = \$3 AND om.organization_id = \$2.*ul.auth_provider = \$4 OR ul.auth_issuer = \$4.*ul.last_login_at IS NULL OR ul.last_login_at < \$5.*ul.last_login_at > \$6.*= \$7`).
+		WithArgs("%ali%", "org-1", "admin", "ldap", before, after, true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`ORDER BY ul.last_login_at DESC NULLS LAST, u.id\s+LIMIT \$8 OFFSET \$9`).
+		WithArgs("%ali%", "org-1", "admin", "ldap", before, after, true, 10, 20).
+		WillReturnRows(sqlmock.NewRows(userSearchCols).
+			AddRow("user-1", "alice@example.com", "Alice", nil, after, after, before.Add(-time.Hour), "ldap", "").
+			AddRow("user-2", "alina@example.com", "Alina", nil, after, after, nil, nil, nil))
+	mock.ExpectQuery("WHERE om.user_id = ANY\\(\\$1\\)").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"user_id", "organization_id", "organization_name", "role_template_id", "created_at",
+			"role_template_name", "role_template_display_name", "role_template_scopes",
+		}).AddRow("user-1", "org-1", "acme", nil, after, "admin", "Admin", []byte(`["admin"]`)))
+
+	users, total, err := repo.SearchUsers(context.Background(), UserSearchFilter{
+		Query: "ali", OrganizationID: "org-1", Role: "admin", AuthProvider: "ldap",
+		LastLoginBefore: &before, LastLoginAfter: &after, Active: &active,
+		Sort: "last_login", Desc: true, Limit: 10, Offset: 20,
+	})
+	if err != nil {
+		t.Fatalf("SearchUsers: %v", err)
+	}
+	if total != 2 || len(users) != 2 {
+		t.Fatalf("got %d users of %d, want 2 of 2", len(users), total)
+	}
+	if users[0].AuthProvider == nil || *users[0].AuthProvider != "ldap" || users[0].AuthIssuer != nil {
+		t.Errorf("user-1 login = %v/%v, want ldap with no issuer", users[0].AuthProvider, users[0].AuthIssuer)
+	}
+	if len(users[0].Memberships) != 1 || users[0].Memberships[0].RoleTemplateScopes[0] != "admin" {
+		t.Errorf("user-1 memberships = %+v", users[0].Memberships)
+	}
+	if users[1].LastLoginAt != nil || users[1].Memberships == nil {
+		t.Errorf("user-2 = %+v, want no login and empty memberships", users[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUserLogin_SearchUsersNoFilterDefaultsToCreatedAt(t *testing.T) {
+	repo, mock := newUserLoginRepo(t)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users u LEFT JOIN user_logins ul ON ul.user_id = u.id\s*$`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`ORDER BY u.created_at ASC, u.id\s+LIMIT \$1 OFFSET \$2`).
+		WithArgs(20, 0).
+		WillReturnRows(sqlmock.NewRows(userSearchCols))
+
+	users, total, err := repo.SearchUsers(context.Background(), UserSearchFilter{Limit: 20})
+	if err != nil || total != 0 || len(users) != 0 || users == nil {
+		t.Fatalf("SearchUsers = %v, %d, %v; want empty non-nil slice", users, total, err)
+	}
+}
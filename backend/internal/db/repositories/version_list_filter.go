@@ -0,0 +1,108 @@
+// version_list_filter.go defines the filters and ordering shared by the admin
+// module and provider version listings.
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Sort orders of the admin version listings.
+const (
+	VersionSortSemver    = "semver"
+	VersionSortPublished = "published"
+)
+
+// VersionListFilter narrows an admin version listing. Nil and empty fields
+// mean "no filter".
+type VersionListFilter struct {
+	Deprecated     *bool
+	Archived       *bool // module versions only
+	PublishedAfter *time.Time
+	PublishedBy    string              // user ID
+	Constraint     version.Constraints // evaluated in Go over the SQL-filtered candidates
+	Sort           string              // VersionSortSemver (default) | VersionSortPublished
+	Ascending      bool
+	Limit          int
+	Offset         int
+}
+
+// sqlConditions returns the SQL-side filters as " AND ..." conditions on the
+// version table aliased alias, with placeholders numbered from len(args)+1,
+// and the extended argument list.
+func (f VersionListFilter) sqlConditions(alias string, args []interface{}) (string, []interface{}) {
+	var b strings.Builder
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		fmt.Fprintf(&b, " AND "+cond, alias, len(args))
+	}
+	if f.Deprecated != nil {
+		add("COALESCE(%s.deprecated, false) = $%d", *f.Deprecated)
+	}
+	if f.PublishedAfter != nil {
+		add("%s.created_at > $%d", *f.PublishedAfter)
+	}
+	if f.PublishedBy != "" {
+		add("%s.published_by::text = $%d", f.PublishedBy)
+	}
+	return b.String(), args
+}
+
+// pageVersions applies the constraint filter, ordering and limit/offset to
+// the SQL-filtered candidates and returns the page plus the total number of
+// candidates that matched. Versions that do not parse never satisfy a
+// constraint and sort below every parseable version.
+func pageVersions[T any](candidates []T, f VersionListFilter, versionOf func(T) string, publishedAt func(T) time.Time) ([]T, int) {
+	type entry struct {
+		item T
+		v    *version.Version
+	}
+	entries := make([]entry, 0, len(candidates))
+	for _, c := range candidates {
+		v, err := version.NewVersion(versionOf(c))
+		if err != nil {
+			v = nil
+		}
+		if f.Constraint != nil && (v == nil || !f.Constraint.Check(v)) {
+			continue
+		}
+		entries = append(entries, entry{item: c, v: v})
+	}
+
+	less := func(a, b entry) bool {
+		if f.Sort == VersionSortPublished {
+			return publishedAt(a.item).Before(publishedAt(b.item))
+		}
+		switch {
+		case a.v == nil && b.v == nil:
+			return versionOf(a.item) < versionOf(b.item)
+		case a.v == nil:
+			return true
+		case b.v == nil:
+			return false
+		}
+		return a.v.LessThan(b.v)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if f.Ascending {
+			return less(entries[i], entries[j])
+		}
+		return less(entries[j], entries[i])
+	})
+
+	total := len(entries)
+	start := min(max(f.Offset, 0), total)
+	end := total
+	if f.Limit > 0 {
+		end = min(start+f.Limit, total)
+	}
+	page := make([]T, 0, end-start)
+	for _, e := range entries[start:end] {
+		page = append(page, e.item)
+	}
+	return page, total
+}
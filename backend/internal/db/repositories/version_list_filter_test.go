@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-version"
+)
+
+type listedVersion struct {
+	v  string
+	at time.Time
+}
+
+func listedVersions(t0 time.Time, vs ...string) []listedVersion {
+	out := make([]listedVersion, len(vs))
+	for i, v := range vs {
+		out[i] = listedVersion{v: v, at: t0.Add(time.Duration(i) * time.Hour)}
+	}
+	return out
+}
+
+func pageOf(items []listedVersion, f VersionListFilter) ([]string, int) {
+	page, total := pageVersions(items, f,
+		func(l listedVersion) string { return l.v },
+		func(l listedVersion) time.Time { return l.at })
+	out := make([]string, len(page))
+	for i, l := range page {
+		out[i] = l.v
+	}
+	return out, total
+}
+
+func TestPageVersions_SemverDescendingByDefault(t *testing.T) {
+	items := listedVersions(time.Now(), "1.2.0", "1.10.0", "not-a-version", "1.9.1")
+	got, total := pageOf(items, VersionListFilter{})
+	if want := "1.10.0,1.9.1,1.2.0,not-a-version"; strings.Join(got, ",") != want {
+		t.Errorf("order = %v, want %s", got, want)
+	}
+	if total != 4 {
+		t.Errorf("total = %d, want 4", total)
+	}
+}
+
+func TestPageVersions_PublishedAscending(t *testing.T) {
+	items := listedVersions(time.Now(), "2.0.0", "1.0.0", "1.5.0")
+	got, _ := pageOf(items, VersionListFilter{Sort: VersionSortPublished, Ascending: true})
+	if want := "2.0.0,1.0.0,1.5.0"; strings.Join(got, ",") != want {
+		t.Errorf("order = %v, want %s", got, want)
+	}
+}
+
+func TestPageVersions_ConstraintCountsBeforePaging(t *testing.T) {
+	c, err := version.NewConstraint("~> 1.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := listedVersions(time.Now(), "1.3.0", "1.4.0", "1.4.2", "1.9.0", "2.0.0", "junk")
+	got, total := pageOf(items, VersionListFilter{Constraint: c, Limit: 2, Offset: 1})
+	if total != 3 {
+		t.Errorf("total = %d, want 3 (1.4.0, 1.4.2, 1.9.0)", total)
+	}
+	if want := "1.4.2,1.4.0"; strings.Join(got, ",") != want {
+		t.Errorf("page = %v, want %s", got, want)
+	}
+}
+
+func TestPageVersions_OffsetPastEnd(t *testing.T) {
+	got, total := pageOf(listedVersions(time.Now(), "1.0.0"), VersionListFilter{Limit: 10, Offset: 20})
+	if len(got) != 0 || total != 1 {
+		t.Errorf("got %v, total %d; want empty page, total 1", got, total)
+	}
+}
+
+func TestVersionListFilter_SQLConditions(t *testing.T) {
+	deprecated := true
+	after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := VersionListFilter{Deprecated: &deprecated, PublishedAfter: &after, PublishedBy: "user-1"}
+
+	conds, args := f.sqlConditions("mv", []interface{}{"mod-1"})
+	want := " AND COALESCE(mv.deprecated, false) = $2 AND mv.created_at > $3 AND mv.published_by::text = $4"
+	if conds != want {
+		t.Errorf("conds = %q, want %q", conds, want)
+	}
+	if len(args) != 4 || args[1] != true || args[2] != after || args[3] != "user-1" {
+		t.Errorf("args = %v", args)
+	}
+}
@@ -0,0 +1,120 @@
+// Package transient classifies database errors caused by a briefly unavailable
+// connection (failover, restart, dropped TCP session) and retries idempotent
+// reads across them. It is used by the unauthenticated protocol GET handlers so
+// a `terraform init` that lands on a connection the pool has not yet noticed
+// is dead gets a second attempt instead of an immediate 500.
+package transient
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RetryAfterSeconds is the Retry-After value handlers send alongside a 503
+// when a query still fails with a transient error after retrying.
+const RetryAfterSeconds = "5"
+
+// retryDelay is the pause before the single retry. Long enough for the pool to
+// discard the broken connection and dial a new one, short enough not to be
+// noticeable to the client.
+var retryDelay = 100 * time.Millisecond
+
+// transientMessages are substrings of connection-level failures that drivers
+// and intermediate layers sometimes surface only as text (e.g. when an error
+// was wrapped with %v rather than %w).
+var transientMessages = []string{
+	"bad connection",
+	"connection reset by peer",
+	"connection refused",
+	"broken pipe",
+	"unexpected eof",
+	"server closed the connection unexpectedly",
+	"sql: database is closed",
+}
+
+// IsTransient reports whether err looks like a connection-level failure that
+// may succeed if the same idempotent query is retried on a fresh connection.
+// Context cancellation and deadlines are never transient: the caller has gone
+// away or run out of time.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code.Class() == "08": // connection_exception
+			return true
+		case pqErr.Code == "57P01", pqErr.Code == "57P02", pqErr.Code == "57P03":
+			// admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// Do runs fn and, if it fails with a transient error, runs it exactly once
+// more after a short delay. fn must be idempotent — only wrap reads. The
+// error from the last attempt is returned unchanged so callers can still
+// classify it with IsTransient.
+func Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	if !IsTransient(err) || ctx.Err() != nil {
+		return err
+	}
+
+	timer := time.NewTimer(retryDelay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return err
+	case <-timer.C:
+	}
+	return fn(ctx)
+}
+
+// Value is Do for a read that returns a single value.
+func Value[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) (T, error) {
+	var v T
+	err := Do(ctx, func(ctx context.Context) error {
+		var err error
+		v, err = fn(ctx)
+		return err
+	})
+	return v, err
+}
@@ -0,0 +1,129 @@
+package transient
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func init() {
+	retryDelay = time.Millisecond
+}
+
+func TestIsTransient(t *testing.T) {
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"bad conn", driver.ErrBadConn, true},
+		{"conn done", sql.ErrConnDone, true},
+		{"unexpected eof", fmt.Errorf("failed to get module: %w", io.ErrUnexpectedEOF), true},
+		{"connection reset", fmt.Errorf("failed to get module: %w", reset), true},
+		{"connection refused", syscall.ECONNREFUSED, true},
+		{"wrapped with %v", fmt.Errorf("failed: %v", reset), true},
+		{"pq admin shutdown", &pq.Error{Code: "57P01"}, true},
+		{"pq connection failure", &pq.Error{Code: "08006"}, true},
+		{"pq unique violation", &pq.Error{Code: "23505"}, false},
+		{"no rows", sql.ErrNoRows, false},
+		{"canceled", context.Canceled, false},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), false},
+		{"plain", errors.New("syntax error at or near SELECT"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDo_RetriesOnceOnTransientError(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestDo_GivesUpAfterOneRetry(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		return driver.ErrBadConn
+	})
+	if !IsTransient(err) {
+		t.Errorf("Do() error = %v, want transient error", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestDo_NoRetryOnPermanentError(t *testing.T) {
+	calls := 0
+	perm := errors.New("permission denied for table modules")
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		return perm
+	})
+	if !errors.Is(err, perm) {
+		t.Errorf("Do() error = %v, want %v", err, perm)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestDo_NoRetryAfterContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Do(ctx, func(context.Context) error {
+		calls++
+		cancel()
+		return io.EOF
+	})
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("Do() error = %v, want io.EOF", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestValue(t *testing.T) {
+	calls := 0
+	got, err := Value(context.Background(), func(context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", sql.ErrConnDone
+		}
+		return "ok", nil
+	})
+	if err != nil || got != "ok" {
+		t.Errorf("Value() = %q, %v; want ok, nil", got, err)
+	}
+}
@@ -0,0 +1,155 @@
+// Package downloadstats counts provider downloads per version and platform for
+// the popularity stats behind GET /api/v1/admin/providers/:namespace/:type/stats
+// and the unused-platform markers on the mirror status.
+//
+// Downloads are counted in memory and flushed to the database in batches
+// (one upsert per provider/version/platform/day), so the download path never
+// waits on a write. Counts still pending when the process dies are lost; Stop
+// flushes them on a graceful shutdown.
+//
+// A nil *Recorder is valid and records nothing, so handlers wired without one
+// (tests, tools) need no nil checks.
+package downloadstats
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// DefaultFlushInterval is how often pending counts are written out.
+const DefaultFlushInterval = 30 * time.Second
+
+// Store persists batches of download counts. repositories.ProviderRepository
+// implements it.
+type Store interface {
+	AddDownloadStats(ctx context.Context, counts []models.ProviderDownloadCount) error
+}
+
+type key struct {
+	providerID string
+	version    string
+	os         string
+	arch       string
+	day        time.Time
+}
+
+// Recorder buffers download counts and flushes them to a Store.
+type Recorder struct {
+	store    Store
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	pending map[key]int64
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRecorder creates a Recorder that flushes to store every interval
+// (DefaultFlushInterval when interval is zero) once started.
+func NewRecorder(store Store, interval time.Duration) *Recorder {
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	return &Recorder{
+		store:    store,
+		interval: interval,
+		now:      time.Now,
+		pending:  make(map[key]int64),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Record counts one download of a provider version's os/arch archive.
+func (r *Recorder) Record(providerID, version, os, arch string) {
+	if r == nil || providerID == "" {
+		return
+	}
+	k := key{providerID: providerID, version: version, os: os, arch: arch, day: day(r.now())}
+	r.mu.Lock()
+	r.pending[k]++
+	r.mu.Unlock()
+}
+
+// Flush writes every pending count to the store. On failure the counts are
+// put back so the next flush retries them.
+func (r *Recorder) Flush(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make(map[key]int64)
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	counts := make([]models.ProviderDownloadCount, 0, len(batch))
+	for k, n := range batch {
+		counts = append(counts, models.ProviderDownloadCount{
+			ProviderID: k.providerID,
+			Version:    k.version,
+			OS:         k.os,
+			Arch:       k.arch,
+			Day:        k.day,
+			Downloads:  n,
+		})
+	}
+
+	if err := r.store.AddDownloadStats(ctx, counts); err != nil {
+		r.mu.Lock()
+		for k, n := range batch {
+			r.pending[k] += n
+		}
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Name identifies the recorder in the jobs.Registry.
+func (r *Recorder) Name() string { return "provider-download-stats" }
+
+// Start flushes on the recorder's interval until ctx is cancelled or Stop is
+// called. It blocks; the jobs.Registry runs it in its own goroutine.
+func (r *Recorder) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.flushAndLog(ctx)
+		case <-ctx.Done():
+			return nil
+		case <-r.stopCh:
+			return nil
+		}
+	}
+}
+
+// Stop ends the flush loop and writes out whatever is still pending.
+func (r *Recorder) Stop() error {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return r.Flush(ctx)
+}
+
+func (r *Recorder) flushAndLog(ctx context.Context) {
+	if err := r.Flush(ctx); err != nil {
+		slog.Warn("failed to flush provider download stats", "error", err)
+	}
+}
+
+// day truncates t to its UTC calendar day, the granularity counts are kept at.
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
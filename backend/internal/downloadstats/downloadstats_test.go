@@ -0,0 +1,105 @@
+package downloadstats
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+type fakeStore struct {
+	err     error
+	batches [][]models.ProviderDownloadCount
+}
+
+func (f *fakeStore) AddDownloadStats(_ context.Context, counts []models.ProviderDownloadCount) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.batches = append(f.batches, counts)
+	return nil
+}
+
+func sortCounts(c []models.ProviderDownloadCount) {
+	sort.Slice(c, func(i, j int) bool {
+		if !c[i].Day.Equal(c[j].Day) {
+			return c[i].Day.Before(c[j].Day)
+		}
+		return c[i].OS+c[i].Arch < c[j].OS+c[j].Arch
+	})
+}
+
+func TestRecorder_FlushAggregates(t *testing.T) {
+	store := &fakeStore{}
+	r := NewRecorder(store, time.Minute)
+	now := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	r.Record("prov-1", "5.0.0", "linux", "amd64")
+	r.Record("prov-1", "5.0.0", "linux", "amd64")
+	r.Record("prov-1", "5.0.0", "darwin", "arm64")
+	now = now.Add(2 * time.Minute) // next UTC day
+	r.Record("prov-1", "5.0.0", "linux", "amd64")
+	r.Record("", "5.0.0", "linux", "amd64") // unknown provider: ignored
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.batches) != 1 {
+		t.Fatalf("flushed %d batches, want 1", len(store.batches))
+	}
+	got := store.batches[0]
+	sortCounts(got)
+	day1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	want := []models.ProviderDownloadCount{
+		{ProviderID: "prov-1", Version: "5.0.0", OS: "darwin", Arch: "arm64", Day: day1, Downloads: 1},
+		{ProviderID: "prov-1", Version: "5.0.0", OS: "linux", Arch: "amd64", Day: day1, Downloads: 2},
+		{ProviderID: "prov-1", Version: "5.0.0", OS: "linux", Arch: "amd64", Day: day2, Downloads: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("counts = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("counts[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.batches) != 1 {
+		t.Errorf("empty flush wrote a batch")
+	}
+}
+
+func TestRecorder_FlushFailureKeepsCounts(t *testing.T) {
+	store := &fakeStore{err: errors.New("db down")}
+	r := NewRecorder(store, 0)
+
+	r.Record("prov-1", "1.0.0", "linux", "amd64")
+	if err := r.Flush(context.Background()); err == nil {
+		t.Fatal("Flush succeeded against a failing store")
+	}
+	r.Record("prov-1", "1.0.0", "linux", "amd64")
+
+	store.err = nil
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.batches) != 1 || len(store.batches[0]) != 1 || store.batches[0][0].Downloads != 2 {
+		t.Errorf("batches after retry = %+v, want one count of 2", store.batches)
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+	r.Record("prov-1", "1.0.0", "linux", "amd64")
+	if err := r.Flush(context.Background()); err != nil {
+		t.Errorf("nil Flush = %v", err)
+	}
+}
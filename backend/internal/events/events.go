@@ -0,0 +1,242 @@
+// Package events is the registry's in-process bus for security-relevant
+// organization events: membership and role changes, API key lifecycle, SCM
+// token connections, and provider and module tier changes. Handlers Publish an event and move on; subscribers,
+// such as the organization event webhooks, receive it on the bus's own
+// goroutine, so no request waits on outbound HTTP.
+//
+// Events are held in memory only. Those still queued when the process dies are
+// lost; Stop delivers them on a graceful shutdown.
+//
+// A nil *Bus is valid and drops every event, so handlers wired without one
+// (tests, tools) need no nil checks.
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion is the version of the Event JSON shape. It is bumped when a
+// field is removed or changes meaning; adding a field or an event type does
+// not bump it.
+const SchemaVersion = 1
+
+// Event types.
+const (
+	TypeMemberAdded         = "organization.member_added"
+	TypeMemberRemoved       = "organization.member_removed"
+	TypeMemberRoleChanged   = "organization.member_role_changed"
+	TypeAPIKeyCreated       = "api_key.created"
+	TypeAPIKeyRotated       = "api_key.rotated"
+	TypeAPIKeyRevoked       = "api_key.revoked"
+	TypeSCMTokenConnected   = "scm.token_connected"
+	TypeProviderTierChanged = "provider.tier_changed"
+	TypeModuleTierChanged   = "module.tier_changed"
+
+	// TypeTest is the sample event sent when testing a webhook. It is never
+	// published on the bus.
+	TypeTest = "event_webhook.test"
+)
+
+// Types lists the event types a subscription may filter on.
+var Types = []string{
+	TypeMemberAdded,
+	TypeMemberRemoved,
+	TypeMemberRoleChanged,
+	TypeAPIKeyCreated,
+	TypeAPIKeyRotated,
+	TypeAPIKeyRevoked,
+	TypeSCMTokenConnected,
+	TypeProviderTierChanged,
+	TypeModuleTierChanged,
+}
+
+// apiKeyDataFields are the Data keys every API key event carries.
+var apiKeyDataFields = []string{"api_key_id", "name", "key_prefix", "scopes", "user_id", "expires_at"}
+
+// DataFields lists, for each event type including TypeTest, the keys its
+// Data may carry under SchemaVersion. A key that does not apply to an
+// occurrence (expires_at for a key that never expires) is left out of it.
+var DataFields = map[string][]string{
+	TypeMemberAdded:         {"user_id", "role_template_id"},
+	TypeMemberRemoved:       {"user_id", "role_template_id"},
+	TypeMemberRoleChanged:   {"user_id", "role_template_id", "previous_role_template_id"},
+	TypeAPIKeyCreated:       apiKeyDataFields,
+	TypeAPIKeyRotated:       append(append([]string{}, apiKeyDataFields...), "replaced_api_key_id", "old_key_status"),
+	TypeAPIKeyRevoked:       apiKeyDataFields,
+	TypeSCMTokenConnected:   {"user_id", "scm_provider_id", "provider_type", "method", "replaced"},
+	TypeProviderTierChanged: {"provider_id", "namespace", "type", "tier", "previous_tier"},
+	TypeModuleTierChanged:   {"module_id", "namespace", "name", "system", "tier", "previous_tier"},
+	TypeTest:                {"webhook_id"},
+}
+
+// IsType reports whether t is one of Types.
+func IsType(t string) bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultBufferSize is how many events may wait for delivery before Publish
+// starts dropping them.
+const DefaultBufferSize = 1024
+
+// Event is one security-relevant occurrence in an organization. Data holds
+// the type-specific fields (user_id, role_template_id, api_key_id, ...);
+// absent values are omitted rather than sent empty.
+type Event struct {
+	ID             string            `json:"id"`
+	Type           string            `json:"type"`
+	SchemaVersion  int               `json:"schema_version"`
+	OrganizationID string            `json:"organization_id"`
+	ActorID        string            `json:"actor_id,omitempty"` // the user who caused it; unset for system actions
+	OccurredAt     time.Time         `json:"occurred_at"`
+	Data           map[string]string `json:"data"`
+}
+
+// Handler receives published events. It runs on the bus goroutine, one event
+// at a time, and should bound its own work.
+type Handler func(ctx context.Context, e Event)
+
+// Bus queues published events and hands them to its subscribers.
+type Bus struct {
+	queue chan Event
+	now   func() time.Time
+
+	mu       sync.RWMutex
+	handlers []Handler
+
+	started  atomic.Bool
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewBus creates a Bus holding up to buffer queued events (DefaultBufferSize
+// when buffer is zero). Events are delivered once it is started.
+func NewBus(buffer int) *Bus {
+	if buffer <= 0 {
+		buffer = DefaultBufferSize
+	}
+	return &Bus{
+		queue:  make(chan Event, buffer),
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Subscribe adds h to the handlers every event is delivered to.
+func (b *Bus) Subscribe(h Handler) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.handlers = append(b.handlers, h)
+	b.mu.Unlock()
+}
+
+// Publish queues e without blocking, filling in its ID, schema version and
+// time when unset. Events without an organization are ignored, and an event
+// is dropped with a warning when the queue is full.
+func (b *Bus) Publish(e Event) {
+	if b == nil || e.OrganizationID == "" {
+		return
+	}
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	if e.SchemaVersion == 0 {
+		e.SchemaVersion = SchemaVersion
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = b.now().UTC()
+	}
+	if e.Data == nil {
+		e.Data = map[string]string{}
+	}
+	select {
+	case b.queue <- e:
+	default:
+		slog.Warn("event bus full, dropping event",
+			"event_id", e.ID, "type", e.Type, "organization_id", e.OrganizationID)
+	}
+}
+
+// Name identifies the bus in the jobs.Registry.
+func (b *Bus) Name() string { return "event-bus" }
+
+// Start delivers queued events until ctx is cancelled or Stop is called. It
+// blocks; the jobs.Registry runs it in its own goroutine.
+func (b *Bus) Start(ctx context.Context) error {
+	b.started.Store(true)
+	defer close(b.done)
+	for {
+		select {
+		case e := <-b.queue:
+			b.dispatch(ctx, e)
+		case <-ctx.Done():
+			return nil
+		case <-b.stopCh:
+			b.drain()
+			return nil
+		}
+	}
+}
+
+// Stop ends the delivery loop once the events already queued have been
+// delivered, waiting up to 30 seconds.
+func (b *Bus) Stop() error {
+	b.stopOnce.Do(func() { close(b.stopCh) })
+	if !b.started.Load() {
+		return nil
+	}
+	select {
+	case <-b.done:
+	case <-time.After(30 * time.Second):
+		slog.Warn("event bus: stopped before every queued event was delivered")
+	}
+	return nil
+}
+
+// drain delivers whatever is still queued after Stop.
+func (b *Bus) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for {
+		select {
+		case e := <-b.queue:
+			b.dispatch(ctx, e)
+		default:
+			return
+		}
+	}
+}
+
+func (b *Bus) dispatch(ctx context.Context, e Event) {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+	for _, h := range handlers {
+		b.call(ctx, h, e)
+	}
+}
+
+// call runs one handler, so a panicking subscriber neither stops the bus nor
+// keeps the event from the others.
+func (b *Bus) call(ctx context.Context, h Handler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("event bus: handler panicked", "event_id", e.ID, "type", e.Type, "panic", r)
+		}
+	}()
+	h(ctx, e)
+}
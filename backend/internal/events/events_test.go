@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBus_NilIsNoOp(t *testing.T) {
+	var b *Bus
+	b.Publish(Event{Type: TypeMemberAdded, OrganizationID: "org-1"})
+	b.Subscribe(func(context.Context, Event) {})
+}
+
+func TestBus_PublishFillsDefaultsAndDelivers(t *testing.T) {
+	b := NewBus(4)
+	var (
+		mu  sync.Mutex
+		got []Event
+	)
+	b.Subscribe(func(_ context.Context, e Event) {
+		mu.Lock()
+		got = append(got, e)
+		mu.Unlock()
+	})
+	b.Subscribe(func(context.Context, Event) { panic("bad subscriber") })
+
+	b.Publish(Event{Type: TypeAPIKeyCreated, OrganizationID: "org-1", Data: map[string]string{"api_key_id": "key-1"}})
+	b.Publish(Event{Type: TypeAPIKeyCreated}) // no organization: ignored
+
+	done := make(chan struct{})
+	go func() {
+		_ = b.Start(context.Background())
+		close(done)
+	}()
+	// Stop delivers what is already queued before returning.
+	time.Sleep(10 * time.Millisecond)
+	if err := b.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 {
+		t.Fatalf("delivered %d events, want 1", len(got))
+	}
+	e := got[0]
+	if e.ID == "" || e.SchemaVersion != SchemaVersion || e.OccurredAt.IsZero() || e.Data["api_key_id"] != "key-1" {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestBus_DropsWhenFull(t *testing.T) {
+	b := NewBus(1)
+	b.Publish(Event{Type: TypeMemberAdded, OrganizationID: "org-1"})
+	b.Publish(Event{Type: TypeMemberRemoved, OrganizationID: "org-1"})
+	if n := len(b.queue); n != 1 {
+		t.Errorf("queued %d events, want 1", n)
+	}
+}
+
+func TestBus_StopWithoutStart(t *testing.T) {
+	b := NewBus(0)
+	start := time.Now()
+	if err := b.Stop(); err != nil || time.Since(start) > time.Second {
+		t.Errorf("Stop = %v after %s", err, time.Since(start))
+	}
+}
+
+func TestIsType(t *testing.T) {
+	if !IsType(TypeSCMTokenConnected) || IsType(TypeTest) || IsType("module.published") {
+		t.Error("IsType does not match Types")
+	}
+}
@@ -0,0 +1,269 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/pkg/client"
+)
+
+// These are the contract tests of pkg/client: every method runs against the
+// real router, so a server change that breaks a shape the client relies on
+// fails here.
+
+// hostTransport addresses requests to the configured base URL's host, as
+// registry.do does.
+type hostTransport struct{ next http.RoundTripper }
+
+func (t hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Host = strings.TrimPrefix(baseURL, "http://")
+	return t.next.RoundTrip(req)
+}
+
+// newClient serves the registry over HTTP and returns a client for it,
+// authenticated with token when it is non-empty.
+func newClient(t *testing.T, token string) *client.Client {
+	t.Helper()
+	srv := httptest.NewServer(reg.router)
+	t.Cleanup(srv.Close)
+	opts := []client.Option{client.WithHTTPClient(&http.Client{Transport: hostTransport{http.DefaultTransport}})}
+	if token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	c, err := client.New(srv.URL, opts...)
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+	return c
+}
+
+func TestClientModules(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t, reg.token)
+	archive := moduleArchive(t, fixtureFile{"main.tf", moduleMainTF})
+
+	for _, m := range []struct{ name, version string }{
+		{"vpc", "1.0.0"}, {"vpc", "1.1.0"}, {"subnet", "1.0.0"}, {"route", "1.0.0"},
+	} {
+		published, err := c.PublishModule(ctx, client.PublishModuleInput{
+			Namespace: "clientmod",
+			Name:      m.name,
+			System:    "aws",
+			Version:   m.version,
+			Archive:   bytes.NewReader(archive),
+		})
+		if err != nil {
+			t.Fatalf("PublishModule %s %s: %v", m.name, m.version, err)
+		}
+		if published.Checksum != sha256Hex(archive) || published.Version != m.version {
+			t.Errorf("PublishModule = %+v", published)
+		}
+	}
+
+	_, err := c.PublishModule(ctx, client.PublishModuleInput{
+		Namespace: "clientmod", Name: "vpc", System: "aws", Version: "1.0.0", Archive: bytes.NewReader(archive),
+	})
+	if !client.IsConflict(err) {
+		t.Errorf("republish err = %v, want a conflict", err)
+	}
+
+	versions, err := c.ModuleVersions(ctx, "clientmod", "vpc", "aws")
+	if err != nil {
+		t.Fatalf("ModuleVersions: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Errorf("ModuleVersions = %+v, want 2 versions", versions)
+	}
+
+	_, err = c.ModuleVersions(ctx, "clientmod", "missing", "aws")
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || len(apiErr.Errors) == 0 {
+		t.Errorf("missing module err = %#v, want a 404 with the protocol envelope", err)
+	}
+
+	// A page size of one makes the iterator walk three pages.
+	var names []string
+	for m, err := range c.AllModules(ctx, client.ModuleSearchOptions{Namespace: "clientmod", Sort: "name", Order: "asc", Limit: 1}) {
+		if err != nil {
+			t.Fatalf("AllModules: %v", err)
+		}
+		names = append(names, m.Name)
+	}
+	if strings.Join(names, ",") != "route,subnet,vpc" {
+		t.Errorf("AllModules = %v", names)
+	}
+}
+
+func TestClientProviders(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t, reg.token)
+	archive := providerArchive(t, fixtureFile{"terraform-provider-gadget_v2.0.0", "#!/bin/sh\necho gadget\n"})
+
+	for _, arch := range []string{"amd64", "arm64"} {
+		published, err := c.PublishProvider(ctx, client.PublishProviderInput{
+			Namespace: "clientprov",
+			Type:      "gadget",
+			Protocols: []string{"6.0"},
+			Archive:   bytes.NewReader(archive),
+			// Version, OS and Arch are read from the name.
+			Filename: "terraform-provider-gadget_2.0.0_linux_" + arch + ".zip",
+		})
+		if err != nil {
+			t.Fatalf("PublishProvider %s: %v", arch, err)
+		}
+		if published.Version != "2.0.0" || published.Arch != arch || published.Checksum != sha256Hex(archive) {
+			t.Errorf("PublishProvider = %+v", published)
+		}
+	}
+
+	versions, err := c.ProviderVersions(ctx, "clientprov", "gadget")
+	if err != nil {
+		t.Fatalf("ProviderVersions: %v", err)
+	}
+	if len(versions) != 1 || len(versions[0].Platforms) != 2 || strings.Join(versions[0].Protocols, ",") != "6.0" {
+		t.Errorf("ProviderVersions = %+v", versions)
+	}
+
+	var found []client.ProviderSummary
+	for p, err := range c.AllProviders(ctx, client.ProviderSearchOptions{Namespace: "clientprov"}) {
+		if err != nil {
+			t.Fatalf("AllProviders: %v", err)
+		}
+		found = append(found, p)
+	}
+	if len(found) != 1 || found[0].Type != "gadget" || found[0].LatestVersion != "2.0.0" {
+		t.Errorf("AllProviders = %+v", found)
+	}
+}
+
+func TestClientMirrors(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t, reg.token)
+
+	name, upstream, disabled := "client-contract", "https://registry.terraform.io", false
+	created, err := c.CreateMirror(ctx, client.MirrorInput{
+		Name:                &name,
+		UpstreamRegistryURL: &upstream,
+		NamespaceFilter:     []string{"hashicorp"},
+		Enabled:             &disabled,
+	})
+	if err != nil {
+		t.Fatalf("CreateMirror: %v", err)
+	}
+	if created.Name != name || created.Enabled || created.NamespaceFilter == nil || *created.NamespaceFilter != `["hashicorp"]` {
+		t.Errorf("CreateMirror = %+v", created)
+	}
+
+	hours := 12
+	updated, err := c.UpdateMirror(ctx, created.ID, client.MirrorInput{SyncIntervalHours: &hours})
+	if err != nil {
+		t.Fatalf("UpdateMirror: %v", err)
+	}
+	if updated.SyncIntervalHours != 12 || updated.Name != name {
+		t.Errorf("UpdateMirror = %+v", updated)
+	}
+
+	mirrors, err := c.ListMirrors(ctx, false)
+	if err != nil {
+		t.Fatalf("ListMirrors: %v", err)
+	}
+	listed := false
+	for _, m := range mirrors {
+		listed = listed || m.ID == created.ID
+	}
+	if !listed {
+		t.Errorf("ListMirrors does not include %s", created.ID)
+	}
+
+	status, err := c.GetMirrorStatus(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetMirrorStatus: %v", err)
+	}
+	if status.Mirror.ID != created.ID || status.CurrentSync != nil {
+		t.Errorf("GetMirrorStatus = %+v", status)
+	}
+
+	if err := c.DeleteMirror(ctx, created.ID, false); err != nil {
+		t.Fatalf("DeleteMirror: %v", err)
+	}
+	if _, err := c.GetMirror(ctx, created.ID); !client.IsNotFound(err) {
+		t.Errorf("GetMirror after delete err = %v, want not found", err)
+	}
+
+	if _, err := newClient(t, "").ListMirrors(ctx, false); !client.IsUnauthorized(err) {
+		t.Errorf("anonymous ListMirrors err = %v, want unauthorized", err)
+	}
+}
+
+func TestClientAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t, reg.token)
+
+	created, err := c.CreateAPIKey(ctx, client.CreateAPIKeyInput{
+		Name:           "client-contract",
+		OrganizationID: reg.orgID,
+		Scopes:         []string{"modules:read"},
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	if created.Key == "" || !strings.HasPrefix(created.Key, created.KeyPrefix) {
+		t.Errorf("CreateAPIKey = %+v", created)
+	}
+
+	// The new key authenticates the client on its own.
+	got, err := newClient(t, created.Key).GetAPIKey(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetAPIKey with the new key: %v", err)
+	}
+	if got.Name != "client-contract" {
+		t.Errorf("GetAPIKey = %+v", got)
+	}
+
+	rename := "client-contract-renamed"
+	updated, err := c.UpdateAPIKey(ctx, created.ID, client.UpdateAPIKeyInput{Name: &rename})
+	if err != nil {
+		t.Fatalf("UpdateAPIKey: %v", err)
+	}
+	if updated.Name != rename {
+		t.Errorf("UpdateAPIKey = %+v", updated)
+	}
+
+	keys, err := c.ListAPIKeys(ctx, reg.orgID)
+	if err != nil {
+		t.Fatalf("ListAPIKeys: %v", err)
+	}
+	listed := false
+	for _, k := range keys {
+		listed = listed || (k.ID == created.ID && k.Name == rename)
+	}
+	if !listed {
+		t.Errorf("ListAPIKeys does not include %s", created.ID)
+	}
+
+	rotated, err := c.RotateAPIKey(ctx, created.ID, 0)
+	if err != nil {
+		t.Fatalf("RotateAPIKey: %v", err)
+	}
+	if rotated.NewKey.Key == "" || rotated.NewKey.ID == created.ID || rotated.OldKeyStatus != "revoked" {
+		t.Errorf("RotateAPIKey = %+v", rotated)
+	}
+	if _, err := c.GetAPIKey(ctx, created.ID); !client.IsNotFound(err) {
+		t.Errorf("GetAPIKey of the rotated key err = %v, want not found", err)
+	}
+
+	if err := c.DeleteAPIKey(ctx, rotated.NewKey.ID); err != nil {
+		t.Fatalf("DeleteAPIKey: %v", err)
+	}
+	if _, err := c.GetAPIKey(ctx, rotated.NewKey.ID); !client.IsNotFound(err) {
+		t.Errorf("GetAPIKey after delete err = %v, want not found", err)
+	}
+}
@@ -0,0 +1,12 @@
+// Package integration holds the opt-in end-to-end test suite. It is built only
+// with the integration tag:
+//
+//	go test -tags=integration ./internal/integration/...
+//
+// TestMain starts PostgreSQL in Docker, applies the same embedded migrations
+// the server runs at startup, and serves requests through the full router, so
+// real SQL runs where the handler tests only see sqlmock expectations. The
+// protocol responses are compared to the golden files in testdata/golden; run
+// with -update to rewrite them after an intended change. client_test.go holds
+// the contract tests of pkg/client, run against the same router over HTTP.
+package integration
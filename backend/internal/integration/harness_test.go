@@ -0,0 +1,202 @@
+//go:build integration
+
+package integration
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureTime pins the entry times of the fixture archives, so their bytes
+// (and the sizes in the golden files) never change between runs.
+var fixtureTime = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type fixtureFile struct {
+	name string
+	body string
+}
+
+// moduleArchive builds a module tarball. It is stored rather than compressed
+// so its bytes do not depend on the Go version's deflate implementation.
+func moduleArchive(t *testing.T, files ...fixtureFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, gzip.NoCompression)
+	if err != nil {
+		t.Fatalf("gzip writer: %v", err)
+	}
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:     f.name,
+			Mode:     0o644,
+			Size:     int64(len(f.body)),
+			ModTime:  fixtureTime,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("tar header %s: %v", f.name, err)
+		}
+		if _, err := io.WriteString(tw, f.body); err != nil {
+			t.Fatalf("tar write %s: %v", f.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar close: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// providerArchive builds a provider zip with stored (uncompressed) entries,
+// for the same reason as moduleArchive.
+func providerArchive(t *testing.T, files ...fixtureFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Store, Modified: fixtureTime})
+		if err != nil {
+			t.Fatalf("zip header %s: %v", f.name, err)
+		}
+		if _, err := io.WriteString(w, f.body); err != nil {
+			t.Fatalf("zip write %s: %v", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// do serves req through the router. Requests are addressed to the configured
+// base URL's host, as they would be behind the real ingress.
+func (r *registry) do(req *http.Request) *httptest.ResponseRecorder {
+	req.Host = strings.TrimPrefix(baseURL, "http://")
+	w := httptest.NewRecorder()
+	r.router.ServeHTTP(w, req)
+	return w
+}
+
+func (r *registry) get(t *testing.T, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	return r.do(httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+// publish posts a multipart upload as the admin user. The default
+// organization is named explicitly so the first publish claims the namespace
+// for it.
+func (r *registry) publish(t *testing.T, path string, fields map[string]string, filename string, content []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fields["organization_id"] = r.orgID
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			t.Fatalf("form field %s: %v", k, err)
+		}
+	}
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("form file: %v", err)
+	}
+	if _, err := fw.Write(content); err != nil {
+		t.Fatalf("form file: %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("multipart close: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	return r.do(req)
+}
+
+// goldenHeaders are the response headers recorded in golden files; the rest
+// (request IDs, security headers) are not part of the protocol.
+var goldenHeaders = []string{"Content-Type", "X-Terraform-Get"}
+
+var (
+	uuidPattern      = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+)
+
+// golden is the recorded form of a response.
+type golden struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body"`
+}
+
+// assertGolden compares w to testdata/golden/<name>.json, or rewrites the file
+// with -update. IDs and timestamps are masked, and each key of replace (such
+// as a fixture's checksum) is written as its value, so the files only change
+// when a response does.
+func assertGolden(t *testing.T, name string, w *httptest.ResponseRecorder, replace map[string]string) {
+	t.Helper()
+	normalize := func(s string) string {
+		for from, to := range replace {
+			s = strings.ReplaceAll(s, from, to)
+		}
+		s = uuidPattern.ReplaceAllString(s, "<uuid>")
+		return timestampPattern.ReplaceAllString(s, "<timestamp>")
+	}
+
+	got := golden{Status: w.Code, Headers: map[string]string{}}
+	for _, h := range goldenHeaders {
+		if v := w.Header().Get(h); v != "" {
+			got.Headers[h] = normalize(v)
+		}
+	}
+	if body := normalize(w.Body.String()); body != "" {
+		if err := json.Unmarshal([]byte(body), &got.Body); err != nil {
+			t.Fatalf("%s: response is not JSON: %v\n%s", name, err, body)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(got); err != nil {
+		t.Fatalf("%s: encode: %v", name, err)
+	}
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path) // #nosec G304 -- path is built from a test-controlled name
+	if err != nil {
+		t.Fatalf("read %s (run with -update to create it): %v", path, err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("%s does not match the golden file %s\ngot:\n%s\nwant:\n%s", name, path, buf.Bytes(), want)
+	}
+}
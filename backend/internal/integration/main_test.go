@@ -0,0 +1,208 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dhui/dktest"
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+
+	"github.com/terraform-registry/terraform-registry/internal/api"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+const (
+	// postgresImage matches the database of the compose stacks in deployments/.
+	postgresImage = "postgres:16-alpine"
+	pgUser        = "registry"
+	pgPassword    = "registry"
+	pgDatabase    = "terraform_registry"
+
+	// baseURL is the registry's configured base URL; it prefixes every
+	// download URL in the golden files.
+	baseURL = "http://registry.test"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files from the current responses")
+
+// reg is the registry under test, shared by every test in the package.
+var reg *registry
+
+// registry is a fully wired server on a migrated database, with an admin user
+// to publish as.
+type registry struct {
+	router http.Handler
+	db     *sql.DB
+	// token is an admin JWT for the seeded user.
+	token string
+	// orgID is the default organization, which serves the protocol endpoints.
+	orgID string
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	os.Exit(run(m))
+}
+
+// run starts PostgreSQL, runs the tests against a registry backed by it and
+// returns the exit code. The container is removed on return.
+func run(m *testing.M) int {
+	code := 1
+	opts := dktest.Options{
+		PortRequired: true,
+		ReadyFunc:    postgresReady,
+		Timeout:      3 * time.Minute,
+		Env: map[string]string{
+			"POSTGRES_USER":     pgUser,
+			"POSTGRES_PASSWORD": pgPassword,
+			"POSTGRES_DB":       pgDatabase,
+		},
+	}
+	err := dktest.RunContext(context.Background(), stdLogger{}, postgresImage, opts, func(c dktest.ContainerInfo) error {
+		host, port, err := c.FirstPort()
+		if err != nil {
+			return err
+		}
+		r, cleanup, err := startRegistry(host, port)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		reg = r
+		code = m.Run()
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "integration: %v\n", err)
+		return 1
+	}
+	return code
+}
+
+// startRegistry configures the registry through the environment, exactly as
+// a deployment would, then connects, migrates and builds the router.
+func startRegistry(host, port string) (*registry, func(), error) {
+	storageDir, err := os.MkdirTemp("", "registry-integration-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	env := map[string]string{
+		"TFR_DATABASE_HOST":                  host,
+		"TFR_DATABASE_PORT":                  port,
+		"TFR_DATABASE_NAME":                  pgDatabase,
+		"TFR_DATABASE_USER":                  pgUser,
+		"TFR_DATABASE_PASSWORD":              pgPassword,
+		"TFR_DATABASE_SSL_MODE":              "disable",
+		"TFR_SERVER_BASE_URL":                baseURL,
+		"TFR_STORAGE_DEFAULT_BACKEND":        "local",
+		"TFR_STORAGE_LOCAL_BASE_PATH":        storageDir,
+		"TFR_STORAGE_LOCAL_SERVE_DIRECTLY":   "true",
+		"TFR_SECURITY_RATE_LIMITING_ENABLED": "false",
+		"TFR_JWT_SECRET":                     randomHex(32),
+		"ENCRYPTION_KEY":                     randomHex(16),
+	}
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := auth.ValidateJWTSecret(); err != nil {
+		return nil, nil, err
+	}
+	database, err := db.Connect(cfg.Database.GetDSN(), cfg.Database.MaxConnections, cfg.Database.MinIdleConnections)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The server applies these same embedded migrations on startup.
+	if err := db.RunMigrations(database, "up"); err != nil {
+		return nil, nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router, bg, err := api.NewRouter(cfg, database, database)
+	if err != nil {
+		_ = database.Close()
+		_ = os.RemoveAll(storageDir)
+		return nil, nil, err
+	}
+	cleanup := func() {
+		bg.Shutdown()
+		_ = database.Close()
+		_ = os.RemoveAll(storageDir)
+	}
+
+	r := &registry{router: router, db: database}
+	if err := r.seed(context.Background()); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return r, cleanup, nil
+}
+
+// seed creates the admin user the tests publish as and resolves the default
+// organization.
+func (r *registry) seed(ctx context.Context) error {
+	user := &models.User{Email: "admin@registry.test", Name: "Integration Admin"}
+	if err := repositories.NewUserRepository(r.db).CreateUser(ctx, user); err != nil {
+		return fmt.Errorf("failed to create admin user: %w", err)
+	}
+	token, err := auth.GenerateJWT(user.ID, user.Email, []string{string(auth.ScopeAdmin)}, time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to issue admin token: %w", err)
+	}
+	org, err := repositories.NewOrganizationRepository(r.db).GetDefaultOrganization(ctx)
+	if err != nil || org == nil {
+		return fmt.Errorf("default organization not seeded by migrations: %v", err)
+	}
+	r.token, r.orgID = token, org.ID
+	return nil
+}
+
+// postgresReady reports whether the container accepts connections.
+func postgresReady(ctx context.Context, c dktest.ContainerInfo) bool {
+	host, port, err := c.FirstPort()
+	if err != nil {
+		return false
+	}
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", host, port, pgUser, pgPassword, pgDatabase)
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	return conn.PingContext(ctx) == nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// stdLogger adapts the standard logger to dktest.
+type stdLogger struct{}
+
+func (stdLogger) Log(args ...interface{}) { log.Println(args...) }
@@ -0,0 +1,102 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/pkg/checksum"
+)
+
+const moduleMainTF = `variable "cidr_block" {
+  description = "CIDR block of the network."
+  type        = string
+}
+
+output "cidr_block" {
+  value = var.cidr_block
+}
+`
+
+const moduleReadme = "# network\n\nA network for integration tests.\n"
+
+// TestModuleProtocol publishes a module and walks the Module Registry
+// Protocol: list versions, resolve the download, fetch the archive.
+func TestModuleProtocol(t *testing.T) {
+	archive := moduleArchive(t,
+		fixtureFile{"main.tf", moduleMainTF},
+		fixtureFile{"README.md", moduleReadme},
+	)
+	replace := map[string]string{sha256Hex(archive): "<module-sha256>"}
+
+	w := reg.publish(t, "/api/v1/modules", map[string]string{
+		"namespace": "acme",
+		"name":      "network",
+		"system":    "aws",
+		"version":   "1.0.0",
+	}, "network-aws-1.0.0.tar.gz", archive)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("publish = %d: %s", w.Code, w.Body)
+	}
+	assertGolden(t, "module_publish", w, replace)
+
+	// Listed before the download, which bumps the count asynchronously.
+	assertGolden(t, "module_versions", reg.get(t, "/v1/modules/acme/network/aws/versions"), replace)
+
+	w = reg.get(t, "/v1/modules/acme/network/aws/1.0.0/download")
+	assertGolden(t, "module_download", w, replace)
+
+	location := w.Header().Get("X-Terraform-Get")
+	w = reg.get(t, strings.TrimPrefix(location, baseURL))
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), archive) {
+		t.Errorf("GET %s = %d with %d bytes, want the published archive", location, w.Code, w.Body.Len())
+	}
+}
+
+// TestProviderProtocol publishes a provider platform and reads it back through
+// the Provider Registry Protocol and the Network Mirror Protocol.
+func TestProviderProtocol(t *testing.T) {
+	archive := providerArchive(t, fixtureFile{"terraform-provider-widget_v1.0.0", "#!/bin/sh\necho widget\n"})
+	h1, err := checksum.HashZipFile(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("HashZipFile: %v", err)
+	}
+	replace := map[string]string{
+		sha256Hex(archive): "<provider-sha256>",
+		h1:                 "<provider-h1>",
+	}
+
+	w := reg.publish(t, "/api/v1/providers", map[string]string{
+		"namespace": "acme",
+		"type":      "widget",
+		"version":   "1.0.0",
+		"os":        "linux",
+		"arch":      "amd64",
+	}, "terraform-provider-widget_1.0.0_linux_amd64.zip", archive)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("publish = %d: %s", w.Code, w.Body)
+	}
+	assertGolden(t, "provider_publish", w, replace)
+
+	assertGolden(t, "provider_versions", reg.get(t, "/v1/providers/acme/widget/versions"), replace)
+	assertGolden(t, "mirror_index", reg.get(t, "/terraform/providers/registry.test/acme/widget/index.json"), replace)
+	assertGolden(t, "mirror_platforms", reg.get(t, "/terraform/providers/registry.test/acme/widget/1.0.0.json"), replace)
+
+	w = reg.get(t, "/v1/providers/acme/widget/1.0.0/download/linux/amd64")
+	assertGolden(t, "provider_download", w, replace)
+
+	var download struct {
+		DownloadURL string `json:"download_url"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &download); err != nil {
+		t.Fatalf("decode download: %v", err)
+	}
+	w = reg.get(t, strings.TrimPrefix(download.DownloadURL, baseURL))
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), archive) {
+		t.Errorf("GET %s = %d with %d bytes, want the published archive", download.DownloadURL, w.Code, w.Body.Len())
+	}
+}
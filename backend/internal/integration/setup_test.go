@@ -0,0 +1,99 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+)
+
+// TestCompleteSetupRace fires concurrent complete-setup requests with a valid
+// setup token. The requests serialize on the system_settings row lock: exactly
+// one completes setup, and every other one sees the committed state and gets
+// a 410, as does any later setup request.
+func TestCompleteSetupRace(t *testing.T) {
+	const token = "integration-setup-token"
+	const requests = 20
+	hash, err := bcrypt.GenerateFromPassword([]byte(token), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_, err = reg.db.ExecContext(ctx, `
+		UPDATE system_settings SET
+			setup_completed = false, setup_token_hash = $1,
+			setup_completed_at = NULL, setup_completed_by = NULL,
+			oidc_configured = true, storage_configured = true, scanning_configured = true,
+			pending_admin_email = 'admin@registry.test'
+		WHERE id = 1`, string(hash))
+	if err != nil {
+		t.Fatalf("failed to reset setup state: %v", err)
+	}
+
+	setupRequest := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "SetupToken "+token)
+		return reg.do(req)
+	}
+
+	codes := make([]int, requests)
+	bodies := make([][]byte, requests)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			w := setupRequest("/api/v1/setup/complete")
+			codes[i], bodies[i] = w.Code, w.Body.Bytes()
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	completed := 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusOK:
+			completed++
+		case http.StatusGone:
+			var body map[string]string
+			if err := json.Unmarshal(bodies[i], &body); err != nil || body["code"] != middleware.ErrCodeSetupCompleted {
+				t.Errorf("410 body = %s, want code %q", bodies[i], middleware.ErrCodeSetupCompleted)
+			}
+		default:
+			t.Errorf("status = %d, want 200 or 410: %s", code, bodies[i])
+		}
+	}
+	if completed != 1 {
+		t.Errorf("%d requests completed setup, want exactly 1", completed)
+	}
+
+	var storedHash sql.NullString
+	var completedAt sql.NullTime
+	var completedBy sql.NullString
+	err = reg.db.QueryRowContext(ctx,
+		`SELECT setup_token_hash, setup_completed_at, setup_completed_by FROM system_settings WHERE id = 1`).
+		Scan(&storedHash, &completedAt, &completedBy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedHash.Valid || !completedAt.Valid || completedBy.String == "" {
+		t.Errorf("after completion: token hash %v, completed at %v, completed by %q; want no hash and both recorded",
+			storedHash, completedAt, completedBy.String)
+	}
+
+	if w := setupRequest("/api/v1/setup/validate-token"); w.Code != http.StatusGone {
+		t.Errorf("validate-token after completion: status = %d, want 410", w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+// apikey_claim_expiry_job.go implements the job that revokes API keys whose
+// one-time retrieval link expired before anyone claimed it.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// apiKeyClaimExpiryInterval is how often expired claims are collected. Claim
+// lifetimes are minutes long, so an unclaimed key outlives its link by at most
+// this much.
+const apiKeyClaimExpiryInterval = time.Minute
+
+// APIKeyClaimExpiryJob revokes every API key whose claim link has expired and
+// removes the claim. The raw key was never delivered, so nothing can be
+// using it.
+type APIKeyClaimExpiryJob struct {
+	claimRepo  *repositories.APIKeyClaimRepository
+	apiKeyRepo *repositories.APIKeyRepository
+	stopChan   chan struct{}
+	scheduled
+}
+
+// NewAPIKeyClaimExpiryJob constructs an APIKeyClaimExpiryJob.
+func NewAPIKeyClaimExpiryJob(claimRepo *repositories.APIKeyClaimRepository, apiKeyRepo *repositories.APIKeyRepository) *APIKeyClaimExpiryJob {
+	return &APIKeyClaimExpiryJob{
+		claimRepo:  claimRepo,
+		apiKeyRepo: apiKeyRepo,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Name returns the human-readable job name used in logs.
+func (j *APIKeyClaimExpiryJob) Name() string { return "api-key-claim-expiry" }
+
+// Start runs one cycle immediately, then one every minute.
+func (j *APIKeyClaimExpiryJob) Start(ctx context.Context) error {
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: apiKeyClaimExpiryInterval}, j.stopChan, j.runExpiry)
+	return nil
+}
+
+// Stop signals the job to exit gracefully. It is safe to call multiple times.
+func (j *APIKeyClaimExpiryJob) Stop() error {
+	select {
+	case <-j.stopChan:
+	default:
+		close(j.stopChan)
+	}
+	return nil
+}
+
+// runExpiry revokes the key behind each expired claim, then deletes the
+// claim. A claim whose key could not be revoked is kept for the next cycle.
+func (j *APIKeyClaimExpiryJob) runExpiry(ctx context.Context) {
+	claims, err := j.claimRepo.ListExpired(ctx)
+	if err != nil {
+		slog.Error("api key claim expiry: list failed", "error", err)
+		return
+	}
+	revoked := 0
+	for _, claim := range claims {
+		if err := j.apiKeyRepo.Delete(ctx, claim.APIKeyID); err != nil {
+			slog.Error("api key claim expiry: revoke failed", "api_key_id", claim.APIKeyID, "error", err)
+			continue
+		}
+		if err := j.claimRepo.Delete(ctx, claim.ID); err != nil {
+			slog.Error("api key claim expiry: delete claim failed", "claim_id", claim.ID, "error", err)
+			continue
+		}
+		revoked++
+	}
+	if revoked > 0 {
+		slog.Info("api key claim expiry: revoked unclaimed keys", "count", revoked)
+	}
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+func TestAPIKeyClaimExpiryJob_RevokesUnclaimedKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cols := []string{"id", "api_key_id", "user_id", "expires_at", "created_at"}
+	mock.ExpectQuery("FROM api_key_claims WHERE expires_at <= NOW").
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("claim-1", "key-1", "user-1", time.Now(), time.Now()).
+			AddRow("claim-2", "key-2", "user-1", time.Now(), time.Now()))
+	mock.ExpectExec("DELETE FROM api_keys").WithArgs("key-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM api_key_claims").WithArgs("claim-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	// A key that cannot be revoked keeps its claim for the next cycle.
+	mock.ExpectExec("DELETE FROM api_keys").WithArgs("key-2").WillReturnError(errors.New("connection reset"))
+
+	job := NewAPIKeyClaimExpiryJob(repositories.NewAPIKeyClaimRepository(db), repositories.NewAPIKeyRepository(db))
+	job.runExpiry(context.Background())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -18,6 +18,7 @@ type AuditCleanupJob struct {
 	cfg       *config.AuditRetentionConfig
 	auditRepo *repositories.AuditRepository
 	stopChan  chan struct{}
+	scheduled
 }
 
 // NewAuditCleanupJob constructs an AuditCleanupJob.
@@ -33,7 +34,7 @@ func NewAuditCleanupJob(cfg *config.AuditRetentionConfig, auditRepo *repositorie
 func (j *AuditCleanupJob) Name() string { return "audit-cleanup" }
 
 // Start begins the cleanup loop. It is a no-op when RetentionDays is 0 (keep forever).
-// An immediate cycle is run on startup, then a 24-hour ticker drives subsequent cycles.
+// An immediate cycle is run on startup, then the scheduler repeats it every 24 hours.
 func (j *AuditCleanupJob) Start(ctx context.Context) error {
 	if j.cfg.RetentionDays == 0 {
 		slog.Info("audit cleanup: disabled (audit_retention.retention_days=0)")
@@ -42,22 +43,9 @@ func (j *AuditCleanupJob) Start(ctx context.Context) error {
 
 	slog.Info("audit cleanup: started", "retention_days", j.cfg.RetentionDays, "batch_size", j.cfg.CleanupBatchSize)
 
-	// Run one immediate cycle before entering the ticker loop.
-	j.runCleanupCycle(ctx)
-
-	ticker := time.NewTicker(24 * time.Hour)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			j.runCleanupCycle(ctx)
-		case <-j.stopChan:
-			return nil
-		case <-ctx.Done():
-			return nil
-		}
-	}
+	// One immediate cycle, then one every 24 hours.
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: 24 * time.Hour}, j.stopChan, j.runCleanupCycle)
+	return nil
 }
 
 // Stop signals the job to exit gracefully. It is safe to call multiple times.
@@ -0,0 +1,182 @@
+// code_index_job.go implements the module content indexer behind
+// GET /api/v1/search/code. Each run queues the module versions published
+// since the last one, then claims pending versions, extracts the resources,
+// data sources, variables and module calls of their .tf files and stores
+// them. Publishing never waits for it.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/analyzer"
+	"github.com/terraform-registry/terraform-registry/internal/archiver"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// codeIndexStaleAfter is how long a version may stay 'indexing' before a
+// run assumes its worker stopped and queues it again.
+const codeIndexStaleAfter = 30 * time.Minute
+
+// CodeIndexStore persists the indexing queue and the extracted symbols.
+// *repositories.ModuleCodeIndexRepository satisfies it.
+type CodeIndexStore interface {
+	QueueUnindexed(ctx context.Context) (int64, error)
+	ResetStale(ctx context.Context, olderThan time.Duration) error
+	ClaimPending(ctx context.Context, limit int) ([]string, error)
+	SaveIndex(ctx context.Context, moduleVersionID string, idx *analyzer.CodeIndex) error
+	MarkError(ctx context.Context, moduleVersionID, msg string) error
+}
+
+// ModuleVersionGetter looks up a module version by ID.
+// *repositories.ModuleRepository satisfies it.
+type ModuleVersionGetter interface {
+	GetVersionByID(ctx context.Context, id string) (*models.ModuleVersion, error)
+}
+
+// CodeIndexJob indexes the code of module versions in the background.
+type CodeIndexJob struct {
+	cfg        *config.CodeIndexConfig
+	store      CodeIndexStore
+	versions   ModuleVersionGetter
+	storage    storage.Storage
+	scratchDir string // where archives are unpacked; "" = OS temp dir
+	stopChan   chan struct{}
+	manualCh   chan struct{}
+	scheduled
+}
+
+// NewCodeIndexJob constructs a CodeIndexJob.
+func NewCodeIndexJob(cfg *config.CodeIndexConfig, store CodeIndexStore, versions ModuleVersionGetter, storageBackend storage.Storage) *CodeIndexJob {
+	return &CodeIndexJob{
+		cfg:      cfg,
+		store:    store,
+		versions: versions,
+		storage:  storageBackend,
+		stopChan: make(chan struct{}),
+		manualCh: make(chan struct{}, 1),
+	}
+}
+
+// SetScratch unpacks archives for indexing in the registry's scratch
+// directory. Call before Start.
+func (j *CodeIndexJob) SetScratch(space *scratch.Space) {
+	j.scratchDir = space.Dir()
+}
+
+// Name returns the human-readable job name used in logs.
+func (j *CodeIndexJob) Name() string { return "code-index" }
+
+// Start runs the indexer until ctx is cancelled or Stop is called. It is a
+// no-op when code_index.enabled is false.
+func (j *CodeIndexJob) Start(ctx context.Context) error {
+	if !j.cfg.Enabled {
+		slog.Info("code index: disabled (code_index.enabled=false)")
+		return nil
+	}
+
+	slog.Info("code index: started", "interval", j.cfg.Interval,
+		"max_bytes_per_version", j.cfg.MaxBytesPerVersion, "batch_size", j.cfg.BatchSize)
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: j.cfg.Interval, Trigger: j.manualCh}, j.stopChan, j.runIndex)
+	return nil
+}
+
+// TriggerIndex queues a run to start now, for versions an admin backfill
+// just queued. It reports false when the indexer is disabled; a run already
+// queued absorbs the request.
+func (j *CodeIndexJob) TriggerIndex() bool {
+	if !j.cfg.Enabled {
+		return false
+	}
+	select {
+	case j.manualCh <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// Stop signals the job to exit gracefully. It is safe to call multiple times.
+func (j *CodeIndexJob) Stop() error {
+	select {
+	case <-j.stopChan:
+		// already stopped
+	default:
+		close(j.stopChan)
+	}
+	return nil
+}
+
+// runIndex queues new versions and indexes pending ones until none are left.
+func (j *CodeIndexJob) runIndex(ctx context.Context) {
+	if err := j.store.ResetStale(ctx, codeIndexStaleAfter); err != nil {
+		slog.Error("code index: failed to reset stale records", "error", err)
+	}
+	if queued, err := j.store.QueueUnindexed(ctx); err != nil {
+		slog.Error("code index: failed to queue new module versions", "error", err)
+	} else if queued > 0 {
+		slog.Info("code index: queued module versions", "count", queued)
+	}
+
+	indexed := 0
+	for ctx.Err() == nil {
+		ids, err := j.store.ClaimPending(ctx, j.cfg.BatchSize)
+		if err != nil {
+			slog.Error("code index: failed to claim pending versions", "error", err)
+			break
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, id := range ids {
+			if err := j.indexOne(ctx, id); err != nil {
+				slog.Warn("code index: failed to index module version", "version_id", id, "error", err)
+				if markErr := j.store.MarkError(ctx, id, err.Error()); markErr != nil {
+					slog.Error("code index: failed to record error", "version_id", id, "error", markErr)
+				}
+				continue
+			}
+			indexed++
+		}
+	}
+	if indexed > 0 {
+		slog.Info("code index: run complete", "indexed", indexed)
+	}
+}
+
+// indexOne downloads and unpacks one version's archive and stores its symbols.
+func (j *CodeIndexJob) indexOne(ctx context.Context, moduleVersionID string) error {
+	mv, err := j.versions.GetVersionByID(ctx, moduleVersionID)
+	if err != nil {
+		return fmt.Errorf("get module version: %w", err)
+	}
+	if mv == nil {
+		return fmt.Errorf("module version not found")
+	}
+
+	tmpDir, err := os.MkdirTemp(j.scratchDir, "code-index-*")
+	if err != nil {
+		return fmt.Errorf("mkdirtemp: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	reader, err := j.storage.Download(ctx, mv.StoragePath)
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+	defer reader.Close()
+	if err := archiver.ExtractTarGz(reader, tmpDir); err != nil {
+		return fmt.Errorf("extract: %w", err)
+	}
+
+	idx, err := analyzer.IndexCode(tmpDir, j.cfg.MaxBytesPerVersion)
+	if err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+	return j.store.SaveIndex(ctx, moduleVersionID, idx)
+}
@@ -0,0 +1,142 @@
+package jobs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/analyzer"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// fakeCodeIndexStore is an in-memory CodeIndexStore whose queue is pending.
+type fakeCodeIndexStore struct {
+	pending []string
+	saved   map[string]*analyzer.CodeIndex
+	errors  map[string]string
+	queued  bool
+}
+
+func (f *fakeCodeIndexStore) QueueUnindexed(context.Context) (int64, error) {
+	f.queued = true
+	return 0, nil
+}
+
+func (f *fakeCodeIndexStore) ResetStale(context.Context, time.Duration) error { return nil }
+
+func (f *fakeCodeIndexStore) ClaimPending(_ context.Context, limit int) ([]string, error) {
+	n := min(limit, len(f.pending))
+	ids := f.pending[:n]
+	f.pending = f.pending[n:]
+	return ids, nil
+}
+
+func (f *fakeCodeIndexStore) SaveIndex(_ context.Context, id string, idx *analyzer.CodeIndex) error {
+	f.saved[id] = idx
+	return nil
+}
+
+func (f *fakeCodeIndexStore) MarkError(_ context.Context, id, msg string) error {
+	f.errors[id] = msg
+	return nil
+}
+
+// fakeVersionGetter answers GetVersionByID from a map.
+type fakeVersionGetter map[string]*models.ModuleVersion
+
+func (f fakeVersionGetter) GetVersionByID(_ context.Context, id string) (*models.ModuleVersion, error) {
+	return f[id], nil
+}
+
+// archiveStorage is a storage stub whose Download serves archives by path.
+type archiveStorage struct {
+	fakeUploadStorage
+	archives map[string][]byte
+}
+
+func (s *archiveStorage) Download(_ context.Context, path string) (io.ReadCloser, error) {
+	data, ok := s.archives[path]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func codeIndexArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCodeIndexJob_RunIndex(t *testing.T) {
+	store := &fakeCodeIndexStore{
+		pending: []string{"mv-1", "mv-missing", "mv-gone"},
+		saved:   map[string]*analyzer.CodeIndex{},
+		errors:  map[string]string{},
+	}
+	versions := fakeVersionGetter{
+		"mv-1":    {ID: "mv-1", StoragePath: "modules/acme/eks/aws/1.0.0.tar.gz"},
+		"mv-gone": {ID: "mv-gone", StoragePath: "modules/acme/gone/aws/1.0.0.tar.gz"},
+	}
+	objects := &archiveStorage{archives: map[string][]byte{
+		"modules/acme/eks/aws/1.0.0.tar.gz": codeIndexArchive(t, map[string]string{
+			"main.tf": "resource \"aws_eks_cluster\" \"this\" {}\n\nvariable \"capacity_type\" {\n  default = \"SPOT\"\n}\n",
+		}),
+	}}
+	cfg := &config.CodeIndexConfig{Enabled: true, Interval: time.Minute, MaxBytesPerVersion: 1 << 20, BatchSize: 2}
+	j := NewCodeIndexJob(cfg, store, versions, objects)
+	j.scratchDir = t.TempDir()
+
+	j.runIndex(context.Background())
+
+	if !store.queued {
+		t.Error("run did not queue unindexed versions")
+	}
+	idx := store.saved["mv-1"]
+	if idx == nil || idx.Files != 1 || len(idx.Symbols) != 2 || idx.Symbols[0].Symbol != "aws_eks_cluster" {
+		t.Fatalf("saved index = %+v", idx)
+	}
+	if len(store.errors) != 2 || store.errors["mv-missing"] == "" || store.errors["mv-gone"] == "" {
+		t.Errorf("errors = %v, want mv-missing and mv-gone", store.errors)
+	}
+	if len(store.pending) != 0 {
+		t.Errorf("pending = %v, want the queue drained", store.pending)
+	}
+}
+
+func TestCodeIndexJob_TriggerIndex(t *testing.T) {
+	cfg := &config.CodeIndexConfig{}
+	j := NewCodeIndexJob(cfg, &fakeCodeIndexStore{}, fakeVersionGetter{}, &archiveStorage{})
+	if j.TriggerIndex() {
+		t.Error("TriggerIndex on a disabled indexer = true")
+	}
+	cfg.Enabled = true
+	if !j.TriggerIndex() || !j.TriggerIndex() {
+		t.Error("TriggerIndex = false, want true (a queued run absorbs the second)")
+	}
+	if len(j.manualCh) != 1 {
+		t.Errorf("queued runs = %d, want 1", len(j.manualCh))
+	}
+}
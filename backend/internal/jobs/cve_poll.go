@@ -40,6 +40,7 @@ type CVEPollJob struct {
 	notifier *notify.Notifier
 	stopChan chan struct{}
 	manualCh chan struct{}
+	scheduled
 }
 
 // NewCVEPollJob constructs and returns a CVEPollJob. The OSV client uses the
@@ -108,27 +109,10 @@ func (j *CVEPollJob) Start(ctx context.Context) error {
 
 	log.Printf("[cve-poll] started (interval: %v)", interval)
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	// Run once immediately at startup.
-	j.runPoll(ctx)
-
-	for {
-		select {
-		case <-ticker.C:
-			j.runPoll(ctx)
-		case <-j.manualCh:
-			log.Println("[cve-poll] manual trigger received")
-			j.runPoll(ctx)
-		case <-j.stopChan:
-			log.Println("[cve-poll] stopped")
-			return nil
-		case <-ctx.Done():
-			log.Println("[cve-poll] context cancelled")
-			return nil
-		}
-	}
+	// Runs once immediately at startup, then on the interval or TriggerPoll.
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: interval, Trigger: j.manualCh}, j.stopChan, j.runPoll)
+	log.Println("[cve-poll] stopped")
+	return nil
 }
 
 // TriggerPoll sends a non-blocking signal to run a poll immediately.
@@ -140,9 +124,14 @@ func (j *CVEPollJob) TriggerPoll() {
 	}
 }
 
-// Stop signals the background loop to exit.
+// Stop signals the background loop to exit. It is safe to call multiple times.
 func (j *CVEPollJob) Stop() error {
-	close(j.stopChan)
+	select {
+	case <-j.stopChan:
+		// already stopped
+	default:
+		close(j.stopChan)
+	}
 	return nil
 }
 
@@ -0,0 +1,41 @@
+// mirror_provider_address.go checks that the provider archives a mirror sync
+// downloads hold the provider they are mirrored as. Unlike an upload, a
+// mismatch does not stop the sync: upstream archives are mirrored as
+// published, and the mismatch is flagged in the sync details.
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// addressCheck collects the archives downloaded during one provider sync
+// whose binary or manifest names a different provider. A nil *addressCheck
+// disables the check.
+type addressCheck struct {
+	namespace    string
+	providerType string
+	mismatches   []string
+}
+
+// check examines a provider zip archive. Unreadable archives are logged and
+// not flagged.
+func (ac *addressCheck) check(filename string, r io.ReaderAt, size int64) {
+	if ac == nil {
+		return
+	}
+	err := validation.CheckProviderArchiveAddress(r, size, ac.namespace, ac.providerType)
+	var mismatch *validation.ProviderAddressMismatch
+	switch {
+	case errors.As(err, &mismatch):
+		log.Printf("Warning: %s does not match %s/%s: %v", filename, ac.namespace, ac.providerType, err)
+		ac.mismatches = append(ac.mismatches, fmt.Sprintf("%s: %s", filename, strings.Join(mismatch.Discrepancies, "; ")))
+	case err != nil:
+		log.Printf("Warning: failed to check the provider address of %s: %v", filename, err)
+	}
+}
@@ -0,0 +1,24 @@
+package jobs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAddressCheck_FlagsMismatches(t *testing.T) {
+	ac := &addressCheck{namespace: "hashicorp", providerType: "aws"}
+
+	ok := licenseZip(t, map[string]string{"terraform-provider-aws_v5.0.0_x5": "binary"})
+	ac.check("terraform-provider-aws_5.0.0_linux_amd64.zip", bytes.NewReader(ok), int64(len(ok)))
+	bad := licenseZip(t, map[string]string{"terraform-provider-google_v5.0.0_x5": "binary"})
+	ac.check("terraform-provider-aws_5.0.0_darwin_arm64.zip", bytes.NewReader(bad), int64(len(bad)))
+	ac.check("corrupt.zip", bytes.NewReader([]byte("not a zip")), 9)
+
+	if len(ac.mismatches) != 1 || !strings.HasPrefix(ac.mismatches[0], "terraform-provider-aws_5.0.0_darwin_arm64.zip: ") {
+		t.Errorf("mismatches = %q, want only the darwin archive", ac.mismatches)
+	}
+
+	var disabled *addressCheck
+	disabled.check("terraform-provider-aws_5.0.0_darwin_arm64.zip", bytes.NewReader(bad), int64(len(bad)))
+}
@@ -0,0 +1,153 @@
+// mirror_provider_metadata.go records the upstream registry's descriptive
+// metadata (description, source repository, tier) and the license shipped in
+// the provider archive on each mirrored provider. Both are informational:
+// failures become warnings in the sync details and never fail the sync.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+)
+
+// licenseCapture holds the license found in the newest provider archive
+// examined during one provider sync. A nil *licenseCapture disables capture.
+type licenseCapture struct {
+	checked bool // an archive was opened and examined
+	version string
+	info    *mirror.LicenseInfo // nil when the examined archive had no license file
+}
+
+// wants reports whether an archive of version should be examined: either none
+// has been yet, or version is newer than the one examined.
+func (lc *licenseCapture) wants(version string) bool {
+	return lc != nil && (!lc.checked || mirror.CompareSemver(version, lc.version) > 0)
+}
+
+// capture examines a provider zip archive. Unreadable archives are logged and
+// leave the capture unchanged.
+func (lc *licenseCapture) capture(version string, r io.ReaderAt, size int64) {
+	info, err := mirror.ExtractLicense(r, size)
+	if err != nil {
+		log.Printf("Warning: failed to read license from provider archive for version %s: %v", version, err)
+		return
+	}
+	lc.checked, lc.version, lc.info = true, version, info
+}
+
+// refreshProviderMetadata stores the upstream description, source URL and tier
+// on the provider, then the captured license. When no archive was downloaded
+// this sync and the provider's archives have never been examined (providers
+// mirrored before license capture existed), the newest stored archive is read
+// instead. It returns the warnings to report in the sync details.
+func (j *MirrorSyncJob) refreshProviderMetadata(
+	ctx context.Context,
+	upstreamClient mirror.UpstreamRegistryClient,
+	provider *models.Provider,
+	namespace, providerName string,
+	license *licenseCapture,
+) []string {
+	var warnings []string
+
+	meta, err := upstreamClient.GetProviderMetadata(ctx, namespace, providerName)
+	if err != nil {
+		log.Printf("Warning: failed to fetch upstream metadata for %s/%s: %v", namespace, providerName, err)
+		warnings = append(warnings, fmt.Sprintf("upstream metadata unavailable: %v", err))
+	} else if err := j.providerRepo.UpdateUpstreamMetadata(ctx, provider.ID,
+		nonEmpty(meta.Description), nonEmpty(meta.SourceURL), nonEmpty(meta.Tier)); err != nil {
+		log.Printf("Warning: failed to store upstream metadata for %s/%s: %v", namespace, providerName, err)
+		warnings = append(warnings, "failed to store upstream metadata")
+	}
+
+	if !license.checked {
+		existing, err := j.providerRepo.GetUpstreamMetadata(ctx, provider.ID)
+		switch {
+		case err != nil:
+			log.Printf("Warning: failed to load stored metadata for %s/%s: %v", namespace, providerName, err)
+			return warnings
+		case existing != nil && existing.LicenseCheckedAt != nil:
+			// Nothing new downloaded and the license is already on record.
+			return warnings
+		}
+		if err := j.captureStoredLicense(ctx, provider.ID, license); err != nil {
+			log.Printf("Warning: failed to backfill license for %s/%s: %v", namespace, providerName, err)
+			return append(warnings, fmt.Sprintf("license backfill failed: %v", err))
+		}
+		if !license.checked {
+			return warnings
+		}
+	}
+
+	var spdx, file, text *string
+	if license.info != nil {
+		spdx, file, text = nonEmpty(license.info.SPDX), &license.info.File, &license.info.Text
+	} else {
+		warnings = append(warnings, "no LICENSE file found in provider archive")
+	}
+	if err := j.providerRepo.UpdateLicense(ctx, provider.ID, spdx, file, text); err != nil {
+		log.Printf("Warning: failed to store license for %s/%s: %v", namespace, providerName, err)
+		warnings = append(warnings, "failed to store license")
+	}
+	return warnings
+}
+
+// captureStoredLicense examines one stored platform archive of the provider's
+// newest version. It leaves the capture unchecked when nothing is stored.
+func (j *MirrorSyncJob) captureStoredLicense(ctx context.Context, providerID string, license *licenseCapture) error {
+	versions, err := j.providerRepo.ListVersions(ctx, providerID)
+	if err != nil {
+		return fmt.Errorf("failed to list versions: %w", err)
+	}
+	var newest *models.ProviderVersion
+	for _, v := range versions {
+		if newest == nil || mirror.CompareSemver(v.Version, newest.Version) > 0 {
+			newest = v
+		}
+	}
+	if newest == nil {
+		return nil
+	}
+
+	platforms, err := j.providerRepo.ListPlatforms(ctx, newest.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list platforms: %w", err)
+	}
+	if len(platforms) == 0 {
+		return nil
+	}
+
+	rc, err := j.storageBackend.Download(ctx, platforms[0].StoragePath)
+	if err != nil {
+		return fmt.Errorf("failed to open stored archive: %w", err)
+	}
+	defer rc.Close()
+
+	tmpFile, err := os.CreateTemp(j.scratchDir, "provider-license-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+	}()
+
+	written, err := io.Copy(tmpFile, rc)
+	if err != nil {
+		return fmt.Errorf("failed to read stored archive: %w", err)
+	}
+	license.capture(newest.Version, tmpFile, written)
+	return nil
+}
+
+// nonEmpty returns nil for "" so the column is stored as NULL.
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
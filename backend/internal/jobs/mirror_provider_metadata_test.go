@@ -0,0 +1,141 @@
+package jobs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+)
+
+func licenseZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLicenseCapture_KeepsNewestVersion(t *testing.T) {
+	older := licenseZip(t, map[string]string{"LICENSE": "Mozilla Public License Version 2.0"})
+	newer := licenseZip(t, map[string]string{"LICENSE": "Business Source License 1.1"})
+
+	lc := &licenseCapture{}
+	if !lc.wants("1.0.0") {
+		t.Fatal("empty capture should want any version")
+	}
+	lc.capture("1.0.0", bytes.NewReader(older), int64(len(older)))
+	if !lc.wants("1.1.0") || lc.wants("0.9.0") || lc.wants("1.0.0") {
+		t.Error("capture should only want newer versions once one is examined")
+	}
+	lc.capture("1.1.0", bytes.NewReader(newer), int64(len(newer)))
+	if lc.info == nil || lc.info.SPDX != "BUSL-1.1" {
+		t.Errorf("info = %+v, want BUSL-1.1", lc.info)
+	}
+
+	var disabled *licenseCapture
+	if disabled.wants("1.0.0") {
+		t.Error("nil capture should never want an archive")
+	}
+}
+
+func TestRefreshProviderMetadata_StoresMetadataAndLicense(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("UPDATE providers.*description = COALESCE").
+		WithArgs("prov-1", "AWS provider", "https://github.com/hashicorp/terraform-provider-aws", "official").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE providers.*license = \\$2").
+		WithArgs("prov-1", "MPL-2.0", "LICENSE", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	job := NewMirrorSyncJob(nil, repositories.NewProviderRepository(db), nil, nil, nil, "")
+	upstream := &fakeUpstreamClient{metadata: &mirror.ProviderMetadata{
+		Description: "AWS provider",
+		SourceURL:   "https://github.com/hashicorp/terraform-provider-aws",
+		Tier:        "official",
+	}}
+	license := &licenseCapture{checked: true, version: "5.0.0", info: &mirror.LicenseInfo{
+		File: "LICENSE", Text: "Mozilla Public License Version 2.0", SPDX: "MPL-2.0",
+	}}
+
+	warnings := job.refreshProviderMetadata(context.Background(), upstream, &models.Provider{ID: "prov-1"}, "hashicorp", "aws", license)
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRefreshProviderMetadata_MissingDataWarns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("UPDATE providers.*license = \\$2").
+		WithArgs("prov-1", nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	job := NewMirrorSyncJob(nil, repositories.NewProviderRepository(db), nil, nil, nil, "")
+	upstream := &fakeUpstreamClient{metadataErr: errors.New("status 404")}
+	license := &licenseCapture{checked: true, version: "1.0.0"}
+
+	warnings := job.refreshProviderMetadata(context.Background(), upstream, &models.Provider{ID: "prov-1"}, "acme", "widget", license)
+	if len(warnings) != 2 {
+		t.Fatalf("warnings = %v, want metadata and license warnings", warnings)
+	}
+	if warnings[1] != "no LICENSE file found in provider archive" {
+		t.Errorf("warnings[1] = %q", warnings[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRefreshProviderMetadata_SkipsBackfillWhenAlreadyChecked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("UPDATE providers.*description = COALESCE").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT source_url, upstream_tier, license").
+		WithArgs("prov-1").
+		WillReturnRows(sqlmock.NewRows([]string{"source_url", "upstream_tier", "license", "license_file", "license_text", "upstream_metadata_synced_at", "license_checked_at"}).
+			AddRow(nil, nil, nil, nil, nil, time.Now(), time.Now()))
+
+	// storageBackend is nil: reaching the backfill would panic.
+	job := NewMirrorSyncJob(nil, repositories.NewProviderRepository(db), nil, nil, nil, "")
+	upstream := &fakeUpstreamClient{metadata: &mirror.ProviderMetadata{}}
+
+	warnings := job.refreshProviderMetadata(context.Background(), upstream, &models.Provider{ID: "prov-1"}, "acme", "widget", &licenseCapture{})
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// mirror_storage_limit.go implements the storage soft limit shared by the
+// provider and Terraform binary mirror syncs: a per-sync budget that stops new
+// versions being downloaded once a mirror's stored archives reach its
+// max_storage_bytes, and the 80%/100% usage notifications.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/notify"
+)
+
+// Storage usage thresholds, in percent of max_storage_bytes, announced through
+// the mirror_storage_threshold notification event.
+const (
+	storageAlertWarn = 80
+	storageAlertFull = 100
+)
+
+// storageBudget tracks a mirror's stored bytes against its limit for the
+// length of one sync. A nil budget, or one without a limit, never runs out.
+type storageBudget struct {
+	limit   *int64
+	used    int64
+	skipped int
+}
+
+func newStorageBudget(used int64, limit *int64) *storageBudget {
+	return &storageBudget{used: used, limit: limit}
+}
+
+// exhausted reports whether the mirror's stored archives have reached its
+// limit, so no new version may be downloaded.
+func (b *storageBudget) exhausted() bool {
+	return b != nil && b.limit != nil && b.used >= *b.limit
+}
+
+// add counts n newly stored bytes.
+func (b *storageBudget) add(n int64) {
+	if b != nil {
+		b.used += n
+	}
+}
+
+// skip counts a version left unsynced because the budget ran out.
+func (b *storageBudget) skip() {
+	if b != nil {
+		b.skipped++
+	}
+}
+
+type storageBudgetKey struct{}
+
+// withStorageBudget attaches b to ctx so the sync steps that download
+// archives can consult and charge it.
+func withStorageBudget(ctx context.Context, b *storageBudget) context.Context {
+	return context.WithValue(ctx, storageBudgetKey{}, b)
+}
+
+// storageBudgetFrom returns the budget attached to ctx, or nil.
+func storageBudgetFrom(ctx context.Context) *storageBudget {
+	b, _ := ctx.Value(storageBudgetKey{}).(*storageBudget)
+	return b
+}
+
+// storageLimitMessage is the last_sync_error recorded when a sync skipped
+// versions because the mirror reached its storage limit.
+func storageLimitMessage(b *storageBudget) string {
+	return fmt.Sprintf("storage limit reached: %s stored of %s; %d new version(s) not synced. Raise max_storage_bytes or free storage to resume.",
+		formatBytes(b.used), formatBytes(*b.limit), b.skipped)
+}
+
+// storageAlertLevel returns the highest threshold used has reached against
+// limit: 0, storageAlertWarn or storageAlertFull.
+func storageAlertLevel(used int64, limit *int64) int {
+	if limit == nil || *limit <= 0 {
+		return 0
+	}
+	switch {
+	case used >= *limit:
+		return storageAlertFull
+	case used*100 >= *limit*storageAlertWarn:
+		return storageAlertWarn
+	default:
+		return 0
+	}
+}
+
+// checkStorageAlert compares a mirror's usage with the threshold last
+// notified (prevLevel) and, when usage has crossed a higher one, sends a
+// mirror_storage_threshold event. The new level is saved through setLevel
+// whenever it changes, so falling back below a threshold — after the limit
+// is raised or storage freed — re-arms its notification.
+func checkStorageAlert(ctx context.Context, notifier *notify.Notifier, kind, name string, prevLevel int, used int64, limit *int64, setLevel func(context.Context, int) error) {
+	level := storageAlertLevel(used, limit)
+	if level == prevLevel {
+		return
+	}
+	if err := setLevel(ctx, level); err != nil {
+		log.Printf("Warning: failed to record storage alert level for %s %s: %v", kind, name, err)
+	}
+	if level <= prevLevel {
+		return
+	}
+
+	usage := models.NewMirrorStorageUsage(used, limit)
+	title := fmt.Sprintf("%s %q has used %d%% of its storage limit", kind, name, storageAlertWarn)
+	next := "New versions are still synced until the limit is reached."
+	if level == storageAlertFull {
+		title = fmt.Sprintf("%s %q has reached its storage limit", kind, name)
+		next = "New versions are no longer synced until max_storage_bytes is raised or storage is freed."
+	}
+	message := fmt.Sprintf("%s %q stores %s of its %s limit (%.1f%%). %s",
+		kind, name, formatBytes(used), formatBytes(*limit), *usage.PercentUsed, next)
+	notifier.Notify(ctx, notify.Event{Type: notify.EventMirrorStorageThreshold, Title: title, Message: message})
+}
+
+// formatBytes renders n in the largest binary unit that keeps it at least 1.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestStorageBudget(t *testing.T) {
+	var nilBudget *storageBudget
+	if nilBudget.exhausted() {
+		t.Error("nil budget should never be exhausted")
+	}
+	nilBudget.add(10)
+	nilBudget.skip()
+
+	unlimited := newStorageBudget(1<<40, nil)
+	if unlimited.exhausted() {
+		t.Error("budget without a limit should never be exhausted")
+	}
+
+	b := newStorageBudget(900, int64Ptr(1000))
+	if b.exhausted() {
+		t.Fatal("900 of 1000 should not be exhausted")
+	}
+	b.add(100)
+	if !b.exhausted() {
+		t.Fatal("1000 of 1000 should be exhausted")
+	}
+	b.skip()
+	b.skip()
+	if b.skipped != 2 {
+		t.Errorf("skipped = %d, want 2", b.skipped)
+	}
+	if msg := storageLimitMessage(b); !strings.Contains(msg, "2 new version(s) not synced") {
+		t.Errorf("message = %q", msg)
+	}
+}
+
+func TestStorageBudgetContext(t *testing.T) {
+	if storageBudgetFrom(context.Background()) != nil {
+		t.Error("expected no budget on a bare context")
+	}
+	b := newStorageBudget(0, int64Ptr(1))
+	if got := storageBudgetFrom(withStorageBudget(context.Background(), b)); got != b {
+		t.Error("budget not carried by the context")
+	}
+}
+
+func TestStorageAlertLevel(t *testing.T) {
+	tests := []struct {
+		used  int64
+		limit *int64
+		want  int
+	}{
+		{500, nil, 0},
+		{0, int64Ptr(1000), 0},
+		{799, int64Ptr(1000), 0},
+		{800, int64Ptr(1000), storageAlertWarn},
+		{999, int64Ptr(1000), storageAlertWarn},
+		{1000, int64Ptr(1000), storageAlertFull},
+		{1500, int64Ptr(1000), storageAlertFull},
+	}
+	for _, tt := range tests {
+		if got := storageAlertLevel(tt.used, tt.limit); got != tt.want {
+			t.Errorf("storageAlertLevel(%d, %v) = %d, want %d", tt.used, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestCheckStorageAlert(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev      int
+		used      int64
+		limit     *int64
+		wantSaved []int
+	}{
+		{"below warn, nothing saved", 0, 100, int64Ptr(1000), nil},
+		{"crosses warn", 0, 850, int64Ptr(1000), []int{storageAlertWarn}},
+		{"already warned", storageAlertWarn, 900, int64Ptr(1000), nil},
+		{"crosses full", storageAlertWarn, 1000, int64Ptr(1000), []int{storageAlertFull}},
+		{"limit raised re-arms", storageAlertFull, 1000, int64Ptr(10000), []int{0}},
+		{"limit removed re-arms", storageAlertFull, 1000, nil, []int{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []int
+			setLevel := func(_ context.Context, level int) error {
+				saved = append(saved, level)
+				return nil
+			}
+			// A nil notifier is a no-op, so the level bookkeeping is what is checked.
+			checkStorageAlert(context.Background(), nil, "Provider mirror", "m", tt.prev, tt.used, tt.limit, setLevel)
+			if len(saved) != len(tt.wantSaved) || (len(saved) == 1 && saved[0] != tt.wantSaved[0]) {
+				t.Errorf("saved levels = %v, want %v", saved, tt.wantSaved)
+			}
+		})
+	}
+}
+
+func TestCheckStorageAlert_SaveErrorIsLogged(t *testing.T) {
+	called := false
+	checkStorageAlert(context.Background(), nil, "Terraform mirror", "m", 0, 1000, int64Ptr(1000),
+		func(context.Context, int) error {
+			called = true
+			return errors.New("db down")
+		})
+	if !called {
+		t.Error("expected the level to be saved")
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:     "512 B",
+		2048:    "2.0 KiB",
+		5 << 20: "5.0 MiB",
+		3 << 30: "3.0 GiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
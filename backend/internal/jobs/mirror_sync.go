@@ -4,6 +4,7 @@
 package jobs
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -13,14 +14,17 @@ import (
 	"log"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
 	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/notify"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
 	"github.com/terraform-registry/terraform-registry/internal/safego"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 	"github.com/terraform-registry/terraform-registry/pkg/checksum"
@@ -36,6 +40,23 @@ func safeString(s *string) string {
 	return *s
 }
 
+// listingValidators returns the validators to list a mirrored provider's
+// versions conditionally on: those of its last complete sync. Editing the
+// mirror configuration clears them (see MirrorRepository.Update).
+func listingValidators(tracked *models.MirroredProvider) mirror.VersionsValidators {
+	if tracked == nil {
+		return mirror.VersionsValidators{}
+	}
+	var v mirror.VersionsValidators
+	if tracked.UpstreamETag != nil {
+		v.ETag = *tracked.UpstreamETag
+	}
+	if tracked.UpstreamLastModified != nil {
+		v.LastModified = *tracked.UpstreamLastModified
+	}
+	return v
+}
+
 // filterPlatforms filters platforms based on a JSON array of "os/arch" strings
 // If filter is nil or empty, all platforms are returned
 func filterPlatforms(platforms []mirror.ProviderPlatform, filter *string) []mirror.ProviderPlatform {
@@ -81,9 +102,9 @@ type MirrorSyncJob struct {
 	approvalRepo       *repositories.VersionApprovalRepository // optional; set via SetApprovalRepo for auto-approve event logging
 	storageBackend     storage.Storage
 	storageBackendName string
-	activeSyncs        map[uuid.UUID]bool
-	activeSyncsMutex   sync.Mutex
+	activeSyncs        *activeRuns
 	stopCh             chan struct{}
+	scheduled
 	// intervalMinutes is the sync cadence; SetInterval overrides it, otherwise
 	// Start falls back to defaultMirrorSyncIntervalMinutes.
 	intervalMinutes int
@@ -97,6 +118,35 @@ type MirrorSyncJob struct {
 	// egressGuard widens the SSRF egress deny-list for upstream fetches
 	// (nil = strict). Set via SetEgressGuard before Start.
 	egressGuard *httpsafe.Guard
+
+	// scratchDir is where downloads are staged ("" = OS temp dir). Set via
+	// SetScratch before Start.
+	scratchDir string
+
+	// leases, when set via SetSyncLeases, guards syncs across replicas in
+	// place of activeSyncs. leaseHolder identifies this process in them;
+	// heartbeatEvery overrides models.MirrorSyncLeaseHeartbeat in tests.
+	leases         MirrorSyncLeaseStore
+	leaseHolder    string
+	heartbeatEvery time.Duration
+
+	// resigner re-signs newly synced versions with the registry key
+	// (mirror_signing). Optional; set via SetResigner.
+	resigner *services.ProviderResigner
+
+	// operations lists running syncs in /admin/operations. Optional; set via
+	// SetOperations.
+	operations *operations.Registry
+
+	// blobs stores platform archives content-addressed, once across mirrors
+	// and organizations. Optional; set via SetMirrorBlobs. When unset,
+	// archives are stored under per-namespace keys.
+	blobs *services.MirrorBlobs
+
+	// notifier sends the mirror_storage_threshold event when a mirror's
+	// stored archives cross 80% or 100% of max_storage_bytes. Optional; set
+	// via SetNotifier.
+	notifier *notify.Notifier
 }
 
 // NewMirrorSyncJob creates a new mirror sync job
@@ -115,8 +165,7 @@ func NewMirrorSyncJob(
 		orgRepo:            orgRepo,
 		storageBackend:     storageBackend,
 		storageBackendName: storageBackendName,
-		activeSyncs:        make(map[uuid.UUID]bool),
-		activeSyncsMutex:   sync.Mutex{},
+		activeSyncs:        newActiveRuns(),
 		stopCh:             make(chan struct{}),
 	}
 	j.newUpstream = func(baseURL string) mirror.UpstreamRegistryClient {
@@ -132,6 +181,12 @@ func (j *MirrorSyncJob) SetEgressGuard(g *httpsafe.Guard) {
 	j.egressGuard = g
 }
 
+// SetScratch stages downloaded provider archives in the registry's scratch
+// directory. Call before Start.
+func (j *MirrorSyncJob) SetScratch(space *scratch.Space) {
+	j.scratchDir = space.Dir()
+}
+
 // SetApprovalRepo wires the version-approval repository so the sync job can log
 // auto_approved audit events. Optional: when unset, auto-approval still applies
 // to the version's status but no event row is written.
@@ -139,6 +194,33 @@ func (j *MirrorSyncJob) SetApprovalRepo(repo *repositories.VersionApprovalReposi
 	j.approvalRepo = repo
 }
 
+// SetResigner enables re-signing of newly synced, GPG-verified versions with
+// the registry's signing key. A re-signing failure is logged and leaves the
+// version served with its upstream signature; POST /admin/mirrors/:id/resign
+// backfills it later.
+func (j *MirrorSyncJob) SetResigner(r *services.ProviderResigner) {
+	j.resigner = r
+}
+
+// SetOperations registers each running sync in the in-process operations
+// registry, where it can be listed and cancelled. Progress is counted in
+// providers.
+func (j *MirrorSyncJob) SetOperations(registry *operations.Registry) {
+	j.operations = registry
+}
+
+// SetMirrorBlobs stores synced platform archives as reference-counted,
+// content-addressed blobs, so identical upstream archives are stored once.
+func (j *MirrorSyncJob) SetMirrorBlobs(b *services.MirrorBlobs) {
+	j.blobs = b
+}
+
+// SetNotifier wires the notifier used for storage limit alerts. Optional:
+// when unset, limits are still enforced but no notification is sent.
+func (j *MirrorSyncJob) SetNotifier(n *notify.Notifier) {
+	j.notifier = n
+}
+
 // SetUpstreamFactory replaces the upstream-client factory.  Intended for tests
 // that want to substitute a fake mirror.UpstreamRegistryClient; production
 // callers should rely on the default factory installed by NewMirrorSyncJob.
@@ -175,24 +257,10 @@ func (j *MirrorSyncJob) Start(ctx context.Context) error {
 		log.Printf("Reset %d stale sync history record(s) from previous process", n)
 	}
 
-	ticker := time.NewTicker(time.Duration(intervalMinutes) * time.Minute)
-	defer ticker.Stop()
-
-	// Run initial sync immediately
-	j.runScheduledSyncs(ctx)
-
-	for {
-		select {
-		case <-ticker.C:
-			j.runScheduledSyncs(ctx)
-		case <-j.stopCh:
-			log.Println("Mirror sync job stopped")
-			return nil
-		case <-ctx.Done():
-			log.Println("Mirror sync job context cancelled")
-			return nil
-		}
-	}
+	// The first sync runs immediately, then every intervalMinutes.
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: time.Duration(intervalMinutes) * time.Minute}, j.stopCh, j.runScheduledSyncs)
+	log.Println("Mirror sync job stopped")
+	return nil
 }
 
 // Stop signals the sync loop to exit. Best-effort and idempotent (matching the
@@ -223,15 +291,16 @@ func (j *MirrorSyncJob) runScheduledSyncs(ctx context.Context) {
 	log.Printf("Found %d mirrors needing sync", len(mirrors))
 
 	for _, mirror := range mirrors {
-		// Check if this mirror is already syncing
-		j.activeSyncsMutex.Lock()
-		if j.activeSyncs[mirror.ID] {
+		// Check if this mirror is already syncing, here or on another replica
+		acquired, err := j.acquireSync(ctx, mirror.ID)
+		if err != nil {
+			log.Printf("Failed to acquire sync lease for mirror %s: %v", mirror.Name, err)
+			continue
+		}
+		if !acquired {
 			log.Printf("Mirror %s is already syncing, skipping", mirror.Name)
-			j.activeSyncsMutex.Unlock()
 			continue
 		}
-		j.activeSyncs[mirror.ID] = true
-		j.activeSyncsMutex.Unlock()
 
 		// Run sync in a goroutine with panic recovery
 		mirrorCopy := mirror
@@ -242,11 +311,13 @@ func (j *MirrorSyncJob) runScheduledSyncs(ctx context.Context) {
 // syncMirror performs the actual synchronization of a mirror.
 // coverage:skip:integration-only — constructs a live mirror.UpstreamRegistry HTTP client inline and drives sync history + status writes to the database; tested end-to-end via the api-test integration suite in cmd/api-test.
 func (j *MirrorSyncJob) syncMirror(ctx context.Context, config models.MirrorConfiguration) {
-	defer func() {
-		j.activeSyncsMutex.Lock()
-		delete(j.activeSyncs, config.ID)
-		j.activeSyncsMutex.Unlock()
-	}()
+	defer j.releaseSync(config.ID)
+
+	ctx, stopHeartbeat := j.holdSyncLease(ctx, config.ID)
+	defer stopHeartbeat()
+
+	ctx, op := j.operations.Start(ctx, operations.TypeMirrorSync, operations.ActorFromContext(ctx), "mirror/"+config.Name)
+	defer op.Finish()
 
 	log.Printf("Starting sync for mirror: %s (ID: %s)", config.Name, config.ID)
 
@@ -301,12 +372,31 @@ func (j *MirrorSyncJob) syncMirror(ctx context.Context, config models.MirrorConf
 			config.Name, syncHistory.ProvidersSynced, syncHistory.ProvidersFailed)
 		syncHistory.Status = "success"
 
+		// A run that skipped versions at the storage limit still succeeded, but
+		// the mirror's status says so until the limit is raised.
+		status := "success"
+		var statusMsg *string
+		if syncDetails != nil && syncDetails.VersionsSkipped > 0 {
+			status = models.MirrorSyncStatusLimitReached
+			msg := syncDetails.storageLimitError
+			statusMsg = &msg
+			log.Printf("Mirror %s: %s", config.Name, msg)
+		}
+
 		// Update mirror config with success (use cleanupCtx)
-		if updateErr := j.mirrorRepo.UpdateSyncStatus(cleanupCtx, config.ID, "success", nil); updateErr != nil {
-			log.Printf("ERROR: Failed to update mirror config status to 'success': %v", updateErr)
+		if updateErr := j.mirrorRepo.UpdateSyncStatus(cleanupCtx, config.ID, status, statusMsg); updateErr != nil {
+			log.Printf("ERROR: Failed to update mirror config status to '%s': %v", status, updateErr)
 		}
 	}
 
+	if syncDetails != nil && syncDetails.storageChecked {
+		checkStorageAlert(cleanupCtx, j.notifier, "Provider mirror", config.Name, config.StorageAlertLevel,
+			syncDetails.StorageUsedBytes, config.MaxStorageBytes,
+			func(ctx context.Context, level int) error {
+				return j.mirrorRepo.SetStorageAlertLevel(ctx, config.ID, level)
+			})
+	}
+
 	// Store sync details as JSON
 	if syncDetails != nil {
 		detailsJSON, _ := json.Marshal(syncDetails)
@@ -323,6 +413,13 @@ func (j *MirrorSyncJob) syncMirror(ctx context.Context, config models.MirrorConf
 	} else {
 		log.Printf("Successfully updated sync history for mirror %s", config.Name)
 	}
+
+	// Enforce the config's history retention now that this run is recorded
+	if pruned, err := j.mirrorRepo.PruneSyncHistory(cleanupCtx, config.ID, config.HistoryRetentionCount, config.HistoryRetentionDays); err != nil {
+		log.Printf("ERROR: Failed to prune sync history for mirror %s: %v", config.Name, err)
+	} else if pruned > 0 {
+		log.Printf("Pruned %d sync history entries for mirror %s", pruned, config.Name)
+	}
 }
 
 // SyncDetails contains detailed information about a sync operation
@@ -333,6 +430,22 @@ type SyncDetails struct {
 	ProvidersFailed int              `json:"providers_failed"`
 	Errors          []string         `json:"errors,omitempty"`
 	SyncedProviders []SyncedProvider `json:"synced_providers,omitempty"`
+	// ProvidersNotModified counts the synced providers the upstream reported
+	// unchanged since their last complete sync (HTTP 304), which were skipped.
+	ProvidersNotModified int `json:"providers_not_modified,omitempty"`
+	// UpstreamAPICalls counts the upstream registry API requests (discovery,
+	// version listings, package info, docs, metadata) the sync made;
+	// UpstreamDownloads counts the SHASUMS, signature and archive downloads.
+	UpstreamAPICalls  int64 `json:"upstream_api_calls"`
+	UpstreamDownloads int64 `json:"upstream_downloads"`
+	// StorageUsedBytes is the size of the mirror's stored archives when the
+	// sync finished. VersionsSkipped counts the new versions left unsynced
+	// because the mirror reached its max_storage_bytes.
+	StorageUsedBytes int64 `json:"storage_used_bytes,omitempty"`
+	VersionsSkipped  int   `json:"versions_skipped_storage_limit,omitempty"`
+
+	storageChecked    bool
+	storageLimitError string
 }
 
 // SyncedProvider contains information about a synced provider
@@ -341,6 +454,16 @@ type SyncedProvider struct {
 	Name        string   `json:"name"`
 	Versions    []string `json:"versions"`
 	VersionsNew int      `json:"versions_new"`
+	// NotModified is true when the upstream reported the version listing
+	// unchanged since the last complete sync, so the provider was skipped.
+	NotModified bool `json:"not_modified,omitempty"`
+	// Warnings lists non-fatal problems, such as upstream metadata or the
+	// license file being unavailable.
+	Warnings []string `json:"warnings,omitempty"`
+	// AddressMismatches lists the archives synced whose binary or manifest
+	// names a different provider, with the discrepancies found. They are
+	// mirrored regardless.
+	AddressMismatches []string `json:"address_mismatches,omitempty"`
 }
 
 // performSync performs the actual provider synchronization.
@@ -351,8 +474,32 @@ func (j *MirrorSyncJob) performSync(ctx context.Context, config models.MirrorCon
 	}
 
 	// Create upstream registry client via the injectable factory so tests can
-	// substitute a fake without real HTTP.
-	upstreamClient := j.newUpstream(config.UpstreamRegistryURL)
+	// substitute a fake without real HTTP. Calls are counted so the sync
+	// details show how hard the run leaned on the upstream API.
+	upstreamClient := mirror.NewCountingClient(j.newUpstream(config.UpstreamRegistryURL))
+	defer func() {
+		details.UpstreamAPICalls = upstreamClient.APICalls()
+		details.UpstreamDownloads = upstreamClient.Downloads()
+		log.Printf("Mirror %s made %d upstream API calls and %d downloads",
+			config.Name, details.UpstreamAPICalls, details.UpstreamDownloads)
+	}()
+
+	// Charge what this run downloads against the mirror's storage limit. When
+	// the current usage cannot be read the limit is not enforced this run.
+	if used, err := j.mirrorRepo.GetStorageUsage(ctx, config.ID); err != nil {
+		log.Printf("Warning: failed to read storage usage for mirror %s, storage limit not enforced: %v", config.Name, err)
+	} else {
+		budget := newStorageBudget(used, config.MaxStorageBytes)
+		ctx = withStorageBudget(ctx, budget)
+		defer func() {
+			details.storageChecked = true
+			details.StorageUsedBytes = budget.used
+			details.VersionsSkipped = budget.skipped
+			if budget.skipped > 0 {
+				details.storageLimitError = storageLimitMessage(budget)
+			}
+		}()
+	}
 
 	// Test service discovery first
 	_, err := upstreamClient.DiscoverServices(ctx)
@@ -363,6 +510,7 @@ func (j *MirrorSyncJob) performSync(ctx context.Context, config models.MirrorCon
 	// Parse namespace and provider filters
 	var namespaces []string
 	var providerNames []string
+	var excluded map[string]bool
 
 	if config.NamespaceFilter != nil && *config.NamespaceFilter != "" {
 		if err := json.Unmarshal([]byte(*config.NamespaceFilter), &namespaces); err != nil {
@@ -371,9 +519,13 @@ func (j *MirrorSyncJob) performSync(ctx context.Context, config models.MirrorCon
 	}
 
 	if config.ProviderFilter != nil && *config.ProviderFilter != "" {
-		if err := json.Unmarshal([]byte(*config.ProviderFilter), &providerNames); err != nil {
+		var entries []string
+		if err := json.Unmarshal([]byte(*config.ProviderFilter), &entries); err != nil {
 			return details, fmt.Errorf("invalid provider filter: %w", err)
 		}
+		// "!namespace/type" entries exclude pairs detached from the mirror
+		// when their provider was deleted.
+		providerNames, excluded = models.SplitProviderFilter(entries)
 	}
 
 	// Handle different filter combinations
@@ -398,21 +550,41 @@ func (j *MirrorSyncJob) performSync(ctx context.Context, config models.MirrorCon
 	// Sync all namespace/provider combinations
 	for _, namespace := range namespaces {
 		for _, providerName := range providerNames {
+			if !excluded[namespace+"/"+providerName] {
+				details.ProvidersFound++
+			}
+		}
+	}
+	op := operations.FromContext(ctx)
+	op.SetTotal(int64(details.ProvidersFound))
+	for _, namespace := range namespaces {
+		for _, providerName := range providerNames {
+			if excluded[namespace+"/"+providerName] {
+				continue
+			}
+			if ctx.Err() != nil {
+				return details, fmt.Errorf("sync cancelled: %w", ctx.Err())
+			}
 			syncedProvider, err := j.syncProvider(ctx, upstreamClient, config, namespace, providerName)
 			if err != nil {
 				details.ProvidersFailed++
 				details.Errors = append(details.Errors, fmt.Sprintf("%s/%s: %v", namespace, providerName, err))
 				log.Printf("Error syncing provider %s/%s: %v", namespace, providerName, err)
+			} else if syncedProvider.NotModified {
+				details.ProvidersSynced++
+				details.ProvidersNotModified++
+				details.SyncedProviders = append(details.SyncedProviders, *syncedProvider)
+				log.Printf("Provider %s/%s not modified upstream since its last sync", namespace, providerName)
 			} else {
 				details.ProvidersSynced++
 				details.SyncedProviders = append(details.SyncedProviders, *syncedProvider)
 				log.Printf("Successfully synced provider %s/%s (%d versions)", namespace, providerName, len(syncedProvider.Versions))
 			}
+			op.AddDone(1)
 		}
 	}
 
 	details.Namespaces = namespaces
-	details.ProvidersFound = len(namespaces) * len(providerNames)
 
 	return details, nil
 }
@@ -420,11 +592,29 @@ func (j *MirrorSyncJob) performSync(ctx context.Context, config models.MirrorCon
 // syncProvider syncs a single provider from upstream.
 // coverage:skip:integration-only — takes an UpstreamRegistryClient and drives real HTTP + DB flow; covered by integration tests.
 func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient mirror.UpstreamRegistryClient, config models.MirrorConfiguration, namespace, providerName string) (*SyncedProvider, error) {
+	// The mirror's tracking record for this provider carries the validators
+	// of the upstream listing as of its last complete sync. Sending them lets
+	// an unchanged upstream answer 304, which skips the provider.
+	tracked, err := j.mirrorRepo.GetMirroredProvider(ctx, config.ID, namespace, providerName)
+	if err != nil {
+		log.Printf("Warning: failed to load mirrored provider %s/%s, listing versions unconditionally: %v", namespace, providerName, err)
+	}
+
 	// List versions from upstream
-	allVersions, err := upstreamClient.ListProviderVersions(ctx, namespace, providerName)
+	listing, err := mirror.ListProviderVersionsConditional(ctx, upstreamClient, namespace, providerName, listingValidators(tracked))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list versions: %w", err)
 	}
+	if listing.NotModified {
+		if tracked != nil {
+			tracked.LastSyncedAt = time.Now()
+			if err := j.mirrorRepo.UpdateMirroredProvider(ctx, tracked); err != nil {
+				log.Printf("Warning: failed to update mirrored provider sync time for %s/%s: %v", namespace, providerName, err)
+			}
+		}
+		return &SyncedProvider{Namespace: namespace, Name: providerName, Versions: []string{}, NotModified: true}, nil
+	}
+	allVersions := listing.Versions
 
 	if len(allVersions) == 0 {
 		return nil, fmt.Errorf("no versions found")
@@ -479,6 +669,7 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient mirror.
 			Type:           providerName,
 			Description:    &description,
 			Source:         &source,
+			Tier:           models.TierMirrored,
 		}
 
 		if err := j.providerRepo.CreateProvider(ctx, localProvider); err != nil {
@@ -548,6 +739,15 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient mirror.
 		existingVersionMap[v.Version] = v
 	}
 
+	// license collects the license file from the newest archive downloaded
+	// during this sync.
+	license := &licenseCapture{}
+	// address flags downloaded archives that hold a different provider.
+	address := &addressCheck{namespace: namespace, providerType: providerName}
+
+	// complete is cleared when a version is left unsynced.
+	complete := true
+
 	// Sync each version
 	for _, version := range versions {
 		syncedProvider.Versions = append(syncedProvider.Versions, version.Version)
@@ -589,7 +789,12 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient mirror.
 				}
 			}
 
-			if (needsGPGKeyBackfill || needsGPGVerifyBackfill) && len(version.Platforms) > 0 {
+			// Versions synced before the SHA256SUMS file and signature were
+			// stored locally still point clients at upstream for them.
+			needsShasumStorageBackfill := existingVersion.ShasumURL != "" &&
+				(existingVersion.ShasumStorageKey == nil || *existingVersion.ShasumStorageKey == "")
+
+			if (needsGPGKeyBackfill || needsGPGVerifyBackfill || needsShasumStorageBackfill) && len(version.Platforms) > 0 {
 				p0 := version.Platforms[0]
 				if pkgInfo, pkgErr := upstreamClient.GetProviderPackage(ctx, namespace, providerName, version.Version, p0.OS, p0.Arch); pkgErr == nil {
 					if needsGPGKeyBackfill && len(pkgInfo.SigningKeys.GPGPublicKeys) > 0 {
@@ -603,9 +808,26 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient mirror.
 						}
 					}
 
+					var shasumContent, sigContent []byte
+					if (needsGPGVerifyBackfill && trackingRecord != nil) || needsShasumStorageBackfill {
+						shasumContent, _ = upstreamClient.DownloadFile(ctx, pkgInfo.SHASumsURL)
+						if pkgInfo.SHASumsSignatureURL != "" {
+							sigContent, _ = upstreamClient.DownloadFile(ctx, pkgInfo.SHASumsSignatureURL)
+						}
+					}
+
+					if needsShasumStorageBackfill && len(shasumContent) > 0 {
+						sumsKey, sigKey := j.storeShasumFiles(ctx, namespace, providerName, version.Version, shasumContent, sigContent)
+						if sumsKey != nil {
+							if err := j.providerRepo.UpdateVersionSignatureStorage(ctx, existingVersion.ID, sumsKey, sigKey); err != nil {
+								log.Printf("Warning: failed to backfill SHA256SUMS storage for %s/%s@%s: %v", namespace, providerName, version.Version, err)
+							} else {
+								log.Printf("Backfilled stored SHA256SUMS for %s/%s@%s", namespace, providerName, version.Version)
+							}
+						}
+					}
+
 					if needsGPGVerifyBackfill && trackingRecord != nil {
-						shasumContent, _ := upstreamClient.DownloadFile(ctx, pkgInfo.SHASumsURL)
-						sigContent, _ := upstreamClient.DownloadFile(ctx, pkgInfo.SHASumsSignatureURL)
 						if len(shasumContent) > 0 && len(sigContent) > 0 {
 							var resolvedKeys []string
 							for _, gpgKey := range pkgInfo.SigningKeys.GPGPublicKeys {
@@ -683,11 +905,20 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient mirror.
 				continue
 			}
 
+			if budget := storageBudgetFrom(ctx); budget.exhausted() {
+				budget.skip()
+				log.Printf("Storage limit reached, not re-syncing %d missing platform(s) of %s/%s@%s",
+					len(missingPlatforms), namespace, providerName, version.Version)
+				continue
+			}
+
 			log.Printf("Version %s of %s/%s exists but is missing %d platform(s), re-syncing those",
 				version.Version, namespace, providerName, len(missingPlatforms))
 
-			// Fetch SHASUM info once for this version then download missing platforms.
-			firstPlatform := version.Platforms[0]
+			// Fetch package info and SHASUMs once for this version, from the
+			// first missing platform so its own lookup is not repeated, then
+			// download missing platforms.
+			firstPlatform := missingPlatforms[0]
 			packageInfo, pkgErr := upstreamClient.GetProviderPackage(ctx, namespace, providerName, version.Version, firstPlatform.OS, firstPlatform.Arch)
 			var shasumMap map[string]string
 			if pkgErr == nil {
@@ -707,8 +938,12 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient mirror.
 			existingVersionRecord := &models.ProviderVersion{
 				ID: existingVersion.ID,
 			}
+			pkgs := &mirror.VersionPackages{Shasums: shasumMap}
+			if pkgErr == nil {
+				pkgs.Base = packageInfo
+			}
 			for _, mp := range missingPlatforms {
-				if err := j.syncPlatformBinary(ctx, upstreamClient, existingVersionRecord, namespace, providerName, version.Version, mp, shasumMap); err != nil {
+				if err := j.syncPlatformBinary(ctx, upstreamClient, config.UpstreamRegistryURL, existingVersionRecord, namespace, providerName, version.Version, mp, pkgs, license, address); err != nil {
 					log.Printf("Error re-syncing missing platform %s/%s for %s/%s@%s: %v",
 						mp.OS, mp.Arch, namespace, providerName, version.Version, err)
 				} else {
@@ -720,10 +955,18 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient mirror.
 			continue
 		}
 
+		if budget := storageBudgetFrom(ctx); budget.exhausted() {
+			budget.skip()
+			log.Printf("Storage limit reached, not syncing version %s of %s/%s", version.Version, namespace, providerName)
+			complete = false
+			continue
+		}
+
 		// Sync this version (download and create)
-		err := j.syncProviderVersion(ctx, upstreamClient, localProvider, mirroredProvider, namespace, providerName, version, config)
+		err := j.syncProviderVersion(ctx, upstreamClient, localProvider, mirroredProvider, namespace, providerName, version, config, license, address)
 		if err != nil {
 			log.Printf("Error syncing version %s of %s/%s: %v", version.Version, namespace, providerName, err)
+			complete = false
 			// Continue with other versions
 			continue
 		}
@@ -749,6 +992,26 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient mirror.
 		}
 	}
 
+	// Keep the listing's validators only when every version was synced, so a
+	// provider left incomplete is listed in full again next time.
+	if tracked == nil && mirroredProvider != nil && mirroredProvider.MirrorConfigID == config.ID {
+		tracked = mirroredProvider
+	}
+	if tracked != nil {
+		var etag, lastModified *string
+		if complete {
+			etag, lastModified = nonEmpty(listing.Validators.ETag), nonEmpty(listing.Validators.LastModified)
+		}
+		if err := j.mirrorRepo.SetMirroredProviderValidators(ctx, tracked.ID, etag, lastModified); err != nil {
+			log.Printf("Warning: failed to store upstream validators for %s/%s: %v", namespace, providerName, err)
+		}
+	}
+
+	// Upstream metadata and license are informational: problems are reported
+	// as warnings in the sync details and never fail the sync.
+	syncedProvider.Warnings = j.refreshProviderMetadata(ctx, upstreamClient, localProvider, namespace, providerName, license)
+	syncedProvider.AddressMismatches = address.mismatches
+
 	log.Printf("Synced %s/%s: %d total versions, %d new",
 		namespace, providerName, len(versions), syncedProvider.VersionsNew)
 
@@ -765,6 +1028,8 @@ func (j *MirrorSyncJob) syncProviderVersion(
 	namespace, providerName string,
 	version mirror.ProviderVersion,
 	config models.MirrorConfiguration,
+	license *licenseCapture,
+	address *addressCheck,
 ) error {
 	platformFilter := config.PlatformFilter
 	// Filter platforms if a filter is specified
@@ -777,8 +1042,13 @@ func (j *MirrorSyncJob) syncProviderVersion(
 		return fmt.Errorf("no platforms available for version %s", version.Version)
 	}
 
-	// Get package info for the first platform to get signing keys and SHASUM URLs
+	// Get package info for one platform to get signing keys and SHASUM URLs.
+	// The first platform being synced is preferred so its lookup is reused
+	// for its download.
 	firstPlatform := version.Platforms[0]
+	if len(platforms) > 0 {
+		firstPlatform = platforms[0]
+	}
 	packageInfo, err := upstreamClient.GetProviderPackage(ctx, namespace, providerName, version.Version, firstPlatform.OS, firstPlatform.Arch)
 	if err != nil {
 		return fmt.Errorf("failed to get package info: %w", err)
@@ -805,8 +1075,9 @@ func (j *MirrorSyncJob) syncProviderVersion(
 
 	// Download and verify the GPG signature
 	gpgVerified := false
+	var sigContent []byte
 	if len(shasumContent) > 0 && gpgPublicKey != "" {
-		sigContent, err := upstreamClient.DownloadFile(ctx, packageInfo.SHASumsSignatureURL)
+		sigContent, err = upstreamClient.DownloadFile(ctx, packageInfo.SHASumsSignatureURL)
 		if err != nil {
 			log.Printf("Warning: failed to download SHASUM signature: %v", err)
 		} else {
@@ -845,6 +1116,11 @@ func (j *MirrorSyncJob) syncProviderVersion(
 		ShasumURL:          packageInfo.SHASumsURL,
 		ShasumSignatureURL: packageInfo.SHASumsSignatureURL,
 	}
+	// Serve the SHA256SUMS file and signature from the registry, so clients
+	// that cannot reach upstream can still verify the download. The upstream
+	// URLs stay on the record for audit and as the fallback.
+	versionRecord.ShasumStorageKey, versionRecord.ShasumSignatureStorageKey =
+		j.storeShasumFiles(ctx, namespace, providerName, version.Version, shasumContent, sigContent)
 
 	if err := j.providerRepo.CreateVersion(ctx, versionRecord); err != nil {
 		return fmt.Errorf("failed to create version record: %w", err)
@@ -886,10 +1162,13 @@ func (j *MirrorSyncJob) syncProviderVersion(
 		}
 	}
 
-	// Download and store each platform binary (using filtered platforms)
+	// Download and store each platform binary (using filtered platforms). The
+	// version-level package info is reused across platforms rather than
+	// looked up again for each.
+	pkgs := &mirror.VersionPackages{Base: packageInfo, Shasums: shasumMap}
 	platformsDownloaded := 0
 	for _, platform := range platforms {
-		err := j.syncPlatformBinary(ctx, upstreamClient, versionRecord, namespace, providerName, version.Version, platform, shasumMap)
+		err := j.syncPlatformBinary(ctx, upstreamClient, config.UpstreamRegistryURL, versionRecord, namespace, providerName, version.Version, platform, pkgs, license, address)
 		if err != nil {
 			log.Printf("Error syncing platform %s/%s for %s/%s@%s: %v",
 				platform.OS, platform.Arch, namespace, providerName, version.Version, err)
@@ -945,6 +1224,12 @@ func (j *MirrorSyncJob) syncProviderVersion(
 		}
 	}
 
+	if j.resigner != nil && gpgVerified {
+		if err := j.resigner.ResignVersion(ctx, localProvider, versionRecord.ID, version.Version); err != nil {
+			log.Printf("Warning: failed to re-sign %s/%s@%s: %v", namespace, providerName, version.Version, err)
+		}
+	}
+
 	log.Printf("Synced version %s: %d/%d platforms downloaded", version.Version, platformsDownloaded, len(platforms))
 	return nil
 }
@@ -995,31 +1280,85 @@ func (j *MirrorSyncJob) resolveProviderApproval(
 	return &pending, ""
 }
 
-// syncPlatformBinary downloads and stores a single platform binary.
+// storeShasumFiles stores a mirrored version's SHA256SUMS file and detached
+// signature in the registry's storage and returns their keys, so download
+// responses can point at the registry instead of upstream. A file that was
+// not downloaded or could not be stored gets a nil key; the signature is only
+// stored alongside the SHA256SUMS file it signs.
+func (j *MirrorSyncJob) storeShasumFiles(ctx context.Context, namespace, providerName, version string, sums, sig []byte) (sumsKey, sigKey *string) {
+	if len(sums) == 0 {
+		return nil, nil
+	}
+	upload := func(file string, content []byte) *string {
+		result, err := j.storageBackend.Upload(ctx, storage.ProviderFileKey(namespace, providerName, version, file),
+			bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			log.Printf("Warning: failed to store %s for %s/%s@%s: %v", file, namespace, providerName, version, err)
+			return nil
+		}
+		return &result.Path
+	}
+	if sumsKey = upload("SHA256SUMS", sums); sumsKey == nil {
+		return nil, nil
+	}
+	if len(sig) > 0 {
+		sigKey = upload("SHA256SUMS.sig", sig)
+	}
+	return sumsKey, sigKey
+}
+
+// syncPlatformBinary downloads and stores a single platform binary, recording
+// the upstream registry and URLs it was fetched from as its provenance. The
+// platform's package info is built from pkgs when possible; the upstream is
+// only asked for it when pkgs cannot resolve the platform or the download
+// from the derived URL fails.
 // coverage:skip:integration-only — streams a real provider archive from upstream, verifies its checksum, and writes to the storage backend; exercised by integration tests.
 func (j *MirrorSyncJob) syncPlatformBinary(
 	ctx context.Context,
 	upstreamClient mirror.UpstreamRegistryClient,
+	upstreamRegistryURL string,
 	versionRecord *models.ProviderVersion,
 	namespace, providerName, version string,
 	platform mirror.ProviderPlatform,
-	shasumMap map[string]string,
+	pkgs *mirror.VersionPackages,
+	license *licenseCapture,
+	address *addressCheck,
 ) error {
+	fetchPackage := func() (*mirror.ProviderPackageResponse, error) {
+		packageInfo, err := upstreamClient.GetProviderPackage(ctx, namespace, providerName, version, platform.OS, platform.Arch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get package info: %w", err)
+		}
+		return packageInfo, nil
+	}
+
 	// Get download info for this platform
-	packageInfo, err := upstreamClient.GetProviderPackage(ctx, namespace, providerName, version, platform.OS, platform.Arch)
-	if err != nil {
-		return fmt.Errorf("failed to get package info: %w", err)
+	packageInfo := pkgs.Platform(platform)
+	reused := packageInfo != nil
+	if !reused {
+		var err error
+		if packageInfo, err = fetchPackage(); err != nil {
+			return err
+		}
 	}
 
 	log.Printf("Downloading %s from %s", packageInfo.Filename, packageInfo.DownloadURL)
+	fetchedAt := time.Now()
 
 	// Stream binary to a temp file to avoid buffering large zips in memory.
 	stream, err := upstreamClient.DownloadFileStream(ctx, packageInfo.DownloadURL)
+	if err != nil && reused {
+		log.Printf("Download of %s from version-level package info failed (%v); asking upstream for its package info", packageInfo.Filename, err)
+		if packageInfo, err = fetchPackage(); err != nil {
+			return err
+		}
+		stream, err = upstreamClient.DownloadFileStream(ctx, packageInfo.DownloadURL)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to download binary: %w", err)
 	}
 
-	tmpFile, err := os.CreateTemp("", "provider-binary-*.zip")
+	tmpFile, err := os.CreateTemp(j.scratchDir, "provider-binary-*.zip")
 	if err != nil {
 		stream.Body.Close()
 		return fmt.Errorf("failed to create temp file: %w", err)
@@ -1042,9 +1381,7 @@ func (j *MirrorSyncJob) syncPlatformBinary(
 	expectedChecksum := packageInfo.SHA256Sum
 	if expectedChecksum == "" {
 		// Try to get from SHASUM file
-		if cs, ok := shasumMap[packageInfo.Filename]; ok {
-			expectedChecksum = cs
-		}
+		expectedChecksum = pkgs.Checksum(packageInfo.Filename)
 	}
 
 	if expectedChecksum != "" && checksumHex != expectedChecksum {
@@ -1054,24 +1391,30 @@ func (j *MirrorSyncJob) syncPlatformBinary(
 	log.Printf("Checksum verified for %s: %s", packageInfo.Filename, checksumHex)
 
 	// packageInfo.Filename comes straight from the upstream registry's package
-	// descriptor, unlike the other segments of this path (which are validated
-	// registry identifiers / platform values); reject path separators and '..'
-	// before it reaches the storage key (issue #677).
+	// descriptor and is recorded and served as the platform filename; reject
+	// path separators and '..' (issue #677).
 	if err := validation.ValidateStorageFilename(packageInfo.Filename); err != nil {
 		return fmt.Errorf("unsafe filename from upstream package descriptor: %w", err)
 	}
 
 	// Store the binary
-	storagePath := fmt.Sprintf("providers/%s/%s/%s/%s/%s/%s",
-		namespace, providerName, version, platform.OS, platform.Arch, packageInfo.Filename)
+	storagePath := storage.ProviderArchiveKey(namespace, providerName, version, platform.OS, platform.Arch)
 
 	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
 		return fmt.Errorf("failed to seek temp file: %w", err)
 	}
 
-	uploadResult, err := j.storageBackend.Upload(ctx, storagePath, tmpFile, written)
-	if err != nil {
-		return fmt.Errorf("failed to store binary: %w", err)
+	if j.blobs != nil {
+		storagePath, err = j.blobs.Store(ctx, checksumHex, tmpFile, written)
+		if err != nil {
+			return fmt.Errorf("failed to store binary: %w", err)
+		}
+	} else {
+		uploadResult, err := j.storageBackend.Upload(ctx, storagePath, tmpFile, written)
+		if err != nil {
+			return fmt.Errorf("failed to store binary: %w", err)
+		}
+		storagePath = uploadResult.Path
 	}
 
 	// Create platform record
@@ -1080,10 +1423,12 @@ func (j *MirrorSyncJob) syncPlatformBinary(
 		OS:                platform.OS,
 		Arch:              platform.Arch,
 		Filename:          packageInfo.Filename,
-		StoragePath:       uploadResult.Path,
+		StoragePath:       storagePath,
 		StorageBackend:    j.storageBackendName,
 		SizeBytes:         written,
 		Shasum:            checksumHex,
+		Upstream: models.NewUpstreamProvenance(models.UpstreamHostname(upstreamRegistryURL),
+			packageInfo.DownloadURL, packageInfo.SHASumsURL, fetchedAt),
 	}
 
 	// Compute the h1: dirhash for the zip archive so Terraform's network mirror
@@ -1095,9 +1440,20 @@ func (j *MirrorSyncJob) syncPlatformBinary(
 		platformRecord.H1Hash = &h1
 	}
 
+	if license.wants(version) {
+		license.capture(version, tmpFile, written)
+	}
+	address.check(packageInfo.Filename, tmpFile, written)
+
 	if err := j.providerRepo.CreatePlatform(ctx, platformRecord); err != nil {
 		return fmt.Errorf("failed to create platform record: %w", err)
 	}
+	storageBudgetFrom(ctx).add(written)
+	if j.blobs != nil {
+		if err := j.blobs.Attach(ctx, platformRecord.ID, checksumHex, tmpFile, written); err != nil {
+			return fmt.Errorf("failed to reference stored binary: %w", err)
+		}
+	}
 
 	log.Printf("Stored platform %s/%s: %s (%d bytes)", platform.OS, platform.Arch, storagePath, written)
 	return nil
@@ -1141,36 +1497,33 @@ func verifyGPGSignature(shasumContent, signatureContent []byte, publicKeys []str
 // TriggerManualSync triggers a manual sync for a specific mirror.
 // coverage:skip:integration-only — orchestrates the full sync pipeline via syncMirror/performSync which themselves require a live upstream registry.
 func (j *MirrorSyncJob) TriggerManualSync(ctx context.Context, mirrorID uuid.UUID) error {
-	// Check if already syncing and mark as active atomically
-	j.activeSyncsMutex.Lock()
-	if j.activeSyncs[mirrorID] {
-		j.activeSyncsMutex.Unlock()
+	// Check if already syncing and mark as active atomically to prevent races
+	acquired, err := j.acquireSync(ctx, mirrorID)
+	if err != nil {
+		return fmt.Errorf("failed to acquire sync lease: %w", err)
+	}
+	if !acquired {
 		return fmt.Errorf("sync already in progress for this mirror")
 	}
-	// Mark as active immediately to prevent race conditions
-	j.activeSyncs[mirrorID] = true
-	j.activeSyncsMutex.Unlock()
 
 	// Get mirror config using the request context
 	config, err := j.mirrorRepo.GetByID(ctx, mirrorID)
 	if err != nil {
 		// If we fail to get config, clean up the active sync flag
-		j.activeSyncsMutex.Lock()
-		delete(j.activeSyncs, mirrorID)
-		j.activeSyncsMutex.Unlock()
+		j.releaseSync(mirrorID)
 		return fmt.Errorf("failed to get mirror configuration: %w", err)
 	}
 	if config == nil {
 		// If config not found, clean up the active sync flag
-		j.activeSyncsMutex.Lock()
-		delete(j.activeSyncs, mirrorID)
-		j.activeSyncsMutex.Unlock()
+		j.releaseSync(mirrorID)
 		return fmt.Errorf("mirror configuration not found")
 	}
 
 	// Use a background context for the sync operation since the HTTP request
-	// context will be cancelled when the response is sent
-	go j.syncMirror(context.Background(), *config) // #nosec G118 -- request context cancels when response is sent; background context is required for async sync
+	// context will be cancelled when the response is sent. The caller's actor
+	// (WithActor) is carried over so the operations registry attributes the
+	// sync to them.
+	go j.syncMirror(operations.WithActor(context.Background(), operations.ActorFromContext(ctx)), *config) // #nosec G118 -- request context cancels when response is sent; background context is required for async sync
 
 	return nil
 }
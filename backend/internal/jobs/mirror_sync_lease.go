@@ -0,0 +1,116 @@
+// mirror_sync_lease.go guards provider mirror syncs with database leases, so
+// a mirror is synced by one replica at a time and a sync that dies without
+// cleaning up (an OOM-killed pod, a panicking goroutine) stops blocking the
+// mirror once its heartbeat goes stale.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/safego"
+)
+
+// MirrorSyncLeaseStore persists mirror sync leases.
+// *repositories.MirrorSyncLeaseRepository satisfies it.
+type MirrorSyncLeaseStore interface {
+	TryAcquire(ctx context.Context, mirrorID uuid.UUID, holder string, ttl time.Duration) (bool, error)
+	Heartbeat(ctx context.Context, mirrorID uuid.UUID, holder string) (bool, error)
+	Release(ctx context.Context, mirrorID uuid.UUID, holder string) error
+}
+
+// SetSyncLeases replaces the in-process overlap guard with database leases
+// held under this process's identity. Call before Start.
+func (j *MirrorSyncJob) SetSyncLeases(store MirrorSyncLeaseStore) {
+	j.leases = store
+	j.leaseHolder = newLeaseHolder()
+}
+
+// newLeaseHolder identifies this process in mirror_sync_leases: the hostname
+// (the pod name under Kubernetes) plus a random suffix, so a restarted
+// process never mistakes its predecessor's lease for its own.
+func newLeaseHolder() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s/%s", host, uuid.NewString()[:8])
+}
+
+// acquireSync claims the right to sync a mirror, reporting false when a sync
+// of it is already running here or on another replica.
+func (j *MirrorSyncJob) acquireSync(ctx context.Context, mirrorID uuid.UUID) (bool, error) {
+	if j.leases == nil {
+		return j.activeSyncs.tryAcquire(mirrorID), nil
+	}
+	return j.leases.TryAcquire(ctx, mirrorID, j.leaseHolder, models.MirrorSyncLeaseTTL)
+}
+
+// releaseSync gives up the claim taken by acquireSync. It runs on its own
+// context so a cancelled sync still releases its lease.
+func (j *MirrorSyncJob) releaseSync(mirrorID uuid.UUID) {
+	if j.leases == nil {
+		j.activeSyncs.release(mirrorID)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := j.leases.Release(ctx, mirrorID, j.leaseHolder); err != nil {
+		log.Printf("Warning: failed to release sync lease for mirror %s: %v", mirrorID, err)
+	}
+}
+
+// holdSyncLease heartbeats the mirror's lease until the returned stop func is
+// called. The returned context is cancelled if the lease is lost (an admin
+// cleared it, or it went stale and another replica took over), so the sync
+// stops instead of running alongside the new holder. A failed heartbeat is
+// retried on the next tick. Without database leases it returns ctx unchanged.
+func (j *MirrorSyncJob) holdSyncLease(ctx context.Context, mirrorID uuid.UUID) (context.Context, func()) {
+	if j.leases == nil {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	safego.Go(func() {
+		ticker := time.NewTicker(j.leaseHeartbeatInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				held, err := j.leases.Heartbeat(ctx, mirrorID, j.leaseHolder)
+				if err != nil {
+					log.Printf("Warning: failed to heartbeat sync lease for mirror %s: %v", mirrorID, err)
+					continue
+				}
+				if !held {
+					log.Printf("Sync lease for mirror %s was lost; stopping the sync", mirrorID)
+					cancel()
+					return
+				}
+			}
+		}
+	})
+	return ctx, func() {
+		close(done)
+		cancel()
+	}
+}
+
+// leaseHeartbeatInterval is models.MirrorSyncLeaseHeartbeat unless a test
+// shortened it.
+func (j *MirrorSyncJob) leaseHeartbeatInterval() time.Duration {
+	if j.heartbeatEvery > 0 {
+		return j.heartbeatEvery
+	}
+	return models.MirrorSyncLeaseHeartbeat
+}
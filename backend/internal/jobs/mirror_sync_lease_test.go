@@ -0,0 +1,164 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeLeaseStore is an in-memory MirrorSyncLeaseStore. Leases never go
+// stale; clear simulates an admin force-release.
+type fakeLeaseStore struct {
+	mu         sync.Mutex
+	holders    map[uuid.UUID]string
+	released   []uuid.UUID
+	acquireErr error
+}
+
+func newFakeLeaseStore() *fakeLeaseStore {
+	return &fakeLeaseStore{holders: make(map[uuid.UUID]string)}
+}
+
+func (f *fakeLeaseStore) TryAcquire(_ context.Context, id uuid.UUID, holder string, _ time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.acquireErr != nil {
+		return false, f.acquireErr
+	}
+	if _, held := f.holders[id]; held {
+		return false, nil
+	}
+	f.holders[id] = holder
+	return true, nil
+}
+
+func (f *fakeLeaseStore) Heartbeat(_ context.Context, id uuid.UUID, holder string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.holders[id] == holder, nil
+}
+
+func (f *fakeLeaseStore) Release(_ context.Context, id uuid.UUID, holder string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.holders[id] == holder {
+		delete(f.holders, id)
+	}
+	f.released = append(f.released, id)
+	return nil
+}
+
+func (f *fakeLeaseStore) clear(id uuid.UUID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.holders, id)
+}
+
+func TestNewLeaseHolder_Unique(t *testing.T) {
+	a, b := newLeaseHolder(), newLeaseHolder()
+	if a == b || !strings.Contains(a, "/") {
+		t.Errorf("newLeaseHolder() = %q, %q; want distinct host/suffix values", a, b)
+	}
+}
+
+func TestAcquireSync_FallsBackToActiveRuns(t *testing.T) {
+	j := &MirrorSyncJob{activeSyncs: newActiveRuns()}
+	id := uuid.New()
+
+	if ok, err := j.acquireSync(context.Background(), id); err != nil || !ok {
+		t.Fatalf("first acquireSync = (%v, %v), want (true, nil)", ok, err)
+	}
+	if ok, _ := j.acquireSync(context.Background(), id); ok {
+		t.Error("second acquireSync succeeded while the first was held")
+	}
+	j.releaseSync(id)
+	if ok, _ := j.acquireSync(context.Background(), id); !ok {
+		t.Error("acquireSync failed after release")
+	}
+}
+
+func TestAcquireSync_UsesLeases(t *testing.T) {
+	store := newFakeLeaseStore()
+	replicaA := &MirrorSyncJob{activeSyncs: newActiveRuns()}
+	replicaA.SetSyncLeases(store)
+	replicaB := &MirrorSyncJob{activeSyncs: newActiveRuns()}
+	replicaB.SetSyncLeases(store)
+	id := uuid.New()
+
+	if ok, err := replicaA.acquireSync(context.Background(), id); err != nil || !ok {
+		t.Fatalf("replica A acquireSync = (%v, %v), want (true, nil)", ok, err)
+	}
+	if ok, _ := replicaB.acquireSync(context.Background(), id); ok {
+		t.Error("replica B acquired a lease held by replica A")
+	}
+	replicaA.releaseSync(id)
+	if ok, _ := replicaB.acquireSync(context.Background(), id); !ok {
+		t.Error("replica B could not acquire after replica A released")
+	}
+}
+
+func TestTriggerManualSync_LeaseHeld(t *testing.T) {
+	store := newFakeLeaseStore()
+	j := &MirrorSyncJob{activeSyncs: newActiveRuns()}
+	j.SetSyncLeases(store)
+	id := uuid.New()
+	store.holders[id] = "other-replica"
+
+	err := j.TriggerManualSync(context.Background(), id)
+	if err == nil || err.Error() != "sync already in progress for this mirror" {
+		t.Errorf("TriggerManualSync err = %v, want sync already in progress", err)
+	}
+}
+
+func TestTriggerManualSync_LeaseError(t *testing.T) {
+	store := newFakeLeaseStore()
+	store.acquireErr = errors.New("db down")
+	j := &MirrorSyncJob{activeSyncs: newActiveRuns()}
+	j.SetSyncLeases(store)
+
+	err := j.TriggerManualSync(context.Background(), uuid.New())
+	if err == nil || !strings.Contains(err.Error(), "db down") {
+		t.Errorf("TriggerManualSync err = %v, want the lease error", err)
+	}
+}
+
+func TestHoldSyncLease_CancelsWhenLeaseLost(t *testing.T) {
+	store := newFakeLeaseStore()
+	j := &MirrorSyncJob{activeSyncs: newActiveRuns(), heartbeatEvery: 5 * time.Millisecond}
+	j.SetSyncLeases(store)
+	id := uuid.New()
+	if ok, _ := j.acquireSync(context.Background(), id); !ok {
+		t.Fatal("acquireSync failed")
+	}
+
+	ctx, stop := j.holdSyncLease(context.Background(), id)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("sync context cancelled while the lease was held")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	store.clear(id)
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("sync context not cancelled after the lease was cleared")
+	}
+}
+
+func TestHoldSyncLease_NoLeases(t *testing.T) {
+	j := &MirrorSyncJob{activeSyncs: newActiveRuns()}
+	parent := context.Background()
+	ctx, stop := j.holdSyncLease(parent, uuid.New())
+	stop()
+	if ctx != parent || ctx.Err() != nil {
+		t.Error("holdSyncLease without leases should return the parent context untouched")
+	}
+}
@@ -2,6 +2,9 @@ package jobs
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -499,6 +502,9 @@ type fakeUpstreamClient struct {
 	pkgErr error
 	binary string // DownloadFileStream body content
 	dlErr  error
+
+	metadata    *mirror.ProviderMetadata
+	metadataErr error
 }
 
 func (f *fakeUpstreamClient) DiscoverServices(_ context.Context) (*mirror.ServiceDiscoveryResponse, error) {
@@ -525,6 +531,9 @@ func (f *fakeUpstreamClient) GetProviderDocIndexByVersion(_ context.Context, _,
 func (f *fakeUpstreamClient) GetProviderDocContent(_ context.Context, _ string) (string, error) {
 	return "", nil
 }
+func (f *fakeUpstreamClient) GetProviderMetadata(_ context.Context, _, _ string) (*mirror.ProviderMetadata, error) {
+	return f.metadata, f.metadataErr
+}
 
 var _ mirror.UpstreamRegistryClient = (*fakeUpstreamClient)(nil)
 
@@ -545,8 +554,8 @@ func TestSyncPlatformBinary_RejectsUnsafeUpstreamFilename(t *testing.T) {
 	}
 	versionRecord := &models.ProviderVersion{ID: "v1"}
 
-	err := job.syncPlatformBinary(context.Background(), upstream, versionRecord,
-		"hashicorp", "aws", "5.0.0", mirror.ProviderPlatform{OS: "linux", Arch: "amd64"}, nil)
+	err := job.syncPlatformBinary(context.Background(), upstream, "https://registry.terraform.io", versionRecord,
+		"hashicorp", "aws", "5.0.0", mirror.ProviderPlatform{OS: "linux", Arch: "amd64"}, nil, nil, nil)
 	if err == nil {
 		t.Fatal("expected error for path-traversal filename from upstream package descriptor")
 	}
@@ -589,7 +598,11 @@ func TestSyncPlatformBinary_AcceptsWellFormedFilename(t *testing.T) {
 		t.Fatalf("sqlmock.New: %v", err)
 	}
 	defer db.Close()
+	a := sqlmock.AnyArg()
 	mock.ExpectQuery("INSERT INTO provider_platforms").
+		// The upstream provenance is recorded after the nine platform columns.
+		WithArgs(a, a, a, a, a, a, a, a, a,
+			models.ProvenanceRecorded, "registry.terraform.io", "https://upstream.example.com/download", nil, a).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("platform-1"))
 
 	job := NewMirrorSyncJob(nil, repositories.NewProviderRepository(db), nil, nil, &fakeUploadStorage{}, "local")
@@ -602,14 +615,114 @@ func TestSyncPlatformBinary_AcceptsWellFormedFilename(t *testing.T) {
 	}
 	versionRecord := &models.ProviderVersion{ID: "v1"}
 
-	err = job.syncPlatformBinary(context.Background(), upstream, versionRecord,
-		"hashicorp", "aws", "5.0.0", mirror.ProviderPlatform{OS: "linux", Arch: "amd64"}, nil)
+	err = job.syncPlatformBinary(context.Background(), upstream, "https://registry.terraform.io", versionRecord,
+		"hashicorp", "aws", "5.0.0", mirror.ProviderPlatform{OS: "linux", Arch: "amd64"}, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("syncPlatformBinary: %v", err)
 	}
 	gotStorage := job.storageBackend.(*fakeUploadStorage)
-	wantPath := "providers/hashicorp/aws/5.0.0/linux/amd64/terraform-provider-aws_5.0.0_linux_amd64.zip"
+	wantPath := storage.ProviderArchiveKey("hashicorp", "aws", "5.0.0", "linux", "amd64")
 	if gotStorage.uploadedPath != wantPath {
 		t.Errorf("uploaded path = %q, want %q", gotStorage.uploadedPath, wantPath)
 	}
 }
+
+// TestSyncPlatformBinary_ReusesVersionPackages checks that a platform whose
+// package info can be built from the version-level metadata is downloaded
+// without a per-platform package lookup.
+func TestSyncPlatformBinary_ReusesVersionPackages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	a := sqlmock.AnyArg()
+	derivedURL := "https://releases.example.com/aws/5.0.0/terraform-provider-aws_5.0.0_darwin_arm64.zip"
+	mock.ExpectQuery("INSERT INTO provider_platforms").
+		WithArgs(a, a, a, a, a, a, a, a, a,
+			models.ProvenanceRecorded, "registry.terraform.io", derivedURL, "https://releases.example.com/aws/5.0.0/SHA256SUMS", a).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("platform-1"))
+
+	binary := "fake-binary-content"
+	sum := sha256.Sum256([]byte(binary))
+	job := NewMirrorSyncJob(nil, repositories.NewProviderRepository(db), nil, nil, &fakeUploadStorage{}, "local")
+	upstream := &fakeUpstreamClient{pkgErr: errors.New("package lookup must not be called"), binary: binary}
+	pkgs := &mirror.VersionPackages{
+		Base: &mirror.ProviderPackageResponse{
+			OS: "linux", Arch: "amd64",
+			Filename:    "terraform-provider-aws_5.0.0_linux_amd64.zip",
+			DownloadURL: "https://releases.example.com/aws/5.0.0/terraform-provider-aws_5.0.0_linux_amd64.zip",
+			SHASumsURL:  "https://releases.example.com/aws/5.0.0/SHA256SUMS",
+		},
+		Shasums: map[string]string{"terraform-provider-aws_5.0.0_darwin_arm64.zip": hex.EncodeToString(sum[:])},
+	}
+
+	err = job.syncPlatformBinary(context.Background(), upstream, "https://registry.terraform.io", &models.ProviderVersion{ID: "v1"},
+		"hashicorp", "aws", "5.0.0", mirror.ProviderPlatform{OS: "darwin", Arch: "arm64"}, pkgs, nil, nil)
+	if err != nil {
+		t.Fatalf("syncPlatformBinary: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// storeShasumFiles
+// ---------------------------------------------------------------------------
+
+// failingUploadStorage fails uploads to the paths in fail.
+type failingUploadStorage struct {
+	fakeUploadStorage
+	fail map[string]bool
+}
+
+func (s *failingUploadStorage) Upload(ctx context.Context, path string, r io.Reader, size int64) (*storage.UploadResult, error) {
+	if s.fail[path] {
+		return nil, storage.ErrArtifactImmutable
+	}
+	return s.fakeUploadStorage.Upload(ctx, path, r, size)
+}
+
+func TestStoreShasumFiles(t *testing.T) {
+	sumsPath := storage.ProviderFileKey("hashicorp", "aws", "5.0.0", "SHA256SUMS")
+	sigPath := storage.ProviderFileKey("hashicorp", "aws", "5.0.0", "SHA256SUMS.sig")
+	sums, sig := []byte("abc  terraform-provider-aws_5.0.0_linux_amd64.zip\n"), []byte("sig")
+
+	job := NewMirrorSyncJob(nil, nil, nil, nil, &failingUploadStorage{}, "local")
+	sumsKey, sigKey := job.storeShasumFiles(context.Background(), "hashicorp", "aws", "5.0.0", sums, sig)
+	if sumsKey == nil || *sumsKey != sumsPath || sigKey == nil || *sigKey != sigPath {
+		t.Errorf("keys = %v, %v, want %q, %q", sumsKey, sigKey, sumsPath, sigPath)
+	}
+
+	if sumsKey, sigKey = job.storeShasumFiles(context.Background(), "hashicorp", "aws", "5.0.0", sums, nil); sumsKey == nil || sigKey != nil {
+		t.Errorf("without a signature: keys = %v, %v, want only the SHA256SUMS key", sumsKey, sigKey)
+	}
+	if sumsKey, sigKey = job.storeShasumFiles(context.Background(), "hashicorp", "aws", "5.0.0", nil, sig); sumsKey != nil || sigKey != nil {
+		t.Errorf("without SHA256SUMS: keys = %v, %v, want none", sumsKey, sigKey)
+	}
+
+	// The signature is useless without the file it signs.
+	job = NewMirrorSyncJob(nil, nil, nil, nil, &failingUploadStorage{fail: map[string]bool{sumsPath: true}}, "local")
+	if sumsKey, sigKey = job.storeShasumFiles(context.Background(), "hashicorp", "aws", "5.0.0", sums, sig); sumsKey != nil || sigKey != nil {
+		t.Errorf("failed SHA256SUMS upload: keys = %v, %v, want none", sumsKey, sigKey)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// listingValidators
+// ---------------------------------------------------------------------------
+
+func TestListingValidators(t *testing.T) {
+	if v := listingValidators(nil); !v.IsZero() {
+		t.Errorf("untracked provider: validators = %+v, want none", v)
+	}
+	tracked := &models.MirroredProvider{UpstreamETag: strPtr(`"abc"`)}
+	if v := listingValidators(tracked); v != (mirror.VersionsValidators{ETag: `"abc"`}) {
+		t.Errorf("validators = %+v, want the stored ETag", v)
+	}
+	tracked.UpstreamLastModified = strPtr("Mon, 02 Jan 2006 15:04:05 GMT")
+	if v := listingValidators(tracked); v.LastModified != "Mon, 02 Jan 2006 15:04:05 GMT" {
+		t.Errorf("validators = %+v, want the stored Last-Modified", v)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/safego"
 	"github.com/terraform-registry/terraform-registry/internal/scanner"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
@@ -25,9 +26,11 @@ type ModuleScannerJob struct {
 	scanRepo   *repositories.ModuleScanRepository
 	moduleRepo *repositories.ModuleRepository
 	storage    storage.Storage
+	scratchDir string // where archives are unpacked for scanning; "" = OS temp dir
 	stopChan   chan struct{}
 	mu         sync.Mutex
 	started    bool
+	scheduled
 }
 
 // NewModuleScannerJob constructs a ModuleScannerJob.
@@ -46,6 +49,12 @@ func NewModuleScannerJob(
 	}
 }
 
+// SetScratch unpacks archives for scanning in the registry's scratch
+// directory. Call before Start.
+func (j *ModuleScannerJob) SetScratch(space *scratch.Space) {
+	j.scratchDir = space.Dir()
+}
+
 // Name returns the human-readable job name used in logs.
 func (j *ModuleScannerJob) Name() string { return "module-scanner" }
 
@@ -119,25 +128,17 @@ func (j *ModuleScannerJob) Start(ctx context.Context) error {
 		interval = 5 * time.Minute
 	}
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	// Run once immediately before entering the ticker loop.
-	j.runScanCycle(ctx, s, actualVersion)
-
-	for {
-		select {
-		case <-ticker.C:
-			j.runScanCycle(ctx, s, actualVersion)
-		case <-stopChan:
-			return nil
-		case <-ctx.Done():
-			j.mu.Lock()
-			j.started = false
-			j.mu.Unlock()
-			return nil
-		}
+	// Runs once immediately, then on the interval.
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: interval}, stopChan, func(runCtx context.Context) {
+		j.runScanCycle(runCtx, s, actualVersion)
+	})
+	if ctx.Err() != nil {
+		// Stop() already cleared started when it closed stopChan.
+		j.mu.Lock()
+		j.started = false
+		j.mu.Unlock()
 	}
+	return nil
 }
 
 // Stop signals the job to exit gracefully.
@@ -201,7 +202,7 @@ func (j *ModuleScannerJob) scanOne(ctx context.Context, s scanner.Scanner, scanI
 		return
 	}
 
-	tmpDir, err := os.MkdirTemp("", "scan-*")
+	tmpDir, err := os.MkdirTemp(j.scratchDir, "scan-*")
 	if err != nil {
 		_ = j.scanRepo.MarkError(ctx, scanID, fmt.Sprintf("mkdirtemp: %v", err))
 		return
@@ -4,9 +4,12 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"time"
 
 	identitynotify "github.com/sethbacon/terraform-suite-identity/identity/notify"
 
+	"github.com/terraform-registry/terraform-registry/internal/downloadstats"
+	"github.com/terraform-registry/terraform-registry/internal/events"
 	"github.com/terraform-registry/terraform-registry/internal/safego"
 )
 
@@ -29,19 +32,39 @@ var (
 	_ Job = (*AuditCleanupJob)(nil)
 	_ Job = (*WebhookRetryJob)(nil)
 	_ Job = (*CVEPollJob)(nil)
+	_ Job = (*StorageConsistencyJob)(nil)
+	_ Job = (*CodeIndexJob)(nil)
+	_ Job = (*ScratchCleanupJob)(nil)
+	_ Job = (*APIKeyClaimExpiryJob)(nil)
+	_ Job = (*downloadstats.Recorder)(nil)
+	_ Job = (*events.Bus)(nil)
 )
 
-// Registry manages the lifecycle of background jobs.
+// defaultShutdownGrace bounds how long StopAll waits for in-flight scheduled
+// runs to finish before cancelling their contexts.
+const defaultShutdownGrace = 30 * time.Second
+
+// Registry manages the lifecycle of background jobs. Jobs that run on the
+// shared Scheduler (everything embedding scheduled) are handed the registry's
+// scheduler on Register, so their run status is visible through Status and
+// their in-flight runs are drained by StopAll.
 type Registry struct {
-	jobs []Job
-	mu   sync.Mutex
+	jobs          []Job
+	mu            sync.Mutex
+	sched         *Scheduler
+	shutdownGrace time.Duration
 }
 
-func NewRegistry() *Registry { return &Registry{} }
+func NewRegistry() *Registry {
+	return &Registry{sched: NewScheduler(), shutdownGrace: defaultShutdownGrace}
+}
 
 func (r *Registry) Register(j Job) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if u, ok := j.(schedulerUser); ok {
+		u.useScheduler(r.sched)
+	}
 	r.jobs = append(r.jobs, j)
 }
 
@@ -56,10 +79,32 @@ func (r *Registry) StartAll(ctx context.Context) {
 	}
 }
 
+// StopAll stops every job in reverse registration order (a job registered
+// after its dependencies is stopped before them), then stops the shared
+// scheduler, waiting up to the shutdown grace period for in-flight runs to
+// finish before cancelling them.
 func (r *Registry) StopAll() {
-	for _, j := range r.jobs {
+	r.mu.Lock()
+	jobs := append([]Job(nil), r.jobs...)
+	r.mu.Unlock()
+
+	for i := len(jobs) - 1; i >= 0; i-- {
+		j := jobs[i]
 		if err := j.Stop(); err != nil {
 			slog.Error("job failed to stop cleanly", "job", j.Name(), "error", err)
 		}
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.shutdownGrace)
+	defer cancel()
+	if err := r.sched.Stop(ctx); err != nil {
+		slog.Warn("background job runs still in flight after shutdown grace period; cancelled",
+			"grace", r.shutdownGrace, "error", err)
+	}
+}
+
+// Status reports the scheduling state of every job running on the shared
+// scheduler, sorted by name.
+func (r *Registry) Status() []TaskStatus {
+	return r.sched.Status()
 }
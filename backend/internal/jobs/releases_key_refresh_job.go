@@ -62,6 +62,7 @@ type ReleasesKeyRefreshJob struct {
 	cache   map[string]string // tool -> armored key
 
 	stopChan chan struct{}
+	scheduled
 }
 
 type toolEndpoint struct {
@@ -153,23 +154,11 @@ func (j *ReleasesKeyRefreshJob) Start(ctx context.Context) error {
 	// unreachable on this cycle.
 	j.primeCacheFromDB(ctx)
 
-	// Run once immediately so the first sync after startup sees fresh keys
-	// (or at least the most recent attempt's outcome reflected in metrics).
-	j.runCycle(ctx)
-
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			j.runCycle(ctx)
-		case <-j.stopChan:
-			return nil
-		case <-ctx.Done():
-			return nil
-		}
-	}
+	// The first cycle runs immediately so the first sync after startup sees
+	// fresh keys (or at least the most recent attempt's outcome reflected in
+	// metrics).
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: interval}, j.stopChan, j.runCycle)
+	return nil
 }
 
 // Stop signals the job to exit gracefully.
@@ -51,6 +51,7 @@ type ScannerUpdateJob struct {
 	manualCh chan struct{}
 	mu       sync.Mutex
 	started  bool
+	scheduled
 	// egressGuard is threaded into every installer.InstallConfig this job builds
 	// so the installer's default HTTP client (GitHub API + browser_download_url
 	// asset fetches) is routed through the shared httpsafe resolve-and-pin guard
@@ -139,33 +140,20 @@ func (j *ScannerUpdateJob) Start(ctx context.Context) error {
 
 	log.Printf("[scanner-update] started (interval: %v)", interval)
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	// Run once immediately at startup.
-	j.runCheck(ctx)
-	j.reconcileActivations(ctx)
-
-	for {
-		select {
-		case <-ticker.C:
-			j.runCheck(ctx)
-			j.reconcileActivations(ctx)
-		case <-j.manualCh:
-			log.Println("[scanner-update] manual trigger received")
-			j.runCheck(ctx)
-			j.reconcileActivations(ctx)
-		case <-stopChan:
-			log.Println("[scanner-update] stopped")
-			return nil
-		case <-ctx.Done():
-			log.Println("[scanner-update] context cancelled")
-			j.mu.Lock()
-			j.started = false
-			j.mu.Unlock()
-			return nil
-		}
+	// Runs once immediately at startup, then on the interval or TriggerCheck.
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: interval, Trigger: j.manualCh}, stopChan, func(runCtx context.Context) {
+		j.runCheck(runCtx)
+		j.reconcileActivations(runCtx)
+	})
+	if ctx.Err() != nil {
+		log.Println("[scanner-update] context cancelled")
+		j.mu.Lock()
+		j.started = false
+		j.mu.Unlock()
+		return nil
 	}
+	log.Println("[scanner-update] stopped")
+	return nil
 }
 
 // TriggerCheck sends a non-blocking signal to run a check immediately.
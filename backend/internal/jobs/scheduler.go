@@ -0,0 +1,349 @@
+// scheduler.go implements the shared run loop behind every interval-driven
+// background job: interval + jitter scheduling, per-task overlap prevention,
+// panic recovery, run bookkeeping for the admin jobs endpoint, and a single
+// graceful Stop.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/terraform-registry/terraform-registry/internal/safego"
+)
+
+// Schedule describes when a scheduled task runs.
+type Schedule struct {
+	// Interval is the time between the starts of consecutive runs. A run that
+	// overruns the interval is followed immediately by the next one; runs of
+	// the same task never overlap.
+	Interval time.Duration
+	// Jitter adds a random delay in [0, Jitter) before every run after the
+	// first, spreading load when several replicas start at the same time.
+	Jitter time.Duration
+	// SkipInitialRun waits one interval before the first run instead of
+	// running immediately when the loop starts.
+	SkipInitialRun bool
+	// Trigger, when non-nil, runs the task immediately each time it receives
+	// a value (manual "run now" requests). Triggers received while a run is in
+	// flight are handled once that run finishes.
+	Trigger <-chan struct{}
+}
+
+// TaskStatus is a point-in-time snapshot of a scheduled task, served by
+// GET /api/v1/admin/jobs.
+type TaskStatus struct {
+	Name           string     `json:"name"`
+	Interval       string     `json:"interval"`
+	Active         bool       `json:"active"`
+	Running        bool       `json:"running"`
+	RunCount       int64      `json:"run_count"`
+	PanicCount     int64      `json:"panic_count"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastDurationMs *int64     `json:"last_duration_ms,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+}
+
+// task holds the bookkeeping for one named task. All fields are guarded by
+// Scheduler.mu.
+type task struct {
+	status TaskStatus
+	// loops counts Run calls currently driving this task. It is normally 0 or
+	// 1, but a job restarted while its old loop is finishing a run briefly has
+	// two; the running flag still keeps their runs from overlapping.
+	loops int
+}
+
+// Scheduler runs named tasks on a Schedule. Each task runs at most once at a
+// time, a panicking run is recovered and recorded instead of killing the
+// process, and Stop ends every loop and waits for in-flight runs.
+type Scheduler struct {
+	mu      sync.Mutex
+	tasks   map[string]*task
+	stopped bool
+	done    chan struct{}
+
+	// runCtx is cancelled by Stop, which in turn cancels the context of every
+	// Run loop so work still in flight after the grace period is aborted.
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+	inflight   sync.WaitGroup
+
+	now    func() time.Time
+	jitter func(limit time.Duration) time.Duration
+}
+
+// NewScheduler constructs an idle Scheduler.
+func NewScheduler() *Scheduler {
+	runCtx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		tasks:      make(map[string]*task),
+		done:       make(chan struct{}),
+		runCtx:     runCtx,
+		cancelRuns: cancel,
+		now:        time.Now,
+		jitter: func(limit time.Duration) time.Duration {
+			if limit <= 0 {
+				return 0
+			}
+			return rand.N(limit)
+		},
+	}
+}
+
+// Run drives fn on sched until ctx is cancelled, stop is closed (it may be
+// nil), or the scheduler is stopped. It blocks, so callers run it from the
+// job's Start. Runs of the same name never overlap, even across Run calls or
+// with TryRun: a wake-up that finds the task already running is skipped.
+func (s *Scheduler) Run(ctx context.Context, name string, sched Schedule, stop <-chan struct{}, fn func(context.Context)) {
+	if sched.Interval <= 0 {
+		slog.Error("scheduler: refusing to run task without a positive interval", "job", name)
+		return
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	t := s.taskLocked(name)
+	t.loops++
+	t.status.Active = true
+	t.status.Interval = sched.Interval.String()
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		t.loops--
+		if t.loops == 0 {
+			t.status.Active = false
+			t.status.NextRunAt = nil
+		}
+		s.mu.Unlock()
+	}()
+
+	// Runs get a context that Stop cancels once its grace period ends. It is
+	// deliberately not cancelled when a single run returns: jobs may hand it to
+	// per-resource goroutines that outlive the run that spawned them.
+	loopCtx, cancel := context.WithCancel(ctx)
+	context.AfterFunc(s.runCtx, cancel)
+
+	next := s.now()
+	if sched.SkipInitialRun {
+		next = next.Add(sched.Interval + s.jitter(sched.Jitter))
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		s.setNextRun(t, next)
+		timer.Reset(max(next.Sub(s.now()), 0))
+
+		select {
+		case <-timer.C:
+		case <-sched.Trigger:
+			slog.Info("scheduler: manual trigger received", "job", name)
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-s.done:
+			return
+		}
+
+		started, ran := s.runOnce(loopCtx, name, fn)
+		if !ran && s.isStopped() {
+			return
+		}
+		next = started.Add(sched.Interval + s.jitter(sched.Jitter))
+	}
+}
+
+// TryRun executes fn once as name, outside any loop, unless a run of that task
+// is already in flight. It blocks until the run finishes and reports whether
+// it ran. Manual "run now" paths use it so they cannot overlap a scheduled run.
+func (s *Scheduler) TryRun(ctx context.Context, name string, fn func(context.Context)) bool {
+	_, ran := s.runOnce(ctx, name, fn)
+	return ran
+}
+
+// runOnce claims the task's running flag, executes fn with panic recovery, and
+// records the outcome. It returns the time the attempt started and false,
+// without running fn, when the task is already running or the scheduler has
+// stopped.
+func (s *Scheduler) runOnce(ctx context.Context, name string, fn func(context.Context)) (time.Time, bool) {
+	s.mu.Lock()
+	started := s.now()
+	if s.stopped {
+		s.mu.Unlock()
+		return started, false
+	}
+	t := s.taskLocked(name)
+	if t.status.Running {
+		s.mu.Unlock()
+		slog.Info("scheduler: previous run still in progress, skipping", "job", name)
+		return started, false
+	}
+	t.status.Running = true
+	t.status.LastStartedAt = &started
+	s.inflight.Add(1)
+	s.mu.Unlock()
+
+	var runErr string
+	panicked := false
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = true
+				runErr = fmt.Sprintf("panic: %v", r)
+				slog.Error("scheduler: job run panicked", "job", name, "panic", r, "stack", string(debug.Stack()))
+			}
+		}()
+		fn(ctx)
+	}()
+
+	finished := s.now()
+	duration := finished.Sub(started).Milliseconds()
+	s.mu.Lock()
+	t.status.Running = false
+	t.status.RunCount++
+	if panicked {
+		t.status.PanicCount++
+	}
+	t.status.LastError = runErr
+	t.status.LastFinishedAt = &finished
+	t.status.LastDurationMs = &duration
+	s.mu.Unlock()
+	s.inflight.Done()
+	return started, true
+}
+
+// Status returns a snapshot of every task the scheduler has seen, sorted by
+// name.
+func (s *Scheduler) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]TaskStatus, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		out = append(out, t.status)
+	}
+	sort.Slice(out, func(i, k int) bool { return out[i].Name < out[k].Name })
+	return out
+}
+
+// Stop ends every task loop and prevents new runs, then waits for in-flight
+// runs to finish. If ctx expires first the remaining runs have their contexts
+// cancelled and Stop returns ctx.Err() without waiting further. It is safe to
+// call more than once.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.done)
+	}
+	s.mu.Unlock()
+
+	finished := make(chan struct{})
+	safego.Go(func() {
+		s.inflight.Wait()
+		close(finished)
+	})
+
+	select {
+	case <-finished:
+		s.cancelRuns()
+		return nil
+	case <-ctx.Done():
+		s.cancelRuns()
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+func (s *Scheduler) taskLocked(name string) *task {
+	t, ok := s.tasks[name]
+	if !ok {
+		t = &task{status: TaskStatus{Name: name}}
+		s.tasks[name] = t
+	}
+	return t
+}
+
+func (s *Scheduler) setNextRun(t *task, next time.Time) {
+	s.mu.Lock()
+	t.status.NextRunAt = &next
+	s.mu.Unlock()
+}
+
+// scheduled is embedded by jobs whose loop runs on a Scheduler. Registry.Register
+// points it at the registry's shared scheduler; a job used on its own (tests,
+// ad-hoc wiring) gets a private one.
+type scheduled struct {
+	schedMu sync.Mutex
+	sched   *Scheduler
+}
+
+func (s *scheduled) useScheduler(sc *Scheduler) {
+	s.schedMu.Lock()
+	s.sched = sc
+	s.schedMu.Unlock()
+}
+
+func (s *scheduled) scheduler() *Scheduler {
+	s.schedMu.Lock()
+	defer s.schedMu.Unlock()
+	if s.sched == nil {
+		s.sched = NewScheduler()
+	}
+	return s.sched
+}
+
+// schedulerUser is implemented by jobs embedding scheduled.
+type schedulerUser interface {
+	useScheduler(*Scheduler)
+}
+
+// activeRuns is a keyed overlap guard for jobs that fan out one run per
+// resource (a mirror, a binary mirror config) on their own goroutines, so a
+// scheduled and a manual sync of the same resource never run together.
+type activeRuns struct {
+	mu   sync.Mutex
+	keys map[uuid.UUID]struct{}
+}
+
+func newActiveRuns() *activeRuns {
+	return &activeRuns{keys: make(map[uuid.UUID]struct{})}
+}
+
+// tryAcquire marks key active and reports true, or reports false when a run
+// for key is already active.
+func (a *activeRuns) tryAcquire(key uuid.UUID) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.keys[key]; ok {
+		return false
+	}
+	a.keys[key] = struct{}{}
+	return true
+}
+
+// release clears key so a later run can acquire it.
+func (a *activeRuns) release(key uuid.UUID) {
+	a.mu.Lock()
+	delete(a.keys, key)
+	a.mu.Unlock()
+}
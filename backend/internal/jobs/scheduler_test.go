@@ -0,0 +1,443 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func taskStatus(s *Scheduler, name string) (TaskStatus, bool) {
+	for _, st := range s.Status() {
+		if st.Name == name {
+			return st, true
+		}
+	}
+	return TaskStatus{}, false
+}
+
+// runAsync starts s.Run on its own goroutine and returns a channel closed when
+// it returns.
+func runAsync(ctx context.Context, s *Scheduler, name string, sched Schedule, stop <-chan struct{}, fn func(context.Context)) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, name, sched, stop, fn)
+		close(done)
+	}()
+	return done
+}
+
+// ---------------------------------------------------------------------------
+// Run: scheduling
+// ---------------------------------------------------------------------------
+
+func TestScheduler_RunsImmediatelyThenOnInterval(t *testing.T) {
+	s := NewScheduler()
+	var runs atomic.Int32
+	stop := make(chan struct{})
+	done := runAsync(context.Background(), s, "tick", Schedule{Interval: 5 * time.Millisecond}, stop, func(context.Context) {
+		runs.Add(1)
+	})
+
+	waitFor(t, "three runs", func() bool { return runs.Load() >= 3 })
+	close(stop)
+	<-done
+
+	st, _ := taskStatus(s, "tick")
+	if st.Active || st.NextRunAt != nil {
+		t.Errorf("stopped loop still reported active: %+v", st)
+	}
+	if st.RunCount < 3 || st.LastStartedAt == nil || st.LastFinishedAt == nil || st.LastDurationMs == nil {
+		t.Errorf("bookkeeping incomplete: %+v", st)
+	}
+}
+
+func TestScheduler_SkipInitialRun(t *testing.T) {
+	s := NewScheduler()
+	var runs atomic.Int32
+	stop := make(chan struct{})
+	done := runAsync(context.Background(), s, "delayed", Schedule{Interval: time.Hour, SkipInitialRun: true}, stop, func(context.Context) {
+		runs.Add(1)
+	})
+
+	waitFor(t, "loop active", func() bool {
+		st, ok := taskStatus(s, "delayed")
+		return ok && st.Active && st.NextRunAt != nil
+	})
+	st, _ := taskStatus(s, "delayed")
+	if until := time.Until(*st.NextRunAt); until < 59*time.Minute {
+		t.Errorf("next run in %v, want about one interval", until)
+	}
+	close(stop)
+	<-done
+	if runs.Load() != 0 {
+		t.Errorf("runs = %d, want 0", runs.Load())
+	}
+}
+
+func TestScheduler_JitterAddedToNextRun(t *testing.T) {
+	s := NewScheduler()
+	s.jitter = func(limit time.Duration) time.Duration {
+		if limit != 10*time.Minute {
+			t.Errorf("jitter limit = %v", limit)
+		}
+		return 7 * time.Minute
+	}
+	stop := make(chan struct{})
+	done := runAsync(context.Background(), s, "jittered", Schedule{Interval: time.Hour, Jitter: 10 * time.Minute}, stop, func(context.Context) {})
+
+	waitFor(t, "first run", func() bool {
+		st, _ := taskStatus(s, "jittered")
+		return st.RunCount == 1 && st.NextRunAt != nil
+	})
+	st, _ := taskStatus(s, "jittered")
+	if gap := st.NextRunAt.Sub(*st.LastStartedAt); gap != time.Hour+7*time.Minute {
+		t.Errorf("next run %v after last start, want 1h7m", gap)
+	}
+	close(stop)
+	<-done
+}
+
+func TestScheduler_TriggerRunsImmediately(t *testing.T) {
+	s := NewScheduler()
+	trigger := make(chan struct{}, 1)
+	var runs atomic.Int32
+	stop := make(chan struct{})
+	done := runAsync(context.Background(), s, "manual", Schedule{Interval: time.Hour, Trigger: trigger}, stop, func(context.Context) {
+		runs.Add(1)
+	})
+
+	waitFor(t, "initial run", func() bool { return runs.Load() == 1 })
+	trigger <- struct{}{}
+	waitFor(t, "triggered run", func() bool { return runs.Load() == 2 })
+	close(stop)
+	<-done
+}
+
+func TestScheduler_RejectsNonPositiveInterval(t *testing.T) {
+	s := NewScheduler()
+	called := false
+	s.Run(context.Background(), "bad", Schedule{}, nil, func(context.Context) { called = true })
+	if called {
+		t.Error("task ran without an interval")
+	}
+}
+
+func TestScheduler_ContextCancelEndsLoop(t *testing.T) {
+	s := NewScheduler()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, s, "ctx", Schedule{Interval: time.Hour}, nil, func(context.Context) {})
+	waitFor(t, "first run", func() bool { st, _ := taskStatus(s, "ctx"); return st.RunCount == 1 })
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after ctx cancellation")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Overlap prevention
+// ---------------------------------------------------------------------------
+
+func TestScheduler_TryRunSkipsWhileScheduledRunInFlight(t *testing.T) {
+	s := NewScheduler()
+	release := make(chan struct{})
+	var inFlight, maxInFlight atomic.Int32
+	fn := func(context.Context) {
+		n := inFlight.Add(1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		<-release
+		inFlight.Add(-1)
+	}
+
+	stop := make(chan struct{})
+	done := runAsync(context.Background(), s, "slow", Schedule{Interval: time.Hour}, stop, fn)
+	waitFor(t, "scheduled run in flight", func() bool { st, _ := taskStatus(s, "slow"); return st.Running })
+
+	if s.TryRun(context.Background(), "slow", fn) {
+		t.Error("TryRun ran while a scheduled run was in flight")
+	}
+	close(release)
+	waitFor(t, "run finished", func() bool { st, _ := taskStatus(s, "slow"); return !st.Running })
+
+	if !s.TryRun(context.Background(), "slow", fn) {
+		t.Error("TryRun skipped although nothing was running")
+	}
+	close(stop)
+	<-done
+	if maxInFlight.Load() != 1 {
+		t.Errorf("max concurrent runs = %d, want 1", maxInFlight.Load())
+	}
+}
+
+func TestScheduler_DuplicateLoopsNeverOverlap(t *testing.T) {
+	s := NewScheduler()
+	var inFlight, overlaps atomic.Int32
+	fn := func(context.Context) {
+		if inFlight.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		time.Sleep(3 * time.Millisecond)
+		inFlight.Add(-1)
+	}
+	stop := make(chan struct{})
+	a := runAsync(context.Background(), s, "dup", Schedule{Interval: time.Millisecond}, stop, fn)
+	b := runAsync(context.Background(), s, "dup", Schedule{Interval: time.Millisecond}, stop, fn)
+
+	waitFor(t, "several runs", func() bool { st, _ := taskStatus(s, "dup"); return st.RunCount >= 10 })
+	close(stop)
+	<-a
+	<-b
+	if overlaps.Load() != 0 {
+		t.Errorf("%d overlapping runs", overlaps.Load())
+	}
+	if st, _ := taskStatus(s, "dup"); st.Active {
+		t.Error("task still active after both loops returned")
+	}
+}
+
+func TestActiveRuns(t *testing.T) {
+	a := newActiveRuns()
+	id := uuid.New()
+	if !a.tryAcquire(id) {
+		t.Fatal("first acquire failed")
+	}
+	if a.tryAcquire(id) {
+		t.Error("second acquire of an active key succeeded")
+	}
+	if !a.tryAcquire(uuid.New()) {
+		t.Error("acquire of a different key failed")
+	}
+	a.release(id)
+	if !a.tryAcquire(id) {
+		t.Error("acquire after release failed")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Panic recovery
+// ---------------------------------------------------------------------------
+
+func TestScheduler_PanicIsRecoveredAndLoopContinues(t *testing.T) {
+	s := NewScheduler()
+	var runs atomic.Int32
+	stop := make(chan struct{})
+	done := runAsync(context.Background(), s, "flaky", Schedule{Interval: time.Millisecond}, stop, func(context.Context) {
+		if runs.Add(1) == 1 {
+			panic("boom")
+		}
+	})
+
+	waitFor(t, "runs after the panic", func() bool { return runs.Load() >= 3 })
+	close(stop)
+	<-done
+
+	st, _ := taskStatus(s, "flaky")
+	if st.PanicCount != 1 {
+		t.Errorf("PanicCount = %d, want 1", st.PanicCount)
+	}
+	if st.LastError != "" {
+		t.Errorf("LastError = %q; a later successful run should clear it", st.LastError)
+	}
+	if st.Running {
+		t.Error("task left marked running after a panic")
+	}
+}
+
+func TestScheduler_TryRunRecordsPanic(t *testing.T) {
+	s := NewScheduler()
+	if !s.TryRun(context.Background(), "once", func(context.Context) { panic("kaboom") }) {
+		t.Fatal("TryRun did not run")
+	}
+	st, _ := taskStatus(s, "once")
+	if st.PanicCount != 1 || st.LastError != "panic: kaboom" || st.RunCount != 1 {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Shutdown
+// ---------------------------------------------------------------------------
+
+func TestScheduler_StopWaitsForInFlightRun(t *testing.T) {
+	s := NewScheduler()
+	release := make(chan struct{})
+	var finished atomic.Bool
+	done := runAsync(context.Background(), s, "drain", Schedule{Interval: time.Hour}, nil, func(context.Context) {
+		<-release
+		finished.Store(true)
+	})
+	waitFor(t, "run in flight", func() bool { st, _ := taskStatus(s, "drain"); return st.Running })
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Stop(context.Background()) }()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while a run was still in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Stop returned before the in-flight run finished")
+	}
+	<-done
+}
+
+func TestScheduler_StopCancelsRunsAfterGrace(t *testing.T) {
+	s := NewScheduler()
+	cancelled := make(chan struct{})
+	runAsync(context.Background(), s, "stuck", Schedule{Interval: time.Hour}, nil, func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+	waitFor(t, "run in flight", func() bool { st, _ := taskStatus(s, "stuck"); return st.Running })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop err = %v, want deadline exceeded", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("run context was not cancelled after the grace period")
+	}
+}
+
+func TestScheduler_NoRunsAfterStop(t *testing.T) {
+	s := NewScheduler()
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("second Stop: %v", err)
+	}
+	ran := false
+	s.Run(context.Background(), "late", Schedule{Interval: time.Millisecond}, nil, func(context.Context) { ran = true })
+	if s.TryRun(context.Background(), "late", func(context.Context) { ran = true }) || ran {
+		t.Error("task ran after Stop")
+	}
+}
+
+// orderedJob is a scheduled Job that records when it was stopped and when its
+// in-flight run completed.
+type orderedJob struct {
+	scheduled
+	name    string
+	log     *eventLog
+	stopCh  chan struct{}
+	release chan struct{}
+}
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(e string) {
+	l.mu.Lock()
+	l.events = append(l.events, e)
+	l.mu.Unlock()
+}
+
+func (l *eventLog) snapshot() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func (j *orderedJob) Name() string { return j.name }
+func (j *orderedJob) Start(ctx context.Context) error {
+	j.scheduler().Run(ctx, j.name, Schedule{Interval: time.Hour}, j.stopCh, func(context.Context) {
+		<-j.release
+		j.log.add(j.name + " run done")
+	})
+	return nil
+}
+func (j *orderedJob) Stop() error {
+	j.log.add(j.name + " stop")
+	close(j.stopCh)
+	return nil
+}
+
+func TestRegistry_StopAll_ReverseOrderThenDrainsRuns(t *testing.T) {
+	events := &eventLog{}
+	first := &orderedJob{name: "first", log: events, stopCh: make(chan struct{}), release: make(chan struct{})}
+	second := &orderedJob{name: "second", log: events, stopCh: make(chan struct{}), release: make(chan struct{})}
+
+	r := NewRegistry()
+	r.Register(first)
+	r.Register(second)
+	r.StartAll(context.Background())
+	waitFor(t, "both runs in flight", func() bool {
+		running := 0
+		for _, st := range r.Status() {
+			if st.Running {
+				running++
+			}
+		}
+		return running == 2
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		r.StopAll()
+		close(stopped)
+	}()
+	waitFor(t, "both jobs stopped", func() bool { return len(events.snapshot()) == 2 })
+	select {
+	case <-stopped:
+		t.Fatal("StopAll returned before in-flight runs drained")
+	default:
+	}
+	close(first.release)
+	close(second.release)
+	<-stopped
+
+	got := events.snapshot()
+	want := []string{"second stop", "first stop"}
+	if len(got) != 4 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("events = %v, want stops %v before run completions", got, want)
+	}
+}
+
+func TestRegistry_RegisterSharesScheduler(t *testing.T) {
+	r := NewRegistry()
+	j := &orderedJob{name: "shared"}
+	r.Register(j)
+	if j.scheduler() != r.sched {
+		t.Error("registered job does not use the registry scheduler")
+	}
+
+	standalone := &orderedJob{name: "standalone"}
+	if standalone.scheduler() == nil || standalone.scheduler() == r.sched {
+		t.Error("unregistered job should get its own scheduler")
+	}
+}
@@ -0,0 +1,214 @@
+// scm_publish_queue.go implements the background job that drains the SCM
+// publish queue: tag publishes enqueued by webhook deliveries, which are
+// acknowledged before the (possibly slow) download and packaging starts.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+const (
+	// publishQueuePollInterval is how often the queue is checked for due tasks.
+	publishQueuePollInterval = 5 * time.Second
+	// publishQueueBatch bounds the tasks processed per poll so Stop is
+	// honored between batches.
+	publishQueueBatch = 10
+	// publishTaskTimeout bounds one publish attempt.
+	publishTaskTimeout = 10 * time.Minute
+	// publishTaskLease is how long a claimed task stays running before
+	// another replica may assume its worker died and claim it again. It must
+	// exceed publishTaskTimeout.
+	publishTaskLease = 15 * time.Minute
+)
+
+// SCMPublishQueueJob processes queued SCM tag publishes. Failed attempts are
+// retried with the same exponential backoff as webhook retries until the
+// task's max_attempts, after which the task is failed. The webhook event
+// that queued a task is kept in step with it, so GET .../scm/events shows
+// the outcome.
+type SCMPublishQueueJob struct {
+	scmRepo     *repositories.SCMRepository
+	publisher   *services.SCMPublisher
+	tokenCipher *crypto.TokenCipher
+	stopChan    chan struct{}
+	scheduled
+}
+
+// NewSCMPublishQueueJob constructs an SCMPublishQueueJob.
+func NewSCMPublishQueueJob(scmRepo *repositories.SCMRepository, publisher *services.SCMPublisher, tokenCipher *crypto.TokenCipher) *SCMPublishQueueJob {
+	return &SCMPublishQueueJob{
+		scmRepo:     scmRepo,
+		publisher:   publisher,
+		tokenCipher: tokenCipher,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Name identifies the job in the jobs.Registry.
+func (j *SCMPublishQueueJob) Name() string { return "scm-publish-queue" }
+
+// Start polls the queue until ctx is cancelled or Stop is called. It blocks
+// (the Registry runs it in its own goroutine).
+func (j *SCMPublishQueueJob) Start(ctx context.Context) error {
+	slog.Info("scm publish queue: started", "interval", publishQueuePollInterval)
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: publishQueuePollInterval}, j.stopChan, j.drain)
+	return nil
+}
+
+// Stop signals the job to exit gracefully.
+func (j *SCMPublishQueueJob) Stop() error {
+	select {
+	case <-j.stopChan:
+		// already stopped
+	default:
+		close(j.stopChan)
+	}
+	return nil
+}
+
+// drain processes due tasks until none are left or the batch is done.
+func (j *SCMPublishQueueJob) drain(ctx context.Context) {
+	for i := 0; i < publishQueueBatch; i++ {
+		task, err := j.scmRepo.ClaimPublishTask(ctx, publishTaskLease)
+		if err != nil {
+			slog.Error("scm publish queue: failed to claim task", "error", err)
+			return
+		}
+		if task == nil {
+			return
+		}
+		j.process(ctx, task)
+	}
+}
+
+// process runs one attempt of a claimed task and records its outcome.
+func (j *SCMPublishQueueJob) process(ctx context.Context, task *scm.PublishTask) {
+	taskCtx, cancel := context.WithTimeout(ctx, publishTaskTimeout)
+	defer cancel()
+
+	if task.WebhookEventID != nil {
+		if err := j.scmRepo.UpdateWebhookLogState(taskCtx, *task.WebhookEventID, "processing", nil, nil); err != nil {
+			slog.Warn("scm publish queue: failed to mark event as processing",
+				"task_id", task.ID, "event_id", *task.WebhookEventID, "error", err)
+		}
+	}
+
+	result, err := j.publish(taskCtx, task)
+	switch {
+	case errors.Is(err, services.ErrSourceArchiveTooLarge):
+		// The archive will be just as large next time; fail without retrying.
+		msg := err.Error()
+		j.finish(ctx, task, scm.PublishTaskFailed, &msg, nil)
+	case errors.Is(err, services.ErrPublishHookDenied):
+		// The organization's pre-publish hook rejected this version.
+		msg := err.Error()
+		j.finish(ctx, task, scm.PublishTaskFailed, &msg, nil)
+	case err != nil:
+		j.fail(ctx, task, err.Error())
+	case result.Skipped != "":
+		j.finish(ctx, task, scm.PublishTaskSkipped, &result.Skipped, nil)
+	default:
+		slog.Info("scm publish queue: published",
+			"task_id", task.ID, "tag", task.TagName, "version", result.Version)
+		j.finish(ctx, task, scm.PublishTaskSucceeded, nil, &result.Metadata.ModuleVersionID)
+	}
+}
+
+// publish loads the task's link and provider and publishes its tag.
+func (j *SCMPublishQueueJob) publish(ctx context.Context, task *scm.PublishTask) (*services.TagPublish, error) {
+	link, err := j.scmRepo.GetModuleSourceRepoByID(ctx, task.ModuleSCMRepoID)
+	if err != nil || link == nil {
+		return nil, fmt.Errorf("failed to load module SCM repo: %v", err)
+	}
+	provider, err := j.scmRepo.GetProvider(ctx, link.SCMProviderID)
+	if err != nil || provider == nil {
+		return nil, fmt.Errorf("failed to load SCM provider: %v", err)
+	}
+	connector, err := buildProviderConnector(j.tokenCipher, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	hook := &scm.IncomingHook{Type: scm.WebhookEventTag, TagName: task.TagName}
+	if task.CommitSHA != nil {
+		hook.CommitSHA = *task.CommitSHA
+	}
+	return j.publisher.PublishTag(ctx, link, hook, connector, nil)
+}
+
+// fail requeues a task after a failed attempt, or fails it for good once its
+// attempts are used up.
+func (j *SCMPublishQueueJob) fail(ctx context.Context, task *scm.PublishTask, errMsg string) {
+	if task.Attempts >= task.MaxAttempts {
+		slog.Warn("scm publish queue: attempts exhausted",
+			"task_id", task.ID, "tag", task.TagName, "attempts", task.Attempts, "error", errMsg)
+		j.finish(ctx, task, scm.PublishTaskFailed, &errMsg, nil)
+		return
+	}
+	next := time.Now().Add(calculateBackoff(task.Attempts))
+	if err := j.scmRepo.RequeuePublishTask(ctx, task.ID, errMsg, next); err != nil {
+		slog.Error("scm publish queue: failed to requeue task", "task_id", task.ID, "error", err)
+		return
+	}
+	slog.Info("scm publish queue: attempt failed, retrying",
+		"task_id", task.ID, "tag", task.TagName, "attempts", task.Attempts, "next_attempt_at", next, "error", errMsg)
+}
+
+// finish records a terminal status on the task and the webhook event that
+// queued it.
+func (j *SCMPublishQueueJob) finish(ctx context.Context, task *scm.PublishTask, status scm.PublishTaskStatus, message *string, versionID *uuid.UUID) {
+	if err := j.scmRepo.FinishPublishTask(ctx, task.ID, status, message, versionID); err != nil {
+		slog.Error("scm publish queue: failed to record task outcome",
+			"task_id", task.ID, "status", status, "error", err)
+	}
+	if task.WebhookEventID == nil {
+		return
+	}
+	eventState := map[scm.PublishTaskStatus]string{
+		scm.PublishTaskSucceeded: "completed",
+		scm.PublishTaskSkipped:   "skipped",
+		scm.PublishTaskFailed:    "failed",
+	}[status]
+	if err := j.scmRepo.UpdateWebhookLogState(ctx, *task.WebhookEventID, eventState, message, versionID); err != nil {
+		slog.Warn("scm publish queue: failed to record event outcome",
+			"task_id", task.ID, "event_id", *task.WebhookEventID, "error", err)
+	}
+}
+
+// buildProviderConnector builds the connector for an SCM provider from its
+// stored settings.
+func buildProviderConnector(tokenCipher *crypto.TokenCipher, provider *scm.SCMProvider) (scm.Connector, error) {
+	clientSecret, err := tokenCipher.Open(provider.ClientSecretEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt client secret: %v", err)
+	}
+	baseURL := ""
+	if provider.BaseURL != nil {
+		baseURL = *provider.BaseURL
+	}
+	tenantID := ""
+	if provider.TenantID != nil {
+		tenantID = *provider.TenantID
+	}
+	connector, err := scm.BuildConnector(&scm.ConnectorSettings{
+		Kind:            provider.ProviderType,
+		InstanceBaseURL: baseURL,
+		ClientID:        provider.ClientID,
+		ClientSecret:    clientSecret,
+		TenantID:        tenantID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build connector: %v", err)
+	}
+	return connector, nil
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+)
+
+func newPublishQueueJob(t *testing.T) (*SCMPublishQueueJob, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewSCMPublishQueueJob(repositories.NewSCMRepository(sqlx.NewDb(db, "sqlmock")), nil, nil), mock
+}
+
+func TestSCMPublishQueue_FailedAttemptIsRequeued(t *testing.T) {
+	job, mock := newPublishQueueJob(t)
+	eventID := uuid.New()
+	task := &scm.PublishTask{ID: uuid.New(), ModuleSCMRepoID: uuid.New(), WebhookEventID: &eventID, TagName: "v1.2.0", Attempts: 1, MaxAttempts: 4}
+
+	mock.ExpectExec("UPDATE scm_webhook_events SET.*processing_started_at").
+		WithArgs(eventID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT.*FROM module_scm_repos WHERE id").
+		WillReturnError(errors.New("db down"))
+	mock.ExpectExec("UPDATE scm_publish_tasks SET.*status = 'queued'").
+		WithArgs(task.ID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	job.process(context.Background(), task)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSCMPublishQueue_LastAttemptFailsTask(t *testing.T) {
+	job, mock := newPublishQueueJob(t)
+	eventID := uuid.New()
+	task := &scm.PublishTask{ID: uuid.New(), WebhookEventID: &eventID, TagName: "v1.2.0", Attempts: 4, MaxAttempts: 4}
+	msg := "failed to publish version: download failed"
+
+	mock.ExpectExec("UPDATE scm_publish_tasks SET.*completed_at").
+		WithArgs(task.ID, scm.PublishTaskFailed, &msg, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE scm_webhook_events SET.*processed = true").
+		WithArgs(eventID, sqlmock.AnyArg(), &msg, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	job.fail(context.Background(), task, msg)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSCMPublishQueue_DrainStopsWhenEmpty(t *testing.T) {
+	job, mock := newPublishQueueJob(t)
+	mock.ExpectQuery("UPDATE scm_publish_tasks SET").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	job.drain(context.Background())
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+// scratch_cleanup_job.go implements the scratch cleanup job, which deletes
+// entries left behind in the scratch directory by failed or interrupted work
+// and samples the directory's size for the scratch usage metric.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+)
+
+const (
+	defaultScratchOrphanMaxAge    = time.Hour
+	defaultScratchCleanupInterval = 5 * time.Minute
+)
+
+// ScratchCleanupJob sweeps the scratch directory at startup and then every
+// scratch.cleanup_interval, removing entries older than scratch.orphan_max_age.
+type ScratchCleanupJob struct {
+	cfg      *config.ScratchConfig
+	space    *scratch.Space
+	stopChan chan struct{}
+	scheduled
+}
+
+// NewScratchCleanupJob constructs a ScratchCleanupJob.
+func NewScratchCleanupJob(cfg *config.ScratchConfig, space *scratch.Space) *ScratchCleanupJob {
+	return &ScratchCleanupJob{
+		cfg:      cfg,
+		space:    space,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Name returns the human-readable job name used in logs.
+func (j *ScratchCleanupJob) Name() string { return "scratch-cleanup" }
+
+// Start runs one sweep immediately, catching what a previous process left
+// behind, then one every cleanup interval.
+func (j *ScratchCleanupJob) Start(ctx context.Context) error {
+	interval := j.cfg.CleanupInterval
+	if interval <= 0 {
+		interval = defaultScratchCleanupInterval
+	}
+	slog.Info("scratch cleanup: started", "dir", j.space.Dir(), "interval", interval, "orphan_max_age", j.orphanMaxAge())
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: interval}, j.stopChan, j.runCleanup)
+	return nil
+}
+
+// Stop signals the job to exit gracefully. It is safe to call multiple times.
+func (j *ScratchCleanupJob) Stop() error {
+	select {
+	case <-j.stopChan:
+	default:
+		close(j.stopChan)
+	}
+	return nil
+}
+
+func (j *ScratchCleanupJob) orphanMaxAge() time.Duration {
+	if j.cfg.OrphanMaxAge > 0 {
+		return j.cfg.OrphanMaxAge
+	}
+	return defaultScratchOrphanMaxAge
+}
+
+// runCleanup removes orphaned entries, then records the directory's size.
+func (j *ScratchCleanupJob) runCleanup(_ context.Context) {
+	removed, err := j.space.Sweep(j.orphanMaxAge())
+	if err != nil {
+		slog.Warn("scratch cleanup: failed to remove some entries", "dir", j.space.Dir(), "error", err)
+	}
+	if removed > 0 {
+		slog.Info("scratch cleanup: removed orphaned entries", "dir", j.space.Dir(), "removed", removed)
+	}
+
+	usage, err := j.space.Usage()
+	if err != nil {
+		slog.Warn("scratch cleanup: failed to measure scratch usage", "dir", j.space.Dir(), "error", err)
+		return
+	}
+	telemetry.ScratchUsageBytes.Set(float64(usage))
+}
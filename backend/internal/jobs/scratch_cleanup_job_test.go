@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+)
+
+func TestScratchCleanupJob_RemovesOrphansAndRecordsUsage(t *testing.T) {
+	dir := t.TempDir()
+	space, err := scratch.New(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphan := filepath.Join(dir, "scm-publish-orphan")
+	if err := os.WriteFile(orphan, make([]byte, 64), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(orphan, stale, stale)
+	if err := os.WriteFile(filepath.Join(dir, "module-upload-live.tar.gz"), make([]byte, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	job := NewScratchCleanupJob(&config.ScratchConfig{OrphanMaxAge: time.Hour}, space)
+	job.runCleanup(context.Background())
+
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("orphan still present: %v", err)
+	}
+	if got := testutil.ToFloat64(telemetry.ScratchUsageBytes); got != 32 {
+		t.Errorf("scratch usage gauge = %v, want 32", got)
+	}
+}
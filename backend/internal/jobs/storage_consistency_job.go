@@ -0,0 +1,210 @@
+// storage_consistency_job.go implements the storage consistency checker: the
+// inverse of garbage collection, it looks for artifact rows whose storage
+// object is gone (for example after a misfired bucket lifecycle rule) and
+// records them as findings for GET /api/v1/admin/consistency/report.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// StorageConsistencyStore persists the checker's runs and findings.
+// *repositories.StorageConsistencyRepository satisfies it.
+type StorageConsistencyStore interface {
+	ListArtifacts(ctx context.Context, artifactType string, limit int) ([]models.StorageArtifact, error)
+	CreateRun(ctx context.Context, fullScan bool) (*models.StorageConsistencyRun, error)
+	CompleteRun(ctx context.Context, run *models.StorageConsistencyRun) error
+	ListFindings(ctx context.Context) ([]*models.StorageConsistencyFinding, error)
+	RecordMissing(ctx context.Context, artifact models.StorageArtifact, markBroken bool) error
+	DeleteFinding(ctx context.Context, id string) error
+	PruneFindings(ctx context.Context) (int64, error)
+}
+
+// StorageConsistencyJob periodically checks that the storage objects of module
+// versions, provider platforms and Terraform binary mirror platforms exist.
+// Each run first re-checks the open findings, clearing those whose object is
+// back, then checks a random sample of each artifact type (or all of them
+// with full_scan).
+type StorageConsistencyJob struct {
+	cfg            *config.StorageConsistencyConfig
+	store          StorageConsistencyStore
+	storageBackend storage.Storage
+	stopChan       chan struct{}
+	manualCh       chan struct{}
+	scheduled
+}
+
+// NewStorageConsistencyJob constructs a StorageConsistencyJob.
+func NewStorageConsistencyJob(cfg *config.StorageConsistencyConfig, store StorageConsistencyStore, storageBackend storage.Storage) *StorageConsistencyJob {
+	return &StorageConsistencyJob{
+		cfg:            cfg,
+		store:          store,
+		storageBackend: storageBackend,
+		stopChan:       make(chan struct{}),
+		manualCh:       make(chan struct{}, 1),
+	}
+}
+
+// Name returns the human-readable job name used in logs.
+func (j *StorageConsistencyJob) Name() string { return "storage-consistency" }
+
+// Start runs the checker until ctx is cancelled or Stop is called. It is a
+// no-op when storage_consistency.enabled is false. The first check waits one
+// interval, so a restart does not repeat a full scan.
+func (j *StorageConsistencyJob) Start(ctx context.Context) error {
+	if !j.cfg.Enabled {
+		slog.Info("storage consistency: disabled (storage_consistency.enabled=false)")
+		return nil
+	}
+
+	slog.Info("storage consistency: started", "interval", j.cfg.Interval,
+		"full_scan", j.cfg.FullScan, "sample_size", j.cfg.SampleSize, "mark_broken", j.cfg.MarkBroken)
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: j.cfg.Interval, SkipInitialRun: true, Trigger: j.manualCh}, j.stopChan, j.runCheck)
+	return nil
+}
+
+// TriggerCheck queues a check to run now. It reports false when the checker
+// is disabled; a check already queued absorbs the request.
+func (j *StorageConsistencyJob) TriggerCheck() bool {
+	if !j.cfg.Enabled {
+		return false
+	}
+	select {
+	case j.manualCh <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// Stop signals the job to exit gracefully. It is safe to call multiple times.
+func (j *StorageConsistencyJob) Stop() error {
+	select {
+	case <-j.stopChan:
+		// already stopped
+	default:
+		close(j.stopChan)
+	}
+	return nil
+}
+
+// runCheck performs one recorded check.
+func (j *StorageConsistencyJob) runCheck(ctx context.Context) {
+	run, err := j.store.CreateRun(ctx, j.cfg.FullScan)
+	if err != nil {
+		slog.Error("storage consistency: failed to record run", "error", err)
+		return
+	}
+	if err := j.check(ctx, run); err != nil {
+		msg := err.Error()
+		run.Error = &msg
+		slog.Error("storage consistency: run stopped early", "error", err)
+	}
+
+	// Record the outcome even when the run was cancelled by shutdown.
+	completeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := j.store.CompleteRun(completeCtx, run); err != nil {
+		slog.Error("storage consistency: failed to record run result", "error", err)
+	}
+	slog.Info("storage consistency: run complete",
+		"checked", run.Checked, "missing", run.Missing, "errors", run.Errors)
+}
+
+// check fills in run's counts. It returns an error when the run could not
+// finish; counts up to that point are kept.
+func (j *StorageConsistencyJob) check(ctx context.Context, run *models.StorageConsistencyRun) error {
+	if pruned, err := j.store.PruneFindings(ctx); err != nil {
+		return err
+	} else if pruned > 0 {
+		slog.Info("storage consistency: removed findings of deleted artifacts", "count", pruned)
+	}
+
+	findings, err := j.store.ListFindings(ctx)
+	if err != nil {
+		return err
+	}
+	checked := make(map[string]bool, len(findings))
+	for _, f := range findings {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		checked[f.ArtifactType+"/"+f.ArtifactID] = true
+		artifact := models.StorageArtifact{
+			Type: f.ArtifactType, ID: f.ArtifactID, StorageKey: f.StorageKey,
+			Description: f.Description, MirrorConfigID: f.MirrorConfigID,
+		}
+		exists, ok := j.exists(ctx, run, artifact)
+		if !ok {
+			continue
+		}
+		if exists {
+			slog.Info("storage consistency: object is back", "artifact_type", f.ArtifactType,
+				"artifact", f.Description, "storage_key", f.StorageKey)
+			if err := j.store.DeleteFinding(ctx, f.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := j.recordMissing(ctx, run, artifact); err != nil {
+			return err
+		}
+	}
+
+	limit := j.cfg.SampleSize
+	if j.cfg.FullScan {
+		limit = 0
+	}
+	for _, artifactType := range models.ConsistencyArtifactTypes {
+		artifacts, err := j.store.ListArtifacts(ctx, artifactType, limit)
+		if err != nil {
+			return err
+		}
+		for _, a := range artifacts {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if checked[a.Type+"/"+a.ID] {
+				continue
+			}
+			exists, ok := j.exists(ctx, run, a)
+			if !ok || exists {
+				continue
+			}
+			if err := j.recordMissing(ctx, run, a); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exists checks one artifact's object and counts it. ok is false when the
+// check itself failed.
+func (j *StorageConsistencyJob) exists(ctx context.Context, run *models.StorageConsistencyRun, a models.StorageArtifact) (exists, ok bool) {
+	run.Checked++
+	exists, err := j.storageBackend.Exists(ctx, a.StorageKey)
+	if err != nil {
+		run.Errors++
+		slog.Warn("storage consistency: failed to check object", "artifact_type", a.Type,
+			"artifact", a.Description, "storage_key", a.StorageKey, "error", err)
+		return false, false
+	}
+	return exists, true
+}
+
+func (j *StorageConsistencyJob) recordMissing(ctx context.Context, run *models.StorageConsistencyRun, a models.StorageArtifact) error {
+	run.Missing++
+	slog.Warn("storage consistency: object missing", "artifact_type", a.Type, "artifact", a.Description,
+		"storage_key", a.StorageKey, "severity", a.Severity())
+	if err := j.store.RecordMissing(ctx, a, j.cfg.MarkBroken); err != nil {
+		return fmt.Errorf("failed to record missing object for %s: %w", a.Description, err)
+	}
+	return nil
+}
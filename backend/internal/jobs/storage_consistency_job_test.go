@@ -0,0 +1,168 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// objectStorage is a storage stub whose Exists answers from a set of present
+// keys, failing for keys in failing.
+type objectStorage struct {
+	fakeUploadStorage
+	present map[string]bool
+	failing map[string]bool
+}
+
+func (s *objectStorage) Exists(_ context.Context, path string) (bool, error) {
+	if s.failing[path] {
+		return false, errors.New("storage unavailable")
+	}
+	return s.present[path], nil
+}
+
+// fakeConsistencyStore is an in-memory StorageConsistencyStore.
+type fakeConsistencyStore struct {
+	artifacts map[string][]models.StorageArtifact
+	findings  []*models.StorageConsistencyFinding
+	limits    []int
+	recorded  []models.StorageArtifact
+	markedAs  []bool
+	deleted   []string
+	completed *models.StorageConsistencyRun
+}
+
+func (f *fakeConsistencyStore) ListArtifacts(_ context.Context, artifactType string, limit int) ([]models.StorageArtifact, error) {
+	f.limits = append(f.limits, limit)
+	return f.artifacts[artifactType], nil
+}
+
+func (f *fakeConsistencyStore) CreateRun(_ context.Context, fullScan bool) (*models.StorageConsistencyRun, error) {
+	return &models.StorageConsistencyRun{ID: "run-1", FullScan: fullScan}, nil
+}
+
+func (f *fakeConsistencyStore) CompleteRun(_ context.Context, run *models.StorageConsistencyRun) error {
+	f.completed = run
+	return nil
+}
+
+func (f *fakeConsistencyStore) ListFindings(context.Context) ([]*models.StorageConsistencyFinding, error) {
+	return f.findings, nil
+}
+
+func (f *fakeConsistencyStore) RecordMissing(_ context.Context, a models.StorageArtifact, markBroken bool) error {
+	f.recorded = append(f.recorded, a)
+	f.markedAs = append(f.markedAs, markBroken)
+	return nil
+}
+
+func (f *fakeConsistencyStore) DeleteFinding(_ context.Context, id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeConsistencyStore) PruneFindings(context.Context) (int64, error) { return 0, nil }
+
+func TestStorageConsistencyJob_RunCheck(t *testing.T) {
+	mirrorID := "mc-1"
+	store := &fakeConsistencyStore{
+		artifacts: map[string][]models.StorageArtifact{
+			models.ArtifactTypeModuleVersion: {
+				{Type: models.ArtifactTypeModuleVersion, ID: "mv-ok", StorageKey: "modules/ok.tar.gz"},
+				{Type: models.ArtifactTypeModuleVersion, ID: "mv-gone", StorageKey: "modules/gone.tar.gz"},
+				{Type: models.ArtifactTypeModuleVersion, ID: "mv-err", StorageKey: "modules/err.tar.gz"},
+			},
+			models.ArtifactTypeProviderPlatform: {
+				// Already a finding: re-checked once, not twice.
+				{Type: models.ArtifactTypeProviderPlatform, ID: "pp-gone", StorageKey: "blobs/sha256/aa", MirrorConfigID: &mirrorID},
+			},
+		},
+		findings: []*models.StorageConsistencyFinding{
+			{ID: "f-back", ArtifactType: models.ArtifactTypeTerraformVersionPlatform, ArtifactID: "tp-1", StorageKey: "terraform/back.zip"},
+			{ID: "f-still", ArtifactType: models.ArtifactTypeProviderPlatform, ArtifactID: "pp-gone", StorageKey: "blobs/sha256/aa", MirrorConfigID: &mirrorID},
+		},
+	}
+	objects := &objectStorage{
+		present: map[string]bool{"modules/ok.tar.gz": true, "terraform/back.zip": true},
+		failing: map[string]bool{"modules/err.tar.gz": true},
+	}
+	cfg := &config.StorageConsistencyConfig{Enabled: true, SampleSize: 100, MarkBroken: true}
+	j := NewStorageConsistencyJob(cfg, store, objects)
+
+	j.runCheck(context.Background())
+
+	run := store.completed
+	if run == nil || run.Error != nil {
+		t.Fatalf("completed run = %+v, want a run without error", run)
+	}
+	if run.Checked != 5 || run.Missing != 2 || run.Errors != 1 {
+		t.Errorf("run counts = checked %d, missing %d, errors %d; want 5, 2, 1", run.Checked, run.Missing, run.Errors)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != "f-back" {
+		t.Errorf("deleted findings = %v, want [f-back]", store.deleted)
+	}
+	if len(store.recorded) != 2 || store.recorded[0].ID != "pp-gone" || store.recorded[1].ID != "mv-gone" {
+		t.Errorf("recorded = %+v, want pp-gone then mv-gone", store.recorded)
+	}
+	if store.recorded[0].Severity() != models.ConsistencySeverityWarning || store.recorded[1].Severity() != models.ConsistencySeverityCritical {
+		t.Error("a mirrored artifact should be a warning and an upload critical")
+	}
+	for _, marked := range store.markedAs {
+		if !marked {
+			t.Error("mark_broken was not passed to RecordMissing")
+		}
+	}
+	for _, limit := range store.limits {
+		if limit != 100 {
+			t.Errorf("ListArtifacts limit = %d, want the sample size", limit)
+		}
+	}
+}
+
+func TestStorageConsistencyJob_FullScan(t *testing.T) {
+	store := &fakeConsistencyStore{}
+	cfg := &config.StorageConsistencyConfig{Enabled: true, FullScan: true, SampleSize: 100}
+	NewStorageConsistencyJob(cfg, store, &objectStorage{}).runCheck(context.Background())
+
+	if len(store.limits) != len(models.ConsistencyArtifactTypes) {
+		t.Fatalf("ListArtifacts called %d times, want once per artifact type", len(store.limits))
+	}
+	for _, limit := range store.limits {
+		if limit != 0 {
+			t.Errorf("full scan ListArtifacts limit = %d, want 0", limit)
+		}
+	}
+}
+
+func TestStorageConsistencyJob_CancelledRunIsRecorded(t *testing.T) {
+	store := &fakeConsistencyStore{
+		findings: []*models.StorageConsistencyFinding{{ID: "f-1", StorageKey: "k"}},
+	}
+	cfg := &config.StorageConsistencyConfig{Enabled: true, SampleSize: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	NewStorageConsistencyJob(cfg, store, &objectStorage{}).runCheck(ctx)
+	if store.completed == nil || store.completed.Error == nil {
+		t.Errorf("completed run = %+v, want it recorded with the cancellation", store.completed)
+	}
+}
+
+func TestStorageConsistencyJob_Disabled(t *testing.T) {
+	j := NewStorageConsistencyJob(&config.StorageConsistencyConfig{}, &fakeConsistencyStore{}, &objectStorage{})
+	if err := j.Start(context.Background()); err != nil {
+		t.Errorf("Start: %v", err)
+	}
+	if j.TriggerCheck() {
+		t.Error("TriggerCheck on a disabled checker reported true")
+	}
+	if err := j.Stop(); err != nil {
+		t.Errorf("Stop: %v", err)
+	}
+	if err := j.Stop(); err != nil {
+		t.Errorf("second Stop: %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/scm"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 )
 
 // TagVerifier periodically verifies that git tags haven't been moved
@@ -88,6 +89,9 @@ func (v *TagVerifier) runVerification(ctx context.Context) {
 
 	checked := 0
 	violations := 0
+	// Publishing tokens are checked once per link per run.
+	linkTokens := make(map[uuid.UUID]*scm.OAuthToken)
+	linkChecked := make(map[uuid.UUID]bool)
 
 	for _, ver := range versions {
 		// Need both a tag name and a commit SHA to verify
@@ -113,10 +117,6 @@ func (v *TagVerifier) runVerification(ctx context.Context) {
 			continue
 		}
 
-		// Build connector (unauthenticated — enough for public repos; authenticated preferred)
-		// NOTE: For private repos, fetching may fail without credentials; those are silently skipped.
-		var oauthToken *scm.OAuthToken
-
 		baseURL := ""
 		if provider.BaseURL != nil {
 			baseURL = *provider.BaseURL
@@ -141,6 +141,16 @@ func (v *TagVerifier) runVerification(ctx context.Context) {
 			continue
 		}
 
+		// Verify the link's publishing token (oauth_user providers) and use it
+		// for the tag lookup. Without a usable token the lookup is
+		// unauthenticated, which is enough for public repos; private repos
+		// fail and are skipped.
+		if !linkChecked[link.ID] {
+			linkChecked[link.ID] = true
+			linkTokens[link.ID] = v.checkPublishingToken(ctx, link, provider, connector)
+		}
+		oauthToken := linkTokens[link.ID]
+
 		// Fetch the current tag from the SCM provider
 		currentTag, fetchErr := connector.FetchTagByName(ctx, oauthToken, link.RepositoryOwner, link.RepositoryName, *ver.TagName)
 		if fetchErr != nil {
@@ -161,3 +171,85 @@ func (v *TagVerifier) runVerification(ctx context.Context) {
 
 	log.Printf("Tag verification run completed: checked %d tags, found %d violations", checked, violations)
 }
+
+// checkPublishingToken resolves the personal token that backs publishes for an
+// oauth_user link and records whether it still works: a missing, undecryptable
+// or expired-without-refresh token, or one the provider rejects, marks the link
+// with token_invalid_at; a working token clears the flag. Transient provider
+// errors leave the flag unchanged. Returns the token when it is usable.
+func (v *TagVerifier) checkPublishingToken(ctx context.Context, link *scm.ModuleSourceRepoRecord, provider *scm.SCMProviderRecord, connector scm.Connector) *scm.OAuthToken {
+	if provider.AuthMode == scm.AuthModeEntraApp || provider.AuthMode == scm.AuthModeGitHubApp {
+		return nil
+	}
+
+	module, err := v.moduleRepo.GetModuleByID(ctx, link.ModuleID.String())
+	if err != nil || module == nil {
+		return nil
+	}
+	publisher := services.PublishingUserID(link, module.CreatedBy)
+	if publisher == nil {
+		return nil
+	}
+	publisherUUID, err := uuid.Parse(*publisher)
+	if err != nil {
+		return nil
+	}
+
+	token, reason := v.loadUserToken(ctx, publisherUUID, link.SCMProviderID)
+	if token != nil {
+		if _, fetchErr := connector.FetchRepository(ctx, token, link.RepositoryOwner, link.RepositoryName); fetchErr != nil {
+			if !scm.IsAuthError(fetchErr) {
+				return token
+			}
+			token, reason = nil, fetchErr.Error()
+		}
+	}
+
+	v.setTokenInvalid(ctx, link, token == nil, reason)
+	return token
+}
+
+// loadUserToken returns a user's decrypted token for a provider, or nil and
+// the reason it cannot be used.
+func (v *TagVerifier) loadUserToken(ctx context.Context, userID, providerID uuid.UUID) (*scm.OAuthToken, string) {
+	record, err := v.scmRepo.GetUserToken(ctx, userID, providerID)
+	if err != nil || record == nil {
+		return nil, "publishing user has no token for this provider"
+	}
+	accessToken, err := v.tokenCipher.Open(record.AccessTokenEncrypted)
+	if err != nil {
+		return nil, "stored token could not be decrypted"
+	}
+	token := &scm.OAuthToken{
+		AccessToken: accessToken,
+		TokenType:   record.TokenType,
+		ExpiresAt:   record.ExpiresAt,
+	}
+	if token.IsExpired() && record.RefreshTokenEncrypted == nil {
+		return nil, "token expired and cannot be refreshed"
+	}
+	return token, ""
+}
+
+// setTokenInvalid updates a link's token_invalid_at flag when its state changes.
+func (v *TagVerifier) setTokenInvalid(ctx context.Context, link *scm.ModuleSourceRepoRecord, invalid bool, reason string) {
+	if invalid == (link.TokenInvalidAt != nil) {
+		return
+	}
+	var invalidAt *time.Time
+	if invalid {
+		now := time.Now()
+		invalidAt = &now
+	}
+	if err := v.scmRepo.SetModuleSourceRepoTokenInvalid(ctx, link.ID, invalidAt); err != nil {
+		log.Printf("Tag verification: failed to update token state for SCM link %s: %v", link.ID, err)
+		return
+	}
+	link.TokenInvalidAt = invalidAt
+	if invalid {
+		log.Printf("Tag verification: publishing token for SCM link %s (%s/%s) is invalid: %s",
+			link.ID, link.RepositoryOwner, link.RepositoryName, reason)
+	} else {
+		log.Printf("Tag verification: publishing token for SCM link %s is valid again", link.ID)
+	}
+}
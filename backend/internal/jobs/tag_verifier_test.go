@@ -7,7 +7,10 @@ import (
 	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
 )
 
 // ---------------------------------------------------------------------------
@@ -161,7 +164,7 @@ func TestRunVerification_EmptyVersions(t *testing.T) {
 			"id", "module_id", "version", "storage_path", "storage_backend",
 			"size_bytes", "checksum", "readme", "published_by", "download_count",
 			"deprecated", "deprecated_at", "deprecation_message", "replacement_source", "created_at",
-			"commit_sha", "tag_name", "scm_repo_id",
+			"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version",
 		}))
 
 	tv.runVerification(context.Background())
@@ -193,3 +196,67 @@ func TestRunVerification_QueryError(t *testing.T) {
 		t.Errorf("unfulfilled expectations: %v", err)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// setTokenInvalid — only writes when the flag changes
+// ---------------------------------------------------------------------------
+
+func newTagVerifierWithSCMMock(t *testing.T) (*TagVerifier, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	scmRepo := repositories.NewSCMRepository(sqlx.NewDb(db, "sqlmock"))
+	return NewTagVerifier(scmRepo, nil, nil, 24), mock
+}
+
+func TestSetTokenInvalid_FlagsValidLink(t *testing.T) {
+	tv, mock := newTagVerifierWithSCMMock(t)
+	link := &scm.ModuleSourceRepoRecord{ID: uuid.New()}
+
+	mock.ExpectExec("UPDATE module_scm_repos SET token_invalid_at").
+		WithArgs(link.ID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tv.setTokenInvalid(context.Background(), link, true, "token expired and cannot be refreshed")
+
+	if link.TokenInvalidAt == nil {
+		t.Error("expected TokenInvalidAt to be set")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSetTokenInvalid_ClearsRecoveredLink(t *testing.T) {
+	tv, mock := newTagVerifierWithSCMMock(t)
+	flagged := time.Now().Add(-time.Hour)
+	link := &scm.ModuleSourceRepoRecord{ID: uuid.New(), TokenInvalidAt: &flagged}
+
+	mock.ExpectExec("UPDATE module_scm_repos SET token_invalid_at").
+		WithArgs(link.ID, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tv.setTokenInvalid(context.Background(), link, false, "")
+
+	if link.TokenInvalidAt != nil {
+		t.Error("expected TokenInvalidAt to be cleared")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSetTokenInvalid_UnchangedStateSkipsWrite(t *testing.T) {
+	tv, mock := newTagVerifierWithSCMMock(t)
+	flagged := time.Now().Add(-time.Hour)
+
+	tv.setTokenInvalid(context.Background(), &scm.ModuleSourceRepoRecord{ID: uuid.New()}, false, "")
+	tv.setTokenInvalid(context.Background(), &scm.ModuleSourceRepoRecord{ID: uuid.New(), TokenInvalidAt: &flagged}, true, "still invalid")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected database calls: %v", err)
+	}
+}
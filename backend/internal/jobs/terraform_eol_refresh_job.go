@@ -0,0 +1,82 @@
+// terraform_eol_refresh_job.go implements the job that keeps the Terraform
+// binary mirror's end-of-life markings in line with the EOL rules.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// terraformEOLRefreshInterval is how often the EOL rules are re-applied. Rules
+// take effect on their eol_date, so an hourly pass marks versions within an
+// hour of the date turning.
+const terraformEOLRefreshInterval = time.Hour
+
+// TerraformEOLRefreshJob applies the operator-maintained EOL rules to every
+// mirrored Terraform/OpenTofu version, then re-resolves the latest version of
+// each enabled mirror so that mirrors excluding EOL versions from latest stop
+// pointing at one.
+type TerraformEOLRefreshJob struct {
+	repo     *repositories.TerraformMirrorRepository
+	stopChan chan struct{}
+	scheduled
+}
+
+// NewTerraformEOLRefreshJob constructs a TerraformEOLRefreshJob.
+func NewTerraformEOLRefreshJob(repo *repositories.TerraformMirrorRepository) *TerraformEOLRefreshJob {
+	return &TerraformEOLRefreshJob{repo: repo, stopChan: make(chan struct{})}
+}
+
+// Name returns the human-readable job name used in logs.
+func (j *TerraformEOLRefreshJob) Name() string { return "terraform-eol-refresh" }
+
+// Start runs one refresh immediately, then one every hour.
+func (j *TerraformEOLRefreshJob) Start(ctx context.Context) error {
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: terraformEOLRefreshInterval}, j.stopChan, j.runRefresh)
+	return nil
+}
+
+// Stop signals the job to exit gracefully. It is safe to call multiple times.
+func (j *TerraformEOLRefreshJob) Stop() error {
+	select {
+	case <-j.stopChan:
+	default:
+		close(j.stopChan)
+	}
+	return nil
+}
+
+// Refresh applies the EOL rules and re-resolves each enabled mirror's latest
+// version. The admin handlers call it after an EOL rule or a mirror's
+// exclude_eol_from_latest changes, so the change shows without waiting for the
+// next scheduled run. A mirror whose latest version cannot be updated is
+// logged and skipped.
+func (j *TerraformEOLRefreshJob) Refresh(ctx context.Context) error {
+	changed, err := j.repo.ApplyEOLRules(ctx)
+	if err != nil {
+		return err
+	}
+	configs, err := j.repo.ListEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list mirrors: %w", err)
+	}
+	for i := range configs {
+		if err := updateTerraformLatestVersion(ctx, j.repo, &configs[i]); err != nil {
+			slog.Error("terraform eol refresh: latest version update failed", "mirror", configs[i].Name, "error", err)
+		}
+	}
+	if changed > 0 {
+		slog.Info("terraform eol refresh: updated version markings", "count", changed)
+	}
+	return nil
+}
+
+func (j *TerraformEOLRefreshJob) runRefresh(ctx context.Context) {
+	if err := j.Refresh(ctx); err != nil {
+		slog.Error("terraform eol refresh failed", "error", err)
+	}
+}
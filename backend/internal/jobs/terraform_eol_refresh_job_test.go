@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+func newTestEOLRefreshJob(t *testing.T) (*TerraformEOLRefreshJob, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := repositories.NewTerraformMirrorRepository(sqlx.NewDb(db, "sqlmock"))
+	return NewTerraformEOLRefreshJob(repo), mock
+}
+
+var eolRefreshVersionCols = []string{"id", "config_id", "version", "is_latest", "is_eol"}
+
+// An EOL latest version is replaced by the newest supported one when the
+// mirror excludes EOL versions from latest.
+func TestTerraformEOLRefreshJob_Refresh_ExcludesEOLFromLatest(t *testing.T) {
+	job, mock := newTestEOLRefreshJob(t)
+	cfgID, eolID, supportedID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectExec("UPDATE terraform_versions tv SET is_eol").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT.*FROM terraform_mirror_configs WHERE enabled = true").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "exclude_eol_from_latest"}).
+			AddRow(cfgID, "tf", true))
+	mock.ExpectQuery("SELECT.*FROM terraform_versions").
+		WithArgs(cfgID).
+		WillReturnRows(sqlmock.NewRows(eolRefreshVersionCols).
+			AddRow(eolID, cfgID, "1.6.0", true, true).
+			AddRow(supportedID, cfgID, "1.5.7", false, false))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE terraform_versions SET is_latest = false").
+		WithArgs(cfgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE terraform_versions SET is_latest = true").
+		WithArgs(supportedID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := job.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// When every version is EOL no version is left marked latest.
+func TestTerraformEOLRefreshJob_Refresh_AllEOLClearsLatest(t *testing.T) {
+	job, mock := newTestEOLRefreshJob(t)
+	cfgID := uuid.New()
+
+	mock.ExpectExec("UPDATE terraform_versions tv SET is_eol").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT.*FROM terraform_mirror_configs WHERE enabled = true").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "exclude_eol_from_latest"}).
+			AddRow(cfgID, "tf", true))
+	mock.ExpectQuery("SELECT.*FROM terraform_versions").
+		WithArgs(cfgID).
+		WillReturnRows(sqlmock.NewRows(eolRefreshVersionCols).
+			AddRow(uuid.New(), cfgID, "1.5.7", true, true))
+	mock.ExpectExec("UPDATE terraform_versions SET is_latest = false").
+		WithArgs(cfgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := job.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// Without exclude_eol_from_latest an EOL version may stay latest.
+func TestTerraformEOLRefreshJob_Refresh_KeepsEOLLatestByDefault(t *testing.T) {
+	job, mock := newTestEOLRefreshJob(t)
+	cfgID := uuid.New()
+
+	mock.ExpectExec("UPDATE terraform_versions tv SET is_eol").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT.*FROM terraform_mirror_configs WHERE enabled = true").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "exclude_eol_from_latest"}).
+			AddRow(cfgID, "tf", false))
+	mock.ExpectQuery("SELECT.*FROM terraform_versions").
+		WithArgs(cfgID).
+		WillReturnRows(sqlmock.NewRows(eolRefreshVersionCols).
+			AddRow(uuid.New(), cfgID, "1.6.0", true, true).
+			AddRow(uuid.New(), cfgID, "1.5.7", false, false))
+
+	if err := job.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestTerraformEOLRefreshJob_Name(t *testing.T) {
+	job := NewTerraformEOLRefreshJob(nil)
+	if job.Name() != "terraform-eol-refresh" {
+		t.Errorf("Name() = %q", job.Name())
+	}
+	if err := job.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := job.Stop(); err != nil {
+		t.Fatalf("second Stop: %v", err)
+	}
+}
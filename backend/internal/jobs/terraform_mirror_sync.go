@@ -9,6 +9,9 @@
 //   - TriggerSync(ctx, configID) allows a single config to be synced on demand.
 //   - GPG key selection driven by config.Tool ("terraform" → HashiCorp key,
 //     "opentofu" → OpenTofu key, "custom" / gpg_verify=false → skip).
+//   - OpenTofu SHA256SUMS that do not GPG-verify fall back to the release's
+//     keyless cosign signature; the method that verified each platform is
+//     recorded in signature_method.
 package jobs
 
 import (
@@ -25,14 +28,15 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
 	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/notify"
 	"github.com/terraform-registry/terraform-registry/internal/safego"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 
@@ -45,10 +49,10 @@ type TerraformMirrorSyncJob struct {
 	storageBackend     storage.Storage
 	storageBackendName string
 
-	activeSyncs      map[uuid.UUID]bool
-	activeSyncsMutex sync.Mutex
+	activeSyncs *activeRuns
 
 	stopCh chan struct{}
+	scheduled
 	// intervalMinutes is the sync cadence; SetInterval overrides it, otherwise
 	// Start falls back to defaultTerraformMirrorSyncIntervalMinutes.
 	intervalMinutes int
@@ -59,6 +63,15 @@ type TerraformMirrorSyncJob struct {
 	// egressGuard widens the SSRF egress deny-list for upstream fetches
 	// (nil = strict). Set via SetEgressGuard before Start.
 	egressGuard *httpsafe.Guard
+
+	// scratchDir is where downloads are staged ("" = OS temp dir). Set via
+	// SetScratch before Start.
+	scratchDir string
+
+	// notifier sends the mirror_storage_threshold event when a mirror's
+	// stored binaries cross 80% or 100% of max_storage_bytes. Optional; set
+	// via SetNotifier.
+	notifier *notify.Notifier
 }
 
 // NewTerraformMirrorSyncJob creates a new TerraformMirrorSyncJob.
@@ -71,7 +84,7 @@ func NewTerraformMirrorSyncJob(
 		repo:               repo,
 		storageBackend:     storageBackend,
 		storageBackendName: storageBackendName,
-		activeSyncs:        make(map[uuid.UUID]bool),
+		activeSyncs:        newActiveRuns(),
 		stopCh:             make(chan struct{}),
 		manualTriggerCh:    make(chan uuid.UUID, 16),
 	}
@@ -84,6 +97,18 @@ func (j *TerraformMirrorSyncJob) SetEgressGuard(g *httpsafe.Guard) {
 	j.egressGuard = g
 }
 
+// SetScratch stages downloaded binaries in the registry's scratch directory.
+// Call before Start.
+func (j *TerraformMirrorSyncJob) SetScratch(space *scratch.Space) {
+	j.scratchDir = space.Dir()
+}
+
+// SetNotifier wires the notifier used for storage limit alerts. Optional:
+// when unset, limits are still enforced but no notification is sent.
+func (j *TerraformMirrorSyncJob) SetNotifier(n *notify.Notifier) {
+	j.notifier = n
+}
+
 // defaultTerraformMirrorSyncIntervalMinutes is the sync cadence used when
 // SetInterval was not called (preserves the value previously hard-coded at
 // the call site).
@@ -107,27 +132,25 @@ func (j *TerraformMirrorSyncJob) Start(ctx context.Context) error {
 	}
 	log.Printf("[terraform-mirror] starting sync job (interval: %d minutes)", intervalMinutes)
 
-	ticker := time.NewTicker(time.Duration(intervalMinutes) * time.Minute)
-	defer ticker.Stop()
-
-	// Run an initial scheduled check immediately on startup.
-	j.runScheduledSyncs(ctx)
-
-	for {
-		select {
-		case <-ticker.C:
-			j.runScheduledSyncs(ctx)
-		case configID := <-j.manualTriggerCh:
-			cid := configID
-			safego.Go(func() { j.syncConfig(ctx, cid, "manual") })
-		case <-j.stopCh:
-			log.Println("[terraform-mirror] sync job stopped")
-			return nil
-		case <-ctx.Done():
-			log.Println("[terraform-mirror] sync job context cancelled")
-			return nil
+	// Manual per-config triggers are dispatched alongside the scheduled loop
+	// for as long as it runs.
+	loopDone := make(chan struct{})
+	defer close(loopDone)
+	safego.Go(func() {
+		for {
+			select {
+			case configID := <-j.manualTriggerCh:
+				safego.Go(func() { j.syncConfig(ctx, configID, "manual") })
+			case <-loopDone:
+				return
+			}
 		}
-	}
+	})
+
+	// The first scheduled check runs immediately on startup.
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: time.Duration(intervalMinutes) * time.Minute}, j.stopCh, j.runScheduledSyncs)
+	log.Println("[terraform-mirror] sync job stopped")
+	return nil
 }
 
 // Stop signals the sync loop to exit. Best-effort and idempotent (matching the
@@ -170,14 +193,10 @@ func (j *TerraformMirrorSyncJob) runScheduledSyncs(ctx context.Context) {
 	for _, cfg := range configs {
 		cfgID := cfg.ID // capture for goroutine
 
-		j.activeSyncsMutex.Lock()
-		if j.activeSyncs[cfgID] {
+		if !j.activeSyncs.tryAcquire(cfgID) {
 			log.Printf("[terraform-mirror] config %s (%s) is already syncing, skipping", cfg.Name, cfgID)
-			j.activeSyncsMutex.Unlock()
 			continue
 		}
-		j.activeSyncs[cfgID] = true
-		j.activeSyncsMutex.Unlock()
 
 		safego.Go(func() { j.doSync(ctx, cfgID, "scheduler") })
 	}
@@ -186,14 +205,10 @@ func (j *TerraformMirrorSyncJob) runScheduledSyncs(ctx context.Context) {
 // syncConfig is the entrypoint for a manual sync trigger.
 // coverage:skip:integration-only — delegates to doSync which constructs a live releases client and talks to upstream HTTP + DB; exercised by integration tests.
 func (j *TerraformMirrorSyncJob) syncConfig(ctx context.Context, configID uuid.UUID, triggeredBy string) {
-	j.activeSyncsMutex.Lock()
-	if j.activeSyncs[configID] {
+	if !j.activeSyncs.tryAcquire(configID) {
 		log.Printf("[terraform-mirror] config %s already syncing, ignoring %s trigger", configID, triggeredBy)
-		j.activeSyncsMutex.Unlock()
 		return
 	}
-	j.activeSyncs[configID] = true
-	j.activeSyncsMutex.Unlock()
 
 	j.doSync(ctx, configID, triggeredBy)
 }
@@ -201,11 +216,7 @@ func (j *TerraformMirrorSyncJob) syncConfig(ctx context.Context, configID uuid.U
 // doSync performs the full sync lifecycle for one config: load, create history, sync, update history.
 // coverage:skip:integration-only — drives the complete sync pipeline with a live releases client + storage + DB; exercised by the api-test integration suite.
 func (j *TerraformMirrorSyncJob) doSync(ctx context.Context, configID uuid.UUID, triggeredBy string) {
-	defer func() {
-		j.activeSyncsMutex.Lock()
-		delete(j.activeSyncs, configID)
-		j.activeSyncsMutex.Unlock()
-	}()
+	defer j.activeSyncs.release(configID)
 
 	cfg, err := j.repo.GetByID(ctx, configID)
 	if err != nil || cfg == nil {
@@ -255,7 +266,31 @@ func (j *TerraformMirrorSyncJob) doSync(ctx context.Context, configID uuid.UUID,
 
 	_ = j.repo.CompleteSyncHistory(cleanupCtx, histRecord.ID, status,
 		versionsSynced, platformsSynced, versionsFailed, errMsg, detailsStr)
+
+	// A run that skipped versions at the storage limit still succeeded, but
+	// the mirror's status says so until the limit is raised.
+	if syncErr == nil && syncDetails != nil && syncDetails.VersionsSkipped > 0 {
+		status = models.MirrorSyncStatusLimitReached
+		s := syncDetails.storageLimitError
+		errMsg = &s
+		log.Printf("[terraform-mirror] %s: %s", cfg.Name, s)
+	}
 	_ = j.repo.UpdateSyncStatus(cleanupCtx, configID, status, errMsg)
+
+	if syncDetails != nil && syncDetails.storageChecked {
+		checkStorageAlert(cleanupCtx, j.notifier, "Terraform mirror", cfg.Name, cfg.StorageAlertLevel,
+			syncDetails.StorageUsedBytes, cfg.MaxStorageBytes,
+			func(ctx context.Context, level int) error {
+				return j.repo.SetStorageAlertLevel(ctx, configID, level)
+			})
+	}
+
+	// Enforce the config's history retention now that this run is recorded.
+	if pruned, pruneErr := j.repo.PruneSyncHistory(cleanupCtx, configID, cfg.HistoryRetentionCount, cfg.HistoryRetentionDays); pruneErr != nil {
+		log.Printf("[terraform-mirror] failed to prune sync history for %s: %v", cfg.Name, pruneErr)
+	} else if pruned > 0 {
+		log.Printf("[terraform-mirror] pruned %d sync history entries for %s", pruned, cfg.Name)
+	}
 }
 
 // ----- Client interface -----------------------------------------------------
@@ -304,6 +339,14 @@ func githubBinaryPrefix(productName string) string {
 type terraformSyncDetails struct {
 	VersionsFound int      `json:"versions_found"`
 	Errors        []string `json:"errors,omitempty"`
+	// StorageUsedBytes is the size of the mirror's stored binaries when the
+	// sync finished. VersionsSkipped counts the versions left unsynced
+	// because the mirror reached its max_storage_bytes.
+	StorageUsedBytes int64 `json:"storage_used_bytes,omitempty"`
+	VersionsSkipped  int   `json:"versions_skipped_storage_limit,omitempty"`
+
+	storageChecked    bool
+	storageLimitError string
 }
 
 // coverage:skip:integration-only — performs live upstream HTTP + storage + DB writes for the complete sync pipeline; exercised by api-test integration suite.
@@ -429,7 +472,40 @@ func (j *TerraformMirrorSyncJob) performSync(
 		}
 	}
 
+	// Charge what this run downloads against the mirror's storage limit,
+	// newest versions first so a mirror near its limit keeps the releases
+	// users are most likely to want. Binaries stored before sizes were
+	// recorded are sized from storage first. When the current usage cannot
+	// be read the limit is not enforced this run.
+	j.backfillPlatformSizes(ctx, cfg)
+	ordered := make([]*platformGroup, 0, len(groups))
 	for _, group := range groups {
+		ordered = append(ordered, group)
+	}
+	sort.Slice(ordered, func(a, b int) bool {
+		return compareTerraformSemver(ordered[a].version, ordered[b].version) > 0
+	})
+	if used, usageErr := j.repo.GetStorageUsage(ctx, cfg.ID); usageErr != nil {
+		log.Printf("[terraform-mirror] failed to read storage usage for %s, storage limit not enforced: %v", cfg.Name, usageErr)
+	} else {
+		budget := newStorageBudget(used, cfg.MaxStorageBytes)
+		ctx = withStorageBudget(ctx, budget)
+		defer func() {
+			details.storageChecked = true
+			details.StorageUsedBytes = budget.used
+			details.VersionsSkipped = budget.skipped
+			if budget.skipped > 0 {
+				details.storageLimitError = storageLimitMessage(budget)
+			}
+		}()
+	}
+
+	for _, group := range ordered {
+		if budget := storageBudgetFrom(ctx); budget.exhausted() {
+			budget.skip()
+			log.Printf("[terraform-mirror] storage limit reached for %s, not syncing %s", cfg.Name, group.version)
+			continue
+		}
 		vs, ps, vf := j.syncVersionBinaries(ctx, client, cfg, group.version, group.versionID, group.platforms)
 		versionsSynced += vs
 		platformsSynced += ps
@@ -469,8 +545,12 @@ func (j *TerraformMirrorSyncJob) performSync(
 		log.Printf("[terraform-mirror] attestation back-fill error for %s: %v", cfg.Name, backfillErr)
 	}
 
-	// 7. Mark the highest fully-synced stable version as is_latest.
-	if setLatestErr := j.updateLatestVersion(ctx, cfg.ID); setLatestErr != nil {
+	// 7. Mark newly discovered versions end-of-life per the EOL rules, then
+	// mark the highest fully-synced stable version as is_latest.
+	if _, eolErr := j.repo.ApplyEOLRules(ctx); eolErr != nil {
+		log.Printf("[terraform-mirror] failed to apply EOL rules for %s: %v", cfg.Name, eolErr)
+	}
+	if setLatestErr := updateTerraformLatestVersion(ctx, j.repo, cfg); setLatestErr != nil {
 		log.Printf("[terraform-mirror] failed to update latest version for %s: %v", cfg.Name, setLatestErr)
 	}
 
@@ -496,38 +576,24 @@ func (j *TerraformMirrorSyncJob) syncVersionBinaries(
 		sums = nil
 	}
 
-	// GPG-verify the SUMS file if enabled. Capture sigBytes so we can persist
-	// it alongside the SUMS file for the public download endpoint to serve.
+	// Authenticate the SUMS file if enabled. Capture the GPG signature so we
+	// can persist it alongside the SUMS file for the public download endpoint
+	// to serve. A signature that fails to verify fails the version for tools
+	// with a cosign fallback (see verifyVersionSums); its platforms stay
+	// pending and are retried on the next run.
 	sumsGPGVerified := false
 	var verifiedSigBytes []byte
+	var signatureMethod string
 	if cfg.GPGVerify && sumsRaw != nil {
-		gpgKey := gpgKeyForConfig(cfg)
-		if gpgKey != "" {
-			sigBytes, sigErr := client.FetchSHASumsSignature(ctx, version)
-			if sigErr != nil {
-				log.Printf("[terraform-mirror] failed to fetch GPG sig for %s@%s: %v", version, cfg.Name, sigErr)
-			} else {
-				if verifyErr := validation.VerifySignature(gpgKey, sumsRaw, sigBytes); verifyErr != nil {
-					log.Printf("[terraform-mirror] GPG verification FAILED for %s SHA256SUMS (%s): %v",
-						version, cfg.Name, verifyErr)
-				} else {
-					sumsGPGVerified = true
-					verifiedSigBytes = sigBytes
-					log.Printf("[terraform-mirror] GPG verification OK for %s SHA256SUMS (%s)", version, cfg.Name)
-				}
-			}
-		} else {
-			if mirror.IsUnsignedUpstreamTool(cfg.Tool) {
-				// OPA (and any other unsigned-upstream tool) publishes no release
-				// signature — only per-file SHA-256 checksums, already fetched into
-				// `sums` and checked per binary below. Integrity is verified;
-				// authenticity cannot be. Surface this honestly rather than as a
-				// missing-key warning (the admin signing-keys view shows the same).
-				log.Printf("[terraform-mirror] %s publishes no release signature; verifying %s by checksum only (no GPG)", cfg.Tool, version)
-			} else {
-				log.Printf("[terraform-mirror] GPG verify enabled but no key for tool %q — skipping GPG check", cfg.Tool)
-			}
+		method, sigBytes, verifyErr := verifyVersionSums(ctx, client, cfg, version, sumsRaw)
+		if verifyErr != nil {
+			errStr := verifyErr.Error()
+			_ = j.repo.UpdateVersionSyncStatus(ctx, versionID, "failed", &errStr)
+			return 0, 0, 1
 		}
+		signatureMethod = method
+		sumsGPGVerified = method == signatureMethodGPG
+		verifiedSigBytes = sigBytes
 	}
 
 	// Persist SHA256SUMS (always, if we fetched it) and the GPG signature
@@ -550,11 +616,12 @@ func (j *TerraformMirrorSyncJob) syncVersionBinaries(
 	// config). nil when the flag is off or the upstream isn't GitHub-hosted,
 	// in which case syncOnePlatform skips attestation entirely.
 	attestVerifier := attestationVerifierForConfig(cfg, j.egressGuard)
+	source := versionUpstreamSource(ctx, client, cfg, version)
 
 	platformOK := 0
 	platformFail := 0
 	for _, p := range platforms {
-		ok := j.syncOnePlatform(ctx, client, version, p, sums, sumsGPGVerified, attestVerifier)
+		ok := j.syncOnePlatform(ctx, client, version, p, sums, sumsGPGVerified, attestVerifier, source)
 		if ok {
 			platformOK++
 		} else {
@@ -562,6 +629,12 @@ func (j *TerraformMirrorSyncJob) syncVersionBinaries(
 		}
 	}
 
+	if signatureMethod != "" && platformOK > 0 {
+		if err := j.repo.UpdateSignatureMethodForVersion(ctx, versionID, signatureMethod); err != nil {
+			log.Printf("[terraform-mirror] failed to record signature method for version %s: %v", version, err)
+		}
+	}
+
 	if platformFail == 0 && platformOK > 0 {
 		_ = j.repo.UpdateVersionSyncStatus(ctx, versionID, "synced", nil)
 		versionsSynced = 1
@@ -632,7 +705,9 @@ func (j *TerraformMirrorSyncJob) storeVersionVerificationFiles(
 	}
 }
 
-// syncOnePlatform downloads a single binary and stores it.
+// syncOnePlatform downloads a single binary and stores it, recording source
+// as its upstream provenance. A binary that is already stored keeps the
+// provenance of the run that fetched it.
 // coverage:skip:integration-only — streams a live binary from upstream, checksums it, and uploads to the storage backend; covered by integration tests.
 func (j *TerraformMirrorSyncJob) syncOnePlatform(
 	ctx context.Context,
@@ -642,6 +717,7 @@ func (j *TerraformMirrorSyncJob) syncOnePlatform(
 	sums map[string]string,
 	sumsGPGVerified bool,
 	attestVerifier attestationVerifier,
+	source upstreamSource,
 ) bool {
 	// Skip if already stored.
 	if p.StorageKey != nil {
@@ -650,11 +726,15 @@ func (j *TerraformMirrorSyncJob) syncOnePlatform(
 			backendName := j.storageBackendName
 			attestationVerified := verifyBinaryAttestation(ctx, attestVerifier, version, p.OS, p.Arch, p.SHA256)
 			_ = j.repo.UpdatePlatformSyncStatus(ctx, p.ID, "synced", p.StorageKey, &backendName, true, sumsGPGVerified, attestationVerified, nil)
+			if p.SizeBytes == nil {
+				j.recordPlatformSize(ctx, p.ID, *p.StorageKey)
+			}
 			return true
 		}
 	}
 
 	log.Printf("[terraform-mirror] downloading %s (%s/%s)", version, p.OS, p.Arch)
+	fetchedAt := time.Now()
 
 	body, _, dlErr := client.DownloadBinaryStream(ctx, p.UpstreamURL)
 	if dlErr != nil {
@@ -664,7 +744,7 @@ func (j *TerraformMirrorSyncJob) syncOnePlatform(
 		return false
 	}
 
-	tmpFile, tmpErr := os.CreateTemp("", "terraform-binary-*.zip")
+	tmpFile, tmpErr := os.CreateTemp(j.scratchDir, "terraform-binary-*.zip")
 	if tmpErr != nil {
 		body.Close()
 		errStr := fmt.Sprintf("failed to create temp file: %v", tmpErr)
@@ -738,12 +818,55 @@ func (j *TerraformMirrorSyncJob) syncOnePlatform(
 		return false
 	}
 
+	provenance := models.NewUpstreamProvenance(source.registry, p.UpstreamURL, source.sumsURL, fetchedAt)
+	if err := j.repo.RecordPlatformProvenance(ctx, p.ID, provenance); err != nil {
+		log.Printf("[terraform-mirror] failed to record provenance for %s %s/%s: %v", version, p.OS, p.Arch, err)
+	}
+
+	if err := j.repo.UpdatePlatformSize(ctx, p.ID, written); err != nil {
+		log.Printf("[terraform-mirror] failed to record size for %s %s/%s: %v", version, p.OS, p.Arch, err)
+	}
+	storageBudgetFrom(ctx).add(written)
+
 	backendName := j.storageBackendName
 	_ = j.repo.UpdatePlatformSyncStatus(ctx, p.ID, "synced", &storagePath, &backendName, sha256Verified, sumsGPGVerified, attestationVerified, nil)
 	log.Printf("[terraform-mirror] stored %s %s/%s -> %s", version, p.OS, p.Arch, storagePath)
 	return true
 }
 
+// recordPlatformSize records the size of a binary already in storage, read
+// from the backend's metadata, and charges it to the run's storage budget.
+// Failures are logged; the platform then counts towards usage from the next
+// run's size back-fill.
+func (j *TerraformMirrorSyncJob) recordPlatformSize(ctx context.Context, platformID uuid.UUID, storageKey string) {
+	meta, err := j.storageBackend.GetMetadata(ctx, storageKey)
+	if err != nil {
+		log.Printf("[terraform-mirror] failed to read size of %s: %v", storageKey, err)
+		return
+	}
+	if err := j.repo.UpdatePlatformSize(ctx, platformID, meta.Size); err != nil {
+		log.Printf("[terraform-mirror] failed to record size of %s: %v", storageKey, err)
+		return
+	}
+	storageBudgetFrom(ctx).add(meta.Size)
+}
+
+// backfillPlatformSizes records the size of synced binaries stored before
+// sizes were kept, so they count towards the mirror's storage usage. Sizes
+// come from the storage backend's metadata; nothing is downloaded.
+func (j *TerraformMirrorSyncJob) backfillPlatformSizes(ctx context.Context, cfg *models.TerraformMirrorConfig) {
+	platforms, err := j.repo.ListPlatformsMissingSize(ctx, cfg.ID)
+	if err != nil {
+		log.Printf("[terraform-mirror] size back-fill error for %s: %v", cfg.Name, err)
+		return
+	}
+	for _, p := range platforms {
+		if p.StorageKey != nil {
+			j.recordPlatformSize(ctx, p.ID, *p.StorageKey)
+		}
+	}
+}
+
 // backfillSHA256 populates the sha256 column for already-synced platforms whose
 // hash was not persisted during their original sync run. It fetches the
 // lightweight upstream SHA256SUMS text for each filtered version (~5KB each)
@@ -844,7 +967,7 @@ func (j *TerraformMirrorSyncJob) backfillGPGVerification(
 		}
 		needsBackfill := false
 		for _, p := range platforms {
-			if !p.GPGVerified {
+			if !p.GPGVerified && p.SignatureMethod == nil {
 				needsBackfill = true
 				break
 			}
@@ -873,6 +996,9 @@ func (j *TerraformMirrorSyncJob) backfillGPGVerification(
 		if updErr := j.repo.UpdateGPGVerifiedForVersion(ctx, sv.ID, true); updErr != nil {
 			log.Printf("[terraform-mirror] backfill: failed to update gpg_verified for %s: %v", sv.Version, updErr)
 		}
+		if updErr := j.repo.UpdateSignatureMethodForVersion(ctx, sv.ID, signatureMethodGPG); updErr != nil {
+			log.Printf("[terraform-mirror] backfill: failed to update signature_method for %s: %v", sv.Version, updErr)
+		}
 	}
 
 	return nil
@@ -996,11 +1122,12 @@ func (j *TerraformMirrorSyncJob) backfillAttestation(
 	return nil
 }
 
-// updateLatestVersion scans all fully-synced versions for a config and sets is_latest
-// on the highest stable semver. Runs inside a DB transaction (via SetLatestVersion).
+// updateTerraformLatestVersion scans all fully-synced versions for a config and sets
+// is_latest on the highest stable semver, skipping end-of-life versions when the
+// config sets exclude_eol_from_latest. Runs inside a DB transaction (via SetLatestVersion).
 // coverage:skip:requires-database — selects and updates synced-version rows; covered by integration tests.
-func (j *TerraformMirrorSyncJob) updateLatestVersion(ctx context.Context, configID uuid.UUID) error {
-	syncedVersions, err := j.repo.ListVersions(ctx, configID, true /* syncedOnly */)
+func updateTerraformLatestVersion(ctx context.Context, repo *repositories.TerraformMirrorRepository, cfg *models.TerraformMirrorConfig) error {
+	syncedVersions, err := repo.ListVersions(ctx, cfg.ID, true /* syncedOnly */)
 	if err != nil || len(syncedVersions) == 0 {
 		return err
 	}
@@ -1017,6 +1144,20 @@ func (j *TerraformMirrorSyncJob) updateLatestVersion(ctx context.Context, config
 		return nil // nothing approved/visible yet — leave is_latest unset
 	}
 
+	if cfg.ExcludeEOLFromLatest {
+		supported := visible[:0]
+		for _, v := range visible {
+			if !v.IsEOL {
+				supported = append(supported, v)
+			}
+		}
+		if len(supported) == 0 {
+			// Every visible version is end-of-life: no version may be latest.
+			return repo.ClearLatestVersion(ctx, cfg.ID)
+		}
+		visible = supported
+	}
+
 	stable := make([]models.TerraformVersion, 0, len(visible))
 	for _, v := range visible {
 		if !hasPreReleaseSuffix(v.Version) {
@@ -1032,7 +1173,10 @@ func (j *TerraformMirrorSyncJob) updateLatestVersion(ctx context.Context, config
 		return compareTerraformSemver(stable[i].Version, stable[k].Version) > 0
 	})
 
-	return j.repo.SetLatestVersion(ctx, configID, stable[0].ID)
+	if stable[0].IsLatest {
+		return nil
+	}
+	return repo.SetLatestVersion(ctx, cfg.ID, stable[0].ID)
 }
 
 // resolveTerraformApproval decides the approval_status for a freshly discovered
@@ -1132,6 +1276,114 @@ func gpgKeyForTool(tool string) string {
 	}
 }
 
+// Signature methods recorded in terraform_version_platforms.signature_method.
+const (
+	signatureMethodGPG    = "gpg"
+	signatureMethodCosign = "cosign"
+)
+
+// sumsURLResolver is implemented by releases clients that can name the
+// SHA256SUMS file of a version, recorded as each platform's provenance.
+type sumsURLResolver interface {
+	SHASumsURL(ctx context.Context, version string) (string, error)
+}
+
+// upstreamSource is where a version's binaries are mirrored from.
+type upstreamSource struct {
+	registry string // hostname of the config's upstream URL
+	sumsURL  string // "" when unknown
+}
+
+// versionUpstreamSource resolves the upstream source of a version's binaries.
+func versionUpstreamSource(ctx context.Context, client terraformReleasesClient, cfg *models.TerraformMirrorConfig, version string) upstreamSource {
+	src := upstreamSource{registry: models.UpstreamHostname(cfg.UpstreamURL)}
+	if r, ok := client.(sumsURLResolver); ok {
+		if u, err := r.SHASumsURL(ctx, version); err == nil {
+			src.sumsURL = u
+		}
+	}
+	return src
+}
+
+// cosignSumsFetcher is implemented by releases clients that can fetch the
+// keyless cosign signature of a version's SHA256SUMS (GitHubReleasesClient).
+type cosignSumsFetcher interface {
+	FetchSHASumsCosignSignature(ctx context.Context, version string) (sig, cert []byte, err error)
+}
+
+// verifyCosignBlob is mirror.VerifyCosignBlob; tests replace it to avoid the
+// live Sigstore trust root.
+var verifyCosignBlob = mirror.VerifyCosignBlob
+
+// verifyVersionSums authenticates a version's SHA256SUMS file. It checks the
+// detached GPG signature when the config has a key, and for tools that also
+// publish a keyless cosign signature (OpenTofu) falls back to that when GPG
+// does not verify. It returns the method that verified the file ("" when none
+// did) and, for GPG, the verified signature to persist.
+//
+// For tools with a cosign identity, err is non-nil when a signature was found
+// but did not verify and no method succeeded; the caller fails the version.
+// Missing signatures and an unreachable Sigstore trust root only leave the
+// file unverified. Other tools keep checksum-only behaviour on any failure.
+func verifyVersionSums(
+	ctx context.Context,
+	client terraformReleasesClient,
+	cfg *models.TerraformMirrorConfig,
+	version string,
+	sumsRaw []byte,
+) (method string, gpgSig []byte, err error) {
+	var failures []string
+
+	gpgKey := gpgKeyForConfig(cfg)
+	switch {
+	case gpgKey != "":
+		sigBytes, sigErr := client.FetchSHASumsSignature(ctx, version)
+		if sigErr != nil {
+			log.Printf("[terraform-mirror] failed to fetch GPG sig for %s@%s: %v", version, cfg.Name, sigErr)
+		} else if verifyErr := validation.VerifySignature(gpgKey, sumsRaw, sigBytes); verifyErr != nil {
+			log.Printf("[terraform-mirror] GPG verification FAILED for %s SHA256SUMS (%s): %v",
+				version, cfg.Name, verifyErr)
+			failures = append(failures, "GPG: "+verifyErr.Error())
+		} else {
+			log.Printf("[terraform-mirror] GPG verification OK for %s SHA256SUMS (%s)", version, cfg.Name)
+			return signatureMethodGPG, sigBytes, nil
+		}
+	case mirror.IsUnsignedUpstreamTool(cfg.Tool):
+		// OPA (and any other unsigned-upstream tool) publishes no release
+		// signature — only per-file SHA-256 checksums, already fetched into
+		// `sums` and checked per binary. Integrity is verified; authenticity
+		// cannot be. Surface this honestly rather than as a missing-key
+		// warning (the admin signing-keys view shows the same).
+		log.Printf("[terraform-mirror] %s publishes no release signature; verifying %s by checksum only (no GPG)", cfg.Tool, version)
+	default:
+		log.Printf("[terraform-mirror] GPG verify enabled but no key for tool %q — skipping GPG check", cfg.Tool)
+	}
+
+	identity := mirror.CosignIdentityForTool(cfg.Tool)
+	if identity == nil || cfg.SkipGPGVerify {
+		return "", nil, nil
+	}
+	fetcher, ok := client.(cosignSumsFetcher)
+	if !ok {
+		log.Printf("[terraform-mirror] upstream %s serves no cosign signatures; %s SHA256SUMS not cosign-verified (%s)", cfg.UpstreamURL, version, cfg.Name)
+	} else if sig, cert, fetchErr := fetcher.FetchSHASumsCosignSignature(ctx, version); fetchErr != nil {
+		log.Printf("[terraform-mirror] failed to fetch cosign signature for %s@%s: %v", version, cfg.Name, fetchErr)
+	} else if verifyErr := verifyCosignBlob(sumsRaw, sig, cert, *identity); errors.Is(verifyErr, mirror.ErrCosignUnavailable) {
+		log.Printf("[terraform-mirror] WARNING: cosign verification unavailable for %s SHA256SUMS (%s): %v", version, cfg.Name, verifyErr)
+	} else if verifyErr != nil {
+		log.Printf("[terraform-mirror] cosign verification FAILED for %s SHA256SUMS (%s): %v", version, cfg.Name, verifyErr)
+		failures = append(failures, "cosign: "+verifyErr.Error())
+	} else {
+		log.Printf("[terraform-mirror] cosign verification OK for %s SHA256SUMS (%s)", version, cfg.Name)
+		return signatureMethodCosign, nil, nil
+	}
+
+	if len(failures) > 0 {
+		return "", nil, fmt.Errorf("SHA256SUMS signature verification failed: %s", strings.Join(failures, "; "))
+	}
+	return "", nil, nil
+}
+
 // gpgKeyForConfig returns the GPG key to use for a given mirror config,
 // checking custom config fields before falling back to the built-in key.
 // Returns "" if GPG verification should be skipped.
@@ -4,6 +4,7 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -172,7 +173,7 @@ func TestSyncOnePlatform_RejectsUnsafeUpstreamFilename(t *testing.T) {
 	client := &fakeReleasesClient{binary: "fake-binary-content"}
 	p := models.TerraformVersionPlatform{ID: uuid.New(), OS: "linux", Arch: "amd64", Filename: "../../etc/passwd"}
 
-	ok := job.syncOnePlatform(context.Background(), client, "1.7.0", p, nil, false, nil)
+	ok := job.syncOnePlatform(context.Background(), client, "1.7.0", p, nil, false, nil, upstreamSource{})
 	if ok {
 		t.Fatal("expected syncOnePlatform to fail for a path-traversal filename from the upstream releases index")
 	}
@@ -190,15 +191,21 @@ func TestSyncOnePlatform_AcceptsWellFormedFilename(t *testing.T) {
 		t.Fatalf("sqlmock.New: %v", err)
 	}
 	defer db.Close()
+	upstreamURL := "https://releases.hashicorp.com/terraform/1.7.0/terraform_1.7.0_linux_amd64.zip"
+	sumsURL := "https://releases.hashicorp.com/terraform/1.7.0/terraform_1.7.0_SHA256SUMS"
+	mock.ExpectExec(`UPDATE terraform_version_platforms\s+SET provenance`).
+		WithArgs(sqlmock.AnyArg(), models.ProvenanceRecorded, "releases.hashicorp.com", upstreamURL, sumsURL, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectExec("UPDATE terraform_version_platforms").WillReturnResult(sqlmock.NewResult(0, 1))
 
 	repo := repositories.NewTerraformMirrorRepository(sqlx.NewDb(db, "sqlmock"))
 	fakeStorage := &fakeUploadStorage{}
 	job := NewTerraformMirrorSyncJob(repo, fakeStorage, "local")
 	client := &fakeReleasesClient{binary: "fake-binary-content"}
-	p := models.TerraformVersionPlatform{ID: uuid.New(), OS: "linux", Arch: "amd64", Filename: "terraform_1.7.0_linux_amd64.zip"}
+	p := models.TerraformVersionPlatform{ID: uuid.New(), OS: "linux", Arch: "amd64", Filename: "terraform_1.7.0_linux_amd64.zip", UpstreamURL: upstreamURL}
 
-	ok := job.syncOnePlatform(context.Background(), client, "1.7.0", p, nil, false, nil)
+	ok := job.syncOnePlatform(context.Background(), client, "1.7.0", p, nil, false, nil,
+		upstreamSource{registry: "releases.hashicorp.com", sumsURL: sumsURL})
 	if !ok {
 		t.Fatal("expected syncOnePlatform to succeed for a well-formed upstream filename")
 	}
@@ -210,3 +217,120 @@ func TestSyncOnePlatform_AcceptsWellFormedFilename(t *testing.T) {
 		t.Errorf("unmet sqlmock expectations: %v", err)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// verifyVersionSums — GPG with cosign fallback for OpenTofu
+// ---------------------------------------------------------------------------
+
+// fakeCosignReleasesClient adds FetchSHASumsCosignSignature to
+// fakeReleasesClient. Its GPG signature is empty, so GPG verification against
+// the OpenTofu key fails (or, with gpgErr set, cannot be fetched) and the
+// cosign path is exercised.
+type fakeCosignReleasesClient struct {
+	fakeReleasesClient
+	sig, cert []byte
+	gpgErr    error
+}
+
+func (f *fakeCosignReleasesClient) FetchSHASumsSignature(_ context.Context, _ string) ([]byte, error) {
+	return nil, f.gpgErr
+}
+
+func (f *fakeCosignReleasesClient) FetchSHASumsCosignSignature(_ context.Context, _ string) ([]byte, []byte, error) {
+	return f.sig, f.cert, nil
+}
+
+var _ cosignSumsFetcher = (*fakeCosignReleasesClient)(nil)
+
+func stubVerifyCosignBlob(t *testing.T, err error) *int {
+	t.Helper()
+	calls := 0
+	orig := verifyCosignBlob
+	verifyCosignBlob = func(_, _, _ []byte, _ mirror.CosignIdentity) error {
+		calls++
+		return err
+	}
+	t.Cleanup(func() { verifyCosignBlob = orig })
+	return &calls
+}
+
+func TestVerifyVersionSums_OpenTofuCosignFallback(t *testing.T) {
+	calls := stubVerifyCosignBlob(t, nil)
+	cfg := &models.TerraformMirrorConfig{Name: "tofu", Tool: "opentofu", GPGVerify: true}
+	client := &fakeCosignReleasesClient{sig: []byte("sig"), cert: []byte("cert")}
+
+	method, gpgSig, err := verifyVersionSums(context.Background(), client, cfg, "1.8.0", []byte("sums"))
+	if err != nil {
+		t.Fatalf("verifyVersionSums: %v", err)
+	}
+	if method != signatureMethodCosign {
+		t.Errorf("method = %q, want %q", method, signatureMethodCosign)
+	}
+	if gpgSig != nil {
+		t.Errorf("gpgSig = %q, want nil for a cosign verification", gpgSig)
+	}
+	if *calls != 1 {
+		t.Errorf("verifyCosignBlob called %d times, want 1", *calls)
+	}
+}
+
+func TestVerifyVersionSums_OpenTofuBothFail(t *testing.T) {
+	stubVerifyCosignBlob(t, errors.New("identity mismatch"))
+	cfg := &models.TerraformMirrorConfig{Name: "tofu", Tool: "opentofu", GPGVerify: true}
+	client := &fakeCosignReleasesClient{sig: []byte("sig"), cert: []byte("cert")}
+
+	method, _, err := verifyVersionSums(context.Background(), client, cfg, "1.8.0", []byte("sums"))
+	if err == nil {
+		t.Fatal("expected an error when GPG and cosign both fail")
+	}
+	if method != "" {
+		t.Errorf("method = %q, want empty", method)
+	}
+	if !strings.Contains(err.Error(), "cosign: identity mismatch") || !strings.Contains(err.Error(), "GPG:") {
+		t.Errorf("error %q should name both failed methods", err)
+	}
+}
+
+func TestVerifyVersionSums_OpenTofuCosignUnavailable(t *testing.T) {
+	stubVerifyCosignBlob(t, mirror.ErrCosignUnavailable)
+	cfg := &models.TerraformMirrorConfig{Name: "tofu", Tool: "opentofu", GPGVerify: true}
+	client := &fakeCosignReleasesClient{
+		sig: []byte("sig"), cert: []byte("cert"),
+		gpgErr: errors.New("release has no SHA256SUMS.gpgsig asset"),
+	}
+
+	// Neither method could run: the file stays unverified but the version
+	// is not failed.
+	method, _, err := verifyVersionSums(context.Background(), client, cfg, "1.8.0", []byte("sums"))
+	if err != nil || method != "" {
+		t.Errorf("got (%q, %v), want (\"\", nil)", method, err)
+	}
+}
+
+func TestVerifyVersionSums_SkipGPGVerify(t *testing.T) {
+	calls := stubVerifyCosignBlob(t, nil)
+	cfg := &models.TerraformMirrorConfig{Name: "tofu", Tool: "opentofu", GPGVerify: true, SkipGPGVerify: true}
+	client := &fakeCosignReleasesClient{sig: []byte("sig"), cert: []byte("cert")}
+
+	method, _, err := verifyVersionSums(context.Background(), client, cfg, "1.8.0", []byte("sums"))
+	if err != nil || method != "" {
+		t.Errorf("got (%q, %v), want (\"\", nil)", method, err)
+	}
+	if *calls != 0 {
+		t.Errorf("verifyCosignBlob called %d times, want 0", *calls)
+	}
+}
+
+func TestVerifyVersionSums_NoCosignIdentity(t *testing.T) {
+	calls := stubVerifyCosignBlob(t, nil)
+	cfg := &models.TerraformMirrorConfig{Name: "custom", Tool: "custom", GPGVerify: true}
+	client := &fakeCosignReleasesClient{sig: []byte("sig"), cert: []byte("cert")}
+
+	method, _, err := verifyVersionSums(context.Background(), client, cfg, "1.0.0", []byte("sums"))
+	if err != nil || method != "" {
+		t.Errorf("got (%q, %v), want (\"\", nil) for a tool without cosign", method, err)
+	}
+	if *calls != 0 {
+		t.Errorf("verifyCosignBlob called %d times, want 0", *calls)
+	}
+}
@@ -26,6 +26,7 @@ type WebhookRetryJob struct {
 	publisher   *services.SCMPublisher
 	tokenCipher *crypto.TokenCipher
 	stopChan    chan struct{}
+	scheduled
 }
 
 // NewWebhookRetryJob constructs a WebhookRetryJob.
@@ -66,22 +67,9 @@ func (j *WebhookRetryJob) Start(ctx context.Context) error {
 
 	slog.Info("webhook retry job: started", "max_retries", j.cfg.MaxRetries, "interval", interval)
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	// Run once immediately before entering the ticker loop.
-	j.runRetryCycle(ctx)
-
-	for {
-		select {
-		case <-ticker.C:
-			j.runRetryCycle(ctx)
-		case <-j.stopChan:
-			return nil
-		case <-ctx.Done():
-			return nil
-		}
-	}
+	// Runs once immediately, then on the interval.
+	j.scheduler().Run(ctx, j.Name(), Schedule{Interval: interval}, j.stopChan, j.runRetryCycle)
+	return nil
 }
 
 // Stop signals the job to exit gracefully.
@@ -130,27 +118,9 @@ func (j *WebhookRetryJob) retryOne(ctx context.Context, event *scm.SCMWebhookLog
 		return
 	}
 
-	// Decrypt the provider's client secret.
-	clientSecret, err := j.tokenCipher.Open(provider.ClientSecretEncrypted)
-	if err != nil {
-		j.failRetry(ctx, event, fmt.Sprintf("failed to decrypt client secret: %v", err))
-		return
-	}
-
-	// Build connector.
-	baseURL := ""
-	if provider.BaseURL != nil {
-		baseURL = *provider.BaseURL
-	}
-	connector, err := scm.BuildConnector(&scm.ConnectorSettings{
-		Kind:            provider.ProviderType,
-		InstanceBaseURL: baseURL,
-		ClientID:        provider.ClientID,
-		ClientSecret:    clientSecret,
-		CallbackURL:     "",
-	})
+	connector, err := buildProviderConnector(j.tokenCipher, provider)
 	if err != nil {
-		j.failRetry(ctx, event, fmt.Sprintf("failed to build connector: %v", err))
+		j.failRetry(ctx, event, err.Error())
 		return
 	}
 
@@ -101,6 +101,8 @@ func AuditMiddlewareWithShipper(auditRepo *repositories.AuditRepository, shipper
 		if resourceType == "mirror" {
 			if strings.Contains(c.Request.URL.Path, "/sync") {
 				action = "mirror.sync_triggered"
+			} else if strings.HasSuffix(c.Request.URL.Path, "/lock") {
+				action = "mirror.sync_lock_cleared"
 			} else if c.Request.Method == "POST" {
 				action = "mirror.created"
 			} else if c.Request.Method == "PUT" {
@@ -110,6 +112,15 @@ func AuditMiddlewareWithShipper(auditRepo *repositories.AuditRepository, shipper
 			}
 			auditLog.Action = action
 		}
+		// Namespace landing-page edits get a stable action name so they can be
+		// filtered without matching on the namespace in the path.
+		if resourceType == "namespace" && c.Request.Method == "PUT" && strings.HasSuffix(c.FullPath(), "/metadata") {
+			auditLog.Action = "namespace.metadata_updated"
+		}
+		// Tier changes likewise, as provider.tier_changed / module.tier_changed.
+		if (resourceType == "provider" || resourceType == "module") && c.Request.Method == "PATCH" && strings.HasSuffix(c.FullPath(), "/tier") {
+			auditLog.Action = resourceType + ".tier_changed"
+		}
 
 		// Extract metadata from context if available
 		metadata := make(map[string]interface{})
@@ -118,6 +129,18 @@ func AuditMiddlewareWithShipper(auditRepo *repositories.AuditRepository, shipper
 			metadata["auth_method"] = authMethod
 		}
 		metadata["status_code"] = c.Writer.Status()
+		if resourceType == "namespace" {
+			metadata["namespace"] = c.Param("namespace")
+		}
+		// CI publish tokens carry no user; record the CI identity instead so
+		// the trail shows which repository and ref performed the change.
+		if ci, ok := CITokenFromContext(c); ok {
+			metadata["trust_rule_id"] = ci.TrustRuleID
+			metadata["ci_subject"] = ci.CISubject
+			metadata["ci_repository"] = ci.CIRepository
+			metadata["ci_ref"] = ci.CIRef
+			metadata["ci_jti"] = ci.ID
+		}
 
 		if len(metadata) > 0 {
 			auditLog.Metadata = metadata
@@ -187,6 +210,8 @@ func getResourceType(c *gin.Context) string {
 		return "organization"
 	case strings.HasPrefix(fullPath, "/api/v1/admin/storage"):
 		return "storage"
+	case strings.HasPrefix(fullPath, "/api/v1/namespaces"):
+		return "namespace"
 	case strings.HasPrefix(fullPath, "/api/v1/admin/roles"):
 		return "role"
 	case strings.HasPrefix(fullPath, "/api/v1/admin/scm-providers"):
@@ -176,6 +176,7 @@ func TestAuditMiddleware_ResourceTypeDetection(t *testing.T) {
 		{"/api/v1/organizations/some-id", "organization"},
 		{"/api/v1/organizations/some-id/members", "organization"},
 		{"/api/v1/admin/mirrors/y", "mirror"},
+		{"/api/v1/namespaces/acme/claim", "namespace"},
 		// The hosted binary-mirror admin API (mounted under /admin/terraform-mirror*)
 		// and the forward-compat /admin/binary-mirror prefix both audit as "binary_mirror".
 		{"/api/v1/admin/terraform-mirrors/some-id", "binary_mirror"},
@@ -202,6 +203,25 @@ func TestAuditMiddleware_ResourceTypeDetection(t *testing.T) {
 	}
 }
 
+func TestAuditMiddleware_NamespaceMetadataAction(t *testing.T) {
+	cs := newCaptureShipper(1)
+	r := gin.New()
+	r.Use(AuditMiddlewareWithShipper(nil, cs, nil))
+	r.PUT("/api/v1/namespaces/:namespace/metadata", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/namespaces/acme/metadata", nil)
+	r.ServeHTTP(w, req)
+
+	entry := cs.waitForEntry(t, 500*time.Millisecond)
+	if entry.Action != "namespace.metadata_updated" || entry.ResourceType != "namespace" {
+		t.Errorf("entry = (%q, %q), want (namespace.metadata_updated, namespace)", entry.Action, entry.ResourceType)
+	}
+	if entry.Metadata["namespace"] != "acme" {
+		t.Errorf("metadata namespace = %v, want acme", entry.Metadata["namespace"])
+	}
+}
+
 func TestAuditMiddleware_ContextValuesExtracted(t *testing.T) {
 	cs := newCaptureShipper(1)
 	r := gin.New()
@@ -283,3 +303,19 @@ func TestAuditMiddleware_BackwardCompat(t *testing.T) {
 		t.Errorf("status = %d, want 200", w.Code)
 	}
 }
+
+func TestAuditMiddleware_MirrorSyncLockAction(t *testing.T) {
+	cs := newCaptureShipper(1)
+	r := gin.New()
+	r.Use(AuditMiddlewareWithShipper(nil, cs, nil))
+	r.DELETE("/api/v1/admin/mirrors/:id/lock", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/mirrors/abc/lock", nil)
+	r.ServeHTTP(w, req)
+
+	entry := cs.waitForEntry(t, 500*time.Millisecond)
+	if entry.Action != "mirror.sync_lock_cleared" || entry.ResourceType != "mirror" {
+		t.Errorf("entry = (%q, %q), want (mirror.sync_lock_cleared, mirror)", entry.Action, entry.ResourceType)
+	}
+}
@@ -26,7 +26,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/safego"
 )
 
-// AuthMiddleware validates authentication (JWT or API key).
+// AuthMiddleware validates authentication (JWT, CI or CLI token, or API key).
 //
 // Token resolution order:
 //  1. Authorization: Bearer <token> header — tried as JWT first, then API key.
@@ -43,6 +43,7 @@ func AuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, a
 		authHeader := c.GetHeader("Authorization")
 		if authHeader != "" {
 			if !strings.HasPrefix(authHeader, "Bearer ") {
+				recordAuthFailure(authFailureMalformed, credentialUnknown)
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 					"error": "Authorization header must start with 'Bearer '",
 				})
@@ -76,7 +77,8 @@ func AuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, a
 		}
 
 		// Try JWT first
-		if claims, err := auth.ValidateJWT(token); err == nil {
+		claims, jwtErr := auth.ValidateJWT(token)
+		if jwtErr == nil {
 			// Check if the token has been revoked
 			if claims.JTI != "" && tokenRepo != nil {
 				if revoked, rErr := tokenRepo.IsTokenRevoked(c.Request.Context(), claims.JTI); rErr != nil {
@@ -85,6 +87,7 @@ func AuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, a
 					})
 					return
 				} else if revoked {
+					recordAuthFailure(authFailureRevoked, credentialJWT)
 					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 						"error": "Token has been revoked",
 					})
@@ -104,6 +107,7 @@ func AuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, a
 					})
 					return
 				} else if revoked {
+					recordAuthFailure(authFailureRevoked, credentialJWT)
 					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 						"error": "Token has been revoked",
 					})
@@ -121,6 +125,7 @@ func AuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, a
 			}
 
 			if user == nil {
+				recordAuthFailure(authFailureRevoked, credentialJWT)
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 					"error": "User not found",
 				})
@@ -144,6 +149,13 @@ func AuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, a
 			}
 			c.Set("scopes", scopes)
 
+			if err := setOrganizationContext(c, orgRepo, user.ID, "", claims.OrgID); err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to resolve organization context",
+				})
+				return
+			}
+
 			c.Next()
 			return
 		}
@@ -156,9 +168,80 @@ func AuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, a
 		// If the token came from a cookie, it can only be a JWT (API keys are never
 		// stored in cookies). Don't try API key auth for cookie tokens.
 		if fromCookie {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authentication cookie",
-			})
+			reason, credentialType := classifyRejectedToken(token, jwtErr, nil, nil)
+			recordAuthFailure(reason, credentialType)
+			abortRejectedToken(c, "Invalid authentication cookie", jwtErr, nil, nil)
+			return
+		}
+
+		// CI publish tokens minted by the OIDC token exchange carry no user;
+		// they are bound to one namespace and organization instead.
+		ciClaims, ciErr := auth.ValidateCIToken(token)
+		if ciErr == nil {
+			if ciClaims.ID != "" && tokenRepo != nil {
+				if revoked, rErr := tokenRepo.IsTokenRevoked(c.Request.Context(), ciClaims.ID); rErr != nil {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+						"error": "Auth check failed",
+					})
+					return
+				} else if revoked {
+					recordAuthFailure(authFailureRevoked, credentialCIToken)
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+						"error": "Token has been revoked",
+					})
+					return
+				}
+			}
+			setCITokenContext(c, ciClaims)
+			c.Next()
+			return
+		}
+
+		// Terraform CLI tokens authenticate a user, but only for protocol
+		// reads on the groups marked with AllowCLITokens.
+		cliClaims, cliErr := auth.ValidateCLIToken(token)
+		if cliErr == nil {
+			if !cliTokenPermitted(c) {
+				recordAuthFailure(authFailureWrongScope, credentialCLIToken)
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": cliTokenMisuseMessage,
+				})
+				return
+			}
+			if revoked, rErr := cliTokenRevoked(c.Request.Context(), cliClaims, tokenRepo, userRevocations); rErr != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Auth check failed",
+				})
+				return
+			} else if revoked {
+				recordAuthFailure(authFailureRevoked, credentialCLIToken)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "Token has been revoked",
+				})
+				return
+			}
+			user, err := userRepo.GetUserByID(c.Request.Context(), cliClaims.UserID())
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to load user",
+				})
+				return
+			}
+			if user == nil {
+				recordAuthFailure(authFailureRevoked, credentialCLIToken)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "User not found",
+				})
+				return
+			}
+			setCLITokenContext(c, cliClaims, user)
+			if err := setOrganizationContext(c, orgRepo, user.ID, "", ""); err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to resolve organization context",
+				})
+				return
+			}
+			c.Next()
 			return
 		}
 
@@ -184,6 +267,7 @@ func AuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, a
 		if apiKey != nil {
 			// Check expiration
 			if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+				recordAuthFailure(authFailureExpired, credentialAPIKey)
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 					"error": "API key expired",
 				})
@@ -209,25 +293,53 @@ func AuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, a
 			c.Set("scopes", apiKey.Scopes)
 
 			// Load user if exists
+			var keyUserID string
 			if apiKey.UserID != nil {
 				user, _ := userRepo.GetUserByID(c.Request.Context(), *apiKey.UserID)
 				if user != nil {
 					c.Set("user", user)
 					c.Set("user_id", user.ID)
+					keyUserID = user.ID
 				}
 			}
 
+			// The role comes from the key owner's membership in the key's
+			// organization.
+			if err := setOrganizationContext(c, orgRepo, keyUserID, apiKey.OrganizationID, ""); err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to resolve organization context",
+				})
+				return
+			}
+
 			c.Next()
 			return
 		}
 
 		// Neither JWT nor API key worked
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid credentials",
-		})
+		reason, credentialType := classifyRejectedToken(token, jwtErr, ciErr, cliErr)
+		recordAuthFailure(reason, credentialType)
+		abortRejectedToken(c, "Invalid credentials", jwtErr, ciErr, cliErr)
 	}
 }
 
+// abortRejectedToken answers 401 for a bearer token no validator accepted.
+// A genuine token rejected for its exp or nbf claim gets the reason
+// (token_expired or token_not_yet_valid) and the server's clock instead of the
+// generic message, so client tooling can tell clock skew from expiry.
+func abortRejectedToken(c *gin.Context, message string, jwtErr, ciErr, cliErr error) {
+	body := gin.H{"error": message}
+	if timeErr, _ := rejectedTokenTimeError(jwtErr, ciErr, cliErr); timeErr != nil {
+		body["error"] = "Token has expired"
+		if timeErr.Reason == auth.TokenNotYetValid {
+			body["error"] = "Token is not valid yet"
+		}
+		body["reason"] = timeErr.Reason
+		body["server_time"] = time.Now().UTC().Format(time.RFC3339)
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, body)
+}
+
 // OptionalAuthMiddleware - same as AuthMiddleware but doesn't abort if no auth
 func OptionalAuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepository, apiKeyRepo *repositories.APIKeyRepository, orgRepo *repositories.OrganizationRepository, tokenRepo *repositories.TokenRepository, userRevocations *repositories.UserTokenRevocationRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -286,6 +398,14 @@ func OptionalAuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepos
 						scopes = []string{}
 					}
 					c.Set("scopes", scopes)
+					// Unlike a revoked token, a failed lookup aborts: continuing
+					// anonymously would skip the caller's organization policies.
+					if err := setOrganizationContext(c, orgRepo, user.ID, "", claims.OrgID); err != nil {
+						c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+							"error": "Failed to resolve organization context",
+						})
+						return
+					}
 				}
 			}
 			c.Next()
@@ -298,6 +418,33 @@ func OptionalAuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepos
 			return
 		}
 
+		// Terraform CLI tokens: a revoked token or missing user downgrades to
+		// unauthenticated like a revoked JWT above, but a CLI token presented
+		// outside the protocol routes is rejected outright so the caller
+		// learns why it does not work there.
+		if cliClaims, err := auth.ValidateCLIToken(token); err == nil {
+			if !cliTokenPermitted(c) {
+				recordAuthFailure(authFailureWrongScope, credentialCLIToken)
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": cliTokenMisuseMessage,
+				})
+				return
+			}
+			if revoked, rErr := cliTokenRevoked(c.Request.Context(), cliClaims, tokenRepo, userRevocations); rErr == nil && !revoked {
+				if user, err := userRepo.GetUserByID(c.Request.Context(), cliClaims.UserID()); err == nil && user != nil {
+					setCLITokenContext(c, cliClaims, user)
+					if err := setOrganizationContext(c, orgRepo, user.ID, "", ""); err != nil {
+						c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+							"error": "Failed to resolve organization context",
+						})
+						return
+					}
+				}
+			}
+			c.Next()
+			return
+		}
+
 		// Try API key
 		keyPrefix := token
 		if len(token) > 10 {
@@ -323,13 +470,21 @@ func OptionalAuthMiddleware(cfg *config.Config, userRepo *repositories.UserRepos
 				c.Set("scopes", apiKey.Scopes)
 
 				// Load user if exists
+				var keyUserID string
 				if apiKey.UserID != nil {
 					user, _ := userRepo.GetUserByID(c.Request.Context(), *apiKey.UserID)
 					if user != nil {
 						c.Set("user", user)
 						c.Set("user_id", user.ID)
+						keyUserID = user.ID
 					}
 				}
+				if err := setOrganizationContext(c, orgRepo, keyUserID, apiKey.OrganizationID, ""); err != nil {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+						"error": "Failed to resolve organization context",
+					})
+					return
+				}
 			}
 		}
 
@@ -0,0 +1,101 @@
+// auth_metrics.go records authentication and authorization failures on the
+// tfr_auth_failures_total counter.
+//
+// Both labels are drawn from the fixed sets below and never from request data,
+// so no token, key, key prefix or user identifier can reach a label value.
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+)
+
+// Auth failure reasons (the reason label).
+const (
+	authFailureExpired     = "expired"       // the token or API key is past its expiry
+	authFailureNotYetValid = "not_yet_valid" // the token's nbf is in the future, beyond the clock skew leeway
+	authFailureMalformed   = "malformed"     // the credential could not be parsed or its signature did not verify
+	authFailureRevoked     = "revoked"       // the token was revoked, or its user no longer exists
+	authFailureUnknownKey  = "unknown_key"   // no API key matches the presented value
+	authFailureWrongScope  = "wrong_scope"   // the credential is valid but lacks the scope or namespace required
+)
+
+// Credential types (the credential_type label).
+const (
+	credentialJWT      = "jwt"
+	credentialAPIKey   = "api_key"
+	credentialCLIToken = "cli_token"
+	credentialCIToken  = "ci_token"
+	credentialMTLS     = "mtls"
+	credentialUnknown  = "unknown"
+)
+
+// recordAuthFailure increments tfr_auth_failures_total. Callers pass only the
+// constants above.
+func recordAuthFailure(reason, credentialType string) {
+	telemetry.AuthFailuresTotal.WithLabelValues(reason, credentialType).Inc()
+}
+
+// recordScopeFailure records a wrong_scope failure for the credential the
+// request authenticated with.
+func recordScopeFailure(c *gin.Context) {
+	recordAuthFailure(authFailureWrongScope, credentialTypeFromContext(c))
+}
+
+// credentialTypeFromContext maps the auth_method set by the auth middlewares
+// to a credential_type label.
+func credentialTypeFromContext(c *gin.Context) string {
+	switch c.GetString("auth_method") {
+	case "jwt", "jwt_cookie":
+		return credentialJWT
+	case "api_key":
+		return credentialAPIKey
+	case AuthMethodCLIToken:
+		return credentialCLIToken
+	case AuthMethodOIDCCI:
+		return credentialCIToken
+	case "mtls":
+		return credentialMTLS
+	default:
+		return credentialUnknown
+	}
+}
+
+// classifyRejectedToken labels a bearer token that no validator accepted.
+// JWT-shaped tokens are expired or not yet valid when one of the token
+// validators reported a time-claim failure (signatures are verified before
+// the time claims, so the token was genuine) and malformed otherwise; anything
+// else was looked up as an API key.
+func classifyRejectedToken(token string, jwtErr, ciErr, cliErr error) (reason, credentialType string) {
+	if timeErr, credentialType := rejectedTokenTimeError(jwtErr, ciErr, cliErr); timeErr != nil {
+		if timeErr.Reason == auth.TokenNotYetValid {
+			return authFailureNotYetValid, credentialType
+		}
+		return authFailureExpired, credentialType
+	}
+	switch {
+	case strings.Count(token, ".") == 2:
+		return authFailureMalformed, credentialJWT
+	default:
+		return authFailureUnknownKey, credentialAPIKey
+	}
+}
+
+// rejectedTokenTimeError returns the time-claim failure among the validator
+// errors, if any, with the credential type of the validator that reported it.
+func rejectedTokenTimeError(jwtErr, ciErr, cliErr error) (*auth.TokenTimeError, string) {
+	var timeErr *auth.TokenTimeError
+	switch {
+	case errors.As(jwtErr, &timeErr):
+		return timeErr, credentialJWT
+	case errors.As(ciErr, &timeErr):
+		return timeErr, credentialCIToken
+	case errors.As(cliErr, &timeErr):
+		return timeErr, credentialCLIToken
+	}
+	return nil, ""
+}
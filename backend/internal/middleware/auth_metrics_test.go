@@ -0,0 +1,257 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// authFailureDelta runs fn and returns how much tfr_auth_failures_total grew
+// for {reason, credentialType}.
+func authFailureDelta(reason, credentialType string, fn func()) float64 {
+	counter := telemetry.AuthFailuresTotal.WithLabelValues(reason, credentialType)
+	before := testutil.ToFloat64(counter)
+	fn()
+	return testutil.ToFloat64(counter) - before
+}
+
+func TestAuthMiddleware_FailureReasons(t *testing.T) {
+	expiredJWT, err := auth.GenerateJWT("user-1", "test@example.com", nil, -time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	expiredKey := "tfr_expired_key"
+	hash, _ := bcrypt.GenerateFromPassword([]byte(expiredKey), bcrypt.MinCost)
+	expiredAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		header         string
+		keyRows        *sqlmock.Rows // nil: no API key lookup expected
+		wantReason     string
+		wantCredential string
+	}{
+		{
+			name:           "non-bearer header",
+			header:         "Basic dXNlcjpwYXNz",
+			wantReason:     authFailureMalformed,
+			wantCredential: credentialUnknown,
+		},
+		{
+			name:           "garbled JWT",
+			header:         "Bearer aaa.bbb.ccc",
+			keyRows:        sqlmock.NewRows(apiKeyPrefixCols),
+			wantReason:     authFailureMalformed,
+			wantCredential: credentialJWT,
+		},
+		{
+			name:           "expired JWT",
+			header:         "Bearer " + expiredJWT,
+			keyRows:        sqlmock.NewRows(apiKeyPrefixCols),
+			wantReason:     authFailureExpired,
+			wantCredential: credentialJWT,
+		},
+		{
+			name:           "unknown API key",
+			header:         "Bearer tfr_no_such_key_123",
+			keyRows:        sqlmock.NewRows(apiKeyPrefixCols),
+			wantReason:     authFailureUnknownKey,
+			wantCredential: credentialAPIKey,
+		},
+		{
+			name:   "expired API key",
+			header: "Bearer " + expiredKey,
+			keyRows: sqlmock.NewRows(apiKeyPrefixCols).AddRow(
+				"key-1", "user-1", "org-1", "Test Key", nil, string(hash), "tfr_expire",
+				[]byte(`["read"]`), &expiredAt, nil, nil, time.Now(),
+			),
+			wantReason:     authFailureExpired,
+			wantCredential: credentialAPIKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, r := newAuthRouterWithRepos(t)
+			if tt.keyRows != nil {
+				mock.ExpectQuery("SELECT.*FROM api_keys.*WHERE.*key_prefix").WillReturnRows(tt.keyRows)
+			}
+			var code int
+			delta := authFailureDelta(tt.wantReason, tt.wantCredential, func() {
+				code = doAuthRequest(r, tt.header)
+			})
+			if code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", code)
+			}
+			if delta != 1 {
+				t.Errorf("tfr_auth_failures_total{reason=%q,credential_type=%q} grew by %v, want 1", tt.wantReason, tt.wantCredential, delta)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_RevokedJWTFailureReason(t *testing.T) {
+	userRepo, userMock := newUserRepo(t)
+	orgRepo, _ := newOrgRepo(t)
+	tokenRepo, tokenMock := newTokenRepo(t)
+	tokenMock.ExpectQuery("SELECT EXISTS").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	r := newAuthRouterWithRevocation(t, userRepo, userMock, orgRepo, tokenRepo)
+	token := generateTestJWT(t, "user-1")
+
+	delta := authFailureDelta(authFailureRevoked, credentialJWT, func() {
+		if code := doAuthRequest(r, "Bearer "+token); code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want 401", code)
+		}
+	})
+	if delta != 1 {
+		t.Errorf("revoked JWT: counter grew by %v, want 1", delta)
+	}
+}
+
+func TestRequireScope_WrongScopeFailureReason(t *testing.T) {
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		c.Set("auth_method", "api_key")
+		c.Set("scopes", []string{"modules:read"})
+	}, RequireScope(auth.ScopeAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	delta := authFailureDelta(authFailureWrongScope, credentialAPIKey, func() {
+		if w := do(r); w.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", w.Code)
+		}
+	})
+	if delta != 1 {
+		t.Errorf("wrong scope: counter grew by %v, want 1", delta)
+	}
+}
+
+func TestCITokenNamespaceCheck_WrongScopeFailureReason(t *testing.T) {
+	c, _ := gin.CreateTestContext(nil)
+	setCITokenContext(c, &auth.CITokenClaims{Namespace: "team-a", OrganizationID: "org-1"})
+
+	delta := authFailureDelta(authFailureWrongScope, credentialCIToken, func() {
+		if status, _ := ciTokenNamespaceCheck(c, "team-b"); status != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", status)
+		}
+	})
+	if delta != 1 {
+		t.Errorf("CI namespace mismatch: counter grew by %v, want 1", delta)
+	}
+}
+
+// TestAuthFailures_LabelsCarryNoSecrets checks every recorded label value is
+// one of the fixed reasons or credential types, never request material.
+func TestAuthFailures_LabelsCarryNoSecrets(t *testing.T) {
+	const secret = "tfr_secret_material_42"
+	mock, r := newAuthRouterWithRepos(t)
+	mock.ExpectQuery("SELECT.*FROM api_keys.*WHERE.*key_prefix").WillReturnRows(sqlmock.NewRows(apiKeyPrefixCols))
+	doAuthRequest(r, "Bearer "+secret)
+
+	allowed := map[string]bool{
+		authFailureExpired: true, authFailureMalformed: true, authFailureRevoked: true,
+		authFailureUnknownKey: true, authFailureWrongScope: true,
+		credentialJWT: true, credentialAPIKey: true, credentialCLIToken: true,
+		credentialCIToken: true, credentialMTLS: true, credentialUnknown: true,
+	}
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	found := false
+	for _, mf := range families {
+		if mf.GetName() != "tfr_auth_failures_total" {
+			continue
+		}
+		found = true
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if !allowed[lp.GetValue()] || strings.Contains(lp.GetValue(), secret[:10]) {
+					t.Errorf("label %s=%q is not a fixed label value", lp.GetName(), lp.GetValue())
+				}
+			}
+		}
+	}
+	if !found {
+		t.Error("tfr_auth_failures_total was not gathered")
+	}
+}
+
+// skewedSessionJWT signs a session JWT valid from nbf to exp, as issued by a
+// host whose clock is off.
+func skewedSessionJWT(t *testing.T, nbf, exp time.Time) string {
+	t.Helper()
+	claims := &auth.Claims{
+		UserID: "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "terraform-registry",
+			Audience:  jwt.ClaimStrings{"terraform-registry"},
+			IssuedAt:  jwt.NewNumericDate(nbf),
+			NotBefore: jwt.NewNumericDate(nbf),
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(auth.GetJWTSecret()))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return token
+}
+
+func TestAuthMiddleware_ClockSkewedJWT(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		token      string
+		wantReason string
+		wantMetric string
+	}{
+		{"issued by a clock running ahead", skewedSessionJWT(t, now.Add(5*time.Minute), now.Add(time.Hour)), auth.TokenNotYetValid, authFailureNotYetValid},
+		{"expired", skewedSessionJWT(t, now.Add(-time.Hour), now.Add(-5*time.Minute)), auth.TokenExpired, authFailureExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, r := newAuthRouterWithRepos(t)
+			mock.ExpectQuery("SELECT.*FROM api_keys.*WHERE.*key_prefix").WillReturnRows(sqlmock.NewRows(apiKeyPrefixCols))
+
+			w := httptest.NewRecorder()
+			delta := authFailureDelta(tt.wantMetric, credentialJWT, func() {
+				req, _ := http.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+				r.ServeHTTP(w, req)
+			})
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", w.Code)
+			}
+			var body struct {
+				Reason     string `json:"reason"`
+				ServerTime string `json:"server_time"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", body.Reason, tt.wantReason)
+			}
+			if serverTime, err := time.Parse(time.RFC3339, body.ServerTime); err != nil || time.Since(serverTime) > time.Minute {
+				t.Errorf("server_time = %q, want the current time", body.ServerTime)
+			}
+			if delta != 1 {
+				t.Errorf("tfr_auth_failures_total{reason=%q} grew by %v, want 1", tt.wantMetric, delta)
+			}
+		})
+	}
+}
@@ -134,7 +134,7 @@ func TestAuthMiddleware_CookieAuth_InvalidJWT(t *testing.T) {
 
 func TestAuthMiddleware_CookieAuth_ValidJWT(t *testing.T) {
 	userRepo, userMock := newUserRepo(t)
-	orgRepo, _ := newOrgRepo(t)
+	orgRepo, orgMock := newOrgRepo(t)
 
 	r := gin.New()
 	var capturedAuthMethod string
@@ -153,6 +153,8 @@ func TestAuthMiddleware_CookieAuth_ValidJWT(t *testing.T) {
 	userMock.ExpectQuery("SELECT.*FROM users WHERE id").
 		WillReturnRows(sqlmock.NewRows(jwtUserCols).
 			AddRow("user-123", "test@example.com", "Test", "sub-123", time.Now(), time.Now()))
+	orgMock.ExpectQuery("SELECT.*FROM organization_members").
+		WillReturnRows(sqlmock.NewRows(jwtMembershipCols))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -169,7 +171,7 @@ func TestAuthMiddleware_CookieAuth_ValidJWT(t *testing.T) {
 
 func TestAuthMiddleware_HeaderTakesPrecedenceOverCookie(t *testing.T) {
 	userRepo, userMock := newUserRepo(t)
-	orgRepo, _ := newOrgRepo(t)
+	orgRepo, orgMock := newOrgRepo(t)
 
 	r := gin.New()
 	var capturedAuthMethod string
@@ -187,6 +189,8 @@ func TestAuthMiddleware_HeaderTakesPrecedenceOverCookie(t *testing.T) {
 	userMock.ExpectQuery("SELECT.*FROM users WHERE id").
 		WillReturnRows(sqlmock.NewRows(jwtUserCols).
 			AddRow("user-456", "test@example.com", "Test", "sub-456", time.Now(), time.Now()))
+	orgMock.ExpectQuery("SELECT.*FROM organization_members").
+		WillReturnRows(sqlmock.NewRows(jwtMembershipCols))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -857,7 +861,7 @@ func TestAuthMiddleware_RevokeAllWatermark_DBError(t *testing.T) {
 
 func TestAuthMiddleware_RevokeAllWatermark_NotRevoked_PassesThrough(t *testing.T) {
 	userRepo, userMock := newUserRepo(t)
-	orgRepo, _ := newOrgRepo(t)
+	orgRepo, orgMock := newOrgRepo(t)
 	userRevocations, revMock := newUserRevocationRepo(t)
 
 	token := generateTestJWT(t, "user-1")
@@ -867,6 +871,8 @@ func TestAuthMiddleware_RevokeAllWatermark_NotRevoked_PassesThrough(t *testing.T
 	userMock.ExpectQuery("SELECT.*FROM users WHERE id").
 		WillReturnRows(sqlmock.NewRows(jwtUserCols).AddRow(
 			"user-1", "test@example.com", "Test User", nil, time.Now(), time.Now()))
+	orgMock.ExpectQuery("SELECT.*FROM organization_members").
+		WillReturnRows(sqlmock.NewRows(jwtMembershipCols))
 
 	r := gin.New()
 	r.Use(AuthMiddleware(nil, userRepo, nil, orgRepo, nil, userRevocations))
@@ -910,3 +916,39 @@ func TestOptionalAuthMiddleware_RevokeAllWatermark_ContinuesUnauthenticated(t *t
 }
 
 // ---------------------------------------------------------------------------
+
+// ---------------------------------------------------------------------------
+// AuthMiddleware — CI publish tokens
+// ---------------------------------------------------------------------------
+
+func TestAuthMiddleware_CIToken_SetsCIContext(t *testing.T) {
+	token, err := auth.GenerateCIToken(&auth.CITokenClaims{
+		OrganizationID: "org-1",
+		Namespace:      "acme",
+		Scopes:         []string{string(auth.ScopeModulesWrite)},
+		TrustRuleID:    "rule-1",
+	}, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateCIToken: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(AuthMiddleware(nil, nil, nil, nil, nil, nil))
+	r.GET("/", func(c *gin.Context) {
+		ci, ok := CITokenFromContext(c)
+		if !ok || ci.Namespace != "acme" {
+			t.Errorf("CI claims missing from context: %+v", ci)
+		}
+		if _, hasUser := c.Get("user_id"); hasUser {
+			t.Error("a CI token must not set a user")
+		}
+		if c.GetString("auth_method") != AuthMethodOIDCCI || c.GetString("organization_id") != "org-1" {
+			t.Errorf("auth_method=%q organization_id=%q", c.GetString("auth_method"), c.GetString("organization_id"))
+		}
+		c.Status(http.StatusOK)
+	})
+
+	if code := doAuthRequest(r, "Bearer "+token); code != http.StatusOK {
+		t.Errorf("status = %d, want 200", code)
+	}
+}
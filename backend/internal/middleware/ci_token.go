@@ -0,0 +1,55 @@
+// Package middleware (ci_token.go) holds the request-context plumbing for CI
+// publish tokens issued by the OIDC token exchange. AuthMiddleware accepts
+// them; NamespaceAuthorizer pins them to their namespace and organization.
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+)
+
+// AuthMethodOIDCCI is the auth_method recorded for CI publish tokens.
+const AuthMethodOIDCCI = "oidc_ci"
+
+// ciTokenContextKey holds the *auth.CITokenClaims of a CI-authenticated request.
+const ciTokenContextKey = "ci_token"
+
+// setCITokenContext populates the request context for a validated CI token.
+// No user is set: downstream code that records a publisher sees none.
+func setCITokenContext(c *gin.Context, claims *auth.CITokenClaims) {
+	scopes := claims.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	c.Set(ciTokenContextKey, claims)
+	c.Set("auth_method", AuthMethodOIDCCI)
+	c.Set("organization_id", claims.OrganizationID)
+	c.Set(OrganizationIDsContextKey, []string{claims.OrganizationID})
+	c.Set("scopes", scopes)
+}
+
+// CITokenFromContext returns the CI token claims of the request, if it was
+// authenticated with one.
+func CITokenFromContext(c *gin.Context) (*auth.CITokenClaims, bool) {
+	v, exists := c.Get(ciTokenContextKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := v.(*auth.CITokenClaims)
+	return claims, ok && claims != nil
+}
+
+// ciTokenNamespaceCheck rejects a CI-authenticated request that targets any
+// namespace other than the one its token was issued for. It returns (0, "")
+// for other principals and for the token's own namespace. Rejections count as
+// wrong_scope auth failures.
+func ciTokenNamespaceCheck(c *gin.Context, namespace string) (int, string) {
+	ci, ok := CITokenFromContext(c)
+	if !ok || ci.Namespace == namespace {
+		return 0, ""
+	}
+	recordAuthFailure(authFailureWrongScope, credentialCIToken)
+	return http.StatusForbidden, "Token is restricted to namespace " + ci.Namespace
+}
@@ -0,0 +1,92 @@
+// Package middleware (cli_token.go) holds the request-context plumbing for
+// tokens issued to the Terraform CLI. AuthMiddleware and OptionalAuthMiddleware
+// accept them only on route groups marked with AllowCLITokens (the registry
+// protocol routes), and only for reads; anywhere else they are rejected with
+// an error naming the token type, rather than the generic "Invalid
+// credentials" a user would otherwise have to puzzle over.
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// AuthMethodCLIToken is the auth_method recorded for Terraform CLI tokens.
+const AuthMethodCLIToken = "cli_token"
+
+// cliTokenContextKey holds the *auth.CLITokenClaims of a CLI-authenticated request.
+const cliTokenContextKey = "cli_token"
+
+// cliTokensAllowedKey marks a request as targeting a route that accepts CLI tokens.
+const cliTokensAllowedKey = "cli_tokens_allowed"
+
+// cliTokenMisuseMessage is returned when a CLI token is presented outside
+// the registry protocol routes.
+const cliTokenMisuseMessage = "This token was issued for the Terraform CLI (audience " + auth.CLITokenAudience +
+	") and can only be used to read modules and providers through the registry protocol. " +
+	"Sign in or use an API key for this endpoint."
+
+// AllowCLITokens marks the route group as a registry protocol group on which
+// the auth middleware accepts Terraform CLI tokens. It must run before the
+// auth middleware.
+func AllowCLITokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(cliTokensAllowedKey, true)
+		c.Next()
+	}
+}
+
+// cliTokenPermitted reports whether a CLI token may authenticate the request:
+// the route must allow CLI tokens and the request must be a read.
+func cliTokenPermitted(c *gin.Context) bool {
+	if !c.GetBool(cliTokensAllowedKey) {
+		return false
+	}
+	return c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+}
+
+// cliTokenRevoked reports whether the token's JTI is on the denylist or the
+// token predates its user's revoke-all watermark.
+func cliTokenRevoked(ctx context.Context, claims *auth.CLITokenClaims, tokenRepo *repositories.TokenRepository, userRevocations *repositories.UserTokenRevocationRepository) (bool, error) {
+	if tokenRepo != nil {
+		if revoked, err := tokenRepo.IsTokenRevoked(ctx, claims.ID); err != nil || revoked {
+			return revoked, err
+		}
+	}
+	if claims.IssuedAt != nil && userRevocations != nil {
+		return userRevocations.TokensRevokedSince(ctx, claims.UserID(), claims.IssuedAt.Time)
+	}
+	return false, nil
+}
+
+// setCLITokenContext populates the request context for a validated CLI token.
+// The token's own scopes are used, not the user's current ones: they are the
+// protocol reads the user held at issue time, and privilege changes since then
+// move the user's revocation watermark, which invalidates the token.
+func setCLITokenContext(c *gin.Context, claims *auth.CLITokenClaims, user *models.User) {
+	scopes := claims.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	c.Set(cliTokenContextKey, claims)
+	c.Set("user", user)
+	c.Set("user_id", user.ID)
+	c.Set("auth_method", AuthMethodCLIToken)
+	c.Set("scopes", scopes)
+}
+
+// CLITokenFromContext returns the CLI token claims of the request, if it was
+// authenticated with one.
+func CLITokenFromContext(c *gin.Context) (*auth.CLITokenClaims, bool) {
+	v, exists := c.Get(cliTokenContextKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := v.(*auth.CLITokenClaims)
+	return claims, ok && claims != nil
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+)
+
+func generateTestCLIToken(t *testing.T, userID string) string {
+	t.Helper()
+	token, _, err := auth.GenerateCLIToken(userID, "test@example.com", []string{string(auth.ScopeModulesRead)}, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateCLIToken: %v", err)
+	}
+	return token
+}
+
+// newCLITokenRouter builds a router with one protocol route (GET and POST
+// under AllowCLITokens) and one admin route, both behind mw.
+func newCLITokenRouter(mw gin.HandlerFunc) *gin.Engine {
+	r := gin.New()
+	protocol := r.Group("/v1", AllowCLITokens(), mw)
+	protocol.GET("/download", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"auth_method": c.GetString("auth_method"), "user_id": c.GetString("user_id")})
+	})
+	protocol.POST("/download", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/admin", mw, func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func doCLITokenRequest(r *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func expectCLITokenUser(mock sqlmock.Sqlmock, userID string) {
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").
+		WillReturnRows(sqlmock.NewRows(jwtUserCols).
+			AddRow(userID, "test@example.com", "Test User", nil, time.Now(), time.Now()))
+}
+
+func TestAuthMiddleware_CLIToken_ProtocolRead(t *testing.T) {
+	userRepo, userMock := newUserRepo(t)
+	expectCLITokenUser(userMock, "user-1")
+	r := newCLITokenRouter(AuthMiddleware(nil, userRepo, nil, nil, nil, nil))
+
+	w := doCLITokenRequest(r, http.MethodGet, "/v1/download", generateTestCLIToken(t, "user-1"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"auth_method":"`+AuthMethodCLIToken+`"`) {
+		t.Errorf("body = %s, want auth_method %s", w.Body.String(), AuthMethodCLIToken)
+	}
+}
+
+func TestAuthMiddleware_CLIToken_RejectedOnAdminRoute(t *testing.T) {
+	r := newCLITokenRouter(AuthMiddleware(nil, nil, nil, nil, nil, nil))
+
+	w := doCLITokenRequest(r, http.MethodGet, "/admin", generateTestCLIToken(t, "user-1"))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), auth.CLITokenAudience) {
+		t.Errorf("body = %s, want an error naming the token type", w.Body.String())
+	}
+}
+
+func TestAuthMiddleware_CLIToken_RejectedForWrites(t *testing.T) {
+	r := newCLITokenRouter(AuthMiddleware(nil, nil, nil, nil, nil, nil))
+
+	if w := doCLITokenRequest(r, http.MethodPost, "/v1/download", generateTestCLIToken(t, "user-1")); w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
+
+func TestAuthMiddleware_CLIToken_Revoked(t *testing.T) {
+	tokenRepo, tokenMock := newTokenRepo(t)
+	tokenMock.ExpectQuery("SELECT EXISTS").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	r := newCLITokenRouter(AuthMiddleware(nil, nil, nil, nil, tokenRepo, nil))
+
+	if w := doCLITokenRequest(r, http.MethodGet, "/v1/download", generateTestCLIToken(t, "user-1")); w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+}
+
+func TestOptionalAuthMiddleware_CLIToken_ProtocolRead(t *testing.T) {
+	userRepo, userMock := newUserRepo(t)
+	expectCLITokenUser(userMock, "user-1")
+	r := newCLITokenRouter(OptionalAuthMiddleware(nil, userRepo, nil, nil, nil, nil))
+
+	w := doCLITokenRequest(r, http.MethodGet, "/v1/download", generateTestCLIToken(t, "user-1"))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"user_id":"user-1"`) {
+		t.Errorf("status = %d body = %s, want 200 with user-1", w.Code, w.Body.String())
+	}
+}
+
+func TestOptionalAuthMiddleware_CLIToken_RevokedContinuesUnauthenticated(t *testing.T) {
+	tokenRepo, tokenMock := newTokenRepo(t)
+	tokenMock.ExpectQuery("SELECT EXISTS").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	r := newCLITokenRouter(OptionalAuthMiddleware(nil, nil, nil, nil, tokenRepo, nil))
+
+	w := doCLITokenRequest(r, http.MethodGet, "/v1/download", generateTestCLIToken(t, "user-1"))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"auth_method":""`) {
+		t.Errorf("status = %d body = %s, want 200 unauthenticated", w.Code, w.Body.String())
+	}
+}
+
+func TestOptionalAuthMiddleware_CLIToken_RejectedOutsideProtocolRoutes(t *testing.T) {
+	r := newCLITokenRouter(OptionalAuthMiddleware(nil, nil, nil, nil, nil, nil))
+
+	if w := doCLITokenRequest(r, http.MethodGet, "/admin", generateTestCLIToken(t, "user-1")); w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
+
+func TestAuthMiddleware_UserJWTStillAcceptedOnProtocolRoutes(t *testing.T) {
+	userRepo, userMock := newUserRepo(t)
+	expectCLITokenUser(userMock, "user-1")
+	r := newCLITokenRouter(AuthMiddleware(nil, userRepo, nil, nil, nil, nil))
+
+	w := doCLITokenRequest(r, http.MethodGet, "/v1/download", generateTestJWT(t, "user-1"))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"auth_method":"jwt"`) {
+		t.Errorf("status = %d body = %s, want 200 via jwt", w.Code, w.Body.String())
+	}
+}
@@ -156,6 +156,14 @@ func requestOrigin(c *gin.Context) string {
 	return ""
 }
 
+// isTenantOrigin reports whether origin is the tenant domain the request was
+// made on (see TenantMiddleware), which is one of the deployment's own
+// origins even though it is not in the static allowlist.
+func isTenantOrigin(c *gin.Context, origin string) bool {
+	t := TenantFromContext(c)
+	return t != nil && canonicalOrigin(t.PublicURL) == origin
+}
+
 // CSRFMiddleware enforces the double-submit cookie pattern on mutating HTTP methods.
 // Safe methods (GET, HEAD, OPTIONS) pass through unconditionally.
 // Requests authenticated via API key (Authorization: Bearer <api_key>) are exempt
@@ -168,7 +176,8 @@ func requestOrigin(c *gin.Context) string {
 //	api_key      any             exempt (API keys are never auto-sent by browsers)
 //	jwt (Bearer) absent          exempt (programmatic client: CLI, CI, curl)
 //	jwt (Bearer) present         origin allowlist — 403 unless scheme+host matches
-//	                             server public/base URL or a configured CORS origin
+//	                             server public/base URL, a configured CORS origin,
+//	                             or the tenant domain the request was made on
 //	jwt_cookie   any             double-submit token validation
 //
 // Bearer browser clients may not hold the tfr_csrf cookie (they never went
@@ -208,7 +217,7 @@ func CSRFMiddleware(cfg *config.Config) gin.HandlerFunc {
 					c.Next()
 					return
 				}
-				if _, ok := allowedOrigins[origin]; !ok {
+				if _, ok := allowedOrigins[origin]; !ok && !isTenantOrigin(c, origin) {
 					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 						"error": "request origin not allowed",
 					})
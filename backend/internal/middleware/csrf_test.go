@@ -320,7 +320,9 @@ func TestCSRF_Integration_CookieSession_HeaderStripped(t *testing.T) {
 
 	newRouter := func() (*gin.Engine, sqlmock.Sqlmock) {
 		userRepo, userMock := newUserRepo(t)
-		orgRepo, _ := newOrgRepo(t)
+		orgRepo, orgMock := newOrgRepo(t)
+		orgMock.ExpectQuery("SELECT.*FROM organization_members").
+			WillReturnRows(sqlmock.NewRows(jwtMembershipCols))
 		r := gin.New()
 		r.Use(AuthMiddleware(nil, userRepo, nil, orgRepo, nil, nil))
 		r.Use(CSRFMiddleware(csrfTestConfig()))
@@ -52,6 +52,7 @@ func RedactSensitiveQuery(rawQuery string) string {
 // RedactSensitivePath masks secret URL segments before logging.
 //
 //	/webhooks/scm/<id>/<secret>  -> /webhooks/scm/<id>/[REDACTED]
+//	/webhooks/scm/<id>           -> unchanged (secretless callback URL)
 //	/webhooks/approvals/<token>  -> /webhooks/approvals/[REDACTED]
 //
 // Exported so both api.LoggerMiddleware and AuditMiddleware share this one
@@ -64,7 +65,7 @@ func RedactSensitivePath(p string) string {
 			parts[4] = "[REDACTED]"
 			return strings.Join(parts[:5], "/")
 		}
-		if len(parts) == 4 {
+		if len(parts) == 4 && parts[3] == "" {
 			parts[3] = "[REDACTED]"
 			return strings.Join(parts[:4], "/")
 		}
@@ -13,6 +13,16 @@ func TestRedactSensitivePath(t *testing.T) {
 			in:   "/webhooks/scm/repo-123/s3cr3t-value",
 			want: "/webhooks/scm/repo-123/[REDACTED]",
 		},
+		{
+			name: "secretless scm webhook keeps link id",
+			in:   "/webhooks/scm/repo-123",
+			want: "/webhooks/scm/repo-123",
+		},
+		{
+			name: "scm webhook extra segments dropped",
+			in:   "/webhooks/scm/repo-123/s3cr3t-value/extra",
+			want: "/webhooks/scm/repo-123/[REDACTED]",
+		},
 		{
 			name: "approval token redacted",
 			in:   "/webhooks/approvals/tok_abc123",
@@ -0,0 +1,148 @@
+// Package middleware (maintenance.go) enforces the registry's read-only
+// maintenance mode.
+//
+// While maintenance mode is on (for example during a storage migration) the
+// registry keeps serving reads, including every Terraform protocol download,
+// and refuses mutating requests with 503, a Retry-After header and the
+// operator's message instead of letting them change state mid-migration.
+// Login and the routes an operator needs to finish the maintenance stay
+// writable (see maintenanceAllowedPrefixes).
+//
+// The state lives in system_settings and is cached for a short TTL like the
+// mirror allowlist: the admin toggle invalidates this replica's cache
+// immediately and other replicas pick the change up when their TTL expires.
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// DefaultMaintenanceTTL bounds how long a replica keeps accepting or refusing
+// writes after another replica toggled maintenance mode.
+const DefaultMaintenanceTTL = 5 * time.Second
+
+// DefaultMaintenanceRetryAfter is the Retry-After sent when maintenance mode
+// has no ETA in the future.
+const DefaultMaintenanceRetryAfter = 5 * time.Minute
+
+// DefaultMaintenanceMessage is returned when the operator gave no message.
+const DefaultMaintenanceMessage = "The registry is in read-only maintenance mode"
+
+// maintenanceAllowedPrefixes lists the paths that accept writes during
+// maintenance: authentication, the maintenance toggle itself, and storage
+// migrations, which maintenance mode exists to protect.
+var maintenanceAllowedPrefixes = []string{
+	"/api/v1/auth/",
+	"/api/v1/admin/maintenance",
+	"/api/v1/admin/storage/migrations",
+}
+
+// MaintenanceLoader loads the stored maintenance mode state.
+type MaintenanceLoader interface {
+	Get(ctx context.Context) (*models.MaintenanceMode, error)
+}
+
+// MaintenanceState caches the maintenance mode state.
+type MaintenanceState struct {
+	loader MaintenanceLoader
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	mode     *models.MaintenanceMode
+	loadedAt time.Time
+	loaded   bool
+}
+
+// NewMaintenanceState creates a maintenance mode cache backed by loader. A
+// non-positive ttl selects DefaultMaintenanceTTL.
+func NewMaintenanceState(loader MaintenanceLoader, ttl time.Duration) *MaintenanceState {
+	if ttl <= 0 {
+		ttl = DefaultMaintenanceTTL
+	}
+	return &MaintenanceState{loader: loader, ttl: ttl, now: time.Now}
+}
+
+// Invalidate forces the next check to reload the state. Call it after every
+// maintenance mode write.
+func (s *MaintenanceState) Invalidate() {
+	s.mu.Lock()
+	s.loaded = false
+	s.mu.Unlock()
+}
+
+// Current returns the maintenance mode state. When a reload fails the last
+// loaded state stays in force; if nothing has been loaded yet maintenance is
+// reported as off, so a database outage does not also refuse every write
+// with a maintenance message.
+func (s *MaintenanceState) Current(ctx context.Context) *models.MaintenanceMode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded && s.now().Sub(s.loadedAt) < s.ttl {
+		return s.mode
+	}
+	mode, err := s.loader.Get(ctx)
+	if err != nil {
+		slog.Warn("maintenance mode reload failed", "error", err)
+		if s.mode != nil {
+			return s.mode
+		}
+		return &models.MaintenanceMode{}
+	}
+	s.mode, s.loadedAt, s.loaded = mode, s.now(), true
+	return mode
+}
+
+// MaintenanceMiddleware refuses mutating requests with 503 while maintenance
+// mode is on. GET, HEAD and OPTIONS requests and the paths in
+// maintenanceAllowedPrefixes always pass. A nil state allows everything.
+func MaintenanceMiddleware(state *MaintenanceState) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if state == nil || !isMutatingMethod(c.Request.Method) || maintenanceAllowedPath(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		mode := state.Current(c.Request.Context())
+		if !mode.Enabled {
+			c.Next()
+			return
+		}
+		message := mode.Message
+		if message == "" {
+			message = DefaultMaintenanceMessage
+		}
+		retryAfter := mode.RetryAfter(state.now(), DefaultMaintenanceRetryAfter)
+		c.Header("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+		body := gin.H{"error": message, "maintenance": true}
+		if mode.ETA != nil {
+			body["eta"] = mode.ETA
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, body)
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func maintenanceAllowedPath(path string) bool {
+	for _, prefix := range maintenanceAllowedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+type fakeMaintenanceLoader struct {
+	mode  *models.MaintenanceMode
+	err   error
+	calls int
+}
+
+func (f *fakeMaintenanceLoader) Get(context.Context) (*models.MaintenanceMode, error) {
+	f.calls++
+	return f.mode, f.err
+}
+
+func newMaintenanceRouter(s *MaintenanceState) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(MaintenanceMiddleware(s))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/v1/modules/:namespace/:name/:system/versions", ok)
+	r.POST("/api/v1/modules", ok)
+	r.DELETE("/api/v1/modules/:id", ok)
+	r.POST("/api/v1/auth/refresh", ok)
+	r.POST("/api/v1/admin/maintenance", ok)
+	return r
+}
+
+func serveMaintenance(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMaintenanceMiddleware_Disabled(t *testing.T) {
+	r := newMaintenanceRouter(NewMaintenanceState(&fakeMaintenanceLoader{mode: &models.MaintenanceMode{}}, 0))
+	if w := serveMaintenance(r, http.MethodPost, "/api/v1/modules"); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
+
+func TestMaintenanceMiddleware_RefusesWritesServesReads(t *testing.T) {
+	eta := time.Now().Add(10 * time.Minute)
+	loader := &fakeMaintenanceLoader{mode: &models.MaintenanceMode{Enabled: true, Message: "migrating storage", ETA: &eta}}
+	r := newMaintenanceRouter(NewMaintenanceState(loader, 0))
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/v1/modules/acme/vpc/aws/versions", http.StatusOK},
+		{http.MethodPost, "/api/v1/modules", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/v1/modules/m-1", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/auth/refresh", http.StatusOK},
+		{http.MethodPost, "/api/v1/admin/maintenance", http.StatusOK},
+	} {
+		w := serveMaintenance(r, tc.method, tc.path)
+		if w.Code != tc.want {
+			t.Errorf("%s %s status = %d, want %d", tc.method, tc.path, w.Code, tc.want)
+		}
+		if tc.want == http.StatusServiceUnavailable {
+			if got := w.Header().Get("Retry-After"); got != "600" && got != "599" {
+				t.Errorf("Retry-After = %q, want about 600", got)
+			}
+			if !strings.Contains(w.Body.String(), "migrating storage") {
+				t.Errorf("body = %s, want the maintenance message", w.Body.String())
+			}
+		}
+	}
+	// Reads and allowlisted writes never consult the state.
+	if loader.calls != 1 {
+		t.Errorf("loader calls = %d, want 1", loader.calls)
+	}
+}
+
+func TestMaintenanceMiddleware_DefaultMessageAndRetryAfter(t *testing.T) {
+	r := newMaintenanceRouter(NewMaintenanceState(&fakeMaintenanceLoader{mode: &models.MaintenanceMode{Enabled: true}}, 0))
+
+	w := serveMaintenance(r, http.MethodPost, "/api/v1/modules")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "300" {
+		t.Errorf("Retry-After = %q, want 300", got)
+	}
+	if !strings.Contains(w.Body.String(), DefaultMaintenanceMessage) {
+		t.Errorf("body = %s", w.Body.String())
+	}
+}
+
+func TestMaintenanceState_CachesAndInvalidates(t *testing.T) {
+	loader := &fakeMaintenanceLoader{mode: &models.MaintenanceMode{}}
+	s := NewMaintenanceState(loader, time.Minute)
+
+	s.Current(context.Background())
+	s.Current(context.Background())
+	if loader.calls != 1 {
+		t.Fatalf("loader calls = %d, want 1 within the TTL", loader.calls)
+	}
+	loader.mode = &models.MaintenanceMode{Enabled: true}
+	s.Invalidate()
+	if !s.Current(context.Background()).Enabled || loader.calls != 2 {
+		t.Errorf("Invalidate did not force a reload")
+	}
+}
+
+func TestMaintenanceState_LoadErrors(t *testing.T) {
+	loader := &fakeMaintenanceLoader{err: errors.New("db down")}
+	s := NewMaintenanceState(loader, time.Nanosecond)
+
+	// Nothing loaded yet: fail open rather than refuse every write.
+	if s.Current(context.Background()).Enabled {
+		t.Error("want maintenance off when the state was never loaded")
+	}
+
+	loader.mode, loader.err = &models.MaintenanceMode{Enabled: true}, nil
+	s.Current(context.Background())
+	loader.err = errors.New("db down")
+	time.Sleep(time.Millisecond)
+	if !s.Current(context.Background()).Enabled {
+		t.Error("want the last loaded state kept when a reload fails")
+	}
+}
+
+func TestMaintenanceMiddleware_NilState(t *testing.T) {
+	r := newMaintenanceRouter(nil)
+	if w := serveMaintenance(r, http.MethodPost, "/api/v1/modules"); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
@@ -4,68 +4,152 @@
 package middleware
 
 import (
+	"encoding/hex"
 	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/telemetry"
 )
 
-// MetricsMiddleware returns a Gin handler that records two Prometheus metrics for every
-// request that passes through the router.
+// MetricsMiddleware returns a Gin handler that records the HTTP Prometheus metrics for
+// every request that passes through the router.
 //
 // Recorded metrics:
-//   - http_requests_total{method, path, status}    — CounterVec
-//   - http_request_duration_seconds{method, path}  — HistogramVec
+//   - http_requests_total{method, path, group, org_bucket, status}  — CounterVec
+//   - http_request_duration_seconds{method, path, group}            — HistogramVec
+//   - http_requests_in_flight{method, path, group}                  — GaugeVec
 //
 // The path label is set from c.FullPath(), which returns the matched Gin route template
 // (e.g. /v1/modules/:namespace/:name/:system/:version/download) rather than the raw URL.
 // Requests that do not match any registered route (404/405) use the literal string
-// "<no-route>" so unhandled paths do not inflate label cardinality.
+// "<no-route>" so unhandled paths do not inflate label cardinality. The group label is
+// derived from the route template (see routeMetricsGroup).
 //
-// Prometheus exemplars are attached to the duration histogram using the X-Request-ID
-// as the trace_id label. This links individual histogram observations to specific
-// requests, enabling Grafana Tempo or other trace backends to correlate metrics with
-// traces when the same request ID is propagated through the tracing pipeline.
+// org_bucket is read after the handler chain has run, from the "organization_id" the
+// authentication middleware sets (API keys, CI tokens) or, failing that, the
+// "owner_org_id" the namespace authorization middleware resolves. The ID is hashed
+// into one of telemetry.HTTPOrgBuckets buckets so the label stays bounded; requests
+// without an organization record "none".
+//
+// Prometheus exemplars are attached to the duration histogram with a trace_id label.
+// When tracing is enabled in cfg and the request carries a valid W3C traceparent
+// header, the trace ID comes from that header, so a slow histogram bucket links
+// straight to the distributed trace. Otherwise the X-Request-ID is used, which still
+// links the observation to the request's log entries.
+//
+// cfg may be nil, which behaves like tracing being disabled.
 //
 // This middleware must be registered AFTER RecoveryMiddleware and RequestIDMiddleware so that
 // the response status set by error handlers is captured correctly:
 //
 //	router.Use(RecoveryMiddleware())
 //	router.Use(RequestIDMiddleware())
-//	router.Use(MetricsMiddleware())
+//	router.Use(MetricsMiddleware(&cfg.Telemetry))
 //
 // See telemetry.HTTPRequestsTotal and telemetry.HTTPRequestDuration for example PromQL
 // queries and alert rules.
-func MetricsMiddleware() gin.HandlerFunc {
+func MetricsMiddleware(cfg *config.TelemetryConfig) gin.HandlerFunc {
+	tracing := cfg != nil && cfg.Tracing.Enabled
 	return func(c *gin.Context) {
 		start := time.Now()
 
-		c.Next()
-
-		// Resolve the route template; fall back for 404/405 situations.
+		// Resolve the route template; fall back for 404/405 situations. Gin has
+		// already matched the route, so the template is known before c.Next().
 		path := c.FullPath()
 		if path == "" {
 			path = "<no-route>"
 		}
+		method := c.Request.Method
+		group := routeMetricsGroup(path)
+
+		inFlight := telemetry.HTTPRequestsInFlight.WithLabelValues(method, path, group)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		c.Next()
 
 		duration := time.Since(start).Seconds()
-		method := c.Request.Method
 		status := fmt.Sprintf("%d", c.Writer.Status())
 
-		telemetry.HTTPRequestsTotal.WithLabelValues(method, path, status).Inc()
+		telemetry.HTTPRequestsTotal.WithLabelValues(method, path, group, orgMetricsBucket(c), status).Inc()
 
-		// Attach the request ID as an exemplar on the duration histogram so operators
-		// can navigate from a slow histogram bucket directly to the offending request's
-		// trace or log entry.
-		obs := telemetry.HTTPRequestDuration.WithLabelValues(method, path)
+		// Attach a trace or request ID as an exemplar on the duration histogram so
+		// operators can navigate from a slow histogram bucket directly to the
+		// offending request's trace or log entry.
+		obs := telemetry.HTTPRequestDuration.WithLabelValues(method, path, group)
 		if eo, ok := obs.(prometheus.ExemplarObserver); ok {
-			if requestID := c.GetString(RequestIDKey); requestID != "" {
-				eo.ObserveWithExemplar(duration, prometheus.Labels{"trace_id": requestID})
+			traceID := ""
+			if tracing {
+				traceID = traceparentTraceID(c.GetHeader("traceparent"))
+			}
+			if traceID == "" {
+				traceID = c.GetString(RequestIDKey)
+			}
+			if traceID != "" {
+				eo.ObserveWithExemplar(duration, prometheus.Labels{"trace_id": traceID})
 				return
 			}
 		}
 		obs.Observe(duration)
 	}
 }
+
+// Values of the group label on the HTTP metrics.
+const (
+	metricsGroupProtocol = "protocol"
+	metricsGroupMirror   = "mirror"
+	metricsGroupAdmin    = "admin"
+	metricsGroupOther    = "other"
+)
+
+// routeMetricsGroup assigns a route template to an API group: the Terraform and
+// OCI protocols, the provider network mirror and binary mirror, the management
+// API, or everything else (health, metrics, swagger, inbound webhooks, 404s).
+func routeMetricsGroup(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/terraform/"):
+		return metricsGroupMirror
+	case strings.HasPrefix(path, "/v1/"), strings.HasPrefix(path, "/v2/"), strings.HasPrefix(path, "/.well-known/"):
+		return metricsGroupProtocol
+	case strings.HasPrefix(path, "/api/"), strings.HasPrefix(path, "/scim/"):
+		return metricsGroupAdmin
+	default:
+		return metricsGroupOther
+	}
+}
+
+// orgMetricsBucket returns the org_bucket label for the request: the FNV-1a hash
+// of its organization ID modulo telemetry.HTTPOrgBuckets, or "none".
+func orgMetricsBucket(c *gin.Context) string {
+	orgID := c.GetString("organization_id")
+	if orgID == "" {
+		orgID = c.GetString("owner_org_id")
+	}
+	if orgID == "" {
+		return "none"
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(orgID))
+	return strconv.Itoa(int(h.Sum32() % telemetry.HTTPOrgBuckets))
+}
+
+// traceparentTraceID returns the trace ID of a W3C traceparent header
+// ("00-<32 hex trace-id>-<16 hex parent-id>-<2 hex flags>"), or "" when the
+// header is absent or malformed or the trace ID is all zeros.
+func traceparentTraceID(header string) string {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ""
+	}
+	traceID := strings.ToLower(parts[1])
+	if _, err := hex.DecodeString(traceID); err != nil || traceID == strings.Repeat("0", 32) {
+		return ""
+	}
+	return traceID
+}
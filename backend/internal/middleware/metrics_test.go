@@ -1,13 +1,16 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	dto "github.com/prometheus/client_model/go"
+	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/telemetry"
 )
 
@@ -81,7 +84,7 @@ func collectHistogramCount(hv *prometheus.HistogramVec, labels prometheus.Labels
 // newMetricsRouter builds a minimal Gin engine with MetricsMiddleware and one test route.
 func newMetricsRouter(handler gin.HandlerFunc) *gin.Engine {
 	r := gin.New()
-	r.Use(MetricsMiddleware())
+	r.Use(MetricsMiddleware(nil))
 	r.GET("/test/:id", handler)
 	return r
 }
@@ -159,7 +162,7 @@ func TestMetricsMiddleware_UsesRouteTemplate_NotRawURL(t *testing.T) {
 func TestMetricsMiddleware_NoRouteLabel(t *testing.T) {
 	// Requests to unregistered paths should record the sentinel "<no-route>", not a raw URL.
 	r := gin.New()
-	r.Use(MetricsMiddleware())
+	r.Use(MetricsMiddleware(nil))
 	// No routes registered → every request is a 404.
 
 	req := httptest.NewRequest(http.MethodGet, "/does-not-exist", nil)
@@ -206,3 +209,161 @@ func TestMetricsMiddleware_RecordsErrorStatus(t *testing.T) {
 		t.Errorf("http_requests_total for status=500 not incremented: before=%.0f after=%.0f", before, after)
 	}
 }
+
+func TestMetricsMiddleware_GroupAndOrgBucketLabels(t *testing.T) {
+	r := gin.New()
+	r.Use(MetricsMiddleware(nil))
+	r.GET("/api/v1/things/:id", func(c *gin.Context) {
+		c.Set("organization_id", c.Param("id"))
+		c.Status(http.StatusOK)
+	})
+	r.GET("/terraform/providers/:hostname", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/things/org-1", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/terraform/providers/example.com", nil))
+
+	withOrg := prometheus.Labels{"path": "/api/v1/things/:id", "group": "admin", "org_bucket": orgBucketFor("org-1")}
+	if collectCounter(telemetry.HTTPRequestsTotal, withOrg) < 1 {
+		t.Errorf("no http_requests_total series with %v", withOrg)
+	}
+	noOrg := prometheus.Labels{"path": "/terraform/providers/:hostname", "group": "mirror", "org_bucket": "none"}
+	if collectCounter(telemetry.HTTPRequestsTotal, noOrg) < 1 {
+		t.Errorf("no http_requests_total series with %v", noOrg)
+	}
+}
+
+// orgBucketFor returns the org_bucket MetricsMiddleware records for orgID.
+func orgBucketFor(orgID string) string {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("organization_id", orgID)
+	return orgMetricsBucket(c)
+}
+
+func TestOrgMetricsBucket_Bounded(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		seen[orgBucketFor(fmt.Sprintf("org-%d", i))] = true
+	}
+	if len(seen) > telemetry.HTTPOrgBuckets {
+		t.Errorf("1000 organizations produced %d buckets, want at most %d", len(seen), telemetry.HTTPOrgBuckets)
+	}
+	if orgBucketFor("org-7") != orgBucketFor("org-7") {
+		t.Error("bucketing is not stable")
+	}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("owner_org_id", "org-7")
+	if got := orgMetricsBucket(c); got != orgBucketFor("org-7") {
+		t.Errorf("owner_org_id bucket = %q, want %q", got, orgBucketFor("org-7"))
+	}
+}
+
+func TestRouteMetricsGroup(t *testing.T) {
+	tests := map[string]string{
+		"/v1/modules/:namespace/:name/:system/versions":              "protocol",
+		"/.well-known/terraform.json":                                "protocol",
+		"/terraform/providers/:hostname/:namespace/:type/index.json": "mirror",
+		"/terraform/binaries/:name/versions":                         "mirror",
+		"/api/v1/modules":                                            "admin",
+		"/scim/v2/Users":                                             "admin",
+		"/health":                                                    "other",
+		"<no-route>":                                                 "other",
+	}
+	for path, want := range tests {
+		if got := routeMetricsGroup(path); got != want {
+			t.Errorf("routeMetricsGroup(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestMetricsMiddleware_InFlightGauge(t *testing.T) {
+	r := gin.New()
+	r.Use(MetricsMiddleware(nil))
+	var during float64
+	gauge := telemetry.HTTPRequestsInFlight.WithLabelValues("GET", "/inflight/:id", "other")
+	r.GET("/inflight/:id", func(c *gin.Context) {
+		during = testutil.ToFloat64(gauge)
+		c.Status(http.StatusOK)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/inflight/1", nil))
+
+	if during != 1 {
+		t.Errorf("in-flight gauge during the request = %v, want 1", during)
+	}
+	if after := testutil.ToFloat64(gauge); after != 0 {
+		t.Errorf("in-flight gauge after the request = %v, want 0", after)
+	}
+}
+
+func TestTraceparentTraceID(t *testing.T) {
+	tests := map[string]string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01": "",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": "",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01": "",
+		"00-4bf92f35-00f067aa0ba902b7-01":                         "",
+		"":                                                        "",
+	}
+	for header, want := range tests {
+		if got := traceparentTraceID(header); got != want {
+			t.Errorf("traceparentTraceID(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+// durationExemplarTraceIDs returns the trace_id of every exemplar on the
+// duration histogram series for path.
+func durationExemplarTraceIDs(path string) map[string]bool {
+	ids := map[string]bool{}
+	ch := make(chan prometheus.Metric, 100)
+	telemetry.HTTPRequestDuration.Collect(ch)
+	close(ch)
+	for m := range ch {
+		var dm dto.Metric
+		if err := m.Write(&dm); err != nil {
+			continue
+		}
+		match := false
+		for _, lp := range dm.GetLabel() {
+			if lp.GetName() == "path" && lp.GetValue() == path {
+				match = true
+			}
+		}
+		if !match {
+			continue
+		}
+		for _, b := range dm.GetHistogram().GetBucket() {
+			for _, lp := range b.GetExemplar().GetLabel() {
+				if lp.GetName() == "trace_id" {
+					ids[lp.GetValue()] = true
+				}
+			}
+		}
+	}
+	return ids
+}
+
+func TestMetricsMiddleware_ExemplarTraceID(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	serve := func(cfg *config.TelemetryConfig, path string) {
+		r := gin.New()
+		r.Use(RequestIDMiddleware())
+		r.Use(MetricsMiddleware(cfg))
+		r.GET(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(RequestIDHeader, "req-123")
+		req.Header.Set("traceparent", traceparent)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(&config.TelemetryConfig{Tracing: config.TracingConfig{Enabled: true}}, "/exemplar/traced")
+	if ids := durationExemplarTraceIDs("/exemplar/traced"); !ids["4bf92f3577b34da6a3ce929d0e0e4736"] {
+		t.Errorf("tracing enabled: exemplar trace IDs = %v, want the traceparent trace ID", ids)
+	}
+
+	serve(nil, "/exemplar/untraced")
+	if ids := durationExemplarTraceIDs("/exemplar/untraced"); !ids["req-123"] {
+		t.Errorf("tracing disabled: exemplar trace IDs = %v, want the request ID", ids)
+	}
+}
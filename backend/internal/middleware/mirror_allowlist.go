@@ -0,0 +1,136 @@
+// Package middleware (mirror_allowlist.go) restricts the network mirror routes
+// (/terraform/providers/...) to the providers on the admin-managed allowlist.
+//
+// The registry may store providers that must not be served through the mirror
+// (e.g. locally published internal providers). Requests outside the allowlist
+// are answered with the same 404 body the mirror handlers use for unknown
+// providers, before any provider lookup, so the response does not reveal
+// whether the provider exists. An empty allowlist serves everything. A
+// request under a hostname alias is permitted when the alias's target
+// hostname is (see WithAliases).
+//
+// Entries are cached for a short TTL so the hot mirror path does not hit the
+// database on every request. Admin writes on this replica invalidate the
+// cache immediately; other replicas pick changes up when their TTL expires.
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// DefaultMirrorAllowlistTTL bounds how long a replica serves a stale
+// allowlist after another replica changed it.
+const DefaultMirrorAllowlistTTL = 30 * time.Second
+
+// mirrorNotFoundBody matches the mirror handlers' own not-found response.
+var mirrorNotFoundBody = []byte(`{"errors":["provider not found"]}`)
+
+// MirrorAllowlistLoader loads the current allowlist entries.
+type MirrorAllowlistLoader interface {
+	List(ctx context.Context) ([]*models.MirrorAllowlistEntry, error)
+}
+
+// MirrorAllowlist caches the network mirror allowlist.
+type MirrorAllowlist struct {
+	loader  MirrorAllowlistLoader
+	aliases *MirrorHostnameAliases // optional
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	entries  []*models.MirrorAllowlistEntry
+	loadedAt time.Time
+	loaded   bool
+}
+
+// NewMirrorAllowlist creates an allowlist cache backed by loader. A
+// non-positive ttl selects DefaultMirrorAllowlistTTL.
+func NewMirrorAllowlist(loader MirrorAllowlistLoader, ttl time.Duration) *MirrorAllowlist {
+	if ttl <= 0 {
+		ttl = DefaultMirrorAllowlistTTL
+	}
+	return &MirrorAllowlist{loader: loader, ttl: ttl, now: time.Now}
+}
+
+// WithAliases makes Permits accept a request under a hostname alias when the
+// allowlist permits the alias's target hostname.
+func (a *MirrorAllowlist) WithAliases(aliases *MirrorHostnameAliases) *MirrorAllowlist {
+	a.aliases = aliases
+	return a
+}
+
+// Invalidate forces the next check to reload the allowlist. Call it after
+// every allowlist write.
+func (a *MirrorAllowlist) Invalidate() {
+	a.mu.Lock()
+	a.loaded = false
+	a.mu.Unlock()
+}
+
+// Permits reports whether the mirror may serve hostname/namespace/type,
+// directly or through a hostname alias. When
+// a reload fails the last loaded allowlist stays in force; if nothing has
+// been loaded yet the error is returned.
+func (a *MirrorAllowlist) Permits(ctx context.Context, hostname, namespace, providerType string) (bool, error) {
+	entries, err := a.current(ctx)
+	if err != nil {
+		return false, err
+	}
+	if models.MirrorAllowlistPermits(entries, hostname, namespace, providerType) {
+		return true, nil
+	}
+	alias, err := a.aliases.Lookup(ctx, hostname, namespace)
+	if err != nil || alias == nil {
+		return false, err
+	}
+	return models.MirrorAllowlistPermits(entries, alias.TargetHostname, namespace, providerType), nil
+}
+
+func (a *MirrorAllowlist) current(ctx context.Context) ([]*models.MirrorAllowlistEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.loaded && a.now().Sub(a.loadedAt) < a.ttl {
+		return a.entries, nil
+	}
+	entries, err := a.loader.List(ctx)
+	if err != nil {
+		if a.entries != nil {
+			slog.Warn("mirror allowlist reload failed, keeping previous entries", "error", err)
+			return a.entries, nil
+		}
+		return nil, err
+	}
+	a.entries, a.loadedAt, a.loaded = entries, a.now(), true
+	return entries, nil
+}
+
+// MirrorAllowlistMiddleware rejects network mirror requests whose
+// :hostname/:namespace/:type is not on the allowlist. A nil allowlist allows
+// everything.
+func MirrorAllowlistMiddleware(allowlist *MirrorAllowlist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allowlist == nil {
+			c.Next()
+			return
+		}
+		ok, err := allowlist.Permits(c.Request.Context(), c.Param("hostname"), c.Param("namespace"), c.Param("type"))
+		if err != nil {
+			slog.Error("failed to load mirror allowlist", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load mirror allowlist"})
+			return
+		}
+		if !ok {
+			c.Data(http.StatusNotFound, "application/json", mirrorNotFoundBody)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
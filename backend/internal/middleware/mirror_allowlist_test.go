@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+type fakeAllowlistLoader struct {
+	entries []*models.MirrorAllowlistEntry
+	err     error
+	calls   int
+}
+
+func (f *fakeAllowlistLoader) List(context.Context) ([]*models.MirrorAllowlistEntry, error) {
+	f.calls++
+	return f.entries, f.err
+}
+
+func newMirrorAllowlistRouter(a *MirrorAllowlist) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	g := r.Group("/terraform/providers")
+	g.Use(MirrorAllowlistMiddleware(a))
+	g.GET("/:hostname/:namespace/:type/index.json", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func getMirrorIndex(r *gin.Engine, addr string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/terraform/providers/"+addr+"/index.json", nil)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMirrorAllowlistMiddleware(t *testing.T) {
+	loader := &fakeAllowlistLoader{entries: []*models.MirrorAllowlistEntry{
+		{HostnamePattern: "registry.terraform.io", NamespacePattern: "hashicorp", TypePattern: "*"},
+	}}
+	r := newMirrorAllowlistRouter(NewMirrorAllowlist(loader, time.Minute))
+
+	if w := getMirrorIndex(r, "registry.terraform.io/hashicorp/aws"); w.Code != http.StatusOK {
+		t.Errorf("allowed provider: status = %d, want 200", w.Code)
+	}
+	w := getMirrorIndex(r, "registry.example.com/internal/secret")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("blocked provider: status = %d, want 404", w.Code)
+	}
+	if w.Body.String() != `{"errors":["provider not found"]}` || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("blocked response = %q (%s), want the mirror not-found body", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+	if loader.calls != 1 {
+		t.Errorf("loader calls = %d, want 1 (cached)", loader.calls)
+	}
+}
+
+func TestMirrorAllowlistMiddleware_NilAllowsAll(t *testing.T) {
+	r := newMirrorAllowlistRouter(nil)
+	if w := getMirrorIndex(r, "registry.example.com/internal/secret"); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
+
+func TestMirrorAllowlist_InvalidateAndTTL(t *testing.T) {
+	loader := &fakeAllowlistLoader{entries: []*models.MirrorAllowlistEntry{}}
+	a := NewMirrorAllowlist(loader, time.Minute)
+	now := time.Now()
+	a.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if ok, _ := a.Permits(ctx, "registry.example.com", "internal", "secret"); !ok {
+		t.Fatal("empty allowlist should permit everything")
+	}
+
+	// A write on this replica takes effect on the next request.
+	loader.entries = []*models.MirrorAllowlistEntry{{HostnamePattern: "*", NamespacePattern: "hashicorp", TypePattern: "*"}}
+	a.Invalidate()
+	if ok, _ := a.Permits(ctx, "registry.example.com", "internal", "secret"); ok {
+		t.Error("provider still permitted after invalidation")
+	}
+
+	// A write on another replica takes effect once the TTL expires.
+	loader.entries = []*models.MirrorAllowlistEntry{}
+	if ok, _ := a.Permits(ctx, "registry.example.com", "internal", "secret"); ok {
+		t.Error("cache reloaded before the TTL expired")
+	}
+	now = now.Add(time.Minute)
+	if ok, _ := a.Permits(ctx, "registry.example.com", "internal", "secret"); !ok {
+		t.Error("cache not reloaded after the TTL expired")
+	}
+}
+
+func TestMirrorAllowlist_LoadErrors(t *testing.T) {
+	loader := &fakeAllowlistLoader{err: errors.New("db down")}
+	a := NewMirrorAllowlist(loader, time.Minute)
+	r := newMirrorAllowlistRouter(a)
+
+	// Nothing loaded yet: fail closed.
+	if w := getMirrorIndex(r, "registry.terraform.io/hashicorp/aws"); w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+
+	// Once loaded, a failed reload keeps the previous entries.
+	loader.err = nil
+	loader.entries = []*models.MirrorAllowlistEntry{{HostnamePattern: "*", NamespacePattern: "hashicorp", TypePattern: "*"}}
+	a.Invalidate()
+	if w := getMirrorIndex(r, "registry.terraform.io/hashicorp/aws"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	loader.err = errors.New("db down")
+	a.Invalidate()
+	if w := getMirrorIndex(r, "registry.example.com/internal/secret"); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 from the previous entries", w.Code)
+	}
+}
+
+type fakeAliasLoader struct {
+	aliases []*models.MirrorHostnameAlias
+	calls   int
+}
+
+func (f *fakeAliasLoader) List(context.Context) ([]*models.MirrorHostnameAlias, error) {
+	f.calls++
+	return f.aliases, nil
+}
+
+func TestMirrorAllowlist_AliasFollowsTarget(t *testing.T) {
+	loader := &fakeAllowlistLoader{entries: []*models.MirrorAllowlistEntry{
+		{HostnamePattern: "registry.terraform.io", NamespacePattern: "hashicorp", TypePattern: "*"},
+	}}
+	aliasLoader := &fakeAliasLoader{aliases: []*models.MirrorHostnameAlias{
+		{AliasHostname: "registry.opentofu.org", TargetHostname: "registry.terraform.io"},
+	}}
+	aliases := NewMirrorHostnameAliases(aliasLoader, time.Minute)
+	r := newMirrorAllowlistRouter(NewMirrorAllowlist(loader, time.Minute).WithAliases(aliases))
+
+	if w := getMirrorIndex(r, "registry.opentofu.org/hashicorp/aws"); w.Code != http.StatusOK {
+		t.Errorf("aliased provider: status = %d, want 200", w.Code)
+	}
+	if w := getMirrorIndex(r, "registry.opentofu.org/acme/internal"); w.Code != http.StatusNotFound {
+		t.Errorf("aliased provider outside the allowlist: status = %d, want 404", w.Code)
+	}
+
+	aliases.Invalidate()
+	aliasLoader.aliases = nil
+	if w := getMirrorIndex(r, "registry.opentofu.org/hashicorp/aws"); w.Code != http.StatusNotFound {
+		t.Errorf("after alias removed: status = %d, want 404", w.Code)
+	}
+	if aliasLoader.calls != 2 {
+		t.Errorf("alias loader calls = %d, want 2", aliasLoader.calls)
+	}
+}
@@ -0,0 +1,84 @@
+// Package middleware (mirror_hostname_aliases.go) caches the network mirror
+// hostname aliases (see models.MirrorHostnameAlias) for the mirror routes.
+//
+// The allowlist consults the cache so a request under an alias hostname is
+// permitted when its target hostname is, and the mirror handlers use it to
+// decide which provider versions an alias may serve. Like the allowlist,
+// entries are cached for a short TTL and invalidated by admin writes on this
+// replica.
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// MirrorHostnameAliasLoader loads the current hostname aliases.
+type MirrorHostnameAliasLoader interface {
+	List(ctx context.Context) ([]*models.MirrorHostnameAlias, error)
+}
+
+// MirrorHostnameAliases caches the network mirror hostname aliases.
+type MirrorHostnameAliases struct {
+	loader MirrorHostnameAliasLoader
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	aliases  []*models.MirrorHostnameAlias
+	loadedAt time.Time
+	loaded   bool
+}
+
+// NewMirrorHostnameAliases creates an alias cache backed by loader. A
+// non-positive ttl selects DefaultMirrorAllowlistTTL.
+func NewMirrorHostnameAliases(loader MirrorHostnameAliasLoader, ttl time.Duration) *MirrorHostnameAliases {
+	if ttl <= 0 {
+		ttl = DefaultMirrorAllowlistTTL
+	}
+	return &MirrorHostnameAliases{loader: loader, ttl: ttl, now: time.Now}
+}
+
+// Invalidate forces the next lookup to reload the aliases. Call it after
+// every alias write.
+func (a *MirrorHostnameAliases) Invalidate() {
+	a.mu.Lock()
+	a.loaded = false
+	a.mu.Unlock()
+}
+
+// Lookup returns the alias that applies to hostname/namespace, or nil. A nil
+// *MirrorHostnameAliases has no aliases. When a reload fails the last loaded
+// aliases stay in force; if nothing has been loaded yet the error is returned.
+func (a *MirrorHostnameAliases) Lookup(ctx context.Context, hostname, namespace string) (*models.MirrorHostnameAlias, error) {
+	if a == nil {
+		return nil, nil
+	}
+	aliases, err := a.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.MirrorHostnameAliasFor(aliases, hostname, namespace), nil
+}
+
+func (a *MirrorHostnameAliases) current(ctx context.Context) ([]*models.MirrorHostnameAlias, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.loaded && a.now().Sub(a.loadedAt) < a.ttl {
+		return a.aliases, nil
+	}
+	aliases, err := a.loader.List(ctx)
+	if err != nil {
+		if a.aliases != nil {
+			slog.Warn("mirror hostname alias reload failed, keeping previous aliases", "error", err)
+			return a.aliases, nil
+		}
+		return nil, err
+	}
+	a.aliases, a.loadedAt, a.loaded = aliases, a.now(), true
+	return aliases, nil
+}
@@ -14,7 +14,10 @@
 //     equals the owning organization (keys are bound to exactly one
 //     organization at creation time); or
 //   - the caller is a JWT principal whose user is a member of the owning
-//     organization with a role template that grants the required write scope.
+//     organization with a role template that grants the required write scope;
+//     or
+//   - the caller holds a CI publish token (OIDC token exchange) issued for
+//     this exact namespace and for the owning organization.
 //
 // When no claim exists the ownership falls back to the organization of the
 // existing artifact rows (covers system-created content such as mirror-synced
@@ -176,6 +179,23 @@ func (a *NamespaceAuthorizer) RequirePublishAccessFromJSON(scope auth.Scope) gin
 	}
 }
 
+// RequirePublishAccessFromBody authorizes upload routes that accept either a
+// multipart form or, for publish-from-URL, a JSON body. The request's
+// Content-Type selects RequirePublishAccessFromJSON or
+// RequirePublishAccessFromForm so the namespace is read the same way the
+// handler will read it.
+func (a *NamespaceAuthorizer) RequirePublishAccessFromBody(scope auth.Scope, maxMemory int64) gin.HandlerFunc {
+	fromJSON := a.RequirePublishAccessFromJSON(scope)
+	fromForm := a.RequirePublishAccessFromForm(scope, maxMemory)
+	return func(c *gin.Context) {
+		if c.ContentType() == gin.MIMEJSON {
+			fromJSON(c)
+			return
+		}
+		fromForm(c)
+	}
+}
+
 // RequireModuleAccessByID authorizes mutations on routes that address a module
 // by its UUID (SCM link operations). Missing modules and malformed IDs pass
 // through so the handler keeps its own not-found/bad-request semantics.
@@ -222,6 +242,10 @@ func (a *NamespaceAuthorizer) RequireModuleUpdateAccess(scope auth.Scope) gin.Ha
 		}
 
 		target := *body.Namespace
+		if status, msg := ciTokenNamespaceCheck(c, target); status != 0 {
+			abortNamespaceAuthz(c, status, msg)
+			return
+		}
 		targetOwner, err := a.resolveOwnerOrg(c.Request.Context(), target)
 		if err != nil {
 			if errors.Is(err, errAmbiguousOwnership) {
@@ -284,6 +308,10 @@ func (a *NamespaceAuthorizer) RequireProviderAccessByID(scope auth.Scope) gin.Ha
 			c.Next()
 			return
 		}
+		if status, msg := ciTokenNamespaceCheck(c, provider.Namespace); status != 0 {
+			abortNamespaceAuthz(c, status, msg)
+			return
+		}
 
 		ownerOrgID, err := a.ownerOrgForArtifact(c.Request.Context(), provider.Namespace, provider.OrganizationID)
 		if err != nil {
@@ -318,6 +346,10 @@ func (a *NamespaceAuthorizer) moduleAccessByID(c *gin.Context, scope auth.Scope)
 	if module == nil {
 		return nil, "", true
 	}
+	if status, msg := ciTokenNamespaceCheck(c, module.Namespace); status != 0 {
+		abortNamespaceAuthz(c, status, msg)
+		return nil, "", false
+	}
 
 	ownerOrgID, err := a.ownerOrgForArtifact(c.Request.Context(), module.Namespace, module.OrganizationID)
 	if err != nil {
@@ -340,6 +372,11 @@ func (a *NamespaceAuthorizer) moduleAccessByID(c *gin.Context, scope auth.Scope)
 // that binds an unclaimed namespace to the caller's organization. Returns true
 // when the request may proceed; on false the request has been aborted.
 func (a *NamespaceAuthorizer) authorizeNamespaceMutation(c *gin.Context, namespace string, scope auth.Scope, allowClaim bool) bool {
+	if status, msg := ciTokenNamespaceCheck(c, namespace); status != 0 {
+		abortNamespaceAuthz(c, status, msg)
+		return false
+	}
+
 	ownerOrgID, err := a.resolveOwnerOrg(c.Request.Context(), namespace)
 	if err != nil {
 		if errors.Is(err, errAmbiguousOwnership) {
@@ -434,6 +471,15 @@ func (a *NamespaceAuthorizer) authorizeOrgAccess(c *gin.Context, ownerOrgID stri
 		return 0, ""
 	}
 
+	// CI publish tokens act for the organization their trust rule names; the
+	// namespace pin is checked by ciTokenNamespaceCheck before this point.
+	if ci, ok := CITokenFromContext(c); ok {
+		if ci.OrganizationID == ownerOrgID {
+			return 0, ""
+		}
+		return http.StatusForbidden, "Namespace is owned by another organization"
+	}
+
 	// API keys are bound to exactly one organization at creation time; that
 	// binding is authoritative for the key regardless of the owning user's
 	// other memberships.
@@ -530,6 +576,10 @@ func (a *NamespaceAuthorizer) resolveCallerOrg(c *gin.Context) (string, int, str
 			return apiKey.OrganizationID, 0, ""
 		}
 	}
+	// CI publish tokens are likewise fixed to their trust rule's organization.
+	if ci, ok := CITokenFromContext(c); ok {
+		return ci.OrganizationID, 0, ""
+	}
 
 	// An explicit target organization may be supplied on the publish request
 	// (organization_id in the JSON body or multipart form; stashed by the
@@ -712,6 +712,46 @@ func TestRequirePublishAccessFromJSON_OrgOverrideAdmin_Allowed(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// RequirePublishAccessFromBody — multipart or JSON (publish-from-URL) upload
+// ---------------------------------------------------------------------------
+
+func TestRequirePublishAccessFromBody_DispatchesOnContentType(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		req  func(t *testing.T) *http.Request
+	}{
+		{"json", func(*testing.T) *http.Request {
+			return jsonRequest("/modules", `{"namespace":"acme","name":"vpc","system":"aws","source_url":"https://example.com/a.tar.gz"}`)
+		}},
+		{"multipart", func(t *testing.T) *http.Request {
+			return multipartRequest(t, map[string]string{"namespace": "acme", "name": "vpc", "system": "aws"})
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mock, authz := newNamespaceAuthzTestDeps(t)
+
+			mock.ExpectQuery("SELECT.*FROM namespace_claims").
+				WillReturnRows(sqlmock.NewRows(claimCols).AddRow("acme", nsOrgB, nil, time.Now()))
+			mock.ExpectQuery("SELECT.*FROM organization_members.*JOIN.*role_templates").
+				WillReturnRows(sqlmock.NewRows(memberRoleColsMW)) // not a member of org B
+
+			r := gin.New()
+			r.POST("/modules",
+				contextSetter(withScopesAndUser([]string{string(auth.ScopeModulesWrite)}, nsUserID)),
+				authz.RequirePublishAccessFromBody(auth.ScopeModulesWrite, 100<<20),
+				func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{"ok": true}) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, tc.req(t))
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want 403 (namespace owned by another org): body=%s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // RequireModuleAccessByID / RequireModuleUpdateAccess / RequireProviderAccessByID
 // ---------------------------------------------------------------------------
@@ -873,3 +913,74 @@ func TestRequireModuleUpdateAccess_MoveToOtherOrgNamespace_Denied(t *testing.T)
 		t.Errorf("status = %d, want 403 (cannot move module into another org's namespace): body=%s", w.Code, w.Body.String())
 	}
 }
+
+// ---------------------------------------------------------------------------
+// CI publish tokens — pinned to one namespace and one organization
+// ---------------------------------------------------------------------------
+
+func withCIToken(orgID, namespace string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		setCITokenContext(c, &auth.CITokenClaims{
+			OrganizationID: orgID,
+			Namespace:      namespace,
+			Scopes:         []string{string(auth.ScopeModulesWrite)},
+			TrustRuleID:    "rule-1",
+		})
+	}
+}
+
+func TestRequireNamespaceAccessFromPath_CIToken_OtherNamespace_Denied(t *testing.T) {
+	mock, authz := newNamespaceAuthzTestDeps(t)
+
+	r := gin.New()
+	r.DELETE("/modules/:namespace/:name/:system",
+		contextSetter(withCIToken(nsOrgA, "acme")),
+		authz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
+		func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+
+	// Same organization, different namespace: rejected before any lookup.
+	w := doNamespaceReq(r, "DELETE", "/modules/acme-other/vpc/aws")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403 (CI token used outside its namespace): body=%s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRequireNamespaceAccessFromPath_CIToken_OwnNamespace_Allowed(t *testing.T) {
+	mock, authz := newNamespaceAuthzTestDeps(t)
+
+	mock.ExpectQuery("SELECT.*FROM namespace_claims").
+		WillReturnRows(sqlmock.NewRows(claimCols).AddRow("acme", nsOrgA, nil, time.Now()))
+
+	r := gin.New()
+	r.DELETE("/modules/:namespace/:name/:system",
+		contextSetter(withCIToken(nsOrgA, "acme")),
+		authz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
+		func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+
+	w := doNamespaceReq(r, "DELETE", "/modules/acme/vpc/aws")
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200: body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRequireNamespaceAccessFromPath_CIToken_OrgMismatch_Denied(t *testing.T) {
+	mock, authz := newNamespaceAuthzTestDeps(t)
+
+	// The namespace has since been claimed by another organization.
+	mock.ExpectQuery("SELECT.*FROM namespace_claims").
+		WillReturnRows(sqlmock.NewRows(claimCols).AddRow("acme", nsOrgB, nil, time.Now()))
+
+	r := gin.New()
+	r.DELETE("/modules/:namespace/:name/:system",
+		contextSetter(withCIToken(nsOrgA, "acme")),
+		authz.RequireNamespaceAccessFromPath(auth.ScopeModulesWrite),
+		func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+
+	w := doNamespaceReq(r, "DELETE", "/modules/acme/vpc/aws")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403 (CI token org does not own namespace): body=%s", w.Code, w.Body.String())
+	}
+}
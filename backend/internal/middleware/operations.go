@@ -0,0 +1,55 @@
+// Package middleware (operations.go) registers long-running requests — uploads
+// and exports — in the in-process operations registry for the duration of the
+// request, so GET /api/v1/admin/operations can list them and DELETE can cancel
+// them through the request context.
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
+)
+
+// TrackOperation records the request as an operation of type opType. Progress
+// is the number of request-body bytes read, out of Content-Length when the
+// client sent one. Place it after authentication and scope checks so rejected
+// requests are not tracked. A nil registry disables tracking.
+func TrackOperation(registry *operations.Registry, opType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if registry == nil {
+			c.Next()
+			return
+		}
+		ctx, op := registry.Start(c.Request.Context(), opType, c.GetString("user_id"), RedactSensitivePath(c.Request.URL.Path))
+		defer op.Finish()
+		if c.Request.ContentLength > 0 {
+			op.SetTotal(c.Request.ContentLength)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = &progressBody{ReadCloser: c.Request.Body, ctx: ctx, op: op}
+		}
+		c.Next()
+	}
+}
+
+// progressBody counts the bytes read from a request body into an operation,
+// and stops reading once the operation is cancelled so a handler blocked on a
+// slow upload (e.g. in ParseMultipartForm) returns promptly.
+type progressBody struct {
+	io.ReadCloser
+	ctx context.Context
+	op  *operations.Operation
+}
+
+func (b *progressBody) Read(p []byte) (int, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.op.AddDone(int64(n))
+	return n, err
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
+)
+
+func TestTrackOperation_RecordsProgress(t *testing.T) {
+	reg := operations.NewRegistry()
+	var seen operations.Info
+	r := gin.New()
+	r.POST("/modules",
+		func(c *gin.Context) { c.Set("user_id", "user-1") },
+		TrackOperation(reg, operations.TypeModuleUpload),
+		func(c *gin.Context) {
+			if _, err := io.Copy(io.Discard, c.Request.Body); err != nil {
+				t.Errorf("reading body: %v", err)
+			}
+			list := reg.List()
+			if len(list) != 1 {
+				t.Fatalf("List() during request = %d operations, want 1", len(list))
+			}
+			seen = list[0]
+			c.Status(http.StatusCreated)
+		})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/modules", strings.NewReader("0123456789")))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if seen.Type != operations.TypeModuleUpload || seen.Actor != "user-1" || seen.Resource != "/modules" {
+		t.Errorf("operation = %+v", seen)
+	}
+	if seen.Done != 10 || seen.Total != 10 {
+		t.Errorf("progress = %d/%d, want 10/10", seen.Done, seen.Total)
+	}
+	if n := len(reg.List()); n != 0 {
+		t.Errorf("%d operations still listed after the request", n)
+	}
+}
+
+func TestTrackOperation_CancelStopsBody(t *testing.T) {
+	reg := operations.NewRegistry()
+	var readErr error
+	var ctxErr error
+	r := gin.New()
+	r.POST("/providers",
+		TrackOperation(reg, operations.TypeProviderUpload),
+		func(c *gin.Context) {
+			reg.Cancel(reg.List()[0].ID)
+			_, readErr = io.ReadAll(c.Request.Body)
+			ctxErr = c.Request.Context().Err()
+			c.Status(http.StatusOK)
+		})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/providers", strings.NewReader("payload")))
+
+	if !errors.Is(readErr, context.Canceled) {
+		t.Errorf("body read error = %v, want context.Canceled", readErr)
+	}
+	if !errors.Is(ctxErr, context.Canceled) {
+		t.Errorf("request context error = %v, want context.Canceled", ctxErr)
+	}
+}
+
+func TestTrackOperation_NilRegistry(t *testing.T) {
+	r := gin.New()
+	r.GET("/export", TrackOperation(nil, operations.TypeAuditExport), func(c *gin.Context) {
+		if operations.FromContext(c.Request.Context()) != nil {
+			t.Error("nil registry attached an operation")
+		}
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d", w.Code)
+	}
+}
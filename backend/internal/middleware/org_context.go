@@ -0,0 +1,93 @@
+// Package middleware (org_context.go) resolves the organization a principal
+// acts for. The auth middlewares record it once per request so quota, audit
+// and protocol handlers read it from the context instead of querying
+// memberships again.
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+const (
+	// OrganizationIDsContextKey holds every organization the principal acts
+	// for, as a []string. It is set (possibly empty) for each authenticated
+	// principal, which tells readers the lookup already happened.
+	OrganizationIDsContextKey = "organization_ids"
+
+	// OrganizationRoleContextKey holds the principal's role template name in
+	// the organization named by organization_id, when it has one.
+	OrganizationRoleContextKey = "organization_role"
+)
+
+// setOrganizationContext records the organizations of an authenticated
+// principal. An organization-bound API key (keyOrgID) acts for that
+// organization only; a user acts for each organization it belongs to. The
+// single organization_id is set for the key's organization, the JWT's org_id
+// claim when the user is a member of it, or an unambiguous sole membership.
+//
+// userID may be empty (a key without a user) and orgRepo may be nil, in which
+// case no memberships are looked up.
+func setOrganizationContext(c *gin.Context, orgRepo *repositories.OrganizationRepository, userID, keyOrgID, claimOrgID string) error {
+	var ids []string
+	var orgID, role string
+
+	if userID != "" && orgRepo != nil {
+		memberships, err := orgRepo.GetUserMemberships(c.Request.Context(), userID)
+		if err != nil {
+			return err
+		}
+		want := keyOrgID
+		if want == "" {
+			want = claimOrgID
+		}
+		if want == "" && len(memberships) == 1 {
+			want = memberships[0].OrganizationID
+		}
+		for _, m := range memberships {
+			if keyOrgID == "" {
+				ids = append(ids, m.OrganizationID)
+			}
+			if m.OrganizationID == want {
+				orgID = m.OrganizationID
+				if m.RoleTemplateName != nil {
+					role = *m.RoleTemplateName
+				}
+			}
+		}
+	}
+	if keyOrgID != "" {
+		ids = []string{keyOrgID}
+		orgID = keyOrgID
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+
+	c.Set(OrganizationIDsContextKey, ids)
+	if orgID != "" {
+		c.Set("organization_id", orgID)
+	}
+	if role != "" {
+		c.Set(OrganizationRoleContextKey, role)
+	}
+	return nil
+}
+
+// OrganizationIDsFromContext returns the organizations the authenticated
+// principal acts for. ok is false when the auth middleware did not resolve
+// them, such as for anonymous requests.
+func OrganizationIDsFromContext(c *gin.Context) (ids []string, ok bool) {
+	v, exists := c.Get(OrganizationIDsContextKey)
+	if !exists {
+		return nil, false
+	}
+	ids, ok = v.([]string)
+	return ids, ok
+}
+
+// OrganizationRoleFromContext returns the principal's role template name in
+// the request's organization_id, or "" when it has none.
+func OrganizationRoleFromContext(c *gin.Context) string {
+	return c.GetString(OrganizationRoleContextKey)
+}
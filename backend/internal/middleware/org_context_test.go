@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// orgContext is what the auth middleware left on the request.
+type orgContext struct {
+	orgID string
+	role  string
+	ids   []string
+}
+
+// captureOrgContext adds a route that records the request's organization
+// context into got.
+func captureOrgContext(r *gin.Engine, got *orgContext) {
+	r.GET("/", func(c *gin.Context) {
+		got.orgID = c.GetString("organization_id")
+		got.role = OrganizationRoleFromContext(c)
+		got.ids, _ = OrganizationIDsFromContext(c)
+		c.Status(http.StatusOK)
+	})
+}
+
+func TestAuthMiddleware_JWT_SingleMembershipSetsOrganization(t *testing.T) {
+	userRepo, userMock := newUserRepo(t)
+	orgRepo, orgMock := newOrgRepo(t)
+	userMock.ExpectQuery("SELECT.*FROM users WHERE id").
+		WillReturnRows(sqlmock.NewRows(jwtUserCols).
+			AddRow("user-1", "test@example.com", "Test User", nil, time.Now(), time.Now()))
+	orgMock.ExpectQuery("SELECT.*FROM organization_members").
+		WillReturnRows(sqlmock.NewRows(jwtMembershipCols).
+			AddRow("org-1", "acme", "rt-1", time.Now(), "publisher", "Publisher", []byte(`["modules:write"]`)))
+
+	var got orgContext
+	r := gin.New()
+	r.Use(AuthMiddleware(nil, userRepo, nil, orgRepo, nil, nil))
+	captureOrgContext(r, &got)
+
+	if code := doAuthRequest(r, "Bearer "+generateTestJWT(t, "user-1")); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	want := orgContext{orgID: "org-1", role: "publisher", ids: []string{"org-1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("org context = %+v, want %+v", got, want)
+	}
+}
+
+func TestAuthMiddleware_JWT_MultipleMembershipsLeaveOrganizationUnset(t *testing.T) {
+	userRepo, userMock := newUserRepo(t)
+	orgRepo, orgMock := newOrgRepo(t)
+	userMock.ExpectQuery("SELECT.*FROM users WHERE id").
+		WillReturnRows(sqlmock.NewRows(jwtUserCols).
+			AddRow("user-1", "test@example.com", "Test User", nil, time.Now(), time.Now()))
+	orgMock.ExpectQuery("SELECT.*FROM organization_members").
+		WillReturnRows(sqlmock.NewRows(jwtMembershipCols).
+			AddRow("org-1", "acme", nil, time.Now(), nil, nil, []byte(`[]`)).
+			AddRow("org-2", "globex", nil, time.Now(), nil, nil, []byte(`[]`)))
+
+	var got orgContext
+	r := gin.New()
+	r.Use(AuthMiddleware(nil, userRepo, nil, orgRepo, nil, nil))
+	captureOrgContext(r, &got)
+
+	if code := doAuthRequest(r, "Bearer "+generateTestJWT(t, "user-1")); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	want := orgContext{ids: []string{"org-1", "org-2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("org context = %+v, want %+v", got, want)
+	}
+}
+
+func TestOptionalAuthMiddleware_APIKeyUsesKeyOrganizationAndOwnerRole(t *testing.T) {
+	apiKeyRepo, apiKeyMock := newTestAPIKeyRepo(t)
+	userRepo, userMock := newUserRepo(t)
+	orgRepo, orgMock := newOrgRepo(t)
+
+	token := "tfr_orgkey_secret"
+	hash, _ := bcrypt.GenerateFromPassword([]byte(token), bcrypt.MinCost)
+	userID := "user-1"
+	apiKeyMock.ExpectQuery("SELECT.*FROM api_keys.*WHERE.*key_prefix").
+		WillReturnRows(sqlmock.NewRows(apiKeyPrefixCols).AddRow(
+			"key-1", &userID, "org-2", "Org Key", nil, string(hash), "tfr_orgkey",
+			[]byte(`["modules:read"]`), nil, nil, nil, time.Now(),
+		))
+	apiKeyMock.ExpectExec("UPDATE api_keys").WillReturnResult(sqlmock.NewResult(0, 1))
+	userMock.ExpectQuery("SELECT.*FROM users WHERE id").
+		WillReturnRows(sqlmock.NewRows(jwtUserCols).
+			AddRow("user-1", "test@example.com", "Test User", nil, time.Now(), time.Now()))
+	orgMock.ExpectQuery("SELECT.*FROM organization_members").
+		WillReturnRows(sqlmock.NewRows(jwtMembershipCols).
+			AddRow("org-1", "acme", "rt-1", time.Now(), "admin", "Admin", []byte(`["admin"]`)).
+			AddRow("org-2", "globex", "rt-2", time.Now(), "viewer", "Viewer", []byte(`["modules:read"]`)))
+
+	var got orgContext
+	r := gin.New()
+	r.Use(OptionalAuthMiddleware(nil, userRepo, apiKeyRepo, orgRepo, nil, nil))
+	captureOrgContext(r, &got)
+
+	if code := doAuthRequest(r, "Bearer "+token); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	// The key acts for its own organization only, with the owner's role there.
+	want := orgContext{orgID: "org-2", role: "viewer", ids: []string{"org-2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("org context = %+v, want %+v", got, want)
+	}
+}
+
+func TestOptionalAuthMiddleware_MembershipErrorAborts(t *testing.T) {
+	userRepo, userMock := newUserRepo(t)
+	orgRepo, orgMock := newOrgRepo(t)
+	userMock.ExpectQuery("SELECT.*FROM users WHERE id").
+		WillReturnRows(sqlmock.NewRows(jwtUserCols).
+			AddRow("user-1", "test@example.com", "Test User", nil, time.Now(), time.Now()))
+	orgMock.ExpectQuery("SELECT.*FROM organization_members").WillReturnError(errors.New("db error"))
+
+	r := gin.New()
+	r.Use(OptionalAuthMiddleware(nil, userRepo, nil, orgRepo, nil, nil))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Continuing anonymously would skip the caller's organization policies.
+	if code := doAuthRequest(r, "Bearer "+generateTestJWT(t, "user-1")); code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", code)
+	}
+}
+
+func TestSetOrganizationContext_KeyWithoutUser(t *testing.T) {
+	c, _ := gin.CreateTestContext(nil)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+
+	// No user means no membership lookup, so a nil repository is never used.
+	var orgRepo *repositories.OrganizationRepository
+	if err := setOrganizationContext(c, orgRepo, "", "org-1", ""); err != nil {
+		t.Fatalf("setOrganizationContext: %v", err)
+	}
+	if ids, ok := OrganizationIDsFromContext(c); !ok || !reflect.DeepEqual(ids, []string{"org-1"}) {
+		t.Errorf("organization_ids = %v, %v; want [org-1]", ids, ok)
+	}
+	if c.GetString("organization_id") != "org-1" || OrganizationRoleFromContext(c) != "" {
+		t.Errorf("organization_id = %q, role = %q", c.GetString("organization_id"), OrganizationRoleFromContext(c))
+	}
+}
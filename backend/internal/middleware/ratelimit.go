@@ -11,12 +11,16 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/telemetry"
 )
 
 // RateLimitConfig holds configuration for rate limiting
 type RateLimitConfig struct {
+	// Name identifies the limiter in the limiter label of the rate-limit
+	// metrics (e.g. "general", "auth"); empty reports as "unnamed".
+	Name string
 	// RequestsPerMinute is the maximum number of requests allowed per minute
 	RequestsPerMinute int
 	// BurstSize is the maximum burst of requests allowed
@@ -28,6 +32,7 @@ type RateLimitConfig struct {
 // DefaultRateLimitConfig returns sensible defaults
 func DefaultRateLimitConfig() RateLimitConfig {
 	return RateLimitConfig{
+		Name:              "general",
 		RequestsPerMinute: 200, // Higher limit for authenticated API usage
 		BurstSize:         50,  // Allow burst for pages that load multiple resources
 		CleanupInterval:   5 * time.Minute,
@@ -40,6 +45,7 @@ func DefaultRateLimitConfig() RateLimitConfig {
 // plus headroom for multi-provider probes and retries).
 func AuthRateLimitConfig() RateLimitConfig {
 	return RateLimitConfig{
+		Name:              "auth",
 		RequestsPerMinute: 20, // 20 auth requests per minute
 		BurstSize:         15,
 		CleanupInterval:   5 * time.Minute,
@@ -49,12 +55,26 @@ func AuthRateLimitConfig() RateLimitConfig {
 // UploadRateLimitConfig returns limits for upload endpoints
 func UploadRateLimitConfig() RateLimitConfig {
 	return RateLimitConfig{
+		Name:              "upload",
 		RequestsPerMinute: 30, // 30 uploads per minute
 		BurstSize:         5,
 		CleanupInterval:   5 * time.Minute,
 	}
 }
 
+// DevRateLimitConfig returns limits for the dev-mode login and impersonation
+// endpoints. They apply whether or not rate limiting is enabled in config:
+// each request mints an admin-capable session, so a caller needs a handful,
+// not a stream.
+func DevRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Name:              "dev",
+		RequestsPerMinute: 10,
+		BurstSize:         5,
+		CleanupInterval:   5 * time.Minute,
+	}
+}
+
 // RateLimiterBackend is the interface that rate limiter backends must satisfy.
 // Implementations include the in-memory token bucket (MemoryRateLimiter) and
 // the Redis-backed GCRA limiter (RedisRateLimiter).
@@ -74,6 +94,19 @@ type RateLimiterBackend interface {
 	Close() error
 }
 
+// namedLimiter is implemented by backends that know their RateLimitConfig.Name.
+type namedLimiter interface {
+	LimiterName() string
+}
+
+// limiterName returns the limiter label for backend's rate-limit metrics.
+func limiterName(backend RateLimiterBackend) string {
+	if n, ok := backend.(namedLimiter); ok && n.LimiterName() != "" {
+		return n.LimiterName()
+	}
+	return "unnamed"
+}
+
 // OrgRateLimiterConfig holds configuration for per-organization rate limiting.
 type OrgRateLimiterConfig struct {
 	RequestsPerMinute int
@@ -125,12 +158,15 @@ func (rl *MemoryRateLimiter) cleanup() {
 		case <-ticker.C:
 			rl.mu.Lock()
 			now := time.Now()
+			removed := 0
 			for key, entry := range rl.entries {
 				// Remove entries that haven't been accessed in 10 minutes
 				if now.Sub(entry.lastUpdate) > 10*time.Minute {
 					delete(rl.entries, key)
+					removed++
 				}
 			}
+			rl.activeBuckets().Sub(float64(removed))
 			rl.mu.Unlock()
 		case <-rl.stopCh:
 			return
@@ -138,9 +174,25 @@ func (rl *MemoryRateLimiter) cleanup() {
 	}
 }
 
-// Stop stops the cleanup goroutine
+// Stop stops the cleanup goroutine and withdraws the limiter's buckets from
+// the active-bucket gauge.
 func (rl *MemoryRateLimiter) Stop() {
 	close(rl.stopCh)
+	rl.mu.Lock()
+	rl.activeBuckets().Sub(float64(len(rl.entries)))
+	rl.entries = make(map[string]*rateLimitEntry)
+	rl.mu.Unlock()
+}
+
+// LimiterName returns the configured limiter name.
+func (rl *MemoryRateLimiter) LimiterName() string {
+	return rl.config.Name
+}
+
+// activeBuckets is the gauge tracking this limiter's entries. Limiters sharing
+// a name (the principal overrides) add into the same series.
+func (rl *MemoryRateLimiter) activeBuckets() prometheus.Gauge {
+	return telemetry.RateLimiterActiveBuckets.WithLabelValues(limiterName(rl))
 }
 
 // Close implements RateLimiterBackend.
@@ -166,6 +218,7 @@ func (rl *MemoryRateLimiter) Allow(_ context.Context, key string) (bool, int, er
 			tokens:     tokens,
 			lastUpdate: now,
 		}
+		rl.activeBuckets().Inc()
 		return true, int(tokens), nil
 	}
 
@@ -232,6 +285,7 @@ func RateLimitMiddleware(backend RateLimiterBackend) gin.HandlerFunc {
 			c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
 			c.Header("Retry-After", "60")
 			telemetry.RateLimitRejectionsTotal.WithLabelValues(tierFromPrincipal(principal), keyTypeFromPrincipal(principal)).Inc()
+			telemetry.RateLimiterRejectionsTotal.WithLabelValues(limiterName(backend)).Inc()
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error":       "Rate limit exceeded",
 				"retry_after": 60,
@@ -272,6 +326,7 @@ func OrgRateLimitMiddleware(individual RateLimiterBackend, orgBackend RateLimite
 			c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
 			c.Header("Retry-After", "60")
 			telemetry.RateLimitRejectionsTotal.WithLabelValues("individual", keyTypeFromPrincipal(principal)).Inc()
+			telemetry.RateLimiterRejectionsTotal.WithLabelValues(limiterName(individual)).Inc()
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error":       "Rate limit exceeded",
 				"retry_after": 60,
@@ -292,6 +347,7 @@ func OrgRateLimitMiddleware(individual RateLimiterBackend, orgBackend RateLimite
 						c.Header("X-RateLimit-Remaining", strconv.Itoa(orgRemaining))
 						c.Header("Retry-After", "60")
 						telemetry.RateLimitRejectionsTotal.WithLabelValues("organization", "org").Inc()
+						telemetry.RateLimiterRejectionsTotal.WithLabelValues(limiterName(orgBackend)).Inc()
 						c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 							"error":       "Organization rate limit exceeded",
 							"retry_after": 60,
@@ -321,6 +377,7 @@ func NewPrincipalOverrideLimiters(overrides map[string]config.PrincipalRateLimit
 	m := make(map[string]RateLimiterBackend, len(overrides))
 	for key, ov := range overrides {
 		cfg := RateLimitConfig{
+			Name:              "principal_override",
 			RequestsPerMinute: ov.RequestsPerMinute,
 			BurstSize:         ov.Burst,
 			CleanupInterval:   5 * time.Minute,
@@ -376,6 +433,7 @@ func PrincipalRateLimitMiddleware(defaultBackend RateLimiterBackend, overrides *
 			c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
 			c.Header("Retry-After", "60")
 			telemetry.RateLimitRejectionsTotal.WithLabelValues("principal", keyTypeFromPrincipal(principal)).Inc()
+			telemetry.RateLimiterRejectionsTotal.WithLabelValues(limiterName(backend)).Inc()
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error":       "Rate limit exceeded",
 				"retry_after": 60,
@@ -21,6 +21,7 @@ type RedisRateLimiter struct {
 	client  *redis.Client
 	limiter *redis_rate.Limiter
 	limit   redis_rate.Limit
+	name    string
 }
 
 // NewRedisRateLimiter creates a Redis-backed rate limiter. The cfg parameter
@@ -65,9 +66,15 @@ func NewRedisRateLimiter(cfg *config.RedisConfig, rlCfg RateLimitConfig) (*Redis
 		client:  client,
 		limiter: limiter,
 		limit:   limit,
+		name:    rlCfg.Name,
 	}, nil
 }
 
+// LimiterName returns the configured limiter name.
+func (r *RedisRateLimiter) LimiterName() string {
+	return r.name
+}
+
 // Allow checks whether the request identified by key should be permitted, and
 // returns the remaining GCRA quota from the same probe. Callers should use this
 // remaining value directly (e.g. for the X-RateLimit-Remaining header) instead of
@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
 )
 
 // ---------------------------------------------------------------------------
@@ -49,6 +51,16 @@ func TestUploadRateLimitConfig(t *testing.T) {
 	}
 }
 
+func TestDevRateLimitConfig(t *testing.T) {
+	cfg := DevRateLimitConfig()
+	if cfg.RequestsPerMinute != 10 {
+		t.Errorf("RequestsPerMinute = %d, want 10", cfg.RequestsPerMinute)
+	}
+	if cfg.BurstSize != 5 {
+		t.Errorf("BurstSize = %d, want 5", cfg.BurstSize)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // MemoryRateLimiter.Allow (via RateLimiterBackend interface)
 // ---------------------------------------------------------------------------
@@ -653,3 +665,58 @@ func TestPrincipalRateLimitMiddleware_NilDefault(t *testing.T) {
 		t.Error("should pass through when default backend is nil")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Limiter metrics
+// ---------------------------------------------------------------------------
+
+func TestRateLimitMiddleware_RejectionCountedByLimiter(t *testing.T) {
+	rl := NewRateLimiter(RateLimitConfig{Name: "upload", RequestsPerMinute: 1, BurstSize: 1, CleanupInterval: time.Hour})
+	defer rl.Stop()
+	r := newRateLimitRouter(rl)
+	counter := telemetry.RateLimiterRejectionsTotal.WithLabelValues("upload")
+	before := testutil.ToFloat64(counter)
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.40:1234"
+		r.ServeHTTP(w, req)
+	}
+
+	if got := testutil.ToFloat64(counter) - before; got != 1 {
+		t.Errorf("tfr_ratelimit_rejections_total{limiter=upload} grew by %v, want 1", got)
+	}
+}
+
+func TestMemoryRateLimiter_ActiveBucketsGauge(t *testing.T) {
+	rl := NewRateLimiter(RateLimitConfig{Name: "gauge-test", RequestsPerMinute: 60, BurstSize: 5, CleanupInterval: time.Hour})
+	gauge := telemetry.RateLimiterActiveBuckets.WithLabelValues("gauge-test")
+
+	ctx := context.Background()
+	rl.Allow(ctx, "a")
+	rl.Allow(ctx, "a")
+	rl.Allow(ctx, "b")
+	if got := testutil.ToFloat64(gauge); got != 2 {
+		t.Errorf("active buckets = %v, want 2", got)
+	}
+
+	rl.Stop()
+	if got := testutil.ToFloat64(gauge); got != 0 {
+		t.Errorf("active buckets after Stop = %v, want 0", got)
+	}
+}
+
+func TestLimiterName(t *testing.T) {
+	named := NewRateLimiter(AuthRateLimitConfig())
+	defer named.Stop()
+	unnamed := newTestLimiter(60, 5)
+	defer unnamed.Stop()
+
+	if got := limiterName(named); got != "auth" {
+		t.Errorf("limiterName(auth) = %q, want auth", got)
+	}
+	if got := limiterName(unnamed); got != "unnamed" {
+		t.Errorf("limiterName(unnamed) = %q, want unnamed", got)
+	}
+}
@@ -50,6 +50,7 @@ func RequireScope(scope auth.Scope) gin.HandlerFunc {
 		}
 
 		if !auth.HasScope(userScopes, scope) {
+			recordScopeFailure(c)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "Missing required scope",
 				"details": "Required scope: " + string(scope),
@@ -81,6 +82,7 @@ func RequireAnyScope(scopes ...auth.Scope) gin.HandlerFunc {
 		}
 
 		if !auth.HasAnyScope(userScopes, scopes) {
+			recordScopeFailure(c)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": "Missing required scope",
 			})
@@ -111,6 +113,7 @@ func RequireAllScopes(scopes ...auth.Scope) gin.HandlerFunc {
 		}
 
 		if !auth.HasAllScopes(userScopes, scopes) {
+			recordScopeFailure(c)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": "Missing one or more required scopes",
 			})
@@ -205,6 +208,7 @@ func RequireOrgScopeForPathOrg(scope auth.Scope, orgRepo *repositories.Organizat
 		}
 
 		if !auth.HasScope(orgScopes, scope) {
+			recordScopeFailure(c)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "Missing required scope for this organization",
 				"details": "Required scope: " + string(scope),
@@ -332,6 +336,7 @@ func RequireOrgScope(scope auth.Scope, orgRepo *repositories.OrganizationReposit
 
 		// Check if user has required scope via role template
 		if !auth.HasScope(memberWithRole.RoleTemplateScopes, scope) {
+			recordScopeFailure(c)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "Missing required scope for organization",
 				"details": "Required scope: " + string(scope),
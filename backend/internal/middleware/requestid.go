@@ -34,7 +34,7 @@ const (
 //
 //	router.Use(RecoveryMiddleware())
 //	router.Use(RequestIDMiddleware())
-//	router.Use(MetricsMiddleware())
+//	router.Use(MetricsMiddleware(&cfg.Telemetry))
 //	router.Use(LoggerMiddleware(cfg))
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
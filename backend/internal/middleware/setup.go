@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -84,21 +85,53 @@ func (rl *setupRateLimiter) allow(ip string) bool {
 	return true
 }
 
+// SetupTxContextKey is the context key holding the *sqlx.Tx whose row lock on
+// system_settings a setup request runs under (see SetupTx).
+const SetupTxContextKey = "setup_tx"
+
+// ErrCodeSetupCompleted is the stable "code" of the 410 response returned by
+// setup endpoints once setup has been completed.
+const ErrCodeSetupCompleted = "setup_already_completed"
+
+// setupCompletePath is the route that completes setup. It takes the exclusive
+// lock on the setup state; every other setup route takes a shared one.
+const setupCompletePath = "/api/v1/setup/complete"
+
+// SetupTx returns the transaction SetupTokenMiddleware holds the setup state
+// lock in, or nil when the request did not pass through the middleware. The
+// complete-setup handler commits through it; the middleware rolls it back
+// once the handler returns if it is still open.
+func SetupTx(c *gin.Context) *sqlx.Tx {
+	if v, ok := c.Get(SetupTxContextKey); ok {
+		if tx, ok := v.(*sqlx.Tx); ok {
+			return tx
+		}
+	}
+	return nil
+}
+
 // SetupTokenMiddleware validates setup token authentication. It checks that:
-//  1. Setup has not already been completed (returns 403 if it has).
-//  2. The Authorization header contains a valid "SetupToken <token>" value.
-//  3. The token matches the bcrypt hash stored in system_settings.
-//  4. The IP is not rate-limited (max 5 attempts per minute).
+//  1. Setup has not already been completed (returns 410 if it has).
+//  2. The IP is not rate-limited (max 5 attempts per minute).
+//  3. The Authorization header contains a valid "SetupToken <token>" value.
+//  4. The token matches the bcrypt hash stored in system_settings.
+//
+// The checks read the setup state under a row lock held until the handler
+// returns, so a request cannot pass them while CompleteSetup is committing:
+// the complete route locks exclusively, and requests queued behind it see the
+// completed state and get a 410.
 //
-// On success, sets SetupTokenContextKey=true in the gin context and calls c.Next().
+// On success, sets SetupTokenContextKey=true and SetupTxContextKey in the gin
+// context and calls c.Next().
 func SetupTokenMiddleware(oidcConfigRepo *repositories.OIDCConfigRepository) gin.HandlerFunc {
 	rateLimiter := newSetupRateLimiter()
 
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 
-		// 1. Check if setup is already completed — block unless there are pending features
-		completed, err := oidcConfigRepo.IsSetupCompleted(ctx)
+		// 1. Lock and read the setup state — block completed setup unless there
+		// are pending features
+		tx, state, err := oidcConfigRepo.LockSetupState(ctx, c.FullPath() == setupCompletePath)
 		if err != nil {
 			slog.Error("setup middleware: failed to check setup status", "error", err)
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
@@ -106,25 +139,19 @@ func SetupTokenMiddleware(oidcConfigRepo *repositories.OIDCConfigRepository) gin
 			})
 			return
 		}
-		if completed {
-			// Allow through only if there is an unconfigured feature added in a
-			// later release (e.g. scanning) AND the requested route is part of
-			// that feature's own setup surface. OIDC/LDAP/storage/admin stay
-			// permanently disabled even when a feature is pending (issue #649).
-			hasPending, pendingErr := oidcConfigRepo.HasPendingFeatureSetup(ctx)
-			if pendingErr != nil {
-				slog.Error("setup middleware: failed to check pending features", "error", pendingErr)
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-					"error": "Failed to check setup status",
-				})
-				return
-			}
-			if !hasPending || !featureSetupAllowedPaths[c.FullPath()] {
-				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-					"error": "Setup has already been completed. These endpoints are permanently disabled.",
-				})
-				return
-			}
+		defer func() { _ = tx.Rollback() }()
+
+		// Allow completed setup through only if there is an unconfigured
+		// feature added in a later release (e.g. scanning) AND the requested
+		// route is part of that feature's own setup surface. OIDC/LDAP/storage/
+		// admin stay permanently disabled even when a feature is pending
+		// (issue #649).
+		if state.Completed && (!state.PendingFeature || !featureSetupAllowedPaths[c.FullPath()]) {
+			c.AbortWithStatusJSON(http.StatusGone, gin.H{
+				"error": "Setup has already been completed. These endpoints are permanently disabled.",
+				"code":  ErrCodeSetupCompleted,
+			})
+			return
 		}
 
 		// 2. Rate limit check before doing any bcrypt work
@@ -155,15 +182,8 @@ func SetupTokenMiddleware(oidcConfigRepo *repositories.OIDCConfigRepository) gin
 		}
 		rawToken := strings.TrimSpace(parts[1])
 
-		// 4. Retrieve stored hash
-		storedHash, err := oidcConfigRepo.GetSetupTokenHash(ctx)
-		if err != nil {
-			slog.Error("setup middleware: failed to get token hash", "error", err)
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to validate setup token",
-			})
-			return
-		}
+		// 4. Check the token against the stored hash
+		storedHash := state.TokenHash
 		if storedHash == "" {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": "No setup token has been generated. Restart the server to generate one.",
@@ -171,7 +191,7 @@ func SetupTokenMiddleware(oidcConfigRepo *repositories.OIDCConfigRepository) gin
 			return
 		}
 
-		// 5. Verify token against bcrypt hash
+		// Verify token against bcrypt hash
 		if err := bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(rawToken)); err != nil {
 			slog.Warn("setup middleware: invalid setup token", "ip", clientIP)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
@@ -182,6 +202,7 @@ func SetupTokenMiddleware(oidcConfigRepo *repositories.OIDCConfigRepository) gin
 
 		// Token is valid — set context flag and continue
 		c.Set(SetupTokenContextKey, true)
+		c.Set(SetupTxContextKey, tx)
 		c.Next()
 	}
 }
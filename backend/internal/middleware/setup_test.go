@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -78,6 +79,16 @@ func doFullSetupRequestMethod(r *gin.Engine, method, path, authHeader string) *h
 	return w
 }
 
+// expectSetupState registers the transaction SetupTokenMiddleware reads the
+// setup state in: begin, the locking SELECT, and the rollback that ends it
+// once the request is done.
+func expectSetupState(mock sqlmock.Sqlmock, completed, pending bool, hash interface{}) {
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT setup_completed, .* FROM system_settings WHERE id = 1 FOR").
+		WillReturnRows(sqlmock.NewRows([]string{"completed", "pending", "hash"}).AddRow(completed, pending, hash))
+	mock.ExpectRollback()
+}
+
 func doSetupRequest(r *gin.Engine, authHeader string) *httptest.ResponseRecorder {
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -94,15 +105,20 @@ func doSetupRequest(r *gin.Engine, authHeader string) *httptest.ResponseRecorder
 
 func TestSetupMiddleware_SetupCompleted(t *testing.T) {
 	mock, r := newSetupRouter(t)
-	mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(true))
-	// HasPendingFeatureSetup check — no pending features
-	mock.ExpectQuery(`SELECT setup_completed AND \(NOT scanning_configured\) FROM system_settings`).
-		WillReturnRows(sqlmock.NewRows([]string{"pending"}).AddRow(false))
+	// Completed with no pending features; the cleared hash is never reached.
+	expectSetupState(mock, true, false, "")
 
 	w := doSetupRequest(r, "SetupToken valid-token")
-	if w.Code != http.StatusForbidden {
-		t.Errorf("status = %d, want 403", w.Code)
+	if w.Code != http.StatusGone {
+		t.Errorf("status = %d, want 410", w.Code)
+	}
+	var body map[string]string
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if body["code"] != ErrCodeSetupCompleted {
+		t.Errorf("code = %q, want %q", body["code"], ErrCodeSetupCompleted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
 	}
 }
 
@@ -112,8 +128,10 @@ func TestSetupMiddleware_SetupCompleted(t *testing.T) {
 
 func TestSetupMiddleware_SetupCheckError(t *testing.T) {
 	mock, r := newSetupRouter(t)
-	mock.ExpectQuery("SELECT setup_completed FROM system_settings").
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT setup_completed").
 		WillReturnError(errors.New("db down"))
+	mock.ExpectRollback()
 
 	w := doSetupRequest(r, "SetupToken valid-token")
 	if w.Code != http.StatusInternalServerError {
@@ -127,8 +145,7 @@ func TestSetupMiddleware_SetupCheckError(t *testing.T) {
 
 func TestSetupMiddleware_MissingHeader(t *testing.T) {
 	mock, r := newSetupRouter(t)
-	mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(false))
+	expectSetupState(mock, false, false, "")
 
 	w := doSetupRequest(r, "")
 	if w.Code != http.StatusUnauthorized {
@@ -142,8 +159,7 @@ func TestSetupMiddleware_MissingHeader(t *testing.T) {
 
 func TestSetupMiddleware_WrongScheme(t *testing.T) {
 	mock, r := newSetupRouter(t)
-	mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(false))
+	expectSetupState(mock, false, false, "")
 
 	w := doSetupRequest(r, "Bearer some-jwt")
 	if w.Code != http.StatusUnauthorized {
@@ -157,12 +173,8 @@ func TestSetupMiddleware_WrongScheme(t *testing.T) {
 
 func TestSetupMiddleware_NoStoredHash(t *testing.T) {
 	mock, r := newSetupRouter(t)
-	// setup_completed = false
-	mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(false))
-	// setup_token_hash is NULL
-	mock.ExpectQuery("SELECT setup_token_hash FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_token_hash"}).AddRow(nil))
+	// setup_completed = false, setup_token_hash is NULL
+	expectSetupState(mock, false, false, "")
 
 	w := doSetupRequest(r, "SetupToken some-token")
 	if w.Code != http.StatusForbidden {
@@ -170,23 +182,6 @@ func TestSetupMiddleware_NoStoredHash(t *testing.T) {
 	}
 }
 
-// ---------------------------------------------------------------------------
-// SetupTokenMiddleware — hash retrieval error
-// ---------------------------------------------------------------------------
-
-func TestSetupMiddleware_HashRetrievalError(t *testing.T) {
-	mock, r := newSetupRouter(t)
-	mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(false))
-	mock.ExpectQuery("SELECT setup_token_hash FROM system_settings").
-		WillReturnError(errors.New("connection lost"))
-
-	w := doSetupRequest(r, "SetupToken some-token")
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("status = %d, want 500", w.Code)
-	}
-}
-
 // ---------------------------------------------------------------------------
 // SetupTokenMiddleware — wrong token
 // ---------------------------------------------------------------------------
@@ -196,10 +191,7 @@ func TestSetupMiddleware_WrongToken(t *testing.T) {
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte("correct-token"), bcrypt.MinCost)
 
-	mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(false))
-	mock.ExpectQuery("SELECT setup_token_hash FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_token_hash"}).AddRow(string(hash)))
+	expectSetupState(mock, false, false, string(hash))
 
 	w := doSetupRequest(r, "SetupToken wrong-token")
 	if w.Code != http.StatusUnauthorized {
@@ -217,10 +209,7 @@ func TestSetupMiddleware_ValidToken(t *testing.T) {
 	token := "my-valid-setup-token"
 	hash, _ := bcrypt.GenerateFromPassword([]byte(token), bcrypt.MinCost)
 
-	mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(false))
-	mock.ExpectQuery("SELECT setup_token_hash FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_token_hash"}).AddRow(string(hash)))
+	expectSetupState(mock, false, false, string(hash))
 
 	w := doSetupRequest(r, "SetupToken "+token)
 	if w.Code != http.StatusOK {
@@ -238,10 +227,7 @@ func TestSetupMiddleware_CaseInsensitiveScheme(t *testing.T) {
 	token := "my-valid-setup-token"
 	hash, _ := bcrypt.GenerateFromPassword([]byte(token), bcrypt.MinCost)
 
-	mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(false))
-	mock.ExpectQuery("SELECT setup_token_hash FROM system_settings").
-		WillReturnRows(sqlmock.NewRows([]string{"setup_token_hash"}).AddRow(string(hash)))
+	expectSetupState(mock, false, false, string(hash))
 
 	w := doSetupRequest(r, "setuptoken "+token)
 	if w.Code != http.StatusOK {
@@ -299,7 +285,7 @@ func TestSetupRateLimiter_DifferentIPsIndependent(t *testing.T) {
 //
 // The Authorization header carries a real, correctly bcrypt-hashed setup
 // token (mocked identically to the positive AllowsScanningEndpoint case
-// below), not a throwaway string -- so the 403 asserted here demonstrates
+// below), not a throwaway string -- so the 410 asserted here demonstrates
 // that the route-scoping gate itself blocks a validly-reminted token,
 // rather than merely reflecting an unrelated sqlmock "unexpected query"
 // 500 that would occur regardless of whether the gate exists. If the
@@ -325,21 +311,15 @@ func TestSetupMiddleware_PendingFeature_BlocksIdentityEndpoints(t *testing.T) {
 			token := "re-minted-setup-token"
 			hash, _ := bcrypt.GenerateFromPassword([]byte(token), bcrypt.MinCost)
 
-			mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-				WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(true))
-			mock.ExpectQuery(`SELECT setup_completed AND \(NOT scanning_configured\) FROM system_settings`).
-				WillReturnRows(sqlmock.NewRows([]string{"pending"}).AddRow(true))
-			// Registered but expected to go unconsumed: the route-scoping gate
-			// must abort with 403 before ever reaching the token-hash check. If
-			// the gate is removed, the middleware would query this hash next,
-			// the valid token below would match it, and the request would
+			// The state carries the valid token's hash: the route-scoping gate
+			// must abort with 410 before ever checking it. If the gate is
+			// removed, the valid token below would match and the request would
 			// wrongly succeed with 200 -- which is exactly the exploit #649 fixed.
-			mock.ExpectQuery("SELECT setup_token_hash FROM system_settings").
-				WillReturnRows(sqlmock.NewRows([]string{"setup_token_hash"}).AddRow(string(hash)))
+			expectSetupState(mock, true, true, string(hash))
 
 			w := doFullSetupRequestMethod(r, tc.method, tc.path, "SetupToken "+token)
-			if w.Code != http.StatusForbidden {
-				t.Errorf("%s %s: status = %d, want 403 (a valid re-minted setup token must not reach this endpoint)", tc.method, tc.path, w.Code)
+			if w.Code != http.StatusGone {
+				t.Errorf("%s %s: status = %d, want 410 (a valid re-minted setup token must not reach this endpoint)", tc.method, tc.path, w.Code)
 			}
 		})
 	}
@@ -356,12 +336,7 @@ func TestSetupMiddleware_PendingFeature_AllowsScanningEndpoint(t *testing.T) {
 			token := "feature-setup-token"
 			hash, _ := bcrypt.GenerateFromPassword([]byte(token), bcrypt.MinCost)
 
-			mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-				WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(true))
-			mock.ExpectQuery(`SELECT setup_completed AND \(NOT scanning_configured\) FROM system_settings`).
-				WillReturnRows(sqlmock.NewRows([]string{"pending"}).AddRow(true))
-			mock.ExpectQuery("SELECT setup_token_hash FROM system_settings").
-				WillReturnRows(sqlmock.NewRows([]string{"setup_token_hash"}).AddRow(string(hash)))
+			expectSetupState(mock, true, true, string(hash))
 
 			w := doFullSetupRequest(r, path, "SetupToken "+token)
 			if w.Code != http.StatusOK {
@@ -383,8 +358,7 @@ func TestSetupMiddleware_RateLimitExceeded(t *testing.T) {
 
 	// Make setupMaxAttempts + 1 requests (each needs completed check)
 	for i := 0; i <= setupMaxAttempts; i++ {
-		mock.ExpectQuery("SELECT setup_completed FROM system_settings").
-			WillReturnRows(sqlmock.NewRows([]string{"setup_completed"}).AddRow(false))
+		expectSetupState(mock, false, false, "")
 	}
 
 	var lastCode int
@@ -396,3 +370,43 @@ func TestSetupMiddleware_RateLimitExceeded(t *testing.T) {
 		t.Errorf("after exceeding rate limit, status = %d, want 429", lastCode)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// SetupTokenMiddleware — setup state lock
+// ---------------------------------------------------------------------------
+
+// TestSetupMiddleware_LockMode checks that the complete route reads the setup
+// state under the exclusive lock, other setup routes under the shared one,
+// and that the handler gets the transaction the lock is held in.
+func TestSetupMiddleware_LockMode(t *testing.T) {
+	for path, lock := range map[string]string{
+		"/api/v1/setup/complete":       "FOR UPDATE",
+		"/api/v1/setup/validate-token": "FOR KEY SHARE",
+	} {
+		t.Run(path, func(t *testing.T) {
+			repo, mock := newOIDCConfigRepo(t)
+			token := "setup-token"
+			hash, _ := bcrypt.GenerateFromPassword([]byte(token), bcrypt.MinCost)
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT setup_completed, .* " + lock).
+				WillReturnRows(sqlmock.NewRows([]string{"completed", "pending", "hash"}).AddRow(false, false, string(hash)))
+			mock.ExpectRollback()
+
+			var gotTx bool
+			r := gin.New()
+			r.POST(path, SetupTokenMiddleware(repo), func(c *gin.Context) {
+				gotTx = SetupTx(c) != nil
+				c.Status(http.StatusOK)
+			})
+			if w := doFullSetupRequest(r, path, "SetupToken "+token); w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			if !gotTx {
+				t.Error("SetupTx returned nil in the handler")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Package middleware (tenant_domains.go) resolves custom tenant domains (see
+// models.TenantDomain): hostnames that serve one organization's view of a
+// shared deployment.
+//
+// TenantMiddleware matches the request Host against the configured domains.
+// On a match, handlers that build absolute URLs use the request's own origin
+// instead of server.public_url/base_url, and search endpoints scope their
+// results to the domain's organization. Any other host keeps the
+// single-tenant behavior. The request Host is only ever echoed back after it
+// has matched a configured domain, so a forged Host header cannot redirect
+// generated URLs. Like the mirror allowlist, domains are cached for a short
+// TTL and invalidated by admin writes on this replica.
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// tenantContextKey holds the *Tenant of a request made on a tenant domain.
+const tenantContextKey = "tenant"
+
+// TenantDomainLoader loads the configured tenant domains.
+type TenantDomainLoader interface {
+	List(ctx context.Context) ([]*models.TenantDomain, error)
+}
+
+// TenantDomains caches the tenant domains by hostname.
+type TenantDomains struct {
+	loader TenantDomainLoader
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	domains  map[string]*models.TenantDomain
+	loadedAt time.Time
+	loaded   bool
+}
+
+// NewTenantDomains creates a tenant domain cache backed by loader. A
+// non-positive ttl selects DefaultMirrorAllowlistTTL.
+func NewTenantDomains(loader TenantDomainLoader, ttl time.Duration) *TenantDomains {
+	if ttl <= 0 {
+		ttl = DefaultMirrorAllowlistTTL
+	}
+	return &TenantDomains{loader: loader, ttl: ttl, now: time.Now}
+}
+
+// Invalidate forces the next lookup to reload the domains. Call it after
+// every tenant domain write.
+func (d *TenantDomains) Invalidate() {
+	d.mu.Lock()
+	d.loaded = false
+	d.mu.Unlock()
+}
+
+// Resolve returns the tenant domain for a request Host, or nil. A nil
+// *TenantDomains has no domains. When a reload fails the last loaded domains
+// stay in force; if nothing has been loaded yet the error is returned.
+func (d *TenantDomains) Resolve(ctx context.Context, host string) (*models.TenantDomain, error) {
+	if d == nil {
+		return nil, nil
+	}
+	domains, err := d.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domains[models.TenantHostname(host)], nil
+}
+
+func (d *TenantDomains) current(ctx context.Context) (map[string]*models.TenantDomain, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.loaded && d.now().Sub(d.loadedAt) < d.ttl {
+		return d.domains, nil
+	}
+	list, err := d.loader.List(ctx)
+	if err != nil {
+		if d.domains != nil {
+			slog.Warn("tenant domain reload failed, keeping previous domains", "error", err)
+			return d.domains, nil
+		}
+		return nil, err
+	}
+	domains := make(map[string]*models.TenantDomain, len(list))
+	for _, td := range list {
+		domains[td.Hostname] = td
+	}
+	d.domains, d.loadedAt, d.loaded = domains, d.now(), true
+	return domains, nil
+}
+
+// Tenant is the tenant domain a request was made on.
+type Tenant struct {
+	Domain *models.TenantDomain
+	// PublicURL is the request's own origin (scheme://host), used in place of
+	// server.public_url for URLs handed back to the client.
+	PublicURL string
+
+	// globalBases are the deployment URLs TenantURL rewrites.
+	globalBases []string
+}
+
+// TenantMiddleware resolves the request Host against domains and records the
+// match for TenantFromContext. Requests on any other host, and every request
+// when domains is nil, pass through unchanged. A failed lookup is logged and
+// treated as no match.
+func TenantMiddleware(domains *TenantDomains, server *config.ServerConfig) gin.HandlerFunc {
+	scheme := "https"
+	if u, err := url.Parse(server.GetPublicURL()); err == nil && u.Scheme != "" {
+		scheme = strings.ToLower(u.Scheme)
+	}
+	var bases []string
+	for _, b := range []string{server.GetPublicURL(), server.BaseURL} {
+		if b = strings.TrimRight(b, "/"); b != "" {
+			bases = append(bases, b)
+		}
+	}
+	return func(c *gin.Context) {
+		if domains == nil {
+			c.Next()
+			return
+		}
+		domain, err := domains.Resolve(c.Request.Context(), c.Request.Host)
+		if err != nil {
+			slog.Warn("tenant domain lookup failed, serving as the default tenant", "host", c.Request.Host, "error", err)
+		}
+		if domain != nil {
+			c.Set(tenantContextKey, &Tenant{
+				Domain:      domain,
+				PublicURL:   scheme + "://" + strings.ToLower(c.Request.Host),
+				globalBases: bases,
+			})
+		}
+		c.Next()
+	}
+}
+
+// TenantFromContext returns the tenant of a request made on a tenant domain,
+// or nil.
+func TenantFromContext(c *gin.Context) *Tenant {
+	v, exists := c.Get(tenantContextKey)
+	if !exists {
+		return nil
+	}
+	t, _ := v.(*Tenant)
+	return t
+}
+
+// TenantOrganizationID returns the organization of the request's tenant
+// domain, or "" when the request was not made on one.
+func TenantOrganizationID(c *gin.Context) string {
+	if t := TenantFromContext(c); t != nil {
+		return t.Domain.OrganizationID
+	}
+	return ""
+}
+
+// TenantPublicURL returns the public URL to build absolute URLs on: the
+// tenant domain's origin, or fallback (normally server.public_url) when the
+// request was not made on a tenant domain.
+func TenantPublicURL(c *gin.Context, fallback string) string {
+	if t := TenantFromContext(c); t != nil {
+		return t.PublicURL
+	}
+	return fallback
+}
+
+// TenantURL moves an absolute URL built on the deployment's public or base
+// URL (such as a local storage download URL) onto the tenant domain. Other
+// URLs, including pre-signed object storage URLs, are returned unchanged.
+func TenantURL(c *gin.Context, rawURL string) string {
+	t := TenantFromContext(c)
+	if t == nil {
+		return rawURL
+	}
+	for _, base := range t.globalBases {
+		if strings.HasPrefix(rawURL, base+"/") {
+			return t.PublicURL + strings.TrimPrefix(rawURL, base)
+		}
+	}
+	return rawURL
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+type fakeTenantDomainLoader struct {
+	domains []*models.TenantDomain
+	err     error
+	calls   int
+}
+
+func (f *fakeTenantDomainLoader) List(context.Context) ([]*models.TenantDomain, error) {
+	f.calls++
+	return f.domains, f.err
+}
+
+func newTenantRouter(domains *TenantDomains) *gin.Engine {
+	server := &config.ServerConfig{BaseURL: "http://localhost:8080", PublicURL: "https://registry.example.com"}
+	r := gin.New()
+	r.Use(TenantMiddleware(domains, server))
+	r.GET("/urls", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"org":        TenantOrganizationID(c),
+			"public_url": TenantPublicURL(c, server.GetPublicURL()),
+			"local":      TenantURL(c, "http://localhost:8080/v1/files/modules/a.tar.gz"),
+			"presigned":  TenantURL(c, "https://bucket.s3.amazonaws.com/modules/a.tar.gz?X-Amz-Signature=x"),
+		})
+	})
+	return r
+}
+
+func getTenantURLs(t *testing.T, r *gin.Engine, host string) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/urls", nil)
+	req.Host = host
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	return w.Body.String()
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	loader := &fakeTenantDomainLoader{domains: []*models.TenantDomain{
+		{Hostname: "modules.acme-blue.example.com", OrganizationID: "org-blue"},
+	}}
+	r := newTenantRouter(NewTenantDomains(loader, time.Minute))
+
+	got := getTenantURLs(t, r, "Modules.ACME-Blue.example.com")
+	want := `{"local":"https://modules.acme-blue.example.com/v1/files/modules/a.tar.gz","org":"org-blue",` +
+		`"presigned":"https://bucket.s3.amazonaws.com/modules/a.tar.gz?X-Amz-Signature=x","public_url":"https://modules.acme-blue.example.com"}`
+	if got != want {
+		t.Errorf("tenant host:\n got  %s\n want %s", got, want)
+	}
+
+	got = getTenantURLs(t, r, "evil.example.com")
+	want = `{"local":"http://localhost:8080/v1/files/modules/a.tar.gz","org":"",` +
+		`"presigned":"https://bucket.s3.amazonaws.com/modules/a.tar.gz?X-Amz-Signature=x","public_url":"https://registry.example.com"}`
+	if got != want {
+		t.Errorf("unknown host:\n got  %s\n want %s", got, want)
+	}
+	if loader.calls != 1 {
+		t.Errorf("loader called %d times, want the domains cached", loader.calls)
+	}
+}
+
+func TestTenantDomains_ReloadFailureKeepsDomains(t *testing.T) {
+	loader := &fakeTenantDomainLoader{domains: []*models.TenantDomain{
+		{Hostname: "modules.acme-red.example.com", OrganizationID: "org-red"},
+	}}
+	domains := NewTenantDomains(loader, time.Minute)
+	now := time.Now()
+	domains.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if d, _ := domains.Resolve(ctx, "modules.acme-red.example.com:443"); d == nil || d.OrganizationID != "org-red" {
+		t.Fatalf("Resolve = %+v, want org-red", d)
+	}
+	now = now.Add(2 * time.Minute)
+	loader.err = errors.New("db down")
+	if d, err := domains.Resolve(ctx, "modules.acme-red.example.com"); err != nil || d == nil {
+		t.Errorf("after failed reload: %+v, %v; want the previous domain", d, err)
+	}
+
+	if _, err := NewTenantDomains(&fakeTenantDomainLoader{err: errors.New("db down")}, 0).Resolve(ctx, "x"); err == nil {
+		t.Error("first load failure: expected an error")
+	}
+	var none *TenantDomains
+	if d, err := none.Resolve(ctx, "modules.acme-red.example.com"); d != nil || err != nil {
+		t.Errorf("nil cache: %+v, %v; want nil, nil", d, err)
+	}
+}
+
+func TestCSRF_BearerJWT_AllowedTenantOrigin(t *testing.T) {
+	loader := &fakeTenantDomainLoader{domains: []*models.TenantDomain{
+		{Hostname: "modules.acme-blue.example.com", OrganizationID: "org-blue"},
+	}}
+	cfg := csrfTestConfig()
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("auth_method", "jwt") })
+	r.Use(TenantMiddleware(NewTenantDomains(loader, time.Minute), &cfg.Server))
+	r.Use(CSRFMiddleware(cfg))
+	r.POST("/mutate", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for host, want := range map[string]int{
+		"modules.acme-blue.example.com": http.StatusOK,
+		"modules.acme-red.example.com":  http.StatusForbidden,
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/mutate", nil)
+		req.Host = host
+		req.Header.Set("Origin", "https://"+host)
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("Origin https://%s: status = %d, want %d", host, w.Code, want)
+		}
+	}
+}
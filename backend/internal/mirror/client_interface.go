@@ -21,7 +21,43 @@ type UpstreamRegistryClient interface {
 	DownloadFileStream(ctx context.Context, fileURL string) (*DownloadStream, error)
 	GetProviderDocIndexByVersion(ctx context.Context, namespace, providerName, version string) ([]ProviderDocEntry, error)
 	GetProviderDocContent(ctx context.Context, upstreamDocID string) (string, error)
+	GetProviderMetadata(ctx context.Context, namespace, providerName string) (*ProviderMetadata, error)
 }
 
 // Compile-time assertion that *UpstreamRegistry satisfies UpstreamRegistryClient.
 var _ UpstreamRegistryClient = (*UpstreamRegistry)(nil)
+
+// UpstreamModuleClient is the subset of UpstreamRegistry used by the module
+// pull-through proxy. It is separate from UpstreamRegistryClient so existing
+// provider-side fakes do not have to grow module methods.
+type UpstreamModuleClient interface {
+	ListModuleVersions(ctx context.Context, namespace, name, system string) ([]string, error)
+	GetModuleDownloadURL(ctx context.Context, namespace, name, system, version string) (string, error)
+	DownloadFileStream(ctx context.Context, fileURL string) (*DownloadStream, error)
+}
+
+// Compile-time assertion that *UpstreamRegistry satisfies UpstreamModuleClient.
+var _ UpstreamModuleClient = (*UpstreamRegistry)(nil)
+
+// ConditionalVersionLister is implemented by clients that can list a
+// provider's versions conditionally. It is optional so existing fakes need
+// not implement it; use ListProviderVersionsConditional to call it.
+type ConditionalVersionLister interface {
+	ListProviderVersionsConditional(ctx context.Context, namespace, providerName string, since VersionsValidators) (*ProviderVersionsListing, error)
+}
+
+// Compile-time assertion that *UpstreamRegistry satisfies ConditionalVersionLister.
+var _ ConditionalVersionLister = (*UpstreamRegistry)(nil)
+
+// ListProviderVersionsConditional lists a provider's versions through c,
+// conditionally on since when c supports it, and unconditionally otherwise.
+func ListProviderVersionsConditional(ctx context.Context, c UpstreamRegistryClient, namespace, providerName string, since VersionsValidators) (*ProviderVersionsListing, error) {
+	if cl, ok := c.(ConditionalVersionLister); ok {
+		return cl.ListProviderVersionsConditional(ctx, namespace, providerName, since)
+	}
+	versions, err := c.ListProviderVersions(ctx, namespace, providerName)
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderVersionsListing{Versions: versions}, nil
+}
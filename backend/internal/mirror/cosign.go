@@ -0,0 +1,147 @@
+// Package mirror - cosign.go verifies keyless cosign signatures over release
+// checksum files.
+//
+// OpenTofu signs each release's tofu_<version>_SHA256SUMS twice: a detached
+// GPG signature (tofu_<version>_SHA256SUMS.gpgsig, OpenTofuReleasesGPGKey) and
+// a keyless cosign signature made from the release workflow
+// (tofu_<version>_SHA256SUMS.sig, base64 ECDSA signature, plus
+// tofu_<version>_SHA256SUMS.pem, the base64-encoded Fulcio certificate). The
+// documented check is:
+//
+//	cosign verify-blob \
+//	  --certificate-identity-regexp '^https://github.com/opentofu/opentofu/.github/workflows/release.yml@refs/heads/v.*' \
+//	  --certificate-oidc-issuer https://token.actions.githubusercontent.com \
+//	  --certificate tofu_<version>_SHA256SUMS.pem --signature tofu_<version>_SHA256SUMS.sig \
+//	  tofu_<version>_SHA256SUMS
+//
+// VerifyCosignBlob performs the same checks without the cosign binary:
+//
+//  1. The certificate must chain to a Fulcio CA of the Sigstore public-good
+//     trust root, evaluated at the certificate's issuance time.
+//  2. The certificate's OIDC issuer must equal the pinned issuer exactly.
+//  3. The certificate's SubjectAlternativeName (the signing workflow ref) must
+//     match the pinned identity pattern.
+//  4. The signature must verify over the blob with the certificate's key.
+//
+// Release assets carry no Rekor bundle, so the transparency log is not
+// consulted; trust rests on the Fulcio chain and the pinned identity. As with
+// attestations, an unreachable trust root yields ErrCosignUnavailable, which
+// callers must treat as "could not verify", not as a bad signature.
+package mirror
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sigstore/sigstore-go/pkg/fulcio/certificate"
+	"github.com/sigstore/sigstore-go/pkg/root"
+)
+
+// ErrCosignUnavailable indicates cosign verification could not run because the
+// Sigstore trust root is unreachable (e.g. an air-gapped deployment).
+var ErrCosignUnavailable = errors.New("cosign verification unavailable")
+
+// CosignIdentity pins the signer of a keyless cosign signature.
+type CosignIdentity struct {
+	// Issuer is the exact OIDC issuer recorded in the Fulcio certificate.
+	Issuer string
+	// SubjectRegexp must match the certificate's SubjectAlternativeName.
+	SubjectRegexp *regexp.Regexp
+}
+
+// OpenTofuCosignIdentity is the signer identity documented for OpenTofu
+// releases: the release workflow of github.com/opentofu/opentofu, run from a
+// release branch (refs/heads/v1.6, refs/heads/v1.7, ...).
+var OpenTofuCosignIdentity = CosignIdentity{
+	Issuer:        GitHubActionsOIDCIssuer,
+	SubjectRegexp: regexp.MustCompile(`^https://github\.com/opentofu/opentofu/\.github/workflows/release\.yml@refs/heads/v[0-9]`),
+}
+
+// CosignIdentityForTool returns the pinned cosign signer for a mirrored tool,
+// or nil when the tool's releases carry no cosign signature.
+func CosignIdentityForTool(tool string) *CosignIdentity {
+	if strings.EqualFold(tool, "opentofu") {
+		id := OpenTofuCosignIdentity
+		return &id
+	}
+	return nil
+}
+
+// VerifyCosignBlob verifies a keyless cosign signature over blob against the
+// Sigstore public-good trust root and the pinned identity. sig is the
+// base64-encoded signature and cert the certificate as PEM, or base64 of the
+// PEM as cosign writes it.
+func VerifyCosignBlob(blob, sig, cert []byte, id CosignIdentity) error {
+	trusted, err := publicGoodTrustedMaterial()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCosignUnavailable, err)
+	}
+	return verifyCosignBlob(trusted, blob, sig, cert, id)
+}
+
+// verifyCosignBlob is VerifyCosignBlob against the given trusted material, so
+// tests can drive it with a test CA.
+func verifyCosignBlob(trusted root.TrustedMaterial, blob, sig, cert []byte, id CosignIdentity) error {
+	leaf, err := parseCosignCertificate(cert)
+	if err != nil {
+		return err
+	}
+
+	chained := false
+	for _, ca := range trusted.FulcioCertificateAuthorities() {
+		if _, verr := ca.Verify(leaf, leaf.NotBefore); verr == nil {
+			chained = true
+			break
+		}
+	}
+	if !chained {
+		return errors.New("cosign certificate does not chain to a trusted Fulcio CA")
+	}
+
+	summary, err := certificate.SummarizeCertificate(leaf)
+	if err != nil {
+		return fmt.Errorf("failed to read cosign certificate: %w", err)
+	}
+	if summary.Issuer != id.Issuer {
+		return fmt.Errorf("cosign certificate issuer %q does not match pinned issuer %q", summary.Issuer, id.Issuer)
+	}
+	if id.SubjectRegexp == nil || !id.SubjectRegexp.MatchString(summary.SubjectAlternativeName) {
+		return fmt.Errorf("cosign certificate identity %q does not match the pinned identity", summary.SubjectAlternativeName)
+	}
+
+	rawSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("cosign signature is not valid base64: %w", err)
+	}
+	if err := leaf.CheckSignature(x509.ECDSAWithSHA256, blob, rawSig); err != nil {
+		return fmt.Errorf("cosign signature verification failed: %w", err)
+	}
+	return nil
+}
+
+// parseCosignCertificate decodes a certificate written as PEM or as base64 of
+// the PEM.
+func parseCosignCertificate(data []byte) (*x509.Certificate, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(trimmed, "-----BEGIN") {
+		decoded, err := base64.StdEncoding.DecodeString(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("cosign certificate is neither PEM nor base64: %w", err)
+		}
+		trimmed = string(decoded)
+	}
+	block, _ := pem.Decode([]byte(trimmed))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("cosign certificate has no PEM CERTIFICATE block")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cosign certificate: %w", err)
+	}
+	return cert, nil
+}
@@ -0,0 +1,111 @@
+package mirror
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/testing/ca"
+)
+
+const testOpenTofuWorkflow = "https://github.com/opentofu/opentofu/.github/workflows/release.yml@refs/heads/v1.8"
+
+// cosignSign signs blob the way `cosign sign-blob` does for a keyless signer:
+// a base64 ECDSA signature and the base64-encoded PEM leaf certificate.
+func cosignSign(t *testing.T, vs *ca.VirtualSigstore, identity, issuer string, blob []byte) (sig, cert []byte) {
+	t.Helper()
+	leaf, key, err := vs.GenerateLeafCert(identity, issuer)
+	if err != nil {
+		t.Fatalf("GenerateLeafCert: %v", err)
+	}
+	digest := sha256.Sum256(blob)
+	raw, err := key.(*ecdsa.PrivateKey).Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})
+	return []byte(base64.StdEncoding.EncodeToString(raw)), []byte(base64.StdEncoding.EncodeToString(certPEM))
+}
+
+func newVirtualSigstore(t *testing.T) *ca.VirtualSigstore {
+	t.Helper()
+	vs, err := ca.NewVirtualSigstore()
+	if err != nil {
+		t.Fatalf("NewVirtualSigstore: %v", err)
+	}
+	return vs
+}
+
+var testSums = []byte("abc123  tofu_1.8.0_linux_amd64.zip\n")
+
+func TestVerifyCosignBlob_Success(t *testing.T) {
+	vs := newVirtualSigstore(t)
+	sig, cert := cosignSign(t, vs, testOpenTofuWorkflow, GitHubActionsOIDCIssuer, testSums)
+	if err := verifyCosignBlob(vs, testSums, sig, cert, OpenTofuCosignIdentity); err != nil {
+		t.Fatalf("verifyCosignBlob: %v", err)
+	}
+}
+
+func TestVerifyCosignBlob_PlainPEMCertificate(t *testing.T) {
+	vs := newVirtualSigstore(t)
+	sig, cert := cosignSign(t, vs, testOpenTofuWorkflow, GitHubActionsOIDCIssuer, testSums)
+	plain, _ := base64.StdEncoding.DecodeString(string(cert))
+	if err := verifyCosignBlob(vs, testSums, sig, plain, OpenTofuCosignIdentity); err != nil {
+		t.Fatalf("verifyCosignBlob: %v", err)
+	}
+}
+
+func TestVerifyCosignBlob_Rejections(t *testing.T) {
+	vs := newVirtualSigstore(t)
+	goodSig, goodCert := cosignSign(t, vs, testOpenTofuWorkflow, GitHubActionsOIDCIssuer, testSums)
+	wrongIssuerSig, wrongIssuerCert := cosignSign(t, vs, testOpenTofuWorkflow, "https://accounts.example.com", testSums)
+	forkSig, forkCert := cosignSign(t, vs, "https://github.com/opentofu-evil/opentofu/.github/workflows/release.yml@refs/heads/v1.8", GitHubActionsOIDCIssuer, testSums)
+	otherSig, otherCert := cosignSign(t, newVirtualSigstore(t), testOpenTofuWorkflow, GitHubActionsOIDCIssuer, testSums)
+
+	tests := []struct {
+		name      string
+		blob      []byte
+		sig, cert []byte
+		wantErr   string
+	}{
+		{"tampered blob", []byte("tampered"), goodSig, goodCert, "signature verification failed"},
+		{"wrong issuer", testSums, wrongIssuerSig, wrongIssuerCert, "issuer"},
+		{"wrong identity", testSums, forkSig, forkCert, "identity"},
+		{"untrusted CA", testSums, otherSig, otherCert, "trusted Fulcio CA"},
+		{"garbage certificate", testSums, goodSig, []byte("!!"), "neither PEM nor base64"},
+		{"garbage signature", testSums, []byte("!!"), goodCert, "not valid base64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyCosignBlob(vs, tt.blob, tt.sig, tt.cert, OpenTofuCosignIdentity)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyCosignBlob_TrustRootUnavailable(t *testing.T) {
+	resetAttestationTrustRootCache(t)
+	stubFetchTrustRoot(t, func() (*root.TrustedRoot, error) { return nil, errors.New("no network") })
+	err := VerifyCosignBlob(testSums, nil, nil, OpenTofuCosignIdentity)
+	if !errors.Is(err, ErrCosignUnavailable) {
+		t.Errorf("err = %v, want ErrCosignUnavailable", err)
+	}
+}
+
+func TestCosignIdentityForTool(t *testing.T) {
+	if id := CosignIdentityForTool("OpenTofu"); id == nil || id.Issuer != GitHubActionsOIDCIssuer {
+		t.Errorf("opentofu identity = %+v", id)
+	}
+	if id := CosignIdentityForTool("terraform"); id != nil {
+		t.Errorf("terraform identity = %+v, want nil", id)
+	}
+}
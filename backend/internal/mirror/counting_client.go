@@ -0,0 +1,89 @@
+// counting_client.go wraps an UpstreamRegistryClient to count the upstream
+// registry API calls a mirror sync makes.
+package mirror
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// CountingClient is an UpstreamRegistryClient that counts the calls made
+// through it. Registry API calls (discovery, version listings, package info,
+// docs and metadata) and file downloads are counted separately. A version
+// listing or package lookup counts once, although UpstreamRegistry also makes
+// a service discovery request for it.
+type CountingClient struct {
+	UpstreamRegistryClient
+	apiCalls  atomic.Int64
+	downloads atomic.Int64
+}
+
+// NewCountingClient wraps c.
+func NewCountingClient(c UpstreamRegistryClient) *CountingClient {
+	return &CountingClient{UpstreamRegistryClient: c}
+}
+
+// APICalls returns the number of registry API calls made so far.
+func (c *CountingClient) APICalls() int64 { return c.apiCalls.Load() }
+
+// Downloads returns the number of file downloads started so far.
+func (c *CountingClient) Downloads() int64 { return c.downloads.Load() }
+
+// DiscoverServices implements UpstreamRegistryClient.
+func (c *CountingClient) DiscoverServices(ctx context.Context) (*ServiceDiscoveryResponse, error) {
+	c.apiCalls.Add(1)
+	return c.UpstreamRegistryClient.DiscoverServices(ctx)
+}
+
+// ListProviderVersions implements UpstreamRegistryClient.
+func (c *CountingClient) ListProviderVersions(ctx context.Context, namespace, providerName string) ([]ProviderVersion, error) {
+	c.apiCalls.Add(1)
+	return c.UpstreamRegistryClient.ListProviderVersions(ctx, namespace, providerName)
+}
+
+// ListProviderVersionsConditional implements ConditionalVersionLister,
+// falling back to an unconditional listing when the wrapped client cannot
+// list conditionally.
+func (c *CountingClient) ListProviderVersionsConditional(ctx context.Context, namespace, providerName string, since VersionsValidators) (*ProviderVersionsListing, error) {
+	c.apiCalls.Add(1)
+	return ListProviderVersionsConditional(ctx, c.UpstreamRegistryClient, namespace, providerName, since)
+}
+
+// GetProviderPackage implements UpstreamRegistryClient.
+func (c *CountingClient) GetProviderPackage(ctx context.Context, namespace, providerName, version, os, arch string) (*ProviderPackageResponse, error) {
+	c.apiCalls.Add(1)
+	return c.UpstreamRegistryClient.GetProviderPackage(ctx, namespace, providerName, version, os, arch)
+}
+
+// GetProviderDocIndexByVersion implements UpstreamRegistryClient.
+func (c *CountingClient) GetProviderDocIndexByVersion(ctx context.Context, namespace, providerName, version string) ([]ProviderDocEntry, error) {
+	c.apiCalls.Add(1)
+	return c.UpstreamRegistryClient.GetProviderDocIndexByVersion(ctx, namespace, providerName, version)
+}
+
+// GetProviderDocContent implements UpstreamRegistryClient.
+func (c *CountingClient) GetProviderDocContent(ctx context.Context, upstreamDocID string) (string, error) {
+	c.apiCalls.Add(1)
+	return c.UpstreamRegistryClient.GetProviderDocContent(ctx, upstreamDocID)
+}
+
+// GetProviderMetadata implements UpstreamRegistryClient.
+func (c *CountingClient) GetProviderMetadata(ctx context.Context, namespace, providerName string) (*ProviderMetadata, error) {
+	c.apiCalls.Add(1)
+	return c.UpstreamRegistryClient.GetProviderMetadata(ctx, namespace, providerName)
+}
+
+// DownloadFile implements UpstreamRegistryClient.
+func (c *CountingClient) DownloadFile(ctx context.Context, fileURL string) ([]byte, error) {
+	c.downloads.Add(1)
+	return c.UpstreamRegistryClient.DownloadFile(ctx, fileURL)
+}
+
+// DownloadFileStream implements UpstreamRegistryClient.
+func (c *CountingClient) DownloadFileStream(ctx context.Context, fileURL string) (*DownloadStream, error) {
+	c.downloads.Add(1)
+	return c.UpstreamRegistryClient.DownloadFileStream(ctx, fileURL)
+}
+
+// Compile-time assertion that *CountingClient satisfies UpstreamRegistryClient.
+var _ UpstreamRegistryClient = (*CountingClient)(nil)
@@ -0,0 +1,60 @@
+package mirror
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCountingClient_CountsAPICallsAndDownloads(t *testing.T) {
+	srv, u := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/terraform.json":
+			_, _ = w.Write([]byte(`{"providers.v1":"/v1/providers/"}`))
+		case "/v1/providers/hashicorp/aws/versions":
+			_, _ = w.Write([]byte(`{"versions":[]}`))
+		default:
+			_, _ = w.Write([]byte("file"))
+		}
+	})
+	c := NewCountingClient(u)
+
+	ctx := context.Background()
+	if _, err := c.ListProviderVersions(ctx, "hashicorp", "aws"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DiscoverServices(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DownloadFile(ctx, srv.URL+"/SHA256SUMS"); err != nil {
+		t.Fatal(err)
+	}
+	if c.APICalls() != 2 || c.Downloads() != 1 {
+		t.Errorf("api calls = %d, downloads = %d; want 2 and 1", c.APICalls(), c.Downloads())
+	}
+}
+
+// versionsOnlyClient is an UpstreamRegistryClient that cannot list
+// conditionally.
+type versionsOnlyClient struct {
+	UpstreamRegistryClient
+}
+
+func (versionsOnlyClient) ListProviderVersions(context.Context, string, string) ([]ProviderVersion, error) {
+	return []ProviderVersion{{Version: "1.0.0"}}, nil
+}
+
+func TestCountingClient_ConditionalListingFallsBack(t *testing.T) {
+	c := NewCountingClient(versionsOnlyClient{})
+
+	listing, err := c.ListProviderVersionsConditional(context.Background(), "hashicorp", "aws", VersionsValidators{ETag: `"v1"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listing.NotModified || len(listing.Versions) != 1 {
+		t.Errorf("listing = %+v, want the unconditional listing", listing)
+	}
+	if c.APICalls() != 1 {
+		t.Errorf("api calls = %d, want 1", c.APICalls())
+	}
+}
@@ -151,6 +151,21 @@ var sha256sumsRE = regexp.MustCompile(`^(.+?)_([^_]+)_SHA256SUMS$`)
 // sha256sumsSigRE matches: {product}_{version}_SHA256SUMS.sig  (or .*.sig)
 var sha256sumsSigRE = regexp.MustCompile(`^(.+?)_([^_]+)_SHA256SUMS\..*sig$`)
 
+// sha256sumsGPGSigRE matches: {product}_{version}_SHA256SUMS.gpgsig. OpenTofu
+// publishes its GPG signature under this name next to a cosign signature
+// named .sig, so it is preferred over sha256sumsSigRE.
+var sha256sumsGPGSigRE = regexp.MustCompile(`^(.+?)_([^_]+)_SHA256SUMS\.gpgsig$`)
+
+// sha256sumsCosignSigRE and sha256sumsCosignCertRE match the keyless cosign
+// signature and certificate of the SUMS file:
+//
+//	{product}_{version}_SHA256SUMS.sig
+//	{product}_{version}_SHA256SUMS.pem
+var (
+	sha256sumsCosignSigRE  = regexp.MustCompile(`^(.+?)_([^_]+)_SHA256SUMS\.sig$`)
+	sha256sumsCosignCertRE = regexp.MustCompile(`^(.+?)_([^_]+)_SHA256SUMS\.pem$`)
+)
+
 // tfDocsArchiveRE matches hyphen-delimited, version-prefixed release archives:
 //
 //	{product}-v{version}-{os}-{arch}.tar.gz   e.g. terraform-docs-v0.24.0-linux-amd64.tar.gz
@@ -358,6 +373,13 @@ func (c *GitHubReleasesClient) parseRelease(rel gitHubRelease) (TerraformVersion
 
 // ----- FetchSHASums ---------------------------------------------------------
 
+// SHASumsURL returns the download URL of the combined SHA256SUMS asset for a
+// specific version, or "" when the release only has per-file .sha256
+// sidecars (see FetchSHASums).
+func (c *GitHubReleasesClient) SHASumsURL(ctx context.Context, version string) (string, error) {
+	return c.findSHA256SumsURL(ctx, version)
+}
+
 // FetchSHASums downloads the SHA256SUMS asset for a specific version from
 // GitHub and returns parsed filename→sha256 map plus the raw bytes.
 // If no combined SHA256SUMS file exists (e.g. OPA), it falls back to fetching
@@ -437,6 +459,30 @@ func (c *GitHubReleasesClient) FetchSHASumsSignature(ctx context.Context, versio
 	return c.fetchURL(ctx, sigURL, 65536) // 64 KB cap
 }
 
+// FetchSHASumsCosignSignature downloads the keyless cosign signature and
+// certificate for the SHA256SUMS file of a specific version (see
+// VerifyCosignBlob). Both assets must be present.
+func (c *GitHubReleasesClient) FetchSHASumsCosignSignature(ctx context.Context, version string) (sig, cert []byte, err error) {
+	sigURL, err := c.findAssetURL(ctx, version, sha256sumsCosignSigRE)
+	if err != nil {
+		return nil, nil, err
+	}
+	certURL, err := c.findAssetURL(ctx, version, sha256sumsCosignCertRE)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sigURL == "" || certURL == "" {
+		return nil, nil, fmt.Errorf("no cosign signature and certificate assets found for version %s in %s/%s", version, c.Owner, c.Repo)
+	}
+	if sig, err = c.fetchURL(ctx, sigURL, 65536); err != nil {
+		return nil, nil, err
+	}
+	if cert, err = c.fetchURL(ctx, certURL, 65536); err != nil {
+		return nil, nil, err
+	}
+	return sig, cert, nil
+}
+
 // DownloadBinary downloads a binary zip from the given URL (already a full URL
 // from the GitHub asset list). Identical to TerraformReleasesClient.DownloadBinary.
 func (c *GitHubReleasesClient) DownloadBinaryStream(ctx context.Context, downloadURL string) (io.ReadCloser, int64, error) {
@@ -466,13 +512,13 @@ func (c *GitHubReleasesClient) findSHA256SumsURL(ctx context.Context, version st
 }
 
 func (c *GitHubReleasesClient) findSHA256SumsSigURL(ctx context.Context, version string) (string, error) {
-	return c.findAssetURL(ctx, version, sha256sumsSigRE)
+	return c.findAssetURL(ctx, version, sha256sumsGPGSigRE, sha256sumsSigRE)
 }
 
 // findAssetURL fetches the specific release by tag (tries with and without
-// leading "v") and returns the browser_download_url of the first asset whose
-// name matches any of the supplied regexes and whose product prefix (capture
-// group 1) matches c.ProductName.
+// leading "v") and returns the browser_download_url of an asset whose name
+// matches one of the supplied regexes, earlier regexes first, and whose
+// product prefix (capture group 1) matches c.ProductName.
 func (c *GitHubReleasesClient) findAssetURL(ctx context.Context, version string, res ...*regexp.Regexp) (string, error) {
 	// GitHub tags may use "v1.9.0" or "1.9.0" — try both.
 	for _, tag := range []string{"v" + version, version} {
@@ -480,8 +526,8 @@ func (c *GitHubReleasesClient) findAssetURL(ctx context.Context, version string,
 		if err != nil {
 			continue
 		}
-		for _, asset := range rel.Assets {
-			for _, re := range res {
+		for _, re := range res {
+			for _, asset := range rel.Assets {
 				if m := re.FindStringSubmatch(asset.Name); m != nil {
 					if strings.EqualFold(m[1], c.ProductName) {
 						return asset.BrowserDownloadURL, nil
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path"
 	"strings"
 	"testing"
 )
@@ -989,3 +990,81 @@ func TestGitHubFetchSHASums_PerFileFallback(t *testing.T) {
 		t.Error("raw bytes should not be empty")
 	}
 }
+
+// openTofuSignedReleaseJSON lists the SUMS assets of an OpenTofu release: a
+// cosign signature (.sig) and certificate (.pem) next to the GPG .gpgsig,
+// with the cosign signature first.
+func openTofuSignedReleaseJSON(version string) gitHubRelease {
+	rel := gitHubRelease{TagName: "v" + version}
+	for _, ext := range []string{"", ".sig", ".pem", ".gpgsig"} {
+		name := "tofu_" + version + "_SHA256SUMS" + ext
+		rel.Assets = append(rel.Assets, gitHubAsset{Name: name, BrowserDownloadURL: "https://github.com/releases/" + name})
+	}
+	return rel
+}
+
+func newOpenTofuAssetServer(t *testing.T, version string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/releases/tags/") {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(openTofuSignedReleaseJSON(version))
+			return
+		}
+		_, _ = w.Write([]byte("content of " + path.Base(r.URL.Path)))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGitHubFetchSHASumsSignature_PrefersGPGSig(t *testing.T) {
+	ts := newOpenTofuAssetServer(t, "1.8.0")
+	client := newTestGitHubClient(ts, "opentofu", "opentofu", "tofu")
+
+	got, err := client.FetchSHASumsSignature(context.Background(), "1.8.0")
+	if err != nil {
+		t.Fatalf("FetchSHASumsSignature error: %v", err)
+	}
+	if string(got) != "content of tofu_1.8.0_SHA256SUMS.gpgsig" {
+		t.Errorf("signature = %q, want the .gpgsig asset", got)
+	}
+}
+
+func TestGitHubSHASumsURL(t *testing.T) {
+	ts := newOpenTofuAssetServer(t, "1.8.0")
+	client := newTestGitHubClient(ts, "opentofu", "opentofu", "tofu")
+
+	got, err := client.SHASumsURL(context.Background(), "1.8.0")
+	if err != nil {
+		t.Fatalf("SHASumsURL error: %v", err)
+	}
+	if !strings.HasSuffix(got, "/tofu_1.8.0_SHA256SUMS") {
+		t.Errorf("SHASumsURL = %q, want the SHA256SUMS asset", got)
+	}
+}
+
+func TestGitHubFetchSHASumsCosignSignature(t *testing.T) {
+	ts := newOpenTofuAssetServer(t, "1.8.0")
+	client := newTestGitHubClient(ts, "opentofu", "opentofu", "tofu")
+
+	sig, cert, err := client.FetchSHASumsCosignSignature(context.Background(), "1.8.0")
+	if err != nil {
+		t.Fatalf("FetchSHASumsCosignSignature error: %v", err)
+	}
+	if string(sig) != "content of tofu_1.8.0_SHA256SUMS.sig" || string(cert) != "content of tofu_1.8.0_SHA256SUMS.pem" {
+		t.Errorf("sig = %q, cert = %q", sig, cert)
+	}
+}
+
+func TestGitHubFetchSHASumsCosignSignature_Missing(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sampleReleaseJSON("v1.9.0", "tofu"))
+	}))
+	defer ts.Close()
+	client := newTestGitHubClient(ts, "opentofu", "opentofu", "tofu")
+
+	if _, _, err := client.FetchSHASumsCosignSignature(context.Background(), "1.9.0"); err == nil {
+		t.Fatal("expected error when the certificate asset is missing")
+	}
+}
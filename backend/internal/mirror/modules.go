@@ -0,0 +1,251 @@
+// modules.go extends the upstream registry client with the Module Registry
+// Protocol calls used by the module pull-through proxy: version enumeration,
+// download-location lookup, and translation of the returned go-getter source
+// into a plain HTTPS archive URL this server can fetch and cache.
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// UpstreamStatusError is returned by the module protocol calls when the
+// upstream registry answered with a non-success status. The module proxy
+// relays StatusCode and Message to its own caller instead of masking them
+// behind a generic 500.
+type UpstreamStatusError struct {
+	Op         string
+	StatusCode int
+	Message    string
+}
+
+func (e *UpstreamStatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("upstream %s failed with status %d", e.Op, e.StatusCode)
+	}
+	return fmt.Sprintf("upstream %s failed with status %d: %s", e.Op, e.StatusCode, e.Message)
+}
+
+// newUpstreamStatusError reads a bounded error body and extracts the
+// registry's "errors" array when present.
+func newUpstreamStatusError(op string, resp *http.Response) *UpstreamStatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxUpstreamErrorBodyBytes))
+	msg := strings.TrimSpace(string(body))
+	var doc struct {
+		Errors []string `json:"errors"`
+	}
+	if json.Unmarshal(body, &doc) == nil && len(doc.Errors) > 0 {
+		msg = strings.Join(doc.Errors, "; ")
+	}
+	return &UpstreamStatusError{Op: op, StatusCode: resp.StatusCode, Message: msg}
+}
+
+// moduleVersionsResponse is the Module Registry Protocol versions document.
+type moduleVersionsResponse struct {
+	Modules []struct {
+		Versions []struct {
+			Version string `json:"version"`
+		} `json:"versions"`
+	} `json:"modules"`
+}
+
+// modulesBaseURL resolves the upstream's modules.v1 endpoint.
+func (u *UpstreamRegistry) modulesBaseURL(ctx context.Context) (string, error) {
+	discovery, err := u.DiscoverServices(ctx)
+	if err != nil {
+		return "", fmt.Errorf("service discovery failed: %w", err)
+	}
+	if discovery.ModulesV1 == "" {
+		return "", &UpstreamStatusError{Op: "service discovery", StatusCode: http.StatusNotFound, Message: "upstream registry does not advertise modules.v1"}
+	}
+	base, _ := url.Parse(u.BaseURL)
+	modRef, err := url.Parse(discovery.ModulesV1)
+	if err != nil {
+		return "", fmt.Errorf("invalid modules.v1 endpoint %q: %w", discovery.ModulesV1, err)
+	}
+	return strings.TrimSuffix(base.ResolveReference(modRef).String(), "/"), nil
+}
+
+// ListModuleVersions lists the versions the upstream registry offers for a
+// module. A 404 is returned as an *UpstreamStatusError, not an empty list, so
+// the proxy can tell "unknown module" from "module with no versions".
+func (u *UpstreamRegistry) ListModuleVersions(ctx context.Context, namespace, name, system string) ([]string, error) {
+	base, err := u.modulesBaseURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versionsURL := fmt.Sprintf("%s/%s/%s/%s/versions", base,
+		url.PathEscape(namespace), url.PathEscape(name), url.PathEscape(system))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", versionsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create module versions request: %w", err)
+	}
+	resp, err := u.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch module versions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newUpstreamStatusError("module versions request", resp)
+	}
+
+	var doc moduleVersionsResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxUpstreamResponseBytes)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode module versions response: %w", err)
+	}
+	var versions []string
+	for _, m := range doc.Modules {
+		for _, v := range m.Versions {
+			versions = append(versions, v.Version)
+		}
+	}
+	return versions, nil
+}
+
+// GetModuleDownloadURL returns the X-Terraform-Get location for a module
+// version, resolved against the download endpoint URL when relative.
+func (u *UpstreamRegistry) GetModuleDownloadURL(ctx context.Context, namespace, name, system, version string) (string, error) {
+	base, err := u.modulesBaseURL(ctx)
+	if err != nil {
+		return "", err
+	}
+	downloadURL := fmt.Sprintf("%s/%s/%s/%s/%s/download", base,
+		url.PathEscape(namespace), url.PathEscape(name), url.PathEscape(system), url.PathEscape(version))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create module download request: %w", err)
+	}
+	resp, err := u.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch module download location: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return "", newUpstreamStatusError("module download request", resp)
+	}
+	location := resp.Header.Get("X-Terraform-Get")
+	if location == "" {
+		return "", &UpstreamStatusError{Op: "module download request", StatusCode: http.StatusBadGateway, Message: "upstream response has no X-Terraform-Get header"}
+	}
+	return resolveGetterLocation(resp.Request.URL, location), nil
+}
+
+// resolveGetterLocation resolves a relative X-Terraform-Get value against the
+// URL it was returned from. Values carrying a go-getter forced prefix
+// ("git::", "s3::") or a host shorthand ("github.com/...") are left alone.
+func resolveGetterLocation(from *url.URL, location string) string {
+	if strings.Contains(location, "::") || from == nil {
+		return location
+	}
+	if strings.HasPrefix(location, "/") || strings.HasPrefix(location, "./") || strings.HasPrefix(location, "../") {
+		ref, err := url.Parse(location)
+		if err != nil {
+			return location
+		}
+		return from.ResolveReference(ref).String()
+	}
+	return location
+}
+
+// ModuleArchive describes where to fetch a module archive and which part of it
+// is the module.
+type ModuleArchive struct {
+	// URL is an HTTPS URL serving a .tar.gz archive.
+	URL string
+	// StripTopDir is true when the archive wraps its contents in a single
+	// top-level directory (GitHub source archives) that must be removed.
+	StripTopDir bool
+	// Subdir is the go-getter "//subdir" component, relative to the archive
+	// root after StripTopDir has been applied. Empty for the root.
+	Subdir string
+}
+
+// ErrUnsupportedModuleSource is returned by ResolveModuleArchive for getter
+// sources the proxy cannot turn into an HTTPS archive download.
+var ErrUnsupportedModuleSource = errors.New("unsupported module source")
+
+// ResolveModuleArchive converts an X-Terraform-Get value into an HTTPS
+// archive download. Supported forms:
+//
+//   - git::https://github.com/OWNER/REPO(.git)[//subdir]?ref=REF
+//   - github.com/OWNER/REPO[//subdir]?ref=REF
+//   - https://host/path.tar.gz (or .tgz, or ?archive=tar.gz)[//subdir]
+//
+// GitHub sources are fetched as the tag/commit source tarball. A GitHub source
+// without ref is rejected: caching the default branch would pin whatever
+// happened to be on it at first download.
+func ResolveModuleArchive(location string) (*ModuleArchive, error) {
+	src := strings.TrimPrefix(location, "git::")
+	forcedGit := src != location
+
+	if strings.HasPrefix(src, "github.com/") {
+		src = "https://" + src
+		forcedGit = true
+	}
+
+	// Split query first so "//" inside it cannot be mistaken for a subdir.
+	rawQuery := ""
+	if i := strings.Index(src, "?"); i >= 0 {
+		src, rawQuery = src[:i], src[i+1:]
+	}
+	subdir := ""
+	if scheme := strings.Index(src, "://"); scheme >= 0 {
+		if i := strings.Index(src[scheme+3:], "//"); i >= 0 {
+			cut := scheme + 3 + i
+			src, subdir = src[:cut], strings.Trim(src[cut+2:], "/")
+		}
+	}
+	if subdir != "" {
+		clean := path.Clean(subdir)
+		if clean == ".." || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+			return nil, fmt.Errorf("%w: invalid subdirectory %q", ErrUnsupportedModuleSource, subdir)
+		}
+		subdir = clean
+	}
+
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedModuleSource, location)
+	}
+	query, _ := url.ParseQuery(rawQuery)
+
+	if strings.EqualFold(u.Host, "github.com") && (forcedGit || strings.HasSuffix(u.Path, ".git")) {
+		parts := strings.Split(strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/"), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedModuleSource, location)
+		}
+		ref := query.Get("ref")
+		if ref == "" {
+			return nil, fmt.Errorf("%w: GitHub source without ref: %q", ErrUnsupportedModuleSource, location)
+		}
+		return &ModuleArchive{
+			URL:         fmt.Sprintf("https://codeload.github.com/%s/%s/tar.gz/%s", parts[0], parts[1], url.PathEscape(ref)),
+			StripTopDir: true,
+			Subdir:      subdir,
+		}, nil
+	}
+
+	if forcedGit {
+		return nil, fmt.Errorf("%w: git source %q", ErrUnsupportedModuleSource, location)
+	}
+	archiveType := query.Get("archive")
+	isTarGz := archiveType == "tar.gz" || archiveType == "tgz" ||
+		strings.HasSuffix(u.Path, ".tar.gz") || strings.HasSuffix(u.Path, ".tgz")
+	if !isTarGz {
+		return nil, fmt.Errorf("%w: %q is not a tar.gz archive", ErrUnsupportedModuleSource, location)
+	}
+	query.Del("archive")
+	u.RawQuery = query.Encode()
+	return &ModuleArchive{URL: u.String(), Subdir: subdir}, nil
+}
@@ -0,0 +1,151 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestListModuleVersions(t *testing.T) {
+	_, u := newTestRegistry(t, newDiscoveryHandler("/v1/providers/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/modules/hashicorp/consul/aws/versions" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"modules":[{"versions":[{"version":"0.1.0"},{"version":"0.2.0"}]}]}`))
+	})))
+
+	versions, err := u.ListModuleVersions(context.Background(), "hashicorp", "consul", "aws")
+	if err != nil {
+		t.Fatalf("ListModuleVersions: %v", err)
+	}
+	if len(versions) != 2 || versions[0] != "0.1.0" || versions[1] != "0.2.0" {
+		t.Errorf("versions = %v, want [0.1.0 0.2.0]", versions)
+	}
+}
+
+func TestListModuleVersions_UpstreamError(t *testing.T) {
+	_, u := newTestRegistry(t, newDiscoveryHandler("/v1/providers/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":["Not Found"]}`))
+	})))
+
+	_, err := u.ListModuleVersions(context.Background(), "hashicorp", "missing", "aws")
+	var statusErr *UpstreamStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("err = %v, want *UpstreamStatusError", err)
+	}
+	if statusErr.StatusCode != http.StatusNotFound || statusErr.Message != "Not Found" {
+		t.Errorf("statusErr = %+v, want 404 Not Found", statusErr)
+	}
+}
+
+func TestGetModuleDownloadURL(t *testing.T) {
+	srv, u := newTestRegistry(t, newDiscoveryHandler("/v1/providers/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/modules/hashicorp/consul/aws/0.1.0/download":
+			w.Header().Set("X-Terraform-Get", "git::https://github.com/hashicorp/terraform-aws-consul?ref=v0.1.0")
+		case "/v1/modules/acme/net/aws/1.0.0/download":
+			w.Header().Set("X-Terraform-Get", "/archives/net-1.0.0.tar.gz")
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	got, err := u.GetModuleDownloadURL(context.Background(), "hashicorp", "consul", "aws", "0.1.0")
+	if err != nil {
+		t.Fatalf("GetModuleDownloadURL: %v", err)
+	}
+	if got != "git::https://github.com/hashicorp/terraform-aws-consul?ref=v0.1.0" {
+		t.Errorf("location = %q", got)
+	}
+
+	got, err = u.GetModuleDownloadURL(context.Background(), "acme", "net", "aws", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetModuleDownloadURL relative: %v", err)
+	}
+	if got != srv.URL+"/archives/net-1.0.0.tar.gz" {
+		t.Errorf("relative location = %q, want resolved against %s", got, srv.URL)
+	}
+}
+
+func TestGetModuleDownloadURL_MissingHeader(t *testing.T) {
+	_, u := newTestRegistry(t, newDiscoveryHandler("/v1/providers/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	_, err := u.GetModuleDownloadURL(context.Background(), "a", "b", "c", "1.0.0")
+	var statusErr *UpstreamStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("err = %v, want 502 UpstreamStatusError", err)
+	}
+}
+
+func TestResolveModuleArchive(t *testing.T) {
+	tests := []struct {
+		location  string
+		wantURL   string
+		wantStrip bool
+		wantSub   string
+	}{
+		{
+			location:  "git::https://github.com/hashicorp/terraform-aws-consul?ref=v0.1.0",
+			wantURL:   "https://codeload.github.com/hashicorp/terraform-aws-consul/tar.gz/v0.1.0",
+			wantStrip: true,
+		},
+		{
+			location:  "git::https://github.com/terraform-aws-modules/terraform-aws-iam.git//modules/iam-user?ref=v5.0.0",
+			wantURL:   "https://codeload.github.com/terraform-aws-modules/terraform-aws-iam/tar.gz/v5.0.0",
+			wantStrip: true,
+			wantSub:   "modules/iam-user",
+		},
+		{
+			location:  "github.com/acme/terraform-null-label?ref=0.25.0",
+			wantURL:   "https://codeload.github.com/acme/terraform-null-label/tar.gz/0.25.0",
+			wantStrip: true,
+		},
+		{
+			location: "https://example.com/modules/net-1.0.0.tar.gz",
+			wantURL:  "https://example.com/modules/net-1.0.0.tar.gz",
+		},
+		{
+			location: "https://example.com/download?id=7&archive=tar.gz",
+			wantURL:  "https://example.com/download?id=7",
+		},
+		{
+			location: "https://example.com/modules/net.tgz//sub",
+			wantURL:  "https://example.com/modules/net.tgz",
+			wantSub:  "sub",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			got, err := ResolveModuleArchive(tt.location)
+			if err != nil {
+				t.Fatalf("ResolveModuleArchive: %v", err)
+			}
+			if got.URL != tt.wantURL || got.StripTopDir != tt.wantStrip || got.Subdir != tt.wantSub {
+				t.Errorf("got %+v, want {%s %v %s}", got, tt.wantURL, tt.wantStrip, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestResolveModuleArchive_Unsupported(t *testing.T) {
+	for _, loc := range []string{
+		"git::https://github.com/acme/repo",              // no ref
+		"git::https://gitlab.com/acme/repo?ref=v1",       // non-GitHub git
+		"s3::https://s3.amazonaws.com/bucket/mod.tar.gz", // forced getter
+		"http://example.com/mod.tar.gz",                  // plain http
+		"https://example.com/mod.zip",                    // not tar.gz
+		"https://example.com/mod.tar.gz//../../etc",      // subdir escape
+		"git::https://github.com/acme?ref=v1",            // missing repo
+	} {
+		if _, err := ResolveModuleArchive(loc); !errors.Is(err, ErrUnsupportedModuleSource) {
+			t.Errorf("ResolveModuleArchive(%q) err = %v, want ErrUnsupportedModuleSource", loc, err)
+		}
+	}
+}
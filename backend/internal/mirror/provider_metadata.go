@@ -0,0 +1,161 @@
+// provider_metadata.go fetches the descriptive metadata the upstream registry
+// publishes for a provider (description, source repository, tier) and extracts
+// the license file shipped inside provider archives, so mirrored providers can
+// answer "what license is this under" without a trip to the upstream.
+package mirror
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ProviderMetadata is the upstream registry's descriptive metadata for a
+// provider. Empty strings mean the upstream did not supply the field.
+type ProviderMetadata struct {
+	Description string
+	SourceURL   string
+	Tier        string
+}
+
+// GetProviderMetadata fetches a provider's description, source repository URL
+// and tier from the upstream registry's v2 provider API
+// (GET /v2/providers/{namespace}/{name}).
+func (u *UpstreamRegistry) GetProviderMetadata(ctx context.Context, namespace, providerName string) (*ProviderMetadata, error) {
+	providerURL := fmt.Sprintf("%s/v2/providers/%s/%s",
+		strings.TrimSuffix(u.BaseURL, "/"),
+		url.PathEscape(namespace),
+		url.PathEscape(providerName),
+	)
+	req, err := http.NewRequestWithContext(ctx, "GET", providerURL, nil) // #nosec G107 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	if err != nil {
+		return nil, fmt.Errorf("failed to create v2 provider request: %w", err)
+	}
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch v2 provider: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxUpstreamErrorBodyBytes))
+		return nil, fmt.Errorf("v2 provider lookup failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	var provResp providerV2Response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxUpstreamResponseBytes)).Decode(&provResp); err != nil {
+		return nil, fmt.Errorf("failed to decode v2 provider response: %w", err)
+	}
+	attrs := provResp.Data.Attributes
+	return &ProviderMetadata{
+		Description: strings.TrimSpace(attrs.Description),
+		SourceURL:   strings.TrimSpace(attrs.Source),
+		Tier:        strings.ToLower(strings.TrimSpace(attrs.Tier)),
+	}, nil
+}
+
+// MaxLicenseTextBytes caps the license text kept from a provider archive.
+// Real license files are a few tens of KiB; the cap stops a hostile archive
+// from inflating the providers row.
+const MaxLicenseTextBytes = 256 << 10
+
+// LicenseInfo describes the license file found in a provider archive.
+type LicenseInfo struct {
+	// File is the archive entry name, e.g. "LICENSE.txt".
+	File string
+	// Text is the file contents, truncated to MaxLicenseTextBytes.
+	Text string
+	// SPDX is the detected SPDX identifier, or "" when unrecognised.
+	SPDX string
+}
+
+// licenseFileNames are the top-level archive entries treated as a license
+// file, in order of preference (lower-cased).
+var licenseFileNames = []string{
+	"license", "license.txt", "license.md",
+	"licence", "licence.txt", "licence.md",
+	"copying", "copying.txt",
+}
+
+// ExtractLicense looks for a license file at the top level of a provider zip
+// archive and returns its contents. It returns (nil, nil) when the archive has
+// no license file.
+func ExtractLicense(r io.ReaderAt, size int64) (*LicenseInfo, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open provider archive: %w", err)
+	}
+
+	byName := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || strings.Contains(strings.TrimPrefix(f.Name, "./"), "/") {
+			continue
+		}
+		name := strings.ToLower(path.Base(f.Name))
+		if _, seen := byName[name]; !seen {
+			byName[name] = f
+		}
+	}
+
+	for _, candidate := range licenseFileNames {
+		f, ok := byName[candidate]
+		if !ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s in provider archive: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, MaxLicenseTextBytes))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s in provider archive: %w", f.Name, err)
+		}
+		text := strings.ToValidUTF8(string(data), string(utf8.RuneError))
+		return &LicenseInfo{
+			File: path.Base(f.Name),
+			Text: text,
+			SPDX: DetectLicense(text),
+		}, nil
+	}
+	return nil, nil
+}
+
+// licenseSignatures maps distinctive phrases to SPDX identifiers. Order
+// matters: more specific licenses are checked before ones whose wording they
+// contain (LGPL before GPL, BSD-3 before BSD-2).
+var licenseSignatures = []struct {
+	spdx    string
+	pattern *regexp.Regexp
+}{
+	{"BUSL-1.1", regexp.MustCompile(`business source license 1\.1`)},
+	{"MPL-2.0", regexp.MustCompile(`mozilla public license,? version 2\.0`)},
+	{"Apache-2.0", regexp.MustCompile(`apache license,? version 2\.0`)},
+	{"AGPL-3.0", regexp.MustCompile(`gnu affero general public license\s+version 3`)},
+	{"LGPL-3.0", regexp.MustCompile(`gnu lesser general public license\s+version 3`)},
+	{"LGPL-2.1", regexp.MustCompile(`gnu lesser general public license\s+version 2\.1`)},
+	{"GPL-3.0", regexp.MustCompile(`gnu general public license\s+version 3`)},
+	{"GPL-2.0", regexp.MustCompile(`gnu general public license\s+version 2`)},
+	{"BSD-3-Clause", regexp.MustCompile(`neither the name of .* nor the names of its\s+contributors may be used`)},
+	{"BSD-2-Clause", regexp.MustCompile(`redistributions in binary form must reproduce the above\s+copyright notice`)},
+	{"MIT", regexp.MustCompile(`permission is hereby granted, free of charge, to any person obtaining a copy`)},
+	{"ISC", regexp.MustCompile(`permission to use, copy, modify, and/or distribute this software for any`)},
+}
+
+// DetectLicense returns the SPDX identifier of a well-known license text, or
+// "" when the text matches none of them.
+func DetectLicense(text string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	for _, sig := range licenseSignatures {
+		if sig.pattern.MatchString(normalized) {
+			return sig.spdx
+		}
+	}
+	return ""
+}
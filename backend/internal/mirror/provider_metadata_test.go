@@ -0,0 +1,133 @@
+package mirror
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetProviderMetadata(t *testing.T) {
+	_, reg := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/providers/hashicorp/aws" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"id":"323","attributes":{
+			"description":" The AWS provider ",
+			"source":"https://github.com/hashicorp/terraform-provider-aws",
+			"tier":"Official"}}}`))
+	})
+
+	meta, err := reg.GetProviderMetadata(context.Background(), "hashicorp", "aws")
+	if err != nil {
+		t.Fatalf("GetProviderMetadata: %v", err)
+	}
+	if meta.Description != "The AWS provider" {
+		t.Errorf("Description = %q", meta.Description)
+	}
+	if meta.SourceURL != "https://github.com/hashicorp/terraform-provider-aws" {
+		t.Errorf("SourceURL = %q", meta.SourceURL)
+	}
+	if meta.Tier != "official" {
+		t.Errorf("Tier = %q, want official", meta.Tier)
+	}
+}
+
+func TestGetProviderMetadata_UpstreamError(t *testing.T) {
+	_, reg := newTestRegistry(t, func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "nope", http.StatusNotFound)
+	})
+	if _, err := reg.GetProviderMetadata(context.Background(), "hashicorp", "missing"); err == nil {
+		t.Fatal("expected error for 404 upstream response")
+	}
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractLicense(t *testing.T) {
+	mpl := "Mozilla Public License Version 2.0\n==================================\n"
+	data := buildZip(t, map[string]string{
+		"terraform-provider-aws_v5.0.0_x5": "binary",
+		"LICENSE.txt":                      "Copyright (c) HashiCorp, Inc.\n\n" + mpl,
+		"docs/LICENSE":                     "ignored nested file",
+	})
+
+	info, err := ExtractLicense(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ExtractLicense: %v", err)
+	}
+	if info == nil {
+		t.Fatal("expected a license")
+	}
+	if info.File != "LICENSE.txt" || info.SPDX != "MPL-2.0" || !strings.Contains(info.Text, "Mozilla") {
+		t.Errorf("info = %+v", info)
+	}
+}
+
+func TestExtractLicense_NoLicense(t *testing.T) {
+	data := buildZip(t, map[string]string{"terraform-provider-x": "binary", "sub/LICENSE": "nested"})
+	info, err := ExtractLicense(bytes.NewReader(data), int64(len(data)))
+	if err != nil || info != nil {
+		t.Fatalf("got (%+v, %v), want (nil, nil)", info, err)
+	}
+}
+
+func TestExtractLicense_Truncates(t *testing.T) {
+	data := buildZip(t, map[string]string{"COPYING": strings.Repeat("x", MaxLicenseTextBytes+100)})
+	info, err := ExtractLicense(bytes.NewReader(data), int64(len(data)))
+	if err != nil || info == nil {
+		t.Fatalf("got (%+v, %v)", info, err)
+	}
+	if len(info.Text) != MaxLicenseTextBytes {
+		t.Errorf("len(Text) = %d, want %d", len(info.Text), MaxLicenseTextBytes)
+	}
+}
+
+func TestExtractLicense_NotAZip(t *testing.T) {
+	data := []byte("not a zip")
+	if _, err := ExtractLicense(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Fatal("expected error for a non-zip archive")
+	}
+}
+
+func TestDetectLicense(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Mozilla Public License, version 2.0", "MPL-2.0"},
+		{"Apache License\n                           Version 2.0, January 2004", "Apache-2.0"},
+		{"License text copyright (c) 2020 HashiCorp, Inc.\nBusiness Source License 1.1", "BUSL-1.1"},
+		{"Permission is hereby granted, free of charge, to any person obtaining a copy of this software", "MIT"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007", "LGPL-3.0"},
+		{"GNU GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007", "GPL-3.0"},
+		{"Redistributions in binary form must reproduce the above copyright notice ... Neither the name of Foo nor the names of its contributors may be used", "BSD-3-Clause"},
+		{"Redistributions in binary form must reproduce the above\ncopyright notice", "BSD-2-Clause"},
+		{"All rights reserved.", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectLicense(tt.text); got != tt.want {
+			t.Errorf("DetectLicense(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// signer.go produces the registry's own detached OpenPGP signatures over the
+// SHA256SUMS of mirrored providers (mirror_signing). Two key sources are
+// supported: an armored private key held in process, and an external signer
+// service that never exposes the key (e.g. an HSM front end).
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// maxSignatureBytes caps an external signer's response. Detached signatures
+// are a few hundred bytes; 64 KB matches the upload limit for .sig files.
+const maxSignatureBytes = 64 << 10
+
+// Signer signs SHA256SUMS content with the registry's mirror signing key.
+type Signer interface {
+	// Sign returns a binary detached signature over data.
+	Sign(ctx context.Context, data []byte) ([]byte, error)
+	// PublicKey is the ASCII-armored public key that verifies Sign's output.
+	PublicKey() string
+	// KeyID is the primary key's long key ID (16 uppercase hex characters).
+	KeyID() string
+}
+
+// NewSigner builds the Signer described by cfg. guard is the egress policy
+// for an external signer (nil = strict). It returns (nil, nil) when re-signing
+// is disabled.
+func NewSigner(cfg config.MirrorSigningConfig, guard *httpsafe.Guard) (Signer, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.SignerURL != "" {
+		return NewRemoteSigner(cfg.SignerURL, cfg.SignerToken, cfg.PublicKey, httpsafe.NewClient(30*time.Second, guard))
+	}
+	armored := cfg.PrivateKey
+	if cfg.PrivateKeyFile != "" {
+		data, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mirror_signing.private_key_file: %w", err)
+		}
+		armored = string(data)
+	}
+	return NewKeySigner(armored, cfg.Passphrase)
+}
+
+// keySigner signs in process with an OpenPGP private key.
+type keySigner struct {
+	entity    *openpgp.Entity
+	publicKey string
+	keyID     string
+}
+
+// NewKeySigner parses an ASCII-armored private key, decrypting it with
+// passphrase when it is encrypted.
+func NewKeySigner(armoredPrivateKey, passphrase string) (Signer, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredPrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mirror signing key: %w", err)
+	}
+	if len(entities) == 0 || entities[0].PrivateKey == nil {
+		return nil, errors.New("mirror signing key contains no private key")
+	}
+	entity := entities[0]
+	if entity.PrivateKey.Encrypted {
+		if passphrase == "" {
+			return nil, errors.New("mirror signing key is encrypted but no passphrase is configured")
+		}
+		if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt mirror signing key: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to armor mirror signing public key: %w", err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		return nil, fmt.Errorf("failed to serialize mirror signing public key: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to armor mirror signing public key: %w", err)
+	}
+
+	return &keySigner{entity: entity, publicKey: buf.String(), keyID: entity.PrimaryKey.KeyIdString()}, nil
+}
+
+func (s *keySigner) Sign(_ context.Context, data []byte) ([]byte, error) {
+	var sig bytes.Buffer
+	if err := openpgp.DetachSign(&sig, s.entity, bytes.NewReader(data), nil); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return sig.Bytes(), nil
+}
+
+func (s *keySigner) PublicKey() string { return s.publicKey }
+func (s *keySigner) KeyID() string     { return s.keyID }
+
+// remoteSigner delegates signing to an external HTTP service.
+type remoteSigner struct {
+	url       string
+	token     string
+	publicKey string
+	keyID     string
+	client    *http.Client
+}
+
+// NewRemoteSigner returns a Signer that POSTs data to signerURL. Every
+// signature it returns is verified against publicKey before use.
+func NewRemoteSigner(signerURL, token, publicKey string, client *http.Client) (Signer, error) {
+	keyID, err := validation.ExtractKeyID(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("mirror_signing.public_key: %w", err)
+	}
+	return &remoteSigner{url: signerURL, token: token, publicKey: publicKey, keyID: keyID, client: client}, nil
+}
+
+func (s *remoteSigner) Sign(ctx context.Context, data []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build signer request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("signer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signer returned %s", resp.Status)
+	}
+	sig, err := io.ReadAll(io.LimitReader(resp.Body, maxSignatureBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signer response: %w", err)
+	}
+	if len(sig) > maxSignatureBytes {
+		return nil, errors.New("signer response exceeds the signature size limit")
+	}
+	if block, err := armor.Decode(bytes.NewReader(sig)); err == nil {
+		if sig, err = io.ReadAll(block.Body); err != nil {
+			return nil, fmt.Errorf("failed to read armored signature: %w", err)
+		}
+	}
+	if err := validation.VerifySignature(s.publicKey, data, sig); err != nil {
+		return nil, fmt.Errorf("signer returned a signature that does not verify against mirror_signing.public_key: %w", err)
+	}
+	return sig, nil
+}
+
+func (s *remoteSigner) PublicKey() string { return s.publicKey }
+func (s *remoteSigner) KeyID() string     { return s.keyID }
@@ -0,0 +1,213 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// armoredTestPrivateKey generates a signing key and returns it ASCII-armored,
+// encrypted with passphrase when one is given. The returned entity is only
+// usable for signing when passphrase is empty.
+func armoredTestPrivateKey(t *testing.T, passphrase string) (string, *openpgp.Entity) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Registry Signing", "test", "signing@example.com", nil)
+	if err != nil {
+		t.Fatalf("openpgp.NewEntity: %v", err)
+	}
+	if passphrase != "" {
+		if err := entity.EncryptPrivateKeys([]byte(passphrase), nil); err != nil {
+			t.Fatalf("EncryptPrivateKeys: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatalf("armor.Encode: %v", err)
+	}
+	if err := entity.SerializePrivateWithoutSigning(w, nil); err != nil {
+		t.Fatalf("SerializePrivateWithoutSigning: %v", err)
+	}
+	w.Close()
+	return buf.String(), entity
+}
+
+func TestNewSigner_Disabled(t *testing.T) {
+	s, err := NewSigner(config.MirrorSigningConfig{}, nil)
+	if s != nil || err != nil {
+		t.Fatalf("got (%v, %v), want (nil, nil)", s, err)
+	}
+}
+
+func TestKeySigner_SignVerifies(t *testing.T) {
+	armored, _ := armoredTestPrivateKey(t, "")
+	s, err := NewKeySigner(armored, "")
+	if err != nil {
+		t.Fatalf("NewKeySigner: %v", err)
+	}
+
+	data := []byte("abcd  terraform-provider-aws_5.0.0_linux_amd64.zip\n")
+	sig, err := s.Sign(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if err := validation.VerifySignature(s.PublicKey(), data, sig); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+	keyID, err := validation.ExtractKeyID(s.PublicKey())
+	if err != nil || keyID != s.KeyID() {
+		t.Errorf("KeyID = %q, public key ID = %q (%v)", s.KeyID(), keyID, err)
+	}
+	if strings.Contains(s.PublicKey(), "PRIVATE KEY") {
+		t.Error("PublicKey leaks the private key")
+	}
+}
+
+func TestKeySigner_EncryptedKey(t *testing.T) {
+	armored, _ := armoredTestPrivateKey(t, "s3cret")
+
+	if _, err := NewKeySigner(armored, ""); err == nil || !strings.Contains(err.Error(), "no passphrase") {
+		t.Errorf("missing passphrase: err = %v", err)
+	}
+	if _, err := NewKeySigner(armored, "wrong"); err == nil {
+		t.Error("wrong passphrase: expected error")
+	}
+
+	s, err := NewKeySigner(armored, "s3cret")
+	if err != nil {
+		t.Fatalf("NewKeySigner: %v", err)
+	}
+	sig, err := s.Sign(context.Background(), []byte("data"))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if err := validation.VerifySignature(s.PublicKey(), []byte("data"), sig); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
+
+func TestNewSigner_PrivateKeyFile(t *testing.T) {
+	armored, _ := armoredTestPrivateKey(t, "")
+	path := filepath.Join(t.TempDir(), "signing.asc")
+	if err := os.WriteFile(path, []byte(armored), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSigner(config.MirrorSigningConfig{Enabled: true, PrivateKeyFile: path}, nil)
+	if err != nil || s == nil {
+		t.Fatalf("NewSigner: (%v, %v)", s, err)
+	}
+
+	if _, err := NewSigner(config.MirrorSigningConfig{Enabled: true, PrivateKeyFile: path + ".missing"}, nil); err == nil {
+		t.Error("missing key file: expected error")
+	}
+}
+
+func TestNewKeySigner_Invalid(t *testing.T) {
+	if _, err := NewKeySigner("not a key", ""); err == nil {
+		t.Error("expected error for garbage input")
+	}
+}
+
+// remoteSignerServer signs requests with entity, optionally armoring the
+// response, and records the Authorization header it received.
+func remoteSignerServer(t *testing.T, entity *openpgp.Entity, armored bool, gotAuth *string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*gotAuth = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		if armored {
+			_ = openpgp.ArmoredDetachSign(w, entity, bytes.NewReader(data), nil)
+			return
+		}
+		_ = openpgp.DetachSign(w, entity, bytes.NewReader(data), nil)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRemoteSigner(t *testing.T) {
+	armored, entity := armoredTestPrivateKey(t, "")
+	keySigner, err := NewKeySigner(armored, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := httpsafe.NewClient(0, httpsafe.MustGuard("127.0.0.1", "::1"))
+
+	for _, armorResp := range []bool{false, true} {
+		var auth string
+		srv := remoteSignerServer(t, entity, armorResp, &auth)
+		s, err := NewRemoteSigner(srv.URL, "tok", keySigner.PublicKey(), client)
+		if err != nil {
+			t.Fatalf("NewRemoteSigner: %v", err)
+		}
+		sig, err := s.Sign(context.Background(), []byte("sums"))
+		if err != nil {
+			t.Fatalf("armored=%v: Sign: %v", armorResp, err)
+		}
+		if err := validation.VerifySignature(s.PublicKey(), []byte("sums"), sig); err != nil {
+			t.Errorf("armored=%v: signature does not verify: %v", armorResp, err)
+		}
+		if auth != "Bearer tok" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if s.KeyID() != keySigner.KeyID() {
+			t.Errorf("KeyID = %q, want %q", s.KeyID(), keySigner.KeyID())
+		}
+	}
+}
+
+func TestRemoteSigner_RejectsWrongKey(t *testing.T) {
+	_, entity := armoredTestPrivateKey(t, "")
+	otherArmored, _ := armoredTestPrivateKey(t, "")
+	other, err := NewKeySigner(otherArmored, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var auth string
+	srv := remoteSignerServer(t, entity, false, &auth)
+
+	s, err := NewRemoteSigner(srv.URL, "", other.PublicKey(), httpsafe.NewClient(0, httpsafe.MustGuard("127.0.0.1", "::1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Sign(context.Background(), []byte("sums")); err == nil || !strings.Contains(err.Error(), "does not verify") {
+		t.Errorf("err = %v, want verification failure", err)
+	}
+}
+
+func TestRemoteSigner_ErrorStatus(t *testing.T) {
+	armored, _ := armoredTestPrivateKey(t, "")
+	ks, _ := NewKeySigner(armored, "")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	s, err := NewRemoteSigner(srv.URL, "", ks.PublicKey(), httpsafe.NewClient(0, httpsafe.MustGuard("127.0.0.1", "::1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Sign(context.Background(), []byte("sums")); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("err = %v, want 403 error", err)
+	}
+}
+
+func TestNewRemoteSigner_InvalidPublicKey(t *testing.T) {
+	if _, err := NewRemoteSigner("https://signer.example.com", "", "garbage", http.DefaultClient); err == nil {
+		t.Error("expected error for invalid public key")
+	}
+}
@@ -395,10 +395,15 @@ func (c *TerraformReleasesClient) ListVersions(ctx context.Context) ([]Terraform
 
 // ----- SHA256SUMS fetching & parsing ----------------------------------------
 
+// SHASumsURL returns the URL of the SHA256SUMS file for a given version.
+func (c *TerraformReleasesClient) SHASumsURL(_ context.Context, version string) (string, error) {
+	return fmt.Sprintf("%s/%s/%s/%s_%s_SHA256SUMS", c.UpstreamURL, c.ProductName, version, c.ProductName, version), nil
+}
+
 // FetchSHASums downloads the SHA256SUMS file for a given version and returns
 // a map of filename → hex-sha256 plus the raw bytes (needed for GPG verify).
 func (c *TerraformReleasesClient) FetchSHASums(ctx context.Context, version string) (map[string]string, []byte, error) {
-	sumsURL := fmt.Sprintf("%s/%s/%s/%s_%s_SHA256SUMS", c.UpstreamURL, c.ProductName, version, c.ProductName, version)
+	sumsURL, _ := c.SHASumsURL(ctx, version)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sumsURL, nil)
 	if err != nil {
@@ -253,6 +253,17 @@ func TestFetchSHASums_NonOKStatus(t *testing.T) {
 	}
 }
 
+func TestSHASumsURL(t *testing.T) {
+	c := NewTerraformReleasesClient("https://releases.example.com", "opentofu")
+	got, err := c.SHASumsURL(context.Background(), "1.8.0")
+	if err != nil {
+		t.Fatalf("SHASumsURL error: %v", err)
+	}
+	if want := "https://releases.example.com/opentofu/1.8.0/opentofu_1.8.0_SHA256SUMS"; got != want {
+		t.Errorf("SHASumsURL = %q, want %q", got, want)
+	}
+}
+
 func TestFetchSHASums_InvalidURL(t *testing.T) {
 	c := NewTerraformReleasesClient("http://127.0.0.1:0", "terraform")
 	_, _, err := c.FetchSHASums(context.Background(), "1.0.0")
@@ -92,10 +92,16 @@ type ProviderVersion struct {
 	Platforms []ProviderPlatform `json:"platforms"`
 }
 
-// ProviderPlatform represents a platform-specific build of a provider
+// ProviderPlatform represents a platform-specific build of a provider. The
+// protocol's version listing only carries os and arch; some registries also
+// include the archive's filename, download URL and checksum, which lets a sync
+// skip the platform's package lookup (see VersionPackages).
 type ProviderPlatform struct {
-	OS   string `json:"os"`
-	Arch string `json:"arch"`
+	OS          string `json:"os"`
+	Arch        string `json:"arch"`
+	Filename    string `json:"filename,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"`
+	SHA256Sum   string `json:"shasum,omitempty"`
 }
 
 // ProviderPackageResponse represents the download information for a specific provider version
@@ -155,6 +161,42 @@ func (u *UpstreamRegistry) DiscoverServices(ctx context.Context) (*ServiceDiscov
 
 // ListProviderVersions lists all available versions of a provider from upstream
 func (u *UpstreamRegistry) ListProviderVersions(ctx context.Context, namespace, providerName string) ([]ProviderVersion, error) {
+	listing, err := u.ListProviderVersionsConditional(ctx, namespace, providerName, VersionsValidators{})
+	if err != nil {
+		return nil, err
+	}
+	return listing.Versions, nil
+}
+
+// VersionsValidators are the HTTP cache validators (ETag and Last-Modified)
+// of a provider's version listing. Either may be empty: not every upstream
+// emits both, or any.
+type VersionsValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// IsZero reports whether no validator is set.
+func (v VersionsValidators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// ProviderVersionsListing is the result of a conditional version listing.
+type ProviderVersionsListing struct {
+	// NotModified is true when the upstream answered 304 to the validators
+	// sent; Versions is then nil and Validators are the ones sent.
+	NotModified bool
+	Versions    []ProviderVersion
+	// Validators are those the upstream returned with the listing, to send
+	// next time. Empty when it returned none.
+	Validators VersionsValidators
+}
+
+// ListProviderVersionsConditional lists a provider's versions like
+// ListProviderVersions, sending since as If-None-Match / If-Modified-Since so
+// an upstream whose listing has not changed can answer 304 without a body.
+// A zero since makes it an unconditional listing.
+func (u *UpstreamRegistry) ListProviderVersionsConditional(ctx context.Context, namespace, providerName string, since VersionsValidators) (*ProviderVersionsListing, error) {
 	// First, discover the providers endpoint
 	discovery, err := u.DiscoverServices(ctx)
 	if err != nil {
@@ -177,6 +219,12 @@ func (u *UpstreamRegistry) ListProviderVersions(ctx context.Context, namespace,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create versions request: %w", err)
 	}
+	if since.ETag != "" {
+		req.Header.Set("If-None-Match", since.ETag)
+	}
+	if since.LastModified != "" {
+		req.Header.Set("If-Modified-Since", since.LastModified)
+	}
 
 	resp, err := u.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
 	if err != nil {
@@ -184,8 +232,15 @@ func (u *UpstreamRegistry) ListProviderVersions(ctx context.Context, namespace,
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		if since.IsZero() {
+			return nil, fmt.Errorf("versions request answered 304 to an unconditional request")
+		}
+		return &ProviderVersionsListing{NotModified: true, Validators: since}, nil
+	}
+
 	if resp.StatusCode == http.StatusNotFound {
-		return []ProviderVersion{}, nil
+		return &ProviderVersionsListing{Versions: []ProviderVersion{}}, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -203,7 +258,13 @@ func (u *UpstreamRegistry) ListProviderVersions(ctx context.Context, namespace,
 		return nil, fmt.Errorf("failed to decode versions response: %w", err)
 	}
 
-	return versionsResp.Versions, nil
+	return &ProviderVersionsListing{
+		Versions: versionsResp.Versions,
+		Validators: VersionsValidators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+	}, nil
 }
 
 // GetProviderPackage gets the download information for a specific provider version and platform
@@ -398,7 +459,12 @@ type providerDocContentV2 struct {
 // providerV2Response is the JSON:API envelope for GET /v2/providers/{namespace}/{name}.
 type providerV2Response struct {
 	Data struct {
-		ID string `json:"id"`
+		ID         string `json:"id"`
+		Attributes struct {
+			Description string `json:"description"`
+			Source      string `json:"source"`
+			Tier        string `json:"tier"`
+		} `json:"attributes"`
 	} `json:"data"`
 }
 
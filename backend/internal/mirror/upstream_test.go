@@ -216,6 +216,88 @@ func TestListProviderVersions_ServerError(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ListProviderVersionsConditional
+// ---------------------------------------------------------------------------
+
+// conditionalVersionsHandler serves a version listing with an ETag and a
+// Last-Modified, answering 304 to a request carrying the ETag.
+func conditionalVersionsHandler(t *testing.T, gotHeaders *http.Header) http.HandlerFunc {
+	t.Helper()
+	return newDiscoveryHandler("/v1/providers/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*gotHeaders = r.Header.Clone()
+		if r.Header.Get("If-None-Match") == `"v2"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v2"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		_, _ = w.Write([]byte(`{"versions":[{"version":"5.0.0"}]}`))
+	}))
+}
+
+func TestListProviderVersionsConditional_ReturnsValidators(t *testing.T) {
+	var got http.Header
+	_, u := newTestRegistry(t, conditionalVersionsHandler(t, &got))
+
+	listing, err := u.ListProviderVersionsConditional(context.Background(), "hashicorp", "aws", VersionsValidators{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Get("If-None-Match") != "" || got.Get("If-Modified-Since") != "" {
+		t.Errorf("unconditional listing sent validators: %v", got)
+	}
+	if listing.NotModified || len(listing.Versions) != 1 {
+		t.Errorf("listing = %+v, want one version", listing)
+	}
+	want := VersionsValidators{ETag: `"v2"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}
+	if listing.Validators != want {
+		t.Errorf("validators = %+v, want %+v", listing.Validators, want)
+	}
+}
+
+func TestListProviderVersionsConditional_NotModified(t *testing.T) {
+	var got http.Header
+	_, u := newTestRegistry(t, conditionalVersionsHandler(t, &got))
+
+	since := VersionsValidators{ETag: `"v2"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}
+	listing, err := u.ListProviderVersionsConditional(context.Background(), "hashicorp", "aws", since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Get("If-None-Match") != since.ETag || got.Get("If-Modified-Since") != since.LastModified {
+		t.Errorf("request headers = %v, want the validators sent", got)
+	}
+	if !listing.NotModified || listing.Versions != nil || listing.Validators != since {
+		t.Errorf("listing = %+v, want not modified with the validators sent", listing)
+	}
+}
+
+func TestListProviderVersionsConditional_NoValidators(t *testing.T) {
+	_, u := newTestRegistry(t, newDiscoveryHandler("/v1/providers/", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"versions":[{"version":"5.0.0"}]}`))
+	})))
+
+	// An upstream that ignores the validators answers in full, and sends none back.
+	listing, err := u.ListProviderVersionsConditional(context.Background(), "hashicorp", "aws", VersionsValidators{ETag: `"old"`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if listing.NotModified || len(listing.Versions) != 1 || !listing.Validators.IsZero() {
+		t.Errorf("listing = %+v, want a full listing without validators", listing)
+	}
+}
+
+func TestListProviderVersionsConditional_UnexpectedNotModified(t *testing.T) {
+	_, u := newTestRegistry(t, newDiscoveryHandler("/v1/providers/", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})))
+
+	if _, err := u.ListProviderVersionsConditional(context.Background(), "hashicorp", "aws", VersionsValidators{}); err == nil {
+		t.Error("expected error for 304 to an unconditional request")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // GetProviderPackage
 // ---------------------------------------------------------------------------
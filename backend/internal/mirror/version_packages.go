@@ -0,0 +1,102 @@
+// version_packages.go builds the package info for every platform of a
+// provider version from metadata fetched once for the version, so a mirror
+// sync does not repeat the per-platform download lookup — which returns the
+// same signing keys and SHASUMS URLs every time — for each platform.
+package mirror
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// VersionPackages is the version-level package metadata of one provider
+// version: the package info fetched for one of its platforms (which carries
+// the signing keys and SHASUMS URLs shared by all of them) and the parsed
+// SHA256SUMS file. A nil *VersionPackages resolves no platforms.
+type VersionPackages struct {
+	// Base is the package info upstream returned for one platform of the
+	// version; nil when the lookup failed.
+	Base *ProviderPackageResponse
+	// Shasums maps archive filenames to their SHA256 checksums.
+	Shasums map[string]string
+}
+
+// Checksum returns the SHA256SUMS checksum of filename, or "" when unknown.
+func (v *VersionPackages) Checksum(filename string) string {
+	if v == nil {
+		return ""
+	}
+	return v.Shasums[filename]
+}
+
+// Platform returns the package info for p without asking upstream, or nil
+// when the caller has to fetch it with GetProviderPackage. The download URL
+// comes from p itself when the version listing carried one, and otherwise
+// from Base's download directory and the platform's archive in the
+// SHA256SUMS file. A package is only built when its checksum is known, so
+// the archive is still verified against the (signed) SHA256SUMS.
+func (v *VersionPackages) Platform(p ProviderPlatform) *ProviderPackageResponse {
+	if v == nil || v.Base == nil {
+		return nil
+	}
+	if v.Base.OS == p.OS && v.Base.Arch == p.Arch {
+		return v.Base
+	}
+
+	pkg := *v.Base
+	pkg.OS, pkg.Arch = p.OS, p.Arch
+	pkg.SHA256Sum = p.SHA256Sum
+	if p.DownloadURL != "" {
+		pkg.DownloadURL = p.DownloadURL
+		pkg.Filename = p.Filename
+		if pkg.Filename == "" {
+			u, err := url.Parse(p.DownloadURL)
+			if err != nil {
+				return nil
+			}
+			pkg.Filename = path.Base(u.Path)
+		}
+	} else {
+		dir := v.downloadDir()
+		filename := v.archiveName(p)
+		if dir == "" || filename == "" {
+			return nil
+		}
+		pkg.Filename = filename
+		pkg.DownloadURL = dir + filename
+	}
+	if pkg.SHA256Sum == "" {
+		pkg.SHA256Sum = v.Shasums[pkg.Filename]
+	}
+	if pkg.SHA256Sum == "" {
+		return nil
+	}
+	return &pkg
+}
+
+// downloadDir returns the URL Base's archive was served from, without the
+// archive name, or "" when Base's download URL does not end in its filename
+// (for example, when it is a signed URL with a query string).
+func (v *VersionPackages) downloadDir() string {
+	u, err := url.Parse(v.Base.DownloadURL)
+	if err != nil || u.RawQuery != "" || u.Fragment != "" || v.Base.Filename == "" ||
+		path.Base(u.Path) != v.Base.Filename || !strings.HasSuffix(v.Base.DownloadURL, v.Base.Filename) {
+		return ""
+	}
+	return strings.TrimSuffix(v.Base.DownloadURL, v.Base.Filename)
+}
+
+// archiveName returns p's archive name by swapping the platform suffix of
+// Base's filename, or "" when the result is not listed in SHA256SUMS.
+func (v *VersionPackages) archiveName(p ProviderPlatform) string {
+	suffix := "_" + v.Base.OS + "_" + v.Base.Arch + ".zip"
+	if v.Base.OS == "" || !strings.HasSuffix(v.Base.Filename, suffix) {
+		return ""
+	}
+	name := strings.TrimSuffix(v.Base.Filename, suffix) + "_" + p.OS + "_" + p.Arch + ".zip"
+	if _, ok := v.Shasums[name]; !ok {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,74 @@
+package mirror
+
+import "testing"
+
+func testVersionPackages() *VersionPackages {
+	return &VersionPackages{
+		Base: &ProviderPackageResponse{
+			OS:                  "linux",
+			Arch:                "amd64",
+			Filename:            "terraform-provider-aws_5.0.0_linux_amd64.zip",
+			DownloadURL:         "https://releases.example.com/terraform-provider-aws/5.0.0/terraform-provider-aws_5.0.0_linux_amd64.zip",
+			SHASumsURL:          "https://releases.example.com/terraform-provider-aws/5.0.0/SHA256SUMS",
+			SHASumsSignatureURL: "https://releases.example.com/terraform-provider-aws/5.0.0/SHA256SUMS.sig",
+			SHA256Sum:           "aaa",
+			SigningKeys:         SigningKeysInfo{GPGPublicKeys: []GPGPublicKey{{KeyID: "K1"}}},
+		},
+		Shasums: map[string]string{
+			"terraform-provider-aws_5.0.0_linux_amd64.zip":  "aaa",
+			"terraform-provider-aws_5.0.0_darwin_arm64.zip": "bbb",
+		},
+	}
+}
+
+func TestVersionPackages_PlatformFromShasums(t *testing.T) {
+	v := testVersionPackages()
+	pkg := v.Platform(ProviderPlatform{OS: "darwin", Arch: "arm64"})
+	if pkg == nil {
+		t.Fatal("Platform returned nil")
+	}
+	if pkg.Filename != "terraform-provider-aws_5.0.0_darwin_arm64.zip" ||
+		pkg.DownloadURL != "https://releases.example.com/terraform-provider-aws/5.0.0/terraform-provider-aws_5.0.0_darwin_arm64.zip" ||
+		pkg.SHA256Sum != "bbb" || pkg.OS != "darwin" || pkg.Arch != "arm64" ||
+		pkg.SHASumsURL != v.Base.SHASumsURL || len(pkg.SigningKeys.GPGPublicKeys) != 1 {
+		t.Errorf("package = %+v", pkg)
+	}
+	if v.Base.OS != "linux" || v.Base.SHA256Sum != "aaa" {
+		t.Errorf("Base was modified: %+v", v.Base)
+	}
+	if got := v.Platform(ProviderPlatform{OS: "linux", Arch: "amd64"}); got != v.Base {
+		t.Errorf("Base platform = %+v, want Base", got)
+	}
+}
+
+func TestVersionPackages_PlatformFromListing(t *testing.T) {
+	v := testVersionPackages()
+	pkg := v.Platform(ProviderPlatform{OS: "windows", Arch: "amd64",
+		DownloadURL: "https://cdn.example.com/aws/terraform-provider-aws_5.0.0_windows_amd64.zip", SHA256Sum: "ccc"})
+	if pkg == nil || pkg.Filename != "terraform-provider-aws_5.0.0_windows_amd64.zip" || pkg.SHA256Sum != "ccc" {
+		t.Errorf("package = %+v", pkg)
+	}
+}
+
+func TestVersionPackages_PlatformNeedsLookup(t *testing.T) {
+	signed := testVersionPackages()
+	signed.Base.DownloadURL += "?X-Amz-Signature=abc"
+
+	for name, tc := range map[string]struct {
+		v *VersionPackages
+		p ProviderPlatform
+	}{
+		"nil":                  {nil, ProviderPlatform{OS: "darwin", Arch: "arm64"}},
+		"no base":              {&VersionPackages{Shasums: testVersionPackages().Shasums}, ProviderPlatform{OS: "darwin", Arch: "arm64"}},
+		"not in shasums":       {testVersionPackages(), ProviderPlatform{OS: "freebsd", Arch: "386"}},
+		"signed download url":  {signed, ProviderPlatform{OS: "darwin", Arch: "arm64"}},
+		"listing without hash": {testVersionPackages(), ProviderPlatform{OS: "windows", Arch: "amd64", DownloadURL: "https://cdn.example.com/x.zip"}},
+	} {
+		if pkg := tc.v.Platform(tc.p); pkg != nil {
+			t.Errorf("%s: package = %+v, want nil", name, pkg)
+		}
+	}
+	if (*VersionPackages)(nil).Checksum("x") != "" {
+		t.Error("nil Checksum should be empty")
+	}
+}
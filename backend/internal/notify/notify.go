@@ -41,6 +41,10 @@ const (
 	EventApprovalPending        = "approval_pending"
 	EventCVEDetected            = "cve_detected"
 	EventScannerUpdateAvailable = "scanner_update_available"
+	// EventMirrorStorageThreshold fires when a provider or Terraform binary
+	// mirror's stored archives reach 80% and then 100% of its
+	// max_storage_bytes. It is only routed to notification channels.
+	EventMirrorStorageThreshold = "mirror_storage_threshold"
 )
 
 // ParseRecipients is aliased to the shared implementation.
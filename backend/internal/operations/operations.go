@@ -0,0 +1,235 @@
+// Package operations tracks long-running work in this process — uploads, mirror
+// syncs, storage migrations, exports — so operators can see what is in flight
+// before a restart (GET /api/v1/admin/operations) and ask for it to stop
+// (DELETE /api/v1/admin/operations/:id).
+//
+// Cancellation is cooperative: Start hands the owner a context that Cancel
+// cancels, and the owner stops at its next context check. The registry is
+// per-process; in a multi-replica deployment each replica reports only its own
+// operations.
+//
+// A nil *Registry and a nil *Operation are valid and do nothing, so optional
+// wiring does not need nil checks at every call site.
+package operations
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operation types reported by the registry.
+const (
+	TypeModuleUpload     = "module_upload"
+	TypeProviderUpload   = "provider_upload"
+	TypeMirrorSync       = "mirror_sync"
+	TypeStorageMigration = "storage_migration"
+	TypeAuditExport      = "audit_export"
+	TypeMetadataExport   = "metadata_export"
+	TypeUserDataExport   = "user_data_export"
+	TypeSecretReencrypt  = "secret_reencrypt"
+)
+
+// ActorSystem is the actor recorded for work the registry starts on its own
+// (scheduled syncs).
+const ActorSystem = "system"
+
+// Info is a point-in-time view of an operation.
+type Info struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Actor     string    `json:"actor"`    // user ID, or "system" for scheduled work
+	Resource  string    `json:"resource"` // what the operation acts on, e.g. a request path or mirror name
+	StartedAt time.Time `json:"started_at"`
+	// Done and Total report progress in operation-specific units (bytes for
+	// uploads, items for syncs and migrations). Total is 0 when unknown.
+	Done            int64 `json:"done"`
+	Total           int64 `json:"total,omitempty"`
+	CancelRequested bool  `json:"cancel_requested"`
+}
+
+// Operation is a tracked unit of work. Its owner reports progress and must call
+// Finish when the work ends.
+type Operation struct {
+	id        string
+	typ       string
+	actor     string
+	resource  string
+	startedAt time.Time
+
+	done      atomic.Int64
+	total     atomic.Int64
+	cancelled atomic.Bool
+	cancel    context.CancelFunc
+	registry  *Registry
+}
+
+// Registry holds the operations currently running in this process.
+type Registry struct {
+	mu  sync.Mutex
+	ops map[string]*Operation
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{ops: make(map[string]*Operation)}
+}
+
+type contextKey struct{}
+
+type actorKey struct{}
+
+// WithActor records who asked for work that is started later on another
+// goroutine (e.g. a manually triggered mirror sync), for ActorFromContext.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor recorded by WithActor, or ActorSystem.
+func ActorFromContext(ctx context.Context) string {
+	if actor, _ := ctx.Value(actorKey{}).(string); actor != "" {
+		return actor
+	}
+	return ActorSystem
+}
+
+// Start registers an operation and returns a context derived from ctx that is
+// cancelled when the operation is cancelled or finished. The returned context
+// also carries the operation (see FromContext). On a nil registry Start
+// returns ctx unchanged and a nil operation.
+func (r *Registry) Start(ctx context.Context, typ, actor, resource string) (context.Context, *Operation) {
+	if r == nil {
+		return ctx, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	op := &Operation{
+		id:        uuid.NewString(),
+		typ:       typ,
+		actor:     actor,
+		resource:  resource,
+		startedAt: time.Now(),
+		cancel:    cancel,
+		registry:  r,
+	}
+	r.mu.Lock()
+	r.ops[op.id] = op
+	r.mu.Unlock()
+	return context.WithValue(ctx, contextKey{}, op), op
+}
+
+// List returns the running operations, oldest first.
+func (r *Registry) List() []Info {
+	if r == nil {
+		return []Info{}
+	}
+	r.mu.Lock()
+	out := make([]Info, 0, len(r.ops))
+	for _, op := range r.ops {
+		out = append(out, op.Info())
+	}
+	r.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].StartedAt.Equal(out[j].StartedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].StartedAt.Before(out[j].StartedAt)
+	})
+	return out
+}
+
+// Get returns one running operation, or false when there is none with id.
+func (r *Registry) Get(id string) (Info, bool) {
+	if r == nil {
+		return Info{}, false
+	}
+	r.mu.Lock()
+	op, ok := r.ops[id]
+	r.mu.Unlock()
+	if !ok {
+		return Info{}, false
+	}
+	return op.Info(), true
+}
+
+// Cancel requests cancellation of a running operation by cancelling its
+// context. The operation stays listed (with CancelRequested set) until its
+// owner notices and calls Finish. It reports whether the operation exists.
+func (r *Registry) Cancel(id string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	op, ok := r.ops[id]
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+	op.cancelled.Store(true)
+	op.cancel()
+	return true
+}
+
+// FromContext returns the operation carried by ctx, or nil.
+func FromContext(ctx context.Context) *Operation {
+	op, _ := ctx.Value(contextKey{}).(*Operation)
+	return op
+}
+
+// ID returns the operation's ID ("" for a nil operation).
+func (o *Operation) ID() string {
+	if o == nil {
+		return ""
+	}
+	return o.id
+}
+
+// SetTotal records the total amount of work, when known.
+func (o *Operation) SetTotal(total int64) {
+	if o != nil {
+		o.total.Store(total)
+	}
+}
+
+// SetDone records how much work has completed.
+func (o *Operation) SetDone(done int64) {
+	if o != nil {
+		o.done.Store(done)
+	}
+}
+
+// AddDone adds n to the completed work.
+func (o *Operation) AddDone(n int64) {
+	if o != nil {
+		o.done.Add(n)
+	}
+}
+
+// Finish removes the operation from the registry and releases its context.
+// It is safe to call more than once.
+func (o *Operation) Finish() {
+	if o == nil {
+		return
+	}
+	o.registry.mu.Lock()
+	delete(o.registry.ops, o.id)
+	o.registry.mu.Unlock()
+	o.cancel()
+}
+
+// Info returns a snapshot of the operation.
+func (o *Operation) Info() Info {
+	return Info{
+		ID:              o.id,
+		Type:            o.typ,
+		Actor:           o.actor,
+		Resource:        o.resource,
+		StartedAt:       o.startedAt,
+		Done:            o.done.Load(),
+		Total:           o.total.Load(),
+		CancelRequested: o.cancelled.Load(),
+	}
+}
@@ -0,0 +1,103 @@
+package operations
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRegistry_StartListFinish(t *testing.T) {
+	r := NewRegistry()
+	ctx, op := r.Start(context.Background(), TypeModuleUpload, "user-1", "/api/v1/modules")
+	if FromContext(ctx) != op {
+		t.Fatal("context does not carry the operation")
+	}
+	_, second := r.Start(context.Background(), TypeMirrorSync, ActorSystem, "mirror/hashicorp")
+
+	op.SetTotal(100)
+	op.AddDone(40)
+	op.AddDone(2)
+
+	list := r.List()
+	if len(list) != 2 {
+		t.Fatalf("List() = %d operations, want 2", len(list))
+	}
+	got := list[0]
+	if got.ID != op.ID() || got.Type != TypeModuleUpload || got.Actor != "user-1" || got.Resource != "/api/v1/modules" {
+		t.Errorf("List()[0] = %+v, want the first operation (oldest first)", got)
+	}
+	if got.Done != 42 || got.Total != 100 || got.CancelRequested {
+		t.Errorf("progress = %d/%d cancel=%v, want 42/100 false", got.Done, got.Total, got.CancelRequested)
+	}
+
+	op.Finish()
+	op.Finish() // idempotent
+	if ctx.Err() == nil {
+		t.Error("Finish did not release the operation's context")
+	}
+	if _, ok := r.Get(op.ID()); ok {
+		t.Error("finished operation is still listed")
+	}
+	if list := r.List(); len(list) != 1 || list[0].ID != second.ID() {
+		t.Errorf("List() after Finish = %+v", list)
+	}
+}
+
+func TestRegistry_Cancel(t *testing.T) {
+	r := NewRegistry()
+	ctx, op := r.Start(context.Background(), TypeStorageMigration, "user-1", "storage_migration/m1")
+	defer op.Finish()
+
+	if r.Cancel("no-such-id") {
+		t.Error("Cancel of an unknown ID reported true")
+	}
+	if !r.Cancel(op.ID()) {
+		t.Fatal("Cancel reported false for a running operation")
+	}
+	if ctx.Err() == nil {
+		t.Error("Cancel did not cancel the operation's context")
+	}
+	info, ok := r.Get(op.ID())
+	if !ok {
+		t.Fatal("cancelled operation disappeared before its owner finished it")
+	}
+	if !info.CancelRequested {
+		t.Error("CancelRequested = false after Cancel")
+	}
+}
+
+func TestRegistry_Nil(t *testing.T) {
+	var r *Registry
+	parent := context.Background()
+	ctx, op := r.Start(parent, TypeAuditExport, "user-1", "/export")
+	if ctx != parent || op != nil {
+		t.Fatalf("nil registry Start = (%v, %v), want (parent, nil)", ctx, op)
+	}
+	op.SetTotal(1)
+	op.SetDone(1)
+	op.AddDone(1)
+	op.Finish()
+	if op.ID() != "" {
+		t.Error("nil operation has an ID")
+	}
+	if list := r.List(); list == nil || len(list) != 0 {
+		t.Errorf("nil registry List() = %#v, want empty non-nil slice", list)
+	}
+	if r.Cancel("x") {
+		t.Error("nil registry Cancel reported true")
+	}
+	if FromContext(parent) != nil {
+		t.Error("FromContext found an operation in a plain context")
+	}
+}
+
+func TestActorFromContext(t *testing.T) {
+	if got := ActorFromContext(context.Background()); got != ActorSystem {
+		t.Errorf("default actor = %q, want %q", got, ActorSystem)
+	}
+	if got := ActorFromContext(WithActor(context.Background(), "")); got != ActorSystem {
+		t.Errorf("empty actor = %q, want %q", got, ActorSystem)
+	}
+	if got := ActorFromContext(WithActor(context.Background(), "user-1")); got != "user-1" {
+		t.Errorf("actor = %q, want user-1", got)
+	}
+}
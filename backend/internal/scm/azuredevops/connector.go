@@ -144,7 +144,7 @@ func (c *AzureDevOpsConnector) CompleteAuthorization(ctx context.Context, authCo
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	// #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to exchange code", err)
 	}
@@ -196,7 +196,7 @@ func (c *AzureDevOpsConnector) RenewToken(ctx context.Context, refreshToken stri
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	// #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to refresh token", err)
 	}
@@ -246,7 +246,7 @@ func (c *AzureDevOpsConnector) FetchRepositories(ctx context.Context, creds *scm
 		}
 		c.setAuthHeaders(req, creds)
 		// #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-		resp, err := scm.HTTPClient.Do(req)
+		resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 		if err != nil {
 			continue
 		}
@@ -281,7 +281,7 @@ func (c *AzureDevOpsConnector) FetchRepository(ctx context.Context, creds *scm.A
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch repository", err)
 	}
@@ -333,7 +333,7 @@ func (c *AzureDevOpsConnector) FetchBranches(ctx context.Context, creds *scm.Acc
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch branches", err)
 	}
@@ -378,7 +378,7 @@ func (c *AzureDevOpsConnector) FetchTags(ctx context.Context, creds *scm.AccessT
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch tags", err)
 	}
@@ -449,7 +449,7 @@ func (c *AzureDevOpsConnector) FetchCommit(ctx context.Context, creds *scm.Acces
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch commit", err)
 	}
@@ -513,7 +513,7 @@ func (c *AzureDevOpsConnector) DownloadSourceArchive(ctx context.Context, creds
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to download archive", err)
 	}
@@ -611,7 +611,7 @@ func (c *AzureDevOpsConnector) fetchRepoAndProjectIDs(ctx context.Context, creds
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G107 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return "", "", scm.WrapRemoteError(0, "failed to fetch repository IDs", err)
 	}
@@ -669,7 +669,7 @@ func (c *AzureDevOpsConnector) RegisterWebhook(ctx context.Context, creds *scm.A
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G107 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to create service hook subscription", err)
 	}
@@ -700,7 +700,7 @@ func (c *AzureDevOpsConnector) RemoveWebhook(ctx context.Context, creds *scm.Acc
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G107 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return scm.WrapRemoteError(0, "failed to delete service hook subscription", err)
 	}
@@ -819,7 +819,7 @@ func (c *AzureDevOpsConnector) fetchProjects(ctx context.Context, creds *scm.Acc
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderAzureDevOps, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch projects", err)
 	}
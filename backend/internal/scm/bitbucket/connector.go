@@ -267,20 +267,13 @@ func (c *BitbucketDCConnector) FetchCommit(ctx context.Context, creds *scm.Acces
 
 // DownloadSourceArchive downloads repository contents at a specific ref
 func (c *BitbucketDCConnector) DownloadSourceArchive(ctx context.Context, creds *scm.AccessToken, ownerName, repoName, gitRef string, format scm.ArchiveKind) (io.ReadCloser, error) {
-	archiveFormat := "tgz"
-	if format == scm.ArchiveZipball {
-		archiveFormat = "zip"
-	}
-
-	endpoint := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/archive?at=%s&format=%s", c.baseURL, ownerName, repoName, gitRef, archiveFormat)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.archiveEndpoint(ownerName, repoName, gitRef, format), nil)
 	if err != nil {
 		return nil, fmt.Errorf("bitbucket: create archive request: %w", err)
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderBitbucketDC, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to download archive", err)
 	}
@@ -293,6 +286,25 @@ func (c *BitbucketDCConnector) DownloadSourceArchive(ctx context.Context, creds
 	return resp.Body, nil
 }
 
+// SourceArchiveSize reports the size of the archive DownloadSourceArchive would
+// fetch, or -1 when Bitbucket streams it without a Content-Length.
+func (c *BitbucketDCConnector) SourceArchiveSize(ctx context.Context, creds *scm.AccessToken, ownerName, repoName, gitRef string, format scm.ArchiveKind) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.archiveEndpoint(ownerName, repoName, gitRef, format), nil)
+	if err != nil {
+		return -1, fmt.Errorf("bitbucket: create archive size request: %w", err)
+	}
+	c.setAuthHeaders(req, creds)
+	return scm.HeadContentLength(scm.ProviderBitbucketDC, req)
+}
+
+func (c *BitbucketDCConnector) archiveEndpoint(ownerName, repoName, gitRef string, format scm.ArchiveKind) string {
+	archiveFormat := "tgz"
+	if format == scm.ArchiveZipball {
+		archiveFormat = "zip"
+	}
+	return fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/archive?at=%s&format=%s", c.baseURL, ownerName, repoName, gitRef, archiveFormat)
+}
+
 // RegisterWebhook creates a webhook on the repository
 func (c *BitbucketDCConnector) RegisterWebhook(ctx context.Context, creds *scm.AccessToken, ownerName, repoName string, hookConfig scm.WebhookSetup) (*scm.WebhookInfo, error) {
 	endpoint := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/webhooks", c.baseURL, ownerName, repoName)
@@ -329,7 +341,7 @@ func (c *BitbucketDCConnector) RegisterWebhook(ctx context.Context, creds *scm.A
 	c.setAuthHeaders(req, creds)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderBitbucketDC, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to create webhook", err)
 	}
@@ -361,7 +373,7 @@ func (c *BitbucketDCConnector) RemoveWebhook(ctx context.Context, creds *scm.Acc
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderBitbucketDC, req)
 	if err != nil {
 		return scm.WrapRemoteError(0, "failed to delete webhook", err)
 	}
@@ -466,7 +478,7 @@ func (c *BitbucketDCConnector) doJSON(ctx context.Context, creds *scm.AccessToke
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderBitbucketDC, req)
 	if err != nil {
 		return scm.WrapRemoteError(0, "request failed", err)
 	}
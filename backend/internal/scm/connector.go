@@ -68,6 +68,14 @@ type Connector interface {
 	VerifyDeliverySignature(payloadBytes []byte, signatureHeader, sharedSecret string) bool
 }
 
+// ArchiveSizer is implemented by connectors that can report the size of a
+// source archive without downloading it, so an oversized repository is
+// rejected before its archive is fetched. Platforms that stream archives send
+// no size; SourceArchiveSize then returns -1.
+type ArchiveSizer interface {
+	SourceArchiveSize(ctx context.Context, creds *AccessToken, ownerName, repoName, gitRef string, format ArchiveKind) (int64, error)
+}
+
 // Pagination holds page navigation parameters
 type Pagination struct {
 	PageNum  int
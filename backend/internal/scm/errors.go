@@ -83,6 +83,17 @@ var (
 	ErrAPIRateLimited = ErrRateLimitExceeded
 )
 
+// IsAuthError reports whether err means the provider rejected the credentials
+// used for the call (expired or revoked token), as opposed to a missing
+// repository or a transient failure.
+func IsAuthError(err error) bool {
+	if errors.Is(err, ErrOAuthTokenInvalid) || errors.Is(err, ErrOAuthTokenExpired) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == 401
+}
+
 // APIError represents an error from the SCM provider API
 type APIError struct {
 	StatusCode int
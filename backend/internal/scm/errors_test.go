@@ -152,3 +152,23 @@ func TestErrorAliasesMatchOriginals(t *testing.T) {
 		}
 	}
 }
+
+func TestIsAuthError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"invalid token", ErrOAuthTokenInvalid, true},
+		{"expired token", fmt.Errorf("wrapped: %w", ErrTokenExpired), true},
+		{"401 api error", WrapRemoteError(401, "failed to fetch repository", nil), true},
+		{"403 api error", WrapRemoteError(403, "failed to fetch repository", nil), false},
+		{"repo not found", ErrRepoNotFound, false},
+		{"nil", nil, false},
+	}
+	for _, tc := range cases {
+		if got := IsAuthError(tc.err); got != tc.want {
+			t.Errorf("%s: IsAuthError = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
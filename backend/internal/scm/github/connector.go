@@ -89,7 +89,7 @@ func (c *GitHubConnector) CompleteAuthorization(ctx context.Context, authCode st
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to exchange code", err)
 	}
@@ -161,7 +161,7 @@ func (c *GitHubConnector) FetchRepository(ctx context.Context, creds *scm.Access
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch repository", err)
 	}
@@ -202,7 +202,7 @@ func (c *GitHubConnector) SearchRepositories(ctx context.Context, creds *scm.Acc
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "search failed", err)
 	}
@@ -253,7 +253,7 @@ func (c *GitHubConnector) FetchBranches(ctx context.Context, creds *scm.AccessTo
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch branches", err)
 	}
@@ -306,7 +306,7 @@ func (c *GitHubConnector) FetchTags(ctx context.Context, creds *scm.AccessToken,
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch tags", err)
 	}
@@ -349,7 +349,7 @@ func (c *GitHubConnector) FetchTagByName(ctx context.Context, creds *scm.AccessT
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch tag", err)
 	}
@@ -391,7 +391,7 @@ func (c *GitHubConnector) FetchCommit(ctx context.Context, creds *scm.AccessToke
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch commit", err)
 	}
@@ -433,20 +433,13 @@ func (c *GitHubConnector) FetchCommit(ctx context.Context, creds *scm.AccessToke
 
 // DownloadSourceArchive downloads repository contents at a specific ref
 func (c *GitHubConnector) DownloadSourceArchive(ctx context.Context, creds *scm.AccessToken, ownerName, repoName, gitRef string, format scm.ArchiveKind) (io.ReadCloser, error) {
-	archiveType := "tarball"
-	if format == scm.ArchiveZipball {
-		archiveType = "zipball"
-	}
-
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/%s/%s", c.apiURL, ownerName, repoName, archiveType, gitRef)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.archiveEndpoint(ownerName, repoName, gitRef, format), nil)
 	if err != nil {
 		return nil, fmt.Errorf("github: create archive request: %w", err)
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to download archive", err)
 	}
@@ -459,6 +452,25 @@ func (c *GitHubConnector) DownloadSourceArchive(ctx context.Context, creds *scm.
 	return resp.Body, nil
 }
 
+// SourceArchiveSize reports the size of the archive DownloadSourceArchive would
+// fetch. codeload streams most archives, so this is often -1.
+func (c *GitHubConnector) SourceArchiveSize(ctx context.Context, creds *scm.AccessToken, ownerName, repoName, gitRef string, format scm.ArchiveKind) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.archiveEndpoint(ownerName, repoName, gitRef, format), nil)
+	if err != nil {
+		return -1, fmt.Errorf("github: create archive size request: %w", err)
+	}
+	c.setAuthHeaders(req, creds)
+	return scm.HeadContentLength(scm.ProviderGitHub, req)
+}
+
+func (c *GitHubConnector) archiveEndpoint(ownerName, repoName, gitRef string, format scm.ArchiveKind) string {
+	archiveType := "tarball"
+	if format == scm.ArchiveZipball {
+		archiveType = "zipball"
+	}
+	return fmt.Sprintf("%s/repos/%s/%s/%s/%s", c.apiURL, ownerName, repoName, archiveType, gitRef)
+}
+
 // RegisterWebhook creates a GitHub repository webhook for push events.
 func (c *GitHubConnector) RegisterWebhook(ctx context.Context, creds *scm.AccessToken, ownerName, repoName string, hookConfig scm.WebhookSetup) (*scm.WebhookInfo, error) {
 	endpoint := fmt.Sprintf("%s/repos/%s/%s/hooks", c.apiURL, ownerName, repoName)
@@ -484,7 +496,7 @@ func (c *GitHubConnector) RegisterWebhook(ctx context.Context, creds *scm.Access
 	c.setAuthHeaders(req, creds)
 	req.Header.Set("Content-Type", "application/json")
 	// #nosec G107 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to create webhook", err)
 	}
@@ -515,7 +527,7 @@ func (c *GitHubConnector) RemoveWebhook(ctx context.Context, creds *scm.AccessTo
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G107 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return scm.WrapRemoteError(0, "failed to delete webhook", err)
 	}
@@ -624,7 +636,7 @@ func (c *GitHubConnector) fetchRepoList(ctx context.Context, creds *scm.AccessTo
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitHub, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch repositories", err)
 	}
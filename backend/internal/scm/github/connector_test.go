@@ -394,6 +394,31 @@ func TestDownloadSourceArchive_Error(t *testing.T) {
 	}
 }
 
+func TestSourceArchiveSize_ReadsContentLength(t *testing.T) {
+	_, c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || !strings.HasSuffix(r.URL.Path, "/repos/org/repo/tarball/v1.0") {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Length", "4096")
+	})
+
+	size, err := c.SourceArchiveSize(context.Background(), creds(), "org", "repo", "v1.0", scm.ArchiveTarball)
+	if err != nil || size != 4096 {
+		t.Errorf("SourceArchiveSize = %d, %v; want 4096, nil", size, err)
+	}
+}
+
+func TestSourceArchiveSize_HeadNotAllowed(t *testing.T) {
+	_, c := newTestConnector(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	size, err := c.SourceArchiveSize(context.Background(), creds(), "org", "repo", "v1.0", scm.ArchiveTarball)
+	if err != nil || size != -1 {
+		t.Errorf("SourceArchiveSize = %d, %v; want -1, nil", size, err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // SearchRepositories
 // ---------------------------------------------------------------------------
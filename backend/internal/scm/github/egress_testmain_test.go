@@ -3,6 +3,7 @@ package github
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/terraform-registry/terraform-registry/internal/scm"
 )
@@ -12,9 +13,14 @@ import (
 // test in this package points a connector at an httptest.Server, which binds
 // to 127.0.0.1. Production callers get the strict default (see
 // internal/scm/httpclient.go); only this test binary's egress policy changes.
+// Retry waits are capped at a few milliseconds so tests of 5xx responses do
+// not sit through the production backoff.
 func TestMain(m *testing.M) {
 	if err := scm.ConfigureEgress([]string{"127.0.0.1", "::1"}); err != nil {
 		panic(err)
 	}
+	opts := scm.DefaultClientOptions()
+	opts.MaxRetryWait = 5 * time.Millisecond
+	scm.ConfigureClient(opts)
 	os.Exit(m.Run())
 }
@@ -87,7 +87,7 @@ func (c *GitLabConnector) CompleteAuthorization(ctx context.Context, authCode st
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to exchange code", err)
 	}
@@ -139,7 +139,7 @@ func (c *GitLabConnector) RenewToken(ctx context.Context, refreshToken string) (
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to refresh token", err)
 	}
@@ -189,7 +189,7 @@ func (c *GitLabConnector) FetchRepositories(ctx context.Context, creds *scm.Acce
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch projects", err)
 	}
@@ -228,7 +228,7 @@ func (c *GitLabConnector) FetchRepository(ctx context.Context, creds *scm.Access
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch project", err)
 	}
@@ -269,7 +269,7 @@ func (c *GitLabConnector) SearchRepositories(ctx context.Context, creds *scm.Acc
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "search failed", err)
 	}
@@ -316,7 +316,7 @@ func (c *GitLabConnector) FetchBranches(ctx context.Context, creds *scm.AccessTo
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch branches", err)
 	}
@@ -372,7 +372,7 @@ func (c *GitLabConnector) FetchTags(ctx context.Context, creds *scm.AccessToken,
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch tags", err)
 	}
@@ -422,7 +422,7 @@ func (c *GitLabConnector) FetchTagByName(ctx context.Context, creds *scm.AccessT
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch tag", err)
 	}
@@ -467,7 +467,7 @@ func (c *GitLabConnector) FetchCommit(ctx context.Context, creds *scm.AccessToke
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to fetch commit", err)
 	}
@@ -506,23 +506,13 @@ func (c *GitLabConnector) FetchCommit(ctx context.Context, creds *scm.AccessToke
 
 // DownloadSourceArchive downloads project contents at a specific ref
 func (c *GitLabConnector) DownloadSourceArchive(ctx context.Context, creds *scm.AccessToken, ownerName, repoName, gitRef string, format scm.ArchiveKind) (io.ReadCloser, error) {
-	projectPath := url.PathEscape(fmt.Sprintf("%s/%s", ownerName, repoName))
-
-	// GitLab uses different format names
-	archiveFormat := "tar.gz"
-	if format == scm.ArchiveZipball {
-		archiveFormat = "zip"
-	}
-
-	endpoint := fmt.Sprintf("%s/projects/%s/repository/archive.%s?sha=%s", c.apiURL, projectPath, archiveFormat, gitRef)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.archiveEndpoint(ownerName, repoName, gitRef, format), nil)
 	if err != nil {
 		return nil, fmt.Errorf("gitlab: create archive request: %w", err)
 	}
 	c.setAuthHeaders(req, creds)
 
-	resp, err := scm.HTTPClient.Do(req) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to download archive", err)
 	}
@@ -535,6 +525,28 @@ func (c *GitLabConnector) DownloadSourceArchive(ctx context.Context, creds *scm.
 	return resp.Body, nil
 }
 
+// SourceArchiveSize reports the size of the archive DownloadSourceArchive would
+// fetch, or -1 when GitLab streams it without a Content-Length.
+func (c *GitLabConnector) SourceArchiveSize(ctx context.Context, creds *scm.AccessToken, ownerName, repoName, gitRef string, format scm.ArchiveKind) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.archiveEndpoint(ownerName, repoName, gitRef, format), nil)
+	if err != nil {
+		return -1, fmt.Errorf("gitlab: create archive size request: %w", err)
+	}
+	c.setAuthHeaders(req, creds)
+	return scm.HeadContentLength(scm.ProviderGitLab, req)
+}
+
+func (c *GitLabConnector) archiveEndpoint(ownerName, repoName, gitRef string, format scm.ArchiveKind) string {
+	projectPath := url.PathEscape(fmt.Sprintf("%s/%s", ownerName, repoName))
+
+	// GitLab uses different format names
+	archiveFormat := "tar.gz"
+	if format == scm.ArchiveZipball {
+		archiveFormat = "zip"
+	}
+	return fmt.Sprintf("%s/projects/%s/repository/archive.%s?sha=%s", c.apiURL, projectPath, archiveFormat, gitRef)
+}
+
 // RegisterWebhook creates a GitLab project webhook for push and tag-push events.
 // ownerName is the namespace (user or group) and repoName is the project name.
 func (c *GitLabConnector) RegisterWebhook(ctx context.Context, creds *scm.AccessToken, ownerName, repoName string, hookConfig scm.WebhookSetup) (*scm.WebhookInfo, error) {
@@ -557,7 +569,7 @@ func (c *GitLabConnector) RegisterWebhook(ctx context.Context, creds *scm.Access
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G107 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return nil, scm.WrapRemoteError(0, "failed to create webhook", err)
 	}
@@ -589,7 +601,7 @@ func (c *GitLabConnector) RemoveWebhook(ctx context.Context, creds *scm.AccessTo
 	}
 	c.setAuthHeaders(req, creds)
 	// #nosec G107 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
-	resp, err := scm.HTTPClient.Do(req)
+	resp, err := scm.Do(scm.ProviderGitLab, req)
 	if err != nil {
 		return scm.WrapRemoteError(0, "failed to delete webhook", err)
 	}
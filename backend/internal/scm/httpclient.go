@@ -1,18 +1,29 @@
 // httpclient.go provides the shared HTTP client and response-body size caps used by all
 // SCM connectors (GitHub, GitLab, Bitbucket Data Center, Azure DevOps) for API calls and
-// OAuth token exchanges.
+// OAuth token exchanges, with bounded retries, rate-limit awareness, per-provider
+// concurrency limits and metrics (Do).
 package scm
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
 )
 
-// httpClientTimeout is the shared request timeout for all SCM connector calls.
+// httpClientTimeout is the default per-attempt timeout for SCM connector calls.
 const httpClientTimeout = 30 * time.Second
 
+// retryBaseDelay is the first backoff step between retries that carry no
+// Retry-After; each further retry doubles it. A var so tests can shorten it.
+var retryBaseDelay = 500 * time.Millisecond
+
 // HTTPClient is the shared HTTP client every SCM connector should use instead of
 // http.DefaultClient, which has a zero Timeout. Self-hosted/enterprise SCM instance
 // base URLs are operator-configurable, so every request is routed through the
@@ -21,8 +32,47 @@ const httpClientTimeout = 30 * time.Second
 // The strict default policy applies until ConfigureEgress installs the
 // operator's allow-list at startup; tests that talk to local httptest servers
 // replace this client with one built from an explicit loopback allow-list.
+//
+// Connectors send requests through Do rather than calling HTTPClient
+// directly, so retries, rate-limit handling, concurrency limits and metrics
+// apply to every call.
 var HTTPClient = httpsafe.NewClient(httpClientTimeout, nil)
 
+// ClientOptions tunes the shared connector client (config: scm.*).
+type ClientOptions struct {
+	// Timeout bounds one attempt, including reading the response body.
+	Timeout time.Duration
+	// MaxRetries is how many times a request is retried after a 429, a
+	// rate-limited 403, or (for idempotent methods) a 5xx or transport error.
+	MaxRetries int
+	// MaxRetryWait caps any single wait. When the SCM asks for a longer one
+	// (Retry-After, or an exhausted rate limit's reset time) the request is
+	// not retried: its response is returned, or ErrRateLimitExceeded while the
+	// limit is known to be exhausted.
+	MaxRetryWait time.Duration
+	// MaxConcurrent limits in-flight requests per provider type; 0 is
+	// unlimited.
+	MaxConcurrent int
+}
+
+// DefaultClientOptions returns the options used until ConfigureClient is
+// called.
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		Timeout:       httpClientTimeout,
+		MaxRetries:    3,
+		MaxRetryWait:  time.Minute,
+		MaxConcurrent: 8,
+	}
+}
+
+var (
+	clientMu    sync.Mutex
+	egressGuard *httpsafe.Guard
+	clientOpts  = DefaultClientOptions()
+	limiters    = make(map[ProviderType]*providerLimiter)
+)
+
 // ConfigureEgress rebuilds the shared connector client with the
 // operator-configured egress allow-list (security.egress.allowlist). Call once
 // at startup before any connector traffic; entries may be hostnames, IPs, or
@@ -32,10 +82,273 @@ func ConfigureEgress(allowlist []string) error {
 	if err != nil {
 		return err
 	}
-	HTTPClient = httpsafe.NewClient(httpClientTimeout, g)
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	egressGuard = g
+	HTTPClient = httpsafe.NewClient(clientOpts.Timeout, g)
 	return nil
 }
 
+// ConfigureClient rebuilds the shared connector client with opts. Call once
+// at startup before any connector traffic. A zero Timeout keeps the default.
+func ConfigureClient(opts ClientOptions) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = httpClientTimeout
+	}
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	clientOpts = opts
+	limiters = make(map[ProviderType]*providerLimiter)
+	HTTPClient = httpsafe.NewClient(opts.Timeout, egressGuard)
+}
+
+// providerLimiter is the per-provider-type concurrency and rate-limit state.
+type providerLimiter struct {
+	sem chan struct{} // nil: unlimited
+
+	mu          sync.Mutex
+	pausedUntil time.Time // the provider's rate limit is exhausted until then
+}
+
+func (l *providerLimiter) acquire(ctx context.Context) error {
+	if l.sem == nil {
+		return nil
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *providerLimiter) release() {
+	if l.sem != nil {
+		<-l.sem
+	}
+}
+
+// waitForReset blocks while the provider's rate limit is exhausted, failing
+// with ErrRateLimitExceeded when that would take longer than maxWait.
+func (l *providerLimiter) waitForReset(ctx context.Context, maxWait time.Duration) error {
+	l.mu.Lock()
+	wait := time.Until(l.pausedUntil)
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if wait > maxWait {
+		return fmt.Errorf("%w: resets at %s", ErrRateLimitExceeded, time.Now().Add(wait).UTC().Format(time.RFC3339))
+	}
+	return sleepContext(ctx, wait)
+}
+
+// pauseUntil records that the provider's rate limit is exhausted until t.
+func (l *providerLimiter) pauseUntil(t time.Time) {
+	l.mu.Lock()
+	if t.After(l.pausedUntil) {
+		l.pausedUntil = t
+	}
+	l.mu.Unlock()
+}
+
+// clientFor returns the shared client, its options and provider's limiter.
+func clientFor(provider ProviderType) (*http.Client, ClientOptions, *providerLimiter) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	l, ok := limiters[provider]
+	if !ok {
+		l = &providerLimiter{}
+		if clientOpts.MaxConcurrent > 0 {
+			l.sem = make(chan struct{}, clientOpts.MaxConcurrent)
+		}
+		limiters[provider] = l
+	}
+	return HTTPClient, clientOpts, l
+}
+
+// Do sends req, made by a connector of the given provider type, through
+// HTTPClient. It waits out an exhausted rate limit, holds one of the
+// provider's concurrency slots until the response body is closed, and retries
+// 429s, rate-limited 403s and, for idempotent methods, 5xx responses and
+// transport errors, honouring Retry-After. A request whose body cannot be
+// replayed (no GetBody) is not retried. Every attempt is counted in the
+// terraform_registry_scm_* metrics.
+func Do(provider ProviderType, req *http.Request) (*http.Response, error) {
+	client, opts, lim := clientFor(provider)
+	ctx := req.Context()
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		if err := lim.waitForReset(ctx, opts.MaxRetryWait); err != nil {
+			return nil, err
+		}
+		if err := lim.acquire(ctx); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := client.Do(r) // #nosec G704 -- request is routed through the SSRF-safe egress client (internal/httpsafe): scheme allow-list, resolve-and-pin private-range deny-list, per-hop redirect re-validation
+		telemetry.SCMRequestDuration.WithLabelValues(string(provider)).Observe(time.Since(start).Seconds())
+		canRetry := attempt < opts.MaxRetries && replayable && ctx.Err() == nil
+
+		if err != nil {
+			lim.release()
+			telemetry.SCMRequestsTotal.WithLabelValues(string(provider), "error").Inc()
+			if !canRetry || !idempotentMethod(req.Method) {
+				return nil, err
+			}
+			if err := sleepContext(ctx, backoff(attempt, opts.MaxRetryWait)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		telemetry.SCMRequestsTotal.WithLabelValues(string(provider), strconv.Itoa(resp.StatusCode)).Inc()
+
+		limited := recordRateLimit(provider, lim, resp)
+		wait, retry := retryWait(req.Method, resp, limited, attempt, opts.MaxRetryWait)
+		if !retry || !canRetry {
+			resp.Body = &releasingBody{ReadCloser: resp.Body, release: lim.release}
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, LimitErrorBody(resp.Body))
+		resp.Body.Close()
+		lim.release()
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// recordRateLimit reads the rate-limit headers of resp (X-RateLimit-* on
+// GitHub, Azure DevOps and Bitbucket, RateLimit-* on GitLab) into the
+// remaining-requests gauge, and pauses the provider until the reset time when
+// the limit is exhausted. It reports whether it is.
+func recordRateLimit(provider ProviderType, lim *providerLimiter, resp *http.Response) bool {
+	remaining, ok := headerInt(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	if !ok {
+		return false
+	}
+	telemetry.SCMRateLimitRemaining.WithLabelValues(string(provider)).Set(float64(remaining))
+	if remaining > 0 {
+		return false
+	}
+	if reset, ok := headerInt(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset"); ok {
+		lim.pauseUntil(time.Unix(reset, 0))
+	}
+	return true
+}
+
+// retryWait decides whether resp is worth retrying and after how long. 429s
+// and 403s from an exhausted rate limit (GitHub's primary limit) or carrying
+// Retry-After (its secondary limit) are always retried; 5xx responses only
+// for idempotent methods. A wait longer than maxWait is not retried.
+func retryWait(method string, resp *http.Response, limited bool, attempt int, maxWait time.Duration) (time.Duration, bool) {
+	retryAfter, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && (limited || hasRetryAfter):
+		if !hasRetryAfter && limited {
+			if reset, ok := headerInt(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset"); ok {
+				retryAfter, hasRetryAfter = time.Until(time.Unix(reset, 0)), true
+			}
+		}
+	case resp.StatusCode >= 500 && idempotentMethod(method):
+	default:
+		return 0, false
+	}
+	if !hasRetryAfter {
+		retryAfter = backoff(attempt, maxWait)
+	}
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return retryAfter, retryAfter <= maxWait
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP
+// date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+// headerInt returns the first of names present in h as an integer.
+func headerInt(h http.Header, names ...string) (int64, bool) {
+	for _, name := range names {
+		if v := h.Get(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+// backoff is the exponential wait before retry attempt+1, capped at maxWait.
+func backoff(attempt int, maxWait time.Duration) time.Duration {
+	d := retryBaseDelay << attempt
+	if d > maxWait || d <= 0 {
+		return maxWait
+	}
+	return d
+}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releasingBody frees the provider's concurrency slot when the response body
+// is closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
 const (
 	// MaxResponseBodyBytes bounds successful SCM API response bodies (repository
 	// listings, commit/tag/branch metadata, OAuth token responses). These are small
@@ -49,6 +362,26 @@ const (
 	MaxErrorBodyBytes = 4096
 )
 
+// HeadContentLength sends req as a HEAD request through Do and returns the
+// Content-Length of the response, or -1 when there is none or the endpoint
+// does not answer HEAD (405/501).
+func HeadContentLength(provider ProviderType, req *http.Request) (int64, error) {
+	req.Method = http.MethodHead
+	resp, err := Do(provider, req)
+	if err != nil {
+		return -1, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented:
+		return -1, nil
+	case resp.StatusCode != http.StatusOK:
+		return -1, WrapRemoteError(resp.StatusCode, "failed to read archive size", nil)
+	}
+	return resp.ContentLength, nil
+}
+
 // LimitBody wraps r in an io.LimitReader capped at MaxResponseBodyBytes, for use before
 // io.ReadAll or json.NewDecoder(...).Decode on a successful SCM API response body.
 func LimitBody(r io.Reader) io.Reader {
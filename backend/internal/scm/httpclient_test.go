@@ -0,0 +1,207 @@
+package scm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useTestClient points the shared client at loopback test servers with opts,
+// restoring the defaults when the test ends.
+func useTestClient(t *testing.T, opts ClientOptions) {
+	t.Helper()
+	if err := ConfigureEgress([]string{"127.0.0.1", "::1"}); err != nil {
+		t.Fatalf("ConfigureEgress: %v", err)
+	}
+	ConfigureClient(opts)
+	t.Cleanup(func() {
+		_ = ConfigureEgress(nil)
+		ConfigureClient(DefaultClientOptions())
+	})
+}
+
+func testClientOptions() ClientOptions {
+	return ClientOptions{Timeout: 5 * time.Second, MaxRetries: 3, MaxRetryWait: 10 * time.Millisecond}
+}
+
+// statusSequence serves the given statuses in order, then 200s, counting
+// requests and recording each request body.
+func statusSequence(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32, *[]string) {
+	t.Helper()
+	var calls atomic.Int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls, &bodies
+}
+
+func doRequest(t *testing.T, ctx context.Context, method, url string, body io.Reader) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	return Do(ProviderGitHub, req)
+}
+
+func TestDo_RetriesServerErrorsForIdempotentMethods(t *testing.T) {
+	useTestClient(t, testClientOptions())
+	srv, calls, _ := statusSequence(t, http.StatusBadGateway, http.StatusServiceUnavailable)
+
+	resp, err := doRequest(t, context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("status = %d after %d calls, want 200 after 3", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestDo_DoesNotRetryServerErrorsForPOST(t *testing.T) {
+	useTestClient(t, testClientOptions())
+	srv, calls, _ := statusSequence(t, http.StatusBadGateway)
+
+	resp, err := doRequest(t, context.Background(), http.MethodPost, srv.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != 1 {
+		t.Errorf("status = %d after %d calls, want 502 after 1", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestDo_RetriesTooManyRequestsWithBody(t *testing.T) {
+	useTestClient(t, testClientOptions())
+	srv, calls, bodies := statusSequence(t, http.StatusTooManyRequests)
+
+	resp, err := doRequest(t, context.Background(), http.MethodPost, srv.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("status = %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+	}
+	if len(*bodies) != 2 || (*bodies)[1] != "payload" {
+		t.Errorf("bodies = %q, want the payload replayed", *bodies)
+	}
+}
+
+func TestDo_GivesUpAfterMaxRetries(t *testing.T) {
+	opts := testClientOptions()
+	opts.MaxRetries = 1
+	useTestClient(t, opts)
+	srv, calls, _ := statusSequence(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+
+	resp, err := doRequest(t, context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 2 {
+		t.Errorf("status = %d after %d calls, want 503 after 2", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestDo_RetryAfterBeyondMaxWaitIsNotRetried(t *testing.T) {
+	useTestClient(t, testClientOptions())
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	resp, err := doRequest(t, context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Errorf("status = %d after %d calls, want 429 after 1", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestDo_ExhaustedRateLimitFailsFast(t *testing.T) {
+	useTestClient(t, testClientOptions())
+	var calls atomic.Int32
+	reset := time.Now().Add(time.Hour).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	resp, err := doRequest(t, context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("first Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := doRequest(t, context.Background(), http.MethodGet, srv.URL, nil); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("second Do error = %v, want ErrRateLimitExceeded", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1 (the second request must not be sent)", calls.Load())
+	}
+}
+
+func TestDo_LimitsConcurrencyUntilBodyClosed(t *testing.T) {
+	opts := testClientOptions()
+	opts.MaxConcurrent = 1
+	useTestClient(t, opts)
+	srv, _, _ := statusSequence(t)
+
+	first, err := doRequest(t, context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("first Do: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := doRequest(t, ctx, http.MethodGet, srv.URL, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second Do error = %v, want it to wait for the slot", err)
+	}
+
+	first.Body.Close()
+	second, err := doRequest(t, context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Do after release: %v", err)
+	}
+	second.Body.Close()
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("7"); !ok || d != 7*time.Second {
+		t.Errorf("parseRetryAfter(7) = (%v, %v), want 7s", d, ok)
+	}
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(date); !ok || d <= 0 || d > time.Minute {
+		t.Errorf("parseRetryAfter(date) = (%v, %v), want about a minute", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Error("parseRetryAfter(soon) should not parse")
+	}
+}
@@ -44,6 +44,14 @@ func (p ProviderType) IsPATBased() bool {
 	return p == ProviderBitbucketDC
 }
 
+// SignsDeliveries returns true if the provider authenticates webhook
+// deliveries with the shared secret (an HMAC signature or token header).
+// Azure DevOps service hooks carry no signature, so their callback URL keeps
+// an embedded secret.
+func (p ProviderType) SignsDeliveries() bool {
+	return p != ProviderAzureDevOps
+}
+
 // IsValid is an alias for Valid()
 func (p ProviderType) IsValid() bool {
 	return p.Valid()
@@ -229,23 +237,69 @@ type SCMOAuthToken struct {
 
 // ModuleSCMRepo represents a link between a module and an SCM repository
 type ModuleSCMRepo struct {
-	ID              uuid.UUID  `json:"id" db:"id"`
-	ModuleID        uuid.UUID  `json:"module_id" db:"module_id"`
-	SCMProviderID   uuid.UUID  `json:"scm_provider_id" db:"scm_provider_id"`
-	RepositoryOwner string     `json:"repository_owner" db:"repository_owner"`
-	RepositoryName  string     `json:"repository_name" db:"repository_name"`
-	RepositoryURL   *string    `json:"repository_url,omitempty" db:"repository_url"`
-	DefaultBranch   string     `json:"default_branch" db:"default_branch"`
-	ModulePath      string     `json:"module_path" db:"module_path"`
-	TagPattern      string     `json:"tag_pattern" db:"tag_pattern"`
-	AutoPublish     bool       `json:"auto_publish_enabled" db:"auto_publish"`
-	WebhookID       *string    `json:"webhook_id,omitempty" db:"webhook_id"`
-	WebhookURL      *string    `json:"webhook_url,omitempty" db:"webhook_url"`
-	WebhookEnabled  bool       `json:"webhook_enabled" db:"webhook_enabled"`
-	LastSyncAt      *time.Time `json:"last_sync_at,omitempty" db:"last_sync_at"`
-	LastSyncCommit  *string    `json:"last_sync_commit,omitempty" db:"last_sync_commit"`
-	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	ID              uuid.UUID `json:"id" db:"id"`
+	ModuleID        uuid.UUID `json:"module_id" db:"module_id"`
+	SCMProviderID   uuid.UUID `json:"scm_provider_id" db:"scm_provider_id"`
+	RepositoryOwner string    `json:"repository_owner" db:"repository_owner"`
+	RepositoryName  string    `json:"repository_name" db:"repository_name"`
+	RepositoryURL   *string   `json:"repository_url,omitempty" db:"repository_url"`
+	DefaultBranch   string    `json:"default_branch" db:"default_branch"`
+	ModulePath      string    `json:"module_path" db:"module_path"`
+	TagPattern      string    `json:"tag_pattern" db:"tag_pattern"`
+	AutoPublish     bool      `json:"auto_publish_enabled" db:"auto_publish"`
+	// SkipPrerelease makes the publisher ignore tags whose version carries a
+	// semver pre-release suffix (v1.4.0-rc.1).
+	SkipPrerelease bool       `json:"skip_prerelease" db:"skip_prerelease"`
+	WebhookID      *string    `json:"webhook_id,omitempty" db:"webhook_id"`
+	WebhookURL     *string    `json:"webhook_url,omitempty" db:"webhook_url"`
+	WebhookEnabled bool       `json:"webhook_enabled" db:"webhook_enabled"`
+	LastSyncAt     *time.Time `json:"last_sync_at,omitempty" db:"last_sync_at"`
+	LastSyncCommit *string    `json:"last_sync_commit,omitempty" db:"last_sync_commit"`
+	// PublishingUserID is the user whose OAuth token backs webhook publishes on
+	// oauth_user providers. Nil means the module's creator.
+	PublishingUserID *uuid.UUID `json:"publishing_user_id,omitempty" db:"publishing_user_id"`
+	// TokenInvalidAt is set when the publishing token was last found missing,
+	// expired, or rejected by the provider.
+	TokenInvalidAt *time.Time `json:"token_invalid_at,omitempty" db:"token_invalid_at"`
+	// WebhookSecret is the link's own delivery signing secret, set by secret
+	// rotation. Nil means deliveries are signed with the provider's secret.
+	WebhookSecret *string `json:"-" db:"webhook_secret"`
+	// PreviousWebhookSecret and PreviousWebhookURL are what the last rotation
+	// replaced (a nil secret meaning the provider's); both are still accepted
+	// until WebhookSecretGraceUntil.
+	PreviousWebhookSecret   *string    `json:"-" db:"previous_webhook_secret"`
+	PreviousWebhookURL      *string    `json:"-" db:"previous_webhook_url"`
+	WebhookSecretGraceUntil *time.Time `json:"webhook_secret_grace_until,omitempty" db:"webhook_secret_grace_until"`
+	// ModuleAddress is the canonical hostname/namespace/name/system address
+	// of the linked module, recorded when the link was created. Nil for
+	// links that predate it.
+	ModuleAddress *string   `json:"module_address,omitempty" db:"module_address"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	// LastPublish describes the most recent version published from the link.
+	LastPublish *PublishMetadata `json:"last_publish,omitempty" db:"-"`
+}
+
+// PublishMetadata records how a module version was published from a linked
+// repository, so a published archive can be traced back to its source.
+type PublishMetadata struct {
+	ModuleVersionID uuid.UUID `json:"module_version_id"`
+	Version         string    `json:"version"`
+	TagName         string    `json:"tag_name"`
+	// CommitSHA is the commit the tag pointed at when it was published.
+	CommitSHA string `json:"commit_sha"`
+	// SourceArchiveSizeBytes and SourceArchiveSHA256 describe the archive
+	// downloaded from the provider, before it was repackaged.
+	SourceArchiveSizeBytes int64  `json:"source_archive_size_bytes"`
+	SourceArchiveSHA256    string `json:"source_archive_sha256"`
+	// PackageDurationMs is how long downloading and repackaging took.
+	PackageDurationMs int64 `json:"package_duration_ms"`
+	// ConnectorAPICalls lists the connector methods called, in order.
+	ConnectorAPICalls []string `json:"connector_api_calls"`
+	// ModuleAddress is the link's canonical module address at publish time;
+	// empty for links that have none.
+	ModuleAddress string    `json:"module_address,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // SCMWebhookEvent represents a webhook event received from an SCM provider
@@ -253,6 +307,7 @@ type SCMWebhookEvent struct {
 	ID                  uuid.UUID              `json:"id" db:"id"`
 	ModuleSCMRepoID     uuid.UUID              `json:"module_scm_repo_id" db:"module_scm_repo_id"`
 	EventID             *string                `json:"event_id,omitempty" db:"event_id"`
+	DeliveryID          *string                `json:"delivery_id,omitempty" db:"delivery_id"` // provider delivery ID; a redelivery carries the same one
 	EventType           WebhookEventType       `json:"event_type" db:"event_type"`
 	Ref                 *string                `json:"ref,omitempty" db:"ref"`
 	CommitSHA           *string                `json:"commit_sha,omitempty" db:"commit_sha"`
@@ -271,6 +326,52 @@ type SCMWebhookEvent struct {
 	NextRetryAt         *time.Time             `json:"next_retry_at,omitempty" db:"next_retry_at"`
 	LastError           *string                `json:"last_error,omitempty" db:"last_error"`
 	CreatedAt           time.Time              `json:"created_at" db:"created_at"`
+	// PublishMetadata describes the version the event published, if any.
+	PublishMetadata *PublishMetadata `json:"publish_metadata,omitempty" db:"-"`
+	// PublishTask is the publish the event queued, if any.
+	PublishTask *PublishTask `json:"publish_task,omitempty" db:"-"`
+}
+
+// PublishTaskStatus is the state of a queued SCM tag publish.
+type PublishTaskStatus string
+
+const (
+	PublishTaskQueued    PublishTaskStatus = "queued"
+	PublishTaskRunning   PublishTaskStatus = "running"
+	PublishTaskSucceeded PublishTaskStatus = "succeeded"
+	PublishTaskSkipped   PublishTaskStatus = "skipped"
+	PublishTaskFailed    PublishTaskStatus = "failed"
+)
+
+// IsTerminal reports whether the task will not be attempted again.
+func (s PublishTaskStatus) IsTerminal() bool {
+	return s == PublishTaskSucceeded || s == PublishTaskSkipped || s == PublishTaskFailed
+}
+
+// PublishTask is a tag publish queued by a webhook delivery or a tag
+// backfill and processed by the scm-publish-queue job.
+type PublishTask struct {
+	ID              uuid.UUID         `json:"id" db:"id"`
+	ModuleSCMRepoID uuid.UUID         `json:"module_scm_repo_id" db:"module_scm_repo_id"`
+	WebhookEventID  *uuid.UUID        `json:"webhook_event_id,omitempty" db:"webhook_event_id"`
+	DeliveryID      *string           `json:"delivery_id,omitempty" db:"delivery_id"` // at most one task per link and delivery ID
+	BackfillID      *uuid.UUID        `json:"backfill_id,omitempty" db:"backfill_id"` // the tag backfill that queued the task, if any
+	BackfillSeq     *int              `json:"-" db:"backfill_seq"`                    // the task's place in its backfill
+	TagName         string            `json:"tag_name" db:"tag_name"`
+	CommitSHA       *string           `json:"commit_sha,omitempty" db:"commit_sha"`
+	Status          PublishTaskStatus `json:"status" db:"status"`
+	Attempts        int               `json:"attempts" db:"attempts"`
+	MaxAttempts     int               `json:"max_attempts" db:"max_attempts"`
+	NextAttemptAt   time.Time         `json:"next_attempt_at" db:"next_attempt_at"`
+	LockedUntil     *time.Time        `json:"-" db:"locked_until"`
+	LastError       *string           `json:"last_error,omitempty" db:"last_error"`
+	ResultVersionID *uuid.UUID        `json:"result_version_id,omitempty" db:"result_version_id"`
+	StartedAt       *time.Time        `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt     *time.Time        `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+	// PublishMetadata describes the version the task published, if any.
+	PublishMetadata *PublishMetadata `json:"publish_metadata,omitempty" db:"-"`
 }
 
 // VersionImmutabilityViolation represents a detected tag movement
@@ -0,0 +1,67 @@
+// webhook_secrets.go decides which webhook secrets a module SCM link accepts,
+// including the grace period that follows a secret rotation.
+package scm
+
+import (
+	"crypto/subtle"
+	"path"
+	"time"
+)
+
+// inRotationGrace reports whether the secret and URL replaced by the last
+// rotation are still accepted at now.
+func (l *ModuleSCMRepo) inRotationGrace(now time.Time) bool {
+	return l.WebhookSecretGraceUntil != nil && now.Before(*l.WebhookSecretGraceUntil)
+}
+
+// DeliverySecrets returns the secrets a delivery signature may be verified
+// against at now: the link's signing secret (providerSecret when the link has
+// none of its own) and, during a rotation grace period, the one it replaced.
+// Empty secrets are left out.
+func (l *ModuleSCMRepo) DeliverySecrets(providerSecret string, now time.Time) []string {
+	resolve := func(s *string) string {
+		if s != nil {
+			return *s
+		}
+		return providerSecret
+	}
+
+	var secrets []string
+	if current := resolve(l.WebhookSecret); current != "" {
+		secrets = append(secrets, current)
+	}
+	if l.inRotationGrace(now) {
+		if previous := resolve(l.PreviousWebhookSecret); previous != "" && (len(secrets) == 0 || previous != secrets[0]) {
+			secrets = append(secrets, previous)
+		}
+	}
+	return secrets
+}
+
+// AcceptsPathSecret reports whether secret, taken from a legacy
+// /webhooks/scm/<link id>/<secret> callback URL, matches the secret embedded
+// in the link's webhook URL or, during a rotation grace period, in the URL it
+// replaced.
+func (l *ModuleSCMRepo) AcceptsPathSecret(secret string, now time.Time) bool {
+	if secret == "" {
+		return false
+	}
+	if l.pathSecretMatches(l.WebhookURL, secret) {
+		return true
+	}
+	return l.inRotationGrace(now) && l.pathSecretMatches(l.PreviousWebhookURL, secret)
+}
+
+// pathSecretMatches compares secret against the last path segment of a
+// callback URL in constant time. URLs without an embedded secret end in the
+// link ID and never match.
+func (l *ModuleSCMRepo) pathSecretMatches(callbackURL *string, secret string) bool {
+	if callbackURL == nil {
+		return false
+	}
+	stored := path.Base(*callbackURL)
+	if stored == l.ID.String() {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(secret)) == 1
+}
@@ -0,0 +1,88 @@
+package scm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestDeliverySecrets(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		link     ModuleSCMRepo
+		provider string
+		want     []string
+	}{
+		{"provider secret", ModuleSCMRepo{}, "prov", []string{"prov"}},
+		{"no secret anywhere", ModuleSCMRepo{}, "", nil},
+		{"link secret", ModuleSCMRepo{WebhookSecret: strPtr("new")}, "prov", []string{"new"}},
+		{"grace keeps provider secret", ModuleSCMRepo{WebhookSecret: strPtr("new"), WebhookSecretGraceUntil: &future}, "prov", []string{"new", "prov"}},
+		{"grace keeps previous link secret", ModuleSCMRepo{
+			WebhookSecret: strPtr("new"), PreviousWebhookSecret: strPtr("old"), WebhookSecretGraceUntil: &future,
+		}, "prov", []string{"new", "old"}},
+		{"grace expired", ModuleSCMRepo{
+			WebhookSecret: strPtr("new"), PreviousWebhookSecret: strPtr("old"), WebhookSecretGraceUntil: &past,
+		}, "prov", []string{"new"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.DeliverySecrets(tt.provider, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeliverySecrets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceptsPathSecret(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+	id := uuid.New()
+	legacy := "https://registry.example.com/webhooks/scm/" + id.String() + "/s3cret"
+	secretless := "https://registry.example.com/webhooks/scm/" + id.String()
+
+	tests := []struct {
+		name   string
+		link   ModuleSCMRepo
+		secret string
+		want   bool
+	}{
+		{"legacy URL match", ModuleSCMRepo{ID: id, WebhookURL: &legacy}, "s3cret", true},
+		{"legacy URL mismatch", ModuleSCMRepo{ID: id, WebhookURL: &legacy}, "other", false},
+		{"empty secret", ModuleSCMRepo{ID: id, WebhookURL: &legacy}, "", false},
+		{"no URL", ModuleSCMRepo{ID: id}, "s3cret", false},
+		{"secretless URL never matches its link ID", ModuleSCMRepo{ID: id, WebhookURL: &secretless}, id.String(), false},
+		{"previous URL during grace", ModuleSCMRepo{
+			ID: id, WebhookURL: &secretless, PreviousWebhookURL: &legacy, WebhookSecretGraceUntil: &future,
+		}, "s3cret", true},
+		{"previous URL after grace", ModuleSCMRepo{
+			ID: id, WebhookURL: &secretless, PreviousWebhookURL: &legacy, WebhookSecretGraceUntil: &past,
+		}, "s3cret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.AcceptsPathSecret(tt.secret, now); got != tt.want {
+				t.Errorf("AcceptsPathSecret(%q) = %v, want %v", tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderTypeSignsDeliveries(t *testing.T) {
+	for _, pt := range []ProviderType{ProviderGitHub, ProviderGitLab, ProviderBitbucketDC} {
+		if !pt.SignsDeliveries() {
+			t.Errorf("ProviderType(%q).SignsDeliveries() = false, want true", pt)
+		}
+	}
+	if ProviderAzureDevOps.SignsDeliveries() {
+		t.Error("ProviderAzureDevOps.SignsDeliveries() = true, want false")
+	}
+}
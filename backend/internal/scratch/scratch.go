@@ -0,0 +1,153 @@
+// Package scratch manages the registry's scratch directory, the one place on
+// local disk where uploads, SCM publishes, the module proxy and mirror syncs
+// stage archives while they work on them.
+//
+// Work that can write a lot reserves its worst case from a shared budget
+// before it starts, so a burst of concurrent publishes is turned away instead
+// of filling the disk. Entries left behind by a crash are removed by Sweep,
+// which the scratch cleanup job runs at startup and then periodically.
+//
+// A nil *Space is valid: it stages in the OS temporary directory and never
+// refuses a reservation.
+package scratch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/telemetry"
+)
+
+// ErrBudgetExceeded is returned by Reserve when the reservation would take the
+// scratch space in use over its budget. It clears as in-flight work finishes.
+var ErrBudgetExceeded = errors.New("scratch space budget exceeded; retry later")
+
+// Space is a scratch directory with a size budget.
+type Space struct {
+	dir    string
+	budget int64 // bytes; 0 means unlimited
+
+	mu       sync.Mutex
+	reserved int64
+}
+
+// New creates dir if needed and returns a Space rooted there. budget is in
+// bytes; 0 disables it.
+func New(dir string, budget int64) (*Space, error) {
+	if dir == "" {
+		return nil, errors.New("scratch directory is not set")
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("create scratch directory: %w", err)
+	}
+	return &Space{dir: dir, budget: budget}, nil
+}
+
+// Dir returns the scratch directory.
+func (s *Space) Dir() string {
+	if s == nil {
+		return os.TempDir()
+	}
+	return s.dir
+}
+
+// Reserve claims n bytes of the budget for work that may write up to n bytes
+// to the scratch directory. The returned release gives them back; it is safe
+// to call more than once, so it can be deferred and also called early.
+func (s *Space) Reserve(n int64) (release func(), err error) {
+	if s == nil || s.budget <= 0 {
+		return func() {}, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reserved+n > s.budget {
+		return nil, ErrBudgetExceeded
+	}
+	s.reserved += n
+	telemetry.ScratchReservedBytes.Set(float64(s.reserved))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.reserved -= n
+			telemetry.ScratchReservedBytes.Set(float64(s.reserved))
+		})
+	}, nil
+}
+
+// Reserved returns the bytes currently reserved.
+func (s *Space) Reserved() int64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reserved
+}
+
+// MkdirTemp creates a new directory in the scratch directory, as os.MkdirTemp.
+func (s *Space) MkdirTemp(pattern string) (string, error) {
+	return os.MkdirTemp(s.Dir(), pattern)
+}
+
+// CreateTemp creates a new file in the scratch directory, as os.CreateTemp.
+func (s *Space) CreateTemp(pattern string) (*os.File, error) {
+	return os.CreateTemp(s.Dir(), pattern)
+}
+
+// Usage returns the total size of the files in the scratch directory. Files
+// removed during the walk are skipped.
+func (s *Space) Usage() (int64, error) {
+	var total int64
+	err := filepath.WalkDir(s.Dir(), func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// Sweep removes the entries at the top of the scratch directory last modified
+// more than maxAge ago, and returns how many it removed. Work in flight
+// creates its own entry there and finishes well within maxAge, so anything
+// older was left behind by a failed or interrupted run.
+func (s *Space) Sweep(maxAge time.Duration) (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	var errs []error
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.dir, e.Name())); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
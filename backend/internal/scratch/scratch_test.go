@@ -0,0 +1,83 @@
+package scratch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReserve_Budget(t *testing.T) {
+	s, err := New(t.TempDir(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release, err := s.Reserve(60)
+	if err != nil {
+		t.Fatalf("Reserve(60) = %v", err)
+	}
+	if _, err := s.Reserve(50); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("Reserve(50) over budget = %v, want ErrBudgetExceeded", err)
+	}
+	release()
+	release() // a second call must not free the bytes twice
+	if got := s.Reserved(); got != 0 {
+		t.Fatalf("Reserved = %d after release, want 0", got)
+	}
+	if _, err := s.Reserve(100); err != nil {
+		t.Fatalf("Reserve(100) after release = %v", err)
+	}
+}
+
+func TestReserve_Unlimited(t *testing.T) {
+	s, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Reserve(1 << 40); err != nil {
+		t.Errorf("Reserve with no budget = %v", err)
+	}
+	var none *Space
+	if _, err := none.Reserve(1 << 40); err != nil {
+		t.Errorf("nil Space Reserve = %v", err)
+	}
+	if none.Dir() != os.TempDir() {
+		t.Errorf("nil Space Dir = %q, want the OS temp dir", none.Dir())
+	}
+}
+
+func TestSweepAndUsage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, _ := s.MkdirTemp("scm-publish-*")
+	if err := os.WriteFile(filepath.Join(old, "main.tf"), make([]byte, 300), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(old, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	fresh, _ := s.CreateTemp("module-upload-*.tar.gz")
+	_, _ = fresh.Write(make([]byte, 200))
+	fresh.Close()
+
+	if usage, err := s.Usage(); err != nil || usage != 500 {
+		t.Fatalf("Usage = %d, %v; want 500", usage, err)
+	}
+	removed, err := s.Sweep(time.Hour)
+	if err != nil || removed != 1 {
+		t.Fatalf("Sweep = %d, %v; want 1 removed", removed, err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("orphaned directory still present: %v", err)
+	}
+	if _, err := os.Stat(fresh.Name()); err != nil {
+		t.Errorf("fresh file removed: %v", err)
+	}
+}
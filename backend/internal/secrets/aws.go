@@ -0,0 +1,159 @@
+// aws.go reads secrets from AWS Secrets Manager. The GetSecretValue call is
+// signed with the SDK's SigV4 signer and the default credential chain, so no
+// service-specific SDK client is needed.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// AWSOptions configures the AWS Secrets Manager backend.
+type AWSOptions struct {
+	// Region is the Secrets Manager region; empty uses the SDK default
+	// (AWS_REGION, shared config).
+	Region string
+	// Endpoint overrides the Secrets Manager endpoint, e.g. for a VPC
+	// endpoint or LocalStack.
+	Endpoint string
+	// Credentials overrides the default credential chain; for tests.
+	Credentials aws.CredentialsProvider
+}
+
+// AWSProvider reads secrets from AWS Secrets Manager.
+type AWSProvider struct {
+	endpoint string
+	region   string
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+	client   *http.Client
+}
+
+// NewAWSProvider returns an AWSProvider using the default AWS credential
+// chain (environment, shared config, IRSA / pod identity, instance role).
+func NewAWSProvider(ctx context.Context, opts AWSOptions, client *http.Client) (*AWSProvider, error) {
+	region, creds := opts.Region, opts.Credentials
+	if creds == nil || region == "" {
+		var loadOpts []func(*awsconfig.LoadOptions) error
+		if region != "" {
+			loadOpts = append(loadOpts, awsconfig.WithRegion(region))
+		}
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx, loadOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("aws secrets manager: loading AWS config: %w", err)
+		}
+		if region == "" {
+			region = awsCfg.Region
+		}
+		if creds == nil {
+			creds = awsCfg.Credentials
+		}
+	}
+	if region == "" {
+		return nil, errors.New("aws secrets manager: region is required (set secrets.aws.region or AWS_REGION)")
+	}
+	endpoint := strings.TrimSuffix(opts.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+	return &AWSProvider{
+		endpoint: endpoint,
+		region:   region,
+		creds:    aws.NewCredentialsCache(creds),
+		signer:   v4.NewSigner(),
+		client:   client,
+	}, nil
+}
+
+// Get implements Provider.
+func (p *AWSProvider) Get(ctx context.Context, ref string) (string, error) {
+	id, key := splitRef(ref, "")
+	if id == "" {
+		return "", fmt.Errorf("aws secrets manager: empty secret id in %q", ref)
+	}
+	payload, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	creds, err := p.creds.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager: retrieving AWS credentials: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	if err := p.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "secretsmanager", p.region, time.Now()); err != nil {
+		return "", fmt.Errorf("aws secrets manager: signing request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager at %s is unreachable: %w", p.endpoint, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager: reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(body, &e)
+		if strings.HasSuffix(e.Type, "ResourceNotFoundException") {
+			return "", fmt.Errorf("aws secrets manager: secret %s: %w", id, ErrNotFound)
+		}
+		return "", fmt.Errorf("aws secrets manager: GetSecretValue %s returned %d: %s %s", id, resp.StatusCode, e.Type, e.Message)
+	}
+
+	var out struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"` // base64 on the wire; decoded by encoding/json
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("aws secrets manager: decoding response: %w", err)
+	}
+	var value string
+	switch {
+	case out.SecretString != nil:
+		value = *out.SecretString
+	case out.SecretBinary != nil:
+		value = string(out.SecretBinary)
+	}
+	if key == "" {
+		return value, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("aws secrets manager: secret %s is not a JSON object, so key %q cannot be read", id, key)
+	}
+	v, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("aws secrets manager: key %q in %s: %w", key, id, ErrNotFound)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("aws secrets manager: key %q in %s is not a string", key, id)
+	}
+	return s, nil
+}
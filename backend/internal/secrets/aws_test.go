@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// fakeSecretsManager answers GetSecretValue for the secrets in values and
+// rejects unsigned requests.
+func fakeSecretsManager(t *testing.T, values map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			t.Errorf("X-Amz-Target = %q", r.Header.Get("X-Amz-Target"))
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(auth, "/us-west-2/secretsmanager/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"__type":"UnrecognizedClientException","message":"bad signature"}`))
+			return
+		}
+		var in struct{ SecretId string }
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		v, ok := values[in.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.secretsmanager#ResourceNotFoundException","message":"not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"Name": in.SecretId, "SecretString": v})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestAWSProvider(t *testing.T, endpoint string) *AWSProvider {
+	t.Helper()
+	p, err := NewAWSProvider(context.Background(), AWSOptions{
+		Region:      "us-west-2",
+		Endpoint:    endpoint,
+		Credentials: credentials.NewStaticCredentialsProvider("AKIDTEST", "secret", ""),
+	}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewAWSProvider: %v", err)
+	}
+	return p
+}
+
+func TestAWSProvider_Get(t *testing.T) {
+	srv := fakeSecretsManager(t, map[string]string{
+		"registry/encryption-key": "0123456789abcdef0123456789abcdef",
+		"registry/db":             `{"username":"registry","password":"from-aws","port":5432}`,
+	})
+	p := newTestAWSProvider(t, srv.URL)
+	ctx := context.Background()
+
+	if v, err := p.Get(ctx, "registry/encryption-key"); err != nil || v != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("Get plain secret = %q, %v", v, err)
+	}
+	if v, err := p.Get(ctx, "registry/db#password"); err != nil || v != "from-aws" {
+		t.Errorf("Get JSON key = %q, %v; want from-aws", v, err)
+	}
+	if _, err := p.Get(ctx, "registry/db#missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing key: err = %v, want ErrNotFound", err)
+	}
+	if _, err := p.Get(ctx, "registry/db#port"); err == nil {
+		t.Error("Get non-string key: want an error")
+	}
+	if _, err := p.Get(ctx, "registry/encryption-key#password"); err == nil {
+		t.Error("Get key of a non-JSON secret: want an error")
+	}
+	if _, err := p.Get(ctx, "registry/other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing secret: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAWSProvider_Unreachable(t *testing.T) {
+	srv := fakeSecretsManager(t, nil)
+	endpoint := srv.URL
+	srv.Close()
+
+	_, err := newTestAWSProvider(t, endpoint).Get(context.Background(), "registry/db#password")
+	if err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("Get = %v, want an unreachable error", err)
+	}
+}
@@ -0,0 +1,70 @@
+// refresher.go re-fetches a secret on an interval so rotated credentials
+// (for example a Vault-managed database password) take effect without a
+// restart.
+package secrets
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Refresher polls one secret and calls OnChange when its value changes.
+type Refresher struct {
+	provider Provider
+	ref      string
+	interval time.Duration
+	onChange func(value string)
+
+	mu      sync.Mutex
+	current string
+}
+
+// NewRefresher returns a Refresher for ref, starting from the already
+// resolved value current.
+func NewRefresher(provider Provider, ref, current string, interval time.Duration, onChange func(value string)) *Refresher {
+	return &Refresher{provider: provider, ref: ref, interval: interval, onChange: onChange, current: current}
+}
+
+// Value returns the most recently fetched value.
+func (r *Refresher) Value() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Run polls until ctx is done. A failed fetch keeps the current value and is
+// retried at the next tick.
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh fetches the secret once, reporting whether it changed.
+func (r *Refresher) Refresh(ctx context.Context) bool {
+	value, err := r.provider.Get(ctx, r.ref)
+	if err != nil {
+		slog.Warn("failed to refresh secret; keeping the current value", "ref", r.ref, "error", err)
+		return false
+	}
+	r.mu.Lock()
+	changed := value != r.current
+	r.current = value
+	r.mu.Unlock()
+	if changed {
+		slog.Info("secret rotated", "ref", r.ref)
+		if r.onChange != nil {
+			r.onChange(value)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider returns value, or err when set.
+type fakeProvider struct {
+	mu    sync.Mutex
+	value string
+	err   error
+}
+
+func (f *fakeProvider) Get(context.Context, string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.value, f.err
+}
+
+func (f *fakeProvider) set(value string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.value, f.err = value, err
+}
+
+func TestRefresher_Refresh(t *testing.T) {
+	p := &fakeProvider{value: "v1"}
+	var changes []string
+	r := NewRefresher(p, "db#password", "v1", time.Minute, func(v string) { changes = append(changes, v) })
+	ctx := context.Background()
+
+	if r.Refresh(ctx) {
+		t.Error("Refresh with an unchanged value reported a change")
+	}
+	p.set("v2", nil)
+	if !r.Refresh(ctx) || r.Value() != "v2" {
+		t.Errorf("Refresh after rotation: Value = %q, want v2", r.Value())
+	}
+	p.set("", errors.New("vault sealed"))
+	if r.Refresh(ctx) || r.Value() != "v2" {
+		t.Errorf("failed Refresh: Value = %q, want v2 kept", r.Value())
+	}
+	if len(changes) != 1 || changes[0] != "v2" {
+		t.Errorf("OnChange calls = %v, want [v2]", changes)
+	}
+}
+
+func TestRefresher_RunStopsWithContext(t *testing.T) {
+	p := &fakeProvider{value: "v2"}
+	changed := make(chan string, 1)
+	r := NewRefresher(p, "db#password", "v1", time.Millisecond, func(v string) { changed <- v })
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case v := <-changed:
+		if v != "v2" {
+			t.Errorf("OnChange(%q), want v2", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not pick up the rotated value")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew_Backends(t *testing.T) {
+	for backend, want := range map[string]Provider{
+		"":          EnvProvider{},
+		BackendEnv:  EnvProvider{},
+		BackendFile: FileProvider{},
+	} {
+		p, err := New(context.Background(), Options{Backend: backend})
+		if err != nil {
+			t.Fatalf("New(%q): %v", backend, err)
+		}
+		if p != want {
+			t.Errorf("New(%q) = %T, want %T", backend, p, want)
+		}
+	}
+	if _, err := New(context.Background(), Options{Backend: "keychain"}); err == nil {
+		t.Error("New with an unknown backend: want an error")
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("REGISTRY_TEST_SECRET", "s3cret")
+	v, err := EnvProvider{}.Get(context.Background(), "REGISTRY_TEST_SECRET")
+	if err != nil || v != "s3cret" {
+		t.Errorf("Get = %q, %v; want s3cret", v, err)
+	}
+	if _, err := (EnvProvider{}).Get(context.Background(), "REGISTRY_TEST_SECRET_UNSET"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get unset variable: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db-password")
+	if err := os.WriteFile(path, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := FileProvider{}.Get(context.Background(), path)
+	if err != nil || v != "hunter2" {
+		t.Errorf("Get = %q, %v; want hunter2 without the trailing newline", v, err)
+	}
+	if _, err := (FileProvider{}).Get(context.Background(), path+".missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing file: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSplitRef(t *testing.T) {
+	cases := []struct{ ref, path, key string }{
+		{"registry/prod#db_password", "registry/prod", "db_password"},
+		{"registry/prod", "registry/prod", "value"},
+		{"arn:aws:secretsmanager:us-east-1:1:secret:a#b", "arn:aws:secretsmanager:us-east-1:1:secret:a", "b"},
+	}
+	for _, tc := range cases {
+		path, key := splitRef(tc.ref, "value")
+		if path != tc.path || key != tc.key {
+			t.Errorf("splitRef(%q) = %q, %q; want %q, %q", tc.ref, path, key, tc.path, tc.key)
+		}
+	}
+}
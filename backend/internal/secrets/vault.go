@@ -0,0 +1,218 @@
+// vault.go reads secrets from a HashiCorp Vault KV v2 engine, authenticating
+// with a token or the Kubernetes auth method.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultKubernetesTokenFile is the service account token Kubernetes mounts
+// into every pod.
+const DefaultKubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token" // #nosec G101 -- a file path, not a credential
+
+// VaultOptions configures the Vault backend.
+type VaultOptions struct {
+	// Address is the Vault server URL, e.g. https://vault.example.com:8200.
+	Address string
+	// Namespace is sent as X-Vault-Namespace (Vault Enterprise); optional.
+	Namespace string
+	// Mount is the KV v2 mount path; default "secret".
+	Mount string
+	// AuthMethod is "token" (default) or "kubernetes".
+	AuthMethod string
+	// Token, or the contents of TokenFile, authenticates the token method.
+	Token     string
+	TokenFile string
+	// Role is the Vault role the kubernetes method logs in as.
+	Role string
+	// KubernetesMount is the kubernetes auth mount path; default "kubernetes".
+	KubernetesMount string
+	// KubernetesTokenFile is the service account JWT presented to Vault;
+	// default DefaultKubernetesTokenFile.
+	KubernetesTokenFile string
+}
+
+// VaultProvider reads secrets from a Vault KV v2 engine.
+type VaultProvider struct {
+	opts   VaultOptions
+	client *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewVaultProvider returns a VaultProvider. For the token method the token
+// is read here; the kubernetes method logs in on the first Get.
+func NewVaultProvider(opts VaultOptions, client *http.Client) (*VaultProvider, error) {
+	if opts.Address == "" {
+		return nil, errors.New("vault: address is required")
+	}
+	opts.Address = strings.TrimSuffix(opts.Address, "/")
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+	if opts.KubernetesMount == "" {
+		opts.KubernetesMount = "kubernetes"
+	}
+	if opts.KubernetesTokenFile == "" {
+		opts.KubernetesTokenFile = DefaultKubernetesTokenFile
+	}
+	p := &VaultProvider{opts: opts, client: client}
+
+	switch opts.AuthMethod {
+	case "", "token":
+		p.token = opts.Token
+		if p.token == "" && opts.TokenFile != "" {
+			b, err := os.ReadFile(opts.TokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("vault: reading token file: %w", err)
+			}
+			p.token = strings.TrimSpace(string(b))
+		}
+		if p.token == "" {
+			return nil, errors.New("vault: token or token_file is required for token auth")
+		}
+	case "kubernetes":
+		if opts.Role == "" {
+			return nil, errors.New("vault: role is required for kubernetes auth")
+		}
+	default:
+		return nil, fmt.Errorf("vault: unknown auth method %q (must be token or kubernetes)", opts.AuthMethod)
+	}
+	return p, nil
+}
+
+// Get implements Provider. A kubernetes-auth token that Vault rejects is
+// renewed with a fresh login and the read retried once.
+func (p *VaultProvider) Get(ctx context.Context, ref string) (string, error) {
+	path, key := splitRef(ref, "value")
+	if path == "" {
+		return "", fmt.Errorf("vault: empty secret path in %q", ref)
+	}
+	u := p.opts.Address + "/v1/" + strings.Trim(p.opts.Mount, "/") + "/data/" + strings.TrimPrefix(path, "/")
+
+	var data map[string]interface{}
+	err := p.read(ctx, u, &data)
+	if errors.Is(err, errVaultForbidden) && p.opts.AuthMethod == "kubernetes" {
+		p.mu.Lock()
+		p.token = ""
+		p.mu.Unlock()
+		err = p.read(ctx, u, &data)
+	}
+	if err != nil {
+		return "", err
+	}
+	v, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("vault: key %q in %s: %w", key, path, ErrNotFound)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("vault: key %q in %s is not a string", key, path)
+	}
+	return s, nil
+}
+
+// errVaultForbidden marks a 403 response; with kubernetes auth it usually
+// means the login token has expired.
+var errVaultForbidden = errors.New("permission denied")
+
+// read fetches a KV v2 secret's data into data.
+func (p *VaultProvider) read(ctx context.Context, u string, data *map[string]interface{}) error {
+	token, err := p.currentToken(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := p.do(req, &body); err != nil {
+		return err
+	}
+	*data = body.Data.Data
+	return nil
+}
+
+// currentToken returns the Vault token, logging in with the kubernetes
+// method when there is none yet.
+func (p *VaultProvider) currentToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" {
+		return p.token, nil
+	}
+
+	jwt, err := os.ReadFile(p.opts.KubernetesTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("vault: reading kubernetes service account token: %w", err)
+	}
+	payload, err := json.Marshal(map[string]string{"role": p.opts.Role, "jwt": strings.TrimSpace(string(jwt))})
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	u := p.opts.Address + "/v1/auth/" + strings.Trim(p.opts.KubernetesMount, "/") + "/login"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var body struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := p.do(req, &body); err != nil {
+		return "", fmt.Errorf("vault: kubernetes login as role %q: %w", p.opts.Role, err)
+	}
+	if body.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault: kubernetes login as role %q returned no token", p.opts.Role)
+	}
+	p.token = body.Auth.ClientToken
+	return p.token, nil
+}
+
+// do sends req and decodes a 200 response into out.
+func (p *VaultProvider) do(req *http.Request, out interface{}) error {
+	if p.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.opts.Namespace)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault at %s is unreachable: %w", p.opts.Address, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out); err != nil {
+			return fmt.Errorf("vault: decoding response: %w", err)
+		}
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("vault: %s: %w", req.URL.Path, ErrNotFound)
+	case http.StatusForbidden:
+		return fmt.Errorf("vault: %s: %w", req.URL.Path, errVaultForbidden)
+	default:
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&e)
+		return fmt.Errorf("vault: %s returned %d: %s", req.URL.Path, resp.StatusCode, strings.Join(e.Errors, "; "))
+	}
+}
@@ -0,0 +1,156 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeVault serves a KV v2 engine at /v1/secret and kubernetes logins at
+// /v1/auth/kubernetes/login. Logins return the token in its token field.
+type fakeVault struct {
+	token  string
+	data   map[string]map[string]interface{}
+	logins atomic.Int32
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v1/auth/kubernetes/login" {
+		var body struct{ Role, JWT string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Role != "registry" || body.JWT != "sa-jwt" {
+			http.Error(w, `{"errors":["invalid role or jwt"]}`, http.StatusBadRequest)
+			return
+		}
+		f.logins.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]string{"client_token": f.token}})
+		return
+	}
+	if r.Header.Get("X-Vault-Token") != f.token {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+	data, ok := f.data[strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")]
+	if !ok {
+		http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": data}})
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	t.Helper()
+	f := &fakeVault{
+		token: "root-token",
+		data: map[string]map[string]interface{}{
+			"registry/prod": {"db_password": "from-vault", "value": "default-key", "port": 5432},
+		},
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func TestVaultProvider_TokenAuth(t *testing.T) {
+	_, srv := newFakeVault(t)
+	p, err := NewVaultProvider(VaultOptions{Address: srv.URL + "/", Token: "root-token"}, srv.Client())
+	if err != nil {
+		t.Fatalf("NewVaultProvider: %v", err)
+	}
+	ctx := context.Background()
+
+	if v, err := p.Get(ctx, "registry/prod#db_password"); err != nil || v != "from-vault" {
+		t.Errorf("Get with key = %q, %v; want from-vault", v, err)
+	}
+	if v, err := p.Get(ctx, "registry/prod"); err != nil || v != "default-key" {
+		t.Errorf("Get without key = %q, %v; want the value key", v, err)
+	}
+	if _, err := p.Get(ctx, "registry/prod#missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing key: err = %v, want ErrNotFound", err)
+	}
+	if _, err := p.Get(ctx, "registry/staging#db_password"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing path: err = %v, want ErrNotFound", err)
+	}
+	if _, err := p.Get(ctx, "registry/prod#port"); err == nil {
+		t.Error("Get non-string value: want an error")
+	}
+}
+
+func TestVaultProvider_TokenFile(t *testing.T) {
+	_, srv := newFakeVault(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("root-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewVaultProvider(VaultOptions{Address: srv.URL, TokenFile: tokenFile}, srv.Client())
+	if err != nil {
+		t.Fatalf("NewVaultProvider: %v", err)
+	}
+	if v, err := p.Get(context.Background(), "registry/prod#db_password"); err != nil || v != "from-vault" {
+		t.Errorf("Get = %q, %v; want from-vault", v, err)
+	}
+}
+
+func TestVaultProvider_KubernetesAuthRenewsRejectedToken(t *testing.T) {
+	f, srv := newFakeVault(t)
+	jwtFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwtFile, []byte("sa-jwt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewVaultProvider(VaultOptions{
+		Address: srv.URL, AuthMethod: "kubernetes", Role: "registry", KubernetesTokenFile: jwtFile,
+	}, srv.Client())
+	if err != nil {
+		t.Fatalf("NewVaultProvider: %v", err)
+	}
+	ctx := context.Background()
+	if v, err := p.Get(ctx, "registry/prod#db_password"); err != nil || v != "from-vault" {
+		t.Fatalf("Get = %q, %v; want from-vault", v, err)
+	}
+
+	// The login token expires: the next read is rejected, logs in again and
+	// retries.
+	f.token = "renewed-token"
+	if v, err := p.Get(ctx, "registry/prod#db_password"); err != nil || v != "from-vault" {
+		t.Fatalf("Get after expiry = %q, %v; want from-vault", v, err)
+	}
+	if got := f.logins.Load(); got != 2 {
+		t.Errorf("logins = %d, want 2", got)
+	}
+}
+
+func TestVaultProvider_Unreachable(t *testing.T) {
+	_, srv := newFakeVault(t)
+	addr := srv.URL
+	srv.Close()
+
+	p, err := NewVaultProvider(VaultOptions{Address: addr, Token: "root-token"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewVaultProvider: %v", err)
+	}
+	_, err = p.Get(context.Background(), "registry/prod#db_password")
+	if err == nil || !strings.Contains(err.Error(), "unreachable") || !strings.Contains(err.Error(), addr) {
+		t.Errorf("Get = %v, want an unreachable error naming %s", err, addr)
+	}
+}
+
+func TestNewVaultProvider_Validation(t *testing.T) {
+	for name, opts := range map[string]VaultOptions{
+		"no address":     {Token: "t"},
+		"no token":       {Address: "http://vault:8200"},
+		"no role":        {Address: "http://vault:8200", AuthMethod: "kubernetes"},
+		"unknown method": {Address: "http://vault:8200", AuthMethod: "approle"},
+		"bad token file": {Address: "http://vault:8200", TokenFile: "/nonexistent/token"},
+	} {
+		if _, err := NewVaultProvider(opts, http.DefaultClient); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// artifact_immutability.go applies the write-once artifact setting
+// (immutable_artifacts, and per-organization overrides). The delete handlers
+// call CheckVersionDelete before removing a version, every publish path calls
+// RecordPublish once a version is stored, and the storage decorator
+// (storage.NewImmutableStorage) uses it as its guard so that no code path can
+// delete or replace a protected archive.
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// publishLogVerifyBatch is how many publish log entries VerifyPublishLog
+// reads at a time.
+const publishLogVerifyBatch = 1000
+
+// immutabilityStore is the subset of ArtifactImmutabilityRepository
+// ArtifactImmutability needs.
+type immutabilityStore interface {
+	GetOrgSetting(ctx context.Context, orgID string) (*models.OrgArtifactImmutability, error)
+	AnyOrgEnabled(ctx context.Context) (bool, error)
+	FindArtifactByStorageKey(ctx context.Context, key string) (*repositories.StoredArtifact, error)
+	AppendPublishLog(ctx context.Context, e *models.PublishLogEntry) error
+	ListPublishLog(ctx context.Context, afterID int64, limit int) ([]*models.PublishLogEntry, error)
+}
+
+// ArtifactImmutability enforces artifact immutability. A nil
+// *ArtifactImmutability enforces nothing and records nothing.
+type ArtifactImmutability struct {
+	cfg   *config.ImmutableArtifactsConfig
+	store immutabilityStore
+	now   func() time.Time
+}
+
+// NewArtifactImmutability creates an ArtifactImmutability.
+func NewArtifactImmutability(cfg *config.ImmutableArtifactsConfig, repo *repositories.ArtifactImmutabilityRepository) *ArtifactImmutability {
+	return &ArtifactImmutability{cfg: cfg, store: repo, now: time.Now}
+}
+
+var _ storage.ImmutabilityGuard = (*ArtifactImmutability)(nil)
+
+// Effective returns the immutability that applies to orgID's artifacts. An
+// empty orgID (single-tenant artifacts) gets the registry-wide setting only.
+func (a *ArtifactImmutability) Effective(ctx context.Context, orgID string) (models.EffectiveImmutability, error) {
+	if a == nil {
+		return models.EffectiveImmutability{}, nil
+	}
+	var org *models.OrgArtifactImmutability
+	if orgID != "" {
+		var err error
+		if org, err = a.store.GetOrgSetting(ctx, orgID); err != nil {
+			return models.EffectiveImmutability{}, err
+		}
+	}
+	return models.MergeImmutability(a.cfg.Enabled, a.cfg.RetentionDays, org), nil
+}
+
+// CheckVersionDelete returns an error wrapping storage.ErrArtifactImmutable
+// when a version of orgID published at publishedAt is still within its
+// retention period.
+func (a *ArtifactImmutability) CheckVersionDelete(ctx context.Context, orgID string, publishedAt time.Time) error {
+	if a == nil {
+		return nil
+	}
+	eff, err := a.Effective(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	deletableAt, ok := eff.DeletableAt(publishedAt)
+	if !ok {
+		return fmt.Errorf("%w: published versions can never be deleted", storage.ErrArtifactImmutable)
+	}
+	if a.now().Before(deletableAt) {
+		return fmt.Errorf("%w: the version cannot be deleted before %s (retention %d days)",
+			storage.ErrArtifactImmutable, deletableAt.UTC().Format(time.RFC3339), eff.RetentionDays)
+	}
+	return nil
+}
+
+// mayApply reports whether immutability is enabled anywhere, so the storage
+// guard can skip the key lookup on registries that do not use it.
+func (a *ArtifactImmutability) mayApply(ctx context.Context) (bool, error) {
+	if a.cfg.Enabled {
+		return true, nil
+	}
+	return a.store.AnyOrgEnabled(ctx)
+}
+
+// CheckDelete implements storage.ImmutabilityGuard: deleting a version's
+// stored file is refused while the version could not be deleted itself.
+// Objects no version owns (temporary files, already deleted versions) are
+// not protected.
+func (a *ArtifactImmutability) CheckDelete(ctx context.Context, path string) error {
+	if a == nil {
+		return nil
+	}
+	if ok, err := a.mayApply(ctx); err != nil || !ok {
+		return err
+	}
+	artifact, err := a.store.FindArtifactByStorageKey(ctx, path)
+	if err != nil || artifact == nil {
+		return err
+	}
+	return a.CheckVersionDelete(ctx, artifact.OrganizationID, artifact.PublishedAt)
+}
+
+// CheckOverwrite implements storage.ImmutabilityGuard: a file that belongs to
+// a published version of an immutable organization can never be replaced.
+func (a *ArtifactImmutability) CheckOverwrite(ctx context.Context, path string) error {
+	if a == nil {
+		return nil
+	}
+	if ok, err := a.mayApply(ctx); err != nil || !ok {
+		return err
+	}
+	artifact, err := a.store.FindArtifactByStorageKey(ctx, path)
+	if err != nil || artifact == nil {
+		return err
+	}
+	eff, err := a.Effective(ctx, artifact.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if eff.Enabled {
+		return fmt.Errorf("%w: published files cannot be replaced", storage.ErrArtifactImmutable)
+	}
+	return nil
+}
+
+// RecordPublish appends a publish to the publish log when immutability
+// applies to the entry's organization (an empty OrganizationID is stored as
+// none). PublishedAt defaults to now. Failures are logged, not returned: the
+// version is already stored and recorded, and a gap shows up as a missing
+// entry rather than a broken chain.
+func (a *ArtifactImmutability) RecordPublish(ctx context.Context, e *models.PublishLogEntry) {
+	if a == nil {
+		return
+	}
+	var orgID string
+	if e.OrganizationID != nil {
+		orgID = *e.OrganizationID
+	}
+	if orgID == "" {
+		e.OrganizationID = nil
+	}
+	eff, err := a.Effective(ctx, orgID)
+	if err != nil {
+		slog.Error("artifact immutability: failed to load settings; publish not logged",
+			"namespace", e.Namespace, "name", e.Name, "version", e.Version, "error", err)
+		return
+	}
+	if !eff.Enabled {
+		return
+	}
+	if e.PublishedAt.IsZero() {
+		e.PublishedAt = a.now()
+	}
+	if err := a.store.AppendPublishLog(ctx, e); err != nil {
+		slog.Error("artifact immutability: failed to append publish log entry",
+			"namespace", e.Namespace, "name", e.Name, "version", e.Version, "error", err)
+	}
+}
+
+// VerifyPublishLog validates the whole publish log chain.
+func (a *ArtifactImmutability) VerifyPublishLog(ctx context.Context) (*models.PublishLogIntegrity, error) {
+	res := models.NewPublishLogIntegrity()
+	if a == nil {
+		return res, nil
+	}
+	var afterID int64
+	for {
+		entries, err := a.store.ListPublishLog(ctx, afterID, publishLogVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+		if !res.Extend(entries) || len(entries) < publishLogVerifyBatch {
+			return res, nil
+		}
+		afterID = entries[len(entries)-1].ID
+	}
+}
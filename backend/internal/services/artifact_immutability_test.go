@@ -0,0 +1,182 @@
+// artifact_immutability_test.go tests ArtifactImmutability with a fake store.
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+type fakeImmutabilityStore struct {
+	orgs      map[string]*models.OrgArtifactImmutability
+	artifacts map[string]*repositories.StoredArtifact
+	log       []*models.PublishLogEntry
+
+	lookups int
+}
+
+func (f *fakeImmutabilityStore) GetOrgSetting(_ context.Context, orgID string) (*models.OrgArtifactImmutability, error) {
+	return f.orgs[orgID], nil
+}
+
+func (f *fakeImmutabilityStore) AnyOrgEnabled(_ context.Context) (bool, error) {
+	for _, s := range f.orgs {
+		if s.Enabled {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeImmutabilityStore) FindArtifactByStorageKey(_ context.Context, key string) (*repositories.StoredArtifact, error) {
+	f.lookups++
+	return f.artifacts[key], nil
+}
+
+func (f *fakeImmutabilityStore) AppendPublishLog(_ context.Context, e *models.PublishLogEntry) error {
+	e.PrevHash = models.PublishLogGenesisHash
+	if n := len(f.log); n > 0 {
+		e.PrevHash = f.log[n-1].EntryHash
+	}
+	e.ID = int64(len(f.log) + 1)
+	e.EntryHash = e.ComputeHash()
+	f.log = append(f.log, e)
+	return nil
+}
+
+func (f *fakeImmutabilityStore) ListPublishLog(_ context.Context, afterID int64, limit int) ([]*models.PublishLogEntry, error) {
+	var out []*models.PublishLogEntry
+	for _, e := range f.log {
+		if e.ID > afterID && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+var immutabilityNow = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestImmutability(cfg config.ImmutableArtifactsConfig, store *fakeImmutabilityStore) *ArtifactImmutability {
+	return &ArtifactImmutability{cfg: &cfg, store: store, now: func() time.Time { return immutabilityNow }}
+}
+
+func TestArtifactImmutability_CheckVersionDelete(t *testing.T) {
+	store := &fakeImmutabilityStore{orgs: map[string]*models.OrgArtifactImmutability{
+		"worm": {OrganizationID: "worm", Enabled: true},
+	}}
+	a := newTestImmutability(config.ImmutableArtifactsConfig{Enabled: true, RetentionDays: 30}, store)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		orgID     string
+		published time.Time
+		wantErr   bool
+	}{
+		{"within global retention", "", immutabilityNow.AddDate(0, 0, -10), true},
+		{"past global retention", "", immutabilityNow.AddDate(0, 0, -31), false},
+		{"org never deletable", "worm", immutabilityNow.AddDate(-5, 0, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.CheckVersionDelete(ctx, tt.orgID, tt.published)
+			if tt.wantErr != errors.Is(err, storage.ErrArtifactImmutable) {
+				t.Errorf("CheckVersionDelete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var disabled *ArtifactImmutability
+	if err := disabled.CheckVersionDelete(ctx, "worm", immutabilityNow); err != nil {
+		t.Errorf("nil ArtifactImmutability should allow deletes, got %v", err)
+	}
+}
+
+func TestArtifactImmutability_StorageGuard(t *testing.T) {
+	store := &fakeImmutabilityStore{
+		orgs: map[string]*models.OrgArtifactImmutability{"worm": {OrganizationID: "worm", Enabled: true, RetentionDays: 30}},
+		artifacts: map[string]*repositories.StoredArtifact{
+			"recent": {OrganizationID: "worm", PublishedAt: immutabilityNow.AddDate(0, 0, -1)},
+			"old":    {OrganizationID: "worm", PublishedAt: immutabilityNow.AddDate(0, 0, -60)},
+			"other":  {OrganizationID: "free", PublishedAt: immutabilityNow},
+		},
+	}
+	a := newTestImmutability(config.ImmutableArtifactsConfig{}, store)
+	ctx := context.Background()
+
+	if err := a.CheckDelete(ctx, "recent"); !errors.Is(err, storage.ErrArtifactImmutable) {
+		t.Errorf("CheckDelete(recent) = %v, want ErrArtifactImmutable", err)
+	}
+	for _, key := range []string{"old", "other", "unowned"} {
+		if err := a.CheckDelete(ctx, key); err != nil {
+			t.Errorf("CheckDelete(%s) = %v, want nil", key, err)
+		}
+	}
+	// Past retention a version may be deleted, but its archive is still never replaced.
+	if err := a.CheckOverwrite(ctx, "old"); !errors.Is(err, storage.ErrArtifactImmutable) {
+		t.Errorf("CheckOverwrite(old) = %v, want ErrArtifactImmutable", err)
+	}
+	if err := a.CheckOverwrite(ctx, "other"); err != nil {
+		t.Errorf("CheckOverwrite(other) = %v, want nil", err)
+	}
+}
+
+func TestArtifactImmutability_StorageGuardSkipsLookupWhenUnused(t *testing.T) {
+	store := &fakeImmutabilityStore{orgs: map[string]*models.OrgArtifactImmutability{
+		"off": {OrganizationID: "off", Enabled: false},
+	}}
+	a := newTestImmutability(config.ImmutableArtifactsConfig{}, store)
+
+	if err := a.CheckDelete(context.Background(), "any"); err != nil {
+		t.Fatalf("CheckDelete = %v", err)
+	}
+	if err := a.CheckOverwrite(context.Background(), "any"); err != nil {
+		t.Fatalf("CheckOverwrite = %v", err)
+	}
+	if store.lookups != 0 {
+		t.Errorf("storage key lookups = %d, want 0 when immutability is disabled everywhere", store.lookups)
+	}
+}
+
+func TestArtifactImmutability_RecordPublishAndVerify(t *testing.T) {
+	store := &fakeImmutabilityStore{orgs: map[string]*models.OrgArtifactImmutability{
+		"worm": {OrganizationID: "worm", Enabled: true},
+	}}
+	a := newTestImmutability(config.ImmutableArtifactsConfig{}, store)
+	ctx := context.Background()
+
+	worm, free := "worm", "free"
+	a.RecordPublish(ctx, &models.PublishLogEntry{ArtifactType: models.PublishLogArtifactModule, OrganizationID: &free, Version: "1.0.0"})
+	a.RecordPublish(ctx, &models.PublishLogEntry{ArtifactType: models.PublishLogArtifactModule, OrganizationID: &worm, Version: "1.0.0"})
+	a.RecordPublish(ctx, &models.PublishLogEntry{ArtifactType: models.PublishLogArtifactProvider, OrganizationID: &worm, Version: "2.0.0"})
+
+	if len(store.log) != 2 {
+		t.Fatalf("publish log has %d entries, want 2 (only the immutable organization's)", len(store.log))
+	}
+	if !store.log[0].PublishedAt.Equal(immutabilityNow) {
+		t.Errorf("PublishedAt = %v, want now", store.log[0].PublishedAt)
+	}
+
+	res, err := a.VerifyPublishLog(ctx)
+	if err != nil {
+		t.Fatalf("VerifyPublishLog: %v", err)
+	}
+	if !res.Valid || res.EntriesChecked != 2 || res.HeadHash != store.log[1].EntryHash {
+		t.Errorf("VerifyPublishLog = %+v", res)
+	}
+
+	store.log[0].Version = "1.0.1"
+	res, err = a.VerifyPublishLog(ctx)
+	if err != nil {
+		t.Fatalf("VerifyPublishLog: %v", err)
+	}
+	if res.Valid || res.FirstInvalidID == nil || *res.FirstInvalidID != 1 {
+		t.Errorf("tampered log: VerifyPublishLog = %+v", res)
+	}
+}
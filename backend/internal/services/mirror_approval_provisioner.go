@@ -0,0 +1,180 @@
+// mirror_approval_provisioner.go applies reviewed mirror approval requests to
+// their mirror configuration. Approving a request with auto_apply set adds the
+// requested namespace and provider to the mirror's filters (in the same
+// transaction as the status change), records which entries it added, and
+// starts a sync of the mirror. Revoking an approval removes the entries it
+// added. Both are audited. Requests without auto_apply, and rejections, only
+// change the request's status.
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// ErrApprovalNotFound is returned for an unknown approval request.
+var ErrApprovalNotFound = errors.New("approval request not found")
+
+// mirrorApprovalStore is the subset of RBACRepository
+// MirrorApprovalProvisioner needs.
+type mirrorApprovalStore interface {
+	GetApprovalRequest(ctx context.Context, id uuid.UUID) (*models.MirrorApprovalRequest, error)
+	UpdateApprovalStatus(ctx context.Context, id uuid.UUID, status models.ApprovalStatus, reviewedBy uuid.UUID, notes string) error
+	ApproveAndApplyFilters(ctx context.Context, id uuid.UUID, reviewedBy uuid.UUID, notes string) (*repositories.ApprovalFilterChange, error)
+	RevokeApproval(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID, notes string) (*repositories.ApprovalFilterChange, error)
+}
+
+// MirrorSyncTrigger starts a sync of one mirror. *jobs.MirrorSyncJob
+// satisfies it.
+type MirrorSyncTrigger interface {
+	TriggerManualSync(ctx context.Context, mirrorID uuid.UUID) error
+}
+
+// MirrorApprovalResult is the outcome of reviewing or revoking an approval
+// request. Change is nil when the mirror's filters were not touched.
+type MirrorApprovalResult struct {
+	Approval      *models.MirrorApprovalRequest
+	Change        *repositories.ApprovalFilterChange
+	SyncTriggered bool
+	SyncError     string
+}
+
+// MirrorApprovalProvisioner reviews and revokes mirror approval requests.
+type MirrorApprovalProvisioner struct {
+	store mirrorApprovalStore
+	sync  MirrorSyncTrigger // optional: no sync is started when nil
+	audit auditLogWriter    // optional
+}
+
+// NewMirrorApprovalProvisioner creates a MirrorApprovalProvisioner. auditRepo
+// may be nil.
+func NewMirrorApprovalProvisioner(rbacRepo *repositories.RBACRepository, auditRepo *repositories.AuditRepository) *MirrorApprovalProvisioner {
+	p := &MirrorApprovalProvisioner{store: rbacRepo}
+	if auditRepo != nil {
+		p.audit = auditRepo
+	}
+	return p
+}
+
+// WithSync sets the sync started after an auto-applied approval.
+func (p *MirrorApprovalProvisioner) WithSync(sync MirrorSyncTrigger) *MirrorApprovalProvisioner {
+	p.sync = sync
+	return p
+}
+
+// Review sets request id to status (approved or rejected). An approval of an
+// auto_apply request also updates the mirror's filters and starts a sync; a
+// sync that cannot start is reported in the result, not as an error, since
+// the approval itself succeeded and the next scheduled sync picks it up.
+// reviewer is uuid.Nil for approvals redeemed without a user.
+func (p *MirrorApprovalProvisioner) Review(ctx context.Context, id uuid.UUID, status models.ApprovalStatus, reviewer uuid.UUID, notes string) (*MirrorApprovalResult, error) {
+	approval, err := p.store.GetApprovalRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval == nil {
+		return nil, ErrApprovalNotFound
+	}
+
+	result := &MirrorApprovalResult{}
+	if status == models.ApprovalStatusApproved && approval.AutoApply {
+		change, err := p.store.ApproveAndApplyFilters(ctx, id, reviewer, notes)
+		if err != nil {
+			return nil, err
+		}
+		if change == nil {
+			return nil, ErrApprovalNotFound
+		}
+		result.Change = change
+		if p.sync != nil {
+			if err := p.sync.TriggerManualSync(ctx, approval.MirrorConfigID); err != nil {
+				slog.Warn("mirror approval: failed to start sync",
+					"approval_id", id, "mirror_id", approval.MirrorConfigID, "error", err)
+				result.SyncError = err.Error()
+			} else {
+				result.SyncTriggered = true
+			}
+		}
+		p.writeAudit(ctx, reviewer, "mirror_approval.applied", approval, change, map[string]interface{}{
+			"sync_triggered": result.SyncTriggered,
+			"sync_error":     result.SyncError,
+		})
+	} else if err := p.store.UpdateApprovalStatus(ctx, id, status, reviewer, notes); err != nil {
+		return nil, err
+	}
+
+	if result.Approval, err = p.store.GetApprovalRequest(ctx, id); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Revoke revokes approved request id and removes the filter entries its
+// approval added, unless another approved request for the mirror still asks
+// for them. It returns repositories.ErrApprovalNotApproved for a request that
+// is not approved.
+func (p *MirrorApprovalProvisioner) Revoke(ctx context.Context, id uuid.UUID, actor uuid.UUID, notes string) (*MirrorApprovalResult, error) {
+	approval, err := p.store.GetApprovalRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval == nil {
+		return nil, ErrApprovalNotFound
+	}
+	change, err := p.store.RevokeApproval(ctx, id, actor, notes)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, ErrApprovalNotFound
+	}
+	p.writeAudit(ctx, actor, "mirror_approval.revoked", approval, change, nil)
+
+	result := &MirrorApprovalResult{Change: change}
+	if result.Approval, err = p.store.GetApprovalRequest(ctx, id); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// writeAudit records action on approval, with the filter entries changed.
+func (p *MirrorApprovalProvisioner) writeAudit(ctx context.Context, actor uuid.UUID, action string, approval *models.MirrorApprovalRequest, change *repositories.ApprovalFilterChange, extra map[string]interface{}) {
+	if p.audit == nil {
+		return
+	}
+	metadata := map[string]interface{}{
+		"mirror_config_id":   approval.MirrorConfigID.String(),
+		"provider_namespace": approval.ProviderNamespace,
+		"provider_name":      approval.ProviderName,
+		"filter_namespace":   change.Namespace,
+		"filter_provider":    change.Provider,
+	}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+	resourceType := "mirror_approval"
+	resourceID := approval.ID.String()
+	entry := &models.AuditLog{
+		Action:       action,
+		ResourceType: &resourceType,
+		ResourceID:   &resourceID,
+		Metadata:     metadata,
+	}
+	if actor != uuid.Nil {
+		userID := actor.String()
+		entry.UserID = &userID
+	}
+	if approval.OrganizationID != nil {
+		orgID := approval.OrganizationID.String()
+		entry.OrganizationID = &orgID
+	}
+	if err := p.audit.CreateAuditLog(ctx, entry); err != nil {
+		slog.Warn("mirror approval: failed to write audit log", "approval_id", approval.ID, "action", action, "error", err)
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+type fakeApprovalStore struct {
+	approval     *models.MirrorApprovalRequest
+	change       *repositories.ApprovalFilterChange
+	revokeErr    error
+	statusCalls  int
+	applyCalls   int
+	revokeCalls  int
+	lastStatus   models.ApprovalStatus
+	lastReviewer uuid.UUID
+}
+
+func (f *fakeApprovalStore) GetApprovalRequest(_ context.Context, _ uuid.UUID) (*models.MirrorApprovalRequest, error) {
+	return f.approval, nil
+}
+
+func (f *fakeApprovalStore) UpdateApprovalStatus(_ context.Context, _ uuid.UUID, status models.ApprovalStatus, reviewedBy uuid.UUID, _ string) error {
+	f.statusCalls++
+	f.lastStatus = status
+	f.lastReviewer = reviewedBy
+	return nil
+}
+
+func (f *fakeApprovalStore) ApproveAndApplyFilters(_ context.Context, _ uuid.UUID, reviewedBy uuid.UUID, _ string) (*repositories.ApprovalFilterChange, error) {
+	f.applyCalls++
+	f.lastReviewer = reviewedBy
+	return f.change, nil
+}
+
+func (f *fakeApprovalStore) RevokeApproval(_ context.Context, _ uuid.UUID, _ uuid.UUID, _ string) (*repositories.ApprovalFilterChange, error) {
+	f.revokeCalls++
+	if f.revokeErr != nil {
+		return nil, f.revokeErr
+	}
+	return f.change, nil
+}
+
+type fakeSyncTrigger struct {
+	mirrors []uuid.UUID
+	err     error
+}
+
+func (f *fakeSyncTrigger) TriggerManualSync(_ context.Context, mirrorID uuid.UUID) error {
+	f.mirrors = append(f.mirrors, mirrorID)
+	return f.err
+}
+
+func newApproval(autoApply bool) *models.MirrorApprovalRequest {
+	name := "aws"
+	return &models.MirrorApprovalRequest{
+		ID:                uuid.New(),
+		MirrorConfigID:    uuid.New(),
+		ProviderNamespace: "hashicorp",
+		ProviderName:      &name,
+		Status:            models.ApprovalStatusPending,
+		AutoApply:         autoApply,
+	}
+}
+
+func TestMirrorApprovalProvisioner_ReviewAutoApply(t *testing.T) {
+	ns := "hashicorp"
+	store := &fakeApprovalStore{approval: newApproval(true), change: &repositories.ApprovalFilterChange{Namespace: &ns}}
+	sync := &fakeSyncTrigger{}
+	audit := &fakeAuditLogWriter{}
+	p := &MirrorApprovalProvisioner{store: store, sync: sync, audit: audit}
+
+	reviewer := uuid.New()
+	result, err := p.Review(context.Background(), store.approval.ID, models.ApprovalStatusApproved, reviewer, "ok")
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if store.applyCalls != 1 || store.statusCalls != 0 {
+		t.Errorf("apply calls = %d, status calls = %d; want 1, 0", store.applyCalls, store.statusCalls)
+	}
+	if !result.SyncTriggered || len(sync.mirrors) != 1 || sync.mirrors[0] != store.approval.MirrorConfigID {
+		t.Errorf("sync not triggered for mirror: triggered=%v mirrors=%v", result.SyncTriggered, sync.mirrors)
+	}
+	if result.Change == nil || result.Change.Namespace == nil || *result.Change.Namespace != "hashicorp" {
+		t.Errorf("change = %+v, want namespace hashicorp", result.Change)
+	}
+	if len(audit.logs) != 1 || audit.logs[0].Action != "mirror_approval.applied" {
+		t.Fatalf("audit logs = %+v, want one mirror_approval.applied", audit.logs)
+	}
+	if audit.logs[0].UserID == nil || *audit.logs[0].UserID != reviewer.String() {
+		t.Errorf("audit user = %v, want %s", audit.logs[0].UserID, reviewer)
+	}
+}
+
+func TestMirrorApprovalProvisioner_ReviewSyncFailureIsReported(t *testing.T) {
+	store := &fakeApprovalStore{approval: newApproval(true), change: &repositories.ApprovalFilterChange{}}
+	sync := &fakeSyncTrigger{err: errors.New("sync already in progress")}
+	p := &MirrorApprovalProvisioner{store: store, sync: sync}
+
+	result, err := p.Review(context.Background(), store.approval.ID, models.ApprovalStatusApproved, uuid.New(), "")
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if result.SyncTriggered || result.SyncError != "sync already in progress" {
+		t.Errorf("triggered=%v error=%q, want false and the sync error", result.SyncTriggered, result.SyncError)
+	}
+}
+
+func TestMirrorApprovalProvisioner_ReviewWithoutAutoApply(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		autoApply bool
+		status    models.ApprovalStatus
+	}{
+		{"approve without auto_apply", false, models.ApprovalStatusApproved},
+		{"reject with auto_apply", true, models.ApprovalStatusRejected},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &fakeApprovalStore{approval: newApproval(tc.autoApply)}
+			sync := &fakeSyncTrigger{}
+			audit := &fakeAuditLogWriter{}
+			p := &MirrorApprovalProvisioner{store: store, sync: sync, audit: audit}
+
+			result, err := p.Review(context.Background(), store.approval.ID, tc.status, uuid.New(), "")
+			if err != nil {
+				t.Fatalf("Review: %v", err)
+			}
+			if store.statusCalls != 1 || store.applyCalls != 0 || store.lastStatus != tc.status {
+				t.Errorf("status calls = %d (%s), apply calls = %d", store.statusCalls, store.lastStatus, store.applyCalls)
+			}
+			if result.Change != nil || len(sync.mirrors) != 0 || len(audit.logs) != 0 {
+				t.Errorf("unexpected side effects: change=%+v syncs=%d audits=%d", result.Change, len(sync.mirrors), len(audit.logs))
+			}
+		})
+	}
+}
+
+func TestMirrorApprovalProvisioner_ReviewNotFound(t *testing.T) {
+	p := &MirrorApprovalProvisioner{store: &fakeApprovalStore{}}
+	if _, err := p.Review(context.Background(), uuid.New(), models.ApprovalStatusApproved, uuid.New(), ""); !errors.Is(err, ErrApprovalNotFound) {
+		t.Fatalf("err = %v, want ErrApprovalNotFound", err)
+	}
+}
+
+func TestMirrorApprovalProvisioner_Revoke(t *testing.T) {
+	provider := "aws"
+	approval := newApproval(true)
+	approval.Status = models.ApprovalStatusApproved
+	store := &fakeApprovalStore{approval: approval, change: &repositories.ApprovalFilterChange{Provider: &provider}}
+	audit := &fakeAuditLogWriter{}
+	p := &MirrorApprovalProvisioner{store: store, audit: audit}
+
+	result, err := p.Revoke(context.Background(), approval.ID, uuid.New(), "done")
+	if err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if result.Change == nil || result.Change.Provider == nil || *result.Change.Provider != "aws" {
+		t.Errorf("change = %+v, want provider aws", result.Change)
+	}
+	if len(audit.logs) != 1 || audit.logs[0].Action != "mirror_approval.revoked" {
+		t.Fatalf("audit logs = %+v, want one mirror_approval.revoked", audit.logs)
+	}
+	if got := audit.logs[0].Metadata["filter_provider"]; got != &provider {
+		t.Errorf("audit filter_provider = %v, want the removed provider", got)
+	}
+}
+
+func TestMirrorApprovalProvisioner_RevokeNotApproved(t *testing.T) {
+	store := &fakeApprovalStore{approval: newApproval(true), revokeErr: repositories.ErrApprovalNotApproved}
+	audit := &fakeAuditLogWriter{}
+	p := &MirrorApprovalProvisioner{store: store, audit: audit}
+
+	if _, err := p.Revoke(context.Background(), store.approval.ID, uuid.New(), ""); !errors.Is(err, repositories.ErrApprovalNotApproved) {
+		t.Fatalf("err = %v, want ErrApprovalNotApproved", err)
+	}
+	if len(audit.logs) != 0 {
+		t.Errorf("audit logs = %d, want 0", len(audit.logs))
+	}
+}
@@ -0,0 +1,202 @@
+// mirror_blob_dedup.go moves mirrored platform archives written before
+// content-addressed storage onto blobs (see mirror_blobs.go). Each archive is
+// hashed from storage and checked against the checksum recorded when it was
+// synced: archives stored under per-namespace keys may have been overwritten
+// by another organization's sync of the same provider, so an object is only
+// trusted when its content is what the platform row says it is.
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// MirrorBlobDedupFailure is a platform archive the dedup run could not move.
+type MirrorBlobDedupFailure struct {
+	PlatformID  string `json:"platform_id"`
+	StoragePath string `json:"storage_path"`
+	Error       string `json:"error"`
+}
+
+// MirrorBlobDedupReport summarises a dedup run. On a dry run the counts are
+// what an applied run would do, and BytesReclaimed is an estimate.
+type MirrorBlobDedupReport struct {
+	DryRun         bool                     `json:"dry_run"`
+	Platforms      int                      `json:"platforms"`    // mirrored platforms not yet backed by a blob
+	Deduplicated   int                      `json:"deduplicated"` // platforms moved onto a blob
+	Blobs          int                      `json:"blobs"`        // distinct blobs those platforms share
+	Failed         int                      `json:"failed"`
+	ObjectsDeleted int                      `json:"objects_deleted"` // old per-platform objects removed
+	OrphansPruned  int                      `json:"orphans_pruned"`  // blobs no platform references
+	BytesReclaimed int64                    `json:"bytes_reclaimed"`
+	Failures       []MirrorBlobDedupFailure `json:"failures,omitempty"`
+}
+
+// dedupGroup is the candidates whose archives have the same content.
+type dedupGroup struct {
+	size      int64
+	source    string // an object holding the content
+	platforms []models.MirrorBlobCandidate
+	paths     map[string]int64
+}
+
+// Dedup plans, and with apply performs, moving every mirrored platform archive
+// not yet backed by a blob onto one, then deleting the old objects nothing
+// references any more and any blob left without references.
+func (m *MirrorBlobs) Dedup(ctx context.Context, apply bool) (*MirrorBlobDedupReport, error) {
+	candidates, err := m.blobs.ListDedupCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &MirrorBlobDedupReport{DryRun: !apply, Platforms: len(candidates)}
+	fail := func(c models.MirrorBlobCandidate, err error) {
+		report.Failed++
+		report.Failures = append(report.Failures, MirrorBlobDedupFailure{PlatformID: c.PlatformID, StoragePath: c.StoragePath, Error: err.Error()})
+	}
+
+	// Candidates are listed by storage path, and platforms sharing a path
+	// share one object: hash each object once.
+	groups := make(map[string]*dedupGroup)
+	for i := 0; i < len(candidates); {
+		path := candidates[i].StoragePath
+		j := i
+		for j < len(candidates) && candidates[j].StoragePath == path {
+			j++
+		}
+		digest, size, err := m.hashObject(ctx, path)
+		for _, c := range candidates[i:j] {
+			switch {
+			case err != nil:
+				fail(c, err)
+			case c.Shasum != "" && !strings.EqualFold(c.Shasum, digest):
+				fail(c, fmt.Errorf("content of %s does not match the recorded checksum (got %s, want %s); re-sync this version", path, digest, c.Shasum))
+			default:
+				g := groups[digest]
+				if g == nil {
+					g = &dedupGroup{size: size, source: path, paths: make(map[string]int64)}
+					groups[digest] = g
+				}
+				g.platforms = append(g.platforms, c)
+				g.paths[path] = size
+			}
+		}
+		i = j
+	}
+
+	digests := make([]string, 0, len(groups))
+	for d := range groups {
+		digests = append(digests, d)
+	}
+	sort.Strings(digests)
+	report.Blobs = len(digests)
+
+	for _, digest := range digests {
+		g := groups[digest]
+		key := storage.BlobKey(digest)
+		if !apply {
+			report.Deduplicated += len(g.platforms)
+			for path, size := range g.paths {
+				if path != key {
+					report.ObjectsDeleted++
+					report.BytesReclaimed += size
+				}
+			}
+			if existing, err := m.blobs.GetBlob(ctx, digest); err != nil {
+				return report, err
+			} else if existing == nil {
+				report.BytesReclaimed -= g.size
+			}
+			continue
+		}
+
+		uploaded, err := m.storeFrom(ctx, digest, g.source, g.size)
+		if err != nil {
+			for _, c := range g.platforms {
+				fail(c, err)
+			}
+			continue
+		}
+		for _, c := range g.platforms {
+			if err := m.blobs.RebindPlatform(ctx, c.PlatformID, digest); err != nil {
+				fail(c, err)
+				continue
+			}
+			report.Deduplicated++
+		}
+		for path, size := range g.paths {
+			if path == key {
+				continue
+			}
+			// Failed platforms, and any other row, still point here.
+			if n, err := m.blobs.CountPlatformsByStoragePath(ctx, path); err != nil || n > 0 {
+				continue
+			}
+			if err := m.storageBackend.Delete(ctx, path); err != nil {
+				return report, fmt.Errorf("failed to delete old object %s: %w", path, err)
+			}
+			report.ObjectsDeleted++
+			report.BytesReclaimed += size
+		}
+		if uploaded {
+			report.BytesReclaimed -= g.size
+		}
+	}
+
+	orphans, err := m.blobs.ListUnreferencedBlobs(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, b := range orphans {
+		if !apply {
+			report.OrphansPruned++
+			report.BytesReclaimed += b.SizeBytes
+			continue
+		}
+		deleted, err := m.blobs.DeleteIfUnreferenced(ctx, b.Digest, func(storagePath string) error {
+			return m.storageBackend.Delete(ctx, storagePath)
+		})
+		if err != nil {
+			return report, fmt.Errorf("failed to prune blob %s: %w", b.Digest, err)
+		}
+		if deleted {
+			report.OrphansPruned++
+			report.BytesReclaimed += b.SizeBytes
+		}
+	}
+	return report, nil
+}
+
+// hashObject returns the SHA-256 and size of the object at path.
+func (m *MirrorBlobs) hashObject(ctx context.Context, path string) (string, int64, error) {
+	reader, err := m.storageBackend.Download(ctx, path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer reader.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// storeFrom stores the object at source as the blob digest, reporting whether
+// it had to be uploaded.
+func (m *MirrorBlobs) storeFrom(ctx context.Context, digest, source string, size int64) (bool, error) {
+	reader, err := m.storageBackend.Download(ctx, source)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	defer reader.Close()
+	_, uploaded, err := m.store(ctx, digest, reader, size)
+	return uploaded, err
+}
@@ -0,0 +1,108 @@
+// mirror_blobs.go stores mirrored provider platform archives content-addressed
+// (see storage.BlobKey), so an upstream archive synced by several mirror
+// configurations, or into several organizations, is stored once. Platform rows
+// keep their storage_path pointing at the blob key, so downloads resolve as
+// before; mirror_blob_refs records which platforms share each blob, and a blob
+// is deleted only when its last reference is released.
+//
+// This is a keying strategy above storage.Storage: every backend stores blobs
+// like any other object.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// mirrorBlobStore is the subset of MirrorBlobRepository MirrorBlobs uses.
+type mirrorBlobStore interface {
+	EnsureBlob(ctx context.Context, blob *models.MirrorBlob) (bool, error)
+	GetBlob(ctx context.Context, digest string) (*models.MirrorBlob, error)
+	AddRef(ctx context.Context, platformID, digest string) error
+	RebindPlatform(ctx context.Context, platformID, digest string) error
+	ReleaseRef(ctx context.Context, platformID string, onLast func(storagePath string) error) (bool, error)
+	DeleteIfUnreferenced(ctx context.Context, digest string, onDelete func(storagePath string) error) (bool, error)
+	ListUnreferencedBlobs(ctx context.Context) ([]*models.MirrorBlob, error)
+	ListDedupCandidates(ctx context.Context) ([]models.MirrorBlobCandidate, error)
+	CountPlatformsByStoragePath(ctx context.Context, storagePath string) (int, error)
+}
+
+// MirrorBlobs stores and reference-counts mirrored platform archives.
+type MirrorBlobs struct {
+	blobs          mirrorBlobStore
+	storageBackend storage.Storage
+}
+
+// NewMirrorBlobs creates a MirrorBlobs.
+func NewMirrorBlobs(repo *repositories.MirrorBlobRepository, storageBackend storage.Storage) *MirrorBlobs {
+	return &MirrorBlobs{blobs: repo, storageBackend: storageBackend}
+}
+
+// Store makes content, whose SHA-256 is digest, available under its blob key
+// and returns the key. Content already stored is not uploaded again; the blob
+// is unreferenced until Attach.
+func (m *MirrorBlobs) Store(ctx context.Context, digest string, content io.Reader, size int64) (string, error) {
+	key, _, err := m.store(ctx, digest, content, size)
+	return key, err
+}
+
+// store is Store, also reporting whether content had to be uploaded.
+func (m *MirrorBlobs) store(ctx context.Context, digest string, content io.Reader, size int64) (string, bool, error) {
+	digest = strings.ToLower(digest)
+	key := storage.BlobKey(digest)
+	created, err := m.blobs.EnsureBlob(ctx, &models.MirrorBlob{Digest: digest, StoragePath: key, SizeBytes: size})
+	if err != nil {
+		return "", false, err
+	}
+	if !created {
+		// The row may outlive a failed upload; only trust it when the object
+		// is there.
+		exists, err := m.storageBackend.Exists(ctx, key)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to check blob %s: %w", key, err)
+		}
+		if exists {
+			return key, false, nil
+		}
+	}
+	if _, err := m.storageBackend.Upload(ctx, key, content, size); err != nil {
+		return "", false, fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	return key, true, nil
+}
+
+// Attach records that platformID's archive is the blob digest. If the blob was
+// released and deleted since Store, content is stored again and the reference
+// retried once.
+func (m *MirrorBlobs) Attach(ctx context.Context, platformID, digest string, content io.ReadSeeker, size int64) error {
+	digest = strings.ToLower(digest)
+	err := m.blobs.AddRef(ctx, platformID, digest)
+	if !errors.Is(err, repositories.ErrMirrorBlobNotFound) {
+		return err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind blob content: %w", err)
+	}
+	if _, err := m.Store(ctx, digest, content, size); err != nil {
+		return err
+	}
+	return m.blobs.AddRef(ctx, platformID, digest)
+}
+
+// Release drops platformID's blob reference, deleting the blob's object and
+// row when it was the last one. It reports whether the platform's archive was
+// a blob at all; when it was not, the caller deletes the archive itself. If
+// the object cannot be deleted (for example storage.ErrArtifactImmutable) the
+// reference is kept and the error returned.
+func (m *MirrorBlobs) Release(ctx context.Context, platformID string) (bool, error) {
+	return m.blobs.ReleaseRef(ctx, platformID, func(storagePath string) error {
+		return m.storageBackend.Delete(ctx, storagePath)
+	})
+}
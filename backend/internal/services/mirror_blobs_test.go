@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// fakeMirrorBlobStore keeps blobs, references and platform storage paths in
+// memory.
+type fakeMirrorBlobStore struct {
+	blobs      map[string]*models.MirrorBlob
+	refs       map[string]string // platform ID -> digest
+	paths      map[string]string // platform ID -> storage path
+	candidates []models.MirrorBlobCandidate
+	addRefs    int
+}
+
+func newFakeMirrorBlobStore() *fakeMirrorBlobStore {
+	return &fakeMirrorBlobStore{
+		blobs: map[string]*models.MirrorBlob{},
+		refs:  map[string]string{},
+		paths: map[string]string{},
+	}
+}
+
+func (f *fakeMirrorBlobStore) EnsureBlob(_ context.Context, blob *models.MirrorBlob) (bool, error) {
+	if _, ok := f.blobs[blob.Digest]; ok {
+		return false, nil
+	}
+	b := *blob
+	f.blobs[blob.Digest] = &b
+	return true, nil
+}
+
+func (f *fakeMirrorBlobStore) GetBlob(_ context.Context, digest string) (*models.MirrorBlob, error) {
+	return f.blobs[digest], nil
+}
+
+func (f *fakeMirrorBlobStore) AddRef(_ context.Context, platformID, digest string) error {
+	f.addRefs++
+	if _, ok := f.blobs[digest]; !ok {
+		return repositories.ErrMirrorBlobNotFound
+	}
+	f.refs[platformID] = digest
+	return nil
+}
+
+func (f *fakeMirrorBlobStore) RebindPlatform(ctx context.Context, platformID, digest string) error {
+	if err := f.AddRef(ctx, platformID, digest); err != nil {
+		return err
+	}
+	f.paths[platformID] = f.blobs[digest].StoragePath
+	return nil
+}
+
+func (f *fakeMirrorBlobStore) ReleaseRef(ctx context.Context, platformID string, onLast func(string) error) (bool, error) {
+	digest, ok := f.refs[platformID]
+	if !ok {
+		return false, nil
+	}
+	delete(f.refs, platformID)
+	if _, err := f.DeleteIfUnreferenced(ctx, digest, onLast); err != nil {
+		f.refs[platformID] = digest
+		return false, err
+	}
+	return true, nil
+}
+
+func (f *fakeMirrorBlobStore) DeleteIfUnreferenced(_ context.Context, digest string, onDelete func(string) error) (bool, error) {
+	for _, d := range f.refs {
+		if d == digest {
+			return false, nil
+		}
+	}
+	if err := onDelete(f.blobs[digest].StoragePath); err != nil {
+		return false, err
+	}
+	delete(f.blobs, digest)
+	return true, nil
+}
+
+func (f *fakeMirrorBlobStore) ListUnreferencedBlobs(_ context.Context) ([]*models.MirrorBlob, error) {
+	referenced := map[string]bool{}
+	for _, d := range f.refs {
+		referenced[d] = true
+	}
+	var out []*models.MirrorBlob
+	for d, b := range f.blobs {
+		if !referenced[d] {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeMirrorBlobStore) ListDedupCandidates(_ context.Context) ([]models.MirrorBlobCandidate, error) {
+	sort.Slice(f.candidates, func(i, j int) bool { return f.candidates[i].StoragePath < f.candidates[j].StoragePath })
+	return f.candidates, nil
+}
+
+func (f *fakeMirrorBlobStore) CountPlatformsByStoragePath(_ context.Context, path string) (int, error) {
+	n := 0
+	for _, p := range f.paths {
+		if p == path {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeMirrorBlobStore) addCandidate(id, path, shasum string, size int64) {
+	f.candidates = append(f.candidates, models.MirrorBlobCandidate{PlatformID: id, StoragePath: path, Shasum: shasum, SizeBytes: size})
+	f.paths[id] = path
+}
+
+func newTestMirrorBlobs() (*MirrorBlobs, *fakeMirrorBlobStore, *foldingStorage) {
+	store := newFakeMirrorBlobStore()
+	backend := &foldingStorage{}
+	return &MirrorBlobs{blobs: store, storageBackend: backend}, store, backend
+}
+
+func TestMirrorBlobs_SharedBlobDeletedWithLastRef(t *testing.T) {
+	m, store, backend := newTestMirrorBlobs()
+	ctx := context.Background()
+	content := "provider archive"
+	digest := sha256Hex(content)
+
+	for _, platform := range []string{"plat-1", "plat-2"} {
+		key, err := m.Store(ctx, strings.ToUpper(digest), strings.NewReader(content), int64(len(content)))
+		if err != nil || key != storage.BlobKey(digest) {
+			t.Fatalf("Store = %q, %v", key, err)
+		}
+		if err := m.Attach(ctx, platform, digest, strings.NewReader(content), int64(len(content))); err != nil {
+			t.Fatalf("Attach(%s): %v", platform, err)
+		}
+	}
+	if len(backend.files) != 1 || len(store.blobs) != 1 {
+		t.Fatalf("stored %d objects and %d blobs, want 1 of each", len(backend.files), len(store.blobs))
+	}
+
+	if handled, err := m.Release(ctx, "plat-1"); err != nil || !handled {
+		t.Fatalf("Release(plat-1) = %v, %v", handled, err)
+	}
+	if len(backend.deleted) != 0 {
+		t.Fatalf("blob deleted while still referenced: %v", backend.deleted)
+	}
+	if handled, err := m.Release(ctx, "plat-2"); err != nil || !handled {
+		t.Fatalf("Release(plat-2) = %v, %v", handled, err)
+	}
+	if len(backend.deleted) != 1 || backend.deleted[0] != storage.BlobKey(digest) || len(store.blobs) != 0 {
+		t.Fatalf("deleted = %v, blobs = %v; want the blob removed", backend.deleted, store.blobs)
+	}
+
+	if handled, err := m.Release(ctx, "plat-3"); err != nil || handled {
+		t.Fatalf("Release(unreferenced) = %v, %v; want false, nil", handled, err)
+	}
+}
+
+func TestMirrorBlobs_StoreReuploadsMissingObject(t *testing.T) {
+	m, store, backend := newTestMirrorBlobs()
+	digest := sha256Hex("x")
+	store.blobs[digest] = &models.MirrorBlob{Digest: digest, StoragePath: storage.BlobKey(digest)}
+
+	if _, err := m.Store(context.Background(), digest, strings.NewReader("x"), 1); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if ok, _ := backend.Exists(context.Background(), storage.BlobKey(digest)); !ok {
+		t.Error("object behind an existing blob row was not uploaded")
+	}
+}
+
+func TestMirrorBlobs_AttachRestoresReleasedBlob(t *testing.T) {
+	m, store, backend := newTestMirrorBlobs()
+	digest := sha256Hex("x")
+	// The blob was deleted between Store and Attach.
+	if err := m.Attach(context.Background(), "plat-1", digest, strings.NewReader("x"), 1); err != nil {
+		t.Fatalf("Attach: %v", err)
+	}
+	if store.refs["plat-1"] != digest || store.addRefs != 2 {
+		t.Errorf("refs = %v after %d AddRef calls", store.refs, store.addRefs)
+	}
+	if ok, _ := backend.Exists(context.Background(), storage.BlobKey(digest)); !ok {
+		t.Error("blob was not stored again")
+	}
+}
+
+func TestMirrorBlobs_ReleaseKeepsRefWhenDeleteRefused(t *testing.T) {
+	store := newFakeMirrorBlobStore()
+	digest := sha256Hex("x")
+	store.blobs[digest] = &models.MirrorBlob{Digest: digest, StoragePath: storage.BlobKey(digest)}
+	store.refs["plat-1"] = digest
+	m := &MirrorBlobs{blobs: store, storageBackend: storage.NewImmutableStorage(&foldingStorage{}, refuseAllGuard{})}
+
+	if _, err := m.Release(context.Background(), "plat-1"); !errors.Is(err, storage.ErrArtifactImmutable) {
+		t.Fatalf("Release = %v, want ErrArtifactImmutable", err)
+	}
+	if store.refs["plat-1"] != digest {
+		t.Error("reference dropped although the blob was kept")
+	}
+}
+
+type refuseAllGuard struct{}
+
+func (refuseAllGuard) CheckDelete(context.Context, string) error {
+	return storage.ErrArtifactImmutable
+}
+
+func (refuseAllGuard) CheckOverwrite(context.Context, string) error {
+	return storage.ErrArtifactImmutable
+}
+
+func TestMirrorBlobs_Dedup(t *testing.T) {
+	archive := "terraform-provider-aws_5.0.0_linux_amd64.zip"
+	digest := sha256Hex(archive)
+	size := int64(len(archive))
+
+	setup := func() (*MirrorBlobs, *fakeMirrorBlobStore, *foldingStorage) {
+		m, store, backend := newTestMirrorBlobs()
+		backend.put("providers/acme/hashicorp/aws/5.0.0/linux_amd64.zip", archive)
+		backend.put("providers/beta/hashicorp/aws/5.0.0/linux_amd64.zip", archive)
+		backend.put("providers/gamma/hashicorp/aws/5.0.0/linux_amd64.zip", "overwritten")
+		store.addCandidate("plat-a", "providers/acme/hashicorp/aws/5.0.0/linux_amd64.zip", digest, size)
+		store.addCandidate("plat-b", "providers/beta/hashicorp/aws/5.0.0/linux_amd64.zip", digest, size)
+		store.addCandidate("plat-c", "providers/gamma/hashicorp/aws/5.0.0/linux_amd64.zip", digest, size)
+		orphan := sha256Hex("orphan")
+		store.blobs[orphan] = &models.MirrorBlob{Digest: orphan, StoragePath: storage.BlobKey(orphan), SizeBytes: 6}
+		backend.put(storage.BlobKey(orphan), "orphan")
+		return m, store, backend
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		m, store, backend := setup()
+		report, err := m.Dedup(context.Background(), false)
+		if err != nil {
+			t.Fatalf("Dedup: %v", err)
+		}
+		if !report.DryRun || report.Deduplicated != 2 || report.Failed != 1 || report.ObjectsDeleted != 2 || report.OrphansPruned != 1 {
+			t.Errorf("report = %+v", report)
+		}
+		if report.BytesReclaimed != size+6 {
+			t.Errorf("bytes reclaimed = %d, want %d", report.BytesReclaimed, size+6)
+		}
+		if len(store.refs) != 0 || len(backend.deleted) != 0 {
+			t.Error("dry run changed state")
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		m, store, backend := setup()
+		report, err := m.Dedup(context.Background(), true)
+		if err != nil {
+			t.Fatalf("Dedup: %v", err)
+		}
+		if report.Deduplicated != 2 || report.Blobs != 1 || report.Failed != 1 || report.ObjectsDeleted != 2 || report.OrphansPruned != 1 {
+			t.Errorf("report = %+v", report)
+		}
+		if report.BytesReclaimed != size+6 {
+			t.Errorf("bytes reclaimed = %d, want %d", report.BytesReclaimed, size+6)
+		}
+		if len(report.Failures) != 1 || report.Failures[0].PlatformID != "plat-c" {
+			t.Errorf("failures = %+v, want plat-c", report.Failures)
+		}
+		key := storage.BlobKey(digest)
+		if store.paths["plat-a"] != key || store.paths["plat-b"] != key || store.refs["plat-a"] != digest {
+			t.Errorf("paths = %v, refs = %v", store.paths, store.refs)
+		}
+		if store.paths["plat-c"] == key {
+			t.Error("mismatching archive was rebound")
+		}
+		if ok, _ := backend.Exists(context.Background(), "providers/gamma/hashicorp/aws/5.0.0/linux_amd64.zip"); !ok {
+			t.Error("object of failed platform was deleted")
+		}
+	})
+}
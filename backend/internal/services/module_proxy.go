@@ -0,0 +1,500 @@
+// module_proxy.go implements the module pull-through proxy. A module version
+// requested through /v1/modules/proxy/:hostname/... that is not present locally
+// is fetched from the upstream registry on demand, repackaged as a plain module
+// tarball, stored in the configured storage backend, and recorded in the
+// database under the default organization with a proxied_modules row naming
+// the upstream host. Later requests (through the proxy routes or the ordinary
+// /v1/modules routes) are served from the local copy.
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/terraform-registry/terraform-registry/internal/analyzer"
+	"github.com/terraform-registry/terraform-registry/internal/archiver"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// maxProxiedArchiveBytes caps the upstream archive download; matches the
+// 100 MB module upload limit.
+const maxProxiedArchiveBytes = 100 << 20
+
+// moduleProxyFetchTimeout bounds one upstream fetch-and-store.
+const moduleProxyFetchTimeout = 10 * time.Minute
+
+var (
+	// ErrModuleProxyHostNotAllowed is returned for upstream hosts missing
+	// from module_proxy.allowed_hosts.
+	ErrModuleProxyHostNotAllowed = errors.New("upstream registry host is not allowed for module proxying")
+
+	// ErrModuleProxyConflict is returned when the requested coordinates are
+	// already taken by a locally published module (or one proxied from a
+	// different host). The proxy never writes into such a module.
+	ErrModuleProxyConflict = errors.New("module exists locally and is not proxied from this registry")
+)
+
+// ModuleProxyDeniedError is returned when the mirror policies do not allow
+// proxying the requested module.
+type ModuleProxyDeniedError struct {
+	Reason string
+}
+
+func (e *ModuleProxyDeniedError) Error() string {
+	return "module proxying denied: " + e.Reason
+}
+
+// ModulePolicyEvaluator evaluates the mirror allow/deny policies.
+// *repositories.RBACRepository satisfies it.
+type ModulePolicyEvaluator interface {
+	EvaluatePolicies(ctx context.Context, orgID *uuid.UUID, registry, namespace, provider string) (*models.PolicyEvaluationResult, error)
+}
+
+// ModuleProxyService fetches and caches upstream module versions on demand.
+type ModuleProxyService struct {
+	cfg            config.ModuleProxyConfig
+	moduleRepo     *repositories.ModuleRepository
+	proxyRepo      *repositories.ModuleProxyRepository
+	orgRepo        *repositories.OrganizationRepository
+	policies       ModulePolicyEvaluator
+	storageBackend storage.Storage
+	backendName    string
+	tempDir        string
+	scratch        *scratch.Space
+
+	// newUpstream builds the upstream client for a registry hostname. Tests
+	// override it via SetUpstreamFactory.
+	newUpstream func(hostname string) mirror.UpstreamModuleClient
+	egressGuard *httpsafe.Guard
+
+	// fetches collapses concurrent requests for the same uncached version
+	// into a single upstream download.
+	fetches singleflight.Group
+}
+
+// NewModuleProxyService constructs a ModuleProxyService.
+func NewModuleProxyService(
+	cfg config.ModuleProxyConfig,
+	moduleRepo *repositories.ModuleRepository,
+	proxyRepo *repositories.ModuleProxyRepository,
+	orgRepo *repositories.OrganizationRepository,
+	policies ModulePolicyEvaluator,
+	storageBackend storage.Storage,
+	backendName string,
+) *ModuleProxyService {
+	s := &ModuleProxyService{
+		cfg:            cfg,
+		moduleRepo:     moduleRepo,
+		proxyRepo:      proxyRepo,
+		orgRepo:        orgRepo,
+		policies:       policies,
+		storageBackend: storageBackend,
+		backendName:    backendName,
+		tempDir:        os.TempDir(),
+	}
+	s.newUpstream = func(hostname string) mirror.UpstreamModuleClient {
+		return mirror.NewUpstreamRegistryWithGuard("https://"+hostname, s.egressGuard)
+	}
+	return s
+}
+
+// SetEgressGuard installs the operator-configured egress guard used by the
+// default upstream-client factory. nil keeps the strict default policy.
+func (s *ModuleProxyService) SetEgressGuard(g *httpsafe.Guard) {
+	s.egressGuard = g
+}
+
+// SetScratch stages fetches in the registry's scratch directory and holds
+// each one to its budget; a fetch that would go over it fails with
+// scratch.ErrBudgetExceeded.
+func (s *ModuleProxyService) SetScratch(space *scratch.Space) {
+	s.scratch = space
+	s.tempDir = space.Dir()
+}
+
+// SetUpstreamFactory replaces the upstream-client factory. Intended for tests.
+func (s *ModuleProxyService) SetUpstreamFactory(f func(hostname string) mirror.UpstreamModuleClient) {
+	s.newUpstream = f
+}
+
+// ListVersions returns the versions the upstream registry offers for a
+// module. If the upstream cannot be reached at all, the versions already
+// cached locally are returned instead so terraform init keeps working during
+// an upstream outage; an explicit upstream error status is always relayed.
+func (s *ModuleProxyService) ListVersions(ctx context.Context, hostname, namespace, name, system string) ([]string, error) {
+	org, err := s.checkAllowed(ctx, hostname, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.newUpstream(hostname).ListModuleVersions(ctx, namespace, name, system)
+	if err == nil {
+		return versions, nil
+	}
+	var statusErr *mirror.UpstreamStatusError
+	if errors.As(err, &statusErr) {
+		return nil, err
+	}
+
+	cached, cacheErr := s.cachedVersions(ctx, org.ID, hostname, namespace, name, system)
+	if cacheErr != nil || len(cached) == 0 {
+		return nil, err
+	}
+	slog.Warn("module proxy: upstream unreachable, serving cached versions",
+		"host", hostname, "namespace", namespace, "name", name, "system", system, "error", err)
+	return cached, nil
+}
+
+// Fetch returns the local copy of a module version, downloading and caching it
+// from the upstream registry first when it is not present yet.
+func (s *ModuleProxyService) Fetch(ctx context.Context, hostname, namespace, name, system, version string) (*models.ModuleVersion, error) {
+	org, err := s.checkAllowed(ctx, hostname, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	mv, err := s.localVersion(ctx, org.ID, hostname, namespace, name, system, version)
+	if err != nil || mv != nil {
+		return mv, err
+	}
+
+	key := strings.Join([]string{strings.ToLower(hostname), namespace, name, system, version}, "/")
+	v, err, _ := s.fetches.Do(key, func() (interface{}, error) {
+		// Detach from the first caller's cancellation: other requests may be
+		// waiting on this fetch, and a completed download is worth keeping.
+		fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), moduleProxyFetchTimeout)
+		defer cancel()
+		return s.fetchAndStore(fctx, org.ID, hostname, namespace, name, system, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*models.ModuleVersion), nil
+}
+
+// checkAllowed applies the host allow-list and the mirror policies. Policies
+// are evaluated with the module name in the provider-pattern position.
+func (s *ModuleProxyService) checkAllowed(ctx context.Context, hostname, namespace, name string) (*models.Organization, error) {
+	if !s.cfg.IsAllowedHost(hostname) {
+		return nil, ErrModuleProxyHostNotAllowed
+	}
+
+	org, err := s.orgRepo.GetDefaultOrganization(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get default organization: %w", err)
+	}
+	if org == nil {
+		return nil, errors.New("default organization not found")
+	}
+
+	var orgID *uuid.UUID
+	if id, err := uuid.Parse(org.ID); err == nil {
+		orgID = &id
+	}
+	result, err := s.policies.EvaluatePolicies(ctx, orgID, hostname, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate mirror policies: %w", err)
+	}
+	if !result.Allowed {
+		return nil, &ModuleProxyDeniedError{Reason: result.Reason}
+	}
+	return org, nil
+}
+
+// proxiedModule returns the local module at the coordinates, nil when none
+// exists, or ErrModuleProxyConflict when it was not proxied from hostname.
+func (s *ModuleProxyService) proxiedModule(ctx context.Context, orgID, hostname, namespace, name, system string) (*models.Module, error) {
+	module, err := s.moduleRepo.GetModule(ctx, orgID, namespace, name, system)
+	if err != nil || module == nil {
+		return nil, err
+	}
+	pm, err := s.proxyRepo.Get(ctx, module.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pm == nil || !strings.EqualFold(pm.UpstreamHost, hostname) {
+		return nil, ErrModuleProxyConflict
+	}
+	return module, nil
+}
+
+func (s *ModuleProxyService) localVersion(ctx context.Context, orgID, hostname, namespace, name, system, version string) (*models.ModuleVersion, error) {
+	module, err := s.proxiedModule(ctx, orgID, hostname, namespace, name, system)
+	if err != nil || module == nil {
+		return nil, err
+	}
+	return s.moduleRepo.GetVersion(ctx, module.ID, version)
+}
+
+func (s *ModuleProxyService) cachedVersions(ctx context.Context, orgID, hostname, namespace, name, system string) ([]string, error) {
+	module, err := s.proxiedModule(ctx, orgID, hostname, namespace, name, system)
+	if err != nil || module == nil {
+		return nil, err
+	}
+	versions, err := s.moduleRepo.ListVersions(ctx, module.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(versions))
+	for _, v := range versions {
+		out = append(out, v.Version)
+	}
+	return out, nil
+}
+
+// fetchAndStore downloads a version from upstream, repackages it, uploads it
+// to storage, and records the module and version rows.
+func (s *ModuleProxyService) fetchAndStore(ctx context.Context, orgID, hostname, namespace, name, system, version string) (*models.ModuleVersion, error) {
+	client := s.newUpstream(hostname)
+
+	location, err := client.GetModuleDownloadURL(ctx, namespace, name, system, version)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := mirror.ResolveModuleArchive(location)
+	if err != nil {
+		return nil, err
+	}
+
+	// The download, its extraction and the repackaged archive are each held
+	// to the archive cap.
+	release, err := s.scratch.Reserve(3 * maxProxiedArchiveBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	workDir, err := os.MkdirTemp(s.tempDir, "module-proxy-")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	moduleDir, err := s.downloadAndExtract(ctx, client, archive, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the module's required_version like a local publish does.
+	doc, err := analyzer.AnalyzeDir(moduleDir)
+	if err != nil {
+		slog.Warn("module proxy: failed to analyze module", "namespace", namespace, "name", name, "version", version, "error", err)
+	}
+
+	source := fmt.Sprintf("%s/%s/%s/%s", hostname, namespace, name, system)
+	packagePath := filepath.Join(workDir, "module.tar.gz")
+	checksum, size, err := packageProxiedModule(moduleDir, packagePath, source, version, location)
+	if err != nil {
+		return nil, fmt.Errorf("package module: %w", err)
+	}
+
+	pkg, err := os.Open(packagePath) // #nosec G304 -- path is inside a temp dir created above
+	if err != nil {
+		return nil, fmt.Errorf("open packaged module: %w", err)
+	}
+	defer pkg.Close()
+
+	module := &models.Module{
+		OrganizationID: orgID,
+		Namespace:      namespace,
+		Name:           name,
+		System:         system,
+		Source:         &source,
+	}
+	if err := s.moduleRepo.UpsertModule(ctx, module); err != nil {
+		return nil, err
+	}
+	pm, err := s.proxyRepo.MarkProxied(ctx, module.ID, strings.ToLower(hostname))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(pm.UpstreamHost, hostname) {
+		return nil, ErrModuleProxyConflict
+	}
+
+	// The module row is claimed as proxied before anything is written to
+	// storage, so the proxy can never overwrite a local module's archive.
+	storagePath := storage.ModuleArchiveKey(namespace, name, system, version)
+	uploaded, err := s.storageBackend.Upload(ctx, storagePath, pkg, size)
+	if err != nil {
+		return nil, fmt.Errorf("store module archive: %w", err)
+	}
+
+	mv := &models.ModuleVersion{
+		ModuleID:                 module.ID,
+		Version:                  version,
+		StoragePath:              uploaded.Path,
+		StorageBackend:           s.backendName,
+		SizeBytes:                size,
+		Checksum:                 checksum,
+		RequiredTerraformVersion: doc.RequiredTerraformVersion(),
+	}
+	if err := s.moduleRepo.CreateVersion(ctx, mv); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			// Another replica cached the same version first.
+			return s.moduleRepo.GetVersion(ctx, module.ID, version)
+		}
+		return nil, err
+	}
+	if err := s.proxyRepo.TouchFetched(ctx, module.ID); err != nil {
+		slog.Warn("module proxy: failed to update last_fetched_at", "module_id", module.ID, "error", err)
+	}
+
+	slog.Info("module proxy: cached upstream module version",
+		"source", source, "version", version, "size_bytes", size)
+	return mv, nil
+}
+
+// downloadAndExtract fetches the archive into workDir and returns the
+// directory holding the module.
+func (s *ModuleProxyService) downloadAndExtract(ctx context.Context, client mirror.UpstreamModuleClient, archive *mirror.ModuleArchive, workDir string) (string, error) {
+	stream, err := client.DownloadFileStream(ctx, archive.URL)
+	if err != nil {
+		return "", fmt.Errorf("download module archive: %w", err)
+	}
+	defer stream.Body.Close()
+
+	archivePath := filepath.Join(workDir, "upstream.tar.gz")
+	f, err := os.Create(archivePath) // #nosec G304 -- path is inside a temp dir created by the caller
+	if err != nil {
+		return "", fmt.Errorf("create temp archive: %w", err)
+	}
+	n, err := io.Copy(f, io.LimitReader(stream.Body, maxProxiedArchiveBytes+1))
+	_ = f.Close()
+	if err != nil {
+		return "", fmt.Errorf("download module archive: %w", err)
+	}
+	if n > maxProxiedArchiveBytes {
+		return "", fmt.Errorf("module archive exceeds %d bytes", maxProxiedArchiveBytes)
+	}
+
+	extractDir := filepath.Join(workDir, "src")
+	if err := os.MkdirAll(extractDir, 0750); err != nil {
+		return "", fmt.Errorf("create extract dir: %w", err)
+	}
+	in, err := os.Open(archivePath) // #nosec G304 -- path is inside a temp dir created by the caller
+	if err != nil {
+		return "", fmt.Errorf("open temp archive: %w", err)
+	}
+	defer in.Close()
+	if err := archiver.ExtractTarGz(in, extractDir); err != nil {
+		return "", fmt.Errorf("extract module archive: %w", err)
+	}
+
+	root := extractDir
+	if archive.StripTopDir {
+		entries, err := os.ReadDir(extractDir)
+		if err != nil {
+			return "", fmt.Errorf("read extracted archive: %w", err)
+		}
+		if len(entries) != 1 || !entries[0].IsDir() {
+			return "", errors.New("source archive does not have a single top-level directory")
+		}
+		root = filepath.Join(extractDir, entries[0].Name())
+	}
+	if archive.Subdir != "" {
+		root = filepath.Join(root, filepath.FromSlash(archive.Subdir))
+		info, err := os.Stat(root)
+		if err != nil || !info.IsDir() {
+			return "", fmt.Errorf("module subdirectory %q not found in source archive", archive.Subdir)
+		}
+		return root, nil
+	}
+	if !archive.StripTopDir {
+		root = archiver.FindModuleRoot(root)
+	}
+	return root, nil
+}
+
+// packageProxiedModule writes srcDir as a tar.gz at destPath with a manifest
+// recording where it came from, and returns its sha256 and size.
+func packageProxiedModule(srcDir, destPath, source, version, location string) (string, int64, error) {
+	out, err := os.Create(destPath) // #nosec G304 -- path is inside a temp dir created by the caller
+	if err != nil {
+		return "", 0, err
+	}
+	defer out.Close()
+
+	hasher := sha256.New()
+	gzw := gzip.NewWriter(io.MultiWriter(out, hasher))
+	tw := tar.NewWriter(gzw)
+
+	manifest := fmt.Sprintf("proxied_from: %s\nversion: %s\nupstream_location: %s\nfetched: %s\n",
+		source, version, location, time.Now().UTC().Format(time.RFC3339))
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    ".terraform-registry-proxy",
+		Size:    int64(len(manifest)),
+		Mode:    0644,
+		ModTime: time.Now(),
+	}); err != nil {
+		return "", 0, err
+	}
+	if _, err := tw.Write([]byte(manifest)); err != nil {
+		return "", 0, err
+	}
+
+	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		f, err := os.Open(path) // #nosec G304 G122 -- path comes from walking an extracted temp dir whose entries were containment-checked by archiver.ExtractTarGz
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to flush tar writer: %w", err)
+	}
+	if err := gzw.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to flush gzip writer: %w", err)
+	}
+	info, err := out.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), info.Size(), nil
+}
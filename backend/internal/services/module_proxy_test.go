@@ -0,0 +1,355 @@
+// module_proxy_test.go tests ModuleProxyService with a fake upstream module
+// client, a fake policy evaluator, an in-memory storage backend, and sqlmock
+// for the module, proxied-module, and organization repositories.
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// Fakes
+// ---------------------------------------------------------------------------
+
+type fakeModuleUpstream struct {
+	versions    []string
+	versionsErr error
+	location    string
+	locationErr error
+	archive     []byte
+	downloads   int
+}
+
+func (f *fakeModuleUpstream) ListModuleVersions(_ context.Context, _, _, _ string) ([]string, error) {
+	return f.versions, f.versionsErr
+}
+
+func (f *fakeModuleUpstream) GetModuleDownloadURL(_ context.Context, _, _, _, _ string) (string, error) {
+	return f.location, f.locationErr
+}
+
+func (f *fakeModuleUpstream) DownloadFileStream(_ context.Context, _ string) (*mirror.DownloadStream, error) {
+	f.downloads++
+	return &mirror.DownloadStream{Body: io.NopCloser(bytes.NewReader(f.archive)), ContentLength: int64(len(f.archive))}, nil
+}
+
+type fakePolicyEvaluator struct {
+	result *models.PolicyEvaluationResult
+	err    error
+}
+
+func (f *fakePolicyEvaluator) EvaluatePolicies(_ context.Context, _ *uuid.UUID, _, _, _ string) (*models.PolicyEvaluationResult, error) {
+	return f.result, f.err
+}
+
+type memStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (m *memStorage) Upload(_ context.Context, path string, r io.Reader, _ int64) (*storage.UploadResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.files == nil {
+		m.files = map[string][]byte{}
+	}
+	m.files[path] = data
+	return &storage.UploadResult{Path: path, Size: int64(len(data))}, nil
+}
+func (m *memStorage) Download(_ context.Context, path string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m.files[path])), nil
+}
+func (m *memStorage) Delete(_ context.Context, _ string) error { return nil }
+func (m *memStorage) GetURL(_ context.Context, path string, _ time.Duration) (string, error) {
+	return "https://storage.test/" + path, nil
+}
+func (m *memStorage) Exists(_ context.Context, path string) (bool, error) {
+	_, ok := m.files[path]
+	return ok, nil
+}
+func (m *memStorage) GetMetadata(_ context.Context, path string) (*storage.FileMetadata, error) {
+	return &storage.FileMetadata{Path: path, Size: int64(len(m.files[path]))}, nil
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+var (
+	proxyOrgID    = uuid.New().String()
+	proxyOrgCols  = []string{"id", "name", "display_name", "idp_type", "idp_name", "created_at", "updated_at"}
+	proxyModCols  = []string{"id", "organization_id", "namespace", "name", "system", "description", "source", "created_by", "created_at", "updated_at", "created_by_name", "deprecated", "deprecated_at", "deprecation_message", "successor_module_id"}
+	proxyPMCols   = []string{"module_id", "upstream_host", "created_at", "last_fetched_at"}
+	proxyVerCols  = []string{"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes", "checksum", "readme", "published_by", "download_count", "deprecated", "deprecated_at", "deprecation_message", "replacement_source", "created_at", "commit_sha", "tag_name", "scm_repo_id", "required_terraform_version"}
+	allowedPolicy = &models.PolicyEvaluationResult{Allowed: true, Reason: "allowed by policy"}
+)
+
+func newModuleProxyEnv(t *testing.T, upstream *fakeModuleUpstream, policy *fakePolicyEvaluator) (*ModuleProxyService, sqlmock.Sqlmock, *memStorage) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store := &memStorage{}
+	svc := NewModuleProxyService(
+		config.ModuleProxyConfig{Enabled: true, AllowedHosts: []string{"registry.terraform.io"}},
+		repositories.NewModuleRepository(db),
+		repositories.NewModuleProxyRepository(db),
+		repositories.NewOrganizationRepository(db),
+		policy, store, "local",
+	)
+	svc.tempDir = t.TempDir()
+	svc.SetUpstreamFactory(func(string) mirror.UpstreamModuleClient { return upstream })
+	return svc, mock, store
+}
+
+func expectDefaultOrg(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT.*FROM organizations").
+		WillReturnRows(sqlmock.NewRows(proxyOrgCols).AddRow(proxyOrgID, "default", "Default", nil, nil, time.Now(), time.Now()))
+}
+
+func moduleRow() *sqlmock.Rows {
+	return sqlmock.NewRows(proxyModCols).AddRow("mod-1", proxyOrgID, "hashicorp", "consul", "aws",
+		nil, "registry.terraform.io/hashicorp/consul/aws", nil, time.Now(), time.Now(), nil, false, nil, nil, nil)
+}
+
+func proxiedRow(host string) *sqlmock.Rows {
+	return sqlmock.NewRows(proxyPMCols).AddRow("mod-1", host, time.Now(), time.Now())
+}
+
+// moduleTarGz builds an upstream-style archive with a single top-level directory.
+func moduleTarGz(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, body := range map[string]string{
+		"consul-1.0.0/main.tf":      `resource "null_resource" "x" {}`,
+		"consul-1.0.0/variables.tf": `variable "a" {}`,
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestModuleProxy_HostNotAllowed(t *testing.T) {
+	svc, _, _ := newModuleProxyEnv(t, &fakeModuleUpstream{}, &fakePolicyEvaluator{result: allowedPolicy})
+
+	_, err := svc.ListVersions(context.Background(), "evil.example.com", "hashicorp", "consul", "aws")
+	if !errors.Is(err, ErrModuleProxyHostNotAllowed) {
+		t.Fatalf("err = %v, want ErrModuleProxyHostNotAllowed", err)
+	}
+}
+
+func TestModuleProxy_PolicyDenied(t *testing.T) {
+	policy := &fakePolicyEvaluator{result: &models.PolicyEvaluationResult{Allowed: false, Reason: "no matching allow policy"}}
+	svc, mock, _ := newModuleProxyEnv(t, &fakeModuleUpstream{}, policy)
+	expectDefaultOrg(mock)
+
+	_, err := svc.Fetch(context.Background(), "registry.terraform.io", "hashicorp", "consul", "aws", "1.0.0")
+	var denied *ModuleProxyDeniedError
+	if !errors.As(err, &denied) {
+		t.Fatalf("err = %v, want *ModuleProxyDeniedError", err)
+	}
+	if denied.Reason != "no matching allow policy" {
+		t.Errorf("reason = %q", denied.Reason)
+	}
+}
+
+func TestModuleProxy_ListVersions_RelaysUpstreamStatus(t *testing.T) {
+	upstream := &fakeModuleUpstream{versionsErr: &mirror.UpstreamStatusError{Op: "list module versions", StatusCode: http.StatusNotFound, Message: "Not Found"}}
+	svc, mock, _ := newModuleProxyEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDefaultOrg(mock)
+
+	_, err := svc.ListVersions(context.Background(), "registry.terraform.io", "hashicorp", "consul", "aws")
+	var statusErr *mirror.UpstreamStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want upstream 404", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestModuleProxy_ListVersions_FallsBackToCache(t *testing.T) {
+	upstream := &fakeModuleUpstream{versionsErr: errors.New("dial tcp: connection refused")}
+	svc, mock, _ := newModuleProxyEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(moduleRow())
+	mock.ExpectQuery("SELECT.*FROM proxied_modules").WillReturnRows(proxiedRow("registry.terraform.io"))
+	mock.ExpectQuery("SELECT.*FROM module_versions").WillReturnRows(sqlmock.NewRows([]string{
+		"id", "module_id", "version", "storage_path", "storage_backend", "size_bytes", "checksum",
+		"readme", "published_by", "published_by_name", "download_count", "deprecated",
+		"deprecated_at", "deprecation_message", "replacement_source", "created_at",
+		"commit_sha", "tag_name", "scm_repo_id", "required_terraform_version", "has_docs",
+	}).AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tar.gz", "local",
+		1024, "abc", nil, nil, nil, int64(0), false, nil, nil, nil, time.Now(), nil, nil, nil, nil, false))
+
+	versions, err := svc.ListVersions(context.Background(), "registry.terraform.io", "hashicorp", "consul", "aws")
+	if err != nil {
+		t.Fatalf("ListVersions: %v", err)
+	}
+	if len(versions) != 1 || versions[0] != "1.0.0" {
+		t.Errorf("versions = %v, want [1.0.0]", versions)
+	}
+}
+
+func TestModuleProxy_Fetch_CacheHit(t *testing.T) {
+	upstream := &fakeModuleUpstream{}
+	svc, mock, _ := newModuleProxyEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(moduleRow())
+	mock.ExpectQuery("SELECT.*FROM proxied_modules").WillReturnRows(proxiedRow("registry.terraform.io"))
+	mock.ExpectQuery("SELECT.*FROM module_versions").WillReturnRows(sqlmock.NewRows(proxyVerCols).
+		AddRow("ver-1", "mod-1", "1.0.0", "modules/hashicorp/consul/aws/1.0.0.tar.gz", "local",
+			1024, "abc", nil, nil, int64(3), false, nil, nil, nil, time.Now(), nil, nil, nil, nil))
+
+	mv, err := svc.Fetch(context.Background(), "registry.terraform.io", "hashicorp", "consul", "aws", "1.0.0")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if mv.ID != "ver-1" {
+		t.Errorf("ID = %q, want ver-1", mv.ID)
+	}
+	if upstream.downloads != 0 {
+		t.Errorf("upstream downloads = %d, want 0 on cache hit", upstream.downloads)
+	}
+}
+
+func TestModuleProxy_Fetch_LocalModuleConflict(t *testing.T) {
+	svc, mock, _ := newModuleProxyEnv(t, &fakeModuleUpstream{}, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(moduleRow())
+	mock.ExpectQuery("SELECT.*FROM proxied_modules").WillReturnRows(sqlmock.NewRows(proxyPMCols))
+
+	_, err := svc.Fetch(context.Background(), "registry.terraform.io", "hashicorp", "consul", "aws", "1.0.0")
+	if !errors.Is(err, ErrModuleProxyConflict) {
+		t.Fatalf("err = %v, want ErrModuleProxyConflict", err)
+	}
+}
+
+func TestModuleProxy_Fetch_DownloadsAndStores(t *testing.T) {
+	upstream := &fakeModuleUpstream{
+		location: "git::https://github.com/hashicorp/terraform-aws-consul?ref=v1.0.0",
+		archive:  moduleTarGz(t),
+	}
+	svc, mock, store := newModuleProxyEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(sqlmock.NewRows(proxyModCols))
+	mock.ExpectQuery("INSERT INTO modules").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("mod-1", time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO proxied_modules").WithArgs("mod-1", "registry.terraform.io").
+		WillReturnRows(proxiedRow("registry.terraform.io"))
+	mock.ExpectQuery("INSERT INTO module_versions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("ver-1", time.Now()))
+	mock.ExpectExec("UPDATE proxied_modules").WithArgs("mod-1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mv, err := svc.Fetch(context.Background(), "registry.terraform.io", "hashicorp", "consul", "aws", "1.0.0")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if mv.StoragePath != storage.ModuleArchiveKey("hashicorp", "consul", "aws", "1.0.0") {
+		t.Errorf("StoragePath = %q", mv.StoragePath)
+	}
+	if mv.Checksum == "" || mv.SizeBytes == 0 {
+		t.Errorf("checksum/size not recorded: %q/%d", mv.Checksum, mv.SizeBytes)
+	}
+
+	// The stored archive holds the module files at its root plus the manifest.
+	gzr, err := gzip.NewReader(bytes.NewReader(store.files[mv.StoragePath]))
+	if err != nil {
+		t.Fatalf("stored archive is not gzip: %v", err)
+	}
+	names := map[string]bool{}
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names[hdr.Name] = true
+	}
+	for _, want := range []string{".terraform-registry-proxy", "main.tf", "variables.tf"} {
+		if !names[want] {
+			t.Errorf("stored archive missing %q (have %v)", want, names)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestModuleProxy_Fetch_ClaimedByOtherHostDoesNotUpload(t *testing.T) {
+	upstream := &fakeModuleUpstream{
+		location: "https://example.com/consul.tar.gz",
+		archive:  moduleTarGz(t),
+	}
+	svc, mock, store := newModuleProxyEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(sqlmock.NewRows(proxyModCols))
+	mock.ExpectQuery("INSERT INTO modules").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("mod-1", time.Now(), time.Now()))
+	mock.ExpectQuery("INSERT INTO proxied_modules").
+		WillReturnRows(proxiedRow("other.example.com"))
+
+	_, err := svc.Fetch(context.Background(), "registry.terraform.io", "hashicorp", "consul", "aws", "1.0.0")
+	if !errors.Is(err, ErrModuleProxyConflict) {
+		t.Fatalf("err = %v, want ErrModuleProxyConflict", err)
+	}
+	if len(store.files) != 0 {
+		t.Errorf("storage written despite conflict: %v", store.files)
+	}
+}
+
+func TestModuleProxy_Fetch_UnsupportedSource(t *testing.T) {
+	upstream := &fakeModuleUpstream{location: "s3::https://s3.amazonaws.com/bucket/module.zip"}
+	svc, mock, _ := newModuleProxyEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM modules").WillReturnRows(sqlmock.NewRows(proxyModCols))
+
+	_, err := svc.Fetch(context.Background(), "registry.terraform.io", "hashicorp", "consul", "aws", "1.0.0")
+	if !errors.Is(err, mirror.ErrUnsupportedModuleSource) {
+		t.Fatalf("err = %v, want ErrUnsupportedModuleSource", err)
+	}
+}
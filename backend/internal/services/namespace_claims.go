@@ -0,0 +1,210 @@
+// namespace_claims.go decides self-service namespace claim requests. A claim
+// for a reserved namespace (hashicorp, terraform, or an admin-configured
+// pattern) is rejected on submission; a claim for a namespace matching the
+// organization's verified domain is approved on submission when
+// namespace_claims.auto_approve_verified_domain is set; everything else waits
+// for an admin. Approval writes the namespace_claims ownership row.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+var (
+	// ErrClaimRequestNotFound is returned for an unknown claim request.
+	ErrClaimRequestNotFound = errors.New("namespace claim request not found")
+	// ErrNamespaceAlreadyClaimed is returned when submitting a claim for a
+	// namespace that already has an owner.
+	ErrNamespaceAlreadyClaimed = errors.New("namespace is already claimed")
+)
+
+// builtinReservedNamespaces are reserved whatever the configuration says.
+var builtinReservedNamespaces = []string{`^hashicorp(-.*)?$`, `^terraform(-.*)?$`}
+
+// namespaceClaimRequestStore is the subset of NamespaceClaimRequestRepository
+// NamespaceClaims needs.
+type namespaceClaimRequestStore interface {
+	CreateRequest(ctx context.Context, req *models.NamespaceClaimRequest) error
+	ApproveRequest(ctx context.Context, id string, decidedBy *string, notes *string) (*models.NamespaceClaimRequest, []*models.NamespaceClaimRequest, error)
+	RejectRequest(ctx context.Context, id string, decidedBy *string, notes *string) (*models.NamespaceClaimRequest, error)
+	GetVerifiedDomain(ctx context.Context, organizationID string) (*models.OrgVerifiedDomain, error)
+}
+
+// namespaceOwnerStore is the subset of NamespaceClaimRepository
+// NamespaceClaims needs.
+type namespaceOwnerStore interface {
+	GetClaim(ctx context.Context, namespace string) (*models.NamespaceClaim, error)
+}
+
+// NamespaceClaimDecision is the outcome of approving a claim request.
+// Superseded lists the other organizations' pending requests for the
+// namespace that the approval rejected.
+type NamespaceClaimDecision struct {
+	Request    *models.NamespaceClaimRequest
+	Superseded []*models.NamespaceClaimRequest
+}
+
+// NamespaceClaims submits and decides namespace claim requests.
+type NamespaceClaims struct {
+	cfg      *config.NamespaceClaimsConfig
+	reserved []*regexp.Regexp
+	requests namespaceClaimRequestStore
+	owners   namespaceOwnerStore
+	audit    auditLogWriter // optional
+}
+
+// NewNamespaceClaims creates a NamespaceClaims. auditRepo may be nil.
+// Reserved patterns that do not compile are skipped with a warning;
+// config.Validate rejects them at startup.
+func NewNamespaceClaims(cfg *config.NamespaceClaimsConfig, requestRepo *repositories.NamespaceClaimRequestRepository, claimRepo *repositories.NamespaceClaimRepository, auditRepo *repositories.AuditRepository) *NamespaceClaims {
+	s := &NamespaceClaims{cfg: cfg, requests: requestRepo, owners: claimRepo}
+	if auditRepo != nil {
+		s.audit = auditRepo
+	}
+	patterns := append([]string{}, builtinReservedNamespaces...)
+	patterns = append(patterns, cfg.ReservedPatterns...)
+	for _, p := range patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			slog.Warn("ignoring invalid reserved namespace pattern", "pattern", p, "error", err)
+			continue
+		}
+		s.reserved = append(s.reserved, re)
+	}
+	return s
+}
+
+// IsReserved reports whether namespace matches a reserved pattern.
+func (s *NamespaceClaims) IsReserved(namespace string) bool {
+	for _, re := range s.reserved {
+		if re.MatchString(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// Submit records organizationID's claim on namespace and applies the
+// automatic rules: the returned request is rejected for a reserved
+// namespace, approved for a verified-domain match (when enabled), and
+// pending otherwise. It returns ErrNamespaceAlreadyClaimed when the
+// namespace has an owner and repositories.ErrClaimRequestPending when the
+// organization already has a pending request for it.
+func (s *NamespaceClaims) Submit(ctx context.Context, namespace, organizationID string, requestedBy *string, reason string) (*models.NamespaceClaimRequest, error) {
+	owner, err := s.owners.GetClaim(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		return nil, ErrNamespaceAlreadyClaimed
+	}
+
+	req := &models.NamespaceClaimRequest{
+		Namespace:      namespace,
+		OrganizationID: organizationID,
+		RequestedBy:    requestedBy,
+		Reason:         reason,
+		Status:         models.NamespaceClaimPending,
+	}
+	if s.IsReserved(namespace) {
+		notes := "The namespace is reserved."
+		req.Status = models.NamespaceClaimRejected
+		req.AutoDecided = true
+		req.DecisionNotes = &notes
+	}
+	if err := s.requests.CreateRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	s.record(ctx, "namespace_claim.submit", req, requestedBy)
+	if req.Status != models.NamespaceClaimPending || !s.cfg.AutoApproveVerifiedDomain {
+		return req, nil
+	}
+
+	domain, err := s.requests.GetVerifiedDomain(ctx, organizationID)
+	if err != nil {
+		// The request is recorded; an admin can still approve it.
+		slog.Warn("failed to look up verified domain for namespace claim auto-approval",
+			"organization_id", organizationID, "namespace", namespace, "error", err)
+		return req, nil
+	}
+	if domain == nil || !domain.MatchesNamespace(namespace) {
+		return req, nil
+	}
+	notes := fmt.Sprintf("The namespace matches the organization's verified domain %s.", domain.Domain)
+	decision, err := s.approve(ctx, req.ID, nil, &notes)
+	if err != nil {
+		slog.Warn("failed to auto-approve namespace claim", "request_id", req.ID, "namespace", namespace, "error", err)
+		return req, nil
+	}
+	return decision.Request, nil
+}
+
+// Approve approves pending request id on behalf of decidedBy, claiming the
+// namespace for the request's organization. It returns
+// ErrClaimRequestNotFound, repositories.ErrClaimRequestNotPending, or
+// repositories.ErrNamespaceOwnedByOther.
+func (s *NamespaceClaims) Approve(ctx context.Context, id string, decidedBy *string, notes *string) (*NamespaceClaimDecision, error) {
+	return s.approve(ctx, id, decidedBy, notes)
+}
+
+func (s *NamespaceClaims) approve(ctx context.Context, id string, decidedBy *string, notes *string) (*NamespaceClaimDecision, error) {
+	approved, superseded, err := s.requests.ApproveRequest(ctx, id, decidedBy, notes)
+	if err != nil {
+		return nil, err
+	}
+	if approved == nil {
+		return nil, ErrClaimRequestNotFound
+	}
+	s.record(ctx, "namespace_claim.approve", approved, decidedBy)
+	for _, r := range superseded {
+		s.record(ctx, "namespace_claim.reject", r, nil)
+	}
+	return &NamespaceClaimDecision{Request: approved, Superseded: superseded}, nil
+}
+
+// Reject rejects pending request id on behalf of decidedBy. It returns
+// ErrClaimRequestNotFound or repositories.ErrClaimRequestNotPending.
+func (s *NamespaceClaims) Reject(ctx context.Context, id string, decidedBy *string, notes *string) (*models.NamespaceClaimRequest, error) {
+	rejected, err := s.requests.RejectRequest(ctx, id, decidedBy, notes)
+	if err != nil {
+		return nil, err
+	}
+	if rejected == nil {
+		return nil, ErrClaimRequestNotFound
+	}
+	s.record(ctx, "namespace_claim.reject", rejected, decidedBy)
+	return rejected, nil
+}
+
+// record writes an audit entry for a claim request. Failures are logged: the
+// decision has already been committed.
+func (s *NamespaceClaims) record(ctx context.Context, action string, req *models.NamespaceClaimRequest, userID *string) {
+	if s.audit == nil {
+		return
+	}
+	resourceType := "namespace_claim_request"
+	orgID := req.OrganizationID
+	entry := &models.AuditLog{
+		UserID:         userID,
+		OrganizationID: &orgID,
+		Action:         action,
+		ResourceType:   &resourceType,
+		ResourceID:     &req.ID,
+		Metadata: map[string]interface{}{
+			"namespace":    req.Namespace,
+			"status":       string(req.Status),
+			"auto_decided": req.AutoDecided,
+		},
+	}
+	if err := s.audit.CreateAuditLog(ctx, entry); err != nil {
+		slog.Warn("failed to write namespace claim audit log", "action", action, "request_id", req.ID, "error", err)
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+type fakeClaimRequestStore struct {
+	requests map[string]*models.NamespaceClaimRequest
+	domains  map[string]*models.OrgVerifiedDomain
+	owners   map[string]string
+}
+
+func newFakeClaimRequestStore() *fakeClaimRequestStore {
+	return &fakeClaimRequestStore{
+		requests: map[string]*models.NamespaceClaimRequest{},
+		domains:  map[string]*models.OrgVerifiedDomain{},
+		owners:   map[string]string{},
+	}
+}
+
+func (f *fakeClaimRequestStore) CreateRequest(_ context.Context, req *models.NamespaceClaimRequest) error {
+	for _, r := range f.requests {
+		if r.Namespace == req.Namespace && r.OrganizationID == req.OrganizationID && r.Status == models.NamespaceClaimPending {
+			return repositories.ErrClaimRequestPending
+		}
+	}
+	req.ID = "req-" + req.OrganizationID
+	f.requests[req.ID] = req
+	return nil
+}
+
+func (f *fakeClaimRequestStore) ApproveRequest(_ context.Context, id string, decidedBy *string, notes *string) (*models.NamespaceClaimRequest, []*models.NamespaceClaimRequest, error) {
+	req := f.requests[id]
+	if req == nil {
+		return nil, nil, nil
+	}
+	if req.Status != models.NamespaceClaimPending {
+		return nil, nil, repositories.ErrClaimRequestNotPending
+	}
+	if owner, ok := f.owners[req.Namespace]; ok && owner != req.OrganizationID {
+		return nil, nil, repositories.ErrNamespaceOwnedByOther
+	}
+	f.owners[req.Namespace] = req.OrganizationID
+	req.Status, req.AutoDecided, req.DecidedBy, req.DecisionNotes = models.NamespaceClaimApproved, decidedBy == nil, decidedBy, notes
+	var superseded []*models.NamespaceClaimRequest
+	for _, r := range f.requests {
+		if r.Namespace == req.Namespace && r.Status == models.NamespaceClaimPending {
+			r.Status, r.AutoDecided = models.NamespaceClaimRejected, true
+			superseded = append(superseded, r)
+		}
+	}
+	return req, superseded, nil
+}
+
+func (f *fakeClaimRequestStore) RejectRequest(_ context.Context, id string, decidedBy *string, notes *string) (*models.NamespaceClaimRequest, error) {
+	req := f.requests[id]
+	if req == nil {
+		return nil, nil
+	}
+	if req.Status != models.NamespaceClaimPending {
+		return nil, repositories.ErrClaimRequestNotPending
+	}
+	req.Status, req.DecidedBy, req.DecisionNotes = models.NamespaceClaimRejected, decidedBy, notes
+	return req, nil
+}
+
+func (f *fakeClaimRequestStore) GetVerifiedDomain(_ context.Context, organizationID string) (*models.OrgVerifiedDomain, error) {
+	return f.domains[organizationID], nil
+}
+
+func (f *fakeClaimRequestStore) GetClaim(_ context.Context, namespace string) (*models.NamespaceClaim, error) {
+	if owner, ok := f.owners[namespace]; ok {
+		return &models.NamespaceClaim{Namespace: namespace, OrganizationID: owner}, nil
+	}
+	return nil, nil
+}
+
+func newTestNamespaceClaims(cfg config.NamespaceClaimsConfig) (*NamespaceClaims, *fakeClaimRequestStore, *fakeAuditLogWriter) {
+	store := newFakeClaimRequestStore()
+	audit := &fakeAuditLogWriter{}
+	s := NewNamespaceClaims(&cfg, nil, nil, nil)
+	s.requests, s.owners, s.audit = store, store, audit
+	return s, store, audit
+}
+
+func TestNamespaceClaims_IsReserved(t *testing.T) {
+	s, _, _ := newTestNamespaceClaims(config.NamespaceClaimsConfig{ReservedPatterns: []string{`^internal-`, `[`}})
+	for ns, want := range map[string]bool{
+		"hashicorp":        true,
+		"HashiCorp":        true,
+		"hashicorp-labs":   true,
+		"hashicorpfan":     false,
+		"terraform":        true,
+		"terraform-google": true,
+		"internal-tools":   true,
+		"acme":             false,
+	} {
+		if got := s.IsReserved(ns); got != want {
+			t.Errorf("IsReserved(%q) = %v, want %v", ns, got, want)
+		}
+	}
+}
+
+func TestNamespaceClaims_SubmitPending(t *testing.T) {
+	s, store, audit := newTestNamespaceClaims(config.NamespaceClaimsConfig{})
+	user := "user-1"
+	req, err := s.Submit(context.Background(), "acme", "org-1", &user, "our company")
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if req.Status != models.NamespaceClaimPending || req.AutoDecided {
+		t.Errorf("request = %+v, want pending", req)
+	}
+	if _, ok := store.owners["acme"]; ok {
+		t.Error("pending request claimed the namespace")
+	}
+	if len(audit.logs) != 1 || audit.logs[0].Action != "namespace_claim.submit" {
+		t.Errorf("audit = %+v", audit.logs)
+	}
+
+	if _, err := s.Submit(context.Background(), "acme", "org-1", &user, ""); !errors.Is(err, repositories.ErrClaimRequestPending) {
+		t.Errorf("duplicate Submit = %v, want ErrClaimRequestPending", err)
+	}
+}
+
+func TestNamespaceClaims_SubmitReserved(t *testing.T) {
+	s, store, _ := newTestNamespaceClaims(config.NamespaceClaimsConfig{AutoApproveVerifiedDomain: true})
+	store.domains["org-1"] = &models.OrgVerifiedDomain{OrganizationID: "org-1", Domain: "terraform.io"}
+	req, err := s.Submit(context.Background(), "terraform", "org-1", nil, "")
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if req.Status != models.NamespaceClaimRejected || !req.AutoDecided {
+		t.Errorf("request = %+v, want auto-rejected", req)
+	}
+	if _, ok := store.owners["terraform"]; ok {
+		t.Error("reserved namespace was claimed")
+	}
+}
+
+func TestNamespaceClaims_SubmitAutoApprove(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		namespace string
+		want      models.NamespaceClaimStatus
+	}{
+		{"matching prefix", true, "acme-platform", models.NamespaceClaimApproved},
+		{"exact prefix", true, "acme", models.NamespaceClaimApproved},
+		{"different namespace", true, "acmecorp", models.NamespaceClaimPending},
+		{"disabled", false, "acme", models.NamespaceClaimPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, _ := newTestNamespaceClaims(config.NamespaceClaimsConfig{AutoApproveVerifiedDomain: tt.enabled})
+			store.domains["org-1"] = &models.OrgVerifiedDomain{OrganizationID: "org-1", Domain: "acme.com"}
+			req, err := s.Submit(context.Background(), tt.namespace, "org-1", nil, "")
+			if err != nil {
+				t.Fatalf("Submit: %v", err)
+			}
+			if req.Status != tt.want {
+				t.Errorf("status = %s, want %s", req.Status, tt.want)
+			}
+			if owned := store.owners[tt.namespace] == "org-1"; owned != (tt.want == models.NamespaceClaimApproved) {
+				t.Errorf("namespace owned = %v", owned)
+			}
+			if tt.want == models.NamespaceClaimApproved && (!req.AutoDecided || req.DecisionNotes == nil) {
+				t.Errorf("auto-approved request = %+v, want auto_decided with notes", req)
+			}
+		})
+	}
+}
+
+func TestNamespaceClaims_SubmitAlreadyClaimed(t *testing.T) {
+	s, store, _ := newTestNamespaceClaims(config.NamespaceClaimsConfig{})
+	store.owners["acme"] = "org-2"
+	if _, err := s.Submit(context.Background(), "acme", "org-1", nil, ""); !errors.Is(err, ErrNamespaceAlreadyClaimed) {
+		t.Fatalf("Submit = %v, want ErrNamespaceAlreadyClaimed", err)
+	}
+}
+
+func TestNamespaceClaims_ApproveSupersedes(t *testing.T) {
+	s, store, audit := newTestNamespaceClaims(config.NamespaceClaimsConfig{})
+	for _, org := range []string{"org-1", "org-2"} {
+		if _, err := s.Submit(context.Background(), "acme", org, nil, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	admin := "admin-1"
+	decision, err := s.Approve(context.Background(), "req-org-1", &admin, nil)
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if decision.Request.Status != models.NamespaceClaimApproved || decision.Request.AutoDecided {
+		t.Errorf("request = %+v", decision.Request)
+	}
+	if len(decision.Superseded) != 1 || decision.Superseded[0].OrganizationID != "org-2" {
+		t.Errorf("superseded = %+v", decision.Superseded)
+	}
+	if store.owners["acme"] != "org-1" {
+		t.Errorf("owner = %q, want org-1", store.owners["acme"])
+	}
+	// two submits, one approval, one superseded rejection
+	if len(audit.logs) != 4 {
+		t.Errorf("audit entries = %d, want 4", len(audit.logs))
+	}
+
+	if _, err := s.Approve(context.Background(), "req-org-1", &admin, nil); !errors.Is(err, repositories.ErrClaimRequestNotPending) {
+		t.Errorf("second Approve = %v, want ErrClaimRequestNotPending", err)
+	}
+	if _, err := s.Approve(context.Background(), "missing", &admin, nil); !errors.Is(err, ErrClaimRequestNotFound) {
+		t.Errorf("Approve(missing) = %v, want ErrClaimRequestNotFound", err)
+	}
+}
+
+func TestNamespaceClaims_Reject(t *testing.T) {
+	s, _, _ := newTestNamespaceClaims(config.NamespaceClaimsConfig{})
+	if _, err := s.Submit(context.Background(), "acme", "org-1", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	notes := "use your org name"
+	req, err := s.Reject(context.Background(), "req-org-1", nil, &notes)
+	if err != nil || req.Status != models.NamespaceClaimRejected || req.DecisionNotes == nil {
+		t.Fatalf("Reject = %+v, %v", req, err)
+	}
+	if _, err := s.Reject(context.Background(), "missing", nil, nil); !errors.Is(err, ErrClaimRequestNotFound) {
+		t.Errorf("Reject(missing) = %v, want ErrClaimRequestNotFound", err)
+	}
+}
@@ -0,0 +1,288 @@
+// org_event_webhooks.go delivers an organization's security-relevant events
+// (membership and role changes, API key lifecycle, SCM token connections) to
+// the HTTPS endpoints it has subscribed, typically a SIEM collector. Handlers
+// publish events on the events.Bus; OrgEventWebhooks.Handle is subscribed to
+// the bus and runs on its goroutine, never inline in a request.
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/events"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+)
+
+// Headers sent with every event webhook delivery besides the publish hook
+// event, delivery and signature headers. X-Registry-Signature-256 is computed
+// as for publish hooks (see SignPublishHookBody) with the secret named by
+// OrgEventSignatureKeyIDHeader. OrgEventSignaturesHeader lists the signature
+// under every secret signing the delivery, as comma-separated
+// "<key_id>:sha256=<hex>" entries: both secrets while a rotation overlaps,
+// otherwise just the one.
+const (
+	OrgEventSchemaVersionHeader  = PublishHookSchemaVersionHeader
+	OrgEventSignatureKeyIDHeader = "X-Registry-Signature-Key-Id"
+	OrgEventSignaturesHeader     = "X-Registry-Signatures"
+)
+
+// webhookSigningKey is a secret deliveries are signed with, and its key ID.
+type webhookSigningKey struct {
+	id     string
+	secret string
+}
+
+// orgEventWebhookStore is the subset of OrgEventWebhookRepository
+// OrgEventWebhooks needs.
+type orgEventWebhookStore interface {
+	List(ctx context.Context, orgID string, enabledOnly bool) ([]*models.OrgEventWebhook, error)
+	CreateDelivery(ctx context.Context, d *models.OrgEventDelivery) error
+}
+
+// OrgEventWebhooks delivers organization events to their webhooks. A nil
+// *OrgEventWebhooks delivers nothing.
+type OrgEventWebhooks struct {
+	store  orgEventWebhookStore
+	cipher *crypto.TokenCipher
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewOrgEventWebhooks creates an OrgEventWebhooks. Deliveries go through the
+// egress guard, so an endpoint on a private network must be on the egress
+// allowlist.
+func NewOrgEventWebhooks(repo *repositories.OrgEventWebhookRepository, cipher *crypto.TokenCipher, egress *httpsafe.Guard) *OrgEventWebhooks {
+	return &OrgEventWebhooks{
+		store:  repo,
+		cipher: cipher,
+		client: httpsafe.NewClient(MaxPublishHookTimeout, egress),
+		now:    time.Now,
+	}
+}
+
+// SetHTTPClient replaces the HTTP client used for deliveries. Intended for
+// tests.
+func (s *OrgEventWebhooks) SetHTTPClient(c *http.Client) {
+	s.client = c
+}
+
+// Handle delivers e to every enabled webhook of its organization subscribed
+// to its type. It is an events.Handler.
+func (s *OrgEventWebhooks) Handle(ctx context.Context, e events.Event) {
+	if s == nil {
+		return
+	}
+	webhooks, err := s.store.List(ctx, e.OrganizationID, true)
+	if err != nil {
+		slog.Error("event webhooks: failed to list webhooks",
+			"organization_id", e.OrganizationID, "event_id", e.ID, "error", err)
+		return
+	}
+	var payload []byte
+	for _, w := range webhooks {
+		if !w.Wants(e.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(e); err != nil {
+				slog.Error("event webhooks: failed to encode event", "event_id", e.ID, "error", err)
+				return
+			}
+		}
+		d := s.deliver(ctx, w, e.ID, e.Type, e.SchemaVersion, payload, nil)
+		if d.Outcome != models.OrgEventOutcomeDelivered {
+			slog.Warn("event webhooks: delivery failed",
+				"organization_id", e.OrganizationID, "webhook_id", w.ID, "event_id", e.ID,
+				"type", e.Type, "error", *d.Error)
+		}
+	}
+}
+
+// OrgEventWebhookTestResult reports a test of an event webhook.
+type OrgEventWebhookTestResult struct {
+	// Delivery is the signed sample event, as recorded in the delivery log.
+	Delivery *models.OrgEventDelivery `json:"delivery"`
+	// BadSignatureStatus is the status the endpoint returned for a copy of
+	// the event signed with the wrong key; unset when it did not answer.
+	BadSignatureStatus *int `json:"bad_signature_status,omitempty"`
+	// SignatureVerified reports that the endpoint rejected the badly signed
+	// copy with a 4xx status.
+	SignatureVerified bool `json:"signature_verified"`
+	// Ready reports that the endpoint accepted the signed event and verified
+	// the signature, so the webhook can be enabled.
+	Ready bool `json:"ready"`
+}
+
+// Test sends w a signed sample event of type events.TypeTest and then a copy
+// signed with the wrong key, whether or not w is enabled. It does not fail
+// when the endpoint misbehaves; the result says how.
+func (s *OrgEventWebhooks) Test(ctx context.Context, w *models.OrgEventWebhook) *OrgEventWebhookTestResult {
+	e := events.Event{
+		ID:             uuid.New().String(),
+		Type:           events.TypeTest,
+		SchemaVersion:  events.SchemaVersion,
+		OrganizationID: w.OrganizationID,
+		OccurredAt:     s.now().UTC(),
+		Data:           map[string]string{"webhook_id": w.ID},
+	}
+	payload, _ := json.Marshal(e)
+	res := &OrgEventWebhookTestResult{Delivery: s.deliver(ctx, w, e.ID, e.Type, e.SchemaVersion, payload, nil)}
+
+	badKey := make([]byte, 32)
+	if _, err := rand.Read(badKey); err == nil {
+		attemptCtx, cancel := context.WithTimeout(ctx, orgEventWebhookTimeout(w))
+		keys := []webhookSigningKey{{id: w.SecretKeyID, secret: string(badKey)}}
+		status, err := s.post(attemptCtx, w.URL, e.Type, "", e.SchemaVersion, keys, payload)
+		cancel()
+		if err == nil {
+			res.BadSignatureStatus = &status
+			res.SignatureVerified = status >= 400 && status < 500
+		}
+	}
+	res.Ready = res.Delivery.Outcome == models.OrgEventOutcomeDelivered && res.SignatureVerified
+	return res
+}
+
+// Replay re-sends d's payload, byte for byte, to w, signed with w's current
+// secrets, whether or not w is enabled. The new delivery is recorded with
+// ReplayOf set to d.
+func (s *OrgEventWebhooks) Replay(ctx context.Context, w *models.OrgEventWebhook, d *models.OrgEventDelivery) *models.OrgEventDelivery {
+	return s.deliver(ctx, w, d.EventID, d.EventType, d.SchemaVersion, d.Payload, &d.ID)
+}
+
+// deliver POSTs payload to w, retrying once on a 5xx response, and records
+// the delivery. A failed delivery has its Error set.
+func (s *OrgEventWebhooks) deliver(ctx context.Context, w *models.OrgEventWebhook, eventID, eventType string, schemaVersion int, payload []byte, replayOf *string) *models.OrgEventDelivery {
+	start := s.now()
+	d := &models.OrgEventDelivery{
+		ID:            uuid.New().String(),
+		WebhookID:     w.ID,
+		EventID:       eventID,
+		EventType:     eventType,
+		SchemaVersion: schemaVersion,
+		Payload:       payload,
+		ReplayOf:      replayOf,
+	}
+
+	if err := s.send(ctx, w, d); err != nil {
+		msg := err.Error()
+		d.Error = &msg
+		d.Outcome = models.OrgEventOutcomeFailed
+	} else {
+		d.Outcome = models.OrgEventOutcomeDelivered
+	}
+	d.DurationMs = s.now().Sub(start).Milliseconds()
+
+	// The log must not hold up the bus, so a failed write is only logged.
+	if err := s.store.CreateDelivery(ctx, d); err != nil {
+		slog.Warn("event webhooks: failed to record delivery",
+			"webhook_id", w.ID, "event_id", eventID, "error", err)
+	}
+	return d
+}
+
+// send makes up to two attempts; only a 5xx response is retried. d.Attempts
+// and d.StatusCode are updated as it goes.
+func (s *OrgEventWebhooks) send(ctx context.Context, w *models.OrgEventWebhook, d *models.OrgEventDelivery) error {
+	keys, err := s.signingKeys(w)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		d.Attempts = attempt
+		attemptCtx, cancel := context.WithTimeout(ctx, orgEventWebhookTimeout(w))
+		status, err := s.post(attemptCtx, w.URL, d.EventType, d.ID, d.SchemaVersion, keys, d.Payload)
+		cancel()
+		if err != nil {
+			d.StatusCode = nil
+			return err
+		}
+		d.StatusCode = &status
+		switch {
+		case status >= 200 && status < 300:
+			return nil
+		case status >= 500 && attempt < 2:
+			continue
+		}
+		return fmt.Errorf("webhook returned %d", status)
+	}
+}
+
+// signingKeys opens the secrets w's deliveries are signed with, the first
+// of which signs X-Registry-Signature-256: the current secret, then the next
+// one while a rotation overlaps, or the next one alone once the overlap has
+// ended.
+func (s *OrgEventWebhooks) signingKeys(w *models.OrgEventWebhook) ([]webhookSigningKey, error) {
+	if s.cipher == nil {
+		return nil, errors.New("no encryption key is configured to open the webhook secret")
+	}
+	var keys []webhookSigningKey
+	if w.SecretRotation == nil || s.now().Before(w.SecretRotation.OverlapUntil) {
+		secret, err := s.cipher.Open(w.EncryptedSecret)
+		if err != nil {
+			return nil, fmt.Errorf("open webhook secret: %w", err)
+		}
+		keys = append(keys, webhookSigningKey{id: w.SecretKeyID, secret: secret})
+	}
+	if w.SecretRotation != nil {
+		secret, err := s.cipher.Open(w.NextEncryptedSecret)
+		if err != nil {
+			return nil, fmt.Errorf("open next webhook secret: %w", err)
+		}
+		keys = append(keys, webhookSigningKey{id: w.SecretRotation.NextSecretKeyID, secret: secret})
+	}
+	return keys, nil
+}
+
+// post sends one request signed with keys and returns the response status.
+func (s *OrgEventWebhooks) post(ctx context.Context, url, eventType, deliveryID string, schemaVersion int, keys []webhookSigningKey, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "terraform-registry-event-webhook")
+	req.Header.Set(PublishHookEventHeader, eventType)
+	req.Header.Set(OrgEventSchemaVersionHeader, strconv.Itoa(schemaVersion))
+	if deliveryID != "" {
+		req.Header.Set(PublishHookDeliveryHeader, deliveryID)
+	}
+	signatures := make([]string, len(keys))
+	for i, k := range keys {
+		signatures[i] = k.id + ":" + SignPublishHookBody(k.secret, body)
+	}
+	req.Header.Set(PublishHookSignatureHeader, SignPublishHookBody(keys[0].secret, body))
+	req.Header.Set(OrgEventSignatureKeyIDHeader, keys[0].id)
+	req.Header.Set(OrgEventSignaturesHeader, strings.Join(signatures, ", "))
+
+	resp, err := s.client.Do(req) // #nosec G107 -- webhook URL is admin-configured and dialed through the egress guard
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxPublishHookResponse))
+	return resp.StatusCode, nil
+}
+
+func orgEventWebhookTimeout(w *models.OrgEventWebhook) time.Duration {
+	timeout := time.Duration(w.TimeoutSeconds) * time.Second
+	if timeout <= 0 || timeout > MaxPublishHookTimeout {
+		return MaxPublishHookTimeout
+	}
+	return timeout
+}
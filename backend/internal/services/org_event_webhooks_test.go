@@ -0,0 +1,241 @@
+// org_event_webhooks_test.go tests OrgEventWebhooks against httptest
+// endpoints with a fake webhook store.
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/events"
+)
+
+type fakeOrgEventWebhookStore struct {
+	webhooks   []*models.OrgEventWebhook
+	deliveries []*models.OrgEventDelivery
+}
+
+func (f *fakeOrgEventWebhookStore) List(_ context.Context, _ string, _ bool) ([]*models.OrgEventWebhook, error) {
+	return f.webhooks, nil
+}
+
+func (f *fakeOrgEventWebhookStore) CreateDelivery(_ context.Context, d *models.OrgEventDelivery) error {
+	f.deliveries = append(f.deliveries, d)
+	return nil
+}
+
+// newTestOrgEventWebhooks returns an OrgEventWebhooks and a webhook pointing
+// at an endpoint served by handler, subscribed to eventTypes.
+func newTestOrgEventWebhooks(t *testing.T, handler http.HandlerFunc, eventTypes ...string) (*OrgEventWebhooks, *fakeOrgEventWebhookStore, *models.OrgEventWebhook) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cipher, err := crypto.NewTokenCipher(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := cipher.Seal(testHookSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &models.OrgEventWebhook{
+		ID:              "wh-1",
+		OrganizationID:  "org-1",
+		URL:             srv.URL,
+		EncryptedSecret: sealed,
+		SecretKeyID:     "k1",
+		EventTypes:      eventTypes,
+		TimeoutSeconds:  1,
+		Enabled:         true,
+	}
+	store := &fakeOrgEventWebhookStore{webhooks: []*models.OrgEventWebhook{w}}
+	return &OrgEventWebhooks{store: store, cipher: cipher, client: srv.Client(), now: time.Now}, store, w
+}
+
+// verifyingReceiver answers 204 after checking the request signature, and 401
+// when it does not match. Accepted bodies are sent to got.
+func verifyingReceiver(got chan<- []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(PublishHookSignatureHeader) != SignPublishHookBody(testHookSecret, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got != nil {
+			got <- body
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestOrgEventWebhooks_HandleDeliversSubscribedEvents(t *testing.T) {
+	got := make(chan []byte, 2)
+	s, store, _ := newTestOrgEventWebhooks(t, verifyingReceiver(got), events.TypeAPIKeyRevoked)
+
+	s.Handle(context.Background(), events.Event{ID: "ev-1", Type: events.TypeMemberAdded, SchemaVersion: 1, OrganizationID: "org-1"})
+	s.Handle(context.Background(), events.Event{
+		ID: "ev-2", Type: events.TypeAPIKeyRevoked, SchemaVersion: 1, OrganizationID: "org-1",
+		Data: map[string]string{"api_key_id": "key-1"},
+	})
+
+	if len(store.deliveries) != 1 {
+		t.Fatalf("deliveries = %d, want 1 (member_added is filtered out)", len(store.deliveries))
+	}
+	d := store.deliveries[0]
+	if d.Outcome != models.OrgEventOutcomeDelivered || d.EventID != "ev-2" || d.Attempts != 1 {
+		t.Errorf("delivery = %+v", d)
+	}
+	var sent events.Event
+	if err := json.Unmarshal(<-got, &sent); err != nil || sent.Type != events.TypeAPIKeyRevoked || sent.SchemaVersion != 1 || sent.Data["api_key_id"] != "key-1" {
+		t.Errorf("sent = %+v, %v", sent, err)
+	}
+}
+
+func TestOrgEventWebhooks_RetriesOnceOn5xx(t *testing.T) {
+	var calls atomic.Int32
+	s, store, _ := newTestOrgEventWebhooks(t, func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	s.Handle(context.Background(), events.Event{ID: "ev-1", Type: events.TypeMemberRemoved, SchemaVersion: 1, OrganizationID: "org-1"})
+
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	d := store.deliveries[0]
+	if d.Outcome != models.OrgEventOutcomeFailed || d.Attempts != 2 || d.StatusCode == nil || *d.StatusCode != http.StatusBadGateway || d.Error == nil {
+		t.Errorf("delivery = %+v", d)
+	}
+}
+
+func TestOrgEventWebhooks_TestReady(t *testing.T) {
+	got := make(chan []byte, 1)
+	s, store, w := newTestOrgEventWebhooks(t, verifyingReceiver(got))
+	w.Enabled = false
+
+	res := s.Test(context.Background(), w)
+	if !res.Ready || !res.SignatureVerified || res.BadSignatureStatus == nil || *res.BadSignatureStatus != http.StatusUnauthorized {
+		t.Errorf("result = %+v", res)
+	}
+	if len(store.deliveries) != 1 || store.deliveries[0].EventType != events.TypeTest {
+		t.Errorf("deliveries = %+v", store.deliveries)
+	}
+	var sent events.Event
+	if err := json.Unmarshal(<-got, &sent); err != nil || sent.Type != events.TypeTest || sent.Data["webhook_id"] != "wh-1" {
+		t.Errorf("sent = %+v, %v", sent, err)
+	}
+}
+
+func TestOrgEventWebhooks_TestNotReadyWithoutSignatureCheck(t *testing.T) {
+	s, _, w := newTestOrgEventWebhooks(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	res := s.Test(context.Background(), w)
+	if res.Ready || res.SignatureVerified || res.Delivery.Outcome != models.OrgEventOutcomeDelivered {
+		t.Errorf("result = %+v, want delivered but not ready", res)
+	}
+}
+
+func TestOrgEventWebhooks_ReplaySendsStoredPayload(t *testing.T) {
+	got := make(chan []byte, 1)
+	s, store, w := newTestOrgEventWebhooks(t, verifyingReceiver(got))
+	original := &models.OrgEventDelivery{
+		ID: "d-1", WebhookID: "wh-1", EventID: "ev-1", EventType: events.TypeAPIKeyCreated, SchemaVersion: 1,
+		Payload: json.RawMessage(`{"id":"ev-1","type":"api_key.created","schema_version":1}`),
+	}
+
+	d := s.Replay(context.Background(), w, original)
+	if d.Outcome != models.OrgEventOutcomeDelivered || d.ReplayOf == nil || *d.ReplayOf != "d-1" || d.ID == "d-1" || d.EventID != "ev-1" {
+		t.Errorf("replay = %+v", d)
+	}
+	if body := <-got; string(body) != string(original.Payload) {
+		t.Errorf("sent %s, want the stored payload", body)
+	}
+	if len(store.deliveries) != 1 {
+		t.Errorf("deliveries = %d, want 1", len(store.deliveries))
+	}
+}
+
+// signatureHeaders captures the signature headers of the last request.
+type signatureHeaders struct {
+	signature, keyID, signatures string
+	body                         []byte
+}
+
+func capturingReceiver(got *signatureHeaders) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got.body, _ = io.ReadAll(r.Body)
+		got.signature = r.Header.Get(PublishHookSignatureHeader)
+		got.keyID = r.Header.Get(OrgEventSignatureKeyIDHeader)
+		got.signatures = r.Header.Get(OrgEventSignaturesHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// startRotation gives w a rotation to nextSecret under key ID k2 whose
+// overlap ends at overlapUntil.
+func startRotation(t *testing.T, s *OrgEventWebhooks, w *models.OrgEventWebhook, nextSecret string, overlapUntil time.Time) {
+	t.Helper()
+	sealed, err := s.cipher.Seal(nextSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.NextEncryptedSecret = sealed
+	w.SecretRotation = &models.OrgEventWebhookSecretRotation{NextSecretKeyID: "k2", StartedAt: time.Now(), OverlapUntil: overlapUntil}
+}
+
+func TestOrgEventWebhooks_SignsWithKeyID(t *testing.T) {
+	var got signatureHeaders
+	s, _, _ := newTestOrgEventWebhooks(t, capturingReceiver(&got))
+
+	s.Handle(context.Background(), events.Event{ID: "ev-1", Type: events.TypeMemberAdded, SchemaVersion: 1, OrganizationID: "org-1"})
+
+	sig := SignPublishHookBody(testHookSecret, got.body)
+	if got.signature != sig || got.keyID != "k1" || got.signatures != "k1:"+sig {
+		t.Errorf("headers = %+v, want signature %s under k1", got, sig)
+	}
+}
+
+func TestOrgEventWebhooks_RotationOverlapSignsWithBoth(t *testing.T) {
+	const nextSecret = "next-secret-0123456789"
+	var got signatureHeaders
+	s, _, w := newTestOrgEventWebhooks(t, capturingReceiver(&got))
+	startRotation(t, s, w, nextSecret, time.Now().Add(time.Hour))
+
+	s.Handle(context.Background(), events.Event{ID: "ev-1", Type: events.TypeMemberAdded, SchemaVersion: 1, OrganizationID: "org-1"})
+
+	current, next := SignPublishHookBody(testHookSecret, got.body), SignPublishHookBody(nextSecret, got.body)
+	if got.signature != current || got.keyID != "k1" {
+		t.Errorf("primary signature = %s under %s, want the current secret's under k1", got.signature, got.keyID)
+	}
+	if got.signatures != "k1:"+current+", k2:"+next {
+		t.Errorf("%s = %q", OrgEventSignaturesHeader, got.signatures)
+	}
+}
+
+func TestOrgEventWebhooks_RotationAfterOverlapSignsWithNextOnly(t *testing.T) {
+	const nextSecret = "next-secret-0123456789"
+	var got signatureHeaders
+	s, store, w := newTestOrgEventWebhooks(t, capturingReceiver(&got))
+	startRotation(t, s, w, nextSecret, time.Now().Add(-time.Minute))
+
+	s.Handle(context.Background(), events.Event{ID: "ev-1", Type: events.TypeMemberAdded, SchemaVersion: 1, OrganizationID: "org-1"})
+
+	next := SignPublishHookBody(nextSecret, got.body)
+	if got.signature != next || got.keyID != "k2" || got.signatures != "k2:"+next {
+		t.Errorf("headers = %+v, want the next secret's signature under k2 only", got)
+	}
+	if store.deliveries[0].Outcome != models.OrgEventOutcomeDelivered {
+		t.Errorf("delivery = %+v", store.deliveries[0])
+	}
+}
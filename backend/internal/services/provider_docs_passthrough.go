@@ -0,0 +1,370 @@
+// provider_docs_passthrough.go implements the documentation passthrough for
+// mirrored providers. A mirror with docs_passthrough_enabled serves the
+// resource and data source pages of the providers it synced from its upstream
+// registry's v2 provider-docs API: the doc index of a version and each page
+// are fetched on the first request and stored in provider_version_docs, and a
+// page older than the mirror's docs_cache_ttl_hours is fetched again.
+//
+// The passthrough is opt-in per mirror because it makes the API server itself
+// call the upstream registry, which an otherwise air-gapped deployment may
+// not allow. The mirror policies gate it like any other mirror operation.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+)
+
+// defaultDocsCacheTTL applies to mirrors stored without a docs_cache_ttl_hours.
+const defaultDocsCacheTTL = 24 * time.Hour
+
+var (
+	// ErrProviderDocsNotFound is returned when the provider, the version or
+	// the doc page does not exist.
+	ErrProviderDocsNotFound = errors.New("provider documentation not found")
+
+	// ErrProviderDocsDisabled is returned for providers no mirror synced, and
+	// for those whose mirror does not enable the documentation passthrough.
+	ErrProviderDocsDisabled = errors.New("documentation passthrough is not enabled for this provider")
+
+	// ErrProviderDocsUpstream wraps failures to fetch from the upstream
+	// registry when no cached copy can be served instead.
+	ErrProviderDocsUpstream = errors.New("failed to fetch documentation from the upstream registry")
+)
+
+// ProviderDocsDeniedError is returned when the mirror policies do not allow
+// the provider's upstream documentation to be fetched.
+type ProviderDocsDeniedError struct {
+	Reason string
+}
+
+func (e *ProviderDocsDeniedError) Error() string {
+	return "provider documentation denied: " + e.Reason
+}
+
+// ProviderDocsUpstream is the subset of the upstream registry client the
+// passthrough uses. *mirror.UpstreamRegistry satisfies it.
+type ProviderDocsUpstream interface {
+	GetProviderDocIndexByVersion(ctx context.Context, namespace, providerName, version string) ([]mirror.ProviderDocEntry, error)
+	GetProviderDocContent(ctx context.Context, upstreamDocID string) (string, error)
+}
+
+// ProviderDocsSource attributes documentation to the registry it came from.
+type ProviderDocsSource struct {
+	// Registry is the upstream registry's base URL.
+	Registry string
+	// URL is the page on the upstream registry's website.
+	URL string
+	// Attribution is a sentence for the UI to show with the content.
+	Attribution string
+}
+
+// ProviderDocsIndex lists the doc pages of a provider version.
+type ProviderDocsIndex struct {
+	Docs      []models.ProviderVersionDoc
+	Source    ProviderDocsSource
+	FetchedAt time.Time
+}
+
+// ProviderDocPage is one doc page with its markdown content.
+type ProviderDocPage struct {
+	Doc       models.ProviderVersionDoc
+	Content   string
+	Source    ProviderDocsSource
+	FetchedAt time.Time
+	// Stale is set when the cached copy is past the mirror's TTL and the
+	// upstream registry could not be reached to refresh it.
+	Stale bool
+}
+
+// ProviderDocsPassthrough fetches and caches upstream documentation for
+// mirrored providers.
+type ProviderDocsPassthrough struct {
+	providerRepo *repositories.ProviderRepository
+	docsRepo     *repositories.ProviderDocsRepository
+	mirrorRepo   *repositories.MirrorRepository
+	orgRepo      *repositories.OrganizationRepository
+	policies     ModulePolicyEvaluator
+
+	// newUpstream builds the upstream client for a mirror's registry URL.
+	// Tests override it via SetUpstreamFactory.
+	newUpstream func(registryURL string) ProviderDocsUpstream
+	egressGuard *httpsafe.Guard
+	now         func() time.Time
+
+	// fetches collapses concurrent first requests for the same index or page.
+	fetches singleflight.Group
+}
+
+// NewProviderDocsPassthrough constructs a ProviderDocsPassthrough.
+func NewProviderDocsPassthrough(
+	providerRepo *repositories.ProviderRepository,
+	docsRepo *repositories.ProviderDocsRepository,
+	mirrorRepo *repositories.MirrorRepository,
+	orgRepo *repositories.OrganizationRepository,
+	policies ModulePolicyEvaluator,
+) *ProviderDocsPassthrough {
+	s := &ProviderDocsPassthrough{
+		providerRepo: providerRepo,
+		docsRepo:     docsRepo,
+		mirrorRepo:   mirrorRepo,
+		orgRepo:      orgRepo,
+		policies:     policies,
+		now:          time.Now,
+	}
+	s.newUpstream = func(registryURL string) ProviderDocsUpstream {
+		return mirror.NewUpstreamRegistryWithGuard(registryURL, s.egressGuard)
+	}
+	return s
+}
+
+// SetEgressGuard installs the operator-configured egress guard used by the
+// default upstream-client factory. nil keeps the strict default policy.
+func (s *ProviderDocsPassthrough) SetEgressGuard(g *httpsafe.Guard) {
+	s.egressGuard = g
+}
+
+// SetUpstreamFactory replaces the upstream-client factory. Intended for tests.
+func (s *ProviderDocsPassthrough) SetUpstreamFactory(f func(registryURL string) ProviderDocsUpstream) {
+	s.newUpstream = f
+}
+
+// docsTarget is a provider version resolved to the mirror that synced it.
+type docsTarget struct {
+	version   string
+	versionID string
+	mirrored  *models.MirroredProvider
+	mirror    *models.MirrorConfiguration
+}
+
+// resolve finds the provider version and checks that its mirror enables the
+// passthrough and that the mirror policies allow its upstream provider.
+func (s *ProviderDocsPassthrough) resolve(ctx context.Context, namespace, providerType, version string) (*docsTarget, error) {
+	org, err := s.orgRepo.GetDefaultOrganization(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get default organization: %w", err)
+	}
+	if org == nil {
+		return nil, errors.New("default organization not found")
+	}
+	provider, err := s.providerRepo.GetProvider(ctx, org.ID, namespace, providerType)
+	if err != nil {
+		return nil, fmt.Errorf("get provider: %w", err)
+	}
+	if provider == nil {
+		return nil, ErrProviderDocsNotFound
+	}
+	pv, err := s.providerRepo.GetVersion(ctx, provider.ID, version)
+	if err != nil {
+		return nil, fmt.Errorf("get provider version: %w", err)
+	}
+	if pv == nil {
+		return nil, ErrProviderDocsNotFound
+	}
+	// A version held back by the approval gate is not served, as on download.
+	status, err := s.providerRepo.GetVersionApprovalStatus(ctx, pv.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get version approval status: %w", err)
+	}
+	if status != nil && *status != models.VersionApprovalStatusApproved {
+		return nil, ErrProviderDocsNotFound
+	}
+
+	providerID, err := uuid.Parse(provider.ID)
+	if err != nil {
+		return nil, ErrProviderDocsDisabled
+	}
+	mp, err := s.mirrorRepo.GetMirroredProviderByProviderID(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("get mirrored provider: %w", err)
+	}
+	if mp == nil {
+		return nil, ErrProviderDocsDisabled
+	}
+	cfg, err := s.mirrorRepo.GetByID(ctx, mp.MirrorConfigID)
+	if err != nil {
+		return nil, fmt.Errorf("get mirror configuration: %w", err)
+	}
+	if cfg == nil || !cfg.DocsPassthroughEnabled {
+		return nil, ErrProviderDocsDisabled
+	}
+
+	result, err := s.policies.EvaluatePolicies(ctx, cfg.OrganizationID, registryHost(cfg.UpstreamRegistryURL), mp.UpstreamNamespace, mp.UpstreamType)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate mirror policies: %w", err)
+	}
+	if !result.Allowed {
+		return nil, &ProviderDocsDeniedError{Reason: result.Reason}
+	}
+
+	return &docsTarget{version: version, versionID: pv.ID, mirrored: mp, mirror: cfg}, nil
+}
+
+// Index returns the doc pages of a provider version, optionally only those of
+// one category. The index is fetched from the upstream registry on the first
+// request unless the mirror sync already stored it. A version's pages do not
+// change upstream, so the index is not refetched.
+func (s *ProviderDocsPassthrough) Index(ctx context.Context, namespace, providerType, version, category string) (*ProviderDocsIndex, error) {
+	t, err := s.resolve(ctx, namespace, providerType, version)
+	if err != nil {
+		return nil, err
+	}
+	fetchedAt, err := s.ensureIndex(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	var categoryFilter *string
+	if category != "" {
+		categoryFilter = &category
+	}
+	docs, err := s.docsRepo.ListProviderVersionDocs(ctx, t.versionID, categoryFilter, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderDocsIndex{
+		Docs:      docs,
+		Source:    t.source(""),
+		FetchedAt: fetchedAt,
+	}, nil
+}
+
+// Page returns one doc page. The content is served from the cache while it is
+// younger than the mirror's docs_cache_ttl_hours and fetched otherwise; when
+// that fetch fails, an expired cached copy is served marked as stale.
+func (s *ProviderDocsPassthrough) Page(ctx context.Context, namespace, providerType, version, category, slug string) (*ProviderDocPage, error) {
+	t, err := s.resolve(ctx, namespace, providerType, version)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.ensureIndex(ctx, t); err != nil {
+		return nil, err
+	}
+	doc, err := s.docsRepo.GetProviderVersionDocBySlug(ctx, t.versionID, category, slug)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrProviderDocsNotFound
+	}
+
+	page := &ProviderDocPage{Doc: *doc, Source: t.source(doc.Category + "/" + doc.Slug)}
+	cached, cachedAt, err := s.docsRepo.GetProviderVersionDocContent(ctx, doc.ID)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && s.now().Sub(*cachedAt) < t.cacheTTL() {
+		page.Content, page.FetchedAt = *cached, *cachedAt
+		return page, nil
+	}
+
+	v, err, _ := s.fetches.Do("page:"+doc.ID, func() (interface{}, error) {
+		content, err := s.newUpstream(t.mirror.UpstreamRegistryURL).GetProviderDocContent(ctx, doc.UpstreamDocID)
+		if err != nil {
+			return nil, err
+		}
+		fetchedAt := s.now()
+		if err := s.docsRepo.SetProviderVersionDocContent(ctx, doc.ID, content, fetchedAt); err != nil {
+			slog.Warn("provider docs: failed to cache doc page", "doc_id", doc.ID, "error", err)
+		}
+		return &ProviderDocPage{Content: content, FetchedAt: fetchedAt}, nil
+	})
+	if err != nil {
+		if cached == nil {
+			return nil, fmt.Errorf("%w: %v", ErrProviderDocsUpstream, err)
+		}
+		slog.Warn("provider docs: serving stale doc page", "doc_id", doc.ID, "fetched_at", *cachedAt, "error", err)
+		page.Content, page.FetchedAt, page.Stale = *cached, *cachedAt, true
+		return page, nil
+	}
+	fetched := v.(*ProviderDocPage)
+	page.Content, page.FetchedAt = fetched.Content, fetched.FetchedAt
+	return page, nil
+}
+
+// ensureIndex stores the version's doc index if it has none yet and returns
+// when it was stored.
+func (s *ProviderDocsPassthrough) ensureIndex(ctx context.Context, t *docsTarget) (time.Time, error) {
+	fetchedAt, err := s.docsRepo.DocIndexFetchedAt(ctx, t.versionID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if fetchedAt != nil {
+		return *fetchedAt, nil
+	}
+
+	v, err, _ := s.fetches.Do("index:"+t.versionID, func() (interface{}, error) {
+		entries, err := s.newUpstream(t.mirror.UpstreamRegistryURL).GetProviderDocIndexByVersion(ctx,
+			t.mirrored.UpstreamNamespace, t.mirrored.UpstreamType, t.version)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrProviderDocsUpstream, err)
+		}
+		docs := make([]models.ProviderVersionDoc, len(entries))
+		for i, d := range entries {
+			docs[i] = models.ProviderVersionDoc{
+				UpstreamDocID: d.ID,
+				Title:         d.Title,
+				Slug:          d.Slug,
+				Category:      d.Category,
+				Subcategory:   d.Subcategory,
+				Path:          &d.Path,
+				Language:      d.Language,
+			}
+		}
+		if err := s.docsRepo.BulkCreateProviderVersionDocs(ctx, t.versionID, docs); err != nil {
+			return nil, err
+		}
+		return s.now(), nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return v.(time.Time), nil
+}
+
+// cacheTTL is how long a fetched doc page is served from the cache.
+func (t *docsTarget) cacheTTL() time.Duration {
+	if t.mirror.DocsCacheTTLHours <= 0 {
+		return defaultDocsCacheTTL
+	}
+	return time.Duration(t.mirror.DocsCacheTTLHours) * time.Hour
+}
+
+// source attributes the version's docs, or the page at path under them, to
+// the mirror's upstream registry.
+func (t *docsTarget) source(path string) ProviderDocsSource {
+	registry := strings.TrimRight(t.mirror.UpstreamRegistryURL, "/")
+	page := fmt.Sprintf("%s/providers/%s/%s/%s/docs", registry,
+		url.PathEscape(t.mirrored.UpstreamNamespace), url.PathEscape(t.mirrored.UpstreamType), url.PathEscape(t.version))
+	if path != "" && path != "overview/index" {
+		page += "/" + path
+	}
+	return ProviderDocsSource{
+		Registry: registry,
+		URL:      page,
+		Attribution: fmt.Sprintf("Documentation for %s/%s %s is published by %s and cached by this registry.",
+			t.mirrored.UpstreamNamespace, t.mirrored.UpstreamType, t.version, registryHost(registry)),
+	}
+}
+
+// registryHost returns the hostname of a registry base URL, as the mirror
+// policies name registries.
+func registryHost(registryURL string) string {
+	u, err := url.Parse(registryURL)
+	if err != nil || u.Host == "" {
+		return registryURL
+	}
+	return u.Host
+}
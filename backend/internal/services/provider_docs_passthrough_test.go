@@ -0,0 +1,242 @@
+// provider_docs_passthrough_test.go tests ProviderDocsPassthrough with a fake
+// upstream docs client, a fake policy evaluator, and sqlmock for the provider,
+// provider docs, mirror and organization repositories.
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+)
+
+type fakeDocsUpstream struct {
+	index      []mirror.ProviderDocEntry
+	content    string
+	contentErr error
+	fetches    int
+}
+
+func (f *fakeDocsUpstream) GetProviderDocIndexByVersion(_ context.Context, _, _, _ string) ([]mirror.ProviderDocEntry, error) {
+	return f.index, nil
+}
+
+func (f *fakeDocsUpstream) GetProviderDocContent(_ context.Context, _ string) (string, error) {
+	f.fetches++
+	return f.content, f.contentErr
+}
+
+var (
+	docsProviderID = uuid.New()
+	docsMirrorID   = uuid.New()
+
+	docsMirroredCols = []string{"id", "mirror_config_id", "provider_id", "upstream_namespace", "upstream_type",
+		"last_synced_at", "last_sync_version", "sync_enabled", "created_at"}
+	docsMirrorCols = []string{"id", "name", "upstream_registry_url", "organization_id", "enabled",
+		"docs_passthrough_enabled", "docs_cache_ttl_hours", "created_at", "updated_at"}
+	docsEntryCols = []string{"id", "provider_version_id", "upstream_doc_id", "title", "slug", "category",
+		"subcategory", "path", "language"}
+)
+
+func newProviderDocsEnv(t *testing.T, upstream *fakeDocsUpstream, policy *fakePolicyEvaluator) (*ProviderDocsPassthrough, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	svc := NewProviderDocsPassthrough(
+		repositories.NewProviderRepository(db),
+		repositories.NewProviderDocsRepository(db),
+		repositories.NewMirrorRepository(sqlx.NewDb(db, "sqlmock")),
+		repositories.NewOrganizationRepository(db),
+		policy,
+	)
+	svc.SetUpstreamFactory(func(string) ProviderDocsUpstream { return upstream })
+	return svc, mock
+}
+
+// expectDocsProvider expects the lookups of hashicorp/aws 5.0.0 up to its
+// mirrored-provider row; mirrored=false returns none.
+func expectDocsProvider(mock sqlmock.Sqlmock, mirrored bool) {
+	expectDefaultOrg(mock)
+	mock.ExpectQuery("SELECT.*FROM providers p").
+		WillReturnRows(sqlmock.NewRows(providerGetCols).AddRow(docsProviderID.String(), proxyOrgID, "hashicorp", "aws",
+			nil, nil, nil, time.Now(), time.Now(), nil))
+	mock.ExpectQuery("SELECT.*FROM provider_versions").
+		WillReturnRows(sqlmock.NewRows(versionGetCols).AddRow("ver-1", docsProviderID.String(), "5.0.0", []byte(`["6.0"]`),
+			"", "", "", nil, nil, nil, false, nil, nil, time.Now()))
+	mock.ExpectQuery("SELECT approval_status FROM mirrored_provider_versions").
+		WillReturnRows(sqlmock.NewRows([]string{"approval_status"}))
+	rows := sqlmock.NewRows(docsMirroredCols)
+	if mirrored {
+		rows.AddRow(uuid.New(), docsMirrorID, docsProviderID, "hashicorp", "aws", time.Now(), nil, true, time.Now())
+	}
+	mock.ExpectQuery("SELECT.*FROM mirrored_providers").WillReturnRows(rows)
+}
+
+func expectDocsMirror(mock sqlmock.Sqlmock, passthrough bool, ttlHours int) {
+	mock.ExpectQuery("SELECT.*FROM mirror_configurations").
+		WillReturnRows(sqlmock.NewRows(docsMirrorCols).AddRow(docsMirrorID, "upstream", "https://registry.terraform.io/",
+			nil, true, passthrough, ttlHours, time.Now(), time.Now()))
+}
+
+func expectDocPage(mock sqlmock.Sqlmock, content interface{}, fetchedAt interface{}) {
+	mock.ExpectQuery("SELECT MIN\\(created_at\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(time.Now().Add(-48 * time.Hour)))
+	mock.ExpectQuery("SELECT id, provider_version_id.*FROM provider_version_docs").
+		WithArgs("ver-1", "resources", "instance").
+		WillReturnRows(sqlmock.NewRows(docsEntryCols).AddRow("doc-1", "ver-1", "12345", "aws_instance", "instance",
+			"resources", nil, nil, "hcl"))
+	mock.ExpectQuery("SELECT content, content_fetched_at FROM provider_version_docs").
+		WithArgs("doc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"content", "content_fetched_at"}).AddRow(content, fetchedAt))
+}
+
+func TestProviderDocs_NotMirrored(t *testing.T) {
+	svc, mock := newProviderDocsEnv(t, &fakeDocsUpstream{}, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDocsProvider(mock, false)
+
+	_, err := svc.Index(context.Background(), "hashicorp", "aws", "5.0.0", "")
+	if !errors.Is(err, ErrProviderDocsDisabled) {
+		t.Fatalf("err = %v, want ErrProviderDocsDisabled", err)
+	}
+}
+
+func TestProviderDocs_PassthroughNotEnabled(t *testing.T) {
+	upstream := &fakeDocsUpstream{}
+	svc, mock := newProviderDocsEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDocsProvider(mock, true)
+	expectDocsMirror(mock, false, 24)
+
+	_, err := svc.Page(context.Background(), "hashicorp", "aws", "5.0.0", "resources", "instance")
+	if !errors.Is(err, ErrProviderDocsDisabled) {
+		t.Fatalf("err = %v, want ErrProviderDocsDisabled", err)
+	}
+	if upstream.fetches != 0 {
+		t.Errorf("upstream fetched %d times, want none", upstream.fetches)
+	}
+}
+
+func TestProviderDocs_PolicyDenied(t *testing.T) {
+	policy := &fakePolicyEvaluator{result: &models.PolicyEvaluationResult{Allowed: false, Reason: "No matching policy found"}}
+	svc, mock := newProviderDocsEnv(t, &fakeDocsUpstream{}, policy)
+	expectDocsProvider(mock, true)
+	expectDocsMirror(mock, true, 24)
+
+	_, err := svc.Index(context.Background(), "hashicorp", "aws", "5.0.0", "")
+	var denied *ProviderDocsDeniedError
+	if !errors.As(err, &denied) {
+		t.Fatalf("err = %v, want *ProviderDocsDeniedError", err)
+	}
+}
+
+func TestProviderDocs_Index_FetchedOnFirstRequest(t *testing.T) {
+	upstream := &fakeDocsUpstream{index: []mirror.ProviderDocEntry{
+		{ID: "12345", Title: "aws_instance", Slug: "instance", Category: "resources", Path: "website/docs/r/instance.html.md", Language: "hcl"},
+	}}
+	svc, mock := newProviderDocsEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDocsProvider(mock, true)
+	expectDocsMirror(mock, true, 24)
+	mock.ExpectQuery("SELECT MIN\\(created_at\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(nil))
+	mock.ExpectExec("INSERT INTO provider_version_docs").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, provider_version_id.*FROM provider_version_docs").
+		WillReturnRows(sqlmock.NewRows(docsEntryCols).AddRow("doc-1", "ver-1", "12345", "aws_instance", "instance",
+			"resources", nil, "website/docs/r/instance.html.md", "hcl"))
+
+	index, err := svc.Index(context.Background(), "hashicorp", "aws", "5.0.0", "")
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if len(index.Docs) != 1 || index.FetchedAt.IsZero() {
+		t.Errorf("index = %+v", index)
+	}
+	if index.Source.URL != "https://registry.terraform.io/providers/hashicorp/aws/5.0.0/docs" {
+		t.Errorf("source URL = %q", index.Source.URL)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProviderDocs_Page_FetchesAndCaches(t *testing.T) {
+	upstream := &fakeDocsUpstream{content: "# aws_instance"}
+	svc, mock := newProviderDocsEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDocsProvider(mock, true)
+	expectDocsMirror(mock, true, 24)
+	expectDocPage(mock, nil, nil)
+	mock.ExpectExec("UPDATE provider_version_docs SET content").
+		WithArgs("doc-1", "# aws_instance", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	page, err := svc.Page(context.Background(), "hashicorp", "aws", "5.0.0", "resources", "instance")
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if page.Content != "# aws_instance" || page.Stale {
+		t.Errorf("page = %+v", page)
+	}
+	if page.Source.URL != "https://registry.terraform.io/providers/hashicorp/aws/5.0.0/docs/resources/instance" ||
+		!strings.Contains(page.Source.Attribution, "registry.terraform.io") {
+		t.Errorf("source = %+v", page.Source)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProviderDocs_Page_ServesFreshCacheWithoutUpstream(t *testing.T) {
+	upstream := &fakeDocsUpstream{}
+	svc, mock := newProviderDocsEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDocsProvider(mock, true)
+	expectDocsMirror(mock, true, 24)
+	cachedAt := time.Now().Add(-time.Hour)
+	expectDocPage(mock, "# cached", cachedAt)
+
+	page, err := svc.Page(context.Background(), "hashicorp", "aws", "5.0.0", "resources", "instance")
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if page.Content != "# cached" || !page.FetchedAt.Equal(cachedAt) || upstream.fetches != 0 {
+		t.Errorf("page = %+v, upstream fetches = %d", page, upstream.fetches)
+	}
+}
+
+func TestProviderDocs_Page_ServesStaleWhenUpstreamFails(t *testing.T) {
+	upstream := &fakeDocsUpstream{contentErr: errors.New("connection refused")}
+	svc, mock := newProviderDocsEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDocsProvider(mock, true)
+	expectDocsMirror(mock, true, 1)
+	expectDocPage(mock, "# cached", time.Now().Add(-2*time.Hour))
+
+	page, err := svc.Page(context.Background(), "hashicorp", "aws", "5.0.0", "resources", "instance")
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if page.Content != "# cached" || !page.Stale || upstream.fetches != 1 {
+		t.Errorf("page = %+v, upstream fetches = %d", page, upstream.fetches)
+	}
+}
+
+func TestProviderDocs_Page_UpstreamFailureWithoutCache(t *testing.T) {
+	upstream := &fakeDocsUpstream{contentErr: errors.New("connection refused")}
+	svc, mock := newProviderDocsEnv(t, upstream, &fakePolicyEvaluator{result: allowedPolicy})
+	expectDocsProvider(mock, true)
+	expectDocsMirror(mock, true, 24)
+	expectDocPage(mock, nil, nil)
+
+	_, err := svc.Page(context.Background(), "hashicorp", "aws", "5.0.0", "resources", "instance")
+	if !errors.Is(err, ErrProviderDocsUpstream) {
+		t.Fatalf("err = %v, want ErrProviderDocsUpstream", err)
+	}
+}
@@ -0,0 +1,192 @@
+// provider_resigner.go re-signs mirrored provider versions with the registry's
+// own OpenPGP key (mirror_signing). For each version it rebuilds SHA256SUMS
+// from the checksums recorded at sync time, signs it, stores both files under
+// providers/<ns>/<type>/<version>/resigned/<key-id>/ and records the result in
+// provider_version_resignatures. The upstream SHA256SUMS URLs and key stay on
+// the provider_versions row for audit.
+//
+// Only versions whose upstream signature verified during sync are re-signed:
+// the registry's key vouches for content whose origin was proven, never for
+// content it could not check.
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// ErrResignMirrorNotFound is returned by ResignMirror for an unknown mirror.
+var ErrResignMirrorNotFound = errors.New("mirror configuration not found")
+
+// maxResignErrors caps the per-version error messages kept in a ResignSummary.
+const maxResignErrors = 20
+
+// ResignSummary reports the outcome of re-signing a mirror's versions.
+type ResignSummary struct {
+	KeyID    string   `json:"key_id"`
+	Versions int      `json:"versions"`
+	Resigned int      `json:"resigned"`
+	Skipped  int      `json:"skipped"` // upstream signature was never verified
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// ProviderResigner re-signs mirrored provider versions.
+type ProviderResigner struct {
+	providerRepo   *repositories.ProviderRepository
+	mirrorRepo     *repositories.MirrorRepository
+	storageBackend storage.Storage
+	signer         mirror.Signer
+}
+
+// NewProviderResigner creates a ProviderResigner that signs with signer.
+func NewProviderResigner(
+	providerRepo *repositories.ProviderRepository,
+	mirrorRepo *repositories.MirrorRepository,
+	storageBackend storage.Storage,
+	signer mirror.Signer,
+) *ProviderResigner {
+	return &ProviderResigner{
+		providerRepo:   providerRepo,
+		mirrorRepo:     mirrorRepo,
+		storageBackend: storageBackend,
+		signer:         signer,
+	}
+}
+
+// KeyID is the long key ID of the registry signing key.
+func (r *ProviderResigner) KeyID() string { return r.signer.KeyID() }
+
+// ResignVersion signs one provider version's SHA256SUMS with the registry key
+// and records the signature. Re-running it replaces the previous signature.
+func (r *ProviderResigner) ResignVersion(ctx context.Context, provider *models.Provider, versionID, version string) error {
+	sums, err := r.buildSHA256SUMS(ctx, versionID)
+	if err != nil {
+		return err
+	}
+	sig, err := r.signer.Sign(ctx, sums)
+	if err != nil {
+		return fmt.Errorf("failed to sign SHA256SUMS: %w", err)
+	}
+
+	sumsKey := storage.ProviderFileKey(provider.Namespace, provider.Type, version, "resigned", r.signer.KeyID(), "SHA256SUMS")
+	sigKey := storage.ProviderFileKey(provider.Namespace, provider.Type, version, "resigned", r.signer.KeyID(), "SHA256SUMS.sig")
+	if _, err := r.storageBackend.Upload(ctx, sumsKey, bytes.NewReader(sums), int64(len(sums))); err != nil {
+		return fmt.Errorf("failed to store re-signed SHA256SUMS: %w", err)
+	}
+	if _, err := r.storageBackend.Upload(ctx, sigKey, bytes.NewReader(sig), int64(len(sig))); err != nil {
+		return fmt.Errorf("failed to store SHA256SUMS signature: %w", err)
+	}
+
+	return r.providerRepo.UpsertVersionResignature(ctx, &models.ProviderVersionResignature{
+		ProviderVersionID:         versionID,
+		KeyID:                     r.signer.KeyID(),
+		GPGPublicKey:              r.signer.PublicKey(),
+		ShasumStorageKey:          sumsKey,
+		ShasumSignatureStorageKey: sigKey,
+	})
+}
+
+// ResignMirror re-signs every GPG-verified version synced by a mirror, e.g. to
+// backfill versions synced before re-signing was enabled or after a key
+// rotation. Per-version failures are counted in the summary rather than
+// aborting the run.
+func (r *ProviderResigner) ResignMirror(ctx context.Context, mirrorID uuid.UUID) (*ResignSummary, error) {
+	cfg, err := r.mirrorRepo.GetByID(ctx, mirrorID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, ErrResignMirrorNotFound
+	}
+
+	mirrored, err := r.mirrorRepo.ListMirroredProviders(ctx, mirrorID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ResignSummary{KeyID: r.signer.KeyID()}
+	for _, mp := range mirrored {
+		versions, err := r.mirrorRepo.ListMirroredProviderVersions(ctx, mp.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		provider, err := r.providerRepo.GetProviderByID(ctx, mp.ProviderID.String())
+		if err != nil {
+			return nil, err
+		}
+		if provider == nil {
+			continue
+		}
+		for _, v := range versions {
+			summary.Versions++
+			if !v.GPGVerified {
+				summary.Skipped++
+				continue
+			}
+			if err := r.ResignVersion(ctx, provider, v.ProviderVersionID.String(), v.UpstreamVersion); err != nil {
+				summary.Failed++
+				if len(summary.Errors) < maxResignErrors {
+					summary.Errors = append(summary.Errors, fmt.Sprintf("%s/%s@%s: %v", provider.Namespace, provider.Type, v.UpstreamVersion, err))
+				}
+				continue
+			}
+			summary.Resigned++
+		}
+	}
+	return summary, nil
+}
+
+// buildSHA256SUMS renders the version's checksums in sha256sum format
+// ("<hex>  <filename>"), sorted by filename. The full upstream list recorded
+// at sync time is preferred; versions synced before it was recorded fall back
+// to the locally stored platforms.
+func (r *ProviderResigner) buildSHA256SUMS(ctx context.Context, versionID string) ([]byte, error) {
+	sums := map[string]string{}
+	shasums, err := r.providerRepo.ListProviderVersionShasums(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range shasums {
+		sums[s.Filename] = s.SHA256Hex
+	}
+	if len(sums) == 0 {
+		platforms, err := r.providerRepo.ListPlatforms(ctx, versionID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range platforms {
+			if p.Shasum != "" {
+				sums[p.Filename] = p.Shasum
+			}
+		}
+	}
+	if len(sums) == 0 {
+		return nil, errors.New("no checksums recorded for version")
+	}
+
+	filenames := make([]string, 0, len(sums))
+	for f := range sums {
+		filenames = append(filenames, f)
+	}
+	sort.Strings(filenames)
+	var b strings.Builder
+	for _, f := range filenames {
+		fmt.Fprintf(&b, "%s  %s\n", strings.ToLower(sums[f]), f)
+	}
+	return []byte(b.String()), nil
+}
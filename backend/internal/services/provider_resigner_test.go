@@ -0,0 +1,167 @@
+// provider_resigner_test.go tests ProviderResigner with a fake signer, the
+// in-memory storage backend from module_proxy_test.go, and sqlmock for the
+// provider and mirror repositories.
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+type fakeSigner struct {
+	signed [][]byte
+	err    error
+}
+
+func (f *fakeSigner) Sign(_ context.Context, data []byte) ([]byte, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.signed = append(f.signed, data)
+	return []byte("SIG"), nil
+}
+func (f *fakeSigner) PublicKey() string { return "PUBLIC KEY" }
+func (f *fakeSigner) KeyID() string     { return "0123456789ABCDEF" }
+
+func newResignerEnv(t *testing.T, signer *fakeSigner) (*ProviderResigner, sqlmock.Sqlmock, sqlmock.Sqlmock, *memStorage) {
+	t.Helper()
+	provDB, pmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New (provider): %v", err)
+	}
+	t.Cleanup(func() { provDB.Close() })
+	mirrorDB, mmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New (mirror): %v", err)
+	}
+	t.Cleanup(func() { mirrorDB.Close() })
+
+	store := &memStorage{}
+	r := NewProviderResigner(
+		repositories.NewProviderRepository(provDB),
+		repositories.NewMirrorRepository(sqlx.NewDb(mirrorDB, "sqlmock")),
+		store, signer,
+	)
+	return r, pmock, mmock, store
+}
+
+var resignProvider = &models.Provider{ID: "prov-1", Namespace: "hashicorp", Type: "aws"}
+
+func TestResignVersion_SignsSortedSums(t *testing.T) {
+	signer := &fakeSigner{}
+	r, pmock, _, store := newResignerEnv(t, signer)
+	resignedSumsKey := storage.ProviderFileKey("hashicorp", "aws", "5.0.0", "resigned", "0123456789ABCDEF", "SHA256SUMS")
+	resignedSigKey := storage.ProviderFileKey("hashicorp", "aws", "5.0.0", "resigned", "0123456789ABCDEF", "SHA256SUMS.sig")
+
+	pmock.ExpectQuery("SELECT.*FROM provider_version_shasums").WithArgs("ver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"provider_version_id", "filename", "sha256_hex"}).
+			AddRow("ver-1", "terraform-provider-aws_5.0.0_linux_amd64.zip", "BBBB").
+			AddRow("ver-1", "terraform-provider-aws_5.0.0_darwin_arm64.zip", "aaaa"))
+	pmock.ExpectQuery("INSERT INTO provider_version_resignatures").
+		WithArgs("ver-1", "0123456789ABCDEF", "PUBLIC KEY",
+			resignedSumsKey, resignedSigKey).
+		WillReturnRows(sqlmock.NewRows([]string{"signed_at"}).AddRow(time.Now()))
+
+	if err := r.ResignVersion(context.Background(), resignProvider, "ver-1", "5.0.0"); err != nil {
+		t.Fatalf("ResignVersion: %v", err)
+	}
+
+	want := "aaaa  terraform-provider-aws_5.0.0_darwin_arm64.zip\n" +
+		"bbbb  terraform-provider-aws_5.0.0_linux_amd64.zip\n"
+	if got := string(store.files[resignedSumsKey]); got != want {
+		t.Errorf("SHA256SUMS = %q, want %q", got, want)
+	}
+	if len(signer.signed) != 1 || string(signer.signed[0]) != want {
+		t.Errorf("signed = %q", signer.signed)
+	}
+	if got := string(store.files[resignedSigKey]); got != "SIG" {
+		t.Errorf("signature = %q", got)
+	}
+	if err := pmock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResignVersion_FallsBackToPlatforms(t *testing.T) {
+	signer := &fakeSigner{}
+	r, pmock, _, _ := newResignerEnv(t, signer)
+
+	pmock.ExpectQuery("SELECT.*FROM provider_version_shasums").
+		WillReturnRows(sqlmock.NewRows([]string{"provider_version_id", "filename", "sha256_hex"}))
+	pmock.ExpectQuery("SELECT.*FROM provider_platforms").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "provider_version_id", "os", "arch", "filename", "storage_path", "storage_backend", "size_bytes", "shasum", "h1_hash", "download_count"}).
+			AddRow("p-1", "ver-1", "linux", "amd64", "tf_linux_amd64.zip", "path", "local", 10, "cccc", nil, 0))
+	pmock.ExpectQuery("INSERT INTO provider_version_resignatures").
+		WillReturnRows(sqlmock.NewRows([]string{"signed_at"}).AddRow(time.Now()))
+
+	if err := r.ResignVersion(context.Background(), resignProvider, "ver-1", "5.0.0"); err != nil {
+		t.Fatalf("ResignVersion: %v", err)
+	}
+	if len(signer.signed) != 1 || string(signer.signed[0]) != "cccc  tf_linux_amd64.zip\n" {
+		t.Errorf("signed = %q", signer.signed)
+	}
+}
+
+func TestResignVersion_NoChecksums(t *testing.T) {
+	r, pmock, _, _ := newResignerEnv(t, &fakeSigner{})
+
+	pmock.ExpectQuery("SELECT.*FROM provider_version_shasums").
+		WillReturnRows(sqlmock.NewRows([]string{"provider_version_id", "filename", "sha256_hex"}))
+	pmock.ExpectQuery("SELECT.*FROM provider_platforms").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "provider_version_id", "os", "arch", "filename", "storage_path", "storage_backend", "size_bytes", "shasum", "h1_hash", "download_count"}))
+
+	err := r.ResignVersion(context.Background(), resignProvider, "ver-1", "5.0.0")
+	if err == nil || !strings.Contains(err.Error(), "no checksums") {
+		t.Fatalf("err = %v, want no checksums error", err)
+	}
+}
+
+func TestResignMirror_NotFound(t *testing.T) {
+	r, _, mmock, _ := newResignerEnv(t, &fakeSigner{})
+	mmock.ExpectQuery("FROM mirror_configurations").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, err := r.ResignMirror(context.Background(), uuid.New()); !errors.Is(err, ErrResignMirrorNotFound) {
+		t.Fatalf("err = %v, want ErrResignMirrorNotFound", err)
+	}
+}
+
+func TestResignMirror_SkipsUnverifiedAndCountsFailures(t *testing.T) {
+	r, pmock, mmock, _ := newResignerEnv(t, &fakeSigner{err: errors.New("signer down")})
+	mirrorID, mpID, provID := uuid.New(), uuid.New(), uuid.New()
+
+	mmock.ExpectQuery("FROM mirror_configurations").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(mirrorID, "hashicorp"))
+	mmock.ExpectQuery("FROM mirrored_providers").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "mirror_config_id", "provider_id", "upstream_namespace", "upstream_type"}).
+			AddRow(mpID, mirrorID, provID, "hashicorp", "aws"))
+	mmock.ExpectQuery("FROM mirrored_provider_versions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "mirrored_provider_id", "provider_version_id", "upstream_version", "gpg_verified"}).
+			AddRow(uuid.New(), mpID, uuid.New(), "5.1.0", true).
+			AddRow(uuid.New(), mpID, uuid.New(), "5.0.0", false))
+	pmock.ExpectQuery("SELECT.*FROM providers").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organization_id", "namespace", "type", "description", "source", "created_by", "created_at", "updated_at", "created_by_name"}).
+			AddRow(provID.String(), nil, "hashicorp", "aws", nil, nil, nil, time.Now(), time.Now(), nil))
+	pmock.ExpectQuery("SELECT.*FROM provider_version_shasums").
+		WillReturnRows(sqlmock.NewRows([]string{"provider_version_id", "filename", "sha256_hex"}).AddRow("v", "f.zip", "dddd"))
+
+	summary, err := r.ResignMirror(context.Background(), mirrorID)
+	if err != nil {
+		t.Fatalf("ResignMirror: %v", err)
+	}
+	if summary.Versions != 2 || summary.Skipped != 1 || summary.Failed != 1 || summary.Resigned != 0 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if len(summary.Errors) != 1 || !strings.Contains(summary.Errors[0], "hashicorp/aws@5.1.0") {
+		t.Errorf("errors = %v", summary.Errors)
+	}
+}
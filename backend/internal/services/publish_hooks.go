@@ -0,0 +1,406 @@
+// publish_hooks.go calls an organization's pre-publish hook: an external
+// HTTPS endpoint that must approve every module version before it is stored.
+// Every publish path except mirror sync (uploads, publishes from a URL,
+// registry-import through the upload API, and SCM tag publishes) calls Check
+// once the archive is packaged and its digest known, before anything is
+// written to storage.
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/httpsafe"
+)
+
+// Headers sent with every publish hook request. The signature is
+// "sha256=" followed by the hex HMAC-SHA256 of the request body, keyed with
+// the hook's secret.
+const (
+	PublishHookSignatureHeader     = "X-Registry-Signature-256"
+	PublishHookEventHeader         = "X-Registry-Event"
+	PublishHookDeliveryHeader      = "X-Registry-Delivery"
+	PublishHookSchemaVersionHeader = "X-Registry-Schema-Version"
+)
+
+// PublishHookSchemaVersion is the version of the publish hook event JSON
+// shape. Like events.SchemaVersion, it is bumped when a field is removed or
+// changes meaning, not when one is added.
+const PublishHookSchemaVersion = 1
+
+// Publish hook event types.
+const (
+	PublishHookEventPublish = "module_version.publish"
+	PublishHookEventTest    = "publish_hook.test"
+)
+
+// MaxPublishHookTimeout is the longest timeout a hook may be configured with.
+const MaxPublishHookTimeout = 60 * time.Second
+
+// maxPublishHookResponse caps how much of a hook's response body is read.
+const maxPublishHookResponse = 64 << 10
+
+// ErrPublishHookDenied is matched by the error Check returns when the hook
+// denies a publish; the error is a *PublishHookDeniedError carrying the reason.
+var ErrPublishHookDenied = errors.New("publish denied by the organization's pre-publish hook")
+
+// ErrPublishHookUnavailable is returned by Check when the hook gave no valid
+// answer and fails closed.
+var ErrPublishHookUnavailable = errors.New("the organization's pre-publish hook did not answer")
+
+// PublishHookDeniedError is returned by Check when the hook denies a publish.
+type PublishHookDeniedError struct {
+	Reason string
+}
+
+func (e *PublishHookDeniedError) Error() string {
+	if e.Reason == "" {
+		return ErrPublishHookDenied.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrPublishHookDenied, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrPublishHookDenied) match.
+func (e *PublishHookDeniedError) Is(target error) bool { return target == ErrPublishHookDenied }
+
+// ModuleVersionPublish describes a module version about to be published.
+type ModuleVersionPublish struct {
+	OrganizationID string
+	Source         string // models.PublishHookSource*
+	Namespace      string
+	Name           string
+	System         string
+	Version        string
+	Checksum       string // SHA-256 (hex) of the archive
+	SizeBytes      int64
+	PublishedBy    *string
+	CommitSHA      string // SCM publishes only
+	TagName        string // SCM publishes only
+	// DryRun marks a publish that only validates; the hook is told so and
+	// the call is not recorded in the delivery log.
+	DryRun bool
+}
+
+// publishHookEvent is the JSON body POSTed to a hook.
+type publishHookEvent struct {
+	Event          string    `json:"event"`
+	SchemaVersion  int       `json:"schema_version"`
+	DeliveryID     string    `json:"delivery_id"`
+	OrganizationID string    `json:"organization_id"`
+	Source         string    `json:"source"`
+	Namespace      string    `json:"namespace"`
+	Name           string    `json:"name"`
+	System         string    `json:"system"`
+	Version        string    `json:"version"`
+	Checksum       string    `json:"checksum"`
+	SizeBytes      int64     `json:"size_bytes"`
+	PublishedBy    *string   `json:"published_by,omitempty"`
+	CommitSHA      string    `json:"commit_sha,omitempty"`
+	TagName        string    `json:"tag_name,omitempty"`
+	DryRun         bool      `json:"dry_run,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// publishHookDecision is the answer a hook must return with a 2xx status.
+type publishHookDecision struct {
+	Allow  *bool  `json:"allow"`
+	Reason string `json:"reason"`
+}
+
+// publishHookStore is the subset of PublishHookRepository PublishHooks needs.
+type publishHookStore interface {
+	Get(ctx context.Context, orgID string) (*models.PublishHook, error)
+	CreateDelivery(ctx context.Context, d *models.PublishHookDelivery) error
+}
+
+// PublishHooks calls organizations' pre-publish hooks. A nil *PublishHooks
+// approves every publish.
+type PublishHooks struct {
+	store  publishHookStore
+	cipher *crypto.TokenCipher
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewPublishHooks creates a PublishHooks. Hook requests go through the egress
+// guard, so an endpoint on a private network must be on the egress allowlist.
+func NewPublishHooks(repo *repositories.PublishHookRepository, cipher *crypto.TokenCipher, egress *httpsafe.Guard) *PublishHooks {
+	return &PublishHooks{
+		store:  repo,
+		cipher: cipher,
+		client: httpsafe.NewClient(MaxPublishHookTimeout, egress),
+		now:    time.Now,
+	}
+}
+
+// SetHTTPClient replaces the HTTP client used to call hooks. Intended for
+// tests.
+func (h *PublishHooks) SetHTTPClient(c *http.Client) {
+	h.client = c
+}
+
+// Check asks p's organization's hook, when it has an enabled one, whether p
+// may be published. It returns an error matching ErrPublishHookDenied when the
+// hook denies the publish, and one wrapping ErrPublishHookUnavailable when the
+// hook gave no valid answer and fails closed. Every call except a dry run is
+// recorded in the hook's delivery log.
+func (h *PublishHooks) Check(ctx context.Context, p *ModuleVersionPublish) error {
+	if h == nil || p.OrganizationID == "" {
+		return nil
+	}
+	hook, err := h.store.Get(ctx, p.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if hook == nil || !hook.Enabled {
+		return nil
+	}
+
+	d := h.deliver(ctx, hook, PublishHookEventPublish, p)
+	switch d.Outcome {
+	case models.PublishHookOutcomeAllowed:
+		return nil
+	case models.PublishHookOutcomeDenied:
+		reason := ""
+		if d.Reason != nil {
+			reason = *d.Reason
+		}
+		return &PublishHookDeniedError{Reason: reason}
+	case models.PublishHookOutcomeFailedOpen:
+		slog.Warn("publish hook: no valid answer, failing open",
+			"organization_id", p.OrganizationID, "namespace", p.Namespace, "name", p.Name,
+			"system", p.System, "version", p.Version, "error", *d.Error)
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrPublishHookUnavailable, *d.Error)
+}
+
+// PublishHookTestResult reports a connectivity test of a hook.
+type PublishHookTestResult struct {
+	// Delivery is the signed test request, as recorded in the delivery log.
+	// Its outcome is what a real publish would have done.
+	Delivery *models.PublishHookDelivery `json:"delivery"`
+	// BadSignatureStatus is the status the endpoint returned for a copy of
+	// the request signed with the wrong key; unset when it did not answer.
+	BadSignatureStatus *int `json:"bad_signature_status,omitempty"`
+	// SignatureVerified reports that the endpoint rejected the badly signed
+	// copy with a 4xx status.
+	SignatureVerified bool `json:"signature_verified"`
+	// Ready reports that the endpoint answered the signed request with a
+	// valid decision and verified the signature, so enforcement can be
+	// enabled.
+	Ready bool `json:"ready"`
+}
+
+// Test sends hook a signed test event and then a copy signed with the wrong
+// key, whether or not the hook is enabled. It does not fail when the endpoint
+// misbehaves; the result says how.
+func (h *PublishHooks) Test(ctx context.Context, hook *models.PublishHook) *PublishHookTestResult {
+	p := &ModuleVersionPublish{
+		OrganizationID: hook.OrganizationID,
+		Source:         models.PublishHookSourceTest,
+		Namespace:      "registry-test",
+		Name:           "publish-hook",
+		System:         "test",
+		Version:        "0.0.0",
+		Checksum:       hex.EncodeToString(sha256.New().Sum(nil)),
+	}
+	res := &PublishHookTestResult{Delivery: h.deliver(ctx, hook, PublishHookEventTest, p)}
+
+	badKey := make([]byte, 32)
+	if _, err := rand.Read(badKey); err == nil {
+		body, _ := json.Marshal(h.event(PublishHookEventTest, uuid.New().String(), p))
+		attemptCtx, cancel := context.WithTimeout(ctx, hookTimeout(hook))
+		resp, err := h.post(attemptCtx, hook.URL, PublishHookEventTest, "", string(badKey), body)
+		cancel()
+		if err == nil {
+			status := resp.StatusCode
+			res.BadSignatureStatus = &status
+			res.SignatureVerified = status >= 400 && status < 500
+		}
+	}
+	allowedOrDenied := res.Delivery.Outcome == models.PublishHookOutcomeAllowed || res.Delivery.Outcome == models.PublishHookOutcomeDenied
+	res.Ready = allowedOrDenied && res.SignatureVerified
+	return res
+}
+
+// deliver calls hook about p, retrying once on a 5xx response, and records the
+// call. The returned delivery's outcome applies the hook's failure mode when
+// no valid answer arrived, in which case its Error is set.
+func (h *PublishHooks) deliver(ctx context.Context, hook *models.PublishHook, event string, p *ModuleVersionPublish) *models.PublishHookDelivery {
+	start := h.now()
+	d := &models.PublishHookDelivery{
+		OrganizationID: hook.OrganizationID,
+		Source:         p.Source,
+		Namespace:      p.Namespace,
+		Name:           p.Name,
+		System:         p.System,
+		Version:        p.Version,
+		Checksum:       p.Checksum,
+	}
+
+	decision, err := h.call(ctx, hook, event, p, d)
+	switch {
+	case err != nil:
+		msg := err.Error()
+		d.Error = &msg
+		d.Outcome = models.PublishHookOutcomeFailedClosed
+		if hook.FailureMode == models.PublishHookFailOpen {
+			d.Outcome = models.PublishHookOutcomeFailedOpen
+		}
+	case *decision.Allow:
+		d.Outcome = models.PublishHookOutcomeAllowed
+	default:
+		d.Outcome = models.PublishHookOutcomeDenied
+	}
+	if decision != nil && decision.Reason != "" {
+		d.Reason = &decision.Reason
+	}
+	d.DurationMs = h.now().Sub(start).Milliseconds()
+
+	if p.DryRun {
+		return d
+	}
+	// The log must not decide the publish, so a failed write is only logged.
+	if err := h.store.CreateDelivery(ctx, d); err != nil {
+		slog.Warn("publish hook: failed to record delivery",
+			"organization_id", hook.OrganizationID, "error", err)
+	}
+	return d
+}
+
+// call POSTs the event to hook and returns its decision. Only a 5xx response
+// is retried, once; a timeout or a connection failure is not. d.Attempts and
+// d.StatusCode are updated as it goes.
+func (h *PublishHooks) call(ctx context.Context, hook *models.PublishHook, event string, p *ModuleVersionPublish, d *models.PublishHookDelivery) (*publishHookDecision, error) {
+	if h.cipher == nil {
+		return nil, errors.New("no encryption key is configured to open the hook secret")
+	}
+	secret, err := h.cipher.Open(hook.EncryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("open hook secret: %w", err)
+	}
+	deliveryID := uuid.New().String()
+	body, err := json.Marshal(h.event(event, deliveryID, p))
+	if err != nil {
+		return nil, fmt.Errorf("encode event: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		d.Attempts = attempt
+		attemptCtx, cancel := context.WithTimeout(ctx, hookTimeout(hook))
+		decision, retry, err := h.attempt(attemptCtx, hook.URL, event, deliveryID, secret, body, d)
+		cancel()
+		if err == nil || !retry || attempt == 2 {
+			return decision, err
+		}
+	}
+}
+
+// attempt makes one request and reports whether a failure may be retried.
+func (h *PublishHooks) attempt(ctx context.Context, url, event, deliveryID, secret string, body []byte, d *models.PublishHookDelivery) (*publishHookDecision, bool, error) {
+	resp, err := h.post(ctx, url, event, deliveryID, secret, body)
+	if err != nil {
+		d.StatusCode = nil
+		return nil, false, err
+	}
+	status := resp.StatusCode
+	d.StatusCode = &status
+	if status >= 500 {
+		return nil, true, fmt.Errorf("hook returned %d", status)
+	}
+	if status < 200 || status >= 300 {
+		return nil, false, fmt.Errorf("hook returned %d", status)
+	}
+	decision := &publishHookDecision{}
+	if err := json.Unmarshal(resp.body, decision); err != nil || decision.Allow == nil {
+		return nil, false, errors.New(`hook response is not a {"allow": bool, "reason": string} decision`)
+	}
+	return decision, false, nil
+}
+
+// hookResponse is a hook's status and (capped) body.
+type hookResponse struct {
+	StatusCode int
+	body       []byte
+}
+
+// post sends one signed request and reads the response.
+func (h *PublishHooks) post(ctx context.Context, url, event, deliveryID, secret string, body []byte) (*hookResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "terraform-registry-publish-hook")
+	req.Header.Set(PublishHookEventHeader, event)
+	req.Header.Set(PublishHookSchemaVersionHeader, strconv.Itoa(PublishHookSchemaVersion))
+	if deliveryID != "" {
+		req.Header.Set(PublishHookDeliveryHeader, deliveryID)
+	}
+	req.Header.Set(PublishHookSignatureHeader, SignPublishHookBody(secret, body))
+
+	resp, err := h.client.Do(req) // #nosec G107 -- hook URL is admin-configured and dialed through the egress guard
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxPublishHookResponse))
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return &hookResponse{StatusCode: resp.StatusCode, body: respBody}, nil
+}
+
+func (h *PublishHooks) event(event, deliveryID string, p *ModuleVersionPublish) *publishHookEvent {
+	return &publishHookEvent{
+		Event:          event,
+		SchemaVersion:  PublishHookSchemaVersion,
+		DeliveryID:     deliveryID,
+		OrganizationID: p.OrganizationID,
+		Source:         p.Source,
+		Namespace:      p.Namespace,
+		Name:           p.Name,
+		System:         p.System,
+		Version:        p.Version,
+		Checksum:       p.Checksum,
+		SizeBytes:      p.SizeBytes,
+		PublishedBy:    p.PublishedBy,
+		CommitSHA:      p.CommitSHA,
+		TagName:        p.TagName,
+		DryRun:         p.DryRun,
+		Timestamp:      h.now().UTC(),
+	}
+}
+
+// SignPublishHookBody returns the signature header value for body:
+// "sha256=" and the hex HMAC-SHA256 of body keyed with secret.
+func SignPublishHookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func hookTimeout(hook *models.PublishHook) time.Duration {
+	timeout := time.Duration(hook.TimeoutSeconds) * time.Second
+	if timeout <= 0 || timeout > MaxPublishHookTimeout {
+		return MaxPublishHookTimeout
+	}
+	return timeout
+}
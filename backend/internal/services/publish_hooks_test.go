@@ -0,0 +1,252 @@
+// publish_hooks_test.go tests PublishHooks against an httptest endpoint with a
+// fake hook store.
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+const testHookSecret = "hook-secret-0123456789"
+
+type fakePublishHookStore struct {
+	hook       *models.PublishHook
+	deliveries []*models.PublishHookDelivery
+}
+
+func (f *fakePublishHookStore) Get(_ context.Context, _ string) (*models.PublishHook, error) {
+	return f.hook, nil
+}
+
+func (f *fakePublishHookStore) CreateDelivery(_ context.Context, d *models.PublishHookDelivery) error {
+	f.deliveries = append(f.deliveries, d)
+	return nil
+}
+
+// newTestPublishHooks returns a PublishHooks whose org-1 hook points at an
+// endpoint served by handler.
+func newTestPublishHooks(t *testing.T, failureMode string, handler http.HandlerFunc) (*PublishHooks, *fakePublishHookStore) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cipher, err := crypto.NewTokenCipher(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := cipher.Seal(testHookSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &fakePublishHookStore{hook: &models.PublishHook{
+		OrganizationID:  "org-1",
+		URL:             srv.URL,
+		EncryptedSecret: sealed,
+		TimeoutSeconds:  1,
+		FailureMode:     failureMode,
+		Enabled:         true,
+	}}
+	h := &PublishHooks{store: store, cipher: cipher, client: srv.Client(), now: time.Now}
+	return h, store
+}
+
+// verifyingHook answers with decision after checking the request signature,
+// and with 401 when it does not match.
+func verifyingHook(decision string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(PublishHookSignatureHeader) != SignPublishHookBody(testHookSecret, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(decision))
+	}
+}
+
+// hangingHook never answers. It reads the body first so the server notices
+// when the client gives up.
+func hangingHook(_ http.ResponseWriter, r *http.Request) {
+	_, _ = io.ReadAll(r.Body)
+	<-r.Context().Done()
+}
+
+func testPublish() *ModuleVersionPublish {
+	return &ModuleVersionPublish{
+		OrganizationID: "org-1", Source: models.PublishHookSourceUpload,
+		Namespace: "acme", Name: "vpc", System: "aws", Version: "1.0.0", Checksum: "abc123", SizeBytes: 42,
+	}
+}
+
+func TestPublishHooks_Allow(t *testing.T) {
+	var event publishHookEvent
+	var signed bool
+	h, store := newTestPublishHooks(t, models.PublishHookFailClosed, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &event)
+		signed = r.Header.Get(PublishHookSignatureHeader) == SignPublishHookBody(testHookSecret, body) &&
+			r.Header.Get(PublishHookDeliveryHeader) == event.DeliveryID &&
+			r.Header.Get(PublishHookSchemaVersionHeader) == "1"
+		_, _ = w.Write([]byte(`{"allow": true}`))
+	})
+
+	if err := h.Check(context.Background(), testPublish()); err != nil {
+		t.Fatalf("Check = %v, want nil", err)
+	}
+	if !signed {
+		t.Error("request signature, delivery or schema version header does not match the body")
+	}
+	if event.Event != PublishHookEventPublish || event.SchemaVersion != PublishHookSchemaVersion || event.Checksum != "abc123" || event.SizeBytes != 42 || event.Namespace != "acme" {
+		t.Errorf("event = %+v", event)
+	}
+	if len(store.deliveries) != 1 || store.deliveries[0].Outcome != models.PublishHookOutcomeAllowed || store.deliveries[0].Attempts != 1 {
+		t.Errorf("deliveries = %+v", store.deliveries)
+	}
+}
+
+func TestPublishHooks_Deny(t *testing.T) {
+	h, store := newTestPublishHooks(t, models.PublishHookFailOpen, verifyingHook(`{"allow": false, "reason": "missing SBOM"}`))
+
+	err := h.Check(context.Background(), testPublish())
+	var denied *PublishHookDeniedError
+	if !errors.As(err, &denied) || denied.Reason != "missing SBOM" || !errors.Is(err, ErrPublishHookDenied) {
+		t.Fatalf("Check = %v, want a denial with the hook's reason", err)
+	}
+	if d := store.deliveries[0]; d.Outcome != models.PublishHookOutcomeDenied || d.Reason == nil || *d.Reason != "missing SBOM" {
+		t.Errorf("delivery = %+v", d)
+	}
+}
+
+func TestPublishHooks_DryRunIsNotLogged(t *testing.T) {
+	var event publishHookEvent
+	h, store := newTestPublishHooks(t, models.PublishHookFailClosed, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &event)
+		_, _ = w.Write([]byte(`{"allow": false, "reason": "missing SBOM"}`))
+	})
+
+	p := testPublish()
+	p.DryRun = true
+	if err := h.Check(context.Background(), p); !errors.Is(err, ErrPublishHookDenied) {
+		t.Fatalf("Check = %v, want the hook's denial", err)
+	}
+	if !event.DryRun {
+		t.Error("event does not tell the hook it is a dry run")
+	}
+	if len(store.deliveries) != 0 {
+		t.Errorf("dry run recorded %d deliveries, want none", len(store.deliveries))
+	}
+}
+
+func TestPublishHooks_RetriesOnceOn5xx(t *testing.T) {
+	var calls atomic.Int32
+	h, store := newTestPublishHooks(t, models.PublishHookFailClosed, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		verifyingHook(`{"allow": true}`)(w, r)
+	})
+
+	if err := h.Check(context.Background(), testPublish()); err != nil {
+		t.Fatalf("Check = %v, want nil after the retry", err)
+	}
+	if calls.Load() != 2 || store.deliveries[0].Attempts != 2 {
+		t.Errorf("calls = %d, attempts = %d; want 2", calls.Load(), store.deliveries[0].Attempts)
+	}
+}
+
+func TestPublishHooks_FailureModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		handler http.HandlerFunc
+		calls   int32
+	}{
+		{"5xx twice", models.PublishHookFailClosed, func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusBadGateway) }, 2},
+		{"4xx is not retried", models.PublishHookFailClosed, func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNotFound) }, 1},
+		{"no decision", models.PublishHookFailClosed, func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(`{"ok": true}`)) }, 1},
+		{"timeout", models.PublishHookFailClosed, hangingHook, 1},
+		{"5xx twice, fail open", models.PublishHookFailOpen, func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusInternalServerError) }, 2},
+		{"timeout, fail open", models.PublishHookFailOpen, hangingHook, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			h, store := newTestPublishHooks(t, tt.mode, func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				tt.handler(w, r)
+			})
+
+			err := h.Check(context.Background(), testPublish())
+			d := store.deliveries[0]
+			if tt.mode == models.PublishHookFailOpen {
+				if err != nil || d.Outcome != models.PublishHookOutcomeFailedOpen {
+					t.Errorf("Check = %v, outcome %s; want nil, failed_open", err, d.Outcome)
+				}
+			} else if !errors.Is(err, ErrPublishHookUnavailable) || d.Outcome != models.PublishHookOutcomeFailedClosed {
+				t.Errorf("Check = %v, outcome %s; want ErrPublishHookUnavailable, failed_closed", err, d.Outcome)
+			}
+			if d.Error == nil {
+				t.Error("delivery has no error")
+			}
+			if calls.Load() != tt.calls {
+				t.Errorf("calls = %d, want %d", calls.Load(), tt.calls)
+			}
+		})
+	}
+}
+
+func TestPublishHooks_DisabledOrMissing(t *testing.T) {
+	var calls atomic.Int32
+	h, store := newTestPublishHooks(t, models.PublishHookFailClosed, func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	store.hook.Enabled = false
+	if err := h.Check(context.Background(), testPublish()); err != nil {
+		t.Errorf("disabled hook: Check = %v", err)
+	}
+	store.hook = nil
+	if err := h.Check(context.Background(), testPublish()); err != nil {
+		t.Errorf("no hook: Check = %v", err)
+	}
+	var none *PublishHooks
+	if err := none.Check(context.Background(), testPublish()); err != nil {
+		t.Errorf("nil PublishHooks: Check = %v", err)
+	}
+	if calls.Load() != 0 || len(store.deliveries) != 0 {
+		t.Errorf("calls = %d, deliveries = %d; want none", calls.Load(), len(store.deliveries))
+	}
+}
+
+func TestPublishHooks_Test(t *testing.T) {
+	h, store := newTestPublishHooks(t, models.PublishHookFailClosed, verifyingHook(`{"allow": true}`))
+	store.hook.Enabled = false
+
+	res := h.Test(context.Background(), store.hook)
+	if !res.Ready || !res.SignatureVerified || res.BadSignatureStatus == nil || *res.BadSignatureStatus != http.StatusUnauthorized {
+		t.Errorf("Test = %+v, want ready", res)
+	}
+	if res.Delivery.Source != models.PublishHookSourceTest || res.Delivery.Outcome != models.PublishHookOutcomeAllowed {
+		t.Errorf("delivery = %+v", res.Delivery)
+	}
+
+	// An endpoint that accepts anything is reachable but not verifying.
+	h, store = newTestPublishHooks(t, models.PublishHookFailClosed, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"allow": true}`))
+	})
+	res = h.Test(context.Background(), store.hook)
+	if res.Ready || res.SignatureVerified {
+		t.Errorf("Test = %+v, want not ready", res)
+	}
+}
@@ -73,6 +73,10 @@ func (f *fakeUpstreamClient) GetProviderDocContent(ctx context.Context, upstream
 	return "", nil
 }
 
+func (f *fakeUpstreamClient) GetProviderMetadata(ctx context.Context, namespace, providerName string) (*mirror.ProviderMetadata, error) {
+	return nil, errors.New("not implemented in fake")
+}
+
 // ---------------------------------------------------------------------------
 // Tests
 // ---------------------------------------------------------------------------
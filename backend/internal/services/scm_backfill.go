@@ -0,0 +1,145 @@
+// scm_backfill.go plans and queues tag backfills: publishing every historical
+// tag of a repository when a module is first linked to it. A backfill is a
+// batch of ordinary publish tasks, so the scm-publish-queue job runs it and a
+// restart resumes it where it stopped.
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+const (
+	// backfillTagPageSize is the page size tags are listed with, the largest
+	// GitHub, GitLab and Bitbucket accept.
+	backfillTagPageSize = 100
+	// maxBackfillTagPages bounds the tag listing at 10,000 tags.
+	maxBackfillTagPages = 100
+)
+
+// BackfillTag is a tag a backfill publishes.
+type BackfillTag struct {
+	TagName   string `json:"tag_name"`
+	CommitSHA string `json:"commit_sha,omitempty"`
+	Version   string `json:"version"`
+}
+
+// PlanBackfill lists every tag of the link's repository and returns those a
+// backfill would publish, oldest version first so the newest version is
+// published last. Tags are matched as a manual sync matches them; tags whose
+// version the module already has, or that already have a queued or running
+// publish, are left out.
+func (p *SCMPublisher) PlanBackfill(ctx context.Context, link *scm.ModuleSourceRepoRecord, connector scm.Connector, token *scm.OAuthToken) ([]BackfillTag, error) {
+	tags, err := listAllTags(ctx, connector, token, link.RepositoryOwner, link.RepositoryName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	versions, err := p.moduleRepo.ListVersions(ctx, link.ModuleID.String())
+	if err != nil {
+		return nil, err
+	}
+	published := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		published[v.Version] = true
+	}
+	pending, err := p.scmRepo.ListPendingPublishTags(ctx, link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending publishes: %w", err)
+	}
+	return p.planBackfill(link, tags, published, pending), nil
+}
+
+// planBackfill picks the tags to publish out of tags. When several tags name
+// the same version only the first is kept.
+func (p *SCMPublisher) planBackfill(link *scm.ModuleSourceRepoRecord, tags []*scm.GitTag, published, pending map[string]bool) []BackfillTag {
+	tagPattern := link.TagPattern
+	if tagPattern == "" {
+		tagPattern = "v*"
+	}
+
+	plan := []BackfillTag{}
+	for _, tag := range tags {
+		version := p.extractVersionFromTag(tag.TagName, tagPattern)
+		if version == "" || skipsPrerelease(link, version) || published[version] {
+			continue
+		}
+		// Marking the version stops a second tag for it from being queued
+		// behind a publish already under way.
+		published[version] = true
+		if pending[tag.TagName] {
+			continue
+		}
+		plan = append(plan, BackfillTag{TagName: tag.TagName, CommitSHA: tag.TargetCommit, Version: version})
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool {
+		cmp, err := validation.CompareSemver(plan[i].Version, plan[j].Version)
+		return err == nil && cmp < 0
+	})
+	return plan
+}
+
+// listAllTags pages through a repository's tags. Connectors that ignore
+// pagination return every tag on each page, so a page that adds no new tag
+// ends the listing as a short page does.
+func listAllTags(ctx context.Context, connector scm.Connector, token *scm.OAuthToken, owner, repo string) ([]*scm.GitTag, error) {
+	var all []*scm.GitTag
+	seen := map[string]bool{}
+	for page := 1; page <= maxBackfillTagPages; page++ {
+		tags, err := connector.FetchTags(ctx, token, owner, repo, scm.Pagination{PageNum: page, PageSize: backfillTagPageSize})
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, tag := range tags {
+			if !seen[tag.TagName] {
+				seen[tag.TagName] = true
+				all = append(all, tag)
+				added++
+			}
+		}
+		if len(tags) < backfillTagPageSize || added == 0 {
+			break
+		}
+	}
+	return all, nil
+}
+
+// QueueBackfill queues a publish task for each tag of plan, in order, and
+// returns the backfill's ID and tasks. The tasks come due interval apart so
+// the queue does not download every archive from the SCM at once; each may
+// be attempted maxAttempts times.
+func (p *SCMPublisher) QueueBackfill(ctx context.Context, link *scm.ModuleSourceRepoRecord, plan []BackfillTag, maxAttempts int, interval time.Duration) (uuid.UUID, []*scm.PublishTask, error) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	backfillID := uuid.New()
+	start := time.Now()
+	tasks := make([]*scm.PublishTask, len(plan))
+	for i, tag := range plan {
+		seq := i
+		task := &scm.PublishTask{
+			ModuleSCMRepoID: link.ID,
+			BackfillID:      &backfillID,
+			BackfillSeq:     &seq,
+			TagName:         tag.TagName,
+			MaxAttempts:     maxAttempts,
+			NextAttemptAt:   start.Add(time.Duration(i) * interval),
+		}
+		if tag.CommitSHA != "" {
+			commit := tag.CommitSHA
+			task.CommitSHA = &commit
+		}
+		tasks[i] = task
+	}
+	if err := p.scmRepo.EnqueueBackfill(ctx, tasks); err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to queue backfill: %w", err)
+	}
+	return backfillID, tasks, nil
+}
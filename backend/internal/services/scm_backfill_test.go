@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/scm"
+)
+
+// pagedTagConnector serves tags in pages, or all of them on every page when
+// ignorePaging is set, as Azure DevOps does.
+type pagedTagConnector struct {
+	mockConnector
+	tags         []*scm.GitTag
+	ignorePaging bool
+	err          error
+	pages        int
+}
+
+func (p *pagedTagConnector) FetchTags(_ context.Context, _ *scm.AccessToken, _, _ string, page scm.Pagination) ([]*scm.GitTag, error) {
+	p.pages++
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.ignorePaging {
+		return p.tags, nil
+	}
+	start := (page.PageNum - 1) * page.PageSize
+	if start >= len(p.tags) {
+		return nil, nil
+	}
+	end := start + page.PageSize
+	if end > len(p.tags) {
+		end = len(p.tags)
+	}
+	return p.tags[start:end], nil
+}
+
+func numberedTags(n int) []*scm.GitTag {
+	tags := make([]*scm.GitTag, n)
+	for i := range tags {
+		tags[i] = &scm.GitTag{TagName: fmt.Sprintf("v1.0.%d", i), TargetCommit: fmt.Sprintf("sha%d", i)}
+	}
+	return tags
+}
+
+func TestListAllTags_Pages(t *testing.T) {
+	conn := &pagedTagConnector{tags: numberedTags(250)}
+	tags, err := listAllTags(context.Background(), conn, nil, "acme", "terraform-aws-vpc")
+	if err != nil {
+		t.Fatalf("listAllTags: %v", err)
+	}
+	if len(tags) != 250 || conn.pages != 3 {
+		t.Errorf("got %d tags in %d pages, want 250 in 3", len(tags), conn.pages)
+	}
+}
+
+func TestListAllTags_ConnectorIgnoresPaging(t *testing.T) {
+	conn := &pagedTagConnector{tags: numberedTags(150), ignorePaging: true}
+	tags, err := listAllTags(context.Background(), conn, nil, "acme", "terraform-aws-vpc")
+	if err != nil {
+		t.Fatalf("listAllTags: %v", err)
+	}
+	if len(tags) != 150 || conn.pages != 2 {
+		t.Errorf("got %d tags in %d pages, want 150 in 2", len(tags), conn.pages)
+	}
+}
+
+func TestListAllTags_Error(t *testing.T) {
+	conn := &pagedTagConnector{err: errors.New("rate limited")}
+	if _, err := listAllTags(context.Background(), conn, nil, "acme", "terraform-aws-vpc"); err == nil {
+		t.Fatal("listAllTags: want error")
+	}
+}
+
+func TestPlanBackfill_FiltersAndOrders(t *testing.T) {
+	p := NewSCMPublisher(nil, nil, nil, nil)
+	link := &scm.ModuleSourceRepoRecord{TagPattern: "*", SkipPrerelease: true}
+	tags := []*scm.GitTag{
+		{TagName: "v1.10.0", TargetCommit: "c"},
+		{TagName: "v1.2.0", TargetCommit: "b"},
+		{TagName: "v1.9.0-rc.1"}, // pre-release, skipped by the link
+		{TagName: "release-2"},   // not a version
+		{TagName: "v1.0.0"},      // already published
+		{TagName: "v1.1.0"},      // publish already queued
+		{TagName: "v2.0.0", TargetCommit: "d"},
+		{TagName: "1.2.0"}, // second tag for a version
+		{TagName: "v0.9.0", TargetCommit: "a"},
+	}
+
+	plan := p.planBackfill(link, tags, map[string]bool{"1.0.0": true}, map[string]bool{"v1.1.0": true})
+
+	want := []string{"v0.9.0", "v1.2.0", "v1.10.0", "v2.0.0"}
+	if len(plan) != len(want) {
+		t.Fatalf("plan = %+v, want tags %v", plan, want)
+	}
+	for i, tag := range want {
+		if plan[i].TagName != tag {
+			t.Errorf("plan[%d] = %s, want %s", i, plan[i].TagName, tag)
+		}
+	}
+	if plan[0].Version != "0.9.0" || plan[0].CommitSHA != "a" {
+		t.Errorf("plan[0] = %+v", plan[0])
+	}
+}
+
+func TestPlanBackfill_CustomPattern(t *testing.T) {
+	p := NewSCMPublisher(nil, nil, nil, nil)
+	link := &scm.ModuleSourceRepoRecord{TagPattern: "vpc/v*"}
+	tags := []*scm.GitTag{{TagName: "vpc/v1.0.0"}, {TagName: "v1.0.0"}, {TagName: "subnet/v1.0.0"}}
+
+	plan := p.planBackfill(link, tags, map[string]bool{}, map[string]bool{})
+	if len(plan) != 1 || plan[0].TagName != "vpc/v1.0.0" || plan[0].Version != "1.0.0" {
+		t.Errorf("plan = %+v", plan)
+	}
+}
+
+func TestQueueBackfill(t *testing.T) {
+	scmRepo, mock := newSCMRepoMock(t)
+	p := NewSCMPublisher(scmRepo, nil, nil, nil)
+	link := &scm.ModuleSourceRepoRecord{ID: uuid.New()}
+	plan := []BackfillTag{{TagName: "v1.0.0", CommitSHA: "a", Version: "1.0.0"}, {TagName: "v1.1.0", Version: "1.1.0"}}
+
+	retCols := []string{"id", "status", "next_attempt_at", "created_at", "updated_at"}
+	mock.ExpectBegin()
+	for range plan {
+		mock.ExpectQuery("INSERT INTO scm_publish_tasks").
+			WillReturnRows(sqlmock.NewRows(retCols).AddRow(uuid.New(), "queued", time.Now(), time.Now(), time.Now()))
+	}
+	mock.ExpectCommit()
+
+	backfillID, tasks, err := p.QueueBackfill(context.Background(), link, plan, 0, 30*time.Second)
+	if err != nil {
+		t.Fatalf("QueueBackfill: %v", err)
+	}
+	if backfillID == uuid.Nil || len(tasks) != 2 {
+		t.Fatalf("QueueBackfill = %s, %d tasks", backfillID, len(tasks))
+	}
+	for i, task := range tasks {
+		if task.BackfillID == nil || *task.BackfillID != backfillID || task.BackfillSeq == nil || *task.BackfillSeq != i {
+			t.Errorf("task %d backfill = %v/%v, want %s/%d", i, task.BackfillID, task.BackfillSeq, backfillID, i)
+		}
+		if task.MaxAttempts != 1 {
+			t.Errorf("task %d max attempts = %d, want 1", i, task.MaxAttempts)
+		}
+	}
+	if tasks[0].CommitSHA == nil || *tasks[0].CommitSHA != "a" || tasks[1].CommitSHA != nil {
+		t.Errorf("commit SHAs = %v, %v", tasks[0].CommitSHA, tasks[1].CommitSHA)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
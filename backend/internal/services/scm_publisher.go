@@ -8,7 +8,9 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
 	"os"
@@ -26,6 +28,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/scm"
 	"github.com/terraform-registry/terraform-registry/internal/scm/appcreds"
+	"github.com/terraform-registry/terraform-registry/internal/scratch"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
@@ -37,12 +40,26 @@ type SCMPublisher struct {
 	storageBackend storage.Storage
 	tokenCipher    *crypto.TokenCipher
 	tempDir        string
+	scratch        *scratch.Space                     // optional: budget for the scratch directory
 	scanRepo       *repositories.ModuleScanRepository // optional: queue scans after publish
 	moduleDocsRepo *repositories.ModuleDocsRepository // optional: store terraform-docs after publish
 	scanningCfg    *config.ScanningConfig             // optional: scan feature flags
 	sharedMinter   appcreds.SharedMinter              // optional: shared app-credential token minter
+	versionCap     *VersionCap                        // optional: module version cap
+	immutability   *ArtifactImmutability              // optional: publish log for immutable artifacts
+	publishHooks   *PublishHooks                      // optional: organizations' pre-publish hooks
+	maxArchiveSize int64                              // largest repository archive downloaded, in bytes
 }
 
+// defaultMaxSourceArchiveSize caps repository archives when WithArchiveSizeLimit
+// is not used; it matches the upload handler's validation.MaxArchiveSize.
+const defaultMaxSourceArchiveSize = validation.MaxArchiveSize
+
+// ErrSourceArchiveTooLarge is returned when a repository archive is over the
+// publisher's size limit. Retrying cannot help, so callers fail the publish
+// outright.
+var ErrSourceArchiveTooLarge = errors.New("repository archive exceeds the SCM publish size limit")
+
 // NewSCMPublisher creates a new SCM publisher
 func NewSCMPublisher(scmRepo *repositories.SCMRepository, moduleRepo *repositories.ModuleRepository, storageBackend storage.Storage, tokenCipher *crypto.TokenCipher) *SCMPublisher {
 	return &SCMPublisher{
@@ -51,7 +68,27 @@ func NewSCMPublisher(scmRepo *repositories.SCMRepository, moduleRepo *repositori
 		storageBackend: storageBackend,
 		tokenCipher:    tokenCipher,
 		tempDir:        os.TempDir(),
+		maxArchiveSize: defaultMaxSourceArchiveSize,
+	}
+}
+
+// WithArchiveSizeLimit sets the largest repository archive, in bytes, a
+// publish downloads. Larger archives are rejected before the download when the
+// SCM reports their size, and cut off mid-stream otherwise.
+func (p *SCMPublisher) WithArchiveSizeLimit(maxBytes int64) *SCMPublisher {
+	if maxBytes > 0 {
+		p.maxArchiveSize = maxBytes
 	}
+	return p
+}
+
+// WithScratch stages publishes in the registry's scratch directory and holds
+// each one to its budget: a publish that would go over it fails with
+// scratch.ErrBudgetExceeded, which the publish queue retries.
+func (p *SCMPublisher) WithScratch(space *scratch.Space) *SCMPublisher {
+	p.scratch = space
+	p.tempDir = space.Dir()
+	return p
 }
 
 // WithScanQueue wires in the scan repository and config so the publisher queues
@@ -77,12 +114,44 @@ func (p *SCMPublisher) WithSharedMinter(minter appcreds.SharedMinter) *SCMPublis
 	return p
 }
 
+// WithVersionCap wires in the module version cap so tag publishes respect it:
+// strict mode fails the publish, rolling mode archives the oldest versions.
+func (p *SCMPublisher) WithVersionCap(vc *VersionCap) *SCMPublisher {
+	p.versionCap = vc
+	return p
+}
+
+// WithImmutability wires in artifact immutability so tag publishes are
+// appended to the publish log where it applies.
+func (p *SCMPublisher) WithImmutability(a *ArtifactImmutability) *SCMPublisher {
+	p.immutability = a
+	return p
+}
+
+// WithPublishHooks wires in pre-publish hooks so an organization's hook must
+// approve each tag publish before the archive is stored.
+func (p *SCMPublisher) WithPublishHooks(h *PublishHooks) *SCMPublisher {
+	p.publishHooks = h
+	return p
+}
+
+// PublishingUserID returns the user whose personal token backs publishes for a
+// link: the explicit publishing identity when ownership has been transferred,
+// otherwise the module's creator.
+func PublishingUserID(link *scm.ModuleSourceRepoRecord, moduleCreatedBy *string) *string {
+	if link.PublishingUserID != nil {
+		id := link.PublishingUserID.String()
+		return &id
+	}
+	return moduleCreatedBy
+}
+
 // resolveSourceToken resolves the token used to download repository archives.
 // Providers in an app auth mode mint the shared, admin-managed credential;
-// legacy oauth_user providers fall back to the module creator's stored personal
+// legacy oauth_user providers fall back to the publishing user's stored personal
 // token. Returns nil (download proceeds unauthenticated) for public repos or when
 // no credential is available.
-func (p *SCMPublisher) resolveSourceToken(ctx context.Context, createdBy *string, providerID uuid.UUID) *scm.OAuthToken {
+func (p *SCMPublisher) resolveSourceToken(ctx context.Context, publisherID *string, providerID uuid.UUID) *scm.OAuthToken {
 	if p.sharedMinter != nil {
 		if provider, err := p.scmRepo.GetProvider(ctx, providerID); err == nil && provider != nil {
 			if provider.AuthMode == scm.AuthModeEntraApp || provider.AuthMode == scm.AuthModeGitHubApp {
@@ -94,14 +163,14 @@ func (p *SCMPublisher) resolveSourceToken(ctx context.Context, createdBy *string
 		}
 	}
 
-	if createdBy == nil {
+	if publisherID == nil {
 		return nil
 	}
-	createdByUUID, parseErr := uuid.Parse(*createdBy)
+	publisherUUID, parseErr := uuid.Parse(*publisherID)
 	if parseErr != nil {
 		return nil
 	}
-	tokenRecord, tokenErr := p.scmRepo.GetUserToken(ctx, createdByUUID, providerID)
+	tokenRecord, tokenErr := p.scmRepo.GetUserToken(ctx, publisherUUID, providerID)
 	if tokenErr != nil || tokenRecord == nil {
 		return nil
 	}
@@ -116,7 +185,74 @@ func (p *SCMPublisher) resolveSourceToken(ctx context.Context, createdBy *string
 	}
 }
 
-// ProcessTagPush processes a tag push webhook and publishes a new version
+// TagPublish is the outcome of publishing one tag with PublishTag.
+type TagPublish struct {
+	// Version is the version extracted from the tag; empty when the tag does
+	// not match the link's tag pattern.
+	Version string
+	// Metadata describes the published version; nil when the tag was skipped.
+	Metadata *scm.PublishMetadata
+	// Skipped says why the tag was not published; empty when it was.
+	Skipped string
+}
+
+// PublishTag publishes the tag of hook as a version of the link's module. It
+// is the executor shared by queued webhook publishes, webhook retries and
+// manual sync. A tag that is not published because it carries no version, is
+// a pre-release the link skips, or names a version that already exists is
+// reported in TagPublish.Skipped rather than as an error, so every error is
+// worth retrying. A nil token resolves the link's source credential.
+// coverage:skip:integration-only — requires live SCM connector, DB, and storage
+func (p *SCMPublisher) PublishTag(ctx context.Context, moduleSourceRepo *scm.ModuleSourceRepoRecord, hook *scm.IncomingHook, connector scm.Connector, token *scm.OAuthToken) (*TagPublish, error) {
+	version := p.extractVersionFromTag(hook.TagName, moduleSourceRepo.TagPattern)
+	if version == "" {
+		return &TagPublish{Skipped: fmt.Sprintf("could not extract version from tag %s", hook.TagName)}, nil
+	}
+	result := &TagPublish{Version: version}
+	if skipsPrerelease(moduleSourceRepo, version) {
+		result.Skipped = fmt.Sprintf("version %s is a pre-release and the link skips pre-release tags", version)
+		return result, nil
+	}
+
+	// Check if this version already exists — skip gracefully. Manual sync
+	// checks first too, but a webhook delivery may have created it since.
+	existingVersion, err := p.moduleRepo.GetVersion(ctx, moduleSourceRepo.ModuleID.String(), version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing version: %w", err)
+	}
+	if existingVersion != nil {
+		result.Skipped = fmt.Sprintf("version %s already exists, skipping", version)
+		return result, nil
+	}
+
+	if token == nil {
+		// Look up the module owner for the token lookup
+		module, err := p.moduleRepo.GetModuleByID(ctx, moduleSourceRepo.ModuleID.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up module: %w", err)
+		}
+		if module == nil {
+			return nil, fmt.Errorf("module not found")
+		}
+
+		// Resolve a token so downloads from private repos work. App-mode providers
+		// (entra_app/github_app) use the shared, admin-managed credential; legacy
+		// oauth_user providers fall back to the link's publishing identity.
+		token = p.resolveSourceToken(ctx, PublishingUserID(moduleSourceRepo, module.CreatedBy), moduleSourceRepo.SCMProviderID)
+	}
+
+	// Publish the module version (download, upload, create DB record)
+	result.Metadata, err = p.publishModuleVersion(ctx, connector, token, moduleSourceRepo, hook, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish version: %w", err)
+	}
+	return result, nil
+}
+
+// ProcessTagPush publishes the tag of a logged webhook event and records the
+// outcome on the event, marking it for retry on failure. The webhook retry job
+// uses it for events received before webhook publishes were queued as
+// scm_publish_tasks.
 // coverage:skip:integration-only — requires live SCM connector, DB, and storage
 func (p *SCMPublisher) ProcessTagPush(ctx context.Context, logID uuid.UUID, moduleSourceRepo *scm.ModuleSourceRepoRecord, hook *scm.IncomingHook, connector scm.Connector) {
 	// Update webhook log to processing. Returning silently on a state-write
@@ -128,85 +264,146 @@ func (p *SCMPublisher) ProcessTagPush(ctx context.Context, logID uuid.UUID, modu
 		return
 	}
 
-	// Extract version from tag name
-	version := p.extractVersionFromTag(hook.TagName, moduleSourceRepo.TagPattern)
-	if version == "" {
-		errMsg := "could not extract version from tag"
-		_ = p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
-		_ = p.scmRepo.MarkWebhookForRetry(ctx, logID, time.Now().Add(time.Minute))
-		return
-	}
-
-	// Check if this version already exists — skip gracefully
-	existingVersion, err := p.moduleRepo.GetVersion(ctx, moduleSourceRepo.ModuleID.String(), version)
+	result, err := p.PublishTag(ctx, moduleSourceRepo, hook, connector, nil)
 	if err != nil {
-		errMsg := fmt.Sprintf("failed to check for existing version: %v", err)
+		errMsg := err.Error()
 		_ = p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
 		_ = p.scmRepo.MarkWebhookForRetry(ctx, logID, time.Now().Add(time.Minute))
 		return
 	}
-	if existingVersion != nil {
-		skipMsg := fmt.Sprintf("version %s already exists, skipping", version)
-		_ = p.scmRepo.UpdateWebhookLogState(ctx, logID, "skipped", &skipMsg, nil)
+	if result.Skipped != "" {
+		_ = p.scmRepo.UpdateWebhookLogState(ctx, logID, "skipped", &result.Skipped, nil)
 		return
 	}
 
-	// Look up the module to get namespace/name/system and owner for token lookup
-	module, err := p.moduleRepo.GetModuleByID(ctx, moduleSourceRepo.ModuleID.String())
-	if err != nil {
-		errMsg := fmt.Sprintf("failed to look up module: %v", err)
-		_ = p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
-		_ = p.scmRepo.MarkWebhookForRetry(ctx, logID, time.Now().Add(time.Minute))
-		return
+	// Update webhook log to success
+	_ = p.scmRepo.UpdateWebhookLogState(ctx, logID, "completed", nil, &result.Metadata.ModuleVersionID)
+}
+
+// sourceArchive describes the archive downloaded from the SCM provider.
+type sourceArchive struct {
+	size   int64
+	sha256 string
+}
+
+// digestReader hashes and counts everything read through it.
+type digestReader struct {
+	r    io.Reader
+	h    hash.Hash
+	size int64
+}
+
+func (d *digestReader) Read(b []byte) (int, error) {
+	n, err := d.r.Read(b)
+	d.h.Write(b[:n])
+	d.size += int64(n)
+	return n, err
+}
+
+// cappedReader fails with ErrSourceArchiveTooLarge once more than max bytes
+// have been read through it.
+type cappedReader struct {
+	r    io.Reader
+	max  int64
+	read int64
+}
+
+func (c *cappedReader) Read(b []byte) (int, error) {
+	if c.read > c.max {
+		return 0, archiveTooLarge(c.read, c.max, false)
 	}
-	if module == nil {
-		errMsg := "module not found"
-		_ = p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
-		_ = p.scmRepo.MarkWebhookForRetry(ctx, logID, time.Now().Add(time.Minute))
-		return
+	if rest := c.max + 1 - c.read; int64(len(b)) > rest {
+		b = b[:rest]
 	}
+	n, err := c.r.Read(b)
+	c.read += int64(n)
+	if c.read > c.max {
+		return n, archiveTooLarge(c.read, c.max, false)
+	}
+	return n, err
+}
 
-	// Resolve a token so downloads from private repos work. App-mode providers
-	// (entra_app/github_app) use the shared, admin-managed credential; legacy
-	// oauth_user providers fall back to the module creator's personal token.
-	oauthToken := p.resolveSourceToken(ctx, module.CreatedBy, moduleSourceRepo.SCMProviderID)
+// archiveTooLarge describes an oversized archive. reported is whether size is
+// the SCM's figure rather than the bytes read before the download was cut off.
+func archiveTooLarge(size, limit int64, reported bool) error {
+	if reported {
+		return fmt.Errorf("%w: the SCM reports %s, the limit is %s", ErrSourceArchiveTooLarge, formatBytes(size), formatBytes(limit))
+	}
+	return fmt.Errorf("%w: stopped after %s, the limit is %s", ErrSourceArchiveTooLarge, formatBytes(size), formatBytes(limit))
+}
 
-	// Publish the module version (download, upload, create DB record)
-	versionID, err := p.publishModuleVersion(ctx, connector, oauthToken, moduleSourceRepo, hook, version)
-	if err != nil {
-		errMsg := fmt.Sprintf("failed to publish version: %v", err)
-		_ = p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
-		_ = p.scmRepo.MarkWebhookForRetry(ctx, logID, time.Now().Add(time.Minute))
-		return
+// formatBytes renders n in the largest binary unit that keeps it at least 1.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
 	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
 
-	// Update webhook log to success
-	versionUUID, _ := uuid.Parse(versionID)
-	_ = p.scmRepo.UpdateWebhookLogState(ctx, logID, "completed", nil, &versionUUID)
+// archiveLimit returns the largest repository archive a publish downloads.
+func (p *SCMPublisher) archiveLimit() int64 {
+	if p.maxArchiveSize <= 0 {
+		return defaultMaxSourceArchiveSize
+	}
+	return p.maxArchiveSize
 }
 
-// downloadAndPackage downloads the repository and creates a tarball
+// downloadAndPackage downloads the repository and creates a tarball. The
+// archive is held to the publisher's size limit: the SCM is asked for its size
+// first where the connector can, and the download is cut off at the limit.
 func (p *SCMPublisher) downloadAndPackage(ctx context.Context, connector scm.Connector, token *scm.OAuthToken,
-	owner, repo, commitSHA, subpath string) (string, string, error) {
+	owner, repo, commitSHA, subpath string) (string, string, sourceArchive, error) {
+
+	limit := p.archiveLimit()
+	if sizer, ok := connector.(scm.ArchiveSizer); ok {
+		size, err := sizer.SourceArchiveSize(ctx, token, owner, repo, commitSHA, scm.ArchiveTarball)
+		switch {
+		case err != nil:
+			// The streaming cap still applies, so go ahead with the download.
+			slog.Debug("scm-publisher: failed to read archive size", "owner", owner, "repo", repo, "error", err)
+		case size > limit:
+			return "", "", sourceArchive{}, archiveTooLarge(size, limit, true)
+		}
+	}
 
 	// Download source archive
 	archive, err := connector.DownloadSourceArchive(ctx, token, owner, repo, commitSHA, scm.ArchiveTarball)
 	if err != nil {
-		return "", "", fmt.Errorf("download failed: %w", err)
+		return "", "", sourceArchive{}, fmt.Errorf("download failed: %w", err)
 	}
 	defer archive.Close()
 
 	// Create temp directory for extraction
 	tempDir := filepath.Join(p.tempDir, fmt.Sprintf("scm-publish-%s", uuid.New().String()))
 	if err := os.MkdirAll(tempDir, 0750); err != nil {
-		return "", "", err
+		return "", "", sourceArchive{}, err
 	}
 	defer os.RemoveAll(tempDir)
 
-	// Extract archive
-	if err := p.extractTarGz(archive, tempDir); err != nil {
-		return "", "", fmt.Errorf("extraction failed: %w", err)
+	// Extract archive, measuring it on the way. The tar reader stops at the
+	// end-of-archive marker, so drain the rest for a digest of the whole
+	// download.
+	capped := &cappedReader{r: archive, max: limit}
+	src := &digestReader{r: capped, h: sha256.New()}
+	if err := archiver.ExtractTarGzLimit(src, tempDir, limit); err != nil {
+		if capped.read > limit {
+			return "", "", sourceArchive{}, archiveTooLarge(capped.read, limit, false)
+		}
+		return "", "", sourceArchive{}, fmt.Errorf("extraction failed: %w", err)
 	}
+	if _, err := io.Copy(io.Discard, src); err != nil {
+		if errors.Is(err, ErrSourceArchiveTooLarge) {
+			return "", "", sourceArchive{}, err
+		}
+		return "", "", sourceArchive{}, fmt.Errorf("download failed: %w", err)
+	}
+	source := sourceArchive{size: src.size, sha256: hex.EncodeToString(src.h.Sum(nil))}
 
 	// Resolve the module within the extracted archive. GitHub/GitLab wrap the repo in a
 	// single "<repo>-<sha>/" directory and the subpath must be resolved inside that wrapper.
@@ -242,19 +439,20 @@ func (p *SCMPublisher) downloadAndPackage(ctx context.Context, connector scm.Con
 	}
 	if modulePath == "" {
 		if validateErr != nil {
-			return "", "", fmt.Errorf("invalid module structure: %w", validateErr)
+			return "", "", sourceArchive{}, fmt.Errorf("invalid module structure: %w", validateErr)
 		}
-		return "", "", fmt.Errorf("module path %q not found in repository", subpath)
+		return "", "", sourceArchive{}, fmt.Errorf("module path %q not found in repository", subpath)
 	}
 
 	// Create new tarball with commit SHA manifest
 	outputPath := filepath.Join(p.tempDir, fmt.Sprintf("module-%s.tar.gz", uuid.New().String()))
 	checksum, err := p.createImmutableTarball(modulePath, outputPath, commitSHA)
 	if err != nil {
-		return "", "", fmt.Errorf("packaging failed: %w", err)
+		_ = os.Remove(outputPath)
+		return "", "", sourceArchive{}, fmt.Errorf("packaging failed: %w", err)
 	}
 
-	return outputPath, checksum, nil
+	return outputPath, checksum, source, nil
 }
 
 // extractTarGz extracts a tar.gz archive by delegating to the shared archiver package.
@@ -414,14 +612,16 @@ func (p *SCMPublisher) extractVersionFromTag(tag, glob string) string {
 // TriggerManualSync scans a repository for tags and publishes any matching versions
 // TriggerManualSync manually syncs all tags for a module source repo.
 // coverage:skip:integration-only — requires live SCM connector and DB
-// This is called when a user manually triggers a sync from the UI
-func (p *SCMPublisher) TriggerManualSync(ctx context.Context, moduleSourceRepo *scm.ModuleSourceRepoRecord, connector scm.Connector, token *scm.OAuthToken) error {
+// This is called when a user manually triggers a sync from the UI. Tags are
+// published one after another and the publish metadata of each new version
+// is returned; a tag that fails to publish is logged and skipped.
+func (p *SCMPublisher) TriggerManualSync(ctx context.Context, moduleSourceRepo *scm.ModuleSourceRepoRecord, connector scm.Connector, token *scm.OAuthToken) ([]*scm.PublishMetadata, error) {
 	slog.Debug("starting manual sync", "module_id", moduleSourceRepo.ModuleID, "owner", moduleSourceRepo.RepositoryOwner, "repo", moduleSourceRepo.RepositoryName)
 
 	// List all tags from the repository
 	tags, err := connector.FetchTags(ctx, token, moduleSourceRepo.RepositoryOwner, moduleSourceRepo.RepositoryName, scm.DefaultPagination())
 	if err != nil {
-		return fmt.Errorf("failed to list tags: %w", err)
+		return nil, fmt.Errorf("failed to list tags: %w", err)
 	}
 
 	slog.Debug("fetched repository tags", "tag_count", len(tags))
@@ -434,6 +634,7 @@ func (p *SCMPublisher) TriggerManualSync(ctx context.Context, moduleSourceRepo *
 	slog.Debug("using tag pattern", "tag_pattern", tagPattern)
 
 	matchingTags := 0
+	published := []*scm.PublishMetadata{}
 	for _, tag := range tags {
 		slog.Debug("checking tag", "tag", tag.TagName)
 
@@ -446,6 +647,11 @@ func (p *SCMPublisher) TriggerManualSync(ctx context.Context, moduleSourceRepo *
 
 		slog.Debug("tag matches pattern", "tag", tag.TagName, "version", version)
 
+		if skipsPrerelease(moduleSourceRepo, version) {
+			slog.Debug("pre-release tag skipped by link setting", "tag", tag.TagName, "version", version)
+			continue
+		}
+
 		// Check if this version already exists.
 		// Existing versions are not re-published from SCM, but if their HCL docs
 		// (inputs/outputs/providers) are missing, we re-run the analyzer on the
@@ -468,9 +674,10 @@ func (p *SCMPublisher) TriggerManualSync(ctx context.Context, moduleSourceRepo *
 		}
 
 		// Process this tag push (without a webhook log ID since this is manual)
-		// We'll pass a nil UUID since webhook logging isn't applicable here
-		slog.Debug("starting goroutine to process tag", "tag", tag.TagName, "commit", tag.TargetCommit)
-		go p.processTagForManualSync(ctx, moduleSourceRepo, hook, connector, token)
+		slog.Debug("processing tag", "tag", tag.TagName, "commit", tag.TargetCommit)
+		if meta := p.processTagForManualSync(ctx, moduleSourceRepo, hook, connector, token); meta != nil {
+			published = append(published, meta)
+		}
 	}
 
 	slog.Debug("manual sync tag matching complete", "matching_tags", matchingTags, "total_tags", len(tags))
@@ -479,38 +686,35 @@ func (p *SCMPublisher) TriggerManualSync(ctx context.Context, moduleSourceRepo *
 	now := time.Now()
 	moduleSourceRepo.LastSyncAt = &now
 	if err := p.scmRepo.UpdateModuleSourceRepo(ctx, moduleSourceRepo); err != nil {
-		return fmt.Errorf("failed to update last sync time: %w", err)
+		return published, fmt.Errorf("failed to update last sync time: %w", err)
 	}
 
-	return nil
+	return published, nil
 }
 
-// processTagForManualSync processes a single tag during manual sync (no webhook logging)
-func (p *SCMPublisher) processTagForManualSync(ctx context.Context, moduleSourceRepo *scm.ModuleSourceRepoRecord, hook *scm.IncomingHook, connector scm.Connector, token *scm.OAuthToken) {
-	slog.Debug("processing tag for manual sync", "tag", hook.TagName, "module_id", moduleSourceRepo.ModuleID)
-
-	// Extract version from tag name
-	version := p.extractVersionFromTag(hook.TagName, moduleSourceRepo.TagPattern)
-	if version == "" {
-		slog.Warn("failed to extract version from tag", "tag", hook.TagName)
-		return
-	}
-	slog.Debug("extracted version from tag", "tag", hook.TagName, "version", version)
+// skipsPrerelease reports whether link is configured to ignore version
+// because it is a pre-release.
+func skipsPrerelease(link *scm.ModuleSourceRepoRecord, version string) bool {
+	return link.SkipPrerelease && validation.IsPrerelease(version)
+}
 
-	// Guard against races: the caller (TriggerManualSync) checks for existing versions before
-	// spawning goroutines, but a second goroutine may have created it in the meantime.
-	if existingVer, checkErr := p.moduleRepo.GetVersion(ctx, moduleSourceRepo.ModuleID.String(), version); checkErr == nil && existingVer != nil {
-		slog.Debug("version already exists, skipping", "version", version, "module_id", moduleSourceRepo.ModuleID)
-		return
-	}
+// processTagForManualSync processes a single tag during manual sync (no webhook logging).
+// It returns the publish metadata, or nil when the tag was not published.
+func (p *SCMPublisher) processTagForManualSync(ctx context.Context, moduleSourceRepo *scm.ModuleSourceRepoRecord, hook *scm.IncomingHook, connector scm.Connector, token *scm.OAuthToken) *scm.PublishMetadata {
+	slog.Debug("processing tag for manual sync", "tag", hook.TagName, "module_id", moduleSourceRepo.ModuleID)
 
-	versionID, err := p.publishModuleVersion(ctx, connector, token, moduleSourceRepo, hook, version)
+	result, err := p.PublishTag(ctx, moduleSourceRepo, hook, connector, token)
 	if err != nil {
-		slog.Warn("failed to publish version", "version", version, "error", err)
-		return
+		slog.Warn("failed to publish version", "tag", hook.TagName, "error", err)
+		return nil
+	}
+	if result.Skipped != "" {
+		slog.Debug("tag not published", "tag", hook.TagName, "reason", result.Skipped, "module_id", moduleSourceRepo.ModuleID)
+		return nil
 	}
 
-	slog.Debug("successfully published version", "version", version, "version_id", versionID, "module_id", moduleSourceRepo.ModuleID)
+	slog.Debug("successfully published version", "version", result.Version, "version_id", result.Metadata.ModuleVersionID, "module_id", moduleSourceRepo.ModuleID)
+	return result.Metadata
 }
 
 // reanalyzeExistingVersion re-runs the HCL analyzer on a module version that
@@ -541,6 +745,14 @@ func (p *SCMPublisher) reanalyzeExistingVersion(ctx context.Context, moduleID st
 	slog.Info("scm-publisher: reanalyze: docs missing, re-running analyzer",
 		"module_id", moduleID, "version_id", version.ID, "version", version.Version)
 
+	// The archive and its extraction are each at most the upload limit.
+	release, err := p.scratch.Reserve(2 * validation.MaxArchiveSize)
+	if err != nil {
+		slog.Warn("scm-publisher: reanalyze: skipped", "version_id", version.ID, "error", err)
+		return
+	}
+	defer release()
+
 	reader, err := p.storageBackend.Download(ctx, version.StoragePath)
 	if err != nil {
 		slog.Warn("scm-publisher: reanalyze: failed to download archive",
@@ -569,7 +781,7 @@ func (p *SCMPublisher) reanalyzeExistingVersion(ctx context.Context, moduleID st
 		return
 	}
 
-	doc, err := analyzer.AnalyzeArchive(tmp)
+	doc, err := analyzer.AnalyzeArchiveIn(p.tempDir, tmp)
 	if err != nil {
 		slog.Warn("scm-publisher: reanalyze: analyzer failed",
 			"version_id", version.ID, "error", err)
@@ -591,10 +803,58 @@ func (p *SCMPublisher) reanalyzeExistingVersion(ctx context.Context, moduleID st
 		"version_id", version.ID, "inputs", len(doc.Inputs), "outputs", len(doc.Outputs))
 }
 
+// callRecorder wraps the connector of one publish and records the API calls
+// the publish makes through it.
+type callRecorder struct {
+	scm.Connector
+	calls []string
+}
+
+func (r *callRecorder) FetchTagByName(ctx context.Context, creds *scm.AccessToken, ownerName, repoName, tagName string) (*scm.GitTag, error) {
+	r.calls = append(r.calls, "FetchTagByName")
+	return r.Connector.FetchTagByName(ctx, creds, ownerName, repoName, tagName)
+}
+
+// SourceArchiveSize asks the wrapped connector for the archive size when it
+// can report one, and returns -1 otherwise.
+func (r *callRecorder) SourceArchiveSize(ctx context.Context, creds *scm.AccessToken, ownerName, repoName, gitRef string, format scm.ArchiveKind) (int64, error) {
+	sizer, ok := r.Connector.(scm.ArchiveSizer)
+	if !ok {
+		return -1, nil
+	}
+	r.calls = append(r.calls, "SourceArchiveSize")
+	return sizer.SourceArchiveSize(ctx, creds, ownerName, repoName, gitRef, format)
+}
+
+func (r *callRecorder) DownloadSourceArchive(ctx context.Context, creds *scm.AccessToken, ownerName, repoName, gitRef string, format scm.ArchiveKind) (io.ReadCloser, error) {
+	r.calls = append(r.calls, "DownloadSourceArchive")
+	return r.Connector.DownloadSourceArchive(ctx, creds, ownerName, repoName, gitRef, format)
+}
+
+// resolveTagCommit returns the commit the tag points at now. A webhook's
+// commit may be an annotated tag's object ID rather than the commit, so the
+// provider is asked; the hook's commit is used when it cannot answer.
+func resolveTagCommit(ctx context.Context, connector scm.Connector, token *scm.OAuthToken, moduleSourceRepo *scm.ModuleSourceRepoRecord, hook *scm.IncomingHook) (string, error) {
+	if hook.TagName != "" {
+		tag, err := connector.FetchTagByName(ctx, token, moduleSourceRepo.RepositoryOwner, moduleSourceRepo.RepositoryName, hook.TagName)
+		if err == nil && tag != nil && tag.TargetCommit != "" {
+			return tag.TargetCommit, nil
+		}
+		if err != nil {
+			slog.Debug("scm-publisher: failed to resolve tag commit", "tag", hook.TagName, "error", err)
+		}
+	}
+	if hook.CommitSHA == "" {
+		return "", fmt.Errorf("could not resolve the commit of tag %q", hook.TagName)
+	}
+	return hook.CommitSHA, nil
+}
+
 // publishModuleVersion contains the shared logic for publishing a module version
 // from an SCM tag. It downloads the source archive, uploads it to storage,
-// extracts a README, and creates the database record. Both webhook-driven
-// (ProcessTagPush) and manual sync (processTagForManualSync) paths call this.
+// extracts a README, and creates the database record. Webhook-driven and
+// manual sync publishes both reach it through PublishTag. It returns the
+// publish metadata recorded for the new version.
 // coverage:skip:integration-only — requires live SCM connector, DB, storage, analyzer, and scanner
 func (p *SCMPublisher) publishModuleVersion(
 	ctx context.Context,
@@ -603,42 +863,80 @@ func (p *SCMPublisher) publishModuleVersion(
 	moduleSourceRepo *scm.ModuleSourceRepoRecord,
 	hook *scm.IncomingHook,
 	version string,
-) (string, error) {
+) (*scm.PublishMetadata, error) {
 	// Look up the module to get namespace/name/system
 	module, err := p.moduleRepo.GetModuleByID(ctx, moduleSourceRepo.ModuleID.String())
 	if err != nil {
-		return "", fmt.Errorf("look up module: %w", err)
+		return nil, fmt.Errorf("look up module: %w", err)
 	}
 	if module == nil {
-		return "", fmt.Errorf("module %s not found", moduleSourceRepo.ModuleID)
+		return nil, fmt.Errorf("module %s not found", moduleSourceRepo.ModuleID)
+	}
+	if err := p.versionCap.CheckPublish(ctx, module.ID, false); err != nil {
+		return nil, err
+	}
+
+	recorder := &callRecorder{Connector: connector}
+	connector = recorder
+
+	commitSHA, err := resolveTagCommit(ctx, connector, token, moduleSourceRepo, hook)
+	if err != nil {
+		return nil, err
+	}
+
+	// The extracted repository and the repackaged archive are each held to
+	// the archive size limit; reserve room for both before downloading.
+	release, err := p.scratch.Reserve(2 * p.archiveLimit())
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	// Download source archive at the specific commit
-	archivePath, checksum, err := p.downloadAndPackage(ctx, connector, token, moduleSourceRepo.RepositoryOwner,
-		moduleSourceRepo.RepositoryName, hook.CommitSHA, moduleSourceRepo.ModulePath)
+	packageStart := time.Now()
+	archivePath, checksum, source, err := p.downloadAndPackage(ctx, connector, token, moduleSourceRepo.RepositoryOwner,
+		moduleSourceRepo.RepositoryName, commitSHA, moduleSourceRepo.ModulePath)
 	if err != nil {
-		return "", fmt.Errorf("download source: %w", err)
+		return nil, fmt.Errorf("download source: %w", err)
 	}
+	packageDuration := time.Since(packageStart)
 	defer os.Remove(archivePath)
 
 	// Open archive for upload
 	file, err := os.Open(archivePath) // #nosec G304 -- path is constructed from validated namespace/name/version components; path traversal is prevented at the API and archive-extraction layers
 	if err != nil {
-		return "", fmt.Errorf("open archive: %w", err)
+		return nil, fmt.Errorf("open archive: %w", err)
 	}
 	defer file.Close()
 
-	storagePath := fmt.Sprintf("modules/%s/%s/%s/%s-%s.tar.gz",
-		module.Namespace, module.Name, module.System, module.Name, version)
+	storagePath := storage.ModuleArchiveKey(module.Namespace, module.Name, module.System, version)
 
 	// Get file size for upload
 	fileInfo, err := os.Stat(archivePath)
 	if err != nil {
-		return "", fmt.Errorf("stat temp file: %w", err)
+		return nil, fmt.Errorf("stat temp file: %w", err)
+	}
+
+	// The organization's pre-publish hook sees the packaged archive's digest
+	// before anything is stored.
+	if err := p.publishHooks.Check(ctx, &ModuleVersionPublish{
+		OrganizationID: module.OrganizationID,
+		Source:         models.PublishHookSourceSCM,
+		Namespace:      module.Namespace,
+		Name:           module.Name,
+		System:         module.System,
+		Version:        version,
+		Checksum:       checksum,
+		SizeBytes:      fileInfo.Size(),
+		PublishedBy:    PublishingUserID(moduleSourceRepo, module.CreatedBy),
+		CommitSHA:      commitSHA,
+		TagName:        hook.TagName,
+	}); err != nil {
+		return nil, err
 	}
 
 	if _, err := p.storageBackend.Upload(ctx, storagePath, file, fileInfo.Size()); err != nil {
-		return "", fmt.Errorf("upload to storage: %w", err)
+		return nil, fmt.Errorf("upload to storage: %w", err)
 	}
 
 	// Extract README from the archive
@@ -651,29 +949,61 @@ func (p *SCMPublisher) publishModuleVersion(
 		_ = readmeFile.Close()
 	}
 
+	// Parse the module's Terraform configuration (non-fatal). The
+	// required_version constraint is recorded on the version row; the rest is
+	// stored as terraform-docs metadata once the version exists.
+	var doc *analyzer.ModuleDoc
+	if f, err := os.Open(archivePath); err == nil { // #nosec G304 -- archivePath is a temp file created by this process
+		if doc, err = analyzer.AnalyzeArchiveIn(p.tempDir, f); err != nil {
+			slog.Warn("scm-publisher: terraform-docs: failed to analyze archive",
+				"module", module.Name, "version", version, "error", err)
+		}
+		_ = f.Close()
+	}
+
 	// Create module version record
 	versionID := uuid.New().String()
 	scmRepoIDStr := moduleSourceRepo.ID.String()
 	tagName := hook.TagName
-	commitSHA := hook.CommitSHA
 
 	moduleVersion := &models.ModuleVersion{
-		ID:             versionID,
-		ModuleID:       moduleSourceRepo.ModuleID.String(),
-		Version:        version,
-		StoragePath:    storagePath,
-		StorageBackend: "default",
-		SizeBytes:      fileInfo.Size(),
-		Checksum:       checksum,
-		CreatedAt:      time.Now(),
-		Readme:         readmeContent,
-		CommitSHA:      &commitSHA,
-		TagName:        &tagName,
-		SCMRepoID:      &scmRepoIDStr,
+		ID:                       versionID,
+		ModuleID:                 moduleSourceRepo.ModuleID.String(),
+		Version:                  version,
+		StoragePath:              storagePath,
+		StorageBackend:           "default",
+		SizeBytes:                fileInfo.Size(),
+		Checksum:                 checksum,
+		CreatedAt:                time.Now(),
+		Readme:                   readmeContent,
+		CommitSHA:                &commitSHA,
+		TagName:                  &tagName,
+		SCMRepoID:                &scmRepoIDStr,
+		RequiredTerraformVersion: doc.RequiredTerraformVersion(),
 	}
 
 	if err := p.moduleRepo.CreateVersion(ctx, moduleVersion); err != nil {
-		return "", fmt.Errorf("create version: %w", err)
+		return nil, fmt.Errorf("create version: %w", err)
+	}
+	p.versionCap.AfterPublish(ctx, moduleVersion, PublishingUserID(moduleSourceRepo, module.CreatedBy), false)
+	p.immutability.RecordPublish(ctx, &models.PublishLogEntry{
+		ArtifactType:   models.PublishLogArtifactModule,
+		OrganizationID: &module.OrganizationID,
+		Namespace:      module.Namespace,
+		Name:           module.Name,
+		Target:         module.System,
+		Version:        version,
+		StorageKey:     storagePath,
+		Checksum:       checksum,
+		PublishedBy:    PublishingUserID(moduleSourceRepo, module.CreatedBy),
+	})
+
+	// Record the release notes for this version (non-fatal).
+	if changelog := p.resolveChangelog(ctx, connector, token, moduleSourceRepo, hook, version, archivePath); changelog != "" {
+		if err := p.moduleRepo.SetVersionChangelog(ctx, moduleVersion.ID, changelog); err != nil {
+			slog.Warn("scm-publisher: failed to store changelog",
+				"version_id", moduleVersion.ID, "error", err)
+		}
 	}
 
 	// Queue a security scan for the newly published version (non-fatal).
@@ -684,21 +1014,71 @@ func (p *SCMPublisher) publishModuleVersion(
 		}
 	}
 
-	// Extract and store terraform-docs metadata (non-fatal).
-	if p.moduleDocsRepo != nil {
-		if f, err := os.Open(archivePath); err == nil { // G304: archivePath is a temp file created by this process
-			defer f.Close()
-			if doc, err := analyzer.AnalyzeArchive(f); err != nil {
-				slog.Warn("scm-publisher: terraform-docs: failed to analyze archive",
-					"module", module.Name, "version", version, "error", err)
-			} else if doc != nil {
-				if err := p.moduleDocsRepo.UpsertModuleDocs(ctx, moduleVersion.ID, doc); err != nil {
-					slog.Warn("scm-publisher: terraform-docs: failed to store docs",
-						"version_id", moduleVersion.ID, "error", err)
-				}
-			}
+	// Store the terraform-docs metadata extracted above (non-fatal).
+	if p.moduleDocsRepo != nil && doc != nil {
+		if err := p.moduleDocsRepo.UpsertModuleDocs(ctx, moduleVersion.ID, doc); err != nil {
+			slog.Warn("scm-publisher: terraform-docs: failed to store docs",
+				"version_id", moduleVersion.ID, "error", err)
+		}
+	}
+
+	// Record where the archive came from (non-fatal). The changelog lookup
+	// above may have made a call of its own, so this comes last.
+	meta := &scm.PublishMetadata{
+		ModuleVersionID:        uuid.MustParse(versionID),
+		Version:                version,
+		TagName:                tagName,
+		CommitSHA:              commitSHA,
+		SourceArchiveSizeBytes: source.size,
+		SourceArchiveSHA256:    source.sha256,
+		PackageDurationMs:      packageDuration.Milliseconds(),
+		ConnectorAPICalls:      recorder.calls,
+		CreatedAt:              time.Now(),
+	}
+	if moduleSourceRepo.ModuleAddress != nil {
+		meta.ModuleAddress = *moduleSourceRepo.ModuleAddress
+	}
+	if err := p.scmRepo.CreatePublishMetadata(ctx, moduleSourceRepo.ID, meta); err != nil {
+		slog.Warn("scm-publisher: failed to store publish metadata",
+			"version_id", moduleVersion.ID, "error", err)
+	}
+
+	return meta, nil
+}
+
+// resolveChangelog returns the sanitized release notes for a published tag:
+// the section of the packaged module's CHANGELOG matching version, or, when
+// the archive has no changelog or no matching section, the annotated tag
+// message. Returns "" when neither is available.
+func (p *SCMPublisher) resolveChangelog(
+	ctx context.Context,
+	connector scm.Connector,
+	token *scm.OAuthToken,
+	moduleSourceRepo *scm.ModuleSourceRepoRecord,
+	hook *scm.IncomingHook,
+	version string,
+	archivePath string,
+) string {
+	// #nosec G304 -- archivePath is a temp file created by this process
+	if f, err := os.Open(archivePath); err == nil {
+		content, extractErr := validation.ExtractChangelog(f)
+		_ = f.Close()
+		if extractErr != nil {
+			slog.Debug("scm-publisher: failed to read changelog from archive", "version", version, "error", extractErr)
+		} else if section := validation.ChangelogSection(content, version); section != "" {
+			return validation.SanitizeChangelog(section)
 		}
 	}
 
-	return versionID, nil
+	if hook.TagName == "" {
+		return ""
+	}
+	tag, err := connector.FetchTagByName(ctx, token, moduleSourceRepo.RepositoryOwner, moduleSourceRepo.RepositoryName, hook.TagName)
+	if err != nil || tag == nil {
+		if err != nil {
+			slog.Debug("scm-publisher: failed to fetch tag for changelog", "tag", hook.TagName, "error", err)
+		}
+		return ""
+	}
+	return validation.SanitizeChangelog(tag.AnnotationMsg)
 }
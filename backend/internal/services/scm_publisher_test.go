@@ -5,12 +5,16 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/terraform-registry/terraform-registry/internal/scm"
 )
 
@@ -32,6 +36,30 @@ func newTestPublisher(t *testing.T) *SCMPublisher {
 	return &SCMPublisher{tempDir: t.TempDir()}
 }
 
+// ---------------------------------------------------------------------------
+// PublishingUserID
+// ---------------------------------------------------------------------------
+
+func TestPublishingUserID_PrefersTransferredOwner(t *testing.T) {
+	owner := uuid.New()
+	creator := "creator-id"
+	got := PublishingUserID(&scm.ModuleSourceRepoRecord{PublishingUserID: &owner}, &creator)
+	if got == nil || *got != owner.String() {
+		t.Errorf("PublishingUserID = %v, want %s", got, owner)
+	}
+}
+
+func TestPublishingUserID_FallsBackToCreator(t *testing.T) {
+	creator := "creator-id"
+	got := PublishingUserID(&scm.ModuleSourceRepoRecord{}, &creator)
+	if got == nil || *got != creator {
+		t.Errorf("PublishingUserID = %v, want %s", got, creator)
+	}
+	if PublishingUserID(&scm.ModuleSourceRepoRecord{}, nil) != nil {
+		t.Error("expected nil when the link has no owner and the module no creator")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // NewSCMPublisher
 // ---------------------------------------------------------------------------
@@ -115,6 +143,21 @@ func TestExtractVersionFromTag_VPrefix_ExactVersion(t *testing.T) {
 	}
 }
 
+func TestSkipsPrerelease(t *testing.T) {
+	skipping := &scm.ModuleSourceRepoRecord{SkipPrerelease: true}
+	publishing := &scm.ModuleSourceRepoRecord{}
+
+	if !skipsPrerelease(skipping, "1.4.0-rc.1") {
+		t.Error("skip_prerelease link should skip 1.4.0-rc.1")
+	}
+	if skipsPrerelease(skipping, "1.4.0") {
+		t.Error("skip_prerelease link should publish 1.4.0")
+	}
+	if skipsPrerelease(publishing, "1.4.0-rc.1") {
+		t.Error("link without skip_prerelease should publish 1.4.0-rc.1")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // validateModuleStructure
 // ---------------------------------------------------------------------------
@@ -392,6 +435,8 @@ func TestCreateImmutableTarball_InvalidDestPath(t *testing.T) {
 type mockConnector struct {
 	archiveData []byte
 	archiveErr  error
+	tag         *scm.GitTag
+	tagErr      error
 }
 
 func (m *mockConnector) Platform() scm.ProviderKind                    { return scm.ProviderGitHub }
@@ -418,7 +463,7 @@ func (m *mockConnector) FetchTags(context.Context, *scm.AccessToken, string, str
 	return nil, nil
 }
 func (m *mockConnector) FetchTagByName(context.Context, *scm.AccessToken, string, string, string) (*scm.GitTag, error) {
-	return nil, nil
+	return m.tag, m.tagErr
 }
 func (m *mockConnector) FetchCommit(context.Context, *scm.AccessToken, string, string, string) (*scm.GitCommit, error) {
 	return nil, nil
@@ -466,7 +511,7 @@ func TestDownloadAndPackage_GitHubWrapperWithSlashSubpath(t *testing.T) {
 	p := newTestPublisher(t)
 	connector := &mockConnector{archiveData: archiveData}
 
-	outputPath, checksum, err := p.downloadAndPackage(context.Background(), connector, nil,
+	outputPath, checksum, source, err := p.downloadAndPackage(context.Background(), connector, nil,
 		"sethbacon", "terraform-azurerm-vm", "abc123", "/")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -474,6 +519,11 @@ func TestDownloadAndPackage_GitHubWrapperWithSlashSubpath(t *testing.T) {
 	if len(checksum) != 64 {
 		t.Errorf("checksum length = %d, want 64", len(checksum))
 	}
+	// The source digest covers the downloaded archive, not the repackaged one.
+	sum := sha256.Sum256(archiveData)
+	if source.size != int64(len(archiveData)) || source.sha256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("source = %+v, want size %d and sha256 %x", source, len(archiveData), sum)
+	}
 	if _, err := os.Stat(outputPath); err != nil {
 		t.Errorf("output tarball not found: %v", err)
 	}
@@ -492,7 +542,7 @@ func TestDownloadAndPackage_GitHubWrapperWithExplicitSubpath(t *testing.T) {
 	p := newTestPublisher(t)
 	connector := &mockConnector{archiveData: archiveData}
 
-	_, _, err := p.downloadAndPackage(context.Background(), connector, nil,
+	_, _, _, err := p.downloadAndPackage(context.Background(), connector, nil,
 		"owner", "monorepo", "abc123", "modules/vm")
 	if err != nil {
 		t.Fatalf("unexpected error for explicit subpath: %v", err)
@@ -514,7 +564,7 @@ func TestDownloadAndPackage_NoWrapperSingleModuleDir(t *testing.T) {
 	p := newTestPublisher(t)
 	connector := &mockConnector{archiveData: archiveData}
 
-	_, _, err := p.downloadAndPackage(context.Background(), connector, nil,
+	_, _, _, err := p.downloadAndPackage(context.Background(), connector, nil,
 		"Terraform", "terraform-azurerm-diagnostic-settings", "abc123",
 		"terraform_diagnostic_settings_module")
 	if err != nil {
@@ -532,7 +582,7 @@ func TestDownloadAndPackage_SubpathNotFound(t *testing.T) {
 	p := newTestPublisher(t)
 	connector := &mockConnector{archiveData: archiveData}
 
-	_, _, err := p.downloadAndPackage(context.Background(), connector, nil,
+	_, _, _, err := p.downloadAndPackage(context.Background(), connector, nil,
 		"owner", "repo", "abc123", "nonexistent/path")
 	if err == nil {
 		t.Fatal("expected error for missing subpath, got nil")
@@ -549,7 +599,7 @@ func TestDownloadAndPackage_NoTFFilesAtRoot(t *testing.T) {
 	p := newTestPublisher(t)
 	connector := &mockConnector{archiveData: archiveData}
 
-	_, _, err := p.downloadAndPackage(context.Background(), connector, nil,
+	_, _, _, err := p.downloadAndPackage(context.Background(), connector, nil,
 		"owner", "repo", "abc123", "/")
 	if err == nil {
 		t.Fatal("expected validation error for missing .tf files, got nil")
@@ -562,7 +612,7 @@ func TestDownloadAndPackage_DownloadError(t *testing.T) {
 	p := newTestPublisher(t)
 	connector := &mockConnector{archiveErr: errors.New("network failure")}
 
-	_, _, err := p.downloadAndPackage(context.Background(), connector, nil,
+	_, _, _, err := p.downloadAndPackage(context.Background(), connector, nil,
 		"owner", "repo", "sha", "/")
 	if err == nil {
 		t.Fatal("expected error for download failure, got nil")
@@ -588,3 +638,176 @@ func TestWithModuleDocs_ReturnsPublisher(t *testing.T) {
 		t.Error("WithModuleDocs should return the same *SCMPublisher")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// resolveChangelog
+// ---------------------------------------------------------------------------
+
+func writeTempArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "module.tar.gz")
+	if err := os.WriteFile(path, makeTarGz(t, files), 0600); err != nil {
+		t.Fatalf("write archive: %v", err)
+	}
+	return path
+}
+
+func TestResolveChangelog_FromChangelogFile(t *testing.T) {
+	p := newTestPublisher(t)
+	archive := writeTempArchive(t, map[string]string{
+		"main.tf":      "",
+		"CHANGELOG.md": "# Changelog\n\n## [1.1.0] - 2024-02-01\n- newer\n\n## [1.0.0] - 2024-01-01\n- <b>first</b> release\n",
+	})
+	connector := &mockConnector{tag: &scm.GitTag{AnnotationMsg: "tag message"}}
+	repo := &scm.ModuleSourceRepoRecord{RepositoryOwner: "o", RepositoryName: "r"}
+
+	got := p.resolveChangelog(context.Background(), connector, nil, repo, &scm.IncomingHook{TagName: "v1.0.0"}, "1.0.0", archive)
+	if got != "- first release" {
+		t.Errorf("resolveChangelog() = %q, want %q", got, "- first release")
+	}
+}
+
+func TestResolveChangelog_FallsBackToTagMessage(t *testing.T) {
+	p := newTestPublisher(t)
+	archive := writeTempArchive(t, map[string]string{
+		"main.tf":      "",
+		"CHANGELOG.md": "## [0.9.0]\n- old\n",
+	})
+	connector := &mockConnector{tag: &scm.GitTag{AnnotationMsg: "Release 1.0.0\n\n- shipped it\n"}}
+	repo := &scm.ModuleSourceRepoRecord{RepositoryOwner: "o", RepositoryName: "r"}
+
+	got := p.resolveChangelog(context.Background(), connector, nil, repo, &scm.IncomingHook{TagName: "v1.0.0"}, "1.0.0", archive)
+	if got != "Release 1.0.0\n\n- shipped it" {
+		t.Errorf("resolveChangelog() = %q", got)
+	}
+}
+
+func TestResolveChangelog_NothingAvailable(t *testing.T) {
+	p := newTestPublisher(t)
+	archive := writeTempArchive(t, map[string]string{"main.tf": ""})
+	repo := &scm.ModuleSourceRepoRecord{RepositoryOwner: "o", RepositoryName: "r"}
+
+	connector := &mockConnector{tagErr: errors.New("not found")}
+	if got := p.resolveChangelog(context.Background(), connector, nil, repo, &scm.IncomingHook{TagName: "v1.0.0"}, "1.0.0", archive); got != "" {
+		t.Errorf("resolveChangelog() = %q, want empty", got)
+	}
+	if got := p.resolveChangelog(context.Background(), &mockConnector{}, nil, repo, &scm.IncomingHook{}, "1.0.0", archive); got != "" {
+		t.Errorf("resolveChangelog() without tag = %q, want empty", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// resolveTagCommit / callRecorder
+// ---------------------------------------------------------------------------
+
+func TestResolveTagCommit_PrefersProviderCommit(t *testing.T) {
+	repo := &scm.ModuleSourceRepoRecord{RepositoryOwner: "o", RepositoryName: "r"}
+	// An annotated tag's webhook carries the tag object, not the commit.
+	connector := &mockConnector{tag: &scm.GitTag{TargetCommit: "commit-sha"}}
+
+	got, err := resolveTagCommit(context.Background(), connector, nil, repo, &scm.IncomingHook{TagName: "v1.0.0", CommitSHA: "tag-object-sha"})
+	if err != nil || got != "commit-sha" {
+		t.Errorf("resolveTagCommit() = %q, %v; want commit-sha", got, err)
+	}
+}
+
+func TestResolveTagCommit_FallsBackToHookCommit(t *testing.T) {
+	repo := &scm.ModuleSourceRepoRecord{RepositoryOwner: "o", RepositoryName: "r"}
+	connector := &mockConnector{tagErr: errors.New("rate limited")}
+
+	got, err := resolveTagCommit(context.Background(), connector, nil, repo, &scm.IncomingHook{TagName: "v1.0.0", CommitSHA: "hook-sha"})
+	if err != nil || got != "hook-sha" {
+		t.Errorf("resolveTagCommit() = %q, %v; want hook-sha", got, err)
+	}
+	if _, err := resolveTagCommit(context.Background(), connector, nil, repo, &scm.IncomingHook{TagName: "v1.0.0"}); err == nil {
+		t.Error("expected error when no commit can be resolved")
+	}
+}
+
+func TestCallRecorder_RecordsCallsInOrder(t *testing.T) {
+	recorder := &callRecorder{Connector: &mockConnector{archiveData: []byte("x")}}
+
+	_, _ = recorder.FetchTagByName(context.Background(), nil, "o", "r", "v1.0.0")
+	rc, err := recorder.DownloadSourceArchive(context.Background(), nil, "o", "r", "sha", scm.ArchiveTarball)
+	if err != nil {
+		t.Fatalf("DownloadSourceArchive: %v", err)
+	}
+	rc.Close()
+
+	want := []string{"FetchTagByName", "DownloadSourceArchive"}
+	if len(recorder.calls) != len(want) || recorder.calls[0] != want[0] || recorder.calls[1] != want[1] {
+		t.Errorf("calls = %v, want %v", recorder.calls, want)
+	}
+}
+
+// sizedConnector reports an archive size before the download and counts the
+// downloads that followed.
+type sizedConnector struct {
+	mockConnector
+	size      int64
+	sizeErr   error
+	downloads int
+}
+
+func (s *sizedConnector) SourceArchiveSize(context.Context, *scm.AccessToken, string, string, string, scm.ArchiveKind) (int64, error) {
+	return s.size, s.sizeErr
+}
+
+func (s *sizedConnector) DownloadSourceArchive(ctx context.Context, creds *scm.AccessToken, owner, repo, ref string, format scm.ArchiveKind) (io.ReadCloser, error) {
+	s.downloads++
+	return s.mockConnector.DownloadSourceArchive(ctx, creds, owner, repo, ref, format)
+}
+
+func TestDownloadAndPackage_RejectsReportedOversizeArchive(t *testing.T) {
+	p := newTestPublisher(t).WithArchiveSizeLimit(1 << 20)
+	connector := &sizedConnector{size: 5 << 20}
+
+	_, _, _, err := p.downloadAndPackage(context.Background(), connector, nil, "owner", "repo", "sha", "/")
+	if !errors.Is(err, ErrSourceArchiveTooLarge) {
+		t.Fatalf("err = %v, want ErrSourceArchiveTooLarge", err)
+	}
+	if !strings.Contains(err.Error(), "5.0 MiB") || !strings.Contains(err.Error(), "1.0 MiB") {
+		t.Errorf("err = %q, want the size and the limit", err)
+	}
+	if connector.downloads != 0 {
+		t.Errorf("archive downloaded %d times, want none", connector.downloads)
+	}
+}
+
+func TestDownloadAndPackage_UnknownSizeIsCappedWhileStreaming(t *testing.T) {
+	data := makeGitHubTarGz(t, "owner-repo-abc123", map[string]string{"main.tf": "# module"})
+	p := newTestPublisher(t).WithArchiveSizeLimit(int64(len(data) - 1))
+	connector := &sizedConnector{mockConnector: mockConnector{archiveData: data}, size: -1}
+
+	_, _, _, err := p.downloadAndPackage(context.Background(), connector, nil, "owner", "repo", "abc123", "/")
+	if !errors.Is(err, ErrSourceArchiveTooLarge) {
+		t.Fatalf("err = %v, want ErrSourceArchiveTooLarge", err)
+	}
+}
+
+func TestDownloadAndPackage_SizeLookupErrorFallsBackToDownload(t *testing.T) {
+	data := makeGitHubTarGz(t, "owner-repo-abc123", map[string]string{"main.tf": "# module"})
+	p := newTestPublisher(t)
+	connector := &sizedConnector{mockConnector: mockConnector{archiveData: data}, sizeErr: errors.New("HEAD refused")}
+
+	tarball, _, _, err := p.downloadAndPackage(context.Background(), connector, nil, "owner", "repo", "abc123", "/")
+	if err != nil {
+		t.Fatalf("downloadAndPackage: %v", err)
+	}
+	os.Remove(tarball)
+	if connector.downloads != 1 {
+		t.Errorf("downloads = %d, want 1", connector.downloads)
+	}
+}
+
+func TestCallRecorder_SourceArchiveSizeWithoutSizer(t *testing.T) {
+	recorder := &callRecorder{Connector: &mockConnector{}}
+
+	size, err := recorder.SourceArchiveSize(context.Background(), nil, "o", "r", "sha", scm.ArchiveTarball)
+	if err != nil || size != -1 {
+		t.Errorf("SourceArchiveSize = %d, %v; want -1, nil", size, err)
+	}
+	if len(recorder.calls) != 0 {
+		t.Errorf("calls = %v, want none", recorder.calls)
+	}
+}
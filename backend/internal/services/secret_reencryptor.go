@@ -0,0 +1,139 @@
+// secret_reencryptor.go re-seals the secrets stored in the database with the
+// current ENCRYPTION_KEY after a key rotation. While ENCRYPTION_KEY_PREVIOUS
+// is set the token cipher still opens values sealed with the old key, so the
+// registry keeps working during the rotation; once every value has been
+// re-sealed the previous key can be removed.
+package services
+
+import (
+	"context"
+
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
+)
+
+// SecretReencryptFailure is a stored value that could not be re-sealed.
+type SecretReencryptFailure struct {
+	Column string `json:"column"`
+	ID     string `json:"id"`
+	Error  string `json:"error"`
+}
+
+// SecretColumnReport counts the values of one encrypted column.
+type SecretColumnReport struct {
+	Column   string `json:"column"`
+	Values   int    `json:"values"`
+	Current  int    `json:"current"`  // already sealed with the current key
+	Previous int    `json:"previous"` // only the previous key opens them
+	Resealed int    `json:"resealed"`
+	Stale    int    `json:"stale"` // changed since listed; left alone
+	Failed   int    `json:"failed"`
+}
+
+// SecretReencryptReport summarises a re-encryption run. On a dry run nothing
+// is written and Resealed is zero; Previous still counts the values that
+// would be re-sealed.
+type SecretReencryptReport struct {
+	DryRun   bool                     `json:"dry_run"`
+	Values   int                      `json:"values"`
+	Current  int                      `json:"current"`
+	Previous int                      `json:"previous"`
+	Resealed int                      `json:"resealed"`
+	Stale    int                      `json:"stale"`
+	Failed   int                      `json:"failed"`
+	Columns  []SecretColumnReport     `json:"columns"`
+	Failures []SecretReencryptFailure `json:"failures,omitempty"`
+}
+
+// SecretReencryptor re-seals encrypted columns with the cipher's current key.
+type SecretReencryptor struct {
+	repo    *repositories.EncryptedColumnRepository
+	cipher  *crypto.TokenCipher
+	columns []repositories.EncryptedColumn
+}
+
+// NewSecretReencryptor creates a SecretReencryptor over every column in
+// repositories.EncryptedColumns.
+func NewSecretReencryptor(repo *repositories.EncryptedColumnRepository, cipher *crypto.TokenCipher) *SecretReencryptor {
+	return &SecretReencryptor{repo: repo, cipher: cipher, columns: repositories.EncryptedColumns}
+}
+
+// Run checks every stored value and, with apply, re-seals those only the
+// previous key opens. Values neither key opens are reported as failures and
+// left untouched. Progress (values checked) is reported on the operation
+// carried by ctx, and the run stops early when ctx is cancelled.
+func (r *SecretReencryptor) Run(ctx context.Context, apply bool) (*SecretReencryptReport, error) {
+	op := operations.FromContext(ctx)
+	listed := make([][]repositories.EncryptedValue, len(r.columns))
+	var total int64
+	for i, col := range r.columns {
+		values, err := r.repo.ListValues(ctx, col)
+		if err != nil {
+			return nil, err
+		}
+		listed[i] = values
+		total += int64(len(values))
+	}
+	op.SetTotal(total)
+
+	report := &SecretReencryptReport{DryRun: !apply, Columns: make([]SecretColumnReport, 0, len(r.columns))}
+	var done int64
+	for i, col := range r.columns {
+		cr := SecretColumnReport{Column: col.String(), Values: len(listed[i])}
+		for _, v := range listed[i] {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			if err := r.reseal(ctx, col, v, apply, &cr); err != nil {
+				report.Failures = append(report.Failures, SecretReencryptFailure{Column: cr.Column, ID: v.ID, Error: err.Error()})
+				cr.Failed++
+			}
+			done++
+			op.SetDone(done)
+		}
+		report.Values += cr.Values
+		report.Current += cr.Current
+		report.Previous += cr.Previous
+		report.Resealed += cr.Resealed
+		report.Stale += cr.Stale
+		report.Failed += cr.Failed
+		report.Columns = append(report.Columns, cr)
+	}
+	return report, nil
+}
+
+// reseal classifies v into cr and, with apply, re-seals it when only the
+// previous key opens it.
+func (r *SecretReencryptor) reseal(ctx context.Context, col repositories.EncryptedColumn, v repositories.EncryptedValue, apply bool, cr *SecretColumnReport) error {
+	needsReseal, err := r.cipher.NeedsReseal(v.Ciphertext)
+	if err != nil {
+		return err
+	}
+	if !needsReseal {
+		cr.Current++
+		return nil
+	}
+	cr.Previous++
+	if !apply {
+		return nil
+	}
+	plaintext, err := r.cipher.Open(v.Ciphertext)
+	if err != nil {
+		return err
+	}
+	sealed, err := r.cipher.Seal(plaintext)
+	if err != nil {
+		return err
+	}
+	updated, err := r.repo.UpdateValue(ctx, col, v, sealed)
+	if err != nil {
+		return err
+	}
+	if updated {
+		cr.Resealed++
+	} else {
+		cr.Stale++
+	}
+	return nil
+}
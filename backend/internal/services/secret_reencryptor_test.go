@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
+)
+
+func newTestReencryptor(t *testing.T) (*SecretReencryptor, sqlmock.Sqlmock, *crypto.TokenCipher, *crypto.TokenCipher) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	oldKey := bytes.Repeat([]byte("o"), 32)
+	newKey := bytes.Repeat([]byte("n"), 32)
+	oldCipher, _ := crypto.NewTokenCipher(oldKey)
+	dual, _ := crypto.NewTokenCipherWithPrevious(newKey, oldKey)
+
+	r := NewSecretReencryptor(repositories.NewEncryptedColumnRepository(db), dual)
+	r.columns = []repositories.EncryptedColumn{
+		{Table: "scm_providers", IDColumn: "id", Column: "client_secret_encrypted"},
+		{Table: "oidc_config", IDColumn: "id", Column: "client_secret_encrypted"},
+	}
+	return r, mock, oldCipher, dual
+}
+
+func TestSecretReencryptor_Apply(t *testing.T) {
+	r, mock, oldCipher, dual := newTestReencryptor(t)
+	sealedOld, _ := oldCipher.Seal("old-secret")
+	sealedNew, _ := dual.Seal("new-secret")
+	other, _ := crypto.NewTokenCipher(bytes.Repeat([]byte("x"), 32))
+	sealedOther, _ := other.Seal("lost")
+
+	mock.ExpectQuery("FROM scm_providers").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "v"}).
+			AddRow("p-1", sealedOld).AddRow("p-2", sealedNew).AddRow("p-3", sealedOther))
+	mock.ExpectQuery("FROM oidc_config").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "v"}).AddRow("o-1", sealedOld))
+	mock.ExpectExec("UPDATE scm_providers SET client_secret_encrypted").
+		WithArgs(sqlmock.AnyArg(), "p-1", sealedOld).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE oidc_config SET client_secret_encrypted").
+		WithArgs(sqlmock.AnyArg(), "o-1", sealedOld).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	registry := operations.NewRegistry()
+	ctx, op := registry.Start(context.Background(), operations.TypeSecretReencrypt, "admin", "crypto/reencrypt")
+	report, err := r.Run(ctx, true)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	info, _ := registry.Get(op.ID())
+	op.Finish()
+
+	if report.DryRun || report.Values != 4 || report.Current != 1 || report.Previous != 2 ||
+		report.Resealed != 1 || report.Stale != 1 || report.Failed != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].ID != "p-3" || report.Failures[0].Column != "scm_providers.client_secret_encrypted" {
+		t.Errorf("failures = %+v", report.Failures)
+	}
+	if len(report.Columns) != 2 || report.Columns[0].Resealed != 1 || report.Columns[1].Stale != 1 {
+		t.Errorf("columns = %+v", report.Columns)
+	}
+	if info.Done != 4 || info.Total != 4 {
+		t.Errorf("progress = %+v", info)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSecretReencryptor_DryRunWritesNothing(t *testing.T) {
+	r, mock, oldCipher, _ := newTestReencryptor(t)
+	sealedOld, _ := oldCipher.Seal("old-secret")
+
+	mock.ExpectQuery("FROM scm_providers").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "v"}).AddRow("p-1", sealedOld))
+	mock.ExpectQuery("FROM oidc_config").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "v"}))
+
+	report, err := r.Run(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !report.DryRun || report.Previous != 1 || report.Resealed != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSecretReencryptor_Cancelled(t *testing.T) {
+	r, mock, oldCipher, _ := newTestReencryptor(t)
+	sealedOld, _ := oldCipher.Seal("old-secret")
+	mock.ExpectQuery("FROM scm_providers").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "v"}).AddRow("p-1", sealedOld))
+	mock.ExpectQuery("FROM oidc_config").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "v"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Run(ctx, true); err == nil {
+		t.Fatal("expected context error")
+	}
+}
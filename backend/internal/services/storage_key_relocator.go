@@ -0,0 +1,237 @@
+// storage_key_relocator.go moves stored artifacts to their canonical storage
+// keys (see storage.CanonicalKey). Objects written before the canonical scheme
+// live under keys that keep the namespace's original case, so on a
+// case-insensitive filesystem providers/Acme/... and providers/acme/... are
+// the same directory and one artifact may have overwritten the other.
+//
+// The relocator copies each object to its canonical key, verifies the copy
+// against the checksum recorded for it, then points the database path column
+// at the new key. Downloads resolve through those columns, so artifacts can be
+// relocated gradually while the registry keeps serving. Old objects are only
+// deleted on request, once nothing references them any more.
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// Outcomes of a StorageKeyMove.
+const (
+	StorageKeyMovePlanned      = "planned"      // dry run: would be relocated
+	StorageKeyMoveRelocated    = "relocated"    // copied, verified and recorded
+	StorageKeyMoveStale        = "stale"        // key changed since it was listed; left alone
+	StorageKeyMoveUnverifiable = "unverifiable" // shares a key with another artifact and has no checksum to tell them apart
+	StorageKeyMoveFailed       = "failed"
+)
+
+// StorageKeyMove is the relocation of one artifact from its current key.
+type StorageKeyMove struct {
+	models.StoredArtifact
+	To     string `json:"to"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// StorageKeyCollision lists the keys that name the same object on a
+// case-insensitive backend, and the artifacts recorded under them.
+type StorageKeyCollision struct {
+	Keys      []string `json:"keys"`
+	Artifacts []string `json:"artifacts"` // "<kind>:<id>"
+}
+
+// StorageKeyReport summarises a relocation run.
+type StorageKeyReport struct {
+	DryRun     bool                  `json:"dry_run"`
+	Artifacts  int                   `json:"artifacts"`
+	Canonical  int                   `json:"canonical"` // already under their canonical key
+	Relocated  int                   `json:"relocated"`
+	Failed     int                   `json:"failed"`
+	OldDeleted int                   `json:"old_deleted"`
+	Collisions []StorageKeyCollision `json:"collisions,omitempty"`
+	Moves      []StorageKeyMove      `json:"moves,omitempty"`
+}
+
+// StorageKeyRelocator moves stored artifacts to canonical storage keys.
+type StorageKeyRelocator struct {
+	repo           *repositories.StorageKeyRepository
+	storageBackend storage.Storage
+}
+
+// NewStorageKeyRelocator creates a StorageKeyRelocator.
+func NewStorageKeyRelocator(repo *repositories.StorageKeyRepository, storageBackend storage.Storage) *StorageKeyRelocator {
+	return &StorageKeyRelocator{repo: repo, storageBackend: storageBackend}
+}
+
+// CanonicalStorageKey returns the key a stored artifact is written under by
+// the current upload and sync code.
+func CanonicalStorageKey(a models.StoredArtifact) (string, error) {
+	switch a.Kind {
+	case models.StoredArtifactModuleArchive:
+		return storage.ModuleArchiveKey(a.Namespace, a.Name, a.System, a.Version), nil
+	case models.StoredArtifactProviderArchive:
+		return storage.ProviderArchiveKey(a.Namespace, a.Name, a.Version, a.OS, a.Arch), nil
+	case models.StoredArtifactProviderShasums:
+		return storage.ProviderFileKey(a.Namespace, a.Name, a.Version, "SHA256SUMS"), nil
+	case models.StoredArtifactProviderShasumsSignature:
+		return storage.ProviderFileKey(a.Namespace, a.Name, a.Version, "SHA256SUMS.sig"), nil
+	case models.StoredArtifactResignedShasums:
+		return storage.ProviderFileKey(a.Namespace, a.Name, a.Version, "resigned", a.KeyID, "SHA256SUMS"), nil
+	case models.StoredArtifactResignedShasumsSignature:
+		return storage.ProviderFileKey(a.Namespace, a.Name, a.Version, "resigned", a.KeyID, "SHA256SUMS.sig"), nil
+	}
+	return "", fmt.Errorf("unknown stored artifact kind %q", a.Kind)
+}
+
+// Run plans, and with apply performs, the relocation of every artifact not
+// yet under its canonical key. With deleteOld (only honoured with apply) the
+// old objects of relocated artifacts are deleted afterwards, unless another
+// artifact still references the same key in any case.
+//
+// Artifacts whose keys collide case-insensitively are copied like any other,
+// but the copy must match the artifact's recorded checksum: the object may
+// hold another artifact's content. A mismatching copy is removed and the
+// artifact reported as failed, to be re-published. Colliding artifacts with no
+// recorded checksum cannot be checked and are left where they are.
+func (r *StorageKeyRelocator) Run(ctx context.Context, apply, deleteOld bool) (*StorageKeyReport, error) {
+	artifacts, err := r.repo.ListStoredArtifacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &StorageKeyReport{DryRun: !apply, Artifacts: len(artifacts)}
+
+	byFoldedKey := make(map[string][]models.StoredArtifact)
+	for _, a := range artifacts {
+		if storage.IsBlobKey(a.Key) {
+			// Shared by design; see MirrorBlobs.
+			continue
+		}
+		folded := strings.ToLower(a.Key)
+		byFoldedKey[folded] = append(byFoldedKey[folded], a)
+	}
+	colliding := make(map[string]bool)
+	for folded, group := range byFoldedKey {
+		if len(group) < 2 {
+			continue
+		}
+		keys := make(map[string]bool)
+		for _, a := range group {
+			keys[a.Key] = true
+		}
+		colliding[folded] = true
+		c := StorageKeyCollision{}
+		for k := range keys {
+			c.Keys = append(c.Keys, k)
+		}
+		for _, a := range group {
+			c.Artifacts = append(c.Artifacts, a.Kind+":"+a.ID)
+		}
+		sort.Strings(c.Keys)
+		sort.Strings(c.Artifacts)
+		report.Collisions = append(report.Collisions, c)
+	}
+	sort.Slice(report.Collisions, func(i, j int) bool { return report.Collisions[i].Keys[0] < report.Collisions[j].Keys[0] })
+
+	// Folded keys still referenced once the run is over; an old object is
+	// only deleted when its key is not among them.
+	referenced := make(map[string]bool)
+	var relocated []string
+
+	for _, a := range artifacts {
+		to, err := CanonicalStorageKey(a)
+		if err != nil {
+			return nil, err
+		}
+		// Mirrored archives stored as blobs are content-addressed, which is
+		// canonical for them.
+		if a.Key == to || storage.IsBlobKey(a.Key) {
+			report.Canonical++
+			referenced[strings.ToLower(a.Key)] = true
+			continue
+		}
+		move := StorageKeyMove{StoredArtifact: a, To: to}
+		switch {
+		case colliding[strings.ToLower(a.Key)] && a.Checksum == "":
+			move.Status = StorageKeyMoveUnverifiable
+		case !apply:
+			move.Status = StorageKeyMovePlanned
+		default:
+			move.Status, err = r.relocate(ctx, a, to)
+			if err != nil {
+				move.Error = err.Error()
+			}
+		}
+
+		switch move.Status {
+		case StorageKeyMoveRelocated:
+			report.Relocated++
+			relocated = append(relocated, a.Key)
+			referenced[strings.ToLower(to)] = true
+		case StorageKeyMoveFailed:
+			report.Failed++
+			referenced[strings.ToLower(a.Key)] = true
+		default:
+			referenced[strings.ToLower(a.Key)] = true
+		}
+		report.Moves = append(report.Moves, move)
+	}
+
+	if apply && deleteOld {
+		deleted := make(map[string]bool)
+		for _, key := range relocated {
+			if referenced[strings.ToLower(key)] || deleted[key] {
+				continue
+			}
+			if err := r.storageBackend.Delete(ctx, key); err != nil {
+				return report, fmt.Errorf("failed to delete old object %s: %w", key, err)
+			}
+			deleted[key] = true
+			report.OldDeleted++
+		}
+	}
+	return report, nil
+}
+
+// relocate copies a's object to key to, verifies it and records the new key.
+func (r *StorageKeyRelocator) relocate(ctx context.Context, a models.StoredArtifact, to string) (string, error) {
+	meta, err := r.storageBackend.GetMetadata(ctx, a.Key)
+	if err != nil {
+		return StorageKeyMoveFailed, fmt.Errorf("failed to stat %s: %w", a.Key, err)
+	}
+	reader, err := r.storageBackend.Download(ctx, a.Key)
+	if err != nil {
+		return StorageKeyMoveFailed, fmt.Errorf("failed to read %s: %w", a.Key, err)
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	if _, err := r.storageBackend.Upload(ctx, to, io.TeeReader(reader, hash), meta.Size); err != nil {
+		return StorageKeyMoveFailed, fmt.Errorf("failed to write %s: %w", to, err)
+	}
+	if a.Checksum != "" {
+		if got := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(got, a.Checksum) {
+			_ = r.storageBackend.Delete(ctx, to)
+			return StorageKeyMoveFailed, fmt.Errorf("content of %s does not match the recorded checksum (got %s, want %s); re-publish this artifact", a.Key, got, a.Checksum)
+		}
+	}
+
+	updated, err := r.repo.UpdateStoredArtifactKey(ctx, a, to)
+	if err != nil {
+		return StorageKeyMoveFailed, err
+	}
+	if !updated {
+		// Changed since it was listed. The copy is left in place: the row may
+		// now point at the canonical key itself.
+		return StorageKeyMoveStale, nil
+	}
+	return StorageKeyMoveRelocated, nil
+}
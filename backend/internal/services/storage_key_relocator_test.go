@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// foldingStorage behaves like a local filesystem on a case-insensitive
+// volume: keys differing only in case name the same object.
+type foldingStorage struct {
+	memStorage
+	deleted []string
+}
+
+func (f *foldingStorage) Upload(ctx context.Context, path string, r io.Reader, size int64) (*storage.UploadResult, error) {
+	return f.memStorage.Upload(ctx, strings.ToLower(path), r, size)
+}
+func (f *foldingStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	return f.memStorage.Download(ctx, strings.ToLower(path))
+}
+func (f *foldingStorage) Delete(_ context.Context, path string) error {
+	delete(f.files, strings.ToLower(path))
+	f.deleted = append(f.deleted, path)
+	return nil
+}
+func (f *foldingStorage) GetURL(ctx context.Context, path string, d time.Duration) (string, error) {
+	return f.memStorage.GetURL(ctx, strings.ToLower(path), d)
+}
+func (f *foldingStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return f.memStorage.Exists(ctx, strings.ToLower(path))
+}
+func (f *foldingStorage) GetMetadata(ctx context.Context, path string) (*storage.FileMetadata, error) {
+	return f.memStorage.GetMetadata(ctx, strings.ToLower(path))
+}
+
+func (f *foldingStorage) put(path, content string) {
+	_, _ = f.Upload(context.Background(), path, strings.NewReader(content), int64(len(content)))
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	relocModuleCols   = []string{"id", "storage_path", "namespace", "name", "system", "version", "checksum"}
+	relocPlatformCols = []string{"id", "storage_path", "namespace", "type", "version", "os", "arch", "shasum"}
+	relocVersionCols  = []string{"id", "namespace", "type", "version", "sums", "sig"}
+	relocResignCols   = []string{"id", "namespace", "type", "version", "key_id", "sums", "sig"}
+)
+
+func newRelocatorEnv(t *testing.T) (*StorageKeyRelocator, sqlmock.Sqlmock, *foldingStorage) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store := &foldingStorage{}
+	return NewStorageKeyRelocator(repositories.NewStorageKeyRepository(db), store), mock, store
+}
+
+// expectArtifacts queues the listing queries, with the module archives and
+// provider platforms given and no checksum files.
+func expectArtifacts(mock sqlmock.Sqlmock, modules, platforms, versions *sqlmock.Rows) {
+	if modules == nil {
+		modules = sqlmock.NewRows(relocModuleCols)
+	}
+	if platforms == nil {
+		platforms = sqlmock.NewRows(relocPlatformCols)
+	}
+	if versions == nil {
+		versions = sqlmock.NewRows(relocVersionCols)
+	}
+	mock.ExpectQuery("FROM module_versions mv").WillReturnRows(modules)
+	mock.ExpectQuery("FROM provider_platforms pp").WillReturnRows(platforms)
+	mock.ExpectQuery("FROM provider_versions pv").WillReturnRows(versions)
+	mock.ExpectQuery("FROM provider_version_resignatures r").WillReturnRows(sqlmock.NewRows(relocResignCols))
+}
+
+func TestStorageKeyRelocator_DryRunPlansOnly(t *testing.T) {
+	r, mock, store := newRelocatorEnv(t)
+	canonical := storage.ModuleArchiveKey("acme", "dns", "aws", "2.0.0")
+	expectArtifacts(mock, sqlmock.NewRows(relocModuleCols).
+		AddRow("mv-1", "modules/Acme/vpc/aws/1.0.0.tar.gz", "Acme", "vpc", "aws", "1.0.0", sha256Hex("vpc")).
+		AddRow("mv-2", canonical, "acme", "dns", "aws", "2.0.0", sha256Hex("dns")), nil, nil)
+	store.put("modules/Acme/vpc/aws/1.0.0.tar.gz", "vpc")
+
+	report, err := r.Run(context.Background(), false, true)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !report.DryRun || report.Artifacts != 2 || report.Canonical != 1 || report.Relocated != 0 || report.OldDeleted != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Moves) != 1 || report.Moves[0].Status != StorageKeyMovePlanned ||
+		report.Moves[0].To != storage.ModuleArchiveKey("Acme", "vpc", "aws", "1.0.0") {
+		t.Errorf("moves = %+v", report.Moves)
+	}
+	if len(store.files) != 1 {
+		t.Errorf("dry run wrote to storage: %v", store.files)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorageKeyRelocator_ApplyCopiesUpdatesAndDeletes(t *testing.T) {
+	r, mock, store := newRelocatorEnv(t)
+	oldKey := "modules/Acme/vpc/aws/1.0.0.tar.gz"
+	newKey := storage.ModuleArchiveKey("Acme", "vpc", "aws", "1.0.0")
+	expectArtifacts(mock, sqlmock.NewRows(relocModuleCols).
+		AddRow("mv-1", oldKey, "Acme", "vpc", "aws", "1.0.0", sha256Hex("vpc")), nil, nil)
+	mock.ExpectExec("UPDATE module_versions SET storage_path").
+		WithArgs(newKey, "mv-1", oldKey).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	store.put(oldKey, "vpc")
+
+	report, err := r.Run(context.Background(), true, true)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Relocated != 1 || report.Failed != 0 || report.OldDeleted != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if got := string(store.files[strings.ToLower(newKey)]); got != "vpc" {
+		t.Errorf("canonical object = %q, want %q", got, "vpc")
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != oldKey {
+		t.Errorf("deleted = %v, want [%s]", store.deleted, oldKey)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorageKeyRelocator_CaseCollision(t *testing.T) {
+	r, mock, store := newRelocatorEnv(t)
+	upper := "providers/Acme/cloud/1.0.0/linux/amd64/p.zip"
+	lower := "providers/acme/cloud/1.0.0/linux/amd64/p.zip"
+	lowerKey := storage.ProviderArchiveKey("acme", "cloud", "1.0.0", "linux", "amd64")
+	expectArtifacts(mock, nil,
+		sqlmock.NewRows(relocPlatformCols).
+			AddRow("pp-1", upper, "Acme", "cloud", "1.0.0", "linux", "amd64", sha256Hex("upper")).
+			AddRow("pp-2", lower, "acme", "cloud", "1.0.0", "linux", "amd64", sha256Hex("lower")),
+		sqlmock.NewRows(relocVersionCols).
+			AddRow("pv-1", "Acme", "cloud", "1.0.0", "providers/Acme/cloud/1.0.0/SHA256SUMS", "").
+			AddRow("pv-2", "acme", "cloud", "1.0.0", "providers/acme/cloud/1.0.0/SHA256SUMS", ""))
+	mock.ExpectExec("UPDATE provider_platforms SET storage_path").
+		WithArgs(lowerKey, "pp-2", lower).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The lower-case upload overwrote the upper-case one.
+	store.put(lower, "lower")
+	store.put("providers/acme/cloud/1.0.0/SHA256SUMS", "sums")
+
+	report, err := r.Run(context.Background(), true, true)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Collisions) != 2 {
+		t.Fatalf("collisions = %+v", report.Collisions)
+	}
+	status := map[string]string{}
+	for _, m := range report.Moves {
+		status[m.ID+"/"+m.Kind] = m.Status
+	}
+	want := map[string]string{
+		"pp-1/" + models.StoredArtifactProviderArchive: StorageKeyMoveFailed,
+		"pp-2/" + models.StoredArtifactProviderArchive: StorageKeyMoveRelocated,
+		"pv-1/" + models.StoredArtifactProviderShasums: StorageKeyMoveUnverifiable,
+		"pv-2/" + models.StoredArtifactProviderShasums: StorageKeyMoveUnverifiable,
+	}
+	for k, v := range want {
+		if status[k] != v {
+			t.Errorf("status[%s] = %q, want %q", k, status[k], v)
+		}
+	}
+	// The clobbered artifact's copy is removed, and the shared old object is
+	// kept because pp-1 still references it.
+	if _, ok := store.files[strings.ToLower(storage.ProviderArchiveKey("Acme", "cloud", "1.0.0", "linux", "amd64"))]; ok {
+		t.Error("mismatching copy was kept")
+	}
+	if _, ok := store.files[lower]; !ok || report.OldDeleted != 0 {
+		t.Errorf("old object deleted while still referenced (old_deleted = %d)", report.OldDeleted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorageKeyRelocator_SharedBlobIsCanonical(t *testing.T) {
+	r, mock, _ := newRelocatorEnv(t)
+	blob := storage.BlobKey(sha256Hex("aws"))
+	expectArtifacts(mock, nil,
+		sqlmock.NewRows(relocPlatformCols).
+			AddRow("pp-1", blob, "acme", "aws", "5.0.0", "linux", "amd64", sha256Hex("aws")).
+			AddRow("pp-2", blob, "beta", "aws", "5.0.0", "linux", "amd64", sha256Hex("aws")), nil)
+
+	report, err := r.Run(context.Background(), true, true)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Canonical != 2 || len(report.Moves) != 0 || len(report.Collisions) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorageKeyRelocator_StaleRowLeftAlone(t *testing.T) {
+	r, mock, store := newRelocatorEnv(t)
+	oldKey := "modules/acme/vpc/aws/1.0.0.tar.gz"
+	expectArtifacts(mock, sqlmock.NewRows(relocModuleCols).
+		AddRow("mv-1", oldKey, "acme", "vpc", "aws", "1.0.0", sha256Hex("vpc")), nil, nil)
+	mock.ExpectExec("UPDATE module_versions SET storage_path").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	store.put(oldKey, "vpc")
+
+	report, err := r.Run(context.Background(), true, true)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Relocated != 0 || report.OldDeleted != 0 || report.Moves[0].Status != StorageKeyMoveStale {
+		t.Errorf("report = %+v", report)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
@@ -29,6 +30,7 @@ type StorageMigrationService struct {
 	tokenCipher       *crypto.TokenCipher
 	cfg               *config.Config
 	cancelFuncs       sync.Map // migrationID -> context.CancelFunc
+	operations        *operations.Registry
 }
 
 // NewStorageMigrationService creates a new StorageMigrationService.
@@ -50,6 +52,12 @@ func NewStorageMigrationService(
 	}
 }
 
+// SetOperations registers running migrations in the in-process operations
+// registry so they appear in (and can be cancelled from) /admin/operations.
+func (s *StorageMigrationService) SetOperations(registry *operations.Registry) {
+	s.operations = registry
+}
+
 // PlanMigration counts artifacts that would be migrated between two storage configs.
 // coverage:skip:requires-infrastructure
 func (s *StorageMigrationService) PlanMigration(ctx context.Context, sourceConfigID, targetConfigID string) (*models.MigrationPlan, error) {
@@ -188,6 +196,8 @@ func (s *StorageMigrationService) StartMigration(ctx context.Context, sourceConf
 
 	// Launch background execution
 	bgCtx, cancel := context.WithCancel(context.Background())
+	bgCtx, op := s.operations.Start(bgCtx, operations.TypeStorageMigration, userID, "storage_migration/"+migrationID)
+	op.SetTotal(int64(totalArtifacts))
 	s.cancelFuncs.Store(migrationID, cancel)
 	go s.executeMigration(bgCtx, migrationID)
 
@@ -300,6 +310,20 @@ func (s *StorageMigrationService) buildStorageFromConfig(sc *models.StorageConfi
 			gcfg.CredentialsJSON = v
 		}
 		cfg.Storage.GCS = gcfg
+
+	case "artifactory":
+		afcfg := config.ArtifactoryStorageConfig{
+			BaseURL:    sc.ArtifactoryBaseURL.String,
+			Repository: sc.ArtifactoryRepository.String,
+		}
+		if sc.ArtifactoryAccessTokenEncrypted.Valid && sc.ArtifactoryAccessTokenEncrypted.String != "" {
+			v, err := s.tokenCipher.Open(sc.ArtifactoryAccessTokenEncrypted.String)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt artifactory access token: %w", err)
+			}
+			afcfg.AccessToken = v
+		}
+		cfg.Storage.Artifactory = afcfg
 	}
 
 	return storage.NewStorage(cfg)
@@ -314,6 +338,7 @@ func (s *StorageMigrationService) executeMigration(ctx context.Context, migratio
 
 	defer func() {
 		s.cancelFuncs.Delete(migrationID)
+		operations.FromContext(ctx).Finish()
 	}()
 
 	// Mark migration as running
@@ -381,7 +406,8 @@ func (s *StorageMigrationService) executeMigration(ctx context.Context, migratio
 		case <-ctx.Done():
 			log.Info("migration cancelled, waiting for in-flight workers")
 			wg.Wait()
-			s.updateProgress(migrationID, &migrated, &failed, &skipped)
+			s.updateProgress(ctx, migrationID, &migrated, &failed, &skipped)
+			s.markCancelled(migrationID)
 			return
 		default:
 		}
@@ -401,7 +427,8 @@ func (s *StorageMigrationService) executeMigration(ctx context.Context, migratio
 			select {
 			case <-ctx.Done():
 				wg.Wait()
-				s.updateProgress(migrationID, &migrated, &failed, &skipped)
+				s.updateProgress(ctx, migrationID, &migrated, &failed, &skipped)
+				s.markCancelled(migrationID)
 				return
 			default:
 			}
@@ -426,17 +453,17 @@ func (s *StorageMigrationService) executeMigration(ctx context.Context, migratio
 				// Periodically update progress in the DB
 				total := atomic.LoadInt64(&migrated) + atomic.LoadInt64(&failed) + atomic.LoadInt64(&skipped)
 				if total%10 == 0 {
-					s.updateProgress(migrationID, &migrated, &failed, &skipped)
+					s.updateProgress(ctx, migrationID, &migrated, &failed, &skipped)
 				}
 			}(item)
 		}
 
 		wg.Wait()
-		s.updateProgress(migrationID, &migrated, &failed, &skipped)
+		s.updateProgress(ctx, migrationID, &migrated, &failed, &skipped)
 	}
 
 	wg.Wait()
-	s.updateProgress(migrationID, &migrated, &failed, &skipped)
+	s.updateProgress(ctx, migrationID, &migrated, &failed, &skipped)
 
 	// Final status
 	finalFailed := atomic.LoadInt64(&failed)
@@ -537,15 +564,25 @@ func (s *StorageMigrationService) updateBackendRef(ctx context.Context, item *mo
 	}
 }
 
-// updateProgress writes current counters to the migration record.
-func (s *StorageMigrationService) updateProgress(migrationID string, migrated, failed, skipped *int64) {
+// updateProgress writes current counters to the migration record and to the
+// operation carried by opCtx, if any.
+func (s *StorageMigrationService) updateProgress(opCtx context.Context, migrationID string, migrated, failed, skipped *int64) {
+	m, f, sk := atomic.LoadInt64(migrated), atomic.LoadInt64(failed), atomic.LoadInt64(skipped)
+	operations.FromContext(opCtx).SetDone(m + f + sk)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	_ = s.repo.UpdateMigrationProgress(ctx, migrationID,
-		int(atomic.LoadInt64(migrated)),
-		int(atomic.LoadInt64(failed)),
-		int(atomic.LoadInt64(skipped)),
-	)
+	_ = s.repo.UpdateMigrationProgress(ctx, migrationID, int(m), int(f), int(sk))
+}
+
+// markCancelled records a migration stopped by context cancellation as
+// cancelled. CancelMigration already does this itself; cancellation through
+// the operations registry does not, and the migration's own context is done
+// by then, hence the fresh one.
+func (s *StorageMigrationService) markCancelled(migrationID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = s.repo.UpdateMigrationStatus(ctx, migrationID, "cancelled", nil)
 }
 
 // ensure sql import is used (referenced by models.StorageConfig using sql.NullString)
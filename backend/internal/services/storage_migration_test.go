@@ -11,6 +11,7 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/operations"
 
 	// Register the local storage backend for buildStorageFromConfig tests.
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/local"
@@ -644,8 +645,35 @@ func TestUpdateProgress(t *testing.T) {
 		WithArgs("mig-1", 10, 2, 0).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
+	ctx, op := operations.NewRegistry().Start(context.Background(), operations.TypeStorageMigration, "u1", "storage_migration/mig-1")
+	defer op.Finish()
+
 	var migrated, failed, skipped int64 = 10, 2, 0
-	svc.updateProgress("mig-1", &migrated, &failed, &skipped)
+	svc.updateProgress(ctx, "mig-1", &migrated, &failed, &skipped)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+	if done := op.Info().Done; done != 12 {
+		t.Errorf("operation done = %d, want 12", done)
+	}
+}
+
+func TestMarkCancelled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	repo := repositories.NewStorageMigrationRepository(sqlx.NewDb(db, "sqlmock"))
+	svc := NewStorageMigrationService(repo, nil, nil, nil, nil, nil)
+
+	mock.ExpectExec("UPDATE storage_migrations SET status").
+		WithArgs("mig-1", "cancelled", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	svc.markCancelled("mig-1")
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unfulfilled expectations: %v", err)
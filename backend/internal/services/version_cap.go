@@ -0,0 +1,119 @@
+// version_cap.go enforces the soft cap on how many versions a module keeps
+// listed. Every publish path (uploads, publishes from a URL, and SCM tag
+// publishes) calls CheckPublish before storing a version and AfterPublish once
+// it is recorded.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// ErrVersionCapExceeded is returned by CheckPublish when a module in strict
+// mode already lists its maximum number of versions.
+var ErrVersionCapExceeded = errors.New("module version cap reached")
+
+// versionCapStore is the subset of ModuleRepository VersionCap needs.
+type versionCapStore interface {
+	GetVersionCap(ctx context.Context, moduleID string) (*models.ModuleVersionCap, error)
+	ArchiveOldestVersions(ctx context.Context, moduleID, keepVersionID string, maxVersions int) ([]string, error)
+}
+
+// auditLogWriter is the subset of AuditRepository VersionCap needs.
+type auditLogWriter interface {
+	CreateAuditLog(ctx context.Context, log *models.AuditLog) error
+}
+
+// VersionCap applies a module's version cap (see models.ModuleVersionCap) to
+// publishes. A nil *VersionCap enforces nothing.
+type VersionCap struct {
+	store versionCapStore
+	audit auditLogWriter // optional: records archivals
+}
+
+// NewVersionCap creates a VersionCap. auditRepo may be nil.
+func NewVersionCap(moduleRepo *repositories.ModuleRepository, auditRepo *repositories.AuditRepository) *VersionCap {
+	vc := &VersionCap{store: moduleRepo}
+	if auditRepo != nil {
+		vc.audit = auditRepo
+	}
+	return vc
+}
+
+// CheckPublish returns an error wrapping ErrVersionCapExceeded when publishing
+// one more version of moduleID would exceed its cap in strict mode. override
+// is the admin escape hatch and skips the check.
+func (v *VersionCap) CheckPublish(ctx context.Context, moduleID string, override bool) error {
+	if v == nil || override {
+		return nil
+	}
+	vc, err := v.store.GetVersionCap(ctx, moduleID)
+	if err != nil {
+		return err
+	}
+	if vc == nil || vc.MaxVersions == nil || vc.Mode != models.VersionCapModeStrict {
+		return nil
+	}
+	if vc.ActiveVersions >= *vc.MaxVersions {
+		return fmt.Errorf("%w: the module already lists %d of %d allowed versions", ErrVersionCapExceeded, vc.ActiveVersions, *vc.MaxVersions)
+	}
+	return nil
+}
+
+// AfterPublish archives the oldest unapproved versions of a module in rolling
+// mode until it is back under its cap, and records the archival in the audit
+// log. It never fails the publish: problems are logged. override skips it, so
+// an admin can publish past the cap without archiving anything.
+func (v *VersionCap) AfterPublish(ctx context.Context, published *models.ModuleVersion, publishedBy *string, override bool) {
+	if v == nil || override || published == nil {
+		return
+	}
+	vc, err := v.store.GetVersionCap(ctx, published.ModuleID)
+	if err != nil {
+		slog.Warn("version cap: failed to load cap", "module_id", published.ModuleID, "error", err)
+		return
+	}
+	if vc == nil || vc.MaxVersions == nil || vc.Mode != models.VersionCapModeRolling || vc.ActiveVersions <= *vc.MaxVersions {
+		return
+	}
+
+	archived, err := v.store.ArchiveOldestVersions(ctx, published.ModuleID, published.ID, *vc.MaxVersions)
+	if err != nil {
+		slog.Warn("version cap: failed to archive versions", "module_id", published.ModuleID, "error", err)
+		return
+	}
+	if remaining := vc.ActiveVersions - len(archived); remaining > *vc.MaxVersions {
+		slog.Warn("version cap: module still over its cap, remaining versions are approved",
+			"module_id", published.ModuleID, "max_versions", *vc.MaxVersions, "listed_versions", remaining)
+	}
+	if len(archived) == 0 {
+		return
+	}
+	slog.Info("version cap: archived oldest module versions",
+		"module_id", published.ModuleID, "versions", archived, "max_versions", *vc.MaxVersions)
+
+	if v.audit == nil {
+		return
+	}
+	resourceType := "module"
+	moduleID := published.ModuleID
+	entry := &models.AuditLog{
+		UserID:       publishedBy,
+		Action:       "module.versions_archived",
+		ResourceType: &resourceType,
+		ResourceID:   &moduleID,
+		Metadata: map[string]interface{}{
+			"versions":          archived,
+			"max_versions":      *vc.MaxVersions,
+			"published_version": published.Version,
+		},
+	}
+	if err := v.audit.CreateAuditLog(ctx, entry); err != nil {
+		slog.Warn("version cap: failed to write audit log", "module_id", published.ModuleID, "error", err)
+	}
+}
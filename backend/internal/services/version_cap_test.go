@@ -0,0 +1,153 @@
+// version_cap_test.go tests VersionCap with a fake cap store and audit log.
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+type fakeVersionCapStore struct {
+	cap      *models.ModuleVersionCap
+	capErr   error
+	archived []string
+
+	archiveCalls int
+	archiveKeep  string
+	archiveMax   int
+}
+
+func (f *fakeVersionCapStore) GetVersionCap(_ context.Context, _ string) (*models.ModuleVersionCap, error) {
+	return f.cap, f.capErr
+}
+
+func (f *fakeVersionCapStore) ArchiveOldestVersions(_ context.Context, _, keepVersionID string, maxVersions int) ([]string, error) {
+	f.archiveCalls++
+	f.archiveKeep = keepVersionID
+	f.archiveMax = maxVersions
+	return f.archived, nil
+}
+
+type fakeAuditLogWriter struct {
+	logs []*models.AuditLog
+}
+
+func (f *fakeAuditLogWriter) CreateAuditLog(_ context.Context, log *models.AuditLog) error {
+	f.logs = append(f.logs, log)
+	return nil
+}
+
+func capOf(maxVersions int, mode string, active int) *models.ModuleVersionCap {
+	return &models.ModuleVersionCap{MaxVersions: &maxVersions, Mode: mode, ActiveVersions: active}
+}
+
+func TestVersionCap_CheckPublish(t *testing.T) {
+	tests := []struct {
+		name     string
+		cap      *models.ModuleVersionCap
+		override bool
+		wantErr  bool
+	}{
+		{"no cap", &models.ModuleVersionCap{Mode: models.VersionCapModeStrict, ActiveVersions: 100}, false, false},
+		{"strict under cap", capOf(3, models.VersionCapModeStrict, 2), false, false},
+		{"strict at cap", capOf(3, models.VersionCapModeStrict, 3), false, true},
+		{"strict at cap with override", capOf(3, models.VersionCapModeStrict, 3), true, false},
+		{"rolling at cap", capOf(3, models.VersionCapModeRolling, 3), false, false},
+		{"module not found", nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vc := &VersionCap{store: &fakeVersionCapStore{cap: tt.cap}}
+			err := vc.CheckPublish(context.Background(), "mod-1", tt.override)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckPublish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrVersionCapExceeded) {
+				t.Errorf("error %v does not wrap ErrVersionCapExceeded", err)
+			}
+		})
+	}
+}
+
+func TestVersionCap_CheckPublishStoreError(t *testing.T) {
+	vc := &VersionCap{store: &fakeVersionCapStore{capErr: errors.New("db down")}}
+	err := vc.CheckPublish(context.Background(), "mod-1", false)
+	if err == nil || errors.Is(err, ErrVersionCapExceeded) {
+		t.Errorf("CheckPublish() error = %v, want the store error", err)
+	}
+}
+
+func TestVersionCap_NilIsNoOp(t *testing.T) {
+	var vc *VersionCap
+	if err := vc.CheckPublish(context.Background(), "mod-1", false); err != nil {
+		t.Errorf("CheckPublish() on nil = %v", err)
+	}
+	vc.AfterPublish(context.Background(), &models.ModuleVersion{ModuleID: "mod-1"}, nil, false)
+}
+
+func TestVersionCap_AfterPublishArchivesInRollingMode(t *testing.T) {
+	store := &fakeVersionCapStore{cap: capOf(3, models.VersionCapModeRolling, 5), archived: []string{"1.0.0", "1.1.0"}}
+	audit := &fakeAuditLogWriter{}
+	vc := &VersionCap{store: store, audit: audit}
+	user := "user-1"
+
+	vc.AfterPublish(context.Background(), &models.ModuleVersion{ID: "ver-new", ModuleID: "mod-1", Version: "2.0.0"}, &user, false)
+
+	if store.archiveCalls != 1 || store.archiveKeep != "ver-new" || store.archiveMax != 3 {
+		t.Fatalf("ArchiveOldestVersions calls=%d keep=%q max=%d; want 1, ver-new, 3",
+			store.archiveCalls, store.archiveKeep, store.archiveMax)
+	}
+	if len(audit.logs) != 1 {
+		t.Fatalf("audit logs = %d, want 1", len(audit.logs))
+	}
+	entry := audit.logs[0]
+	if entry.Action != "module.versions_archived" || entry.UserID == nil || *entry.UserID != user ||
+		entry.ResourceID == nil || *entry.ResourceID != "mod-1" {
+		t.Errorf("audit entry = %+v", entry)
+	}
+	if entry.Metadata["published_version"] != "2.0.0" {
+		t.Errorf("audit metadata = %v", entry.Metadata)
+	}
+}
+
+func TestVersionCap_AfterPublishSkips(t *testing.T) {
+	tests := []struct {
+		name     string
+		cap      *models.ModuleVersionCap
+		override bool
+	}{
+		{"strict mode", capOf(3, models.VersionCapModeStrict, 5), false},
+		{"rolling within cap", capOf(3, models.VersionCapModeRolling, 3), false},
+		{"override", capOf(3, models.VersionCapModeRolling, 5), true},
+		{"no cap", &models.ModuleVersionCap{Mode: models.VersionCapModeRolling, ActiveVersions: 50}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeVersionCapStore{cap: tt.cap}
+			audit := &fakeAuditLogWriter{}
+			vc := &VersionCap{store: store, audit: audit}
+			vc.AfterPublish(context.Background(), &models.ModuleVersion{ID: "ver-new", ModuleID: "mod-1"}, nil, tt.override)
+			if store.archiveCalls != 0 || len(audit.logs) != 0 {
+				t.Errorf("archive calls = %d, audit logs = %d; want none", store.archiveCalls, len(audit.logs))
+			}
+		})
+	}
+}
+
+func TestVersionCap_AfterPublishNothingArchivable(t *testing.T) {
+	// Every older version is approved, so nothing can be archived.
+	store := &fakeVersionCapStore{cap: capOf(2, models.VersionCapModeRolling, 4)}
+	audit := &fakeAuditLogWriter{}
+	vc := &VersionCap{store: store, audit: audit}
+
+	vc.AfterPublish(context.Background(), &models.ModuleVersion{ID: "ver-new", ModuleID: "mod-1"}, nil, false)
+
+	if store.archiveCalls != 1 {
+		t.Errorf("archive calls = %d, want 1", store.archiveCalls)
+	}
+	if len(audit.logs) != 0 {
+		t.Errorf("audit logs = %d, want none when nothing was archived", len(audit.logs))
+	}
+}
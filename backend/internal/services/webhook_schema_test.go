@@ -0,0 +1,179 @@
+// webhook_schema_test.go is the compatibility suite for outbound webhook
+// payloads. A sample payload of every event type is compared with its
+// snapshot in testdata/webhook_schema/<webhook>/v<schema_version>/<type>.json,
+// so a change to a payload's shape fails here. A field may be added by
+// rewriting the snapshots with -update; removing or renaming one means bumping
+// the schema version, which starts a new snapshot directory and leaves the old
+// one as the record of the previous version.
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/events"
+)
+
+var updateSchemaSnapshots = flag.Bool("update", false, "rewrite the webhook payload snapshots of the current schema versions")
+
+// schemaSampleTime is the timestamp of every sample payload.
+var schemaSampleTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// sampleOrgEvent returns an event of type t carrying every data key the type
+// declares, each set to "<key>".
+func sampleOrgEvent(t string) events.Event {
+	data := map[string]string{}
+	for _, key := range events.DataFields[t] {
+		data[key] = "<" + key + ">"
+	}
+	return events.Event{
+		ID:             "00000000-0000-0000-0000-000000000001",
+		Type:           t,
+		SchemaVersion:  events.SchemaVersion,
+		OrganizationID: "00000000-0000-0000-0000-000000000002",
+		ActorID:        "00000000-0000-0000-0000-000000000003",
+		OccurredAt:     schemaSampleTime,
+		Data:           data,
+	}
+}
+
+// samplePublishHookEvent returns a publish hook event of type t with every
+// optional field set.
+func samplePublishHookEvent(t string) *publishHookEvent {
+	h := &PublishHooks{now: func() time.Time { return schemaSampleTime }}
+	publishedBy := "00000000-0000-0000-0000-000000000003"
+	return h.event(t, "00000000-0000-0000-0000-000000000004", &ModuleVersionPublish{
+		OrganizationID: "00000000-0000-0000-0000-000000000002",
+		Source:         "scm",
+		Namespace:      "acme",
+		Name:           "vpc",
+		System:         "aws",
+		Version:        "1.2.3",
+		Checksum:       strings.Repeat("ab", 32),
+		SizeBytes:      1024,
+		PublishedBy:    &publishedBy,
+		CommitSHA:      strings.Repeat("c", 40),
+		TagName:        "v1.2.3",
+		DryRun:         true,
+	})
+}
+
+// assertSchemaSnapshots compares the sample payload of each type with its
+// snapshot under dir, or rewrites dir with -update, and fails on a snapshot
+// no type accounts for.
+func assertSchemaSnapshots(t *testing.T, dir string, types []string, sample func(string) interface{}) {
+	t.Helper()
+	if *updateSchemaSnapshots {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]bool{}
+	for _, typ := range types {
+		want[typ+".json"] = true
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(sample(typ)); err != nil {
+			t.Fatalf("%s: encode: %v", typ, err)
+		}
+
+		path := filepath.Join(dir, typ+".json")
+		if *updateSchemaSnapshots {
+			if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+				t.Fatalf("write %s: %v", path, err)
+			}
+			continue
+		}
+		snapshot, err := os.ReadFile(path) // #nosec G304 -- path is built from a known event type
+		if err != nil {
+			t.Errorf("%s: no snapshot (run with -update to create it, if the payload is meant to change): %v", typ, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), snapshot) {
+			t.Errorf("%s payload no longer matches %s; removing or renaming a field needs a schema version bump\ngot:\n%s\nwant:\n%s",
+				typ, path, buf.Bytes(), snapshot)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if !want[e.Name()] {
+			t.Errorf("%s has a snapshot for no event type: %s", dir, e.Name())
+		}
+	}
+}
+
+func TestWebhookSchema_OrgEvents(t *testing.T) {
+	types := append(append([]string{}, events.Types...), events.TypeTest)
+	for _, typ := range types {
+		if _, ok := events.DataFields[typ]; !ok {
+			t.Errorf("events.DataFields has no entry for %s", typ)
+		}
+	}
+	dir := filepath.Join("testdata", "webhook_schema", "organization_event", fmt.Sprintf("v%d", events.SchemaVersion))
+	assertSchemaSnapshots(t, dir, types, func(typ string) interface{} { return sampleOrgEvent(typ) })
+}
+
+func TestWebhookSchema_PublishHooks(t *testing.T) {
+	dir := filepath.Join("testdata", "webhook_schema", "publish_hook", fmt.Sprintf("v%d", PublishHookSchemaVersion))
+	assertSchemaSnapshots(t, dir, []string{PublishHookEventPublish, PublishHookEventTest},
+		func(typ string) interface{} { return samplePublishHookEvent(typ) })
+}
+
+// TestWebhookSchema_VersionsKept checks that no earlier schema version's
+// snapshots were deleted: each webhook keeps a directory for every version
+// up to the current one.
+func TestWebhookSchema_VersionsKept(t *testing.T) {
+	for webhook, current := range map[string]int{
+		"organization_event": events.SchemaVersion,
+		"publish_hook":       PublishHookSchemaVersion,
+	} {
+		entries, err := os.ReadDir(filepath.Join("testdata", "webhook_schema", webhook))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Name())
+		}
+		sort.Strings(got)
+		for v := 1; v <= current; v++ {
+			name := fmt.Sprintf("v%d", v)
+			if i := sort.SearchStrings(got, name); i == len(got) || got[i] != name {
+				t.Errorf("%s: snapshots of schema version %d are missing", webhook, v)
+			}
+		}
+	}
+}
+
+func TestWebhookVerification_ListsEveryEventType(t *testing.T) {
+	info := WebhookVerification()
+	listed := map[string]bool{}
+	for _, p := range info.Payloads {
+		for _, et := range p.EventTypes {
+			listed[et.Type] = true
+		}
+	}
+	for _, typ := range append(append([]string{}, events.Types...), events.TypeTest, PublishHookEventPublish, PublishHookEventTest) {
+		if !listed[typ] {
+			t.Errorf("verification info does not list %s", typ)
+		}
+	}
+}
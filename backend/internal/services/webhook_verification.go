@@ -0,0 +1,107 @@
+// webhook_verification.go describes, for receivers, how the registry's
+// outbound webhooks are signed and versioned: organization event webhooks and
+// pre-publish hooks. It is served unauthenticated at
+// GET /api/v1/webhooks/verification-info, so it must hold nothing specific to
+// an organization.
+package services
+
+import (
+	"sort"
+
+	"github.com/terraform-registry/terraform-registry/internal/events"
+)
+
+// WebhookVerificationInfo is the verification recipe for outbound webhooks.
+type WebhookVerificationInfo struct {
+	// Algorithm is the signature algorithm: "HMAC-SHA256".
+	Algorithm string `json:"algorithm"`
+	// Headers describes each header a delivery may carry, by name.
+	Headers []WebhookHeaderInfo `json:"headers"`
+	// Steps is the verification recipe, in order.
+	Steps []string `json:"steps"`
+	// KeyRotation explains how a secret rotation shows in the headers.
+	KeyRotation string `json:"key_rotation"`
+	// Payloads describes each kind of webhook, its schema version and
+	// event types.
+	Payloads []WebhookPayloadInfo `json:"payloads"`
+}
+
+// WebhookHeaderInfo describes one request header.
+type WebhookHeaderInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Webhooks lists the kinds of webhook sending the header.
+	Webhooks []string `json:"webhooks"`
+}
+
+// WebhookPayloadInfo describes one kind of webhook payload.
+type WebhookPayloadInfo struct {
+	// Webhook is the kind of webhook: "organization_event" or "publish_hook".
+	Webhook string `json:"webhook"`
+	// SchemaVersion is the version of the payload's JSON shape, sent as
+	// schema_version and in X-Registry-Schema-Version. It is bumped when a
+	// field is removed or changes meaning; added fields and event types do
+	// not bump it, so receivers must ignore fields they do not know.
+	SchemaVersion int                    `json:"schema_version"`
+	EventTypes    []WebhookEventTypeInfo `json:"event_types"`
+}
+
+// WebhookEventTypeInfo describes one event type of a payload kind.
+type WebhookEventTypeInfo struct {
+	Type string `json:"type"`
+	// DataFields lists the keys of an organization event's data object;
+	// unset for publish hooks, whose fields are top level.
+	DataFields []string `json:"data_fields,omitempty"`
+}
+
+// Kinds of outbound webhook, as named in WebhookVerificationInfo.
+const (
+	webhookKindOrgEvent    = "organization_event"
+	webhookKindPublishHook = "publish_hook"
+)
+
+// WebhookVerification returns the verification recipe for outbound webhooks.
+func WebhookVerification() *WebhookVerificationInfo {
+	both := []string{webhookKindOrgEvent, webhookKindPublishHook}
+	orgEventTypes := append(append([]string{}, events.Types...), events.TypeTest)
+	eventTypes := make([]WebhookEventTypeInfo, 0, len(orgEventTypes))
+	for _, t := range orgEventTypes {
+		fields := append([]string{}, events.DataFields[t]...)
+		sort.Strings(fields)
+		eventTypes = append(eventTypes, WebhookEventTypeInfo{Type: t, DataFields: fields})
+	}
+
+	return &WebhookVerificationInfo{
+		Algorithm: "HMAC-SHA256",
+		Headers: []WebhookHeaderInfo{
+			{Name: PublishHookSignatureHeader, Webhooks: both,
+				Description: `"sha256=" followed by the lowercase hex HMAC-SHA256 of the raw request body, keyed with the webhook secret. For event webhooks, the secret is the one named by ` + OrgEventSignatureKeyIDHeader + "."},
+			{Name: OrgEventSignatureKeyIDHeader, Webhooks: []string{webhookKindOrgEvent},
+				Description: "Key ID of the secret that computed " + PublishHookSignatureHeader + ", as returned in the webhook's secret_key_id."},
+			{Name: OrgEventSignaturesHeader, Webhooks: []string{webhookKindOrgEvent},
+				Description: `Comma-separated "<key_id>:sha256=<hex>" signatures, one for every secret signing the delivery.`},
+			{Name: PublishHookEventHeader, Webhooks: both, Description: "The event type."},
+			{Name: PublishHookSchemaVersionHeader, Webhooks: both, Description: "The payload's schema_version."},
+			{Name: PublishHookDeliveryHeader, Webhooks: both,
+				Description: "Unique ID of the delivery; a retry of the same delivery repeats it. Absent on the badly signed copy a webhook test sends."},
+		},
+		Steps: []string{
+			"Read the raw request body before parsing it; the signature covers the exact bytes sent.",
+			"Event webhooks: for each entry of " + OrgEventSignaturesHeader + " whose key ID is one you hold a secret for, compute HMAC-SHA256 of the body keyed with that secret and compare its lowercase hex encoding, prefixed with \"sha256=\", to the entry's signature.",
+			"Publish hooks, or receivers that do not use key IDs: compare the same computation with " + PublishHookSignatureHeader + ".",
+			"Compare signatures in constant time (hmac.compare_digest, crypto.timingSafeEqual, hmac.Equal) and reject the request with a 4xx status unless one matches.",
+			"Check schema_version: reject, or route to a different parser, a version you were not written for, and ignore fields you do not know.",
+			"Use " + PublishHookDeliveryHeader + " to drop deliveries you have already processed.",
+		},
+		KeyRotation: "Rotating an event webhook's secret adds a next secret with its own key ID. Until the rotation's overlap_until, deliveries are signed with both secrets: " +
+			PublishHookSignatureHeader + " and " + OrgEventSignatureKeyIDHeader + " keep using the current secret and " + OrgEventSignaturesHeader +
+			" carries both signatures. After overlap_until, or once the rotation is completed, only the next secret signs. Install the next secret on the receiver before the overlap ends.",
+		Payloads: []WebhookPayloadInfo{
+			{Webhook: webhookKindOrgEvent, SchemaVersion: events.SchemaVersion, EventTypes: eventTypes},
+			{Webhook: webhookKindPublishHook, SchemaVersion: PublishHookSchemaVersion, EventTypes: []WebhookEventTypeInfo{
+				{Type: PublishHookEventPublish},
+				{Type: PublishHookEventTest},
+			}},
+		},
+	}
+}
@@ -0,0 +1,351 @@
+// Package artifactory implements a storage backend for JFrog Artifactory using its REST API:
+// artifacts are deployed with PUT (carrying checksum headers so Artifactory can verify the
+// upload), read with GET, probed with HEAD, and removed with DELETE against
+// {base_url}/{repository}/{path}. Any generic WebDAV server that accepts the same verbs and
+// creates intermediate collections on PUT works too; checksum headers are then simply ignored.
+//
+// Artifactory has no equivalent of presigned URLs and its access token must never reach
+// clients, so GetURL points at the registry's /v1/files endpoint, which streams the artifact
+// through the registry.
+package artifactory
+
+import (
+	"context"
+	"crypto/md5"  // #nosec G501 -- X-Checksum (MD5) is an Artifactory integrity header, not a security control
+	"crypto/sha1" // #nosec G505 -- X-Checksum-Sha1 is an Artifactory integrity header, not a security control
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	appconfig "github.com/terraform-registry/terraform-registry/internal/config"
+	appstorage "github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+func init() {
+	// Register Artifactory storage backend
+	appstorage.Register("artifactory", func(cfg *appconfig.Config) (appstorage.Storage, error) {
+		return New(&cfg.Storage.Artifactory, cfg.Server.BaseURL)
+	})
+}
+
+const (
+	// defaultMaxRetries is how many times a request rejected with 429 Too Many
+	// Requests is retried before the error is returned.
+	defaultMaxRetries = 3
+	// defaultRetryWait is the first backoff interval when the server sends no
+	// usable Retry-After header; it doubles on each attempt.
+	defaultRetryWait = 500 * time.Millisecond
+	// maxRetryWait caps both Retry-After and the exponential backoff.
+	maxRetryWait = 30 * time.Second
+	// errorBodyLimit bounds how much of an error response is quoted in errors.
+	errorBodyLimit = 512
+)
+
+// ArtifactoryStorage implements the Storage interface for JFrog Artifactory
+// and generic WebDAV servers
+type ArtifactoryStorage struct {
+	client        *http.Client
+	rootURL       string // base URL plus repository, without trailing slash
+	accessToken   string
+	serverBaseURL string
+	maxRetries    int
+	retryWait     time.Duration
+}
+
+// New creates a new Artifactory storage backend
+func New(cfg *appconfig.ArtifactoryStorageConfig, serverBaseURL string) (*ArtifactoryStorage, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("artifactory base_url is required")
+	}
+	parsed, err := url.Parse(cfg.BaseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("artifactory base_url must be an absolute http(s) URL: %q", cfg.BaseURL)
+	}
+
+	rootURL := strings.TrimRight(cfg.BaseURL, "/")
+	if repo := strings.Trim(cfg.Repository, "/"); repo != "" {
+		rootURL += "/" + url.PathEscape(repo)
+	}
+
+	return &ArtifactoryStorage{
+		client:        &http.Client{Timeout: 10 * time.Minute},
+		rootURL:       rootURL,
+		accessToken:   cfg.AccessToken,
+		serverBaseURL: serverBaseURL,
+		maxRetries:    defaultMaxRetries,
+		retryWait:     defaultRetryWait,
+	}, nil
+}
+
+// objectURL returns the artifact URL for a storage path, escaping each segment
+// and rejecting paths that would leave the repository.
+func (s *ArtifactoryStorage) objectURL(path string) (string, error) {
+	trimmed := strings.TrimLeft(path, "/")
+	if trimmed == "" {
+		return "", fmt.Errorf("storage path is required")
+	}
+	segments := strings.Split(trimmed, "/")
+	for i, seg := range segments {
+		if seg == "" || seg == "." || seg == ".." {
+			return "", fmt.Errorf("invalid storage path: %s", path)
+		}
+		segments[i] = url.PathEscape(seg)
+	}
+	return s.rootURL + "/" + strings.Join(segments, "/"), nil
+}
+
+// do sends a request, retrying while the server answers 429 Too Many Requests.
+// newBody, when non-nil, must return a fresh reader and its length for every
+// attempt.
+func (s *ArtifactoryStorage) do(ctx context.Context, method, path string, newBody func() (io.Reader, int64, error), header http.Header) (*http.Response, error) {
+	target, err := s.objectURL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		var length int64
+		if newBody != nil {
+			if body, length, err = newBody(); err != nil {
+				return nil, err
+			}
+		}
+		req, err := http.NewRequestWithContext(ctx, method, target, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s request: %w", method, err)
+		}
+		if body != nil {
+			req.ContentLength = length
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		if s.accessToken != "" {
+			req.Header.Set("Authorization", "Bearer "+s.accessToken)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s failed: %w", method, path, err)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= s.maxRetries {
+			return resp, nil
+		}
+
+		wait := s.backoff(resp, attempt)
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, errorBodyLimit))
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns how long to wait before retrying a 429 response: the
+// server's Retry-After when present, otherwise exponential backoff.
+func (s *ArtifactoryStorage) backoff(resp *http.Response, attempt int) time.Duration {
+	wait := s.retryWait << attempt
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil && secs >= 0 {
+			wait = time.Duration(secs) * time.Second
+		} else if at, err := http.ParseTime(ra); err == nil {
+			wait = time.Until(at)
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > maxRetryWait {
+		wait = maxRetryWait
+	}
+	return wait
+}
+
+// statusError builds an error for an unexpected response, quoting the start
+// of the body. The response body is consumed and closed.
+func statusError(resp *http.Response, op, path string) error {
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyLimit))
+	msg := strings.TrimSpace(string(snippet))
+	if msg == "" {
+		return fmt.Errorf("artifactory %s %s: unexpected status %d", op, path, resp.StatusCode)
+	}
+	return fmt.Errorf("artifactory %s %s: unexpected status %d: %s", op, path, resp.StatusCode, msg)
+}
+
+// Upload deploys a file to Artifactory. The content is spooled to a temporary
+// file first so the checksum headers can be sent up front and the body can be
+// replayed when the request is rate limited.
+func (s *ArtifactoryStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*appstorage.UploadResult, error) {
+	tmp, err := os.CreateTemp("", "artifactory-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload spool file: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	sha256Hash := sha256.New()
+	sha1Hash := sha1.New() // #nosec G401 -- integrity header only
+	md5Hash := md5.New()   // #nosec G401 -- integrity header only
+	written, err := io.Copy(io.MultiWriter(tmp, sha256Hash, sha1Hash, md5Hash), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload content: %w", err)
+	}
+	checksum := hex.EncodeToString(sha256Hash.Sum(nil))
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("X-Checksum-Sha256", checksum)
+	header.Set("X-Checksum-Sha1", hex.EncodeToString(sha1Hash.Sum(nil)))
+	header.Set("X-Checksum", hex.EncodeToString(md5Hash.Sum(nil)))
+
+	resp, err := s.do(ctx, http.MethodPut, path, func() (io.Reader, int64, error) {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, 0, fmt.Errorf("failed to rewind upload spool file: %w", err)
+		}
+		// NopCloser keeps the transport from closing the spool file between attempts.
+		return io.NopCloser(io.LimitReader(tmp, written)), written, nil
+	}, header)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	default:
+		return nil, statusError(resp, "upload", path)
+	}
+
+	return &appstorage.UploadResult{
+		Path:     path,
+		Size:     written,
+		Checksum: checksum,
+	}, nil
+}
+
+// Download retrieves a file from Artifactory
+func (s *ArtifactoryStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("file not found: %s", path)
+	default:
+		return nil, statusError(resp, "download", path)
+	}
+}
+
+// Delete removes a file from Artifactory. A missing file is not an error.
+func (s *ArtifactoryStorage) Delete(ctx context.Context, path string) error {
+	resp, err := s.do(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent, http.StatusNotFound:
+		resp.Body.Close()
+		return nil
+	default:
+		return statusError(resp, "delete", path)
+	}
+}
+
+// GetURL returns the registry's file-serving URL for the artifact. The ttl is
+// ignored: the registry proxies the download, so there is nothing to sign.
+func (s *ArtifactoryStorage) GetURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	exists, err := s.Exists(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("file not found: %s", path)
+	}
+	return fmt.Sprintf("%s/v1/files/%s", s.serverBaseURL, strings.TrimLeft(path, "/")), nil
+}
+
+// Exists checks if a file exists in Artifactory
+func (s *ArtifactoryStorage) Exists(ctx context.Context, path string) (bool, error) {
+	resp, err := s.do(ctx, http.MethodHead, path, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		resp.Body.Close()
+		return true, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return false, nil
+	default:
+		return false, statusError(resp, "exists", path)
+	}
+}
+
+// GetMetadata retrieves file metadata with a HEAD request. Artifactory reports
+// the SHA256 in X-Checksum-Sha256; servers that do not (generic WebDAV) have
+// the checksum computed by downloading the file.
+func (s *ArtifactoryStorage) GetMetadata(ctx context.Context, path string) (*appstorage.FileMetadata, error) {
+	resp, err := s.do(ctx, http.MethodHead, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		resp.Body.Close()
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("file not found: %s", path)
+	default:
+		return nil, statusError(resp, "metadata", path)
+	}
+
+	meta := &appstorage.FileMetadata{
+		Path:     path,
+		Size:     resp.ContentLength,
+		Checksum: strings.ToLower(resp.Header.Get("X-Checksum-Sha256")),
+	}
+	if lm := resp.Header.Get("Last-Modified"); lm != "" {
+		if t, err := http.ParseTime(lm); err == nil {
+			meta.LastModified = t
+		}
+	}
+
+	if meta.Checksum == "" {
+		body, err := s.Download(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		hasher := sha256.New()
+		n, err := io.Copy(hasher, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate checksum: %w", err)
+		}
+		meta.Checksum = hex.EncodeToString(hasher.Sum(nil))
+		if meta.Size < 0 {
+			meta.Size = n
+		}
+	}
+	return meta, nil
+}
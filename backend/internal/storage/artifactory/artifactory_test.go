@@ -0,0 +1,351 @@
+package artifactory
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	appconfig "github.com/terraform-registry/terraform-registry/internal/config"
+	appstorage "github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// Mock Artifactory server
+// ---------------------------------------------------------------------------
+
+// mockArtifactory is an in-memory stand-in for an Artifactory repository. It
+// enforces the bearer token, verifies X-Checksum-Sha256 on deploy the way
+// Artifactory does, reports checksums on HEAD, and can answer 429 for the
+// first N requests.
+type mockArtifactory struct {
+	mu          sync.Mutex
+	token       string
+	files       map[string][]byte
+	throttle    int // remaining requests to reject with 429
+	requests    int
+	omitSHA256  bool // behave like a generic WebDAV server
+	lastHeaders http.Header
+}
+
+func newMockArtifactory(t *testing.T, token string) (*mockArtifactory, *httptest.Server) {
+	t.Helper()
+	m := &mockArtifactory{token: token, files: map[string][]byte{}}
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+	return m, srv
+}
+
+func (m *mockArtifactory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests++
+	m.lastHeaders = r.Header.Clone()
+
+	if m.throttle > 0 {
+		m.throttle--
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	if m.token != "" && r.Header.Get("Authorization") != "Bearer "+m.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"errors":[{"status":401,"message":"Bad credentials"}]}`))
+		return
+	}
+
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if want := r.Header.Get("X-Checksum-Sha256"); want != "" && want != hex.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte("checksum mismatch"))
+			return
+		}
+		m.files[key] = body
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet, http.MethodHead:
+		body, ok := m.files[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		sum := sha256.Sum256(body)
+		if !m.omitSHA256 {
+			w.Header().Set("X-Checksum-Sha256", hex.EncodeToString(sum[:]))
+		}
+		w.Header().Set("Last-Modified", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(body)
+		}
+	case http.MethodDelete:
+		if _, ok := m.files[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(m.files, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestStorage(t *testing.T, srvURL, token string) *ArtifactoryStorage {
+	t.Helper()
+	s, err := New(&appconfig.ArtifactoryStorageConfig{
+		BaseURL:     srvURL + "/artifactory/",
+		Repository:  "terraform-local",
+		AccessToken: token,
+	}, "https://registry.example.com")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	s.retryWait = time.Millisecond
+	return s
+}
+
+// ---------------------------------------------------------------------------
+// New
+// ---------------------------------------------------------------------------
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		wantErr bool
+	}{
+		{"missing base url", "", true},
+		{"relative url", "artifactory.example.com/artifactory", true},
+		{"unsupported scheme", "ftp://artifactory.example.com", true},
+		{"https", "https://example.jfrog.io/artifactory", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&appconfig.ArtifactoryStorageConfig{BaseURL: tt.baseURL, Repository: "repo"}, "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew_RootURLIncludesRepository(t *testing.T) {
+	s, err := New(&appconfig.ArtifactoryStorageConfig{BaseURL: "https://example.jfrog.io/artifactory/", Repository: "/tf-local/"}, "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if s.rootURL != "https://example.jfrog.io/artifactory/tf-local" {
+		t.Errorf("rootURL = %q", s.rootURL)
+	}
+}
+
+func TestRegisteredWithFactory(t *testing.T) {
+	cfg := &appconfig.Config{}
+	cfg.Storage.DefaultBackend = "artifactory"
+	cfg.Storage.Artifactory.BaseURL = "https://example.jfrog.io/artifactory"
+	backend, err := appstorage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	if _, ok := backend.(*ArtifactoryStorage); !ok {
+		t.Errorf("NewStorage() returned %T, want *ArtifactoryStorage", backend)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Round trip against the mock server
+// ---------------------------------------------------------------------------
+
+func TestArtifactory_RoundTrip(t *testing.T) {
+	mock, srv := newMockArtifactory(t, "secret-token")
+	s := newTestStorage(t, srv.URL, "secret-token")
+	ctx := context.Background()
+	content := []byte("module archive contents")
+	path := "modules/hashicorp/vpc/aws/1.0.0.tar.gz"
+
+	result, err := s.Upload(ctx, path, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	sum := sha256.Sum256(content)
+	if result.Checksum != hex.EncodeToString(sum[:]) || result.Size != int64(len(content)) || result.Path != path {
+		t.Errorf("Upload() = %+v", result)
+	}
+	if mock.lastHeaders.Get("X-Checksum-Sha1") == "" || mock.lastHeaders.Get("X-Checksum") == "" {
+		t.Error("expected SHA1 and MD5 checksum headers on deploy")
+	}
+	if _, ok := mock.files["/artifactory/terraform-local/"+path]; !ok {
+		t.Errorf("artifact not stored under the repository path; have %v", mock.files)
+	}
+
+	exists, err := s.Exists(ctx, path)
+	if err != nil || !exists {
+		t.Errorf("Exists() = %v, %v; want true", exists, err)
+	}
+
+	meta, err := s.GetMetadata(ctx, path)
+	if err != nil {
+		t.Fatalf("GetMetadata() error = %v", err)
+	}
+	if meta.Checksum != result.Checksum || meta.Size != int64(len(content)) || meta.LastModified.IsZero() {
+		t.Errorf("GetMetadata() = %+v", meta)
+	}
+
+	rc, err := s.Download(ctx, path)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, content) {
+		t.Errorf("Download() = %q, want %q", got, content)
+	}
+
+	url, err := s.GetURL(ctx, path, time.Minute)
+	if err != nil || url != "https://registry.example.com/v1/files/"+path {
+		t.Errorf("GetURL() = %q, %v", url, err)
+	}
+
+	if err := s.Delete(ctx, path); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if exists, _ := s.Exists(ctx, path); exists {
+		t.Error("Exists() = true after Delete")
+	}
+	// Deleting a missing artifact is not an error.
+	if err := s.Delete(ctx, path); err != nil {
+		t.Errorf("Delete() of missing file error = %v", err)
+	}
+}
+
+func TestArtifactory_MissingFile(t *testing.T) {
+	_, srv := newMockArtifactory(t, "")
+	s := newTestStorage(t, srv.URL, "")
+	ctx := context.Background()
+
+	if exists, err := s.Exists(ctx, "missing.zip"); err != nil || exists {
+		t.Errorf("Exists() = %v, %v; want false, nil", exists, err)
+	}
+	if _, err := s.Download(ctx, "missing.zip"); err == nil || !strings.Contains(err.Error(), "file not found") {
+		t.Errorf("Download() error = %v, want file not found", err)
+	}
+	if _, err := s.GetMetadata(ctx, "missing.zip"); err == nil {
+		t.Error("GetMetadata() error = nil, want error")
+	}
+	if _, err := s.GetURL(ctx, "missing.zip", time.Minute); err == nil {
+		t.Error("GetURL() error = nil, want error")
+	}
+}
+
+func TestArtifactory_BadTokenSurfacesError(t *testing.T) {
+	_, srv := newMockArtifactory(t, "right-token")
+	s := newTestStorage(t, srv.URL, "wrong-token")
+
+	if _, err := s.Exists(context.Background(), ".connectivity-test"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Exists() error = %v, want 401", err)
+	}
+	// GET responses carry a body, which is quoted in the error.
+	if _, err := s.Download(context.Background(), "file.zip"); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("Download() error = %v, want server message", err)
+	}
+}
+
+func TestArtifactory_ChecksumComputedWithoutHeader(t *testing.T) {
+	mock, srv := newMockArtifactory(t, "")
+	mock.omitSHA256 = true
+	s := newTestStorage(t, srv.URL, "")
+	ctx := context.Background()
+	content := []byte("webdav content")
+
+	if _, err := s.Upload(ctx, "a/b.zip", bytes.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	meta, err := s.GetMetadata(ctx, "a/b.zip")
+	if err != nil {
+		t.Fatalf("GetMetadata() error = %v", err)
+	}
+	sum := sha256.Sum256(content)
+	if meta.Checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("Checksum = %q, want computed SHA256", meta.Checksum)
+	}
+}
+
+func TestArtifactory_RejectsTraversal(t *testing.T) {
+	mock, srv := newMockArtifactory(t, "")
+	s := newTestStorage(t, srv.URL, "")
+	ctx := context.Background()
+
+	for _, p := range []string{"../other-repo/file", "a/../../b", "a//b", ""} {
+		if _, err := s.Exists(ctx, p); err == nil {
+			t.Errorf("Exists(%q) error = nil, want invalid path", p)
+		}
+	}
+	if mock.requests != 0 {
+		t.Errorf("server received %d requests for rejected paths", mock.requests)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// 429 retry
+// ---------------------------------------------------------------------------
+
+func TestArtifactory_RetriesThrottledUpload(t *testing.T) {
+	mock, srv := newMockArtifactory(t, "")
+	mock.throttle = 2
+	s := newTestStorage(t, srv.URL, "")
+	content := []byte("retry me")
+
+	if _, err := s.Upload(context.Background(), "retry.zip", bytes.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if mock.requests != 3 {
+		t.Errorf("requests = %d, want 3 (two throttled, one accepted)", mock.requests)
+	}
+	// The replayed body must be complete, or the checksum check would fail.
+	if got := mock.files["/artifactory/terraform-local/retry.zip"]; !bytes.Equal(got, content) {
+		t.Errorf("stored %q, want %q", got, content)
+	}
+}
+
+func TestArtifactory_RetriesExhausted(t *testing.T) {
+	mock, srv := newMockArtifactory(t, "")
+	mock.throttle = 10
+	s := newTestStorage(t, srv.URL, "")
+
+	_, err := s.Exists(context.Background(), "busy.zip")
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("Exists() error = %v, want 429", err)
+	}
+	if mock.requests != defaultMaxRetries+1 {
+		t.Errorf("requests = %d, want %d", mock.requests, defaultMaxRetries+1)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	s := &ArtifactoryStorage{retryWait: 100 * time.Millisecond}
+	resp := &http.Response{Header: http.Header{}}
+
+	if got := s.backoff(resp, 2); got != 400*time.Millisecond {
+		t.Errorf("exponential backoff = %v, want 400ms", got)
+	}
+	resp.Header.Set("Retry-After", "2")
+	if got := s.backoff(resp, 0); got != 2*time.Second {
+		t.Errorf("Retry-After seconds = %v, want 2s", got)
+	}
+	resp.Header.Set("Retry-After", "3600")
+	if got := s.backoff(resp, 0); got != maxRetryWait {
+		t.Errorf("Retry-After cap = %v, want %v", got, maxRetryWait)
+	}
+}
@@ -1,5 +1,5 @@
 // factory.go implements the storage backend registry and factory, mapping backend type
-// strings (local, s3, azure, gcs) to constructor functions and dispatching NewStorage calls.
+// strings (local, s3, azure, gcs, artifactory) to constructor functions and dispatching NewStorage calls.
 package storage
 
 import (
@@ -22,7 +22,7 @@ func Register(name string, factory FactoryFunc) {
 func NewStorage(cfg *config.Config) (Storage, error) {
 	factory, ok := factories[cfg.Storage.DefaultBackend]
 	if !ok {
-		return nil, fmt.Errorf("unsupported storage backend: %s (must be 'local', 'azure', 's3', or 'gcs')", cfg.Storage.DefaultBackend)
+		return nil, fmt.Errorf("unsupported storage backend: %s (must be 'local', 'azure', 's3', 'gcs', or 'artifactory')", cfg.Storage.DefaultBackend)
 	}
 
 	return factory(cfg)
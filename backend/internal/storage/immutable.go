@@ -0,0 +1,71 @@
+// immutable.go implements the storage decorator that enforces artifact
+// immutability below the handlers: whatever code path asks to delete or
+// replace a published archive, the request is checked against the guard
+// before it reaches the backend.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrArtifactImmutable is returned (wrapped) when a guard refuses to delete
+// or replace a stored artifact.
+var ErrArtifactImmutable = errors.New("artifact is immutable")
+
+// ImmutabilityGuard decides whether a stored object may be deleted or
+// replaced. Both methods return an error wrapping ErrArtifactImmutable to
+// refuse, or another error if the decision could not be made. CheckOverwrite
+// is also asked about paths that do not exist yet; its answer only counts
+// when the object does.
+type ImmutabilityGuard interface {
+	CheckDelete(ctx context.Context, path string) error
+	CheckOverwrite(ctx context.Context, path string) error
+}
+
+// immutableStorage wraps a Storage and consults a guard before deletes and
+// before uploads that would replace an existing object.
+type immutableStorage struct {
+	Storage
+	guard ImmutabilityGuard
+}
+
+// NewImmutableStorage wraps backend so that deletes and overwrites are
+// checked against guard. Reads and uploads to new paths pass straight
+// through. A nil guard returns backend unchanged.
+func NewImmutableStorage(backend Storage, guard ImmutabilityGuard) Storage {
+	if guard == nil {
+		return backend
+	}
+	return &immutableStorage{Storage: backend, guard: guard}
+}
+
+// Upload refuses to replace an existing object the guard protects. The guard
+// is asked first so that the backend's Exists (a HEAD request on object
+// stores) is only called for the paths it protects, not on every upload.
+func (s *immutableStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*UploadResult, error) {
+	if guardErr := s.guard.CheckOverwrite(ctx, path); guardErr != nil {
+		exists, err := s.Storage.Exists(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, guardErr
+		}
+	}
+	return s.Storage.Upload(ctx, path, reader, size)
+}
+
+// StreamsUploads reports whether the wrapped backend streams uploads.
+func (s *immutableStorage) StreamsUploads() bool {
+	return SupportsStreamingUpload(s.Storage)
+}
+
+// Delete refuses to remove an object the guard protects.
+func (s *immutableStorage) Delete(ctx context.Context, path string) error {
+	if err := s.guard.CheckDelete(ctx, path); err != nil {
+		return err
+	}
+	return s.Storage.Delete(ctx, path)
+}
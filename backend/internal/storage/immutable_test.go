@@ -0,0 +1,119 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// recordingStorage is a mockStorage that remembers which paths exist and
+// which were written or deleted.
+type recordingStorage struct {
+	mockStorage
+	existing map[string]bool
+	checked  []string
+	uploaded []string
+	deleted  []string
+}
+
+func (s *recordingStorage) Upload(_ context.Context, path string, _ io.Reader, size int64) (*storage.UploadResult, error) {
+	s.uploaded = append(s.uploaded, path)
+	return &storage.UploadResult{Path: path, Size: size}, nil
+}
+func (s *recordingStorage) Delete(_ context.Context, path string) error {
+	s.deleted = append(s.deleted, path)
+	return nil
+}
+func (s *recordingStorage) Exists(_ context.Context, path string) (bool, error) {
+	s.checked = append(s.checked, path)
+	return s.existing[path], nil
+}
+
+// protectGuard refuses deletes and overwrites of the paths it protects.
+type protectGuard map[string]bool
+
+func (g protectGuard) CheckDelete(_ context.Context, path string) error {
+	if g[path] {
+		return fmt.Errorf("%w: retained", storage.ErrArtifactImmutable)
+	}
+	return nil
+}
+func (g protectGuard) CheckOverwrite(_ context.Context, path string) error {
+	if g[path] {
+		return fmt.Errorf("%w: cannot be replaced", storage.ErrArtifactImmutable)
+	}
+	return nil
+}
+
+func TestNewImmutableStorage_NilGuardReturnsBackend(t *testing.T) {
+	backend := &recordingStorage{}
+	if storage.NewImmutableStorage(backend, nil) != storage.Storage(backend) {
+		t.Error("a nil guard should return the backend unchanged")
+	}
+}
+
+func TestImmutableStorage_Delete(t *testing.T) {
+	backend := &recordingStorage{}
+	s := storage.NewImmutableStorage(backend, protectGuard{"protected": true})
+
+	if err := s.Delete(context.Background(), "protected"); !errors.Is(err, storage.ErrArtifactImmutable) {
+		t.Errorf("Delete(protected) error = %v, want ErrArtifactImmutable", err)
+	}
+	if err := s.Delete(context.Background(), "other"); err != nil {
+		t.Errorf("Delete(other) error = %v", err)
+	}
+	if len(backend.deleted) != 1 || backend.deleted[0] != "other" {
+		t.Errorf("backend deletes = %v, want [other]", backend.deleted)
+	}
+}
+
+func TestImmutableStorage_Upload(t *testing.T) {
+	backend := &recordingStorage{existing: map[string]bool{"protected": true, "replaceable": true}}
+	s := storage.NewImmutableStorage(backend, protectGuard{"protected": true, "new": true})
+	ctx := context.Background()
+
+	if _, err := s.Upload(ctx, "protected", strings.NewReader("x"), 1); !errors.Is(err, storage.ErrArtifactImmutable) {
+		t.Errorf("Upload over a protected object: error = %v, want ErrArtifactImmutable", err)
+	}
+	// A refusal only counts for objects that already exist.
+	if _, err := s.Upload(ctx, "new", strings.NewReader("x"), 1); err != nil {
+		t.Errorf("Upload(new) error = %v", err)
+	}
+	if _, err := s.Upload(ctx, "replaceable", strings.NewReader("x"), 1); err != nil {
+		t.Errorf("Upload(replaceable) error = %v", err)
+	}
+	if want := []string{"new", "replaceable"}; strings.Join(backend.uploaded, ",") != strings.Join(want, ",") {
+		t.Errorf("backend uploads = %v, want %v", backend.uploaded, want)
+	}
+	// The backend is only asked whether paths the guard protects exist.
+	if want := []string{"protected", "new"}; strings.Join(backend.checked, ",") != strings.Join(want, ",") {
+		t.Errorf("backend Exists calls = %v, want %v", backend.checked, want)
+	}
+}
+
+// streamingStorage is a mockStorage that streams uploads.
+type streamingStorage struct{ mockStorage }
+
+func (*streamingStorage) StreamsUploads() bool { return true }
+
+func TestSupportsStreamingUpload(t *testing.T) {
+	if storage.SupportsStreamingUpload(&mockStorage{}) {
+		t.Error("a backend without StreamsUploads should not stream")
+	}
+	if !storage.SupportsStreamingUpload(&streamingStorage{}) {
+		t.Error("a backend reporting StreamsUploads should stream")
+	}
+	// The immutability wrapper reports what it wraps.
+	guard := protectGuard{}
+	if storage.SupportsStreamingUpload(storage.NewImmutableStorage(&mockStorage{}, guard)) {
+		t.Error("wrapped non-streaming backend should not stream")
+	}
+	if !storage.SupportsStreamingUpload(storage.NewImmutableStorage(&streamingStorage{}, guard)) {
+		t.Error("wrapped streaming backend should stream")
+	}
+}
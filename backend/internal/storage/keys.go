@@ -0,0 +1,68 @@
+// keys.go builds the canonical storage keys for registry artifacts. Every
+// upload path and mirror sync names its objects through these helpers, so an
+// object's key depends only on what it is, never on which code path stored it.
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+)
+
+// CanonicalKey joins segments into a storage key that is safe on
+// case-insensitive filesystems. The key is lowercased, and its final segment
+// gets a short hash of the original spelling before its extension, so
+// artifacts whose identities differ only in case (providers/Acme/... and
+// providers/acme/...) never share a key, whether or not the backend folds
+// case. The same segments always produce the same key.
+//
+// Segments are not validated here; callers pass registry identifiers and
+// filenames that have already been checked.
+func CanonicalKey(segments ...string) string {
+	original := strings.Join(segments, "/")
+	sum := sha256.Sum256([]byte(original))
+	suffix := "-" + hex.EncodeToString(sum[:4])
+
+	dir, file := path.Split(strings.ToLower(original))
+	ext := path.Ext(file)
+	if strings.HasSuffix(file, ".tar.gz") {
+		ext = ".tar.gz"
+	}
+	return dir + strings.TrimSuffix(file, ext) + suffix + ext
+}
+
+// ModuleArchiveKey returns the key of a module version's archive.
+func ModuleArchiveKey(namespace, name, system, version string) string {
+	return CanonicalKey("modules", namespace, name, system, version+".tar.gz")
+}
+
+// ProviderArchiveKey returns the key of a provider version's archive for one
+// platform. Uploaded archives use this layout; mirrored archives are stored
+// once per content under BlobKey instead. The archive's original filename is
+// kept on the platform record, not in the key.
+func ProviderArchiveKey(namespace, providerType, version, os, arch string) string {
+	return ProviderFileKey(namespace, providerType, version, os+"_"+arch+".zip")
+}
+
+// ProviderFileKey returns the key of a file that belongs to a provider
+// version, such as SHA256SUMS or its signature. file is the
+// path of the file within the version, as one or more segments.
+func ProviderFileKey(namespace, providerType, version string, file ...string) string {
+	return CanonicalKey(append([]string{"providers", namespace, providerType, version}, file...)...)
+}
+
+// blobKeyPrefix is the prefix of content-addressed keys.
+const blobKeyPrefix = "blobs/sha256/"
+
+// BlobKey returns the content-addressed key of an object whose SHA-256 is
+// digest (lowercase hex). Identical content always maps to the same key, so
+// it is stored once however many artifacts reference it.
+func BlobKey(digest string) string {
+	return blobKeyPrefix + strings.ToLower(digest)
+}
+
+// IsBlobKey reports whether key is a content-addressed BlobKey.
+func IsBlobKey(key string) bool {
+	return strings.HasPrefix(key, blobKeyPrefix)
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalKey(t *testing.T) {
+	key := ModuleArchiveKey("hashicorp", "vpc", "aws", "1.0.0")
+	if !strings.HasPrefix(key, "modules/hashicorp/vpc/aws/1.0.0-") || !strings.HasSuffix(key, ".tar.gz") {
+		t.Errorf("ModuleArchiveKey = %q, want modules/hashicorp/vpc/aws/1.0.0-<hash>.tar.gz", key)
+	}
+	if again := ModuleArchiveKey("hashicorp", "vpc", "aws", "1.0.0"); again != key {
+		t.Errorf("ModuleArchiveKey is not deterministic: %q then %q", key, again)
+	}
+
+	upper := ProviderFileKey("Acme", "widget", "1.0.0", "linux_amd64.zip")
+	lower := ProviderFileKey("acme", "widget", "1.0.0", "linux_amd64.zip")
+	if upper == lower {
+		t.Errorf("namespaces differing in case share the key %q", upper)
+	}
+	if upper != strings.ToLower(upper) {
+		t.Errorf("key %q is not lowercase", upper)
+	}
+	if strings.EqualFold(upper, lower) {
+		t.Errorf("keys %q and %q collide on a case-insensitive filesystem", upper, lower)
+	}
+
+	if got := ProviderFileKey("acme", "widget", "1.0.0", "SHA256SUMS"); !strings.HasPrefix(got, "providers/acme/widget/1.0.0/sha256sums-") {
+		t.Errorf("ProviderFileKey(SHA256SUMS) = %q", got)
+	}
+	if got := ProviderFileKey("acme", "widget", "1.0.0", "SHA256SUMS.sig"); !strings.HasSuffix(got, ".sig") {
+		t.Errorf("ProviderFileKey(SHA256SUMS.sig) = %q, want the .sig extension kept", got)
+	}
+}
+
+func TestBlobKey(t *testing.T) {
+	digest := "ABCDEF0123456789abcdef0123456789abcdef0123456789abcdef0123456789"
+	key := BlobKey(digest)
+	if key != "blobs/sha256/"+strings.ToLower(digest) {
+		t.Errorf("BlobKey = %q", key)
+	}
+	if !IsBlobKey(key) {
+		t.Errorf("IsBlobKey(%q) = false", key)
+	}
+	if IsBlobKey(ProviderArchiveKey("acme", "widget", "1.0.0", "linux", "amd64")) {
+		t.Error("IsBlobKey is true for a provider archive key")
+	}
+}
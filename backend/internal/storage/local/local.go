@@ -109,6 +109,9 @@ func (s *LocalStorage) Upload(ctx context.Context, path string, reader io.Reader
 	}, nil
 }
 
+// StreamsUploads reports that Upload writes the reader straight to disk.
+func (s *LocalStorage) StreamsUploads() bool { return true }
+
 // Download retrieves a file from the local filesystem
 func (s *LocalStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
 	fullPath, err := s.safeJoin(path)
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
 // newTestStorage creates a LocalStorage backed by a temporary directory.
@@ -81,6 +82,22 @@ func TestUpload(t *testing.T) {
 	}
 }
 
+func TestUpload_UnknownSizeStreams(t *testing.T) {
+	s := newTestStorage(t, false, "")
+	if !storage.SupportsStreamingUpload(s) {
+		t.Fatal("local storage should stream uploads")
+	}
+
+	content := "streamed without a length"
+	result, err := s.Upload(context.Background(), "stream/file.bin", strings.NewReader(content), -1)
+	if err != nil {
+		t.Fatalf("Upload() error: %v", err)
+	}
+	if result.Size != int64(len(content)) {
+		t.Errorf("Size = %d, want %d", result.Size, len(content))
+	}
+}
+
 func TestUpload_CreatesSubdirectories(t *testing.T) {
 	s := newTestStorage(t, false, "")
 	ctx := context.Background()
@@ -45,6 +45,20 @@ type Storage interface {
 	GetMetadata(ctx context.Context, path string) (*FileMetadata, error)
 }
 
+// StreamingUploader is implemented by backends whose Upload consumes the
+// reader in one forward pass, without buffering it whole in memory or on
+// disk, and accepts a size of -1 for content of unknown length. Uploads to
+// such backends can be streamed straight from the request body.
+type StreamingUploader interface {
+	StreamsUploads() bool
+}
+
+// SupportsStreamingUpload reports whether s streams uploads.
+func SupportsStreamingUpload(s Storage) bool {
+	su, ok := s.(StreamingUploader)
+	return ok && su.StreamsUploads()
+}
+
 // UploadResult contains information about an uploaded file
 type UploadResult struct {
 	// Path is the storage path where the file was stored
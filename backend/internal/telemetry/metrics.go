@@ -14,10 +14,13 @@
 //
 // # Metric Groups
 //
-//   - HTTP request counters and latency histograms (labelled by route template, not raw URL)
+//   - HTTP request counters, latency histograms and in-flight gauges (labelled by route template, not raw URL)
 //   - Module and provider binary download counters
 //   - Provider mirror sync duration and error counters
 //   - API key expiry notification counters
+//   - Rate limiter rejection counters and active-bucket gauges
+//   - Authentication failure counters by reason and credential type
+//   - SCM connector request counters, latency histograms and rate-limit gauges
 //   - Database connection pool gauge (polled every 30 s)
 //
 // # Label Cardinality
@@ -25,6 +28,7 @@
 // HTTP metrics use c.FullPath() (route template such as /v1/modules/:namespace/:name)
 // rather than the raw request URL to prevent unbounded label cardinality from
 // user-supplied path segments such as module names or version strings.
+// Organization IDs are hashed into HTTPOrgBuckets buckets for the same reason.
 //
 // # Usage
 //
@@ -47,42 +51,71 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
-// HTTP metrics — labelled by method, route template, and status code.
+// HTTP metrics — labelled by method, route template, API group, and status code.
 //
-// HTTPRequestsTotal is a CounterVec with labels {method, path, status}.
+// The group label is the API surface the route belongs to: "protocol"
+// (/v1, /v2, /.well-known), "mirror" (/terraform), "admin" (/api, /scim), or
+// "other" (health, swagger, inbound webhooks, unmatched requests).
+//
+// HTTPRequestsTotal is a CounterVec with labels {method, path, group, org_bucket, status}.
 // The path label holds the Gin route template (e.g. /v1/modules/:namespace/:name/:system/:version/download),
-// NOT the raw URL, to prevent unbounded cardinality.
+// NOT the raw URL, to prevent unbounded cardinality. org_bucket identifies the
+// organization the request was authenticated for, hashed into one of
+// HTTPOrgBuckets buckets ("0".."15"), or "none" when the request carries no
+// organization. Bucketing caps the series per route no matter how many
+// organizations exist.
 //
 // Example PromQL queries:
 //   - Request rate (req/s, 5 m window):  rate(http_requests_total[5m])
 //   - Error rate (%):                    sum(rate(http_requests_total{status=~"5.."}[5m])) / sum(rate(http_requests_total[5m])) * 100
 //   - Requests by route:                 sum by (path) (rate(http_requests_total[5m]))
+//   - Busiest tenant buckets:            topk(3, sum by (org_bucket) (rate(http_requests_total{group="admin"}[5m])))
 //
-// HTTPRequestDuration is a HistogramVec with labels {method, path} and exponential-ish
+// HTTPRequestDuration is a HistogramVec with labels {method, path, group} and exponential-ish
 // buckets from 5 ms to 30 s.  Use histogram_quantile to compute latency percentiles.
+// It carries no org_bucket label: the bucket series would multiply it
+// sixteen-fold. Observations carry a trace_id exemplar (see middleware.MetricsMiddleware).
 //
 // Example PromQL queries:
 //   - p99 latency per route:             histogram_quantile(0.99, sum by (path, le) (rate(http_request_duration_seconds_bucket[5m])))
 //   - Average latency:                   rate(http_request_duration_seconds_sum[5m]) / rate(http_request_duration_seconds_count[5m])
+//
+// HTTPRequestsInFlight is a GaugeVec with labels {method, path, group} holding
+// the number of requests currently being served per route.
+//
+// Example PromQL queries:
+//   - Routes with the most concurrent requests:  topk(5, http_requests_in_flight)
 var (
 	HTTPRequestsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "http_requests_total",
-			Help: "Total number of HTTP requests processed, by method, route template, and status code.",
+			Help: "Total number of HTTP requests processed, by method, route template, API group, organization bucket, and status code.",
 		},
-		[]string{"method", "path", "status"},
+		[]string{"method", "path", "group", "org_bucket", "status"},
 	)
 
 	HTTPRequestDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "http_request_duration_seconds",
-			Help:    "Histogram of HTTP request latencies, by method and route template.",
+			Help:    "Histogram of HTTP request latencies, by method, route template, and API group.",
 			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
 		},
-		[]string{"method", "path"},
+		[]string{"method", "path", "group"},
+	)
+
+	HTTPRequestsInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests currently being served, by method, route template, and API group.",
+		},
+		[]string{"method", "path", "group"},
 	)
 )
 
+// HTTPOrgBuckets is the number of org_bucket values organizations are hashed
+// into on http_requests_total.
+const HTTPOrgBuckets = 16
+
 // Terraform protocol download metrics — used by module, provider, and binary mirror download handlers.
 //
 // ModuleDownloadsTotal is a CounterVec with labels {namespace, system} incremented
@@ -233,6 +266,56 @@ var RateLimitRejectionsTotal = promauto.NewCounterVec(
 	[]string{"tier", "key_type"},
 )
 
+// RateLimiterRejectionsTotal is a CounterVec with label {limiter} incremented
+// each time a rate limiter rejects a request (HTTP 429). limiter is the
+// limiter's configured name: "general", "auth", "upload", "organization" or
+// "principal_override". Unlike RateLimitRejectionsTotal, which splits
+// rejections by who was limited, this tells which limiter is saturating.
+//
+// Example PromQL queries:
+//   - Rejections per limiter:  sum by (limiter) (rate(tfr_ratelimit_rejections_total[5m]))
+var RateLimiterRejectionsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "tfr_ratelimit_rejections_total",
+		Help: "Total number of requests rejected by rate limiting, by limiter.",
+	},
+	[]string{"limiter"},
+)
+
+// RateLimiterActiveBuckets is a GaugeVec with label {limiter} holding the
+// number of client buckets the in-memory rate limiters are tracking. Buckets
+// idle for ten minutes are dropped by the limiter's cleanup pass. The Redis
+// backend keeps its buckets in Redis and does not report this gauge.
+//
+// Example PromQL queries:
+//   - Distinct clients per limiter:  tfr_ratelimit_active_buckets
+var RateLimiterActiveBuckets = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "tfr_ratelimit_active_buckets",
+		Help: "Number of client buckets tracked by the in-memory rate limiters, by limiter.",
+	},
+	[]string{"limiter"},
+)
+
+// AuthFailuresTotal is a CounterVec with labels {reason, credential_type}
+// incremented each time the auth or RBAC middleware rejects a request that
+// presented a credential. reason is "expired", "not_yet_valid", "malformed",
+// "revoked", "unknown_key" or "wrong_scope"; credential_type is "jwt", "api_key",
+// "cli_token", "ci_token", "mtls" or "unknown". Both labels come from fixed
+// sets; no token or key material is ever used as a label value. Requests that
+// present no credential at all are not counted.
+//
+// Example PromQL queries:
+//   - Failures by reason:  sum by (reason) (rate(tfr_auth_failures_total[5m]))
+//   - Alert on API key guessing:  rate(tfr_auth_failures_total{reason="unknown_key"}[5m]) > 1
+var AuthFailuresTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "tfr_auth_failures_total",
+		Help: "Total number of authentication and authorization failures, by reason and credential type.",
+	},
+	[]string{"reason", "credential_type"},
+)
+
 // AppInfo is a GaugeVec that exposes build information as Prometheus labels.
 // Set once at startup with value 1 so the info is available via the /metrics endpoint.
 //
@@ -255,6 +338,28 @@ var ModuleScanQueueDepth = promauto.NewGauge(
 	},
 )
 
+// ScratchUsageBytes is the size of the files in the scratch directory
+// (scratch.dir), sampled by the scratch cleanup job on each run.
+//
+// Example PromQL queries:
+//   - Share of the budget on disk:  terraform_registry_scratch_usage_bytes / (<TFR_SCRATCH_MAX_SIZE_MB> * 1048576)
+var ScratchUsageBytes = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "terraform_registry_scratch_usage_bytes",
+		Help: "Bytes on disk in the scratch directory",
+	},
+)
+
+// ScratchReservedBytes is the part of the scratch budget (scratch.max_size_mb)
+// reserved by uploads and SCM publishes in flight. New work is refused while
+// it would go over the budget.
+var ScratchReservedBytes = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "terraform_registry_scratch_reserved_bytes",
+		Help: "Bytes of the scratch budget reserved by work in flight",
+	},
+)
+
 // ModuleScanDuration is a HistogramVec recording the time taken for each security scan,
 // labelled by scanner tool and result status.
 //
@@ -379,3 +484,46 @@ var ReleasesKeyExpiresSeconds = promauto.NewGaugeVec(
 	},
 	[]string{"tool", "source"},
 )
+
+// SCMRequestsTotal is a CounterVec of SCM connector HTTP attempts (retries
+// count separately), labelled by {provider, status}. provider is the SCM
+// provider type (github, gitlab, azuredevops, bitbucket_dc); status is the
+// HTTP status code, or "error" when no response was received.
+//
+// Example PromQL:
+//   - Rate-limited responses per provider:
+//     sum by (provider) (rate(terraform_registry_scm_requests_total{status=~"429|403"}[5m]))
+var SCMRequestsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "terraform_registry_scm_requests_total",
+		Help: "Total SCM connector HTTP attempts by provider type and status.",
+	},
+	[]string{"provider", "status"},
+)
+
+// SCMRequestDuration is a HistogramVec of SCM connector HTTP attempt latency
+// in seconds, labelled by provider type. It measures the time to response
+// headers, not the body read.
+var SCMRequestDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "terraform_registry_scm_request_duration_seconds",
+		Help:    "SCM connector HTTP attempt latency in seconds by provider type.",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{"provider"},
+)
+
+// SCMRateLimitRemaining is a GaugeVec holding the last rate-limit remaining
+// count an SCM returned (X-RateLimit-Remaining or RateLimit-Remaining),
+// labelled by provider type.
+//
+// Example PromQL:
+//   - Alert before GitHub starts refusing requests:
+//     terraform_registry_scm_rate_limit_remaining{provider="github"} < 100
+var SCMRateLimitRemaining = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "terraform_registry_scm_rate_limit_remaining",
+		Help: "Last SCM rate-limit remaining count by provider type.",
+	},
+	[]string{"provider"},
+)
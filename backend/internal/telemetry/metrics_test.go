@@ -16,6 +16,7 @@ func TestMetricRegistration(t *testing.T) {
 	}{
 		{"HTTPRequestsTotal", HTTPRequestsTotal},
 		{"HTTPRequestDuration", HTTPRequestDuration},
+		{"HTTPRequestsInFlight", HTTPRequestsInFlight},
 		{"ModuleDownloadsTotal", ModuleDownloadsTotal},
 		{"ProviderDownloadsTotal", ProviderDownloadsTotal},
 		{"TerraformBinaryDownloadsTotal", TerraformBinaryDownloadsTotal},
@@ -44,11 +45,17 @@ func TestMetricRegistration(t *testing.T) {
 
 func TestHTTPRequestsTotalLabels(t *testing.T) {
 	// Verify we can record with the expected label set without panicking.
-	HTTPRequestsTotal.WithLabelValues("GET", "/v1/modules", "200").Inc()
+	HTTPRequestsTotal.WithLabelValues("GET", "/v1/modules", "protocol", "none", "200").Inc()
 }
 
 func TestHTTPRequestDurationLabels(t *testing.T) {
-	HTTPRequestDuration.WithLabelValues("GET", "/v1/modules").Observe(0.042)
+	HTTPRequestDuration.WithLabelValues("GET", "/v1/modules", "protocol").Observe(0.042)
+}
+
+func TestHTTPRequestsInFlightLabels(t *testing.T) {
+	g := HTTPRequestsInFlight.WithLabelValues("GET", "/v1/modules", "protocol")
+	g.Inc()
+	g.Dec()
 }
 
 func TestModuleDownloadsTotalLabels(t *testing.T) {
@@ -7,6 +7,7 @@ package validation
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
@@ -27,6 +28,23 @@ const (
 
 // ValidateArchive validates a tar.gz archive
 func ValidateArchive(reader io.Reader, maxSize int64) error {
+	return inspectArchive(reader, maxSize, nil)
+}
+
+// InspectArchive validates a tar.gz archive like ValidateArchive and, in the
+// same pass, returns its README as ExtractReadme would. It only reads forward,
+// so it can check an archive while the archive streams elsewhere.
+func InspectArchive(reader io.Reader, maxSize int64) (readme string, err error) {
+	readmes := &readmeCollector{}
+	if err := inspectArchive(reader, maxSize, readmes); err != nil {
+		return "", err
+	}
+	return readmes.best(), nil
+}
+
+// inspectArchive walks a tar.gz archive enforcing the upload rules, handing
+// README candidates to readmes when it is non-nil.
+func inspectArchive(reader io.Reader, maxSize int64, readmes *readmeCollector) error {
 	if maxSize <= 0 {
 		maxSize = MaxArchiveSize
 	}
@@ -78,11 +96,23 @@ func ValidateArchive(reader io.Reader, maxSize int64) error {
 		// (header.Size is attacker-controlled and can be set to 0 while the
 		// entry contains arbitrary data).
 		cw := &countingWriter{}
+		var dst io.Writer = cw
+		priority := -1
+		var readme *bytes.Buffer
+		if readmes != nil {
+			if priority = readmes.wants(header); priority >= 0 {
+				readme = &bytes.Buffer{}
+				dst = io.MultiWriter(cw, &cappedWriter{w: readme, n: maxReadmeSize})
+			}
+		}
 		limited := io.LimitReader(tarReader, maxSize-totalSize+1)
-		if _, err := io.Copy(cw, limited); err != nil {
+		if _, err := io.Copy(dst, limited); err != nil {
 			return fmt.Errorf("failed to read archive entry: %w", err)
 		}
 		totalSize += cw.n
+		if readme != nil {
+			readmes.add(priority, readme.String())
+		}
 
 		// Check size limit
 		if totalSize > maxSize {
@@ -136,3 +166,23 @@ func (cw *countingWriter) Write(p []byte) (int, error) {
 	cw.n += int64(len(p))
 	return len(p), nil
 }
+
+// cappedWriter keeps the first n bytes written to it and discards the rest.
+type cappedWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *cappedWriter) Write(p []byte) (int, error) {
+	if cw.n > 0 {
+		keep := p
+		if int64(len(keep)) > cw.n {
+			keep = keep[:cw.n]
+		}
+		if _, err := cw.w.Write(keep); err != nil {
+			return 0, err
+		}
+		cw.n -= int64(len(keep))
+	}
+	return len(p), nil
+}
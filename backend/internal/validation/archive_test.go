@@ -217,3 +217,40 @@ func TestValidatePath(t *testing.T) {
 		})
 	}
 }
+
+func TestInspectArchive(t *testing.T) {
+	t.Run("returns highest-priority root README", func(t *testing.T) {
+		data := makeTarGz(t, map[string]string{
+			"main.tf":          "resource {}",
+			"README":           "other readme",
+			"README.md":        "preferred readme",
+			"subdir/README.md": "nested readme",
+		})
+		readme, err := InspectArchive(bytes.NewReader(data), 1024*1024)
+		if err != nil {
+			t.Fatalf("InspectArchive: %v", err)
+		}
+		if readme != "preferred readme" {
+			t.Errorf("readme = %q, want %q", readme, "preferred readme")
+		}
+	})
+
+	t.Run("applies ValidateArchive rules", func(t *testing.T) {
+		data := makeTarGz(t, map[string]string{"../escape.tf": "x", "README.md": "docs"})
+		if _, err := InspectArchive(bytes.NewReader(data), 1024*1024); err == nil {
+			t.Error("expected error for path traversal")
+		}
+	})
+
+	t.Run("caps README content", func(t *testing.T) {
+		big := strings.Repeat("a", maxReadmeSize+10)
+		data := makeTarGz(t, map[string]string{"README.md": big})
+		readme, err := InspectArchive(bytes.NewReader(data), 4*1024*1024)
+		if err != nil {
+			t.Fatalf("InspectArchive: %v", err)
+		}
+		if len(readme) != maxReadmeSize {
+			t.Errorf("len(readme) = %d, want %d", len(readme), maxReadmeSize)
+		}
+	})
+}
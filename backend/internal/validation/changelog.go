@@ -0,0 +1,152 @@
+// changelog.go extracts per-version release notes from a module's CHANGELOG
+// (keep-a-changelog and the common generator variants of it), so version
+// detail pages can show what changed in a given release.
+package validation
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxChangelogSize is the largest changelog excerpt stored per version. Longer
+// sections are truncated on a line boundary with a trailing marker.
+const MaxChangelogSize = 32 * 1024
+
+// changelogTruncatedMarker is appended to an excerpt cut at MaxChangelogSize.
+const changelogTruncatedMarker = "\n\n_(changelog truncated)_"
+
+// changelogNames lists the root-level file names recognised as a changelog,
+// in priority order.
+var changelogNames = []string{"CHANGELOG.md", "CHANGELOG", "CHANGELOG.txt", "CHANGES.md", "HISTORY.md"}
+
+// ExtractChangelog returns the full content of the root-level changelog file
+// in a tar.gz module archive, or "" when the archive has none. The read is
+// capped at 1 MiB, like ExtractReadme.
+func ExtractChangelog(archiveReader io.Reader) (string, error) {
+	gzReader, err := gzip.NewReader(archiveReader)
+	if err != nil {
+		return "", fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzReader.Close()
+
+	tarReader := tar.NewReader(gzReader)
+
+	const maxChangelogFileSize = 1024 * 1024
+	candidates := make(map[int]string)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read tar entry: %w", err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+
+		fileName := strings.TrimPrefix(header.Name, "./")
+		if strings.Contains(fileName, "/") {
+			continue
+		}
+
+		for priority, name := range changelogNames {
+			if strings.EqualFold(fileName, name) {
+				if _, already := candidates[priority]; !already {
+					content, err := io.ReadAll(io.LimitReader(tarReader, maxChangelogFileSize))
+					if err != nil {
+						return "", fmt.Errorf("failed to read changelog content: %w", err)
+					}
+					candidates[priority] = string(content)
+				}
+				break
+			}
+		}
+	}
+
+	for priority := range changelogNames {
+		if content, ok := candidates[priority]; ok {
+			return content, nil
+		}
+	}
+	return "", nil
+}
+
+// changelogHeadingRe matches an ATX markdown heading and captures its level
+// marker and text.
+var changelogHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// changelogVersionRe captures the first version-looking token in a heading's
+// text. It accepts the layouts produced by keep-a-changelog ("[1.2.0] -
+// 2024-01-01"), release-please / conventional-changelog ("[1.2.0](https://…)
+// (2024-01-01)"), and plain "v1.2.0" / "Version 1.2.0" headings.
+var changelogVersionRe = regexp.MustCompile(`(?i)(?:^|[\s\[(])v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)(?:$|[\s\]):,])`)
+
+// ChangelogSection returns the body of the changelog section whose heading
+// names version (with or without a leading "v"), or "" when no such section
+// exists. The section runs until the next heading that names another version
+// (at any level, since generators such as release-please emit patch releases
+// one level deeper than minor ones) or the next heading of a higher level.
+// The heading line itself is not included.
+func ChangelogSection(content, version string) string {
+	want := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if want == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	start, level := -1, 0
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		m := changelogHeadingRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		v := changelogVersionRe.FindStringSubmatch(m[2])
+		if start >= 0 {
+			if v != nil || len(m[1]) < level {
+				return strings.TrimSpace(strings.Join(lines[start:i], "\n"))
+			}
+			continue
+		}
+		if v != nil && v[1] == want {
+			start, level = i+1, len(m[1])
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n"))
+}
+
+// SanitizeChangelog prepares a changelog excerpt for storage and rendering:
+// it applies SanitizeMarkdown and truncates to MaxChangelogSize on a line
+// boundary.
+func SanitizeChangelog(s string) string {
+	s = SanitizeMarkdown(s)
+
+	if len(s) <= MaxChangelogSize {
+		return s
+	}
+	cut := MaxChangelogSize - len(changelogTruncatedMarker)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	if nl := strings.LastIndexByte(s[:cut], '\n'); nl > 0 {
+		cut = nl
+	}
+	return strings.TrimRight(s[:cut], " \n") + changelogTruncatedMarker
+}
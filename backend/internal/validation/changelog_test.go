@@ -0,0 +1,255 @@
+package validation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const keepAChangelog = `# Changelog
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+
+## [Unreleased]
+### Added
+- Work in progress.
+
+## [1.2.0] - 2024-03-01
+### Added
+- Support for IPv6 subnets.
+
+### Fixed
+- Tag propagation on NAT gateways.
+
+## [1.1.0] - 2024-01-15
+### Changed
+- Bump minimum AWS provider to 5.0.
+
+[unreleased]: https://github.com/example/vpc/compare/v1.2.0...HEAD
+[1.2.0]: https://github.com/example/vpc/compare/v1.1.0...v1.2.0
+`
+
+const releasePleaseChangelog = `# Changelog
+
+## [2.0.0](https://github.com/example/mod/compare/v1.9.1...v2.0.0) (2024-05-02)
+
+
+### ⚠ BREAKING CHANGES
+
+* drop support for Terraform 0.13
+
+### Features
+
+* add ` + "`enable_logging`" + ` input ([#42](https://github.com/example/mod/issues/42)) ([abc1234](https://github.com/example/mod/commit/abc1234))
+
+### [1.9.1](https://github.com/example/mod/compare/v1.9.0...v1.9.1) (2024-04-10)
+
+### Bug Fixes
+
+* correct output type
+`
+
+const plainVersionChangelog = `Release history
+===============
+
+# v3.1.0 (March 5, 2024)
+
+ENHANCEMENTS:
+
+* resource/widget: add ` + "`timeouts`" + ` block
+
+` + "```hcl" + `
+# 3.0.0 inside a code fence must not end the section
+` + "```" + `
+
+# v3.0.0 (February 1, 2024)
+
+BREAKING CHANGES:
+
+* removed deprecated arguments
+`
+
+const prereleaseChangelog = `## 1.0.0-rc.1
+
+- Release candidate.
+
+## 1.0.0-rc.10
+
+- Later release candidate.
+`
+
+func TestChangelogSection(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		version  string
+		contains []string
+		excludes []string
+		want     string
+	}{
+		{
+			name:     "keep-a-changelog bracketed heading",
+			content:  keepAChangelog,
+			version:  "1.2.0",
+			contains: []string{"IPv6 subnets", "### Fixed", "NAT gateways"},
+			excludes: []string{"Bump minimum", "Work in progress", "[1.2.0] - 2024-03-01"},
+		},
+		{
+			name:     "keep-a-changelog last section runs to link references",
+			content:  keepAChangelog,
+			version:  "1.1.0",
+			contains: []string{"Bump minimum AWS provider"},
+			excludes: []string{"IPv6"},
+		},
+		{
+			name:     "leading v in requested version is ignored",
+			content:  keepAChangelog,
+			version:  "v1.2.0",
+			contains: []string{"IPv6 subnets"},
+		},
+		{
+			name:     "release-please linked heading",
+			content:  releasePleaseChangelog,
+			version:  "2.0.0",
+			contains: []string{"BREAKING CHANGES", "enable_logging"},
+			excludes: []string{"correct output type"},
+		},
+		{
+			name:     "release-please patch release at deeper heading level",
+			content:  releasePleaseChangelog,
+			version:  "1.9.1",
+			contains: []string{"correct output type"},
+		},
+		{
+			name:     "plain v-prefixed heading with date and fenced code",
+			content:  plainVersionChangelog,
+			version:  "3.1.0",
+			contains: []string{"timeouts", "3.0.0 inside a code fence"},
+			excludes: []string{"removed deprecated arguments"},
+		},
+		{
+			name:     "prerelease does not match by prefix",
+			content:  prereleaseChangelog,
+			version:  "1.0.0-rc.1",
+			want:     "- Release candidate.",
+			excludes: []string{"Later"},
+		},
+		{
+			name:    "version not present",
+			content: keepAChangelog,
+			version: "9.9.9",
+			want:    "",
+		},
+		{
+			name:    "empty version",
+			content: keepAChangelog,
+			version: "",
+			want:    "",
+		},
+		{
+			name:    "CRLF line endings",
+			content: "## [0.1.0]\r\n- first\r\n## [0.0.1]\r\n- zero\r\n",
+			version: "0.1.0",
+			want:    "- first",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChangelogSection(tt.content, tt.version)
+			if tt.contains == nil && tt.excludes == nil && got != tt.want {
+				t.Fatalf("ChangelogSection() = %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Fatalf("ChangelogSection() = %q, want %q", got, tt.want)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("section missing %q; got:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(got, s) {
+					t.Errorf("section unexpectedly contains %q; got:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeChangelog(t *testing.T) {
+	t.Run("strips html and control characters", func(t *testing.T) {
+		in := "- fixed <script>alert(1)</script>bug\x00\x07 <!-- hidden -->\n- kept `a < b`"
+		got := SanitizeChangelog(in)
+		want := "- fixed alert(1)bug \n- kept `a < b`"
+		if got != want {
+			t.Errorf("SanitizeChangelog() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("truncates on a line boundary", func(t *testing.T) {
+		line := strings.Repeat("x", 99) + "\n"
+		got := SanitizeChangelog(strings.Repeat(line, 1000))
+		if len(got) > MaxChangelogSize {
+			t.Fatalf("len = %d, want <= %d", len(got), MaxChangelogSize)
+		}
+		if !strings.HasSuffix(got, changelogTruncatedMarker) {
+			t.Errorf("missing truncation marker")
+		}
+		body := strings.TrimSuffix(got, changelogTruncatedMarker)
+		if !strings.HasSuffix(body, strings.Repeat("x", 99)) {
+			t.Errorf("truncation split a line")
+		}
+	})
+
+	t.Run("short input unchanged", func(t *testing.T) {
+		if got := SanitizeChangelog("  ### Added\n- thing  "); got != "### Added\n- thing" {
+			t.Errorf("SanitizeChangelog() = %q", got)
+		}
+	})
+}
+
+func TestExtractChangelog(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "CHANGELOG.md at root",
+			files: map[string]string{"main.tf": "", "CHANGELOG.md": "## 1.0.0\n- init"},
+			want:  "## 1.0.0\n- init",
+		},
+		{
+			name:  "case-insensitive match",
+			files: map[string]string{"changelog.md": "lower"},
+			want:  "lower",
+		},
+		{
+			name:  "CHANGELOG.md preferred over CHANGES.md",
+			files: map[string]string{"CHANGES.md": "changes", "CHANGELOG.md": "changelog"},
+			want:  "changelog",
+		},
+		{
+			name:  "nested changelog ignored",
+			files: map[string]string{"modules/sub/CHANGELOG.md": "nested"},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractChangelog(bytes.NewReader(makeTarGz(t, tt.files)))
+			if err != nil {
+				t.Fatalf("ExtractChangelog: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractChangelog() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ExtractChangelog(strings.NewReader("not gzip")); err == nil {
+		t.Error("expected error for non-gzip input")
+	}
+}
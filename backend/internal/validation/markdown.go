@@ -0,0 +1,29 @@
+// markdown.go sanitizes user-supplied markdown (changelogs, namespace
+// descriptions) before it is stored. The frontend renders markdown only, so
+// raw HTML is never needed.
+package validation
+
+import (
+	"regexp"
+	"strings"
+)
+
+// markdownHTMLTagRe matches raw HTML tags and comments embedded in markdown.
+var markdownHTMLTagRe = regexp.MustCompile(`(?s)<!--.*?-->|</?[A-Za-z][^<>]*>`)
+
+// SanitizeMarkdown drops invalid UTF-8 and control characters (other than
+// newlines and tabs), strips raw HTML tags and comments, and trims
+// surrounding whitespace.
+func SanitizeMarkdown(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(markdownHTMLTagRe.ReplaceAllString(s, ""))
+}
@@ -0,0 +1,35 @@
+// module_repository.go checks SCM repository names against the module
+// registry protocol's terraform-<PROVIDER>-<NAME> naming convention, which
+// consumers rely on to tell which registry module a repository publishes.
+package validation
+
+import "strings"
+
+// ModuleRepositoryName returns the repository name the naming convention gives
+// the module with the given name and system: terraform-<system>-<name>.
+func ModuleRepositoryName(name, system string) string {
+	return "terraform-" + strings.ToLower(system) + "-" + strings.ToLower(name)
+}
+
+// FollowsModuleRepositoryConvention reports whether repoName has the
+// terraform-<PROVIDER>-<NAME> shape at all. Repositories named otherwise (a
+// monorepo, "infra-modules") make no claim about the module they hold.
+func FollowsModuleRepositoryConvention(repoName string) bool {
+	rest, ok := strings.CutPrefix(strings.ToLower(repoName), "terraform-")
+	if !ok {
+		return false
+	}
+	system, name, ok := strings.Cut(rest, "-")
+	return ok && system != "" && name != ""
+}
+
+// ModuleRepositoryNameMatches reports whether repoName is the conventional
+// repository name of the module. Repository hosts treat names
+// case-insensitively, so the comparison does too. Generic modules may also
+// use the terraform-module-<NAME> form, which predates the generic system.
+func ModuleRepositoryNameMatches(repoName, name, system string) bool {
+	if strings.EqualFold(repoName, ModuleRepositoryName(name, system)) {
+		return true
+	}
+	return IsGenericModuleSystem(system) && strings.EqualFold(repoName, ModuleRepositoryName(name, "module"))
+}
@@ -0,0 +1,45 @@
+package validation
+
+import "testing"
+
+func TestFollowsModuleRepositoryConvention(t *testing.T) {
+	tests := []struct {
+		repo string
+		want bool
+	}{
+		{"terraform-aws-vpc", true},
+		{"terraform-google-network-peering", true},
+		{"Terraform-AWS-VPC", true},
+		{"terraform-aws", false},
+		{"terraform-aws-", false},
+		{"terraform--vpc", false},
+		{"infra-modules", false},
+		{"aws-vpc", false},
+	}
+	for _, tt := range tests {
+		if got := FollowsModuleRepositoryConvention(tt.repo); got != tt.want {
+			t.Errorf("FollowsModuleRepositoryConvention(%q) = %v, want %v", tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestModuleRepositoryNameMatches(t *testing.T) {
+	tests := []struct {
+		repo, name, system string
+		want               bool
+	}{
+		{"terraform-aws-vpc", "vpc", "aws", true},
+		{"Terraform-AWS-VPC", "vpc", "aws", true},
+		{"terraform-google-network", "network", "aws", false},
+		{"terraform-aws-network", "vpc", "aws", false},
+		{"terraform-google-beta-net", "net", "google-beta", true},
+		{"terraform-generic-opa-policies", "opa-policies", "generic", true},
+		{"terraform-module-opa-policies", "opa-policies", "generic", true},
+		{"terraform-module-vpc", "vpc", "aws", false},
+	}
+	for _, tt := range tests {
+		if got := ModuleRepositoryNameMatches(tt.repo, tt.name, tt.system); got != tt.want {
+			t.Errorf("ModuleRepositoryNameMatches(%q, %q, %q) = %v, want %v", tt.repo, tt.name, tt.system, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// module_system.go validates the system (provider) segment of a module
+// address. Modules that do not target a single provider — policy libraries,
+// wrapper modules — use the reserved "generic" system rather than ad-hoc
+// placeholders that would each show up as a separate search facet.
+package validation
+
+import "fmt"
+
+// GenericModuleSystem is the reserved system of tool-agnostic modules.
+const GenericModuleSystem = "generic"
+
+// placeholderModuleSystems are systems teams invented before "generic" was
+// supported. They are rejected for new modules so the facet stays unified.
+var placeholderModuleSystems = map[string]bool{
+	"all":      true,
+	"any":      true,
+	"none":     true,
+	"multi":    true,
+	"agnostic": true,
+	"common":   true,
+}
+
+// IsGenericModuleSystem reports whether system is the reserved generic system.
+func IsGenericModuleSystem(system string) bool {
+	return system == GenericModuleSystem
+}
+
+// ValidateModuleSystem returns an error if system is not a valid registry
+// segment or is a placeholder standing in for "generic".
+func ValidateModuleSystem(system string) error {
+	if err := ValidateRegistrySegment(system); err != nil {
+		return err
+	}
+	if placeholderModuleSystems[system] {
+		return fmt.Errorf("%q is not a provider: use the reserved %q system for modules that are not provider-specific", system, GenericModuleSystem)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package validation
+
+import "testing"
+
+func TestValidateModuleSystem(t *testing.T) {
+	tests := []struct {
+		system  string
+		wantErr bool
+	}{
+		{"aws", false},
+		{"google-beta", false},
+		{"generic", false},
+		{"all", true},
+		{"none", true},
+		{"AWS", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if err := ValidateModuleSystem(tt.system); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateModuleSystem(%q) error = %v, wantErr %v", tt.system, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// namespace_metadata.go validates and sanitizes the landing-page content an
+// owning organization publishes for a namespace: a markdown description, a
+// short list of links, and an avatar URL.
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on namespace metadata. Larger submissions are rejected rather than
+// truncated, so the owner sees exactly what is published.
+const (
+	MaxNamespaceDescriptionSize    = 16 * 1024
+	MaxNamespaceLinks              = 10
+	MaxNamespaceLinkLabelLength    = 100
+	MaxNamespaceURLLength          = 2048
+	NamespaceShortDescriptionLimit = 200
+)
+
+// markdownScriptBlockRe matches script and style elements including their
+// content, which tag stripping alone would leave behind as text.
+var markdownScriptBlockRe = regexp.MustCompile(`(?is)<(script|style)\b[^>]*>.*?</(script|style)\s*>`)
+
+// markdownUnsafeLinkRe matches inline link and image targets that use a
+// scheme able to run code in the browser. One level of nested parentheses is
+// allowed in the target, as in javascript:alert(1).
+var markdownUnsafeLinkRe = regexp.MustCompile(`(?i)\]\(\s*<?\s*(?:javascript|vbscript|data):(?:[^()]|\([^()]*\))*\)`)
+
+// SanitizeNamespaceDescription prepares a namespace description for storage.
+// Script and style elements are removed with their content, links with
+// javascript:, vbscript: or data: targets are neutralized, and the result is
+// passed through SanitizeMarkdown. An error is returned when the sanitized
+// description exceeds MaxNamespaceDescriptionSize.
+func SanitizeNamespaceDescription(s string) (string, error) {
+	s = markdownScriptBlockRe.ReplaceAllString(s, "")
+	s = markdownUnsafeLinkRe.ReplaceAllString(s, "](#)")
+	s = SanitizeMarkdown(s)
+	if len(s) > MaxNamespaceDescriptionSize {
+		return "", fmt.Errorf("description exceeds %d bytes", MaxNamespaceDescriptionSize)
+	}
+	return s, nil
+}
+
+// ValidateNamespaceURL checks a link or avatar URL: it must be an absolute
+// http or https URL with a host and at most MaxNamespaceURLLength bytes long.
+func ValidateNamespaceURL(raw string) error {
+	if len(raw) > MaxNamespaceURLLength {
+		return fmt.Errorf("URL exceeds %d bytes", MaxNamespaceURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL must use http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL must include a host")
+	}
+	return nil
+}
+
+// ValidateNamespaceLinkLabel checks a link label is non-empty, single-line
+// and at most MaxNamespaceLinkLabelLength characters long.
+func ValidateNamespaceLinkLabel(label string) error {
+	if strings.TrimSpace(label) == "" {
+		return fmt.Errorf("link label is required")
+	}
+	if utf8.RuneCountInString(label) > MaxNamespaceLinkLabelLength {
+		return fmt.Errorf("link label exceeds %d characters", MaxNamespaceLinkLabelLength)
+	}
+	if strings.ContainsAny(label, "\r\n") {
+		return fmt.Errorf("link label must be a single line")
+	}
+	return nil
+}
+
+// markdownLinkRe captures the text of inline links and images.
+var markdownLinkRe = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+
+// NamespaceShortDescription derives the one-line summary shown in search
+// results from a sanitized description: the first paragraph, skipping
+// headings and fenced code, with link syntax and emphasis markers removed, cut to
+// NamespaceShortDescriptionLimit characters on a word boundary.
+func NamespaceShortDescription(description string) string {
+	var lines []string
+	inFence := false
+	for _, line := range strings.Split(strings.ReplaceAll(description, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "" {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	paragraph := strings.Join(lines, " ")
+
+	paragraph = markdownLinkRe.ReplaceAllString(paragraph, "$1")
+	paragraph = strings.NewReplacer("**", "", "__", "", "`", "", "*", "", "> ", "").Replace(paragraph)
+	paragraph = strings.Join(strings.Fields(paragraph), " ")
+
+	if utf8.RuneCountInString(paragraph) <= NamespaceShortDescriptionLimit {
+		return paragraph
+	}
+	runes := []rune(paragraph)[:NamespaceShortDescriptionLimit-1]
+	cut := string(runes)
+	if sp := strings.LastIndexByte(cut, ' '); sp > 0 {
+		cut = cut[:sp]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
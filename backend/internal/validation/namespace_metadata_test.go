@@ -0,0 +1,94 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeNamespaceDescription(t *testing.T) {
+	t.Run("strips scripts, html and unsafe links", func(t *testing.T) {
+		in := "# Acme\n<script>alert(1)</script><STYLE>p{}</STYLE>Our <b>modules</b> [docs](javascript:alert(1)) [site](https://acme.example)"
+		got, err := SanitizeNamespaceDescription(in)
+		if err != nil {
+			t.Fatalf("SanitizeNamespaceDescription: %v", err)
+		}
+		want := "# Acme\nOur modules [docs](#) [site](https://acme.example)"
+		if got != want {
+			t.Errorf("SanitizeNamespaceDescription() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("rejects oversized descriptions", func(t *testing.T) {
+		if _, err := SanitizeNamespaceDescription(strings.Repeat("x", MaxNamespaceDescriptionSize+1)); err == nil {
+			t.Error("expected an error for an oversized description")
+		}
+	})
+}
+
+func TestValidateNamespaceURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://acme.example/logo.png", false},
+		{"http://acme.example", false},
+		{"javascript:alert(1)", true},
+		{"data:image/png;base64,AAAA", true},
+		{"/relative/path", true},
+		{"https://", true},
+		{"https://acme.example/" + strings.Repeat("a", MaxNamespaceURLLength), true},
+	}
+	for _, tt := range tests {
+		if err := ValidateNamespaceURL(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateNamespaceURL(%.40q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateNamespaceLinkLabel(t *testing.T) {
+	if err := ValidateNamespaceLinkLabel("Runbook"); err != nil {
+		t.Errorf("valid label: %v", err)
+	}
+	for _, label := range []string{"", "  ", "two\nlines", strings.Repeat("x", MaxNamespaceLinkLabelLength+1)} {
+		if err := ValidateNamespaceLinkLabel(label); err == nil {
+			t.Errorf("ValidateNamespaceLinkLabel(%.20q) should fail", label)
+		}
+	}
+}
+
+func TestNamespaceShortDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "skips headings and strips markup",
+			in:   "# Acme networking\n\nModules for **VPCs** and\n[transit gateways](https://x.example).\n\nSecond paragraph.",
+			want: "Modules for VPCs and transit gateways.",
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NamespaceShortDescription(tt.in); got != tt.want {
+				t.Errorf("NamespaceShortDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("truncates on a word boundary", func(t *testing.T) {
+		got := NamespaceShortDescription(strings.Repeat("word ", 100))
+		if utf8.RuneCountInString(got) > NamespaceShortDescriptionLimit {
+			t.Fatalf("length = %d, want <= %d", utf8.RuneCountInString(got), NamespaceShortDescriptionLimit)
+		}
+		if !strings.HasSuffix(got, "word…") {
+			t.Errorf("got %q, want a whole word followed by an ellipsis", got)
+		}
+	})
+}
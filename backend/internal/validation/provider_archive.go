@@ -0,0 +1,143 @@
+// provider_archive.go checks that a provider zip archive holds the provider it
+// is published as. Terraform installs whatever binary an archive contains, so
+// an archive built for another provider (terraform-provider-google uploaded
+// as acme/aws) would otherwise be served without complaint.
+package validation
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ProviderManifestName is the registry manifest goreleaser-based provider
+// builds ship; when present at the top level of an archive and declaring
+// metadata.address, that address is checked as well.
+const ProviderManifestName = "terraform-registry-manifest.json"
+
+// maxProviderManifestBytes caps how much of the manifest is read.
+const maxProviderManifestBytes = 64 << 10
+
+// reProviderBinary matches a provider binary name with any .exe suffix
+// removed: terraform-provider-<TYPE>, optionally followed by an underscore
+// and the version, protocol or platform parts release tooling appends
+// (terraform-provider-aws_v5.0.0, terraform-provider-aws_v5.0.0_x5, ...).
+var reProviderBinary = regexp.MustCompile(`^terraform-provider-([A-Za-z0-9][A-Za-z0-9-]*)(?:_.*)?$`)
+
+// ProviderBinaryType returns the provider type a binary name in a provider
+// archive declares, reporting false when name is not a provider binary.
+func ProviderBinaryType(name string) (string, bool) {
+	base := strings.TrimSuffix(path.Base(name), ".exe")
+	if strings.HasSuffix(base, ".json") {
+		return "", false
+	}
+	m := reProviderBinary.FindStringSubmatch(base)
+	if m == nil {
+		return "", false
+	}
+	return strings.ToLower(m[1]), true
+}
+
+// ProviderAddressMismatch is returned by CheckProviderArchiveAddress when an
+// archive's contents name a different provider than the address checked.
+type ProviderAddressMismatch struct {
+	// Discrepancies describes each disagreement found.
+	Discrepancies []string
+}
+
+func (e *ProviderAddressMismatch) Error() string {
+	return "provider archive does not match its address: " + strings.Join(e.Discrepancies, "; ")
+}
+
+// providerManifest is the part of terraform-registry-manifest.json checked.
+type providerManifest struct {
+	Metadata struct {
+		Address string `json:"address"`
+	} `json:"metadata"`
+}
+
+// CheckProviderArchiveAddress checks the top level of a provider zip archive
+// against namespace/providerType. The archive must hold a provider binary
+// (terraform-provider-<TYPE>[_...][.exe]) and every such binary must name
+// providerType; a registry manifest declaring metadata.address
+// ([HOSTNAME/]NAMESPACE/TYPE) must declare namespace and providerType.
+// Names are compared case-insensitively, as Terraform compares addresses.
+// Mismatches are reported as a *ProviderAddressMismatch; other errors mean
+// the archive could not be read.
+func CheckProviderArchiveAddress(r io.ReaderAt, size int64, namespace, providerType string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("failed to open provider archive: %w", err)
+	}
+
+	want := strings.ToLower(providerType)
+	var discrepancies []string
+	binaries := 0
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(f.Name, "./")
+		if f.FileInfo().IsDir() || strings.Contains(name, "/") {
+			continue
+		}
+		if name == ProviderManifestName {
+			d, err := checkManifestAddress(f, namespace, providerType)
+			if err != nil {
+				return err
+			}
+			discrepancies = append(discrepancies, d...)
+			continue
+		}
+		got, ok := ProviderBinaryType(name)
+		if !ok {
+			continue
+		}
+		binaries++
+		if got != want {
+			discrepancies = append(discrepancies,
+				fmt.Sprintf("binary %s is provider type %q, not %q", name, got, want))
+		}
+	}
+	if binaries == 0 {
+		discrepancies = append(discrepancies,
+			fmt.Sprintf("archive has no terraform-provider-%s binary at its top level", want))
+	}
+
+	if len(discrepancies) > 0 {
+		return &ProviderAddressMismatch{Discrepancies: discrepancies}
+	}
+	return nil
+}
+
+// checkManifestAddress compares the address a registry manifest declares
+// with namespace/providerType. A manifest that is not JSON or declares no
+// address has nothing to compare.
+func checkManifestAddress(f *zip.File, namespace, providerType string) ([]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s in provider archive: %w", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxProviderManifestBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s in provider archive: %w", f.Name, err)
+	}
+
+	var manifest providerManifest
+	if json.Unmarshal(data, &manifest) != nil || manifest.Metadata.Address == "" {
+		return nil, nil
+	}
+	declared := manifest.Metadata.Address
+	parts := strings.Split(declared, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return []string{fmt.Sprintf("manifest address %q is not [HOSTNAME/]NAMESPACE/TYPE", declared)}, nil
+	}
+	gotNamespace, gotType := parts[len(parts)-2], parts[len(parts)-1]
+	if !strings.EqualFold(gotNamespace, namespace) || !strings.EqualFold(gotType, providerType) {
+		return []string{fmt.Sprintf("manifest declares %s, not %s/%s",
+			declared, strings.ToLower(namespace), strings.ToLower(providerType))}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,142 @@
+package validation
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func providerZip(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestProviderBinaryType(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantType string
+		wantOK   bool
+	}{
+		// goreleaser and HashiCorp release naming variants
+		{"terraform-provider-aws", "aws", true},
+		{"terraform-provider-aws_v5.31.0", "aws", true},
+		{"terraform-provider-aws_v5.31.0_x5", "aws", true},
+		{"terraform-provider-aws_v5.31.0_x6", "aws", true},
+		{"terraform-provider-aws_v5.31.0_x5.exe", "aws", true},
+		{"terraform-provider-aws.exe", "aws", true},
+		{"terraform-provider-aws_5.31.0", "aws", true},
+		{"terraform-provider-aws_v5.31.0-rc.1_x5", "aws", true},
+		{"terraform-provider-google-beta_v5.0.0_x5", "google-beta", true},
+		{"terraform-provider-AzureRM_v3.0.0", "azurerm", true},
+		{"./terraform-provider-aws_v5.31.0", "aws", true},
+		// Not provider binaries
+		{"terraform-provider-aws_v5.31.0_manifest.json", "", false},
+		{"LICENSE", "", false},
+		{"README.md", "", false},
+		{"terraform-provider-", "", false},
+		{"provider-aws", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ProviderBinaryType(tt.name)
+			if ok != tt.wantOK || got != tt.wantType {
+				t.Errorf("ProviderBinaryType(%q) = (%q, %v), want (%q, %v)", tt.name, got, ok, tt.wantType, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckProviderArchiveAddress_Match(t *testing.T) {
+	r := providerZip(t, map[string]string{
+		"terraform-provider-aws_v5.31.0_x5": "binary",
+		"LICENSE":                           "MPL",
+		"CHANGELOG.md":                      "changes",
+		ProviderManifestName:                `{"version":1,"metadata":{"protocol_versions":["5.0"],"address":"registry.example.com/Acme/AWS"}}`,
+	})
+	if err := CheckProviderArchiveAddress(r, r.Size(), "acme", "aws"); err != nil {
+		t.Errorf("CheckProviderArchiveAddress: %v", err)
+	}
+}
+
+func TestCheckProviderArchiveAddress_ManifestWithoutAddress(t *testing.T) {
+	r := providerZip(t, map[string]string{
+		"terraform-provider-aws_v5.31.0_x5": "binary",
+		ProviderManifestName:                `{"version":1,"metadata":{"protocol_versions":["5.0"]}}`,
+	})
+	if err := CheckProviderArchiveAddress(r, r.Size(), "acme", "aws"); err != nil {
+		t.Errorf("CheckProviderArchiveAddress: %v", err)
+	}
+}
+
+func TestCheckProviderArchiveAddress_Mismatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string // substrings, one per discrepancy
+	}{
+		{"binary for another type",
+			map[string]string{"terraform-provider-google_v5.0.0_x5": "binary"},
+			[]string{`binary terraform-provider-google_v5.0.0_x5 is provider type "google", not "aws"`}},
+		{"type with a suffix",
+			map[string]string{"terraform-provider-aws-extras_v1.0.0": "binary"},
+			[]string{`"aws-extras"`}},
+		{"no binary",
+			map[string]string{"LICENSE": "MPL", "nested/terraform-provider-aws": "binary"},
+			[]string{"no terraform-provider-aws binary"}},
+		{"manifest for another namespace",
+			map[string]string{
+				"terraform-provider-aws_v5.31.0_x5": "binary",
+				ProviderManifestName:                `{"metadata":{"address":"hashicorp/aws"}}`,
+			},
+			[]string{"manifest declares hashicorp/aws, not acme/aws"}},
+		{"malformed manifest address",
+			map[string]string{
+				"terraform-provider-aws_v5.31.0_x5": "binary",
+				ProviderManifestName:                `{"metadata":{"address":"aws"}}`,
+			},
+			[]string{"is not [HOSTNAME/]NAMESPACE/TYPE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := providerZip(t, tt.files)
+			err := CheckProviderArchiveAddress(r, r.Size(), "acme", "aws")
+			var mismatch *ProviderAddressMismatch
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("err = %v, want a *ProviderAddressMismatch", err)
+			}
+			if len(mismatch.Discrepancies) != len(tt.want) {
+				t.Fatalf("discrepancies = %q, want %d", mismatch.Discrepancies, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(mismatch.Discrepancies[i], want) {
+					t.Errorf("discrepancy %d = %q, want it to contain %q", i, mismatch.Discrepancies[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckProviderArchiveAddress_NotAZip(t *testing.T) {
+	r := bytes.NewReader([]byte("PK\x03\x04 not really a zip"))
+	err := CheckProviderArchiveAddress(r, r.Size(), "acme", "aws")
+	var mismatch *ProviderAddressMismatch
+	if err == nil || errors.As(err, &mismatch) {
+		t.Errorf("err = %v, want a read error", err)
+	}
+}
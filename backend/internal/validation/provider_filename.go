@@ -0,0 +1,30 @@
+// provider_filename.go parses provider archive names in the layout goreleaser
+// and the HashiCorp release tooling produce,
+// terraform-provider-<TYPE>_<VERSION>_<OS>_<ARCH>.zip, so uploads can take
+// their version and platform from the file instead of form fields.
+package validation
+
+import "regexp"
+
+// reProviderFilename matches terraform-provider-<TYPE>_<VERSION>_<OS>_<ARCH>.zip.
+// The captured values are only split out here; callers validate them with
+// ValidateSemver and ValidatePlatform like explicitly supplied ones.
+var reProviderFilename = regexp.MustCompile(`^terraform-provider-([a-z0-9][a-z0-9-]*)_([^_/\\]+)_([a-z0-9]+)_([a-z0-9]+)\.zip$`)
+
+// ProviderFilename is what a canonical provider archive name encodes.
+type ProviderFilename struct {
+	Type    string
+	Version string
+	OS      string
+	Arch    string
+}
+
+// ParseProviderFilename splits a canonical provider archive name into its
+// parts, reporting false when name does not follow the layout.
+func ParseProviderFilename(name string) (ProviderFilename, bool) {
+	m := reProviderFilename.FindStringSubmatch(name)
+	if m == nil {
+		return ProviderFilename{}, false
+	}
+	return ProviderFilename{Type: m[1], Version: m[2], OS: m[3], Arch: m[4]}, true
+}
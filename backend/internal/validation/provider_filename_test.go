@@ -0,0 +1,31 @@
+package validation
+
+import "testing"
+
+func TestParseProviderFilename(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   ProviderFilename
+		wantOK bool
+	}{
+		{"terraform-provider-foo_1.2.3_darwin_arm64.zip", ProviderFilename{"foo", "1.2.3", "darwin", "arm64"}, true},
+		{"terraform-provider-foo_1.2.3_windows_386.zip", ProviderFilename{"foo", "1.2.3", "windows", "386"}, true},
+		{"terraform-provider-google-beta_5.0.0-rc.1_linux_amd64.zip", ProviderFilename{"google-beta", "5.0.0-rc.1", "linux", "amd64"}, true},
+		// Malformed names
+		{"provider.zip", ProviderFilename{}, false},
+		{"terraform-provider-foo_1.2.3_linux.zip", ProviderFilename{}, false},
+		{"terraform-provider-foo_1.2.3_linux_amd64.tar.gz", ProviderFilename{}, false},
+		{"terraform-provider-foo_1.2.3_linux_amd64_extra.zip", ProviderFilename{}, false},
+		{"terraform-provider-Foo_1.2.3_linux_amd64.zip", ProviderFilename{}, false},
+		{"terraform-provider-foo_1.2.3_SHA256SUMS", ProviderFilename{}, false},
+		{"", ProviderFilename{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseProviderFilename(tt.name)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseProviderFilename(%q) = (%+v, %v), want (%+v, %v)", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -10,6 +10,63 @@ import (
 	"strings"
 )
 
+// readmeNames lists the README file names looked for in the archive root,
+// highest priority first.
+var readmeNames = []string{"README.md", "readme.md", "README.MD", "README", "readme", "README.txt", "readme.txt"}
+
+// maxReadmeSize caps the README content kept from an archive.
+const maxReadmeSize = 1024 * 1024
+
+// readmeCollector keeps the README candidates seen while walking an archive
+// so the highest-priority one can be returned once the walk is done.
+type readmeCollector struct {
+	candidates map[int]string // priority → content
+}
+
+// wants returns the priority of header's README candidate, or -1 when it is
+// not a root README or one of its priority has already been collected.
+func (r *readmeCollector) wants(header *tar.Header) int {
+	// Skip directories
+	if header.Typeflag == tar.TypeDir {
+		return -1
+	}
+
+	// Get the file name (basename), removing a leading ./ if present
+	fileName := strings.TrimPrefix(header.Name, "./")
+
+	// Check if it's in the root (no subdirectories)
+	if strings.Contains(fileName, "/") {
+		return -1
+	}
+
+	for priority, readmeName := range readmeNames {
+		if strings.EqualFold(fileName, readmeName) {
+			if _, already := r.candidates[priority]; already {
+				return -1
+			}
+			return priority
+		}
+	}
+	return -1
+}
+
+func (r *readmeCollector) add(priority int, content string) {
+	if r.candidates == nil {
+		r.candidates = make(map[int]string)
+	}
+	r.candidates[priority] = content
+}
+
+// best returns the highest-priority candidate (lowest index in readmeNames).
+func (r *readmeCollector) best() string {
+	for priority := range readmeNames {
+		if content, ok := r.candidates[priority]; ok {
+			return content
+		}
+	}
+	return ""
+}
+
 // ExtractReadme extracts README content from a tarball.
 // Looks for README.md, README.txt, README, or readme.md files in the root.
 // When multiple candidates are present, the one with the highest priority in
@@ -25,13 +82,9 @@ func ExtractReadme(archiveReader io.Reader) (string, error) {
 	// Create tar reader
 	tarReader := tar.NewReader(gzReader)
 
-	// Priority-ordered list: index 0 = highest priority.
-	readmeNames := []string{"README.md", "readme.md", "README.MD", "README", "readme", "README.txt", "readme.txt"}
-
-	// Collect all README candidates keyed by their priority index so we can
-	// return the highest-priority one after scanning the full archive.
-	const maxReadmeSize = 1024 * 1024
-	candidates := make(map[int]string) // priority → content
+	// Collect all README candidates so we can return the highest-priority
+	// one after scanning the full archive.
+	readmes := &readmeCollector{}
 
 	for {
 		header, err := tarReader.Next()
@@ -42,43 +95,15 @@ func ExtractReadme(archiveReader io.Reader) (string, error) {
 			return "", fmt.Errorf("failed to read tar entry: %w", err)
 		}
 
-		// Skip directories
-		if header.Typeflag == tar.TypeDir {
-			continue
-		}
-
-		// Get the file name (basename)
-		fileName := header.Name
-		// Remove leading ./ if present
-		fileName = strings.TrimPrefix(fileName, "./")
-
-		// Check if it's in the root (no subdirectories)
-		if strings.Contains(fileName, "/") {
-			continue
-		}
-
-		// Check if this is a README file and record it with its priority
-		for priority, readmeName := range readmeNames {
-			if strings.EqualFold(fileName, readmeName) {
-				if _, already := candidates[priority]; !already {
-					limited := io.LimitReader(tarReader, maxReadmeSize)
-					content, err := io.ReadAll(limited)
-					if err != nil {
-						return "", fmt.Errorf("failed to read README content: %w", err)
-					}
-					candidates[priority] = string(content)
-				}
-				break
+		if priority := readmes.wants(header); priority >= 0 {
+			limited := io.LimitReader(tarReader, maxReadmeSize)
+			content, err := io.ReadAll(limited)
+			if err != nil {
+				return "", fmt.Errorf("failed to read README content: %w", err)
 			}
+			readmes.add(priority, string(content))
 		}
 	}
 
-	// Return the highest-priority candidate (lowest index in readmeNames).
-	for priority := range readmeNames {
-		if content, ok := candidates[priority]; ok {
-			return content, nil
-		}
-	}
-
-	return "", nil
+	return readmes.best(), nil
 }
@@ -4,6 +4,8 @@ package validation
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 )
@@ -32,3 +34,128 @@ func CompareSemver(v1Str, v2Str string) (int, error) {
 
 	return v1.Compare(v2), nil
 }
+
+// ValidateVersionConstraint validates a version constraint string such as a
+// Terraform required_version (">= 1.5.0, < 2.0.0", "~> 1.6").
+func ValidateVersionConstraint(constraint string) error {
+	if _, err := version.NewConstraint(constraint); err != nil {
+		return fmt.Errorf("invalid version constraint: %w", err)
+	}
+	return nil
+}
+
+// SatisfiesConstraint reports whether versionStr satisfies constraint. Only
+// the core (major.minor.patch) of versionStr is compared, so a pre-release
+// client such as 1.9.0-beta1 is treated as 1.9.0 rather than being excluded
+// by every constraint that does not name a pre-release.
+func SatisfiesConstraint(versionStr, constraint string) (bool, error) {
+	v, err := version.NewVersion(versionStr)
+	if err != nil {
+		return false, fmt.Errorf("invalid version: %w", err)
+	}
+	c, err := version.NewConstraint(constraint)
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint: %w", err)
+	}
+	return c.Check(v.Core()), nil
+}
+
+// IsPrerelease reports whether versionStr carries a pre-release suffix
+// (1.4.0-rc.1, 2.0.0-beta). Build metadata alone (1.0.0+build.1) does not make
+// a pre-release. Invalid versions are reported as not pre-release.
+func IsPrerelease(versionStr string) bool {
+	v, err := version.NewVersion(versionStr)
+	if err != nil {
+		return false
+	}
+	return v.Prerelease() != ""
+}
+
+// MatchingVersions returns the versions satisfying constraint, newest first.
+// An empty constraint matches every version. Pre-releases follow Terraform's
+// rule and only match a constraint naming a pre-release of the same version
+// (= 2.0.0-beta1), unless includePrerelease is set, in which case they are
+// compared by their core like releases. Versions that do not parse are
+// skipped.
+func MatchingVersions(versions []string, constraint string, includePrerelease bool) ([]string, error) {
+	var cs version.Constraints
+	if strings.TrimSpace(constraint) != "" {
+		var err error
+		if cs, err = version.NewConstraint(constraint); err != nil {
+			return nil, fmt.Errorf("invalid version constraint: %w", err)
+		}
+	}
+
+	var matched []*version.Version
+	for _, s := range versions {
+		v, err := version.NewVersion(s)
+		if err != nil {
+			continue
+		}
+		pre := v.Prerelease() != ""
+		switch {
+		case cs == nil:
+			if pre && !includePrerelease {
+				continue
+			}
+		case !cs.Check(v):
+			if !pre || !includePrerelease || !cs.Check(v.Core()) {
+				continue
+			}
+		}
+		matched = append(matched, v)
+	}
+	return newestFirst(matched), nil
+}
+
+// ClosestVersions returns up to n of versions nearest the version the first
+// term of constraint names (~> 5.0 names 5.0), newest first: the highest ones
+// below it and the lowest ones from it upwards, half each when both sides
+// have enough. It suggests alternatives when nothing matches constraint. With
+// an empty or unparseable constraint the n newest versions are returned.
+func ClosestVersions(versions []string, constraint string, n int) []string {
+	var parsed []*version.Version
+	for _, s := range versions {
+		if v, err := version.NewVersion(s); err == nil {
+			parsed = append(parsed, v)
+		}
+	}
+	if n <= 0 || len(parsed) == 0 {
+		return []string{}
+	}
+	sort.Sort(version.Collection(parsed))
+
+	// Every version is below the pivot unless one can be parsed.
+	split := len(parsed)
+	if first := strings.TrimSpace(strings.Split(constraint, ",")[0]); first != "" {
+		if pivot, err := version.NewVersion(strings.TrimLeft(first, "<>=!~ ")); err == nil {
+			split = sort.Search(len(parsed), func(i int) bool { return !parsed[i].LessThan(pivot) })
+		}
+	}
+
+	below, above := split, len(parsed)-split
+	takeAbove := n / 2
+	if takeAbove > above {
+		takeAbove = above
+	}
+	takeBelow := n - takeAbove
+	if takeBelow > below {
+		takeBelow = below
+		takeAbove = n - takeBelow
+		if takeAbove > above {
+			takeAbove = above
+		}
+	}
+	return newestFirst(parsed[split-takeBelow : split+takeAbove])
+}
+
+// newestFirst sorts vs newest first and returns their original strings.
+func newestFirst(vs []*version.Version) []string {
+	sorted := append([]*version.Version(nil), vs...)
+	sort.Sort(sort.Reverse(version.Collection(sorted)))
+	out := make([]string, len(sorted))
+	for i, v := range sorted {
+		out[i] = v.Original()
+	}
+	return out
+}
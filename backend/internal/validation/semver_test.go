@@ -1,6 +1,9 @@
 package validation
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestValidateSemver(t *testing.T) {
 	tests := []struct {
@@ -66,3 +69,114 @@ func TestCompareSemver(t *testing.T) {
 		})
 	}
 }
+
+func TestSatisfiesConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		constraint string
+		want       bool
+		wantErr    bool
+	}{
+		{"satisfied range", "1.6.2", ">= 1.5.0, < 2.0.0", true, false},
+		{"below minimum", "1.4.0", ">= 1.5.0", false, false},
+		{"pessimistic", "1.7.0", "~> 1.6.0", false, false},
+		{"pre-release client uses core version", "1.9.0-beta1", ">= 1.5", true, false},
+		{"invalid version", "dev", ">= 1.5", false, true},
+		{"invalid constraint", "1.6.0", "newest", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SatisfiesConstraint(tt.version, tt.constraint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SatisfiesConstraint(%q, %q) error = %v, wantErr %v", tt.version, tt.constraint, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SatisfiesConstraint(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"1.0.0", false},
+		{"v1.2.3", false},
+		{"1.4.0-rc.1", true},
+		{"2.0.0-beta", true},
+		{"v2.0.0-alpha.3", true},
+		{"1.0.0+build.1", false},
+		{"1.0.0-rc.1+build.1", true},
+		{"not-a-version", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := IsPrerelease(tt.version); got != tt.want {
+				t.Errorf("IsPrerelease(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchingVersions(t *testing.T) {
+	versions := []string{"4.67.0", "5.0.0", "5.31.0", "5.32.0-beta1", "6.0.0", "6.1.0-rc1", "not-a-version"}
+	tests := []struct {
+		name       string
+		constraint string
+		includePre bool
+		want       []string
+		wantErr    bool
+	}{
+		{"pessimistic", "~> 5.0", false, []string{"5.31.0", "5.0.0"}, false},
+		{"empty matches all releases", "", false, []string{"6.0.0", "5.31.0", "5.0.0", "4.67.0"}, false},
+		{"pre-release opt-in", "~> 5.0", true, []string{"5.32.0-beta1", "5.31.0", "5.0.0"}, false},
+		{"empty with pre-releases", "", true, []string{"6.1.0-rc1", "6.0.0", "5.32.0-beta1", "5.31.0", "5.0.0", "4.67.0"}, false},
+		{"exact pre-release", "= 6.1.0-rc1", false, []string{"6.1.0-rc1"}, false},
+		{"no match", ">= 7.0", false, []string{}, false},
+		{"invalid constraint", "newest", false, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MatchingVersions(versions, tt.constraint, tt.includePre)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("MatchingVersions(%q) = %v, want %v", tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClosestVersions(t *testing.T) {
+	versions := []string{"3.0.0", "4.0.0", "4.66.0", "4.67.0", "6.0.0", "6.1.0", "6.2.0", "7.0.0"}
+	tests := []struct {
+		name       string
+		constraint string
+		n          int
+		want       []string
+	}{
+		{"both sides", "~> 5.0", 4, []string{"6.1.0", "6.0.0", "4.67.0", "4.66.0"}},
+		{"few above", ">= 7.0", 4, []string{"7.0.0", "6.2.0", "6.1.0", "6.0.0"}},
+		{"few below", "~> 3.5", 4, []string{"4.67.0", "4.66.0", "4.0.0", "3.0.0"}},
+		{"no pivot", "", 2, []string{"7.0.0", "6.2.0"}},
+		{"more than available", "~> 5.0", 20, []string{"7.0.0", "6.2.0", "6.1.0", "6.0.0", "4.67.0", "4.66.0", "4.0.0", "3.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClosestVersions(versions, tt.constraint, tt.n)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ClosestVersions(%q, %d) = %v, want %v", tt.constraint, tt.n, got, tt.want)
+			}
+		})
+	}
+	if got := ClosestVersions(nil, "~> 5.0", 4); len(got) != 0 {
+		t.Errorf("ClosestVersions(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// APIKey is a stored API key. The secret itself is only ever returned by
+// CreateAPIKey and RotateAPIKey.
+type APIKey struct {
+	ID                       string     `json:"id"`
+	UserID                   *string    `json:"user_id,omitempty"`
+	UserName                 *string    `json:"user_name,omitempty"`
+	OrganizationID           string     `json:"organization_id,omitempty"`
+	Name                     string     `json:"name"`
+	Description              string     `json:"description,omitempty"`
+	KeyPrefix                string     `json:"key_prefix"`
+	Scopes                   []string   `json:"scopes"`
+	ExpiresAt                *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt               *time.Time `json:"last_used_at,omitempty"`
+	ExpiryNotificationSentAt *time.Time `json:"expiry_notification_sent_at,omitempty"`
+	CreatedAt                time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyInput is the body of an API key creation.
+type CreateAPIKeyInput struct {
+	Name           string     `json:"name"`
+	OrganizationID string     `json:"organization_id"`
+	Description    *string    `json:"description,omitempty"`
+	Scopes         []string   `json:"scopes"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	// Delivery set to DeliveryOneTimeLink returns ClaimURL instead of Key.
+	Delivery string `json:"delivery,omitempty"`
+}
+
+// DeliveryOneTimeLink asks for a new key's secret to be delivered through a
+// single-use link, retrieved with ClaimAPIKey, instead of in the response.
+const DeliveryOneTimeLink = "one_time_link"
+
+// CreatedAPIKey is a new key with its secret. Key is not retrievable again.
+// With one-time link delivery, Key is empty and ClaimURL retrieves it once
+// before ClaimExpiresAt.
+type CreatedAPIKey struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	Key         string     `json:"key"`
+	KeyPrefix   string     `json:"key_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	ClaimURL       string     `json:"claim_url,omitempty"`
+	ClaimExpiresAt *time.Time `json:"claim_expires_at,omitempty"`
+}
+
+// UpdateAPIKeyInput changes the set fields of a key and leaves the rest.
+type UpdateAPIKeyInput struct {
+	Name      *string    `json:"name,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// RotatedAPIKey is the result of a rotation.
+type RotatedAPIKey struct {
+	NewKey CreatedAPIKey `json:"new_key"`
+	// OldKeyStatus is "revoked", or "expires_at" when the old key stays valid
+	// for the grace period until OldExpiresAt.
+	OldKeyStatus string     `json:"old_key_status"`
+	OldExpiresAt *time.Time `json:"old_expires_at,omitempty"`
+}
+
+// ListAPIKeys lists the caller's keys, or every key of organizationID for
+// admins (GET /api/v1/apikeys). An empty organizationID applies no filter.
+func (c *Client) ListAPIKeys(ctx context.Context, organizationID string) ([]APIKey, error) {
+	query := url.Values{}
+	setQuery(query, "organization_id", organizationID)
+	var out struct {
+		Keys []APIKey `json:"keys"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/apikeys", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Keys, nil
+}
+
+// GetAPIKey returns one key (GET /api/v1/apikeys/{id}).
+func (c *Client) GetAPIKey(ctx context.Context, id string) (*APIKey, error) {
+	var out struct {
+		Key APIKey `json:"key"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, pathEscape("/api/v1/apikeys/%s", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out.Key, nil
+}
+
+// CreateAPIKey creates a key (POST /api/v1/apikeys). The returned Key is the
+// only copy of the secret.
+func (c *Client) CreateAPIKey(ctx context.Context, in CreateAPIKeyInput) (*CreatedAPIKey, error) {
+	var out CreatedAPIKey
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v1/apikeys", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateAPIKey changes a key's name, scopes or expiry (PUT /api/v1/apikeys/{id}).
+func (c *Client) UpdateAPIKey(ctx context.Context, id string, in UpdateAPIKeyInput) (*APIKey, error) {
+	var out struct {
+		Key APIKey `json:"key"`
+	}
+	if err := c.doJSON(ctx, http.MethodPut, pathEscape("/api/v1/apikeys/%s", id), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out.Key, nil
+}
+
+// DeleteAPIKey revokes a key (DELETE /api/v1/apikeys/{id}).
+func (c *Client) DeleteAPIKey(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodDelete, pathEscape("/api/v1/apikeys/%s", id), nil, nil, nil)
+}
+
+// RotateAPIKey replaces a key with a new one carrying the same name and
+// scopes (POST /api/v1/apikeys/{id}/rotate). The old key stays valid for
+// gracePeriod (0 revokes it immediately; the server allows up to 72 hours).
+func (c *Client) RotateAPIKey(ctx context.Context, id string, gracePeriod time.Duration) (*RotatedAPIKey, error) {
+	in := struct {
+		GracePeriodHours int `json:"grace_period_hours"`
+	}{GracePeriodHours: int(gracePeriod / time.Hour)}
+	var out RotatedAPIKey
+	if err := c.doJSON(ctx, http.MethodPost, pathEscape("/api/v1/apikeys/%s/rotate", id), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ClaimAPIKey retrieves the secret of a key created with one-time link
+// delivery (GET /api/v1/apikeys/claims/{token}). token is the last path
+// segment of ClaimURL. The link works once, for the key's owner.
+func (c *Client) ClaimAPIKey(ctx context.Context, token string) (string, error) {
+	var out struct {
+		Key string `json:"key"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, pathEscape("/api/v1/apikeys/claims/%s", token), nil, nil, &out); err != nil {
+		return "", err
+	}
+	return out.Key, nil
+}
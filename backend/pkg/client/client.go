@@ -0,0 +1,160 @@
+// Package client is a typed Go client for the registry's stable HTTP surfaces:
+// module and provider publishing, the protocol version listings, search,
+// provider mirror management and API key management. It exists so internal
+// tooling stops hand-rolling requests against the API; the package is covered
+// by contract tests that drive the real router (see internal/integration), so
+// a server change that breaks a request or response shape used here fails CI
+// instead of a downstream tool.
+//
+// Every method takes a context, sends the configured credential as a Bearer
+// token, and returns an *APIError when the server answers with a non-2xx
+// status, carrying the message from the registry's error envelope.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds requests made with the default HTTP client. Uploads of
+// large provider archives go through the same client, so it is generous.
+const defaultTimeout = 5 * time.Minute
+
+// Client talks to one registry. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	userAgent  string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithToken sets the credential sent on every request. Both JWTs and API keys
+// are accepted by the registry as Bearer tokens.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient replaces the default HTTP client, e.g. to add a transport
+// with custom TLS settings or to shorten the timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithUserAgent sets the User-Agent header, so the registry's request logs
+// name the calling tool.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New returns a client for the registry at baseURL, e.g.
+// "https://registry.example.com". A path prefix on baseURL is kept, for
+// registries served below the root of their host.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		userAgent:  "terraform-registry-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// endpoint resolves path (already escaped, starting with a slash) against the
+// base URL.
+func (c *Client) endpoint(path string, query url.Values) string {
+	u := *c.baseURL
+	u.RawPath = c.baseURL.EscapedPath() + path
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// newRequest builds a request carrying the auth and user agent headers.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint(path, query), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
+
+// doJSON sends in (when non-nil) as a JSON body and decodes the response into
+// out (when non-nil).
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body, contentType = bytes.NewReader(b), "application/json"
+	}
+	req, err := c.newRequest(ctx, method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	return c.do(req, out)
+}
+
+// do sends req and decodes a 2xx JSON response into out. Any other status is
+// returned as an *APIError.
+func (c *Client) do(req *http.Request, out interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", req.Method, req.URL.Path, err)
+	}
+	return nil
+}
+
+// pathEscape escapes each argument as a single path segment.
+func pathEscape(format string, args ...string) string {
+	escaped := make([]interface{}, len(args))
+	for i, a := range args {
+		escaped[i] = url.PathEscape(a)
+	}
+	return fmt.Sprintf(format, escaped...)
+}
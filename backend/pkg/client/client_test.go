@@ -0,0 +1,300 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient serves handler and returns a client for it.
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL, opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestNew_RejectsInvalidBaseURL(t *testing.T) {
+	for _, raw := range []string{"registry.example.com", "ftp://registry.example.com", "://"} {
+		if _, err := New(raw); err == nil {
+			t.Errorf("New(%q) = nil error, want error", raw)
+		}
+	}
+}
+
+func TestClient_SendsTokenAndUserAgent(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tfr_secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("User-Agent"); got != "release-bot/1.0" {
+			t.Errorf("User-Agent = %q", got)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []interface{}{}})
+	}, WithToken("tfr_secret"), WithUserAgent("release-bot/1.0"))
+
+	if _, err := c.ListAPIKeys(context.Background(), ""); err != nil {
+		t.Fatalf("ListAPIKeys: %v", err)
+	}
+}
+
+func TestClient_KeepsBasePathAndEscapesSegments(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/registry/v1/modules/acme/a%2Fb/aws/versions" {
+			t.Errorf("path = %q", r.URL.EscapedPath())
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"modules": []interface{}{}})
+	}))
+	defer srv.Close()
+	c, err := New(srv.URL + "/registry/")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	versions, err := c.ModuleVersions(context.Background(), "acme", "a/b", "aws")
+	if err != nil {
+		t.Fatalf("ModuleVersions: %v", err)
+	}
+	if versions != nil {
+		t.Errorf("versions = %v, want none", versions)
+	}
+}
+
+func TestClient_DecodesErrorEnvelopes(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMessage string
+		wantErrors  []string
+		check       func(error) bool
+	}{
+		{
+			name:        "api envelope",
+			status:      http.StatusConflict,
+			body:        `{"error":"Module version already exists"}`,
+			wantMessage: "Module version already exists",
+			check:       IsConflict,
+		},
+		{
+			name:        "protocol envelope",
+			status:      http.StatusNotFound,
+			body:        `{"errors":["Module not found","try again"]}`,
+			wantMessage: "Module not found; try again",
+			wantErrors:  []string{"Module not found", "try again"},
+			check:       IsNotFound,
+		},
+		{
+			name:   "not json",
+			status: http.StatusForbidden,
+			body:   "<html>denied</html>",
+			check:  IsUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			})
+			_, err := c.GetAPIKey(context.Background(), "k1")
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.wantMessage {
+				t.Errorf("got %d %q, want %d %q", apiErr.StatusCode, apiErr.Message, tt.status, tt.wantMessage)
+			}
+			if strings.Join(apiErr.Errors, "|") != strings.Join(tt.wantErrors, "|") {
+				t.Errorf("Errors = %v, want %v", apiErr.Errors, tt.wantErrors)
+			}
+			if string(apiErr.Body) != tt.body {
+				t.Errorf("Body = %q", apiErr.Body)
+			}
+			if !tt.check(err) {
+				t.Errorf("status helper does not match %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_HonoursContext(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetMirror(ctx, "m1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+// searchServer serves total modules in pages, recording each request's offset.
+func searchServer(t *testing.T, total int, offsets *[]int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		*offsets = append(*offsets, offset)
+		if r.URL.Query().Get("namespace") != "acme" {
+			t.Errorf("namespace filter not sent: %s", r.URL.RawQuery)
+		}
+		var modules []map[string]interface{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			modules = append(modules, map[string]interface{}{"id": strconv.Itoa(i), "name": "m" + strconv.Itoa(i)})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"modules": modules,
+			"meta":    map[string]int{"limit": limit, "offset": offset, "total": total},
+		})
+	}
+}
+
+func TestAllModules_WalksEveryPage(t *testing.T) {
+	var offsets []int
+	c := newTestClient(t, searchServer(t, 5, &offsets))
+
+	var ids []string
+	for m, err := range c.AllModules(context.Background(), ModuleSearchOptions{Namespace: "acme", Limit: 2}) {
+		if err != nil {
+			t.Fatalf("AllModules: %v", err)
+		}
+		ids = append(ids, m.ID)
+	}
+	if strings.Join(ids, ",") != "0,1,2,3,4" {
+		t.Errorf("ids = %v", ids)
+	}
+	if len(offsets) != 3 || offsets[2] != 4 {
+		t.Errorf("requested offsets %v, want [0 2 4]", offsets)
+	}
+}
+
+func TestAllModules_StopsWhenLoopBreaks(t *testing.T) {
+	var offsets []int
+	c := newTestClient(t, searchServer(t, 50, &offsets))
+
+	n := 0
+	for _, err := range c.AllModules(context.Background(), ModuleSearchOptions{Namespace: "acme", Limit: 10}) {
+		if err != nil {
+			t.Fatalf("AllModules: %v", err)
+		}
+		if n++; n == 3 {
+			break
+		}
+	}
+	if len(offsets) != 1 {
+		t.Errorf("requested %d pages, want 1", len(offsets))
+	}
+}
+
+func TestAllProviders_YieldsError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to search providers"})
+	})
+
+	var errs []error
+	for _, err := range c.AllProviders(context.Background(), ProviderSearchOptions{}) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] == nil || !strings.Contains(errs[0].Error(), "Failed to search providers") {
+		t.Errorf("errs = %v, want the one search error", errs)
+	}
+}
+
+func TestPublishProvider_SendsMultipartForm(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/providers" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm: %v", err)
+		}
+		if got := r.FormValue("protocols"); got != `["6.0"]` {
+			t.Errorf("protocols = %q", got)
+		}
+		if _, ok := r.MultipartForm.Value["version"]; ok {
+			t.Error("empty version was sent")
+		}
+		if _, ok := r.MultipartForm.File["shasums_file"]; ok {
+			t.Error("absent SHA256SUMS file was sent")
+		}
+		f, hdr, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("file: %v", err)
+		}
+		body, _ := io.ReadAll(f)
+		if hdr.Filename != "terraform-provider-widget_1.2.0_linux_amd64.zip" || string(body) != "zip-bytes" {
+			t.Errorf("file = %s %q", hdr.Filename, body)
+		}
+		writeJSON(w, http.StatusCreated, map[string]interface{}{
+			"namespace": "acme", "type": "widget", "version": "1.2.0", "os": "linux", "arch": "amd64",
+		})
+	})
+
+	got, err := c.PublishProvider(context.Background(), PublishProviderInput{
+		Namespace: "acme",
+		Type:      "widget",
+		Protocols: []string{"6.0"},
+		Archive:   strings.NewReader("zip-bytes"),
+		Filename:  "terraform-provider-widget_1.2.0_linux_amd64.zip",
+	})
+	if err != nil {
+		t.Fatalf("PublishProvider: %v", err)
+	}
+	if got.Version != "1.2.0" || got.OS != "linux" {
+		t.Errorf("published = %+v", got)
+	}
+}
+
+func TestRotateAPIKey_SendsGracePeriodInHours(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]int
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/api/v1/apikeys/k1/rotate" || body["grace_period_hours"] != 24 {
+			t.Errorf("%s %v", r.URL.Path, body)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"new_key":        map[string]interface{}{"id": "k2", "key": "tfr_new"},
+			"old_key_status": "expires_at",
+		})
+	})
+
+	got, err := c.RotateAPIKey(context.Background(), "k1", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("RotateAPIKey: %v", err)
+	}
+	if got.NewKey.Key != "tfr_new" || got.OldKeyStatus != "expires_at" {
+		t.Errorf("rotated = %+v", got)
+	}
+}
+
+func TestClaimAPIKey(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v1/apikeys/claims/tok" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"id": "k1", "key": "tfr_secret"})
+	})
+
+	key, err := c.ClaimAPIKey(context.Background(), "tok")
+	if err != nil || key != "tfr_secret" {
+		t.Errorf("ClaimAPIKey = %q, %v", key, err)
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody caps how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// APIError is a non-2xx response. The registry's API endpoints answer with
+// {"error": "..."} and the Terraform protocol endpoints with
+// {"errors": ["..."]}; both are decoded here.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Message is the "error" field, or the "errors" entries joined with "; ".
+	Message string
+	// Errors holds the "errors" entries of a protocol endpoint response.
+	Errors []string
+	// Body is the raw response body, for responses that carry more than the
+	// envelope (such as policy violations).
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("registry returned %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("registry returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an *APIError with status 404.
+func IsNotFound(err error) bool { return hasStatus(err, http.StatusNotFound) }
+
+// IsConflict reports whether err is an *APIError with status 409, such as a
+// version that is already published.
+func IsConflict(err error) bool { return hasStatus(err, http.StatusConflict) }
+
+// IsUnauthorized reports whether err is an *APIError with status 401 or 403.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized) || hasStatus(err, http.StatusForbidden)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// decodeError reads resp into an *APIError. A body that is not the JSON
+// envelope (a proxy's HTML error page, say) is kept in Body only.
+func decodeError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &APIError{StatusCode: resp.StatusCode, Body: body}
+
+	var envelope struct {
+		Error  string   `json:"error"`
+		Errors []string `json:"errors"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		apiErr.Errors = envelope.Errors
+		apiErr.Message = envelope.Error
+		if apiErr.Message == "" {
+			apiErr.Message = strings.Join(envelope.Errors, "; ")
+		}
+	}
+	return apiErr
+}
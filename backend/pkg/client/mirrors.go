@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Mirror is a provider mirror configuration. The filters are stored and
+// returned as JSON array text (e.g. `["hashicorp"]`).
+type Mirror struct {
+	ID                       string     `json:"id"`
+	Name                     string     `json:"name"`
+	Description              *string    `json:"description,omitempty"`
+	UpstreamRegistryURL      string     `json:"upstream_registry_url"`
+	OrganizationID           *string    `json:"organization_id,omitempty"`
+	Private                  bool       `json:"private"`
+	NamespaceFilter          *string    `json:"namespace_filter,omitempty"`
+	ProviderFilter           *string    `json:"provider_filter,omitempty"`
+	VersionFilter            *string    `json:"version_filter,omitempty"`
+	PlatformFilter           *string    `json:"platform_filter,omitempty"`
+	Enabled                  bool       `json:"enabled"`
+	SyncIntervalHours        int        `json:"sync_interval_hours"`
+	RequiresApproval         bool       `json:"requires_approval"`
+	AutoApproveRules         *string    `json:"auto_approve_rules,omitempty"`
+	PullThroughEnabled       bool       `json:"pull_through_enabled"`
+	PullThroughCacheTTLHours int        `json:"pull_through_cache_ttl_hours"`
+	DocsPassthroughEnabled   bool       `json:"docs_passthrough_enabled"`
+	DocsCacheTTLHours        int        `json:"docs_cache_ttl_hours"`
+	HistoryRetentionCount    int        `json:"history_retention_count"`
+	HistoryRetentionDays     int        `json:"history_retention_days"`
+	LastSyncAt               *time.Time `json:"last_sync_at,omitempty"`
+	LastSyncStatus           *string    `json:"last_sync_status,omitempty"`
+	LastSyncError            *string    `json:"last_sync_error,omitempty"`
+	CreatedAt                time.Time  `json:"created_at"`
+	UpdatedAt                time.Time  `json:"updated_at"`
+	CreatedBy                *string    `json:"created_by,omitempty"`
+}
+
+// MirrorInput is the body of a mirror create or update. On create, Name and
+// UpstreamRegistryURL are required and unset fields take the server defaults;
+// on update, only the set fields change.
+type MirrorInput struct {
+	Name                *string `json:"name,omitempty"`
+	Description         *string `json:"description,omitempty"`
+	UpstreamRegistryURL *string `json:"upstream_registry_url,omitempty"`
+	OrganizationID      *string `json:"organization_id,omitempty"`
+	Private             *bool   `json:"private,omitempty"`
+	// NamespaceFilter and ProviderFilter list the namespaces and provider
+	// names to mirror.
+	NamespaceFilter []string `json:"namespace_filter,omitempty"`
+	ProviderFilter  []string `json:"provider_filter,omitempty"`
+	// VersionFilter is e.g. "3.", "latest:5", ">=3.0.0" or a comma-separated
+	// list.
+	VersionFilter *string `json:"version_filter,omitempty"`
+	// PlatformFilter lists "os/arch" strings such as "linux/amd64".
+	PlatformFilter           []string `json:"platform_filter,omitempty"`
+	Enabled                  *bool    `json:"enabled,omitempty"`
+	SyncIntervalHours        *int     `json:"sync_interval_hours,omitempty"`
+	RequiresApproval         *bool    `json:"requires_approval,omitempty"`
+	AutoApproveRules         *string  `json:"auto_approve_rules,omitempty"`
+	PullThroughEnabled       *bool    `json:"pull_through_enabled,omitempty"`
+	PullThroughCacheTTLHours *int     `json:"pull_through_cache_ttl_hours,omitempty"`
+	DocsPassthroughEnabled   *bool    `json:"docs_passthrough_enabled,omitempty"`
+	DocsCacheTTLHours        *int     `json:"docs_cache_ttl_hours,omitempty"`
+	HistoryRetentionCount    *int     `json:"history_retention_count,omitempty"`
+	HistoryRetentionDays     *int     `json:"history_retention_days,omitempty"`
+}
+
+// MirrorSync is one sync run of a mirror.
+type MirrorSync struct {
+	ID              string     `json:"id"`
+	MirrorConfigID  string     `json:"mirror_config_id"`
+	StartedAt       time.Time  `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	Status          string     `json:"status"`
+	ProvidersSynced int        `json:"providers_synced"`
+	ProvidersFailed int        `json:"providers_failed"`
+	ErrorMessage    *string    `json:"error_message,omitempty"`
+}
+
+// MirrorStatus is a mirror's configuration with its current and recent syncs.
+type MirrorStatus struct {
+	Mirror        Mirror       `json:"mirror_config"`
+	CurrentSync   *MirrorSync  `json:"current_sync,omitempty"`
+	RecentSyncs   []MirrorSync `json:"recent_syncs"`
+	NextScheduled *time.Time   `json:"next_scheduled,omitempty"`
+}
+
+// SyncMirrorInput narrows a manual sync to one namespace or provider.
+type SyncMirrorInput struct {
+	Namespace    *string `json:"namespace,omitempty"`
+	ProviderName *string `json:"provider_name,omitempty"`
+}
+
+// ListMirrors lists the mirror configurations (GET /api/v1/admin/mirrors),
+// only the enabled ones when enabledOnly is set.
+func (c *Client) ListMirrors(ctx context.Context, enabledOnly bool) ([]Mirror, error) {
+	query := url.Values{}
+	if enabledOnly {
+		query.Set("enabled", "true")
+	}
+	var out struct {
+		Mirrors []Mirror `json:"mirrors"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/admin/mirrors", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Mirrors, nil
+}
+
+// GetMirror returns one mirror configuration (GET /api/v1/admin/mirrors/{id}).
+func (c *Client) GetMirror(ctx context.Context, id string) (*Mirror, error) {
+	var out Mirror
+	if err := c.doJSON(ctx, http.MethodGet, pathEscape("/api/v1/admin/mirrors/%s", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateMirror creates a mirror configuration (POST /api/v1/admin/mirrors).
+func (c *Client) CreateMirror(ctx context.Context, in MirrorInput) (*Mirror, error) {
+	var out Mirror
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v1/admin/mirrors", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateMirror changes the set fields of a mirror configuration
+// (PUT /api/v1/admin/mirrors/{id}).
+func (c *Client) UpdateMirror(ctx context.Context, id string, in MirrorInput) (*Mirror, error) {
+	var out Mirror
+	if err := c.doJSON(ctx, http.MethodPut, pathEscape("/api/v1/admin/mirrors/%s", id), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteMirror removes a mirror configuration (DELETE
+// /api/v1/admin/mirrors/{id}); deleteProviders also removes the providers
+// only this mirror created.
+func (c *Client) DeleteMirror(ctx context.Context, id string, deleteProviders bool) error {
+	query := url.Values{}
+	if deleteProviders {
+		query.Set("delete_providers", "true")
+	}
+	return c.doJSON(ctx, http.MethodDelete, pathEscape("/api/v1/admin/mirrors/%s", id), query, nil, nil)
+}
+
+// SyncMirror starts a sync in the background (POST
+// /api/v1/admin/mirrors/{id}/sync); follow it with GetMirrorStatus.
+func (c *Client) SyncMirror(ctx context.Context, id string, in SyncMirrorInput) error {
+	return c.doJSON(ctx, http.MethodPost, pathEscape("/api/v1/admin/mirrors/%s/sync", id), nil, in, nil)
+}
+
+// GetMirrorStatus returns a mirror's sync status (GET
+// /api/v1/admin/mirrors/{id}/status).
+func (c *Client) GetMirrorStatus(ctx context.Context, id string) (*MirrorStatus, error) {
+	var out MirrorStatus
+	if err := c.doJSON(ctx, http.MethodGet, pathEscape("/api/v1/admin/mirrors/%s/status", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
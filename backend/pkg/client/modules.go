@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// PublishModuleInput is a module version upload.
+type PublishModuleInput struct {
+	Namespace   string
+	Name        string
+	System      string
+	Version     string
+	Description string
+	// Source is the module's source repository URL.
+	Source string
+	// IgnoreVersionCap publishes past the module's version cap without
+	// archiving older versions. Admin scope only.
+	IgnoreVersionCap bool
+	// Archive is the module's .tar.gz, sent as Filename.
+	Archive  io.Reader
+	Filename string
+}
+
+// PublishedModule is the response to a module upload.
+type PublishedModule struct {
+	ID        string    `json:"id"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	System    string    `json:"system"`
+	Version   string    `json:"version"`
+	Checksum  string    `json:"checksum"`
+	SizeBytes int64     `json:"size_bytes"`
+	Filename  string    `json:"filename"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PublishModule uploads a module version (POST /api/v1/modules). Publishing a
+// version that already exists returns an error satisfying IsConflict.
+func (c *Client) PublishModule(ctx context.Context, in PublishModuleInput) (*PublishedModule, error) {
+	fields := map[string]string{
+		"namespace":   in.Namespace,
+		"name":        in.Name,
+		"system":      in.System,
+		"version":     in.Version,
+		"description": in.Description,
+		"source":      in.Source,
+	}
+	if in.IgnoreVersionCap {
+		fields["ignore_version_cap"] = "true"
+	}
+	filename := in.Filename
+	if filename == "" {
+		filename = in.Name + "-" + in.System + "-" + in.Version + ".tar.gz"
+	}
+	var out PublishedModule
+	files := []formFile{{field: "file", filename: filename, content: in.Archive}}
+	if err := c.upload(ctx, "/api/v1/modules", fields, files, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ModuleVersion is one entry of a module's version listing.
+type ModuleVersion struct {
+	ID                 string     `json:"id,omitempty"`
+	Version            string     `json:"version"`
+	PublishedAt        *time.Time `json:"published_at,omitempty"`
+	DownloadCount      int64      `json:"download_count,omitempty"`
+	HasDocs            bool       `json:"has_docs,omitempty"`
+	Deprecated         bool       `json:"deprecated,omitempty"`
+	DeprecatedAt       *time.Time `json:"deprecated_at,omitempty"`
+	DeprecationMessage *string    `json:"deprecation_message,omitempty"`
+}
+
+// ModuleVersions lists a module's versions through the Module Registry
+// Protocol (GET /v1/modules/{namespace}/{name}/{system}/versions).
+func (c *Client) ModuleVersions(ctx context.Context, namespace, name, system string) ([]ModuleVersion, error) {
+	var out struct {
+		Modules []struct {
+			Versions []ModuleVersion `json:"versions"`
+		} `json:"modules"`
+	}
+	path := pathEscape("/v1/modules/%s/%s/%s/versions", namespace, name, system)
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Modules) == 0 {
+		return nil, nil
+	}
+	return out.Modules[0].Versions, nil
+}
+
+// ModuleSearchOptions filters and pages a module search. Zero values are left
+// to the server's defaults.
+type ModuleSearchOptions struct {
+	Query     string
+	Namespace string
+	System    string
+	// Sort is one of relevance, name, downloads, created or updated.
+	Sort string
+	// Order is asc or desc.
+	Order string
+	// Scope "all" searches every namespace on a custom tenant domain.
+	Scope string
+	// Limit is the page size (server default 20, max 100).
+	Limit  int
+	Offset int
+}
+
+func (o ModuleSearchOptions) values() url.Values {
+	v := url.Values{}
+	setQuery(v, "q", o.Query)
+	setQuery(v, "namespace", o.Namespace)
+	setQuery(v, "system", o.System)
+	setQuery(v, "sort", o.Sort)
+	setQuery(v, "order", o.Order)
+	setQuery(v, "scope", o.Scope)
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		v.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return v
+}
+
+// ModuleSummary is one search result.
+type ModuleSummary struct {
+	ID                   string     `json:"id"`
+	Namespace            string     `json:"namespace"`
+	Name                 string     `json:"name"`
+	System               string     `json:"system"`
+	Description          *string    `json:"description,omitempty"`
+	Source               *string    `json:"source,omitempty"`
+	LatestVersion        string     `json:"latest_version,omitempty"`
+	NamespaceDescription *string    `json:"namespace_description,omitempty"`
+	DownloadCount        int64      `json:"download_count"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+	Deprecated           bool       `json:"deprecated,omitempty"`
+	DeprecatedAt         *time.Time `json:"deprecated_at,omitempty"`
+	DeprecationMessage   *string    `json:"deprecation_message,omitempty"`
+	SuccessorModuleID    *string    `json:"successor_module_id,omitempty"`
+}
+
+// ModuleSearchResult is one page of a module search.
+type ModuleSearchResult struct {
+	Modules []ModuleSummary `json:"modules"`
+	Meta    Pagination      `json:"meta"`
+}
+
+// SearchModules returns one page of modules (GET /api/v1/modules/search).
+func (c *Client) SearchModules(ctx context.Context, opts ModuleSearchOptions) (*ModuleSearchResult, error) {
+	var out ModuleSearchResult
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/modules/search", opts.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AllModules iterates over every module matching opts, requesting further
+// pages as the loop advances. opts.Limit sets the page size (default 100) and
+// opts.Offset where to start.
+//
+//	for m, err := range c.AllModules(ctx, client.ModuleSearchOptions{Namespace: "acme"}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(m.Name)
+//	}
+func (c *Client) AllModules(ctx context.Context, opts ModuleSearchOptions) iter.Seq2[ModuleSummary, error] {
+	return paginate(ctx, opts.Offset, opts.Limit, func(ctx context.Context, offset, limit int) ([]ModuleSummary, Pagination, error) {
+		opts.Offset, opts.Limit = offset, limit
+		page, err := c.SearchModules(ctx, opts)
+		if err != nil {
+			return nil, Pagination{}, err
+		}
+		return page.Modules, page.Meta, nil
+	})
+}
+
+// setQuery sets key when value is non-empty.
+func setQuery(v url.Values, key, value string) {
+	if value != "" {
+		v.Set(key, value)
+	}
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+// Pagination is the meta block of the paginated search endpoints.
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// maxPageSize is the largest page the search endpoints serve.
+const maxPageSize = 100
+
+// fetchPage returns the items at offset along with the page's meta block.
+type fetchPage[T any] func(ctx context.Context, offset, limit int) ([]T, Pagination, error)
+
+// paginate walks every page from offset, limit items at a time, yielding each
+// item. A failed request is yielded once as the error and ends the walk; so
+// does the consumer breaking out of the loop.
+func paginate[T any](ctx context.Context, offset, limit int, fetch fetchPage[T]) iter.Seq2[T, error] {
+	if limit < 1 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return func(yield func(T, error) bool) {
+		for {
+			items, meta, err := fetch(ctx, offset, limit)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			offset += len(items)
+			if len(items) == 0 || offset >= meta.Total {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// PublishProviderInput is a provider platform upload.
+type PublishProviderInput struct {
+	Namespace string
+	Type      string
+	// Version, OS and Arch may be left empty when Filename follows
+	// terraform-provider-<TYPE>_<VERSION>_<OS>_<ARCH>.zip; the registry then
+	// reads them from the name.
+	Version string
+	OS      string
+	Arch    string
+	// Protocols defaults to ["5.0"] on the server.
+	Protocols   []string
+	Description string
+	Source      string
+	// Archive is the provider .zip, sent as Filename.
+	Archive  io.Reader
+	Filename string
+	// SHASums and SHASumsSignature optionally carry the release's
+	// SHA256SUMS file and its detached signature; the signature also needs
+	// GPGPublicKey (ASCII-armored) to verify against.
+	SHASums          io.Reader
+	SHASumsSignature io.Reader
+	GPGPublicKey     string
+}
+
+// PublishedProvider is the response to a provider upload.
+type PublishedProvider struct {
+	ID        string   `json:"id"`
+	Namespace string   `json:"namespace"`
+	Type      string   `json:"type"`
+	Version   string   `json:"version"`
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+	Protocols []string `json:"protocols"`
+	Checksum  string   `json:"checksum"`
+	SizeBytes int64    `json:"size_bytes"`
+	Filename  string   `json:"filename"`
+}
+
+// PublishProvider uploads one platform of a provider version
+// (POST /api/v1/providers).
+func (c *Client) PublishProvider(ctx context.Context, in PublishProviderInput) (*PublishedProvider, error) {
+	fields := map[string]string{
+		"namespace":      in.Namespace,
+		"type":           in.Type,
+		"version":        in.Version,
+		"os":             in.OS,
+		"arch":           in.Arch,
+		"description":    in.Description,
+		"source":         in.Source,
+		"gpg_public_key": in.GPGPublicKey,
+	}
+	if len(in.Protocols) > 0 {
+		b, err := json.Marshal(in.Protocols)
+		if err != nil {
+			return nil, err
+		}
+		fields["protocols"] = string(b)
+	}
+	filename := in.Filename
+	if filename == "" {
+		filename = "terraform-provider-" + in.Type + "_" + in.Version + "_" + in.OS + "_" + in.Arch + ".zip"
+	}
+	sumsName := "terraform-provider-" + in.Type + "_" + in.Version + "_SHA256SUMS"
+	files := []formFile{
+		{field: "file", filename: filename, content: in.Archive},
+		{field: "shasums_file", filename: sumsName, content: in.SHASums},
+		{field: "shasums_signature_file", filename: sumsName + ".sig", content: in.SHASumsSignature},
+	}
+	var out PublishedProvider
+	if err := c.upload(ctx, "/api/v1/providers", fields, files, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProviderPlatform is one os/arch build of a provider version.
+type ProviderPlatform struct {
+	ID            string `json:"id"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	Filename      string `json:"filename"`
+	Shasum        string `json:"shasum"`
+	DownloadCount int64  `json:"download_count"`
+}
+
+// ProviderVersion is one entry of a provider's version listing.
+type ProviderVersion struct {
+	ID                 string             `json:"id"`
+	Version            string             `json:"version"`
+	Protocols          []string           `json:"protocols"`
+	Platforms          []ProviderPlatform `json:"platforms"`
+	PublishedAt        *time.Time         `json:"published_at,omitempty"`
+	DownloadCount      int64              `json:"download_count"`
+	Deprecated         bool               `json:"deprecated"`
+	DeprecatedAt       *time.Time         `json:"deprecated_at,omitempty"`
+	DeprecationMessage *string            `json:"deprecation_message,omitempty"`
+}
+
+// ProviderVersions lists a provider's versions through the Provider Registry
+// Protocol (GET /v1/providers/{namespace}/{type}/versions).
+func (c *Client) ProviderVersions(ctx context.Context, namespace, providerType string) ([]ProviderVersion, error) {
+	var out struct {
+		Versions []ProviderVersion `json:"versions"`
+	}
+	path := pathEscape("/v1/providers/%s/%s/versions", namespace, providerType)
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Versions, nil
+}
+
+// ProviderSearchOptions filters and pages a provider search. Zero values are
+// left to the server's defaults.
+type ProviderSearchOptions struct {
+	Query     string
+	Namespace string
+	// Sort is one of relevance, name, downloads, created or updated.
+	Sort string
+	// Order is asc or desc.
+	Order string
+	// Scope "all" searches every namespace on a custom tenant domain.
+	Scope string
+	// Limit is the page size (server default 20, max 100).
+	Limit  int
+	Offset int
+}
+
+func (o ProviderSearchOptions) values() url.Values {
+	v := url.Values{}
+	setQuery(v, "q", o.Query)
+	setQuery(v, "namespace", o.Namespace)
+	setQuery(v, "sort", o.Sort)
+	setQuery(v, "order", o.Order)
+	setQuery(v, "scope", o.Scope)
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		v.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return v
+}
+
+// ProviderSummary is one search result.
+type ProviderSummary struct {
+	ID            string    `json:"id"`
+	Namespace     string    `json:"namespace"`
+	Type          string    `json:"type"`
+	Description   *string   `json:"description,omitempty"`
+	Source        *string   `json:"source,omitempty"`
+	LatestVersion string    `json:"latest_version,omitempty"`
+	DownloadCount int64     `json:"download_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	// SourceURL, Tier and License are set for mirrored providers only.
+	SourceURL string `json:"source_url,omitempty"`
+	Tier      string `json:"tier,omitempty"`
+	License   string `json:"license,omitempty"`
+}
+
+// ProviderSearchResult is one page of a provider search.
+type ProviderSearchResult struct {
+	Providers []ProviderSummary `json:"providers"`
+	Meta      Pagination        `json:"meta"`
+}
+
+// SearchProviders returns one page of providers (GET /api/v1/providers/search).
+func (c *Client) SearchProviders(ctx context.Context, opts ProviderSearchOptions) (*ProviderSearchResult, error) {
+	var out ProviderSearchResult
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/providers/search", opts.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AllProviders iterates over every provider matching opts, requesting further
+// pages as the loop advances, in the same way as AllModules.
+func (c *Client) AllProviders(ctx context.Context, opts ProviderSearchOptions) iter.Seq2[ProviderSummary, error] {
+	return paginate(ctx, opts.Offset, opts.Limit, func(ctx context.Context, offset, limit int) ([]ProviderSummary, Pagination, error) {
+		opts.Offset, opts.Limit = offset, limit
+		page, err := c.SearchProviders(ctx, opts)
+		if err != nil {
+			return nil, Pagination{}, err
+		}
+		return page.Providers, page.Meta, nil
+	})
+}
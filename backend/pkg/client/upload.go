@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// formFile is a file part of a multipart upload.
+type formFile struct {
+	field    string
+	filename string
+	content  io.Reader
+}
+
+// upload posts fields and files as multipart/form-data to path and decodes
+// the response into out. The body is streamed, so provider archives are
+// never held in memory.
+func (c *Client) upload(ctx context.Context, path string, fields map[string]string, files []formFile, out interface{}) error {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		pw.CloseWithError(writeForm(mw, fields, files))
+	}()
+
+	req, err := c.newRequest(ctx, http.MethodPost, path, nil, pr, mw.FormDataContentType())
+	if err != nil {
+		_ = pr.Close()
+		return err
+	}
+	err = c.do(req, out)
+	// Unblocks the writer if the server answered before reading the body.
+	_ = pr.Close()
+	return err
+}
+
+func writeForm(mw *multipart.Writer, fields map[string]string, files []formFile) error {
+	for k, v := range fields {
+		if v == "" {
+			continue
+		}
+		if err := mw.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+	for _, f := range files {
+		if f.content == nil {
+			continue
+		}
+		w, err := mw.CreateFormFile(f.field, f.filename)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, f.content); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
@@ -0,0 +1,100 @@
+#!/usr/bin/env python3
+"""Report a Terraform configuration's module and provider usage to the registry.
+
+Reads the provider versions locked in ``.terraform.lock.hcl`` and the
+registry modules installed in ``.terraform/modules/modules.json`` (written by
+``terraform init``) and POSTs them to ``/api/v1/usage/report``. Run it from CI
+after ``terraform init``:
+
+    TFR_API_KEY=... python scripts/report-usage.py \\
+        --registry https://registry.example.com \\
+        --consumer https://github.com/acme/infra \\
+        --dir environments/prod
+
+The API key needs ``modules:read`` or ``providers:read``. Modules installed
+from anything but a registry (git, local paths, archives) have no version and
+are skipped. Only the standard library is used, so the script runs wherever
+Python 3 does. ``--dry-run`` prints the report instead of sending it.
+"""
+
+from __future__ import annotations
+
+import argparse
+import json
+import os
+import re
+import sys
+import urllib.error
+import urllib.request
+from pathlib import Path
+
+# provider "registry.terraform.io/hashicorp/aws" {
+#   version     = "5.31.0"
+LOCK_PROVIDER = re.compile(r'provider\s+"([^"]+)"\s*\{[^}]*?\bversion\s*=\s*"([^"]+)"', re.S)
+
+
+def locked_providers(config_dir: Path) -> list[dict]:
+    lock = config_dir / ".terraform.lock.hcl"
+    if not lock.exists():
+        return []
+    return [{"source": source, "version": version}
+            for source, version in LOCK_PROVIDER.findall(lock.read_text())]
+
+
+def installed_modules(config_dir: Path) -> list[dict]:
+    manifest = config_dir / ".terraform" / "modules" / "modules.json"
+    if not manifest.exists():
+        return []
+    modules = []
+    for m in json.loads(manifest.read_text()).get("Modules", []):
+        # Registry modules are the only ones with a version.
+        if m.get("Version") and m.get("Source"):
+            modules.append({"source": m["Source"], "version": m["Version"]})
+    return modules
+
+
+def main() -> int:
+    parser = argparse.ArgumentParser(description=__doc__.splitlines()[0])
+    parser.add_argument("--registry", default=os.environ.get("TFR_REGISTRY_URL"),
+                        help="registry base URL (default: $TFR_REGISTRY_URL)")
+    parser.add_argument("--consumer", default=os.environ.get("TFR_USAGE_CONSUMER"),
+                        help="repository URL or workspace name (default: $TFR_USAGE_CONSUMER)")
+    parser.add_argument("--dir", default=".", help="Terraform configuration directory")
+    parser.add_argument("--dry-run", action="store_true", help="print the report without sending it")
+    args = parser.parse_args()
+
+    if not args.consumer:
+        parser.error("--consumer (or $TFR_USAGE_CONSUMER) is required")
+    config_dir = Path(args.dir)
+    report = {
+        "consumer": args.consumer,
+        "modules": installed_modules(config_dir),
+        "providers": locked_providers(config_dir),
+    }
+    if not report["modules"] and not report["providers"]:
+        print(f"no modules or providers found in {config_dir}; run terraform init first", file=sys.stderr)
+        return 1
+    if args.dry_run:
+        print(json.dumps(report, indent=2))
+        return 0
+
+    api_key = os.environ.get("TFR_API_KEY")
+    if not args.registry or not api_key:
+        parser.error("--registry (or $TFR_REGISTRY_URL) and $TFR_API_KEY are required")
+    req = urllib.request.Request(
+        args.registry.rstrip("/") + "/api/v1/usage/report",
+        data=json.dumps(report).encode(),
+        headers={"Authorization": f"Bearer {api_key}", "Content-Type": "application/json"},
+        method="POST",
+    )
+    try:
+        with urllib.request.urlopen(req, timeout=30) as resp:  # noqa: S310 - operator-supplied registry URL
+            print(resp.read().decode())
+    except urllib.error.HTTPError as e:
+        print(f"usage report rejected: {e.code} {e.read().decode()}", file=sys.stderr)
+        return 1
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())